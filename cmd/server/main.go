@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -11,35 +13,93 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 
+	"austrian-business-infrastructure/internal/abwesenheit"
 	"austrian-business-infrastructure/internal/account"
+	"austrian-business-infrastructure/internal/activity"
+	"austrian-business-infrastructure/internal/ai"
+	"austrian-business-infrastructure/internal/analysis"
+	"austrian-business-infrastructure/internal/analytics"
+	"austrian-business-infrastructure/internal/anomaly"
 	"austrian-business-infrastructure/internal/antrag"
 	"austrian-business-infrastructure/internal/api"
 	"austrian-business-infrastructure/internal/apikey"
+	"austrian-business-infrastructure/internal/assessment"
 	"austrian-business-infrastructure/internal/audit"
 	"austrian-business-infrastructure/internal/auth"
+	"austrian-business-infrastructure/internal/backfill"
+	"austrian-business-infrastructure/internal/backup"
+	"austrian-business-infrastructure/internal/branding"
+	"austrian-business-infrastructure/internal/breakglass"
+	"austrian-business-infrastructure/internal/buak"
+	"austrian-business-infrastructure/internal/calendarsync"
+	"austrian-business-infrastructure/internal/client"
 	"austrian-business-infrastructure/internal/config"
+	"austrian-business-infrastructure/internal/contract"
+	"austrian-business-infrastructure/internal/customfield"
+	"austrian-business-infrastructure/internal/datev"
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/dienstnehmer"
 	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/eldameldung"
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/endpoint"
+	"austrian-business-infrastructure/internal/entitychange"
+	"austrian-business-infrastructure/internal/fb"
 	"austrian-business-infrastructure/internal/firmenbuch"
+	"austrian-business-infrastructure/internal/foerderplanung"
 	"austrian-business-infrastructure/internal/foerderung"
+	"austrian-business-infrastructure/internal/handover"
+	"austrian-business-infrastructure/internal/idaustria"
+	"austrian-business-infrastructure/internal/ingest"
+	"austrian-business-infrastructure/internal/invitation"
 	"austrian-business-infrastructure/internal/invoice"
+	"austrian-business-infrastructure/internal/jahreserklaerung"
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/jobs"
+	"austrian-business-infrastructure/internal/kammerumlage"
+	"austrian-business-infrastructure/internal/kleinunternehmer"
+	"austrian-business-infrastructure/internal/kommunalsteuer"
+	"austrian-business-infrastructure/internal/mail"
 	"austrian-business-infrastructure/internal/matcher"
 	"austrian-business-infrastructure/internal/monitor"
 	"austrian-business-infrastructure/internal/notification"
+	"austrian-business-infrastructure/internal/partner"
 	"austrian-business-infrastructure/internal/payment"
+	"austrian-business-infrastructure/internal/permission"
+	"austrian-business-infrastructure/internal/pflichten"
 	"austrian-business-infrastructure/internal/profil"
+	"austrian-business-infrastructure/internal/project"
+	"austrian-business-infrastructure/internal/quota"
+	"austrian-business-infrastructure/internal/rawpayload"
+	"austrian-business-infrastructure/internal/refdata"
+	"austrian-business-infrastructure/internal/replay"
+	"austrian-business-infrastructure/internal/retention"
+	"austrian-business-infrastructure/internal/salesdoc"
 	"austrian-business-infrastructure/internal/session"
+	"austrian-business-infrastructure/internal/sigbilling"
+	"austrian-business-infrastructure/internal/system"
+	"austrian-business-infrastructure/internal/team"
+	"austrian-business-infrastructure/internal/teilzeit"
 	"austrian-business-infrastructure/internal/tenant"
+	"austrian-business-infrastructure/internal/tenantstats"
 	"austrian-business-infrastructure/internal/uid"
+	"austrian-business-infrastructure/internal/usage"
 	"austrian-business-infrastructure/internal/user"
 	"austrian-business-infrastructure/internal/uva"
 	"austrian-business-infrastructure/internal/webhook"
+	"austrian-business-infrastructure/internal/workflow"
+	"austrian-business-infrastructure/internal/xmlschema"
 	"austrian-business-infrastructure/internal/zm"
 	"austrian-business-infrastructure/pkg/cache"
 	"austrian-business-infrastructure/pkg/database"
 )
 
 func main() {
+	flag.Parse()
+
 	if err := run(); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
@@ -73,26 +133,58 @@ func run() error {
 	}
 	logger.Info("JWT signing keys loaded")
 
+	// Compile the embedded XML schemas and warn before one stops covering new periods
+	xmlschema.Default().WarnExpiring(logger, time.Now(), xmlschema.ExpiryWarningPeriod)
+
+	// Activate the yearly statutory parameters (SV limits, Lohnsteuer tariff, thresholds)
+	if err := refdata.Init(cfg.ReferenceDataFile, logger, time.Now()); err != nil {
+		return fmt.Errorf("failed to load reference data: %w", err)
+	}
+
+	// Activate the VAT rates per country the invoice validation checks lines against
+	if err := refdata.InitVAT(cfg.VATRatesFile, logger); err != nil {
+		return fmt.Errorf("failed to load VAT rates: %w", err)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// Initialize database connection
+	moduleTimeouts, err := database.ParseModuleTimeouts(cfg.DatabaseTuning.ModuleTimeouts)
+	if err != nil {
+		return fmt.Errorf("invalid DB_MODULE_TIMEOUTS: %w", err)
+	}
 	dbConfig := database.DefaultPostgresConfig(cfg.DatabaseURL)
+	dbConfig.StatementTimeout = cfg.DatabaseTuning.StatementTimeout
+	dbConfig.ModuleTimeouts = moduleTimeouts
+	dbConfig.SlowQueryThreshold = cfg.DatabaseTuning.SlowQueryThreshold
+	dbConfig.Logger = logger
 	db, err := database.NewPool(ctx, dbConfig)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer db.Close()
-	logger.Info("connected to database")
+	logger.Info("connected to database", "statement_timeouts", database.FormatModuleTimeouts())
+	go db.LogPoolMetrics(ctx, logger, cfg.DatabaseTuning.PoolMetricsInterval)
 
-	// Initialize Redis connection
+	// Initialize Redis connection. Without Redis the server starts in
+	// degraded mode unless REDIS_REQUIRED is set; the monitor notices when
+	// it returns.
 	redisConfig := cache.DefaultRedisConfig(cfg.RedisURL)
-	redis, err := cache.NewClient(ctx, redisConfig)
+	redis, err := cache.Dial(redisConfig)
 	if err != nil {
-		return fmt.Errorf("failed to connect to redis: %w", err)
+		return fmt.Errorf("failed to configure redis: %w", err)
 	}
 	defer redis.Close()
-	logger.Info("connected to redis")
+	redisMonitor := cache.NewMonitor(redis, cfg.RedisHealthInterval, logger)
+	if redisMonitor.Check(ctx) {
+		logger.Info("connected to redis")
+	} else if cfg.RedisRequired {
+		return fmt.Errorf("failed to connect to redis (REDIS_REQUIRED is set)")
+	} else {
+		logger.Warn("redis unavailable, starting in degraded mode")
+	}
+	go redisMonitor.Run(ctx)
 
 	// Setup router
 	router := api.NewRouter(logger)
@@ -101,13 +193,71 @@ func run() error {
 	router.Use(api.RequestID)
 	router.Use(api.Recovery(logger))
 	router.Use(api.Logger(logger))
+	if compressionCfg := config.LoadCompressionConfig(); compressionCfg.Enabled {
+		router.Use(api.Compress(api.CompressionConfig{
+			Encodings: compressionCfg.Encodings,
+			MinSize:   compressionCfg.MinSize,
+			GzipLevel: compressionCfg.GzipLevel,
+		}))
+	}
+
+	// Security event stream: auth failures, permission denials, cross-tenant
+	// attempts and rate-limit hits, kept apart from the business audit log
+	secCfg := config.LoadSecurityEventConfig()
+	alertThresholds, err := audit.ParseAlertThresholds(secCfg.AlertThresholds)
+	if err != nil {
+		return fmt.Errorf("invalid SECURITY_ALERT_THRESHOLDS: %w", err)
+	}
+	webhookMinSeverity, ok := audit.ParseSeverity(secCfg.WebhookMinSeverity)
+	if !ok {
+		return fmt.Errorf("invalid SECURITY_EVENTS_WEBHOOK_MIN_SEVERITY %q", secCfg.WebhookMinSeverity)
+	}
+	securityEventRepo := audit.NewSecurityEventRepository(db.Pool)
+	securityStream := audit.NewSecurityStream(securityEventRepo, redis, audit.SecurityStreamConfig{
+		WebhookURL:         secCfg.WebhookURL,
+		WebhookSecret:      secCfg.WebhookSecret,
+		WebhookMinSeverity: webhookMinSeverity,
+		Thresholds:         alertThresholds,
+		Retention:          secCfg.Retention,
+	}, logger)
+	defer securityStream.Close()
+	router.Use(api.SecuritySignals(securityStream))
+
 	router.Use(api.CORS(cfg.AllowedOrigins))
+	router.Use(api.CSRF(&api.CSRFConfig{
+		TrustedOrigins: cfg.CSRFTrustedOrigins,
+		CookieNames:    []string{auth.RefreshTokenCookieName, client.AccessTokenCookieName, client.RefreshTokenCookieName},
+		ExemptPaths:    []string{"/api/v1/csp-report", "/api/v1/notifications/unsubscribe"},
+	}))
 	router.Use(api.SecureHeaders)
-	router.Use(api.ContentSecurityPolicy(api.DefaultCSPConfig()))
+
+	// Content Security Policy, relaxed for the PDFs the document viewer embeds
+	cspConfig := api.DefaultCSPConfig()
+	viewerCSP := api.EmbeddedViewerCSPConfig(cfg.AllowedOrigins)
+	for _, c := range []*api.CSPConfig{cspConfig, viewerCSP} {
+		c.ReportOnly = cfg.CSPReportOnly
+		c.ReportURI = cfg.CSPReportURI
+	}
+	router.Use(api.ContentSecurityPolicy(cspConfig,
+		api.CSPRoute{Pattern: "/api/v1/documents/*/content", Config: viewerCSP},
+	))
+	router.HandleFunc("POST /api/v1/csp-report", api.CSPReportHandler(logger))
 
 	// Health check endpoints
 	router.HandleFunc("GET /health", healthHandler())
-	router.HandleFunc("GET /ready", readyHandler(db, redis))
+	router.HandleFunc("GET /ready", readyHandler(db, redisMonitor))
+
+	// Mock ID Austria provider for signing without a registered client
+	// (IDAUSTRIA_MOCK, honoured with APP_ENV=dev only)
+	if sigCfg := config.LoadSignatureConfig(); sigCfg.IDAustriaMock {
+		mockIdP, err := idaustria.NewMockProvider(sigCfg.IDAustriaIssuer, sigCfg.IDAustriaClientID,
+			sigCfg.IDAustriaClientSecret, sigCfg.IDAustriaRedirectURL)
+		if err != nil {
+			return fmt.Errorf("failed to create ID Austria mock provider: %w", err)
+		}
+		router.Handle(mockIdP.BasePath()+"/", mockIdP)
+		logger.Warn("ID Austria mock provider enabled", "issuer", sigCfg.IDAustriaIssuer)
+	}
 
 	// Initialize repositories (use db.Pool to get underlying *pgxpool.Pool)
 	tenantRepo := tenant.NewRepository(db.Pool)
@@ -117,12 +267,18 @@ func run() error {
 	zmRepo := zm.NewRepository(db.Pool)
 	invoiceRepo := invoice.NewRepository(db.Pool)
 	paymentRepo := payment.NewRepository(db.Pool)
+	salesdocRepo := salesdoc.NewRepository(db.Pool)
+	projectRepo := project.NewRepository(db.Pool)
+	kleinunternehmerRepo := kleinunternehmer.NewRepository(db.Pool)
+	anomalyRepo := anomaly.NewRepository(db.Pool)
 	firmenbuchRepo := firmenbuch.NewRepository(db.Pool)
 	uidRepo := uid.NewRepository(db.Pool)
+	rawPayloadRepo := rawpayload.NewRepository(db.Pool)
 
 	// Förderung-related repositories
 	foerderungRepo := foerderung.NewRepository(db.Pool)
 	antragRepo := antrag.NewRepository(db.Pool)
+	foerderplanungRepo := foerderplanung.NewRepository(db.Pool)
 	profilRepo := profil.NewRepository(db.Pool)
 	monitorRepo := monitor.NewRepository(db.Pool)
 	monitorNotifRepo := monitor.NewNotificationRepository(db.Pool)
@@ -146,44 +302,255 @@ func run() error {
 	zmService := zm.NewService(zmRepo, accountService)
 	invoiceService := invoice.NewService(invoiceRepo)
 	paymentService := payment.NewService(paymentRepo)
-	firmenbuchService := firmenbuch.NewService(firmenbuchRepo, nil) // client nil for now
+	salesdocService := salesdoc.NewService(salesdocRepo, invoiceService)
+	projectService := project.NewService(projectRepo)
+	kleinunternehmerService := kleinunternehmer.NewService(kleinunternehmerRepo)
+	anomalyService := anomaly.NewService(anomalyRepo)
+	assessmentService := assessment.NewService(assessment.NewRepository(db.Pool), uvaRepo, analysis.NewRepository(db.Pool))
+	contractService := contract.NewService(contract.NewRepository(db.Pool), analysis.NewRepository(db.Pool))
+	contractService.SetTimezones(tenantService)
+	// Firmenbuch lookups need the Justiz web service; the worker checks the
+	// watchlists with the same key
+	fbCfg := config.LoadFirmenbuchConfig()
+	var fbRegistry fb.Registry
+	if fbCfg.APIKey != "" {
+		fbRegistry = fb.NewClient(fbCfg.APIKey, fbCfg.TestMode)
+	}
+	firmenbuchService := firmenbuch.NewService(firmenbuchRepo, fbRegistry)
+	firmenbuchService.SetSearchCache(cache.NewStore(redis, "firmenbuch:search:"), fbCfg.SearchCacheTTL)
 	uidService := uid.NewService(uidRepo, accountService)
 
+	// Retain raw FinanzOnline exchanges of submissions as evidence
+	rawPayloadService, err := rawpayload.NewService(rawPayloadRepo, rawpayload.ServiceConfig{
+		EncryptionKey: []byte(cfg.EncryptionKey),
+		Logger:        logger,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create raw payload service: %w", err)
+	}
+	uvaService.SetRawPayloads(rawPayloadService)
+	zmService.SetRawPayloads(rawPayloadService)
+	uidService.SetRawPayloads(rawPayloadService)
+
+	// Legal entity changes decide which account a filing period belongs to
+	entityChangeService := entitychange.NewService(entitychange.NewRepository(db.Pool))
+	uvaService.SetEntityChanges(entityChangeService)
+	zmService.SetEntityChanges(entityChangeService)
+
+	// FinanzOnline and ELDA endpoint sets: scheduled switchovers to migrated
+	// endpoints and fail-fast during announced maintenance windows
+	endpointCfg := config.LoadEndpointConfig()
+	foEndpoints, err := endpoint.NewIntegration(endpoint.FinanzOnline, endpointCfg.FinanzOnlineEndpoints, endpointCfg.FinanzOnlineActive)
+	if err != nil {
+		return fmt.Errorf("invalid FO_ENDPOINTS: %w", err)
+	}
+	eldaEndpoints, err := endpoint.NewIntegration(endpoint.ELDA, endpointCfg.ELDAEndpoints, endpointCfg.ELDAActive)
+	if err != nil {
+		return fmt.Errorf("invalid ELDA_ENDPOINTS: %w", err)
+	}
+	switchovers, err := endpoint.ParseSwitchovers(endpointCfg.Switchovers)
+	if err != nil {
+		return fmt.Errorf("invalid ENDPOINT_SWITCHOVERS: %w", err)
+	}
+	maintenanceWindows, err := endpoint.ParseWindows(endpointCfg.MaintenanceWindows)
+	if err != nil {
+		return fmt.Errorf("invalid ENDPOINT_MAINTENANCE_WINDOWS: %w", err)
+	}
+	endpoints, err := endpoint.NewRegistry(endpoint.NewRepository(db.Pool), endpoint.Config{
+		Integrations:    []endpoint.Integration{foEndpoints, eldaEndpoints},
+		Switchovers:     switchovers,
+		Windows:         maintenanceWindows,
+		RefreshInterval: endpointCfg.RefreshInterval,
+	}, logger)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint configuration: %w", err)
+	}
+	defer endpoints.Close()
+	uvaService.SetEndpoints(endpoints.Resolver(endpoint.FinanzOnline))
+	zmService.SetEndpoints(endpoints.Resolver(endpoint.FinanzOnline))
+	uidService.SetEndpoints(endpoints.Resolver(endpoint.FinanzOnline))
+
+	// Online backfills of large schema changes; the flags steer the
+	// dual-write shims
+	backfillCfg := config.LoadBackfillConfig()
+	backfills, err := backfill.NewService(backfill.NewRepository(db.Pool), backfill.Config{
+		Definitions:     backfill.Registered(),
+		BatchSize:       backfillCfg.BatchSize,
+		BatchPause:      backfillCfg.BatchPause,
+		BatchTimeout:    backfillCfg.BatchTimeout,
+		RefreshInterval: backfillCfg.RefreshInterval,
+	}, logger)
+	if err != nil {
+		return fmt.Errorf("invalid backfill definitions: %w", err)
+	}
+	defer backfills.Close()
+	go backfills.Run(ctx, backfillCfg.Interval)
+
+	// Tenant-defined fields on invoices and Förderungsanträge
+	customFieldService := customfield.NewService(customfield.NewRepository(db.Pool))
+	invoiceService.SetCustomFields(customFieldService)
+
+	// Submission replay: re-render past filings with the current code and
+	// diff them against what was sent. ELDA meldungen can be re-submitted to
+	// the ELDA test endpoint; the server never submits them to production.
+	eldaSandboxClient := elda.NewClientWithConfig(elda.ClientConfig{
+		Endpoint: cfg.ELDATestEndpoint,
+		TestMode: true,
+		Timeout:  time.Duration(cfg.ELDATimeoutSeconds) * time.Second,
+		Logger:   logger,
+	})
+	// The same sandbox answers ELDA dry runs that ask for it
+	eldaMeldungService := eldameldung.NewService(db.Pool, eldaSandboxClient)
+	eldaMeldungService.SetSandbox(eldaSandboxClient)
+	// BUAK Zuschlagsmeldungen and AUAs are sent to ELDA itself
+	eldaClient := elda.NewClientWithConfig(elda.ClientConfig{
+		Resolver: endpoints.Resolver(endpoint.ELDA),
+		Timeout:  time.Duration(cfg.ELDATimeoutSeconds) * time.Second,
+		Logger:   logger,
+	})
+	buakService := buak.NewService(buak.ServiceConfig{
+		Repository:        buak.NewRepository(db.Pool),
+		MeldungRepository: eldameldung.NewRepository(db.Pool),
+		ELDAClient:        eldaClient,
+		RawPayloads:       rawPayloadService,
+		Logger:            logger,
+	})
+	abwesenheitRepo := abwesenheit.NewRepository(db.Pool)
+	abwesenheitService := abwesenheit.NewService(abwesenheit.ServiceConfig{
+		Repository:        abwesenheitRepo,
+		MeldungRepository: eldameldung.NewRepository(db.Pool),
+		ELDAClient:        eldaClient,
+		RawPayloads:       rawPayloadService,
+		Logger:            logger,
+	})
+	teilzeitService := teilzeit.NewService(teilzeit.ServiceConfig{
+		Repository:            teilzeit.NewRepository(db.Pool),
+		MeldungRepository:     eldameldung.NewRepository(db.Pool),
+		AbwesenheitRepository: abwesenheitRepo,
+		Logger:                logger,
+	})
+	replayService := replay.NewService(rawPayloadService)
+	replayService.Register(replay.KindUVA, replay.NewUVASource(uvaService))
+	replayService.Register(replay.KindZM, replay.NewZMSource(zmService))
+	replayService.Register(replay.KindELDAMeldung, replay.NewELDAMeldungSource(
+		eldaMeldungService, eldaSandboxClient, db.Pool))
+
 	// Förderung-related services
 	antragService := antrag.NewService(antragRepo)
+	antragService.SetCustomFields(customFieldService)
+	foerderplanungService := foerderplanung.NewService(foerderplanungRepo)
 	profilService := profil.NewService(profilRepo)
 	monitorService := monitor.NewService(monitorRepo, monitorNotifRepo)
-	matcherService := matcher.NewService(foerderungRepo, matcherSearchRepo, nil, nil) // nil LLM client for now
+	matcherService := matcher.NewService(foerderungRepo, matcherSearchRepo, nil, config.LoadFoerderungConfig()) // nil LLM client for now
+	matcherService.SetResultCache(cache.NewStore(redis, "matcher:"))
 
 	// Additional services for new handlers (apikey only, notification needs docRepo)
 	apikeyService := apikey.NewService(apikeyRepo)
 
 	// Initialize document storage and service with IDOR protection
-	docStorage, err := document.NewStorage(&document.StorageConfig{
-		Type:              document.StorageType(cfg.StorageType),
-		LocalPath:         cfg.StorageLocalPath,
-		S3Endpoint:        cfg.StorageS3Endpoint,
-		S3Bucket:          cfg.StorageS3Bucket,
-		S3Region:          cfg.StorageS3Region,
-		S3AccessKeyID:     cfg.StorageS3AccessKeyID,
-		S3SecretAccessKey: cfg.StorageS3SecretKey,
-		S3UseSSL:          cfg.StorageS3UseSSL,
-	})
+	// Documents go to the storage region of their tenant's data residency
+	docRepo := document.NewRepository(db.Pool)
+	storageCfg := document.NewStorageConfig(config.LoadStorageConfig())
+	docStorage, err := document.NewRegionalStorage(storageCfg, docRepo)
 	if err != nil {
 		return fmt.Errorf("failed to create document storage: %w", err)
 	}
 
-	docRepo := document.NewRepository(db.Pool)
 	// CRITICAL: Use NewServiceWithAccountVerifier to enable tenant isolation on document creation
 	// This prevents IDOR attacks where attackers could create documents for accounts they don't own
 	docService := document.NewServiceWithAccountVerifier(docRepo, docStorage, accountRepo)
 
+	// Soft per-tenant storage quota, checked before new documents are stored
+	quotaService := quota.NewService(quota.NewRepository(db.Pool), cfg.StorageDefaultQuota)
+	docService.SetQuotaChecker(quotaService)
+
+	// Deadlines are overdue once the tenant's day is past them
+	docService.SetTimezones(tenantService)
+
+	// Queue the PDF/A archival conversion of new documents for the worker
+	docJobQueue := job.NewQueue(db.Pool, &job.QueueConfig{Logger: logger})
+	if config.LoadPDFAConfig().Enabled {
+		docService.SetArchivalScheduler(jobs.NewPDFAScheduler(docJobQueue))
+	}
+	// Uploaded e-mails and their attachments are analyzed by the worker
+	docService.SetAnalysisScheduler(jobs.NewAnalysisScheduler(docJobQueue))
+	// Uploaded UID lists are verified with FinanzOnline by the worker
+	uidService.SetBatchScheduler(jobs.NewUIDBatchScheduler(docJobQueue))
+	// The documents list reads precomputed analysis badges after cutover
+	docService.SetReadModel(backfills)
+
+	// Bulk document ingestion via the API: acknowledged at once and turned
+	// into documents by the worker, serving API keys in turn
+	ingestCfg := config.LoadIngestConfig()
+	ingestService := ingest.NewService(ingest.NewRepository(db.Pool), docStorage, docService, ingest.Config{
+		UploadBaseURL:       ingestCfg.UploadBaseURL,
+		UploadKey:           ingestCfg.UploadKey(cfg.EncryptionKey),
+		UploadTTL:           ingestCfg.UploadTTL,
+		MaxPendingPerSource: ingestCfg.MaxPendingPerSource,
+	})
+	ingestService.SetScheduler(jobs.NewIngestionScheduler(docJobQueue))
+	ingestService.SetAccountVerifier(accountRepo)
+	ingestService.SetQuotaChecker(quotaService)
+
+	// Outgoing mail: every sender goes through the mail service, which
+	// applies the suppression list and the tenant's sender identity
+	mailCfg := config.LoadMailConfig()
+	mailProvider, err := mail.NewProvider(mailCfg, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create mail provider: %w", err)
+	}
+	mailRenderer, err := mail.NewRenderer(mailCfg.FromName)
+	if err != nil {
+		return fmt.Errorf("failed to load mail templates: %w", err)
+	}
+	spfInclude := mailCfg.SPFInclude
+	if spfInclude == "" {
+		spfInclude = mail.DefaultSPFInclude(mailCfg.Provider)
+	}
+	mailService := mail.NewService(mailProvider, mail.NewRepository(db.Pool), mailRenderer, mail.ServiceConfig{
+		From:     mailCfg.From,
+		FromName: mailCfg.FromName,
+		DNS:      mail.DNSConfig{SPFInclude: spfInclude, DKIMSelector: mailCfg.DKIMSelector, DKIMDomain: mailCfg.DKIMDomain},
+		Logger:   logger,
+	})
+	mailWebhooks := mail.WebhookConfig{MailgunSigningKey: mailCfg.MailgunSigningKey}
+	if mailCfg.SESTopicARN != "" {
+		mailWebhooks.SNS = mail.NewSNSVerifier(mailCfg.SESTopicARN)
+	}
+	emailService := email.NewMailService(mailService)
+	logger.Info("mail provider configured", "provider", mailService.ProviderName())
+
+	// White-label branding of tenant mails and invoice PDFs
+	brandingService := branding.NewService(db.Pool, cfg.AppURL)
+	mailService.SetBrandProvider(brandingService)
+	invoiceService.SetBrandProvider(brandingService)
+
+	// Custom domains of public links: DNS verification, certificates and
+	// host-based routing of the public routes
+	domainCfg := config.LoadCustomDomainConfig()
+	brandingDomains := branding.DomainConfig{CNAMETarget: domainCfg.CNAMETarget, Logger: logger}
+	if domainCfg.CertificateHook != "" {
+		brandingDomains.Certificates = &branding.CommandCertificateHook{Path: domainCfg.CertificateHook, Timeout: domainCfg.HookTimeout}
+	}
+	brandingService.SetDomainConfig(brandingDomains)
+	router.Use(brandingService.HostMiddleware)
+
 	// Initialize notification service (needs docRepo to be initialized first)
-	notificationService := notification.NewService(notificationRepo, docRepo, nil, &notification.ServiceConfig{
+	notificationService := notification.NewService(notificationRepo, docRepo, emailService, &notification.ServiceConfig{
 		Logger: logger,
-		AppURL: "http://localhost:3000", // TODO: Get from config
+		AppURL: cfg.AppURL,
 	})
 
+	// Per-event notification preferences apply to every mail; notification
+	// emails get a signed one-click unsubscribe link
+	unsubscribeSecret := []byte(config.LoadNotificationConfig().UnsubscribeSecret)
+	if len(unsubscribeSecret) == 0 {
+		derived := sha256.Sum256([]byte("notification-unsubscribe:" + cfg.JWTSecret))
+		unsubscribeSecret = derived[:]
+	}
+	notificationService.SetUnsubscribeSigner(notification.NewUnsubscribeSigner(unsubscribeSecret))
+	mailService.SetPreferences(notificationService)
+
 	// Initialize webhook repository and service
 	webhookRepo := webhook.NewRepository(db.Pool)
 	webhookService := webhook.NewService(webhookRepo, &webhook.ServiceConfig{
@@ -194,6 +561,7 @@ func run() error {
 	jwtConfig := auth.DefaultJWTConfig(cfg.JWTSecret)
 	jwtManager := auth.NewJWTManager(jwtConfig)
 	revocationList := auth.NewTokenRevocationList(redis.Client) // redis.Client is embedded *redis.Client
+	revocationList.SetAvailability(redisMonitor)
 	jwtManager.SetRevocationList(revocationList)
 
 	// Initialize session manager (needs pgxpool.Pool, cache.Client, TTL)
@@ -201,18 +569,75 @@ func run() error {
 
 	// Initialize handlers
 	authHandler := auth.NewHandler(tenantService, userService, sessionManager, jwtManager, logger)
+	authHandler.SetRedis(redis)
+	authHandler.SetRedisAvailability(redisMonitor)
+	loginLimiter := auth.NewRateLimiter(redis.Client)
+	loginLimiter.SetAvailability(redisMonitor)
+	authHandler.SetRateLimiter(loginLimiter)
+	authHandler.SetEmailService(emailService, cfg.AppURL)
+
+	// Per-tenant security policy: password length, required 2FA, session
+	// duration and allowed IP ranges
+	securityPolicyService := auth.NewSecurityPolicyService(auth.NewSecurityPolicyRepository(db.Pool))
+	authHandler.SetSecurityPolicies(securityPolicyService)
+	userService.SetPasswordPolicies(securityPolicyService)
+
+	// Anti-automation on registration and password reset
+	abuseCfg := config.LoadAbuseConfig()
+	abuseGuard := api.NewAbuseGuard(redis, map[string]api.AbuseRule{
+		"register": {
+			IPLimit:    abuseCfg.RegisterPerIP,
+			EmailLimit: abuseCfg.RegisterPerEmail,
+			Window:     abuseCfg.Window,
+			Challenge:  abuseCfg.RequiresChallenge("register"),
+		},
+		"forgot_password": {
+			IPLimit:    abuseCfg.ForgotPasswordPerIP,
+			EmailLimit: abuseCfg.ForgotPasswordPerEmail,
+			Window:     abuseCfg.Window,
+			Challenge:  abuseCfg.RequiresChallenge("forgot_password"),
+		},
+		"reset_password": {
+			IPLimit:   abuseCfg.ResetPasswordPerIP,
+			Window:    abuseCfg.Window,
+			Challenge: abuseCfg.RequiresChallenge("reset_password"),
+		},
+	}, logger)
+	switch abuseCfg.Challenge {
+	case "pow":
+		powSecret := []byte(abuseCfg.PoWSecret)
+		if len(powSecret) == 0 {
+			derived := sha256.Sum256([]byte("abuse-pow:" + cfg.JWTSecret))
+			powSecret = derived[:]
+		}
+		pow := api.NewProofOfWork(powSecret, abuseCfg.PoWDifficulty, 5*time.Minute, redis)
+		abuseGuard.SetChallengeVerifier(pow)
+		router.HandleFunc("GET /api/v1/challenge", pow.Handler())
+	case "captcha":
+		abuseGuard.SetChallengeVerifier(api.NewCaptchaVerifier(abuseCfg.CaptchaVerifyURL, abuseCfg.CaptchaSecret))
+	}
+	authHandler.SetAbuseGuard(abuseGuard)
+
 	accountHandler := account.NewHandler(accountService)
 	uvaHandler := uva.NewHandler(uvaService)
 	zmHandler := zm.NewHandler(zmService)
 	invoiceHandler := invoice.NewHandler(invoiceService)
 	paymentHandler := payment.NewHandler(paymentService)
+	salesdocHandler := salesdoc.NewHandler(salesdocService)
+	projectHandler := project.NewHandler(projectService)
+	kleinunternehmerHandler := kleinunternehmer.NewHandler(kleinunternehmerService)
+	anomalyHandler := anomaly.NewHandler(anomalyService)
 	firmenbuchHandler := firmenbuch.NewHandler(firmenbuchService)
 	uidHandler := uid.NewHandler(uidService)
+	rawPayloadHandler := rawpayload.NewHandler(rawPayloadService)
+	replayHandler := replay.NewHandler(replayService)
 	docHandler := document.NewHandler(docService)
 
 	// Förderung-related handlers
 	foerderungHandler := foerderung.NewHandler(foerderungRepo)
+	foerderungHandler.SetComparisonSources(brandingService, matcherSearchRepo, tenantService)
 	antragHandler := antrag.NewHandler(antragService)
+	foerderplanungHandler := foerderplanung.NewHandler(foerderplanungService)
 	profilHandler := profil.NewHandler(profilService, nil) // nil deriveService for now
 	monitorHandler := monitor.NewHandler(monitorService)
 	matcherHandler := matcher.NewHandler(matcherService, profilRepo)
@@ -221,6 +646,15 @@ func run() error {
 	userHandler := user.NewHandler(userService, logger)
 	sessionHandler := session.NewHandler(sessionManager, logger)
 	auditHandler := audit.NewHandler(auditRepo, logger)
+	auditCfg := config.LoadAuditConfig()
+	auditPolicy := audit.NewPolicy(auditRepo,
+		audit.NewAnonymizer([]byte(auditCfg.IPHashKey), auditCfg.SaltRotation),
+		audit.PolicyConfig{IPMode: auditCfg.IPAnonymization, RetentionDays: auditCfg.RetentionDays})
+	auditHandler.SetPolicy(auditPolicy)
+	// Admins lifting the document access lists are audit-logged
+	docAudit := audit.NewLogger(auditRepo, logger)
+	docAudit.SetPolicy(auditPolicy)
+	docHandler.SetAuditLogger(docAudit)
 	notificationHandler := notification.NewHandler(notificationService)
 	apikeyHandler := apikey.NewHandler(apikeyService, logger)
 	webhookHandler := webhook.NewHandler(webhookRepo, webhookService)
@@ -230,21 +664,215 @@ func run() error {
 	requireAuth := authMiddleware.RequireAuth
 	requireAdmin := authMiddleware.RequireRole("admin")
 
+	// Granular permissions: the built-in roles are fixed bundles, custom
+	// roles of the tenant add to them
+	permissionService := permission.NewService(permission.NewRepository(db.Pool))
+	authMiddleware.SetPermissionResolver(permissionService)
+	requirePermission := func(p permission.Permission) func(http.Handler) http.Handler {
+		return authMiddleware.RequirePermission(p)
+	}
+
+	// Break-glass access of super-admins to tenants: every request under a
+	// grant is written to the tenant's audit log
+	breakGlassCfg := config.LoadBreakGlassConfig()
+	var superAdmins []uuid.UUID
+	for _, id := range breakGlassCfg.SuperAdmins {
+		adminID, err := uuid.Parse(id)
+		if err != nil {
+			return fmt.Errorf("invalid BREAK_GLASS_SUPER_ADMINS entry %q: %w", id, err)
+		}
+		superAdmins = append(superAdmins, adminID)
+	}
+	breakGlassAudit := audit.NewLogger(auditRepo, logger)
+	breakGlassAudit.SetPolicy(auditPolicy)
+	breakGlassService := breakglass.NewService(breakglass.NewRepository(db.Pool), jwtManager, breakGlassAudit, breakglass.Config{
+		SuperAdmins:     superAdmins,
+		DefaultDuration: breakGlassCfg.DefaultDuration,
+		MaxDuration:     breakGlassCfg.MaxDuration,
+		Logger:          logger,
+	})
+	breakGlassService.SetNotifier(emailService)
+	breakGlassService.SetSecurityAuditor(securityStream.Auditor())
+	authMiddleware.SetBreakGlassVerifier(breakGlassService)
+	go breakGlassService.Run(ctx, breakGlassCfg.SweepInterval)
+
+	// Tenant-to-tenant handovers (Steuerberaterwechsel), audited on both sides
+	handoverAudit := audit.NewLogger(auditRepo, logger)
+	handoverAudit.SetPolicy(auditPolicy)
+	handoverService := handover.NewService(handover.NewRepository(db.Pool), docStorage, handoverAudit, logger)
+
+	// Prepaid signature credits and monthly signature statements
+	sigCfg := config.LoadSignatureConfig()
+	sigBillingService := sigbilling.NewService(sigbilling.NewRepository(db.Pool), sigbilling.Config{
+		DefaultPostpaid:            sigCfg.SignatureDefaultPostpaid,
+		DefaultLowBalanceThreshold: sigCfg.SignatureLowBalanceThreshold,
+		BillingURL:                 cfg.AppURL + "/signatures",
+		Logger:                     logger,
+	})
+	sigBillingService.SetNotifier(emailService)
+
+	// Business partners (Stammdaten); the worker re-validates their UIDs
+	// quarterly and invoices to UIDs found invalid are blocked
+	partnerService := partner.NewService(partner.NewRepository(db.Pool), uidService)
+	invoiceService.SetPartnerUIDs(partnerService)
+
+	// Employee master data (Dienstnehmer); ELDA meldungen are prefilled
+	// from it and its employment history is derived from them
+	dienstnehmerService := dienstnehmer.NewService(dienstnehmer.NewRepository(db.Pool), eldameldung.NewRepository(db.Pool))
+	eldaMeldungService.SetDienstnehmerRegistry(dienstnehmerService)
+
+	// Kommunalsteuer and Wiener Dienstgeberabgabe returns, calculated from
+	// the submitted mBGM; the worker reminds of the filing deadline
+	kommunalsteuerService := kommunalsteuer.NewService(kommunalsteuer.NewRepository(db.Pool), accountService)
+	kommunalsteuerService.SetRawPayloads(rawPayloadService)
+	kommunalsteuerService.SetEndpoints(endpoints.Resolver(endpoint.FinanzOnline))
+
+	// Kammerumlage 1, calculated quarterly from the input tax of the UVA
+	// filings; the worker reminds of the payment deadline
+	kammerumlageService := kammerumlage.NewService(kammerumlage.NewRepository(db.Pool), accountService)
+
+	// Draft Umsatzsteuerjahreserklärung (U1), aggregated from the UVA filings
+	jahreserklaerungService := jahreserklaerung.NewService(jahreserklaerung.NewRepository(db.Pool), accountService)
+
+	// DATEV Buchungsstapel export for German parent companies, derived from
+	// the invoices behind the UVA
+	datevService := datev.NewService(datev.NewRepository(db.Pool), uvaRepo)
+
+	// Signed deletion protocols of the worker's retention runs, for tenant
+	// admins; the key must match the worker's to verify them
+	var retentionSigner *retention.Signer
+	if key := config.LoadRetentionConfig().ProtocolKey(cfg.EncryptionKey); key != nil {
+		retentionSigner = retention.NewSigner(key)
+	}
+	retentionService := retention.NewService(retention.NewRepository(db.Pool), nil, retentionSigner)
+
+	// Step-based internal processes with assignments; the worker fires their
+	// timers and escalates those past their SLA
+	workflowService := workflow.NewService(workflow.NewRepository(db.Pool))
+
+	// Pflichten-Radar: the outstanding obligations of all companies, scored
+	pflichtenService := pflichten.NewService(pflichten.NewRepository(db.Pool))
+	pflichtenService.SetTimezones(tenantService)
+
+	// Google and Microsoft 365 calendars: deadlines and action items are
+	// pushed as events; busy times cut the advisors' Förderung capacity
+	calCfg := config.LoadCalendarConfig()
+	calendarService := calendarsync.NewService(calendarsync.NewRepository(db.Pool), []byte(cfg.EncryptionKey),
+		cache.NewStore(redis, "calendar:oauth_state:"),
+		calendarsync.Config{AppURL: cfg.AppURL, HorizonDays: calCfg.HorizonDays}, logger,
+		calendarsync.NewProviders(calCfg.RedirectBaseURL, calCfg.GoogleClientID, calCfg.GoogleClientSecret,
+			calCfg.MicrosoftClientID, calCfg.MicrosoftClientSecret)...)
+	calendarService.SetTimezones(tenantService)
+	foerderplanungService.SetBusySource(calendarService)
+
+	// Single sign-on with the tenants' own identity providers (ID Austria,
+	// Entra ID, Google Workspace, any OIDC issuer) next to password login
+	ssoCfg := config.LoadSSOConfig()
+	ssoService := auth.NewSSOService(auth.NewSSORepository(db.Pool), userService, tenantService,
+		[]byte(cfg.EncryptionKey), cache.NewStore(redis, "sso:"), ssoCfg.RedirectBaseURL)
+
+	// Teams, deputies of absent users and bulk user import. Imported users
+	// are invited and join their teams when accepting.
+	teamService := team.NewService(team.NewRepository(db.Pool), userRepo, team.Config{AppURL: cfg.AppURL, Logger: logger})
+	invitationService := invitation.NewService(invitation.NewRepository(db.Pool), userRepo)
+	invitationService.SetAcceptHook(teamService)
+	invitationService.SetPasswordPolicies(securityPolicyService)
+	teamService.SetInviter(invitationService, emailService)
+	notificationService.SetDeputies(teamService)
+
+	// Product analytics events (ANALYTICS_ENABLED), pseudonymized
+	analyticsCfg := config.LoadAnalyticsConfig()
+	analyticsEmitter, err := analytics.NewFromConfig(db.Pool, analyticsCfg, logger)
+	if err != nil {
+		return err
+	}
+	defer analyticsEmitter.Close()
+	tenantService.SetAnalytics(analyticsEmitter)
+	invitationService.SetAnalytics(analyticsEmitter)
+	accountService.SetAnalytics(analyticsEmitter)
+	docService.SetAnalytics(analyticsEmitter)
+	uvaService.SetAnalytics(analyticsEmitter)
+	zmService.SetAnalytics(analyticsEmitter)
+	invoiceService.SetAnalytics(analyticsEmitter)
+
 	// Register routes
 	// Auth routes (no auth required for login/register)
 	authHandler.RegisterRoutes(router, requireAuth)
+	auth.NewSSOHandler(ssoService, authHandler, cfg.AppURL, logger).RegisterRoutes(router, requireAuth, requireAdmin)
+	auth.NewSecurityPolicyHandler(securityPolicyService, authHandler, logger).RegisterRoutes(router, requireAuth, requireAdmin)
 
 	// Protected routes
 	accountHandler.RegisterRoutes(router, requireAuth, requireAdmin)
-	uvaHandler.RegisterRoutes(router, requireAuth, requireAdmin)
+	uvaHandler.RegisterRoutes(router, requireAuth, requirePermission)
 	zmHandler.RegisterRoutes(router, requireAuth, requireAdmin)
-	invoiceHandler.RegisterRoutes(router, requireAuth, requireAdmin)
+	invoiceHandler.RegisterRoutes(router, requireAuth, requirePermission)
+	customfield.NewHandler(customFieldService).RegisterRoutes(router, requireAuth, requireAdmin)
 	paymentHandler.RegisterRoutes(router, requireAuth, requireAdmin)
+	salesdocHandler.RegisterRoutes(router, requireAuth, requireAdmin)
+	projectHandler.RegisterRoutes(router, requireAuth, requireAdmin)
+	kleinunternehmerHandler.RegisterRoutes(router, requireAuth, requireAdmin)
+	tenant.NewHandler(tenantService).RegisterRoutes(router, requireAuth, requireAdmin)
+	anomalyHandler.RegisterRoutes(router, requireAuth, requireAdmin)
+	assessment.NewHandler(assessmentService).RegisterRoutes(router, requireAuth)
+	contract.NewHandler(contractService).RegisterRoutes(router, requireAuth)
+	partner.NewHandler(partnerService).RegisterRoutes(router, requireAuth, requireAdmin)
+	dienstnehmer.NewHandler(dienstnehmerService).RegisterRoutes(router, requireAuth)
+	kommunalsteuer.NewHandler(kommunalsteuerService).RegisterRoutes(router, requireAuth, requireAdmin)
+	kammerumlage.NewHandler(kammerumlageService).RegisterRoutes(router, requireAuth, requireAdmin)
+	jahreserklaerung.NewHandler(jahreserklaerungService).RegisterRoutes(router, requireAuth, requireAdmin)
+	datev.NewHandler(datevService).RegisterRoutes(router, requireAuth, requireAdmin)
+	retention.NewHandler(retentionService).RegisterRoutes(router, requireAuth, requireAdmin)
+	workflow.NewHandler(workflowService).RegisterRoutes(router, requireAuth, requireAdmin)
+	pflichten.NewHandler(pflichtenService).RegisterRoutes(router, requireAuth)
+	calendarsync.NewHandler(calendarService, cfg.AppURL, logger).RegisterRoutes(router, requireAuth)
+	foerderplanungHandler.RegisterRoutes(router, requireAuth, requireAdmin)
+	refdata.NewHandler().RegisterRoutes(router, requireAuth)
 	firmenbuchHandler.RegisterRoutes(router, requireAuth, requireAdmin)
 	uidHandler.RegisterRoutes(router, requireAuth, requireAdmin)
+	rawPayloadHandler.RegisterRoutes(router, requireAuth, requireAdmin)
+	replayHandler.RegisterRoutes(router, requireAuth, requireAdmin)
+
+	// Per-tenant usage series, populated by the nightly usage_aggregation job
+	usage.NewHandler(usage.NewService(usage.NewRepository(db.Pool))).RegisterRoutes(router, requireAuth, requireAdmin)
+
+	// Sender identity, suppression list and provider webhooks
+	mail.NewHandler(mailService, mailWebhooks).RegisterRoutes(router, requireAuth, requireAdmin)
+
+	// Tenant branding, logo upload and the public branding of client pages
+	branding.NewHandler(brandingService).RegisterRoutes(router, requireAuth, requireAdmin)
+
+	// Storage usage breakdown and quota
+	quota.NewHandler(quotaService).RegisterRoutes(router, requireAuth, requireAdmin)
+	document.NewResidencyHandler(docRepo, storageCfg).RegisterRoutes(router, requireAuth, requireAdmin)
+
+	// Signature credit balance, packages and monthly statements
+	sigbilling.NewHandler(sigBillingService).RegisterRoutes(router, requireAuth, requireAdmin)
+
+	// Activity feed per invoice, document and Antrag
+	activity.NewHandler(activity.NewService(activity.NewRepository(db.Pool))).RegisterRoutes(router, requireAuth)
+
+	// System info and connection pool metrics (admin-only)
+	system.NewHandler(nil).WithDatabase(db).WithAbuseGuard(abuseGuard).WithMatcherCache(matcherService).WithAIRoutes(ai.NewRouteMetrics(db.Pool)).RegisterRoutes(router, requireAuth, requireAdmin)
+
+	// Demo tenant generator for sales environments
+	if cfg.DemoSeedingEnabled {
+		demoService, err := demo.NewService(db.Pool, []byte(cfg.EncryptionKey), docStorage)
+		if err != nil {
+			return fmt.Errorf("failed to create demo service: %w", err)
+		}
+		demo.NewHandler(demoService).RegisterRoutes(router, requireAuth, requireAdmin)
+		logger.Warn("demo tenant seeding enabled, do not use in production")
+	}
 
 	// User management routes (admin-only for modifications)
 	userHandler.RegisterRoutes(router, requireAuth, requireAdmin)
+	permission.NewHandler(permissionService).RegisterRoutes(router, requireAuth, requirePermission)
+
+	// Invitations, teams, deputies and user import
+	invitationHandler := invitation.NewHandler(invitationService, tenantService, jwtManager, sessionManager, logger)
+	invitationHandler.SetSecurityPolicies(securityPolicyService, authHandler.ClientIP)
+	invitationHandler.RegisterRoutes(router, authMiddleware)
+	team.NewHandler(teamService).RegisterRoutes(router, requireAuth, requireAdmin)
 
 	// Session management routes (users can manage their own sessions)
 	sessionHandler.RegisterRoutes(router, requireAuth)
@@ -252,17 +880,74 @@ func run() error {
 	// Audit log routes (admin-only)
 	auditHandler.RegisterRoutes(router, requireAuth, requireAdmin)
 
+	// Break-glass routes (super-admins grant, tenant admins review and revoke)
+	breakglass.NewHandler(breakGlassService).RegisterRoutes(router, requireAuth, requireAdmin)
+
+	// Tenant stats for support triage (tenant admins, super-admins audited)
+	tenantStatsService := tenantstats.NewService(tenantstats.NewRepository(db.Pool))
+	tenantStatsService.SetStorage(quotaService)
+	tenantStatsHandler := tenantstats.NewHandler(tenantStatsService)
+	tenantStatsHandler.SetSuperAdmins(breakGlassService, breakGlassAudit)
+	tenantStatsHandler.RegisterRoutes(router, requireAuth, requireAdmin)
+
+	// Tenant handover routes (admin-only)
+	handover.NewHandler(handoverService).RegisterRoutes(router, requireAuth, requireAdmin)
+
+	// Legal entity change routes (Rechtsformwechsel, merger)
+	entitychange.NewHandler(entityChangeService).RegisterRoutes(router, requireAuth, requireAdmin)
+	eldameldung.NewHandler(eldaMeldungService, nil).RegisterDryRunRoute(router, requireAuth)
+
+	// Security event export for SIEM collectors (bearer token, all tenants)
+	if secCfg.ExportToken != "" {
+		audit.NewSecurityEventHandler(securityEventRepo, secCfg.ExportToken, logger).RegisterRoutes(router)
+	}
+
+	// Maintenance API for ops tooling (maintenance token, platform-wide):
+	// backup orchestration, endpoint switchovers, backfills, the analytics
+	// funnel and the job SLA dashboard
+	if cfg.MaintenanceToken != "" {
+		backupCfg := config.LoadBackupConfig()
+		backupRepo := backup.NewRepository(db.Pool)
+		backupService := backup.NewService(backupRepo, db.Pool, job.NewQueue(db.Pool, &job.QueueConfig{Logger: logger}), backup.Config{
+			DBSnapshotHook:      backupCfg.DBSnapshotHook,
+			StorageSnapshotHook: backupCfg.StorageSnapshotHook,
+			RestoreHook:         backupCfg.RestoreHook,
+			ScratchDatabaseURL:  backupCfg.ScratchDatabaseURL,
+			HookTimeout:         backupCfg.HookTimeout,
+			DrainTimeout:        backupCfg.DrainTimeout,
+			Tables:              backupCfg.ManifestTables,
+		}, logger)
+		defer backupService.Close()
+		backup.NewHandler(backupService, backupRepo, cfg.MaintenanceToken, logger).RegisterRoutes(router)
+		endpoint.NewHandler(endpoints, cfg.MaintenanceToken, logger).RegisterRoutes(router)
+		backfill.NewHandler(backfills, cfg.MaintenanceToken, logger).RegisterRoutes(router)
+		if analyticsCfg.Enabled && analyticsCfg.Sink == analytics.SinkPostgres {
+			analytics.NewHandler(analytics.NewRepository(db.Pool), cfg.MaintenanceToken, logger).RegisterRoutes(router)
+		}
+		jobSLA, err := job.NewSLAConfig(config.LoadJobSLAConfig())
+		if err != nil {
+			return fmt.Errorf("invalid JOB_SLA_OVERRIDES: %w", err)
+		}
+		job.NewSLAHandler(job.NewRepository(db.Pool), jobSLA, cfg.MaintenanceToken, logger).RegisterRoutes(router)
+	}
+
 	// 2FA setup routes (authenticated users)
 	authHandler.Register2FARoutes(router, requireAuth)
 
 	// API key management routes (authenticated users)
 	apikeyHandler.RegisterRoutes(router, requireAuth)
 
+	// Document ingestion routes (API keys or users)
+	apikeyMiddleware := apikey.NewMiddleware(apikeyService)
+	ingest.NewHandler(ingestService).RegisterRoutes(router,
+		apikey.CombinedAuth(requireAuth, apikeyMiddleware.AuthenticateAPIKey), apikeyMiddleware.RequireScope)
+
 	// Notification preferences routes (wrap with auth middleware)
 	notifMux := http.NewServeMux()
 	notificationHandler.RegisterRoutes(notifMux)
 	router.Handle("/api/v1/notifications/preferences", requireAuth(notifMux))
 	router.Handle("/api/v1/notifications/preferences/", requireAuth(notifMux))
+	notificationHandler.RegisterPublicRoutes(router)
 
 	// Webhook routes (wrap with auth middleware, admin-only for create/update/delete)
 	webhookMux := http.NewServeMux()
@@ -274,8 +959,9 @@ func run() error {
 	// Wrap document routes with auth middleware since RegisterRoutes uses raw mux
 	docMux := http.NewServeMux()
 	docHandler.RegisterRoutes(docMux)
-	router.Handle("/api/v1/documents", requireAuth(docMux))
-	router.Handle("/api/v1/documents/", requireAuth(docMux))
+	docRoutes := requireAuth(docHandler.AccessOverride(docMux))
+	router.Handle("/api/v1/documents", docRoutes)
+	router.Handle("/api/v1/documents/", docRoutes)
 
 	// Förderung-related routes using chi router (these handlers use chi.URLParam)
 	chiRouter := chi.NewRouter()
@@ -297,8 +983,37 @@ func run() error {
 	router.Handle("/api/v1/foerderungssuche", requireAuth(chiRouter))
 	router.Handle("/api/v1/foerderungssuche/", requireAuth(chiRouter))
 
+	// BUAK routes (chi handler scoped to the tenant's ELDA accounts)
+	buakRouter := chi.NewRouter()
+	buakRouter.Route("/api/v1", buak.NewHandler(buakService).RegisterRoutes)
+	router.Handle("/api/v1/buak/", requireAuth(buakRouter))
+
+	// Absence and AUA routes (chi handler scoped to the tenant's ELDA accounts)
+	abwesenheitRouter := chi.NewRouter()
+	abwesenheitRouter.Route("/api/v1", abwesenheit.NewHandler(abwesenheitService).RegisterRoutes)
+	router.Handle("/api/v1/abwesenheiten", requireAuth(abwesenheitRouter))
+	router.Handle("/api/v1/abwesenheiten/", requireAuth(abwesenheitRouter))
+	router.Handle("/api/v1/aua/", requireAuth(abwesenheitRouter))
+
+	// Part-time scenario routes (chi handler scoped to the tenant's ELDA accounts)
+	teilzeitRouter := chi.NewRouter()
+	teilzeitRouter.Route("/api/v1", teilzeit.NewHandler(teilzeitService).RegisterRoutes)
+	router.Handle("/api/v1/teilzeit/", requireAuth(teilzeitRouter))
+
 	logger.Info("API routes registered")
 
+	// Performance smoke mode: measure the hot paths against latency budgets
+	// instead of serving traffic
+	if *perfSmoke {
+		return runPerfSmoke(ctx, logger, perfSmokeDeps{
+			handler:       router,
+			db:            db.Pool,
+			encryptionKey: []byte(cfg.EncryptionKey),
+			storage:       docStorage,
+			documents:     docService,
+		})
+	}
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         cfg.Address(),
@@ -354,8 +1069,9 @@ func healthHandler() http.HandlerFunc {
 	}
 }
 
-// readyHandler returns readiness probe handler
-func readyHandler(db *database.Pool, redis *cache.Client) http.HandlerFunc {
+// readyHandler returns readiness probe handler. Without Redis the server is
+// ready but degraded; only a database outage makes it not ready.
+func readyHandler(db *database.Pool, redis *cache.Monitor) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -370,22 +1086,37 @@ func readyHandler(db *database.Pool, redis *cache.Client) http.HandlerFunc {
 			checks["database"] = "healthy"
 		}
 
+		resp := map[string]interface{}{"checks": checks}
+
 		// Check Redis - don't leak error details to unauthenticated callers
-		if err := redis.Health(ctx); err != nil {
-			checks["redis"] = "unhealthy"
-			healthy = false
-		} else {
+		if redis.Check(ctx) {
 			checks["redis"] = "healthy"
+		} else {
+			checks["redis"] = "unavailable"
+			resp["degraded"] = map[string]interface{}{
+				"since": redis.Status().Since,
+				"features": map[string]string{
+					"login":             "rejected",
+					"two_factor_login":  "disabled",
+					"two_factor_setup":  "disabled",
+					"password_reset":    "rejected",
+					"registration":      "rejected",
+					"revocation_checks": "flagged",
+				},
+			}
 		}
 
 		status := http.StatusOK
-		if !healthy {
+		switch {
+		case !healthy:
 			status = http.StatusServiceUnavailable
+			resp["status"] = "not_ready"
+		case resp["degraded"] != nil:
+			resp["status"] = "degraded"
+		default:
+			resp["status"] = "ready"
 		}
 
-		api.JSONResponse(w, status, map[string]interface{}{
-			"status": map[bool]string{true: "ready", false: "not_ready"}[healthy],
-			"checks": checks,
-		})
+		api.JSONResponse(w, status, resp)
 	}
 }
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/internal/auth"
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/perf"
+)
+
+// Performance smoke mode flags
+var (
+	perfSmoke           = flag.Bool("perf-smoke", false, "Measure the hot paths against a synthetic tenant and exit non-zero if a latency budget is exceeded")
+	perfSmokeIterations = flag.Int("perf-iterations", 50, "Measured iterations per check in performance smoke mode")
+	perfSmokeBudgets    = flag.String("perf-budgets", "", "Budgets file for performance smoke mode (default: embedded budgets)")
+)
+
+// perfSmokeDeps are the parts of the server the smoke run exercises
+type perfSmokeDeps struct {
+	handler       http.Handler
+	db            *pgxpool.Pool
+	encryptionKey []byte
+	storage       document.Storage
+	documents     *document.Service
+}
+
+// runPerfSmoke seeds a synthetic demo tenant, measures login, the list
+// endpoints, document upload and analysis enqueue against the latency
+// budgets and removes the tenant again. It must run against a dedicated CI or
+// staging database, never production.
+func runPerfSmoke(ctx context.Context, logger *slog.Logger, deps perfSmokeDeps) error {
+	budgets, err := perf.LoadBudgets(*perfSmokeBudgets)
+	if err != nil {
+		return err
+	}
+
+	demoService, err := demo.NewService(deps.db, deps.encryptionKey, deps.storage)
+	if err != nil {
+		return fmt.Errorf("failed to create demo service: %w", err)
+	}
+
+	// List endpoints regressed with realistic volumes, so seed more than a demo
+	seeded, err := demoService.Seed(ctx, demo.Options{
+		Name:      "Perf Smoke GmbH",
+		Seed:      time.Now().Unix(),
+		Employees: 20,
+		Documents: 300,
+		Invoices:  300,
+	}, "perf-smoke", nil)
+	if err != nil {
+		return fmt.Errorf("failed to seed synthetic tenant: %w", err)
+	}
+	logger.Info("synthetic tenant seeded", "tenant_id", seeded.TenantID, "slug", seeded.Slug)
+	defer func() {
+		if err := demoService.Teardown(context.WithoutCancel(ctx), seeded.TenantID, nil); err != nil {
+			logger.Error("failed to remove synthetic tenant", "tenant_id", seeded.TenantID, "error", err)
+		}
+	}()
+
+	loginBody := func() io.Reader {
+		body, _ := json.Marshal(auth.LoginRequest{Email: seeded.OwnerEmail, Password: seeded.OwnerPassword})
+		return bytes.NewReader(body)
+	}
+	token, err := perfSmokeLogin(deps.handler, loginBody())
+	if err != nil {
+		return err
+	}
+
+	var accountID, documentID uuid.UUID
+	err = deps.db.QueryRow(ctx, `
+		SELECT account_id, id FROM documents WHERE tenant_id = $1 ORDER BY received_at DESC LIMIT 1
+	`, seeded.TenantID).Scan(&accountID, &documentID)
+	if err != nil {
+		return fmt.Errorf("failed to find a seeded document: %w", err)
+	}
+
+	queue := job.NewQueue(deps.db, &job.QueueConfig{WorkerID: "perf-smoke", Logger: logger})
+
+	var checks []perf.Check
+	for _, budget := range budgets.Budgets {
+		switch {
+		case budget.Name == "login":
+			checks = append(checks, perf.HTTPCheck(budget, deps.handler, "", loginBody))
+		case budget.Path != "":
+			checks = append(checks, perf.HTTPCheck(budget, deps.handler, token, nil))
+		case budget.Name == "document_upload":
+			checks = append(checks, perf.Check{Name: budget.Name, Run: func(ctx context.Context, i int) error {
+				_, err := deps.documents.Create(ctx, seeded.TenantID.String(), &document.CreateDocumentInput{
+					AccountID:   accountID,
+					ExternalID:  fmt.Sprintf("perf-smoke-%d", i),
+					Type:        "bescheid",
+					Title:       fmt.Sprintf("Perf smoke document %d", i),
+					Sender:      "Finanzamt Österreich",
+					ReceivedAt:  time.Now(),
+					Content:     bytes.NewReader(perfSmokePDF(i)),
+					ContentType: "application/pdf",
+				})
+				return err
+			}})
+		case budget.Name == "analysis_enqueue":
+			// Jobs are scheduled after the run so that a worker sharing the
+			// database never picks them up; the teardown removes them.
+			checks = append(checks, perf.Check{Name: budget.Name, Run: func(ctx context.Context, _ int) error {
+				opts := job.DefaultEnqueueOptions()
+				opts.RunAt = time.Now().Add(24 * time.Hour)
+				_, err := queue.Enqueue(ctx, seeded.TenantID, job.TypeDocumentAnalysis, map[string]interface{}{
+					"document_id": documentID,
+					"tenant_id":   seeded.TenantID,
+				}, opts)
+				return err
+			}})
+		default:
+			logger.Warn("budget has no smoke check", "budget", budget.Name)
+		}
+	}
+
+	runner := &perf.Runner{Budgets: budgets, Iterations: *perfSmokeIterations, Warmup: 5}
+	report, err := runner.Run(ctx, checks)
+	if err != nil {
+		return err
+	}
+	if err := report.Write(os.Stdout); err != nil {
+		return err
+	}
+	if !report.Passed() {
+		return errors.New("performance budgets exceeded")
+	}
+	logger.Info("performance budgets met")
+	return nil
+}
+
+// perfSmokeLogin logs the synthetic owner in and returns the access token
+func perfSmokeLogin(handler http.Handler, body io.Reader) (string, error) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", body)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		return "", fmt.Errorf("synthetic login failed: status %d", rec.Code)
+	}
+
+	var resp auth.LoginResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.AccessToken == "" {
+		return "", errors.New("synthetic login returned no access token")
+	}
+	return resp.AccessToken, nil
+}
+
+// perfSmokePDF returns a distinct document of about 64 KB, the size of a
+// typical Bescheid, so that content deduplication does not short-circuit
+func perfSmokePDF(i int) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%%PDF-1.4\n%% perf smoke %d %d\n", i, time.Now().UnixNano())
+	b.WriteString(strings.Repeat("BT /F1 10 Tf (Bescheid ueber die Festsetzung) Tj ET\n", 1200))
+	b.WriteString("%%EOF\n")
+	return b.Bytes()
+}
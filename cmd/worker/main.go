@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -10,13 +11,54 @@ import (
 	"syscall"
 	"time"
 
+	"austrian-business-infrastructure/internal/account"
+	"austrian-business-infrastructure/internal/ai"
 	"austrian-business-infrastructure/internal/analysis"
+	"austrian-business-infrastructure/internal/analytics"
+	"austrian-business-infrastructure/internal/anomaly"
+	"austrian-business-infrastructure/internal/assessment"
+	"austrian-business-infrastructure/internal/audit"
+	"austrian-business-infrastructure/internal/backup"
+	"austrian-business-infrastructure/internal/branding"
+	"austrian-business-infrastructure/internal/calendarsync"
 	"austrian-business-infrastructure/internal/config"
+	"austrian-business-infrastructure/internal/contract"
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/eldameldung"
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/endpoint"
+	"austrian-business-infrastructure/internal/fb"
+	"austrian-business-infrastructure/internal/firmenbuch"
+	"austrian-business-infrastructure/internal/foerderung"
+	"austrian-business-infrastructure/internal/ingest"
 	"austrian-business-infrastructure/internal/job"
 	"austrian-business-infrastructure/internal/jobs"
+	"austrian-business-infrastructure/internal/kammerumlage"
+	"austrian-business-infrastructure/internal/kleinunternehmer"
+	"austrian-business-infrastructure/internal/kommunalsteuer"
+	"austrian-business-infrastructure/internal/mail"
+	"austrian-business-infrastructure/internal/notification"
+	"austrian-business-infrastructure/internal/partner"
+	"austrian-business-infrastructure/internal/pdfa"
+	"austrian-business-infrastructure/internal/quota"
+	"austrian-business-infrastructure/internal/rawpayload"
+	"austrian-business-infrastructure/internal/refdata"
+	"austrian-business-infrastructure/internal/retention"
+	"austrian-business-infrastructure/internal/sigbilling"
+	"austrian-business-infrastructure/internal/storage"
+	"austrian-business-infrastructure/internal/system"
+	"austrian-business-infrastructure/internal/tenant"
+	"austrian-business-infrastructure/internal/uid"
+	"austrian-business-infrastructure/internal/usage"
+	"austrian-business-infrastructure/internal/user"
+	"austrian-business-infrastructure/internal/uva"
+	"austrian-business-infrastructure/internal/webhook"
+	"austrian-business-infrastructure/internal/workflow"
 	"austrian-business-infrastructure/pkg/cache"
 	"austrian-business-infrastructure/pkg/database"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
 func main() {
@@ -48,17 +90,31 @@ func run() error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	// Activate the yearly statutory parameters used by the monitors
+	if err := refdata.Init(cfg.ReferenceDataFile, logger, time.Now()); err != nil {
+		return fmt.Errorf("failed to load reference data: %w", err)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// Initialize database connection
+	moduleTimeouts, err := database.ParseModuleTimeouts(cfg.DatabaseTuning.ModuleTimeouts)
+	if err != nil {
+		return fmt.Errorf("invalid DB_MODULE_TIMEOUTS: %w", err)
+	}
 	dbConfig := database.DefaultPostgresConfig(cfg.DatabaseURL)
+	dbConfig.StatementTimeout = cfg.DatabaseTuning.StatementTimeout
+	dbConfig.ModuleTimeouts = moduleTimeouts
+	dbConfig.SlowQueryThreshold = cfg.DatabaseTuning.SlowQueryThreshold
+	dbConfig.Logger = logger
 	db, err := database.NewPool(ctx, dbConfig)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer db.Close()
-	logger.Info("connected to database")
+	logger.Info("connected to database", "statement_timeouts", database.FormatModuleTimeouts())
+	go db.LogPoolMetrics(ctx, logger, cfg.DatabaseTuning.PoolMetricsInterval)
 
 	// Initialize Redis connection (optional for worker, used for distributed locks)
 	var redis *cache.Client
@@ -79,9 +135,42 @@ func run() error {
 		Logger:   logger,
 	})
 
+	// Product analytics events of completed analyses (ANALYTICS_ENABLED)
+	analyticsEmitter, err := analytics.NewFromConfig(db.Pool, config.LoadAnalyticsConfig(), logger)
+	if err != nil {
+		return err
+	}
+	defer analyticsEmitter.Close()
+
+	// Route analysis completions between the AI providers, failing over
+	// while one is down (CLAUDE_*, OPENAI_*, AI_ROUTES)
+	aiRouter, err := ai.NewRouterFromConfig(config.LoadAIConfig())
+	if err != nil {
+		return err
+	}
+	if aiRouter != nil {
+		aiRouter.SetRecorder(ai.NewRouteMetrics(db.Pool))
+		aiRouter.SetLogger(logger)
+	}
+
 	// Initialize job registry with handlers
 	registry := job.NewRegistry()
-	registerJobHandlers(registry, db, redis, logger)
+	registerJobHandlers(registry, db, redis, analyticsEmitter, aiRouter, cfg, logger)
+
+	// Turn documents pushed via the ingestion API into documents
+	if err := registerDocumentIngestion(registry, queue, db, analyticsEmitter, cfg, logger); err != nil {
+		logger.Error("document ingestion disabled", "error", err)
+	}
+
+	// Archive stored documents as PDF/A when the conversion tools are installed
+	pdfaCfg := config.LoadPDFAConfig()
+	var pdfaSweeper *jobs.PDFASweeper
+	if pdfaCfg.Enabled {
+		pdfaSweeper, err = registerPDFAConversion(registry, queue, db, pdfaCfg, logger)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Initialize worker
 	worker := job.NewWorker(queue, registry, &job.WorkerConfig{
@@ -89,6 +178,7 @@ func run() error {
 		Concurrency:     cfg.WorkerConcurrency,
 		PollInterval:    cfg.PollInterval,
 		ShutdownTimeout: cfg.ShutdownTimeout,
+		Version:         system.Version,
 		Logger:          logger,
 	})
 
@@ -98,7 +188,7 @@ func run() error {
 	})
 
 	// Start health check server
-	healthServer := startHealthServer(cfg.HealthPort, db, redis, worker, logger)
+	healthServer := startHealthServer(cfg.HealthPort, db, redis, worker, aiRouter, logger)
 
 	// Start scheduler
 	schedulerDone := make(chan struct{})
@@ -109,6 +199,46 @@ func run() error {
 		}
 	}()
 
+	// Start restore checks for completed backups
+	backupCfg := config.LoadBackupConfig()
+	if backupCfg.RestoreCheckEnabled() {
+		checker := backup.NewRestoreChecker(backup.NewRepository(db.Pool), backup.Config{
+			RestoreHook:        backupCfg.RestoreHook,
+			ScratchDatabaseURL: backupCfg.ScratchDatabaseURL,
+			HookTimeout:        backupCfg.HookTimeout,
+			Tables:             backupCfg.ManifestTables,
+		}, backupCfg.RestoreCheckPoll, logger)
+		go func() {
+			if err := checker.Run(ctx); err != nil && ctx.Err() == nil {
+				logger.Error("restore check error", "error", err)
+			}
+		}()
+	}
+
+	// Start judging job types against their SLA (JOB_SLA_*)
+	slaCfg := config.LoadJobSLAConfig()
+	if slaCfg.Enabled {
+		sla, err := job.NewSLAConfig(slaCfg)
+		if err != nil {
+			return fmt.Errorf("invalid JOB_SLA_OVERRIDES: %w", err)
+		}
+		monitor := job.NewSLAMonitor(job.NewRepository(db.Pool), sla, slaCfg.CheckInterval, slaCfg.AlertWebhookURL, logger)
+		go func() {
+			if err := monitor.Run(ctx); err != nil && ctx.Err() == nil {
+				logger.Error("job SLA monitor error", "error", err)
+			}
+		}()
+	}
+
+	// Start queueing documents that still lack a PDF/A rendition
+	if pdfaSweeper != nil {
+		go func() {
+			if err := pdfaSweeper.Run(ctx); err != nil && ctx.Err() == nil {
+				logger.Error("PDF/A sweep error", "error", err)
+			}
+		}()
+	}
+
 	// Start worker
 	workerDone := make(chan struct{})
 	go func() {
@@ -163,10 +293,11 @@ func run() error {
 }
 
 // registerJobHandlers registers all job handlers with the registry
-func registerJobHandlers(registry *job.Registry, db *database.Pool, redis *cache.Client, logger *slog.Logger) {
+func registerJobHandlers(registry *job.Registry, db *database.Pool, redis *cache.Client, analyticsEmitter *analytics.Emitter, aiRouter *ai.Router, cfg *config.WorkerConfig, logger *slog.Logger) {
 	// Initialize analysis service for document analysis jobs
 	analysisRepo := analysis.NewRepository(db.Pool)
-	analysisService := analysis.NewService(analysisRepo, analysis.ServiceConfig{}) // AI and OCR services configured via config
+	analysisService := analysis.NewService(analysisRepo, analysisConfig(db, aiRouter, logger))
+	analysisService.SetAnalytics(analyticsEmitter)
 
 	// Register document analysis handler
 	docAnalysisHandler := jobs.NewDocumentAnalysisHandler(
@@ -178,22 +309,421 @@ func registerJobHandlers(registry *job.Registry, db *database.Pool, redis *cache
 			Logger:     logger,
 		},
 	)
+	// Notify users with the summary in their language once an analysis is done
+	notificationService := notification.NewService(notification.NewRepository(db.Pool), document.NewRepository(db.Pool), nil, &notification.ServiceConfig{
+		Logger: logger,
+		AppURL: cfg.AppURL,
+	})
+	notificationService.SetTranslator(analysisService)
+	// Deliver the queued notifications by mail (schedule every minute)
+	if mailService, err := newMailService(db, cfg, logger); err != nil {
+		logger.Error("notification delivery disabled", "error", err)
+	} else {
+		if secret := config.LoadNotificationConfig().UnsubscribeSecret; secret != "" {
+			notificationService.SetUnsubscribeSigner(notification.NewUnsubscribeSigner([]byte(secret)))
+		}
+		mailService.SetPreferences(notificationService)
+		notificationService.SetMailer(mailService)
+		registry.Register(job.TypeNotificationDelivery, jobs.NewNotificationDeliveryHandler(notificationService, logger))
+	}
+	assessmentService := assessment.NewService(assessment.NewRepository(db.Pool), uva.NewRepository(db.Pool), analysisRepo)
+	contractService := contract.NewService(contract.NewRepository(db.Pool), analysisRepo)
+	contractService.SetTimezones(tenant.NewService(db.Pool, tenant.NewRepository(db.Pool), user.NewRepository(db.Pool)))
+	docAnalysisHandler.SetCompleteCallback(func(ctx context.Context, tenantID, documentID uuid.UUID, result *jobs.DocumentAnalysisResult) {
+		full, err := analysisService.GetFullAnalysis(ctx, documentID)
+		if err != nil || full.Analysis == nil {
+			logger.Error("failed to load analysis for notifications", "document_id", documentID, "error", err)
+			return
+		}
+		if err := notificationService.NotifyAnalysisCompleted(ctx, tenantID, full); err != nil {
+			logger.Error("failed to queue analysis notifications", "document_id", documentID, "error", err)
+		}
+		// Compare a Bescheid with the UVA it assesses; most documents are not one
+		if full.Analysis.DocumentType == string(analysis.DocTypeBescheid) {
+			if _, err := assessmentService.Check(ctx, tenantID, documentID); err != nil && !assessment.IsNotApplicable(err) {
+				logger.Error("failed to compare bescheid with submission", "document_id", documentID, "error", err)
+			}
+		}
+		// Register the term and notice period of a contract
+		if full.Analysis.DocumentType == string(analysis.DocTypeVertrag) {
+			if _, err := contractService.Register(ctx, tenantID, documentID); err != nil && !contract.IsNotApplicable(err) {
+				logger.Error("failed to register contract", "document_id", documentID, "error", err)
+			}
+		}
+	})
 	registry.Register(job.TypeDocumentAnalysis, docAnalysisHandler)
 
+	// Register contract renewal: rolls contracts past their last day to cancel into the next term (schedule daily)
+	registry.Register(job.TypeContractRenewal, jobs.NewContractRenewalHandler(contractService, logger))
+
+	// Register Kleinunternehmer threshold check (schedule daily)
+	kleinunternehmerService := kleinunternehmer.NewService(kleinunternehmer.NewRepository(db.Pool))
+	registry.Register(job.TypeKleinunternehmerCheck, jobs.NewKleinunternehmerCheckHandler(kleinunternehmerService, logger))
+
+	// Register anomaly detection on payments, invoices and bank lines (schedule daily)
+	anomalyService := anomaly.NewService(anomaly.NewRepository(db.Pool))
+	registry.Register(job.TypeAnomalyDetection, jobs.NewAnomalyDetectionHandler(anomalyService, logger))
+
+	// Register raw provider payload retention cleanup (schedule daily)
+	registry.Register(job.TypeRawPayloadCleanup, jobs.NewRawPayloadCleanupHandler(rawpayload.NewRepository(db.Pool), logger))
+
+	// Register usage time-series aggregation (schedule daily, after midnight Europe/Vienna)
+	registry.Register(job.TypeUsageAggregation, jobs.NewUsageAggregationHandler(usage.NewService(usage.NewRepository(db.Pool)), logger))
+
+	// Register compaction of large and old extracted analysis texts (schedule daily)
+	registry.Register(job.TypeAnalysisTextCompaction, jobs.NewAnalysisTextCompactionHandler(analysisRepo, logger))
+
+	// Register monthly signature statements (schedule monthly, on the 1st)
+	sigCfg := config.LoadSignatureConfig()
+	sigBillingService := sigbilling.NewService(sigbilling.NewRepository(db.Pool), sigbilling.Config{
+		DefaultPostpaid:            sigCfg.SignatureDefaultPostpaid,
+		DefaultLowBalanceThreshold: sigCfg.SignatureLowBalanceThreshold,
+		Logger:                     logger,
+	})
+	registry.Register(job.TypeSignatureStatements, jobs.NewSignatureStatementsHandler(sigBillingService, logger))
+
+	// Register audit log archiving with the tenants' retention (schedule daily)
+	if auditArchive, err := newAuditArchiveHandler(db, logger); err != nil {
+		logger.Error("audit archive disabled", "error", err)
+	} else {
+		auditArchive.Register(registry)
+	}
+
+	// FinanzOnline and ELDA jobs honour the endpoint sets and maintenance
+	// windows like the server
+	endpoints, endpointErr := newEndpointRegistry(db, logger)
+
+	// Register UID batch verification with FinanzOnline (queued by uploads)
+	// and the quarterly re-validation of business partner UIDs (schedule daily)
+	if endpointErr != nil {
+		logger.Error("UID verification disabled", "error", endpointErr)
+	} else if uidService, mailService, err := newUIDService(db, cfg, endpoints, logger); err != nil {
+		logger.Error("UID verification disabled", "error", err)
+	} else {
+		registry.Register(job.TypeUIDBatch, jobs.NewUIDBatchHandler(uidService, logger))
+
+		partnerService := partner.NewService(partner.NewRepository(db.Pool), uidService)
+		partnerService.SetNotifier(email.NewMailService(mailService), cfg.AppURL)
+		partnerService.SetEvents(webhook.NewService(webhook.NewRepository(db.Pool), &webhook.ServiceConfig{Logger: logger}))
+		registry.Register(job.TypePartnerUIDRevalidation, jobs.NewPartnerUIDRevalidationHandler(partnerService, logger))
+	}
+
+	// Register the Firmenbuch watchlist checks (schedule hourly)
+	if fbCfg := config.LoadFirmenbuchConfig(); fbCfg.APIKey == "" {
+		logger.Warn("Firmenbuch watchlist checks disabled: FIRMENBUCH_API_KEY is not set")
+	} else if mailService, err := newMailService(db, cfg, logger); err != nil {
+		logger.Error("Firmenbuch watchlist checks disabled", "error", err)
+	} else {
+		firmenbuchService := firmenbuch.NewService(firmenbuch.NewRepository(db.Pool), fb.NewClient(fbCfg.APIKey, fbCfg.TestMode))
+		firmenbuchService.SetNotifier(email.NewMailService(mailService), cfg.AppURL)
+		firmenbuchService.SetEvents(webhook.NewService(webhook.NewRepository(db.Pool), &webhook.ServiceConfig{Logger: logger}))
+		registry.Register(job.TypeFirmenbuchWatch, jobs.NewFirmenbuchWatchHandler(firmenbuchService, fbCfg.CheckInterval, fbCfg.CheckBatchSize, logger))
+	}
+
+	// Register the polling of ELDA Rückmeldungen for submitted meldungen
+	// (schedule every 15 minutes)
+	if endpointErr != nil {
+		logger.Error("ELDA Rückmeldung polling disabled", "error", endpointErr)
+	} else if eldaMeldungService, err := newELDAMeldungService(db, cfg, endpoints, logger); err != nil {
+		logger.Error("ELDA Rückmeldung polling disabled", "error", err)
+	} else {
+		pollCfg := config.LoadELDAPollConfig()
+		registry.Register(job.TypeELDARueckmeldung, jobs.NewELDARueckmeldungHandler(eldaMeldungService, pollCfg.Interval, pollCfg.MaxAge, pollCfg.BatchSize, logger))
+	}
+
+	// Register the Förderung status sync (schedule nightly)
+	foerderungSync := foerderung.NewStatusSync(foerderung.NewRepository(db.Pool), config.LoadFoerderungConfig().ReviewAfterMonths)
+	if mailService, err := newMailService(db, cfg, logger); err != nil {
+		logger.Error("Förderung status mails disabled", "error", err)
+	} else {
+		foerderungSync.SetNotifier(email.NewMailService(mailService), cfg.AppURL)
+	}
+	registry.Register(job.TypeFoerderungStatusSync, jobs.NewFoerderungStatusSyncHandler(foerderungSync, logger))
+
+	// Register the Kommunalsteuer deadline reminders (schedule daily)
+	if mailService, err := newMailService(db, cfg, logger); err != nil {
+		logger.Error("Kommunalsteuer reminders disabled", "error", err)
+	} else {
+		kommunalsteuerService := kommunalsteuer.NewService(kommunalsteuer.NewRepository(db.Pool), nil)
+		kommunalsteuerService.SetNotifier(email.NewMailService(mailService), cfg.AppURL)
+		registry.Register(job.TypeKommunalsteuerFristen, jobs.NewKommunalsteuerFristenHandler(kommunalsteuerService, logger))
+	}
+
+	// Register the Kammerumlage deadline reminders (schedule daily)
+	if mailService, err := newMailService(db, cfg, logger); err != nil {
+		logger.Error("Kammerumlage reminders disabled", "error", err)
+	} else {
+		kammerumlageService := kammerumlage.NewService(kammerumlage.NewRepository(db.Pool), nil)
+		kammerumlageService.SetNotifier(email.NewMailService(mailService), cfg.AppURL)
+		registry.Register(job.TypeKammerumlageFristen, jobs.NewKammerumlageFristenHandler(kammerumlageService, logger))
+	}
+
+	// Register the workflow timers and SLA escalations (schedule every few
+	// minutes); without mail, escalations are only recorded
+	workflowService := workflow.NewService(workflow.NewRepository(db.Pool))
+	if mailService, err := newMailService(db, cfg, logger); err != nil {
+		logger.Error("workflow escalation mails disabled", "error", err)
+	} else {
+		workflowService.SetNotifier(email.NewMailService(mailService), cfg.AppURL)
+	}
+	registry.Register(job.TypeWorkflowTimers, jobs.NewWorkflowTimersHandler(workflowService, logger))
+
+	// Register the calendar sync (schedule hourly); it decrypts the OAuth
+	// tokens of the connected calendars
+	if cfg.EncryptionKey == "" {
+		logger.Error("calendar sync disabled", "error", "ENCRYPTION_KEY is not set")
+	} else {
+		calCfg := config.LoadCalendarConfig()
+		providers := calendarsync.NewProviders(calCfg.RedirectBaseURL, calCfg.GoogleClientID, calCfg.GoogleClientSecret,
+			calCfg.MicrosoftClientID, calCfg.MicrosoftClientSecret)
+		calendarService := calendarsync.NewService(calendarsync.NewRepository(db.Pool), []byte(cfg.EncryptionKey), nil,
+			calendarsync.Config{AppURL: cfg.AppURL, HorizonDays: calCfg.HorizonDays}, logger, providers...)
+		calendarService.SetTimezones(tenant.NewService(db.Pool, tenant.NewRepository(db.Pool), user.NewRepository(db.Pool)))
+		registry.Register(job.TypeCalendarSync, jobs.NewCalendarSyncHandler(calendarService, logger))
+	}
+
+	// Register the retention deletion (schedule daily); every run leaves a
+	// signed deletion protocol, so it needs a protocol key
+	if key := config.LoadRetentionConfig().ProtocolKey(cfg.EncryptionKey); key == nil {
+		logger.Error("document retention disabled", "error", "neither RETENTION_PROTOCOL_SECRET nor ENCRYPTION_KEY is set")
+	} else if handler, err := newDocumentRetentionHandler(db, key, logger); err != nil {
+		logger.Error("document retention disabled", "error", err)
+	} else {
+		registry.Register(job.TypeDocumentRetention, handler)
+	}
+
 	// TODO: Register other job handlers as they are implemented
 	// registry.Register(job.TypeDataboxSync, jobs.NewDataboxSyncHandler(db, logger))
 	// registry.Register(job.TypeDeadlineReminder, jobs.NewDeadlineReminderHandler(db, logger))
 	// registry.Register(job.TypeWatchlistCheck, jobs.NewWatchlistCheckHandler(db, logger))
 	// registry.Register(job.TypeSessionCleanup, jobs.NewSessionCleanupHandler(db, logger))
 	// registry.Register(job.TypeWebhookDelivery, jobs.NewWebhookDeliveryHandler(db, logger))
-	// registry.Register(job.TypeAuditArchive, jobs.NewAuditArchiveHandler(db, logger))
 
 	_ = redis
-	logger.Info("job handlers registered", "handlers", []string{job.TypeDocumentAnalysis})
+	logger.Info("job handlers registered", "handlers", []string{job.TypeDocumentAnalysis, job.TypeKleinunternehmerCheck, job.TypeAnomalyDetection, job.TypeRawPayloadCleanup, job.TypeUsageAggregation, job.TypeAnalysisTextCompaction, job.TypeSignatureStatements, job.TypeAuditArchive, job.TypeUIDBatch, job.TypeContractRenewal, job.TypePartnerUIDRevalidation, job.TypeFirmenbuchWatch, job.TypeFoerderungStatusSync, job.TypeELDARueckmeldung, job.TypeKommunalsteuerFristen, job.TypeKammerumlageFristen, job.TypeWorkflowTimers, job.TypeCalendarSync, job.TypeDocumentRetention, job.TypeNotificationDelivery})
+}
+
+// newDocumentRetentionHandler creates the retention deletion job, which
+// deletes documents from storage and database past their retention date
+func newDocumentRetentionHandler(db *database.Pool, key []byte, logger *slog.Logger) (*jobs.DocumentRetentionHandler, error) {
+	docRepo := document.NewRepository(db.Pool)
+	storage, err := document.NewRegionalStorage(document.NewStorageConfig(config.LoadStorageConfig()), docRepo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create document storage: %w", err)
+	}
+	service := retention.NewService(retention.NewRepository(db.Pool), document.NewService(docRepo, storage), retention.NewSigner(key))
+	return jobs.NewDocumentRetentionHandler(service, logger), nil
+}
+
+// newAuditArchiveHandler creates the audit archive job, which moves audit
+// logs past their tenant's retention to the archive directory
+func newAuditArchiveHandler(db *database.Pool, logger *slog.Logger) (*jobs.AuditArchiveHandler, error) {
+	auditCfg := config.LoadAuditConfig()
+	archive, err := storage.NewLocalClient(auditCfg.ArchivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	auditRepo := audit.NewRepository(db.Pool)
+	policy := audit.NewPolicy(auditRepo,
+		audit.NewAnonymizer([]byte(auditCfg.IPHashKey), auditCfg.SaltRotation),
+		audit.PolicyConfig{IPMode: auditCfg.IPAnonymization, RetentionDays: auditCfg.RetentionDays})
+	return jobs.NewAuditArchiveHandler(auditRepo, archive, &jobs.AuditArchiveConfig{
+		Logger:        logger,
+		Retention:     policy,
+		RetentionDays: auditCfg.RetentionDays,
+	}), nil
+}
+
+// newUIDService creates the UID service of the UID jobs and the mail
+// service of their notifications. It logs in to FinanzOnline with the stored
+// credentials of the batch's account, honours the endpoint sets and
+// maintenance windows and mails the uploader.
+func newUIDService(db *database.Pool, cfg *config.WorkerConfig, endpoints *endpoint.Registry, logger *slog.Logger) (*uid.Service, *mail.Service, error) {
+	if cfg.EncryptionKey == "" {
+		return nil, nil, fmt.Errorf("ENCRYPTION_KEY is not set")
+	}
+	accountService, err := account.NewService(account.NewRepository(db.Pool), []byte(cfg.EncryptionKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create account service: %w", err)
+	}
+	rawPayloadService, err := rawpayload.NewService(rawpayload.NewRepository(db.Pool), rawpayload.ServiceConfig{
+		EncryptionKey: []byte(cfg.EncryptionKey),
+		Logger:        logger,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create raw payload service: %w", err)
+	}
+
+	mailService, err := newMailService(db, cfg, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	uidService := uid.NewService(uid.NewRepository(db.Pool), accountService)
+	uidService.SetRawPayloads(rawPayloadService)
+	uidService.SetEndpoints(endpoints.Resolver(endpoint.FinanzOnline))
+	uidService.SetNotifier(email.NewMailService(mailService), cfg.AppURL)
+	return uidService, mailService, nil
+}
+
+// newEndpointRegistry creates the registry of the FinanzOnline and ELDA
+// endpoint sets, switchovers and maintenance windows
+func newEndpointRegistry(db *database.Pool, logger *slog.Logger) (*endpoint.Registry, error) {
+	endpointCfg := config.LoadEndpointConfig()
+	foEndpoints, err := endpoint.NewIntegration(endpoint.FinanzOnline, endpointCfg.FinanzOnlineEndpoints, endpointCfg.FinanzOnlineActive)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FO_ENDPOINTS: %w", err)
+	}
+	eldaEndpoints, err := endpoint.NewIntegration(endpoint.ELDA, endpointCfg.ELDAEndpoints, endpointCfg.ELDAActive)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ELDA_ENDPOINTS: %w", err)
+	}
+	switchovers, err := endpoint.ParseSwitchovers(endpointCfg.Switchovers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ENDPOINT_SWITCHOVERS: %w", err)
+	}
+	maintenanceWindows, err := endpoint.ParseWindows(endpointCfg.MaintenanceWindows)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ENDPOINT_MAINTENANCE_WINDOWS: %w", err)
+	}
+	// Runs for the life of the worker
+	endpoints, err := endpoint.NewRegistry(endpoint.NewRepository(db.Pool), endpoint.Config{
+		Integrations:    []endpoint.Integration{foEndpoints, eldaEndpoints},
+		Switchovers:     switchovers,
+		Windows:         maintenanceWindows,
+		RefreshInterval: endpointCfg.RefreshInterval,
+	}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint configuration: %w", err)
+	}
+	return endpoints, nil
+}
+
+// newELDAMeldungService creates the ELDA meldung service of the Rückmeldung
+// polling. It queries the active ELDA endpoint, keeps the raw exchanges and
+// mails the creator of a rejected meldung.
+func newELDAMeldungService(db *database.Pool, cfg *config.WorkerConfig, endpoints *endpoint.Registry, logger *slog.Logger) (*eldameldung.Service, error) {
+	if cfg.EncryptionKey == "" {
+		return nil, fmt.Errorf("ENCRYPTION_KEY is not set")
+	}
+	rawPayloadService, err := rawpayload.NewService(rawpayload.NewRepository(db.Pool), rawpayload.ServiceConfig{
+		EncryptionKey: []byte(cfg.EncryptionKey),
+		Logger:        logger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create raw payload service: %w", err)
+	}
+	mailService, err := newMailService(db, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	eldaClient := elda.NewClientWithConfig(elda.ClientConfig{
+		Resolver: endpoints.Resolver(endpoint.ELDA),
+		Logger:   logger,
+	})
+	service := eldameldung.NewService(db.Pool, eldaClient)
+	service.SetRawPayloads(rawPayloadService)
+	service.SetNotifier(email.NewMailService(mailService))
+	return service, nil
+}
+
+// newMailService creates the mail service of jobs that mail users
+func newMailService(db *database.Pool, cfg *config.WorkerConfig, logger *slog.Logger) (*mail.Service, error) {
+	mailCfg := config.LoadMailConfig()
+	mailProvider, err := mail.NewProvider(mailCfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mail provider: %w", err)
+	}
+	mailRenderer, err := mail.NewRenderer(mailCfg.FromName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mail templates: %w", err)
+	}
+	mailService := mail.NewService(mailProvider, mail.NewRepository(db.Pool), mailRenderer, mail.ServiceConfig{
+		From:     mailCfg.From,
+		FromName: mailCfg.FromName,
+		Logger:   logger,
+	})
+	mailService.SetBrandProvider(branding.NewService(db.Pool, cfg.AppURL))
+	return mailService, nil
+}
+
+// registerDocumentIngestion registers the document ingestion handler. The
+// documents are created as on upload: for accounts of the tenant, within
+// its storage quota, and archived as PDF/A when that is enabled.
+func registerDocumentIngestion(registry *job.Registry, queue *job.Queue, db *database.Pool, analyticsEmitter *analytics.Emitter, cfg *config.WorkerConfig, logger *slog.Logger) error {
+	docRepo := document.NewRepository(db.Pool)
+	storage, err := document.NewRegionalStorage(document.NewStorageConfig(config.LoadStorageConfig()), docRepo)
+	if err != nil {
+		return fmt.Errorf("failed to create document storage: %w", err)
+	}
+	docService := document.NewServiceWithAccountVerifier(docRepo, storage, account.NewRepository(db.Pool))
+	docService.SetQuotaChecker(quota.NewService(quota.NewRepository(db.Pool), cfg.StorageDefaultQuota))
+	docService.SetAnalytics(analyticsEmitter)
+	if config.LoadPDFAConfig().Enabled {
+		docService.SetArchivalScheduler(jobs.NewPDFAScheduler(queue))
+	}
+
+	service := ingest.NewService(ingest.NewRepository(db.Pool), storage, docService, ingest.Config{})
+	service.SetScheduler(jobs.NewIngestionScheduler(queue))
+	registry.Register(job.TypeDocumentIngestion, jobs.NewDocumentIngestionHandler(service, logger))
+	logger.Info("document ingestion enabled")
+	return nil
+}
+
+// analysisConfig configures document analysis with the AI router. Without
+// a router analysis stays disabled; OCR is not configured in the worker,
+// so scanned documents are analysed by their embedded text only.
+func analysisConfig(db *database.Pool, aiRouter *ai.Router, logger *slog.Logger) analysis.ServiceConfig {
+	if aiRouter == nil {
+		logger.Info("document analysis disabled, no AI provider configured")
+		return analysis.ServiceConfig{}
+	}
+	docRepo := document.NewRepository(db.Pool)
+	storage, err := document.NewRegionalStorage(document.NewStorageConfig(config.LoadStorageConfig()), docRepo)
+	if err != nil {
+		logger.Error("document analysis disabled", "error", fmt.Errorf("failed to create document storage: %w", err))
+		return analysis.ServiceConfig{}
+	}
+	return analysis.ServiceConfig{
+		AIClient:     aiRouter,
+		PromptLoader: ai.NewPromptLoader(stdlib.OpenDBFromPool(db.Pool)),
+		DocService:   document.NewService(docRepo, storage),
+		Enabled:      true,
+	}
+}
+
+// registerPDFAConversion registers the PDF/A conversion handler and returns
+// the sweeper that queues documents without a rendition
+func registerPDFAConversion(registry *job.Registry, queue *job.Queue, db *database.Pool, cfg *config.PDFAConfig, logger *slog.Logger) (*jobs.PDFASweeper, error) {
+	docRepo := document.NewRepository(db.Pool)
+	storage, err := document.NewRegionalStorage(document.NewStorageConfig(config.LoadStorageConfig()), docRepo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create document storage: %w", err)
+	}
+	docService := document.NewService(docRepo, storage)
+
+	converter := pdfa.NewConverter(pdfa.Config{
+		GhostscriptPath: cfg.GhostscriptPath,
+		LibreOfficePath: cfg.LibreOfficePath,
+		VeraPDFPath:     cfg.VeraPDFPath,
+		ICCProfile:      cfg.ICCProfile,
+		Timeout:         cfg.Timeout,
+	})
+	registry.Register(job.TypePDFAConversion, jobs.NewPDFAConversionHandler(docService, converter, logger))
+
+	validator := pdfa.ValidatorVeraPDF
+	if cfg.VeraPDFPath == "" {
+		validator = pdfa.ValidatorBuiltin
+	}
+	logger.Info("PDF/A conversion enabled", "validator", validator, "sweep_interval", cfg.SweepInterval)
+
+	return jobs.NewPDFASweeper(docService, jobs.NewPDFAScheduler(queue), cfg.SweepInterval, cfg.SweepBatch, logger), nil
 }
 
 // startHealthServer starts the health check HTTP server
-func startHealthServer(port int, db *database.Pool, redis *cache.Client, worker *job.Worker, logger *slog.Logger) *http.Server {
+func startHealthServer(port int, db *database.Pool, redis *cache.Client, worker *job.Worker, aiRouter *ai.Router, logger *slog.Logger) *http.Server {
 	mux := http.NewServeMux()
 
 	// Liveness probe - basic check that process is running
@@ -253,6 +783,16 @@ func startHealthServer(port int, db *database.Pool, redis *cache.Client, worker
 		fmt.Fprintf(w, `%s`, toJSON(metrics))
 	})
 
+	// Circuit breaker state of the AI targets; an open one does not make
+	// the worker unready, since completions fail over
+	if aiRouter != nil {
+		mux.HandleFunc("GET /ai", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(map[string]interface{}{"targets": aiRouter.Health()})
+		})
+	}
+
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", port),
 		Handler:      mux,
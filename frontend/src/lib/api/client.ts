@@ -83,6 +83,7 @@ class ApiClient {
 
 		const headers: HeadersInit = {
 			'Content-Type': 'application/json',
+			'X-Requested-With': 'XMLHttpRequest', // Required by the API's CSRF check
 			...customHeaders
 		};
 
@@ -196,7 +197,7 @@ class ApiClient {
 		const formData = new FormData();
 		formData.append(fieldName, file);
 
-		const headers: HeadersInit = {};
+		const headers: HeadersInit = { 'X-Requested-With': 'XMLHttpRequest' };
 		if (this.accessToken) {
 			headers['Authorization'] = `Bearer ${this.accessToken}`;
 		}
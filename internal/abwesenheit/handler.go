@@ -0,0 +1,343 @@
+package abwesenheit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/eldameldung"
+)
+
+// Handler handles HTTP requests for absences and AUAs
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new absence HTTP handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers absence routes with the router
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/abwesenheiten", func(r chi.Router) {
+		r.Post("/", h.Create)
+		r.Get("/", h.List)
+		r.Get("/{id}", h.Get)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
+
+		// Arbeits- und Entgeltbestätigungen
+		r.Post("/{id}/aua", h.CreateAUA)
+		r.Get("/{id}/aua", h.ListAUAs)
+	})
+
+	r.Route("/aua", func(r chi.Router) {
+		r.Get("/{id}", h.GetAUA)
+		r.Get("/{id}/preview", h.PreviewAUA)
+		r.Post("/{id}/send", h.SubmitAUA)
+	})
+}
+
+// Create handles POST /api/v1/abwesenheiten
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var req elda.AbwesenheitCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if !h.ownsAccount(w, r, req.ELDAAccountID) {
+		return
+	}
+
+	a, err := h.service.Create(r.Context(), &req, api.OptionalUser(r))
+	if err != nil {
+		h.handleError(w, err, "Failed to record absence")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusCreated, a)
+}
+
+// List handles GET /api/v1/abwesenheiten
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	filter := ListFilter{
+		ELDAAccountID: accountID,
+		SVNummer:      r.URL.Query().Get("sv_nummer"),
+	}
+	if art := r.URL.Query().Get("art"); art != "" {
+		a := elda.AbwesenheitArt(art)
+		filter.Art = &a
+	}
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil {
+			api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid year", err)
+			return
+		}
+		filter.Year = &year
+	}
+
+	absences, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusInternalServerError, "Failed to list absences", err)
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"abwesenheiten": absences,
+		"count":         len(absences),
+	})
+}
+
+// Get handles GET /api/v1/abwesenheiten/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.abwesenheitID(w, r)
+	if !ok {
+		return
+	}
+
+	a, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err, "Failed to get absence")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, a)
+}
+
+// Update handles PUT /api/v1/abwesenheiten/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.abwesenheitID(w, r)
+	if !ok {
+		return
+	}
+
+	var req elda.AbwesenheitCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	a, err := h.service.Update(r.Context(), id, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to update absence")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, a)
+}
+
+// Delete handles DELETE /api/v1/abwesenheiten/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.abwesenheitID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		h.handleError(w, err, "Failed to delete absence")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateAUA handles POST /api/v1/abwesenheiten/{id}/aua
+func (h *Handler) CreateAUA(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.abwesenheitID(w, r)
+	if !ok {
+		return
+	}
+
+	var req elda.AUACreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	m, err := h.service.CreateAUA(r.Context(), id, &req, api.OptionalUser(r))
+	if err != nil {
+		h.handleError(w, err, "Failed to create AUA")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusCreated, m)
+}
+
+// ListAUAs handles GET /api/v1/abwesenheiten/{id}/aua
+func (h *Handler) ListAUAs(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.abwesenheitID(w, r)
+	if !ok {
+		return
+	}
+
+	auas, err := h.service.ListAUAs(r.Context(), id)
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusInternalServerError, "Failed to list AUAs", err)
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"aua":   auas,
+		"count": len(auas),
+	})
+}
+
+// GetAUA handles GET /api/v1/aua/{id}
+func (h *Handler) GetAUA(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.auaID(w, r)
+	if !ok {
+		return
+	}
+
+	m, err := h.service.GetAUA(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err, "Failed to get AUA")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, m)
+}
+
+// PreviewAUA handles GET /api/v1/aua/{id}/preview
+func (h *Handler) PreviewAUA(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.auaID(w, r)
+	if !ok {
+		return
+	}
+
+	dienstgeberNr := r.URL.Query().Get("dienstgeber_nr")
+	if dienstgeberNr == "" {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "dienstgeber_nr is required", nil)
+		return
+	}
+
+	xmlData, err := h.service.PreviewAUA(r.Context(), id, dienstgeberNr)
+	if err != nil {
+		h.handleError(w, err, "Failed to generate preview")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(xmlData)
+}
+
+// SubmitAUA handles POST /api/v1/aua/{id}/send
+func (h *Handler) SubmitAUA(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.auaID(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		DienstgeberNr string `json:"dienstgeber_nr"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if req.DienstgeberNr == "" {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "dienstgeber_nr is required", nil)
+		return
+	}
+
+	result, err := h.service.SubmitAUA(r.Context(), id, req.DienstgeberNr)
+	if err != nil {
+		if result != nil {
+			api.RespondJSON(w, http.StatusOK, result) // Return result even on submission error
+			return
+		}
+		h.handleError(w, err, "Failed to submit AUA")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error, message string) {
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		api.RespondJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":  "Validation failed",
+			"errors": validationErr.Errors,
+		})
+	case errors.Is(err, ErrAbwesenheitNotFound), errors.Is(err, ErrAUANotFound),
+		errors.Is(err, eldameldung.ErrMeldungNotFound), errors.Is(err, ErrAccountNotFound):
+		api.RespondErrorWithDetails(w, http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, ErrAUAAlreadySubmitted):
+		api.RespondErrorWithDetails(w, http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrNotEmployed), errors.Is(err, ErrNotArbeitsunfaehig),
+		errors.Is(err, ErrBeitragsgrundlageZero):
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, err.Error(), err)
+	default:
+		api.RespondErrorWithDetails(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+func (h *Handler) accountIDParam(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	accountID, err := uuid.Parse(r.URL.Query().Get("elda_account_id"))
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "elda_account_id is required", err)
+		return uuid.Nil, false
+	}
+	return accountID, h.ownsAccount(w, r, accountID)
+}
+
+func (h *Handler) ownsAccount(w http.ResponseWriter, r *http.Request, eldaAccountID uuid.UUID) bool {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return false
+	}
+	ok, err := h.service.OwnsELDAAccount(r.Context(), tenantID, eldaAccountID)
+	if err != nil {
+		h.handleError(w, err, "Failed to check ELDA account")
+		return false
+	}
+	if !ok {
+		h.handleError(w, ErrAccountNotFound, "")
+		return false
+	}
+	return true
+}
+
+func (h *Handler) abwesenheitID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid absence ID", err)
+		return uuid.Nil, false
+	}
+	a, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err, "Failed to get absence")
+		return uuid.Nil, false
+	}
+	return id, h.ownsAccount(w, r, a.ELDAAccountID)
+}
+
+func (h *Handler) auaID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid AUA ID", err)
+		return uuid.Nil, false
+	}
+	m, err := h.service.GetAUA(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err, "Failed to get AUA")
+		return uuid.Nil, false
+	}
+	return id, h.ownsAccount(w, r, m.ELDAAccountID)
+}
@@ -0,0 +1,388 @@
+package abwesenheit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/internal/elda"
+)
+
+var (
+	ErrAbwesenheitNotFound = errors.New("absence not found")
+	ErrAUANotFound         = errors.New("AUA not found")
+)
+
+// Repository handles absence and AUA database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new absence repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const abwesenheitColumns = `
+	id, elda_account_id, sv_nummer, vorname, nachname, art, von, bis,
+	bestaetigung, bemerkung, created_by, created_at, updated_at`
+
+// Create creates a new absence
+func (r *Repository) Create(ctx context.Context, a *elda.Abwesenheit) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	now := time.Now()
+	a.CreatedAt = now
+	a.UpdatedAt = now
+
+	query := `
+		INSERT INTO abwesenheiten (` + abwesenheitColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		a.ID, a.ELDAAccountID, a.SVNummer, a.Vorname, a.Nachname, a.Art, a.Von, a.Bis,
+		a.Bestaetigung, a.Bemerkung, a.CreatedBy, a.CreatedAt, a.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create absence: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an absence by ID
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*elda.Abwesenheit, error) {
+	query := `SELECT ` + abwesenheitColumns + ` FROM abwesenheiten WHERE id = $1`
+
+	a, err := scanAbwesenheit(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAbwesenheitNotFound
+		}
+		return nil, fmt.Errorf("get absence: %w", err)
+	}
+
+	return a, nil
+}
+
+// ListFilter contains filters for listing absences
+type ListFilter struct {
+	ELDAAccountID uuid.UUID
+	SVNummer      string
+	Art           *elda.AbwesenheitArt
+	Year          *int
+}
+
+// List lists the absences of an ELDA account, newest first
+func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*elda.Abwesenheit, error) {
+	query := `SELECT ` + abwesenheitColumns + ` FROM abwesenheiten WHERE elda_account_id = $1`
+	args := []interface{}{filter.ELDAAccountID}
+	argNum := 2
+
+	if filter.SVNummer != "" {
+		query += fmt.Sprintf(" AND sv_nummer = $%d", argNum)
+		args = append(args, filter.SVNummer)
+		argNum++
+	}
+	if filter.Art != nil {
+		query += fmt.Sprintf(" AND art = $%d", argNum)
+		args = append(args, *filter.Art)
+		argNum++
+	}
+	if filter.Year != nil {
+		query += fmt.Sprintf(" AND von <= make_date($%d, 12, 31) AND (bis IS NULL OR bis >= make_date($%d, 1, 1))", argNum, argNum)
+		args = append(args, *filter.Year)
+	}
+
+	query += " ORDER BY von DESC"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list absences: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*elda.Abwesenheit
+	for rows.Next() {
+		a, err := scanAbwesenheit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan absence: %w", err)
+		}
+		results = append(results, a)
+	}
+
+	return results, rows.Err()
+}
+
+// Update updates an absence
+func (r *Repository) Update(ctx context.Context, a *elda.Abwesenheit) error {
+	query := `
+		UPDATE abwesenheiten SET
+			art = $2,
+			von = $3,
+			bis = $4,
+			bestaetigung = $5,
+			bemerkung = $6,
+			updated_at = $7
+		WHERE id = $1
+	`
+
+	a.UpdatedAt = time.Now()
+
+	result, err := r.db.Exec(ctx, query,
+		a.ID, a.Art, a.Von, a.Bis, a.Bestaetigung, a.Bemerkung, a.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("update absence: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrAbwesenheitNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes an absence and its AUAs
+func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM abwesenheiten WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete absence: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrAbwesenheitNotFound
+	}
+
+	return nil
+}
+
+// ELDAAccountBelongsToTenant reports whether an ELDA account is one of the
+// tenant's
+func (r *Repository) ELDAAccountBelongsToTenant(ctx context.Context, tenantID, eldaAccountID uuid.UUID) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM elda_accounts ea JOIN accounts a ON a.id = ea.account_id
+			WHERE ea.id = $1 AND a.tenant_id = $2)`, eldaAccountID, tenantID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("check ELDA account: %w", err)
+	}
+	return ok, nil
+}
+
+// GetLastBeitragsgrundlage returns the Beitragsgrundlage in cents of the last
+// mBGM before the given month, or 0 if the employee has not been reported yet
+func (r *Repository) GetLastBeitragsgrundlage(ctx context.Context, accountID uuid.UUID, svNummer string, before time.Time) (int64, error) {
+	query := `
+		SELECT ROUND(p.beitragsgrundlage * 100)::BIGINT
+		FROM mbgm_positionen p
+		JOIN mbgm m ON m.id = p.mbgm_id
+		WHERE m.elda_account_id = $1 AND p.sv_nummer = $2
+			AND (m.year * 12 + m.month) < $3
+		ORDER BY m.year DESC, m.month DESC
+		LIMIT 1
+	`
+
+	var cents int64
+	err := r.db.QueryRow(ctx, query, accountID, svNummer, before.Year()*12+int(before.Month())).Scan(&cents)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("get last Beitragsgrundlage: %w", err)
+	}
+
+	return cents, nil
+}
+
+// SumSonderzahlungen returns the Sonderzahlungen in cents reported in the
+// twelve months before the given month
+func (r *Repository) SumSonderzahlungen(ctx context.Context, accountID uuid.UUID, svNummer string, before time.Time) (int64, error) {
+	query := `
+		SELECT COALESCE(ROUND(SUM(p.sonderzahlung) * 100), 0)::BIGINT
+		FROM mbgm_positionen p
+		JOIN mbgm m ON m.id = p.mbgm_id
+		WHERE m.elda_account_id = $1 AND p.sv_nummer = $2
+			AND (m.year * 12 + m.month) < $3
+			AND (m.year * 12 + m.month) >= $3 - 12
+	`
+
+	var cents int64
+	if err := r.db.QueryRow(ctx, query, accountID, svNummer, before.Year()*12+int(before.Month())).Scan(&cents); err != nil {
+		return 0, fmt.Errorf("sum Sonderzahlungen: %w", err)
+	}
+
+	return cents, nil
+}
+
+const auaColumns = `
+	id, abwesenheit_id, elda_account_id, sv_nummer, status,
+	anforderungsdatum, anforderungsreferenz,
+	arbeitsunfall, letzter_arbeitstag, arbeitsunfaehig_ab, entgelt_voll_bis, entgelt_halb_bis,
+	beitragsgrundlage, sonderzahlungen,
+	protokollnummer, submitted_at, request_xml, error_code, error_message,
+	created_by, created_at, updated_at`
+
+// CreateAUA creates a new AUA
+func (r *Repository) CreateAUA(ctx context.Context, m *elda.AUAMeldung) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	now := time.Now()
+	m.CreatedAt = now
+	m.UpdatedAt = now
+
+	query := `
+		INSERT INTO aua_meldungen (` + auaColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		m.ID, m.AbwesenheitID, m.ELDAAccountID, m.SVNummer, m.Status,
+		m.Anforderungsdatum, m.Anforderungsreferenz,
+		m.Arbeitsunfall, m.LetzterArbeitstag, m.ArbeitsunfaehigAb, m.EntgeltVollBis, m.EntgeltHalbBis,
+		m.Beitragsgrundlage, m.Sonderzahlungen,
+		m.Protokollnummer, m.SubmittedAt, m.RequestXML, m.ErrorCode, m.ErrorMessage,
+		m.CreatedBy, m.CreatedAt, m.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create AUA: %w", err)
+	}
+
+	return nil
+}
+
+// GetAUA retrieves an AUA by ID
+func (r *Repository) GetAUA(ctx context.Context, id uuid.UUID) (*elda.AUAMeldung, error) {
+	query := `SELECT ` + auaColumns + ` FROM aua_meldungen WHERE id = $1`
+
+	m, err := scanAUA(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAUANotFound
+		}
+		return nil, fmt.Errorf("get AUA: %w", err)
+	}
+
+	return m, nil
+}
+
+// ListAUAs lists the AUAs of an absence
+func (r *Repository) ListAUAs(ctx context.Context, abwesenheitID uuid.UUID) ([]*elda.AUAMeldung, error) {
+	query := `SELECT ` + auaColumns + `
+		FROM aua_meldungen
+		WHERE abwesenheit_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, abwesenheitID)
+	if err != nil {
+		return nil, fmt.Errorf("list AUAs: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*elda.AUAMeldung
+	for rows.Next() {
+		m, err := scanAUA(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan AUA: %w", err)
+		}
+		results = append(results, m)
+	}
+
+	return results, rows.Err()
+}
+
+// UpdateAUA updates the status and ELDA response of an AUA
+func (r *Repository) UpdateAUA(ctx context.Context, m *elda.AUAMeldung) error {
+	query := `
+		UPDATE aua_meldungen SET
+			status = $2,
+			beitragsgrundlage = $3,
+			sonderzahlungen = $4,
+			protokollnummer = $5,
+			submitted_at = $6,
+			request_xml = $7,
+			error_code = $8,
+			error_message = $9,
+			updated_at = $10
+		WHERE id = $1
+	`
+
+	m.UpdatedAt = time.Now()
+
+	result, err := r.db.Exec(ctx, query,
+		m.ID, m.Status, m.Beitragsgrundlage, m.Sonderzahlungen,
+		m.Protokollnummer, m.SubmittedAt, m.RequestXML, m.ErrorCode, m.ErrorMessage, m.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("update AUA: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrAUANotFound
+	}
+
+	return nil
+}
+
+func scanAbwesenheit(row pgx.Row) (*elda.Abwesenheit, error) {
+	a := &elda.Abwesenheit{}
+	var bemerkung *string
+
+	err := row.Scan(
+		&a.ID, &a.ELDAAccountID, &a.SVNummer, &a.Vorname, &a.Nachname, &a.Art, &a.Von, &a.Bis,
+		&a.Bestaetigung, &bemerkung, &a.CreatedBy, &a.CreatedAt, &a.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if bemerkung != nil {
+		a.Bemerkung = *bemerkung
+	}
+
+	return a, nil
+}
+
+func scanAUA(row pgx.Row) (*elda.AUAMeldung, error) {
+	m := &elda.AUAMeldung{}
+	var referenz, protokollnummer, requestXML, errorCode, errorMessage *string
+
+	err := row.Scan(
+		&m.ID, &m.AbwesenheitID, &m.ELDAAccountID, &m.SVNummer, &m.Status,
+		&m.Anforderungsdatum, &referenz,
+		&m.Arbeitsunfall, &m.LetzterArbeitstag, &m.ArbeitsunfaehigAb, &m.EntgeltVollBis, &m.EntgeltHalbBis,
+		&m.Beitragsgrundlage, &m.Sonderzahlungen,
+		&protokollnummer, &m.SubmittedAt, &requestXML, &errorCode, &errorMessage,
+		&m.CreatedBy, &m.CreatedAt, &m.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if referenz != nil {
+		m.Anforderungsreferenz = *referenz
+	}
+	if protokollnummer != nil {
+		m.Protokollnummer = *protokollnummer
+	}
+	if requestXML != nil {
+		m.RequestXML = *requestXML
+	}
+	if errorCode != nil {
+		m.ErrorCode = *errorCode
+	}
+	if errorMessage != nil {
+		m.ErrorMessage = *errorMessage
+	}
+
+	return m, nil
+}
@@ -0,0 +1,513 @@
+package abwesenheit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/eldameldung"
+	"austrian-business-infrastructure/internal/rawpayload"
+)
+
+var (
+	ErrNotEmployed           = errors.New("no accepted ELDA Anmeldung for this SV-Nummer")
+	ErrNotArbeitsunfaehig    = errors.New("AUA can only be created for Krankenstand or Arbeitsunfall")
+	ErrAUAAlreadySubmitted   = errors.New("AUA has already been submitted")
+	ErrBeitragsgrundlageZero = errors.New("no Beitragsgrundlage found in the mBGM history; provide it explicitly")
+	ErrAccountNotFound       = errors.New("ELDA account not found")
+)
+
+// Service handles absence and AUA business logic
+type Service struct {
+	repo        *Repository
+	meldungRepo *eldameldung.Repository
+	eldaService *elda.AUAService
+	payloads    *rawpayload.Service
+	logger      *slog.Logger
+}
+
+// ServiceConfig contains configuration for the absence service
+type ServiceConfig struct {
+	Repository        *Repository
+	MeldungRepository *eldameldung.Repository
+	ELDAClient        elda.Caller
+	RawPayloads       *rawpayload.Service // Optional: retain raw ELDA exchanges
+	Logger            *slog.Logger
+}
+
+// NewService creates a new absence service
+func NewService(cfg ServiceConfig) *Service {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Service{
+		repo:        cfg.Repository,
+		meldungRepo: cfg.MeldungRepository,
+		eldaService: elda.NewAUAService(cfg.ELDAClient),
+		payloads:    cfg.RawPayloads,
+		logger:      logger,
+	}
+}
+
+// ValidationError holds the messages of a failed absence validation
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 0 {
+		return "validation failed"
+	}
+	return fmt.Sprintf("validation failed: %s", e.Errors[0])
+}
+
+// Beschaeftigungszeitraeume derives the employment periods of an SV-Nummer from
+// its accepted An- and Abmeldungen. An Abmeldung closes the open period.
+func Beschaeftigungszeitraeume(meldungen []*elda.ELDAMeldung, svNummer string) []elda.Beschaeftigungszeitraum {
+	type event struct {
+		date      time.Time
+		anmeldung bool
+	}
+
+	var events []event
+	for _, m := range meldungen {
+		if m.SVNummer != svNummer || m.Status != elda.MeldungStatusAccepted {
+			continue
+		}
+		switch {
+		case m.Type == elda.MeldungTypeAnmeldung && m.Eintrittsdatum != nil:
+			events = append(events, event{date: *m.Eintrittsdatum, anmeldung: true})
+		case m.Type == elda.MeldungTypeAbmeldung && m.Austrittsdatum != nil:
+			events = append(events, event{date: *m.Austrittsdatum})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].date.Before(events[j].date)
+	})
+
+	var periods []elda.Beschaeftigungszeitraum
+	open := false
+	for _, e := range events {
+		if e.anmeldung {
+			if !open {
+				periods = append(periods, elda.Beschaeftigungszeitraum{Von: e.date})
+				open = true
+			}
+			continue
+		}
+		if open {
+			bis := e.date
+			periods[len(periods)-1].Bis = &bis
+			open = false
+		}
+	}
+
+	return periods
+}
+
+// findZeitraum returns the employment period covering an absence
+func findZeitraum(periods []elda.Beschaeftigungszeitraum, a *elda.Abwesenheit) (elda.Beschaeftigungszeitraum, bool) {
+	for _, p := range periods {
+		if p.Contains(a.Von, a.Bis) {
+			return p, true
+		}
+	}
+	return elda.Beschaeftigungszeitraum{}, false
+}
+
+// ValidateAbwesenheit checks an absence against the employment periods and the
+// other absences of the employee
+func ValidateAbwesenheit(a *elda.Abwesenheit, periods []elda.Beschaeftigungszeitraum, existing []*elda.Abwesenheit) []string {
+	var errs []string
+
+	switch a.Art {
+	case elda.AbwesenheitKrankenstand, elda.AbwesenheitArbeitsunfall,
+		elda.AbwesenheitUrlaub, elda.AbwesenheitPflegeurlaub:
+	default:
+		errs = append(errs, "art muss krankenstand, arbeitsunfall, urlaub oder pflegeurlaub sein")
+	}
+
+	if a.Von.IsZero() {
+		errs = append(errs, "von ist erforderlich")
+		return errs
+	}
+	if a.Bis != nil && a.Bis.Before(a.Von) {
+		errs = append(errs, "bis liegt vor von")
+		return errs
+	}
+	if a.Bis == nil && !a.Art.IsArbeitsunfaehigkeit() {
+		errs = append(errs, "bis ist für urlaub und pflegeurlaub erforderlich")
+	}
+
+	if len(periods) == 0 {
+		errs = append(errs, "kein aufrechtes Dienstverhältnis laut ELDA")
+	} else if _, ok := findZeitraum(periods, a); !ok {
+		errs = append(errs, "abwesenheit liegt außerhalb des Dienstverhältnisses")
+	}
+
+	for _, other := range existing {
+		if other.ID == a.ID {
+			continue
+		}
+		if a.Overlaps(other) {
+			errs = append(errs, fmt.Sprintf("überschneidet sich mit %s ab %s", other.Art, other.Von.Format("2006-01-02")))
+		}
+	}
+
+	return errs
+}
+
+// Create records an absence after validating it against the ELDA employment history
+func (s *Service) Create(ctx context.Context, req *elda.AbwesenheitCreateRequest, createdBy *uuid.UUID) (*elda.Abwesenheit, error) {
+	a := &elda.Abwesenheit{
+		ELDAAccountID: req.ELDAAccountID,
+		SVNummer:      req.SVNummer,
+		CreatedBy:     createdBy,
+	}
+	if err := applyCreateRequest(a, req); err != nil {
+		return nil, err
+	}
+
+	if err := elda.ValidateSVNummer(a.SVNummer); err != nil {
+		return nil, &ValidationError{Errors: []string{"sv_nummer: " + err.Error()}}
+	}
+
+	history, err := s.meldungRepo.GetHistoryBySVNummer(ctx, a.ELDAAccountID, a.SVNummer)
+	if err != nil {
+		return nil, err
+	}
+
+	// History is ordered newest first; take the name from the latest Anmeldung
+	for _, m := range history {
+		if m.Type == elda.MeldungTypeAnmeldung && m.Status == elda.MeldungStatusAccepted {
+			a.Vorname = m.Vorname
+			a.Nachname = m.Nachname
+			break
+		}
+	}
+	if a.Vorname == "" && a.Nachname == "" {
+		return nil, ErrNotEmployed
+	}
+
+	if err := s.validate(ctx, a, history); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, a); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("absence recorded",
+		"id", a.ID,
+		"elda_account_id", a.ELDAAccountID,
+		"art", a.Art)
+
+	return a, nil
+}
+
+// Update changes an absence, e.g. to close an ongoing Krankenstand
+func (s *Service) Update(ctx context.Context, id uuid.UUID, req *elda.AbwesenheitCreateRequest) (*elda.Abwesenheit, error) {
+	a, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyCreateRequest(a, req); err != nil {
+		return nil, err
+	}
+
+	history, err := s.meldungRepo.GetHistoryBySVNummer(ctx, a.ELDAAccountID, a.SVNummer)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validate(ctx, a, history); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (s *Service) validate(ctx context.Context, a *elda.Abwesenheit, history []*elda.ELDAMeldung) error {
+	existing, err := s.repo.List(ctx, ListFilter{
+		ELDAAccountID: a.ELDAAccountID,
+		SVNummer:      a.SVNummer,
+	})
+	if err != nil {
+		return err
+	}
+
+	if errs := ValidateAbwesenheit(a, Beschaeftigungszeitraeume(history, a.SVNummer), existing); len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func applyCreateRequest(a *elda.Abwesenheit, req *elda.AbwesenheitCreateRequest) error {
+	if req.Art != "" {
+		a.Art = req.Art
+	}
+	if req.Von != "" {
+		t, err := time.Parse("2006-01-02", req.Von)
+		if err != nil {
+			return &ValidationError{Errors: []string{"von: ungültiges Datum"}}
+		}
+		a.Von = t
+	}
+	if req.Bis != "" {
+		t, err := time.Parse("2006-01-02", req.Bis)
+		if err != nil {
+			return &ValidationError{Errors: []string{"bis: ungültiges Datum"}}
+		}
+		a.Bis = &t
+	}
+	a.Bestaetigung = req.Bestaetigung
+	if req.Bemerkung != "" {
+		a.Bemerkung = req.Bemerkung
+	}
+	return nil
+}
+
+// OwnsELDAAccount reports whether an ELDA account is one of the tenant's.
+// Absences and their AUAs belong to the tenant of their ELDA account.
+func (s *Service) OwnsELDAAccount(ctx context.Context, tenantID, eldaAccountID uuid.UUID) (bool, error) {
+	return s.repo.ELDAAccountBelongsToTenant(ctx, tenantID, eldaAccountID)
+}
+
+// Get returns an absence
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*elda.Abwesenheit, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List lists the absences of an ELDA account
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]*elda.Abwesenheit, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Delete deletes an absence
+func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// BuildAUA derives the Arbeitsbestätigung of an Arbeitsunfähigkeit. The
+// Entgeltfortzahlung periods are counted from the first day of the absence.
+func BuildAUA(a *elda.Abwesenheit, eintritt time.Time) *elda.AUAMeldung {
+	arbeitsunfall := a.Art == elda.AbwesenheitArbeitsunfall
+	efz := elda.CalculateEntgeltfortzahlung(eintritt, a.Von, arbeitsunfall)
+
+	m := &elda.AUAMeldung{
+		AbwesenheitID:     a.ID,
+		ELDAAccountID:     a.ELDAAccountID,
+		SVNummer:          a.SVNummer,
+		Status:            elda.AUAStatusDraft,
+		Arbeitsunfall:     arbeitsunfall,
+		LetzterArbeitstag: a.Von.AddDate(0, 0, -1),
+		ArbeitsunfaehigAb: a.Von,
+		EntgeltVollBis:    a.Von.AddDate(0, 0, efz.VollWochen*7-1),
+	}
+	if efz.HalbWochen > 0 {
+		halbBis := m.EntgeltVollBis.AddDate(0, 0, efz.HalbWochen*7)
+		m.EntgeltHalbBis = &halbBis
+	}
+
+	return m
+}
+
+// CreateAUA drafts the Arbeits- und Entgeltbestätigung requested by the ÖGK for
+// an absence. Beitragsgrundlage and Sonderzahlungen default to the mBGM history.
+func (s *Service) CreateAUA(ctx context.Context, abwesenheitID uuid.UUID, req *elda.AUACreateRequest, createdBy *uuid.UUID) (*elda.AUAMeldung, error) {
+	a, err := s.repo.GetByID(ctx, abwesenheitID)
+	if err != nil {
+		return nil, err
+	}
+	if !a.Art.IsArbeitsunfaehigkeit() {
+		return nil, ErrNotArbeitsunfaehig
+	}
+
+	history, err := s.meldungRepo.GetHistoryBySVNummer(ctx, a.ELDAAccountID, a.SVNummer)
+	if err != nil {
+		return nil, err
+	}
+	zeitraum, ok := findZeitraum(Beschaeftigungszeitraeume(history, a.SVNummer), a)
+	if !ok {
+		return nil, ErrNotEmployed
+	}
+
+	m := BuildAUA(a, zeitraum.Von)
+	m.CreatedBy = createdBy
+	m.Anforderungsreferenz = req.Anforderungsreferenz
+	m.Anforderungsdatum = time.Now().Truncate(24 * time.Hour)
+	if req.Anforderungsdatum != "" {
+		t, err := time.Parse("2006-01-02", req.Anforderungsdatum)
+		if err != nil {
+			return nil, &ValidationError{Errors: []string{"anforderungsdatum: ungültiges Datum"}}
+		}
+		m.Anforderungsdatum = t
+	}
+
+	if req.Beitragsgrundlage != nil {
+		m.Beitragsgrundlage = *req.Beitragsgrundlage
+	} else {
+		m.Beitragsgrundlage, err = s.repo.GetLastBeitragsgrundlage(ctx, a.ELDAAccountID, a.SVNummer, a.Von)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if m.Beitragsgrundlage <= 0 {
+		return nil, ErrBeitragsgrundlageZero
+	}
+
+	m.Sonderzahlungen = req.Sonderzahlungen
+	if m.Sonderzahlungen == 0 {
+		m.Sonderzahlungen, err = s.repo.SumSonderzahlungen(ctx, a.ELDAAccountID, a.SVNummer, a.Von)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.CreateAUA(ctx, m); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("AUA drafted",
+		"id", m.ID,
+		"abwesenheit_id", a.ID,
+		"anforderungsreferenz", m.Anforderungsreferenz)
+
+	return m, nil
+}
+
+// GetAUA returns an AUA
+func (s *Service) GetAUA(ctx context.Context, id uuid.UUID) (*elda.AUAMeldung, error) {
+	return s.repo.GetAUA(ctx, id)
+}
+
+// ListAUAs lists the AUAs of an absence
+func (s *Service) ListAUAs(ctx context.Context, abwesenheitID uuid.UUID) ([]*elda.AUAMeldung, error) {
+	return s.repo.ListAUAs(ctx, abwesenheitID)
+}
+
+// PreviewAUA renders the XML of an AUA
+func (s *Service) PreviewAUA(ctx context.Context, id uuid.UUID, dienstgeberNr string) ([]byte, error) {
+	m, a, err := s.getAUAWithAbwesenheit(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return elda.MarshalAUADocument(BuildAUADocument(m, a, dienstgeberNr))
+}
+
+// SubmitAUA submits an AUA through the ELDA channel
+func (s *Service) SubmitAUA(ctx context.Context, id uuid.UUID, dienstgeberNr string) (*elda.AUASubmitResult, error) {
+	m, a, err := s.getAUAWithAbwesenheit(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Status != elda.AUAStatusDraft && m.Status != elda.AUAStatusRejected {
+		return nil, ErrAUAAlreadySubmitted
+	}
+
+	rec := rawpayload.NewRecorder()
+	result, err := s.eldaService.SubmitAUA(rawpayload.WithRecorder(ctx, rec), BuildAUADocument(m, a, dienstgeberNr))
+
+	now := time.Now()
+	m.SubmittedAt = &now
+	if result != nil {
+		m.RequestXML = result.RequestXML
+		s.payloads.Save(ctx, rawpayload.Reference{
+			ELDAAccountID:     &m.ELDAAccountID,
+			Module:            rawpayload.ModuleELDA,
+			ReferenceType:     "aua_meldung",
+			ReferenceID:       &m.ID,
+			ProviderReference: result.Protokollnummer,
+			Outcome:           rawpayload.OutcomeOf(err == nil, result.ErrorCode != ""),
+		}, rec)
+	}
+
+	if err != nil {
+		m.Status = elda.AUAStatusRejected
+		if result != nil {
+			m.ErrorCode = result.ErrorCode
+			m.ErrorMessage = result.ErrorMessage
+		}
+		if updateErr := s.repo.UpdateAUA(ctx, m); updateErr != nil {
+			s.logger.Error("failed to update rejected AUA", "id", id, "error", updateErr)
+		}
+		return result, err
+	}
+
+	m.Status = elda.AUAStatusSubmitted
+	m.Protokollnummer = result.Protokollnummer
+	m.ErrorCode = ""
+	m.ErrorMessage = ""
+
+	if updateErr := s.repo.UpdateAUA(ctx, m); updateErr != nil {
+		s.logger.Error("failed to update submitted AUA", "id", id, "error", updateErr)
+	}
+
+	s.logger.Info("AUA submitted",
+		"id", id,
+		"protokollnummer", result.Protokollnummer)
+
+	return result, nil
+}
+
+func (s *Service) getAUAWithAbwesenheit(ctx context.Context, id uuid.UUID) (*elda.AUAMeldung, *elda.Abwesenheit, error) {
+	m, err := s.repo.GetAUA(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := s.repo.GetByID(ctx, m.AbwesenheitID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, a, nil
+}
+
+// BuildAUADocument builds the ELDA XML document of an AUA
+func BuildAUADocument(m *elda.AUAMeldung, a *elda.Abwesenheit, dienstgeberNr string) *elda.AUADocument {
+	doc := &elda.AUADocument{
+		XMLNS: elda.ELDANS,
+		Kopf: elda.ELDAKopf{
+			DienstgeberNr: dienstgeberNr,
+			Datum:         time.Now().Format("2006-01-02"),
+			MeldungsArt:   "AUA",
+		},
+		Anforderungsreferenz: m.Anforderungsreferenz,
+		SVNummer:             m.SVNummer,
+		Familienname:         a.Nachname,
+		Vorname:              a.Vorname,
+		Arbeitsunfall:        m.Arbeitsunfall,
+		LetzterArbeitstag:    m.LetzterArbeitstag.Format("2006-01-02"),
+		ArbeitsunfaehigAb:    m.ArbeitsunfaehigAb.Format("2006-01-02"),
+		EntgeltVollBis:       m.EntgeltVollBis.Format("2006-01-02"),
+		Beitragsgrundlage:    formatCents(m.Beitragsgrundlage),
+	}
+	if m.EntgeltHalbBis != nil {
+		doc.EntgeltHalbBis = m.EntgeltHalbBis.Format("2006-01-02")
+	}
+	if m.Sonderzahlungen > 0 {
+		doc.Sonderzahlungen = formatCents(m.Sonderzahlungen)
+	}
+
+	return doc
+}
+
+func formatCents(cents int64) string {
+	return strconv.FormatFloat(float64(cents)/100, 'f', 2, 64)
+}
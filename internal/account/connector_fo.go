@@ -10,7 +10,7 @@ import (
 
 // FinanzOnlineConnector tests connections to FinanzOnline
 type FinanzOnlineConnector struct {
-	client *fonws.Client
+	client fonws.Caller
 }
 
 // NewFinanzOnlineConnector creates a new FO connector
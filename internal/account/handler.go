@@ -501,17 +501,17 @@ func (h *Handler) toResponse(account *Account, creds interface{}) *AccountRespon
 		Status:       account.Status,
 		Credentials:  creds,
 		ErrorMessage: account.ErrorMessage,
-		CreatedAt:    account.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:    account.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		CreatedAt:    account.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:    account.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 
 	if account.LastVerifiedAt != nil {
-		s := account.LastVerifiedAt.Format("2006-01-02T15:04:05Z")
+		s := account.LastVerifiedAt.UTC().Format(time.RFC3339)
 		resp.LastVerifiedAt = &s
 	}
 
 	if account.LastSyncAt != nil {
-		s := account.LastSyncAt.Format("2006-01-02T15:04:05Z")
+		s := account.LastSyncAt.UTC().Format(time.RFC3339)
 		resp.LastSyncAt = &s
 	}
 
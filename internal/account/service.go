@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"austrian-business-infrastructure/internal/account/types"
+	"austrian-business-infrastructure/internal/analytics"
 	"github.com/google/uuid"
 )
 
@@ -33,6 +34,7 @@ type Service struct {
 	repo       *Repository
 	encryptor  *Encryptor
 	connectors map[string]Connector
+	analytics  *analytics.Emitter
 }
 
 // NewService creates a new account service
@@ -54,6 +56,11 @@ func (s *Service) RegisterConnector(accountType string, connector Connector) {
 	s.connectors[accountType] = connector
 }
 
+// SetAnalytics reports connected FinanzOnline, ELDA and Firmenbuch accounts to the product analytics
+func (s *Service) SetAnalytics(emitter *analytics.Emitter) {
+	s.analytics = emitter
+}
+
 // CreateAccountInput defines input for creating an account
 type CreateAccountInput struct {
 	TenantID    uuid.UUID
@@ -105,6 +112,7 @@ func (s *Service) CreateAccount(ctx context.Context, input *CreateAccountInput)
 		return nil, err
 	}
 
+	s.analytics.Track(ctx, input.TenantID, uuid.Nil, analytics.EventAccountCreated, analytics.Properties{"account_type": input.Type})
 	return created, nil
 }
 
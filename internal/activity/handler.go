@@ -0,0 +1,182 @@
+package activity
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// Handler handles activity feed HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new activity handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers activity routes
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/activity/{entityType}/{entityId}", requireAuth(http.HandlerFunc(h.Feed)))
+	router.Handle("POST /api/v1/activity/{entityType}/{entityId}/comments", requireAuth(http.HandlerFunc(h.AddComment)))
+	router.Handle("DELETE /api/v1/activity/{entityType}/{entityId}/comments/{commentId}", requireAuth(http.HandlerFunc(h.DeleteComment)))
+}
+
+// Feed handles GET /api/v1/activity/{entityType}/{entityId}
+func (h *Handler) Feed(w http.ResponseWriter, r *http.Request) {
+	tenantID, entityType, entityID, ok := h.parseEntity(w, r)
+	if !ok {
+		return
+	}
+
+	filter := FeedFilter{
+		TenantID:   tenantID,
+		EntityType: entityType,
+		EntityID:   entityID,
+	}
+
+	q := r.URL.Query()
+	if kinds := q.Get("kinds"); kinds != "" {
+		for _, k := range strings.Split(kinds, ",") {
+			kind := Kind(strings.TrimSpace(k))
+			if !validKind(kind) {
+				api.BadRequest(w, "invalid kind: "+string(kind))
+				return
+			}
+			filter.Kinds = append(filter.Kinds, kind)
+		}
+	}
+	if beforeStr := q.Get("before"); beforeStr != "" {
+		before, err := time.Parse(time.RFC3339Nano, beforeStr)
+		if err != nil {
+			api.BadRequest(w, "invalid before, expected RFC 3339 timestamp")
+			return
+		}
+		filter.Before = &before
+	}
+	if limitStr := q.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filter.Limit = limit
+		}
+	}
+
+	feed, err := h.service.Feed(r.Context(), filter)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, feed)
+}
+
+// AddCommentRequest is the body of POST .../comments
+type AddCommentRequest struct {
+	Body string `json:"body"`
+}
+
+// AddComment handles POST /api/v1/activity/{entityType}/{entityId}/comments
+func (h *Handler) AddComment(w http.ResponseWriter, r *http.Request) {
+	tenantID, entityType, entityID, ok := h.parseEntity(w, r)
+	if !ok {
+		return
+	}
+
+	var req AddCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	var userID *uuid.UUID
+	if uid, err := uuid.Parse(api.GetUserID(r.Context())); err == nil {
+		userID = &uid
+	}
+
+	comment, err := h.service.AddComment(r.Context(), tenantID, entityType, entityID, userID, req.Body)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, comment)
+}
+
+// DeleteComment handles DELETE /api/v1/activity/{entityType}/{entityId}/comments/{commentId}
+func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	tenantID, entityType, entityID, ok := h.parseEntity(w, r)
+	if !ok {
+		return
+	}
+
+	commentID, err := uuid.Parse(r.PathValue("commentId"))
+	if err != nil {
+		api.BadRequest(w, "invalid comment ID")
+		return
+	}
+	userID, err := uuid.Parse(api.GetUserID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "user not found in context")
+		return
+	}
+	role := api.GetUserRole(r.Context())
+	isAdmin := role == "owner" || role == "admin"
+
+	if err := h.service.DeleteComment(r.Context(), tenantID, entityType, entityID, commentID, userID, isAdmin); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseEntity reads the tenant and the entity path values, writing an error
+// response if one is invalid
+func (h *Handler) parseEntity(w http.ResponseWriter, r *http.Request) (uuid.UUID, EntityType, uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return uuid.Nil, "", uuid.Nil, false
+	}
+	entityType, err := ParseEntityType(r.PathValue("entityType"))
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return uuid.Nil, "", uuid.Nil, false
+	}
+	entityID, err := uuid.Parse(r.PathValue("entityId"))
+	if err != nil {
+		api.BadRequest(w, "invalid entity ID")
+		return uuid.Nil, "", uuid.Nil, false
+	}
+	return tenantID, entityType, entityID, true
+}
+
+func validKind(kind Kind) bool {
+	for _, k := range AllKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrEntityNotFound):
+		api.NotFound(w, "entity not found")
+	case errors.Is(err, ErrCommentNotFound):
+		api.NotFound(w, "comment not found")
+	case errors.Is(err, ErrCommentForbidden):
+		api.Forbidden(w, err.Error())
+	case errors.Is(err, ErrInvalidEntityType), errors.Is(err, ErrEmptyComment), errors.Is(err, ErrCommentTooLong):
+		api.BadRequest(w, err.Error())
+	default:
+		api.InternalError(w)
+	}
+}
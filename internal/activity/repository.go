@@ -0,0 +1,315 @@
+package activity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/pkg/database"
+)
+
+// Repository reads the feed sources and stores comments
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new activity repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// EntityExists reports whether the entity belongs to the tenant. A document
+// exists only if the viewer of ctx may see it.
+func (r *Repository) EntityExists(ctx context.Context, tenantID uuid.UUID, entityType EntityType, entityID uuid.UUID) (bool, error) {
+	table, ok := entityTables[entityType]
+	if !ok {
+		return false, ErrInvalidEntityType
+	}
+
+	var access string
+	args := []interface{}{entityID, tenantID}
+	if entityType == EntityDocument {
+		access, args = document.AccessCondition(ctx, "d", args)
+	}
+
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s d WHERE d.id = $1 AND d.tenant_id = $2%s)`, table, access)
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check entity: %w", err)
+	}
+	return exists, nil
+}
+
+// ListEntries loads up to filter.Limit entries of each selected source in one
+// round trip. The entries are not merged; see Service.Feed.
+func (r *Repository) ListEntries(ctx context.Context, filter FeedFilter) ([]*Entry, error) {
+	var entries []*Entry
+	collect := func(scan func(pgx.Rows) (*Entry, error)) func(pgx.Rows) error {
+		return func(rows pgx.Rows) error {
+			for rows.Next() {
+				e, err := scan(rows)
+				if err != nil {
+					return err
+				}
+				entries = append(entries, e)
+			}
+			return rows.Err()
+		}
+	}
+
+	var queries []database.BatchQuery
+	if filter.includes(KindAudit) {
+		queries = append(queries, database.BatchQuery{
+			SQL: `
+				SELECT a.id, a.created_at, a.action, a.details,
+					a.user_id, COALESCE(u.name, ''), COALESCE(u.email, '')
+				FROM audit_logs a
+				LEFT JOIN users u ON u.id = a.user_id
+				WHERE a.tenant_id = $1 AND a.resource_type = $2 AND a.resource_id = $3
+					AND ($4::timestamptz IS NULL OR a.created_at < $4)
+				ORDER BY a.created_at DESC
+				LIMIT $5`,
+			Args: []any{filter.TenantID, string(filter.EntityType), filter.EntityID, filter.Before, filter.Limit},
+			Scan: collect(scanAuditEntry),
+		})
+	}
+	if filter.includes(KindStatusChange) {
+		if filter.EntityType == EntityAntrag {
+			queries = append(queries, database.BatchQuery{
+				SQL: `
+					SELECT (t->>'date')::timestamptz AS occurred_at, COALESCE(t->>'status', ''), COALESCE(t->>'description', ''),
+						u.id, COALESCE(u.name, ''), COALESCE(u.email, '')
+					FROM foerderungs_antraege a
+					CROSS JOIN LATERAL jsonb_array_elements(COALESCE(a.timeline, '[]'::jsonb)) t
+					LEFT JOIN users u ON u.id = NULLIF(t->>'created_by', '')::uuid
+					WHERE a.tenant_id = $1 AND a.id = $2
+						AND ($3::timestamptz IS NULL OR (t->>'date')::timestamptz < $3)
+					ORDER BY occurred_at DESC
+					LIMIT $4`,
+				Args: []any{filter.TenantID, filter.EntityID, filter.Before, filter.Limit},
+				Scan: collect(func(rows pgx.Rows) (*Entry, error) { return scanTimelineEntry(rows, filter.EntityID) }),
+			})
+		} else {
+			queries = append(queries, database.BatchQuery{
+				SQL: `
+					SELECT id, changed_at, old_status, new_status
+					FROM entity_status_changes
+					WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = $3
+						AND ($4::timestamptz IS NULL OR changed_at < $4)
+					ORDER BY changed_at DESC
+					LIMIT $5`,
+				Args: []any{filter.TenantID, string(filter.EntityType), filter.EntityID, filter.Before, filter.Limit},
+				Scan: collect(scanStatusChange),
+			})
+		}
+	}
+	if filter.includes(KindNotification) && filter.EntityType == EntityDocument {
+		access, args := document.AccessCondition(ctx, "d", []any{filter.TenantID, filter.EntityID, filter.Before, filter.Limit})
+		queries = append(queries, database.BatchQuery{
+			SQL: `
+				SELECT n.id, COALESCE(n.sent_at, n.created_at), n.notification_type, n.status,
+					COALESCE(n.error_message, ''), n.retry_count, COALESCE(u.name, '')
+				FROM notification_queue n
+				JOIN documents d ON d.id = n.document_id
+				LEFT JOIN users u ON u.id = n.user_id
+				WHERE d.tenant_id = $1 AND n.document_id = $2
+					AND ($3::timestamptz IS NULL OR COALESCE(n.sent_at, n.created_at) < $3)` + access + `
+				ORDER BY COALESCE(n.sent_at, n.created_at) DESC
+				LIMIT $4`,
+			Args: args,
+			Scan: collect(scanNotification),
+		})
+	}
+	if filter.includes(KindWebhook) {
+		queries = append(queries, database.BatchQuery{
+			SQL: `
+				SELECT d.id, d.created_at, d.event_type, d.status, d.attempt_count,
+					d.response_status, COALESCE(d.last_error, ''), w.name
+				FROM webhook_deliveries d
+				JOIN webhooks w ON w.id = d.webhook_id
+				WHERE d.tenant_id = $1 AND d.payload->'data' @> jsonb_build_object($2::text, $3::text)
+					AND ($4::timestamptz IS NULL OR d.created_at < $4)
+				ORDER BY d.created_at DESC
+				LIMIT $5`,
+			Args: []any{filter.TenantID, webhookDataKeys[filter.EntityType], filter.EntityID.String(), filter.Before, filter.Limit},
+			Scan: collect(scanWebhookDelivery),
+		})
+	}
+	if filter.includes(KindComment) {
+		queries = append(queries, database.BatchQuery{
+			SQL: `
+				SELECT c.id, c.created_at, c.body, c.user_id, COALESCE(u.name, ''), COALESCE(u.email, '')
+				FROM entity_comments c
+				LEFT JOIN users u ON u.id = c.user_id
+				WHERE c.tenant_id = $1 AND c.entity_type = $2 AND c.entity_id = $3
+					AND ($4::timestamptz IS NULL OR c.created_at < $4)
+				ORDER BY c.created_at DESC
+				LIMIT $5`,
+			Args: []any{filter.TenantID, string(filter.EntityType), filter.EntityID, filter.Before, filter.Limit},
+			Scan: collect(scanComment),
+		})
+	}
+
+	if len(queries) == 0 {
+		return nil, nil
+	}
+	if err := database.RunBatch(ctx, r.db, queries...); err != nil {
+		return nil, fmt.Errorf("list activity: %w", err)
+	}
+	return entries, nil
+}
+
+func actor(userID *uuid.UUID, name, email string) *Actor {
+	if userID == nil {
+		return nil
+	}
+	return &Actor{UserID: *userID, Name: name, Email: email}
+}
+
+func scanAuditEntry(rows pgx.Rows) (*Entry, error) {
+	e := &Entry{Kind: KindAudit}
+	var userID *uuid.UUID
+	var name, email string
+	if err := rows.Scan(&e.ID, &e.OccurredAt, &e.Summary, &e.Details, &userID, &name, &email); err != nil {
+		return nil, err
+	}
+	e.Actor = actor(userID, name, email)
+	return e, nil
+}
+
+func scanTimelineEntry(rows pgx.Rows, antragID uuid.UUID) (*Entry, error) {
+	e := &Entry{Kind: KindStatusChange}
+	var status, description string
+	var userID *uuid.UUID
+	var name, email string
+	if err := rows.Scan(&e.OccurredAt, &status, &description, &userID, &name, &email); err != nil {
+		return nil, err
+	}
+	// Timeline entries have no ID; derive a stable one for clients
+	e.ID = uuid.NewSHA1(antragID, []byte(e.OccurredAt.UTC().Format(time.RFC3339Nano)+status))
+	e.Summary = description
+	if e.Summary == "" {
+		e.Summary = "Status changed to " + status
+	}
+	e.Details = map[string]interface{}{"new_status": status}
+	e.Actor = actor(userID, name, email)
+	return e, nil
+}
+
+func scanStatusChange(rows pgx.Rows) (*Entry, error) {
+	e := &Entry{Kind: KindStatusChange}
+	var oldStatus *string
+	var newStatus string
+	if err := rows.Scan(&e.ID, &e.OccurredAt, &oldStatus, &newStatus); err != nil {
+		return nil, err
+	}
+	e.Details = map[string]interface{}{"new_status": newStatus}
+	if oldStatus == nil {
+		e.Summary = "Created with status " + newStatus
+	} else {
+		e.Summary = "Status changed from " + *oldStatus + " to " + newStatus
+		e.Details["old_status"] = *oldStatus
+	}
+	return e, nil
+}
+
+func scanNotification(rows pgx.Rows) (*Entry, error) {
+	e := &Entry{Kind: KindNotification}
+	var channel, status, errMsg, recipient string
+	var retries int
+	if err := rows.Scan(&e.ID, &e.OccurredAt, &channel, &status, &errMsg, &retries, &recipient); err != nil {
+		return nil, err
+	}
+	switch status {
+	case "sent":
+		e.Summary = fmt.Sprintf("%s notification sent to %s", channel, recipient)
+	case "failed":
+		e.Summary = fmt.Sprintf("%s notification to %s failed", channel, recipient)
+	default:
+		e.Summary = fmt.Sprintf("%s notification to %s queued", channel, recipient)
+	}
+	e.Details = map[string]interface{}{"channel": channel, "status": status, "recipient": recipient, "retries": retries}
+	if errMsg != "" {
+		e.Details["error"] = errMsg
+	}
+	return e, nil
+}
+
+func scanWebhookDelivery(rows pgx.Rows) (*Entry, error) {
+	e := &Entry{Kind: KindWebhook}
+	var eventType, status, lastError, webhookName string
+	var attempts int
+	var responseStatus *int
+	if err := rows.Scan(&e.ID, &e.OccurredAt, &eventType, &status, &attempts, &responseStatus, &lastError, &webhookName); err != nil {
+		return nil, err
+	}
+	e.Summary = fmt.Sprintf("Webhook %q: %s delivery %s", webhookName, eventType, status)
+	e.Details = map[string]interface{}{"webhook": webhookName, "event_type": eventType, "status": status, "attempts": attempts}
+	if responseStatus != nil {
+		e.Details["response_status"] = *responseStatus
+	}
+	if lastError != "" {
+		e.Details["error"] = lastError
+	}
+	return e, nil
+}
+
+func scanComment(rows pgx.Rows) (*Entry, error) {
+	e := &Entry{Kind: KindComment}
+	var userID *uuid.UUID
+	var name, email string
+	if err := rows.Scan(&e.ID, &e.OccurredAt, &e.Summary, &userID, &name, &email); err != nil {
+		return nil, err
+	}
+	e.Actor = actor(userID, name, email)
+	return e, nil
+}
+
+// CreateComment stores a comment
+func (r *Repository) CreateComment(ctx context.Context, c *Comment) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return r.db.QueryRow(ctx, `
+		INSERT INTO entity_comments (id, tenant_id, entity_type, entity_id, user_id, body)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`, c.ID, c.TenantID, string(c.EntityType), c.EntityID, c.UserID, c.Body).Scan(&c.CreatedAt)
+}
+
+// GetComment returns a comment of the tenant
+func (r *Repository) GetComment(ctx context.Context, tenantID, id uuid.UUID) (*Comment, error) {
+	c := &Comment{}
+	var entityType string
+	err := r.db.QueryRow(ctx, `
+		SELECT id, tenant_id, entity_type, entity_id, user_id, body, created_at
+		FROM entity_comments
+		WHERE id = $1 AND tenant_id = $2
+	`, id, tenantID).Scan(&c.ID, &c.TenantID, &entityType, &c.EntityID, &c.UserID, &c.Body, &c.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get comment: %w", err)
+	}
+	c.EntityType = EntityType(entityType)
+	return c, nil
+}
+
+// DeleteComment deletes a comment of the tenant
+func (r *Repository) DeleteComment(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM entity_comments WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("delete comment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
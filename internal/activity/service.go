@@ -0,0 +1,130 @@
+package activity
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultFeedLimit = 50
+	maxFeedLimit     = 200
+)
+
+// Service builds activity feeds and manages comments
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new activity service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Feed returns a page of the entity's activity, newest first. Each source is
+// read up to the limit and the results are merged, so a page is complete
+// whichever sources its entries come from.
+func (s *Service) Feed(ctx context.Context, filter FeedFilter) (*Feed, error) {
+	if _, ok := entityTables[filter.EntityType]; !ok {
+		return nil, ErrInvalidEntityType
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultFeedLimit
+	}
+	filter.Limit = min(filter.Limit, maxFeedLimit)
+
+	exists, err := s.repo.EntityExists(ctx, filter.TenantID, filter.EntityType, filter.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrEntityNotFound
+	}
+
+	entries, err := s.repo.ListEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &Feed{
+		EntityType: filter.EntityType,
+		EntityID:   filter.EntityID,
+		Entries:    MergeEntries(entries, filter.Limit),
+	}
+	if len(feed.Entries) == filter.Limit {
+		next := feed.Entries[len(feed.Entries)-1].OccurredAt
+		feed.NextBefore = &next
+	}
+	return feed, nil
+}
+
+// MergeEntries sorts entries newest first and keeps at most limit. Entries
+// with the same time are ordered by kind so that pages are stable.
+func MergeEntries(entries []*Entry, limit int) []*Entry {
+	rank := make(map[Kind]int, len(AllKinds))
+	for i, k := range AllKinds {
+		rank[k] = i
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].OccurredAt.Equal(entries[j].OccurredAt) {
+			return entries[i].OccurredAt.After(entries[j].OccurredAt)
+		}
+		return rank[entries[i].Kind] < rank[entries[j].Kind]
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	if entries == nil {
+		entries = []*Entry{}
+	}
+	return entries
+}
+
+// AddComment adds a comment to an entity of the tenant
+func (s *Service) AddComment(ctx context.Context, tenantID uuid.UUID, entityType EntityType, entityID uuid.UUID, userID *uuid.UUID, body string) (*Comment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, ErrEmptyComment
+	}
+	if utf8.RuneCountInString(body) > MaxCommentLength {
+		return nil, ErrCommentTooLong
+	}
+
+	exists, err := s.repo.EntityExists(ctx, tenantID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrEntityNotFound
+	}
+
+	c := &Comment{
+		TenantID:   tenantID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		UserID:     userID,
+		Body:       body,
+	}
+	if err := s.repo.CreateComment(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DeleteComment deletes a comment. Only its author or an admin may delete it.
+func (s *Service) DeleteComment(ctx context.Context, tenantID uuid.UUID, entityType EntityType, entityID, commentID, userID uuid.UUID, isAdmin bool) error {
+	c, err := s.repo.GetComment(ctx, tenantID, commentID)
+	if err != nil {
+		return err
+	}
+	if c.EntityType != entityType || c.EntityID != entityID {
+		return ErrCommentNotFound
+	}
+	if !isAdmin && (c.UserID == nil || *c.UserID != userID) {
+		return ErrCommentForbidden
+	}
+	return s.repo.DeleteComment(ctx, tenantID, commentID)
+}
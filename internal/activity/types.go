@@ -0,0 +1,126 @@
+package activity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEntityNotFound    = errors.New("entity not found")
+	ErrInvalidEntityType = errors.New("invalid entity type, expected invoice, document or antrag")
+	ErrCommentNotFound   = errors.New("comment not found")
+	ErrCommentForbidden  = errors.New("only the author or an admin can delete a comment")
+	ErrEmptyComment      = errors.New("comment must not be empty")
+	ErrCommentTooLong    = errors.New("comment exceeds 5000 characters")
+)
+
+// EntityType is the kind of entity a feed is built for
+type EntityType string
+
+const (
+	EntityInvoice  EntityType = "invoice"
+	EntityDocument EntityType = "document"
+	EntityAntrag   EntityType = "antrag"
+)
+
+// entityTables maps entity types to their table
+var entityTables = map[EntityType]string{
+	EntityInvoice:  "invoices",
+	EntityDocument: "documents",
+	EntityAntrag:   "foerderungs_antraege",
+}
+
+// webhookDataKeys is the key of the entity ID in webhook event data
+var webhookDataKeys = map[EntityType]string{
+	EntityInvoice:  "invoice_id",
+	EntityDocument: "document_id",
+	EntityAntrag:   "antrag_id",
+}
+
+// ParseEntityType validates an entity type from a URL
+func ParseEntityType(s string) (EntityType, error) {
+	t := EntityType(s)
+	if _, ok := entityTables[t]; !ok {
+		return "", ErrInvalidEntityType
+	}
+	return t, nil
+}
+
+// Kind is the source of a feed entry
+type Kind string
+
+const (
+	KindAudit        Kind = "audit"
+	KindStatusChange Kind = "status_change"
+	KindNotification Kind = "notification"
+	KindWebhook      Kind = "webhook_delivery"
+	KindComment      Kind = "comment"
+)
+
+// AllKinds lists the feed sources in display order
+var AllKinds = []Kind{KindAudit, KindStatusChange, KindNotification, KindWebhook, KindComment}
+
+// Actor is the user behind an entry. Entries without an actor were caused by
+// the system (databox sync, worker, database trigger).
+type Actor struct {
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+	Email  string    `json:"email,omitempty"`
+}
+
+// Entry is one item of an entity's activity feed
+type Entry struct {
+	ID         uuid.UUID              `json:"id"`
+	Kind       Kind                   `json:"kind"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Actor      *Actor                 `json:"actor,omitempty"`
+	Summary    string                 `json:"summary"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+}
+
+// FeedFilter selects a page of a feed
+type FeedFilter struct {
+	TenantID   uuid.UUID
+	EntityType EntityType
+	EntityID   uuid.UUID
+	Kinds      []Kind     // empty means all
+	Before     *time.Time // entries strictly before, for paging
+	Limit      int
+}
+
+// includes reports whether the filter selects a kind
+func (f FeedFilter) includes(kind Kind) bool {
+	if len(f.Kinds) == 0 {
+		return true
+	}
+	for _, k := range f.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Feed is a page of an entity's activity, newest first
+type Feed struct {
+	EntityType EntityType `json:"entity_type"`
+	EntityID   uuid.UUID  `json:"entity_id"`
+	Entries    []*Entry   `json:"entries"`
+	NextBefore *time.Time `json:"next_before,omitempty"` // pass as ?before= for the next page
+}
+
+// Comment is a user comment on an entity
+type Comment struct {
+	ID         uuid.UUID  `json:"id"`
+	TenantID   uuid.UUID  `json:"tenant_id"`
+	EntityType EntityType `json:"entity_type"`
+	EntityID   uuid.UUID  `json:"entity_id"`
+	UserID     *uuid.UUID `json:"user_id,omitempty"`
+	Body       string     `json:"body"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// MaxCommentLength is the maximum comment length in characters
+const MaxCommentLength = 5000
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"austrian-business-infrastructure/internal/constants"
+	"austrian-business-infrastructure/pkg/httpclient"
 )
 
 const (
@@ -24,7 +25,7 @@ type Client struct {
 	apiKey      string
 	model       string
 	maxTokens   int
-	httpClient  *http.Client
+	httpClient  *httpclient.Client
 	rateLimiter *RateLimiter
 	mu          sync.Mutex
 }
@@ -63,6 +64,9 @@ type Response struct {
 	StopReason   string        `json:"stop_reason"`
 	StopSequence string        `json:"stop_sequence,omitempty"`
 	Usage        Usage         `json:"usage"`
+
+	// quality records the caller's verdict on a routed answer
+	quality func(valid bool, confidence float64)
 }
 
 // ContentBlock represents a content block in the response
@@ -86,6 +90,15 @@ type ErrorResponse struct {
 	} `json:"error"`
 }
 
+// Completer sends prompts to the model. *Client implements it against the
+// Claude API; internal/fakes provides a scripted in-memory implementation.
+type Completer interface {
+	Complete(ctx context.Context, systemPrompt, userPrompt string, temperature float64) (*Response, error)
+	CompleteWithRetry(ctx context.Context, systemPrompt, userPrompt string, temperature float64, maxRetries int) (*Response, error)
+}
+
+var _ Completer = (*Client)(nil)
+
 // NewClient creates a new Claude API client
 func NewClient(cfg ClientConfig) (*Client, error) {
 	if cfg.APIKey == "" {
@@ -108,15 +121,30 @@ func NewClient(cfg ClientConfig) (*Client, error) {
 		cfg.Timeout = constants.AIClientTimeout
 	}
 
-	return &Client{
-		apiKey:    cfg.APIKey,
-		model:     cfg.Model,
-		maxTokens: cfg.MaxTokens,
-		httpClient: &http.Client{
-			Timeout: cfg.Timeout,
-		},
+	c := &Client{
+		apiKey:      cfg.APIKey,
+		model:       cfg.Model,
+		maxTokens:   cfg.MaxTokens,
 		rateLimiter: NewRateLimiter(cfg.RateLimitPerMin),
-	}, nil
+	}
+
+	// Rate limits and server errors are retried with exponential backoff;
+	// every attempt waits for the rate limiter
+	httpCfg := httpclient.DefaultConfig("ai")
+	httpCfg.Timeout = cfg.Timeout
+	httpCfg.Budget = 0 // bounded by the attempts and the caller's context
+	httpCfg.Backoff.Initial = time.Second
+	httpCfg.Retryable = httpclient.RetryServerErrors
+	httpCfg.Hooks.BeforeAttempt = func(req *http.Request) error {
+		if err := c.rateLimiter.Wait(req.Context()); err != nil {
+			return fmt.Errorf("rate limiter: %w", err)
+		}
+		return nil
+	}
+	httpCfg.Hooks.AfterAttempt = httpclient.LogAttempts(nil)
+	c.httpClient = httpclient.New(httpCfg)
+
+	return c, nil
 }
 
 // Complete sends a completion request to Claude API
@@ -124,38 +152,10 @@ func (c *Client) Complete(ctx context.Context, systemPrompt, userPrompt string,
 	return c.CompleteWithRetry(ctx, systemPrompt, userPrompt, temperature, 3)
 }
 
-// CompleteWithRetry sends a completion request with retry logic
+// CompleteWithRetry sends a completion request with retry logic. maxRetries
+// is the number of attempts.
 func (c *Client) CompleteWithRetry(ctx context.Context, systemPrompt, userPrompt string, temperature float64, maxRetries int) (*Response, error) {
-	var lastErr error
-
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		// Wait for rate limiter
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limiter: %w", err)
-		}
-
-		resp, err := c.doRequest(ctx, systemPrompt, userPrompt, temperature)
-		if err == nil {
-			return resp, nil
-		}
-
-		lastErr = err
-
-		// Check if error is retryable
-		if !isRetryableError(err) {
-			return nil, err
-		}
-
-		// Exponential backoff
-		backoff := time.Duration(1<<attempt) * time.Second
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-time.After(backoff):
-		}
-	}
-
-	return nil, fmt.Errorf("max retries exceeded: %w", lastErr)
+	return c.doRequest(httpclient.WithMaxRetries(ctx, max(maxRetries-1, 0)), systemPrompt, userPrompt, temperature)
 }
 
 func (c *Client) doRequest(ctx context.Context, systemPrompt, userPrompt string, temperature float64) (*Response, error) {
@@ -225,6 +225,16 @@ func (r *Response) GetText() string {
 	return r.Content[0].Text
 }
 
+// ReportQuality tells the router that served the response whether the
+// answer was usable, e.g. parsed as the JSON the prompt asked for, and
+// the confidence it claimed, 0 if none. It does nothing for responses
+// not served by a router.
+func (r *Response) ReportQuality(valid bool, confidence float64) {
+	if r != nil && r.quality != nil {
+		r.quality(valid, confidence)
+	}
+}
+
 // TotalTokens returns the total token count
 func (r *Response) TotalTokens() int {
 	return r.Usage.InputTokens + r.Usage.OutputTokens
@@ -235,18 +245,16 @@ type APIError struct {
 	StatusCode int
 	Type       string
 	Message    string
+	// Provider is the provider answering; empty for Claude
+	Provider string
 }
 
 func (e *APIError) Error() string {
-	return fmt.Sprintf("claude API error (status %d, type %s): %s", e.StatusCode, e.Type, e.Message)
-}
-
-func isRetryableError(err error) bool {
-	if apiErr, ok := err.(*APIError); ok {
-		// Retry on rate limit or server errors
-		return apiErr.StatusCode == 429 || apiErr.StatusCode >= 500
+	provider := e.Provider
+	if provider == "" {
+		provider = "claude"
 	}
-	return false
+	return fmt.Sprintf("%s API error (status %d, type %s): %s", provider, e.StatusCode, e.Type, e.Message)
 }
 
 // EstimateCost estimates the cost in cents based on token usage
@@ -0,0 +1,188 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/constants"
+	"austrian-business-infrastructure/pkg/httpclient"
+)
+
+// DefaultOpenAIBaseURL is the API of OpenAI itself. Other providers with a
+// compatible chat completions API, such as Azure OpenAI or Mistral, are
+// used by setting their base URL instead.
+const DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// OpenAIClient is a client of an OpenAI-compatible chat completions API.
+// It answers in the shape of Claude responses, so that it can stand in for
+// the Claude client as a secondary provider.
+type OpenAIClient struct {
+	baseURL     string
+	apiKey      string
+	model       string
+	maxTokens   int
+	httpClient  *httpclient.Client
+	rateLimiter *RateLimiter
+}
+
+// OpenAIConfig holds OpenAI-compatible client configuration
+type OpenAIConfig struct {
+	BaseURL         string
+	APIKey          string
+	Model           string
+	MaxTokens       int
+	RateLimitPerMin int
+	Timeout         time.Duration
+}
+
+var _ Completer = (*OpenAIClient)(nil)
+
+type openAIRequest struct {
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	MaxTokens   int       `json:"max_tokens,omitempty"`
+	Temperature float64   `json:"temperature"`
+}
+
+type openAIResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      Message `json:"message"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// NewOpenAIClient creates a new OpenAI-compatible client
+func NewOpenAIClient(cfg OpenAIConfig) (*OpenAIClient, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultOpenAIBaseURL
+	}
+	if cfg.MaxTokens == 0 {
+		cfg.MaxTokens = 4096
+	}
+	if cfg.RateLimitPerMin == 0 {
+		cfg.RateLimitPerMin = 60
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = constants.AIClientTimeout
+	}
+
+	c := &OpenAIClient{
+		baseURL:     strings.TrimRight(cfg.BaseURL, "/"),
+		apiKey:      cfg.APIKey,
+		model:       cfg.Model,
+		maxTokens:   cfg.MaxTokens,
+		rateLimiter: NewRateLimiter(cfg.RateLimitPerMin),
+	}
+
+	// Retried like the Claude client
+	httpCfg := httpclient.DefaultConfig("ai-openai")
+	httpCfg.Timeout = cfg.Timeout
+	httpCfg.Budget = 0
+	httpCfg.Backoff.Initial = time.Second
+	httpCfg.Retryable = httpclient.RetryServerErrors
+	httpCfg.Hooks.BeforeAttempt = func(req *http.Request) error {
+		if err := c.rateLimiter.Wait(req.Context()); err != nil {
+			return fmt.Errorf("rate limiter: %w", err)
+		}
+		return nil
+	}
+	httpCfg.Hooks.AfterAttempt = httpclient.LogAttempts(nil)
+	c.httpClient = httpclient.New(httpCfg)
+
+	return c, nil
+}
+
+// Complete sends a chat completion request
+func (c *OpenAIClient) Complete(ctx context.Context, systemPrompt, userPrompt string, temperature float64) (*Response, error) {
+	return c.CompleteWithRetry(ctx, systemPrompt, userPrompt, temperature, 3)
+}
+
+// CompleteWithRetry sends a chat completion request with retry logic.
+// maxRetries is the number of attempts.
+func (c *OpenAIClient) CompleteWithRetry(ctx context.Context, systemPrompt, userPrompt string, temperature float64, maxRetries int) (*Response, error) {
+	ctx = httpclient.WithMaxRetries(ctx, max(maxRetries-1, 0))
+
+	messages := make([]Message, 0, 2)
+	if systemPrompt != "" {
+		messages = append(messages, Message{Role: "system", Content: systemPrompt})
+	}
+	messages = append(messages, Message{Role: "user", Content: userPrompt})
+
+	body, err := json.Marshal(openAIRequest{
+		Model:       c.model,
+		Messages:    messages,
+		MaxTokens:   c.maxTokens,
+		Temperature: temperature,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	var parsed openAIResponse
+	if resp.StatusCode != http.StatusOK {
+		if json.Unmarshal(respBody, &parsed) == nil && parsed.Error != nil && parsed.Error.Message != "" {
+			return nil, &APIError{StatusCode: resp.StatusCode, Type: parsed.Error.Type, Message: parsed.Error.Message, Provider: ProviderOpenAI}
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: string(respBody), Provider: ProviderOpenAI}
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return nil, fmt.Errorf("unmarshal response: no choices")
+	}
+
+	choice := parsed.Choices[0]
+	return &Response{
+		ID:         parsed.ID,
+		Type:       "message",
+		Role:       "assistant",
+		Content:    []ContentBlock{{Type: "text", Text: choice.Message.Content}},
+		Model:      parsed.Model,
+		StopReason: choice.FinishReason,
+		Usage: Usage{
+			InputTokens:  parsed.Usage.PromptTokens,
+			OutputTokens: parsed.Usage.CompletionTokens,
+		},
+	}, nil
+}
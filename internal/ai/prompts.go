@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -40,6 +41,7 @@ type Prompt struct {
 // PromptLoader loads prompts from the database
 type PromptLoader struct {
 	db    *sql.DB
+	mu    sync.RWMutex
 	cache map[PromptType]*Prompt
 }
 
@@ -54,7 +56,10 @@ func NewPromptLoader(db *sql.DB) *PromptLoader {
 // Get retrieves a prompt by type
 func (l *PromptLoader) Get(ctx context.Context, promptType PromptType) (*Prompt, error) {
 	// Check cache first
-	if prompt, ok := l.cache[promptType]; ok {
+	l.mu.RLock()
+	prompt, ok := l.cache[promptType]
+	l.mu.RUnlock()
+	if ok {
 		return prompt, nil
 	}
 
@@ -65,7 +70,9 @@ func (l *PromptLoader) Get(ctx context.Context, promptType PromptType) (*Prompt,
 	}
 
 	// Cache it
+	l.mu.Lock()
 	l.cache[promptType] = prompt
+	l.mu.Unlock()
 	return prompt, nil
 }
 
@@ -108,6 +115,8 @@ func (l *PromptLoader) loadFromDB(ctx context.Context, promptType PromptType) (*
 
 // Refresh clears the cache to reload prompts from database
 func (l *PromptLoader) Refresh() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	l.cache = make(map[PromptType]*Prompt)
 }
 
@@ -133,7 +142,7 @@ func DefaultPrompts() map[PromptType]*Prompt {
 
 Antworte im folgenden JSON-Format:
 {
-  "document_type": "bescheid|ersuchen|info|rechnung|mahnung|sonstige",
+  "document_type": "bescheid|ersuchen|info|rechnung|mahnung|vertrag|sonstige",
   "document_subtype": "ergaenzungsersuchen|steuerbescheid|mahnbescheid|...",
   "priority": "critical|high|medium|low",
   "confidence": 0.0-1.0,
@@ -0,0 +1,84 @@
+package ai
+
+import (
+	"fmt"
+
+	"austrian-business-infrastructure/internal/config"
+	"austrian-business-infrastructure/pkg/httpclient"
+)
+
+// PolicyFromConfig returns the configured routing policy: AI_ROUTES, or
+// else the default policy over the providers that have an API key. It
+// returns nil if AI is disabled or no provider is configured.
+func PolicyFromConfig(cfg *config.AIConfig) (Policy, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.Routes != "" {
+		policy, err := ParsePolicy(cfg.Routes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AI_ROUTES: %w", err)
+		}
+		return policy, nil
+	}
+
+	var openAI []Target
+	if cfg.OpenAIAPIKey != "" {
+		openAI = append(openAI, Target{Provider: ProviderOpenAI, Model: cfg.OpenAIModel})
+	}
+	switch {
+	case cfg.ClaudeAPIKey != "":
+		return DefaultPolicy(
+			Target{Provider: ProviderClaude, Model: cfg.ClaudeModel},
+			Target{Provider: ProviderClaude, Model: cfg.ClaudeFastModel},
+			openAI...,
+		), nil
+	case len(openAI) > 0:
+		return DefaultPolicy(openAI[0], openAI[0]), nil
+	default:
+		return nil, nil
+	}
+}
+
+// NewRouterFromConfig creates a router with a client for every target of
+// the configured policy. It returns nil if AI is disabled or no provider
+// is configured.
+func NewRouterFromConfig(cfg *config.AIConfig) (*Router, error) {
+	policy, err := PolicyFromConfig(cfg)
+	if err != nil || policy == nil {
+		return nil, err
+	}
+
+	clients := make(map[Target]Completer)
+	for _, t := range policy.Targets() {
+		var client Completer
+		switch t.Provider {
+		case ProviderClaude:
+			client, err = NewClient(ClientConfig{
+				APIKey:          cfg.ClaudeAPIKey,
+				Model:           t.Model,
+				MaxTokens:       cfg.MaxTokens,
+				RateLimitPerMin: cfg.RateLimitPerMin,
+			})
+		case ProviderOpenAI:
+			client, err = NewOpenAIClient(OpenAIConfig{
+				BaseURL:         cfg.OpenAIBaseURL,
+				APIKey:          cfg.OpenAIAPIKey,
+				Model:           t.Model,
+				MaxTokens:       cfg.MaxTokens,
+				RateLimitPerMin: cfg.RateLimitPerMin,
+			})
+		default:
+			err = fmt.Errorf("unknown provider")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("AI target %s: %w", t, err)
+		}
+		clients[t] = client
+	}
+
+	return NewRouter(policy, clients, httpclient.BreakerConfig{
+		Threshold: cfg.HealthFailureThreshold,
+		Cooldown:  cfg.HealthCooldown,
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"austrian-business-infrastructure/pkg/money"
 )
 
 // ClassificationResponse represents a document classification result
@@ -45,13 +47,13 @@ type AmountResponse struct {
 
 // ExtractedAmount represents a single extracted amount
 type ExtractedAmount struct {
-	AmountType string  `json:"amount_type"`
-	Amount     float64 `json:"amount"`
-	Currency   string  `json:"currency"`
-	IsNegative bool    `json:"is_negative"`
-	Label      string  `json:"label"`
-	SourceText string  `json:"source_text"`
-	Confidence float64 `json:"confidence"`
+	AmountType string      `json:"amount_type"`
+	Amount     money.Money `json:"amount"`
+	Currency   string      `json:"currency"`
+	IsNegative bool        `json:"is_negative"`
+	Label      string      `json:"label"`
+	SourceText string      `json:"source_text"`
+	Confidence float64     `json:"confidence"`
 }
 
 // SuggestionResponse represents response suggestion results
@@ -65,6 +67,12 @@ type SuggestionResponse struct {
 
 // ParseClassification parses a classification response from Claude
 func ParseClassification(text string) (*ClassificationResponse, error) {
+	return ParseClassificationWithTypes(text, nil)
+}
+
+// ParseClassificationWithTypes parses a classification response that may use
+// tenant-specific document types in addition to the built-in ones
+func ParseClassificationWithTypes(text string, customTypes []string) (*ClassificationResponse, error) {
 	jsonStr := extractJSON(text)
 	if jsonStr == "" {
 		return nil, fmt.Errorf("no JSON found in response")
@@ -76,7 +84,7 @@ func ParseClassification(text string) (*ClassificationResponse, error) {
 	}
 
 	// Validate
-	if err := validateClassification(&resp); err != nil {
+	if err := validateClassification(&resp, customTypes); err != nil {
 		return nil, err
 	}
 
@@ -188,10 +196,13 @@ func extractJSON(text string) string {
 
 // Validation functions
 
-func validateClassification(c *ClassificationResponse) error {
+func validateClassification(c *ClassificationResponse, customTypes []string) error {
 	validTypes := map[string]bool{
 		"bescheid": true, "ersuchen": true, "info": true,
-		"rechnung": true, "mahnung": true, "sonstige": true,
+		"rechnung": true, "mahnung": true, "vertrag": true, "sonstige": true,
+	}
+	for _, t := range customTypes {
+		validTypes[t] = true
 	}
 	if !validTypes[c.DocumentType] {
 		return fmt.Errorf("invalid document_type: %s", c.DocumentType)
@@ -0,0 +1,184 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// modelPrices are the list prices in cents per million input and output
+// tokens, by a substring of the model name. The first match wins, so more
+// specific names come first.
+var modelPrices = []struct {
+	match         string
+	input, output float64
+}{
+	{"claude-3-haiku", 25, 125},
+	{"haiku", 80, 400},
+	{"opus", 1500, 7500},
+	{"sonnet", 300, 1500},
+	{"gpt-4o-mini", 15, 60},
+	{"gpt-4o", 250, 1000},
+	{"mistral-small", 20, 60},
+	{"mistral-large", 200, 600},
+}
+
+// EstimateModelCost estimates the cost in cents of a completion by model.
+// Unknown models are priced like Claude Sonnet. Unlike EstimateCost it is
+// not rounded, so that the cost of many small calls adds up.
+func EstimateModelCost(model string, inputTokens, outputTokens int) float64 {
+	input, output := 300.0, 1500.0
+	for _, p := range modelPrices {
+		if strings.Contains(model, p.match) {
+			input, output = p.input, p.output
+			break
+		}
+	}
+	return (float64(inputTokens)*input + float64(outputTokens)*output) / 1e6
+}
+
+// RouteStats are the cost and quality of one target on one operation's
+// route over a period
+type RouteStats struct {
+	Operation    Operation `json:"operation"`
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	Requests     int       `json:"requests"`
+	Failures     int       `json:"failures"`
+	Fallbacks    int       `json:"fallbacks"`
+	FailureRate  float64   `json:"failure_rate"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	CostCents    float64   `json:"cost_cents"`
+	// CostPerCallCents is the cost of an answered call
+	CostPerCallCents float64 `json:"cost_per_call_cents"`
+	AvgLatencyMs     int64   `json:"avg_latency_ms"`
+	// Rated answers are those the caller reported on; ValidRate is the
+	// share of them it could use
+	Rated         int      `json:"rated"`
+	ValidRate     *float64 `json:"valid_rate,omitempty"`
+	AvgConfidence *float64 `json:"avg_confidence,omitempty"`
+}
+
+// RouteMetrics records routed completions in hourly buckets
+type RouteMetrics struct {
+	db *pgxpool.Pool
+}
+
+var _ RouteRecorder = (*RouteMetrics)(nil)
+
+// NewRouteMetrics creates a new route metrics recorder
+func NewRouteMetrics(db *pgxpool.Pool) *RouteMetrics {
+	return &RouteMetrics{db: db}
+}
+
+// RecordCall records a completion
+func (m *RouteMetrics) RecordCall(ctx context.Context, call *RouteCall) error {
+	failures, fallbacks := 0, 0
+	if call.Err != nil {
+		failures = 1
+	} else if call.Fallback {
+		fallbacks = 1
+	}
+
+	_, err := m.db.Exec(ctx, `
+		INSERT INTO ai_route_metrics (
+			bucket, operation, provider, model,
+			requests, failures, fallbacks, input_tokens, output_tokens, cost_cents, latency_ms
+		) VALUES (date_trunc('hour', NOW()), $1, $2, $3, 1, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (bucket, operation, provider, model) DO UPDATE SET
+			requests = ai_route_metrics.requests + 1,
+			failures = ai_route_metrics.failures + EXCLUDED.failures,
+			fallbacks = ai_route_metrics.fallbacks + EXCLUDED.fallbacks,
+			input_tokens = ai_route_metrics.input_tokens + EXCLUDED.input_tokens,
+			output_tokens = ai_route_metrics.output_tokens + EXCLUDED.output_tokens,
+			cost_cents = ai_route_metrics.cost_cents + EXCLUDED.cost_cents,
+			latency_ms = ai_route_metrics.latency_ms + EXCLUDED.latency_ms
+	`, string(call.Operation), call.Target.Provider, call.Target.Model,
+		failures, fallbacks, call.InputTokens, call.OutputTokens, call.CostCents, call.Latency.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("record AI call: %w", err)
+	}
+	return nil
+}
+
+// RecordQuality records the caller's verdict on an answer
+func (m *RouteMetrics) RecordQuality(ctx context.Context, op Operation, target Target, valid bool, confidence float64) error {
+	validCount, confidenceCount := 0, 0
+	if valid {
+		validCount = 1
+	}
+	if confidence > 0 {
+		confidenceCount = 1
+	}
+
+	_, err := m.db.Exec(ctx, `
+		INSERT INTO ai_route_metrics (
+			bucket, operation, provider, model, rated, valid, confidence_sum, confidence_count
+		) VALUES (date_trunc('hour', NOW()), $1, $2, $3, 1, $4, $5, $6)
+		ON CONFLICT (bucket, operation, provider, model) DO UPDATE SET
+			rated = ai_route_metrics.rated + 1,
+			valid = ai_route_metrics.valid + EXCLUDED.valid,
+			confidence_sum = ai_route_metrics.confidence_sum + EXCLUDED.confidence_sum,
+			confidence_count = ai_route_metrics.confidence_count + EXCLUDED.confidence_count
+	`, string(op), target.Provider, target.Model, validCount, confidence, confidenceCount)
+	if err != nil {
+		return fmt.Errorf("record AI answer quality: %w", err)
+	}
+	return nil
+}
+
+// Stats returns the cost and quality per operation and target since a
+// time, by operation and then by requests
+func (m *RouteMetrics) Stats(ctx context.Context, since time.Time) ([]*RouteStats, error) {
+	rows, err := m.db.Query(ctx, `
+		SELECT operation, provider, model,
+			SUM(requests), SUM(failures), SUM(fallbacks),
+			SUM(input_tokens)::bigint, SUM(output_tokens)::bigint, SUM(cost_cents)::float8, SUM(latency_ms)::bigint,
+			SUM(rated), SUM(valid), SUM(confidence_sum), SUM(confidence_count)
+		FROM ai_route_metrics
+		WHERE bucket >= date_trunc('hour', $1::timestamptz)
+		GROUP BY operation, provider, model
+		ORDER BY operation, SUM(requests) DESC
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("query AI route metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*RouteStats
+	for rows.Next() {
+		var s RouteStats
+		var op string
+		var latency, valid, confidenceCount int64
+		var confidenceSum float64
+		if err := rows.Scan(&op, &s.Provider, &s.Model,
+			&s.Requests, &s.Failures, &s.Fallbacks,
+			&s.InputTokens, &s.OutputTokens, &s.CostCents, &latency,
+			&s.Rated, &valid, &confidenceSum, &confidenceCount,
+		); err != nil {
+			return nil, fmt.Errorf("scan AI route metrics: %w", err)
+		}
+		s.Operation = Operation(op)
+		if s.Requests > 0 {
+			s.FailureRate = float64(s.Failures) / float64(s.Requests)
+			s.AvgLatencyMs = latency / int64(s.Requests)
+		}
+		if answered := s.Requests - s.Failures; answered > 0 {
+			s.CostPerCallCents = s.CostCents / float64(answered)
+		}
+		if s.Rated > 0 {
+			rate := float64(valid) / float64(s.Rated)
+			s.ValidRate = &rate
+		}
+		if confidenceCount > 0 {
+			avg := confidenceSum / float64(confidenceCount)
+			s.AvgConfidence = &avg
+		}
+		stats = append(stats, &s)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,329 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/pkg/httpclient"
+)
+
+// Providers a routing target can name
+const (
+	ProviderClaude = "claude"
+	ProviderOpenAI = "openai"
+)
+
+// Operation is the kind of work a completion does. Routing policies pick
+// the models per operation, e.g. a cheap one for classification.
+type Operation string
+
+const (
+	OpClassification Operation = "classification"
+	OpExtraction     Operation = "extraction"
+	OpSummary        Operation = "summary"
+	OpTranslation    Operation = "translation"
+	OpSuggestion     Operation = "suggestion"
+	// OpDefault is the route of every operation a policy does not name, and
+	// the operation of completions that do not tell theirs
+	OpDefault Operation = "default"
+)
+
+// Operations are the operations a policy may name
+var Operations = []Operation{OpClassification, OpExtraction, OpSummary, OpTranslation, OpSuggestion, OpDefault}
+
+// ErrNoHealthyTarget is returned when every target of a route is failing or
+// was skipped because its circuit breaker is open
+var ErrNoHealthyTarget = errors.New("no healthy AI provider")
+
+type operationKey struct{}
+
+// WithOperation tells the router which operation the completions made with
+// ctx serve
+func WithOperation(ctx context.Context, op Operation) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+// OperationFrom returns the operation set by WithOperation, or OpDefault
+func OperationFrom(ctx context.Context) Operation {
+	if op, ok := ctx.Value(operationKey{}).(Operation); ok && op != "" {
+		return op
+	}
+	return OpDefault
+}
+
+// Target is a model of a provider
+type Target struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+}
+
+// ParseTarget parses "provider:model"
+func ParseTarget(s string) (Target, error) {
+	provider, model, ok := strings.Cut(strings.TrimSpace(s), ":")
+	provider, model = strings.TrimSpace(provider), strings.TrimSpace(model)
+	if !ok || provider == "" || model == "" {
+		return Target{}, fmt.Errorf("invalid AI target %q, want provider:model", s)
+	}
+	return Target{Provider: provider, Model: model}, nil
+}
+
+// String returns the target as "provider:model"
+func (t Target) String() string {
+	return t.Provider + ":" + t.Model
+}
+
+// Policy is the route of each operation: the targets to try, in order
+type Policy map[Operation][]Target
+
+// ParsePolicy parses routes in the form
+//
+//	classification=claude:claude-3-5-haiku-latest,claude:claude-sonnet-4-20250514;default=claude:claude-sonnet-4-20250514,openai:gpt-4o
+//
+// The default route is required.
+func ParsePolicy(spec string) (Policy, error) {
+	p := make(Policy)
+	for _, route := range strings.Split(spec, ";") {
+		if strings.TrimSpace(route) == "" {
+			continue
+		}
+		name, targets, ok := strings.Cut(route, "=")
+		op := Operation(strings.TrimSpace(name))
+		if !ok || !isOperation(op) {
+			return nil, fmt.Errorf("invalid AI route %q", route)
+		}
+		if _, dup := p[op]; dup {
+			return nil, fmt.Errorf("AI route %s given twice", op)
+		}
+		for _, s := range strings.Split(targets, ",") {
+			t, err := ParseTarget(s)
+			if err != nil {
+				return nil, err
+			}
+			p[op] = append(p[op], t)
+		}
+	}
+	if len(p[OpDefault]) == 0 {
+		return nil, fmt.Errorf("AI routes need a default route")
+	}
+	return p, nil
+}
+
+// DefaultPolicy routes classification to the cheap model and everything
+// else to the strong one, each falling back to the strong model and then
+// to the fallbacks
+func DefaultPolicy(strong, cheap Target, fallbacks ...Target) Policy {
+	route := func(targets ...Target) []Target {
+		var r []Target
+		for _, t := range append(targets, fallbacks...) {
+			if t.Model != "" && !containsTarget(r, t) {
+				r = append(r, t)
+			}
+		}
+		return r
+	}
+	return Policy{
+		OpClassification: route(cheap, strong),
+		OpDefault:        route(strong),
+	}
+}
+
+// Route returns the targets of op
+func (p Policy) Route(op Operation) []Target {
+	if targets, ok := p[op]; ok {
+		return targets
+	}
+	return p[OpDefault]
+}
+
+// Targets returns every target of the policy once
+func (p Policy) Targets() []Target {
+	var targets []Target
+	for _, op := range Operations {
+		for _, t := range p[op] {
+			if !containsTarget(targets, t) {
+				targets = append(targets, t)
+			}
+		}
+	}
+	return targets
+}
+
+func isOperation(op Operation) bool {
+	for _, o := range Operations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func containsTarget(targets []Target, t Target) bool {
+	for _, have := range targets {
+		if have == t {
+			return true
+		}
+	}
+	return false
+}
+
+// RouteCall is a completion one target made for a route
+type RouteCall struct {
+	Operation Operation
+	Target    Target
+	// Fallback is set when the target is not the first of the route
+	Fallback     bool
+	Err          error
+	Latency      time.Duration
+	InputTokens  int
+	OutputTokens int
+	CostCents    float64
+}
+
+// RouteRecorder records the cost and quality of routed completions, for
+// tuning the policy. RouteMetrics records them in the database.
+type RouteRecorder interface {
+	RecordCall(ctx context.Context, call *RouteCall) error
+	// RecordQuality records whether the caller could use an answer and the
+	// confidence it claimed, 0 if none
+	RecordQuality(ctx context.Context, op Operation, target Target, valid bool, confidence float64) error
+}
+
+// TargetHealth is the circuit breaker state of a target
+type TargetHealth struct {
+	Target
+	State httpclient.State `json:"state"`
+}
+
+// Router is a Completer that sends each completion to the targets of its
+// operation's route in turn, until one answers. A target that keeps failing
+// is skipped while its circuit breaker is open, so that an outage of the
+// primary provider costs one failed call per cooldown instead of one per
+// completion.
+type Router struct {
+	policy   Policy
+	clients  map[Target]Completer
+	health   *httpclient.Breakers
+	recorder RouteRecorder
+	logger   *slog.Logger
+}
+
+var _ Completer = (*Router)(nil)
+
+// NewRouter creates a router. clients holds the client of every target of
+// the policy; health configures when a target counts as down and for how
+// long.
+func NewRouter(policy Policy, clients map[Target]Completer, health httpclient.BreakerConfig) (*Router, error) {
+	if len(policy.Route(OpDefault)) == 0 {
+		return nil, fmt.Errorf("AI routes need a default route")
+	}
+	for _, t := range policy.Targets() {
+		if clients[t] == nil {
+			return nil, fmt.Errorf("no client for AI target %s", t)
+		}
+	}
+	return &Router{
+		policy:  policy,
+		clients: clients,
+		health:  httpclient.NewBreakers(health),
+		logger:  slog.Default(),
+	}, nil
+}
+
+// SetRecorder sets where the cost and quality of completions are recorded
+func (r *Router) SetRecorder(recorder RouteRecorder) {
+	r.recorder = recorder
+}
+
+// SetLogger sets the logger of failovers
+func (r *Router) SetLogger(logger *slog.Logger) {
+	r.logger = logger
+}
+
+// Complete sends a completion along the route of the operation of ctx
+func (r *Router) Complete(ctx context.Context, systemPrompt, userPrompt string, temperature float64) (*Response, error) {
+	return r.CompleteWithRetry(ctx, systemPrompt, userPrompt, temperature, 3)
+}
+
+// CompleteWithRetry sends a completion along the route of the operation of
+// ctx. maxRetries is the number of attempts per target.
+func (r *Router) CompleteWithRetry(ctx context.Context, systemPrompt, userPrompt string, temperature float64, maxRetries int) (*Response, error) {
+	op := OperationFrom(ctx)
+	var lastErr error
+	for i, t := range r.policy.Route(op) {
+		if !r.health.Allow(t.String()) {
+			continue
+		}
+
+		start := time.Now()
+		resp, err := r.clients[t].CompleteWithRetry(ctx, systemPrompt, userPrompt, temperature, maxRetries)
+		call := &RouteCall{Operation: op, Target: t, Fallback: i > 0, Err: err, Latency: time.Since(start)}
+
+		// A caller giving up says nothing about the target
+		if err != nil && ctx.Err() != nil {
+			r.health.Record(t.String(), err, func(error) bool { return false })
+			return nil, err
+		}
+		r.health.Record(t.String(), err, failsOver)
+
+		if err == nil {
+			call.InputTokens, call.OutputTokens = resp.Usage.InputTokens, resp.Usage.OutputTokens
+			call.CostCents = EstimateModelCost(t.Model, call.InputTokens, call.OutputTokens)
+			r.record(ctx, call)
+			if r.recorder != nil {
+				resp.quality = func(valid bool, confidence float64) {
+					if err := r.recorder.RecordQuality(context.WithoutCancel(ctx), op, t, valid, confidence); err != nil {
+						r.logger.Error("failed to record AI answer quality", "operation", op, "target", t.String(), "error", err)
+					}
+				}
+			}
+			return resp, nil
+		}
+
+		r.record(ctx, call)
+		if !failsOver(err) {
+			return nil, err
+		}
+		r.logger.Warn("AI target failed, trying next", "operation", op, "target", t.String(), "error", err)
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("%w for %s", ErrNoHealthyTarget, op)
+	}
+	return nil, fmt.Errorf("%w for %s: %w", ErrNoHealthyTarget, op, lastErr)
+}
+
+func (r *Router) record(ctx context.Context, call *RouteCall) {
+	if r.recorder == nil {
+		return
+	}
+	if err := r.recorder.RecordCall(context.WithoutCancel(ctx), call); err != nil {
+		r.logger.Error("failed to record AI call", "operation", call.Operation, "target", call.Target.String(), "error", err)
+	}
+}
+
+// Health returns the state of every target of the policy
+func (r *Router) Health() []TargetHealth {
+	targets := r.policy.Targets()
+	health := make([]TargetHealth, 0, len(targets))
+	for _, t := range targets {
+		health = append(health, TargetHealth{Target: t, State: r.health.State(t.String())})
+	}
+	return health
+}
+
+// failsOver reports whether another target may succeed where one failed.
+// A request the API rejects as malformed fails the same everywhere; any
+// other error, including authentication and unknown models, is the
+// target's.
+func failsOver(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode != 400
+	}
+	return true
+}
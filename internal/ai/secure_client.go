@@ -7,6 +7,8 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/pkg/money"
 )
 
 // SecureAnalysisRequest matches security.AnalysisRequest for document analysis
@@ -21,12 +23,12 @@ type SecureAnalysisRequest struct {
 
 // SecureAnalysisResponse matches security.AnalysisResponse
 type SecureAnalysisResponse struct {
-	Summary      string   `json:"summary"`
-	DocumentType string   `json:"document_type"`
-	Deadline     string   `json:"deadline,omitempty"`
-	Amount       *float64 `json:"amount,omitempty"`
-	ActionItems  []string `json:"action_items,omitempty"`
-	Confidence   float64  `json:"confidence"`
+	Summary      string       `json:"summary"`
+	DocumentType string       `json:"document_type"`
+	Deadline     string       `json:"deadline,omitempty"`
+	Amount       *money.Money `json:"amount,omitempty"`
+	ActionItems  []string     `json:"action_items,omitempty"`
+	Confidence   float64      `json:"confidence"`
 }
 
 // SecureTextAnalysisRequest matches security.TextAnalysisRequest
@@ -7,6 +7,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/pkg/money"
 )
 
 // UsageLog represents an AI usage log entry
@@ -269,21 +271,20 @@ func (l *UsageLogger) GetTenantCostSummary(ctx context.Context, tenantID uuid.UU
 		return nil, fmt.Errorf("get cost summary: %w", err)
 	}
 
-	// Convert cents to euros
-	summary.TotalCostEuros = float64(summary.TotalCostCents) / 100.0
+	summary.TotalCostEuros = money.EUR(int64(summary.TotalCostCents))
 
 	return summary, nil
 }
 
 // CostSummary represents a billing cost summary
 type CostSummary struct {
-	TenantID        uuid.UUID `json:"tenant_id"`
-	StartDate       time.Time `json:"start_date"`
-	EndDate         time.Time `json:"end_date"`
-	TotalRequests   int       `json:"total_requests"`
-	TotalTokens     int       `json:"total_tokens"`
-	TotalCostCents  int       `json:"total_cost_cents"`
-	TotalCostEuros  float64   `json:"total_cost_euros"`
+	TenantID       uuid.UUID   `json:"tenant_id"`
+	StartDate      time.Time   `json:"start_date"`
+	EndDate        time.Time   `json:"end_date"`
+	TotalRequests  int         `json:"total_requests"`
+	TotalTokens    int         `json:"total_tokens"`
+	TotalCostCents int         `json:"total_cost_cents"`
+	TotalCostEuros money.Money `json:"total_cost_euros"`
 }
 
 // CleanupOldLogs removes logs older than the specified retention period
@@ -21,6 +21,7 @@ const (
 	DocTypeAntrag         DocumentType = "antrag"         // Application
 	DocTypeVorhalt        DocumentType = "vorhalt"        // Preliminary assessment
 	DocTypeZahlungsbefehl DocumentType = "zahlungsbefehl" // Payment order
+	DocTypeVertrag        DocumentType = "vertrag"        // Contract
 	DocTypeSonstige       DocumentType = "sonstige"       // Other
 )
 
@@ -55,20 +56,21 @@ type ClassificationResult struct {
 
 // Classifier handles document classification using AI
 type Classifier struct {
-	aiClient     *ai.Client
+	aiClient     ai.Completer
 	promptLoader *ai.PromptLoader
 }
 
 // NewClassifier creates a new document classifier
-func NewClassifier(aiClient *ai.Client, promptLoader *ai.PromptLoader) *Classifier {
+func NewClassifier(aiClient ai.Completer, promptLoader *ai.PromptLoader) *Classifier {
 	return &Classifier{
 		aiClient:     aiClient,
 		promptLoader: promptLoader,
 	}
 }
 
-// Classify analyzes document text and returns classification
-func (c *Classifier) Classify(ctx context.Context, text string) (*ClassificationResult, error) {
+// Classify analyzes document text and returns classification. Tenant document
+// types in custom extend the built-in taxonomy.
+func (c *Classifier) Classify(ctx context.Context, text string, custom []TaxonomyType) (*ClassificationResult, error) {
 	// Load classification prompt
 	prompt, err := c.promptLoader.Get(ctx, ai.PromptClassification)
 	var systemPrompt, userTemplate string
@@ -80,6 +82,7 @@ func (c *Classifier) Classify(ctx context.Context, text string) (*Classification
 		systemPrompt = prompt.SystemPrompt
 		userTemplate = prompt.UserPromptTemplate
 	}
+	systemPrompt = BuildTaxonomyPrompt(systemPrompt, custom)
 
 	// Truncate text if too long (keep first ~4000 chars for classification)
 	truncatedText := text
@@ -91,16 +94,18 @@ func (c *Classifier) Classify(ctx context.Context, text string) (*Classification
 	userPrompt := strings.ReplaceAll(userTemplate, "{document_text}", truncatedText)
 
 	// Call Claude API
-	response, err := c.aiClient.CompleteWithRetry(ctx, systemPrompt, userPrompt, 0.1, 2)
+	response, err := c.aiClient.CompleteWithRetry(ai.WithOperation(ctx, ai.OpClassification), systemPrompt, userPrompt, 0.1, 2)
 	if err != nil {
 		return nil, fmt.Errorf("AI classification failed: %w", err)
 	}
 
 	// Parse response
-	parsed, err := ai.ParseClassification(response.GetText())
+	parsed, err := ai.ParseClassificationWithTypes(response.GetText(), taxonomyCodes(custom))
 	if err != nil {
+		response.ReportQuality(false, 0)
 		return nil, fmt.Errorf("parse classification response: %w", err)
 	}
+	response.ReportQuality(true, parsed.Confidence)
 
 	// Map to result - map Priority to Urgency
 	urgency := "normal"
@@ -130,7 +135,9 @@ func (c *Classifier) Classify(ctx context.Context, text string) (*Classification
 	}
 
 	// Validate document type
-	if !isValidDocumentType(result.DocumentType) {
+	if t := findTaxonomyType(custom, parsed.DocumentType); t != nil {
+		result.RequiresAction = result.RequiresAction || t.RequiresAction
+	} else if !isValidDocumentType(result.DocumentType) {
 		result.DocumentType = DocTypeSonstige
 	}
 
@@ -138,13 +145,23 @@ func (c *Classifier) Classify(ctx context.Context, text string) (*Classification
 }
 
 // ClassifyWithFallback attempts classification with fallback to heuristics
-func (c *Classifier) ClassifyWithFallback(ctx context.Context, text string, title string) (*ClassificationResult, error) {
+func (c *Classifier) ClassifyWithFallback(ctx context.Context, text string, title string, custom []TaxonomyType) (*ClassificationResult, error) {
 	// Try AI classification first
-	result, err := c.Classify(ctx, text)
+	result, err := c.Classify(ctx, text, custom)
 	if err == nil && result.Confidence > 0.5 {
 		return result, nil
 	}
 
+	// Tenant document types are more specific than the built-in keywords
+	if t := MatchCustomType(text, title, custom); t != nil {
+		result := c.classifyHeuristic(text, title)
+		result.DocumentType = DocumentType(t.Code)
+		result.Reasoning = "Classified using tenant document type keywords"
+		result.Keywords = append(result.Keywords, t.Code)
+		result.RequiresAction = result.RequiresAction || t.RequiresAction
+		return result, nil
+	}
+
 	// Fall back to heuristic classification
 	return c.classifyHeuristic(text, title), nil
 }
@@ -183,6 +200,9 @@ func (c *Classifier) classifyHeuristic(text, title string) *ClassificationResult
 		result.DocumentType = DocTypeVorhalt
 		result.RequiresAction = true
 		result.Keywords = append(result.Keywords, "vorhalt")
+	} else if containsAny(combined, []string{"kündigungsfrist", "vertragslaufzeit", "mindestlaufzeit", "mietvertrag", "wartungsvertrag", "dienstleistungsvertrag", "rahmenvertrag"}) {
+		result.DocumentType = DocTypeVertrag
+		result.Keywords = append(result.Keywords, "vertrag")
 	} else if containsAny(combined, []string{"rechnung", "faktura", "invoice"}) {
 		result.DocumentType = DocTypeRechnung
 		result.Keywords = append(result.Keywords, "rechnung")
@@ -219,7 +239,7 @@ func isValidDocumentType(dt DocumentType) bool {
 	switch dt {
 	case DocTypeBescheid, DocTypeErsuchen, DocTypeMitteilung, DocTypeMahnung,
 		DocTypeRechnung, DocTypeBestätigung, DocTypeAntrag, DocTypeVorhalt,
-		DocTypeZahlungsbefehl, DocTypeSonstige:
+		DocTypeZahlungsbefehl, DocTypeVertrag, DocTypeSonstige:
 		return true
 	default:
 		return false
@@ -242,7 +262,7 @@ Analysiere das folgende Dokument und klassifiziere es.
 
 Gib die Antwort als JSON in diesem Format:
 {
-  "document_type": "bescheid|ersuchen|mitteilung|mahnung|rechnung|bestätigung|antrag|vorhalt|zahlungsbefehl|sonstige",
+  "document_type": "bescheid|ersuchen|mitteilung|mahnung|rechnung|bestätigung|antrag|vorhalt|zahlungsbefehl|vertrag|sonstige",
   "document_subtype": "einkommensteuer|umsatzsteuer|körperschaftsteuer|lohnsteuer|sozialversicherung|gewerbe|zoll|finanzamt|gkk|wko|sonstige",
   "confidence": 0.0-1.0,
   "reasoning": "Kurze Erklärung der Klassifizierung",
@@ -259,6 +279,7 @@ Wichtige Dokumenttypen:
 - mahnung: Zahlungserinnerung, Säumniszuschlag
 - vorhalt: Vorhaltsbeantwortung, Prüfungsfeststellung
 - zahlungsbefehl: Gerichtlicher Zahlungsbefehl, Exekution
+- vertrag: Vertrag mit Laufzeit oder Kündigungsfrist (z.B. Miet-, Wartungs-, Dienstleistungsvertrag)
 
 Urgency Kriterien:
 - critical: Zahlungsbefehl, Exekutionsandrohung, sehr kurze Frist (<3 Tage)
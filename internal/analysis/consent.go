@@ -0,0 +1,401 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrConsentDenied   = errors.New("AI processing is not covered by the tenant's consent")
+	ErrInvalidConsent  = errors.New("invalid consent")
+	ErrConsentConflict = errors.New("consent was changed concurrently, reload and retry")
+)
+
+// Processing purposes a tenant consents to per document type. Text
+// extraction, reference links and filing rules do not send the document to
+// the AI provider and need no consent.
+const (
+	PurposeClassification = "classification"
+	PurposeSummary        = "summary"
+	PurposeExtraction     = "extraction" // deadlines, amounts and action items
+	PurposeSuggestions    = "suggestions"
+)
+
+// ConsentPurposes lists the purposes in processing order
+var ConsentPurposes = []string{PurposeClassification, PurposeSummary, PurposeExtraction, PurposeSuggestions}
+
+// ConsentAnyDocumentType is the rule for the document types without a rule
+// of their own
+const ConsentAnyDocumentType = "*"
+
+// Analysis status and error code of an analysis the consent does not cover
+const (
+	StatusSkipped          = "skipped"
+	ErrorCodeConsentDenied = "consent_denied"
+)
+
+const (
+	maxConsentRules        = 100
+	maxConsentDocumentType = 100
+)
+
+// ConsentRule names the purposes AI processing is allowed for on documents
+// of a type. No purposes means no AI processing.
+type ConsentRule struct {
+	DocumentType string   `json:"document_type"`
+	Purposes     []string `json:"purposes"`
+}
+
+// ConsentPolicy is a version of a tenant's consent to AI processing. Version
+// 0 means the tenant never configured a consent: all purposes are allowed
+// for all document types. Once a version exists, document types without a
+// rule and without a "*" rule are not processed.
+type ConsentPolicy struct {
+	TenantID  uuid.UUID     `json:"tenant_id"`
+	Version   int           `json:"version"`
+	Rules     []ConsentRule `json:"rules"`
+	Note      string        `json:"note,omitempty"`
+	CreatedBy *uuid.UUID    `json:"created_by,omitempty"`
+	CreatedAt *time.Time    `json:"created_at,omitempty"`
+}
+
+// ConsentRequest replaces the consent of a tenant with a new version
+type ConsentRequest struct {
+	Rules []ConsentRule `json:"rules"`
+	Note  string        `json:"note,omitempty"`
+}
+
+// ConsentTypeStatus is the consent of a document type with the analyses of
+// the tenant's documents of that type
+type ConsentTypeStatus struct {
+	DocumentType   string     `json:"document_type"`
+	Purposes       []string   `json:"purposes"` // allowed by the current version
+	Documents      int        `json:"documents"`
+	Analyzed       int        `json:"analyzed"` // documents with a completed analysis
+	Skipped        int        `json:"skipped"`  // documents with an analysis skipped for lack of consent
+	LastAnalyzedAt *time.Time `json:"last_analyzed_at,omitempty"`
+}
+
+// ConsentOverview is the compliance view of a tenant's consent: the version
+// in force, all versions and what it means for each document type
+type ConsentOverview struct {
+	Current       *ConsentPolicy      `json:"current"`
+	Versions      []*ConsentPolicy    `json:"versions"`
+	DocumentTypes []ConsentTypeStatus `json:"document_types"`
+}
+
+// ValidateConsentRules checks the rules of a consent and normalizes
+// document types and purposes
+func ValidateConsentRules(rules []ConsentRule) error {
+	if len(rules) > maxConsentRules {
+		return fmt.Errorf("%w: at most %d rules", ErrInvalidConsent, maxConsentRules)
+	}
+	seen := make(map[string]bool, len(rules))
+	for i := range rules {
+		rule := &rules[i]
+		rule.DocumentType = strings.ToLower(strings.TrimSpace(rule.DocumentType))
+		if rule.DocumentType == "" || len(rule.DocumentType) > maxConsentDocumentType {
+			return fmt.Errorf("%w: document_type is required and at most %d characters", ErrInvalidConsent, maxConsentDocumentType)
+		}
+		if seen[rule.DocumentType] {
+			return fmt.Errorf("%w: document type %s has two rules", ErrInvalidConsent, rule.DocumentType)
+		}
+		seen[rule.DocumentType] = true
+
+		purposes := make([]string, 0, len(rule.Purposes))
+		for _, purpose := range rule.Purposes {
+			purpose = strings.ToLower(strings.TrimSpace(purpose))
+			if !slices.Contains(ConsentPurposes, purpose) {
+				return fmt.Errorf("%w: unknown purpose %q", ErrInvalidConsent, purpose)
+			}
+			if !slices.Contains(purposes, purpose) {
+				purposes = append(purposes, purpose)
+			}
+		}
+		rule.Purposes = purposes
+	}
+	return nil
+}
+
+// Allowed returns the purposes AI processing is allowed for on documents of
+// a type, in processing order
+func (p *ConsentPolicy) Allowed(documentType string) []string {
+	if p.Version == 0 {
+		return ConsentPurposes
+	}
+	var match, fallback *ConsentRule
+	for i := range p.Rules {
+		switch p.Rules[i].DocumentType {
+		case strings.ToLower(strings.TrimSpace(documentType)):
+			match = &p.Rules[i]
+		case ConsentAnyDocumentType:
+			fallback = &p.Rules[i]
+		}
+	}
+	if match == nil {
+		match = fallback
+	}
+	if match == nil {
+		return []string{}
+	}
+	allowed := []string{}
+	for _, purpose := range ConsentPurposes {
+		if slices.Contains(match.Purposes, purpose) {
+			allowed = append(allowed, purpose)
+		}
+	}
+	return allowed
+}
+
+// Allows reports whether AI processing for a purpose is allowed on documents
+// of a type
+func (p *ConsentPolicy) Allows(documentType, purpose string) bool {
+	return slices.Contains(p.Allowed(documentType), purpose)
+}
+
+// Restrict turns off the analysis steps of purposes the consent does not
+// allow for a document type and returns those purposes
+func (p *ConsentPolicy) Restrict(opts *AnalysisOptions, documentType string) []string {
+	steps := map[string][]*bool{
+		PurposeClassification: {&opts.IncludeClassify},
+		PurposeSummary:        {&opts.IncludeSummary},
+		PurposeExtraction:     {&opts.IncludeDeadlines, &opts.IncludeAmounts, &opts.IncludeActionItems},
+		PurposeSuggestions:    {&opts.IncludeSuggestions},
+	}
+	allowed := p.Allowed(documentType)
+	denied := []string{}
+	for _, purpose := range ConsentPurposes {
+		if slices.Contains(allowed, purpose) {
+			continue
+		}
+		denied = append(denied, purpose)
+		for _, step := range steps[purpose] {
+			*step = false
+		}
+	}
+	return denied
+}
+
+// ============== Repository ==============
+
+const consentColumns = `tenant_id, version, rules, COALESCE(note, ''), created_by, created_at`
+
+func scanConsentPolicy(row pgx.Row) (*ConsentPolicy, error) {
+	p := &ConsentPolicy{}
+	var rules []byte
+	var createdAt time.Time
+	if err := row.Scan(&p.TenantID, &p.Version, &rules, &p.Note, &p.CreatedBy, &createdAt); err != nil {
+		return nil, err
+	}
+	p.CreatedAt = &createdAt
+	if err := json.Unmarshal(rules, &p.Rules); err != nil {
+		return nil, fmt.Errorf("decode consent rules: %w", err)
+	}
+	return p, nil
+}
+
+// LatestConsent returns the consent version in force for a tenant, or nil
+// if the tenant never configured one
+func (r *Repository) LatestConsent(ctx context.Context, tenantID uuid.UUID) (*ConsentPolicy, error) {
+	query := `SELECT ` + consentColumns + ` FROM ai_consent_versions
+		WHERE tenant_id = $1 ORDER BY version DESC LIMIT 1`
+
+	p, err := scanConsentPolicy(r.db.QueryRow(ctx, query, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get consent: %w", err)
+	}
+	return p, nil
+}
+
+// ListConsentVersions lists all consent versions of a tenant, latest first
+func (r *Repository) ListConsentVersions(ctx context.Context, tenantID uuid.UUID) ([]*ConsentPolicy, error) {
+	query := `SELECT ` + consentColumns + ` FROM ai_consent_versions
+		WHERE tenant_id = $1 ORDER BY version DESC`
+
+	rows, err := r.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list consent versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []*ConsentPolicy{}
+	for rows.Next() {
+		p, err := scanConsentPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan consent version: %w", err)
+		}
+		versions = append(versions, p)
+	}
+	return versions, rows.Err()
+}
+
+// CreateConsentVersion stores a consent as the next version of the tenant
+func (r *Repository) CreateConsentVersion(ctx context.Context, p *ConsentPolicy) error {
+	rules, _ := json.Marshal(p.Rules)
+
+	var createdAt time.Time
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO ai_consent_versions (tenant_id, version, rules, note, created_by)
+		VALUES ($1, (SELECT COALESCE(MAX(version), 0) + 1 FROM ai_consent_versions WHERE tenant_id = $1), $2, NULLIF($3, ''), $4)
+		RETURNING version, created_at
+	`, p.TenantID, rules, p.Note, p.CreatedBy).Scan(&p.Version, &createdAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "23505") || strings.Contains(err.Error(), "duplicate key") {
+			return ErrConsentConflict
+		}
+		return fmt.Errorf("create consent version: %w", err)
+	}
+	p.CreatedAt = &createdAt
+	return nil
+}
+
+// consentTypeStats counts the documents of a tenant per type and how many
+// of them were analyzed or skipped for lack of consent
+func (r *Repository) consentTypeStats(ctx context.Context, tenantID uuid.UUID) ([]ConsentTypeStatus, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT LOWER(d.type), COUNT(DISTINCT d.id),
+			COUNT(DISTINCT a.document_id) FILTER (WHERE a.status = 'completed'),
+			COUNT(DISTINCT a.document_id) FILTER (WHERE a.status = 'skipped'),
+			MAX(a.completed_at) FILTER (WHERE a.status = 'completed')
+		FROM documents d
+		LEFT JOIN document_analyses a ON a.document_id = d.id AND a.tenant_id = d.tenant_id
+		WHERE d.tenant_id = $1
+		GROUP BY LOWER(d.type)
+		ORDER BY LOWER(d.type)
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("count documents by type: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []ConsentTypeStatus
+	for rows.Next() {
+		var s ConsentTypeStatus
+		if err := rows.Scan(&s.DocumentType, &s.Documents, &s.Analyzed, &s.Skipped, &s.LastAnalyzedAt); err != nil {
+			return nil, fmt.Errorf("scan document type: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// ============== Service ==============
+
+// ConsentPolicy returns the consent in force for a tenant; version 0 if the
+// tenant never configured one
+func (s *Service) ConsentPolicy(ctx context.Context, tenantID uuid.UUID) (*ConsentPolicy, error) {
+	p, err := s.repo.LatestConsent(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return &ConsentPolicy{TenantID: tenantID, Rules: []ConsentRule{}}, nil
+	}
+	return p, nil
+}
+
+// UpdateConsent records a new consent version, which applies to all
+// analyses started from now on. Earlier versions are kept for audits.
+func (s *Service) UpdateConsent(ctx context.Context, tenantID uuid.UUID, userID *uuid.UUID, req *ConsentRequest) (*ConsentPolicy, error) {
+	if req.Rules == nil {
+		req.Rules = []ConsentRule{}
+	}
+	if err := ValidateConsentRules(req.Rules); err != nil {
+		return nil, err
+	}
+	p := &ConsentPolicy{
+		TenantID:  tenantID,
+		Rules:     req.Rules,
+		Note:      strings.TrimSpace(req.Note),
+		CreatedBy: userID,
+	}
+	if err := s.repo.CreateConsentVersion(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListConsentVersions lists all consent versions of a tenant, latest first
+func (s *Service) ListConsentVersions(ctx context.Context, tenantID uuid.UUID) ([]*ConsentPolicy, error) {
+	return s.repo.ListConsentVersions(ctx, tenantID)
+}
+
+// ConsentOverview returns the consent of a tenant for compliance audits: the
+// version in force, all versions and, per document type of the tenant's
+// documents or rules, the allowed purposes and the analyses done or skipped
+func (s *Service) ConsentOverview(ctx context.Context, tenantID uuid.UUID) (*ConsentOverview, error) {
+	current, err := s.ConsentPolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.repo.ListConsentVersions(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.consentTypeStats(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string]*ConsentTypeStatus, len(stats))
+	for i := range stats {
+		byType[stats[i].DocumentType] = &stats[i]
+	}
+	for _, rule := range current.Rules {
+		if rule.DocumentType != ConsentAnyDocumentType && byType[rule.DocumentType] == nil {
+			stats = append(stats, ConsentTypeStatus{DocumentType: rule.DocumentType})
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].DocumentType < stats[j].DocumentType })
+	for i := range stats {
+		stats[i].Purposes = current.Allowed(stats[i].DocumentType)
+	}
+
+	return &ConsentOverview{Current: current, Versions: versions, DocumentTypes: stats}, nil
+}
+
+// checkConsent applies the tenant's consent to an analysis of a document:
+// steps of purposes that are not allowed are turned off and the consent
+// version and the denied purposes are recorded in the analysis metadata. If
+// no purpose is allowed the analysis is marked skipped and ErrConsentDenied
+// is returned.
+func (s *Service) checkConsent(ctx context.Context, analysis *Analysis, documentType string, opts *AnalysisOptions) error {
+	policy, err := s.ConsentPolicy(ctx, analysis.TenantID)
+	if err != nil {
+		return err
+	}
+
+	denied := policy.Restrict(opts, documentType)
+	if analysis.Metadata == nil {
+		analysis.Metadata = make(map[string]interface{})
+	}
+	analysis.Metadata["consent_version"] = policy.Version
+	if len(denied) > 0 {
+		analysis.Metadata["consent_denied_purposes"] = denied
+	}
+	if len(denied) < len(ConsentPurposes) {
+		return nil
+	}
+
+	analysis.Status = StatusSkipped
+	analysis.ErrorCode = ErrorCodeConsentDenied
+	analysis.ErrorMessage = fmt.Sprintf("consent version %d does not allow AI processing of document type %q", policy.Version, documentType)
+	now := time.Now()
+	analysis.CompletedAt = &now
+	if err := s.repo.UpdateAnalysis(ctx, analysis); err != nil {
+		return fmt.Errorf("update analysis: %w", err)
+	}
+	return fmt.Errorf("%w: %s", ErrConsentDenied, analysis.ErrorMessage)
+}
@@ -0,0 +1,367 @@
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"austrian-business-infrastructure/pkg/money"
+)
+
+var ErrNothingToUndo = errors.New("no correction to undo")
+
+// Entities whose corrections are recorded
+const (
+	EntityDeadline = "deadline"
+	EntityAmount   = "amount"
+)
+
+// FieldChange is the old and new value of a corrected field, encoded as in
+// the entity's JSON
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// Correction is a user correction of an extracted deadline or amount. An
+// undone correction stays in the history with UndoneAt set.
+type Correction struct {
+	ID         uuid.UUID     `json:"id"`
+	EntityType string        `json:"entity_type"`
+	EntityID   uuid.UUID     `json:"entity_id"`
+	Changes    []FieldChange `json:"changes"`
+	ChangedBy  *uuid.UUID    `json:"changed_by,omitempty"`
+	ChangedAt  time.Time     `json:"changed_at"`
+	UndoneBy   *uuid.UUID    `json:"undone_by,omitempty"`
+	UndoneAt   *time.Time    `json:"undone_at,omitempty"`
+}
+
+// DiffFields compares two values of the same struct type field by field and
+// returns the changed fields by JSON name, in name order
+func DiffFields(before, after any) ([]FieldChange, error) {
+	old, err := fieldValues(before)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := fieldValues(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(updated))
+	for name := range updated {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var changes []FieldChange
+	for _, name := range names {
+		if !bytes.Equal(old[name], updated[name]) {
+			changes = append(changes, FieldChange{Field: name, Old: old[name], New: updated[name]})
+		}
+	}
+	return changes, nil
+}
+
+// RevertFields sets the changed fields of the struct fields points to back
+// to their old values. Other fields are kept.
+func RevertFields(fields any, changes []FieldChange) error {
+	old := make(map[string]json.RawMessage, len(changes))
+	for _, c := range changes {
+		old[c.Field] = c.Old
+	}
+	data, err := json.Marshal(old)
+	if err != nil {
+		return fmt.Errorf("encode old values: %w", err)
+	}
+	if err := json.Unmarshal(data, fields); err != nil {
+		return fmt.Errorf("restore old values: %w", err)
+	}
+	return nil
+}
+
+// fieldValues encodes the fields of a struct by JSON name
+func fieldValues(v any) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode fields: %w", err)
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("decode fields: %w", err)
+	}
+	return values, nil
+}
+
+// deadlineFields are the fields of a deadline a user can correct
+type deadlineFields struct {
+	Date            time.Time  `json:"date"`
+	Description     string     `json:"description"`
+	IsAcknowledged  bool       `json:"is_acknowledged"`
+	AcknowledgedAt  *time.Time `json:"acknowledged_at"`
+	ManuallySet     bool       `json:"manually_set"`
+	CorrectedByUser bool       `json:"corrected_by_user"`
+	Notes           string     `json:"notes"`
+}
+
+func (d *Deadline) fields() deadlineFields {
+	return deadlineFields{
+		Date:            d.Date,
+		Description:     d.Description,
+		IsAcknowledged:  d.IsAcknowledged,
+		AcknowledgedAt:  d.AcknowledgedAt,
+		ManuallySet:     d.ManuallySet,
+		CorrectedByUser: d.CorrectedByUser,
+		Notes:           d.Notes,
+	}
+}
+
+func (d *Deadline) setFields(f deadlineFields) {
+	d.Date = f.Date
+	d.Description = f.Description
+	d.IsAcknowledged = f.IsAcknowledged
+	d.AcknowledgedAt = f.AcknowledgedAt
+	d.ManuallySet = f.ManuallySet
+	d.CorrectedByUser = f.CorrectedByUser
+	d.Notes = f.Notes
+}
+
+// amountFields are the fields of an amount a user can correct
+type amountFields struct {
+	AmountType      string      `json:"amount_type"`
+	Amount          money.Money `json:"amount"`
+	Currency        string      `json:"currency"`
+	Description     string      `json:"description"`
+	DueDate         *time.Time  `json:"due_date"`
+	CorrectedByUser bool        `json:"corrected_by_user"`
+	Notes           string      `json:"notes"`
+}
+
+func (a *Amount) fields() amountFields {
+	return amountFields{
+		AmountType:      a.AmountType,
+		Amount:          a.Amount,
+		Currency:        a.Currency,
+		Description:     a.Description,
+		DueDate:         a.DueDate,
+		CorrectedByUser: a.CorrectedByUser,
+		Notes:           a.Notes,
+	}
+}
+
+func (a *Amount) setFields(f amountFields) {
+	a.AmountType = f.AmountType
+	a.Amount = f.Amount
+	a.Currency = f.Currency
+	a.Amount.Currency = f.Currency
+	a.Description = f.Description
+	a.DueDate = f.DueDate
+	a.CorrectedByUser = f.CorrectedByUser
+	a.Notes = f.Notes
+}
+
+const correctionColumns = `id, entity_type, entity_id, changes, changed_by, changed_at, undone_by, undone_at`
+
+func scanCorrection(row pgx.Row) (*Correction, error) {
+	c := &Correction{}
+	var changes []byte
+	if err := row.Scan(&c.ID, &c.EntityType, &c.EntityID, &changes, &c.ChangedBy, &c.ChangedAt, &c.UndoneBy, &c.UndoneAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(changes, &c.Changes); err != nil {
+		return nil, fmt.Errorf("decode changes: %w", err)
+	}
+	return c, nil
+}
+
+// scanCorrections scans rows of correctionColumns
+func scanCorrections(rows pgx.Rows) ([]*Correction, error) {
+	corrections := []*Correction{}
+	for rows.Next() {
+		c, err := scanCorrection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan correction: %w", err)
+		}
+		corrections = append(corrections, c)
+	}
+	return corrections, rows.Err()
+}
+
+// CreateCorrection records a correction of an entity of a document
+func (r *Repository) CreateCorrection(ctx context.Context, tenantID, documentID uuid.UUID, c *Correction) error {
+	changes, err := json.Marshal(c.Changes)
+	if err != nil {
+		return fmt.Errorf("encode changes: %w", err)
+	}
+
+	query := `
+		INSERT INTO extraction_corrections (tenant_id, document_id, entity_type, entity_id, changes, changed_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, changed_at
+	`
+	err = r.db.QueryRow(ctx, query, tenantID, documentID, c.EntityType, c.EntityID, changes, c.ChangedBy).
+		Scan(&c.ID, &c.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("create correction: %w", err)
+	}
+	return nil
+}
+
+// ListCorrections returns the corrections of an entity, newest first
+func (r *Repository) ListCorrections(ctx context.Context, entityType string, entityID uuid.UUID) ([]*Correction, error) {
+	query := `SELECT ` + correctionColumns + ` FROM extraction_corrections
+		WHERE entity_type = $1 AND entity_id = $2 ORDER BY changed_at DESC`
+
+	rows, err := r.db.Query(ctx, query, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("list corrections: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCorrections(rows)
+}
+
+// ClaimUndo marks the latest correction of an entity that is not undone yet
+// as undone by a user and returns it. Of concurrent undos of the same
+// correction one wins, the others get ErrNothingToUndo.
+func (r *Repository) ClaimUndo(ctx context.Context, entityType string, entityID uuid.UUID, userID *uuid.UUID) (*Correction, error) {
+	query := `
+		UPDATE extraction_corrections SET undone_at = NOW(), undone_by = $3
+		WHERE id = (
+			SELECT id FROM extraction_corrections
+			WHERE entity_type = $1 AND entity_id = $2 AND undone_at IS NULL
+			ORDER BY changed_at DESC LIMIT 1
+			FOR UPDATE
+		) AND undone_at IS NULL
+		RETURNING ` + correctionColumns
+
+	c, err := scanCorrection(r.db.QueryRow(ctx, query, entityType, entityID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNothingToUndo
+		}
+		return nil, fmt.Errorf("claim undo: %w", err)
+	}
+	return c, nil
+}
+
+// ReleaseUndo marks a claimed correction as not undone again, when its old
+// values could not be restored
+func (r *Repository) ReleaseUndo(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE extraction_corrections SET undone_at = NULL, undone_by = NULL WHERE id = $1`
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("release undo: %w", err)
+	}
+	return nil
+}
+
+// attachHistory sets the correction history of the deadlines and amounts of
+// a document from its corrections, newest first
+func attachHistory(corrections []*Correction, deadlines []*Deadline, amounts []*Amount) {
+	history := make(map[uuid.UUID][]*Correction)
+	for _, c := range corrections {
+		history[c.EntityID] = append(history[c.EntityID], c)
+	}
+	for _, d := range deadlines {
+		d.History = history[d.ID]
+	}
+	for _, a := range amounts {
+		a.History = history[a.ID]
+	}
+}
+
+// recordCorrection records the changes a user made to the correctable
+// fields of an entity, if any
+func (s *Service) recordCorrection(ctx context.Context, tenantID, documentID uuid.UUID, entityType string, entityID uuid.UUID, userID *uuid.UUID, before, after any) error {
+	changes, err := DiffFields(before, after)
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+	return s.repo.CreateCorrection(ctx, tenantID, documentID, &Correction{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Changes:    changes,
+		ChangedBy:  userID,
+	})
+}
+
+// GetDeadline returns a deadline with its correction history
+func (s *Service) GetDeadline(ctx context.Context, id uuid.UUID) (*Deadline, error) {
+	deadline, err := s.repo.GetDeadlineByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if deadline.History, err = s.repo.ListCorrections(ctx, EntityDeadline, id); err != nil {
+		return nil, err
+	}
+	return deadline, nil
+}
+
+// GetAmount returns an amount with its correction history
+func (s *Service) GetAmount(ctx context.Context, id uuid.UUID) (*Amount, error) {
+	amount, err := s.repo.GetAmountByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if amount.History, err = s.repo.ListCorrections(ctx, EntityAmount, id); err != nil {
+		return nil, err
+	}
+	return amount, nil
+}
+
+// UndoDeadline restores the fields changed by the latest correction of a
+// deadline that is not undone yet. Repeated undos step back through the
+// history. Returns ErrNothingToUndo if no correction is left.
+func (s *Service) UndoDeadline(ctx context.Context, id uuid.UUID, userID *uuid.UUID) (*Deadline, error) {
+	deadline, err := s.repo.GetDeadlineByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.repo.ClaimUndo(ctx, EntityDeadline, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := deadline.fields()
+	if err = RevertFields(&fields, c.Changes); err == nil {
+		deadline.setFields(fields)
+		err = s.repo.UpdateDeadline(ctx, deadline)
+	}
+	if err != nil {
+		return nil, errors.Join(err, s.repo.ReleaseUndo(ctx, c.ID))
+	}
+
+	return s.GetDeadline(ctx, id)
+}
+
+// UndoAmount restores the fields changed by the latest correction of an
+// amount that is not undone yet, see UndoDeadline
+func (s *Service) UndoAmount(ctx context.Context, id uuid.UUID, userID *uuid.UUID) (*Amount, error) {
+	amount, err := s.repo.GetAmountByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.repo.ClaimUndo(ctx, EntityAmount, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := amount.fields()
+	if err = RevertFields(&fields, c.Changes); err == nil {
+		amount.setFields(fields)
+		err = s.repo.UpdateAmount(ctx, amount)
+	}
+	if err != nil {
+		return nil, errors.Join(err, s.repo.ReleaseUndo(ctx, c.ID))
+	}
+
+	return s.GetAmount(ctx, id)
+}
@@ -2,6 +2,7 @@ package analysis
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -9,16 +10,17 @@ import (
 	"time"
 
 	"austrian-business-infrastructure/internal/ai"
+	"austrian-business-infrastructure/pkg/money"
 )
 
 // Extractor handles extraction of structured data from documents
 type Extractor struct {
-	aiClient     *ai.Client
+	aiClient     ai.Completer
 	promptLoader *ai.PromptLoader
 }
 
 // NewExtractor creates a new data extractor
-func NewExtractor(aiClient *ai.Client, promptLoader *ai.PromptLoader) *Extractor {
+func NewExtractor(aiClient ai.Completer, promptLoader *ai.PromptLoader) *Extractor {
 	return &Extractor{
 		aiClient:     aiClient,
 		promptLoader: promptLoader,
@@ -58,12 +60,13 @@ func (e *Extractor) ExtractDeadlines(ctx context.Context, text string) ([]Extrac
 	userPrompt = strings.ReplaceAll(userPrompt, "{current_date}", time.Now().Format("02.01.2006"))
 	userPrompt = strings.ReplaceAll(userPrompt, "{delivery_date}", "unbekannt")
 
-	response, err := e.aiClient.CompleteWithRetry(ctx, systemPrompt, userPrompt, 0.1, 2)
+	response, err := e.aiClient.CompleteWithRetry(ai.WithOperation(ctx, ai.OpExtraction), systemPrompt, userPrompt, 0.1, 2)
 	if err != nil {
 		return nil, fmt.Errorf("AI deadline extraction failed: %w", err)
 	}
 
 	parsed, err := ai.ParseDeadlines(response.GetText())
+	response.ReportQuality(err == nil, 0)
 	if err != nil {
 		// Fall back to regex extraction
 		return e.extractDeadlinesRegex(text), nil
@@ -167,13 +170,13 @@ func (e *Extractor) extractDeadlinesRegex(text string) []ExtractedDeadline {
 
 // ExtractedAmount represents an extracted monetary amount
 type ExtractedAmount struct {
-	Type        string     `json:"type"`
-	Amount      float64    `json:"amount"`
-	Currency    string     `json:"currency"`
-	Description string     `json:"description"`
-	SourceText  string     `json:"source_text"`
-	Confidence  float64    `json:"confidence"`
-	DueDate     *time.Time `json:"due_date,omitempty"`
+	Type        string      `json:"type"`
+	Amount      money.Money `json:"amount"`
+	Currency    string      `json:"currency"`
+	Description string      `json:"description"`
+	SourceText  string      `json:"source_text"`
+	Confidence  float64     `json:"confidence"`
+	DueDate     *time.Time  `json:"due_date,omitempty"`
 }
 
 // ExtractAmounts extracts monetary amounts from document text
@@ -195,18 +198,20 @@ func (e *Extractor) ExtractAmounts(ctx context.Context, text string) ([]Extracte
 
 	userPrompt := strings.ReplaceAll(userTemplate, "{document_text}", truncatedText)
 
-	response, err := e.aiClient.CompleteWithRetry(ctx, systemPrompt, userPrompt, 0.1, 2)
+	response, err := e.aiClient.CompleteWithRetry(ai.WithOperation(ctx, ai.OpExtraction), systemPrompt, userPrompt, 0.1, 2)
 	if err != nil {
 		return nil, fmt.Errorf("AI amount extraction failed: %w", err)
 	}
 
 	parsed, err := ai.ParseAmounts(response.GetText())
+	response.ReportQuality(err == nil, 0)
 	if err != nil {
 		return e.extractAmountsRegex(text), nil
 	}
 
 	var amounts []ExtractedAmount
 	for _, a := range parsed.Amounts {
+		a.Amount.Currency = a.Currency
 		amount := ExtractedAmount{
 			Type:        a.AmountType,
 			Amount:      a.Amount,
@@ -240,13 +245,13 @@ func (e *Extractor) extractAmountsRegex(text string) []ExtractedAmount {
 		// Parse amount
 		amountStr = strings.ReplaceAll(amountStr, ".", "")
 		amountStr = strings.ReplaceAll(amountStr, ",", ".")
-		amountVal, err := strconv.ParseFloat(amountStr, 64)
-		if err != nil || amountVal == 0 {
+		amountVal, err := money.Parse(amountStr, "EUR")
+		if err != nil || amountVal.IsZero() {
 			continue
 		}
 
 		// Skip very small or very large amounts
-		if amountVal < 1 || amountVal > 10000000 {
+		if amountVal.Cents < 100 || amountVal.Cents > 1000000000 {
 			continue
 		}
 
@@ -319,12 +324,13 @@ func (e *Extractor) Summarize(ctx context.Context, text string) (*SummaryResult,
 
 	userPrompt := strings.ReplaceAll(userTemplate, "{document_text}", truncatedText)
 
-	response, err := e.aiClient.CompleteWithRetry(ctx, systemPrompt, userPrompt, 0.3, 2)
+	response, err := e.aiClient.CompleteWithRetry(ai.WithOperation(ctx, ai.OpSummary), systemPrompt, userPrompt, 0.3, 2)
 	if err != nil {
 		return nil, fmt.Errorf("AI summarization failed: %w", err)
 	}
 
 	parsed, err := ai.ParseSummary(response.GetText())
+	response.ReportQuality(err == nil, 0)
 	if err != nil {
 		// Return raw text as summary
 		return &SummaryResult{
@@ -342,6 +348,37 @@ func (e *Extractor) Summarize(ctx context.Context, text string) (*SummaryResult,
 	}, nil
 }
 
+// Translate translates texts such as a summary and its key points into the
+// target language. The result has the same length and order as texts.
+func (e *Extractor) Translate(ctx context.Context, texts []string, from, to string) ([]string, error) {
+	input, err := json.Marshal(texts)
+	if err != nil {
+		return nil, err
+	}
+	userPrompt := fmt.Sprintf("Source language: %s\nTarget language: %s\n\n%s", from, to, input)
+
+	response, err := e.aiClient.CompleteWithRetry(ai.WithOperation(ctx, ai.OpTranslation), translatePrompt, userPrompt, 0.2, 2)
+	if err != nil {
+		return nil, fmt.Errorf("AI translation failed: %w", err)
+	}
+
+	text := strings.TrimSpace(response.GetText())
+	if start, end := strings.Index(text, "["), strings.LastIndex(text, "]"); start >= 0 && end > start {
+		text = text[start : end+1]
+	}
+	var translated []string
+	if err := json.Unmarshal([]byte(text), &translated); err != nil {
+		response.ReportQuality(false, 0)
+		return nil, fmt.Errorf("parse translation: %w", err)
+	}
+	if len(translated) != len(texts) {
+		response.ReportQuality(false, 0)
+		return nil, fmt.Errorf("translation returned %d texts, want %d", len(translated), len(texts))
+	}
+	response.ReportQuality(true, 0)
+	return translated, nil
+}
+
 // ActionItemResult represents a generated action item
 type ActionItemResult struct {
 	Title       string     `json:"title"`
@@ -510,15 +547,17 @@ func (e *Extractor) GenerateSuggestions(ctx context.Context, text string, classi
 	userPrompt := strings.ReplaceAll(userTemplate, "{document_text}", truncatedText)
 	userPrompt = strings.ReplaceAll(userPrompt, "{client_context}", "Keine zusätzlichen Informationen")
 
-	response, err := e.aiClient.CompleteWithRetry(ctx, systemPrompt, userPrompt, 0.5, 2)
+	response, err := e.aiClient.CompleteWithRetry(ai.WithOperation(ctx, ai.OpSuggestion), systemPrompt, userPrompt, 0.5, 2)
 	if err != nil {
 		return nil, fmt.Errorf("AI suggestion generation failed: %w", err)
 	}
 
 	parsed, err := ai.ParseSuggestion(response.GetText())
 	if err != nil {
+		response.ReportQuality(false, 0)
 		return nil, fmt.Errorf("parse suggestion response: %w", err)
 	}
+	response.ReportQuality(true, parsed.Confidence)
 
 	return []SuggestionResult{{
 		Type:       "formal_response",
@@ -627,6 +666,11 @@ Gib die Antwort als JSON in diesem Format:
   ]
 }`
 
+const translatePrompt = `You translate summaries of Austrian official documents for notifications.
+Translate each string of the JSON array from the source into the target language.
+Keep dates, amounts, reference numbers and names of authorities unchanged.
+Answer only with a JSON array of the translated strings, in the same order.`
+
 const defaultSummaryPrompt = `Du bist ein Experte für österreichische Behördendokumente.
 Erstelle eine verständliche Zusammenfassung des Dokuments in einfachem Deutsch.
 
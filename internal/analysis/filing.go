@@ -0,0 +1,876 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/pkg/money"
+)
+
+var (
+	ErrFilingRuleNotFound = errors.New("filing rule not found")
+	ErrFilingRuleExists   = errors.New("filing rule already exists")
+	ErrInvalidFilingRule  = errors.New("invalid filing rule")
+)
+
+// Filing limits
+const (
+	maxFilingLabels        = 20
+	maxFilingLabelLength   = 50
+	maxFilingRuleName      = 100
+	maxFilingDueInDays     = 365
+	defaultPreviewLimit    = 20
+	maxPreviewLimit        = 100
+	filingActionCategory   = "filing"
+	filingPeriodLayout     = "2006-01"
+	filingPeriodFormatHint = "YYYY-MM"
+)
+
+// filingPlaceholders are replaced in folders, labels and action item titles
+var filingPlaceholders = map[string]func(*FilingInput) string{
+	"year":    func(in *FilingInput) string { return strconv.Itoa(in.Period.Year()) },
+	"month":   func(in *FilingInput) string { return fmt.Sprintf("%02d", int(in.Period.Month())) },
+	"quarter": func(in *FilingInput) string { return "Q" + strconv.Itoa((int(in.Period.Month())+2)/3) },
+	"type":    func(in *FilingInput) string { return in.DocumentType },
+}
+
+var filingPlaceholderPattern = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// FilingConditions select the documents a rule files. All conditions that
+// are set must hold; a list matches if any of its entries does.
+type FilingConditions struct {
+	DocumentTypes  []string `json:"document_types,omitempty"`
+	SenderContains []string `json:"sender_contains,omitempty"` // case-insensitive
+	// An extracted amount of one of AmountTypes (any type if empty) must lie
+	// between AmountMin and AmountMax
+	AmountTypes []string     `json:"amount_types,omitempty"`
+	AmountMin   *money.Money `json:"amount_min,omitempty"`
+	AmountMax   *money.Money `json:"amount_max,omitempty"`
+	// The period the document concerns, as YYYY-MM, both inclusive
+	PeriodFrom string `json:"period_from,omitempty"`
+	PeriodTo   string `json:"period_to,omitempty"`
+}
+
+// FilingActionItem is an action item a rule creates
+type FilingActionItem struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Priority    Priority `json:"priority,omitempty"`
+	DueInDays   int      `json:"due_in_days,omitempty"`
+}
+
+// FilingActions are applied to the documents a rule matches
+type FilingActions struct {
+	Folder     string            `json:"folder,omitempty"`
+	Labels     []string          `json:"labels,omitempty"`
+	AssignTo   *uuid.UUID        `json:"assign_to,omitempty"`
+	ActionItem *FilingActionItem `json:"action_item,omitempty"`
+}
+
+// FilingRule files analyzed documents of a tenant
+type FilingRule struct {
+	ID             uuid.UUID        `json:"id"`
+	TenantID       uuid.UUID        `json:"tenant_id"`
+	Name           string           `json:"name"`
+	Description    string           `json:"description,omitempty"`
+	Position       int              `json:"position"`
+	Enabled        bool             `json:"enabled"`
+	StopProcessing bool             `json:"stop_processing"`
+	Conditions     FilingConditions `json:"conditions"`
+	Actions        FilingActions    `json:"actions"`
+	CreatedBy      *uuid.UUID       `json:"created_by,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// FilingRuleRequest represents a create or update request for a filing rule
+type FilingRuleRequest struct {
+	Name           string           `json:"name"`
+	Description    string           `json:"description,omitempty"`
+	Position       *int             `json:"position,omitempty"`
+	Enabled        *bool            `json:"enabled,omitempty"`
+	StopProcessing bool             `json:"stop_processing"`
+	Conditions     FilingConditions `json:"conditions"`
+	Actions        FilingActions    `json:"actions"`
+}
+
+// FilingInput is what the rules see of an analyzed document
+type FilingInput struct {
+	DocumentType string
+	Sender       string
+	Amounts      []*Amount
+	// Period is the first day of the month the document concerns: the UVA
+	// period named in the text (the first month of a quarter), otherwise the
+	// month it was received
+	Period time.Time
+}
+
+// FilingRuleRef names a rule in a filing plan
+type FilingRuleRef struct {
+	ID             uuid.UUID `json:"id"`
+	Name           string    `json:"name"`
+	AlreadyApplied bool      `json:"already_applied,omitempty"`
+}
+
+// FilingPlan is the combined outcome of the matching rules. The folder and
+// the assignee come from the first matching rule that sets them; labels and
+// action items are collected from all.
+type FilingPlan struct {
+	Rules       []FilingRuleRef    `json:"rules"`
+	Folder      string             `json:"folder,omitempty"`
+	Labels      []string           `json:"labels,omitempty"`
+	AssignTo    *uuid.UUID         `json:"assign_to,omitempty"`
+	ActionItems []FilingActionItem `json:"action_items,omitempty"`
+}
+
+// ValidateFilingRule checks a filing rule. Document types are checked
+// against the tenant taxonomy by the service.
+func ValidateFilingRule(rule *FilingRule) error {
+	if name := strings.TrimSpace(rule.Name); name == "" || len(name) > maxFilingRuleName {
+		return fmt.Errorf("%w: name is required and at most %d characters", ErrInvalidFilingRule, maxFilingRuleName)
+	}
+
+	c := rule.Conditions
+	if c.AmountMin != nil && c.AmountMax != nil && c.AmountMin.Cents > c.AmountMax.Cents {
+		return fmt.Errorf("%w: amount_min exceeds amount_max", ErrInvalidFilingRule)
+	}
+	from, to, err := filingPeriodRange(c.PeriodFrom, c.PeriodTo)
+	if err != nil {
+		return err
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return fmt.Errorf("%w: period_from is after period_to", ErrInvalidFilingRule)
+	}
+
+	a := rule.Actions
+	if a.Folder == "" && len(a.Labels) == 0 && a.AssignTo == nil && a.ActionItem == nil {
+		return fmt.Errorf("%w: at least one action is required", ErrInvalidFilingRule)
+	}
+	if len(document.CleanFolder(a.Folder)) > document.MaxFolderLength {
+		return fmt.Errorf("%w: folder exceeds %d characters", ErrInvalidFilingRule, document.MaxFolderLength)
+	}
+	if err := checkFilingPlaceholders("folder", a.Folder); err != nil {
+		return err
+	}
+	if len(a.Labels) > maxFilingLabels {
+		return fmt.Errorf("%w: at most %d labels", ErrInvalidFilingRule, maxFilingLabels)
+	}
+	for _, label := range a.Labels {
+		if label = strings.TrimSpace(label); label == "" || len(label) > maxFilingLabelLength {
+			return fmt.Errorf("%w: labels must have 1 to %d characters", ErrInvalidFilingRule, maxFilingLabelLength)
+		}
+		if err := checkFilingPlaceholders("label", label); err != nil {
+			return err
+		}
+	}
+	if item := a.ActionItem; item != nil {
+		if strings.TrimSpace(item.Title) == "" {
+			return fmt.Errorf("%w: action item title is required", ErrInvalidFilingRule)
+		}
+		if err := checkFilingPlaceholders("action item title", item.Title); err != nil {
+			return err
+		}
+		switch item.Priority {
+		case "", PriorityHigh, PriorityMedium, PriorityLow:
+		default:
+			return fmt.Errorf("%w: unknown action item priority %q", ErrInvalidFilingRule, item.Priority)
+		}
+		if item.DueInDays < 0 || item.DueInDays > maxFilingDueInDays {
+			return fmt.Errorf("%w: due_in_days must be between 0 and %d", ErrInvalidFilingRule, maxFilingDueInDays)
+		}
+	}
+	return nil
+}
+
+func checkFilingPlaceholders(field, s string) error {
+	for _, m := range filingPlaceholderPattern.FindAllStringSubmatch(s, -1) {
+		if _, ok := filingPlaceholders[m[1]]; !ok {
+			return fmt.Errorf("%w: unknown placeholder %s in %s", ErrInvalidFilingRule, m[0], field)
+		}
+	}
+	return nil
+}
+
+func filingPeriodRange(from, to string) (time.Time, time.Time, error) {
+	var start, end time.Time
+	var err error
+	if from != "" {
+		if start, err = time.Parse(filingPeriodLayout, from); err != nil {
+			return start, end, fmt.Errorf("%w: period_from must be %s", ErrInvalidFilingRule, filingPeriodFormatHint)
+		}
+	}
+	if to != "" {
+		if end, err = time.Parse(filingPeriodLayout, to); err != nil {
+			return start, end, fmt.Errorf("%w: period_to must be %s", ErrInvalidFilingRule, filingPeriodFormatHint)
+		}
+	}
+	return start, end, nil
+}
+
+// Matches reports whether the conditions hold for a document
+func (c *FilingConditions) Matches(in *FilingInput) bool {
+	if len(c.DocumentTypes) > 0 && !slices.Contains(c.DocumentTypes, in.DocumentType) {
+		return false
+	}
+
+	if len(c.SenderContains) > 0 {
+		sender := strings.ToLower(in.Sender)
+		found := false
+		for _, s := range c.SenderContains {
+			if s = strings.ToLower(strings.TrimSpace(s)); s != "" && strings.Contains(sender, s) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(c.AmountTypes) > 0 || c.AmountMin != nil || c.AmountMax != nil {
+		found := false
+		for _, a := range in.Amounts {
+			if len(c.AmountTypes) > 0 && !slices.Contains(c.AmountTypes, a.AmountType) {
+				continue
+			}
+			if c.AmountMin != nil && a.Amount.Cents < c.AmountMin.Cents {
+				continue
+			}
+			if c.AmountMax != nil && a.Amount.Cents > c.AmountMax.Cents {
+				continue
+			}
+			found = true
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if c.PeriodFrom != "" || c.PeriodTo != "" {
+		from, to, err := filingPeriodRange(c.PeriodFrom, c.PeriodTo)
+		if err != nil || in.Period.IsZero() {
+			return false
+		}
+		if !from.IsZero() && in.Period.Before(from) {
+			return false
+		}
+		if !to.IsZero() && in.Period.After(to) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchFilingRules returns the enabled rules that match a document, in
+// position order, up to the first matching rule that stops processing
+func MatchFilingRules(rules []FilingRule, in *FilingInput) []FilingRule {
+	sorted := slices.Clone(rules)
+	slices.SortStableFunc(sorted, func(a, b FilingRule) int { return a.Position - b.Position })
+
+	var matched []FilingRule
+	for _, rule := range sorted {
+		if !rule.Enabled || !rule.Conditions.Matches(in) {
+			continue
+		}
+		matched = append(matched, rule)
+		if rule.StopProcessing {
+			break
+		}
+	}
+	return matched
+}
+
+// BuildFilingPlan combines the actions of matched rules
+func BuildFilingPlan(matched []FilingRule, in *FilingInput) *FilingPlan {
+	plan := &FilingPlan{Rules: []FilingRuleRef{}}
+	for _, rule := range matched {
+		plan.Rules = append(plan.Rules, FilingRuleRef{ID: rule.ID, Name: rule.Name})
+
+		a := rule.Actions
+		if plan.Folder == "" && a.Folder != "" {
+			plan.Folder = document.CleanFolder(renderFilingTemplate(a.Folder, in))
+		}
+		for _, label := range a.Labels {
+			label = strings.TrimSpace(renderFilingTemplate(label, in))
+			if !slices.Contains(plan.Labels, label) {
+				plan.Labels = append(plan.Labels, label)
+			}
+		}
+		if plan.AssignTo == nil && a.AssignTo != nil {
+			plan.AssignTo = a.AssignTo
+		}
+		if a.ActionItem != nil {
+			item := *a.ActionItem
+			item.Title = renderFilingTemplate(item.Title, in)
+			if item.Priority == "" {
+				item.Priority = PriorityMedium
+			}
+			plan.ActionItems = append(plan.ActionItems, item)
+		}
+	}
+	return plan
+}
+
+func renderFilingTemplate(s string, in *FilingInput) string {
+	return filingPlaceholderPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if value, ok := filingPlaceholders[m[1:len(m)-1]]; ok {
+			return value(in)
+		}
+		return m
+	})
+}
+
+// filingInput collects what the rules see of an analyzed document
+func filingInput(doc *document.Document, docType string, amounts []*Amount, text string) *FilingInput {
+	in := &FilingInput{
+		DocumentType: docType,
+		Sender:       doc.Sender,
+		Amounts:      amounts,
+	}
+	for _, ref := range ExtractReferences(text) {
+		if ref.Kind != document.ReferenceUVAPeriod {
+			continue
+		}
+		month := ref.Month
+		if month == 0 {
+			month = ref.Quarter*3 - 2
+		}
+		in.Period = time.Date(ref.Year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		break
+	}
+	if in.Period.IsZero() {
+		received := doc.ReceivedAt
+		if received.IsZero() {
+			received = doc.CreatedAt
+		}
+		in.Period = time.Date(received.Year(), received.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return in
+}
+
+// ============== Repository ==============
+
+const filingRuleColumns = `
+	id, tenant_id, name, COALESCE(description, ''), position, enabled, stop_processing,
+	conditions, actions, created_by, created_at, updated_at`
+
+func scanFilingRule(row pgx.Row) (*FilingRule, error) {
+	rule := &FilingRule{}
+	var conditions, actions []byte
+
+	err := row.Scan(
+		&rule.ID, &rule.TenantID, &rule.Name, &rule.Description, &rule.Position, &rule.Enabled,
+		&rule.StopProcessing, &conditions, &actions, &rule.CreatedBy, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(conditions, &rule.Conditions); err != nil {
+		return nil, fmt.Errorf("decode conditions: %w", err)
+	}
+	if err := json.Unmarshal(actions, &rule.Actions); err != nil {
+		return nil, fmt.Errorf("decode actions: %w", err)
+	}
+	return rule, nil
+}
+
+// CreateFilingRule creates a filing rule
+func (r *Repository) CreateFilingRule(ctx context.Context, rule *FilingRule) error {
+	conditions, _ := json.Marshal(rule.Conditions)
+	actions, _ := json.Marshal(rule.Actions)
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO filing_rules (
+			tenant_id, name, description, position, enabled, stop_processing, conditions, actions, created_by
+		) VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`,
+		rule.TenantID, rule.Name, rule.Description, rule.Position, rule.Enabled, rule.StopProcessing,
+		conditions, actions, rule.CreatedBy,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "23505") || strings.Contains(err.Error(), "duplicate key") {
+			return ErrFilingRuleExists
+		}
+		return fmt.Errorf("create filing rule: %w", err)
+	}
+	return nil
+}
+
+// GetFilingRule retrieves a filing rule of a tenant
+func (r *Repository) GetFilingRule(ctx context.Context, tenantID, id uuid.UUID) (*FilingRule, error) {
+	query := `SELECT ` + filingRuleColumns + ` FROM filing_rules WHERE id = $1 AND tenant_id = $2`
+
+	rule, err := scanFilingRule(r.db.QueryRow(ctx, query, id, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFilingRuleNotFound
+		}
+		return nil, fmt.Errorf("get filing rule: %w", err)
+	}
+	return rule, nil
+}
+
+// ListFilingRules lists the filing rules of a tenant in evaluation order
+func (r *Repository) ListFilingRules(ctx context.Context, tenantID uuid.UUID, enabledOnly bool) ([]FilingRule, error) {
+	query := `SELECT ` + filingRuleColumns + `
+		FROM filing_rules
+		WHERE tenant_id = $1 AND (enabled OR NOT $2)
+		ORDER BY position, created_at`
+
+	rows, err := r.db.Query(ctx, query, tenantID, enabledOnly)
+	if err != nil {
+		return nil, fmt.Errorf("list filing rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []FilingRule{}
+	for rows.Next() {
+		rule, err := scanFilingRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan filing rule: %w", err)
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+// UpdateFilingRule updates a filing rule
+func (r *Repository) UpdateFilingRule(ctx context.Context, rule *FilingRule) error {
+	conditions, _ := json.Marshal(rule.Conditions)
+	actions, _ := json.Marshal(rule.Actions)
+
+	err := r.db.QueryRow(ctx, `
+		UPDATE filing_rules SET
+			name = $3, description = NULLIF($4, ''), position = $5, enabled = $6,
+			stop_processing = $7, conditions = $8, actions = $9, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2
+		RETURNING updated_at
+	`,
+		rule.ID, rule.TenantID, rule.Name, rule.Description, rule.Position, rule.Enabled,
+		rule.StopProcessing, conditions, actions,
+	).Scan(&rule.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrFilingRuleNotFound
+		}
+		if strings.Contains(err.Error(), "23505") || strings.Contains(err.Error(), "duplicate key") {
+			return ErrFilingRuleExists
+		}
+		return fmt.Errorf("update filing rule: %w", err)
+	}
+	return nil
+}
+
+// DeleteFilingRule deletes a filing rule. Filed documents stay where they are.
+func (r *Repository) DeleteFilingRule(ctx context.Context, tenantID, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM filing_rules WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("delete filing rule: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrFilingRuleNotFound
+	}
+	return nil
+}
+
+// nextFilingRulePosition returns the position after the last rule of a tenant
+func (r *Repository) nextFilingRulePosition(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	var position int
+	err := r.db.QueryRow(ctx, `SELECT COALESCE(MAX(position) + 1, 0) FROM filing_rules WHERE tenant_id = $1`, tenantID).Scan(&position)
+	if err != nil {
+		return 0, fmt.Errorf("next filing rule position: %w", err)
+	}
+	return position, nil
+}
+
+// tenantHasUser reports whether a user belongs to a tenant
+func (r *Repository) tenantHasUser(ctx context.Context, tenantID, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND tenant_id = $2)`, userID, tenantID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check assignee: %w", err)
+	}
+	return exists, nil
+}
+
+// appliedFilingRules returns the rules that already acted on a document
+func (r *Repository) appliedFilingRules(ctx context.Context, documentID uuid.UUID) (map[uuid.UUID]bool, error) {
+	rows, err := r.db.Query(ctx, `SELECT rule_id FROM filing_rule_matches WHERE document_id = $1`, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("list applied filing rules: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan applied filing rule: %w", err)
+		}
+		applied[id] = true
+	}
+	return applied, rows.Err()
+}
+
+// recordFilingMatches records the rules that acted on a document
+func (r *Repository) recordFilingMatches(ctx context.Context, tenantID, documentID, analysisID uuid.UUID, ruleIDs []uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO filing_rule_matches (rule_id, document_id, tenant_id, analysis_id)
+		SELECT unnest($1::uuid[]), $2, $3, $4
+		ON CONFLICT (rule_id, document_id) DO NOTHING
+	`, ruleIDs, documentID, tenantID, analysisID)
+	if err != nil {
+		return fmt.Errorf("record filing matches: %w", err)
+	}
+	return nil
+}
+
+// latestCompletedAnalyses returns the latest completed analysis of the most
+// recently analyzed documents of a tenant
+func (r *Repository) latestCompletedAnalyses(ctx context.Context, tenantID uuid.UUID, limit int) ([]*Analysis, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
+	query := analysisSelect + `
+		WHERE id IN (
+			SELECT DISTINCT ON (document_id) id FROM document_analyses
+			WHERE tenant_id = $1 AND status = 'completed'
+			ORDER BY document_id, created_at DESC
+		)
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, tenantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list latest analyses: %w", err)
+	}
+	var analyses []*Analysis
+	for rows.Next() {
+		a, err := scanAnalysis(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan analysis: %w", err)
+		}
+		analyses = append(analyses, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list latest analyses: %w", err)
+	}
+
+	for _, a := range analyses {
+		if err := r.restoreText(ctx, a); err != nil {
+			return nil, err
+		}
+	}
+	return analyses, nil
+}
+
+// ============== Service ==============
+
+// ListFilingRules lists the filing rules of a tenant
+func (s *Service) ListFilingRules(ctx context.Context, tenantID uuid.UUID) ([]FilingRule, error) {
+	return s.repo.ListFilingRules(ctx, tenantID, false)
+}
+
+// GetFilingRule returns a filing rule of a tenant
+func (s *Service) GetFilingRule(ctx context.Context, tenantID, id uuid.UUID) (*FilingRule, error) {
+	return s.repo.GetFilingRule(ctx, tenantID, id)
+}
+
+// CreateFilingRule adds a filing rule; without a position it is evaluated last
+func (s *Service) CreateFilingRule(ctx context.Context, tenantID uuid.UUID, userID *uuid.UUID, req *FilingRuleRequest) (*FilingRule, error) {
+	rule := &FilingRule{TenantID: tenantID, Enabled: true, CreatedBy: userID}
+	applyFilingRuleRequest(rule, req)
+
+	if req.Position == nil {
+		position, err := s.repo.nextFilingRulePosition(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		rule.Position = position
+	}
+
+	if err := s.validateFilingRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateFilingRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdateFilingRule replaces the conditions and actions of a filing rule
+func (s *Service) UpdateFilingRule(ctx context.Context, tenantID, id uuid.UUID, req *FilingRuleRequest) (*FilingRule, error) {
+	rule, err := s.repo.GetFilingRule(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	applyFilingRuleRequest(rule, req)
+
+	if err := s.validateFilingRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateFilingRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteFilingRule removes a filing rule
+func (s *Service) DeleteFilingRule(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.DeleteFilingRule(ctx, tenantID, id)
+}
+
+func applyFilingRuleRequest(rule *FilingRule, req *FilingRuleRequest) {
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.Description = req.Description
+	if req.Position != nil {
+		rule.Position = *req.Position
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	rule.StopProcessing = req.StopProcessing
+	rule.Conditions = req.Conditions
+	rule.Actions = req.Actions
+}
+
+// validateFilingRule checks a rule, its document types against the tenant
+// taxonomy and its assignee against the tenant's users
+func (s *Service) validateFilingRule(ctx context.Context, rule *FilingRule) error {
+	if err := ValidateFilingRule(rule); err != nil {
+		return err
+	}
+
+	custom := s.tenantTaxonomy(ctx, rule.TenantID)
+	for _, t := range rule.Conditions.DocumentTypes {
+		if !isValidDocumentType(DocumentType(t)) && findTaxonomyType(custom, t) == nil {
+			return fmt.Errorf("%w: %q", ErrUnknownDocumentType, t)
+		}
+	}
+
+	if rule.Actions.AssignTo != nil {
+		ok, err := s.repo.tenantHasUser(ctx, rule.TenantID, *rule.Actions.AssignTo)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: assignee is not a user of the tenant", ErrInvalidFilingRule)
+		}
+	}
+	return nil
+}
+
+// applyFilingRules files an analyzed document by the tenant's rules. Each
+// rule acts on a document once, so a re-analysis only applies rules that
+// did not match before.
+func (s *Service) applyFilingRules(ctx context.Context, analysis *Analysis, doc *document.Document, amounts []*Amount) (*FilingPlan, error) {
+	rules, err := s.repo.ListFilingRules(ctx, analysis.TenantID, true)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+
+	in := filingInput(doc, analysis.DocumentType, amounts, analysis.ExtractedText)
+	applied, err := s.repo.appliedFilingRules(ctx, doc.ID)
+	if err != nil {
+		return nil, err
+	}
+	var fresh []FilingRule
+	for _, rule := range MatchFilingRules(rules, in) {
+		if !applied[rule.ID] {
+			fresh = append(fresh, rule)
+		}
+	}
+	if len(fresh) == 0 {
+		return nil, nil
+	}
+
+	plan := BuildFilingPlan(fresh, in)
+	if plan.Folder != "" || len(plan.Labels) > 0 || plan.AssignTo != nil {
+		filing := &document.Filing{Folder: plan.Folder, Labels: plan.Labels, AssignedTo: plan.AssignTo}
+		if err := s.docService.File(ctx, analysis.TenantID, doc.ID, filing); err != nil {
+			return nil, fmt.Errorf("file document: %w", err)
+		}
+	}
+
+	for _, planned := range plan.ActionItems {
+		item := &ActionItem{
+			AnalysisID:  analysis.ID,
+			DocumentID:  doc.ID,
+			TenantID:    analysis.TenantID,
+			Title:       planned.Title,
+			Description: planned.Description,
+			Priority:    planned.Priority,
+			Category:    filingActionCategory,
+			Status:      ActionStatusPending,
+			Confidence:  1,
+		}
+		if planned.DueInDays > 0 {
+			due := time.Now().AddDate(0, 0, planned.DueInDays)
+			item.DueDate = &due
+		}
+		if plan.AssignTo != nil {
+			assignee := plan.AssignTo.String()
+			item.AssignedTo = &assignee
+		}
+		if err := s.repo.CreateActionItem(ctx, item); err != nil {
+			return nil, fmt.Errorf("create action item: %w", err)
+		}
+	}
+
+	ruleIDs := make([]uuid.UUID, len(fresh))
+	for i, rule := range fresh {
+		ruleIDs[i] = rule.ID
+	}
+	if err := s.repo.recordFilingMatches(ctx, analysis.TenantID, doc.ID, analysis.ID, ruleIDs); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// FilingPreviewRequest asks what the filing rules would do. With a rule, only
+// that (unsaved) rule is evaluated, otherwise the tenant's enabled rules.
+// Without document IDs the most recently analyzed documents are used.
+type FilingPreviewRequest struct {
+	Rule        *FilingRuleRequest `json:"rule,omitempty"`
+	DocumentIDs []uuid.UUID        `json:"document_ids,omitempty"`
+	Limit       int                `json:"limit,omitempty"`
+}
+
+// FilingPreviewResult is the filing plan for one document
+type FilingPreviewResult struct {
+	DocumentID    uuid.UUID   `json:"document_id"`
+	Title         string      `json:"title"`
+	DocumentType  string      `json:"document_type,omitempty"`
+	CurrentFolder string      `json:"current_folder,omitempty"`
+	Plan          *FilingPlan `json:"plan"`
+}
+
+// FilingPreview is the dry run of filing rules over analyzed documents
+type FilingPreview struct {
+	Evaluated int                   `json:"evaluated"`
+	Matched   int                   `json:"matched"`
+	Results   []FilingPreviewResult `json:"results"`
+}
+
+// PreviewFiling evaluates filing rules against analyzed documents without
+// changing them. Rules that already acted on a document are flagged; the
+// plan shows what the rules do for a document analyzed for the first time.
+func (s *Service) PreviewFiling(ctx context.Context, tenantID uuid.UUID, req *FilingPreviewRequest) (*FilingPreview, error) {
+	var rules []FilingRule
+	if req.Rule != nil {
+		rule := &FilingRule{TenantID: tenantID}
+		applyFilingRuleRequest(rule, req.Rule)
+		rule.Enabled = true // a draft is previewed even when it is disabled
+		if err := s.validateFilingRule(ctx, rule); err != nil {
+			return nil, err
+		}
+		rules = []FilingRule{*rule}
+	} else {
+		var err error
+		if rules, err = s.repo.ListFilingRules(ctx, tenantID, true); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultPreviewLimit
+	}
+	if limit > maxPreviewLimit {
+		limit = maxPreviewLimit
+	}
+
+	var analyses []*Analysis
+	if len(req.DocumentIDs) > 0 {
+		if len(req.DocumentIDs) > maxPreviewLimit {
+			return nil, fmt.Errorf("%w: at most %d documents", ErrInvalidFilingRule, maxPreviewLimit)
+		}
+		for _, id := range req.DocumentIDs {
+			a, err := s.repo.GetAnalysisByDocumentID(ctx, id)
+			if errors.Is(err, ErrAnalysisNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if a.TenantID == tenantID && a.Status == StatusCompleted {
+				analyses = append(analyses, a)
+			}
+		}
+	} else {
+		var err error
+		if analyses, err = s.repo.latestCompletedAnalyses(ctx, tenantID, limit); err != nil {
+			return nil, err
+		}
+	}
+
+	documentIDs := make([]uuid.UUID, len(analyses))
+	for i, a := range analyses {
+		documentIDs[i] = a.DocumentID
+	}
+	docs, err := s.docService.GetByIDs(ctx, tenantID, documentIDs)
+	if err != nil {
+		return nil, err
+	}
+	docsByID := make(map[uuid.UUID]*document.Document, len(docs))
+	for _, doc := range docs {
+		docsByID[doc.ID] = doc
+	}
+	extractions, err := s.repo.GetExtractionsByDocuments(ctx, documentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &FilingPreview{Results: []FilingPreviewResult{}}
+	for _, a := range analyses {
+		doc, ok := docsByID[a.DocumentID]
+		if !ok {
+			continue
+		}
+		var amounts []*Amount
+		if ex := extractions[a.DocumentID]; ex != nil {
+			amounts = ex.Amounts
+		}
+
+		in := filingInput(doc, a.DocumentType, amounts, a.ExtractedText)
+		matched := MatchFilingRules(rules, in)
+		plan := BuildFilingPlan(matched, in)
+		if len(matched) > 0 && req.Rule == nil {
+			applied, err := s.repo.appliedFilingRules(ctx, doc.ID)
+			if err != nil {
+				return nil, err
+			}
+			for i := range plan.Rules {
+				plan.Rules[i].AlreadyApplied = applied[plan.Rules[i].ID]
+			}
+		}
+
+		preview.Evaluated++
+		if len(matched) > 0 {
+			preview.Matched++
+		}
+		preview.Results = append(preview.Results, FilingPreviewResult{
+			DocumentID:    doc.ID,
+			Title:         doc.Title,
+			DocumentType:  a.DocumentType,
+			CurrentFolder: doc.Folder,
+			Plan:          plan,
+		})
+	}
+	return preview, nil
+}
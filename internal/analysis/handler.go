@@ -2,12 +2,18 @@ package analysis
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/filetype"
+	"austrian-business-infrastructure/pkg/money"
 )
 
 // Handler provides HTTP endpoints for document analysis
@@ -36,15 +42,41 @@ func (h *Handler) Routes() chi.Router {
 	r.Get("/analyses", h.ListAnalyses)
 	r.Get("/analyses/{analysisId}", h.GetAnalysis)
 	r.Get("/analyses/stats", h.GetAnalysisStats)
+	r.Get("/analyses/accuracy", h.GetClassificationAccuracy)
+	r.Put("/analyses/{analysisId}/classification", h.Reclassify)
+
+	// Tenant document type taxonomy
+	r.Get("/taxonomy", h.ListTaxonomyTypes)
+	r.Post("/taxonomy", h.CreateTaxonomyType)
+	r.Put("/taxonomy/{typeId}", h.UpdateTaxonomyType)
+	r.Delete("/taxonomy/{typeId}", h.DeleteTaxonomyType)
+
+	// Consent to AI processing per document type and purpose
+	r.Get("/consent", h.GetConsent)
+	r.Put("/consent", h.UpdateConsent)
+	r.Get("/consent/versions", h.ListConsentVersions)
+	r.Get("/consent/overview", h.GetConsentOverview)
+
+	// Filing rules
+	r.Get("/filing-rules", h.ListFilingRules)
+	r.Post("/filing-rules", h.CreateFilingRule)
+	r.Post("/filing-rules/preview", h.PreviewFiling)
+	r.Get("/filing-rules/{ruleId}", h.GetFilingRule)
+	r.Put("/filing-rules/{ruleId}", h.UpdateFilingRule)
+	r.Delete("/filing-rules/{ruleId}", h.DeleteFilingRule)
 
 	// Deadlines
 	r.Get("/deadlines/upcoming", h.GetUpcomingDeadlines)
+	r.Get("/deadlines/{deadlineId}", h.GetDeadline)
 	r.Put("/deadlines/{deadlineId}", h.UpdateDeadline)
 	r.Post("/deadlines/{deadlineId}/acknowledge", h.AcknowledgeDeadline)
+	r.Post("/deadlines/{deadlineId}/undo", h.UndoDeadline)
 
 	// Amounts
+	r.Get("/amounts/{amountId}", h.GetAmount)
 	r.Put("/amounts/{amountId}", h.UpdateAmount)
 	r.Delete("/amounts/{amountId}", h.DeleteAmount)
+	r.Post("/amounts/{amountId}/undo", h.UndoAmount)
 
 	// Action items
 	r.Get("/action-items/pending", h.GetPendingActionItems)
@@ -84,6 +116,8 @@ type AnalyzeDocumentRequest struct {
 	IncludeAmounts     *bool `json:"include_amounts,omitempty"`
 	IncludeActionItems *bool `json:"include_action_items,omitempty"`
 	IncludeSuggestions *bool `json:"include_suggestions,omitempty"`
+	IncludeLinks       *bool `json:"include_links,omitempty"`
+	IncludeFiling      *bool `json:"include_filing,omitempty"`
 }
 
 // AnalyzeDocument initiates document analysis
@@ -128,11 +162,21 @@ func (h *Handler) AnalyzeDocument(w http.ResponseWriter, r *http.Request) {
 			if req.IncludeSuggestions != nil {
 				opts.IncludeSuggestions = *req.IncludeSuggestions
 			}
+			if req.IncludeLinks != nil {
+				opts.IncludeLinks = *req.IncludeLinks
+			}
+			if req.IncludeFiling != nil {
+				opts.IncludeFiling = *req.IncludeFiling
+			}
 		}
 	}
 
 	result, err := h.service.AnalyzeDocument(ctx, documentID, tenantID, opts)
 	if err != nil {
+		if errors.Is(err, ErrConsentDenied) {
+			writeError(w, http.StatusForbidden, err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -251,7 +295,9 @@ func (h *Handler) GetDocumentSuggestions(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// ListAnalyses returns all analyses for a tenant
+// ListAnalyses returns all analyses for a tenant. The list is streamed;
+// ?fields= selects the fields of each analysis, and without extracted_text
+// the text is not loaded at all.
 func (h *Handler) ListAnalyses(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -274,18 +320,41 @@ func (h *Handler) ListAnalyses(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	analyses, total, err := h.service.ListAnalyses(ctx, tenantID, limit, offset)
+	fields := api.ParseFields(r)
+	analyses, total, err := h.service.ListAnalyses(ctx, tenantID, limit, offset, fields.Has("extracted_text"))
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"analyses": analyses,
-		"total":    total,
-		"limit":    limit,
-		"offset":   offset,
-	})
+	resp := map[string]interface{}{
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	}
+
+	// ?include=extractions adds the deadlines, amounts and action items of the
+	// listed documents, loaded for the whole page at once
+	if r.URL.Query().Get("include") == "extractions" {
+		extractions, err := h.service.GetExtractions(ctx, analyses)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp["extractions"] = extractions
+	}
+
+	stream, err := api.NewJSONStream(w, http.StatusOK, "analyses", fields, resp)
+	if err != nil {
+		return
+	}
+	for _, a := range analyses {
+		if err := stream.Write(a); err != nil {
+			stream.Fail("failed to encode analysis")
+			return
+		}
+	}
+	stream.Close(nil)
 }
 
 // GetAnalysis returns a single analysis by ID
@@ -382,6 +451,55 @@ func (h *Handler) AcknowledgeDeadline(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "acknowledged"})
 }
 
+// GetDeadline returns a deadline with its correction history
+func (h *Handler) GetDeadline(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	deadlineID, err := uuid.Parse(chi.URLParam(r, "deadlineId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid deadline ID")
+		return
+	}
+
+	deadline, err := h.service.GetDeadline(ctx, deadlineID)
+	if err != nil {
+		if err == ErrDeadlineNotFound {
+			writeError(w, http.StatusNotFound, "Deadline not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deadline)
+}
+
+// UndoDeadline undoes the latest correction of a deadline
+func (h *Handler) UndoDeadline(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	deadlineID, err := uuid.Parse(chi.URLParam(r, "deadlineId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid deadline ID")
+		return
+	}
+
+	deadline, err := h.service.UndoDeadline(ctx, deadlineID, getUserID(r))
+	if err != nil {
+		switch err {
+		case ErrDeadlineNotFound:
+			writeError(w, http.StatusNotFound, "Deadline not found")
+		case ErrNothingToUndo:
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deadline)
+}
+
 // GetPendingActionItems returns pending action items
 func (h *Handler) GetPendingActionItems(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -487,7 +605,7 @@ func (h *Handler) QuickClassify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.service.QuickClassify(ctx, req.Text, req.Title)
+	result, err := h.service.QuickClassify(ctx, getTenantID(r), req.Text, req.Title)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -552,7 +670,9 @@ func (h *Handler) QuickExtractDeadlines(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// AnalyzePDF analyzes an uploaded PDF
+// AnalyzePDF analyzes an uploaded PDF, Word document or image. The file type
+// is determined from the content and checked, with the page count and image
+// size, before any text is extracted.
 func (h *Handler) AnalyzePDF(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -571,15 +691,20 @@ func (h *Handler) AnalyzePDF(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	file, _, err := r.FormFile("file")
+	file, header, err := r.FormFile("file")
 	if err != nil {
 		writeError(w, http.StatusBadRequest, "No file provided")
 		return
 	}
 	defer file.Close()
 
-	// Read PDF data
-	pdfData, err := io.ReadAll(file)
+	info, err := filetype.Analysis.Check(header.Filename, file, header.Size)
+	if err != nil {
+		writeError(w, filetype.HTTPStatus(err), err.Error())
+		return
+	}
+
+	data, err := io.ReadAll(file)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "Failed to read file")
 		return
@@ -594,7 +719,7 @@ func (h *Handler) AnalyzePDF(w http.ResponseWriter, r *http.Request) {
 		opts = AnalysisOptions{IncludeSummary: true}
 	}
 
-	result, err := h.service.ProcessPDFBytes(ctx, tenantID, pdfData, opts)
+	result, err := h.service.ProcessBytes(ctx, tenantID, data, info.MIMEType, opts)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -619,6 +744,14 @@ func getTenantID(r *http.Request) uuid.UUID {
 	return uuid.Nil
 }
 
+func getUserID(r *http.Request) *uuid.UUID {
+	// Get from context (set by auth middleware)
+	if id, ok := r.Context().Value("user_id").(uuid.UUID); ok && id != uuid.Nil {
+		return &id
+	}
+	return nil
+}
+
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -655,7 +788,7 @@ func (h *Handler) UpdateDeadline(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	deadline, err := h.service.UpdateDeadline(ctx, deadlineID, &req)
+	deadline, err := h.service.UpdateDeadline(ctx, deadlineID, getUserID(r), &req)
 	if err != nil {
 		if err == ErrDeadlineNotFound {
 			writeError(w, http.StatusNotFound, "Deadline not found")
@@ -759,6 +892,10 @@ func (h *Handler) GenerateSuggestion(w http.ResponseWriter, r *http.Request) {
 
 	suggestion, err := h.service.GenerateSuggestion(ctx, documentID, tenantID, req.Context, req.Style)
 	if err != nil {
+		if errors.Is(err, ErrConsentDenied) {
+			writeError(w, http.StatusForbidden, err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -914,12 +1051,12 @@ func (h *Handler) DeleteResponseTemplate(w http.ResponseWriter, r *http.Request)
 
 // UpdateAmountRequest represents an amount update request (T074)
 type UpdateAmountRequest struct {
-	Amount      *float64 `json:"amount,omitempty"`
-	Currency    *string  `json:"currency,omitempty"`
-	AmountType  *string  `json:"amount_type,omitempty"`
-	Description *string  `json:"description,omitempty"`
-	DueDate     *string  `json:"due_date,omitempty"`
-	Notes       *string  `json:"notes,omitempty"`
+	Amount      *money.Money `json:"amount,omitempty"`
+	Currency    *string      `json:"currency,omitempty"`
+	AmountType  *string      `json:"amount_type,omitempty"`
+	Description *string      `json:"description,omitempty"`
+	DueDate     *string      `json:"due_date,omitempty"`
+	Notes       *string      `json:"notes,omitempty"`
 }
 
 // UpdateAmount updates an extracted amount (T074 - manual correction)
@@ -938,7 +1075,7 @@ func (h *Handler) UpdateAmount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	amount, err := h.service.UpdateAmount(ctx, amountID, &req)
+	amount, err := h.service.UpdateAmount(ctx, amountID, getUserID(r), &req)
 	if err != nil {
 		if err == ErrAmountNotFound {
 			writeError(w, http.StatusNotFound, "Amount not found")
@@ -972,3 +1109,473 @@ func (h *Handler) DeleteAmount(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// GetAmount returns an extracted amount with its correction history
+func (h *Handler) GetAmount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	amountID, err := uuid.Parse(chi.URLParam(r, "amountId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid amount ID")
+		return
+	}
+
+	amount, err := h.service.GetAmount(ctx, amountID)
+	if err != nil {
+		if err == ErrAmountNotFound {
+			writeError(w, http.StatusNotFound, "Amount not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, amount)
+}
+
+// UndoAmount undoes the latest correction of an extracted amount
+func (h *Handler) UndoAmount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	amountID, err := uuid.Parse(chi.URLParam(r, "amountId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid amount ID")
+		return
+	}
+
+	amount, err := h.service.UndoAmount(ctx, amountID, getUserID(r))
+	if err != nil {
+		switch err {
+		case ErrAmountNotFound:
+			writeError(w, http.StatusNotFound, "Amount not found")
+		case ErrNothingToUndo:
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, amount)
+}
+
+// ListTaxonomyTypes returns the document types of the tenant
+func (h *Handler) ListTaxonomyTypes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	types, err := h.service.ListTaxonomyTypes(ctx, tenantID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"types": types,
+		"count": len(types),
+	})
+}
+
+// CreateTaxonomyType adds a document type to the tenant taxonomy
+func (h *Handler) CreateTaxonomyType(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	var req TaxonomyTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	t, err := h.service.CreateTaxonomyType(ctx, tenantID, &req)
+	if err != nil {
+		writeTaxonomyError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, t)
+}
+
+// UpdateTaxonomyType updates a document type of the tenant taxonomy
+func (h *Handler) UpdateTaxonomyType(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	typeID, err := uuid.Parse(chi.URLParam(r, "typeId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid document type ID")
+		return
+	}
+
+	var req TaxonomyTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	t, err := h.service.UpdateTaxonomyType(ctx, tenantID, typeID, &req)
+	if err != nil {
+		writeTaxonomyError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, t)
+}
+
+// DeleteTaxonomyType removes a document type from the tenant taxonomy
+func (h *Handler) DeleteTaxonomyType(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	typeID, err := uuid.Parse(chi.URLParam(r, "typeId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid document type ID")
+		return
+	}
+
+	if err := h.service.DeleteTaxonomyType(ctx, tenantID, typeID); err != nil {
+		writeTaxonomyError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Reclassify corrects the document type of an analysis
+func (h *Handler) Reclassify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	analysisID, err := uuid.Parse(chi.URLParam(r, "analysisId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid analysis ID")
+		return
+	}
+
+	var req ReclassifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.DocumentType == "" {
+		writeError(w, http.StatusBadRequest, "document_type is required")
+		return
+	}
+
+	analysis, err := h.service.Reclassify(ctx, tenantID, analysisID, getUserID(r), &req)
+	if err != nil {
+		if errors.Is(err, ErrAnalysisNotFound) {
+			writeError(w, http.StatusNotFound, "Analysis not found")
+			return
+		}
+		writeTaxonomyError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, analysis)
+}
+
+// GetClassificationAccuracy returns per-type classification accuracy over time
+func (h *Handler) GetClassificationAccuracy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	months := 12
+	if m := r.URL.Query().Get("months"); m != "" {
+		if n, err := strconv.Atoi(m); err == nil && n > 0 && n <= 60 {
+			months = n
+		}
+	}
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -(months - 1), 0)
+
+	report, err := h.service.GetClassificationAccuracy(ctx, tenantID, since)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+func writeTaxonomyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrTaxonomyTypeNotFound):
+		writeError(w, http.StatusNotFound, "Document type not found")
+	case errors.Is(err, ErrTaxonomyTypeExists):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrInvalidTaxonomyCode), errors.Is(err, ErrUnknownDocumentType):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// ListFilingRules lists the filing rules of the tenant in evaluation order
+func (h *Handler) ListFilingRules(w http.ResponseWriter, r *http.Request) {
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	rules, err := h.service.ListFilingRules(r.Context(), tenantID)
+	if err != nil {
+		writeFilingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"rules": rules})
+}
+
+// GetFilingRule returns a filing rule of the tenant
+func (h *Handler) GetFilingRule(w http.ResponseWriter, r *http.Request) {
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	ruleID, err := uuid.Parse(chi.URLParam(r, "ruleId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid filing rule ID")
+		return
+	}
+
+	rule, err := h.service.GetFilingRule(r.Context(), tenantID, ruleID)
+	if err != nil {
+		writeFilingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// CreateFilingRule adds a filing rule to the tenant
+func (h *Handler) CreateFilingRule(w http.ResponseWriter, r *http.Request) {
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	var req FilingRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule, err := h.service.CreateFilingRule(r.Context(), tenantID, getUserID(r), &req)
+	if err != nil {
+		writeFilingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, rule)
+}
+
+// UpdateFilingRule updates a filing rule of the tenant
+func (h *Handler) UpdateFilingRule(w http.ResponseWriter, r *http.Request) {
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	ruleID, err := uuid.Parse(chi.URLParam(r, "ruleId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid filing rule ID")
+		return
+	}
+
+	var req FilingRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule, err := h.service.UpdateFilingRule(r.Context(), tenantID, ruleID, &req)
+	if err != nil {
+		writeFilingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// DeleteFilingRule removes a filing rule of the tenant
+func (h *Handler) DeleteFilingRule(w http.ResponseWriter, r *http.Request) {
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	ruleID, err := uuid.Parse(chi.URLParam(r, "ruleId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid filing rule ID")
+		return
+	}
+
+	if err := h.service.DeleteFilingRule(r.Context(), tenantID, ruleID); err != nil {
+		writeFilingError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PreviewFiling shows what the filing rules, or a draft rule, would do with
+// analyzed documents without changing them
+func (h *Handler) PreviewFiling(w http.ResponseWriter, r *http.Request) {
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	var req FilingPreviewRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	preview, err := h.service.PreviewFiling(r.Context(), tenantID, &req)
+	if err != nil {
+		writeFilingError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, preview)
+}
+
+func writeFilingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrFilingRuleNotFound):
+		writeError(w, http.StatusNotFound, "Filing rule not found")
+	case errors.Is(err, ErrFilingRuleExists):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrInvalidFilingRule), errors.Is(err, ErrUnknownDocumentType):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// GetConsent returns the tenant's consent to AI processing in force
+func (h *Handler) GetConsent(w http.ResponseWriter, r *http.Request) {
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	policy, err := h.service.ConsentPolicy(r.Context(), tenantID)
+	if err != nil {
+		writeConsentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, policy)
+}
+
+// UpdateConsent records a new version of the tenant's consent
+func (h *Handler) UpdateConsent(w http.ResponseWriter, r *http.Request) {
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	var req ConsentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	policy, err := h.service.UpdateConsent(r.Context(), tenantID, getUserID(r), &req)
+	if err != nil {
+		writeConsentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, policy)
+}
+
+// ListConsentVersions lists all versions of the tenant's consent
+func (h *Handler) ListConsentVersions(w http.ResponseWriter, r *http.Request) {
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	versions, err := h.service.ListConsentVersions(r.Context(), tenantID)
+	if err != nil {
+		writeConsentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"versions": versions})
+}
+
+// GetConsentOverview returns the consent overview for compliance audits
+func (h *Handler) GetConsentOverview(w http.ResponseWriter, r *http.Request) {
+	tenantID := getTenantID(r)
+	if tenantID == uuid.Nil {
+		writeError(w, http.StatusUnauthorized, "Missing tenant context")
+		return
+	}
+
+	overview, err := h.service.ConsentOverview(r.Context(), tenantID)
+	if err != nil {
+		writeConsentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, overview)
+}
+
+func writeConsentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidConsent):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrConsentConflict):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/timezone"
 )
 
 // NotificationService handles deadline reminders and notifications
@@ -13,6 +15,7 @@ type NotificationService struct {
 	repo          *Repository
 	emailSender   EmailSender
 	webhookSender WebhookSender
+	timezones     timezone.Source
 }
 
 // EmailSender interface for sending email notifications
@@ -34,6 +37,17 @@ func NewNotificationService(repo *Repository, email EmailSender, webhook Webhook
 	}
 }
 
+// SetTimezones sets where tenants' time zones come from; reminders count
+// days in the tenant's zone, Europe/Vienna without it
+func (n *NotificationService) SetTimezones(src timezone.Source) {
+	n.timezones = src
+}
+
+// today returns the tenant's current calendar day
+func (n *NotificationService) today(ctx context.Context, tenantID uuid.UUID) time.Time {
+	return timezone.Today(time.Now(), timezone.Of(ctx, n.timezones, tenantID))
+}
+
 // NotificationConfig holds notification configuration
 type NotificationConfig struct {
 	EmailEnabled       bool
@@ -71,10 +85,11 @@ type DeadlineNotification struct {
 // CheckAndSendReminders checks for upcoming deadlines and sends reminders
 func (n *NotificationService) CheckAndSendReminders(ctx context.Context, tenantID uuid.UUID, config NotificationConfig) ([]DeadlineNotification, error) {
 	var notifications []DeadlineNotification
+	today := n.today(ctx, tenantID)
 
 	for _, daysBefore := range config.ReminderDaysBefore {
 		// Get deadlines due in exactly daysBefore days
-		deadlines, err := n.getDeadlinesDueIn(ctx, tenantID, daysBefore)
+		deadlines, err := n.getDeadlinesDueIn(ctx, tenantID, today, daysBefore)
 		if err != nil {
 			continue
 		}
@@ -127,7 +142,7 @@ func (n *NotificationService) CheckAndSendReminders(ctx context.Context, tenantI
 	}
 
 	// Check for overdue deadlines
-	overdueDeadlines, err := n.getOverdueDeadlines(ctx, tenantID)
+	overdueDeadlines, err := n.getOverdueDeadlines(ctx, tenantID, today)
 	if err == nil {
 		for _, deadline := range overdueDeadlines {
 			notification := DeadlineNotification{
@@ -136,7 +151,7 @@ func (n *NotificationService) CheckAndSendReminders(ctx context.Context, tenantI
 				DeadlineID: deadline.ID,
 				DocumentID: deadline.DocumentID,
 				Type:       "overdue",
-				DaysUntil:  -int(time.Since(deadline.Date).Hours() / 24),
+				DaysUntil:  -daysBetween(deadline.Date, today),
 				Status:     "pending",
 				CreatedAt:  time.Now(),
 			}
@@ -168,20 +183,18 @@ func (n *NotificationService) CheckAndSendReminders(ctx context.Context, tenantI
 	return notifications, nil
 }
 
-// getDeadlinesDueIn returns deadlines due in exactly N days
-func (n *NotificationService) getDeadlinesDueIn(ctx context.Context, tenantID uuid.UUID, days int) ([]*Deadline, error) {
+// getDeadlinesDueIn returns deadlines due in exactly N days from today
+func (n *NotificationService) getDeadlinesDueIn(ctx context.Context, tenantID uuid.UUID, today time.Time, days int) ([]*Deadline, error) {
 	// Use the repository to get deadlines
-	allUpcoming, err := n.repo.GetUpcomingDeadlines(ctx, tenantID, days+1)
+	allUpcoming, err := n.repo.GetUpcomingDeadlines(ctx, tenantID, today, days)
 	if err != nil {
 		return nil, err
 	}
 
-	targetDate := time.Now().AddDate(0, 0, days).Truncate(24 * time.Hour)
 	var result []*Deadline
 
 	for _, d := range allUpcoming {
-		deadlineDate := d.Date.Truncate(24 * time.Hour)
-		if deadlineDate.Equal(targetDate) {
+		if daysBetween(today, d.Date) == days {
 			result = append(result, d)
 		}
 	}
@@ -189,8 +202,8 @@ func (n *NotificationService) getDeadlinesDueIn(ctx context.Context, tenantID uu
 	return result, nil
 }
 
-// getOverdueDeadlines returns unacknowledged overdue deadlines
-func (n *NotificationService) getOverdueDeadlines(ctx context.Context, tenantID uuid.UUID) ([]*Deadline, error) {
+// getOverdueDeadlines returns unacknowledged deadlines before today
+func (n *NotificationService) getOverdueDeadlines(ctx context.Context, tenantID uuid.UUID, today time.Time) ([]*Deadline, error) {
 	// Get all upcoming deadlines with a negative range to include past deadlines
 	// For now, query all and filter
 	query := `
@@ -198,13 +211,13 @@ func (n *NotificationService) getOverdueDeadlines(ctx context.Context, tenantID
 			description, source_text, confidence, is_hard, is_acknowledged, created_at, updated_at
 		FROM extracted_deadlines
 		WHERE tenant_id = $1
-			AND deadline_date < CURRENT_DATE
+			AND deadline_date < $2::date
 			AND is_acknowledged = FALSE
 		ORDER BY deadline_date DESC
 		LIMIT 50
 	`
 
-	rows, err := n.repo.db.Query(ctx, query, tenantID)
+	rows, err := n.repo.db.Query(ctx, query, tenantID, today)
 	if err != nil {
 		return nil, fmt.Errorf("get overdue deadlines: %w", err)
 	}
@@ -229,16 +242,16 @@ func (n *NotificationService) getOverdueDeadlines(ctx context.Context, tenantID
 
 // GenerateDailyDigest generates a daily digest of upcoming deadlines
 func (n *NotificationService) GenerateDailyDigest(ctx context.Context, tenantID uuid.UUID) (*DailyDigest, error) {
-	today := time.Now()
+	today := n.today(ctx, tenantID)
 
 	// Get deadlines for next 7 days
-	upcomingDeadlines, err := n.repo.GetUpcomingDeadlines(ctx, tenantID, 7)
+	upcomingDeadlines, err := n.repo.GetUpcomingDeadlines(ctx, tenantID, today, 7)
 	if err != nil {
 		return nil, fmt.Errorf("get upcoming deadlines: %w", err)
 	}
 
 	// Get overdue deadlines
-	overdueDeadlines, err := n.getOverdueDeadlines(ctx, tenantID)
+	overdueDeadlines, err := n.getOverdueDeadlines(ctx, tenantID, today)
 	if err != nil {
 		overdueDeadlines = []*Deadline{} // Non-fatal
 	}
@@ -263,7 +276,7 @@ func (n *NotificationService) GenerateDailyDigest(ctx context.Context, tenantID
 
 	// Categorize upcoming by urgency
 	for _, d := range upcomingDeadlines {
-		daysUntil := int(d.Date.Sub(today).Hours() / 24)
+		daysUntil := daysBetween(today, d.Date)
 		if daysUntil <= 1 {
 			digest.UrgentCount++
 		} else if daysUntil <= 3 {
@@ -298,3 +311,9 @@ type ScheduledReminder struct {
 	Status     string    `json:"status"`  // scheduled, sent, cancelled
 	CreatedAt  time.Time `json:"created_at"`
 }
+
+// daysBetween counts the calendar days from one date to another; both are
+// dates as scanned from DATE columns or returned by timezone.Today
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
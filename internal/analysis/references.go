@@ -0,0 +1,154 @@
+package analysis
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"austrian-business-infrastructure/internal/document"
+)
+
+// ExtractedReference is a mention of an invoice number or an UVA period in
+// a document's text
+type ExtractedReference struct {
+	Kind       string  `json:"kind"` // invoice_number, uva_period
+	Value      string  `json:"value"`
+	Year       int     `json:"year,omitempty"`
+	Month      int     `json:"month,omitempty"`
+	Quarter    int     `json:"quarter,omitempty"`
+	SourceText string  `json:"source_text"`
+	Confidence float64 `json:"confidence"`
+}
+
+var (
+	invoiceNumberPattern = regexp.MustCompile(`(?i)\b(?:rechnungs-?(?:nummer|nr\.?)|rechnung\s+(?:nr\.?|nummer)|re-?nr\.?|invoice\s+(?:no\.?|number))\s*[:#]?\s*([A-Z0-9][A-Z0-9\-/._]{2,39})`)
+	uvaContextPattern    = regexp.MustCompile(`(?i)umsatzsteuervoranmeldung|voranmeldungszeitraum|voranmeldung|\bUVA\b`)
+	monthPeriodPattern   = regexp.MustCompile(`\b(0?[1-9]|1[0-2])\s*/\s*(20\d{2})\b`)
+	monthNamePattern     = regexp.MustCompile(`(?i)\b(jänner|januar|februar|märz|april|mai|juni|juli|august|september|oktober|november|dezember)\s+(20\d{2})\b`)
+	quarterPattern       = regexp.MustCompile(`(?i)\b(?:Q\s*([1-4])\s*/?\s*(20\d{2})|([1-4])\.\s*(?:Quartal|Vierteljahr)\s+(20\d{2}))\b`)
+	digitPattern         = regexp.MustCompile(`\d`)
+)
+
+var germanMonths = map[string]int{
+	"jänner": 1, "januar": 1, "februar": 2, "märz": 3, "april": 4, "mai": 5, "juni": 6,
+	"juli": 7, "august": 8, "september": 9, "oktober": 10, "november": 11, "dezember": 12,
+}
+
+// uvaPeriodWindow is how far after an UVA keyword the period is looked for
+const uvaPeriodWindow = 120
+
+// ExtractReferences finds invoice numbers and UVA periods in document text.
+// It only uses patterns, so it costs nothing and runs for every analysis.
+func ExtractReferences(text string) []ExtractedReference {
+	var refs []ExtractedReference
+	seen := make(map[string]bool)
+	add := func(ref ExtractedReference) {
+		key := ref.Kind + ":" + strings.ToUpper(ref.Value)
+		if !seen[key] {
+			seen[key] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, m := range invoiceNumberPattern.FindAllStringSubmatchIndex(text, -1) {
+		number := strings.TrimRight(text[m[2]:m[3]], ".-/_")
+		if len(number) < 3 || !digitPattern.MatchString(number) {
+			continue
+		}
+		add(ExtractedReference{
+			Kind:       document.ReferenceInvoiceNumber,
+			Value:      number,
+			SourceText: strings.TrimSpace(text[m[0]:m[1]]),
+			Confidence: 0.9,
+		})
+	}
+
+	for _, m := range uvaContextPattern.FindAllStringIndex(text, -1) {
+		end := min(m[1]+uvaPeriodWindow, len(text))
+		if ref, ok := uvaPeriod(text[m[1]:end]); ok {
+			ref.SourceText = strings.TrimSpace(text[m[0]:end])
+			add(ref)
+		}
+	}
+	return refs
+}
+
+// uvaPeriod reads the first month or quarter in the text following an UVA
+// keyword
+func uvaPeriod(window string) (ExtractedReference, bool) {
+	type candidate struct {
+		at                   int
+		year, month, quarter int
+	}
+	var found []candidate
+	if m := monthPeriodPattern.FindStringSubmatchIndex(window); m != nil {
+		month, _ := strconv.Atoi(window[m[2]:m[3]])
+		year, _ := strconv.Atoi(window[m[4]:m[5]])
+		found = append(found, candidate{at: m[0], year: year, month: month})
+	}
+	if m := monthNamePattern.FindStringSubmatchIndex(window); m != nil {
+		year, _ := strconv.Atoi(window[m[4]:m[5]])
+		found = append(found, candidate{at: m[0], year: year, month: germanMonths[strings.ToLower(window[m[2]:m[3]])]})
+	}
+	if m := quarterPattern.FindStringSubmatchIndex(window); m != nil {
+		c := candidate{at: m[0]}
+		if m[2] >= 0 {
+			c.quarter, _ = strconv.Atoi(window[m[2]:m[3]])
+			c.year, _ = strconv.Atoi(window[m[4]:m[5]])
+		} else {
+			c.quarter, _ = strconv.Atoi(window[m[6]:m[7]])
+			c.year, _ = strconv.Atoi(window[m[8]:m[9]])
+		}
+		found = append(found, c)
+	}
+	if len(found) == 0 {
+		return ExtractedReference{}, false
+	}
+
+	first := found[0]
+	for _, c := range found[1:] {
+		if c.at < first.at {
+			first = c
+		}
+	}
+	ref := ExtractedReference{
+		Kind:       document.ReferenceUVAPeriod,
+		Year:       first.year,
+		Month:      first.month,
+		Quarter:    first.quarter,
+		Confidence: 0.8,
+	}
+	if first.month > 0 {
+		ref.Value = strconv.Itoa(first.month) + "/" + strconv.Itoa(first.year)
+	} else {
+		ref.Value = "Q" + strconv.Itoa(first.quarter) + "/" + strconv.Itoa(first.year)
+	}
+	return ref, true
+}
+
+// referencesToLink turns extracted references into links; the document type
+// decides the relation, e.g. a Mahnung is a reminder for the invoice it
+// names and a Bescheid the assessment of the UVA period
+func referencesToLink(refs []ExtractedReference, docType DocumentType) []document.Reference {
+	links := make([]document.Reference, 0, len(refs))
+	for _, ref := range refs {
+		relation := document.RelationReferences
+		switch {
+		case ref.Kind == document.ReferenceInvoiceNumber && (docType == DocTypeMahnung || docType == DocTypeZahlungsbefehl):
+			relation = document.RelationReminderFor
+		case ref.Kind == document.ReferenceUVAPeriod && docType == DocTypeBescheid:
+			relation = document.RelationAssessmentOf
+		}
+		links = append(links, document.Reference{
+			Kind:       ref.Kind,
+			Value:      ref.Value,
+			Year:       ref.Year,
+			Month:      ref.Month,
+			Quarter:    ref.Quarter,
+			Relation:   relation,
+			Confidence: ref.Confidence,
+			SourceText: ref.SourceText,
+		})
+	}
+	return links
+}
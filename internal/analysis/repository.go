@@ -5,11 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/pkg/database"
+	"austrian-business-infrastructure/pkg/money"
 )
 
 // Repository errors
@@ -23,34 +28,37 @@ var (
 
 // Analysis represents a document analysis record
 type Analysis struct {
-	ID                      uuid.UUID              `json:"id"`
-	DocumentID              uuid.UUID              `json:"document_id"`
-	TenantID                uuid.UUID              `json:"tenant_id"`
-	Status                  string                 `json:"status"`
-	DocumentType            string                 `json:"document_type,omitempty"`
-	DocumentSubtype         string                 `json:"document_subtype,omitempty"`
-	ClassificationConfidence float64               `json:"classification_confidence,omitempty"`
-	IsScanned               bool                   `json:"is_scanned"`
-	OCRProvider             string                 `json:"ocr_provider,omitempty"`
-	OCRConfidence           float64                `json:"ocr_confidence,omitempty"`
-	Summary                 string                 `json:"summary,omitempty"`
-	KeyPoints               []string               `json:"key_points,omitempty"`
-	ExtractedText           string                 `json:"extracted_text,omitempty"`
-	TextLength              int                    `json:"text_length"`
-	PageCount               int                    `json:"page_count"`
-	Language                string                 `json:"language,omitempty"`
-	AIModel                 string                 `json:"ai_model,omitempty"`
-	PromptVersion           string                 `json:"prompt_version,omitempty"`
-	TokensUsed              int                    `json:"tokens_used"`
-	ProcessingTimeMs        int                    `json:"processing_time_ms"`
-	EstimatedCost           float64                `json:"estimated_cost"`
-	ErrorMessage            string                 `json:"error_message,omitempty"`
-	ErrorCode               string                 `json:"error_code,omitempty"`
-	RetryCount              int                    `json:"retry_count"`
-	Metadata                map[string]interface{} `json:"metadata,omitempty"`
-	CreatedAt               time.Time              `json:"created_at"`
-	UpdatedAt               time.Time              `json:"updated_at"`
-	CompletedAt             *time.Time             `json:"completed_at,omitempty"`
+	ID                       uuid.UUID              `json:"id"`
+	DocumentID               uuid.UUID              `json:"document_id"`
+	TenantID                 uuid.UUID              `json:"tenant_id"`
+	Status                   string                 `json:"status"`
+	DocumentType             string                 `json:"document_type,omitempty"`
+	DocumentSubtype          string                 `json:"document_subtype,omitempty"`
+	ClassificationConfidence float64                `json:"classification_confidence,omitempty"`
+	IsScanned                bool                   `json:"is_scanned"`
+	OCRProvider              string                 `json:"ocr_provider,omitempty"`
+	OCRConfidence            float64                `json:"ocr_confidence,omitempty"`
+	Summary                  string                 `json:"summary,omitempty"`
+	KeyPoints                []string               `json:"key_points,omitempty"`
+	ExtractedText            string                 `json:"extracted_text,omitempty"`
+	TextCompacted            bool                   `json:"text_compacted,omitempty"` // ExtractedText is a preview in lists
+	TextLength               int                    `json:"text_length"`
+	PageCount                int                    `json:"page_count"`
+	Language                 string                 `json:"language,omitempty"`
+	AIModel                  string                 `json:"ai_model,omitempty"`
+	PromptVersion            string                 `json:"prompt_version,omitempty"`
+	TokensUsed               int                    `json:"tokens_used"`
+	ProcessingTimeMs         int                    `json:"processing_time_ms"`
+	EstimatedCost            money.Money            `json:"estimated_cost"`
+	ErrorMessage             string                 `json:"error_message,omitempty"`
+	ErrorCode                string                 `json:"error_code,omitempty"`
+	RetryCount               int                    `json:"retry_count"`
+	Metadata                 map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt                time.Time              `json:"created_at"`
+	UpdatedAt                time.Time              `json:"updated_at"`
+	CompletedAt              *time.Time             `json:"completed_at,omitempty"`
+
+	textRestored bool // the full text of a compacted analysis was loaded
 }
 
 // Deadline represents an extracted deadline
@@ -72,25 +80,31 @@ type Deadline struct {
 	Notes           string     `json:"notes,omitempty"`
 	CreatedAt       time.Time  `json:"created_at"`
 	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// History holds the user corrections, newest first, in detail responses
+	History []*Correction `json:"history,omitempty"`
 }
 
 // Amount represents an extracted monetary amount
 type Amount struct {
-	ID              uuid.UUID  `json:"id"`
-	AnalysisID      uuid.UUID  `json:"analysis_id"`
-	DocumentID      uuid.UUID  `json:"document_id"`
-	TenantID        uuid.UUID  `json:"tenant_id"`
-	AmountType      string     `json:"amount_type"`
-	Amount          float64    `json:"amount"`
-	Currency        string     `json:"currency"`
-	Description     string     `json:"description"`
-	SourceText      string     `json:"source_text,omitempty"`
-	Confidence      float64    `json:"confidence"`
-	DueDate         *time.Time `json:"due_date,omitempty"`
-	CorrectedByUser bool       `json:"corrected_by_user"`
-	Notes           string     `json:"notes,omitempty"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
+	ID              uuid.UUID   `json:"id"`
+	AnalysisID      uuid.UUID   `json:"analysis_id"`
+	DocumentID      uuid.UUID   `json:"document_id"`
+	TenantID        uuid.UUID   `json:"tenant_id"`
+	AmountType      string      `json:"amount_type"`
+	Amount          money.Money `json:"amount"`
+	Currency        string      `json:"currency"`
+	Description     string      `json:"description"`
+	SourceText      string      `json:"source_text,omitempty"`
+	Confidence      float64     `json:"confidence"`
+	DueDate         *time.Time  `json:"due_date,omitempty"`
+	CorrectedByUser bool        `json:"corrected_by_user"`
+	Notes           string      `json:"notes,omitempty"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+
+	// History holds the user corrections, newest first, in detail responses
+	History []*Correction `json:"history,omitempty"`
 }
 
 // Priority represents action item priority
@@ -138,18 +152,29 @@ type Suggestion struct {
 
 // Repository handles analysis database operations
 type Repository struct {
-	db *pgxpool.Pool
+	db     *pgxpool.Pool
+	module database.Module
 }
 
 // NewRepository creates a new analysis repository
 func NewRepository(db *pgxpool.Pool) *Repository {
-	return &Repository{db: db}
+	return &Repository{db: db, module: database.NewModule("analysis")}
 }
 
 // CreateAnalysis inserts a new analysis record
 func (r *Repository) CreateAnalysis(ctx context.Context, a *Analysis) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	keyPointsJSON, _ := json.Marshal(a.KeyPoints)
 	metadataJSON, _ := json.Marshal(a.Metadata)
+	compacted := shouldCompact(a.ExtractedText)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
 	query := `
 		INSERT INTO document_analyses (
@@ -157,156 +182,195 @@ func (r *Repository) CreateAnalysis(ctx context.Context, a *Analysis) error {
 			classification_confidence, is_scanned, ocr_provider, ocr_confidence,
 			summary, key_points, extracted_text, text_length, page_count,
 			language, ai_model, prompt_version, tokens_used, processing_time_ms,
-			estimated_cost, error_message, error_code, retry_count, metadata
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
+			cost_cents, error_message, error_code, retry_count, metadata, text_compacted
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25)
 		RETURNING id, created_at, updated_at
 	`
 
-	return r.db.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		a.DocumentID, a.TenantID, a.Status, a.DocumentType, a.DocumentSubtype,
 		a.ClassificationConfidence, a.IsScanned, a.OCRProvider, a.OCRConfidence,
-		a.Summary, keyPointsJSON, a.ExtractedText, a.TextLength, a.PageCount,
+		a.Summary, keyPointsJSON, storedText(a.ExtractedText, compacted), a.TextLength, a.PageCount,
 		a.Language, a.AIModel, a.PromptVersion, a.TokensUsed, a.ProcessingTimeMs,
-		a.EstimatedCost, a.ErrorMessage, a.ErrorCode, a.RetryCount, metadataJSON,
+		a.EstimatedCost, a.ErrorMessage, a.ErrorCode, a.RetryCount, metadataJSON, compacted,
 	).Scan(&a.ID, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("create analysis: %w", err)
+	}
+
+	if compacted {
+		if _, err := saveFullText(ctx, tx, a, true); err != nil {
+			return fmt.Errorf("save compacted text: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	a.TextCompacted = compacted
+	a.textRestored = compacted
+	return nil
 }
 
-// GetAnalysisByID retrieves an analysis by ID
-func (r *Repository) GetAnalysisByID(ctx context.Context, id uuid.UUID) (*Analysis, error) {
-	query := `
+// analysisSelect selects analyses
+const analysisSelect = `
 		SELECT id, document_id, tenant_id, status, document_type, document_subtype,
 			classification_confidence, is_scanned, ocr_provider, ocr_confidence,
-			summary, key_points, extracted_text, text_length, page_count,
+			summary, key_points, extracted_text, text_compacted, text_length, page_count,
 			language, ai_model, prompt_version, tokens_used, processing_time_ms,
-			estimated_cost, error_message, error_code, retry_count, metadata,
+			cost_cents, error_message, error_code, retry_count, metadata,
 			created_at, updated_at, completed_at
 		FROM document_analyses
-		WHERE id = $1
-	`
+`
+
+// analysisListSelect is analysisSelect without the extracted text, for
+// lists that leave it out (?fields=-extracted_text)
+var analysisListSelect = strings.Replace(analysisSelect, " extracted_text,", " ''::text AS extracted_text,", 1)
 
+// scanAnalysis scans a row of analysisSelect
+func scanAnalysis(row pgx.Row) (*Analysis, error) {
 	a := &Analysis{}
 	var keyPointsJSON, metadataJSON []byte
 
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := row.Scan(
 		&a.ID, &a.DocumentID, &a.TenantID, &a.Status, &a.DocumentType, &a.DocumentSubtype,
 		&a.ClassificationConfidence, &a.IsScanned, &a.OCRProvider, &a.OCRConfidence,
-		&a.Summary, &keyPointsJSON, &a.ExtractedText, &a.TextLength, &a.PageCount,
+		&a.Summary, &keyPointsJSON, &a.ExtractedText, &a.TextCompacted, &a.TextLength, &a.PageCount,
 		&a.Language, &a.AIModel, &a.PromptVersion, &a.TokensUsed, &a.ProcessingTimeMs,
 		&a.EstimatedCost, &a.ErrorMessage, &a.ErrorCode, &a.RetryCount, &metadataJSON,
 		&a.CreatedAt, &a.UpdatedAt, &a.CompletedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal(keyPointsJSON, &a.KeyPoints)
+	json.Unmarshal(metadataJSON, &a.Metadata)
+	return a, nil
+}
 
+// GetAnalysisByID retrieves an analysis by ID
+func (r *Repository) GetAnalysisByID(ctx context.Context, id uuid.UUID) (*Analysis, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
+	a, err := scanAnalysis(r.db.QueryRow(ctx, analysisSelect+` WHERE id = $1`, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrAnalysisNotFound
 		}
 		return nil, fmt.Errorf("get analysis: %w", err)
 	}
-
-	json.Unmarshal(keyPointsJSON, &a.KeyPoints)
-	json.Unmarshal(metadataJSON, &a.Metadata)
+	if err := r.restoreText(ctx, a); err != nil {
+		return nil, err
+	}
 
 	return a, nil
 }
 
 // GetAnalysisByDocumentID retrieves the latest analysis for a document
 func (r *Repository) GetAnalysisByDocumentID(ctx context.Context, documentID uuid.UUID) (*Analysis, error) {
-	query := `
-		SELECT id, document_id, tenant_id, status, document_type, document_subtype,
-			classification_confidence, is_scanned, ocr_provider, ocr_confidence,
-			summary, key_points, extracted_text, text_length, page_count,
-			language, ai_model, prompt_version, tokens_used, processing_time_ms,
-			estimated_cost, error_message, error_code, retry_count, metadata,
-			created_at, updated_at, completed_at
-		FROM document_analyses
-		WHERE document_id = $1
-		ORDER BY created_at DESC
-		LIMIT 1
-	`
-
-	a := &Analysis{}
-	var keyPointsJSON, metadataJSON []byte
-
-	err := r.db.QueryRow(ctx, query, documentID).Scan(
-		&a.ID, &a.DocumentID, &a.TenantID, &a.Status, &a.DocumentType, &a.DocumentSubtype,
-		&a.ClassificationConfidence, &a.IsScanned, &a.OCRProvider, &a.OCRConfidence,
-		&a.Summary, &keyPointsJSON, &a.ExtractedText, &a.TextLength, &a.PageCount,
-		&a.Language, &a.AIModel, &a.PromptVersion, &a.TokensUsed, &a.ProcessingTimeMs,
-		&a.EstimatedCost, &a.ErrorMessage, &a.ErrorCode, &a.RetryCount, &metadataJSON,
-		&a.CreatedAt, &a.UpdatedAt, &a.CompletedAt,
-	)
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
 
+	query := analysisSelect + ` WHERE document_id = $1 ORDER BY created_at DESC LIMIT 1`
+	a, err := scanAnalysis(r.db.QueryRow(ctx, query, documentID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrAnalysisNotFound
 		}
 		return nil, fmt.Errorf("get analysis by document: %w", err)
 	}
-
-	json.Unmarshal(keyPointsJSON, &a.KeyPoints)
-	json.Unmarshal(metadataJSON, &a.Metadata)
+	if err := r.restoreText(ctx, a); err != nil {
+		return nil, err
+	}
 
 	return a, nil
 }
 
-// UpdateAnalysis updates an analysis record
+// UpdateAnalysis updates an analysis record. The text of a compacted
+// analysis loaded without its full text (from a list) is left as it is.
 func (r *Repository) UpdateAnalysis(ctx context.Context, a *Analysis) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	keyPointsJSON, _ := json.Marshal(a.KeyPoints)
 	metadataJSON, _ := json.Marshal(a.Metadata)
+	keepText := a.TextCompacted && !a.textRestored
+	compacted := a.TextCompacted
+	if !keepText {
+		compacted = shouldCompact(a.ExtractedText)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
 	query := `
 		UPDATE document_analyses SET
 			status = $2, document_type = $3, document_subtype = $4,
 			classification_confidence = $5, is_scanned = $6, ocr_provider = $7,
 			ocr_confidence = $8, summary = $9, key_points = $10,
-			extracted_text = $11, text_length = $12, page_count = $13,
+			extracted_text = CASE WHEN $25 THEN extracted_text ELSE $11 END,
+			text_compacted = $26, text_length = $12, page_count = $13,
 			language = $14, ai_model = $15, prompt_version = $16,
-			tokens_used = $17, processing_time_ms = $18, estimated_cost = $19,
+			tokens_used = $17, processing_time_ms = $18, cost_cents = $19,
 			error_message = $20, error_code = $21, retry_count = $22,
 			metadata = $23, updated_at = NOW(), completed_at = $24
 		WHERE id = $1
 	`
 
-	_, err := r.db.Exec(ctx, query,
+	_, err = tx.Exec(ctx, query,
 		a.ID, a.Status, a.DocumentType, a.DocumentSubtype,
 		a.ClassificationConfidence, a.IsScanned, a.OCRProvider,
 		a.OCRConfidence, a.Summary, keyPointsJSON,
-		a.ExtractedText, a.TextLength, a.PageCount,
+		storedText(a.ExtractedText, compacted), a.TextLength, a.PageCount,
 		a.Language, a.AIModel, a.PromptVersion,
 		a.TokensUsed, a.ProcessingTimeMs, a.EstimatedCost,
 		a.ErrorMessage, a.ErrorCode, a.RetryCount,
-		metadataJSON, a.CompletedAt,
+		metadataJSON, a.CompletedAt, keepText, compacted,
 	)
-
 	if err != nil {
 		return fmt.Errorf("update analysis: %w", err)
 	}
 
+	if !keepText {
+		if _, err := saveFullText(ctx, tx, a, compacted); err != nil {
+			return fmt.Errorf("save compacted text: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	a.TextCompacted = compacted
+	a.textRestored = a.textRestored || (compacted && !keepText)
 	return nil
 }
 
-// ListAnalyses returns analyses for a tenant
-func (r *Repository) ListAnalyses(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*Analysis, int, error) {
-	countQuery := `SELECT COUNT(*) FROM document_analyses WHERE tenant_id = $1`
+// ListAnalyses returns analyses for a tenant. Without withText the
+// extracted text is not loaded.
+func (r *Repository) ListAnalyses(ctx context.Context, tenantID uuid.UUID, limit, offset int, withText bool) ([]*Analysis, int, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
+	access, countArgs := document.RowAccessCondition(ctx, "document_analyses", []interface{}{tenantID})
+	countQuery := `SELECT COUNT(*) FROM document_analyses WHERE tenant_id = $1` + access
 	var total int
-	if err := r.db.QueryRow(ctx, countQuery, tenantID).Scan(&total); err != nil {
+	if err := r.db.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("count analyses: %w", err)
 	}
 
-	query := `
-		SELECT id, document_id, tenant_id, status, document_type, document_subtype,
-			classification_confidence, is_scanned, ocr_provider, ocr_confidence,
-			summary, key_points, extracted_text, text_length, page_count,
-			language, ai_model, prompt_version, tokens_used, processing_time_ms,
-			estimated_cost, error_message, error_code, retry_count, metadata,
-			created_at, updated_at, completed_at
-		FROM document_analyses
-		WHERE tenant_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`
+	selectQuery := analysisSelect
+	if !withText {
+		selectQuery = analysisListSelect
+	}
+	access, args := document.RowAccessCondition(ctx, "document_analyses", []interface{}{tenantID, limit, offset})
+	query := selectQuery + ` WHERE tenant_id = $1` + access + ` ORDER BY created_at DESC LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.Query(ctx, query, tenantID, limit, offset)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("list analyses: %w", err)
 	}
@@ -314,23 +378,10 @@ func (r *Repository) ListAnalyses(ctx context.Context, tenantID uuid.UUID, limit
 
 	var analyses []*Analysis
 	for rows.Next() {
-		a := &Analysis{}
-		var keyPointsJSON, metadataJSON []byte
-
-		err := rows.Scan(
-			&a.ID, &a.DocumentID, &a.TenantID, &a.Status, &a.DocumentType, &a.DocumentSubtype,
-			&a.ClassificationConfidence, &a.IsScanned, &a.OCRProvider, &a.OCRConfidence,
-			&a.Summary, &keyPointsJSON, &a.ExtractedText, &a.TextLength, &a.PageCount,
-			&a.Language, &a.AIModel, &a.PromptVersion, &a.TokensUsed, &a.ProcessingTimeMs,
-			&a.EstimatedCost, &a.ErrorMessage, &a.ErrorCode, &a.RetryCount, &metadataJSON,
-			&a.CreatedAt, &a.UpdatedAt, &a.CompletedAt,
-		)
+		a, err := scanAnalysis(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("scan analysis: %w", err)
 		}
-
-		json.Unmarshal(keyPointsJSON, &a.KeyPoints)
-		json.Unmarshal(metadataJSON, &a.Metadata)
 		analyses = append(analyses, a)
 	}
 
@@ -339,6 +390,9 @@ func (r *Repository) ListAnalyses(ctx context.Context, tenantID uuid.UUID, limit
 
 // CreateDeadline inserts a new deadline
 func (r *Repository) CreateDeadline(ctx context.Context, d *Deadline) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO extracted_deadlines (
 			analysis_id, document_id, tenant_id, deadline_type, deadline_date,
@@ -353,22 +407,15 @@ func (r *Repository) CreateDeadline(ctx context.Context, d *Deadline) error {
 	).Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt)
 }
 
-// GetDeadlinesByDocument returns all deadlines for a document
-func (r *Repository) GetDeadlinesByDocument(ctx context.Context, documentID uuid.UUID) ([]*Deadline, error) {
-	query := `
+// deadlineSelect selects deadlines
+const deadlineSelect = `
 		SELECT id, analysis_id, document_id, tenant_id, deadline_type, deadline_date,
 			description, source_text, confidence, is_hard, acknowledged, created_at, updated_at
 		FROM extracted_deadlines
-		WHERE document_id = $1
-		ORDER BY deadline_date ASC
-	`
-
-	rows, err := r.db.Query(ctx, query, documentID)
-	if err != nil {
-		return nil, fmt.Errorf("get deadlines: %w", err)
-	}
-	defer rows.Close()
+`
 
+// scanDeadlines scans the rows of deadlineSelect
+func scanDeadlines(rows pgx.Rows) ([]*Deadline, error) {
 	var deadlines []*Deadline
 	for rows.Next() {
 		d := &Deadline{}
@@ -382,48 +429,53 @@ func (r *Repository) GetDeadlinesByDocument(ctx context.Context, documentID uuid
 		}
 		deadlines = append(deadlines, d)
 	}
+	return deadlines, rows.Err()
+}
+
+// GetDeadlinesByDocument returns all deadlines for a document
+func (r *Repository) GetDeadlinesByDocument(ctx context.Context, documentID uuid.UUID) ([]*Deadline, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
+	access, args := document.RowAccessCondition(ctx, "extracted_deadlines", []interface{}{documentID})
+	rows, err := r.db.Query(ctx, deadlineSelect+` WHERE document_id = $1`+access+` ORDER BY deadline_date ASC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get deadlines: %w", err)
+	}
+	defer rows.Close()
 
-	return deadlines, nil
+	return scanDeadlines(rows)
 }
 
-// GetUpcomingDeadlines returns upcoming deadlines for a tenant
-func (r *Repository) GetUpcomingDeadlines(ctx context.Context, tenantID uuid.UUID, days int) ([]*Deadline, error) {
-	query := `
-		SELECT id, analysis_id, document_id, tenant_id, deadline_type, deadline_date,
-			description, source_text, confidence, is_hard, acknowledged, created_at, updated_at
-		FROM extracted_deadlines
+// GetUpcomingDeadlines returns the deadlines of a tenant due from today
+// through today + days. today is the tenant's calendar day (timezone.Today).
+func (r *Repository) GetUpcomingDeadlines(ctx context.Context, tenantID uuid.UUID, today time.Time, days int) ([]*Deadline, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
+	access, args := document.RowAccessCondition(ctx, "extracted_deadlines", []interface{}{tenantID, today, days})
+	query := deadlineSelect + `
 		WHERE tenant_id = $1
-			AND deadline_date >= CURRENT_DATE
-			AND deadline_date <= CURRENT_DATE + $2 * INTERVAL '1 day'
-			AND acknowledged = FALSE
+			AND deadline_date >= $2::date
+			AND deadline_date <= $2::date + $3::int
+			AND acknowledged = FALSE` + access + `
 		ORDER BY deadline_date ASC
 	`
 
-	rows, err := r.db.Query(ctx, query, tenantID, days)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("get upcoming deadlines: %w", err)
 	}
 	defer rows.Close()
 
-	var deadlines []*Deadline
-	for rows.Next() {
-		d := &Deadline{}
-		err := rows.Scan(
-			&d.ID, &d.AnalysisID, &d.DocumentID, &d.TenantID, &d.DeadlineType, &d.Date,
-			&d.Description, &d.SourceText, &d.Confidence, &d.IsHard, &d.IsAcknowledged,
-			&d.CreatedAt, &d.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("scan deadline: %w", err)
-		}
-		deadlines = append(deadlines, d)
-	}
-
-	return deadlines, nil
+	return scanDeadlines(rows)
 }
 
 // AcknowledgeDeadline marks a deadline as acknowledged
 func (r *Repository) AcknowledgeDeadline(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `UPDATE extracted_deadlines SET acknowledged = TRUE, updated_at = NOW() WHERE id = $1`
 	result, err := r.db.Exec(ctx, query, id)
 	if err != nil {
@@ -437,9 +489,12 @@ func (r *Repository) AcknowledgeDeadline(ctx context.Context, id uuid.UUID) erro
 
 // CreateAmount inserts a new amount
 func (r *Repository) CreateAmount(ctx context.Context, a *Amount) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO extracted_amounts (
-			analysis_id, document_id, tenant_id, amount_type, amount, currency,
+			analysis_id, document_id, tenant_id, amount_type, amount_cents, currency,
 			description, source_text, confidence, due_date
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at
@@ -451,24 +506,17 @@ func (r *Repository) CreateAmount(ctx context.Context, a *Amount) error {
 	).Scan(&a.ID, &a.CreatedAt)
 }
 
-// GetAmountsByDocument returns all amounts for a document
-func (r *Repository) GetAmountsByDocument(ctx context.Context, documentID uuid.UUID) ([]*Amount, error) {
-	query := `
-		SELECT id, analysis_id, document_id, tenant_id, amount_type, amount, currency,
+// amountSelect selects amounts
+const amountSelect = `
+		SELECT id, analysis_id, document_id, tenant_id, amount_type, amount_cents, currency,
 			description, source_text, confidence, due_date,
 			COALESCE(corrected_by_user, false), COALESCE(notes, ''),
 			created_at, COALESCE(updated_at, created_at)
 		FROM extracted_amounts
-		WHERE document_id = $1
-		ORDER BY created_at DESC
-	`
-
-	rows, err := r.db.Query(ctx, query, documentID)
-	if err != nil {
-		return nil, fmt.Errorf("get amounts: %w", err)
-	}
-	defer rows.Close()
+`
 
+// scanAmounts scans the rows of amountSelect
+func scanAmounts(rows pgx.Rows) ([]*Amount, error) {
 	var amounts []*Amount
 	for rows.Next() {
 		a := &Amount{}
@@ -480,16 +528,34 @@ func (r *Repository) GetAmountsByDocument(ctx context.Context, documentID uuid.U
 		if err != nil {
 			return nil, fmt.Errorf("scan amount: %w", err)
 		}
+		a.Amount.Currency = a.Currency
 		amounts = append(amounts, a)
 	}
+	return amounts, rows.Err()
+}
+
+// GetAmountsByDocument returns all amounts for a document
+func (r *Repository) GetAmountsByDocument(ctx context.Context, documentID uuid.UUID) ([]*Amount, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
+	access, args := document.RowAccessCondition(ctx, "extracted_amounts", []interface{}{documentID})
+	rows, err := r.db.Query(ctx, amountSelect+` WHERE document_id = $1`+access+` ORDER BY created_at DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get amounts: %w", err)
+	}
+	defer rows.Close()
 
-	return amounts, nil
+	return scanAmounts(rows)
 }
 
 // GetAmountByID returns a single amount by ID
 func (r *Repository) GetAmountByID(ctx context.Context, id uuid.UUID) (*Amount, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, analysis_id, document_id, tenant_id, amount_type, amount, currency,
+		SELECT id, analysis_id, document_id, tenant_id, amount_type, amount_cents, currency,
 			description, source_text, confidence, due_date,
 			COALESCE(corrected_by_user, false), COALESCE(notes, ''),
 			created_at, COALESCE(updated_at, created_at)
@@ -509,15 +575,19 @@ func (r *Repository) GetAmountByID(ctx context.Context, id uuid.UUID) (*Amount,
 		}
 		return nil, fmt.Errorf("get amount: %w", err)
 	}
+	a.Amount.Currency = a.Currency
 
 	return a, nil
 }
 
 // UpdateAmount updates an amount
 func (r *Repository) UpdateAmount(ctx context.Context, a *Amount) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE extracted_amounts SET
-			amount_type = $2, amount = $3, currency = $4, description = $5,
+			amount_type = $2, amount_cents = $3, currency = $4, description = $5,
 			due_date = $6, corrected_by_user = $7, notes = $8, updated_at = NOW()
 		WHERE id = $1
 	`
@@ -539,6 +609,9 @@ func (r *Repository) UpdateAmount(ctx context.Context, a *Amount) error {
 
 // DeleteAmount deletes an amount
 func (r *Repository) DeleteAmount(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM extracted_amounts WHERE id = $1`
 	result, err := r.db.Exec(ctx, query, id)
 	if err != nil {
@@ -554,6 +627,9 @@ func (r *Repository) DeleteAmount(ctx context.Context, id uuid.UUID) error {
 
 // CreateActionItem inserts a new action item
 func (r *Repository) CreateActionItem(ctx context.Context, a *ActionItem) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO action_items (
 			analysis_id, document_id, tenant_id, title, description, priority,
@@ -568,23 +644,16 @@ func (r *Repository) CreateActionItem(ctx context.Context, a *ActionItem) error
 	).Scan(&a.ID, &a.CreatedAt, &a.UpdatedAt)
 }
 
-// GetActionItemsByDocument returns all action items for a document
-func (r *Repository) GetActionItemsByDocument(ctx context.Context, documentID uuid.UUID) ([]*ActionItem, error) {
-	query := `
+// actionItemSelect selects action items
+const actionItemSelect = `
 		SELECT id, analysis_id, document_id, tenant_id, title, description, priority,
 			category, status, due_date, assigned_to, source_text, confidence,
 			completed_at, created_at, updated_at
 		FROM action_items
-		WHERE document_id = $1
-		ORDER BY priority ASC, created_at DESC
-	`
-
-	rows, err := r.db.Query(ctx, query, documentID)
-	if err != nil {
-		return nil, fmt.Errorf("get action items: %w", err)
-	}
-	defer rows.Close()
+`
 
+// scanActionItems scans the rows of actionItemSelect
+func scanActionItems(rows pgx.Rows) ([]*ActionItem, error) {
 	var items []*ActionItem
 	for rows.Next() {
 		a := &ActionItem{}
@@ -598,46 +667,46 @@ func (r *Repository) GetActionItemsByDocument(ctx context.Context, documentID uu
 		}
 		items = append(items, a)
 	}
+	return items, rows.Err()
+}
+
+// GetActionItemsByDocument returns all action items for a document
+func (r *Repository) GetActionItemsByDocument(ctx context.Context, documentID uuid.UUID) ([]*ActionItem, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
+	access, args := document.RowAccessCondition(ctx, "action_items", []interface{}{documentID})
+	rows, err := r.db.Query(ctx, actionItemSelect+` WHERE document_id = $1`+access+` ORDER BY priority ASC, created_at DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get action items: %w", err)
+	}
+	defer rows.Close()
 
-	return items, nil
+	return scanActionItems(rows)
 }
 
 // GetPendingActionItems returns pending action items for a tenant
 func (r *Repository) GetPendingActionItems(ctx context.Context, tenantID uuid.UUID) ([]*ActionItem, error) {
-	query := `
-		SELECT id, analysis_id, document_id, tenant_id, title, description, priority,
-			category, status, due_date, assigned_to, source_text, confidence,
-			completed_at, created_at, updated_at
-		FROM action_items
-		WHERE tenant_id = $1 AND status = 'pending'
-		ORDER BY priority ASC, due_date ASC NULLS LAST
-	`
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
 
-	rows, err := r.db.Query(ctx, query, tenantID)
+	access, args := document.RowAccessCondition(ctx, "action_items", []interface{}{tenantID})
+	query := actionItemSelect + ` WHERE tenant_id = $1 AND status = 'pending'` + access + ` ORDER BY priority ASC, due_date ASC NULLS LAST`
+
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("get pending action items: %w", err)
 	}
 	defer rows.Close()
 
-	var items []*ActionItem
-	for rows.Next() {
-		a := &ActionItem{}
-		err := rows.Scan(
-			&a.ID, &a.AnalysisID, &a.DocumentID, &a.TenantID, &a.Title, &a.Description, &a.Priority,
-			&a.Category, &a.Status, &a.DueDate, &a.AssignedTo, &a.SourceText, &a.Confidence,
-			&a.CompletedAt, &a.CreatedAt, &a.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("scan action item: %w", err)
-		}
-		items = append(items, a)
-	}
-
-	return items, nil
+	return scanActionItems(rows)
 }
 
 // UpdateActionItemStatus updates an action item's status
 func (r *Repository) UpdateActionItemStatus(ctx context.Context, id uuid.UUID, status ActionStatus) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	var completedAt *time.Time
 	if status == "completed" {
 		now := time.Now()
@@ -657,6 +726,9 @@ func (r *Repository) UpdateActionItemStatus(ctx context.Context, id uuid.UUID, s
 
 // CreateSuggestion inserts a new suggestion
 func (r *Repository) CreateSuggestion(ctx context.Context, s *Suggestion) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO response_suggestions (
 			analysis_id, document_id, tenant_id, suggestion_type, title,
@@ -671,22 +743,15 @@ func (r *Repository) CreateSuggestion(ctx context.Context, s *Suggestion) error
 	).Scan(&s.ID, &s.CreatedAt)
 }
 
-// GetSuggestionsByDocument returns all suggestions for a document
-func (r *Repository) GetSuggestionsByDocument(ctx context.Context, documentID uuid.UUID) ([]*Suggestion, error) {
-	query := `
+// suggestionSelect selects response suggestions
+const suggestionSelect = `
 		SELECT id, analysis_id, document_id, tenant_id, suggestion_type, title,
 			content, reasoning, confidence, is_used, used_at, created_at
 		FROM response_suggestions
-		WHERE document_id = $1
-		ORDER BY confidence DESC
-	`
-
-	rows, err := r.db.Query(ctx, query, documentID)
-	if err != nil {
-		return nil, fmt.Errorf("get suggestions: %w", err)
-	}
-	defer rows.Close()
+`
 
+// scanSuggestions scans the rows of suggestionSelect
+func scanSuggestions(rows pgx.Rows) ([]*Suggestion, error) {
 	var suggestions []*Suggestion
 	for rows.Next() {
 		s := &Suggestion{}
@@ -699,12 +764,172 @@ func (r *Repository) GetSuggestionsByDocument(ctx context.Context, documentID uu
 		}
 		suggestions = append(suggestions, s)
 	}
+	return suggestions, rows.Err()
+}
+
+// GetSuggestionsByDocument returns all suggestions for a document
+func (r *Repository) GetSuggestionsByDocument(ctx context.Context, documentID uuid.UUID) ([]*Suggestion, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
+	access, args := document.RowAccessCondition(ctx, "response_suggestions", []interface{}{documentID})
+	rows, err := r.db.Query(ctx, suggestionSelect+` WHERE document_id = $1`+access+` ORDER BY confidence DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSuggestions(rows)
+}
+
+// DocumentExtractions are the deadlines, amounts and action items of a document
+type DocumentExtractions struct {
+	Deadlines   []*Deadline   `json:"deadlines"`
+	Amounts     []*Amount     `json:"amounts"`
+	ActionItems []*ActionItem `json:"action_items"`
+}
+
+// GetExtractionsByDocuments loads the extractions of a page of documents in
+// one round trip. The result has an entry for every document ID.
+func (r *Repository) GetExtractionsByDocuments(ctx context.Context, documentIDs []uuid.UUID) (map[uuid.UUID]*DocumentExtractions, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
+	ids := database.UniqueIDs(documentIDs)
+	result := make(map[uuid.UUID]*DocumentExtractions, len(ids))
+	for _, id := range ids {
+		result[id] = &DocumentExtractions{}
+	}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	var deadlines []*Deadline
+	var amounts []*Amount
+	var items []*ActionItem
+	err := database.RunBatch(ctx, r.db,
+		database.BatchQuery{
+			SQL:  deadlineSelect + ` WHERE document_id = ANY($1) ORDER BY deadline_date ASC`,
+			Args: []any{ids},
+			Scan: func(rows pgx.Rows) (err error) {
+				deadlines, err = scanDeadlines(rows)
+				return err
+			},
+		},
+		database.BatchQuery{
+			SQL:  amountSelect + ` WHERE document_id = ANY($1) ORDER BY created_at DESC`,
+			Args: []any{ids},
+			Scan: func(rows pgx.Rows) (err error) {
+				amounts, err = scanAmounts(rows)
+				return err
+			},
+		},
+		database.BatchQuery{
+			SQL:  actionItemSelect + ` WHERE document_id = ANY($1) ORDER BY priority ASC, created_at DESC`,
+			Args: []any{ids},
+			Scan: func(rows pgx.Rows) (err error) {
+				items, err = scanActionItems(rows)
+				return err
+			},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get extractions: %w", err)
+	}
+
+	for _, d := range deadlines {
+		result[d.DocumentID].Deadlines = append(result[d.DocumentID].Deadlines, d)
+	}
+	for _, a := range amounts {
+		result[a.DocumentID].Amounts = append(result[a.DocumentID].Amounts, a)
+	}
+	for _, a := range items {
+		result[a.DocumentID].ActionItems = append(result[a.DocumentID].ActionItems, a)
+	}
+	return result, nil
+}
 
-	return suggestions, nil
+// GetFullAnalysis loads the latest analysis of a document with its
+// extractions and suggestions in one round trip. A document the viewer of
+// ctx may not see has no analysis; the rest of the batch is dropped.
+func (r *Repository) GetFullAnalysis(ctx context.Context, documentID uuid.UUID) (*FullAnalysisResult, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
+	result := &FullAnalysisResult{}
+	var corrections []*Correction
+	access, args := document.RowAccessCondition(ctx, "document_analyses", []interface{}{documentID})
+	err := database.RunBatch(ctx, r.db,
+		database.BatchQuery{
+			SQL:  analysisSelect + ` WHERE document_id = $1` + access + ` ORDER BY created_at DESC LIMIT 1`,
+			Args: args,
+			Scan: func(rows pgx.Rows) (err error) {
+				if !rows.Next() {
+					return rows.Err()
+				}
+				result.Analysis, err = scanAnalysis(rows)
+				return err
+			},
+		},
+		database.BatchQuery{
+			SQL:  deadlineSelect + ` WHERE document_id = $1 ORDER BY deadline_date ASC`,
+			Args: []any{documentID},
+			Scan: func(rows pgx.Rows) (err error) {
+				result.Deadlines, err = scanDeadlines(rows)
+				return err
+			},
+		},
+		database.BatchQuery{
+			SQL:  amountSelect + ` WHERE document_id = $1 ORDER BY created_at DESC`,
+			Args: []any{documentID},
+			Scan: func(rows pgx.Rows) (err error) {
+				result.Amounts, err = scanAmounts(rows)
+				return err
+			},
+		},
+		database.BatchQuery{
+			SQL:  actionItemSelect + ` WHERE document_id = $1 ORDER BY priority ASC, created_at DESC`,
+			Args: []any{documentID},
+			Scan: func(rows pgx.Rows) (err error) {
+				result.ActionItems, err = scanActionItems(rows)
+				return err
+			},
+		},
+		database.BatchQuery{
+			SQL:  suggestionSelect + ` WHERE document_id = $1 ORDER BY confidence DESC`,
+			Args: []any{documentID},
+			Scan: func(rows pgx.Rows) (err error) {
+				result.Suggestions, err = scanSuggestions(rows)
+				return err
+			},
+		},
+		database.BatchQuery{
+			SQL:  `SELECT ` + correctionColumns + ` FROM extraction_corrections WHERE document_id = $1 ORDER BY changed_at DESC`,
+			Args: []any{documentID},
+			Scan: func(rows pgx.Rows) (err error) {
+				corrections, err = scanCorrections(rows)
+				return err
+			},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get full analysis: %w", err)
+	}
+	if result.Analysis == nil {
+		return nil, ErrAnalysisNotFound
+	}
+	attachHistory(corrections, result.Deadlines, result.Amounts)
+	if err := r.restoreText(ctx, result.Analysis); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // MarkSuggestionUsed marks a suggestion as used
 func (r *Repository) MarkSuggestionUsed(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `UPDATE response_suggestions SET is_used = TRUE, used_at = NOW() WHERE id = $1`
 	_, err := r.db.Exec(ctx, query, id)
 	return err
@@ -712,6 +937,9 @@ func (r *Repository) MarkSuggestionUsed(ctx context.Context, id uuid.UUID) error
 
 // GetAnalysisStats returns analysis statistics for a tenant
 func (r *Repository) GetAnalysisStats(ctx context.Context, tenantID uuid.UUID) (*AnalysisStats, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT
 			COUNT(*) as total,
@@ -719,7 +947,7 @@ func (r *Repository) GetAnalysisStats(ctx context.Context, tenantID uuid.UUID) (
 			COUNT(*) FILTER (WHERE status = 'pending') as pending,
 			COUNT(*) FILTER (WHERE status = 'failed') as failed,
 			COALESCE(SUM(tokens_used), 0) as total_tokens,
-			COALESCE(SUM(estimated_cost), 0) as total_cost,
+			COALESCE(SUM(cost_cents), 0) as total_cost,
 			COALESCE(AVG(processing_time_ms) FILTER (WHERE status = 'completed'), 0) as avg_processing_time
 		FROM document_analyses
 		WHERE tenant_id = $1
@@ -740,21 +968,24 @@ func (r *Repository) GetAnalysisStats(ctx context.Context, tenantID uuid.UUID) (
 
 // AnalysisStats holds aggregate statistics
 type AnalysisStats struct {
-	TotalAnalyses       int     `json:"total_analyses"`
-	CompletedAnalyses   int     `json:"completed_analyses"`
-	PendingAnalyses     int     `json:"pending_analyses"`
-	FailedAnalyses      int     `json:"failed_analyses"`
-	TotalTokensUsed     int     `json:"total_tokens_used"`
-	TotalCost           float64 `json:"total_cost"`
-	AvgProcessingTimeMs float64 `json:"avg_processing_time_ms"`
+	TotalAnalyses       int         `json:"total_analyses"`
+	CompletedAnalyses   int         `json:"completed_analyses"`
+	PendingAnalyses     int         `json:"pending_analyses"`
+	FailedAnalyses      int         `json:"failed_analyses"`
+	TotalTokensUsed     int         `json:"total_tokens_used"`
+	TotalCost           money.Money `json:"total_cost"`
+	AvgProcessingTimeMs float64     `json:"avg_processing_time_ms"`
 }
 
 // LogAIUsage logs AI API usage
 func (r *Repository) LogAIUsage(ctx context.Context, log *AIUsageLog) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO ai_usage_logs (
 			tenant_id, analysis_id, operation, model, prompt_type,
-			input_tokens, output_tokens, total_tokens, estimated_cost,
+			input_tokens, output_tokens, total_tokens, cost_cents,
 			latency_ms, success, error_message
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, created_at
@@ -769,20 +1000,20 @@ func (r *Repository) LogAIUsage(ctx context.Context, log *AIUsageLog) error {
 
 // AIUsageLog represents an AI API usage log entry
 type AIUsageLog struct {
-	ID            uuid.UUID  `json:"id"`
-	TenantID      uuid.UUID  `json:"tenant_id"`
-	AnalysisID    *uuid.UUID `json:"analysis_id,omitempty"`
-	Operation     string     `json:"operation"`
-	Model         string     `json:"model"`
-	PromptType    string     `json:"prompt_type,omitempty"`
-	InputTokens   int        `json:"input_tokens"`
-	OutputTokens  int        `json:"output_tokens"`
-	TotalTokens   int        `json:"total_tokens"`
-	EstimatedCost float64    `json:"estimated_cost"`
-	LatencyMs     int        `json:"latency_ms"`
-	Success       bool       `json:"success"`
-	ErrorMessage  string     `json:"error_message,omitempty"`
-	CreatedAt     time.Time  `json:"created_at"`
+	ID            uuid.UUID   `json:"id"`
+	TenantID      uuid.UUID   `json:"tenant_id"`
+	AnalysisID    *uuid.UUID  `json:"analysis_id,omitempty"`
+	Operation     string      `json:"operation"`
+	Model         string      `json:"model"`
+	PromptType    string      `json:"prompt_type,omitempty"`
+	InputTokens   int         `json:"input_tokens"`
+	OutputTokens  int         `json:"output_tokens"`
+	TotalTokens   int         `json:"total_tokens"`
+	EstimatedCost money.Money `json:"estimated_cost"`
+	LatencyMs     int         `json:"latency_ms"`
+	Success       bool        `json:"success"`
+	ErrorMessage  string      `json:"error_message,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
 }
 
 // Analysis status constants
@@ -800,6 +1031,9 @@ const (
 	DeadlineTypeSubmission = "submission"
 	DeadlineTypeAppeal     = "appeal"
 	DeadlineTypeOther      = "other"
+	// DeadlineTypeCancellation is the last day to cancel a contract before
+	// it renews; set by the contract register, not extracted
+	DeadlineTypeCancellation = "cancellation"
 )
 
 // Action item priority constants
@@ -818,21 +1052,24 @@ const (
 
 // ResponseTemplate represents a response template
 type ResponseTemplate struct {
-	ID          uuid.UUID  `json:"id"`
-	TenantID    uuid.UUID  `json:"tenant_id"`
-	Name        string     `json:"name"`
-	Category    string     `json:"category"`
-	Content     string     `json:"content"`
-	Description string     `json:"description,omitempty"`
-	Variables   []string   `json:"variables,omitempty"`
-	IsActive    bool       `json:"is_active"`
-	UsageCount  int        `json:"usage_count"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID          uuid.UUID `json:"id"`
+	TenantID    uuid.UUID `json:"tenant_id"`
+	Name        string    `json:"name"`
+	Category    string    `json:"category"`
+	Content     string    `json:"content"`
+	Description string    `json:"description,omitempty"`
+	Variables   []string  `json:"variables,omitempty"`
+	IsActive    bool      `json:"is_active"`
+	UsageCount  int       `json:"usage_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 // GetDeadlineByID retrieves a deadline by ID
 func (r *Repository) GetDeadlineByID(ctx context.Context, id uuid.UUID) (*Deadline, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, analysis_id, document_id, tenant_id, deadline_type, deadline_date,
 			description, source_text, confidence, is_hard, is_acknowledged,
@@ -861,6 +1098,9 @@ func (r *Repository) GetDeadlineByID(ctx context.Context, id uuid.UUID) (*Deadli
 
 // UpdateDeadline updates a deadline
 func (r *Repository) UpdateDeadline(ctx context.Context, d *Deadline) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE extracted_deadlines SET
 			deadline_date = $2, description = $3, is_acknowledged = $4,
@@ -884,6 +1124,9 @@ func (r *Repository) UpdateDeadline(ctx context.Context, d *Deadline) error {
 
 // GetActionItemByID retrieves an action item by ID
 func (r *Repository) GetActionItemByID(ctx context.Context, id uuid.UUID) (*ActionItem, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, analysis_id, document_id, tenant_id, title, description, priority,
 			category, status, due_date, assigned_to, source_text, confidence,
@@ -911,6 +1154,9 @@ func (r *Repository) GetActionItemByID(ctx context.Context, id uuid.UUID) (*Acti
 
 // UpdateActionItem updates an action item
 func (r *Repository) UpdateActionItem(ctx context.Context, a *ActionItem) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE action_items SET
 			title = $2, description = $3, priority = $4, status = $5,
@@ -933,6 +1179,9 @@ func (r *Repository) UpdateActionItem(ctx context.Context, a *ActionItem) error
 
 // DeleteActionItem deletes an action item
 func (r *Repository) DeleteActionItem(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM action_items WHERE id = $1`
 	result, err := r.db.Exec(ctx, query, id)
 	if err != nil {
@@ -946,6 +1195,9 @@ func (r *Repository) DeleteActionItem(ctx context.Context, id uuid.UUID) error {
 
 // CreateResponseTemplate creates a new response template
 func (r *Repository) CreateResponseTemplate(ctx context.Context, t *ResponseTemplate) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	variablesJSON, _ := json.Marshal(t.Variables)
 
 	query := `
@@ -962,6 +1214,9 @@ func (r *Repository) CreateResponseTemplate(ctx context.Context, t *ResponseTemp
 
 // GetResponseTemplateByID retrieves a response template by ID
 func (r *Repository) GetResponseTemplateByID(ctx context.Context, id uuid.UUID) (*ResponseTemplate, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, tenant_id, name, category, content, description, variables,
 			is_active, usage_count, created_at, updated_at
@@ -990,6 +1245,9 @@ func (r *Repository) GetResponseTemplateByID(ctx context.Context, id uuid.UUID)
 
 // ListResponseTemplates lists response templates for a tenant
 func (r *Repository) ListResponseTemplates(ctx context.Context, tenantID uuid.UUID, category string) ([]*ResponseTemplate, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, tenant_id, name, category, content, description, variables,
 			is_active, usage_count, created_at, updated_at
@@ -1033,6 +1291,9 @@ func (r *Repository) ListResponseTemplates(ctx context.Context, tenantID uuid.UU
 
 // UpdateResponseTemplate updates a response template
 func (r *Repository) UpdateResponseTemplate(ctx context.Context, t *ResponseTemplate) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	variablesJSON, _ := json.Marshal(t.Variables)
 
 	query := `
@@ -1056,6 +1317,9 @@ func (r *Repository) UpdateResponseTemplate(ctx context.Context, t *ResponseTemp
 
 // DeleteResponseTemplate deletes a response template
 func (r *Repository) DeleteResponseTemplate(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM response_templates WHERE id = $1`
 	result, err := r.db.Exec(ctx, query, id)
 	if err != nil {
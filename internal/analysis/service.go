@@ -3,33 +3,38 @@ package analysis
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"time"
 
 	"github.com/google/uuid"
 	"austrian-business-infrastructure/internal/ai"
+	"austrian-business-infrastructure/internal/analytics"
 	"austrian-business-infrastructure/internal/document"
 	"austrian-business-infrastructure/internal/ocr"
+	"austrian-business-infrastructure/internal/timezone"
 )
 
 // Service orchestrates document analysis
 type Service struct {
 	repo        *Repository
 	docService  *document.Service
-	ocrService  *ocr.Service
+	ocrService  ocr.Processor
 	classifier  *Classifier
 	extractor   *Extractor
-	aiClient    *ai.Client
+	aiClient    ai.Completer
 	maxCost     float64
 	enabled     bool
+	analytics   *analytics.Emitter
+	timezones   timezone.Source
 }
 
 // ServiceConfig holds analysis service configuration
 type ServiceConfig struct {
-	AIClient      *ai.Client
+	AIClient      ai.Completer
 	PromptLoader  *ai.PromptLoader
-	OCRService    *ocr.Service
+	OCRService    ocr.Processor
 	DocService    *document.Service
 	MaxCostPerDoc float64
 	Enabled       bool
@@ -49,6 +54,11 @@ func NewService(repo *Repository, cfg ServiceConfig) *Service {
 	}
 }
 
+// SetAnalytics reports completed document analyses to the product analytics
+func (s *Service) SetAnalytics(emitter *analytics.Emitter) {
+	s.analytics = emitter
+}
+
 // AnalysisOptions configures what analysis to perform
 type AnalysisOptions struct {
 	IncludeOCR         bool `json:"include_ocr"`
@@ -58,6 +68,8 @@ type AnalysisOptions struct {
 	IncludeAmounts     bool `json:"include_amounts"`
 	IncludeActionItems bool `json:"include_action_items"`
 	IncludeSuggestions bool `json:"include_suggestions"`
+	IncludeLinks       bool `json:"include_links"`
+	IncludeFiling      bool `json:"include_filing"`
 }
 
 // DefaultOptions returns the default analysis options
@@ -70,6 +82,8 @@ func DefaultOptions() AnalysisOptions {
 		IncludeAmounts:     true,
 		IncludeActionItems: true,
 		IncludeSuggestions: true,
+		IncludeLinks:       true,
+		IncludeFiling:      true,
 	}
 }
 
@@ -80,6 +94,7 @@ type FullAnalysisResult struct {
 	Amounts     []*Amount             `json:"amounts,omitempty"`
 	ActionItems []*ActionItem         `json:"action_items,omitempty"`
 	Suggestions []*Suggestion         `json:"suggestions,omitempty"`
+	Filing      *FilingPlan           `json:"filing,omitempty"`
 	Warnings    []ConfidenceWarning   `json:"warnings,omitempty"`
 }
 
@@ -231,40 +246,25 @@ func (s *Service) AnalyzeDocument(ctx context.Context, documentID, tenantID uuid
 		return nil, fmt.Errorf("get document metadata: %w", err)
 	}
 
-	var text string
-
-	// Step 1: OCR/Text Extraction
-	if opts.IncludeOCR && storageInfo.ContentType == "application/pdf" {
-		ocrResult, err := s.ocrService.ProcessBytes(ctx, data)
-		if err != nil {
-			// Log OCR error but continue with what we have
-			analysis.ErrorMessage = fmt.Sprintf("OCR warning: %v", err)
-		} else {
-			text = ocrResult.Text
-			analysis.IsScanned = ocrResult.Provider != ocr.ProviderNone
-			analysis.OCRProvider = string(ocrResult.Provider)
-			analysis.OCRConfidence = ocrResult.Confidence
-			analysis.PageCount = len(ocrResult.PageTexts)
+	// Consent of the tenant: steps of purposes it does not cover are skipped,
+	// and the whole analysis if it covers none
+	if err := s.checkConsent(ctx, analysis, doc.Type, &opts); err != nil {
+		if !errors.Is(err, ErrConsentDenied) {
+			s.failAnalysis(ctx, analysis, "consent_error", err.Error())
 		}
+		return nil, err
 	}
 
-	// If no text from OCR, try direct extraction
-	if text == "" {
-		extracted, err := ocr.ExtractPDFTextFromBytes(data)
-		if err == nil {
-			text = extracted
-		}
+	// Step 1: Text extraction (PDF, DOCX, XLSX or image via OCR)
+	text, err := s.extractText(ctx, analysis, data, storageInfo.ContentType, opts.IncludeOCR)
+	if err != nil {
+		s.failAnalysis(ctx, analysis, extractionErrorCode(err), err.Error())
+		return nil, fmt.Errorf("extract text: %w", err)
 	}
 
 	analysis.ExtractedText = text
 	analysis.TextLength = len(text)
 
-	// If we still have no text, fail
-	if text == "" {
-		s.failAnalysis(ctx, analysis, "no_text", "Could not extract text from document")
-		return nil, fmt.Errorf("no text could be extracted from document")
-	}
-
 	result := &FullAnalysisResult{
 		Analysis: analysis,
 	}
@@ -272,7 +272,7 @@ func (s *Service) AnalyzeDocument(ctx context.Context, documentID, tenantID uuid
 	// Step 2: Classification
 	var classification *ClassificationResult
 	if opts.IncludeClassify {
-		classification, err = s.classifier.ClassifyWithFallback(ctx, text, doc.Title)
+		classification, err = s.classifier.ClassifyWithFallback(ctx, text, doc.Title, s.tenantTaxonomy(ctx, tenantID))
 		if err != nil {
 			// Non-fatal, continue with default
 			classification = &ClassificationResult{
@@ -286,6 +286,16 @@ func (s *Service) AnalyzeDocument(ctx context.Context, documentID, tenantID uuid
 		analysis.ClassificationConfidence = classification.Confidence
 	}
 
+	// Step 2b: Link the invoices and UVA periods the document refers to.
+	// Non-fatal: the document graph then lacks the automatic links.
+	if opts.IncludeLinks {
+		docType := DocTypeSonstige
+		if classification != nil {
+			docType = classification.DocumentType
+		}
+		s.docService.LinkReferences(ctx, tenantID, documentID, referencesToLink(ExtractReferences(text), docType))
+	}
+
 	// Step 3: Summary
 	if opts.IncludeSummary {
 		summary, err := s.extractor.Summarize(ctx, text)
@@ -402,6 +412,14 @@ func (s *Service) AnalyzeDocument(ctx context.Context, documentID, tenantID uuid
 		}
 	}
 
+	// Step 8: Filing rules of the tenant (folder, labels, assignee, action items).
+	// Non-fatal: the document then stays where it is.
+	if opts.IncludeFiling && classification != nil {
+		if plan, err := s.applyFilingRules(ctx, analysis, doc, result.Amounts); err == nil {
+			result.Filing = plan
+		}
+	}
+
 	// Finalize analysis
 	analysis.Status = StatusCompleted
 	analysis.ProcessingTimeMs = int(time.Since(startTime).Milliseconds())
@@ -415,6 +433,10 @@ func (s *Service) AnalyzeDocument(ctx context.Context, documentID, tenantID uuid
 	// Generate confidence warnings for low-confidence items
 	result.GenerateConfidenceWarnings()
 
+	s.analytics.Track(ctx, tenantID, uuid.Nil, analytics.EventAnalysisCompleted, analytics.Properties{
+		"document_type":      analysis.DocumentType,
+		"processing_time_ms": analysis.ProcessingTimeMs,
+	})
 	return result, nil
 }
 
@@ -438,33 +460,35 @@ func (s *Service) GetAnalysisByDocument(ctx context.Context, documentID uuid.UUI
 
 // GetFullAnalysis retrieves full analysis results for a document
 func (s *Service) GetFullAnalysis(ctx context.Context, documentID uuid.UUID) (*FullAnalysisResult, error) {
-	analysis, err := s.repo.GetAnalysisByDocumentID(ctx, documentID)
-	if err != nil {
-		return nil, err
-	}
+	return s.repo.GetFullAnalysis(ctx, documentID)
+}
 
-	deadlines, _ := s.repo.GetDeadlinesByDocument(ctx, documentID)
-	amounts, _ := s.repo.GetAmountsByDocument(ctx, documentID)
-	actionItems, _ := s.repo.GetActionItemsByDocument(ctx, documentID)
-	suggestions, _ := s.repo.GetSuggestionsByDocument(ctx, documentID)
+// ListAnalyses returns analyses for a tenant
+func (s *Service) ListAnalyses(ctx context.Context, tenantID uuid.UUID, limit, offset int, withText bool) ([]*Analysis, int, error) {
+	return s.repo.ListAnalyses(ctx, tenantID, limit, offset, withText)
+}
 
-	return &FullAnalysisResult{
-		Analysis:    analysis,
-		Deadlines:   deadlines,
-		Amounts:     amounts,
-		ActionItems: actionItems,
-		Suggestions: suggestions,
-	}, nil
+// GetExtractions returns the deadlines, amounts and action items of the
+// documents of the given analyses, keyed by document ID
+func (s *Service) GetExtractions(ctx context.Context, analyses []*Analysis) (map[uuid.UUID]*DocumentExtractions, error) {
+	ids := make([]uuid.UUID, len(analyses))
+	for i, a := range analyses {
+		ids[i] = a.DocumentID
+	}
+	return s.repo.GetExtractionsByDocuments(ctx, ids)
 }
 
-// ListAnalyses returns analyses for a tenant
-func (s *Service) ListAnalyses(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*Analysis, int, error) {
-	return s.repo.ListAnalyses(ctx, tenantID, limit, offset)
+// SetTimezones sets where tenants' time zones come from; without it days
+// are those of Europe/Vienna
+func (s *Service) SetTimezones(src timezone.Source) {
+	s.timezones = src
 }
 
-// GetUpcomingDeadlines returns upcoming deadlines
+// GetUpcomingDeadlines returns the deadlines due in the next days, counted
+// from today in the tenant's time zone
 func (s *Service) GetUpcomingDeadlines(ctx context.Context, tenantID uuid.UUID, days int) ([]*Deadline, error) {
-	return s.repo.GetUpcomingDeadlines(ctx, tenantID, days)
+	today := timezone.Today(time.Now(), timezone.Of(ctx, s.timezones, tenantID))
+	return s.repo.GetUpcomingDeadlines(ctx, tenantID, today, days)
 }
 
 // AcknowledgeDeadline acknowledges a deadline
@@ -498,11 +522,11 @@ func (s *Service) GetStats(ctx context.Context, tenantID uuid.UUID) (*AnalysisSt
 }
 
 // QuickClassify performs only classification without full analysis
-func (s *Service) QuickClassify(ctx context.Context, text string, title string) (*ClassificationResult, error) {
+func (s *Service) QuickClassify(ctx context.Context, tenantID uuid.UUID, text string, title string) (*ClassificationResult, error) {
 	if !s.enabled {
 		return nil, fmt.Errorf("AI analysis is disabled")
 	}
-	return s.classifier.ClassifyWithFallback(ctx, text, title)
+	return s.classifier.ClassifyWithFallback(ctx, text, title, s.tenantTaxonomy(ctx, tenantID))
 }
 
 // QuickSummary performs only summarization
@@ -513,6 +537,17 @@ func (s *Service) QuickSummary(ctx context.Context, text string) (*SummaryResult
 	return s.extractor.Summarize(ctx, text)
 }
 
+// ErrTranslationUnavailable is returned by Translate without an AI client
+var ErrTranslationUnavailable = errors.New("translation unavailable")
+
+// Translate translates analysis texts into another language
+func (s *Service) Translate(ctx context.Context, texts []string, from, to string) ([]string, error) {
+	if !s.enabled || s.aiClient == nil {
+		return nil, ErrTranslationUnavailable
+	}
+	return s.extractor.Translate(ctx, texts, from, to)
+}
+
 // QuickExtractDeadlines extracts only deadlines
 func (s *Service) QuickExtractDeadlines(ctx context.Context, text string) ([]ExtractedDeadline, error) {
 	if !s.enabled {
@@ -521,43 +556,34 @@ func (s *Service) QuickExtractDeadlines(ctx context.Context, text string) ([]Ext
 	return s.extractor.ExtractDeadlines(ctx, text)
 }
 
-// ProcessPDFBytes analyzes a PDF from bytes (for direct API use)
-func (s *Service) ProcessPDFBytes(ctx context.Context, tenantID uuid.UUID, pdfData []byte, opts AnalysisOptions) (*FullAnalysisResult, error) {
+// ProcessBytes analyzes an uploaded file (for direct API use) without
+// storing anything. mimeType is the type the upload was checked as: a PDF,
+// Word document or image.
+func (s *Service) ProcessBytes(ctx context.Context, tenantID uuid.UUID, data []byte, mimeType string, opts AnalysisOptions) (*FullAnalysisResult, error) {
 	if !s.enabled {
 		return nil, fmt.Errorf("AI analysis is disabled")
 	}
 
-	// Extract text
-	var text string
-	if s.ocrService != nil {
-		ocrResult, err := s.ocrService.ProcessBytes(ctx, pdfData)
-		if err == nil {
-			text = ocrResult.Text
-		}
-	}
-
-	if text == "" {
-		extracted, err := ocr.ExtractPDFTextFromBytes(pdfData)
-		if err != nil {
-			return nil, fmt.Errorf("extract text: %w", err)
-		}
-		text = extracted
-	}
-
 	// Create in-memory analysis result
 	result := &FullAnalysisResult{
 		Analysis: &Analysis{
-			ID:            uuid.New(),
-			TenantID:      tenantID,
-			Status:        StatusCompleted,
-			ExtractedText: text,
-			TextLength:    len(text),
+			ID:       uuid.New(),
+			TenantID: tenantID,
+			Status:   StatusCompleted,
 		},
 	}
 
+	// Extract text
+	text, err := s.extractText(ctx, result.Analysis, data, mimeType, true)
+	if err != nil {
+		return nil, fmt.Errorf("extract text: %w", err)
+	}
+	result.Analysis.ExtractedText = text
+	result.Analysis.TextLength = len(text)
+
 	// Classification
 	if opts.IncludeClassify {
-		classification, err := s.classifier.ClassifyWithFallback(ctx, text, "")
+		classification, err := s.classifier.ClassifyWithFallback(ctx, text, "", s.tenantTaxonomy(ctx, tenantID))
 		if err == nil {
 			result.Analysis.DocumentType = string(classification.DocumentType)
 			result.Analysis.DocumentSubtype = string(classification.DocumentSubtype)
@@ -609,12 +635,14 @@ func readContent(r io.ReadCloser) ([]byte, error) {
 	return buf.Bytes(), err
 }
 
-// UpdateDeadline updates a deadline (T029 - manual correction)
-func (s *Service) UpdateDeadline(ctx context.Context, id uuid.UUID, req *UpdateDeadlineRequest) (*Deadline, error) {
+// UpdateDeadline updates a deadline (T029 - manual correction). The changes
+// are recorded in the deadline's history with the editing user.
+func (s *Service) UpdateDeadline(ctx context.Context, id uuid.UUID, userID *uuid.UUID, req *UpdateDeadlineRequest) (*Deadline, error) {
 	deadline, err := s.repo.GetDeadlineByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	before := deadline.fields()
 
 	if req.Date != nil {
 		parsedDate, err := time.Parse("2006-01-02", *req.Date)
@@ -652,8 +680,11 @@ func (s *Service) UpdateDeadline(ctx context.Context, id uuid.UUID, req *UpdateD
 	if err := s.repo.UpdateDeadline(ctx, deadline); err != nil {
 		return nil, err
 	}
+	if err := s.recordCorrection(ctx, deadline.TenantID, deadline.DocumentID, EntityDeadline, id, userID, before, deadline.fields()); err != nil {
+		return nil, err
+	}
 
-	return deadline, nil
+	return s.GetDeadline(ctx, id)
 }
 
 // UpdateActionItem updates an action item (T045)
@@ -707,12 +738,14 @@ func (s *Service) DeleteActionItem(ctx context.Context, id uuid.UUID) error {
 	return s.repo.DeleteActionItem(ctx, id)
 }
 
-// UpdateAmount updates an extracted amount (T074 - manual correction)
-func (s *Service) UpdateAmount(ctx context.Context, id uuid.UUID, req *UpdateAmountRequest) (*Amount, error) {
+// UpdateAmount updates an extracted amount (T074 - manual correction). The
+// changes are recorded in the amount's history with the editing user.
+func (s *Service) UpdateAmount(ctx context.Context, id uuid.UUID, userID *uuid.UUID, req *UpdateAmountRequest) (*Amount, error) {
 	amount, err := s.repo.GetAmountByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	before := amount.fields()
 
 	if req.Amount != nil {
 		amount.Amount = *req.Amount
@@ -722,6 +755,7 @@ func (s *Service) UpdateAmount(ctx context.Context, id uuid.UUID, req *UpdateAmo
 	if req.Currency != nil {
 		amount.Currency = *req.Currency
 	}
+	amount.Amount.Currency = amount.Currency
 
 	if req.AmountType != nil {
 		amount.AmountType = *req.AmountType
@@ -750,8 +784,11 @@ func (s *Service) UpdateAmount(ctx context.Context, id uuid.UUID, req *UpdateAmo
 	if err := s.repo.UpdateAmount(ctx, amount); err != nil {
 		return nil, err
 	}
+	if err := s.recordCorrection(ctx, amount.TenantID, amount.DocumentID, EntityAmount, id, userID, before, amount.fields()); err != nil {
+		return nil, err
+	}
 
-	return amount, nil
+	return s.GetAmount(ctx, id)
 }
 
 // DeleteAmount deletes an extracted amount
@@ -765,6 +802,18 @@ func (s *Service) GenerateSuggestion(ctx context.Context, documentID, tenantID u
 		return nil, fmt.Errorf("AI analysis is disabled")
 	}
 
+	doc, err := s.docService.GetByID(ctx, tenantID, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("get document metadata: %w", err)
+	}
+	policy, err := s.ConsentPolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.Allows(doc.Type, PurposeSuggestions) {
+		return nil, fmt.Errorf("%w: consent version %d does not allow response suggestions for document type %q", ErrConsentDenied, policy.Version, doc.Type)
+	}
+
 	// Get existing analysis
 	analysis, err := s.repo.GetAnalysisByDocumentID(ctx, documentID)
 	if err != nil {
@@ -0,0 +1,590 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrTaxonomyTypeNotFound = errors.New("document type not found")
+	ErrTaxonomyTypeExists   = errors.New("document type already exists")
+	ErrInvalidTaxonomyCode  = errors.New("invalid document type code")
+	ErrUnknownDocumentType  = errors.New("unknown document type")
+)
+
+var taxonomyCodePattern = regexp.MustCompile(`^[a-z0-9äöüß_-]{2,50}$`)
+
+// TaxonomyType is a tenant-specific document type extending the built-in classification taxonomy
+type TaxonomyType struct {
+	ID             uuid.UUID    `json:"id"`
+	TenantID       uuid.UUID    `json:"tenant_id"`
+	Code           string       `json:"code"`
+	Label          string       `json:"label"`
+	Description    string       `json:"description,omitempty"`
+	Keywords       []string     `json:"keywords,omitempty"`
+	BaseType       DocumentType `json:"base_type,omitempty"` // Built-in type the custom type refines
+	RequiresAction bool         `json:"requires_action"`
+	IsActive       bool         `json:"is_active"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// TaxonomyTypeRequest represents a create or update request for a tenant document type
+type TaxonomyTypeRequest struct {
+	Code           string       `json:"code"`
+	Label          string       `json:"label"`
+	Description    string       `json:"description,omitempty"`
+	Keywords       []string     `json:"keywords,omitempty"`
+	BaseType       DocumentType `json:"base_type,omitempty"`
+	RequiresAction bool         `json:"requires_action"`
+	IsActive       *bool        `json:"is_active,omitempty"`
+}
+
+// ValidateTaxonomyType checks a tenant document type
+func ValidateTaxonomyType(t *TaxonomyType) error {
+	if !taxonomyCodePattern.MatchString(t.Code) {
+		return fmt.Errorf("%w: %q", ErrInvalidTaxonomyCode, t.Code)
+	}
+	if isValidDocumentType(DocumentType(t.Code)) {
+		return fmt.Errorf("%w: %q is a built-in type", ErrTaxonomyTypeExists, t.Code)
+	}
+	if strings.TrimSpace(t.Label) == "" {
+		return fmt.Errorf("%w: label is required", ErrInvalidTaxonomyCode)
+	}
+	if t.BaseType != "" && !isValidDocumentType(t.BaseType) {
+		return fmt.Errorf("%w: base type %q", ErrUnknownDocumentType, t.BaseType)
+	}
+	return nil
+}
+
+// BuildTaxonomyPrompt appends the tenant document types to a classification system prompt
+func BuildTaxonomyPrompt(systemPrompt string, custom []TaxonomyType) string {
+	if len(custom) == 0 {
+		return systemPrompt
+	}
+
+	var b strings.Builder
+	b.WriteString(systemPrompt)
+	b.WriteString("\n\nZusätzliche mandantenspezifische Dokumenttypen (als document_type zulässig, bevorzugen wenn zutreffend):\n")
+	for _, t := range custom {
+		fmt.Fprintf(&b, "- %s: %s", t.Code, t.Label)
+		if t.Description != "" {
+			fmt.Fprintf(&b, " – %s", t.Description)
+		}
+		if len(t.Keywords) > 0 {
+			fmt.Fprintf(&b, " (Schlüsselwörter: %s)", strings.Join(t.Keywords, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// MatchCustomType returns the first tenant document type whose keywords occur
+// in the document text or title
+func MatchCustomType(text, title string, custom []TaxonomyType) *TaxonomyType {
+	combined := strings.ToLower(text + " " + title)
+	for i := range custom {
+		for _, kw := range custom[i].Keywords {
+			if kw = strings.ToLower(strings.TrimSpace(kw)); kw != "" && strings.Contains(combined, kw) {
+				return &custom[i]
+			}
+		}
+	}
+	return nil
+}
+
+func findTaxonomyType(custom []TaxonomyType, code string) *TaxonomyType {
+	for i := range custom {
+		if custom[i].Code == code {
+			return &custom[i]
+		}
+	}
+	return nil
+}
+
+func taxonomyCodes(custom []TaxonomyType) []string {
+	codes := make([]string, len(custom))
+	for i, t := range custom {
+		codes[i] = t.Code
+	}
+	return codes
+}
+
+// ============== Repository ==============
+
+const taxonomyColumns = `
+	id, tenant_id, code, label, description, keywords, base_type,
+	requires_action, is_active, created_at, updated_at`
+
+// CreateTaxonomyType creates a tenant document type
+func (r *Repository) CreateTaxonomyType(ctx context.Context, t *TaxonomyType) error {
+	if t.Keywords == nil {
+		t.Keywords = []string{}
+	}
+
+	query := `
+		INSERT INTO document_type_definitions (
+			tenant_id, code, label, description, keywords, base_type, requires_action, is_active
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		t.TenantID, t.Code, t.Label, t.Description, t.Keywords, t.BaseType, t.RequiresAction, t.IsActive,
+	).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "23505") || strings.Contains(err.Error(), "duplicate key") {
+			return ErrTaxonomyTypeExists
+		}
+		return fmt.Errorf("create document type: %w", err)
+	}
+	return nil
+}
+
+// GetTaxonomyType retrieves a tenant document type by ID
+func (r *Repository) GetTaxonomyType(ctx context.Context, tenantID, id uuid.UUID) (*TaxonomyType, error) {
+	query := `SELECT ` + taxonomyColumns + ` FROM document_type_definitions WHERE id = $1 AND tenant_id = $2`
+
+	t, err := scanTaxonomyType(r.db.QueryRow(ctx, query, id, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaxonomyTypeNotFound
+		}
+		return nil, fmt.Errorf("get document type: %w", err)
+	}
+	return t, nil
+}
+
+// ListTaxonomyTypes lists the document types of a tenant
+func (r *Repository) ListTaxonomyTypes(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]TaxonomyType, error) {
+	query := `SELECT ` + taxonomyColumns + `
+		FROM document_type_definitions
+		WHERE tenant_id = $1 AND (is_active OR NOT $2)
+		ORDER BY label`
+
+	rows, err := r.db.Query(ctx, query, tenantID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("list document types: %w", err)
+	}
+	defer rows.Close()
+
+	var types []TaxonomyType
+	for rows.Next() {
+		t, err := scanTaxonomyType(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan document type: %w", err)
+		}
+		types = append(types, *t)
+	}
+	return types, rows.Err()
+}
+
+// UpdateTaxonomyType updates a tenant document type; the code is immutable
+func (r *Repository) UpdateTaxonomyType(ctx context.Context, t *TaxonomyType) error {
+	if t.Keywords == nil {
+		t.Keywords = []string{}
+	}
+
+	query := `
+		UPDATE document_type_definitions SET
+			label = $3, description = $4, keywords = $5, base_type = $6,
+			requires_action = $7, is_active = $8, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		t.ID, t.TenantID, t.Label, t.Description, t.Keywords, t.BaseType, t.RequiresAction, t.IsActive,
+	).Scan(&t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTaxonomyTypeNotFound
+		}
+		return fmt.Errorf("update document type: %w", err)
+	}
+	return nil
+}
+
+// DeleteTaxonomyType deletes a tenant document type. Analyses keep the code.
+func (r *Repository) DeleteTaxonomyType(ctx context.Context, tenantID, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM document_type_definitions WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("delete document type: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrTaxonomyTypeNotFound
+	}
+	return nil
+}
+
+func scanTaxonomyType(row pgx.Row) (*TaxonomyType, error) {
+	t := &TaxonomyType{}
+	var description, baseType *string
+
+	err := row.Scan(
+		&t.ID, &t.TenantID, &t.Code, &t.Label, &description, &t.Keywords, &baseType,
+		&t.RequiresAction, &t.IsActive, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if description != nil {
+		t.Description = *description
+	}
+	if baseType != nil {
+		t.BaseType = DocumentType(*baseType)
+	}
+	return t, nil
+}
+
+// ============== Service ==============
+
+// tenantTaxonomy returns the active document types of a tenant. Classification
+// falls back to the built-in taxonomy if they cannot be loaded.
+func (s *Service) tenantTaxonomy(ctx context.Context, tenantID uuid.UUID) []TaxonomyType {
+	if tenantID == uuid.Nil {
+		return nil
+	}
+	types, err := s.repo.ListTaxonomyTypes(ctx, tenantID, true)
+	if err != nil {
+		return nil
+	}
+	return types
+}
+
+// ListTaxonomyTypes lists the document types of a tenant
+func (s *Service) ListTaxonomyTypes(ctx context.Context, tenantID uuid.UUID) ([]TaxonomyType, error) {
+	return s.repo.ListTaxonomyTypes(ctx, tenantID, false)
+}
+
+// CreateTaxonomyType adds a document type to the taxonomy of a tenant
+func (s *Service) CreateTaxonomyType(ctx context.Context, tenantID uuid.UUID, req *TaxonomyTypeRequest) (*TaxonomyType, error) {
+	t := &TaxonomyType{
+		TenantID:       tenantID,
+		Code:           strings.ToLower(strings.TrimSpace(req.Code)),
+		Label:          req.Label,
+		Description:    req.Description,
+		Keywords:       req.Keywords,
+		BaseType:       req.BaseType,
+		RequiresAction: req.RequiresAction,
+		IsActive:       true,
+	}
+	if req.IsActive != nil {
+		t.IsActive = *req.IsActive
+	}
+
+	if err := ValidateTaxonomyType(t); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateTaxonomyType(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// UpdateTaxonomyType updates a tenant document type
+func (s *Service) UpdateTaxonomyType(ctx context.Context, tenantID, id uuid.UUID, req *TaxonomyTypeRequest) (*TaxonomyType, error) {
+	t, err := s.repo.GetTaxonomyType(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Label != "" {
+		t.Label = req.Label
+	}
+	t.Description = req.Description
+	t.Keywords = req.Keywords
+	t.BaseType = req.BaseType
+	t.RequiresAction = req.RequiresAction
+	if req.IsActive != nil {
+		t.IsActive = *req.IsActive
+	}
+
+	if err := ValidateTaxonomyType(t); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateTaxonomyType(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// DeleteTaxonomyType removes a document type from the taxonomy of a tenant
+func (s *Service) DeleteTaxonomyType(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.DeleteTaxonomyType(ctx, tenantID, id)
+}
+
+// ============== Classification feedback ==============
+
+// ClassificationFeedback records a user re-classification of an analysis
+type ClassificationFeedback struct {
+	ID                  uuid.UUID  `json:"id"`
+	TenantID            uuid.UUID  `json:"tenant_id"`
+	AnalysisID          uuid.UUID  `json:"analysis_id"`
+	DocumentID          uuid.UUID  `json:"document_id"`
+	PredictedType       string     `json:"predicted_type"`
+	PredictedSubtype    string     `json:"predicted_subtype,omitempty"`
+	PredictedConfidence float64    `json:"predicted_confidence"`
+	CorrectedType       string     `json:"corrected_type"`
+	CorrectedSubtype    string     `json:"corrected_subtype,omitempty"`
+	CorrectedBy         *uuid.UUID `json:"corrected_by,omitempty"`
+	Notes               string     `json:"notes,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// ReclassifyRequest represents a manual classification correction
+type ReclassifyRequest struct {
+	DocumentType    string `json:"document_type"`
+	DocumentSubtype string `json:"document_subtype,omitempty"`
+	Notes           string `json:"notes,omitempty"`
+}
+
+// TypeAccuracy is the classification accuracy of one predicted type in one period
+type TypeAccuracy struct {
+	Period        time.Time `json:"period"`
+	DocumentType  string    `json:"document_type"`
+	Classified    int       `json:"classified"`
+	Corrected     int       `json:"corrected"`
+	Accuracy      float64   `json:"accuracy"`
+	AvgConfidence float64   `json:"avg_confidence"`
+}
+
+// Misclassification counts how often one type was corrected to another
+type Misclassification struct {
+	PredictedType string `json:"predicted_type"`
+	CorrectedType string `json:"corrected_type"`
+	Count         int    `json:"count"`
+}
+
+// AccuracyReport contains per-type classification accuracy over time
+type AccuracyReport struct {
+	Since              time.Time           `json:"since"`
+	Types              []TypeAccuracy      `json:"types"`
+	Misclassifications []Misclassification `json:"misclassifications"`
+}
+
+// Accuracy returns the share of classifications that were not corrected
+func Accuracy(classified, corrected int) float64 {
+	if classified == 0 {
+		return 0
+	}
+	if corrected > classified {
+		corrected = classified
+	}
+	return float64(classified-corrected) / float64(classified)
+}
+
+// CreateClassificationFeedback stores a re-classification and applies it to the analysis
+func (r *Repository) CreateClassificationFeedback(ctx context.Context, f *ClassificationFeedback) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO classification_feedback (
+			tenant_id, analysis_id, document_id, predicted_type, predicted_subtype,
+			predicted_confidence, corrected_type, corrected_subtype, corrected_by, notes
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at
+	`,
+		f.TenantID, f.AnalysisID, f.DocumentID, f.PredictedType, f.PredictedSubtype,
+		f.PredictedConfidence, f.CorrectedType, f.CorrectedSubtype, f.CorrectedBy, f.Notes,
+	).Scan(&f.ID, &f.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create classification feedback: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE document_analyses SET
+			document_type = $2, document_subtype = $3,
+			manually_corrected = true, corrected_by = $4, corrected_at = NOW(),
+			correction_notes = $5, updated_at = NOW()
+		WHERE id = $1
+	`, f.AnalysisID, f.CorrectedType, f.CorrectedSubtype, f.CorrectedBy, f.Notes)
+	if err != nil {
+		return fmt.Errorf("update analysis classification: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetFirstClassificationFeedback returns the earliest feedback of an analysis,
+// which holds the original model prediction
+func (r *Repository) GetFirstClassificationFeedback(ctx context.Context, analysisID uuid.UUID) (*ClassificationFeedback, error) {
+	f := &ClassificationFeedback{}
+	var predictedSubtype, correctedSubtype, notes *string
+
+	err := r.db.QueryRow(ctx, `
+		SELECT id, tenant_id, analysis_id, document_id, predicted_type, predicted_subtype,
+			predicted_confidence, corrected_type, corrected_subtype, corrected_by, notes, created_at
+		FROM classification_feedback
+		WHERE analysis_id = $1
+		ORDER BY created_at
+		LIMIT 1
+	`, analysisID).Scan(
+		&f.ID, &f.TenantID, &f.AnalysisID, &f.DocumentID, &f.PredictedType, &predictedSubtype,
+		&f.PredictedConfidence, &f.CorrectedType, &correctedSubtype, &f.CorrectedBy, &notes, &f.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get classification feedback: %w", err)
+	}
+
+	if predictedSubtype != nil {
+		f.PredictedSubtype = *predictedSubtype
+	}
+	if correctedSubtype != nil {
+		f.CorrectedSubtype = *correctedSubtype
+	}
+	if notes != nil {
+		f.Notes = *notes
+	}
+	return f, nil
+}
+
+// GetClassificationAccuracy aggregates classifications and corrections per month and predicted type.
+// An analysis counts as corrected if its latest correction differs from the prediction.
+func (r *Repository) GetClassificationAccuracy(ctx context.Context, tenantID uuid.UUID, since time.Time) (*AccuracyReport, error) {
+	report := &AccuracyReport{Since: since, Types: []TypeAccuracy{}, Misclassifications: []Misclassification{}}
+
+	rows, err := r.db.Query(ctx, `
+		WITH first_fb AS (
+			SELECT DISTINCT ON (analysis_id) analysis_id, predicted_type, predicted_confidence
+			FROM classification_feedback
+			WHERE tenant_id = $1
+			ORDER BY analysis_id, created_at
+		), last_fb AS (
+			SELECT DISTINCT ON (analysis_id) analysis_id, corrected_type
+			FROM classification_feedback
+			WHERE tenant_id = $1
+			ORDER BY analysis_id, created_at DESC
+		)
+		SELECT
+			date_trunc('month', a.created_at) AS period,
+			COALESCE(f.predicted_type, a.document_type) AS predicted,
+			COUNT(*) AS classified,
+			COUNT(*) FILTER (WHERE l.corrected_type IS NOT NULL AND l.corrected_type <> COALESCE(f.predicted_type, a.document_type)) AS corrected,
+			COALESCE(AVG(COALESCE(f.predicted_confidence, a.classification_confidence)), 0) AS avg_confidence
+		FROM document_analyses a
+		LEFT JOIN first_fb f ON f.analysis_id = a.id
+		LEFT JOIN last_fb l ON l.analysis_id = a.id
+		WHERE a.tenant_id = $1
+			AND a.created_at >= $2
+			AND a.status = 'completed'
+			AND COALESCE(f.predicted_type, a.document_type) IS NOT NULL
+			AND COALESCE(f.predicted_type, a.document_type) <> ''
+		GROUP BY period, predicted
+		ORDER BY period, predicted
+	`, tenantID, since)
+	if err != nil {
+		return nil, fmt.Errorf("query classification accuracy: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t TypeAccuracy
+		if err := rows.Scan(&t.Period, &t.DocumentType, &t.Classified, &t.Corrected, &t.AvgConfidence); err != nil {
+			return nil, fmt.Errorf("scan classification accuracy: %w", err)
+		}
+		t.Accuracy = Accuracy(t.Classified, t.Corrected)
+		report.Types = append(report.Types, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query(ctx, `
+		WITH fb AS (
+			SELECT DISTINCT ON (analysis_id) analysis_id, corrected_type,
+				FIRST_VALUE(predicted_type) OVER (PARTITION BY analysis_id ORDER BY created_at) AS predicted_type
+			FROM classification_feedback
+			WHERE tenant_id = $1 AND created_at >= $2
+			ORDER BY analysis_id, created_at DESC
+		)
+		SELECT predicted_type, corrected_type, COUNT(*)
+		FROM fb
+		WHERE predicted_type <> corrected_type
+		GROUP BY predicted_type, corrected_type
+		ORDER BY COUNT(*) DESC
+		LIMIT 20
+	`, tenantID, since)
+	if err != nil {
+		return nil, fmt.Errorf("query misclassifications: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m Misclassification
+		if err := rows.Scan(&m.PredictedType, &m.CorrectedType, &m.Count); err != nil {
+			return nil, fmt.Errorf("scan misclassification: %w", err)
+		}
+		report.Misclassifications = append(report.Misclassifications, m)
+	}
+	return report, rows.Err()
+}
+
+// Reclassify corrects the classification of an analysis and records the
+// correction as feedback for accuracy reporting
+func (s *Service) Reclassify(ctx context.Context, tenantID, analysisID uuid.UUID, userID *uuid.UUID, req *ReclassifyRequest) (*Analysis, error) {
+	analysis, err := s.repo.GetAnalysisByID(ctx, analysisID)
+	if err != nil {
+		return nil, err
+	}
+	if analysis.TenantID != tenantID {
+		return nil, ErrAnalysisNotFound
+	}
+
+	docType := strings.ToLower(strings.TrimSpace(req.DocumentType))
+	if !isValidDocumentType(DocumentType(docType)) && findTaxonomyType(s.tenantTaxonomy(ctx, tenantID), docType) == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownDocumentType, req.DocumentType)
+	}
+
+	feedback := &ClassificationFeedback{
+		TenantID:            tenantID,
+		AnalysisID:          analysis.ID,
+		DocumentID:          analysis.DocumentID,
+		PredictedType:       analysis.DocumentType,
+		PredictedSubtype:    analysis.DocumentSubtype,
+		PredictedConfidence: analysis.ClassificationConfidence,
+		CorrectedType:       docType,
+		CorrectedSubtype:    req.DocumentSubtype,
+		CorrectedBy:         userID,
+		Notes:               req.Notes,
+	}
+
+	// Keep the original model prediction across repeated corrections
+	first, err := s.repo.GetFirstClassificationFeedback(ctx, analysis.ID)
+	if err != nil {
+		return nil, err
+	}
+	if first != nil {
+		feedback.PredictedType = first.PredictedType
+		feedback.PredictedSubtype = first.PredictedSubtype
+		feedback.PredictedConfidence = first.PredictedConfidence
+	}
+
+	if err := s.repo.CreateClassificationFeedback(ctx, feedback); err != nil {
+		return nil, err
+	}
+
+	analysis.DocumentType = feedback.CorrectedType
+	analysis.DocumentSubtype = feedback.CorrectedSubtype
+	return analysis, nil
+}
+
+// GetClassificationAccuracy returns per-type classification accuracy since the given time
+func (s *Service) GetClassificationAccuracy(ctx context.Context, tenantID uuid.UUID, since time.Time) (*AccuracyReport, error) {
+	return s.repo.GetClassificationAccuracy(ctx, tenantID, since)
+}
@@ -0,0 +1,142 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"austrian-business-infrastructure/internal/mailparse"
+	"austrian-business-infrastructure/internal/ocr"
+)
+
+// Error codes of analyses that fail during text extraction
+const (
+	ErrorCodeUnsupportedFormat = "unsupported_format"
+	ErrorCodeTooLarge          = "too_large"
+	ErrorCodeOCRUnavailable    = "ocr_unavailable"
+	ErrorCodeNoText            = "no_text"
+)
+
+// textExtractionError carries the error code an analysis fails with
+type textExtractionError struct {
+	code string
+	err  error
+}
+
+func (e *textExtractionError) Error() string { return e.err.Error() }
+func (e *textExtractionError) Unwrap() error { return e.err }
+
+func extractionError(code string, err error) error {
+	return &textExtractionError{code: code, err: err}
+}
+
+// extractionErrorCode returns the error code of a failed extraction
+func extractionErrorCode(err error) string {
+	var e *textExtractionError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return ErrorCodeNoText
+}
+
+// CanExtractText reports whether text can be extracted from documents of the
+// MIME type
+func CanExtractText(mimeType string) bool {
+	return ocr.IsExtractable(mimeType) || mailparse.IsEmail(mimeType) || mimeType == "text/plain"
+}
+
+// extractText extracts the text of a document for the classification and
+// extraction stages. The format is detected from the content: PDFs use the
+// embedded text or OCR for scans, Word documents and workbooks are read
+// directly, e-mails contribute their headers and body, and photos and scans
+// as images go through OCR. The OCR details are recorded on the analysis.
+func (s *Service) extractText(ctx context.Context, analysis *Analysis, data []byte, declaredMIME string, useOCR bool) (string, error) {
+	if len(data) > ocr.MaxExtractInputSize {
+		return "", extractionError(ErrorCodeTooLarge, ocr.ErrInputTooLarge)
+	}
+
+	mimeType := ocr.DetectMIME(data, declaredMIME)
+	var text string
+	switch {
+	case mailparse.IsEmail(mimeType):
+		msg, err := mailparse.Parse(data)
+		if err != nil {
+			return "", extractionError(ErrorCodeNoText, err)
+		}
+		text = msg.Text()
+
+	case mimeType == "text/plain":
+		text = string(data)
+
+	case mimeType == ocr.MIMEPDF:
+		text = s.extractPDFText(ctx, analysis, data, useOCR)
+
+	case mimeType == ocr.MIMEDOCX || mimeType == ocr.MIMEXLSX:
+		var err error
+		if mimeType == ocr.MIMEDOCX {
+			text, err = ocr.ExtractDOCXText(data)
+		} else {
+			text, err = ocr.ExtractXLSXText(data)
+		}
+		if errors.Is(err, ocr.ErrArchiveTooLarge) {
+			return "", extractionError(ErrorCodeTooLarge, err)
+		}
+		if err != nil {
+			return "", extractionError(ErrorCodeNoText, err)
+		}
+
+	case ocr.IsImage(mimeType):
+		if s.ocrService == nil {
+			return "", extractionError(ErrorCodeOCRUnavailable, fmt.Errorf("OCR is not configured for %s", mimeType))
+		}
+		result, err := s.ocrService.ProcessImage(ctx, data, mimeType)
+		if errors.Is(err, ocr.ErrInputTooLarge) {
+			return "", extractionError(ErrorCodeTooLarge, err)
+		}
+		if err != nil {
+			return "", extractionError(ErrorCodeNoText, err)
+		}
+		text = result.Text
+		analysis.IsScanned = true
+		analysis.OCRProvider = string(result.Provider)
+		analysis.OCRConfidence = result.Confidence
+		analysis.PageCount = 1
+
+	default:
+		return "", extractionError(ErrorCodeUnsupportedFormat, fmt.Errorf("%w: %s", ocr.ErrUnsupportedFormat, mimeType))
+	}
+
+	text = ocr.NormalizeText(text)
+	if text == "" {
+		return "", extractionError(ErrorCodeNoText, errors.New("no text could be extracted from document"))
+	}
+	return text, nil
+}
+
+// extractPDFText uses OCR where the PDF needs it and the embedded text
+// otherwise. OCR errors are kept as a warning on the analysis.
+func (s *Service) extractPDFText(ctx context.Context, analysis *Analysis, data []byte, useOCR bool) string {
+	var text string
+	if useOCR && s.ocrService != nil {
+		ocrResult, err := s.ocrService.ProcessBytes(ctx, data)
+		if err != nil {
+			// Log OCR error but continue with what we have
+			analysis.ErrorMessage = fmt.Sprintf("OCR warning: %v", err)
+		} else {
+			text = ocrResult.Text
+			analysis.IsScanned = ocrResult.Provider != ocr.ProviderNone
+			analysis.OCRProvider = string(ocrResult.Provider)
+			analysis.OCRConfidence = ocrResult.Confidence
+			analysis.PageCount = len(ocrResult.PageTexts)
+		}
+	}
+
+	// If no text from OCR, try direct extraction
+	if text == "" {
+		extracted, err := ocr.ExtractPDFTextFromBytes(data)
+		if err == nil {
+			text = extracted
+		}
+	}
+	return text
+}
@@ -0,0 +1,199 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Extracted texts of some MB bloat document_analyses. Large texts are
+// compacted: the full text goes zstd-compressed into document_analysis_texts
+// and the analysis row keeps a preview. The repository restores the full
+// text when a single analysis is loaded; lists carry the preview only.
+const (
+	// TextCompactThreshold is the text size in bytes from which a text is
+	// compacted when it is saved
+	TextCompactThreshold = 64 * 1024
+
+	// TextCompactAfterDays is the analysis age from which the compaction job
+	// also compacts texts below the threshold that are longer than a preview
+	TextCompactAfterDays = 180
+
+	// TextPreviewRunes is the length of the preview kept in the analysis row
+	TextPreviewRunes = 2000
+)
+
+var (
+	textEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	textDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+)
+
+// CompressText compresses an extracted text with zstd
+func CompressText(text string) []byte {
+	return textEncoder.EncodeAll([]byte(text), nil)
+}
+
+// DecompressText restores a text compressed with CompressText
+func DecompressText(data []byte) (string, error) {
+	text, err := textDecoder.DecodeAll(data, nil)
+	if err != nil {
+		return "", fmt.Errorf("decompress text: %w", err)
+	}
+	return string(text), nil
+}
+
+// TextPreview returns the first TextPreviewRunes characters of a text
+func TextPreview(text string) string {
+	if utf8.RuneCountInString(text) <= TextPreviewRunes {
+		return text
+	}
+	n := 0
+	for i := range text {
+		if n == TextPreviewRunes {
+			return text[:i]
+		}
+		n++
+	}
+	return text
+}
+
+// shouldCompact reports whether a text is stored compacted when it is saved
+func shouldCompact(text string) bool {
+	return len(text) >= TextCompactThreshold
+}
+
+// storedText returns what goes into the extracted_text column
+func storedText(text string, compacted bool) string {
+	if compacted {
+		return TextPreview(text)
+	}
+	return text
+}
+
+// saveFullText writes or removes the compacted text of an analysis and
+// returns the compressed size
+func saveFullText(ctx context.Context, tx pgx.Tx, a *Analysis, compacted bool) (int, error) {
+	if !compacted {
+		_, err := tx.Exec(ctx, `DELETE FROM document_analysis_texts WHERE analysis_id = $1`, a.ID)
+		return 0, err
+	}
+	content := CompressText(a.ExtractedText)
+	_, err := tx.Exec(ctx, `
+		INSERT INTO document_analysis_texts (analysis_id, tenant_id, encoding, content, original_bytes)
+		VALUES ($1, $2, 'zstd', $3, $4)
+		ON CONFLICT (analysis_id) DO UPDATE SET
+			content = EXCLUDED.content,
+			original_bytes = EXCLUDED.original_bytes,
+			created_at = NOW()
+	`, a.ID, a.TenantID, content, len(a.ExtractedText))
+	return len(content), err
+}
+
+// restoreText replaces the preview of a compacted analysis with its full text
+func (r *Repository) restoreText(ctx context.Context, a *Analysis) error {
+	if a == nil || !a.TextCompacted {
+		return nil
+	}
+	var content []byte
+	err := r.db.QueryRow(ctx, `SELECT content FROM document_analysis_texts WHERE analysis_id = $1`, a.ID).Scan(&content)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("compacted text of analysis %s is missing", a.ID)
+		}
+		return fmt.Errorf("get compacted text: %w", err)
+	}
+	text, err := DecompressText(content)
+	if err != nil {
+		return err
+	}
+	a.ExtractedText = text
+	a.textRestored = true
+	return nil
+}
+
+// GetExtractedText returns the full extracted text of an analysis
+func (r *Repository) GetExtractedText(ctx context.Context, id uuid.UUID) (string, error) {
+	a, err := r.GetAnalysisByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return a.ExtractedText, nil
+}
+
+// TextCompactionResult summarizes a compaction batch
+type TextCompactionResult struct {
+	Compacted       int   `json:"compacted"`
+	OriginalBytes   int64 `json:"original_bytes"`
+	CompressedBytes int64 `json:"compressed_bytes"`
+}
+
+// CompactTexts compacts up to limit stored texts that are over the threshold,
+// or longer than a preview and older than before. Rows locked by a
+// concurrent save are skipped.
+func (r *Repository) CompactTexts(ctx context.Context, before time.Time, limit int) (*TextCompactionResult, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, tenant_id, extracted_text
+		FROM document_analyses
+		WHERE NOT text_compacted AND extracted_text IS NOT NULL
+		  AND (octet_length(extracted_text) >= $1
+		       OR (created_at < $2 AND char_length(extracted_text) > $3))
+		ORDER BY created_at
+		LIMIT $4
+		FOR UPDATE SKIP LOCKED
+	`, TextCompactThreshold, before, TextPreviewRunes, limit)
+	if err != nil {
+		return nil, fmt.Errorf("select texts to compact: %w", err)
+	}
+
+	var analyses []*Analysis
+	for rows.Next() {
+		a := &Analysis{}
+		if err := rows.Scan(&a.ID, &a.TenantID, &a.ExtractedText); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan text to compact: %w", err)
+		}
+		analyses = append(analyses, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate texts to compact: %w", err)
+	}
+
+	result := &TextCompactionResult{}
+	for _, a := range analyses {
+		compressed, err := saveFullText(ctx, tx, a, true)
+		if err != nil {
+			return nil, fmt.Errorf("save compacted text: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE document_analyses SET extracted_text = $2, text_compacted = TRUE
+			WHERE id = $1
+		`, a.ID, TextPreview(a.ExtractedText))
+		if err != nil {
+			return nil, fmt.Errorf("replace text with preview: %w", err)
+		}
+		result.Compacted++
+		result.OriginalBytes += int64(len(a.ExtractedText))
+		result.CompressedBytes += int64(compressed)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,116 @@
+// Package analytics emits product analytics events (sign-ups, connected
+// accounts, first analyses, filings) for funnel and adoption metrics. Events
+// never carry personal data: tenants and users are pseudonymized with a
+// keyed hash, and properties are restricted to short categorical values and
+// numbers.
+package analytics
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event names
+const (
+	EventTenantCreated     = "tenant.created"
+	EventUserJoined        = "user.joined"
+	EventAccountCreated    = "account.created"
+	EventDocumentAdded     = "document.added"
+	EventAnalysisCompleted = "analysis.completed"
+	EventFilingSubmitted   = "filing.submitted"
+	EventInvoiceCreated    = "invoice.created"
+)
+
+// FunnelSteps are the activation steps of a new tenant, in order
+var FunnelSteps = []string{
+	EventAccountCreated,
+	EventDocumentAdded,
+	EventAnalysisCompleted,
+	EventFilingSubmitted,
+}
+
+// maxValueLength bounds string properties; longer values are free text
+const maxValueLength = 64
+
+var propertyKey = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// Properties describe an event. Only booleans, numbers and short strings
+// are kept.
+type Properties map[string]any
+
+// Event is one pseudonymized analytics event
+type Event struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Tenant     string     `json:"tenant"`
+	User       string     `json:"user,omitempty"`
+	Properties Properties `json:"properties"`
+	OccurredAt time.Time  `json:"occurred_at"`
+}
+
+// Sink delivers batches of events
+type Sink interface {
+	Send(ctx context.Context, events []*Event) error
+}
+
+// Pseudonymizer replaces tenant and user IDs by keyed hashes. The same key
+// always yields the same pseudonym, so funnels can follow a tenant over
+// time, but the IDs cannot be recovered without the key.
+type Pseudonymizer struct {
+	key []byte
+}
+
+// NewPseudonymizer creates a pseudonymizer
+func NewPseudonymizer(key []byte) *Pseudonymizer {
+	return &Pseudonymizer{key: key}
+}
+
+// Tenant returns the pseudonym of a tenant
+func (p *Pseudonymizer) Tenant(id uuid.UUID) string {
+	return p.hash("tenant", id)
+}
+
+// User returns the pseudonym of a user, or "" for uuid.Nil
+func (p *Pseudonymizer) User(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return p.hash("user", id)
+}
+
+func (p *Pseudonymizer) hash(kind string, id uuid.UUID) string {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(kind))
+	mac.Write(id[:])
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// Sanitize drops properties that could carry personal data: keys that are
+// not snake_case identifiers, values that are neither booleans, numbers nor
+// short strings, and strings that look like e-mail addresses or free text.
+func Sanitize(props Properties) Properties {
+	clean := make(Properties, len(props))
+	for key, value := range props {
+		if !propertyKey.MatchString(key) {
+			continue
+		}
+		switch v := value.(type) {
+		case bool, int, int32, int64, float64:
+			clean[key] = v
+		case string:
+			v = strings.TrimSpace(v)
+			if v == "" || len(v) > maxValueLength || strings.ContainsAny(v, "@ \t\n") {
+				continue
+			}
+			clean[key] = v
+		}
+	}
+	return clean
+}
@@ -0,0 +1,225 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/config"
+)
+
+// Config configures an emitter
+type Config struct {
+	// Key derives the pseudonyms; all API instances need the same key
+	Key           []byte
+	BatchSize     int
+	FlushInterval time.Duration
+	BufferSize    int
+	// Retention is how long a sink that stores events keeps them; 0 keeps them
+	Retention time.Duration
+}
+
+// pruner is implemented by sinks that store events
+type pruner interface {
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Emitter queues events and delivers them to a sink in batches in the
+// background, so tracking never slows down or fails the action it records.
+// A nil emitter drops all events; services call Track unconditionally.
+type Emitter struct {
+	sink   Sink
+	pseudo *Pseudonymizer
+	config Config
+	logger *slog.Logger
+
+	queue   chan *Event
+	wg      sync.WaitGroup
+	dropped atomic.Int64
+}
+
+// NewEmitter creates an emitter and starts its worker
+func NewEmitter(sink Sink, config Config, logger *slog.Logger) *Emitter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 10 * time.Second
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 10000
+	}
+
+	e := &Emitter{
+		sink:   sink,
+		pseudo: NewPseudonymizer(config.Key),
+		config: config,
+		logger: logger.With("component", "analytics"),
+		queue:  make(chan *Event, config.BufferSize),
+	}
+	e.wg.Add(1)
+	go e.loop()
+	return e
+}
+
+// Track queues an event. Without a user ID the authenticated user of the
+// request is used, if any.
+func (e *Emitter) Track(ctx context.Context, tenantID, userID uuid.UUID, name string, props Properties) {
+	if e == nil || tenantID == uuid.Nil {
+		return
+	}
+	if userID == uuid.Nil {
+		userID, _ = uuid.Parse(api.GetUserID(ctx))
+	}
+
+	event := &Event{
+		ID:         uuid.New(),
+		Name:       name,
+		Tenant:     e.pseudo.Tenant(tenantID),
+		User:       e.pseudo.User(userID),
+		Properties: Sanitize(props),
+		OccurredAt: time.Now().UTC(),
+	}
+	select {
+	case e.queue <- event:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of events dropped because the queue was full
+func (e *Emitter) Dropped() int64 {
+	if e == nil {
+		return 0
+	}
+	return e.dropped.Load()
+}
+
+// Close stops accepting events and flushes the queue
+func (e *Emitter) Close() {
+	if e == nil {
+		return
+	}
+	close(e.queue)
+	e.wg.Wait()
+}
+
+func (e *Emitter) loop() {
+	defer e.wg.Done()
+
+	flush := time.NewTicker(e.config.FlushInterval)
+	defer flush.Stop()
+	prune := time.NewTicker(time.Hour)
+	defer prune.Stop()
+
+	var batch []*Event
+	for {
+		select {
+		case event, ok := <-e.queue:
+			if !ok {
+				e.deliver(batch)
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= e.config.BatchSize {
+				e.deliver(batch)
+				batch = nil
+			}
+		case <-flush.C:
+			e.deliver(batch)
+			batch = nil
+		case <-prune.C:
+			e.prune()
+		}
+	}
+}
+
+// deliver sends a batch, retrying with backoff. Analytics are best effort:
+// a batch that still fails is logged and dropped.
+func (e *Emitter) deliver(batch []*Event) {
+	if len(batch) == 0 {
+		return
+	}
+
+	var err error
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err = e.sink.Send(ctx, batch)
+		cancel()
+		if err == nil {
+			return
+		}
+		if attempt == 3 {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	e.dropped.Add(int64(len(batch)))
+	e.logger.Error("failed to deliver analytics events", "events", len(batch), "error", err)
+}
+
+func (e *Emitter) prune() {
+	p, ok := e.sink.(pruner)
+	if !ok || e.config.Retention <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	deleted, err := p.DeleteBefore(ctx, time.Now().Add(-e.config.Retention))
+	if err != nil {
+		e.logger.Error("failed to prune analytics events", "error", err)
+		return
+	}
+	if deleted > 0 {
+		e.logger.Info("pruned analytics events", "deleted", deleted)
+	}
+}
+
+// Sink names
+const (
+	SinkPostgres = "postgres"
+	SinkSegment  = "segment"
+)
+
+// NewFromConfig creates the emitter configured by cfg, or returns nil when
+// analytics are disabled
+func NewFromConfig(pool *pgxpool.Pool, cfg *config.AnalyticsConfig, logger *slog.Logger) (*Emitter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.PseudonymKey == "" {
+		return nil, fmt.Errorf("ANALYTICS_PSEUDONYM_KEY is required when analytics are enabled")
+	}
+
+	var sink Sink
+	switch cfg.Sink {
+	case SinkPostgres:
+		sink = NewRepository(pool)
+	case SinkSegment:
+		if cfg.SegmentWriteKey == "" {
+			return nil, fmt.Errorf("ANALYTICS_SEGMENT_WRITE_KEY is required for the segment sink")
+		}
+		sink = NewSegmentSink(cfg.SegmentEndpoint, cfg.SegmentWriteKey)
+	default:
+		return nil, fmt.Errorf("unknown ANALYTICS_SINK %q", cfg.Sink)
+	}
+
+	return NewEmitter(sink, Config{
+		Key:           []byte(cfg.PseudonymKey),
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: cfg.FlushInterval,
+		Retention:     cfg.Retention,
+	}, logger), nil
+}
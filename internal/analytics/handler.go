@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler serves the funnel and adoption metrics of the Postgres sink. They
+// cover the whole platform and are authenticated with the maintenance
+// token, not a tenant session.
+type Handler struct {
+	repo   *Repository
+	token  string
+	logger *slog.Logger
+}
+
+// NewHandler creates a new analytics handler
+func NewHandler(repo *Repository, token string, logger *slog.Logger) *Handler {
+	return &Handler{repo: repo, token: token, logger: logger}
+}
+
+// RegisterRoutes registers the analytics routes
+func (h *Handler) RegisterRoutes(router *api.Router) {
+	router.Handle("GET /api/v1/maintenance/analytics/funnel", h.requireToken(h.Funnel))
+	router.Handle("GET /api/v1/maintenance/analytics/adoption", h.requireToken(h.Adoption))
+}
+
+func (h *Handler) requireToken(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !api.BearerTokenMatches(r, h.token) {
+			api.JSONError(w, http.StatusUnauthorized, "Invalid maintenance token", api.ErrCodeUnauthorized)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// period reads from and to (YYYY-MM-DD, to inclusive), defaulting to the
+// last 30 days
+func period(r *http.Request) (time.Time, time.Time, bool) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from, to := today.AddDate(0, 0, -29), today
+	q := r.URL.Query()
+	if s := q.Get("from"); s != "" {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return from, to, false
+		}
+		from = t
+	}
+	if s := q.Get("to"); s != "" {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return from, to, false
+		}
+		to = t
+	}
+	return from, to.AddDate(0, 0, 1), !to.Before(from)
+}
+
+// Funnel handles GET /api/v1/maintenance/analytics/funnel?from=&to=
+func (h *Handler) Funnel(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := period(r)
+	if !ok {
+		api.BadRequest(w, "Invalid period")
+		return
+	}
+	funnel, err := h.repo.Funnel(r.Context(), from, to)
+	if err != nil {
+		h.logger.Error("failed to compute funnel", "error", err)
+		api.InternalError(w)
+		return
+	}
+	api.JSONResponse(w, http.StatusOK, funnel)
+}
+
+// Adoption handles GET /api/v1/maintenance/analytics/adoption?from=&to=
+func (h *Handler) Adoption(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := period(r)
+	if !ok {
+		api.BadRequest(w, "Invalid period")
+		return
+	}
+	adoption, err := h.repo.Adoption(r.Context(), from, to)
+	if err != nil {
+		h.logger.Error("failed to compute adoption", "error", err)
+		api.InternalError(w)
+		return
+	}
+	api.JSONResponse(w, http.StatusOK, map[string]any{"from": from, "to": to, "events": adoption})
+}
@@ -0,0 +1,167 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// FunnelStep is how many tenants of a cohort reached a step, and how long
+// after sign-up they first did
+type FunnelStep struct {
+	Event   string  `json:"event"`
+	Tenants int     `json:"tenants"`
+	Rate    float64 `json:"rate"`
+	// Median and P90 are hours from sign-up to the first event
+	MedianHours *float64 `json:"median_hours,omitempty"`
+	P90Hours    *float64 `json:"p90_hours,omitempty"`
+}
+
+// Funnel is the activation funnel of the tenants that signed up in a period
+type Funnel struct {
+	From    time.Time     `json:"from"`
+	To      time.Time     `json:"to"`
+	SignUps int           `json:"sign_ups"`
+	Steps   []*FunnelStep `json:"steps"`
+}
+
+// Adoption is how many tenants used a feature in a period
+type Adoption struct {
+	Event   string `json:"event"`
+	Tenants int    `json:"tenants"`
+	Events  int    `json:"events"`
+}
+
+// Repository stores events in Postgres. It is the default sink and answers
+// the funnel and adoption queries.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new analytics repository
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Send stores a batch of events. It implements Sink.
+func (r *Repository) Send(ctx context.Context, events []*Event) error {
+	batch := &pgx.Batch{}
+	for _, e := range events {
+		props, err := json.Marshal(e.Properties)
+		if err != nil {
+			return fmt.Errorf("failed to encode properties: %w", err)
+		}
+		batch.Queue(`
+			INSERT INTO analytics_events (id, name, tenant_hash, user_hash, properties, occurred_at)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+			ON CONFLICT (id) DO NOTHING
+		`, e.ID, e.Name, e.Tenant, e.User, props, e.OccurredAt)
+	}
+	return r.pool.SendBatch(ctx, batch).Close()
+}
+
+// DeleteBefore deletes events older than before
+func (r *Repository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM analytics_events WHERE occurred_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete analytics events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// cohortSQL selects the tenants whose first tenant.created event is in
+// [$2, $3) and when it happened
+const cohortSQL = `
+	SELECT tenant_hash, MIN(occurred_at) AS signed_up
+	FROM analytics_events
+	WHERE name = $1
+	GROUP BY tenant_hash
+	HAVING MIN(occurred_at) >= $2 AND MIN(occurred_at) < $3
+`
+
+// Funnel returns the activation funnel of the tenants that signed up in
+// [from, to). Later steps are counted up to now.
+func (r *Repository) Funnel(ctx context.Context, from, to time.Time) (*Funnel, error) {
+	funnel := &Funnel{From: from, To: to}
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM (`+cohortSQL+`) cohort`,
+		EventTenantCreated, from, to).Scan(&funnel.SignUps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sign-ups: %w", err)
+	}
+
+	query := `
+		WITH cohort AS (` + cohortSQL + `), firsts AS (
+			SELECT e.tenant_hash, e.name, MIN(e.occurred_at) AS first_at
+			FROM analytics_events e
+			JOIN cohort c ON c.tenant_hash = e.tenant_hash
+			WHERE e.name = ANY($4)
+			GROUP BY e.tenant_hash, e.name
+		)
+		SELECT f.name, COUNT(*),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM f.first_at - c.signed_up)) / 3600,
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM f.first_at - c.signed_up)) / 3600
+		FROM firsts f
+		JOIN cohort c ON c.tenant_hash = f.tenant_hash
+		GROUP BY f.name
+	`
+	rows, err := r.pool.Query(ctx, query, EventTenantCreated, from, to, FunnelSteps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query funnel: %w", err)
+	}
+	defer rows.Close()
+
+	reached := make(map[string]*FunnelStep)
+	for rows.Next() {
+		step := &FunnelStep{}
+		var median, p90 float64
+		if err := rows.Scan(&step.Event, &step.Tenants, &median, &p90); err != nil {
+			return nil, fmt.Errorf("failed to scan funnel step: %w", err)
+		}
+		step.MedianHours, step.P90Hours = &median, &p90
+		reached[step.Event] = step
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate funnel: %w", err)
+	}
+
+	for _, name := range FunnelSteps {
+		step, ok := reached[name]
+		if !ok {
+			step = &FunnelStep{Event: name}
+		}
+		if funnel.SignUps > 0 {
+			step.Rate = float64(step.Tenants) / float64(funnel.SignUps)
+		}
+		funnel.Steps = append(funnel.Steps, step)
+	}
+	return funnel, nil
+}
+
+// Adoption counts the tenants and events per event name in [from, to)
+func (r *Repository) Adoption(ctx context.Context, from, to time.Time) ([]*Adoption, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT name, COUNT(DISTINCT tenant_hash), COUNT(*)
+		FROM analytics_events
+		WHERE occurred_at >= $1 AND occurred_at < $2
+		GROUP BY name
+		ORDER BY name
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query adoption: %w", err)
+	}
+	defer rows.Close()
+
+	adoption := []*Adoption{}
+	for rows.Next() {
+		a := &Adoption{}
+		if err := rows.Scan(&a.Event, &a.Tenants, &a.Events); err != nil {
+			return nil, fmt.Errorf("failed to scan adoption: %w", err)
+		}
+		adoption = append(adoption, a)
+	}
+	return adoption, rows.Err()
+}
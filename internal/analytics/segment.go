@@ -0,0 +1,96 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultSegmentEndpoint is the Segment HTTP tracking API
+const DefaultSegmentEndpoint = "https://api.segment.io"
+
+// SegmentSink posts events to the batch endpoint of the Segment HTTP
+// tracking API or a compatible collector (RudderStack, Jitsu, ...).
+// The tenant pseudonym is sent as the group, so tools can build funnels per
+// tenant; events without a user use it as the anonymous ID.
+type SegmentSink struct {
+	endpoint   string
+	writeKey   string
+	httpClient *http.Client
+}
+
+// NewSegmentSink creates a sink for the collector at endpoint (the API
+// root; /v1/batch is appended)
+func NewSegmentSink(endpoint, writeKey string) *SegmentSink {
+	if endpoint == "" {
+		endpoint = DefaultSegmentEndpoint
+	}
+	return &SegmentSink{
+		endpoint:   strings.TrimSuffix(endpoint, "/") + "/v1/batch",
+		writeKey:   writeKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type segmentMessage struct {
+	Type        string         `json:"type"`
+	MessageID   string         `json:"messageId"`
+	Event       string         `json:"event"`
+	UserID      string         `json:"userId,omitempty"`
+	AnonymousID string         `json:"anonymousId,omitempty"`
+	Properties  Properties     `json:"properties"`
+	Context     map[string]any `json:"context"`
+	Timestamp   time.Time      `json:"timestamp"`
+}
+
+// EncodeSegmentBatch encodes events as the body of a Segment batch request
+func EncodeSegmentBatch(events []*Event, sentAt time.Time) ([]byte, error) {
+	messages := make([]segmentMessage, len(events))
+	for i, e := range events {
+		messages[i] = segmentMessage{
+			Type:       "track",
+			MessageID:  e.ID.String(),
+			Event:      e.Name,
+			UserID:     e.User,
+			Properties: e.Properties,
+			Context:    map[string]any{"groupId": e.Tenant},
+			Timestamp:  e.OccurredAt,
+		}
+		if e.User == "" {
+			messages[i].AnonymousID = e.Tenant
+		}
+	}
+	return json.Marshal(map[string]any{"batch": messages, "sentAt": sentAt.UTC()})
+}
+
+// Send posts a batch of events. It implements Sink.
+func (s *SegmentSink) Send(ctx context.Context, events []*Event) error {
+	body, err := EncodeSegmentBatch(events, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(s.writeKey, "")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
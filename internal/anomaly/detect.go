@@ -0,0 +1,291 @@
+package anomaly
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NormalizeIBAN strips spaces and upper-cases an IBAN
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(iban), " ", ""))
+}
+
+// CounterpartyKey returns the key of an invoice buyer: its ID if the buyer
+// is in the master data, its normalized name otherwise
+func CounterpartyKey(buyerID *uuid.UUID, buyerName string) string {
+	if buyerID != nil {
+		return buyerID.String()
+	}
+	return strings.ToLower(strings.Join(strings.Fields(buyerName), " "))
+}
+
+func details(v map[string]any) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func eur(cents int64) string {
+	return fmt.Sprintf("%.2f EUR", float64(cents)/100)
+}
+
+// DuplicatePayments flags payments that repeat an earlier payment of the
+// same amount to the same IBAN within window. Payment items and bank lines
+// are compared among themselves only, since a transfer shows up in both.
+// Each later payment becomes one finding listing the payments it repeats.
+func DuplicatePayments(payments []Payment, since time.Time, window time.Duration) []*Finding {
+	type key struct {
+		source, iban string
+		amount       int64
+	}
+	groups := make(map[key][]Payment)
+	for _, p := range payments {
+		iban := NormalizeIBAN(p.IBAN)
+		if iban == "" || p.Amount <= 0 {
+			continue
+		}
+		k := key{p.Source, iban, p.Amount}
+		groups[k] = append(groups[k], p)
+	}
+
+	var findings []*Finding
+	for k, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool {
+			if !group[i].Date.Equal(group[j].Date) {
+				return group[i].Date.Before(group[j].Date)
+			}
+			return group[i].ID.String() < group[j].ID.String()
+		})
+		for i, p := range group {
+			if p.Date.Before(since) {
+				continue
+			}
+			var earlier []uuid.UUID
+			sameDay := false
+			for _, q := range group[:i] {
+				if p.Date.Sub(q.Date) <= window {
+					earlier = append(earlier, q.ID)
+					sameDay = sameDay || p.Date.Equal(q.Date)
+				}
+			}
+			if len(earlier) == 0 {
+				continue
+			}
+
+			severity := SeverityMedium
+			if sameDay {
+				severity = SeverityHigh
+			}
+			amount := p.Amount
+			findings = append(findings, &Finding{
+				Kind:         KindDuplicatePayment,
+				Severity:     severity,
+				Fingerprint:  p.ID.String(),
+				SubjectType:  p.Source,
+				SubjectID:    p.ID,
+				RelatedIDs:   earlier,
+				Title:        fmt.Sprintf("Possible duplicate payment of %s to %s", eur(amount), k.iban),
+				AmountCents:  &amount,
+				Counterparty: p.Counterparty,
+				Details: details(map[string]any{
+					"iban":       k.iban,
+					"date":       p.Date.Format("2006-01-02"),
+					"reference":  p.Reference,
+					"duplicates": len(earlier),
+				}),
+			})
+		}
+	}
+	sortFindings(findings)
+	return findings
+}
+
+// UnusualAmounts flags invoices issued since the given time whose amount is
+// far off the earlier invoices to the same counterparty. Amounts are judged
+// by their modified z-score (median and median absolute deviation), so a few
+// outliers in the history do not hide a new one. Counterparties with fewer
+// than minHistory earlier invoices are skipped.
+func UnusualAmounts(invoices []Invoice, since time.Time, minHistory int, maxScore float64) []*Finding {
+	byCounterparty := make(map[string][]Invoice)
+	for _, inv := range invoices {
+		if inv.Counterparty == "" || inv.Amount <= 0 {
+			continue
+		}
+		byCounterparty[inv.Counterparty] = append(byCounterparty[inv.Counterparty], inv)
+	}
+
+	var findings []*Finding
+	for _, group := range byCounterparty {
+		sort.Slice(group, func(i, j int) bool {
+			if !group[i].IssueDate.Equal(group[j].IssueDate) {
+				return group[i].IssueDate.Before(group[j].IssueDate)
+			}
+			return group[i].ID.String() < group[j].ID.String()
+		})
+		for i, inv := range group {
+			if inv.IssueDate.Before(since) || i < minHistory {
+				continue
+			}
+			history := make([]float64, i)
+			for j, prev := range group[:i] {
+				history[j] = float64(prev.Amount)
+			}
+			score, median := ModifiedZScore(float64(inv.Amount), history)
+			if math.Abs(score) <= maxScore {
+				continue
+			}
+
+			ratio := float64(inv.Amount) / median
+			severity := SeverityMedium
+			if ratio >= 10 || ratio <= 0.1 {
+				severity = SeverityHigh
+			}
+			direction := "higher"
+			if score < 0 {
+				direction = "lower"
+			}
+			amount := inv.Amount
+			findings = append(findings, &Finding{
+				Kind:         KindUnusualAmount,
+				Severity:     severity,
+				Fingerprint:  inv.ID.String(),
+				SubjectType:  SubjectInvoice,
+				SubjectID:    inv.ID,
+				Title:        fmt.Sprintf("Invoice %s of %s is much %s than usual for %s", inv.Number, eur(amount), direction, inv.BuyerName),
+				AmountCents:  &amount,
+				Counterparty: inv.BuyerName,
+				Details: details(map[string]any{
+					"invoice_number": inv.Number,
+					"median_cents":   int64(math.Round(median)),
+					"ratio":          math.Round(ratio*100) / 100,
+					"score":          math.Round(score*10) / 10,
+					"history_count":  len(history),
+				}),
+			})
+		}
+	}
+	sortFindings(findings)
+	return findings
+}
+
+// ModifiedZScore returns the modified z-score of x against values and the
+// median of values. If most values are equal, so that the median absolute
+// deviation is zero, the mean absolute deviation is used instead; if all are
+// equal, any difference scores as infinitely far off.
+func ModifiedZScore(x float64, values []float64) (score, median float64) {
+	median = medianOf(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	if mad := medianOf(deviations); mad > 0 {
+		return 0.6745 * (x - median) / mad, median
+	}
+
+	var sum float64
+	for _, d := range deviations {
+		sum += d
+	}
+	if meanAD := sum / float64(len(values)); meanAD > 0 {
+		return (x - median) / (1.253314 * meanAD), median
+	}
+	switch {
+	case x > median:
+		return math.Inf(1), median
+	case x < median:
+		return math.Inf(-1), median
+	}
+	return 0, median
+}
+
+func medianOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// UnknownCounterparties flags bank lines whose counterparty IBAN is not in
+// known. Lines to the same unknown IBAN form one finding, so reviewing it
+// settles the counterparty. Outgoing lines weigh more than incoming ones.
+func UnknownCounterparties(lines []BankLine, known map[string]bool) []*Finding {
+	byIBAN := make(map[string][]BankLine)
+	var order []string
+	for _, line := range lines {
+		iban := NormalizeIBAN(line.IBAN)
+		if iban == "" || known[iban] {
+			continue
+		}
+		if _, ok := byIBAN[iban]; !ok {
+			order = append(order, iban)
+		}
+		byIBAN[iban] = append(byIBAN[iban], line)
+	}
+
+	findings := make([]*Finding, 0, len(order))
+	for _, iban := range order {
+		group := byIBAN[iban]
+		sort.Slice(group, func(i, j int) bool { return group[i].BookingDate.Before(group[j].BookingDate) })
+
+		first := group[0]
+		var related []uuid.UUID
+		var total int64
+		severity := SeverityLow
+		for _, line := range group {
+			if line.ID != first.ID {
+				related = append(related, line.ID)
+			}
+			if line.Debit {
+				total -= line.Amount
+				severity = SeverityMedium
+			} else {
+				total += line.Amount
+			}
+		}
+
+		name := first.Counterparty
+		if name == "" {
+			name = iban
+		}
+		findings = append(findings, &Finding{
+			Kind:         KindUnknownCounterparty,
+			Severity:     severity,
+			Fingerprint:  iban,
+			SubjectType:  SubjectBankTransaction,
+			SubjectID:    first.ID,
+			RelatedIDs:   related,
+			Title:        fmt.Sprintf("Bank line with unknown counterparty %s", name),
+			AmountCents:  &total,
+			Counterparty: first.Counterparty,
+			Details: details(map[string]any{
+				"iban":       iban,
+				"lines":      len(group),
+				"first_date": first.BookingDate.Format("2006-01-02"),
+				"reference":  first.Reference,
+			}),
+		})
+	}
+	return findings
+}
+
+// sortFindings orders findings by subject for stable results
+func sortFindings(findings []*Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].SubjectID.String() < findings[j].SubjectID.String()
+	})
+}
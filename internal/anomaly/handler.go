@@ -0,0 +1,175 @@
+package anomaly
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler handles anomaly finding HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new anomaly handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers anomaly routes. Findings are reviewed by any user
+// of the tenant; an on-demand scan is admin-only.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/anomalies", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("POST /api/v1/anomalies/scan", requireAuth(requireAdmin(http.HandlerFunc(h.Scan))))
+	router.Handle("GET /api/v1/anomalies/{id}", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("POST /api/v1/anomalies/{id}/confirm", requireAuth(http.HandlerFunc(h.Confirm)))
+	router.Handle("POST /api/v1/anomalies/{id}/dismiss", requireAuth(http.HandlerFunc(h.Dismiss)))
+}
+
+// ReviewRequest is the optional body of a confirm or dismiss request
+type ReviewRequest struct {
+	Note string `json:"note"`
+}
+
+// List handles GET /api/v1/anomalies
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	filter := ListFilter{TenantID: tenantID, Limit: 50}
+	query := r.URL.Query()
+	if status := query.Get("status"); status != "" {
+		if status != StatusOpen && status != StatusConfirmed && status != StatusDismissed {
+			api.BadRequest(w, "status must be open, confirmed or dismissed")
+			return
+		}
+		filter.Status = status
+	}
+	if kind := query.Get("kind"); kind != "" {
+		if !IsValidKind(kind) {
+			api.BadRequest(w, "invalid kind")
+			return
+		}
+		filter.Kind = kind
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	findings, total, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if findings == nil {
+		findings = []*Finding{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  findings,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// Get handles GET /api/v1/anomalies/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	finding, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, finding)
+}
+
+// Confirm handles POST /api/v1/anomalies/{id}/confirm
+func (h *Handler) Confirm(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, StatusConfirmed)
+}
+
+// Dismiss handles POST /api/v1/anomalies/{id}/dismiss
+func (h *Handler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, StatusDismissed)
+}
+
+func (h *Handler) review(w http.ResponseWriter, r *http.Request, status string) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	var finding *Finding
+	var err error
+	if status == StatusConfirmed {
+		finding, err = h.service.Confirm(r.Context(), tenantID, id, api.OptionalUser(r), req.Note)
+	} else {
+		finding, err = h.service.Dismiss(r.Context(), tenantID, id, api.OptionalUser(r), req.Note)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, finding)
+}
+
+// Scan handles POST /api/v1/anomalies/scan
+func (h *Handler) Scan(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Scan(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, result)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrFindingNotFound):
+		api.NotFound(w, "finding not found")
+	case errors.Is(err, ErrFindingReviewed):
+		api.Conflict(w, err.Error())
+	default:
+		api.InternalError(w)
+	}
+}
@@ -0,0 +1,304 @@
+package anomaly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles anomaly database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new anomaly repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// ActiveTenants returns the tenants with invoices, payment batches or bank
+// statements created since the given time
+func (r *Repository) ActiveTenants(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT tenant_id FROM invoices WHERE created_at >= $1
+		UNION
+		SELECT tenant_id FROM payment_batches WHERE created_at >= $1
+		UNION
+		SELECT tenant_id FROM bank_statements WHERE created_at >= $1`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, id)
+	}
+	return tenants, rows.Err()
+}
+
+// OutgoingPayments returns the credit transfer items and the debit bank
+// lines of a tenant dated on or after from
+func (r *Repository) OutgoingPayments(ctx context.Context, tenantID uuid.UUID, from time.Time) ([]Payment, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT i.id, $3::text, i.creditor_iban, i.creditor_name, i.amount,
+			COALESCE(b.execution_date, b.created_at::date), COALESCE(i.remittance_info, '')
+		FROM payment_items i
+		JOIN payment_batches b ON b.id = i.batch_id
+		WHERE b.tenant_id = $1 AND b.type = 'pain.001' AND b.status <> 'failed'
+			AND COALESCE(b.execution_date, b.created_at::date) >= $2
+		UNION ALL
+		SELECT t.id, $4::text, COALESCE(t.counterparty_iban, ''), COALESCE(t.counterparty_name, ''), t.amount,
+			t.booking_date, COALESCE(t.remittance_info, '')
+		FROM transactions t
+		JOIN bank_statements s ON s.id = t.statement_id
+		WHERE s.tenant_id = $1 AND t.credit_debit = 'DBIT' AND t.booking_date >= $2`,
+		tenantID, from, SubjectPaymentItem, SubjectBankTransaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payments: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []Payment
+	for rows.Next() {
+		var p Payment
+		if err := rows.Scan(&p.ID, &p.Source, &p.IBAN, &p.Counterparty, &p.Amount, &p.Date, &p.Reference); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
+
+// Invoices returns the issued invoices of a tenant from the given date on,
+// limited to buyers invoiced since recent. Credit notes are left out.
+func (r *Repository) Invoices(ctx context.Context, tenantID uuid.UUID, from, recent time.Time) ([]Invoice, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH issued AS (
+			SELECT id, invoice_number, buyer_id, buyer_name, tax_inclusive_amount, issue_date,
+				COALESCE(buyer_id::text, regexp_replace(lower(trim(buyer_name)), '\s+', ' ', 'g')) AS counterparty
+			FROM invoices
+			WHERE tenant_id = $1 AND issue_date >= $2
+				AND status NOT IN ('draft', 'cancelled') AND invoice_type <> '381'
+		)
+		SELECT id, invoice_number, buyer_id, buyer_name, tax_inclusive_amount, issue_date
+		FROM issued
+		WHERE counterparty IN (SELECT counterparty FROM issued WHERE issue_date >= $3)`,
+		tenantID, from, recent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []Invoice
+	for rows.Next() {
+		var inv Invoice
+		var buyerID *uuid.UUID
+		if err := rows.Scan(&inv.ID, &inv.Number, &buyerID, &inv.BuyerName, &inv.Amount, &inv.IssueDate); err != nil {
+			return nil, err
+		}
+		inv.Counterparty = CounterpartyKey(buyerID, inv.BuyerName)
+		invoices = append(invoices, inv)
+	}
+	return invoices, rows.Err()
+}
+
+// UnmatchedBankLines returns the bank lines of a tenant booked since the
+// given date that are matched to neither a payment nor an invoice
+func (r *Repository) UnmatchedBankLines(ctx context.Context, tenantID uuid.UUID, since time.Time) ([]BankLine, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT t.id, COALESCE(t.counterparty_iban, ''), COALESCE(t.counterparty_name, ''), t.amount,
+			t.credit_debit = 'DBIT', t.booking_date, COALESCE(t.remittance_info, '')
+		FROM transactions t
+		JOIN bank_statements s ON s.id = t.statement_id
+		WHERE s.tenant_id = $1 AND t.booking_date >= $2
+			AND t.matched_payment_id IS NULL AND t.matched_invoice_id IS NULL`,
+		tenantID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bank lines: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []BankLine
+	for rows.Next() {
+		var l BankLine
+		if err := rows.Scan(&l.ID, &l.IBAN, &l.Counterparty, &l.Amount, &l.Debit, &l.BookingDate, &l.Reference); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+// KnownIBANs returns the normalized IBANs a tenant has dealt with: its own
+// statement accounts, the creditors it paid by transfer, counterparties of
+// matched bank lines and of any bank line booked before the given date
+func (r *Repository) KnownIBANs(ctx context.Context, tenantID uuid.UUID, before time.Time) (map[string]bool, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT upper(replace(iban, ' ', '')) FROM bank_statements WHERE tenant_id = $1
+		UNION
+		SELECT upper(replace(i.creditor_iban, ' ', ''))
+		FROM payment_items i JOIN payment_batches b ON b.id = i.batch_id
+		WHERE b.tenant_id = $1
+		UNION
+		SELECT upper(replace(t.counterparty_iban, ' ', ''))
+		FROM transactions t JOIN bank_statements s ON s.id = t.statement_id
+		WHERE s.tenant_id = $1 AND t.counterparty_iban IS NOT NULL
+			AND (t.booking_date < $2 OR t.matched_payment_id IS NOT NULL OR t.matched_invoice_id IS NOT NULL)`,
+		tenantID, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known IBANs: %w", err)
+	}
+	defer rows.Close()
+
+	known := make(map[string]bool)
+	for rows.Next() {
+		var iban string
+		if err := rows.Scan(&iban); err != nil {
+			return nil, err
+		}
+		known[iban] = true
+	}
+	return known, rows.Err()
+}
+
+// SaveFindings stores new findings of a tenant and returns how many were
+// new. Findings raised before, reviewed or not, are left as they are.
+func (r *Repository) SaveFindings(ctx context.Context, tenantID uuid.UUID, findings []*Finding) (int, error) {
+	if len(findings) == 0 {
+		return 0, nil
+	}
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	raised := 0
+	for _, f := range findings {
+		f.TenantID = tenantID
+		f.Status = StatusOpen
+		if f.RelatedIDs == nil {
+			f.RelatedIDs = []uuid.UUID{}
+		}
+		err := tx.QueryRow(ctx, `
+			INSERT INTO anomaly_findings (
+				tenant_id, kind, severity, fingerprint, subject_type, subject_id, related_ids,
+				title, amount_cents, counterparty, details
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), COALESCE($11, '{}'::jsonb))
+			ON CONFLICT (tenant_id, kind, fingerprint) DO NOTHING
+			RETURNING id, detected_at`,
+			f.TenantID, f.Kind, f.Severity, f.Fingerprint, f.SubjectType, f.SubjectID, f.RelatedIDs,
+			f.Title, f.AmountCents, f.Counterparty, []byte(f.Details),
+		).Scan(&f.ID, &f.DetectedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to save finding: %w", err)
+		}
+		raised++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit findings: %w", err)
+	}
+	return raised, nil
+}
+
+const findingColumns = `id, tenant_id, kind, severity, status, fingerprint, subject_type, subject_id,
+	related_ids, title, amount_cents, COALESCE(counterparty, ''), details, detected_at,
+	reviewed_by, reviewed_at, COALESCE(review_note, '')`
+
+func scanFinding(row pgx.Row) (*Finding, error) {
+	var f Finding
+	err := row.Scan(&f.ID, &f.TenantID, &f.Kind, &f.Severity, &f.Status, &f.Fingerprint, &f.SubjectType, &f.SubjectID,
+		&f.RelatedIDs, &f.Title, &f.AmountCents, &f.Counterparty, &f.Details, &f.DetectedAt,
+		&f.ReviewedBy, &f.ReviewedAt, &f.ReviewNote)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// List returns the findings of a tenant, newest first
+func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Finding, int, error) {
+	where := "tenant_id = $1"
+	args := []interface{}{filter.TenantID}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.Kind != "" {
+		args = append(args, filter.Kind)
+		where += fmt.Sprintf(" AND kind = $%d", len(args))
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM anomaly_findings WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count findings: %w", err)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT %s FROM anomaly_findings
+		WHERE %s
+		ORDER BY detected_at DESC, id
+		LIMIT $%d OFFSET $%d`, findingColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list findings: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []*Finding
+	for rows.Next() {
+		f, err := scanFinding(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		findings = append(findings, f)
+	}
+	return findings, total, rows.Err()
+}
+
+// Get returns a finding of a tenant
+func (r *Repository) Get(ctx context.Context, tenantID, id uuid.UUID) (*Finding, error) {
+	f, err := scanFinding(r.db.QueryRow(ctx,
+		`SELECT `+findingColumns+` FROM anomaly_findings WHERE id = $1 AND tenant_id = $2`, id, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrFindingNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get finding: %w", err)
+	}
+	return f, nil
+}
+
+// Review confirms or dismisses an open finding
+func (r *Repository) Review(ctx context.Context, tenantID, id uuid.UUID, status string, reviewedBy *uuid.UUID, note string) (*Finding, error) {
+	f, err := scanFinding(r.db.QueryRow(ctx, `
+		UPDATE anomaly_findings
+		SET status = $3, reviewed_by = $4, reviewed_at = NOW(), review_note = NULLIF($5, '')
+		WHERE id = $1 AND tenant_id = $2 AND status = 'open'
+		RETURNING `+findingColumns,
+		id, tenantID, status, reviewedBy, note))
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := r.Get(ctx, tenantID, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrFindingReviewed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to review finding: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,120 @@
+package anomaly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Service runs the anomaly detection and the review of its findings
+type Service struct {
+	repo *Repository
+	cfg  Config
+	now  func() time.Time
+}
+
+// NewService creates a new anomaly service with the default configuration
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo, cfg: DefaultConfig(), now: time.Now}
+}
+
+// SetConfig replaces the detection configuration
+func (s *Service) SetConfig(cfg Config) {
+	s.cfg = cfg
+}
+
+// Scan runs all detections for a tenant over the lookback period and stores
+// the findings not raised before
+func (s *Service) Scan(ctx context.Context, tenantID uuid.UUID) (*ScanResult, error) {
+	now := s.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	since := today.Add(-s.cfg.Lookback)
+
+	payments, err := s.repo.OutgoingPayments(ctx, tenantID, since.Add(-s.cfg.DuplicateWindow))
+	if err != nil {
+		return nil, err
+	}
+	duplicates := DuplicatePayments(payments, since, s.cfg.DuplicateWindow)
+
+	invoices, err := s.repo.Invoices(ctx, tenantID, today.Add(-s.cfg.History), since)
+	if err != nil {
+		return nil, err
+	}
+	unusual := UnusualAmounts(invoices, since, s.cfg.MinHistory, s.cfg.MaxScore)
+
+	lines, err := s.repo.UnmatchedBankLines(ctx, tenantID, since)
+	if err != nil {
+		return nil, err
+	}
+	known, err := s.repo.KnownIBANs(ctx, tenantID, since)
+	if err != nil {
+		return nil, err
+	}
+	unknown := UnknownCounterparties(lines, known)
+
+	findings := make([]*Finding, 0, len(duplicates)+len(unusual)+len(unknown))
+	findings = append(findings, duplicates...)
+	findings = append(findings, unusual...)
+	findings = append(findings, unknown...)
+	raised, err := s.repo.SaveFindings(ctx, tenantID, findings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScanResult{
+		DuplicatePayments:     len(duplicates),
+		UnusualAmounts:        len(unusual),
+		UnknownCounterparties: len(unknown),
+		Raised:                raised,
+	}, nil
+}
+
+// ScanAll scans every tenant with recent invoices, payments or bank
+// statements and returns the number of findings raised
+func (s *Service) ScanAll(ctx context.Context) (int, error) {
+	tenants, err := s.repo.ActiveTenants(ctx, s.now().Add(-s.cfg.Lookback))
+	if err != nil {
+		return 0, err
+	}
+
+	raised := 0
+	var errs []error
+	for _, tenantID := range tenants {
+		result, err := s.Scan(ctx, tenantID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenantID, err))
+			continue
+		}
+		raised += result.Raised
+	}
+	return raised, errors.Join(errs...)
+}
+
+// List lists the findings of a tenant
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]*Finding, int, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// Get returns a finding of a tenant
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Finding, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// Confirm marks an open finding as a real anomaly
+func (s *Service) Confirm(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID, note string) (*Finding, error) {
+	return s.repo.Review(ctx, tenantID, id, StatusConfirmed, userID, note)
+}
+
+// Dismiss marks an open finding as a false alarm. It is not raised again.
+func (s *Service) Dismiss(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID, note string) (*Finding, error) {
+	return s.repo.Review(ctx, tenantID, id, StatusDismissed, userID, note)
+}
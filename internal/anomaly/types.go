@@ -0,0 +1,150 @@
+package anomaly
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrFindingNotFound = errors.New("finding not found")
+	ErrFindingReviewed = errors.New("finding has already been reviewed")
+)
+
+// Kinds of findings
+const (
+	KindDuplicatePayment    = "duplicate_payment"
+	KindUnusualAmount       = "unusual_invoice_amount"
+	KindUnknownCounterparty = "unknown_counterparty"
+)
+
+// Severities of findings
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// Review states of findings
+const (
+	StatusOpen      = "open"
+	StatusConfirmed = "confirmed"
+	StatusDismissed = "dismissed"
+)
+
+// Subject types: what a finding points at
+const (
+	SubjectPaymentItem     = "payment_item"
+	SubjectInvoice         = "invoice"
+	SubjectBankTransaction = "bank_transaction"
+)
+
+// IsValidKind reports whether kind is a known finding kind
+func IsValidKind(kind string) bool {
+	switch kind {
+	case KindDuplicatePayment, KindUnusualAmount, KindUnknownCounterparty:
+		return true
+	}
+	return false
+}
+
+// Finding is a review item raised by the detection
+type Finding struct {
+	ID           uuid.UUID       `json:"id"`
+	TenantID     uuid.UUID       `json:"tenant_id"`
+	Kind         string          `json:"kind"`
+	Severity     string          `json:"severity"`
+	Status       string          `json:"status"`
+	Fingerprint  string          `json:"-"`
+	SubjectType  string          `json:"subject_type"`
+	SubjectID    uuid.UUID       `json:"subject_id"`
+	RelatedIDs   []uuid.UUID     `json:"related_ids,omitempty"`
+	Title        string          `json:"title"`
+	AmountCents  *int64          `json:"amount_cents,omitempty"`
+	Counterparty string          `json:"counterparty,omitempty"`
+	Details      json.RawMessage `json:"details,omitempty"`
+	DetectedAt   time.Time       `json:"detected_at"`
+	ReviewedBy   *uuid.UUID      `json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time      `json:"reviewed_at,omitempty"`
+	ReviewNote   string          `json:"review_note,omitempty"`
+}
+
+// ListFilter filters findings
+type ListFilter struct {
+	TenantID uuid.UUID
+	Status   string
+	Kind     string
+	Limit    int
+	Offset   int
+}
+
+// Payment is an outgoing payment, either an item of a credit transfer batch
+// or a debit line of a bank statement
+type Payment struct {
+	ID           uuid.UUID
+	Source       string // SubjectPaymentItem or SubjectBankTransaction
+	IBAN         string
+	Counterparty string
+	Amount       int64 // In cents
+	Date         time.Time
+	Reference    string
+}
+
+// Invoice is an issued invoice with the key of its buyer
+type Invoice struct {
+	ID           uuid.UUID
+	Number       string
+	Counterparty string // buyer ID, or the normalized buyer name
+	BuyerName    string
+	Amount       int64 // Gross, in cents
+	IssueDate    time.Time
+}
+
+// BankLine is a line of an imported bank statement
+type BankLine struct {
+	ID           uuid.UUID
+	IBAN         string
+	Counterparty string
+	Amount       int64 // In cents
+	Debit        bool
+	BookingDate  time.Time
+	Reference    string
+}
+
+// Config tunes the detection
+type Config struct {
+	// Lookback is how far back new payments, invoices and bank lines are
+	// checked on each run
+	Lookback time.Duration
+	// DuplicateWindow is how far apart two payments of the same amount to the
+	// same IBAN may be to count as duplicates
+	DuplicateWindow time.Duration
+	// History is how far back invoices form a counterparty's history
+	History time.Duration
+	// MinHistory is the number of earlier invoices a counterparty needs
+	// before its amounts are judged
+	MinHistory int
+	// MaxScore is the modified z-score above which an amount is unusual
+	MaxScore float64
+}
+
+// DefaultConfig returns the detection defaults
+func DefaultConfig() Config {
+	return Config{
+		Lookback:        30 * 24 * time.Hour,
+		DuplicateWindow: 7 * 24 * time.Hour,
+		History:         2 * 365 * 24 * time.Hour,
+		MinHistory:      5,
+		MaxScore:        3.5,
+	}
+}
+
+// ScanResult counts the findings of a detection run
+type ScanResult struct {
+	DuplicatePayments     int `json:"duplicate_payments"`
+	UnusualAmounts        int `json:"unusual_amounts"`
+	UnknownCounterparties int `json:"unknown_counterparties"`
+	Raised                int `json:"raised"`
+}
@@ -1,14 +1,17 @@
 package antrag
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
 	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/customfield"
 	"austrian-business-infrastructure/internal/foerderung"
 )
 
@@ -28,6 +31,7 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Post("/", h.Create)
 		r.Get("/", h.List)
 		r.Get("/stats", h.GetStats)
+		r.Get("/export", h.Export)
 		r.Get("/{id}", h.Get)
 		r.Put("/{id}", h.Update)
 		r.Delete("/{id}", h.Delete)
@@ -44,6 +48,9 @@ type CreateRequest struct {
 	InternalReference *string `json:"internal_reference,omitempty"`
 	RequestedAmount   *int    `json:"requested_amount,omitempty"`
 	Notes             *string `json:"notes,omitempty"`
+	AdvisorID         *string `json:"advisor_id,omitempty"`
+
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
 }
 
 // UpdateRequest represents the update application request
@@ -53,6 +60,11 @@ type UpdateRequest struct {
 	ApprovedAmount    *int    `json:"approved_amount,omitempty"`
 	DecisionNotes     *string `json:"decision_notes,omitempty"`
 	Notes             *string `json:"notes,omitempty"`
+	// AdvisorID assigns the application; an empty string clears it
+	AdvisorID *string `json:"advisor_id,omitempty"`
+
+	// CustomFields are merged into the current values; null removes a field
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
 }
 
 // StatusUpdateRequest represents the status update request
@@ -70,22 +82,24 @@ type AddAttachmentRequest struct {
 
 // AntragResponse represents an application in API responses
 type AntragResponse struct {
-	ID                string                       `json:"id"`
-	TenantID          string                       `json:"tenant_id"`
-	ProfileID         string                       `json:"profile_id"`
-	FoerderungID      string                       `json:"foerderung_id"`
-	Status            string                       `json:"status"`
-	InternalReference *string                      `json:"internal_reference,omitempty"`
-	SubmittedAt       *string                      `json:"submitted_at,omitempty"`
-	RequestedAmount   *int                         `json:"requested_amount,omitempty"`
-	ApprovedAmount    *int                         `json:"approved_amount,omitempty"`
-	DecisionDate      *string                      `json:"decision_date,omitempty"`
-	DecisionNotes     *string                      `json:"decision_notes,omitempty"`
-	Attachments       []foerderung.Attachment      `json:"attachments,omitempty"`
-	Timeline          []foerderung.TimelineEntry   `json:"timeline,omitempty"`
-	Notes             *string                      `json:"notes,omitempty"`
-	CreatedAt         string                       `json:"created_at"`
-	UpdatedAt         string                       `json:"updated_at"`
+	ID                string                     `json:"id"`
+	TenantID          string                     `json:"tenant_id"`
+	ProfileID         string                     `json:"profile_id"`
+	FoerderungID      string                     `json:"foerderung_id"`
+	Status            string                     `json:"status"`
+	InternalReference *string                    `json:"internal_reference,omitempty"`
+	SubmittedAt       *string                    `json:"submitted_at,omitempty"`
+	RequestedAmount   *int                       `json:"requested_amount,omitempty"`
+	ApprovedAmount    *int                       `json:"approved_amount,omitempty"`
+	DecisionDate      *string                    `json:"decision_date,omitempty"`
+	DecisionNotes     *string                    `json:"decision_notes,omitempty"`
+	Attachments       []foerderung.Attachment    `json:"attachments,omitempty"`
+	Timeline          []foerderung.TimelineEntry `json:"timeline,omitempty"`
+	Notes             *string                    `json:"notes,omitempty"`
+	CustomFields      map[string]interface{}     `json:"custom_fields,omitempty"`
+	AdvisorID         *string                    `json:"advisor_id,omitempty"`
+	CreatedAt         string                     `json:"created_at"`
+	UpdatedAt         string                     `json:"updated_at"`
 }
 
 // ListResponse represents the list applications response
@@ -122,6 +136,12 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	advisorID, err := parseAdvisorID(req.AdvisorID)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "Invalid advisor ID")
+		return
+	}
+
 	userID := getUserIDFromContext(r)
 
 	input := &CreateInput{
@@ -131,6 +151,8 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		InternalReference: req.InternalReference,
 		RequestedAmount:   req.RequestedAmount,
 		Notes:             req.Notes,
+		CustomFields:      req.CustomFields,
+		AdvisorID:         advisorID,
 		CreatedBy:         userID,
 	}
 
@@ -151,6 +173,95 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filter, err := h.parseListFilter(r, tenantID)
+	if err != nil {
+		if customfield.IsInvalid(err) {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list applications")
+		return
+	}
+
+	antraege, total, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list applications")
+		return
+	}
+
+	resp := ListResponse{
+		Antraege: make([]*AntragResponse, 0, len(antraege)),
+		Total:    total,
+		Limit:    filter.Limit,
+		Offset:   filter.Offset,
+	}
+	for _, a := range antraege {
+		resp.Antraege = append(resp.Antraege, toAntragResponse(a))
+	}
+
+	api.RespondJSON(w, http.StatusOK, resp)
+}
+
+// Export handles GET /api/v1/antraege/export. It takes the filters of List
+// and writes up to MaxExportRows applications as CSV, one column per custom
+// field after the fixed columns.
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := getTenantIDFromContext(r)
+	if err != nil {
+		api.RespondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	filter, err := h.parseListFilter(r, tenantID)
+	if err != nil {
+		if customfield.IsInvalid(err) {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list applications")
+		return
+	}
+
+	defs, err := h.service.CustomFieldDefinitions(r.Context(), tenantID)
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to load custom fields")
+		return
+	}
+	antraege, err := h.service.ListForExport(r.Context(), filter)
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "Failed to list applications")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=antraege.csv")
+
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	header := []string{"ID", "Profile ID", "Foerderung ID", "Status", "Internal Reference",
+		"Submitted At", "Requested Amount", "Approved Amount", "Decision Date", "Created At"}
+	writer.Write(append(header, customfield.CSVHeader(defs)...))
+
+	for _, a := range antraege {
+		row := []string{
+			a.ID.String(),
+			a.ProfileID.String(),
+			a.FoerderungID.String(),
+			a.Status,
+			customfield.CSVCell(derefString(a.InternalReference)),
+			formatTime(a.SubmittedAt),
+			formatInt(a.RequestedAmount),
+			formatInt(a.ApprovedAmount),
+			formatTime(a.DecisionDate),
+			a.CreatedAt.Format(time.RFC3339),
+		}
+		writer.Write(append(row, customfield.CSVValues(defs, a.CustomFields)...))
+	}
+}
+
+// parseListFilter reads the list filters shared by List and Export
+func (h *Handler) parseListFilter(r *http.Request, tenantID uuid.UUID) (ListFilter, error) {
 	q := r.URL.Query()
 	filter := ListFilter{
 		TenantID: tenantID,
@@ -182,23 +293,13 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		filter.Limit = 20
 	}
 
-	antraege, total, err := h.service.List(r.Context(), filter)
+	cfFilters, err := h.service.CustomFieldFilters(r.Context(), tenantID, customfield.ParseFilterParams(q))
 	if err != nil {
-		api.RespondError(w, http.StatusInternalServerError, "Failed to list applications")
-		return
+		return filter, err
 	}
+	filter.CustomFields = cfFilters
 
-	resp := ListResponse{
-		Antraege: make([]*AntragResponse, 0, len(antraege)),
-		Total:    total,
-		Limit:    filter.Limit,
-		Offset:   filter.Offset,
-	}
-	for _, a := range antraege {
-		resp.Antraege = append(resp.Antraege, toAntragResponse(a))
-	}
-
-	api.RespondJSON(w, http.StatusOK, resp)
+	return filter, nil
 }
 
 // Get handles GET /api/v1/antraege/{id}
@@ -244,6 +345,15 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	advisorID, err := parseAdvisorID(req.AdvisorID)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "Invalid advisor ID")
+		return
+	}
+	if advisorID == nil && req.AdvisorID != nil {
+		advisorID = &uuid.Nil
+	}
+
 	userID := getUserIDFromContext(r)
 
 	input := &UpdateInput{
@@ -252,6 +362,8 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		ApprovedAmount:    req.ApprovedAmount,
 		DecisionNotes:     req.DecisionNotes,
 		Notes:             req.Notes,
+		CustomFields:      req.CustomFields,
+		AdvisorID:         advisorID,
 	}
 
 	antrag, err := h.service.Update(r.Context(), id, tenantID, input, userID)
@@ -418,22 +530,60 @@ func toAntragResponse(a *foerderung.FoerderungsAntrag) *AntragResponse {
 		Attachments:       a.Attachments,
 		Timeline:          a.Timeline,
 		Notes:             a.Notes,
-		CreatedAt:         a.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:         a.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		CustomFields:      a.CustomFields,
+		CreatedAt:         a.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:         a.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 
+	if a.AdvisorID != nil {
+		s := a.AdvisorID.String()
+		resp.AdvisorID = &s
+	}
 	if a.SubmittedAt != nil {
-		s := a.SubmittedAt.Format("2006-01-02T15:04:05Z")
+		s := a.SubmittedAt.UTC().Format(time.RFC3339)
 		resp.SubmittedAt = &s
 	}
 	if a.DecisionDate != nil {
-		s := a.DecisionDate.Format("2006-01-02T15:04:05Z")
+		s := a.DecisionDate.UTC().Format(time.RFC3339)
 		resp.DecisionDate = &s
 	}
 
 	return resp
 }
 
+// parseAdvisorID parses an optional advisor ID; nil and "" give nil
+func parseAdvisorID(s *string) (*uuid.UUID, error) {
+	if s == nil || *s == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(*s)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
 // Context helper functions
 
 type contextKey string
@@ -467,4 +617,3 @@ func getUserIDFromContext(r *http.Request) *uuid.UUID {
 	}
 	return nil
 }
-
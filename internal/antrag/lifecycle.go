@@ -0,0 +1,37 @@
+package antrag
+
+import (
+	"austrian-business-infrastructure/internal/foerderung"
+	"austrian-business-infrastructure/internal/workflow"
+)
+
+// Lifecycle is the workflow of an application. Its transitions are named
+// after the status they lead to.
+var Lifecycle = &workflow.Definition{
+	Key:     "antrag",
+	Name:    "Förderantrag",
+	Initial: foerderung.AntragStatusPlanned,
+	States: []workflow.State{
+		{Name: foerderung.AntragStatusPlanned, Label: "Geplant"},
+		{Name: foerderung.AntragStatusDrafting, Label: "In Ausarbeitung"},
+		{Name: foerderung.AntragStatusSubmitted, Label: "Eingereicht"},
+		{Name: foerderung.AntragStatusInReview, Label: "In Prüfung"},
+		{Name: foerderung.AntragStatusApproved, Label: "Bewilligt", Final: true},
+		{Name: foerderung.AntragStatusRejected, Label: "Abgelehnt", Final: true},
+		{Name: foerderung.AntragStatusWithdrawn, Label: "Zurückgezogen", Final: true},
+	},
+	Transitions: []workflow.Transition{
+		{Name: foerderung.AntragStatusPlanned, From: []string{foerderung.AntragStatusDrafting}, To: foerderung.AntragStatusPlanned},
+		{Name: foerderung.AntragStatusDrafting, From: []string{foerderung.AntragStatusPlanned}, To: foerderung.AntragStatusDrafting},
+		{Name: foerderung.AntragStatusSubmitted, From: []string{foerderung.AntragStatusDrafting}, To: foerderung.AntragStatusSubmitted},
+		{Name: foerderung.AntragStatusInReview, From: []string{foerderung.AntragStatusSubmitted}, To: foerderung.AntragStatusInReview},
+		{Name: foerderung.AntragStatusApproved, From: []string{foerderung.AntragStatusInReview}, To: foerderung.AntragStatusApproved},
+		{Name: foerderung.AntragStatusRejected, From: []string{foerderung.AntragStatusInReview}, To: foerderung.AntragStatusRejected},
+		{Name: foerderung.AntragStatusWithdrawn, From: []string{
+			foerderung.AntragStatusPlanned,
+			foerderung.AntragStatusDrafting,
+			foerderung.AntragStatusSubmitted,
+			foerderung.AntragStatusInReview,
+		}, To: foerderung.AntragStatusWithdrawn},
+	},
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"austrian-business-infrastructure/internal/customfield"
 	"austrian-business-infrastructure/internal/foerderung"
 )
 
@@ -39,15 +40,15 @@ func (r *Repository) Create(ctx context.Context, a *foerderung.FoerderungsAntrag
 			requested_amount, approved_amount,
 			decision_date, decision_notes,
 			attachments, timeline, notes,
-			created_by, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+			created_by, created_at, updated_at, custom_fields, advisor_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 	`,
 		a.ID, a.TenantID, a.ProfileID, a.FoerderungID,
 		a.Status, a.InternalReference, a.SubmittedAt,
 		a.RequestedAmount, a.ApprovedAmount,
 		a.DecisionDate, a.DecisionNotes,
 		attachmentsJSON, timelineJSON, a.Notes,
-		a.CreatedBy, a.CreatedAt, a.UpdatedAt,
+		a.CreatedBy, a.CreatedAt, a.UpdatedAt, customfield.Values(a.CustomFields).Param(), a.AdvisorID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create antrag: %w", err)
@@ -67,7 +68,7 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*foerderung.Foe
 			requested_amount, approved_amount,
 			decision_date, decision_notes,
 			attachments, timeline, notes,
-			created_by, created_at, updated_at
+			created_by, created_at, updated_at, custom_fields, advisor_id
 		FROM foerderungs_antraege
 		WHERE id = $1
 	`, id).Scan(
@@ -76,7 +77,7 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*foerderung.Foe
 		&a.RequestedAmount, &a.ApprovedAmount,
 		&a.DecisionDate, &a.DecisionNotes,
 		&attachmentsJSON, &timelineJSON, &a.Notes,
-		&a.CreatedBy, &a.CreatedAt, &a.UpdatedAt,
+		&a.CreatedBy, &a.CreatedAt, &a.UpdatedAt, &a.CustomFields, &a.AdvisorID,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("antrag not found")
@@ -106,7 +107,7 @@ func (r *Repository) GetByIDAndTenant(ctx context.Context, id, tenantID uuid.UUI
 			requested_amount, approved_amount,
 			decision_date, decision_notes,
 			attachments, timeline, notes,
-			created_by, created_at, updated_at
+			created_by, created_at, updated_at, custom_fields, advisor_id
 		FROM foerderungs_antraege
 		WHERE id = $1 AND tenant_id = $2
 	`, id, tenantID).Scan(
@@ -115,7 +116,7 @@ func (r *Repository) GetByIDAndTenant(ctx context.Context, id, tenantID uuid.UUI
 		&a.RequestedAmount, &a.ApprovedAmount,
 		&a.DecisionDate, &a.DecisionNotes,
 		&attachmentsJSON, &timelineJSON, &a.Notes,
-		&a.CreatedBy, &a.CreatedAt, &a.UpdatedAt,
+		&a.CreatedBy, &a.CreatedAt, &a.UpdatedAt, &a.CustomFields, &a.AdvisorID,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("antrag not found")
@@ -140,6 +141,7 @@ type ListFilter struct {
 	ProfileID    *uuid.UUID
 	FoerderungID *uuid.UUID
 	Status       string
+	CustomFields []customfield.Filter
 	Limit        int
 	Offset       int
 }
@@ -157,7 +159,7 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*foerderung
 			requested_amount, approved_amount,
 			decision_date, decision_notes,
 			attachments, timeline, notes,
-			created_by, created_at, updated_at
+			created_by, created_at, updated_at, custom_fields, advisor_id
 		FROM foerderungs_antraege
 		WHERE tenant_id = $1
 	`
@@ -184,6 +186,12 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*foerderung
 		args = append(args, filter.Status)
 		argIdx++
 	}
+	if doc := customfield.FilterDocument(filter.CustomFields); doc != nil {
+		query += fmt.Sprintf(" AND custom_fields @> $%d", argIdx)
+		countQuery += fmt.Sprintf(" AND custom_fields @> $%d", argIdx)
+		args = append(args, doc)
+		argIdx++
+	}
 
 	// Get total count
 	var total int
@@ -212,7 +220,7 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*foerderung
 			&a.RequestedAmount, &a.ApprovedAmount,
 			&a.DecisionDate, &a.DecisionNotes,
 			&attachmentsJSON, &timelineJSON, &a.Notes,
-			&a.CreatedBy, &a.CreatedAt, &a.UpdatedAt,
+			&a.CreatedBy, &a.CreatedAt, &a.UpdatedAt, &a.CustomFields, &a.AdvisorID,
 		); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan antrag: %w", err)
 		}
@@ -243,14 +251,14 @@ func (r *Repository) Update(ctx context.Context, a *foerderung.FoerderungsAntrag
 			requested_amount = $5, approved_amount = $6,
 			decision_date = $7, decision_notes = $8,
 			attachments = $9, timeline = $10, notes = $11,
-			updated_at = $12
+			updated_at = $12, custom_fields = $13, advisor_id = $14
 		WHERE id = $1
 	`,
 		a.ID, a.Status, a.InternalReference, a.SubmittedAt,
 		a.RequestedAmount, a.ApprovedAmount,
 		a.DecisionDate, a.DecisionNotes,
 		attachmentsJSON, timelineJSON, a.Notes,
-		a.UpdatedAt,
+		a.UpdatedAt, customfield.Values(a.CustomFields).Param(), a.AdvisorID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update antrag: %w", err)
@@ -263,6 +271,18 @@ func (r *Repository) Update(ctx context.Context, a *foerderung.FoerderungsAntrag
 	return nil
 }
 
+// IsActiveUser checks that a user is an active member of the tenant
+func (r *Repository) IsActiveUser(ctx context.Context, tenantID, userID uuid.UUID) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND tenant_id = $2 AND is_active)
+	`, userID, tenantID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("failed to check advisor: %w", err)
+	}
+	return ok, nil
+}
+
 // Delete deletes an application
 func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
 	result, err := r.db.Exec(ctx, `DELETE FROM foerderungs_antraege WHERE id = $1`, id)
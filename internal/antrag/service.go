@@ -7,12 +7,17 @@ import (
 
 	"github.com/google/uuid"
 
+	"austrian-business-infrastructure/internal/customfield"
 	"austrian-business-infrastructure/internal/foerderung"
 )
 
+// MaxExportRows caps the number of applications in one CSV export
+const MaxExportRows = 10000
+
 // Service provides application business logic
 type Service struct {
-	repo *Repository
+	repo         *Repository
+	customFields *customfield.Service
 }
 
 // NewService creates a new application service
@@ -20,6 +25,11 @@ func NewService(repo *Repository) *Service {
 	return &Service{repo: repo}
 }
 
+// SetCustomFields enables the tenant's custom fields on applications
+func (s *Service) SetCustomFields(cf *customfield.Service) {
+	s.customFields = cf
+}
+
 // CreateInput contains input for creating an application
 type CreateInput struct {
 	TenantID          uuid.UUID
@@ -28,6 +38,8 @@ type CreateInput struct {
 	InternalReference *string
 	RequestedAmount   *int
 	Notes             *string
+	CustomFields      map[string]interface{}
+	AdvisorID         *uuid.UUID
 	CreatedBy         *uuid.UUID
 }
 
@@ -39,10 +51,22 @@ type UpdateInput struct {
 	ApprovedAmount    *int
 	DecisionNotes     *string
 	Notes             *string
+	CustomFields      map[string]interface{} // merged into the current values
+	AdvisorID         *uuid.UUID             // uuid.Nil clears the advisor
 }
 
 // Create creates a new application
 func (s *Service) Create(ctx context.Context, input *CreateInput) (*foerderung.FoerderungsAntrag, error) {
+	customFields, err := s.customFields.Validate(ctx, input.TenantID, customfield.EntityAntrag, input.CustomFields, nil)
+	if err != nil {
+		return nil, err
+	}
+	if input.AdvisorID != nil {
+		if err := s.requireAdvisor(ctx, input.TenantID, *input.AdvisorID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create timeline entry
 	timeline := []foerderung.TimelineEntry{
 		{
@@ -61,7 +85,9 @@ func (s *Service) Create(ctx context.Context, input *CreateInput) (*foerderung.F
 		InternalReference: input.InternalReference,
 		RequestedAmount:   input.RequestedAmount,
 		Notes:             input.Notes,
+		CustomFields:      customFields,
 		Timeline:          timeline,
+		AdvisorID:         input.AdvisorID,
 		CreatedBy:         input.CreatedBy,
 	}
 
@@ -87,6 +113,25 @@ func (s *Service) List(ctx context.Context, filter ListFilter) ([]*foerderung.Fo
 	return s.repo.List(ctx, filter)
 }
 
+// ListForExport lists up to MaxExportRows applications matching the filter,
+// ignoring its paging
+func (s *Service) ListForExport(ctx context.Context, filter ListFilter) ([]*foerderung.FoerderungsAntrag, error) {
+	filter.Limit = MaxExportRows
+	filter.Offset = 0
+	antraege, _, err := s.repo.List(ctx, filter)
+	return antraege, err
+}
+
+// CustomFieldDefinitions returns the tenant's custom fields on applications
+func (s *Service) CustomFieldDefinitions(ctx context.Context, tenantID uuid.UUID) ([]*customfield.Definition, error) {
+	return s.customFields.List(ctx, tenantID, customfield.EntityAntrag)
+}
+
+// CustomFieldFilters turns cf.<key> list parameters into filters
+func (s *Service) CustomFieldFilters(ctx context.Context, tenantID uuid.UUID, params map[string]string) ([]customfield.Filter, error) {
+	return s.customFields.Filters(ctx, tenantID, customfield.EntityAntrag, params)
+}
+
 // Update updates an application
 func (s *Service) Update(ctx context.Context, id, tenantID uuid.UUID, input *UpdateInput, userID *uuid.UUID) (*foerderung.FoerderungsAntrag, error) {
 	antrag, err := s.repo.GetByIDAndTenant(ctx, id, tenantID)
@@ -109,6 +154,23 @@ func (s *Service) Update(ctx context.Context, id, tenantID uuid.UUID, input *Upd
 	if input.Notes != nil {
 		antrag.Notes = input.Notes
 	}
+	if input.AdvisorID != nil {
+		if *input.AdvisorID == uuid.Nil {
+			antrag.AdvisorID = nil
+		} else {
+			if err := s.requireAdvisor(ctx, tenantID, *input.AdvisorID); err != nil {
+				return nil, err
+			}
+			antrag.AdvisorID = input.AdvisorID
+		}
+	}
+	if input.CustomFields != nil {
+		customFields, err := s.customFields.Validate(ctx, tenantID, customfield.EntityAntrag, input.CustomFields, antrag.CustomFields)
+		if err != nil {
+			return nil, err
+		}
+		antrag.CustomFields = customFields
+	}
 
 	if err := s.repo.Update(ctx, antrag); err != nil {
 		return nil, err
@@ -117,6 +179,18 @@ func (s *Service) Update(ctx context.Context, id, tenantID uuid.UUID, input *Upd
 	return antrag, nil
 }
 
+// requireAdvisor checks that an advisor is an active user of the tenant
+func (s *Service) requireAdvisor(ctx context.Context, tenantID, userID uuid.UUID) error {
+	ok, err := s.repo.IsActiveUser(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("Berater ist kein aktiver Benutzer dieses Mandanten")
+	}
+	return nil
+}
+
 // UpdateStatus updates the status of an application with timeline entry
 func (s *Service) UpdateStatus(ctx context.Context, id, tenantID uuid.UUID, newStatus, description string, userID *uuid.UUID) (*foerderung.FoerderungsAntrag, error) {
 	antrag, err := s.repo.GetByIDAndTenant(ctx, id, tenantID)
@@ -161,43 +235,15 @@ func (s *Service) UpdateStatus(ctx context.Context, id, tenantID uuid.UUID, newS
 }
 
 // validateStatusTransition validates if a status transition is allowed
+// by the Lifecycle
 func (s *Service) validateStatusTransition(currentStatus, newStatus string) error {
-	validTransitions := map[string][]string{
-		foerderung.AntragStatusPlanned: {
-			foerderung.AntragStatusDrafting,
-			foerderung.AntragStatusWithdrawn,
-		},
-		foerderung.AntragStatusDrafting: {
-			foerderung.AntragStatusPlanned,
-			foerderung.AntragStatusSubmitted,
-			foerderung.AntragStatusWithdrawn,
-		},
-		foerderung.AntragStatusSubmitted: {
-			foerderung.AntragStatusInReview,
-			foerderung.AntragStatusWithdrawn,
-		},
-		foerderung.AntragStatusInReview: {
-			foerderung.AntragStatusApproved,
-			foerderung.AntragStatusRejected,
-			foerderung.AntragStatusWithdrawn,
-		},
-		foerderung.AntragStatusApproved:  {}, // Terminal state
-		foerderung.AntragStatusRejected:  {}, // Terminal state
-		foerderung.AntragStatusWithdrawn: {}, // Terminal state
-	}
-
-	allowed, ok := validTransitions[currentStatus]
-	if !ok {
+	if _, ok := Lifecycle.State(currentStatus); !ok {
 		return fmt.Errorf("unbekannter Status: %s", currentStatus)
 	}
-
-	for _, s := range allowed {
-		if s == newStatus {
-			return nil
-		}
+	if _, err := Lifecycle.Transition(currentStatus, newStatus); err != nil {
+		return fmt.Errorf("ungültiger Statusübergang von %s nach %s", currentStatus, newStatus)
 	}
-
-	return fmt.Errorf("ungültiger Statusübergang von %s nach %s", currentStatus, newStatus)
+	return nil
 }
 
 // Delete deletes an application
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"austrian-business-infrastructure/pkg/cache"
+)
+
+// ChallengeHeader carries the client's solution of a proof-of-work or CAPTCHA
+// challenge on endpoints that require one
+const ChallengeHeader = "X-Challenge-Response"
+
+// maxGuardedBody bounds the body read to find the email address
+const maxGuardedBody = 64 * 1024
+
+// AbuseRule configures the protection of one unauthenticated endpoint.
+// Limits of 0 are not enforced.
+type AbuseRule struct {
+	IPLimit    int // requests per client IP and window
+	EmailLimit int // requests per email address and window
+	Window     time.Duration
+	// EmailField is the JSON body field holding the email address
+	EmailField string
+	// Challenge requires a solved challenge when a verifier is configured
+	Challenge bool
+}
+
+// ChallengeVerifier verifies challenge solutions, such as a proof of work or
+// a CAPTCHA token checked with the CAPTCHA provider
+type ChallengeVerifier interface {
+	Verify(ctx context.Context, solution, clientIP string) error
+}
+
+// AbuseMetrics counts the decisions for one endpoint
+type AbuseMetrics struct {
+	Allowed          int64 `json:"allowed"`
+	BlockedIP        int64 `json:"blocked_ip"`
+	BlockedEmail     int64 `json:"blocked_email"`
+	BlockedChallenge int64 `json:"blocked_challenge"`
+}
+
+type abuseCounters struct {
+	allowed, blockedIP, blockedEmail, blockedChallenge atomic.Int64
+}
+
+// AbuseGuard protects unauthenticated endpoints such as registration and
+// password reset against automated use: it throttles per client IP and per
+// email address in Redis and can require a solved challenge
+type AbuseGuard struct {
+	redis          *cache.Client
+	rules          map[string]AbuseRule
+	verifier       ChallengeVerifier
+	trustedProxies map[string]bool
+	logger         *slog.Logger
+
+	mu       sync.Mutex
+	counters map[string]*abuseCounters
+}
+
+// NewAbuseGuard creates a guard with rules by endpoint name
+func NewAbuseGuard(redis *cache.Client, rules map[string]AbuseRule, logger *slog.Logger) *AbuseGuard {
+	return &AbuseGuard{
+		redis:    redis,
+		rules:    rules,
+		logger:   logger,
+		counters: make(map[string]*abuseCounters),
+	}
+}
+
+// SetChallengeVerifier enables challenges for rules that require one
+func (g *AbuseGuard) SetChallengeVerifier(verifier ChallengeVerifier) {
+	g.verifier = verifier
+}
+
+// SetTrustedProxies configures which proxy IPs are trusted for X-Forwarded-For
+func (g *AbuseGuard) SetTrustedProxies(proxies []string) {
+	g.trustedProxies = make(map[string]bool)
+	for _, p := range proxies {
+		g.trustedProxies[p] = true
+	}
+}
+
+// Protect returns middleware applying the rule of an endpoint. Endpoints
+// without a rule pass through.
+func (g *AbuseGuard) Protect(endpoint string) Middleware {
+	rule, ok := g.rules[endpoint]
+	if !ok {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	if rule.EmailField == "" {
+		rule.EmailField = "email"
+	}
+	counters := g.countersFor(endpoint)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := getClientIPWithTrustedProxies(r, g.trustedProxies)
+
+			if rule.IPLimit > 0 {
+				if !g.allow(w, r.Context(), endpoint+":ip:"+ip, rule.IPLimit, rule.Window) {
+					counters.blockedIP.Add(1)
+					ReportSecurity(r, SecuritySignal{Kind: SignalRateLimited, Reason: endpoint + ":ip"})
+					g.logger.Warn("public endpoint throttled", "endpoint", endpoint, "by", "ip", "ip", ip)
+					return
+				}
+			}
+
+			if rule.Challenge && g.verifier != nil {
+				if err := g.verifier.Verify(r.Context(), r.Header.Get(ChallengeHeader), ip); err != nil {
+					counters.blockedChallenge.Add(1)
+					ReportSecurity(r, SecuritySignal{Kind: SignalChallengeFailed, Reason: endpoint})
+					g.logger.Warn("public endpoint challenge failed", "endpoint", endpoint, "ip", ip, "error", err)
+					JSONError(w, http.StatusForbidden, "Challenge verification failed", ErrCodeChallengeRequired)
+					return
+				}
+			}
+
+			if rule.EmailLimit > 0 {
+				email, err := readEmailField(r, rule.EmailField)
+				if err != nil {
+					BadRequest(w, "Invalid request body")
+					return
+				}
+				if email != "" && !g.allow(w, r.Context(), endpoint+":email:"+hashIdentifier(email), rule.EmailLimit, rule.Window) {
+					counters.blockedEmail.Add(1)
+					ReportSecurity(r, SecuritySignal{Kind: SignalRateLimited, Reason: endpoint + ":email"})
+					g.logger.Warn("public endpoint throttled", "endpoint", endpoint, "by", "email", "ip", ip)
+					return
+				}
+			}
+
+			counters.allowed.Add(1)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allow counts the request and writes the rejection when the limit is exceeded
+func (g *AbuseGuard) allow(w http.ResponseWriter, ctx context.Context, identifier string, limit int, window time.Duration) bool {
+	key := "abuse:" + identifier + ":" + currentWindow(window)
+
+	ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	count, err := g.redis.IncrementRateLimit(ctx, key, window)
+	if err != nil {
+		// Fail-closed: these endpoints are the ones abused during outages
+		JSONError(w, http.StatusServiceUnavailable, "Service temporarily unavailable", ErrCodeServiceUnavailable)
+		return false
+	}
+	if count > int64(limit) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(nextWindow(window)).Seconds())))
+		JSONError(w, http.StatusTooManyRequests, "Too many requests, please try again later", ErrCodeRateLimited)
+		return false
+	}
+	return true
+}
+
+// Metrics returns the decision counts by endpoint since startup
+func (g *AbuseGuard) Metrics() map[string]AbuseMetrics {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	metrics := make(map[string]AbuseMetrics, len(g.counters))
+	for endpoint, c := range g.counters {
+		metrics[endpoint] = AbuseMetrics{
+			Allowed:          c.allowed.Load(),
+			BlockedIP:        c.blockedIP.Load(),
+			BlockedEmail:     c.blockedEmail.Load(),
+			BlockedChallenge: c.blockedChallenge.Load(),
+		}
+	}
+	return metrics
+}
+
+func (g *AbuseGuard) countersFor(endpoint string) *abuseCounters {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c, ok := g.counters[endpoint]
+	if !ok {
+		c = &abuseCounters{}
+		g.counters[endpoint] = c
+	}
+	return c
+}
+
+// readEmailField reads a string field of a JSON body and restores the body
+// for the handler. A body without the field returns "".
+func readEmailField(r *http.Request, field string) (string, error) {
+	if r.Body == nil {
+		return "", nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxGuardedBody))
+	r.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		// Leave malformed bodies to the handler's validation
+		return "", nil
+	}
+	var email string
+	if raw, ok := fields[field]; ok {
+		_ = json.Unmarshal(raw, &email)
+	}
+	return strings.ToLower(strings.TrimSpace(email)), nil
+}
+
+// hashIdentifier keeps email addresses out of Redis keys
+func hashIdentifier(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:16])
+}
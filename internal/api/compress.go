@@ -0,0 +1,351 @@
+package api
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content codings offered by Compress
+const (
+	EncodingZstd = "zstd"
+	EncodingGzip = "gzip"
+)
+
+// CompressionConfig configures response compression
+type CompressionConfig struct {
+	// Encodings in order of preference when the client accepts several
+	// equally; zstd and gzip are supported
+	Encodings []string
+	// MinSize is the smallest body that is compressed; smaller responses are
+	// sent as they are unless the handler flushes
+	MinSize int
+	// GzipLevel is the gzip compression level, 1 (fastest) to 9
+	GzipLevel int
+}
+
+// DefaultCompressionConfig prefers zstd and compresses bodies from 1 KiB
+func DefaultCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		Encodings: []string{EncodingZstd, EncodingGzip},
+		MinSize:   1024,
+		GzipLevel: gzip.DefaultCompression,
+	}
+}
+
+// incompressibleTypes are content types that are compressed already
+var incompressibleTypes = []string{
+	"image/", "video/", "audio/", "font/woff",
+	"application/pdf", "application/zip", "application/gzip", "application/zstd",
+	"application/octet-stream", "application/x-7z-compressed", "application/vnd.openxmlformats",
+}
+
+// Compress compresses responses with the best content coding the client
+// accepts (Accept-Encoding). Bodies are buffered up to MinSize before
+// deciding, so small responses go out unchanged. Responses that already
+// have a Content-Encoding or Content-Range, are of an already compressed
+// type (PDF, images, archives) or have no body are not touched. A handler
+// that flushes, e.g. a JSONStream, gets its data compressed and flushed
+// through as it goes.
+func Compress(cfg CompressionConfig) Middleware {
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = DefaultCompressionConfig().MinSize
+	}
+	if cfg.GzipLevel == 0 {
+		cfg.GzipLevel = gzip.DefaultCompression
+	}
+	var encodings []string
+	for _, e := range cfg.Encodings {
+		if e == EncodingZstd || e == EncodingGzip {
+			encodings = append(encodings, e)
+		}
+	}
+	if len(encodings) == 0 {
+		encodings = DefaultCompressionConfig().Encodings
+	}
+	pools := newEncoderPools(cfg.GzipLevel)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := NegotiateEncoding(r.Header.Get("Accept-Encoding"), encodings)
+			if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				minSize:        cfg.MinSize,
+				pools:          pools,
+			}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// NegotiateEncoding picks the content coding for an Accept-Encoding header:
+// the supported coding with the highest q-value, ties going to the earlier
+// one in supported. "*" stands for every coding not listed. It returns ""
+// if none is acceptable.
+func NegotiateEncoding(header string, supported []string) string {
+	if header == "" {
+		return ""
+	}
+	q := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		value := 1.0
+		for _, p := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+			if ok && strings.TrimSpace(k) == "q" {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					value = f
+				}
+			}
+		}
+		if name == "*" {
+			wildcard = value
+		} else {
+			q[name] = value
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, e := range supported {
+		value, ok := q[e]
+		if !ok {
+			value = wildcard
+		}
+		if value > bestQ {
+			best, bestQ = e, value
+		}
+	}
+	return best
+}
+
+// encoderPools reuses gzip writers and zstd encoders across responses
+type encoderPools struct {
+	gzip sync.Pool
+	zstd sync.Pool
+}
+
+func newEncoderPools(gzipLevel int) *encoderPools {
+	p := &encoderPools{}
+	p.gzip.New = func() interface{} {
+		w, err := gzip.NewWriterLevel(io.Discard, gzipLevel)
+		if err != nil {
+			w = gzip.NewWriter(io.Discard)
+		}
+		return w
+	}
+	p.zstd.New = func() interface{} {
+		// Browsers decode zstd content with windows up to 8 MiB only
+		enc, _ := zstd.NewWriter(io.Discard,
+			zstd.WithEncoderConcurrency(1),
+			zstd.WithWindowSize(1<<20),
+			zstd.WithEncoderLevel(zstd.SpeedDefault))
+		return enc
+	}
+	return p
+}
+
+// encoder is the part of gzip.Writer and zstd.Encoder used here
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+}
+
+func (p *encoderPools) get(encoding string, w io.Writer) encoder {
+	if encoding == EncodingZstd {
+		enc := p.zstd.Get().(*zstd.Encoder)
+		enc.Reset(w)
+		return enc
+	}
+	gz := p.gzip.Get().(*gzip.Writer)
+	gz.Reset(w)
+	return gz
+}
+
+func (p *encoderPools) put(enc encoder) {
+	switch e := enc.(type) {
+	case *zstd.Encoder:
+		e.Reset(io.Discard)
+		p.zstd.Put(e)
+	case *gzip.Writer:
+		e.Reset(io.Discard)
+		p.gzip.Put(e)
+	}
+}
+
+// compressWriter buffers the start of a response until it knows whether to
+// compress it
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+	pools    *encoderPools
+
+	status  int
+	buf     []byte
+	decided bool
+	enc     encoder // nil when sending uncompressed
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.status != 0 {
+		return
+	}
+	if status < 200 {
+		// Informational, the final status follows
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	cw.status = status
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		cw.decided = true
+		cw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		if cw.enc != nil {
+			return cw.enc.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.minSize {
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// decide starts the response, compressed if large is set and the response
+// qualifies, and writes out what was buffered
+func (cw *compressWriter) decide(large bool) error {
+	cw.decided = true
+	h := cw.Header()
+	if large && cw.compressible(h) {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", cw.encoding)
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		cw.enc = cw.pools.get(cw.encoding, cw.ResponseWriter)
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(cw.buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(cw.buf)
+	}
+	cw.buf = nil
+	return err
+}
+
+func (cw *compressWriter) compressible(h http.Header) bool {
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+	contentType := h.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(cw.buf)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if mediaType == "image/svg+xml" {
+		return true
+	}
+	for _, t := range incompressibleTypes {
+		if strings.HasPrefix(mediaType, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Flush sends what was written so far. A response that is flushed before
+// reaching MinSize is a stream and is compressed regardless of its size.
+func (cw *compressWriter) Flush() {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.decided {
+		if err := cw.decide(true); err != nil {
+			return
+		}
+	}
+	if cw.enc != nil {
+		if err := cw.enc.Flush(); err != nil {
+			return
+		}
+	}
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Close finishes the response
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		if cw.status == 0 {
+			// Nothing written: let net/http send its implicit 200
+			if len(cw.buf) == 0 {
+				return nil
+			}
+			cw.status = http.StatusOK
+		}
+		if err := cw.decide(false); err != nil {
+			return err
+		}
+	}
+	if cw.enc == nil {
+		return nil
+	}
+	err := cw.enc.Close()
+	cw.pools.put(cw.enc)
+	cw.enc = nil
+	return err
+}
+
+// Hijack lets websocket upgrades through
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := cw.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
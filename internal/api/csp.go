@@ -0,0 +1,256 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CSPRoute overrides the Content Security Policy for matching requests
+type CSPRoute struct {
+	// Pattern is matched against the request path with path.Match, so
+	// "/api/v1/documents/*/content" matches a single path segment; a trailing
+	// "/" matches the whole subtree
+	Pattern string
+	Config  *CSPConfig
+}
+
+type cspOverride struct {
+	pattern string
+	policy  *cspPolicy
+}
+
+func (o cspOverride) matches(p string) bool {
+	if strings.HasSuffix(o.pattern, "/") {
+		return strings.HasPrefix(p, o.pattern)
+	}
+	ok, _ := path.Match(o.pattern, p)
+	return ok
+}
+
+// EmbeddedViewerCSPConfig returns a policy for responses the frontend embeds,
+// such as PDFs shown in the document viewer: the browser's PDF plugin needs
+// object-src and the response may be framed by the API and the given origins
+func EmbeddedViewerCSPConfig(frameOrigins []string) *CSPConfig {
+	config := DefaultCSPConfig()
+	config.ObjectSrc = []string{"'self'"}
+	config.FrameAncestors = []string{"'self'"}
+	for _, origin := range frameOrigins {
+		if origin != "*" && origin != "" {
+			config.FrameAncestors = append(config.FrameAncestors, origin)
+		}
+	}
+	return config
+}
+
+// cspPolicy is a CSPConfig prepared for serving
+type cspPolicy struct {
+	header      string
+	value       string
+	frameOption string // X-Frame-Options value
+}
+
+func newCSPPolicy(config *CSPConfig) *cspPolicy {
+	p := &cspPolicy{
+		header: "Content-Security-Policy",
+		value:  config.headerValue(),
+	}
+	if config.ReportOnly {
+		p.header = "Content-Security-Policy-Report-Only"
+	}
+
+	// Keep the legacy header consistent with frame-ancestors. Browsers that
+	// enforce frame-ancestors ignore it; older ones only allow same-origin framing.
+	p.frameOption = "SAMEORIGIN"
+	if len(config.FrameAncestors) == 0 || config.FrameAncestors[0] == "'none'" {
+		p.frameOption = "DENY"
+	}
+	return p
+}
+
+// apply sets the policy headers
+func (p *cspPolicy) apply(w http.ResponseWriter) {
+	h := w.Header()
+	h.Del("Content-Security-Policy")
+	h.Del("Content-Security-Policy-Report-Only")
+	h.Set("X-Frame-Options", p.frameOption)
+	h.Set(p.header, p.value)
+}
+
+// headerValue builds the policy
+func (c *CSPConfig) headerValue() string {
+	directives := []struct {
+		name   string
+		values []string
+	}{
+		{"default-src", c.DefaultSrc},
+		{"script-src", c.ScriptSrc},
+		{"style-src", c.StyleSrc},
+		{"img-src", c.ImgSrc},
+		{"connect-src", c.ConnectSrc},
+		{"font-src", c.FontSrc},
+		{"object-src", c.ObjectSrc},
+		{"frame-ancestors", c.FrameAncestors},
+		{"base-uri", c.BaseURI},
+		{"form-action", c.FormAction},
+	}
+
+	var parts []string
+	for _, d := range directives {
+		if len(d.values) > 0 {
+			parts = append(parts, d.name+" "+strings.Join(d.values, " "))
+		}
+	}
+	if c.ReportURI != "" {
+		parts = append(parts, "report-uri "+c.ReportURI)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// CSPViolation is a normalized CSP violation report
+type CSPViolation struct {
+	DocumentURI        string
+	BlockedURI         string
+	EffectiveDirective string
+	SourceFile         string
+	LineNumber         int
+	Disposition        string
+	Sample             string
+}
+
+// Limits of the violation report endpoint. Identical violations are logged
+// once per cspReportDedupWindow so a broken page cannot flood the logs.
+const (
+	maxCSPReportBody     = 64 * 1024
+	cspReportDedupWindow = time.Minute
+	cspReportDedupSize   = 1000
+)
+
+// CSPReportHandler collects violation reports sent to the report-uri, in the
+// legacy application/csp-report format as well as the Reporting API format
+func CSPReportHandler(logger *slog.Logger) http.HandlerFunc {
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]time.Time)
+	)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxCSPReportBody))
+		if err != nil {
+			BadRequest(w, "invalid report")
+			return
+		}
+		violations, err := ParseCSPReports(body)
+		if err != nil {
+			BadRequest(w, "invalid report")
+			return
+		}
+
+		now := time.Now()
+		mu.Lock()
+		if len(seen) >= cspReportDedupSize {
+			seen = make(map[string]time.Time)
+		}
+		for _, v := range violations {
+			key := v.EffectiveDirective + "|" + v.BlockedURI + "|" + v.DocumentURI
+			if last, ok := seen[key]; ok && now.Sub(last) < cspReportDedupWindow {
+				continue
+			}
+			seen[key] = now
+
+			logger.Warn("csp violation",
+				"document_uri", v.DocumentURI,
+				"blocked_uri", v.BlockedURI,
+				"directive", v.EffectiveDirective,
+				"source_file", v.SourceFile,
+				"line", v.LineNumber,
+				"disposition", v.Disposition,
+				"sample", v.Sample,
+			)
+		}
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ParseCSPReports parses a violation report body. It accepts a legacy
+// {"csp-report": {...}} object and a Reporting API array, of which only
+// csp-violation reports are returned.
+func ParseCSPReports(body []byte) ([]CSPViolation, error) {
+	body = []byte(strings.TrimSpace(string(body)))
+	if len(body) > 0 && body[0] == '[' {
+		var reports []struct {
+			Type string `json:"type"`
+			URL  string `json:"url"`
+			Body struct {
+				DocumentURL        string `json:"documentURL"`
+				BlockedURL         string `json:"blockedURL"`
+				EffectiveDirective string `json:"effectiveDirective"`
+				SourceFile         string `json:"sourceFile"`
+				LineNumber         int    `json:"lineNumber"`
+				Disposition        string `json:"disposition"`
+				Sample             string `json:"sample"`
+			} `json:"body"`
+		}
+		if err := json.Unmarshal(body, &reports); err != nil {
+			return nil, err
+		}
+
+		var violations []CSPViolation
+		for _, report := range reports {
+			if report.Type != "csp-violation" {
+				continue
+			}
+			documentURI := report.Body.DocumentURL
+			if documentURI == "" {
+				documentURI = report.URL
+			}
+			violations = append(violations, CSPViolation{
+				DocumentURI:        documentURI,
+				BlockedURI:         report.Body.BlockedURL,
+				EffectiveDirective: report.Body.EffectiveDirective,
+				SourceFile:         report.Body.SourceFile,
+				LineNumber:         report.Body.LineNumber,
+				Disposition:        report.Body.Disposition,
+				Sample:             report.Body.Sample,
+			})
+		}
+		return violations, nil
+	}
+
+	var legacy struct {
+		Report struct {
+			DocumentURI        string `json:"document-uri"`
+			BlockedURI         string `json:"blocked-uri"`
+			ViolatedDirective  string `json:"violated-directive"`
+			EffectiveDirective string `json:"effective-directive"`
+			SourceFile         string `json:"source-file"`
+			LineNumber         int    `json:"line-number"`
+			Disposition        string `json:"disposition"`
+			ScriptSample       string `json:"script-sample"`
+		} `json:"csp-report"`
+	}
+	if err := json.Unmarshal(body, &legacy); err != nil {
+		return nil, err
+	}
+	report := legacy.Report
+	directive := report.EffectiveDirective
+	if directive == "" {
+		directive, _, _ = strings.Cut(report.ViolatedDirective, " ")
+	}
+	return []CSPViolation{{
+		DocumentURI:        report.DocumentURI,
+		BlockedURI:         report.BlockedURI,
+		EffectiveDirective: directive,
+		SourceFile:         report.SourceFile,
+		LineNumber:         report.LineNumber,
+		Disposition:        report.Disposition,
+		Sample:             report.ScriptSample,
+	}}, nil
+}
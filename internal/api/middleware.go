@@ -19,6 +19,7 @@ const (
 	TenantIDKey   contextKey = "tenant_id"
 	UserRoleKey   contextKey = "user_role"
 	UserEmailKey  contextKey = "user_email"
+	BreakGlassKey contextKey = "break_glass_grant_id"
 )
 
 // Middleware represents a middleware function
@@ -82,6 +83,11 @@ func Recovery(logger *slog.Logger) Middleware {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
+					if err == http.ErrAbortHandler {
+						// Deliberate abort of a response already under way
+						panic(err)
+					}
+
 					requestID, _ := r.Context().Value(RequestIDKey).(string)
 
 					logger.Error("panic recovered",
@@ -123,7 +129,7 @@ func CORS(allowedOrigins []string) Middleware {
 			}
 
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-Request-ID, X-API-Key")
+			w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-Request-ID, X-API-Key, "+CSRFHeader+", "+ChallengeHeader)
 			w.Header().Set("Access-Control-Max-Age", "86400")
 
 			if r.Method == http.MethodOptions {
@@ -195,81 +201,33 @@ func DefaultCSPConfig() *CSPConfig {
 	}
 }
 
-// ContentSecurityPolicy adds CSP headers for XSS defense in depth
-func ContentSecurityPolicy(config *CSPConfig) Middleware {
+// ContentSecurityPolicy adds CSP headers for XSS defense in depth. Routes
+// override the policy for requests matching their pattern.
+func ContentSecurityPolicy(config *CSPConfig, routes ...CSPRoute) Middleware {
 	if config == nil {
 		config = DefaultCSPConfig()
 	}
-
-	// Build CSP header value
-	directives := []string{}
-
-	if len(config.DefaultSrc) > 0 {
-		directives = append(directives, "default-src "+joinCSPValues(config.DefaultSrc))
-	}
-	if len(config.ScriptSrc) > 0 {
-		directives = append(directives, "script-src "+joinCSPValues(config.ScriptSrc))
-	}
-	if len(config.StyleSrc) > 0 {
-		directives = append(directives, "style-src "+joinCSPValues(config.StyleSrc))
-	}
-	if len(config.ImgSrc) > 0 {
-		directives = append(directives, "img-src "+joinCSPValues(config.ImgSrc))
-	}
-	if len(config.ConnectSrc) > 0 {
-		directives = append(directives, "connect-src "+joinCSPValues(config.ConnectSrc))
-	}
-	if len(config.FontSrc) > 0 {
-		directives = append(directives, "font-src "+joinCSPValues(config.FontSrc))
-	}
-	if len(config.ObjectSrc) > 0 {
-		directives = append(directives, "object-src "+joinCSPValues(config.ObjectSrc))
-	}
-	if len(config.FrameAncestors) > 0 {
-		directives = append(directives, "frame-ancestors "+joinCSPValues(config.FrameAncestors))
-	}
-	if len(config.BaseURI) > 0 {
-		directives = append(directives, "base-uri "+joinCSPValues(config.BaseURI))
-	}
-	if len(config.FormAction) > 0 {
-		directives = append(directives, "form-action "+joinCSPValues(config.FormAction))
-	}
-	if config.ReportURI != "" {
-		directives = append(directives, "report-uri "+config.ReportURI)
-	}
-
-	cspValue := ""
-	for i, d := range directives {
-		if i > 0 {
-			cspValue += "; "
-		}
-		cspValue += d
-	}
-
-	headerName := "Content-Security-Policy"
-	if config.ReportOnly {
-		headerName = "Content-Security-Policy-Report-Only"
+	policy := newCSPPolicy(config)
+	overrides := make([]cspOverride, len(routes))
+	for i, route := range routes {
+		overrides[i] = cspOverride{pattern: route.Pattern, policy: newCSPPolicy(route.Config)}
 	}
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set(headerName, cspValue)
+			p := policy
+			for _, o := range overrides {
+				if o.matches(r.URL.Path) {
+					p = o.policy
+					break
+				}
+			}
+			p.apply(w)
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
-func joinCSPValues(values []string) string {
-	result := ""
-	for i, v := range values {
-		if i > 0 {
-			result += " "
-		}
-		result += v
-	}
-	return result
-}
-
 // ContentType sets the Content-Type header for JSON responses
 func ContentType(contentType string) Middleware {
 	return func(next http.Handler) http.Handler {
@@ -291,6 +249,16 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Flush passes flushes of streamed responses through
+func (rw *responseWriter) Flush() {
+	http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Helper functions for context values
 
 // GetRequestID retrieves request ID from context
@@ -317,6 +285,44 @@ func GetTenantID(ctx context.Context) string {
 	return ""
 }
 
+// RequestTenant returns the tenant of the request, writing 401 if there is none
+func RequestTenant(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(GetTenantID(r.Context()))
+	if err != nil {
+		Unauthorized(w, "tenant not found in context")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// RequestUser returns the user of the request, writing 401 if there is none
+func RequestUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(GetUserID(r.Context()))
+	if err != nil {
+		Unauthorized(w, "user not found in context")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// OptionalUser returns the user of the request, nil for API keys without one
+func OptionalUser(r *http.Request) *uuid.UUID {
+	if id, err := uuid.Parse(GetUserID(r.Context())); err == nil {
+		return &id
+	}
+	return nil
+}
+
+// PathID parses the UUID path value name, writing 400 if it is invalid
+func PathID(w http.ResponseWriter, r *http.Request, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue(name))
+	if err != nil {
+		BadRequest(w, "invalid "+name)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
 // GetUserRole retrieves user role from context
 func GetUserRole(ctx context.Context) string {
 	if role, ok := ctx.Value(UserRoleKey).(string); ok {
@@ -325,6 +331,15 @@ func GetUserRole(ctx context.Context) string {
 	return ""
 }
 
+// GetBreakGlassGrantID retrieves the break-glass grant a request is made
+// under, empty for regular sessions
+func GetBreakGlassGrantID(ctx context.Context) string {
+	if id, ok := ctx.Value(BreakGlassKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
 // GetUserEmail retrieves user email from context
 func GetUserEmail(ctx context.Context) string {
 	if email, ok := ctx.Value(UserEmailKey).(string); ok {
@@ -69,6 +69,7 @@ func (rl *RateLimiter) Limit(next http.Handler) http.Handler {
 		if count > int64(rl.requests) {
 			retryAfter := int(time.Until(nextWindow(rl.window)).Seconds())
 			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			ReportSecurity(r, SecuritySignal{Kind: SignalRateLimited, Reason: rl.keyPrefix})
 			JSONError(w, http.StatusTooManyRequests, "Rate limit exceeded", ErrCodeRateLimited)
 			return
 		}
@@ -101,6 +102,7 @@ func (rl *RateLimiter) LimitByKey(keyFunc func(*http.Request) string) Middleware
 			if count > int64(rl.requests) {
 				retryAfter := int(time.Until(nextWindow(rl.window)).Seconds())
 				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				ReportSecurity(r, SecuritySignal{Kind: SignalRateLimited, Reason: rl.keyPrefix})
 				JSONError(w, http.StatusTooManyRequests, "Rate limit exceeded", ErrCodeRateLimited)
 				return
 			}
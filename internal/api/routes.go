@@ -124,6 +124,9 @@ const (
 	ErrCodeTokenExpired        = "TOKEN_EXPIRED"
 	ErrCodeInvalidToken        = "INVALID_TOKEN"
 	ErrCodeServiceUnavailable  = "SERVICE_UNAVAILABLE"
+	ErrCodeChallengeRequired   = "CHALLENGE_REQUIRED"
+	ErrCodeIPNotAllowed        = "IP_NOT_ALLOWED"
+	ErrCode2FASetupRequired    = "2FA_SETUP_REQUIRED"
 )
 
 // Standard error responses
@@ -168,3 +171,10 @@ func RateLimited(w http.ResponseWriter, retryAfter int) {
 	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
 	JSONError(w, http.StatusTooManyRequests, "Rate limit exceeded", ErrCodeRateLimited)
 }
+
+// ServiceUnavailable sends a 503 response asking the client to retry after
+// retryAfter seconds
+func ServiceUnavailable(w http.ResponseWriter, retryAfter int, message string) {
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	JSONError(w, http.StatusServiceUnavailable, message, ErrCodeServiceUnavailable)
+}
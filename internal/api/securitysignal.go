@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Security signal kinds reported by middleware and handlers
+const (
+	SignalAuthFailed       = "auth_failed"
+	SignalPermissionDenied = "permission_denied"
+	SignalTenantMismatch   = "tenant_mismatch"
+	SignalRateLimited      = "rate_limited"
+	SignalChallengeFailed  = "challenge_failed"
+	SignalCSRFRejected     = "csrf_rejected"
+)
+
+// SecurityReporterKey is the context key of the request's security reporter
+const SecurityReporterKey contextKey = "security_reporter"
+
+// SecuritySignal describes a security-relevant request outcome. The reporter
+// adds the request context (client IP, user, tenant, request ID).
+type SecuritySignal struct {
+	Kind string
+	// Reason is a short machine-readable cause, e.g. "invalid_token"
+	Reason     string
+	Resource   string
+	ResourceID string
+	// Subject is the account targeted by an unauthenticated request, e.g.
+	// the email address of a failed login
+	Subject string
+}
+
+// SecurityReporter receives security signals. The audit package's security
+// event stream implements it; the indirection keeps this package free of
+// storage dependencies.
+type SecurityReporter interface {
+	ReportSecurity(r *http.Request, clientIP string, signal SecuritySignal)
+}
+
+// SecuritySignals returns middleware that makes the reporter available to
+// later middleware and handlers through ReportSecurity
+func SecuritySignals(reporter SecurityReporter) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), SecurityReporterKey, reporter)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ReportSecurity reports a signal to the request's security reporter. It is
+// a no-op when no reporter is installed.
+func ReportSecurity(r *http.Request, signal SecuritySignal) {
+	reporter, ok := r.Context().Value(SecurityReporterKey).(SecurityReporter)
+	if !ok || reporter == nil {
+		return
+	}
+	reporter.ReportSecurity(r, getClientIP(r), signal)
+}
+
+// ReportTenantMismatch reports a request for a resource that belongs to
+// another tenant. Handlers answer these with 404 so the resource's existence
+// is not disclosed; the signal is how repeated ID probing becomes visible.
+func ReportTenantMismatch(r *http.Request, resource, resourceID string) {
+	ReportSecurity(r, SecuritySignal{
+		Kind:       SignalTenantMismatch,
+		Resource:   resource,
+		ResourceID: resourceID,
+	})
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Fields is the field selection of a request, from ?fields=. Either the
+// fields to send ("id,status,summary") or, prefixed with "-", the ones to
+// leave out ("-extracted_text,-key_points"). Selection applies to the
+// top-level fields of each listed item. The zero value sends everything.
+type Fields struct {
+	include map[string]bool
+	exclude map[string]bool
+}
+
+// ParseFields reads the ?fields= query parameter
+func ParseFields(r *http.Request) Fields {
+	var f Fields
+	for _, name := range strings.Split(r.URL.Query().Get("fields"), ",") {
+		name = strings.TrimSpace(name)
+		if strings.HasPrefix(name, "-") {
+			if name = strings.TrimSpace(name[1:]); name != "" {
+				if f.exclude == nil {
+					f.exclude = make(map[string]bool)
+				}
+				f.exclude[name] = true
+			}
+		} else if name != "" {
+			if f.include == nil {
+				f.include = make(map[string]bool)
+			}
+			f.include[name] = true
+		}
+	}
+	return f
+}
+
+// IsZero reports whether all fields are sent
+func (f Fields) IsZero() bool {
+	return f.include == nil && f.exclude == nil
+}
+
+// Has reports whether a field is sent, so handlers can skip loading heavy
+// columns nobody asked for
+func (f Fields) Has(name string) bool {
+	if f.exclude[name] {
+		return false
+	}
+	return f.include == nil || f.include[name]
+}
+
+// Marshal encodes v as JSON with only the selected top-level fields, in
+// their original order. Values that are not objects are encoded unchanged.
+func (f Fields) Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || f.IsZero() || len(data) == 0 || data[0] != '{' {
+		return data, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.WriteByte('{')
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		name, _ := key.(string)
+		if !f.Has(name) {
+			continue
+		}
+		if out.Len() > 1 {
+			out.WriteByte(',')
+		}
+		k, _ := json.Marshal(name)
+		out.Write(k)
+		out.WriteByte(':')
+		out.Write(value)
+	}
+	out.WriteByte('}')
+	return out.Bytes(), nil
+}
+
+// streamFlushEvery is how many items a JSONStream writes between flushes
+const streamFlushEvery = 100
+
+// JSONStream writes a JSON object with one list member whose items are
+// encoded and sent one at a time, so a large collection is never held in
+// memory as a whole. Members given to NewJSONStream come before the list,
+// members given to Close after it, e.g. a count known only at the end.
+//
+// The status is sent with the first bytes, so an error while streaming
+// cannot change it: Fail ends the document with "incomplete": true and an
+// error message instead.
+type JSONStream struct {
+	w      io.Writer
+	rc     *http.ResponseController
+	fields Fields
+	count  int
+	done   bool
+}
+
+// NewJSONStream starts a streamed response of the form
+// {"<head>": ..., "<key>": [items...], "<tail>": ...}
+func NewJSONStream(w http.ResponseWriter, status int, key string, fields Fields, head map[string]interface{}) (*JSONStream, error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	s := &JSONStream{w: w, rc: http.NewResponseController(w), fields: fields}
+	var b bytes.Buffer
+	b.WriteByte('{')
+	if err := writeMembers(&b, head); err != nil {
+		return nil, err
+	}
+	if len(head) > 0 {
+		b.WriteByte(',')
+	}
+	k, _ := json.Marshal(key)
+	b.Write(k)
+	b.WriteString(":[")
+	if _, err := w.Write(b.Bytes()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write encodes the next item with the field selection applied
+func (s *JSONStream) Write(item interface{}) error {
+	if s.done {
+		return errors.New("json stream closed")
+	}
+	data, err := s.fields.Marshal(item)
+	if err != nil {
+		return err
+	}
+	if s.count > 0 {
+		if _, err := s.w.Write([]byte{','}); err != nil {
+			return err
+		}
+	}
+	if _, err := s.w.Write(data); err != nil {
+		return err
+	}
+	s.count++
+	if s.count%streamFlushEvery == 0 {
+		s.flush()
+	}
+	return nil
+}
+
+// Count returns the number of items written
+func (s *JSONStream) Count() int {
+	return s.count
+}
+
+// Close ends the list and the document with the given members
+func (s *JSONStream) Close(tail map[string]interface{}) error {
+	if s.done {
+		return nil
+	}
+	s.done = true
+	var b bytes.Buffer
+	b.WriteByte(']')
+	if len(tail) > 0 {
+		b.WriteByte(',')
+		if err := writeMembers(&b, tail); err != nil {
+			return err
+		}
+	}
+	b.WriteString("}\n")
+	_, err := s.w.Write(b.Bytes())
+	s.flush()
+	return err
+}
+
+// Fail ends the document after a failure partway through
+func (s *JSONStream) Fail(message string) error {
+	return s.Close(map[string]interface{}{
+		"incomplete": true,
+		"error":      message,
+	})
+}
+
+func (s *JSONStream) flush() {
+	// Writers that cannot flush send everything at the end
+	_ = s.rc.Flush()
+}
+
+// writeMembers writes the members of an object, without braces, in key order
+func writeMembers(b *bytes.Buffer, members map[string]interface{}) error {
+	keys := make([]string, 0, len(members))
+	for k := range members {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(k)
+		value, err := json.Marshal(members[k])
+		if err != nil {
+			return err
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	return nil
+}
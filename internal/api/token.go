@@ -0,0 +1,18 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// BearerTokenMatches reports whether the request carries the given static
+// bearer token. Machine clients such as SIEM collectors and ops tooling use
+// these instead of user sessions. An empty token never matches.
+func BearerTokenMatches(r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
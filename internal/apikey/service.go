@@ -32,6 +32,8 @@ var ValidScopes = []string{
 	"read:users",
 	"write:users",
 	"read:audit",
+	"read:documents",
+	"write:documents",
 }
 
 // CreateKeyInput contains input for creating an API key
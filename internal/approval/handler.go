@@ -63,9 +63,10 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	userID, _ := uuid.Parse(userIDStr)
 
 	var req struct {
-		DocumentID uuid.UUID `json:"document_id"`
-		ClientID   uuid.UUID `json:"client_id"`
-		Message    *string   `json:"message,omitempty"`
+		DocumentID uuid.UUID  `json:"document_id"`
+		ClientID   uuid.UUID  `json:"client_id"`
+		Message    *string    `json:"message,omitempty"`
+		TeamID     *uuid.UUID `json:"team_id,omitempty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
@@ -82,8 +83,14 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		ClientID:    req.ClientID,
 		RequestedBy: userID,
 		Message:     req.Message,
+		TeamID:      req.TeamID,
+		TenantID:    tenantID,
 	})
 	if err != nil {
+		if errors.Is(err, ErrTeamNotFound) {
+			http.Error(w, "team not found", http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "failed to create approval request", http.StatusInternalServerError)
 		return
 	}
@@ -93,7 +100,9 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(approval)
 }
 
-// ListPending returns pending approvals for the tenant
+// ListPending returns pending approvals for the tenant. With mine=true it
+// returns only those the user handles, including the approvals of absent
+// colleagues they stand in for and of their teams.
 func (h *Handler) ListPending(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -113,7 +122,19 @@ func (h *Handler) ListPending(w http.ResponseWriter, r *http.Request) {
 		offset = 0
 	}
 
-	approvals, total, err := h.service.ListPendingForTenant(ctx, tenantID, limit, offset)
+	var approvals []*ApprovalRequest
+	var total int
+	var err error
+	if r.URL.Query().Get("mine") == "true" {
+		userID, parseErr := uuid.Parse(api.GetUserID(ctx))
+		if parseErr != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		approvals, total, err = h.service.ListPendingForStaff(ctx, tenantID, userID, limit, offset)
+	} else {
+		approvals, total, err = h.service.ListPendingForTenant(ctx, tenantID, limit, offset)
+	}
 	if err != nil {
 		http.Error(w, "failed to list approvals", http.StatusInternalServerError)
 		return
@@ -14,6 +14,7 @@ var (
 	ErrApprovalNotFound = errors.New("approval request not found")
 	ErrInvalidStatus    = errors.New("invalid approval status")
 	ErrAlreadyResponded = errors.New("approval already responded")
+	ErrTeamNotFound     = errors.New("team not found")
 )
 
 // Status represents the status of an approval request
@@ -49,6 +50,7 @@ type ApprovalRequest struct {
 	RequestedBy     uuid.UUID  `json:"requested_by"`
 	RequestedAt     time.Time  `json:"requested_at"`
 	Message         *string    `json:"message,omitempty"`
+	TeamID          *uuid.UUID `json:"team_id,omitempty"`
 
 	// Response
 	Status          Status     `json:"status"`
@@ -79,7 +81,7 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 
 // approvalColumns is the standard column list
 const approvalColumns = `id, document_id, client_id, requested_by, requested_at,
-	message, status, responded_at, response_comment, reminder_sent_at, reminder_count, created_at`
+	message, team_id, status, responded_at, response_comment, reminder_sent_at, reminder_count, created_at`
 
 // Create creates a new approval request
 func (r *Repository) Create(ctx context.Context, approval *ApprovalRequest) error {
@@ -89,9 +91,9 @@ func (r *Repository) Create(ctx context.Context, approval *ApprovalRequest) erro
 
 	query := `
 		INSERT INTO approval_requests (
-			id, document_id, client_id, requested_by, message
+			id, document_id, client_id, requested_by, message, team_id
 		)
-		VALUES ($1, $2, $3, $4, $5)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING requested_at, created_at
 	`
 
@@ -101,6 +103,7 @@ func (r *Repository) Create(ctx context.Context, approval *ApprovalRequest) erro
 		approval.ClientID,
 		approval.RequestedBy,
 		approval.Message,
+		approval.TeamID,
 	).Scan(&approval.RequestedAt, &approval.CreatedAt)
 
 	if err != nil {
@@ -121,7 +124,7 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*ApprovalReques
 func (r *Repository) GetByIDWithDetails(ctx context.Context, id uuid.UUID) (*ApprovalRequest, error) {
 	query := `
 		SELECT ar.id, ar.document_id, ar.client_id, ar.requested_by, ar.requested_at,
-			ar.message, ar.status, ar.responded_at, ar.response_comment,
+			ar.message, ar.team_id, ar.status, ar.responded_at, ar.response_comment,
 			ar.reminder_sent_at, ar.reminder_count, ar.created_at,
 			d.title as document_title, c.name as client_name, c.email as client_email
 		FROM approval_requests ar
@@ -133,7 +136,7 @@ func (r *Repository) GetByIDWithDetails(ctx context.Context, id uuid.UUID) (*App
 	approval := &ApprovalRequest{}
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&approval.ID, &approval.DocumentID, &approval.ClientID, &approval.RequestedBy,
-		&approval.RequestedAt, &approval.Message, &approval.Status, &approval.RespondedAt,
+		&approval.RequestedAt, &approval.Message, &approval.TeamID, &approval.Status, &approval.RespondedAt,
 		&approval.ResponseComment, &approval.ReminderSentAt, &approval.ReminderCount,
 		&approval.CreatedAt, &approval.DocumentTitle, &approval.ClientName, &approval.ClientEmail,
 	)
@@ -153,7 +156,7 @@ func (r *Repository) ListByClient(ctx context.Context, clientID uuid.UUID, statu
 	countQuery := `SELECT COUNT(*) FROM approval_requests WHERE client_id = $1`
 	listQuery := `
 		SELECT ar.id, ar.document_id, ar.client_id, ar.requested_by, ar.requested_at,
-			ar.message, ar.status, ar.responded_at, ar.response_comment,
+			ar.message, ar.team_id, ar.status, ar.responded_at, ar.response_comment,
 			ar.reminder_sent_at, ar.reminder_count, ar.created_at,
 			d.title as document_title
 		FROM approval_requests ar
@@ -200,7 +203,7 @@ func (r *Repository) ListByClient(ctx context.Context, clientID uuid.UUID, statu
 		approval := &ApprovalRequest{}
 		err := rows.Scan(
 			&approval.ID, &approval.DocumentID, &approval.ClientID, &approval.RequestedBy,
-			&approval.RequestedAt, &approval.Message, &approval.Status, &approval.RespondedAt,
+			&approval.RequestedAt, &approval.Message, &approval.TeamID, &approval.Status, &approval.RespondedAt,
 			&approval.ResponseComment, &approval.ReminderSentAt, &approval.ReminderCount,
 			&approval.CreatedAt, &approval.DocumentTitle,
 		)
@@ -215,38 +218,58 @@ func (r *Repository) ListByClient(ctx context.Context, clientID uuid.UUID, statu
 
 // ListPendingByTenant returns all pending approvals for a tenant
 func (r *Repository) ListPendingByTenant(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*ApprovalRequest, int, error) {
+	return r.listPending(ctx, tenantID, "", nil, limit, offset)
+}
+
+// ListPendingForUsers returns the pending approvals of a tenant requested
+// by one of the users or assigned to one of the teams
+func (r *Repository) ListPendingForUsers(ctx context.Context, tenantID uuid.UUID, userIDs, teamIDs []uuid.UUID, limit, offset int) ([]*ApprovalRequest, int, error) {
+	if teamIDs == nil {
+		teamIDs = []uuid.UUID{}
+	}
+	return r.listPending(ctx, tenantID, ` AND (ar.requested_by = ANY($2) OR ar.team_id = ANY($3))`,
+		[]interface{}{userIDs, teamIDs}, limit, offset)
+}
+
+// listPending lists the pending approvals of a tenant matching filter, a
+// condition on ar using the parameters after the tenant
+func (r *Repository) listPending(ctx context.Context, tenantID uuid.UUID, filter string, filterArgs []interface{}, limit, offset int) ([]*ApprovalRequest, int, error) {
 	countQuery := `
 		SELECT COUNT(*)
 		FROM approval_requests ar
 		JOIN clients c ON ar.client_id = c.id
 		WHERE c.tenant_id = $1 AND ar.status = 'pending'
-	`
+	` + filter
 
 	listQuery := `
 		SELECT ar.id, ar.document_id, ar.client_id, ar.requested_by, ar.requested_at,
-			ar.message, ar.status, ar.responded_at, ar.response_comment,
+			ar.message, ar.team_id, ar.status, ar.responded_at, ar.response_comment,
 			ar.reminder_sent_at, ar.reminder_count, ar.created_at,
 			d.title as document_title, c.name as client_name, c.email as client_email
 		FROM approval_requests ar
 		JOIN documents d ON ar.document_id = d.id
 		JOIN clients c ON ar.client_id = c.id
 		WHERE c.tenant_id = $1 AND ar.status = 'pending'
+	` + filter + `
 		ORDER BY ar.requested_at ASC
 	`
 
+	args := append([]interface{}{tenantID}, filterArgs...)
+
 	var total int
-	err := r.pool.QueryRow(ctx, countQuery, tenantID).Scan(&total)
+	err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	args := []interface{}{tenantID}
+	argNum := len(args) + 1
 	if limit > 0 {
-		listQuery += ` LIMIT $2`
+		listQuery += ` LIMIT $` + itoa(argNum)
 		args = append(args, limit)
+		argNum++
 	}
 	if offset > 0 {
-		listQuery += ` OFFSET $3`
+		listQuery += ` OFFSET $` + itoa(argNum)
 		args = append(args, offset)
 	}
 
@@ -261,7 +284,7 @@ func (r *Repository) ListPendingByTenant(ctx context.Context, tenantID uuid.UUID
 		approval := &ApprovalRequest{}
 		err := rows.Scan(
 			&approval.ID, &approval.DocumentID, &approval.ClientID, &approval.RequestedBy,
-			&approval.RequestedAt, &approval.Message, &approval.Status, &approval.RespondedAt,
+			&approval.RequestedAt, &approval.Message, &approval.TeamID, &approval.Status, &approval.RespondedAt,
 			&approval.ResponseComment, &approval.ReminderSentAt, &approval.ReminderCount,
 			&approval.CreatedAt, &approval.DocumentTitle, &approval.ClientName, &approval.ClientEmail,
 		)
@@ -274,6 +297,13 @@ func (r *Repository) ListPendingByTenant(ctx context.Context, tenantID uuid.UUID
 	return approvals, total, rows.Err()
 }
 
+// TeamInTenant reports whether a team belongs to a tenant
+func (r *Repository) TeamInTenant(ctx context.Context, tenantID, teamID uuid.UUID) (bool, error) {
+	var ok bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM teams WHERE id = $1 AND tenant_id = $2)`, teamID, tenantID).Scan(&ok)
+	return ok, err
+}
+
 // Approve approves an approval request
 func (r *Repository) Approve(ctx context.Context, id uuid.UUID) error {
 	query := `
@@ -359,7 +389,7 @@ func (r *Repository) scanApproval(row pgx.Row) (*ApprovalRequest, error) {
 	approval := &ApprovalRequest{}
 	err := row.Scan(
 		&approval.ID, &approval.DocumentID, &approval.ClientID, &approval.RequestedBy,
-		&approval.RequestedAt, &approval.Message, &approval.Status, &approval.RespondedAt,
+		&approval.RequestedAt, &approval.Message, &approval.TeamID, &approval.Status, &approval.RespondedAt,
 		&approval.ResponseComment, &approval.ReminderSentAt, &approval.ReminderCount,
 		&approval.CreatedAt,
 	)
@@ -2,6 +2,7 @@ package approval
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -13,12 +14,25 @@ type CreateRequest struct {
 	ClientID    uuid.UUID `json:"client_id"`
 	RequestedBy uuid.UUID `json:"requested_by"`
 	Message     *string   `json:"message,omitempty"`
+	// TeamID lets the whole team handle the request; it must belong to
+	// TenantID
+	TeamID   *uuid.UUID `json:"team_id,omitempty"`
+	TenantID uuid.UUID  `json:"-"`
+}
+
+// Delegation tells who a staff member handles approvals for: the users
+// they currently stand in for and the teams of all of them; team.Service
+// implements it
+type Delegation interface {
+	ApprovalPrincipals(ctx context.Context, tenantID, userID uuid.UUID) ([]uuid.UUID, error)
+	TeamIDs(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error)
 }
 
 // Service provides approval business logic
 type Service struct {
-	repo *Repository
-	pool *pgxpool.Pool
+	repo       *Repository
+	pool       *pgxpool.Pool
+	delegation Delegation
 }
 
 // NewService creates a new approval service
@@ -34,13 +48,30 @@ func (s *Service) Repository() *Repository {
 	return s.repo
 }
 
+// SetDelegation enables listing the approvals of absent colleagues and of
+// teams
+func (s *Service) SetDelegation(d Delegation) {
+	s.delegation = d
+}
+
 // Create creates a new approval request
 func (s *Service) Create(ctx context.Context, req *CreateRequest) (*ApprovalRequest, error) {
+	if req.TeamID != nil {
+		ok, err := s.repo.TeamInTenant(ctx, req.TenantID, *req.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrTeamNotFound, req.TeamID)
+		}
+	}
+
 	approval := &ApprovalRequest{
 		DocumentID:  req.DocumentID,
 		ClientID:    req.ClientID,
 		RequestedBy: req.RequestedBy,
 		Message:     req.Message,
+		TeamID:      req.TeamID,
 	}
 
 	if err := s.repo.Create(ctx, approval); err != nil {
@@ -70,6 +101,24 @@ func (s *Service) ListPendingForTenant(ctx context.Context, tenantID uuid.UUID,
 	return s.repo.ListPendingByTenant(ctx, tenantID, limit, offset)
 }
 
+// ListPendingForStaff returns the pending approvals a staff member handles:
+// those they requested, those of the colleagues they stand in for, and
+// those assigned to a team of any of them
+func (s *Service) ListPendingForStaff(ctx context.Context, tenantID, userID uuid.UUID, limit, offset int) ([]*ApprovalRequest, int, error) {
+	principals := []uuid.UUID{userID}
+	var teams []uuid.UUID
+	if s.delegation != nil {
+		var err error
+		if principals, err = s.delegation.ApprovalPrincipals(ctx, tenantID, userID); err != nil {
+			return nil, 0, err
+		}
+		if teams, err = s.delegation.TeamIDs(ctx, tenantID, principals); err != nil {
+			return nil, 0, err
+		}
+	}
+	return s.repo.ListPendingForUsers(ctx, tenantID, principals, teams, limit, offset)
+}
+
 // Approve approves an approval request
 func (s *Service) Approve(ctx context.Context, approvalID uuid.UUID) error {
 	return s.repo.Approve(ctx, approvalID)
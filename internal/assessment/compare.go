@@ -0,0 +1,274 @@
+package assessment
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/uva"
+)
+
+// kennzahl is a UVA Kennzahl the comparison knows
+type kennzahl struct {
+	code  string
+	label string
+	value func(d *uva.UVAData) int64
+}
+
+// kennzahlen lists the compared Kennzahlen in form order
+var kennzahlen = []kennzahl{
+	{"000", "Gesamtbetrag der Lieferungen", func(d *uva.UVAData) int64 { return d.KZ000 }},
+	{"001", "Innergemeinschaftliche Lieferungen", func(d *uva.UVAData) int64 { return d.KZ001 }},
+	{"011", "Steuerfreie Umsätze ohne Vorsteuerabzug", func(d *uva.UVAData) int64 { return d.KZ011 }},
+	{"017", "Normalsteuersatz 20 %", func(d *uva.UVAData) int64 { return d.KZ017 }},
+	{"018", "Ermäßigter Steuersatz 10 %", func(d *uva.UVAData) int64 { return d.KZ018 }},
+	{"019", "Ermäßigter Steuersatz 13 %", func(d *uva.UVAData) int64 { return d.KZ019 }},
+	{"020", "Sonstige Steuersätze", func(d *uva.UVAData) int64 { return d.KZ020 }},
+	{"022", "Einfuhrumsatzsteuer", func(d *uva.UVAData) int64 { return d.KZ022 }},
+	{"029", "Innergemeinschaftliche Erwerbe", func(d *uva.UVAData) int64 { return d.KZ029 }},
+	{"060", "Vorsteuern", func(d *uva.UVAData) int64 { return d.KZ060 }},
+	{"065", "Einfuhrumsatzsteuer als Vorsteuer", func(d *uva.UVAData) int64 { return d.KZ065 }},
+	{"066", "Vorsteuern aus innergemeinschaftlichen Erwerben", func(d *uva.UVAData) int64 { return d.KZ066 }},
+	{"070", "Sonstige Berichtigungen", func(d *uva.UVAData) int64 { return d.KZ070 }},
+	{"095", "Zahllast/Gutschrift", func(d *uva.UVAData) int64 { return d.KZ095 }},
+}
+
+// KennzahlTotal is the Kennzahl of the Zahllast (positive) or Gutschrift
+// (negative)
+const KennzahlTotal = "095"
+
+var (
+	kennzahlLine = regexp.MustCompile(`(?i)\b(?:KZ|Kennzahl)\.?\s*(\d{3})\b([^\n]*)`)
+	totalLine    = regexp.MustCompile(`(?i)\b(Zahllast|Gutschrift|Überschuss|Ueberschuss)\b([^\n]*)`)
+	amountText   = regexp.MustCompile(`(-\s?)?\b(\d{1,3}(?:\.\d{3})+|\d+),(\d{2})\b(-)?`)
+	creditWord   = regexp.MustCompile(`(?i)gutschrift|überschuss|ueberschuss`)
+)
+
+// SubmittedAmounts returns the Kennzahlen of a submitted UVA, in cents
+func SubmittedAmounts(data *uva.UVAData) map[string]int64 {
+	amounts := make(map[string]int64, len(kennzahlen))
+	for _, kz := range kennzahlen {
+		amounts[kz.code] = kz.value(data)
+	}
+	return amounts
+}
+
+// ParseAssessed extracts the assessed Kennzahlen from the text of a Bescheid,
+// in cents. A Kennzahl counts when a line names it ("KZ 060", "Kennzahl
+// 060") followed by an amount; the Zahllast or Gutschrift is also taken from
+// a labeled line. Bescheide list only the Kennzahlen they assess, so
+// Kennzahlen not found are not compared.
+func ParseAssessed(text string) map[string]int64 {
+	known := make(map[string]bool, len(kennzahlen))
+	for _, kz := range kennzahlen {
+		known[kz.code] = true
+	}
+
+	assessed := make(map[string]int64)
+	for _, m := range kennzahlLine.FindAllStringSubmatch(text, -1) {
+		code, rest := m[1], m[2]
+		if !known[code] {
+			continue
+		}
+		if _, seen := assessed[code]; seen {
+			continue
+		}
+		amount, ok := parseAmount(rest)
+		if !ok {
+			continue
+		}
+		if code == KennzahlTotal && amount > 0 && creditWord.MatchString(rest) {
+			amount = -amount
+		}
+		assessed[code] = amount
+	}
+
+	if _, ok := assessed[KennzahlTotal]; !ok {
+		for _, m := range totalLine.FindAllStringSubmatch(text, -1) {
+			amount, ok := parseAmount(m[2])
+			if !ok {
+				continue
+			}
+			if amount > 0 && creditWord.MatchString(m[1]) {
+				amount = -amount
+			}
+			assessed[KennzahlTotal] = amount
+			break
+		}
+	}
+	return assessed
+}
+
+// parseAmount parses the first Austrian amount (1.234,56) in s into cents.
+// A leading or trailing minus makes it negative.
+func parseAmount(s string) (int64, bool) {
+	m := amountText.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	euros, err := strconv.ParseInt(strings.ReplaceAll(m[2], ".", ""), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	cents, _ := strconv.ParseInt(m[3], 10, 64)
+	amount := euros*100 + cents
+	if m[1] != "" || m[4] != "" {
+		amount = -amount
+	}
+	return amount, true
+}
+
+// Compare compares the assessed Kennzahlen with the submitted ones, in form
+// order. Only Kennzahlen found in the Bescheid are compared.
+func Compare(submitted, assessed map[string]int64, cfg Config) []Line {
+	lines := make([]Line, 0, len(assessed))
+	for _, kz := range kennzahlen {
+		value, ok := assessed[kz.code]
+		if !ok {
+			continue
+		}
+		line := Line{
+			Kennzahl:   kz.code,
+			Label:      kz.label,
+			Submitted:  submitted[kz.code],
+			Assessed:   value,
+			Difference: value - submitted[kz.code],
+		}
+		if line.Submitted != 0 {
+			line.Share = float64(line.Difference) / float64(abs(line.Submitted))
+		}
+		line.Significant = isSignificant(line, cfg)
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func isSignificant(line Line, cfg Config) bool {
+	diff := abs(line.Difference)
+	if diff <= cfg.Tolerance {
+		return false
+	}
+	if line.Submitted == 0 || diff >= cfg.MinDifference {
+		return true
+	}
+	return float64(diff) >= cfg.MinShare*float64(abs(line.Submitted))
+}
+
+// AppealDeadline returns the deadline of a Beschwerde against a Bescheid.
+// A deadline found in the Bescheid wins; otherwise it is one month from
+// delivery (§ 245 BAO), moved to the next Monday when it ends on a weekend
+// (§ 108 BAO).
+func AppealDeadline(receivedAt time.Time, extracted []time.Time) (time.Time, string) {
+	var earliest time.Time
+	for _, d := range extracted {
+		if earliest.IsZero() || d.Before(earliest) {
+			earliest = d
+		}
+	}
+	if !earliest.IsZero() {
+		return dateOf(earliest), DeadlineExtracted
+	}
+
+	deadline := addMonth(dateOf(receivedAt))
+	switch deadline.Weekday() {
+	case time.Saturday:
+		deadline = deadline.AddDate(0, 0, 2)
+	case time.Sunday:
+		deadline = deadline.AddDate(0, 0, 1)
+	}
+	return deadline, DeadlineStatutory
+}
+
+// addMonth adds one month; a day the next month lacks becomes its last day
+func addMonth(d time.Time) time.Time {
+	next := time.Date(d.Year(), d.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	last := next.AddDate(0, 1, -1).Day()
+	day := d.Day()
+	if day > last {
+		day = last
+	}
+	return time.Date(next.Year(), next.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// PeriodLabel formats a UVA period, e.g. 09/2026 or Q3/2026
+func PeriodLabel(year int, month, quarter *int) string {
+	switch {
+	case month != nil:
+		return fmt.Sprintf("%02d/%d", *month, year)
+	case quarter != nil:
+		return fmt.Sprintf("Q%d/%d", *quarter, year)
+	}
+	return strconv.Itoa(year)
+}
+
+// Draft returns a pre-filled Beschwerde (§ 243 BAO) against the Bescheid,
+// naming the contested Kennzahlen and requesting the assessment as
+// submitted. The reasoning is left for the advisor to complete.
+func Draft(b *Bescheid, period string, lines []Line) string {
+	var sb strings.Builder
+	sb.WriteString("An das Finanzamt Österreich\n\n")
+	sb.WriteString("Beschwerde gemäß § 243 BAO\n\n")
+	fmt.Fprintf(&sb, "Gegen den Bescheid über die Festsetzung der Umsatzsteuer für den Zeitraum %s", period)
+	if !b.ReceivedAt.IsZero() {
+		fmt.Fprintf(&sb, ", zugestellt am %s,", b.ReceivedAt.Format("02.01.2006"))
+	}
+	sb.WriteString(" wird innerhalb offener Frist Beschwerde erhoben.\n\n")
+
+	sb.WriteString("Der Bescheid weicht in folgenden Kennzahlen von der eingereichten Umsatzsteuervoranmeldung ab:\n")
+	var total *Line
+	for i := range lines {
+		line := &lines[i]
+		if line.Kennzahl == KennzahlTotal {
+			total = line
+		}
+		if !line.Significant {
+			continue
+		}
+		fmt.Fprintf(&sb, "- KZ %s (%s): erklärt EUR %s, festgesetzt EUR %s, Differenz EUR %s\n",
+			line.Kennzahl, line.Label, formatEUR(line.Submitted), formatEUR(line.Assessed), formatEUR(line.Difference))
+	}
+
+	sb.WriteString("\nEs wird beantragt, den Bescheid dahingehend abzuändern, dass die Umsatzsteuer erklärungsgemäß festgesetzt wird")
+	if total != nil {
+		if total.Submitted < 0 {
+			fmt.Fprintf(&sb, " (Gutschrift EUR %s)", formatEUR(-total.Submitted))
+		} else {
+			fmt.Fprintf(&sb, " (Zahllast EUR %s)", formatEUR(total.Submitted))
+		}
+	}
+	sb.WriteString(".\n\n")
+	sb.WriteString("Begründung:\n[Begründung ergänzen]\n\n")
+	sb.WriteString("Beilagen:\n[Belege ergänzen]\n")
+	return sb.String()
+}
+
+// formatEUR formats cents as an Austrian amount, e.g. -1.234,50
+func formatEUR(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	euros := strconv.FormatInt(cents/100, 10)
+	var b strings.Builder
+	for i, r := range euros {
+		if i > 0 && (len(euros)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	return fmt.Sprintf("%s%s,%02d", sign, b.String(), cents%100)
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,182 @@
+package assessment
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// Handler handles assessment variance HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new assessment variance handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers assessment variance routes
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/assessment-variances", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("POST /api/v1/assessment-variances/check", requireAuth(http.HandlerFunc(h.Check)))
+	router.Handle("GET /api/v1/assessment-variances/{id}", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("POST /api/v1/assessment-variances/{id}/accept", requireAuth(http.HandlerFunc(h.Accept)))
+	router.Handle("POST /api/v1/assessment-variances/{id}/appeal", requireAuth(http.HandlerFunc(h.Appeal)))
+}
+
+// CheckRequest names the Bescheid to compare
+type CheckRequest struct {
+	DocumentID uuid.UUID `json:"document_id"`
+}
+
+// ReviewRequest is the optional body of an accept or appeal request
+type ReviewRequest struct {
+	Note string `json:"note"`
+}
+
+// List handles GET /api/v1/assessment-variances
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	filter := ListFilter{TenantID: tenantID, Limit: 50}
+	query := r.URL.Query()
+	if status := query.Get("status"); status != "" {
+		if status != StatusOpen && status != StatusAccepted && status != StatusAppealed {
+			api.BadRequest(w, "status must be open, accepted or appealed")
+			return
+		}
+		filter.Status = status
+	}
+	filter.SignificantOnly = query.Get("significant") == "true"
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	variances, total, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if variances == nil {
+		variances = []*Variance{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  variances,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// Check handles POST /api/v1/assessment-variances/check
+func (h *Handler) Check(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var req CheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.DocumentID == uuid.Nil {
+		api.BadRequest(w, "document_id is required")
+		return
+	}
+
+	variance, err := h.service.Check(r.Context(), tenantID, req.DocumentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, variance)
+}
+
+// Get handles GET /api/v1/assessment-variances/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	variance, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, variance)
+}
+
+// Accept handles POST /api/v1/assessment-variances/{id}/accept
+func (h *Handler) Accept(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, StatusAccepted)
+}
+
+// Appeal handles POST /api/v1/assessment-variances/{id}/appeal
+func (h *Handler) Appeal(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, StatusAppealed)
+}
+
+func (h *Handler) review(w http.ResponseWriter, r *http.Request, status string) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	var variance *Variance
+	var err error
+	if status == StatusAccepted {
+		variance, err = h.service.Accept(r.Context(), tenantID, id, api.OptionalUser(r), req.Note)
+	} else {
+		variance, err = h.service.Appeal(r.Context(), tenantID, id, api.OptionalUser(r), req.Note)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, variance)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrVarianceNotFound):
+		api.NotFound(w, "variance not found")
+	case errors.Is(err, ErrVarianceReviewed):
+		api.Conflict(w, err.Error())
+	case IsNotApplicable(err):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	default:
+		api.InternalError(w)
+	}
+}
@@ -0,0 +1,190 @@
+package assessment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/internal/document"
+)
+
+// Repository handles variance database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new variance repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Bescheid returns a document of a tenant with the UVA submission it is the
+// assessment of. The newest link wins if there are several.
+func (r *Repository) Bescheid(ctx context.Context, tenantID, documentID uuid.UUID) (*Bescheid, error) {
+	var b Bescheid
+	access, args := document.AccessCondition(ctx, "d", []interface{}{documentID, tenantID})
+	err := r.db.QueryRow(ctx, `
+		SELECT d.id, d.tenant_id, d.title, d.received_at, rel.target_id
+		FROM documents d
+		JOIN document_relations rel ON rel.document_id = d.id
+			AND rel.relation_type = 'assessment_of' AND rel.target_type = 'uva_submission'
+		WHERE d.id = $1 AND d.tenant_id = $2`+access+`
+		ORDER BY rel.created_at DESC
+		LIMIT 1`, args...,
+	).Scan(&b.DocumentID, &b.TenantID, &b.Title, &b.ReceivedAt, &b.SubmissionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoSubmission
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bescheid: %w", err)
+	}
+	return &b, nil
+}
+
+const varianceColumns = `id, tenant_id, document_id, uva_submission_id, period, lines, significant,
+	difference_cents, appeal_deadline, COALESCE(appeal_deadline_source, ''), COALESCE(draft, ''),
+	action_item_id, status, reviewed_by, reviewed_at, COALESCE(review_note, ''), created_at, updated_at`
+
+func scanVariance(row pgx.Row) (*Variance, error) {
+	var v Variance
+	var lines []byte
+	err := row.Scan(&v.ID, &v.TenantID, &v.DocumentID, &v.SubmissionID, &v.Period, &lines, &v.Significant,
+		&v.Difference, &v.AppealDeadline, &v.AppealDeadlineSource, &v.Draft,
+		&v.ActionItemID, &v.Status, &v.ReviewedBy, &v.ReviewedAt, &v.ReviewNote, &v.CreatedAt, &v.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(lines, &v.Lines); err != nil {
+		return nil, fmt.Errorf("failed to decode variance lines: %w", err)
+	}
+	return &v, nil
+}
+
+// GetByDocument returns the variance of a Bescheid
+func (r *Repository) GetByDocument(ctx context.Context, tenantID, documentID uuid.UUID) (*Variance, error) {
+	access, args := document.RowAccessCondition(ctx, "assessment_variances", []interface{}{documentID, tenantID})
+	v, err := scanVariance(r.db.QueryRow(ctx,
+		`SELECT `+varianceColumns+` FROM assessment_variances WHERE document_id = $1 AND tenant_id = $2`+access,
+		args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrVarianceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variance: %w", err)
+	}
+	return v, nil
+}
+
+// Save stores the variance of a Bescheid, replacing an earlier comparison of
+// the same document. The review and the action item of an earlier
+// comparison are kept.
+func (r *Repository) Save(ctx context.Context, v *Variance) error {
+	lines, err := json.Marshal(v.Lines)
+	if err != nil {
+		return fmt.Errorf("failed to encode variance lines: %w", err)
+	}
+	saved, err := scanVariance(r.db.QueryRow(ctx, `
+		INSERT INTO assessment_variances (
+			tenant_id, document_id, uva_submission_id, period, lines, significant, difference_cents,
+			appeal_deadline, appeal_deadline_source, draft, action_item_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''), $11)
+		ON CONFLICT (document_id) DO UPDATE SET
+			uva_submission_id = EXCLUDED.uva_submission_id,
+			period = EXCLUDED.period,
+			lines = EXCLUDED.lines,
+			significant = EXCLUDED.significant,
+			difference_cents = EXCLUDED.difference_cents,
+			appeal_deadline = EXCLUDED.appeal_deadline,
+			appeal_deadline_source = EXCLUDED.appeal_deadline_source,
+			draft = EXCLUDED.draft,
+			action_item_id = COALESCE(assessment_variances.action_item_id, EXCLUDED.action_item_id),
+			updated_at = NOW()
+		RETURNING `+varianceColumns,
+		v.TenantID, v.DocumentID, v.SubmissionID, v.Period, lines, v.Significant, v.Difference,
+		v.AppealDeadline, v.AppealDeadlineSource, v.Draft, v.ActionItemID))
+	if err != nil {
+		return fmt.Errorf("failed to save variance: %w", err)
+	}
+	*v = *saved
+	return nil
+}
+
+// List returns the variances of a tenant, newest first
+func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Variance, int, error) {
+	where := "tenant_id = $1"
+	args := []interface{}{filter.TenantID}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.SignificantOnly {
+		where += " AND significant"
+	}
+	access, args := document.RowAccessCondition(ctx, "assessment_variances", args)
+	where += access
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM assessment_variances WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count variances: %w", err)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT %s FROM assessment_variances
+		WHERE %s
+		ORDER BY created_at DESC, id
+		LIMIT $%d OFFSET $%d`, varianceColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list variances: %w", err)
+	}
+	defer rows.Close()
+
+	var variances []*Variance
+	for rows.Next() {
+		v, err := scanVariance(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		variances = append(variances, v)
+	}
+	return variances, total, rows.Err()
+}
+
+// Get returns a variance of a tenant
+func (r *Repository) Get(ctx context.Context, tenantID, id uuid.UUID) (*Variance, error) {
+	access, args := document.RowAccessCondition(ctx, "assessment_variances", []interface{}{id, tenantID})
+	v, err := scanVariance(r.db.QueryRow(ctx,
+		`SELECT `+varianceColumns+` FROM assessment_variances WHERE id = $1 AND tenant_id = $2`+access, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrVarianceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variance: %w", err)
+	}
+	return v, nil
+}
+
+// Review accepts an open variance or records that it was appealed
+func (r *Repository) Review(ctx context.Context, tenantID, id uuid.UUID, status string, reviewedBy *uuid.UUID, note string) (*Variance, error) {
+	access, args := document.RowAccessCondition(ctx, "assessment_variances", []interface{}{id, tenantID, status, reviewedBy, note})
+	v, err := scanVariance(r.db.QueryRow(ctx, `
+		UPDATE assessment_variances
+		SET status = $3, reviewed_by = $4, reviewed_at = NOW(), review_note = NULLIF($5, ''), updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status = 'open'`+access+`
+		RETURNING `+varianceColumns, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := r.Get(ctx, tenantID, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrVarianceReviewed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to review variance: %w", err)
+	}
+	return v, nil
+}
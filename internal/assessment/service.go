@@ -0,0 +1,178 @@
+package assessment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/analysis"
+	"austrian-business-infrastructure/internal/uva"
+)
+
+// Service compares Bescheide with the submitted UVAs and tracks the review
+// of the variances
+type Service struct {
+	repo     *Repository
+	uva      *uva.Repository
+	analysis *analysis.Repository
+	cfg      Config
+	now      func() time.Time
+}
+
+// NewService creates a new variance service with the default configuration
+func NewService(repo *Repository, uvaRepo *uva.Repository, analysisRepo *analysis.Repository) *Service {
+	return &Service{repo: repo, uva: uvaRepo, analysis: analysisRepo, cfg: DefaultConfig(), now: time.Now}
+}
+
+// SetConfig replaces the significance thresholds
+func (s *Service) SetConfig(cfg Config) {
+	s.cfg = cfg
+}
+
+// Check compares an analyzed Bescheid with the UVA it is the assessment of
+// and stores the result. When a Kennzahl differs significantly and the
+// appeal period is still open, a Beschwerde draft is added to the document
+// as an action item, once per document. Checking a document again updates
+// the comparison.
+func (s *Service) Check(ctx context.Context, tenantID, documentID uuid.UUID) (*Variance, error) {
+	bescheid, err := s.repo.Bescheid(ctx, tenantID, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	submission, err := s.uva.GetByID(ctx, bescheid.SubmissionID, tenantID)
+	if err != nil {
+		if errors.Is(err, uva.ErrSubmissionNotFound) {
+			return nil, ErrNoSubmission
+		}
+		return nil, err
+	}
+	if submission.Status != uva.StatusSubmitted && submission.Status != uva.StatusAccepted {
+		return nil, ErrNoSubmission
+	}
+	var data uva.UVAData
+	if err := json.Unmarshal(submission.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode UVA data: %w", err)
+	}
+
+	result, err := s.analysis.GetAnalysisByDocumentID(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, analysis.ErrAnalysisNotFound) {
+			return nil, ErrNoAnalysis
+		}
+		return nil, err
+	}
+	assessed := ParseAssessed(result.ExtractedText)
+	if len(assessed) == 0 {
+		return nil, ErrNoAssessedAmounts
+	}
+
+	deadlines, err := s.analysis.GetDeadlinesByDocument(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+	var extracted []time.Time
+	for _, d := range deadlines {
+		if d.DeadlineType == analysis.DeadlineTypeAppeal {
+			extracted = append(extracted, d.Date)
+		}
+	}
+	deadline, source := AppealDeadline(bescheid.ReceivedAt, extracted)
+
+	period := PeriodLabel(submission.PeriodYear, submission.PeriodMonth, submission.PeriodQuarter)
+	lines := Compare(SubmittedAmounts(&data), assessed, s.cfg)
+	v := &Variance{
+		TenantID:             tenantID,
+		DocumentID:           documentID,
+		SubmissionID:         submission.ID,
+		Period:               period,
+		Lines:                lines,
+		AppealDeadline:       &deadline,
+		AppealDeadlineSource: source,
+	}
+	for _, line := range lines {
+		if line.Significant {
+			v.Significant = true
+		}
+		if line.Kennzahl == KennzahlTotal {
+			v.Difference = line.Difference
+		}
+	}
+	if v.Significant {
+		v.Draft = Draft(bescheid, period, lines)
+	}
+
+	previous, err := s.repo.GetByDocument(ctx, tenantID, documentID)
+	if err != nil && !errors.Is(err, ErrVarianceNotFound) {
+		return nil, err
+	}
+	open := previous == nil || (previous.ActionItemID == nil && previous.Status == StatusOpen)
+	if v.Significant && open && deadline.After(s.now()) {
+		item, err := s.createAppealTask(ctx, result, v, deadline)
+		if err != nil {
+			return nil, err
+		}
+		v.ActionItemID = &item.ID
+	}
+
+	if err := s.repo.Save(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// createAppealTask adds the Beschwerde draft to the Bescheid as an action
+// item, due AppealLead before the deadline
+func (s *Service) createAppealTask(ctx context.Context, result *analysis.Analysis, v *Variance, deadline time.Time) (*analysis.ActionItem, error) {
+	due := deadline.Add(-s.cfg.AppealLead)
+	if due.Before(s.now()) {
+		due = deadline
+	}
+	item := &analysis.ActionItem{
+		AnalysisID: result.ID,
+		DocumentID: v.DocumentID,
+		TenantID:   v.TenantID,
+		Title:      fmt.Sprintf("Beschwerde gegen Umsatzsteuerbescheid %s prüfen", v.Period),
+		Description: fmt.Sprintf("Der Bescheid weicht von der eingereichten UVA ab. Beschwerdefrist bis %s.\n\n%s",
+			deadline.Format("02.01.2006"), v.Draft),
+		Priority:   analysis.PriorityHigh,
+		Category:   ActionCategory,
+		Status:     analysis.ActionStatusPending,
+		DueDate:    &due,
+		Confidence: 1,
+	}
+	if err := s.analysis.CreateActionItem(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to create appeal task: %w", err)
+	}
+	return item, nil
+}
+
+// List returns the variances of a tenant
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]*Variance, int, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// Get returns a variance of a tenant
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Variance, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// Accept marks an open variance as accepted: the Bescheid stands
+func (s *Service) Accept(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID, note string) (*Variance, error) {
+	return s.repo.Review(ctx, tenantID, id, StatusAccepted, userID, note)
+}
+
+// Appeal records that a Beschwerde was filed against the Bescheid
+func (s *Service) Appeal(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID, note string) (*Variance, error) {
+	return s.repo.Review(ctx, tenantID, id, StatusAppealed, userID, note)
+}
@@ -0,0 +1,116 @@
+// Package assessment compares assessments (Bescheide) of the tax office with
+// the UVA we submitted for the same period. Differences are computed per
+// Kennzahl; a significant difference with an appeal period still open gets a
+// pre-filled Beschwerde draft as an action item of the Bescheid.
+package assessment
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrVarianceNotFound = errors.New("variance not found")
+	ErrVarianceReviewed = errors.New("variance has already been reviewed")
+
+	// The document cannot be compared; the worker skips these silently
+	ErrNoSubmission      = errors.New("document is not linked to a submitted UVA")
+	ErrNoAnalysis        = errors.New("document has not been analyzed")
+	ErrNoAssessedAmounts = errors.New("no assessed Kennzahlen found in the document")
+)
+
+// IsNotApplicable reports whether err means the document is not an
+// assessment that can be compared with a submission
+func IsNotApplicable(err error) bool {
+	return errors.Is(err, ErrNoSubmission) || errors.Is(err, ErrNoAnalysis) || errors.Is(err, ErrNoAssessedAmounts)
+}
+
+// Review states of variances
+const (
+	StatusOpen     = "open"
+	StatusAccepted = "accepted"
+	StatusAppealed = "appealed"
+)
+
+// Sources of the appeal deadline
+const (
+	DeadlineExtracted = "extracted" // appeal deadline found in the Bescheid
+	DeadlineStatutory = "statutory" // one month from delivery, § 245 BAO
+)
+
+// ActionCategory is the category of the Beschwerde action items
+const ActionCategory = "assessment_variance"
+
+// Config tunes when a difference is significant. Differences up to
+// Tolerance are rounding; above it a difference is significant when it
+// reaches MinDifference or MinShare of the submitted value.
+type Config struct {
+	Tolerance     int64   // In cents
+	MinDifference int64   // In cents
+	MinShare      float64 // Of the submitted value, e.g. 0.01
+	// AppealLead is how long before the appeal deadline the draft task is due
+	AppealLead time.Duration
+}
+
+// DefaultConfig returns the default significance thresholds
+func DefaultConfig() Config {
+	return Config{
+		Tolerance:     100,
+		MinDifference: 100_00,
+		MinShare:      0.01,
+		AppealLead:    7 * 24 * time.Hour,
+	}
+}
+
+// Line is the comparison of one Kennzahl
+type Line struct {
+	Kennzahl    string  `json:"kennzahl"`
+	Label       string  `json:"label"`
+	Submitted   int64   `json:"submitted_cents"`
+	Assessed    int64   `json:"assessed_cents"`
+	Difference  int64   `json:"difference_cents"` // Assessed minus submitted
+	Share       float64 `json:"share,omitempty"`  // Difference relative to the submitted value
+	Significant bool    `json:"significant"`
+}
+
+// Variance is the comparison of a Bescheid with the submitted UVA
+type Variance struct {
+	ID                   uuid.UUID  `json:"id"`
+	TenantID             uuid.UUID  `json:"tenant_id"`
+	DocumentID           uuid.UUID  `json:"document_id"`
+	SubmissionID         uuid.UUID  `json:"uva_submission_id"`
+	Period               string     `json:"period"`
+	Lines                []Line     `json:"lines"`
+	Significant          bool       `json:"significant"`
+	Difference           int64      `json:"difference_cents"` // Of the Zahllast/Gutschrift (KZ 095)
+	AppealDeadline       *time.Time `json:"appeal_deadline,omitempty"`
+	AppealDeadlineSource string     `json:"appeal_deadline_source,omitempty"`
+	Draft                string     `json:"draft,omitempty"`
+	ActionItemID         *uuid.UUID `json:"action_item_id,omitempty"`
+	Status               string     `json:"status"`
+	ReviewedBy           *uuid.UUID `json:"reviewed_by,omitempty"`
+	ReviewedAt           *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNote           string     `json:"review_note,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+// ListFilter filters variances
+type ListFilter struct {
+	TenantID        uuid.UUID
+	Status          string
+	SignificantOnly bool
+	Limit           int
+	Offset          int
+}
+
+// Bescheid is the document side of a comparison
+type Bescheid struct {
+	DocumentID   uuid.UUID
+	TenantID     uuid.UUID
+	Title        string
+	ReceivedAt   time.Time
+	SubmissionID uuid.UUID
+}
@@ -0,0 +1,156 @@
+package atrust
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// CMS object identifiers (RFC 5652, RFC 5035)
+var (
+	oidData              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidAttrContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidAttrMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidAttrSigningCertV2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+	oidSHA256            = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidECDSAWithSHA256   = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidQCStatements      = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 3}
+	oidQCCompliance      = asn1.ObjectIdentifier{0, 4, 0, 1862, 1, 1}
+	oidQCSSCD            = asn1.ObjectIdentifier{0, 4, 0, 1862, 1, 4}
+)
+
+type algorithmIdentifier struct {
+	Algorithm asn1.ObjectIdentifier
+}
+
+type cmsContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue // [0] EXPLICIT
+}
+
+type cmsSignedData struct {
+	Version          int
+	DigestAlgorithms []algorithmIdentifier `asn1:"set"`
+	EncapContentInfo cmsEncapContentInfo
+	Certificates     asn1.RawValue
+	SignerInfos      []cmsSignerInfo `asn1:"set"`
+}
+
+type cmsEncapContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+}
+
+type cmsSignerInfo struct {
+	Version            int
+	SID                cmsIssuerAndSerial
+	DigestAlgorithm    algorithmIdentifier
+	SignedAttrs        asn1.RawValue
+	SignatureAlgorithm algorithmIdentifier
+	Signature          []byte
+}
+
+type cmsIssuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type cmsAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+type essCertIDv2 struct {
+	CertHash []byte
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+// buildDetachedCMS creates a detached CMS SignedData over a SHA-256 document
+// digest, as embedded in the /Contents of a PAdES signature. The signed
+// attributes are those PAdES baseline requires: content type, message digest
+// and the signing certificate.
+func buildDetachedCMS(digest []byte, cert *x509.Certificate, key *ecdsa.PrivateKey, chain []*x509.Certificate) ([]byte, error) {
+	certHash := sha256.Sum256(cert.Raw)
+	signingCert, err := asn1.Marshal(signingCertificateV2{Certs: []essCertIDv2{{CertHash: certHash[:]}}})
+	if err != nil {
+		return nil, err
+	}
+	contentType, err := asn1.Marshal(oidData)
+	if err != nil {
+		return nil, err
+	}
+	messageDigest, err := asn1.Marshal(digest)
+	if err != nil {
+		return nil, err
+	}
+
+	var attrs [][]byte
+	for _, a := range []struct {
+		oid   asn1.ObjectIdentifier
+		value []byte
+	}{
+		{oidAttrContentType, contentType},
+		{oidAttrMessageDigest, messageDigest},
+		{oidAttrSigningCertV2, signingCert},
+	} {
+		attr, err := asn1.Marshal(cmsAttribute{
+			Type:   a.oid,
+			Values: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: a.value},
+		})
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, attr)
+	}
+	// DER orders the elements of a SET OF by their encoding
+	sort.Slice(attrs, func(i, j int) bool { return bytes.Compare(attrs[i], attrs[j]) < 0 })
+	attrBytes := bytes.Join(attrs, nil)
+
+	// The signature covers the attributes encoded as a SET, not as [0]
+	signedAttrsSet, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: attrBytes})
+	if err != nil {
+		return nil, err
+	}
+	attrsHash := sha256.Sum256(signedAttrsSet)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, attrsHash[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign attributes: %w", err)
+	}
+
+	var certBytes []byte
+	for _, c := range append([]*x509.Certificate{cert}, chain...) {
+		certBytes = append(certBytes, c.Raw...)
+	}
+
+	signedData, err := asn1.Marshal(cmsSignedData{
+		Version:          1,
+		DigestAlgorithms: []algorithmIdentifier{{Algorithm: oidSHA256}},
+		EncapContentInfo: cmsEncapContentInfo{EContentType: oidData},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certBytes},
+		SignerInfos: []cmsSignerInfo{{
+			Version:            1,
+			SID:                cmsIssuerAndSerial{Issuer: asn1.RawValue{FullBytes: cert.RawIssuer}, Serial: cert.SerialNumber},
+			DigestAlgorithm:    algorithmIdentifier{Algorithm: oidSHA256},
+			SignedAttrs:        asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attrBytes},
+			SignatureAlgorithm: algorithmIdentifier{Algorithm: oidECDSAWithSHA256},
+			Signature:          signature,
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(cmsContentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedData},
+	})
+}
@@ -0,0 +1,289 @@
+package atrust
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DevClient signs with a throwaway test CA instead of A-Trust. Unlike
+// MockClient it issues real X.509 certificates and returns real detached CMS
+// signatures, so the signing flow can run end to end offline. It is meant for
+// local development (APP_ENV=dev) together with the ID Austria mock provider.
+type DevClient struct {
+	mu          sync.Mutex
+	caKey       *ecdsa.PrivateKey
+	caCert      *x509.Certificate
+	certs       map[string]*devCertificate // per signer certificate ID
+	subjectName func(certID string) string
+}
+
+// devCertificate is a signer's test certificate and key
+type devCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// DevClientOption is a functional option for configuring the dev client
+type DevClientOption func(*DevClient)
+
+// WithSubjectNames sets how a signer certificate ID maps to the name in the
+// certificate, e.g. the ID Austria mock provider's SubjectName
+func WithSubjectNames(fn func(certID string) string) DevClientOption {
+	return func(c *DevClient) {
+		c.subjectName = fn
+	}
+}
+
+// NewDevClient creates a dev client with a fresh test CA
+func NewDevClient(opts ...DevClientOption) (*DevClient, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: randomSerial(),
+		Subject: pkix.Name{
+			CommonName:   "a-sign-Premium-Mobile-07 (Test)",
+			Organization: []string{"A-Trust Ges. f. Sicherheitssysteme im elektr. Datenverkehr GmbH (Mock)"},
+			Country:      []string{"AT"},
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+	caCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &DevClient{
+		caKey:  caKey,
+		caCert: caCert,
+		certs:  make(map[string]*devCertificate),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// CACertificate returns the test CA, e.g. to trust it when verifying
+func (c *DevClient) CACertificate() *x509.Certificate {
+	return c.caCert
+}
+
+// Sign signs a document hash with the signer's test certificate
+func (c *DevClient) Sign(ctx context.Context, req *SignRequest) (*SignResponse, error) {
+	digest, err := decodeSHA256(req.DocumentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	dc, err := c.certificate(req.SignerCertID)
+	if err != nil {
+		return nil, err
+	}
+
+	cms, err := buildDetachedCMS(digest, dc.cert, dc.key, []*x509.Certificate{c.caCert})
+	if err != nil {
+		return nil, &ATrustError{StatusCode: 500, Code: ErrCodeSignatureFailed, Message: err.Error()}
+	}
+
+	return &SignResponse{
+		Signature:          base64.StdEncoding.EncodeToString(cms),
+		SignedAt:           time.Now(),
+		Certificate:        base64.StdEncoding.EncodeToString(dc.cert.Raw),
+		CertificateChain:   []string{base64.StdEncoding.EncodeToString(dc.cert.Raw), base64.StdEncoding.EncodeToString(c.caCert.Raw)},
+		Timestamp:          generateMockTimestamp(),
+		TimestampAuthority: "A-Trust Timestamp Service (Mock)",
+	}, nil
+}
+
+// BatchSign signs several document hashes with the signer's test certificate
+func (c *DevClient) BatchSign(ctx context.Context, req *BatchSignRequest) (*BatchSignResponse, error) {
+	if len(req.Documents) > 100 {
+		return nil, &ATrustError{
+			StatusCode: 400,
+			Code:       ErrCodeBatchTooLarge,
+			Message:    "Batch size exceeds maximum of 100 documents",
+		}
+	}
+
+	results := make([]BatchSignResult, len(req.Documents))
+	for i, doc := range req.Documents {
+		resp, err := c.Sign(ctx, &SignRequest{
+			DocumentHash:  doc.DocumentHash,
+			HashAlgorithm: HashAlgoSHA256,
+			SignerCertID:  req.SignerCertID,
+			Reason:        req.Reason,
+		})
+		if err != nil {
+			result := BatchSignResult{ID: doc.ID, Error: err.Error()}
+			if atErr, ok := err.(*ATrustError); ok {
+				result.ErrorCode = atErr.Code
+			}
+			results[i] = result
+			continue
+		}
+		results[i] = BatchSignResult{
+			ID:          doc.ID,
+			Success:     true,
+			Signature:   resp.Signature,
+			Certificate: resp.Certificate,
+			Timestamp:   resp.Timestamp,
+		}
+	}
+
+	return &BatchSignResponse{Results: results, SignedAt: time.Now()}, nil
+}
+
+// GetTimestamp returns a mock timestamp; the dev client has no TSA
+func (c *DevClient) GetTimestamp(ctx context.Context, req *TimestampRequest) (*TimestampResponse, error) {
+	if _, err := decodeSHA256(req.Hash); err != nil {
+		return nil, err
+	}
+	return &TimestampResponse{
+		Token:        generateMockTimestamp(),
+		Time:         time.Now(),
+		Authority:    "A-Trust Timestamp Service (Mock)",
+		SerialNumber: generateRandomHex(16),
+	}, nil
+}
+
+// GetCertificateInfo describes the signer's test certificate
+func (c *DevClient) GetCertificateInfo(ctx context.Context, certID string) (*CertificateInfo, error) {
+	dc, err := c.certificate(certID)
+	if err != nil {
+		return nil, err
+	}
+	cert := dc.cert
+	return &CertificateInfo{
+		Subject:      cert.Subject.String(),
+		SubjectCN:    cert.Subject.CommonName,
+		Issuer:       cert.Issuer.String(),
+		IssuerCN:     cert.Issuer.CommonName,
+		SerialNumber: strings.ToUpper(cert.SerialNumber.Text(16)),
+		ValidFrom:    cert.NotBefore,
+		ValidTo:      cert.NotAfter,
+		IsQualified:  true,
+		KeyUsage:     []string{"digitalSignature", "nonRepudiation"},
+	}, nil
+}
+
+// HealthCheck always succeeds
+func (c *DevClient) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// certificate returns the signer's test certificate, issuing it on first use
+func (c *DevClient) certificate(certID string) (*devCertificate, error) {
+	if certID == "" {
+		return nil, &ATrustError{StatusCode: 400, Code: ErrCodeInvalidCertificate, Message: "signer certificate ID is required"}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if dc, ok := c.certs[certID]; ok {
+		return dc, nil
+	}
+
+	name := ""
+	if c.subjectName != nil {
+		name = c.subjectName(certID)
+	}
+	named := name != ""
+	if !named {
+		name = "Test Signer " + certID
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signer key: %w", err)
+	}
+
+	// Qualified certificates carry the ETSI QC statements (EN 319 412-5)
+	qcStatements, err := asn1.Marshal([]struct{ ID asn1.ObjectIdentifier }{{oidQCCompliance}, {oidQCSSCD}})
+	if err != nil {
+		return nil, err
+	}
+
+	subject := pkix.Name{CommonName: name, Country: []string{"AT"}, SerialNumber: certSerialAttribute(certID)}
+	if given, family, ok := strings.Cut(name, " "); named && ok {
+		subject.ExtraNames = []pkix.AttributeTypeAndValue{
+			{Type: asn1.ObjectIdentifier{2, 5, 4, 42}, Value: given}, // givenName
+			{Type: asn1.ObjectIdentifier{2, 5, 4, 4}, Value: family}, // surname
+		}
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:    randomSerial(),
+		Subject:         subject,
+		NotBefore:       now.Add(-time.Hour),
+		NotAfter:        now.AddDate(5, 0, 0),
+		KeyUsage:        x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+		ExtraExtensions: []pkix.Extension{{Id: oidQCStatements, Value: qcStatements}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, c.caCert, &key.PublicKey, c.caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue signer certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	dc := &devCertificate{cert: cert, key: key}
+	c.certs[certID] = dc
+	return dc, nil
+}
+
+// decodeSHA256 decodes a hex SHA-256 hash
+func decodeSHA256(hash string) ([]byte, error) {
+	digest, err := hex.DecodeString(hash)
+	if err != nil || len(digest) != sha256.Size {
+		return nil, &ATrustError{
+			StatusCode: 400,
+			Code:       ErrCodeInvalidHash,
+			Message:    "Invalid document hash format",
+		}
+	}
+	return digest, nil
+}
+
+// certSerialAttribute derives the subject serialNumber attribute
+func certSerialAttribute(certID string) string {
+	hash := sha256.Sum256([]byte(certID))
+	return "MOCK-" + strings.ToUpper(hex.EncodeToString(hash[:8]))
+}
+
+func randomSerial() *big.Int {
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	return serial
+}
+
+// Ensure DevClient implements Signer
+var _ Signer = (*DevClient)(nil)
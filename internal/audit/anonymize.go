@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// IP anonymization modes of audit logs
+const (
+	// IPModeFull stores no IP address at all
+	IPModeFull = "full"
+	// IPModeTruncate keeps the network prefix: /24 for IPv4, /48 for IPv6
+	IPModeTruncate = "truncate"
+	// IPModeHash stores a keyed hash whose salt rotates, so entries of the
+	// same address can be correlated within one salt period only
+	IPModeHash = "hash"
+)
+
+// DefaultIPMode is used for tenants without a setting of their own
+const DefaultIPMode = IPModeTruncate
+
+// hashPrefix marks hashed addresses, which are no valid IP addresses
+const hashPrefix = "h:"
+
+// ValidIPMode reports whether mode is a known anonymization mode
+func ValidIPMode(mode string) bool {
+	switch mode {
+	case IPModeFull, IPModeTruncate, IPModeHash:
+		return true
+	}
+	return false
+}
+
+// Anonymizer anonymizes client IP addresses before they are stored
+type Anonymizer struct {
+	key      []byte
+	rotation time.Duration
+	now      func() time.Time
+}
+
+// NewAnonymizer creates an anonymizer. key derives the hash salts; without
+// one a random key is used, so hashes cannot be linked across restarts.
+// The salt changes every rotation (default 24 hours).
+func NewAnonymizer(key []byte, rotation time.Duration) *Anonymizer {
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(fmt.Sprintf("audit: generate anonymization key: %v", err))
+		}
+	}
+	if rotation <= 0 {
+		rotation = 24 * time.Hour
+	}
+	return &Anonymizer{key: key, rotation: rotation, now: time.Now}
+}
+
+// SetClock replaces the time source of the salt rotation
+func (a *Anonymizer) SetClock(now func() time.Time) {
+	a.now = now
+}
+
+// SaltRotation returns how long a hash salt is used
+func (a *Anonymizer) SaltRotation() time.Duration {
+	return a.rotation
+}
+
+// Anonymize returns the form of ip stored under mode; empty means nothing
+// is stored. Values that are no IP address are dropped.
+func (a *Anonymizer) Anonymize(ip, mode string) string {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap().WithZone("")
+
+	switch mode {
+	case IPModeFull:
+		return ""
+	case IPModeHash:
+		return hashPrefix + a.hash(addr)
+	default:
+		return truncateIP(addr)
+	}
+}
+
+// hash is a keyed hash of the address with the salt of the current period
+func (a *Anonymizer) hash(addr netip.Addr) string {
+	period := make([]byte, 8)
+	binary.BigEndian.PutUint64(period, uint64(a.now().UnixNano()/int64(a.rotation)))
+
+	salt := hmac.New(sha256.New, a.key)
+	salt.Write([]byte("audit-ip-salt:"))
+	salt.Write(period)
+
+	mac := hmac.New(sha256.New, salt.Sum(nil))
+	mac.Write(addr.AsSlice())
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// truncateIP zeroes the host part of an address. Addresses of the same
+// network keep a common prefix, enough to spot attacks from one provider.
+// Examples:
+//   - 192.168.1.123 -> 192.168.1.0
+//   - 2001:db8:85a3:8d3:1319:8a2e:370:7348 -> 2001:db8:85a3::
+func truncateIP(addr netip.Addr) string {
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.Addr().String()
+}
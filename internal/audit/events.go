@@ -34,6 +34,10 @@ const (
 	EventSessionCreated = "auth.session_created"
 	// EventSessionTerminated is logged when a session is terminated
 	EventSessionTerminated = "auth.session_terminated"
+	// EventSSOLinkApproved is logged when an admin approves linking an SSO identity to a user
+	EventSSOLinkApproved = "auth.sso_link_approved"
+	// EventSSOLinkRemoved is logged when an admin unlinks a user's SSO identity or withdraws the approval
+	EventSSOLinkRemoved = "auth.sso_link_removed"
 )
 
 // Credential Events
@@ -124,6 +128,8 @@ const (
 	EventKeyRotationCompleted = "security.key_rotation_completed"
 	// EventKeyRotationFailed is logged when key rotation fails
 	EventKeyRotationFailed = "security.key_rotation_failed"
+	// EventSecurityPolicyUpdated is logged when a tenant changes its security policy
+	EventSecurityPolicyUpdated = "security.policy_updated"
 )
 
 // Resource Types for categorizing audit log entries
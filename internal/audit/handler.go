@@ -3,6 +3,7 @@ package audit
 import (
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -15,6 +16,7 @@ import (
 // Handler handles audit log HTTP requests
 type Handler struct {
 	repo   *Repository
+	policy *Policy
 	logger *slog.Logger
 }
 
@@ -26,9 +28,17 @@ func NewHandler(repo *Repository, logger *slog.Logger) *Handler {
 	}
 }
 
+// SetPolicy enables the audit settings routes and adds the settings in
+// effect to list and export responses
+func (h *Handler) SetPolicy(policy *Policy) {
+	h.policy = policy
+}
+
 // RegisterRoutes registers audit routes
 func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
 	router.Handle("GET /api/v1/audit-logs", requireAuth(requireAdmin(http.HandlerFunc(h.List))))
+	router.Handle("GET /api/v1/audit-logs/settings", requireAuth(requireAdmin(http.HandlerFunc(h.GetSettings))))
+	router.Handle("PUT /api/v1/audit-logs/settings", requireAuth(requireAdmin(http.HandlerFunc(h.UpdateSettings))))
 	router.Handle("GET /api/v1/audit-logs/statistics", requireAuth(requireAdmin(http.HandlerFunc(h.Statistics))))
 	router.Handle("GET /api/v1/audit-logs/export", requireAuth(requireAdmin(http.HandlerFunc(h.Export))))
 	router.Handle("GET /api/v1/audit-logs/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.GetByID))))
@@ -54,6 +64,7 @@ type ListResponse struct {
 	Limit      int            `json:"limit"`
 	Offset     int            `json:"offset"`
 	HasMore    bool           `json:"has_more"`
+	Metadata   *Settings      `json:"metadata,omitempty"` // how IPs are anonymized and how long logs are kept
 }
 
 // StatisticsResponse represents audit log statistics
@@ -95,6 +106,78 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 	api.JSONResponse(w, http.StatusOK, toAuditLogDTO(log))
 }
 
+// GetSettings handles GET /api/v1/audit-logs/settings
+func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if h.policy == nil {
+		api.NotFound(w, "Audit settings are not available")
+		return
+	}
+
+	settings, err := h.policy.Settings(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("failed to get audit settings", "error", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, settings)
+}
+
+// UpdateSettings handles PUT /api/v1/audit-logs/settings
+func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if h.policy == nil {
+		api.NotFound(w, "Audit settings are not available")
+		return
+	}
+
+	var req UpdateSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	var userID *uuid.UUID
+	if id, err := uuid.Parse(api.GetUserID(r.Context())); err == nil {
+		userID = &id
+	}
+
+	settings, err := h.policy.Update(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidIPMode) || errors.Is(err, ErrInvalidRetention) {
+			api.JSONError(w, http.StatusBadRequest, err.Error(), api.ErrCodeValidation)
+			return
+		}
+		h.logger.Error("failed to update audit settings", "error", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, settings)
+}
+
+// metadata returns the settings in effect for the tenant, nil if unknown
+func (h *Handler) metadata(r *http.Request, tenantID uuid.UUID) *Settings {
+	if h.policy == nil {
+		return nil
+	}
+	settings, err := h.policy.Settings(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Warn("failed to get audit settings", "error", err)
+		return nil
+	}
+	return settings
+}
+
 // Statistics handles GET /api/v1/audit-logs/statistics
 func (h *Handler) Statistics(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
@@ -143,15 +226,17 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	api.JSONResponse(w, http.StatusOK, ListResponse{
-		Logs:    dtos,
-		Total:   total,
-		Limit:   filter.Limit,
-		Offset:  filter.Offset,
-		HasMore: int64(filter.Offset+len(logs)) < total,
+		Logs:     dtos,
+		Total:    total,
+		Limit:    filter.Limit,
+		Offset:   filter.Offset,
+		HasMore:  int64(filter.Offset+len(logs)) < total,
+		Metadata: h.metadata(r, tenantID),
 	})
 }
 
-// Export handles GET /api/v1/audit-logs/export
+// Export handles GET /api/v1/audit-logs/export. Logs are written while they
+// are read from the database; ?fields= selects the fields of each JSON entry.
 func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
 	if err != nil {
@@ -163,13 +248,6 @@ func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
 	filter.Limit = 10000 // Max export limit
 	filter.Offset = 0
 
-	logs, err := h.repo.List(r.Context(), filter)
-	if err != nil {
-		h.logger.Error("failed to export audit logs", "error", err)
-		api.InternalError(w)
-		return
-	}
-
 	format := r.URL.Query().Get("format")
 	if format == "" {
 		format = "json"
@@ -177,76 +255,129 @@ func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
 
 	switch format {
 	case "json":
-		h.exportJSON(w, logs)
+		h.exportJSON(w, r, filter, h.metadata(r, tenantID))
 	case "csv":
-		h.exportCSV(w, logs)
+		h.exportCSV(w, r, filter)
 	default:
 		api.BadRequest(w, "Invalid format. Use 'json' or 'csv'")
 	}
 }
 
-func (h *Handler) exportJSON(w http.ResponseWriter, logs []*AuditLog) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Content-Disposition", "attachment; filename=audit-logs.json")
+func (h *Handler) exportJSON(w http.ResponseWriter, r *http.Request, filter *ListFilter, metadata *Settings) {
+	// The response starts with the first log, so a failing query still
+	// gets an error status
+	var stream *api.JSONStream
+	start := func() error {
+		w.Header().Set("Content-Disposition", "attachment; filename=audit-logs.json")
+		var err error
+		stream, err = api.NewJSONStream(w, http.StatusOK, "logs", api.ParseFields(r), map[string]interface{}{
+			"exported_at": time.Now().Format(time.RFC3339),
+			"metadata":    metadata,
+		})
+		return err
+	}
 
-	dtos := make([]*AuditLogDTO, len(logs))
-	for i, log := range logs {
-		dtos[i] = toAuditLogDTO(log)
+	err := h.repo.Each(r.Context(), filter, func(log *AuditLog) error {
+		if stream == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return stream.Write(toAuditLogDTO(log))
+	})
+	if err != nil {
+		h.logger.Error("failed to export audit logs", "error", err)
+		if stream == nil {
+			api.InternalError(w)
+			return
+		}
+		stream.Fail("export failed")
+		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"logs":       dtos,
-		"exported_at": time.Now().Format(time.RFC3339),
+	if stream == nil {
+		if err := start(); err != nil {
+			return
+		}
+	}
+	stream.Close(nil)
+}
+
+func (h *Handler) exportCSV(w http.ResponseWriter, r *http.Request, filter *ListFilter) {
+	var writer *csv.Writer
+	err := h.repo.Each(r.Context(), filter, func(log *AuditLog) error {
+		if writer == nil {
+			writer = h.startCSV(w)
+		}
+		return writer.Write(auditLogRecord(log))
 	})
+	if err == nil && writer != nil {
+		writer.Flush()
+		err = writer.Error()
+	}
+	if err != nil {
+		h.logger.Error("failed to export audit logs", "error", err)
+		if writer == nil {
+			api.InternalError(w)
+			return
+		}
+		// CSV has no way to mark an incomplete file: abort the response so
+		// the client sees a broken transfer rather than a short export
+		panic(http.ErrAbortHandler)
+	}
+
+	if writer == nil {
+		writer = h.startCSV(w)
+		writer.Flush()
+	}
 }
 
-func (h *Handler) exportCSV(w http.ResponseWriter, logs []*AuditLog) {
+// startCSV sends the headers and the header row of a CSV export
+func (h *Handler) startCSV(w http.ResponseWriter) *csv.Writer {
 	w.Header().Set("Content-Type", "text/csv")
 	w.Header().Set("Content-Disposition", "attachment; filename=audit-logs.csv")
 
 	writer := csv.NewWriter(w)
-	defer writer.Flush()
-
-	// Write header
 	writer.Write([]string{"ID", "User ID", "Action", "Resource Type", "Resource ID", "IP Address", "User Agent", "Created At"})
+	return writer
+}
 
-	// Write data
-	for _, log := range logs {
-		userID := ""
-		if log.UserID != nil {
-			userID = log.UserID.String()
-		}
+// auditLogRecord is the CSV row of an audit log
+func auditLogRecord(log *AuditLog) []string {
+	userID := ""
+	if log.UserID != nil {
+		userID = log.UserID.String()
+	}
 
-		resourceType := ""
-		if log.ResourceType != nil {
-			resourceType = *log.ResourceType
-		}
+	resourceType := ""
+	if log.ResourceType != nil {
+		resourceType = *log.ResourceType
+	}
 
-		resourceID := ""
-		if log.ResourceID != nil {
-			resourceID = log.ResourceID.String()
-		}
+	resourceID := ""
+	if log.ResourceID != nil {
+		resourceID = log.ResourceID.String()
+	}
 
-		ipAddress := ""
-		if log.IPAddress != nil {
-			ipAddress = *log.IPAddress
-		}
+	ipAddress := ""
+	if log.IPAddress != nil {
+		ipAddress = *log.IPAddress
+	}
 
-		userAgent := ""
-		if log.UserAgent != nil {
-			userAgent = *log.UserAgent
-		}
+	userAgent := ""
+	if log.UserAgent != nil {
+		userAgent = *log.UserAgent
+	}
 
-		writer.Write([]string{
-			log.ID.String(),
-			userID,
-			log.Action,
-			resourceType,
-			resourceID,
-			ipAddress,
-			userAgent,
-			log.CreatedAt.Format(time.RFC3339),
-		})
+	return []string{
+		log.ID.String(),
+		userID,
+		log.Action,
+		resourceType,
+		resourceID,
+		ipAddress,
+		userAgent,
+		log.CreatedAt.Format(time.RFC3339),
 	}
 }
 
@@ -3,7 +3,9 @@ package audit
 import (
 	"context"
 	"log/slog"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
 
 	"austrian-business-infrastructure/internal/api"
@@ -41,6 +43,7 @@ type Logger struct {
 	asyncQueue chan *AuditLog
 	wg         sync.WaitGroup
 	asyncMode  bool
+	policy     *Policy
 }
 
 // NewLogger creates a new audit logger (synchronous mode)
@@ -96,11 +99,17 @@ func (l *Logger) Close() {
 	}
 }
 
+// SetPolicy makes the logger anonymize IP addresses with the tenants' audit
+// settings. Without a policy they are truncated.
+func (l *Logger) SetPolicy(policy *Policy) {
+	l.policy = policy
+}
+
 // LogContext contains context information for logging
 type LogContext struct {
 	TenantID     *uuid.UUID
 	UserID       *uuid.UUID
-	IPAddress    *string
+	IPAddress    *string // client address as received; Log anonymizes it
 	UserAgent    *string
 	ResourceType *string
 	ResourceID   *uuid.UUID
@@ -144,7 +153,7 @@ func (l *Logger) Log(ctx context.Context, logCtx *LogContext, action string, det
 		ResourceType: logCtx.ResourceType,
 		ResourceID:   logCtx.ResourceID,
 		Details:      details,
-		IPAddress:    logCtx.IPAddress,
+		IPAddress:    l.anonymizeIP(ctx, logCtx),
 		UserAgent:    logCtx.UserAgent,
 	}
 
@@ -158,7 +167,7 @@ func (l *Logger) Log(ctx context.Context, logCtx *LogContext, action string, det
 		"user_id", logCtx.UserID,
 		"resource_type", logCtx.ResourceType,
 		"resource_id", logCtx.ResourceID,
-		"ip_address", logCtx.IPAddress,
+		"ip_address", log.IPAddress,
 	)
 
 	// In async mode, queue the log and return immediately
@@ -297,6 +306,23 @@ func (l *Logger) LogSessionTerminate(ctx context.Context, r *http.Request, sessi
 	return l.Log(ctx, logCtx, ActionSessionTerminate, nil)
 }
 
+// anonymizeIP returns the stored form of the client address, nil if none
+func (l *Logger) anonymizeIP(ctx context.Context, logCtx *LogContext) *string {
+	if logCtx.IPAddress == nil {
+		return nil
+	}
+	var ip string
+	if l.policy != nil {
+		ip = l.policy.AnonymizeIP(ctx, logCtx.TenantID, *logCtx.IPAddress)
+	} else {
+		ip = anonymizeIP(*logCtx.IPAddress)
+	}
+	if ip == "" {
+		return nil
+	}
+	return &ip
+}
+
 func ptr(s string) *string {
 	return &s
 }
@@ -306,77 +332,31 @@ func getClientIP(r *http.Request) string {
 	if xff != "" {
 		for i := 0; i < len(xff); i++ {
 			if xff[i] == ',' {
-				return anonymizeIP(xff[:i])
+				return strings.TrimSpace(xff[:i])
 			}
 		}
-		return anonymizeIP(xff)
+		return strings.TrimSpace(xff)
 	}
 
 	xri := r.Header.Get("X-Real-IP")
 	if xri != "" {
-		return anonymizeIP(xri)
+		return strings.TrimSpace(xri)
 	}
 
 	// RemoteAddr includes port, strip it
-	ip := r.RemoteAddr
-	for i := len(ip) - 1; i >= 0; i-- {
-		if ip[i] == ':' {
-			ip = ip[:i]
-			break
-		}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
 	}
-	return anonymizeIP(ip)
+	return r.RemoteAddr
 }
 
-// anonymizeIP removes the last octet of IPv4 addresses
-// and the last 80 bits of IPv6 addresses for DSGVO compliance.
+// anonymizeIP truncates an address to its network for DSGVO compliance,
+// the default when no policy is set.
 // Examples:
 //   - 192.168.1.123 -> 192.168.1.0
-//   - 2001:db8::1 -> 2001:db8::0
+//   - 2001:db8:85a3::8a2e:370:7334 -> 2001:db8:85a3::
 func anonymizeIP(ip string) string {
-	if ip == "" {
-		return ""
-	}
-
-	// Check for IPv6
-	hasColon := false
-	for i := 0; i < len(ip); i++ {
-		if ip[i] == ':' {
-			hasColon = true
-			break
-		}
-	}
-
-	if hasColon {
-		// IPv6: find last colon and zero everything after
-		// This is a simplified approach - zeroes last segment
-		lastColon := -1
-		for i := len(ip) - 1; i >= 0; i-- {
-			if ip[i] == ':' {
-				lastColon = i
-				break
-			}
-		}
-		if lastColon > 0 {
-			return ip[:lastColon+1] + "0"
-		}
-		return ip
-	}
-
-	// IPv4: replace last octet with 0
-	lastDot := -1
-	for i := len(ip) - 1; i >= 0; i-- {
-		if ip[i] == '.' {
-			lastDot = i
-			break
-		}
-	}
-
-	if lastDot > 0 {
-		return ip[:lastDot+1] + "0"
-	}
-
-	return ip
+	return (&Anonymizer{}).Anonymize(ip, IPModeTruncate)
 }
 
 // truncateUserAgent truncates user agent to max 255 characters
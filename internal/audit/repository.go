@@ -81,9 +81,25 @@ func (r *Repository) Create(ctx context.Context, log *AuditLog) error {
 // IMPORTANT: TenantID is REQUIRED for security - queries without tenant context will fail.
 // This enforces tenant isolation at the repository level.
 func (r *Repository) List(ctx context.Context, filter *ListFilter) ([]*AuditLog, error) {
+	var logs []*AuditLog
+	err := r.Each(ctx, filter, func(log *AuditLog) error {
+		logs = append(logs, log)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// Each calls fn for every audit log matching the filter, newest first, while
+// reading them from the database, so exports need not hold them all in
+// memory. An error from fn stops the iteration and is returned.
+// IMPORTANT: TenantID is REQUIRED, as for List.
+func (r *Repository) Each(ctx context.Context, filter *ListFilter, fn func(*AuditLog) error) error {
 	// Enforce tenant context - no tenant = no results (fail closed)
 	if filter.TenantID == nil {
-		return nil, ErrNoTenantContext
+		return ErrNoTenantContext
 	}
 
 	query := `
@@ -147,11 +163,10 @@ func (r *Repository) List(ctx context.Context, filter *ListFilter) ([]*AuditLog,
 
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var logs []*AuditLog
 	for rows.Next() {
 		log := &AuditLog{}
 		if err := rows.Scan(
@@ -166,12 +181,14 @@ func (r *Repository) List(ctx context.Context, filter *ListFilter) ([]*AuditLog,
 			&log.UserAgent,
 			&log.CreatedAt,
 		); err != nil {
-			return nil, err
+			return err
+		}
+		if err := fn(log); err != nil {
+			return err
 		}
-		logs = append(logs, log)
 	}
 
-	return logs, rows.Err()
+	return rows.Err()
 }
 
 // Count returns the count of audit logs matching the filter.
@@ -355,17 +372,19 @@ func (r *Repository) GetStatistics(ctx context.Context, tenantID uuid.UUID) (*St
 	return stats, nil
 }
 
-// ListForArchive returns audit logs older than the given date
-func (r *Repository) ListForArchive(ctx context.Context, tenantID uuid.UUID, olderThan time.Time, limit int) ([]*AuditLog, error) {
+// ListForArchive returns audit logs older than the given date, a page at a
+// time: the next page starts after the last log of the previous one (the
+// zero time and uuid.Nil for the first page)
+func (r *Repository) ListForArchive(ctx context.Context, tenantID uuid.UUID, olderThan time.Time, afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]*AuditLog, error) {
 	query := `
 		SELECT id, tenant_id, user_id, action, resource_type, resource_id, details, ip_address, user_agent, created_at
 		FROM audit_logs
-		WHERE tenant_id = $1 AND created_at < $2
-		ORDER BY created_at ASC
-		LIMIT $3
+		WHERE tenant_id = $1 AND created_at < $2 AND (created_at, id) > ($3, $4)
+		ORDER BY created_at ASC, id ASC
+		LIMIT $5
 	`
 
-	rows, err := r.pool.Query(ctx, query, tenantID, olderThan, limit)
+	rows, err := r.pool.Query(ctx, query, tenantID, olderThan, afterCreatedAt, afterID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -34,6 +36,8 @@ const (
 	EventConfigChanged        = "admin.config_changed"
 	EventUserSuspended        = "admin.user_suspended"
 	EventUserReactivated      = "admin.user_reactivated"
+	EventBreakGlassGranted    = "admin.break_glass_granted"
+	EventBreakGlassEnded      = "admin.break_glass_ended"
 )
 
 // Severity levels for security events
@@ -56,8 +60,39 @@ const (
 	OutcomeBlocked Outcome = "blocked"
 )
 
+// severityOrder lists the severities from lowest to highest
+var severityOrder = []Severity{SeverityInfo, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// Rank returns the position of the severity in severityOrder, or -1 for an
+// unknown severity
+func (s Severity) Rank() int {
+	for i, o := range severityOrder {
+		if s == o {
+			return i
+		}
+	}
+	return -1
+}
+
+// AtLeast reports whether the severity is at or above min. An empty min
+// matches every severity.
+func (s Severity) AtLeast(min Severity) bool {
+	if min == "" {
+		return true
+	}
+	return s.Rank() >= min.Rank()
+}
+
+// ParseSeverity parses a severity name
+func ParseSeverity(s string) (Severity, bool) {
+	severity := Severity(strings.ToLower(strings.TrimSpace(s)))
+	return severity, severity.Rank() >= 0
+}
+
 // SecurityEvent represents an enhanced security audit event
 type SecurityEvent struct {
+	// Seq is the export cursor assigned when the event is stored
+	Seq       int64             `json:"seq,omitempty"`
 	ID        string            `json:"id"`
 	Timestamp time.Time         `json:"timestamp"`
 	EventType string            `json:"event_type"`
@@ -114,6 +149,18 @@ func (a *SecurityAuditor) Log(ctx context.Context, event *SecurityEvent) {
 		event.Timestamp = time.Now().UTC()
 	}
 
+	a.write(ctx, event)
+
+	// Persist to store if configured
+	if a.store != nil {
+		if err := a.store.Store(ctx, event); err != nil {
+			a.logger.Error("failed to persist security event", "error", err, "event_id", event.ID)
+		}
+	}
+}
+
+// write logs a security event to the structured log
+func (a *SecurityAuditor) write(ctx context.Context, event *SecurityEvent) {
 	// Determine log level based on severity
 	level := slog.LevelInfo
 	switch event.Severity {
@@ -163,13 +210,6 @@ func (a *SecurityAuditor) Log(ctx context.Context, event *SecurityEvent) {
 	}
 
 	a.logger.Log(ctx, level, "security_event", attrs...)
-
-	// Persist to store if configured
-	if a.store != nil {
-		if err := a.store.Store(ctx, event); err != nil {
-			a.logger.Error("failed to persist security event", "error", err, "event_id", event.ID)
-		}
-	}
 }
 
 // Helper methods for common security events
@@ -273,7 +313,7 @@ func (a *SecurityAuditor) LogBruteForce(ctx context.Context, ip, target, request
 		Message:   "Suspected brute force attack",
 		Metadata: map[string]string{
 			"target":   target,
-			"attempts": string(rune(attempts)),
+			"attempts": strconv.Itoa(attempts),
 		},
 	})
 }
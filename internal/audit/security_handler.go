@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// SecurityEventHandler serves the security event export for SIEM collectors.
+// It is authenticated with a static bearer token rather than a user session:
+// collectors are machines, and the export spans all tenants.
+type SecurityEventHandler struct {
+	repo   *SecurityEventRepository
+	token  string
+	logger *slog.Logger
+}
+
+// NewSecurityEventHandler creates a new security event export handler
+func NewSecurityEventHandler(repo *SecurityEventRepository, token string, logger *slog.Logger) *SecurityEventHandler {
+	return &SecurityEventHandler{
+		repo:   repo,
+		token:  token,
+		logger: logger,
+	}
+}
+
+// RegisterRoutes registers the export route
+func (h *SecurityEventHandler) RegisterRoutes(router *api.Router) {
+	router.HandleFunc("GET /api/v1/security/events", h.Export)
+}
+
+// Export handles GET /api/v1/security/events?after=<seq>&min_severity=&type=&limit=
+// and returns events as NDJSON in sequence order. X-Next-After carries the
+// cursor for the next poll.
+func (h *SecurityEventHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if !api.BearerTokenMatches(r, h.token) {
+		api.JSONError(w, http.StatusUnauthorized, "Invalid export token", api.ErrCodeUnauthorized)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := SecurityEventFilter{EventType: q.Get("type")}
+	if v := q.Get("after"); v != "" {
+		after, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || after < 0 {
+			api.BadRequest(w, "Invalid after cursor")
+			return
+		}
+		filter.AfterSeq = after
+	}
+	if v := q.Get("min_severity"); v != "" {
+		severity, ok := ParseSeverity(v)
+		if !ok {
+			api.BadRequest(w, "Invalid min_severity")
+			return
+		}
+		filter.MinSeverity = severity
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			api.BadRequest(w, "Invalid limit")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	events, err := h.repo.List(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("failed to export security events", "error", err)
+		api.InternalError(w)
+		return
+	}
+	body, err := EncodeSecurityEvents(events)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	next := filter.AfterSeq
+	if len(events) > 0 {
+		next = events[len(events)-1].Seq
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Next-After", strconv.FormatInt(next, 10))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
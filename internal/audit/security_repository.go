@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SecurityEventFilter selects security events for export
+type SecurityEventFilter struct {
+	// AfterSeq returns events with a higher sequence number (export cursor)
+	AfterSeq    int64
+	MinSeverity Severity
+	EventType   string
+	Limit       int
+}
+
+// SecurityEventRepository stores security events. Unlike audit logs they are
+// platform-wide: the SIEM reads across tenants.
+type SecurityEventRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSecurityEventRepository creates a new security event repository
+func NewSecurityEventRepository(pool *pgxpool.Pool) *SecurityEventRepository {
+	return &SecurityEventRepository{pool: pool}
+}
+
+// Insert stores an event and sets its sequence number
+func (r *SecurityEventRepository) Insert(ctx context.Context, event *SecurityEvent) error {
+	metadata, err := json.Marshal(event.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	if event.Metadata == nil {
+		metadata = []byte("{}")
+	}
+
+	query := `
+		INSERT INTO security_events (id, occurred_at, event_type, severity, outcome, tenant_id, user_id, user_email,
+			ip_address, user_agent, request_id, method, path, resource, action, message, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING seq
+	`
+
+	return r.pool.QueryRow(ctx, query,
+		event.ID,
+		event.Timestamp,
+		event.EventType,
+		string(event.Severity),
+		string(event.Outcome),
+		optionalUUID(event.TenantID),
+		optionalUUID(event.UserID),
+		nullIfEmpty(event.UserEmail),
+		nullIfEmpty(event.IPAddress),
+		nullIfEmpty(truncate(event.UserAgent, 500)),
+		nullIfEmpty(event.RequestID),
+		nullIfEmpty(event.Method),
+		nullIfEmpty(truncate(event.Path, 500)),
+		nullIfEmpty(event.Resource),
+		nullIfEmpty(event.Action),
+		nullIfEmpty(event.Message),
+		metadata,
+	).Scan(&event.Seq)
+}
+
+// List returns events in sequence order
+func (r *SecurityEventRepository) List(ctx context.Context, filter SecurityEventFilter) ([]*SecurityEvent, error) {
+	if filter.Limit <= 0 || filter.Limit > 1000 {
+		filter.Limit = 1000
+	}
+
+	query := `
+		SELECT seq, id, occurred_at, event_type, severity, outcome,
+			COALESCE(tenant_id::text, ''), COALESCE(user_id::text, ''), COALESCE(user_email, ''),
+			COALESCE(ip_address, ''), COALESCE(user_agent, ''), COALESCE(request_id, ''),
+			COALESCE(method, ''), COALESCE(path, ''), COALESCE(resource, ''), COALESCE(action, ''),
+			COALESCE(message, ''), metadata
+		FROM security_events
+		WHERE seq > $1
+		  AND ($2 = '' OR event_type = $2)
+		  AND severity = ANY($3)
+		ORDER BY seq
+		LIMIT $4
+	`
+
+	rows, err := r.pool.Query(ctx, query, filter.AfterSeq, filter.EventType, severitiesFrom(filter.MinSeverity), filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list security events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*SecurityEvent
+	for rows.Next() {
+		var e SecurityEvent
+		var severity, outcome string
+		var metadata []byte
+		if err := rows.Scan(&e.Seq, &e.ID, &e.Timestamp, &e.EventType, &severity, &outcome,
+			&e.TenantID, &e.UserID, &e.UserEmail, &e.IPAddress, &e.UserAgent, &e.RequestID,
+			&e.Method, &e.Path, &e.Resource, &e.Action, &e.Message, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to scan security event: %w", err)
+		}
+		e.Severity = Severity(severity)
+		e.Outcome = Outcome(outcome)
+		if len(metadata) > 0 {
+			_ = json.Unmarshal(metadata, &e.Metadata)
+		}
+		if len(e.Metadata) == 0 {
+			e.Metadata = nil
+		}
+		events = append(events, &e)
+	}
+	return events, rows.Err()
+}
+
+// DeleteBefore removes events that occurred before the cutoff
+func (r *SecurityEventRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM security_events WHERE occurred_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete security events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// severitiesFrom lists the severities at or above min
+func severitiesFrom(min Severity) []string {
+	var result []string
+	for _, s := range severityOrder {
+		if s.AtLeast(min) {
+			result = append(result, string(s))
+		}
+	}
+	return result
+}
+
+func optionalUUID(s string) *uuid.UUID {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
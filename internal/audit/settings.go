@@ -0,0 +1,241 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Bounds of the per-tenant audit retention
+const (
+	MinRetentionDays     = 30
+	MaxRetentionDays     = 3650
+	DefaultRetentionDays = 90
+)
+
+// settingsCacheTTL bounds how long a changed setting can take to reach
+// other API instances
+const settingsCacheTTL = time.Minute
+
+var (
+	ErrInvalidIPMode    = errors.New("ip_anonymization must be full, truncate or hash")
+	ErrInvalidRetention = fmt.Errorf("retention_days must be between %d and %d", MinRetentionDays, MaxRetentionDays)
+)
+
+// TenantSettings are the audit settings a tenant stored; nil fields fall
+// back to the platform defaults
+type TenantSettings struct {
+	TenantID        uuid.UUID
+	IPAnonymization *string
+	RetentionDays   *int
+	UpdatedBy       *uuid.UUID
+	UpdatedAt       time.Time
+}
+
+// GetSettings returns a tenant's audit settings, nil if it has none
+func (r *Repository) GetSettings(ctx context.Context, tenantID uuid.UUID) (*TenantSettings, error) {
+	s := &TenantSettings{TenantID: tenantID}
+	err := r.pool.QueryRow(ctx, `
+		SELECT ip_anonymization, retention_days, updated_by, updated_at
+		FROM tenant_audit_settings
+		WHERE tenant_id = $1
+	`, tenantID).Scan(&s.IPAnonymization, &s.RetentionDays, &s.UpdatedBy, &s.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// UpsertSettings stores a tenant's audit settings
+func (r *Repository) UpsertSettings(ctx context.Context, s *TenantSettings) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO tenant_audit_settings (tenant_id, ip_anonymization, retention_days, updated_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			ip_anonymization = EXCLUDED.ip_anonymization,
+			retention_days = EXCLUDED.retention_days,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, s.TenantID, s.IPAnonymization, s.RetentionDays, s.UpdatedBy).Scan(&s.UpdatedAt)
+}
+
+// Settings are the audit settings in effect for a tenant. They are returned
+// with audit log responses so that readers know how the data was reduced.
+type Settings struct {
+	IPAnonymization  string     `json:"ip_anonymization"`
+	IPDescription    string     `json:"ip_anonymization_description"`
+	HashSaltRotation string     `json:"hash_salt_rotation,omitempty"`
+	RetentionDays    int        `json:"retention_days"`
+	RetentionNote    string     `json:"retention_description"`
+	TenantDefined    bool       `json:"tenant_defined"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+}
+
+// UpdateSettingsRequest changes a tenant's audit settings. An empty mode or
+// a zero retention returns to the platform default.
+type UpdateSettingsRequest struct {
+	IPAnonymization *string `json:"ip_anonymization"`
+	RetentionDays   *int    `json:"retention_days"`
+}
+
+// PolicyConfig holds the platform defaults of the audit settings
+type PolicyConfig struct {
+	IPMode        string
+	RetentionDays int
+}
+
+// Policy applies the per-tenant audit settings: it anonymizes IP addresses
+// before they are stored and tells the archive job how long logs are kept
+type Policy struct {
+	repo       *Repository
+	anonymizer *Anonymizer
+	defaults   PolicyConfig
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]cachedSettings
+}
+
+type cachedSettings struct {
+	settings *Settings
+	expires  time.Time
+}
+
+// NewPolicy creates an audit policy with the given platform defaults
+func NewPolicy(repo *Repository, anonymizer *Anonymizer, defaults PolicyConfig) *Policy {
+	if !ValidIPMode(defaults.IPMode) {
+		defaults.IPMode = DefaultIPMode
+	}
+	if defaults.RetentionDays <= 0 {
+		defaults.RetentionDays = DefaultRetentionDays
+	}
+	return &Policy{
+		repo:       repo,
+		anonymizer: anonymizer,
+		defaults:   defaults,
+		cache:      make(map[uuid.UUID]cachedSettings),
+	}
+}
+
+// Settings returns the settings in effect for a tenant
+func (p *Policy) Settings(ctx context.Context, tenantID uuid.UUID) (*Settings, error) {
+	p.mu.Lock()
+	cached, ok := p.cache[tenantID]
+	p.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.settings, nil
+	}
+
+	stored, err := p.repo.GetSettings(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	settings := p.effective(stored)
+
+	p.mu.Lock()
+	p.cache[tenantID] = cachedSettings{settings: settings, expires: time.Now().Add(settingsCacheTTL)}
+	p.mu.Unlock()
+	return settings, nil
+}
+
+// Update validates and stores a tenant's audit settings
+func (p *Policy) Update(ctx context.Context, tenantID uuid.UUID, userID *uuid.UUID, req *UpdateSettingsRequest) (*Settings, error) {
+	stored, err := p.repo.GetSettings(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		stored = &TenantSettings{TenantID: tenantID}
+	}
+
+	if req.IPAnonymization != nil {
+		switch mode := *req.IPAnonymization; {
+		case mode == "":
+			stored.IPAnonymization = nil
+		case ValidIPMode(mode):
+			stored.IPAnonymization = &mode
+		default:
+			return nil, ErrInvalidIPMode
+		}
+	}
+	if req.RetentionDays != nil {
+		switch days := *req.RetentionDays; {
+		case days == 0:
+			stored.RetentionDays = nil
+		case days >= MinRetentionDays && days <= MaxRetentionDays:
+			stored.RetentionDays = &days
+		default:
+			return nil, ErrInvalidRetention
+		}
+	}
+	stored.UpdatedBy = userID
+
+	if err := p.repo.UpsertSettings(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	settings := p.effective(stored)
+	p.mu.Lock()
+	p.cache[tenantID] = cachedSettings{settings: settings, expires: time.Now().Add(settingsCacheTTL)}
+	p.mu.Unlock()
+	return settings, nil
+}
+
+// AnonymizeIP returns the form of ip to store for a tenant's audit log.
+// Logs without tenant, or whose settings cannot be loaded, use the platform
+// default.
+func (p *Policy) AnonymizeIP(ctx context.Context, tenantID *uuid.UUID, ip string) string {
+	mode := p.defaults.IPMode
+	if tenantID != nil {
+		if settings, err := p.Settings(ctx, *tenantID); err == nil {
+			mode = settings.IPAnonymization
+		}
+	}
+	return p.anonymizer.Anonymize(ip, mode)
+}
+
+// RetentionDays returns how long a tenant's audit logs stay in the database
+func (p *Policy) RetentionDays(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	settings, err := p.Settings(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return settings.RetentionDays, nil
+}
+
+func (p *Policy) effective(stored *TenantSettings) *Settings {
+	settings := &Settings{
+		IPAnonymization: p.defaults.IPMode,
+		RetentionDays:   p.defaults.RetentionDays,
+	}
+	if stored != nil {
+		settings.TenantDefined = stored.IPAnonymization != nil || stored.RetentionDays != nil
+		settings.UpdatedAt = &stored.UpdatedAt
+		if stored.IPAnonymization != nil {
+			settings.IPAnonymization = *stored.IPAnonymization
+		}
+		if stored.RetentionDays != nil {
+			settings.RetentionDays = *stored.RetentionDays
+		}
+	}
+
+	switch settings.IPAnonymization {
+	case IPModeFull:
+		settings.IPDescription = "IP addresses are not stored"
+	case IPModeHash:
+		settings.IPDescription = "IP addresses are stored as keyed SHA-256 hashes; the salt rotates, so entries can only be correlated within one period"
+		settings.HashSaltRotation = p.anonymizer.SaltRotation().String()
+	default:
+		settings.IPDescription = "IP addresses are truncated to their network: /24 for IPv4, /48 for IPv6"
+	}
+	settings.RetentionNote = fmt.Sprintf("Entries older than %d days are moved to the audit archive and deleted by the daily audit_archive job", settings.RetentionDays)
+	return settings
+}
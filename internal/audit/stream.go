@@ -0,0 +1,443 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/pkg/cache"
+)
+
+// Security stream event types, in addition to the shared types in events.go
+const (
+	EventAuthFailed             = "security.auth_failed"
+	EventPermissionDenied       = "security.permission_denied"
+	EventCSRFRejected           = "security.csrf_rejected"
+	EventChallengeFailed        = "security.challenge_failed"
+	EventAlertThresholdExceeded = "security.alert_threshold_exceeded"
+)
+
+// Webhook headers of security event deliveries
+const (
+	SecurityWebhookSignatureHeader = "X-Security-Event-Signature"
+	SecurityWebhookTimestampHeader = "X-Security-Event-Timestamp"
+)
+
+const maxWebhookBatch = 100
+
+// AlertThreshold raises an alert when Count events of EventType arrive from
+// one client IP within Window
+type AlertThreshold struct {
+	EventType string
+	Count     int
+	Window    time.Duration
+	Severity  Severity
+}
+
+// ParseAlertThresholds parses a comma-separated threshold list such as
+// "security.auth_failed=20/5m,security.cross_tenant_attempt=5/10m:critical".
+// Alerts are high severity unless a severity is given.
+func ParseAlertThresholds(s string) ([]AlertThreshold, error) {
+	var thresholds []AlertThreshold
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		eventType, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid alert threshold %q", entry)
+		}
+		t := AlertThreshold{EventType: strings.TrimSpace(eventType), Severity: SeverityHigh}
+		if rate, severity, ok := strings.Cut(spec, ":"); ok {
+			if t.Severity, ok = ParseSeverity(severity); !ok {
+				return nil, fmt.Errorf("invalid severity in alert threshold %q", entry)
+			}
+			spec = rate
+		}
+		count, window, ok := strings.Cut(spec, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid alert threshold %q", entry)
+		}
+		var err error
+		if t.Count, err = strconv.Atoi(count); err != nil || t.Count <= 0 {
+			return nil, fmt.Errorf("invalid count in alert threshold %q", entry)
+		}
+		if t.Window, err = time.ParseDuration(window); err != nil || t.Window < time.Second {
+			return nil, fmt.Errorf("invalid window in alert threshold %q", entry)
+		}
+		thresholds = append(thresholds, t)
+	}
+	return thresholds, nil
+}
+
+// SecurityStreamConfig configures the security event stream
+type SecurityStreamConfig struct {
+	// WebhookURL receives events as NDJSON batches; empty disables the push
+	WebhookURL         string
+	WebhookSecret      string
+	WebhookMinSeverity Severity
+	Thresholds         []AlertThreshold
+	// Retention is how long stored events are kept; 0 keeps them
+	Retention  time.Duration
+	BufferSize int
+}
+
+// SecurityStream is the security event pipeline, kept apart from the
+// business audit log: events are logged, stored for pull-based SIEM export,
+// pushed to a SIEM webhook and counted against alert thresholds. Events are
+// processed in the background so a flood of failed requests never waits on
+// the database or the SIEM.
+type SecurityStream struct {
+	repo       *SecurityEventRepository
+	redis      *cache.Client
+	config     SecurityStreamConfig
+	auditor    *SecurityAuditor
+	logger     *slog.Logger
+	httpClient *http.Client
+
+	queue   chan *SecurityEvent
+	outbox  chan *SecurityEvent
+	wg      sync.WaitGroup
+	dropped atomic.Int64
+}
+
+// NewSecurityStream creates a security event stream and starts its workers.
+// Without a repository events are not stored; without Redis thresholds are
+// not evaluated.
+func NewSecurityStream(repo *SecurityEventRepository, redis *cache.Client, config SecurityStreamConfig, logger *slog.Logger) *SecurityStream {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1000
+	}
+
+	s := &SecurityStream{
+		repo:       repo,
+		redis:      redis,
+		config:     config,
+		logger:     logger.With("component", "security_stream"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		queue:      make(chan *SecurityEvent, config.BufferSize),
+	}
+	s.auditor = NewSecurityAuditor(logger, s)
+
+	if config.WebhookURL != "" {
+		s.outbox = make(chan *SecurityEvent, config.BufferSize)
+		s.wg.Add(1)
+		go s.deliverLoop()
+	}
+	s.wg.Add(1)
+	go s.processLoop()
+	return s
+}
+
+// Auditor returns the security auditor writing to this stream
+func (s *SecurityStream) Auditor() *SecurityAuditor {
+	return s.auditor
+}
+
+// Dropped returns the number of events dropped because the queue was full
+func (s *SecurityStream) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close stops accepting events, drains the queue and flushes the webhook
+func (s *SecurityStream) Close() {
+	close(s.queue)
+	s.wg.Wait()
+}
+
+// Store queues an event for processing. It implements SecurityEventStore.
+func (s *SecurityStream) Store(ctx context.Context, event *SecurityEvent) error {
+	select {
+	case s.queue <- event:
+		return nil
+	default:
+		s.dropped.Add(1)
+		return fmt.Errorf("security event queue full")
+	}
+}
+
+// ReportSecurity converts a request signal into a security event. It
+// implements api.SecurityReporter.
+func (s *SecurityStream) ReportSecurity(r *http.Request, clientIP string, signal api.SecuritySignal) {
+	ctx := r.Context()
+	event := &SecurityEvent{
+		TenantID:  api.GetTenantID(ctx),
+		UserID:    api.GetUserID(ctx),
+		UserEmail: signal.Subject,
+		IPAddress: clientIP,
+		UserAgent: r.UserAgent(),
+		RequestID: api.GetRequestID(ctx),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Resource:  signal.Resource,
+		Outcome:   OutcomeBlocked,
+	}
+	if signal.Reason != "" {
+		event.Metadata = map[string]string{"reason": signal.Reason}
+	}
+	if signal.ResourceID != "" {
+		if event.Metadata == nil {
+			event.Metadata = make(map[string]string)
+		}
+		event.Metadata["resource_id"] = signal.ResourceID
+	}
+
+	switch signal.Kind {
+	case api.SignalAuthFailed:
+		event.EventType, event.Severity, event.Outcome = EventAuthFailed, SeverityMedium, OutcomeFailure
+		event.Message = "Authentication failed"
+	case api.SignalPermissionDenied:
+		event.EventType, event.Severity = EventPermissionDenied, SeverityMedium
+		event.Message = "Permission denied"
+	case api.SignalTenantMismatch:
+		event.EventType, event.Severity = EventCrossTenantAttempt, SeverityHigh
+		event.Message = "Access to a resource of another tenant"
+	case api.SignalRateLimited:
+		event.EventType, event.Severity = EventRateLimited, SeverityLow
+		event.Message = "Rate limit exceeded"
+	case api.SignalChallengeFailed:
+		event.EventType, event.Severity = EventChallengeFailed, SeverityLow
+		event.Message = "Challenge verification failed"
+	case api.SignalCSRFRejected:
+		event.EventType, event.Severity = EventCSRFRejected, SeverityMedium
+		event.Message = "Cross-site request rejected"
+	default:
+		event.EventType, event.Severity = "security."+signal.Kind, SeverityMedium
+	}
+
+	s.auditor.Log(ctx, event)
+}
+
+func (s *SecurityStream) processLoop() {
+	defer s.wg.Done()
+	if s.outbox != nil {
+		defer close(s.outbox)
+	}
+
+	prune := time.NewTicker(time.Hour)
+	defer prune.Stop()
+
+	for {
+		select {
+		case event, ok := <-s.queue:
+			if !ok {
+				return
+			}
+			s.process(event)
+		case <-prune.C:
+			s.prune()
+		}
+	}
+}
+
+func (s *SecurityStream) process(event *SecurityEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if s.repo != nil {
+		if err := s.repo.Insert(ctx, event); err != nil {
+			s.logger.Error("failed to store security event", "event_id", event.ID, "error", err)
+		}
+	}
+
+	if s.outbox != nil && event.Severity.AtLeast(s.config.WebhookMinSeverity) {
+		select {
+		case s.outbox <- event:
+		default:
+			s.dropped.Add(1)
+			s.logger.Warn("security webhook queue full, event not pushed", "event_id", event.ID)
+		}
+	}
+
+	// Alerts are not counted themselves
+	if event.EventType != EventAlertThresholdExceeded {
+		s.checkThresholds(ctx, event)
+	}
+}
+
+// checkThresholds counts the event per client IP in fixed windows and raises
+// one alert per window when a threshold is reached
+func (s *SecurityStream) checkThresholds(ctx context.Context, event *SecurityEvent) {
+	if s.redis == nil || event.IPAddress == "" {
+		return
+	}
+	for _, t := range s.config.Thresholds {
+		if t.EventType != event.EventType {
+			continue
+		}
+		bucket := event.Timestamp.Unix() / int64(t.Window.Seconds())
+		key := "security:threshold:" + t.EventType + ":" + event.IPAddress + ":" + strconv.FormatInt(bucket, 10)
+		count, err := s.redis.IncrementRateLimit(ctx, key, t.Window)
+		if err != nil {
+			s.logger.Error("failed to count security event", "event_type", t.EventType, "error", err)
+			continue
+		}
+		if count != int64(t.Count) {
+			continue
+		}
+
+		alert := &SecurityEvent{
+			ID:        uuid.New().String(),
+			Timestamp: time.Now().UTC(),
+			EventType: EventAlertThresholdExceeded,
+			Severity:  t.Severity,
+			Outcome:   event.Outcome,
+			TenantID:  event.TenantID,
+			UserID:    event.UserID,
+			IPAddress: event.IPAddress,
+			UserAgent: event.UserAgent,
+			RequestID: event.RequestID,
+			Message:   fmt.Sprintf("%d %s events from %s within %s", t.Count, t.EventType, event.IPAddress, t.Window),
+			Metadata: map[string]string{
+				"trigger_event_type": t.EventType,
+				"count":              strconv.Itoa(t.Count),
+				"window":             t.Window.String(),
+			},
+		}
+		// Processed here rather than queued: the queue may be full exactly
+		// when an alert matters
+		s.auditor.write(ctx, alert)
+		s.process(alert)
+	}
+}
+
+func (s *SecurityStream) prune() {
+	if s.repo == nil || s.config.Retention <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	deleted, err := s.repo.DeleteBefore(ctx, time.Now().Add(-s.config.Retention))
+	if err != nil {
+		s.logger.Error("failed to prune security events", "error", err)
+		return
+	}
+	if deleted > 0 {
+		s.logger.Info("pruned security events", "deleted", deleted)
+	}
+}
+
+func (s *SecurityStream) deliverLoop() {
+	defer s.wg.Done()
+
+	flush := time.NewTicker(2 * time.Second)
+	defer flush.Stop()
+
+	var batch []*SecurityEvent
+	for {
+		select {
+		case event, ok := <-s.outbox:
+			if !ok {
+				s.deliver(batch)
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= maxWebhookBatch {
+				s.deliver(batch)
+				batch = nil
+			}
+		case <-flush.C:
+			s.deliver(batch)
+			batch = nil
+		}
+	}
+}
+
+// deliver posts a batch as NDJSON, retrying with backoff. Events that cannot
+// be delivered remain available from the export endpoint.
+func (s *SecurityStream) deliver(batch []*SecurityEvent) {
+	if len(batch) == 0 {
+		return
+	}
+	body, err := EncodeSecurityEvents(batch)
+	if err != nil {
+		s.logger.Error("failed to encode security events", "error", err)
+		return
+	}
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		err = s.post(body)
+		if err == nil {
+			return
+		}
+		if attempt == 3 {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	s.logger.Error("failed to push security events to webhook",
+		"events", len(batch),
+		"first_seq", batch[0].Seq,
+		"error", err,
+	)
+}
+
+func (s *SecurityStream) post(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("User-Agent", "Austrian-Business-Platform-Security/1.0")
+	req.Header.Set(SecurityWebhookTimestampHeader, timestamp)
+	if s.config.WebhookSecret != "" {
+		req.Header.Set(SecurityWebhookSignatureHeader, SignSecurityWebhook(s.config.WebhookSecret, timestamp, body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignSecurityWebhook computes the signature of a webhook delivery:
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)). Receivers
+// should also reject stale timestamps.
+func SignSecurityWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// EncodeSecurityEvents encodes events as newline-delimited JSON, the format
+// of both the webhook and the export endpoint
+func EncodeSecurityEvents(events []*SecurityEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -6,14 +6,19 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"austrian-business-infrastructure/internal/api"
 	"austrian-business-infrastructure/internal/audit"
 	"austrian-business-infrastructure/internal/crypto"
+	"austrian-business-infrastructure/internal/email"
 	"austrian-business-infrastructure/internal/tenant"
+	"austrian-business-infrastructure/internal/timezone"
 	"austrian-business-infrastructure/internal/user"
 	"austrian-business-infrastructure/pkg/cache"
 	"github.com/google/uuid"
@@ -28,6 +33,12 @@ type Handler struct {
 	rateLimiter    *RateLimiter
 	auditLogger    *audit.Logger
 	redis          *cache.Client
+	redisState     cache.Availability
+	passwordResets *PasswordResetStore
+	emailService   email.Service
+	appURL         string // base URL of reset links
+	abuseGuard     *api.AbuseGuard
+	policies       *SecurityPolicyService
 	logger         *slog.Logger
 	cookieConfig   *CookieConfig
 	trustedProxies map[string]bool // Trusted proxy IPs/CIDRs for X-Forwarded-For
@@ -62,22 +73,78 @@ func NewHandlerWithSecurity(
 	redis *cache.Client,
 	logger *slog.Logger,
 ) *Handler {
-	return &Handler{
+	h := &Handler{
 		tenantService:  tenantService,
 		userService:    userService,
 		sessionManager: sessionManager,
 		jwtManager:     jwtManager,
 		rateLimiter:    rateLimiter,
 		auditLogger:    auditLogger,
-		redis:          redis,
 		logger:         logger,
 		cookieConfig:   DefaultCookieConfig(),
 	}
+	h.SetRedis(redis)
+	return h
+}
+
+// SetRedis sets the Redis client holding 2FA challenges and password reset
+// tokens
+func (h *Handler) SetRedis(redis *cache.Client) {
+	h.redis = redis
+	h.passwordResets = nil
+	if redis != nil {
+		h.passwordResets = NewPasswordResetStore(redis.Client)
+	}
+}
+
+// SetRedisAvailability enables degraded mode: while a reports Redis as
+// down, 2FA logins and 2FA setup are answered with 503 at once
+func (h *Handler) SetRedisAvailability(a cache.Availability) {
+	h.redisState = a
+}
+
+// SetRateLimiter enables the login rate limit (FR-106). It is fail-closed,
+// so logins are rejected while Redis is unavailable.
+func (h *Handler) SetRateLimiter(rl *RateLimiter) {
+	h.rateLimiter = rl
+}
+
+// redisDown reports whether Redis is known to be unavailable
+func (h *Handler) redisDown() bool {
+	return h.redisState != nil && !h.redisState.Available()
+}
+
+// SetEmailService sets the service password reset links are sent with.
+// appURL is the base URL of the reset page.
+func (h *Handler) SetEmailService(emailService email.Service, appURL string) {
+	h.emailService = emailService
+	h.appURL = strings.TrimSuffix(appURL, "/")
+}
+
+// SetAbuseGuard protects registration and password reset against automated
+// use. It must be set before RegisterRoutes.
+func (h *Handler) SetAbuseGuard(guard *api.AbuseGuard) {
+	h.abuseGuard = guard
+}
+
+// SetSecurityPolicies enforces the security policies of tenants on login,
+// token refresh and password changes. Without it the platform defaults
+// apply.
+func (h *Handler) SetSecurityPolicies(policies *SecurityPolicyService) {
+	h.policies = policies
+}
+
+// guarded applies the abuse guard rule of an endpoint, if a guard is set
+func (h *Handler) guarded(endpoint string, fn http.HandlerFunc) http.Handler {
+	if h.abuseGuard == nil {
+		return fn
+	}
+	return h.abuseGuard.Protect(endpoint)(fn)
 }
 
 // RegisterRoutes registers auth routes
 func (h *Handler) RegisterRoutes(router *api.Router, requireAuth func(http.Handler) http.Handler) {
-	router.HandleFunc("POST /api/v1/auth/register", h.Register)
+	router.Handle("POST /api/v1/auth/register", h.guarded("register", h.Register))
 	router.HandleFunc("POST /api/v1/auth/login", h.Login)
 	router.HandleFunc("POST /api/v1/auth/login/2fa", h.Login2FA)
 	router.HandleFunc("POST /api/v1/auth/refresh", h.Refresh)
@@ -85,8 +152,8 @@ func (h *Handler) RegisterRoutes(router *api.Router, requireAuth func(http.Handl
 	router.Handle("GET /api/v1/auth/me", requireAuth(http.HandlerFunc(h.Me)))
 
 	// Password reset endpoints (public)
-	router.HandleFunc("POST /api/v1/auth/forgot-password", h.ForgotPassword)
-	router.HandleFunc("POST /api/v1/auth/reset-password", h.ResetPassword)
+	router.Handle("POST /api/v1/auth/forgot-password", h.guarded("forgot_password", h.ForgotPassword))
+	router.Handle("POST /api/v1/auth/reset-password", h.guarded("reset_password", h.ResetPassword))
 
 	// Profile and password change endpoints (authenticated)
 	router.Handle("PATCH /api/v1/auth/profile", requireAuth(http.HandlerFunc(h.UpdateProfile)))
@@ -216,7 +283,7 @@ func (h *Handler) handleRegistrationError(w http.ResponseWriter, err error) {
 		})
 	case errors.Is(err, ErrPasswordTooShort):
 		api.ValidationError(w, map[string]string{
-			"password": "Password must be at least 12 characters",
+			"password": passwordTooShortMessage(err),
 		})
 	case errors.Is(err, ErrPasswordNoUppercase):
 		api.ValidationError(w, map[string]string{
@@ -254,6 +321,9 @@ type LoginResponse struct {
 	AccessToken string   `json:"access_token"`
 	TokenType   string   `json:"token_type"`
 	ExpiresIn   int      `json:"expires_in"`
+	// TwoFactorSetupRequired is set when the tenant requires 2FA and the
+	// user has not set it up; the access token is then only good for that
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required,omitempty"`
 }
 
 // Login2FARequiredResponse is returned when 2FA verification is needed
@@ -279,6 +349,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	if h.rateLimiter != nil {
 		if err := h.rateLimiter.CheckLogin(ctx, clientIP); err != nil {
 			if errors.Is(err, ErrRateLimited) {
+				api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalRateLimited, Reason: "login"})
 				w.Header().Set("Retry-After", "60")
 				api.JSONError(w, http.StatusTooManyRequests, "Too many login attempts", "RATE_LIMITED")
 				return
@@ -312,8 +383,10 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		})
 		switch {
 		case errors.Is(err, ErrPasswordInvalid), errors.Is(err, user.ErrUserNotFound):
+			api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalAuthFailed, Reason: "invalid_credentials", Subject: req.Email})
 			api.JSONError(w, http.StatusUnauthorized, "Invalid email or password", api.ErrCodeInvalidCredentials)
 		case errors.Is(err, user.ErrUserInactive):
+			api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalAuthFailed, Reason: "inactive_account", Subject: req.Email})
 			api.JSONError(w, http.StatusUnauthorized, "Account is inactive", api.ErrCodeUnauthorized)
 		default:
 			h.logger.Error("login failed", "error", err)
@@ -325,6 +398,11 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	// Check if 2FA is enabled - return challenge token instead of tokens
 	if u.TOTPEnabled {
 		challengeToken, err := h.create2FAChallenge(ctx, u)
+		if errors.Is(err, cache.ErrUnavailable) {
+			h.logger.Warn("2FA login unavailable in degraded mode", "user_id", u.ID)
+			api.JSONError(w, http.StatusServiceUnavailable, "Two-factor login is temporarily unavailable", "SERVICE_UNAVAILABLE")
+			return
+		}
 		if err != nil {
 			h.logger.Error("failed to create 2FA challenge", "error", err)
 			api.InternalError(w)
@@ -363,10 +441,15 @@ func (h *Handler) Login2FA(w http.ResponseWriter, r *http.Request) {
 
 	// Validate challenge token and get user
 	u, err := h.validate2FAChallenge(ctx, req.ChallengeToken)
+	if errors.Is(err, cache.ErrUnavailable) {
+		api.JSONError(w, http.StatusServiceUnavailable, "Two-factor login is temporarily unavailable", "SERVICE_UNAVAILABLE")
+		return
+	}
 	if err != nil {
 		h.logAuthEvent(ctx, audit.EventLoginFailed, nil, nil, clientIP, r.UserAgent(), map[string]any{
 			"reason": "invalid_2fa_challenge",
 		})
+		api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalAuthFailed, Reason: "invalid_2fa_challenge"})
 		api.JSONError(w, http.StatusUnauthorized, "Invalid or expired challenge", api.ErrCodeInvalidToken)
 		return
 	}
@@ -377,6 +460,7 @@ func (h *Handler) Login2FA(w http.ResponseWriter, r *http.Request) {
 		h.logAuthEvent(ctx, audit.EventLoginFailed, &u.ID, &u.TenantID, clientIP, r.UserAgent(), map[string]any{
 			"reason": "invalid_totp_code",
 		})
+		api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalAuthFailed, Reason: "invalid_totp_code", Subject: u.Email})
 		api.JSONError(w, http.StatusUnauthorized, "Invalid TOTP code", api.ErrCodeInvalidCredentials)
 		return
 	}
@@ -392,11 +476,29 @@ func (h *Handler) Login2FA(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) completeLogin(w http.ResponseWriter, r *http.Request, u *user.User, clientIP string) {
 	ctx := r.Context()
 
+	// Enforce the tenant's security policy
+	policy, err := h.securityPolicy(ctx, u.TenantID)
+	if err != nil {
+		h.logger.Error("failed to load security policy", "error", err, "tenant_id", u.TenantID)
+		api.InternalError(w)
+		return
+	}
+	if !policy.AllowsIP(clientIP) {
+		h.logAuthEvent(ctx, audit.EventLoginFailed, &u.ID, &u.TenantID, clientIP, r.UserAgent(), map[string]any{
+			"reason": "ip_not_allowed",
+		})
+		api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalAuthFailed, Reason: "ip_not_allowed", Subject: u.Email})
+		api.JSONError(w, http.StatusForbidden, "Your organization does not allow sign-in from this network", api.ErrCodeIPNotAllowed)
+		return
+	}
+	enroll2FA := policy.Require2FA && !u.TOTPEnabled
+
 	// Generate tokens (Email intentionally excluded from JWT per FR-104)
 	tokens, err := h.jwtManager.GenerateTokenPair(&UserInfo{
-		UserID:   u.ID.String(),
-		TenantID: u.TenantID.String(),
-		Role:     string(u.Role),
+		UserID:    u.ID.String(),
+		TenantID:  u.TenantID.String(),
+		Role:      string(u.Role),
+		Enroll2FA: enroll2FA,
 	})
 
 	if err != nil {
@@ -438,6 +540,7 @@ func (h *Handler) completeLogin(w http.ResponseWriter, r *http.Request, u *user.
 		TokenType:   "Bearer",
 		ExpiresIn:   900,
 		// NOTE: refresh_token intentionally NOT in response body - it's in httpOnly cookie
+		TwoFactorSetupRequired: enroll2FA,
 	})
 }
 
@@ -447,6 +550,8 @@ type RefreshResponse struct {
 	AccessToken string `json:"access_token"`
 	TokenType   string `json:"token_type"`
 	ExpiresIn   int    `json:"expires_in"`
+	// TwoFactorSetupRequired is set like on login
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required,omitempty"`
 }
 
 // Refresh handles POST /api/v1/auth/refresh
@@ -490,11 +595,51 @@ func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Enforce the tenant's security policy: the session must be young
+	// enough, the client in an allowed network, and users that have not
+	// set up required 2FA only get a token to do so
+	tenantID, err := uuid.Parse(claims.TenantID)
+	if err != nil {
+		ClearRefreshTokenCookie(w, h.cookieConfig)
+		api.JSONError(w, http.StatusUnauthorized, "Invalid refresh token", api.ErrCodeInvalidToken)
+		return
+	}
+	policy, err := h.securityPolicy(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("failed to load security policy", "error", err, "tenant_id", tenantID)
+		api.InternalError(w)
+		return
+	}
+	if policy.SessionExpired(session.CreatedAt, time.Now()) {
+		if err := h.sessionManager.DeleteByRefreshToken(r.Context(), refreshToken); err != nil {
+			h.logger.Error("failed to delete expired session", "error", err)
+		}
+		ClearRefreshTokenCookie(w, h.cookieConfig)
+		api.JSONError(w, http.StatusUnauthorized, "Session has expired", api.ErrCodeTokenExpired)
+		return
+	}
+	if clientIP := h.getClientIP(r); !policy.AllowsIP(clientIP) {
+		api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalAuthFailed, Reason: "ip_not_allowed"})
+		api.JSONError(w, http.StatusForbidden, "Your organization does not allow access from this network", api.ErrCodeIPNotAllowed)
+		return
+	}
+	enroll2FA := false
+	if policy.Require2FA {
+		u, err := h.userService.GetByID(r.Context(), session.UserID)
+		if err != nil {
+			h.logger.Error("failed to get user", "error", err)
+			api.InternalError(w)
+			return
+		}
+		enroll2FA = !u.TOTPEnabled
+	}
+
 	// Generate new token pair (Email intentionally excluded from JWT per FR-104)
 	tokens, err := h.jwtManager.GenerateTokenPair(&UserInfo{
-		UserID:   claims.UserID,
-		TenantID: claims.TenantID,
-		Role:     claims.Role,
+		UserID:    claims.UserID,
+		TenantID:  claims.TenantID,
+		Role:      claims.Role,
+		Enroll2FA: enroll2FA,
 	})
 
 	if err != nil {
@@ -514,9 +659,10 @@ func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
 	SetRefreshTokenCookie(w, tokens.RefreshToken, refreshExpiry, h.cookieConfig)
 
 	api.JSONResponse(w, http.StatusOK, RefreshResponse{
-		AccessToken: tokens.AccessToken,
-		TokenType:   "Bearer",
-		ExpiresIn:   900,
+		AccessToken:            tokens.AccessToken,
+		TokenType:              "Bearer",
+		ExpiresIn:              900,
+		TwoFactorSetupRequired: enroll2FA,
 	})
 }
 
@@ -549,6 +695,8 @@ type MeResponse struct {
 	Role       string `json:"role"`
 	TenantID   string `json:"tenantId"`
 	TenantName string `json:"tenantName"`
+	// TenantTimezone is the IANA zone the tenant's calendar days are in
+	TenantTimezone string `json:"tenantTimezone"`
 }
 
 // Me handles GET /api/v1/auth/me - returns current authenticated user
@@ -578,23 +726,33 @@ func (h *Handler) Me(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get tenant name
-	tenantName := ""
+	api.JSONResponse(w, http.StatusOK, h.meResponse(r.Context(), u))
+}
+
+// meResponse describes the user together with their tenant's name and
+// time zone
+func (h *Handler) meResponse(ctx context.Context, u *user.User) MeResponse {
+	resp := MeResponse{
+		ID:             u.ID.String(),
+		Email:          u.Email,
+		Name:           u.Name,
+		Role:           string(u.Role),
+		TenantID:       u.TenantID.String(),
+		TenantTimezone: timezone.Default,
+	}
 	if h.tenantService != nil {
-		t, err := h.tenantService.GetByID(r.Context(), u.TenantID)
+		t, err := h.tenantService.GetByID(ctx, u.TenantID)
 		if err == nil && t != nil {
-			tenantName = t.Name
+			resp.TenantName = t.Name
+			resp.TenantTimezone = t.Location().String()
 		}
 	}
+	return resp
+}
 
-	api.JSONResponse(w, http.StatusOK, MeResponse{
-		ID:         u.ID.String(),
-		Email:      u.Email,
-		Name:       u.Name,
-		Role:       string(u.Role),
-		TenantID:   u.TenantID.String(),
-		TenantName: tenantName,
-	})
+// ClientIP returns the client IP of a request the way logins take it
+func (h *Handler) ClientIP(r *http.Request) string {
+	return h.getClientIP(r)
 }
 
 // getClientIP extracts client IP from request with trusted proxy validation
@@ -708,6 +866,9 @@ func (h *Handler) create2FAChallenge(ctx context.Context, u *user.User) (string,
 	if h.redis == nil {
 		return "", errors.New("redis not configured for 2FA challenges")
 	}
+	if h.redisDown() {
+		return "", cache.ErrUnavailable
+	}
 
 	// Generate random challenge token
 	tokenBytes := make([]byte, 32)
@@ -739,6 +900,9 @@ func (h *Handler) validate2FAChallenge(ctx context.Context, token string) (*user
 	if h.redis == nil {
 		return nil, errors.New("redis not configured for 2FA challenges")
 	}
+	if h.redisDown() {
+		return nil, cache.ErrUnavailable
+	}
 
 	key := challenge2FAPrefix + token
 	dataJSON, err := h.redis.Get(ctx, key).Result()
@@ -801,10 +965,8 @@ func (h *Handler) verifyTOTP(ctx context.Context, u *user.User, code string) boo
 
 // ============== Password Reset Endpoints ==============
 
-const (
-	passwordResetPrefix = "password_reset:"
-	passwordResetTTL    = 1 * time.Hour
-)
+// passwordResetSendTimeout bounds the background delivery of a reset email
+const passwordResetSendTimeout = 30 * time.Second
 
 // ForgotPasswordRequest represents a forgot password request
 type ForgotPasswordRequest struct {
@@ -812,8 +974,9 @@ type ForgotPasswordRequest struct {
 }
 
 // ForgotPassword handles POST /api/v1/auth/forgot-password
-// Generates a password reset token and stores it in Redis
-// SECURITY: Always returns 200 OK to prevent user enumeration
+// Issues a password reset token and emails the reset link
+// SECURITY: Always returns 200 OK to prevent user enumeration; the email is
+// sent in the background so the response time does not reveal accounts either
 func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	clientIP := h.getClientIP(r)
@@ -852,23 +1015,22 @@ func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate reset token
-	tokenBytes := make([]byte, 32)
-	if _, err := rand.Read(tokenBytes); err != nil {
-		h.logger.Error("failed to generate reset token", "error", err)
+	if h.passwordResets == nil || h.emailService == nil {
+		h.logger.Error("password reset not configured", "redis", h.passwordResets != nil, "email", h.emailService != nil)
 		return
 	}
-	token := base64.RawURLEncoding.EncodeToString(tokenBytes)
 
-	// Store token in Redis with user ID
-	if h.redis != nil {
-		key := passwordResetPrefix + token
-		if err := h.redis.Set(ctx, key, u.ID.String(), passwordResetTTL).Err(); err != nil {
-			h.logger.Error("failed to store reset token", "error", err)
-			return
-		}
-	} else {
-		h.logger.Error("redis not configured for password reset")
+	// Only a hash of the token is stored; the previous link stops working
+	token, err := h.passwordResets.Issue(ctx, u.ID)
+	if errors.Is(err, ErrResetThrottled) {
+		h.logger.Warn("password reset throttled", "user_id", u.ID, "ip", clientIP)
+		h.logAuthEvent(ctx, audit.EventPasswordReset, &u.ID, &u.TenantID, clientIP, r.UserAgent(), map[string]any{
+			"action": "throttled",
+		})
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to issue reset token", "error", err)
 		return
 	}
 
@@ -877,12 +1039,25 @@ func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
 		"action": "requested",
 	})
 
-	// TODO: Send email with reset link containing token
-	// For now, log the token for development purposes
-	h.logger.Info("password reset token generated",
-		"user_id", u.ID,
-		"token", token, // Remove this in production!
-	)
+	params := email.PasswordResetParams{
+		Name:         u.Name,
+		ResetURL:     h.appURL + "/auth/reset-password?token=" + url.QueryEscape(token),
+		ValidMinutes: int(PasswordResetTTL / time.Minute),
+		RequestedAt:  formatViennaTime(time.Now()),
+		IPAddress:    clientIP,
+	}
+	go func(to string, userID uuid.UUID) {
+		sendCtx, cancel := context.WithTimeout(context.Background(), passwordResetSendTimeout)
+		defer cancel()
+		if err := h.emailService.SendPasswordReset(sendCtx, to, params); err != nil {
+			h.logger.Error("failed to send password reset email", "user_id", userID, "error", err)
+		}
+	}(u.Email, u.ID)
+}
+
+// formatViennaTime formats a time for German emails in Austrian local time
+func formatViennaTime(t time.Time) string {
+	return t.In(timezone.Vienna).Format("02.01.2006 um 15:04 Uhr")
 }
 
 // ResetPasswordRequest represents a password reset request
@@ -916,25 +1091,39 @@ func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get user ID from Redis
-	if h.redis == nil {
+	if h.passwordResets == nil {
 		h.logger.Error("redis not configured for password reset")
 		api.InternalError(w)
 		return
 	}
 
-	key := passwordResetPrefix + req.Token
-	userIDStr, err := h.redis.Get(ctx, key).Result()
-	if err != nil {
+	// The password must also meet the user's tenant policy, which is
+	// checked before the token is redeemed so that it can be retried
+	if userID, err := h.passwordResets.Lookup(ctx, req.Token); err == nil {
+		if u, err := h.userService.GetByID(ctx, userID); err == nil {
+			policy, err := h.passwordPolicy(ctx, u.TenantID)
+			if err != nil {
+				h.logger.Error("failed to load password policy", "error", err)
+				api.InternalError(w)
+				return
+			}
+			if err := ValidatePassword(req.Password, policy); err != nil {
+				h.handlePasswordValidationError(w, err)
+				return
+			}
+		}
+	}
+
+	// Redeem the token (one-time use)
+	userID, err := h.passwordResets.Consume(ctx, req.Token)
+	if errors.Is(err, ErrResetTokenInvalid) {
 		h.logger.Debug("invalid or expired reset token", "ip", clientIP)
 		api.JSONError(w, http.StatusBadRequest, "Invalid or expired reset token", api.ErrCodeInvalidToken)
 		return
 	}
-
-	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		h.logger.Error("invalid user ID in reset token", "error", err)
-		api.JSONError(w, http.StatusBadRequest, "Invalid reset token", api.ErrCodeInvalidToken)
+		h.logger.Error("failed to redeem reset token", "error", err)
+		api.InternalError(w)
 		return
 	}
 
@@ -961,9 +1150,6 @@ func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Delete the reset token (one-time use)
-	h.redis.Del(ctx, key)
-
 	// Invalidate all user sessions for security
 	if err := h.sessionManager.DeleteAllUserSessions(ctx, userID); err != nil {
 		h.logger.Error("failed to invalidate sessions after password reset", "error", err)
@@ -980,12 +1166,21 @@ func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// passwordTooShortMessage describes the minimum length a password missed
+func passwordTooShortMessage(err error) string {
+	var short *PasswordTooShortError
+	if errors.As(err, &short) {
+		return fmt.Sprintf("Password must be at least %d characters", short.MinLength)
+	}
+	return "Password must be at least 12 characters"
+}
+
 // handlePasswordValidationError handles password validation errors
 func (h *Handler) handlePasswordValidationError(w http.ResponseWriter, err error) {
 	switch {
 	case errors.Is(err, ErrPasswordTooShort):
 		api.ValidationError(w, map[string]string{
-			"password": "Password must be at least 12 characters",
+			"password": passwordTooShortMessage(err),
 		})
 	case errors.Is(err, ErrPasswordNoUppercase):
 		api.ValidationError(w, map[string]string{
@@ -1114,23 +1309,7 @@ func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	// Log profile update
 	h.logAuthEvent(ctx, "auth.profile_updated", &u.ID, &u.TenantID, clientIP, r.UserAgent(), changes)
 
-	// Get tenant name for response
-	tenantName := ""
-	if h.tenantService != nil {
-		t, err := h.tenantService.GetByID(ctx, u.TenantID)
-		if err == nil && t != nil {
-			tenantName = t.Name
-		}
-	}
-
-	api.JSONResponse(w, http.StatusOK, MeResponse{
-		ID:         u.ID.String(),
-		Email:      u.Email,
-		Name:       u.Name,
-		Role:       string(u.Role),
-		TenantID:   u.TenantID.String(),
-		TenantName: tenantName,
-	})
+	api.JSONResponse(w, http.StatusOK, h.meResponse(ctx, u))
 }
 
 // ============== Change Password Endpoint ==============
@@ -1173,8 +1352,19 @@ func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate new password meets policy
-	if err := ValidatePassword(req.NewPassword, nil); err != nil {
+	// Validate new password meets the tenant's policy
+	tenantID, err := uuid.Parse(api.GetTenantID(ctx))
+	if err != nil {
+		api.JSONError(w, http.StatusUnauthorized, "Invalid tenant ID", api.ErrCodeUnauthorized)
+		return
+	}
+	policy, err := h.passwordPolicy(ctx, tenantID)
+	if err != nil {
+		h.logger.Error("failed to load password policy", "error", err)
+		api.InternalError(w)
+		return
+	}
+	if err := ValidatePassword(req.NewPassword, policy); err != nil {
 		h.handlePasswordValidationError(w, err)
 		return
 	}
@@ -104,6 +104,12 @@ func (h *Handler) Setup2FA(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The secret is held in Redis until it is verified
+	if h.redisDown() {
+		api.JSONError(w, http.StatusServiceUnavailable, "2FA setup is temporarily unavailable", "SERVICE_UNAVAILABLE")
+		return
+	}
+
 	// Generate TOTP secret
 	totpMgr := NewTOTPManager(nil) // keyManager not needed for generation
 	setupInfo, err := totpMgr.GenerateSecret(u.Email)
@@ -168,7 +174,7 @@ func (h *Handler) Verify2FA(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get the temporary secret from Redis
-	if h.redis == nil {
+	if h.redis == nil || h.redisDown() {
 		api.JSONError(w, http.StatusServiceUnavailable, "Service unavailable", "SERVICE_UNAVAILABLE")
 		return
 	}
@@ -295,6 +301,18 @@ func (h *Handler) Disable2FA(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 2FA cannot be turned off where the tenant requires it
+	policy, err := h.securityPolicy(ctx, tenantUUID)
+	if err != nil {
+		h.logger.Error("failed to load security policy", "error", err)
+		api.InternalError(w)
+		return
+	}
+	if policy.Require2FA {
+		api.JSONError(w, http.StatusForbidden, "Your organization requires two-factor authentication", "2FA_REQUIRED")
+		return
+	}
+
 	// Get user and verify password
 	u, err := h.userService.GetByID(ctx, userUUID)
 	if err != nil {
@@ -36,6 +36,14 @@ type Claims struct {
 	TenantID string    `json:"tid"`
 	Role     string    `json:"role"`
 	Type     TokenType `json:"type"`
+	// BreakGlass is the grant of a time-boxed break-glass access token
+	BreakGlass string `json:"bg,omitempty"`
+	// Enroll2FA restricts an access token to setting up 2FA, for users of
+	// tenants that require it who have not done so yet
+	Enroll2FA bool `json:"2fa_enroll,omitempty"`
+	// RevocationUnchecked is set during validation when the revocation list
+	// was unavailable; it is never part of the token
+	RevocationUnchecked bool `json:"-"`
 	// Email field REMOVED per FR-104 - no PII in JWT
 }
 
@@ -114,6 +122,10 @@ type UserInfo struct {
 	UserID   string
 	TenantID string
 	Role     string
+	// BreakGlass is set for break-glass access to another tenant
+	BreakGlass string
+	// Enroll2FA restricts the access token to setting up 2FA
+	Enroll2FA bool
 	// Email is intentionally not included in JWT claims per FR-104
 }
 
@@ -150,6 +162,15 @@ func (m *JWTManager) GenerateAccessToken(user *UserInfo) (string, time.Time, err
 	return token, expiry, err
 }
 
+// GenerateBreakGlassToken creates an access token for a break-glass grant.
+// It lives until the grant expires and cannot be refreshed.
+func (m *JWTManager) GenerateBreakGlassToken(user *UserInfo, expiry time.Time) (string, error) {
+	if user.BreakGlass == "" {
+		return "", fmt.Errorf("break-glass token without grant")
+	}
+	return m.generateToken(user, AccessToken, expiry)
+}
+
 // GenerateRefreshToken creates a new refresh token
 func (m *JWTManager) GenerateRefreshToken(user *UserInfo) (string, time.Time, error) {
 	expiry := time.Now().Add(m.config.RefreshTokenExpiry)
@@ -174,10 +195,12 @@ func (m *JWTManager) generateToken(user *UserInfo, tokenType TokenType, expiry t
 			ExpiresAt: jwt.NewNumericDate(expiry),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
-		UserID:   user.UserID,
-		TenantID: user.TenantID,
-		Role:     user.Role,
-		Type:     tokenType,
+		UserID:     user.UserID,
+		TenantID:   user.TenantID,
+		Role:       user.Role,
+		Type:       tokenType,
+		BreakGlass: user.BreakGlass,
+		Enroll2FA:  user.Enroll2FA,
 		// Email intentionally NOT included per FR-104
 	}
 
@@ -247,6 +270,11 @@ func (m *JWTManager) ValidateTokenWithContext(ctx context.Context, tokenString s
 	// Check revocation list if configured
 	if m.revoker != nil {
 		revoked, _, err := m.revoker.CheckRevocation(ctx, claims)
+		if errors.Is(err, ErrRevocationUnchecked) {
+			// Degraded mode: accept the token but flag it
+			claims.RevocationUnchecked = true
+			return claims, nil
+		}
 		if err != nil {
 			// Fail closed on revocation check errors for security
 			return nil, ErrTokenRevoked
@@ -6,13 +6,32 @@ import (
 	"strings"
 
 	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/permission"
 	"austrian-business-infrastructure/internal/security"
 	"github.com/google/uuid"
 )
 
+// DegradedHeader is set on responses to requests whose token was accepted
+// without a revocation check because Redis was unavailable
+const DegradedHeader = "X-Degraded"
+
 // AuthMiddleware provides JWT authentication middleware
 type AuthMiddleware struct {
-	jwtManager *JWTManager
+	jwtManager  *JWTManager
+	breakGlass  BreakGlassVerifier
+	permissions PermissionResolver
+}
+
+// BreakGlassVerifier checks on every request that the grant of a
+// break-glass token is still active and records the access
+type BreakGlassVerifier interface {
+	VerifyBreakGlass(ctx context.Context, grantID, userID, tenantID string, r *http.Request) error
+}
+
+// PermissionResolver returns the permissions a user holds through the
+// custom roles assigned to them
+type PermissionResolver interface {
+	CustomPermissions(ctx context.Context, tenantID, userID string) ([]permission.Permission, error)
 }
 
 // NewAuthMiddleware creates a new auth middleware
@@ -20,6 +39,30 @@ func NewAuthMiddleware(jwtManager *JWTManager) *AuthMiddleware {
 	return &AuthMiddleware{jwtManager: jwtManager}
 }
 
+// SetBreakGlassVerifier enables break-glass tokens. Without a verifier
+// they are rejected.
+func (m *AuthMiddleware) SetBreakGlassVerifier(verifier BreakGlassVerifier) {
+	m.breakGlass = verifier
+}
+
+// SetPermissionResolver enables custom roles in RequirePermission. Without
+// a resolver only the built-in permissions of the role count.
+func (m *AuthMiddleware) SetPermissionResolver(resolver PermissionResolver) {
+	m.permissions = resolver
+}
+
+// verifyBreakGlass checks the grant of a break-glass token; regular
+// tokens pass
+func (m *AuthMiddleware) verifyBreakGlass(r *http.Request, claims *Claims) error {
+	if claims.BreakGlass == "" {
+		return nil
+	}
+	if m.breakGlass == nil {
+		return ErrInvalidToken
+	}
+	return m.breakGlass.VerifyBreakGlass(r.Context(), claims.BreakGlass, claims.UserID, claims.TenantID, r)
+}
+
 // RequireAuth returns middleware that requires a valid JWT token
 func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -45,20 +88,37 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 			case ErrExpiredToken:
 				api.JSONError(w, http.StatusUnauthorized, "Token has expired", api.ErrCodeTokenExpired)
 			case ErrTokenRevoked:
+				api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalAuthFailed, Reason: "revoked_token"})
 				api.JSONError(w, http.StatusUnauthorized, "Token has been revoked", api.ErrCodeInvalidToken)
 			case ErrInvalidToken, ErrInvalidClaims:
+				api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalAuthFailed, Reason: "invalid_token"})
 				api.JSONError(w, http.StatusUnauthorized, "Invalid token", api.ErrCodeInvalidToken)
 			default:
 				api.JSONError(w, http.StatusUnauthorized, "Authentication failed", api.ErrCodeUnauthorized)
 			}
 			return
 		}
+		if err := m.verifyBreakGlass(r, claims); err != nil {
+			api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalAuthFailed, Reason: "break_glass_ended"})
+			api.JSONError(w, http.StatusUnauthorized, "Break-glass access has ended", api.ErrCodeTokenExpired)
+			return
+		}
+		if claims.Enroll2FA && !Enroll2FAPath(r.URL.Path) {
+			api.JSONError(w, http.StatusForbidden, "Your organization requires two-factor authentication. Set it up to continue.", api.ErrCode2FASetupRequired)
+			return
+		}
+		if claims.RevocationUnchecked {
+			w.Header().Set(DegradedHeader, "revocation-unchecked")
+		}
 
 		// Inject user info into context (Email intentionally NOT included per FR-104)
 		ctx := r.Context()
 		ctx = context.WithValue(ctx, api.UserIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, api.TenantIDKey, claims.TenantID)
 		ctx = context.WithValue(ctx, api.UserRoleKey, claims.Role)
+		if claims.BreakGlass != "" {
+			ctx = context.WithValue(ctx, api.BreakGlassKey, claims.BreakGlass)
+		}
 		// Note: Email is NOT stored in JWT claims per FR-104 - no PII in tokens
 
 		// Also set RLS tenant context for Row-Level Security (FR-113)
@@ -72,6 +132,12 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 	})
 }
 
+// Enroll2FAPath reports whether a path is open to access tokens restricted
+// to setting up 2FA: the 2FA routes themselves and the current user
+func Enroll2FAPath(path string) bool {
+	return strings.HasPrefix(path, "/api/v1/auth/2fa/") || path == "/api/v1/auth/2fa" || path == "/api/v1/auth/me"
+}
+
 // OptionalAuth returns middleware that validates JWT if present but doesn't require it
 func (m *AuthMiddleware) OptionalAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -88,17 +154,24 @@ func (m *AuthMiddleware) OptionalAuth(next http.Handler) http.Handler {
 
 		token := authHeader[7:]
 		claims, err := m.jwtManager.ValidateAccessTokenWithContext(r.Context(), token)
-		if err != nil {
-			// Invalid or revoked token - continue without auth
+		if err != nil || m.verifyBreakGlass(r, claims) != nil || claims.Enroll2FA {
+			// Invalid or revoked token, or one only good for setting up
+			// 2FA - continue without auth
 			next.ServeHTTP(w, r)
 			return
 		}
+		if claims.RevocationUnchecked {
+			w.Header().Set(DegradedHeader, "revocation-unchecked")
+		}
 
 		// Inject user info into context (Email intentionally NOT included per FR-104)
 		ctx := r.Context()
 		ctx = context.WithValue(ctx, api.UserIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, api.TenantIDKey, claims.TenantID)
 		ctx = context.WithValue(ctx, api.UserRoleKey, claims.Role)
+		if claims.BreakGlass != "" {
+			ctx = context.WithValue(ctx, api.BreakGlassKey, claims.BreakGlass)
+		}
 		// Note: Email is NOT stored in JWT claims per FR-104 - no PII in tokens
 
 		// Also set RLS tenant context for Row-Level Security (FR-113)
@@ -123,6 +196,7 @@ func (m *AuthMiddleware) RequireRole(minRole string) api.Middleware {
 			}
 
 			if !hasMinimumRole(userRole, minRole) {
+				api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalPermissionDenied, Reason: "requires_" + minRole})
 				api.JSONError(w, http.StatusForbidden, "Insufficient permissions", api.ErrCodeForbidden)
 				return
 			}
@@ -132,6 +206,46 @@ func (m *AuthMiddleware) RequireRole(minRole string) api.Middleware {
 	}
 }
 
+// RequirePermission returns middleware that requires a permission, held
+// through the built-in role or a custom role of the user
+func (m *AuthMiddleware) RequirePermission(p permission.Permission) api.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userRole := api.GetUserRole(r.Context())
+			if userRole == "" {
+				api.JSONError(w, http.StatusUnauthorized, "Authentication required", api.ErrCodeUnauthorized)
+				return
+			}
+
+			if !m.hasPermission(r, userRole, p) {
+				api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalPermissionDenied, Reason: "requires_" + string(p)})
+				api.JSONError(w, http.StatusForbidden, "Insufficient permissions", api.ErrCodeForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// hasPermission checks the built-in permissions of the role first and the
+// custom roles only when those lack p. Failing to resolve the custom roles
+// denies.
+func (m *AuthMiddleware) hasPermission(r *http.Request, role string, p permission.Permission) bool {
+	if permission.Has(permission.Builtin(role), p) {
+		return true
+	}
+	if m.permissions == nil {
+		return false
+	}
+	ctx := r.Context()
+	custom, err := m.permissions.CustomPermissions(ctx, api.GetTenantID(ctx), api.GetUserID(ctx))
+	if err != nil {
+		return false
+	}
+	return permission.Has(custom, p)
+}
+
 // RequireTenant returns middleware that requires matching tenant
 func (m *AuthMiddleware) RequireTenant(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -145,6 +259,7 @@ func (m *AuthMiddleware) RequireTenant(next http.Handler) http.Handler {
 		// Check if user's tenant matches
 		userTenant := api.GetTenantID(r.Context())
 		if userTenant != pathTenant {
+			api.ReportTenantMismatch(r, "tenant", pathTenant)
 			api.JSONError(w, http.StatusForbidden, "Access denied to this tenant", api.ErrCodeForbidden)
 			return
 		}
@@ -0,0 +1,375 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrOIDCDiscovery  = errors.New("OIDC discovery failed")
+	ErrInvalidIDToken = errors.New("invalid ID token")
+)
+
+// jwksRefreshInterval is how often the keys of an issuer may be fetched
+// again when an ID token is signed with an unknown key
+const jwksRefreshInterval = time.Minute
+
+// OIDCClientConfig configures an OIDC client
+type OIDCClientConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTPClient   *http.Client // Optional
+}
+
+// OIDCClient is an OpenID Connect relying party of one client at one
+// issuer: the authorization code flow with PKCE and a nonce, and the
+// verification of ID tokens against the keys the issuer publishes. The
+// endpoints are discovered on first use.
+type OIDCClient struct {
+	cfg        OIDCClientConfig
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	discovery   *oidcDiscovery
+	oauth       *oauth2.Config
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+// oidcDiscovery is the part of the discovery document the client uses
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCAuthorization is a started authorization. State, Nonce and Verifier
+// must be kept until the callback.
+type OIDCAuthorization struct {
+	URL      string
+	State    string
+	Nonce    string
+	Verifier string
+}
+
+// IDClaims are the claims of a verified ID token
+type IDClaims map[string]any
+
+// String returns a string claim, "" if it is missing or not a string
+func (c IDClaims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Bool returns a boolean claim. Some providers send "true" as a string.
+func (c IDClaims) Bool(name string) bool {
+	switch v := c[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
+
+// Strings returns a claim holding a string or a list of strings, such as
+// groups or roles
+func (c IDClaims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// NewOIDCClient creates a new OIDC client
+func NewOIDCClient(cfg OIDCClientConfig) *OIDCClient {
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	cfg.Issuer = strings.TrimSuffix(cfg.Issuer, "/")
+	return &OIDCClient{cfg: cfg, httpClient: httpClient}
+}
+
+// Authorize starts an authorization and returns the URL of the issuer's
+// login page to send the user to
+func (c *OIDCClient) Authorize(ctx context.Context) (*OIDCAuthorization, error) {
+	oauth, _, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	return &OIDCAuthorization{
+		URL:      oauth.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), oauth2.SetAuthURLParam("nonce", nonce)),
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+	}, nil
+}
+
+// Exchange redeems the authorization code the issuer redirected back with
+// and returns the claims of the verified ID token
+func (c *OIDCClient) Exchange(ctx context.Context, code, verifier, nonce string) (IDClaims, error) {
+	oauth, _, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauth.Exchange(context.WithValue(ctx, oauth2.HTTPClient, c.httpClient), code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no ID token", ErrInvalidIDToken)
+	}
+	return c.VerifyIDToken(ctx, rawIDToken, nonce)
+}
+
+// VerifyIDToken checks the signature, issuer, audience, lifetime and nonce
+// of an ID token and returns its claims
+func (c *OIDCClient) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (IDClaims, error) {
+	_, discovery, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return c.key(ctx, discovery.JWKSURI, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(c.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	// A token for several audiences must have been issued to this client
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != c.cfg.ClientID {
+			return nil, fmt.Errorf("%w: authorized party mismatch", ErrInvalidIDToken)
+		}
+	}
+	got, _ := claims["nonce"].(string)
+	if subtle.ConstantTimeCompare([]byte(got), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, fmt.Errorf("%w: subject missing", ErrInvalidIDToken)
+	}
+	return IDClaims(claims), nil
+}
+
+// discover fetches the discovery document once and returns the OAuth
+// configuration built from it
+func (c *OIDCClient) discover(ctx context.Context) (*oauth2.Config, *oidcDiscovery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.discovery != nil {
+		return c.oauth, c.discovery, nil
+	}
+
+	var d oidcDiscovery
+	if err := c.getJSON(ctx, c.cfg.Issuer+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrOIDCDiscovery, err)
+	}
+	if strings.TrimSuffix(d.Issuer, "/") != c.cfg.Issuer {
+		return nil, nil, fmt.Errorf("%w: issuer %q does not match %q", ErrOIDCDiscovery, d.Issuer, c.cfg.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, nil, fmt.Errorf("%w: endpoints missing", ErrOIDCDiscovery)
+	}
+
+	c.discovery = &d
+	c.oauth = &oauth2.Config{
+		ClientID:     c.cfg.ClientID,
+		ClientSecret: c.cfg.ClientSecret,
+		RedirectURL:  c.cfg.RedirectURL,
+		Scopes:       c.cfg.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  d.AuthorizationEndpoint,
+			TokenURL: d.TokenEndpoint,
+		},
+	}
+	return c.oauth, c.discovery, nil
+}
+
+// key returns the issuer's key with the ID kid. The keys are fetched again
+// when the kid is unknown, as issuers rotate their keys.
+func (c *OIDCClient) key(ctx context.Context, jwksURI, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.lookupKey(kid); ok {
+		return key, nil
+	}
+	if time.Since(c.keysFetched) < jwksRefreshInterval {
+		return nil, errors.New("unknown signing key")
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := c.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	c.keys = make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if pub, err := k.publicKey(); err == nil {
+			c.keys[k.Kid] = pub
+		}
+	}
+	c.keysFetched = time.Now()
+
+	if key, ok := c.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, errors.New("unknown signing key")
+}
+
+// lookupKey finds a cached key; a token without kid is accepted if the
+// issuer has a single key. c.mu must be held.
+func (c *OIDCClient) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, true
+		}
+	}
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+func (c *OIDCClient) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// jsonWebKey is an RSA or EC public key of a JWK set
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	decode := func(s string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+		if err != nil || len(b) == 0 {
+			return nil, errors.New("invalid key parameter")
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// randomToken returns 32 random bytes, hex encoded
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -13,6 +13,9 @@ var (
 	ErrPasswordInvalid     = crypto.ErrPasswordInvalid
 )
 
+// PasswordTooShortError is an alias for crypto.PasswordTooShortError
+type PasswordTooShortError = crypto.PasswordTooShortError
+
 // PasswordPolicy is an alias for crypto.PasswordPolicy
 type PasswordPolicy = crypto.PasswordPolicy
 
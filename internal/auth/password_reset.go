@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Password reset limits. Only the latest link of an account works; links
+// are sent at most PasswordResetMaxPerHour times an hour and not more often
+// than every PasswordResetCooldown.
+const (
+	PasswordResetTTL        = 1 * time.Hour
+	PasswordResetMaxPerHour = 3
+	PasswordResetCooldown   = 2 * time.Minute
+)
+
+// Password reset errors
+var (
+	ErrResetThrottled    = errors.New("password reset requested too often")
+	ErrResetTokenInvalid = errors.New("invalid or expired reset token")
+)
+
+// PasswordResetStore keeps password reset tokens in Redis. Only a SHA-256
+// hash of each token is stored, so a Redis dump does not reveal usable links.
+type PasswordResetStore struct {
+	redis  redis.Cmdable
+	prefix string
+}
+
+// NewPasswordResetStore creates a new password reset store
+func NewPasswordResetStore(redisClient redis.Cmdable) *PasswordResetStore {
+	return &PasswordResetStore{
+		redis:  redisClient,
+		prefix: "password_reset:",
+	}
+}
+
+// Issue creates a reset token for a user and invalidates the previous one.
+// It returns ErrResetThrottled when the user's resend limits are reached.
+func (s *PasswordResetStore) Issue(ctx context.Context, userID uuid.UUID) (string, error) {
+	cooldownKey := s.prefix + "cooldown:" + userID.String()
+	ok, err := s.redis.SetNX(ctx, cooldownKey, "1", PasswordResetCooldown).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to check reset cooldown: %w", err)
+	}
+	if !ok {
+		return "", ErrResetThrottled
+	}
+
+	countKey := s.prefix + "sent:" + userID.String()
+	count, err := s.redis.Incr(ctx, countKey).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to count reset requests: %w", err)
+	}
+	if count == 1 {
+		s.redis.Expire(ctx, countKey, time.Hour)
+	}
+	if count > PasswordResetMaxPerHour {
+		return "", ErrResetThrottled
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(tokenBytes)
+	hash := hashResetToken(token)
+
+	userKey := s.prefix + "user:" + userID.String()
+	previous, err := s.redis.Get(ctx, userKey).Result()
+	if err != nil && err != redis.Nil {
+		return "", fmt.Errorf("failed to load previous reset token: %w", err)
+	}
+
+	pipe := s.redis.TxPipeline()
+	if previous != "" {
+		pipe.Del(ctx, s.prefix+previous)
+	}
+	pipe.Set(ctx, s.prefix+hash, userID.String(), PasswordResetTTL)
+	pipe.Set(ctx, userKey, hash, PasswordResetTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("failed to store reset token: %w", err)
+	}
+
+	return token, nil
+}
+
+// Lookup returns the user a token was issued for without redeeming it
+func (s *PasswordResetStore) Lookup(ctx context.Context, token string) (uuid.UUID, error) {
+	if token == "" {
+		return uuid.Nil, ErrResetTokenInvalid
+	}
+
+	userIDStr, err := s.redis.Get(ctx, s.prefix+hashResetToken(token)).Result()
+	if err == redis.Nil {
+		return uuid.Nil, ErrResetTokenInvalid
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to load reset token: %w", err)
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, ErrResetTokenInvalid
+	}
+	return userID, nil
+}
+
+// Consume redeems a token once and returns the user it was issued for
+func (s *PasswordResetStore) Consume(ctx context.Context, token string) (uuid.UUID, error) {
+	if token == "" {
+		return uuid.Nil, ErrResetTokenInvalid
+	}
+
+	userIDStr, err := s.redis.GetDel(ctx, s.prefix+hashResetToken(token)).Result()
+	if err == redis.Nil {
+		return uuid.Nil, ErrResetTokenInvalid
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to load reset token: %w", err)
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, ErrResetTokenInvalid
+	}
+	s.redis.Del(ctx, s.prefix+"user:"+userID.String())
+	return userID, nil
+}
+
+// hashResetToken returns the key a token is stored under
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
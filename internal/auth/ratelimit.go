@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"austrian-business-infrastructure/pkg/cache"
 )
 
 var (
@@ -34,7 +36,8 @@ const (
 
 // RateLimiter provides rate limiting functionality using Redis
 type RateLimiter struct {
-	client *redis.Client
+	client       *redis.Client
+	availability cache.Availability
 }
 
 // NewRateLimiter creates a new rate limiter
@@ -42,6 +45,13 @@ func NewRateLimiter(client *redis.Client) *RateLimiter {
 	return &RateLimiter{client: client}
 }
 
+// SetAvailability makes Check fail with cache.ErrUnavailable at once while
+// Redis is down, so fail-closed endpoints reject without waiting for a
+// timeout and fail-open ones pass
+func (rl *RateLimiter) SetAvailability(a cache.Availability) {
+	rl.availability = a
+}
+
 // RateLimitConfig contains configuration for a rate limit
 type RateLimitConfig struct {
 	// MaxRequests is the maximum number of requests allowed in the window
@@ -78,6 +88,10 @@ func (rl *RateLimiter) CheckLogin(ctx context.Context, ip string) error {
 // Returns nil if allowed, ErrRateLimited if blocked.
 // Automatically increments the counter.
 func (rl *RateLimiter) Check(ctx context.Context, config *RateLimitConfig, identifier string) error {
+	if rl.availability != nil && !rl.availability.Available() {
+		return fmt.Errorf("rate limit check failed: %w", cache.ErrUnavailable)
+	}
+
 	key := config.KeyPrefix + identifier
 
 	// Use a Lua script for atomic increment and check
@@ -2,17 +2,28 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"austrian-business-infrastructure/pkg/cache"
 )
 
+// ErrRevocationUnchecked is returned by CheckRevocation while Redis is known
+// to be unavailable. Tokens are then accepted without a revocation check and
+// flagged, instead of locking every user out.
+var ErrRevocationUnchecked = errors.New("revocation list unavailable")
+
 // TokenRevocationList manages revoked tokens using Redis
 // Tokens are stored until their natural expiry, then automatically cleaned up
 type TokenRevocationList struct {
-	redis  redis.Cmdable
-	prefix string
+	redis        redis.Cmdable
+	prefix       string
+	availability cache.Availability
+	unchecked    atomic.Int64
 }
 
 // NewTokenRevocationList creates a new token revocation list
@@ -23,6 +34,18 @@ func NewTokenRevocationList(redisClient redis.Cmdable) *TokenRevocationList {
 	}
 }
 
+// SetAvailability enables degraded mode: while a reports Redis as down,
+// CheckRevocation returns ErrRevocationUnchecked at once. Errors while Redis
+// is reported available still fail closed.
+func (r *TokenRevocationList) SetAvailability(a cache.Availability) {
+	r.availability = a
+}
+
+// Unchecked returns the number of tokens accepted without a revocation check
+func (r *TokenRevocationList) Unchecked() int64 {
+	return r.unchecked.Load()
+}
+
 // RevokeToken adds a token to the revocation list
 // The token ID (jti) is stored until the token's expiry time
 func (r *TokenRevocationList) RevokeToken(ctx context.Context, tokenID string, expiry time.Time) error {
@@ -158,6 +181,11 @@ func (r *TokenRevocationList) IsTenantTokenRevoked(ctx context.Context, tenantID
 // CheckRevocation performs a complete revocation check for a token
 // Checks individual token, user-level, and tenant-level revocations
 func (r *TokenRevocationList) CheckRevocation(ctx context.Context, claims *Claims) (bool, string, error) {
+	if r.availability != nil && !r.availability.Available() {
+		r.unchecked.Add(1)
+		return false, "revocation_unchecked", ErrRevocationUnchecked
+	}
+
 	// Check individual token revocation
 	if claims.ID != "" {
 		revoked, err := r.IsRevoked(ctx, claims.ID)
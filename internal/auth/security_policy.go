@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/pkg/crypto"
+)
+
+var (
+	ErrInvalidSecurityPolicy = errors.New("invalid security policy")
+	ErrSecurityPolicyLockout = errors.New("the allowed IP ranges do not include your current IP address")
+)
+
+// Security policy limits
+const (
+	MaxPasswordLength         = 128
+	MinSessionDurationMinutes = 15
+	MaxAllowedIPRanges        = 50
+	MaxSessionDurationMinutes = 90 * 24 * 60
+)
+
+// SecurityPolicy is the security policy of a tenant, enforced on login,
+// token refresh and password changes. Tenants without one get
+// DefaultSecurityPolicy; a policy can only tighten the platform defaults.
+type SecurityPolicy struct {
+	TenantID          uuid.UUID `json:"tenant_id"`
+	MinPasswordLength int       `json:"min_password_length"`
+	// Require2FA makes users without 2FA set it up before anything else
+	Require2FA bool `json:"require_2fa"`
+	// SessionDurationMinutes is how long a session can be refreshed after
+	// login; nil keeps the lifetime of the refresh token
+	SessionDurationMinutes *int `json:"session_duration_minutes"`
+	// AllowedIPRanges are the CIDRs logins and refreshes are accepted
+	// from; empty allows all
+	AllowedIPRanges []string   `json:"allowed_ip_ranges"`
+	UpdatedBy       *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+
+	prefixes []netip.Prefix
+}
+
+// SecurityPolicyInput is the request body of a security policy update. It
+// replaces the whole policy.
+type SecurityPolicyInput struct {
+	MinPasswordLength      int      `json:"min_password_length"`
+	Require2FA             bool     `json:"require_2fa"`
+	SessionDurationMinutes *int     `json:"session_duration_minutes"`
+	AllowedIPRanges        []string `json:"allowed_ip_ranges"`
+}
+
+// DefaultSecurityPolicy returns the policy of a tenant that has not set one
+func DefaultSecurityPolicy(tenantID uuid.UUID) *SecurityPolicy {
+	return &SecurityPolicy{
+		TenantID:          tenantID,
+		MinPasswordLength: crypto.MinPasswordLength,
+		AllowedIPRanges:   []string{},
+	}
+}
+
+// ApplySecurityPolicyInput validates input and sets it on p. Single IP
+// addresses are accepted as ranges and all ranges are normalized.
+func ApplySecurityPolicyInput(p *SecurityPolicy, input *SecurityPolicyInput) error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidSecurityPolicy, fmt.Sprintf(format, args...))
+	}
+
+	minLength := input.MinPasswordLength
+	if minLength == 0 {
+		minLength = crypto.MinPasswordLength
+	}
+	if minLength < crypto.MinPasswordLength || minLength > MaxPasswordLength {
+		return invalid("min_password_length must be between %d and %d", crypto.MinPasswordLength, MaxPasswordLength)
+	}
+
+	if d := input.SessionDurationMinutes; d != nil && (*d < MinSessionDurationMinutes || *d > MaxSessionDurationMinutes) {
+		return invalid("session_duration_minutes must be between %d and %d", MinSessionDurationMinutes, MaxSessionDurationMinutes)
+	}
+
+	if len(input.AllowedIPRanges) > MaxAllowedIPRanges {
+		return invalid("at most %d allowed IP ranges", MaxAllowedIPRanges)
+	}
+	ranges := make([]string, 0, len(input.AllowedIPRanges))
+	prefixes := make([]netip.Prefix, 0, len(input.AllowedIPRanges))
+	for _, raw := range input.AllowedIPRanges {
+		prefix, err := parseIPRange(raw)
+		if err != nil {
+			return invalid("allowed IP range %q is neither an IP address nor a CIDR", raw)
+		}
+		if prefix.Bits() == 0 {
+			return invalid("allowed IP range %q allows every address", raw)
+		}
+		ranges = append(ranges, prefix.String())
+		prefixes = append(prefixes, prefix)
+	}
+
+	p.MinPasswordLength = minLength
+	p.Require2FA = input.Require2FA
+	p.SessionDurationMinutes = input.SessionDurationMinutes
+	p.AllowedIPRanges = ranges
+	p.prefixes = prefixes
+	return nil
+}
+
+// parseIPRange parses a CIDR or a single IP address
+func parseIPRange(raw string) (netip.Prefix, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.Contains(raw, "/") {
+		prefix, err := netip.ParsePrefix(raw)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		if prefix.Addr().Is4In6() {
+			if prefix.Bits() < 96 {
+				return netip.Prefix{}, fmt.Errorf("IPv4-mapped prefix shorter than /96")
+			}
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(raw)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// setRanges sets the allowed IP ranges as stored
+func (p *SecurityPolicy) setRanges(ranges []string) error {
+	p.AllowedIPRanges = ranges
+	p.prefixes = make([]netip.Prefix, 0, len(ranges))
+	for _, raw := range ranges {
+		prefix, err := parseIPRange(raw)
+		if err != nil {
+			return fmt.Errorf("stored IP range %q: %w", raw, err)
+		}
+		p.prefixes = append(p.prefixes, prefix)
+	}
+	return nil
+}
+
+// AllowsIP reports whether a client IP is within the allowed ranges.
+// Without ranges every IP is allowed; with ranges an unparsable one is not.
+func (p *SecurityPolicy) AllowsIP(ip string) bool {
+	if len(p.prefixes) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range p.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionExpired reports whether a session created at createdAt can no
+// longer be refreshed
+func (p *SecurityPolicy) SessionExpired(createdAt, now time.Time) bool {
+	if p.SessionDurationMinutes == nil {
+		return false
+	}
+	return now.After(createdAt.Add(time.Duration(*p.SessionDurationMinutes) * time.Minute))
+}
+
+// PasswordPolicy returns the password requirements of the policy
+func (p *SecurityPolicy) PasswordPolicy() *PasswordPolicy {
+	policy := DefaultPasswordPolicy()
+	if p.MinPasswordLength > policy.MinLength {
+		policy.MinLength = p.MinPasswordLength
+	}
+	return policy
+}
+
+// SecurityPolicyService manages the security policies of tenants
+type SecurityPolicyService struct {
+	repo *SecurityPolicyRepository
+}
+
+// NewSecurityPolicyService creates a new security policy service
+func NewSecurityPolicyService(repo *SecurityPolicyRepository) *SecurityPolicyService {
+	return &SecurityPolicyService{repo: repo}
+}
+
+// Get returns the security policy of a tenant
+func (s *SecurityPolicyService) Get(ctx context.Context, tenantID uuid.UUID) (*SecurityPolicy, error) {
+	return s.repo.Get(ctx, tenantID)
+}
+
+// Update replaces the security policy of a tenant. It refuses allowed IP
+// ranges that would shut out the admin making the change from clientIP.
+func (s *SecurityPolicyService) Update(ctx context.Context, tenantID uuid.UUID, input *SecurityPolicyInput, updatedBy uuid.UUID, clientIP string) (*SecurityPolicy, error) {
+	p := DefaultSecurityPolicy(tenantID)
+	if err := ApplySecurityPolicyInput(p, input); err != nil {
+		return nil, err
+	}
+	if !p.AllowsIP(clientIP) {
+		return nil, ErrSecurityPolicyLockout
+	}
+	p.UpdatedBy = &updatedBy
+	if err := s.repo.Upsert(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// PasswordPolicy returns the password requirements of a tenant
+func (s *SecurityPolicyService) PasswordPolicy(ctx context.Context, tenantID uuid.UUID) (*crypto.PasswordPolicy, error) {
+	p, err := s.repo.Get(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return p.PasswordPolicy(), nil
+}
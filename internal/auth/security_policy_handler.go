@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/audit"
+)
+
+// SecurityPolicyHandler handles the security policy settings of tenants
+type SecurityPolicyHandler struct {
+	service *SecurityPolicyService
+	auth    *Handler
+	logger  *slog.Logger
+}
+
+// NewSecurityPolicyHandler creates a new security policy handler. Client
+// IPs are taken and changes audited like logins by auth.
+func NewSecurityPolicyHandler(service *SecurityPolicyService, auth *Handler, logger *slog.Logger) *SecurityPolicyHandler {
+	return &SecurityPolicyHandler{service: service, auth: auth, logger: logger}
+}
+
+// RegisterRoutes registers the security policy routes, open to tenant
+// admins only
+func (h *SecurityPolicyHandler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/security/policy", requireAuth(requireAdmin(http.HandlerFunc(h.Get))))
+	router.Handle("PUT /api/v1/security/policy", requireAuth(requireAdmin(http.HandlerFunc(h.Update))))
+}
+
+// Get handles GET /api/v1/security/policy
+func (h *SecurityPolicyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := ssoRequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := h.service.Get(r.Context(), tenantID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, p)
+}
+
+// Update handles PUT /api/v1/security/policy
+func (h *SecurityPolicyHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID, ok := ssoRequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(api.GetUserID(ctx))
+	if err != nil {
+		api.Unauthorized(w, "user not found in context")
+		return
+	}
+
+	var input SecurityPolicyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	clientIP := h.auth.getClientIP(r)
+	p, err := h.service.Update(ctx, tenantID, &input, userID, clientIP)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.auth.logAuthEvent(ctx, audit.EventSecurityPolicyUpdated, &userID, &tenantID, clientIP, r.UserAgent(), map[string]any{
+		"min_password_length":      p.MinPasswordLength,
+		"require_2fa":              p.Require2FA,
+		"session_duration_minutes": p.SessionDurationMinutes,
+		"allowed_ip_ranges":        p.AllowedIPRanges,
+	})
+
+	api.JSONResponse(w, http.StatusOK, p)
+}
+
+func (h *SecurityPolicyHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidSecurityPolicy), errors.Is(err, ErrSecurityPolicyLockout):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	default:
+		h.logger.Error("security policy request failed", "error", err)
+		api.InternalError(w)
+	}
+}
+
+// securityPolicy returns the security policy of a tenant, the default one
+// if no policies are set up
+func (h *Handler) securityPolicy(ctx context.Context, tenantID uuid.UUID) (*SecurityPolicy, error) {
+	if h.policies == nil {
+		return DefaultSecurityPolicy(tenantID), nil
+	}
+	return h.policies.Get(ctx, tenantID)
+}
+
+// passwordPolicy returns the password requirements of a tenant
+func (h *Handler) passwordPolicy(ctx context.Context, tenantID uuid.UUID) (*PasswordPolicy, error) {
+	p, err := h.securityPolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return p.PasswordPolicy(), nil
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SecurityPolicyRepository provides tenant security policy data access
+type SecurityPolicyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSecurityPolicyRepository creates a new security policy repository
+func NewSecurityPolicyRepository(pool *pgxpool.Pool) *SecurityPolicyRepository {
+	return &SecurityPolicyRepository{pool: pool}
+}
+
+// Get returns the security policy of a tenant, or the default policy if
+// the tenant has not set one
+func (r *SecurityPolicyRepository) Get(ctx context.Context, tenantID uuid.UUID) (*SecurityPolicy, error) {
+	p := &SecurityPolicy{TenantID: tenantID}
+	var ranges []string
+	err := r.pool.QueryRow(ctx, `
+		SELECT min_password_length, require_2fa, session_duration_minutes, allowed_ip_ranges::text[],
+			updated_by, updated_at
+		FROM tenant_security_policies WHERE tenant_id = $1
+	`, tenantID).Scan(&p.MinPasswordLength, &p.Require2FA, &p.SessionDurationMinutes, &ranges,
+		&p.UpdatedBy, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DefaultSecurityPolicy(tenantID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get security policy: %w", err)
+	}
+	if ranges == nil {
+		ranges = []string{}
+	}
+	if err := p.setRanges(ranges); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Upsert stores the security policy of a tenant
+func (r *SecurityPolicyRepository) Upsert(ctx context.Context, p *SecurityPolicy) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO tenant_security_policies (tenant_id, min_password_length, require_2fa,
+			session_duration_minutes, allowed_ip_ranges, updated_by)
+		VALUES ($1, $2, $3, $4, $5::cidr[], $6)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			min_password_length = EXCLUDED.min_password_length,
+			require_2fa = EXCLUDED.require_2fa,
+			session_duration_minutes = EXCLUDED.session_duration_minutes,
+			allowed_ip_ranges = EXCLUDED.allowed_ip_ranges,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, p.TenantID, p.MinPasswordLength, p.Require2FA, p.SessionDurationMinutes, p.AllowedIPRanges, p.UpdatedBy,
+	).Scan(&p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("update security policy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,676 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/crypto"
+	"austrian-business-infrastructure/internal/tenant"
+	"austrian-business-infrastructure/internal/user"
+)
+
+var (
+	ErrSSOProviderNotFound = errors.New("SSO provider not found")
+	ErrSSOProviderExists   = errors.New("an SSO provider with this name already exists")
+	ErrInvalidSSOProvider  = errors.New("invalid SSO provider")
+	ErrSSOState            = errors.New("invalid or expired SSO state")
+	ErrSSOLoginCode        = errors.New("invalid or expired SSO login code")
+	ErrSSONoEmail          = errors.New("the identity provider sent no email address")
+	ErrSSODomainNotAllowed = errors.New("the email domain is not allowed for this SSO provider")
+	ErrSSOEmailUnverified  = errors.New("the email address is not verified by the identity provider")
+	ErrSSONoAccount        = errors.New("no account for this identity")
+	ErrSSOAccountInactive  = errors.New("the account is inactive")
+	ErrSSOLinkNotApproved  = errors.New("linking this identity to the existing account needs an admin's approval")
+	ErrSSOLinkForbidden    = errors.New("only owners can approve SSO links of owners")
+)
+
+// TrustableEmailClaims are the claims trust_email_claim accepts as verified
+// emails: sign-in names managed by the directory, which users cannot edit
+var TrustableEmailClaims = []string{"preferred_username", "upn"}
+
+// SSO provider kinds. The presets set the issuer of Google and the claims
+// of Microsoft Entra ID; any other OpenID Connect provider is "oidc".
+const (
+	SSOKindIDAustria = "id_austria"
+	SSOKindEntra     = "entra"
+	SSOKindGoogle    = "google"
+	SSOKindOIDC      = "oidc"
+)
+
+// Issuers of the presets
+const (
+	GoogleIssuer    = "https://accounts.google.com"
+	IDAustriaIssuer = "https://eid.gv.at"
+)
+
+// SSO flow lifetimes
+const (
+	ssoStateTTL     = 10 * time.Minute
+	ssoLoginCodeTTL = 60 * time.Second
+)
+
+// SSOStateStore keeps the state of a login between the redirect to the
+// identity provider and the callback, and the login codes after it;
+// cache.Store implements it
+type SSOStateStore interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// SSOProvider is an OpenID Connect identity provider a tenant signs in with
+type SSOProvider struct {
+	ID              uuid.UUID         `json:"id"`
+	TenantID        uuid.UUID         `json:"tenant_id"`
+	Name            string            `json:"name"`
+	Kind            string            `json:"kind"`
+	Issuer          string            `json:"issuer"`
+	ClientID        string            `json:"client_id"`
+	HasClientSecret bool              `json:"has_client_secret"`
+	Scopes          []string          `json:"scopes"`
+	EmailClaim      string            `json:"email_claim"`
+	NameClaim       string            `json:"name_claim"`
+	RoleClaim       *string           `json:"role_claim,omitempty"`
+	RoleMapping     map[string]string `json:"role_mapping"`
+	AllowedDomains  []string          `json:"allowed_domains"`
+	TrustEmailClaim bool              `json:"trust_email_claim"`
+	JITProvisioning bool              `json:"jit_provisioning"`
+	DefaultRole     string            `json:"default_role"`
+	Enabled         bool              `json:"enabled"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+
+	clientSecret []byte // Encrypted
+}
+
+// SSOProviderInput creates or replaces an SSO provider. A nil ClientSecret
+// keeps the stored secret; an empty one removes it.
+type SSOProviderInput struct {
+	Name            string            `json:"name"`
+	Kind            string            `json:"kind"`
+	Issuer          string            `json:"issuer"`
+	ClientID        string            `json:"client_id"`
+	ClientSecret    *string           `json:"client_secret,omitempty"`
+	Scopes          []string          `json:"scopes"`
+	EmailClaim      string            `json:"email_claim"`
+	NameClaim       string            `json:"name_claim"`
+	RoleClaim       string            `json:"role_claim"`
+	RoleMapping     map[string]string `json:"role_mapping"`
+	AllowedDomains  []string          `json:"allowed_domains"`
+	TrustEmailClaim bool              `json:"trust_email_claim"`
+	JITProvisioning bool              `json:"jit_provisioning"`
+	DefaultRole     string            `json:"default_role"`
+	Enabled         *bool             `json:"enabled"`
+}
+
+// SSOLoginOption is an enabled provider offered on a tenant's login page
+type SSOLoginOption struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Kind string    `json:"kind"`
+}
+
+// SSOIdentity is a user as the identity provider describes them, after
+// claim mapping
+type SSOIdentity struct {
+	Subject string
+	Email   string
+	Name    string
+	Role    user.Role // Role for provisioning
+}
+
+// SSOLink is a user's identity linked with a provider, or an approval to
+// link one on the user's next SSO login
+type SSOLink struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	Email       string     `json:"email"`
+	Linked      bool       `json:"linked"`
+	Subject     *string    `json:"subject,omitempty"`
+	ApprovedBy  *uuid.UUID `json:"approved_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+// ssoState is what the state parameter of a login stands for
+type ssoState struct {
+	ProviderID uuid.UUID `json:"provider_id"`
+	Nonce      string    `json:"nonce"`
+	Verifier   string    `json:"verifier"`
+}
+
+// ApplySSOProviderInput validates the input and sets it on the provider,
+// filling in the defaults of its kind
+func ApplySSOProviderInput(p *SSOProvider, input *SSOProviderInput) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: %s", ErrInvalidSSOProvider, reason)
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return invalid("name is required (at most 100 characters)")
+	}
+	kind := strings.TrimSpace(input.Kind)
+	issuer := strings.TrimSuffix(strings.TrimSpace(input.Issuer), "/")
+	emailClaim, nameClaim := "email", "name"
+	switch kind {
+	case SSOKindGoogle:
+		issuer = GoogleIssuer
+	case SSOKindIDAustria:
+		if issuer == "" {
+			issuer = IDAustriaIssuer
+		}
+	case SSOKindEntra:
+		// Entra ID sends the email claim only if configured, the sign-in
+		// name always
+		emailClaim = "preferred_username"
+		if strings.Contains(issuer, "/common/") || strings.Contains(issuer, "/organizations/") {
+			return invalid("Entra ID needs the issuer of a directory, https://login.microsoftonline.com/{tenant-id}/v2.0")
+		}
+	case SSOKindOIDC:
+	default:
+		return invalid("kind must be id_austria, entra, google or oidc")
+	}
+	if !validIssuer(issuer) {
+		return invalid("issuer must be an https URL")
+	}
+	clientID := strings.TrimSpace(input.ClientID)
+	if clientID == "" {
+		return invalid("client_id is required")
+	}
+
+	scopes := []string{"openid"}
+	if len(input.Scopes) == 0 {
+		scopes = append(scopes, "email", "profile")
+	}
+	for _, s := range input.Scopes {
+		if s = strings.TrimSpace(s); s != "" && !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	if c := strings.TrimSpace(input.EmailClaim); c != "" {
+		emailClaim = c
+	}
+	if c := strings.TrimSpace(input.NameClaim); c != "" {
+		nameClaim = c
+	}
+	if input.TrustEmailClaim && !slices.Contains(TrustableEmailClaims, emailClaim) {
+		return invalid("trust_email_claim needs email_claim preferred_username or upn")
+	}
+
+	var roleClaim *string
+	if c := strings.TrimSpace(input.RoleClaim); c != "" {
+		roleClaim = &c
+	}
+	mapping := make(map[string]string, len(input.RoleMapping))
+	for value, role := range input.RoleMapping {
+		if !provisionableRole(role) {
+			return invalid("role_mapping may map to admin, member or viewer only")
+		}
+		mapping[value] = role
+	}
+	defaultRole := input.DefaultRole
+	if defaultRole == "" {
+		defaultRole = string(user.RoleMember)
+	}
+	if !provisionableRole(defaultRole) {
+		return invalid("default_role must be admin, member or viewer")
+	}
+
+	domains := []string{}
+	for _, d := range input.AllowedDomains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if d == "" || strings.ContainsAny(d, "@/ ") {
+			return invalid("allowed_domains must be domain names")
+		}
+		if !slices.Contains(domains, d) {
+			domains = append(domains, d)
+		}
+	}
+
+	p.Name = name
+	p.Kind = kind
+	p.Issuer = issuer
+	p.ClientID = clientID
+	p.Scopes = scopes
+	p.EmailClaim = emailClaim
+	p.NameClaim = nameClaim
+	p.RoleClaim = roleClaim
+	p.RoleMapping = mapping
+	p.AllowedDomains = domains
+	p.TrustEmailClaim = input.TrustEmailClaim
+	p.JITProvisioning = input.JITProvisioning
+	p.DefaultRole = defaultRole
+	if input.Enabled != nil {
+		p.Enabled = *input.Enabled
+	}
+	return nil
+}
+
+// validIssuer accepts https URLs; plain http only for local development
+func validIssuer(issuer string) bool {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return false
+	}
+	host := u.Hostname()
+	return u.Scheme == "https" || (u.Scheme == "http" && (host == "localhost" || host == "127.0.0.1"))
+}
+
+func provisionableRole(role string) bool {
+	return role == string(user.RoleAdmin) || role == string(user.RoleMember) || role == string(user.RoleViewer)
+}
+
+// roleRank orders the provisionable roles by privilege
+var roleRank = map[string]int{string(user.RoleViewer): 1, string(user.RoleMember): 2, string(user.RoleAdmin): 3}
+
+// MapSSOIdentity maps the claims of a verified ID token to a user. The
+// email must be verified by the provider, unless the provider trusts its
+// email claim, and belong to one of the allowed domains if there are any.
+// The role is the most privileged one the role claim maps to, else the
+// default role.
+func MapSSOIdentity(p *SSOProvider, claims IDClaims) (*SSOIdentity, error) {
+	id := &SSOIdentity{Subject: claims.String("sub")}
+	if id.Subject == "" {
+		return nil, fmt.Errorf("%w: subject missing", ErrInvalidIDToken)
+	}
+
+	email := claims.String(p.EmailClaim)
+	trusted := p.TrustEmailClaim && email != ""
+	if email == "" && p.EmailClaim != "email" {
+		email = claims.String("email")
+	}
+	id.Email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndexByte(id.Email, '@')
+	if at <= 0 || at == len(id.Email)-1 {
+		return nil, ErrSSONoEmail
+	}
+	if !trusted && !claims.Bool("email_verified") {
+		return nil, ErrSSOEmailUnverified
+	}
+	if len(p.AllowedDomains) > 0 && !slices.Contains(p.AllowedDomains, id.Email[at+1:]) {
+		return nil, ErrSSODomainNotAllowed
+	}
+
+	id.Name = strings.TrimSpace(claims.String(p.NameClaim))
+	if id.Name == "" {
+		id.Name = strings.TrimSpace(claims.String("given_name") + " " + claims.String("family_name"))
+	}
+	if id.Name == "" {
+		id.Name = id.Email[:at]
+	}
+
+	role := p.DefaultRole
+	if p.RoleClaim != nil {
+		best := 0
+		for _, value := range claims.Strings(*p.RoleClaim) {
+			if mapped, ok := p.RoleMapping[value]; ok && roleRank[mapped] > best {
+				role, best = mapped, roleRank[mapped]
+			}
+		}
+	}
+	id.Role = user.Role(role)
+	return id, nil
+}
+
+// SSOService manages the SSO providers of tenants and signs users in with
+// them
+type SSOService struct {
+	repo        *SSORepository
+	users       *user.Service
+	tenants     *tenant.Service
+	key         []byte
+	states      SSOStateStore
+	redirectURL string
+
+	mu      sync.Mutex
+	clients map[uuid.UUID]*ssoClient
+}
+
+// ssoClient is the OIDC client of a provider as last updated
+type ssoClient struct {
+	updatedAt time.Time
+	client    *OIDCClient
+}
+
+// NewSSOService creates a new SSO service. Client secrets are encrypted
+// with key; redirectBaseURL is the public base URL of the API the identity
+// providers redirect back to.
+func NewSSOService(repo *SSORepository, users *user.Service, tenants *tenant.Service, key []byte, states SSOStateStore, redirectBaseURL string) *SSOService {
+	return &SSOService{
+		repo:        repo,
+		users:       users,
+		tenants:     tenants,
+		key:         key,
+		states:      states,
+		redirectURL: strings.TrimSuffix(redirectBaseURL, "/") + "/api/v1/auth/sso/callback",
+		clients:     make(map[uuid.UUID]*ssoClient),
+	}
+}
+
+// RedirectURL returns the callback URL to register with identity providers
+func (s *SSOService) RedirectURL() string {
+	return s.redirectURL
+}
+
+// ListProviders returns the SSO providers of a tenant
+func (s *SSOService) ListProviders(ctx context.Context, tenantID uuid.UUID) ([]*SSOProvider, error) {
+	return s.repo.List(ctx, tenantID)
+}
+
+// GetProvider returns an SSO provider of a tenant
+func (s *SSOService) GetProvider(ctx context.Context, tenantID, id uuid.UUID) (*SSOProvider, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// CreateProvider adds an SSO provider to a tenant
+func (s *SSOService) CreateProvider(ctx context.Context, tenantID uuid.UUID, input *SSOProviderInput) (*SSOProvider, error) {
+	p := &SSOProvider{TenantID: tenantID, Enabled: true}
+	if err := ApplySSOProviderInput(p, input); err != nil {
+		return nil, err
+	}
+	if err := s.setSecret(p, input.ClientSecret); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// UpdateProvider replaces an SSO provider of a tenant
+func (s *SSOService) UpdateProvider(ctx context.Context, tenantID, id uuid.UUID, input *SSOProviderInput) (*SSOProvider, error) {
+	p, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplySSOProviderInput(p, input); err != nil {
+		return nil, err
+	}
+	if input.ClientSecret != nil {
+		if err := s.setSecret(p, input.ClientSecret); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// DeleteProvider removes an SSO provider with the identities linked to it.
+// Users keep their accounts; those without a password can no longer sign
+// in until they reset one.
+func (s *SSOService) DeleteProvider(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+func (s *SSOService) setSecret(p *SSOProvider, secret *string) error {
+	p.clientSecret, p.HasClientSecret = nil, false
+	if secret == nil || *secret == "" {
+		return nil
+	}
+	encrypted, err := crypto.Encrypt([]byte(*secret), s.key)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt client secret: %w", err)
+	}
+	p.clientSecret, p.HasClientSecret = encrypted, true
+	return nil
+}
+
+// LoginOptions returns the enabled providers of the tenant with the slug
+func (s *SSOService) LoginOptions(ctx context.Context, tenantSlug string) ([]SSOLoginOption, error) {
+	t, err := s.tenants.GetBySlug(ctx, tenantSlug)
+	if err != nil {
+		if errors.Is(err, tenant.ErrTenantNotFound) {
+			return nil, ErrSSOProviderNotFound
+		}
+		return nil, err
+	}
+	providers, err := s.repo.List(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	options := []SSOLoginOption{}
+	for _, p := range providers {
+		if p.Enabled {
+			options = append(options, SSOLoginOption{ID: p.ID, Name: p.Name, Kind: p.Kind})
+		}
+	}
+	return options, nil
+}
+
+// StartLogin starts a login with a provider of the tenant with the slug and
+// returns the URL of the provider's login page
+func (s *SSOService) StartLogin(ctx context.Context, tenantSlug string, providerID uuid.UUID) (string, error) {
+	t, err := s.tenants.GetBySlug(ctx, tenantSlug)
+	if err != nil {
+		if errors.Is(err, tenant.ErrTenantNotFound) {
+			return "", ErrSSOProviderNotFound
+		}
+		return "", err
+	}
+	p, err := s.repo.Get(ctx, t.ID, providerID)
+	if err != nil {
+		return "", err
+	}
+	if !p.Enabled {
+		return "", ErrSSOProviderNotFound
+	}
+	client, err := s.client(p)
+	if err != nil {
+		return "", err
+	}
+
+	authz, err := client.Authorize(ctx)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(ssoState{ProviderID: p.ID, Nonce: authz.Nonce, Verifier: authz.Verifier})
+	if err != nil {
+		return "", err
+	}
+	if err := s.states.Set(ctx, "state:"+authz.State, data, ssoStateTTL); err != nil {
+		return "", fmt.Errorf("failed to store SSO state: %w", err)
+	}
+	return authz.URL, nil
+}
+
+// CompleteLogin redeems the authorization code the provider redirected
+// back with and returns the user it signs in. Identities are linked on
+// first login to the user of the tenant with the same email if an admin
+// approved the link; unknown users are provisioned if the provider allows
+// it.
+func (s *SSOService) CompleteLogin(ctx context.Context, state, code string) (*user.User, *SSOProvider, error) {
+	data, ok := s.states.Get(ctx, "state:"+state)
+	if !ok || state == "" {
+		return nil, nil, ErrSSOState
+	}
+	_ = s.states.Delete(ctx, "state:"+state)
+	var st ssoState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, nil, ErrSSOState
+	}
+
+	p, err := s.repo.GetByID(ctx, st.ProviderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !p.Enabled {
+		return nil, nil, ErrSSOProviderNotFound
+	}
+	client, err := s.client(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	claims, err := client.Exchange(ctx, code, st.Verifier, st.Nonce)
+	if err != nil {
+		return nil, p, err
+	}
+
+	u, err := s.resolveUser(ctx, p, claims)
+	if err != nil {
+		return nil, p, err
+	}
+	if !u.IsActive {
+		return nil, p, ErrSSOAccountInactive
+	}
+	return u, p, nil
+}
+
+// resolveUser finds or provisions the user of the identity
+func (s *SSOService) resolveUser(ctx context.Context, p *SSOProvider, claims IDClaims) (*user.User, error) {
+	subject := claims.String("sub")
+	userID, err := s.repo.LinkedUser(ctx, p.ID, subject)
+	if err == nil {
+		u, err := s.users.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if u.TenantID != p.TenantID {
+			return nil, ErrSSONoAccount
+		}
+		_ = s.repo.TouchIdentity(ctx, p.ID, subject)
+		return u, nil
+	}
+	if !errors.Is(err, ErrSSONoAccount) {
+		return nil, err
+	}
+
+	id, err := MapSSOIdentity(p, claims)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.GetByEmail(ctx, p.TenantID, id.Email)
+	switch {
+	case errors.Is(err, user.ErrUserNotFound):
+		if !p.JITProvisioning {
+			return nil, ErrSSONoAccount
+		}
+		u, err = s.users.CreateSSOUser(ctx, p.TenantID, id.Email, id.Name, id.Role, id.Subject)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.LinkIdentity(ctx, p.ID, id.Subject, u.ID, id.Email); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		// An existing account, possibly with a password, is taken over
+		// by the identity only with an admin's approval
+		if err := s.repo.LinkApprovedIdentity(ctx, p.ID, id.Subject, u.ID, id.Email); err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+// ApproveLink lets the next login with the provider by the user's email
+// link an identity to the user. Only owners approve links of owners.
+func (s *SSOService) ApproveLink(ctx context.Context, tenantID, providerID, userID, approvedBy uuid.UUID, approverRole string) error {
+	if _, err := s.repo.Get(ctx, tenantID, providerID); err != nil {
+		return err
+	}
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.TenantID != tenantID {
+		return user.ErrUserNotFound
+	}
+	if u.Role == user.RoleOwner && approverRole != string(user.RoleOwner) {
+		return ErrSSOLinkForbidden
+	}
+	return s.repo.ApproveLink(ctx, providerID, userID, approvedBy)
+}
+
+// RemoveLink withdraws the link approval of a user and unlinks the
+// user's identities of the provider
+func (s *SSOService) RemoveLink(ctx context.Context, tenantID, providerID, userID uuid.UUID) error {
+	if _, err := s.repo.Get(ctx, tenantID, providerID); err != nil {
+		return err
+	}
+	return s.repo.RemoveLink(ctx, providerID, userID)
+}
+
+// ListLinks returns the identities linked with a provider and the pending
+// link approvals
+func (s *SSOService) ListLinks(ctx context.Context, tenantID, providerID uuid.UUID) ([]*SSOLink, error) {
+	if _, err := s.repo.Get(ctx, tenantID, providerID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListLinks(ctx, providerID)
+}
+
+// IssueLoginCode returns a one-time code the frontend exchanges for the
+// tokens of the user, so that no token appears in a URL
+func (s *SSOService) IssueLoginCode(ctx context.Context, userID uuid.UUID) (string, error) {
+	code, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.states.Set(ctx, "code:"+code, []byte(userID.String()), ssoLoginCodeTTL); err != nil {
+		return "", fmt.Errorf("failed to store SSO login code: %w", err)
+	}
+	return code, nil
+}
+
+// RedeemLoginCode consumes a login code and returns the user it was issued
+// for
+func (s *SSOService) RedeemLoginCode(ctx context.Context, code string) (*user.User, error) {
+	data, ok := s.states.Get(ctx, "code:"+code)
+	if !ok || code == "" {
+		return nil, ErrSSOLoginCode
+	}
+	_ = s.states.Delete(ctx, "code:"+code)
+	userID, err := uuid.Parse(string(data))
+	if err != nil {
+		return nil, ErrSSOLoginCode
+	}
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsActive {
+		return nil, ErrSSOAccountInactive
+	}
+	return u, nil
+}
+
+// client returns the OIDC client of a provider, reusing it, and its cached
+// discovery and keys, until the provider is changed
+func (s *SSOService) client(p *SSOProvider) (*OIDCClient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.clients[p.ID]; ok && c.updatedAt.Equal(p.UpdatedAt) {
+		return c.client, nil
+	}
+
+	var secret string
+	if len(p.clientSecret) > 0 {
+		plain, err := crypto.Decrypt(p.clientSecret, s.key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt client secret: %w", err)
+		}
+		secret = string(plain)
+	}
+	client := NewOIDCClient(OIDCClientConfig{
+		Issuer:       p.Issuer,
+		ClientID:     p.ClientID,
+		ClientSecret: secret,
+		RedirectURL:  s.redirectURL,
+		Scopes:       p.Scopes,
+	})
+	s.clients[p.ID] = &ssoClient{updatedAt: p.UpdatedAt, client: client}
+	return client, nil
+}
@@ -0,0 +1,451 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/audit"
+	"austrian-business-infrastructure/internal/user"
+	"austrian-business-infrastructure/pkg/cache"
+)
+
+// SSOHandler handles single sign-on and the SSO settings of tenants
+type SSOHandler struct {
+	service *SSOService
+	auth    *Handler
+	appURL  string
+	logger  *slog.Logger
+}
+
+// NewSSOHandler creates a new SSO handler. Logins are completed like
+// password logins by auth, including the second factor of users with 2FA;
+// the callback sends the browser on to appURL.
+func NewSSOHandler(service *SSOService, auth *Handler, appURL string, logger *slog.Logger) *SSOHandler {
+	return &SSOHandler{
+		service: service,
+		auth:    auth,
+		appURL:  strings.TrimSuffix(appURL, "/"),
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers the SSO routes. The login routes are public;
+// the provider settings are open to tenant admins only.
+func (h *SSOHandler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.HandleFunc("GET /api/v1/auth/sso/{tenant}/providers", h.LoginOptions)
+	router.HandleFunc("GET /api/v1/auth/sso/{tenant}/{provider}/login", h.StartLogin)
+	router.HandleFunc("GET /api/v1/auth/sso/callback", h.Callback)
+	router.HandleFunc("POST /api/v1/auth/sso/token", h.ExchangeLoginCode)
+
+	router.Handle("GET /api/v1/sso/providers", requireAuth(requireAdmin(http.HandlerFunc(h.ListProviders))))
+	router.Handle("POST /api/v1/sso/providers", requireAuth(requireAdmin(http.HandlerFunc(h.CreateProvider))))
+	router.Handle("GET /api/v1/sso/providers/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.GetProvider))))
+	router.Handle("PUT /api/v1/sso/providers/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.UpdateProvider))))
+	router.Handle("DELETE /api/v1/sso/providers/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.DeleteProvider))))
+	router.Handle("GET /api/v1/sso/providers/{id}/links", requireAuth(requireAdmin(http.HandlerFunc(h.ListLinks))))
+	router.Handle("POST /api/v1/sso/providers/{id}/links", requireAuth(requireAdmin(http.HandlerFunc(h.ApproveLink))))
+	router.Handle("DELETE /api/v1/sso/providers/{id}/links/{userId}", requireAuth(requireAdmin(http.HandlerFunc(h.RemoveLink))))
+}
+
+// LoginOptions handles GET /api/v1/auth/sso/{tenant}/providers
+func (h *SSOHandler) LoginOptions(w http.ResponseWriter, r *http.Request) {
+	options, err := h.service.LoginOptions(r.Context(), r.PathValue("tenant"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"providers": options})
+}
+
+// StartLogin handles GET /api/v1/auth/sso/{tenant}/{provider}/login
+func (h *SSOHandler) StartLogin(w http.ResponseWriter, r *http.Request) {
+	providerID, err := uuid.Parse(r.PathValue("provider"))
+	if err != nil {
+		api.BadRequest(w, "invalid provider")
+		return
+	}
+
+	authURL, err := h.service.StartLogin(r.Context(), r.PathValue("tenant"), providerID)
+	if err != nil {
+		if errors.Is(err, ErrOIDCDiscovery) {
+			h.logger.Warn("SSO provider unreachable", "provider_id", providerID, "error", err)
+			api.JSONError(w, http.StatusBadGateway, "The identity provider is not reachable", "SSO_PROVIDER_UNAVAILABLE")
+			return
+		}
+		h.writeError(w, err)
+		return
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback handles GET /api/v1/auth/sso/callback, where identity providers
+// redirect back to. It sends the browser on to the app with a one-time
+// login code, or with an error code.
+func (h *SSOHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q := r.URL.Query()
+	clientIP := h.auth.getClientIP(r)
+
+	if errParam := q.Get("error"); errParam != "" {
+		h.logger.Warn("SSO error from identity provider", "error", errParam, "description", q.Get("error_description"))
+		_ = h.service.states.Delete(ctx, "state:"+q.Get("state"))
+		h.redirectWithError(w, r, "idp_error")
+		return
+	}
+
+	u, provider, err := h.service.CompleteLogin(ctx, q.Get("state"), q.Get("code"))
+	if err != nil {
+		metadata := map[string]any{"method": "sso", "reason": ssoErrorCode(err)}
+		var tenantID *uuid.UUID
+		if provider != nil {
+			metadata["provider_id"] = provider.ID.String()
+			tenantID = &provider.TenantID
+		}
+		h.auth.logAuthEvent(ctx, audit.EventLoginFailed, nil, tenantID, clientIP, r.UserAgent(), metadata)
+		if ssoErrorCode(err) == "sso_failed" {
+			h.logger.Error("SSO login failed", "error", err)
+		}
+		api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalAuthFailed, Reason: "sso_" + ssoErrorCode(err)})
+		h.redirectWithError(w, r, ssoErrorCode(err))
+		return
+	}
+
+	code, err := h.service.IssueLoginCode(ctx, u.ID)
+	if err != nil {
+		h.logger.Error("failed to issue SSO login code", "error", err)
+		h.redirectWithError(w, r, "sso_failed")
+		return
+	}
+
+	http.Redirect(w, r, h.appURL+"/auth/sso/callback?code="+url.QueryEscape(code), http.StatusFound)
+}
+
+// ExchangeLoginCodeRequest is the request body of the login code exchange
+type ExchangeLoginCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// ExchangeLoginCode handles POST /api/v1/auth/sso/token. It answers like
+// POST /api/v1/auth/login: with the tokens, or with a 2FA challenge for
+// users with two-factor authentication.
+func (h *SSOHandler) ExchangeLoginCode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req ExchangeLoginCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	u, err := h.service.RedeemLoginCode(ctx, req.Code)
+	if err != nil {
+		if errors.Is(err, ErrSSOLoginCode) || errors.Is(err, ErrSSOAccountInactive) {
+			api.JSONError(w, http.StatusUnauthorized, err.Error(), api.ErrCodeInvalidToken)
+			return
+		}
+		h.logger.Error("failed to redeem SSO login code", "error", err)
+		api.InternalError(w)
+		return
+	}
+
+	if u.TOTPEnabled {
+		challengeToken, err := h.auth.create2FAChallenge(ctx, u)
+		if errors.Is(err, cache.ErrUnavailable) {
+			api.JSONError(w, http.StatusServiceUnavailable, "Two-factor login is temporarily unavailable", "SERVICE_UNAVAILABLE")
+			return
+		}
+		if err != nil {
+			h.logger.Error("failed to create 2FA challenge", "error", err)
+			api.InternalError(w)
+			return
+		}
+		api.JSONResponse(w, http.StatusOK, Login2FARequiredResponse{
+			RequiresTwoFactor: true,
+			ChallengeToken:    challengeToken,
+			ExpiresIn:         300,
+		})
+		return
+	}
+
+	h.auth.completeLogin(w, r, u, h.auth.getClientIP(r))
+}
+
+// ssoRequestTenant returns the tenant of the request, writing 401 if there is none
+func ssoRequestTenant(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// ListProviders handles GET /api/v1/sso/providers
+func (h *SSOHandler) ListProviders(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := ssoRequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	providers, err := h.service.ListProviders(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if providers == nil {
+		providers = []*SSOProvider{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"providers":    providers,
+		"redirect_url": h.service.RedirectURL(),
+	})
+}
+
+// CreateProvider handles POST /api/v1/sso/providers
+func (h *SSOHandler) CreateProvider(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := ssoRequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var input SSOProviderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	p, err := h.service.CreateProvider(r.Context(), tenantID, &input)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, p)
+}
+
+// GetProvider handles GET /api/v1/sso/providers/{id}
+func (h *SSOHandler) GetProvider(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := ssoRequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid id")
+		return
+	}
+
+	p, err := h.service.GetProvider(r.Context(), tenantID, id)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, p)
+}
+
+// UpdateProvider handles PUT /api/v1/sso/providers/{id}
+func (h *SSOHandler) UpdateProvider(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := ssoRequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid id")
+		return
+	}
+
+	var input SSOProviderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	p, err := h.service.UpdateProvider(r.Context(), tenantID, id, &input)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, p)
+}
+
+// DeleteProvider handles DELETE /api/v1/sso/providers/{id}
+func (h *SSOHandler) DeleteProvider(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := ssoRequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid id")
+		return
+	}
+
+	if err := h.service.DeleteProvider(r.Context(), tenantID, id); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListLinks handles GET /api/v1/sso/providers/{id}/links
+func (h *SSOHandler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := ssoRequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid id")
+		return
+	}
+
+	links, err := h.service.ListLinks(r.Context(), tenantID, id)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"links": links})
+}
+
+// ApproveLinkRequest is the request body of a link approval
+type ApproveLinkRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// ApproveLink handles POST /api/v1/sso/providers/{id}/links. The user's
+// next login with the provider links the identity to the account.
+func (h *SSOHandler) ApproveLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID, ok := ssoRequestTenant(w, r)
+	if !ok {
+		return
+	}
+	adminID, err := uuid.Parse(api.GetUserID(ctx))
+	if err != nil {
+		api.Unauthorized(w, "user not found in context")
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid id")
+		return
+	}
+
+	var req ApproveLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == uuid.Nil {
+		api.BadRequest(w, "user_id is required")
+		return
+	}
+
+	if err := h.service.ApproveLink(ctx, tenantID, id, req.UserID, adminID, api.GetUserRole(ctx)); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.auth.logAuthEvent(ctx, audit.EventSSOLinkApproved, &adminID, &tenantID, h.auth.getClientIP(r), r.UserAgent(), map[string]any{
+		"provider_id": id.String(),
+		"user_id":     req.UserID.String(),
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveLink handles DELETE /api/v1/sso/providers/{id}/links/{userId}
+func (h *SSOHandler) RemoveLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID, ok := ssoRequestTenant(w, r)
+	if !ok {
+		return
+	}
+	adminID, err := uuid.Parse(api.GetUserID(ctx))
+	if err != nil {
+		api.Unauthorized(w, "user not found in context")
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid id")
+		return
+	}
+	userID, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		api.BadRequest(w, "invalid user id")
+		return
+	}
+
+	if err := h.service.RemoveLink(ctx, tenantID, id, userID); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.auth.logAuthEvent(ctx, audit.EventSSOLinkRemoved, &adminID, &tenantID, h.auth.getClientIP(r), r.UserAgent(), map[string]any{
+		"provider_id": id.String(),
+		"user_id":     userID.String(),
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *SSOHandler) redirectWithError(w http.ResponseWriter, r *http.Request, code string) {
+	http.Redirect(w, r, h.appURL+"/auth/sso/error?error="+url.QueryEscape(code), http.StatusFound)
+}
+
+// ssoErrorCode is the error code the callback sends the browser on with
+func ssoErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrSSOState):
+		return "invalid_state"
+	case errors.Is(err, ErrSSOProviderNotFound):
+		return "provider_disabled"
+	case errors.Is(err, ErrSSONoEmail):
+		return "no_email"
+	case errors.Is(err, ErrSSOEmailUnverified):
+		return "email_unverified"
+	case errors.Is(err, ErrSSODomainNotAllowed):
+		return "domain_not_allowed"
+	case errors.Is(err, ErrSSONoAccount):
+		return "no_account"
+	case errors.Is(err, ErrSSOLinkNotApproved):
+		return "link_not_approved"
+	case errors.Is(err, ErrSSOAccountInactive):
+		return "account_inactive"
+	case errors.Is(err, ErrInvalidIDToken):
+		return "invalid_id_token"
+	default:
+		return "sso_failed"
+	}
+}
+
+func (h *SSOHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrSSOProviderNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrSSOProviderExists):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, user.ErrUserNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrSSONoAccount):
+		api.NotFound(w, "no SSO link for this user")
+	case errors.Is(err, ErrSSOLinkForbidden):
+		api.Forbidden(w, err.Error())
+	case errors.Is(err, ErrInvalidSSOProvider):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	default:
+		h.logger.Error("SSO request failed", "error", err)
+		api.InternalError(w)
+	}
+}
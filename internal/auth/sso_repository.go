@@ -0,0 +1,285 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SSORepository provides SSO provider and identity data access
+type SSORepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSSORepository creates a new SSO repository
+func NewSSORepository(pool *pgxpool.Pool) *SSORepository {
+	return &SSORepository{pool: pool}
+}
+
+const ssoProviderColumns = `id, tenant_id, name, kind, issuer, client_id, client_secret_encrypted, scopes,
+	email_claim, name_claim, role_claim, role_mapping, allowed_domains, trust_email_claim, jit_provisioning,
+	default_role, enabled, created_at, updated_at`
+
+func scanSSOProvider(row pgx.Row) (*SSOProvider, error) {
+	p := &SSOProvider{}
+	var mapping []byte
+	err := row.Scan(&p.ID, &p.TenantID, &p.Name, &p.Kind, &p.Issuer, &p.ClientID, &p.clientSecret, &p.Scopes,
+		&p.EmailClaim, &p.NameClaim, &p.RoleClaim, &mapping, &p.AllowedDomains, &p.TrustEmailClaim, &p.JITProvisioning,
+		&p.DefaultRole, &p.Enabled, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSSOProviderNotFound
+		}
+		return nil, fmt.Errorf("scan SSO provider: %w", err)
+	}
+	p.HasClientSecret = len(p.clientSecret) > 0
+	p.RoleMapping = map[string]string{}
+	if err := json.Unmarshal(mapping, &p.RoleMapping); err != nil {
+		return nil, fmt.Errorf("decode role mapping: %w", err)
+	}
+	return p, nil
+}
+
+func isSSOUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// Create inserts an SSO provider
+func (r *SSORepository) Create(ctx context.Context, p *SSOProvider) error {
+	mapping, err := json.Marshal(p.RoleMapping)
+	if err != nil {
+		return err
+	}
+	err = r.pool.QueryRow(ctx, `
+		INSERT INTO tenant_sso_providers (tenant_id, name, kind, issuer, client_id, client_secret_encrypted, scopes,
+			email_claim, name_claim, role_claim, role_mapping, allowed_domains, jit_provisioning, default_role, enabled,
+			trust_email_claim)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id, created_at, updated_at
+	`, p.TenantID, p.Name, p.Kind, p.Issuer, p.ClientID, p.clientSecret, p.Scopes,
+		p.EmailClaim, p.NameClaim, p.RoleClaim, mapping, p.AllowedDomains, p.JITProvisioning, p.DefaultRole, p.Enabled,
+		p.TrustEmailClaim,
+	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if isSSOUniqueViolation(err) {
+			return ErrSSOProviderExists
+		}
+		return fmt.Errorf("create SSO provider: %w", err)
+	}
+	return nil
+}
+
+// Get returns an SSO provider of a tenant
+func (r *SSORepository) Get(ctx context.Context, tenantID, id uuid.UUID) (*SSOProvider, error) {
+	return scanSSOProvider(r.pool.QueryRow(ctx, `
+		SELECT `+ssoProviderColumns+` FROM tenant_sso_providers WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id))
+}
+
+// GetByID returns an SSO provider of any tenant
+func (r *SSORepository) GetByID(ctx context.Context, id uuid.UUID) (*SSOProvider, error) {
+	return scanSSOProvider(r.pool.QueryRow(ctx, `
+		SELECT `+ssoProviderColumns+` FROM tenant_sso_providers WHERE id = $1
+	`, id))
+}
+
+// List returns the SSO providers of a tenant, by name
+func (r *SSORepository) List(ctx context.Context, tenantID uuid.UUID) ([]*SSOProvider, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+ssoProviderColumns+` FROM tenant_sso_providers WHERE tenant_id = $1 ORDER BY lower(name)
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list SSO providers: %w", err)
+	}
+	defer rows.Close()
+
+	var providers []*SSOProvider
+	for rows.Next() {
+		p, err := scanSSOProvider(rows)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, p)
+	}
+	return providers, rows.Err()
+}
+
+// Update replaces an SSO provider
+func (r *SSORepository) Update(ctx context.Context, p *SSOProvider) error {
+	mapping, err := json.Marshal(p.RoleMapping)
+	if err != nil {
+		return err
+	}
+	err = r.pool.QueryRow(ctx, `
+		UPDATE tenant_sso_providers SET name = $3, kind = $4, issuer = $5, client_id = $6,
+			client_secret_encrypted = $7, scopes = $8, email_claim = $9, name_claim = $10, role_claim = $11,
+			role_mapping = $12, allowed_domains = $13, jit_provisioning = $14, default_role = $15, enabled = $16,
+			trust_email_claim = $17, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING updated_at
+	`, p.TenantID, p.ID, p.Name, p.Kind, p.Issuer, p.ClientID, p.clientSecret, p.Scopes,
+		p.EmailClaim, p.NameClaim, p.RoleClaim, mapping, p.AllowedDomains, p.JITProvisioning, p.DefaultRole, p.Enabled,
+		p.TrustEmailClaim,
+	).Scan(&p.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrSSOProviderNotFound
+		}
+		if isSSOUniqueViolation(err) {
+			return ErrSSOProviderExists
+		}
+		return fmt.Errorf("update SSO provider: %w", err)
+	}
+	return nil
+}
+
+// Delete removes an SSO provider with its linked identities
+func (r *SSORepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM tenant_sso_providers WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("delete SSO provider: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSSOProviderNotFound
+	}
+	return nil
+}
+
+// LinkedUser returns the user an identity is linked to, ErrSSONoAccount if
+// it is not linked
+func (r *SSORepository) LinkedUser(ctx context.Context, providerID uuid.UUID, subject string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		SELECT user_id FROM user_sso_identities WHERE provider_id = $1 AND subject = $2
+	`, providerID, subject).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrSSONoAccount
+		}
+		return uuid.Nil, fmt.Errorf("get SSO identity: %w", err)
+	}
+	return userID, nil
+}
+
+// LinkIdentity links an identity to a user
+func (r *SSORepository) LinkIdentity(ctx context.Context, providerID uuid.UUID, subject string, userID uuid.UUID, email string) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO user_sso_identities (provider_id, subject, user_id, email, last_login_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (provider_id, subject) DO UPDATE SET user_id = EXCLUDED.user_id, email = EXCLUDED.email, last_login_at = NOW()
+	`, providerID, subject, userID, email)
+	if err != nil {
+		return fmt.Errorf("link SSO identity: %w", err)
+	}
+	return nil
+}
+
+// TouchIdentity records a login with an identity
+func (r *SSORepository) TouchIdentity(ctx context.Context, providerID uuid.UUID, subject string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE user_sso_identities SET last_login_at = NOW() WHERE provider_id = $1 AND subject = $2
+	`, providerID, subject)
+	return err
+}
+
+// LinkApprovedIdentity links an identity to an existing user, using up the
+// admin's approval to link one. Without an approval it returns
+// ErrSSOLinkNotApproved.
+func (r *SSORepository) LinkApprovedIdentity(ctx context.Context, providerID uuid.UUID, subject string, userID uuid.UUID, email string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		DELETE FROM sso_link_approvals WHERE provider_id = $1 AND user_id = $2
+	`, providerID, userID)
+	if err != nil {
+		return fmt.Errorf("use SSO link approval: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSSOLinkNotApproved
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO user_sso_identities (provider_id, subject, user_id, email, last_login_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`, providerID, subject, userID, email)
+	if err != nil {
+		return fmt.Errorf("link SSO identity: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// ApproveLink approves linking an identity of the provider to a user
+func (r *SSORepository) ApproveLink(ctx context.Context, providerID, userID, approvedBy uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO sso_link_approvals (provider_id, user_id, approved_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (provider_id, user_id) DO UPDATE SET approved_by = EXCLUDED.approved_by, created_at = NOW()
+	`, providerID, userID, approvedBy)
+	if err != nil {
+		return fmt.Errorf("approve SSO link: %w", err)
+	}
+	return nil
+}
+
+// RemoveLink deletes the link approval and the linked identities of a
+// user with the provider
+func (r *SSORepository) RemoveLink(ctx context.Context, providerID, userID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	approvals, err := tx.Exec(ctx, `DELETE FROM sso_link_approvals WHERE provider_id = $1 AND user_id = $2`, providerID, userID)
+	if err != nil {
+		return fmt.Errorf("delete SSO link approval: %w", err)
+	}
+	identities, err := tx.Exec(ctx, `DELETE FROM user_sso_identities WHERE provider_id = $1 AND user_id = $2`, providerID, userID)
+	if err != nil {
+		return fmt.Errorf("unlink SSO identities: %w", err)
+	}
+	if approvals.RowsAffected()+identities.RowsAffected() == 0 {
+		return ErrSSONoAccount
+	}
+	return tx.Commit(ctx)
+}
+
+// ListLinks returns the linked identities of a provider and the pending
+// link approvals, by email
+func (r *SSORepository) ListLinks(ctx context.Context, providerID uuid.UUID) ([]*SSOLink, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT i.user_id, u.email, TRUE, i.subject, NULL::uuid, i.created_at, i.last_login_at
+		FROM user_sso_identities i JOIN users u ON u.id = i.user_id
+		WHERE i.provider_id = $1
+		UNION ALL
+		SELECT a.user_id, u.email, FALSE, NULL, a.approved_by, a.created_at, NULL
+		FROM sso_link_approvals a JOIN users u ON u.id = a.user_id
+		WHERE a.provider_id = $1
+		ORDER BY 2, 3 DESC
+	`, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("list SSO links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []*SSOLink{}
+	for rows.Next() {
+		l := &SSOLink{}
+		if err := rows.Scan(&l.UserID, &l.Email, &l.Linked, &l.Subject, &l.ApprovedBy, &l.CreatedAt, &l.LastLoginAt); err != nil {
+			return nil, fmt.Errorf("scan SSO link: %w", err)
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
@@ -0,0 +1,196 @@
+// Package backfill runs the data part of large schema changes online,
+// next to the migrations in /migrations. A change that cannot be applied in
+// one migration without locking busy tables, such as moving amounts to a new
+// money type, is rolled out in steps:
+//
+//  1. a migration adds the new column or table next to the old one
+//  2. dual-write is switched on, so the application writes both
+//  3. the backfill copies existing rows in small batches in the background
+//  4. verification queries confirm that old and new data agree
+//  5. cutover switches reads to the new data; rollback switches them back
+//  6. complete ends writes to the old data; a later migration drops it
+//
+// Backfills are defined in code (see Registered). Their progress and flags
+// are stored in schema_backfills, so every instance sees the same state and
+// a restarted instance resumes where the last batch ended.
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrUnknownBackfill    = errors.New("unknown backfill")
+	ErrInvalidPhase       = errors.New("action not allowed in this phase")
+	ErrDualWriteRequired  = errors.New("dual-write must be enabled before the backfill starts")
+	ErrDualWriteSettling  = errors.New("dual-write was enabled too recently for all instances to pick it up")
+	ErrVerificationFailed = errors.New("verification failed")
+)
+
+// Phase is the rollout step of a backfill
+type Phase string
+
+const (
+	PhasePending    Phase = "pending"
+	PhaseRunning    Phase = "running"
+	PhasePaused     Phase = "paused"
+	PhaseFailed     Phase = "failed"
+	PhaseBackfilled Phase = "backfilled"
+	PhaseCutOver    Phase = "cut_over"
+	PhaseCompleted  Phase = "completed"
+)
+
+// BatchFunc processes up to limit rows following cursor within tx. It
+// returns the cursor of the last processed row and the number of rows; fewer
+// than limit rows ends the backfill. The cursor is empty for the first batch.
+type BatchFunc func(ctx context.Context, tx pgx.Tx, cursor string, limit int) (next string, n int, err error)
+
+// Check is a verification query run before cutover. The query returns the
+// number of rows violating the check; zero passes.
+type Check struct {
+	Name  string
+	Query string
+}
+
+// Definition describes one backfill
+type Definition struct {
+	// Name identifies the backfill in the API and in the flag lookups of the
+	// dual-write shims, e.g. "invoice_amount_cents"
+	Name        string
+	Description string
+	// Count returns the number of rows to process; it is only used for the
+	// progress report and may be empty
+	Count  string
+	Batch  BatchFunc
+	Checks []Check
+}
+
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,99}$`)
+
+func (d *Definition) validate() error {
+	if !namePattern.MatchString(d.Name) {
+		return fmt.Errorf("invalid backfill name %q", d.Name)
+	}
+	if d.Batch == nil {
+		return fmt.Errorf("backfill %s has no batch function", d.Name)
+	}
+	for _, c := range d.Checks {
+		if c.Name == "" || c.Query == "" {
+			return fmt.Errorf("backfill %s has an incomplete check", d.Name)
+		}
+	}
+	return nil
+}
+
+// SQLBatch returns a BatchFunc for a statement that takes the cursor as $1
+// and the batch size as $2 and returns the key of every processed row as
+// text. Keys must sort as strings in the order the statement walks them,
+// which holds for lowercase UUIDs:
+//
+//	UPDATE invoices SET total_cents = ROUND(total * 100)
+//	WHERE id IN (
+//		SELECT id FROM invoices
+//		WHERE $1 = '' OR id > $1::uuid
+//		ORDER BY id LIMIT $2
+//	)
+//	RETURNING id::text
+func SQLBatch(query string) BatchFunc {
+	return func(ctx context.Context, tx pgx.Tx, cursor string, limit int) (string, int, error) {
+		rows, err := tx.Query(ctx, query, cursor, limit)
+		if err != nil {
+			return "", 0, err
+		}
+		defer rows.Close()
+
+		next, n := cursor, 0
+		for rows.Next() {
+			var key string
+			if err := rows.Scan(&key); err != nil {
+				return "", 0, err
+			}
+			if key > next {
+				next = key
+			}
+			n++
+		}
+		return next, n, rows.Err()
+	}
+}
+
+// Flags tell the dual-write shims which side of a schema change to use
+type Flags struct {
+	WriteOld bool `json:"write_old"`
+	WriteNew bool `json:"write_new"`
+	ReadNew  bool `json:"read_new"`
+}
+
+// PhaseFlags returns the flags of a backfill. Reads move to the new data at
+// cutover; writes to the old data stop once the backfill is completed.
+func PhaseFlags(phase Phase, dualWrite bool) Flags {
+	switch phase {
+	case PhaseCompleted:
+		return Flags{WriteNew: true, ReadNew: true}
+	case PhaseCutOver:
+		return Flags{WriteOld: true, WriteNew: true, ReadNew: true}
+	}
+	return Flags{WriteOld: true, WriteNew: dualWrite}
+}
+
+// CheckResult is the outcome of one verification query
+type CheckResult struct {
+	Name       string `json:"name"`
+	Violations int64  `json:"violations"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Verification is the outcome of all checks of a backfill
+type Verification struct {
+	Passed bool          `json:"passed"`
+	Checks []CheckResult `json:"checks"`
+	RanAt  time.Time     `json:"ran_at"`
+}
+
+// State is the stored progress of a backfill
+type State struct {
+	Name           string        `json:"name"`
+	Description    string        `json:"description,omitempty"`
+	Phase          Phase         `json:"phase"`
+	DualWrite      bool          `json:"dual_write"`
+	DualWriteSince *time.Time    `json:"dual_write_since,omitempty"`
+	Flags          Flags         `json:"flags"`
+	Cursor         string        `json:"cursor,omitempty"`
+	RowsDone       int64         `json:"rows_done"`
+	RowsTotal      *int64        `json:"rows_total,omitempty"`
+	Percent        *float64      `json:"percent,omitempty"`
+	Batches        int           `json:"batches"`
+	StartedAt      *time.Time    `json:"started_at,omitempty"`
+	LastBatchAt    *time.Time    `json:"last_batch_at,omitempty"`
+	BackfilledAt   *time.Time    `json:"backfilled_at,omitempty"`
+	Verification   *Verification `json:"verification,omitempty"`
+	CutOverAt      *time.Time    `json:"cut_over_at,omitempty"`
+	CompletedAt    *time.Time    `json:"completed_at,omitempty"`
+	Error          string        `json:"error,omitempty"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// progress fills the derived fields of a state
+func (st *State) progress() {
+	st.Flags = PhaseFlags(st.Phase, st.DualWrite)
+	st.Percent = nil
+	switch {
+	case st.Phase == PhaseBackfilled || st.Phase == PhaseCutOver || st.Phase == PhaseCompleted:
+		full := 100.0
+		st.Percent = &full
+	case st.RowsTotal != nil && *st.RowsTotal > 0:
+		// The total is counted at the start; rows written since may push
+		// the count past it
+		p := min(float64(st.RowsDone)/float64(*st.RowsTotal)*100, 99.9)
+		st.Percent = &p
+	}
+}
@@ -0,0 +1,49 @@
+package backfill
+
+// DocumentListSummaries precomputes the analysis badges of the documents
+// list (migration 057). Its dual-write shims are the triggers on the
+// analysis tables, which read the flags from schema_backfills directly; the
+// documents list switches to the summaries at cutover.
+const DocumentListSummaries = "document_list_summaries"
+
+// Registered returns the backfills of this build. A backfill stays
+// registered until the migration dropping its old data has shipped, since
+// the dual-write shims look up its flags by name until then.
+func Registered() []Definition {
+	return []Definition{
+		{
+			Name:        DocumentListSummaries,
+			Description: "Precompute the analysis badges of the documents list",
+			Count:       `SELECT COUNT(*) FROM documents`,
+			Batch: SQLBatch(`
+				WITH batch AS (
+					SELECT id FROM documents
+					WHERE $1 = '' OR id > $1::uuid
+					ORDER BY id LIMIT $2
+				), refreshed AS (
+					SELECT refresh_document_list_summaries(ARRAY(SELECT id FROM batch))
+				)
+				SELECT id::text FROM batch, refreshed`),
+			Checks: []Check{
+				{
+					Name: "every analysed document has a summary",
+					Query: `
+						SELECT COUNT(*) FROM document_analyses da
+						WHERE NOT EXISTS (
+							SELECT 1 FROM document_list_summaries s WHERE s.document_id = da.document_id
+						)`,
+				},
+				{
+					Name: "summaries match the analyses",
+					Query: `
+						SELECT COUNT(*) FROM document_list_summaries s
+						JOIN document_list_badges b ON b.document_id = s.document_id
+						WHERE (s.analysis_status, s.deadline_count, s.next_deadline, s.open_action_count,
+								s.top_action_id, s.top_action_title, s.top_action_priority, s.top_action_due_date)
+							IS DISTINCT FROM (b.analysis_status, b.deadline_count, b.next_deadline, b.open_action_count,
+								b.top_action_id, b.top_action_title, b.top_action_priority, b.top_action_due_date)`,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,158 @@
+package backfill
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler serves the backfill admin API. Backfills act on the whole
+// platform, so the API is authenticated with the maintenance token like the
+// backups.
+type Handler struct {
+	service *Service
+	token   string
+	logger  *slog.Logger
+}
+
+// NewHandler creates a new backfill admin handler
+func NewHandler(service *Service, token string, logger *slog.Logger) *Handler {
+	return &Handler{
+		service: service,
+		token:   token,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers the backfill admin routes
+func (h *Handler) RegisterRoutes(router *api.Router) {
+	router.Handle("GET /api/v1/maintenance/backfills", h.requireToken(h.List))
+	router.Handle("GET /api/v1/maintenance/backfills/{name}", h.requireToken(h.Get))
+	router.Handle("PUT /api/v1/maintenance/backfills/{name}/dual-write", h.requireToken(h.SetDualWrite))
+	router.Handle("POST /api/v1/maintenance/backfills/{name}/start", h.requireToken(h.Start))
+	router.Handle("POST /api/v1/maintenance/backfills/{name}/pause", h.requireToken(h.Pause))
+	router.Handle("POST /api/v1/maintenance/backfills/{name}/verify", h.requireToken(h.Verify))
+	router.Handle("POST /api/v1/maintenance/backfills/{name}/cutover", h.requireToken(h.Cutover))
+	router.Handle("POST /api/v1/maintenance/backfills/{name}/rollback", h.requireToken(h.Rollback))
+	router.Handle("POST /api/v1/maintenance/backfills/{name}/complete", h.requireToken(h.Complete))
+}
+
+func (h *Handler) requireToken(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !api.BearerTokenMatches(r, h.token) {
+			api.JSONError(w, http.StatusUnauthorized, "Invalid maintenance token", api.ErrCodeUnauthorized)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// List handles GET /api/v1/maintenance/backfills
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	states, err := h.service.List(r.Context())
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	api.JSONResponse(w, http.StatusOK, map[string]any{"backfills": states})
+}
+
+// Get handles GET /api/v1/maintenance/backfills/{name}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	h.respond(w)(h.service.Get(r.Context(), r.PathValue("name")))
+}
+
+// DualWriteRequest is the body of PUT .../dual-write
+type DualWriteRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// SetDualWrite handles PUT /api/v1/maintenance/backfills/{name}/dual-write
+func (h *Handler) SetDualWrite(w http.ResponseWriter, r *http.Request) {
+	var req DualWriteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "Invalid request body")
+		return
+	}
+	if req.Enabled == nil {
+		api.BadRequest(w, "enabled is required")
+		return
+	}
+	h.respond(w)(h.service.SetDualWrite(r.Context(), r.PathValue("name"), *req.Enabled))
+}
+
+// Start handles POST /api/v1/maintenance/backfills/{name}/start
+func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
+	h.respond(w)(h.service.Start(r.Context(), r.PathValue("name")))
+}
+
+// Pause handles POST /api/v1/maintenance/backfills/{name}/pause
+func (h *Handler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.respond(w)(h.service.Pause(r.Context(), r.PathValue("name")))
+}
+
+// Verify handles POST /api/v1/maintenance/backfills/{name}/verify. A failed
+// check is a regular result, not an error.
+func (h *Handler) Verify(w http.ResponseWriter, r *http.Request) {
+	v, err := h.service.Verify(r.Context(), r.PathValue("name"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	api.JSONResponse(w, http.StatusOK, v)
+}
+
+// Cutover handles POST /api/v1/maintenance/backfills/{name}/cutover
+func (h *Handler) Cutover(w http.ResponseWriter, r *http.Request) {
+	st, v, err := h.service.Cutover(r.Context(), r.PathValue("name"))
+	if errors.Is(err, ErrVerificationFailed) {
+		details := make(map[string]string, len(v.Checks))
+		for _, c := range v.Checks {
+			if c.Error != "" {
+				details[c.Name] = c.Error
+			} else if c.Violations > 0 {
+				details[c.Name] = strconv.FormatInt(c.Violations, 10) + " rows differ"
+			}
+		}
+		api.JSONErrorWithDetails(w, http.StatusConflict, "Verification failed, reads stay on the old data", api.ErrCodeConflict, details)
+		return
+	}
+	h.respond(w)(st, err)
+}
+
+// Rollback handles POST /api/v1/maintenance/backfills/{name}/rollback
+func (h *Handler) Rollback(w http.ResponseWriter, r *http.Request) {
+	h.respond(w)(h.service.Rollback(r.Context(), r.PathValue("name")))
+}
+
+// Complete handles POST /api/v1/maintenance/backfills/{name}/complete
+func (h *Handler) Complete(w http.ResponseWriter, r *http.Request) {
+	h.respond(w)(h.service.Complete(r.Context(), r.PathValue("name")))
+}
+
+// respond writes a state or the error of an action
+func (h *Handler) respond(w http.ResponseWriter) func(*State, error) {
+	return func(st *State, err error) {
+		if err != nil {
+			h.handleError(w, err)
+			return
+		}
+		api.JSONResponse(w, http.StatusOK, st)
+	}
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownBackfill):
+		api.NotFound(w, "Backfill not found")
+	case errors.Is(err, ErrInvalidPhase), errors.Is(err, ErrDualWriteRequired), errors.Is(err, ErrDualWriteSettling):
+		api.Conflict(w, err.Error())
+	default:
+		h.logger.Error("backfill admin request failed", "error", err)
+		api.InternalError(w)
+	}
+}
@@ -0,0 +1,193 @@
+package backfill
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository stores the state of backfills. Backfills are platform-wide and
+// not tenant scoped.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new backfill repository
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+const stateColumns = `name, phase, dual_write, dual_write_since, cursor, rows_done, rows_total, batches,
+	started_at, last_batch_at, backfilled_at, verification, cut_over_at, completed_at,
+	COALESCE(error, ''), updated_at`
+
+// Ensure creates the state of backfills seen for the first time
+func (r *Repository) Ensure(ctx context.Context, names []string) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO schema_backfills (name) SELECT unnest($1::text[])
+		ON CONFLICT (name) DO NOTHING
+	`, names)
+	if err != nil {
+		return fmt.Errorf("ensure backfills: %w", err)
+	}
+	return nil
+}
+
+// List returns the state of all stored backfills
+func (r *Repository) List(ctx context.Context) ([]*State, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+stateColumns+` FROM schema_backfills ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list backfills: %w", err)
+	}
+	defer rows.Close()
+
+	var states []*State
+	for rows.Next() {
+		st, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, st)
+	}
+	return states, rows.Err()
+}
+
+// Get returns the state of one backfill
+func (r *Repository) Get(ctx context.Context, name string) (*State, error) {
+	st, err := r.scan(r.pool.QueryRow(ctx, `SELECT `+stateColumns+` FROM schema_backfills WHERE name = $1`, name))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUnknownBackfill
+	}
+	return st, err
+}
+
+// transition applies set to a backfill in one of the phases from. The
+// assignments are fixed SQL from this package; args start at $3. A backfill
+// in another phase yields ErrInvalidPhase.
+func (r *Repository) transition(ctx context.Context, name string, from []Phase, set string, args ...any) (*State, error) {
+	phases := make([]string, len(from))
+	for i, p := range from {
+		phases[i] = string(p)
+	}
+	st, err := r.scan(r.pool.QueryRow(ctx, `
+		UPDATE schema_backfills SET `+set+`, updated_at = NOW()
+		WHERE name = $1 AND phase = ANY($2)
+		RETURNING `+stateColumns, append([]any{name, phases}, args...)...))
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return st, err
+	}
+	current, err := r.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: %s is %s", ErrInvalidPhase, name, current.Phase)
+}
+
+// SaveVerification stores the outcome of the checks
+func (r *Repository) SaveVerification(ctx context.Context, name string, v *Verification) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, `
+		UPDATE schema_backfills SET verification = $2, verified_at = $3, updated_at = NOW()
+		WHERE name = $1
+	`, name, data, v.RanAt)
+	if err != nil {
+		return fmt.Errorf("save verification: %w", err)
+	}
+	return nil
+}
+
+// Fail stops a running backfill after a failed batch. It keeps the cursor so
+// that start resumes after the last committed batch.
+func (r *Repository) Fail(ctx context.Context, name, reason string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE schema_backfills SET phase = $2, error = $3, updated_at = NOW()
+		WHERE name = $1 AND phase = $4
+	`, name, PhaseFailed, reason, PhaseRunning)
+	if err != nil {
+		return fmt.Errorf("fail backfill: %w", err)
+	}
+	return nil
+}
+
+// step runs one batch of a running backfill in a transaction that holds the
+// lock on its state and records the new cursor with the batch. It reports
+// false when the backfill is not running or another instance holds the lock.
+func (r *Repository) step(ctx context.Context, name string, batch func(tx pgx.Tx, cursor string) (next string, n int, done bool, err error)) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var cursor string
+	err = tx.QueryRow(ctx, `
+		SELECT cursor FROM schema_backfills
+		WHERE name = $1 AND phase = $2
+		FOR UPDATE SKIP LOCKED
+	`, name, PhaseRunning).Scan(&cursor)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("lock backfill: %w", err)
+	}
+
+	next, n, done, err := batch(tx, cursor)
+	if err != nil {
+		return false, err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE schema_backfills
+		SET cursor = $2, rows_done = rows_done + $3, batches = batches + 1, last_batch_at = NOW(),
+			phase = CASE WHEN $4 THEN $5 ELSE phase END,
+			backfilled_at = CASE WHEN $4 THEN NOW() ELSE backfilled_at END,
+			updated_at = NOW()
+		WHERE name = $1
+	`, name, next, n, done, PhaseBackfilled)
+	if err != nil {
+		return false, fmt.Errorf("advance backfill: %w", err)
+	}
+	return true, tx.Commit(ctx)
+}
+
+// countRows runs a query returning a single row count, such as the count
+// query of a definition or a check
+func (r *Repository) countRows(ctx context.Context, query string) (int64, error) {
+	var n int64
+	err := r.pool.QueryRow(ctx, query).Scan(&n)
+	return n, err
+}
+
+// SetTotal stores the number of rows to process
+func (r *Repository) SetTotal(ctx context.Context, name string, total int64) error {
+	_, err := r.pool.Exec(ctx, `UPDATE schema_backfills SET rows_total = $2 WHERE name = $1`, name, total)
+	if err != nil {
+		return fmt.Errorf("set backfill total: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) scan(row pgx.Row) (*State, error) {
+	var st State
+	var verification []byte
+	err := row.Scan(&st.Name, &st.Phase, &st.DualWrite, &st.DualWriteSince, &st.Cursor, &st.RowsDone,
+		&st.RowsTotal, &st.Batches, &st.StartedAt, &st.LastBatchAt, &st.BackfilledAt, &verification,
+		&st.CutOverAt, &st.CompletedAt, &st.Error, &st.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if len(verification) > 0 {
+		st.Verification = &Verification{}
+		if err := json.Unmarshal(verification, st.Verification); err != nil {
+			return nil, fmt.Errorf("decode verification: %w", err)
+		}
+	}
+	return &st, nil
+}
@@ -0,0 +1,473 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Config configures the backfill service
+type Config struct {
+	Definitions []Definition
+	// BatchSize is the number of rows per batch; each batch is one
+	// transaction
+	BatchSize    int
+	BatchPause   time.Duration
+	BatchTimeout time.Duration
+	// RefreshInterval is how often the flags are reloaded. Dual-write must
+	// have been on for twice this long before a backfill may start.
+	RefreshInterval time.Duration
+}
+
+// Service runs backfills and answers the flag lookups of the dual-write
+// shims. Flags are served from memory and reloaded periodically, so a change
+// made on one instance reaches all of them within the refresh interval.
+type Service struct {
+	defs   map[string]*Definition
+	names  []string
+	repo   *Repository
+	cfg    Config
+	logger *slog.Logger
+
+	mu      sync.RWMutex
+	states  map[string]*State
+	ensured bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+var errNoRepository = errors.New("backfill service has no repository")
+
+// NewService creates the service. repo may be nil when no backfill is
+// defined; every backfill then reports the flags of the old schema.
+func NewService(repo *Repository, cfg Config, logger *slog.Logger) (*Service, error) {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1000
+	}
+	if cfg.BatchTimeout <= 0 {
+		cfg.BatchTimeout = 30 * time.Second
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = 15 * time.Second
+	}
+	s := &Service{
+		defs:   make(map[string]*Definition),
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger.With("component", "backfill"),
+		states: make(map[string]*State),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for i := range cfg.Definitions {
+		def := &cfg.Definitions[i]
+		if err := def.validate(); err != nil {
+			return nil, err
+		}
+		if _, dup := s.defs[def.Name]; dup {
+			return nil, fmt.Errorf("duplicate backfill %s", def.Name)
+		}
+		s.defs[def.Name] = def
+		s.names = append(s.names, def.Name)
+	}
+
+	if repo != nil && len(s.names) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := s.Refresh(ctx); err != nil {
+			s.logger.Error("failed to load backfills", "error", err)
+		}
+		cancel()
+	}
+	go s.loop()
+	return s, nil
+}
+
+// Close stops the periodic reload
+func (s *Service) Close() {
+	close(s.stop)
+	<-s.done
+}
+
+func (s *Service) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := s.Refresh(ctx); err != nil {
+				s.logger.Error("failed to reload backfills", "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// Refresh reloads the stored state of all defined backfills
+func (s *Service) Refresh(ctx context.Context) error {
+	if s.repo == nil || len(s.names) == 0 {
+		return nil
+	}
+	s.mu.RLock()
+	ensured := s.ensured
+	s.mu.RUnlock()
+	if !ensured {
+		if err := s.repo.Ensure(ctx, s.names); err != nil {
+			return err
+		}
+	}
+
+	stored, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	states := make(map[string]*State, len(stored))
+	for _, st := range stored {
+		if _, ok := s.defs[st.Name]; ok {
+			states[st.Name] = st
+		}
+	}
+
+	s.mu.Lock()
+	s.states = states
+	s.ensured = true
+	s.mu.Unlock()
+	return nil
+}
+
+// Flags returns the flags of a backfill. Unknown backfills report the flags
+// of the old schema.
+func (s *Service) Flags(name string) Flags {
+	s.mu.RLock()
+	st := s.states[name]
+	s.mu.RUnlock()
+	if st == nil {
+		return PhaseFlags(PhasePending, false)
+	}
+	return PhaseFlags(st.Phase, st.DualWrite)
+}
+
+// List returns the current state of all defined backfills
+func (s *Service) List(ctx context.Context) ([]*State, error) {
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	states := make([]*State, 0, len(s.names))
+	for _, name := range s.names {
+		if st := s.states[name]; st != nil {
+			states = append(states, s.describe(st))
+		}
+	}
+	return states, nil
+}
+
+// Get returns the current state of one backfill
+func (s *Service) Get(ctx context.Context, name string) (*State, error) {
+	if _, err := s.definition(name); err != nil {
+		return nil, err
+	}
+	st, err := s.repo.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.describe(st), nil
+}
+
+func (s *Service) definition(name string) (*Definition, error) {
+	def, ok := s.defs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBackfill, name)
+	}
+	if s.repo == nil {
+		return nil, errNoRepository
+	}
+	return def, nil
+}
+
+// describe returns a copy of a state with the derived fields filled in
+func (s *Service) describe(st *State) *State {
+	out := *st
+	out.Description = s.defs[st.Name].Description
+	out.progress()
+	return &out
+}
+
+// changed reloads the flags after an action and returns the new state
+func (s *Service) changed(ctx context.Context, st *State, msg string, args ...any) *State {
+	s.logger.Info(msg, append([]any{"backfill", st.Name, "phase", st.Phase}, args...)...)
+	if err := s.Refresh(ctx); err != nil {
+		s.logger.Error("failed to reload backfills", "error", err)
+	}
+	return s.describe(st)
+}
+
+// SetDualWrite switches dual-write of a backfill. Turning it off before
+// cutover discards the progress, since rows written meanwhile are not
+// mirrored; a running backfill must be paused first, and after cutover it
+// must be rolled back first.
+func (s *Service) SetDualWrite(ctx context.Context, name string, enabled bool) (*State, error) {
+	if _, err := s.definition(name); err != nil {
+		return nil, err
+	}
+	if enabled {
+		st, err := s.repo.transition(ctx, name,
+			[]Phase{PhasePending, PhaseRunning, PhasePaused, PhaseFailed, PhaseBackfilled, PhaseCutOver},
+			`dual_write = TRUE, dual_write_since = CASE WHEN dual_write THEN dual_write_since ELSE NOW() END`)
+		if err != nil {
+			return nil, err
+		}
+		return s.changed(ctx, st, "backfill dual-write enabled"), nil
+	}
+
+	st, err := s.repo.transition(ctx, name,
+		[]Phase{PhasePending, PhasePaused, PhaseFailed, PhaseBackfilled},
+		`dual_write = FALSE, dual_write_since = NULL, phase = $3, cursor = '', rows_done = 0,
+			rows_total = NULL, batches = 0, started_at = NULL, last_batch_at = NULL, backfilled_at = NULL,
+			verification = NULL, verified_at = NULL, error = NULL`, PhasePending)
+	if err != nil {
+		return nil, err
+	}
+	return s.changed(ctx, st, "backfill dual-write disabled, progress reset"), nil
+}
+
+// Start starts or resumes a backfill. Dual-write must be on and must have
+// reached every instance, otherwise rows written during the backfill could
+// be missed.
+func (s *Service) Start(ctx context.Context, name string) (*State, error) {
+	def, err := s.definition(name)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.repo.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !current.DualWrite {
+		return nil, ErrDualWriteRequired
+	}
+	if settled := current.DualWriteSince.Add(2 * s.cfg.RefreshInterval); time.Now().Before(settled) {
+		return nil, fmt.Errorf("%w, retry after %s", ErrDualWriteSettling, settled.Format(time.RFC3339))
+	}
+
+	st, err := s.repo.transition(ctx, name, []Phase{PhasePending, PhasePaused, PhaseFailed},
+		`phase = $3, error = NULL, started_at = COALESCE(started_at, NOW())`, PhaseRunning)
+	if err != nil {
+		return nil, err
+	}
+	if st.RowsTotal == nil && def.Count != "" {
+		go s.countTotal(def)
+	}
+	return s.changed(ctx, st, "backfill started", "cursor", st.Cursor), nil
+}
+
+// countTotal stores the number of rows to process; counting a large table
+// may take a while, so it runs next to the first batches
+func (s *Service) countTotal(def *Definition) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	total, err := s.repo.countRows(ctx, def.Count)
+	if err == nil {
+		err = s.repo.SetTotal(ctx, def.Name, total)
+	}
+	if err != nil {
+		s.logger.Warn("failed to count backfill rows", "backfill", def.Name, "error", err)
+	}
+}
+
+// Pause stops a running backfill after the current batch
+func (s *Service) Pause(ctx context.Context, name string) (*State, error) {
+	if _, err := s.definition(name); err != nil {
+		return nil, err
+	}
+	st, err := s.repo.transition(ctx, name, []Phase{PhaseRunning}, `phase = $3`, PhasePaused)
+	if err != nil {
+		return nil, err
+	}
+	return s.changed(ctx, st, "backfill paused", "cursor", st.Cursor), nil
+}
+
+// Verify runs the checks of a finished backfill and stores the outcome
+func (s *Service) Verify(ctx context.Context, name string) (*Verification, error) {
+	def, err := s.definition(name)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.repo.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if current.Phase != PhaseBackfilled && current.Phase != PhaseCutOver {
+		return nil, fmt.Errorf("%w: %s is %s", ErrInvalidPhase, name, current.Phase)
+	}
+	return s.verify(ctx, def)
+}
+
+func (s *Service) verify(ctx context.Context, def *Definition) (*Verification, error) {
+	v := &Verification{Passed: true, Checks: make([]CheckResult, 0, len(def.Checks)), RanAt: time.Now()}
+	for _, check := range def.Checks {
+		result := CheckResult{Name: check.Name}
+		n, err := s.repo.countRows(ctx, check.Query)
+		if err != nil {
+			result.Error = err.Error()
+			v.Passed = false
+		} else {
+			result.Violations = n
+			v.Passed = v.Passed && n == 0
+		}
+		v.Checks = append(v.Checks, result)
+	}
+	if err := s.repo.SaveVerification(ctx, def.Name, v); err != nil {
+		return nil, err
+	}
+	s.logger.Info("backfill verified", "backfill", def.Name, "passed", v.Passed)
+	return v, nil
+}
+
+// Cutover switches reads to the new data. The checks are run again first;
+// when one fails the verification is returned with ErrVerificationFailed.
+func (s *Service) Cutover(ctx context.Context, name string) (*State, *Verification, error) {
+	def, err := s.definition(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := s.repo.Get(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if current.Phase != PhaseBackfilled {
+		return nil, nil, fmt.Errorf("%w: %s is %s", ErrInvalidPhase, name, current.Phase)
+	}
+	v, err := s.verify(ctx, def)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !v.Passed {
+		return nil, v, ErrVerificationFailed
+	}
+
+	st, err := s.repo.transition(ctx, name, []Phase{PhaseBackfilled}, `phase = $3, cut_over_at = NOW()`, PhaseCutOver)
+	if err != nil {
+		return nil, v, err
+	}
+	return s.changed(ctx, st, "backfill cut over"), v, nil
+}
+
+// Rollback switches reads back to the old data. Both sides are still
+// written, so a later cutover needs no new backfill.
+func (s *Service) Rollback(ctx context.Context, name string) (*State, error) {
+	if _, err := s.definition(name); err != nil {
+		return nil, err
+	}
+	st, err := s.repo.transition(ctx, name, []Phase{PhaseCutOver}, `phase = $3, cut_over_at = NULL`, PhaseBackfilled)
+	if err != nil {
+		return nil, err
+	}
+	return s.changed(ctx, st, "backfill rolled back"), nil
+}
+
+// Complete stops writes to the old data. It cannot be rolled back; the old
+// data may be dropped by a later migration.
+func (s *Service) Complete(ctx context.Context, name string) (*State, error) {
+	if _, err := s.definition(name); err != nil {
+		return nil, err
+	}
+	st, err := s.repo.transition(ctx, name, []Phase{PhaseCutOver}, `phase = $3, completed_at = NOW()`, PhaseCompleted)
+	if err != nil {
+		return nil, err
+	}
+	return s.changed(ctx, st, "backfill completed"), nil
+}
+
+// Run processes running backfills until ctx is cancelled. Each tick works
+// through batches for up to one interval. Several instances may run it;
+// every batch holds the lock on the backfill's state.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	if s.repo == nil || len(s.names) == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deadline := time.Now().Add(interval)
+			for _, name := range s.names {
+				if s.phase(name) == PhaseRunning {
+					s.drain(ctx, s.defs[name], deadline)
+				}
+			}
+		}
+	}
+}
+
+func (s *Service) phase(name string) Phase {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if st := s.states[name]; st != nil {
+		return st.Phase
+	}
+	return PhasePending
+}
+
+// drain runs batches of one backfill until it is done, paused, failed or
+// the deadline has passed
+func (s *Service) drain(ctx context.Context, def *Definition, deadline time.Time) {
+	for time.Now().Before(deadline) {
+		done, ran, err := s.batch(ctx, def)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.logger.Error("backfill batch failed", "backfill", def.Name, "error", err)
+			if err := s.repo.Fail(ctx, def.Name, err.Error()); err != nil {
+				s.logger.Error("failed to record backfill failure", "backfill", def.Name, "error", err)
+			}
+			_ = s.Refresh(ctx)
+			return
+		}
+		if done {
+			s.logger.Info("backfill finished, awaiting verification", "backfill", def.Name)
+			_ = s.Refresh(ctx)
+			return
+		}
+		if !ran {
+			// Paused or another instance holds the lock
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.cfg.BatchPause):
+		}
+	}
+}
+
+// batch runs one batch and reports whether it was the last
+func (s *Service) batch(ctx context.Context, def *Definition) (done, ran bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.BatchTimeout)
+	defer cancel()
+
+	ran, err = s.repo.step(ctx, def.Name, func(tx pgx.Tx, cursor string) (string, int, bool, error) {
+		next, n, err := def.Batch(ctx, tx, cursor, s.cfg.BatchSize)
+		done = n < s.cfg.BatchSize
+		return next, n, done, err
+	})
+	return done && ran, ran, err
+}
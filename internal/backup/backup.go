@@ -0,0 +1,154 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Backup errors
+var (
+	ErrBackupRunning  = errors.New("a backup is already running")
+	ErrBackupNotFound = errors.New("backup not found")
+	ErrNotConfigured  = errors.New("no snapshot hook configured")
+)
+
+// Backup statuses
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Restore check statuses. Drift means the restore worked but row counts
+// differ from the manifest, which is expected for tables written to by the
+// API while the snapshot was taken.
+const (
+	VerificationPending  = "pending"
+	VerificationRunning  = "running"
+	VerificationVerified = "verified"
+	VerificationDrift    = "drift"
+	VerificationFailed   = "failed"
+	VerificationSkipped  = "skipped"
+)
+
+// DefaultManifestTables are counted in every manifest
+var DefaultManifestTables = []string{
+	"tenants", "users", "accounts", "clients", "documents",
+	"invoices", "uva_submissions", "zm_submissions", "audit_logs",
+}
+
+// Manifest describes what a snapshot should contain
+type Manifest struct {
+	SchemaVersion string `json:"schema_version"`
+	// ObjectCounts holds row counts by table and, as "storage_objects",
+	// the object count the storage hook reports
+	ObjectCounts map[string]int64 `json:"object_counts"`
+}
+
+// Backup is one backup run with its manifest and restore check
+type Backup struct {
+	ID                  uuid.UUID        `json:"id"`
+	Label               string           `json:"label,omitempty"`
+	Status              string           `json:"status"`
+	SchemaVersion       string           `json:"schema_version,omitempty"`
+	ObjectCounts        map[string]int64 `json:"object_counts"`
+	DBSnapshot          string           `json:"db_snapshot,omitempty"`
+	StorageSnapshot     string           `json:"storage_snapshot,omitempty"`
+	Error               string           `json:"error,omitempty"`
+	StartedAt           time.Time        `json:"started_at"`
+	CompletedAt         *time.Time       `json:"completed_at,omitempty"`
+	VerificationStatus  string           `json:"verification_status"`
+	VerificationDetails *Verification    `json:"verification_details,omitempty"`
+	VerifiedAt          *time.Time       `json:"verified_at,omitempty"`
+}
+
+// CaptureManifest reads the schema version and the row counts of the given
+// tables. Tables that do not exist are left out.
+func CaptureManifest(ctx context.Context, pool *pgxpool.Pool, tables []string) (*Manifest, error) {
+	m := &Manifest{ObjectCounts: make(map[string]int64)}
+
+	err := pool.QueryRow(ctx, `SELECT COALESCE(MAX(version), '') FROM schema_migrations`).Scan(&m.SchemaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("read schema version: %w", err)
+	}
+
+	for _, table := range tables {
+		var exists bool
+		if err := pool.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("check table %s: %w", table, err)
+		}
+		if !exists {
+			continue
+		}
+		var count int64
+		query := "SELECT COUNT(*) FROM " + pgx.Identifier{table}.Sanitize()
+		if err := pool.QueryRow(ctx, query).Scan(&count); err != nil {
+			return nil, fmt.Errorf("count %s: %w", table, err)
+		}
+		m.ObjectCounts[table] = count
+	}
+	return m, nil
+}
+
+// CountComparison compares one count of the manifest with the restore
+type CountComparison struct {
+	Expected int64 `json:"expected"`
+	Restored int64 `json:"restored"`
+}
+
+// Verification is the outcome of a restore check
+type Verification struct {
+	Status         string                     `json:"status"`
+	SchemaExpected string                     `json:"schema_expected,omitempty"`
+	SchemaRestored string                     `json:"schema_restored,omitempty"`
+	Counts         map[string]CountComparison `json:"counts,omitempty"`
+	Missing        []string                   `json:"missing,omitempty"`
+	Error          string                     `json:"error,omitempty"`
+}
+
+// Compare checks a restored database against the manifest. The restore
+// fails if the schema version differs, a table is missing or a table that
+// had rows restored empty; differing counts are drift.
+func Compare(expected, restored *Manifest) *Verification {
+	v := &Verification{
+		Status:         VerificationVerified,
+		SchemaExpected: expected.SchemaVersion,
+		SchemaRestored: restored.SchemaVersion,
+		Counts:         make(map[string]CountComparison),
+	}
+	if expected.SchemaVersion != restored.SchemaVersion {
+		v.Status = VerificationFailed
+	}
+
+	for table, want := range expected.ObjectCounts {
+		got, ok := restored.ObjectCounts[table]
+		if !ok && table == StorageObjectsKey {
+			// Only compared when the restore hook reports it
+			continue
+		}
+		if !ok {
+			v.Missing = append(v.Missing, table)
+			v.Status = VerificationFailed
+			continue
+		}
+		v.Counts[table] = CountComparison{Expected: want, Restored: got}
+		switch {
+		case want > 0 && got == 0:
+			v.Status = VerificationFailed
+		case want != got && v.Status == VerificationVerified:
+			v.Status = VerificationDrift
+		}
+	}
+	sort.Strings(v.Missing)
+	return v
+}
+
+// StorageObjectsKey is the manifest entry of the storage object count
+const StorageObjectsKey = "storage_objects"
@@ -0,0 +1,120 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler serves the maintenance endpoints. They act on the whole platform
+// and are authenticated with the maintenance token, not a tenant session.
+type Handler struct {
+	service *Service
+	repo    *Repository
+	token   string
+	logger  *slog.Logger
+}
+
+// NewHandler creates a new maintenance handler
+func NewHandler(service *Service, repo *Repository, token string, logger *slog.Logger) *Handler {
+	return &Handler{
+		service: service,
+		repo:    repo,
+		token:   token,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers the maintenance routes
+func (h *Handler) RegisterRoutes(router *api.Router) {
+	router.Handle("POST /api/v1/maintenance/backups", h.requireToken(h.Start))
+	router.Handle("GET /api/v1/maintenance/backups", h.requireToken(h.List))
+	router.Handle("GET /api/v1/maintenance/backups/{id}", h.requireToken(h.Get))
+}
+
+func (h *Handler) requireToken(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !api.BearerTokenMatches(r, h.token) {
+			api.JSONError(w, http.StatusUnauthorized, "Invalid maintenance token", api.ErrCodeUnauthorized)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// StartRequest is the body of POST /api/v1/maintenance/backups
+type StartRequest struct {
+	Label string `json:"label"`
+}
+
+// Start handles POST /api/v1/maintenance/backups. The backup runs in the
+// background; poll GET /api/v1/maintenance/backups/{id} for its status.
+func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
+	var req StartRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			api.BadRequest(w, "Invalid request body")
+			return
+		}
+	}
+	if len(req.Label) > 255 {
+		api.BadRequest(w, "Label too long")
+		return
+	}
+
+	b, err := h.service.Start(r.Context(), req.Label)
+	switch {
+	case errors.Is(err, ErrBackupRunning):
+		api.Conflict(w, "A backup is already running")
+		return
+	case errors.Is(err, ErrNotConfigured):
+		api.JSONError(w, http.StatusServiceUnavailable, "No database snapshot hook configured", api.ErrCodeServiceUnavailable)
+		return
+	case err != nil:
+		h.logger.Error("failed to start backup", "error", err)
+		api.InternalError(w)
+		return
+	}
+	api.JSONResponse(w, http.StatusAccepted, b)
+}
+
+// List handles GET /api/v1/maintenance/backups
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	backups, err := h.repo.List(r.Context(), limit)
+	if err != nil {
+		h.logger.Error("failed to list backups", "error", err)
+		api.InternalError(w)
+		return
+	}
+	if backups == nil {
+		backups = []*Backup{}
+	}
+	api.JSONResponse(w, http.StatusOK, map[string]any{"backups": backups})
+}
+
+// Get handles GET /api/v1/maintenance/backups/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "Invalid backup ID")
+		return
+	}
+	b, err := h.repo.GetByID(r.Context(), id)
+	if errors.Is(err, ErrBackupNotFound) {
+		api.NotFound(w, "Backup not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to get backup", "error", err)
+		api.InternalError(w)
+		return
+	}
+	api.JSONResponse(w, http.StatusOK, b)
+}
@@ -0,0 +1,88 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// maxHookOutput bounds the captured output of a hook
+const maxHookOutput = 64 * 1024
+
+// HookResult is what a snapshot or restore hook reports. A hook prints it
+// as a JSON object on the last line of its output, e.g.
+// {"location":"s3://backups/2026-10-16.dump","objects":1200}; any other last
+// line is taken as the location.
+type HookResult struct {
+	Location string `json:"location"`
+	Objects  int64  `json:"objects,omitempty"`
+}
+
+// RunHook runs a hook script with the given environment added to the
+// process environment. A non-zero exit fails with the tail of stderr.
+func RunHook(ctx context.Context, path string, timeout time.Duration, env map[string]string) (*HookResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Env = os.Environ()
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	stdout := &limitedBuffer{max: maxHookOutput}
+	stderr := &limitedBuffer{max: maxHookOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Children of the hook may keep its output open after it is killed
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("hook %s timed out after %s", path, timeout)
+		}
+		return nil, fmt.Errorf("hook %s failed: %w: %s", path, err, tail(stderr.String(), 500))
+	}
+	return parseHookOutput(stdout.String()), nil
+}
+
+func parseHookOutput(out string) *HookResult {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+
+	var result HookResult
+	if strings.HasPrefix(last, "{") && json.Unmarshal([]byte(last), &result) == nil {
+		return &result
+	}
+	return &HookResult{Location: last}
+}
+
+func tail(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) > n {
+		return "..." + s[len(s)-n:]
+	}
+	return s
+}
+
+// limitedBuffer keeps the last max bytes written, which hold the result
+// line of stdout and the error message of stderr
+type limitedBuffer struct {
+	buf []byte
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.max {
+		b.buf = append(b.buf[:0], b.buf[len(b.buf)-b.max:]...)
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return string(b.buf)
+}
@@ -0,0 +1,170 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository stores backup runs. Backups are platform-wide and not tenant
+// scoped.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new backup repository
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+const backupColumns = `id, COALESCE(label, ''), status, COALESCE(schema_version, ''), object_counts,
+	COALESCE(db_snapshot, ''), COALESCE(storage_snapshot, ''), COALESCE(error, ''),
+	started_at, completed_at, verification_status, verification_details, verified_at`
+
+// Create records a running backup. Backups left running for longer than
+// staleAfter, e.g. by a crashed server, are marked failed first.
+func (r *Repository) Create(ctx context.Context, label string, staleAfter time.Duration) (*Backup, error) {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE backups SET status = $1, error = 'interrupted', completed_at = NOW()
+		WHERE status = $2 AND started_at < $3
+	`, StatusFailed, StatusRunning, time.Now().Add(-staleAfter))
+	if err != nil {
+		return nil, fmt.Errorf("fail stale backups: %w", err)
+	}
+
+	b, err := r.scan(r.pool.QueryRow(ctx, `
+		INSERT INTO backups (label) VALUES (NULLIF($1, ''))
+		RETURNING `+backupColumns, label))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrBackupRunning
+		}
+		return nil, fmt.Errorf("create backup: %w", err)
+	}
+	return b, nil
+}
+
+// Complete stores the manifest and snapshot locations of a finished backup
+func (r *Repository) Complete(ctx context.Context, b *Backup) error {
+	counts, err := json.Marshal(b.ObjectCounts)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, `
+		UPDATE backups
+		SET status = $2, schema_version = $3, object_counts = $4, db_snapshot = NULLIF($5, ''),
+			storage_snapshot = NULLIF($6, ''), verification_status = $7, completed_at = NOW()
+		WHERE id = $1
+	`, b.ID, StatusCompleted, b.SchemaVersion, counts, b.DBSnapshot, b.StorageSnapshot, b.VerificationStatus)
+	if err != nil {
+		return fmt.Errorf("complete backup: %w", err)
+	}
+	return nil
+}
+
+// Fail marks a backup as failed
+func (r *Repository) Fail(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE backups SET status = $2, error = $3, verification_status = $4, completed_at = NOW()
+		WHERE id = $1
+	`, id, StatusFailed, reason, VerificationSkipped)
+	if err != nil {
+		return fmt.Errorf("fail backup: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a backup
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*Backup, error) {
+	b, err := r.scan(r.pool.QueryRow(ctx, `SELECT `+backupColumns+` FROM backups WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrBackupNotFound
+	}
+	return b, err
+}
+
+// List returns the most recent backups
+func (r *Repository) List(ctx context.Context, limit int) ([]*Backup, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	rows, err := r.pool.Query(ctx, `SELECT `+backupColumns+` FROM backups ORDER BY started_at DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list backups: %w", err)
+	}
+	defer rows.Close()
+
+	var backups []*Backup
+	for rows.Next() {
+		b, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, b)
+	}
+	return backups, rows.Err()
+}
+
+// ClaimVerification picks the oldest completed backup awaiting a restore
+// check and marks it running. It returns nil when there is none.
+func (r *Repository) ClaimVerification(ctx context.Context) (*Backup, error) {
+	b, err := r.scan(r.pool.QueryRow(ctx, `
+		UPDATE backups SET verification_status = $1
+		WHERE id = (
+			SELECT id FROM backups
+			WHERE status = $2 AND verification_status = $3
+			ORDER BY completed_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING `+backupColumns, VerificationRunning, StatusCompleted, VerificationPending))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return b, err
+}
+
+// SaveVerification stores the outcome of a restore check
+func (r *Repository) SaveVerification(ctx context.Context, id uuid.UUID, v *Verification) error {
+	details, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, `
+		UPDATE backups SET verification_status = $2, verification_details = $3, verified_at = NOW()
+		WHERE id = $1
+	`, id, v.Status, details)
+	if err != nil {
+		return fmt.Errorf("save verification: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) scan(row pgx.Row) (*Backup, error) {
+	var b Backup
+	var counts, details []byte
+	err := row.Scan(&b.ID, &b.Label, &b.Status, &b.SchemaVersion, &counts,
+		&b.DBSnapshot, &b.StorageSnapshot, &b.Error,
+		&b.StartedAt, &b.CompletedAt, &b.VerificationStatus, &details, &b.VerifiedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(counts, &b.ObjectCounts); err != nil {
+		return nil, fmt.Errorf("decode object counts: %w", err)
+	}
+	if len(details) > 0 {
+		b.VerificationDetails = &Verification{}
+		if err := json.Unmarshal(details, b.VerificationDetails); err != nil {
+			return nil, fmt.Errorf("decode verification: %w", err)
+		}
+	}
+	return &b, nil
+}
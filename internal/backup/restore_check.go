@@ -0,0 +1,108 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RestoreChecker proves backups restorable: it picks up completed backups,
+// restores them into the scratch database with the restore hook and
+// compares the result with the manifest. It runs in the worker.
+type RestoreChecker struct {
+	repo     *Repository
+	config   Config
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+// NewRestoreChecker creates a restore checker polling for backups at the
+// given interval
+func NewRestoreChecker(repo *Repository, config Config, interval time.Duration, logger *slog.Logger) *RestoreChecker {
+	if config.HookTimeout <= 0 {
+		config.HookTimeout = time.Hour
+	}
+	if len(config.Tables) == 0 {
+		config.Tables = DefaultManifestTables
+	}
+	return &RestoreChecker{
+		repo:     repo,
+		config:   config,
+		interval: interval,
+		logger:   logger.With("component", "restore_check"),
+	}
+}
+
+// Run checks pending backups until the context is cancelled
+func (c *RestoreChecker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			b, err := c.repo.ClaimVerification(ctx)
+			if err != nil {
+				c.logger.Error("failed to claim backup for restore check", "error", err)
+				continue
+			}
+			if b == nil {
+				continue
+			}
+			c.check(ctx, b)
+		}
+	}
+}
+
+func (c *RestoreChecker) check(ctx context.Context, b *Backup) {
+	logger := c.logger.With("backup_id", b.ID)
+	logger.Info("restore check started", "db_snapshot", b.DBSnapshot)
+
+	v, err := c.verify(ctx, b)
+	if err != nil {
+		v = &Verification{Status: VerificationFailed, Error: err.Error()}
+	}
+	if err := c.repo.SaveVerification(context.Background(), b.ID, v); err != nil {
+		logger.Error("failed to record restore check", "error", err)
+		return
+	}
+
+	if v.Status == VerificationFailed {
+		logger.Error("restore check failed", "error", v.Error, "missing", v.Missing,
+			"schema_expected", v.SchemaExpected, "schema_restored", v.SchemaRestored)
+		return
+	}
+	logger.Info("restore check finished", "status", v.Status)
+}
+
+func (c *RestoreChecker) verify(ctx context.Context, b *Backup) (*Verification, error) {
+	result, err := RunHook(ctx, c.config.RestoreHook, c.config.HookTimeout, map[string]string{
+		"BACKUP_ID":               b.ID.String(),
+		"BACKUP_DB_SNAPSHOT":      b.DBSnapshot,
+		"BACKUP_STORAGE_SNAPSHOT": b.StorageSnapshot,
+		"SCRATCH_DATABASE_URL":    c.config.ScratchDatabaseURL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+
+	pool, err := pgxpool.New(ctx, c.config.ScratchDatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("connect to scratch database: %w", err)
+	}
+	defer pool.Close()
+
+	restored, err := CaptureManifest(ctx, pool, c.config.Tables)
+	if err != nil {
+		return nil, fmt.Errorf("read restored database: %w", err)
+	}
+	if result.Objects > 0 {
+		restored.ObjectCounts[StorageObjectsKey] = result.Objects
+	}
+	return Compare(&Manifest{SchemaVersion: b.SchemaVersion, ObjectCounts: b.ObjectCounts}, restored), nil
+}
@@ -0,0 +1,197 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// JobQueue is the part of the job queue a backup quiesces
+type JobQueue interface {
+	Pause(ctx context.Context, reason string, ttl time.Duration) (uuid.UUID, error)
+	Resume(ctx context.Context, pauseID uuid.UUID) error
+	RunningCount(ctx context.Context) (int64, error)
+}
+
+// Config configures backups and restore checks. Hooks are executable
+// scripts; see scripts/backup-hooks for examples.
+type Config struct {
+	DBSnapshotHook      string
+	StorageSnapshotHook string
+	RestoreHook         string
+	// ScratchDatabaseURL is the database the restore check restores into.
+	// It is overwritten by every check.
+	ScratchDatabaseURL string
+
+	HookTimeout  time.Duration
+	DrainTimeout time.Duration
+	Tables       []string
+}
+
+// Service coordinates consistent backups: it pauses the job queue, waits
+// for running jobs to finish, records the manifest, runs the snapshot hooks
+// and resumes the queue. The API stays available; only background jobs,
+// which make the bulk of writes, are held back.
+type Service struct {
+	repo   *Repository
+	pool   *pgxpool.Pool
+	queue  JobQueue
+	config Config
+	logger *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService creates a backup service
+func NewService(repo *Repository, pool *pgxpool.Pool, queue JobQueue, config Config, logger *slog.Logger) *Service {
+	if config.HookTimeout <= 0 {
+		config.HookTimeout = time.Hour
+	}
+	if config.DrainTimeout <= 0 {
+		config.DrainTimeout = 10 * time.Minute
+	}
+	if len(config.Tables) == 0 {
+		config.Tables = DefaultManifestTables
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Service{
+		repo:   repo,
+		pool:   pool,
+		queue:  queue,
+		config: config,
+		logger: logger.With("component", "backup"),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// maxDuration bounds a backup run; longer runs count as interrupted
+func (s *Service) maxDuration() time.Duration {
+	return s.config.DrainTimeout + 2*s.config.HookTimeout + 5*time.Minute
+}
+
+// Start records a backup and runs it in the background
+func (s *Service) Start(ctx context.Context, label string) (*Backup, error) {
+	if s.config.DBSnapshotHook == "" {
+		return nil, ErrNotConfigured
+	}
+	b, err := s.repo.Create(ctx, label, s.maxDuration())
+	if err != nil {
+		return nil, err
+	}
+
+	started := *b
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(b)
+	}()
+	return &started, nil
+}
+
+// Close aborts a running backup, killing its hooks, and waits until the
+// failure is recorded and the job queue resumed
+func (s *Service) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Service) run(b *Backup) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.maxDuration())
+	defer cancel()
+	logger := s.logger.With("backup_id", b.ID)
+
+	if err := s.snapshot(ctx, b, logger); err != nil {
+		logger.Error("backup failed", "error", err)
+		if err := s.repo.Fail(context.Background(), b.ID, err.Error()); err != nil {
+			logger.Error("failed to record backup failure", "error", err)
+		}
+		return
+	}
+
+	b.VerificationStatus = VerificationPending
+	if s.config.RestoreHook == "" || s.config.ScratchDatabaseURL == "" {
+		b.VerificationStatus = VerificationSkipped
+	}
+	if err := s.repo.Complete(context.Background(), b); err != nil {
+		logger.Error("failed to record backup", "error", err)
+		return
+	}
+	logger.Info("backup completed",
+		"schema_version", b.SchemaVersion,
+		"db_snapshot", b.DBSnapshot,
+		"storage_snapshot", b.StorageSnapshot)
+}
+
+func (s *Service) snapshot(ctx context.Context, b *Backup, logger *slog.Logger) error {
+	pauseID, err := s.queue.Pause(ctx, "backup "+b.ID.String(), s.maxDuration())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := s.queue.Resume(context.Background(), pauseID); err != nil {
+			logger.Error("failed to resume job queue; the pause expires on its own", "error", err)
+		}
+	}()
+
+	if err := s.drain(ctx); err != nil {
+		return err
+	}
+
+	manifest, err := CaptureManifest(ctx, s.pool, s.config.Tables)
+	if err != nil {
+		return fmt.Errorf("capture manifest: %w", err)
+	}
+	b.SchemaVersion = manifest.SchemaVersion
+	b.ObjectCounts = manifest.ObjectCounts
+
+	env := map[string]string{
+		"BACKUP_ID":         b.ID.String(),
+		"BACKUP_LABEL":      b.Label,
+		"BACKUP_STARTED_AT": b.StartedAt.UTC().Format(time.RFC3339),
+	}
+	result, err := RunHook(ctx, s.config.DBSnapshotHook, s.config.HookTimeout, env)
+	if err != nil {
+		return fmt.Errorf("database snapshot: %w", err)
+	}
+	b.DBSnapshot = result.Location
+
+	if s.config.StorageSnapshotHook != "" {
+		result, err := RunHook(ctx, s.config.StorageSnapshotHook, s.config.HookTimeout, env)
+		if err != nil {
+			return fmt.Errorf("storage snapshot: %w", err)
+		}
+		b.StorageSnapshot = result.Location
+		b.ObjectCounts[StorageObjectsKey] = result.Objects
+	}
+	return nil
+}
+
+// drain waits until no job is running
+func (s *Service) drain(ctx context.Context) error {
+	deadline := time.Now().Add(s.config.DrainTimeout)
+	for {
+		running, err := s.queue.RunningCount(ctx)
+		if err != nil {
+			return err
+		}
+		if running == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d jobs still running after %s", running, s.config.DrainTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package branding
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/backup"
+	"austrian-business-infrastructure/internal/tenant"
+)
+
+// A tenant proves control over its custom domain with a TXT record at
+// VerificationRecordPrefix.<domain> holding VerificationValuePrefix<token>.
+const (
+	VerificationRecordPrefix = "_abi-domain"
+	VerificationValuePrefix  = "abi-domain-verification="
+)
+
+// Certificate states of a custom domain
+const (
+	CertificateNone     = "none"
+	CertificatePending  = "pending"
+	CertificateOnDemand = "on_demand"
+	CertificateIssued   = "issued"
+	CertificateFailed   = "failed"
+)
+
+// hostCacheTTL bounds how long the host middleware remembers whether a host
+// is a custom domain, including hosts that are none
+const hostCacheTTL = time.Minute
+
+// maxCachedHosts bounds the host cache. Any host name can be sent, so once
+// the cache is full of unexpired entries hosts that are no custom domain are
+// no longer cached; custom domains are limited by the tenants and always are.
+const maxCachedHosts = 10000
+
+// PublicPathPrefixes are the routes a custom domain serves. Everything else
+// is only reachable on the platform's own hosts.
+var PublicPathPrefixes = []string{
+	"/api/v1/public/",
+	"/api/v1/sign/",
+	"/api/v1/sign-status/",
+}
+
+// Resolver looks up the DNS records of a custom domain; *net.Resolver
+// implements it
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
+// CertificateHook obtains and removes TLS certificates of custom domains
+type CertificateHook interface {
+	Provision(ctx context.Context, tenantID uuid.UUID, domain string) error
+	Remove(ctx context.Context, tenantID uuid.UUID, domain string) error
+}
+
+// CommandCertificateHook runs an executable with CUSTOM_DOMAIN,
+// CUSTOM_DOMAIN_ACTION (provision or remove) and TENANT_ID in its
+// environment. A non-zero exit code fails the action.
+type CommandCertificateHook struct {
+	Path    string
+	Timeout time.Duration
+}
+
+// Provision runs the hook with CUSTOM_DOMAIN_ACTION=provision
+func (h *CommandCertificateHook) Provision(ctx context.Context, tenantID uuid.UUID, domain string) error {
+	return h.run(ctx, "provision", tenantID, domain)
+}
+
+// Remove runs the hook with CUSTOM_DOMAIN_ACTION=remove
+func (h *CommandCertificateHook) Remove(ctx context.Context, tenantID uuid.UUID, domain string) error {
+	return h.run(ctx, "remove", tenantID, domain)
+}
+
+func (h *CommandCertificateHook) run(ctx context.Context, action string, tenantID uuid.UUID, domain string) error {
+	_, err := backup.RunHook(ctx, h.Path, h.Timeout, map[string]string{
+		"CUSTOM_DOMAIN":        domain,
+		"CUSTOM_DOMAIN_ACTION": action,
+		"TENANT_ID":            tenantID.String(),
+	})
+	return err
+}
+
+// DomainConfig configures the verification of custom domains
+type DomainConfig struct {
+	CNAMETarget  string          // host tenants point their domain at; optional
+	Certificates CertificateHook // nil leaves certificates to on-demand TLS
+	Resolver     Resolver        // defaults to net.DefaultResolver
+	Logger       *slog.Logger
+}
+
+// SetDomainConfig configures custom domain verification and certificates
+func (s *Service) SetDomainConfig(cfg DomainConfig) {
+	if cfg.Resolver == nil {
+		cfg.Resolver = net.DefaultResolver
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	s.domains = cfg
+}
+
+// DomainRecord is a DNS record a custom domain needs
+type DomainRecord struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Purpose  string `json:"purpose"`
+	Required bool   `json:"required"`
+	Status   string `json:"status"` // ok, missing, invalid or unchecked
+}
+
+// DomainStatus is the state of a tenant's custom domain with the DNS
+// records to publish
+type DomainStatus struct {
+	Domain            string         `json:"domain"`
+	Verified          bool           `json:"verified"`
+	VerifiedAt        *time.Time     `json:"verified_at,omitempty"`
+	CheckedAt         *time.Time     `json:"checked_at,omitempty"`
+	CheckError        string         `json:"check_error,omitempty"`
+	CertificateStatus string         `json:"certificate_status"`
+	CertificateError  string         `json:"certificate_error,omitempty"`
+	Records           []DomainRecord `json:"dns_records"`
+}
+
+// GetDomainStatus returns the state of the tenant's custom domain. The
+// record statuses are those of the last check.
+func (s *Service) GetDomainStatus(ctx context.Context, tenantID uuid.UUID) (*DomainStatus, error) {
+	state, err := s.domainState(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	txtStatus := "unchecked"
+	if state.VerifiedAt != nil {
+		txtStatus = "ok"
+	} else if state.CheckedAt != nil {
+		txtStatus = "missing"
+	}
+	return s.domainStatus(state, txtStatus, "unchecked"), nil
+}
+
+// VerifyDomain looks up the verification record of the tenant's custom
+// domain. Once it is verified, public links use the domain and its
+// certificate is provisioned.
+func (s *Service) VerifyDomain(ctx context.Context, tenantID uuid.UUID) (*DomainStatus, error) {
+	state, err := s.domainState(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	txtStatus, checkErr := s.checkVerificationRecord(ctx, state)
+	cnameStatus := s.checkCNAME(ctx, state.Domain)
+
+	var checkError *string
+	if checkErr != nil {
+		msg := checkErr.Error()
+		checkError = &msg
+	}
+	if err := s.repo.RecordDomainCheck(ctx, tenantID, txtStatus == "ok", checkError); err != nil {
+		return nil, err
+	}
+
+	if txtStatus == "ok" && (state.VerifiedAt == nil || state.CertificateStatus == CertificateNone || state.CertificateStatus == CertificateFailed) {
+		s.provisionCertificate(ctx, tenantID, state.Domain)
+	}
+	s.domainsChanged(tenantID)
+
+	if state, err = s.domainState(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	return s.domainStatus(state, txtStatus, cnameStatus), nil
+}
+
+// checkVerificationRecord reports whether the TXT record holds the token
+func (s *Service) checkVerificationRecord(ctx context.Context, state *DomainState) (string, error) {
+	name := VerificationRecordPrefix + "." + state.Domain
+	values, err := s.domains.Resolver.LookupTXT(ctx, name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return "missing", fmt.Errorf("no TXT record at %s", name)
+		}
+		return "missing", fmt.Errorf("DNS lookup of %s failed: %w", name, err)
+	}
+
+	want := VerificationValuePrefix + deref(state.VerificationToken)
+	found := false
+	for _, value := range values {
+		if strings.TrimSpace(value) == want {
+			return "ok", nil
+		}
+		if strings.HasPrefix(strings.TrimSpace(value), VerificationValuePrefix) {
+			found = true
+		}
+	}
+	if found {
+		return "invalid", fmt.Errorf("TXT record at %s holds another verification token", name)
+	}
+	return "missing", fmt.Errorf("no verification value in the TXT records at %s", name)
+}
+
+// checkCNAME reports whether the domain points at the platform. It is
+// informational: apex domains use A records or provider aliases instead.
+func (s *Service) checkCNAME(ctx context.Context, domain string) string {
+	if s.domains.CNAMETarget == "" {
+		return "unchecked"
+	}
+	cname, err := s.domains.Resolver.LookupCNAME(ctx, domain)
+	if err != nil {
+		return "missing"
+	}
+	if !strings.EqualFold(strings.TrimSuffix(cname, "."), strings.TrimSuffix(s.domains.CNAMETarget, ".")) {
+		return "invalid"
+	}
+	return "ok"
+}
+
+// provisionCertificate runs the certificate hook. Without one the TLS proxy
+// obtains the certificate on the first request, after asking TLSAllowed.
+func (s *Service) provisionCertificate(ctx context.Context, tenantID uuid.UUID, domain string) {
+	if s.domains.Certificates == nil {
+		s.setCertificateStatus(ctx, tenantID, CertificateOnDemand, nil)
+		return
+	}
+
+	s.setCertificateStatus(ctx, tenantID, CertificatePending, nil)
+	if err := s.domains.Certificates.Provision(ctx, tenantID, domain); err != nil {
+		s.domains.Logger.Error("certificate provisioning failed", "tenant_id", tenantID, "domain", domain, "error", err)
+		msg := err.Error()
+		s.setCertificateStatus(ctx, tenantID, CertificateFailed, &msg)
+		return
+	}
+	s.domains.Logger.Info("certificate provisioned", "tenant_id", tenantID, "domain", domain)
+	s.setCertificateStatus(ctx, tenantID, CertificateIssued, nil)
+}
+
+func (s *Service) setCertificateStatus(ctx context.Context, tenantID uuid.UUID, status string, certError *string) {
+	if err := s.repo.SetCertificateStatus(ctx, tenantID, status, certError); err != nil {
+		s.domains.Logger.Error("failed to store certificate status", "tenant_id", tenantID, "status", status, "error", err)
+	}
+}
+
+// domainChanged starts the verification of a new custom domain and removes
+// the certificate of the previous one
+func (s *Service) domainChanged(ctx context.Context, tenantID uuid.UUID, previous string, wasVerified bool, domain string) error {
+	var token *string
+	if domain != "" {
+		t, err := newVerificationToken()
+		if err != nil {
+			return err
+		}
+		token = &t
+	}
+	if err := s.repo.ResetDomain(ctx, tenantID, token); err != nil {
+		return err
+	}
+	s.domainsChanged(tenantID)
+
+	if previous != "" && wasVerified && s.domains.Certificates != nil {
+		if err := s.domains.Certificates.Remove(ctx, tenantID, previous); err != nil {
+			s.domains.Logger.Warn("failed to remove certificate of previous custom domain", "tenant_id", tenantID, "domain", previous, "error", err)
+		}
+	}
+	return nil
+}
+
+// domainState loads the state of the tenant's domain, giving domains set
+// before verification existed a token
+func (s *Service) domainState(ctx context.Context, tenantID uuid.UUID) (*DomainState, error) {
+	state, err := s.repo.GetDomainState(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if state.VerificationToken == nil {
+		token, err := newVerificationToken()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.SetVerificationToken(ctx, tenantID, token); err != nil {
+			return nil, err
+		}
+		return s.repo.GetDomainState(ctx, tenantID)
+	}
+	return state, nil
+}
+
+func (s *Service) domainStatus(state *DomainState, txtStatus, cnameStatus string) *DomainStatus {
+	status := &DomainStatus{
+		Domain:            state.Domain,
+		Verified:          state.VerifiedAt != nil,
+		VerifiedAt:        state.VerifiedAt,
+		CheckedAt:         state.CheckedAt,
+		CheckError:        deref(state.CheckError),
+		CertificateStatus: state.CertificateStatus,
+		CertificateError:  deref(state.CertificateError),
+		Records: []DomainRecord{{
+			Type:     "TXT",
+			Name:     VerificationRecordPrefix + "." + state.Domain,
+			Value:    VerificationValuePrefix + deref(state.VerificationToken),
+			Purpose:  "Verification: proves that the tenant controls the domain",
+			Required: true,
+			Status:   txtStatus,
+		}},
+	}
+	if s.domains.CNAMETarget != "" {
+		status.Records = append(status.Records, DomainRecord{
+			Type:    "CNAME",
+			Name:    state.Domain,
+			Value:   s.domains.CNAMETarget,
+			Purpose: "Routing: sends the domain's traffic to the platform (apex domains use an ALIAS or A record)",
+			// Required in effect, but any record reaching the platform works
+			Required: false,
+			Status:   cnameStatus,
+		})
+	}
+	return status
+}
+
+func newVerificationToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+type hostEntry struct {
+	tenantID uuid.UUID // uuid.Nil for hosts that are no custom domain
+	expires  time.Time
+}
+
+// HostTenant returns the tenant whose verified custom domain host is, or
+// uuid.Nil. Lookups are cached for a minute, see maxCachedHosts.
+func (s *Service) HostTenant(ctx context.Context, host string) (uuid.UUID, error) {
+	host = strings.TrimSuffix(strings.ToLower(hostname(host)), ".")
+	if host == "" || host == s.platformHost() || net.ParseIP(host) != nil {
+		return uuid.Nil, nil
+	}
+
+	s.hostMu.Lock()
+	entry, ok := s.hosts[host]
+	s.hostMu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.tenantID, nil
+	}
+
+	tenantID := uuid.Nil
+	branding, err := s.repo.GetByCustomDomain(ctx, host)
+	switch {
+	case err == nil:
+		tenantID = branding.TenantID
+	case !errors.Is(err, ErrBrandingNotFound):
+		return uuid.Nil, err
+	}
+
+	now := time.Now()
+	s.hostMu.Lock()
+	if len(s.hosts) >= maxCachedHosts {
+		for h, e := range s.hosts {
+			if !now.Before(e.expires) {
+				delete(s.hosts, h)
+			}
+		}
+	}
+	if tenantID != uuid.Nil || len(s.hosts) < maxCachedHosts {
+		s.hosts[host] = hostEntry{tenantID: tenantID, expires: now.Add(hostCacheTTL)}
+	}
+	s.hostMu.Unlock()
+	return tenantID, nil
+}
+
+// platformHost is the host of the platform's own public URL, which is never
+// a custom domain
+func (s *Service) platformHost() string {
+	u, err := url.Parse(s.publicURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// TLSAllowed reports whether a TLS proxy may obtain a certificate for a
+// domain: only verified custom domains qualify
+func (s *Service) TLSAllowed(ctx context.Context, domain string) (bool, error) {
+	tenantID, err := s.HostTenant(ctx, domain)
+	return tenantID != uuid.Nil, err
+}
+
+// HostMiddleware routes requests by host. Requests to a verified custom
+// domain reach only the public routes and carry the domain's tenant, so the
+// public handlers serve that tenant's links only.
+func (s *Service) HostMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID, err := s.HostTenant(r.Context(), r.Host)
+		if err != nil {
+			s.domains.Logger.Error("custom domain lookup failed", "host", r.Host, "error", err)
+			api.JSONError(w, http.StatusServiceUnavailable, "service unavailable", api.ErrCodeServiceUnavailable)
+			return
+		}
+		if tenantID == uuid.Nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !isPublicPath(r.URL.Path) {
+			api.NotFound(w, "not found")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(tenant.WithHostTenantID(r.Context(), tenantID)))
+	})
+}
+
+// domainsChanged drops cached lookups after a tenant's domain changed
+func (s *Service) domainsChanged(tenantID uuid.UUID) {
+	s.InvalidateCache(tenantID)
+	s.hostMu.Lock()
+	clear(s.hosts)
+	s.hostMu.Unlock()
+}
+
+func isPublicPath(path string) bool {
+	for _, prefix := range PublicPathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostname strips the port of a Host header
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
@@ -2,11 +2,14 @@ package branding
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"austrian-business-infrastructure/internal/api"
 	"austrian-business-infrastructure/internal/tenant"
 )
 
@@ -22,167 +25,239 @@ func NewHandler(service *Service) *Handler {
 	}
 }
 
-// StaffRoutes returns routes for staff managing branding
-func (h *Handler) StaffRoutes() chi.Router {
-	r := chi.NewRouter()
-
-	r.Get("/", h.Get)
-	r.Put("/", h.Update)
-	r.Get("/css", h.GetCSS)
-	r.Get("/preview", h.Preview)
-
-	return r
-}
-
-// PublicRoutes returns public routes for portal
-func (h *Handler) PublicRoutes() chi.Router {
-	r := chi.NewRouter()
-
-	r.Get("/", h.GetPublic)
-	r.Get("/css", h.GetPublicCSS)
-
-	return r
+// RegisterRoutes registers branding routes. Staff read the branding,
+// admins change it; the public routes serve client-facing pages and mails.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	admin := func(fn http.HandlerFunc) http.Handler { return requireAuth(requireAdmin(fn)) }
+
+	router.Handle("GET /api/v1/branding", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("PUT /api/v1/branding", admin(h.Update))
+	router.Handle("GET /api/v1/branding/css", requireAuth(http.HandlerFunc(h.GetCSS)))
+	router.Handle("GET /api/v1/branding/preview", requireAuth(http.HandlerFunc(h.Preview)))
+	router.Handle("POST /api/v1/branding/logo", admin(h.UploadLogo))
+	router.Handle("DELETE /api/v1/branding/logo", admin(h.DeleteLogo))
+	router.Handle("GET /api/v1/branding/domain", requireAuth(http.HandlerFunc(h.GetDomain)))
+	router.Handle("POST /api/v1/branding/domain/verify", admin(h.VerifyDomain))
+
+	router.HandleFunc("GET /api/v1/public/branding", h.GetPublic)
+	router.HandleFunc("GET /api/v1/public/branding/css", h.GetPublicCSS)
+	router.HandleFunc("GET /api/v1/public/branding/{tenantID}/logo", h.GetLogo)
+	router.HandleFunc("GET /api/v1/public/domains/tls-check", h.TLSCheck)
 }
 
 // Get returns branding for the current tenant
 func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	tenantID := tenant.GetTenantID(ctx)
-	if tenantID == uuid.Nil {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
 		return
 	}
 
-	branding, err := h.service.GetForTenant(ctx, tenantID)
+	branding, err := h.service.GetForTenant(r.Context(), tenantID)
 	if err != nil {
-		http.Error(w, "failed to get branding", http.StatusInternalServerError)
+		api.InternalError(w)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(branding)
+	api.JSONResponse(w, http.StatusOK, branding)
 }
 
 // Update updates branding for the current tenant
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	tenantID := tenant.GetTenantID(ctx)
-	if tenantID == uuid.Nil {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
 		return
 	}
 
 	var req UpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		api.BadRequest(w, "invalid request body")
 		return
 	}
 
-	branding, err := h.service.Update(ctx, tenantID, &req)
+	branding, err := h.service.Update(r.Context(), tenantID, &req)
 	if err != nil {
-		http.Error(w, "failed to update branding", http.StatusInternalServerError)
+		h.handleError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(branding)
+	api.JSONResponse(w, http.StatusOK, branding)
 }
 
-// GetCSS returns the generated CSS for the tenant
-func (h *Handler) GetCSS(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+// UploadLogo handles POST /api/v1/branding/logo with the logo as multipart
+// field "file"
+func (h *Handler) UploadLogo(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
+		return
+	}
 
-	tenantID := tenant.GetTenantID(ctx)
-	if tenantID == uuid.Nil {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	// Leave room for the multipart framing around the file
+	r.Body = http.MaxBytesReader(w, r.Body, MaxLogoSize+64<<10)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			api.JSONError(w, http.StatusRequestEntityTooLarge, ErrLogoTooLarge.Error(), api.ErrCodeValidation)
+			return
+		}
+		api.BadRequest(w, "file required")
 		return
 	}
+	defer file.Close()
 
-	branding, err := h.service.GetForTenant(ctx, tenantID)
+	data, err := io.ReadAll(io.LimitReader(file, MaxLogoSize+1))
 	if err != nil {
-		http.Error(w, "failed to get branding", http.StatusInternalServerError)
+		api.BadRequest(w, "failed to read file")
 		return
 	}
 
-	css := h.service.GenerateCSS(branding)
+	branding, err := h.service.UploadLogo(r.Context(), tenantID, data)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
 
-	w.Header().Set("Content-Type", "text/css")
-	w.Header().Set("Cache-Control", "public, max-age=3600")
-	w.Write([]byte(css))
+	api.JSONResponse(w, http.StatusOK, branding)
 }
 
-// Preview returns branding preview with provided values
-func (h *Handler) Preview(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+// DeleteLogo handles DELETE /api/v1/branding/logo
+func (h *Handler) DeleteLogo(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
+		return
+	}
 
-	tenantID := tenant.GetTenantID(ctx)
-	if tenantID == uuid.Nil {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	if err := h.service.DeleteLogo(r.Context(), tenantID); err != nil {
+		h.handleError(w, err)
 		return
 	}
 
-	// Get current branding as base
-	branding, err := h.service.GetForTenant(ctx, tenantID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDomain handles GET /api/v1/branding/domain with the verification state
+// of the custom domain and the DNS records to publish
+func (h *Handler) GetDomain(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.GetDomainStatus(r.Context(), tenantID)
 	if err != nil {
-		http.Error(w, "failed to get branding", http.StatusInternalServerError)
+		h.handleError(w, err)
 		return
 	}
 
-	// Apply query params for preview
-	if primaryColor := r.URL.Query().Get("primary_color"); primaryColor != "" {
-		branding.PrimaryColor = primaryColor
+	api.JSONResponse(w, http.StatusOK, status)
+}
+
+// VerifyDomain handles POST /api/v1/branding/domain/verify: it checks the
+// DNS records now and provisions the certificate once the domain verifies
+func (h *Handler) VerifyDomain(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.VerifyDomain(r.Context(), tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, status)
+}
+
+// TLSCheck handles GET /api/v1/public/domains/tls-check?domain=, the ask
+// endpoint of TLS proxies issuing certificates on demand: 200 permits a
+// certificate for the domain, 404 refuses it
+func (h *Handler) TLSCheck(w http.ResponseWriter, r *http.Request) {
+	allowed, err := h.service.TLSAllowed(r.Context(), r.URL.Query().Get("domain"))
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if !allowed {
+		api.NotFound(w, "unknown domain")
+		return
 	}
-	if secondaryColor := r.URL.Query().Get("secondary_color"); secondaryColor != "" {
-		branding.SecondaryColor = &secondaryColor
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetCSS returns the generated CSS for the tenant
+func (h *Handler) GetCSS(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
+		return
 	}
-	if accentColor := r.URL.Query().Get("accent_color"); accentColor != "" {
-		branding.AccentColor = &accentColor
+
+	branding, err := h.service.GetForTenant(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return
 	}
 
 	css := h.service.GenerateCSS(branding)
 
 	w.Header().Set("Content-Type", "text/css")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
 	w.Write([]byte(css))
 }
 
-// GetPublic returns public branding information
-func (h *Handler) GetPublic(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+// Preview returns branding preview with provided values
+func (h *Handler) Preview(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
+		return
+	}
 
-	// Try to resolve tenant from host
-	host := r.Host
-	tenantID, branding, err := h.service.ResolveTenant(ctx, host)
+	// Get current branding as base; the copy keeps the cached one intact
+	current, err := h.service.GetForTenant(r.Context(), tenantID)
 	if err != nil {
-		http.Error(w, "failed to resolve tenant", http.StatusInternalServerError)
+		api.InternalError(w)
 		return
 	}
+	branding := *current
 
-	// If not found by domain, try tenant ID from query or header
-	if tenantID == uuid.Nil {
-		tenantIDStr := r.URL.Query().Get("tenant_id")
-		if tenantIDStr == "" {
-			tenantIDStr = r.Header.Get("X-Tenant-ID")
-		}
-
-		if tenantIDStr != "" {
-			tenantID, err = uuid.Parse(tenantIDStr)
-			if err != nil {
-				http.Error(w, "invalid tenant ID", http.StatusBadRequest)
-				return
-			}
-
-			branding, err = h.service.GetForTenant(ctx, tenantID)
+	// Apply query params for preview
+	query := r.URL.Query()
+	for param, target := range map[string]**string{
+		"secondary_color": &branding.SecondaryColor,
+		"accent_color":    &branding.AccentColor,
+	} {
+		if value := query.Get(param); value != "" {
+			color, err := NormalizeColor(value)
 			if err != nil {
-				http.Error(w, "failed to get branding", http.StatusInternalServerError)
+				api.BadRequest(w, err.Error())
 				return
 			}
+			*target = &color
 		}
 	}
+	if value := query.Get("primary_color"); value != "" {
+		color, err := NormalizeColor(value)
+		if err != nil {
+			api.BadRequest(w, err.Error())
+			return
+		}
+		branding.PrimaryColor = color
+	}
+
+	css := h.service.GenerateCSS(&branding)
+
+	w.Header().Set("Content-Type", "text/css")
+	w.Write([]byte(css))
+}
 
+// GetPublic returns public branding information
+func (h *Handler) GetPublic(w http.ResponseWriter, r *http.Request) {
+	branding, ok := h.resolvePublic(w, r)
+	if !ok {
+		return
+	}
 	if branding == nil {
-		http.Error(w, "branding not found", http.StatusNotFound)
+		api.NotFound(w, "branding not found")
 		return
 	}
 
@@ -200,41 +275,16 @@ func (h *Handler) GetPublic(w http.ResponseWriter, r *http.Request) {
 		"footer_text":     branding.FooterText,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "public, max-age=300")
-	json.NewEncoder(w).Encode(publicBranding)
+	api.JSONResponse(w, http.StatusOK, publicBranding)
 }
 
 // GetPublicCSS returns the generated CSS for public portal
 func (h *Handler) GetPublicCSS(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	// Try to resolve tenant from host
-	host := r.Host
-	tenantID, branding, err := h.service.ResolveTenant(ctx, host)
-	if err != nil {
-		http.Error(w, "failed to resolve tenant", http.StatusInternalServerError)
+	branding, ok := h.resolvePublic(w, r)
+	if !ok {
 		return
 	}
-
-	// If not found by domain, try tenant ID from query
-	if tenantID == uuid.Nil {
-		tenantIDStr := r.URL.Query().Get("tenant_id")
-		if tenantIDStr != "" {
-			tenantID, err = uuid.Parse(tenantIDStr)
-			if err != nil {
-				http.Error(w, "invalid tenant ID", http.StatusBadRequest)
-				return
-			}
-
-			branding, err = h.service.GetForTenant(ctx, tenantID)
-			if err != nil {
-				http.Error(w, "failed to get branding", http.StatusInternalServerError)
-				return
-			}
-		}
-	}
-
 	if branding == nil {
 		// Return default CSS
 		branding = DefaultBranding
@@ -246,3 +296,89 @@ func (h *Handler) GetPublicCSS(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Cache-Control", "public, max-age=3600")
 	w.Write([]byte(css))
 }
+
+// GetLogo serves a tenant's uploaded logo to mail clients and public pages
+func (h *Handler) GetLogo(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(r.PathValue("tenantID"))
+	if err != nil || !tenant.ServesTenant(r.Context(), tenantID) {
+		api.NotFound(w, "logo not found")
+		return
+	}
+
+	data, contentType, err := h.service.GetLogo(r.Context(), tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Write(data)
+}
+
+// resolvePublic finds the branding of a public request by its host, the
+// tenant_id query parameter or the X-Tenant-ID header. It returns nil if
+// none is given. On a custom domain only the domain's tenant resolves.
+func (h *Handler) resolvePublic(w http.ResponseWriter, r *http.Request) (*TenantBranding, bool) {
+	ctx := r.Context()
+
+	tenantID, branding, err := h.service.ResolveTenant(ctx, r.Host)
+	if err != nil {
+		api.InternalError(w)
+		return nil, false
+	}
+	if tenantID != uuid.Nil {
+		return branding, true
+	}
+
+	tenantIDStr := r.URL.Query().Get("tenant_id")
+	if tenantIDStr == "" {
+		tenantIDStr = r.Header.Get("X-Tenant-ID")
+	}
+	if tenantIDStr == "" {
+		return nil, true
+	}
+
+	tenantID, err = uuid.Parse(tenantIDStr)
+	if err != nil {
+		api.BadRequest(w, "invalid tenant ID")
+		return nil, false
+	}
+	if !tenant.ServesTenant(ctx, tenantID) {
+		api.NotFound(w, "branding not found")
+		return nil, false
+	}
+	branding, err = h.service.GetForTenant(ctx, tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return nil, false
+	}
+	return branding, true
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrLogoNotFound), errors.Is(err, ErrBrandingNotFound), errors.Is(err, ErrNoCustomDomain):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrDomainTaken):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, ErrLogoTooLarge):
+		api.JSONError(w, http.StatusRequestEntityTooLarge, err.Error(), api.ErrCodeValidation)
+	case errors.Is(err, ErrInvalidColor), errors.Is(err, ErrInvalidDomain),
+		errors.Is(err, ErrInvalidURL), errors.Is(err, ErrInvalidLogo), errors.Is(err, errNameTooLong):
+		api.JSONError(w, http.StatusBadRequest, err.Error(), api.ErrCodeValidation)
+	default:
+		api.InternalError(w)
+	}
+}
+
+func tenantFromContext(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
@@ -0,0 +1,156 @@
+package branding
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/foerderung"
+	"austrian-business-infrastructure/internal/invoice"
+	"austrian-business-infrastructure/internal/mail"
+	"austrian-business-infrastructure/internal/signature"
+)
+
+// The generators of client-facing output take the branding through these
+// methods: mails, invoice PDFs, the signing pages and links and exported
+// spreadsheets.
+var (
+	_ mail.BrandProvider       = (*Service)(nil)
+	_ invoice.BrandProvider    = (*Service)(nil)
+	_ signature.Branding       = (*Service)(nil)
+	_ foerderung.BrandProvider = (*Service)(nil)
+)
+
+// configured returns the tenant's branding, nil if the tenant has none
+func (s *Service) configured(ctx context.Context, tenantID uuid.UUID) (*TenantBranding, error) {
+	branding, err := s.GetForTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if branding.ID == uuid.Nil {
+		return nil, nil
+	}
+	return branding, nil
+}
+
+// MailBrand returns the look of the tenant's mails
+func (s *Service) MailBrand(ctx context.Context, tenantID uuid.UUID) (*mail.Brand, error) {
+	branding, err := s.configured(ctx, tenantID)
+	if err != nil || branding == nil {
+		return nil, err
+	}
+
+	brand := &mail.Brand{
+		Name:       branding.CompanyName,
+		SenderName: deref(branding.SenderName),
+		LogoURL:    deref(branding.LogoURL),
+		Footer:     deref(branding.FooterText),
+	}
+	if color, err := NormalizeColor(branding.PrimaryColor); err == nil {
+		brand.Color = color
+	}
+	return brand, nil
+}
+
+// InvoiceBrand returns the look of the tenant's invoice PDFs. The uploaded
+// logo is converted to JPEG on white, the image format PDFs embed as is.
+func (s *Service) InvoiceBrand(ctx context.Context, tenantID uuid.UUID) (*invoice.PDFBrand, error) {
+	branding, err := s.configured(ctx, tenantID)
+	if err != nil || branding == nil {
+		return nil, err
+	}
+
+	brand := &invoice.PDFBrand{}
+	if color, err := NormalizeColor(branding.PrimaryColor); err == nil {
+		brand.Color = color
+	}
+	if !branding.HasUploadedLogo() {
+		return brand, nil
+	}
+
+	data, _, err := s.repo.GetLogo(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	logo, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode logo: %w", err)
+	}
+	bounds := logo.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), logo, bounds.Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, fmt.Errorf("failed to encode logo: %w", err)
+	}
+	brand.Logo = buf.Bytes()
+	brand.LogoWidth = bounds.Dx()
+	brand.LogoHeight = bounds.Dy()
+	return brand, nil
+}
+
+// PageBranding returns the look of the public signing and status pages
+func (s *Service) PageBranding(ctx context.Context, tenantID uuid.UUID) (*signature.PageBranding, error) {
+	branding, err := s.configured(ctx, tenantID)
+	if err != nil || branding == nil {
+		return nil, err
+	}
+	return &signature.PageBranding{
+		CompanyName:  branding.CompanyName,
+		LogoURL:      deref(branding.LogoURL),
+		PrimaryColor: branding.PrimaryColor,
+		AccentColor:  deref(branding.AccentColor),
+	}, nil
+}
+
+// SheetBrand returns the look of the tenant's exported spreadsheets
+func (s *Service) SheetBrand(ctx context.Context, tenantID uuid.UUID) (*foerderung.SheetBrand, error) {
+	branding, err := s.configured(ctx, tenantID)
+	if err != nil || branding == nil {
+		return nil, err
+	}
+	brand := &foerderung.SheetBrand{
+		CompanyName: branding.CompanyName,
+		Footer:      deref(branding.FooterText),
+	}
+	if color, err := NormalizeColor(branding.PrimaryColor); err == nil {
+		brand.Color = strings.TrimPrefix(color, "#")
+	}
+	return brand, nil
+}
+
+// PublicBaseURL moves a public link base URL to the tenant's custom domain.
+// The path is kept, so the portal has to serve the same routes there.
+// Without a verified custom domain, or if it cannot be loaded, the default
+// is kept.
+func (s *Service) PublicBaseURL(ctx context.Context, tenantID uuid.UUID, defaultURL string) string {
+	branding, err := s.configured(ctx, tenantID)
+	if err != nil || branding == nil || branding.CustomDomain == nil || branding.DomainVerifiedAt == nil {
+		return defaultURL
+	}
+
+	u, err := url.Parse(defaultURL)
+	if err != nil {
+		return defaultURL
+	}
+	u.Scheme = "https"
+	u.Host = *branding.CustomDomain
+	return u.String()
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
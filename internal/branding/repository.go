@@ -7,44 +7,60 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var (
 	ErrBrandingNotFound = errors.New("branding not found")
+	ErrLogoNotFound     = errors.New("logo not found")
+	ErrDomainTaken      = errors.New("custom domain is already used by another tenant")
+	ErrNoCustomDomain   = errors.New("no custom domain configured")
 )
 
 // TenantBranding represents branding configuration for a tenant
 type TenantBranding struct {
-	ID              uuid.UUID  `json:"id"`
-	TenantID        uuid.UUID  `json:"tenant_id"`
+	ID       uuid.UUID `json:"id"`
+	TenantID uuid.UUID `json:"tenant_id"`
 
 	// Branding
-	CompanyName     string     `json:"company_name"`
-	LogoURL         *string    `json:"logo_url,omitempty"`
-	FaviconURL      *string    `json:"favicon_url,omitempty"`
+	CompanyName string  `json:"company_name"`
+	LogoURL     *string `json:"logo_url,omitempty"`
+	FaviconURL  *string `json:"favicon_url,omitempty"`
 
 	// Colors
-	PrimaryColor    string     `json:"primary_color"`
-	SecondaryColor  *string    `json:"secondary_color,omitempty"`
-	AccentColor     *string    `json:"accent_color,omitempty"`
+	PrimaryColor   string  `json:"primary_color"`
+	SecondaryColor *string `json:"secondary_color,omitempty"`
+	AccentColor    *string `json:"accent_color,omitempty"`
 
 	// Custom CSS
-	CustomCSS       *string    `json:"custom_css,omitempty"`
+	CustomCSS *string `json:"custom_css,omitempty"`
 
 	// Contact
-	SupportEmail    *string    `json:"support_email,omitempty"`
-	SupportPhone    *string    `json:"support_phone,omitempty"`
+	SupportEmail *string `json:"support_email,omitempty"`
+	SupportPhone *string `json:"support_phone,omitempty"`
+
+	// Mails
+	SenderName *string `json:"sender_name,omitempty"`
 
 	// Portal settings
-	WelcomeMessage  *string    `json:"welcome_message,omitempty"`
-	FooterText      *string    `json:"footer_text,omitempty"`
+	WelcomeMessage *string `json:"welcome_message,omitempty"`
+	FooterText     *string `json:"footer_text,omitempty"`
+
+	// Custom domain; public links use it once it is verified
+	CustomDomain     *string    `json:"custom_domain,omitempty"`
+	DomainVerifiedAt *time.Time `json:"domain_verified_at,omitempty"`
 
-	// Custom domain
-	CustomDomain    *string    `json:"custom_domain,omitempty"`
+	// Uploaded logo; the data is loaded with GetLogo
+	LogoContentType *string `json:"logo_content_type,omitempty"`
 
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HasUploadedLogo reports whether the tenant uploaded a logo
+func (b *TenantBranding) HasUploadedLogo() bool {
+	return b.LogoContentType != nil
 }
 
 // Repository provides branding data access
@@ -57,6 +73,31 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 	return &Repository{pool: pool}
 }
 
+const brandingColumns = `id, tenant_id, company_name, logo_url, favicon_url,
+	primary_color, secondary_color, accent_color, custom_css,
+	support_email, support_phone, sender_name, welcome_message, footer_text,
+	custom_domain, domain_verified_at, logo_content_type, created_at, updated_at`
+
+func scanBranding(row pgx.Row) (*TenantBranding, error) {
+	branding := &TenantBranding{}
+	err := row.Scan(
+		&branding.ID, &branding.TenantID, &branding.CompanyName,
+		&branding.LogoURL, &branding.FaviconURL,
+		&branding.PrimaryColor, &branding.SecondaryColor, &branding.AccentColor,
+		&branding.CustomCSS, &branding.SupportEmail, &branding.SupportPhone,
+		&branding.SenderName, &branding.WelcomeMessage, &branding.FooterText,
+		&branding.CustomDomain, &branding.DomainVerifiedAt, &branding.LogoContentType,
+		&branding.CreatedAt, &branding.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBrandingNotFound
+		}
+		return nil, err
+	}
+	return branding, nil
+}
+
 // Create creates a new branding configuration
 func (r *Repository) Create(ctx context.Context, branding *TenantBranding) error {
 	if branding.ID == uuid.Nil {
@@ -67,9 +108,9 @@ func (r *Repository) Create(ctx context.Context, branding *TenantBranding) error
 		INSERT INTO tenant_branding (
 			id, tenant_id, company_name, logo_url, favicon_url,
 			primary_color, secondary_color, accent_color, custom_css,
-			support_email, support_phone, welcome_message, footer_text, custom_domain
+			support_email, support_phone, sender_name, welcome_message, footer_text, custom_domain
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		RETURNING created_at, updated_at
 	`
 
@@ -85,105 +126,31 @@ func (r *Repository) Create(ctx context.Context, branding *TenantBranding) error
 		branding.CustomCSS,
 		branding.SupportEmail,
 		branding.SupportPhone,
+		branding.SenderName,
 		branding.WelcomeMessage,
 		branding.FooterText,
 		branding.CustomDomain,
 	).Scan(&branding.CreatedAt, &branding.UpdatedAt)
 
-	return err
+	return mapWriteError(err)
 }
 
 // GetByID retrieves branding by ID
 func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*TenantBranding, error) {
-	query := `
-		SELECT id, tenant_id, company_name, logo_url, favicon_url,
-			primary_color, secondary_color, accent_color, custom_css,
-			support_email, support_phone, welcome_message, footer_text, custom_domain,
-			created_at, updated_at
-		FROM tenant_branding
-		WHERE id = $1
-	`
-
-	branding := &TenantBranding{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&branding.ID, &branding.TenantID, &branding.CompanyName,
-		&branding.LogoURL, &branding.FaviconURL,
-		&branding.PrimaryColor, &branding.SecondaryColor, &branding.AccentColor,
-		&branding.CustomCSS, &branding.SupportEmail, &branding.SupportPhone,
-		&branding.WelcomeMessage, &branding.FooterText, &branding.CustomDomain,
-		&branding.CreatedAt, &branding.UpdatedAt,
-	)
-
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrBrandingNotFound
-		}
-		return nil, err
-	}
-
-	return branding, nil
+	query := `SELECT ` + brandingColumns + ` FROM tenant_branding WHERE id = $1`
+	return scanBranding(r.pool.QueryRow(ctx, query, id))
 }
 
 // GetByTenantID retrieves branding by tenant ID
 func (r *Repository) GetByTenantID(ctx context.Context, tenantID uuid.UUID) (*TenantBranding, error) {
-	query := `
-		SELECT id, tenant_id, company_name, logo_url, favicon_url,
-			primary_color, secondary_color, accent_color, custom_css,
-			support_email, support_phone, welcome_message, footer_text, custom_domain,
-			created_at, updated_at
-		FROM tenant_branding
-		WHERE tenant_id = $1
-	`
-
-	branding := &TenantBranding{}
-	err := r.pool.QueryRow(ctx, query, tenantID).Scan(
-		&branding.ID, &branding.TenantID, &branding.CompanyName,
-		&branding.LogoURL, &branding.FaviconURL,
-		&branding.PrimaryColor, &branding.SecondaryColor, &branding.AccentColor,
-		&branding.CustomCSS, &branding.SupportEmail, &branding.SupportPhone,
-		&branding.WelcomeMessage, &branding.FooterText, &branding.CustomDomain,
-		&branding.CreatedAt, &branding.UpdatedAt,
-	)
-
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrBrandingNotFound
-		}
-		return nil, err
-	}
-
-	return branding, nil
+	query := `SELECT ` + brandingColumns + ` FROM tenant_branding WHERE tenant_id = $1`
+	return scanBranding(r.pool.QueryRow(ctx, query, tenantID))
 }
 
-// GetByCustomDomain retrieves branding by custom domain
+// GetByCustomDomain retrieves branding by verified custom domain
 func (r *Repository) GetByCustomDomain(ctx context.Context, domain string) (*TenantBranding, error) {
-	query := `
-		SELECT id, tenant_id, company_name, logo_url, favicon_url,
-			primary_color, secondary_color, accent_color, custom_css,
-			support_email, support_phone, welcome_message, footer_text, custom_domain,
-			created_at, updated_at
-		FROM tenant_branding
-		WHERE custom_domain = $1
-	`
-
-	branding := &TenantBranding{}
-	err := r.pool.QueryRow(ctx, query, domain).Scan(
-		&branding.ID, &branding.TenantID, &branding.CompanyName,
-		&branding.LogoURL, &branding.FaviconURL,
-		&branding.PrimaryColor, &branding.SecondaryColor, &branding.AccentColor,
-		&branding.CustomCSS, &branding.SupportEmail, &branding.SupportPhone,
-		&branding.WelcomeMessage, &branding.FooterText, &branding.CustomDomain,
-		&branding.CreatedAt, &branding.UpdatedAt,
-	)
-
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrBrandingNotFound
-		}
-		return nil, err
-	}
-
-	return branding, nil
+	query := `SELECT ` + brandingColumns + ` FROM tenant_branding WHERE custom_domain = $1 AND domain_verified_at IS NOT NULL`
+	return scanBranding(r.pool.QueryRow(ctx, query, domain))
 }
 
 // Update updates branding configuration
@@ -193,8 +160,9 @@ func (r *Repository) Update(ctx context.Context, branding *TenantBranding) error
 		SET company_name = $2, logo_url = $3, favicon_url = $4,
 			primary_color = $5, secondary_color = $6, accent_color = $7,
 			custom_css = $8, support_email = $9, support_phone = $10,
-			welcome_message = $11, footer_text = $12, custom_domain = $13,
-			updated_at = NOW()
+			sender_name = $11, welcome_message = $12, footer_text = $13,
+			custom_domain = $14, updated_at = NOW(),
+			domain_verified_at = CASE WHEN custom_domain IS DISTINCT FROM $14 THEN NULL ELSE domain_verified_at END
 		WHERE id = $1
 		RETURNING updated_at
 	`
@@ -210,6 +178,7 @@ func (r *Repository) Update(ctx context.Context, branding *TenantBranding) error
 		branding.CustomCSS,
 		branding.SupportEmail,
 		branding.SupportPhone,
+		branding.SenderName,
 		branding.WelcomeMessage,
 		branding.FooterText,
 		branding.CustomDomain,
@@ -219,13 +188,14 @@ func (r *Repository) Update(ctx context.Context, branding *TenantBranding) error
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ErrBrandingNotFound
 		}
-		return err
+		return mapWriteError(err)
 	}
 
 	return nil
 }
 
-// Upsert creates or updates branding for a tenant
+// Upsert creates or updates branding for a tenant. The uploaded logo is
+// kept; it is changed with SetLogo only.
 func (r *Repository) Upsert(ctx context.Context, branding *TenantBranding) error {
 	if branding.ID == uuid.Nil {
 		branding.ID = uuid.New()
@@ -235,9 +205,9 @@ func (r *Repository) Upsert(ctx context.Context, branding *TenantBranding) error
 		INSERT INTO tenant_branding (
 			id, tenant_id, company_name, logo_url, favicon_url,
 			primary_color, secondary_color, accent_color, custom_css,
-			support_email, support_phone, welcome_message, footer_text, custom_domain
+			support_email, support_phone, sender_name, welcome_message, footer_text, custom_domain
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		ON CONFLICT (tenant_id) DO UPDATE SET
 			company_name = EXCLUDED.company_name,
 			logo_url = EXCLUDED.logo_url,
@@ -248,11 +218,15 @@ func (r *Repository) Upsert(ctx context.Context, branding *TenantBranding) error
 			custom_css = EXCLUDED.custom_css,
 			support_email = EXCLUDED.support_email,
 			support_phone = EXCLUDED.support_phone,
+			sender_name = EXCLUDED.sender_name,
 			welcome_message = EXCLUDED.welcome_message,
 			footer_text = EXCLUDED.footer_text,
 			custom_domain = EXCLUDED.custom_domain,
+			-- A new domain is not verified until ResetDomain and a new check
+			domain_verified_at = CASE WHEN tenant_branding.custom_domain IS DISTINCT FROM EXCLUDED.custom_domain
+				THEN NULL ELSE tenant_branding.domain_verified_at END,
 			updated_at = NOW()
-		RETURNING id, created_at, updated_at
+		RETURNING id, domain_verified_at, logo_content_type, created_at, updated_at
 	`
 
 	err := r.pool.QueryRow(ctx, query,
@@ -267,11 +241,131 @@ func (r *Repository) Upsert(ctx context.Context, branding *TenantBranding) error
 		branding.CustomCSS,
 		branding.SupportEmail,
 		branding.SupportPhone,
+		branding.SenderName,
 		branding.WelcomeMessage,
 		branding.FooterText,
 		branding.CustomDomain,
-	).Scan(&branding.ID, &branding.CreatedAt, &branding.UpdatedAt)
+	).Scan(&branding.ID, &branding.DomainVerifiedAt, &branding.LogoContentType, &branding.CreatedAt, &branding.UpdatedAt)
+
+	return mapWriteError(err)
+}
+
+// SetLogo stores an uploaded logo together with the URL it is served at.
+// A nil logo removes the uploaded logo and its URL.
+func (r *Repository) SetLogo(ctx context.Context, tenantID uuid.UUID, data []byte, contentType, logoURL *string) error {
+	query := `
+		UPDATE tenant_branding
+		SET logo_data = $2, logo_content_type = $3, logo_url = $4, updated_at = NOW()
+		WHERE tenant_id = $1
+	`
+
+	result, err := r.pool.Exec(ctx, query, tenantID, data, contentType, logoURL)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrBrandingNotFound
+	}
+	return nil
+}
 
+// GetLogo returns the uploaded logo of a tenant and its content type
+func (r *Repository) GetLogo(ctx context.Context, tenantID uuid.UUID) ([]byte, string, error) {
+	var data []byte
+	var contentType *string
+	err := r.pool.QueryRow(ctx, `
+		SELECT logo_data, logo_content_type FROM tenant_branding WHERE tenant_id = $1
+	`, tenantID).Scan(&data, &contentType)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, "", ErrLogoNotFound
+		}
+		return nil, "", err
+	}
+	if data == nil || contentType == nil {
+		return nil, "", ErrLogoNotFound
+	}
+	return data, *contentType, nil
+}
+
+// DomainState is the verification and certificate state of a custom domain
+type DomainState struct {
+	Domain               string
+	VerificationToken    *string
+	VerifiedAt           *time.Time
+	CheckedAt            *time.Time
+	CheckError           *string
+	CertificateStatus    string
+	CertificateError     *string
+	CertificateUpdatedAt *time.Time
+}
+
+// GetDomainState returns the state of a tenant's custom domain
+func (r *Repository) GetDomainState(ctx context.Context, tenantID uuid.UUID) (*DomainState, error) {
+	var domain *string
+	state := &DomainState{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT custom_domain, domain_verification_token, domain_verified_at, domain_checked_at,
+			domain_check_error, certificate_status, certificate_error, certificate_updated_at
+		FROM tenant_branding
+		WHERE tenant_id = $1
+	`, tenantID).Scan(
+		&domain, &state.VerificationToken, &state.VerifiedAt, &state.CheckedAt,
+		&state.CheckError, &state.CertificateStatus, &state.CertificateError, &state.CertificateUpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoCustomDomain
+		}
+		return nil, err
+	}
+	if domain == nil {
+		return nil, ErrNoCustomDomain
+	}
+	state.Domain = *domain
+	return state, nil
+}
+
+// ResetDomain starts the verification of a new custom domain over
+func (r *Repository) ResetDomain(ctx context.Context, tenantID uuid.UUID, token *string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE tenant_branding
+		SET domain_verification_token = $2, domain_verified_at = NULL, domain_checked_at = NULL,
+			domain_check_error = NULL, certificate_status = 'none', certificate_error = NULL,
+			certificate_updated_at = NULL
+		WHERE tenant_id = $1
+	`, tenantID, token)
+	return err
+}
+
+// SetVerificationToken sets the token of a domain that has none yet
+func (r *Repository) SetVerificationToken(ctx context.Context, tenantID uuid.UUID, token string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE tenant_branding SET domain_verification_token = $2
+		WHERE tenant_id = $1 AND domain_verification_token IS NULL
+	`, tenantID, token)
+	return err
+}
+
+// RecordDomainCheck stores the result of a DNS check. A verified domain
+// stays verified when a later check fails.
+func (r *Repository) RecordDomainCheck(ctx context.Context, tenantID uuid.UUID, verified bool, checkError *string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE tenant_branding
+		SET domain_checked_at = NOW(), domain_check_error = $3,
+			domain_verified_at = CASE WHEN $2 THEN COALESCE(domain_verified_at, NOW()) ELSE domain_verified_at END
+		WHERE tenant_id = $1
+	`, tenantID, verified, checkError)
+	return mapWriteError(err)
+}
+
+// SetCertificateStatus stores the state of the domain's certificate
+func (r *Repository) SetCertificateStatus(ctx context.Context, tenantID uuid.UUID, status string, certError *string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE tenant_branding
+		SET certificate_status = $2, certificate_error = $3, certificate_updated_at = NOW()
+		WHERE tenant_id = $1
+	`, tenantID, status, certError)
 	return err
 }
 
@@ -290,3 +384,12 @@ func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// mapWriteError maps the unique violation of the verified domain index
+func mapWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_branding_verified_domain" {
+		return ErrDomainTaken
+	}
+	return err
+}
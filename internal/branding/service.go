@@ -1,14 +1,49 @@
 package branding
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // register JPEG for logo uploads
+	_ "image/png"  // register PNG for logo uploads
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var (
+	ErrInvalidColor  = errors.New("colors must be hex values like #1A2B3C")
+	ErrInvalidDomain = errors.New("custom domain must be a host name like portal.example.at")
+	ErrInvalidURL    = errors.New("URLs must use https")
+	ErrInvalidLogo   = errors.New("logo must be a PNG or JPEG image")
+	ErrLogoTooLarge  = errors.New("logo is too large")
+	errNameTooLong   = fmt.Errorf("names must not exceed %d characters", maxNameLength)
+)
+
+const (
+	// MaxLogoSize is the maximum size of an uploaded logo
+	MaxLogoSize = 512 * 1024
+	// MaxLogoDimension is the maximum width and height of an uploaded logo
+	MaxLogoDimension = 2000
+	// maxNameLength matches the company_name and sender_name columns
+	maxNameLength = 255
+)
+
+var (
+	hexColorPattern = regexp.MustCompile(`^#([0-9A-Fa-f]{3}|[0-9A-Fa-f]{6})$`)
+	hostnamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+)
+
 // DefaultBranding provides default values when tenant has no branding configured
 var DefaultBranding = &TenantBranding{
 	CompanyName:  "Client Portal",
@@ -26,6 +61,7 @@ type UpdateRequest struct {
 	CustomCSS      *string `json:"custom_css,omitempty"`
 	SupportEmail   *string `json:"support_email,omitempty"`
 	SupportPhone   *string `json:"support_phone,omitempty"`
+	SenderName     *string `json:"sender_name,omitempty"`
 	WelcomeMessage *string `json:"welcome_message,omitempty"`
 	FooterText     *string `json:"footer_text,omitempty"`
 	CustomDomain   *string `json:"custom_domain,omitempty"`
@@ -33,18 +69,26 @@ type UpdateRequest struct {
 
 // Service provides branding business logic
 type Service struct {
-	repo  *Repository
-	pool  *pgxpool.Pool
-	cache map[uuid.UUID]*TenantBranding
-	mu    sync.RWMutex
+	repo      *Repository
+	pool      *pgxpool.Pool
+	publicURL string // base URL of the API, for uploaded logo URLs
+	domains   DomainConfig
+	cache     map[uuid.UUID]*TenantBranding
+	mu        sync.RWMutex
+	hosts     map[string]hostEntry // custom domain lookups of the host middleware
+	hostMu    sync.Mutex
 }
 
-// NewService creates a new branding service
-func NewService(pool *pgxpool.Pool) *Service {
+// NewService creates a new branding service. publicURL is the base URL
+// the API is reachable at from mail clients and browsers.
+func NewService(pool *pgxpool.Pool, publicURL string) *Service {
 	return &Service{
-		repo:  NewRepository(pool),
-		pool:  pool,
-		cache: make(map[uuid.UUID]*TenantBranding),
+		repo:      NewRepository(pool),
+		pool:      pool,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		domains:   DomainConfig{Resolver: net.DefaultResolver, Logger: slog.Default()},
+		cache:     make(map[uuid.UUID]*TenantBranding),
+		hosts:     make(map[string]hostEntry),
 	}
 }
 
@@ -93,6 +137,10 @@ func (s *Service) GetByDomain(ctx context.Context, domain string) (*TenantBrandi
 
 // Update updates branding configuration
 func (s *Service) Update(ctx context.Context, tenantID uuid.UUID, req *UpdateRequest) (*TenantBranding, error) {
+	if err := req.normalize(); err != nil {
+		return nil, err
+	}
+
 	// Get existing branding or create new
 	branding, err := s.repo.GetByTenantID(ctx, tenantID)
 	if err != nil && err != ErrBrandingNotFound {
@@ -100,54 +148,56 @@ func (s *Service) Update(ctx context.Context, tenantID uuid.UUID, req *UpdateReq
 	}
 
 	if branding == nil {
+		// The company name stays empty until it is set, so that mails and
+		// PDFs keep the platform name instead of the portal default
 		branding = &TenantBranding{
 			TenantID:     tenantID,
-			CompanyName:  DefaultBranding.CompanyName,
 			PrimaryColor: DefaultBranding.PrimaryColor,
 		}
 	}
 
+	previousDomain := deref(branding.CustomDomain)
+	wasVerified := branding.DomainVerifiedAt != nil
+
 	// Apply updates
 	if req.CompanyName != nil {
 		branding.CompanyName = *req.CompanyName
 	}
-	if req.LogoURL != nil {
-		branding.LogoURL = req.LogoURL
+	if req.LogoURL != nil && !branding.HasUploadedLogo() {
+		branding.LogoURL = optional(*req.LogoURL)
 	}
 	if req.FaviconURL != nil {
-		branding.FaviconURL = req.FaviconURL
+		branding.FaviconURL = optional(*req.FaviconURL)
 	}
 	if req.PrimaryColor != nil {
 		branding.PrimaryColor = *req.PrimaryColor
 	}
 	if req.SecondaryColor != nil {
-		branding.SecondaryColor = req.SecondaryColor
+		branding.SecondaryColor = optional(*req.SecondaryColor)
 	}
 	if req.AccentColor != nil {
-		branding.AccentColor = req.AccentColor
+		branding.AccentColor = optional(*req.AccentColor)
 	}
 	if req.CustomCSS != nil {
-		branding.CustomCSS = req.CustomCSS
+		branding.CustomCSS = optional(*req.CustomCSS)
 	}
 	if req.SupportEmail != nil {
-		branding.SupportEmail = req.SupportEmail
+		branding.SupportEmail = optional(*req.SupportEmail)
 	}
 	if req.SupportPhone != nil {
-		branding.SupportPhone = req.SupportPhone
+		branding.SupportPhone = optional(*req.SupportPhone)
+	}
+	if req.SenderName != nil {
+		branding.SenderName = optional(*req.SenderName)
 	}
 	if req.WelcomeMessage != nil {
-		branding.WelcomeMessage = req.WelcomeMessage
+		branding.WelcomeMessage = optional(*req.WelcomeMessage)
 	}
 	if req.FooterText != nil {
-		branding.FooterText = req.FooterText
+		branding.FooterText = optional(*req.FooterText)
 	}
 	if req.CustomDomain != nil {
-		domain := strings.ToLower(strings.TrimSpace(*req.CustomDomain))
-		if domain == "" {
-			branding.CustomDomain = nil
-		} else {
-			branding.CustomDomain = &domain
-		}
+		branding.CustomDomain = optional(*req.CustomDomain)
 	}
 
 	// Upsert to database
@@ -155,6 +205,13 @@ func (s *Service) Update(ctx context.Context, tenantID uuid.UUID, req *UpdateReq
 		return nil, err
 	}
 
+	if domain := deref(branding.CustomDomain); domain != previousDomain {
+		if err := s.domainChanged(ctx, tenantID, previousDomain, wasVerified, domain); err != nil {
+			return nil, err
+		}
+		branding.DomainVerifiedAt = nil
+	}
+
 	// Invalidate cache
 	s.mu.Lock()
 	delete(s.cache, tenantID)
@@ -163,6 +220,146 @@ func (s *Service) Update(ctx context.Context, tenantID uuid.UUID, req *UpdateReq
 	return branding, nil
 }
 
+// normalize validates the request and brings its values into their stored
+// form. Empty strings clear optional settings.
+func (req *UpdateRequest) normalize() error {
+	if req.PrimaryColor != nil {
+		normalized, err := NormalizeColor(*req.PrimaryColor)
+		if err != nil {
+			return err
+		}
+		req.PrimaryColor = &normalized
+	}
+	for _, color := range []*string{req.SecondaryColor, req.AccentColor} {
+		if color == nil || *color == "" {
+			continue
+		}
+		normalized, err := NormalizeColor(*color)
+		if err != nil {
+			return err
+		}
+		*color = normalized
+	}
+
+	for _, name := range []*string{req.CompanyName, req.SenderName} {
+		if name == nil {
+			continue
+		}
+		// Collapsing whitespace also keeps line breaks out of mail headers
+		*name = strings.Join(strings.Fields(*name), " ")
+		if len(*name) > maxNameLength {
+			return errNameTooLong
+		}
+	}
+
+	for _, u := range []*string{req.LogoURL, req.FaviconURL} {
+		if u == nil || *u == "" {
+			continue
+		}
+		parsed, err := url.Parse(strings.TrimSpace(*u))
+		if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return ErrInvalidURL
+		}
+		*u = parsed.String()
+	}
+
+	if req.CustomDomain != nil {
+		domain, err := NormalizeDomain(*req.CustomDomain)
+		if err != nil {
+			return err
+		}
+		*req.CustomDomain = domain
+	}
+	return nil
+}
+
+// NormalizeColor validates a hex color and returns it as upper case
+// #RRGGBB
+func NormalizeColor(color string) (string, error) {
+	color = strings.TrimSpace(color)
+	if !hexColorPattern.MatchString(color) {
+		return "", ErrInvalidColor
+	}
+	if len(color) == 4 {
+		color = string([]byte{'#', color[1], color[1], color[2], color[2], color[3], color[3]})
+	}
+	return strings.ToUpper(color), nil
+}
+
+// NormalizeDomain validates a custom domain and returns it in lower case.
+// An empty domain removes the custom domain.
+func NormalizeDomain(domain string) (string, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if domain == "" {
+		return "", nil
+	}
+	if len(domain) > 253 || !hostnamePattern.MatchString(domain) {
+		return "", ErrInvalidDomain
+	}
+	return domain, nil
+}
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// UploadLogo stores a PNG or JPEG logo. It replaces a logo URL set before
+// and is served from the public logo endpoint.
+func (s *Service) UploadLogo(ctx context.Context, tenantID uuid.UUID, data []byte) (*TenantBranding, error) {
+	if len(data) > MaxLogoSize {
+		return nil, ErrLogoTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	if contentType != "image/png" && contentType != "image/jpeg" {
+		return nil, ErrInvalidLogo
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidLogo
+	}
+	if cfg.Width > MaxLogoDimension || cfg.Height > MaxLogoDimension {
+		return nil, ErrLogoTooLarge
+	}
+
+	// The logo is stored on the tenant's branding row
+	if _, err := s.repo.GetByTenantID(ctx, tenantID); errors.Is(err, ErrBrandingNotFound) {
+		if _, err := s.Update(ctx, tenantID, &UpdateRequest{}); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	// The version parameter makes mail clients and browsers load a new logo
+	logoURL := fmt.Sprintf("%s/api/v1/public/branding/%s/logo?v=%d", s.publicURL, tenantID, time.Now().Unix())
+	if err := s.repo.SetLogo(ctx, tenantID, data, &contentType, &logoURL); err != nil {
+		return nil, err
+	}
+	s.InvalidateCache(tenantID)
+
+	return s.repo.GetByTenantID(ctx, tenantID)
+}
+
+// DeleteLogo removes the uploaded logo
+func (s *Service) DeleteLogo(ctx context.Context, tenantID uuid.UUID) error {
+	if err := s.repo.SetLogo(ctx, tenantID, nil, nil, nil); err != nil {
+		if errors.Is(err, ErrBrandingNotFound) {
+			return ErrLogoNotFound
+		}
+		return err
+	}
+	s.InvalidateCache(tenantID)
+	return nil
+}
+
+// GetLogo returns the uploaded logo of a tenant and its content type
+func (s *Service) GetLogo(ctx context.Context, tenantID uuid.UUID) ([]byte, string, error) {
+	return s.repo.GetLogo(ctx, tenantID)
+}
+
 // InvalidateCache removes a tenant from the cache
 func (s *Service) InvalidateCache(tenantID uuid.UUID) {
 	s.mu.Lock()
@@ -170,17 +367,13 @@ func (s *Service) InvalidateCache(tenantID uuid.UUID) {
 	s.mu.Unlock()
 }
 
-// ResolveTenant determines the tenant from a request domain
+// ResolveTenant determines the tenant from a request domain; only verified
+// custom domains resolve
 func (s *Service) ResolveTenant(ctx context.Context, host string) (uuid.UUID, *TenantBranding, error) {
-	// Strip port if present
-	if idx := strings.Index(host, ":"); idx != -1 {
-		host = host[:idx]
-	}
-
 	// Try to find by custom domain
-	branding, err := s.GetByDomain(ctx, host)
+	branding, err := s.GetByDomain(ctx, hostname(host))
 	if err != nil {
-		if err == ErrBrandingNotFound {
+		if errors.Is(err, ErrBrandingNotFound) {
 			return uuid.Nil, nil, nil
 		}
 		return uuid.Nil, nil, err
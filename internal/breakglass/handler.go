@@ -0,0 +1,154 @@
+package breakglass
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler handles break-glass HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new break-glass handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers break-glass routes. Super-admins are checked by
+// the service; tenant admins list and revoke the grants on their tenant.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("POST /api/v1/break-glass/grants", requireAuth(http.HandlerFunc(h.Grant)))
+	router.Handle("GET /api/v1/break-glass/grants", requireAuth(http.HandlerFunc(h.ListOwn)))
+	router.Handle("POST /api/v1/break-glass/grants/{id}/revoke", requireAuth(http.HandlerFunc(h.Revoke)))
+	router.Handle("GET /api/v1/break-glass/tenant-grants", requireAuth(requireAdmin(http.HandlerFunc(h.ListTenant))))
+}
+
+// Grant starts break-glass access to a tenant
+func (h *Handler) Grant(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	var req GrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+	if req.TenantID == uuid.Nil {
+		api.JSONError(w, http.StatusBadRequest, "tenant_id is required", api.ErrCodeValidation)
+		return
+	}
+
+	result, err := h.service.Grant(r.Context(), caller, &req, r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, result)
+}
+
+// ListOwn returns the grants of the calling super-admin
+func (h *Handler) ListOwn(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	grants, err := h.service.ListForAdmin(r.Context(), caller.UserID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	if grants == nil {
+		grants = []*Grant{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"grants": grants})
+}
+
+// ListTenant returns the break-glass grants on the caller's tenant
+func (h *Handler) ListTenant(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	grants, err := h.service.ListForTenant(r.Context(), caller.TenantID)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	if grants == nil {
+		grants = []*Grant{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"grants": grants})
+}
+
+// Revoke ends a grant before it expires
+func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	grantID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid grant ID")
+		return
+	}
+
+	grant, err := h.service.Revoke(r.Context(), caller, grantID, r)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, grant)
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrNotSuperAdmin), errors.Is(err, ErrNested):
+		api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalPermissionDenied, Reason: "break_glass"})
+		api.JSONError(w, http.StatusForbidden, err.Error(), api.ErrCodeForbidden)
+	case errors.Is(err, ErrTwoFactorRequired):
+		api.JSONError(w, http.StatusForbidden, err.Error(), "2FA_REQUIRED")
+	case errors.Is(err, ErrGrantNotFound), errors.Is(err, ErrTenantNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrGrantActive), errors.Is(err, ErrGrantInactive):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, ErrReasonRequired), errors.Is(err, ErrInvalidDuration), errors.Is(err, ErrOwnTenant):
+		api.JSONError(w, http.StatusBadRequest, err.Error(), api.ErrCodeValidation)
+	default:
+		api.InternalError(w)
+	}
+}
+
+func callerFromContext(w http.ResponseWriter, r *http.Request) (Caller, bool) {
+	ctx := r.Context()
+	userID, err := uuid.Parse(api.GetUserID(ctx))
+	if err != nil {
+		api.Unauthorized(w, "user not found in context")
+		return Caller{}, false
+	}
+	tenantID, err := uuid.Parse(api.GetTenantID(ctx))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return Caller{}, false
+	}
+	return Caller{
+		UserID:     userID,
+		TenantID:   tenantID,
+		Role:       api.GetUserRole(ctx),
+		BreakGlass: api.GetBreakGlassGrantID(ctx) != "",
+	}, true
+}
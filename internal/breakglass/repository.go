@@ -0,0 +1,220 @@
+package breakglass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Grant is a super-admin's time-boxed access to a tenant
+type Grant struct {
+	ID           uuid.UUID  `json:"id"`
+	TenantID     uuid.UUID  `json:"tenant_id"`
+	AdminUserID  uuid.UUID  `json:"admin_user_id"`
+	AdminName    string     `json:"admin_name"`
+	Reason       string     `json:"reason"`
+	Role         string     `json:"role"`
+	GrantedAt    time.Time  `json:"granted_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy    *uuid.UUID `json:"revoked_by,omitempty"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	RequestCount int        `json:"request_count"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	Active       bool       `json:"active"`
+}
+
+// Recipient is a tenant admin notified about break-glass access
+type Recipient struct {
+	Name  string
+	Email string
+}
+
+// adminAccount is the super-admin's own user record
+type adminAccount struct {
+	Name        string
+	TOTPEnabled bool
+	Active      bool
+}
+
+// Repository stores break-glass grants. Grants are platform records; the
+// tenant only sees them through its audit log and the tenant listing.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new break-glass repository
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+const grantColumns = `id, tenant_id, admin_user_id, admin_name, reason, role, granted_at, expires_at,
+	revoked_at, revoked_by, ended_at, request_count, last_used_at,
+	(revoked_at IS NULL AND ended_at IS NULL AND expires_at > NOW())`
+
+// Create stores a new grant
+func (r *Repository) Create(ctx context.Context, g *Grant) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO break_glass_grants (tenant_id, admin_user_id, admin_name, reason, role, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, granted_at
+	`, g.TenantID, g.AdminUserID, g.AdminName, g.Reason, g.Role, g.ExpiresAt).Scan(&g.ID, &g.GrantedAt)
+}
+
+// GetByID returns a grant
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*Grant, error) {
+	g, err := scanGrant(r.pool.QueryRow(ctx, `SELECT `+grantColumns+` FROM break_glass_grants WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrGrantNotFound
+	}
+	return g, err
+}
+
+// HasActive reports whether the admin already holds an active grant for the
+// tenant
+func (r *Repository) HasActive(ctx context.Context, adminUserID, tenantID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM break_glass_grants
+			WHERE admin_user_id = $1 AND tenant_id = $2
+				AND revoked_at IS NULL AND ended_at IS NULL AND expires_at > NOW()
+		)
+	`, adminUserID, tenantID).Scan(&exists)
+	return exists, err
+}
+
+// ListByAdmin returns the grants of a super-admin, newest first
+func (r *Repository) ListByAdmin(ctx context.Context, adminUserID uuid.UUID, limit int) ([]*Grant, error) {
+	return r.list(ctx, `SELECT `+grantColumns+` FROM break_glass_grants
+		WHERE admin_user_id = $1 ORDER BY granted_at DESC LIMIT $2`, adminUserID, limit)
+}
+
+// ListByTenant returns the grants on a tenant, newest first
+func (r *Repository) ListByTenant(ctx context.Context, tenantID uuid.UUID, limit int) ([]*Grant, error) {
+	return r.list(ctx, `SELECT `+grantColumns+` FROM break_glass_grants
+		WHERE tenant_id = $1 ORDER BY granted_at DESC LIMIT $2`, tenantID, limit)
+}
+
+// RecordUse counts a request under an active grant. It returns
+// ErrGrantInactive when the grant does not match or has ended.
+func (r *Repository) RecordUse(ctx context.Context, id, adminUserID, tenantID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE break_glass_grants SET request_count = request_count + 1, last_used_at = NOW()
+		WHERE id = $1 AND admin_user_id = $2 AND tenant_id = $3
+			AND revoked_at IS NULL AND ended_at IS NULL AND expires_at > NOW()
+	`, id, adminUserID, tenantID)
+	if err != nil {
+		return fmt.Errorf("record break-glass use: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrGrantInactive
+	}
+	return nil
+}
+
+// Revoke ends an open grant early
+func (r *Repository) Revoke(ctx context.Context, id, revokedBy uuid.UUID) (*Grant, error) {
+	g, err := scanGrant(r.pool.QueryRow(ctx, `
+		UPDATE break_glass_grants SET revoked_at = NOW(), revoked_by = $2, ended_at = NOW()
+		WHERE id = $1 AND ended_at IS NULL
+		RETURNING `+grantColumns, id, revokedBy))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrGrantInactive
+	}
+	return g, err
+}
+
+// ClaimExpired marks expired grants as ended and returns them. Each grant is
+// claimed once, so concurrent servers notify about it only once.
+func (r *Repository) ClaimExpired(ctx context.Context, limit int) ([]*Grant, error) {
+	return r.list(ctx, `
+		UPDATE break_glass_grants SET ended_at = NOW()
+		WHERE id IN (
+			SELECT id FROM break_glass_grants
+			WHERE ended_at IS NULL AND expires_at <= NOW()
+			ORDER BY expires_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+grantColumns, limit)
+}
+
+// getAdmin loads the super-admin's user record
+func (r *Repository) getAdmin(ctx context.Context, userID uuid.UUID) (*adminAccount, error) {
+	a := &adminAccount{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT name, COALESCE(totp_enabled, false), is_active FROM users WHERE id = $1
+	`, userID).Scan(&a.Name, &a.TOTPEnabled, &a.Active)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotSuperAdmin
+	}
+	return a, err
+}
+
+// tenantName returns the name of a tenant
+func (r *Repository) tenantName(ctx context.Context, tenantID uuid.UUID) (string, error) {
+	var name string
+	err := r.pool.QueryRow(ctx, `SELECT name FROM tenants WHERE id = $1`, tenantID).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrTenantNotFound
+	}
+	return name, err
+}
+
+// tenantAdmins returns the active owners and admins of a tenant
+func (r *Repository) tenantAdmins(ctx context.Context, tenantID uuid.UUID) ([]Recipient, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT name, email FROM users
+		WHERE tenant_id = $1 AND is_active = true AND role IN ('owner', 'admin')
+		ORDER BY role DESC, name
+	`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipients []Recipient
+	for rows.Next() {
+		var rc Recipient
+		if err := rows.Scan(&rc.Name, &rc.Email); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, rc)
+	}
+	return recipients, rows.Err()
+}
+
+func (r *Repository) list(ctx context.Context, query string, args ...interface{}) ([]*Grant, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []*Grant
+	for rows.Next() {
+		g, err := scanGrant(rows)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, g)
+	}
+	return grants, rows.Err()
+}
+
+func scanGrant(row pgx.Row) (*Grant, error) {
+	g := &Grant{}
+	err := row.Scan(&g.ID, &g.TenantID, &g.AdminUserID, &g.AdminName, &g.Reason, &g.Role,
+		&g.GrantedAt, &g.ExpiresAt, &g.RevokedAt, &g.RevokedBy, &g.EndedAt,
+		&g.RequestCount, &g.LastUsedAt, &g.Active)
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
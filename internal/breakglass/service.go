@@ -0,0 +1,489 @@
+package breakglass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/audit"
+	"austrian-business-infrastructure/internal/auth"
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/timezone"
+	"github.com/google/uuid"
+)
+
+// Audit log actions written to the tenant's audit log
+const (
+	ActionGranted = "break_glass_granted"
+	ActionAccess  = "break_glass_access"
+	ActionRevoked = "break_glass_revoked"
+	ActionExpired = "break_glass_expired"
+)
+
+// ResourceGrant is the audit resource type of break-glass grants
+const ResourceGrant = "break_glass_grant"
+
+// Bounds of the reason and the duration of a grant
+const (
+	MinReasonLength = 20
+	MaxReasonLength = 2000
+	MinDuration     = 5 * time.Minute
+)
+
+// grantRole is the tenant role held during break-glass access
+const grantRole = "admin"
+
+var (
+	ErrNotSuperAdmin     = errors.New("break-glass access requires a super-admin")
+	ErrNested            = errors.New("break-glass access cannot be granted from a break-glass session")
+	ErrTwoFactorRequired = errors.New("break-glass access requires two-factor authentication")
+	ErrOwnTenant         = errors.New("break-glass access to the own tenant is not needed")
+	ErrReasonRequired    = fmt.Errorf("reason must be between %d and %d characters", MinReasonLength, MaxReasonLength)
+	ErrInvalidDuration   = errors.New("duration is out of range")
+	ErrTenantNotFound    = errors.New("tenant not found")
+	ErrGrantActive       = errors.New("an active break-glass grant for this tenant exists")
+	ErrGrantNotFound     = errors.New("break-glass grant not found")
+	ErrGrantInactive     = errors.New("break-glass grant is not active")
+)
+
+// TokenIssuer signs the access token of a grant; *auth.JWTManager
+// implements it
+type TokenIssuer interface {
+	GenerateBreakGlassToken(user *auth.UserInfo, expiry time.Time) (string, error)
+}
+
+// Notifier sends the notifications to tenant admins; email.Service
+// implements it
+type Notifier interface {
+	SendBreakGlassStarted(ctx context.Context, to string, params email.BreakGlassParams) error
+	SendBreakGlassEnded(ctx context.Context, to string, params email.BreakGlassParams) error
+}
+
+// Config configures break-glass access
+type Config struct {
+	// SuperAdmins are the users allowed to grant themselves access
+	SuperAdmins     []uuid.UUID
+	DefaultDuration time.Duration
+	MaxDuration     time.Duration
+	Logger          *slog.Logger
+}
+
+// Service grants, verifies and ends break-glass access
+type Service struct {
+	repo     *Repository
+	tokens   TokenIssuer
+	auditLog *audit.Logger
+	notifier Notifier
+	security *audit.SecurityAuditor
+	cfg      Config
+	admins   map[uuid.UUID]bool
+	logger   *slog.Logger
+}
+
+// NewService creates a break-glass service. Every grant and every request
+// under it is written to the tenant's audit log.
+func NewService(repo *Repository, tokens TokenIssuer, auditLog *audit.Logger, cfg Config) *Service {
+	if cfg.DefaultDuration <= 0 {
+		cfg.DefaultDuration = time.Hour
+	}
+	if cfg.MaxDuration <= 0 {
+		cfg.MaxDuration = 4 * time.Hour
+	}
+	if cfg.DefaultDuration > cfg.MaxDuration {
+		cfg.DefaultDuration = cfg.MaxDuration
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	admins := make(map[uuid.UUID]bool, len(cfg.SuperAdmins))
+	for _, id := range cfg.SuperAdmins {
+		admins[id] = true
+	}
+	return &Service{
+		repo:     repo,
+		tokens:   tokens,
+		auditLog: auditLog,
+		cfg:      cfg,
+		admins:   admins,
+		logger:   logger,
+	}
+}
+
+// SetNotifier enables the notifications to tenant admins
+func (s *Service) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
+// SetSecurityAuditor reports grants and their end as security events
+func (s *Service) SetSecurityAuditor(auditor *audit.SecurityAuditor) {
+	s.security = auditor
+}
+
+// IsSuperAdmin reports whether a user may use break-glass access
+func (s *Service) IsSuperAdmin(userID uuid.UUID) bool {
+	return s.admins[userID]
+}
+
+// GrantRequest asks for break-glass access to a tenant
+type GrantRequest struct {
+	TenantID        uuid.UUID `json:"tenant_id"`
+	Reason          string    `json:"reason"`
+	DurationMinutes int       `json:"duration_minutes,omitempty"`
+}
+
+// GrantResult is a new grant with its access token
+type GrantResult struct {
+	Grant       *Grant    `json:"grant"`
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Caller identifies who asks for or ends a grant
+type Caller struct {
+	UserID   uuid.UUID
+	TenantID uuid.UUID
+	Role     string
+	// BreakGlass is set when the caller itself uses a break-glass token
+	BreakGlass bool
+}
+
+// Duration returns the validated duration of a request
+func (s *Service) Duration(minutes int) (time.Duration, error) {
+	if minutes == 0 {
+		return s.cfg.DefaultDuration, nil
+	}
+	d := time.Duration(minutes) * time.Minute
+	if d < MinDuration || d > s.cfg.MaxDuration {
+		return 0, fmt.Errorf("%w: between %d and %d minutes", ErrInvalidDuration,
+			int(MinDuration/time.Minute), int(s.cfg.MaxDuration/time.Minute))
+	}
+	return d, nil
+}
+
+// ValidateReason checks and normalizes the reason of a request
+func ValidateReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if n := utf8.RuneCountInString(reason); n < MinReasonLength || n > MaxReasonLength {
+		return "", ErrReasonRequired
+	}
+	return reason, nil
+}
+
+// Grant gives a super-admin time-boxed admin access to a tenant and returns
+// the token to use it. The tenant's admins are notified.
+func (s *Service) Grant(ctx context.Context, caller Caller, req *GrantRequest, r *http.Request) (*GrantResult, error) {
+	if !s.IsSuperAdmin(caller.UserID) {
+		return nil, ErrNotSuperAdmin
+	}
+	if caller.BreakGlass {
+		return nil, ErrNested
+	}
+	if req.TenantID == caller.TenantID {
+		return nil, ErrOwnTenant
+	}
+	reason, err := ValidateReason(req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	duration, err := s.Duration(req.DurationMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := s.repo.getAdmin(ctx, caller.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin.Active {
+		return nil, ErrNotSuperAdmin
+	}
+	if !admin.TOTPEnabled {
+		return nil, ErrTwoFactorRequired
+	}
+	tenantName, err := s.repo.tenantName(ctx, req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	active, err := s.repo.HasActive(ctx, caller.UserID, req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, ErrGrantActive
+	}
+
+	grant := &Grant{
+		TenantID:    req.TenantID,
+		AdminUserID: caller.UserID,
+		AdminName:   admin.Name,
+		Reason:      reason,
+		Role:        grantRole,
+		ExpiresAt:   time.Now().Add(duration).Truncate(time.Second),
+		Active:      true,
+	}
+	if err := s.repo.Create(ctx, grant); err != nil {
+		return nil, fmt.Errorf("create break-glass grant: %w", err)
+	}
+
+	token, err := s.tokens.GenerateBreakGlassToken(&auth.UserInfo{
+		UserID:     caller.UserID.String(),
+		TenantID:   grant.TenantID.String(),
+		Role:       grant.Role,
+		BreakGlass: grant.ID.String(),
+	}, grant.ExpiresAt)
+	if err != nil {
+		// Without a token the grant cannot be used; end it right away
+		_, _ = s.repo.Revoke(ctx, grant.ID, caller.UserID)
+		return nil, fmt.Errorf("issue break-glass token: %w", err)
+	}
+
+	if err := s.audit(ctx, r, grant, ActionGranted, map[string]interface{}{
+		"reason":     grant.Reason,
+		"admin_name": grant.AdminName,
+		"expires_at": grant.ExpiresAt,
+	}); err != nil {
+		_, _ = s.repo.Revoke(ctx, grant.ID, caller.UserID)
+		return nil, err
+	}
+	s.logger.Warn("break-glass access granted",
+		"grant_id", grant.ID,
+		"tenant_id", grant.TenantID,
+		"admin_user_id", grant.AdminUserID,
+		"expires_at", grant.ExpiresAt)
+	if s.security != nil {
+		event := s.securityEvent(grant, audit.EventBreakGlassGranted, "Break-glass access to tenant granted")
+		if r != nil {
+			event.IPAddress = auth.GetClientIP(r)
+			event.UserAgent = r.UserAgent()
+			event.RequestID = api.GetRequestID(ctx)
+		}
+		s.security.Log(ctx, event)
+	}
+	s.notify(grant, tenantName, true)
+
+	return &GrantResult{
+		Grant:       grant,
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresAt:   grant.ExpiresAt,
+	}, nil
+}
+
+// VerifyBreakGlass checks that the grant of a break-glass token is still
+// active and writes the request to the tenant's audit log. It implements
+// auth.BreakGlassVerifier; a request that cannot be audited is refused.
+func (s *Service) VerifyBreakGlass(ctx context.Context, grantID, userID, tenantID string, r *http.Request) error {
+	gid, err := uuid.Parse(grantID)
+	if err != nil {
+		return ErrGrantInactive
+	}
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return ErrGrantInactive
+	}
+	tid, err := uuid.Parse(tenantID)
+	if err != nil {
+		return ErrGrantInactive
+	}
+	if err := s.repo.RecordUse(ctx, gid, uid, tid); err != nil {
+		return err
+	}
+
+	grant := &Grant{ID: gid, TenantID: tid, AdminUserID: uid}
+	return s.audit(ctx, r, grant, ActionAccess, map[string]interface{}{
+		"method": r.Method,
+		"path":   r.URL.Path,
+	})
+}
+
+// Revoke ends a grant early. The super-admin who holds it and the admins of
+// the tenant may revoke it.
+func (s *Service) Revoke(ctx context.Context, caller Caller, grantID uuid.UUID, r *http.Request) (*Grant, error) {
+	grant, err := s.repo.GetByID(ctx, grantID)
+	if err != nil {
+		return nil, err
+	}
+	holder := grant.AdminUserID == caller.UserID
+	tenantAdmin := !caller.BreakGlass && grant.TenantID == caller.TenantID && (caller.Role == "owner" || caller.Role == "admin")
+	if !holder && !tenantAdmin {
+		// Do not reveal grants on other tenants
+		return nil, ErrGrantNotFound
+	}
+
+	grant, err = s.repo.Revoke(ctx, grantID, caller.UserID)
+	if err != nil {
+		return nil, err
+	}
+	s.end(ctx, r, grant, ActionRevoked)
+	return grant, nil
+}
+
+// ListForAdmin returns the grants of a super-admin
+func (s *Service) ListForAdmin(ctx context.Context, adminUserID uuid.UUID) ([]*Grant, error) {
+	if !s.IsSuperAdmin(adminUserID) {
+		return nil, ErrNotSuperAdmin
+	}
+	return s.repo.ListByAdmin(ctx, adminUserID, 100)
+}
+
+// ListForTenant returns the grants on a tenant
+func (s *Service) ListForTenant(ctx context.Context, tenantID uuid.UUID) ([]*Grant, error) {
+	return s.repo.ListByTenant(ctx, tenantID, 100)
+}
+
+// ExpireDue ends the grants past their expiry, audits the end and notifies
+// the tenant admins. It returns the number of grants ended.
+func (s *Service) ExpireDue(ctx context.Context) (int, error) {
+	grants, err := s.repo.ClaimExpired(ctx, 100)
+	if err != nil {
+		return 0, fmt.Errorf("claim expired break-glass grants: %w", err)
+	}
+	for _, grant := range grants {
+		s.end(ctx, nil, grant, ActionExpired)
+	}
+	return len(grants), nil
+}
+
+// Run ends expired grants every interval until ctx is cancelled
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ExpireDue(ctx); err != nil {
+				s.logger.Error("failed to end expired break-glass grants", "error", err)
+			}
+		}
+	}
+}
+
+// end audits the end of a grant and notifies the tenant admins
+func (s *Service) end(ctx context.Context, r *http.Request, grant *Grant, action string) {
+	details := map[string]interface{}{
+		"reason":        grant.Reason,
+		"admin_name":    grant.AdminName,
+		"request_count": grant.RequestCount,
+	}
+	if grant.RevokedBy != nil {
+		details["revoked_by"] = grant.RevokedBy.String()
+	}
+	if err := s.audit(ctx, r, grant, action, details); err != nil {
+		s.logger.Error("failed to audit end of break-glass access", "grant_id", grant.ID, "error", err)
+	}
+	s.logger.Warn("break-glass access ended",
+		"grant_id", grant.ID,
+		"tenant_id", grant.TenantID,
+		"admin_user_id", grant.AdminUserID,
+		"action", action,
+		"request_count", grant.RequestCount)
+	if s.security != nil {
+		event := s.securityEvent(grant, audit.EventBreakGlassEnded, "Break-glass access to tenant ended")
+		event.Action = action
+		event.Metadata["request_count"] = fmt.Sprint(grant.RequestCount)
+		s.security.Log(ctx, event)
+	}
+
+	tenantName, err := s.repo.tenantName(ctx, grant.TenantID)
+	if err != nil {
+		s.logger.Error("failed to load tenant for break-glass notification", "grant_id", grant.ID, "error", err)
+		return
+	}
+	s.notify(grant, tenantName, false)
+}
+
+// audit writes a break-glass entry to the tenant's audit log, attributed to
+// the super-admin
+func (s *Service) audit(ctx context.Context, r *http.Request, grant *Grant, action string, details map[string]interface{}) error {
+	logCtx := &audit.LogContext{}
+	if r != nil {
+		logCtx = audit.ContextFromRequest(r)
+	}
+	resourceType := ResourceGrant
+	logCtx.TenantID = &grant.TenantID
+	logCtx.UserID = &grant.AdminUserID
+	logCtx.ResourceType = &resourceType
+	logCtx.ResourceID = &grant.ID
+
+	details["grant_id"] = grant.ID.String()
+	details["break_glass"] = true
+	if err := s.auditLog.Log(ctx, logCtx, action, details); err != nil {
+		return fmt.Errorf("audit break-glass access: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) securityEvent(grant *Grant, eventType, message string) *audit.SecurityEvent {
+	return &audit.SecurityEvent{
+		EventType: eventType,
+		Severity:  audit.SeverityCritical,
+		Outcome:   audit.OutcomeSuccess,
+		TenantID:  grant.TenantID.String(),
+		UserID:    grant.AdminUserID.String(),
+		Resource:  ResourceGrant,
+		Message:   message,
+		Metadata: map[string]string{
+			"grant_id":   grant.ID.String(),
+			"reason":     grant.Reason,
+			"expires_at": grant.ExpiresAt.UTC().Format(time.RFC3339),
+		},
+	}
+}
+
+// notify mails the tenant's admins in the background
+func (s *Service) notify(grant *Grant, tenantName string, started bool) {
+	if s.notifier == nil {
+		return
+	}
+	params := email.BreakGlassParams{
+		TenantName:   tenantName,
+		AdminName:    grant.AdminName,
+		Reason:       grant.Reason,
+		GrantedAt:    formatViennaTime(grant.GrantedAt),
+		ExpiresAt:    formatViennaTime(grant.ExpiresAt),
+		Revoked:      grant.RevokedAt != nil,
+		RequestCount: grant.RequestCount,
+	}
+	if grant.EndedAt != nil {
+		params.EndedAt = formatViennaTime(*grant.EndedAt)
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		recipients, err := s.repo.tenantAdmins(ctx, grant.TenantID)
+		if err != nil {
+			s.logger.Error("failed to load tenant admins for break-glass notification", "grant_id", grant.ID, "error", err)
+			return
+		}
+		for _, rc := range recipients {
+			p := params
+			p.RecipientName = rc.Name
+			if started {
+				err = s.notifier.SendBreakGlassStarted(ctx, rc.Email, p)
+			} else {
+				err = s.notifier.SendBreakGlassEnded(ctx, rc.Email, p)
+			}
+			if err != nil {
+				s.logger.Error("failed to send break-glass notification", "grant_id", grant.ID, "error", err)
+			}
+		}
+	}()
+}
+
+// formatViennaTime formats a time for German emails in Austrian local time
+func formatViennaTime(t time.Time) string {
+	return t.In(timezone.Vienna).Format("02.01.2006 um 15:04 Uhr")
+}
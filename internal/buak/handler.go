@@ -0,0 +1,474 @@
+package buak
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/eldameldung"
+)
+
+// Handler handles HTTP requests for BUAK operations
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new BUAK HTTP handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers BUAK routes with the router
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/buak", func(r chi.Router) {
+		// Workers (on top of the ELDA Anmeldungen)
+		r.Post("/arbeitnehmer", h.RegisterArbeitnehmer)
+		r.Get("/arbeitnehmer", h.ListArbeitnehmer)
+		r.Post("/arbeitnehmer/sync", h.SyncArbeitnehmer)
+		r.Get("/arbeitnehmer/{id}", h.GetArbeitnehmer)
+		r.Put("/arbeitnehmer/{id}", h.UpdateArbeitnehmer)
+		r.Delete("/arbeitnehmer/{id}", h.DeleteArbeitnehmer)
+
+		// Zuschlagsmeldungen
+		r.Post("/meldungen", h.CreateMeldung)
+		r.Get("/meldungen", h.ListMeldungen)
+		r.Get("/meldungen/{id}", h.GetMeldung)
+		r.Post("/meldungen/{id}/validate", h.Validate)
+		r.Get("/meldungen/{id}/preview", h.PreviewXML)
+		r.Post("/meldungen/{id}/send", h.Submit)
+		r.Post("/meldungen/{id}/status", h.RefreshStatus)
+		r.Post("/meldungen/{id}/confirm", h.Confirm)
+		r.Delete("/meldungen/{id}", h.DeleteMeldung)
+
+		// Reference data
+		r.Get("/deadline", h.GetDeadline)
+		r.Get("/zuschlagssaetze", h.GetZuschlagssaetze)
+	})
+}
+
+// RegisterArbeitnehmer handles POST /api/v1/buak/arbeitnehmer
+func (h *Handler) RegisterArbeitnehmer(w http.ResponseWriter, r *http.Request) {
+	var req elda.BUAKArbeitnehmerCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if !h.ownsAccount(w, r, req.ELDAAccountID) {
+		return
+	}
+
+	a, err := h.service.RegisterArbeitnehmer(r.Context(), &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to register BUAK worker")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusCreated, a)
+}
+
+// ListArbeitnehmer handles GET /api/v1/buak/arbeitnehmer
+func (h *Handler) ListArbeitnehmer(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	workers, err := h.service.ListArbeitnehmer(r.Context(), accountID)
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusInternalServerError, "Failed to list BUAK workers", err)
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"arbeitnehmer": workers,
+		"count":        len(workers),
+	})
+}
+
+// SyncArbeitnehmer handles POST /api/v1/buak/arbeitnehmer/sync
+func (h *Handler) SyncArbeitnehmer(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.SyncFromELDA(r.Context(), accountID)
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusInternalServerError, "Failed to sync BUAK workers", err)
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, result)
+}
+
+// GetArbeitnehmer handles GET /api/v1/buak/arbeitnehmer/{id}
+func (h *Handler) GetArbeitnehmer(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.arbeitnehmerID(w, r)
+	if !ok {
+		return
+	}
+
+	a, err := h.service.GetArbeitnehmer(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err, "Failed to get BUAK worker")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, a)
+}
+
+// UpdateArbeitnehmer handles PUT /api/v1/buak/arbeitnehmer/{id}
+func (h *Handler) UpdateArbeitnehmer(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.arbeitnehmerID(w, r)
+	if !ok {
+		return
+	}
+
+	var req elda.BUAKArbeitnehmerCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	a, err := h.service.UpdateArbeitnehmer(r.Context(), id, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to update BUAK worker")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, a)
+}
+
+// DeleteArbeitnehmer handles DELETE /api/v1/buak/arbeitnehmer/{id}
+func (h *Handler) DeleteArbeitnehmer(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.arbeitnehmerID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteArbeitnehmer(r.Context(), id); err != nil {
+		h.handleError(w, err, "Failed to delete BUAK worker")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateMeldung handles POST /api/v1/buak/meldungen
+func (h *Handler) CreateMeldung(w http.ResponseWriter, r *http.Request) {
+	var req elda.BUAKMeldungCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if !h.ownsAccount(w, r, req.ELDAAccountID) {
+		return
+	}
+
+	m, err := h.service.CreateMeldung(r.Context(), &req, api.OptionalUser(r))
+	if err != nil {
+		h.handleError(w, err, "Failed to create BUAK Zuschlagsmeldung")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusCreated, m)
+}
+
+// ListMeldungen handles GET /api/v1/buak/meldungen
+func (h *Handler) ListMeldungen(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var year *int
+	if v := r.URL.Query().Get("year"); v != "" {
+		if y, err := strconv.Atoi(v); err == nil {
+			year = &y
+		}
+	}
+
+	meldungen, err := h.service.ListMeldungen(r.Context(), accountID, year)
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusInternalServerError, "Failed to list BUAK Zuschlagsmeldungen", err)
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"meldungen": meldungen,
+		"count":     len(meldungen),
+	})
+}
+
+// GetMeldung handles GET /api/v1/buak/meldungen/{id}
+func (h *Handler) GetMeldung(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.meldungID(w, r)
+	if !ok {
+		return
+	}
+
+	m, err := h.service.GetMeldung(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err, "Failed to get BUAK Zuschlagsmeldung")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, m)
+}
+
+// Validate handles POST /api/v1/buak/meldungen/{id}/validate
+func (h *Handler) Validate(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.meldungID(w, r)
+	if !ok {
+		return
+	}
+
+	m, err := h.service.Validate(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err, "Validation failed")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, m)
+}
+
+// PreviewXML handles GET /api/v1/buak/meldungen/{id}/preview
+func (h *Handler) PreviewXML(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.meldungID(w, r)
+	if !ok {
+		return
+	}
+
+	dienstgeberNr := r.URL.Query().Get("dienstgeber_nr")
+	if dienstgeberNr == "" {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "dienstgeber_nr is required", nil)
+		return
+	}
+
+	xmlData, err := h.service.PreviewXML(r.Context(), id, dienstgeberNr)
+	if err != nil {
+		h.handleError(w, err, "Failed to generate preview")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(xmlData)
+}
+
+// Submit handles POST /api/v1/buak/meldungen/{id}/send
+func (h *Handler) Submit(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.meldungID(w, r)
+	if !ok {
+		return
+	}
+
+	dienstgeberNr, ok := dienstgeberNrBody(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Submit(r.Context(), id, dienstgeberNr)
+	if err != nil {
+		if result != nil {
+			api.RespondJSON(w, http.StatusOK, result) // Return result even on submission error
+			return
+		}
+		h.handleError(w, err, "Failed to submit BUAK Zuschlagsmeldung")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, result)
+}
+
+// RefreshStatus handles POST /api/v1/buak/meldungen/{id}/status
+func (h *Handler) RefreshStatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.meldungID(w, r)
+	if !ok {
+		return
+	}
+
+	dienstgeberNr, ok := dienstgeberNrBody(w, r)
+	if !ok {
+		return
+	}
+
+	m, err := h.service.RefreshStatus(r.Context(), id, dienstgeberNr)
+	if err != nil {
+		h.handleError(w, err, "Failed to query BUAK status")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, m)
+}
+
+// Confirm handles POST /api/v1/buak/meldungen/{id}/confirm
+func (h *Handler) Confirm(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.meldungID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	m, err := h.service.Confirm(r.Context(), id, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to confirm BUAK Zuschlagsmeldung")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, m)
+}
+
+// DeleteMeldung handles DELETE /api/v1/buak/meldungen/{id}
+func (h *Handler) DeleteMeldung(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.meldungID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteMeldung(r.Context(), id); err != nil {
+		h.handleError(w, err, "Failed to delete BUAK Zuschlagsmeldung")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeadline handles GET /api/v1/buak/deadline
+func (h *Handler) GetDeadline(w http.ResponseWriter, r *http.Request) {
+	year, errYear := strconv.Atoi(r.URL.Query().Get("year"))
+	month, errMonth := strconv.Atoi(r.URL.Query().Get("month"))
+	if errYear != nil || errMonth != nil || month < 1 || month > 12 {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "year and month are required", nil)
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"year":     year,
+		"month":    month,
+		"deadline": elda.GetBUAKDeadline(year, month),
+	})
+}
+
+// GetZuschlagssaetze handles GET /api/v1/buak/zuschlagssaetze
+func (h *Handler) GetZuschlagssaetze(w http.ResponseWriter, r *http.Request) {
+	year, err := strconv.Atoi(r.URL.Query().Get("year"))
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "year is required", err)
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"year":               year,
+		"zuschlagssaetze_bp": elda.GetBUAKZuschlagssaetze(year),
+	})
+}
+
+// Helper functions
+
+func (h *Handler) handleError(w http.ResponseWriter, err error, message string) {
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		api.RespondJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":  "Validation failed",
+			"errors": validationErr.Errors,
+		})
+	case errors.Is(err, ErrArbeitnehmerNotFound), errors.Is(err, ErrMeldungNotFound),
+		errors.Is(err, eldameldung.ErrMeldungNotFound), errors.Is(err, ErrAccountNotFound):
+		api.RespondErrorWithDetails(w, http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, ErrDuplicate), errors.Is(err, ErrDuplicateArbeitnehmer),
+		errors.Is(err, ErrNotDraft), errors.Is(err, ErrNotSubmitted):
+		api.RespondErrorWithDetails(w, http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrNotAnmeldung), errors.Is(err, ErrNoArbeitnehmer):
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, err.Error(), err)
+	default:
+		api.RespondErrorWithDetails(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+// accountIDParam parses the elda_account_id query parameter of an ELDA
+// account of the request's tenant
+func (h *Handler) accountIDParam(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	accountID, err := uuid.Parse(r.URL.Query().Get("elda_account_id"))
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "elda_account_id is required", err)
+		return uuid.Nil, false
+	}
+	return accountID, h.ownsAccount(w, r, accountID)
+}
+
+// ownsAccount reports whether an ELDA account is one of the request's
+// tenant's and responds 404 if not
+func (h *Handler) ownsAccount(w http.ResponseWriter, r *http.Request, eldaAccountID uuid.UUID) bool {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return false
+	}
+	ok, err := h.service.OwnsELDAAccount(r.Context(), tenantID, eldaAccountID)
+	if err != nil {
+		h.handleError(w, err, "Failed to check ELDA account")
+		return false
+	}
+	if !ok {
+		h.handleError(w, ErrAccountNotFound, "")
+		return false
+	}
+	return true
+}
+
+// arbeitnehmerID parses the ID of a worker of the request's tenant
+func (h *Handler) arbeitnehmerID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid worker ID", err)
+		return uuid.Nil, false
+	}
+	a, err := h.service.GetArbeitnehmer(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err, "Failed to get BUAK worker")
+		return uuid.Nil, false
+	}
+	return id, h.ownsAccount(w, r, a.ELDAAccountID)
+}
+
+// meldungID parses the ID of a Zuschlagsmeldung of the request's tenant
+func (h *Handler) meldungID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid Zuschlagsmeldung ID", err)
+		return uuid.Nil, false
+	}
+	m, err := h.service.GetMeldung(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err, "Failed to get BUAK Zuschlagsmeldung")
+		return uuid.Nil, false
+	}
+	return id, h.ownsAccount(w, r, m.ELDAAccountID)
+}
+
+func dienstgeberNrBody(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req struct {
+		DienstgeberNr string `json:"dienstgeber_nr"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid request body", err)
+		return "", false
+	}
+	if req.DienstgeberNr == "" {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "dienstgeber_nr is required", nil)
+		return "", false
+	}
+	return req.DienstgeberNr, true
+}
@@ -0,0 +1,432 @@
+package buak
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/internal/elda"
+)
+
+var (
+	ErrArbeitnehmerNotFound  = errors.New("BUAK worker not found")
+	ErrMeldungNotFound       = errors.New("BUAK Zuschlagsmeldung not found")
+	ErrDuplicateArbeitnehmer = errors.New("BUAK worker already registered for this SV-Nummer")
+	ErrDuplicate             = errors.New("BUAK Zuschlagsmeldung already exists for this period")
+)
+
+// Repository handles BUAK database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new BUAK repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const arbeitnehmerColumns = `
+	id, elda_account_id, meldung_id, sv_nummer, vorname, nachname, buak_nummer,
+	kategorie, taetigkeit, stundenlohn, wochenstunden, eintrittsdatum, austrittsdatum,
+	ueberlassung, created_at, updated_at`
+
+// CreateArbeitnehmer creates a new BUAK worker
+func (r *Repository) CreateArbeitnehmer(ctx context.Context, a *elda.BUAKArbeitnehmer) error {
+	ueberlassungJSON, err := marshalUeberlassung(a.Ueberlassung)
+	if err != nil {
+		return err
+	}
+
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	now := time.Now()
+	a.CreatedAt = now
+	a.UpdatedAt = now
+
+	query := `
+		INSERT INTO buak_arbeitnehmer (` + arbeitnehmerColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+
+	_, err = r.db.Exec(ctx, query,
+		a.ID, a.ELDAAccountID, a.MeldungID, a.SVNummer, a.Vorname, a.Nachname, a.BUAKNummer,
+		a.Kategorie, a.Taetigkeit, a.Stundenlohn, a.Wochenstunden, a.Eintrittsdatum, a.Austrittsdatum,
+		ueberlassungJSON, a.CreatedAt, a.UpdatedAt,
+	)
+	if err != nil {
+		if isDuplicateError(err) {
+			return ErrDuplicateArbeitnehmer
+		}
+		return fmt.Errorf("create BUAK worker: %w", err)
+	}
+
+	return nil
+}
+
+// GetArbeitnehmer retrieves a BUAK worker by ID
+func (r *Repository) GetArbeitnehmer(ctx context.Context, id uuid.UUID) (*elda.BUAKArbeitnehmer, error) {
+	query := `SELECT ` + arbeitnehmerColumns + ` FROM buak_arbeitnehmer WHERE id = $1`
+
+	a, err := scanArbeitnehmer(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrArbeitnehmerNotFound
+		}
+		return nil, fmt.Errorf("get BUAK worker: %w", err)
+	}
+
+	return a, nil
+}
+
+// ListArbeitnehmer lists the BUAK workers of an ELDA account
+func (r *Repository) ListArbeitnehmer(ctx context.Context, accountID uuid.UUID) ([]*elda.BUAKArbeitnehmer, error) {
+	query := `SELECT ` + arbeitnehmerColumns + `
+		FROM buak_arbeitnehmer
+		WHERE elda_account_id = $1
+		ORDER BY nachname, vorname`
+
+	rows, err := r.db.Query(ctx, query, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("list BUAK workers: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*elda.BUAKArbeitnehmer
+	for rows.Next() {
+		a, err := scanArbeitnehmer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan BUAK worker: %w", err)
+		}
+		results = append(results, a)
+	}
+
+	return results, rows.Err()
+}
+
+// UpdateArbeitnehmer updates a BUAK worker
+func (r *Repository) UpdateArbeitnehmer(ctx context.Context, a *elda.BUAKArbeitnehmer) error {
+	ueberlassungJSON, err := marshalUeberlassung(a.Ueberlassung)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE buak_arbeitnehmer SET
+			buak_nummer = $2,
+			kategorie = $3,
+			taetigkeit = $4,
+			stundenlohn = $5,
+			wochenstunden = $6,
+			austrittsdatum = $7,
+			ueberlassung = $8,
+			updated_at = $9
+		WHERE id = $1
+	`
+
+	a.UpdatedAt = time.Now()
+
+	result, err := r.db.Exec(ctx, query,
+		a.ID, a.BUAKNummer, a.Kategorie, a.Taetigkeit, a.Stundenlohn, a.Wochenstunden,
+		a.Austrittsdatum, ueberlassungJSON, a.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("update BUAK worker: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrArbeitnehmerNotFound
+	}
+
+	return nil
+}
+
+// DeleteArbeitnehmer deletes a BUAK worker
+func (r *Repository) DeleteArbeitnehmer(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM buak_arbeitnehmer WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete BUAK worker: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrArbeitnehmerNotFound
+	}
+
+	return nil
+}
+
+// CreateMeldung creates a Zuschlagsmeldung with its positions
+func (r *Repository) CreateMeldung(ctx context.Context, m *elda.BUAKMeldung) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	now := time.Now()
+	m.CreatedAt = now
+	m.UpdatedAt = now
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO buak_meldungen (
+			id, elda_account_id, year, month, status,
+			total_arbeitnehmer, total_lohnsumme, total_zuschlag,
+			created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err = tx.Exec(ctx, query,
+		m.ID, m.ELDAAccountID, m.Year, m.Month, m.Status,
+		m.TotalArbeitnehmer, m.TotalLohnsumme, m.TotalZuschlag,
+		m.CreatedBy, m.CreatedAt, m.UpdatedAt,
+	)
+	if err != nil {
+		if isDuplicateError(err) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("create BUAK Zuschlagsmeldung: %w", err)
+	}
+
+	posQuery := `
+		INSERT INTO buak_positionen (
+			id, meldung_id, arbeitnehmer_id, sv_nummer, vorname, nachname, buak_nummer, kategorie,
+			stunden, stundenlohn, lohnsumme,
+			zuschlag_urlaub, zuschlag_abfertigung, zuschlag_schlechtwetter, zuschlag_ueberbrueckung,
+			beschaeftiger_name, beschaeftiger_uid, position_index, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+	`
+
+	for _, p := range m.Positionen {
+		if p.ID == uuid.Nil {
+			p.ID = uuid.New()
+		}
+		p.MeldungID = m.ID
+		p.CreatedAt = now
+
+		_, err := tx.Exec(ctx, posQuery,
+			p.ID, p.MeldungID, p.ArbeitnehmerID, p.SVNummer, p.Vorname, p.Nachname, p.BUAKNummer, p.Kategorie,
+			p.Stunden, p.Stundenlohn, p.Lohnsumme,
+			p.Zuschlaege.Urlaub, p.Zuschlaege.Abfertigung, p.Zuschlaege.Schlechtwetter, p.Zuschlaege.Ueberbrueckung,
+			p.BeschaeftigerName, p.BeschaeftigerUID, p.PositionIndex, p.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("create BUAK position: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+const meldungColumns = `
+	id, elda_account_id, year, month, status, protokollnummer,
+	total_arbeitnehmer, total_lohnsumme, total_zuschlag,
+	submitted_at, confirmed_at, confirmation_number, request_xml, response_xml,
+	error_message, error_code, created_by, created_at, updated_at`
+
+// GetMeldung retrieves a Zuschlagsmeldung including its positions
+func (r *Repository) GetMeldung(ctx context.Context, id uuid.UUID) (*elda.BUAKMeldung, error) {
+	query := `SELECT ` + meldungColumns + ` FROM buak_meldungen WHERE id = $1`
+
+	m, err := scanMeldung(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMeldungNotFound
+		}
+		return nil, fmt.Errorf("get BUAK Zuschlagsmeldung: %w", err)
+	}
+
+	positionen, err := r.getPositionen(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	m.Positionen = positionen
+
+	return m, nil
+}
+
+// ELDAAccountBelongsToTenant reports whether an ELDA account is one of the
+// tenant's
+func (r *Repository) ELDAAccountBelongsToTenant(ctx context.Context, tenantID, eldaAccountID uuid.UUID) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM elda_accounts ea JOIN accounts a ON a.id = ea.account_id
+			WHERE ea.id = $1 AND a.tenant_id = $2)`, eldaAccountID, tenantID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("failed to check ELDA account: %w", err)
+	}
+	return ok, nil
+}
+
+// ListMeldungen lists the Zuschlagsmeldungen of an ELDA account
+func (r *Repository) ListMeldungen(ctx context.Context, accountID uuid.UUID, year *int) ([]*elda.BUAKMeldung, error) {
+	query := `SELECT ` + meldungColumns + ` FROM buak_meldungen WHERE elda_account_id = $1`
+	args := []interface{}{accountID}
+
+	if year != nil {
+		query += " AND year = $2"
+		args = append(args, *year)
+	}
+	query += " ORDER BY year DESC, month DESC"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list BUAK Zuschlagsmeldungen: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*elda.BUAKMeldung
+	for rows.Next() {
+		m, err := scanMeldung(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan BUAK Zuschlagsmeldung: %w", err)
+		}
+		results = append(results, m)
+	}
+
+	return results, rows.Err()
+}
+
+// UpdateMeldung updates status and response fields of a Zuschlagsmeldung
+func (r *Repository) UpdateMeldung(ctx context.Context, m *elda.BUAKMeldung) error {
+	query := `
+		UPDATE buak_meldungen SET
+			status = $2,
+			protokollnummer = $3,
+			submitted_at = $4,
+			confirmed_at = $5,
+			confirmation_number = $6,
+			request_xml = $7,
+			response_xml = $8,
+			error_message = $9,
+			error_code = $10,
+			updated_at = $11
+		WHERE id = $1
+	`
+
+	m.UpdatedAt = time.Now()
+
+	result, err := r.db.Exec(ctx, query,
+		m.ID, m.Status, m.Protokollnummer, m.SubmittedAt, m.ConfirmedAt, m.ConfirmationNumber,
+		m.RequestXML, m.ResponseXML, m.ErrorMessage, m.ErrorCode, m.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("update BUAK Zuschlagsmeldung: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrMeldungNotFound
+	}
+
+	return nil
+}
+
+// DeleteMeldung deletes a Zuschlagsmeldung; positions are removed by cascade
+func (r *Repository) DeleteMeldung(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM buak_meldungen WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete BUAK Zuschlagsmeldung: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrMeldungNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) getPositionen(ctx context.Context, meldungID uuid.UUID) ([]*elda.BUAKPosition, error) {
+	query := `
+		SELECT id, meldung_id, arbeitnehmer_id, sv_nummer, vorname, nachname, buak_nummer, kategorie,
+			stunden, stundenlohn, lohnsumme,
+			zuschlag_urlaub, zuschlag_abfertigung, zuschlag_schlechtwetter, zuschlag_ueberbrueckung,
+			beschaeftiger_name, beschaeftiger_uid, position_index, created_at
+		FROM buak_positionen
+		WHERE meldung_id = $1
+		ORDER BY position_index
+	`
+
+	rows, err := r.db.Query(ctx, query, meldungID)
+	if err != nil {
+		return nil, fmt.Errorf("list BUAK positions: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*elda.BUAKPosition
+	for rows.Next() {
+		p := &elda.BUAKPosition{}
+		err := rows.Scan(
+			&p.ID, &p.MeldungID, &p.ArbeitnehmerID, &p.SVNummer, &p.Vorname, &p.Nachname, &p.BUAKNummer, &p.Kategorie,
+			&p.Stunden, &p.Stundenlohn, &p.Lohnsumme,
+			&p.Zuschlaege.Urlaub, &p.Zuschlaege.Abfertigung, &p.Zuschlaege.Schlechtwetter, &p.Zuschlaege.Ueberbrueckung,
+			&p.BeschaeftigerName, &p.BeschaeftigerUID, &p.PositionIndex, &p.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan BUAK position: %w", err)
+		}
+		results = append(results, p)
+	}
+
+	return results, rows.Err()
+}
+
+func scanArbeitnehmer(row pgx.Row) (*elda.BUAKArbeitnehmer, error) {
+	a := &elda.BUAKArbeitnehmer{}
+	var ueberlassungJSON []byte
+
+	err := row.Scan(
+		&a.ID, &a.ELDAAccountID, &a.MeldungID, &a.SVNummer, &a.Vorname, &a.Nachname, &a.BUAKNummer,
+		&a.Kategorie, &a.Taetigkeit, &a.Stundenlohn, &a.Wochenstunden, &a.Eintrittsdatum, &a.Austrittsdatum,
+		&ueberlassungJSON, &a.CreatedAt, &a.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ueberlassungJSON) > 0 {
+		a.Ueberlassung = &elda.AUEGUeberlassung{}
+		json.Unmarshal(ueberlassungJSON, a.Ueberlassung)
+	}
+
+	return a, nil
+}
+
+func scanMeldung(row pgx.Row) (*elda.BUAKMeldung, error) {
+	m := &elda.BUAKMeldung{}
+	err := row.Scan(
+		&m.ID, &m.ELDAAccountID, &m.Year, &m.Month, &m.Status, &m.Protokollnummer,
+		&m.TotalArbeitnehmer, &m.TotalLohnsumme, &m.TotalZuschlag,
+		&m.SubmittedAt, &m.ConfirmedAt, &m.ConfirmationNumber, &m.RequestXML, &m.ResponseXML,
+		&m.ErrorMessage, &m.ErrorCode, &m.CreatedBy, &m.CreatedAt, &m.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func marshalUeberlassung(u *elda.AUEGUeberlassung) ([]byte, error) {
+	if u == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(u)
+	if err != nil {
+		return nil, fmt.Errorf("marshal Überlassung: %w", err)
+	}
+	return data, nil
+}
+
+// isDuplicateError checks if the error is a unique constraint violation
+func isDuplicateError(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "23505") || strings.Contains(err.Error(), "duplicate key"))
+}
@@ -0,0 +1,742 @@
+package buak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/eldameldung"
+	"austrian-business-infrastructure/internal/rawpayload"
+)
+
+var (
+	ErrNotAnmeldung    = errors.New("ELDA meldung is not an Anmeldung")
+	ErrNotDraft        = errors.New("can only change BUAK Zuschlagsmeldungen in draft or validated status")
+	ErrNotSubmitted    = errors.New("BUAK Zuschlagsmeldung has not been submitted")
+	ErrNoArbeitnehmer  = errors.New("no BUAK workers employed in this period")
+	ErrAccountNotFound = errors.New("ELDA account not found")
+)
+
+// WeeksPerMonth is used to derive monthly hours from weekly hours
+const WeeksPerMonth = 4.33
+
+// Service handles BUAK business logic
+type Service struct {
+	repo        *Repository
+	meldungRepo *eldameldung.Repository
+	eldaService *elda.BUAKService
+	payloads    *rawpayload.Service
+	logger      *slog.Logger
+}
+
+// ServiceConfig contains configuration for the BUAK service
+type ServiceConfig struct {
+	Repository        *Repository
+	MeldungRepository *eldameldung.Repository
+	ELDAClient        elda.Caller
+	RawPayloads       *rawpayload.Service // Optional: retain raw ELDA exchanges
+	Logger            *slog.Logger
+}
+
+// NewService creates a new BUAK service
+func NewService(cfg ServiceConfig) *Service {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Service{
+		repo:        cfg.Repository,
+		meldungRepo: cfg.MeldungRepository,
+		eldaService: elda.NewBUAKService(cfg.ELDAClient),
+		payloads:    cfg.RawPayloads,
+		logger:      logger,
+	}
+}
+
+// ValidationError holds the messages of a failed BUAK validation
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 0 {
+		return "validation failed"
+	}
+	return fmt.Sprintf("validation failed: %s", e.Errors[0])
+}
+
+// RegisterArbeitnehmer registers a BUAK worker. With a MeldungID the employee
+// data is taken from the ELDA Anmeldung; fields in the request take precedence.
+func (s *Service) RegisterArbeitnehmer(ctx context.Context, req *elda.BUAKArbeitnehmerCreateRequest) (*elda.BUAKArbeitnehmer, error) {
+	a := &elda.BUAKArbeitnehmer{
+		ELDAAccountID: req.ELDAAccountID,
+		Kategorie:     elda.BUAKKategorieArbeiter,
+	}
+
+	if req.MeldungID != nil {
+		meldung, err := s.meldungRepo.GetByID(ctx, *req.MeldungID)
+		if err != nil {
+			return nil, err
+		}
+		if meldung.ELDAAccountID != req.ELDAAccountID {
+			return nil, eldameldung.ErrMeldungNotFound
+		}
+		if meldung.Type != elda.MeldungTypeAnmeldung {
+			return nil, ErrNotAnmeldung
+		}
+		a = ArbeitnehmerFromAnmeldung(meldung)
+	}
+
+	if err := applyCreateRequest(a, req); err != nil {
+		return nil, err
+	}
+
+	if errs := ValidateArbeitnehmer(a); len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	if err := s.repo.CreateArbeitnehmer(ctx, a); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("BUAK worker registered", "id", a.ID, "elda_account_id", a.ELDAAccountID)
+	return a, nil
+}
+
+// ArbeitnehmerFromAnmeldung derives the BUAK employment data from an ELDA Anmeldung
+func ArbeitnehmerFromAnmeldung(m *elda.ELDAMeldung) *elda.BUAKArbeitnehmer {
+	a := &elda.BUAKArbeitnehmer{
+		ELDAAccountID: m.ELDAAccountID,
+		MeldungID:     &m.ID,
+		SVNummer:      m.SVNummer,
+		Vorname:       m.Vorname,
+		Nachname:      m.Nachname,
+		Kategorie:     elda.BUAKKategorieArbeiter,
+	}
+
+	if m.Eintrittsdatum != nil {
+		a.Eintrittsdatum = *m.Eintrittsdatum
+	}
+	if m.Beschaeftigung != nil {
+		a.Taetigkeit = m.Beschaeftigung.Taetigkeit
+		if m.Beschaeftigung.Lehrling {
+			a.Kategorie = elda.BUAKKategorieLehrling
+		}
+	}
+	if m.Arbeitszeit != nil {
+		a.Wochenstunden = m.Arbeitszeit.WochenStunden
+	}
+	if m.Entgelt != nil {
+		a.Stundenlohn = m.Entgelt.StundenSatz
+		if a.Stundenlohn == 0 && m.Entgelt.BruttoMonatlich > 0 && a.Wochenstunden > 0 {
+			a.Stundenlohn = int64(math.Round(float64(m.Entgelt.BruttoMonatlich) / (a.Wochenstunden * WeeksPerMonth)))
+		}
+	}
+
+	return a
+}
+
+func applyCreateRequest(a *elda.BUAKArbeitnehmer, req *elda.BUAKArbeitnehmerCreateRequest) error {
+	if req.SVNummer != "" {
+		a.SVNummer = req.SVNummer
+	}
+	if req.Vorname != "" {
+		a.Vorname = req.Vorname
+	}
+	if req.Nachname != "" {
+		a.Nachname = req.Nachname
+	}
+	if req.BUAKNummer != "" {
+		a.BUAKNummer = req.BUAKNummer
+	}
+	if req.Taetigkeit != "" {
+		a.Taetigkeit = req.Taetigkeit
+	}
+	if req.Stundenlohn > 0 {
+		a.Stundenlohn = req.Stundenlohn
+	}
+	if req.Wochenstunden > 0 {
+		a.Wochenstunden = req.Wochenstunden
+	}
+	if req.Eintrittsdatum != "" {
+		t, err := time.Parse("2006-01-02", req.Eintrittsdatum)
+		if err != nil {
+			return &ValidationError{Errors: []string{"eintrittsdatum: ungültiges Datum"}}
+		}
+		a.Eintrittsdatum = t
+	}
+	if req.Austrittsdatum != "" {
+		t, err := time.Parse("2006-01-02", req.Austrittsdatum)
+		if err != nil {
+			return &ValidationError{Errors: []string{"austrittsdatum: ungültiges Datum"}}
+		}
+		a.Austrittsdatum = &t
+	}
+	if req.Ueberlassung != nil {
+		a.Ueberlassung = req.Ueberlassung
+		a.Kategorie = elda.BUAKKategorieUeberlass
+	}
+	if req.Kategorie != "" {
+		a.Kategorie = req.Kategorie
+	}
+	return nil
+}
+
+// ValidateArbeitnehmer checks the BUAK employment data of a worker
+func ValidateArbeitnehmer(a *elda.BUAKArbeitnehmer) []string {
+	var errs []string
+
+	if err := elda.ValidateSVNummer(a.SVNummer); err != nil {
+		errs = append(errs, "sv_nummer: "+err.Error())
+	}
+	if a.Vorname == "" || a.Nachname == "" {
+		errs = append(errs, "vorname und nachname sind erforderlich")
+	}
+	if a.Eintrittsdatum.IsZero() {
+		errs = append(errs, "eintrittsdatum ist erforderlich")
+	}
+	if a.Austrittsdatum != nil && a.Austrittsdatum.Before(a.Eintrittsdatum) {
+		errs = append(errs, "austrittsdatum liegt vor dem eintrittsdatum")
+	}
+	if a.Stundenlohn <= 0 {
+		errs = append(errs, "stundenlohn muss größer als 0 sein")
+	}
+
+	switch a.Kategorie {
+	case elda.BUAKKategorieArbeiter, elda.BUAKKategorieLehrling:
+	case elda.BUAKKategorieUeberlass:
+		if a.Ueberlassung == nil {
+			errs = append(errs, "ueberlassung ist für überlassene Arbeitskräfte erforderlich")
+		}
+	default:
+		errs = append(errs, "kategorie muss arbeiter, lehrling oder ueberlassen sein")
+	}
+
+	if u := a.Ueberlassung; u != nil {
+		if u.BeschaeftigerName == "" {
+			errs = append(errs, "ueberlassung.beschaeftiger_name ist erforderlich")
+		}
+		von, err := time.Parse("2006-01-02", u.Von)
+		if err != nil {
+			errs = append(errs, "ueberlassung.von: ungültiges Datum")
+		}
+		if u.Bis != "" {
+			bis, err := time.Parse("2006-01-02", u.Bis)
+			if err != nil {
+				errs = append(errs, "ueberlassung.bis: ungültiges Datum")
+			} else if bis.Before(von) {
+				errs = append(errs, "ueberlassung.bis liegt vor ueberlassung.von")
+			}
+		}
+	}
+
+	return errs
+}
+
+// OwnsELDAAccount reports whether an ELDA account is one of the tenant's.
+// Workers and Zuschlagsmeldungen belong to the tenant of their ELDA account.
+func (s *Service) OwnsELDAAccount(ctx context.Context, tenantID, eldaAccountID uuid.UUID) (bool, error) {
+	return s.repo.ELDAAccountBelongsToTenant(ctx, tenantID, eldaAccountID)
+}
+
+// GetArbeitnehmer returns a BUAK worker
+func (s *Service) GetArbeitnehmer(ctx context.Context, id uuid.UUID) (*elda.BUAKArbeitnehmer, error) {
+	return s.repo.GetArbeitnehmer(ctx, id)
+}
+
+// ListArbeitnehmer lists the BUAK workers of an ELDA account
+func (s *Service) ListArbeitnehmer(ctx context.Context, accountID uuid.UUID) ([]*elda.BUAKArbeitnehmer, error) {
+	return s.repo.ListArbeitnehmer(ctx, accountID)
+}
+
+// UpdateArbeitnehmer updates the BUAK employment data of a worker
+func (s *Service) UpdateArbeitnehmer(ctx context.Context, id uuid.UUID, req *elda.BUAKArbeitnehmerCreateRequest) (*elda.BUAKArbeitnehmer, error) {
+	a, err := s.repo.GetArbeitnehmer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Identity comes from the ELDA Anmeldung and is not changed here
+	req.SVNummer, req.Vorname, req.Nachname, req.Eintrittsdatum = "", "", "", ""
+	if err := applyCreateRequest(a, req); err != nil {
+		return nil, err
+	}
+
+	if errs := ValidateArbeitnehmer(a); len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	if err := s.repo.UpdateArbeitnehmer(ctx, a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// DeleteArbeitnehmer deletes a BUAK worker
+func (s *Service) DeleteArbeitnehmer(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeleteArbeitnehmer(ctx, id)
+}
+
+// SyncResult contains the outcome of a sync with the ELDA employee records
+type SyncResult struct {
+	Created int      `json:"created"`
+	Updated int      `json:"updated"`
+	Skipped []string `json:"skipped,omitempty"` // SV-Nummern with incomplete data
+}
+
+// SyncFromELDA registers all accepted Anmeldungen in Beitragsgruppe B1 as BUAK
+// workers and applies the Austrittsdatum of accepted Abmeldungen.
+func (s *Service) SyncFromELDA(ctx context.Context, accountID uuid.UUID) (*SyncResult, error) {
+	existing, err := s.repo.ListArbeitnehmer(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	bySV := make(map[string]*elda.BUAKArbeitnehmer, len(existing))
+	for _, a := range existing {
+		bySV[a.SVNummer] = a
+	}
+
+	accepted := elda.MeldungStatusAccepted
+	meldungen, err := s.meldungRepo.List(ctx, eldameldung.ListFilter{
+		ELDAAccountID: &accountID,
+		Status:        &accepted,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{}
+
+	// List is ordered newest first; register in chronological order
+	for i := len(meldungen) - 1; i >= 0; i-- {
+		m := meldungen[i]
+		if m.Type != elda.MeldungTypeAnmeldung || m.Beschaeftigung == nil ||
+			m.Beschaeftigung.Beitragsgruppe != elda.BUAKBeitragsgruppe {
+			continue
+		}
+		if _, ok := bySV[m.SVNummer]; ok {
+			continue
+		}
+
+		a := ArbeitnehmerFromAnmeldung(m)
+		if errs := ValidateArbeitnehmer(a); len(errs) > 0 {
+			result.Skipped = append(result.Skipped, m.SVNummer)
+			continue
+		}
+		if err := s.repo.CreateArbeitnehmer(ctx, a); err != nil {
+			return result, err
+		}
+		bySV[a.SVNummer] = a
+		result.Created++
+	}
+
+	for _, m := range meldungen {
+		if m.Type != elda.MeldungTypeAbmeldung || m.Austrittsdatum == nil {
+			continue
+		}
+		a, ok := bySV[m.SVNummer]
+		if !ok || (a.Austrittsdatum != nil && a.Austrittsdatum.Equal(*m.Austrittsdatum)) {
+			continue
+		}
+		a.Austrittsdatum = m.Austrittsdatum
+		if err := s.repo.UpdateArbeitnehmer(ctx, a); err != nil {
+			return result, err
+		}
+		result.Updated++
+	}
+
+	return result, nil
+}
+
+// CreateMeldung creates the Zuschlagsmeldung of a month for all workers employed in it
+func (s *Service) CreateMeldung(ctx context.Context, req *elda.BUAKMeldungCreateRequest, createdBy *uuid.UUID) (*elda.BUAKMeldung, error) {
+	if req.Year < 2020 || req.Month < 1 || req.Month > 12 {
+		return nil, &ValidationError{Errors: []string{"ungültiger Meldezeitraum"}}
+	}
+
+	workers, err := s.repo.ListArbeitnehmer(ctx, req.ELDAAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[uuid.UUID]elda.BUAKStundenItem, len(req.Stunden))
+	for _, item := range req.Stunden {
+		overrides[item.ArbeitnehmerID] = item
+	}
+
+	positionen := BuildPositionen(workers, req.Year, req.Month, overrides, elda.GetBUAKZuschlagssaetze(req.Year))
+	if len(positionen) == 0 {
+		return nil, ErrNoArbeitnehmer
+	}
+
+	m := &elda.BUAKMeldung{
+		ID:            uuid.New(),
+		ELDAAccountID: req.ELDAAccountID,
+		Year:          req.Year,
+		Month:         req.Month,
+		Status:        elda.BUAKStatusDraft,
+		CreatedBy:     createdBy,
+		Positionen:    positionen,
+	}
+	for _, p := range positionen {
+		m.TotalArbeitnehmer++
+		m.TotalLohnsumme += p.Lohnsumme
+		m.TotalZuschlag += p.Zuschlaege.Total()
+	}
+
+	if err := s.repo.CreateMeldung(ctx, m); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("BUAK Zuschlagsmeldung created",
+		"id", m.ID,
+		"period", fmt.Sprintf("%d-%02d", m.Year, m.Month),
+		"arbeitnehmer", m.TotalArbeitnehmer)
+
+	return m, nil
+}
+
+// BuildPositionen calculates the Zuschlagsmeldung positions of a month.
+// Workers without an override are reported with their contractual hours,
+// prorated for partial months.
+func BuildPositionen(workers []*elda.BUAKArbeitnehmer, year, month int, overrides map[uuid.UUID]elda.BUAKStundenItem, rates elda.BUAKZuschlagssaetze) []*elda.BUAKPosition {
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, -1)
+	daysInMonth := float64(end.Day())
+
+	var positionen []*elda.BUAKPosition
+	for _, a := range workers {
+		if !a.ActiveIn(year, month) {
+			continue
+		}
+
+		p := &elda.BUAKPosition{
+			ArbeitnehmerID: a.ID,
+			SVNummer:       a.SVNummer,
+			Vorname:        a.Vorname,
+			Nachname:       a.Nachname,
+			BUAKNummer:     a.BUAKNummer,
+			Kategorie:      a.Kategorie,
+			Stundenlohn:    a.Stundenlohn,
+			PositionIndex:  len(positionen) + 1,
+		}
+
+		if item, ok := overrides[a.ID]; ok {
+			p.Stunden = item.Stunden
+			if item.Lohnsumme != nil {
+				p.Lohnsumme = *item.Lohnsumme
+			}
+		} else {
+			from, to := start, end
+			if a.Eintrittsdatum.After(from) {
+				from = a.Eintrittsdatum
+			}
+			if a.Austrittsdatum != nil && a.Austrittsdatum.Before(to) {
+				to = *a.Austrittsdatum
+			}
+			days := to.Sub(from).Hours()/24 + 1
+			p.Stunden = math.Round(a.Wochenstunden*WeeksPerMonth*days/daysInMonth*100) / 100
+		}
+
+		if p.Lohnsumme == 0 {
+			p.Lohnsumme = int64(math.Round(p.Stunden * float64(a.Stundenlohn)))
+		}
+		p.Zuschlaege = elda.CalculateBUAKZuschlaege(p.Lohnsumme, rates)
+
+		if u := a.Ueberlassung; u != nil && ueberlassungActiveIn(u, start, end) {
+			p.BeschaeftigerName = u.BeschaeftigerName
+			p.BeschaeftigerUID = u.BeschaeftigerUID
+		}
+
+		positionen = append(positionen, p)
+	}
+
+	return positionen
+}
+
+func ueberlassungActiveIn(u *elda.AUEGUeberlassung, start, end time.Time) bool {
+	von, err := time.Parse("2006-01-02", u.Von)
+	if err != nil || von.After(end) {
+		return false
+	}
+	if u.Bis == "" {
+		return true
+	}
+	bis, err := time.Parse("2006-01-02", u.Bis)
+	return err == nil && !bis.Before(start)
+}
+
+// ValidateMeldung checks a Zuschlagsmeldung before submission
+func ValidateMeldung(m *elda.BUAKMeldung) []string {
+	var errs []string
+
+	if len(m.Positionen) == 0 {
+		errs = append(errs, "Zuschlagsmeldung enthält keine Positionen")
+	}
+
+	for _, p := range m.Positionen {
+		prefix := fmt.Sprintf("Position %d (%s): ", p.PositionIndex, p.SVNummer)
+		if err := elda.ValidateSVNummer(p.SVNummer); err != nil {
+			errs = append(errs, prefix+err.Error())
+		}
+		if p.Stunden <= 0 {
+			errs = append(errs, prefix+"stunden muss größer als 0 sein")
+		}
+		if p.Lohnsumme <= 0 {
+			errs = append(errs, prefix+"lohnsumme muss größer als 0 sein")
+		}
+		if p.Kategorie == elda.BUAKKategorieUeberlass && p.BeschaeftigerName == "" {
+			errs = append(errs, prefix+"Beschäftiger fehlt für überlassene Arbeitskraft")
+		}
+	}
+
+	return errs
+}
+
+// GetMeldung returns a Zuschlagsmeldung with its positions
+func (s *Service) GetMeldung(ctx context.Context, id uuid.UUID) (*elda.BUAKMeldung, error) {
+	return s.repo.GetMeldung(ctx, id)
+}
+
+// ListMeldungen lists the Zuschlagsmeldungen of an ELDA account
+func (s *Service) ListMeldungen(ctx context.Context, accountID uuid.UUID, year *int) ([]*elda.BUAKMeldung, error) {
+	return s.repo.ListMeldungen(ctx, accountID, year)
+}
+
+// Validate validates a Zuschlagsmeldung and marks it as validated
+func (s *Service) Validate(ctx context.Context, id uuid.UUID) (*elda.BUAKMeldung, error) {
+	m, err := s.repo.GetMeldung(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if errs := ValidateMeldung(m); len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	if m.Status == elda.BUAKStatusDraft {
+		m.Status = elda.BUAKStatusValidated
+		if err := s.repo.UpdateMeldung(ctx, m); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// PreviewXML renders the XML of a Zuschlagsmeldung
+func (s *Service) PreviewXML(ctx context.Context, id uuid.UUID, dienstgeberNr string) ([]byte, error) {
+	m, err := s.repo.GetMeldung(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return elda.MarshalBUAKDocument(BuildDocument(m, dienstgeberNr))
+}
+
+// Submit submits a Zuschlagsmeldung
+func (s *Service) Submit(ctx context.Context, id uuid.UUID, dienstgeberNr string) (*elda.BUAKSubmitResult, error) {
+	m, err := s.repo.GetMeldung(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Status != elda.BUAKStatusDraft && m.Status != elda.BUAKStatusValidated && m.Status != elda.BUAKStatusRejected {
+		return nil, fmt.Errorf("BUAK Zuschlagsmeldung already %s", m.Status)
+	}
+
+	if errs := ValidateMeldung(m); len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	rec := rawpayload.NewRecorder()
+	result, err := s.eldaService.SubmitBUAK(rawpayload.WithRecorder(ctx, rec), BuildDocument(m, dienstgeberNr))
+
+	now := time.Now()
+	m.SubmittedAt = &now
+	if result != nil {
+		m.RequestXML = result.RequestXML
+		s.payloads.Save(ctx, rawpayload.Reference{
+			ELDAAccountID:     &m.ELDAAccountID,
+			Module:            rawpayload.ModuleELDA,
+			ReferenceType:     "buak_meldung",
+			ReferenceID:       &m.ID,
+			ProviderReference: result.Protokollnummer,
+			Outcome:           rawpayload.OutcomeOf(err == nil, result.ErrorCode != ""),
+		}, rec)
+	}
+
+	if err != nil {
+		m.Status = elda.BUAKStatusRejected
+		if result != nil {
+			m.ErrorCode = result.ErrorCode
+			m.ErrorMessage = result.ErrorMessage
+		}
+		if updateErr := s.repo.UpdateMeldung(ctx, m); updateErr != nil {
+			s.logger.Error("failed to update rejected BUAK Zuschlagsmeldung", "id", id, "error", updateErr)
+		}
+		return result, err
+	}
+
+	m.Status = elda.BUAKStatusSubmitted
+	m.Protokollnummer = result.Protokollnummer
+	m.ErrorCode = ""
+	m.ErrorMessage = ""
+
+	if updateErr := s.repo.UpdateMeldung(ctx, m); updateErr != nil {
+		s.logger.Error("failed to update submitted BUAK Zuschlagsmeldung", "id", id, "error", updateErr)
+	}
+
+	s.logger.Info("BUAK Zuschlagsmeldung submitted",
+		"id", id,
+		"protokollnummer", result.Protokollnummer)
+
+	return result, nil
+}
+
+// RefreshStatus queries the confirmation status of a submitted Zuschlagsmeldung
+func (s *Service) RefreshStatus(ctx context.Context, id uuid.UUID, dienstgeberNr string) (*elda.BUAKMeldung, error) {
+	m, err := s.repo.GetMeldung(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Status != elda.BUAKStatusSubmitted || m.Protokollnummer == "" {
+		return m, nil
+	}
+
+	status, err := s.eldaService.QueryBUAKStatus(ctx, dienstgeberNr, m.Protokollnummer)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case status.Confirmed:
+		confirmedAt := time.Now()
+		if status.ConfirmedAt != nil {
+			confirmedAt = *status.ConfirmedAt
+		}
+		m.Status = elda.BUAKStatusConfirmed
+		m.ConfirmedAt = &confirmedAt
+		m.ConfirmationNumber = status.ConfirmationNumber
+	case status.ErrorCode != "":
+		m.Status = elda.BUAKStatusRejected
+		m.ErrorCode = status.ErrorCode
+		m.ErrorMessage = status.ErrorMessage
+	default:
+		return m, nil
+	}
+
+	if err := s.repo.UpdateMeldung(ctx, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// ConfirmRequest records a confirmation received outside of ELDA (e.g. eBUAK portal)
+type ConfirmRequest struct {
+	ConfirmationNumber string `json:"confirmation_number"`
+	ConfirmedAt        string `json:"confirmed_at,omitempty"` // YYYY-MM-DD, defaults to today
+}
+
+// Confirm records the BUAK confirmation of a submitted Zuschlagsmeldung
+func (s *Service) Confirm(ctx context.Context, id uuid.UUID, req *ConfirmRequest) (*elda.BUAKMeldung, error) {
+	m, err := s.repo.GetMeldung(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Status != elda.BUAKStatusSubmitted {
+		return nil, ErrNotSubmitted
+	}
+
+	confirmedAt := time.Now()
+	if req.ConfirmedAt != "" {
+		t, err := time.Parse("2006-01-02", req.ConfirmedAt)
+		if err != nil {
+			return nil, &ValidationError{Errors: []string{"confirmed_at: ungültiges Datum"}}
+		}
+		confirmedAt = t
+	}
+
+	m.Status = elda.BUAKStatusConfirmed
+	m.ConfirmedAt = &confirmedAt
+	m.ConfirmationNumber = req.ConfirmationNumber
+
+	if err := s.repo.UpdateMeldung(ctx, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// DeleteMeldung deletes a Zuschlagsmeldung that has not been submitted
+func (s *Service) DeleteMeldung(ctx context.Context, id uuid.UUID) error {
+	m, err := s.repo.GetMeldung(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if m.Status != elda.BUAKStatusDraft && m.Status != elda.BUAKStatusValidated {
+		return ErrNotDraft
+	}
+
+	return s.repo.DeleteMeldung(ctx, id)
+}
+
+// BuildDocument creates the XML document of a Zuschlagsmeldung
+func BuildDocument(m *elda.BUAKMeldung, dienstgeberNr string) *elda.BUAKDocument {
+	doc := &elda.BUAKDocument{
+		XMLNS: elda.ELDANS,
+		Kopf: elda.BUAKKopf{
+			DienstgeberNummer: dienstgeberNr,
+			Jahr:              m.Year,
+			Monat:             m.Month,
+			Erstellungsdatum:  time.Now().Format("2006-01-02"),
+			SummeLohn:         formatCents(m.TotalLohnsumme),
+			SummeZuschlag:     formatCents(m.TotalZuschlag),
+		},
+		Positionen: make([]elda.BUAKXMLPos, 0, len(m.Positionen)),
+	}
+
+	for _, p := range m.Positionen {
+		pos := elda.BUAKXMLPos{
+			SVNummer:          p.SVNummer,
+			BUAKNummer:        p.BUAKNummer,
+			Familienname:      p.Nachname,
+			Vorname:           p.Vorname,
+			Kategorie:         p.Kategorie,
+			Stunden:           fmt.Sprintf("%.2f", p.Stunden),
+			Stundenlohn:       formatCents(p.Stundenlohn),
+			Lohnsumme:         formatCents(p.Lohnsumme),
+			ZuschlagUrlaub:    formatCents(p.Zuschlaege.Urlaub),
+			ZuschlagAbfertig:  formatCents(p.Zuschlaege.Abfertigung),
+			ZuschlagSchlechtw: formatCents(p.Zuschlaege.Schlechtwetter),
+			ZuschlagUeberbr:   formatCents(p.Zuschlaege.Ueberbrueckung),
+		}
+		if p.BeschaeftigerName != "" {
+			pos.Ueberlassung = &elda.BUAKXMLUeberl{
+				BeschaeftigerName: p.BeschaeftigerName,
+				BeschaeftigerUID:  p.BeschaeftigerUID,
+			}
+		}
+		doc.Positionen = append(doc.Positionen, pos)
+	}
+
+	return doc
+}
+
+func formatCents(cents int64) string {
+	euros := float64(cents) / 100
+	return strconv.FormatFloat(euros, 'f', 2, 64)
+}
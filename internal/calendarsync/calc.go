@@ -0,0 +1,146 @@
+package calendarsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/foerderplanung"
+)
+
+// Hash identifies the content of an item's event, so that unchanged items
+// are not written to the calendar again
+func Hash(item Item) string {
+	h := sha256.New()
+	for _, part := range []string{item.Title, item.Description, item.Date.Format("2006-01-02"), item.URL} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// deadlineLabels names the deadline types of the document analysis
+var deadlineLabels = map[string]string{
+	"response":   "Antwortfrist",
+	"payment":    "Zahlungsfrist",
+	"appeal":     "Rechtsmittelfrist",
+	"submission": "Einreichfrist",
+}
+
+// DeadlineItem returns the calendar item of a deadline, linked to its
+// document
+func DeadlineItem(d Deadline, appURL string) Item {
+	label, ok := deadlineLabels[d.Type]
+	if !ok {
+		label = "Frist"
+	}
+	description := d.Rule
+	if d.SourceText != "" {
+		if description != "" {
+			description += "\n\n"
+		}
+		description += d.SourceText
+	}
+	return Item{
+		SourceType:  SourceDeadline,
+		SourceID:    d.ID,
+		Title:       label + ": " + d.DocumentTitle,
+		Description: description,
+		Date:        d.Date,
+		URL:         strings.TrimRight(appURL, "/") + "/documents/" + d.DocumentID.String(),
+	}
+}
+
+// ActionItemItem returns the calendar item of an action item, linked to its
+// document if it has one
+func ActionItemItem(a ActionItem, appURL string) Item {
+	item := Item{
+		SourceType:  SourceActionItem,
+		SourceID:    a.ID,
+		Title:       "Aufgabe: " + a.Title,
+		Description: a.Description,
+		Date:        a.DueDate,
+	}
+	if a.DocumentID != nil {
+		item.URL = strings.TrimRight(appURL, "/") + "/documents/" + a.DocumentID.String()
+	}
+	return item
+}
+
+// Plan compares the items that should be in the calendar with the events
+// pushed before: items without an event are created, items whose content
+// changed are updated and events whose item is gone are deleted
+func Plan(items []Item, events []Event) (create []Item, update []Update, remove []Event) {
+	pushed := make(map[string]Event, len(events))
+	for _, e := range events {
+		pushed[e.SourceType+":"+e.SourceID.String()] = e
+	}
+
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		key := item.SourceType + ":" + item.SourceID.String()
+		seen[key] = true
+		e, ok := pushed[key]
+		switch {
+		case !ok:
+			create = append(create, item)
+		case e.Hash != Hash(item):
+			update = append(update, Update{Item: item, EventID: e.EventID})
+		}
+	}
+	for _, e := range events {
+		if !seen[e.SourceType+":"+e.SourceID.String()] {
+			remove = append(remove, e)
+		}
+	}
+	return create, update, remove
+}
+
+// MaxBusyHoursPerDay caps the busy time counted per day, so that an
+// all-day event or a vacation counts as a working day, not 24 hours
+const MaxBusyHoursPerDay = 8.0
+
+// BusyPerWeek sums the busy time of a calendar per week, keyed by the
+// Monday of the week as 2006-01-02. Overlapping intervals count once, and
+// at most MaxBusyHoursPerDay per day (UTC).
+func BusyPerWeek(intervals []Interval) map[string]float64 {
+	sorted := make([]Interval, 0, len(intervals))
+	for _, iv := range intervals {
+		if iv.End.After(iv.Start) {
+			sorted = append(sorted, Interval{Start: iv.Start.UTC(), End: iv.End.UTC()})
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var merged []Interval
+	for _, iv := range sorted {
+		if n := len(merged); n > 0 && !iv.Start.After(merged[n-1].End) {
+			if iv.End.After(merged[n-1].End) {
+				merged[n-1].End = iv.End
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+
+	perDay := make(map[time.Time]float64)
+	for _, iv := range merged {
+		for start := iv.Start; start.Before(iv.End); {
+			day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+			end := day.AddDate(0, 0, 1)
+			if iv.End.Before(end) {
+				end = iv.End
+			}
+			perDay[day] += end.Sub(start).Hours()
+			start = end
+		}
+	}
+
+	busy := make(map[string]float64)
+	for day, hours := range perDay {
+		busy[foerderplanung.WeekStart(day).Format("2006-01-02")] += min(hours, MaxBusyHoursPerDay)
+	}
+	return busy
+}
@@ -0,0 +1,145 @@
+package calendarsync
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// Google is the Google Calendar API (v3)
+type Google struct {
+	config  *oauth2.Config
+	baseURL string
+}
+
+// NewGoogle creates the Google Calendar provider
+func NewGoogle(clientID, clientSecret, redirectURL string) *Google {
+	return &Google{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Endpoint:     google.Endpoint,
+			RedirectURL:  redirectURL,
+			Scopes: []string{
+				"email",
+				"https://www.googleapis.com/auth/calendar.events",
+				"https://www.googleapis.com/auth/calendar.freebusy",
+			},
+		},
+		baseURL: "https://www.googleapis.com",
+	}
+}
+
+// Name returns google
+func (g *Google) Name() string { return ProviderGoogle }
+
+// OAuth returns the OAuth client configuration
+func (g *Google) OAuth() *oauth2.Config { return g.config }
+
+// AccountEmail returns the email address of the Google account
+func (g *Google) AccountEmail(ctx context.Context, client *http.Client) (string, error) {
+	var info struct {
+		Email string `json:"email"`
+	}
+	if err := doJSON(ctx, client, http.MethodGet, g.baseURL+"/oauth2/v2/userinfo", nil, nil, &info); err != nil {
+		return "", err
+	}
+	return info.Email, nil
+}
+
+type googleDate struct {
+	Date string `json:"date"`
+}
+
+type googleSource struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+type googleEvent struct {
+	ID           string        `json:"id,omitempty"`
+	Summary      string        `json:"summary"`
+	Description  string        `json:"description"`
+	Start        googleDate    `json:"start"`
+	End          googleDate    `json:"end"`
+	Transparency string        `json:"transparency"`
+	Source       *googleSource `json:"source,omitempty"`
+}
+
+// PutEvent creates or replaces the all-day event of an item. The event is
+// transparent, so it does not block the day in free/busy.
+func (g *Google) PutEvent(ctx context.Context, client *http.Client, calendarID, eventID string, item Item) (string, error) {
+	event := googleEvent{
+		Summary:      item.Title,
+		Description:  eventDescription(item),
+		Start:        googleDate{Date: item.Date.Format("2006-01-02")},
+		End:          googleDate{Date: item.Date.AddDate(0, 0, 1).Format("2006-01-02")},
+		Transparency: "transparent",
+	}
+	if item.URL != "" {
+		event.Source = &googleSource{Title: item.Title, URL: item.URL}
+	}
+
+	events := g.baseURL + "/calendar/v3/calendars/" + url.PathEscape(calendarID) + "/events"
+	var saved googleEvent
+	var err error
+	if eventID == "" {
+		err = doJSON(ctx, client, http.MethodPost, events, nil, event, &saved)
+	} else {
+		err = doJSON(ctx, client, http.MethodPut, events+"/"+url.PathEscape(eventID), nil, event, &saved)
+	}
+	if err != nil {
+		return "", err
+	}
+	return saved.ID, nil
+}
+
+// DeleteEvent removes an event
+func (g *Google) DeleteEvent(ctx context.Context, client *http.Client, calendarID, eventID string) error {
+	return doJSON(ctx, client, http.MethodDelete,
+		g.baseURL+"/calendar/v3/calendars/"+url.PathEscape(calendarID)+"/events/"+url.PathEscape(eventID), nil, nil, nil)
+}
+
+// Busy returns the busy times of the calendar from the free/busy query
+func (g *Google) Busy(ctx context.Context, client *http.Client, calendarID string, from, to time.Time) ([]Interval, error) {
+	query := map[string]interface{}{
+		"timeMin": from.UTC().Format(time.RFC3339),
+		"timeMax": to.UTC().Format(time.RFC3339),
+		"items":   []map[string]string{{"id": calendarID}},
+	}
+	var resp struct {
+		Calendars map[string]struct {
+			Busy []struct {
+				Start time.Time `json:"start"`
+				End   time.Time `json:"end"`
+			} `json:"busy"`
+		} `json:"calendars"`
+	}
+	if err := doJSON(ctx, client, http.MethodPost, g.baseURL+"/calendar/v3/freeBusy", nil, query, &resp); err != nil {
+		return nil, err
+	}
+
+	var busy []Interval
+	for _, cal := range resp.Calendars {
+		for _, b := range cal.Busy {
+			busy = append(busy, Interval{Start: b.Start, End: b.End})
+		}
+	}
+	return busy, nil
+}
+
+// eventDescription returns the text of an item's event with the link to
+// the item in the app
+func eventDescription(item Item) string {
+	if item.URL == "" {
+		return item.Description
+	}
+	if item.Description == "" {
+		return item.URL
+	}
+	return item.Description + "\n\n" + item.URL
+}
@@ -0,0 +1,199 @@
+package calendarsync
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler handles calendar integration HTTP requests
+type Handler struct {
+	service *Service
+	appURL  string
+	logger  *slog.Logger
+}
+
+// NewHandler creates a new calendar handler. The OAuth callback sends the
+// user back to the calendar settings of the app at appURL.
+func NewHandler(service *Service, appURL string, logger *slog.Logger) *Handler {
+	return &Handler{service: service, appURL: strings.TrimRight(appURL, "/"), logger: logger}
+}
+
+// RegisterRoutes registers the calendar routes. The OAuth callback is
+// public: the provider redirects the browser there, and the state
+// identifies the user.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/calendar/providers", requireAuth(http.HandlerFunc(h.Providers)))
+	router.Handle("POST /api/v1/calendar/connect/{provider}", requireAuth(http.HandlerFunc(h.Connect)))
+	router.HandleFunc("GET /api/v1/calendar/oauth/{provider}/callback", h.Callback)
+	router.Handle("GET /api/v1/calendar/connections", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("PATCH /api/v1/calendar/connections/{id}", requireAuth(http.HandlerFunc(h.Update)))
+	router.Handle("DELETE /api/v1/calendar/connections/{id}", requireAuth(http.HandlerFunc(h.Disconnect)))
+	router.Handle("POST /api/v1/calendar/connections/{id}/sync", requireAuth(http.HandlerFunc(h.Sync)))
+}
+
+// Providers handles GET /api/v1/calendar/providers
+func (h *Handler) Providers(w http.ResponseWriter, r *http.Request) {
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"providers": h.service.Providers()})
+}
+
+// Connect handles POST /api/v1/calendar/connect/{provider}
+func (h *Handler) Connect(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := requestUser(w, r)
+	if !ok {
+		return
+	}
+
+	authURL, err := h.service.Connect(r.Context(), tenantID, userID, r.PathValue("provider"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]string{"auth_url": authURL})
+}
+
+// Callback handles GET /api/v1/calendar/oauth/{provider}/callback
+func (h *Handler) Callback(w http.ResponseWriter, r *http.Request) {
+	provider := r.PathValue("provider")
+	q := r.URL.Query()
+	if errParam := q.Get("error"); errParam != "" {
+		h.logger.Warn("calendar authorization failed", "provider", provider,
+			"error", errParam, "description", q.Get("error_description"))
+		h.redirect(w, r, "error", "Calendar access was not granted")
+		return
+	}
+
+	conn, err := h.service.Callback(r.Context(), provider, q.Get("state"), q.Get("code"))
+	switch {
+	case errors.Is(err, ErrUnknownProvider), errors.Is(err, ErrProviderDisabled), errors.Is(err, ErrInvalidState):
+		h.redirect(w, r, "error", err.Error())
+	case err != nil:
+		h.logger.Error("failed to connect calendar", "provider", provider, "error", err)
+		h.redirect(w, r, "error", "Failed to connect the calendar")
+	default:
+		h.redirect(w, r, "connected", conn.ID.String())
+	}
+}
+
+func (h *Handler) redirect(w http.ResponseWriter, r *http.Request, key, value string) {
+	http.Redirect(w, r, h.appURL+"/settings/calendar?"+url.Values{key: {value}}.Encode(), http.StatusTemporaryRedirect)
+}
+
+// List handles GET /api/v1/calendar/connections
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := requestUser(w, r)
+	if !ok {
+		return
+	}
+
+	conns, err := h.service.List(r.Context(), tenantID, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if conns == nil {
+		conns = []*Connection{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"connections": conns})
+}
+
+// Update handles PATCH /api/v1/calendar/connections/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := requestUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid connection ID")
+		return
+	}
+
+	var input UpdateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	conn, err := h.service.Update(r.Context(), tenantID, userID, id, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, conn)
+}
+
+// Disconnect handles DELETE /api/v1/calendar/connections/{id}
+func (h *Handler) Disconnect(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := requestUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid connection ID")
+		return
+	}
+
+	if err := h.service.Disconnect(r.Context(), tenantID, userID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Sync handles POST /api/v1/calendar/connections/{id}/sync
+func (h *Handler) Sync(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := requestUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid connection ID")
+		return
+	}
+
+	result, err := h.service.Sync(r.Context(), tenantID, userID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, result)
+}
+
+// requestUser returns the tenant and user of the request, writing 401 if
+// there is none
+func requestUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, ok := api.RequestUser(w, r)
+	return tenantID, userID, ok
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrConnectionNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrUnknownProvider), errors.Is(err, ErrInvalidCalendarID):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	case errors.Is(err, ErrProviderDisabled), errors.Is(err, ErrAuthorizationRevoked):
+		api.Conflict(w, err.Error())
+	default:
+		api.InternalError(w)
+	}
+}
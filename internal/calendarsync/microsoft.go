@@ -0,0 +1,152 @@
+package calendarsync
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/microsoft"
+)
+
+// Microsoft is the calendar of Microsoft 365 through Microsoft Graph
+type Microsoft struct {
+	config  *oauth2.Config
+	baseURL string
+}
+
+// NewMicrosoft creates the Microsoft 365 calendar provider
+func NewMicrosoft(clientID, clientSecret, redirectURL string) *Microsoft {
+	return &Microsoft{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Endpoint:     microsoft.AzureADEndpoint("common"),
+			RedirectURL:  redirectURL,
+			Scopes:       []string{"offline_access", "User.Read", "Calendars.ReadWrite"},
+		},
+		baseURL: "https://graph.microsoft.com/v1.0",
+	}
+}
+
+// Name returns microsoft
+func (m *Microsoft) Name() string { return ProviderMicrosoft }
+
+// OAuth returns the OAuth client configuration
+func (m *Microsoft) OAuth() *oauth2.Config { return m.config }
+
+// AccountEmail returns the email address of the Microsoft account, which
+// may be in either field
+func (m *Microsoft) AccountEmail(ctx context.Context, client *http.Client) (string, error) {
+	var info struct {
+		Mail              string `json:"mail"`
+		UserPrincipalName string `json:"userPrincipalName"`
+	}
+	if err := doJSON(ctx, client, http.MethodGet, m.baseURL+"/me", nil, nil, &info); err != nil {
+		return "", err
+	}
+	if info.Mail != "" {
+		return info.Mail, nil
+	}
+	return info.UserPrincipalName, nil
+}
+
+// calendar returns the path of a calendar: the user's default calendar for
+// primary
+func (m *Microsoft) calendar(calendarID string) string {
+	if calendarID == "" || calendarID == DefaultCalendarID {
+		return m.baseURL + "/me/calendar"
+	}
+	return m.baseURL + "/me/calendars/" + url.PathEscape(calendarID)
+}
+
+type graphTime struct {
+	DateTime string `json:"dateTime"`
+	TimeZone string `json:"timeZone"`
+}
+
+type graphBody struct {
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+}
+
+type graphEvent struct {
+	ID       string    `json:"id,omitempty"`
+	Subject  string    `json:"subject"`
+	Body     graphBody `json:"body"`
+	Start    graphTime `json:"start"`
+	End      graphTime `json:"end"`
+	IsAllDay bool      `json:"isAllDay"`
+	ShowAs   string    `json:"showAs"`
+}
+
+// PutEvent creates or updates the all-day event of an item. The event is
+// shown as free, so it does not block the day in free/busy.
+func (m *Microsoft) PutEvent(ctx context.Context, client *http.Client, calendarID, eventID string, item Item) (string, error) {
+	event := graphEvent{
+		Subject:  item.Title,
+		Body:     graphBody{ContentType: "text", Content: eventDescription(item)},
+		Start:    graphTime{DateTime: item.Date.Format("2006-01-02") + "T00:00:00", TimeZone: "UTC"},
+		End:      graphTime{DateTime: item.Date.AddDate(0, 0, 1).Format("2006-01-02") + "T00:00:00", TimeZone: "UTC"},
+		IsAllDay: true,
+		ShowAs:   "free",
+	}
+
+	var saved graphEvent
+	var err error
+	if eventID == "" {
+		err = doJSON(ctx, client, http.MethodPost, m.calendar(calendarID)+"/events", nil, event, &saved)
+	} else {
+		err = doJSON(ctx, client, http.MethodPatch, m.baseURL+"/me/events/"+url.PathEscape(eventID), nil, event, &saved)
+	}
+	if err != nil {
+		return "", err
+	}
+	return saved.ID, nil
+}
+
+// DeleteEvent removes an event
+func (m *Microsoft) DeleteEvent(ctx context.Context, client *http.Client, calendarID, eventID string) error {
+	return doJSON(ctx, client, http.MethodDelete, m.baseURL+"/me/events/"+url.PathEscape(eventID), nil, nil, nil)
+}
+
+// Busy returns the events of the calendar view shown as busy or out of
+// office
+func (m *Microsoft) Busy(ctx context.Context, client *http.Client, calendarID string, from, to time.Time) ([]Interval, error) {
+	query := url.Values{}
+	query.Set("startDateTime", from.UTC().Format(time.RFC3339))
+	query.Set("endDateTime", to.UTC().Format(time.RFC3339))
+	query.Set("$select", "showAs,start,end")
+	query.Set("$top", "200")
+	next := m.calendar(calendarID) + "/calendarView?" + query.Encode()
+	header := http.Header{"Prefer": []string{`outlook.timezone="UTC"`}}
+
+	var busy []Interval
+	for next != "" {
+		var page struct {
+			Value []struct {
+				ShowAs string    `json:"showAs"`
+				Start  graphTime `json:"start"`
+				End    graphTime `json:"end"`
+			} `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := doJSON(ctx, client, http.MethodGet, next, header, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, e := range page.Value {
+			if e.ShowAs != "busy" && e.ShowAs != "oof" {
+				continue
+			}
+			start, err1 := time.Parse("2006-01-02T15:04:05.9999999", e.Start.DateTime)
+			end, err2 := time.Parse("2006-01-02T15:04:05.9999999", e.End.DateTime)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			busy = append(busy, Interval{Start: start, End: end})
+		}
+		next = page.NextLink
+	}
+	return busy, nil
+}
@@ -0,0 +1,105 @@
+package calendarsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Provider is the calendar API of a provider. The client passed to the
+// methods authorizes the requests with the connection's OAuth token.
+type Provider interface {
+	// Name returns the provider, google or microsoft
+	Name() string
+	// OAuth returns the OAuth client configuration
+	OAuth() *oauth2.Config
+	// AccountEmail returns the email address of the connected account
+	AccountEmail(ctx context.Context, client *http.Client) (string, error)
+	// PutEvent creates the all-day event of an item, or replaces the event
+	// with the given ID, and returns the event's ID
+	PutEvent(ctx context.Context, client *http.Client, calendarID, eventID string, item Item) (string, error)
+	// DeleteEvent removes an event; ErrEventNotFound if it is gone already
+	DeleteEvent(ctx context.Context, client *http.Client, calendarID, eventID string) error
+	// Busy returns the busy times of the calendar between two instants
+	Busy(ctx context.Context, client *http.Client, calendarID string, from, to time.Time) ([]Interval, error)
+}
+
+// NewProviders returns the providers whose OAuth client is configured. The
+// providers redirect back to {redirectBaseURL}/api/v1/calendar/oauth/{provider}/callback.
+func NewProviders(redirectBaseURL, googleClientID, googleClientSecret, microsoftClientID, microsoftClientSecret string) []Provider {
+	redirect := strings.TrimRight(redirectBaseURL, "/") + "/api/v1/calendar/oauth/"
+	var providers []Provider
+	if googleClientID != "" && googleClientSecret != "" {
+		providers = append(providers, NewGoogle(googleClientID, googleClientSecret, redirect+ProviderGoogle+"/callback"))
+	}
+	if microsoftClientID != "" && microsoftClientSecret != "" {
+		providers = append(providers, NewMicrosoft(microsoftClientID, microsoftClientSecret, redirect+ProviderMicrosoft+"/callback"))
+	}
+	return providers
+}
+
+// requestTimeout bounds each call to a calendar API
+const requestTimeout = 20 * time.Second
+
+// doJSON sends a request with an optional JSON body and decodes a JSON
+// response into out. 404 and 410 become ErrEventNotFound, 401 becomes
+// ErrAuthorizationRevoked, as does a refresh token the provider no longer
+// accepts.
+func doJSON(ctx context.Context, client *http.Client, method, url string, header http.Header, body, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+			return ErrAuthorizationRevoked
+		}
+		return fmt.Errorf("calendar request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrEventNotFound
+	case resp.StatusCode == http.StatusUnauthorized:
+		return ErrAuthorizationRevoked
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("calendar API returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid calendar API response: %w", err)
+	}
+	return nil
+}
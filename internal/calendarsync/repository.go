@@ -0,0 +1,279 @@
+package calendarsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/internal/document"
+)
+
+// Repository stores calendar connections and the events pushed to them,
+// and reads the deadlines and action items to push
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new calendar repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const connectionColumns = `id, tenant_id, user_id, provider, account_email, calendar_id, token,
+	push_deadlines, push_action_items, read_busy, status, last_error, last_synced_at, created_at, updated_at`
+
+func scanConnection(row pgx.Row) (*Connection, error) {
+	var c Connection
+	err := row.Scan(&c.ID, &c.TenantID, &c.UserID, &c.Provider, &c.AccountEmail, &c.CalendarID, &c.token,
+		&c.PushDeadlines, &c.PushActionItems, &c.ReadBusy, &c.Status, &c.LastError, &c.LastSyncedAt,
+		&c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *Repository) listConnections(ctx context.Context, where string, args ...interface{}) ([]*Connection, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+connectionColumns+` FROM calendar_connections WHERE `+where+`
+		ORDER BY created_at`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar connections: %w", err)
+	}
+	defer rows.Close()
+
+	var conns []*Connection
+	for rows.Next() {
+		c, err := scanConnection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan calendar connection: %w", err)
+		}
+		conns = append(conns, c)
+	}
+	return conns, rows.Err()
+}
+
+// Save stores a new connection. Connecting an account that is connected
+// already replaces its token and reactivates it, keeping its settings and
+// pushed events.
+func (r *Repository) Save(ctx context.Context, c *Connection) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO calendar_connections (tenant_id, user_id, provider, account_email, calendar_id, token,
+			push_deadlines, push_action_items, read_busy)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id, provider, account_email) DO UPDATE SET
+			token = EXCLUDED.token, status = 'active', last_error = NULL, updated_at = NOW()
+		RETURNING `+connectionColumns,
+		c.TenantID, c.UserID, c.Provider, c.AccountEmail, c.CalendarID, c.token,
+		c.PushDeadlines, c.PushActionItems, c.ReadBusy,
+	).Scan(&c.ID, &c.TenantID, &c.UserID, &c.Provider, &c.AccountEmail, &c.CalendarID, &c.token,
+		&c.PushDeadlines, &c.PushActionItems, &c.ReadBusy, &c.Status, &c.LastError, &c.LastSyncedAt,
+		&c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save calendar connection: %w", err)
+	}
+	return nil
+}
+
+// Get returns a connection of a user
+func (r *Repository) Get(ctx context.Context, tenantID, userID, id uuid.UUID) (*Connection, error) {
+	c, err := scanConnection(r.db.QueryRow(ctx, `SELECT `+connectionColumns+` FROM calendar_connections
+		WHERE id = $1 AND tenant_id = $2 AND user_id = $3`, id, tenantID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrConnectionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar connection: %w", err)
+	}
+	return c, nil
+}
+
+// ListByUser returns the connections of a user
+func (r *Repository) ListByUser(ctx context.Context, tenantID, userID uuid.UUID) ([]*Connection, error) {
+	return r.listConnections(ctx, `tenant_id = $1 AND user_id = $2`, tenantID, userID)
+}
+
+// ListActive returns the active connections of all tenants that push
+// deadlines or action items
+func (r *Repository) ListActive(ctx context.Context) ([]*Connection, error) {
+	return r.listConnections(ctx, `status = 'active' AND (push_deadlines OR push_action_items)`)
+}
+
+// ListBusy returns the active connections of a tenant whose busy times are
+// read
+func (r *Repository) ListBusy(ctx context.Context, tenantID uuid.UUID) ([]*Connection, error) {
+	return r.listConnections(ctx, `tenant_id = $1 AND status = 'active' AND read_busy`, tenantID)
+}
+
+// UpdateSettings stores the settings of a connection
+func (r *Repository) UpdateSettings(ctx context.Context, c *Connection) error {
+	err := r.db.QueryRow(ctx, `
+		UPDATE calendar_connections
+		SET calendar_id = $2, push_deadlines = $3, push_action_items = $4, read_busy = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`,
+		c.ID, c.CalendarID, c.PushDeadlines, c.PushActionItems, c.ReadBusy,
+	).Scan(&c.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrConnectionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update calendar connection: %w", err)
+	}
+	return nil
+}
+
+// UpdateToken stores a refreshed token
+func (r *Repository) UpdateToken(ctx context.Context, id uuid.UUID, token []byte) error {
+	if _, err := r.db.Exec(ctx, `UPDATE calendar_connections SET token = $2 WHERE id = $1`, id, token); err != nil {
+		return fmt.Errorf("failed to update calendar token: %w", err)
+	}
+	return nil
+}
+
+// MarkSynced records the outcome of a sync
+func (r *Repository) MarkSynced(ctx context.Context, c *Connection) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE calendar_connections SET status = $2, last_error = $3, last_synced_at = $4
+		WHERE id = $1`, c.ID, c.Status, c.LastError, c.LastSyncedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update calendar connection: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a connection and its event records
+func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM calendar_connections WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete calendar connection: %w", err)
+	}
+	return nil
+}
+
+// ListEvents returns the events pushed to a connection for items dated on
+// or after a day
+func (r *Repository) ListEvents(ctx context.Context, connectionID uuid.UUID, from time.Time) ([]Event, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT source_type, source_id, event_id, hash, event_date
+		FROM calendar_events
+		WHERE connection_id = $1 AND event_date >= $2`, connectionID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.SourceType, &e.SourceID, &e.EventID, &e.Hash, &e.Date); err != nil {
+			return nil, fmt.Errorf("failed to scan calendar event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// SaveEvent records the event pushed for an item
+func (r *Repository) SaveEvent(ctx context.Context, connectionID uuid.UUID, e Event) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO calendar_events (connection_id, source_type, source_id, event_id, hash, event_date)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (connection_id, source_type, source_id) DO UPDATE SET
+			event_id = EXCLUDED.event_id, hash = EXCLUDED.hash, event_date = EXCLUDED.event_date,
+			synced_at = NOW()`,
+		connectionID, e.SourceType, e.SourceID, e.EventID, e.Hash, e.Date)
+	if err != nil {
+		return fmt.Errorf("failed to save calendar event: %w", err)
+	}
+	return nil
+}
+
+// DeleteEvent removes the record of a pushed event
+func (r *Repository) DeleteEvent(ctx context.Context, connectionID uuid.UUID, e Event) error {
+	_, err := r.db.Exec(ctx, `
+		DELETE FROM calendar_events WHERE connection_id = $1 AND source_type = $2 AND source_id = $3`,
+		connectionID, e.SourceType, e.SourceID)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar event: %w", err)
+	}
+	return nil
+}
+
+// DeleteEvents removes the records of all events pushed to a connection
+func (r *Repository) DeleteEvents(ctx context.Context, connectionID uuid.UUID) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM calendar_events WHERE connection_id = $1`, connectionID); err != nil {
+		return fmt.Errorf("failed to delete calendar events: %w", err)
+	}
+	return nil
+}
+
+// ForgetEvents drops the records of events dated before a day; the events
+// stay in the calendar as history and are no longer updated
+func (r *Repository) ForgetEvents(ctx context.Context, connectionID uuid.UUID, before time.Time) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM calendar_events WHERE connection_id = $1 AND event_date < $2`,
+		connectionID, before)
+	if err != nil {
+		return fmt.Errorf("failed to clean up calendar events: %w", err)
+	}
+	return nil
+}
+
+// ListDeadlines returns the open deadlines extracted from the tenant's
+// documents between two days, of the documents a user may see
+func (r *Repository) ListDeadlines(ctx context.Context, tenantID, userID uuid.UUID, from, until time.Time) ([]Deadline, error) {
+	access, args := document.AccessConditionFor(userID, "d", []interface{}{tenantID, from, until})
+	rows, err := r.db.Query(ctx, `
+		SELECT ed.id, ed.document_id, ed.deadline_type, ed.deadline_date,
+			COALESCE(NULLIF(d.title, ''), d.type), COALESCE(ed.calculation_rule, ''), COALESCE(ed.source_text, '')
+		FROM extracted_deadlines ed
+		JOIN documents d ON d.id = ed.document_id
+		WHERE ed.tenant_id = $1 AND ed.status IN ('active', 'overdue')
+			AND ed.deadline_date BETWEEN $2 AND $3`+access+`
+		ORDER BY ed.deadline_date`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deadlines: %w", err)
+	}
+	defer rows.Close()
+
+	var deadlines []Deadline
+	for rows.Next() {
+		var d Deadline
+		if err := rows.Scan(&d.ID, &d.DocumentID, &d.Type, &d.Date, &d.DocumentTitle, &d.Rule, &d.SourceText); err != nil {
+			return nil, fmt.Errorf("failed to scan deadline: %w", err)
+		}
+		deadlines = append(deadlines, d)
+	}
+	return deadlines, rows.Err()
+}
+
+// ListActionItems returns the open action items assigned to a user that are
+// due between two days, leaving out those of documents the user may not see.
+// Items without a document pass the access condition.
+func (r *Repository) ListActionItems(ctx context.Context, tenantID, userID uuid.UUID, from, until time.Time) ([]ActionItem, error) {
+	access, args := document.AccessConditionFor(userID, "d", []interface{}{tenantID, userID, from, until})
+	rows, err := r.db.Query(ctx, `
+		SELECT a.id, a.document_id, a.title, COALESCE(a.description, ''), a.due_date
+		FROM action_items a
+		LEFT JOIN documents d ON d.id = a.document_id
+		WHERE a.tenant_id = $1 AND a.assigned_to = $2 AND a.status IN ('pending', 'in_progress')
+			AND a.due_date BETWEEN $3 AND $4`+access+`
+		ORDER BY a.due_date`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list action items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []ActionItem
+	for rows.Next() {
+		var a ActionItem
+		if err := rows.Scan(&a.ID, &a.DocumentID, &a.Title, &a.Description, &a.DueDate); err != nil {
+			return nil, fmt.Errorf("failed to scan action item: %w", err)
+		}
+		items = append(items, a)
+	}
+	return items, rows.Err()
+}
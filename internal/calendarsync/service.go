@@ -0,0 +1,529 @@
+package calendarsync
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+
+	"austrian-business-infrastructure/internal/crypto"
+	"austrian-business-infrastructure/internal/timezone"
+)
+
+// stateTTL is how long a user has to complete the provider's consent
+const stateTTL = 10 * time.Minute
+
+// StateStore keeps the OAuth state between the redirect to the provider and
+// the callback; cache.Store implements it
+type StateStore interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Config configures the calendar sync
+type Config struct {
+	// AppURL is the base URL of the app events link to
+	AppURL string
+	// HorizonDays is how far ahead deadlines and action items are pushed
+	HorizonDays int
+}
+
+// oauthState is what the state parameter of an authorization stands for
+type oauthState struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Provider string    `json:"provider"`
+	Verifier string    `json:"verifier"`
+}
+
+// Service connects calendars and keeps their events in sync
+type Service struct {
+	repo      *Repository
+	providers map[string]Provider
+	key       []byte
+	states    StateStore
+	cfg       Config
+	timezones timezone.Source
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewService creates a new calendar sync service. OAuth tokens are
+// encrypted with key; only the given providers can be connected.
+func NewService(repo *Repository, key []byte, states StateStore, cfg Config, logger *slog.Logger, providers ...Provider) *Service {
+	if cfg.HorizonDays <= 0 {
+		cfg.HorizonDays = DefaultHorizonDays
+	}
+	s := &Service{
+		repo:      repo,
+		providers: make(map[string]Provider, len(providers)),
+		key:       key,
+		states:    states,
+		cfg:       cfg,
+		logger:    logger,
+		now:       time.Now,
+	}
+	for _, p := range providers {
+		s.providers[p.Name()] = p
+	}
+	return s
+}
+
+// SetTimezones sets the source of the tenants' time zones the days of the
+// sync window are decided in; Europe/Vienna if not set
+func (s *Service) SetTimezones(src timezone.Source) {
+	s.timezones = src
+}
+
+// Providers returns the providers that can be connected
+func (s *Service) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Service) provider(name string) (Provider, error) {
+	if name != ProviderGoogle && name != ProviderMicrosoft {
+		return nil, ErrUnknownProvider
+	}
+	p, ok := s.providers[name]
+	if !ok {
+		return nil, ErrProviderDisabled
+	}
+	return p, nil
+}
+
+// Connect starts connecting a calendar and returns the provider's consent
+// page to send the user to
+func (s *Service) Connect(ctx context.Context, tenantID, userID uuid.UUID, providerName string) (string, error) {
+	p, err := s.provider(providerName)
+	if err != nil {
+		return "", err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	state := hex.EncodeToString(b)
+	st := oauthState{TenantID: tenantID, UserID: userID, Provider: providerName, Verifier: oauth2.GenerateVerifier()}
+	data, err := json.Marshal(st)
+	if err != nil {
+		return "", err
+	}
+	if err := s.states.Set(ctx, state, data, stateTTL); err != nil {
+		return "", fmt.Errorf("failed to store OAuth state: %w", err)
+	}
+
+	// Offline access with forced consent, so that a refresh token is issued
+	// on every connect
+	return p.OAuth().AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce,
+		oauth2.S256ChallengeOption(st.Verifier)), nil
+}
+
+// Callback completes connecting a calendar with the authorization code the
+// provider redirected back with
+func (s *Service) Callback(ctx context.Context, providerName, state, code string) (*Connection, error) {
+	p, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := s.states.Get(ctx, state)
+	if !ok || state == "" {
+		return nil, ErrInvalidState
+	}
+	_ = s.states.Delete(ctx, state)
+	var st oauthState
+	if err := json.Unmarshal(data, &st); err != nil || st.Provider != providerName {
+		return nil, ErrInvalidState
+	}
+
+	token, err := p.OAuth().Exchange(ctx, code, oauth2.VerifierOption(st.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	client := p.OAuth().Client(ctx, token)
+	email, err := p.AccountEmail(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar account: %w", err)
+	}
+
+	encrypted, err := s.encryptToken(token)
+	if err != nil {
+		return nil, err
+	}
+	conn := &Connection{
+		TenantID:        st.TenantID,
+		UserID:          st.UserID,
+		Provider:        providerName,
+		AccountEmail:    email,
+		CalendarID:      DefaultCalendarID,
+		PushDeadlines:   true,
+		PushActionItems: true,
+		token:           encrypted,
+	}
+	if err := s.repo.Save(ctx, conn); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// List returns the calendars a user connected
+func (s *Service) List(ctx context.Context, tenantID, userID uuid.UUID) ([]*Connection, error) {
+	return s.repo.ListByUser(ctx, tenantID, userID)
+}
+
+// Update changes the settings of a connection. Moving to another calendar
+// removes the events pushed to the old one; the next sync pushes them to
+// the new one.
+func (s *Service) Update(ctx context.Context, tenantID, userID, id uuid.UUID, input *UpdateInput) (*Connection, error) {
+	conn, err := s.repo.Get(ctx, tenantID, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.CalendarID != nil {
+		calendarID := *input.CalendarID
+		if calendarID == "" {
+			calendarID = DefaultCalendarID
+		}
+		if len(calendarID) > 255 {
+			return nil, ErrInvalidCalendarID
+		}
+		if calendarID != conn.CalendarID {
+			s.removeAll(ctx, conn)
+			conn.CalendarID = calendarID
+		}
+	}
+	if input.PushDeadlines != nil {
+		conn.PushDeadlines = *input.PushDeadlines
+	}
+	if input.PushActionItems != nil {
+		conn.PushActionItems = *input.PushActionItems
+	}
+	if input.ReadBusy != nil {
+		conn.ReadBusy = *input.ReadBusy
+	}
+	if err := s.repo.UpdateSettings(ctx, conn); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Disconnect removes a connection and, as far as the provider still allows,
+// the events pushed to the calendar
+func (s *Service) Disconnect(ctx context.Context, tenantID, userID, id uuid.UUID) error {
+	conn, err := s.repo.Get(ctx, tenantID, userID, id)
+	if err != nil {
+		return err
+	}
+	if conn.Status == StatusActive {
+		s.removeAll(ctx, conn)
+	}
+	return s.repo.Delete(ctx, conn.ID)
+}
+
+// Sync brings the calendar of a connection up to date now
+func (s *Service) Sync(ctx context.Context, tenantID, userID, id uuid.UUID) (*SyncResult, error) {
+	conn, err := s.repo.Get(ctx, tenantID, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.sync(ctx, conn)
+}
+
+// SyncAll brings the calendars of all active connections up to date and
+// returns how many were synced and how many failed
+func (s *Service) SyncAll(ctx context.Context) (synced, failed int, err error) {
+	conns, err := s.repo.ListActive(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, conn := range conns {
+		if ctx.Err() != nil {
+			return synced, failed, ctx.Err()
+		}
+		result, err := s.sync(ctx, conn)
+		if err != nil {
+			s.logger.Warn("calendar sync failed",
+				"connection_id", conn.ID, "provider", conn.Provider, "error", err)
+			failed++
+			continue
+		}
+		if result.Failed > 0 {
+			s.logger.Warn("calendar sync incomplete",
+				"connection_id", conn.ID, "provider", conn.Provider, "failed", result.Failed, "error", *conn.LastError)
+		}
+		synced++
+	}
+	return synced, failed, nil
+}
+
+// BusyHours returns the busy time of the tenant's users between two days
+// from the connections that read it, per user and week. Calendars that
+// cannot be read are skipped.
+func (s *Service) BusyHours(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (map[uuid.UUID]map[string]float64, error) {
+	conns, err := s.repo.ListBusy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	intervals := make(map[uuid.UUID][]Interval)
+	for _, conn := range conns {
+		p, sess, err := s.open(ctx, conn)
+		if err == nil {
+			var busy []Interval
+			busy, err = p.Busy(ctx, sess.client, conn.CalendarID, from, to)
+			s.keepToken(ctx, conn, sess)
+			intervals[conn.UserID] = append(intervals[conn.UserID], busy...)
+		}
+		if err != nil {
+			s.logger.Warn("failed to read busy times",
+				"connection_id", conn.ID, "provider", conn.Provider, "error", err)
+			if errors.Is(err, ErrAuthorizationRevoked) {
+				s.markFailed(ctx, conn, err)
+			}
+		}
+	}
+
+	busy := make(map[uuid.UUID]map[string]float64, len(intervals))
+	for userID, list := range intervals {
+		busy[userID] = BusyPerWeek(list)
+	}
+	return busy, nil
+}
+
+// session is an authorized client of a connection. The token source
+// refreshes the access token as needed.
+type session struct {
+	client *http.Client
+	source oauth2.TokenSource
+	token  *oauth2.Token
+}
+
+// open returns the provider and an authorized client of a connection
+func (s *Service) open(ctx context.Context, conn *Connection) (Provider, *session, error) {
+	p, err := s.provider(conn.Provider)
+	if err != nil {
+		return nil, nil, err
+	}
+	plain, err := crypto.Decrypt(conn.token, s.key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt calendar token: %w", err)
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal(plain, &token); err != nil {
+		return nil, nil, fmt.Errorf("invalid calendar token: %w", err)
+	}
+	source := p.OAuth().TokenSource(ctx, &token)
+	return p, &session{client: oauth2.NewClient(ctx, source), source: source, token: &token}, nil
+}
+
+// keepToken stores the access token if it was refreshed during a session,
+// and a new refresh token if the provider rotated it
+func (s *Service) keepToken(ctx context.Context, conn *Connection, sess *session) {
+	token, err := sess.source.Token()
+	if err != nil || token.AccessToken == sess.token.AccessToken {
+		return
+	}
+	encrypted, err := s.encryptToken(token)
+	if err == nil {
+		err = s.repo.UpdateToken(ctx, conn.ID, encrypted)
+	}
+	if err != nil {
+		s.logger.Warn("failed to store refreshed calendar token", "connection_id", conn.ID, "error", err)
+		return
+	}
+	conn.token = encrypted
+	sess.token = token
+}
+
+func (s *Service) encryptToken(token *oauth2.Token) ([]byte, error) {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := crypto.Encrypt(data, s.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt calendar token: %w", err)
+	}
+	return encrypted, nil
+}
+
+// sync pushes the connection's items within the sync window and removes
+// the events of items that were completed, dismissed or deleted. Events of
+// items that left the window behind stay in the calendar and are
+// forgotten.
+func (s *Service) sync(ctx context.Context, conn *Connection) (*SyncResult, error) {
+	p, sess, err := s.open(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	defer s.keepToken(ctx, conn, sess)
+
+	today := timezone.Today(s.now(), timezone.Of(ctx, s.timezones, conn.TenantID))
+	from := today.AddDate(0, 0, -PastDays)
+	until := today.AddDate(0, 0, s.cfg.HorizonDays)
+
+	var items []Item
+	if conn.PushDeadlines {
+		deadlines, err := s.repo.ListDeadlines(ctx, conn.TenantID, conn.UserID, from, until)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range deadlines {
+			items = append(items, DeadlineItem(d, s.cfg.AppURL))
+		}
+	}
+	if conn.PushActionItems {
+		actions, err := s.repo.ListActionItems(ctx, conn.TenantID, conn.UserID, from, until)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range actions {
+			items = append(items, ActionItemItem(a, s.cfg.AppURL))
+		}
+	}
+	events, err := s.repo.ListEvents(ctx, conn.ID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	create, update, remove := Plan(items, events)
+	result := &SyncResult{}
+	applyErr := s.apply(ctx, p, sess, conn, create, update, remove, result)
+	if errors.Is(applyErr, ErrAuthorizationRevoked) {
+		s.markFailed(ctx, conn, applyErr)
+		return nil, applyErr
+	}
+	if err := s.repo.ForgetEvents(ctx, conn.ID, from); err != nil {
+		return nil, err
+	}
+
+	result.At = s.now()
+	conn.Status = StatusActive
+	conn.LastError = nil
+	if applyErr != nil {
+		msg := applyErr.Error()
+		conn.LastError = &msg
+	}
+	conn.LastSyncedAt = &result.At
+	if err := s.repo.MarkSynced(ctx, conn); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// apply carries out a sync plan. Items that fail are counted and the last
+// error is returned; a revoked authorization stops the sync.
+func (s *Service) apply(ctx context.Context, p Provider, sess *session, conn *Connection,
+	create []Item, update []Update, remove []Event, result *SyncResult) error {
+	var lastErr error
+	for _, item := range create {
+		err := s.push(ctx, p, sess, conn, "", item)
+		if errors.Is(err, ErrAuthorizationRevoked) {
+			return err
+		}
+		if err != nil {
+			result.Failed++
+			lastErr = err
+			continue
+		}
+		result.Created++
+	}
+	for _, u := range update {
+		err := s.push(ctx, p, sess, conn, u.EventID, u.Item)
+		if errors.Is(err, ErrEventNotFound) {
+			// Deleted in the calendar: the changed item is pushed again
+			err = s.push(ctx, p, sess, conn, "", u.Item)
+		}
+		if errors.Is(err, ErrAuthorizationRevoked) {
+			return err
+		}
+		if err != nil {
+			result.Failed++
+			lastErr = err
+			continue
+		}
+		result.Updated++
+	}
+	for _, e := range remove {
+		err := p.DeleteEvent(ctx, sess.client, conn.CalendarID, e.EventID)
+		if err == nil || errors.Is(err, ErrEventNotFound) {
+			err = s.repo.DeleteEvent(ctx, conn.ID, e)
+		}
+		if errors.Is(err, ErrAuthorizationRevoked) {
+			return err
+		}
+		if err != nil {
+			result.Failed++
+			lastErr = err
+			continue
+		}
+		result.Deleted++
+	}
+	return lastErr
+}
+
+// push creates or replaces the event of an item and records it
+func (s *Service) push(ctx context.Context, p Provider, sess *session, conn *Connection, eventID string, item Item) error {
+	id, err := p.PutEvent(ctx, sess.client, conn.CalendarID, eventID, item)
+	if err != nil {
+		return err
+	}
+	return s.repo.SaveEvent(ctx, conn.ID, Event{
+		SourceType: item.SourceType,
+		SourceID:   item.SourceID,
+		EventID:    id,
+		Hash:       Hash(item),
+		Date:       item.Date,
+	})
+}
+
+// removeAll deletes the events pushed to a connection's calendar, best
+// effort, and their records
+func (s *Service) removeAll(ctx context.Context, conn *Connection) {
+	events, err := s.repo.ListEvents(ctx, conn.ID, time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || len(events) == 0 {
+		return
+	}
+	p, sess, err := s.open(ctx, conn)
+	if err != nil {
+		return
+	}
+	defer s.keepToken(ctx, conn, sess)
+	for _, e := range events {
+		err := p.DeleteEvent(ctx, sess.client, conn.CalendarID, e.EventID)
+		if err != nil && !errors.Is(err, ErrEventNotFound) {
+			s.logger.Warn("failed to delete calendar event", "connection_id", conn.ID, "error", err)
+			if errors.Is(err, ErrAuthorizationRevoked) {
+				break
+			}
+		}
+	}
+	if err := s.repo.DeleteEvents(ctx, conn.ID); err != nil {
+		s.logger.Warn("failed to delete calendar event records", "connection_id", conn.ID, "error", err)
+	}
+}
+
+// markFailed records that the provider rejected the connection's token;
+// syncing stops until the calendar is connected again
+func (s *Service) markFailed(ctx context.Context, conn *Connection, err error) {
+	msg := err.Error()
+	conn.Status = StatusError
+	conn.LastError = &msg
+	if err := s.repo.MarkSynced(ctx, conn); err != nil {
+		s.logger.Warn("failed to update calendar connection", "connection_id", conn.ID, "error", err)
+	}
+}
@@ -0,0 +1,139 @@
+// Package calendarsync connects users' Google and Microsoft 365 calendars.
+// Deadlines extracted from documents and the user's action items are
+// pushed as all-day events and updated or removed when they change; the
+// busy times of the calendar can be read for the Förderung capacity
+// planner.
+package calendarsync
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownProvider      = errors.New("provider must be google or microsoft")
+	ErrProviderDisabled     = errors.New("calendar provider is not configured")
+	ErrInvalidState         = errors.New("invalid or expired OAuth state")
+	ErrConnectionNotFound   = errors.New("calendar connection not found")
+	ErrInvalidCalendarID    = errors.New("calendar_id must be at most 255 characters")
+	ErrEventNotFound        = errors.New("calendar event not found")
+	ErrAuthorizationRevoked = errors.New("calendar access has been revoked; connect the calendar again")
+)
+
+// Providers
+const (
+	ProviderGoogle    = "google"
+	ProviderMicrosoft = "microsoft"
+)
+
+// Status of a connection
+const (
+	StatusActive = "active"
+	StatusError  = "error"
+)
+
+// Sources of a pushed event
+const (
+	SourceDeadline   = "deadline"
+	SourceActionItem = "action_item"
+)
+
+// DefaultCalendarID is the user's primary calendar
+const DefaultCalendarID = "primary"
+
+// DefaultHorizonDays is how far ahead deadlines and action items are pushed
+const DefaultHorizonDays = 90
+
+// PastDays is how long after their date deadlines and action items are
+// still kept up to date; older events stay in the calendar unchanged
+const PastDays = 7
+
+// Connection is a user's connected calendar account
+type Connection struct {
+	ID              uuid.UUID  `json:"id"`
+	TenantID        uuid.UUID  `json:"tenant_id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	Provider        string     `json:"provider"`
+	AccountEmail    string     `json:"account_email"`
+	CalendarID      string     `json:"calendar_id"`
+	PushDeadlines   bool       `json:"push_deadlines"`
+	PushActionItems bool       `json:"push_action_items"`
+	ReadBusy        bool       `json:"read_busy"`
+	Status          string     `json:"status"`
+	LastError       *string    `json:"last_error,omitempty"`
+	LastSyncedAt    *time.Time `json:"last_synced_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	token []byte // encrypted OAuth token
+}
+
+// UpdateInput changes the settings of a connection; nil fields are kept
+type UpdateInput struct {
+	CalendarID      *string `json:"calendar_id"`
+	PushDeadlines   *bool   `json:"push_deadlines"`
+	PushActionItems *bool   `json:"push_action_items"`
+	ReadBusy        *bool   `json:"read_busy"`
+}
+
+// Item is a deadline or action item to show in the calendar
+type Item struct {
+	SourceType  string
+	SourceID    uuid.UUID
+	Title       string
+	Description string
+	Date        time.Time
+	URL         string
+}
+
+// Deadline is a deadline extracted from a document
+type Deadline struct {
+	ID            uuid.UUID
+	DocumentID    uuid.UUID
+	Type          string
+	Date          time.Time
+	DocumentTitle string
+	Rule          string
+	SourceText    string
+}
+
+// ActionItem is an action item assigned to the user
+type ActionItem struct {
+	ID          uuid.UUID
+	DocumentID  *uuid.UUID
+	Title       string
+	Description string
+	DueDate     time.Time
+}
+
+// Event is the calendar event of an item pushed to a connection
+type Event struct {
+	SourceType string
+	SourceID   uuid.UUID
+	EventID    string
+	Hash       string
+	Date       time.Time
+}
+
+// Update is an item whose event has to be changed
+type Update struct {
+	Item    Item
+	EventID string
+}
+
+// Interval is a busy time of a calendar
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// SyncResult counts the events changed by a sync
+type SyncResult struct {
+	Created int       `json:"created"`
+	Updated int       `json:"updated"`
+	Deleted int       `json:"deleted"`
+	Failed  int       `json:"failed"`
+	At      time.Time `json:"at"`
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"austrian-business-infrastructure/internal/config"
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/pkg/database"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Demo command flags
+	demoOpts      demo.Options
+	demoNoStorage bool
+)
+
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Create and remove demo tenants",
+	Long: `Create demo tenants with representative Austrian data (documents with
+analyses, invoices, Förderanträge, ELDA employees) and remove them again.
+
+Connects to the platform database directly and uses the server's environment:
+DATABASE_URL, ENCRYPTION_KEY and the STORAGE_* variables.
+
+Commands:
+  seed      - Create a demo tenant
+  list      - List demo tenants
+  teardown  - Remove a demo tenant`,
+}
+
+var demoSeedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Create a demo tenant",
+	Long: `Create a demo tenant. The data is derived from the seed: the same seed
+produces the same tenant, so a demo can be reproduced after a teardown.
+
+Examples:
+  fo demo seed
+  fo demo seed --name "Huber Haustechnik GmbH" --seed 42 --employees 20`,
+	Args: cobra.NoArgs,
+	RunE: runDemoSeed,
+}
+
+var demoListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List demo tenants",
+	Args:  cobra.NoArgs,
+	RunE:  runDemoList,
+}
+
+var demoTeardownCmd = &cobra.Command{
+	Use:   "teardown <tenant-id|slug>",
+	Short: "Remove a demo tenant",
+	Long: `Remove a demo tenant with all its data and document files. Tenants not
+created by 'fo demo seed' or the demo endpoint are refused.
+
+Examples:
+  fo demo teardown demo-huber-haustechnik-gmbh-42`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDemoTeardown,
+}
+
+func init() {
+	demoSeedCmd.Flags().StringVar(&demoOpts.Name, "name", "", "Tenant name (default: generated)")
+	demoSeedCmd.Flags().Int64Var(&demoOpts.Seed, "seed", 1, "Seed of the generated data")
+	demoSeedCmd.Flags().IntVar(&demoOpts.Employees, "employees", demo.DefaultEmployees, "Number of employees")
+	demoSeedCmd.Flags().IntVar(&demoOpts.Documents, "documents", demo.DefaultDocuments, "Number of databox documents")
+	demoSeedCmd.Flags().IntVar(&demoOpts.Invoices, "invoices", demo.DefaultInvoices, "Number of invoices")
+	demoSeedCmd.Flags().IntVar(&demoOpts.Applications, "applications", demo.DefaultApplications, "Number of Förderanträge")
+	demoSeedCmd.Flags().StringVar(&demoOpts.OwnerPassword, "password", "", "Owner password (default: generated)")
+	demoCmd.PersistentFlags().BoolVar(&demoNoStorage, "no-storage", false, "Do not write or delete document files")
+
+	demoCmd.AddCommand(demoSeedCmd)
+	demoCmd.AddCommand(demoListCmd)
+	demoCmd.AddCommand(demoTeardownCmd)
+
+	rootCmd.AddCommand(demoCmd)
+}
+
+// demoService connects to the database named by DATABASE_URL
+func demoService(ctx context.Context) (*demo.Service, func(), error) {
+	db, err := database.NewPool(ctx, database.DefaultPostgresConfig(os.Getenv("DATABASE_URL")))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	var storage document.Storage
+	if !demoNoStorage {
+		storage, err = document.NewRegionalStorage(document.NewStorageConfig(config.LoadStorageConfig()), document.NewRepository(db.Pool))
+		if err != nil {
+			db.Close()
+			return nil, nil, fmt.Errorf("failed to create document storage: %w", err)
+		}
+	}
+
+	svc, err := demo.NewService(db.Pool, []byte(os.Getenv("ENCRYPTION_KEY")), storage)
+	if err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("invalid ENCRYPTION_KEY: %w", err)
+	}
+	return svc, db.Close, nil
+}
+
+func runDemoSeed(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	svc, closeDB, err := demoService(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	LogVerbose("Seeding demo tenant with seed %d", demoOpts.Seed)
+	result, err := svc.Seed(ctx, demoOpts, "cli", nil)
+	if err != nil {
+		return err
+	}
+
+	if IsJSONOutput() {
+		return outputJSON(result)
+	}
+
+	fmt.Printf("Demo tenant created: %s (%s)\n", result.Name, result.Slug)
+	fmt.Printf("  Tenant ID:    %s\n", result.TenantID)
+	fmt.Printf("  Login:        %s\n", result.OwnerEmail)
+	if result.OwnerPassword != "" {
+		fmt.Printf("  Password:     %s\n", result.OwnerPassword)
+	}
+	fmt.Printf("  Employees:    %d\n", result.Counts.Employees)
+	fmt.Printf("  Documents:    %d (%d action items)\n", result.Counts.Documents, result.Counts.ActionItems)
+	fmt.Printf("  Invoices:     %d\n", result.Counts.Invoices)
+	fmt.Printf("  Applications: %d\n", result.Counts.Applications)
+	for _, note := range result.Notes {
+		fmt.Fprintf(errWriter, "Note: %s\n", note)
+	}
+	return nil
+}
+
+func runDemoList(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	svc, closeDB, err := demoService(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	tenants, err := svc.List(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if IsJSONOutput() {
+		return outputJSON(tenants)
+	}
+	if len(tenants) == 0 {
+		fmt.Println("No demo tenants.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SLUG\tNAME\tSEED\tCREATED BY\tCREATED")
+	for _, t := range tenants {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", t.Slug, t.Name, t.Seed, t.CreatedBy, t.CreatedAt.Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
+
+func runDemoTeardown(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	svc, closeDB, err := demoService(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	if id, parseErr := uuid.Parse(args[0]); parseErr == nil {
+		err = svc.Teardown(ctx, id, nil)
+	} else {
+		_, err = svc.TeardownBySlug(ctx, args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Demo tenant %s removed.\n", args[0])
+	return nil
+}
@@ -10,6 +10,12 @@ import (
 	"github.com/google/uuid"
 )
 
+// Portal authentication cookie names
+const (
+	AccessTokenCookieName  = "portal_access_token"
+	RefreshTokenCookieName = "portal_refresh_token"
+)
+
 var (
 	ErrUnauthorized     = errors.New("unauthorized")
 	ErrTokenExpired     = errors.New("token expired")
@@ -162,7 +168,7 @@ func PortalAuthMiddleware(auth *ClientAuth) func(http.Handler) http.Handler {
 			tokenString := extractBearerToken(r)
 			if tokenString == "" {
 				// Try cookie as fallback
-				cookie, err := r.Cookie("portal_access_token")
+				cookie, err := r.Cookie(AccessTokenCookieName)
 				if err == nil {
 					tokenString = cookie.Value
 				}
@@ -216,7 +222,7 @@ type AuthResponse struct {
 func SetAuthCookies(w http.ResponseWriter, accessToken, refreshToken string, accessExpiry, refreshExpiry time.Duration, secure bool) {
 	// Access token cookie
 	http.SetCookie(w, &http.Cookie{
-		Name:     "portal_access_token",
+		Name:     AccessTokenCookieName,
 		Value:    accessToken,
 		Path:     "/",
 		MaxAge:   int(accessExpiry.Seconds()),
@@ -227,7 +233,7 @@ func SetAuthCookies(w http.ResponseWriter, accessToken, refreshToken string, acc
 
 	// Refresh token cookie
 	http.SetCookie(w, &http.Cookie{
-		Name:     "portal_refresh_token",
+		Name:     RefreshTokenCookieName,
 		Value:    refreshToken,
 		Path:     "/api/v1/portal/refresh",
 		MaxAge:   int(refreshExpiry.Seconds()),
@@ -240,7 +246,7 @@ func SetAuthCookies(w http.ResponseWriter, accessToken, refreshToken string, acc
 // ClearAuthCookies clears the authentication cookies
 func ClearAuthCookies(w http.ResponseWriter) {
 	http.SetCookie(w, &http.Cookie{
-		Name:     "portal_access_token",
+		Name:     AccessTokenCookieName,
 		Value:    "",
 		Path:     "/",
 		MaxAge:   -1,
@@ -248,7 +254,7 @@ func ClearAuthCookies(w http.ResponseWriter) {
 	})
 
 	http.SetCookie(w, &http.Cookie{
-		Name:     "portal_refresh_token",
+		Name:     RefreshTokenCookieName,
 		Value:    "",
 		Path:     "/api/v1/portal/refresh",
 		MaxAge:   -1,
@@ -2,15 +2,18 @@ package client
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
 	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/customfield"
 	"austrian-business-infrastructure/internal/email"
 	"austrian-business-infrastructure/internal/tenant"
 )
@@ -41,6 +44,7 @@ func (h *Handler) Routes() chi.Router {
 	r.Group(func(r chi.Router) {
 		r.Post("/invite", h.Invite)
 		r.Get("/", h.List)
+		r.Get("/export", h.Export)
 		r.Get("/{id}", h.GetByID)
 		r.Put("/{id}", h.Update)
 		r.Delete("/{id}", h.Deactivate)
@@ -116,6 +120,10 @@ func (h *Handler) Invite(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "client with this email already exists", http.StatusConflict)
 			return
 		}
+		if customfield.IsInvalid(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "failed to create invitation", http.StatusInternalServerError)
 		return
 	}
@@ -194,7 +202,17 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		offset = 0
 	}
 
-	clients, total, err := h.service.List(ctx, tenantID, status, limit, offset)
+	fields, err := h.service.CustomFieldFilters(ctx, tenantID, customfield.ParseFilterParams(r.URL.Query()))
+	if err != nil {
+		if customfield.IsInvalid(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "failed to list clients", http.StatusInternalServerError)
+		return
+	}
+
+	clients, total, err := h.service.List(ctx, tenantID, status, fields, limit, offset)
 	if err != nil {
 		http.Error(w, "failed to list clients", http.StatusInternalServerError)
 		return
@@ -211,6 +229,80 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// Export writes the tenant's clients as CSV, one column per custom field
+// after the fixed columns. It takes the status and cf.<key> filters of List.
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID := tenant.GetTenantID(ctx)
+	if tenantID == uuid.Nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var status *Status
+	if statusStr := r.URL.Query().Get("status"); statusStr != "" {
+		s := Status(statusStr)
+		if !IsValidStatus(statusStr) {
+			http.Error(w, "invalid status", http.StatusBadRequest)
+			return
+		}
+		status = &s
+	}
+
+	fields, err := h.service.CustomFieldFilters(ctx, tenantID, customfield.ParseFilterParams(r.URL.Query()))
+	if err != nil {
+		if customfield.IsInvalid(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "failed to export clients", http.StatusInternalServerError)
+		return
+	}
+
+	defs, err := h.service.CustomFieldDefinitions(ctx, tenantID)
+	if err != nil {
+		http.Error(w, "failed to export clients", http.StatusInternalServerError)
+		return
+	}
+	clients, _, err := h.service.List(ctx, tenantID, status, fields, MaxExportRows, 0)
+	if err != nil {
+		http.Error(w, "failed to export clients", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=clients.csv")
+
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	header := []string{"ID", "Name", "Company", "Email", "Phone", "Status", "Language", "Created At"}
+	writer.Write(append(header, customfield.CSVHeader(defs)...))
+
+	for _, c := range clients {
+		company, phone := "", ""
+		if c.CompanyName != nil {
+			company = *c.CompanyName
+		}
+		if c.Phone != nil {
+			phone = *c.Phone
+		}
+
+		row := []string{
+			c.ID.String(),
+			customfield.CSVCell(c.Name),
+			customfield.CSVCell(company),
+			customfield.CSVCell(c.Email),
+			customfield.CSVCell(phone),
+			string(c.Status),
+			c.Language,
+			c.CreatedAt.Format(time.RFC3339),
+		}
+		writer.Write(append(row, customfield.CSVValues(defs, c.CustomFields)...))
+	}
+}
+
 // GetByID returns a client by ID
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -239,6 +331,7 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	// Verify tenant access
 	if clientDetail.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "client", clientDetail.ID.String())
 		http.Error(w, "client not found", http.StatusNotFound)
 		return
 	}
@@ -276,16 +369,18 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 
 	// Verify tenant access
 	if client.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "client", client.ID.String())
 		http.Error(w, "client not found", http.StatusNotFound)
 		return
 	}
 
 	// Parse update request
 	var req struct {
-		Name        *string     `json:"name"`
-		CompanyName *string     `json:"company_name"`
-		Phone       *string     `json:"phone"`
-		AccountIDs  []uuid.UUID `json:"account_ids"`
+		Name         *string                `json:"name"`
+		CompanyName  *string                `json:"company_name"`
+		Phone        *string                `json:"phone"`
+		AccountIDs   []uuid.UUID            `json:"account_ids"`
+		CustomFields map[string]interface{} `json:"custom_fields"` // merged; null removes a field
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
@@ -302,6 +397,16 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.Phone != nil {
 		client.Phone = req.Phone
 	}
+	if req.CustomFields != nil {
+		if err := h.service.UpdateCustomFields(ctx, client, req.CustomFields); err != nil {
+			if customfield.IsInvalid(err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "failed to update client", http.StatusInternalServerError)
+			return
+		}
+	}
 
 	if err := h.service.repo.Update(ctx, client); err != nil {
 		http.Error(w, "failed to update client", http.StatusInternalServerError)
@@ -359,6 +464,7 @@ func (h *Handler) Deactivate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if client.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "client", client.ID.String())
 		http.Error(w, "client not found", http.StatusNotFound)
 		return
 	}
@@ -401,6 +507,7 @@ func (h *Handler) ResendInvitation(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if client.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "client", client.ID.String())
 		http.Error(w, "client not found", http.StatusNotFound)
 		return
 	}
@@ -559,7 +666,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 // RefreshToken refreshes the access token
 func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	// Get refresh token from cookie
-	cookie, err := r.Cookie("portal_refresh_token")
+	cookie, err := r.Cookie(RefreshTokenCookieName)
 	if err != nil {
 		http.Error(w, "refresh token required", http.StatusUnauthorized)
 		return
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"time"
 
+	"austrian-business-infrastructure/internal/customfield"
+
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -61,6 +63,9 @@ type Client struct {
 	NotificationPortal bool   `json:"notification_portal"`
 	Language           string `json:"language"`
 
+	// Tenant-defined fields, see the customfield package
+	CustomFields customfield.Values `json:"custom_fields,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -107,7 +112,7 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 const clientColumns = `id, tenant_id, user_id, email, name, company_name, phone,
 	status, invited_at, activated_at, last_login_at,
 	notification_email, notification_portal, language,
-	created_at, updated_at`
+	created_at, updated_at, custom_fields`
 
 // Create creates a new client
 func (r *Repository) Create(ctx context.Context, client *Client) error {
@@ -122,9 +127,10 @@ func (r *Repository) Create(ctx context.Context, client *Client) error {
 	query := `
 		INSERT INTO clients (
 			id, tenant_id, email, name, company_name, phone,
-			status, invited_at, notification_email, notification_portal, language
+			status, invited_at, notification_email, notification_portal, language,
+			custom_fields
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING created_at, updated_at
 	`
 
@@ -140,6 +146,7 @@ func (r *Repository) Create(ctx context.Context, client *Client) error {
 		client.NotificationEmail,
 		client.NotificationPortal,
 		client.Language,
+		client.CustomFields.Param(),
 	).Scan(&client.CreatedAt, &client.UpdatedAt)
 
 	if err != nil {
@@ -171,8 +178,8 @@ func (r *Repository) GetByUserID(ctx context.Context, userID uuid.UUID) (*Client
 }
 
 // ListByTenant returns all clients for a tenant with optional filters
-func (r *Repository) ListByTenant(ctx context.Context, tenantID uuid.UUID, status *Status, limit, offset int) ([]*Client, int, error) {
-	// Build query with optional status filter
+func (r *Repository) ListByTenant(ctx context.Context, tenantID uuid.UUID, status *Status, fields []customfield.Filter, limit, offset int) ([]*Client, int, error) {
+	// Build query with optional status and custom field filters
 	countQuery := `SELECT COUNT(*) FROM clients WHERE tenant_id = $1`
 	listQuery := `SELECT ` + clientColumns + ` FROM clients WHERE tenant_id = $1`
 
@@ -184,6 +191,12 @@ func (r *Repository) ListByTenant(ctx context.Context, tenantID uuid.UUID, statu
 		args = append(args, *status)
 	}
 
+	if doc := customfield.FilterDocument(fields); doc != nil {
+		countQuery += ` AND custom_fields @> $` + itoa(len(args)+1)
+		listQuery += ` AND custom_fields @> $` + itoa(len(args)+1)
+		args = append(args, doc)
+	}
+
 	listQuery += ` ORDER BY created_at DESC`
 
 	// Get total count
@@ -231,7 +244,7 @@ func (r *Repository) Update(ctx context.Context, client *Client) error {
 		UPDATE clients
 		SET email = $2, name = $3, company_name = $4, phone = $5,
 			status = $6, notification_email = $7, notification_portal = $8,
-			language = $9, updated_at = NOW()
+			language = $9, custom_fields = $10, updated_at = NOW()
 		WHERE id = $1
 		RETURNING updated_at
 	`
@@ -246,6 +259,7 @@ func (r *Repository) Update(ctx context.Context, client *Client) error {
 		client.NotificationEmail,
 		client.NotificationPortal,
 		client.Language,
+		client.CustomFields.Param(),
 	).Scan(&client.UpdatedAt)
 
 	if err != nil {
@@ -418,6 +432,7 @@ func (r *Repository) scanClient(row pgx.Row) (*Client, error) {
 		&client.Language,
 		&client.CreatedAt,
 		&client.UpdatedAt,
+		&client.CustomFields,
 	)
 
 	if err != nil {
@@ -449,6 +464,7 @@ func (r *Repository) scanClientFromRows(rows pgx.Rows) (*Client, error) {
 		&client.Language,
 		&client.CreatedAt,
 		&client.UpdatedAt,
+		&client.CustomFields,
 	)
 
 	if err != nil {
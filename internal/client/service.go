@@ -8,6 +8,8 @@ import (
 	"errors"
 	"time"
 
+	"austrian-business-infrastructure/internal/customfield"
+
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -41,10 +43,11 @@ type Invitation struct {
 
 // InviteRequest contains data for inviting a client
 type InviteRequest struct {
-	Email       string      `json:"email"`
-	Name        string      `json:"name"`
-	CompanyName *string     `json:"company_name,omitempty"`
-	AccountIDs  []uuid.UUID `json:"account_ids"`
+	Email        string                 `json:"email"`
+	Name         string                 `json:"name"`
+	CompanyName  *string                `json:"company_name,omitempty"`
+	AccountIDs   []uuid.UUID            `json:"account_ids"`
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
 }
 
 // InviteResponse contains the result of an invitation
@@ -62,12 +65,16 @@ type ActivationInfo struct {
 	TenantName string `json:"tenant_name"`
 }
 
+// MaxExportRows caps the number of clients in one CSV export
+const MaxExportRows = 10000
+
 // Service provides client business logic
 type Service struct {
-	repo              *Repository
-	pool              *pgxpool.Pool
-	accountVerifier   AccountVerifier
-	invitationExpiry  time.Duration
+	repo             *Repository
+	pool             *pgxpool.Pool
+	accountVerifier  AccountVerifier
+	customFields     *customfield.Service
+	invitationExpiry time.Duration
 }
 
 // NewService creates a new client service
@@ -92,6 +99,11 @@ func (s *Service) SetAccountVerifier(verifier AccountVerifier) {
 	s.accountVerifier = verifier
 }
 
+// SetCustomFields enables the tenant's custom fields on clients
+func (s *Service) SetCustomFields(cf *customfield.Service) {
+	s.customFields = cf
+}
+
 // verifyAccountsOwnership verifies all account IDs belong to the tenant
 func (s *Service) verifyAccountsOwnership(ctx context.Context, tenantID uuid.UUID, accountIDs []uuid.UUID) error {
 	if s.accountVerifier == nil {
@@ -116,8 +128,29 @@ func (s *Service) GetByUserID(ctx context.Context, userID uuid.UUID) (*Client, e
 }
 
 // List returns all clients for a tenant
-func (s *Service) List(ctx context.Context, tenantID uuid.UUID, status *Status, limit, offset int) ([]*Client, int, error) {
-	return s.repo.ListByTenant(ctx, tenantID, status, limit, offset)
+func (s *Service) List(ctx context.Context, tenantID uuid.UUID, status *Status, fields []customfield.Filter, limit, offset int) ([]*Client, int, error) {
+	return s.repo.ListByTenant(ctx, tenantID, status, fields, limit, offset)
+}
+
+// CustomFieldDefinitions returns the tenant's custom fields on clients
+func (s *Service) CustomFieldDefinitions(ctx context.Context, tenantID uuid.UUID) ([]*customfield.Definition, error) {
+	return s.customFields.List(ctx, tenantID, customfield.EntityClient)
+}
+
+// CustomFieldFilters turns cf.<key> list parameters into filters
+func (s *Service) CustomFieldFilters(ctx context.Context, tenantID uuid.UUID, params map[string]string) ([]customfield.Filter, error) {
+	return s.customFields.Filters(ctx, tenantID, customfield.EntityClient, params)
+}
+
+// UpdateCustomFields merges custom field values into a client's values.
+// The client is not saved.
+func (s *Service) UpdateCustomFields(ctx context.Context, client *Client, input map[string]interface{}) error {
+	values, err := s.customFields.Validate(ctx, client.TenantID, customfield.EntityClient, input, client.CustomFields)
+	if err != nil {
+		return err
+	}
+	client.CustomFields = values
+	return nil
 }
 
 // Invite creates a new client invitation
@@ -128,6 +161,11 @@ func (s *Service) Invite(ctx context.Context, tenantID uuid.UUID, invitedBy uuid
 		return nil, err
 	}
 
+	customFields, err := s.customFields.Validate(ctx, tenantID, customfield.EntityClient, req.CustomFields, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 
 	// Create client record
@@ -141,6 +179,7 @@ func (s *Service) Invite(ctx context.Context, tenantID uuid.UUID, invitedBy uuid
 		NotificationEmail:  true,
 		NotificationPortal: true,
 		Language:           "de",
+		CustomFields:       customFields,
 	}
 
 	if err := s.repo.Create(ctx, client); err != nil {
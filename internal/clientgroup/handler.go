@@ -8,6 +8,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"austrian-business-infrastructure/internal/api"
 	"austrian-business-infrastructure/internal/tenant"
 )
 
@@ -131,6 +132,7 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	// Verify tenant access
 	if group.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "client_group", group.ID.String())
 		http.Error(w, "group not found", http.StatusNotFound)
 		return
 	}
@@ -167,6 +169,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if existing.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "client_group", existing.ID.String())
 		http.Error(w, "group not found", http.StatusNotFound)
 		return
 	}
@@ -215,6 +218,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if existing.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "client_group", existing.ID.String())
 		http.Error(w, "group not found", http.StatusNotFound)
 		return
 	}
@@ -255,6 +259,7 @@ func (h *Handler) ListMembers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if existing.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "client_group", existing.ID.String())
 		http.Error(w, "group not found", http.StatusNotFound)
 		return
 	}
@@ -299,6 +304,7 @@ func (h *Handler) SetMembers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if existing.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "client_group", existing.ID.String())
 		http.Error(w, "group not found", http.StatusNotFound)
 		return
 	}
@@ -353,6 +359,7 @@ func (h *Handler) AddMember(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if existing.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "client_group", existing.ID.String())
 		http.Error(w, "group not found", http.StatusNotFound)
 		return
 	}
@@ -403,6 +410,7 @@ func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if existing.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "client_group", existing.ID.String())
 		http.Error(w, "group not found", http.StatusNotFound)
 		return
 	}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// AbuseConfig holds the anti-automation limits of unauthenticated endpoints
+type AbuseConfig struct {
+	Window time.Duration
+
+	RegisterPerIP          int
+	RegisterPerEmail       int
+	ForgotPasswordPerIP    int
+	ForgotPasswordPerEmail int
+	ResetPasswordPerIP     int
+
+	// Challenge is pow, captcha or empty for none
+	Challenge string
+	// ChallengeEndpoints lists the endpoints requiring a solved challenge
+	ChallengeEndpoints []string
+
+	PoWSecret     string // signs challenges; must be shared by all API instances
+	PoWDifficulty int
+
+	CaptchaVerifyURL string
+	CaptchaSecret    string
+}
+
+// LoadAbuseConfig loads anti-automation configuration from environment variables
+func LoadAbuseConfig() *AbuseConfig {
+	return &AbuseConfig{
+		Window: getEnvDuration("ABUSE_WINDOW", time.Hour),
+
+		RegisterPerIP:          getEnvInt("ABUSE_REGISTER_PER_IP", 5),
+		RegisterPerEmail:       getEnvInt("ABUSE_REGISTER_PER_EMAIL", 3),
+		ForgotPasswordPerIP:    getEnvInt("ABUSE_FORGOT_PASSWORD_PER_IP", 10),
+		ForgotPasswordPerEmail: getEnvInt("ABUSE_FORGOT_PASSWORD_PER_EMAIL", 5),
+		ResetPasswordPerIP:     getEnvInt("ABUSE_RESET_PASSWORD_PER_IP", 20),
+
+		Challenge:          strings.ToLower(os.Getenv("ABUSE_CHALLENGE")),
+		ChallengeEndpoints: getEnvList("ABUSE_CHALLENGE_ENDPOINTS", []string{"register", "forgot_password"}),
+
+		PoWSecret:     os.Getenv("ABUSE_POW_SECRET"),
+		PoWDifficulty: getEnvInt("ABUSE_POW_DIFFICULTY", 20),
+
+		CaptchaVerifyURL: getEnv("CAPTCHA_VERIFY_URL", "https://challenges.cloudflare.com/turnstile/v0/siteverify"),
+		CaptchaSecret:    os.Getenv("CAPTCHA_SECRET"),
+	}
+}
+
+// RequiresChallenge reports whether an endpoint requires a solved challenge
+func (c *AbuseConfig) RequiresChallenge(endpoint string) bool {
+	if c.Challenge == "" {
+		return false
+	}
+	for _, e := range c.ChallengeEndpoints {
+		if e == endpoint {
+			return true
+		}
+	}
+	return false
+}
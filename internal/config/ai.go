@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// AIConfig configures the AI providers of document analysis and how
+// completions are routed between them
+type AIConfig struct {
+	Enabled bool
+
+	// Claude (Anthropic). Model is the strong model; FastModel is the cheap
+	// one classification is routed to by default.
+	ClaudeAPIKey    string
+	ClaudeModel     string
+	ClaudeFastModel string
+
+	// An OpenAI-compatible provider, the fallback by default. BaseURL
+	// points it at a compatible API other than OpenAI's.
+	OpenAIAPIKey  string
+	OpenAIBaseURL string
+	OpenAIModel   string
+
+	MaxTokens       int
+	RateLimitPerMin int
+	// MaxCostPerDoc is the most one document analysis may cost, in cents
+	MaxCostPerDoc int
+
+	// Routes is the routing policy, a list like
+	// "classification=claude:claude-3-5-haiku-latest,openai:gpt-4o-mini;default=claude:claude-sonnet-4-20250514,openai:gpt-4o"
+	// of the targets to try per operation. Empty routes classification to
+	// the fast model and everything else to the strong one, each falling
+	// back to the strong model and then to the OpenAI-compatible provider.
+	Routes string
+
+	// A target failing HealthFailureThreshold times in a row is skipped for
+	// HealthCooldown, then probed with one completion
+	HealthFailureThreshold int
+	HealthCooldown         time.Duration
+}
+
+// LoadAIConfig loads AI configuration from environment variables
+func LoadAIConfig() *AIConfig {
+	return &AIConfig{
+		Enabled: getEnvBool("AI_ENABLED", true),
+
+		ClaudeAPIKey:    os.Getenv("CLAUDE_API_KEY"),
+		ClaudeModel:     getEnv("CLAUDE_MODEL", "claude-sonnet-4-20250514"),
+		ClaudeFastModel: getEnv("CLAUDE_FAST_MODEL", "claude-3-5-haiku-20241022"),
+
+		OpenAIAPIKey:  os.Getenv("OPENAI_API_KEY"),
+		OpenAIBaseURL: os.Getenv("OPENAI_BASE_URL"),
+		OpenAIModel:   getEnv("OPENAI_MODEL", "gpt-4o"),
+
+		MaxTokens:       getEnvInt("CLAUDE_MAX_TOKENS", 4096),
+		RateLimitPerMin: getEnvInt("AI_RATE_LIMIT_PER_MIN", 60),
+		MaxCostPerDoc:   getEnvInt("AI_MAX_COST_PER_DOC_CENTS", 10),
+
+		Routes: os.Getenv("AI_ROUTES"),
+
+		HealthFailureThreshold: getEnvInt("AI_HEALTH_FAILURE_THRESHOLD", 3),
+		HealthCooldown:         getEnvDuration("AI_HEALTH_COOLDOWN", time.Minute),
+	}
+}
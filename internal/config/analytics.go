@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// AnalyticsConfig configures the product analytics events
+type AnalyticsConfig struct {
+	// Enabled turns event emission on; it is off by default
+	Enabled bool
+	// Sink is postgres or segment
+	Sink string
+	// PseudonymKey derives the tenant and user pseudonyms; all API instances
+	// need the same key, and changing it breaks funnels across the change
+	PseudonymKey string
+
+	SegmentEndpoint string
+	SegmentWriteKey string
+
+	BatchSize     int
+	FlushInterval time.Duration
+	// Retention applies to the postgres sink
+	Retention time.Duration
+}
+
+// LoadAnalyticsConfig loads analytics configuration from environment variables
+func LoadAnalyticsConfig() *AnalyticsConfig {
+	return &AnalyticsConfig{
+		Enabled:      getEnvBool("ANALYTICS_ENABLED", false),
+		Sink:         getEnv("ANALYTICS_SINK", "postgres"),
+		PseudonymKey: os.Getenv("ANALYTICS_PSEUDONYM_KEY"),
+
+		SegmentEndpoint: getEnv("ANALYTICS_SEGMENT_ENDPOINT", "https://api.segment.io"),
+		SegmentWriteKey: os.Getenv("ANALYTICS_SEGMENT_WRITE_KEY"),
+
+		BatchSize:     getEnvInt("ANALYTICS_BATCH_SIZE", 100),
+		FlushInterval: getEnvDuration("ANALYTICS_FLUSH_INTERVAL", 10*time.Second),
+		Retention:     getEnvDuration("ANALYTICS_RETENTION", 395*24*time.Hour),
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// AuditConfig configures the platform defaults of audit logs. Tenants can
+// override the IP anonymization and retention.
+type AuditConfig struct {
+	// IPAnonymization is full, truncate or hash
+	IPAnonymization string
+	// IPHashKey derives the hash salts; all API instances need the same key.
+	// Without one each process uses a random key.
+	IPHashKey    string
+	SaltRotation time.Duration
+
+	RetentionDays int
+	// ArchivePath is where the archive job writes logs past their retention
+	ArchivePath string
+}
+
+// LoadAuditConfig loads audit log configuration from environment variables
+func LoadAuditConfig() *AuditConfig {
+	return &AuditConfig{
+		IPAnonymization: getEnv("AUDIT_IP_ANONYMIZATION", "truncate"),
+		IPHashKey:       os.Getenv("AUDIT_IP_HASH_KEY"),
+		SaltRotation:    getEnvDuration("AUDIT_IP_SALT_ROTATION", 24*time.Hour),
+
+		RetentionDays: getEnvInt("AUDIT_RETENTION_DAYS", 90),
+		ArchivePath:   getEnv("AUDIT_ARCHIVE_PATH", "./data/audit-archive"),
+	}
+}
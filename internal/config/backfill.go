@@ -0,0 +1,25 @@
+package config
+
+import "time"
+
+// BackfillConfig configures the online backfills of large schema changes
+type BackfillConfig struct {
+	BatchSize    int
+	BatchPause   time.Duration
+	BatchTimeout time.Duration
+	// Interval is how often running backfills are picked up; each round
+	// works through batches for up to one interval
+	Interval        time.Duration
+	RefreshInterval time.Duration
+}
+
+// LoadBackfillConfig loads backfill configuration from environment variables
+func LoadBackfillConfig() *BackfillConfig {
+	return &BackfillConfig{
+		BatchSize:       getEnvInt("BACKFILL_BATCH_SIZE", 1000),
+		BatchPause:      getEnvDuration("BACKFILL_BATCH_PAUSE", 100*time.Millisecond),
+		BatchTimeout:    getEnvDuration("BACKFILL_BATCH_TIMEOUT", 30*time.Second),
+		Interval:        getEnvDuration("BACKFILL_INTERVAL", 10*time.Second),
+		RefreshInterval: getEnvDuration("BACKFILL_REFRESH_INTERVAL", 15*time.Second),
+	}
+}
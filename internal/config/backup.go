@@ -0,0 +1,39 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// BackupConfig configures backup orchestration and restore checks
+type BackupConfig struct {
+	DBSnapshotHook      string
+	StorageSnapshotHook string
+	RestoreHook         string
+	ScratchDatabaseURL  string
+
+	HookTimeout      time.Duration
+	DrainTimeout     time.Duration
+	RestoreCheckPoll time.Duration
+	ManifestTables   []string
+}
+
+// LoadBackupConfig loads backup configuration from environment variables
+func LoadBackupConfig() *BackupConfig {
+	return &BackupConfig{
+		DBSnapshotHook:      os.Getenv("BACKUP_DB_SNAPSHOT_HOOK"),
+		StorageSnapshotHook: os.Getenv("BACKUP_STORAGE_SNAPSHOT_HOOK"),
+		RestoreHook:         os.Getenv("BACKUP_RESTORE_HOOK"),
+		ScratchDatabaseURL:  os.Getenv("BACKUP_SCRATCH_DATABASE_URL"),
+
+		HookTimeout:      getEnvDuration("BACKUP_HOOK_TIMEOUT", time.Hour),
+		DrainTimeout:     getEnvDuration("BACKUP_DRAIN_TIMEOUT", 10*time.Minute),
+		RestoreCheckPoll: getEnvDuration("BACKUP_RESTORE_CHECK_POLL", time.Minute),
+		ManifestTables:   getEnvList("BACKUP_MANIFEST_TABLES", nil),
+	}
+}
+
+// RestoreCheckEnabled reports whether backups are verified by a restore
+func (c *BackupConfig) RestoreCheckEnabled() bool {
+	return c.RestoreHook != "" && c.ScratchDatabaseURL != ""
+}
@@ -0,0 +1,25 @@
+package config
+
+import "time"
+
+// BreakGlassConfig configures time-boxed break-glass access of platform
+// super-admins to tenants
+type BreakGlassConfig struct {
+	// SuperAdmins are the user IDs allowed to grant themselves access; none
+	// disables break-glass access
+	SuperAdmins     []string
+	DefaultDuration time.Duration
+	MaxDuration     time.Duration
+	// SweepInterval is how often expired grants are ended and notified
+	SweepInterval time.Duration
+}
+
+// LoadBreakGlassConfig loads break-glass configuration from environment variables
+func LoadBreakGlassConfig() *BreakGlassConfig {
+	return &BreakGlassConfig{
+		SuperAdmins:     getEnvList("BREAK_GLASS_SUPER_ADMINS", nil),
+		DefaultDuration: getEnvDuration("BREAK_GLASS_DEFAULT_DURATION", time.Hour),
+		MaxDuration:     getEnvDuration("BREAK_GLASS_MAX_DURATION", 4*time.Hour),
+		SweepInterval:   getEnvDuration("BREAK_GLASS_SWEEP_INTERVAL", time.Minute),
+	}
+}
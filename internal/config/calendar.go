@@ -0,0 +1,32 @@
+package config
+
+import "os"
+
+// CalendarConfig configures the Google and Microsoft 365 calendar
+// integration. A provider is offered once its client ID and secret are
+// set; they are the OAuth clients of the login with Google and Microsoft.
+type CalendarConfig struct {
+	GoogleClientID        string
+	GoogleClientSecret    string
+	MicrosoftClientID     string
+	MicrosoftClientSecret string
+
+	// RedirectBaseURL is the public base URL of the API the providers
+	// redirect back to, /api/v1/calendar/oauth/{provider}/callback
+	RedirectBaseURL string
+	// HorizonDays is how far ahead deadlines and action items are pushed
+	HorizonDays int
+}
+
+// LoadCalendarConfig loads calendar integration configuration from
+// environment variables
+func LoadCalendarConfig() *CalendarConfig {
+	return &CalendarConfig{
+		GoogleClientID:        os.Getenv("GOOGLE_CLIENT_ID"),
+		GoogleClientSecret:    os.Getenv("GOOGLE_CLIENT_SECRET"),
+		MicrosoftClientID:     os.Getenv("MICROSOFT_CLIENT_ID"),
+		MicrosoftClientSecret: os.Getenv("MICROSOFT_CLIENT_SECRET"),
+		RedirectBaseURL:       getEnv("CALENDAR_REDIRECT_BASE_URL", getEnv("APP_URL", "http://localhost:8080")),
+		HorizonDays:           getEnvInt("CALENDAR_SYNC_HORIZON_DAYS", 90),
+	}
+}
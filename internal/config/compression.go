@@ -0,0 +1,21 @@
+package config
+
+// CompressionConfig configures response compression
+type CompressionConfig struct {
+	Enabled bool
+	// Encodings in order of preference; zstd and gzip are supported
+	Encodings []string
+	// MinSize is the smallest response body in bytes that is compressed
+	MinSize   int
+	GzipLevel int // 1 (fastest) to 9, -1 for the gzip default
+}
+
+// LoadCompressionConfig loads response compression configuration from environment variables
+func LoadCompressionConfig() *CompressionConfig {
+	return &CompressionConfig{
+		Enabled:   getEnvBool("COMPRESSION_ENABLED", true),
+		Encodings: getEnvList("COMPRESSION_ENCODINGS", []string{"zstd", "gzip"}),
+		MinSize:   getEnvInt("COMPRESSION_MIN_SIZE", 1024),
+		GzipLevel: getEnvInt("COMPRESSION_GZIP_LEVEL", -1),
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// CustomDomainConfig configures tenant custom domains for public links
+type CustomDomainConfig struct {
+	// CNAMETarget is the host tenants point their domain at, e.g.
+	// links.example.at; shown as the DNS record to create
+	CNAMETarget string
+	// CertificateHook is run with CUSTOM_DOMAIN, CUSTOM_DOMAIN_ACTION
+	// (provision or remove) and TENANT_ID once a domain is verified or given up. Without
+	// a hook, certificates are left to a TLS proxy with on-demand TLS.
+	CertificateHook string
+	HookTimeout     time.Duration
+}
+
+// LoadCustomDomainConfig loads custom domain configuration from environment variables
+func LoadCustomDomainConfig() *CustomDomainConfig {
+	return &CustomDomainConfig{
+		CNAMETarget:     os.Getenv("CUSTOM_DOMAIN_CNAME_TARGET"),
+		CertificateHook: os.Getenv("CUSTOM_DOMAIN_CERT_HOOK"),
+		HookTimeout:     getEnvDuration("CUSTOM_DOMAIN_HOOK_TIMEOUT", 2*time.Minute),
+	}
+}
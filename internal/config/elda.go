@@ -0,0 +1,25 @@
+package config
+
+import "time"
+
+// ELDAPollConfig configures the polling of ELDA for the Rückmeldungen
+// (Protokolle) of submitted meldungen
+type ELDAPollConfig struct {
+	// Interval is how often a submitted meldung is asked about again;
+	// BatchSize caps the meldungen of one run
+	Interval  time.Duration
+	BatchSize int
+	// MaxAge stops polling meldungen submitted longer ago; they stay
+	// submitted
+	MaxAge time.Duration
+}
+
+// LoadELDAPollConfig loads ELDA polling configuration from environment
+// variables
+func LoadELDAPollConfig() *ELDAPollConfig {
+	return &ELDAPollConfig{
+		Interval:  getEnvDuration("ELDA_POLL_INTERVAL", 15*time.Minute),
+		BatchSize: getEnvInt("ELDA_POLL_BATCH_SIZE", 200),
+		MaxAge:    getEnvDuration("ELDA_POLL_MAX_AGE", 30*24*time.Hour),
+	}
+}
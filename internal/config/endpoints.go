@@ -0,0 +1,39 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// EndpointConfig configures the endpoint sets of external integrations
+type EndpointConfig struct {
+	// Endpoint sets as name=url; the first is active unless *Active names
+	// another one
+	FinanzOnlineEndpoints []string
+	FinanzOnlineActive    string
+	ELDAEndpoints         []string
+	ELDAActive            string
+
+	// Switchovers as integration:set@time and maintenance windows as
+	// integration@start/end, both RFC 3339
+	Switchovers        []string
+	MaintenanceWindows []string
+
+	RefreshInterval time.Duration
+}
+
+// LoadEndpointConfig loads endpoint configuration from environment variables
+func LoadEndpointConfig() *EndpointConfig {
+	foURL := getEnv("FO_WEBSERVICE_URL", "https://finanzonline.bmf.gv.at/fonws/ws")
+	eldaURL := getEnv("ELDA_ENDPOINT", "https://elda.sozvers.at/elda-webservice/")
+
+	return &EndpointConfig{
+		FinanzOnlineEndpoints: getEnvList("FO_ENDPOINTS", []string{"primary=" + foURL}),
+		FinanzOnlineActive:    os.Getenv("FO_ACTIVE_ENDPOINT"),
+		ELDAEndpoints:         getEnvList("ELDA_ENDPOINTS", []string{"primary=" + eldaURL}),
+		ELDAActive:            os.Getenv("ELDA_ACTIVE_ENDPOINT"),
+		Switchovers:           getEnvList("ENDPOINT_SWITCHOVERS", nil),
+		MaintenanceWindows:    getEnvList("ENDPOINT_MAINTENANCE_WINDOWS", nil),
+		RefreshInterval:       getEnvDuration("ENDPOINT_REFRESH_INTERVAL", 30*time.Second),
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// FirmenbuchConfig configures the Firmenbuch web service of the Justiz
+type FirmenbuchConfig struct {
+	// APIKey of the Firmenbuch web service; lookups and the watchlist
+	// checks are disabled without it
+	APIKey   string
+	TestMode bool
+
+	// CheckInterval is how often each company on a watchlist is fetched
+	// again and compared; CheckBatchSize caps the companies of one run
+	CheckInterval  time.Duration
+	CheckBatchSize int
+
+	// SearchCacheTTL is how long search responses are kept in Redis
+	SearchCacheTTL time.Duration
+}
+
+// LoadFirmenbuchConfig loads Firmenbuch configuration from environment
+// variables
+func LoadFirmenbuchConfig() *FirmenbuchConfig {
+	return &FirmenbuchConfig{
+		APIKey:         os.Getenv("FIRMENBUCH_API_KEY"),
+		TestMode:       getEnvBool("FIRMENBUCH_TEST_MODE", false),
+		CheckInterval:  getEnvDuration("FIRMENBUCH_CHECK_INTERVAL", 24*time.Hour),
+		CheckBatchSize: getEnvInt("FIRMENBUCH_CHECK_BATCH_SIZE", 200),
+		SearchCacheTTL: getEnvDuration("FIRMENBUCH_SEARCH_CACHE_TTL", time.Hour),
+	}
+}
@@ -35,6 +35,7 @@ type FoerderungConfig struct {
 	// Expiry Settings
 	ExpiryJobCron string // Cron for expiry check (default: "0 1 * * *")
 	ExpiryWarningDays int // Days before deadline to warn (default: 7)
+	ReviewAfterMonths int // Flag programs whose StandDatum is older for review (default: 6)
 
 	// Cache Settings
 	SearchCacheTTLHours int // Cache search results (default: 24)
@@ -74,6 +75,7 @@ func LoadFoerderungConfig() *FoerderungConfig {
 		// Expiry Settings
 		ExpiryJobCron:     getEnvDefault("FOERDERUNG_EXPIRY_CRON", "0 1 * * *"),
 		ExpiryWarningDays: getEnvIntDefault("FOERDERUNG_EXPIRY_WARNING_DAYS", 7),
+		ReviewAfterMonths: getEnvIntDefault("FOERDERUNG_REVIEW_AFTER_MONTHS", 6),
 
 		// Cache Settings
 		SearchCacheTTLHours:       getEnvIntDefault("FOERDERUNG_SEARCH_CACHE_TTL", 24),
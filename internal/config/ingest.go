@@ -0,0 +1,46 @@
+package config
+
+import (
+	"crypto/sha256"
+	"os"
+	"time"
+)
+
+// IngestConfig holds the settings of the document ingestion API
+type IngestConfig struct {
+	// UploadBaseURL is the public base URL of the API that upload URLs
+	// point to, /api/v1/ingest/uploads/{id}
+	UploadBaseURL string
+	// UploadSecret signs the upload URLs
+	UploadSecret string
+	// UploadTTL is how long an upload URL is valid
+	UploadTTL time.Duration
+	// MaxPendingPerSource is how many items an API key or user may have
+	// waiting for processing; 0 is unlimited
+	MaxPendingPerSource int
+}
+
+// LoadIngestConfig loads document ingestion configuration from environment
+// variables
+func LoadIngestConfig() *IngestConfig {
+	return &IngestConfig{
+		UploadBaseURL:       getEnv("INGEST_UPLOAD_BASE_URL", getEnv("APP_URL", "http://localhost:8080")),
+		UploadSecret:        os.Getenv("INGEST_UPLOAD_SECRET"),
+		UploadTTL:           getEnvDuration("INGEST_UPLOAD_TTL", time.Hour),
+		MaxPendingPerSource: getEnvInt("INGEST_MAX_PENDING_PER_SOURCE", 10000),
+	}
+}
+
+// UploadKey returns the key signing upload URLs: the upload secret, or
+// else a key derived from the encryption key. It returns nil if neither is
+// set.
+func (c *IngestConfig) UploadKey(encryptionKey string) []byte {
+	if c.UploadSecret != "" {
+		return []byte(c.UploadSecret)
+	}
+	if encryptionKey == "" {
+		return nil
+	}
+	derived := sha256.Sum256([]byte("ingest-upload:" + encryptionKey))
+	return derived[:]
+}
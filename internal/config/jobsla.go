@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// JobSLAConfig configures the service levels of background job types
+type JobSLAConfig struct {
+	// Enabled runs the SLA monitor in the worker
+	Enabled bool
+	// Window is the period success rates and durations are computed over
+	Window time.Duration
+	// MinRuns is how many finished runs a job type needs before it is judged
+	MinRuns       int
+	CheckInterval time.Duration
+
+	// MaxFailureRate and MaxP95 apply to job types without an override
+	MaxFailureRate float64
+	MaxP95         time.Duration
+	// Overrides is a list like "document_analysis=0.1/10m,databox_sync=0.02/2m"
+	// of a maximum failure rate and P95 duration per job type
+	Overrides string
+
+	// AlertWebhookURL receives raised and resolved alerts as JSON; alerts are
+	// logged and listed by the maintenance API either way
+	AlertWebhookURL string
+}
+
+// LoadJobSLAConfig loads job SLA configuration from environment variables
+func LoadJobSLAConfig() *JobSLAConfig {
+	return &JobSLAConfig{
+		Enabled:       getEnvBool("JOB_SLA_ENABLED", true),
+		Window:        getEnvDuration("JOB_SLA_WINDOW", 24*time.Hour),
+		MinRuns:       getEnvInt("JOB_SLA_MIN_RUNS", 20),
+		CheckInterval: getEnvDuration("JOB_SLA_CHECK_INTERVAL", 5*time.Minute),
+
+		MaxFailureRate: getEnvFloat("JOB_SLA_MAX_FAILURE_RATE", 0.05),
+		MaxP95:         getEnvDuration("JOB_SLA_MAX_P95", 10*time.Minute),
+		Overrides:      os.Getenv("JOB_SLA_OVERRIDES"),
+
+		AlertWebhookURL: os.Getenv("JOB_SLA_ALERT_WEBHOOK_URL"),
+	}
+}
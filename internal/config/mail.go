@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// MailConfig holds outgoing mail configuration
+type MailConfig struct {
+	// Provider is smtp, mailgun, ses or log; empty picks smtp when SMTP_HOST
+	// is set and log otherwise
+	Provider string
+	From     string
+	FromName string
+
+	// SMTP relay
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUser     string
+	SMTPPassword string
+
+	// Mailgun
+	MailgunDomain     string
+	MailgunAPIKey     string
+	MailgunSigningKey string
+	MailgunRegion     string // eu or us
+
+	// Amazon SES
+	SESRegion           string
+	SESAccessKeyID      string
+	SESSecretAccessKey  string
+	SESConfigurationSet string
+	SESTopicARN         string // SNS topic of bounce and complaint notifications
+
+	// DNS guidance for tenant sender domains
+	SPFInclude   string
+	DKIMSelector string
+	DKIMDomain   string
+}
+
+// LoadMailConfig loads mail configuration from environment variables
+func LoadMailConfig() *MailConfig {
+	cfg := &MailConfig{
+		Provider: strings.ToLower(os.Getenv("MAIL_PROVIDER")),
+		From:     getEnv("SMTP_FROM", "noreply@example.com"),
+		FromName: getEnv("MAIL_FROM_NAME", getEnv("APP_NAME", "Austrian Business Platform")),
+
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     getEnvInt("SMTP_PORT", 587),
+		SMTPUser:     os.Getenv("SMTP_USER"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+
+		MailgunDomain:     os.Getenv("MAILGUN_DOMAIN"),
+		MailgunAPIKey:     os.Getenv("MAILGUN_API_KEY"),
+		MailgunSigningKey: os.Getenv("MAILGUN_WEBHOOK_SIGNING_KEY"),
+		MailgunRegion:     getEnv("MAILGUN_REGION", "eu"),
+
+		SESRegion:           getEnv("SES_REGION", "eu-central-1"),
+		SESAccessKeyID:      os.Getenv("SES_ACCESS_KEY_ID"),
+		SESSecretAccessKey:  os.Getenv("SES_SECRET_ACCESS_KEY"),
+		SESConfigurationSet: os.Getenv("SES_CONFIGURATION_SET"),
+		SESTopicARN:         os.Getenv("SES_SNS_TOPIC_ARN"),
+
+		SPFInclude:   os.Getenv("MAIL_SPF_INCLUDE"),
+		DKIMSelector: getEnv("MAIL_DKIM_SELECTOR", "abi1"),
+		DKIMDomain:   os.Getenv("MAIL_DKIM_DOMAIN"),
+	}
+
+	if cfg.Provider == "" {
+		cfg.Provider = "log"
+		if cfg.SMTPHost != "" {
+			cfg.Provider = "smtp"
+		}
+	}
+	if cfg.DKIMDomain == "" {
+		if _, domain, ok := strings.Cut(cfg.From, "@"); ok {
+			cfg.DKIMDomain = domain
+		}
+	}
+
+	return cfg
+}
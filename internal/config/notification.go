@@ -0,0 +1,18 @@
+package config
+
+import "os"
+
+// NotificationConfig holds the notification settings
+type NotificationConfig struct {
+	// UnsubscribeSecret signs the one-click unsubscribe links of
+	// notification emails. Links stay valid as long as it does not change.
+	UnsubscribeSecret string
+}
+
+// LoadNotificationConfig loads notification configuration from environment
+// variables
+func LoadNotificationConfig() *NotificationConfig {
+	return &NotificationConfig{
+		UnsubscribeSecret: os.Getenv("NOTIFICATION_UNSUBSCRIBE_SECRET"),
+	}
+}
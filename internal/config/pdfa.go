@@ -0,0 +1,34 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// PDFAConfig configures the PDF/A archival conversion of stored documents
+type PDFAConfig struct {
+	Enabled         bool
+	GhostscriptPath string
+	LibreOfficePath string
+	VeraPDFPath     string // optional; without it only a structural check is made
+	ICCProfile      string
+	Timeout         time.Duration
+
+	SweepInterval time.Duration
+	SweepBatch    int
+}
+
+// LoadPDFAConfig loads PDF/A conversion configuration from environment variables
+func LoadPDFAConfig() *PDFAConfig {
+	return &PDFAConfig{
+		Enabled:         getEnvBool("PDFA_ENABLED", false),
+		GhostscriptPath: getEnv("PDFA_GHOSTSCRIPT_PATH", "gs"),
+		LibreOfficePath: getEnv("PDFA_LIBREOFFICE_PATH", "soffice"),
+		VeraPDFPath:     os.Getenv("PDFA_VERAPDF_PATH"),
+		ICCProfile:      getEnv("PDFA_ICC_PROFILE", "/usr/share/color/icc/ghostscript/srgb.icc"),
+		Timeout:         getEnvDuration("PDFA_TIMEOUT", 5*time.Minute),
+
+		SweepInterval: getEnvDuration("PDFA_SWEEP_INTERVAL", time.Hour),
+		SweepBatch:    getEnvInt("PDFA_SWEEP_BATCH", 100),
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"crypto/sha256"
+	"os"
+)
+
+// RetentionConfig holds the retention deletion settings
+type RetentionConfig struct {
+	// ProtocolSecret signs the deletion protocols of retention runs.
+	// Protocols signed with an earlier secret no longer verify.
+	ProtocolSecret string
+}
+
+// LoadRetentionConfig loads retention configuration from environment
+// variables
+func LoadRetentionConfig() *RetentionConfig {
+	return &RetentionConfig{
+		ProtocolSecret: os.Getenv("RETENTION_PROTOCOL_SECRET"),
+	}
+}
+
+// ProtocolKey returns the key signing deletion protocols: the protocol
+// secret, or else a key derived from the encryption key, so that server and
+// worker agree. It returns nil if neither is set.
+func (c *RetentionConfig) ProtocolKey(encryptionKey string) []byte {
+	if c.ProtocolSecret != "" {
+		return []byte(c.ProtocolSecret)
+	}
+	if encryptionKey == "" {
+		return nil
+	}
+	derived := sha256.Sum256([]byte("retention-protocol:" + encryptionKey))
+	return derived[:]
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// DefaultSecurityAlertThresholds raise an alert per client IP. IDOR probing
+// shows up as repeated cross-tenant attempts and is treated as critical.
+const DefaultSecurityAlertThresholds = "security.auth_failed=20/5m," +
+	"security.cross_tenant_attempt=5/10m:critical," +
+	"security.permission_denied=20/10m," +
+	"security.csrf_rejected=10/10m," +
+	"security.rate_limited=100/10m"
+
+// SecurityEventConfig configures the security event stream
+type SecurityEventConfig struct {
+	// WebhookURL receives events as signed NDJSON batches (e.g. a SIEM HTTP collector)
+	WebhookURL         string
+	WebhookSecret      string
+	WebhookMinSeverity string
+
+	// ExportToken authenticates SIEM collectors polling GET /api/v1/security/events;
+	// the endpoint is disabled when empty
+	ExportToken string
+
+	// AlertThresholds is a list like "security.auth_failed=20/5m,security.cross_tenant_attempt=5/10m:critical"
+	AlertThresholds string
+
+	Retention time.Duration
+}
+
+// LoadSecurityEventConfig loads security event stream configuration from environment variables
+func LoadSecurityEventConfig() *SecurityEventConfig {
+	return &SecurityEventConfig{
+		WebhookURL:         os.Getenv("SECURITY_EVENTS_WEBHOOK_URL"),
+		WebhookSecret:      os.Getenv("SECURITY_EVENTS_WEBHOOK_SECRET"),
+		WebhookMinSeverity: getEnv("SECURITY_EVENTS_WEBHOOK_MIN_SEVERITY", "medium"),
+
+		ExportToken: os.Getenv("SECURITY_EVENTS_EXPORT_TOKEN"),
+
+		AlertThresholds: getEnv("SECURITY_ALERT_THRESHOLDS", DefaultSecurityAlertThresholds),
+
+		Retention: getEnvDuration("SECURITY_EVENTS_RETENTION", 90*24*time.Hour),
+	}
+}
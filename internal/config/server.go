@@ -15,10 +15,16 @@ type ServerConfig struct {
 	LogLevel   string
 
 	// Database
-	DatabaseURL string
+	DatabaseURL    string
+	DatabaseTuning DatabaseTuning
 
 	// Redis
 	RedisURL string
+	// RedisRequired refuses to start without Redis; by default the server
+	// starts in degraded mode and recovers when Redis returns
+	RedisRequired bool
+	// RedisHealthInterval is how often Redis availability is checked
+	RedisHealthInterval time.Duration
 
 	// JWT
 	JWTSecret             string
@@ -50,9 +56,19 @@ type ServerConfig struct {
 	AppName        string
 	AppURL         string
 	AllowedOrigins []string
+	// CSRFTrustedOrigins are the SPA origins allowed to send cookie-authenticated requests
+	CSRFTrustedOrigins []string
+	// CSPReportOnly only reports Content Security Policy violations to CSPReportURI
+	CSPReportOnly bool
+	CSPReportURI  string
 
 	// Features
 	EnableRegistration bool
+	DemoSeedingEnabled bool // demo tenant endpoints, never in production
+
+	// MaintenanceToken authenticates ops tooling calling the platform-wide
+	// /api/v1/maintenance endpoints; they are disabled when empty
+	MaintenanceToken string
 
 	// AI Configuration
 	ClaudeAPIKey       string
@@ -77,6 +93,7 @@ type ServerConfig struct {
 	StorageS3AccessKeyID  string
 	StorageS3SecretKey    string
 	StorageS3UseSSL       bool
+	StorageDefaultQuota   int64 // bytes per tenant without an override, 0 = unlimited
 
 	// ELDA Configuration
 	ELDAEndpoint          string
@@ -86,6 +103,29 @@ type ServerConfig struct {
 	ELDARetryMax          int
 	ELDACertExpiryWarnDays int
 	ELDATestMode          bool
+
+	// Reference data (yearly statutory parameters), merged over the embedded defaults
+	ReferenceDataFile string
+
+	// VAT rates per country and validity period, merged over the embedded rates
+	VATRatesFile string
+}
+
+// DatabaseTuning holds the statement timeouts and pool observability settings
+type DatabaseTuning struct {
+	StatementTimeout    time.Duration // default for repositories bounded per module
+	ModuleTimeouts      string        // overrides, e.g. "firmenbuch=10s,analysis=20s"
+	SlowQueryThreshold  time.Duration // 0 disables slow-query logging
+	PoolMetricsInterval time.Duration // 0 disables periodic pool metrics logs
+}
+
+func loadDatabaseTuning() DatabaseTuning {
+	return DatabaseTuning{
+		StatementTimeout:    getEnvDuration("DB_STATEMENT_TIMEOUT", 30*time.Second),
+		ModuleTimeouts:      os.Getenv("DB_MODULE_TIMEOUTS"),
+		SlowQueryThreshold:  getEnvDuration("DB_SLOW_QUERY_THRESHOLD", 500*time.Millisecond),
+		PoolMetricsInterval: getEnvDuration("DB_POOL_METRICS_INTERVAL", time.Minute),
+	}
 }
 
 // LoadServerConfig loads configuration from environment variables
@@ -102,6 +142,13 @@ func LoadServerConfig() (*ServerConfig, error) {
 		JWTSecret:     os.Getenv("JWT_SECRET"),
 		EncryptionKey: os.Getenv("ENCRYPTION_KEY"),
 
+		// Redis degraded mode
+		RedisRequired:       getEnvBool("REDIS_REQUIRED", false),
+		RedisHealthInterval: getEnvDuration("REDIS_HEALTH_INTERVAL", 5*time.Second),
+
+		// Database timeouts and observability
+		DatabaseTuning: loadDatabaseTuning(),
+
 		// JWT timing
 		JWTAccessTokenExpiry:  getEnvDuration("JWT_ACCESS_TOKEN_EXPIRY", 15*time.Minute),
 		JWTRefreshTokenExpiry: getEnvDuration("JWT_REFRESH_TOKEN_EXPIRY", 7*24*time.Hour),
@@ -128,9 +175,13 @@ func LoadServerConfig() (*ServerConfig, error) {
 		AppName:        getEnv("APP_NAME", "Austrian Business Platform"),
 		AppURL:         getEnv("APP_URL", "http://localhost:8080"),
 		AllowedOrigins: getEnvList("ALLOWED_ORIGINS", []string{"http://localhost:3000", "http://localhost:5173", "http://localhost:8080"}),
+		CSPReportOnly:  getEnvBool("CSP_REPORT_ONLY", false),
+		CSPReportURI:   getEnv("CSP_REPORT_URI", "/api/v1/csp-report"),
 
 		// Features
 		EnableRegistration: getEnvBool("ENABLE_REGISTRATION", true),
+		DemoSeedingEnabled: getEnvBool("DEMO_SEEDING_ENABLED", false),
+		MaintenanceToken:   os.Getenv("MAINTENANCE_TOKEN"),
 
 		// AI Configuration
 		ClaudeAPIKey:      os.Getenv("CLAUDE_API_KEY"),
@@ -155,6 +206,7 @@ func LoadServerConfig() (*ServerConfig, error) {
 		StorageS3AccessKeyID:  os.Getenv("STORAGE_S3_ACCESS_KEY_ID"),
 		StorageS3SecretKey:    os.Getenv("STORAGE_S3_SECRET_KEY"),
 		StorageS3UseSSL:       getEnvBool("STORAGE_S3_USE_SSL", true),
+		StorageDefaultQuota:   getEnvInt64("STORAGE_DEFAULT_QUOTA_BYTES", 0),
 
 		// ELDA Configuration
 		ELDAEndpoint:           getEnv("ELDA_ENDPOINT", "https://elda.sozvers.at/elda-webservice/"),
@@ -164,7 +216,12 @@ func LoadServerConfig() (*ServerConfig, error) {
 		ELDARetryMax:           getEnvInt("ELDA_RETRY_MAX", 3),
 		ELDACertExpiryWarnDays: getEnvInt("ELDA_CERT_EXPIRY_WARN_DAYS", 30),
 		ELDATestMode:           getEnvBool("ELDA_TEST_MODE", false),
+
+		// Reference data
+		ReferenceDataFile: os.Getenv("REFERENCE_DATA_FILE"),
+		VATRatesFile:      os.Getenv("VAT_RATES_FILE"),
 	}
+	cfg.CSRFTrustedOrigins = getEnvList("CSRF_TRUSTED_ORIGINS", cfg.AllowedOrigins)
 
 	// Validate required fields
 	if err := cfg.Validate(); err != nil {
@@ -256,6 +313,20 @@ type StorageConfigResult struct {
 	S3AccessKeyID     string
 	S3SecretAccessKey string
 	S3UseSSL          bool
+
+	AzureAccountName string
+	AzureAccountKey  string
+	AzureContainer   string
+	AzureEndpoint    string
+
+	GCSBucket          string
+	GCSCredentialsFile string
+	GCSEndpoint        string
+
+	// Region names the data residency region of this backend
+	Region string
+	// Regions are the backends of further residency regions by name
+	Regions map[string]*StorageConfigResult
 }
 
 func (c *ServerConfig) StorageConfig() *StorageConfigResult {
@@ -370,7 +441,8 @@ func trim(s string) string {
 // WorkerConfig holds worker process configuration
 type WorkerConfig struct {
 	// Database
-	DatabaseURL string
+	DatabaseURL    string
+	DatabaseTuning DatabaseTuning
 
 	// Redis (optional for distributed locks)
 	RedisURL string
@@ -386,13 +458,28 @@ type WorkerConfig struct {
 
 	// Logging
 	LogLevel string
+
+	// Reference data (yearly statutory parameters)
+	ReferenceDataFile string
+
+	// Base URL of the web app, for links in notifications
+	AppURL string
+
+	// Key of the stored account credentials, the server's ENCRYPTION_KEY;
+	// jobs that log in to FinanzOnline are disabled without it
+	EncryptionKey string
+
+	// Storage quota per tenant without an override, as on the server;
+	// checked for documents the worker creates
+	StorageDefaultQuota int64
 }
 
 // LoadWorkerConfig loads worker configuration from environment variables
 func LoadWorkerConfig() (*WorkerConfig, error) {
 	cfg := &WorkerConfig{
 		// Required
-		DatabaseURL: os.Getenv("DATABASE_URL"),
+		DatabaseURL:    os.Getenv("DATABASE_URL"),
+		DatabaseTuning: loadDatabaseTuning(),
 
 		// Optional
 		RedisURL: os.Getenv("REDIS_URL"),
@@ -408,6 +495,15 @@ func LoadWorkerConfig() (*WorkerConfig, error) {
 
 		// Logging
 		LogLevel: getEnv("LOG_LEVEL", "info"),
+
+		// Reference data
+		ReferenceDataFile: os.Getenv("REFERENCE_DATA_FILE"),
+
+		AppURL: getEnv("APP_URL", "http://localhost:8080"),
+
+		EncryptionKey: os.Getenv("ENCRYPTION_KEY"),
+
+		StorageDefaultQuota: getEnvInt64("STORAGE_DEFAULT_QUOTA_BYTES", 0),
 	}
 
 	// Validate required fields
@@ -21,6 +21,10 @@ type SignatureConfig struct {
 	IDAustriaRedirectURL  string
 	IDAustriaScopes       []string
 
+	// IDAustriaMock serves a mock identity provider at IDAustriaIssuer and
+	// signs with a test CA instead of A-Trust. Only honoured with APP_ENV=dev.
+	IDAustriaMock bool
+
 	// Signature Settings
 	SignatureLinkExpiryDays    int
 	SignatureReminderDays      int
@@ -32,16 +36,21 @@ type SignatureConfig struct {
 	PDFMaxSizeBytes int64 // Maximum PDF size for signing (default 100MB)
 
 	// Callback URLs
-	SigningCallbackURL    string
-	PortalSigningBasePath string
+	SigningCallbackURL          string
+	PortalSigningBasePath       string
+	PortalSigningStatusBasePath string
 
 	// Cost tracking
 	SignatureCostCents int // Per-signature cost in cents for tracking
+
+	// Billing defaults for tenants without own settings
+	SignatureDefaultPostpaid     bool // Sign beyond prepaid credits, billed monthly
+	SignatureLowBalanceThreshold int  // Mail tenant admins at or below this many credits (0 disables)
 }
 
 // LoadSignatureConfig loads signature configuration from environment variables
 func LoadSignatureConfig() *SignatureConfig {
-	return &SignatureConfig{
+	cfg := &SignatureConfig{
 		// A-Trust API
 		ATrustAPIURL:   getEnv("ATRUST_API_URL", "https://api.a-trust.at/v1"),
 		ATrustAPIKey:   os.Getenv("ATRUST_API_KEY"),
@@ -67,12 +76,28 @@ func LoadSignatureConfig() *SignatureConfig {
 		PDFMaxSizeBytes: getEnvInt64("SIGNATURE_PDF_MAX_SIZE", 100*1024*1024),
 
 		// Callback URLs
-		SigningCallbackURL:    getEnv("SIGNING_CALLBACK_URL", "http://localhost:8080/api/v1/sign"),
-		PortalSigningBasePath: getEnv("PORTAL_SIGNING_BASE_PATH", "http://localhost:3001/sign"),
+		SigningCallbackURL:          getEnv("SIGNING_CALLBACK_URL", "http://localhost:8080/api/v1/sign"),
+		PortalSigningBasePath:       getEnv("PORTAL_SIGNING_BASE_PATH", "http://localhost:3001/sign"),
+		PortalSigningStatusBasePath: getEnv("PORTAL_SIGNING_STATUS_BASE_PATH", "http://localhost:3001/sign/status"),
 
 		// Cost tracking (example: 30 cents per signature)
 		SignatureCostCents: getEnvInt("SIGNATURE_COST_CENTS", 30),
+
+		// Billing defaults (postpaid, so tenants without packages keep signing)
+		SignatureDefaultPostpaid:     getEnvBool("SIGNATURE_DEFAULT_POSTPAID", true),
+		SignatureLowBalanceThreshold: getEnvInt("SIGNATURE_LOW_BALANCE_THRESHOLD", 10),
 	}
+
+	// Local development without a registered ID Austria client
+	appEnv := os.Getenv("APP_ENV")
+	if getEnvBool("IDAUSTRIA_MOCK", false) && (appEnv == "dev" || appEnv == "development") {
+		cfg.IDAustriaMock = true
+		cfg.IDAustriaIssuer = getEnv("IDAUSTRIA_ISSUER", "http://localhost:8080/dev/idaustria")
+		cfg.IDAustriaClientID = getEnv("IDAUSTRIA_CLIENT_ID", "dev-client")
+		cfg.IDAustriaClientSecret = getEnv("IDAUSTRIA_CLIENT_SECRET", "dev-secret")
+	}
+
+	return cfg
 }
 
 // IsATrustConfigured returns true if A-Trust API is configured
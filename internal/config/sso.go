@@ -0,0 +1,17 @@
+package config
+
+// SSOConfig configures the single sign-on of tenants with their own
+// identity providers, which are set up per tenant
+type SSOConfig struct {
+	// RedirectBaseURL is the public base URL of the API the identity
+	// providers redirect back to, /api/v1/auth/sso/callback
+	RedirectBaseURL string
+}
+
+// LoadSSOConfig loads single sign-on configuration from environment
+// variables
+func LoadSSOConfig() *SSOConfig {
+	return &SSOConfig{
+		RedirectBaseURL: getEnv("SSO_REDIRECT_BASE_URL", getEnv("APP_URL", "http://localhost:8080")),
+	}
+}
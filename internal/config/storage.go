@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// LoadStorageConfig loads the document storage configuration from the same
+// environment variables as the server, for processes such as the worker that
+// read and write stored documents. Every region listed in STORAGE_REGIONS is
+// configured with the same variables prefixed STORAGE_REGION_<NAME>_, e.g.
+// STORAGE_REGION_CH_TYPE.
+func LoadStorageConfig() *StorageConfigResult {
+	cfg := loadStorageBackend("STORAGE_", "./data/documents")
+	cfg.Region = getEnv("STORAGE_REGION", "default")
+	for _, name := range getEnvList("STORAGE_REGIONS", nil) {
+		name = strings.ToLower(name)
+		if name == cfg.Region {
+			continue
+		}
+		if cfg.Regions == nil {
+			cfg.Regions = make(map[string]*StorageConfigResult)
+		}
+		prefix := "STORAGE_REGION_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		region := loadStorageBackend(prefix, "./data/documents-"+name)
+		region.Region = name
+		cfg.Regions[name] = region
+	}
+	return cfg
+}
+
+// loadStorageBackend reads the settings of one storage backend
+func loadStorageBackend(prefix, localPath string) *StorageConfigResult {
+	return &StorageConfigResult{
+		Type:              getEnv(prefix+"TYPE", "local"),
+		LocalPath:         getEnv(prefix+"LOCAL_PATH", localPath),
+		S3Endpoint:        os.Getenv(prefix + "S3_ENDPOINT"),
+		S3Bucket:          getEnv(prefix+"S3_BUCKET", "documents"),
+		S3Region:          getEnv(prefix+"S3_REGION", "us-east-1"),
+		S3AccessKeyID:     os.Getenv(prefix + "S3_ACCESS_KEY_ID"),
+		S3SecretAccessKey: os.Getenv(prefix + "S3_SECRET_KEY"),
+		S3UseSSL:          getEnvBool(prefix+"S3_USE_SSL", true),
+
+		AzureAccountName: os.Getenv(prefix + "AZURE_ACCOUNT_NAME"),
+		AzureAccountKey:  os.Getenv(prefix + "AZURE_ACCOUNT_KEY"),
+		AzureContainer:   getEnv(prefix+"AZURE_CONTAINER", "documents"),
+		AzureEndpoint:    os.Getenv(prefix + "AZURE_ENDPOINT"),
+
+		GCSBucket:          getEnv(prefix+"GCS_BUCKET", "documents"),
+		GCSCredentialsFile: os.Getenv(prefix + "GCS_CREDENTIALS_FILE"),
+		GCSEndpoint:        os.Getenv(prefix + "GCS_ENDPOINT"),
+	}
+}
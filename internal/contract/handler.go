@@ -0,0 +1,200 @@
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// Handler handles contract register HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new contract handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers contract routes
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/contracts", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("POST /api/v1/contracts/register", requireAuth(http.HandlerFunc(h.Register)))
+	router.Handle("GET /api/v1/contracts/{id}", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("PUT /api/v1/contracts/{id}", requireAuth(http.HandlerFunc(h.Update)))
+	router.Handle("POST /api/v1/contracts/{id}/cancel", requireAuth(http.HandlerFunc(h.Cancel)))
+}
+
+// RegisterRequest names the contract document to register
+type RegisterRequest struct {
+	DocumentID uuid.UUID `json:"document_id"`
+}
+
+// CancelRequest is the optional body of a cancel request
+type CancelRequest struct {
+	Note string `json:"note"`
+}
+
+// List handles GET /api/v1/contracts
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	filter := ListFilter{TenantID: tenantID, Limit: 50}
+	query := r.URL.Query()
+	if status := query.Get("status"); status != "" {
+		if status != StatusActive && status != StatusCancelled && status != StatusExpired {
+			api.BadRequest(w, "status must be active, cancelled or expired")
+			return
+		}
+		filter.Status = status
+	}
+	if dueStr := query.Get("due_within"); dueStr != "" {
+		days, err := strconv.Atoi(dueStr)
+		if err != nil || days < 0 || days > 3650 {
+			api.BadRequest(w, "due_within must be a number of days")
+			return
+		}
+		filter.DueWithin = &days
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	overview, err := h.service.Overview(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, overview)
+}
+
+// Register handles POST /api/v1/contracts/register
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var req RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.DocumentID == uuid.Nil {
+		api.BadRequest(w, "document_id is required")
+		return
+	}
+
+	contract, err := h.service.Register(r.Context(), tenantID, req.DocumentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, contract)
+}
+
+// Get handles GET /api/v1/contracts/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	contract, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, contract)
+}
+
+// Update handles PUT /api/v1/contracts/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var input UpdateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		if errors.Is(err, ErrInvalidPeriod) {
+			api.BadRequest(w, "periods must be ISO 8601 durations such as P30D, P4W, P3M or P1Y")
+			return
+		}
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	contract, err := h.service.Update(r.Context(), tenantID, id, input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, contract)
+}
+
+// Cancel handles POST /api/v1/contracts/{id}/cancel
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req CancelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	contract, err := h.service.Cancel(r.Context(), tenantID, id, api.OptionalUser(r), req.Note)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, contract)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrContractNotFound):
+		api.NotFound(w, "contract not found")
+	case errors.Is(err, ErrDocumentNotFound):
+		api.NotFound(w, "document not found")
+	case errors.Is(err, ErrContractClosed):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, ErrInvalidDate), errors.Is(err, ErrInvalidTerms):
+		api.BadRequest(w, err.Error())
+	case IsNotApplicable(err):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	default:
+		api.InternalError(w)
+	}
+}
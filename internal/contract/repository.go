@@ -0,0 +1,240 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/internal/document"
+)
+
+// ErrDocumentNotFound is returned when a document does not exist for the tenant
+var ErrDocumentNotFound = errors.New("document not found")
+
+// Repository handles contract database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new contract repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// DocumentTitle returns the title of a document of a tenant the viewer of
+// ctx may see
+func (r *Repository) DocumentTitle(ctx context.Context, tenantID, documentID uuid.UUID) (string, error) {
+	var title string
+	access, args := document.AccessCondition(ctx, "d", []interface{}{documentID, tenantID})
+	err := r.db.QueryRow(ctx, `SELECT d.title FROM documents d WHERE d.id = $1 AND d.tenant_id = $2`+access,
+		args...).Scan(&title)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrDocumentNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load document: %w", err)
+	}
+	return title, nil
+}
+
+const contractColumns = `id, tenant_id, document_id, analysis_id, title, COALESCE(counterparty, ''),
+	start_date, end_date, COALESCE(initial_term, ''), auto_renewal, COALESCE(renewal_term, ''),
+	COALESCE(notice_period, ''), COALESCE(source_text, ''), term_end, cancel_by, source, status,
+	deadline_id, cancelled_by, cancelled_at, COALESCE(note, ''), created_at, updated_at`
+
+func scanContract(row pgx.Row) (*Contract, error) {
+	var c Contract
+	var initial, renewal, notice string
+	err := row.Scan(&c.ID, &c.TenantID, &c.DocumentID, &c.AnalysisID, &c.Title, &c.Counterparty,
+		&c.StartDate, &c.EndDate, &initial, &c.AutoRenewal, &renewal,
+		&notice, &c.SourceText, &c.TermEnd, &c.CancelBy, &c.Source, &c.Status,
+		&c.DeadlineID, &c.CancelledBy, &c.CancelledAt, &c.Note, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	// Periods are validated on the way in; a malformed one reads as unset
+	c.InitialTerm, _ = ParsePeriod(initial)
+	c.RenewalTerm, _ = ParsePeriod(renewal)
+	c.NoticePeriod, _ = ParsePeriod(notice)
+	return &c, nil
+}
+
+func collectContracts(rows pgx.Rows) ([]*Contract, error) {
+	defer rows.Close()
+	var contracts []*Contract
+	for rows.Next() {
+		c, err := scanContract(rows)
+		if err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, c)
+	}
+	return contracts, rows.Err()
+}
+
+// Save stores a contract, replacing the contract registered for the same
+// document
+func (r *Repository) Save(ctx context.Context, c *Contract) error {
+	saved, err := scanContract(r.db.QueryRow(ctx, `
+		INSERT INTO contracts (
+			tenant_id, document_id, analysis_id, title, counterparty, start_date, end_date,
+			initial_term, auto_renewal, renewal_term, notice_period, source_text,
+			term_end, cancel_by, source, status, deadline_id
+		) VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, NULLIF($8, ''), $9, NULLIF($10, ''),
+			NULLIF($11, ''), NULLIF($12, ''), $13, $14, $15, $16, $17)
+		ON CONFLICT (document_id) DO UPDATE SET
+			analysis_id = EXCLUDED.analysis_id,
+			title = EXCLUDED.title,
+			counterparty = EXCLUDED.counterparty,
+			start_date = EXCLUDED.start_date,
+			end_date = EXCLUDED.end_date,
+			initial_term = EXCLUDED.initial_term,
+			auto_renewal = EXCLUDED.auto_renewal,
+			renewal_term = EXCLUDED.renewal_term,
+			notice_period = EXCLUDED.notice_period,
+			source_text = EXCLUDED.source_text,
+			term_end = EXCLUDED.term_end,
+			cancel_by = EXCLUDED.cancel_by,
+			source = EXCLUDED.source,
+			status = EXCLUDED.status,
+			deadline_id = EXCLUDED.deadline_id,
+			updated_at = NOW()
+		RETURNING `+contractColumns,
+		c.TenantID, c.DocumentID, c.AnalysisID, c.Title, c.Counterparty, c.StartDate, c.EndDate,
+		c.InitialTerm.String(), c.AutoRenewal, c.RenewalTerm.String(), c.NoticePeriod.String(), c.SourceText,
+		c.TermEnd, c.CancelBy, c.Source, c.Status, c.DeadlineID))
+	if err != nil {
+		return fmt.Errorf("failed to save contract: %w", err)
+	}
+	*c = *saved
+	return nil
+}
+
+// Get returns a contract of a tenant
+func (r *Repository) Get(ctx context.Context, tenantID, id uuid.UUID) (*Contract, error) {
+	access, args := document.RowAccessCondition(ctx, "contracts", []interface{}{id, tenantID})
+	c, err := scanContract(r.db.QueryRow(ctx,
+		`SELECT `+contractColumns+` FROM contracts WHERE id = $1 AND tenant_id = $2`+access, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrContractNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contract: %w", err)
+	}
+	return c, nil
+}
+
+// GetByDocument returns the contract registered for a document
+func (r *Repository) GetByDocument(ctx context.Context, tenantID, documentID uuid.UUID) (*Contract, error) {
+	access, args := document.RowAccessCondition(ctx, "contracts", []interface{}{documentID, tenantID})
+	c, err := scanContract(r.db.QueryRow(ctx,
+		`SELECT `+contractColumns+` FROM contracts WHERE document_id = $1 AND tenant_id = $2`+access, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrContractNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contract: %w", err)
+	}
+	return c, nil
+}
+
+// List returns the contracts of a tenant, the next to cancel first
+func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Contract, int, error) {
+	where := "tenant_id = $1"
+	args := []interface{}{filter.TenantID}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.DueWithin != nil {
+		args = append(args, filter.Today, *filter.DueWithin)
+		where += fmt.Sprintf(" AND cancel_by <= $%d::date + $%d::int", len(args)-1, len(args))
+	}
+	access, args := document.RowAccessCondition(ctx, "contracts", args)
+	where += access
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM contracts WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count contracts: %w", err)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT %s FROM contracts
+		WHERE %s
+		ORDER BY cancel_by ASC NULLS LAST, term_end ASC NULLS LAST, created_at DESC, id
+		LIMIT $%d OFFSET $%d`, contractColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list contracts: %w", err)
+	}
+	contracts, err := collectContracts(rows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list contracts: %w", err)
+	}
+	return contracts, total, nil
+}
+
+// Summary counts the contracts of a tenant on today
+func (r *Repository) Summary(ctx context.Context, tenantID uuid.UUID, today time.Time) (*Summary, error) {
+	var s Summary
+	access, args := document.RowAccessCondition(ctx, "contracts", []interface{}{tenantID, today, DueSoonDays})
+	err := r.db.QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'active'),
+			COUNT(*) FILTER (WHERE status = 'cancelled'),
+			COUNT(*) FILTER (WHERE status = 'expired'),
+			COUNT(*) FILTER (WHERE status = 'active' AND auto_renewal),
+			COUNT(*) FILTER (WHERE status = 'active' AND cancel_by >= $2::date AND cancel_by <= $2::date + $3::int),
+			MIN(cancel_by) FILTER (WHERE status = 'active' AND cancel_by >= $2::date)
+		FROM contracts
+		WHERE tenant_id = $1`+access, args...,
+	).Scan(&s.Active, &s.Cancelled, &s.Expired, &s.AutoRenewal, &s.DueSoon, &s.NextCancel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize contracts: %w", err)
+	}
+	return &s, nil
+}
+
+// Due returns the active contracts of all tenants that have to be rolled
+// forward or expired: the last day to cancel or the end of the term is
+// before day
+func (r *Repository) Due(ctx context.Context, day time.Time) ([]*Contract, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+contractColumns+` FROM contracts
+		WHERE status = 'active'
+			AND (cancel_by < $1::date OR (cancel_by IS NULL AND term_end < $1::date))
+		ORDER BY tenant_id, id`, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due contracts: %w", err)
+	}
+	contracts, err := collectContracts(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due contracts: %w", err)
+	}
+	return contracts, nil
+}
+
+// Cancel records that notice was given on an active contract
+func (r *Repository) Cancel(ctx context.Context, tenantID, id uuid.UUID, cancelledBy *uuid.UUID, note string) (*Contract, error) {
+	access, args := document.RowAccessCondition(ctx, "contracts", []interface{}{id, tenantID, cancelledBy, note})
+	c, err := scanContract(r.db.QueryRow(ctx, `
+		UPDATE contracts
+		SET status = 'cancelled', cancelled_by = $3, cancelled_at = NOW(), note = NULLIF($4, ''), updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status = 'active'`+access+`
+		RETURNING `+contractColumns, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := r.Get(ctx, tenantID, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrContractClosed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel contract: %w", err)
+	}
+	return c, nil
+}
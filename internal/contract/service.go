@@ -0,0 +1,297 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/analysis"
+	"austrian-business-infrastructure/internal/timezone"
+)
+
+// Service maintains the contract register and the cancellation deadlines of
+// the contracts
+type Service struct {
+	repo      *Repository
+	analysis  *analysis.Repository
+	timezones timezone.Source
+	now       func() time.Time
+}
+
+// NewService creates a new contract service
+func NewService(repo *Repository, analysisRepo *analysis.Repository) *Service {
+	return &Service{repo: repo, analysis: analysisRepo, now: time.Now}
+}
+
+// SetTimezones sets the source of the tenants' time zones the last day to
+// cancel is counted in; Europe/Vienna if not set
+func (s *Service) SetTimezones(src timezone.Source) {
+	s.timezones = src
+}
+
+// today returns the calendar day of a tenant
+func (s *Service) today(ctx context.Context, tenantID uuid.UUID) time.Time {
+	return timezone.Today(s.now(), timezone.Of(ctx, s.timezones, tenantID))
+}
+
+// Register reads the terms of an analyzed contract document into the
+// register. Registering a document again updates the terms, unless a user
+// has corrected them or the contract is no longer active.
+func (s *Service) Register(ctx context.Context, tenantID, documentID uuid.UUID) (*Contract, error) {
+	title, err := s.repo.DocumentTitle(ctx, tenantID, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetByDocument(ctx, tenantID, documentID)
+	if err != nil && !errors.Is(err, ErrContractNotFound) {
+		return nil, err
+	}
+	if existing != nil && (existing.Source == SourceManual || existing.Status != StatusActive) {
+		return existing, nil
+	}
+
+	result, err := s.analysis.GetAnalysisByDocumentID(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, analysis.ErrAnalysisNotFound) {
+			return nil, ErrNoAnalysis
+		}
+		return nil, err
+	}
+	terms := ParseTerms(result.ExtractedText)
+	if terms.Empty() {
+		return nil, ErrNoTerms
+	}
+
+	c := existing
+	if c == nil {
+		c = &Contract{TenantID: tenantID, DocumentID: documentID, Title: title, Status: StatusActive}
+	}
+	previous := c.CancelBy
+	c.AnalysisID = &result.ID
+	c.Terms = terms
+	c.Source = SourceExtracted
+	if err := s.apply(ctx, c, previous); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Overview returns the contracts of a tenant with the summary of the
+// register
+func (s *Service) Overview(ctx context.Context, filter ListFilter) (*Overview, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	filter.Today = s.today(ctx, filter.TenantID)
+
+	summary, err := s.repo.Summary(ctx, filter.TenantID, filter.Today)
+	if err != nil {
+		return nil, err
+	}
+	contracts, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if contracts == nil {
+		contracts = []*Contract{}
+	}
+	return &Overview{Summary: *summary, Items: contracts, Total: total, Limit: filter.Limit, Offset: filter.Offset}, nil
+}
+
+// Get returns a contract of a tenant
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Contract, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// Update corrects the terms of an active contract and recomputes its
+// deadline
+func (s *Service) Update(ctx context.Context, tenantID, id uuid.UUID, input UpdateInput) (*Contract, error) {
+	c, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status != StatusActive {
+		return nil, ErrContractClosed
+	}
+	previous := c.CancelBy
+
+	if input.Title != nil {
+		if title := strings.TrimSpace(*input.Title); title != "" {
+			c.Title = title
+		}
+	}
+	if input.Counterparty != nil {
+		c.Counterparty = strings.TrimSpace(*input.Counterparty)
+	}
+	if input.StartDate != nil {
+		if c.StartDate, err = parseInputDate(*input.StartDate); err != nil {
+			return nil, err
+		}
+	}
+	if input.EndDate != nil {
+		if c.EndDate, err = parseInputDate(*input.EndDate); err != nil {
+			return nil, err
+		}
+	}
+	if input.InitialTerm != nil {
+		c.InitialTerm = *input.InitialTerm
+	}
+	if input.AutoRenewal != nil {
+		c.AutoRenewal = *input.AutoRenewal
+	}
+	if input.RenewalTerm != nil {
+		c.RenewalTerm = *input.RenewalTerm
+	}
+	if input.NoticePeriod != nil {
+		c.NoticePeriod = *input.NoticePeriod
+	}
+	if c.EndDate == nil && (c.StartDate == nil || c.InitialTerm.IsZero()) {
+		return nil, ErrInvalidTerms
+	}
+	c.Source = SourceManual
+
+	if err := s.apply(ctx, c, previous); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Cancel records that notice was given on a contract; its cancellation
+// deadline is done
+func (s *Service) Cancel(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID, note string) (*Contract, error) {
+	c, err := s.repo.Cancel(ctx, tenantID, id, userID, note)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.closeDeadline(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Renew rolls the contracts whose last day to cancel has passed into their
+// next term and expires contracts whose term ended, across all tenants. A
+// renewed contract gets the cancellation deadline of its new term.
+func (s *Service) Renew(ctx context.Context) (*RenewalResult, error) {
+	// Tenants east of UTC may already be a day ahead
+	due, err := s.repo.Due(ctx, timezone.Today(s.now(), time.UTC).AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RenewalResult{}
+	var errs []error
+	for _, c := range due {
+		previous := c.CancelBy
+		termEnd, _, status := c.Schedule(s.today(ctx, c.TenantID))
+		if status == c.Status && sameDay(termEnd, c.TermEnd) {
+			continue
+		}
+		if err := s.apply(ctx, c, previous); err != nil {
+			errs = append(errs, fmt.Errorf("contract %s: %w", c.ID, err))
+			continue
+		}
+		if c.Status == StatusExpired {
+			result.Expired++
+		} else {
+			result.Renewed++
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// apply computes the current term of an active contract, keeps its
+// cancellation deadline in step and stores it. previous is the last day to
+// cancel the deadline was created for.
+func (s *Service) apply(ctx context.Context, c *Contract, previous *time.Time) error {
+	if c.Status == StatusActive {
+		c.TermEnd, c.CancelBy, c.Status = c.Schedule(s.today(ctx, c.TenantID))
+	}
+
+	switch {
+	case c.Status != StatusActive || c.CancelBy == nil:
+		if err := s.closeDeadline(ctx, c); err != nil {
+			return err
+		}
+		c.DeadlineID = nil
+	case c.DeadlineID == nil || !sameDay(previous, c.CancelBy):
+		if err := s.closeDeadline(ctx, c); err != nil {
+			return err
+		}
+		if err := s.createDeadline(ctx, c); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.Save(ctx, c)
+}
+
+// createDeadline adds the last day to cancel to the contract document's
+// deadlines, where the deadline reminders pick it up
+func (s *Service) createDeadline(ctx context.Context, c *Contract) error {
+	c.DeadlineID = nil
+	if c.AnalysisID == nil {
+		// Deadlines belong to an analysis; a contract without one has none
+		return nil
+	}
+	confidence := 0.8
+	if c.Source == SourceManual {
+		confidence = 1
+	}
+	d := &analysis.Deadline{
+		AnalysisID:   *c.AnalysisID,
+		DocumentID:   c.DocumentID,
+		TenantID:     c.TenantID,
+		DeadlineType: analysis.DeadlineTypeCancellation,
+		Date:         *c.CancelBy,
+		Description:  fmt.Sprintf("Letzter Tag zur Kündigung von %s zum %s", c.Title, c.TermEnd.Format("02.01.2006")),
+		SourceText:   c.SourceText,
+		Confidence:   confidence,
+		IsHard:       true,
+	}
+	if err := s.analysis.CreateDeadline(ctx, d); err != nil {
+		return fmt.Errorf("failed to create cancellation deadline: %w", err)
+	}
+	c.DeadlineID = &d.ID
+	return nil
+}
+
+// closeDeadline acknowledges the cancellation deadline of a contract, so
+// it is no longer reminded of
+func (s *Service) closeDeadline(ctx context.Context, c *Contract) error {
+	if c.DeadlineID == nil {
+		return nil
+	}
+	err := s.analysis.AcknowledgeDeadline(ctx, *c.DeadlineID)
+	if err != nil && !errors.Is(err, analysis.ErrDeadlineNotFound) {
+		return fmt.Errorf("failed to close cancellation deadline: %w", err)
+	}
+	return nil
+}
+
+// parseInputDate parses a YYYY-MM-DD date; the empty string clears it
+func parseInputDate(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	day, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+	return &day, nil
+}
+
+func sameDay(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,259 @@
+package contract
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Units of a period
+const (
+	UnitDays   = "D"
+	UnitWeeks  = "W"
+	UnitMonths = "M"
+	UnitYears  = "Y"
+)
+
+// Period is a contract term or notice period. It is written as an ISO 8601
+// duration of a single unit, e.g. "P3M" for three months.
+type Period struct {
+	Count int
+	Unit  string
+}
+
+var periodPattern = regexp.MustCompile(`^P(\d{1,3})([DWMY])$`)
+
+// ParsePeriod parses a period such as "P30D", "P4W", "P3M" or "P1Y". The
+// empty string is the zero period.
+func ParsePeriod(s string) (Period, error) {
+	if s == "" {
+		return Period{}, nil
+	}
+	m := periodPattern.FindStringSubmatch(s)
+	if m == nil {
+		return Period{}, ErrInvalidPeriod
+	}
+	count, _ := strconv.Atoi(m[1])
+	if count == 0 {
+		return Period{}, ErrInvalidPeriod
+	}
+	return Period{Count: count, Unit: m[2]}, nil
+}
+
+// IsZero reports whether the period is not set
+func (p Period) IsZero() bool {
+	return p.Count == 0
+}
+
+func (p Period) String() string {
+	if p.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("P%d%s", p.Count, p.Unit)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (p Period) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (p *Period) UnmarshalText(text []byte) error {
+	parsed, err := ParsePeriod(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// add moves day by n times the period. Months are added calendar-wise; a
+// day beyond the end of the target month becomes its last day.
+func (p Period) add(day time.Time, n int) time.Time {
+	switch p.Unit {
+	case UnitDays:
+		return day.AddDate(0, 0, n*p.Count)
+	case UnitWeeks:
+		return day.AddDate(0, 0, 7*n*p.Count)
+	case UnitMonths:
+		return addMonths(day, n*p.Count)
+	case UnitYears:
+		return addMonths(day, 12*n*p.Count)
+	}
+	return day
+}
+
+func addMonths(day time.Time, months int) time.Time {
+	first := time.Date(day.Year(), day.Month()+time.Month(months), 1, 0, 0, 0, 0, day.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	d := day.Day()
+	if d > last {
+		d = last
+	}
+	return time.Date(first.Year(), first.Month(), d, 0, 0, 0, 0, day.Location())
+}
+
+// TermEnd returns the last day of a term of length p starting on start
+func TermEnd(start time.Time, p Period) time.Time {
+	return p.add(start, 1).AddDate(0, 0, -1)
+}
+
+// CancelBy returns the last day notice can be given to end a contract on
+// termEnd: the notice period counted back from the end of the term. Without
+// a notice period it is the last day of the term.
+func CancelBy(termEnd time.Time, notice Period) time.Time {
+	return notice.add(termEnd.AddDate(0, 0, 1), -1).AddDate(0, 0, -1)
+}
+
+// renewal is the period a contract renews by. A renewal clause without a
+// period renews by the initial term, or by a year if that is unknown too.
+func (t Terms) renewal() Period {
+	switch {
+	case !t.RenewalTerm.IsZero():
+		return t.RenewalTerm
+	case !t.InitialTerm.IsZero():
+		return t.InitialTerm
+	default:
+		return Period{Count: 1, Unit: UnitYears}
+	}
+}
+
+// maxRenewals bounds rolling a contract forward to today
+const maxRenewals = 1000
+
+// Schedule computes the state of a contract on today, a calendar day as
+// midnight UTC. A contract that renews is rolled forward to the first term
+// whose last day to cancel is not yet past; cancelBy is that day. A contract
+// that does not renew simply ends: it has no cancelBy and has expired once
+// its term is over. Without an end of the first term both are nil.
+func (t Terms) Schedule(today time.Time) (termEnd, cancelBy *time.Time, status string) {
+	var end time.Time
+	switch {
+	case t.EndDate != nil:
+		end = *t.EndDate
+	case t.StartDate != nil && !t.InitialTerm.IsZero():
+		end = TermEnd(*t.StartDate, t.InitialTerm)
+	default:
+		return nil, nil, StatusActive
+	}
+
+	if !t.AutoRenewal {
+		if end.Before(today) {
+			return &end, nil, StatusExpired
+		}
+		return &end, nil, StatusActive
+	}
+
+	renewal := t.renewal()
+	last := CancelBy(end, t.NoticePeriod)
+	for i := 0; last.Before(today) && i < maxRenewals; i++ {
+		end = TermEnd(end.AddDate(0, 0, 1), renewal)
+		last = CancelBy(end, t.NoticePeriod)
+	}
+	return &end, &last, StatusActive
+}
+
+// Clauses are matched on the text with whitespace collapsed. Numbers may be
+// written as words; units are matched by their stem.
+const (
+	numberPattern = `(\d{1,3}|einem|einen|einer|eine|ein|zwei|drei|vier|fünf|sechs|sieben|acht|neun|zehn|elf|zwölf)`
+	unitPattern   = `(tag|woche|monat|jahr)\S*`
+	datePattern   = `(\d{1,2})\.\s?(\d{1,2})\.\s?(\d{4})`
+)
+
+var (
+	startPattern = regexp.MustCompile(`(?i)(?:vertragsbeginn|beginnt|beginn|in kraft|wirksam)\D{0,40}?` + datePattern)
+	endPattern   = regexp.MustCompile(`(?i)(?:vertragsende|endet|befristet bis|läuft bis)\D{0,40}?` + datePattern)
+	termPattern  = regexp.MustCompile(`(?i)(?:mindestlaufzeit|laufzeit|vertragsdauer)\D{0,40}?\b` + numberPattern + `\s*` + unitPattern)
+	// "zwölfmonatige Laufzeit", "3-monatigen Kündigungsfrist"
+	termAdjectivePattern   = regexp.MustCompile(`(?i)\b` + numberPattern + `[- ]?(tägig|wöchig|monatig|jährig)\S*\s+(?:mindest)?(?:laufzeit|vertragsdauer)`)
+	renewalPattern         = regexp.MustCompile(`(?i)verlängert\s+sich|automatische\s+verlängerung|stillschweigend\s+verlängert|automatisch\s+verlängert`)
+	renewalTermPattern     = regexp.MustCompile(`(?i)verlänger\D{0,60}?\bum\s+(?:jeweils\s+|weitere\s+)*` + numberPattern + `\s+(?:weitere[snm]?\s+)?` + unitPattern)
+	noticePattern          = regexp.MustCompile(`(?i)kündigungsfrist\D{0,30}?\b` + numberPattern + `\s*` + unitPattern)
+	noticeAdjectivePattern = regexp.MustCompile(`(?i)\b` + numberPattern + `[- ]?(tägig|wöchig|monatig|jährig)\S*\s+kündigungsfrist`)
+	noticeBeforePattern    = regexp.MustCompile(`(?i)\b` + numberPattern + `\s*` + unitPattern + `\s+(?:vor|zum)\s+(?:dem\s+)?(?:ablauf|ende)`)
+)
+
+var numberWords = map[string]int{
+	"ein": 1, "eine": 1, "einen": 1, "einem": 1, "einer": 1,
+	"zwei": 2, "drei": 3, "vier": 4, "fünf": 5, "sechs": 6,
+	"sieben": 7, "acht": 8, "neun": 9, "zehn": 10, "elf": 11, "zwölf": 12,
+}
+
+// ParseTerms reads the duration clauses of a German contract: start and
+// end date, the term ("Laufzeit von 12 Monaten"), automatic renewal
+// ("verlängert sich jeweils um ein weiteres Jahr") and the notice period
+// ("Kündigungsfrist von drei Monaten", "dreimonatigen Kündigungsfrist").
+// Clauses not found are left empty.
+func ParseTerms(text string) Terms {
+	text = strings.Join(strings.Fields(text), " ")
+
+	var t Terms
+	if m := startPattern.FindStringSubmatch(text); m != nil {
+		t.StartDate = parseDate(m[1], m[2], m[3])
+	}
+	if m := endPattern.FindStringSubmatch(text); m != nil {
+		t.EndDate = parseDate(m[1], m[2], m[3])
+	}
+	if m := termPattern.FindStringSubmatch(text); m != nil {
+		t.InitialTerm = period(m[1], m[2])
+	} else if m := termAdjectivePattern.FindStringSubmatch(text); m != nil {
+		t.InitialTerm = period(m[1], m[2])
+	}
+
+	if renewalPattern.MatchString(text) {
+		t.AutoRenewal = true
+		if m := renewalTermPattern.FindStringSubmatch(text); m != nil {
+			t.RenewalTerm = period(m[1], m[2])
+		}
+	}
+
+	for _, pattern := range []*regexp.Regexp{noticePattern, noticeAdjectivePattern, noticeBeforePattern} {
+		if m := pattern.FindStringSubmatch(text); m != nil {
+			if p := period(m[1], m[2]); !p.IsZero() {
+				t.NoticePeriod = p
+				t.SourceText = m[0]
+				break
+			}
+		}
+	}
+	return t
+}
+
+// period builds a period from a number (digits or a word) and a unit stem
+func period(number, unit string) Period {
+	number = strings.ToLower(number)
+	count, ok := numberWords[number]
+	if !ok {
+		count, _ = strconv.Atoi(number)
+	}
+	if count <= 0 {
+		return Period{}
+	}
+	unit = strings.ToLower(unit)
+	switch {
+	case strings.HasPrefix(unit, "ta"), strings.HasPrefix(unit, "tä"):
+		return Period{Count: count, Unit: UnitDays}
+	case strings.HasPrefix(unit, "wo"), strings.HasPrefix(unit, "wö"):
+		return Period{Count: count, Unit: UnitWeeks}
+	case strings.HasPrefix(unit, "mo"):
+		return Period{Count: count, Unit: UnitMonths}
+	case strings.HasPrefix(unit, "ja"), strings.HasPrefix(unit, "jä"):
+		return Period{Count: count, Unit: UnitYears}
+	}
+	return Period{}
+}
+
+// parseDate builds a calendar day as midnight UTC, nil if it does not exist
+func parseDate(day, month, year string) *time.Time {
+	d, _ := strconv.Atoi(day)
+	m, _ := strconv.Atoi(month)
+	y, _ := strconv.Atoi(year)
+	date := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	if date.Day() != d || int(date.Month()) != m {
+		return nil
+	}
+	return &date
+}
@@ -0,0 +1,139 @@
+// Package contract keeps a register of the contracts of a tenant. Term,
+// automatic renewal and notice period are read from the text of documents
+// classified as contracts; from them the end of the current term and the
+// last day to cancel before the contract renews are computed. The last day
+// to cancel is kept as a deadline of the document, so it is reminded of
+// like any other deadline.
+package contract
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrContractNotFound = errors.New("contract not found")
+	ErrContractClosed   = errors.New("contract has already been cancelled or has expired")
+	ErrInvalidPeriod    = errors.New("invalid period")
+	ErrInvalidDate      = errors.New("invalid date, expected YYYY-MM-DD")
+	ErrInvalidTerms     = errors.New("contract needs a start date with a term, or an end date")
+
+	// The document cannot be registered; the worker skips these silently
+	ErrNoAnalysis = errors.New("document has not been analyzed")
+	ErrNoTerms    = errors.New("no contract term or notice period found in the document")
+)
+
+// IsNotApplicable reports whether err means the document is not a contract
+// whose terms could be read
+func IsNotApplicable(err error) bool {
+	return errors.Is(err, ErrNoAnalysis) || errors.Is(err, ErrNoTerms)
+}
+
+// Contract states
+const (
+	StatusActive    = "active"
+	StatusCancelled = "cancelled" // Notice was given
+	StatusExpired   = "expired"   // The term ended without renewal
+)
+
+// Sources of the contract terms
+const (
+	SourceExtracted = "extracted" // Read from the document text
+	SourceManual    = "manual"    // Entered or corrected by a user
+)
+
+// DueSoonDays is how far ahead the overview counts cancellation deadlines
+// as due soon
+const DueSoonDays = 90
+
+// Terms are the duration clauses of a contract
+type Terms struct {
+	StartDate    *time.Time `json:"start_date,omitempty"`
+	EndDate      *time.Time `json:"end_date,omitempty"`    // Of the initial term, if stated
+	InitialTerm  Period     `json:"initial_term"`          // From the start date
+	AutoRenewal  bool       `json:"auto_renewal"`          // Renews unless cancelled
+	RenewalTerm  Period     `json:"renewal_term"`          // Of each renewal
+	NoticePeriod Period     `json:"notice_period"`         // Before the end of a term
+	SourceText   string     `json:"source_text,omitempty"` // The notice clause
+}
+
+// Empty reports whether no clause was found
+func (t Terms) Empty() bool {
+	return t.EndDate == nil && t.InitialTerm.IsZero() && !t.AutoRenewal && t.NoticePeriod.IsZero()
+}
+
+// Contract is a contract in the register
+type Contract struct {
+	ID           uuid.UUID  `json:"id"`
+	TenantID     uuid.UUID  `json:"tenant_id"`
+	DocumentID   uuid.UUID  `json:"document_id"`
+	AnalysisID   *uuid.UUID `json:"analysis_id,omitempty"`
+	Title        string     `json:"title"`
+	Counterparty string     `json:"counterparty,omitempty"`
+	Terms
+	TermEnd    *time.Time `json:"term_end,omitempty"`  // End of the current term
+	CancelBy   *time.Time `json:"cancel_by,omitempty"` // Last day to give notice for TermEnd
+	Source     string     `json:"source"`
+	Status     string     `json:"status"`
+	DeadlineID *uuid.UUID `json:"deadline_id,omitempty"`
+
+	CancelledBy *uuid.UUID `json:"cancelled_by,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	Note        string     `json:"note,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ListFilter filters contracts
+type ListFilter struct {
+	TenantID uuid.UUID
+	Status   string
+	// DueWithin only returns contracts whose last day to cancel is at most
+	// that many days after Today
+	DueWithin *int
+	Today     time.Time
+	Limit     int
+	Offset    int
+}
+
+// Summary counts the contracts of a tenant
+type Summary struct {
+	Active      int        `json:"active"`
+	Cancelled   int        `json:"cancelled"`
+	Expired     int        `json:"expired"`
+	AutoRenewal int        `json:"auto_renewal"` // Active contracts that renew
+	DueSoon     int        `json:"due_soon"`     // Active, cancel by within DueSoonDays
+	Overdue     int        `json:"overdue"`      // Active, cancel by passed, not rolled over yet
+	NextCancel  *time.Time `json:"next_cancel_by,omitempty"`
+}
+
+// Overview is the contract register of a tenant
+type Overview struct {
+	Summary Summary     `json:"summary"`
+	Items   []*Contract `json:"items"`
+	Total   int         `json:"total"`
+	Limit   int         `json:"limit"`
+	Offset  int         `json:"offset"`
+}
+
+// UpdateInput corrects the terms of a contract. Fields left nil are kept,
+// an empty date or period clears it. The contract is then maintained
+// manually and no longer updated from the document.
+type UpdateInput struct {
+	Title        *string `json:"title"`
+	Counterparty *string `json:"counterparty"`
+	StartDate    *string `json:"start_date"` // YYYY-MM-DD
+	EndDate      *string `json:"end_date"`   // YYYY-MM-DD
+	InitialTerm  *Period `json:"initial_term"`
+	AutoRenewal  *bool   `json:"auto_renewal"`
+	RenewalTerm  *Period `json:"renewal_term"`
+	NoticePeriod *Period `json:"notice_period"`
+}
+
+// RenewalResult counts the changes of a renewal run
+type RenewalResult struct {
+	Renewed int `json:"renewed"`
+	Expired int `json:"expired"`
+}
@@ -0,0 +1,178 @@
+package customfield
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// Handler handles custom field definition HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new custom field handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers custom field routes
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	// Admin-only: manage definitions
+	router.Handle("POST /api/v1/custom-fields", requireAuth(requireAdmin(http.HandlerFunc(h.Create))))
+	router.Handle("PATCH /api/v1/custom-fields/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Update))))
+	router.Handle("DELETE /api/v1/custom-fields/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Delete))))
+
+	// Member access: forms and filters need the definitions
+	router.Handle("GET /api/v1/custom-fields", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("GET /api/v1/custom-fields/{id}", requireAuth(http.HandlerFunc(h.Get)))
+}
+
+// List handles GET /api/v1/custom-fields?entity_type=invoice
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.tenantID(w, r)
+	if !ok {
+		return
+	}
+	entityType, err := ParseEntityType(r.URL.Query().Get("entity_type"))
+	if err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
+	defs, err := h.service.List(r.Context(), tenantID, entityType)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"fields": defs,
+	})
+}
+
+// Get handles GET /api/v1/custom-fields/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.tenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	d, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, d)
+}
+
+// Create handles POST /api/v1/custom-fields
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.tenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var input CreateDefinitionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	d, err := h.service.Create(r.Context(), tenantID, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, d)
+}
+
+// Update handles PATCH /api/v1/custom-fields/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.tenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	var input UpdateDefinitionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	d, err := h.service.Update(r.Context(), tenantID, id, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, d)
+}
+
+// Delete handles DELETE /api/v1/custom-fields/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.tenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), tenantID, id); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) tenantID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
+
+func (h *Handler) tenantAndID(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, ok := h.tenantID(w, r)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid custom field ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return tenantID, id, true
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrDefinitionNotFound):
+		api.NotFound(w, "custom field not found")
+	case errors.Is(err, ErrDuplicateKey):
+		api.Conflict(w, err.Error())
+	case IsInvalid(err):
+		api.BadRequest(w, err.Error())
+	default:
+		api.InternalError(w)
+	}
+}
+
+// IsInvalid reports whether err is caused by an invalid definition, value or
+// filter, so that entity handlers can answer 400 with its message
+func IsInvalid(err error) bool {
+	var ve *ValueError
+	return errors.As(err, &ve) ||
+		errors.Is(err, ErrInvalidEntityType) ||
+		errors.Is(err, ErrInvalidKey) ||
+		errors.Is(err, ErrInvalidFieldType) ||
+		errors.Is(err, ErrMissingLabel) ||
+		errors.Is(err, ErrInvalidOptions) ||
+		errors.Is(err, ErrTooManyFields)
+}
@@ -0,0 +1,193 @@
+package customfield
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles custom field definition database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new custom field repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const definitionColumns = `id, tenant_id, entity_type, key, label, field_type,
+	required, options, position, created_at, updated_at`
+
+// List returns the tenant's fields for an entity type in display order
+func (r *Repository) List(ctx context.Context, tenantID uuid.UUID, entityType EntityType) ([]*Definition, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+definitionColumns+`
+		FROM custom_field_definitions
+		WHERE tenant_id = $1 AND entity_type = $2
+		ORDER BY position, key`, tenantID, entityType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	defer rows.Close()
+
+	defs := make([]*Definition, 0)
+	for rows.Next() {
+		d, err := scanDefinition(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan custom field: %w", err)
+		}
+		defs = append(defs, d)
+	}
+	return defs, rows.Err()
+}
+
+// Get returns a field of the tenant
+func (r *Repository) Get(ctx context.Context, tenantID, id uuid.UUID) (*Definition, error) {
+	d, err := scanDefinition(r.db.QueryRow(ctx, `
+		SELECT `+definitionColumns+`
+		FROM custom_field_definitions
+		WHERE id = $1 AND tenant_id = $2`, id, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDefinitionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom field: %w", err)
+	}
+	return d, nil
+}
+
+// Count returns the number of fields the tenant has for an entity type
+func (r *Repository) Count(ctx context.Context, tenantID uuid.UUID, entityType EntityType) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM custom_field_definitions
+		WHERE tenant_id = $1 AND entity_type = $2`, tenantID, entityType).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count custom fields: %w", err)
+	}
+	return n, nil
+}
+
+// Create inserts a field
+func (r *Repository) Create(ctx context.Context, d *Definition) error {
+	d.ID = uuid.New()
+	d.CreatedAt = time.Now()
+	d.UpdatedAt = d.CreatedAt
+
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO custom_field_definitions (
+			id, tenant_id, entity_type, key, label, field_type,
+			required, options, position, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		d.ID, d.TenantID, d.EntityType, d.Key, d.Label, d.FieldType,
+		d.Required, optionsParam(d.Options), d.Position, d.CreatedAt, d.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrDuplicateKey
+		}
+		return fmt.Errorf("failed to create custom field: %w", err)
+	}
+	return nil
+}
+
+// Update writes the mutable attributes of a field
+func (r *Repository) Update(ctx context.Context, d *Definition) error {
+	d.UpdatedAt = time.Now()
+
+	result, err := r.db.Exec(ctx, `
+		UPDATE custom_field_definitions
+		SET label = $3, required = $4, options = $5, position = $6, updated_at = $7
+		WHERE id = $1 AND tenant_id = $2`,
+		d.ID, d.TenantID, d.Label, d.Required, optionsParam(d.Options), d.Position, d.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update custom field: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrDefinitionNotFound
+	}
+	return nil
+}
+
+// Delete removes a field and strips its values from the tenant's entities,
+// so that a later field with the same key starts empty
+func (r *Repository) Delete(ctx context.Context, d *Definition) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `DELETE FROM custom_field_definitions WHERE id = $1 AND tenant_id = $2`, d.ID, d.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete custom field: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrDefinitionNotFound
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE `+entityTables[d.EntityType]+`
+		SET custom_fields = custom_fields - $2
+		WHERE tenant_id = $1 AND custom_fields ? $2`, d.TenantID, d.Key)
+	if err != nil {
+		return fmt.Errorf("failed to remove custom field values: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ValuesInUse returns which of the values are stored in the field on any of
+// the tenant's entities
+func (r *Repository) ValuesInUse(ctx context.Context, d *Definition, values []string) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT custom_fields ->> $2
+		FROM `+entityTables[d.EntityType]+`
+		WHERE tenant_id = $1 AND custom_fields ->> $2 = ANY($3)`, d.TenantID, d.Key, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check custom field values: %w", err)
+	}
+	defer rows.Close()
+
+	var used []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		used = append(used, v)
+	}
+	return used, rows.Err()
+}
+
+// optionsParam keeps options a JSON array, also when there are none
+func optionsParam(options []string) []string {
+	if options == nil {
+		return []string{}
+	}
+	return options
+}
+
+func scanDefinition(row pgx.Row) (*Definition, error) {
+	var d Definition
+	err := row.Scan(
+		&d.ID, &d.TenantID, &d.EntityType, &d.Key, &d.Label, &d.FieldType,
+		&d.Required, &d.Options, &d.Position, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.Options) == 0 {
+		d.Options = nil
+	}
+	return &d, nil
+}
@@ -0,0 +1,148 @@
+package customfield
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Service manages field definitions and validates values and filters for
+// the entity services. A nil Service has no fields, so entities accept no
+// custom values.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new custom field service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// List returns the tenant's fields for an entity type in display order
+func (s *Service) List(ctx context.Context, tenantID uuid.UUID, entityType EntityType) ([]*Definition, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if _, ok := entityTables[entityType]; !ok {
+		return nil, ErrInvalidEntityType
+	}
+	return s.repo.List(ctx, tenantID, entityType)
+}
+
+// Get returns a field of the tenant
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Definition, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// Create defines a new field
+func (s *Service) Create(ctx context.Context, tenantID uuid.UUID, input *CreateDefinitionInput) (*Definition, error) {
+	d := &Definition{
+		TenantID:   tenantID,
+		EntityType: input.EntityType,
+		Key:        strings.TrimSpace(input.Key),
+		Label:      input.Label,
+		FieldType:  input.FieldType,
+		Required:   input.Required,
+		Options:    input.Options,
+		Position:   input.Position,
+	}
+	if err := ValidateDefinition(d); err != nil {
+		return nil, err
+	}
+
+	n, err := s.repo.Count(ctx, tenantID, d.EntityType)
+	if err != nil {
+		return nil, err
+	}
+	if n >= MaxFieldsPerEntity {
+		return nil, ErrTooManyFields
+	}
+
+	if err := s.repo.Create(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Update changes the label, required flag, options or position of a field.
+// Making a field required does not touch existing entities; the value is
+// enforced the next time their custom fields are set. Enum options that are
+// still stored on an entity cannot be removed.
+func (s *Service) Update(ctx context.Context, tenantID, id uuid.UUID, input *UpdateDefinitionInput) (*Definition, error) {
+	d, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	oldOptions := d.Options
+
+	if input.Label != nil {
+		d.Label = *input.Label
+	}
+	if input.Required != nil {
+		d.Required = *input.Required
+	}
+	if input.Options != nil {
+		d.Options = input.Options
+	}
+	if input.Position != nil {
+		d.Position = *input.Position
+	}
+	if err := ValidateDefinition(d); err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, o := range oldOptions {
+		if !containsOption(d.Options, o) {
+			removed = append(removed, o)
+		}
+	}
+	if len(removed) > 0 {
+		used, err := s.repo.ValuesInUse(ctx, d, removed)
+		if err != nil {
+			return nil, err
+		}
+		if len(used) > 0 {
+			return nil, &ValueError{Key: d.Key, Reason: ErrOptionInUse.Error() + ": " + strings.Join(used, ", ")}
+		}
+	}
+
+	if err := s.repo.Update(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Delete removes a field together with its stored values
+func (s *Service) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	d, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, d)
+}
+
+// Validate checks input values of an entity against the tenant's fields and
+// returns the values to store. existing holds the entity's current values on
+// update and is nil on create.
+func (s *Service) Validate(ctx context.Context, tenantID uuid.UUID, entityType EntityType, input map[string]interface{}, existing Values) (Values, error) {
+	defs, err := s.List(ctx, tenantID, entityType)
+	if err != nil {
+		return nil, err
+	}
+	return Validate(defs, input, existing)
+}
+
+// Filters turns cf.<key> query parameters into filters. It only reads the
+// definitions if there are parameters.
+func (s *Service) Filters(ctx context.Context, tenantID uuid.UUID, entityType EntityType, params map[string]string) ([]Filter, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	defs, err := s.List(ctx, tenantID, entityType)
+	if err != nil {
+		return nil, err
+	}
+	return BuildFilters(defs, params)
+}
@@ -0,0 +1,132 @@
+package customfield
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDefinitionNotFound = errors.New("custom field not found")
+	ErrDuplicateKey       = errors.New("custom field key already exists")
+	ErrInvalidEntityType  = errors.New("invalid entity type, expected invoice, client or antrag")
+	ErrInvalidKey         = errors.New("key must start with a lowercase letter and contain only lowercase letters, digits and underscores (max 50)")
+	ErrInvalidFieldType   = errors.New("invalid field type, expected text, number, date or enum")
+	ErrMissingLabel       = errors.New("label is required (max 100 characters)")
+	ErrInvalidOptions     = errors.New("enum fields need between 1 and 100 distinct, non-empty options; other types take none")
+	ErrTooManyFields      = errors.New("too many custom fields for this entity type")
+	ErrOptionInUse        = errors.New("option is still used by existing values")
+)
+
+// EntityType is the kind of entity a field is defined for
+type EntityType string
+
+const (
+	EntityInvoice EntityType = "invoice"
+	EntityClient  EntityType = "client"
+	EntityAntrag  EntityType = "antrag"
+)
+
+// entityTables maps entity types to the table holding their values
+var entityTables = map[EntityType]string{
+	EntityInvoice: "invoices",
+	EntityClient:  "clients",
+	EntityAntrag:  "foerderungs_antraege",
+}
+
+// ParseEntityType validates an entity type from a request
+func ParseEntityType(s string) (EntityType, error) {
+	t := EntityType(s)
+	if _, ok := entityTables[t]; !ok {
+		return "", ErrInvalidEntityType
+	}
+	return t, nil
+}
+
+// FieldType is the data type of a field
+type FieldType string
+
+const (
+	TypeText   FieldType = "text"
+	TypeNumber FieldType = "number"
+	TypeDate   FieldType = "date"
+	TypeEnum   FieldType = "enum"
+)
+
+// Limits
+const (
+	MaxFieldsPerEntity = 50
+	MaxKeyLength       = 50
+	MaxLabelLength     = 100
+	MaxTextLength      = 1000
+	MaxOptions         = 100
+)
+
+// DateFormat is the format of date values
+const DateFormat = "2006-01-02"
+
+// Definition is a tenant's custom field on an entity type
+type Definition struct {
+	ID         uuid.UUID  `json:"id"`
+	TenantID   uuid.UUID  `json:"tenant_id"`
+	EntityType EntityType `json:"entity_type"`
+	Key        string     `json:"key"`
+	Label      string     `json:"label"`
+	FieldType  FieldType  `json:"field_type"`
+	Required   bool       `json:"required"`
+	Options    []string   `json:"options,omitempty"`
+	Position   int        `json:"position"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CreateDefinitionInput is the input for creating a field
+type CreateDefinitionInput struct {
+	EntityType EntityType `json:"entity_type"`
+	Key        string     `json:"key"`
+	Label      string     `json:"label"`
+	FieldType  FieldType  `json:"field_type"`
+	Required   bool       `json:"required"`
+	Options    []string   `json:"options,omitempty"`
+	Position   int        `json:"position"`
+}
+
+// UpdateDefinitionInput is the input for updating a field. Key and type are
+// fixed because stored values depend on them.
+type UpdateDefinitionInput struct {
+	Label    *string  `json:"label,omitempty"`
+	Required *bool    `json:"required,omitempty"`
+	Options  []string `json:"options,omitempty"`
+	Position *int     `json:"position,omitempty"`
+}
+
+// Values are the custom field values of one entity, keyed by field key.
+// Numbers are float64, dates YYYY-MM-DD strings.
+type Values map[string]interface{}
+
+// Param returns the values as a query argument. Nil values are stored as an
+// empty object rather than JSON null.
+func (v Values) Param() Values {
+	if v == nil {
+		return Values{}
+	}
+	return v
+}
+
+// Filter restricts a list to entities whose field equals a value
+type Filter struct {
+	Key   string
+	Value interface{}
+}
+
+// ValueError reports an invalid value for a field
+type ValueError struct {
+	Key    string
+	Reason string
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("custom field %s: %s", e.Key, e.Reason)
+}
@@ -0,0 +1,265 @@
+package customfield
+
+import (
+	"encoding/json"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// FilterPrefix marks custom field filters in list query strings, e.g.
+// ?cf.branch_code=W01
+const FilterPrefix = "cf."
+
+// ValidateDefinition checks a field definition and normalizes its options
+func ValidateDefinition(d *Definition) error {
+	if _, ok := entityTables[d.EntityType]; !ok {
+		return ErrInvalidEntityType
+	}
+	if len(d.Key) > MaxKeyLength || !keyPattern.MatchString(d.Key) {
+		return ErrInvalidKey
+	}
+	d.Label = strings.TrimSpace(d.Label)
+	if d.Label == "" || utf8.RuneCountInString(d.Label) > MaxLabelLength {
+		return ErrMissingLabel
+	}
+
+	switch d.FieldType {
+	case TypeText, TypeNumber, TypeDate:
+		if len(d.Options) > 0 {
+			return ErrInvalidOptions
+		}
+		d.Options = nil
+	case TypeEnum:
+		if len(d.Options) == 0 || len(d.Options) > MaxOptions {
+			return ErrInvalidOptions
+		}
+		seen := make(map[string]bool, len(d.Options))
+		for i, o := range d.Options {
+			o = strings.TrimSpace(o)
+			if o == "" || seen[o] {
+				return ErrInvalidOptions
+			}
+			seen[o] = true
+			d.Options[i] = o
+		}
+	default:
+		return ErrInvalidFieldType
+	}
+	return nil
+}
+
+// Validate checks input values against the definitions and merges them into
+// the existing values. A null or empty value removes the field. Keys without
+// a definition are rejected, and required fields must have a value after the
+// merge.
+func Validate(defs []*Definition, input map[string]interface{}, existing Values) (Values, error) {
+	byKey := make(map[string]*Definition, len(defs))
+	for _, d := range defs {
+		byKey[d.Key] = d
+	}
+
+	result := make(Values, len(existing)+len(input))
+	for k, v := range existing {
+		result[k] = v
+	}
+
+	for key, raw := range input {
+		d, ok := byKey[key]
+		if !ok {
+			return nil, &ValueError{Key: key, Reason: "unknown field"}
+		}
+		v, err := normalize(d, raw)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			delete(result, key)
+		} else {
+			result[key] = v
+		}
+	}
+
+	for _, d := range defs {
+		if _, ok := result[d.Key]; d.Required && !ok {
+			return nil, &ValueError{Key: d.Key, Reason: "is required"}
+		}
+	}
+	return result, nil
+}
+
+// normalize converts a decoded JSON value to the stored form of the field.
+// It returns nil for null and empty values.
+func normalize(d *Definition, raw interface{}) (interface{}, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	switch d.FieldType {
+	case TypeNumber:
+		var f float64
+		switch n := raw.(type) {
+		case float64:
+			f = n
+		case int:
+			f = float64(n)
+		case int64:
+			f = float64(n)
+		case json.Number:
+			parsed, err := n.Float64()
+			if err != nil {
+				return nil, &ValueError{Key: d.Key, Reason: "must be a number"}
+			}
+			f = parsed
+		default:
+			return nil, &ValueError{Key: d.Key, Reason: "must be a number"}
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, &ValueError{Key: d.Key, Reason: "must be a finite number"}
+		}
+		return f, nil
+	}
+
+	s, ok := raw.(string)
+	if !ok {
+		return nil, &ValueError{Key: d.Key, Reason: "must be a string"}
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	switch d.FieldType {
+	case TypeText:
+		if utf8.RuneCountInString(s) > MaxTextLength {
+			return nil, &ValueError{Key: d.Key, Reason: "exceeds " + strconv.Itoa(MaxTextLength) + " characters"}
+		}
+	case TypeDate:
+		if _, err := time.Parse(DateFormat, s); err != nil {
+			return nil, &ValueError{Key: d.Key, Reason: "must be a date (YYYY-MM-DD)"}
+		}
+	case TypeEnum:
+		if !containsOption(d.Options, s) {
+			return nil, &ValueError{Key: d.Key, Reason: "must be one of " + strings.Join(d.Options, ", ")}
+		}
+	}
+	return s, nil
+}
+
+func containsOption(options []string, s string) bool {
+	for _, o := range options {
+		if o == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFilterParams collects the cf.<key> parameters of a query string
+func ParseFilterParams(q url.Values) map[string]string {
+	var params map[string]string
+	for name, vals := range q {
+		key, ok := strings.CutPrefix(name, FilterPrefix)
+		if !ok || len(vals) == 0 {
+			continue
+		}
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[key] = vals[0]
+	}
+	return params
+}
+
+// BuildFilters converts query parameters to typed filters. Numbers are
+// parsed so that they match the stored JSON numbers.
+func BuildFilters(defs []*Definition, params map[string]string) ([]Filter, error) {
+	byKey := make(map[string]*Definition, len(defs))
+	for _, d := range defs {
+		byKey[d.Key] = d
+	}
+
+	filters := make([]Filter, 0, len(params))
+	for key, s := range params {
+		d, ok := byKey[key]
+		if !ok {
+			return nil, &ValueError{Key: key, Reason: "unknown field"}
+		}
+		var raw interface{} = s
+		if d.FieldType == TypeNumber {
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, &ValueError{Key: key, Reason: "must be a number"}
+			}
+			raw = f
+		}
+		v, err := normalize(d, raw)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			return nil, &ValueError{Key: key, Reason: "filter value must not be empty"}
+		}
+		filters = append(filters, Filter{Key: key, Value: v})
+	}
+	sort.Slice(filters, func(i, j int) bool { return filters[i].Key < filters[j].Key })
+	return filters, nil
+}
+
+// FilterDocument is the JSON object a custom_fields column must contain to
+// match all filters, or nil if there are none. Pass it as the argument of
+// "custom_fields @> $n".
+func FilterDocument(filters []Filter) []byte {
+	if len(filters) == 0 {
+		return nil
+	}
+	doc := make(map[string]interface{}, len(filters))
+	for _, f := range filters {
+		doc[f.Key] = f.Value
+	}
+	b, _ := json.Marshal(doc)
+	return b
+}
+
+// CSVHeader returns the export column titles of the fields
+func CSVHeader(defs []*Definition) []string {
+	header := make([]string, len(defs))
+	for i, d := range defs {
+		header[i] = CSVCell(d.Label)
+	}
+	return header
+}
+
+// CSVValues returns the export cells of an entity's values, in the order of
+// the definitions
+func CSVValues(defs []*Definition, values Values) []string {
+	cells := make([]string, len(defs))
+	for i, d := range defs {
+		switch v := values[d.Key].(type) {
+		case nil:
+		case float64:
+			cells[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case string:
+			cells[i] = CSVCell(v)
+		default:
+			b, _ := json.Marshal(v)
+			cells[i] = CSVCell(string(b))
+		}
+	}
+	return cells
+}
+
+// CSVCell neutralizes free text that a spreadsheet would run as a formula
+func CSVCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,277 @@
+package datev
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/uva"
+)
+
+// ekrKonto is an account of the EKR with its default DATEV accounts
+type ekrKonto struct {
+	Bezeichnung string
+	SKR03       string
+	SKR04       string
+}
+
+// ekrKonten are the EKR accounts the postings use. The default accounts
+// are the usual SKR accounts of the same purpose; the German automatic
+// accounts are booked with BUOhneAutomatik.
+var ekrKonten = map[string]ekrKonto{
+	EKRForderungen:          {"Lieferforderungen", "1400", "1200"},
+	EKRVorsteuer:            {"Vorsteuer", "1570", "1400"},
+	EKREUSt:                 {"Einfuhrumsatzsteuer (Vorsteuer)", "1588", "1433"},
+	EKRVorsteuerIGE:         {"Vorsteuer innergemeinschaftlicher Erwerb", "1574", "1404"},
+	EKRBank:                 {"Bank", "1200", "1800"},
+	EKRVerbindlichkeiten:    {"Lieferverbindlichkeiten", "1600", "3300"},
+	EKRUmsatzsteuer:         {"Umsatzsteuer", "1770", "3800"},
+	EKREUStSchuld:           {"Einfuhrumsatzsteuer (Abgabenkonto)", "1770", "3800"},
+	EKRUmsatzsteuerIGE:      {"Umsatzsteuer innergemeinschaftlicher Erwerb", "1774", "3804"},
+	EKRErloese20:            {"Erlöse 20 %", "8400", "4400"},
+	EKRErloese10:            {"Erlöse 10 %", "8300", "4300"},
+	EKRErloese13:            {"Erlöse 13 %", "8300", "4300"},
+	EKRErloeseSonstige:      {"Erlöse sonstige Steuersätze", "8200", "4200"},
+	EKRErloeseSteuerfrei:    {"Steuerfreie Erlöse", "8100", "4100"},
+	EKRErloeseIGL:           {"Erlöse innergemeinschaftliche Lieferungen", "8125", "4125"},
+	EKRErloeseAusfuhr:       {"Erlöse Ausfuhrlieferungen", "8120", "4120"},
+	EKRErloeseReverseCharge: {"Erlöse Reverse Charge", "8337", "4337"},
+	EKRWareneinkauf20:       {"Wareneinkauf 20 %", "3400", "5400"},
+	EKRWareneinkauf10:       {"Wareneinkauf 10 %", "3300", "5300"},
+	EKRWareneinkauf13:       {"Wareneinkauf 13 %", "3300", "5300"},
+	EKRWareneinkaufSonstige: {"Wareneinkauf sonstige Steuersätze", "3200", "5200"},
+	EKRWareneinkaufIGE:      {"Innergemeinschaftlicher Erwerb", "3425", "5425"},
+}
+
+// IsEKRKonto reports whether an EKR account is used by the export
+func IsEKRKonto(konto string) bool {
+	_, ok := ekrKonten[konto]
+	return ok
+}
+
+// Zuordnungen returns the DATEV account of every EKR account under the
+// settings, ordered by EKR account. Default accounts are extended with
+// zeros to the Sachkontenlänge.
+func Zuordnungen(s *Settings) []Zuordnung {
+	list := make([]Zuordnung, 0, len(ekrKonten))
+	for ekr, k := range ekrKonten {
+		z := Zuordnung{EKRKonto: ekr, Bezeichnung: k.Bezeichnung}
+		if konto, ok := s.Konten[ekr]; ok && konto != "" {
+			z.Konto, z.Custom = konto, true
+		} else {
+			z.Konto = k.SKR03
+			if s.Kontenrahmen == SKR04 {
+				z.Konto = k.SKR04
+			}
+			if n := s.Sachkontenlaenge - len(z.Konto); n > 0 {
+				z.Konto += strings.Repeat("0", n)
+			}
+		}
+		list = append(list, z)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].EKRKonto < list[j].EKRKonto })
+	return list
+}
+
+// Zuordnen maps the EKR accounts of postings to DATEV accounts, keeping the
+// EKR accounts in SollEKR and HabenEKR
+func Zuordnen(buchungen []Buchung, s *Settings) []Buchung {
+	konten := make(map[string]string, len(ekrKonten))
+	for _, z := range Zuordnungen(s) {
+		konten[z.EKRKonto] = z.Konto
+	}
+	mapped := make([]Buchung, len(buchungen))
+	for i, b := range buchungen {
+		b.SollEKR, b.HabenEKR = b.Soll, b.Haben
+		b.Soll, b.Haben = konten[b.Soll], konten[b.Haben]
+		mapped[i] = b
+	}
+	return mapped
+}
+
+// Buchen derives the postings of [from, to]. Outgoing and input invoices
+// are booked on their invoice date, net per rate on the revenue or expense
+// account and the tax on the tax account; credit notes are booked the other
+// way round. Receipts of outgoing invoices and payments of input invoices
+// are booked against the bank. Import VAT is booked without the customs
+// value, which the supplier's invoice covers.
+func Buchen(invoices []*uva.TaxableInvoice, inputs []*uva.InputInvoice, from, to time.Time) []Buchung {
+	var buchungen []Buchung
+	for _, inv := range invoices {
+		text := "Rechnung " + inv.InvoiceNumber
+		if inv.CreditNote {
+			text = "Gutschrift " + inv.InvoiceNumber
+		}
+		if inPeriod(inv.IssueDate, from, to) {
+			for _, l := range ausgangZeilen(inv) {
+				buchungen = append(buchungen, buchung(inv.IssueDate, l.betrag, EKRForderungen, l.konto, l.bu, inv.InvoiceNumber, text, inv.CreditNote))
+			}
+		}
+		for _, r := range inv.Receipts {
+			if inPeriod(r.Date, from, to) {
+				buchungen = append(buchungen, buchung(r.Date, r.Amount, EKRBank, EKRForderungen, "", inv.InvoiceNumber,
+					"Zahlung "+inv.InvoiceNumber, inv.CreditNote))
+			}
+		}
+	}
+
+	for _, inv := range inputs {
+		text := inv.SupplierName + " " + inv.InvoiceNumber
+		var payable int64
+		for _, l := range eingangZeilen(inv) {
+			if l.gegen == EKRVerbindlichkeiten {
+				payable += l.betrag
+			}
+			if inPeriod(inv.InvoiceDate, from, to) {
+				buchungen = append(buchungen, buchung(inv.InvoiceDate, l.betrag, l.konto, l.gegen, l.bu, inv.InvoiceNumber, text, inv.CreditNote))
+			}
+		}
+		if inv.PaidDate != nil && inPeriod(*inv.PaidDate, from, to) && payable != 0 {
+			buchungen = append(buchungen, buchung(*inv.PaidDate, payable, EKRVerbindlichkeiten, EKRBank, "", inv.InvoiceNumber,
+				"Zahlung "+text, inv.CreditNote))
+		}
+	}
+
+	sort.SliceStable(buchungen, func(i, j int) bool { return buchungen[i].Datum.Before(buchungen[j].Datum) })
+	return buchungen
+}
+
+// zeile is an amount booked on an account against a counter account
+type zeile struct {
+	konto  string
+	gegen  string
+	betrag int64
+	bu     string
+}
+
+// ausgangZeilen splits an outgoing invoice into its net amounts per
+// revenue account and its tax. The tax is computed per rate; a rounding
+// difference to the invoice's gross amount goes to the last taxed rate.
+func ausgangZeilen(inv *uva.TaxableInvoice) []zeile {
+	var zeilen []zeile
+	var net, tax int64
+	last := -1
+	for _, line := range inv.Lines {
+		net += line.Net
+		zeilen = append(zeilen, zeile{konto: erloesKonto(line.TaxCategory, line.TaxPercent), betrag: line.Net, bu: BUOhneAutomatik})
+		if steuerpflichtig(line.TaxCategory) && line.TaxPercent > 0 {
+			t := uva.TaxOf(line.Net, line.TaxPercent)
+			tax += t
+			zeilen = append(zeilen, zeile{konto: EKRUmsatzsteuer, betrag: t})
+			last = len(zeilen) - 1
+		}
+	}
+	if diff := inv.Gross - net - tax; diff != 0 && last >= 0 && inv.Gross != 0 {
+		zeilen[last].betrag += diff
+	}
+	return nonZero(zeilen)
+}
+
+// eingangZeilen splits an input invoice into its postings by kind
+func eingangZeilen(inv *uva.InputInvoice) []zeile {
+	var zeilen []zeile
+	for _, line := range inv.Lines {
+		tax := line.Tax
+		if tax == 0 {
+			tax = uva.TaxOf(line.Net, line.TaxPercent)
+		}
+		switch inv.Kind {
+		case uva.InputImport:
+			zeilen = append(zeilen, zeile{konto: EKREUSt, gegen: EKRVerbindlichkeiten, betrag: tax})
+		case uva.InputImportDeferred:
+			zeilen = append(zeilen, zeile{konto: EKREUSt, gegen: EKREUStSchuld, betrag: tax})
+		case uva.InputIGAcquisition:
+			zeilen = append(zeilen,
+				zeile{konto: EKRWareneinkaufIGE, gegen: EKRVerbindlichkeiten, betrag: line.Net, bu: BUOhneAutomatik},
+				zeile{konto: EKRVorsteuerIGE, gegen: EKRUmsatzsteuerIGE, betrag: tax})
+		default:
+			zeilen = append(zeilen,
+				zeile{konto: aufwandKonto(line.TaxPercent), gegen: EKRVerbindlichkeiten, betrag: line.Net, bu: BUOhneAutomatik},
+				zeile{konto: EKRVorsteuer, gegen: EKRVerbindlichkeiten, betrag: tax})
+		}
+	}
+	return nonZero(zeilen)
+}
+
+func nonZero(zeilen []zeile) []zeile {
+	kept := zeilen[:0]
+	for _, z := range zeilen {
+		if z.betrag != 0 {
+			kept = append(kept, z)
+		}
+	}
+	return kept
+}
+
+// buchung books an amount from soll to haben; a negative amount or a
+// credit note books it the other way round
+func buchung(datum time.Time, betrag int64, soll, haben, bu, beleg, text string, reverse bool) Buchung {
+	if betrag < 0 {
+		betrag, reverse = -betrag, !reverse
+	}
+	if reverse {
+		soll, haben = haben, soll
+	}
+	return Buchung{Datum: datum, Betrag: betrag, Soll: soll, Haben: haben, BUSchluessel: bu, Beleg: beleg, Text: text}
+}
+
+// steuerpflichtig reports whether an EN 16931 tax category carries
+// Austrian VAT
+func steuerpflichtig(category string) bool {
+	switch category {
+	case "K", "G", "AE", "E", "Z", "O":
+		return false
+	}
+	return true
+}
+
+// erloesKonto returns the revenue account of a tax category and rate
+func erloesKonto(category string, percent float64) string {
+	switch category {
+	case "K":
+		return EKRErloeseIGL
+	case "G":
+		return EKRErloeseAusfuhr
+	case "AE":
+		return EKRErloeseReverseCharge
+	case "E", "Z", "O":
+		return EKRErloeseSteuerfrei
+	}
+	switch percent {
+	case 20:
+		return EKRErloese20
+	case 10:
+		return EKRErloese10
+	case 13:
+		return EKRErloese13
+	case 0:
+		return EKRErloeseSteuerfrei
+	}
+	return EKRErloeseSonstige
+}
+
+// aufwandKonto returns the expense account of a domestic input invoice rate
+func aufwandKonto(percent float64) string {
+	switch percent {
+	case 20:
+		return EKRWareneinkauf20
+	case 10:
+		return EKRWareneinkauf10
+	case 13:
+		return EKRWareneinkauf13
+	}
+	return EKRWareneinkaufSonstige
+}
+
+// FiscalYearStart returns the first day of the fiscal year containing day
+func FiscalYearStart(day time.Time, startMonth int) time.Time {
+	start := time.Date(day.Year(), time.Month(startMonth), 1, 0, 0, 0, 0, time.UTC)
+	if day.Before(start) {
+		start = start.AddDate(-1, 0, 0)
+	}
+	return start
+}
+
+func inPeriod(date, from, to time.Time) bool {
+	return !date.Before(from) && !date.After(to)
+}
@@ -0,0 +1,112 @@
+package datev
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// EXTF header of a Buchungsstapel: DATEV format version 700, category 21,
+// format version 13
+const (
+	extfVersion         = 700
+	extfKategorie       = 21
+	extfFormatName      = "Buchungsstapel"
+	extfFormatVersion   = 13
+	extfHerkunft        = "RE"
+	maxBelegLength      = 36
+	maxTextLength       = 60
+	maxBezeichnungLen   = 30
+	extfBuchungstypFibu = 1
+)
+
+// extfSpalten are the leading columns of the Buchungsstapel; the columns
+// after the Buchungstext are optional and left out
+var extfSpalten = []string{
+	"Umsatz (ohne Soll/Haben-Kz)", "Soll/Haben-Kennzeichen", "WKZ Umsatz", "Kurs", "Basis-Umsatz",
+	"WKZ Basis-Umsatz", "Konto", "Gegenkonto (ohne BU-Schlüssel)", "BU-Schlüssel", "Belegdatum",
+	"Belegfeld 1", "Belegfeld 2", "Skonto", "Buchungstext",
+}
+
+// WriteEXTF writes a Buchungsstapel in the DATEV EXTF format: semicolon
+// separated, Windows-1252 encoded with CRLF line ends. Each posting debits
+// Konto (Soll/Haben-Kennzeichen S) and credits Gegenkonto. The postings must
+// lie within one fiscal year, as the Belegdatum carries no year.
+func WriteEXTF(w io.Writer, s *Settings, stapel *Stapel, created time.Time) error {
+	enc := encoding.ReplaceUnsupported(charmap.Windows1252.NewEncoder())
+	bw := bufio.NewWriter(enc.Writer(w))
+
+	skr := strings.TrimPrefix(s.Kontenrahmen, "SKR")
+	header := []string{
+		quote("EXTF"), strconv.Itoa(extfVersion), strconv.Itoa(extfKategorie), quote(extfFormatName),
+		strconv.Itoa(extfFormatVersion), created.UTC().Format("20060102150405") + "000", "",
+		quote(extfHerkunft), quote(""), quote(""),
+		strconv.Itoa(s.BeraterNr), strconv.Itoa(s.MandantNr),
+		FiscalYearStart(stapel.Von, s.WJBeginnMonat).Format("20060102"), strconv.Itoa(s.Sachkontenlaenge),
+		stapel.Von.Format("20060102"), stapel.Bis.Format("20060102"),
+		quote(truncate("Buchungen "+stapel.Von.Format("01/2006")+"-"+stapel.Bis.Format("01/2006"), maxBezeichnungLen)),
+		quote(""), strconv.Itoa(extfBuchungstypFibu), "0", "0", quote("EUR"), "", quote(""), "", "",
+		quote(skr), "", "", quote(""), quote(""),
+	}
+	writeLine(bw, header)
+
+	spalten := make([]string, len(extfSpalten))
+	for i, name := range extfSpalten {
+		spalten[i] = quote(name)
+	}
+	writeLine(bw, spalten)
+
+	for _, b := range stapel.Buchungen {
+		writeLine(bw, []string{
+			betrag(b.Betrag), quote("S"), quote("EUR"), "", "", quote(""),
+			b.Soll, b.Haben, quote(b.BUSchluessel), b.Datum.Format("0201"),
+			quote(Belegfeld(b.Beleg)), quote(""), "", quote(truncate(b.Text, maxTextLength)),
+		})
+	}
+	return bw.Flush()
+}
+
+func writeLine(w *bufio.Writer, fields []string) {
+	w.WriteString(strings.Join(fields, ";"))
+	w.WriteString("\r\n")
+}
+
+// quote quotes a text field, doubling quotes inside
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// betrag formats cents with a decimal comma
+func betrag(cents int64) string {
+	return fmt.Sprintf("%d,%02d", cents/100, cents%100)
+}
+
+// Belegfeld returns the Belegfeld 1 of a document number: DATEV allows
+// letters, digits and $ % & * + - / _ up to 36 characters, other characters
+// become -
+func Belegfeld(beleg string) string {
+	var b strings.Builder
+	for _, r := range beleg {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("$%&*+-/_", r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	return truncate(b.String(), maxBelegLength)
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
@@ -0,0 +1,154 @@
+package datev
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler handles DATEV export HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new DATEV export handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the DATEV export routes
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/datev/settings", requireAuth(http.HandlerFunc(h.GetSettings)))
+	router.Handle("PUT /api/v1/datev/settings", requireAuth(requireAdmin(http.HandlerFunc(h.SaveSettings))))
+	router.Handle("GET /api/v1/datev/buchungen", requireAuth(http.HandlerFunc(h.Buchungen)))
+	router.Handle("GET /api/v1/datev/export", requireAuth(http.HandlerFunc(h.Export)))
+}
+
+// GetSettings handles GET /api/v1/datev/settings
+func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.service.Settings(r.Context(), tenantID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"settings":    settings,
+		"zuordnungen": Zuordnungen(settings),
+	})
+}
+
+// SaveSettings handles PUT /api/v1/datev/settings
+func (h *Handler) SaveSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(api.GetUserID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "user not found in context")
+		return
+	}
+
+	var input SettingsInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	settings, err := h.service.SaveSettings(r.Context(), tenantID, userID, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"settings":    settings,
+		"zuordnungen": Zuordnungen(settings),
+	})
+}
+
+// Buchungen handles GET /api/v1/datev/buchungen
+func (h *Handler) Buchungen(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	from, to, ok := parsePeriod(w, r)
+	if !ok {
+		return
+	}
+
+	stapel, _, err := h.service.Stapel(r.Context(), tenantID, from, to)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if stapel.Buchungen == nil {
+		stapel.Buchungen = []Buchung{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, stapel)
+}
+
+// Export handles GET /api/v1/datev/export
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	from, to, ok := parsePeriod(w, r)
+	if !ok {
+		return
+	}
+
+	content, name, err := h.service.Export(r.Context(), tenantID, from, to)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=windows-1252")
+	w.Header().Set("Content-Disposition", "attachment; filename="+name)
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
+
+// parsePeriod reads the from and to dates of the request, writing 400 if
+// they are missing or invalid
+func parsePeriod(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	from, err := time.Parse("2006-01-02", r.URL.Query().Get("from"))
+	if err != nil {
+		api.BadRequest(w, "from must be a date (YYYY-MM-DD)")
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := time.Parse("2006-01-02", r.URL.Query().Get("to"))
+	if err != nil {
+		api.BadRequest(w, "to must be a date (YYYY-MM-DD)")
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotConfigured):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, ErrInvalidBerater), errors.Is(err, ErrInvalidMandant), errors.Is(err, ErrInvalidKontenrahmen),
+		errors.Is(err, ErrInvalidWJBeginn), errors.Is(err, ErrInvalidKontenlaenge), errors.Is(err, ErrUnknownEKRKonto),
+		errors.Is(err, ErrInvalidKonto), errors.Is(err, ErrInvalidPeriod):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	default:
+		api.InternalError(w)
+	}
+}
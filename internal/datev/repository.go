@@ -0,0 +1,60 @@
+package datev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository stores the DATEV settings of tenants
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new DATEV repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// GetSettings returns the settings of a tenant, nil if it has none
+func (r *Repository) GetSettings(ctx context.Context, tenantID uuid.UUID) (*Settings, error) {
+	s := &Settings{TenantID: tenantID, Configured: true}
+	err := r.db.QueryRow(ctx, `
+		SELECT berater_nr, mandant_nr, kontenrahmen, wj_beginn_monat, sachkontenlaenge, konten, updated_at
+		FROM datev_settings WHERE tenant_id = $1`, tenantID,
+	).Scan(&s.BeraterNr, &s.MandantNr, &s.Kontenrahmen, &s.WJBeginnMonat, &s.Sachkontenlaenge, &s.Konten, &s.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DATEV settings: %w", err)
+	}
+	if s.Konten == nil {
+		s.Konten = map[string]string{}
+	}
+	return s, nil
+}
+
+// SaveSettings stores the settings of a tenant
+func (r *Repository) SaveSettings(ctx context.Context, s *Settings, userID uuid.UUID) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO datev_settings (tenant_id, berater_nr, mandant_nr, kontenrahmen, wj_beginn_monat,
+			sachkontenlaenge, konten, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			berater_nr = EXCLUDED.berater_nr, mandant_nr = EXCLUDED.mandant_nr,
+			kontenrahmen = EXCLUDED.kontenrahmen, wj_beginn_monat = EXCLUDED.wj_beginn_monat,
+			sachkontenlaenge = EXCLUDED.sachkontenlaenge, konten = EXCLUDED.konten,
+			updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING updated_at`,
+		s.TenantID, s.BeraterNr, s.MandantNr, s.Kontenrahmen, s.WJBeginnMonat, s.Sachkontenlaenge, s.Konten, userID,
+	).Scan(&s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save DATEV settings: %w", err)
+	}
+	return nil
+}
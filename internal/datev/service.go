@@ -0,0 +1,168 @@
+package datev
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/uva"
+)
+
+// Source provides the invoices the postings are derived from;
+// uva.Repository implements it
+type Source interface {
+	ListTaxableInvoices(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*uva.TaxableInvoice, error)
+	ListInputInvoices(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*uva.InputInvoice, error)
+}
+
+// Service handles the DATEV export
+type Service struct {
+	repo   *Repository
+	source Source
+	now    func() time.Time
+}
+
+// NewService creates a new DATEV export service
+func NewService(repo *Repository, source Source) *Service {
+	return &Service{repo: repo, source: source, now: time.Now}
+}
+
+// Settings returns the DATEV settings of a tenant; SKR03 with a calendar
+// fiscal year and four-digit accounts while not configured
+func (s *Service) Settings(ctx context.Context, tenantID uuid.UUID) (*Settings, error) {
+	settings, err := s.repo.GetSettings(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &Settings{
+			TenantID:         tenantID,
+			Kontenrahmen:     SKR03,
+			WJBeginnMonat:    1,
+			Sachkontenlaenge: 4,
+			Konten:           map[string]string{},
+		}
+	}
+	return settings, nil
+}
+
+// SaveSettings validates and stores the DATEV settings of a tenant
+func (s *Service) SaveSettings(ctx context.Context, tenantID, userID uuid.UUID, input *SettingsInput) (*Settings, error) {
+	if input.BeraterNr < 1001 || input.BeraterNr > 9999999 {
+		return nil, ErrInvalidBerater
+	}
+	if input.MandantNr < 1 || input.MandantNr > 99999 {
+		return nil, ErrInvalidMandant
+	}
+	if input.Kontenrahmen != SKR03 && input.Kontenrahmen != SKR04 {
+		return nil, ErrInvalidKontenrahmen
+	}
+	if input.WJBeginnMonat == 0 {
+		input.WJBeginnMonat = 1
+	}
+	if input.WJBeginnMonat < 1 || input.WJBeginnMonat > 12 {
+		return nil, ErrInvalidWJBeginn
+	}
+	if input.Sachkontenlaenge == 0 {
+		input.Sachkontenlaenge = 4
+	}
+	if input.Sachkontenlaenge < 4 || input.Sachkontenlaenge > 8 {
+		return nil, ErrInvalidKontenlaenge
+	}
+
+	konten := make(map[string]string, len(input.Konten))
+	for ekr, konto := range input.Konten {
+		if !IsEKRKonto(ekr) {
+			return nil, ErrUnknownEKRKonto
+		}
+		if konto == "" {
+			continue
+		}
+		if !validKonto(konto, input.Sachkontenlaenge) {
+			return nil, ErrInvalidKonto
+		}
+		konten[ekr] = konto
+	}
+
+	settings := &Settings{
+		TenantID:         tenantID,
+		Configured:       true,
+		BeraterNr:        input.BeraterNr,
+		MandantNr:        input.MandantNr,
+		Kontenrahmen:     input.Kontenrahmen,
+		WJBeginnMonat:    input.WJBeginnMonat,
+		Sachkontenlaenge: input.Sachkontenlaenge,
+		Konten:           konten,
+	}
+	if err := s.repo.SaveSettings(ctx, settings, userID); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Stapel derives the postings of [from, to] mapped to the DATEV accounts of
+// the tenant. The period must lie within one fiscal year.
+func (s *Service) Stapel(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (*Stapel, *Settings, error) {
+	settings, err := s.Settings(ctx, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if to.Before(from) || !FiscalYearStart(from, settings.WJBeginnMonat).Equal(FiscalYearStart(to, settings.WJBeginnMonat)) {
+		return nil, nil, ErrInvalidPeriod
+	}
+
+	invoices, err := s.source.ListTaxableInvoices(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	inputs, err := s.source.ListInputInvoices(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stapel := &Stapel{
+		Von:          from,
+		Bis:          to,
+		Kontenrahmen: settings.Kontenrahmen,
+		Buchungen:    Zuordnen(Buchen(invoices, inputs, from, to), settings),
+	}
+	for _, b := range stapel.Buchungen {
+		stapel.Summe += b.Betrag
+	}
+	return stapel, settings, nil
+}
+
+// Export returns the Buchungsstapel of [from, to] as EXTF file with its
+// file name
+func (s *Service) Export(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]byte, string, error) {
+	stapel, settings, err := s.Stapel(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, "", err
+	}
+	if !settings.Configured {
+		return nil, "", ErrNotConfigured
+	}
+
+	var buf bytes.Buffer
+	if err := WriteEXTF(&buf, settings, stapel, s.now()); err != nil {
+		return nil, "", err
+	}
+	name := "EXTF_Buchungsstapel_" + from.Format("20060102") + "_" + to.Format("20060102") + ".csv"
+	return buf.Bytes(), name, nil
+}
+
+// validKonto reports whether a DATEV account has the Sachkontenlänge and
+// only digits
+func validKonto(konto string, length int) bool {
+	if len(konto) != length {
+		return false
+	}
+	for _, r := range konto {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+// Package datev exports the bookings of a tenant as DATEV Buchungsstapel
+// (EXTF format) for German parent companies. The postings are derived from
+// the outgoing invoices with their receipts and the input invoices the UVA
+// is computed from, booked on the accounts of the österreichischer
+// Einheitskontenrahmen (EKR) and mapped to SKR03 or SKR04.
+package datev
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotConfigured       = errors.New("DATEV export is not configured; set Berater- and Mandantennummer first")
+	ErrInvalidBerater      = errors.New("berater_nr must be between 1001 and 9999999")
+	ErrInvalidMandant      = errors.New("mandant_nr must be between 1 and 99999")
+	ErrInvalidKontenrahmen = errors.New("kontenrahmen must be SKR03 or SKR04")
+	ErrInvalidWJBeginn     = errors.New("wj_beginn_monat must be between 1 and 12")
+	ErrInvalidKontenlaenge = errors.New("sachkontenlaenge must be between 4 and 8")
+	ErrUnknownEKRKonto     = errors.New("unknown EKR account in konten")
+	ErrInvalidKonto        = errors.New("DATEV accounts must be digits of the Sachkontenlänge")
+	ErrInvalidPeriod       = errors.New("from and to must be dates within one fiscal year, from not after to")
+)
+
+// Kontenrahmen of DATEV
+const (
+	SKR03 = "SKR03"
+	SKR04 = "SKR04"
+)
+
+// BUOhneAutomatik is the BU-Schlüssel that turns off the tax automation of
+// a DATEV account. Net amounts and tax are booked on separate lines, as the
+// Austrian rates have no DATEV tax keys.
+const BUOhneAutomatik = "40"
+
+// Accounts of the EKR the postings are booked on
+const (
+	EKRForderungen          = "2000"
+	EKRVorsteuer            = "2500"
+	EKREUSt                 = "2510"
+	EKRVorsteuerIGE         = "2520"
+	EKRBank                 = "2800"
+	EKRVerbindlichkeiten    = "3300"
+	EKRUmsatzsteuer         = "3500"
+	EKREUStSchuld           = "3510"
+	EKRUmsatzsteuerIGE      = "3520"
+	EKRErloese20            = "4000"
+	EKRErloese10            = "4010"
+	EKRErloese13            = "4013"
+	EKRErloeseSonstige      = "4020"
+	EKRErloeseSteuerfrei    = "4090"
+	EKRErloeseIGL           = "4100"
+	EKRErloeseAusfuhr       = "4110"
+	EKRErloeseReverseCharge = "4120"
+	EKRWareneinkauf20       = "5000"
+	EKRWareneinkauf10       = "5010"
+	EKRWareneinkauf13       = "5013"
+	EKRWareneinkaufSonstige = "5020"
+	EKRWareneinkaufIGE      = "5050"
+)
+
+// Settings are the DATEV settings of a tenant: the numbers of the
+// Steuerberater and Mandant at the parent company, the Kontenrahmen and the
+// accounts the EKR accounts are mapped to where they differ from the
+// default mapping
+type Settings struct {
+	TenantID         uuid.UUID         `json:"tenant_id"`
+	Configured       bool              `json:"configured"`
+	BeraterNr        int               `json:"berater_nr"`
+	MandantNr        int               `json:"mandant_nr"`
+	Kontenrahmen     string            `json:"kontenrahmen"`
+	WJBeginnMonat    int               `json:"wj_beginn_monat"`  // First month of the fiscal year
+	Sachkontenlaenge int               `json:"sachkontenlaenge"` // Digits of the general ledger accounts
+	Konten           map[string]string `json:"konten"`           // EKR account to DATEV account
+	UpdatedAt        *time.Time        `json:"updated_at,omitempty"`
+}
+
+// SettingsInput changes the DATEV settings. An empty account in Konten
+// restores the default mapping of that EKR account.
+type SettingsInput struct {
+	BeraterNr        int               `json:"berater_nr"`
+	MandantNr        int               `json:"mandant_nr"`
+	Kontenrahmen     string            `json:"kontenrahmen"`
+	WJBeginnMonat    int               `json:"wj_beginn_monat"`
+	Sachkontenlaenge int               `json:"sachkontenlaenge"`
+	Konten           map[string]string `json:"konten"`
+}
+
+// Zuordnung is the DATEV account an EKR account is exported to
+type Zuordnung struct {
+	EKRKonto    string `json:"ekr_konto"`
+	Bezeichnung string `json:"bezeichnung"`
+	Konto       string `json:"konto"`
+	Custom      bool   `json:"custom"`
+}
+
+// Buchung is a posting: Betrag is debited to Soll and credited to Haben.
+// Soll and Haben are EKR accounts until mapped; then the EKR accounts move
+// to SollEKR and HabenEKR.
+type Buchung struct {
+	Datum        time.Time `json:"datum"`
+	Betrag       int64     `json:"betrag"` // In cents, positive
+	Soll         string    `json:"soll"`
+	Haben        string    `json:"haben"`
+	SollEKR      string    `json:"soll_ekr,omitempty"`
+	HabenEKR     string    `json:"haben_ekr,omitempty"`
+	BUSchluessel string    `json:"bu_schluessel,omitempty"`
+	Beleg        string    `json:"beleg"`
+	Text         string    `json:"text"`
+}
+
+// Stapel is the Buchungsstapel of a period
+type Stapel struct {
+	Von          time.Time `json:"von"`
+	Bis          time.Time `json:"bis"`
+	Kontenrahmen string    `json:"kontenrahmen"`
+	Buchungen    []Buchung `json:"buchungen"`
+	Summe        int64     `json:"summe"` // Sum of the amounts, in cents
+}
@@ -0,0 +1,449 @@
+package demo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/fixtures"
+	"austrian-business-infrastructure/internal/refdata"
+	"austrian-business-infrastructure/pkg/crypto"
+)
+
+// Default and maximum sizes of a demo tenant
+const (
+	DefaultEmployees    = 8
+	DefaultDocuments    = 24
+	DefaultInvoices     = 36
+	DefaultApplications = 3
+	MaxItems            = 500
+)
+
+// Options controls the size and content of a demo tenant
+type Options struct {
+	Name          string    `json:"name,omitempty"` // defaults to a generated company name
+	Seed          int64     `json:"seed,omitempty"` // same seed, same data; defaults to 1
+	Employees     int       `json:"employees,omitempty"`
+	Documents     int       `json:"documents,omitempty"`
+	Invoices      int       `json:"invoices,omitempty"`
+	Applications  int       `json:"applications,omitempty"`
+	OwnerPassword string    `json:"owner_password,omitempty"` // generated if empty
+	Now           time.Time `json:"-"`                        // reference date, defaults to today
+}
+
+// Normalize fills defaults and checks limits
+func (o *Options) Normalize() error {
+	if o.Seed == 0 {
+		o.Seed = 1
+	}
+	if o.Seed < 0 {
+		return errors.New("seed must be positive")
+	}
+	if o.Now.IsZero() {
+		o.Now = time.Now()
+	}
+	if o.OwnerPassword != "" {
+		if err := crypto.ValidatePassword(o.OwnerPassword, nil); err != nil {
+			return fmt.Errorf("invalid password: %w", err)
+		}
+	}
+	for _, n := range []struct {
+		value *int
+		def   int
+		name  string
+	}{
+		{&o.Employees, DefaultEmployees, "employees"},
+		{&o.Documents, DefaultDocuments, "documents"},
+		{&o.Invoices, DefaultInvoices, "invoices"},
+		{&o.Applications, DefaultApplications, "applications"},
+	} {
+		if *n.value == 0 {
+			*n.value = n.def
+		}
+		if *n.value < 0 || *n.value > MaxItems {
+			return fmt.Errorf("%s must be between 1 and %d", n.name, MaxItems)
+		}
+	}
+	return nil
+}
+
+// Dataset is the generated content of a demo tenant
+type Dataset struct {
+	Company    fixtures.Company
+	Slug       string
+	Owner      fixtures.Person
+	OwnerEmail string
+
+	// FinanzOnline and ELDA access
+	TID                 string
+	BenID               string
+	Dienstgebernummer   string
+	Beitragskontonummer string
+
+	Employees    []Employee
+	Documents    []Document
+	Invoices     []Invoice
+	Applications []Application
+}
+
+// Employee is a Dienstnehmer registered with the ÖGK
+type Employee struct {
+	fixtures.Person
+	SVNummer       string
+	Beitragsgruppe string
+	Eintritt       time.Time
+	Wochenstunden  float64
+	BruttoCents    int64 // monthly
+}
+
+// Document is a databox document with its AI analysis
+type Document struct {
+	ExternalID string
+	Type       string
+	Title      string
+	Sender     string
+	ELDA       bool // received through the ELDA account
+	ReceivedAt time.Time
+	Status     string
+	Text       string
+
+	// Analysis
+	DocumentType    string
+	DocumentSubtype string
+	Priority        string
+	Confidence      float64
+	Summary         string
+	KeyPoints       []string
+	Deadline        *time.Time
+	DeadlineType    string
+	Action          string // title of the action item for the deadline
+	Done            bool   // action item completed
+}
+
+// Invoice is an outgoing invoice
+type Invoice struct {
+	Number   string
+	Date     time.Time
+	Due      time.Time
+	Customer fixtures.Company
+	Items    []InvoiceItem
+	Status   string
+	Net      int64
+	Tax      int64
+	Gross    int64
+}
+
+// InvoiceItem is an invoice line
+type InvoiceItem struct {
+	Description    string
+	Quantity       float64
+	Unit           string
+	UnitPriceCents int64
+	TaxRate        float64
+	Net            int64
+	Tax            int64
+	Gross          int64
+}
+
+// Application is a Förderantrag; the program is picked from the catalog
+// when seeding
+type Application struct {
+	Status         string
+	AppliedAt      *time.Time
+	AppliedAmount  int // EUR
+	ApprovedAmount *int
+	DecisionDate   *time.Time
+	Notes          string
+}
+
+// Generate creates the dataset of a demo tenant. It only depends on the
+// options, so the same options always produce the same tenant.
+func Generate(opts Options) *Dataset {
+	f := fixtures.NewFaker(opts.Seed)
+	today := time.Date(opts.Now.Year(), opts.Now.Month(), opts.Now.Day(), 0, 0, 0, 0, time.UTC)
+
+	d := &Dataset{Company: f.Company(), Owner: f.Person()}
+	if opts.Name != "" {
+		d.Company.Name = opts.Name
+	}
+	d.Slug = fmt.Sprintf("demo-%s-%d", slugify(d.Company.Name), opts.Seed)
+	d.OwnerEmail = fmt.Sprintf("%s@example.com", d.Slug)
+	d.TID = f.TID()
+	d.BenID = "DEMO" + f.Digits(6)
+	d.Dienstgebernummer = f.Digits(6)
+	d.Beitragskontonummer = f.Digits(9)
+
+	d.Employees = generateEmployees(f, opts.Employees, today)
+	d.Documents = generateDocuments(f, opts.Documents, today)
+	d.Invoices = generateInvoices(f, d.Company, opts.Invoices, today)
+	d.Applications = generateApplications(f, opts.Applications, today)
+	return d
+}
+
+func generateEmployees(f *fixtures.Faker, n int, today time.Time) []Employee {
+	grenze := refdata.For(today).Geringfuegigkeitsgrenze
+	employees := make([]Employee, 0, n)
+	for i := 0; i < n; i++ {
+		e := Employee{Person: f.Person(), Beitragsgruppe: "D1", Wochenstunden: 38.5}
+		e.SVNummer = f.SVNummer(e.Geburtsdatum)
+		e.Eintritt = today.AddDate(0, -1-f.Intn(96), 0)
+		e.Eintritt = time.Date(e.Eintritt.Year(), e.Eintritt.Month(), 1, 0, 0, 0, 0, time.UTC)
+		e.BruttoCents = f.Between(2300, 5200) * 100
+
+		switch {
+		case i%7 == 3: // Arbeiter
+			e.Beitragsgruppe = "A1"
+			e.Wochenstunden = 40
+			e.BruttoCents = f.Between(2200, 3400) * 100
+		case i%7 == 5: // geringfügig beschäftigt
+			e.Beitragsgruppe = "D3"
+			e.Wochenstunden = 8
+			e.BruttoCents = grenze - f.Between(20, 150)*100
+		case i%7 == 6: // Teilzeit
+			e.Beitragsgruppe = "D2"
+			e.Wochenstunden = 20
+			e.BruttoCents /= 2
+		}
+		employees = append(employees, e)
+	}
+	return employees
+}
+
+// documentTemplate describes one kind of databox document
+type documentTemplate struct {
+	docType, subtype, analysisType, priority string
+	title, sender                            string
+	elda                                     bool
+	deadlineDays                             int // 0: no deadline
+	deadlineType, action                     string
+	text                                     string // %[1]s date, %[2]s amount, %[3]s deadline
+	summary                                  string
+	keyPoints                                []string
+}
+
+var documentTemplates = []documentTemplate{
+	{
+		docType: "bescheid", subtype: "steuerbescheid", analysisType: "bescheid", priority: "high",
+		title: "Einkommensteuerbescheid %d", sender: "Finanzamt Österreich",
+		deadlineDays: 30, deadlineType: "payment", action: "Nachzahlung Einkommensteuer überweisen",
+		text:      "Bescheid vom %[1]s\nDie Einkommensteuer wird festgesetzt. Es ergibt sich eine Nachforderung von EUR %[2]s.\nFälligkeit: %[3]s. Gegen diesen Bescheid ist binnen eines Monats ab Zustellung die Beschwerde zulässig.",
+		summary:   "Einkommensteuerbescheid mit Nachforderung, fällig in einem Monat.",
+		keyPoints: []string{"Nachforderung Einkommensteuer", "Beschwerdefrist ein Monat ab Zustellung"},
+	},
+	{
+		docType: "ersuchen", subtype: "ergaenzungsersuchen", analysisType: "ersuchen", priority: "high",
+		title: "Ergänzungsersuchen Umsatzsteuer %d", sender: "Finanzamt Österreich",
+		deadlineDays: 28, deadlineType: "response", action: "Unterlagen für Ergänzungsersuchen einreichen",
+		text:      "Ergänzungsersuchen vom %[1]s\nIm Rahmen der Veranlagung werden Sie ersucht, Belege zu Vorsteuern in Höhe von EUR %[2]s vorzulegen.\nFrist zur Beantwortung: %[3]s.",
+		summary:   "Das Finanzamt ersucht um Belege zu geltend gemachten Vorsteuern.",
+		keyPoints: []string{"Vorsteuerbelege vorlegen", "Antwortfrist vier Wochen"},
+	},
+	{
+		docType: "mitteilung", subtype: "buchungsmitteilung", analysisType: "info", priority: "low",
+		title: "Buchungsmitteilung %d", sender: "Finanzamt Österreich",
+		text:      "Buchungsmitteilung vom %[1]s\nAuf Ihrem Abgabenkonto wurde eine Zahlung von EUR %[2]s verbucht. Es besteht kein Rückstand.",
+		summary:   "Zahlungseingang am Abgabenkonto verbucht, kein Rückstand.",
+		keyPoints: []string{"Zahlung verbucht", "Kein Handlungsbedarf"},
+	},
+	{
+		docType: "mahnung", subtype: "mahnung", analysisType: "bescheid", priority: "critical",
+		title: "Mahnung Umsatzsteuervorauszahlung %d", sender: "Finanzamt Österreich",
+		deadlineDays: 14, deadlineType: "payment", action: "Rückstand Umsatzsteuer begleichen",
+		text:      "Mahnung vom %[1]s\nDer Rückstand von EUR %[2]s ist bis %[3]s zu entrichten, andernfalls wird die Einbringung eingeleitet.",
+		summary:   "Mahnung wegen offener Umsatzsteuervorauszahlung.",
+		keyPoints: []string{"Rückstand Umsatzsteuer", "Zahlung binnen zwei Wochen"},
+	},
+	{
+		docType: "sonstige", subtype: "beitragsvorschreibung", analysisType: "rechnung", priority: "medium",
+		title: "Beitragsvorschreibung %d", sender: "Österreichische Gesundheitskasse", elda: true,
+		deadlineDays: 15, deadlineType: "payment", action: "SV-Beiträge überweisen",
+		text:      "Beitragsvorschreibung vom %[1]s\nFür den Beitragszeitraum werden Beiträge von EUR %[2]s vorgeschrieben, fällig am %[3]s.",
+		summary:   "Vorschreibung der Sozialversicherungsbeiträge des Vormonats.",
+		keyPoints: []string{"SV-Beiträge Vormonat", "Fällig am 15. des Folgemonats"},
+	},
+	{
+		docType: "mitteilung", subtype: "information", analysisType: "info", priority: "low",
+		title: "Information zur Arbeitnehmerveranlagung %d", sender: "Finanzamt Österreich",
+		text:      "Information vom %[1]s\nDie Lohnzettel Ihrer Dienstnehmer wurden übermittelt. Summe der Bezüge EUR %[2]s.",
+		summary:   "Bestätigung der übermittelten Lohnzettel.",
+		keyPoints: []string{"Lohnzettel übermittelt"},
+	},
+}
+
+func generateDocuments(f *fixtures.Faker, n int, today time.Time) []Document {
+	docs := make([]Document, 0, n)
+	for i := 0; i < n; i++ {
+		t := documentTemplates[i%len(documentTemplates)]
+		received := today.AddDate(0, 0, -(i*180/max(n, 1) + f.Intn(5)))
+		amount := formatEUR(f.Between(150, 9500) * 100)
+
+		doc := Document{
+			ExternalID:      fmt.Sprintf("DEMO-%s-%04d", received.Format("20060102"), i+1),
+			Type:            t.docType,
+			Title:           fmt.Sprintf(t.title, received.Year()),
+			Sender:          t.sender,
+			ELDA:            t.elda,
+			ReceivedAt:      received.Add(time.Duration(8+f.Intn(9)) * time.Hour),
+			Status:          "read",
+			DocumentType:    t.analysisType,
+			DocumentSubtype: t.subtype,
+			Priority:        t.priority,
+			Confidence:      float64(80+f.Intn(20)) / 100,
+			Summary:         t.summary,
+			KeyPoints:       t.keyPoints,
+		}
+		if today.Sub(received) < 21*24*time.Hour {
+			doc.Status = "new"
+		}
+
+		deadlineText := "-"
+		if t.deadlineDays > 0 {
+			deadline := received.AddDate(0, 0, t.deadlineDays)
+			doc.Deadline = &deadline
+			doc.DeadlineType = t.deadlineType
+			doc.Action = t.action
+			doc.Done = deadline.Before(today)
+			deadlineText = deadline.Format("02.01.2006")
+		}
+		doc.Text = fmt.Sprintf(t.text, received.Format("02.01.2006"), amount, deadlineText)
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// product is an invoice line template per branch
+type product struct {
+	description string
+	unit        string
+	minCents    int64
+	maxCents    int64
+}
+
+var products = []product{
+	{"Arbeitsstunde Fachkraft", "HUR", 6500, 9800},
+	{"Arbeitsstunde Projektleitung", "HUR", 9500, 14500},
+	{"Anfahrtspauschale", "C62", 4500, 8900},
+	{"Material lt. Aufstellung", "C62", 12000, 185000},
+	{"Wartungspauschale", "MON", 9900, 49900},
+	{"Beratung", "HUR", 11000, 16000},
+}
+
+func generateInvoices(f *fixtures.Faker, supplier fixtures.Company, n int, today time.Time) []Invoice {
+	customers := make([]fixtures.Company, 6)
+	for i := range customers {
+		customers[i] = f.Company()
+	}
+
+	invoices := make([]Invoice, 0, n)
+	sequence := map[int]int{}
+	for i := 0; i < n; i++ {
+		date := today.AddDate(0, 0, -((n-1-i)*365/max(n, 1) + f.Intn(4)))
+		sequence[date.Year()]++
+		inv := Invoice{
+			Number:   fmt.Sprintf("RE-%d-%04d", date.Year(), sequence[date.Year()]),
+			Date:     date,
+			Due:      date.AddDate(0, 0, 14),
+			Customer: fixtures.Pick(f, customers),
+			Status:   "sent",
+		}
+		switch age := today.Sub(date); {
+		case i >= n-2:
+			inv.Status = "draft"
+		case age < 45*24*time.Hour:
+			inv.Status = "finalized"
+		}
+
+		for p := 0; p < 1+f.Intn(3); p++ {
+			prod := fixtures.Pick(f, products)
+			item := InvoiceItem{
+				Description:    prod.description,
+				Quantity:       float64(1 + f.Intn(8)),
+				Unit:           prod.unit,
+				UnitPriceCents: f.Between(prod.minCents, prod.maxCents),
+				TaxRate:        20,
+			}
+			item.Net = int64(math.Round(item.Quantity * float64(item.UnitPriceCents)))
+			item.Tax = int64(math.Round(float64(item.Net) * item.TaxRate / 100))
+			item.Gross = item.Net + item.Tax
+			inv.Items = append(inv.Items, item)
+			inv.Net += item.Net
+			inv.Tax += item.Tax
+			inv.Gross += item.Gross
+		}
+		invoices = append(invoices, inv)
+	}
+	return invoices
+}
+
+var applicationStatuses = []string{"approved", "submitted", "drafting", "rejected", "in_review", "planned"}
+
+func generateApplications(f *fixtures.Faker, n int, today time.Time) []Application {
+	apps := make([]Application, 0, n)
+	for i := 0; i < n; i++ {
+		a := Application{
+			Status:        applicationStatuses[i%len(applicationStatuses)],
+			AppliedAmount: int(f.Between(5, 150)) * 1000,
+		}
+		if a.Status != "planned" && a.Status != "drafting" {
+			applied := today.AddDate(0, -1-f.Intn(8), -f.Intn(28))
+			a.AppliedAt = &applied
+		}
+		switch a.Status {
+		case "approved":
+			approved := a.AppliedAmount * (60 + f.Intn(41)) / 100
+			decided := a.AppliedAt.AddDate(0, 2, 0)
+			a.ApprovedAmount = &approved
+			a.DecisionDate = &decided
+			a.Notes = "Förderzusage erhalten, Auszahlung nach Endabrechnung."
+		case "rejected":
+			decided := a.AppliedAt.AddDate(0, 2, 0)
+			a.DecisionDate = &decided
+			a.Notes = "Projekt bereits vor Antragstellung begonnen."
+		case "planned":
+			a.Notes = "Antrag in Vorbereitung, Angebote einholen."
+		}
+		apps = append(apps, a)
+	}
+	return apps
+}
+
+// slugify converts a name to the tenant slug format
+func slugify(name string) string {
+	replacer := strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss")
+	name = replacer.Replace(strings.ToLower(name))
+
+	var b strings.Builder
+	dash := false
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if len(slug) > 60 {
+		slug = strings.TrimSuffix(slug[:60], "-")
+	}
+	if slug == "" {
+		slug = "tenant"
+	}
+	return slug
+}
+
+// formatEUR formats cents as an Austrian amount, e.g. 1.234,50
+func formatEUR(cents int64) string {
+	euros := fmt.Sprintf("%d", cents/100)
+	var b strings.Builder
+	for i, r := range euros {
+		if i > 0 && (len(euros)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	return fmt.Sprintf("%s,%02d", b.String(), cents%100)
+}
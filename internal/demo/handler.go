@@ -0,0 +1,102 @@
+package demo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// Handler handles demo tenant HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new demo handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers demo tenant routes. They are only registered when
+// DEMO_SEEDING_ENABLED is set, which must never be the case in production.
+// A tenant admin sees and removes only the demo tenants it requested.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/demo-tenants", requireAuth(requireAdmin(http.HandlerFunc(h.List))))
+	router.Handle("POST /api/v1/demo-tenants", requireAuth(requireAdmin(http.HandlerFunc(h.Seed))))
+	router.Handle("DELETE /api/v1/demo-tenants/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Teardown))))
+}
+
+// List handles GET /api/v1/demo-tenants
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	tenants, err := h.service.List(r.Context(), &tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"tenants": tenants})
+}
+
+// Seed handles POST /api/v1/demo-tenants
+func (h *Handler) Seed(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	var opts Options
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			api.BadRequest(w, "invalid request body")
+			return
+		}
+	}
+	if err := opts.Normalize(); err != nil {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
+	result, err := h.service.Seed(r.Context(), opts, "api", &tenantID)
+	if err != nil {
+		if errors.Is(err, ErrTenantExists) {
+			api.Conflict(w, err.Error())
+			return
+		}
+		api.InternalError(w)
+		return
+	}
+	api.JSONResponse(w, http.StatusCreated, result)
+}
+
+// Teardown handles DELETE /api/v1/demo-tenants/{id}
+func (h *Handler) Teardown(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid tenant ID")
+		return
+	}
+
+	if err := h.service.Teardown(r.Context(), id, &tenantID); err != nil {
+		if errors.Is(err, ErrNotDemoTenant) {
+			api.NotFound(w, err.Error())
+			return
+		}
+		api.InternalError(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
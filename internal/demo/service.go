@@ -0,0 +1,623 @@
+// Package demo generates demo tenants with representative Austrian data for
+// sales presentations and removes them again. All data is derived from a
+// seed, so a demo can be reproduced exactly.
+package demo
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/account"
+	"austrian-business-infrastructure/internal/account/types"
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/internal/fixtures"
+	"austrian-business-infrastructure/internal/refdata"
+	"austrian-business-infrastructure/pkg/crypto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Errors
+var (
+	ErrTenantExists  = errors.New("demo tenant with this seed already exists")
+	ErrNotDemoTenant = errors.New("demo tenant not found")
+)
+
+// settingsKey marks demo tenants in tenants.settings
+const settingsKey = "demo"
+
+// Result describes a seeded demo tenant
+type Result struct {
+	TenantID      uuid.UUID `json:"tenant_id"`
+	Name          string    `json:"name"`
+	Slug          string    `json:"slug"`
+	Seed          int64     `json:"seed"`
+	OwnerEmail    string    `json:"owner_email"`
+	OwnerPassword string    `json:"owner_password,omitempty"` // only when generated
+	Counts        Counts    `json:"counts"`
+	Notes         []string  `json:"notes,omitempty"`
+}
+
+// Counts are the number of records created per kind
+type Counts struct {
+	Employees    int `json:"employees"`
+	Documents    int `json:"documents"`
+	Analyses     int `json:"analyses"`
+	ActionItems  int `json:"action_items"`
+	Invoices     int `json:"invoices"`
+	Applications int `json:"applications"`
+}
+
+// Tenant is a demo tenant as listed
+type Tenant struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Slug      string     `json:"slug"`
+	Seed      int64      `json:"seed"`
+	CreatedBy string     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	CreatorID *uuid.UUID `json:"-"`
+}
+
+// marker is stored in tenants.settings under settingsKey
+type marker struct {
+	Seed          int64      `json:"seed"`
+	CreatedBy     string     `json:"created_by"` // cli or api
+	CreatorTenant *uuid.UUID `json:"creator_tenant_id,omitempty"`
+}
+
+// Service seeds and removes demo tenants
+type Service struct {
+	db        *pgxpool.Pool
+	encryptor *account.Encryptor
+	storage   document.Storage
+}
+
+// NewService creates a demo service. Credentials of the demo accounts are
+// encrypted with the platform key so the account pages work as usual;
+// storage may be nil, documents then have no file.
+func NewService(db *pgxpool.Pool, encryptionKey []byte, storage document.Storage) (*Service, error) {
+	enc, err := account.NewEncryptor(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{db: db, encryptor: enc, storage: storage}, nil
+}
+
+// Seed creates a demo tenant. createdBy is "cli" or "api"; creatorTenant is
+// the tenant whose admin requested it through the API.
+func (s *Service) Seed(ctx context.Context, opts Options, createdBy string, creatorTenant *uuid.UUID) (*Result, error) {
+	if err := opts.Normalize(); err != nil {
+		return nil, err
+	}
+	data := Generate(opts)
+
+	result := &Result{
+		TenantID:   uuid.New(),
+		Name:       data.Company.Name,
+		Slug:       data.Slug,
+		Seed:       opts.Seed,
+		OwnerEmail: data.OwnerEmail,
+	}
+
+	password := opts.OwnerPassword
+	if password == "" {
+		password = generatePassword()
+		result.OwnerPassword = password
+	}
+	passwordHash, err := crypto.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	foCreds, foIV, err := s.encryptor.EncryptJSON(&types.FinanzOnlineCredentials{
+		TID: data.TID, BenID: data.BenID, PIN: "demo",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encrypt credentials: %w", err)
+	}
+	eldaCreds, eldaIV, err := s.encryptor.EncryptJSON(&types.ELDACredentials{
+		DienstgeberNr: data.Dienstgebernummer, PIN: "demo", CertificatePath: "demo",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encrypt credentials: %w", err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	settings := map[string]interface{}{
+		settingsKey: marker{Seed: opts.Seed, CreatedBy: createdBy, CreatorTenant: creatorTenant},
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO tenants (id, name, slug, settings) VALUES ($1, $2, $3, $4)
+	`, result.TenantID, result.Name, result.Slug, settings)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrTenantExists
+		}
+		return nil, fmt.Errorf("failed to create tenant: %w", err)
+	}
+
+	ownerID := uuid.New()
+	_, err = tx.Exec(ctx, `
+		INSERT INTO users (id, tenant_id, email, password_hash, name, role, email_verified, email_verified_at, is_active)
+		VALUES ($1, $2, $3, $4, $5, 'owner', true, NOW(), true)
+	`, ownerID, result.TenantID, data.OwnerEmail, passwordHash, data.Owner.Vorname+" "+data.Owner.Familienname)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create owner: %w", err)
+	}
+
+	// Accounts never sync, the credentials are fake
+	var foAccountID, eldaAccountID, eldaID uuid.UUID
+	accountQuery := `
+		INSERT INTO accounts (tenant_id, name, type, credentials, credentials_iv, status, last_verified_at, sync_interval, auto_sync_enabled)
+		VALUES ($1, $2, $3, $4, $5, 'verified', NOW(), 'disabled', false)
+		RETURNING id
+	`
+	if err := tx.QueryRow(ctx, accountQuery, result.TenantID, "FinanzOnline "+data.Company.Name,
+		account.AccountTypeFinanzOnline, foCreds, foIV).Scan(&foAccountID); err != nil {
+		return nil, fmt.Errorf("failed to create FinanzOnline account: %w", err)
+	}
+	if err := tx.QueryRow(ctx, accountQuery, result.TenantID, "ELDA "+data.Company.Name,
+		account.AccountTypeELDA, eldaCreds, eldaIV).Scan(&eldaAccountID); err != nil {
+		return nil, fmt.Errorf("failed to create ELDA account: %w", err)
+	}
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO elda_accounts (account_id, dienstgeber_nummer, beitragskontonummer, status)
+		VALUES ($1, $2, $3, 'active')
+		RETURNING id
+	`, eldaAccountID, data.Dienstgebernummer, data.Beitragskontonummer).Scan(&eldaID); err != nil {
+		return nil, fmt.Errorf("failed to create ELDA account: %w", err)
+	}
+
+	if err := seedEmployees(ctx, tx, eldaID, ownerID, data.Employees, opts.Now); err != nil {
+		return nil, err
+	}
+	result.Counts.Employees = len(data.Employees)
+
+	// Document files are written before the commit and removed again on failure
+	var stored []string
+	committed := false
+	defer func() {
+		if !committed {
+			for _, path := range stored {
+				_ = s.storage.Delete(context.WithoutCancel(ctx), path)
+			}
+		}
+	}()
+
+	for _, doc := range data.Documents {
+		accountID := foAccountID
+		if doc.ELDA {
+			accountID = eldaAccountID
+		}
+		path, size, hash, err := s.storeDocument(ctx, result.TenantID, accountID, doc)
+		if err != nil {
+			return nil, err
+		}
+		if path != "" {
+			stored = append(stored, path)
+		}
+		actions, err := seedDocument(ctx, tx, result.TenantID, accountID, ownerID, doc, path, size, hash)
+		if err != nil {
+			return nil, err
+		}
+		result.Counts.Documents++
+		result.Counts.Analyses++
+		result.Counts.ActionItems += actions
+	}
+
+	for _, inv := range data.Invoices {
+		if err := seedInvoice(ctx, tx, result.TenantID, ownerID, data.Company, inv); err != nil {
+			return nil, err
+		}
+	}
+	result.Counts.Invoices = len(data.Invoices)
+
+	applications, err := seedApplications(ctx, tx, result.TenantID, foAccountID, ownerID, data, len(data.Employees))
+	if err != nil {
+		return nil, err
+	}
+	result.Counts.Applications = applications
+	if applications < len(data.Applications) {
+		result.Notes = append(result.Notes, "Förderungskatalog enthält keine aktiven Programme, Förderanträge wurden übersprungen")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+	return result, nil
+}
+
+// storeDocument writes the document text to storage
+func (s *Service) storeDocument(ctx context.Context, tenantID, accountID uuid.UUID, doc Document) (string, int, string, error) {
+	content := []byte(doc.Text)
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	if s.storage == nil {
+		return "", len(content), hash, nil
+	}
+	info, err := s.storage.Store(ctx, tenantID.String(), accountID.String(), doc.ExternalID+".txt", bytes.NewReader(content), "text/plain")
+	if err != nil {
+		return "", 0, "", fmt.Errorf("store document: %w", err)
+	}
+	return info.Path, int(info.Size), hash, nil
+}
+
+func seedEmployees(ctx context.Context, tx pgx.Tx, eldaID, ownerID uuid.UUID, employees []Employee, now time.Time) error {
+	for i, e := range employees {
+		payload, _ := json.Marshal(map[string]interface{}{
+			"sv_nummer":      e.SVNummer,
+			"vorname":        e.Vorname,
+			"nachname":       e.Familienname,
+			"geburtsdatum":   e.Geburtsdatum.Format("2006-01-02"),
+			"geschlecht":     e.Geschlecht,
+			"eintrittsdatum": e.Eintritt.Format("2006-01-02"),
+			"beitragsgruppe": e.Beitragsgruppe,
+			"wochenstunden":  e.Wochenstunden,
+			"brutto":         refdata.EUR(e.BruttoCents),
+		})
+		submitted := e.Eintritt.AddDate(0, 0, -1)
+		_, err := tx.Exec(ctx, `
+			INSERT INTO elda_meldungen (
+				elda_account_id, meldung_type, sv_nummer, familienname, vorname,
+				payload_json, status, protokollnummer, submitted_at, created_by
+			) VALUES ($1, 'anmeldung', $2, $3, $4, $5, 'accepted', $6, $7, $8)
+		`, eldaID, e.SVNummer, e.Familienname, e.Vorname, payload,
+			fmt.Sprintf("DEMO%s%04d", submitted.Format("20060102"), i+1), submitted, ownerID)
+		if err != nil {
+			return fmt.Errorf("failed to create Anmeldung: %w", err)
+		}
+	}
+
+	// mBGM of the previous month
+	period := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	hbgl := refdata.For(period).Hoechstbeitragsgrundlage
+	var active []Employee
+	var total int64
+	for _, e := range employees {
+		if e.Eintritt.After(period) {
+			continue
+		}
+		active = append(active, e)
+		total += min(e.BruttoCents, hbgl)
+	}
+	if len(active) == 0 {
+		return nil
+	}
+
+	var mbgmID uuid.UUID
+	submitted := time.Date(now.Year(), now.Month(), 1, 9, 0, 0, 0, time.UTC).AddDate(0, 0, 10)
+	err := tx.QueryRow(ctx, `
+		INSERT INTO mbgm (
+			elda_account_id, year, month, status, protokollnummer,
+			total_dienstnehmer, total_beitragsgrundlage, submitted_at, created_by
+		) VALUES ($1, $2, $3, 'accepted', $4, $5, $6, $7, $8)
+		RETURNING id
+	`, eldaID, period.Year(), int(period.Month()), "DEMO-MBGM-"+period.Format("200601"),
+		len(active), refdata.EUR(total), submitted, ownerID).Scan(&mbgmID)
+	if err != nil {
+		return fmt.Errorf("failed to create mBGM: %w", err)
+	}
+	for i, e := range active {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO mbgm_positionen (
+				mbgm_id, sv_nummer, familienname, vorname, geburtsdatum,
+				beitragsgruppe, beitragsgrundlage, wochenstunden, position_index
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, mbgmID, e.SVNummer, e.Familienname, e.Vorname, e.Geburtsdatum,
+			e.Beitragsgruppe, refdata.EUR(min(e.BruttoCents, hbgl)), e.Wochenstunden, i)
+		if err != nil {
+			return fmt.Errorf("failed to create mBGM position: %w", err)
+		}
+	}
+	return nil
+}
+
+func seedDocument(ctx context.Context, tx pgx.Tx, tenantID, accountID, ownerID uuid.UUID, doc Document, path string, size int, hash string) (int, error) {
+	var documentID, analysisID uuid.UUID
+	err := tx.QueryRow(ctx, `
+		INSERT INTO documents (
+			tenant_id, account_id, external_id, type, title, sender, received_at,
+			content_hash, storage_path, file_size, mime_type, status, deadline, metadata
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, 'text/plain', $11, $12, $13)
+		RETURNING id
+	`, tenantID, accountID, doc.ExternalID, doc.Type, doc.Title, doc.Sender, doc.ReceivedAt,
+		hash, path, size, doc.Status, doc.Deadline, map[string]interface{}{"demo": true}).Scan(&documentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create document: %w", err)
+	}
+
+	keyPoints, _ := json.Marshal(doc.KeyPoints)
+	err = tx.QueryRow(ctx, `
+		INSERT INTO document_analyses (
+			document_id, tenant_id, status, document_type, document_subtype,
+			classification_confidence, priority, ocr_provider, extracted_text,
+			summary, key_points, processing_time_ms, token_count, cost_cents
+		) VALUES ($1, $2, 'completed', $3, $4, $5, $6, 'none', $7, $8, $9, $10, $11, 1)
+		RETURNING id
+	`, documentID, tenantID, doc.DocumentType, doc.DocumentSubtype, doc.Confidence, doc.Priority,
+		doc.Text, doc.Summary, keyPoints, 1200+len(doc.Text), 400+len(doc.Text)/3).Scan(&analysisID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create analysis: %w", err)
+	}
+
+	if doc.Deadline == nil {
+		return 0, nil
+	}
+
+	var deadlineID, actionID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO extracted_deadlines (
+			analysis_id, document_id, tenant_id, deadline_type, deadline_date,
+			calculated_from, source_text, confidence
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, analysisID, documentID, tenantID, doc.DeadlineType, *doc.Deadline,
+		doc.ReceivedAt, doc.Text, doc.Confidence).Scan(&deadlineID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create deadline: %w", err)
+	}
+
+	status := "pending"
+	var completedAt *time.Time
+	if doc.Done {
+		status = "completed"
+		completedAt = doc.Deadline
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO action_items (
+			tenant_id, document_id, analysis_id, deadline_id, title, description,
+			action_type, priority, status, due_date, completed_at, assigned_to, source, ai_confidence
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, 'ai', $13)
+		RETURNING id
+	`, tenantID, documentID, analysisID, deadlineID, doc.Action, doc.Summary,
+		actionType(doc.DeadlineType), doc.Priority, status, *doc.Deadline, completedAt,
+		ownerID, doc.Confidence).Scan(&actionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create action item: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE extracted_deadlines SET action_item_id = $1 WHERE id = $2`, actionID, deadlineID); err != nil {
+		return 0, fmt.Errorf("failed to link action item: %w", err)
+	}
+	return 1, nil
+}
+
+// actionType maps a deadline type to the action item type
+func actionType(deadlineType string) string {
+	switch deadlineType {
+	case "payment":
+		return "pay"
+	case "response":
+		return "respond"
+	default:
+		return "review"
+	}
+}
+
+func seedInvoice(ctx context.Context, tx pgx.Tx, tenantID, ownerID uuid.UUID, supplier fixtures.Company, inv Invoice) error {
+	var invoiceID uuid.UUID
+	err := tx.QueryRow(ctx, `
+		INSERT INTO invoices (
+			tenant_id, invoice_number, invoice_date, due_date,
+			supplier_name, supplier_street, supplier_city, supplier_postal_code, supplier_uid, supplier_iban,
+			customer_name, customer_street, customer_city, customer_postal_code, customer_uid,
+			net_amount_cents, tax_amount_cents, gross_amount_cents,
+			payment_terms, payment_reference, validation_status, status, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18,
+			'Zahlbar binnen 14 Tagen ohne Abzug', $2, $19, $20, $21)
+		RETURNING id
+	`, tenantID, inv.Number, inv.Date, inv.Due,
+		supplier.Name, supplier.Address.Street, supplier.Address.City, supplier.Address.PostalCode, supplier.UID, supplier.IBAN,
+		inv.Customer.Name, inv.Customer.Address.Street, inv.Customer.Address.City, inv.Customer.Address.PostalCode, inv.Customer.UID,
+		inv.Net, inv.Tax, inv.Gross, validationStatus(inv.Status), inv.Status, ownerID).Scan(&invoiceID)
+	if err != nil {
+		return fmt.Errorf("failed to create invoice %s: %w", inv.Number, err)
+	}
+
+	for i, item := range inv.Items {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO invoice_items (
+				invoice_id, position, description, quantity, unit, unit_price_cents,
+				net_amount_cents, tax_rate, tax_amount_cents, gross_amount_cents
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, invoiceID, i+1, item.Description, item.Quantity, item.Unit, item.UnitPriceCents,
+			item.Net, item.TaxRate, item.Tax, item.Gross)
+		if err != nil {
+			return fmt.Errorf("failed to create invoice item: %w", err)
+		}
+	}
+	return nil
+}
+
+func validationStatus(status string) string {
+	if status == "draft" {
+		return "pending"
+	}
+	return "valid"
+}
+
+// seedApplications creates a company profile and Förderanträge for active
+// programs of the catalog. The catalog is shared by all tenants and not
+// touched; if it is empty no applications are created.
+func seedApplications(ctx context.Context, tx pgx.Tx, tenantID, accountID, ownerID uuid.UUID, data *Dataset, employees int) (int, error) {
+	var profileID uuid.UUID
+	legalForm := data.Company.LegalForm
+	if legalForm == "e.U." {
+		legalForm = "EPU"
+	}
+	err := tx.QueryRow(ctx, `
+		INSERT INTO unternehmensprofile (
+			tenant_id, account_id, name, legal_form, founded_year, state,
+			employees_count, annual_revenue, industry, is_kmu, status, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, true, 'complete', $10)
+		RETURNING id
+	`, tenantID, accountID, data.Company.Name, legalForm, 2008, data.Company.Address.State,
+		employees, sumGross(data.Invoices)/100, data.Company.Branch, ownerID).Scan(&profileID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create company profile: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM foerderungen WHERE status = 'active' ORDER BY name LIMIT $1
+	`, len(data.Applications))
+	if err != nil {
+		return 0, fmt.Errorf("failed to query Förderungen: %w", err)
+	}
+	programs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return 0, fmt.Errorf("failed to query Förderungen: %w", err)
+	}
+
+	for i, program := range programs {
+		a := data.Applications[i]
+		var number *string
+		if a.AppliedAt != nil {
+			n := fmt.Sprintf("DEMO-%d-%03d", a.AppliedAt.Year(), i+1)
+			number = &n
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO foerderungs_antraege (
+				tenant_id, account_id, foerderung_id, profile_id, application_number,
+				applied_at, applied_amount, status, status_changed_at,
+				approved_amount, rejection_reason, decision_date, notes, created_by
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), $9, $10, $11, $12, $13)
+		`, tenantID, accountID, program, profileID, number,
+			a.AppliedAt, a.AppliedAmount, a.Status,
+			a.ApprovedAmount, rejectionReason(a), a.DecisionDate, a.Notes, ownerID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create Förderantrag: %w", err)
+		}
+	}
+	return len(programs), nil
+}
+
+func rejectionReason(a Application) *string {
+	if a.Status != "rejected" {
+		return nil
+	}
+	return &a.Notes
+}
+
+func sumGross(invoices []Invoice) int64 {
+	var sum int64
+	for _, inv := range invoices {
+		sum += inv.Gross
+	}
+	return sum
+}
+
+// List returns demo tenants. If creatorTenant is set only the demo tenants
+// requested by that tenant are returned.
+func (s *Service) List(ctx context.Context, creatorTenant *uuid.UUID) ([]*Tenant, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, name, slug, settings->$1, created_at
+		FROM tenants
+		WHERE settings ? $1
+		ORDER BY created_at DESC
+	`, settingsKey)
+	if err != nil {
+		return nil, fmt.Errorf("list demo tenants: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []*Tenant{}
+	for rows.Next() {
+		t := &Tenant{}
+		var m marker
+		if err := rows.Scan(&t.ID, &t.Name, &t.Slug, &m, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan demo tenant: %w", err)
+		}
+		t.Seed, t.CreatedBy, t.CreatorID = m.Seed, m.CreatedBy, m.CreatorTenant
+		if creatorTenant != nil && (t.CreatorID == nil || *t.CreatorID != *creatorTenant) {
+			continue
+		}
+		tenants = append(tenants, t)
+	}
+	return tenants, rows.Err()
+}
+
+// Teardown deletes a demo tenant with all its data and document files. Only
+// tenants created by Seed can be removed; if creatorTenant is set the demo
+// tenant must have been requested by that tenant.
+func (s *Service) Teardown(ctx context.Context, tenantID uuid.UUID, creatorTenant *uuid.UUID) error {
+	var m marker
+	err := s.db.QueryRow(ctx, `
+		SELECT settings->$2 FROM tenants WHERE id = $1 AND settings ? $2
+	`, tenantID, settingsKey).Scan(&m)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotDemoTenant
+	}
+	if err != nil {
+		return fmt.Errorf("get demo tenant: %w", err)
+	}
+	if creatorTenant != nil && (m.CreatorTenant == nil || *m.CreatorTenant != *creatorTenant) {
+		return ErrNotDemoTenant
+	}
+
+	var paths []string
+	if s.storage != nil {
+		rows, err := s.db.Query(ctx, `
+			SELECT storage_path FROM documents WHERE tenant_id = $1 AND storage_path IS NOT NULL
+		`, tenantID)
+		if err != nil {
+			return fmt.Errorf("list demo documents: %w", err)
+		}
+		if paths, err = pgx.CollectRows(rows, pgx.RowTo[string]); err != nil {
+			return fmt.Errorf("list demo documents: %w", err)
+		}
+	}
+
+	result, err := s.db.Exec(ctx, `DELETE FROM tenants WHERE id = $1 AND settings ? $2`, tenantID, settingsKey)
+	if err != nil {
+		return fmt.Errorf("delete demo tenant: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotDemoTenant
+	}
+
+	// Files are removed after the rows, a leftover file is harmless
+	for _, path := range paths {
+		_ = s.storage.Delete(ctx, path)
+	}
+	return nil
+}
+
+// TeardownBySlug resolves a slug and deletes the demo tenant
+func (s *Service) TeardownBySlug(ctx context.Context, slug string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := s.db.QueryRow(ctx, `SELECT id FROM tenants WHERE slug = $1`, strings.ToLower(slug)).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, ErrNotDemoTenant
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("get demo tenant: %w", err)
+	}
+	return id, s.Teardown(ctx, id, nil)
+}
+
+// generatePassword returns a random password meeting the password policy
+func generatePassword() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand: %v", err))
+	}
+	return "Demo-" + base64.RawURLEncoding.EncodeToString(b) + "-7a"
+}
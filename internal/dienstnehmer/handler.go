@@ -0,0 +1,224 @@
+package dienstnehmer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler handles Dienstnehmer HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new Dienstnehmer handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the Dienstnehmer routes. Dienstnehmer are kept
+// by any user of the tenant.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/dienstnehmer", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("POST /api/v1/dienstnehmer", requireAuth(http.HandlerFunc(h.Create)))
+	router.Handle("GET /api/v1/dienstnehmer/sv-nummer-check", requireAuth(http.HandlerFunc(h.CheckSVNummer)))
+	router.Handle("GET /api/v1/dienstnehmer/{id}", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("PUT /api/v1/dienstnehmer/{id}", requireAuth(http.HandlerFunc(h.Update)))
+	router.Handle("DELETE /api/v1/dienstnehmer/{id}", requireAuth(http.HandlerFunc(h.Delete)))
+	router.Handle("GET /api/v1/dienstnehmer/{id}/beschaeftigungen", requireAuth(http.HandlerFunc(h.History)))
+}
+
+// List handles GET /api/v1/dienstnehmer
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	filter := ListFilter{TenantID: tenantID, Search: r.URL.Query().Get("search"), Limit: 50}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	list, total, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if list == nil {
+		list = []*Dienstnehmer{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  list,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// Create handles POST /api/v1/dienstnehmer
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var input Input
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	d, err := h.service.Create(r.Context(), tenantID, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, d)
+}
+
+// CheckSVNummer handles GET /api/v1/dienstnehmer/sv-nummer-check
+func (h *Handler) CheckSVNummer(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("sv_nummer") == "" {
+		api.BadRequest(w, "sv_nummer is required")
+		return
+	}
+	var geburtsdatum *time.Time
+	if v := query.Get("geburtsdatum"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			api.BadRequest(w, ErrInvalidGeburtsdatum.Error())
+			return
+		}
+		geburtsdatum = &t
+	}
+
+	check, err := h.service.Check(r.Context(), tenantID, query.Get("sv_nummer"), geburtsdatum)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, check)
+}
+
+// Get handles GET /api/v1/dienstnehmer/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	d, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, d)
+}
+
+// Update handles PUT /api/v1/dienstnehmer/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var input Input
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	d, err := h.service.Update(r.Context(), tenantID, id, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, d)
+}
+
+// Delete handles DELETE /api/v1/dienstnehmer/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), tenantID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// History handles GET /api/v1/dienstnehmer/{id}/beschaeftigungen
+func (h *Handler) History(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	employments, err := h.service.History(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if employments == nil {
+		employments = []*Beschaeftigung{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"items": employments})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrDienstnehmerNotFound):
+		api.NotFound(w, "Dienstnehmer not found")
+	case errors.Is(err, ErrDuplicateSVNummer):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, ErrNameRequired), errors.Is(err, ErrInvalidSVNummer), errors.Is(err, ErrInvalidGeburtsdatum),
+		errors.Is(err, ErrInvalidGeschlecht):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	default:
+		api.InternalError(w)
+	}
+}
@@ -0,0 +1,209 @@
+package dienstnehmer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles Dienstnehmer database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new Dienstnehmer repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const dienstnehmerColumns = `id, tenant_id, sv_nummer, vorname, nachname, geburtsdatum, geschlecht,
+	adresse, bankverbindung, notes, created_at, updated_at`
+
+func scanDienstnehmer(row pgx.Row) (*Dienstnehmer, error) {
+	var d Dienstnehmer
+	var adresseJSON, bankJSON []byte
+	if err := row.Scan(&d.ID, &d.TenantID, &d.SVNummer, &d.Vorname, &d.Nachname, &d.Geburtsdatum,
+		&d.Geschlecht, &adresseJSON, &bankJSON, &d.Notes, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if len(adresseJSON) > 0 {
+		if err := json.Unmarshal(adresseJSON, &d.Adresse); err != nil {
+			return nil, fmt.Errorf("invalid adresse: %w", err)
+		}
+	}
+	if len(bankJSON) > 0 {
+		if err := json.Unmarshal(bankJSON, &d.Bankverbindung); err != nil {
+			return nil, fmt.Errorf("invalid bankverbindung: %w", err)
+		}
+	}
+	return &d, nil
+}
+
+// jsonColumns encodes the address and bank account, NULL if not set
+func jsonColumns(d *Dienstnehmer) ([]byte, []byte, error) {
+	var adresseJSON, bankJSON []byte
+	var err error
+	if d.Adresse != nil {
+		if adresseJSON, err = json.Marshal(d.Adresse); err != nil {
+			return nil, nil, err
+		}
+	}
+	if d.Bankverbindung != nil {
+		if bankJSON, err = json.Marshal(d.Bankverbindung); err != nil {
+			return nil, nil, err
+		}
+	}
+	return adresseJSON, bankJSON, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// Create inserts a Dienstnehmer
+func (r *Repository) Create(ctx context.Context, d *Dienstnehmer) error {
+	adresseJSON, bankJSON, err := jsonColumns(d)
+	if err != nil {
+		return fmt.Errorf("failed to create Dienstnehmer: %w", err)
+	}
+	saved, err := scanDienstnehmer(r.db.QueryRow(ctx, `
+		INSERT INTO dienstnehmer (tenant_id, sv_nummer, vorname, nachname, geburtsdatum, geschlecht, adresse, bankverbindung, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING `+dienstnehmerColumns,
+		d.TenantID, d.SVNummer, d.Vorname, d.Nachname, d.Geburtsdatum, d.Geschlecht, adresseJSON, bankJSON, d.Notes))
+	if isUniqueViolation(err) {
+		return ErrDuplicateSVNummer
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create Dienstnehmer: %w", err)
+	}
+	*d = *saved
+	return nil
+}
+
+// Update replaces the master data of a Dienstnehmer
+func (r *Repository) Update(ctx context.Context, d *Dienstnehmer) error {
+	adresseJSON, bankJSON, err := jsonColumns(d)
+	if err != nil {
+		return fmt.Errorf("failed to update Dienstnehmer: %w", err)
+	}
+	saved, err := scanDienstnehmer(r.db.QueryRow(ctx, `
+		UPDATE dienstnehmer SET
+			sv_nummer = $3, vorname = $4, nachname = $5, geburtsdatum = $6, geschlecht = $7,
+			adresse = $8, bankverbindung = $9, notes = $10, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2
+		RETURNING `+dienstnehmerColumns,
+		d.ID, d.TenantID, d.SVNummer, d.Vorname, d.Nachname, d.Geburtsdatum, d.Geschlecht, adresseJSON, bankJSON, d.Notes))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrDienstnehmerNotFound
+	}
+	if isUniqueViolation(err) {
+		return ErrDuplicateSVNummer
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update Dienstnehmer: %w", err)
+	}
+	*d = *saved
+	return nil
+}
+
+// Get returns a Dienstnehmer of a tenant
+func (r *Repository) Get(ctx context.Context, tenantID, id uuid.UUID) (*Dienstnehmer, error) {
+	d, err := scanDienstnehmer(r.db.QueryRow(ctx,
+		`SELECT `+dienstnehmerColumns+` FROM dienstnehmer WHERE id = $1 AND tenant_id = $2`, id, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDienstnehmerNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Dienstnehmer: %w", err)
+	}
+	return d, nil
+}
+
+// GetBySVNummer returns the Dienstnehmer of a tenant with an SV-Nummer
+func (r *Repository) GetBySVNummer(ctx context.Context, tenantID uuid.UUID, svNummer string) (*Dienstnehmer, error) {
+	d, err := scanDienstnehmer(r.db.QueryRow(ctx,
+		`SELECT `+dienstnehmerColumns+` FROM dienstnehmer WHERE tenant_id = $1 AND sv_nummer = $2`, tenantID, svNummer))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDienstnehmerNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Dienstnehmer: %w", err)
+	}
+	return d, nil
+}
+
+// GetForELDAAccount returns a Dienstnehmer of the tenant the ELDA account
+// belongs to
+func (r *Repository) GetForELDAAccount(ctx context.Context, id, eldaAccountID uuid.UUID) (*Dienstnehmer, error) {
+	d, err := scanDienstnehmer(r.db.QueryRow(ctx, `
+		SELECT `+dienstnehmerColumns+` FROM dienstnehmer
+		WHERE id = $1 AND tenant_id = (
+			SELECT a.tenant_id FROM elda_accounts ea JOIN accounts a ON a.id = ea.account_id WHERE ea.id = $2)`,
+		id, eldaAccountID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDienstnehmerNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Dienstnehmer: %w", err)
+	}
+	return d, nil
+}
+
+// List returns the Dienstnehmer of a tenant by name
+func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Dienstnehmer, int, error) {
+	where := "tenant_id = $1"
+	args := []interface{}{filter.TenantID}
+	if filter.Search != "" {
+		args = append(args, "%"+filter.Search+"%")
+		where += fmt.Sprintf(" AND (nachname ILIKE $%d OR vorname ILIKE $%d OR sv_nummer LIKE $%d)", len(args), len(args), len(args))
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM dienstnehmer WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count Dienstnehmer: %w", err)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT %s FROM dienstnehmer
+		WHERE %s
+		ORDER BY nachname, vorname, id
+		LIMIT $%d OFFSET $%d`, dienstnehmerColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list Dienstnehmer: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*Dienstnehmer
+	for rows.Next() {
+		d, err := scanDienstnehmer(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list Dienstnehmer: %w", err)
+		}
+		list = append(list, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list Dienstnehmer: %w", err)
+	}
+	return list, total, nil
+}
+
+// Delete removes a Dienstnehmer; the meldungen created for it are kept
+func (r *Repository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM dienstnehmer WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete Dienstnehmer: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDienstnehmerNotFound
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package dienstnehmer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/eldameldung"
+)
+
+// maxHistoryMeldungen bounds the meldungen the employment history is derived
+// from
+const maxHistoryMeldungen = 1000
+
+// Service handles the Dienstnehmer registry
+type Service struct {
+	repo      *Repository
+	meldungen *eldameldung.Repository
+	now       func() time.Time
+}
+
+// NewService creates a new Dienstnehmer service. The employment history is
+// read from the ELDA meldungen.
+func NewService(repo *Repository, meldungen *eldameldung.Repository) *Service {
+	return &Service{repo: repo, meldungen: meldungen, now: time.Now}
+}
+
+// NormalizeSVNummer returns an SV-Nummer without spaces
+func NormalizeSVNummer(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
+// Create adds a Dienstnehmer
+func (s *Service) Create(ctx context.Context, tenantID uuid.UUID, input *Input) (*Dienstnehmer, error) {
+	d := &Dienstnehmer{TenantID: tenantID}
+	if err := apply(d, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, d); err != nil {
+		return nil, err
+	}
+	s.addWarnings(d)
+	return d, nil
+}
+
+// Update replaces the master data of a Dienstnehmer
+func (s *Service) Update(ctx context.Context, tenantID, id uuid.UUID, input *Input) (*Dienstnehmer, error) {
+	d := &Dienstnehmer{ID: id, TenantID: tenantID}
+	if err := apply(d, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, d); err != nil {
+		return nil, err
+	}
+	s.addWarnings(d)
+	return d, nil
+}
+
+// Get returns a Dienstnehmer of a tenant
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Dienstnehmer, error) {
+	d, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.addWarnings(d)
+	return d, nil
+}
+
+// List returns the Dienstnehmer of a tenant and their total
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]*Dienstnehmer, int, error) {
+	filter.Search = normalizeSearch(filter.Search)
+	list, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, d := range list {
+		s.addWarnings(d)
+	}
+	return list, total, nil
+}
+
+// normalizeSearch drops the spaces of a search for a formatted SV-Nummer
+// (NNNN TTMMJJ), so it matches the stored digits
+func normalizeSearch(search string) string {
+	search = strings.TrimSpace(search)
+	if digits := NormalizeSVNummer(search); digits != search && strings.Trim(digits, "0123456789") == "" {
+		return digits
+	}
+	return search
+}
+
+// Delete removes a Dienstnehmer
+func (s *Service) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+// Check checks the plausibility of an SV-Nummer and looks up the tenant's
+// Dienstnehmer with it
+func (s *Service) Check(ctx context.Context, tenantID uuid.UUID, svNummer string, geburtsdatum *time.Time) (*SVNummerCheck, error) {
+	check := CheckSVNummer(svNummer, geburtsdatum, s.now())
+	if !check.Valid {
+		return check, nil
+	}
+	d, err := s.repo.GetBySVNummer(ctx, tenantID, check.SVNummer)
+	if err != nil && !errors.Is(err, ErrDienstnehmerNotFound) {
+		return nil, err
+	}
+	if d != nil {
+		check.DienstnehmerID = &d.ID
+	}
+	return check, nil
+}
+
+// CheckSVNummer checks the check digit of an SV-Nummer and whether the
+// birth date it encodes is plausible and matches geburtsdatum. Numbers of
+// persons without a known birth date need not encode a real date, so date
+// findings are warnings.
+func CheckSVNummer(svNummer string, geburtsdatum *time.Time, now time.Time) *SVNummerCheck {
+	check := &SVNummerCheck{SVNummer: NormalizeSVNummer(svNummer)}
+	if err := elda.ValidateSVNummer(check.SVNummer); err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	check.Valid = true
+
+	encoded := check.SVNummer[4:10]
+	birth, err := elda.ExtractBirthDateFromSVNummer(check.SVNummer)
+	if err != nil || birth.Format("020106") != encoded {
+		check.Warnings = append(check.Warnings, "the SV-Nummer does not encode a valid birth date")
+	} else {
+		date := birth.Format("2006-01-02")
+		check.Geburtsdatum = &date
+		if geburtsdatum != nil && geburtsdatum.Format("020106") != encoded {
+			check.Warnings = append(check.Warnings, fmt.Sprintf("geburtsdatum differs from the birth date %s in the SV-Nummer", birth.Format("02.01.2006")))
+		}
+	}
+	if geburtsdatum != nil && geburtsdatum.After(now) {
+		check.Warnings = append(check.Warnings, "geburtsdatum is in the future")
+	}
+	return check
+}
+
+// addWarnings sets the plausibility findings of a saved Dienstnehmer
+func (s *Service) addWarnings(d *Dienstnehmer) {
+	d.Warnings = CheckSVNummer(d.SVNummer, d.Geburtsdatum, s.now()).Warnings
+}
+
+// apply validates the input and copies it to d. An invalid SV-Nummer is
+// rejected; date findings are reported as warnings.
+func apply(d *Dienstnehmer, input *Input) error {
+	d.Vorname = strings.TrimSpace(input.Vorname)
+	d.Nachname = strings.TrimSpace(input.Nachname)
+	if d.Vorname == "" || d.Nachname == "" {
+		return ErrNameRequired
+	}
+	d.SVNummer = NormalizeSVNummer(input.SVNummer)
+	if elda.ValidateSVNummer(d.SVNummer) != nil {
+		return ErrInvalidSVNummer
+	}
+
+	d.Geburtsdatum = nil
+	if input.Geburtsdatum != "" {
+		t, err := time.Parse("2006-01-02", input.Geburtsdatum)
+		if err != nil {
+			return ErrInvalidGeburtsdatum
+		}
+		d.Geburtsdatum = &t
+	}
+	d.Geschlecht = strings.ToUpper(strings.TrimSpace(input.Geschlecht))
+	if d.Geschlecht != "" && d.Geschlecht != "M" && d.Geschlecht != "W" && d.Geschlecht != "D" {
+		return ErrInvalidGeschlecht
+	}
+
+	d.Adresse = input.Adresse
+	d.Bankverbindung = input.Bankverbindung
+	if d.Bankverbindung != nil {
+		d.Bankverbindung.IBAN = strings.ToUpper(strings.Join(strings.Fields(d.Bankverbindung.IBAN), ""))
+	}
+	d.Notes = nil
+	if input.Notes != nil {
+		if notes := strings.TrimSpace(*input.Notes); notes != "" {
+			d.Notes = &notes
+		}
+	}
+	return nil
+}
+
+// PrefillMeldung fills the employee data of a meldung created with a
+// dienstnehmer_id from the registry. The Dienstnehmer must belong to the
+// tenant of the meldung's ELDA account.
+func (s *Service) PrefillMeldung(ctx context.Context, req *elda.MeldungCreateRequest) error {
+	d, err := s.repo.GetForELDAAccount(ctx, *req.DienstnehmerID, req.ELDAAccountID)
+	if errors.Is(err, ErrDienstnehmerNotFound) {
+		return &eldameldung.ValidationError{
+			Message: "Validierungsfehler",
+			Errors:  []string{"dienstnehmer_id: Dienstnehmer nicht gefunden"},
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return PrefillRequest(req, d)
+}
+
+// PrefillRequest fills the empty employee fields of a meldung request from
+// a Dienstnehmer; fields set in the request take precedence. A different
+// SV-Nummer in the request is rejected.
+func PrefillRequest(req *elda.MeldungCreateRequest, d *Dienstnehmer) error {
+	if sv := NormalizeSVNummer(req.SVNummer); sv != "" && sv != d.SVNummer {
+		return &eldameldung.ValidationError{
+			Message: "Validierungsfehler",
+			Errors:  []string{"sv_nummer: SV-Nummer weicht vom Dienstnehmer ab"},
+		}
+	}
+	req.SVNummer = d.SVNummer
+	if req.Vorname == "" {
+		req.Vorname = d.Vorname
+	}
+	if req.Nachname == "" {
+		req.Nachname = d.Nachname
+	}
+	if req.Geburtsdatum == "" && d.Geburtsdatum != nil {
+		req.Geburtsdatum = d.Geburtsdatum.Format("2006-01-02")
+	}
+	if req.Geschlecht == "" {
+		req.Geschlecht = d.Geschlecht
+	}
+	if req.Adresse == nil && d.Adresse != nil {
+		adresse := *d.Adresse
+		req.Adresse = &adresse
+	}
+	if req.Bankverbindung == nil && d.Bankverbindung != nil {
+		bank := *d.Bankverbindung
+		req.Bankverbindung = &bank
+	}
+	return nil
+}
+
+// History returns the employments of a Dienstnehmer, the latest first
+func (s *Service) History(ctx context.Context, tenantID, id uuid.UUID) ([]*Beschaeftigung, error) {
+	d, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	meldungen, err := s.meldungen.List(ctx, eldameldung.ListFilter{
+		TenantID: &tenantID,
+		SVNummer: d.SVNummer,
+		Limit:    maxHistoryMeldungen,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return DeriveBeschaeftigungen(meldungen), nil
+}
+
+// meldungOrder orders meldungen of the same day: the Anmeldung before
+// Änderungen before the Abmeldung
+var meldungOrder = map[elda.MeldungType]int{
+	elda.MeldungTypeAnmeldung: 0,
+	elda.MeldungTypeAenderung: 1,
+	elda.MeldungTypeAbmeldung: 2,
+}
+
+// DeriveBeschaeftigungen derives the employments, the latest first, from
+// the submitted and accepted meldungen of one SV-Nummer. An Anmeldung opens
+// an employment with the Dienstgeber of its ELDA account and the Abmeldung
+// closes it; Änderungsmeldungen update the working time and occupation.
+// Drafts, rejected meldungen and Korrekturen are ignored.
+func DeriveBeschaeftigungen(meldungen []*elda.ELDAMeldung) []*Beschaeftigung {
+	var reported []*elda.ELDAMeldung
+	for _, m := range meldungen {
+		if _, ok := meldungOrder[m.Type]; !ok {
+			continue
+		}
+		if m.Status == elda.MeldungStatusSubmitted || m.Status == elda.MeldungStatusAccepted {
+			reported = append(reported, m)
+		}
+	}
+	sort.SliceStable(reported, func(i, j int) bool {
+		a, b := reported[i], reported[j]
+		if da, db := effectiveDate(a), effectiveDate(b); !da.Equal(db) {
+			return da.Before(db)
+		}
+		if meldungOrder[a.Type] != meldungOrder[b.Type] {
+			return meldungOrder[a.Type] < meldungOrder[b.Type]
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+
+	var employments []*Beschaeftigung
+	open := make(map[uuid.UUID]*Beschaeftigung) // by ELDA account
+	for _, m := range reported {
+		switch m.Type {
+		case elda.MeldungTypeAnmeldung:
+			id := m.ID
+			b := &Beschaeftigung{ELDAAccountID: m.ELDAAccountID, Eintrittsdatum: m.Eintrittsdatum, AnmeldungID: &id}
+			applyEmployment(b, m)
+			open[m.ELDAAccountID] = b
+			employments = append(employments, b)
+		case elda.MeldungTypeAenderung:
+			if b := open[m.ELDAAccountID]; b != nil {
+				b.Aenderungen++
+				applyEmployment(b, m)
+			}
+		case elda.MeldungTypeAbmeldung:
+			b := open[m.ELDAAccountID]
+			if b == nil {
+				// Employed before the Anmeldung was on record
+				b = &Beschaeftigung{ELDAAccountID: m.ELDAAccountID}
+				employments = append(employments, b)
+			}
+			id := m.ID
+			b.Austrittsdatum, b.AustrittGrund, b.AbmeldungID = m.Austrittsdatum, m.AustrittGrund, &id
+			delete(open, m.ELDAAccountID)
+		}
+	}
+
+	for i, j := 0, len(employments)-1; i < j; i, j = i+1, j-1 {
+		employments[i], employments[j] = employments[j], employments[i]
+	}
+	return employments
+}
+
+// effectiveDate is the date a meldung takes effect
+func effectiveDate(m *elda.ELDAMeldung) time.Time {
+	var date *time.Time
+	switch m.Type {
+	case elda.MeldungTypeAnmeldung:
+		date = m.Eintrittsdatum
+	case elda.MeldungTypeAbmeldung:
+		date = m.Austrittsdatum
+	case elda.MeldungTypeAenderung:
+		date = m.AenderungDatum
+	}
+	if date == nil {
+		return m.CreatedAt
+	}
+	return *date
+}
+
+// applyEmployment takes the occupation and working time reported by a
+// meldung
+func applyEmployment(b *Beschaeftigung, m *elda.ELDAMeldung) {
+	if m.Beschaeftigung != nil {
+		if m.Beschaeftigung.Art != "" {
+			b.Art = m.Beschaeftigung.Art
+		}
+		if m.Beschaeftigung.Taetigkeit != "" {
+			b.Taetigkeit = m.Beschaeftigung.Taetigkeit
+		}
+	}
+	if m.Arbeitszeit != nil && m.Arbeitszeit.WochenStunden > 0 {
+		b.WochenStunden = m.Arbeitszeit.WochenStunden
+	}
+}
@@ -0,0 +1,90 @@
+// Package dienstnehmer keeps the employee master data (Dienstnehmer) of a
+// tenant: SV-Nummer, name, birth date, address and bank account. ELDA
+// meldungen created with a dienstnehmer_id are prefilled from it, and the
+// employment history of a Dienstnehmer is derived from the tenant's ELDA
+// An-, Ab- and Änderungsmeldungen.
+package dienstnehmer
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/elda"
+)
+
+var (
+	ErrDienstnehmerNotFound = errors.New("Dienstnehmer not found")
+	ErrNameRequired         = errors.New("vorname and nachname are required")
+	ErrInvalidSVNummer      = errors.New("invalid SV-Nummer")
+	ErrInvalidGeburtsdatum  = errors.New("geburtsdatum must be a date (YYYY-MM-DD)")
+	ErrInvalidGeschlecht    = errors.New("geschlecht must be M, W or D")
+	ErrDuplicateSVNummer    = errors.New("a Dienstnehmer with this SV-Nummer already exists")
+)
+
+// Dienstnehmer is an employee of a tenant
+type Dienstnehmer struct {
+	ID             uuid.UUID                 `json:"id"`
+	TenantID       uuid.UUID                 `json:"tenant_id"`
+	SVNummer       string                    `json:"sv_nummer"`
+	Vorname        string                    `json:"vorname"`
+	Nachname       string                    `json:"nachname"`
+	Geburtsdatum   *time.Time                `json:"geburtsdatum,omitempty"`
+	Geschlecht     string                    `json:"geschlecht,omitempty"` // M, W, D
+	Adresse        *elda.DienstnehmerAdresse `json:"adresse,omitempty"`
+	Bankverbindung *elda.Bankverbindung      `json:"bankverbindung,omitempty"`
+	Notes          *string                   `json:"notes,omitempty"`
+	// Warnings are the plausibility findings that do not prevent saving
+	Warnings  []string  `json:"warnings,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Input creates or replaces a Dienstnehmer
+type Input struct {
+	SVNummer       string                    `json:"sv_nummer"`
+	Vorname        string                    `json:"vorname"`
+	Nachname       string                    `json:"nachname"`
+	Geburtsdatum   string                    `json:"geburtsdatum"` // YYYY-MM-DD
+	Geschlecht     string                    `json:"geschlecht"`
+	Adresse        *elda.DienstnehmerAdresse `json:"adresse"`
+	Bankverbindung *elda.Bankverbindung      `json:"bankverbindung"`
+	Notes          *string                   `json:"notes"`
+}
+
+// ListFilter filters Dienstnehmer
+type ListFilter struct {
+	TenantID uuid.UUID
+	Search   string // In name and SV-Nummer
+	Limit    int
+	Offset   int
+}
+
+// SVNummerCheck is the plausibility check of an SV-Nummer
+type SVNummerCheck struct {
+	SVNummer string `json:"sv_nummer"`
+	Valid    bool   `json:"valid"`
+	Error    string `json:"error,omitempty"`
+	// Geburtsdatum is the birth date encoded in a valid SV-Nummer
+	Geburtsdatum *string  `json:"geburtsdatum,omitempty"`
+	Warnings     []string `json:"warnings,omitempty"`
+	// DienstnehmerID is the registered Dienstnehmer with this SV-Nummer
+	DienstnehmerID *uuid.UUID `json:"dienstnehmer_id,omitempty"`
+}
+
+// Beschaeftigung is an employment of a Dienstnehmer with the Dienstgeber of
+// an ELDA account, derived from the submitted and accepted meldungen
+type Beschaeftigung struct {
+	ELDAAccountID  uuid.UUID              `json:"elda_account_id"`
+	Eintrittsdatum *time.Time             `json:"eintrittsdatum,omitempty"` // nil if the Anmeldung is not on record
+	Austrittsdatum *time.Time             `json:"austrittsdatum,omitempty"` // nil while employed
+	AustrittGrund  elda.ELDAAustrittGrund `json:"austritt_grund,omitempty"`
+	Art            string                 `json:"art,omitempty"`
+	Taetigkeit     string                 `json:"taetigkeit,omitempty"`
+	WochenStunden  float64                `json:"wochen_stunden,omitempty"`
+	AnmeldungID    *uuid.UUID             `json:"anmeldung_id,omitempty"`
+	AbmeldungID    *uuid.UUID             `json:"abmeldung_id,omitempty"`
+	// Aenderungen counts the Änderungsmeldungen during the employment
+	Aenderungen int `json:"aenderungen"`
+}
@@ -0,0 +1,249 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/pkg/database"
+)
+
+// Access errors
+var (
+	ErrInvalidAccess  = errors.New("users and teams must belong to the tenant")
+	ErrFolderRequired = errors.New("folder is required")
+)
+
+// Access lists who may see a document or the documents of a folder and its
+// subfolders. Without any entry on a document or its folders the document is
+// visible to every user of the tenant; otherwise only to the listed users
+// and the members of the listed teams.
+type Access struct {
+	DocumentID *uuid.UUID  `json:"document_id,omitempty"`
+	Folder     string      `json:"folder,omitempty"`
+	UserIDs    []uuid.UUID `json:"user_ids"`
+	TeamIDs    []uuid.UUID `json:"team_ids"`
+}
+
+type accessOverrideKey struct{}
+
+// WithAccessOverride lifts the access lists for the queries of ctx. Admins
+// use it to reach restricted documents; each use is audit-logged.
+func WithAccessOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, accessOverrideKey{}, true)
+}
+
+// viewer returns the user whose access lists restrict the queries of ctx.
+// Requests of a user are restricted; jobs and other calls without a user
+// in the context see all documents of the tenant.
+func viewer(ctx context.Context) (uuid.UUID, bool) {
+	if override, _ := ctx.Value(accessOverrideKey{}).(bool); override {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(api.GetUserID(ctx))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// accessCondition returns the condition limiting the documents aliased
+// alias to those the viewer of ctx may see, with the viewer appended to
+// args; "" if ctx has no restricted viewer
+func accessCondition(ctx context.Context, alias string, args []interface{}) (string, []interface{}) {
+	userID, ok := viewer(ctx)
+	if !ok {
+		return "", args
+	}
+	return AccessConditionFor(userID, alias, args)
+}
+
+// AccessConditionFor is AccessCondition for a given user rather than the
+// viewer of ctx, for work done on a user's behalf outside a request
+func AccessConditionFor(userID uuid.UUID, alias string, args []interface{}) (string, []interface{}) {
+	args = append(args, userID)
+	return "\n\t\tAND " + visibleTo(alias, fmt.Sprintf("$%d", len(args))), args
+}
+
+// AccessCondition is accessCondition for the queries of other packages;
+// alias must name a row of documents joined to the rows they return
+func AccessCondition(ctx context.Context, alias string, args []interface{}) (string, []interface{}) {
+	return accessCondition(ctx, alias, args)
+}
+
+// RowAccessCondition is AccessCondition for the rows of table that refer to
+// a document in their document_id column; rows without a document pass
+func RowAccessCondition(ctx context.Context, table string, args []interface{}) (string, []interface{}) {
+	access, args := accessCondition(ctx, "d", args)
+	if access == "" {
+		return "", args
+	}
+	return fmt.Sprintf(`
+		AND (%[1]s.document_id IS NULL OR EXISTS (SELECT 1 FROM documents d
+			WHERE d.id = %[1]s.document_id AND d.tenant_id = %[1]s.tenant_id%[2]s))`, table, access), args
+}
+
+// visibleTo returns the condition that the document aliased alias is
+// visible to the user expression user
+func visibleTo(alias, user string) string {
+	entries := fmt.Sprintf(`SELECT 1 FROM document_access x
+			WHERE x.tenant_id = %[1]s.tenant_id
+				AND (x.document_id = %[1]s.id OR x.folder = %[1]s.folder OR starts_with(%[1]s.folder, x.folder || '/'))`, alias)
+	return fmt.Sprintf(`(NOT EXISTS (%[1]s)
+			OR EXISTS (%[1]s
+				AND (x.user_id = %[2]s OR x.team_id IN (SELECT m.team_id FROM team_members m WHERE m.user_id = %[2]s))))`,
+		entries, user)
+}
+
+// FilterViewers returns those of userIDs the access lists let see a
+// document; all of them without a list
+func (r *Repository) FilterViewers(ctx context.Context, tenantID, documentID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	visible := make(map[uuid.UUID]bool, len(userIDs))
+	if len(userIDs) == 0 {
+		return visible, nil
+	}
+	rows, err := r.db.Query(ctx, `
+		SELECT u.id FROM documents d, unnest($3::uuid[]) AS u(id)
+		WHERE d.id = $1 AND d.tenant_id = $2
+			AND `+visibleTo("d", "u.id"), documentID, tenantID, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("filter document viewers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan document viewer: %w", err)
+		}
+		visible[id] = true
+	}
+	return visible, rows.Err()
+}
+
+// GetAccess returns the access list of a document
+func (r *Repository) GetAccess(ctx context.Context, tenantID, documentID uuid.UUID) (*Access, error) {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM documents WHERE id = $1 AND tenant_id = $2)`,
+		documentID, tenantID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("get document: %w", err)
+	}
+	if !exists {
+		return nil, ErrDocumentNotFound
+	}
+	access := &Access{DocumentID: &documentID}
+	return access, r.loadAccess(ctx, access, `tenant_id = $1 AND document_id = $2`, tenantID, documentID)
+}
+
+// GetFolderAccess returns the access list of a folder
+func (r *Repository) GetFolderAccess(ctx context.Context, tenantID uuid.UUID, folder string) (*Access, error) {
+	access := &Access{Folder: folder}
+	return access, r.loadAccess(ctx, access, `tenant_id = $1 AND folder = $2`, tenantID, folder)
+}
+
+func (r *Repository) loadAccess(ctx context.Context, access *Access, where string, args ...interface{}) error {
+	rows, err := r.db.Query(ctx, `
+		SELECT user_id, team_id FROM document_access
+		WHERE `+where+`
+		ORDER BY created_at`, args...)
+	if err != nil {
+		return fmt.Errorf("get document access: %w", err)
+	}
+	defer rows.Close()
+
+	access.UserIDs, access.TeamIDs = []uuid.UUID{}, []uuid.UUID{}
+	for rows.Next() {
+		var userID, teamID *uuid.UUID
+		if err := rows.Scan(&userID, &teamID); err != nil {
+			return fmt.Errorf("scan document access: %w", err)
+		}
+		if userID != nil {
+			access.UserIDs = append(access.UserIDs, *userID)
+		} else if teamID != nil {
+			access.TeamIDs = append(access.TeamIDs, *teamID)
+		}
+	}
+	return rows.Err()
+}
+
+// SetAccess replaces the access list of a document or, without a document,
+// of a folder. An empty list makes it visible to the whole tenant again.
+func (r *Repository) SetAccess(ctx context.Context, tenantID uuid.UUID, access *Access, createdBy *uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	target, targetArg := "folder", interface{}(access.Folder)
+	if access.DocumentID != nil {
+		target, targetArg = "document_id", *access.DocumentID
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM documents WHERE id = $1 AND tenant_id = $2)`,
+			*access.DocumentID, tenantID).Scan(&exists); err != nil {
+			return fmt.Errorf("get document: %w", err)
+		}
+		if !exists {
+			return ErrDocumentNotFound
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM document_access WHERE tenant_id = $1 AND `+target+` = $2`,
+		tenantID, targetArg); err != nil {
+		return fmt.Errorf("clear document access: %w", err)
+	}
+
+	userIDs, teamIDs := database.UniqueIDs(access.UserIDs), database.UniqueIDs(access.TeamIDs)
+	users, err := tx.Exec(ctx, `
+		INSERT INTO document_access (tenant_id, `+target+`, user_id, created_by)
+		SELECT $1, $2, u.id, $4 FROM users u WHERE u.tenant_id = $1 AND u.id = ANY($3)`,
+		tenantID, targetArg, userIDs, createdBy)
+	if err != nil {
+		return fmt.Errorf("set document access: %w", err)
+	}
+	teams, err := tx.Exec(ctx, `
+		INSERT INTO document_access (tenant_id, `+target+`, team_id, created_by)
+		SELECT $1, $2, t.id, $4 FROM teams t WHERE t.tenant_id = $1 AND t.id = ANY($3)`,
+		tenantID, targetArg, teamIDs, createdBy)
+	if err != nil {
+		return fmt.Errorf("set document access: %w", err)
+	}
+	if int(users.RowsAffected()) != len(userIDs) || int(teams.RowsAffected()) != len(teamIDs) {
+		return ErrInvalidAccess
+	}
+	return tx.Commit(ctx)
+}
+
+// GetAccess returns the access list of a document
+func (s *Service) GetAccess(ctx context.Context, tenantID, documentID uuid.UUID) (*Access, error) {
+	return s.repo.GetAccess(ctx, tenantID, documentID)
+}
+
+// GetFolderAccess returns the access list of a folder
+func (s *Service) GetFolderAccess(ctx context.Context, tenantID uuid.UUID, folder string) (*Access, error) {
+	folder = CleanFolder(folder)
+	if folder == "" {
+		return nil, ErrFolderRequired
+	}
+	return s.repo.GetFolderAccess(ctx, tenantID, folder)
+}
+
+// SetAccess replaces the access list of a document or a folder
+func (s *Service) SetAccess(ctx context.Context, tenantID uuid.UUID, access *Access, createdBy *uuid.UUID) (*Access, error) {
+	if access.DocumentID == nil {
+		access.Folder = CleanFolder(access.Folder)
+		if access.Folder == "" {
+			return nil, ErrFolderRequired
+		}
+	} else {
+		access.Folder = ""
+	}
+	if err := s.repo.SetAccess(ctx, tenantID, access, createdBy); err != nil {
+		return nil, err
+	}
+	access.UserIDs, access.TeamIDs = database.UniqueIDs(access.UserIDs), database.UniqueIDs(access.TeamIDs)
+	return access, nil
+}
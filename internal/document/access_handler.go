@@ -0,0 +1,190 @@
+package document
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/audit"
+)
+
+// ActionAccessOverride is the audit action of an admin request that lifts
+// the document access lists
+const ActionAccessOverride = "document_access_override"
+
+// AccessRequest is the body of PUT /api/v1/documents/{id}/access and
+// PUT /api/v1/documents/folder-access
+type AccessRequest struct {
+	Folder  string      `json:"folder,omitempty"`
+	UserIDs []uuid.UUID `json:"user_ids"`
+	TeamIDs []uuid.UUID `json:"team_ids"`
+}
+
+// SetAuditLogger sets the logger recording admin access overrides
+func (h *Handler) SetAuditLogger(logger *audit.Logger) {
+	h.auditLogger = logger
+}
+
+func isAdmin(r *http.Request) bool {
+	role := api.GetUserRole(r.Context())
+	return role == "owner" || role == "admin"
+}
+
+// AccessOverride wraps the document routes: with ?override=true an admin
+// sees documents hidden from them by access lists. Each override is
+// audit-logged; other users are refused.
+func (h *Handler) AccessOverride(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("override") != "true" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !isAdmin(r) {
+			api.JSONError(w, http.StatusForbidden, "only admins may override document access", api.ErrCodeForbidden)
+			return
+		}
+		if h.auditLogger == nil {
+			api.JSONError(w, http.StatusForbidden, "document access override is not available", api.ErrCodeForbidden)
+			return
+		}
+
+		logCtx := audit.ContextFromRequest(r)
+		resourceType := "document"
+		logCtx.ResourceType = &resourceType
+		if err := h.auditLogger.Log(r.Context(), logCtx, ActionAccessOverride, map[string]interface{}{
+			"method": r.Method,
+			"path":   r.URL.Path,
+		}); err != nil {
+			api.JSONError(w, http.StatusInternalServerError, "failed to record access override", api.ErrCodeInternalError)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithAccessOverride(r.Context())))
+	})
+}
+
+// GetAccess returns the access list of a document (admin only)
+func (h *Handler) GetAccess(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.accessTarget(w, r)
+	if !ok {
+		return
+	}
+
+	access, err := h.service.GetAccess(r.Context(), tenantID, id)
+	if err != nil {
+		writeAccessError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, access)
+}
+
+// SetAccess replaces the access list of a document (admin only). Empty lists
+// make the document visible to the whole tenant again.
+func (h *Handler) SetAccess(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.accessTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req AccessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid request body", api.ErrCodeBadRequest)
+		return
+	}
+
+	access, err := h.service.SetAccess(r.Context(), tenantID, &Access{
+		DocumentID: &id,
+		UserIDs:    req.UserIDs,
+		TeamIDs:    req.TeamIDs,
+	}, api.OptionalUser(r))
+	if err != nil {
+		writeAccessError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, access)
+}
+
+// GetFolderAccess returns the access list of the folder given by ?folder=
+// (admin only)
+func (h *Handler) GetFolderAccess(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := adminTenant(w, r)
+	if !ok {
+		return
+	}
+
+	access, err := h.service.GetFolderAccess(r.Context(), tenantID, r.URL.Query().Get("folder"))
+	if err != nil {
+		writeAccessError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, access)
+}
+
+// SetFolderAccess replaces the access list of a folder and its subfolders
+// (admin only)
+func (h *Handler) SetFolderAccess(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := adminTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var req AccessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid request body", api.ErrCodeBadRequest)
+		return
+	}
+
+	access, err := h.service.SetAccess(r.Context(), tenantID, &Access{
+		Folder:  req.Folder,
+		UserIDs: req.UserIDs,
+		TeamIDs: req.TeamIDs,
+	}, api.OptionalUser(r))
+	if err != nil {
+		writeAccessError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, access)
+}
+
+// accessTarget returns the tenant and document of an access list request,
+// writing an error unless the user is an admin and the ID is valid
+func (h *Handler) accessTarget(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, ok := adminTenant(w, r)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid document ID", api.ErrCodeBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return tenantID, id, true
+}
+
+// adminTenant returns the tenant of the request, writing 403 unless the
+// user is an admin
+func adminTenant(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantID, err := getTenantID(r)
+	if err != nil || !isAdmin(r) {
+		api.JSONError(w, http.StatusForbidden, "access denied", api.ErrCodeForbidden)
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
+
+func writeAccessError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrDocumentNotFound):
+		api.JSONError(w, http.StatusNotFound, "document not found", api.ErrCodeNotFound)
+	case errors.Is(err, ErrInvalidAccess), errors.Is(err, ErrFolderRequired):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	default:
+		api.JSONError(w, http.StatusInternalServerError, "failed to process document access", api.ErrCodeInternalError)
+	}
+}
@@ -0,0 +1,519 @@
+package document
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"austrian-business-infrastructure/internal/mailparse"
+)
+
+// Document types of uploaded e-mails
+const (
+	TypeEmail           = "email"
+	TypeEmailAttachment = "email_attachment"
+)
+
+// maxEmailDepth limits how deep e-mails attached to e-mails are unpacked
+const maxEmailDepth = 3
+
+// E-mail errors
+var (
+	ErrEmailNotFound   = errors.New("e-mail not found")
+	ErrNotEmail        = errors.New("file is not an EML or MSG e-mail")
+	ErrEmailUnreadable = errors.New("e-mail could not be read")
+)
+
+// AnalysisScheduler queues the AI analysis of documents
+type AnalysisScheduler interface {
+	// SupportsAnalysis reports whether text can be extracted from documents
+	// of the MIME type
+	SupportsAnalysis(mimeType string) bool
+	ScheduleAnalysis(ctx context.Context, tenantID, documentID uuid.UUID) error
+}
+
+// Email is what was read from an uploaded e-mail
+type Email struct {
+	DocumentID       uuid.UUID
+	TenantID         uuid.UUID
+	ParentDocumentID *uuid.UUID
+	Format           string
+	MessageID        string
+	InReplyTo        string
+	References       []string
+	ThreadID         string
+	From             string
+	FromName         string
+	To               []string
+	Cc               []string
+	Subject          string
+	SentAt           *time.Time
+	Authenticity     mailparse.Authenticity
+	Warnings         []string
+	CreatedAt        time.Time
+}
+
+// EmailAttachment links an attachment document to its e-mail
+type EmailAttachment struct {
+	EmailDocumentID uuid.UUID
+	DocumentID      uuid.UUID
+	Filename        string
+	ContentType     string
+	Position        int
+	FileSize        int
+}
+
+const emailColumns = `document_id, tenant_id, parent_document_id, format, message_id,
+	in_reply_to, "references", thread_id, from_address, from_name, to_addresses,
+	cc_addresses, subject, sent_at, authenticity, warnings, created_at`
+
+func scanEmail(row pgx.Row) (*Email, error) {
+	e := &Email{}
+	var authenticity, warnings []byte
+	err := row.Scan(
+		&e.DocumentID, &e.TenantID, &e.ParentDocumentID, &e.Format, &e.MessageID,
+		&e.InReplyTo, &e.References, &e.ThreadID, &e.From, &e.FromName, &e.To,
+		&e.Cc, &e.Subject, &e.SentAt, &authenticity, &warnings, &e.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(authenticity) > 0 {
+		json.Unmarshal(authenticity, &e.Authenticity)
+	}
+	if len(warnings) > 0 {
+		json.Unmarshal(warnings, &e.Warnings)
+	}
+	return e, nil
+}
+
+// CreateEmail records what was read from an e-mail document
+func (r *Repository) CreateEmail(ctx context.Context, email *Email) error {
+	authenticity, err := json.Marshal(email.Authenticity)
+	if err != nil {
+		return fmt.Errorf("marshal authenticity: %w", err)
+	}
+	warnings := []byte("[]")
+	if len(email.Warnings) > 0 {
+		if warnings, err = json.Marshal(email.Warnings); err != nil {
+			return fmt.Errorf("marshal warnings: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO document_emails (
+			document_id, tenant_id, parent_document_id, format, message_id,
+			in_reply_to, "references", thread_id, from_address, from_name,
+			to_addresses, cc_addresses, subject, sent_at, authenticity, warnings
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		ON CONFLICT (document_id) DO NOTHING
+		RETURNING created_at
+	`
+
+	err = r.db.QueryRow(ctx, query,
+		email.DocumentID, email.TenantID, email.ParentDocumentID, email.Format, email.MessageID,
+		email.InReplyTo, nonNil(email.References), email.ThreadID, email.From, email.FromName,
+		nonNil(email.To), nonNil(email.Cc), email.Subject, email.SentAt, authenticity, warnings,
+	).Scan(&email.CreatedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("create email: %w", err)
+	}
+	return nil
+}
+
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+// GetEmail returns the e-mail stored as a document with tenant isolation
+func (r *Repository) GetEmail(ctx context.Context, tenantID, documentID uuid.UUID) (*Email, error) {
+	access, args := accessCondition(ctx, "d", []interface{}{documentID, tenantID})
+	query := `SELECT ` + emailColumns + ` FROM document_emails e WHERE e.document_id = $1 AND e.tenant_id = $2
+		AND EXISTS (SELECT 1 FROM documents d WHERE d.id = e.document_id` + access + `)`
+
+	email, err := scanEmail(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrEmailNotFound
+		}
+		return nil, fmt.Errorf("get email: %w", err)
+	}
+	return email, nil
+}
+
+// ListEmailThread returns the e-mails of a conversation, oldest first
+func (r *Repository) ListEmailThread(ctx context.Context, tenantID uuid.UUID, threadID string) ([]*Email, error) {
+	access, args := accessCondition(ctx, "d", []interface{}{tenantID, threadID, MaxPageSize})
+	query := `
+		SELECT ` + emailColumns + ` FROM document_emails e
+		WHERE e.tenant_id = $1 AND e.thread_id = $2
+			AND EXISTS (SELECT 1 FROM documents d WHERE d.id = e.document_id` + access + `)
+		ORDER BY e.sent_at ASC NULLS LAST, e.created_at ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list email thread: %w", err)
+	}
+	defer rows.Close()
+
+	var emails []*Email
+	for rows.Next() {
+		email, err := scanEmail(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan email: %w", err)
+		}
+		emails = append(emails, email)
+	}
+	return emails, rows.Err()
+}
+
+// FindEmailThread returns the conversation of the first stored e-mail with
+// one of the message IDs, or "" when none is stored
+func (r *Repository) FindEmailThread(ctx context.Context, tenantID uuid.UUID, messageIDs []string) (string, error) {
+	if len(messageIDs) == 0 {
+		return "", nil
+	}
+	query := `
+		SELECT thread_id FROM document_emails
+		WHERE tenant_id = $1 AND message_id = ANY($2)
+		ORDER BY created_at ASC
+		LIMIT 1
+	`
+
+	var threadID string
+	err := r.db.QueryRow(ctx, query, tenantID, messageIDs).Scan(&threadID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("find email thread: %w", err)
+	}
+	return threadID, nil
+}
+
+// AddEmailAttachment links an attachment document to its e-mail
+func (r *Repository) AddEmailAttachment(ctx context.Context, tenantID uuid.UUID, attachment *EmailAttachment) error {
+	query := `
+		INSERT INTO document_email_attachments (
+			email_document_id, document_id, tenant_id, filename, content_type, position
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (email_document_id, document_id) DO NOTHING
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		attachment.EmailDocumentID, attachment.DocumentID, tenantID,
+		attachment.Filename, attachment.ContentType, attachment.Position,
+	)
+	if err != nil {
+		return fmt.Errorf("add email attachment: %w", err)
+	}
+	return nil
+}
+
+// ListEmailAttachments returns the attachments of an e-mail in their order
+// in the message
+func (r *Repository) ListEmailAttachments(ctx context.Context, tenantID, emailDocumentID uuid.UUID) ([]*EmailAttachment, error) {
+	access, args := accessCondition(ctx, "d", []interface{}{emailDocumentID, tenantID})
+	query := `
+		SELECT a.email_document_id, a.document_id, a.filename, a.content_type, a.position, d.file_size
+		FROM document_email_attachments a
+		JOIN documents d ON d.id = a.document_id
+		WHERE a.email_document_id = $1 AND a.tenant_id = $2` + access + `
+		ORDER BY a.position ASC
+	`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list email attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []*EmailAttachment
+	for rows.Next() {
+		a := &EmailAttachment{}
+		if err := rows.Scan(&a.EmailDocumentID, &a.DocumentID, &a.Filename, &a.ContentType, &a.Position, &a.FileSize); err != nil {
+			return nil, fmt.Errorf("scan email attachment: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+// SetAnalysisScheduler sets the scheduler that queues the analysis of
+// ingested e-mails and their attachments
+func (s *Service) SetAnalysisScheduler(scheduler AnalysisScheduler) {
+	s.analysisScheduler = scheduler
+}
+
+// IngestEmailInput holds an uploaded e-mail
+type IngestEmailInput struct {
+	AccountID uuid.UUID
+	Filename  string
+	Content   io.Reader
+}
+
+// EmailIngestResult is the outcome of an e-mail upload
+type EmailIngestResult struct {
+	Email       *Email
+	Document    *Document
+	Attachments []*EmailAttachment
+	// Duplicate is set when the e-mail had been uploaded before; nothing
+	// was stored or queued again
+	Duplicate bool
+	// AnalysisQueued counts the documents queued for analysis: the e-mail
+	// itself and every attachment text can be extracted from
+	AnalysisQueued int
+}
+
+// IngestEmail stores an uploaded EML or MSG file. The e-mail becomes a
+// document of type "email" whose text is its headers and body; every
+// attachment becomes a child document, and attached e-mails are unpacked the
+// same way. Replies are put into the conversation of the e-mails they refer
+// to. The e-mail and attachments are queued for analysis.
+func (s *Service) IngestEmail(ctx context.Context, tenantID uuid.UUID, input *IngestEmailInput) (*EmailIngestResult, error) {
+	content, err := io.ReadAll(io.LimitReader(input.Content, s.maxDocumentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read content: %w", err)
+	}
+	if int64(len(content)) > s.maxDocumentSize {
+		return nil, ErrDocumentTooLarge
+	}
+
+	mimeType := mailparse.Detect(content, input.Filename)
+	if mimeType == "" {
+		return nil, ErrNotEmail
+	}
+	msg, err := mailparse.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEmailUnreadable, err)
+	}
+
+	result := &EmailIngestResult{}
+	doc, email, err := s.ingestMessage(ctx, tenantID, input.AccountID, content, mimeType, msg, nil, 0, result)
+	if err != nil {
+		return nil, err
+	}
+	result.Document = doc
+	result.Email = email
+	if result.Attachments, err = s.repo.ListEmailAttachments(ctx, tenantID, doc.ID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ingestMessage stores one e-mail and its attachments
+func (s *Service) ingestMessage(ctx context.Context, tenantID, accountID uuid.UUID, content []byte, mimeType string, msg *mailparse.Message, parent *uuid.UUID, depth int, result *EmailIngestResult) (*Document, *Email, error) {
+	externalID := emailExternalID(msg, content)
+	receivedAt := time.Now()
+	var sentAt *time.Time
+	if !msg.Date.IsZero() {
+		receivedAt = msg.Date
+		sentAt = &msg.Date
+	}
+	sender := msg.From
+	if msg.FromName != "" {
+		sender = msg.FromName + " <" + msg.From + ">"
+	}
+	sender = truncateRunes(sender, 255)
+	title := truncateRunes(msg.Subject, 500)
+	if title == "" {
+		title = "(kein Betreff)"
+	}
+
+	doc, err := s.Create(ctx, tenantID.String(), &CreateDocumentInput{
+		AccountID:   accountID,
+		ExternalID:  externalID,
+		Type:        TypeEmail,
+		Title:       title,
+		Sender:      sender,
+		ReceivedAt:  receivedAt,
+		Content:     newBytesReader(content),
+		ContentType: mimeType,
+		Metadata: map[string]interface{}{
+			"email": map[string]interface{}{
+				"message_id":   msg.MessageID,
+				"from":         msg.From,
+				"authenticity": msg.Authenticity,
+			},
+		},
+	})
+	duplicate := errors.Is(err, ErrDuplicateDocument) || (err == nil && doc.ExternalID != externalID)
+	if err != nil && !errors.Is(err, ErrDuplicateDocument) {
+		return nil, nil, err
+	}
+	if duplicate {
+		if email, err := s.repo.GetEmail(ctx, tenantID, doc.ID); err == nil {
+			if depth == 0 {
+				result.Duplicate = true
+			}
+			return doc, email, nil
+		}
+		// The same file was stored before without being read as an e-mail
+	}
+
+	referenced := msg.References
+	if msg.InReplyTo != "" {
+		referenced = append([]string{msg.InReplyTo}, referenced...)
+	}
+	threadID, err := s.repo.FindEmailThread(ctx, tenantID, referenced)
+	if err != nil {
+		return nil, nil, err
+	}
+	if threadID == "" {
+		threadID = msg.ThreadID()
+	}
+	if threadID == "" {
+		threadID = doc.ID.String()
+	}
+
+	email := &Email{
+		DocumentID:       doc.ID,
+		TenantID:         tenantID,
+		ParentDocumentID: parent,
+		Format:           msg.Format,
+		MessageID:        msg.MessageID,
+		InReplyTo:        msg.InReplyTo,
+		References:       msg.References,
+		ThreadID:         threadID,
+		From:             msg.From,
+		FromName:         msg.FromName,
+		To:               msg.To,
+		Cc:               msg.Cc,
+		Subject:          msg.Subject,
+		SentAt:           sentAt,
+		Authenticity:     msg.Authenticity,
+		Warnings:         msg.Warnings,
+	}
+
+	for i, attachment := range msg.Attachments {
+		// Logos and other images embedded in the HTML body are not files
+		// the sender attached
+		if attachment.Inline && attachment.ContentID != "" && strings.HasPrefix(attachment.ContentType, "image/") {
+			continue
+		}
+
+		link := &EmailAttachment{
+			EmailDocumentID: doc.ID,
+			Filename:        truncateRunes(attachment.Filename, 500),
+			ContentType:     attachment.ContentType,
+			Position:        i + 1,
+		}
+
+		var child *Document
+		if innerType := mailparse.Detect(attachment.Data, attachment.Filename); innerType != "" && depth < maxEmailDepth {
+			if inner, err := mailparse.Parse(attachment.Data); err == nil {
+				link.ContentType = innerType
+				child, _, err = s.ingestMessage(ctx, tenantID, accountID, attachment.Data, innerType, inner, &doc.ID, depth+1, result)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+		if child == nil {
+			childExternalID := externalID + "-" + strconv.Itoa(i+1)
+			child, err = s.Create(ctx, tenantID.String(), &CreateDocumentInput{
+				AccountID:   accountID,
+				ExternalID:  childExternalID,
+				Type:        TypeEmailAttachment,
+				Title:       truncateRunes(attachment.Filename, 500),
+				Sender:      sender,
+				ReceivedAt:  receivedAt,
+				Content:     newBytesReader(attachment.Data),
+				ContentType: attachment.ContentType,
+				Metadata: map[string]interface{}{
+					"email_document_id": doc.ID.String(),
+					"filename":          attachment.Filename,
+				},
+			})
+			switch {
+			case errors.Is(err, ErrDuplicateDocument):
+			case errors.Is(err, ErrDocumentTooLarge):
+				email.Warnings = append(email.Warnings, fmt.Sprintf("attachment %q exceeds the document size limit", attachment.Filename))
+				continue
+			case err != nil:
+				return nil, nil, fmt.Errorf("store attachment %q: %w", attachment.Filename, err)
+			case child.ExternalID == childExternalID:
+				// Attachments stored before with another e-mail were
+				// analyzed then
+				s.queueAnalysis(ctx, tenantID, child, result)
+			}
+		}
+
+		link.DocumentID = child.ID
+		if err := s.repo.AddEmailAttachment(ctx, tenantID, link); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := s.repo.CreateEmail(ctx, email); err != nil {
+		return nil, nil, err
+	}
+	s.queueAnalysis(ctx, tenantID, doc, result)
+	return doc, email, nil
+}
+
+// queueAnalysis queues the analysis of an ingested document. Errors are not
+// returned: the document is stored and can be analyzed on request.
+func (s *Service) queueAnalysis(ctx context.Context, tenantID uuid.UUID, doc *Document, result *EmailIngestResult) {
+	if s.analysisScheduler == nil || !s.analysisScheduler.SupportsAnalysis(doc.MimeType) {
+		return
+	}
+	if err := s.analysisScheduler.ScheduleAnalysis(ctx, tenantID, doc.ID); err == nil {
+		result.AnalysisQueued++
+	}
+}
+
+// truncateRunes shortens a header value to the length of its column
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// emailExternalID identifies an e-mail by its Message-ID, so that the same
+// e-mail forwarded twice or saved once as EML and once as MSG is stored
+// once; e-mails without one are identified by their content
+func emailExternalID(msg *mailparse.Message, content []byte) string {
+	key := msg.MessageID
+	if key == "" {
+		key = string(content)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "email-" + hex.EncodeToString(sum[:16])
+}
+
+// GetEmail returns the e-mail stored as a document with its attachments
+func (s *Service) GetEmail(ctx context.Context, tenantID, documentID uuid.UUID) (*Email, []*EmailAttachment, error) {
+	email, err := s.repo.GetEmail(ctx, tenantID, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachments, err := s.repo.ListEmailAttachments(ctx, tenantID, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return email, attachments, nil
+}
+
+// GetEmailThread returns the conversation the e-mail belongs to
+func (s *Service) GetEmailThread(ctx context.Context, tenantID, documentID uuid.UUID) ([]*Email, error) {
+	email, err := s.repo.GetEmail(ctx, tenantID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListEmailThread(ctx, tenantID, email.ThreadID)
+}
@@ -0,0 +1,219 @@
+package document
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/mailparse"
+	"austrian-business-infrastructure/internal/quota"
+)
+
+// EmailResponse represents an uploaded e-mail in API responses
+type EmailResponse struct {
+	DocumentID       string                    `json:"document_id"`
+	ParentDocumentID *string                   `json:"parent_document_id,omitempty"`
+	Format           string                    `json:"format"`
+	MessageID        string                    `json:"message_id,omitempty"`
+	InReplyTo        string                    `json:"in_reply_to,omitempty"`
+	References       []string                  `json:"references,omitempty"`
+	ThreadID         string                    `json:"thread_id"`
+	From             string                    `json:"from"`
+	FromName         string                    `json:"from_name,omitempty"`
+	To               []string                  `json:"to"`
+	Cc               []string                  `json:"cc,omitempty"`
+	Subject          string                    `json:"subject"`
+	SentAt           *time.Time                `json:"sent_at,omitempty"`
+	Authenticity     mailparse.Authenticity    `json:"authenticity"`
+	Warnings         []string                  `json:"warnings,omitempty"`
+	Attachments      []EmailAttachmentResponse `json:"attachments,omitempty"`
+}
+
+// EmailAttachmentResponse represents an attachment document of an e-mail
+type EmailAttachmentResponse struct {
+	DocumentID  string `json:"document_id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	FileSize    int    `json:"file_size"`
+	Position    int    `json:"position"`
+}
+
+func toEmailResponse(e *Email, attachments []*EmailAttachment) *EmailResponse {
+	resp := &EmailResponse{
+		DocumentID:   e.DocumentID.String(),
+		Format:       e.Format,
+		MessageID:    e.MessageID,
+		InReplyTo:    e.InReplyTo,
+		References:   e.References,
+		ThreadID:     e.ThreadID,
+		From:         e.From,
+		FromName:     e.FromName,
+		To:           e.To,
+		Cc:           e.Cc,
+		Subject:      e.Subject,
+		SentAt:       e.SentAt,
+		Authenticity: e.Authenticity,
+		Warnings:     e.Warnings,
+	}
+	if e.ParentDocumentID != nil {
+		parent := e.ParentDocumentID.String()
+		resp.ParentDocumentID = &parent
+	}
+	for _, a := range attachments {
+		resp.Attachments = append(resp.Attachments, EmailAttachmentResponse{
+			DocumentID:  a.DocumentID.String(),
+			Filename:    a.Filename,
+			ContentType: a.ContentType,
+			FileSize:    a.FileSize,
+			Position:    a.Position,
+		})
+	}
+	return resp
+}
+
+// UploadEmail stores an e-mail uploaded as EML or MSG file together with its
+// attachments and queues them for analysis
+func (h *Handler) UploadEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := getTenantID(r)
+	if err != nil {
+		api.JSONError(w, http.StatusUnauthorized, "unauthorized", api.ErrCodeUnauthorized)
+		return
+	}
+
+	// Leave room for the multipart framing around the file
+	r.Body = http.MaxBytesReader(w, r.Body, h.service.maxDocumentSize+1<<20)
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			api.JSONError(w, http.StatusRequestEntityTooLarge, "file too large", api.ErrCodeValidation)
+			return
+		}
+		api.JSONError(w, http.StatusBadRequest, "invalid multipart form", api.ErrCodeBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	accountID, err := uuid.Parse(r.FormValue("account_id"))
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "valid account_id required", api.ErrCodeBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "file required", api.ErrCodeBadRequest)
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.IngestEmail(ctx, tenantID, &IngestEmailInput{
+		AccountID: accountID,
+		Filename:  header.Filename,
+		Content:   file,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAccountNotOwned):
+			api.JSONError(w, http.StatusForbidden, "no access to this account", api.ErrCodeForbidden)
+		case errors.Is(err, ErrNotEmail):
+			api.JSONError(w, http.StatusUnprocessableEntity, "file is not an EML or MSG e-mail", api.ErrCodeValidation)
+		case errors.Is(err, ErrEmailUnreadable):
+			api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+		case errors.Is(err, ErrDocumentTooLarge):
+			api.JSONError(w, http.StatusRequestEntityTooLarge, "file too large", api.ErrCodeValidation)
+		case errors.Is(err, quota.ErrQuotaExceeded):
+			api.JSONError(w, http.StatusRequestEntityTooLarge, err.Error(), api.ErrCodeValidation)
+		default:
+			api.JSONError(w, http.StatusInternalServerError, "failed to store e-mail", api.ErrCodeInternalError)
+		}
+		return
+	}
+
+	status := http.StatusCreated
+	if result.Duplicate {
+		status = http.StatusOK
+	}
+	api.JSONResponse(w, status, map[string]interface{}{
+		"document":        toResponse(result.Document),
+		"email":           toEmailResponse(result.Email, result.Attachments),
+		"duplicate":       result.Duplicate,
+		"analysis_queued": result.AnalysisQueued,
+	})
+}
+
+// GetEmail returns the headers, sender authentication results and
+// attachments of an uploaded e-mail
+func (h *Handler) GetEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := getTenantID(r)
+	if err != nil {
+		api.JSONError(w, http.StatusNotFound, "e-mail not found", api.ErrCodeNotFound)
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid document ID", api.ErrCodeBadRequest)
+		return
+	}
+
+	email, attachments, err := h.service.GetEmail(ctx, tenantID, id)
+	if err != nil {
+		if errors.Is(err, ErrEmailNotFound) {
+			api.JSONError(w, http.StatusNotFound, "e-mail not found", api.ErrCodeNotFound)
+			return
+		}
+		api.JSONError(w, http.StatusInternalServerError, "failed to get e-mail", api.ErrCodeInternalError)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, toEmailResponse(email, attachments))
+}
+
+// GetEmailThread returns the uploaded e-mails of the conversation an e-mail
+// belongs to, oldest first
+func (h *Handler) GetEmailThread(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := getTenantID(r)
+	if err != nil {
+		api.JSONError(w, http.StatusNotFound, "e-mail not found", api.ErrCodeNotFound)
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid document ID", api.ErrCodeBadRequest)
+		return
+	}
+
+	emails, err := h.service.GetEmailThread(ctx, tenantID, id)
+	if err != nil {
+		if errors.Is(err, ErrEmailNotFound) {
+			api.JSONError(w, http.StatusNotFound, "e-mail not found", api.ErrCodeNotFound)
+			return
+		}
+		api.JSONError(w, http.StatusInternalServerError, "failed to get e-mail thread", api.ErrCodeInternalError)
+		return
+	}
+
+	responses := make([]*EmailResponse, len(emails))
+	for i, email := range emails {
+		responses[i] = toEmailResponse(email, nil)
+	}
+	threadID := ""
+	if len(emails) > 0 {
+		threadID = emails[0].ThreadID
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"thread_id": threadID,
+		"emails":    responses,
+	})
+}
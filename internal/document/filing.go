@@ -0,0 +1,71 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MaxFolderLength is the longest folder path a document can be filed under
+const MaxFolderLength = 255
+
+// Filing places a document in a folder, adds labels and assigns it to a
+// user. Empty fields leave the document unchanged.
+type Filing struct {
+	Folder     string
+	Labels     []string
+	AssignedTo *uuid.UUID
+}
+
+// CleanFolder normalizes a folder path: segments are trimmed and separated
+// by single slashes, without leading or trailing slash
+func CleanFolder(folder string) string {
+	var segments []string
+	for _, segment := range strings.Split(folder, "/") {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// File applies a filing to a document. Labels are added to the existing ones;
+// an assignee outside the tenant is ignored.
+func (r *Repository) File(ctx context.Context, tenantID, id uuid.UUID, f *Filing) error {
+	labels := f.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+
+	access, args := accessCondition(ctx, "d", []interface{}{id, tenantID, f.Folder, labels, f.AssignedTo})
+	result, err := r.db.Exec(ctx, `
+		UPDATE documents d SET
+			folder = COALESCE(NULLIF($3, ''), d.folder),
+			labels = ARRAY(SELECT DISTINCT unnest(d.labels || $4::text[]) ORDER BY 1),
+			assigned_to = COALESCE((SELECT u.id FROM users u WHERE u.id = $5 AND u.tenant_id = $2), d.assigned_to),
+			updated_at = NOW()
+		WHERE d.id = $1 AND d.tenant_id = $2`+access, args...)
+	if err != nil {
+		return fmt.Errorf("file document: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrDocumentNotFound
+	}
+	return nil
+}
+
+// File places a document in a folder, adds labels and assigns it
+func (s *Service) File(ctx context.Context, tenantID, id uuid.UUID, f *Filing) error {
+	f.Folder = CleanFolder(f.Folder)
+	if len(f.Folder) > MaxFolderLength {
+		return fmt.Errorf("folder exceeds %d characters", MaxFolderLength)
+	}
+	return s.repo.File(ctx, tenantID, id, f)
+}
+
+// GetByIDs retrieves several documents of a tenant in the order of ids
+func (s *Service) GetByIDs(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) ([]*Document, error) {
+	return s.repo.GetByIDs(ctx, tenantID, ids)
+}
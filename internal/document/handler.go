@@ -8,12 +8,14 @@ import (
 	"time"
 
 	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/audit"
 	"github.com/google/uuid"
 )
 
 // Handler handles document HTTP requests
 type Handler struct {
-	service *Service
+	service     *Service
+	auditLogger *audit.Logger
 }
 
 // NewHandler creates a new document handler
@@ -43,6 +45,20 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("DELETE /api/v1/documents/{id}", h.Delete)
 	mux.HandleFunc("GET /api/v1/documents/stats", h.GetStats)
 	mux.HandleFunc("GET /api/v1/documents/expired", h.GetExpired)
+	mux.HandleFunc("GET /api/v1/documents/pdfa", h.ListPDFA)
+	mux.HandleFunc("GET /api/v1/documents/{id}/pdfa", h.GetPDFA)
+	mux.HandleFunc("GET /api/v1/documents/{id}/pdfa/content", h.GetPDFAContent)
+	mux.HandleFunc("POST /api/v1/documents/{id}/pdfa", h.ConvertPDFA)
+	mux.HandleFunc("POST /api/v1/documents/email", h.UploadEmail)
+	mux.HandleFunc("GET /api/v1/documents/{id}/email", h.GetEmail)
+	mux.HandleFunc("GET /api/v1/documents/{id}/email/thread", h.GetEmailThread)
+	mux.HandleFunc("GET /api/v1/documents/{id}/relations", h.GetRelations)
+	mux.HandleFunc("POST /api/v1/documents/{id}/relations", h.CreateRelation)
+	mux.HandleFunc("DELETE /api/v1/documents/{id}/relations/{relationId}", h.DeleteRelation)
+	mux.HandleFunc("GET /api/v1/documents/{id}/access", h.GetAccess)
+	mux.HandleFunc("PUT /api/v1/documents/{id}/access", h.SetAccess)
+	mux.HandleFunc("GET /api/v1/documents/folder-access", h.GetFolderAccess)
+	mux.HandleFunc("PUT /api/v1/documents/folder-access", h.SetFolderAccess)
 }
 
 // ListResponse represents the response for listing documents
@@ -77,7 +93,11 @@ type DocumentResponse struct {
 	Status      string                 `json:"status"`
 	Priority    int                    `json:"priority"`
 	ArchivedAt  *time.Time             `json:"archived_at,omitempty"`
+	Folder      string                 `json:"folder,omitempty"`
+	Labels      []string               `json:"labels,omitempty"`
+	AssignedTo  *uuid.UUID             `json:"assigned_to,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Analysis    *AnalysisBadges        `json:"analysis,omitempty"`
 	CreatedAt   time.Time              `json:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at"`
 }
@@ -99,7 +119,11 @@ func toResponse(doc *Document) *DocumentResponse {
 		Status:      doc.Status,
 		Priority:    TypePriority(doc.Type),
 		ArchivedAt:  doc.ArchivedAt,
+		Folder:      doc.Folder,
+		Labels:      doc.Labels,
+		AssignedTo:  doc.AssignedTo,
 		Metadata:    doc.Metadata,
+		Analysis:    doc.Analysis,
 		CreatedAt:   doc.CreatedAt,
 		UpdatedAt:   doc.UpdatedAt,
 	}
@@ -160,6 +184,14 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		filter.Search = search
 	}
 
+	if folder := r.URL.Query().Get("folder"); folder != "" {
+		filter.Folder = folder
+	}
+
+	if label := r.URL.Query().Get("label"); label != "" {
+		filter.Label = label
+	}
+
 	if archived := r.URL.Query().Get("archived"); archived == "true" {
 		filter.Archived = true
 	}
@@ -0,0 +1,550 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"austrian-business-infrastructure/pkg/database"
+)
+
+// Entity types a document can be related to
+const (
+	EntityDocument      = "document"
+	EntityInvoice       = "invoice"
+	EntityUVASubmission = "uva_submission"
+)
+
+// Relation types. Attachments and signed versions are derived from the
+// e-mail and signature tables and cannot be created.
+const (
+	RelationReferences      = "references"
+	RelationAssessmentOf    = "assessment_of" // Bescheid of an UVA
+	RelationReminderFor     = "reminder_for"  // Mahnung of an invoice
+	RelationSupersedes      = "supersedes"    // correction of an earlier document
+	RelationAttachmentOf    = "attachment_of"
+	RelationSignedVersionOf = "signed_version_of"
+)
+
+// Origins of relations
+const (
+	OriginManual     = "manual"
+	OriginExtraction = "extraction"
+	OriginSystem     = "system"
+)
+
+// Kinds of references found in document text
+const (
+	ReferenceInvoiceNumber = "invoice_number"
+	ReferenceUVAPeriod     = "uva_period"
+)
+
+// MaxGraphDepth limits how many hops the graph follows from a document
+const MaxGraphDepth = 2
+
+// maxGraphEdges bounds the edges read per hop
+const maxGraphEdges = 500
+
+// Relation errors
+var (
+	ErrRelationNotFound  = errors.New("relation not found")
+	ErrDuplicateRelation = errors.New("relation already exists")
+	ErrInvalidRelation   = errors.New("invalid relation")
+	ErrTargetNotFound    = errors.New("related entity not found")
+)
+
+// relationTargets lists the target types each creatable relation accepts
+var relationTargets = map[string][]string{
+	RelationReferences:   {EntityDocument, EntityInvoice, EntityUVASubmission},
+	RelationAssessmentOf: {EntityDocument, EntityUVASubmission},
+	RelationReminderFor:  {EntityDocument, EntityInvoice},
+	RelationSupersedes:   {EntityDocument},
+}
+
+// Relation is a stored edge from a document to a related entity
+type Relation struct {
+	ID           uuid.UUID
+	TenantID     uuid.UUID
+	DocumentID   uuid.UUID
+	TargetType   string
+	TargetID     uuid.UUID
+	RelationType string
+	Origin       string
+	Confidence   *float64
+	Evidence     string
+	CreatedBy    *uuid.UUID
+	CreatedAt    time.Time
+}
+
+// Reference is a mention of another entity found in a document's text, e.g.
+// an invoice number in a Mahnung or the UVA period in a Bescheid
+type Reference struct {
+	Kind       string
+	Value      string
+	Year       int
+	Month      int
+	Quarter    int
+	Relation   string
+	Confidence float64
+	SourceText string
+}
+
+// NodeRef identifies an entity in the graph
+type NodeRef struct {
+	Type string    `json:"type"`
+	ID   uuid.UUID `json:"id"`
+}
+
+// GraphNode is an entity in the link graph of a document
+type GraphNode struct {
+	NodeRef
+	Label  string     `json:"label"`
+	Kind   string     `json:"kind,omitempty"`
+	Status string     `json:"status,omitempty"`
+	Date   *time.Time `json:"date,omitempty"`
+}
+
+// GraphEdge is a typed edge between two nodes; derived edges have no ID
+type GraphEdge struct {
+	ID         *uuid.UUID `json:"id,omitempty"`
+	From       NodeRef    `json:"from"`
+	To         NodeRef    `json:"to"`
+	Type       string     `json:"type"`
+	Origin     string     `json:"origin"`
+	Confidence *float64   `json:"confidence,omitempty"`
+	Evidence   string     `json:"evidence,omitempty"`
+}
+
+// Graph is the neighbourhood of a document
+type Graph struct {
+	Root  NodeRef      `json:"root"`
+	Nodes []*GraphNode `json:"nodes"`
+	Edges []*GraphEdge `json:"edges"`
+}
+
+// ValidateRelation checks that a relation type can be created towards the
+// target type
+func ValidateRelation(relationType, targetType string) error {
+	targets, ok := relationTargets[relationType]
+	if !ok {
+		return fmt.Errorf("%w: unknown relation type %q", ErrInvalidRelation, relationType)
+	}
+	for _, t := range targets {
+		if t == targetType {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s cannot point to %s", ErrInvalidRelation, relationType, targetType)
+}
+
+const relationColumns = `id, tenant_id, document_id, target_type, target_id, relation_type, origin,
+	confidence, COALESCE(evidence, ''), created_by, created_at`
+
+func scanRelation(row pgx.Row) (*Relation, error) {
+	rel := &Relation{}
+	err := row.Scan(&rel.ID, &rel.TenantID, &rel.DocumentID, &rel.TargetType, &rel.TargetID,
+		&rel.RelationType, &rel.Origin, &rel.Confidence, &rel.Evidence, &rel.CreatedBy, &rel.CreatedAt)
+	return rel, err
+}
+
+// CreateRelation stores a manual relation
+func (r *Repository) CreateRelation(ctx context.Context, rel *Relation) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO document_relations (tenant_id, document_id, target_type, target_id, relation_type, origin,
+			confidence, evidence, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9)
+		RETURNING id, created_at
+	`, rel.TenantID, rel.DocumentID, rel.TargetType, rel.TargetID, rel.RelationType, rel.Origin,
+		rel.Confidence, rel.Evidence, rel.CreatedBy,
+	).Scan(&rel.ID, &rel.CreatedAt)
+	if err != nil {
+		if isDuplicateError(err) {
+			return ErrDuplicateRelation
+		}
+		return fmt.Errorf("create relation: %w", err)
+	}
+	return nil
+}
+
+// DeleteRelation removes a stored relation of a document
+func (r *Repository) DeleteRelation(ctx context.Context, tenantID, documentID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM document_relations
+		WHERE id = $1 AND tenant_id = $2 AND (document_id = $3 OR (target_type = $4 AND target_id = $3))
+	`, id, tenantID, documentID, EntityDocument)
+	if err != nil {
+		return fmt.Errorf("delete relation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRelationNotFound
+	}
+	return nil
+}
+
+// ReplaceExtractedRelations swaps the relations found by an earlier
+// analysis of the document for new ones. Manual relations are kept and win
+// over an extracted relation with the same target and type.
+func (r *Repository) ReplaceExtractedRelations(ctx context.Context, tenantID, documentID uuid.UUID, relations []*Relation) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM document_relations WHERE tenant_id = $1 AND document_id = $2 AND origin = $3
+	`, tenantID, documentID, OriginExtraction); err != nil {
+		return 0, fmt.Errorf("delete extracted relations: %w", err)
+	}
+
+	created := 0
+	for _, rel := range relations {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO document_relations (tenant_id, document_id, target_type, target_id, relation_type, origin,
+				confidence, evidence)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))
+			ON CONFLICT (document_id, target_type, target_id, relation_type) DO NOTHING
+		`, tenantID, documentID, rel.TargetType, rel.TargetID, rel.RelationType, OriginExtraction,
+			rel.Confidence, rel.Evidence)
+		if err != nil {
+			return 0, fmt.Errorf("create extracted relation: %w", err)
+		}
+		created += int(tag.RowsAffected())
+	}
+	return created, tx.Commit(ctx)
+}
+
+// listRelations returns the stored relations from or to the documents
+func (r *Repository) listRelations(ctx context.Context, tenantID uuid.UUID, documentIDs []uuid.UUID) ([]*Relation, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+relationColumns+` FROM document_relations
+		WHERE tenant_id = $1 AND (document_id = ANY($2) OR (target_type = $3 AND target_id = ANY($2)))
+		ORDER BY created_at
+		LIMIT $4
+	`, tenantID, documentIDs, EntityDocument, maxGraphEdges)
+	if err != nil {
+		return nil, fmt.Errorf("list relations: %w", err)
+	}
+	defer rows.Close()
+
+	var relations []*Relation
+	for rows.Next() {
+		rel, err := scanRelation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan relation: %w", err)
+		}
+		relations = append(relations, rel)
+	}
+	return relations, rows.Err()
+}
+
+// derivedRelations returns the e-mail attachment and signed version edges
+// touching the documents
+func (r *Repository) derivedRelations(ctx context.Context, tenantID uuid.UUID, documentIDs []uuid.UUID) ([]*GraphEdge, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT a.document_id, a.email_document_id, $3::text
+		FROM document_email_attachments a
+		JOIN documents d ON d.id = a.email_document_id AND d.tenant_id = $1
+		WHERE a.document_id = ANY($2) OR a.email_document_id = ANY($2)
+		UNION ALL
+		SELECT s.signed_document_id, s.document_id, $4::text
+		FROM signature_requests s
+		WHERE s.tenant_id = $1 AND s.signed_document_id IS NOT NULL
+			AND (s.document_id = ANY($2) OR s.signed_document_id = ANY($2))
+		LIMIT $5
+	`, tenantID, documentIDs, RelationAttachmentOf, RelationSignedVersionOf, maxGraphEdges)
+	if err != nil {
+		return nil, fmt.Errorf("list derived relations: %w", err)
+	}
+	defer rows.Close()
+
+	var edges []*GraphEdge
+	for rows.Next() {
+		var from, to uuid.UUID
+		var relationType string
+		if err := rows.Scan(&from, &to, &relationType); err != nil {
+			return nil, fmt.Errorf("scan derived relation: %w", err)
+		}
+		edges = append(edges, &GraphEdge{
+			From:   NodeRef{Type: EntityDocument, ID: from},
+			To:     NodeRef{Type: EntityDocument, ID: to},
+			Type:   relationType,
+			Origin: OriginSystem,
+		})
+	}
+	return edges, rows.Err()
+}
+
+// graphNodes loads the labels of the referenced entities of a tenant.
+// Entities that no longer exist or belong to another tenant are missing
+// from the result.
+func (r *Repository) graphNodes(ctx context.Context, tenantID uuid.UUID, refs []NodeRef) (map[NodeRef]*GraphNode, error) {
+	byType := make(map[string][]uuid.UUID)
+	for _, ref := range refs {
+		byType[ref.Type] = append(byType[ref.Type], ref.ID)
+	}
+
+	nodes := make(map[NodeRef]*GraphNode, len(refs))
+	queries := map[string]string{
+		EntityDocument: `
+			SELECT d.id, d.title, d.type, d.status, d.received_at FROM documents d
+			WHERE d.tenant_id = $1 AND d.id = ANY($2)`,
+		EntityInvoice: `
+			SELECT id, invoice_number || ' – ' || customer_name, 'invoice', COALESCE(status, ''), invoice_date::timestamptz
+			FROM invoices WHERE tenant_id = $1 AND id = ANY($2)`,
+		EntityUVASubmission: `
+			SELECT id, 'UVA ' || CASE WHEN period_type = 'quarterly' THEN 'Q' || period_quarter
+				ELSE LPAD(period_month::text, 2, '0') END || '/' || period_year,
+				'uva', COALESCE(status, ''), submitted_at
+			FROM uva_submissions WHERE tenant_id = $1 AND id = ANY($2)`,
+	}
+	for entityType, ids := range byType {
+		query, ok := queries[entityType]
+		if !ok {
+			continue
+		}
+		args := []interface{}{tenantID, database.UniqueIDs(ids)}
+		if entityType == EntityDocument {
+			// Documents hidden from the viewer are left out like missing ones
+			var access string
+			access, args = accessCondition(ctx, "d", args)
+			query += access
+		}
+		rows, err := r.db.Query(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("load %s nodes: %w", entityType, err)
+		}
+		for rows.Next() {
+			node := &GraphNode{NodeRef: NodeRef{Type: entityType}}
+			if err := rows.Scan(&node.ID, &node.Label, &node.Kind, &node.Status, &node.Date); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scan %s node: %w", entityType, err)
+			}
+			nodes[node.NodeRef] = node
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("load %s nodes: %w", entityType, err)
+		}
+	}
+	return nodes, nil
+}
+
+// findInvoiceByNumber returns the invoice of a tenant with the number, or
+// nil when there is none or the number is ambiguous
+func (r *Repository) findInvoiceByNumber(ctx context.Context, tenantID uuid.UUID, number string) (*uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id FROM invoices WHERE tenant_id = $1 AND UPPER(invoice_number) = UPPER($2) LIMIT 2
+	`, tenantID, number)
+	if err != nil {
+		return nil, fmt.Errorf("find invoice: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil || len(ids) != 1 {
+		return nil, err
+	}
+	return &ids[0], nil
+}
+
+// findUVASubmission returns the latest UVA submission of an account for a
+// period; quarter is used when month is zero
+func (r *Repository) findUVASubmission(ctx context.Context, tenantID, accountID uuid.UUID, year, month, quarter int) (*uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, `
+		SELECT id FROM uva_submissions
+		WHERE tenant_id = $1 AND account_id = $2 AND period_year = $3
+			AND (($4 > 0 AND period_month = $4) OR ($4 = 0 AND period_quarter = $5))
+		ORDER BY submitted_at DESC NULLS LAST, created_at DESC
+		LIMIT 1
+	`, tenantID, accountID, year, month, quarter).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find UVA submission: %w", err)
+	}
+	return &id, nil
+}
+
+// CreateRelationInput is a manual relation from a document
+type CreateRelationInput struct {
+	TargetType   string
+	TargetID     uuid.UUID
+	RelationType string
+	CreatedBy    *uuid.UUID
+}
+
+// CreateRelation relates a document to another entity of the tenant
+func (s *Service) CreateRelation(ctx context.Context, tenantID, documentID uuid.UUID, input *CreateRelationInput) (*Relation, error) {
+	if err := ValidateRelation(input.RelationType, input.TargetType); err != nil {
+		return nil, err
+	}
+	if input.TargetType == EntityDocument && input.TargetID == documentID {
+		return nil, fmt.Errorf("%w: a document cannot relate to itself", ErrInvalidRelation)
+	}
+	if _, err := s.repo.GetByID(ctx, tenantID, documentID); err != nil {
+		return nil, err
+	}
+	target := NodeRef{Type: input.TargetType, ID: input.TargetID}
+	nodes, err := s.repo.graphNodes(ctx, tenantID, []NodeRef{target})
+	if err != nil {
+		return nil, err
+	}
+	if nodes[target] == nil {
+		return nil, ErrTargetNotFound
+	}
+
+	rel := &Relation{
+		TenantID:     tenantID,
+		DocumentID:   documentID,
+		TargetType:   input.TargetType,
+		TargetID:     input.TargetID,
+		RelationType: input.RelationType,
+		Origin:       OriginManual,
+		CreatedBy:    input.CreatedBy,
+	}
+	if err := s.repo.CreateRelation(ctx, rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// DeleteRelation removes a stored relation from or to a document
+func (s *Service) DeleteRelation(ctx context.Context, tenantID, documentID, relationID uuid.UUID) error {
+	return s.repo.DeleteRelation(ctx, tenantID, documentID, relationID)
+}
+
+// GetGraph returns the entities related to a document, following relations
+// between documents for up to depth hops
+func (s *Service) GetGraph(ctx context.Context, tenantID, documentID uuid.UUID, depth int) (*Graph, error) {
+	if depth < 1 {
+		depth = 1
+	}
+	depth = min(depth, MaxGraphDepth)
+	if _, err := s.repo.GetByID(ctx, tenantID, documentID); err != nil {
+		return nil, err
+	}
+
+	root := NodeRef{Type: EntityDocument, ID: documentID}
+	refs := []NodeRef{root}
+	seenRefs := map[NodeRef]bool{root: true}
+	seenEdges := make(map[string]bool)
+	var edges []*GraphEdge
+
+	frontier := []uuid.UUID{documentID}
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		stored, err := s.repo.listRelations(ctx, tenantID, frontier)
+		if err != nil {
+			return nil, err
+		}
+		derived, err := s.repo.derivedRelations(ctx, tenantID, frontier)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range stored {
+			derived = append(derived, relationEdge(rel))
+		}
+
+		frontier = nil
+		for _, edge := range derived {
+			key := edge.From.Type + ":" + edge.From.ID.String() + ">" + edge.Type + ">" + edge.To.Type + ":" + edge.To.ID.String()
+			if seenEdges[key] {
+				continue
+			}
+			seenEdges[key] = true
+			edges = append(edges, edge)
+			for _, ref := range []NodeRef{edge.From, edge.To} {
+				if seenRefs[ref] {
+					continue
+				}
+				seenRefs[ref] = true
+				refs = append(refs, ref)
+				if ref.Type == EntityDocument {
+					frontier = append(frontier, ref.ID)
+				}
+			}
+		}
+	}
+
+	nodes, err := s.repo.graphNodes(ctx, tenantID, refs)
+	if err != nil {
+		return nil, err
+	}
+	graph := &Graph{Root: root, Nodes: make([]*GraphNode, 0, len(nodes)), Edges: make([]*GraphEdge, 0, len(edges))}
+	for _, ref := range refs {
+		if node := nodes[ref]; node != nil {
+			graph.Nodes = append(graph.Nodes, node)
+		}
+	}
+	for _, edge := range edges {
+		if nodes[edge.From] != nil && nodes[edge.To] != nil {
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+	return graph, nil
+}
+
+func relationEdge(rel *Relation) *GraphEdge {
+	id := rel.ID
+	return &GraphEdge{
+		ID:         &id,
+		From:       NodeRef{Type: EntityDocument, ID: rel.DocumentID},
+		To:         NodeRef{Type: rel.TargetType, ID: rel.TargetID},
+		Type:       rel.RelationType,
+		Origin:     rel.Origin,
+		Confidence: rel.Confidence,
+		Evidence:   rel.Evidence,
+	}
+}
+
+// LinkReferences relates a document to the invoices and UVA submissions its
+// analysis found references to, replacing the relations of an earlier
+// analysis. References that match nothing or several entities are skipped.
+// It returns the number of relations created.
+func (s *Service) LinkReferences(ctx context.Context, tenantID, documentID uuid.UUID, refs []Reference) (int, error) {
+	doc, err := s.repo.GetByID(ctx, tenantID, documentID)
+	if err != nil {
+		return 0, err
+	}
+
+	var relations []*Relation
+	for _, ref := range refs {
+		var targetType string
+		var targetID *uuid.UUID
+		switch ref.Kind {
+		case ReferenceInvoiceNumber:
+			targetType = EntityInvoice
+			targetID, err = s.repo.findInvoiceByNumber(ctx, tenantID, strings.TrimSpace(ref.Value))
+		case ReferenceUVAPeriod:
+			targetType = EntityUVASubmission
+			targetID, err = s.repo.findUVASubmission(ctx, tenantID, doc.AccountID, ref.Year, ref.Month, ref.Quarter)
+		default:
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if targetID == nil {
+			continue
+		}
+
+		relationType := ref.Relation
+		if ValidateRelation(relationType, targetType) != nil {
+			relationType = RelationReferences
+		}
+		confidence := ref.Confidence
+		relations = append(relations, &Relation{
+			TargetType:   targetType,
+			TargetID:     *targetID,
+			RelationType: relationType,
+			Confidence:   &confidence,
+			Evidence:     ref.SourceText,
+		})
+	}
+	return s.repo.ReplaceExtractedRelations(ctx, tenantID, documentID, relations)
+}
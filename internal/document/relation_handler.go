@@ -0,0 +1,178 @@
+package document
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// RelationResponse represents a stored relation in API responses
+type RelationResponse struct {
+	ID           string    `json:"id"`
+	DocumentID   string    `json:"document_id"`
+	TargetType   string    `json:"target_type"`
+	TargetID     string    `json:"target_id"`
+	RelationType string    `json:"type"`
+	Origin       string    `json:"origin"`
+	Confidence   *float64  `json:"confidence,omitempty"`
+	Evidence     string    `json:"evidence,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func toRelationResponse(rel *Relation) *RelationResponse {
+	return &RelationResponse{
+		ID:           rel.ID.String(),
+		DocumentID:   rel.DocumentID.String(),
+		TargetType:   rel.TargetType,
+		TargetID:     rel.TargetID.String(),
+		RelationType: rel.RelationType,
+		Origin:       rel.Origin,
+		Confidence:   rel.Confidence,
+		Evidence:     rel.Evidence,
+		CreatedAt:    rel.CreatedAt,
+	}
+}
+
+// CreateRelationRequest is the body of POST /api/v1/documents/{id}/relations
+type CreateRelationRequest struct {
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	Type       string `json:"type"`
+}
+
+// GetRelations returns the link graph of a document for the detail view;
+// ?depth=2 also follows the relations of related documents
+func (h *Handler) GetRelations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := getTenantID(r)
+	if err != nil {
+		api.JSONError(w, http.StatusNotFound, "document not found", api.ErrCodeNotFound)
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid document ID", api.ErrCodeBadRequest)
+		return
+	}
+
+	depth := 1
+	if depthStr := r.URL.Query().Get("depth"); depthStr != "" {
+		parsed, err := strconv.Atoi(depthStr)
+		if err != nil || parsed < 1 || parsed > MaxGraphDepth {
+			api.JSONError(w, http.StatusBadRequest, "depth must be 1 or 2", api.ErrCodeBadRequest)
+			return
+		}
+		depth = parsed
+	}
+
+	graph, err := h.service.GetGraph(ctx, tenantID, id, depth)
+	if err != nil {
+		if errors.Is(err, ErrDocumentNotFound) {
+			api.JSONError(w, http.StatusNotFound, "document not found", api.ErrCodeNotFound)
+			return
+		}
+		api.JSONError(w, http.StatusInternalServerError, "failed to get relations", api.ErrCodeInternalError)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, graph)
+}
+
+// CreateRelation relates a document to another document, an invoice or an
+// UVA submission
+func (h *Handler) CreateRelation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := getTenantID(r)
+	if err != nil {
+		api.JSONError(w, http.StatusNotFound, "document not found", api.ErrCodeNotFound)
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid document ID", api.ErrCodeBadRequest)
+		return
+	}
+
+	var req CreateRelationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid request body", api.ErrCodeBadRequest)
+		return
+	}
+	targetID, err := uuid.Parse(req.TargetID)
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid target ID", api.ErrCodeBadRequest)
+		return
+	}
+
+	input := &CreateRelationInput{
+		TargetType:   req.TargetType,
+		TargetID:     targetID,
+		RelationType: req.Type,
+	}
+	if userID, err := uuid.Parse(api.GetUserID(ctx)); err == nil {
+		input.CreatedBy = &userID
+	}
+
+	rel, err := h.service.CreateRelation(ctx, tenantID, id, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidRelation):
+			api.JSONError(w, http.StatusBadRequest, err.Error(), api.ErrCodeValidation)
+		case errors.Is(err, ErrDocumentNotFound):
+			api.JSONError(w, http.StatusNotFound, "document not found", api.ErrCodeNotFound)
+		case errors.Is(err, ErrTargetNotFound):
+			api.JSONError(w, http.StatusNotFound, "related entity not found", api.ErrCodeNotFound)
+		case errors.Is(err, ErrDuplicateRelation):
+			api.JSONError(w, http.StatusConflict, "relation already exists", api.ErrCodeConflict)
+		default:
+			api.JSONError(w, http.StatusInternalServerError, "failed to create relation", api.ErrCodeInternalError)
+		}
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, toRelationResponse(rel))
+}
+
+// DeleteRelation removes a stored relation from or to a document. Derived
+// relations (e-mail attachments, signed versions) cannot be removed.
+func (h *Handler) DeleteRelation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := getTenantID(r)
+	if err != nil {
+		api.JSONError(w, http.StatusNotFound, "relation not found", api.ErrCodeNotFound)
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid document ID", api.ErrCodeBadRequest)
+		return
+	}
+	relationID, err := uuid.Parse(r.PathValue("relationId"))
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid relation ID", api.ErrCodeBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteRelation(ctx, tenantID, id, relationID); err != nil {
+		if errors.Is(err, ErrRelationNotFound) {
+			api.JSONError(w, http.StatusNotFound, "relation not found", api.ErrCodeNotFound)
+			return
+		}
+		api.JSONError(w, http.StatusInternalServerError, "failed to delete relation", api.ErrCodeInternalError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
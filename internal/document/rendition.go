@@ -0,0 +1,380 @@
+package document
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Rendition kinds
+const (
+	RenditionPDFA = "pdfa"
+)
+
+// Rendition statuses
+const (
+	RenditionConverted   = "converted"
+	RenditionFailed      = "failed"
+	RenditionUnsupported = "unsupported"
+)
+
+// Rendition errors
+var (
+	ErrRenditionNotFound   = errors.New("rendition not found")
+	ErrArchivalDisabled    = errors.New("archival conversion is not enabled")
+	ErrArchivalUnsupported = errors.New("document format cannot be archived as PDF/A")
+)
+
+// RenditionIssue is a conformance problem found when validating a rendition
+type RenditionIssue struct {
+	Clause  string `json:"clause,omitempty"`
+	Message string `json:"message"`
+}
+
+// Rendition is an archival copy of a document, such as its PDF/A version.
+// Failed and unsupported conversions are recorded too, without a file.
+type Rendition struct {
+	ID          uuid.UUID
+	DocumentID  uuid.UUID
+	TenantID    uuid.UUID
+	Kind        string
+	Status      string
+	StoragePath string
+	FileSize    int64
+	ContentHash string
+	Conformance string
+	Validator   string
+	Issues      []RenditionIssue
+	Error       string
+	Attempts    int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// Joined from the document for list queries
+	DocumentTitle string
+	MimeType      string
+}
+
+// ArchivalScheduler schedules the archival conversion of documents
+type ArchivalScheduler interface {
+	// SupportsArchival reports whether documents of the MIME type can be
+	// converted
+	SupportsArchival(mimeType string) bool
+	ScheduleArchival(ctx context.Context, tenantID, documentID uuid.UUID) error
+}
+
+const renditionColumns = `r.id, r.document_id, r.tenant_id, r.kind, r.status, r.storage_path,
+	r.file_size, r.content_hash, r.conformance, r.validator, r.issues, r.error,
+	r.attempts, r.created_at, r.updated_at, d.title, d.mime_type`
+
+func scanRendition(row pgx.Row) (*Rendition, error) {
+	r := &Rendition{}
+	var issues []byte
+	err := row.Scan(
+		&r.ID, &r.DocumentID, &r.TenantID, &r.Kind, &r.Status, &r.StoragePath,
+		&r.FileSize, &r.ContentHash, &r.Conformance, &r.Validator, &issues, &r.Error,
+		&r.Attempts, &r.CreatedAt, &r.UpdatedAt, &r.DocumentTitle, &r.MimeType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(issues) > 0 {
+		json.Unmarshal(issues, &r.Issues)
+	}
+	return r, nil
+}
+
+// UpsertRendition records the outcome of a conversion, replacing the previous
+// one of the same kind and counting the attempts
+func (r *Repository) UpsertRendition(ctx context.Context, rendition *Rendition) error {
+	issues, err := json.Marshal(rendition.Issues)
+	if err != nil {
+		return fmt.Errorf("marshal issues: %w", err)
+	}
+	if rendition.Issues == nil {
+		issues = []byte("[]")
+	}
+
+	query := `
+		INSERT INTO document_renditions (
+			document_id, tenant_id, kind, status, storage_path, file_size,
+			content_hash, conformance, validator, issues, error
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (document_id, kind) DO UPDATE SET
+			status = EXCLUDED.status,
+			storage_path = EXCLUDED.storage_path,
+			file_size = EXCLUDED.file_size,
+			content_hash = EXCLUDED.content_hash,
+			conformance = EXCLUDED.conformance,
+			validator = EXCLUDED.validator,
+			issues = EXCLUDED.issues,
+			error = EXCLUDED.error,
+			attempts = document_renditions.attempts + 1,
+			updated_at = NOW()
+		RETURNING id, attempts, created_at, updated_at
+	`
+
+	err = r.db.QueryRow(ctx, query,
+		rendition.DocumentID, rendition.TenantID, rendition.Kind, rendition.Status,
+		rendition.StoragePath, rendition.FileSize, rendition.ContentHash,
+		rendition.Conformance, rendition.Validator, issues, rendition.Error,
+	).Scan(&rendition.ID, &rendition.Attempts, &rendition.CreatedAt, &rendition.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upsert rendition: %w", err)
+	}
+	return nil
+}
+
+// GetRendition returns the rendition of a kind of a document with tenant
+// isolation
+func (r *Repository) GetRendition(ctx context.Context, tenantID, documentID uuid.UUID, kind string) (*Rendition, error) {
+	access, args := accessCondition(ctx, "d", []interface{}{documentID, tenantID, kind})
+	query := `
+		SELECT ` + renditionColumns + `
+		FROM document_renditions r
+		JOIN documents d ON d.id = r.document_id
+		WHERE r.document_id = $1 AND r.tenant_id = $2 AND r.kind = $3` + access + `
+	`
+
+	rendition, err := scanRendition(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRenditionNotFound
+		}
+		return nil, fmt.Errorf("get rendition: %w", err)
+	}
+	return rendition, nil
+}
+
+// ListRenditions returns the renditions of a kind of a tenant, optionally
+// only those with a status, most recently updated first
+func (r *Repository) ListRenditions(ctx context.Context, tenantID uuid.UUID, kind, status string, limit, offset int) ([]*Rendition, int, error) {
+	if limit <= 0 || limit > MaxPageSize {
+		limit = DefaultPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	where := `WHERE r.tenant_id = $1 AND r.kind = $2`
+	args := []interface{}{tenantID, kind}
+	if status != "" {
+		where += ` AND r.status = $3`
+		args = append(args, status)
+	}
+	access, args := accessCondition(ctx, "d", args)
+	where += access
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM document_renditions r JOIN documents d ON d.id = r.document_id ` + where
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count renditions: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM document_renditions r
+		JOIN documents d ON d.id = r.document_id
+		%s
+		ORDER BY r.updated_at DESC
+		LIMIT $%d OFFSET $%d
+	`, renditionColumns, where, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list renditions: %w", err)
+	}
+	defer rows.Close()
+
+	var renditions []*Rendition
+	for rows.Next() {
+		rendition, err := scanRendition(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan rendition: %w", err)
+		}
+		renditions = append(renditions, rendition)
+	}
+	return renditions, total, rows.Err()
+}
+
+// ListRenditionPaths returns the storage paths of the stored renditions of a
+// document
+func (r *Repository) ListRenditionPaths(ctx context.Context, tenantID, documentID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT storage_path FROM document_renditions
+		WHERE document_id = $1 AND tenant_id = $2 AND storage_path <> ''
+	`
+
+	rows, err := r.db.Query(ctx, query, documentID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list rendition paths: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, fmt.Errorf("scan rendition path: %w", err)
+		}
+		paths = append(paths, p)
+	}
+	return paths, rows.Err()
+}
+
+// ListMissingRenditions returns documents of the given MIME types that have
+// no rendition of a kind yet, oldest first, across all tenants. Documents
+// with a conversion job still queued are skipped.
+func (r *Repository) ListMissingRenditions(ctx context.Context, kind, jobType string, mimeTypes []string, limit int) ([]*Document, error) {
+	query := `
+		SELECT d.id, d.tenant_id, d.mime_type
+		FROM documents d
+		WHERE d.mime_type = ANY($2)
+		AND NOT EXISTS (
+			SELECT 1 FROM document_renditions r
+			WHERE r.document_id = d.id AND r.kind = $1
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM jobs j
+			WHERE j.type = $3 AND j.status IN ('pending', 'running')
+			AND j.payload->>'document_id' = d.id::text
+		)
+		ORDER BY d.created_at ASC
+		LIMIT $4
+	`
+
+	rows, err := r.db.Query(ctx, query, kind, mimeTypes, jobType, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list documents without rendition: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []*Document
+	for rows.Next() {
+		doc := &Document{}
+		if err := rows.Scan(&doc.ID, &doc.TenantID, &doc.MimeType); err != nil {
+			return nil, fmt.Errorf("scan document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// SetArchivalScheduler sets the scheduler that queues the archival conversion
+// of new documents
+func (s *Service) SetArchivalScheduler(scheduler ArchivalScheduler) {
+	s.archivalScheduler = scheduler
+}
+
+// scheduleArchival queues the conversion of a new document. Errors are not
+// returned: the periodic sweep picks up documents whose job was not queued.
+func (s *Service) scheduleArchival(ctx context.Context, tenantID string, doc *Document) {
+	if s.archivalScheduler == nil || !s.archivalScheduler.SupportsArchival(doc.MimeType) {
+		return
+	}
+	tenantUUID, err := uuid.Parse(tenantID)
+	if err != nil {
+		return
+	}
+	s.archivalScheduler.ScheduleArchival(ctx, tenantUUID, doc.ID)
+}
+
+// ScheduleArchival queues the archival conversion of a document again, for
+// example after a failed conversion
+func (s *Service) ScheduleArchival(ctx context.Context, tenantID, id uuid.UUID) error {
+	if s.archivalScheduler == nil {
+		return ErrArchivalDisabled
+	}
+	doc, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if !s.archivalScheduler.SupportsArchival(doc.MimeType) {
+		return ErrArchivalUnsupported
+	}
+	return s.archivalScheduler.ScheduleArchival(ctx, doc.TenantID, doc.ID)
+}
+
+// SaveRendition stores the file of a converted rendition next to the original
+// and records it; without content only the outcome is recorded. The file of
+// a previous rendition of the kind is removed.
+func (s *Service) SaveRendition(ctx context.Context, doc *Document, rendition *Rendition, content []byte) error {
+	rendition.DocumentID = doc.ID
+	rendition.TenantID = doc.TenantID
+
+	previous, err := s.repo.GetRendition(ctx, doc.TenantID, doc.ID, rendition.Kind)
+	if err != nil && !errors.Is(err, ErrRenditionNotFound) {
+		return err
+	}
+
+	if content != nil {
+		info, err := s.storage.Store(ctx, doc.TenantID.String(), doc.AccountID.String(),
+			renditionFilename(doc.StoragePath, rendition.Kind), newBytesReader(content), "application/pdf")
+		if err != nil {
+			return fmt.Errorf("store rendition: %w", err)
+		}
+		rendition.StoragePath = info.Path
+		rendition.FileSize = info.Size
+		rendition.ContentHash = calculateHash(content)
+	} else {
+		rendition.StoragePath = ""
+		rendition.FileSize = 0
+		rendition.ContentHash = ""
+	}
+
+	if err := s.repo.UpsertRendition(ctx, rendition); err != nil {
+		if rendition.StoragePath != "" && (previous == nil || previous.StoragePath != rendition.StoragePath) {
+			s.storage.Delete(ctx, rendition.StoragePath)
+		}
+		return err
+	}
+
+	if previous != nil && previous.StoragePath != "" && previous.StoragePath != rendition.StoragePath {
+		s.storage.Delete(ctx, previous.StoragePath)
+	}
+	return nil
+}
+
+// renditionFilename derives the file name of a rendition from the original,
+// e.g. "bescheid.docx" becomes "bescheid.pdfa.pdf"
+func renditionFilename(originalPath, kind string) string {
+	name := path.Base(originalPath)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	return sanitizeFilename(name) + "." + kind + ".pdf"
+}
+
+// GetRendition returns the rendition of a kind of a document
+func (s *Service) GetRendition(ctx context.Context, tenantID, id uuid.UUID, kind string) (*Rendition, error) {
+	return s.repo.GetRendition(ctx, tenantID, id, kind)
+}
+
+// GetRenditionContent returns the file of a converted rendition
+func (s *Service) GetRenditionContent(ctx context.Context, tenantID, id uuid.UUID, kind string) (io.ReadCloser, *StorageInfo, error) {
+	rendition, err := s.repo.GetRendition(ctx, tenantID, id, kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rendition.Status != RenditionConverted || rendition.StoragePath == "" {
+		return nil, nil, ErrRenditionNotFound
+	}
+	return s.storage.Get(ctx, rendition.StoragePath)
+}
+
+// ListRenditions returns the renditions of a kind of a tenant
+func (s *Service) ListRenditions(ctx context.Context, tenantID uuid.UUID, kind, status string, limit, offset int) ([]*Rendition, int, error) {
+	return s.repo.ListRenditions(ctx, tenantID, kind, status, limit, offset)
+}
+
+// ListMissingRenditions returns documents still lacking a rendition of a kind
+func (s *Service) ListMissingRenditions(ctx context.Context, kind, jobType string, mimeTypes []string, limit int) ([]*Document, error) {
+	return s.repo.ListMissingRenditions(ctx, kind, jobType, mimeTypes, limit)
+}
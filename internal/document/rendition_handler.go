@@ -0,0 +1,197 @@
+package document
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// RenditionResponse represents an archival rendition in API responses
+type RenditionResponse struct {
+	DocumentID    string           `json:"document_id"`
+	DocumentTitle string           `json:"document_title"`
+	MimeType      string           `json:"mime_type"`
+	Kind          string           `json:"kind"`
+	Status        string           `json:"status"`
+	Conformance   string           `json:"conformance,omitempty"`
+	Validator     string           `json:"validator,omitempty"`
+	FileSize      int64            `json:"file_size,omitempty"`
+	ContentHash   string           `json:"content_hash,omitempty"`
+	Issues        []RenditionIssue `json:"issues,omitempty"`
+	Error         string           `json:"error,omitempty"`
+	Attempts      int              `json:"attempts"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+func toRenditionResponse(r *Rendition) *RenditionResponse {
+	return &RenditionResponse{
+		DocumentID:    r.DocumentID.String(),
+		DocumentTitle: r.DocumentTitle,
+		MimeType:      r.MimeType,
+		Kind:          r.Kind,
+		Status:        r.Status,
+		Conformance:   r.Conformance,
+		Validator:     r.Validator,
+		FileSize:      r.FileSize,
+		ContentHash:   r.ContentHash,
+		Issues:        r.Issues,
+		Error:         r.Error,
+		Attempts:      r.Attempts,
+		CreatedAt:     r.CreatedAt,
+		UpdatedAt:     r.UpdatedAt,
+	}
+}
+
+// ListPDFA lists the PDF/A renditions of the tenant; ?status=failed lists the
+// documents that could not be archived
+func (h *Handler) ListPDFA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := getTenantID(r)
+	if err != nil {
+		api.JSONError(w, http.StatusUnauthorized, "unauthorized", api.ErrCodeUnauthorized)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", RenditionConverted, RenditionFailed, RenditionUnsupported:
+	default:
+		api.JSONError(w, http.StatusBadRequest, "invalid status", api.ErrCodeBadRequest)
+		return
+	}
+
+	limit := DefaultPageSize
+	offset := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if parsed, err := strconv.Atoi(offsetStr); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	renditions, total, err := h.service.ListRenditions(ctx, tenantID, RenditionPDFA, status, limit, offset)
+	if err != nil {
+		api.JSONError(w, http.StatusInternalServerError, "failed to list renditions", api.ErrCodeInternalError)
+		return
+	}
+
+	responses := make([]*RenditionResponse, len(renditions))
+	for i, rendition := range renditions {
+		responses[i] = toRenditionResponse(rendition)
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"renditions": responses,
+		"total":      total,
+		"limit":      limit,
+		"offset":     offset,
+		"has_more":   offset+len(renditions) < total,
+	})
+}
+
+// GetPDFA returns the state of the PDF/A rendition of a document
+func (h *Handler) GetPDFA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := getTenantID(r)
+	if err != nil {
+		api.JSONError(w, http.StatusNotFound, "document not found", api.ErrCodeNotFound)
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid document ID", api.ErrCodeBadRequest)
+		return
+	}
+
+	rendition, err := h.service.GetRendition(ctx, tenantID, id, RenditionPDFA)
+	if err != nil {
+		if errors.Is(err, ErrRenditionNotFound) {
+			api.JSONError(w, http.StatusNotFound, "no PDF/A rendition yet", api.ErrCodeNotFound)
+			return
+		}
+		api.JSONError(w, http.StatusInternalServerError, "failed to get rendition", api.ErrCodeInternalError)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, toRenditionResponse(rendition))
+}
+
+// GetPDFAContent downloads the PDF/A rendition of a document
+func (h *Handler) GetPDFAContent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := getTenantID(r)
+	if err != nil {
+		api.JSONError(w, http.StatusNotFound, "document not found", api.ErrCodeNotFound)
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid document ID", api.ErrCodeBadRequest)
+		return
+	}
+
+	content, info, err := h.service.GetRenditionContent(ctx, tenantID, id, RenditionPDFA)
+	if err != nil {
+		if errors.Is(err, ErrRenditionNotFound) || errors.Is(err, ErrStorageNotFound) {
+			api.JSONError(w, http.StatusNotFound, "no PDF/A rendition available", api.ErrCodeNotFound)
+			return
+		}
+		api.JSONError(w, http.StatusInternalServerError, "failed to get rendition content", api.ErrCodeInternalError)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	io.Copy(w, content)
+}
+
+// ConvertPDFA queues the PDF/A conversion of a document again, for example
+// after a failed conversion was fixed
+func (h *Handler) ConvertPDFA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tenantID, err := getTenantID(r)
+	if err != nil {
+		api.JSONError(w, http.StatusNotFound, "document not found", api.ErrCodeNotFound)
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid document ID", api.ErrCodeBadRequest)
+		return
+	}
+
+	if err := h.service.ScheduleArchival(ctx, tenantID, id); err != nil {
+		switch {
+		case errors.Is(err, ErrDocumentNotFound):
+			api.JSONError(w, http.StatusNotFound, "document not found", api.ErrCodeNotFound)
+		case errors.Is(err, ErrArchivalDisabled):
+			api.JSONError(w, http.StatusServiceUnavailable, err.Error(), api.ErrCodeServiceUnavailable)
+		case errors.Is(err, ErrArchivalUnsupported):
+			api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+		default:
+			api.JSONError(w, http.StatusInternalServerError, "failed to queue conversion", api.ErrCodeInternalError)
+		}
+		return
+	}
+
+	api.JSONResponse(w, http.StatusAccepted, map[string]string{"status": "queued"})
+}
@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/pkg/database"
 )
 
 // Pagination limits
@@ -44,6 +47,9 @@ type Document struct {
 	ArchivedAt     *time.Time
 	RetentionUntil *time.Time
 	Deadline       *time.Time
+	Folder         string
+	Labels         []string
+	AssignedTo     *uuid.UUID
 	Metadata       map[string]interface{}
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
@@ -51,6 +57,9 @@ type Document struct {
 	// Joined fields for list queries
 	AccountName string
 	AccountType string
+	// Analysis is set by Service.List; nil if the document has no analysis
+	// and no open deadlines or action items
+	Analysis *AnalysisBadges
 }
 
 // DocumentFilter holds filter criteria for listing documents
@@ -61,6 +70,8 @@ type DocumentFilter struct {
 	Status      string
 	Type        string
 	Search      string
+	Folder      string
+	Label       string
 	DateFrom    *time.Time
 	DateTo      *time.Time
 	Archived    bool
@@ -68,6 +79,8 @@ type DocumentFilter struct {
 	Offset      int
 	SortBy      string
 	SortDesc    bool
+	// WithSummaries joins the precomputed analysis badges
+	WithSummaries bool
 }
 
 // DocumentStats holds statistics about documents
@@ -121,21 +134,22 @@ func (r *Repository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*Docu
 	query := `
 		SELECT d.id, d.account_id, d.tenant_id, d.external_id, d.type, d.title, d.sender,
 			d.received_at, d.content_hash, d.storage_path, d.file_size, d.mime_type,
-			d.status, d.archived_at, d.retention_until, d.metadata, d.created_at, d.updated_at,
-			a.name as account_name, a.type as account_type
+			d.status, d.archived_at, d.retention_until, COALESCE(d.folder, ''), d.labels, d.assigned_to,
+			d.metadata, d.created_at, d.updated_at, a.name as account_name, a.type as account_type
 		FROM documents d
 		JOIN accounts a ON d.account_id = a.id
 		WHERE d.id = $1 AND d.tenant_id = $2
 	`
+	access, args := accessCondition(ctx, "d", []interface{}{id, tenantID})
 
 	doc := &Document{}
 	var metadata []byte
 
-	err := r.db.QueryRow(ctx, query, id, tenantID).Scan(
+	err := r.db.QueryRow(ctx, query+access, args...).Scan(
 		&doc.ID, &doc.AccountID, &doc.TenantID, &doc.ExternalID, &doc.Type, &doc.Title, &doc.Sender,
 		&doc.ReceivedAt, &doc.ContentHash, &doc.StoragePath, &doc.FileSize, &doc.MimeType,
-		&doc.Status, &doc.ArchivedAt, &doc.RetentionUntil, &metadata, &doc.CreatedAt, &doc.UpdatedAt,
-		&doc.AccountName, &doc.AccountType,
+		&doc.Status, &doc.ArchivedAt, &doc.RetentionUntil, &doc.Folder, &doc.Labels, &doc.AssignedTo,
+		&metadata, &doc.CreatedAt, &doc.UpdatedAt, &doc.AccountName, &doc.AccountType,
 	)
 
 	if err != nil {
@@ -149,6 +163,60 @@ func (r *Repository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*Docu
 	return doc, nil
 }
 
+// GetByIDs retrieves several documents of a tenant with one query, in the
+// order of ids. IDs not found in the tenant are skipped.
+func (r *Repository) GetByIDs(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) ([]*Document, error) {
+	ids = database.UniqueIDs(ids)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT d.id, d.account_id, d.tenant_id, d.external_id, d.type, d.title, d.sender,
+			d.received_at, d.content_hash, d.storage_path, d.file_size, d.mime_type,
+			d.status, d.archived_at, d.retention_until, COALESCE(d.folder, ''), d.labels, d.assigned_to,
+			d.metadata, d.created_at, d.updated_at, a.name as account_name, a.type as account_type
+		FROM documents d
+		JOIN accounts a ON d.account_id = a.id
+		WHERE d.id = ANY($1) AND d.tenant_id = $2
+	`
+	access, args := accessCondition(ctx, "d", []interface{}{ids, tenantID})
+
+	rows, err := r.db.Query(ctx, query+access, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get documents: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[uuid.UUID]*Document, len(ids))
+	for rows.Next() {
+		doc := &Document{}
+		var metadata []byte
+		err := rows.Scan(
+			&doc.ID, &doc.AccountID, &doc.TenantID, &doc.ExternalID, &doc.Type, &doc.Title, &doc.Sender,
+			&doc.ReceivedAt, &doc.ContentHash, &doc.StoragePath, &doc.FileSize, &doc.MimeType,
+			&doc.Status, &doc.ArchivedAt, &doc.RetentionUntil, &doc.Folder, &doc.Labels, &doc.AssignedTo,
+			&metadata, &doc.CreatedAt, &doc.UpdatedAt, &doc.AccountName, &doc.AccountType,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan document: %w", err)
+		}
+		doc.Metadata = parseMetadata(metadata)
+		byID[doc.ID] = doc
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get documents: %w", err)
+	}
+
+	docs := make([]*Document, 0, len(byID))
+	for _, id := range ids {
+		if doc, ok := byID[id]; ok {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
 // GetByExternalID retrieves a document by external ID and account
 func (r *Repository) GetByExternalID(ctx context.Context, accountID uuid.UUID, externalID string) (*Document, error) {
 	query := `
@@ -213,21 +281,29 @@ func (r *Repository) GetByContentHash(ctx context.Context, accountID uuid.UUID,
 // List returns documents matching the filter
 func (r *Repository) List(ctx context.Context, filter *DocumentFilter) ([]*Document, int, error) {
 	// Build query with filters
-	baseQuery := `
+	columns := `
 		SELECT d.id, d.account_id, d.external_id, d.type, d.title, d.sender,
 			d.received_at, d.content_hash, d.storage_path, d.file_size, d.mime_type,
-			d.status, d.archived_at, d.retention_until, d.metadata, d.created_at, d.updated_at,
-			a.name as account_name, a.type as account_type
+			d.status, d.archived_at, d.retention_until, COALESCE(d.folder, ''), d.labels, d.assigned_to,
+			d.metadata, d.created_at, d.updated_at, a.name as account_name, a.type as account_type`
+	joins := `
 		FROM documents d
-		JOIN accounts a ON d.account_id = a.id
-		WHERE a.tenant_id = $1
+		JOIN accounts a ON d.account_id = a.id`
+	if filter.WithSummaries {
+		columns += ",\n\t\t\t" + badgeColumns
+		joins += `
+		LEFT JOIN document_list_summaries b ON b.document_id = d.id`
+	}
+	// documents.tenant_id always matches the tenant of the account; filtering
+	// on it lets the count skip the accounts join
+	baseQuery := columns + joins + `
+		WHERE d.tenant_id = $1
 	`
 
 	countQuery := `
 		SELECT COUNT(*)
 		FROM documents d
-		JOIN accounts a ON d.account_id = a.id
-		WHERE a.tenant_id = $1
+		WHERE d.tenant_id = $1
 	`
 
 	args := []interface{}{filter.TenantID}
@@ -260,6 +336,19 @@ func (r *Repository) List(ctx context.Context, filter *DocumentFilter) ([]*Docum
 		argNum++
 	}
 
+	if filter.Folder != "" {
+		// A folder includes its subfolders
+		conditions += fmt.Sprintf(" AND (d.folder = $%d OR d.folder LIKE $%d || '/%%')", argNum, argNum)
+		args = append(args, strings.Trim(filter.Folder, "/"))
+		argNum++
+	}
+
+	if filter.Label != "" {
+		conditions += fmt.Sprintf(" AND $%d = ANY(d.labels)", argNum)
+		args = append(args, filter.Label)
+		argNum++
+	}
+
 	if filter.DateFrom != nil {
 		conditions += fmt.Sprintf(" AND d.received_at >= $%d", argNum)
 		args = append(args, *filter.DateFrom)
@@ -292,6 +381,10 @@ func (r *Repository) List(ctx context.Context, filter *DocumentFilter) ([]*Docum
 		argNum++
 	}
 
+	// Documents restricted by access lists are neither listed nor counted
+	access, args := accessCondition(ctx, "d", args)
+	conditions += access
+
 	// Get total count
 	var totalCount int
 	err := r.db.QueryRow(ctx, countQuery+conditions, args...).Scan(&totalCount)
@@ -346,18 +439,25 @@ func (r *Repository) List(ctx context.Context, filter *DocumentFilter) ([]*Docum
 	for rows.Next() {
 		doc := &Document{}
 		var metadata []byte
+		var badges badgeRow
 
-		err := rows.Scan(
+		dest := []interface{}{
 			&doc.ID, &doc.AccountID, &doc.ExternalID, &doc.Type, &doc.Title, &doc.Sender,
 			&doc.ReceivedAt, &doc.ContentHash, &doc.StoragePath, &doc.FileSize, &doc.MimeType,
-			&doc.Status, &doc.ArchivedAt, &doc.RetentionUntil, &metadata, &doc.CreatedAt, &doc.UpdatedAt,
-			&doc.AccountName, &doc.AccountType,
-		)
-		if err != nil {
+			&doc.Status, &doc.ArchivedAt, &doc.RetentionUntil, &doc.Folder, &doc.Labels, &doc.AssignedTo,
+			&metadata, &doc.CreatedAt, &doc.UpdatedAt, &doc.AccountName, &doc.AccountType,
+		}
+		if filter.WithSummaries {
+			dest = append(dest, badges.dest()...)
+		}
+		if err := rows.Scan(dest...); err != nil {
 			return nil, 0, fmt.Errorf("scan document: %w", err)
 		}
 
 		doc.Metadata = parseMetadata(metadata)
+		if filter.WithSummaries {
+			doc.Analysis = badges.badges()
+		}
 		documents = append(documents, doc)
 	}
 
@@ -366,9 +466,10 @@ func (r *Repository) List(ctx context.Context, filter *DocumentFilter) ([]*Docum
 
 // UpdateStatus updates the status of a document with tenant isolation
 func (r *Repository) UpdateStatus(ctx context.Context, tenantID, id uuid.UUID, status string) error {
-	query := `UPDATE documents SET status = $1, updated_at = NOW() WHERE id = $2 AND tenant_id = $3`
+	query := `UPDATE documents d SET status = $1, updated_at = NOW() WHERE d.id = $2 AND d.tenant_id = $3`
+	access, args := accessCondition(ctx, "d", []interface{}{status, id, tenantID})
 
-	result, err := r.db.Exec(ctx, query, status, id, tenantID)
+	result, err := r.db.Exec(ctx, query+access, args...)
 	if err != nil {
 		return fmt.Errorf("update document status: %w", err)
 	}
@@ -382,9 +483,10 @@ func (r *Repository) UpdateStatus(ctx context.Context, tenantID, id uuid.UUID, s
 
 // Archive marks a document as archived with tenant isolation
 func (r *Repository) Archive(ctx context.Context, tenantID, id uuid.UUID) error {
-	query := `UPDATE documents SET status = 'archived', archived_at = NOW(), updated_at = NOW() WHERE id = $1 AND tenant_id = $2`
+	query := `UPDATE documents d SET status = 'archived', archived_at = NOW(), updated_at = NOW() WHERE d.id = $1 AND d.tenant_id = $2`
+	access, args := accessCondition(ctx, "d", []interface{}{id, tenantID})
 
-	result, err := r.db.Exec(ctx, query, id, tenantID)
+	result, err := r.db.Exec(ctx, query+access, args...)
 	if err != nil {
 		return fmt.Errorf("archive document: %w", err)
 	}
@@ -398,9 +500,10 @@ func (r *Repository) Archive(ctx context.Context, tenantID, id uuid.UUID) error
 
 // BulkArchive archives multiple documents with tenant isolation
 func (r *Repository) BulkArchive(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) (int, error) {
-	query := `UPDATE documents SET status = 'archived', archived_at = NOW(), updated_at = NOW() WHERE id = ANY($1) AND tenant_id = $2`
+	query := `UPDATE documents d SET status = 'archived', archived_at = NOW(), updated_at = NOW() WHERE d.id = ANY($1) AND d.tenant_id = $2`
+	access, args := accessCondition(ctx, "d", []interface{}{ids, tenantID})
 
-	result, err := r.db.Exec(ctx, query, ids, tenantID)
+	result, err := r.db.Exec(ctx, query+access, args...)
 	if err != nil {
 		return 0, fmt.Errorf("bulk archive documents: %w", err)
 	}
@@ -410,9 +513,10 @@ func (r *Repository) BulkArchive(ctx context.Context, tenantID uuid.UUID, ids []
 
 // Delete permanently deletes a document with tenant isolation
 func (r *Repository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
-	query := `DELETE FROM documents WHERE id = $1 AND tenant_id = $2`
+	query := `DELETE FROM documents d WHERE d.id = $1 AND d.tenant_id = $2`
+	access, args := accessCondition(ctx, "d", []interface{}{id, tenantID})
 
-	result, err := r.db.Exec(ctx, query, id, tenantID)
+	result, err := r.db.Exec(ctx, query+access, args...)
 	if err != nil {
 		return fmt.Errorf("delete document: %w", err)
 	}
@@ -437,29 +541,31 @@ func (r *Repository) GetExpired(ctx context.Context, tenantID uuid.UUID, limit,
 		offset = 0
 	}
 
+	access, args := accessCondition(ctx, "d", []interface{}{tenantID})
+
 	// Get total count first
 	countQuery := `
 		SELECT COUNT(*) FROM documents d
 		JOIN accounts a ON d.account_id = a.id
 		WHERE a.tenant_id = $1 AND d.retention_until < NOW()
-	`
+	` + access
 	var total int
-	if err := r.db.QueryRow(ctx, countQuery, tenantID).Scan(&total); err != nil {
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("count expired documents: %w", err)
 	}
 
-	query := `
+	query := fmt.Sprintf(`
 		SELECT d.id, d.account_id, d.external_id, d.type, d.title, d.sender,
 			d.received_at, d.content_hash, d.storage_path, d.file_size, d.mime_type,
 			d.status, d.archived_at, d.retention_until, d.metadata, d.created_at, d.updated_at
 		FROM documents d
 		JOIN accounts a ON d.account_id = a.id
-		WHERE a.tenant_id = $1 AND d.retention_until < NOW()
-		ORDER BY d.retention_until ASC
-		LIMIT $2 OFFSET $3
-	`
+		WHERE a.tenant_id = $1 AND d.retention_until < NOW()%s
+		ORDER BY d.retention_until ASC, d.id
+		LIMIT $%d OFFSET $%d
+	`, access, len(args)+1, len(args)+2)
 
-	rows, err := r.db.Query(ctx, query, tenantID, limit, offset)
+	rows, err := r.db.Query(ctx, query, append(args, limit, offset)...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("get expired documents: %w", err)
 	}
@@ -494,6 +600,8 @@ func (r *Repository) GetStats(ctx context.Context, tenantID uuid.UUID) (*Documen
 		ByAccount: make(map[uuid.UUID]int),
 	}
 
+	access, args := accessCondition(ctx, "d", []interface{}{tenantID})
+
 	// Total and status counts
 	query := `
 		SELECT
@@ -503,9 +611,9 @@ func (r *Repository) GetStats(ctx context.Context, tenantID uuid.UUID) (*Documen
 		FROM documents d
 		JOIN accounts a ON d.account_id = a.id
 		WHERE a.tenant_id = $1 AND d.archived_at IS NULL
-	`
+	` + access
 
-	err := r.db.QueryRow(ctx, query, tenantID).Scan(
+	err := r.db.QueryRow(ctx, query, args...).Scan(
 		&stats.TotalCount, &stats.NewCount, &stats.ReadCount,
 	)
 	if err != nil {
@@ -525,11 +633,11 @@ func (r *Repository) GetStats(ctx context.Context, tenantID uuid.UUID) (*Documen
 		SELECT d.type, COUNT(*)
 		FROM documents d
 		JOIN accounts a ON d.account_id = a.id
-		WHERE a.tenant_id = $1 AND d.archived_at IS NULL
+		WHERE a.tenant_id = $1 AND d.archived_at IS NULL` + access + `
 		GROUP BY d.type
 	`
 
-	rows, err := r.db.Query(ctx, typeQuery, tenantID)
+	rows, err := r.db.Query(ctx, typeQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("get type stats: %w", err)
 	}
@@ -549,11 +657,11 @@ func (r *Repository) GetStats(ctx context.Context, tenantID uuid.UUID) (*Documen
 		SELECT d.account_id, COUNT(*)
 		FROM documents d
 		JOIN accounts a ON d.account_id = a.id
-		WHERE a.tenant_id = $1 AND d.archived_at IS NULL
+		WHERE a.tenant_id = $1 AND d.archived_at IS NULL` + access + `
 		GROUP BY d.account_id
 	`
 
-	rows2, err := r.db.Query(ctx, accountQuery, tenantID)
+	rows2, err := r.db.Query(ctx, accountQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("get account stats: %w", err)
 	}
@@ -573,15 +681,16 @@ func (r *Repository) GetStats(ctx context.Context, tenantID uuid.UUID) (*Documen
 
 // GetUnreadCount returns count of unread documents per account
 func (r *Repository) GetUnreadCount(ctx context.Context, tenantID uuid.UUID) (map[uuid.UUID]int, error) {
+	access, args := accessCondition(ctx, "d", []interface{}{tenantID})
 	query := `
 		SELECT d.account_id, COUNT(*)
 		FROM documents d
 		JOIN accounts a ON d.account_id = a.id
-		WHERE a.tenant_id = $1 AND d.status = 'new' AND d.archived_at IS NULL
+		WHERE a.tenant_id = $1 AND d.status = 'new' AND d.archived_at IS NULL` + access + `
 		GROUP BY d.account_id
 	`
 
-	rows, err := r.db.Query(ctx, query, tenantID)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("get unread counts: %w", err)
 	}
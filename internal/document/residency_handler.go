@@ -0,0 +1,98 @@
+package document
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// ResidencyHandler handles the data residency region of a tenant's documents
+type ResidencyHandler struct {
+	repo          *Repository
+	defaultRegion string
+	regions       []string
+}
+
+// NewResidencyHandler creates a new residency handler for the regions of the
+// storage configuration
+func NewResidencyHandler(repo *Repository, cfg *StorageConfig) *ResidencyHandler {
+	return &ResidencyHandler{repo: repo, defaultRegion: cfg.Region, regions: cfg.RegionNames()}
+}
+
+// RegisterRoutes registers the residency routes. Changing the region is
+// admin-only.
+func (h *ResidencyHandler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/storage/residency", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("PUT /api/v1/storage/residency", requireAuth(requireAdmin(http.HandlerFunc(h.Set))))
+}
+
+// ResidencyResponse is the residency region of a tenant
+type ResidencyResponse struct {
+	Region        string   `json:"region"`
+	DefaultRegion string   `json:"default_region"`
+	Regions       []string `json:"regions"`
+}
+
+// SetResidencyRequest selects the region of new documents
+type SetResidencyRequest struct {
+	Region string `json:"region"`
+}
+
+// Get handles GET /api/v1/storage/residency
+func (h *ResidencyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	region, err := h.repo.StorageRegion(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, h.response(region))
+}
+
+// Set handles PUT /api/v1/storage/residency. Only documents stored afterwards
+// go to the new region; existing ones stay where they are.
+func (h *ResidencyHandler) Set(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	var req SetResidencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+	if !slices.Contains(h.regions, req.Region) {
+		api.BadRequest(w, "region must be one of the configured storage regions")
+		return
+	}
+
+	region := req.Region
+	if region == h.defaultRegion {
+		region = ""
+	}
+	if err := h.repo.SetStorageRegion(r.Context(), tenantID, region); err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, h.response(region))
+}
+
+func (h *ResidencyHandler) response(region string) *ResidencyResponse {
+	if region == "" {
+		region = h.defaultRegion
+	}
+	return &ResidencyResponse{Region: region, DefaultRegion: h.defaultRegion, Regions: h.regions}
+}
@@ -10,6 +10,10 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/analytics"
+	"austrian-business-infrastructure/internal/backfill"
+	"austrian-business-infrastructure/internal/timezone"
 )
 
 // Default limits for document uploads
@@ -27,12 +31,25 @@ type AccountVerifier interface {
 	VerifyAccountOwnership(ctx context.Context, accountID, tenantID uuid.UUID) error
 }
 
+// QuotaChecker enforces the tenant storage quota
+type QuotaChecker interface {
+	// CheckQuota returns an error if storing size more bytes would take the
+	// tenant over its quota
+	CheckQuota(ctx context.Context, tenantID uuid.UUID, size int64) error
+}
+
 // Service handles document business logic
 type Service struct {
-	repo            *Repository
-	storage         Storage
-	accountVerifier AccountVerifier
-	maxDocumentSize int64
+	repo              *Repository
+	storage           Storage
+	accountVerifier   AccountVerifier
+	quotaChecker      QuotaChecker
+	archivalScheduler ArchivalScheduler
+	analysisScheduler AnalysisScheduler
+	readModel         ReadModel
+	analytics         *analytics.Emitter
+	timezones         timezone.Source
+	maxDocumentSize   int64
 }
 
 // NewService creates a new document service
@@ -68,6 +85,16 @@ func (s *Service) SetAccountVerifier(verifier AccountVerifier) {
 	s.accountVerifier = verifier
 }
 
+// SetQuotaChecker sets the storage quota checker
+func (s *Service) SetQuotaChecker(checker QuotaChecker) {
+	s.quotaChecker = checker
+}
+
+// SetAnalytics reports documents added to the archive to the product analytics
+func (s *Service) SetAnalytics(emitter *analytics.Emitter) {
+	s.analytics = emitter
+}
+
 // CreateDocumentInput holds input for creating a document
 type CreateDocumentInput struct {
 	AccountID   uuid.UUID
@@ -124,6 +151,17 @@ func (s *Service) Create(ctx context.Context, tenantID string, input *CreateDocu
 		return existingByHash, nil
 	}
 
+	// Refuse new content once the tenant's storage quota is used up
+	if s.quotaChecker != nil {
+		tenantUUID, err := uuid.Parse(tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tenant ID: %w", err)
+		}
+		if err := s.quotaChecker.CheckQuota(ctx, tenantUUID, int64(len(content))); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate filename from external ID or UUID
 	filename := input.ExternalID
 	if filename == "" {
@@ -159,6 +197,14 @@ func (s *Service) Create(ctx context.Context, tenantID string, input *CreateDocu
 		return nil, fmt.Errorf("create document record: %w", err)
 	}
 
+	s.scheduleArchival(ctx, tenantID, doc)
+	if tenantUUID, err := uuid.Parse(tenantID); err == nil {
+		s.analytics.Track(ctx, tenantUUID, uuid.Nil, analytics.EventDocumentAdded, analytics.Properties{
+			"document_type": input.Type,
+			"content_type":  input.ContentType,
+		})
+	}
+
 	return doc, nil
 }
 
@@ -227,18 +273,45 @@ func (s *Service) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
 		return err
 	}
 
+	// Archival renditions are stored next to the original
+	renditionPaths, err := s.repo.ListRenditionPaths(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
 	// Delete from storage
 	if err := s.storage.Delete(ctx, doc.StoragePath); err != nil {
 		return fmt.Errorf("delete from storage: %w", err)
 	}
+	for _, p := range renditionPaths {
+		s.storage.Delete(ctx, p)
+	}
 
 	// Delete record
 	return s.repo.Delete(ctx, tenantID, id)
 }
 
-// List returns documents matching the filter
+// List returns documents matching the filter together with their analysis
+// badges
 func (s *Service) List(ctx context.Context, filter *DocumentFilter) ([]*Document, int, error) {
-	return s.repo.List(ctx, filter)
+	filter.WithSummaries = s.readModel != nil && s.readModel.Flags(backfill.DocumentListSummaries).ReadNew
+	docs, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !filter.WithSummaries {
+		if err := s.repo.LoadAnalysisBadges(ctx, filter.TenantID, docs); err != nil {
+			return nil, 0, err
+		}
+	}
+	markOverdue(docs, timezone.Today(time.Now(), timezone.Of(ctx, s.timezones, filter.TenantID)))
+	return docs, total, nil
+}
+
+// SetTimezones sets where tenants' time zones come from; a deadline is
+// overdue once the tenant's day is past it, in Europe/Vienna without it
+func (s *Service) SetTimezones(src timezone.Source) {
+	s.timezones = src
 }
 
 // GetStats returns document statistics
@@ -347,6 +420,12 @@ func getExtension(contentType string) string {
 		return ".jpg"
 	case "image/png":
 		return ".png"
+	case "image/heic":
+		return ".heic"
+	case "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+		return ".docx"
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return ".xlsx"
 	case "application/json":
 		return ".json"
 	default:
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"io"
 	"time"
+
+	"austrian-business-infrastructure/internal/config"
 )
 
 // Storage errors
@@ -39,7 +41,8 @@ type Storage interface {
 	// Exists checks if a document exists at the given path
 	Exists(ctx context.Context, path string) (bool, error)
 
-	// GetSignedURL returns a time-limited URL for direct download (for S3-compatible storage)
+	// GetSignedURL returns a time-limited URL for direct download (S3 presigned
+	// URL, Azure SAS token, GCS signed URL)
 	// Returns empty string and nil error if not supported (local storage)
 	GetSignedURL(ctx context.Context, path string, expiry time.Duration) (string, error)
 
@@ -56,6 +59,8 @@ type StorageType string
 const (
 	StorageTypeLocal StorageType = "local"
 	StorageTypeS3    StorageType = "s3"
+	StorageTypeAzure StorageType = "azure"
+	StorageTypeGCS   StorageType = "gcs"
 )
 
 // StorageConfig holds configuration for storage backends
@@ -72,15 +77,67 @@ type StorageConfig struct {
 	S3AccessKeyID     string
 	S3SecretAccessKey string
 	S3UseSSL          bool
+
+	// Azure Blob Storage config; the endpoint defaults to
+	// https://{account}.blob.core.windows.net
+	AzureAccountName string
+	AzureAccountKey  string
+	AzureContainer   string
+	AzureEndpoint    string
+
+	// Google Cloud Storage config; without a credentials file the
+	// application default credentials are used
+	GCSBucket          string
+	GCSCredentialsFile string
+	GCSEndpoint        string
+
+	// Region names the data residency region of this backend
+	Region string
+	// Regions are the backends of further residency regions by name
+	Regions map[string]*StorageConfig
+}
+
+// NewStorageConfig converts the environment settings into a storage config
+func NewStorageConfig(cfg *config.StorageConfigResult) *StorageConfig {
+	sc := &StorageConfig{
+		Type:               StorageType(cfg.Type),
+		LocalPath:          cfg.LocalPath,
+		S3Endpoint:         cfg.S3Endpoint,
+		S3Bucket:           cfg.S3Bucket,
+		S3Region:           cfg.S3Region,
+		S3AccessKeyID:      cfg.S3AccessKeyID,
+		S3SecretAccessKey:  cfg.S3SecretAccessKey,
+		S3UseSSL:           cfg.S3UseSSL,
+		AzureAccountName:   cfg.AzureAccountName,
+		AzureAccountKey:    cfg.AzureAccountKey,
+		AzureContainer:     cfg.AzureContainer,
+		AzureEndpoint:      cfg.AzureEndpoint,
+		GCSBucket:          cfg.GCSBucket,
+		GCSCredentialsFile: cfg.GCSCredentialsFile,
+		GCSEndpoint:        cfg.GCSEndpoint,
+		Region:             cfg.Region,
+	}
+	for name, region := range cfg.Regions {
+		if sc.Regions == nil {
+			sc.Regions = make(map[string]*StorageConfig)
+		}
+		sc.Regions[name] = NewStorageConfig(region)
+	}
+	return sc
 }
 
-// NewStorage creates a new storage instance based on configuration
+// NewStorage creates a new storage instance based on configuration. Regions
+// are ignored; see NewRegionalStorage.
 func NewStorage(cfg *StorageConfig) (Storage, error) {
 	switch cfg.Type {
 	case StorageTypeLocal:
 		return NewLocalStorage(cfg.LocalPath)
 	case StorageTypeS3:
 		return NewS3Storage(cfg)
+	case StorageTypeAzure:
+		return NewAzureStorage(cfg)
+	case StorageTypeGCS:
+		return NewGCSStorage(cfg)
 	default:
 		return NewLocalStorage(cfg.LocalPath)
 	}
@@ -0,0 +1,375 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// azureAPIVersion is the Blob service REST API version requests and SAS
+// tokens are signed for
+const azureAPIVersion = "2021-08-06"
+
+// AzureStorage implements Storage interface for Azure Blob Storage. It uses
+// the Blob service REST API with Shared Key authorization and hands out
+// service SAS tokens as signed URLs.
+type AzureStorage struct {
+	client    *http.Client
+	account   string
+	key       []byte
+	endpoint  *url.URL
+	container string
+}
+
+// NewAzureStorage creates a new Azure Blob Storage client
+func NewAzureStorage(cfg *StorageConfig) (*AzureStorage, error) {
+	if cfg.AzureAccountName == "" || cfg.AzureAccountKey == "" {
+		return nil, errors.New("azure storage requires an account name and key")
+	}
+	key, err := base64.StdEncoding.DecodeString(cfg.AzureAccountKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode azure account key: %w", err)
+	}
+
+	// Emulators such as Azurite use a path-style endpoint including the account
+	endpoint := cfg.AzureEndpoint
+	if endpoint == "" {
+		endpoint = "https://" + cfg.AzureAccountName + ".blob.core.windows.net"
+	}
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid azure endpoint %q", endpoint)
+	}
+
+	s := &AzureStorage{
+		client:    &http.Client{Timeout: 5 * time.Minute},
+		account:   cfg.AzureAccountName,
+		key:       key,
+		endpoint:  u,
+		container: cfg.AzureContainer,
+	}
+
+	// Create the container if it does not exist yet
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := s.do(ctx, http.MethodPut, "", url.Values{"restype": {"container"}}, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create container: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusConflict {
+		return nil, fmt.Errorf("create container: status %d", resp.StatusCode)
+	}
+
+	return s, nil
+}
+
+// Store saves a document as a block blob
+func (s *AzureStorage) Store(ctx context.Context, tenantID, accountID, filename string, content io.Reader, contentType string) (*StorageInfo, error) {
+	path := GeneratePath(tenantID, accountID, filename)
+
+	// Put Blob needs the content length up front
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStorageWriteFailed, err)
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, path, nil, data, map[string]string{
+		"x-ms-blob-type": "BlockBlob",
+		"Content-Type":   contentType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStorageWriteFailed, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("%w: status %d", ErrStorageWriteFailed, resp.StatusCode)
+	}
+
+	return &StorageInfo{
+		Path:        path,
+		Size:        int64(len(data)),
+		ContentType: contentType,
+		ETag:        strings.Trim(resp.Header.Get("ETag"), `"`),
+	}, nil
+}
+
+// Get retrieves a document from Azure
+func (s *AzureStorage) Get(ctx context.Context, path string) (io.ReadCloser, *StorageInfo, error) {
+	resp, err := s.do(ctx, http.MethodGet, path, nil, nil, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrStorageReadFailed, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, nil, ErrStorageNotFound
+	default:
+		resp.Body.Close()
+		return nil, nil, fmt.Errorf("%w: status %d", ErrStorageReadFailed, resp.StatusCode)
+	}
+
+	info := &StorageInfo{
+		Path:        path,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        strings.Trim(resp.Header.Get("ETag"), `"`),
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = t
+	}
+	return resp.Body, info, nil
+}
+
+// Delete removes a document from Azure
+func (s *AzureStorage) Delete(ctx context.Context, path string) error {
+	resp, err := s.do(ctx, http.MethodDelete, path, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrStorageDeleteFailed, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("%w: status %d", ErrStorageDeleteFailed, resp.StatusCode)
+	}
+	return nil
+}
+
+// Exists checks if a document exists in Azure
+func (s *AzureStorage) Exists(ctx context.Context, path string) (bool, error) {
+	resp, err := s.do(ctx, http.MethodHead, path, nil, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("check blob: status %d", resp.StatusCode)
+	}
+}
+
+// GetSignedURL returns the blob URL with a read-only service SAS token
+func (s *AzureStorage) GetSignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	// Default expiry to 15 minutes
+	if expiry == 0 {
+		expiry = 15 * time.Minute
+	}
+
+	// Maximum expiry is 7 days, as for S3
+	if expiry > 7*24*time.Hour {
+		expiry = 7 * 24 * time.Hour
+	}
+
+	expires := time.Now().UTC().Add(expiry).Format("2006-01-02T15:04:05Z")
+	protocol := ""
+	if s.endpoint.Scheme == "https" {
+		protocol = "https"
+	}
+
+	// Fields of a service SAS since version 2020-12-06: permissions, start,
+	// expiry, resource, identifier, IP, protocol, version, resource type,
+	// snapshot time, encryption scope and the five response headers
+	stringToSign := strings.Join([]string{
+		"r", "", expires,
+		"/blob/" + s.account + "/" + s.container + "/" + path,
+		"", "", protocol, azureAPIVersion, "b",
+		"", "", "", "", "", "", "",
+	}, "\n")
+
+	query := url.Values{
+		"sv":  {azureAPIVersion},
+		"se":  {expires},
+		"sr":  {"b"},
+		"sp":  {"r"},
+		"sig": {s.sign(stringToSign)},
+	}
+	if protocol != "" {
+		query.Set("spr", protocol)
+	}
+
+	u := s.blobURL(path)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// azureBlobList is the response of List Blobs
+type azureBlobList struct {
+	Blobs []struct {
+		Name       string `xml:"Name"`
+		Properties struct {
+			ContentLength int64  `xml:"Content-Length"`
+			ContentType   string `xml:"Content-Type"`
+			LastModified  string `xml:"Last-Modified"`
+			ETag          string `xml:"Etag"`
+		} `xml:"Properties"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// List returns all documents under a prefix
+func (s *AzureStorage) List(ctx context.Context, prefix string) ([]StorageInfo, error) {
+	var results []StorageInfo
+
+	marker := ""
+	for {
+		query := url.Values{"restype": {"container"}, "comp": {"list"}, "prefix": {prefix}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page azureBlobList
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("list blobs: status %d", resp.StatusCode)
+		} else {
+			err = xml.NewDecoder(resp.Body).Decode(&page)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, blob := range page.Blobs {
+			info := StorageInfo{
+				Path:        blob.Name,
+				Size:        blob.Properties.ContentLength,
+				ContentType: blob.Properties.ContentType,
+				ETag:        strings.Trim(blob.Properties.ETag, `"`),
+			}
+			if t, err := http.ParseTime(blob.Properties.LastModified); err == nil {
+				info.ModTime = t
+			}
+			results = append(results, info)
+		}
+
+		if page.NextMarker == "" {
+			return results, nil
+		}
+		marker = page.NextMarker
+	}
+}
+
+// GetUsage returns total storage usage for a tenant
+func (s *AzureStorage) GetUsage(ctx context.Context, tenantID string) (int64, error) {
+	blobs, err := s.List(ctx, tenantID+"/")
+	if err != nil {
+		return 0, err
+	}
+
+	var totalSize int64
+	for _, blob := range blobs {
+		totalSize += blob.Size
+	}
+	return totalSize, nil
+}
+
+// blobURL returns the URL of a blob, or of the container for an empty path
+func (s *AzureStorage) blobURL(path string) *url.URL {
+	u := *s.endpoint
+	u.Path = s.endpoint.Path + "/" + s.container
+	if path != "" {
+		u.Path += "/" + path
+	}
+	return &u
+}
+
+// do sends a request signed with the account key
+func (s *AzureStorage) do(ctx context.Context, method, path string, query url.Values, body []byte, headers map[string]string) (*http.Response, error) {
+	u := s.blobURL(path)
+	u.RawQuery = query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Authorization", "SharedKey "+s.account+":"+s.sign(s.stringToSign(req)))
+
+	return s.client.Do(req)
+}
+
+// stringToSign builds the Shared Key string to sign of a request
+func (s *AzureStorage) stringToSign(req *http.Request) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var b strings.Builder
+	for _, value := range []string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, replaced by x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	} {
+		b.WriteString(value)
+		b.WriteByte('\n')
+	}
+
+	var names []string
+	for name := range req.Header {
+		if name = strings.ToLower(name); strings.HasPrefix(name, "x-ms-") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+
+	b.WriteString("/" + s.account + req.URL.EscapedPath())
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		b.WriteString("\n" + strings.ToLower(key) + ":" + strings.Join(values, ","))
+	}
+	return b.String()
+}
+
+// sign returns the base64 HMAC-SHA256 of a string with the account key
+func (s *AzureStorage) sign(stringToSign string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
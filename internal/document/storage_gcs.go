@@ -0,0 +1,383 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/google"
+)
+
+// gcsScope is the OAuth scope of the storage requests
+const gcsScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+// GCSStorage implements Storage interface for Google Cloud Storage through
+// the JSON API. Signed URLs are V4 signed with the service account key, so
+// they are not available with application default credentials.
+type GCSStorage struct {
+	client   *http.Client
+	endpoint string
+	bucket   string
+
+	// The service account signing URLs; no key without a credentials file
+	signerEmail string
+	signerKey   *rsa.PrivateKey
+}
+
+// NewGCSStorage creates a new Google Cloud Storage client
+func NewGCSStorage(cfg *StorageConfig) (*GCSStorage, error) {
+	s := &GCSStorage{
+		endpoint: strings.TrimSuffix(cfg.GCSEndpoint, "/"),
+		bucket:   cfg.GCSBucket,
+	}
+	if s.endpoint == "" {
+		s.endpoint = "https://storage.googleapis.com"
+	}
+
+	switch {
+	case cfg.GCSCredentialsFile != "":
+		data, err := os.ReadFile(cfg.GCSCredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("read GCS credentials: %w", err)
+		}
+		jwtConfig, err := google.JWTConfigFromJSON(data, gcsScope)
+		if err != nil {
+			return nil, fmt.Errorf("parse GCS credentials: %w", err)
+		}
+		key, err := parseRSAPrivateKey(jwtConfig.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("parse GCS credentials: %w", err)
+		}
+		s.client = jwtConfig.Client(context.Background())
+		s.signerEmail = jwtConfig.Email
+		s.signerKey = key
+	case cfg.GCSEndpoint != "":
+		// Emulators such as fake-gcs-server take no credentials
+		s.client = &http.Client{Timeout: 5 * time.Minute}
+	default:
+		client, err := google.DefaultClient(context.Background(), gcsScope)
+		if err != nil {
+			return nil, fmt.Errorf("GCS default credentials: %w", err)
+		}
+		s.client = client
+	}
+
+	// The bucket is not created: its location decides where the documents reside
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := s.do(ctx, http.MethodGet, s.endpoint+"/storage/v1/b/"+url.PathEscape(s.bucket)+"?fields=name", nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("check bucket: %w", err)
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("check bucket: bucket %q does not exist", s.bucket)
+	default:
+		return nil, fmt.Errorf("check bucket: status %d", resp.StatusCode)
+	}
+
+	return s, nil
+}
+
+// gcsObject is the object resource of the JSON API
+type gcsObject struct {
+	Name        string    `json:"name"`
+	Size        string    `json:"size"`
+	ContentType string    `json:"contentType"`
+	Updated     time.Time `json:"updated"`
+	ETag        string    `json:"etag"`
+}
+
+func (o *gcsObject) info() StorageInfo {
+	size, _ := strconv.ParseInt(o.Size, 10, 64)
+	return StorageInfo{
+		Path:        o.Name,
+		Size:        size,
+		ContentType: o.ContentType,
+		ModTime:     o.Updated,
+		ETag:        o.ETag,
+	}
+}
+
+// Store saves a document to GCS
+func (s *GCSStorage) Store(ctx context.Context, tenantID, accountID, filename string, content io.Reader, contentType string) (*StorageInfo, error) {
+	path := GeneratePath(tenantID, accountID, filename)
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStorageWriteFailed, err)
+	}
+
+	uploadURL := s.endpoint + "/upload/storage/v1/b/" + url.PathEscape(s.bucket) +
+		"/o?uploadType=media&name=" + url.QueryEscape(path)
+	resp, err := s.do(ctx, http.MethodPost, uploadURL, data, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStorageWriteFailed, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrStorageWriteFailed, resp.StatusCode)
+	}
+
+	var obj gcsObject
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStorageWriteFailed, err)
+	}
+
+	return &StorageInfo{
+		Path:        path,
+		Size:        int64(len(data)),
+		ContentType: contentType,
+		ETag:        obj.ETag,
+	}, nil
+}
+
+// Get retrieves a document from GCS
+func (s *GCSStorage) Get(ctx context.Context, path string) (io.ReadCloser, *StorageInfo, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(path)+"?alt=media", nil, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrStorageReadFailed, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, nil, ErrStorageNotFound
+	default:
+		resp.Body.Close()
+		return nil, nil, fmt.Errorf("%w: status %d", ErrStorageReadFailed, resp.StatusCode)
+	}
+
+	info := &StorageInfo{
+		Path:        path,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        resp.Header.Get("ETag"),
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = t
+	}
+	return resp.Body, info, nil
+}
+
+// Delete removes a document from GCS
+func (s *GCSStorage) Delete(ctx context.Context, path string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.objectURL(path), nil, "")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrStorageDeleteFailed, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("%w: status %d", ErrStorageDeleteFailed, resp.StatusCode)
+	}
+	return nil
+}
+
+// Exists checks if a document exists in GCS
+func (s *GCSStorage) Exists(ctx context.Context, path string) (bool, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(path)+"?fields=name", nil, "")
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("check object: status %d", resp.StatusCode)
+	}
+}
+
+// GetSignedURL returns a V4 signed URL for direct download. It returns an
+// empty string without a service account key.
+func (s *GCSStorage) GetSignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	if s.signerKey == nil {
+		return "", nil
+	}
+
+	// Default expiry to 15 minutes
+	if expiry == 0 {
+		expiry = 15 * time.Minute
+	}
+
+	// Maximum expiry of V4 signatures is 7 days
+	if expiry > 7*24*time.Hour {
+		expiry = 7 * 24 * time.Hour
+	}
+
+	endpoint, err := url.Parse(s.endpoint)
+	if err != nil {
+		return "", fmt.Errorf("generate signed URL: %w", err)
+	}
+
+	now := time.Now().UTC()
+	timestamp := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/auto/storage/goog4_request"
+	resource := "/" + s.bucket + "/" + gcsEscape(path, true)
+
+	params := map[string]string{
+		"X-Goog-Algorithm":     "GOOG4-RSA-SHA256",
+		"X-Goog-Credential":    s.signerEmail + "/" + scope,
+		"X-Goog-Date":          timestamp,
+		"X-Goog-Expires":       strconv.Itoa(int(expiry.Seconds())),
+		"X-Goog-SignedHeaders": "host",
+	}
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, gcsEscape(key, false)+"="+gcsEscape(params[key], false))
+	}
+	query := strings.Join(pairs, "&")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet, resource, query,
+		"host:" + endpoint.Host + "\n",
+		"host", "UNSIGNED-PAYLOAD",
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "GOOG4-RSA-SHA256\n" + timestamp + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	digest := sha256.Sum256([]byte(stringToSign))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.signerKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("generate signed URL: %w", err)
+	}
+
+	return endpoint.Scheme + "://" + endpoint.Host + resource + "?" + query +
+		"&X-Goog-Signature=" + hex.EncodeToString(signature), nil
+}
+
+// List returns all documents under a prefix
+func (s *GCSStorage) List(ctx context.Context, prefix string) ([]StorageInfo, error) {
+	var results []StorageInfo
+
+	pageToken := ""
+	for {
+		query := url.Values{
+			"prefix": {prefix},
+			"fields": {"items(name,size,contentType,updated,etag),nextPageToken"},
+		}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		resp, err := s.do(ctx, http.MethodGet, s.endpoint+"/storage/v1/b/"+url.PathEscape(s.bucket)+"/o?"+query.Encode(), nil, "")
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Items         []gcsObject `json:"items"`
+			NextPageToken string      `json:"nextPageToken"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("list objects: status %d", resp.StatusCode)
+		} else {
+			err = json.NewDecoder(resp.Body).Decode(&page)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range page.Items {
+			results = append(results, page.Items[i].info())
+		}
+
+		if page.NextPageToken == "" {
+			return results, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// GetUsage returns total storage usage for a tenant
+func (s *GCSStorage) GetUsage(ctx context.Context, tenantID string) (int64, error) {
+	objects, err := s.List(ctx, tenantID+"/")
+	if err != nil {
+		return 0, err
+	}
+
+	var totalSize int64
+	for _, obj := range objects {
+		totalSize += obj.Size
+	}
+	return totalSize, nil
+}
+
+// objectURL returns the JSON API URL of an object
+func (s *GCSStorage) objectURL(path string) string {
+	return s.endpoint + "/storage/v1/b/" + url.PathEscape(s.bucket) + "/o/" + url.PathEscape(path)
+}
+
+func (s *GCSStorage) do(ctx context.Context, method, rawURL string, body []byte, contentType string) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return s.client.Do(req)
+}
+
+// gcsEscape percent-encodes all but the unreserved characters, as V4
+// signatures require, keeping slashes in object paths
+func gcsEscape(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', keepSlash && c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// parseRSAPrivateKey parses the PEM private key of a service account
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block != nil {
+		data = block.Bytes
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(data); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("private key is not an RSA key")
+		}
+		return rsaKey, nil
+	}
+	return x509.ParsePKCS1PrivateKey(data)
+}
@@ -270,6 +270,12 @@ func detectContentType(path string) string {
 		return "image/jpeg"
 	case ".png":
 		return "image/png"
+	case ".heic":
+		return "image/heic"
+	case ".docx":
+		return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	case ".xlsx":
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 	case ".gif":
 		return "image/gif"
 	case ".zip":
@@ -0,0 +1,234 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrUnknownRegion is returned for a residency region without a backend
+var ErrUnknownRegion = errors.New("storage region is not configured")
+
+// RegionResolver returns the data residency region of a tenant, or an empty
+// string for the default region
+type RegionResolver interface {
+	StorageRegion(ctx context.Context, tenantID uuid.UUID) (string, error)
+}
+
+// RegionalStorage routes documents to the backend of their tenant's data
+// residency region. Paths of documents outside the default region carry the
+// region ("ch:{tenant_id}/accounts/..."), so documents stored before a tenant
+// moved to another region stay readable where they are.
+type RegionalStorage struct {
+	defaultRegion string
+	backends      map[string]Storage
+	resolver      RegionResolver
+}
+
+// NewRegionalStorage creates the backends of the default and all further
+// regions of the configuration. Without a resolver it returns the default
+// backend alone.
+func NewRegionalStorage(cfg *StorageConfig, resolver RegionResolver) (Storage, error) {
+	defaultBackend, err := NewStorage(cfg)
+	if err != nil || resolver == nil {
+		return defaultBackend, err
+	}
+
+	s := &RegionalStorage{
+		defaultRegion: cfg.Region,
+		backends:      map[string]Storage{cfg.Region: defaultBackend},
+		resolver:      resolver,
+	}
+	for name, regionCfg := range cfg.Regions {
+		backend, err := NewStorage(regionCfg)
+		if err != nil {
+			return nil, fmt.Errorf("storage region %s: %w", name, err)
+		}
+		s.backends[name] = backend
+	}
+	return s, nil
+}
+
+// RegionNames returns the default and all further regions of a configuration
+func (c *StorageConfig) RegionNames() []string {
+	names := []string{c.Region}
+	for name := range c.Regions {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// backend returns the backend of a region, the default one for ""
+func (s *RegionalStorage) backend(region string) (Storage, error) {
+	if region == "" {
+		region = s.defaultRegion
+	}
+	backend, ok := s.backends[region]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRegion, region)
+	}
+	return backend, nil
+}
+
+// splitPath separates the region of a path from the backend path
+func (s *RegionalStorage) splitPath(path string) (string, string) {
+	if region, rest, ok := strings.Cut(path, ":"); ok && !strings.Contains(region, "/") {
+		return region, rest
+	}
+	return s.defaultRegion, path
+}
+
+// joinPath prefixes a backend path with its region unless it is the default
+func (s *RegionalStorage) joinPath(region, path string) string {
+	if region == "" || region == s.defaultRegion {
+		return path
+	}
+	return region + ":" + path
+}
+
+// Store saves a document in the region of the tenant
+func (s *RegionalStorage) Store(ctx context.Context, tenantID, accountID, filename string, content io.Reader, contentType string) (*StorageInfo, error) {
+	id, err := uuid.Parse(tenantID)
+	if err != nil {
+		return nil, ErrInvalidPath
+	}
+	region, err := s.resolver.StorageRegion(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: resolve storage region: %v", ErrStorageWriteFailed, err)
+	}
+	backend, err := s.backend(region)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := backend.Store(ctx, tenantID, accountID, filename, content, contentType)
+	if err != nil {
+		return nil, err
+	}
+	info.Path = s.joinPath(region, info.Path)
+	return info, nil
+}
+
+// Get retrieves a document from the region of its path
+func (s *RegionalStorage) Get(ctx context.Context, path string) (io.ReadCloser, *StorageInfo, error) {
+	region, backendPath := s.splitPath(path)
+	backend, err := s.backend(region)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, info, err := backend.Get(ctx, backendPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	info.Path = path
+	return content, info, nil
+}
+
+// Delete removes a document from the region of its path
+func (s *RegionalStorage) Delete(ctx context.Context, path string) error {
+	region, backendPath := s.splitPath(path)
+	backend, err := s.backend(region)
+	if err != nil {
+		return err
+	}
+	return backend.Delete(ctx, backendPath)
+}
+
+// Exists checks if a document exists in the region of its path
+func (s *RegionalStorage) Exists(ctx context.Context, path string) (bool, error) {
+	region, backendPath := s.splitPath(path)
+	backend, err := s.backend(region)
+	if err != nil {
+		return false, err
+	}
+	return backend.Exists(ctx, backendPath)
+}
+
+// GetSignedURL returns a signed URL of the backend of the document's region
+func (s *RegionalStorage) GetSignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	region, backendPath := s.splitPath(path)
+	backend, err := s.backend(region)
+	if err != nil {
+		return "", err
+	}
+	return backend.GetSignedURL(ctx, backendPath, expiry)
+}
+
+// List returns all documents under a prefix. A prefix without a region is
+// listed in every region.
+func (s *RegionalStorage) List(ctx context.Context, prefix string) ([]StorageInfo, error) {
+	if region, backendPrefix, ok := strings.Cut(prefix, ":"); ok && !strings.Contains(region, "/") {
+		return s.listRegion(ctx, region, backendPrefix)
+	}
+
+	var results []StorageInfo
+	for region := range s.backends {
+		infos, err := s.listRegion(ctx, region, prefix)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, infos...)
+	}
+	return results, nil
+}
+
+func (s *RegionalStorage) listRegion(ctx context.Context, region, prefix string) ([]StorageInfo, error) {
+	backend, err := s.backend(region)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := backend.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("storage region %s: %w", region, err)
+	}
+	for i := range infos {
+		infos[i].Path = s.joinPath(region, infos[i].Path)
+	}
+	return infos, nil
+}
+
+// GetUsage returns the storage usage of a tenant across all regions
+func (s *RegionalStorage) GetUsage(ctx context.Context, tenantID string) (int64, error) {
+	var total int64
+	for region, backend := range s.backends {
+		usage, err := backend.GetUsage(ctx, tenantID)
+		if err != nil {
+			return 0, fmt.Errorf("storage region %s: %w", region, err)
+		}
+		total += usage
+	}
+	return total, nil
+}
+
+// StorageRegion returns the data residency region of a tenant, or an empty
+// string for the default region
+func (r *Repository) StorageRegion(ctx context.Context, tenantID uuid.UUID) (string, error) {
+	var region string
+	err := r.db.QueryRow(ctx, `SELECT COALESCE(storage_region, '') FROM tenants WHERE id = $1`, tenantID).Scan(&region)
+	if err != nil {
+		return "", fmt.Errorf("get storage region: %w", err)
+	}
+	return region, nil
+}
+
+// SetStorageRegion sets the data residency region of a tenant; an empty
+// region selects the default one
+func (r *Repository) SetStorageRegion(ctx context.Context, tenantID uuid.UUID, region string) error {
+	var value *string
+	if region != "" {
+		value = &region
+	}
+	_, err := r.db.Exec(ctx, `UPDATE tenants SET storage_region = $2, updated_at = NOW() WHERE id = $1`, tenantID, value)
+	if err != nil {
+		return fmt.Errorf("set storage region: %w", err)
+	}
+	return nil
+}
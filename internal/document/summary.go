@@ -0,0 +1,141 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/backfill"
+)
+
+// AnalysisBadges are the analysis results shown on a row of the documents
+// list. Deadlines and action items are counted while they are open.
+type AnalysisBadges struct {
+	AnalysisStatus  string     `json:"analysis_status,omitempty"`
+	DeadlineCount   int        `json:"deadline_count"`
+	NextDeadline    *time.Time `json:"next_deadline,omitempty"`
+	DeadlineOverdue bool       `json:"deadline_overdue"`
+	OpenActionCount int        `json:"open_action_count"`
+	TopAction       *TopAction `json:"top_action,omitempty"`
+}
+
+// TopAction is the open action item of a document with the highest
+// priority, the earliest due date breaking ties
+type TopAction struct {
+	ID       uuid.UUID  `json:"id"`
+	Title    string     `json:"title"`
+	Priority string     `json:"priority"`
+	DueDate  *time.Time `json:"due_date,omitempty"`
+}
+
+// ReadModel reports the rollout of the documents list summaries;
+// backfill.Service implements it
+type ReadModel interface {
+	Flags(name string) backfill.Flags
+}
+
+// SetReadModel makes the documents list read its analysis badges from
+// document_list_summaries once the backfill of the same name is cut over.
+// Until then, or if unset, they are computed from the analysis tables.
+func (s *Service) SetReadModel(readModel ReadModel) {
+	s.readModel = readModel
+}
+
+// badgeColumns selects the badges of the view document_list_badges or the
+// table document_list_summaries, either aliased b. Overdue is derived after
+// reading (markOverdue) since it changes without any write.
+const badgeColumns = `b.analysis_status, COALESCE(b.deadline_count, 0), b.next_deadline,
+			COALESCE(b.open_action_count, 0),
+			b.top_action_id, b.top_action_title, b.top_action_priority, b.top_action_due_date`
+
+// badgeRow holds the scanned badgeColumns
+type badgeRow struct {
+	analysisStatus    *string
+	deadlineCount     int
+	nextDeadline      *time.Time
+	openActionCount   int
+	topActionID       *uuid.UUID
+	topActionTitle    *string
+	topActionPriority *string
+	topActionDueDate  *time.Time
+}
+
+func (b *badgeRow) dest() []interface{} {
+	return []interface{}{
+		&b.analysisStatus, &b.deadlineCount, &b.nextDeadline, &b.openActionCount,
+		&b.topActionID, &b.topActionTitle, &b.topActionPriority, &b.topActionDueDate,
+	}
+}
+
+// badges returns the scanned badges, nil if there are none to show
+func (b *badgeRow) badges() *AnalysisBadges {
+	if b.analysisStatus == nil && b.deadlineCount == 0 && b.openActionCount == 0 {
+		return nil
+	}
+	badges := &AnalysisBadges{
+		DeadlineCount:   b.deadlineCount,
+		NextDeadline:    b.nextDeadline,
+		OpenActionCount: b.openActionCount,
+	}
+	if b.analysisStatus != nil {
+		badges.AnalysisStatus = *b.analysisStatus
+	}
+	if b.topActionID != nil {
+		badges.TopAction = &TopAction{ID: *b.topActionID, DueDate: b.topActionDueDate}
+		if b.topActionTitle != nil {
+			badges.TopAction.Title = *b.topActionTitle
+		}
+		if b.topActionPriority != nil {
+			badges.TopAction.Priority = *b.topActionPriority
+		}
+	}
+	return badges
+}
+
+// LoadAnalysisBadges sets the analysis badges of a page of documents,
+// computing them from the analysis tables with one query
+func (r *Repository) LoadAnalysisBadges(ctx context.Context, tenantID uuid.UUID, docs []*Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	byID := make(map[uuid.UUID]*Document, len(docs))
+	ids := make([]uuid.UUID, len(docs))
+	for i, doc := range docs {
+		byID[doc.ID] = doc
+		ids[i] = doc.ID
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT b.document_id, `+badgeColumns+`
+		FROM document_list_badges b
+		WHERE b.tenant_id = $1 AND b.document_id = ANY($2)
+	`, tenantID, ids)
+	if err != nil {
+		return fmt.Errorf("load analysis badges: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var badges badgeRow
+		if err := rows.Scan(append([]interface{}{&id}, badges.dest()...)...); err != nil {
+			return fmt.Errorf("scan analysis badges: %w", err)
+		}
+		if doc := byID[id]; doc != nil {
+			doc.Analysis = badges.badges()
+		}
+	}
+	return rows.Err()
+}
+
+// markOverdue flags the badges whose next deadline is before today, the
+// tenant's calendar day as returned by timezone.Today
+func markOverdue(docs []*Document, today time.Time) {
+	for _, doc := range docs {
+		if a := doc.Analysis; a != nil && a.NextDeadline != nil {
+			a.DeadlineOverdue = a.NextDeadline.Before(today)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Package dryrun describes the outcome of validate-only calls. Each
+// submission type (UVA, ZM, ELDA Meldungen, e-invoices) runs its full
+// schema and business validation on a request body without storing
+// anything and reports every problem it finds, not just the first.
+package dryrun
+
+import (
+	"errors"
+	"time"
+
+	"austrian-business-infrastructure/internal/xmlschema"
+)
+
+// Submission kinds
+const (
+	KindUVA         = "uva"
+	KindZM          = "zm"
+	KindELDAMeldung = "elda_meldung"
+	KindInvoice     = "invoice"
+)
+
+// Finding severities. Errors block a submission; warnings do not.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// CodeSchema marks violations of the official XML schema
+const CodeSchema = "schema"
+
+// Finding is one problem found in a submission
+type Finding struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+// SandboxResult is the answer of a provider's test system
+type SandboxResult struct {
+	Accepted  bool     `json:"accepted"`
+	Reference string   `json:"reference,omitempty"`
+	ErrorCode string   `json:"error_code,omitempty"`
+	Message   string   `json:"message,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// Report is the outcome of a dry run
+type Report struct {
+	Kind          string         `json:"kind"`
+	Valid         bool           `json:"valid"`
+	Findings      []Finding      `json:"findings"`
+	SchemaVersion string         `json:"schema_version,omitempty"`
+	Sandbox       *SandboxResult `json:"sandbox,omitempty"`
+	CheckedAt     time.Time      `json:"checked_at"`
+}
+
+// NewReport starts an empty report
+func NewReport(kind string) *Report {
+	return &Report{Kind: kind, Findings: []Finding{}}
+}
+
+// Error adds a blocking finding
+func (r *Report) Error(code, field, message string) {
+	r.Findings = append(r.Findings, Finding{Severity: SeverityError, Code: code, Field: field, Message: message})
+}
+
+// Warn adds a non-blocking finding
+func (r *Report) Warn(code, field, message string) {
+	r.Findings = append(r.Findings, Finding{Severity: SeverityWarning, Code: code, Field: field, Message: message})
+}
+
+// HasErrors reports whether any blocking finding was added
+func (r *Report) HasErrors() bool {
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Schema validates a generated document against the official schema for
+// the reporting date. Violations become one finding each with the element
+// path as field. A period no embedded schema covers only warns, as on real
+// submission; other errors are returned.
+func (r *Report) Schema(format xmlschema.Format, date time.Time, doc []byte) error {
+	entry, err := xmlschema.Default().Validate(format, date, doc)
+	if entry != nil {
+		r.SchemaVersion = entry.Version
+	}
+	if errors.Is(err, xmlschema.ErrNoSchema) {
+		r.Warn(CodeSchema, "", "no schema covers "+date.Format("2006-01-02")+", schema validation skipped")
+		return nil
+	}
+	var schemaErr *xmlschema.ValidationError
+	if errors.As(err, &schemaErr) {
+		for _, v := range schemaErr.Violations {
+			r.Error(CodeSchema, v.Path, v.Message)
+		}
+		return nil
+	}
+	return err
+}
+
+// Finish sets the verdict and the time of the check
+func (r *Report) Finish(now time.Time) *Report {
+	r.Valid = !r.HasErrors()
+	r.CheckedAt = now
+	return r
+}
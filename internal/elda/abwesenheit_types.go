@@ -0,0 +1,201 @@
+package elda
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AbwesenheitArt represents the type of an absence
+type AbwesenheitArt string
+
+const (
+	AbwesenheitKrankenstand  AbwesenheitArt = "krankenstand"
+	AbwesenheitArbeitsunfall AbwesenheitArt = "arbeitsunfall"
+	AbwesenheitUrlaub        AbwesenheitArt = "urlaub"
+	AbwesenheitPflegeurlaub  AbwesenheitArt = "pflegeurlaub"
+)
+
+// IsArbeitsunfaehigkeit reports whether the absence is an Arbeitsunfähigkeit
+// for which the ÖGK may request an AUA
+func (a AbwesenheitArt) IsArbeitsunfaehigkeit() bool {
+	return a == AbwesenheitKrankenstand || a == AbwesenheitArbeitsunfall
+}
+
+// Abwesenheit is an absence record of an employee
+type Abwesenheit struct {
+	ID            uuid.UUID      `json:"id" db:"id"`
+	ELDAAccountID uuid.UUID      `json:"elda_account_id" db:"elda_account_id"`
+	SVNummer      string         `json:"sv_nummer" db:"sv_nummer"`
+	Vorname       string         `json:"vorname" db:"vorname"`
+	Nachname      string         `json:"nachname" db:"nachname"`
+	Art           AbwesenheitArt `json:"art" db:"art"`
+	Von           time.Time      `json:"von" db:"von"`
+	Bis           *time.Time     `json:"bis,omitempty" db:"bis"`         // Open while a Krankenstand is ongoing
+	Bestaetigung  bool           `json:"bestaetigung" db:"bestaetigung"` // Arbeitsunfähigkeitsbestätigung vorgelegt
+	Bemerkung     string         `json:"bemerkung,omitempty" db:"bemerkung"`
+
+	// Audit
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Overlaps reports whether two absences share at least one day
+func (a *Abwesenheit) Overlaps(other *Abwesenheit) bool {
+	if a.Bis != nil && a.Bis.Before(other.Von) {
+		return false
+	}
+	if other.Bis != nil && other.Bis.Before(a.Von) {
+		return false
+	}
+	return true
+}
+
+// Beschaeftigungszeitraum is an employment period derived from accepted An- and Abmeldungen
+type Beschaeftigungszeitraum struct {
+	Von time.Time  `json:"von"`
+	Bis *time.Time `json:"bis,omitempty"`
+}
+
+// Contains reports whether the period covers [von, bis]; an open bis means ongoing
+func (b Beschaeftigungszeitraum) Contains(von time.Time, bis *time.Time) bool {
+	if von.Before(b.Von) {
+		return false
+	}
+	if b.Bis == nil {
+		return true
+	}
+	if bis == nil {
+		return false
+	}
+	return !bis.After(*b.Bis)
+}
+
+// AbwesenheitCreateRequest is the request to record an absence
+type AbwesenheitCreateRequest struct {
+	ELDAAccountID uuid.UUID      `json:"elda_account_id"`
+	SVNummer      string         `json:"sv_nummer"`
+	Art           AbwesenheitArt `json:"art"`
+	Von           string         `json:"von"`           // YYYY-MM-DD
+	Bis           string         `json:"bis,omitempty"` // YYYY-MM-DD
+	Bestaetigung  bool           `json:"bestaetigung"`
+	Bemerkung     string         `json:"bemerkung,omitempty"`
+}
+
+// AUA Status constants
+type AUAStatus string
+
+const (
+	AUAStatusDraft     AUAStatus = "draft"
+	AUAStatusSubmitted AUAStatus = "submitted"
+	AUAStatusAccepted  AUAStatus = "accepted"
+	AUAStatusRejected  AUAStatus = "rejected"
+)
+
+// AUAMeldung is an Arbeits- und Entgeltbestätigung for Krankengeld requested by the ÖGK
+type AUAMeldung struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	AbwesenheitID uuid.UUID `json:"abwesenheit_id" db:"abwesenheit_id"`
+	ELDAAccountID uuid.UUID `json:"elda_account_id" db:"elda_account_id"`
+	SVNummer      string    `json:"sv_nummer" db:"sv_nummer"`
+	Status        AUAStatus `json:"status" db:"status"`
+
+	// ÖGK request
+	Anforderungsdatum    time.Time `json:"anforderungsdatum" db:"anforderungsdatum"`
+	Anforderungsreferenz string    `json:"anforderungsreferenz,omitempty" db:"anforderungsreferenz"`
+
+	// Arbeitsbestätigung
+	Arbeitsunfall     bool       `json:"arbeitsunfall" db:"arbeitsunfall"`
+	LetzterArbeitstag time.Time  `json:"letzter_arbeitstag" db:"letzter_arbeitstag"`
+	ArbeitsunfaehigAb time.Time  `json:"arbeitsunfaehig_ab" db:"arbeitsunfaehig_ab"`
+	EntgeltVollBis    time.Time  `json:"entgelt_voll_bis" db:"entgelt_voll_bis"`
+	EntgeltHalbBis    *time.Time `json:"entgelt_halb_bis,omitempty" db:"entgelt_halb_bis"`
+
+	// Entgeltbestätigung (cents)
+	Beitragsgrundlage int64 `json:"beitragsgrundlage" db:"beitragsgrundlage"` // Last full Beitragszeitraum
+	Sonderzahlungen   int64 `json:"sonderzahlungen" db:"sonderzahlungen"`     // Last 12 months
+
+	// ELDA response
+	Protokollnummer string     `json:"protokollnummer,omitempty" db:"protokollnummer"`
+	SubmittedAt     *time.Time `json:"submitted_at,omitempty" db:"submitted_at"`
+	RequestXML      string     `json:"-" db:"request_xml"`
+	ErrorCode       string     `json:"error_code,omitempty" db:"error_code"`
+	ErrorMessage    string     `json:"error_message,omitempty" db:"error_message"`
+
+	// Audit
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// AUACreateRequest is the request to create an AUA for an absence
+type AUACreateRequest struct {
+	Anforderungsdatum    string `json:"anforderungsdatum,omitempty"` // YYYY-MM-DD, defaults to today
+	Anforderungsreferenz string `json:"anforderungsreferenz,omitempty"`
+	Beitragsgrundlage    *int64 `json:"beitragsgrundlage,omitempty"` // cents, defaults to the last mBGM
+	Sonderzahlungen      int64  `json:"sonderzahlungen,omitempty"`   // cents
+}
+
+// Entgeltfortzahlung is the continued-pay entitlement of an Arbeitsunfähigkeit
+type Entgeltfortzahlung struct {
+	VollWochen int `json:"voll_wochen"`
+	HalbWochen int `json:"halb_wochen"`
+}
+
+// CalculateEntgeltfortzahlung returns the continued-pay entitlement per
+// Arbeitsunfähigkeit based on the length of service (§ 2 EFZG, § 8 AngG).
+func CalculateEntgeltfortzahlung(eintritt, arbeitsunfaehigAb time.Time, arbeitsunfall bool) Entgeltfortzahlung {
+	years := arbeitsunfaehigAb.Year() - eintritt.Year()
+	if eintritt.AddDate(years, 0, 0).After(arbeitsunfaehigAb) {
+		years--
+	}
+
+	if arbeitsunfall {
+		// Arbeitsunfall and Berufskrankheit: 8 weeks, 10 weeks after 15 years
+		if years >= 15 {
+			return Entgeltfortzahlung{VollWochen: 10}
+		}
+		return Entgeltfortzahlung{VollWochen: 8}
+	}
+
+	switch {
+	case years >= 25:
+		return Entgeltfortzahlung{VollWochen: 12, HalbWochen: 4}
+	case years >= 15:
+		return Entgeltfortzahlung{VollWochen: 10, HalbWochen: 4}
+	case years >= 1:
+		return Entgeltfortzahlung{VollWochen: 8, HalbWochen: 4}
+	default:
+		return Entgeltfortzahlung{VollWochen: 6, HalbWochen: 4}
+	}
+}
+
+// AUADocument is the XML document of an Arbeits- und Entgeltbestätigung
+type AUADocument struct {
+	XMLName              xml.Name `xml:"ArbeitsEntgeltbestaetigung"`
+	XMLNS                string   `xml:"xmlns,attr"`
+	Kopf                 ELDAKopf `xml:"Kopf"`
+	Anforderungsreferenz string   `xml:"Anforderungsreferenz,omitempty"`
+	SVNummer             string   `xml:"SVNummer"`
+	Familienname         string   `xml:"Familienname"`
+	Vorname              string   `xml:"Vorname"`
+	Arbeitsunfall        bool     `xml:"Arbeitsunfall"`
+	LetzterArbeitstag    string   `xml:"LetzterArbeitstag"`
+	ArbeitsunfaehigAb    string   `xml:"ArbeitsunfaehigAb"`
+	EntgeltVollBis       string   `xml:"Entgeltfortzahlung>VollBis"`
+	EntgeltHalbBis       string   `xml:"Entgeltfortzahlung>HalbBis,omitempty"`
+	Beitragsgrundlage    string   `xml:"Entgelt>Beitragsgrundlage"`
+	Sonderzahlungen      string   `xml:"Entgelt>Sonderzahlungen,omitempty"`
+}
+
+// AUAResponse is the ELDA response to an AUA submission
+type AUAResponse struct {
+	XMLName         xml.Name `xml:"AUAResponse"`
+	Erfolg          bool     `xml:"Erfolg"`
+	Protokollnummer string   `xml:"Protokollnummer,omitempty"`
+	ErrorCode       string   `xml:"FehlerCode,omitempty"`
+	ErrorMessage    string   `xml:"FehlerMeldung,omitempty"`
+	Warnungen       []string `xml:"Warnungen>Warnung,omitempty"`
+}
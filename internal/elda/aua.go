@@ -0,0 +1,82 @@
+package elda
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// AUAService handles Arbeits- und Entgeltbestätigung protocol operations
+type AUAService struct {
+	client Caller
+}
+
+// NewAUAService creates a new AUA service
+func NewAUAService(client Caller) *AUAService {
+	return &AUAService{client: client}
+}
+
+// MarshalAUADocument renders an Arbeits- und Entgeltbestätigung as XML including the header
+func MarshalAUADocument(doc *AUADocument) ([]byte, error) {
+	xmlData, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal AUA document: %w", err)
+	}
+
+	fullXML := []byte(xml.Header)
+	return append(fullXML, xmlData...), nil
+}
+
+// SubmitAUA submits an Arbeits- und Entgeltbestätigung through the ELDA channel
+func (s *AUAService) SubmitAUA(ctx context.Context, doc *AUADocument) (*AUASubmitResult, error) {
+	fullXML, err := MarshalAUADocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	type submitRequest struct {
+		XMLName  xml.Name `xml:"SubmitAUA"`
+		XMLNS    string   `xml:"xmlns,attr"`
+		Document string   `xml:"Document"`
+	}
+
+	req := submitRequest{
+		XMLNS:    ELDANS,
+		Document: string(fullXML),
+	}
+
+	result := &AUASubmitResult{
+		RequestXML:  string(fullXML),
+		SubmittedAt: time.Now(),
+	}
+
+	var resp AUAResponse
+	if err := s.client.CallWithRetry(ctx, "SubmitAUA", &req, &resp); err != nil {
+		result.ErrorMessage = err.Error()
+		return result, fmt.Errorf("AUA submission failed: %w", err)
+	}
+
+	result.Success = resp.Erfolg
+	result.Protokollnummer = resp.Protokollnummer
+	result.Warnings = resp.Warnungen
+
+	if !resp.Erfolg {
+		result.ErrorCode = resp.ErrorCode
+		result.ErrorMessage = resp.ErrorMessage
+		return result, fmt.Errorf("ELDA rejected AUA: %s - %s", resp.ErrorCode, resp.ErrorMessage)
+	}
+
+	return result, nil
+}
+
+// AUASubmitResult contains the result of an AUA submission
+type AUASubmitResult struct {
+	Success         bool      `json:"success"`
+	Protokollnummer string    `json:"protokollnummer,omitempty"`
+	ErrorCode       string    `json:"error_code,omitempty"`
+	ErrorMessage    string    `json:"error_message,omitempty"`
+	Warnings        []string  `json:"warnings,omitempty"`
+	RequestXML      string    `json:"-"`
+	SubmittedAt     time.Time `json:"submitted_at"`
+}
@@ -0,0 +1,142 @@
+package elda
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// BUAKService handles BUAK Zuschlagsmeldung protocol operations
+type BUAKService struct {
+	client Caller
+}
+
+// NewBUAKService creates a new BUAK service
+func NewBUAKService(client Caller) *BUAKService {
+	return &BUAKService{client: client}
+}
+
+// MarshalBUAKDocument renders a Zuschlagsmeldung as XML including the header
+func MarshalBUAKDocument(doc *BUAKDocument) ([]byte, error) {
+	xmlData, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal BUAK document: %w", err)
+	}
+
+	fullXML := []byte(xml.Header)
+	return append(fullXML, xmlData...), nil
+}
+
+// SubmitBUAK submits a Zuschlagsmeldung through the ELDA channel
+func (s *BUAKService) SubmitBUAK(ctx context.Context, doc *BUAKDocument) (*BUAKSubmitResult, error) {
+	fullXML, err := MarshalBUAKDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	type submitRequest struct {
+		XMLName  xml.Name `xml:"SubmitBUAKMeldung"`
+		XMLNS    string   `xml:"xmlns,attr"`
+		Document string   `xml:"Document"`
+	}
+
+	req := submitRequest{
+		XMLNS:    ELDANS,
+		Document: string(fullXML),
+	}
+
+	result := &BUAKSubmitResult{
+		RequestXML:  string(fullXML),
+		SubmittedAt: time.Now(),
+	}
+
+	var resp BUAKResponse
+	if err := s.client.CallWithRetry(ctx, "SubmitBUAKMeldung", &req, &resp); err != nil {
+		result.ErrorMessage = err.Error()
+		return result, fmt.Errorf("BUAK submission failed: %w", err)
+	}
+
+	result.Success = resp.Erfolg
+	result.Protokollnummer = resp.Protokollnummer
+	result.Warnings = resp.Warnungen
+
+	if !resp.Erfolg {
+		result.ErrorCode = resp.ErrorCode
+		result.ErrorMessage = resp.ErrorMessage
+		return result, fmt.Errorf("BUAK rejected Zuschlagsmeldung: %s - %s", resp.ErrorCode, resp.ErrorMessage)
+	}
+
+	return result, nil
+}
+
+// BUAKSubmitResult contains the result of a Zuschlagsmeldung submission
+type BUAKSubmitResult struct {
+	Success         bool      `json:"success"`
+	Protokollnummer string    `json:"protokollnummer,omitempty"`
+	ErrorCode       string    `json:"error_code,omitempty"`
+	ErrorMessage    string    `json:"error_message,omitempty"`
+	Warnings        []string  `json:"warnings,omitempty"`
+	RequestXML      string    `json:"-"`
+	SubmittedAt     time.Time `json:"submitted_at"`
+}
+
+// QueryBUAKStatus queries whether the BUAK has confirmed a Zuschlagsmeldung
+func (s *BUAKService) QueryBUAKStatus(ctx context.Context, dienstgeberNr, protokollnummer string) (*BUAKStatusResult, error) {
+	type statusRequest struct {
+		XMLName         xml.Name `xml:"BUAKStatusAbfrage"`
+		XMLNS           string   `xml:"xmlns,attr"`
+		DienstgeberNr   string   `xml:"DienstgeberNummer"`
+		Protokollnummer string   `xml:"Protokollnummer"`
+	}
+
+	type statusResponse struct {
+		XMLName         xml.Name `xml:"BUAKStatusResponse"`
+		Status          string   `xml:"Status"`
+		Protokollnummer string   `xml:"Protokollnummer"`
+		Bestaetigt      bool     `xml:"Bestaetigt"`
+		Bestaetigungsnr string   `xml:"Bestaetigungsnummer,omitempty"`
+		FehlerCode      string   `xml:"FehlerCode,omitempty"`
+		FehlerMeldung   string   `xml:"FehlerMeldung,omitempty"`
+		BestaetigtAm    string   `xml:"BestaetigtAm,omitempty"`
+	}
+
+	req := statusRequest{
+		XMLNS:           ELDANS,
+		DienstgeberNr:   dienstgeberNr,
+		Protokollnummer: protokollnummer,
+	}
+
+	var resp statusResponse
+	if err := s.client.CallWithRetry(ctx, "BUAKStatusAbfrage", &req, &resp); err != nil {
+		return nil, fmt.Errorf("status query failed: %w", err)
+	}
+
+	result := &BUAKStatusResult{
+		Protokollnummer:    resp.Protokollnummer,
+		Status:             resp.Status,
+		Confirmed:          resp.Bestaetigt,
+		ConfirmationNumber: resp.Bestaetigungsnr,
+		ErrorCode:          resp.FehlerCode,
+		ErrorMessage:       resp.FehlerMeldung,
+	}
+
+	if resp.BestaetigtAm != "" {
+		if t, err := time.Parse("2006-01-02T15:04:05", resp.BestaetigtAm); err == nil {
+			result.ConfirmedAt = &t
+		}
+	}
+
+	return result, nil
+}
+
+// BUAKStatusResult contains the processing status of a Zuschlagsmeldung
+type BUAKStatusResult struct {
+	Protokollnummer    string     `json:"protokollnummer"`
+	Status             string     `json:"status"`
+	Confirmed          bool       `json:"confirmed"`
+	ConfirmationNumber string     `json:"confirmation_number,omitempty"`
+	ConfirmedAt        *time.Time `json:"confirmed_at,omitempty"`
+	ErrorCode          string     `json:"error_code,omitempty"`
+	ErrorMessage       string     `json:"error_message,omitempty"`
+}
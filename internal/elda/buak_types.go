@@ -0,0 +1,270 @@
+package elda
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/refdata"
+)
+
+// BUAK Status constants
+type BUAKStatus string
+
+const (
+	BUAKStatusDraft     BUAKStatus = "draft"
+	BUAKStatusValidated BUAKStatus = "validated"
+	BUAKStatusSubmitted BUAKStatus = "submitted"
+	BUAKStatusConfirmed BUAKStatus = "confirmed"
+	BUAKStatusRejected  BUAKStatus = "rejected"
+)
+
+// BUAK worker categories (Arbeitnehmerkategorie)
+const (
+	BUAKKategorieArbeiter  = "arbeiter"
+	BUAKKategorieLehrling  = "lehrling"
+	BUAKKategorieUeberlass = "ueberlassen" // Überlassene Arbeitskraft (AÜG)
+)
+
+// BUAKBeitragsgruppe is the Beitragsgruppe of BUAK-pflichtige Bauarbeiter
+const BUAKBeitragsgruppe = "B1"
+
+// BUAKArbeitnehmer holds the BUAK-relevant employment data of a worker.
+// It builds on the ELDA Anmeldung of the same SV-Nummer.
+type BUAKArbeitnehmer struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	ELDAAccountID uuid.UUID  `json:"elda_account_id" db:"elda_account_id"`
+	MeldungID     *uuid.UUID `json:"meldung_id,omitempty" db:"meldung_id"` // ELDA Anmeldung
+
+	// Employee
+	SVNummer   string `json:"sv_nummer" db:"sv_nummer"`
+	Vorname    string `json:"vorname" db:"vorname"`
+	Nachname   string `json:"nachname" db:"nachname"`
+	BUAKNummer string `json:"buak_nummer,omitempty" db:"buak_nummer"` // Arbeitnehmernummer, assigned by BUAK
+
+	// Employment
+	Kategorie      string     `json:"kategorie" db:"kategorie"`
+	Taetigkeit     string     `json:"taetigkeit,omitempty" db:"taetigkeit"`
+	Stundenlohn    int64      `json:"stundenlohn" db:"stundenlohn"` // KV-Stundenlohn in cents
+	Wochenstunden  float64    `json:"wochenstunden" db:"wochenstunden"`
+	Eintrittsdatum time.Time  `json:"eintrittsdatum" db:"eintrittsdatum"`
+	Austrittsdatum *time.Time `json:"austrittsdatum,omitempty" db:"austrittsdatum"`
+
+	// Arbeitskräfteüberlassung (AÜG)
+	Ueberlassung *AUEGUeberlassung `json:"ueberlassung,omitempty" db:"ueberlassung"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// AUEGUeberlassung describes the assignment of a leased worker to a Beschäftiger.
+// Leased workers in construction are BUAK-pflichtig for the Überlasser (§ 33d BUAG).
+type AUEGUeberlassung struct {
+	BeschaeftigerName       string `json:"beschaeftiger_name"`
+	BeschaeftigerUID        string `json:"beschaeftiger_uid,omitempty"`
+	BeschaeftigerBUAKNummer string `json:"beschaeftiger_buak_nummer,omitempty"`
+	Von                     string `json:"von"`           // YYYY-MM-DD
+	Bis                     string `json:"bis,omitempty"` // YYYY-MM-DD, open if empty
+}
+
+// ActiveIn reports whether the worker was employed in the given month
+func (a *BUAKArbeitnehmer) ActiveIn(year, month int) bool {
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, -1)
+	if a.Eintrittsdatum.After(end) {
+		return false
+	}
+	return a.Austrittsdatum == nil || !a.Austrittsdatum.Before(start)
+}
+
+// BUAKMeldung is a monthly Zuschlagsmeldung to the BUAK
+type BUAKMeldung struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	ELDAAccountID   uuid.UUID  `json:"elda_account_id" db:"elda_account_id"`
+	Year            int        `json:"year" db:"year"`
+	Month           int        `json:"month" db:"month"`
+	Status          BUAKStatus `json:"status" db:"status"`
+	Protokollnummer string     `json:"protokollnummer,omitempty" db:"protokollnummer"`
+
+	// Statistics
+	TotalArbeitnehmer int   `json:"total_arbeitnehmer" db:"total_arbeitnehmer"`
+	TotalLohnsumme    int64 `json:"total_lohnsumme" db:"total_lohnsumme"` // cents
+	TotalZuschlag     int64 `json:"total_zuschlag" db:"total_zuschlag"`   // cents
+
+	// BUAK Response
+	SubmittedAt        *time.Time `json:"submitted_at,omitempty" db:"submitted_at"`
+	ConfirmedAt        *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	ConfirmationNumber string     `json:"confirmation_number,omitempty" db:"confirmation_number"`
+	RequestXML         string     `json:"-" db:"request_xml"`
+	ResponseXML        string     `json:"-" db:"response_xml"`
+	ErrorMessage       string     `json:"error_message,omitempty" db:"error_message"`
+	ErrorCode          string     `json:"error_code,omitempty" db:"error_code"`
+
+	// Audit
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Relationships (not stored in DB)
+	Positionen []*BUAKPosition `json:"positionen,omitempty" db:"-"`
+}
+
+// BUAKPosition is a single worker entry of a Zuschlagsmeldung
+type BUAKPosition struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	MeldungID      uuid.UUID `json:"meldung_id" db:"meldung_id"`
+	ArbeitnehmerID uuid.UUID `json:"arbeitnehmer_id" db:"arbeitnehmer_id"`
+
+	// Employee
+	SVNummer   string `json:"sv_nummer" db:"sv_nummer"`
+	Vorname    string `json:"vorname" db:"vorname"`
+	Nachname   string `json:"nachname" db:"nachname"`
+	BUAKNummer string `json:"buak_nummer,omitempty" db:"buak_nummer"`
+	Kategorie  string `json:"kategorie" db:"kategorie"`
+
+	// Work and wages
+	Stunden     float64 `json:"stunden" db:"stunden"`
+	Stundenlohn int64   `json:"stundenlohn" db:"stundenlohn"` // cents
+	Lohnsumme   int64   `json:"lohnsumme" db:"lohnsumme"`     // cents
+
+	// Zuschläge in cents
+	Zuschlaege BUAKZuschlaege `json:"zuschlaege" db:"-"`
+
+	// AÜG
+	BeschaeftigerName string `json:"beschaeftiger_name,omitempty" db:"beschaeftiger_name"`
+	BeschaeftigerUID  string `json:"beschaeftiger_uid,omitempty" db:"beschaeftiger_uid"`
+
+	PositionIndex int       `json:"position_index" db:"position_index"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// BUAKZuschlaege are the Zuschläge of one position in cents
+type BUAKZuschlaege struct {
+	Urlaub         int64 `json:"urlaub"`
+	Abfertigung    int64 `json:"abfertigung"`
+	Schlechtwetter int64 `json:"schlechtwetter"`
+	Ueberbrueckung int64 `json:"ueberbrueckung"`
+}
+
+// Total returns the sum of all Zuschläge
+func (z BUAKZuschlaege) Total() int64 {
+	return z.Urlaub + z.Abfertigung + z.Schlechtwetter + z.Ueberbrueckung
+}
+
+// BUAKZuschlagssaetze are the Zuschlag rates in basis points of the Lohnsumme
+type BUAKZuschlagssaetze = refdata.BUAKZuschlagssaetze
+
+// BUAK deadline: 15th of following month, like the mBGM
+const BUAKDeadlineDay = 15
+
+// GetBUAKZuschlagssaetze returns the Zuschlag rates for a year from the
+// reference parameters
+func GetBUAKZuschlagssaetze(year int) BUAKZuschlagssaetze {
+	return refdata.ForYear(year).BUAKZuschlagssaetze
+}
+
+// CalculateBUAKZuschlaege calculates the Zuschläge for a Lohnsumme in cents
+func CalculateBUAKZuschlaege(lohnsumme int64, rates BUAKZuschlagssaetze) BUAKZuschlaege {
+	calc := func(bp int64) int64 {
+		return (lohnsumme*bp + 5000) / 10000
+	}
+	return BUAKZuschlaege{
+		Urlaub:         calc(rates.Urlaub),
+		Abfertigung:    calc(rates.Abfertigung),
+		Schlechtwetter: calc(rates.Schlechtwetter),
+		Ueberbrueckung: calc(rates.Ueberbrueckung),
+	}
+}
+
+// GetBUAKDeadline returns the deadline for submitting the Zuschlagsmeldung
+func GetBUAKDeadline(year, month int) time.Time {
+	return time.Date(year, time.Month(month)+1, BUAKDeadlineDay, 23, 59, 59, 0, time.Local)
+}
+
+// BUAKArbeitnehmerCreateRequest is the request to register a BUAK worker.
+// With MeldungID set, the employee data is taken from the ELDA Anmeldung.
+type BUAKArbeitnehmerCreateRequest struct {
+	ELDAAccountID  uuid.UUID         `json:"elda_account_id"`
+	MeldungID      *uuid.UUID        `json:"meldung_id,omitempty"`
+	SVNummer       string            `json:"sv_nummer,omitempty"`
+	Vorname        string            `json:"vorname,omitempty"`
+	Nachname       string            `json:"nachname,omitempty"`
+	BUAKNummer     string            `json:"buak_nummer,omitempty"`
+	Kategorie      string            `json:"kategorie,omitempty"`
+	Taetigkeit     string            `json:"taetigkeit,omitempty"`
+	Stundenlohn    int64             `json:"stundenlohn,omitempty"`
+	Wochenstunden  float64           `json:"wochenstunden,omitempty"`
+	Eintrittsdatum string            `json:"eintrittsdatum,omitempty"` // YYYY-MM-DD
+	Austrittsdatum string            `json:"austrittsdatum,omitempty"` // YYYY-MM-DD
+	Ueberlassung   *AUEGUeberlassung `json:"ueberlassung,omitempty"`
+}
+
+// BUAKMeldungCreateRequest is the request to create a Zuschlagsmeldung.
+// Workers without an entry in Stunden are reported with their contractual hours.
+type BUAKMeldungCreateRequest struct {
+	ELDAAccountID uuid.UUID         `json:"elda_account_id"`
+	Year          int               `json:"year"`
+	Month         int               `json:"month"`
+	Stunden       []BUAKStundenItem `json:"stunden,omitempty"`
+}
+
+// BUAKStundenItem overrides the hours and wages of one worker
+type BUAKStundenItem struct {
+	ArbeitnehmerID uuid.UUID `json:"arbeitnehmer_id"`
+	Stunden        float64   `json:"stunden"`
+	Lohnsumme      *int64    `json:"lohnsumme,omitempty"` // cents, defaults to Stundenlohn × Stunden
+}
+
+// XML types for BUAK submission
+
+// BUAKDocument is the XML document for the Zuschlagsmeldung
+type BUAKDocument struct {
+	XMLName    xml.Name     `xml:"BUAKZuschlagsmeldung"`
+	XMLNS      string       `xml:"xmlns,attr"`
+	Kopf       BUAKKopf     `xml:"Kopf"`
+	Positionen []BUAKXMLPos `xml:"Positionen>Position"`
+}
+
+// BUAKKopf is the header section
+type BUAKKopf struct {
+	DienstgeberNummer string `xml:"DienstgeberNummer"`
+	Jahr              int    `xml:"Meldezeitraum>Jahr"`
+	Monat             int    `xml:"Meldezeitraum>Monat"`
+	Erstellungsdatum  string `xml:"Erstellungsdatum"`
+	SummeLohn         string `xml:"SummeLohn"`
+	SummeZuschlag     string `xml:"SummeZuschlag"`
+}
+
+// BUAKXMLPos is a single position in XML format
+type BUAKXMLPos struct {
+	SVNummer          string         `xml:"SVNummer"`
+	BUAKNummer        string         `xml:"ArbeitnehmerNummer,omitempty"`
+	Familienname      string         `xml:"Familienname"`
+	Vorname           string         `xml:"Vorname"`
+	Kategorie         string         `xml:"Kategorie"`
+	Stunden           string         `xml:"Stunden"`
+	Stundenlohn       string         `xml:"Stundenlohn"`
+	Lohnsumme         string         `xml:"Lohnsumme"`
+	ZuschlagUrlaub    string         `xml:"Zuschlaege>Urlaub"`
+	ZuschlagAbfertig  string         `xml:"Zuschlaege>Abfertigung"`
+	ZuschlagSchlechtw string         `xml:"Zuschlaege>Schlechtwetter"`
+	ZuschlagUeberbr   string         `xml:"Zuschlaege>Ueberbrueckung"`
+	Ueberlassung      *BUAKXMLUeberl `xml:"Ueberlassung,omitempty"`
+}
+
+// BUAKXMLUeberl is the AÜG section of a position
+type BUAKXMLUeberl struct {
+	BeschaeftigerName string `xml:"BeschaeftigerName"`
+	BeschaeftigerUID  string `xml:"BeschaeftigerUID,omitempty"`
+}
+
+// BUAKResponse is the response for a Zuschlagsmeldung submission
+type BUAKResponse struct {
+	XMLName         xml.Name `xml:"BUAKResponse"`
+	Erfolg          bool     `xml:"Erfolg"`
+	Protokollnummer string   `xml:"Protokollnummer,omitempty"`
+	ErrorCode       string   `xml:"FehlerCode,omitempty"`
+	ErrorMessage    string   `xml:"FehlerMeldung,omitempty"`
+	Warnungen       []string `xml:"Warnungen>Warnung,omitempty"`
+}
@@ -11,6 +11,9 @@ import (
 	"log/slog"
 	"net/http"
 	"time"
+
+	"austrian-business-infrastructure/internal/rawpayload"
+	"austrian-business-infrastructure/pkg/httpclient"
 )
 
 const (
@@ -28,6 +31,9 @@ const (
 
 	// Maximum retries for transient errors
 	DefaultMaxRetries = 3
+
+	// DefaultBudget bounds a call including its retries
+	DefaultBudget = 5 * time.Minute
 )
 
 var (
@@ -44,16 +50,24 @@ type ClientConfig struct {
 	MaxRetries  int
 	Certificate *Certificate
 	Logger      *slog.Logger
+	// Resolver, if set, picks the endpoint per request instead of Endpoint
+	Resolver EndpointResolver
 }
 
+// EndpointResolver returns the endpoint to use, e.g. after a switchover to
+// a migrated endpoint. It fails during maintenance windows, so that no
+// request is sent.
+type EndpointResolver func() (string, error)
+
 // Client handles ELDA API communication
 type Client struct {
 	endpoint    string
-	httpClient  *http.Client
+	httpClient  *httpclient.Client
 	timeout     time.Duration
 	maxRetries  int
 	certificate *Certificate
 	logger      *slog.Logger
+	resolve     EndpointResolver
 }
 
 // NewClient creates a new ELDA client
@@ -90,20 +104,18 @@ func NewClientWithConfig(cfg ClientConfig) *Client {
 		logger = slog.Default()
 	}
 
-	// Create HTTP client with TLS config if certificate provided
-	httpClient := &http.Client{
-		Timeout: timeout,
-	}
-
+	// Transient failures are retried with jittered backoff, except for
+	// calls made with Call
+	httpCfg := httpclient.DefaultConfig("elda")
+	httpCfg.Timeout = timeout
+	httpCfg.Budget = DefaultBudget
+	httpCfg.MaxRetries = maxRetries
+	httpCfg.Backoff.Initial = time.Second
+	httpCfg.Hooks.AfterAttempt = httpclient.LogAttempts(logger)
 	if cfg.Certificate != nil && cfg.Certificate.TLSCert != nil {
-		transport := &http.Transport{
-			TLSClientConfig: &tls.Config{
-				Certificates: []tls.Certificate{*cfg.Certificate.TLSCert},
-				MinVersion:   tls.VersionTLS12,
-			},
-		}
-		httpClient.Transport = transport
+		httpCfg.Transport = certificateTransport(cfg.Certificate)
 	}
+	httpClient := httpclient.New(httpCfg)
 
 	return &Client{
 		endpoint:    endpoint,
@@ -112,25 +124,40 @@ func NewClientWithConfig(cfg ClientConfig) *Client {
 		maxRetries:  maxRetries,
 		certificate: cfg.Certificate,
 		logger:      logger,
+		resolve:     cfg.Resolver,
 	}
 }
 
+// SetEndpointResolver makes the client resolve the endpoint per request
+func (c *Client) SetEndpointResolver(r EndpointResolver) {
+	c.resolve = r
+}
+
 // SetCertificate updates the client's certificate
 func (c *Client) SetCertificate(cert *Certificate) {
 	c.certificate = cert
 	if cert != nil && cert.TLSCert != nil {
-		transport := &http.Transport{
-			TLSClientConfig: &tls.Config{
-				Certificates: []tls.Certificate{*cert.TLSCert},
-				MinVersion:   tls.VersionTLS12,
-			},
-		}
-		c.httpClient.Transport = transport
+		c.httpClient.SetTransport(certificateTransport(cert))
+	}
+}
+
+// certificateTransport authenticates with the client certificate
+func certificateTransport(cert *Certificate) *http.Transport {
+	return &http.Transport{
+		TLSClientConfig: &tls.Config{
+			Certificates: []tls.Certificate{*cert.TLSCert},
+			MinVersion:   tls.VersionTLS12,
+		},
 	}
 }
 
 // GetEndpoint returns the current endpoint
 func (c *Client) GetEndpoint() string {
+	if c.resolve != nil {
+		if endpoint, err := c.resolve(); err == nil {
+			return endpoint
+		}
+	}
 	return c.endpoint
 }
 
@@ -146,12 +173,34 @@ func soapEnvelope(body []byte) []byte {
 	return buf.Bytes()
 }
 
-// call makes a SOAP call to ELDA
+// Caller makes ELDA SOAP calls. *Client implements it against the ELDA
+// endpoint; internal/fakes provides a scripted in-memory implementation.
+type Caller interface {
+	// Call makes a single attempt, for submissions that must not be sent twice
+	Call(ctx context.Context, action string, request interface{}, response interface{}) error
+	// CallWithRetry retries transient failures
+	CallWithRetry(ctx context.Context, action string, request interface{}, response interface{}) error
+}
+
+var _ Caller = (*Client)(nil)
+
+// Call makes a single SOAP call to ELDA
+func (c *Client) Call(ctx context.Context, action string, request interface{}, response interface{}) error {
+	return c.callWithContext(httpclient.WithoutRetry(ctx), action, request, response)
+}
+
+// CallWithRetry makes a SOAP call to ELDA, retrying transient errors
+func (c *Client) CallWithRetry(ctx context.Context, action string, request interface{}, response interface{}) error {
+	return c.callWithContext(ctx, action, request, response)
+}
+
+// call makes a single SOAP call to ELDA
 func (c *Client) call(action string, request interface{}, response interface{}) error {
-	return c.callWithContext(context.Background(), action, request, response)
+	return c.Call(context.Background(), action, request, response)
 }
 
-// callWithContext makes a SOAP call to ELDA with context
+// callWithContext makes a SOAP call to ELDA with context. Transient failures
+// are retried unless ctx is marked with httpclient.WithoutRetry.
 func (c *Client) callWithContext(ctx context.Context, action string, request interface{}, response interface{}) error {
 	// Marshal request body
 	body, err := xml.Marshal(request)
@@ -162,8 +211,15 @@ func (c *Client) callWithContext(ctx context.Context, action string, request int
 	// Wrap in SOAP envelope
 	soapBody := soapEnvelope(body)
 
+	endpoint := c.endpoint
+	if c.resolve != nil {
+		if endpoint, err = c.resolve(); err != nil {
+			return err
+		}
+	}
+
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(soapBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(soapBody))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -171,10 +227,17 @@ func (c *Client) callWithContext(ctx context.Context, action string, request int
 	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
 	req.Header.Set("SOAPAction", action)
 
+	// Record the raw exchanges if the caller asked for it
+	if rec := rawpayload.FromContext(ctx); rec != nil {
+		req = req.WithContext(httpclient.WithObserver(ctx, func(a *httpclient.Attempt) {
+			rec.Record(recordedExchange(action, a))
+		}))
+	}
+
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrELDAConnection, err)
+		return fmt.Errorf("%w: %w", ErrELDAConnection, err)
 	}
 	defer resp.Body.Close()
 
@@ -193,45 +256,25 @@ func (c *Client) callWithContext(ctx context.Context, action string, request int
 	return parseSOAPResponse(respBody, response)
 }
 
-// callWithRetry makes a SOAP call with retry logic for transient errors
-func (c *Client) callWithRetry(ctx context.Context, action string, request interface{}, response interface{}) error {
-	backoff := []time.Duration{1 * time.Second, 5 * time.Second, 30 * time.Second}
-
-	var lastErr error
-	for i := 0; i <= c.maxRetries; i++ {
-		err := c.callWithContext(ctx, action, request, response)
-		if err == nil {
-			return nil
-		}
-
-		lastErr = err
-
-		// Check if error is retryable
-		if !IsRetryable(err) && !errors.Is(err, ErrELDAConnection) {
-			return err
-		}
-
-		// Check context
-		if ctx.Err() != nil {
-			return ctx.Err()
-		}
-
-		// Don't wait after the last attempt
-		if i < c.maxRetries && i < len(backoff) {
-			c.logger.Warn("ELDA request failed, retrying",
-				"attempt", i+1,
-				"wait", backoff[i],
-				"error", err)
-
-			select {
-			case <-time.After(backoff[i]):
-			case <-ctx.Done():
-				return ctx.Err()
-			}
+// recordedExchange converts an attempt into a raw payload exchange
+func recordedExchange(action string, a *httpclient.Attempt) rawpayload.Exchange {
+	ex := rawpayload.Exchange{
+		Operation: action,
+		Endpoint:  a.Request.URL.String(),
+		Request:   a.RequestBody,
+		Response:  a.ResponseBody,
+		StartedAt: a.StartedAt,
+		Duration:  a.Duration,
+	}
+	if a.Err != nil {
+		ex.Error = a.Err.Error()
+	} else {
+		ex.StatusCode = a.Response.StatusCode
+		if ex.StatusCode != http.StatusOK {
+			ex.Error = fmt.Sprintf("HTTP %d", ex.StatusCode)
 		}
 	}
-
-	return fmt.Errorf("ELDA request failed after %d retries: %w", c.maxRetries, lastErr)
+	return ex
 }
 
 // TestConnection tests the connection to ELDA
@@ -251,7 +294,7 @@ func (c *Client) TestConnection(ctx context.Context) (*ConnectionTestResult, err
 	req := pingRequest{XMLNS: ELDANS}
 	var resp pingResponse
 
-	err := c.callWithContext(ctx, "Ping", &req, &resp)
+	err := c.Call(ctx, "Ping", &req, &resp)
 	latency := time.Since(start)
 
 	result := &ConnectionTestResult{
@@ -440,31 +483,39 @@ func GenerateAnmeldungXML(creds *ELDACredentials, anmeldung *ELDAAnmeldung) ([]b
 	return result, nil
 }
 
-// SubmitExtendedMeldung submits an extended meldung (An-/Ab-/Änderungsmeldung)
-func (c *Client) SubmitExtendedMeldung(ctx context.Context, creds *ELDACredentials, meldung *ELDAMeldung) (*MeldungResponse, error) {
-	// Build XML based on meldung type
-	var xmlDoc interface{}
-	var action string
-
+// BuildMeldungDocument returns the document and SOAP action an extended
+// meldung is submitted with
+func BuildMeldungDocument(creds *ELDACredentials, meldung *ELDAMeldung) (interface{}, string, error) {
 	switch meldung.Type {
 	case MeldungTypeAnmeldung:
-		xmlDoc = buildExtendedAnmeldungXML(creds, meldung)
-		action = "SubmitAnmeldung"
+		return buildExtendedAnmeldungXML(creds, meldung), "SubmitAnmeldung", nil
 	case MeldungTypeAbmeldung:
-		xmlDoc = buildExtendedAbmeldungXML(creds, meldung)
-		action = "SubmitAbmeldung"
+		return buildExtendedAbmeldungXML(creds, meldung), "SubmitAbmeldung", nil
 	case MeldungTypeAenderung:
-		xmlDoc = buildAenderungXML(creds, meldung)
-		action = "SubmitAenderung"
+		return buildAenderungXML(creds, meldung), "SubmitAenderung", nil
 	case MeldungTypeKorrektur:
-		xmlDoc = buildKorrekturXML(creds, meldung)
-		action = "SubmitKorrektur"
+		return buildKorrekturXML(creds, meldung), "SubmitKorrektur", nil
 	default:
-		return nil, fmt.Errorf("unsupported meldung type: %s", meldung.Type)
+		return nil, "", fmt.Errorf("unsupported meldung type: %s", meldung.Type)
+	}
+}
+
+// SubmitExtendedMeldung submits an extended meldung (An-/Ab-/Änderungsmeldung)
+func (c *Client) SubmitExtendedMeldung(ctx context.Context, creds *ELDACredentials, meldung *ELDAMeldung) (*MeldungResponse, error) {
+	return SubmitExtendedMeldung(ctx, c, creds, meldung)
+}
+
+// SubmitExtendedMeldung submits an extended meldung through any caller. The
+// call is not retried, so a meldung is never submitted twice.
+func SubmitExtendedMeldung(ctx context.Context, c Caller, creds *ELDACredentials, meldung *ELDAMeldung) (*MeldungResponse, error) {
+	// Build XML based on meldung type
+	xmlDoc, action, err := BuildMeldungDocument(creds, meldung)
+	if err != nil {
+		return nil, err
 	}
 
 	var resp MeldungResponse
-	err := c.callWithContext(ctx, action, xmlDoc, &resp)
+	err = c.Call(ctx, action, xmlDoc, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -11,11 +11,11 @@ import (
 
 // DataboxService handles ELDA databox operations
 type DataboxService struct {
-	client *Client
+	client Caller
 }
 
 // NewDataboxService creates a new ELDA databox service
-func NewDataboxService(client *Client) *DataboxService {
+func NewDataboxService(client Caller) *DataboxService {
 	return &DataboxService{client: client}
 }
 
@@ -88,7 +88,7 @@ func (s *DataboxService) ListDocuments(ctx context.Context, req *DataboxListRequ
 	}
 
 	var resp DataboxListResponse
-	err := s.client.callWithRetry(ctx, "DataboxList", &xmlReq, &resp)
+	err := s.client.CallWithRetry(ctx, "DataboxList", &xmlReq, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("list databox documents failed: %w", err)
 	}
@@ -140,7 +140,7 @@ func (s *DataboxService) GetDocument(ctx context.Context, documentID string) (*D
 	}
 
 	var resp getResponse
-	err := s.client.callWithRetry(ctx, "DataboxGet", &req, &resp)
+	err := s.client.CallWithRetry(ctx, "DataboxGet", &req, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("get document failed: %w", err)
 	}
@@ -202,7 +202,7 @@ func (s *DataboxService) MarkAsRead(ctx context.Context, documentID string) erro
 	}
 
 	var resp markResponse
-	err := s.client.callWithRetry(ctx, "DataboxMarkRead", &req, &resp)
+	err := s.client.CallWithRetry(ctx, "DataboxMarkRead", &req, &resp)
 	if err != nil {
 		return fmt.Errorf("mark as read failed: %w", err)
 	}
@@ -229,7 +229,7 @@ func (s *DataboxService) GetUnreadCount(ctx context.Context) (int, error) {
 	req := countRequest{XMLNS: ELDANS}
 
 	var resp countResponse
-	err := s.client.callWithRetry(ctx, "DataboxUnreadCount", &req, &resp)
+	err := s.client.CallWithRetry(ctx, "DataboxUnreadCount", &req, &resp)
 	if err != nil {
 		return 0, fmt.Errorf("get unread count failed: %w", err)
 	}
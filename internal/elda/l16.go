@@ -8,15 +8,17 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/rawpayload"
 )
 
 // L16Service handles L16 Lohnzettel ELDA protocol operations
 type L16Service struct {
-	client *Client
+	client Caller
 }
 
 // NewL16Service creates a new L16 service
-func NewL16Service(client *Client) *L16Service {
+func NewL16Service(client Caller) *L16Service {
 	return &L16Service{client: client}
 }
 
@@ -45,7 +47,7 @@ func (s *L16Service) SubmitL16(ctx context.Context, doc *L16Document) (*L16Submi
 	}
 
 	var resp L16Response
-	err = s.client.callWithRetry(ctx, "SubmitLohnzettel", &req, &resp)
+	err = s.client.CallWithRetry(ctx, "SubmitLohnzettel", &req, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("ELDA L16 submission failed: %w", err)
 	}
@@ -119,7 +121,11 @@ func (s *L16Service) SubmitL16Batch(ctx context.Context, docs []*L16BatchItem, m
 			}
 
 			// Submit the L16
-			submitResult, err := s.SubmitL16(ctx, batchItem.Document)
+			itemCtx := ctx
+			if batchItem.Recorder != nil {
+				itemCtx = rawpayload.WithRecorder(ctx, batchItem.Recorder)
+			}
+			submitResult, err := s.SubmitL16(itemCtx, batchItem.Document)
 
 			mu.Lock()
 			defer mu.Unlock()
@@ -156,6 +162,7 @@ func (s *L16Service) SubmitL16Batch(ctx context.Context, docs []*L16BatchItem, m
 type L16BatchItem struct {
 	LohnzettelID uuid.UUID
 	Document     *L16Document
+	Recorder     *rawpayload.Recorder // Optional: captures the raw exchange of this item
 }
 
 // L16BatchSubmitResult contains the result of a batch submission
@@ -202,7 +209,7 @@ func (s *L16Service) QueryL16Status(ctx context.Context, protokollnummer string)
 	}
 
 	var resp statusResponse
-	err := s.client.callWithRetry(ctx, "LohnzettelStatusAbfrage", &req, &resp)
+	err := s.client.CallWithRetry(ctx, "LohnzettelStatusAbfrage", &req, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("L16 status query failed: %w", err)
 	}
@@ -263,7 +270,7 @@ func (s *L16Service) SubmitL16Berichtigung(ctx context.Context, doc *L16Document
 	}
 
 	var resp L16Response
-	err = s.client.callWithRetry(ctx, "SubmitLohnzettelBerichtigung", &req, &resp)
+	err = s.client.CallWithRetry(ctx, "SubmitLohnzettelBerichtigung", &req, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("L16 correction submission failed: %w", err)
 	}
@@ -314,7 +321,7 @@ func (s *L16Service) ValidateL16(ctx context.Context, doc *L16Document) (*L16Val
 	}
 
 	var resp validateResponse
-	err = s.client.callWithRetry(ctx, "ValidateLohnzettel", &req, &resp)
+	err = s.client.CallWithRetry(ctx, "ValidateLohnzettel", &req, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("L16 validation request failed: %w", err)
 	}
@@ -425,7 +432,7 @@ func (s *L16Service) GetL16Statistics(ctx context.Context, year int) (*L16Statis
 	}
 
 	var resp statsResponse
-	err := s.client.callWithRetry(ctx, "LohnzettelStatistik", &req, &resp)
+	err := s.client.CallWithRetry(ctx, "LohnzettelStatistik", &req, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("L16 statistics query failed: %w", err)
 	}
@@ -9,11 +9,11 @@ import (
 
 // MBGMService handles mBGM ELDA protocol operations
 type MBGMService struct {
-	client *Client
+	client Caller
 }
 
 // NewMBGMService creates a new mBGM service
-func NewMBGMService(client *Client) *MBGMService {
+func NewMBGMService(client Caller) *MBGMService {
 	return &MBGMService{client: client}
 }
 
@@ -42,7 +42,7 @@ func (s *MBGMService) SubmitMBGM(ctx context.Context, doc *MBGMDocument) (*MBGMS
 	}
 
 	var resp MBGMResponse
-	err = s.client.callWithRetry(ctx, "SubmitMBGM", &req, &resp)
+	err = s.client.CallWithRetry(ctx, "SubmitMBGM", &req, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("ELDA submission failed: %w", err)
 	}
@@ -105,7 +105,7 @@ func (s *MBGMService) QueryMBGMStatus(ctx context.Context, dienstgeberNr, protok
 	}
 
 	var resp statusResponse
-	err := s.client.callWithRetry(ctx, "MBGMStatusAbfrage", &req, &resp)
+	err := s.client.CallWithRetry(ctx, "MBGMStatusAbfrage", &req, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("status query failed: %w", err)
 	}
@@ -160,7 +160,7 @@ func (s *MBGMService) SubmitCorrection(ctx context.Context, doc *MBGMDocument, o
 	}
 
 	var resp MBGMResponse
-	err := s.client.callWithRetry(ctx, "SubmitMBGMKorrektur", &req, &resp)
+	err := s.client.CallWithRetry(ctx, "SubmitMBGMKorrektur", &req, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("correction submission failed: %w", err)
 	}
@@ -201,7 +201,7 @@ func (s *MBGMService) ValidateMBGM(ctx context.Context, doc *MBGMDocument) (*MBG
 	}
 
 	var resp validateResponse
-	err := s.client.callWithRetry(ctx, "ValidateMBGM", &req, &resp)
+	err := s.client.CallWithRetry(ctx, "ValidateMBGM", &req, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("validation request failed: %w", err)
 	}
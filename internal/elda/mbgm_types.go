@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/refdata"
 )
 
 // mBGM Status constants
@@ -167,38 +169,35 @@ type Kollektivvertrag struct {
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
 
-// 2025 Limits (update yearly)
-const (
-	// Geringfügigkeitsgrenze 2025
-	GeringfuegigkeitsGrenze2025 = 518.44
-
-	// Höchstbeitragsgrundlage 2025
-	HoechstbeitragsGrundlage2025 = 6060.00
-
-	// mBGM deadline: 15th of following month
-	MBGMDeadlineDay = 15
-)
-
 // GetMBGMDeadline returns the deadline for submitting mBGM
 func GetMBGMDeadline(year, month int) time.Time {
-	// Deadline is 15th of the following month
+	// Deadline is the statutory day (15th) of the following month
+	day := refdata.For(time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)).MBGMFristTag
 	nextMonth := month + 1
 	nextYear := year
 	if nextMonth > 12 {
 		nextMonth = 1
 		nextYear++
 	}
-	return time.Date(nextYear, time.Month(nextMonth), MBGMDeadlineDay, 23, 59, 59, 0, time.Local)
+	return time.Date(nextYear, time.Month(nextMonth), day, 23, 59, 59, 0, time.Local)
+}
+
+// GeringfuegigkeitsGrenze returns the monthly Geringfügigkeitsgrenze of a year in EUR
+func GeringfuegigkeitsGrenze(year int) float64 {
+	return refdata.EUR(refdata.ForYear(year).Geringfuegigkeitsgrenze)
+}
+
+// HoechstbeitragsGrundlage returns the monthly Höchstbeitragsgrundlage of a year in EUR
+func HoechstbeitragsGrundlage(year int) float64 {
+	return refdata.EUR(refdata.ForYear(year).Hoechstbeitragsgrundlage)
 }
 
 // IsGeringfuegig checks if the amount is below the Geringfügigkeitsgrenze
 func IsGeringfuegig(monthlyAmount float64, year int) bool {
-	// TODO: load from config based on year
-	return monthlyAmount <= GeringfuegigkeitsGrenze2025
+	return monthlyAmount <= GeringfuegigkeitsGrenze(year)
 }
 
 // ExceedsHoechstbeitrag checks if the amount exceeds the Höchstbeitragsgrundlage
 func ExceedsHoechstbeitrag(monthlyAmount float64, year int) bool {
-	// TODO: load from config based on year
-	return monthlyAmount > HoechstbeitragsGrundlage2025
+	return monthlyAmount > HoechstbeitragsGrundlage(year)
 }
@@ -35,7 +35,8 @@ type ELDAMeldung struct {
 	Type           MeldungType   `json:"type" db:"type"`
 	Status         MeldungStatus `json:"status" db:"status"`
 
-	// Employee data
+	// Employee data, prefilled from the Dienstnehmer registry if set
+	DienstnehmerID *uuid.UUID `json:"dienstnehmer_id,omitempty" db:"dienstnehmer_id"`
 	SVNummer     string     `json:"sv_nummer" db:"sv_nummer"`
 	Vorname      string     `json:"vorname" db:"vorname"`
 	Nachname     string     `json:"nachname" db:"nachname"`
@@ -74,6 +75,10 @@ type ELDAMeldung struct {
 	ErrorCode       string     `json:"error_code,omitempty" db:"error_code"`
 	ErrorMessage    string     `json:"error_message,omitempty" db:"error_message"`
 
+	// ELDA Rückmeldung (Protokoll), polled after the submission
+	ProcessedAt        *time.Time        `json:"processed_at,omitempty" db:"processed_at"`
+	RueckmeldungErrors []ProtokollFehler `json:"rueckmeldung_errors,omitempty" db:"rueckmeldung_errors"`
+
 	// Audit
 	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
@@ -143,6 +148,9 @@ type Bankverbindung struct {
 type MeldungCreateRequest struct {
 	ELDAAccountID     uuid.UUID               `json:"elda_account_id" validate:"required"`
 	Type              MeldungType             `json:"type" validate:"required"`
+	// DienstnehmerID prefills the employee data from the registry; fields
+	// set in the request take precedence
+	DienstnehmerID    *uuid.UUID              `json:"dienstnehmer_id,omitempty"`
 	SVNummer          string                  `json:"sv_nummer" validate:"required,len=10"`
 	Vorname           string                  `json:"vorname" validate:"required,max=100"`
 	Nachname          string                  `json:"nachname" validate:"required,max=100"`
@@ -0,0 +1,93 @@
+package elda
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"austrian-business-infrastructure/internal/timezone"
+)
+
+// Protokoll states reported by the ProtokollAbfrage
+const (
+	ProtokollInBearbeitung = "IN_BEARBEITUNG"
+	ProtokollAngenommen    = "ANGENOMMEN"
+	ProtokollAbgelehnt     = "ABGELEHNT"
+)
+
+// ProtokollAbfrageRequest asks ELDA for the Protokoll of a submitted meldung
+type ProtokollAbfrageRequest struct {
+	XMLName         xml.Name `xml:"ProtokollAbfrage"`
+	XMLNS           string   `xml:"xmlns,attr"`
+	DienstgeberNr   string   `xml:"DienstgeberNr"`
+	Protokollnummer string   `xml:"Protokollnummer"`
+}
+
+// ProtokollAbfrageResponse is the Rückmeldung of ELDA for a meldung. A
+// meldung still in processing has no VerarbeitetAm.
+type ProtokollAbfrageResponse struct {
+	XMLName         xml.Name          `xml:"ProtokollAbfrageResponse"`
+	Protokollnummer string            `xml:"Protokollnummer"`
+	Status          string            `xml:"Status"`
+	VerarbeitetAm   string            `xml:"VerarbeitetAm,omitempty"`
+	Fehler          []ProtokollFehler `xml:"Fehlerliste>Fehler,omitempty"`
+}
+
+// ProtokollFehler is an error or warning of a Protokoll
+type ProtokollFehler struct {
+	Code  string `xml:"FehlerCode" json:"code"`
+	Text  string `xml:"FehlerText" json:"text"`
+	Field string `xml:"Feld,omitempty" json:"field,omitempty"`
+}
+
+// Rueckmeldung is the outcome of a meldung as reported by ELDA
+type Rueckmeldung struct {
+	Protokollnummer string
+	// Status is accepted or rejected once ELDA processed the meldung,
+	// submitted while it is still in processing
+	Status      MeldungStatus
+	ProcessedAt *time.Time
+	Errors      []ProtokollFehler
+}
+
+// QueryProtokoll asks ELDA for the Rückmeldung of a submitted meldung. The
+// query has no side effects and is retried on transient failures.
+func QueryProtokoll(ctx context.Context, c Caller, dienstgeberNr, protokollnummer string) (*Rueckmeldung, error) {
+	req := ProtokollAbfrageRequest{
+		XMLNS:           ELDANS,
+		DienstgeberNr:   dienstgeberNr,
+		Protokollnummer: protokollnummer,
+	}
+
+	var resp ProtokollAbfrageResponse
+	if err := c.CallWithRetry(ctx, "ProtokollAbfrage", &req, &resp); err != nil {
+		return nil, fmt.Errorf("ELDA Protokoll query failed: %w", err)
+	}
+
+	return resp.Rueckmeldung(), nil
+}
+
+// Rueckmeldung converts the response. Unknown states count as still in
+// processing.
+func (r *ProtokollAbfrageResponse) Rueckmeldung() *Rueckmeldung {
+	rm := &Rueckmeldung{
+		Protokollnummer: r.Protokollnummer,
+		Status:          MeldungStatusSubmitted,
+		Errors:          r.Fehler,
+	}
+	switch r.Status {
+	case ProtokollAngenommen:
+		rm.Status = MeldungStatusAccepted
+	case ProtokollAbgelehnt:
+		rm.Status = MeldungStatusRejected
+	}
+
+	// ELDA reports Austrian local time
+	if r.VerarbeitetAm != "" {
+		if t, err := time.ParseInLocation("2006-01-02T15:04:05", r.VerarbeitetAm, timezone.Vienna); err == nil {
+			rm.ProcessedAt = &t
+		}
+	}
+	return rm
+}
@@ -25,7 +25,7 @@ type Service struct {
 }
 
 // NewService creates a new ELDA databox service
-func NewService(pool *pgxpool.Pool, eldaClient *elda.Client, storage StorageService) *Service {
+func NewService(pool *pgxpool.Pool, eldaClient elda.Caller, storage StorageService) *Service {
 	return &Service{
 		repo:    NewRepository(pool),
 		databox: elda.NewDataboxService(eldaClient),
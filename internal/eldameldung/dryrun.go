@@ -0,0 +1,125 @@
+package eldameldung
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/dryrun"
+	"austrian-business-infrastructure/internal/elda"
+)
+
+// Abmeldungen are due within seven days after the end of employment (§ 33 ASVG)
+const abmeldungFrist = 7 * 24 * time.Hour
+
+// SetSandbox sets the client for the ELDA test endpoint that dry runs may
+// submit to
+func (s *Service) SetSandbox(client elda.Caller) {
+	s.sandbox = client
+}
+
+// CheckRequest adds the findings of the field and deadline rules of a
+// meldung to the report
+func CheckRequest(report *dryrun.Report, req *elda.MeldungCreateRequest, now time.Time) {
+	for _, msg := range NewValidator().ValidateCreateRequest(req).Errors {
+		field, message, ok := strings.Cut(msg, ": ")
+		if !ok {
+			field, message = "", msg
+		}
+		report.Error("invalid", field, message)
+	}
+
+	parsed := make(map[string]time.Time)
+	for _, d := range []struct{ field, value string }{
+		{"geburtsdatum", req.Geburtsdatum},
+		{"eintrittsdatum", req.Eintrittsdatum},
+		{"austrittsdatum", req.Austrittsdatum},
+		{"aenderung_datum", req.AenderungDatum},
+	} {
+		if d.value == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", d.value)
+		if err != nil {
+			report.Error("invalid_date", d.field, "Datum im Format JJJJ-MM-TT erwartet")
+			continue
+		}
+		parsed[d.field] = t
+	}
+
+	if birth, ok := parsed["geburtsdatum"]; ok && req.SVNummer != "" {
+		if err := elda.ValidateSVNummerWithBirthDate(req.SVNummer, birth); err != nil {
+			report.Warn("birth_date_mismatch", "geburtsdatum", err.Error())
+		}
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch req.Type {
+	case elda.MeldungTypeAnmeldung:
+		// Anmeldungen are due before work starts
+		if start, ok := parsed["eintrittsdatum"]; ok && start.Before(today) {
+			report.Warn("late_anmeldung", "eintrittsdatum", "Anmeldung ist vor Arbeitsantritt zu erstatten; das Eintrittsdatum liegt in der Vergangenheit")
+		}
+	case elda.MeldungTypeAbmeldung:
+		if end, ok := parsed["austrittsdatum"]; ok {
+			if end.Add(abmeldungFrist).Before(today) {
+				report.Warn("late_abmeldung", "austrittsdatum", "Abmeldung ist binnen 7 Tagen nach dem Austritt zu erstatten")
+			}
+			if start, ok := parsed["eintrittsdatum"]; ok && end.Before(start) {
+				report.Error("invalid_period", "austrittsdatum", "Austrittsdatum liegt vor dem Eintrittsdatum")
+			}
+		}
+	}
+}
+
+// DryRun validates a meldung as Create and Submit would and renders its XML
+// without storing anything. With sandbox set, the meldung is also sent to
+// the ELDA test endpoint and its answer added to the report.
+func (s *Service) DryRun(ctx context.Context, req *elda.MeldungCreateRequest, sandbox bool) (*dryrun.Report, error) {
+	report := dryrun.NewReport(dryrun.KindELDAMeldung)
+	now := time.Now()
+	CheckRequest(report, req, now)
+	if report.HasErrors() {
+		return report.Finish(now), nil
+	}
+
+	meldung := newMeldung(req)
+	for _, msg := range s.validator.ValidateMeldung(meldung).Errors {
+		field, message, _ := strings.Cut(msg, ": ")
+		report.Error("invalid", field, message)
+	}
+	if report.HasErrors() {
+		return report.Finish(now), nil
+	}
+	if _, err := s.buildXML(meldung); err != nil {
+		report.Error("xml", "", err.Error())
+		return report.Finish(now), nil
+	}
+	if _, _, err := elda.BuildMeldungDocument(&elda.ELDACredentials{}, meldung); err != nil {
+		report.Error("xml", "", err.Error())
+		return report.Finish(now), nil
+	}
+
+	if sandbox {
+		if s.sandbox == nil {
+			report.Warn("sandbox_unavailable", "", "ELDA-Testsystem ist nicht konfiguriert")
+			return report.Finish(now), nil
+		}
+		resp, err := elda.SubmitExtendedMeldung(ctx, s.sandbox, &elda.ELDACredentials{}, meldung)
+		if err != nil {
+			report.Warn("sandbox_unavailable", "", fmt.Sprintf("ELDA-Testsystem nicht erreichbar: %v", err))
+			return report.Finish(now), nil
+		}
+		report.Sandbox = &dryrun.SandboxResult{
+			Accepted:  resp.Erfolg,
+			Reference: resp.Protokollnummer,
+			ErrorCode: resp.ErrorCode,
+			Message:   resp.ErrorMessage,
+			Warnings:  resp.Warnungen,
+		}
+		if !resp.Erfolg {
+			report.Error("sandbox_rejected", "", resp.ErrorMessage)
+		}
+	}
+	return report.Finish(now), nil
+}
@@ -35,6 +35,7 @@ func (h *Handler) Routes() chi.Router {
 	r.Delete("/{id}", h.Delete)
 
 	// Validation and preview
+	r.Post("/dry-run", h.DryRun)
 	r.Post("/{id}/validate", h.Validate)
 	r.Get("/{id}/preview", h.Preview)
 
@@ -59,6 +60,13 @@ func (h *Handler) Routes() chi.Router {
 	return r
 }
 
+// RegisterDryRunRoute registers only the dry run on the main router. It
+// stores nothing, so unlike the other meldung routes it is safe to serve to
+// every tenant.
+func (h *Handler) RegisterDryRunRoute(router *api.Router, requireAuth func(http.Handler) http.Handler) {
+	router.Handle("POST /api/v1/elda-meldungen/dry-run", requireAuth(http.HandlerFunc(h.DryRun)))
+}
+
 // Create handles POST /api/v1/elda-meldungen
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	var req elda.MeldungCreateRequest
@@ -201,6 +209,25 @@ func (h *Handler) Validate(w http.ResponseWriter, r *http.Request) {
 	api.RespondJSON(w, http.StatusOK, result)
 }
 
+// DryRun handles POST /api/v1/elda-meldungen/dry-run?sandbox=true. The
+// body is that of Create; nothing is stored.
+func (h *Handler) DryRun(w http.ResponseWriter, r *http.Request) {
+	var req elda.MeldungCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.RespondError(w, http.StatusBadRequest, "Ungültige Anfrage: "+err.Error())
+		return
+	}
+
+	sandbox, _ := strconv.ParseBool(r.URL.Query().Get("sandbox"))
+	report, err := h.service.DryRun(r.Context(), &req, sandbox)
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, report)
+}
+
 // Preview handles GET /api/v1/elda-meldungen/{id}/preview
 func (h *Handler) Preview(w http.ResponseWriter, r *http.Request) {
 	id, err := uuid.Parse(chi.URLParam(r, "id"))
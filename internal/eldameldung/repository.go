@@ -44,7 +44,7 @@ func (r *Repository) Create(ctx context.Context, m *elda.ELDAMeldung) error {
 			beschaeftigung, arbeitszeit, entgelt, adresse, bankverbindung,
 			abfertigung, urlaubsersatz, url_tage,
 			aenderung_art, aenderung_datum, original_meldung_id,
-			created_by, created_at, updated_at
+			created_by, created_at, updated_at, dienstnehmer_id
 		) VALUES (
 			$1, $2, $3, $4,
 			$5, $6, $7, $8, $9,
@@ -52,7 +52,7 @@ func (r *Repository) Create(ctx context.Context, m *elda.ELDAMeldung) error {
 			$13, $14, $15, $16, $17,
 			$18, $19, $20,
 			$21, $22, $23,
-			$24, $25, $26
+			$24, $25, $26, $27
 		)
 	`
 
@@ -70,7 +70,7 @@ func (r *Repository) Create(ctx context.Context, m *elda.ELDAMeldung) error {
 		beschaeftigungJSON, arbeitszeitJSON, entgeltJSON, adresseJSON, bankJSON,
 		m.Abfertigung, m.Urlaubsersatz, m.URLTage,
 		m.AenderungArt, m.AenderungDatum, m.OriginalMeldungID,
-		m.CreatedBy, m.CreatedAt, m.UpdatedAt,
+		m.CreatedBy, m.CreatedAt, m.UpdatedAt, m.DienstnehmerID,
 	)
 	if err != nil {
 		return fmt.Errorf("create meldung: %w", err)
@@ -90,14 +90,14 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*elda.ELDAMeldu
 			abfertigung, urlaubsersatz, url_tage,
 			aenderung_art, aenderung_datum, original_meldung_id,
 			protokollnummer, submitted_at, request_xml, response_xml,
-			error_code, error_message,
-			created_by, created_at, updated_at
+			error_code, error_message, processed_at, rueckmeldung_errors,
+			created_by, created_at, updated_at, dienstnehmer_id
 		FROM elda_meldungen
 		WHERE id = $1
 	`
 
 	m := &elda.ELDAMeldung{}
-	var beschaeftigungJSON, arbeitszeitJSON, entgeltJSON, adresseJSON, bankJSON []byte
+	var beschaeftigungJSON, arbeitszeitJSON, entgeltJSON, adresseJSON, bankJSON, rueckmeldungJSON []byte
 
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&m.ID, &m.ELDAAccountID, &m.Type, &m.Status,
@@ -107,8 +107,8 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*elda.ELDAMeldu
 		&m.Abfertigung, &m.Urlaubsersatz, &m.URLTage,
 		&m.AenderungArt, &m.AenderungDatum, &m.OriginalMeldungID,
 		&m.Protokollnummer, &m.SubmittedAt, &m.RequestXML, &m.ResponseXML,
-		&m.ErrorCode, &m.ErrorMessage,
-		&m.CreatedBy, &m.CreatedAt, &m.UpdatedAt,
+		&m.ErrorCode, &m.ErrorMessage, &m.ProcessedAt, &rueckmeldungJSON,
+		&m.CreatedBy, &m.CreatedAt, &m.UpdatedAt, &m.DienstnehmerID,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -138,6 +138,9 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*elda.ELDAMeldu
 		m.Bankverbindung = &elda.Bankverbindung{}
 		json.Unmarshal(bankJSON, m.Bankverbindung)
 	}
+	if len(rueckmeldungJSON) > 0 {
+		json.Unmarshal(rueckmeldungJSON, &m.RueckmeldungErrors)
+	}
 
 	return m, nil
 }
@@ -191,6 +194,7 @@ func (r *Repository) Update(ctx context.Context, m *elda.ELDAMeldung) error {
 // ListFilter contains filter options for listing meldungen
 type ListFilter struct {
 	ELDAAccountID *uuid.UUID
+	TenantID      *uuid.UUID // Meldungen of all ELDA accounts of the tenant
 	Type          *elda.MeldungType
 	Status        *elda.MeldungStatus
 	SVNummer      string
@@ -211,8 +215,8 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*elda.ELDAM
 			abfertigung, urlaubsersatz, url_tage,
 			aenderung_art, aenderung_datum, original_meldung_id,
 			protokollnummer, submitted_at,
-			error_code, error_message,
-			created_by, created_at, updated_at
+			error_code, error_message, processed_at, rueckmeldung_errors,
+			created_by, created_at, updated_at, dienstnehmer_id
 		FROM elda_meldungen
 		WHERE 1=1
 	`
@@ -225,6 +229,13 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*elda.ELDAM
 		argIndex++
 	}
 
+	if filter.TenantID != nil {
+		query += fmt.Sprintf(` AND elda_account_id IN (
+			SELECT ea.id FROM elda_accounts ea JOIN accounts a ON a.id = ea.account_id WHERE a.tenant_id = $%d)`, argIndex)
+		args = append(args, *filter.TenantID)
+		argIndex++
+	}
+
 	if filter.Type != nil {
 		query += fmt.Sprintf(" AND type = $%d", argIndex)
 		args = append(args, *filter.Type)
@@ -273,7 +284,7 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*elda.ELDAM
 	var results []*elda.ELDAMeldung
 	for rows.Next() {
 		m := &elda.ELDAMeldung{}
-		var beschaeftigungJSON, arbeitszeitJSON, entgeltJSON, adresseJSON, bankJSON []byte
+		var beschaeftigungJSON, arbeitszeitJSON, entgeltJSON, adresseJSON, bankJSON, rueckmeldungJSON []byte
 
 		err := rows.Scan(
 			&m.ID, &m.ELDAAccountID, &m.Type, &m.Status,
@@ -283,8 +294,8 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*elda.ELDAM
 			&m.Abfertigung, &m.Urlaubsersatz, &m.URLTage,
 			&m.AenderungArt, &m.AenderungDatum, &m.OriginalMeldungID,
 			&m.Protokollnummer, &m.SubmittedAt,
-			&m.ErrorCode, &m.ErrorMessage,
-			&m.CreatedBy, &m.CreatedAt, &m.UpdatedAt,
+			&m.ErrorCode, &m.ErrorMessage, &m.ProcessedAt, &rueckmeldungJSON,
+			&m.CreatedBy, &m.CreatedAt, &m.UpdatedAt, &m.DienstnehmerID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan meldung: %w", err)
@@ -311,6 +322,9 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*elda.ELDAM
 			m.Bankverbindung = &elda.Bankverbindung{}
 			json.Unmarshal(bankJSON, m.Bankverbindung)
 		}
+		if len(rueckmeldungJSON) > 0 {
+			json.Unmarshal(rueckmeldungJSON, &m.RueckmeldungErrors)
+		}
 
 		results = append(results, m)
 	}
@@ -330,6 +344,13 @@ func (r *Repository) Count(ctx context.Context, filter ListFilter) (int, error)
 		argIndex++
 	}
 
+	if filter.TenantID != nil {
+		query += fmt.Sprintf(` AND elda_account_id IN (
+			SELECT ea.id FROM elda_accounts ea JOIN accounts a ON a.id = ea.account_id WHERE a.tenant_id = $%d)`, argIndex)
+		args = append(args, *filter.TenantID)
+		argIndex++
+	}
+
 	if filter.Type != nil {
 		query += fmt.Sprintf(" AND type = $%d", argIndex)
 		args = append(args, *filter.Type)
@@ -400,3 +421,97 @@ func (r *Repository) GetHistoryBySVNummer(ctx context.Context, accountID uuid.UU
 	}
 	return r.List(ctx, filter)
 }
+
+// AwaitingRueckmeldung is a submitted meldung whose Protokoll is polled,
+// with the creator who is told about a rejection
+type AwaitingRueckmeldung struct {
+	ID              uuid.UUID
+	ELDAAccountID   uuid.UUID
+	TenantID        uuid.UUID
+	DienstgeberNr   string
+	Type            elda.MeldungType
+	Vorname         string
+	Nachname        string
+	Protokollnummer string
+	SubmittedAt     time.Time
+	CreatorName     string
+	CreatorEmail    string // empty without an active creator
+}
+
+// ListAwaitingRueckmeldung returns up to limit meldungen submitted since
+// submittedAfter that were not polled since polledBefore, the longest
+// unpolled first
+func (r *Repository) ListAwaitingRueckmeldung(ctx context.Context, polledBefore, submittedAfter time.Time, limit int) ([]*AwaitingRueckmeldung, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT m.id, m.elda_account_id, a.tenant_id, ea.dienstgeber_nummer,
+			m.type, COALESCE(m.vorname, ''), COALESCE(m.nachname, ''),
+			m.protokollnummer, m.submitted_at,
+			COALESCE(u.name, ''), COALESCE(u.email, '')
+		FROM elda_meldungen m
+		JOIN elda_accounts ea ON ea.id = m.elda_account_id
+		JOIN accounts a ON a.id = ea.account_id
+		LEFT JOIN users u ON u.id = m.created_by AND u.is_active
+		WHERE m.status = $1
+		  AND COALESCE(m.protokollnummer, '') <> ''
+		  AND m.submitted_at >= $3
+		  AND (m.last_polled_at IS NULL OR m.last_polled_at < $2)
+		ORDER BY m.last_polled_at NULLS FIRST, m.submitted_at
+		LIMIT $4
+	`, elda.MeldungStatusSubmitted, polledBefore, submittedAfter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list meldungen awaiting rueckmeldung: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*AwaitingRueckmeldung
+	for rows.Next() {
+		a := &AwaitingRueckmeldung{}
+		if err := rows.Scan(&a.ID, &a.ELDAAccountID, &a.TenantID, &a.DienstgeberNr,
+			&a.Type, &a.Vorname, &a.Nachname,
+			&a.Protokollnummer, &a.SubmittedAt,
+			&a.CreatorName, &a.CreatorEmail); err != nil {
+			return nil, fmt.Errorf("scan meldung awaiting rueckmeldung: %w", err)
+		}
+		results = append(results, a)
+	}
+	return results, rows.Err()
+}
+
+// MarkPolled records a poll that brought no final Rückmeldung
+func (r *Repository) MarkPolled(ctx context.Context, id uuid.UUID, now time.Time) error {
+	_, err := r.db.Exec(ctx, `UPDATE elda_meldungen SET last_polled_at = $2 WHERE id = $1`, id, now)
+	if err != nil {
+		return fmt.Errorf("mark meldung polled: %w", err)
+	}
+	return nil
+}
+
+// ApplyRueckmeldung moves a submitted meldung to the status of its
+// Protokoll and stores the Protokoll: processed_at, the errors, the
+// response XML and the error code and message. It returns false if the
+// meldung is no longer submitted, e.g. because another poll applied it.
+func (r *Repository) ApplyRueckmeldung(ctx context.Context, m *elda.ELDAMeldung, now time.Time) (bool, error) {
+	var errorsJSON []byte
+	if len(m.RueckmeldungErrors) > 0 {
+		errorsJSON, _ = json.Marshal(m.RueckmeldungErrors)
+	}
+
+	result, err := r.db.Exec(ctx, `
+		UPDATE elda_meldungen SET
+			status = $2,
+			processed_at = $3,
+			rueckmeldung_errors = $4,
+			response_xml = $5,
+			error_code = $6,
+			error_message = $7,
+			last_polled_at = $8,
+			updated_at = $8
+		WHERE id = $1 AND status = $9
+	`, m.ID, m.Status, m.ProcessedAt, errorsJSON, m.ResponseXML, m.ErrorCode, m.ErrorMessage,
+		now, elda.MeldungStatusSubmitted)
+	if err != nil {
+		return false, fmt.Errorf("apply rueckmeldung: %w", err)
+	}
+	m.UpdatedAt = now
+	return result.RowsAffected() > 0, nil
+}
@@ -0,0 +1,131 @@
+package eldameldung
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/rawpayload"
+	"austrian-business-infrastructure/internal/timezone"
+)
+
+// maxErrorCodeLength is the size of the error_code column
+const maxErrorCodeLength = 20
+
+// meldungTypeLabels name the meldung types in mails
+var meldungTypeLabels = map[elda.MeldungType]string{
+	elda.MeldungTypeAnmeldung: "Anmeldung",
+	elda.MeldungTypeAbmeldung: "Abmeldung",
+	elda.MeldungTypeAenderung: "Änderungsmeldung",
+	elda.MeldungTypeKorrektur: "Korrekturmeldung",
+}
+
+// RejectionNotifier mails the creator of a meldung ELDA rejected;
+// email.Service implements it
+type RejectionNotifier interface {
+	SendELDAMeldungRejected(ctx context.Context, to string, params email.ELDAMeldungRejectedParams) error
+}
+
+// SetNotifier enables the mail to the creator of a meldung ELDA rejected
+func (s *Service) SetNotifier(notifier RejectionNotifier) {
+	s.notifier = notifier
+}
+
+// DueRueckmeldungen returns up to limit submitted meldungen whose Protokoll
+// is due: not polled within interval and submitted within maxAge. Older
+// meldungen stay submitted and are no longer polled.
+func (s *Service) DueRueckmeldungen(ctx context.Context, interval, maxAge time.Duration, limit int) ([]*AwaitingRueckmeldung, error) {
+	now := time.Now()
+	return s.repo.ListAwaitingRueckmeldung(ctx, now.Add(-interval), now.Add(-maxAge), limit)
+}
+
+// PollRueckmeldung asks ELDA for the Protokoll of a submitted meldung and
+// returns the meldung's status. A final Rückmeldung moves the meldung to
+// accepted or rejected and stores the response XML and the errors; the
+// creator is mailed the error codes of a rejection. A meldung still in
+// processing, or whose query failed, is polled again after the interval.
+func (s *Service) PollRueckmeldung(ctx context.Context, a *AwaitingRueckmeldung) (elda.MeldungStatus, error) {
+	rec := rawpayload.NewRecorder()
+	rm, err := elda.QueryProtokoll(rawpayload.WithRecorder(ctx, rec), s.client, a.DienstgeberNr, a.Protokollnummer)
+	if err != nil || rm.Status == elda.MeldungStatusSubmitted {
+		if markErr := s.repo.MarkPolled(ctx, a.ID, time.Now()); markErr != nil && err == nil {
+			err = markErr
+		}
+		return elda.MeldungStatusSubmitted, err
+	}
+
+	m := &elda.ELDAMeldung{
+		ID:                 a.ID,
+		Status:             rm.Status,
+		ProcessedAt:        rm.ProcessedAt,
+		RueckmeldungErrors: rm.Errors,
+	}
+	if exchanges := rec.Exchanges(); len(exchanges) > 0 {
+		m.ResponseXML = string(exchanges[len(exchanges)-1].Response)
+	}
+	if rm.Status == elda.MeldungStatusRejected {
+		m.ErrorCode, m.ErrorMessage = summarizeErrors(rm.Errors)
+	}
+
+	s.payloads.Save(ctx, rawpayload.Reference{
+		TenantID:          &a.TenantID,
+		ELDAAccountID:     &a.ELDAAccountID,
+		Module:            rawpayload.ModuleELDA,
+		ReferenceType:     "elda_meldung",
+		ReferenceID:       &a.ID,
+		ProviderReference: a.Protokollnummer,
+		Outcome:           rawpayload.OutcomeOf(rm.Status == elda.MeldungStatusAccepted, true),
+	}, rec)
+
+	applied, err := s.repo.ApplyRueckmeldung(ctx, m, time.Now())
+	if err != nil || !applied {
+		return rm.Status, err
+	}
+
+	if rm.Status == elda.MeldungStatusRejected && s.notifier != nil && a.CreatorEmail != "" {
+		if err := s.notifier.SendELDAMeldungRejected(ctx, a.CreatorEmail, rejectionParams(a, rm)); err != nil {
+			return rm.Status, fmt.Errorf("failed to mail %s: %w", a.CreatorEmail, err)
+		}
+	}
+	return rm.Status, nil
+}
+
+// summarizeErrors returns the first error code and all errors as the error
+// message of a rejected meldung
+func summarizeErrors(errs []elda.ProtokollFehler) (string, string) {
+	if len(errs) == 0 {
+		return "", "Von ELDA abgelehnt"
+	}
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		messages = append(messages, e.Code+": "+e.Text)
+	}
+	code := errs[0].Code
+	if len(code) > maxErrorCodeLength {
+		code = code[:maxErrorCodeLength]
+	}
+	return code, strings.Join(messages, "; ")
+}
+
+// rejectionParams builds the mail about a rejected meldung
+func rejectionParams(a *AwaitingRueckmeldung, rm *elda.Rueckmeldung) email.ELDAMeldungRejectedParams {
+	tenantID := a.TenantID
+	params := email.ELDAMeldungRejectedParams{
+		TenantID:        &tenantID,
+		RecipientName:   a.CreatorName,
+		MeldungType:     meldungTypeLabels[a.Type],
+		Dienstnehmer:    strings.TrimSpace(a.Vorname + " " + a.Nachname),
+		Protokollnummer: a.Protokollnummer,
+		SubmittedAt:     a.SubmittedAt.In(timezone.Vienna).Format("02.01.2006"),
+	}
+	if params.MeldungType == "" {
+		params.MeldungType = "Meldung"
+	}
+	for _, e := range rm.Errors {
+		params.Errors = append(params.Errors, email.ELDAProtokollError{Code: e.Code, Text: e.Text, Field: e.Field})
+	}
+	return params
+}
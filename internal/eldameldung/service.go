@@ -11,17 +11,22 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/rawpayload"
 )
 
 // Service handles ELDA meldung business logic
 type Service struct {
 	repo      *Repository
-	client    *elda.Client
+	client    elda.Caller
 	validator *Validator
+	payloads  *rawpayload.Service
+	sandbox   elda.Caller
+	notifier  RejectionNotifier
+	registry  DienstnehmerRegistry
 }
 
 // NewService creates a new ELDA meldung service
-func NewService(pool *pgxpool.Pool, eldaClient *elda.Client) *Service {
+func NewService(pool *pgxpool.Pool, eldaClient elda.Caller) *Service {
 	return &Service{
 		repo:      NewRepository(pool),
 		client:    eldaClient,
@@ -29,8 +34,38 @@ func NewService(pool *pgxpool.Pool, eldaClient *elda.Client) *Service {
 	}
 }
 
+// SetRawPayloads enables retention of the raw ELDA exchanges of submissions
+func (s *Service) SetRawPayloads(p *rawpayload.Service) {
+	s.payloads = p
+}
+
+// DienstnehmerRegistry prefills the employee data of a meldung created with
+// a dienstnehmer_id; dienstnehmer.Service implements it. Problems with the
+// Dienstnehmer are returned as *ValidationError.
+type DienstnehmerRegistry interface {
+	PrefillMeldung(ctx context.Context, req *elda.MeldungCreateRequest) error
+}
+
+// SetDienstnehmerRegistry enables creating meldungen for a Dienstnehmer of
+// the registry
+func (s *Service) SetDienstnehmerRegistry(registry DienstnehmerRegistry) {
+	s.registry = registry
+}
+
 // Create creates a new ELDA meldung
 func (s *Service) Create(ctx context.Context, req *elda.MeldungCreateRequest) (*elda.ELDAMeldung, error) {
+	if req.DienstnehmerID != nil {
+		if s.registry == nil {
+			return nil, &ValidationError{
+				Message: "Validierungsfehler",
+				Errors:  []string{"dienstnehmer_id: Dienstnehmer-Verzeichnis nicht verfügbar"},
+			}
+		}
+		if err := s.registry.PrefillMeldung(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
 	// Validate the request
 	validation := s.validator.ValidateCreateRequest(req)
 	if !validation.Valid {
@@ -40,12 +75,24 @@ func (s *Service) Create(ctx context.Context, req *elda.MeldungCreateRequest) (*
 		}
 	}
 
-	// Create the meldung entity
+	meldung := newMeldung(req)
+
+	// Save to database
+	if err := s.repo.Create(ctx, meldung); err != nil {
+		return nil, fmt.Errorf("failed to create meldung: %w", err)
+	}
+
+	return meldung, nil
+}
+
+// newMeldung builds a draft meldung entity from a create request
+func newMeldung(req *elda.MeldungCreateRequest) *elda.ELDAMeldung {
 	meldung := &elda.ELDAMeldung{
 		ID:            uuid.New(),
 		ELDAAccountID: req.ELDAAccountID,
 		Type:          req.Type,
 		Status:        elda.MeldungStatusDraft,
+		DienstnehmerID: req.DienstnehmerID,
 		SVNummer:      req.SVNummer,
 		Vorname:       req.Vorname,
 		Nachname:      req.Nachname,
@@ -87,12 +134,7 @@ func (s *Service) Create(ctx context.Context, req *elda.MeldungCreateRequest) (*
 		}
 	}
 
-	// Save to database
-	if err := s.repo.Create(ctx, meldung); err != nil {
-		return nil, fmt.Errorf("failed to create meldung: %w", err)
-	}
-
-	return meldung, nil
+	return meldung
 }
 
 // ValidationError represents a validation error
@@ -185,7 +227,8 @@ func (s *Service) SubmitWithCredentials(ctx context.Context, id uuid.UUID, creds
 	}
 
 	// Submit to ELDA using the extended submission
-	resp, err := s.client.SubmitExtendedMeldung(ctx, creds, meldung)
+	rec := rawpayload.NewRecorder()
+	resp, err := elda.SubmitExtendedMeldung(rawpayload.WithRecorder(ctx, rec), s.client, creds, meldung)
 
 	result := &SubmitResult{
 		SubmittedAt: time.Now(),
@@ -219,6 +262,15 @@ func (s *Service) SubmitWithCredentials(ctx context.Context, id uuid.UUID, creds
 		result.Success = true
 	}
 
+	s.payloads.Save(ctx, rawpayload.Reference{
+		ELDAAccountID:     &meldung.ELDAAccountID,
+		Module:            rawpayload.ModuleELDA,
+		ReferenceType:     "elda_meldung",
+		ReferenceID:       &meldung.ID,
+		ProviderReference: meldung.Protokollnummer,
+		Outcome:           rawpayload.OutcomeOf(result.Success, resp != nil),
+	}, rec)
+
 	// Update database
 	if updateErr := s.repo.Update(ctx, meldung); updateErr != nil {
 		return result, fmt.Errorf("submitted but failed to update record: %w", updateErr)
@@ -271,6 +323,63 @@ type PreviewResult struct {
 	GeneratedAt time.Time       `json:"generated_at"`
 }
 
+// Rendition contains a meldung re-rendered with the current builders
+type Rendition struct {
+	Meldung *elda.ELDAMeldung
+	XML     []byte // As stored in request_xml
+	WireXML []byte // As sent in the SOAP body
+}
+
+// Render re-renders the XML of a meldung with the current builders without
+// changing the stored record
+func (s *Service) Render(ctx context.Context, id uuid.UUID) (*Rendition, error) {
+	meldung, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	xmlDoc, err := s.buildXML(meldung)
+	if err != nil {
+		return nil, err
+	}
+
+	wireDoc, _, err := elda.BuildMeldungDocument(&elda.ELDACredentials{}, meldung)
+	if err != nil {
+		return nil, err
+	}
+	wireXML, err := xml.Marshal(wireDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rendition{Meldung: meldung, XML: xmlDoc, WireXML: wireXML}, nil
+}
+
+// SubmitSandbox submits a meldung through the given client, usually one for
+// the ELDA test endpoint, without changing the stored record
+func (s *Service) SubmitSandbox(ctx context.Context, id uuid.UUID, client elda.Caller) (*SubmitResult, error) {
+	meldung, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := elda.SubmitExtendedMeldung(ctx, client, &elda.ELDACredentials{}, meldung)
+	result := &SubmitResult{
+		SubmittedAt: time.Now(),
+	}
+	if err != nil {
+		result.ErrorMessage = err.Error()
+		return result, nil
+	}
+
+	result.Success = resp.Erfolg
+	result.Protokollnummer = resp.Protokollnummer
+	result.ErrorCode = resp.ErrorCode
+	result.ErrorMessage = resp.ErrorMessage
+	result.Warnings = resp.Warnungen
+	return result, nil
+}
+
 // Delete deletes a meldung (only if draft)
 func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
 	meldung, err := s.repo.GetByID(ctx, id)
@@ -3,28 +3,64 @@ package email
 import (
 	"context"
 	"fmt"
-	"net/smtp"
+	"net/url"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/mail"
 )
 
 // Service provides email sending functionality
 type Service interface {
 	SendInvitation(ctx context.Context, to, inviterName, tenantName, token, appURL string) error
-	SendPasswordReset(ctx context.Context, to, token, appURL string) error
+	SendPasswordReset(ctx context.Context, to string, params PasswordResetParams) error
 	SendEmailVerification(ctx context.Context, to, token, appURL string) error
 	// Signature-related emails
 	SendSignatureRequest(ctx context.Context, to string, params SignatureRequestParams) error
 	SendSignatureReminder(ctx context.Context, to string, params SignatureReminderParams) error
 	SendSignatureCompleted(ctx context.Context, to string, params SignatureCompletedParams) error
 	SendSignatureExpired(ctx context.Context, to string, params SignatureExpiredParams) error
+	// Break-glass notifications to tenant admins
+	SendBreakGlassStarted(ctx context.Context, to string, params BreakGlassParams) error
+	SendBreakGlassEnded(ctx context.Context, to string, params BreakGlassParams) error
+	// Signature billing notifications to tenant admins
+	SendSignatureLowBalance(ctx context.Context, to string, params SignatureLowBalanceParams) error
+	// UID batch verification results to the user who uploaded the batch
+	SendUIDBatchCompleted(ctx context.Context, to string, params UIDBatchCompletedParams) error
+	// Business partner UIDs found invalid on re-validation, to tenant admins
+	SendPartnerUIDInvalid(ctx context.Context, to string, params PartnerUIDInvalidParams) error
+	// Changes of companies on the Firmenbuch watchlist, to tenant admins
+	SendFirmenbuchChange(ctx context.Context, to string, params FirmenbuchChangeParams) error
+	// Status changes of watched Förderungen, to applicants and monitoring tenant admins
+	SendFoerderungStatusChanged(ctx context.Context, to string, params FoerderungStatusParams) error
+	// ELDA meldungen rejected in their Protokoll, to the user who created them
+	SendELDAMeldungRejected(ctx context.Context, to string, params ELDAMeldungRejectedParams) error
+	// Kommunalsteuer and Dienstgeberabgabe returns due soon, to tenant admins
+	SendKommunalsteuerFrist(ctx context.Context, to string, params KommunalsteuerFristParams) error
+	// Kammerumlage (KU1) of a quarter due soon, to tenant admins
+	SendKammerumlageFrist(ctx context.Context, to string, params KammerumlageFristParams) error
+	// Workflows past the SLA of their state, to the assignee and tenant admins
+	SendWorkflowEscalation(ctx context.Context, to string, params WorkflowEscalationParams) error
+}
+
+// PasswordResetParams contains parameters for password reset emails
+type PasswordResetParams struct {
+	Name         string
+	ResetURL     string
+	ValidMinutes int
+	RequestedAt  string
+	IPAddress    string
 }
 
 // SignatureRequestParams contains parameters for signature request emails
 type SignatureRequestParams struct {
+	TenantID        *uuid.UUID // brands the mail; nil sends it in the platform look
 	SignerName      string
 	RequesterName   string
 	CompanyName     string
 	DocumentTitle   string
 	SigningURL      string
+	StatusURL       string
 	ExpiresAt       string
 	Message         string
 	SignerPosition  int
@@ -33,9 +69,11 @@ type SignatureRequestParams struct {
 
 // SignatureReminderParams contains parameters for signature reminder emails
 type SignatureReminderParams struct {
+	TenantID      *uuid.UUID
 	SignerName    string
 	DocumentTitle string
 	SigningURL    string
+	StatusURL     string
 	ExpiresAt     string
 	DaysLeft      int
 	ReminderCount int
@@ -43,6 +81,7 @@ type SignatureReminderParams struct {
 
 // SignatureCompletedParams contains parameters for signature completion emails
 type SignatureCompletedParams struct {
+	TenantID      *uuid.UUID
 	RequesterName string
 	DocumentTitle string
 	SignerName    string
@@ -53,245 +92,272 @@ type SignatureCompletedParams struct {
 
 // SignatureExpiredParams contains parameters for signature expiry emails
 type SignatureExpiredParams struct {
+	TenantID      *uuid.UUID
 	RecipientName string
 	DocumentTitle string
 	ExpiredAt     string
 }
 
-// SMTPConfig holds SMTP configuration
-type SMTPConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	From     string
+// BreakGlassParams contains parameters for break-glass notifications
+type BreakGlassParams struct {
+	RecipientName string
+	TenantName    string
+	AdminName     string
+	Reason        string
+	GrantedAt     string
+	ExpiresAt     string
+	EndedAt       string
+	Revoked       bool
+	RequestCount  int
 }
 
-// SMTPService implements email sending via SMTP
-type SMTPService struct {
-	config *SMTPConfig
+// SignatureLowBalanceParams contains parameters for low signature credit
+// notifications
+type SignatureLowBalanceParams struct {
+	RecipientName string
+	TenantName    string
+	Remaining     int
+	Threshold     int
+	Postpaid      bool
+	BillingURL    string
 }
 
-// NewSMTPService creates a new SMTP email service
-func NewSMTPService(config *SMTPConfig) *SMTPService {
-	return &SMTPService{config: config}
+// UIDBatchCompletedParams contains parameters for the mail of a finished
+// UID batch verification
+type UIDBatchCompletedParams struct {
+	TenantID      *uuid.UUID // brands the mail
+	RecipientName string
+	FileName      string
+	Level         int
+	Total         int
+	Valid         int
+	Invalid       int
+	Failed        int
+	Error         string // why the batch stopped, if it failed
+	ResultsURL    string
 }
 
-// SendInvitation sends an invitation email
-func (s *SMTPService) SendInvitation(ctx context.Context, to, inviterName, tenantName, token, appURL string) error {
-	subject := fmt.Sprintf("You've been invited to join %s", tenantName)
-	inviteURL := fmt.Sprintf("%s/invitations/accept?token=%s", appURL, token)
-
-	body := fmt.Sprintf(`Hello,
-
-%s has invited you to join %s on Austrian Business Platform.
-
-Click the link below to accept the invitation and create your account:
+// PartnerUIDInvalidParams contains parameters for the mail of business
+// partners whose UID was found invalid
+type PartnerUIDInvalidParams struct {
+	TenantID      *uuid.UUID // brands the mail
+	RecipientName string
+	Partners      []PartnerUID
+	PartnersURL   string
+}
 
-%s
+// PartnerUID is a business partner with its UID number
+type PartnerUID struct {
+	Name string
+	UID  string
+}
 
-This invitation will expire in 7 days.
+// FirmenbuchChangeParams contains parameters for the mail of changes of a
+// company on the Firmenbuch watchlist
+type FirmenbuchChangeParams struct {
+	TenantID      *uuid.UUID // brands the mail
+	RecipientName string
+	CompanyName   string
+	FN            string
+	Changes       []string // summaries of the changes
+	HistoryURL    string
+}
 
-If you did not expect this invitation, you can safely ignore this email.
+// FoerderungStatusParams contains parameters for the mail of status changes
+// of watched Förderungen
+type FoerderungStatusParams struct {
+	TenantID        *uuid.UUID // brands the mail
+	RecipientName   string
+	Changes         []FoerderungStatusChange
+	FoerderungenURL string
+}
 
-Best regards,
-Austrian Business Platform Team
-`, inviterName, tenantName, inviteURL)
+// FoerderungStatusChange is a status change of a Förderung, with German
+// labels
+type FoerderungStatusChange struct {
+	Name      string
+	OldStatus string
+	NewStatus string
+	Reason    string
+	URL       string
+}
 
-	return s.send(to, subject, body)
+// ELDAMeldungRejectedParams contains parameters for the mail of an ELDA
+// meldung rejected in its Protokoll
+type ELDAMeldungRejectedParams struct {
+	TenantID        *uuid.UUID // brands the mail
+	RecipientName   string
+	MeldungType     string // e.g. Anmeldung
+	Dienstnehmer    string
+	Protokollnummer string
+	SubmittedAt     string // formatted date
+	Errors          []ELDAProtokollError
 }
 
-// SendPasswordReset sends a password reset email
-func (s *SMTPService) SendPasswordReset(ctx context.Context, to, token, appURL string) error {
-	subject := "Reset your password"
-	resetURL := fmt.Sprintf("%s/auth/reset-password?token=%s", appURL, token)
+// ELDAProtokollError is an error of an ELDA Protokoll
+type ELDAProtokollError struct {
+	Code  string
+	Text  string
+	Field string
+}
 
-	body := fmt.Sprintf(`Hello,
+// KommunalsteuerFristParams contains parameters for the reminder of
+// Kommunalsteuer and Dienstgeberabgabe returns not submitted yet
+type KommunalsteuerFristParams struct {
+	TenantID      *uuid.UUID // brands the mail
+	RecipientName string
+	Year          int
+	Faellig       string // formatted date
+	DaysLeft      int
+	Dienstgeber   []string // names of the accounts
+	URL           string
+}
 
-A password reset was requested for your account.
+// KammerumlageFristParams contains parameters for the reminder of KU1 not
+// submitted yet
+type KammerumlageFristParams struct {
+	TenantID      *uuid.UUID // brands the mail
+	RecipientName string
+	Periode       string // e.g. Q1/2025
+	Faellig       string // formatted date
+	DaysLeft      int
+	Umlagen       []KammerumlageBetrag
+	URL           string
+}
 
-Click the link below to reset your password:
+// KammerumlageBetrag is the KU1 of a company
+type KammerumlageBetrag struct {
+	Name   string
+	Umlage string // formatted amount
+}
 
-%s
+// WorkflowEscalationParams contains parameters for the escalation of a
+// workflow past the SLA of its state
+type WorkflowEscalationParams struct {
+	TenantID      *uuid.UUID // brands the mail
+	RecipientName string
+	Workflow      string // name of the definition
+	Title         string
+	State         string
+	Since         string // formatted time the state was entered
+	Due           string // formatted end of the SLA
+	Assignee      string
+	URL           string
+}
 
-This link will expire in 1 hour.
+// MailService implements Service with the templates of the mail subsystem,
+// so every email passes its suppression list
+type MailService struct {
+	mailer *mail.Service
+}
 
-If you did not request a password reset, you can safely ignore this email.
+// NewMailService creates an email service sending through the mail subsystem
+func NewMailService(mailer *mail.Service) *MailService {
+	return &MailService{mailer: mailer}
+}
 
-Best regards,
-Austrian Business Platform Team
-`, resetURL)
+// SendInvitation sends an invitation email
+func (s *MailService) SendInvitation(ctx context.Context, to, inviterName, tenantName, token, appURL string) error {
+	return s.mailer.SendTemplate(ctx, nil, to, mail.TemplateInvitation, map[string]string{
+		"InviterName": inviterName,
+		"TenantName":  tenantName,
+		"URL":         fmt.Sprintf("%s/invitations/accept?token=%s", appURL, url.QueryEscape(token)),
+	})
+}
 
-	return s.send(to, subject, body)
+// SendPasswordReset sends a password reset email
+func (s *MailService) SendPasswordReset(ctx context.Context, to string, params PasswordResetParams) error {
+	return s.mailer.SendTemplate(ctx, nil, to, mail.TemplatePasswordReset, params)
 }
 
 // SendEmailVerification sends an email verification email
-func (s *SMTPService) SendEmailVerification(ctx context.Context, to, token, appURL string) error {
-	subject := "Verify your email address"
-	verifyURL := fmt.Sprintf("%s/auth/verify-email?token=%s", appURL, token)
-
-	body := fmt.Sprintf(`Hello,
-
-Please verify your email address by clicking the link below:
-
-%s
-
-If you did not create an account, you can safely ignore this email.
-
-Best regards,
-Austrian Business Platform Team
-`, verifyURL)
-
-	return s.send(to, subject, body)
+func (s *MailService) SendEmailVerification(ctx context.Context, to, token, appURL string) error {
+	return s.mailer.SendTemplate(ctx, nil, to, mail.TemplateEmailVerification, map[string]string{
+		"URL": fmt.Sprintf("%s/auth/verify-email?token=%s", appURL, url.QueryEscape(token)),
+	})
 }
 
 // SendSignatureRequest sends a signature request email
-func (s *SMTPService) SendSignatureRequest(ctx context.Context, to string, params SignatureRequestParams) error {
-	subject := fmt.Sprintf("Signaturanfrage: %s", params.DocumentTitle)
-
-	positionInfo := ""
-	if params.TotalSigners > 1 {
-		positionInfo = fmt.Sprintf("\n\nSie sind Unterzeichner %d von %d.", params.SignerPosition, params.TotalSigners)
-	}
-
-	messageSection := ""
-	if params.Message != "" {
-		messageSection = fmt.Sprintf("\n\nNachricht von %s:\n%s", params.RequesterName, params.Message)
-	}
-
-	body := fmt.Sprintf(`Guten Tag %s,
-
-%s von %s bittet Sie, das folgende Dokument digital zu signieren:
-
-Dokument: %s%s%s
-
-Bitte klicken Sie auf den folgenden Link, um das Dokument zu signieren:
-
-%s
-
-Die Signatur erfolgt mit ID Austria (qualifizierte elektronische Signatur).
-
-Dieser Link ist gueltig bis: %s
-
-Bei Fragen wenden Sie sich bitte an %s.
-
-Mit freundlichen Gruessen,
-Austrian Business Platform
-`, params.SignerName, params.RequesterName, params.CompanyName, params.DocumentTitle, positionInfo, messageSection, params.SigningURL, params.ExpiresAt, params.RequesterName)
-
-	return s.send(to, subject, body)
+func (s *MailService) SendSignatureRequest(ctx context.Context, to string, params SignatureRequestParams) error {
+	return s.mailer.SendTemplate(ctx, params.TenantID, to, mail.TemplateSignatureRequest, params)
 }
 
 // SendSignatureReminder sends a signature reminder email
-func (s *SMTPService) SendSignatureReminder(ctx context.Context, to string, params SignatureReminderParams) error {
-	subject := fmt.Sprintf("Erinnerung: Signatur ausstehend - %s", params.DocumentTitle)
-
-	urgencyNote := ""
-	if params.DaysLeft <= 3 {
-		urgencyNote = "\n\n*** DRINGEND: Nur noch wenige Tage Zeit! ***\n"
-	}
-
-	body := fmt.Sprintf(`Guten Tag %s,
-
-dies ist eine Erinnerung, dass Ihre Signatur fuer das folgende Dokument noch aussteht:
-
-Dokument: %s%s
-
-Bitte signieren Sie das Dokument bis zum %s (%d Tage verbleibend):
-
-%s
-
-Mit freundlichen Gruessen,
-Austrian Business Platform
-`, params.SignerName, params.DocumentTitle, urgencyNote, params.ExpiresAt, params.DaysLeft, params.SigningURL)
-
-	return s.send(to, subject, body)
+func (s *MailService) SendSignatureReminder(ctx context.Context, to string, params SignatureReminderParams) error {
+	return s.mailer.SendTemplate(ctx, params.TenantID, to, mail.TemplateSignatureReminder, params)
 }
 
 // SendSignatureCompleted sends a signature completion notification
-func (s *SMTPService) SendSignatureCompleted(ctx context.Context, to string, params SignatureCompletedParams) error {
-	var subject, body string
-
-	if params.AllSigned {
-		subject = fmt.Sprintf("Signatur abgeschlossen: %s", params.DocumentTitle)
-		body = fmt.Sprintf(`Guten Tag %s,
-
-alle Signaturen fuer das folgende Dokument wurden abgeschlossen:
-
-Dokument: %s
-Letzte Signatur von: %s
-Zeitpunkt: %s
-
-Sie koennen das signierte Dokument hier herunterladen:
-
-%s
-
-Mit freundlichen Gruessen,
-Austrian Business Platform
-`, params.RequesterName, params.DocumentTitle, params.SignerName, params.SignedAt, params.DownloadURL)
-	} else {
-		subject = fmt.Sprintf("Signatur erhalten: %s", params.DocumentTitle)
-		body = fmt.Sprintf(`Guten Tag %s,
-
-eine Signatur wurde fuer das folgende Dokument hinzugefuegt:
-
-Dokument: %s
-Signiert von: %s
-Zeitpunkt: %s
-
-Das Dokument wird nun an den naechsten Unterzeichner weitergeleitet.
-
-Mit freundlichen Gruessen,
-Austrian Business Platform
-`, params.RequesterName, params.DocumentTitle, params.SignerName, params.SignedAt)
-	}
-
-	return s.send(to, subject, body)
+func (s *MailService) SendSignatureCompleted(ctx context.Context, to string, params SignatureCompletedParams) error {
+	return s.mailer.SendTemplate(ctx, params.TenantID, to, mail.TemplateSignatureCompleted, params)
 }
 
 // SendSignatureExpired sends a signature expiry notification
-func (s *SMTPService) SendSignatureExpired(ctx context.Context, to string, params SignatureExpiredParams) error {
-	subject := fmt.Sprintf("Signaturanfrage abgelaufen: %s", params.DocumentTitle)
+func (s *MailService) SendSignatureExpired(ctx context.Context, to string, params SignatureExpiredParams) error {
+	return s.mailer.SendTemplate(ctx, params.TenantID, to, mail.TemplateSignatureExpired, params)
+}
 
-	body := fmt.Sprintf(`Guten Tag %s,
+// SendBreakGlassStarted tells a tenant admin that break-glass access began.
+// It is a platform mail, so it never carries the tenant's branding.
+func (s *MailService) SendBreakGlassStarted(ctx context.Context, to string, params BreakGlassParams) error {
+	return s.mailer.SendTemplate(ctx, nil, to, mail.TemplateBreakGlassStarted, params)
+}
 
-die folgende Signaturanfrage ist abgelaufen:
+// SendBreakGlassEnded tells a tenant admin that break-glass access ended
+func (s *MailService) SendBreakGlassEnded(ctx context.Context, to string, params BreakGlassParams) error {
+	return s.mailer.SendTemplate(ctx, nil, to, mail.TemplateBreakGlassEnded, params)
+}
 
-Dokument: %s
-Abgelaufen am: %s
+// SendSignatureLowBalance tells a tenant admin that few signature credits
+// are left
+func (s *MailService) SendSignatureLowBalance(ctx context.Context, to string, params SignatureLowBalanceParams) error {
+	return s.mailer.SendTemplate(ctx, nil, to, mail.TemplateSignatureLowBalance, params)
+}
 
-Die ausstehenden Signaturen koennen nicht mehr abgeschlossen werden.
-Bitte erstellen Sie bei Bedarf eine neue Signaturanfrage.
+// SendUIDBatchCompleted tells a user that the UID batch they uploaded has
+// been verified
+func (s *MailService) SendUIDBatchCompleted(ctx context.Context, to string, params UIDBatchCompletedParams) error {
+	return s.mailer.SendTemplate(ctx, params.TenantID, to, mail.TemplateUIDBatchCompleted, params)
+}
 
-Mit freundlichen Gruessen,
-Austrian Business Platform
-`, params.RecipientName, params.DocumentTitle, params.ExpiredAt)
+// SendPartnerUIDInvalid tells a tenant admin that the UIDs of business
+// partners were found invalid
+func (s *MailService) SendPartnerUIDInvalid(ctx context.Context, to string, params PartnerUIDInvalidParams) error {
+	return s.mailer.SendTemplate(ctx, params.TenantID, to, mail.TemplatePartnerUIDInvalid, params)
+}
 
-	return s.send(to, subject, body)
+// SendFirmenbuchChange tells a tenant admin that a watched company changed
+// in the Firmenbuch
+func (s *MailService) SendFirmenbuchChange(ctx context.Context, to string, params FirmenbuchChangeParams) error {
+	return s.mailer.SendTemplate(ctx, params.TenantID, to, mail.TemplateFirmenbuchChange, params)
 }
 
-func (s *SMTPService) send(to, subject, body string) error {
-	if s.config.Host == "" {
-		// SMTP not configured - log and skip
-		return nil
-	}
+// SendFoerderungStatusChanged tells a watcher that the status of Förderungen
+// changed
+func (s *MailService) SendFoerderungStatusChanged(ctx context.Context, to string, params FoerderungStatusParams) error {
+	return s.mailer.SendTemplate(ctx, params.TenantID, to, mail.TemplateFoerderungStatus, params)
+}
 
-	msg := fmt.Sprintf("From: %s\r\n"+
-		"To: %s\r\n"+
-		"Subject: %s\r\n"+
-		"MIME-Version: 1.0\r\n"+
-		"Content-Type: text/plain; charset=utf-8\r\n"+
-		"\r\n"+
-		"%s", s.config.From, to, subject, body)
+// SendELDAMeldungRejected tells a user that ELDA rejected a meldung they
+// created
+func (s *MailService) SendELDAMeldungRejected(ctx context.Context, to string, params ELDAMeldungRejectedParams) error {
+	return s.mailer.SendTemplate(ctx, params.TenantID, to, mail.TemplateELDAMeldungRejected, params)
+}
 
-	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
+// SendKommunalsteuerFrist reminds a tenant admin of Kommunalsteuer and
+// Dienstgeberabgabe returns due soon
+func (s *MailService) SendKommunalsteuerFrist(ctx context.Context, to string, params KommunalsteuerFristParams) error {
+	return s.mailer.SendTemplate(ctx, params.TenantID, to, mail.TemplateKommunalsteuerFrist, params)
+}
 
-	var auth smtp.Auth
-	if s.config.User != "" && s.config.Password != "" {
-		auth = smtp.PlainAuth("", s.config.User, s.config.Password, s.config.Host)
-	}
+// SendKammerumlageFrist reminds a tenant admin of KU1 due soon
+func (s *MailService) SendKammerumlageFrist(ctx context.Context, to string, params KammerumlageFristParams) error {
+	return s.mailer.SendTemplate(ctx, params.TenantID, to, mail.TemplateKammerumlageFrist, params)
+}
 
-	return smtp.SendMail(addr, auth, s.config.From, []string{to}, []byte(msg))
+// SendWorkflowEscalation tells a user of a workflow past its SLA
+func (s *MailService) SendWorkflowEscalation(ctx context.Context, to string, params WorkflowEscalationParams) error {
+	return s.mailer.SendTemplate(ctx, params.TenantID, to, mail.TemplateWorkflowEscalation, params)
 }
 
 // NoopService is a no-op email service for testing/development
@@ -308,7 +374,7 @@ func (s *NoopService) SendInvitation(ctx context.Context, to, inviterName, tenan
 }
 
 // SendPasswordReset does nothing (no-op)
-func (s *NoopService) SendPasswordReset(ctx context.Context, to, token, appURL string) error {
+func (s *NoopService) SendPasswordReset(ctx context.Context, to string, params PasswordResetParams) error {
 	return nil
 }
 
@@ -336,3 +402,58 @@ func (s *NoopService) SendSignatureCompleted(ctx context.Context, to string, par
 func (s *NoopService) SendSignatureExpired(ctx context.Context, to string, params SignatureExpiredParams) error {
 	return nil
 }
+
+// SendBreakGlassStarted does nothing (no-op)
+func (s *NoopService) SendBreakGlassStarted(ctx context.Context, to string, params BreakGlassParams) error {
+	return nil
+}
+
+// SendBreakGlassEnded does nothing (no-op)
+func (s *NoopService) SendBreakGlassEnded(ctx context.Context, to string, params BreakGlassParams) error {
+	return nil
+}
+
+// SendSignatureLowBalance does nothing (no-op)
+func (s *NoopService) SendSignatureLowBalance(ctx context.Context, to string, params SignatureLowBalanceParams) error {
+	return nil
+}
+
+// SendUIDBatchCompleted does nothing (no-op)
+func (s *NoopService) SendUIDBatchCompleted(ctx context.Context, to string, params UIDBatchCompletedParams) error {
+	return nil
+}
+
+// SendPartnerUIDInvalid does nothing (no-op)
+func (s *NoopService) SendPartnerUIDInvalid(ctx context.Context, to string, params PartnerUIDInvalidParams) error {
+	return nil
+}
+
+// SendFirmenbuchChange does nothing (no-op)
+func (s *NoopService) SendFirmenbuchChange(ctx context.Context, to string, params FirmenbuchChangeParams) error {
+	return nil
+}
+
+// SendFoerderungStatusChanged does nothing (no-op)
+func (s *NoopService) SendFoerderungStatusChanged(ctx context.Context, to string, params FoerderungStatusParams) error {
+	return nil
+}
+
+// SendELDAMeldungRejected does nothing (no-op)
+func (s *NoopService) SendELDAMeldungRejected(ctx context.Context, to string, params ELDAMeldungRejectedParams) error {
+	return nil
+}
+
+// SendKommunalsteuerFrist does nothing (no-op)
+func (s *NoopService) SendKommunalsteuerFrist(ctx context.Context, to string, params KommunalsteuerFristParams) error {
+	return nil
+}
+
+// SendKammerumlageFrist does nothing (no-op)
+func (s *NoopService) SendKammerumlageFrist(ctx context.Context, to string, params KammerumlageFristParams) error {
+	return nil
+}
+
+// SendWorkflowEscalation does nothing (no-op)
+func (s *NoopService) SendWorkflowEscalation(ctx context.Context, to string, params WorkflowEscalationParams) error {
+	return nil
+}
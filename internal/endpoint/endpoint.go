@@ -0,0 +1,209 @@
+// Package endpoint selects the endpoints of external integrations such as
+// FinanzOnline and ELDA. Each integration has configured endpoint sets (for
+// example the current and a migrated endpoint); scheduled or manual
+// switchovers pick the active set, and during announced maintenance windows
+// calls fail fast so that callers retry after the window.
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Integrations
+const (
+	FinanzOnline = "finanzonline"
+	ELDA         = "elda"
+)
+
+// Sources of switchovers and windows
+const (
+	SourceConfig = "config"
+	SourceAPI    = "api"
+)
+
+var (
+	ErrMaintenanceWindow  = errors.New("integration is in a maintenance window")
+	ErrUnknownIntegration = errors.New("unknown integration")
+	ErrUnknownSet         = errors.New("unknown endpoint set")
+	ErrNotFound           = errors.New("switchover or maintenance window not found")
+)
+
+// Set is one named endpoint of an integration
+type Set struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// Integration is an external service with its endpoint sets. Default is
+// active until the first switchover is due.
+type Integration struct {
+	Name    string
+	Sets    []Set
+	Default string
+}
+
+// Set returns the endpoint set with the given name
+func (i *Integration) Set(name string) (Set, bool) {
+	for _, s := range i.Sets {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Set{}, false
+}
+
+// Switchover makes an endpoint set active from At on
+type Switchover struct {
+	ID          *uuid.UUID `json:"id,omitempty"`
+	Integration string     `json:"integration"`
+	Set         string     `json:"set"`
+	At          time.Time  `json:"at"`
+	Note        string     `json:"note,omitempty"`
+	Source      string     `json:"source"`
+}
+
+// Window is an announced maintenance window of an integration
+type Window struct {
+	ID          *uuid.UUID `json:"id,omitempty"`
+	Integration string     `json:"integration"`
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      time.Time  `json:"ends_at"`
+	Reason      string     `json:"reason,omitempty"`
+	Source      string     `json:"source"`
+}
+
+// Contains reports whether t falls into the window
+func (w *Window) Contains(t time.Time) bool {
+	return !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+}
+
+// MaintenanceError is returned for calls during a maintenance window. Jobs
+// failing with it are re-run at RetryAt without counting as an attempt.
+type MaintenanceError struct {
+	Integration string
+	Until       time.Time
+	Reason      string
+}
+
+func (e *MaintenanceError) Error() string {
+	msg := fmt.Sprintf("%s is in a maintenance window until %s", e.Integration, e.Until.UTC().Format(time.RFC3339))
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+// Is makes errors.Is(err, ErrMaintenanceWindow) match
+func (e *MaintenanceError) Is(target error) bool {
+	return target == ErrMaintenanceWindow
+}
+
+// RetryAt returns when the window ends
+func (e *MaintenanceError) RetryAt() time.Time {
+	return e.Until
+}
+
+// RetryAfter returns the seconds until the window ends, for Retry-After
+func (e *MaintenanceError) RetryAfter() int {
+	return max(1, int(math.Ceil(time.Until(e.Until).Seconds())))
+}
+
+// AsMaintenance returns the maintenance error wrapped in err, if any
+func AsMaintenance(err error) (*MaintenanceError, bool) {
+	var m *MaintenanceError
+	if errors.As(err, &m) {
+		return m, true
+	}
+	return nil, false
+}
+
+// NewIntegration builds an integration from "name=url" entries. The first
+// set is the default unless active names another one.
+func NewIntegration(name string, sets []string, active string) (Integration, error) {
+	integration := Integration{Name: name}
+	for _, entry := range sets {
+		setName, url, ok := strings.Cut(entry, "=")
+		setName, url = strings.TrimSpace(setName), strings.TrimSpace(url)
+		if !ok || setName == "" || url == "" {
+			return Integration{}, fmt.Errorf("invalid endpoint set %q, want name=url", entry)
+		}
+		if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+			return Integration{}, fmt.Errorf("endpoint set %s: invalid URL %q", setName, url)
+		}
+		if _, dup := integration.Set(setName); dup {
+			return Integration{}, fmt.Errorf("duplicate endpoint set %s", setName)
+		}
+		integration.Sets = append(integration.Sets, Set{Name: setName, URL: url})
+	}
+	if len(integration.Sets) == 0 {
+		return Integration{}, errors.New("no endpoint sets")
+	}
+
+	integration.Default = integration.Sets[0].Name
+	if active != "" {
+		if _, ok := integration.Set(active); !ok {
+			return Integration{}, fmt.Errorf("%w: %s", ErrUnknownSet, active)
+		}
+		integration.Default = active
+	}
+	return integration, nil
+}
+
+// ParseSwitchovers parses "integration:set@time" entries, time in RFC 3339
+func ParseSwitchovers(entries []string) ([]Switchover, error) {
+	var switchovers []Switchover
+	for _, entry := range entries {
+		target, at, ok := strings.Cut(entry, "@")
+		integration, set, ok2 := strings.Cut(target, ":")
+		if !ok || !ok2 || integration == "" || set == "" {
+			return nil, fmt.Errorf("invalid switchover %q, want integration:set@time", entry)
+		}
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(at))
+		if err != nil {
+			return nil, fmt.Errorf("invalid switchover %q: %w", entry, err)
+		}
+		switchovers = append(switchovers, Switchover{
+			Integration: strings.TrimSpace(integration),
+			Set:         strings.TrimSpace(set),
+			At:          t,
+			Source:      SourceConfig,
+		})
+	}
+	return switchovers, nil
+}
+
+// ParseWindows parses "integration@start/end" entries, times in RFC 3339
+func ParseWindows(entries []string) ([]Window, error) {
+	var windows []Window
+	for _, entry := range entries {
+		integration, span, ok := strings.Cut(entry, "@")
+		start, end, ok2 := strings.Cut(span, "/")
+		if !ok || !ok2 || integration == "" {
+			return nil, fmt.Errorf("invalid maintenance window %q, want integration@start/end", entry)
+		}
+		startsAt, err := time.Parse(time.RFC3339, strings.TrimSpace(start))
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance window %q: %w", entry, err)
+		}
+		endsAt, err := time.Parse(time.RFC3339, strings.TrimSpace(end))
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance window %q: %w", entry, err)
+		}
+		if !endsAt.After(startsAt) {
+			return nil, fmt.Errorf("invalid maintenance window %q: ends before it starts", entry)
+		}
+		windows = append(windows, Window{
+			Integration: strings.TrimSpace(integration),
+			StartsAt:    startsAt,
+			EndsAt:      endsAt,
+			Source:      SourceConfig,
+		})
+	}
+	return windows, nil
+}
@@ -0,0 +1,158 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler serves the endpoint admin API. Endpoints are platform-wide, so
+// the API is authenticated with the maintenance token like the backups.
+type Handler struct {
+	registry *Registry
+	token    string
+	logger   *slog.Logger
+}
+
+// NewHandler creates a new endpoint admin handler
+func NewHandler(registry *Registry, token string, logger *slog.Logger) *Handler {
+	return &Handler{
+		registry: registry,
+		token:    token,
+		logger:   logger,
+	}
+}
+
+// RegisterRoutes registers the endpoint admin routes
+func (h *Handler) RegisterRoutes(router *api.Router) {
+	router.Handle("GET /api/v1/maintenance/endpoints", h.requireToken(h.List))
+	router.Handle("POST /api/v1/maintenance/endpoints/{integration}/switchovers", h.requireToken(h.Switch))
+	router.Handle("DELETE /api/v1/maintenance/endpoints/{integration}/switchovers/{id}", h.requireToken(h.CancelSwitchover))
+	router.Handle("POST /api/v1/maintenance/endpoints/{integration}/windows", h.requireToken(h.AddWindow))
+	router.Handle("DELETE /api/v1/maintenance/endpoints/{integration}/windows/{id}", h.requireToken(h.DeleteWindow))
+}
+
+func (h *Handler) requireToken(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !api.BearerTokenMatches(r, h.token) {
+			api.JSONError(w, http.StatusUnauthorized, "Invalid maintenance token", api.ErrCodeUnauthorized)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// List handles GET /api/v1/maintenance/endpoints
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	api.JSONResponse(w, http.StatusOK, map[string]any{"integrations": h.registry.Status()})
+}
+
+// SwitchRequest is the body of POST .../switchovers. Without "at" the set
+// becomes active immediately.
+type SwitchRequest struct {
+	Set  string     `json:"set"`
+	At   *time.Time `json:"at,omitempty"`
+	Note string     `json:"note,omitempty"`
+}
+
+// Switch handles POST /api/v1/maintenance/endpoints/{integration}/switchovers
+func (h *Handler) Switch(w http.ResponseWriter, r *http.Request) {
+	var req SwitchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "Invalid request body")
+		return
+	}
+	if req.Set == "" {
+		api.BadRequest(w, "set is required")
+		return
+	}
+
+	s := &Switchover{Integration: r.PathValue("integration"), Set: req.Set, Note: req.Note}
+	if req.At != nil {
+		s.At = *req.At
+	}
+	if err := h.registry.Schedule(r.Context(), s); err != nil {
+		h.handleError(w, err)
+		return
+	}
+	api.JSONResponse(w, http.StatusCreated, s)
+}
+
+// CancelSwitchover handles DELETE /api/v1/maintenance/endpoints/{integration}/switchovers/{id}
+func (h *Handler) CancelSwitchover(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "Invalid switchover ID")
+		return
+	}
+	if err := h.registry.CancelSwitchover(r.Context(), r.PathValue("integration"), id); err != nil {
+		h.handleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// WindowRequest is the body of POST .../windows
+type WindowRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// AddWindow handles POST /api/v1/maintenance/endpoints/{integration}/windows
+func (h *Handler) AddWindow(w http.ResponseWriter, r *http.Request) {
+	var req WindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "Invalid request body")
+		return
+	}
+	if req.StartsAt.IsZero() || !req.EndsAt.After(req.StartsAt) {
+		api.BadRequest(w, "ends_at must be after starts_at")
+		return
+	}
+	if !req.EndsAt.After(time.Now()) {
+		api.BadRequest(w, "Maintenance window has already ended")
+		return
+	}
+
+	window := &Window{Integration: r.PathValue("integration"), StartsAt: req.StartsAt, EndsAt: req.EndsAt, Reason: req.Reason}
+	if err := h.registry.AddWindow(r.Context(), window); err != nil {
+		h.handleError(w, err)
+		return
+	}
+	api.JSONResponse(w, http.StatusCreated, window)
+}
+
+// DeleteWindow handles DELETE /api/v1/maintenance/endpoints/{integration}/windows/{id}
+func (h *Handler) DeleteWindow(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "Invalid maintenance window ID")
+		return
+	}
+	if err := h.registry.DeleteWindow(r.Context(), r.PathValue("integration"), id); err != nil {
+		h.handleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownIntegration):
+		api.NotFound(w, "Integration not found")
+	case errors.Is(err, ErrUnknownSet):
+		api.BadRequest(w, "Unknown endpoint set")
+	case errors.Is(err, ErrNotFound):
+		api.NotFound(w, "Switchover or maintenance window not found")
+	default:
+		h.logger.Error("endpoint admin request failed", "error", err)
+		api.InternalError(w)
+	}
+}
@@ -0,0 +1,333 @@
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Config configures the registry. Switchovers and windows given here come
+// from the deployment configuration; the admin API adds more at runtime.
+type Config struct {
+	Integrations    []Integration
+	Switchovers     []Switchover
+	Windows         []Window
+	RefreshInterval time.Duration
+}
+
+// Registry resolves the active endpoint of each integration. Stored
+// switchovers and windows are reloaded periodically so that changes made on
+// one instance reach all servers and workers without a redeploy.
+type Registry struct {
+	integrations map[string]*Integration
+	names        []string
+	static       Config
+	repo         *Repository
+	logger       *slog.Logger
+
+	mu          sync.RWMutex
+	switchovers []Switchover
+	windows     []Window
+	active      map[string]string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewRegistry creates a registry. repo may be nil for a configuration that
+// only changes through redeploys; the admin API needs it.
+func NewRegistry(repo *Repository, cfg Config, logger *slog.Logger) (*Registry, error) {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = 30 * time.Second
+	}
+	r := &Registry{
+		integrations: make(map[string]*Integration),
+		static:       cfg,
+		repo:         repo,
+		logger:       logger.With("component", "endpoints"),
+		active:       make(map[string]string),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	for i := range cfg.Integrations {
+		integration := &cfg.Integrations[i]
+		if _, dup := r.integrations[integration.Name]; dup {
+			return nil, fmt.Errorf("duplicate integration %s", integration.Name)
+		}
+		r.integrations[integration.Name] = integration
+		r.names = append(r.names, integration.Name)
+	}
+	for _, s := range cfg.Switchovers {
+		if err := r.validateSet(s.Integration, s.Set); err != nil {
+			return nil, fmt.Errorf("switchover %s:%s: %w", s.Integration, s.Set, err)
+		}
+	}
+	for _, w := range cfg.Windows {
+		if _, ok := r.integrations[w.Integration]; !ok {
+			return nil, fmt.Errorf("maintenance window: %w: %s", ErrUnknownIntegration, w.Integration)
+		}
+	}
+
+	r.apply(nil, nil)
+	if repo != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := r.Refresh(ctx); err != nil {
+			r.logger.Error("failed to load endpoint switchovers", "error", err)
+		}
+		cancel()
+	}
+	go r.loop()
+	return r, nil
+}
+
+// Close stops the periodic reload
+func (r *Registry) Close() {
+	close(r.stop)
+	<-r.done
+}
+
+func (r *Registry) loop() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.static.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := r.Refresh(ctx); err != nil {
+				r.logger.Error("failed to reload endpoint switchovers", "error", err)
+			}
+			cancel()
+			r.logSwitches()
+		}
+	}
+}
+
+// Refresh reloads stored switchovers and windows
+func (r *Registry) Refresh(ctx context.Context) error {
+	if r.repo == nil {
+		return nil
+	}
+	switchovers, err := r.repo.ListSwitchovers(ctx)
+	if err != nil {
+		return err
+	}
+	windows, err := r.repo.ListWindows(ctx)
+	if err != nil {
+		return err
+	}
+	r.apply(switchovers, windows)
+	return nil
+}
+
+func (r *Registry) apply(switchovers []Switchover, windows []Window) {
+	all := append(append([]Switchover{}, r.static.Switchovers...), switchovers...)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].At.Before(all[j].At) })
+
+	r.mu.Lock()
+	r.switchovers = all
+	r.windows = append(append([]Window{}, r.static.Windows...), windows...)
+	r.mu.Unlock()
+}
+
+// logSwitches logs when a scheduled switchover has taken effect
+func (r *Registry) logSwitches() {
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range r.names {
+		set, _ := r.activeSet(name, now)
+		if previous, ok := r.active[name]; ok && previous != set.Name {
+			r.logger.Info("endpoint switched", "integration", name, "from", previous, "to", set.Name, "url", set.URL)
+		}
+		r.active[name] = set.Name
+	}
+}
+
+// activeSet returns the set of the latest due switchover and when it took
+// effect. The caller holds the lock.
+func (r *Registry) activeSet(name string, now time.Time) (Set, *time.Time) {
+	integration := r.integrations[name]
+	set, _ := integration.Set(integration.Default)
+	var since *time.Time
+	for i := range r.switchovers {
+		s := &r.switchovers[i]
+		if s.Integration != name || s.At.After(now) {
+			continue
+		}
+		// Stored switchovers may name a set removed from the configuration
+		if target, ok := integration.Set(s.Set); ok {
+			set, since = target, &s.At
+		}
+	}
+	return set, since
+}
+
+// maintenance returns the window covering now, extended to the latest end
+// of overlapping windows. The caller holds the lock.
+func (r *Registry) maintenance(name string, now time.Time) *Window {
+	var current *Window
+	for i := range r.windows {
+		w := &r.windows[i]
+		if w.Integration == name && w.Contains(now) && (current == nil || w.EndsAt.After(current.EndsAt)) {
+			current = w
+		}
+	}
+	return current
+}
+
+// Resolve returns the URL of the active endpoint set. During a maintenance
+// window it returns a *MaintenanceError instead.
+func (r *Registry) Resolve(name string) (string, error) {
+	now := time.Now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.integrations[name]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownIntegration, name)
+	}
+	if w := r.maintenance(name, now); w != nil {
+		return "", &MaintenanceError{Integration: name, Until: w.EndsAt, Reason: w.Reason}
+	}
+	set, _ := r.activeSet(name, now)
+	return set.URL, nil
+}
+
+// Resolver returns a resolver for one integration, as taken by the
+// FinanzOnline and ELDA clients
+func (r *Registry) Resolver(name string) func() (string, error) {
+	return func() (string, error) {
+		return r.Resolve(name)
+	}
+}
+
+// Status is the endpoint state of one integration
+type Status struct {
+	Integration   string       `json:"integration"`
+	Sets          []Set        `json:"sets"`
+	Active        string       `json:"active"`
+	URL           string       `json:"url"`
+	ActiveSince   *time.Time   `json:"active_since,omitempty"`
+	InMaintenance bool         `json:"in_maintenance"`
+	RetryAt       *time.Time   `json:"retry_at,omitempty"`
+	Switchovers   []Switchover `json:"switchovers"`
+	Windows       []Window     `json:"windows"`
+}
+
+// Status returns the state of all integrations with their pending
+// switchovers and windows
+func (r *Registry) Status() []Status {
+	now := time.Now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(r.names))
+	for _, name := range r.names {
+		set, since := r.activeSet(name, now)
+		st := Status{
+			Integration: name,
+			Sets:        r.integrations[name].Sets,
+			Active:      set.Name,
+			URL:         set.URL,
+			ActiveSince: since,
+			Switchovers: []Switchover{},
+			Windows:     []Window{},
+		}
+		if w := r.maintenance(name, now); w != nil {
+			st.InMaintenance = true
+			st.RetryAt = &w.EndsAt
+		}
+		for _, s := range r.switchovers {
+			if s.Integration == name && s.At.After(now) {
+				st.Switchovers = append(st.Switchovers, s)
+			}
+		}
+		for _, w := range r.windows {
+			if w.Integration == name && w.EndsAt.After(now) {
+				st.Windows = append(st.Windows, w)
+			}
+		}
+		sort.Slice(st.Windows, func(i, j int) bool { return st.Windows[i].StartsAt.Before(st.Windows[j].StartsAt) })
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
+
+func (r *Registry) validateSet(integration, set string) error {
+	i, ok := r.integrations[integration]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownIntegration, integration)
+	}
+	if _, ok := i.Set(set); !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownSet, set)
+	}
+	return nil
+}
+
+var errNoRepository = errors.New("endpoint registry has no repository")
+
+// Schedule stores a switchover; a zero time switches now
+func (r *Registry) Schedule(ctx context.Context, s *Switchover) error {
+	if r.repo == nil {
+		return errNoRepository
+	}
+	if err := r.validateSet(s.Integration, s.Set); err != nil {
+		return err
+	}
+	if s.At.IsZero() {
+		s.At = time.Now()
+	}
+	if err := r.repo.CreateSwitchover(ctx, s); err != nil {
+		return err
+	}
+	r.logger.Info("endpoint switchover scheduled", "integration", s.Integration, "set", s.Set, "at", s.At, "note", s.Note)
+	return r.Refresh(ctx)
+}
+
+// CancelSwitchover cancels a stored switchover that is not yet due
+func (r *Registry) CancelSwitchover(ctx context.Context, integration string, id uuid.UUID) error {
+	if r.repo == nil {
+		return errNoRepository
+	}
+	if err := r.repo.CancelSwitchover(ctx, integration, id); err != nil {
+		return err
+	}
+	r.logger.Info("endpoint switchover cancelled", "integration", integration, "id", id)
+	return r.Refresh(ctx)
+}
+
+// AddWindow stores a maintenance window
+func (r *Registry) AddWindow(ctx context.Context, w *Window) error {
+	if r.repo == nil {
+		return errNoRepository
+	}
+	if _, ok := r.integrations[w.Integration]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownIntegration, w.Integration)
+	}
+	if err := r.repo.CreateWindow(ctx, w); err != nil {
+		return err
+	}
+	r.logger.Info("maintenance window added", "integration", w.Integration, "starts_at", w.StartsAt, "ends_at", w.EndsAt)
+	return r.Refresh(ctx)
+}
+
+// DeleteWindow removes a stored maintenance window
+func (r *Registry) DeleteWindow(ctx context.Context, integration string, id uuid.UUID) error {
+	if r.repo == nil {
+		return errNoRepository
+	}
+	if err := r.repo.DeleteWindow(ctx, integration, id); err != nil {
+		return err
+	}
+	r.logger.Info("maintenance window removed", "integration", integration, "id", id)
+	return r.Refresh(ctx)
+}
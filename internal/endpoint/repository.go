@@ -0,0 +1,138 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository stores switchovers and maintenance windows made through the
+// admin API. They are platform-wide and not tenant scoped.
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new endpoint repository
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// ListSwitchovers returns the switchovers still pending and, per
+// integration, the latest one already due
+func (r *Repository) ListSwitchovers(ctx context.Context) ([]Switchover, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, integration, target_set, switch_at, COALESCE(note, '')
+		FROM endpoint_switchovers
+		WHERE switch_at > NOW() OR id IN (
+			SELECT DISTINCT ON (integration) id FROM endpoint_switchovers
+			WHERE switch_at <= NOW()
+			ORDER BY integration, switch_at DESC, created_at DESC
+		)
+		ORDER BY switch_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list switchovers: %w", err)
+	}
+	defer rows.Close()
+
+	var switchovers []Switchover
+	for rows.Next() {
+		var id uuid.UUID
+		s := Switchover{Source: SourceAPI}
+		if err := rows.Scan(&id, &s.Integration, &s.Set, &s.At, &s.Note); err != nil {
+			return nil, err
+		}
+		s.ID = &id
+		switchovers = append(switchovers, s)
+	}
+	return switchovers, rows.Err()
+}
+
+// CreateSwitchover stores a switchover
+func (r *Repository) CreateSwitchover(ctx context.Context, s *Switchover) error {
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO endpoint_switchovers (integration, target_set, switch_at, note)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING id
+	`, s.Integration, s.Set, s.At, s.Note).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("create switchover: %w", err)
+	}
+	s.ID = &id
+	s.Source = SourceAPI
+	return nil
+}
+
+// CancelSwitchover deletes a switchover that is not yet due
+func (r *Repository) CancelSwitchover(ctx context.Context, integration string, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM endpoint_switchovers WHERE id = $1 AND integration = $2 AND switch_at > NOW()
+	`, id, integration)
+	if err != nil {
+		return fmt.Errorf("cancel switchover: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListWindows returns the maintenance windows that have not ended
+func (r *Repository) ListWindows(ctx context.Context) ([]Window, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, integration, starts_at, ends_at, COALESCE(reason, '')
+		FROM endpoint_maintenance_windows
+		WHERE ends_at > $1
+		ORDER BY starts_at
+	`, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("list maintenance windows: %w", err)
+	}
+	defer rows.Close()
+
+	var windows []Window
+	for rows.Next() {
+		var id uuid.UUID
+		w := Window{Source: SourceAPI}
+		if err := rows.Scan(&id, &w.Integration, &w.StartsAt, &w.EndsAt, &w.Reason); err != nil {
+			return nil, err
+		}
+		w.ID = &id
+		windows = append(windows, w)
+	}
+	return windows, rows.Err()
+}
+
+// CreateWindow stores a maintenance window
+func (r *Repository) CreateWindow(ctx context.Context, w *Window) error {
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO endpoint_maintenance_windows (integration, starts_at, ends_at, reason)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING id
+	`, w.Integration, w.StartsAt, w.EndsAt, w.Reason).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("create maintenance window: %w", err)
+	}
+	w.ID = &id
+	w.Source = SourceAPI
+	return nil
+}
+
+// DeleteWindow deletes a maintenance window, ending it early if it is on
+func (r *Repository) DeleteWindow(ctx context.Context, integration string, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM endpoint_maintenance_windows WHERE id = $1 AND integration = $2
+	`, id, integration)
+	if err != nil {
+		return fmt.Errorf("delete maintenance window: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
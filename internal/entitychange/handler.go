@@ -0,0 +1,191 @@
+package entitychange
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// Handler handles entity change HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new entity change handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers entity change routes. Reading is open to members;
+// changing which entity filings belong to is admin-only.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/entity-changes", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("GET /api/v1/entity-changes/{id}", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("POST /api/v1/entity-changes", requireAuth(requireAdmin(http.HandlerFunc(h.Create))))
+	router.Handle("PUT /api/v1/entity-changes/{id}/accounts/{accountId}", requireAuth(requireAdmin(http.HandlerFunc(h.UpdateAccount))))
+	router.Handle("POST /api/v1/entity-changes/{id}/complete", requireAuth(requireAdmin(http.HandlerFunc(h.Complete))))
+	router.Handle("POST /api/v1/entity-changes/{id}/cancel", requireAuth(requireAdmin(http.HandlerFunc(h.Cancel))))
+}
+
+// List handles GET /api/v1/entity-changes
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var accountID *uuid.UUID
+	if s := r.URL.Query().Get("account_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			api.BadRequest(w, "invalid account_id")
+			return
+		}
+		accountID = &id
+	}
+
+	changes, err := h.service.List(r.Context(), tenantID, accountID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if changes == nil {
+		changes = []*Change{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items": changes,
+		"total": len(changes),
+	})
+}
+
+// Get handles GET /api/v1/entity-changes/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	change, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, change)
+}
+
+// Create handles POST /api/v1/entity-changes
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var input CreateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	change, err := h.service.Create(r.Context(), tenantID, api.OptionalUser(r), &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, change)
+}
+
+// UpdateAccount handles PUT /api/v1/entity-changes/{id}/accounts/{accountId}
+func (h *Handler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	accountID, ok := api.PathID(w, r, "accountId")
+	if !ok {
+		return
+	}
+
+	var input AccountInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	change, err := h.service.UpdateAccount(r.Context(), tenantID, id, accountID, api.OptionalUser(r), &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, change)
+}
+
+// Complete handles POST /api/v1/entity-changes/{id}/complete
+func (h *Handler) Complete(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	change, err := h.service.Complete(r.Context(), tenantID, id, api.OptionalUser(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, change)
+}
+
+// Cancel handles POST /api/v1/entity-changes/{id}/cancel
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	change, err := h.service.Cancel(r.Context(), tenantID, id, api.OptionalUser(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, change)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrChangeNotFound):
+		api.NotFound(w, "entity change not found")
+	case errors.Is(err, ErrNotDraft), errors.Is(err, ErrAccountInChange), errors.Is(err, ErrSuccessorRequired):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, ErrInvalidKind), errors.Is(err, ErrInvalidLegalForm), errors.Is(err, ErrSameLegalForm),
+		errors.Is(err, ErrNamesRequired), errors.Is(err, ErrInvalidEffective), errors.Is(err, ErrNoAccounts),
+		errors.Is(err, ErrAccountNotFound), errors.Is(err, ErrAccountNotInChange), errors.Is(err, ErrRegistrationKept),
+		errors.Is(err, ErrSuccessorAccount), errors.Is(err, ErrSuccessorIsOldEntity):
+		api.BadRequest(w, err.Error())
+	default:
+		api.InternalError(w)
+	}
+}
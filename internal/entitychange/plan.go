@@ -0,0 +1,92 @@
+package entitychange
+
+import (
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/account"
+)
+
+var legalForms = map[string]string{
+	FormEU:     "sole",
+	FormOG:     "partnership",
+	FormKG:     "partnership",
+	FormGmbH:   "corporation",
+	FormFlexCo: "corporation",
+	FormAG:     "corporation",
+}
+
+// Validate checks the request and normalizes the legal forms. It returns
+// the effective date.
+func (in *CreateInput) Validate() (time.Time, error) {
+	in.Kind = strings.ToLower(strings.TrimSpace(in.Kind))
+	in.OldLegalForm = strings.ToLower(strings.TrimSpace(in.OldLegalForm))
+	in.NewLegalForm = strings.ToLower(strings.TrimSpace(in.NewLegalForm))
+	in.EntityName = strings.TrimSpace(in.EntityName)
+	in.SuccessorName = strings.TrimSpace(in.SuccessorName)
+	in.Note = strings.TrimSpace(in.Note)
+
+	if in.Kind != KindConversion && in.Kind != KindMerger {
+		return time.Time{}, ErrInvalidKind
+	}
+	if legalForms[in.OldLegalForm] == "" || legalForms[in.NewLegalForm] == "" {
+		return time.Time{}, ErrInvalidLegalForm
+	}
+	if in.Kind == KindConversion && in.OldLegalForm == in.NewLegalForm {
+		return time.Time{}, ErrSameLegalForm
+	}
+	if in.EntityName == "" || in.SuccessorName == "" {
+		return time.Time{}, ErrNamesRequired
+	}
+	effective, err := time.Parse("2006-01-02", in.EffectiveDate)
+	if err != nil {
+		return time.Time{}, ErrInvalidEffective
+	}
+	if len(in.AccountIDs) == 0 {
+		return time.Time{}, ErrNoAccounts
+	}
+	return effective, nil
+}
+
+// KeepsIdentity reports whether the entity stays the same legal person. A
+// Formwechsel within corporations (GmbH to AG) or within partnerships (OG
+// to KG) keeps it; any other conversion, e.g. an Einzelunternehmen brought
+// into a GmbH, and every merger creates or uses a different one.
+func KeepsIdentity(kind, oldForm, newForm string) bool {
+	family := legalForms[oldForm]
+	return kind == KindConversion && family != "sole" && family == legalForms[newForm]
+}
+
+// Plan returns what has to happen to an account of the old entity, and why
+func Plan(kind, oldForm, newForm, accountType string) (action, reason string) {
+	if KeepsIdentity(kind, oldForm, newForm) {
+		return ActionKeep, "The legal entity continues under its new legal form; update the company name and legal form of the registration."
+	}
+
+	if kind == KindMerger {
+		switch accountType {
+		case account.AccountTypeFinanzOnline:
+			return ActionClose, "The Steuernummer of the absorbed company ends with the merger. File the outstanding returns up to the effective date under this account and link the absorbing company's account."
+		case account.AccountTypeELDA:
+			return ActionClose, "The employees pass to the absorbing company (Betriebsübergang). De-register them under this Dienstgeberkonto and register them under the absorbing company's."
+		case account.AccountTypeFirmenbuch:
+			return ActionClose, "The absorbed company is deleted from the Firmenbuch when the merger is registered; link the absorbing company's entry."
+		}
+		return ActionClose, "The registration ends with the merger; link the absorbing company's account."
+	}
+
+	switch accountType {
+	case account.AccountTypeFinanzOnline:
+		return ActionReregister, "The successor is a new taxpayer. Register it with the Finanzamt for its own Steuernummer and link its FinanzOnline account; filings up to the effective date stay with this account."
+	case account.AccountTypeELDA:
+		return ActionReregister, "The successor needs its own Dienstgeberkonto at the ÖGK. De-register the employees under this account and register them under the successor's."
+	case account.AccountTypeFirmenbuch:
+		return ActionReregister, "The successor is entered in the Firmenbuch with its own Firmenbuchnummer; link its entry."
+	}
+	return ActionReregister, "The successor is a new legal entity and needs its own registration; link its account."
+}
+
+// replaces reports whether the action moves filings to the successor
+func replaces(action string) bool {
+	return action == ActionReregister || action == ActionClose
+}
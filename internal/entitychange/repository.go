@@ -0,0 +1,294 @@
+package entitychange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles entity change database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new entity change repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Snapshot returns the given active accounts of a tenant with their
+// document and filing counts
+func (r *Repository) Snapshot(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) ([]*AccountChange, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT a.id, a.name, a.type, a.status,
+			(SELECT COUNT(*) FROM documents d WHERE d.account_id = a.id AND d.tenant_id = a.tenant_id),
+			(SELECT COUNT(*) FROM uva_submissions u WHERE u.account_id = a.id AND u.tenant_id = a.tenant_id)
+				+ (SELECT COUNT(*) FROM zm_submissions z WHERE z.account_id = a.id AND z.tenant_id = a.tenant_id)
+		FROM accounts a
+		WHERE a.tenant_id = $1 AND a.id = ANY($2) AND a.deleted_at IS NULL
+		ORDER BY a.name`, tenantID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []*AccountChange
+	for rows.Next() {
+		var a AccountChange
+		if err := rows.Scan(&a.AccountID, &a.Name, &a.Type, &a.Status, &a.Documents, &a.Filings); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, &a)
+	}
+	return accounts, rows.Err()
+}
+
+// InChange reports whether any of the accounts is an old account of a draft
+// change, or was replaced by a completed one
+func (r *Repository) InChange(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM entity_change_accounts eca
+			JOIN entity_changes ec ON ec.id = eca.change_id
+			WHERE ec.tenant_id = $1 AND eca.account_id = ANY($2)
+				AND (ec.status = 'draft' OR (ec.status = 'completed' AND eca.action <> 'keep'))
+		)`, tenantID, ids).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check entity changes: %w", err)
+	}
+	return exists, nil
+}
+
+// AccountType returns the type of an active account of a tenant
+func (r *Repository) AccountType(ctx context.Context, tenantID, id uuid.UUID) (string, error) {
+	var t string
+	err := r.db.QueryRow(ctx, `
+		SELECT type FROM accounts
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL`, id, tenantID).Scan(&t)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrSuccessorAccount
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load account: %w", err)
+	}
+	return t, nil
+}
+
+// Create stores a new change with its accounts
+func (r *Repository) Create(ctx context.Context, c *Change) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO entity_changes (
+			tenant_id, kind, entity_name, old_legal_form, successor_name, new_legal_form,
+			effective_date, status, note, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10)
+		RETURNING id, created_at, updated_at`,
+		c.TenantID, c.Kind, c.EntityName, c.OldLegalForm, c.SuccessorName, c.NewLegalForm,
+		c.EffectiveDate, c.Status, c.Note, c.CreatedBy,
+	).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create entity change: %w", err)
+	}
+
+	for _, a := range c.Accounts {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO entity_change_accounts (
+				change_id, account_id, account_name, account_type, account_status,
+				documents, filings, action, reason
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			c.ID, a.AccountID, a.Name, a.Type, a.Status, a.Documents, a.Filings, a.Action, a.Reason)
+		if err != nil {
+			return fmt.Errorf("failed to store entity change account: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+const changeColumns = `id, tenant_id, kind, entity_name, old_legal_form, successor_name, new_legal_form,
+	effective_date, status, COALESCE(note, ''), created_by, completed_by, created_at, updated_at, completed_at`
+
+func scanChange(row pgx.Row) (*Change, error) {
+	var c Change
+	err := row.Scan(&c.ID, &c.TenantID, &c.Kind, &c.EntityName, &c.OldLegalForm, &c.SuccessorName,
+		&c.NewLegalForm, &c.EffectiveDate, &c.Status, &c.Note, &c.CreatedBy, &c.CompletedBy,
+		&c.CreatedAt, &c.UpdatedAt, &c.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+	c.Accounts = []*AccountChange{}
+	return &c, nil
+}
+
+// Get returns a change of the tenant with its accounts
+func (r *Repository) Get(ctx context.Context, tenantID, id uuid.UUID) (*Change, error) {
+	c, err := scanChange(r.db.QueryRow(ctx, `SELECT `+changeColumns+` FROM entity_changes
+		WHERE id = $1 AND tenant_id = $2`, id, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrChangeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity change: %w", err)
+	}
+	if err := r.attachAccounts(ctx, []*Change{c}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// List returns the changes of a tenant, newest first. With an account, only
+// the changes the account is an old or a successor account of.
+func (r *Repository) List(ctx context.Context, tenantID uuid.UUID, accountID *uuid.UUID) ([]*Change, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+changeColumns+` FROM entity_changes ec
+		WHERE tenant_id = $1 AND ($2::uuid IS NULL OR EXISTS (
+			SELECT 1 FROM entity_change_accounts eca
+			WHERE eca.change_id = ec.id AND (eca.account_id = $2 OR eca.successor_account_id = $2)
+		))
+		ORDER BY created_at DESC LIMIT 200`, tenantID, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entity changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []*Change
+	for rows.Next() {
+		c, err := scanChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := r.attachAccounts(ctx, changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (r *Repository) attachAccounts(ctx context.Context, changes []*Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	byID := make(map[uuid.UUID]*Change, len(changes))
+	ids := make([]uuid.UUID, 0, len(changes))
+	for _, c := range changes {
+		byID[c.ID] = c
+		ids = append(ids, c.ID)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT change_id, account_id, account_name, account_type, account_status, documents, filings,
+			action, reason, successor_account_id, done_by, done_at
+		FROM entity_change_accounts
+		WHERE change_id = ANY($1)
+		ORDER BY account_type, account_name`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load entity change accounts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var changeID uuid.UUID
+		var a AccountChange
+		if err := rows.Scan(&changeID, &a.AccountID, &a.Name, &a.Type, &a.Status, &a.Documents, &a.Filings,
+			&a.Action, &a.Reason, &a.SuccessorAccountID, &a.DoneBy, &a.DoneAt); err != nil {
+			return err
+		}
+		byID[changeID].Accounts = append(byID[changeID].Accounts, &a)
+	}
+	return rows.Err()
+}
+
+// UpdateAccount stores the successor and done state of an account of a
+// draft change
+func (r *Repository) UpdateAccount(ctx context.Context, changeID uuid.UUID, a *AccountChange) error {
+	tag, err := r.db.Exec(ctx, `
+		WITH draft AS (
+			UPDATE entity_changes SET updated_at = NOW()
+			WHERE id = $1 AND status = 'draft'
+			RETURNING id
+		)
+		UPDATE entity_change_accounts
+		SET successor_account_id = $3, done_by = $4, done_at = $5
+		WHERE change_id = (SELECT id FROM draft) AND account_id = $2`,
+		changeID, a.AccountID, a.SuccessorAccountID, a.DoneBy, a.DoneAt)
+	if err != nil {
+		return fmt.Errorf("failed to update entity change account: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotDraft
+	}
+	return nil
+}
+
+// Close moves a draft change to completed or cancelled
+func (r *Repository) Close(ctx context.Context, id uuid.UUID, status string, by *uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE entity_changes
+		SET status = $2, updated_at = NOW(),
+			completed_by = CASE WHEN $2 = 'completed' THEN $3::uuid END,
+			completed_at = CASE WHEN $2 = 'completed' THEN NOW() END
+		WHERE id = $1 AND status = 'draft'`, id, status, by)
+	if err != nil {
+		return fmt.Errorf("failed to update entity change: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotDraft
+	}
+	return nil
+}
+
+// succession is a completed change an account is part of
+type succession struct {
+	EffectiveDate time.Time
+	AccountID     uuid.UUID
+	SuccessorID   *uuid.UUID
+}
+
+// ReplacedBy returns the completed change that replaced the account on or
+// before the given day, if any
+func (r *Repository) ReplacedBy(ctx context.Context, tenantID, accountID uuid.UUID, day time.Time) (*succession, error) {
+	return r.succession(ctx, `
+		WHERE ec.tenant_id = $1 AND ec.status = 'completed' AND eca.account_id = $2
+			AND eca.action IN ('reregister', 'close') AND ec.effective_date <= $3`,
+		tenantID, accountID, day)
+}
+
+// RegisteredAfter returns the completed change the account was newly
+// registered for after the given day, if any
+func (r *Repository) RegisteredAfter(ctx context.Context, tenantID, accountID uuid.UUID, day time.Time) (*succession, error) {
+	return r.succession(ctx, `
+		WHERE ec.tenant_id = $1 AND ec.status = 'completed' AND eca.successor_account_id = $2
+			AND eca.action = 'reregister' AND ec.effective_date > $3`,
+		tenantID, accountID, day)
+}
+
+func (r *Repository) succession(ctx context.Context, where string, args ...interface{}) (*succession, error) {
+	var s succession
+	err := r.db.QueryRow(ctx, `
+		SELECT ec.effective_date, eca.account_id, eca.successor_account_id
+		FROM entity_change_accounts eca
+		JOIN entity_changes ec ON ec.id = eca.change_id
+		`+where+`
+		ORDER BY ec.effective_date
+		LIMIT 1`, args...).Scan(&s.EffectiveDate, &s.AccountID, &s.SuccessorID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check entity changes: %w", err)
+	}
+	return &s, nil
+}
@@ -0,0 +1,225 @@
+package entitychange
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Service manages legal entity changes
+type Service struct {
+	repo *Repository
+	now  func() time.Time
+}
+
+// NewService creates a new entity change service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// Create snapshots the accounts of the old entity and plans what has to
+// happen to each. The change stays a draft until it is completed.
+func (s *Service) Create(ctx context.Context, tenantID uuid.UUID, userID *uuid.UUID, input *CreateInput) (*Change, error) {
+	effective, err := input.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := uniqueIDs(input.AccountIDs)
+	if len(ids) == 0 {
+		return nil, ErrNoAccounts
+	}
+	accounts, err := s.repo.Snapshot(ctx, tenantID, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) != len(ids) {
+		return nil, ErrAccountNotFound
+	}
+	taken, err := s.repo.InChange(ctx, tenantID, ids)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrAccountInChange
+	}
+
+	for _, a := range accounts {
+		a.Action, a.Reason = Plan(input.Kind, input.OldLegalForm, input.NewLegalForm, a.Type)
+	}
+	c := &Change{
+		TenantID:      tenantID,
+		Kind:          input.Kind,
+		EntityName:    input.EntityName,
+		OldLegalForm:  input.OldLegalForm,
+		SuccessorName: input.SuccessorName,
+		NewLegalForm:  input.NewLegalForm,
+		EffectiveDate: effective,
+		Status:        StatusDraft,
+		Note:          input.Note,
+		Accounts:      accounts,
+		CreatedBy:     userID,
+	}
+	if err := s.repo.Create(ctx, c); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, tenantID, c.ID)
+}
+
+// Get returns a change of the tenant
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Change, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// List returns the changes of a tenant, optionally only those of an account
+func (s *Service) List(ctx context.Context, tenantID uuid.UUID, accountID *uuid.UUID) ([]*Change, error) {
+	return s.repo.List(ctx, tenantID, accountID)
+}
+
+// UpdateAccount links the successor account of an old account, or ticks off
+// its re-registration, while the change is a draft
+func (s *Service) UpdateAccount(ctx context.Context, tenantID, id, accountID uuid.UUID, userID *uuid.UUID, input *AccountInput) (*Change, error) {
+	c, err := s.draft(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	a := c.account(accountID)
+	if a == nil {
+		return nil, ErrAccountNotInChange
+	}
+
+	if input.SuccessorAccountID != nil {
+		successor := *input.SuccessorAccountID
+		if successor == uuid.Nil {
+			a.SuccessorAccountID = nil
+		} else if err := s.checkSuccessor(ctx, c, a, successor); err != nil {
+			return nil, err
+		} else {
+			a.SuccessorAccountID = &successor
+		}
+	}
+	if input.Done != nil {
+		if *input.Done && a.DoneAt == nil {
+			now := s.now()
+			a.DoneAt, a.DoneBy = &now, userID
+		} else if !*input.Done {
+			a.DoneAt, a.DoneBy = nil, nil
+		}
+	}
+
+	if err := s.repo.UpdateAccount(ctx, c.ID, a); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+func (s *Service) checkSuccessor(ctx context.Context, c *Change, a *AccountChange, successor uuid.UUID) error {
+	if !replaces(a.Action) {
+		return ErrRegistrationKept
+	}
+	if c.account(successor) != nil {
+		return ErrSuccessorIsOldEntity
+	}
+	accountType, err := s.repo.AccountType(ctx, c.TenantID, successor)
+	if err != nil {
+		return err
+	}
+	if accountType != a.Type {
+		return ErrSuccessorAccount
+	}
+	return nil
+}
+
+// Complete finishes a change once every account that is re-registered or
+// closed has its successor. From then on filings are checked against the
+// effective date, see CheckPeriod.
+func (s *Service) Complete(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) (*Change, error) {
+	c, err := s.draft(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range c.Accounts {
+		if replaces(a.Action) && a.SuccessorAccountID == nil {
+			return nil, fmt.Errorf("%w: %s", ErrSuccessorRequired, a.Name)
+		}
+	}
+	if err := s.repo.Close(ctx, id, StatusCompleted, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// Cancel drops a draft change
+func (s *Service) Cancel(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) (*Change, error) {
+	if _, err := s.draft(ctx, tenantID, id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Close(ctx, id, StatusCancelled, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+func (s *Service) draft(ctx context.Context, tenantID, id uuid.UUID) (*Change, error) {
+	c, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status != StatusDraft {
+		return nil, ErrNotDraft
+	}
+	return c, nil
+}
+
+// CheckPeriod checks that a filing period from..to (both inclusive) of an
+// account belongs to the entity of the account. It fails with
+// ErrWrongEntity if the account was replaced by a completed change before
+// the period starts, or was newly registered for a successor after the
+// period ends. A period the effective date falls into belongs to both.
+func (s *Service) CheckPeriod(ctx context.Context, tenantID, accountID uuid.UUID, from, to time.Time) error {
+	replaced, err := s.repo.ReplacedBy(ctx, tenantID, accountID, from)
+	if err != nil {
+		return err
+	}
+	if replaced != nil {
+		successor := "the successor's account"
+		if replaced.SuccessorID != nil {
+			successor = "account " + replaced.SuccessorID.String()
+		}
+		return fmt.Errorf("%w: the account was replaced on %s, file under %s", ErrWrongEntity,
+			replaced.EffectiveDate.Format("2006-01-02"), successor)
+	}
+
+	registered, err := s.repo.RegisteredAfter(ctx, tenantID, accountID, to)
+	if err != nil {
+		return err
+	}
+	if registered != nil {
+		return fmt.Errorf("%w: the successor exists from %s, file under account %s", ErrWrongEntity,
+			registered.EffectiveDate.Format("2006-01-02"), registered.AccountID)
+	}
+	return nil
+}
+
+func (c *Change) account(id uuid.UUID) *AccountChange {
+	for _, a := range c.Accounts {
+		if a.AccountID == id {
+			return a
+		}
+	}
+	return nil
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	var out []uuid.UUID
+	for _, id := range ids {
+		if id != uuid.Nil && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
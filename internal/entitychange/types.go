@@ -0,0 +1,130 @@
+// Package entitychange tracks changes of a client's legal entity: a
+// Rechtsformwechsel, e.g. from an Einzelunternehmen to a GmbH, or a merger
+// (Verschmelzung) into another company. A change snapshots the accounts of
+// the old entity, says for each whether its registration continues or has
+// to be redone by the successor (new Steuernummer, new ELDA
+// Dienstgeberkonto), and links the successor's accounts.
+//
+// Filings stay with the account they were filed for. Once a change is
+// completed, periods from the effective date on can no longer be filed for
+// a replaced account, and periods before it not for a newly registered
+// successor account.
+package entitychange
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrChangeNotFound       = errors.New("entity change not found")
+	ErrNotDraft             = errors.New("entity change is no longer a draft")
+	ErrInvalidKind          = errors.New("kind must be conversion or merger")
+	ErrInvalidLegalForm     = errors.New("legal form must be one of eu, og, kg, gmbh, flexco, ag")
+	ErrSameLegalForm        = errors.New("a conversion must change the legal form")
+	ErrNamesRequired        = errors.New("entity_name and successor_name are required")
+	ErrInvalidEffective     = errors.New("effective_date must be a date (YYYY-MM-DD)")
+	ErrNoAccounts           = errors.New("at least one account is required")
+	ErrAccountNotFound      = errors.New("account not found")
+	ErrAccountInChange      = errors.New("account is already part of an open or completed entity change")
+	ErrAccountNotInChange   = errors.New("account is not part of the entity change")
+	ErrRegistrationKept     = errors.New("account keeps its registration and takes no successor")
+	ErrSuccessorAccount     = errors.New("successor account not found or of a different type")
+	ErrSuccessorIsOldEntity = errors.New("successor account belongs to the old entity")
+	ErrSuccessorRequired    = errors.New("every account to re-register or close needs a successor account")
+
+	// ErrWrongEntity is returned for a filing of a period that belongs to
+	// the other side of a completed entity change
+	ErrWrongEntity = errors.New("period belongs to another legal entity")
+)
+
+// Kinds of entity changes
+const (
+	KindConversion = "conversion"
+	KindMerger     = "merger"
+)
+
+// Legal forms
+const (
+	FormEU     = "eu" // Einzelunternehmen, including EPU
+	FormOG     = "og"
+	FormKG     = "kg"
+	FormGmbH   = "gmbh"
+	FormFlexCo = "flexco"
+	FormAG     = "ag"
+)
+
+// Change states
+const (
+	StatusDraft     = "draft"
+	StatusCompleted = "completed"
+	StatusCancelled = "cancelled"
+)
+
+// Account actions
+const (
+	// ActionKeep: the registration continues for the successor
+	ActionKeep = "keep"
+	// ActionReregister: the successor is a new legal entity and needs its
+	// own registration
+	ActionReregister = "reregister"
+	// ActionClose: the registration ends, the absorbing company's takes over
+	ActionClose = "close"
+)
+
+// Change is the change of a client's legal entity
+type Change struct {
+	ID            uuid.UUID        `json:"id"`
+	TenantID      uuid.UUID        `json:"tenant_id"`
+	Kind          string           `json:"kind"`
+	EntityName    string           `json:"entity_name"`
+	OldLegalForm  string           `json:"old_legal_form"`
+	SuccessorName string           `json:"successor_name"`
+	NewLegalForm  string           `json:"new_legal_form"`
+	EffectiveDate time.Time        `json:"effective_date"`
+	Status        string           `json:"status"`
+	Note          string           `json:"note,omitempty"`
+	Accounts      []*AccountChange `json:"accounts"`
+	CreatedBy     *uuid.UUID       `json:"created_by,omitempty"`
+	CompletedBy   *uuid.UUID       `json:"completed_by,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	CompletedAt   *time.Time       `json:"completed_at,omitempty"`
+}
+
+// AccountChange is an account of the old entity as it was when the change
+// was created, with what has to happen to it
+type AccountChange struct {
+	AccountID          uuid.UUID  `json:"account_id"`
+	Name               string     `json:"name"`
+	Type               string     `json:"type"`
+	Status             string     `json:"status"`
+	Documents          int        `json:"documents"`
+	Filings            int        `json:"filings"`
+	Action             string     `json:"action"`
+	Reason             string     `json:"reason"`
+	SuccessorAccountID *uuid.UUID `json:"successor_account_id,omitempty"`
+	DoneBy             *uuid.UUID `json:"done_by,omitempty"`
+	DoneAt             *time.Time `json:"done_at,omitempty"`
+}
+
+// CreateInput starts an entity change
+type CreateInput struct {
+	Kind          string      `json:"kind"`
+	EntityName    string      `json:"entity_name"`
+	OldLegalForm  string      `json:"old_legal_form"`
+	SuccessorName string      `json:"successor_name"`
+	NewLegalForm  string      `json:"new_legal_form"`
+	EffectiveDate string      `json:"effective_date"` // YYYY-MM-DD
+	AccountIDs    []uuid.UUID `json:"account_ids"`
+	Note          string      `json:"note"`
+}
+
+// AccountInput links the successor account of an old account and ticks
+// off its re-registration. Omitted fields are left unchanged.
+type AccountInput struct {
+	SuccessorAccountID *uuid.UUID `json:"successor_account_id"`
+	Done               *bool      `json:"done"`
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"strings"
 	"time"
+
+	"austrian-business-infrastructure/pkg/money"
 )
 
 // DateOnly is a custom type for parsing dates in YYYY-MM-DD format
@@ -196,9 +198,9 @@ type InvoiceLine struct {
 	GTIN string `json:"gtin,omitempty"`
 }
 
-// CalculateTotal calculates the line total
+// CalculateTotal calculates the line total, rounded to whole cents
 func (l *InvoiceLine) CalculateTotal() {
-	l.LineTotal = int64(float64(l.UnitPrice) * l.Quantity)
+	l.LineTotal = money.EUR(l.UnitPrice).Mul(l.Quantity).Cents
 }
 
 // TaxSubtotal represents a VAT breakdown category
@@ -270,7 +272,7 @@ func (inv *Invoice) CalculateTotals() error {
 	inv.TaxAmount = 0
 
 	for _, ts := range taxGroups {
-		ts.TaxAmount = int64(float64(ts.TaxableAmount) * ts.TaxPercent / 100)
+		ts.TaxAmount = money.EUR(ts.TaxableAmount).Percent(ts.TaxPercent).Cents
 		inv.TaxExclusiveAmount += ts.TaxableAmount
 		inv.TaxAmount += ts.TaxAmount
 		inv.TaxSubtotals = append(inv.TaxSubtotals, ts)
@@ -0,0 +1,81 @@
+package fakes
+
+import (
+	"context"
+	"fmt"
+
+	"austrian-business-infrastructure/internal/ai"
+)
+
+// AIComplete is the only operation of the AI fake
+const AIComplete = "Complete"
+
+// Prompt is the recorded request of an AI completion
+type Prompt struct {
+	System      string
+	User        string
+	Temperature float64
+}
+
+// AI is an in-memory ai.Completer. Answers are *ai.Response values or plain
+// strings, which become the text of the response.
+type AI struct {
+	Script
+}
+
+var _ ai.Completer = (*AI)(nil)
+
+// NewAI creates an AI fake without answers
+func NewAI() *AI {
+	return &AI{}
+}
+
+// Complete answers from the script
+func (f *AI) Complete(ctx context.Context, systemPrompt, userPrompt string, temperature float64) (*ai.Response, error) {
+	response, err := f.answer(ctx, AIComplete, &Prompt{System: systemPrompt, User: userPrompt, Temperature: temperature})
+	if err != nil {
+		return nil, err
+	}
+	switch r := response.(type) {
+	case *ai.Response:
+		return r, nil
+	case string:
+		return TextResponse(r), nil
+	default:
+		return nil, fmt.Errorf("fakes: scripted %T is not an AI response", response)
+	}
+}
+
+// CompleteWithRetry answers like Complete; the fake does not retry
+func (f *AI) CompleteWithRetry(ctx context.Context, systemPrompt, userPrompt string, temperature float64, maxRetries int) (*ai.Response, error) {
+	return f.Complete(ctx, systemPrompt, userPrompt, temperature)
+}
+
+// Reply queues text answers, e.g. the JSON a prompt asks for
+func (f *AI) Reply(texts ...string) *AI {
+	for _, text := range texts {
+		f.Then(AIComplete, Respond(text))
+	}
+	return f
+}
+
+// Prompts returns the prompts sent so far
+func (f *AI) Prompts() []*Prompt {
+	var prompts []*Prompt
+	for _, c := range f.Calls(AIComplete) {
+		prompts = append(prompts, c.Request.(*Prompt))
+	}
+	return prompts
+}
+
+// TextResponse returns a model response with a single text block
+func TextResponse(text string) *ai.Response {
+	return &ai.Response{
+		Type:       "message",
+		Role:       "assistant",
+		Content:    []ai.ContentBlock{{Type: "text", Text: text}},
+		Model:      "fake",
+		StopReason: "end_turn",
+		Usage:      ai.Usage{InputTokens: 100, OutputTokens: len(text) / 4},
+	}
+}
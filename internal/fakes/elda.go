@@ -0,0 +1,80 @@
+package fakes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"austrian-business-infrastructure/internal/elda"
+)
+
+// ELDA is an in-memory elda.Caller. Operations are the SOAP actions, e.g.
+// SubmitAnmeldung or SubmitMBGM. Nothing is answered by default.
+type ELDA struct {
+	Script
+	// MaxRetries is how often CallWithRetry repeats a transient failure,
+	// without waiting in between; elda.DefaultMaxRetries by default
+	MaxRetries int
+}
+
+var _ elda.Caller = (*ELDA)(nil)
+
+// NewELDA creates an ELDA fake
+func NewELDA() *ELDA {
+	return &ELDA{MaxRetries: elda.DefaultMaxRetries}
+}
+
+// Call answers a single attempt from the script
+func (f *ELDA) Call(ctx context.Context, action string, request interface{}, response interface{}) error {
+	return f.call(ctx, action, request, response)
+}
+
+// CallWithRetry answers from the script like the client retries: transient
+// errors are repeated with the next answer, up to MaxRetries times
+func (f *ELDA) CallWithRetry(ctx context.Context, action string, request interface{}, response interface{}) error {
+	var err error
+	for i := 0; i <= f.MaxRetries; i++ {
+		if err = f.call(ctx, action, request, response); err == nil {
+			return nil
+		}
+		if !elda.IsRetryable(err) && !errors.Is(err, elda.ErrELDAConnection) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return fmt.Errorf("ELDA request failed after %d retries: %w", f.MaxRetries, err)
+}
+
+// AcceptMeldung scripts the next An-, Ab- or Änderungsmeldung of an action
+// as accepted under a Protokollnummer
+func (f *ELDA) AcceptMeldung(action, protokollnummer string) *ELDA {
+	f.Then(action, Respond(&elda.MeldungResponse{Erfolg: true, Protokollnummer: protokollnummer}))
+	return f
+}
+
+// RejectMeldung scripts the next meldung of an action as rejected
+func (f *ELDA) RejectMeldung(action, code, message string) *ELDA {
+	f.Then(action, Respond(&elda.MeldungResponse{ErrorCode: code, ErrorMessage: message}))
+	return f
+}
+
+// Unreachable scripts n connection failures of an action
+func (f *ELDA) Unreachable(action string, n int) *ELDA {
+	for i := 0; i < n; i++ {
+		f.Then(action, Fail(fmt.Errorf("%w: connection refused", elda.ErrELDAConnection)))
+	}
+	return f
+}
+
+// Protokoll scripts the Rückmeldung of the next ProtokollAbfrage, e.g.
+// elda.ProtokollAbgelehnt with the errors of the Protokoll
+func (f *ELDA) Protokoll(protokollnummer, status string, fehler ...elda.ProtokollFehler) *ELDA {
+	resp := &elda.ProtokollAbfrageResponse{Protokollnummer: protokollnummer, Status: status, Fehler: fehler}
+	if status != elda.ProtokollInBearbeitung {
+		resp.VerarbeitetAm = "2026-10-16T09:30:00"
+	}
+	f.Then("ProtokollAbfrage", Respond(resp))
+	return f
+}
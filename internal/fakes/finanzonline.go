@@ -0,0 +1,81 @@
+package fakes
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"austrian-business-infrastructure/internal/fonws"
+)
+
+// FinanzOnline operations, named after the request types
+const (
+	FONLogin       = "Login"
+	FONLogout      = "Logout"
+	FONDataboxInfo = "GetDataboxInfo"
+	FONDatabox     = "GetDatabox"
+	FONUIDAbfrage  = "UIDAbfrage"
+	FONFileUpload  = "FileUpload"
+)
+
+// FinanzOnline is an in-memory fonws.Caller. By default logins succeed,
+// uploads are accepted with a new Belegnummer, UIDs with a valid format are
+// confirmed and the databox is empty.
+type FinanzOnline struct {
+	Script
+	seq atomic.Int64
+}
+
+var _ fonws.Caller = (*FinanzOnline)(nil)
+
+// NewFinanzOnline creates a FinanzOnline fake with the default answers
+func NewFinanzOnline() *FinanzOnline {
+	f := &FinanzOnline{}
+	f.Always(FONLogin, Answer{Handle: func(interface{}) (interface{}, error) {
+		return &fonws.LoginResponse{ID: fmt.Sprintf("FAKE-SESSION-%04d", f.seq.Add(1))}, nil
+	}})
+	f.Always(FONLogout, Respond(&fonws.LogoutResponse{}))
+	f.Always(FONFileUpload, Answer{Handle: func(interface{}) (interface{}, error) {
+		return &fonws.FileUploadResponse{Belegnummer: fmt.Sprintf("FAKE-%08d", f.seq.Add(1))}, nil
+	}})
+	f.Always(FONUIDAbfrage, Answer{Handle: func(request interface{}) (interface{}, error) {
+		req := request.(*fonws.UIDAbfrageRequest)
+		if !fonws.ValidateUIDFormat(req.UIDTN).Valid {
+			return &fonws.UIDAbfrageResponse{RC: 1, UIDTN: req.UIDTN, Gueltig: "false", Msg: "UID ungültig"}, nil
+		}
+		resp := &fonws.UIDAbfrageResponse{UIDTN: req.UIDTN, Gueltig: "true"}
+		if req.Stufe == 2 {
+			resp.AbfrageNr = fmt.Sprintf("FAKE-U%08d", f.seq.Add(1))
+		}
+		return resp, nil
+	}})
+	f.Always(FONDataboxInfo, Respond(&fonws.GetDataboxInfoResponse{}))
+	return f
+}
+
+// Call answers a SOAP call from the script
+func (f *FinanzOnline) Call(url string, request interface{}, response interface{}) error {
+	return f.call(context.Background(), operationName(request), request, response)
+}
+
+// RejectLogin makes the next login fail with a FinanzOnline return code,
+// e.g. fonws.ErrCodeInvalidCredentials
+func (f *FinanzOnline) RejectLogin(rc int, msg string) *FinanzOnline {
+	f.Then(FONLogin, Respond(&fonws.LoginResponse{RC: rc, Msg: msg}))
+	return f
+}
+
+// RejectUpload makes the next file upload fail with a return code
+func (f *FinanzOnline) RejectUpload(rc int, msg string) *FinanzOnline {
+	f.Then(FONFileUpload, Respond(&fonws.FileUploadResponse{RC: rc, Msg: msg}))
+	return f
+}
+
+// Uploads returns the file upload requests sent so far
+func (f *FinanzOnline) Uploads() []*fonws.FileUploadRequest {
+	var uploads []*fonws.FileUploadRequest
+	for _, c := range f.Calls(FONFileUpload) {
+		uploads = append(uploads, c.Request.(*fonws.FileUploadRequest))
+	}
+	return uploads
+}
@@ -0,0 +1,132 @@
+package fakes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"austrian-business-infrastructure/internal/fb"
+)
+
+// Firmenbuch operations
+const (
+	FBSearch  = "Search"
+	FBExtract = "Extract"
+)
+
+// ErrCompanyNotFound is returned for an FN that was not added to the fake
+var ErrCompanyNotFound = errors.New("fakes: company not in Firmenbuch")
+
+// Firmenbuch is an in-memory fb.Registry. By default it answers from the
+// companies added with Add: searches match the full text and the filters of
+// the request, extracts are looked up by FN.
+type Firmenbuch struct {
+	Script
+	mu        sync.Mutex
+	companies []*fb.FBExtract
+}
+
+var _ fb.Registry = (*Firmenbuch)(nil)
+
+// NewFirmenbuch creates a Firmenbuch fake with the given companies
+func NewFirmenbuch(companies ...*fb.FBExtract) *Firmenbuch {
+	f := &Firmenbuch{}
+	f.Add(companies...)
+	f.Always(FBSearch, Answer{Handle: func(request interface{}) (interface{}, error) {
+		return f.search(request.(*fb.FBSearchRequest)), nil
+	}})
+	f.Always(FBExtract, Answer{Handle: func(request interface{}) (interface{}, error) {
+		fn := request.(string)
+		if c := f.find(fn); c != nil {
+			return c, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrCompanyNotFound, fn)
+	}})
+	return f
+}
+
+// Add registers companies
+func (f *Firmenbuch) Add(companies ...*fb.FBExtract) *Firmenbuch {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.companies = append(f.companies, companies...)
+	return f
+}
+
+// Search answers a search from the script
+func (f *Firmenbuch) Search(req *fb.FBSearchRequest) (*fb.FBSearchResponse, error) {
+	var resp fb.FBSearchResponse
+	if err := f.call(context.Background(), FBSearch, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Extract answers an extract from the script; invalid FNs fail like the client
+func (f *Firmenbuch) Extract(fn string) (*fb.FBExtract, error) {
+	if err := fb.ValidateFN(fn); err != nil {
+		return nil, fmt.Errorf("invalid FN: %w", err)
+	}
+	var extract fb.FBExtract
+	if err := f.call(context.Background(), FBExtract, fn, &extract); err != nil {
+		return nil, err
+	}
+	return &extract, nil
+}
+
+func (f *Firmenbuch) find(fn string) *fb.FBExtract {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, c := range f.companies {
+		if c.FN == fn {
+			return c
+		}
+	}
+	return nil
+}
+
+func (f *Firmenbuch) search(req *fb.FBSearchRequest) *fb.FBSearchResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &fb.FBSearchResponse{}
+	for _, c := range f.companies {
+		if !matchesSearch(c, req) {
+			continue
+		}
+		resp.TotalCount++
+		if resp.TotalCount <= req.Start || (req.MaxHits > 0 && len(resp.Results) == req.MaxHits) {
+			continue
+		}
+		resp.Results = append(resp.Results, fb.FBSearchResult{
+			FN: c.FN, Firma: c.Firma, Rechtsform: c.Rechtsform, Sitz: c.Sitz, Status: c.Status,
+			UID: c.UID, Adresse: c.Adresse,
+		})
+	}
+	return resp
+}
+
+// matchesSearch reports whether a company matches all filters of req; each
+// word of the full text must occur in the name, FN, UID or address
+func matchesSearch(c *fb.FBExtract, req *fb.FBSearchRequest) bool {
+	contains := func(s, sub string) bool {
+		return strings.Contains(strings.ToLower(s), strings.ToLower(sub))
+	}
+	text := strings.Join([]string{c.Firma, c.FN, c.UID, c.Sitz, c.Adresse.Strasse, c.Adresse.PLZ, c.Adresse.Ort}, " ")
+	for _, word := range strings.Fields(req.Suchbegriff) {
+		if !contains(text, word) {
+			return false
+		}
+	}
+	switch {
+	case req.FN != "" && c.FN != req.FN,
+		req.Name != "" && !contains(c.Firma, req.Name),
+		req.UID != "" && !strings.EqualFold(c.UID, req.UID),
+		req.Strasse != "" && !contains(c.Adresse.Strasse, req.Strasse),
+		req.PLZ != "" && c.Adresse.PLZ != req.PLZ,
+		req.Ort != "" && !strings.EqualFold(c.Sitz, req.Ort) && !strings.EqualFold(c.Adresse.Ort, req.Ort):
+		return false
+	}
+	return true
+}
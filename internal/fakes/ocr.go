@@ -0,0 +1,63 @@
+package fakes
+
+import (
+	"context"
+
+	"austrian-business-infrastructure/internal/ocr"
+)
+
+// OCR operations
+const (
+	OCRProcessBytes = "ProcessBytes"
+	OCRProcessImage = "ProcessImage"
+)
+
+// OCRRequest is the recorded request of an OCR call
+type OCRRequest struct {
+	Data     []byte
+	MIMEType string
+}
+
+// OCR is an in-memory ocr.Processor. Answers are *ocr.Result values.
+type OCR struct {
+	Script
+}
+
+var _ ocr.Processor = (*OCR)(nil)
+
+// NewOCR creates an OCR fake without answers
+func NewOCR() *OCR {
+	return &OCR{}
+}
+
+// ProcessBytes answers OCR of a PDF from the script
+func (f *OCR) ProcessBytes(ctx context.Context, data []byte) (*ocr.Result, error) {
+	var result ocr.Result
+	if err := f.call(ctx, OCRProcessBytes, &OCRRequest{Data: data, MIMEType: ocr.MIMEPDF}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ProcessImage answers OCR of an image from the script
+func (f *OCR) ProcessImage(ctx context.Context, data []byte, mimeType string) (*ocr.Result, error) {
+	var result ocr.Result
+	if err := f.call(ctx, OCRProcessImage, &OCRRequest{Data: data, MIMEType: mimeType}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Recognize makes every OCR call return the text as a single page with a
+// confidence
+func (f *OCR) Recognize(text string, confidence float64) *OCR {
+	result := Answer{Response: &ocr.Result{
+		Text:       text,
+		Provider:   ocr.ProviderTesseract,
+		Confidence: confidence,
+		PageTexts:  []string{text},
+	}}
+	f.Always(OCRProcessBytes, result)
+	f.Always(OCRProcessImage, result)
+	return f
+}
@@ -0,0 +1,191 @@
+// Package fakes provides in-memory implementations of the external client
+// interfaces (FinanzOnline, ELDA, Firmenbuch, AI, OCR) for tests. Each fake
+// answers from a Script: per operation, queued answers are used once and in
+// order, then the operation falls back to its default answer. Every call is
+// recorded, so tests can assert what was sent.
+package fakes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrNotScripted is returned for an operation without queued or default answer
+var ErrNotScripted = errors.New("fakes: operation not scripted")
+
+// Answer is a scripted reply to one call
+type Answer struct {
+	// Response is copied into the caller's response; a value or a pointer of
+	// the response type
+	Response interface{}
+	// Err is returned instead of a response
+	Err error
+	// Handle computes the response from the request; it takes precedence
+	// over Response and Err
+	Handle func(request interface{}) (interface{}, error)
+	// Delay is waited before answering, unless the context ends first
+	Delay time.Duration
+}
+
+// Respond answers with a response
+func Respond(response interface{}) Answer {
+	return Answer{Response: response}
+}
+
+// Fail answers with an error
+func Fail(err error) Answer {
+	return Answer{Err: err}
+}
+
+// Call is a recorded call of a fake
+type Call struct {
+	Operation string
+	Request   interface{}
+	At        time.Time
+}
+
+// Script holds the scripted answers and recorded calls of a fake. The zero
+// value is ready to use and safe for concurrent calls.
+type Script struct {
+	mu       sync.Mutex
+	queued   map[string][]Answer
+	defaults map[string]Answer
+	calls    []Call
+}
+
+// Then queues answers for the next calls of an operation
+func (s *Script) Then(operation string, answers ...Answer) *Script {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.queued == nil {
+		s.queued = make(map[string][]Answer)
+	}
+	s.queued[operation] = append(s.queued[operation], answers...)
+	return s
+}
+
+// Always sets the answer of an operation once its queue is used up
+func (s *Script) Always(operation string, answer Answer) *Script {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.defaults == nil {
+		s.defaults = make(map[string]Answer)
+	}
+	s.defaults[operation] = answer
+	return s
+}
+
+// Calls returns the recorded calls of an operation, or of all operations
+// for an empty name
+func (s *Script) Calls(operation string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var calls []Call
+	for _, c := range s.calls {
+		if operation == "" || c.Operation == operation {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Pending returns the number of queued answers not used yet, so that tests
+// can assert that a scenario ran to the end
+func (s *Script) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, q := range s.queued {
+		n += len(q)
+	}
+	return n
+}
+
+// Reset drops queued answers and recorded calls; defaults are kept
+func (s *Script) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queued = nil
+	s.calls = nil
+}
+
+// answer records a call and returns its response
+func (s *Script) answer(ctx context.Context, operation string, request interface{}) (interface{}, error) {
+	s.mu.Lock()
+	s.calls = append(s.calls, Call{Operation: operation, Request: request, At: time.Now()})
+	a, ok := s.defaults[operation]
+	if q := s.queued[operation]; len(q) > 0 {
+		a, ok = q[0], true
+		s.queued[operation] = q[1:]
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotScripted, operation)
+	}
+	if a.Delay > 0 {
+		select {
+		case <-time.After(a.Delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if a.Handle != nil {
+		return a.Handle(request)
+	}
+	return a.Response, a.Err
+}
+
+// call answers an operation and copies the response into out
+func (s *Script) call(ctx context.Context, operation string, request, out interface{}) error {
+	response, err := s.answer(ctx, operation, request)
+	if err != nil {
+		return err
+	}
+	return assign(out, response)
+}
+
+// assign copies a scripted response into the pointer out. A nil response
+// leaves out at its zero value.
+func assign(out, response interface{}) error {
+	dst := reflect.ValueOf(out)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() {
+		return fmt.Errorf("fakes: response target %T is not a pointer", out)
+	}
+	if response == nil {
+		return nil
+	}
+	src := reflect.ValueOf(response)
+	if src.Kind() == reflect.Ptr && src.Type() != dst.Elem().Type() {
+		if src.IsNil() {
+			return nil
+		}
+		src = src.Elem()
+	}
+	if !src.Type().AssignableTo(dst.Elem().Type()) {
+		return fmt.Errorf("fakes: scripted %T does not fit response %T", response, out)
+	}
+	dst.Elem().Set(src)
+	return nil
+}
+
+// operationName is the type name of a request without a Request suffix,
+// e.g. Login for fonws.LoginRequest
+func operationName(request interface{}) string {
+	t := reflect.TypeOf(request)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return ""
+	}
+	name := t.Name()
+	if n := len(name) - len("Request"); n > 0 && name[n:] == "Request" {
+		return name[:n]
+	}
+	return name
+}
@@ -7,6 +7,8 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"austrian-business-infrastructure/pkg/httpclient"
 )
 
 const (
@@ -24,10 +26,19 @@ const (
 type Client struct {
 	endpoint   string
 	apiKey     string
-	httpClient *http.Client
+	httpClient *httpclient.Client
 	timeout    time.Duration
 }
 
+// Registry looks up companies in the Firmenbuch. *Client implements it
+// against the Justiz web service; internal/fakes provides an in-memory one.
+type Registry interface {
+	Search(req *FBSearchRequest) (*FBSearchResponse, error)
+	Extract(fn string) (*FBExtract, error)
+}
+
+var _ Registry = (*Client)(nil)
+
 // NewClient creates a new Firmenbuch client
 func NewClient(apiKey string, testMode bool) *Client {
 	endpoint := FBEndpoint
@@ -35,13 +46,17 @@ func NewClient(apiKey string, testMode bool) *Client {
 		endpoint = FBTestEndpoint
 	}
 
+	// Searches and extracts are reads, so transient failures are retried
+	httpCfg := httpclient.DefaultConfig("firmenbuch")
+	httpCfg.MaxRetries = 2
+	httpCfg.Budget = 90 * time.Second
+	httpCfg.Hooks.AfterAttempt = httpclient.LogAttempts(nil)
+
 	return &Client{
-		endpoint: endpoint,
-		apiKey:   apiKey,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		timeout: 30 * time.Second,
+		endpoint:   endpoint,
+		apiKey:     apiKey,
+		httpClient: httpclient.New(httpCfg),
+		timeout:    30 * time.Second,
 	}
 }
 
@@ -75,13 +75,19 @@ type FBAdresse struct {
 	Land    string `json:"land" xml:"Land"`
 }
 
-// FBSearchRequest represents a search request to the Firmenbuch
+// FBSearchRequest represents a search request to the Firmenbuch. The
+// filters are combined; Start and MaxHits page through the hits.
 type FBSearchRequest struct {
-	XMLName xml.Name `xml:"FBSuche"`
-	Name    string   `xml:"Name,omitempty"`
-	FN      string   `xml:"FN,omitempty"`
-	Ort     string   `xml:"Ort,omitempty"`
-	MaxHits int      `xml:"MaxHits,omitempty"`
+	XMLName     xml.Name `xml:"FBSuche"`
+	Suchbegriff string   `xml:"Suchbegriff,omitempty"` // Full text over name, FN, UID and address
+	Name        string   `xml:"Name,omitempty"`
+	FN          string   `xml:"FN,omitempty"`
+	UID         string   `xml:"UID,omitempty"`
+	Strasse     string   `xml:"Strasse,omitempty"`
+	PLZ         string   `xml:"PLZ,omitempty"`
+	Ort         string   `xml:"Ort,omitempty"`
+	Start       int      `xml:"Start,omitempty"` // Offset of the first hit
+	MaxHits     int      `xml:"MaxHits,omitempty"`
 }
 
 // FBSearchResult represents a single search result
@@ -91,6 +97,8 @@ type FBSearchResult struct {
 	Rechtsform Rechtsform `json:"rechtsform" xml:"Rechtsform"`
 	Sitz      string     `json:"sitz" xml:"Sitz"`
 	Status    FBStatus   `json:"status" xml:"Status"`
+	UID       string     `json:"uid,omitempty" xml:"UID"`
+	Adresse   FBAdresse  `json:"adresse" xml:"Adresse"`
 }
 
 // FBSearchResponse represents the response from a Firmenbuch search
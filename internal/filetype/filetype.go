@@ -0,0 +1,373 @@
+// Package filetype identifies uploaded files by their content and checks
+// them against the file types and limits an endpoint accepts, before any
+// expensive processing (OCR, AI analysis, signing) starts.
+package filetype
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // DecodeConfig for JPEG
+	_ "image/png"  // DecodeConfig for PNG
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// MIME types recognized from the content
+const (
+	PDF  = "application/pdf"
+	DOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	XLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	DOC  = "application/msword"
+	XLS  = "application/vnd.ms-excel"
+	JPEG = "image/jpeg"
+	PNG  = "image/png"
+	HEIC = "image/heic"
+	ZIP  = "application/zip"
+
+	// OLE is an OLE2 compound file (legacy Office documents, Outlook
+	// messages); the content alone does not tell which
+	OLE = "application/x-ole-storage"
+
+	Unknown = "application/octet-stream"
+)
+
+// Check errors
+var (
+	ErrNotAllowed        = errors.New("file type not allowed")
+	ErrExtensionMismatch = errors.New("file extension does not match its content")
+	ErrTypeMismatch      = errors.New("declared content type does not match the content")
+	ErrUnreadable        = errors.New("file could not be read")
+	ErrTooManyPages      = errors.New("document has too many pages")
+	ErrTooLarge          = errors.New("page or image dimensions too large")
+)
+
+// sniffLen is how much of a file is read to recognize it; the ispe box of a
+// HEIC image comes within the first few KiB
+const sniffLen = 64 << 10
+
+var oleSignature = []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}
+
+// extensions maps file extensions to the type a file named so must have
+var extensions = map[string]string{
+	".pdf":  PDF,
+	".docx": DOCX,
+	".xlsx": XLSX,
+	".doc":  DOC,
+	".xls":  XLS,
+	".jpg":  JPEG,
+	".jpeg": JPEG,
+	".png":  PNG,
+	".heic": HEIC,
+	".heif": HEIC,
+	".zip":  ZIP,
+}
+
+// Sniff determines the type of a file from its content alone. Unknown
+// binary content is Unknown; text is reported as net/http detects it,
+// without parameters.
+func Sniff(data []byte) string {
+	return sniff(bytes.NewReader(data), int64(len(data)))
+}
+
+func sniff(r io.ReaderAt, size int64) string {
+	head := make([]byte, min(size, sniffLen))
+	n, _ := r.ReadAt(head, 0)
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return PDF
+	case isHEIC(head):
+		return HEIC
+	case bytes.HasPrefix(head, oleSignature):
+		return OLE
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		if t := detectOOXML(r, size); t != "" {
+			return t
+		}
+		return ZIP
+	}
+
+	detected, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	return detected
+}
+
+// isHEIC checks the ftyp box of HEIF images with HEVC coding
+func isHEIC(data []byte) bool {
+	if len(data) < 12 || string(data[4:8]) != "ftyp" {
+		return false
+	}
+	switch string(data[8:12]) {
+	case "heic", "heix", "hevc", "hevx", "heim", "heis", "mif1", "msf1":
+		return true
+	}
+	return false
+}
+
+// detectOOXML tells Word documents and workbooks apart by their main part
+func detectOOXML(r io.ReaderAt, size int64) string {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return ""
+	}
+	for _, f := range zr.File {
+		switch f.Name {
+		case "word/document.xml":
+			return DOCX
+		case "xl/workbook.xml":
+			return XLSX
+		}
+	}
+	return ""
+}
+
+// ExtensionType returns the type a file name stands for, "" if its
+// extension is not one of the recognized ones
+func ExtensionType(filename string) string {
+	return extensions[strings.ToLower(filepath.Ext(filename))]
+}
+
+// Policy is the file types and limits an upload endpoint accepts
+type Policy struct {
+	// Types that are accepted
+	Types []string
+
+	// MaxPages limits the pages of a PDF, 0 for no limit
+	MaxPages int
+	// MaxPageSize limits the width and height of a PDF page in points
+	MaxPageSize float64
+	// MaxDimension limits the width and height of an image in pixels
+	MaxDimension int
+	// MaxPixels limits width × height of an image
+	MaxPixels int64
+}
+
+// Limits shared by the policies: the largest PDF page is 200 inches wide,
+// and 50 megapixels is already more than OCR makes use of
+const (
+	maxPageSize  = 14400
+	maxDimension = 20000
+	maxPixels    = 50_000_000
+)
+
+// Upload policies
+var (
+	// Analysis accepts what text can be extracted from in an analysis
+	Analysis = Policy{
+		Types:        []string{PDF, DOCX, JPEG, PNG, HEIC},
+		MaxPages:     300,
+		MaxPageSize:  maxPageSize,
+		MaxDimension: maxDimension,
+		MaxPixels:    maxPixels,
+	}
+
+	// Signature accepts PDFs only, the format that is signed
+	Signature = Policy{
+		Types:       []string{PDF},
+		MaxPages:    500,
+		MaxPageSize: maxPageSize,
+	}
+
+	// PortalUpload accepts the documents clients send through the portal
+	PortalUpload = Policy{
+		Types:        []string{PDF, JPEG, PNG, DOC, DOCX, XLS, XLSX},
+		MaxPages:     500,
+		MaxPageSize:  maxPageSize,
+		MaxDimension: maxDimension,
+		MaxPixels:    maxPixels,
+	}
+
+	// Ingestion accepts the documents connected systems submit through the
+	// ingestion API, scans and photos included
+	Ingestion = Policy{
+		Types:        []string{PDF, JPEG, PNG, HEIC, DOC, DOCX, XLS, XLSX},
+		MaxPages:     500,
+		MaxPageSize:  maxPageSize,
+		MaxDimension: maxDimension,
+		MaxPixels:    maxPixels,
+	}
+)
+
+// Info describes a file that passed a Check
+type Info struct {
+	MIMEType string
+	Pages    int // PDFs only
+	Width    int // images only, in pixels
+	Height   int
+}
+
+// Allows reports whether the policy accepts the type
+func (p Policy) Allows(mimeType string) bool {
+	for _, t := range p.Types {
+		if t == mimeType {
+			return true
+		}
+	}
+	return false
+}
+
+// Check identifies a file by its content and checks it against the policy.
+// The client's Content-Type is not consulted. A recognized extension must
+// match the content, so a PNG named .pdf or a PDF named .docx is rejected.
+// Legacy Office files are recognized as OLE2 files and take their type from
+// the extension.
+func (p Policy) Check(filename string, r io.ReaderAt, size int64) (*Info, error) {
+	detected := sniff(r, size)
+	ext := ExtensionType(filename)
+
+	if detected == OLE && (ext == DOC || ext == XLS) {
+		detected = ext
+	}
+	if ext != "" && ext != detected {
+		return nil, fmt.Errorf("%w: %s is %s", ErrExtensionMismatch, filepath.Ext(filename), detected)
+	}
+	return p.check(detected, r, size)
+}
+
+// CheckDeclared is Check for content without a file name whose sender
+// declared its type, e.g. in a Content-Type header. A declared type must
+// match the content, and legacy Office files take their type from it. An
+// empty or generic declaration (application/octet-stream) leaves the type
+// to the content.
+func (p Policy) CheckDeclared(declared string, r io.ReaderAt, size int64) (*Info, error) {
+	detected := sniff(r, size)
+	if declared == Unknown {
+		declared = ""
+	}
+
+	if detected == OLE && (declared == DOC || declared == XLS) {
+		detected = declared
+	}
+	if declared != "" && declared != detected {
+		return nil, fmt.Errorf("%w: %s is %s", ErrTypeMismatch, declared, detected)
+	}
+	return p.check(detected, r, size)
+}
+
+// CheckBytesDeclared is CheckDeclared for content in memory
+func (p Policy) CheckBytesDeclared(declared string, data []byte) (*Info, error) {
+	return p.CheckDeclared(declared, bytes.NewReader(data), int64(len(data)))
+}
+
+// check checks content of the detected type against the policy
+func (p Policy) check(detected string, r io.ReaderAt, size int64) (*Info, error) {
+	if !p.Allows(detected) {
+		return nil, fmt.Errorf("%w: %s", ErrNotAllowed, detected)
+	}
+
+	info := &Info{MIMEType: detected}
+	var err error
+	switch detected {
+	case PDF:
+		err = p.checkPDF(info, io.NewSectionReader(r, 0, size))
+	case JPEG, PNG, HEIC:
+		err = p.checkImage(info, r, size)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// CheckBytes is Check for a file in memory
+func (p Policy) CheckBytes(filename string, data []byte) (*Info, error) {
+	return p.Check(filename, bytes.NewReader(data), int64(len(data)))
+}
+
+// checkPDF reads the page tree without rendering anything. The declared page
+// count is checked first, so an oversized tree is not walked at all.
+func (p Policy) checkPDF(info *Info, rs io.ReadSeeker) error {
+	ctx, err := api.ReadContext(rs, model.NewDefaultConfiguration())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnreadable, err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnreadable, err)
+	}
+	if p.MaxPages > 0 && ctx.PageCount > p.MaxPages {
+		return fmt.Errorf("%w: %d, at most %d", ErrTooManyPages, ctx.PageCount, p.MaxPages)
+	}
+
+	dims, err := ctx.PageDims()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnreadable, err)
+	}
+	if p.MaxPages > 0 && len(dims) > p.MaxPages {
+		return fmt.Errorf("%w: %d, at most %d", ErrTooManyPages, len(dims), p.MaxPages)
+	}
+	for i, d := range dims {
+		if p.MaxPageSize > 0 && (d.Width > p.MaxPageSize || d.Height > p.MaxPageSize) {
+			return fmt.Errorf("%w: page %d is %.0f×%.0f pt", ErrTooLarge, i+1, d.Width, d.Height)
+		}
+	}
+	info.Pages = len(dims)
+	return nil
+}
+
+// checkImage reads the dimensions from the image header
+func (p Policy) checkImage(info *Info, r io.ReaderAt, size int64) error {
+	if info.MIMEType == HEIC {
+		head := make([]byte, min(size, sniffLen))
+		n, _ := r.ReadAt(head, 0)
+		w, h, ok := heicDimensions(head[:n])
+		if !ok {
+			return fmt.Errorf("%w: no image size in HEIC header", ErrUnreadable)
+		}
+		info.Width, info.Height = w, h
+	} else {
+		cfg, _, err := image.DecodeConfig(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUnreadable, err)
+		}
+		info.Width, info.Height = cfg.Width, cfg.Height
+	}
+
+	if p.MaxDimension > 0 && (info.Width > p.MaxDimension || info.Height > p.MaxDimension) ||
+		p.MaxPixels > 0 && int64(info.Width)*int64(info.Height) > p.MaxPixels {
+		return fmt.Errorf("%w: image is %d×%d px", ErrTooLarge, info.Width, info.Height)
+	}
+	return nil
+}
+
+// heicDimensions returns the largest image spatial extent (ispe) property
+// in the header; a HEIC file lists one per image item and tile
+func heicDimensions(data []byte) (width, height int, ok bool) {
+	box := []byte("ispe")
+	for i := bytes.Index(data, box); i >= 0; {
+		// ispe: version and flags, then width and height as uint32
+		if p := i + len(box) + 4; p+8 <= len(data) {
+			w := int(binary.BigEndian.Uint32(data[p:]))
+			h := int(binary.BigEndian.Uint32(data[p+4:]))
+			if w*h > width*height {
+				width, height, ok = w, h, true
+			}
+		}
+		next := bytes.Index(data[i+len(box):], box)
+		if next < 0 {
+			break
+		}
+		i += len(box) + next
+	}
+	return width, height, ok
+}
+
+// HTTPStatus is the response status for a failed Check: 415 for a rejected
+// type, 422 for a file over the limits and 400 for one that cannot be read
+func HTTPStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrNotAllowed), errors.Is(err, ErrExtensionMismatch), errors.Is(err, ErrTypeMismatch):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, ErrTooManyPages), errors.Is(err, ErrTooLarge):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,131 @@
+package firmenbuch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/fb"
+)
+
+// Change types of the company history
+const (
+	ChangeGeschaeftsfuehrer = "geschaeftsfuehrer"
+	ChangeKapital           = "kapital"
+	ChangeSitz              = "sitz"
+	ChangeInsolvenz         = "insolvenz"
+	ChangeStatus            = "status_change"
+)
+
+// Officer is a current Geschäftsführer or Vorstand in a history entry
+type Officer struct {
+	Name           string `json:"name"`
+	Funktion       string `json:"funktion"`
+	VertretungsArt string `json:"vertretungsart,omitempty"`
+}
+
+// Capital is the share capital in a history entry
+type Capital struct {
+	Stammkapital float64 `json:"stammkapital"`
+	Waehrung     string  `json:"waehrung,omitempty"`
+}
+
+// Diff compares two extracts of a company and returns a history entry for
+// each change of the Geschäftsführer, the share capital, the seat and the
+// status. A change to or from insolvent is an Insolvenz; other status
+// changes, such as a deletion, are a status change.
+func Diff(old, current *fb.FBExtract, detectedAt time.Time) []*HistoryEntry {
+	var changes []*HistoryEntry
+	add := func(changeType string, oldValue, newValue interface{}, summary string) {
+		entry := &HistoryEntry{ChangeType: changeType, Summary: summary, DetectedAt: detectedAt}
+		entry.OldValue, _ = json.Marshal(oldValue)
+		entry.NewValue, _ = json.Marshal(newValue)
+		changes = append(changes, entry)
+	}
+
+	oldOfficers, newOfficers := officers(old), officers(current)
+	if added, removed := officerDiff(oldOfficers, newOfficers); len(added) > 0 || len(removed) > 0 {
+		var parts []string
+		if len(added) > 0 {
+			parts = append(parts, "neu: "+strings.Join(added, ", "))
+		}
+		if len(removed) > 0 {
+			parts = append(parts, "ausgeschieden: "+strings.Join(removed, ", "))
+		}
+		add(ChangeGeschaeftsfuehrer, oldOfficers, newOfficers, "Geschäftsführung "+strings.Join(parts, "; "))
+	}
+
+	if old.Stammkapital != current.Stammkapital || old.Waehrung != current.Waehrung {
+		add(ChangeKapital,
+			Capital{Stammkapital: old.StammkapitalEUR(), Waehrung: old.Waehrung},
+			Capital{Stammkapital: current.StammkapitalEUR(), Waehrung: current.Waehrung},
+			fmt.Sprintf("Stammkapital von %s auf %s", formatCapital(old), formatCapital(current)))
+	}
+
+	if old.Sitz != current.Sitz {
+		add(ChangeSitz, map[string]string{"sitz": old.Sitz}, map[string]string{"sitz": current.Sitz},
+			fmt.Sprintf("Sitz von %s nach %s verlegt", old.Sitz, current.Sitz))
+	}
+
+	if old.Status != current.Status {
+		oldStatus := map[string]string{"status": string(old.Status)}
+		newStatus := map[string]string{"status": string(current.Status)}
+		switch {
+		case current.Status == fb.FBStatusInsolvent:
+			add(ChangeInsolvenz, oldStatus, newStatus, "Insolvenzverfahren eröffnet")
+		case old.Status == fb.FBStatusInsolvent:
+			add(ChangeInsolvenz, oldStatus, newStatus, fmt.Sprintf("Insolvenz beendet, Status %s", current.Status))
+		default:
+			add(ChangeStatus, oldStatus, newStatus, fmt.Sprintf("Status von %s auf %s geändert", old.Status, current.Status))
+		}
+	}
+
+	return changes
+}
+
+// officers returns the current Geschäftsführer and Vorstände of an extract
+func officers(extract *fb.FBExtract) []Officer {
+	list := []Officer{}
+	for _, p := range extract.Geschaeftsfuehrer {
+		if p.Bis != nil && !p.Bis.IsZero() {
+			continue // Left the office
+		}
+		list = append(list, Officer{
+			Name:           strings.TrimSpace(p.FullName()),
+			Funktion:       string(p.Funktion),
+			VertretungsArt: string(p.VertretungsArt),
+		})
+	}
+	return list
+}
+
+// officerDiff returns the names of the officers added and removed; an
+// officer whose function or representation changed is both
+func officerDiff(old, current []Officer) (added, removed []string) {
+	inOld := make(map[Officer]bool, len(old))
+	for _, o := range old {
+		inOld[o] = true
+	}
+	inCurrent := make(map[Officer]bool, len(current))
+	for _, o := range current {
+		inCurrent[o] = true
+		if !inOld[o] {
+			added = append(added, o.Name)
+		}
+	}
+	for _, o := range old {
+		if !inCurrent[o] {
+			removed = append(removed, o.Name)
+		}
+	}
+	return added, removed
+}
+
+func formatCapital(extract *fb.FBExtract) string {
+	currency := extract.Waehrung
+	if currency == "" {
+		currency = "EUR"
+	}
+	return fmt.Sprintf("%.2f %s", extract.StammkapitalEUR(), currency)
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"austrian-business-infrastructure/internal/api"
 	"github.com/google/uuid"
@@ -43,16 +45,27 @@ func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	query := r.URL.Query()
 	input := &SearchInput{
-		Name: r.URL.Query().Get("name"),
-		FN:   r.URL.Query().Get("fn"),
-		Ort:  r.URL.Query().Get("ort"),
+		Query:   strings.TrimSpace(query.Get("q")),
+		Name:    strings.TrimSpace(query.Get("name")),
+		FN:      strings.TrimSpace(query.Get("fn")),
+		UID:     strings.TrimSpace(query.Get("uid")),
+		Strasse: strings.TrimSpace(query.Get("strasse")),
+		PLZ:     strings.TrimSpace(query.Get("plz")),
+		Ort:     strings.TrimSpace(query.Get("ort")),
 	}
 
-	if maxHitsStr := r.URL.Query().Get("max_hits"); maxHitsStr != "" {
-		if maxHits, err := strconv.Atoi(maxHitsStr); err == nil && maxHits > 0 {
-			input.MaxHits = maxHits
-		}
+	// limit is the page size; max_hits is still accepted for older clients
+	limitStr := query.Get("limit")
+	if limitStr == "" {
+		limitStr = query.Get("max_hits")
+	}
+	if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+		input.MaxHits = limit
+	}
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset > 0 {
+		input.Offset = offset
 	}
 
 	resp, err := h.service.Search(r.Context(), tenantID, input)
@@ -167,10 +180,10 @@ func (h *Handler) ListCompanies(w http.ResponseWriter, r *http.Request) {
 			"rechtsform": company.Rechtsform,
 			"sitz":       company.Sitz,
 			"status":     company.Status,
-			"created_at": company.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			"created_at": company.CreatedAt.UTC().Format(time.RFC3339),
 		}
 		if company.LastFetchedAt != nil {
-			item["last_fetched_at"] = company.LastFetchedAt.Format("2006-01-02T15:04:05Z")
+			item["last_fetched_at"] = company.LastFetchedAt.UTC().Format(time.RFC3339)
 		}
 		items = append(items, item)
 	}
@@ -249,7 +262,8 @@ func (h *Handler) GetHistory(w http.ResponseWriter, r *http.Request) {
 			ChangeType: entry.ChangeType,
 			OldValue:   entry.OldValue,
 			NewValue:   entry.NewValue,
-			DetectedAt: entry.DetectedAt.Format("2006-01-02T15:04:05Z"),
+			Summary:    entry.Summary,
+			DetectedAt: entry.DetectedAt.UTC().Format(time.RFC3339),
 		})
 	}
 
@@ -372,7 +386,11 @@ func (h *Handler) handleError(w http.ResponseWriter, err error) {
 	case ErrInvalidFN:
 		api.BadRequest(w, "invalid Firmenbuch number format (expected: FN followed by 1-9 digits and a lowercase letter, e.g., FN123456a)")
 	case ErrSearchEmpty:
-		api.BadRequest(w, "at least one search parameter (name, fn, ort) is required")
+		api.BadRequest(w, "at least one search parameter (q, name, fn, uid, strasse, plz, ort) is required")
+	case ErrInvalidUID:
+		api.BadRequest(w, "invalid UID number (expected: ATU followed by 8 digits)")
+	case ErrNotConfigured:
+		api.JSONError(w, http.StatusServiceUnavailable, "Firmenbuch lookups are not configured", api.ErrCodeServiceUnavailable)
 	case ErrAlreadyOnWatch:
 		api.Conflict(w, "company already on watchlist")
 	default:
@@ -387,11 +405,11 @@ func (h *Handler) toWatchlistResponse(entry *WatchlistEntry) WatchlistResponse {
 		Name:       entry.Name,
 		LastStatus: entry.LastStatus,
 		Notes:      entry.Notes,
-		CreatedAt:  entry.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		CreatedAt:  entry.CreatedAt.UTC().Format(time.RFC3339),
 	}
 
 	if entry.LastChecked != nil {
-		d := entry.LastChecked.Format("2006-01-02T15:04:05Z")
+		d := entry.LastChecked.UTC().Format(time.RFC3339)
 		resp.LastChecked = &d
 	}
 
@@ -10,6 +10,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/pkg/database"
 )
 
 var (
@@ -19,16 +21,20 @@ var (
 
 // Repository handles firmenbuch database operations
 type Repository struct {
-	db *pgxpool.Pool
+	db     *pgxpool.Pool
+	module database.Module
 }
 
 // NewRepository creates a new firmenbuch repository
 func NewRepository(db *pgxpool.Pool) *Repository {
-	return &Repository{db: db}
+	return &Repository{db: db, module: database.NewModule("firmenbuch")}
 }
 
 // CreateCompany creates or updates a company record
 func (r *Repository) CreateCompany(ctx context.Context, company *Company) (*Company, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	company.ID = uuid.New()
 	company.CreatedAt = time.Now()
 	company.UpdatedAt = company.CreatedAt
@@ -70,6 +76,9 @@ func (r *Repository) CreateCompany(ctx context.Context, company *Company) (*Comp
 
 // GetCompanyByFN retrieves a company by FN
 func (r *Repository) GetCompanyByFN(ctx context.Context, tenantID uuid.UUID, fn string) (*Company, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, tenant_id, fn, name, rechtsform, sitz, adresse,
 			stammkapital, waehrung, status, gruendungsdatum, uid,
@@ -126,6 +135,9 @@ func (r *Repository) GetCompanyByFN(ctx context.Context, tenantID uuid.UUID, fn
 
 // GetCompanyByID retrieves a company by ID
 func (r *Repository) GetCompanyByID(ctx context.Context, id, tenantID uuid.UUID) (*Company, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, tenant_id, fn, name, rechtsform, sitz, adresse,
 			stammkapital, waehrung, status, gruendungsdatum, uid,
@@ -182,6 +194,9 @@ func (r *Repository) GetCompanyByID(ctx context.Context, id, tenantID uuid.UUID)
 
 // ListCompanies lists cached companies
 func (r *Repository) ListCompanies(ctx context.Context, filter ListFilter) ([]*Company, int, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	baseQuery := ` FROM firmenbuch_cache WHERE tenant_id = $1`
 	args := []interface{}{filter.TenantID}
 	argIdx := 2
@@ -246,6 +261,9 @@ func (r *Repository) ListCompanies(ctx context.Context, filter ListFilter) ([]*C
 
 // AddToWatchlist adds a company to the watchlist
 func (r *Repository) AddToWatchlist(ctx context.Context, entry *WatchlistEntry) (*WatchlistEntry, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	entry.ID = uuid.New()
 	entry.CreatedAt = time.Now()
 	entry.UpdatedAt = entry.CreatedAt
@@ -277,6 +295,9 @@ func (r *Repository) AddToWatchlist(ctx context.Context, entry *WatchlistEntry)
 
 // GetWatchlistEntry retrieves a watchlist entry by FN
 func (r *Repository) GetWatchlistEntry(ctx context.Context, tenantID uuid.UUID, fn string) (*WatchlistEntry, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, tenant_id, company_id, fn, name, last_status, last_checked, notes, created_at, updated_at
 		FROM firmenbuch_watchlist
@@ -310,6 +331,9 @@ func (r *Repository) GetWatchlistEntry(ctx context.Context, tenantID uuid.UUID,
 
 // ListWatchlist lists all watchlist entries for a tenant
 func (r *Repository) ListWatchlist(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*WatchlistEntry, int, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	// Count total
 	var total int
 	countQuery := `SELECT COUNT(*) FROM firmenbuch_watchlist WHERE tenant_id = $1`
@@ -361,6 +385,9 @@ func (r *Repository) ListWatchlist(ctx context.Context, tenantID uuid.UUID, limi
 
 // UpdateWatchlistEntry updates a watchlist entry
 func (r *Repository) UpdateWatchlistEntry(ctx context.Context, entry *WatchlistEntry) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	entry.UpdatedAt = time.Now()
 
 	query := `
@@ -387,6 +414,9 @@ func (r *Repository) UpdateWatchlistEntry(ctx context.Context, entry *WatchlistE
 
 // RemoveFromWatchlist removes a company from the watchlist
 func (r *Repository) RemoveFromWatchlist(ctx context.Context, tenantID uuid.UUID, fn string) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM firmenbuch_watchlist WHERE tenant_id = $1 AND fn = $2`
 	result, err := r.db.Exec(ctx, query, tenantID, fn)
 	if err != nil {
@@ -398,19 +428,23 @@ func (r *Repository) RemoveFromWatchlist(ctx context.Context, tenantID uuid.UUID
 	return nil
 }
 
+const insertHistoryQuery = `
+	INSERT INTO firmenbuch_history (
+		id, tenant_id, company_id, change_type, old_value, new_value, summary, detected_at, created_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id`
+
 // AddHistoryEntry adds a history entry for a company
 func (r *Repository) AddHistoryEntry(ctx context.Context, entry *HistoryEntry) (*HistoryEntry, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	entry.ID = uuid.New()
 	entry.CreatedAt = time.Now()
 
-	query := `
-		INSERT INTO firmenbuch_history (
-			id, company_id, change_type, old_value, new_value, detected_at, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id`
-
-	err := r.db.QueryRow(ctx, query,
-		entry.ID, entry.CompanyID, entry.ChangeType, entry.OldValue, entry.NewValue, entry.DetectedAt, entry.CreatedAt,
+	err := r.db.QueryRow(ctx, insertHistoryQuery,
+		entry.ID, entry.TenantID, entry.CompanyID, entry.ChangeType, entry.OldValue, entry.NewValue,
+		entry.Summary, entry.DetectedAt, entry.CreatedAt,
 	).Scan(&entry.ID)
 
 	if err != nil {
@@ -422,6 +456,9 @@ func (r *Repository) AddHistoryEntry(ctx context.Context, entry *HistoryEntry) (
 
 // GetCompanyHistory retrieves history entries for a company
 func (r *Repository) GetCompanyHistory(ctx context.Context, companyID uuid.UUID, limit, offset int) ([]*HistoryEntry, int, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	// Count total
 	var total int
 	countQuery := `SELECT COUNT(*) FROM firmenbuch_history WHERE company_id = $1`
@@ -432,7 +469,7 @@ func (r *Repository) GetCompanyHistory(ctx context.Context, companyID uuid.UUID,
 
 	// Get paginated results
 	selectQuery := `
-		SELECT id, company_id, change_type, old_value, new_value, detected_at, created_at
+		SELECT id, tenant_id, company_id, change_type, old_value, new_value, summary, detected_at, created_at
 		FROM firmenbuch_history
 		WHERE company_id = $1
 		ORDER BY detected_at DESC
@@ -450,7 +487,8 @@ func (r *Repository) GetCompanyHistory(ctx context.Context, companyID uuid.UUID,
 		var oldValue, newValue sql.NullString
 
 		err := rows.Scan(
-			&entry.ID, &entry.CompanyID, &entry.ChangeType, &oldValue, &newValue, &entry.DetectedAt, &entry.CreatedAt,
+			&entry.ID, &entry.TenantID, &entry.CompanyID, &entry.ChangeType, &oldValue, &newValue,
+			&entry.Summary, &entry.DetectedAt, &entry.CreatedAt,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan history entry: %w", err)
@@ -471,6 +509,9 @@ func (r *Repository) GetCompanyHistory(ctx context.Context, companyID uuid.UUID,
 
 // GetWatchlistEntriesForCheck returns watchlist entries that need checking
 func (r *Repository) GetWatchlistEntriesForCheck(ctx context.Context, olderThan time.Time, limit int) ([]*WatchlistEntry, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, tenant_id, company_id, fn, name, last_status, last_checked, notes, created_at, updated_at
 		FROM firmenbuch_watchlist
@@ -510,3 +551,82 @@ func (r *Repository) GetWatchlistEntriesForCheck(ctx context.Context, olderThan
 
 	return entries, nil
 }
+
+// RecordCheck stores the result of a watchlist check in one transaction:
+// the refreshed company, the changes found and the check time of the entry
+func (r *Repository) RecordCheck(ctx context.Context, company *Company, entry *WatchlistEntry, changes []*HistoryEntry) error {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	if _, err := tx.Exec(ctx, `
+		UPDATE firmenbuch_cache SET
+			name = $3, rechtsform = $4, sitz = $5, adresse = $6, stammkapital = $7, waehrung = $8,
+			status = $9, gruendungsdatum = $10, uid = $11, gegenstand = $12, extract_data = $13,
+			last_fetched_at = $14, updated_at = $15
+		WHERE id = $1 AND tenant_id = $2`,
+		company.ID, company.TenantID, company.Name, company.Rechtsform, company.Sitz, company.Adresse,
+		company.Stammkapital, company.Waehrung, company.Status, company.Gruendungsdatum, company.UID,
+		company.Gegenstand, company.ExtractData, company.LastFetchedAt, now,
+	); err != nil {
+		return fmt.Errorf("failed to update company: %w", err)
+	}
+
+	for _, change := range changes {
+		change.ID = uuid.New()
+		change.CreatedAt = now
+		if err := tx.QueryRow(ctx, insertHistoryQuery,
+			change.ID, change.TenantID, change.CompanyID, change.ChangeType, change.OldValue, change.NewValue,
+			change.Summary, change.DetectedAt, change.CreatedAt,
+		).Scan(&change.ID); err != nil {
+			return fmt.Errorf("failed to add history entry: %w", err)
+		}
+	}
+
+	entry.UpdatedAt = now
+	if _, err := tx.Exec(ctx, `
+		UPDATE firmenbuch_watchlist SET name = $3, last_status = $4, last_checked = $5, updated_at = $6
+		WHERE id = $1 AND tenant_id = $2`,
+		entry.ID, entry.TenantID, entry.Name, entry.LastStatus, entry.LastChecked, entry.UpdatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to update watchlist entry: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Recipient is a tenant admin mailed about changes of watched companies
+type Recipient struct {
+	Name  string
+	Email string
+}
+
+// tenantAdmins returns the active owners and admins of a tenant
+func (r *Repository) tenantAdmins(ctx context.Context, tenantID uuid.UUID) ([]Recipient, error) {
+	ctx, cancel := r.module.WithTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.Query(ctx, `
+		SELECT name, email FROM users
+		WHERE tenant_id = $1 AND is_active AND role IN ('owner', 'admin')`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant admins: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []Recipient
+	for rows.Next() {
+		var rc Recipient
+		if err := rows.Scan(&rc.Name, &rc.Email); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant admin: %w", err)
+		}
+		recipients = append(recipients, rc)
+	}
+	return recipients, rows.Err()
+}
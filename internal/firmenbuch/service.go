@@ -2,41 +2,101 @@ package firmenbuch
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
+	"austrian-business-infrastructure/internal/email"
 	"austrian-business-infrastructure/internal/fb"
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/webhook"
 	"github.com/google/uuid"
 )
 
 var (
 	ErrInvalidFN        = errors.New("invalid Firmenbuch number format")
 	ErrSearchEmpty      = errors.New("at least one search parameter required")
+	ErrInvalidUID       = errors.New("invalid Austrian UID number")
+	ErrNotConfigured    = errors.New("Firmenbuch web service not configured")
 	ErrAlreadyOnWatch   = errors.New("company already on watchlist")
 )
 
 // CacheDuration is how long to use cached data before refreshing
 const CacheDuration = 24 * time.Hour
 
+// Search paging defaults
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// SearchCache keeps search responses; cache.Store implements it
+type SearchCache interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// Notifier mails the tenant admins about changes of watched companies;
+// email.Service implements it
+type Notifier interface {
+	SendFirmenbuchChange(ctx context.Context, to string, params email.FirmenbuchChangeParams) error
+}
+
+// EventPublisher delivers webhook events; webhook.Service implements it
+type EventPublisher interface {
+	TriggerEvent(ctx context.Context, tenantID uuid.UUID, eventType string, data interface{}) error
+}
+
 // Service handles firmenbuch business logic
 type Service struct {
-	repo   *Repository
-	client *fb.Client
+	repo     *Repository
+	client   fb.Registry
+	notifier Notifier
+	events   EventPublisher
+	appURL   string
+
+	searchCache    SearchCache
+	searchCacheTTL time.Duration
 }
 
 // NewService creates a new firmenbuch service
-func NewService(repo *Repository, client *fb.Client) *Service {
+func NewService(repo *Repository, client fb.Registry) *Service {
 	return &Service{
 		repo:   repo,
 		client: client,
 	}
 }
 
-// Search searches for companies by name, FN, or location
+// SetNotifier enables the mail to tenant admins about changes of watched
+// companies
+func (s *Service) SetNotifier(notifier Notifier, appURL string) {
+	s.notifier = notifier
+	s.appURL = strings.TrimSuffix(appURL, "/")
+}
+
+// SetEvents enables the fb_change webhook event
+func (s *Service) SetEvents(events EventPublisher) {
+	s.events = events
+}
+
+// SetSearchCache caches search responses for ttl. The Firmenbuch is a
+// public register, so the responses are shared by all tenants.
+func (s *Service) SetSearchCache(cache SearchCache, ttl time.Duration) {
+	s.searchCache = cache
+	s.searchCacheTTL = ttl
+}
+
+// Search searches for companies by full text, name, FN, UID or address,
+// one page at a time. Responses are served from the search cache while
+// they are fresh.
 func (s *Service) Search(ctx context.Context, tenantID uuid.UUID, input *SearchInput) (*SearchResponse, error) {
-	if input.Name == "" && input.FN == "" && input.Ort == "" {
+	if input.Query == "" && input.Name == "" && input.FN == "" && input.UID == "" &&
+		input.Strasse == "" && input.PLZ == "" && input.Ort == "" {
 		return nil, ErrSearchEmpty
 	}
 
@@ -46,16 +106,47 @@ func (s *Service) Search(ctx context.Context, tenantID uuid.UUID, input *SearchI
 			return nil, ErrInvalidFN
 		}
 	}
+	if input.UID != "" {
+		input.UID = strings.ToUpper(strings.ReplaceAll(input.UID, " ", ""))
+		if result := fonws.ValidateUIDFormat(input.UID); !result.Valid || result.CountryCode != "AT" {
+			return nil, ErrInvalidUID
+		}
+	}
+	if s.client == nil {
+		return nil, ErrNotConfigured
+	}
 
 	// Build search request
 	req := &fb.FBSearchRequest{
-		Name:    input.Name,
-		FN:      input.FN,
-		Ort:     input.Ort,
-		MaxHits: input.MaxHits,
+		Suchbegriff: input.Query,
+		Name:        input.Name,
+		FN:          input.FN,
+		UID:         input.UID,
+		Strasse:     input.Strasse,
+		PLZ:         input.PLZ,
+		Ort:         input.Ort,
+		Start:       input.Offset,
+		MaxHits:     input.MaxHits,
 	}
 	if req.MaxHits <= 0 {
-		req.MaxHits = 20
+		req.MaxHits = DefaultSearchLimit
+	}
+	if req.MaxHits > MaxSearchLimit {
+		req.MaxHits = MaxSearchLimit
+	}
+	if req.Start < 0 {
+		req.Start = 0
+	}
+
+	key := searchCacheKey(req)
+	if s.searchCache != nil {
+		if data, ok := s.searchCache.Get(ctx, key); ok {
+			var cached SearchResponse
+			if err := json.Unmarshal(data, &cached); err == nil {
+				cached.Cached = true
+				return &cached, nil
+			}
+		}
 	}
 
 	// Call Firmenbuch API
@@ -73,14 +164,37 @@ func (s *Service) Search(ctx context.Context, tenantID uuid.UUID, input *SearchI
 			Rechtsform: string(r.Rechtsform),
 			Sitz:       r.Sitz,
 			Status:     string(r.Status),
+			UID:        r.UID,
+			Strasse:    r.Adresse.Strasse,
+			PLZ:        r.Adresse.PLZ,
+			Ort:        r.Adresse.Ort,
 		})
 	}
 
-	return &SearchResponse{
+	resp := &SearchResponse{
 		Results:    results,
 		TotalCount: fbResp.TotalCount,
+		Limit:      req.MaxHits,
+		Offset:     req.Start,
 		Cached:     false,
-	}, nil
+	}
+
+	// Caching is best-effort; without Redis every search goes to the
+	// Firmenbuch
+	if s.searchCache != nil {
+		if data, err := json.Marshal(resp); err == nil {
+			_ = s.searchCache.Set(ctx, key, data, s.searchCacheTTL)
+		}
+	}
+
+	return resp, nil
+}
+
+// searchCacheKey identifies a search request in the search cache
+func searchCacheKey(req *fb.FBSearchRequest) string {
+	data, _ := json.Marshal(req)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
 
 // GetExtract retrieves a company extract by FN
@@ -101,6 +215,9 @@ func (s *Service) GetExtract(ctx context.Context, tenantID uuid.UUID, fn string,
 	}
 
 	// Fetch from Firmenbuch API
+	if s.client == nil {
+		return nil, ErrNotConfigured
+	}
 	extract, err := s.client.Extract(fn)
 	if err != nil {
 		return nil, fmt.Errorf("extract failed: %w", err)
@@ -285,7 +402,7 @@ func (s *Service) companyToExtractResponse(company *Company, cached bool) *Extra
 		resp.Gegenstand = company.Gegenstand
 	}
 	if company.LastFetchedAt != nil {
-		d := company.LastFetchedAt.Format("2006-01-02T15:04:05Z")
+		d := company.LastFetchedAt.UTC().Format(time.RFC3339)
 		resp.LastFetchedAt = &d
 	}
 
@@ -344,6 +461,9 @@ func (s *Service) AddToWatchlist(ctx context.Context, tenantID uuid.UUID, input
 	if err != nil {
 		if errors.Is(err, ErrCompanyNotFound) {
 			// Fetch from Firmenbuch first
+			if s.client == nil {
+				return nil, ErrNotConfigured
+			}
 			extract, fetchErr := s.client.Extract(input.FN)
 			if fetchErr != nil {
 				return nil, fmt.Errorf("failed to fetch company: %w", fetchErr)
@@ -406,70 +526,83 @@ func (s *Service) GetCompanyHistory(ctx context.Context, tenantID uuid.UUID, fn
 	return s.repo.GetCompanyHistory(ctx, company.ID, limit, offset)
 }
 
-// CheckWatchlist checks all watchlist entries for changes
-func (s *Service) CheckWatchlist(ctx context.Context, olderThan time.Duration, batchSize int) (int, error) {
-	cutoff := time.Now().Add(-olderThan)
-	entries, err := s.repo.GetWatchlistEntriesForCheck(ctx, cutoff, batchSize)
-	if err != nil {
-		return 0, err
-	}
-
-	checked := 0
-	for _, entry := range entries {
-		if err := s.checkWatchlistEntry(ctx, entry); err != nil {
-			// Log but continue
-			fmt.Printf("failed to check %s: %v\n", entry.FN, err)
-			continue
-		}
-		checked++
-	}
-
-	return checked, nil
+// DueWatchlistEntries returns the watchlist entries of all tenants not
+// checked within interval, least recently checked first
+func (s *Service) DueWatchlistEntries(ctx context.Context, interval time.Duration, limit int) ([]*WatchlistEntry, error) {
+	return s.repo.GetWatchlistEntriesForCheck(ctx, time.Now().Add(-interval), limit)
 }
 
-// checkWatchlistEntry checks a single watchlist entry for changes
-func (s *Service) checkWatchlistEntry(ctx context.Context, entry *WatchlistEntry) error {
-	// Fetch current data
+// CheckEntry fetches the current extract of a watched company, compares it
+// with the cached one and stores the changes in the company history. The
+// first check of a company without a cached extract finds no changes.
+func (s *Service) CheckEntry(ctx context.Context, entry *WatchlistEntry) ([]*HistoryEntry, error) {
 	extract, err := s.client.Extract(entry.FN)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("extract failed: %w", err)
 	}
 
-	// Get previous company data
 	oldCompany, err := s.repo.GetCompanyByID(ctx, entry.CompanyID, entry.TenantID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Check for status change
-	newStatus := string(extract.Status)
-	if oldCompany.Status != newStatus {
-		// Record change
-		historyEntry := &HistoryEntry{
-			CompanyID:  entry.CompanyID,
-			ChangeType: "status_change",
-			DetectedAt: time.Now(),
-		}
-		historyEntry.OldValue, _ = json.Marshal(map[string]string{"status": oldCompany.Status})
-		historyEntry.NewValue, _ = json.Marshal(map[string]string{"status": newStatus})
+	now := time.Now()
+	var changes []*HistoryEntry
+	var previous fb.FBExtract
+	if len(oldCompany.ExtractData) > 0 && json.Unmarshal(oldCompany.ExtractData, &previous) == nil {
+		changes = Diff(&previous, extract, now)
+	}
+	for _, change := range changes {
+		change.TenantID = entry.TenantID
+		change.CompanyID = oldCompany.ID
+	}
 
-		if _, err := s.repo.AddHistoryEntry(ctx, historyEntry); err != nil {
-			return err
-		}
+	company := s.extractToCompany(entry.TenantID, extract)
+	company.ID = oldCompany.ID
+	entry.Name = company.Name
+	entry.LastStatus = company.Status
+	entry.LastChecked = &now
+	if err := s.repo.RecordCheck(ctx, company, entry, changes); err != nil {
+		return nil, err
 	}
+	return changes, nil
+}
 
-	// Update company cache
-	updatedCompany := s.extractToCompany(entry.TenantID, extract)
-	updatedCompany.ID = oldCompany.ID
-	if _, err := s.repo.CreateCompany(ctx, updatedCompany); err != nil {
-		return err
+// NotifyChanges announces the changes of a watched company by webhook and
+// mails the tenant admins
+func (s *Service) NotifyChanges(ctx context.Context, entry *WatchlistEntry, changes []*HistoryEntry) error {
+	if len(changes) == 0 {
+		return nil
 	}
 
-	// Update watchlist entry
-	now := time.Now()
-	entry.LastStatus = newStatus
-	entry.LastChecked = &now
-	return s.repo.UpdateWatchlistEntry(ctx, entry)
+	var errs []error
+	if s.events != nil {
+		event := ChangeEvent{FN: entry.FN, Name: entry.Name, Changes: changes}
+		if err := s.events.TriggerEvent(ctx, entry.TenantID, webhook.EventFBChange, event); err != nil {
+			errs = append(errs, fmt.Errorf("failed to trigger webhook: %w", err))
+		}
+	}
+
+	if s.notifier != nil {
+		recipients, err := s.repo.tenantAdmins(ctx, entry.TenantID)
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		params := email.FirmenbuchChangeParams{TenantID: &entry.TenantID, CompanyName: entry.Name, FN: entry.FN}
+		for _, change := range changes {
+			params.Changes = append(params.Changes, change.Summary)
+		}
+		if s.appURL != "" {
+			params.HistoryURL = s.appURL + "/firmenbuch/" + url.PathEscape(entry.FN)
+		}
+		for _, rc := range recipients {
+			params.RecipientName = rc.Name
+			if err := s.notifier.SendFirmenbuchChange(ctx, rc.Email, params); err != nil {
+				errs = append(errs, fmt.Errorf("failed to mail %s: %w", rc.Email, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // ListCachedCompanies lists cached companies
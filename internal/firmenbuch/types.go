@@ -52,20 +52,36 @@ type WatchlistEntry struct {
 // HistoryEntry represents a change in company data
 type HistoryEntry struct {
 	ID         uuid.UUID       `json:"id"`
+	TenantID   uuid.UUID       `json:"tenant_id"`
 	CompanyID  uuid.UUID       `json:"company_id"`
 	ChangeType string          `json:"change_type"`
 	OldValue   json.RawMessage `json:"old_value,omitempty"`
 	NewValue   json.RawMessage `json:"new_value,omitempty"`
+	Summary    string          `json:"summary"`
 	DetectedAt time.Time       `json:"detected_at"`
 	CreatedAt  time.Time       `json:"created_at"`
 }
 
-// SearchInput represents search parameters
+// ChangeEvent is the data of the fb_change webhook event
+type ChangeEvent struct {
+	FN      string          `json:"fn"`
+	Name    string          `json:"name"`
+	Changes []*HistoryEntry `json:"changes"`
+}
+
+// SearchInput represents search parameters. Query is a full-text search
+// over name, FN, UID and address; the other fields filter. MaxHits is the
+// page size, Offset the number of hits skipped.
 type SearchInput struct {
+	Query   string `json:"q,omitempty"`
 	Name    string `json:"name,omitempty"`
 	FN      string `json:"fn,omitempty"`
+	UID     string `json:"uid,omitempty"`
+	Strasse string `json:"strasse,omitempty"`
+	PLZ     string `json:"plz,omitempty"`
 	Ort     string `json:"ort,omitempty"`
 	MaxHits int    `json:"max_hits,omitempty"`
+	Offset  int    `json:"offset,omitempty"`
 }
 
 // SearchResult represents a single search result
@@ -75,12 +91,18 @@ type SearchResult struct {
 	Rechtsform string `json:"rechtsform"`
 	Sitz       string `json:"sitz"`
 	Status     string `json:"status"`
+	UID        string `json:"uid,omitempty"`
+	Strasse    string `json:"strasse,omitempty"`
+	PLZ        string `json:"plz,omitempty"`
+	Ort        string `json:"ort,omitempty"`
 }
 
 // SearchResponse represents the search response
 type SearchResponse struct {
 	Results    []SearchResult `json:"results"`
 	TotalCount int            `json:"total_count"`
+	Limit      int            `json:"limit"`
+	Offset     int            `json:"offset"`
 	Cached     bool           `json:"cached"`
 }
 
@@ -154,6 +176,7 @@ type HistoryResponse struct {
 	ChangeType string          `json:"change_type"`
 	OldValue   json.RawMessage `json:"old_value,omitempty"`
 	NewValue   json.RawMessage `json:"new_value,omitempty"`
+	Summary    string          `json:"summary"`
 	DetectedAt string          `json:"detected_at"`
 }
 
@@ -0,0 +1,133 @@
+package fixtures
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/account/types"
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/fb"
+	"austrian-business-infrastructure/internal/fonws"
+)
+
+// UUID returns a random UUID drawn from the seed
+func (f *Faker) UUID() uuid.UUID {
+	return uuid.Must(uuid.NewRandomFromReader(f.rnd))
+}
+
+// FN returns a Firmenbuch number, e.g. FN123456a
+func (f *Faker) FN() string {
+	return fmt.Sprintf("FN%d%c", 10000+f.rnd.Intn(590000), 'a'+rune(f.rnd.Intn(26)))
+}
+
+// Dienstgebernummer returns a six-digit ELDA employer number
+func (f *Faker) Dienstgebernummer() string {
+	return fmt.Sprintf("%06d", 100000+f.rnd.Intn(900000))
+}
+
+// FinanzOnlineCredentials returns web service credentials with a valid TID
+func (f *Faker) FinanzOnlineCredentials() *types.FinanzOnlineCredentials {
+	return &types.FinanzOnlineCredentials{
+		TID:   f.TID(),
+		BenID: "WSUSER" + f.Digits(4),
+		PIN:   "pin-" + f.Digits(6),
+	}
+}
+
+// ELDACredentials returns ELDA credentials for a new employer
+func (f *Faker) ELDACredentials() *elda.ELDACredentials {
+	return &elda.ELDACredentials{
+		DienstgeberNr: f.Dienstgebernummer(),
+		BenutzerNr:    f.Digits(8),
+		PIN:           "pin-" + f.Digits(6),
+	}
+}
+
+// Anmeldung returns a draft registration of a new employee, with an
+// SV-Nummer matching the date of birth
+func (f *Faker) Anmeldung(accountID uuid.UUID, entry time.Time) *elda.ELDAMeldung {
+	p := f.Person()
+	geschlecht := "M"
+	if p.Geschlecht == "w" {
+		geschlecht = "W"
+	}
+	return &elda.ELDAMeldung{
+		ID:             f.UUID(),
+		ELDAAccountID:  accountID,
+		Type:           elda.MeldungTypeAnmeldung,
+		Status:         elda.MeldungStatusDraft,
+		SVNummer:       f.SVNummer(p.Geburtsdatum),
+		Vorname:        p.Vorname,
+		Nachname:       p.Familienname,
+		Geburtsdatum:   &p.Geburtsdatum,
+		Geschlecht:     geschlecht,
+		Eintrittsdatum: &entry,
+		CreatedAt:      entry,
+		UpdatedAt:      entry,
+	}
+}
+
+// UVA returns a monthly VAT return with 20% and 10% turnover and input tax,
+// and KZ095 calculated from them
+func (f *Faker) UVA(year, month int) *fonws.UVA {
+	uva := &fonws.UVA{
+		Year:   year,
+		Period: fonws.UVAPeriod{Type: fonws.PeriodTypeMonthly, Value: month},
+		KZ017:  f.Between(10000, 5000000) * 100,
+		KZ018:  f.Between(0, 500000) * 100,
+		Status: fonws.UVAStatusDraft,
+	}
+	uva.KZ000 = uva.KZ017 + uva.KZ018
+	uva.KZ060 = f.Between(0, uva.KZ017/100/10) * 100
+	uva.KZ095 = uva.CalculateKZ095()
+	uva.CreatedAt = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 14)
+	return uva
+}
+
+// UIDResponse returns the FinanzOnline answer confirming the UID of a company
+func UIDResponse(c Company) *fonws.UIDAbfrageResponse {
+	return &fonws.UIDAbfrageResponse{
+		RC:         0,
+		UIDTN:      c.UID,
+		Gueltig:    "true",
+		Name:       c.Name,
+		AdrStrasse: c.Address.Street,
+		AdrPLZ:     c.Address.PostalCode,
+		AdrOrt:     c.Address.City,
+	}
+}
+
+// FirmenbuchExtract returns an active Firmenbuch entry of a company with a
+// managing director
+func (f *Faker) FirmenbuchExtract(c Company) *fb.FBExtract {
+	founded := time.Date(1990+f.rnd.Intn(30), time.Month(1+f.rnd.Intn(12)), 1+f.rnd.Intn(28), 0, 0, 0, 0, time.UTC)
+	director := f.Person()
+	return &fb.FBExtract{
+		FN:         f.FN(),
+		Firma:      c.Name,
+		Rechtsform: fb.Rechtsform(c.LegalForm),
+		Sitz:       c.Address.City,
+		Adresse: fb.FBAdresse{
+			Strasse: c.Address.Street,
+			PLZ:     c.Address.PostalCode,
+			Ort:     c.Address.City,
+			Land:    "AT",
+		},
+		Stammkapital:    3500000,
+		Waehrung:        "EUR",
+		Status:          fb.FBStatusAktiv,
+		Gruendungsdatum: founded,
+		LetzteAenderung: founded.AddDate(5, 0, 0),
+		Geschaeftsfuehrer: []fb.FBPerson{{
+			Vorname:        director.Vorname,
+			Nachname:       director.Familienname,
+			Geburtsdatum:   director.Geburtsdatum,
+			Funktion:       fb.FunktionGeschaeftsfuehrer,
+			VertretungsArt: fb.VertretungSelbstaendig,
+			Seit:           founded,
+		}},
+		Gegenstand: c.Branch,
+	}
+}
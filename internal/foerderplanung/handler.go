@@ -0,0 +1,207 @@
+package foerderplanung
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// Handler handles Förderung planning HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new planning handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers planning routes. Deadlines, the calendar,
+// estimates and capacities are open to all users of the tenant; changing
+// estimates and capacities is admin-only.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/foerderplanung/deadlines", requireAuth(http.HandlerFunc(h.ListDeadlines)))
+	router.Handle("GET /api/v1/foerderplanung/calendar", requireAuth(http.HandlerFunc(h.GetCalendar)))
+
+	router.Handle("GET /api/v1/foerderplanung/efforts", requireAuth(http.HandlerFunc(h.ListEfforts)))
+	router.Handle("PUT /api/v1/foerderplanung/efforts/{type}", requireAuth(requireAdmin(http.HandlerFunc(h.SetEffort))))
+	router.Handle("DELETE /api/v1/foerderplanung/efforts/{type}", requireAuth(requireAdmin(http.HandlerFunc(h.ResetEffort))))
+
+	router.Handle("GET /api/v1/foerderplanung/advisors", requireAuth(http.HandlerFunc(h.ListAdvisors)))
+	router.Handle("PUT /api/v1/foerderplanung/advisors/{userId}", requireAuth(requireAdmin(http.HandlerFunc(h.SetCapacity))))
+}
+
+// EffortRequest is the body of an effort estimate update
+type EffortRequest struct {
+	Hours     float64 `json:"hours"`
+	LeadWeeks int     `json:"lead_weeks"`
+}
+
+// CapacityRequest is the body of an advisor capacity update
+type CapacityRequest struct {
+	WeeklyHours *float64 `json:"weekly_hours"`
+}
+
+// ListDeadlines handles GET /api/v1/foerderplanung/deadlines
+func (h *Handler) ListDeadlines(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	deadlines, err := h.service.Deadlines(r.Context(), tenantID, queryWeeks(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if deadlines == nil {
+		deadlines = []Deadline{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items": deadlines,
+	})
+}
+
+// GetCalendar handles GET /api/v1/foerderplanung/calendar
+func (h *Handler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	cal, err := h.service.Calendar(r.Context(), tenantID, queryWeeks(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, cal)
+}
+
+// ListEfforts handles GET /api/v1/foerderplanung/efforts
+func (h *Handler) ListEfforts(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	efforts, err := h.service.ListEfforts(r.Context(), tenantID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items": efforts,
+	})
+}
+
+// SetEffort handles PUT /api/v1/foerderplanung/efforts/{type}
+func (h *Handler) SetEffort(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var req EffortRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	effort, err := h.service.SetEffort(r.Context(), tenantID, Effort{
+		FoerderungType: r.PathValue("type"),
+		Hours:          req.Hours,
+		LeadWeeks:      req.LeadWeeks,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, effort)
+}
+
+// ResetEffort handles DELETE /api/v1/foerderplanung/efforts/{type}
+func (h *Handler) ResetEffort(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	effort, err := h.service.ResetEffort(r.Context(), tenantID, r.PathValue("type"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, effort)
+}
+
+// ListAdvisors handles GET /api/v1/foerderplanung/advisors
+func (h *Handler) ListAdvisors(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	advisors, err := h.service.ListAdvisors(r.Context(), tenantID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if advisors == nil {
+		advisors = []Advisor{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items": advisors,
+	})
+}
+
+// SetCapacity handles PUT /api/v1/foerderplanung/advisors/{userId}
+func (h *Handler) SetCapacity(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		api.BadRequest(w, "invalid userId")
+		return
+	}
+
+	var req CapacityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.WeeklyHours == nil {
+		api.BadRequest(w, "weekly_hours is required")
+		return
+	}
+
+	if err := h.service.SetCapacity(r.Context(), tenantID, userID, *req.WeeklyHours); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// queryWeeks returns the weeks query parameter; 0 selects the default
+func queryWeeks(r *http.Request) int {
+	weeks, _ := strconv.Atoi(r.URL.Query().Get("weeks"))
+	return weeks
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidType), errors.Is(err, ErrInvalidEffort),
+		errors.Is(err, ErrInvalidCapacity), errors.Is(err, ErrUserNotInTenant):
+		api.BadRequest(w, err.Error())
+	default:
+		api.InternalError(w)
+	}
+}
@@ -0,0 +1,170 @@
+package foerderplanung
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/foerderung"
+)
+
+// WeekStart returns the Monday of the week of t, at midnight UTC
+func WeekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// Estimate sets the effort and remaining hours of a deadline from the
+// estimate of its Förderung type. A planned Antrag and a watched program
+// take the full estimate; an Antrag in drafting takes DraftingShare of it.
+func Estimate(d *Deadline, efforts map[string]Effort) {
+	effort, ok := efforts[d.FoerderungType]
+	if !ok {
+		effort = DefaultEfforts()[string(foerderung.TypeZuschuss)]
+	}
+	d.EffortHours = effort.Hours
+	d.RemainingHours = effort.Hours
+	if d.Status == foerderung.AntragStatusDrafting {
+		d.RemainingHours = round1(effort.Hours * DraftingShare)
+	}
+}
+
+// BuildCalendar spreads the remaining work of each deadline evenly over the
+// lead weeks of its Förderung type that end with the deadline's week, and
+// sums it per advisor and week for the given number of weeks from from on.
+// Work of watched programs is summed separately and not held against any
+// advisor. Weeks where an advisor's work exceeds their capacity, deadlines
+// already passed, deadlines closer than their lead time and Anträge without
+// an advisor raise warnings.
+func BuildCalendar(deadlines []Deadline, advisors map[uuid.UUID]Advisor, efforts map[string]Effort, from time.Time, weeks int) *Calendar {
+	first := WeekStart(from)
+	cal := &Calendar{From: first.Format("2006-01-02"), Weeks: make([]*Week, weeks), Warnings: []Warning{}}
+	loads := make([]map[uuid.UUID]*AdvisorLoad, weeks)
+	for i := range cal.Weeks {
+		cal.Weeks[i] = &Week{Start: first.AddDate(0, 0, 7*i).Format("2006-01-02"), Advisors: []*AdvisorLoad{}}
+		loads[i] = make(map[uuid.UUID]*AdvisorLoad)
+	}
+
+	for _, d := range deadlines {
+		effort, ok := efforts[d.FoerderungType]
+		if !ok {
+			effort = DefaultEfforts()[string(foerderung.TypeZuschuss)]
+		}
+		lead := max(effort.LeadWeeks, 1)
+		due := WeekStart(d.Deadline)
+		isAntrag := d.Source == SourceAntrag
+
+		if d.Deadline.Before(from) {
+			if isAntrag {
+				cal.Warnings = append(cal.Warnings, Warning{
+					Kind:      WarningDeadlinePassed,
+					AntragID:  d.AntragID,
+					AdvisorID: d.AdvisorID,
+					Message:   fmt.Sprintf("Einreichfrist of %s passed on %s", d.Program, d.Deadline.Format("2006-01-02")),
+				})
+			}
+			continue
+		}
+
+		start := due.AddDate(0, 0, -7*(lead-1))
+		if start.Before(first) {
+			start = first
+			if isAntrag {
+				cal.Warnings = append(cal.Warnings, Warning{
+					Kind:      WarningShortLeadTime,
+					AntragID:  d.AntragID,
+					AdvisorID: d.AdvisorID,
+					Message: fmt.Sprintf("%s is due %s, with %d of the usual %d weeks left",
+						d.Program, d.Deadline.Format("2006-01-02"), int(due.Sub(first).Hours()/(24*7))+1, lead),
+				})
+			}
+		}
+		if isAntrag && d.AdvisorID == nil {
+			cal.Warnings = append(cal.Warnings, Warning{
+				Kind:     WarningUnassigned,
+				AntragID: d.AntragID,
+				Message:  fmt.Sprintf("Antrag for %s due %s has no advisor", d.Program, d.Deadline.Format("2006-01-02")),
+			})
+		}
+
+		span := int(due.Sub(start).Hours()/(24*7)) + 1
+		perWeek := d.RemainingHours / float64(span)
+		for w := 0; w < span; w++ {
+			i := int(start.Sub(first).Hours()/(24*7)) + w
+			if i < 0 || i >= weeks {
+				continue
+			}
+			week := cal.Weeks[i]
+			switch {
+			case !isAntrag:
+				week.WatchedHours += perWeek
+			case d.AdvisorID == nil:
+				week.UnassignedHours += perWeek
+			default:
+				load, ok := loads[i][*d.AdvisorID]
+				if !ok {
+					load = newLoad(*d.AdvisorID, d.AdvisorName, week.Start, advisors)
+					loads[i][*d.AdvisorID] = load
+				}
+				load.PlannedHours += perWeek
+			}
+		}
+	}
+
+	for i, week := range cal.Weeks {
+		week.UnassignedHours = round1(week.UnassignedHours)
+		week.WatchedHours = round1(week.WatchedHours)
+		for _, load := range loads[i] {
+			load.PlannedHours = round1(load.PlannedHours)
+			if load.CapacityHours > 0 {
+				load.Utilization = math.Round(load.PlannedHours / load.CapacityHours * 100)
+			}
+			load.OverAllocated = load.PlannedHours > load.CapacityHours
+			week.Advisors = append(week.Advisors, load)
+		}
+		sort.Slice(week.Advisors, func(a, b int) bool {
+			if week.Advisors[a].Name != week.Advisors[b].Name {
+				return week.Advisors[a].Name < week.Advisors[b].Name
+			}
+			return week.Advisors[a].UserID.String() < week.Advisors[b].UserID.String()
+		})
+		for _, load := range week.Advisors {
+			if !load.OverAllocated {
+				continue
+			}
+			id := load.UserID
+			cal.Warnings = append(cal.Warnings, Warning{
+				Kind:      WarningOverAllocated,
+				Week:      week.Start,
+				AdvisorID: &id,
+				Message: fmt.Sprintf("%s has %.1f h planned in the week of %s, %.1f h capacity",
+					load.Name, load.PlannedHours, week.Start, load.CapacityHours),
+			})
+		}
+	}
+	return cal
+}
+
+// newLoad starts the load of an advisor in a week. Their capacity is cut to
+// the working time their calendar leaves free that week.
+func newLoad(userID uuid.UUID, name, week string, advisors map[uuid.UUID]Advisor) *AdvisorLoad {
+	load := &AdvisorLoad{UserID: userID, Name: name, CapacityHours: DefaultWeeklyHours}
+	if a, ok := advisors[userID]; ok {
+		load.CapacityHours = a.WeeklyHours
+		if load.Name == "" {
+			load.Name = a.Name
+		}
+		if busy := a.BusyHours[week]; busy > 0 {
+			load.BusyHours = round1(busy)
+			load.CapacityHours = round1(max(min(load.CapacityHours, WorkWeekHours-busy), 0))
+		}
+	}
+	return load
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
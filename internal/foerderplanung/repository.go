@@ -0,0 +1,183 @@
+package foerderplanung
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles planning database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new planning repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// AntragDeadlines returns the planned and drafting Anträge of a tenant whose
+// program has an Einreichfrist up to the given date, passed ones included.
+// The advisor is the assigned one, or the creator if none is assigned.
+func (r *Repository) AntragDeadlines(ctx context.Context, tenantID uuid.UUID, until time.Time) ([]Deadline, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT a.id, f.id, f.name, f.provider, f.type, a.status,
+			COALESCE(f.application_deadline, f.call_end) AS deadline,
+			u.id, COALESCE(u.name, '')
+		FROM foerderungs_antraege a
+		JOIN foerderungen f ON f.id = a.foerderung_id
+		LEFT JOIN users u ON u.id = COALESCE(a.advisor_id, a.created_by)
+		WHERE a.tenant_id = $1 AND a.status IN ('planned', 'drafting')
+			AND COALESCE(f.application_deadline, f.call_end) <= $2
+		ORDER BY deadline, f.name`, tenantID, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load antrag deadlines: %w", err)
+	}
+	defer rows.Close()
+
+	var deadlines []Deadline
+	for rows.Next() {
+		d := Deadline{Source: SourceAntrag}
+		var antragID uuid.UUID
+		if err := rows.Scan(&antragID, &d.FoerderungID, &d.Program, &d.Provider, &d.FoerderungType, &d.Status,
+			&d.Deadline, &d.AdvisorID, &d.AdvisorName); err != nil {
+			return nil, err
+		}
+		d.AntragID = &antragID
+		deadlines = append(deadlines, d)
+	}
+	return deadlines, rows.Err()
+}
+
+// WatchedDeadlines returns the active programs matched by the tenant's
+// active monitors, not dismissed and not applied for yet, whose
+// Einreichfrist lies between the given dates
+func (r *Repository) WatchedDeadlines(ctx context.Context, tenantID uuid.UUID, from, until time.Time) ([]Deadline, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT f.id, f.name, f.provider, f.type, COALESCE(f.application_deadline, f.call_end) AS deadline
+		FROM monitor_notifications n
+		JOIN profil_monitore m ON m.id = n.monitor_id
+		JOIN foerderungen f ON f.id = n.foerderung_id
+		WHERE m.tenant_id = $1 AND m.is_active AND NOT COALESCE(n.dismissed, FALSE)
+			AND f.status = 'active'
+			AND COALESCE(f.application_deadline, f.call_end) BETWEEN $2 AND $3
+			AND NOT EXISTS (
+				SELECT 1 FROM foerderungs_antraege a WHERE a.tenant_id = $1 AND a.foerderung_id = f.id
+			)
+		ORDER BY deadline, f.name`, tenantID, from, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load watched deadlines: %w", err)
+	}
+	defer rows.Close()
+
+	var deadlines []Deadline
+	for rows.Next() {
+		d := Deadline{Source: SourceWatched}
+		if err := rows.Scan(&d.FoerderungID, &d.Program, &d.Provider, &d.FoerderungType, &d.Deadline); err != nil {
+			return nil, err
+		}
+		deadlines = append(deadlines, d)
+	}
+	return deadlines, rows.Err()
+}
+
+// Efforts returns the tenant's effort estimate overrides by Förderung type
+func (r *Repository) Efforts(ctx context.Context, tenantID uuid.UUID) (map[string]Effort, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT foerderung_type, hours::float8, lead_weeks
+		FROM foerderung_effort_estimates
+		WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load effort estimates: %w", err)
+	}
+	defer rows.Close()
+
+	efforts := make(map[string]Effort)
+	for rows.Next() {
+		e := Effort{Custom: true}
+		if err := rows.Scan(&e.FoerderungType, &e.Hours, &e.LeadWeeks); err != nil {
+			return nil, err
+		}
+		efforts[e.FoerderungType] = e
+	}
+	return efforts, rows.Err()
+}
+
+// SaveEffort stores an effort estimate override
+func (r *Repository) SaveEffort(ctx context.Context, tenantID uuid.UUID, e Effort) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO foerderung_effort_estimates (tenant_id, foerderung_type, hours, lead_weeks)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, foerderung_type) DO UPDATE
+		SET hours = EXCLUDED.hours, lead_weeks = EXCLUDED.lead_weeks, updated_at = NOW()`,
+		tenantID, e.FoerderungType, e.Hours, e.LeadWeeks)
+	if err != nil {
+		return fmt.Errorf("failed to save effort estimate: %w", err)
+	}
+	return nil
+}
+
+// DeleteEffort drops an effort estimate override
+func (r *Repository) DeleteEffort(ctx context.Context, tenantID uuid.UUID, foerderungType string) error {
+	_, err := r.db.Exec(ctx, `
+		DELETE FROM foerderung_effort_estimates WHERE tenant_id = $1 AND foerderung_type = $2`,
+		tenantID, foerderungType)
+	if err != nil {
+		return fmt.Errorf("failed to delete effort estimate: %w", err)
+	}
+	return nil
+}
+
+// Advisors returns the active users of a tenant with their capacity; users
+// without a configured capacity get the default
+func (r *Repository) Advisors(ctx context.Context, tenantID uuid.UUID) ([]Advisor, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT u.id, u.name, COALESCE(c.weekly_hours::float8, $2), c.user_id IS NOT NULL
+		FROM users u
+		LEFT JOIN advisor_capacities c ON c.tenant_id = u.tenant_id AND c.user_id = u.id
+		WHERE u.tenant_id = $1 AND u.is_active
+		ORDER BY u.name`, tenantID, DefaultWeeklyHours)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load advisors: %w", err)
+	}
+	defer rows.Close()
+
+	var advisors []Advisor
+	for rows.Next() {
+		var a Advisor
+		if err := rows.Scan(&a.UserID, &a.Name, &a.WeeklyHours, &a.Custom); err != nil {
+			return nil, err
+		}
+		advisors = append(advisors, a)
+	}
+	return advisors, rows.Err()
+}
+
+// IsActiveUser checks that a user is an active member of the tenant
+func (r *Repository) IsActiveUser(ctx context.Context, tenantID, userID uuid.UUID) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND tenant_id = $2 AND is_active)
+	`, userID, tenantID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("failed to check user: %w", err)
+	}
+	return ok, nil
+}
+
+// SaveCapacity stores the weekly capacity of an advisor
+func (r *Repository) SaveCapacity(ctx context.Context, tenantID, userID uuid.UUID, weeklyHours float64) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO advisor_capacities (tenant_id, user_id, weekly_hours)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE
+		SET weekly_hours = EXCLUDED.weekly_hours, updated_at = NOW()`,
+		tenantID, userID, weeklyHours)
+	if err != nil {
+		return fmt.Errorf("failed to save capacity: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package foerderplanung
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxWeeks is the longest planning horizon
+const MaxWeeks = 52
+
+// BusySource returns the busy time of a tenant's users between two days
+// from their connected calendars, per user and week keyed by the week's
+// Monday as 2006-01-02; calendarsync.Service implements it
+type BusySource interface {
+	BusyHours(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (map[uuid.UUID]map[string]float64, error)
+}
+
+// Service handles Förderung workload planning
+type Service struct {
+	repo *Repository
+	busy BusySource
+	now  func() time.Time
+}
+
+// NewService creates a new planning service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// SetBusySource reduces the advisors' capacity by the busy time of their
+// calendars
+func (s *Service) SetBusySource(src BusySource) {
+	s.busy = src
+}
+
+// Efforts returns the effort estimate of every Förderung type: the tenant's
+// override, or the built-in default
+func (s *Service) Efforts(ctx context.Context, tenantID uuid.UUID) (map[string]Effort, error) {
+	custom, err := s.repo.Efforts(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	efforts := DefaultEfforts()
+	for t, e := range custom {
+		if _, ok := efforts[t]; ok {
+			efforts[t] = e
+		}
+	}
+	return efforts, nil
+}
+
+// ListEfforts returns the effort estimates ordered by Förderung type
+func (s *Service) ListEfforts(ctx context.Context, tenantID uuid.UUID) ([]Effort, error) {
+	efforts, err := s.Efforts(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Effort, 0, len(efforts))
+	for _, e := range efforts {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].FoerderungType < list[j].FoerderungType })
+	return list, nil
+}
+
+// SetEffort overrides the effort estimate of a Förderung type
+func (s *Service) SetEffort(ctx context.Context, tenantID uuid.UUID, e Effort) (*Effort, error) {
+	if !IsValidType(e.FoerderungType) {
+		return nil, ErrInvalidType
+	}
+	if e.Hours < 0.5 || e.Hours > 1000 || e.LeadWeeks < 1 || e.LeadWeeks > 26 {
+		return nil, ErrInvalidEffort
+	}
+	e.Hours = round1(e.Hours)
+	e.Custom = true
+	if err := s.repo.SaveEffort(ctx, tenantID, e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// ResetEffort drops the override of a Förderung type, restoring the default
+func (s *Service) ResetEffort(ctx context.Context, tenantID uuid.UUID, foerderungType string) (*Effort, error) {
+	if !IsValidType(foerderungType) {
+		return nil, ErrInvalidType
+	}
+	if err := s.repo.DeleteEffort(ctx, tenantID, foerderungType); err != nil {
+		return nil, err
+	}
+	e := DefaultEfforts()[foerderungType]
+	return &e, nil
+}
+
+// ListAdvisors returns the users of a tenant with their weekly capacity
+func (s *Service) ListAdvisors(ctx context.Context, tenantID uuid.UUID) ([]Advisor, error) {
+	return s.repo.Advisors(ctx, tenantID)
+}
+
+// SetCapacity sets the weekly Förderung capacity of an advisor
+func (s *Service) SetCapacity(ctx context.Context, tenantID, userID uuid.UUID, weeklyHours float64) error {
+	if weeklyHours < 0 || weeklyHours > 80 {
+		return ErrInvalidCapacity
+	}
+	ok, err := s.repo.IsActiveUser(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrUserNotInTenant
+	}
+	return s.repo.SaveCapacity(ctx, tenantID, userID, round1(weeklyHours))
+}
+
+// Deadlines returns the Einreichfristen of open Anträge and watched programs
+// within the given number of weeks, with their estimated effort, soonest
+// first. Passed deadlines of open Anträge are included.
+func (s *Service) Deadlines(ctx context.Context, tenantID uuid.UUID, weeks int) ([]Deadline, error) {
+	efforts, err := s.Efforts(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.deadlines(ctx, tenantID, weeks, efforts)
+}
+
+// Calendar returns the planned workload per advisor and week over the given
+// number of weeks, starting with the current week
+func (s *Service) Calendar(ctx context.Context, tenantID uuid.UUID, weeks int) (*Calendar, error) {
+	efforts, err := s.Efforts(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	deadlines, err := s.deadlines(ctx, tenantID, weeks, efforts)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.Advisors(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	var busy map[uuid.UUID]map[string]float64
+	if s.busy != nil {
+		first := WeekStart(s.today())
+		busy, err = s.busy.BusyHours(ctx, tenantID, first, first.AddDate(0, 0, 7*clampWeeks(weeks)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	advisors := make(map[uuid.UUID]Advisor, len(list))
+	for _, a := range list {
+		a.BusyHours = busy[a.UserID]
+		advisors[a.UserID] = a
+	}
+
+	return BuildCalendar(deadlines, advisors, efforts, s.today(), clampWeeks(weeks)), nil
+}
+
+func (s *Service) deadlines(ctx context.Context, tenantID uuid.UUID, weeks int, efforts map[string]Effort) ([]Deadline, error) {
+	today := s.today()
+	until := WeekStart(today).AddDate(0, 0, 7*clampWeeks(weeks)-1)
+
+	deadlines, err := s.repo.AntragDeadlines(ctx, tenantID, until)
+	if err != nil {
+		return nil, err
+	}
+	watched, err := s.repo.WatchedDeadlines(ctx, tenantID, today, until)
+	if err != nil {
+		return nil, err
+	}
+	deadlines = append(deadlines, watched...)
+
+	for i := range deadlines {
+		d := &deadlines[i]
+		Estimate(d, efforts)
+		d.DaysLeft = int(d.Deadline.Sub(today).Hours() / 24)
+	}
+	sort.SliceStable(deadlines, func(i, j int) bool { return deadlines[i].Deadline.Before(deadlines[j].Deadline) })
+	return deadlines, nil
+}
+
+func (s *Service) today() time.Time {
+	now := s.now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func clampWeeks(weeks int) int {
+	if weeks <= 0 {
+		return 12
+	}
+	return min(weeks, MaxWeeks)
+}
@@ -0,0 +1,143 @@
+// Package foerderplanung plans the workload of Förderung applications:
+// upcoming Einreichfristen of Anträge and watched programs, the effort they
+// take and how it fits the weekly capacity of each advisor.
+package foerderplanung
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/foerderung"
+)
+
+var (
+	ErrInvalidType     = errors.New("unknown foerderung type")
+	ErrInvalidEffort   = errors.New("hours must be between 0.5 and 1000 and lead_weeks between 1 and 26")
+	ErrInvalidCapacity = errors.New("weekly_hours must be between 0 and 80")
+	ErrUserNotInTenant = errors.New("user is not an active member of this tenant")
+)
+
+// Sources of a deadline
+const (
+	SourceAntrag  = "antrag"
+	SourceWatched = "watched"
+)
+
+// Warning kinds
+const (
+	WarningOverAllocated  = "over_allocated"
+	WarningDeadlinePassed = "deadline_passed"
+	WarningShortLeadTime  = "short_lead_time"
+	WarningUnassigned     = "unassigned"
+)
+
+// DefaultWeeklyHours is the Förderung capacity of an advisor without a
+// configured capacity
+const DefaultWeeklyHours = 10.0
+
+// WorkWeekHours is the working time of a week. Time an advisor's calendar
+// shows as busy is taken from it; their Förderung capacity is at most what
+// is left.
+const WorkWeekHours = 40.0
+
+// DraftingShare is the share of the estimate still open once an Antrag is
+// being drafted
+const DraftingShare = 0.5
+
+// Effort is the estimated work for an application of a Förderung type
+type Effort struct {
+	FoerderungType string  `json:"foerderung_type"`
+	Hours          float64 `json:"hours"`
+	LeadWeeks      int     `json:"lead_weeks"`
+	Custom         bool    `json:"custom"`
+}
+
+// DefaultEfforts returns the built-in estimates per Förderung type
+func DefaultEfforts() map[string]Effort {
+	efforts := map[string]Effort{
+		string(foerderung.TypeZuschuss):    {Hours: 16, LeadWeeks: 4},
+		string(foerderung.TypeKredit):      {Hours: 8, LeadWeeks: 3},
+		string(foerderung.TypeGarantie):    {Hours: 6, LeadWeeks: 2},
+		string(foerderung.TypeBeratung):    {Hours: 4, LeadWeeks: 2},
+		string(foerderung.TypeKombination): {Hours: 24, LeadWeeks: 6},
+	}
+	for t, e := range efforts {
+		e.FoerderungType = t
+		efforts[t] = e
+	}
+	return efforts
+}
+
+// IsValidType reports whether t is a Förderung type
+func IsValidType(t string) bool {
+	_, ok := DefaultEfforts()[t]
+	return ok
+}
+
+// Advisor is a user with their weekly Förderung capacity
+type Advisor struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Name        string    `json:"name"`
+	WeeklyHours float64   `json:"weekly_hours"`
+	Custom      bool      `json:"custom"`
+
+	// BusyHours is the busy time of the advisor's calendar per week, keyed
+	// by the week's Monday as 2006-01-02
+	BusyHours map[string]float64 `json:"-"`
+}
+
+// Deadline is an upcoming Einreichfrist: of an open Antrag, or of a program
+// the tenant's monitors matched that has no Antrag yet
+type Deadline struct {
+	Source         string     `json:"source"`
+	AntragID       *uuid.UUID `json:"antrag_id,omitempty"`
+	FoerderungID   uuid.UUID  `json:"foerderung_id"`
+	Program        string     `json:"program"`
+	Provider       string     `json:"provider"`
+	FoerderungType string     `json:"foerderung_type"`
+	Status         string     `json:"status,omitempty"` // Antrag status
+	Deadline       time.Time  `json:"deadline"`
+	DaysLeft       int        `json:"days_left"`
+	AdvisorID      *uuid.UUID `json:"advisor_id,omitempty"`
+	AdvisorName    string     `json:"advisor_name,omitempty"`
+	EffortHours    float64    `json:"effort_hours"`
+	RemainingHours float64    `json:"remaining_hours"`
+}
+
+// Calendar is the planned workload per week
+type Calendar struct {
+	From     string    `json:"from"`
+	Weeks    []*Week   `json:"weeks"`
+	Warnings []Warning `json:"warnings"`
+}
+
+// Week is the workload of one calendar week, starting on Monday
+type Week struct {
+	Start           string         `json:"start"`
+	Advisors        []*AdvisorLoad `json:"advisors"`
+	UnassignedHours float64        `json:"unassigned_hours"`
+	// WatchedHours is the work watched programs would take if applied for
+	WatchedHours float64 `json:"watched_hours"`
+}
+
+// AdvisorLoad is the planned work of an advisor in a week
+type AdvisorLoad struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Name          string    `json:"name"`
+	PlannedHours  float64   `json:"planned_hours"`
+	CapacityHours float64   `json:"capacity_hours"`
+	BusyHours     float64   `json:"busy_hours,omitempty"` // From the advisor's calendar
+	Utilization   float64   `json:"utilization"`          // Percent of capacity
+	OverAllocated bool      `json:"over_allocated"`
+}
+
+// Warning points at a planning problem
+type Warning struct {
+	Kind      string     `json:"kind"`
+	Week      string     `json:"week,omitempty"`
+	AdvisorID *uuid.UUID `json:"advisor_id,omitempty"`
+	AntragID  *uuid.UUID `json:"antrag_id,omitempty"`
+	Message   string     `json:"message"`
+}
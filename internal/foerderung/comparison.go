@@ -0,0 +1,462 @@
+package foerderung
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/timezone"
+	"austrian-business-infrastructure/internal/xlsx"
+)
+
+// Bounds of the number of programs in a comparison sheet
+const (
+	MinComparisonPrograms = 2
+	MaxComparisonPrograms = 10
+)
+
+var (
+	ErrComparisonSize     = fmt.Errorf("between %d and %d distinct programs can be compared", MinComparisonPrograms, MaxComparisonPrograms)
+	ErrComparisonNotFound = errors.New("foerderung not found")
+	ErrComparisonSearch   = errors.New("search not found")
+	ErrUnsupportedLocale  = errors.New("unsupported locale")
+)
+
+// SheetBrand is a tenant's look of exported spreadsheets
+type SheetBrand struct {
+	CompanyName string
+	// Color is the header fill as RRGGBB
+	Color  string
+	Footer string
+}
+
+// BrandProvider returns a tenant's spreadsheet look, nil if it has none
+type BrandProvider interface {
+	SheetBrand(ctx context.Context, tenantID uuid.UUID) (*SheetBrand, error)
+}
+
+// SearchSource loads a tenant's funding search for its match scores
+type SearchSource interface {
+	GetByIDAndTenant(ctx context.Context, id, tenantID uuid.UUID) (*FoerderungsSuche, error)
+}
+
+// LocaleSource returns the locale of a tenant
+type LocaleSource interface {
+	Locale(ctx context.Context, tenantID uuid.UUID) string
+}
+
+// ComparisonRequest selects the programs of a comparison sheet. With a
+// search, its match scores and eligibility notes are included.
+type ComparisonRequest struct {
+	FoerderungIDs []uuid.UUID `json:"foerderung_ids"`
+	SearchID      *uuid.UUID  `json:"search_id,omitempty"`
+	// Locale overrides the tenant's locale, e.g. "en-GB"
+	Locale string `json:"locale,omitempty"`
+}
+
+// sheetLocale holds the formats and texts of a comparison sheet
+type sheetLocale struct {
+	lang           string
+	dateFormat     string // Excel format code
+	goDateFormat   string
+	currencyFormat string
+}
+
+var sheetLocales = map[string]sheetLocale{
+	"de-AT": {lang: "de", dateFormat: "DD.MM.YYYY", goDateFormat: "02.01.2006", currencyFormat: `"€ "#,##0`},
+	"de-DE": {lang: "de", dateFormat: "DD.MM.YYYY", goDateFormat: "02.01.2006", currencyFormat: `#,##0 "€"`},
+	"de-CH": {lang: "de", dateFormat: "DD.MM.YYYY", goDateFormat: "02.01.2006", currencyFormat: `"€ "#,##0`},
+	"en-GB": {lang: "en", dateFormat: "DD/MM/YYYY", goDateFormat: "02/01/2006", currencyFormat: `"€"#,##0`},
+	"en-US": {lang: "en", dateFormat: "MM/DD/YYYY", goDateFormat: "01/02/2006", currencyFormat: `"€"#,##0`},
+}
+
+// defaultLocales are the locales of bare language tags
+var defaultLocales = map[string]string{"de": "de-AT", "en": "en-GB"}
+
+// NormalizeLocale returns the supported locale of a tag such as "de",
+// "en_US" or "en-gb", or "" if it is not supported
+func NormalizeLocale(tag string) string {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	lang, region, _ := strings.Cut(tag, "-")
+	lang = strings.ToLower(lang)
+	if region == "" {
+		return defaultLocales[lang]
+	}
+	locale := lang + "-" + strings.ToUpper(region)
+	if _, ok := sheetLocales[locale]; ok {
+		return locale
+	}
+	return defaultLocales[lang]
+}
+
+// comparisonTexts are the labels of the comparison sheet by language
+var comparisonTexts = map[string]map[string]string{
+	"de": {
+		"title": "Förderungsvergleich", "created": "Erstellt am %s", "search": "Match-Scores aus der Förderungssuche vom %s",
+		"criterion": "Kriterium", "provider": "Fördergeber", "type": "Art", "status": "Status",
+		"rate_min": "Förderquote min.", "rate_max": "Förderquote max.", "amount_min": "Mindestbetrag", "amount_max": "Höchstbetrag",
+		"deadline": "Einreichfrist", "days_left": "Tage bis zur Frist", "call_start": "Call-Beginn", "call_end": "Call-Ende",
+		"target": "Zielgruppe", "states": "Bundesländer", "legal_forms": "Rechtsformen", "industries": "Branchen",
+		"excluded": "Ausgeschlossene Branchen", "topics": "Themen", "requirements": "Voraussetzungen",
+		"score": "Match-Score", "rule_score": "Regel-Score", "llm_score": "KI-Score", "eligible": "Förderfähig (KI)",
+		"matched": "Erfüllte Kriterien", "concerns": "Bedenken", "estimated": "Geschätzter Förderbetrag",
+		"combination": "Kombinationshinweis", "next_steps": "Nächste Schritte", "link": "Link",
+		"yes": "ja", "no": "nein", "rolling": "laufend", "budget_exhausted": "bis Budgetausschöpfung",
+		"all": "alle", "not_matched": "nicht in der Suche", "file": "foerderungsvergleich",
+		"zuschuss": "Zuschuss", "kredit": "Kredit", "garantie": "Garantie", "beratung": "Beratung", "kombination": "Kombination",
+		"active": "aktiv", "upcoming": "demnächst", "paused": "pausiert", "closed": "geschlossen",
+		"kmu": "KMU", "startup": "Startup", "grossunternehmen": "Großunternehmen", "alle": "alle",
+		"size_epu": "EPU", "size_kleinst": "Kleinstunternehmen", "size_klein": "Kleinunternehmen",
+		"size_mittel": "Mittlere Unternehmen", "size_gross": "Großunternehmen",
+		"confidence_high": "hohe Sicherheit", "confidence_medium": "mittlere Sicherheit", "confidence_low": "geringe Sicherheit",
+	},
+	"en": {
+		"title": "Funding comparison", "created": "Created on %s", "search": "Match scores from the funding search of %s",
+		"criterion": "Criterion", "provider": "Provider", "type": "Type", "status": "Status",
+		"rate_min": "Funding rate min.", "rate_max": "Funding rate max.", "amount_min": "Minimum amount", "amount_max": "Maximum amount",
+		"deadline": "Application deadline", "days_left": "Days until deadline", "call_start": "Call start", "call_end": "Call end",
+		"target": "Target group", "states": "Federal states", "legal_forms": "Legal forms", "industries": "Industries",
+		"excluded": "Excluded industries", "topics": "Topics", "requirements": "Requirements",
+		"score": "Match score", "rule_score": "Rule score", "llm_score": "AI score", "eligible": "Eligible (AI)",
+		"matched": "Matched criteria", "concerns": "Concerns", "estimated": "Estimated funding",
+		"combination": "Combination note", "next_steps": "Next steps", "link": "Link",
+		"yes": "yes", "no": "no", "rolling": "rolling", "budget_exhausted": "until the budget is exhausted",
+		"all": "all", "not_matched": "not in the search", "file": "funding-comparison",
+		"zuschuss": "Grant", "kredit": "Loan", "garantie": "Guarantee", "beratung": "Advisory", "kombination": "Combination",
+		"active": "active", "upcoming": "upcoming", "paused": "paused", "closed": "closed",
+		"kmu": "SME", "startup": "Start-up", "grossunternehmen": "Large enterprise", "alle": "all",
+		"size_epu": "Sole proprietor", "size_kleinst": "Micro enterprise", "size_klein": "Small enterprise",
+		"size_mittel": "Medium-sized enterprise", "size_gross": "Large enterprise",
+		"confidence_high": "high confidence", "confidence_medium": "medium confidence", "confidence_low": "low confidence",
+	},
+}
+
+// Comparison is a rendered comparison sheet
+type Comparison struct {
+	Workbook *xlsx.Workbook
+	Filename string
+}
+
+// ComparisonInput is what a comparison sheet is built from
+type ComparisonInput struct {
+	Programs []*Foerderung
+	// Search and Matches are set when the sheet includes match scores
+	Search  *FoerderungsSuche
+	Matches map[uuid.UUID]*FoerderungsMatch
+	Brand   *SheetBrand
+	Locale  string
+	Now     time.Time
+}
+
+// BuildComparison renders programs side by side: one column per program,
+// one row per criterion, in the given locale and brand
+func BuildComparison(in *ComparisonInput) *Comparison {
+	locale := NormalizeLocale(in.Locale)
+	if locale == "" {
+		locale = defaultLocales["de"]
+	}
+	loc := sheetLocales[locale]
+	t := comparisonTexts[loc.lang]
+	now := in.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	now = now.In(timezone.Vienna)
+
+	title := t["title"]
+	footer := ""
+	brand := in.Brand
+	if brand != nil {
+		if brand.CompanyName != "" {
+			title += " – " + brand.CompanyName
+		}
+		footer = brand.Footer
+		if footer == "" {
+			footer = brand.CompanyName
+		}
+	}
+
+	cols := len(in.Programs) + 1
+	note := fmt.Sprintf(t["created"], now.Format(loc.goDateFormat))
+	if in.Search != nil {
+		note += " · " + fmt.Sprintf(t["search"], in.Search.CreatedAt.In(now.Location()).Format(loc.goDateFormat))
+	}
+
+	header := []xlsx.Cell{xlsx.Text(t["criterion"], xlsx.StyleHeader)}
+	for _, f := range in.Programs {
+		header = append(header, xlsx.Text(f.Name, xlsx.StyleHeader))
+	}
+	rows := [][]xlsx.Cell{
+		{xlsx.Text(title, xlsx.StyleTitle)},
+		{xlsx.Text(note, xlsx.StyleNote)},
+		{},
+		header,
+	}
+
+	row := func(label string, value func(f *Foerderung) xlsx.Cell) {
+		cells := []xlsx.Cell{xlsx.Text(t[label], xlsx.StyleLabel)}
+		for _, f := range in.Programs {
+			cells = append(cells, value(f))
+		}
+		rows = append(rows, cells)
+	}
+	text := func(s string) xlsx.Cell { return xlsx.Text(s, xlsx.StyleText) }
+	translated := func(key string) xlsx.Cell {
+		if label, ok := t[key]; ok {
+			return text(label)
+		}
+		return text(key)
+	}
+	list := func(values []string, empty string) xlsx.Cell {
+		if len(values) == 0 {
+			return text(empty)
+		}
+		return text(strings.Join(values, ", "))
+	}
+	rate := func(v *float64) xlsx.Cell {
+		if v == nil {
+			return xlsx.Cell{Style: xlsx.StyleText}
+		}
+		return xlsx.Cell{Value: *v, Style: xlsx.StylePercent}
+	}
+	amount := func(v *int) xlsx.Cell {
+		if v == nil {
+			return xlsx.Cell{Style: xlsx.StyleText}
+		}
+		return xlsx.Cell{Value: *v, Style: xlsx.StyleCurrency}
+	}
+	date := func(v *time.Time) xlsx.Cell {
+		if v == nil {
+			return xlsx.Cell{Style: xlsx.StyleText}
+		}
+		return xlsx.Cell{Value: *v, Style: xlsx.StyleDate}
+	}
+
+	row("provider", func(f *Foerderung) xlsx.Cell { return text(f.Provider) })
+	row("type", func(f *Foerderung) xlsx.Cell { return translated(string(f.Type)) })
+	row("status", func(f *Foerderung) xlsx.Cell { return translated(string(f.Status)) })
+	row("rate_min", func(f *Foerderung) xlsx.Cell { return rate(f.FundingRateMin) })
+	row("rate_max", func(f *Foerderung) xlsx.Cell { return rate(f.FundingRateMax) })
+	row("amount_min", func(f *Foerderung) xlsx.Cell { return amount(f.MinAmount) })
+	row("amount_max", func(f *Foerderung) xlsx.Cell { return amount(f.MaxAmount) })
+	row("deadline", func(f *Foerderung) xlsx.Cell {
+		if f.ApplicationDeadline != nil {
+			return date(f.ApplicationDeadline)
+		}
+		if f.DeadlineType != nil && *f.DeadlineType != DeadlineFixed {
+			return translated(string(*f.DeadlineType))
+		}
+		return text("")
+	})
+	row("days_left", func(f *Foerderung) xlsx.Cell {
+		if f.ApplicationDeadline == nil {
+			return text("")
+		}
+		deadline := f.ApplicationDeadline.In(now.Location())
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		day := time.Date(deadline.Year(), deadline.Month(), deadline.Day(), 0, 0, 0, 0, now.Location())
+		return xlsx.Cell{Value: int(day.Sub(today).Hours() / 24), Style: xlsx.StyleText}
+	})
+	row("call_start", func(f *Foerderung) xlsx.Cell { return date(f.CallStart) })
+	row("call_end", func(f *Foerderung) xlsx.Cell { return date(f.CallEnd) })
+	row("target", func(f *Foerderung) xlsx.Cell {
+		if len(f.TargetSizes) > 0 {
+			sizes := make([]string, len(f.TargetSizes))
+			for i, s := range f.TargetSizes {
+				sizes[i] = string(s)
+				if label, ok := t["size_"+string(s)]; ok {
+					sizes[i] = label
+				}
+			}
+			return list(sizes, t["all"])
+		}
+		if f.TargetSize != nil {
+			return translated(string(*f.TargetSize))
+		}
+		return text(t["all"])
+	})
+	row("states", func(f *Foerderung) xlsx.Cell { return list(f.TargetStates, t["all"]) })
+	row("legal_forms", func(f *Foerderung) xlsx.Cell { return list(f.TargetLegalForms, t["all"]) })
+	row("industries", func(f *Foerderung) xlsx.Cell { return list(f.TargetIndustries, t["all"]) })
+	row("excluded", func(f *Foerderung) xlsx.Cell { return list(f.ExcludedIndustries, "") })
+	row("topics", func(f *Foerderung) xlsx.Cell { return list(f.Topics, "") })
+	row("requirements", func(f *Foerderung) xlsx.Cell { return text(deref(f.Requirements)) })
+
+	if in.Matches != nil {
+		match := func(value func(m *FoerderungsMatch) xlsx.Cell) func(f *Foerderung) xlsx.Cell {
+			return func(f *Foerderung) xlsx.Cell {
+				m, ok := in.Matches[f.ID]
+				if !ok {
+					return xlsx.Text(t["not_matched"], xlsx.StyleText)
+				}
+				return value(m)
+			}
+		}
+		score := func(v float64) xlsx.Cell { return xlsx.Cell{Value: v, Style: xlsx.StylePercent} }
+		llm := func(value func(r *LLMEligibilityResult) xlsx.Cell) func(m *FoerderungsMatch) xlsx.Cell {
+			return func(m *FoerderungsMatch) xlsx.Cell {
+				if m.LLMResult == nil {
+					return text("")
+				}
+				return value(m.LLMResult)
+			}
+		}
+
+		row("score", match(func(m *FoerderungsMatch) xlsx.Cell { return score(m.TotalScore) }))
+		row("rule_score", match(func(m *FoerderungsMatch) xlsx.Cell { return score(m.RuleScore) }))
+		row("llm_score", match(func(m *FoerderungsMatch) xlsx.Cell { return score(m.LLMScore) }))
+		row("eligible", match(llm(func(r *LLMEligibilityResult) xlsx.Cell {
+			answer := t["no"]
+			if r.Eligible {
+				answer = t["yes"]
+			}
+			if confidence, ok := t["confidence_"+r.Confidence]; ok {
+				answer += " (" + confidence + ")"
+			}
+			return text(answer)
+		})))
+		row("matched", match(llm(func(r *LLMEligibilityResult) xlsx.Cell {
+			return text(bullets(append(append([]string{}, r.MatchedCriteria...), r.ImplicitMatches...)))
+		})))
+		row("concerns", match(llm(func(r *LLMEligibilityResult) xlsx.Cell { return text(bullets(r.Concerns)) })))
+		row("estimated", match(llm(func(r *LLMEligibilityResult) xlsx.Cell { return amount(r.EstimatedAmount) })))
+		row("combination", match(llm(func(r *LLMEligibilityResult) xlsx.Cell { return text(deref(r.CombinationHint)) })))
+		row("next_steps", match(llm(func(r *LLMEligibilityResult) xlsx.Cell { return text(bullets(r.NextSteps)) })))
+	}
+
+	row("link", func(f *Foerderung) xlsx.Cell {
+		if f.ApplicationURL != nil {
+			return text(*f.ApplicationURL)
+		}
+		return text(deref(f.URL))
+	})
+
+	widths := []float64{26}
+	for range in.Programs {
+		widths = append(widths, 34)
+	}
+	lastCol := xlsx.ColumnName(cols - 1)
+
+	wb := &xlsx.Workbook{
+		Title: title,
+		Sheets: []*xlsx.Sheet{{
+			Name:          t["title"],
+			Rows:          rows,
+			ColumnWidths:  widths,
+			FreezeRows:    4,
+			FreezeColumns: 1,
+			Merges:        []string{"A1:" + lastCol + "1", "A2:" + lastCol + "2"},
+			Header:        title,
+			Footer:        footer,
+			Landscape:     true,
+		}},
+		CurrencyFormat: loc.currencyFormat,
+		DateFormat:     loc.dateFormat,
+		Created:        now,
+	}
+	if brand != nil {
+		wb.Creator = brand.CompanyName
+		wb.AccentColor = brand.Color
+	}
+
+	return &Comparison{
+		Workbook: wb,
+		Filename: fmt.Sprintf("%s-%s.xlsx", t["file"], now.Format("2006-01-02")),
+	}
+}
+
+// buildComparison loads the programs, the match scores, the branding and
+// the locale of a comparison sheet
+func (h *Handler) buildComparison(ctx context.Context, tenantID uuid.UUID, req *ComparisonRequest) (*Comparison, error) {
+	seen := make(map[uuid.UUID]bool)
+	var ids []uuid.UUID
+	for _, id := range req.FoerderungIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) < MinComparisonPrograms || len(ids) > MaxComparisonPrograms {
+		return nil, ErrComparisonSize
+	}
+
+	in := &ComparisonInput{}
+	if req.Locale != "" {
+		if in.Locale = NormalizeLocale(req.Locale); in.Locale == "" {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedLocale, req.Locale)
+		}
+	} else if h.locales != nil {
+		in.Locale = h.locales.Locale(ctx, tenantID)
+	}
+
+	for _, id := range ids {
+		f, err := h.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrComparisonNotFound, id)
+		}
+		in.Programs = append(in.Programs, f)
+	}
+
+	if req.SearchID != nil {
+		if h.searches == nil {
+			return nil, ErrComparisonSearch
+		}
+		search, err := h.searches.GetByIDAndTenant(ctx, *req.SearchID, tenantID)
+		if err != nil {
+			return nil, ErrComparisonSearch
+		}
+		matches, err := ParseMatches(search)
+		if err != nil {
+			return nil, err
+		}
+		in.Search = search
+		in.Matches = matches
+	}
+
+	if h.brands != nil {
+		// The sheet is usable without the branding; fall back to the default look
+		if brand, err := h.brands.SheetBrand(ctx, tenantID); err == nil {
+			in.Brand = brand
+		}
+	}
+
+	return BuildComparison(in), nil
+}
+
+// ParseMatches returns the matches of a search by program
+func ParseMatches(search *FoerderungsSuche) (map[uuid.UUID]*FoerderungsMatch, error) {
+	matches := make(map[uuid.UUID]*FoerderungsMatch)
+	if len(search.Matches) == 0 {
+		return matches, nil
+	}
+	var list []*FoerderungsMatch
+	if err := json.Unmarshal(search.Matches, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse search matches: %w", err)
+	}
+	for _, m := range list {
+		matches[m.FoerderungID] = m
+	}
+	return matches, nil
+}
+
+func bullets(items []string) string {
+	var lines []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			lines = append(lines, "• "+item)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -2,6 +2,7 @@ package foerderung
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -17,6 +18,9 @@ import (
 type Handler struct {
 	repo               *Repository
 	combinationService *CombinationService
+	brands             BrandProvider
+	searches           SearchSource
+	locales            LocaleSource
 }
 
 // NewHandler creates a new Förderung handler
@@ -27,6 +31,14 @@ func NewHandler(repo *Repository) *Handler {
 	}
 }
 
+// SetComparisonSources sets where comparison sheets take the tenant's
+// branding, match scores and locale from; each may be nil
+func (h *Handler) SetComparisonSources(brands BrandProvider, searches SearchSource, locales LocaleSource) {
+	h.brands = brands
+	h.searches = searches
+	h.locales = locales
+}
+
 // CreateRequest is the request body for creating a Förderung
 type CreateRequest struct {
 	Name        string  `json:"name"`
@@ -56,6 +68,7 @@ type CreateRequest struct {
 	DeadlineType        *string `json:"deadline_type,omitempty"`
 	CallStart           *string `json:"call_start,omitempty"`
 	CallEnd             *string `json:"call_end,omitempty"`
+	StandDatum          *string `json:"stand_datum,omitempty"`
 
 	URL            *string `json:"url,omitempty"`
 	ApplicationURL *string `json:"application_url,omitempty"`
@@ -168,6 +181,14 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		}
 		f.CallEnd = &t
 	}
+	if req.StandDatum != nil {
+		t, err := parseDate(*req.StandDatum)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid stand_datum format")
+			return
+		}
+		f.StandDatum = &t
+	}
 
 	if err := h.repo.Create(r.Context(), f); err != nil {
 		api.RespondError(w, http.StatusInternalServerError, err.Error())
@@ -194,6 +215,9 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	if s := q.Get("status"); s != "" {
 		filter.Status = FoerderungStatus(s)
 	}
+	if q.Get("review_required") == "true" {
+		filter.ReviewRequired = true
+	}
 	if l := q.Get("limit"); l != "" {
 		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
 			filter.Limit = parsed
@@ -366,6 +390,14 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		}
 		f.CallEnd = &t
 	}
+	if req.StandDatum != nil {
+		t, err := parseDate(*req.StandDatum)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid stand_datum format")
+			return
+		}
+		f.StandDatum = &t
+	}
 
 	if err := h.repo.Update(r.Context(), f); err != nil {
 		api.RespondError(w, http.StatusInternalServerError, err.Error())
@@ -469,6 +501,46 @@ func (h *Handler) ValidateCombination(w http.ResponseWriter, r *http.Request) {
 	api.RespondJSON(w, http.StatusOK, validation)
 }
 
+// ExportComparison handles POST /api/v1/foerderungen/compare/export
+func (h *Handler) ExportComparison(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.RespondError(w, http.StatusUnauthorized, "tenant not found in context")
+		return
+	}
+
+	var req ComparisonRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	comparison, err := h.buildComparison(r.Context(), tenantID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrComparisonSize), errors.Is(err, ErrUnsupportedLocale):
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrComparisonNotFound), errors.Is(err, ErrComparisonSearch):
+			api.RespondError(w, http.StatusNotFound, err.Error())
+		default:
+			api.RespondError(w, http.StatusInternalServerError, "failed to export comparison")
+		}
+		return
+	}
+
+	data, err := comparison.Workbook.Bytes()
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "failed to export comparison")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", "attachment; filename="+comparison.Filename)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
 // RegisterRoutes registers foerderung routes with chi router
 func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Route("/foerderungen", func(r chi.Router) {
@@ -476,6 +548,7 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Get("/", h.List)
 		r.Get("/stats", h.GetStats)
 		r.Post("/validate-combination", h.ValidateCombination)
+		r.Post("/compare/export", h.ExportComparison)
 		r.Get("/{id}", h.Get)
 		r.Put("/{id}", h.Update)
 		r.Delete("/{id}", h.Delete)
@@ -53,6 +53,9 @@ type JSONFoerderung struct {
 
 	Status        string `json:"status,omitempty"`
 	IsHighlighted bool   `json:"isHighlighted,omitempty"`
+
+	// StandDatum is the date the provider last confirmed the information
+	StandDatum string `json:"standDatum,omitempty"`
 }
 
 // Importer handles importing Förderungen from JSON files
@@ -258,6 +261,12 @@ func (i *Importer) convertJSONToFoerderung(jf *JSONFoerderung, source string) (*
 			f.CallEnd = &t
 		}
 	}
+	if jf.StandDatum != "" {
+		t, err := parseImportDate(jf.StandDatum)
+		if err == nil {
+			f.StandDatum = &t
+		}
+	}
 
 	// Deadline type
 	if jf.DeadlineType != "" {
@@ -40,8 +40,9 @@ func (r *Repository) Create(ctx context.Context, f *Foerderung) error {
 			url, application_url, guideline_url,
 			combinable_with, not_combinable_with,
 			status, is_highlighted, source, source_id, last_updated_at,
+			stand_datum, review_required_at,
 			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37)
 	`,
 		f.ID, f.Name, f.ShortName, f.Description, f.Provider, f.Type,
 		f.FundingRateMin, f.FundingRateMax, f.MaxAmount, f.MinAmount,
@@ -51,6 +52,7 @@ func (r *Repository) Create(ctx context.Context, f *Foerderung) error {
 		f.URL, f.ApplicationURL, f.GuidelineURL,
 		f.CombinableWith, f.NotCombinableWith,
 		f.Status, f.IsHighlighted, f.Source, f.SourceID, f.LastUpdatedAt,
+		f.StandDatum, f.ReviewRequiredAt,
 		f.CreatedAt, f.UpdatedAt,
 	)
 	if err != nil {
@@ -72,6 +74,7 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*Foerderung, er
 			url, application_url, guideline_url,
 			combinable_with, not_combinable_with,
 			status, is_highlighted, source, source_id, last_updated_at,
+			stand_datum, review_required_at,
 			created_at, updated_at
 		FROM foerderungen
 		WHERE id = $1
@@ -84,6 +87,7 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*Foerderung, er
 		&f.URL, &f.ApplicationURL, &f.GuidelineURL,
 		&f.CombinableWith, &f.NotCombinableWith,
 		&f.Status, &f.IsHighlighted, &f.Source, &f.SourceID, &f.LastUpdatedAt,
+		&f.StandDatum, &f.ReviewRequiredAt,
 		&f.CreatedAt, &f.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
@@ -104,8 +108,10 @@ type ListFilter struct {
 	State    string
 	Topic    string
 	Search   string
-	Limit    int
-	Offset   int
+	// ReviewRequired lists only programs whose stand date is too old
+	ReviewRequired bool
+	Limit          int
+	Offset         int
 }
 
 // List retrieves Förderungen with filters
@@ -125,6 +131,7 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Foerderung
 			url, application_url, guideline_url,
 			combinable_with, not_combinable_with,
 			status, is_highlighted, source, source_id, last_updated_at,
+			stand_datum, review_required_at,
 			created_at, updated_at
 		FROM foerderungen
 		WHERE 1=1
@@ -170,6 +177,10 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Foerderung
 		args = append(args, "%"+filter.Search+"%")
 		argIdx++
 	}
+	if filter.ReviewRequired {
+		query += " AND review_required_at IS NOT NULL"
+		countQuery += " AND review_required_at IS NOT NULL"
+	}
 
 	// Get total count
 	var total int
@@ -199,6 +210,7 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Foerderung
 			&f.URL, &f.ApplicationURL, &f.GuidelineURL,
 			&f.CombinableWith, &f.NotCombinableWith,
 			&f.Status, &f.IsHighlighted, &f.Source, &f.SourceID, &f.LastUpdatedAt,
+			&f.StandDatum, &f.ReviewRequiredAt,
 			&f.CreatedAt, &f.UpdatedAt,
 		); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan foerderung: %w", err)
@@ -220,6 +232,7 @@ func (r *Repository) ListActive(ctx context.Context) ([]*Foerderung, error) {
 			url, application_url, guideline_url,
 			combinable_with, not_combinable_with,
 			status, is_highlighted, source, source_id, last_updated_at,
+			stand_datum, review_required_at,
 			created_at, updated_at
 		FROM foerderungen
 		WHERE status = 'active'
@@ -242,6 +255,7 @@ func (r *Repository) ListActive(ctx context.Context) ([]*Foerderung, error) {
 			&f.URL, &f.ApplicationURL, &f.GuidelineURL,
 			&f.CombinableWith, &f.NotCombinableWith,
 			&f.Status, &f.IsHighlighted, &f.Source, &f.SourceID, &f.LastUpdatedAt,
+			&f.StandDatum, &f.ReviewRequiredAt,
 			&f.CreatedAt, &f.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan foerderung: %w", err)
@@ -252,22 +266,36 @@ func (r *Repository) ListActive(ctx context.Context) ([]*Foerderung, error) {
 	return foerderungen, nil
 }
 
-// Update updates a Förderung
+// Update updates a Förderung. A change of the status is recorded for the
+// watchers, and a new stand date clears the review flag.
 func (r *Repository) Update(ctx context.Context, f *Foerderung) error {
 	f.UpdatedAt = time.Now()
 
-	result, err := r.db.Exec(ctx, `
-		UPDATE foerderungen SET
-			name = $2, short_name = $3, description = $4, provider = $5, type = $6,
-			funding_rate_min = $7, funding_rate_max = $8, max_amount = $9, min_amount = $10,
-			target_size = $11, target_age = $12, target_legal_forms = $13, target_industries = $14, target_states = $15,
-			topics = $16, categories = $17, requirements = $18, eligibility_criteria = $19,
-			application_deadline = $20, deadline_type = $21, call_start = $22, call_end = $23,
-			url = $24, application_url = $25, guideline_url = $26,
-			combinable_with = $27, not_combinable_with = $28,
-			status = $29, is_highlighted = $30, source = $31, source_id = $32, last_updated_at = $33,
-			updated_at = $34
-		WHERE id = $1
+	err := r.db.QueryRow(ctx, `
+		WITH old AS (
+			SELECT id, status FROM foerderungen WHERE id = $1
+		), updated AS (
+			UPDATE foerderungen SET
+				name = $2, short_name = $3, description = $4, provider = $5, type = $6,
+				funding_rate_min = $7, funding_rate_max = $8, max_amount = $9, min_amount = $10,
+				target_size = $11, target_age = $12, target_legal_forms = $13, target_industries = $14, target_states = $15,
+				topics = $16, categories = $17, requirements = $18, eligibility_criteria = $19,
+				application_deadline = $20, deadline_type = $21, call_start = $22, call_end = $23,
+				url = $24, application_url = $25, guideline_url = $26,
+				combinable_with = $27, not_combinable_with = $28,
+				status = $29, is_highlighted = $30, source = $31, source_id = $32, last_updated_at = $33,
+				stand_datum = $34::date,
+				review_required_at = CASE WHEN stand_datum IS DISTINCT FROM $34::date THEN NULL ELSE review_required_at END,
+				updated_at = $35
+			WHERE id = $1
+			RETURNING id, status, review_required_at
+		), changed AS (
+			INSERT INTO foerderung_status_changes (foerderung_id, old_status, new_status, reason)
+			SELECT updated.id, old.status, updated.status, $36
+			FROM updated JOIN old ON old.id = updated.id
+			WHERE old.status IS DISTINCT FROM updated.status
+		)
+		SELECT review_required_at FROM updated
 	`,
 		f.ID, f.Name, f.ShortName, f.Description, f.Provider, f.Type,
 		f.FundingRateMin, f.FundingRateMax, f.MaxAmount, f.MinAmount,
@@ -277,51 +305,175 @@ func (r *Repository) Update(ctx context.Context, f *Foerderung) error {
 		f.URL, f.ApplicationURL, f.GuidelineURL,
 		f.CombinableWith, f.NotCombinableWith,
 		f.Status, f.IsHighlighted, f.Source, f.SourceID, f.LastUpdatedAt,
-		f.UpdatedAt,
-	)
+		f.StandDatum,
+		f.UpdatedAt, ReasonUpdated,
+	).Scan(&f.ReviewRequiredAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("foerderung not found")
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update foerderung: %w", err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("foerderung not found")
-	}
-
 	return nil
 }
 
 // Delete deletes a Förderung (soft delete by setting status to closed)
 func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
-	result, err := r.db.Exec(ctx, `
-		UPDATE foerderungen SET status = 'closed', updated_at = $2 WHERE id = $1
-	`, id, time.Now())
+	var found bool
+	err := r.db.QueryRow(ctx, `
+		WITH old AS (
+			SELECT id, status FROM foerderungen WHERE id = $1
+		), updated AS (
+			UPDATE foerderungen SET status = 'closed', updated_at = $2 WHERE id = $1
+			RETURNING id
+		), changed AS (
+			INSERT INTO foerderung_status_changes (foerderung_id, old_status, new_status, reason)
+			SELECT old.id, old.status, 'closed', $3
+			FROM old JOIN updated ON updated.id = old.id
+			WHERE old.status <> 'closed'
+		)
+		SELECT EXISTS (SELECT 1 FROM updated)
+	`, id, time.Now(), ReasonDeleted).Scan(&found)
 	if err != nil {
 		return fmt.Errorf("failed to delete foerderung: %w", err)
 	}
 
-	if result.RowsAffected() == 0 {
+	if !found {
 		return fmt.Errorf("foerderung not found")
 	}
 
 	return nil
 }
 
-// ExpireOverdue marks Förderungen past their deadline as closed
-func (r *Repository) ExpireOverdue(ctx context.Context) (int, error) {
+// ExpireOverdue closes the Förderungen whose application deadline, or
+// without one the end of the call, is before today and records the status
+// changes for the watchers. Deadlines are Austrian calendar days, so today
+// is taken in Europe/Vienna (see timezone.Today).
+func (r *Repository) ExpireOverdue(ctx context.Context, today time.Time) (int, error) {
+	var closed int
+	err := r.db.QueryRow(ctx, `
+		WITH overdue AS (
+			SELECT id, status FROM foerderungen
+			WHERE status <> 'closed'
+			  AND COALESCE(application_deadline, call_end) < $1::date
+			FOR UPDATE
+		), updated AS (
+			UPDATE foerderungen f SET status = 'closed', updated_at = NOW()
+			FROM overdue
+			WHERE f.id = overdue.id
+			RETURNING f.id, overdue.status AS old_status
+		), changed AS (
+			INSERT INTO foerderung_status_changes (foerderung_id, old_status, new_status, reason)
+			SELECT id, old_status, 'closed', $2 FROM updated
+		)
+		SELECT COUNT(*) FROM updated
+	`, today, ReasonDeadlinePassed).Scan(&closed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire foerderungen: %w", err)
+	}
+
+	return closed, nil
+}
+
+// FlagForReview flags the open Förderungen whose stand date, or without one
+// their last update, is before cutoff as needing a review
+func (r *Repository) FlagForReview(ctx context.Context, cutoff, now time.Time) (int, error) {
 	result, err := r.db.Exec(ctx, `
 		UPDATE foerderungen
-		SET status = 'closed', updated_at = NOW()
-		WHERE status = 'active'
-		  AND application_deadline IS NOT NULL
-		  AND application_deadline < CURRENT_DATE
-	`)
+		SET review_required_at = $2
+		WHERE status <> 'closed'
+		  AND review_required_at IS NULL
+		  AND COALESCE(stand_datum, last_updated_at::date, created_at::date) < $1::date
+	`, cutoff, now)
 	if err != nil {
-		return 0, fmt.Errorf("failed to expire foerderungen: %w", err)
+		return 0, fmt.Errorf("failed to flag foerderungen for review: %w", err)
 	}
 
 	return int(result.RowsAffected()), nil
 }
 
+// ListPendingStatusChanges returns the status changes whose watchers were
+// not notified yet, oldest first
+func (r *Repository) ListPendingStatusChanges(ctx context.Context) ([]*StatusChange, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT c.id, c.foerderung_id, f.name, c.old_status, c.new_status, c.reason, c.changed_at
+		FROM foerderung_status_changes c
+		JOIN foerderungen f ON f.id = c.foerderung_id
+		WHERE c.notified_at IS NULL
+		ORDER BY c.changed_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending status changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []*StatusChange
+	for rows.Next() {
+		var c StatusChange
+		if err := rows.Scan(&c.ID, &c.FoerderungID, &c.FoerderungName, &c.OldStatus, &c.NewStatus, &c.Reason, &c.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan status change: %w", err)
+		}
+		changes = append(changes, &c)
+	}
+
+	return changes, rows.Err()
+}
+
+// ListWatchers returns the watchers of the given Förderungen: the creators
+// of open Anträge and the admins of tenants whose active monitor with
+// email notifications matched the program, unless they dismissed it
+func (r *Repository) ListWatchers(ctx context.Context, foerderungIDs []uuid.UUID) ([]*Watcher, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT w.foerderung_id, u.tenant_id, u.name, u.email
+		FROM (
+			SELECT a.foerderung_id, a.created_by AS user_id
+			FROM foerderungs_antraege a
+			WHERE a.foerderung_id = ANY($1)
+			  AND a.status IN ('planned', 'drafting', 'submitted', 'in_review')
+			  AND a.created_by IS NOT NULL
+			UNION
+			SELECT n.foerderung_id, admin.id
+			FROM monitor_notifications n
+			JOIN profil_monitore m ON m.id = n.monitor_id
+			JOIN users admin ON admin.tenant_id = m.tenant_id AND admin.role IN ('owner', 'admin')
+			WHERE n.foerderung_id = ANY($1)
+			  AND n.dismissed IS NOT TRUE
+			  AND m.is_active AND m.notification_email
+		) w
+		JOIN users u ON u.id = w.user_id
+		WHERE u.is_active
+		ORDER BY u.tenant_id, u.email
+	`, foerderungIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watchers: %w", err)
+	}
+	defer rows.Close()
+
+	var watchers []*Watcher
+	for rows.Next() {
+		var w Watcher
+		if err := rows.Scan(&w.FoerderungID, &w.TenantID, &w.Name, &w.Email); err != nil {
+			return nil, fmt.Errorf("failed to scan watcher: %w", err)
+		}
+		watchers = append(watchers, &w)
+	}
+
+	return watchers, rows.Err()
+}
+
+// MarkStatusChangesNotified marks status changes as notified
+func (r *Repository) MarkStatusChangesNotified(ctx context.Context, ids []uuid.UUID, now time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE foerderung_status_changes SET notified_at = $2 WHERE id = ANY($1)
+	`, ids, now)
+	if err != nil {
+		return fmt.Errorf("failed to mark status changes notified: %w", err)
+	}
+
+	return nil
+}
+
 // GetBySourceID retrieves a Förderung by source and source_id (for imports)
 func (r *Repository) GetBySourceID(ctx context.Context, source, sourceID string) (*Foerderung, error) {
 	var f Foerderung
@@ -334,6 +486,7 @@ func (r *Repository) GetBySourceID(ctx context.Context, source, sourceID string)
 			url, application_url, guideline_url,
 			combinable_with, not_combinable_with,
 			status, is_highlighted, source, source_id, last_updated_at,
+			stand_datum, review_required_at,
 			created_at, updated_at
 		FROM foerderungen
 		WHERE source = $1 AND source_id = $2
@@ -346,6 +499,7 @@ func (r *Repository) GetBySourceID(ctx context.Context, source, sourceID string)
 		&f.URL, &f.ApplicationURL, &f.GuidelineURL,
 		&f.CombinableWith, &f.NotCombinableWith,
 		&f.Status, &f.IsHighlighted, &f.Source, &f.SourceID, &f.LastUpdatedAt,
+		&f.StandDatum, &f.ReviewRequiredAt,
 		&f.CreatedAt, &f.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
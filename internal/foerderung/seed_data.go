@@ -1524,6 +1524,9 @@ func ConvertSeedToFoerderung(seed SeedFoerderung) *Foerderung {
 	// Set source info
 	fd.Source = &seed.ID
 	fd.SourceID = &seed.ID
+	if t, err := parseImportDate(seed.StandDatum); err == nil {
+		fd.StandDatum = &t
+	}
 
 	// Set status
 	if !seed.Active {
@@ -0,0 +1,221 @@
+package foerderung
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/timezone"
+)
+
+// Reasons of a status change
+const (
+	ReasonDeadlinePassed = "deadline_passed"
+	ReasonUpdated        = "updated"
+	ReasonDeleted        = "deleted"
+)
+
+// DefaultReviewAfterMonths is the age of the stand date after which a
+// program is flagged for review
+const DefaultReviewAfterMonths = 6
+
+// statusLabels and reasonLabels name statuses and reasons in the mails
+var (
+	statusLabels = map[FoerderungStatus]string{
+		StatusActive:   "aktiv",
+		StatusUpcoming: "demnächst",
+		StatusPaused:   "pausiert",
+		StatusClosed:   "geschlossen",
+	}
+	reasonLabels = map[string]string{
+		ReasonDeadlinePassed: "Einreichfrist abgelaufen",
+		ReasonUpdated:        "Programm aktualisiert",
+		ReasonDeleted:        "Programm eingestellt",
+	}
+)
+
+// StatusChange is a recorded change of the status of a Förderung
+type StatusChange struct {
+	ID             uuid.UUID        `json:"id"`
+	FoerderungID   uuid.UUID        `json:"foerderung_id"`
+	FoerderungName string           `json:"foerderung_name"`
+	OldStatus      FoerderungStatus `json:"old_status"`
+	NewStatus      FoerderungStatus `json:"new_status"`
+	Reason         string           `json:"reason"`
+	ChangedAt      time.Time        `json:"changed_at"`
+}
+
+// Watcher is a user notified of the status changes of a Förderung
+type Watcher struct {
+	FoerderungID uuid.UUID
+	TenantID     uuid.UUID
+	Name         string
+	Email        string
+}
+
+// StatusNotifier mails watchers about status changes; email.Service
+// implements it
+type StatusNotifier interface {
+	SendFoerderungStatusChanged(ctx context.Context, to string, params email.FoerderungStatusParams) error
+}
+
+// SyncResult is the result of a status sync
+type SyncResult struct {
+	Closed  int `json:"closed"`
+	Flagged int `json:"flagged"`
+}
+
+// StatusSync keeps the status of the Förderungen current: it closes
+// programs whose deadline passed, flags programs with an old stand date for
+// review and notifies the watchers of status changes
+type StatusSync struct {
+	repo              *Repository
+	notifier          StatusNotifier
+	appURL            string
+	reviewAfterMonths int
+	now               func() time.Time
+}
+
+// NewStatusSync creates a status sync flagging programs whose stand date is
+// older than reviewAfterMonths (DefaultReviewAfterMonths if not positive)
+func NewStatusSync(repo *Repository, reviewAfterMonths int) *StatusSync {
+	if reviewAfterMonths <= 0 {
+		reviewAfterMonths = DefaultReviewAfterMonths
+	}
+	return &StatusSync{repo: repo, reviewAfterMonths: reviewAfterMonths, now: time.Now}
+}
+
+// SetNotifier enables the mail to watchers about status changes
+func (s *StatusSync) SetNotifier(notifier StatusNotifier, appURL string) {
+	s.notifier = notifier
+	s.appURL = strings.TrimSuffix(appURL, "/")
+}
+
+// ReviewCutoff returns the stand date before which a program needs a
+// review on the Vienna calendar day of now
+func (s *StatusSync) ReviewCutoff(now time.Time) time.Time {
+	return timezone.Today(now, timezone.Vienna).AddDate(0, -s.reviewAfterMonths, 0)
+}
+
+// Run closes the Förderungen whose deadline passed and flags those with an
+// old stand date for review
+func (s *StatusSync) Run(ctx context.Context) (*SyncResult, error) {
+	now := s.now()
+
+	closed, err := s.repo.ExpireOverdue(ctx, timezone.Today(now, timezone.Vienna))
+	if err != nil {
+		return nil, err
+	}
+	flagged, err := s.repo.FlagForReview(ctx, s.ReviewCutoff(now), now)
+	if err != nil {
+		return &SyncResult{Closed: closed}, err
+	}
+
+	return &SyncResult{Closed: closed, Flagged: flagged}, nil
+}
+
+// NotifyWatchers mails every watcher one summary of the pending status
+// changes of the Förderungen they watch and returns the number of mails
+// sent. The changes are marked notified even if a mail failed, so the
+// other watchers are not mailed twice.
+func (s *StatusSync) NotifyWatchers(ctx context.Context) (int, error) {
+	changes, err := s.repo.ListPendingStatusChanges(ctx)
+	if err != nil || len(changes) == 0 {
+		return 0, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(changes))
+	for _, c := range changes {
+		ids = append(ids, c.ID)
+	}
+
+	var sent int
+	var errs []error
+	if s.notifier != nil {
+		mails, err := s.collectMails(ctx, changes)
+		if err != nil {
+			return 0, err
+		}
+		for _, m := range mails {
+			if err := s.notifier.SendFoerderungStatusChanged(ctx, m.to, m.params); err != nil {
+				errs = append(errs, fmt.Errorf("failed to mail %s: %w", m.to, err))
+				continue
+			}
+			sent++
+		}
+	}
+
+	if err := s.repo.MarkStatusChangesNotified(ctx, ids, s.now()); err != nil {
+		errs = append(errs, err)
+	}
+	return sent, errors.Join(errs...)
+}
+
+type statusMail struct {
+	to     string
+	params email.FoerderungStatusParams
+}
+
+// collectMails groups the changes by watcher, one mail per tenant and
+// address
+func (s *StatusSync) collectMails(ctx context.Context, changes []*StatusChange) ([]*statusMail, error) {
+	byFoerderung := make(map[uuid.UUID][]*StatusChange)
+	var foerderungIDs []uuid.UUID
+	for _, c := range changes {
+		if _, ok := byFoerderung[c.FoerderungID]; !ok {
+			foerderungIDs = append(foerderungIDs, c.FoerderungID)
+		}
+		byFoerderung[c.FoerderungID] = append(byFoerderung[c.FoerderungID], c)
+	}
+
+	watchers, err := s.repo.ListWatchers(ctx, foerderungIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var mails []*statusMail
+	byRecipient := make(map[string]*statusMail)
+	for _, w := range watchers {
+		key := w.TenantID.String() + "/" + w.Email
+		m, ok := byRecipient[key]
+		if !ok {
+			tenantID := w.TenantID
+			m = &statusMail{to: w.Email, params: email.FoerderungStatusParams{TenantID: &tenantID, RecipientName: w.Name}}
+			if s.appURL != "" {
+				m.params.FoerderungenURL = s.appURL + "/foerderungen"
+			}
+			byRecipient[key] = m
+			mails = append(mails, m)
+		}
+		for _, c := range byFoerderung[w.FoerderungID] {
+			m.params.Changes = append(m.params.Changes, s.mailChange(c))
+		}
+	}
+	return mails, nil
+}
+
+// mailChange describes a status change in German for the mail
+func (s *StatusSync) mailChange(c *StatusChange) email.FoerderungStatusChange {
+	change := email.FoerderungStatusChange{
+		Name:      c.FoerderungName,
+		OldStatus: label(statusLabels, c.OldStatus),
+		NewStatus: label(statusLabels, c.NewStatus),
+		Reason:    label(reasonLabels, c.Reason),
+	}
+	if s.appURL != "" {
+		change.URL = s.appURL + "/foerderungen/" + c.FoerderungID.String()
+	}
+	return change
+}
+
+func label[K ~string](labels map[K]string, key K) string {
+	if l, ok := labels[key]; ok {
+		return l
+	}
+	return string(key)
+}
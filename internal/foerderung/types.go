@@ -148,6 +148,11 @@ type Foerderung struct {
 	SourceID      *string    `json:"source_id,omitempty"`
 	LastUpdatedAt *time.Time `json:"last_updated_at,omitempty"`
 
+	// Review: the date the provider last confirmed the information, and
+	// since when it is too old and needs a review
+	StandDatum       *time.Time `json:"stand_datum,omitempty"`
+	ReviewRequiredAt *time.Time `json:"review_required_at,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
@@ -337,6 +342,12 @@ type FoerderungsAntrag struct {
 	Timeline    []TimelineEntry  `json:"timeline,omitempty"`
 	Notes       *string          `json:"notes,omitempty"`
 
+	// Tenant-defined fields, see the customfield package
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+
+	// Advisor working on the application; the creator if unset
+	AdvisorID *uuid.UUID `json:"advisor_id,omitempty"`
+
 	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
@@ -2,11 +2,17 @@ package fonws
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
+
+	"austrian-business-infrastructure/internal/rawpayload"
+	"austrian-business-infrastructure/pkg/httpclient"
 )
 
 const (
@@ -28,6 +34,7 @@ const (
 	// Retry settings
 	DefaultMaxRetries   = 3
 	DefaultRetryBackoff = 1 * time.Second
+	DefaultBudget       = 2 * time.Minute
 )
 
 // SOAPEnvelope represents a SOAP envelope for requests
@@ -53,29 +60,43 @@ type SOAPResponseBody struct {
 	Content []byte `xml:",innerxml"`
 }
 
+// EndpointResolver returns the base URL to use instead of BaseURL, e.g.
+// after a switchover to a migrated endpoint. It fails during maintenance
+// windows, so that no request is sent.
+type EndpointResolver func() (string, error)
+
 // Client is the SOAP HTTP client for FinanzOnline WebService
 type Client struct {
-	httpClient   *http.Client
-	verbose      bool
-	maxRetries   int
-	retryBackoff time.Duration
+	httpClient *httpclient.Client
+	verbose    bool
+	recorder   *rawpayload.Recorder
+	resolve    EndpointResolver
 }
 
 // NewClient creates a new SOAP client
 func NewClient() *Client {
-	return &Client{
-		httpClient: &http.Client{
-			Timeout: DefaultTimeout,
-		},
-		maxRetries:   DefaultMaxRetries,
-		retryBackoff: DefaultRetryBackoff,
-	}
+	c := &Client{}
+	c.SetRetry(DefaultMaxRetries, DefaultRetryBackoff)
+	return c
 }
 
-// SetRetry configures retry behavior for network operations
+// SetRetry configures retry behavior for network operations: transient
+// failures are retried maxRetries times, waiting backoff and doubling it
 func (c *Client) SetRetry(maxRetries int, backoff time.Duration) {
-	c.maxRetries = maxRetries
-	c.retryBackoff = backoff
+	cfg := httpclient.DefaultConfig("finanzonline")
+	cfg.Timeout = DefaultTimeout
+	cfg.Budget = DefaultBudget
+	cfg.MaxRetries = maxRetries
+	cfg.Backoff.Initial = backoff
+	cfg.Retryable = httpclient.RetryServerErrors
+	cfg.Hooks.BeforeAttempt = c.resolveAttempt
+	cfg.Hooks.AfterAttempt = httpclient.LogAttempts(nil)
+	c.httpClient = httpclient.New(cfg)
+}
+
+// SetEndpointResolver makes the client resolve the base URL per request
+func (c *Client) SetEndpointResolver(r EndpointResolver) {
+	c.resolve = r
 }
 
 // SetVerbose enables verbose logging
@@ -83,6 +104,15 @@ func (c *Client) SetVerbose(v bool) {
 	c.verbose = v
 }
 
+// WithRecorder returns a copy of the client that records raw request and
+// response bodies into rec. Use it for submissions only, never for login,
+// since the session request carries the PIN.
+func (c *Client) WithRecorder(rec *rawpayload.Recorder) *Client {
+	clone := *c
+	clone.recorder = rec
+	return &clone
+}
+
 // BuildEnvelope creates a SOAP envelope containing the given request body
 func BuildEnvelope(body interface{}) ([]byte, error) {
 	envelope := SOAPEnvelope{
@@ -110,40 +140,50 @@ func (c *Client) Post(url string, requestBody interface{}) ([]byte, error) {
 		return nil, err
 	}
 
-	return c.postWithRetry(url, envelope)
+	return c.post(url, envelope)
 }
 
-// postWithRetry executes an HTTP POST with exponential backoff retry
-func (c *Client) postWithRetry(url string, envelope []byte) ([]byte, error) {
-	var lastErr error
-
-	for attempt := 0; attempt <= c.maxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff: 1s, 2s, 4s, ...
-			backoff := c.retryBackoff * time.Duration(1<<(attempt-1))
-			time.Sleep(backoff)
-		}
-
-		body, err := c.doPost(url, envelope)
-		if err == nil {
-			return body, nil
-		}
-
-		lastErr = err
-
-		// Only retry on transient network errors, not on HTTP 4xx errors
-		if !isRetryableError(err) {
-			return nil, err
-		}
+// resolveAttempt moves the request of each attempt to the resolved base URL.
+// It is resolved per attempt, since a maintenance window may have started.
+func (c *Client) resolveAttempt(req *http.Request) error {
+	target, err := c.resolveURL(req.URL.String())
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint: %w", err)
 	}
+	req.URL = u
+	req.Host = u.Host
+	return nil
+}
 
-	return nil, fmt.Errorf("request failed after %d retries: %w", c.maxRetries, lastErr)
+// resolveURL moves a URL below BaseURL to the resolved base URL
+func (c *Client) resolveURL(url string) (string, error) {
+	if c.resolve == nil {
+		return url, nil
+	}
+	base, err := c.resolve()
+	if err != nil {
+		return "", err
+	}
+	if rest, ok := strings.CutPrefix(url, BaseURL); ok {
+		return strings.TrimSuffix(base, "/") + rest, nil
+	}
+	return url, nil
 }
 
-// doPost performs a single HTTP POST request
-func (c *Client) doPost(url string, envelope []byte) ([]byte, error) {
+// post sends a SOAP request, retrying transient failures, and records every
+// attempt if the client has a recorder
+func (c *Client) post(url string, envelope []byte) ([]byte, error) {
+	ctx := context.Background()
+	if c.recorder != nil {
+		ctx = httpclient.WithObserver(ctx, c.record)
+	}
+
 	// Create HTTP request
-	req, err := http.NewRequest("POST", url, bytes.NewReader(envelope))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(envelope))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -172,6 +212,27 @@ func (c *Client) doPost(url string, envelope []byte) ([]byte, error) {
 	return body, nil
 }
 
+// record stores an attempt in the recorder of the client
+func (c *Client) record(a *httpclient.Attempt) {
+	ex := rawpayload.Exchange{
+		Operation: a.Request.URL.String(),
+		Endpoint:  a.Request.URL.String(),
+		Request:   a.RequestBody,
+		Response:  a.ResponseBody,
+		StartedAt: a.StartedAt,
+		Duration:  a.Duration,
+	}
+	if a.Err != nil {
+		ex.Error = a.Err.Error()
+	} else {
+		ex.StatusCode = a.Response.StatusCode
+		if ex.StatusCode != http.StatusOK {
+			ex.Error = (&HTTPError{StatusCode: ex.StatusCode, Body: string(a.ResponseBody)}).Error()
+		}
+	}
+	c.recorder.Record(ex)
+}
+
 // HTTPError represents an HTTP error response
 type HTTPError struct {
 	StatusCode int
@@ -182,18 +243,6 @@ func (e *HTTPError) Error() string {
 	return fmt.Sprintf("HTTP error %d: %s", e.StatusCode, e.Body)
 }
 
-// isRetryableError determines if an error should trigger a retry
-func isRetryableError(err error) bool {
-	// Retry on network errors (connection refused, timeout, etc.)
-	if _, ok := err.(*HTTPError); ok {
-		httpErr := err.(*HTTPError)
-		// Retry on 5xx server errors and 429 (rate limit)
-		return httpErr.StatusCode >= 500 || httpErr.StatusCode == 429
-	}
-	// Retry on other errors (likely network issues)
-	return true
-}
-
 // ParseResponse extracts the inner content from a SOAP response and unmarshals it
 func ParseResponse(responseBody []byte, result interface{}) error {
 	// Parse the SOAP envelope
@@ -210,6 +259,23 @@ func ParseResponse(responseBody []byte, result interface{}) error {
 	return nil
 }
 
+// Caller makes FinanzOnline SOAP calls. *Client implements it against the
+// web service; internal/fakes provides a scripted in-memory implementation.
+type Caller interface {
+	Call(url string, request interface{}, response interface{}) error
+}
+
+var _ Caller = (*Client)(nil)
+
+// Recording returns a caller that records raw request and response bodies
+// into rec, see Client.WithRecorder. Other callers are returned unchanged.
+func Recording(c Caller, rec *rawpayload.Recorder) Caller {
+	if client, ok := c.(*Client); ok {
+		return client.WithRecorder(rec)
+	}
+	return c
+}
+
 // Call makes a SOAP call and parses the response into the result
 func (c *Client) Call(url string, request interface{}, response interface{}) error {
 	body, err := c.Post(url, request)
@@ -88,11 +88,11 @@ type DataboxDownload struct {
 
 // DataboxService handles databox operations
 type DataboxService struct {
-	client *Client
+	client Caller
 }
 
 // NewDataboxService creates a new databox service
-func NewDataboxService(client *Client) *DataboxService {
+func NewDataboxService(client Caller) *DataboxService {
 	return &DataboxService{client: client}
 }
 
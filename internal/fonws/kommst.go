@@ -0,0 +1,139 @@
+package fonws
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KommSt is an annual Kommunalsteuererklärung (§ 11 KommStG). It lists the
+// Bemessungsgrundlage and the Kommunalsteuer of each month for every
+// Gemeinde the employer has a Betriebsstätte in.
+type KommSt struct {
+	Year      int
+	Gemeinden []KommStGemeinde
+}
+
+// KommStGemeinde is the part of a Kommunalsteuererklärung for a Gemeinde
+type KommStGemeinde struct {
+	Gemeindekennziffer string // 5 digits
+	Monate             []KommStMonat
+}
+
+// KommStMonat is a month of a Gemeinde
+type KommStMonat struct {
+	Monat               int   // 1-12
+	Bemessungsgrundlage int64 // In cents
+	Steuer              int64 // In cents
+}
+
+// Kommunalsteuererklärung XML structures for FinanzOnline
+type kommStXML struct {
+	XMLName   xml.Name            `xml:"Kommunalsteuererklaerung"`
+	Jahr      int                 `xml:"Jahr"`
+	Gemeinden []kommStGemeindeXML `xml:"Gemeinde"`
+	Summe     kommStSummeXML      `xml:"Summe"`
+}
+
+type kommStGemeindeXML struct {
+	Gemeindekennziffer  string           `xml:"Gemeindekennziffer"`
+	Monate              []kommStMonatXML `xml:"Monat"`
+	Bemessungsgrundlage string           `xml:"Bemessungsgrundlage"`
+	Kommunalsteuer      string           `xml:"Kommunalsteuer"`
+}
+
+type kommStMonatXML struct {
+	Nr                  string `xml:"nr,attr"`
+	Bemessungsgrundlage string `xml:"Bemessungsgrundlage"`
+	Kommunalsteuer      string `xml:"Kommunalsteuer"`
+}
+
+type kommStSummeXML struct {
+	Bemessungsgrundlage string `xml:"Bemessungsgrundlage"`
+	Kommunalsteuer      string `xml:"Kommunalsteuer"`
+}
+
+// Validate validates the Kommunalsteuererklärung
+func (k *KommSt) Validate() error {
+	if k.Year < 2000 || k.Year > 2100 {
+		return fmt.Errorf("invalid year: %d", k.Year)
+	}
+	if len(k.Gemeinden) == 0 {
+		return errors.New("Kommunalsteuererklärung must have at least one Gemeinde")
+	}
+	for _, g := range k.Gemeinden {
+		if len(g.Gemeindekennziffer) != 5 || strings.Trim(g.Gemeindekennziffer, "0123456789") != "" {
+			return fmt.Errorf("invalid Gemeindekennziffer: %q", g.Gemeindekennziffer)
+		}
+		for _, m := range g.Monate {
+			if m.Monat < 1 || m.Monat > 12 {
+				return fmt.Errorf("Gemeinde %s: invalid month %d", g.Gemeindekennziffer, m.Monat)
+			}
+			if m.Bemessungsgrundlage < 0 || m.Steuer < 0 {
+				return fmt.Errorf("Gemeinde %s, month %d: amounts must not be negative", g.Gemeindekennziffer, m.Monat)
+			}
+		}
+	}
+	return nil
+}
+
+// GenerateKommStXML generates the XML of a Kommunalsteuererklärung.
+// Amounts are in EUR with two decimals.
+func GenerateKommStXML(k *KommSt) ([]byte, error) {
+	if err := k.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	doc := kommStXML{Jahr: k.Year}
+	var totalBase, totalTax int64
+	for _, g := range k.Gemeinden {
+		gx := kommStGemeindeXML{Gemeindekennziffer: g.Gemeindekennziffer}
+		var base, tax int64
+		for _, m := range g.Monate {
+			gx.Monate = append(gx.Monate, kommStMonatXML{
+				Nr:                  fmt.Sprintf("%02d", m.Monat),
+				Bemessungsgrundlage: FormatCents(m.Bemessungsgrundlage),
+				Kommunalsteuer:      FormatCents(m.Steuer),
+			})
+			base += m.Bemessungsgrundlage
+			tax += m.Steuer
+		}
+		gx.Bemessungsgrundlage = FormatCents(base)
+		gx.Kommunalsteuer = FormatCents(tax)
+		doc.Gemeinden = append(doc.Gemeinden, gx)
+		totalBase += base
+		totalTax += tax
+	}
+	doc.Summe = kommStSummeXML{Bemessungsgrundlage: FormatCents(totalBase), Kommunalsteuer: FormatCents(totalTax)}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal XML: %w", err)
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// FormatCents formats an amount in cents as EUR with two decimals, e.g.
+// 123456 as "1234.56"
+func FormatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// SubmitKommSt submits a Kommunalsteuererklärung to FinanzOnline, which
+// forwards it to the Gemeinden. If FinanzOnline answers with an error code
+// the response is returned with the error.
+func (s *FileUploadService) SubmitKommSt(sessionID, tid, benid string, k *KommSt) (*FileUploadResponse, error) {
+	xmlData, err := GenerateKommStXML(k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate Kommunalsteuererklärung XML: %w", err)
+	}
+
+	return s.Upload(sessionID, tid, benid, "KOMMST", xmlData)
+}
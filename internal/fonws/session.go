@@ -51,11 +51,11 @@ type Session struct {
 
 // SessionService handles session operations
 type SessionService struct {
-	client *Client
+	client Caller
 }
 
 // NewSessionService creates a new session service
-func NewSessionService(client *Client) *SessionService {
+func NewSessionService(client Caller) *SessionService {
 	return &SessionService{client: client}
 }
 
@@ -0,0 +1,81 @@
+package fonws
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// U1 is an annual Umsatzsteuererklärung. Its Kennzahlen are those of the
+// UVA, summed up for the year; amounts are in cents.
+type U1 struct {
+	Year       int
+	Kennzahlen map[string]int64 // e.g. "KZ017"; KZ095 may be negative
+}
+
+// u1KennzahlenOrder is the order of the Kennzahlen in the XML
+var u1KennzahlenOrder = []string{
+	"KZ000", "KZ001", "KZ011", "KZ017", "KZ018", "KZ019", "KZ020",
+	"KZ022", "KZ029", "KZ060", "KZ065", "KZ066", "KZ070", "KZ095",
+}
+
+// Umsatzsteuererklärung XML structures for FinanzOnline
+type u1XML struct {
+	XMLName    xml.Name        `xml:"Umsatzsteuererklaerung"`
+	XMLNS      string          `xml:"xmlns,attr"`
+	Zeitraum   u1ZeitraumXML   `xml:"Zeitraum"`
+	Kennzahlen []u1KennzahlXML `xml:"Kennzahlen>Kennzahl"`
+}
+
+type u1ZeitraumXML struct {
+	Jahr int `xml:"Jahr"`
+}
+
+type u1KennzahlXML struct {
+	Nr     string `xml:"nr,attr"`
+	Betrag string `xml:",chardata"`
+}
+
+// Validate validates the Umsatzsteuererklärung
+func (u *U1) Validate() error {
+	if u.Year < 2000 || u.Year > 2100 {
+		return fmt.Errorf("invalid year: %d", u.Year)
+	}
+	known := make(map[string]bool, len(u1KennzahlenOrder))
+	for _, kz := range u1KennzahlenOrder {
+		known[kz] = true
+	}
+	for kz, v := range u.Kennzahlen {
+		if !known[kz] {
+			return fmt.Errorf("unknown Kennzahl %s", kz)
+		}
+		if v < 0 && kz != "KZ095" {
+			return fmt.Errorf("%s must be non-negative", kz)
+		}
+	}
+	return nil
+}
+
+// GenerateU1XML generates the XML of an Umsatzsteuererklärung. Kennzahlen
+// without an amount are left out; amounts are in EUR with two decimals.
+func GenerateU1XML(u *U1) ([]byte, error) {
+	if err := u.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	doc := u1XML{
+		XMLNS:    "http://www.bmf.gv.at/steuern/fon/u1",
+		Zeitraum: u1ZeitraumXML{Jahr: u.Year},
+	}
+	for _, kz := range u1KennzahlenOrder {
+		if v := u.Kennzahlen[kz]; v != 0 {
+			doc.Kennzahlen = append(doc.Kennzahlen, u1KennzahlXML{Nr: kz[2:], Betrag: FormatCents(v)})
+		}
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal XML: %w", err)
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
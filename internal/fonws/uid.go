@@ -31,6 +31,26 @@ var uidPatterns = map[string]*regexp.Regexp{
 	"GB": regexp.MustCompile(`^GB\d{9}(\d{3})?$`), // UK: GB + 9 or 12 digits
 	"PL": regexp.MustCompile(`^PL\d{10}$`),      // Poland: PL + 10 digits
 	"CH": regexp.MustCompile(`^CHE\d{9}$`),      // Switzerland: CHE + 9 digits
+	"BG": regexp.MustCompile(`^BG\d{9,10}$`),    // Bulgaria: BG + 9-10 digits
+	"CY": regexp.MustCompile(`^CY\d{8}[A-Z]$`),  // Cyprus: CY + 8 digits + letter
+	"CZ": regexp.MustCompile(`^CZ\d{8,10}$`),    // Czechia: CZ + 8-10 digits
+	"DK": regexp.MustCompile(`^DK\d{8}$`),       // Denmark: DK + 8 digits
+	"EE": regexp.MustCompile(`^EE\d{9}$`),       // Estonia: EE + 9 digits
+	"EL": regexp.MustCompile(`^EL\d{9}$`),       // Greece: EL + 9 digits
+	"FI": regexp.MustCompile(`^FI\d{8}$`),       // Finland: FI + 8 digits
+	"HR": regexp.MustCompile(`^HR\d{11}$`),      // Croatia: HR + 11 digits
+	"HU": regexp.MustCompile(`^HU\d{8}$`),       // Hungary: HU + 8 digits
+	"IE": regexp.MustCompile(`^IE(\d{7}[A-W][A-IW]?|\d[A-Z+*]\d{5}[A-W])$`), // Ireland: IE + 8-9 chars
+	"LT": regexp.MustCompile(`^LT(\d{9}|\d{12})$`), // Lithuania: LT + 9 or 12 digits
+	"LU": regexp.MustCompile(`^LU\d{8}$`),       // Luxembourg: LU + 8 digits
+	"LV": regexp.MustCompile(`^LV\d{11}$`),      // Latvia: LV + 11 digits
+	"MT": regexp.MustCompile(`^MT\d{8}$`),       // Malta: MT + 8 digits
+	"PT": regexp.MustCompile(`^PT\d{9}$`),       // Portugal: PT + 9 digits
+	"RO": regexp.MustCompile(`^RO\d{2,10}$`),    // Romania: RO + 2-10 digits
+	"SE": regexp.MustCompile(`^SE\d{10}01$`),    // Sweden: SE + 10 digits + 01
+	"SI": regexp.MustCompile(`^SI\d{8}$`),       // Slovenia: SI + 8 digits
+	"SK": regexp.MustCompile(`^SK\d{10}$`),      // Slovakia: SK + 10 digits
+	"XI": regexp.MustCompile(`^XI(\d{9}|\d{12}|GD\d{3}|HA\d{3})$`), // Northern Ireland: XI + 9 or 12 digits
 }
 
 // UIDAbfrageRequest represents a SOAP request for UID validation
@@ -54,6 +74,9 @@ type UIDAbfrageResponse struct {
 	AdrStrasse string `xml:"adr_strasse"`
 	AdrPLZ     string `xml:"adr_plz"`
 	AdrOrt     string `xml:"adr_ort"`
+	// AbfrageNr is the consultation number of a Stufe 2 query, for foreign
+	// UIDs the request identifier issued by VIES
+	AbfrageNr string `xml:"abfrage_nr"`
 }
 
 // UIDValidationResult contains the validation result
@@ -68,6 +91,8 @@ type UIDValidationResult struct {
 	CountryCode  string     `json:"country_code"`
 	ErrorCode    int        `json:"error_code,omitempty"`
 	ErrorMessage string     `json:"error_message,omitempty"`
+	// ConsultationNumber proves a Stufe 2 query to the tax office
+	ConsultationNumber string `json:"consultation_number,omitempty"`
 }
 
 // UIDAddress represents an address from UID validation
@@ -158,6 +183,7 @@ func ConvertUIDResponse(resp *UIDAbfrageResponse) *UIDValidationResult {
 			Country:  result.CountryCode,
 		}
 		result.ValidAt = time.Now()
+		result.ConsultationNumber = resp.AbfrageNr
 	} else {
 		result.Valid = false
 		result.ErrorCode = resp.RC
@@ -228,7 +254,7 @@ func WriteUIDResultsCSV(results []*UIDValidationResult) ([]byte, error) {
 	writer := csv.NewWriter(&buf)
 
 	// Write header
-	header := []string{"uid", "valid", "company_name", "street", "post_code", "city", "error"}
+	header := []string{"uid", "valid", "company_name", "street", "post_code", "city", "error", "consultation_number"}
 	if err := writer.Write(header); err != nil {
 		return nil, fmt.Errorf("failed to write CSV header: %w", err)
 	}
@@ -243,6 +269,7 @@ func WriteUIDResultsCSV(results []*UIDValidationResult) ([]byte, error) {
 			r.Address.PostCode,
 			r.Address.City,
 			r.ErrorMessage,
+			r.ConsultationNumber,
 		}
 		if err := writer.Write(row); err != nil {
 			return nil, fmt.Errorf("failed to write CSV row: %w", err)
@@ -276,11 +303,11 @@ const UIDNS = "http://finanzonline.bmf.gv.at/fonuid"
 
 // UIDService handles UID validation queries
 type UIDService struct {
-	client *Client
+	client Caller
 }
 
 // NewUIDService creates a new UID service
-func NewUIDService(client *Client) *UIDService {
+func NewUIDService(client Caller) *UIDService {
 	return &UIDService{client: client}
 }
 
@@ -53,6 +53,9 @@ type UVA struct {
 	KZ070 int64 // Sonstige Berichtigungen
 	KZ095 int64 // Zahllast/Gutschrift (calculated)
 
+	// Berichtigung is set when the UVA corrects an earlier filing of the period
+	Berichtigung *Berichtigung
+
 	// Metadata
 	CreatedAt   time.Time
 	SubmittedAt *time.Time
@@ -66,9 +69,16 @@ type UVADocument struct {
 	XMLNS        string        `xml:"xmlns,attr"`
 	Steuernummer string        `xml:"Steuernummer,omitempty"`
 	Zeitraum     UVAZeitraum   `xml:"Zeitraum"`
+	Berichtigung *Berichtigung `xml:"Berichtigung,omitempty"`
 	Kennzahlen   UVAKennzahlen `xml:"Kennzahlen"`
 }
 
+// Berichtigung marks a UVA or ZM upload as the correction of an earlier
+// filing of the same period
+type Berichtigung struct {
+	Bezugsnummer string `xml:"Bezugsnummer,omitempty"` // Belegnummer of the corrected filing
+}
+
 // UVAZeitraum represents the period in XML format
 type UVAZeitraum struct {
 	Jahr    int    `xml:"Jahr"`
@@ -171,6 +181,7 @@ func GenerateUVAXML(uva *UVA) ([]byte, error) {
 		Zeitraum: UVAZeitraum{
 			Jahr: uva.Year,
 		},
+		Berichtigung: uva.Berichtigung,
 		Kennzahlen: UVAKennzahlen{
 			KZ000: uva.KZ000,
 			KZ001: uva.KZ001,
@@ -231,11 +242,11 @@ const FileUploadNS = "https://finanzonline.bmf.gv.at/fonws/ws/fileUploadService"
 
 // FileUploadService handles UVA and ZM file uploads
 type FileUploadService struct {
-	client *Client
+	client Caller
 }
 
 // NewFileUploadService creates a new file upload service
-func NewFileUploadService(client *Client) *FileUploadService {
+func NewFileUploadService(client Caller) *FileUploadService {
 	return &FileUploadService{client: client}
 }
 
@@ -260,13 +271,14 @@ func (s *FileUploadService) Upload(sessionID, tid, benid, art string, data []byt
 	}
 
 	if resp.RC != 0 {
-		return &resp, fmt.Errorf("upload error (code %d): %s", resp.RC, resp.Msg)
+		return &resp, fmt.Errorf("upload error (code %d): %w", resp.RC, &FOError{Code: resp.RC, Message: resp.Msg})
 	}
 
 	return &resp, nil
 }
 
-// SubmitUVA submits a UVA to FinanzOnline
+// SubmitUVA submits a UVA to FinanzOnline. If FinanzOnline answers with an
+// error code the response is returned with the error, see IsRetryable.
 func (s *FileUploadService) SubmitUVA(sessionID, tid, benid string, uva *UVA) (*FileUploadResponse, error) {
 	xmlData, err := GenerateUVAXML(uva)
 	if err != nil {
@@ -275,7 +287,7 @@ func (s *FileUploadService) SubmitUVA(sessionID, tid, benid string, uva *UVA) (*
 
 	resp, err := s.Upload(sessionID, tid, benid, "U30", xmlData)
 	if err != nil {
-		return nil, err
+		return resp, err
 	}
 
 	// Update UVA status and reference
@@ -310,6 +322,7 @@ func ParseUVAFromXML(data []byte) (*UVA, error) {
 		KZ066: doc.Kennzahlen.KZ066,
 		KZ070: doc.Kennzahlen.KZ070,
 		KZ095: doc.Kennzahlen.KZ095,
+		Berichtigung: doc.Berichtigung,
 		Status: UVAStatusDraft,
 		CreatedAt: time.Now(),
 	}
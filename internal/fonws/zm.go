@@ -27,13 +27,21 @@ const (
 	ZMDeliveryTypeServices   ZMDeliveryType = "S" // Sonstige Leistungen (services)
 )
 
-// ZM represents a recapitulative statement (Zusammenfassende Meldung)
+// ZM represents a recapitulative statement (Zusammenfassende Meldung). It
+// covers a calendar quarter, or a month if Month is set; Quarter is then the
+// quarter of the month.
 type ZM struct {
 	Year    int
 	Quarter int // 1-4
+	Month   int // 1-12 for a monthly ZM, 0 for a quarterly one
 
 	Entries []ZMEntry
 
+	// Berichtigung is set when the ZM corrects an earlier filing of the
+	// quarter. A correction lists only the changed lines, with their
+	// corrected amounts; a withdrawn line is reported with an amount of 0.
+	Berichtigung *Berichtigung
+
 	// Metadata
 	CreatedAt   time.Time
 	SubmittedAt *time.Time
@@ -53,7 +61,9 @@ type ZMEntry struct {
 type zmXML struct {
 	XMLName    xml.Name       `xml:"ZM"`
 	Jahr       int            `xml:"Jahr"`
-	Quartal    int            `xml:"Quartal"`
+	Monat      string         `xml:"Monat,omitempty"`
+	Quartal    int            `xml:"Quartal,omitempty"`
+	Berichtigung *Berichtigung `xml:"Berichtigung,omitempty"`
 	Positionen []zmPositionXML `xml:"Position"`
 }
 
@@ -75,8 +85,19 @@ func NewZM(year, quarter int) *ZM {
 	}
 }
 
-// PeriodString returns the period in format "Q1/2025"
+// NewMonthlyZM creates a new ZM for a month
+func NewMonthlyZM(year, month int) *ZM {
+	zm := NewZM(year, (month-1)/3+1)
+	zm.Month = month
+	return zm
+}
+
+// PeriodString returns the period in format "Q1/2025", or "01/2025" for a
+// monthly ZM
 func (zm *ZM) PeriodString() string {
+	if zm.Month != 0 {
+		return fmt.Sprintf("%02d/%d", zm.Month, zm.Year)
+	}
 	return fmt.Sprintf("Q%d/%d", zm.Quarter, zm.Year)
 }
 
@@ -96,18 +117,15 @@ func (zm *ZM) TotalAmountEUR() float64 {
 
 // Validate validates the ZM
 func (zm *ZM) Validate() error {
-	if zm.Year < 2000 || zm.Year > 2100 {
-		return errors.New("year must be between 2000 and 2100")
-	}
-	if zm.Quarter < 1 || zm.Quarter > 4 {
-		return errors.New("quarter must be between 1 and 4")
+	if err := zm.ValidatePeriod(); err != nil {
+		return err
 	}
 	if len(zm.Entries) == 0 {
 		return errors.New("ZM must have at least one entry")
 	}
 
 	for i, entry := range zm.Entries {
-		if err := entry.Validate(); err != nil {
+		if err := entry.validate(zm.Berichtigung != nil); err != nil {
 			return fmt.Errorf("entry %d: %w", i+1, err)
 		}
 	}
@@ -115,6 +133,39 @@ func (zm *ZM) Validate() error {
 	return nil
 }
 
+// ValidatePeriod validates the reporting period of the ZM
+func (zm *ZM) ValidatePeriod() error {
+	if zm.Year < 2000 || zm.Year > 2100 {
+		return errors.New("year must be between 2000 and 2100")
+	}
+	if zm.Quarter < 1 || zm.Quarter > 4 {
+		return errors.New("quarter must be between 1 and 4")
+	}
+	if zm.Month != 0 && (zm.Month < 1 || zm.Month > 12 || (zm.Month-1)/3+1 != zm.Quarter) {
+		return errors.New("month must be between 1 and 12 and in the quarter")
+	}
+	return nil
+}
+
+// ZMLineError is the validation error of a line of a ZM
+type ZMLineError struct {
+	Line       int    `json:"line"` // 1-based
+	PartnerUID string `json:"partner_uid"`
+	Message    string `json:"message"`
+}
+
+// ValidateLines validates every line of the ZM and returns the errors of
+// all invalid lines, unlike Validate which stops at the first
+func (zm *ZM) ValidateLines() []ZMLineError {
+	var errs []ZMLineError
+	for i, entry := range zm.Entries {
+		if err := entry.validate(zm.Berichtigung != nil); err != nil {
+			errs = append(errs, ZMLineError{Line: i + 1, PartnerUID: entry.PartnerUID, Message: err.Error()})
+		}
+	}
+	return errs
+}
+
 // AddEntry adds an entry to the ZM
 func (zm *ZM) AddEntry(entry ZMEntry) error {
 	if err := entry.Validate(); err != nil {
@@ -126,6 +177,12 @@ func (zm *ZM) AddEntry(entry ZMEntry) error {
 
 // Validate validates a ZM entry
 func (e *ZMEntry) Validate() error {
+	return e.validate(false)
+}
+
+// validate validates a ZM entry, allowing the zero amount of a withdrawn
+// line in a Berichtigung
+func (e *ZMEntry) validate(withdrawable bool) error {
 	if e.PartnerUID == "" {
 		return errors.New("partner_uid is required")
 	}
@@ -139,7 +196,7 @@ func (e *ZMEntry) Validate() error {
 	if strings.ToUpper(e.CountryCode) == "AT" {
 		return errors.New("country_code: Austrian partners are not allowed in ZM (intra-community only)")
 	}
-	if e.Amount <= 0 {
+	if e.Amount < 0 || (e.Amount == 0 && !withdrawable) {
 		return errors.New("amount must be positive")
 	}
 	if e.DeliveryType != ZMDeliveryTypeGoods &&
@@ -148,9 +205,54 @@ func (e *ZMEntry) Validate() error {
 		return errors.New("invalid delivery type (must be L, D, or S)")
 	}
 
+	return e.validatePartner()
+}
+
+// zmPartnerCountries are the UID prefixes of the partners a ZM reports: the
+// other EU member states and, for goods only, Northern Ireland
+var zmPartnerCountries = map[string]bool{
+	"BE": true, "BG": true, "CY": true, "CZ": true, "DE": true, "DK": true,
+	"EE": true, "EL": true, "ES": true, "FI": true, "FR": true, "HR": true,
+	"HU": true, "IE": true, "IT": true, "LT": true, "LU": true, "LV": true,
+	"MT": true, "NL": true, "PL": true, "PT": true, "RO": true, "SE": true,
+	"SI": true, "SK": true, "XI": true,
+}
+
+// validatePartner checks that the partner UID is a valid UID of another EU
+// member state and matches the country code. Greece has the UID prefix EL
+// and may be given as GR.
+func (e *ZMEntry) validatePartner() error {
+	uid := normalizeZMUID(e.PartnerUID)
+	format := ValidateUIDFormat(uid)
+	if !format.Valid {
+		return fmt.Errorf("partner_uid: %s", format.Error)
+	}
+	if !zmPartnerCountries[format.CountryCode] {
+		return fmt.Errorf("partner_uid: %s is not a UID of another EU member state", uid)
+	}
+	if zmCountryCode(e.CountryCode) != format.CountryCode {
+		return fmt.Errorf("country_code: %s does not match partner UID %s", e.CountryCode, uid)
+	}
+	if format.CountryCode == "XI" && e.DeliveryType == ZMDeliveryTypeServices {
+		return errors.New("delivery_type: services to Northern Ireland are not reported in the ZM")
+	}
 	return nil
 }
 
+// normalizeZMUID removes blanks from a UID and upper-cases it
+func normalizeZMUID(uid string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(uid), ""))
+}
+
+// zmCountryCode returns the UID prefix of a country code
+func zmCountryCode(code string) string {
+	code = strings.ToUpper(code)
+	if code == "GR" {
+		return "EL"
+	}
+	return code
+}
+
 // AmountEUR returns the amount in EUR
 func (e *ZMEntry) AmountEUR() float64 {
 	return float64(e.Amount) / 100.0
@@ -165,12 +267,17 @@ func GenerateZMXML(zm *ZM) ([]byte, error) {
 	zmXMLData := zmXML{
 		Jahr:    zm.Year,
 		Quartal: zm.Quarter,
+		Berichtigung: zm.Berichtigung,
+	}
+	if zm.Month != 0 {
+		zmXMLData.Quartal = 0
+		zmXMLData.Monat = fmt.Sprintf("%02d", zm.Month)
 	}
 
 	for _, entry := range zm.Entries {
 		zmXMLData.Positionen = append(zmXMLData.Positionen, zmPositionXML{
-			PartnerUID:          entry.PartnerUID,
-			LandCode:            entry.CountryCode,
+			PartnerUID:          normalizeZMUID(entry.PartnerUID),
+			LandCode:            zmCountryCode(entry.CountryCode),
 			Lieferart:           string(entry.DeliveryType),
 			Bemessungsgrundlage: entry.Amount / 100, // Convert to EUR for XML
 		})
@@ -0,0 +1,223 @@
+package handover
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler handles tenant handover HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new handover handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers handover routes. Handovers move client data
+// between tenants, so every route is admin-only.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/handovers", requireAuth(requireAdmin(http.HandlerFunc(h.List))))
+	router.Handle("POST /api/v1/handovers", requireAuth(requireAdmin(http.HandlerFunc(h.Create))))
+	router.Handle("GET /api/v1/handovers/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Get))))
+	router.Handle("GET /api/v1/handovers/{id}/items", requireAuth(requireAdmin(http.HandlerFunc(h.Items))))
+	router.Handle("POST /api/v1/handovers/{id}/accept", requireAuth(requireAdmin(http.HandlerFunc(h.Accept))))
+	router.Handle("POST /api/v1/handovers/{id}/reject", requireAuth(requireAdmin(http.HandlerFunc(h.Reject))))
+	router.Handle("POST /api/v1/handovers/{id}/cancel", requireAuth(requireAdmin(http.HandlerFunc(h.Cancel))))
+}
+
+// RejectRequest is the optional body of a reject request
+type RejectRequest struct {
+	Note string `json:"note"`
+}
+
+// List handles GET /api/v1/handovers
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	direction := r.URL.Query().Get("direction")
+	if direction != "" && direction != "incoming" && direction != "outgoing" {
+		api.BadRequest(w, "direction must be incoming or outgoing")
+		return
+	}
+
+	handovers, err := h.service.List(r.Context(), tenantID, direction)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if handovers == nil {
+		handovers = []*Handover{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items": handovers,
+		"total": len(handovers),
+	})
+}
+
+// Create handles POST /api/v1/handovers
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var input CreateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	handover, err := h.service.Create(r.Context(), tenantID, api.OptionalUser(r), &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, handover)
+}
+
+// Get handles GET /api/v1/handovers/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	handover, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, handover)
+}
+
+// Items handles GET /api/v1/handovers/{id}/items
+func (h *Handler) Items(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	items, err := h.service.Items(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if items == nil {
+		items = []*Item{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items": items,
+		"total": len(items),
+	})
+}
+
+// Accept handles POST /api/v1/handovers/{id}/accept
+func (h *Handler) Accept(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var input AcceptInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	handover, err := h.service.Accept(r.Context(), tenantID, id, api.OptionalUser(r), &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, handover)
+}
+
+// Reject handles POST /api/v1/handovers/{id}/reject
+func (h *Handler) Reject(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req RejectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	handover, err := h.service.Reject(r.Context(), tenantID, id, api.OptionalUser(r), req.Note)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, handover)
+}
+
+// Cancel handles POST /api/v1/handovers/{id}/cancel
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	handover, err := h.service.Cancel(r.Context(), tenantID, id, api.OptionalUser(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, handover)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrHandoverNotFound):
+		api.NotFound(w, "handover not found")
+	case errors.Is(err, ErrTargetNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrNotPending), errors.Is(err, ErrExpired), errors.Is(err, ErrAccountPending):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, ErrOwnTenant), errors.Is(err, ErrNoAccounts), errors.Is(err, ErrAccountNotFound),
+		errors.Is(err, ErrConsentRequired), errors.Is(err, ErrInvalidConsentDate), errors.Is(err, ErrInvalidMode),
+		errors.Is(err, ErrNothingIncluded), errors.Is(err, ErrMappingIncomplete), errors.Is(err, ErrTargetAccount),
+		errors.Is(err, ErrTargetAccountTwice):
+		api.BadRequest(w, err.Error())
+	default:
+		api.InternalError(w)
+	}
+}
@@ -0,0 +1,524 @@
+package handover
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles handover database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new handover repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// FindTenant looks a tenant up by ID or slug
+func (r *Repository) FindTenant(ctx context.Context, ref string) (uuid.UUID, error) {
+	var id uuid.UUID
+	var err error
+	if parsed, perr := uuid.Parse(ref); perr == nil {
+		err = r.db.QueryRow(ctx, `SELECT id FROM tenants WHERE id = $1`, parsed).Scan(&id)
+	} else {
+		err = r.db.QueryRow(ctx, `SELECT id FROM tenants WHERE slug = $1`, ref).Scan(&id)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, ErrTargetNotFound
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to find tenant: %w", err)
+	}
+	return id, nil
+}
+
+// SourceAccounts returns the given active accounts of a tenant with their
+// document and filing counts
+func (r *Repository) SourceAccounts(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) ([]AccountSummary, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT a.id, a.name, a.type,
+			(SELECT COUNT(*) FROM documents d WHERE d.account_id = a.id AND d.tenant_id = a.tenant_id),
+			(SELECT COUNT(*) FROM uva_submissions u WHERE u.account_id = a.id AND u.tenant_id = a.tenant_id)
+				+ (SELECT COUNT(*) FROM zm_submissions z WHERE z.account_id = a.id AND z.tenant_id = a.tenant_id)
+		FROM accounts a
+		WHERE a.tenant_id = $1 AND a.id = ANY($2) AND a.deleted_at IS NULL
+		ORDER BY a.name`, tenantID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []AccountSummary
+	for rows.Next() {
+		var a AccountSummary
+		if err := rows.Scan(&a.ID, &a.Name, &a.Type, &a.Documents, &a.Filings); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+// HasPending reports whether any of the accounts is part of a pending
+// handover of the tenant
+func (r *Repository) HasPending(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM tenant_handovers h, jsonb_array_elements(h.accounts) a
+			WHERE h.source_tenant_id = $1 AND h.status = 'pending' AND h.expires_at > NOW()
+				AND (a->>'id')::uuid = ANY($2)
+		)`, tenantID, ids).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check pending handovers: %w", err)
+	}
+	return exists, nil
+}
+
+// TargetAccountTypes returns the types of the given active accounts of a tenant
+func (r *Repository) TargetAccountTypes(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, type FROM accounts
+		WHERE tenant_id = $1 AND id = ANY($2) AND deleted_at IS NULL`, tenantID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load target accounts: %w", err)
+	}
+	defer rows.Close()
+
+	types := make(map[uuid.UUID]string)
+	for rows.Next() {
+		var id uuid.UUID
+		var t string
+		if err := rows.Scan(&id, &t); err != nil {
+			return nil, err
+		}
+		types[id] = t
+	}
+	return types, rows.Err()
+}
+
+// Create stores a new handover
+func (r *Repository) Create(ctx context.Context, h *Handover) error {
+	accounts, err := json.Marshal(h.Accounts)
+	if err != nil {
+		return err
+	}
+	err = r.db.QueryRow(ctx, `
+		INSERT INTO tenant_handovers (
+			source_tenant_id, target_tenant_id, status, mode, include_documents, include_filings,
+			accounts, client_name, consent_reference, consent_given_at, message, initiated_by, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12, $13)
+		RETURNING id, created_at`,
+		h.SourceTenantID, h.TargetTenantID, h.Status, h.Mode, h.IncludeDocuments, h.IncludeFilings,
+		accounts, h.ClientName, h.ConsentReference, h.ConsentGivenAt, h.Message, h.InitiatedBy, h.ExpiresAt,
+	).Scan(&h.ID, &h.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create handover: %w", err)
+	}
+	return nil
+}
+
+const handoverColumns = `h.id, h.source_tenant_id, s.name, h.target_tenant_id, t.name, h.status, h.mode,
+	h.include_documents, h.include_filings, h.accounts, h.client_name, h.consent_reference, h.consent_given_at,
+	COALESCE(h.message, ''), h.initiated_by, h.account_mapping, h.responded_by, COALESCE(h.response_note, ''),
+	h.summary, COALESCE(h.error, ''), h.expires_at, h.created_at, h.responded_at, h.completed_at`
+
+const handoverJoins = `FROM tenant_handovers h
+	JOIN tenants s ON s.id = h.source_tenant_id
+	JOIN tenants t ON t.id = h.target_tenant_id`
+
+func scanHandover(row pgx.Row) (*Handover, error) {
+	var h Handover
+	var accounts, mapping, summary []byte
+	err := row.Scan(&h.ID, &h.SourceTenantID, &h.SourceTenantName, &h.TargetTenantID, &h.TargetTenantName,
+		&h.Status, &h.Mode, &h.IncludeDocuments, &h.IncludeFilings, &accounts, &h.ClientName,
+		&h.ConsentReference, &h.ConsentGivenAt, &h.Message, &h.InitiatedBy, &mapping, &h.RespondedBy,
+		&h.ResponseNote, &summary, &h.Error, &h.ExpiresAt, &h.CreatedAt, &h.RespondedAt, &h.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(accounts, &h.Accounts); err != nil {
+		return nil, fmt.Errorf("decode handover accounts: %w", err)
+	}
+	if err := json.Unmarshal(mapping, &h.AccountMapping); err != nil {
+		return nil, fmt.Errorf("decode account mapping: %w", err)
+	}
+	if err := json.Unmarshal(summary, &h.Summary); err != nil {
+		return nil, fmt.Errorf("decode handover summary: %w", err)
+	}
+	return &h, nil
+}
+
+// Get returns a handover the tenant is the source or the target of
+func (r *Repository) Get(ctx context.Context, tenantID, id uuid.UUID) (*Handover, error) {
+	h, err := scanHandover(r.db.QueryRow(ctx, `SELECT `+handoverColumns+` `+handoverJoins+`
+		WHERE h.id = $1 AND (h.source_tenant_id = $2 OR h.target_tenant_id = $2)`, id, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrHandoverNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get handover: %w", err)
+	}
+	return h, nil
+}
+
+// List returns the handovers of a tenant, newest first. direction is
+// "outgoing", "incoming" or empty for both.
+func (r *Repository) List(ctx context.Context, tenantID uuid.UUID, direction string) ([]*Handover, error) {
+	where := "h.source_tenant_id = $1 OR h.target_tenant_id = $1"
+	switch direction {
+	case "outgoing":
+		where = "h.source_tenant_id = $1"
+	case "incoming":
+		where = "h.target_tenant_id = $1"
+	}
+	rows, err := r.db.Query(ctx, `SELECT `+handoverColumns+` `+handoverJoins+`
+		WHERE `+where+` ORDER BY h.created_at DESC LIMIT 200`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list handovers: %w", err)
+	}
+	defer rows.Close()
+
+	var handovers []*Handover
+	for rows.Next() {
+		h, err := scanHandover(rows)
+		if err != nil {
+			return nil, err
+		}
+		handovers = append(handovers, h)
+	}
+	return handovers, rows.Err()
+}
+
+// Close moves a pending handover to a final state without executing it
+func (r *Repository) Close(ctx context.Context, id uuid.UUID, status string, by *uuid.UUID, note string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE tenant_handovers
+		SET status = $2, responded_by = $3, response_note = NULLIF($4, ''), responded_at = NOW()
+		WHERE id = $1 AND status = 'pending'`, id, status, by, note)
+	if err != nil {
+		return fmt.Errorf("failed to update handover: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotPending
+	}
+	return nil
+}
+
+// Fail records the error of a handover that could not be executed
+func (r *Repository) Fail(ctx context.Context, id uuid.UUID, by *uuid.UUID, cause error) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE tenant_handovers
+		SET status = 'failed', responded_by = $2, responded_at = NOW(), error = $3
+		WHERE id = $1 AND status = 'pending'`, id, by, cause.Error())
+	if err != nil {
+		return fmt.Errorf("failed to record handover failure: %w", err)
+	}
+	return nil
+}
+
+// Items returns the audit trail of a handover
+func (r *Repository) Items(ctx context.Context, handoverID uuid.UUID) ([]*Item, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, item_type, source_account_id, source_id, target_id, action, created_at
+		FROM tenant_handover_items
+		WHERE handover_id = $1
+		ORDER BY created_at, item_type, source_id`, handoverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list handover items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*Item
+	for rows.Next() {
+		var it Item
+		if err := rows.Scan(&it.ID, &it.ItemType, &it.SourceAccountID, &it.SourceID, &it.TargetID, &it.Action, &it.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, &it)
+	}
+	return items, rows.Err()
+}
+
+// sourceDocument is a document of a source account to be handed over
+type sourceDocument struct {
+	ID          uuid.UUID
+	StoragePath string
+	MimeType    string
+}
+
+// execution holds the open transaction of a running handover
+type execution struct {
+	tx pgx.Tx
+	h  *Handover
+}
+
+// begin locks a pending handover for execution
+func (r *Repository) begin(ctx context.Context, h *Handover) (*execution, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	var status string
+	if err := tx.QueryRow(ctx, `SELECT status FROM tenant_handovers WHERE id = $1 FOR UPDATE`, h.ID).Scan(&status); err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to lock handover: %w", err)
+	}
+	if status != StatusPending {
+		tx.Rollback(ctx)
+		return nil, ErrNotPending
+	}
+	return &execution{tx: tx, h: h}, nil
+}
+
+func (e *execution) documents(ctx context.Context, sourceAccount uuid.UUID) ([]sourceDocument, error) {
+	rows, err := e.tx.Query(ctx, `
+		SELECT id, COALESCE(storage_path, ''), COALESCE(mime_type, 'application/octet-stream')
+		FROM documents
+		WHERE tenant_id = $1 AND account_id = $2
+		ORDER BY received_at, id`, e.h.SourceTenantID, sourceAccount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load documents: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []sourceDocument
+	for rows.Next() {
+		var d sourceDocument
+		if err := rows.Scan(&d.ID, &d.StoragePath, &d.MimeType); err != nil {
+			return nil, err
+		}
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
+
+// copyDocument copies a document row to the target account, pointing at the
+// copied file. It returns uuid.Nil if the target already has the document.
+func (e *execution) copyDocument(ctx context.Context, doc sourceDocument, targetAccount uuid.UUID, storagePath string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := e.tx.QueryRow(ctx, `
+		INSERT INTO documents (
+			tenant_id, account_id, external_id, type, title, sender, received_at, content_hash,
+			storage_path, file_size, mime_type, status, archived_at, retention_until, deadline,
+			folder, labels, metadata
+		)
+		SELECT $2, $3, external_id, type, title, sender, received_at, content_hash,
+			$4, file_size, mime_type, status, archived_at, retention_until, deadline,
+			folder, labels,
+			COALESCE(metadata, '{}'::jsonb) || jsonb_build_object('handover_id', $5::text, 'handover_source_id', id::text)
+		FROM documents WHERE id = $1
+		ON CONFLICT DO NOTHING
+		RETURNING id`,
+		doc.ID, e.h.TargetTenantID, targetAccount, storagePath, e.h.ID.String()).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, nil
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to copy document %s: %w", doc.ID, err)
+	}
+	return id, nil
+}
+
+// copiedFiling is a filing copied to the target; TargetID is nil if the
+// target already had it
+type copiedFiling struct {
+	ItemType string
+	SourceID uuid.UUID
+	TargetID *uuid.UUID
+}
+
+// copyFilings copies the UVA and ZM submissions of a source account. Who
+// submitted them is not kept, since those users stay with the source.
+func (e *execution) copyFilings(ctx context.Context, sourceAccount, targetAccount uuid.UUID) ([]copiedFiling, error) {
+	statements := map[string]string{
+		ItemUVASubmission: `
+			INSERT INTO uva_submissions (
+				tenant_id, account_id, period_year, period_month, period_quarter, period_type,
+				data, validation_status, status, fo_reference, submitted_at, created_at, updated_at
+			)
+			SELECT $2, $3, period_year, period_month, period_quarter, period_type,
+				data, validation_status, status, fo_reference, submitted_at, created_at, NOW()
+			FROM uva_submissions WHERE id = $1
+			ON CONFLICT DO NOTHING
+			RETURNING id`,
+		ItemZMSubmission: `
+			INSERT INTO zm_submissions (
+				tenant_id, account_id, period_year, period_quarter, entries, entry_count, total_amount,
+				validation_status, status, fo_reference, submitted_at, created_at, updated_at
+			)
+			SELECT $2, $3, period_year, period_quarter, entries, entry_count, total_amount,
+				validation_status, status, fo_reference, submitted_at, created_at, NOW()
+			FROM zm_submissions WHERE id = $1
+			ON CONFLICT DO NOTHING
+			RETURNING id`,
+	}
+	sources := map[string]string{
+		ItemUVASubmission: `SELECT id FROM uva_submissions WHERE tenant_id = $1 AND account_id = $2 ORDER BY created_at, id`,
+		ItemZMSubmission:  `SELECT id FROM zm_submissions WHERE tenant_id = $1 AND account_id = $2 ORDER BY created_at, id`,
+	}
+
+	var copied []copiedFiling
+	for _, itemType := range []string{ItemUVASubmission, ItemZMSubmission} {
+		ids, err := e.ids(ctx, sources[itemType], e.h.SourceTenantID, sourceAccount)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			var target uuid.UUID
+			err := e.tx.QueryRow(ctx, statements[itemType], id, e.h.TargetTenantID, targetAccount).Scan(&target)
+			switch {
+			case errors.Is(err, pgx.ErrNoRows):
+				copied = append(copied, copiedFiling{ItemType: itemType, SourceID: id})
+			case err != nil:
+				return nil, fmt.Errorf("failed to copy %s %s: %w", itemType, id, err)
+			default:
+				copied = append(copied, copiedFiling{ItemType: itemType, SourceID: id, TargetID: &target})
+			}
+		}
+	}
+	if err := e.linkCorrections(ctx, copied); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// linkCorrections keeps the correction chains of copied filings: a copied
+// Berichtigung corrects the copy of the filing it corrected in the source
+func (e *execution) linkCorrections(ctx context.Context, copied []copiedFiling) error {
+	tables := map[string]string{
+		ItemUVASubmission: "uva_submissions",
+		ItemZMSubmission:  "zm_submissions",
+	}
+	targets := make(map[uuid.UUID]uuid.UUID, len(copied))
+	for _, f := range copied {
+		if f.TargetID != nil {
+			targets[f.SourceID] = *f.TargetID
+		}
+	}
+
+	for _, f := range copied {
+		if f.TargetID == nil {
+			continue
+		}
+		var corrects uuid.NullUUID
+		if err := e.tx.QueryRow(ctx, `SELECT corrects_id FROM `+tables[f.ItemType]+` WHERE id = $1`, f.SourceID).Scan(&corrects); err != nil {
+			return fmt.Errorf("failed to load correction of %s %s: %w", f.ItemType, f.SourceID, err)
+		}
+		target, ok := targets[corrects.UUID]
+		if !corrects.Valid || !ok {
+			continue
+		}
+		if _, err := e.tx.Exec(ctx, `UPDATE `+tables[f.ItemType]+` SET corrects_id = $1 WHERE id = $2`, target, *f.TargetID); err != nil {
+			return fmt.Errorf("failed to link correction %s: %w", *f.TargetID, err)
+		}
+	}
+	return nil
+}
+
+func (e *execution) ids(ctx context.Context, query string, args ...interface{}) ([]uuid.UUID, error) {
+	rows, err := e.tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load filings: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (e *execution) item(ctx context.Context, itemType string, sourceAccount, sourceID uuid.UUID, targetID *uuid.UUID, action string) error {
+	_, err := e.tx.Exec(ctx, `
+		INSERT INTO tenant_handover_items (handover_id, item_type, source_account_id, source_id, target_id, action)
+		VALUES ($1, $2, $3, $4, $5, $6)`, e.h.ID, itemType, sourceAccount, sourceID, targetID, action)
+	if err != nil {
+		return fmt.Errorf("failed to record handover item: %w", err)
+	}
+	return nil
+}
+
+// removeSource deletes the handed-over documents and filings of a source
+// account, for transfers
+func (e *execution) removeSource(ctx context.Context, sourceAccount uuid.UUID, documents, filings bool) error {
+	if documents {
+		if _, err := e.tx.Exec(ctx, `DELETE FROM documents WHERE tenant_id = $1 AND account_id = $2`,
+			e.h.SourceTenantID, sourceAccount); err != nil {
+			return fmt.Errorf("failed to remove source documents: %w", err)
+		}
+	}
+	if filings {
+		for _, table := range []string{"uva_submissions", "zm_submissions"} {
+			if _, err := e.tx.Exec(ctx, `DELETE FROM `+table+` WHERE tenant_id = $1 AND account_id = $2`,
+				e.h.SourceTenantID, sourceAccount); err != nil {
+				return fmt.Errorf("failed to remove source filings: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// revokeSource ends the source tenant's access to the handed-over accounts:
+// the accounts are suspended and deleted, and the source's client portal
+// users lose access to them
+func (e *execution) revokeSource(ctx context.Context, accounts []uuid.UUID) error {
+	if _, err := e.tx.Exec(ctx, `
+		UPDATE accounts
+		SET status = 'suspended', auto_sync_enabled = FALSE, deleted_at = NOW(), updated_at = NOW()
+		WHERE tenant_id = $1 AND id = ANY($2)`, e.h.SourceTenantID, accounts); err != nil {
+		return fmt.Errorf("failed to revoke source accounts: %w", err)
+	}
+	if _, err := e.tx.Exec(ctx, `DELETE FROM client_account_access WHERE account_id = ANY($1)`, accounts); err != nil {
+		return fmt.Errorf("failed to revoke client access: %w", err)
+	}
+	return nil
+}
+
+// complete marks the handover completed and commits
+func (e *execution) complete(ctx context.Context, by *uuid.UUID, note string, mapping map[uuid.UUID]uuid.UUID, summary map[string]int) error {
+	mappingJSON, err := json.Marshal(mapping)
+	if err != nil {
+		return err
+	}
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if _, err := e.tx.Exec(ctx, `
+		UPDATE tenant_handovers
+		SET status = 'completed', responded_by = $2, response_note = NULLIF($3, ''), account_mapping = $4,
+			summary = $5, responded_at = $6, completed_at = $6
+		WHERE id = $1`, e.h.ID, by, note, mappingJSON, summaryJSON, now); err != nil {
+		return fmt.Errorf("failed to complete handover: %w", err)
+	}
+	if err := e.tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit handover: %w", err)
+	}
+	e.h.Status = StatusCompleted
+	e.h.RespondedBy = by
+	e.h.ResponseNote = note
+	e.h.AccountMapping = mapping
+	e.h.Summary = summary
+	e.h.RespondedAt = &now
+	e.h.CompletedAt = &now
+	return nil
+}
+
+func (e *execution) rollback(ctx context.Context) {
+	e.tx.Rollback(ctx)
+}
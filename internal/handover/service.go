@@ -0,0 +1,463 @@
+package handover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/audit"
+	"austrian-business-infrastructure/internal/document"
+)
+
+// Service manages handovers between tenants
+type Service struct {
+	repo     *Repository
+	storage  document.Storage
+	auditLog *audit.Logger
+	logger   *slog.Logger
+	expiry   time.Duration
+	now      func() time.Time
+}
+
+// NewService creates a new handover service. Document files are copied
+// through storage; every step is written to the audit logs of both tenants.
+func NewService(repo *Repository, storage document.Storage, auditLog *audit.Logger, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{
+		repo:     repo,
+		storage:  storage,
+		auditLog: auditLog,
+		logger:   logger,
+		expiry:   DefaultExpiry,
+		now:      time.Now,
+	}
+}
+
+// SetExpiry changes how long handovers wait for the target
+func (s *Service) SetExpiry(d time.Duration) {
+	if d > 0 {
+		s.expiry = d
+	}
+}
+
+// Validate checks a handover request and fills in the defaults: copy mode
+// and both documents and filings
+func (in *CreateInput) Validate() (consentGivenAt time.Time, err error) {
+	in.TargetTenant = strings.TrimSpace(in.TargetTenant)
+	in.ClientName = strings.TrimSpace(in.ClientName)
+	in.ConsentReference = strings.TrimSpace(in.ConsentReference)
+	if in.TargetTenant == "" {
+		return time.Time{}, ErrTargetNotFound
+	}
+	if len(in.AccountIDs) == 0 {
+		return time.Time{}, ErrNoAccounts
+	}
+	if in.ClientName == "" || in.ConsentReference == "" || in.ConsentGivenAt == "" {
+		return time.Time{}, ErrConsentRequired
+	}
+	consentGivenAt, err = time.Parse("2006-01-02", in.ConsentGivenAt)
+	if err != nil {
+		return time.Time{}, ErrInvalidConsentDate
+	}
+
+	if in.Mode == "" {
+		in.Mode = ModeCopy
+	}
+	if in.Mode != ModeCopy && in.Mode != ModeTransfer {
+		return time.Time{}, ErrInvalidMode
+	}
+	yes := true
+	if in.IncludeDocuments == nil {
+		in.IncludeDocuments = &yes
+	}
+	if in.IncludeFilings == nil {
+		in.IncludeFilings = &yes
+	}
+	if !*in.IncludeDocuments && !*in.IncludeFilings {
+		return time.Time{}, ErrNothingIncluded
+	}
+	return consentGivenAt, nil
+}
+
+// CheckMapping verifies that every source account maps to a distinct target
+// account of the same type. targetTypes holds the types of the target
+// tenant's accounts named in the mapping.
+func CheckMapping(accounts []AccountSummary, mapping map[uuid.UUID]uuid.UUID, targetTypes map[uuid.UUID]string) error {
+	used := make(map[uuid.UUID]bool, len(mapping))
+	for _, a := range accounts {
+		target, ok := mapping[a.ID]
+		if !ok || target == uuid.Nil {
+			return fmt.Errorf("%w: %s", ErrMappingIncomplete, a.Name)
+		}
+		if targetTypes[target] != a.Type {
+			return fmt.Errorf("%w: %s", ErrTargetAccount, target)
+		}
+		if used[target] {
+			return fmt.Errorf("%w: %s", ErrTargetAccountTwice, target)
+		}
+		used[target] = true
+	}
+	for source := range mapping {
+		if !containsAccount(accounts, source) {
+			return fmt.Errorf("%w: %s", ErrAccountNotFound, source)
+		}
+	}
+	return nil
+}
+
+func containsAccount(accounts []AccountSummary, id uuid.UUID) bool {
+	for _, a := range accounts {
+		if a.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Create starts a handover from the source tenant
+func (s *Service) Create(ctx context.Context, sourceTenantID uuid.UUID, userID *uuid.UUID, input *CreateInput) (*Handover, error) {
+	consentGivenAt, err := input.Validate()
+	if err != nil {
+		return nil, err
+	}
+	targetTenantID, err := s.repo.FindTenant(ctx, input.TargetTenant)
+	if err != nil {
+		return nil, err
+	}
+	if targetTenantID == sourceTenantID {
+		return nil, ErrOwnTenant
+	}
+
+	ids := uniqueIDs(input.AccountIDs)
+	accounts, err := s.repo.SourceAccounts(ctx, sourceTenantID, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) != len(ids) {
+		return nil, ErrAccountNotFound
+	}
+	pending, err := s.repo.HasPending(ctx, sourceTenantID, ids)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrAccountPending
+	}
+
+	h := &Handover{
+		SourceTenantID:   sourceTenantID,
+		TargetTenantID:   targetTenantID,
+		Status:           StatusPending,
+		Mode:             input.Mode,
+		IncludeDocuments: *input.IncludeDocuments,
+		IncludeFilings:   *input.IncludeFilings,
+		Accounts:         accounts,
+		ClientName:       input.ClientName,
+		ConsentReference: input.ConsentReference,
+		ConsentGivenAt:   consentGivenAt,
+		Message:          strings.TrimSpace(input.Message),
+		InitiatedBy:      userID,
+		ExpiresAt:        s.now().Add(s.expiry),
+	}
+	if err := s.repo.Create(ctx, h); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, h, userID, AuditInitiated, map[string]interface{}{
+		"mode":              h.Mode,
+		"include_documents": h.IncludeDocuments,
+		"include_filings":   h.IncludeFilings,
+		"accounts":          len(h.Accounts),
+		"client_name":       h.ClientName,
+		"consent_reference": h.ConsentReference,
+	})
+	return s.repo.Get(ctx, sourceTenantID, h.ID)
+}
+
+// Get returns a handover of the tenant, incoming or outgoing
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Handover, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// List returns the handovers of a tenant
+func (s *Service) List(ctx context.Context, tenantID uuid.UUID, direction string) ([]*Handover, error) {
+	return s.repo.List(ctx, tenantID, direction)
+}
+
+// Items returns what a handover moved
+func (s *Service) Items(ctx context.Context, tenantID, id uuid.UUID) ([]*Item, error) {
+	if _, err := s.repo.Get(ctx, tenantID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.Items(ctx, id)
+}
+
+// Cancel withdraws a pending handover; only the source can cancel
+func (s *Service) Cancel(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) (*Handover, error) {
+	return s.close(ctx, tenantID, id, userID, "", StatusCancelled, AuditCancelled, true)
+}
+
+// Reject declines a pending handover; only the target can reject
+func (s *Service) Reject(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID, note string) (*Handover, error) {
+	return s.close(ctx, tenantID, id, userID, strings.TrimSpace(note), StatusRejected, AuditRejected, false)
+}
+
+func (s *Service) close(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID, note, status, action string, source bool) (*Handover, error) {
+	h, err := s.pending(ctx, tenantID, id, source)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Close(ctx, h.ID, status, userID, note); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, h, userID, action, map[string]interface{}{"note": note})
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// pending loads a pending handover on the given side of the tenant. A
+// handover past its expiry is marked expired.
+func (s *Service) pending(ctx context.Context, tenantID, id uuid.UUID, source bool) (*Handover, error) {
+	h, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if (source && h.SourceTenantID != tenantID) || (!source && h.TargetTenantID != tenantID) {
+		return nil, ErrHandoverNotFound
+	}
+	if h.Status != StatusPending {
+		return nil, ErrNotPending
+	}
+	if !s.now().Before(h.ExpiresAt) {
+		if err := s.repo.Close(ctx, h.ID, StatusExpired, nil, ""); err != nil && !errors.Is(err, ErrNotPending) {
+			return nil, err
+		}
+		return nil, ErrExpired
+	}
+	return h, nil
+}
+
+// Accept executes a pending handover into the given target accounts. The
+// documents and filings are copied inside one transaction; in transfer mode
+// they are removed from the source. Either way the source loses access to
+// the handed-over accounts.
+func (s *Service) Accept(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID, input *AcceptInput) (*Handover, error) {
+	h, err := s.pending(ctx, tenantID, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]uuid.UUID, 0, len(input.AccountMapping))
+	for _, target := range input.AccountMapping {
+		targets = append(targets, target)
+	}
+	targetTypes, err := s.repo.TargetAccountTypes(ctx, tenantID, targets)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckMapping(h.Accounts, input.AccountMapping, targetTypes); err != nil {
+		return nil, err
+	}
+
+	note := strings.TrimSpace(input.Note)
+	if err := s.execute(ctx, h, userID, note, input.AccountMapping); err != nil {
+		if errors.Is(err, ErrNotPending) {
+			return nil, err
+		}
+		if ferr := s.repo.Fail(ctx, h.ID, userID, err); ferr != nil {
+			s.logger.Error("failed to record handover failure", "handover_id", h.ID, "error", ferr)
+		}
+		s.audit(ctx, h, userID, AuditFailed, map[string]interface{}{"error": err.Error()})
+		return nil, fmt.Errorf("handover failed: %w", err)
+	}
+
+	s.audit(ctx, h, userID, AuditCompleted, map[string]interface{}{
+		"mode":     h.Mode,
+		"accounts": len(h.Accounts),
+		"summary":  h.Summary,
+		"note":     note,
+	})
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// execute copies the handover's items. Files are copied before their rows;
+// if anything fails the transaction is rolled back and the copied files
+// removed again. Source files of a transfer are removed only after commit.
+func (s *Service) execute(ctx context.Context, h *Handover, userID *uuid.UUID, note string, mapping map[uuid.UUID]uuid.UUID) (err error) {
+	exec, err := s.repo.begin(ctx, h)
+	if err != nil {
+		return err
+	}
+
+	var copiedFiles, sourceFiles []string
+	defer func() {
+		if err == nil {
+			return
+		}
+		exec.rollback(ctx)
+		for _, p := range copiedFiles {
+			if derr := s.storage.Delete(ctx, p); derr != nil {
+				s.logger.Warn("failed to remove copied handover file", "handover_id", h.ID, "path", p, "error", derr)
+			}
+		}
+	}()
+
+	action := ActionCopied
+	if h.Mode == ModeTransfer {
+		action = ActionTransferred
+	}
+	summary := map[string]int{}
+	accountIDs := make([]uuid.UUID, 0, len(h.Accounts))
+
+	for _, account := range h.Accounts {
+		target := mapping[account.ID]
+		accountIDs = append(accountIDs, account.ID)
+
+		if h.IncludeDocuments {
+			docs, err := exec.documents(ctx, account.ID)
+			if err != nil {
+				return err
+			}
+			for _, doc := range docs {
+				storagePath := ""
+				if doc.StoragePath != "" {
+					storagePath, err = s.copyFile(ctx, h.TargetTenantID, target, doc)
+					if err != nil {
+						return err
+					}
+					copiedFiles = append(copiedFiles, storagePath)
+				}
+				newID, err := exec.copyDocument(ctx, doc, target, storagePath)
+				if err != nil {
+					return err
+				}
+				itemAction, targetID := action, &newID
+				if newID == uuid.Nil {
+					itemAction, targetID = ActionSkipped, nil
+					if storagePath != "" {
+						copiedFiles = copiedFiles[:len(copiedFiles)-1]
+						if derr := s.storage.Delete(ctx, storagePath); derr != nil {
+							s.logger.Warn("failed to remove duplicate handover file", "handover_id", h.ID, "path", storagePath, "error", derr)
+						}
+					}
+				}
+				if err := exec.item(ctx, ItemDocument, account.ID, doc.ID, targetID, itemAction); err != nil {
+					return err
+				}
+				summary[ItemDocument+"s_"+itemAction]++
+				if h.Mode == ModeTransfer && doc.StoragePath != "" {
+					sourceFiles = append(sourceFiles, doc.StoragePath)
+				}
+			}
+		}
+
+		if h.IncludeFilings {
+			filings, err := exec.copyFilings(ctx, account.ID, target)
+			if err != nil {
+				return err
+			}
+			for _, f := range filings {
+				itemAction := action
+				if f.TargetID == nil {
+					itemAction = ActionSkipped
+				}
+				if err := exec.item(ctx, f.ItemType, account.ID, f.SourceID, f.TargetID, itemAction); err != nil {
+					return err
+				}
+				summary[f.ItemType+"s_"+itemAction]++
+			}
+		}
+
+		if h.Mode == ModeTransfer {
+			if err := exec.removeSource(ctx, account.ID, h.IncludeDocuments, h.IncludeFilings); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := exec.revokeSource(ctx, accountIDs); err != nil {
+		return err
+	}
+	if err := exec.complete(ctx, userID, note, mapping, summary); err != nil {
+		return err
+	}
+
+	for _, p := range sourceFiles {
+		if derr := s.storage.Delete(ctx, p); derr != nil {
+			s.logger.Warn("failed to remove transferred source file", "handover_id", h.ID, "path", p, "error", derr)
+		}
+	}
+	return nil
+}
+
+// copyFile copies a document's file into the target account's storage
+func (s *Service) copyFile(ctx context.Context, targetTenantID, targetAccountID uuid.UUID, doc sourceDocument) (string, error) {
+	content, _, err := s.storage.Get(ctx, doc.StoragePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read document %s: %w", doc.ID, err)
+	}
+	defer content.Close()
+
+	info, err := s.storage.Store(ctx, targetTenantID.String(), targetAccountID.String(), path.Base(doc.StoragePath), content, doc.MimeType)
+	if err != nil {
+		return "", fmt.Errorf("failed to store document %s: %w", doc.ID, err)
+	}
+	return info.Path, nil
+}
+
+// audit writes a handover entry to the audit logs of both tenants. Audit
+// failures are logged but do not undo the handover.
+func (s *Service) audit(ctx context.Context, h *Handover, userID *uuid.UUID, action string, details map[string]interface{}) {
+	if s.auditLog == nil {
+		return
+	}
+	resourceType := ResourceHandover
+	for _, tenantID := range []uuid.UUID{h.SourceTenantID, h.TargetTenantID} {
+		tenant := tenantID
+		entry := make(map[string]interface{}, len(details)+3)
+		for k, v := range details {
+			entry[k] = v
+		}
+		entry["handover_id"] = h.ID.String()
+		entry["source_tenant_id"] = h.SourceTenantID.String()
+		entry["target_tenant_id"] = h.TargetTenantID.String()
+
+		logCtx := &audit.LogContext{TenantID: &tenant, ResourceType: &resourceType, ResourceID: &h.ID}
+		// The acting user belongs to one tenant only
+		if userID != nil && tenant == s.actingTenant(h, action) {
+			logCtx.UserID = userID
+		}
+		if err := s.auditLog.Log(ctx, logCtx, action, entry); err != nil {
+			s.logger.Error("failed to audit handover", "handover_id", h.ID, "tenant_id", tenant, "action", action, "error", err)
+		}
+	}
+}
+
+// actingTenant returns the side whose user performed the action
+func (s *Service) actingTenant(h *Handover, action string) uuid.UUID {
+	switch action {
+	case AuditInitiated, AuditCancelled:
+		return h.SourceTenantID
+	default:
+		return h.TargetTenantID
+	}
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	var out []uuid.UUID
+	for _, id := range ids {
+		if id != uuid.Nil && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
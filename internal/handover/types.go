@@ -0,0 +1,143 @@
+// Package handover moves a client's accounts, documents and filings from
+// one tenant to another when the client changes advisors
+// (Steuerberaterwechsel). The source tenant initiates with the client's
+// consent, the target tenant accepts, and the source loses access to the
+// handed-over accounts once the handover completes.
+package handover
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrHandoverNotFound   = errors.New("handover not found")
+	ErrNotPending         = errors.New("handover is no longer pending")
+	ErrExpired            = errors.New("handover has expired")
+	ErrTargetNotFound     = errors.New("target tenant not found")
+	ErrOwnTenant          = errors.New("a handover to the own tenant is not possible")
+	ErrNoAccounts         = errors.New("at least one account is required")
+	ErrAccountNotFound    = errors.New("account not found")
+	ErrAccountPending     = errors.New("account is already part of a pending handover")
+	ErrConsentRequired    = errors.New("client_name, consent_reference and consent_given_at are required")
+	ErrInvalidConsentDate = errors.New("consent_given_at must be a date (YYYY-MM-DD)")
+	ErrInvalidMode        = errors.New("mode must be copy or transfer")
+	ErrNothingIncluded    = errors.New("include documents, filings or both")
+	ErrMappingIncomplete  = errors.New("every account needs a target account")
+	ErrTargetAccount      = errors.New("target account not found or of a different type")
+	ErrTargetAccountTwice = errors.New("a target account can take only one source account")
+)
+
+// Handover states
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusRejected  = "rejected"
+	StatusCancelled = "cancelled"
+	StatusExpired   = "expired"
+	StatusFailed    = "failed"
+)
+
+// Handover modes
+const (
+	ModeCopy     = "copy"
+	ModeTransfer = "transfer"
+)
+
+// Item types
+const (
+	ItemDocument      = "document"
+	ItemUVASubmission = "uva_submission"
+	ItemZMSubmission  = "zm_submission"
+)
+
+// Item actions
+const (
+	ActionCopied      = "copied"
+	ActionTransferred = "transferred"
+	ActionSkipped     = "skipped"
+)
+
+// Audit log actions, written to the logs of both tenants
+const (
+	AuditInitiated = "handover_initiated"
+	AuditCancelled = "handover_cancelled"
+	AuditRejected  = "handover_rejected"
+	AuditCompleted = "handover_completed"
+	AuditFailed    = "handover_failed"
+)
+
+// ResourceHandover is the audit resource type of handovers
+const ResourceHandover = "tenant_handover"
+
+// DefaultExpiry is how long a handover waits for the target
+const DefaultExpiry = 30 * 24 * time.Hour
+
+// Handover is a request to move accounts from a source to a target tenant
+type Handover struct {
+	ID               uuid.UUID               `json:"id"`
+	SourceTenantID   uuid.UUID               `json:"source_tenant_id"`
+	SourceTenantName string                  `json:"source_tenant_name"`
+	TargetTenantID   uuid.UUID               `json:"target_tenant_id"`
+	TargetTenantName string                  `json:"target_tenant_name"`
+	Status           string                  `json:"status"`
+	Mode             string                  `json:"mode"`
+	IncludeDocuments bool                    `json:"include_documents"`
+	IncludeFilings   bool                    `json:"include_filings"`
+	Accounts         []AccountSummary        `json:"accounts"`
+	ClientName       string                  `json:"client_name"`
+	ConsentReference string                  `json:"consent_reference"`
+	ConsentGivenAt   time.Time               `json:"consent_given_at"`
+	Message          string                  `json:"message,omitempty"`
+	InitiatedBy      *uuid.UUID              `json:"initiated_by,omitempty"`
+	AccountMapping   map[uuid.UUID]uuid.UUID `json:"account_mapping,omitempty"`
+	RespondedBy      *uuid.UUID              `json:"responded_by,omitempty"`
+	ResponseNote     string                  `json:"response_note,omitempty"`
+	Summary          map[string]int          `json:"summary,omitempty"`
+	Error            string                  `json:"error,omitempty"`
+	ExpiresAt        time.Time               `json:"expires_at"`
+	CreatedAt        time.Time               `json:"created_at"`
+	RespondedAt      *time.Time              `json:"responded_at,omitempty"`
+	CompletedAt      *time.Time              `json:"completed_at,omitempty"`
+}
+
+// AccountSummary describes a source account at the time of the request
+type AccountSummary struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Documents int       `json:"documents"`
+	Filings   int       `json:"filings"`
+}
+
+// Item records one document or filing moved by a handover
+type Item struct {
+	ID              uuid.UUID  `json:"id"`
+	ItemType        string     `json:"item_type"`
+	SourceAccountID uuid.UUID  `json:"source_account_id"`
+	SourceID        uuid.UUID  `json:"source_id"`
+	TargetID        *uuid.UUID `json:"target_id,omitempty"`
+	Action          string     `json:"action"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// CreateInput is the request of a source tenant
+type CreateInput struct {
+	TargetTenant     string      `json:"target_tenant"` // slug or ID
+	AccountIDs       []uuid.UUID `json:"account_ids"`
+	Mode             string      `json:"mode"`
+	IncludeDocuments *bool       `json:"include_documents"`
+	IncludeFilings   *bool       `json:"include_filings"`
+	ClientName       string      `json:"client_name"`
+	ConsentReference string      `json:"consent_reference"`
+	ConsentGivenAt   string      `json:"consent_given_at"` // YYYY-MM-DD
+	Message          string      `json:"message"`
+}
+
+// AcceptInput maps each source account to an account of the target tenant
+type AcceptInput struct {
+	AccountMapping map[uuid.UUID]uuid.UUID `json:"account_mapping"`
+	Note           string                  `json:"note"`
+}
@@ -0,0 +1,503 @@
+package idaustria
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The mock identity provider stands in for ID Austria during local
+// development, where no registered client is available. It speaks the same
+// OIDC authorization code flow with PKCE as ID Austria, so Client works
+// against it unchanged. It must never be mounted outside APP_ENV=dev.
+
+// Mock provider lifetimes
+const (
+	mockCodeTTL  = 5 * time.Minute
+	mockTokenTTL = 10 * time.Minute
+)
+
+// MockBPKType is the bPK sector of the mock identities
+const MockBPKType = "urn:publicid:gv.at:cdid+ZP"
+
+// MockACR is the level of assurance the mock provider reports
+const MockACR = "http://eidas.europa.eu/LoA/high"
+
+// MockPersona is a test identity of the mock identity provider
+type MockPersona struct {
+	ID          string // Short key used on the login page
+	GivenName   string
+	FamilyName  string
+	DateOfBirth string
+	Email       string
+}
+
+// Name returns the full name
+func (p *MockPersona) Name() string {
+	return p.GivenName + " " + p.FamilyName
+}
+
+// DefaultMockPersonas are the identities offered on the mock login page
+var DefaultMockPersonas = []MockPersona{
+	{ID: "max", GivenName: "Max", FamilyName: "Mustermann", DateOfBirth: "1980-01-01", Email: "max.mustermann@example.at"},
+	{ID: "erika", GivenName: "Erika", FamilyName: "Musterfrau", DateOfBirth: "1975-06-15", Email: "erika.musterfrau@example.at"},
+	{ID: "johann", GivenName: "Johann", FamilyName: "Huber", DateOfBirth: "1962-11-03", Email: "johann.huber@example.at"},
+	{ID: "sophie", GivenName: "Sophie", FamilyName: "Gruber", DateOfBirth: "1991-03-22", Email: "sophie.gruber@example.at"},
+}
+
+// MockProvider is an OIDC identity provider with fixed test identities
+type MockProvider struct {
+	issuer       string
+	basePath     string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	personas     []MockPersona
+	key          *rsa.PrivateKey
+	keyID        string
+	mux          *http.ServeMux
+
+	mu     sync.Mutex
+	codes  map[string]*mockGrant // authorization code -> grant
+	tokens map[string]*mockGrant // access token -> grant
+}
+
+// mockGrant is an authorization by one persona
+type mockGrant struct {
+	persona       *MockPersona
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	scope         string
+	expiresAt     time.Time
+}
+
+// NewMockProvider creates a mock identity provider for one registered client.
+// The provider serves its endpoints below the path of the issuer URL.
+func NewMockProvider(issuer, clientID, clientSecret, redirectURL string) (*MockProvider, error) {
+	u, err := url.Parse(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issuer: %w", err)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	keyID, err := generateRandomString(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key ID: %w", err)
+	}
+
+	p := &MockProvider{
+		issuer:       strings.TrimSuffix(issuer, "/"),
+		basePath:     strings.TrimSuffix(u.Path, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		personas:     DefaultMockPersonas,
+		key:          key,
+		keyID:        keyID,
+		mux:          http.NewServeMux(),
+		codes:        make(map[string]*mockGrant),
+		tokens:       make(map[string]*mockGrant),
+	}
+
+	base := p.BasePath()
+	p.mux.HandleFunc("GET "+base+"/.well-known/openid-configuration", p.discovery)
+	p.mux.HandleFunc("GET "+base+"/authorize", p.authorize)
+	p.mux.HandleFunc("POST "+base+"/token", p.token)
+	p.mux.HandleFunc("GET "+base+"/userinfo", p.userInfo)
+	p.mux.HandleFunc("GET "+base+"/jwks", p.jwks)
+
+	return p, nil
+}
+
+// BasePath returns the URL path the provider's endpoints live under
+func (p *MockProvider) BasePath() string {
+	return p.basePath
+}
+
+// ServeHTTP implements http.Handler
+func (p *MockProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mux.ServeHTTP(w, r)
+}
+
+// Persona returns the persona behind a subject
+func (p *MockProvider) Persona(subject string) (*MockPersona, bool) {
+	for i := range p.personas {
+		if p.subject(&p.personas[i]) == subject {
+			return &p.personas[i], true
+		}
+	}
+	return nil, false
+}
+
+// SubjectName returns the full name behind a subject, or "" if it is unknown
+func (p *MockProvider) SubjectName(subject string) string {
+	if persona, ok := p.Persona(subject); ok {
+		return persona.Name()
+	}
+	return ""
+}
+
+// subject returns the stable pairwise subject of a persona
+func (p *MockProvider) subject(persona *MockPersona) string {
+	hash := sha256.Sum256([]byte(p.issuer + "|" + p.clientID + "|" + persona.ID))
+	return base64.RawURLEncoding.EncodeToString(hash[:20])
+}
+
+// bpk returns a bPK in the format of the real one: Base64 of a SHA-1 hash
+func (p *MockProvider) bpk(persona *MockPersona) string {
+	hash := sha1.Sum([]byte("mock-bpk|" + persona.ID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+func (p *MockProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	mockJSON(w, http.StatusOK, OIDCConfig{
+		Issuer:                            p.issuer,
+		AuthorizationEndpoint:             p.issuer + "/authorize",
+		TokenEndpoint:                     p.issuer + "/token",
+		UserInfoEndpoint:                  p.issuer + "/userinfo",
+		JWKSEndpoint:                      p.issuer + "/jwks",
+		ScopesSupported:                   []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopeSignature, ScopeBPK},
+		ResponseTypesSupported:            []string{"code"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_post", "client_secret_basic"},
+		ClaimsSupported: []string{"sub", "name", "given_name", "family_name", "email",
+			"bpk", "bpk_type", "date_of_birth", "acr"},
+	})
+}
+
+var mockLoginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="de">
+<head><meta charset="utf-8"><title>ID Austria (Mock)</title></head>
+<body>
+<h1>ID Austria (Mock)</h1>
+<p>Lokale Testumgebung. Wählen Sie eine Testidentität:</p>
+<ul>
+{{range .Personas}}<li><a href="{{.URL}}">{{.Name}}</a> (geb. {{.DateOfBirth}})</li>
+{{end}}</ul>
+<p><a href="{{.CancelURL}}">Abbrechen</a></p>
+</body>
+</html>
+`))
+
+func (p *MockProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI := q.Get("redirect_uri")
+
+	// Without a valid client and redirect URI there is nowhere to send errors to
+	if q.Get("client_id") != p.clientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+	if redirectURI == "" || (p.redirectURL != "" && redirectURI != p.redirectURL) {
+		http.Error(w, "redirect_uri is not registered", http.StatusBadRequest)
+		return
+	}
+
+	fail := func(code, description string) {
+		params := url.Values{}
+		params.Set("error", code)
+		params.Set("error_description", description)
+		params.Set("state", q.Get("state"))
+		http.Redirect(w, r, appendQuery(redirectURI, params), http.StatusFound)
+	}
+
+	if q.Get("response_type") != "code" {
+		fail(ErrCodeUnsupportedResponse, "only the authorization code flow is supported")
+		return
+	}
+	if !containsScope(q.Get("scope"), ScopeOpenID) {
+		fail(ErrCodeInvalidScope, "the openid scope is required")
+		return
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		fail(ErrCodeInvalidRequest, "PKCE with S256 is required")
+		return
+	}
+
+	personaID := q.Get("persona")
+	if personaID == "" {
+		p.renderLogin(w, r)
+		return
+	}
+	if personaID == "cancel" {
+		fail(ErrCodeAccessDenied, "authentication cancelled by user")
+		return
+	}
+
+	var persona *MockPersona
+	for i := range p.personas {
+		if p.personas[i].ID == personaID {
+			persona = &p.personas[i]
+		}
+	}
+	if persona == nil {
+		fail(ErrCodeAccessDenied, "unknown test identity")
+		return
+	}
+
+	code, err := generateRandomString(32)
+	if err != nil {
+		fail(ErrCodeServerError, "failed to issue code")
+		return
+	}
+
+	p.mu.Lock()
+	p.pruneLocked(time.Now())
+	p.codes[code] = &mockGrant{
+		persona:       persona,
+		redirectURI:   redirectURI,
+		nonce:         q.Get("nonce"),
+		codeChallenge: q.Get("code_challenge"),
+		scope:         q.Get("scope"),
+		expiresAt:     time.Now().Add(mockCodeTTL),
+	}
+	p.mu.Unlock()
+
+	params := url.Values{}
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	http.Redirect(w, r, appendQuery(redirectURI, params), http.StatusFound)
+}
+
+func (p *MockProvider) renderLogin(w http.ResponseWriter, r *http.Request) {
+	type personaLink struct {
+		Name        string
+		DateOfBirth string
+		URL         string
+	}
+	link := func(id string) string {
+		q := r.URL.Query()
+		q.Set("persona", id)
+		return r.URL.Path + "?" + q.Encode()
+	}
+
+	data := struct {
+		Personas  []personaLink
+		CancelURL string
+	}{CancelURL: link("cancel")}
+	for i := range p.personas {
+		data.Personas = append(data.Personas, personaLink{
+			Name:        p.personas[i].Name(),
+			DateOfBirth: p.personas[i].DateOfBirth,
+			URL:         link(p.personas[i].ID),
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := mockLoginPage.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (p *MockProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		mockOIDCError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid form body")
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != p.clientID || clientSecret != p.clientSecret {
+		mockOIDCError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		mockOIDCError(w, http.StatusBadRequest, "unsupported_grant_type", "only authorization_code is supported")
+		return
+	}
+
+	now := time.Now()
+	code := r.PostForm.Get("code")
+
+	// Codes are single use, a failed exchange burns them as well
+	p.mu.Lock()
+	grant := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	if grant == nil || now.After(grant.expiresAt) {
+		mockOIDCError(w, http.StatusBadRequest, ErrCodeInvalidGrant, "authorization code is invalid or expired")
+		return
+	}
+	if r.PostForm.Get("redirect_uri") != grant.redirectURI {
+		mockOIDCError(w, http.StatusBadRequest, ErrCodeInvalidGrant, "redirect_uri does not match")
+		return
+	}
+	if generateCodeChallenge(r.PostForm.Get("code_verifier")) != grant.codeChallenge {
+		mockOIDCError(w, http.StatusBadRequest, ErrCodeInvalidGrant, "PKCE verification failed")
+		return
+	}
+
+	accessToken, err := generateRandomString(43)
+	if err != nil {
+		mockOIDCError(w, http.StatusInternalServerError, ErrCodeServerError, "failed to issue token")
+		return
+	}
+	idToken, err := p.signIDToken(grant, now)
+	if err != nil {
+		mockOIDCError(w, http.StatusInternalServerError, ErrCodeServerError, "failed to sign ID token")
+		return
+	}
+
+	p.mu.Lock()
+	p.tokens[accessToken] = &mockGrant{
+		persona:   grant.persona,
+		scope:     grant.scope,
+		expiresAt: now.Add(mockTokenTTL),
+	}
+	p.mu.Unlock()
+
+	w.Header().Set("Cache-Control", "no-store")
+	mockJSON(w, http.StatusOK, Token{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(mockTokenTTL.Seconds()),
+		IDToken:     idToken,
+		Scope:       grant.scope,
+	})
+}
+
+func (p *MockProvider) userInfo(w http.ResponseWriter, r *http.Request) {
+	accessToken, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		mockOIDCError(w, http.StatusUnauthorized, ErrCodeInvalidToken, "bearer token required")
+		return
+	}
+
+	p.mu.Lock()
+	grant := p.tokens[accessToken]
+	p.mu.Unlock()
+
+	if grant == nil || time.Now().After(grant.expiresAt) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		mockOIDCError(w, http.StatusUnauthorized, ErrCodeInvalidToken, "access token is invalid or expired")
+		return
+	}
+
+	persona := grant.persona
+	info := UserInfo{
+		Subject:     p.subject(persona),
+		Name:        persona.Name(),
+		GivenName:   persona.GivenName,
+		FamilyName:  persona.FamilyName,
+		BPK:         p.bpk(persona),
+		BPKType:     MockBPKType,
+		DateOfBirth: persona.DateOfBirth,
+	}
+	if containsScope(grant.scope, ScopeEmail) {
+		info.Email = persona.Email
+		info.EmailVerified = true
+	}
+	mockJSON(w, http.StatusOK, info)
+}
+
+func (p *MockProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := p.key.PublicKey
+	mockJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": p.keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// signIDToken issues an RS256 ID token for a grant
+func (p *MockProvider) signIDToken(grant *mockGrant, now time.Time) (string, error) {
+	persona := grant.persona
+	claims := IDTokenClaims{
+		Issuer:     p.issuer,
+		Subject:    p.subject(persona),
+		Audience:   p.clientID,
+		ExpiresAt:  now.Add(mockTokenTTL).Unix(),
+		IssuedAt:   now.Unix(),
+		Nonce:      grant.nonce,
+		Name:       persona.Name(),
+		GivenName:  persona.GivenName,
+		FamilyName: persona.FamilyName,
+		ACR:        MockACR,
+	}
+	if containsScope(grant.scope, ScopeEmail) {
+		claims.Email = persona.Email
+		claims.EmailVerified = true
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": p.keyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// pruneLocked drops expired codes and tokens; p.mu must be held
+func (p *MockProvider) pruneLocked(now time.Time) {
+	for code, grant := range p.codes {
+		if now.After(grant.expiresAt) {
+			delete(p.codes, code)
+		}
+	}
+	for token, grant := range p.tokens {
+		if now.After(grant.expiresAt) {
+			delete(p.tokens, token)
+		}
+	}
+}
+
+func containsScope(scope, want string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
+
+func appendQuery(rawURL string, params url.Values) string {
+	if strings.Contains(rawURL, "?") {
+		return rawURL + "&" + params.Encode()
+	}
+	return rawURL + "?" + params.Encode()
+}
+
+func mockJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func mockOIDCError(w http.ResponseWriter, status int, code, description string) {
+	mockJSON(w, status, map[string]string{"error": code, "error_description": description})
+}
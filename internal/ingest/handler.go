@@ -0,0 +1,279 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/apikey"
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/internal/filetype"
+	"austrian-business-infrastructure/internal/quota"
+)
+
+// Handler handles document ingestion HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new ingestion handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the ingestion routes. requireAuth accepts API
+// keys as well as user tokens; requireScope checks the scope of API keys.
+// Upload URLs are authorized by their signature.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth func(http.Handler) http.Handler, requireScope func(string) api.Middleware) {
+	write := func(f http.HandlerFunc) http.Handler { return requireAuth(requireScope("write:documents")(f)) }
+	read := func(f http.HandlerFunc) http.Handler { return requireAuth(requireScope("read:documents")(f)) }
+
+	router.Handle("POST /api/v1/ingest/documents", write(h.Submit))
+	router.Handle("POST /api/v1/ingest/batches", write(h.CreateBatch))
+	router.Handle("GET /api/v1/ingest/items/{id}", read(h.GetItem))
+	router.Handle("GET /api/v1/ingest/batches/{id}", read(h.GetBatch))
+	router.Handle("GET /api/v1/ingest/batches/{id}/items", read(h.ListBatchItems))
+	router.HandleFunc("PUT /api/v1/ingest/uploads/{id}", h.Upload)
+}
+
+// requestSource returns the source of the request, the API key or else
+// the user, writing 401 if there is none
+func requestSource(w http.ResponseWriter, r *http.Request) (Source, bool) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return Source{}, false
+	}
+	if key := apikey.GetAPIKey(r.Context()); key != nil {
+		return Source{ID: key.ID, TenantID: tenantID}, true
+	}
+	userID, ok := api.RequestUser(w, r)
+	return Source{ID: userID, TenantID: tenantID}, ok
+}
+
+// Submit handles POST /api/v1/ingest/documents, a multipart form with the
+// item as JSON in "metadata" and its content in "file"
+func (h *Handler) Submit(w http.ResponseWriter, r *http.Request) {
+	src, ok := requestSource(w, r)
+	if !ok {
+		return
+	}
+
+	// Leave room for the multipart framing and the metadata
+	r.Body = http.MaxBytesReader(w, r.Body, h.service.MaxContentSize()+1<<20)
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			api.JSONError(w, http.StatusRequestEntityTooLarge, ErrContentTooLarge.Error(), api.ErrCodeValidation)
+			return
+		}
+		api.BadRequest(w, "invalid multipart form")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	var input ItemInput
+	if err := json.Unmarshal([]byte(r.FormValue("metadata")), &input); err != nil {
+		api.BadRequest(w, "metadata must be the item as JSON")
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		api.BadRequest(w, "file required")
+		return
+	}
+	defer file.Close()
+	if input.ContentType == "" {
+		input.ContentType = header.Header.Get("Content-Type")
+		if input.ContentType == "application/octet-stream" {
+			input.ContentType = ""
+		}
+	}
+
+	item, err := h.service.Submit(r.Context(), src, &input, file)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/ingest/items/"+item.ID.String())
+	api.JSONResponse(w, http.StatusAccepted, item)
+}
+
+// CreateBatchRequest is the request body of announcing a batch
+type CreateBatchRequest struct {
+	Items []*ItemInput `json:"items"`
+}
+
+// CreateBatch handles POST /api/v1/ingest/batches
+func (h *Handler) CreateBatch(w http.ResponseWriter, r *http.Request) {
+	src, ok := requestSource(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateBatchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 16<<20)).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+	for i, item := range req.Items {
+		if item == nil {
+			api.JSONError(w, http.StatusUnprocessableEntity, "item "+strconv.Itoa(i)+" is empty", api.ErrCodeValidation)
+			return
+		}
+	}
+
+	batch, uploads, err := h.service.CreateBatch(r.Context(), src, req.Items)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/ingest/batches/"+batch.ID.String())
+	api.JSONResponse(w, http.StatusAccepted, map[string]interface{}{
+		"batch": batch,
+		"items": uploads,
+	})
+}
+
+// Upload handles PUT /api/v1/ingest/uploads/{id}, the upload URL of an
+// announced item. The body is the content.
+func (h *Handler) Upload(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.NotFound(w, "upload not found")
+		return
+	}
+
+	q := r.URL.Query()
+	r.Body = http.MaxBytesReader(w, r.Body, h.service.MaxContentSize()+1)
+	item, err := h.service.Upload(r.Context(), id, q.Get("expires"), q.Get("signature"), r.Header.Get("Content-Type"), r.Body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			err = ErrContentTooLarge
+		}
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusAccepted, item)
+}
+
+// GetItem handles GET /api/v1/ingest/items/{id}
+func (h *Handler) GetItem(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid id")
+		return
+	}
+
+	item, err := h.service.GetItem(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, item)
+}
+
+// GetBatch handles GET /api/v1/ingest/batches/{id}
+func (h *Handler) GetBatch(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid id")
+		return
+	}
+
+	batch, err := h.service.GetBatch(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, batch)
+}
+
+// ListBatchItems handles GET /api/v1/ingest/batches/{id}/items
+func (h *Handler) ListBatchItems(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid id")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", StatusAwaitingUpload, StatusQueued, StatusProcessing, StatusCompleted, StatusDuplicate, StatusFailed, StatusExpired:
+	default:
+		api.BadRequest(w, "invalid status")
+		return
+	}
+	limit, offset := 100, 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= MaxBatchItems {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	items, total, err := h.service.ListBatchItems(r.Context(), tenantID, id, status, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if items == nil {
+		items = []*Item{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  items,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrItemNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrBatchNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrInvalidUploadURL):
+		api.JSONError(w, http.StatusForbidden, err.Error(), api.ErrCodeForbidden)
+	case errors.Is(err, ErrAlreadyUploaded):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, document.ErrAccountNotOwned):
+		api.JSONError(w, http.StatusForbidden, "no access to this account", api.ErrCodeForbidden)
+	case errors.Is(err, ErrContentTooLarge):
+		api.JSONError(w, http.StatusRequestEntityTooLarge, err.Error(), api.ErrCodeValidation)
+	case errors.Is(err, ErrContentRejected):
+		api.JSONError(w, filetype.HTTPStatus(err), err.Error(), api.ErrCodeValidation)
+	case errors.Is(err, ErrInvalidItem), errors.Is(err, ErrBatchTooLarge), errors.Is(err, ErrEmptyContent):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	case errors.Is(err, ErrSourceBusy):
+		w.Header().Set("Retry-After", "300")
+		api.JSONError(w, http.StatusTooManyRequests, err.Error(), "RATE_LIMITED")
+	case errors.Is(err, quota.ErrQuotaExceeded):
+		api.JSONError(w, http.StatusRequestEntityTooLarge, err.Error(), api.ErrCodeValidation)
+	default:
+		api.InternalError(w)
+	}
+}
@@ -0,0 +1,294 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// staleAfter is how long an item may be processing before it is claimed
+// again, as the worker processing it is taken to have died
+const staleAfter = 30 * time.Minute
+
+// Repository provides ingestion data access
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new ingestion repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const itemColumns = `id, tenant_id, source_id, batch_id, account_id, external_id, type, title, sender, received_at,
+	content_type, metadata, staging_path, size, upload_expires_at, status, attempts, not_before, document_id, error,
+	created_at, updated_at, completed_at`
+
+// scanItem scans the item columns, followed by extra columns into extra
+func scanItem(row pgx.Row, extra ...any) (*Item, error) {
+	item := &Item{}
+	var metadata []byte
+	dest := []any{&item.ID, &item.TenantID, &item.SourceID, &item.BatchID, &item.AccountID, &item.ExternalID,
+		&item.Type, &item.Title, &item.Sender, &item.ReceivedAt, &item.ContentType, &metadata, &item.stagingPath,
+		&item.Size, &item.UploadExpiresAt, &item.Status, &item.Attempts, &item.notBefore, &item.DocumentID, &item.Error,
+		&item.CreatedAt, &item.UpdatedAt, &item.CompletedAt}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrItemNotFound
+		}
+		return nil, fmt.Errorf("scan ingestion item: %w", err)
+	}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &item.Metadata); err != nil {
+			return nil, fmt.Errorf("decode metadata: %w", err)
+		}
+	}
+	if item.Status == StatusAwaitingUpload && item.UploadExpiresAt != nil && time.Now().After(*item.UploadExpiresAt) {
+		item.Status = StatusExpired
+	}
+	return item, nil
+}
+
+// EnsureSource registers a source on its first submission
+func (r *Repository) EnsureSource(ctx context.Context, src Source) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO ingestion_sources (id, tenant_id) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING
+	`, src.ID, src.TenantID)
+	if err != nil {
+		return fmt.Errorf("register ingestion source: %w", err)
+	}
+	return nil
+}
+
+// CountPending returns the items of a source not yet processed
+func (r *Repository) CountPending(ctx context.Context, sourceID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM ingestion_items WHERE source_id = $1 AND status IN ('queued', 'processing')
+	`, sourceID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count pending items: %w", err)
+	}
+	return count, nil
+}
+
+func insertItem(ctx context.Context, q interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}, item *Item) error {
+	metadata, err := json.Marshal(item.Metadata)
+	if err != nil {
+		return fmt.Errorf("encode metadata: %w", err)
+	}
+	if item.Metadata == nil {
+		metadata = []byte("{}")
+	}
+	err = q.QueryRow(ctx, `
+		INSERT INTO ingestion_items (tenant_id, source_id, batch_id, account_id, external_id, type, title, sender,
+			received_at, content_type, metadata, staging_path, size, upload_expires_at, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id, not_before, created_at, updated_at
+	`, item.TenantID, item.SourceID, item.BatchID, item.AccountID, item.ExternalID, item.Type, item.Title, item.Sender,
+		item.ReceivedAt, item.ContentType, metadata, item.stagingPath, item.Size, item.UploadExpiresAt, item.Status,
+	).Scan(&item.ID, &item.notBefore, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("create ingestion item: %w", err)
+	}
+	return nil
+}
+
+// CreateItem inserts an item
+func (r *Repository) CreateItem(ctx context.Context, item *Item) error {
+	return insertItem(ctx, r.db, item)
+}
+
+// CreateBatch inserts a batch with its items
+func (r *Repository) CreateBatch(ctx context.Context, batch *Batch, items []*Item) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO ingestion_batches (tenant_id, source_id) VALUES ($1, $2) RETURNING id, created_at
+	`, batch.TenantID, batch.SourceID).Scan(&batch.ID, &batch.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create ingestion batch: %w", err)
+	}
+	for _, item := range items {
+		item.BatchID = &batch.ID
+		if err := insertItem(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// GetItem returns an item of a tenant
+func (r *Repository) GetItem(ctx context.Context, tenantID, id uuid.UUID) (*Item, error) {
+	return scanItem(r.db.QueryRow(ctx, `
+		SELECT `+itemColumns+` FROM ingestion_items WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id))
+}
+
+// GetItemByID returns an item of any tenant
+func (r *Repository) GetItemByID(ctx context.Context, id uuid.UUID) (*Item, error) {
+	return scanItem(r.db.QueryRow(ctx, `SELECT `+itemColumns+` FROM ingestion_items WHERE id = $1`, id))
+}
+
+// MarkUploaded queues an announced item with its staged content. It
+// returns ErrAlreadyUploaded unless the item is still awaiting its upload.
+func (r *Repository) MarkUploaded(ctx context.Context, item *Item, stagingPath string, size int64, contentType string) error {
+	err := r.db.QueryRow(ctx, `
+		UPDATE ingestion_items
+		SET staging_path = $2, size = $3, content_type = $4, status = 'queued', not_before = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'awaiting_upload' AND upload_expires_at > NOW()
+		RETURNING status, updated_at
+	`, item.ID, stagingPath, size, contentType).Scan(&item.Status, &item.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrAlreadyUploaded
+		}
+		return fmt.Errorf("mark item uploaded: %w", err)
+	}
+	item.stagingPath, item.Size, item.ContentType = &stagingPath, &size, &contentType
+	return nil
+}
+
+// GetBatch returns a batch of a tenant with the counts of its items
+func (r *Repository) GetBatch(ctx context.Context, tenantID, id uuid.UUID) (*Batch, error) {
+	b := &Batch{}
+	err := r.db.QueryRow(ctx, `
+		SELECT b.id, b.tenant_id, b.source_id, b.created_at,
+			COUNT(i.id),
+			COUNT(i.id) FILTER (WHERE i.status = 'awaiting_upload' AND i.upload_expires_at > NOW()),
+			COUNT(i.id) FILTER (WHERE i.status = 'queued'),
+			COUNT(i.id) FILTER (WHERE i.status = 'processing'),
+			COUNT(i.id) FILTER (WHERE i.status = 'completed'),
+			COUNT(i.id) FILTER (WHERE i.status = 'duplicate'),
+			COUNT(i.id) FILTER (WHERE i.status = 'failed'),
+			COUNT(i.id) FILTER (WHERE i.status = 'awaiting_upload' AND i.upload_expires_at <= NOW())
+		FROM ingestion_batches b
+		LEFT JOIN ingestion_items i ON i.batch_id = b.id
+		WHERE b.tenant_id = $1 AND b.id = $2
+		GROUP BY b.id
+	`, tenantID, id).Scan(&b.ID, &b.TenantID, &b.SourceID, &b.CreatedAt, &b.Total,
+		&b.Counts.AwaitingUpload, &b.Counts.Queued, &b.Counts.Processing, &b.Counts.Completed,
+		&b.Counts.Duplicate, &b.Counts.Failed, &b.Counts.Expired)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBatchNotFound
+		}
+		return nil, fmt.Errorf("get ingestion batch: %w", err)
+	}
+	b.Status = b.status()
+	return b, nil
+}
+
+// ListBatchItems returns the items of a batch in submission order,
+// optionally of one status, and their total
+func (r *Repository) ListBatchItems(ctx context.Context, batchID uuid.UUID, status string, limit, offset int) ([]*Item, int, error) {
+	where := `batch_id = $1`
+	args := []any{batchID, limit, offset}
+	switch status {
+	case "":
+	case StatusAwaitingUpload:
+		where += ` AND status = 'awaiting_upload' AND upload_expires_at > NOW()`
+	case StatusExpired:
+		where += ` AND status = 'awaiting_upload' AND upload_expires_at <= NOW()`
+	default:
+		where += ` AND status = $4`
+		args = append(args, status)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+itemColumns+`, COUNT(*) OVER()
+		FROM ingestion_items WHERE `+where+`
+		ORDER BY created_at, id
+		LIMIT $2 OFFSET $3
+	`, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list ingestion items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*Item
+	total := 0
+	for rows.Next() {
+		item, err := scanItem(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	return items, total, rows.Err()
+}
+
+// ClaimNext claims the next item to process. Items of the source served
+// longest ago come first, then the oldest item of that source; items of a
+// worker that died are claimed again. It returns ErrItemNotFound if no item
+// is due.
+func (r *Repository) ClaimNext(ctx context.Context) (*Item, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	item, err := scanItem(tx.QueryRow(ctx, `
+		UPDATE ingestion_items
+		SET status = 'processing', attempts = attempts + 1, started_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT i.id FROM ingestion_items i
+			JOIN ingestion_sources s ON s.id = i.source_id
+			WHERE (i.status = 'queued' AND i.not_before <= NOW())
+				OR (i.status = 'processing' AND i.started_at < NOW() - make_interval(secs => $1))
+			ORDER BY s.last_served_at ASC NULLS FIRST, i.created_at, i.id
+			LIMIT 1
+			FOR UPDATE OF i SKIP LOCKED
+		)
+		RETURNING `+itemColumns, staleAfter.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE ingestion_sources SET last_served_at = NOW() WHERE id = $1`, item.SourceID); err != nil {
+		return nil, fmt.Errorf("mark source served: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit claim: %w", err)
+	}
+	return item, nil
+}
+
+// Finish records the outcome of a processed item, completed or duplicate
+// with its document, or failed with its error
+func (r *Repository) Finish(ctx context.Context, id uuid.UUID, status string, documentID *uuid.UUID, errMsg *string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE ingestion_items
+		SET status = $2, document_id = $3, error = $4, staging_path = NULL, completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, id, status, documentID, errMsg)
+	if err != nil {
+		return fmt.Errorf("finish ingestion item: %w", err)
+	}
+	return nil
+}
+
+// Retry queues an item again after a failed attempt
+func (r *Repository) Retry(ctx context.Context, id uuid.UUID, errMsg string, notBefore time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE ingestion_items SET status = 'queued', error = $2, not_before = $3, updated_at = NOW() WHERE id = $1
+	`, id, errMsg, notBefore)
+	if err != nil {
+		return fmt.Errorf("retry ingestion item: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,489 @@
+package ingest
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/internal/filetype"
+	"austrian-business-infrastructure/internal/quota"
+)
+
+// Documents creates the documents of processed items
+type Documents interface {
+	Create(ctx context.Context, tenantID string, input *document.CreateDocumentInput) (*document.Document, error)
+}
+
+// Scheduler queues the processing of an item with the worker; every
+// queued item has one job, which processes whichever item is next
+type Scheduler interface {
+	ScheduleIngestion(ctx context.Context, tenantID uuid.UUID, runAt time.Time) error
+}
+
+// Config holds the ingestion settings
+type Config struct {
+	// UploadBaseURL is the public base URL of the API the upload URLs of
+	// announced items point to
+	UploadBaseURL string
+	// UploadKey signs the upload URLs
+	UploadKey []byte
+	// UploadTTL is how long an upload URL is valid
+	UploadTTL time.Duration
+	// MaxContentSize is the largest content accepted
+	MaxContentSize int64
+	// MaxPendingPerSource is how many items a source may have waiting for
+	// processing; 0 is unlimited
+	MaxPendingPerSource int
+}
+
+// Service accepts items for ingestion and processes them
+type Service struct {
+	repo            *Repository
+	staging         document.Storage
+	docs            Documents
+	scheduler       Scheduler
+	accountVerifier document.AccountVerifier
+	quotaChecker    document.QuotaChecker
+	policy          filetype.Policy
+	cfg             Config
+}
+
+// NewService creates a new ingestion service. Content is staged in
+// staging, the document storage, until docs creates the document.
+func NewService(repo *Repository, staging document.Storage, docs Documents, cfg Config) *Service {
+	if cfg.UploadTTL <= 0 {
+		cfg.UploadTTL = time.Hour
+	}
+	if cfg.MaxContentSize <= 0 {
+		cfg.MaxContentSize = document.DefaultMaxDocumentSize
+	}
+	cfg.UploadBaseURL = strings.TrimSuffix(cfg.UploadBaseURL, "/")
+	return &Service{repo: repo, staging: staging, docs: docs, policy: filetype.Ingestion, cfg: cfg}
+}
+
+// SetScheduler sets the scheduler of queued items. Without one, items are
+// stored but not processed.
+func (s *Service) SetScheduler(scheduler Scheduler) {
+	s.scheduler = scheduler
+}
+
+// SetAccountVerifier checks on submission that the account of an item
+// belongs to the tenant, rather than only when it is processed
+func (s *Service) SetAccountVerifier(verifier document.AccountVerifier) {
+	s.accountVerifier = verifier
+}
+
+// SetQuotaChecker refuses content on submission once the tenant's storage
+// quota is used up, rather than only when it is processed
+func (s *Service) SetQuotaChecker(checker document.QuotaChecker) {
+	s.quotaChecker = checker
+}
+
+// MaxContentSize returns the largest content accepted
+func (s *Service) MaxContentSize() int64 {
+	return s.cfg.MaxContentSize
+}
+
+// ValidateInput checks the metadata of an item
+func ValidateInput(in *ItemInput) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: %s", ErrInvalidItem, reason)
+	}
+	in.Type = strings.TrimSpace(in.Type)
+	in.Title = strings.TrimSpace(in.Title)
+	in.ExternalID = strings.TrimSpace(in.ExternalID)
+	in.Sender = strings.TrimSpace(in.Sender)
+	in.ContentType = strings.TrimSpace(in.ContentType)
+
+	switch {
+	case in.AccountID == uuid.Nil:
+		return invalid("account_id is required")
+	case in.Type == "" || utf8.RuneCountInString(in.Type) > 100:
+		return invalid("type is required (at most 100 characters)")
+	case in.Title == "" || utf8.RuneCountInString(in.Title) > 500:
+		return invalid("title is required (at most 500 characters)")
+	case utf8.RuneCountInString(in.ExternalID) > 255:
+		return invalid("external_id must be at most 255 characters")
+	case utf8.RuneCountInString(in.Sender) > 255:
+		return invalid("sender must be at most 255 characters")
+	case len(in.ContentType) > 100:
+		return invalid("content_type must be at most 100 characters")
+	}
+	return nil
+}
+
+// Submit accepts an item with its content. The content is staged and the
+// item queued; it is acknowledged before the document exists.
+func (s *Service) Submit(ctx context.Context, src Source, in *ItemInput, content io.Reader) (*Item, error) {
+	if err := ValidateInput(in); err != nil {
+		return nil, err
+	}
+	if err := s.verifyAccounts(ctx, src.TenantID, []*ItemInput{in}); err != nil {
+		return nil, err
+	}
+	if err := s.checkCapacity(ctx, src, 1); err != nil {
+		return nil, err
+	}
+	if err := s.repo.EnsureSource(ctx, src); err != nil {
+		return nil, err
+	}
+
+	path, size, contentType, err := s.stage(ctx, src.TenantID, in.AccountID, in.ContentType, content)
+	if err != nil {
+		return nil, err
+	}
+	item := newItem(src, in)
+	item.Status = StatusQueued
+	item.stagingPath, item.Size, item.ContentType = &path, &size, &contentType
+	if err := s.repo.CreateItem(ctx, item); err != nil {
+		_ = s.staging.Delete(ctx, path)
+		return nil, err
+	}
+
+	if err := s.schedule(ctx, item, time.Now()); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// CreateBatch announces items whose content is uploaded separately, and
+// returns a signed upload URL for each
+func (s *Service) CreateBatch(ctx context.Context, src Source, inputs []*ItemInput) (*Batch, []*Upload, error) {
+	if len(inputs) == 0 {
+		return nil, nil, fmt.Errorf("%w: items are required", ErrInvalidItem)
+	}
+	if len(inputs) > MaxBatchItems {
+		return nil, nil, fmt.Errorf("%w: at most %d", ErrBatchTooLarge, MaxBatchItems)
+	}
+	for i, in := range inputs {
+		if err := ValidateInput(in); err != nil {
+			return nil, nil, fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	if err := s.verifyAccounts(ctx, src.TenantID, inputs); err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkCapacity(ctx, src, len(inputs)); err != nil {
+		return nil, nil, err
+	}
+	if err := s.repo.EnsureSource(ctx, src); err != nil {
+		return nil, nil, err
+	}
+
+	expiresAt := time.Now().Add(s.cfg.UploadTTL).Truncate(time.Second)
+	items := make([]*Item, len(inputs))
+	for i, in := range inputs {
+		items[i] = newItem(src, in)
+		items[i].Status = StatusAwaitingUpload
+		items[i].UploadExpiresAt = &expiresAt
+	}
+	batch := &Batch{TenantID: src.TenantID, SourceID: src.ID}
+	if err := s.repo.CreateBatch(ctx, batch, items); err != nil {
+		return nil, nil, err
+	}
+	batch.Total = len(items)
+	batch.Counts.AwaitingUpload = len(items)
+	batch.Status = batch.status()
+
+	uploads := make([]*Upload, len(items))
+	for i, item := range items {
+		uploads[i] = &Upload{
+			ItemID:     item.ID,
+			ExternalID: item.ExternalID,
+			URL:        s.UploadURL(item.ID, expiresAt),
+			ExpiresAt:  expiresAt,
+		}
+	}
+	return batch, uploads, nil
+}
+
+// UploadURL returns the signed URL to upload the content of an item to
+func (s *Service) UploadURL(itemID uuid.UUID, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	return s.cfg.UploadBaseURL + "/api/v1/ingest/uploads/" + itemID.String() +
+		"?expires=" + expires + "&signature=" + s.sign(itemID, expires)
+}
+
+func (s *Service) sign(itemID uuid.UUID, expires string) string {
+	mac := hmac.New(sha256.New, s.cfg.UploadKey)
+	mac.Write([]byte(itemID.String() + ":" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Upload stores the content of an announced item, authorized by the
+// signature of its upload URL, and queues the item
+func (s *Service) Upload(ctx context.Context, itemID uuid.UUID, expires, signature, contentType string, content io.Reader) (*Item, error) {
+	if len(s.cfg.UploadKey) == 0 || !hmac.Equal([]byte(signature), []byte(s.sign(itemID, expires))) {
+		return nil, ErrInvalidUploadURL
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return nil, ErrInvalidUploadURL
+	}
+
+	item, err := s.repo.GetItemByID(ctx, itemID)
+	if errors.Is(err, ErrItemNotFound) {
+		return nil, ErrInvalidUploadURL
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch item.Status {
+	case StatusAwaitingUpload:
+	case StatusExpired:
+		return nil, ErrInvalidUploadURL
+	default:
+		return nil, ErrAlreadyUploaded
+	}
+	if item.ContentType != nil {
+		contentType = *item.ContentType
+	}
+
+	path, size, contentType, err := s.stage(ctx, item.TenantID, item.AccountID, contentType, content)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.MarkUploaded(ctx, item, path, size, contentType); err != nil {
+		_ = s.staging.Delete(ctx, path)
+		return nil, err
+	}
+
+	if err := s.schedule(ctx, item, time.Now()); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetItem returns an item of a tenant
+func (s *Service) GetItem(ctx context.Context, tenantID, id uuid.UUID) (*Item, error) {
+	return s.repo.GetItem(ctx, tenantID, id)
+}
+
+// GetBatch returns a batch of a tenant with the counts of its items
+func (s *Service) GetBatch(ctx context.Context, tenantID, id uuid.UUID) (*Batch, error) {
+	return s.repo.GetBatch(ctx, tenantID, id)
+}
+
+// ListBatchItems returns the items of a batch of a tenant, optionally of
+// one status, and their total
+func (s *Service) ListBatchItems(ctx context.Context, tenantID, batchID uuid.UUID, status string, limit, offset int) ([]*Item, int, error) {
+	if _, err := s.repo.GetBatch(ctx, tenantID, batchID); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListBatchItems(ctx, batchID, status, limit, offset)
+}
+
+// ProcessNext turns the next item into a document. It returns nil if no
+// item is due. An item that fails for reasons that may pass is queued
+// again, up to MaxAttempts; the error returned is only that of recording
+// the outcome.
+func (s *Service) ProcessNext(ctx context.Context) (*Item, error) {
+	item, err := s.repo.ClaimNext(ctx)
+	if errors.Is(err, ErrItemNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := s.createDocument(ctx, item)
+	switch {
+	case err == nil:
+		item.Status, item.DocumentID = StatusCompleted, &doc.ID
+	case errors.Is(err, document.ErrDuplicateDocument) && doc != nil:
+		item.Status, item.DocumentID = StatusDuplicate, &doc.ID
+	case permanent(err) || item.Attempts >= MaxAttempts:
+		msg := err.Error()
+		item.Status, item.Error = StatusFailed, &msg
+	default:
+		// Wait 1, 4, 9 ... minutes before the next attempt
+		notBefore := time.Now().Add(time.Duration(item.Attempts*item.Attempts) * time.Minute)
+		if err := s.repo.Retry(ctx, item.ID, err.Error(), notBefore); err != nil {
+			return nil, err
+		}
+		item.Status = StatusQueued
+		return item, s.schedule(ctx, item, notBefore)
+	}
+
+	if err := s.repo.Finish(ctx, item.ID, item.Status, item.DocumentID, item.Error); err != nil {
+		return nil, err
+	}
+	if item.stagingPath != nil {
+		_ = s.staging.Delete(ctx, *item.stagingPath)
+	}
+	return item, nil
+}
+
+func (s *Service) createDocument(ctx context.Context, item *Item) (*document.Document, error) {
+	if item.stagingPath == nil {
+		return nil, fmt.Errorf("%w: content missing", ErrInvalidItem)
+	}
+	content, _, err := s.staging.Get(ctx, *item.stagingPath)
+	if err != nil {
+		return nil, fmt.Errorf("read staged content: %w", err)
+	}
+	defer content.Close()
+
+	input := &document.CreateDocumentInput{
+		AccountID:  item.AccountID,
+		Type:       item.Type,
+		Title:      item.Title,
+		ReceivedAt: item.CreatedAt,
+		Content:    content,
+		Metadata:   item.Metadata,
+	}
+	if item.ExternalID != nil {
+		input.ExternalID = *item.ExternalID
+	}
+	if item.Sender != nil {
+		input.Sender = *item.Sender
+	}
+	if item.ReceivedAt != nil {
+		input.ReceivedAt = *item.ReceivedAt
+	}
+	if item.ContentType != nil {
+		input.ContentType = *item.ContentType
+	}
+	return s.docs.Create(ctx, item.TenantID.String(), input)
+}
+
+// permanent reports whether an attempt failed for a reason another attempt
+// does not change
+func permanent(err error) bool {
+	return errors.Is(err, document.ErrAccountNotOwned) ||
+		errors.Is(err, document.ErrDocumentTooLarge) ||
+		errors.Is(err, quota.ErrQuotaExceeded) ||
+		errors.Is(err, document.ErrStorageNotFound) ||
+		errors.Is(err, ErrInvalidItem)
+}
+
+// schedule queues the processing of an item. If that fails, the item is
+// failed, as no job would pick it up.
+func (s *Service) schedule(ctx context.Context, item *Item, runAt time.Time) error {
+	if s.scheduler == nil {
+		return nil
+	}
+	if err := s.scheduler.ScheduleIngestion(ctx, item.TenantID, runAt); err != nil {
+		msg := "could not be queued for processing"
+		ctx = context.WithoutCancel(ctx)
+		if ferr := s.repo.Finish(ctx, item.ID, StatusFailed, nil, &msg); ferr == nil && item.stagingPath != nil {
+			_ = s.staging.Delete(ctx, *item.stagingPath)
+		}
+		return fmt.Errorf("schedule ingestion: %w", err)
+	}
+	return nil
+}
+
+// stage stores content in document storage until it is processed. The
+// content is spooled to a temporary file first and identified as on
+// uploads: its type must be allowed for ingestion and match the declared
+// content type, and PDFs and images must be within the page and size
+// limits. The returned content type is the one identified.
+func (s *Service) stage(ctx context.Context, tenantID, accountID uuid.UUID, contentType string, content io.Reader) (string, int64, string, error) {
+	spool, err := os.CreateTemp("", "ingest-*")
+	if err != nil {
+		return "", 0, "", fmt.Errorf("spool content: %w", err)
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	size, err := io.Copy(spool, io.LimitReader(content, s.cfg.MaxContentSize+1))
+	if err != nil {
+		return "", 0, "", fmt.Errorf("read content: %w", err)
+	}
+	if size == 0 {
+		return "", 0, "", ErrEmptyContent
+	}
+	if size > s.cfg.MaxContentSize {
+		return "", 0, "", ErrContentTooLarge
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+	info, err := s.policy.CheckDeclared(contentType, spool, size)
+	if err != nil {
+		return "", 0, "", fmt.Errorf("%w: %w", ErrContentRejected, err)
+	}
+	if s.quotaChecker != nil {
+		if err := s.quotaChecker.CheckQuota(ctx, tenantID, size); err != nil {
+			return "", 0, "", err
+		}
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return "", 0, "", fmt.Errorf("spool content: %w", err)
+	}
+	stored, err := s.staging.Store(ctx, tenantID.String(), accountID.String(), "ingest-"+uuid.NewString(), spool, info.MIMEType)
+	if err != nil {
+		return "", 0, "", fmt.Errorf("stage content: %w", err)
+	}
+	return stored.Path, stored.Size, info.MIMEType, nil
+}
+
+// checkCapacity refuses items beyond the pending limit of a source
+func (s *Service) checkCapacity(ctx context.Context, src Source, n int) error {
+	if s.cfg.MaxPendingPerSource <= 0 {
+		return nil
+	}
+	pending, err := s.repo.CountPending(ctx, src.ID)
+	if err != nil {
+		return err
+	}
+	if pending+n > s.cfg.MaxPendingPerSource {
+		return ErrSourceBusy
+	}
+	return nil
+}
+
+func (s *Service) verifyAccounts(ctx context.Context, tenantID uuid.UUID, inputs []*ItemInput) error {
+	if s.accountVerifier == nil {
+		return nil
+	}
+	verified := make(map[uuid.UUID]bool)
+	for _, in := range inputs {
+		if verified[in.AccountID] {
+			continue
+		}
+		if err := s.accountVerifier.VerifyAccountOwnership(ctx, in.AccountID, tenantID); err != nil {
+			return document.ErrAccountNotOwned
+		}
+		verified[in.AccountID] = true
+	}
+	return nil
+}
+
+func newItem(src Source, in *ItemInput) *Item {
+	item := &Item{
+		TenantID:   src.TenantID,
+		SourceID:   src.ID,
+		AccountID:  in.AccountID,
+		Type:       in.Type,
+		Title:      in.Title,
+		ReceivedAt: in.ReceivedAt,
+		Metadata:   in.Metadata,
+	}
+	if in.ExternalID != "" {
+		item.ExternalID = &in.ExternalID
+	}
+	if in.Sender != "" {
+		item.Sender = &in.Sender
+	}
+	if in.ContentType != "" {
+		item.ContentType = &in.ContentType
+	}
+	return item
+}
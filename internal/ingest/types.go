@@ -0,0 +1,145 @@
+// Package ingest accepts documents pushed via the API in high volume. A
+// submission is acknowledged at once with a tracking ID and processed by
+// the worker later: the content is staged in document storage and turned
+// into a document by a job. Sources, the API keys or users submitting, are
+// served in turn, so that a partner pushing thousands of documents
+// overnight does not hold up the others.
+package ingest
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrItemNotFound     = errors.New("ingestion item not found")
+	ErrBatchNotFound    = errors.New("ingestion batch not found")
+	ErrInvalidItem      = errors.New("invalid ingestion item")
+	ErrBatchTooLarge    = errors.New("too many items in batch")
+	ErrContentTooLarge  = errors.New("content exceeds maximum allowed size")
+	ErrEmptyContent     = errors.New("content is empty")
+	ErrContentRejected  = errors.New("content rejected")
+	ErrSourceBusy       = errors.New("too many items pending for this source")
+	ErrInvalidUploadURL = errors.New("invalid or expired upload URL")
+	ErrAlreadyUploaded  = errors.New("content has already been uploaded")
+)
+
+// Item statuses
+const (
+	StatusAwaitingUpload = "awaiting_upload"
+	StatusQueued         = "queued"
+	StatusProcessing     = "processing"
+	StatusCompleted      = "completed"
+	StatusDuplicate      = "duplicate"
+	StatusFailed         = "failed"
+	// StatusExpired is reported for items whose upload URL expired unused;
+	// it is not stored
+	StatusExpired = "expired"
+)
+
+// Batch statuses, derived from the statuses of the items
+const (
+	BatchAwaitingUpload = "awaiting_upload"
+	BatchProcessing     = "processing"
+	BatchDone           = "done"
+)
+
+// Limits
+const (
+	// MaxBatchItems is the most items a batch may announce at once
+	MaxBatchItems = 1000
+	// MaxAttempts is how often an item is tried before it fails
+	MaxAttempts = 3
+)
+
+// Source is who submits items: an API key, or the user of a user token.
+// It is the unit of fair scheduling.
+type Source struct {
+	ID       uuid.UUID
+	TenantID uuid.UUID
+}
+
+// ItemInput is the metadata of a document to ingest
+type ItemInput struct {
+	AccountID   uuid.UUID              `json:"account_id"`
+	ExternalID  string                 `json:"external_id,omitempty"`
+	Type        string                 `json:"type"`
+	Title       string                 `json:"title"`
+	Sender      string                 `json:"sender,omitempty"`
+	ReceivedAt  *time.Time             `json:"received_at,omitempty"`
+	ContentType string                 `json:"content_type,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Item is a document submitted for ingestion and its processing state
+type Item struct {
+	ID          uuid.UUID              `json:"id"`
+	TenantID    uuid.UUID              `json:"-"`
+	SourceID    uuid.UUID              `json:"-"`
+	BatchID     *uuid.UUID             `json:"batch_id,omitempty"`
+	AccountID   uuid.UUID              `json:"account_id"`
+	ExternalID  *string                `json:"external_id,omitempty"`
+	Type        string                 `json:"type"`
+	Title       string                 `json:"title"`
+	Sender      *string                `json:"sender,omitempty"`
+	ReceivedAt  *time.Time             `json:"received_at,omitempty"`
+	ContentType *string                `json:"content_type,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Size        *int64                 `json:"size,omitempty"`
+
+	Status          string     `json:"status"`
+	Attempts        int        `json:"attempts"`
+	DocumentID      *uuid.UUID `json:"document_id,omitempty"`
+	Error           *string    `json:"error,omitempty"`
+	UploadExpiresAt *time.Time `json:"upload_expires_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+
+	stagingPath *string
+	notBefore   time.Time
+}
+
+// Upload is where the content of an announced item is to be uploaded
+type Upload struct {
+	ItemID     uuid.UUID `json:"id"`
+	ExternalID *string   `json:"external_id,omitempty"`
+	URL        string    `json:"upload_url"`
+	ExpiresAt  time.Time `json:"upload_expires_at"`
+}
+
+// Counts are the items of a batch per status
+type Counts struct {
+	AwaitingUpload int `json:"awaiting_upload"`
+	Queued         int `json:"queued"`
+	Processing     int `json:"processing"`
+	Completed      int `json:"completed"`
+	Duplicate      int `json:"duplicate"`
+	Failed         int `json:"failed"`
+	Expired        int `json:"expired"`
+}
+
+// Batch is a set of items submitted together
+type Batch struct {
+	ID        uuid.UUID `json:"id"`
+	TenantID  uuid.UUID `json:"-"`
+	SourceID  uuid.UUID `json:"-"`
+	Status    string    `json:"status"`
+	Total     int       `json:"total"`
+	Counts    Counts    `json:"counts"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// status derives the batch status from the counts
+func (b *Batch) status() string {
+	switch {
+	case b.Counts.AwaitingUpload > 0:
+		return BatchAwaitingUpload
+	case b.Counts.Queued > 0 || b.Counts.Processing > 0:
+		return BatchProcessing
+	default:
+		return BatchDone
+	}
+}
@@ -3,6 +3,7 @@ package invitation
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"time"
@@ -21,6 +22,8 @@ type Handler struct {
 	tenantService *tenant.Service
 	jwtManager    *auth.JWTManager
 	sessionMgr    *auth.SessionManager
+	policies      *auth.SecurityPolicyService
+	clientIP      func(*http.Request) string
 	logger        *slog.Logger
 }
 
@@ -41,6 +44,14 @@ func NewHandler(
 	}
 }
 
+// SetSecurityPolicies enforces the security policy of the inviting tenant
+// on the login that follows accepting an invitation. clientIP resolves the
+// client address the way logins do.
+func (h *Handler) SetSecurityPolicies(policies *auth.SecurityPolicyService, clientIP func(*http.Request) string) {
+	h.policies = policies
+	h.clientIP = clientIP
+}
+
 // RegisterRoutes registers invitation routes
 func (h *Handler) RegisterRoutes(router *api.Router, authMw *auth.AuthMiddleware) {
 	// Protected routes - require authentication
@@ -202,6 +213,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	tenantID, _ := uuid.Parse(api.GetTenantID(r.Context()))
 	if invitation.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "invitation", invitation.ID.String())
 		api.NotFound(w, "Invitation not found")
 		return
 	}
@@ -289,6 +301,9 @@ type AcceptResponse struct {
 	RefreshToken string   `json:"refresh_token"`
 	TokenType    string   `json:"token_type"`
 	ExpiresIn    int      `json:"expires_in"`
+	// TwoFactorSetupRequired is set when the tenant requires 2FA; the
+	// access token is then only good for setting it up
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required,omitempty"`
 }
 
 // UserDTO is a data transfer object for users
@@ -326,11 +341,26 @@ func (h *Handler) Accept(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The account exists now; signing in follows the tenant's policy
+	policy := auth.DefaultSecurityPolicy(u.TenantID)
+	if h.policies != nil {
+		if policy, err = h.policies.Get(r.Context(), u.TenantID); err != nil {
+			h.logger.Error("failed to load security policy", "error", err)
+			api.InternalError(w)
+			return
+		}
+		if !policy.AllowsIP(h.clientIP(r)) {
+			api.JSONError(w, http.StatusForbidden, "Your account was created, but your organization does not allow sign-in from this network", api.ErrCodeIPNotAllowed)
+			return
+		}
+	}
+
 	// Generate tokens (Email intentionally excluded from JWT per FR-104)
 	tokens, err := h.jwtManager.GenerateTokenPair(&auth.UserInfo{
-		UserID:   u.ID.String(),
-		TenantID: u.TenantID.String(),
-		Role:     string(u.Role),
+		UserID:    u.ID.String(),
+		TenantID:  u.TenantID.String(),
+		Role:      string(u.Role),
+		Enroll2FA: policy.Require2FA,
 	})
 
 	if err != nil {
@@ -364,6 +394,8 @@ func (h *Handler) Accept(w http.ResponseWriter, r *http.Request) {
 		RefreshToken: tokens.RefreshToken,
 		TokenType:    "Bearer",
 		ExpiresIn:    900,
+
+		TwoFactorSetupRequired: policy.Require2FA,
 	})
 }
 
@@ -376,8 +408,13 @@ func (h *Handler) handleAcceptError(w http.ResponseWriter, err error) {
 	case errors.Is(err, ErrInvitationUsed):
 		api.Conflict(w, "Invitation has already been used")
 	case errors.Is(err, crypto.ErrPasswordTooShort):
+		message := "Password must be at least 12 characters"
+		var short *crypto.PasswordTooShortError
+		if errors.As(err, &short) {
+			message = fmt.Sprintf("Password must be at least %d characters", short.MinLength)
+		}
 		api.ValidationError(w, map[string]string{
-			"password": "Password must be at least 12 characters",
+			"password": message,
 		})
 	case errors.Is(err, user.ErrUserEmailExists):
 		api.Conflict(w, "An account with this email already exists")
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"austrian-business-infrastructure/internal/analytics"
 	"austrian-business-infrastructure/internal/user"
 	"austrian-business-infrastructure/pkg/crypto"
 	"github.com/google/uuid"
@@ -43,10 +44,19 @@ type CreateInvitationResult struct {
 	Token      string // Plain text token (only returned once)
 }
 
+// AcceptHook is called after a user joined by accepting an invitation;
+// team.Service implements it
+type AcceptHook interface {
+	UserJoined(ctx context.Context, u *user.User) error
+}
+
 // Service provides invitation business logic
 type Service struct {
-	repo     *Repository
-	userRepo *user.Repository
+	repo             *Repository
+	userRepo         *user.Repository
+	acceptHook       AcceptHook
+	analytics        *analytics.Emitter
+	passwordPolicies user.PasswordPolicySource
 }
 
 // NewService creates a new invitation service
@@ -57,6 +67,22 @@ func NewService(repo *Repository, userRepo *user.Repository) *Service {
 	}
 }
 
+// SetAcceptHook sets the hook called when a user joined
+func (s *Service) SetAcceptHook(hook AcceptHook) {
+	s.acceptHook = hook
+}
+
+// SetAnalytics reports users joining through an invitation to the product analytics
+func (s *Service) SetAnalytics(emitter *analytics.Emitter) {
+	s.analytics = emitter
+}
+
+// SetPasswordPolicies makes the passwords of joining users meet the policy
+// of the inviting tenant. Without it the default policy applies.
+func (s *Service) SetPasswordPolicies(policies user.PasswordPolicySource) {
+	s.passwordPolicies = policies
+}
+
 // Create creates a new invitation
 func (s *Service) Create(ctx context.Context, input *CreateInvitationInput) (*CreateInvitationResult, error) {
 	email := normalizeEmail(input.Email)
@@ -144,7 +170,14 @@ func (s *Service) Accept(ctx context.Context, token string, name string, passwor
 	}
 
 	// Validate and hash password
-	if err := crypto.ValidatePassword(password, nil); err != nil {
+	var policy *crypto.PasswordPolicy
+	if s.passwordPolicies != nil {
+		policy, err = s.passwordPolicies.PasswordPolicy(ctx, invitation.TenantID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := crypto.ValidatePassword(password, policy); err != nil {
 		return nil, fmt.Errorf("invalid password: %w", err)
 	}
 
@@ -171,6 +204,13 @@ func (s *Service) Accept(ctx context.Context, token string, name string, passwor
 		// Log but don't fail - user was created
 	}
 
+	if s.acceptHook != nil {
+		// The user exists either way; a failed hook only loses extras such
+		// as team memberships
+		_ = s.acceptHook.UserJoined(ctx, newUser)
+	}
+	s.analytics.Track(ctx, newUser.TenantID, newUser.ID, analytics.EventUserJoined, analytics.Properties{"role": invitation.Role})
+
 	return newUser, nil
 }
 
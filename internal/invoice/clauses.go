@@ -0,0 +1,215 @@
+package invoice
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Branch constants used to select sector-specific clauses
+const (
+	BranchConstruction = "construction" // Bauwirtschaft
+	BranchUsedGoods    = "used_goods"   // Gebrauchtwarenhandel
+	BranchTravel       = "travel"       // Reisebüros
+)
+
+// Tax code constants for special schemes that are not expressed by a line tax category
+const (
+	TaxCodeMarginUsedGoods = "margin_used_goods" // Differenzbesteuerung § 24 UStG
+	TaxCodeMarginTravel    = "margin_travel"     // Reiseleistungen § 23 UStG
+	TaxCodeSmallBusiness   = "kleinunternehmer"  // § 6 Abs. 1 Z 27 UStG
+	TaxCodeOSS             = "oss"               // One-Stop-Shop, Art. 25a UStG: taxed in the buyer's country
+)
+
+// Clause is a mandatory or recommended invoice sentence.
+// A clause applies when all of its non-empty selectors match the invoice.
+type Clause struct {
+	ID          *uuid.UUID `json:"id,omitempty"` // nil for built-in clauses
+	TenantID    *uuid.UUID `json:"tenant_id,omitempty"`
+	Code        string     `json:"code"`
+	Group       string     `json:"group,omitempty"` // Only the most specific match per group applies
+	Branch      string     `json:"branch,omitempty"`
+	TaxCode     string     `json:"tax_code,omitempty"`
+	TaxCategory string     `json:"tax_category,omitempty"` // EN 16931 category on any line
+	Text        string     `json:"text"`
+	LegalBasis  string     `json:"legal_basis,omitempty"`
+	Mandatory   bool       `json:"mandatory"`
+	BuiltIn     bool       `json:"built_in"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// builtinClauses is the clause library shipped with the platform
+var builtinClauses = []*Clause{
+	{
+		Code:        "reverse_charge",
+		Group:       "reverse_charge",
+		TaxCategory: "AE",
+		Text:        "Übergang der Steuerschuld auf den Leistungsempfänger (Reverse Charge).",
+		LegalBasis:  "§ 19 Abs. 1 UStG, Art. 196 MwStSystRL",
+		Mandatory:   true,
+	},
+	{
+		Code:        "reverse_charge_construction",
+		Group:       "reverse_charge",
+		Branch:      BranchConstruction,
+		TaxCategory: "AE",
+		Text:        "Übergang der Steuerschuld gemäß § 19 Abs. 1a UStG (Bauleistungen). Die Umsatzsteuer wird vom Leistungsempfänger geschuldet.",
+		LegalBasis:  "§ 19 Abs. 1a UStG",
+		Mandatory:   true,
+	},
+	{
+		Code:        "intra_community_supply",
+		TaxCategory: "K",
+		Text:        "Steuerfreie innergemeinschaftliche Lieferung.",
+		LegalBasis:  "Art. 6 Abs. 1 UStG",
+		Mandatory:   true,
+	},
+	{
+		Code:        "export_supply",
+		TaxCategory: "G",
+		Text:        "Steuerfreie Ausfuhrlieferung.",
+		LegalBasis:  "§ 6 Abs. 1 Z 1 iVm § 7 UStG",
+		Mandatory:   true,
+	},
+	{
+		Code:       "margin_used_goods",
+		TaxCode:    TaxCodeMarginUsedGoods,
+		Text:       "Differenzbesteuerung – Sonderregelung für Gebrauchtgegenstände.",
+		LegalBasis: "§ 24 UStG",
+		Mandatory:  true,
+	},
+	{
+		Code:       "margin_travel",
+		TaxCode:    TaxCodeMarginTravel,
+		Text:       "Sonderregelung für Reisebüros.",
+		LegalBasis: "§ 23 UStG",
+		Mandatory:  true,
+	},
+	{
+		Code:       "small_business",
+		TaxCode:    TaxCodeSmallBusiness,
+		Text:       "Umsatzsteuerbefreit – Kleinunternehmer.",
+		LegalBasis: "§ 6 Abs. 1 Z 27 UStG",
+		Mandatory:  true,
+	},
+}
+
+// BuiltinClauses returns a copy of the built-in clause library
+func BuiltinClauses() []*Clause {
+	clauses := make([]*Clause, 0, len(builtinClauses))
+	for _, c := range builtinClauses {
+		cp := *c
+		cp.BuiltIn = true
+		clauses = append(clauses, &cp)
+	}
+	return clauses
+}
+
+// RequiredClauses returns the clauses that apply to an invoice. Tenant clauses
+// override built-in clauses with the same code.
+func RequiredClauses(inv *Invoice, items []*InvoiceItem, tenantClauses []*Clause) []*Clause {
+	library := make(map[string]*Clause)
+	for _, c := range BuiltinClauses() {
+		library[c.Code] = c
+	}
+	for _, c := range tenantClauses {
+		library[c.Code] = c
+	}
+
+	categories := make(map[string]bool)
+	for _, item := range items {
+		categories[item.TaxCategory] = true
+	}
+
+	var matches []*Clause
+	for _, c := range library {
+		if c.Branch != "" && (inv.Branch == nil || *inv.Branch != c.Branch) {
+			continue
+		}
+		if c.TaxCode != "" && (inv.TaxCode == nil || *inv.TaxCode != c.TaxCode) {
+			continue
+		}
+		if c.TaxCategory != "" && !categories[c.TaxCategory] {
+			continue
+		}
+		if c.Branch == "" && c.TaxCode == "" && c.TaxCategory == "" {
+			continue
+		}
+		matches = append(matches, c)
+	}
+
+	// Keep only the most specific clause of each group
+	best := make(map[string]*Clause)
+	var result []*Clause
+	for _, c := range matches {
+		if c.Group == "" {
+			result = append(result, c)
+			continue
+		}
+		if cur, ok := best[c.Group]; !ok || specificity(c) > specificity(cur) {
+			best[c.Group] = c
+		}
+	}
+	for _, c := range best {
+		result = append(result, c)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Code < result[j].Code })
+	return result
+}
+
+// MissingClauses returns the codes of mandatory clauses that were not applied
+func MissingClauses(required []*Clause, applied []string) []string {
+	have := make(map[string]bool, len(applied))
+	for _, code := range applied {
+		have[code] = true
+	}
+
+	var missing []string
+	for _, c := range required {
+		if c.Mandatory && !have[c.Code] {
+			missing = append(missing, c.Code)
+		}
+	}
+	return missing
+}
+
+// clauseNotes appends the clause texts to the invoice notes
+func clauseNotes(notes *string, clauses []*Clause) string {
+	var parts []string
+	if notes != nil && *notes != "" {
+		parts = append(parts, *notes)
+	}
+	for _, c := range clauses {
+		text := c.Text
+		if c.LegalBasis != "" && !strings.Contains(text, c.LegalBasis) {
+			text += " (" + c.LegalBasis + ")"
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func clauseCodes(clauses []*Clause) []string {
+	codes := make([]string, 0, len(clauses))
+	for _, c := range clauses {
+		codes = append(codes, c.Code)
+	}
+	return codes
+}
+
+func specificity(c *Clause) int {
+	n := 0
+	if c.Branch != "" {
+		n++
+	}
+	if c.TaxCode != "" {
+		n++
+	}
+	if c.TaxCategory != "" {
+		n++
+	}
+	return n
+}
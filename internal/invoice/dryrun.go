@@ -0,0 +1,88 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/customfield"
+	"austrian-business-infrastructure/internal/dryrun"
+	"austrian-business-infrastructure/internal/erechnung"
+	"github.com/google/uuid"
+)
+
+// DryRun runs the validation of an invoice as Create, Validate and
+// GenerateXML would: the input, the invoice number, the EN 16931 business
+// rules, the VAT rates of the supply date and the rendering in the given
+// format, with the required clauses. Nothing is stored.
+func (s *Service) DryRun(ctx context.Context, tenantID uuid.UUID, input *CreateInvoiceInput, format string) (*dryrun.Report, error) {
+	report := dryrun.NewReport(dryrun.KindInvoice)
+	now := time.Now()
+
+	if strings.TrimSpace(input.InvoiceNumber) == "" {
+		report.Error("missing_number", "invoice_number", "invoice_number is required")
+	} else {
+		exists, err := s.repo.NumberExists(ctx, tenantID, input.InvoiceNumber)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			report.Error("duplicate_number", "invoice_number", ErrDuplicateNumber.Error())
+		}
+	}
+	if input.SellerName == "" {
+		report.Error("missing_seller", "seller_name", "seller_name is required")
+	}
+	if input.BuyerName == "" {
+		report.Error("missing_buyer", "buyer_name", "buyer_name is required")
+	}
+
+	inv, items, err := s.build(ctx, tenantID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNoItems):
+			report.Error("no_items", "items", err.Error())
+		case customfield.IsInvalid(err):
+			report.Error("invalid_custom_field", "custom_fields", err.Error())
+		case errors.Is(err, ErrInvalidIssueDate):
+			report.Error("invalid_date", "issue_date", err.Error())
+		case errors.Is(err, ErrInvalidDueDate):
+			report.Error("invalid_date", "due_date", err.Error())
+		case errors.Is(err, ErrProjectNotFound):
+			report.Error("unknown_project", "project_id", err.Error())
+		default:
+			return nil, err
+		}
+		return report.Finish(now), nil
+	}
+	inv.TenantID = tenantID
+
+	ereInv := s.toErechnungInvoice(inv, items)
+	result := erechnung.ValidateInvoice(ereInv)
+	CheckVATRates(result, ereInv, inv.TaxCode)
+	for _, e := range result.Errors {
+		report.Error(e.Code, e.Field, e.Message)
+	}
+	for _, w := range result.Warnings {
+		report.Warn(w.Code, w.Field, w.Message)
+	}
+	if report.HasErrors() {
+		return report.Finish(now), nil
+	}
+
+	clauses, err := s.requiredClauses(ctx, inv, items)
+	if err != nil {
+		return nil, err
+	}
+	ereInv.Notes = clauseNotes(inv.Notes, clauses)
+	if format == FormatZUGFeRD {
+		_, err = erechnung.GenerateZUGFeRD(ereInv)
+	} else {
+		_, err = erechnung.GenerateXRechnung(ereInv)
+	}
+	if err != nil {
+		report.Error("xml", "", err.Error())
+	}
+	return report.Finish(now), nil
+}
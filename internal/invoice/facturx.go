@@ -0,0 +1,257 @@
+package invoice
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/encoding/charmap"
+
+	"austrian-business-infrastructure/internal/pdfa"
+)
+
+// PDF profiles of GET /api/v1/invoices/{id}/pdf
+const (
+	ProfilePlain   = ""
+	ProfileFacturX = "facturx"
+)
+
+// Factur-X (ZUGFeRD 2) identification of the embedded invoice
+const (
+	FacturXFileName = "factur-x.xml"
+
+	// facturXLevel is the profile of the CII XML erechnung.GenerateZUGFeRD
+	// writes (urn:factur-x.eu:1p0:extended)
+	facturXLevel = "EXTENDED"
+	facturXNS    = "urn:factur-x:pdfa:CrossIndustryDocument:invoice:1p0#"
+)
+
+// GenerateFacturXPDF renders the invoice as a Factur-X / ZUGFeRD hybrid: a
+// PDF/A-3b file with the CII invoice XML embedded as factur-x.xml, so that
+// people read the PDF and the customer's software reads the XML. The layout
+// is that of GenerateBrandedPDF, with an embedded font as PDF/A requires.
+func GenerateFacturXPDF(inv *Invoice, items []*InvoiceItem, clauses []*Clause, brand *PDFBrand, ciiXML []byte, created time.Time) ([]byte, error) {
+	if len(ciiXML) == 0 {
+		return nil, fmt.Errorf("no invoice XML to embed")
+	}
+	metrics, err := facturXFontMetrics()
+	if err != nil {
+		return nil, fmt.Errorf("load font: %w", err)
+	}
+
+	title := "Rechnung " + inv.InvoiceNumber
+	if inv.InvoiceType == "381" {
+		title = "Gutschrift " + inv.InvoiceNumber
+	}
+	return renderPDF(inv, items, clauses, brand, &facturXArchive{
+		xml:     ciiXML,
+		title:   title,
+		author:  inv.SellerName,
+		created: created.UTC(),
+		metrics: metrics,
+	})
+}
+
+// facturXArchive adds what PDF/A-3 and Factur-X require to the invoice PDF:
+// embedded font, sRGB output intent, XMP metadata with the Factur-X
+// extension schema and the XML as associated file
+type facturXArchive struct {
+	xml     []byte
+	title   string
+	author  string
+	created time.Time
+	metrics *pdfFontMetrics
+}
+
+// Archive objects, numbered from the base
+const (
+	fxFontDescriptor = iota
+	fxFontFile
+	fxICCProfile
+	fxMetadata
+	fxEmbeddedFile
+	fxFileSpec
+)
+
+func (a *facturXArchive) header() string {
+	// The comment with high bytes marks the file as binary (ISO 19005 6.1.2)
+	return "%PDF-1.7\n%\xe2\xe3\xcf\xd3\n"
+}
+
+func (a *facturXArchive) font(base int) string {
+	widths := make([]string, len(a.metrics.widths))
+	for i, w := range a.metrics.widths {
+		widths[i] = fmt.Sprint(w)
+	}
+	return fmt.Sprintf("<< /Type /Font /Subtype /TrueType /BaseFont /%s /FirstChar %d /LastChar %d /Widths [%s] /FontDescriptor %d 0 R /Encoding /WinAnsiEncoding >>",
+		a.metrics.name, pdfFirstChar, pdfFirstChar+len(widths)-1, strings.Join(widths, " "), base+fxFontDescriptor)
+}
+
+func (a *facturXArchive) catalog(base int) string {
+	return fmt.Sprintf(" /Metadata %d 0 R"+
+		" /OutputIntents [<< /Type /OutputIntent /S /GTS_PDFA1 /OutputConditionIdentifier (sRGB) /Info (sRGB) /DestOutputProfile %d 0 R >>]"+
+		" /Names << /EmbeddedFiles << /Names [(%s) %d 0 R] >> >> /AF [%d 0 R]",
+		base+fxMetadata, base+fxICCProfile, FacturXFileName, base+fxFileSpec, base+fxFileSpec)
+}
+
+func (a *facturXArchive) objects(base int) []string {
+	f := a.metrics
+	fontFile := deflate(goregular.TTF)
+	icc := pdfa.SRGBProfile()
+	xmp := a.metadata()
+	modDate := a.created.Format("D:20060102150405Z")
+
+	return []string{
+		fmt.Sprintf("%d 0 obj\n<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>\nendobj\n",
+			base+fxFontDescriptor, f.name, f.bbox[0], f.bbox[1], f.bbox[2], f.bbox[3], f.ascent, f.descent, f.capHeight, base+fxFontFile),
+		fmt.Sprintf("%d 0 obj\n<< /Length %d /Length1 %d /Filter /FlateDecode >>\nstream\n%s\nendstream\nendobj\n",
+			base+fxFontFile, len(fontFile), len(goregular.TTF), fontFile),
+		fmt.Sprintf("%d 0 obj\n<< /N 3 /Length %d >>\nstream\n%s\nendstream\nendobj\n",
+			base+fxICCProfile, len(icc), icc),
+		fmt.Sprintf("%d 0 obj\n<< /Type /Metadata /Subtype /XML /Length %d >>\nstream\n%s\nendstream\nendobj\n",
+			base+fxMetadata, len(xmp), xmp),
+		fmt.Sprintf("%d 0 obj\n<< /Type /EmbeddedFile /Subtype /text#2Fxml /Params << /ModDate (%s) /Size %d >> /Length %d >>\nstream\n%s\nendstream\nendobj\n",
+			base+fxEmbeddedFile, modDate, len(a.xml), len(a.xml), a.xml),
+		// Alternative: the XML is an equivalent rendition of the invoice,
+		// as Factur-X requires from profile EN 16931 upwards
+		fmt.Sprintf("%d 0 obj\n<< /Type /Filespec /F (%s) /UF (%s) /Desc (Factur-X invoice) /AFRelationship /Alternative /EF << /F %d 0 R /UF %d 0 R >> >>\nendobj\n",
+			base+fxFileSpec, FacturXFileName, FacturXFileName, base+fxEmbeddedFile, base+fxEmbeddedFile),
+	}
+}
+
+// trailer adds the file identifier PDF/A requires, derived from the content
+// so the same invoice always gets the same ID
+func (a *facturXArchive) trailer() string {
+	sum := md5.Sum(append([]byte(a.title+"\x00"), a.xml...))
+	id := hex.EncodeToString(sum[:])
+	return fmt.Sprintf(" /ID [<%s> <%s>]", id, id)
+}
+
+// metadata is the XMP packet: PDF/A-3b identification, document title, and
+// the Factur-X properties with the extension schema that declares them
+func (a *facturXArchive) metadata() string {
+	esc := func(s string) string {
+		var b strings.Builder
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+	property := func(name, description string) string {
+		return `<rdf:li rdf:parseType="Resource"><pdfaProperty:name>` + name + `</pdfaProperty:name>` +
+			`<pdfaProperty:valueType>Text</pdfaProperty:valueType><pdfaProperty:category>external</pdfaProperty:category>` +
+			`<pdfaProperty:description>` + description + `</pdfaProperty:description></rdf:li>`
+	}
+	created := a.created.Format(time.RFC3339)
+
+	return `<?xpacket begin="` + "\ufeff" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description rdf:about="" xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/"><pdfaid:part>3</pdfaid:part><pdfaid:conformance>B</pdfaid:conformance></rdf:Description>
+<rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:format>application/pdf</dc:format>` +
+		`<dc:title><rdf:Alt><rdf:li xml:lang="x-default">` + esc(a.title) + `</rdf:li></rdf:Alt></dc:title>` +
+		`<dc:creator><rdf:Seq><rdf:li>` + esc(a.author) + `</rdf:li></rdf:Seq></dc:creator></rdf:Description>
+<rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/"><xmp:CreateDate>` + created + `</xmp:CreateDate><xmp:ModifyDate>` + created + `</xmp:ModifyDate><xmp:CreatorTool>austrian-business-infrastructure</xmp:CreatorTool></rdf:Description>
+<rdf:Description rdf:about="" xmlns:pdf="http://ns.adobe.com/pdf/1.3/"><pdf:Producer>austrian-business-infrastructure</pdf:Producer></rdf:Description>
+<rdf:Description rdf:about="" xmlns:fx="` + facturXNS + `"><fx:DocumentType>INVOICE</fx:DocumentType><fx:DocumentFileName>` + FacturXFileName + `</fx:DocumentFileName><fx:Version>1.0</fx:Version><fx:ConformanceLevel>` + facturXLevel + `</fx:ConformanceLevel></rdf:Description>
+<rdf:Description rdf:about="" xmlns:pdfaExtension="http://www.aiim.org/pdfa/ns/extension/" xmlns:pdfaSchema="http://www.aiim.org/pdfa/ns/schema#" xmlns:pdfaProperty="http://www.aiim.org/pdfa/ns/property#">
+<pdfaExtension:schemas><rdf:Bag><rdf:li rdf:parseType="Resource">
+<pdfaSchema:schema>Factur-X PDFA Extension Schema</pdfaSchema:schema><pdfaSchema:namespaceURI>` + facturXNS + `</pdfaSchema:namespaceURI><pdfaSchema:prefix>fx</pdfaSchema:prefix>
+<pdfaSchema:property><rdf:Seq>` +
+		property("DocumentFileName", "Name of the embedded XML invoice file") +
+		property("DocumentType", "INVOICE") +
+		property("Version", "Version of the Factur-X XML schema") +
+		property("ConformanceLevel", "Conformance level of the embedded XML invoice") +
+		`</rdf:Seq></pdfaSchema:property>
+</rdf:li></rdf:Bag></pdfaExtension:schemas>
+</rdf:Description>
+</rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+}
+
+// pdfFirstChar is the first WinAnsi code with a width in the font dictionary
+const pdfFirstChar = 32
+
+// pdfFontMetrics describes the embedded font in PDF glyph space (1/1000 em)
+type pdfFontMetrics struct {
+	name      string
+	widths    []int // codes pdfFirstChar to 255 in WinAnsiEncoding
+	bbox      [4]int
+	ascent    int
+	descent   int
+	capHeight int
+}
+
+var (
+	fontOnce    sync.Once
+	fontMetrics *pdfFontMetrics
+	fontErr     error
+)
+
+// facturXFontMetrics reads the metrics of Go Regular, the font embedded in
+// archival invoices, once
+func facturXFontMetrics() (*pdfFontMetrics, error) {
+	fontOnce.Do(func() { fontMetrics, fontErr = loadFontMetrics(goregular.TTF) })
+	return fontMetrics, fontErr
+}
+
+func loadFontMetrics(ttf []byte) (*pdfFontMetrics, error) {
+	f, err := sfnt.Parse(ttf)
+	if err != nil {
+		return nil, err
+	}
+	var buf sfnt.Buffer
+	unitsPerEm := int(f.UnitsPerEm())
+	ppem := fixed.I(unitsPerEm) // one unit per pixel
+	scale := func(v fixed.Int26_6) int { return v.Round() * 1000 / unitsPerEm }
+
+	name, err := f.Name(&buf, sfnt.NameIDPostScript)
+	if err != nil || name == "" {
+		name = "GoRegular"
+	}
+	m := &pdfFontMetrics{name: name}
+
+	for code := pdfFirstChar; code <= 255; code++ {
+		width := 0
+		r := charmap.Windows1252.DecodeByte(byte(code))
+		if gi, err := f.GlyphIndex(&buf, r); err == nil && gi != 0 {
+			if adv, err := f.GlyphAdvance(&buf, gi, ppem, font.HintingNone); err == nil {
+				width = scale(adv)
+			}
+		}
+		m.widths = append(m.widths, width)
+	}
+
+	metrics, err := f.Metrics(&buf, ppem, font.HintingNone)
+	if err != nil {
+		return nil, err
+	}
+	m.ascent = scale(metrics.Ascent)
+	m.descent = -scale(metrics.Descent)
+	m.capHeight = scale(metrics.CapHeight)
+
+	// sfnt's y axis points down
+	bounds, err := f.Bounds(&buf, ppem, font.HintingNone)
+	if err != nil {
+		return nil, err
+	}
+	m.bbox = [4]int{scale(bounds.Min.X), -scale(bounds.Max.Y), scale(bounds.Max.X), -scale(bounds.Min.Y)}
+	return m, nil
+}
+
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	zw, _ := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
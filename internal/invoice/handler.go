@@ -1,12 +1,16 @@
 package invoice
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"net/http"
 	"strconv"
 	"time"
 
 	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/customfield"
+	"austrian-business-infrastructure/internal/permission"
+	"austrian-business-infrastructure/pkg/money"
 	"github.com/google/uuid"
 )
 
@@ -20,18 +24,31 @@ func NewHandler(service *Service) *Handler {
 	return &Handler{service: service}
 }
 
-// RegisterRoutes registers invoice routes
-func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
-	// Admin-only: create, delete invoices
-	router.Handle("POST /api/v1/invoices", requireAuth(requireAdmin(http.HandlerFunc(h.Create))))
-	router.Handle("DELETE /api/v1/invoices/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Delete))))
-
-	// Member access: read and generate operations
-	router.Handle("GET /api/v1/invoices", requireAuth(http.HandlerFunc(h.List)))
-	router.Handle("GET /api/v1/invoices/{id}", requireAuth(http.HandlerFunc(h.Get)))
-	router.Handle("POST /api/v1/invoices/{id}/validate", requireAuth(http.HandlerFunc(h.Validate)))
-	router.Handle("POST /api/v1/invoices/{id}/generate", requireAuth(http.HandlerFunc(h.Generate)))
-	router.Handle("GET /api/v1/invoices/{id}/xml", requireAuth(http.HandlerFunc(h.GetXML)))
+// RegisterRoutes registers invoice routes. Sending requires
+// invoices:send, other changes invoices:write.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth func(http.Handler) http.Handler, requirePermission func(permission.Permission) func(http.Handler) http.Handler) {
+	requireRead := requirePermission(permission.InvoicesRead)
+	requireWrite := requirePermission(permission.InvoicesWrite)
+
+	router.Handle("POST /api/v1/invoices", requireAuth(requireWrite(http.HandlerFunc(h.Create))))
+	router.Handle("DELETE /api/v1/invoices/{id}", requireAuth(requireWrite(http.HandlerFunc(h.Delete))))
+	router.Handle("POST /api/v1/invoices/{id}/send", requireAuth(requirePermission(permission.InvoicesSend)(http.HandlerFunc(h.Send))))
+	router.Handle("PATCH /api/v1/invoices/{id}/custom-fields", requireAuth(requireWrite(http.HandlerFunc(h.UpdateCustomFields))))
+	router.Handle("POST /api/v1/invoice-clauses", requireAuth(requireWrite(http.HandlerFunc(h.CreateClause))))
+	router.Handle("DELETE /api/v1/invoice-clauses/{id}", requireAuth(requireWrite(http.HandlerFunc(h.DeleteClause))))
+
+	// Read and generate operations
+	router.Handle("GET /api/v1/invoices", requireAuth(requireRead(http.HandlerFunc(h.List))))
+	router.Handle("GET /api/v1/invoices/export", requireAuth(requireRead(http.HandlerFunc(h.Export))))
+	router.Handle("GET /api/v1/invoices/{id}", requireAuth(requireRead(http.HandlerFunc(h.Get))))
+	router.Handle("POST /api/v1/invoices/dry-run", requireAuth(requireRead(http.HandlerFunc(h.DryRun))))
+	router.Handle("POST /api/v1/invoices/{id}/validate", requireAuth(requireRead(http.HandlerFunc(h.Validate))))
+	router.Handle("POST /api/v1/invoices/{id}/generate", requireAuth(requireRead(http.HandlerFunc(h.Generate))))
+	router.Handle("GET /api/v1/invoices/{id}/xml", requireAuth(requireRead(http.HandlerFunc(h.GetXML))))
+	router.Handle("POST /api/v1/invoices/{id}/pdf", requireAuth(requireRead(http.HandlerFunc(h.GeneratePDF))))
+	router.Handle("GET /api/v1/invoices/{id}/pdf", requireAuth(requireRead(http.HandlerFunc(h.GetPDF))))
+	router.Handle("GET /api/v1/invoices/{id}/clauses", requireAuth(requireRead(http.HandlerFunc(h.CheckClauses))))
+	router.Handle("GET /api/v1/invoice-clauses", requireAuth(requireRead(http.HandlerFunc(h.ListClauses))))
 }
 
 // Create handles POST /api/v1/invoices
@@ -84,6 +101,117 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filter, err := h.parseListFilter(r, tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	invoices, total, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	items := make([]*InvoiceResponse, 0, len(invoices))
+	for _, inv := range invoices {
+		items = append(items, h.toResponse(inv, nil))
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  items,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// Export handles GET /api/v1/invoices/export. It takes the filters of List
+// and writes up to MaxExportRows invoices as CSV, one column per custom field
+// after the fixed columns.
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	filter, err := h.parseListFilter(r, tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	defs, err := h.service.CustomFieldDefinitions(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	invoices, err := h.service.ListForExport(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=invoices.csv")
+
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	header := []string{"Invoice Number", "Type", "Issue Date", "Due Date", "Buyer", "Buyer VAT",
+		"Buyer Reference", "Branch", "Net", "Tax", "Gross", "Currency", "Status"}
+	writer.Write(append(header, customfield.CSVHeader(defs)...))
+
+	for _, inv := range invoices {
+		dueDate := ""
+		if inv.DueDate != nil {
+			dueDate = inv.DueDate.Format("2006-01-02")
+		}
+
+		row := []string{
+			customfield.CSVCell(inv.InvoiceNumber),
+			inv.InvoiceType,
+			inv.IssueDate.Format("2006-01-02"),
+			dueDate,
+			customfield.CSVCell(inv.BuyerName),
+			customfield.CSVCell(derefString(inv.BuyerVAT)),
+			customfield.CSVCell(derefString(inv.BuyerReference)),
+			customfield.CSVCell(derefString(inv.Branch)),
+			money.New(inv.TaxExclusiveAmount, inv.Currency).Decimal(),
+			money.New(inv.TaxAmount, inv.Currency).Decimal(),
+			money.New(inv.TaxInclusiveAmount, inv.Currency).Decimal(),
+			inv.Currency,
+			inv.Status,
+		}
+		writer.Write(append(row, customfield.CSVValues(defs, inv.CustomFields)...))
+	}
+}
+
+// UpdateCustomFields handles PATCH /api/v1/invoices/{id}/custom-fields
+func (h *Handler) UpdateCustomFields(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.getTenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	var input map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	inv, err := h.service.UpdateCustomFields(r.Context(), id, tenantID, input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, h.toResponse(inv, nil))
+}
+
+// parseListFilter reads the list filters shared by List and Export
+func (h *Handler) parseListFilter(r *http.Request, tenantID uuid.UUID) (ListFilter, error) {
 	filter := ListFilter{
 		TenantID: tenantID,
 		Limit:    50,
@@ -100,6 +228,12 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if projectIDStr := r.URL.Query().Get("project_id"); projectIDStr != "" {
+		if projectID, err := uuid.Parse(projectIDStr); err == nil {
+			filter.ProjectID = &projectID
+		}
+	}
+
 	if dateFromStr := r.URL.Query().Get("date_from"); dateFromStr != "" {
 		if dateFrom, err := time.Parse("2006-01-02", dateFromStr); err == nil {
 			filter.DateFrom = &dateFrom
@@ -129,23 +263,13 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	invoices, total, err := h.service.List(r.Context(), filter)
+	cfFilters, err := h.service.CustomFieldFilters(r.Context(), tenantID, customfield.ParseFilterParams(r.URL.Query()))
 	if err != nil {
-		api.InternalError(w)
-		return
-	}
-
-	items := make([]*InvoiceResponse, 0, len(invoices))
-	for _, inv := range invoices {
-		items = append(items, h.toResponse(inv, nil))
+		return filter, err
 	}
+	filter.CustomFields = cfFilters
 
-	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
-		"items":  items,
-		"total":  total,
-		"limit":  filter.Limit,
-		"offset": filter.Offset,
-	})
+	return filter, nil
 }
 
 // Get handles GET /api/v1/invoices/{id}
@@ -193,6 +317,39 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// DryRun handles POST /api/v1/invoices/dry-run?format=xrechnung|zugferd.
+// The body is that of Create; nothing is stored.
+func (h *Handler) DryRun(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = FormatXRechnung
+	}
+	if format != FormatXRechnung && format != FormatZUGFeRD {
+		api.BadRequest(w, "format must be 'xrechnung' or 'zugferd'")
+		return
+	}
+
+	var input CreateInvoiceInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	report, err := h.service.DryRun(r.Context(), tenantID, &input, format)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, report)
+}
+
 // Validate handles POST /api/v1/invoices/{id}/validate
 func (h *Handler) Validate(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := h.getTenantID(r)
@@ -297,8 +454,183 @@ func (h *Handler) GetXML(w http.ResponseWriter, r *http.Request) {
 	w.Write(xmlContent)
 }
 
+// GeneratePDF handles POST /api/v1/invoices/{id}/pdf
+func (h *Handler) GeneratePDF(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.getTenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	pdf, err := h.service.GeneratePDF(r.Context(), id, tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	writePDF(w, pdf)
+}
+
+// GetPDF handles GET /api/v1/invoices/{id}/pdf. With ?profile=facturx the
+// invoice is rendered as Factur-X PDF/A-3 instead of the stored PDF.
+func (h *Handler) GetPDF(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.getTenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	var pdf []byte
+	var err error
+	switch r.URL.Query().Get("profile") {
+	case ProfilePlain:
+		pdf, err = h.service.GetPDF(r.Context(), id, tenantID)
+	case ProfileFacturX:
+		pdf, err = h.service.FacturXPDF(r.Context(), id, tenantID)
+	default:
+		api.BadRequest(w, "profile must be facturx or omitted")
+		return
+	}
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	if pdf == nil {
+		api.NotFound(w, "PDF not generated yet")
+		return
+	}
+
+	writePDF(w, pdf)
+}
+
+// CheckClauses handles GET /api/v1/invoices/{id}/clauses
+func (h *Handler) CheckClauses(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.getTenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	check, err := h.service.CheckClauses(r.Context(), id, tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, check)
+}
+
+// Send handles POST /api/v1/invoices/{id}/send
+func (h *Handler) Send(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.getTenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	inv, check, err := h.service.Send(r.Context(), id, tenantID)
+	if err != nil {
+		if err == ErrMissingClauses {
+			api.JSONResponse(w, http.StatusUnprocessableEntity, map[string]interface{}{
+				"error":   "mandatory invoice clauses are missing",
+				"code":    "MISSING_CLAUSES",
+				"clauses": check,
+			})
+			return
+		}
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, h.toResponse(inv, nil))
+}
+
+// ListClauses handles GET /api/v1/invoice-clauses
+func (h *Handler) ListClauses(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	clauses, err := h.service.ListClauses(r.Context(), tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items": clauses,
+	})
+}
+
+// CreateClause handles POST /api/v1/invoice-clauses
+func (h *Handler) CreateClause(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	var input ClauseInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	clause, err := h.service.CreateClause(r.Context(), tenantID, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, clause)
+}
+
+// DeleteClause handles DELETE /api/v1/invoice-clauses/{id}
+func (h *Handler) DeleteClause(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.getTenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteClause(r.Context(), id, tenantID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // Helper methods
 
+func (h *Handler) getTenantAndID(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return tenantID, id, true
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func writePDF(w http.ResponseWriter, pdf []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", "attachment; filename=invoice.pdf")
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdf)
+}
+
 func (h *Handler) getTenantID(r *http.Request) (uuid.UUID, error) {
 	tenantIDStr := api.GetTenantID(r.Context())
 	if tenantIDStr == "" {
@@ -327,7 +659,21 @@ func (h *Handler) handleError(w http.ResponseWriter, err error) {
 		api.BadRequest(w, "invoice must have at least one item")
 	case ErrValidationFailed:
 		api.BadRequest(w, "validation failed")
+	case ErrInvoiceNotSendable:
+		api.BadRequest(w, "invoice must be validated or generated before sending")
+	case ErrInvalidClause:
+		api.BadRequest(w, "clause needs a code, a text and at least one selector")
+	case ErrClauseNotFound:
+		api.NotFound(w, "clause not found")
+	case ErrDuplicateClause:
+		api.Conflict(w, "clause code already exists")
+	case ErrProjectNotFound:
+		api.BadRequest(w, "project not found")
 	default:
+		if customfield.IsInvalid(err) {
+			api.BadRequest(w, err.Error())
+			return
+		}
 		api.InternalError(w)
 	}
 }
@@ -344,18 +690,23 @@ func (h *Handler) toResponse(inv *Invoice, items []*InvoiceItem) *InvoiceRespons
 		BuyerName:          inv.BuyerName,
 		BuyerVAT:           inv.BuyerVAT,
 		BuyerReference:     inv.BuyerReference,
-		TaxExclusiveAmount: float64(inv.TaxExclusiveAmount) / 100,
-		TaxAmount:          float64(inv.TaxAmount) / 100,
-		TaxInclusiveAmount: float64(inv.TaxInclusiveAmount) / 100,
-		PayableAmount:      float64(inv.PayableAmount) / 100,
+		ProjectID:          inv.ProjectID,
+		Branch:             inv.Branch,
+		TaxCode:            inv.TaxCode,
+		AppliedClauses:     inv.AppliedClauses,
+		TaxExclusiveAmount: money.New(inv.TaxExclusiveAmount, inv.Currency),
+		TaxAmount:          money.New(inv.TaxAmount, inv.Currency),
+		TaxInclusiveAmount: money.New(inv.TaxInclusiveAmount, inv.Currency),
+		PayableAmount:      money.New(inv.PayableAmount, inv.Currency),
 		Status:             inv.Status,
 		ValidationStatus:   inv.ValidationStatus,
 		ValidationErrors:   inv.ValidationErrors,
+		CustomFields:       inv.CustomFields,
 		HasXRechnung:       len(inv.XRechnungXML) > 0,
 		HasZUGFeRD:         len(inv.ZUGFeRDXML) > 0,
 		HasPDF:             len(inv.PDFContent) > 0,
-		CreatedAt:          inv.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:          inv.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		CreatedAt:          inv.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:          inv.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 
 	if inv.DueDate != nil {
@@ -372,8 +723,8 @@ func (h *Handler) toResponse(inv *Invoice, items []*InvoiceItem) *InvoiceRespons
 				Description: item.Description,
 				Quantity:    item.Quantity,
 				UnitCode:    item.UnitCode,
-				UnitPrice:   float64(item.UnitPrice) / 100,
-				LineTotal:   float64(item.LineTotal) / 100,
+				UnitPrice:   money.New(item.UnitPrice, inv.Currency),
+				LineTotal:   money.New(item.LineTotal, inv.Currency),
 				TaxCategory: item.TaxCategory,
 				TaxPercent:  item.TaxPercent,
 			})
@@ -0,0 +1,385 @@
+package invoice
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// pdfLine is a single rendered text line of the invoice PDF
+type pdfLine struct {
+	size int
+	gap  int // Vertical distance to the previous line
+	text string
+}
+
+const (
+	pdfTop          = 800
+	pdfBottom       = 60
+	pdfClauseWidth  = 95 // Characters per line for clause texts at 9pt
+	pdfDescMaxChars = 48
+)
+
+// PDFBrand is a tenant's look of its invoice PDFs
+type PDFBrand struct {
+	Color      string // #RRGGBB of the rule at the top of each page
+	Logo       []byte // baseline JPEG printed in the top right corner of the first page
+	LogoWidth  int    // pixels
+	LogoHeight int
+}
+
+const (
+	pdfLogoMaxWidth  = 140
+	pdfLogoMaxHeight = 40
+)
+
+// GeneratePDF renders a simple invoice PDF. Required clauses are printed below the
+// totals so that the document carries every mandatory sentence.
+// This is a text-based PDF implementation like the Förderung export.
+func GeneratePDF(inv *Invoice, items []*InvoiceItem, clauses []*Clause) ([]byte, error) {
+	return GenerateBrandedPDF(inv, items, clauses, nil)
+}
+
+// GenerateBrandedPDF renders the invoice PDF in a tenant's brand. A nil
+// brand renders the plain layout of GeneratePDF.
+func GenerateBrandedPDF(inv *Invoice, items []*InvoiceItem, clauses []*Clause, brand *PDFBrand) ([]byte, error) {
+	return renderPDF(inv, items, clauses, brand, nil)
+}
+
+// pdfArchive turns the rendered invoice into an archival (PDF/A) file: it
+// replaces the font with an embedded one and adds objects and catalog
+// entries, numbered from the base it is given
+type pdfArchive interface {
+	header() string
+	font(base int) string
+	catalog(base int) string
+	objects(base int) []string
+	trailer() string
+}
+
+func renderPDF(inv *Invoice, items []*InvoiceItem, clauses []*Clause, brand *PDFBrand, archive pdfArchive) ([]byte, error) {
+	lines := invoicePDFLines(inv, items, clauses)
+
+	// Paginate
+	var pages [][]pdfLine
+	var page []pdfLine
+	y := pdfTop
+	for _, l := range lines {
+		if y-l.gap < pdfBottom && len(page) > 0 {
+			pages = append(pages, page)
+			page = nil
+			y = pdfTop
+			l.gap = 0
+		}
+		y -= l.gap
+		page = append(page, l)
+	}
+	pages = append(pages, page)
+
+	header := "%PDF-1.4\n"
+	font := "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"
+	catalog := ""
+
+	// Object layout: 1 catalog, 2 pages, 3 font, then page/content pairs
+	objects := []string{"", "", ""} // Catalog, pages and font, filled below
+
+	// The logo image follows the page/content pairs, the archive objects
+	// come last
+	logoNum := 0
+	archiveBase := 4 + len(pages)*2
+	if brand != nil && len(brand.Logo) > 0 && brand.LogoWidth > 0 && brand.LogoHeight > 0 {
+		logoNum = archiveBase
+		archiveBase++
+	}
+	if archive != nil {
+		header = archive.header()
+		font = archive.font(archiveBase)
+		catalog = archive.catalog(archiveBase)
+	}
+
+	kids := make([]string, 0, len(pages))
+	for i, p := range pages {
+		pageNum := 4 + i*2
+		contentNum := pageNum + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageNum))
+
+		resources := "/Font << /F1 3 0 R >>"
+		content := pdfBrandContent(brand, i == 0 && logoNum > 0) + pdfPageContent(p, i+1, len(pages))
+		if i == 0 && logoNum > 0 {
+			resources += fmt.Sprintf(" /XObject << /Logo %d 0 R >>", logoNum)
+		}
+		objects = append(objects,
+			fmt.Sprintf("%d 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Contents %d 0 R /Resources << %s >> >>\nendobj\n", pageNum, contentNum, resources),
+			fmt.Sprintf("%d 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n", contentNum, len(content), content),
+		)
+	}
+	if logoNum > 0 {
+		objects = append(objects, fmt.Sprintf("%d 0 obj\n<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n%s\nendstream\nendobj\n",
+			logoNum, brand.LogoWidth, brand.LogoHeight, len(brand.Logo), brand.Logo))
+	}
+	if archive != nil {
+		objects = append(objects, archive.objects(archiveBase)...)
+	}
+	objects[0] = fmt.Sprintf("1 0 obj\n<< /Type /Catalog /Pages 2 0 R%s >>\nendobj\n", catalog)
+	objects[1] = fmt.Sprintf("2 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(pages))
+	objects[2] = fmt.Sprintf("3 0 obj\n%s\nendobj\n", font)
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	offsets := make([]int, 0, len(objects))
+	for _, obj := range objects {
+		offsets = append(offsets, buf.Len())
+		buf.WriteString(obj)
+	}
+
+	xrefOffset := buf.Len()
+	buf.WriteString("xref\n")
+	buf.WriteString(fmt.Sprintf("0 %d\n", len(objects)+1))
+	buf.WriteString("0000000000 65535 f \n")
+	for _, offset := range offsets {
+		buf.WriteString(fmt.Sprintf("%010d 00000 n \n", offset))
+	}
+
+	buf.WriteString("trailer\n")
+	trailer := ""
+	if archive != nil {
+		trailer = archive.trailer()
+	}
+	buf.WriteString(fmt.Sprintf("<< /Size %d /Root 1 0 R%s >>\n", len(objects)+1, trailer))
+	buf.WriteString("startxref\n")
+	buf.WriteString(fmt.Sprintf("%d\n", xrefOffset))
+	buf.WriteString("%%EOF\n")
+
+	return buf.Bytes(), nil
+}
+
+func invoicePDFLines(inv *Invoice, items []*InvoiceItem, clauses []*Clause) []pdfLine {
+	title := "Rechnung"
+	if inv.InvoiceType == "381" {
+		title = "Gutschrift"
+	}
+
+	lines := []pdfLine{
+		{size: 18, text: fmt.Sprintf("%s %s", title, inv.InvoiceNumber)},
+		{size: 10, gap: 28, text: "Rechnungsdatum: " + inv.IssueDate.Format("02.01.2006")},
+	}
+	if inv.DueDate != nil {
+		lines = append(lines, pdfLine{size: 10, gap: 14, text: "Fällig am: " + inv.DueDate.Format("02.01.2006")})
+	}
+
+	lines = append(lines, pdfLine{size: 11, gap: 24, text: "Leistender: " + inv.SellerName})
+	lines = append(lines, addressLines(inv.SellerAddress)...)
+	if inv.SellerVAT != nil {
+		lines = append(lines, pdfLine{size: 10, gap: 13, text: "UID: " + *inv.SellerVAT})
+	}
+
+	lines = append(lines, pdfLine{size: 11, gap: 20, text: "Empfänger: " + inv.BuyerName})
+	lines = append(lines, addressLines(inv.BuyerAddress)...)
+	if inv.BuyerVAT != nil {
+		lines = append(lines, pdfLine{size: 10, gap: 13, text: "UID: " + *inv.BuyerVAT})
+	}
+	if inv.BuyerReference != nil {
+		lines = append(lines, pdfLine{size: 10, gap: 13, text: "Ihre Referenz: " + *inv.BuyerReference})
+	}
+	if inv.OrderReference != nil {
+		lines = append(lines, pdfLine{size: 10, gap: 13, text: "Auftrag: " + *inv.OrderReference})
+	}
+
+	lines = append(lines, pdfLine{size: 12, gap: 28, text: "Pos  Beschreibung                                        Menge      Preis      USt       Betrag"})
+	for _, item := range items {
+		desc := item.Description
+		if len([]rune(desc)) > pdfDescMaxChars {
+			desc = string([]rune(desc)[:pdfDescMaxChars-3]) + "..."
+		}
+		lines = append(lines, pdfLine{size: 9, gap: 14, text: fmt.Sprintf("%-4d %-48s %8.2f %10s %5.0f%% %12s",
+			item.LineNumber, desc, item.Quantity, formatAmount(item.UnitPrice), item.TaxPercent, formatAmount(item.LineTotal))})
+	}
+
+	lines = append(lines,
+		pdfLine{size: 10, gap: 24, text: "Summe netto: " + formatAmount(inv.TaxExclusiveAmount) + " " + inv.Currency},
+		pdfLine{size: 10, gap: 14, text: "Umsatzsteuer: " + formatAmount(inv.TaxAmount) + " " + inv.Currency},
+		pdfLine{size: 12, gap: 16, text: "Gesamtbetrag: " + formatAmount(inv.PayableAmount) + " " + inv.Currency},
+	)
+
+	if inv.PaymentTerms != nil {
+		lines = append(lines, pdfLine{size: 10, gap: 22, text: "Zahlungsbedingungen: " + *inv.PaymentTerms})
+	}
+	if inv.PaymentIBAN != nil {
+		bank := "IBAN: " + *inv.PaymentIBAN
+		if inv.PaymentBIC != nil {
+			bank += "  BIC: " + *inv.PaymentBIC
+		}
+		lines = append(lines, pdfLine{size: 10, gap: 14, text: bank})
+	}
+
+	if len(clauses) > 0 {
+		lines = append(lines, pdfLine{size: 11, gap: 24, text: "Hinweise"})
+		for _, c := range clauses {
+			text := c.Text
+			if c.LegalBasis != "" && !strings.Contains(text, c.LegalBasis) {
+				text += " (" + c.LegalBasis + ")"
+			}
+			for i, wrapped := range wrapText(text, pdfClauseWidth) {
+				gap := 12
+				if i == 0 {
+					gap = 15
+				}
+				lines = append(lines, pdfLine{size: 9, gap: gap, text: wrapped})
+			}
+		}
+	}
+
+	if inv.Notes != nil && *inv.Notes != "" {
+		lines = append(lines, pdfLine{size: 11, gap: 22, text: "Anmerkungen"})
+		for _, paragraph := range strings.Split(*inv.Notes, "\n") {
+			for _, wrapped := range wrapText(paragraph, pdfClauseWidth) {
+				lines = append(lines, pdfLine{size: 9, gap: 12, text: wrapped})
+			}
+		}
+	}
+
+	return lines
+}
+
+// pdfBrandContent draws the brand color rule at the top of a page and, on
+// the first page, the logo scaled into the top right corner
+func pdfBrandContent(brand *PDFBrand, logo bool) string {
+	if brand == nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	if r, g, b, ok := parseHexColor(brand.Color); ok {
+		buf.WriteString(fmt.Sprintf("q %.3f %.3f %.3f RG 3 w 50 825 m 545 825 l S Q\n", r, g, b))
+	}
+	if logo {
+		scale := math.Min(float64(pdfLogoMaxWidth)/float64(brand.LogoWidth), float64(pdfLogoMaxHeight)/float64(brand.LogoHeight))
+		w := float64(brand.LogoWidth) * scale
+		h := float64(brand.LogoHeight) * scale
+		buf.WriteString(fmt.Sprintf("q %.2f 0 0 %.2f %.2f %.2f cm /Logo Do Q\n", w, h, 545-w, 815-h))
+	}
+	return buf.String()
+}
+
+// parseHexColor parses #RRGGBB into PDF color components
+func parseHexColor(color string) (r, g, b float64, ok bool) {
+	var rgb [3]uint8
+	if len(color) != 7 || color[0] != '#' {
+		return 0, 0, 0, false
+	}
+	if _, err := fmt.Sscanf(color[1:], "%02x%02x%02x", &rgb[0], &rgb[1], &rgb[2]); err != nil {
+		return 0, 0, 0, false
+	}
+	return float64(rgb[0]) / 255, float64(rgb[1]) / 255, float64(rgb[2]) / 255, true
+}
+
+func pdfPageContent(lines []pdfLine, page, total int) string {
+	var buf bytes.Buffer
+	buf.WriteString("BT\n")
+	buf.WriteString(fmt.Sprintf("50 %d Td\n", pdfTop))
+
+	for i, l := range lines {
+		buf.WriteString(fmt.Sprintf("/F1 %d Tf\n", l.size))
+		if i > 0 {
+			buf.WriteString(fmt.Sprintf("0 -%d Td\n", l.gap))
+		}
+		buf.WriteString(fmt.Sprintf("(%s) Tj\n", escapePDFText(l.text)))
+	}
+	buf.WriteString("ET\n")
+
+	if total > 1 {
+		buf.WriteString("BT\n/F1 8 Tf\n")
+		buf.WriteString(fmt.Sprintf("500 30 Td\n(Seite %d/%d) Tj\nET\n", page, total))
+	}
+
+	return buf.String()
+}
+
+func addressLines(raw json.RawMessage) []pdfLine {
+	if len(raw) == 0 {
+		return nil
+	}
+	var addr Address
+	if json.Unmarshal(raw, &addr) != nil {
+		return nil
+	}
+
+	var lines []pdfLine
+	if addr.Street != "" {
+		lines = append(lines, pdfLine{size: 10, gap: 13, text: addr.Street})
+	}
+	if addr.AdditionalLine != "" {
+		lines = append(lines, pdfLine{size: 10, gap: 13, text: addr.AdditionalLine})
+	}
+	city := strings.TrimSpace(addr.PostalCode + " " + addr.City)
+	if addr.Country != "" {
+		city = strings.TrimSpace(addr.Country + "-" + city)
+	}
+	if city != "" {
+		lines = append(lines, pdfLine{size: 10, gap: 13, text: city})
+	}
+	return lines
+}
+
+// wrapText wraps text at word boundaries to the given number of characters
+func wrapText(text string, width int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var lines []string
+	current := words[0]
+	for _, w := range words[1:] {
+		if len([]rune(current))+1+len([]rune(w)) > width {
+			lines = append(lines, current)
+			current = w
+			continue
+		}
+		current += " " + w
+	}
+	return append(lines, current)
+}
+
+// escapePDFText escapes a string for a PDF literal in WinAnsiEncoding.
+// Latin-1 characters (umlauts, ß, §) are written as octal escapes.
+func escapePDFText(s string) string {
+	var buf strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r == '€':
+			buf.WriteString("\\200")
+		case r == '–':
+			buf.WriteString("\\226")
+		case r < 0x80:
+			buf.WriteRune(r)
+		case r <= 0xFF:
+			buf.WriteString(fmt.Sprintf("\\%03o", r))
+		default:
+			buf.WriteByte('?')
+		}
+	}
+	return buf.String()
+}
+
+// formatAmount formats cents in Austrian notation (1.234,56)
+func formatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	euros := fmt.Sprintf("%d", cents/100)
+	var grouped []string
+	for len(euros) > 3 {
+		grouped = append([]string{euros[len(euros)-3:]}, grouped...)
+		euros = euros[:len(euros)-3]
+	}
+	grouped = append([]string{euros}, grouped...)
+	return fmt.Sprintf("%s%s,%02d", sign, strings.Join(grouped, "."), cents%100)
+}
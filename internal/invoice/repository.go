@@ -5,8 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"austrian-business-infrastructure/internal/customfield"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -15,6 +17,9 @@ import (
 var (
 	ErrInvoiceNotFound = errors.New("invoice not found")
 	ErrDuplicateNumber = errors.New("invoice number already exists")
+	ErrClauseNotFound  = errors.New("clause not found")
+	ErrDuplicateClause = errors.New("clause code already exists")
+	ErrProjectNotFound = errors.New("project not found")
 )
 
 // Repository handles invoice database operations
@@ -48,8 +53,9 @@ func (r *Repository) Create(ctx context.Context, inv *Invoice, items []*InvoiceI
 			buyer_id, buyer_name, buyer_vat, buyer_address, buyer_reference,
 			order_reference, tax_exclusive_amount, tax_amount, tax_inclusive_amount,
 			payable_amount, payment_terms, payment_iban, payment_bic, notes,
-			status, validation_status, created_by, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30)
+			status, validation_status, created_by, created_at, updated_at, project_id,
+			branch, tax_code, custom_fields
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34)
 		RETURNING id`
 
 	err = tx.QueryRow(ctx, query,
@@ -58,7 +64,8 @@ func (r *Repository) Create(ctx context.Context, inv *Invoice, items []*InvoiceI
 		inv.BuyerID, inv.BuyerName, inv.BuyerVAT, inv.BuyerAddress, inv.BuyerReference,
 		inv.OrderReference, inv.TaxExclusiveAmount, inv.TaxAmount, inv.TaxInclusiveAmount,
 		inv.PayableAmount, inv.PaymentTerms, inv.PaymentIBAN, inv.PaymentBIC, inv.Notes,
-		inv.Status, inv.ValidationStatus, inv.CreatedBy, inv.CreatedAt, inv.UpdatedAt,
+		inv.Status, inv.ValidationStatus, inv.CreatedBy, inv.CreatedAt, inv.UpdatedAt, inv.ProjectID,
+		inv.Branch, inv.TaxCode, inv.CustomFields.Param(),
 	).Scan(&inv.ID)
 
 	if err != nil {
@@ -106,14 +113,16 @@ func (r *Repository) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*Invo
 			xrechnung_xml IS NOT NULL as has_xrechnung,
 			zugferd_xml IS NOT NULL as has_zugferd,
 			pdf_content IS NOT NULL as has_pdf,
-			created_by, created_at, updated_at
+			created_by, created_at, updated_at, project_id,
+			branch, tax_code, applied_clauses, custom_fields
 		FROM invoices
 		WHERE id = $1 AND tenant_id = $2`
 
 	var inv Invoice
 	var dueDate sql.NullTime
-	var sellerID, buyerID, createdBy uuid.NullUUID
+	var sellerID, buyerID, createdBy, projectID uuid.NullUUID
 	var sellerVAT, buyerVAT, buyerRef, orderRef, paymentTerms, paymentIBAN, paymentBIC, notes sql.NullString
+	var branch, taxCode sql.NullString
 	var hasXRechnung, hasZUGFeRD, hasPDF bool
 
 	err := r.db.QueryRow(ctx, query, id, tenantID).Scan(
@@ -124,7 +133,8 @@ func (r *Repository) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*Invo
 		&inv.PayableAmount, &paymentTerms, &paymentIBAN, &paymentBIC, &notes,
 		&inv.Status, &inv.ValidationStatus, &inv.ValidationErrors,
 		&hasXRechnung, &hasZUGFeRD, &hasPDF,
-		&createdBy, &inv.CreatedAt, &inv.UpdatedAt,
+		&createdBy, &inv.CreatedAt, &inv.UpdatedAt, &projectID,
+		&branch, &taxCode, &inv.AppliedClauses, &inv.CustomFields,
 	)
 
 	if err != nil {
@@ -170,10 +180,31 @@ func (r *Repository) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*Invo
 	if createdBy.Valid {
 		inv.CreatedBy = &createdBy.UUID
 	}
+	if projectID.Valid {
+		inv.ProjectID = &projectID.UUID
+	}
+	if branch.Valid {
+		inv.Branch = &branch.String
+	}
+	if taxCode.Valid {
+		inv.TaxCode = &taxCode.String
+	}
 
 	return &inv, nil
 }
 
+// NumberExists reports whether the tenant already has an invoice with the number
+func (r *Repository) NumberExists(ctx context.Context, tenantID uuid.UUID, number string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM invoices WHERE tenant_id = $1 AND invoice_number = $2)`,
+		tenantID, number).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check invoice number: %w", err)
+	}
+	return exists, nil
+}
+
 // GetItems retrieves all items for an invoice
 func (r *Repository) GetItems(ctx context.Context, invoiceID uuid.UUID) ([]*InvoiceItem, error) {
 	query := `
@@ -233,6 +264,12 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Invoice, i
 		argIdx++
 	}
 
+	if filter.ProjectID != nil {
+		baseQuery += fmt.Sprintf(" AND project_id = $%d", argIdx)
+		args = append(args, *filter.ProjectID)
+		argIdx++
+	}
+
 	if filter.DateFrom != nil {
 		baseQuery += fmt.Sprintf(" AND issue_date >= $%d", argIdx)
 		args = append(args, *filter.DateFrom)
@@ -251,6 +288,12 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Invoice, i
 		argIdx++
 	}
 
+	if doc := customfield.FilterDocument(filter.CustomFields); doc != nil {
+		baseQuery += fmt.Sprintf(" AND custom_fields @> $%d", argIdx)
+		args = append(args, doc)
+		argIdx++
+	}
+
 	// Count total
 	var total int
 	countQuery := "SELECT COUNT(*)" + baseQuery
@@ -264,7 +307,8 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Invoice, i
 		SELECT id, tenant_id, invoice_number, invoice_type, issue_date, due_date,
 			currency, seller_name, seller_vat, buyer_name, buyer_vat,
 			tax_exclusive_amount, tax_amount, tax_inclusive_amount, payable_amount,
-			status, validation_status, created_at, updated_at
+			status, validation_status, created_at, updated_at, project_id,
+			buyer_reference, branch, custom_fields
 		` + baseQuery + `
 		ORDER BY issue_date DESC, created_at DESC
 		LIMIT $` + fmt.Sprintf("%d", argIdx) + ` OFFSET $` + fmt.Sprintf("%d", argIdx+1)
@@ -281,13 +325,15 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Invoice, i
 	for rows.Next() {
 		var inv Invoice
 		var dueDate sql.NullTime
-		var sellerVAT, buyerVAT sql.NullString
+		var sellerVAT, buyerVAT, buyerRef, branch sql.NullString
+		var projectID uuid.NullUUID
 
 		err := rows.Scan(
 			&inv.ID, &inv.TenantID, &inv.InvoiceNumber, &inv.InvoiceType, &inv.IssueDate, &dueDate,
 			&inv.Currency, &inv.SellerName, &sellerVAT, &inv.BuyerName, &buyerVAT,
 			&inv.TaxExclusiveAmount, &inv.TaxAmount, &inv.TaxInclusiveAmount, &inv.PayableAmount,
-			&inv.Status, &inv.ValidationStatus, &inv.CreatedAt, &inv.UpdatedAt,
+			&inv.Status, &inv.ValidationStatus, &inv.CreatedAt, &inv.UpdatedAt, &projectID,
+			&buyerRef, &branch, &inv.CustomFields,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan invoice: %w", err)
@@ -302,6 +348,15 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Invoice, i
 		if buyerVAT.Valid {
 			inv.BuyerVAT = &buyerVAT.String
 		}
+		if projectID.Valid {
+			inv.ProjectID = &projectID.UUID
+		}
+		if buyerRef.Valid {
+			inv.BuyerReference = &buyerRef.String
+		}
+		if branch.Valid {
+			inv.Branch = &branch.String
+		}
 
 		invoices = append(invoices, &inv)
 	}
@@ -336,6 +391,24 @@ func (r *Repository) Update(ctx context.Context, inv *Invoice) error {
 	return nil
 }
 
+// UpdateCustomFields replaces the custom field values of an invoice
+func (r *Repository) UpdateCustomFields(ctx context.Context, id, tenantID uuid.UUID, values customfield.Values) error {
+	result, err := r.db.Exec(ctx, `
+		UPDATE invoices SET custom_fields = $1, updated_at = NOW()
+		WHERE id = $2 AND tenant_id = $3`,
+		values.Param(), id, tenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update invoice custom fields: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrInvoiceNotFound
+	}
+
+	return nil
+}
+
 // SaveXML saves generated XML content
 func (r *Repository) SaveXML(ctx context.Context, id, tenantID uuid.UUID, format string, xmlContent []byte) error {
 	var query string
@@ -370,6 +443,105 @@ func (r *Repository) GetXML(ctx context.Context, id, tenantID uuid.UUID, format
 	return content, nil
 }
 
+// SavePDF saves a generated PDF together with the clause codes it contains
+func (r *Repository) SavePDF(ctx context.Context, id, tenantID uuid.UUID, pdf []byte, appliedClauses []string) error {
+	query := `UPDATE invoices SET pdf_content = $1, applied_clauses = $2, updated_at = $3 WHERE id = $4 AND tenant_id = $5`
+	_, err := r.db.Exec(ctx, query, pdf, appliedClauses, time.Now(), id, tenantID)
+	return err
+}
+
+// GetPDF retrieves the generated PDF
+func (r *Repository) GetPDF(ctx context.Context, id, tenantID uuid.UUID) ([]byte, error) {
+	var content []byte
+	err := r.db.QueryRow(ctx, `SELECT pdf_content FROM invoices WHERE id = $1 AND tenant_id = $2`, id, tenantID).Scan(&content)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+	return content, nil
+}
+
+// SetAppliedClauses records the clause codes contained in the generated documents
+func (r *Repository) SetAppliedClauses(ctx context.Context, id, tenantID uuid.UUID, appliedClauses []string) error {
+	query := `UPDATE invoices SET applied_clauses = $1, updated_at = $2 WHERE id = $3 AND tenant_id = $4`
+	_, err := r.db.Exec(ctx, query, appliedClauses, time.Now(), id, tenantID)
+	return err
+}
+
+// ListClauses lists the tenant's own invoice clauses
+func (r *Repository) ListClauses(ctx context.Context, tenantID uuid.UUID) ([]*Clause, error) {
+	query := `
+		SELECT id, tenant_id, code, clause_group, branch, tax_code, tax_category,
+			text, legal_basis, mandatory, created_at
+		FROM invoice_clauses
+		WHERE tenant_id = $1
+		ORDER BY code`
+
+	rows, err := r.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clauses: %w", err)
+	}
+	defer rows.Close()
+
+	var clauses []*Clause
+	for rows.Next() {
+		var c Clause
+		var id, tID uuid.UUID
+		var createdAt time.Time
+		if err := rows.Scan(&id, &tID, &c.Code, &c.Group, &c.Branch, &c.TaxCode, &c.TaxCategory,
+			&c.Text, &c.LegalBasis, &c.Mandatory, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan clause: %w", err)
+		}
+		c.ID = &id
+		c.TenantID = &tID
+		c.CreatedAt = &createdAt
+		clauses = append(clauses, &c)
+	}
+
+	return clauses, rows.Err()
+}
+
+// CreateClause creates a tenant clause
+func (r *Repository) CreateClause(ctx context.Context, c *Clause) (*Clause, error) {
+	id := uuid.New()
+	createdAt := time.Now()
+
+	query := `
+		INSERT INTO invoice_clauses (
+			id, tenant_id, code, clause_group, branch, tax_code, tax_category,
+			text, legal_basis, mandatory, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := r.db.Exec(ctx, query,
+		id, c.TenantID, c.Code, c.Group, c.Branch, c.TaxCode, c.TaxCategory,
+		c.Text, c.LegalBasis, c.Mandatory, createdAt,
+	)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, ErrDuplicateClause
+		}
+		return nil, fmt.Errorf("failed to create clause: %w", err)
+	}
+
+	c.ID = &id
+	c.CreatedAt = &createdAt
+	return c, nil
+}
+
+// DeleteClause deletes a tenant clause
+func (r *Repository) DeleteClause(ctx context.Context, id, tenantID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM invoice_clauses WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrClauseNotFound
+	}
+	return nil
+}
+
 // Delete deletes an invoice (only drafts)
 func (r *Repository) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
 	query := `DELETE FROM invoices WHERE id = $1 AND tenant_id = $2 AND status = 'draft'`
@@ -382,3 +554,22 @@ func (r *Repository) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
 	}
 	return nil
 }
+
+// isDuplicateKeyError checks if error is a unique constraint violation
+func isDuplicateKeyError(err error) bool {
+	errStr := err.Error()
+	return strings.Contains(errStr, "23505") || strings.Contains(errStr, "unique constraint")
+}
+
+// ProjectExists reports whether a project belongs to the tenant
+func (r *Repository) ProjectExists(ctx context.Context, projectID, tenantID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND tenant_id = $2)`,
+		projectID, tenantID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check project: %w", err)
+	}
+	return exists, nil
+}
@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"time"
 
+	"austrian-business-infrastructure/internal/analytics"
+	"austrian-business-infrastructure/internal/customfield"
 	"austrian-business-infrastructure/internal/erechnung"
+	"austrian-business-infrastructure/pkg/money"
 	"github.com/google/uuid"
 )
 
@@ -16,11 +19,35 @@ var (
 	ErrInvoiceNotDraft    = errors.New("invoice is not in draft status")
 	ErrNoItems            = errors.New("invoice must have at least one item")
 	ErrValidationFailed   = errors.New("validation failed")
+	ErrMissingClauses     = errors.New("mandatory invoice clauses are missing")
+	ErrInvoiceNotSendable = errors.New("invoice must be validated or generated before sending")
+	ErrInvalidClause      = errors.New("clause needs a code, a text and at least one selector")
+	ErrInvalidIssueDate   = errors.New("invalid issue_date format")
+	ErrInvalidDueDate     = errors.New("invalid due_date format")
 )
 
+// MaxExportRows caps the number of invoices in one CSV export
+const MaxExportRows = 10000
+
+// BrandProvider returns the brand of a tenant's invoice PDFs, nil if the
+// tenant has none
+type BrandProvider interface {
+	InvoiceBrand(ctx context.Context, tenantID uuid.UUID) (*PDFBrand, error)
+}
+
+// PartnerUIDs reports UID numbers of business partners found invalid on
+// re-validation; partner.Service implements it
+type PartnerUIDs interface {
+	UIDInvalid(ctx context.Context, tenantID uuid.UUID, uid string) (bool, error)
+}
+
 // Service handles invoice business logic
 type Service struct {
-	repo *Repository
+	repo         *Repository
+	customFields *customfield.Service
+	brands       BrandProvider
+	analytics    *analytics.Emitter
+	partnerUIDs  PartnerUIDs
 }
 
 // NewService creates a new invoice service
@@ -28,24 +55,81 @@ func NewService(repo *Repository) *Service {
 	return &Service{repo: repo}
 }
 
+// SetCustomFields enables the tenant's custom fields on invoices
+func (s *Service) SetCustomFields(cf *customfield.Service) {
+	s.customFields = cf
+}
+
+// SetBrandProvider renders invoice PDFs in the tenant's brand
+func (s *Service) SetBrandProvider(brands BrandProvider) {
+	s.brands = brands
+}
+
+// SetAnalytics reports created invoices to the product analytics
+func (s *Service) SetAnalytics(emitter *analytics.Emitter) {
+	s.analytics = emitter
+}
+
+// SetPartnerUIDs blocks reverse charge and intra-community invoices to
+// buyer UIDs found invalid
+func (s *Service) SetPartnerUIDs(uids PartnerUIDs) {
+	s.partnerUIDs = uids
+}
+
 // Create creates a new invoice
 func (s *Service) Create(ctx context.Context, tenantID, userID uuid.UUID, input *CreateInvoiceInput) (*Invoice, error) {
+	inv, items, err := s.build(ctx, tenantID, input)
+	if err != nil {
+		return nil, err
+	}
+	inv.CreatedBy = &userID
+
+	created, err := s.repo.Create(ctx, inv, items)
+	if err != nil {
+		return nil, err
+	}
+	s.analytics.Track(ctx, tenantID, userID, analytics.EventInvoiceCreated, analytics.Properties{
+		"invoice_type": created.InvoiceType,
+		"items":        len(items),
+	})
+	return created, nil
+}
+
+// build turns create input into an invoice and its items, computing the
+// totals, without storing anything
+func (s *Service) build(ctx context.Context, tenantID uuid.UUID, input *CreateInvoiceInput) (*Invoice, []*InvoiceItem, error) {
 	// Validate items
 	if len(input.Items) == 0 {
-		return nil, ErrNoItems
+		return nil, nil, ErrNoItems
+	}
+
+	customFields, err := s.customFields.Validate(ctx, tenantID, customfield.EntityInvoice, input.CustomFields, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Only the tenant's own projects can be referenced
+	if input.ProjectID != nil {
+		exists, err := s.repo.ProjectExists(ctx, *input.ProjectID, tenantID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !exists {
+			return nil, nil, ErrProjectNotFound
+		}
 	}
 
 	// Parse dates
 	issueDate, err := time.Parse("2006-01-02", input.IssueDate)
 	if err != nil {
-		return nil, fmt.Errorf("invalid issue_date format: %w", err)
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidIssueDate, err)
 	}
 
 	var dueDate *time.Time
 	if input.DueDate != nil && *input.DueDate != "" {
 		d, err := time.Parse("2006-01-02", *input.DueDate)
 		if err != nil {
-			return nil, fmt.Errorf("invalid due_date format: %w", err)
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidDueDate, err)
 		}
 		dueDate = &d
 	}
@@ -55,11 +139,11 @@ func (s *Service) Create(ctx context.Context, tenantID, userID uuid.UUID, input
 	items := make([]*InvoiceItem, 0, len(input.Items))
 
 	for i, itemInput := range input.Items {
-		lineTotal := int64(float64(itemInput.UnitPrice) * itemInput.Quantity)
-		lineTax := int64(float64(lineTotal) * itemInput.TaxPercent / 100)
+		lineTotal := money.New(itemInput.UnitPrice, input.Currency).Mul(itemInput.Quantity)
+		lineTax := lineTotal.Percent(itemInput.TaxPercent)
 
-		taxExclusive += lineTotal
-		taxAmount += lineTax
+		taxExclusive += lineTotal.Cents
+		taxAmount += lineTax.Cents
 
 		item := &InvoiceItem{
 			LineNumber:  i + 1,
@@ -67,7 +151,7 @@ func (s *Service) Create(ctx context.Context, tenantID, userID uuid.UUID, input
 			Quantity:    itemInput.Quantity,
 			UnitCode:    itemInput.UnitCode,
 			UnitPrice:   itemInput.UnitPrice,
-			LineTotal:   lineTotal,
+			LineTotal:   lineTotal.Cents,
 			TaxCategory: itemInput.TaxCategory,
 			TaxPercent:  itemInput.TaxPercent,
 			ItemID:      itemInput.ItemID,
@@ -102,6 +186,9 @@ func (s *Service) Create(ctx context.Context, tenantID, userID uuid.UUID, input
 		BuyerAddress:       buyerAddr,
 		BuyerReference:     input.BuyerReference,
 		OrderReference:     input.OrderReference,
+		ProjectID:          input.ProjectID,
+		Branch:             input.Branch,
+		TaxCode:            input.TaxCode,
 		TaxExclusiveAmount: taxExclusive,
 		TaxAmount:          taxAmount,
 		TaxInclusiveAmount: taxExclusive + taxAmount,
@@ -110,7 +197,7 @@ func (s *Service) Create(ctx context.Context, tenantID, userID uuid.UUID, input
 		PaymentIBAN:        input.PaymentIBAN,
 		PaymentBIC:         input.PaymentBIC,
 		Notes:              input.Notes,
-		CreatedBy:          &userID,
+		CustomFields:       customFields,
 	}
 
 	if inv.Currency == "" {
@@ -120,7 +207,7 @@ func (s *Service) Create(ctx context.Context, tenantID, userID uuid.UUID, input
 		inv.InvoiceType = string(erechnung.InvoiceTypeCommercial)
 	}
 
-	return s.repo.Create(ctx, inv, items)
+	return inv, items, nil
 }
 
 // Get retrieves an invoice by ID
@@ -151,6 +238,45 @@ func (s *Service) List(ctx context.Context, filter ListFilter) ([]*Invoice, int,
 	return s.repo.List(ctx, filter)
 }
 
+// ListForExport lists up to MaxExportRows invoices matching the filter,
+// ignoring its paging
+func (s *Service) ListForExport(ctx context.Context, filter ListFilter) ([]*Invoice, error) {
+	filter.Limit = MaxExportRows
+	filter.Offset = 0
+	invoices, _, err := s.repo.List(ctx, filter)
+	return invoices, err
+}
+
+// CustomFieldDefinitions returns the tenant's custom fields on invoices
+func (s *Service) CustomFieldDefinitions(ctx context.Context, tenantID uuid.UUID) ([]*customfield.Definition, error) {
+	return s.customFields.List(ctx, tenantID, customfield.EntityInvoice)
+}
+
+// CustomFieldFilters turns cf.<key> list parameters into filters
+func (s *Service) CustomFieldFilters(ctx context.Context, tenantID uuid.UUID, params map[string]string) ([]customfield.Filter, error) {
+	return s.customFields.Filters(ctx, tenantID, customfield.EntityInvoice, params)
+}
+
+// UpdateCustomFields merges custom field values into an invoice. Unlike the
+// invoice content they can change in any status.
+func (s *Service) UpdateCustomFields(ctx context.Context, id, tenantID uuid.UUID, input map[string]interface{}) (*Invoice, error) {
+	inv, err := s.repo.GetByID(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := s.customFields.Validate(ctx, tenantID, customfield.EntityInvoice, input, inv.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateCustomFields(ctx, id, tenantID, values); err != nil {
+		return nil, err
+	}
+	inv.CustomFields = values
+	return inv, nil
+}
+
 // Delete deletes an invoice (only drafts)
 func (s *Service) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
 	inv, err := s.repo.GetByID(ctx, id, tenantID)
@@ -177,6 +303,7 @@ func (s *Service) Validate(ctx context.Context, id, tenantID uuid.UUID) (*Invoic
 
 	// Validate
 	validationResult := erechnung.ValidateInvoice(ereInv)
+	CheckVATRates(validationResult, ereInv, inv.TaxCode)
 
 	if !validationResult.Valid {
 		errJSON, _ := json.Marshal(validationResult.Errors)
@@ -202,8 +329,14 @@ func (s *Service) GenerateXML(ctx context.Context, id, tenantID uuid.UUID, forma
 		return nil, err
 	}
 
-	// Build erechnung Invoice
+	clauses, err := s.requiredClauses(ctx, inv, items)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build erechnung Invoice with the required clauses as invoice notes
 	ereInv := s.toErechnungInvoice(inv, items)
+	ereInv.Notes = clauseNotes(inv.Notes, clauses)
 
 	// Generate XML
 	var xmlContent []byte
@@ -221,6 +354,9 @@ func (s *Service) GenerateXML(ctx context.Context, id, tenantID uuid.UUID, forma
 	if err := s.repo.SaveXML(ctx, id, tenantID, format, xmlContent); err != nil {
 		return nil, err
 	}
+	if err := s.repo.SetAppliedClauses(ctx, id, tenantID, clauseCodes(clauses)); err != nil {
+		return nil, err
+	}
 
 	// Update status
 	inv.Status = StatusGenerated
@@ -236,8 +372,218 @@ func (s *Service) GetXML(ctx context.Context, id, tenantID uuid.UUID, format str
 	return s.repo.GetXML(ctx, id, tenantID, format)
 }
 
+// GeneratePDF renders the invoice PDF with all required clauses
+func (s *Service) GeneratePDF(ctx context.Context, id, tenantID uuid.UUID) ([]byte, error) {
+	inv, items, err := s.GetWithItems(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	clauses, err := s.requiredClauses(ctx, inv, items)
+	if err != nil {
+		return nil, err
+	}
+
+	var brand *PDFBrand
+	if s.brands != nil {
+		if brand, err = s.brands.InvoiceBrand(ctx, tenantID); err != nil {
+			return nil, fmt.Errorf("failed to load brand: %w", err)
+		}
+	}
+
+	pdf, err := GenerateBrandedPDF(inv, items, clauses, brand)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	if err := s.repo.SavePDF(ctx, id, tenantID, pdf, clauseCodes(clauses)); err != nil {
+		return nil, err
+	}
+
+	if inv.Status == StatusDraft || inv.Status == StatusValidated {
+		inv.Status = StatusGenerated
+		if err := s.repo.Update(ctx, inv); err != nil {
+			return nil, err
+		}
+	}
+
+	return pdf, nil
+}
+
+// FacturXPDF renders the invoice as a Factur-X PDF/A-3 with the ZUGFeRD XML
+// embedded. It is rendered on request and neither stored nor changes the
+// invoice status.
+func (s *Service) FacturXPDF(ctx context.Context, id, tenantID uuid.UUID) ([]byte, error) {
+	inv, items, err := s.GetWithItems(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	clauses, err := s.requiredClauses(ctx, inv, items)
+	if err != nil {
+		return nil, err
+	}
+
+	ereInv := s.toErechnungInvoice(inv, items)
+	ereInv.Notes = clauseNotes(inv.Notes, clauses)
+	xmlContent, err := erechnung.GenerateZUGFeRD(ereInv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s XML: %w", FormatZUGFeRD, err)
+	}
+
+	var brand *PDFBrand
+	if s.brands != nil {
+		if brand, err = s.brands.InvoiceBrand(ctx, tenantID); err != nil {
+			return nil, fmt.Errorf("failed to load brand: %w", err)
+		}
+	}
+
+	pdf, err := GenerateFacturXPDF(inv, items, clauses, brand, xmlContent, inv.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+	return pdf, nil
+}
+
+// GetPDF retrieves the stored PDF
+func (s *Service) GetPDF(ctx context.Context, id, tenantID uuid.UUID) ([]byte, error) {
+	return s.repo.GetPDF(ctx, id, tenantID)
+}
+
+// CheckClauses compares the clauses an invoice requires with those contained in its
+// generated documents, and checks the data the clauses depend on.
+func (s *Service) CheckClauses(ctx context.Context, id, tenantID uuid.UUID) (*ClauseCheck, error) {
+	inv, items, err := s.GetWithItems(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := s.requiredClauses(ctx, inv, items)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &ClauseCheck{
+		Required: required,
+		Applied:  inv.AppliedClauses,
+		Missing:  MissingClauses(required, inv.AppliedClauses),
+	}
+	if check.Required == nil {
+		check.Required = []*Clause{}
+	}
+	if check.Applied == nil {
+		check.Applied = []string{}
+	}
+	if check.Missing == nil {
+		check.Missing = []string{}
+	}
+
+	// Reverse charge and intra-community supplies need both UID numbers (§ 11 Abs. 1a UStG)
+	needsBuyerUID := false
+	for _, c := range required {
+		if c.TaxCategory == "AE" || c.TaxCategory == "K" {
+			if inv.SellerVAT == nil || *inv.SellerVAT == "" {
+				check.Problems = append(check.Problems, "seller UID number is required for "+c.Code)
+			}
+			if inv.BuyerVAT == nil || *inv.BuyerVAT == "" {
+				check.Problems = append(check.Problems, "buyer UID number is required for "+c.Code)
+			} else {
+				needsBuyerUID = true
+			}
+		}
+	}
+	// A buyer UID that re-validation found invalid does not support them
+	if needsBuyerUID && s.partnerUIDs != nil {
+		invalid, err := s.partnerUIDs.UIDInvalid(ctx, tenantID, *inv.BuyerVAT)
+		if err != nil {
+			return nil, err
+		}
+		if invalid {
+			check.Problems = append(check.Problems, "buyer UID number "+*inv.BuyerVAT+" was confirmed invalid")
+		}
+	}
+
+	check.Ready = len(check.Missing) == 0 && len(check.Problems) == 0
+	return check, nil
+}
+
+// Send marks an invoice as sent after verifying that all mandatory clauses are present
+func (s *Service) Send(ctx context.Context, id, tenantID uuid.UUID) (*Invoice, *ClauseCheck, error) {
+	inv, err := s.repo.GetByID(ctx, id, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if inv.Status != StatusValidated && inv.Status != StatusGenerated {
+		return nil, nil, ErrInvoiceNotSendable
+	}
+
+	check, err := s.CheckClauses(ctx, id, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !check.Ready {
+		return nil, check, ErrMissingClauses
+	}
+
+	inv.Status = StatusSent
+	if err := s.repo.Update(ctx, inv); err != nil {
+		return nil, nil, err
+	}
+
+	inv, err = s.repo.GetByID(ctx, id, tenantID)
+	return inv, check, err
+}
+
+// ListClauses returns the built-in clause library followed by the tenant's own clauses
+func (s *Service) ListClauses(ctx context.Context, tenantID uuid.UUID) ([]*Clause, error) {
+	tenantClauses, err := s.repo.ListClauses(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return append(BuiltinClauses(), tenantClauses...), nil
+}
+
+// CreateClause adds a tenant clause. A tenant clause with the code of a built-in
+// clause replaces the built-in text.
+func (s *Service) CreateClause(ctx context.Context, tenantID uuid.UUID, input *ClauseInput) (*Clause, error) {
+	if input.Code == "" || input.Text == "" ||
+		(input.Branch == "" && input.TaxCode == "" && input.TaxCategory == "") {
+		return nil, ErrInvalidClause
+	}
+
+	c := &Clause{
+		TenantID:    &tenantID,
+		Code:        input.Code,
+		Group:       input.Group,
+		Branch:      input.Branch,
+		TaxCode:     input.TaxCode,
+		TaxCategory: input.TaxCategory,
+		Text:        input.Text,
+		LegalBasis:  input.LegalBasis,
+		Mandatory:   true,
+	}
+	if input.Mandatory != nil {
+		c.Mandatory = *input.Mandatory
+	}
+
+	return s.repo.CreateClause(ctx, c)
+}
+
+// DeleteClause deletes a tenant clause
+func (s *Service) DeleteClause(ctx context.Context, id, tenantID uuid.UUID) error {
+	return s.repo.DeleteClause(ctx, id, tenantID)
+}
+
 // Helper methods
 
+func (s *Service) requiredClauses(ctx context.Context, inv *Invoice, items []*InvoiceItem) ([]*Clause, error) {
+	tenantClauses, err := s.repo.ListClauses(ctx, inv.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	return RequiredClauses(inv, items, tenantClauses), nil
+}
+
 func (s *Service) toErechnungInvoice(inv *Invoice, items []*InvoiceItem) *erechnung.Invoice {
 	ereInv := &erechnung.Invoice{
 		ID:          inv.InvoiceNumber,
@@ -0,0 +1,93 @@
+package invoice
+
+import (
+	"fmt"
+	"strconv"
+
+	"austrian-business-infrastructure/internal/erechnung"
+	"austrian-business-infrastructure/internal/refdata"
+)
+
+// VATCountry returns the country whose VAT rates apply to the lines of an
+// invoice: the buyer's for One-Stop-Shop sales, otherwise the seller's.
+// Without a country Austria is assumed.
+func VATCountry(inv *erechnung.Invoice, taxCode *string) string {
+	party := inv.Seller
+	if taxCode != nil && *taxCode == TaxCodeOSS {
+		party = inv.Buyer
+	}
+	if party == nil || party.Country == "" {
+		return "AT"
+	}
+	return party.Country
+}
+
+// CheckVATRates checks the line rates of an invoice against the VAT rates in
+// force in its VAT country on the issue date, which is taken as the date of
+// supply. Lines of category S need the standard rate, lines of category AA
+// one of the reduced rates. Other categories are checked by the EN 16931
+// rules. Countries without reference rates only get a warning.
+func CheckVATRates(result *erechnung.InvoiceValidationResult, inv *erechnung.Invoice, taxCode *string) {
+	country := VATCountry(inv, taxCode)
+	rates, ok := refdata.VATRatesFor(country, inv.IssueDate)
+	if !ok {
+		for _, line := range inv.Lines {
+			if line.TaxCategory == erechnung.TaxCategoryStandard || line.TaxCategory == erechnung.TaxCategoryReduced {
+				result.Warnings = append(result.Warnings, erechnung.ValidationError{
+					Code:    "VAT-COUNTRY",
+					Message: fmt.Sprintf("No reference VAT rates for country %s, line rates are not checked", country),
+					Field:   "lines",
+				})
+				return
+			}
+		}
+		return
+	}
+
+	date := inv.IssueDate.Format("2006-01-02")
+	for _, line := range inv.Lines {
+		field := "lines[" + line.ID + "].tax_percent"
+		switch line.TaxCategory {
+		case erechnung.TaxCategoryStandard:
+			if line.TaxPercent > 0 && !rates.IsStandard(line.TaxPercent) {
+				addVATError(result, "VAT-S-RATE", fmt.Sprintf("VAT rate %s%% is not the standard rate of %s on %s (%s%%)",
+					formatRate(line.TaxPercent), country, date, formatRate(rates.Standard)), field)
+			}
+		case erechnung.TaxCategoryReduced:
+			if line.TaxPercent > 0 && !rates.IsReduced(line.TaxPercent) {
+				addVATError(result, "VAT-AA-RATE", fmt.Sprintf("VAT rate %s%% is not a reduced rate of %s on %s (%s)",
+					formatRate(line.TaxPercent), country, date, formatRates(rates.Reduced)), field)
+			}
+		}
+	}
+}
+
+// addVATError adds a rate error to a validation result
+func addVATError(result *erechnung.InvoiceValidationResult, code, message, field string) {
+	result.Valid = false
+	result.Errors = append(result.Errors, erechnung.ValidationError{
+		Code:    code,
+		Message: message,
+		Field:   field,
+	})
+}
+
+// formatRate formats a rate in percent without trailing zeros
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', -1, 64)
+}
+
+// formatRates lists rates in percent, or "none"
+func formatRates(rates []float64) string {
+	if len(rates) == 0 {
+		return "none"
+	}
+	s := ""
+	for i, rate := range rates {
+		if i > 0 {
+			s += ", "
+		}
+		s += formatRate(rate) + "%"
+	}
+	return s
+}
@@ -0,0 +1,164 @@
+package jahreserklaerung
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/uva"
+)
+
+// Kennzahl is a Kennzahl of the UVA and the U1
+type Kennzahl struct {
+	KZ          string
+	Bezeichnung string
+	Wert        func(d *uva.UVAData) *int64
+}
+
+// Kennzahlen are the Kennzahlen of the U1 in the order of the form
+var Kennzahlen = []Kennzahl{
+	{"KZ000", "Gesamtbetrag der Lieferungen und sonstigen Leistungen", func(d *uva.UVAData) *int64 { return &d.KZ000 }},
+	{"KZ001", "Innergemeinschaftliche Lieferungen", func(d *uva.UVAData) *int64 { return &d.KZ001 }},
+	{"KZ011", "Steuerfrei ohne Vorsteuerabzug", func(d *uva.UVAData) *int64 { return &d.KZ011 }},
+	{"KZ017", "Normalsteuersatz 20%", func(d *uva.UVAData) *int64 { return &d.KZ017 }},
+	{"KZ018", "Ermäßigter Steuersatz 10%", func(d *uva.UVAData) *int64 { return &d.KZ018 }},
+	{"KZ019", "Ermäßigter Steuersatz 13%", func(d *uva.UVAData) *int64 { return &d.KZ019 }},
+	{"KZ020", "Sonstige Steuersätze", func(d *uva.UVAData) *int64 { return &d.KZ020 }},
+	{"KZ022", "Einfuhrumsatzsteuer", func(d *uva.UVAData) *int64 { return &d.KZ022 }},
+	{"KZ029", "Innergemeinschaftliche Erwerbe", func(d *uva.UVAData) *int64 { return &d.KZ029 }},
+	{"KZ060", "Vorsteuer", func(d *uva.UVAData) *int64 { return &d.KZ060 }},
+	{"KZ065", "Einfuhrumsatzsteuer als Vorsteuer", func(d *uva.UVAData) *int64 { return &d.KZ065 }},
+	{"KZ066", "Vorsteuern aus innergemeinschaftlichen Erwerben", func(d *uva.UVAData) *int64 { return &d.KZ066 }},
+	{"KZ070", "Sonstige Berichtigungen", func(d *uva.UVAData) *int64 { return &d.KZ070 }},
+	{"KZ095", "Zahllast/Gutschrift", func(d *uva.UVAData) *int64 { return &d.KZ095 }},
+}
+
+// Summe adds up the Kennzahlen of the UVA filings, including their
+// Zahllast
+func Summe(perioden []Periode) uva.UVAData {
+	var sum uva.UVAData
+	for i := range perioden {
+		for _, kz := range Kennzahlen {
+			*kz.Wert(&sum) += *kz.Wert(&perioden[i].Kennzahlen)
+		}
+	}
+	return sum
+}
+
+// Entwurf returns the draft Kennzahlen of the year: the sum of the UVA
+// filings with the Zahllast calculated on the year, which can differ from
+// the sum of the Zahllasten by rounding
+func Entwurf(perioden []Periode) uva.UVAData {
+	sum := Summe(perioden)
+	sum.KZ095 = uva.CalculateKZ095(&sum)
+	return sum
+}
+
+// Abgleichen reconciles the Kennzahlen of a Jahreserklärung with the UVA
+// filings of the year
+func Abgleichen(year int, kennzahlen uva.UVAData, perioden []Periode) *Abgleich {
+	a := &Abgleich{
+		Perioden:         perioden,
+		FehlendePerioden: FehlendePerioden(year, perioden),
+		UVASumme:         Summe(perioden),
+		Abweichungen:     []Abweichung{},
+	}
+	if a.Perioden == nil {
+		a.Perioden = []Periode{}
+	}
+	for _, kz := range Kennzahlen {
+		erklaerung, summe := *kz.Wert(&kennzahlen), *kz.Wert(&a.UVASumme)
+		if erklaerung != summe {
+			a.Abweichungen = append(a.Abweichungen, Abweichung{
+				Kennzahl:    kz.KZ,
+				Bezeichnung: kz.Bezeichnung,
+				Erklaerung:  erklaerung,
+				UVASumme:    summe,
+				Differenz:   erklaerung - summe,
+			})
+		}
+	}
+	return a
+}
+
+// FehlendePerioden returns the months of a year covered by neither a
+// monthly nor a quarterly UVA filing
+func FehlendePerioden(year int, perioden []Periode) []string {
+	covered := make(map[int]bool)
+	for _, p := range perioden {
+		if p.Month > 0 {
+			covered[p.Month] = true
+		}
+		if p.Quarter > 0 {
+			for m := p.Quarter*3 - 2; m <= p.Quarter*3; m++ {
+				covered[m] = true
+			}
+		}
+	}
+	var missing []string
+	for m := 1; m <= 12; m++ {
+		if !covered[m] {
+			missing = append(missing, MonatsPeriode(year, m))
+		}
+	}
+	return missing
+}
+
+// MonatsPeriode formats a monthly UVA period as 03/2025
+func MonatsPeriode(year, month int) string {
+	return fmt.Sprintf("%02d/%d", month, year)
+}
+
+// QuartalsPeriode formats a quarterly UVA period as Q1/2025
+func QuartalsPeriode(year, quarter int) string {
+	return fmt.Sprintf("Q%d/%d", quarter, year)
+}
+
+// U1 returns the FinanzOnline Umsatzsteuererklärung of a Jahreserklärung
+func U1(e *Erklaerung) *fonws.U1 {
+	u := &fonws.U1{Year: e.Year, Kennzahlen: make(map[string]int64, len(Kennzahlen))}
+	for _, kz := range Kennzahlen {
+		u.Kennzahlen[kz.KZ] = *kz.Wert(&e.Kennzahlen)
+	}
+	return u
+}
+
+// WriteCSV writes a Jahreserklärung as CSV for the Steuerberater, one row
+// per Kennzahl with the sum of the UVA filings and the difference. Amounts
+// are in EUR with two decimals.
+func WriteCSV(e *Erklaerung) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{"jahr", "erklaerung", "kennzahl", "bezeichnung", "betrag", "uva_summe", "differenz"}
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	var summe uva.UVAData
+	if e.Abgleich != nil {
+		summe = e.Abgleich.UVASumme
+	}
+	for _, kz := range Kennzahlen {
+		betrag, uvaSumme := *kz.Wert(&e.Kennzahlen), *kz.Wert(&summe)
+		row := []string{
+			fmt.Sprint(e.Year),
+			e.Art,
+			kz.KZ[2:],
+			kz.Bezeichnung,
+			fonws.FormatCents(betrag),
+			fonws.FormatCents(uvaSumme),
+			fonws.FormatCents(betrag - uvaSumme),
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,289 @@
+package jahreserklaerung
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/uva"
+)
+
+// Handler handles Jahreserklärung HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new Jahreserklärung handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the Jahreserklärung routes. Like UVA, changing
+// and finalizing is for admins.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/jahreserklaerungen", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("POST /api/v1/jahreserklaerungen", requireAuth(requireAdmin(http.HandlerFunc(h.Create))))
+	router.Handle("GET /api/v1/jahreserklaerungen/{id}", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("PUT /api/v1/jahreserklaerungen/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Update))))
+	router.Handle("POST /api/v1/jahreserklaerungen/{id}/recalculate", requireAuth(requireAdmin(http.HandlerFunc(h.Recalculate))))
+	router.Handle("DELETE /api/v1/jahreserklaerungen/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Delete))))
+	router.Handle("POST /api/v1/jahreserklaerungen/{id}/finalize", requireAuth(requireAdmin(http.HandlerFunc(h.Finalize))))
+	router.Handle("GET /api/v1/jahreserklaerungen/{id}/xml", requireAuth(http.HandlerFunc(h.XML)))
+	router.Handle("GET /api/v1/jahreserklaerungen/{id}/csv", requireAuth(http.HandlerFunc(h.CSV)))
+}
+
+// List handles GET /api/v1/jahreserklaerungen
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	filter := ListFilter{TenantID: tenantID, Limit: 50}
+	if accountIDStr := r.URL.Query().Get("account_id"); accountIDStr != "" {
+		accountID, err := uuid.Parse(accountIDStr)
+		if err != nil {
+			api.BadRequest(w, "invalid account_id")
+			return
+		}
+		filter.AccountID = &accountID
+	}
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil {
+			api.BadRequest(w, "invalid year")
+			return
+		}
+		filter.Year = &year
+	}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	list, total, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if list == nil {
+		list = []*Erklaerung{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  list,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// Create handles POST /api/v1/jahreserklaerungen
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.RequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var input Input
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	e, err := h.service.Create(r.Context(), tenantID, userID, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, e)
+}
+
+// Get handles GET /api/v1/jahreserklaerungen/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	e, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, e)
+}
+
+// Update handles PUT /api/v1/jahreserklaerungen/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Kennzahlen uva.UVAData `json:"kennzahlen"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	e, err := h.service.Update(r.Context(), tenantID, id, req.Kennzahlen)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, e)
+}
+
+// Recalculate handles POST /api/v1/jahreserklaerungen/{id}/recalculate
+func (h *Handler) Recalculate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	e, err := h.service.Recalculate(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, e)
+}
+
+// Delete handles DELETE /api/v1/jahreserklaerungen/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), tenantID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Finalize handles POST /api/v1/jahreserklaerungen/{id}/finalize
+func (h *Handler) Finalize(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.RequestUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	e, err := h.service.Finalize(r.Context(), tenantID, id, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, e)
+}
+
+// XML handles GET /api/v1/jahreserklaerungen/{id}/xml
+func (h *Handler) XML(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	xmlContent, err := h.service.XML(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Disposition", "attachment; filename=u1.xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(xmlContent)
+}
+
+// CSV handles GET /api/v1/jahreserklaerungen/{id}/csv
+func (h *Handler) CSV(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	e, content, err := h.service.CSV(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=u1-%d.csv", e.Year))
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrErklaerungNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrAccountNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrDuplicateErklaerung), errors.Is(err, ErrNotDraft):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, ErrInvalidYear), errors.Is(err, ErrNotFinanzOnline), errors.Is(err, ErrNoUVA),
+		errors.Is(err, ErrNegativeKennzahl):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	default:
+		api.InternalError(w)
+	}
+}
@@ -0,0 +1,234 @@
+package jahreserklaerung
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/internal/uva"
+)
+
+// Repository handles Jahreserklärung database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new Jahreserklärung repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const erklaerungColumns = `id, tenant_id, account_id, year, art, kennzahlen, abgleich, status,
+	finalized_at, finalized_by, created_by, created_at, updated_at`
+
+func scanErklaerung(row pgx.Row) (*Erklaerung, error) {
+	var e Erklaerung
+	var kennzahlenJSON, abgleichJSON []byte
+	if err := row.Scan(&e.ID, &e.TenantID, &e.AccountID, &e.Year, &e.Art, &kennzahlenJSON, &abgleichJSON,
+		&e.Status, &e.FinalizedAt, &e.FinalizedBy, &e.CreatedBy, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(kennzahlenJSON, &e.Kennzahlen); err != nil {
+		return nil, fmt.Errorf("invalid kennzahlen: %w", err)
+	}
+	if err := json.Unmarshal(abgleichJSON, &e.Abgleich); err != nil {
+		return nil, fmt.Errorf("invalid abgleich: %w", err)
+	}
+	return &e, nil
+}
+
+func marshal(e *Erklaerung) ([]byte, []byte, error) {
+	kennzahlenJSON, err := json.Marshal(e.Kennzahlen)
+	if err != nil {
+		return nil, nil, err
+	}
+	abgleichJSON, err := json.Marshal(e.Abgleich)
+	if err != nil {
+		return nil, nil, err
+	}
+	return kennzahlenJSON, abgleichJSON, nil
+}
+
+// Create inserts a Jahreserklärung
+func (r *Repository) Create(ctx context.Context, e *Erklaerung) error {
+	kennzahlenJSON, abgleichJSON, err := marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to create Jahreserklärung: %w", err)
+	}
+	saved, err := scanErklaerung(r.db.QueryRow(ctx, `
+		INSERT INTO jahreserklaerungen (tenant_id, account_id, year, art, kennzahlen, abgleich, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+erklaerungColumns,
+		e.TenantID, e.AccountID, e.Year, e.Art, kennzahlenJSON, abgleichJSON, e.CreatedBy))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicateErklaerung
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create Jahreserklärung: %w", err)
+	}
+	*e = *saved
+	return nil
+}
+
+// Update replaces the Kennzahlen and the Abgleich of a draft
+func (r *Repository) Update(ctx context.Context, e *Erklaerung) error {
+	kennzahlenJSON, abgleichJSON, err := marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to update Jahreserklärung: %w", err)
+	}
+	saved, err := scanErklaerung(r.db.QueryRow(ctx, `
+		UPDATE jahreserklaerungen SET kennzahlen = $3, abgleich = $4, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status = 'draft'
+		RETURNING `+erklaerungColumns,
+		e.ID, e.TenantID, kennzahlenJSON, abgleichJSON))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotDraft
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update Jahreserklärung: %w", err)
+	}
+	*e = *saved
+	return nil
+}
+
+// Get returns a Jahreserklärung of a tenant
+func (r *Repository) Get(ctx context.Context, tenantID, id uuid.UUID) (*Erklaerung, error) {
+	e, err := scanErklaerung(r.db.QueryRow(ctx,
+		`SELECT `+erklaerungColumns+` FROM jahreserklaerungen WHERE id = $1 AND tenant_id = $2`, id, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrErklaerungNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Jahreserklärung: %w", err)
+	}
+	return e, nil
+}
+
+// List returns the Jahreserklärungen of a tenant, the latest year first
+func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Erklaerung, int, error) {
+	where := "tenant_id = $1"
+	args := []interface{}{filter.TenantID}
+	if filter.AccountID != nil {
+		args = append(args, *filter.AccountID)
+		where += fmt.Sprintf(" AND account_id = $%d", len(args))
+	}
+	if filter.Year != nil {
+		args = append(args, *filter.Year)
+		where += fmt.Sprintf(" AND year = $%d", len(args))
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM jahreserklaerungen WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count Jahreserklärungen: %w", err)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT %s FROM jahreserklaerungen
+		WHERE %s
+		ORDER BY year DESC, created_at DESC
+		LIMIT $%d OFFSET $%d`, erklaerungColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list Jahreserklärungen: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*Erklaerung
+	for rows.Next() {
+		e, err := scanErklaerung(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list Jahreserklärungen: %w", err)
+		}
+		list = append(list, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list Jahreserklärungen: %w", err)
+	}
+	return list, total, nil
+}
+
+// Delete removes a draft
+func (r *Repository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM jahreserklaerungen WHERE id = $1 AND tenant_id = $2 AND status = 'draft'`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete Jahreserklärung: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.Get(ctx, tenantID, id); err != nil {
+			return err
+		}
+		return ErrNotDraft
+	}
+	return nil
+}
+
+// MarkFinal releases a draft to the Steuerberater
+func (r *Repository) MarkFinal(ctx context.Context, tenantID, id, userID uuid.UUID) (*Erklaerung, error) {
+	e, err := scanErklaerung(r.db.QueryRow(ctx, `
+		UPDATE jahreserklaerungen SET
+			status = 'final', finalized_at = NOW(), finalized_by = $3, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status = 'draft'
+		RETURNING `+erklaerungColumns, id, tenantID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := r.Get(ctx, tenantID, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrNotDraft
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to finalize Jahreserklärung: %w", err)
+	}
+	return e, nil
+}
+
+// ListPerioden returns the UVA filings of an account in a year. Of a
+// period filed more than once, e.g. with a Berichtigung, the latest filing
+// counts.
+func (r *Repository) ListPerioden(ctx context.Context, accountID uuid.UUID, year int) ([]Periode, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT ON (period_type, COALESCE(period_month, period_quarter))
+			id, period_type, period_month, period_quarter, corrects_id IS NOT NULL, data
+		FROM uva_submissions
+		WHERE account_id = $1 AND period_year = $2 AND status IN ('submitted', 'accepted')
+		ORDER BY period_type, COALESCE(period_month, period_quarter), submitted_at DESC NULLS LAST, created_at DESC`,
+		accountID, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list UVA filings: %w", err)
+	}
+	defer rows.Close()
+
+	var perioden []Periode
+	for rows.Next() {
+		var p Periode
+		var periodType string
+		var month, quarter *int
+		var data json.RawMessage
+		if err := rows.Scan(&p.SubmissionID, &periodType, &month, &quarter, &p.Berichtigung, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan UVA filing: %w", err)
+		}
+		kz, err := uva.ParseData(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data of UVA %s: %w", p.SubmissionID, err)
+		}
+		p.Kennzahlen = *kz
+		if periodType == uva.PeriodTypeMonthly && month != nil {
+			p.Periode = MonatsPeriode(year, *month)
+			p.Month = *month
+		} else if quarter != nil {
+			p.Periode = QuartalsPeriode(year, *quarter)
+			p.Quarter = *quarter
+		} else {
+			continue
+		}
+		perioden = append(perioden, p)
+	}
+	return perioden, rows.Err()
+}
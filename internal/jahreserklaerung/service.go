@@ -0,0 +1,155 @@
+package jahreserklaerung
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/account"
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/uva"
+)
+
+// Service handles Jahreserklärungen
+type Service struct {
+	repo           *Repository
+	accountService *account.Service
+}
+
+// NewService creates a new Jahreserklärung service
+func NewService(repo *Repository, accountService *account.Service) *Service {
+	return &Service{
+		repo:           repo,
+		accountService: accountService,
+	}
+}
+
+// Create drafts the U1 of a FinanzOnline account for a year from its UVA
+// filings
+func (s *Service) Create(ctx context.Context, tenantID, userID uuid.UUID, input *Input) (*Erklaerung, error) {
+	if input.Year < 2000 || input.Year > 2100 {
+		return nil, ErrInvalidYear
+	}
+	acc, err := s.accountService.GetAccount(ctx, input.AccountID, tenantID)
+	if err != nil {
+		return nil, ErrAccountNotFound
+	}
+	if acc.Type != account.AccountTypeFinanzOnline {
+		return nil, ErrNotFinanzOnline
+	}
+
+	perioden, err := s.repo.ListPerioden(ctx, input.AccountID, input.Year)
+	if err != nil {
+		return nil, err
+	}
+	if len(perioden) == 0 {
+		return nil, ErrNoUVA
+	}
+
+	kennzahlen := Entwurf(perioden)
+	e := &Erklaerung{
+		TenantID:   tenantID,
+		AccountID:  input.AccountID,
+		Year:       input.Year,
+		Art:        ArtU1,
+		Kennzahlen: kennzahlen,
+		Abgleich:   Abgleichen(input.Year, kennzahlen, perioden),
+		CreatedBy:  &userID,
+	}
+	if err := s.repo.Create(ctx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Update adjusts the Kennzahlen of a draft, e.g. with year-end bookings.
+// The Zahllast is recalculated and the Abgleich shows the differences to
+// the UVA filings.
+func (s *Service) Update(ctx context.Context, tenantID, id uuid.UUID, kennzahlen uva.UVAData) (*Erklaerung, error) {
+	e, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if e.Status != StatusDraft {
+		return nil, ErrNotDraft
+	}
+	for _, kz := range Kennzahlen {
+		if kz.KZ != "KZ095" && *kz.Wert(&kennzahlen) < 0 {
+			return nil, ErrNegativeKennzahl
+		}
+	}
+
+	kennzahlen.KZ095 = uva.CalculateKZ095(&kennzahlen)
+	e.Kennzahlen = kennzahlen
+	e.Abgleich = Abgleichen(e.Year, kennzahlen, e.Abgleich.Perioden)
+	if err := s.repo.Update(ctx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Recalculate drafts the Kennzahlen of a draft anew from the current UVA
+// filings, e.g. after a Berichtigung. Adjustments are discarded.
+func (s *Service) Recalculate(ctx context.Context, tenantID, id uuid.UUID) (*Erklaerung, error) {
+	e, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if e.Status != StatusDraft {
+		return nil, ErrNotDraft
+	}
+
+	perioden, err := s.repo.ListPerioden(ctx, e.AccountID, e.Year)
+	if err != nil {
+		return nil, err
+	}
+	e.Kennzahlen = Entwurf(perioden)
+	e.Abgleich = Abgleichen(e.Year, e.Kennzahlen, perioden)
+	if err := s.repo.Update(ctx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Get returns a Jahreserklärung
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Erklaerung, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// List returns the Jahreserklärungen of a tenant
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]*Erklaerung, int, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Delete removes a draft
+func (s *Service) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+// Finalize releases a draft to the Steuerberater; it can no longer be
+// changed
+func (s *Service) Finalize(ctx context.Context, tenantID, id, userID uuid.UUID) (*Erklaerung, error) {
+	return s.repo.MarkFinal(ctx, tenantID, id, userID)
+}
+
+// XML returns the FinanzOnline XML of the U1
+func (s *Service) XML(ctx context.Context, tenantID, id uuid.UUID) ([]byte, error) {
+	e, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	return fonws.GenerateU1XML(U1(e))
+}
+
+// CSV returns the U1 with its Abgleich as CSV
+func (s *Service) CSV(ctx context.Context, tenantID, id uuid.UUID) (*Erklaerung, []byte, error) {
+	e, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := WriteCSV(e)
+	if err != nil {
+		return nil, nil, err
+	}
+	return e, data, nil
+}
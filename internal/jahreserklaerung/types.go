@@ -0,0 +1,99 @@
+// Package jahreserklaerung drafts the annual Umsatzsteuererklärung (U1) of
+// a company from the UVA filings of the year, reconciles it with the sum of
+// the filings and exports it as FinanzOnline XML or as CSV for the
+// Steuerberater.
+package jahreserklaerung
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/uva"
+)
+
+var (
+	ErrErklaerungNotFound  = errors.New("Jahreserklärung not found")
+	ErrDuplicateErklaerung = errors.New("a Jahreserklärung for this account and year already exists")
+	ErrInvalidYear         = errors.New("year must be between 2000 and 2100")
+	ErrAccountNotFound     = errors.New("account not found")
+	ErrNotFinanzOnline     = errors.New("account is not a FinanzOnline account")
+	ErrNoUVA               = errors.New("no submitted UVA in this year")
+	ErrNegativeKennzahl    = errors.New("Kennzahlen other than KZ095 must not be negative")
+	ErrNotDraft            = errors.New("Jahreserklärung has been finalized")
+)
+
+// ArtU1 is the Umsatzsteuererklärung. The income tax returns (E1, K1)
+// cannot be derived from the UVA and are not drafted.
+const ArtU1 = "U1"
+
+// Status of a Jahreserklärung
+const (
+	StatusDraft = "draft"
+	StatusFinal = "final" // released to the Steuerberater, no longer changed
+)
+
+// Erklaerung is the draft Jahreserklärung of a FinanzOnline account for a
+// year. Its Kennzahlen start as the sum of the UVA filings and can be
+// adjusted, e.g. with year-end bookings; the Abgleich shows how they
+// differ from the filings.
+type Erklaerung struct {
+	ID          uuid.UUID   `json:"id"`
+	TenantID    uuid.UUID   `json:"tenant_id"`
+	AccountID   uuid.UUID   `json:"account_id"`
+	Year        int         `json:"year"`
+	Art         string      `json:"art"`
+	Kennzahlen  uva.UVAData `json:"kennzahlen"` // In cents
+	Abgleich    *Abgleich   `json:"abgleich"`
+	Status      string      `json:"status"`
+	FinalizedAt *time.Time  `json:"finalized_at,omitempty"`
+	FinalizedBy *uuid.UUID  `json:"finalized_by,omitempty"`
+	CreatedBy   *uuid.UUID  `json:"created_by,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// Abgleich reconciles a Jahreserklärung with the UVA filings of the year
+type Abgleich struct {
+	Perioden         []Periode    `json:"perioden"`
+	FehlendePerioden []string     `json:"fehlende_perioden,omitempty"`
+	UVASumme         uva.UVAData  `json:"uva_summe"`
+	Abweichungen     []Abweichung `json:"abweichungen"`
+}
+
+// Periode is the UVA filing counted for a period. Of a period filed more
+// than once, e.g. with a Berichtigung, the latest filing counts.
+type Periode struct {
+	Periode      string      `json:"periode"` // e.g. 03/2025 or Q1/2025
+	Month        int         `json:"month,omitempty"`
+	Quarter      int         `json:"quarter,omitempty"`
+	SubmissionID uuid.UUID   `json:"submission_id"`
+	Berichtigung bool        `json:"berichtigung,omitempty"`
+	Kennzahlen   uva.UVAData `json:"kennzahlen"`
+}
+
+// Abweichung is a Kennzahl of the Jahreserklärung that differs from the
+// sum of the UVA filings
+type Abweichung struct {
+	Kennzahl    string `json:"kennzahl"`
+	Bezeichnung string `json:"bezeichnung"`
+	Erklaerung  int64  `json:"erklaerung"`
+	UVASumme    int64  `json:"uva_summe"`
+	Differenz   int64  `json:"differenz"`
+}
+
+// Input creates a Jahreserklärung
+type Input struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Year      int       `json:"year"`
+}
+
+// ListFilter filters Jahreserklärungen
+type ListFilter struct {
+	TenantID  uuid.UUID
+	AccountID *uuid.UUID
+	Year      *int
+	Limit     int
+	Offset    int
+}
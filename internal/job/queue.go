@@ -114,7 +114,8 @@ func (q *Queue) Enqueue(ctx context.Context, tenantID uuid.UUID, jobType string,
 }
 
 // Dequeue fetches and claims the next available job
-// Uses SELECT FOR UPDATE SKIP LOCKED for concurrent worker coordination
+// Uses SELECT FOR UPDATE SKIP LOCKED for concurrent worker coordination.
+// Nothing is claimed while the queue is paused.
 func (q *Queue) Dequeue(ctx context.Context) (*Job, error) {
 	query := `
 		UPDATE jobs
@@ -122,6 +123,7 @@ func (q *Queue) Dequeue(ctx context.Context) (*Job, error) {
 		WHERE id = (
 			SELECT id FROM jobs
 			WHERE status = $4 AND run_at <= $2
+			AND NOT EXISTS (SELECT 1 FROM job_queue_pauses WHERE expires_at > $2)
 			ORDER BY priority DESC, run_at ASC
 			FOR UPDATE SKIP LOCKED
 			LIMIT 1
@@ -155,8 +157,9 @@ func (q *Queue) Dequeue(ctx context.Context) (*Job, error) {
 	return job, nil
 }
 
-// Complete marks a job as successfully completed
-func (q *Queue) Complete(ctx context.Context, jobID uuid.UUID, result json.RawMessage) error {
+// Complete marks a job as successfully completed. version is the version of
+// the handler, recorded in the job history.
+func (q *Queue) Complete(ctx context.Context, jobID uuid.UUID, result json.RawMessage, version string) error {
 	now := time.Now()
 
 	// First, get the job details for history
@@ -181,30 +184,17 @@ func (q *Queue) Complete(ctx context.Context, jobID uuid.UUID, result json.RawMe
 		return ErrJobNotFound
 	}
 
-	// Record in job history
-	historyQuery := `
-		INSERT INTO job_history (
-			tenant_id, job_id, type, payload, status, result,
-			started_at, completed_at, worker_id, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-	`
-
-	_, err = q.db.Exec(ctx, historyQuery,
-		job.TenantID, jobID, job.Type, job.Payload, StatusCompleted, result,
-		job.StartedAt, now, q.workerID, now,
-	)
-	if err != nil {
-		q.logger.Error("failed to record job history", "job_id", jobID, "error", err)
-		// Don't return error - job completion is more important
-	}
+	// Don't fail on the history - job completion is more important
+	q.recordHistory(ctx, job, StatusCompleted, result, "", version, now)
 
 	q.logger.Debug("job completed", "job_id", jobID)
 
 	return nil
 }
 
-// Fail marks a job as failed and handles retry logic
-func (q *Queue) Fail(ctx context.Context, jobID uuid.UUID, errMsg string) error {
+// Fail marks a job as failed and handles retry logic. Every failed attempt
+// is recorded in the job history with the version of the handler.
+func (q *Queue) Fail(ctx context.Context, jobID uuid.UUID, errMsg string, version string) error {
 	now := time.Now()
 
 	// Get job to check retry count
@@ -217,7 +207,7 @@ func (q *Queue) Fail(ctx context.Context, jobID uuid.UUID, errMsg string) error
 
 	// Check if we should move to dead letter queue
 	if newRetryCount >= job.MaxRetries {
-		return q.moveToDead(ctx, job, errMsg)
+		return q.moveToDead(ctx, job, errMsg, version)
 	}
 
 	// Calculate exponential backoff delay: 1s, 2s, 4s, 8s, ...
@@ -235,6 +225,7 @@ func (q *Queue) Fail(ctx context.Context, jobID uuid.UUID, errMsg string) error
 	if err != nil {
 		return fmt.Errorf("fail job: %w", err)
 	}
+	q.recordHistory(ctx, job, StatusRetried, nil, errMsg, version, now)
 
 	q.logger.Info("job failed, will retry",
 		"job_id", jobID,
@@ -246,8 +237,33 @@ func (q *Queue) Fail(ctx context.Context, jobID uuid.UUID, errMsg string) error
 	return nil
 }
 
+// RetryLater is implemented by errors asking for a job to run again at a
+// given time without counting as a failed attempt, such as calls during an
+// announced maintenance window of an external service
+type RetryLater interface {
+	error
+	RetryAt() time.Time
+}
+
+// Defer puts a running job back into the queue to run at runAt. The retry
+// count is left unchanged.
+func (q *Queue) Defer(ctx context.Context, jobID uuid.UUID, runAt time.Time, reason string) error {
+	now := time.Now()
+	_, err := q.db.Exec(ctx, `
+		UPDATE jobs
+		SET status = $1, last_error = $2, run_at = $3, started_at = NULL, worker_id = NULL, updated_at = $4
+		WHERE id = $5
+	`, StatusPending, reason, runAt, now, jobID)
+	if err != nil {
+		return fmt.Errorf("defer job: %w", err)
+	}
+
+	q.logger.Info("job deferred", "job_id", jobID, "run_at", runAt, "reason", reason)
+	return nil
+}
+
 // moveToDead moves a job to the dead letter queue
-func (q *Queue) moveToDead(ctx context.Context, job *Job, lastError string) error {
+func (q *Queue) moveToDead(ctx context.Context, job *Job, lastError string, version string) error {
 	now := time.Now()
 
 	// Collect all errors
@@ -287,21 +303,7 @@ func (q *Queue) moveToDead(ctx context.Context, job *Job, lastError string) erro
 		return fmt.Errorf("update job to dead: %w", err)
 	}
 
-	// Record in job history
-	historyQuery := `
-		INSERT INTO job_history (
-			tenant_id, job_id, type, payload, status, error_message,
-			started_at, completed_at, worker_id, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-	`
-
-	_, err = q.db.Exec(ctx, historyQuery,
-		job.TenantID, job.ID, job.Type, job.Payload, StatusFailed, lastError,
-		job.StartedAt, now, q.workerID, now,
-	)
-	if err != nil {
-		q.logger.Error("failed to record job history", "job_id", job.ID, "error", err)
-	}
+	q.recordHistory(ctx, job, StatusFailed, nil, lastError, version, now)
 
 	q.logger.Warn("job moved to dead letter queue",
 		"job_id", job.ID,
@@ -312,6 +314,31 @@ func (q *Queue) moveToDead(ctx context.Context, job *Job, lastError string) erro
 	return nil
 }
 
+// recordHistory records an attempt of a job in the job history. Errors are
+// logged only: the history must not decide the outcome of a job.
+func (q *Queue) recordHistory(ctx context.Context, job *Job, status string, result json.RawMessage, errMsg, version string, completedAt time.Time) {
+	startedAt := completedAt
+	if job.StartedAt != nil {
+		startedAt = *job.StartedAt
+	}
+	if result == nil {
+		result = json.RawMessage(`{}`)
+	}
+
+	_, err := q.db.Exec(ctx, `
+		INSERT INTO job_history (
+			tenant_id, job_id, type, payload, status, attempt, result, error_message,
+			started_at, completed_at, worker_id, handler_version, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $10)
+	`,
+		job.TenantID, job.ID, job.Type, job.Payload, status, job.RetryCount+1, result, nullString(errMsg),
+		startedAt, completedAt, q.workerID, nullString(version),
+	)
+	if err != nil {
+		q.logger.Error("failed to record job history", "job_id", job.ID, "error", err)
+	}
+}
+
 // GetByID retrieves a job by its ID
 func (q *Queue) GetByID(ctx context.Context, id uuid.UUID) (*Job, error) {
 	query := `
@@ -411,6 +438,39 @@ func (q *Queue) DeleteCompletedJobs(ctx context.Context, olderThan time.Duration
 	return tag.RowsAffected(), nil
 }
 
+// Pause stops all workers from claiming jobs until Resume is called or the
+// pause expires. Jobs already running are not interrupted.
+func (q *Queue) Pause(ctx context.Context, reason string, ttl time.Duration) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := q.db.QueryRow(ctx, `
+		INSERT INTO job_queue_pauses (reason, expires_at) VALUES ($1, $2) RETURNING id
+	`, reason, time.Now().Add(ttl)).Scan(&id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("pause queue: %w", err)
+	}
+	q.logger.Info("job queue paused", "pause_id", id, "reason", reason, "ttl", ttl)
+	return id, nil
+}
+
+// Resume lifts a pause. Other pauses still in effect keep the queue paused.
+func (q *Queue) Resume(ctx context.Context, pauseID uuid.UUID) error {
+	if _, err := q.db.Exec(ctx, `DELETE FROM job_queue_pauses WHERE id = $1 OR expires_at <= NOW()`, pauseID); err != nil {
+		return fmt.Errorf("resume queue: %w", err)
+	}
+	q.logger.Info("job queue resumed", "pause_id", pauseID)
+	return nil
+}
+
+// RunningCount returns the number of jobs currently running
+func (q *Queue) RunningCount(ctx context.Context) (int64, error) {
+	var count int64
+	err := q.db.QueryRow(ctx, `SELECT COUNT(*) FROM jobs WHERE status = $1`, StatusRunning).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count running jobs: %w", err)
+	}
+	return count, nil
+}
+
 // nullString returns nil for empty strings
 func nullString(s string) interface{} {
 	if s == "" {
@@ -78,8 +78,9 @@ func (r *Repository) ListHistory(ctx context.Context, filter JobHistoryFilter) (
 
 	// Fetch rows
 	selectQuery := `
-		SELECT id, tenant_id, job_id, schedule_id, type, payload, status, result,
-		       error_message, started_at, completed_at, duration_ms, worker_id, created_at
+		SELECT id, tenant_id, job_id, schedule_id, type, payload, status, attempt, result,
+		       COALESCE(error_message, ''), started_at, completed_at, duration_ms, worker_id,
+		       COALESCE(handler_version, ''), created_at
 	` + baseQuery + " ORDER BY started_at DESC"
 
 	if filter.Limit > 0 {
@@ -104,8 +105,8 @@ func (r *Repository) ListHistory(ctx context.Context, filter JobHistoryFilter) (
 		h := &JobHistory{}
 		err := rows.Scan(
 			&h.ID, &h.TenantID, &h.JobID, &h.ScheduleID, &h.Type, &h.Payload,
-			&h.Status, &h.Result, &h.ErrorMessage, &h.StartedAt, &h.CompletedAt,
-			&h.DurationMs, &h.WorkerID, &h.CreatedAt,
+			&h.Status, &h.Attempt, &h.Result, &h.ErrorMessage, &h.StartedAt, &h.CompletedAt,
+			&h.DurationMs, &h.WorkerID, &h.HandlerVersion, &h.CreatedAt,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("scan job history: %w", err)
@@ -119,16 +120,17 @@ func (r *Repository) ListHistory(ctx context.Context, filter JobHistoryFilter) (
 // GetHistoryByID retrieves a single job history entry
 func (r *Repository) GetHistoryByID(ctx context.Context, id uuid.UUID) (*JobHistory, error) {
 	query := `
-		SELECT id, tenant_id, job_id, schedule_id, type, payload, status, result,
-		       error_message, started_at, completed_at, duration_ms, worker_id, created_at
+		SELECT id, tenant_id, job_id, schedule_id, type, payload, status, attempt, result,
+		       COALESCE(error_message, ''), started_at, completed_at, duration_ms, worker_id,
+		       COALESCE(handler_version, ''), created_at
 		FROM job_history WHERE id = $1
 	`
 
 	h := &JobHistory{}
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&h.ID, &h.TenantID, &h.JobID, &h.ScheduleID, &h.Type, &h.Payload,
-		&h.Status, &h.Result, &h.ErrorMessage, &h.StartedAt, &h.CompletedAt,
-		&h.DurationMs, &h.WorkerID, &h.CreatedAt,
+		&h.Status, &h.Attempt, &h.Result, &h.ErrorMessage, &h.StartedAt, &h.CompletedAt,
+		&h.DurationMs, &h.WorkerID, &h.HandlerVersion, &h.CreatedAt,
 	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -257,11 +259,11 @@ func (r *Repository) GetJobMetrics(ctx context.Context, tenantID uuid.UUID) (*Jo
 		return nil, fmt.Errorf("count running jobs: %w", err)
 	}
 
-	// Jobs completed in last 24 hours
+	// Jobs finished in last 24 hours; retried attempts are not outcomes
 	yesterday := time.Now().Add(-24 * time.Hour)
 	err = r.db.QueryRow(ctx, `
 		SELECT COUNT(*), COALESCE(SUM(CASE WHEN status = 'completed' THEN 1 ELSE 0 END), 0)
-		FROM job_history WHERE tenant_id = $1 AND started_at >= $2
+		FROM job_history WHERE tenant_id = $1 AND started_at >= $2 AND status <> 'retried'
 	`, tenantID, yesterday).Scan(&metrics.JobsLast24h, &metrics.SuccessLast24h)
 	if err != nil {
 		return nil, fmt.Errorf("count recent jobs: %w", err)
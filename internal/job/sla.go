@@ -0,0 +1,290 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"austrian-business-infrastructure/internal/config"
+)
+
+// SLA metrics
+const (
+	MetricFailureRate = "failure_rate"
+	MetricP95Duration = "p95_duration"
+)
+
+// SLA is the service level of a job type
+type SLA struct {
+	// MaxFailureRate is the share of finished jobs that may end in the dead
+	// letter queue; 0 disables the check
+	MaxFailureRate float64 `json:"max_failure_rate"`
+	// MaxP95 bounds the 95th percentile of the attempt durations; 0
+	// disables the check
+	MaxP95 time.Duration `json:"-"`
+}
+
+// SLAConfig holds the service levels of all job types
+type SLAConfig struct {
+	Default SLA
+	Types   map[string]SLA
+	// Window is the period the metrics are computed over
+	Window time.Duration
+	// MinRuns is how many finished jobs a type needs before it is judged, so
+	// a single failure of a rare job does not raise an alert
+	MinRuns int
+}
+
+// For returns the service level of a job type
+func (c SLAConfig) For(jobType string) SLA {
+	if sla, ok := c.Types[jobType]; ok {
+		return sla
+	}
+	return c.Default
+}
+
+// NewSLAConfig builds the SLA configuration from the environment settings
+func NewSLAConfig(cfg *config.JobSLAConfig) (SLAConfig, error) {
+	overrides, err := ParseSLAOverrides(cfg.Overrides)
+	if err != nil {
+		return SLAConfig{}, err
+	}
+	return SLAConfig{
+		Default: SLA{MaxFailureRate: cfg.MaxFailureRate, MaxP95: cfg.MaxP95},
+		Types:   overrides,
+		Window:  cfg.Window,
+		MinRuns: cfg.MinRuns,
+	}, nil
+}
+
+// ParseSLAOverrides parses a comma-separated list of service levels per job
+// type such as "document_analysis=0.1/10m,databox_sync=0.02/2m": the
+// maximum failure rate and the maximum P95 duration
+func ParseSLAOverrides(s string) (map[string]SLA, error) {
+	overrides := make(map[string]SLA)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		jobType, spec, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(jobType) == "" {
+			return nil, fmt.Errorf("invalid job SLA %q", entry)
+		}
+		rate, p95, ok := strings.Cut(spec, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid job SLA %q", entry)
+		}
+		var sla SLA
+		var err error
+		if sla.MaxFailureRate, err = strconv.ParseFloat(rate, 64); err != nil || sla.MaxFailureRate < 0 || sla.MaxFailureRate > 1 {
+			return nil, fmt.Errorf("invalid failure rate in job SLA %q", entry)
+		}
+		if sla.MaxP95, err = time.ParseDuration(p95); err != nil || sla.MaxP95 < 0 {
+			return nil, fmt.Errorf("invalid P95 duration in job SLA %q", entry)
+		}
+		overrides[strings.TrimSpace(jobType)] = sla
+	}
+	return overrides, nil
+}
+
+// TypeStats are the execution metrics of a job type over a window
+type TypeStats struct {
+	Type string `json:"type"`
+	// Runs are the finished jobs: completed or moved to the dead letter queue
+	Runs      int `json:"runs"`
+	Attempts  int `json:"attempts"`
+	Completed int `json:"completed"`
+	Retried   int `json:"retried"`
+	Failed    int `json:"failed"`
+	// SuccessRate is the share of runs that completed, retries included
+	SuccessRate float64 `json:"success_rate"`
+	FailureRate float64 `json:"failure_rate"`
+	// Durations are of all attempts, in milliseconds
+	P50Ms           float64    `json:"p50_ms"`
+	P95Ms           float64    `json:"p95_ms"`
+	MaxMs           float64    `json:"max_ms"`
+	LastRunAt       *time.Time `json:"last_run_at,omitempty"`
+	HandlerVersions []string   `json:"handler_versions"`
+}
+
+// Breach is a metric of a job type outside its service level
+type Breach struct {
+	JobType   string  `json:"job_type"`
+	Metric    string  `json:"metric"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	Runs      int     `json:"runs"`
+}
+
+// SLAStatus is a job type's metrics judged against its service level
+type SLAStatus struct {
+	TypeStats
+	MaxFailureRate float64 `json:"max_failure_rate"`
+	MaxP95Ms       float64 `json:"max_p95_ms"`
+	// Judged is false while the type has fewer than MinRuns runs
+	Judged   bool     `json:"judged"`
+	Breaches []Breach `json:"breaches"`
+}
+
+// Evaluate judges the metrics of each job type against its service level.
+// Failure rates and durations are in the same units as the alerts: a share
+// and milliseconds.
+func (c SLAConfig) Evaluate(stats []TypeStats) []SLAStatus {
+	statuses := make([]SLAStatus, 0, len(stats))
+	for _, s := range stats {
+		sla := c.For(s.Type)
+		status := SLAStatus{
+			TypeStats:      s,
+			MaxFailureRate: sla.MaxFailureRate,
+			MaxP95Ms:       float64(sla.MaxP95.Milliseconds()),
+			Judged:         s.Runs >= c.MinRuns && s.Runs > 0,
+			Breaches:       []Breach{},
+		}
+		if status.Judged {
+			if sla.MaxFailureRate > 0 && s.FailureRate > sla.MaxFailureRate {
+				status.Breaches = append(status.Breaches, Breach{
+					JobType: s.Type, Metric: MetricFailureRate, Value: s.FailureRate, Threshold: sla.MaxFailureRate, Runs: s.Runs,
+				})
+			}
+			if status.MaxP95Ms > 0 && s.P95Ms > status.MaxP95Ms {
+				status.Breaches = append(status.Breaches, Breach{
+					JobType: s.Type, Metric: MetricP95Duration, Value: s.P95Ms, Threshold: status.MaxP95Ms, Runs: s.Runs,
+				})
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// SLAAlert is a raised breach of a job type's service level. It stays open
+// until the metric is back within the service level.
+type SLAAlert struct {
+	ID            uuid.UUID  `json:"id"`
+	JobType       string     `json:"job_type"`
+	Metric        string     `json:"metric"`
+	Value         float64    `json:"value"`
+	Threshold     float64    `json:"threshold"`
+	Runs          int        `json:"runs"`
+	WindowSeconds int        `json:"window_seconds"`
+	RaisedAt      time.Time  `json:"raised_at"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+}
+
+// TypeStats computes the execution metrics of every job type with attempts
+// started since the given time
+func (r *Repository) TypeStats(ctx context.Context, since time.Time) ([]TypeStats, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT type, COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'completed'),
+			COUNT(*) FILTER (WHERE status = 'retried'),
+			COUNT(*) FILTER (WHERE status = 'failed'),
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY duration_ms), 0),
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY duration_ms), 0),
+			COALESCE(MAX(duration_ms), 0),
+			MAX(completed_at),
+			COALESCE(ARRAY_AGG(DISTINCT handler_version) FILTER (WHERE handler_version IS NOT NULL), '{}')
+		FROM job_history
+		WHERE started_at >= $1
+		GROUP BY type
+		ORDER BY type`, since)
+	if err != nil {
+		return nil, fmt.Errorf("query job type stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []TypeStats
+	for rows.Next() {
+		var s TypeStats
+		if err := rows.Scan(&s.Type, &s.Attempts, &s.Completed, &s.Retried, &s.Failed,
+			&s.P50Ms, &s.P95Ms, &s.MaxMs, &s.LastRunAt, &s.HandlerVersions); err != nil {
+			return nil, fmt.Errorf("scan job type stats: %w", err)
+		}
+		s.Runs = s.Completed + s.Failed
+		if s.Runs > 0 {
+			s.SuccessRate = float64(s.Completed) / float64(s.Runs)
+			s.FailureRate = float64(s.Failed) / float64(s.Runs)
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+const slaAlertColumns = `id, job_type, metric, value, threshold, runs, window_seconds, raised_at, resolved_at`
+
+func scanSLAAlert(row pgx.Row) (*SLAAlert, error) {
+	var a SLAAlert
+	if err := row.Scan(&a.ID, &a.JobType, &a.Metric, &a.Value, &a.Threshold, &a.Runs,
+		&a.WindowSeconds, &a.RaisedAt, &a.ResolvedAt); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// RaiseSLAAlert opens an alert for a breach. It returns nil if an alert for
+// the job type and metric is already open.
+func (r *Repository) RaiseSLAAlert(ctx context.Context, b Breach, window time.Duration) (*SLAAlert, error) {
+	a, err := scanSLAAlert(r.db.QueryRow(ctx, `
+		INSERT INTO job_sla_alerts (job_type, metric, value, threshold, runs, window_seconds)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (job_type, metric) WHERE resolved_at IS NULL DO NOTHING
+		RETURNING `+slaAlertColumns,
+		b.JobType, b.Metric, b.Value, b.Threshold, b.Runs, int(window.Seconds())))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("raise job SLA alert: %w", err)
+	}
+	return a, nil
+}
+
+// ResolveSLAAlert closes an open alert. It returns nil if the alert was
+// resolved already.
+func (r *Repository) ResolveSLAAlert(ctx context.Context, id uuid.UUID) (*SLAAlert, error) {
+	a, err := scanSLAAlert(r.db.QueryRow(ctx, `
+		UPDATE job_sla_alerts SET resolved_at = NOW()
+		WHERE id = $1 AND resolved_at IS NULL
+		RETURNING `+slaAlertColumns, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolve job SLA alert: %w", err)
+	}
+	return a, nil
+}
+
+// ListSLAAlerts returns the newest alerts, or only the open ones
+func (r *Repository) ListSLAAlerts(ctx context.Context, openOnly bool, limit int) ([]*SLAAlert, error) {
+	where := "TRUE"
+	if openOnly {
+		where = "resolved_at IS NULL"
+	}
+	rows, err := r.db.Query(ctx, `
+		SELECT `+slaAlertColumns+` FROM job_sla_alerts
+		WHERE `+where+`
+		ORDER BY raised_at DESC
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list job SLA alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []*SLAAlert
+	for rows.Next() {
+		a, err := scanSLAAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan job SLA alert: %w", err)
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
@@ -0,0 +1,98 @@
+package job
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// SLAHandler serves the job SLA dashboard. It covers the job types of the
+// whole platform and is authenticated with the maintenance token, not a
+// tenant session.
+type SLAHandler struct {
+	repo   *Repository
+	config SLAConfig
+	token  string
+	logger *slog.Logger
+}
+
+// NewSLAHandler creates a new job SLA handler
+func NewSLAHandler(repo *Repository, config SLAConfig, token string, logger *slog.Logger) *SLAHandler {
+	return &SLAHandler{repo: repo, config: config, token: token, logger: logger}
+}
+
+// RegisterRoutes registers the job SLA routes
+func (h *SLAHandler) RegisterRoutes(router *api.Router) {
+	router.Handle("GET /api/v1/maintenance/jobs/sla", h.requireToken(h.Dashboard))
+	router.Handle("GET /api/v1/maintenance/jobs/sla/alerts", h.requireToken(h.Alerts))
+}
+
+func (h *SLAHandler) requireToken(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !api.BearerTokenMatches(r, h.token) {
+			api.JSONError(w, http.StatusUnauthorized, "Invalid maintenance token", api.ErrCodeUnauthorized)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// Dashboard handles GET /api/v1/maintenance/jobs/sla?window=24h
+func (h *SLAHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
+	config := h.config
+	if s := r.URL.Query().Get("window"); s != "" {
+		window, err := time.ParseDuration(s)
+		if err != nil || window < time.Minute || window > 90*24*time.Hour {
+			api.BadRequest(w, "window must be a duration between 1m and 2160h")
+			return
+		}
+		config.Window = window
+	}
+
+	stats, err := h.repo.TypeStats(r.Context(), time.Now().Add(-config.Window))
+	if err != nil {
+		h.logger.Error("failed to compute job type stats", "error", err)
+		api.InternalError(w)
+		return
+	}
+	alerts, err := h.repo.ListSLAAlerts(r.Context(), true, 1000)
+	if err != nil {
+		h.logger.Error("failed to list job SLA alerts", "error", err)
+		api.InternalError(w)
+		return
+	}
+	if alerts == nil {
+		alerts = []*SLAAlert{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]any{
+		"window_seconds": int(config.Window.Seconds()),
+		"min_runs":       config.MinRuns,
+		"types":          config.Evaluate(stats),
+		"open_alerts":    alerts,
+	})
+}
+
+// Alerts handles GET /api/v1/maintenance/jobs/sla/alerts?open=true&limit=
+func (h *SLAHandler) Alerts(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if s := r.URL.Query().Get("limit"); s != "" {
+		if l, err := strconv.Atoi(s); err == nil && l > 0 && l <= 1000 {
+			limit = l
+		}
+	}
+
+	alerts, err := h.repo.ListSLAAlerts(r.Context(), r.URL.Query().Get("open") == "true", limit)
+	if err != nil {
+		h.logger.Error("failed to list job SLA alerts", "error", err)
+		api.InternalError(w)
+		return
+	}
+	if alerts == nil {
+		alerts = []*SLAAlert{}
+	}
+	api.JSONResponse(w, http.StatusOK, map[string]any{"alerts": alerts})
+}
@@ -0,0 +1,139 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// SLA alert webhook events
+const (
+	EventSLAAlertRaised   = "job_sla.raised"
+	EventSLAAlertResolved = "job_sla.resolved"
+)
+
+// SLAMonitor periodically judges the job types against their service
+// levels, raises an alert when a type breaches one and resolves it once the
+// type is back within. Several workers may run a monitor: an alert is
+// raised, and announced, by only one of them.
+type SLAMonitor struct {
+	repo       *Repository
+	config     SLAConfig
+	interval   time.Duration
+	webhookURL string
+	client     *http.Client
+	logger     *slog.Logger
+}
+
+// NewSLAMonitor creates an SLA monitor. Alerts are posted to webhookURL
+// unless it is empty.
+func NewSLAMonitor(repo *Repository, config SLAConfig, interval time.Duration, webhookURL string, logger *slog.Logger) *SLAMonitor {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	return &SLAMonitor{
+		repo:       repo,
+		config:     config,
+		interval:   interval,
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		logger:     logger.With("component", "job_sla"),
+	}
+}
+
+// Run checks the service levels every interval until ctx is cancelled
+func (m *SLAMonitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := m.Check(ctx); err != nil && ctx.Err() == nil {
+				m.logger.Error("job SLA check failed", "error", err)
+			}
+		}
+	}
+}
+
+// Check judges the job types once, raising and resolving alerts
+func (m *SLAMonitor) Check(ctx context.Context) error {
+	stats, err := m.repo.TypeStats(ctx, time.Now().Add(-m.config.Window))
+	if err != nil {
+		return err
+	}
+
+	breached := make(map[string]bool)
+	for _, status := range m.config.Evaluate(stats) {
+		for _, b := range status.Breaches {
+			breached[b.JobType+"/"+b.Metric] = true
+			alert, err := m.repo.RaiseSLAAlert(ctx, b, m.config.Window)
+			if err != nil {
+				return err
+			}
+			if alert != nil {
+				m.logger.Error("job SLA breached",
+					"job_type", alert.JobType, "metric", alert.Metric,
+					"value", alert.Value, "threshold", alert.Threshold, "runs", alert.Runs)
+				m.notify(ctx, EventSLAAlertRaised, alert)
+			}
+		}
+	}
+
+	open, err := m.repo.ListSLAAlerts(ctx, true, 1000)
+	if err != nil {
+		return err
+	}
+	for _, a := range open {
+		if breached[a.JobType+"/"+a.Metric] {
+			continue
+		}
+		resolved, err := m.repo.ResolveSLAAlert(ctx, a.ID)
+		if err != nil {
+			return err
+		}
+		if resolved != nil {
+			m.logger.Info("job SLA restored", "job_type", resolved.JobType, "metric", resolved.Metric)
+			m.notify(ctx, EventSLAAlertResolved, resolved)
+		}
+	}
+	return nil
+}
+
+// notify posts an alert to the webhook. Failures are logged; the alert
+// stays listed by the maintenance API.
+func (m *SLAMonitor) notify(ctx context.Context, event string, alert *SLAAlert) {
+	if m.webhookURL == "" {
+		return
+	}
+	body, err := json.Marshal(map[string]any{"event": event, "alert": alert})
+	if err != nil {
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		m.logger.Error("invalid job SLA webhook", "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			err = fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		}
+	}
+	if err != nil {
+		m.logger.Error("failed to post job SLA alert", "event", event, "job_type", alert.JobType, "error", err)
+	}
+}
@@ -15,6 +15,10 @@ const (
 	StatusCompleted = "completed"
 	StatusFailed    = "failed"
 	StatusDead      = "dead"
+
+	// StatusRetried is the job history outcome of a failed attempt that
+	// runs again
+	StatusRetried = "retried"
 )
 
 // Priority levels
@@ -26,22 +30,42 @@ const (
 
 // Job types
 const (
-	TypeDataboxSync       = "databox_sync"
-	TypeDocumentAnalysis  = "document_analysis"
-	TypeDeadlineReminder  = "deadline_reminder"
-	TypeWatchlistCheck    = "watchlist_check"
-	TypeSessionCleanup    = "session_cleanup"
-	TypeWebhookDelivery   = "webhook_delivery"
-	TypeAuditArchive      = "audit_archive"
-	TypeSoftDeleteCleanup = "soft_delete_cleanup"
+	TypeDataboxSync            = "databox_sync"
+	TypeDocumentAnalysis       = "document_analysis"
+	TypeDeadlineReminder       = "deadline_reminder"
+	TypeWatchlistCheck         = "watchlist_check"
+	TypeSessionCleanup         = "session_cleanup"
+	TypeWebhookDelivery        = "webhook_delivery"
+	TypeAuditArchive           = "audit_archive"
+	TypeSoftDeleteCleanup      = "soft_delete_cleanup"
+	TypeKleinunternehmerCheck  = "kleinunternehmer_check"
+	TypeRawPayloadCleanup      = "raw_payload_cleanup"
+	TypeUsageAggregation       = "usage_aggregation"
+	TypeAnalysisTextCompaction = "analysis_text_compaction"
+	TypePDFAConversion         = "pdfa_conversion"
+	TypeSignatureStatements    = "signature_statements"
+	TypeAnomalyDetection       = "anomaly_detection"
+	TypeUIDBatch               = "uid_batch"
+	TypeContractRenewal        = "contract_renewal"
+	TypePartnerUIDRevalidation = "partner_uid_revalidation"
+	TypeFirmenbuchWatch        = "firmenbuch_watch"
+	TypeFoerderungStatusSync   = "foerderung_status_sync"
+	TypeELDARueckmeldung       = "elda_rueckmeldung"
+	TypeKommunalsteuerFristen  = "kommunalsteuer_fristen"
+	TypeKammerumlageFristen    = "kammerumlage_fristen"
+	TypeWorkflowTimers         = "workflow_timers"
+	TypeCalendarSync           = "calendar_sync"
+	TypeDocumentRetention      = "document_retention"
+	TypeDocumentIngestion      = "document_ingestion"
+	TypeNotificationDelivery   = "notification_delivery"
 )
 
 // Sync intervals
 const (
-	IntervalHourly  = "hourly"
-	Interval4Hourly = "4hourly"
-	IntervalDaily   = "daily"
-	IntervalWeekly  = "weekly"
+	IntervalHourly   = "hourly"
+	Interval4Hourly  = "4hourly"
+	IntervalDaily    = "daily"
+	IntervalWeekly   = "weekly"
 	IntervalDisabled = "disabled"
 )
 
@@ -87,38 +111,41 @@ type Schedule struct {
 
 // JobHistory represents a completed job execution
 type JobHistory struct {
-	ID          uuid.UUID       `json:"id"`
-	TenantID    uuid.UUID       `json:"tenant_id"`
-	JobID       *uuid.UUID      `json:"job_id,omitempty"`
-	ScheduleID  *uuid.UUID      `json:"schedule_id,omitempty"`
-	Type        string          `json:"type"`
-	Payload     json.RawMessage `json:"payload"`
-	Status      string          `json:"status"` // completed, failed
-	Result      json.RawMessage `json:"result,omitempty"`
-	ErrorMessage string         `json:"error_message,omitempty"`
-	StartedAt   time.Time       `json:"started_at"`
-	CompletedAt time.Time       `json:"completed_at"`
-	DurationMs  int             `json:"duration_ms"`
-	WorkerID    string          `json:"worker_id"`
-	CreatedAt   time.Time       `json:"created_at"`
+	ID           uuid.UUID       `json:"id"`
+	TenantID     uuid.UUID       `json:"tenant_id"`
+	JobID        *uuid.UUID      `json:"job_id,omitempty"`
+	ScheduleID   *uuid.UUID      `json:"schedule_id,omitempty"`
+	Type         string          `json:"type"`
+	Payload      json.RawMessage `json:"payload"`
+	Status       string          `json:"status"` // completed, retried, failed
+	Attempt      int             `json:"attempt"`
+	Result       json.RawMessage `json:"result,omitempty"`
+	ErrorMessage string          `json:"error_message,omitempty"`
+	StartedAt    time.Time       `json:"started_at"`
+	CompletedAt  time.Time       `json:"completed_at"`
+	DurationMs   int             `json:"duration_ms"`
+	WorkerID     string          `json:"worker_id"`
+	// HandlerVersion is the version of the handler that ran the attempt
+	HandlerVersion string    `json:"handler_version,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 // DeadLetter represents a permanently failed job
 type DeadLetter struct {
-	ID              uuid.UUID       `json:"id"`
-	TenantID        uuid.UUID       `json:"tenant_id"`
-	OriginalJobID   *uuid.UUID      `json:"original_job_id,omitempty"`
-	Type            string          `json:"type"`
-	Payload         json.RawMessage `json:"payload"`
-	Errors          []string        `json:"errors"`
-	MaxRetries      int             `json:"max_retries"`
-	TotalAttempts   int             `json:"total_attempts"`
-	FirstAttemptedAt time.Time      `json:"first_attempted_at"`
-	LastAttemptedAt  time.Time      `json:"last_attempted_at"`
-	Acknowledged    bool            `json:"acknowledged"`
-	AcknowledgedBy  *uuid.UUID      `json:"acknowledged_by,omitempty"`
-	AcknowledgedAt  *time.Time      `json:"acknowledged_at,omitempty"`
-	CreatedAt       time.Time       `json:"created_at"`
+	ID               uuid.UUID       `json:"id"`
+	TenantID         uuid.UUID       `json:"tenant_id"`
+	OriginalJobID    *uuid.UUID      `json:"original_job_id,omitempty"`
+	Type             string          `json:"type"`
+	Payload          json.RawMessage `json:"payload"`
+	Errors           []string        `json:"errors"`
+	MaxRetries       int             `json:"max_retries"`
+	TotalAttempts    int             `json:"total_attempts"`
+	FirstAttemptedAt time.Time       `json:"first_attempted_at"`
+	LastAttemptedAt  time.Time       `json:"last_attempted_at"`
+	Acknowledged     bool            `json:"acknowledged"`
+	AcknowledgedBy   *uuid.UUID      `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt   *time.Time      `json:"acknowledged_at,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
 }
 
 // Handler is the interface that job handlers must implement
@@ -127,6 +154,13 @@ type Handler interface {
 	Handle(ctx context.Context, job *Job) (json.RawMessage, error)
 }
 
+// Versioned is implemented by handlers that report their own version. The
+// job history records it with every attempt; other handlers are recorded
+// with the version of the worker.
+type Versioned interface {
+	Version() string
+}
+
 // HandlerFunc is an adapter to allow use of ordinary functions as job handlers
 type HandlerFunc func(ctx context.Context, job *Job) (json.RawMessage, error)
 
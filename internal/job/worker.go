@@ -18,6 +18,7 @@ type Worker struct {
 	concurrency  int
 	pollInterval time.Duration
 	shutdownTimeout time.Duration
+	version      string
 	logger       *slog.Logger
 
 	// Metrics
@@ -37,6 +38,9 @@ type WorkerConfig struct {
 	Concurrency     int
 	PollInterval    time.Duration
 	ShutdownTimeout time.Duration
+	// Version is recorded in the job history for handlers that do not
+	// implement Versioned, usually the build version of the worker
+	Version         string
 	Logger          *slog.Logger
 }
 
@@ -47,6 +51,7 @@ func NewWorker(queue *Queue, registry *Registry, cfg *WorkerConfig) *Worker {
 	shutdownTimeout := 30 * time.Second
 	logger := slog.Default()
 	id := "worker"
+	version := ""
 
 	if cfg != nil {
 		if cfg.Concurrency > 0 {
@@ -64,6 +69,7 @@ func NewWorker(queue *Queue, registry *Registry, cfg *WorkerConfig) *Worker {
 		if cfg.ID != "" {
 			id = cfg.ID
 		}
+		version = cfg.Version
 	}
 
 	return &Worker{
@@ -73,6 +79,7 @@ func NewWorker(queue *Queue, registry *Registry, cfg *WorkerConfig) *Worker {
 		concurrency:     concurrency,
 		pollInterval:    pollInterval,
 		shutdownTimeout: shutdownTimeout,
+		version:         version,
 		logger:          logger,
 	}
 }
@@ -178,7 +185,7 @@ func (w *Worker) processJob(ctx context.Context, job *Job) {
 	handler, err := w.registry.Get(job.Type)
 	if err != nil {
 		logger.Error("no handler for job type", "error", err)
-		if err := w.queue.Fail(ctx, job.ID, fmt.Sprintf("no handler for job type: %s", job.Type)); err != nil {
+		if err := w.queue.Fail(ctx, job.ID, fmt.Sprintf("no handler for job type: %s", job.Type), w.version); err != nil {
 			logger.Error("failed to mark job as failed", "error", err)
 		}
 		w.jobsFailed.Add(1)
@@ -207,6 +214,14 @@ func (w *Worker) processJob(ctx context.Context, job *Job) {
 	duration := time.Since(startTime)
 	w.jobsProcessed.Add(1)
 
+	var later RetryLater
+	if errors.As(execErr, &later) {
+		if err := w.queue.Defer(ctx, job.ID, later.RetryAt(), execErr.Error()); err != nil {
+			logger.Error("failed to defer job", "error", err)
+		}
+		return
+	}
+
 	if execErr != nil {
 		logger.Error("job failed",
 			"error", execErr,
@@ -214,7 +229,7 @@ func (w *Worker) processJob(ctx context.Context, job *Job) {
 			"retry_count", job.RetryCount+1,
 			"max_retries", job.MaxRetries)
 
-		if err := w.queue.Fail(ctx, job.ID, execErr.Error()); err != nil {
+		if err := w.queue.Fail(ctx, job.ID, execErr.Error(), w.handlerVersion(handler)); err != nil {
 			logger.Error("failed to mark job as failed", "error", err)
 		}
 		w.jobsFailed.Add(1)
@@ -222,7 +237,7 @@ func (w *Worker) processJob(ctx context.Context, job *Job) {
 	}
 
 	// Mark job as completed
-	if err := w.queue.Complete(ctx, job.ID, result); err != nil {
+	if err := w.queue.Complete(ctx, job.ID, result, w.handlerVersion(handler)); err != nil {
 		logger.Error("failed to mark job as completed", "error", err)
 		w.jobsFailed.Add(1)
 		return
@@ -232,6 +247,17 @@ func (w *Worker) processJob(ctx context.Context, job *Job) {
 	logger.Info("job completed", "duration", duration)
 }
 
+// handlerVersion returns the version of a handler, or the worker version
+// for handlers that do not report one
+func (w *Worker) handlerVersion(handler Handler) string {
+	if v, ok := handler.(Versioned); ok {
+		if version := v.Version(); version != "" {
+			return version
+		}
+	}
+	return w.version
+}
+
 // Status returns the current worker status
 func (w *Worker) Status() string {
 	if w.running.Load() {
@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"austrian-business-infrastructure/internal/analysis"
+	"austrian-business-infrastructure/internal/job"
+)
+
+// Default limits of a text compaction run
+const (
+	defaultTextCompactionBatchSize  = 100
+	defaultTextCompactionMaxBatches = 50
+)
+
+// AnalysisTextCompactionHandler moves large and old extracted analysis texts
+// into the compressed side table. Run daily it also backfills analyses that
+// were stored before compaction existed.
+type AnalysisTextCompactionHandler struct {
+	repo   *analysis.Repository
+	logger *slog.Logger
+}
+
+// NewAnalysisTextCompactionHandler creates a new analysis text compaction handler
+func NewAnalysisTextCompactionHandler(repo *analysis.Repository, logger *slog.Logger) *AnalysisTextCompactionHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &AnalysisTextCompactionHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// AnalysisTextCompactionPayload defines the job payload
+type AnalysisTextCompactionPayload struct {
+	BatchSize  int `json:"batch_size,omitempty"`  // Rows per transaction, default 100
+	MaxBatches int `json:"max_batches,omitempty"` // Batches per run, default 50
+}
+
+// Handle executes the analysis text compaction job
+func (h *AnalysisTextCompactionHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	payload := AnalysisTextCompactionPayload{
+		BatchSize:  defaultTextCompactionBatchSize,
+		MaxBatches: defaultTextCompactionMaxBatches,
+	}
+	if len(j.Payload) > 0 {
+		if err := json.Unmarshal(j.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("parse payload: %w", err)
+		}
+	}
+	if payload.BatchSize <= 0 {
+		payload.BatchSize = defaultTextCompactionBatchSize
+	}
+	if payload.MaxBatches <= 0 {
+		payload.MaxBatches = defaultTextCompactionMaxBatches
+	}
+
+	before := time.Now().AddDate(0, 0, -analysis.TextCompactAfterDays)
+	total := &analysis.TextCompactionResult{}
+	for i := 0; i < payload.MaxBatches; i++ {
+		result, err := h.repo.CompactTexts(ctx, before, payload.BatchSize)
+		if err != nil {
+			h.logger.Error("analysis text compaction failed", "error", err, "compacted", total.Compacted)
+			return nil, err
+		}
+		total.Compacted += result.Compacted
+		total.OriginalBytes += result.OriginalBytes
+		total.CompressedBytes += result.CompressedBytes
+		if result.Compacted < payload.BatchSize {
+			break
+		}
+	}
+
+	h.logger.Info("analysis text compaction completed", "job_id", j.ID, "compacted", total.Compacted,
+		"original_bytes", total.OriginalBytes, "compressed_bytes", total.CompressedBytes)
+	return json.Marshal(total)
+}
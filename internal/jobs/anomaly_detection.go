@@ -0,0 +1,71 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"austrian-business-infrastructure/internal/anomaly"
+	"austrian-business-infrastructure/internal/job"
+	"github.com/google/uuid"
+)
+
+// AnomalyDetectionHandler scans payments, invoices and bank lines for anomalies
+type AnomalyDetectionHandler struct {
+	service *anomaly.Service
+	logger  *slog.Logger
+}
+
+// NewAnomalyDetectionHandler creates a new anomaly detection handler
+func NewAnomalyDetectionHandler(service *anomaly.Service, logger *slog.Logger) *AnomalyDetectionHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &AnomalyDetectionHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// AnomalyDetectionPayload defines the job payload
+type AnomalyDetectionPayload struct {
+	TenantID *uuid.UUID `json:"tenant_id,omitempty"` // Optional: specific tenant
+}
+
+// AnomalyDetectionResult contains the results of a detection run
+type AnomalyDetectionResult struct {
+	FindingsRaised int                 `json:"findings_raised"`
+	Scan           *anomaly.ScanResult `json:"scan,omitempty"` // Only set for single-tenant runs
+}
+
+// Handle executes the anomaly detection job
+func (h *AnomalyDetectionHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	var payload AnomalyDetectionPayload
+	if len(j.Payload) > 0 {
+		if err := json.Unmarshal(j.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("parse payload: %w", err)
+		}
+	}
+
+	var result AnomalyDetectionResult
+
+	if payload.TenantID != nil {
+		scan, err := h.service.Scan(ctx, *payload.TenantID)
+		if err != nil {
+			return nil, fmt.Errorf("scan tenant: %w", err)
+		}
+		result.Scan = scan
+		result.FindingsRaised = scan.Raised
+	} else {
+		raised, err := h.service.ScanAll(ctx)
+		result.FindingsRaised = raised
+		if err != nil {
+			// Partial failures are logged; findings for other tenants were raised
+			h.logger.Error("anomaly detection failed for some tenants", "error", err)
+		}
+	}
+
+	h.logger.Info("anomaly detection completed", "job_id", j.ID, "findings_raised", result.FindingsRaised)
+	return json.Marshal(result)
+}
@@ -18,10 +18,17 @@ import (
 // AuditArchive is the job type for archiving old audit logs
 const AuditArchiveJobType = "audit_archive"
 
+// AuditRetention returns how long a tenant's audit logs stay in the
+// database; *audit.Policy implements it
+type AuditRetention interface {
+	RetentionDays(ctx context.Context, tenantID uuid.UUID) (int, error)
+}
+
 // AuditArchiveHandler handles audit log archiving
 type AuditArchiveHandler struct {
 	auditRepo     *audit.Repository
 	storageClient storage.Client
+	retention     AuditRetention
 	logger        *slog.Logger
 	retentionDays int
 	batchSize     int
@@ -30,8 +37,9 @@ type AuditArchiveHandler struct {
 // AuditArchiveConfig holds configuration for the audit archive handler
 type AuditArchiveConfig struct {
 	Logger        *slog.Logger
-	RetentionDays int // How long to keep logs before archiving (default: 90)
-	BatchSize     int // How many logs to process per batch (default: 1000)
+	Retention     AuditRetention // Per-tenant retention; falls back to RetentionDays
+	RetentionDays int            // How long to keep logs before archiving (default: 90)
+	BatchSize     int            // How many logs to process per batch (default: 1000)
 }
 
 // NewAuditArchiveHandler creates a new audit archive handler
@@ -43,11 +51,13 @@ func NewAuditArchiveHandler(
 	logger := slog.Default()
 	retentionDays := 90
 	batchSize := 1000
+	var retention AuditRetention
 
 	if cfg != nil {
 		if cfg.Logger != nil {
 			logger = cfg.Logger
 		}
+		retention = cfg.Retention
 		if cfg.RetentionDays > 0 {
 			retentionDays = cfg.RetentionDays
 		}
@@ -59,6 +69,7 @@ func NewAuditArchiveHandler(
 	return &AuditArchiveHandler{
 		auditRepo:     auditRepo,
 		storageClient: storageClient,
+		retention:     retention,
 		logger:        logger,
 		retentionDays: retentionDays,
 		batchSize:     batchSize,
@@ -68,7 +79,7 @@ func NewAuditArchiveHandler(
 // AuditArchivePayload defines the job payload
 type AuditArchivePayload struct {
 	TenantID      *uuid.UUID `json:"tenant_id,omitempty"`      // Optional: specific tenant
-	RetentionDays *int       `json:"retention_days,omitempty"` // Override default retention
+	RetentionDays *int       `json:"retention_days,omitempty"` // Override the tenants' retention
 }
 
 // AuditArchiveResult contains the results of an archive operation
@@ -82,11 +93,12 @@ type AuditArchiveResult struct {
 
 // TenantResult contains results for a single tenant
 type TenantResult struct {
-	TenantID     string `json:"tenant_id"`
-	Archived     int64  `json:"archived"`
-	Deleted      int64  `json:"deleted"`
-	ArchiveFile  string `json:"archive_file,omitempty"`
-	ErrorMessage string `json:"error_message,omitempty"`
+	TenantID      string `json:"tenant_id"`
+	RetentionDays int    `json:"retention_days"`
+	Archived      int64  `json:"archived"`
+	Deleted       int64  `json:"deleted"`
+	ArchiveFile   string `json:"archive_file,omitempty"`
+	ErrorMessage  string `json:"error_message,omitempty"`
 }
 
 // Handle executes the audit archive job
@@ -101,19 +113,11 @@ func (h *AuditArchiveHandler) Handle(ctx context.Context, j *job.Job) (json.RawM
 		}
 	}
 
-	// Determine retention period
-	retentionDays := h.retentionDays
-	if payload.RetentionDays != nil {
-		retentionDays = *payload.RetentionDays
-	}
-
-	olderThan := time.Now().AddDate(0, 0, -retentionDays)
-
 	var result AuditArchiveResult
 
 	if payload.TenantID != nil {
 		// Archive for specific tenant
-		tenantResult := h.archiveTenant(ctx, *payload.TenantID, olderThan)
+		tenantResult := h.archiveTenant(ctx, *payload.TenantID, h.tenantRetention(ctx, *payload.TenantID, payload.RetentionDays))
 		result.TenantsProcessed = 1
 		result.TotalArchived = tenantResult.Archived
 		result.TotalDeleted = tenantResult.Deleted
@@ -129,7 +133,7 @@ func (h *AuditArchiveHandler) Handle(ctx context.Context, j *job.Job) (json.RawM
 		}
 
 		for _, tenantID := range tenantIDs {
-			tenantResult := h.archiveTenant(ctx, tenantID, olderThan)
+			tenantResult := h.archiveTenant(ctx, tenantID, h.tenantRetention(ctx, tenantID, payload.RetentionDays))
 			result.TenantsProcessed++
 			result.TotalArchived += tenantResult.Archived
 			result.TotalDeleted += tenantResult.Deleted
@@ -148,11 +152,38 @@ func (h *AuditArchiveHandler) Handle(ctx context.Context, j *job.Job) (json.RawM
 	return json.Marshal(result)
 }
 
-// archiveTenant archives audit logs for a single tenant
-func (h *AuditArchiveHandler) archiveTenant(ctx context.Context, tenantID uuid.UUID, olderThan time.Time) TenantResult {
+// tenantRetention returns the retention in days for a tenant: the payload
+// override, else the tenant's setting, else the default
+func (h *AuditArchiveHandler) tenantRetention(ctx context.Context, tenantID uuid.UUID, override *int) int {
+	if override != nil {
+		return *override
+	}
+	if h.retention == nil {
+		return h.retentionDays
+	}
+	days, err := h.retention.RetentionDays(ctx, tenantID)
+	if err != nil || days <= 0 {
+		// Keeping logs longer is the safe side of a failed lookup
+		h.logger.Warn("failed to get audit retention, skipping tenant",
+			"tenant_id", tenantID,
+			"error", err)
+		return 0
+	}
+	return days
+}
+
+// archiveTenant archives audit logs for a single tenant that are older than
+// its retention. A retention of zero skips the tenant.
+func (h *AuditArchiveHandler) archiveTenant(ctx context.Context, tenantID uuid.UUID, retentionDays int) TenantResult {
 	result := TenantResult{
-		TenantID: tenantID.String(),
+		TenantID:      tenantID.String(),
+		RetentionDays: retentionDays,
+	}
+	if retentionDays <= 0 {
+		result.ErrorMessage = "retention unknown"
+		return result
 	}
+	olderThan := time.Now().AddDate(0, 0, -retentionDays)
 
 	// Count logs to archive
 	count, err := h.auditRepo.CountOlderThan(ctx, tenantID, olderThan)
@@ -229,10 +260,11 @@ func (h *AuditArchiveHandler) exportLogs(ctx context.Context, tenantID uuid.UUID
 		}
 
 		first := true
-		offset := 0
+		var afterCreatedAt time.Time
+		afterID := uuid.Nil
 
 		for {
-			logs, err := h.auditRepo.ListForArchive(ctx, tenantID, olderThan, h.batchSize)
+			logs, err := h.auditRepo.ListForArchive(ctx, tenantID, olderThan, afterCreatedAt, afterID, h.batchSize)
 			if err != nil {
 				errChan <- err
 				return
@@ -258,7 +290,8 @@ func (h *AuditArchiveHandler) exportLogs(ctx context.Context, tenantID uuid.UUID
 				totalCount++
 			}
 
-			offset += len(logs)
+			last := logs[len(logs)-1]
+			afterCreatedAt, afterID = last.CreatedAt, last.ID
 			if len(logs) < h.batchSize {
 				break
 			}
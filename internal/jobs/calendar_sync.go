@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"austrian-business-infrastructure/internal/calendarsync"
+	"austrian-business-infrastructure/internal/job"
+)
+
+// CalendarSyncResult is the result of a calendar sync job
+type CalendarSyncResult struct {
+	Synced int `json:"synced"`
+	Failed int `json:"failed"`
+}
+
+// CalendarSyncHandler pushes deadlines and action items to the connected
+// Google and Microsoft 365 calendars. Schedule it hourly.
+type CalendarSyncHandler struct {
+	service *calendarsync.Service
+	logger  *slog.Logger
+}
+
+// NewCalendarSyncHandler creates a new calendar sync handler
+func NewCalendarSyncHandler(service *calendarsync.Service, logger *slog.Logger) *CalendarSyncHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &CalendarSyncHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Handle executes the calendar sync job
+func (h *CalendarSyncHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	synced, failed, err := h.service.SyncAll(ctx)
+	if err != nil {
+		h.logger.Error("failed to sync calendars", "job_id", j.ID, "error", err)
+	}
+
+	h.logger.Info("calendars synced", "job_id", j.ID, "synced", synced, "failed", failed)
+	return json.Marshal(CalendarSyncResult{Synced: synced, Failed: failed})
+}
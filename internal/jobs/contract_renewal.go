@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"austrian-business-infrastructure/internal/contract"
+	"austrian-business-infrastructure/internal/job"
+)
+
+// ContractRenewalHandler rolls contracts whose last day to cancel has passed
+// into their next term, with a new cancellation deadline, and expires
+// contracts whose term ended
+type ContractRenewalHandler struct {
+	service *contract.Service
+	logger  *slog.Logger
+}
+
+// NewContractRenewalHandler creates a new contract renewal handler
+func NewContractRenewalHandler(service *contract.Service, logger *slog.Logger) *ContractRenewalHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ContractRenewalHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Handle executes the contract renewal job
+func (h *ContractRenewalHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	result, err := h.service.Renew(ctx)
+	if result == nil {
+		return nil, err
+	}
+	if err != nil {
+		// Partial failures are logged; the other contracts were rolled over
+		h.logger.Error("contract renewal failed for some contracts", "error", err)
+	}
+
+	h.logger.Info("contract renewal completed", "job_id", j.ID, "renewed", result.Renewed, "expired", result.Expired)
+	return json.Marshal(result)
+}
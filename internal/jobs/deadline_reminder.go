@@ -11,6 +11,7 @@ import (
 	"austrian-business-infrastructure/internal/document"
 	"austrian-business-infrastructure/internal/email"
 	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/timezone"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -37,6 +38,7 @@ type DeadlineReminderHandler struct {
 	docRepo             *document.Repository
 	emailSvc            email.Service
 	notificationService *analysis.NotificationService
+	timezones           timezone.Source
 	logger              *slog.Logger
 	appURL              string
 
@@ -50,6 +52,10 @@ type DeadlineReminderHandlerConfig struct {
 	AppURL              string
 	ReminderDays        []int
 	NotificationService *analysis.NotificationService
+
+	// Timezones gives the zone a tenant's deadline days are counted in;
+	// Europe/Vienna if nil
+	Timezones timezone.Source
 }
 
 // NewDeadlineReminderHandler creates a new deadline reminder handler
@@ -63,6 +69,7 @@ func NewDeadlineReminderHandler(
 	appURL := "http://localhost:3000"
 	reminderDays := []int{7, 3, 1}
 	var notifSvc *analysis.NotificationService
+	var timezones timezone.Source
 
 	if cfg != nil {
 		if cfg.Logger != nil {
@@ -77,6 +84,7 @@ func NewDeadlineReminderHandler(
 		if cfg.NotificationService != nil {
 			notifSvc = cfg.NotificationService
 		}
+		timezones = cfg.Timezones
 	}
 
 	return &DeadlineReminderHandler{
@@ -84,6 +92,7 @@ func NewDeadlineReminderHandler(
 		docRepo:             docRepo,
 		emailSvc:            emailSvc,
 		notificationService: notifSvc,
+		timezones:           timezones,
 		logger:              logger,
 		appURL:              appURL,
 		defaultReminderDays: reminderDays,
@@ -115,12 +124,14 @@ func (h *DeadlineReminderHandler) Handle(ctx context.Context, j *job.Job) (json.
 
 	result := &DeadlineReminderResult{}
 
-	// Get documents with upcoming deadlines
-	now := time.Now()
+	// Get documents with upcoming deadlines. Document deadlines are
+	// instants; a deadline is due on a day of the tenant's time zone.
+	loc := timezone.Of(ctx, h.timezones, payload.TenantID)
+	today := timezone.Today(time.Now(), loc)
 	for _, days := range reminderDays {
-		deadline := now.AddDate(0, 0, days)
-		deadlineStart := time.Date(deadline.Year(), deadline.Month(), deadline.Day(), 0, 0, 0, 0, time.UTC)
-		deadlineEnd := deadlineStart.Add(24 * time.Hour)
+		day := today.AddDate(0, 0, days)
+		deadlineStart := timezone.DayStart(day, loc)
+		deadlineEnd := timezone.DayStart(day.AddDate(0, 0, 1), loc)
 
 		docs, err := h.getDocumentsWithDeadline(ctx, payload.TenantID, deadlineStart, deadlineEnd, days)
 		if err != nil {
@@ -132,7 +143,7 @@ func (h *DeadlineReminderHandler) Handle(ctx context.Context, j *job.Job) (json.
 		result.DocumentsChecked += len(docs)
 
 		for _, doc := range docs {
-			if err := h.sendReminder(ctx, doc, days); err != nil {
+			if err := h.sendReminder(ctx, doc, days, loc); err != nil {
 				logger.Error("failed to send reminder",
 					"document_id", doc.ID,
 					"days", days,
@@ -251,12 +262,12 @@ func (h *DeadlineReminderHandler) getDocumentsWithDeadline(
 }
 
 // sendReminder sends a deadline reminder email
-func (h *DeadlineReminderHandler) sendReminder(ctx context.Context, doc *document.Document, daysRemaining int) error {
+func (h *DeadlineReminderHandler) sendReminder(ctx context.Context, doc *document.Document, daysRemaining int, loc *time.Location) error {
 	// TODO: Get user emails for the tenant/account
 	// For now, this is a placeholder
 
 	subject := fmt.Sprintf("Frist-Erinnerung: %s (%d Tage)", doc.Title, daysRemaining)
-	body := h.buildReminderBody(doc, daysRemaining)
+	body := h.buildReminderBody(doc, daysRemaining, loc)
 
 	h.logger.Info("sending deadline reminder",
 		"document_id", doc.ID,
@@ -275,8 +286,9 @@ func (h *DeadlineReminderHandler) sendReminder(ctx context.Context, doc *documen
 	return nil
 }
 
-// buildReminderBody creates the reminder email body
-func (h *DeadlineReminderHandler) buildReminderBody(doc *document.Document, daysRemaining int) string {
+// buildReminderBody creates the reminder email body, with the deadline as a
+// date of the tenant's time zone
+func (h *DeadlineReminderHandler) buildReminderBody(doc *document.Document, daysRemaining int, loc *time.Location) string {
 	urgency := "Erinnerung"
 	if daysRemaining == 1 {
 		urgency = "DRINGEND"
@@ -286,7 +298,7 @@ func (h *DeadlineReminderHandler) buildReminderBody(doc *document.Document, days
 
 	deadlineStr := ""
 	if doc.Deadline != nil {
-		deadlineStr = doc.Deadline.Format("02.01.2006")
+		deadlineStr = doc.Deadline.In(loc).Format("02.01.2006")
 	}
 
 	return fmt.Sprintf(`%s: Frist in %d Tag(en)
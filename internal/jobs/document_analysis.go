@@ -3,6 +3,7 @@ package jobs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -33,6 +34,7 @@ type DocumentAnalysisResult struct {
 	Suggestions     int        `json:"suggestions"`
 	ProcessingTimeMs int       `json:"processing_time_ms"`
 	Success         bool       `json:"success"`
+	Skipped         bool       `json:"skipped,omitempty"` // not covered by the tenant's AI consent
 	Error           string     `json:"error,omitempty"`
 }
 
@@ -137,6 +139,18 @@ func (h *DocumentAnalysisHandler) Handle(ctx context.Context, j *job.Job) (json.
 		ProcessingTimeMs: int(time.Since(startTime).Milliseconds()),
 	}
 
+	if errors.Is(err, analysis.ErrConsentDenied) {
+		// Not retried: the analysis is recorded as skipped until the tenant
+		// changes its consent
+		result.Success = false
+		result.Skipped = true
+		result.Error = err.Error()
+		logger.Info("document analysis skipped", "reason", err)
+
+		resultJSON, _ := json.Marshal(result)
+		return resultJSON, nil
+	}
+
 	if err != nil {
 		result.Success = false
 		result.Error = err.Error()
@@ -288,3 +302,31 @@ func CreateAnalysisSchedule(ctx context.Context, scheduler *job.Scheduler, tenan
 
 	return scheduler.CreateSchedule(ctx, schedule)
 }
+
+// AnalysisScheduler queues document analysis jobs; it is the analysis
+// scheduler of the document service for uploaded e-mails
+type AnalysisScheduler struct {
+	queue *job.Queue
+}
+
+// NewAnalysisScheduler creates a new analysis scheduler
+func NewAnalysisScheduler(queue *job.Queue) *AnalysisScheduler {
+	return &AnalysisScheduler{queue: queue}
+}
+
+// SupportsAnalysis reports whether text can be extracted from documents of
+// the MIME type
+func (s *AnalysisScheduler) SupportsAnalysis(mimeType string) bool {
+	return analysis.CanExtractText(mimeType)
+}
+
+// ScheduleAnalysis queues the analysis of a document at normal priority
+func (s *AnalysisScheduler) ScheduleAnalysis(ctx context.Context, tenantID, documentID uuid.UUID) error {
+	payload := DocumentAnalysisPayload{
+		DocumentID: documentID,
+		TenantID:   tenantID,
+		Priority:   "normal",
+	}
+	_, err := s.queue.Enqueue(ctx, tenantID, job.TypeDocumentAnalysis, payload, job.DefaultEnqueueOptions())
+	return err
+}
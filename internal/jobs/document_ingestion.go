@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/ingest"
+	"austrian-business-infrastructure/internal/job"
+)
+
+// DocumentIngestionResult is the result of a document ingestion job
+type DocumentIngestionResult struct {
+	ItemID     *uuid.UUID `json:"item_id,omitempty"`
+	Status     string     `json:"status"`
+	DocumentID *uuid.UUID `json:"document_id,omitempty"`
+}
+
+// DocumentIngestionHandler turns an item submitted for ingestion into a
+// document. A job is queued per item, but processes whichever item is
+// next, so the sources submitting items are served in turn rather than in
+// the order of their jobs.
+type DocumentIngestionHandler struct {
+	service *ingest.Service
+	logger  *slog.Logger
+}
+
+// NewDocumentIngestionHandler creates a new document ingestion handler
+func NewDocumentIngestionHandler(service *ingest.Service, logger *slog.Logger) *DocumentIngestionHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &DocumentIngestionHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Handle executes the document ingestion job
+func (h *DocumentIngestionHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	item, err := h.service.ProcessNext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return json.Marshal(DocumentIngestionResult{Status: "idle"})
+	}
+
+	logger := h.logger.With("job_id", j.ID, "item_id", item.ID, "tenant_id", item.TenantID)
+	switch item.Status {
+	case ingest.StatusFailed:
+		logger.Warn("document ingestion failed", "attempts", item.Attempts, "error", *item.Error)
+	case ingest.StatusQueued:
+		logger.Info("document ingestion retried later", "attempts", item.Attempts)
+	default:
+		logger.Debug("document ingested", "status", item.Status, "document_id", item.DocumentID)
+	}
+	return json.Marshal(DocumentIngestionResult{ItemID: &item.ID, Status: item.Status, DocumentID: item.DocumentID})
+}
+
+// IngestionScheduler queues document ingestion jobs; it is the scheduler
+// of the ingestion service
+type IngestionScheduler struct {
+	queue *job.Queue
+}
+
+// NewIngestionScheduler creates a new ingestion scheduler
+func NewIngestionScheduler(queue *job.Queue) *IngestionScheduler {
+	return &IngestionScheduler{queue: queue}
+}
+
+// ScheduleIngestion queues an ingestion job. Ingestion runs at low
+// priority, so that bulk pushes leave interactive work first.
+func (s *IngestionScheduler) ScheduleIngestion(ctx context.Context, tenantID uuid.UUID, runAt time.Time) error {
+	opts := job.DefaultEnqueueOptions()
+	opts.Priority = job.PriorityLow
+	opts.RunAt = runAt
+	opts.TimeoutSeconds = 600
+	_, err := s.queue.Enqueue(ctx, tenantID, job.TypeDocumentIngestion, struct{}{}, opts)
+	return err
+}
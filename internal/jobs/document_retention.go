@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/retention"
+)
+
+// DocumentRetentionResult is the result of a document retention job
+type DocumentRetentionResult struct {
+	Tenants   int `json:"tenants"`
+	Protocols int `json:"protocols"`
+	Deleted   int `json:"deleted"`
+	Failed    int `json:"failed"`
+}
+
+// DocumentRetentionHandler deletes documents past their retention date and
+// stores a signed deletion protocol per tenant and run. Schedule it daily.
+type DocumentRetentionHandler struct {
+	service *retention.Service
+	logger  *slog.Logger
+}
+
+// NewDocumentRetentionHandler creates a new document retention handler
+func NewDocumentRetentionHandler(service *retention.Service, logger *slog.Logger) *DocumentRetentionHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &DocumentRetentionHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Handle executes the document retention job
+func (h *DocumentRetentionHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	tenants, err := h.service.TenantsDue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := DocumentRetentionResult{Tenants: len(tenants)}
+	for _, tenantID := range tenants {
+		protocol, err := h.service.Run(ctx, tenantID)
+		if protocol != nil {
+			result.Protocols++
+			result.Deleted += protocol.DeletedCount
+			result.Failed += protocol.FailedCount
+			h.logger.Info("retention deletion protocol stored", "job_id", j.ID, "tenant_id", tenantID,
+				"protocol_id", protocol.ID, "deleted", protocol.DeletedCount, "failed", protocol.FailedCount)
+		}
+		if err != nil {
+			h.logger.Error("retention deletion failed", "job_id", j.ID, "tenant_id", tenantID, "error", err)
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+
+	h.logger.Info("retention deletion completed", "job_id", j.ID, "tenants", result.Tenants,
+		"deleted", result.Deleted, "failed", result.Failed)
+	return json.Marshal(result)
+}
@@ -0,0 +1,91 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/eldameldung"
+	"austrian-business-infrastructure/internal/job"
+)
+
+// ELDARueckmeldungResult is the result of an ELDA Rückmeldung poll job
+type ELDARueckmeldungResult struct {
+	Polled   int `json:"polled"`
+	Accepted int `json:"accepted"`
+	Rejected int `json:"rejected"`
+	Pending  int `json:"pending"`
+	Failed   int `json:"failed"`
+}
+
+// ELDARueckmeldungHandler polls ELDA for the Protokolle of submitted
+// meldungen, moves them to accepted or rejected and mails the creator of a
+// rejected meldung its error codes. Schedule it every few minutes; each
+// meldung is polled once per interval until ELDA processed it or it is
+// older than the maximum age. During a maintenance window the job waits
+// without using an attempt.
+type ELDARueckmeldungHandler struct {
+	service   *eldameldung.Service
+	interval  time.Duration
+	maxAge    time.Duration
+	batchSize int
+	logger    *slog.Logger
+}
+
+// NewELDARueckmeldungHandler creates a new ELDA Rückmeldung poll handler
+func NewELDARueckmeldungHandler(service *eldameldung.Service, interval, maxAge time.Duration, batchSize int, logger *slog.Logger) *ELDARueckmeldungHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ELDARueckmeldungHandler{
+		service:   service,
+		interval:  interval,
+		maxAge:    maxAge,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// Handle executes the ELDA Rückmeldung poll job
+func (h *ELDARueckmeldungHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	due, err := h.service.DueRueckmeldungen(ctx, h.interval, h.maxAge, h.batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ELDARueckmeldungResult
+	for _, meldung := range due {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		logger := h.logger.With("job_id", j.ID, "tenant_id", meldung.TenantID, "meldung_id", meldung.ID)
+
+		status, err := h.service.PollRueckmeldung(ctx, meldung)
+		if err != nil {
+			var later job.RetryLater
+			if errors.As(err, &later) {
+				return nil, err
+			}
+			logger.Error("ELDA Rückmeldung poll failed", "protokollnummer", meldung.Protokollnummer, "error", err)
+			result.Failed++
+			continue
+		}
+
+		result.Polled++
+		switch status {
+		case elda.MeldungStatusAccepted:
+			result.Accepted++
+		case elda.MeldungStatusRejected:
+			result.Rejected++
+		default:
+			result.Pending++
+		}
+	}
+
+	h.logger.Info("ELDA Rückmeldung poll completed", "job_id", j.ID, "polled", result.Polled,
+		"accepted", result.Accepted, "rejected", result.Rejected, "failed", result.Failed)
+	return json.Marshal(result)
+}
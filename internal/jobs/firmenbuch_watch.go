@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"austrian-business-infrastructure/internal/firmenbuch"
+	"austrian-business-infrastructure/internal/job"
+)
+
+// FirmenbuchWatchResult is the result of a Firmenbuch watchlist check job
+type FirmenbuchWatchResult struct {
+	Checked int      `json:"checked"`
+	Changed int      `json:"changed"`
+	Changes int      `json:"changes"`
+	Failed  int      `json:"failed"`
+	Failing []string `json:"failing,omitempty"` // FNs that could not be checked
+}
+
+// FirmenbuchWatchHandler fetches the current extracts of the companies on
+// the tenants' Firmenbuch watchlists that are due, stores the changes of
+// Geschäftsführer, capital, seat and status in the company history and
+// announces them by webhook and mail. Schedule it hourly; each company is
+// checked once per interval, the batch size spreading large watchlists over
+// several runs.
+type FirmenbuchWatchHandler struct {
+	service   *firmenbuch.Service
+	interval  time.Duration
+	batchSize int
+	logger    *slog.Logger
+}
+
+// NewFirmenbuchWatchHandler creates a new Firmenbuch watchlist check handler
+func NewFirmenbuchWatchHandler(service *firmenbuch.Service, interval time.Duration, batchSize int, logger *slog.Logger) *FirmenbuchWatchHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &FirmenbuchWatchHandler{
+		service:   service,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// Handle executes the Firmenbuch watchlist check job
+func (h *FirmenbuchWatchHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	entries, err := h.service.DueWatchlistEntries(ctx, h.interval, h.batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var result FirmenbuchWatchResult
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		logger := h.logger.With("job_id", j.ID, "tenant_id", entry.TenantID, "fn", entry.FN)
+
+		changes, err := h.service.CheckEntry(ctx, entry)
+		if err != nil {
+			logger.Error("Firmenbuch watchlist check failed", "error", err)
+			result.Failed++
+			result.Failing = append(result.Failing, entry.FN)
+			continue
+		}
+
+		result.Checked++
+		if len(changes) == 0 {
+			continue
+		}
+		result.Changed++
+		result.Changes += len(changes)
+		if err := h.service.NotifyChanges(ctx, entry, changes); err != nil {
+			logger.Error("failed to announce Firmenbuch changes", "error", err)
+		}
+	}
+
+	h.logger.Info("Firmenbuch watchlist check completed", "job_id", j.ID, "checked", result.Checked,
+		"changed", result.Changed, "failed", result.Failed)
+	return json.Marshal(result)
+}
@@ -0,0 +1,57 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"austrian-business-infrastructure/internal/foerderung"
+	"austrian-business-infrastructure/internal/job"
+)
+
+// FoerderungStatusSyncResult is the result of a Förderung status sync job
+type FoerderungStatusSyncResult struct {
+	Closed   int `json:"closed"`
+	Flagged  int `json:"flagged"`
+	Notified int `json:"notified"`
+}
+
+// FoerderungStatusSyncHandler closes the Förderungen whose Einreichfrist
+// passed, flags the programs whose StandDatum is too old for review and
+// mails the watchers of status changes, including the changes made by
+// admins and imports since the last run. Schedule it nightly. A failed
+// notification is logged; the changes are not mailed again.
+type FoerderungStatusSyncHandler struct {
+	sync   *foerderung.StatusSync
+	logger *slog.Logger
+}
+
+// NewFoerderungStatusSyncHandler creates a new Förderung status sync handler
+func NewFoerderungStatusSyncHandler(sync *foerderung.StatusSync, logger *slog.Logger) *FoerderungStatusSyncHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &FoerderungStatusSyncHandler{
+		sync:   sync,
+		logger: logger,
+	}
+}
+
+// Handle executes the Förderung status sync job
+func (h *FoerderungStatusSyncHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	synced, err := h.sync.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := FoerderungStatusSyncResult{Closed: synced.Closed, Flagged: synced.Flagged}
+	notified, err := h.sync.NotifyWatchers(ctx)
+	if err != nil {
+		h.logger.Error("failed to notify Förderung watchers", "job_id", j.ID, "error", err)
+	}
+	result.Notified = notified
+
+	h.logger.Info("Förderung status sync completed", "job_id", j.ID, "closed", result.Closed,
+		"flagged", result.Flagged, "notified", result.Notified)
+	return json.Marshal(result)
+}
@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/kammerumlage"
+)
+
+// KammerumlageFristenResult is the result of a KU1 deadline reminder job
+type KammerumlageFristenResult struct {
+	Reminded int `json:"reminded"`
+}
+
+// KammerumlageFristenHandler reminds tenant admins of the KU1 of the
+// quarter not submitted yet, on the kammerumlage.ReminderDays before the
+// payment deadline. Schedule it daily.
+type KammerumlageFristenHandler struct {
+	service *kammerumlage.Service
+	logger  *slog.Logger
+}
+
+// NewKammerumlageFristenHandler creates a new KU1 deadline reminder handler
+func NewKammerumlageFristenHandler(service *kammerumlage.Service, logger *slog.Logger) *KammerumlageFristenHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &KammerumlageFristenHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Handle executes the KU1 deadline reminder job
+func (h *KammerumlageFristenHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	reminded, err := h.service.RemindFristen(ctx)
+	if err != nil {
+		h.logger.Error("failed to send Kammerumlage reminders", "job_id", j.ID, "error", err)
+	}
+
+	h.logger.Info("Kammerumlage reminders sent", "job_id", j.ID, "reminded", reminded)
+	return json.Marshal(KammerumlageFristenResult{Reminded: reminded})
+}
@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/kleinunternehmer"
+	"github.com/google/uuid"
+)
+
+// KleinunternehmerCheckHandler checks tenants against the Kleinunternehmer threshold
+type KleinunternehmerCheckHandler struct {
+	service *kleinunternehmer.Service
+	logger  *slog.Logger
+}
+
+// NewKleinunternehmerCheckHandler creates a new Kleinunternehmer check handler
+func NewKleinunternehmerCheckHandler(service *kleinunternehmer.Service, logger *slog.Logger) *KleinunternehmerCheckHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &KleinunternehmerCheckHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// KleinunternehmerCheckPayload defines the job payload
+type KleinunternehmerCheckPayload struct {
+	TenantID *uuid.UUID `json:"tenant_id,omitempty"` // Optional: specific tenant
+}
+
+// KleinunternehmerCheckResult contains the results of a check run
+type KleinunternehmerCheckResult struct {
+	AlertsRaised int    `json:"alerts_raised"`
+	Level        string `json:"level,omitempty"` // Only set for single-tenant runs
+}
+
+// Handle executes the Kleinunternehmer check job
+func (h *KleinunternehmerCheckHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	var payload KleinunternehmerCheckPayload
+	if len(j.Payload) > 0 {
+		if err := json.Unmarshal(j.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("parse payload: %w", err)
+		}
+	}
+
+	var result KleinunternehmerCheckResult
+
+	if payload.TenantID != nil {
+		status, alert, err := h.service.Check(ctx, *payload.TenantID)
+		if err != nil {
+			return nil, fmt.Errorf("check tenant: %w", err)
+		}
+		result.Level = status.Level
+		if alert != nil {
+			result.AlertsRaised = 1
+		}
+	} else {
+		raised, err := h.service.CheckAll(ctx)
+		result.AlertsRaised = raised
+		if err != nil {
+			// Partial failures are logged; alerts for other tenants were raised
+			h.logger.Error("kleinunternehmer check failed for some tenants", "error", err)
+		}
+	}
+
+	h.logger.Info("kleinunternehmer check completed", "job_id", j.ID, "alerts_raised", result.AlertsRaised)
+	return json.Marshal(result)
+}
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/kommunalsteuer"
+)
+
+// KommunalsteuerFristenResult is the result of a Kommunalsteuer deadline
+// reminder job
+type KommunalsteuerFristenResult struct {
+	Reminded int `json:"reminded"`
+}
+
+// KommunalsteuerFristenHandler reminds tenant admins of the Kommunalsteuer
+// and Dienstgeberabgabe returns of the previous year not submitted yet, on
+// the kommunalsteuer.ReminderDays before the deadline. Schedule it daily.
+type KommunalsteuerFristenHandler struct {
+	service *kommunalsteuer.Service
+	logger  *slog.Logger
+}
+
+// NewKommunalsteuerFristenHandler creates a new Kommunalsteuer deadline
+// reminder handler
+func NewKommunalsteuerFristenHandler(service *kommunalsteuer.Service, logger *slog.Logger) *KommunalsteuerFristenHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &KommunalsteuerFristenHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Handle executes the Kommunalsteuer deadline reminder job
+func (h *KommunalsteuerFristenHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	reminded, err := h.service.RemindFristen(ctx)
+	if err != nil {
+		h.logger.Error("failed to send Kommunalsteuer reminders", "job_id", j.ID, "error", err)
+	}
+
+	h.logger.Info("Kommunalsteuer reminders sent", "job_id", j.ID, "reminded", reminded)
+	return json.Marshal(KommunalsteuerFristenResult{Reminded: reminded})
+}
@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/notification"
+)
+
+// defaultNotificationBatch is the number of queued notifications sent per run
+const defaultNotificationBatch = 100
+
+// NotificationDeliveryHandler sends the pending notifications of the queue.
+// Failed notifications are retried with backoff by later runs.
+type NotificationDeliveryHandler struct {
+	service *notification.Service
+	logger  *slog.Logger
+}
+
+// NewNotificationDeliveryHandler creates a new notification delivery handler
+func NewNotificationDeliveryHandler(service *notification.Service, logger *slog.Logger) *NotificationDeliveryHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &NotificationDeliveryHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// NotificationDeliveryPayload defines the job payload
+type NotificationDeliveryPayload struct {
+	BatchSize int `json:"batch_size,omitempty"`
+}
+
+// NotificationDeliveryResult contains the results of a delivery run
+type NotificationDeliveryResult struct {
+	Sent int `json:"sent"`
+}
+
+// Handle executes the notification delivery job
+func (h *NotificationDeliveryHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	payload := NotificationDeliveryPayload{BatchSize: defaultNotificationBatch}
+	if len(j.Payload) > 0 {
+		if err := json.Unmarshal(j.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("parse payload: %w", err)
+		}
+	}
+	if payload.BatchSize <= 0 {
+		payload.BatchSize = defaultNotificationBatch
+	}
+
+	sent, err := h.service.ProcessPendingNotifications(ctx, payload.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info("notification delivery completed", "job_id", j.ID, "sent", sent)
+	return json.Marshal(NotificationDeliveryResult{Sent: sent})
+}
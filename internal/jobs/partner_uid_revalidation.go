@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/partner"
+	"austrian-business-infrastructure/internal/uid"
+)
+
+// PartnerUIDRevalidationResult is the result of a partner UID re-validation
+// job
+type PartnerUIDRevalidationResult struct {
+	Tenants int `json:"tenants"`
+	Checked int `json:"checked"`
+	Invalid int `json:"invalid"`
+	Flagged int `json:"flagged"`
+	Failed  int `json:"failed"`
+}
+
+// PartnerUIDRevalidationHandler re-validates the UIDs of the tenants'
+// business partners with FinanzOnline once per quarter and announces the
+// partners whose UID turned invalid. Run daily, it spreads large partner
+// lists over several days within the daily UID limit. A re-validation that
+// fails continues with the next run; one whose account is gone is aborted.
+// During a maintenance window the job waits without using an attempt.
+type PartnerUIDRevalidationHandler struct {
+	service *partner.Service
+	logger  *slog.Logger
+}
+
+// NewPartnerUIDRevalidationHandler creates a new partner UID re-validation
+// handler
+func NewPartnerUIDRevalidationHandler(service *partner.Service, logger *slog.Logger) *PartnerUIDRevalidationHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &PartnerUIDRevalidationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Handle executes the partner UID re-validation job
+func (h *PartnerUIDRevalidationHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	tenants, err := h.service.DueTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result PartnerUIDRevalidationResult
+	for _, tenantID := range tenants {
+		logger := h.logger.With("job_id", j.ID, "tenant_id", tenantID)
+
+		revalidation, err := h.service.Revalidate(ctx, tenantID)
+		if err != nil {
+			var later job.RetryLater
+			if errors.As(err, &later) {
+				return nil, err
+			}
+			logger.Error("partner UID re-validation failed", "error", err)
+			result.Failed++
+			if !errors.Is(err, uid.ErrAccountNotFound) {
+				continue
+			}
+			if revalidation, err = h.service.AbortRevalidation(ctx, tenantID, err.Error()); err != nil {
+				logger.Error("failed to abort partner UID re-validation", "error", err)
+				continue
+			}
+		}
+
+		result.Tenants++
+		result.Checked += revalidation.Checked
+		result.Invalid += revalidation.Invalid
+		result.Flagged += len(revalidation.Flagged)
+		if err := h.service.NotifyInvalid(ctx, tenantID, revalidation.Flagged); err != nil {
+			logger.Error("failed to announce invalid partner UIDs", "error", err)
+		}
+	}
+
+	h.logger.Info("partner UID re-validation completed", "job_id", j.ID, "tenants", result.Tenants,
+		"checked", result.Checked, "flagged", result.Flagged, "failed", result.Failed)
+	return json.Marshal(result)
+}
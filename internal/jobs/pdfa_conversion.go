@@ -0,0 +1,244 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/pdfa"
+)
+
+// PDFAConversionPayload defines the job payload
+type PDFAConversionPayload struct {
+	DocumentID uuid.UUID `json:"document_id"`
+}
+
+// PDFAConversionResult is the result of a conversion job
+type PDFAConversionResult struct {
+	DocumentID uuid.UUID `json:"document_id"`
+	Status     string    `json:"status"`
+	Validator  string    `json:"validator,omitempty"`
+	Issues     int       `json:"issues,omitempty"`
+	FileSize   int64     `json:"file_size,omitempty"`
+}
+
+// PDFAConversionHandler converts a stored document to PDF/A-2b, validates
+// the result and stores it next to the original. Documents that cannot be
+// converted or do not validate are recorded as failed instead of retried;
+// they are listed for review and can be queued again by hand.
+type PDFAConversionHandler struct {
+	docService *document.Service
+	converter  *pdfa.Converter
+	logger     *slog.Logger
+}
+
+// NewPDFAConversionHandler creates a new PDF/A conversion handler
+func NewPDFAConversionHandler(docService *document.Service, converter *pdfa.Converter, logger *slog.Logger) *PDFAConversionHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &PDFAConversionHandler{
+		docService: docService,
+		converter:  converter,
+		logger:     logger,
+	}
+}
+
+// Handle executes the PDF/A conversion job
+func (h *PDFAConversionHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	var payload PDFAConversionPayload
+	if err := json.Unmarshal(j.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("parse payload: %w", err)
+	}
+
+	doc, err := h.docService.GetByID(ctx, j.TenantID, payload.DocumentID)
+	if err != nil {
+		if errors.Is(err, document.ErrDocumentNotFound) {
+			// Deleted since it was queued
+			return json.Marshal(PDFAConversionResult{DocumentID: payload.DocumentID, Status: "skipped"})
+		}
+		return nil, err
+	}
+	logger := h.logger.With("job_id", j.ID, "document_id", doc.ID)
+
+	rendition := &document.Rendition{Kind: document.RenditionPDFA, Conformance: pdfa.Conformance}
+	content, err := h.convert(ctx, doc, rendition)
+	if err != nil {
+		// Worker shutdown or storage errors are retried
+		return nil, err
+	}
+
+	if err := h.docService.SaveRendition(ctx, doc, rendition, content); err != nil {
+		return nil, fmt.Errorf("save rendition: %w", err)
+	}
+
+	if rendition.Status == document.RenditionConverted {
+		logger.Info("document archived as PDF/A", "validator", rendition.Validator, "size", rendition.FileSize)
+	} else {
+		logger.Warn("document could not be archived as PDF/A", "status", rendition.Status,
+			"error", rendition.Error, "issues", len(rendition.Issues))
+	}
+
+	return json.Marshal(PDFAConversionResult{
+		DocumentID: doc.ID,
+		Status:     rendition.Status,
+		Validator:  rendition.Validator,
+		Issues:     len(rendition.Issues),
+		FileSize:   rendition.FileSize,
+	})
+}
+
+// convert fills in the outcome of the conversion and returns the rendition
+// content if it validated. Only errors that should be retried are returned.
+func (h *PDFAConversionHandler) convert(ctx context.Context, doc *document.Document, rendition *document.Rendition) ([]byte, error) {
+	if !pdfa.Supported(doc.MimeType) {
+		rendition.Status = document.RenditionUnsupported
+		rendition.Error = fmt.Sprintf("%s: %s", pdfa.ErrUnsupportedFormat, doc.MimeType)
+		return nil, nil
+	}
+
+	reader, _, err := h.docService.GetContent(ctx, doc.TenantID, doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get content: %w", err)
+	}
+	original, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read content: %w", err)
+	}
+
+	converted, err := h.converter.Convert(ctx, original, doc.MimeType)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		rendition.Status = document.RenditionFailed
+		rendition.Error = err.Error()
+		return nil, nil
+	}
+
+	validation, err := h.converter.Validate(ctx, converted)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		rendition.Status = document.RenditionFailed
+		rendition.Error = "validation: " + err.Error()
+		return nil, nil
+	}
+
+	rendition.Validator = validation.Validator
+	for _, issue := range validation.Issues {
+		rendition.Issues = append(rendition.Issues, document.RenditionIssue{Clause: issue.Clause, Message: issue.Message})
+	}
+	if !validation.Compliant {
+		rendition.Status = document.RenditionFailed
+		rendition.Error = "rendition is not " + pdfa.Conformance + " conformant"
+		return nil, nil
+	}
+
+	rendition.Status = document.RenditionConverted
+	return converted, nil
+}
+
+// PDFAScheduler queues PDF/A conversion jobs; it is the archival scheduler
+// of the document service
+type PDFAScheduler struct {
+	queue *job.Queue
+}
+
+// NewPDFAScheduler creates a new PDF/A conversion scheduler
+func NewPDFAScheduler(queue *job.Queue) *PDFAScheduler {
+	return &PDFAScheduler{queue: queue}
+}
+
+// SupportsArchival reports whether documents of the MIME type can be converted
+func (s *PDFAScheduler) SupportsArchival(mimeType string) bool {
+	return pdfa.Supported(mimeType)
+}
+
+// ScheduleArchival queues the conversion of a document at low priority, so
+// that archiving never delays syncs and analyses
+func (s *PDFAScheduler) ScheduleArchival(ctx context.Context, tenantID, documentID uuid.UUID) error {
+	opts := job.DefaultEnqueueOptions()
+	opts.Priority = job.PriorityLow
+	opts.TimeoutSeconds = 900
+	_, err := s.queue.Enqueue(ctx, tenantID, job.TypePDFAConversion, PDFAConversionPayload{DocumentID: documentID}, opts)
+	return err
+}
+
+// PDFASweeper periodically queues the conversion of documents without a
+// PDF/A rendition: documents stored before archiving was enabled, created
+// through paths that do not schedule it, or whose job was lost.
+type PDFASweeper struct {
+	docService *document.Service
+	scheduler  *PDFAScheduler
+	interval   time.Duration
+	batch      int
+	logger     *slog.Logger
+}
+
+// NewPDFASweeper creates a new PDF/A sweeper
+func NewPDFASweeper(docService *document.Service, scheduler *PDFAScheduler, interval time.Duration, batch int, logger *slog.Logger) *PDFASweeper {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	if batch <= 0 {
+		batch = 100
+	}
+	return &PDFASweeper{
+		docService: docService,
+		scheduler:  scheduler,
+		interval:   interval,
+		batch:      batch,
+		logger:     logger,
+	}
+}
+
+// Run sweeps on start and then every interval until ctx is cancelled
+func (s *PDFASweeper) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *PDFASweeper) sweep(ctx context.Context) {
+	docs, err := s.docService.ListMissingRenditions(ctx, document.RenditionPDFA, job.TypePDFAConversion, pdfa.SupportedTypes(), s.batch)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("failed to list documents without PDF/A rendition", "error", err)
+		}
+		return
+	}
+
+	queued := 0
+	for _, doc := range docs {
+		if err := s.scheduler.ScheduleArchival(ctx, doc.TenantID, doc.ID); err != nil {
+			s.logger.Error("failed to queue PDF/A conversion", "document_id", doc.ID, "error", err)
+			continue
+		}
+		queued++
+	}
+	if queued > 0 {
+		s.logger.Info("queued PDF/A conversions", "count", queued)
+	}
+}
@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/rawpayload"
+)
+
+// RawPayloadCleanupHandler deletes raw provider payloads past their retention period
+type RawPayloadCleanupHandler struct {
+	repo   *rawpayload.Repository
+	logger *slog.Logger
+}
+
+// NewRawPayloadCleanupHandler creates a new raw payload cleanup handler
+func NewRawPayloadCleanupHandler(repo *rawpayload.Repository, logger *slog.Logger) *RawPayloadCleanupHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &RawPayloadCleanupHandler{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// RawPayloadCleanupResult contains the results of a cleanup run
+type RawPayloadCleanupResult struct {
+	PayloadsDeleted int64 `json:"payloads_deleted"`
+}
+
+// Handle executes the raw payload cleanup job
+func (h *RawPayloadCleanupHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	h.logger.Info("starting raw payload cleanup job", "job_id", j.ID)
+
+	deleted, err := h.repo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		h.logger.Error("raw payload cleanup failed", "error", err)
+		return nil, err
+	}
+
+	h.logger.Info("raw payload cleanup completed", "payloads_deleted", deleted)
+
+	return json.Marshal(RawPayloadCleanupResult{
+		PayloadsDeleted: deleted,
+	})
+}
@@ -99,7 +99,7 @@ func (h *SignatureJobHandler) findRequestsNeedingReminders(ctx context.Context,
 		return nil, err
 	}
 
-	var needsReminder []*signature.SignatureRequest
+	var candidates []*signature.SignatureRequest
 	for _, req := range allRequests {
 		if req.Status != signature.RequestStatusPending && req.Status != signature.RequestStatusInProgress {
 			continue
@@ -107,25 +107,23 @@ func (h *SignatureJobHandler) findRequestsNeedingReminders(ctx context.Context,
 		if req.ExpiresAt.After(cutoff) {
 			continue
 		}
+		candidates = append(candidates, req)
+	}
 
-		// Load signers
-		fullReq, err := h.repo.GetRequestWithSigners(ctx, req.ID)
-		if err != nil {
-			continue
-		}
+	// Load the signers of all candidates at once
+	if err := h.repo.LoadSignersAndFields(ctx, candidates); err != nil {
+		return nil, err
+	}
 
+	var needsReminder []*signature.SignatureRequest
+	for _, req := range candidates {
 		// Check if any signers need reminders
-		hasNotified := false
-		for _, signer := range fullReq.Signers {
+		for _, signer := range req.Signers {
 			if signer.Status == signature.SignerStatusNotified {
-				hasNotified = true
+				needsReminder = append(needsReminder, req)
 				break
 			}
 		}
-
-		if hasNotified {
-			needsReminder = append(needsReminder, fullReq)
-		}
 	}
 
 	return needsReminder, nil
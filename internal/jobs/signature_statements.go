@@ -0,0 +1,57 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/sigbilling"
+)
+
+// SignatureStatementsHandler generates the monthly signature statements
+type SignatureStatementsHandler struct {
+	service *sigbilling.Service
+	logger  *slog.Logger
+}
+
+// NewSignatureStatementsHandler creates a new signature statements handler
+func NewSignatureStatementsHandler(service *sigbilling.Service, logger *slog.Logger) *SignatureStatementsHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SignatureStatementsHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// SignatureStatementsPayload defines the job payload
+type SignatureStatementsPayload struct {
+	Period string `json:"period,omitempty"` // Optional: YYYY-MM, defaults to the previous month
+}
+
+// Handle executes the signature statements job
+func (h *SignatureStatementsHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	var payload SignatureStatementsPayload
+	if len(j.Payload) > 0 {
+		if err := json.Unmarshal(j.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("parse payload: %w", err)
+		}
+	}
+	if payload.Period == "" {
+		payload.Period = sigbilling.PreviousPeriod(time.Now())
+	}
+
+	result, err := h.service.GenerateStatements(ctx, payload.Period)
+	if err != nil {
+		h.logger.Error("signature statements failed", "period", payload.Period, "error", err)
+		return nil, err
+	}
+
+	h.logger.Info("signature statements completed", "job_id", j.ID, "period", result.Period,
+		"statements", result.Statements, "failed", result.Failed, "expired_packages", result.ExpiredPackages)
+	return json.Marshal(result)
+}
@@ -0,0 +1,111 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/uid"
+)
+
+// UIDBatchPayload defines the job payload
+type UIDBatchPayload struct {
+	BatchID uuid.UUID `json:"batch_id"`
+}
+
+// UIDBatchResult is the result of a batch verification job
+type UIDBatchResult struct {
+	BatchID uuid.UUID `json:"batch_id"`
+	Status  string    `json:"status"`
+	Valid   int       `json:"valid"`
+	Invalid int       `json:"invalid"`
+	Failed  int       `json:"failed"`
+}
+
+// UIDBatchHandler verifies the UIDs of an uploaded batch with the
+// FinanzOnline UID-Bestätigungsservice and mails the outcome to the
+// uploader. An attempt that fails as a whole is retried and continues with
+// the UIDs not yet confirmed; after the last attempt the batch is failed.
+// During a maintenance window the job waits without using an attempt.
+type UIDBatchHandler struct {
+	service *uid.Service
+	logger  *slog.Logger
+}
+
+// NewUIDBatchHandler creates a new UID batch handler
+func NewUIDBatchHandler(service *uid.Service, logger *slog.Logger) *UIDBatchHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &UIDBatchHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Handle executes the UID batch job
+func (h *UIDBatchHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	var payload UIDBatchPayload
+	if err := json.Unmarshal(j.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("parse payload: %w", err)
+	}
+	logger := h.logger.With("job_id", j.ID, "batch_id", payload.BatchID)
+
+	batch, err := h.service.ProcessBatch(ctx, j.TenantID, payload.BatchID)
+	if errors.Is(err, uid.ErrBatchNotFound) {
+		return json.Marshal(UIDBatchResult{BatchID: payload.BatchID, Status: "skipped"})
+	}
+	if err != nil {
+		var later job.RetryLater
+		final := errors.Is(err, uid.ErrAccountNotFound) || j.RetryCount+1 >= j.MaxRetries
+		if errors.As(err, &later) || !final {
+			return nil, err
+		}
+
+		logger.Error("UID batch failed", "error", err)
+		batch, err = h.service.FailBatch(ctx, j.TenantID, payload.BatchID, err.Error())
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		logger.Info("UID batch verified", "total", batch.Total,
+			"valid", batch.ValidCount, "invalid", batch.InvalidCount, "failed", batch.FailedCount)
+	}
+
+	if err := h.service.NotifyBatch(ctx, batch); err != nil {
+		logger.Error("failed to send UID batch notification", "error", err)
+	}
+
+	return json.Marshal(UIDBatchResult{
+		BatchID: batch.ID,
+		Status:  batch.Status,
+		Valid:   batch.ValidCount,
+		Invalid: batch.InvalidCount,
+		Failed:  batch.FailedCount,
+	})
+}
+
+// UIDBatchScheduler queues UID batch jobs; it is the batch scheduler of
+// the UID service
+type UIDBatchScheduler struct {
+	queue *job.Queue
+}
+
+// NewUIDBatchScheduler creates a new UID batch scheduler
+func NewUIDBatchScheduler(queue *job.Queue) *UIDBatchScheduler {
+	return &UIDBatchScheduler{queue: queue}
+}
+
+// ScheduleBatch queues the verification of a batch. A batch of the daily
+// limit takes a while, one FinanzOnline query after the other.
+func (s *UIDBatchScheduler) ScheduleBatch(ctx context.Context, tenantID, batchID uuid.UUID) error {
+	opts := job.DefaultEnqueueOptions()
+	opts.TimeoutSeconds = 3600
+	_, err := s.queue.Enqueue(ctx, tenantID, job.TypeUIDBatch, UIDBatchPayload{BatchID: batchID}, opts)
+	return err
+}
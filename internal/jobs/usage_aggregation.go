@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/usage"
+)
+
+// UsageAggregationHandler downsamples usage into the per-tenant time series
+type UsageAggregationHandler struct {
+	service *usage.Service
+	logger  *slog.Logger
+}
+
+// NewUsageAggregationHandler creates a new usage aggregation handler
+func NewUsageAggregationHandler(service *usage.Service, logger *slog.Logger) *UsageAggregationHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &UsageAggregationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// UsageAggregationPayload defines the job payload
+type UsageAggregationPayload struct {
+	From string `json:"from,omitempty"` // Optional: backfill range, YYYY-MM-DD
+	To   string `json:"to,omitempty"`
+}
+
+// Handle executes the usage aggregation job
+func (h *UsageAggregationHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	var payload UsageAggregationPayload
+	if len(j.Payload) > 0 {
+		if err := json.Unmarshal(j.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("parse payload: %w", err)
+		}
+	}
+
+	var result *usage.AggregationResult
+	var err error
+	if payload.From == "" && payload.To == "" {
+		result, err = h.service.AggregateSettled(ctx)
+	} else {
+		from, ferr := time.Parse(usage.DayLayout, payload.From)
+		to, terr := time.Parse(usage.DayLayout, payload.To)
+		if ferr != nil || terr != nil {
+			return nil, fmt.Errorf("parse payload: from and to must both be YYYY-MM-DD")
+		}
+		result, err = h.service.Aggregate(ctx, from, to)
+	}
+	if err != nil {
+		h.logger.Error("usage aggregation failed", "error", err)
+		return nil, err
+	}
+
+	h.logger.Info("usage aggregation completed", "job_id", j.ID, "from", result.From, "to", result.To, "rows_written", result.RowsWritten)
+	return json.Marshal(result)
+}
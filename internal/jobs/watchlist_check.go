@@ -20,7 +20,7 @@ const WatchlistCheckJobType = "watchlist_check"
 // WatchlistCheckHandler handles Firmenbuch watchlist checking
 type WatchlistCheckHandler struct {
 	watchlistRepo  *watchlist.Repository
-	fbClient       *fb.Client
+	fbClient       fb.Registry
 	webhookService *webhook.Service
 	logger         *slog.Logger
 	concurrency    int
@@ -35,7 +35,7 @@ type WatchlistCheckConfig struct {
 // NewWatchlistCheckHandler creates a new watchlist check handler
 func NewWatchlistCheckHandler(
 	watchlistRepo *watchlist.Repository,
-	fbClient *fb.Client,
+	fbClient fb.Registry,
 	webhookService *webhook.Service,
 	cfg *WatchlistCheckConfig,
 ) *WatchlistCheckHandler {
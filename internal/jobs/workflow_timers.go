@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/workflow"
+)
+
+// WorkflowTimersResult is the result of a workflow timer job
+type WorkflowTimersResult struct {
+	Fired     int `json:"fired"`
+	Escalated int `json:"escalated"`
+}
+
+// WorkflowTimersHandler fires the expired timers of workflows and escalates
+// those past the SLA of their state. Schedule it every few minutes.
+type WorkflowTimersHandler struct {
+	service *workflow.Service
+	logger  *slog.Logger
+}
+
+// NewWorkflowTimersHandler creates a new workflow timer handler
+func NewWorkflowTimersHandler(service *workflow.Service, logger *slog.Logger) *WorkflowTimersHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &WorkflowTimersHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Handle executes the workflow timer job
+func (h *WorkflowTimersHandler) Handle(ctx context.Context, j *job.Job) (json.RawMessage, error) {
+	fired, escalated, err := h.service.ProcessTimers(ctx)
+	if err != nil {
+		h.logger.Error("failed to process workflow timers", "job_id", j.ID, "error", err)
+	}
+
+	h.logger.Info("workflow timers processed", "job_id", j.ID, "fired", fired, "escalated", escalated)
+	return json.Marshal(WorkflowTimersResult{Fired: fired, Escalated: escalated})
+}
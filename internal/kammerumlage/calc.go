@@ -0,0 +1,121 @@
+package kammerumlage
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"austrian-business-infrastructure/internal/kommunalsteuer"
+)
+
+// Freigrenze is the annual assessment basis up to which no KU1 is levied
+// (§ 122 Abs. 1 WKG), in cents
+const Freigrenze int64 = 1_500_000
+
+// Stufe is a band of the degressive KU1 rates. The rate applies to the
+// part of the annual assessment basis up to Bis (0 = no limit).
+type Stufe struct {
+	Bis  int64   // In cents
+	Satz float64 // Percent
+}
+
+// Staffel are the KU1 rates since 2019
+var Staffel = []Stufe{
+	{Bis: 300_000_000, Satz: 0.29},
+	{Bis: 3_250_000_000, Satz: 0.2755},
+	{Bis: 0, Satz: 0.2552},
+}
+
+// Umlage returns the KU1 on an annual assessment basis: nothing within the
+// Freigrenze, above it each band at its rate
+func Umlage(basis int64) int64 {
+	if basis <= Freigrenze {
+		return 0
+	}
+	var umlage float64
+	var from int64
+	for _, s := range Staffel {
+		to := basis
+		if s.Bis > 0 && s.Bis < basis {
+			to = s.Bis
+		}
+		umlage += float64(to-from) * s.Satz / 100
+		if to == basis {
+			break
+		}
+		from = s.Bis
+	}
+	return int64(math.Round(umlage))
+}
+
+// Berechne calculates the KU1 of a quarter from the UVA filings of the
+// year. The input tax of the year up to the quarter is the basis of the
+// Freigrenze and the rates; the KU1 of the previous quarters calculated
+// the same way is deducted, so a quarter in which the year exceeds the
+// Freigrenze also pays for the quarters before it.
+func Berechne(year, quarter int, perioden []Periode) *Berechnung {
+	b := &Berechnung{Perioden: []Periode{}, Faellig: Faelligkeit(year, quarter)}
+
+	var vorquartale int64
+	quarterly := make(map[int]bool)
+	months := make(map[string]bool)
+	for _, p := range perioden {
+		if p.Quarter > quarter {
+			continue
+		}
+		b.Perioden = append(b.Perioden, p)
+		if p.Quarter == quarter {
+			b.Bemessungsgrundlage += p.Vorsteuer
+		} else {
+			vorquartale += p.Vorsteuer
+		}
+		if p.Periode[0] == 'Q' {
+			quarterly[p.Quarter] = true
+		} else {
+			months[p.Periode] = true
+		}
+	}
+
+	for q := 1; q <= quarter; q++ {
+		if quarterly[q] {
+			continue
+		}
+		for m := q*3 - 2; m <= q*3; m++ {
+			if periode := MonatsPeriode(year, m); !months[periode] {
+				b.FehlendePerioden = append(b.FehlendePerioden, periode)
+			}
+		}
+	}
+
+	b.BemessungsgrundlageJahr = vorquartale + b.Bemessungsgrundlage
+	b.Freigrenze = b.BemessungsgrundlageJahr <= Freigrenze
+	b.UmlageJahr = Umlage(b.BemessungsgrundlageJahr)
+	b.UmlageVorquartale = Umlage(vorquartale)
+	b.Umlage = b.UmlageJahr - b.UmlageVorquartale
+	return b
+}
+
+// MonatsPeriode formats a monthly UVA period as 03/2025
+func MonatsPeriode(year, month int) string {
+	return fmt.Sprintf("%02d/%d", month, year)
+}
+
+// QuartalsPeriode formats a quarter as Q1/2025
+func QuartalsPeriode(year, quarter int) string {
+	return fmt.Sprintf("Q%d/%d", quarter, year)
+}
+
+// Faelligkeit returns the payment deadline of a quarter's KU1: the 15th of
+// the second month after the quarter, or the next working day
+func Faelligkeit(year, quarter int) time.Time {
+	return kommunalsteuer.NaechsterWerktag(time.Date(year, time.Month(quarter*3+2), 15, 0, 0, 0, 0, time.UTC))
+}
+
+// Fristen returns the payment deadlines of the quarters of a year
+func Fristen(year int) []Frist {
+	fristen := make([]Frist, 0, 4)
+	for q := 1; q <= 4; q++ {
+		fristen = append(fristen, Frist{Periode: QuartalsPeriode(year, q), Faellig: Faelligkeit(year, q)})
+	}
+	return fristen
+}
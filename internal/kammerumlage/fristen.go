@@ -0,0 +1,113 @@
+package kammerumlage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/timezone"
+	"austrian-business-infrastructure/pkg/money"
+)
+
+// ReminderDays are the days before the payment deadline on which tenant
+// admins are reminded of KU1 not submitted yet
+var ReminderDays = []int{14, 7, 3, 1}
+
+// FristNotifier mails the reminders of KU1 deadlines; email.Service
+// implements it
+type FristNotifier interface {
+	SendKammerumlageFrist(ctx context.Context, to string, params email.KammerumlageFristParams) error
+}
+
+// SetNotifier enables the deadline reminders; appURL is linked in the mails
+func (s *Service) SetNotifier(n FristNotifier, appURL string) {
+	s.notifier = n
+	s.appURL = appURL
+}
+
+// ReminderQuarter returns the quarter whose KU1 is due on one of the
+// ReminderDays after today, and the days left
+func ReminderQuarter(today time.Time) (int, int, int, bool) {
+	candidates := [][2]int{{today.Year() - 1, 4}, {today.Year(), 1}, {today.Year(), 2}, {today.Year(), 3}}
+	for _, c := range candidates {
+		daysLeft := int(Faelligkeit(c[0], c[1]).Sub(today).Hours() / 24)
+		for _, d := range ReminderDays {
+			if daysLeft == d {
+				return c[0], c[1], daysLeft, true
+			}
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// RemindFristen mails the admins of tenants with a KU1 due for the quarter
+// that has not been submitted, on the ReminderDays before the deadline.
+// Companies whose year is still within the Freigrenze are left out. Run it
+// daily. It returns the mails sent.
+func (s *Service) RemindFristen(ctx context.Context) (int, error) {
+	if s.notifier == nil {
+		return 0, nil
+	}
+	today := timezone.Today(s.now(), timezone.Vienna)
+	year, quarter, daysLeft, ok := ReminderQuarter(today)
+	if !ok {
+		return 0, nil
+	}
+
+	offen, err := s.repo.ListOffen(ctx, year, quarter)
+	if err != nil {
+		return 0, err
+	}
+
+	umlagen := make(map[uuid.UUID][]email.KammerumlageBetrag)
+	var tenantIDs []uuid.UUID
+	for _, o := range offen {
+		berechnung, err := s.berechne(ctx, o.AccountID, year, quarter)
+		if err != nil {
+			return 0, err
+		}
+		if berechnung.Umlage <= 0 {
+			continue
+		}
+		if _, ok := umlagen[o.TenantID]; !ok {
+			tenantIDs = append(tenantIDs, o.TenantID)
+		}
+		umlagen[o.TenantID] = append(umlagen[o.TenantID], email.KammerumlageBetrag{
+			Name:   o.AccountName,
+			Umlage: money.EUR(berechnung.Umlage).String(),
+		})
+	}
+	if len(tenantIDs) == 0 {
+		return 0, nil
+	}
+
+	admins, err := s.repo.ListAdmins(ctx, tenantIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var lastErr error
+	for _, a := range admins {
+		params := email.KammerumlageFristParams{
+			TenantID:      &a.TenantID,
+			RecipientName: a.Name,
+			Periode:       QuartalsPeriode(year, quarter),
+			Faellig:       Faelligkeit(year, quarter).Format("02.01.2006"),
+			DaysLeft:      daysLeft,
+			Umlagen:       umlagen[a.TenantID],
+		}
+		if s.appURL != "" {
+			params.URL = s.appURL + "/kammerumlage"
+		}
+		if err := s.notifier.SendKammerumlageFrist(ctx, a.Email, params); err != nil {
+			lastErr = fmt.Errorf("failed to remind %s: %w", a.Email, err)
+			continue
+		}
+		sent++
+	}
+	return sent, lastErr
+}
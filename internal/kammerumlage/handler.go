@@ -0,0 +1,231 @@
+package kammerumlage
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler handles Kammerumlage HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new Kammerumlage handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the Kammerumlage routes. Like UVA, changing and
+// submitting is for admins.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/kammerumlage", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("GET /api/v1/kammerumlage/fristen", requireAuth(http.HandlerFunc(h.Fristen)))
+	router.Handle("POST /api/v1/kammerumlage", requireAuth(requireAdmin(http.HandlerFunc(h.Create))))
+	router.Handle("GET /api/v1/kammerumlage/{id}", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("PUT /api/v1/kammerumlage/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Recalculate))))
+	router.Handle("DELETE /api/v1/kammerumlage/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Delete))))
+	router.Handle("POST /api/v1/kammerumlage/{id}/submit", requireAuth(requireAdmin(http.HandlerFunc(h.Submit))))
+}
+
+// List handles GET /api/v1/kammerumlage
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	filter := ListFilter{TenantID: tenantID, Limit: 50}
+	if accountIDStr := r.URL.Query().Get("account_id"); accountIDStr != "" {
+		accountID, err := uuid.Parse(accountIDStr)
+		if err != nil {
+			api.BadRequest(w, "invalid account_id")
+			return
+		}
+		filter.AccountID = &accountID
+	}
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil {
+			api.BadRequest(w, "invalid year")
+			return
+		}
+		filter.Year = &year
+	}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	list, total, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if list == nil {
+		list = []*Meldung{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  list,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// Fristen handles GET /api/v1/kammerumlage/fristen?year=
+func (h *Handler) Fristen(w http.ResponseWriter, r *http.Request) {
+	year, err := strconv.Atoi(r.URL.Query().Get("year"))
+	if err != nil || year < 2000 || year > 2100 {
+		api.BadRequest(w, ErrInvalidYear.Error())
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"year": year, "items": Fristen(year)})
+}
+
+// Create handles POST /api/v1/kammerumlage
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.RequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var input Input
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	m, err := h.service.Create(r.Context(), tenantID, userID, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, m)
+}
+
+// Get handles GET /api/v1/kammerumlage/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	m, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, m)
+}
+
+// Recalculate handles PUT /api/v1/kammerumlage/{id}
+func (h *Handler) Recalculate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	m, err := h.service.Recalculate(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, m)
+}
+
+// Delete handles DELETE /api/v1/kammerumlage/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), tenantID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Submit handles POST /api/v1/kammerumlage/{id}/submit
+func (h *Handler) Submit(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.RequestUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reference string `json:"reference"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			api.BadRequest(w, "invalid request body")
+			return
+		}
+	}
+
+	m, err := h.service.Submit(r.Context(), tenantID, id, userID, req.Reference)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, m)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrMeldungNotFound):
+		api.NotFound(w, "KU1 not found")
+	case errors.Is(err, ErrAccountNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrDuplicateMeldung), errors.Is(err, ErrNotDraft):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, ErrInvalidYear), errors.Is(err, ErrInvalidQuarter), errors.Is(err, ErrNotFinanzOnline):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	default:
+		api.InternalError(w)
+	}
+}
@@ -0,0 +1,275 @@
+package kammerumlage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/internal/uva"
+)
+
+// Repository handles KU1 database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new Kammerumlage repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const meldungColumns = `id, tenant_id, account_id, year, quarter, berechnung, status, reference,
+	submitted_at, submitted_by, created_by, created_at, updated_at`
+
+func scanMeldung(row pgx.Row) (*Meldung, error) {
+	var m Meldung
+	var berechnungJSON []byte
+	if err := row.Scan(&m.ID, &m.TenantID, &m.AccountID, &m.Year, &m.Quarter, &berechnungJSON, &m.Status,
+		&m.Reference, &m.SubmittedAt, &m.SubmittedBy, &m.CreatedBy, &m.CreatedAt, &m.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(berechnungJSON, &m.Berechnung); err != nil {
+		return nil, fmt.Errorf("invalid berechnung: %w", err)
+	}
+	return &m, nil
+}
+
+// Create inserts a Meldung
+func (r *Repository) Create(ctx context.Context, m *Meldung) error {
+	berechnungJSON, err := json.Marshal(m.Berechnung)
+	if err != nil {
+		return fmt.Errorf("failed to create KU1: %w", err)
+	}
+	saved, err := scanMeldung(r.db.QueryRow(ctx, `
+		INSERT INTO kammerumlage_meldungen (tenant_id, account_id, year, quarter, berechnung,
+			bemessungsgrundlage, umlage, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+meldungColumns,
+		m.TenantID, m.AccountID, m.Year, m.Quarter, berechnungJSON,
+		m.Berechnung.Bemessungsgrundlage, m.Berechnung.Umlage, m.CreatedBy))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicateMeldung
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create KU1: %w", err)
+	}
+	*m = *saved
+	return nil
+}
+
+// UpdateBerechnung replaces the calculation of a Meldung that has not been
+// submitted
+func (r *Repository) UpdateBerechnung(ctx context.Context, m *Meldung) error {
+	berechnungJSON, err := json.Marshal(m.Berechnung)
+	if err != nil {
+		return fmt.Errorf("failed to update KU1: %w", err)
+	}
+	saved, err := scanMeldung(r.db.QueryRow(ctx, `
+		UPDATE kammerumlage_meldungen SET
+			berechnung = $3, bemessungsgrundlage = $4, umlage = $5, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status = 'draft'
+		RETURNING `+meldungColumns,
+		m.ID, m.TenantID, berechnungJSON, m.Berechnung.Bemessungsgrundlage, m.Berechnung.Umlage))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotDraft
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update KU1: %w", err)
+	}
+	*m = *saved
+	return nil
+}
+
+// Get returns a Meldung of a tenant
+func (r *Repository) Get(ctx context.Context, tenantID, id uuid.UUID) (*Meldung, error) {
+	m, err := scanMeldung(r.db.QueryRow(ctx,
+		`SELECT `+meldungColumns+` FROM kammerumlage_meldungen WHERE id = $1 AND tenant_id = $2`, id, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrMeldungNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get KU1: %w", err)
+	}
+	return m, nil
+}
+
+// List returns the Meldungen of a tenant, the latest quarter first
+func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Meldung, int, error) {
+	where := "tenant_id = $1"
+	args := []interface{}{filter.TenantID}
+	if filter.AccountID != nil {
+		args = append(args, *filter.AccountID)
+		where += fmt.Sprintf(" AND account_id = $%d", len(args))
+	}
+	if filter.Year != nil {
+		args = append(args, *filter.Year)
+		where += fmt.Sprintf(" AND year = $%d", len(args))
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM kammerumlage_meldungen WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count KU1: %w", err)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT %s FROM kammerumlage_meldungen
+		WHERE %s
+		ORDER BY year DESC, quarter DESC, created_at DESC
+		LIMIT $%d OFFSET $%d`, meldungColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list KU1: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*Meldung
+	for rows.Next() {
+		m, err := scanMeldung(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list KU1: %w", err)
+		}
+		list = append(list, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list KU1: %w", err)
+	}
+	return list, total, nil
+}
+
+// Delete removes a Meldung that has not been submitted
+func (r *Repository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM kammerumlage_meldungen WHERE id = $1 AND tenant_id = $2 AND status = 'draft'`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete KU1: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.Get(ctx, tenantID, id); err != nil {
+			return err
+		}
+		return ErrNotDraft
+	}
+	return nil
+}
+
+// MarkSubmitted records that the KU1 of a draft was reported
+func (r *Repository) MarkSubmitted(ctx context.Context, tenantID, id, userID uuid.UUID, reference *string) (*Meldung, error) {
+	m, err := scanMeldung(r.db.QueryRow(ctx, `
+		UPDATE kammerumlage_meldungen SET
+			status = 'submitted', reference = $3, submitted_at = NOW(), submitted_by = $4, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status = 'draft'
+		RETURNING `+meldungColumns, id, tenantID, reference, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := r.Get(ctx, tenantID, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrNotDraft
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit KU1: %w", err)
+	}
+	return m, nil
+}
+
+// ListPerioden returns the UVA filings of an account in a year up to a
+// quarter. Of a period filed more than once, e.g. with a Berichtigung,
+// the latest filing counts.
+func (r *Repository) ListPerioden(ctx context.Context, accountID uuid.UUID, year, quarter int) ([]Periode, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT ON (period_type, COALESCE(period_month, period_quarter))
+			id, period_type, period_month, period_quarter, data
+		FROM uva_submissions
+		WHERE account_id = $1 AND period_year = $2 AND status IN ('submitted', 'accepted')
+		AND COALESCE(period_quarter, (period_month + 2) / 3) <= $3
+		ORDER BY period_type, COALESCE(period_month, period_quarter), submitted_at DESC NULLS LAST, created_at DESC`,
+		accountID, year, quarter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list UVA filings: %w", err)
+	}
+	defer rows.Close()
+
+	var perioden []Periode
+	for rows.Next() {
+		var p Periode
+		var periodType string
+		var month, q *int
+		var data json.RawMessage
+		if err := rows.Scan(&p.SubmissionID, &periodType, &month, &q, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan UVA filing: %w", err)
+		}
+		kz, err := uva.ParseData(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data of UVA %s: %w", p.SubmissionID, err)
+		}
+		p.Vorsteuer = kz.KZ060 + kz.KZ065 + kz.KZ066
+		if periodType == uva.PeriodTypeMonthly && month != nil {
+			p.Periode = MonatsPeriode(year, *month)
+			p.Quarter = (*month + 2) / 3
+		} else if q != nil {
+			p.Periode = QuartalsPeriode(year, *q)
+			p.Quarter = *q
+		} else {
+			continue
+		}
+		perioden = append(perioden, p)
+	}
+	return perioden, rows.Err()
+}
+
+// ListOffen returns the FinanzOnline accounts with a UVA filing in a
+// quarter whose KU1 of the quarter has not been submitted
+func (r *Repository) ListOffen(ctx context.Context, year, quarter int) ([]*Offen, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT a.tenant_id, a.id, a.name
+		FROM accounts a
+		JOIN uva_submissions u ON u.account_id = a.id AND u.period_year = $1
+			AND COALESCE(u.period_quarter, (u.period_month + 2) / 3) = $2
+			AND u.status IN ('submitted', 'accepted')
+		WHERE NOT EXISTS (
+			SELECT 1 FROM kammerumlage_meldungen k
+			WHERE k.account_id = a.id AND k.year = $1 AND k.quarter = $2 AND k.status = 'submitted')
+		ORDER BY a.tenant_id, a.name`, year, quarter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open KU1: %w", err)
+	}
+	defer rows.Close()
+
+	var offen []*Offen
+	for rows.Next() {
+		var o Offen
+		if err := rows.Scan(&o.TenantID, &o.AccountID, &o.AccountName); err != nil {
+			return nil, fmt.Errorf("failed to scan open KU1: %w", err)
+		}
+		offen = append(offen, &o)
+	}
+	return offen, rows.Err()
+}
+
+// ListAdmins returns the active owners and admins of tenants
+func (r *Repository) ListAdmins(ctx context.Context, tenantIDs []uuid.UUID) ([]*Admin, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT tenant_id, name, email FROM users
+		WHERE tenant_id = ANY($1) AND role IN ('owner', 'admin') AND is_active
+		ORDER BY tenant_id, email`, tenantIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant admins: %w", err)
+	}
+	defer rows.Close()
+
+	var admins []*Admin
+	for rows.Next() {
+		var a Admin
+		if err := rows.Scan(&a.TenantID, &a.Name, &a.Email); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant admin: %w", err)
+		}
+		admins = append(admins, &a)
+	}
+	return admins, rows.Err()
+}
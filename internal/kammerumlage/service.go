@@ -0,0 +1,120 @@
+package kammerumlage
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/account"
+)
+
+// Service handles KU1 calculations and Meldungen
+type Service struct {
+	repo           *Repository
+	accountService *account.Service
+	notifier       FristNotifier
+	appURL         string
+	now            func() time.Time
+}
+
+// NewService creates a new Kammerumlage service
+func NewService(repo *Repository, accountService *account.Service) *Service {
+	return &Service{
+		repo:           repo,
+		accountService: accountService,
+		now:            time.Now,
+	}
+}
+
+// Create calculates the KU1 of a FinanzOnline account for a quarter from
+// its UVA filings
+func (s *Service) Create(ctx context.Context, tenantID, userID uuid.UUID, input *Input) (*Meldung, error) {
+	if input.Year < 2000 || input.Year > 2100 {
+		return nil, ErrInvalidYear
+	}
+	if input.Quarter < 1 || input.Quarter > 4 {
+		return nil, ErrInvalidQuarter
+	}
+	acc, err := s.accountService.GetAccount(ctx, input.AccountID, tenantID)
+	if err != nil {
+		return nil, ErrAccountNotFound
+	}
+	if acc.Type != account.AccountTypeFinanzOnline {
+		return nil, ErrNotFinanzOnline
+	}
+
+	berechnung, err := s.berechne(ctx, input.AccountID, input.Year, input.Quarter)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Meldung{
+		TenantID:   tenantID,
+		AccountID:  input.AccountID,
+		Year:       input.Year,
+		Quarter:    input.Quarter,
+		Berechnung: berechnung,
+		CreatedBy:  &userID,
+	}
+	if err := s.repo.Create(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Recalculate recalculates a draft from the current UVA filings, e.g.
+// after a Berichtigung
+func (s *Service) Recalculate(ctx context.Context, tenantID, id uuid.UUID) (*Meldung, error) {
+	m, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if m.Status != StatusDraft {
+		return nil, ErrNotDraft
+	}
+
+	m.Berechnung, err = s.berechne(ctx, m.AccountID, m.Year, m.Quarter)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateBerechnung(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *Service) berechne(ctx context.Context, accountID uuid.UUID, year, quarter int) (*Berechnung, error) {
+	perioden, err := s.repo.ListPerioden(ctx, accountID, year, quarter)
+	if err != nil {
+		return nil, err
+	}
+	return Berechne(year, quarter, perioden), nil
+}
+
+// Get returns a Meldung
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Meldung, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// List returns the Meldungen of a tenant
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]*Meldung, int, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Delete removes a draft
+func (s *Service) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+// Submit records that the KU1 of a draft was reported with its payment to
+// the Finanzamt. The KU1 has no return of its own; the reference, e.g. of
+// the payment, is kept with the Meldung.
+func (s *Service) Submit(ctx context.Context, tenantID, id, userID uuid.UUID, reference string) (*Meldung, error) {
+	var ref *string
+	if reference = strings.TrimSpace(reference); reference != "" {
+		ref = &reference
+	}
+	return s.repo.MarkSubmitted(ctx, tenantID, id, userID, ref)
+}
@@ -0,0 +1,109 @@
+// Package kammerumlage calculates the quarterly Kammerumlage 1 (KU1, § 122
+// WKG) of a company from the input tax of its UVA filings, records the
+// Meldung once the KU1 has been reported with the payment to the Finanzamt
+// and reminds of the quarterly deadlines.
+package kammerumlage
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMeldungNotFound  = errors.New("KU1 not found")
+	ErrDuplicateMeldung = errors.New("a KU1 for this account and quarter already exists")
+	ErrInvalidYear      = errors.New("year must be between 2000 and 2100")
+	ErrInvalidQuarter   = errors.New("quarter must be between 1 and 4")
+	ErrAccountNotFound  = errors.New("account not found")
+	ErrNotFinanzOnline  = errors.New("account must be a FinanzOnline account")
+	ErrNotDraft         = errors.New("KU1 has already been submitted")
+)
+
+// Status of a Meldung
+const (
+	StatusDraft     = "draft"
+	StatusSubmitted = "submitted"
+)
+
+// Meldung is the KU1 of a company (FinanzOnline account) for a quarter
+type Meldung struct {
+	ID         uuid.UUID   `json:"id"`
+	TenantID   uuid.UUID   `json:"tenant_id"`
+	AccountID  uuid.UUID   `json:"account_id"`
+	Year       int         `json:"year"`
+	Quarter    int         `json:"quarter"`
+	Berechnung *Berechnung `json:"berechnung"`
+
+	Status      string     `json:"status"`
+	Reference   *string    `json:"reference,omitempty"` // e.g. the payment reference
+	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
+	SubmittedBy *uuid.UUID `json:"submitted_by,omitempty"`
+
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Periode is a UVA filing of the year counted for the KU1
+type Periode struct {
+	Periode      string    `json:"periode"` // 03/2025 or Q1/2025
+	Quarter      int       `json:"quarter"`
+	SubmissionID uuid.UUID `json:"submission_id"`
+	Vorsteuer    int64     `json:"vorsteuer"` // KZ060 + KZ065 + KZ066, in cents
+}
+
+// Berechnung is the calculation of a quarter's KU1. The Freigrenze and the
+// rates apply to the year, so the year is calculated up to the quarter and
+// the KU1 of the previous quarters is deducted. Amounts are in cents.
+type Berechnung struct {
+	Perioden         []Periode `json:"perioden"`
+	FehlendePerioden []string  `json:"fehlende_perioden,omitempty"` // Periods up to the quarter without UVA filing
+
+	Bemessungsgrundlage     int64 `json:"bemessungsgrundlage"`      // Input tax of the quarter
+	BemessungsgrundlageJahr int64 `json:"bemessungsgrundlage_jahr"` // Input tax of the year up to the quarter
+	Freigrenze              bool  `json:"freigrenze"`               // Year up to the quarter within the Freigrenze
+	UmlageJahr              int64 `json:"umlage_jahr"`
+	UmlageVorquartale       int64 `json:"umlage_vorquartale"`
+	Umlage                  int64 `json:"umlage"` // Due for the quarter
+
+	Faellig time.Time `json:"faellig"`
+}
+
+// Input creates a Meldung
+type Input struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Year      int       `json:"year"`
+	Quarter   int       `json:"quarter"`
+}
+
+// ListFilter filters Meldungen
+type ListFilter struct {
+	TenantID  uuid.UUID
+	AccountID *uuid.UUID
+	Year      *int
+	Limit     int
+	Offset    int
+}
+
+// Frist is the payment deadline of a quarter's KU1
+type Frist struct {
+	Periode string    `json:"periode"` // Q1/2025
+	Faellig time.Time `json:"faellig"`
+}
+
+// Offen is a company with UVA filings in a quarter whose KU1 has not been
+// submitted yet
+type Offen struct {
+	TenantID    uuid.UUID
+	AccountID   uuid.UUID
+	AccountName string
+}
+
+// Admin is a tenant owner or admin reminded of deadlines
+type Admin struct {
+	TenantID uuid.UUID
+	Name     string
+	Email    string
+}
@@ -0,0 +1,181 @@
+package kleinunternehmer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// Handler handles Kleinunternehmer HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new Kleinunternehmer handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers Kleinunternehmer routes
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	// Admin-only: configuration
+	router.Handle("PUT /api/v1/kleinunternehmer/settings", requireAuth(requireAdmin(http.HandlerFunc(h.UpdateSettings))))
+
+	// Member access: status, alerts and on-demand check
+	router.Handle("GET /api/v1/kleinunternehmer/settings", requireAuth(http.HandlerFunc(h.GetSettings)))
+	router.Handle("GET /api/v1/kleinunternehmer/status", requireAuth(http.HandlerFunc(h.GetStatus)))
+	router.Handle("GET /api/v1/kleinunternehmer/alerts", requireAuth(http.HandlerFunc(h.ListAlerts)))
+	router.Handle("POST /api/v1/kleinunternehmer/check", requireAuth(http.HandlerFunc(h.Check)))
+}
+
+// GetSettings handles GET /api/v1/kleinunternehmer/settings
+func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.getTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.service.GetSettings(r.Context(), tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, toSettingsResponse(settings))
+}
+
+// UpdateSettings handles PUT /api/v1/kleinunternehmer/settings
+func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.getTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var input UpdateSettingsInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(r.Context(), tenantID, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, toSettingsResponse(settings))
+}
+
+// GetStatus handles GET /api/v1/kleinunternehmer/status
+func (h *Handler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.getTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.Status(r.Context(), tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, toStatusResponse(status))
+}
+
+// ListAlerts handles GET /api/v1/kleinunternehmer/alerts
+func (h *Handler) ListAlerts(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.getTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	alerts, err := h.service.ListAlerts(r.Context(), tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	if alerts == nil {
+		alerts = []*Alert{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items": alerts,
+	})
+}
+
+// Check handles POST /api/v1/kleinunternehmer/check
+func (h *Handler) Check(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.getTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	status, alert, err := h.service.Check(r.Context(), tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"status": toStatusResponse(status),
+		"alert":  alert,
+	})
+}
+
+// Helper methods
+
+func (h *Handler) getTenantID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotEnabled):
+		api.Conflict(w, "kleinunternehmer monitoring is not enabled")
+	case errors.Is(err, ErrInvalidThreshold):
+		api.BadRequest(w, "threshold must be positive")
+	case errors.Is(err, ErrInvalidWarnPercent):
+		api.BadRequest(w, "warn_percent must be between 1 and 99")
+	default:
+		api.InternalError(w)
+	}
+}
+
+func toSettingsResponse(s *Settings) *SettingsResponse {
+	resp := &SettingsResponse{
+		Enabled:         s.Enabled,
+		Threshold:       float64(s.ThresholdCents) / 100,
+		CustomThreshold: s.CustomThreshold,
+		WarnPercent:     s.WarnPercent,
+	}
+	if !s.UpdatedAt.IsZero() {
+		resp.UpdatedAt = s.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	return resp
+}
+
+func toStatusResponse(s *Status) *StatusResponse {
+	return &StatusResponse{
+		Year:                 s.Year,
+		AsOf:                 s.AsOf,
+		Revenue:              float64(s.Revenue) / 100,
+		PreviousYearRevenue:  float64(s.PreviousYearRevenue) / 100,
+		Threshold:            float64(s.Threshold) / 100,
+		Remaining:            float64(s.Remaining) / 100,
+		UsedPercent:          s.UsedPercent,
+		DailyRunRate:         float64(s.DailyRunRate) / 100,
+		ProjectedYearRevenue: float64(s.ProjectedYearRevenue) / 100,
+		ProjectedBreachDate:  s.ProjectedBreachDate,
+		Level:                s.Level,
+		Guidance:             s.Guidance,
+	}
+}
@@ -0,0 +1,205 @@
+package kleinunternehmer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles Kleinunternehmer database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new Kleinunternehmer repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// GetSettings returns the settings of a tenant, or defaults if none are stored.
+// ThresholdCents is only set for a custom threshold.
+func (r *Repository) GetSettings(ctx context.Context, tenantID uuid.UUID) (*Settings, error) {
+	query := `
+		SELECT tenant_id, enabled, threshold_cents, warn_percent, updated_at
+		FROM kleinunternehmer_settings
+		WHERE tenant_id = $1`
+
+	var s Settings
+	var threshold *int64
+	err := r.db.QueryRow(ctx, query, tenantID).Scan(
+		&s.TenantID, &s.Enabled, &threshold, &s.WarnPercent, &s.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &Settings{
+				TenantID:    tenantID,
+				WarnPercent: DefaultWarnPercent,
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to get kleinunternehmer settings: %w", err)
+	}
+	if threshold != nil {
+		s.ThresholdCents = *threshold
+		s.CustomThreshold = true
+	}
+
+	return &s, nil
+}
+
+// SaveSettings creates or updates the settings of a tenant
+func (r *Repository) SaveSettings(ctx context.Context, s *Settings) error {
+	s.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO kleinunternehmer_settings (tenant_id, enabled, threshold_cents, warn_percent, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			threshold_cents = EXCLUDED.threshold_cents,
+			warn_percent = EXCLUDED.warn_percent,
+			updated_at = EXCLUDED.updated_at`
+
+	// NULL follows the statutory threshold of each year
+	var threshold *int64
+	if s.CustomThreshold {
+		threshold = &s.ThresholdCents
+	}
+
+	_, err := r.db.Exec(ctx, query, s.TenantID, s.Enabled, threshold, s.WarnPercent, s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save kleinunternehmer settings: %w", err)
+	}
+	return nil
+}
+
+// ListEnabledTenants returns all tenants with Kleinunternehmer monitoring enabled
+func (r *Repository) ListEnabledTenants(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `SELECT tenant_id FROM kleinunternehmer_settings WHERE enabled = true`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kleinunternehmer tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, id)
+	}
+	return tenants, rows.Err()
+}
+
+// Revenue sums the gross revenue of issued invoices in [from, to]. Credit notes reduce the revenue.
+func (r *Repository) Revenue(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(
+			CASE WHEN invoice_type = '381' THEN -tax_inclusive_amount ELSE tax_inclusive_amount END
+		), 0)
+		FROM invoices
+		WHERE tenant_id = $1
+		AND status NOT IN ('draft', 'cancelled')
+		AND issue_date BETWEEN $2 AND $3`
+
+	var revenue int64
+	if err := r.db.QueryRow(ctx, query, tenantID, from, to).Scan(&revenue); err != nil {
+		return 0, fmt.Errorf("failed to sum revenue: %w", err)
+	}
+	return revenue, nil
+}
+
+// GetHighestAlert returns the most severe alert already raised for a tenant and year
+func (r *Repository) GetHighestAlert(ctx context.Context, tenantID uuid.UUID, year int) (*Alert, error) {
+	query := `
+		SELECT id, tenant_id, year, level, revenue, action_item_id, created_at
+		FROM kleinunternehmer_alerts
+		WHERE tenant_id = $1 AND year = $2
+		ORDER BY severity DESC
+		LIMIT 1`
+
+	var a Alert
+	var actionItemID uuid.NullUUID
+	err := r.db.QueryRow(ctx, query, tenantID, year).Scan(
+		&a.ID, &a.TenantID, &a.Year, &a.Level, &a.Revenue, &actionItemID, &a.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get alert: %w", err)
+	}
+	if actionItemID.Valid {
+		a.ActionItemID = &actionItemID.UUID
+	}
+	return &a, nil
+}
+
+// ListAlerts lists the alerts raised for a tenant, newest first
+func (r *Repository) ListAlerts(ctx context.Context, tenantID uuid.UUID) ([]*Alert, error) {
+	query := `
+		SELECT id, tenant_id, year, level, revenue, action_item_id, created_at
+		FROM kleinunternehmer_alerts
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []*Alert
+	for rows.Next() {
+		var a Alert
+		var actionItemID uuid.NullUUID
+		if err := rows.Scan(&a.ID, &a.TenantID, &a.Year, &a.Level, &a.Revenue, &actionItemID, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan alert: %w", err)
+		}
+		if actionItemID.Valid {
+			a.ActionItemID = &actionItemID.UUID
+		}
+		alerts = append(alerts, &a)
+	}
+	return alerts, rows.Err()
+}
+
+// RaiseAlert stores an alert together with a system action item carrying the guidance
+func (r *Repository) RaiseAlert(ctx context.Context, a *Alert, title, description, priority string, dueDate *time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var actionItemID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO action_items (tenant_id, title, description, action_type, priority, status, due_date, source)
+		VALUES ($1, $2, $3, 'review', $4, 'pending', $5, 'system')
+		RETURNING id`,
+		a.TenantID, title, description, priority, dueDate,
+	).Scan(&actionItemID)
+	if err != nil {
+		return fmt.Errorf("failed to create action item: %w", err)
+	}
+	a.ActionItemID = &actionItemID
+
+	a.ID = uuid.New()
+	a.CreatedAt = time.Now()
+	_, err = tx.Exec(ctx, `
+		INSERT INTO kleinunternehmer_alerts (id, tenant_id, year, level, severity, revenue, action_item_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tenant_id, year, level) DO NOTHING`,
+		a.ID, a.TenantID, a.Year, a.Level, Severity(a.Level), a.Revenue, a.ActionItemID, a.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record alert: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
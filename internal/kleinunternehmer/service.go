@@ -0,0 +1,300 @@
+package kleinunternehmer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/refdata"
+)
+
+var (
+	ErrInvalidThreshold   = errors.New("threshold must be positive")
+	ErrInvalidWarnPercent = errors.New("warn_percent must be between 1 and 99")
+	ErrNotEnabled         = errors.New("kleinunternehmer monitoring is not enabled")
+)
+
+// Service handles Kleinunternehmer threshold monitoring
+type Service struct {
+	repo *Repository
+	now  func() time.Time
+}
+
+// NewService creates a new Kleinunternehmer service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// GetSettings returns the settings of a tenant with the threshold of the current year
+func (s *Service) GetSettings(ctx context.Context, tenantID uuid.UUID) (*Settings, error) {
+	settings, err := s.repo.GetSettings(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	settings.ThresholdCents = Threshold(settings, s.now().Year())
+	return settings, nil
+}
+
+// Threshold returns the turnover limit a tenant is checked against in a year:
+// the custom threshold if one is set, otherwise the statutory limit of the year
+func Threshold(settings *Settings, year int) int64 {
+	if settings.CustomThreshold {
+		return settings.ThresholdCents
+	}
+	return refdata.ForYear(year).KleinunternehmerGrenze
+}
+
+// UpdateSettings updates the settings of a tenant
+func (s *Service) UpdateSettings(ctx context.Context, tenantID uuid.UUID, input *UpdateSettingsInput) (*Settings, error) {
+	settings, err := s.repo.GetSettings(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Enabled != nil {
+		settings.Enabled = *input.Enabled
+	}
+	if input.ThresholdCents != nil {
+		if *input.ThresholdCents <= 0 {
+			return nil, ErrInvalidThreshold
+		}
+		settings.ThresholdCents = *input.ThresholdCents
+		settings.CustomThreshold = true
+	}
+	if input.StatutoryThreshold {
+		settings.CustomThreshold = false
+	}
+	if input.WarnPercent != nil {
+		if *input.WarnPercent < 1 || *input.WarnPercent > 99 {
+			return nil, ErrInvalidWarnPercent
+		}
+		settings.WarnPercent = *input.WarnPercent
+	}
+
+	if err := s.repo.SaveSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	settings.ThresholdCents = Threshold(settings, s.now().Year())
+	return settings, nil
+}
+
+// Status evaluates the current threshold position of a tenant
+func (s *Service) Status(ctx context.Context, tenantID uuid.UUID) (*Status, error) {
+	settings, err := s.repo.GetSettings(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled {
+		return nil, ErrNotEnabled
+	}
+	return s.evaluate(ctx, settings, s.now())
+}
+
+// ListAlerts lists the alerts raised for a tenant
+func (s *Service) ListAlerts(ctx context.Context, tenantID uuid.UUID) ([]*Alert, error) {
+	return s.repo.ListAlerts(ctx, tenantID)
+}
+
+// Check evaluates a tenant and raises an alert with a guidance action item when the
+// level got more severe than any alert already raised this year.
+// It returns the status and the new alert, if one was raised.
+func (s *Service) Check(ctx context.Context, tenantID uuid.UUID) (*Status, *Alert, error) {
+	status, err := s.Status(ctx, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if status.Level == LevelOK {
+		return status, nil, nil
+	}
+
+	last, err := s.repo.GetHighestAlert(ctx, tenantID, status.Year)
+	if err != nil {
+		return nil, nil, err
+	}
+	if last != nil && Severity(last.Level) >= Severity(status.Level) {
+		return status, nil, nil
+	}
+
+	alert := &Alert{
+		TenantID: tenantID,
+		Year:     status.Year,
+		Level:    status.Level,
+		Revenue:  status.Revenue,
+	}
+
+	title, priority := alertTitle(status)
+	description := strings.Join(status.Guidance, "\n")
+	dueDate := alertDueDate(status, s.now())
+
+	if err := s.repo.RaiseAlert(ctx, alert, title, description, priority, dueDate); err != nil {
+		return nil, nil, err
+	}
+	return status, alert, nil
+}
+
+// CheckAll checks all tenants with monitoring enabled and returns the number of alerts raised
+func (s *Service) CheckAll(ctx context.Context) (int, error) {
+	tenants, err := s.repo.ListEnabledTenants(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	raised := 0
+	var errs []error
+	for _, tenantID := range tenants {
+		_, alert, err := s.Check(ctx, tenantID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenantID, err))
+			continue
+		}
+		if alert != nil {
+			raised++
+		}
+	}
+	return raised, errors.Join(errs...)
+}
+
+func (s *Service) evaluate(ctx context.Context, settings *Settings, asOf time.Time) (*Status, error) {
+	year := asOf.Year()
+	yearStart := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := time.Date(year, asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+
+	revenue, err := s.repo.Revenue(ctx, settings.TenantID, yearStart, day)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.repo.Revenue(ctx, settings.TenantID, yearStart.AddDate(-1, 0, 0), yearStart.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	return Evaluate(revenue, previous, Threshold(settings, year), settings.WarnPercent, day), nil
+}
+
+// Evaluate computes the threshold position from year-to-date and previous-year revenue.
+// The breach date is projected linearly from the run-rate since January 1st.
+func Evaluate(revenue, previousYearRevenue, threshold int64, warnPercent int, asOf time.Time) *Status {
+	year := asOf.Year()
+	yearStart := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	daysInYear := yearStart.AddDate(1, 0, 0).Sub(yearStart).Hours() / 24
+	daysElapsed := asOf.YearDay()
+
+	status := &Status{
+		Year:                year,
+		AsOf:                asOf.Format("2006-01-02"),
+		Revenue:             revenue,
+		PreviousYearRevenue: previousYearRevenue,
+		Threshold:           threshold,
+		Remaining:           threshold - revenue,
+	}
+	if status.Remaining < 0 {
+		status.Remaining = 0
+	}
+	if threshold > 0 {
+		status.UsedPercent = math.Round(float64(revenue)/float64(threshold)*10000) / 100
+	}
+	if revenue > 0 {
+		status.DailyRunRate = revenue / int64(daysElapsed)
+		status.ProjectedYearRevenue = int64(math.Round(float64(revenue) / float64(daysElapsed) * daysInYear))
+	}
+
+	var breach *time.Time
+	if revenue <= threshold && status.DailyRunRate > 0 {
+		daysToBreach := int(math.Ceil(float64(threshold-revenue+1) / float64(status.DailyRunRate)))
+		b := asOf.AddDate(0, 0, daysToBreach)
+		if b.Year() == year {
+			breach = &b
+			d := b.Format("2006-01-02")
+			status.ProjectedBreachDate = &d
+		}
+	}
+
+	switch {
+	case previousYearRevenue > threshold || revenue > threshold:
+		status.Level = LevelExceeded
+	case status.UsedPercent >= 95 || (breach != nil && breach.Sub(asOf) <= 30*24*time.Hour):
+		status.Level = LevelCritical
+	case status.UsedPercent >= float64(warnPercent):
+		status.Level = LevelWarning
+	case breach != nil:
+		status.Level = LevelWatch
+	default:
+		status.Level = LevelOK
+	}
+
+	status.Guidance = guidance(status)
+	return status
+}
+
+func guidance(status *Status) []string {
+	limit := formatEUR(status.Threshold)
+
+	switch status.Level {
+	case LevelExceeded:
+		if status.PreviousYearRevenue > status.Threshold {
+			return []string{
+				fmt.Sprintf("Revenue of %d exceeded %s; the Kleinunternehmerregelung does not apply for %d.", status.Year-1, limit, status.Year),
+				"All invoices of the current year must show Austrian VAT and the UID number.",
+				"Check whether invoices already issued this year need to be corrected and file UVAs for all periods.",
+			}
+		}
+		return []string{
+			fmt.Sprintf("Revenue of %d exceeded %s; the exemption ends with the invoice that crossed the limit.", status.Year, limit),
+			"That invoice and all later invoices must show Austrian VAT and the UID number instead of the Kleinunternehmer note.",
+			"File UVAs from the month of the breach and apply for a UID number if none exists yet.",
+			fmt.Sprintf("The exemption is also excluded for %d.", status.Year+1),
+		}
+	case LevelCritical, LevelWarning:
+		lines := []string{
+			fmt.Sprintf("%.2f %% of the %s limit are used.", status.UsedPercent, limit),
+			"The invoice that crosses the limit is already taxable; check large offers and orders before invoicing.",
+			"Prepare invoice templates with VAT and the UID number, and consider opting for standard taxation.",
+		}
+		if status.ProjectedBreachDate != nil {
+			lines = append(lines, fmt.Sprintf("At the current run-rate the limit is reached on %s.", *status.ProjectedBreachDate))
+		}
+		return lines
+	case LevelWatch:
+		return []string{
+			fmt.Sprintf("At the current run-rate the limit of %s is reached on %s.", limit, *status.ProjectedBreachDate),
+			"Review expected revenue for the rest of the year with your tax advisor.",
+		}
+	default:
+		return nil
+	}
+}
+
+func alertTitle(status *Status) (string, string) {
+	switch status.Level {
+	case LevelExceeded:
+		return fmt.Sprintf("Kleinunternehmer limit exceeded (%d)", status.Year), "critical"
+	case LevelCritical:
+		return fmt.Sprintf("Kleinunternehmer limit almost reached (%d)", status.Year), "critical"
+	case LevelWarning:
+		return fmt.Sprintf("Kleinunternehmer limit: %.0f %% used (%d)", status.UsedPercent, status.Year), "high"
+	default:
+		return fmt.Sprintf("Kleinunternehmer limit projected to be reached (%d)", status.Year), "medium"
+	}
+}
+
+func alertDueDate(status *Status, now time.Time) *time.Time {
+	if status.Level == LevelExceeded {
+		d := now.AddDate(0, 0, 7)
+		return &d
+	}
+	if status.ProjectedBreachDate != nil {
+		if d, err := time.Parse("2006-01-02", *status.ProjectedBreachDate); err == nil {
+			return &d
+		}
+	}
+	return nil
+}
+
+func formatEUR(cents int64) string {
+	return fmt.Sprintf("%d EUR", cents/100)
+}
@@ -0,0 +1,103 @@
+package kleinunternehmer
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultWarnPercent is the share of the threshold at which a warning is raised
+const DefaultWarnPercent = 80
+
+// Alert level constants, in ascending severity
+const (
+	LevelOK       = "ok"
+	LevelWatch    = "watch"    // Run-rate projects a breach within the year
+	LevelWarning  = "warning"  // Revenue reached the warn percentage
+	LevelCritical = "critical" // Breach projected within the next 30 days or 95 % reached
+	LevelExceeded = "exceeded" // Threshold crossed in the current or previous year
+)
+
+var levelRank = map[string]int{
+	LevelOK:       0,
+	LevelWatch:    1,
+	LevelWarning:  2,
+	LevelCritical: 3,
+	LevelExceeded: 4,
+}
+
+// Severity returns the rank of a level for comparisons
+func Severity(level string) int {
+	return levelRank[level]
+}
+
+// Settings holds the Kleinunternehmer configuration of a tenant
+type Settings struct {
+	TenantID        uuid.UUID `json:"tenant_id"`
+	Enabled         bool      `json:"enabled"`
+	ThresholdCents  int64     `json:"threshold"`        // Effective threshold of the current year
+	CustomThreshold bool      `json:"custom_threshold"` // False: statutory limit of each year from the reference data
+	WarnPercent     int       `json:"warn_percent"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// UpdateSettingsInput represents input for updating the Kleinunternehmer settings
+type UpdateSettingsInput struct {
+	Enabled        *bool  `json:"enabled,omitempty"`
+	ThresholdCents *int64 `json:"threshold,omitempty"`
+	// StatutoryThreshold drops a custom threshold in favour of the statutory limit
+	StatutoryThreshold bool `json:"statutory_threshold,omitempty"`
+	WarnPercent        *int `json:"warn_percent,omitempty"`
+}
+
+// Status is the evaluated threshold position of a tenant
+type Status struct {
+	Year                 int      `json:"year"`
+	AsOf                 string   `json:"as_of"`
+	Revenue              int64    `json:"revenue"`               // Gross revenue year-to-date in cents
+	PreviousYearRevenue  int64    `json:"previous_year_revenue"` // Gross revenue of the previous year in cents
+	Threshold            int64    `json:"threshold"`
+	Remaining            int64    `json:"remaining"`
+	UsedPercent          float64  `json:"used_percent"`
+	DailyRunRate         int64    `json:"daily_run_rate"`
+	ProjectedYearRevenue int64    `json:"projected_year_revenue"`
+	ProjectedBreachDate  *string  `json:"projected_breach_date,omitempty"`
+	Level                string   `json:"level"`
+	Guidance             []string `json:"guidance,omitempty"`
+}
+
+// Alert records that an alert level was raised for a tenant and year
+type Alert struct {
+	ID           uuid.UUID  `json:"id"`
+	TenantID     uuid.UUID  `json:"tenant_id"`
+	Year         int        `json:"year"`
+	Level        string     `json:"level"`
+	Revenue      int64      `json:"revenue"`
+	ActionItemID *uuid.UUID `json:"action_item_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// StatusResponse is the API response format (amounts in EUR)
+type StatusResponse struct {
+	Year                 int      `json:"year"`
+	AsOf                 string   `json:"as_of"`
+	Revenue              float64  `json:"revenue"`
+	PreviousYearRevenue  float64  `json:"previous_year_revenue"`
+	Threshold            float64  `json:"threshold"`
+	Remaining            float64  `json:"remaining"`
+	UsedPercent          float64  `json:"used_percent"`
+	DailyRunRate         float64  `json:"daily_run_rate"`
+	ProjectedYearRevenue float64  `json:"projected_year_revenue"`
+	ProjectedBreachDate  *string  `json:"projected_breach_date,omitempty"`
+	Level                string   `json:"level"`
+	Guidance             []string `json:"guidance,omitempty"`
+}
+
+// SettingsResponse is the API response format for settings (amounts in EUR)
+type SettingsResponse struct {
+	Enabled         bool    `json:"enabled"`
+	Threshold       float64 `json:"threshold"`
+	CustomThreshold bool    `json:"custom_threshold"`
+	WarnPercent     int     `json:"warn_percent"`
+	UpdatedAt       string  `json:"updated_at,omitempty"`
+}
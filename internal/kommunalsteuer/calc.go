@@ -0,0 +1,351 @@
+package kommunalsteuer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/elda"
+)
+
+// Kommunalsteuer (§§ 9, 17 KommStG): 3% of the Bruttobezüge of a month. If
+// they do not exceed the Freigrenze, the Freibetrag is deducted; it is split
+// among the Gemeinden by their share of the Bemessungsgrundlage.
+const (
+	KommStSatzProzent = 3
+	KommStFreigrenze  = 146000 // 1,460 € in cents
+	KommStFreibetrag  = 109500 // 1,095 € in cents
+)
+
+// DGAAltersgrenze is the age after which employees are exempt from the
+// Dienstgeberabgabe
+const DGAAltersgrenze = 55
+
+// DGAWochensatz returns the Dienstgeberabgabe per employee and started
+// week in cents: 2 € until 2024, 6 € from 2025
+func DGAWochensatz(year int) int64 {
+	if year >= 2025 {
+		return 600
+	}
+	return 200
+}
+
+// Position is the Entgelt of an employee in a month, from an mBGM position
+type Position struct {
+	Monat          int
+	SVNummer       string
+	Geburtsdatum   *time.Time
+	Beitragsgruppe string
+	// Entgelt is the Beitragsgrundlage plus Sonderzahlung in cents
+	Entgelt       int64
+	Von           *time.Time // Employment period within the month, if not the whole month
+	Bis           *time.Time
+	Wochenstunden *float64
+}
+
+// Berechnung is the Kommunalsteuer and Dienstgeberabgabe of a year. Amounts
+// are in cents.
+type Berechnung struct {
+	Monate              []Monat         `json:"monate"`
+	Gemeinden           []GemeindeSumme `json:"gemeinden"`
+	Bemessungsgrundlage int64           `json:"bemessungsgrundlage"`
+	Kommunalsteuer      int64           `json:"kommunalsteuer"`
+	// Dienstgeberabgabe is set if a Betriebsstätte is in Wien
+	Dienstgeberabgabe *DGASumme `json:"dienstgeberabgabe,omitempty"`
+}
+
+// Monat is the Kommunalsteuer and Dienstgeberabgabe of a month with Entgelt
+type Monat struct {
+	Monat               int              `json:"monat"`
+	Bemessungsgrundlage int64            `json:"bemessungsgrundlage"`
+	Freibetrag          int64            `json:"freibetrag"`
+	Kommunalsteuer      int64            `json:"kommunalsteuer"`
+	Gemeinden           []GemeindeBetrag `json:"gemeinden"`
+	Dienstgeberabgabe   *DGAMonat        `json:"dienstgeberabgabe,omitempty"`
+}
+
+// GemeindeBetrag is the Kommunalsteuer of a Gemeinde in a month, after its
+// share of the Freibetrag
+type GemeindeBetrag struct {
+	Gemeindekennziffer  string `json:"gemeindekennziffer"`
+	Bemessungsgrundlage int64  `json:"bemessungsgrundlage"`
+	Kommunalsteuer      int64  `json:"kommunalsteuer"`
+}
+
+// GemeindeSumme is the Kommunalsteuer of a Gemeinde in the year
+type GemeindeSumme struct {
+	Gemeindekennziffer  string `json:"gemeindekennziffer"`
+	Bezeichnung         string `json:"bezeichnung,omitempty"`
+	Bemessungsgrundlage int64  `json:"bemessungsgrundlage"`
+	Kommunalsteuer      int64  `json:"kommunalsteuer"`
+}
+
+// DGAMonat is the Dienstgeberabgabe of a month
+type DGAMonat struct {
+	Dienstnehmer int   `json:"dienstnehmer"`
+	Befreit      int   `json:"befreit"`
+	Wochen       int   `json:"wochen"`
+	Abgabe       int64 `json:"abgabe"`
+}
+
+// DGASumme is the Dienstgeberabgabe of the year
+type DGASumme struct {
+	Wochen int   `json:"wochen"`
+	Abgabe int64 `json:"abgabe"`
+}
+
+// Berechne calculates the Kommunalsteuer and Dienstgeberabgabe of a year
+// from the Entgelt of the employees. Only the Betriebsstätten in Wien owe
+// the Dienstgeberabgabe.
+//
+// The Entgelt is that of the mBGM, i.e. capped at the
+// Höchstbeitragsgrundlage; Bezüge above it are not included.
+func Berechne(year int, positions []Position, betriebsstaetten []Betriebsstaette) *Berechnung {
+	b := &Berechnung{Monate: []Monat{}, Gemeinden: []GemeindeSumme{}}
+	if len(betriebsstaetten) == 0 {
+		return b
+	}
+
+	gemeindeOf := make(map[string]string)
+	for i := len(betriebsstaetten) - 1; i >= 0; i-- {
+		for _, sv := range betriebsstaetten[i].SVNummern {
+			gemeindeOf[sv] = betriebsstaetten[i].Gemeindekennziffer
+		}
+	}
+	gemeindeOfPosition := func(p Position) string {
+		if g, ok := gemeindeOf[p.SVNummer]; ok {
+			return g
+		}
+		return betriebsstaetten[0].Gemeindekennziffer
+	}
+
+	sums := make(map[string]*GemeindeSumme)
+	for _, bs := range betriebsstaetten {
+		b.Gemeinden = append(b.Gemeinden, GemeindeSumme{Gemeindekennziffer: bs.Gemeindekennziffer, Bezeichnung: bs.Bezeichnung})
+		if bs.Gemeindekennziffer == GemeindeWien {
+			b.Dienstgeberabgabe = &DGASumme{}
+		}
+	}
+	for i := range b.Gemeinden {
+		sums[b.Gemeinden[i].Gemeindekennziffer] = &b.Gemeinden[i]
+	}
+
+	for month := 1; month <= 12; month++ {
+		bases := make(map[string]int64)
+		var total int64
+		var wien []Position
+		for _, p := range positions {
+			if p.Monat != month {
+				continue
+			}
+			g := gemeindeOfPosition(p)
+			bases[g] += p.Entgelt
+			total += p.Entgelt
+			if g == GemeindeWien {
+				wien = append(wien, p)
+			}
+		}
+		if total <= 0 && len(wien) == 0 {
+			continue
+		}
+
+		m := Monat{Monat: month, Bemessungsgrundlage: total, Gemeinden: []GemeindeBetrag{}}
+		if total > 0 && total <= KommStFreigrenze {
+			m.Freibetrag = min(KommStFreibetrag, total)
+		}
+
+		// The Freibetrag is split by share; the last Gemeinde with Entgelt
+		// takes the rounding remainder
+		remaining := m.Freibetrag
+		last := ""
+		for _, g := range b.Gemeinden {
+			if bases[g.Gemeindekennziffer] > 0 {
+				last = g.Gemeindekennziffer
+			}
+		}
+		for _, g := range b.Gemeinden {
+			base := bases[g.Gemeindekennziffer]
+			if base <= 0 {
+				continue
+			}
+			share := m.Freibetrag * base / total
+			if g.Gemeindekennziffer == last {
+				share = remaining
+			}
+			remaining -= share
+
+			tax := ((base-share)*KommStSatzProzent + 50) / 100
+			m.Gemeinden = append(m.Gemeinden, GemeindeBetrag{
+				Gemeindekennziffer:  g.Gemeindekennziffer,
+				Bemessungsgrundlage: base,
+				Kommunalsteuer:      tax,
+			})
+			m.Kommunalsteuer += tax
+			sums[g.Gemeindekennziffer].Bemessungsgrundlage += base
+			sums[g.Gemeindekennziffer].Kommunalsteuer += tax
+		}
+
+		if b.Dienstgeberabgabe != nil {
+			m.Dienstgeberabgabe = berechneDGA(year, month, wien)
+			b.Dienstgeberabgabe.Wochen += m.Dienstgeberabgabe.Wochen
+			b.Dienstgeberabgabe.Abgabe += m.Dienstgeberabgabe.Abgabe
+		}
+
+		b.Bemessungsgrundlage += m.Bemessungsgrundlage
+		b.Kommunalsteuer += m.Kommunalsteuer
+		b.Monate = append(b.Monate, m)
+	}
+
+	return b
+}
+
+// berechneDGA calculates the Dienstgeberabgabe of a month from the
+// positions of the employees in Wien
+func berechneDGA(year, month int, positions []Position) *DGAMonat {
+	d := &DGAMonat{}
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	employees := make(map[string]bool)
+	exempt := make(map[string]bool)
+	for _, p := range positions {
+		if DGABefreit(p, monthStart) {
+			exempt[p.SVNummer] = true
+			continue
+		}
+		employees[p.SVNummer] = true
+		d.Wochen += Wochen(year, month, p.Von, p.Bis)
+	}
+	d.Dienstnehmer = len(employees)
+	d.Befreit = len(exempt)
+	d.Abgabe = int64(d.Wochen) * DGAWochensatz(year)
+	return d
+}
+
+// DGABefreit reports whether the employee of a position is exempt from the
+// Dienstgeberabgabe in the month starting at monthStart: Lehrlinge,
+// geringfügig Beschäftigte, freie Dienstnehmer, employees working at most
+// 10 hours a week and employees older than 55
+func DGABefreit(p Position, monthStart time.Time) bool {
+	gruppe := strings.ToUpper(strings.TrimSpace(p.Beitragsgruppe))
+	switch {
+	case strings.HasPrefix(gruppe, "L"):
+		return true
+	case gruppe == "A3", gruppe == "D3", gruppe == "N1", gruppe == "N2":
+		return true
+	case p.Wochenstunden != nil && *p.Wochenstunden > 0 && *p.Wochenstunden <= 10:
+		return true
+	}
+
+	birth := p.Geburtsdatum
+	if birth == nil {
+		if encoded, err := elda.ExtractBirthDateFromSVNummer(p.SVNummer); err == nil {
+			birth = &encoded
+		}
+	}
+	return birth != nil && !birth.AddDate(DGAAltersgrenze, 0, 0).After(monthStart)
+}
+
+// Wochen returns the weeks of an employment from von to bis (the whole
+// month if nil) the Dienstgeberabgabe is due for in a month: every week
+// starting on a Monday of the month, and the started week if the employment
+// began after the first of the month on another day. An employment from
+// the first continues the last week of the previous month.
+func Wochen(year, month int, von, bis *time.Time) int {
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, -1)
+	startedWeek := false
+	if von != nil && von.After(start) {
+		start = time.Date(von.Year(), von.Month(), von.Day(), 0, 0, 0, 0, time.UTC)
+		startedWeek = start.Weekday() != time.Monday
+	}
+	if bis != nil && bis.Before(end) {
+		end = time.Date(bis.Year(), bis.Month(), bis.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	if end.Before(start) {
+		return 0
+	}
+
+	weeks := 0
+	if startedWeek {
+		weeks++
+	}
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() == time.Monday {
+			weeks++
+		}
+	}
+	return weeks
+}
+
+// ErklaerungFrist returns the deadline of the Kommunalsteuererklärung and
+// the Dienstgeberabgabe-Erklärung of a year: 31 March of the following
+// year, or the next working day
+func ErklaerungFrist(year int) time.Time {
+	return NaechsterWerktag(time.Date(year+1, time.March, 31, 0, 0, 0, 0, time.UTC))
+}
+
+// ZahlungFrist returns the deadline for paying the Kommunalsteuer and the
+// Dienstgeberabgabe of a month: the 15th of the following month, or the
+// next working day
+func ZahlungFrist(year, month int) time.Time {
+	return NaechsterWerktag(time.Date(year, time.Month(month)+1, 15, 0, 0, 0, 0, time.UTC))
+}
+
+// Fristen returns the payment deadlines of the months of a year and the
+// filing deadlines of its returns, in order
+func Fristen(year int) []Frist {
+	fristen := make([]Frist, 0, 26)
+	for month := 1; month <= 12; month++ {
+		periode := fmt.Sprintf("%02d/%d", month, year)
+		due := ZahlungFrist(year, month)
+		fristen = append(fristen,
+			Frist{Art: FristKommStZahlung, Periode: periode, Faellig: due},
+			Frist{Art: FristDGAZahlung, Periode: periode, Faellig: due})
+	}
+	due := ErklaerungFrist(year)
+	periode := fmt.Sprintf("%d", year)
+	return append(fristen,
+		Frist{Art: FristKommStErklaerung, Periode: periode, Faellig: due},
+		Frist{Art: FristDGAErklaerung, Periode: periode, Faellig: due})
+}
+
+// NaechsterWerktag moves a deadline on a weekend or public holiday to the
+// next working day (§ 108 Abs. 3 BAO)
+func NaechsterWerktag(d time.Time) time.Time {
+	for d.Weekday() == time.Saturday || d.Weekday() == time.Sunday || feiertag(d) {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+// feiertag reports whether d is an Austrian public holiday
+func feiertag(d time.Time) bool {
+	switch int(d.Month())*100 + d.Day() {
+	case 101, 106, 501, 815, 1026, 1101, 1208, 1225, 1226:
+		return true
+	}
+	easter := ostersonntag(d.Year())
+	// Ostermontag, Christi Himmelfahrt, Pfingstmontag, Fronleichnam
+	for _, offset := range []int{1, 39, 50, 60} {
+		if d.Equal(easter.AddDate(0, 0, offset)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ostersonntag returns Easter Sunday of a year (anonymous Gregorian
+// algorithm)
+func ostersonntag(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
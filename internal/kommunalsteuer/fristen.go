@@ -0,0 +1,99 @@
+package kommunalsteuer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/timezone"
+)
+
+// ReminderDays are the days before the filing deadline on which tenant
+// admins are reminded of Erklärungen not submitted yet
+var ReminderDays = []int{30, 14, 7, 3, 1}
+
+// FristNotifier mails the reminders of filing deadlines; email.Service
+// implements it
+type FristNotifier interface {
+	SendKommunalsteuerFrist(ctx context.Context, to string, params email.KommunalsteuerFristParams) error
+}
+
+// SetNotifier enables the deadline reminders; appURL is linked in the mails
+func (s *Service) SetNotifier(n FristNotifier, appURL string) {
+	s.notifier = n
+	s.appURL = appURL
+}
+
+// ReminderYear returns the year whose Erklärung is due on one of the
+// ReminderDays after today, and the days left
+func ReminderYear(today time.Time) (int, int, bool) {
+	year := today.Year() - 1
+	daysLeft := int(ErklaerungFrist(year).Sub(today).Hours() / 24)
+	for _, d := range ReminderDays {
+		if daysLeft == d {
+			return year, daysLeft, true
+		}
+	}
+	return 0, 0, false
+}
+
+// RemindFristen mails the admins of tenants with Entgelt in the previous
+// year whose Erklärung has not been submitted, on the ReminderDays before
+// the deadline. Run it daily. It returns the mails sent.
+func (s *Service) RemindFristen(ctx context.Context) (int, error) {
+	if s.notifier == nil {
+		return 0, nil
+	}
+	today := timezone.Today(s.now(), timezone.Vienna)
+	year, daysLeft, ok := ReminderYear(today)
+	if !ok {
+		return 0, nil
+	}
+
+	offen, err := s.repo.ListOffen(ctx, year)
+	if err != nil {
+		return 0, err
+	}
+	if len(offen) == 0 {
+		return 0, nil
+	}
+
+	dienstgeber := make(map[uuid.UUID][]string)
+	var tenantIDs []uuid.UUID
+	for _, o := range offen {
+		if _, ok := dienstgeber[o.TenantID]; !ok {
+			tenantIDs = append(tenantIDs, o.TenantID)
+		}
+		dienstgeber[o.TenantID] = append(dienstgeber[o.TenantID], o.AccountName)
+	}
+
+	admins, err := s.repo.ListAdmins(ctx, tenantIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var lastErr error
+	for _, a := range admins {
+		params := email.KommunalsteuerFristParams{
+			TenantID:      &a.TenantID,
+			RecipientName: a.Name,
+			Year:          year,
+			Faellig:       ErklaerungFrist(year).Format("02.01.2006"),
+			DaysLeft:      daysLeft,
+			Dienstgeber:   dienstgeber[a.TenantID],
+		}
+		if s.appURL != "" {
+			params.URL = s.appURL + "/kommunalsteuer"
+		}
+		if err := s.notifier.SendKommunalsteuerFrist(ctx, a.Email, params); err != nil {
+			lastErr = fmt.Errorf("failed to remind %s: %w", a.Email, err)
+			continue
+		}
+		sent++
+	}
+	return sent, lastErr
+}
@@ -0,0 +1,306 @@
+package kommunalsteuer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/endpoint"
+)
+
+// Handler handles Kommunalsteuer HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new Kommunalsteuer handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the Kommunalsteuer routes. Submitting and
+// recording filings is for admins, like the other tax filings.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/kommunalsteuer", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("GET /api/v1/kommunalsteuer/fristen", requireAuth(http.HandlerFunc(h.Fristen)))
+	router.Handle("POST /api/v1/kommunalsteuer", requireAuth(http.HandlerFunc(h.Create)))
+	router.Handle("GET /api/v1/kommunalsteuer/{id}", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("PUT /api/v1/kommunalsteuer/{id}", requireAuth(http.HandlerFunc(h.Recalculate)))
+	router.Handle("DELETE /api/v1/kommunalsteuer/{id}", requireAuth(http.HandlerFunc(h.Delete)))
+	router.Handle("GET /api/v1/kommunalsteuer/{id}/xml", requireAuth(http.HandlerFunc(h.KommStXML)))
+	router.Handle("GET /api/v1/kommunalsteuer/{id}/dienstgeberabgabe/xml", requireAuth(http.HandlerFunc(h.DGAXML)))
+	router.Handle("POST /api/v1/kommunalsteuer/{id}/submit", requireAuth(requireAdmin(http.HandlerFunc(h.Submit))))
+	router.Handle("POST /api/v1/kommunalsteuer/{id}/dienstgeberabgabe/filed", requireAuth(requireAdmin(http.HandlerFunc(h.MarkDGAFiled))))
+}
+
+// List handles GET /api/v1/kommunalsteuer
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	filter := ListFilter{TenantID: tenantID, Limit: 50}
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil {
+			api.BadRequest(w, "invalid year")
+			return
+		}
+		filter.Year = &year
+	}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	list, total, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if list == nil {
+		list = []*Erklaerung{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  list,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// Fristen handles GET /api/v1/kommunalsteuer/fristen?year=
+func (h *Handler) Fristen(w http.ResponseWriter, r *http.Request) {
+	year, err := strconv.Atoi(r.URL.Query().Get("year"))
+	if err != nil || year < 2000 || year > 2100 {
+		api.BadRequest(w, ErrInvalidYear.Error())
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"year": year, "items": Fristen(year)})
+}
+
+// Create handles POST /api/v1/kommunalsteuer
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.RequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var input Input
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	e, err := h.service.Create(r.Context(), tenantID, userID, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, e)
+}
+
+// Get handles GET /api/v1/kommunalsteuer/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	e, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, e)
+}
+
+// Recalculate handles PUT /api/v1/kommunalsteuer/{id}
+func (h *Handler) Recalculate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var input Input
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	e, err := h.service.Recalculate(r.Context(), tenantID, id, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, e)
+}
+
+// Delete handles DELETE /api/v1/kommunalsteuer/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), tenantID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// KommStXML handles GET /api/v1/kommunalsteuer/{id}/xml
+func (h *Handler) KommStXML(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	xmlContent, err := h.service.KommStXML(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeXML(w, "kommunalsteuer", xmlContent)
+}
+
+// DGAXML handles GET /api/v1/kommunalsteuer/{id}/dienstgeberabgabe/xml
+func (h *Handler) DGAXML(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	xmlContent, err := h.service.DGAXML(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeXML(w, "dienstgeberabgabe", xmlContent)
+}
+
+// Submit handles POST /api/v1/kommunalsteuer/{id}/submit
+func (h *Handler) Submit(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.RequestUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	e, err := h.service.Submit(r.Context(), tenantID, id, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, e)
+}
+
+// MarkDGAFiled handles POST /api/v1/kommunalsteuer/{id}/dienstgeberabgabe/filed
+func (h *Handler) MarkDGAFiled(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reference string `json:"reference"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			api.BadRequest(w, "invalid request body")
+			return
+		}
+	}
+
+	e, err := h.service.MarkDGAFiled(r.Context(), tenantID, id, req.Reference)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, e)
+}
+
+func writeXML(w http.ResponseWriter, name string, content []byte) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.xml", name))
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	if m, ok := endpoint.AsMaintenance(err); ok {
+		api.ServiceUnavailable(w, m.RetryAfter(), "FinanzOnline is in a maintenance window, retry later")
+		return
+	}
+
+	switch {
+	case errors.Is(err, ErrErklaerungNotFound):
+		api.NotFound(w, "Erklärung not found")
+	case errors.Is(err, ErrELDAAccountNotFound), errors.Is(err, ErrAccountNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrDuplicateErklaerung), errors.Is(err, ErrNotDraft), errors.Is(err, ErrDienstgeberabgabeFiled):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, ErrInvalidYear), errors.Is(err, ErrNoBetriebsstaette), errors.Is(err, ErrInvalidGemeinde),
+		errors.Is(err, ErrDuplicateGemeinde), errors.Is(err, ErrNoEntgelt), errors.Is(err, ErrAccountRequired),
+		errors.Is(err, ErrNoDienstgeberabgabe):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	default:
+		api.InternalError(w)
+	}
+}
@@ -0,0 +1,313 @@
+package kommunalsteuer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles Erklärung database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new Kommunalsteuer repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const erklaerungColumns = `id, tenant_id, elda_account_id, account_id, year, betriebsstaetten, berechnung,
+	status, fon_reference, response_message, submitted_at, submitted_by, dga_filed_at, dga_reference,
+	created_by, created_at, updated_at`
+
+func scanErklaerung(row pgx.Row) (*Erklaerung, error) {
+	var e Erklaerung
+	var betriebsstaettenJSON, berechnungJSON []byte
+	if err := row.Scan(&e.ID, &e.TenantID, &e.ELDAAccountID, &e.AccountID, &e.Year, &betriebsstaettenJSON,
+		&berechnungJSON, &e.Status, &e.FONReference, &e.ResponseMessage, &e.SubmittedAt, &e.SubmittedBy,
+		&e.DGAFiledAt, &e.DGAReference, &e.CreatedBy, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(betriebsstaettenJSON, &e.Betriebsstaetten); err != nil {
+		return nil, fmt.Errorf("invalid betriebsstaetten: %w", err)
+	}
+	if err := json.Unmarshal(berechnungJSON, &e.Berechnung); err != nil {
+		return nil, fmt.Errorf("invalid berechnung: %w", err)
+	}
+	return &e, nil
+}
+
+// Create inserts an Erklärung
+func (r *Repository) Create(ctx context.Context, e *Erklaerung) error {
+	betriebsstaettenJSON, berechnungJSON, err := encode(e)
+	if err != nil {
+		return fmt.Errorf("failed to create Erklärung: %w", err)
+	}
+	saved, err := scanErklaerung(r.db.QueryRow(ctx, `
+		INSERT INTO kommunalsteuer_erklaerungen (tenant_id, elda_account_id, account_id, year, betriebsstaetten,
+			berechnung, bemessungsgrundlage, kommunalsteuer, dienstgeberabgabe, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING `+erklaerungColumns,
+		e.TenantID, e.ELDAAccountID, e.AccountID, e.Year, betriebsstaettenJSON, berechnungJSON,
+		e.Berechnung.Bemessungsgrundlage, e.Berechnung.Kommunalsteuer, dgaAbgabe(e.Berechnung), e.CreatedBy))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicateErklaerung
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create Erklärung: %w", err)
+	}
+	*e = *saved
+	return nil
+}
+
+// UpdateBerechnung replaces the Betriebsstätten, FinanzOnline account and
+// calculation of an Erklärung that has not been submitted
+func (r *Repository) UpdateBerechnung(ctx context.Context, e *Erklaerung) error {
+	betriebsstaettenJSON, berechnungJSON, err := encode(e)
+	if err != nil {
+		return fmt.Errorf("failed to update Erklärung: %w", err)
+	}
+	saved, err := scanErklaerung(r.db.QueryRow(ctx, `
+		UPDATE kommunalsteuer_erklaerungen SET
+			account_id = $3, betriebsstaetten = $4, berechnung = $5, bemessungsgrundlage = $6,
+			kommunalsteuer = $7, dienstgeberabgabe = $8, status = 'draft', updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status <> 'submitted'
+		RETURNING `+erklaerungColumns,
+		e.ID, e.TenantID, e.AccountID, betriebsstaettenJSON, berechnungJSON,
+		e.Berechnung.Bemessungsgrundlage, e.Berechnung.Kommunalsteuer, dgaAbgabe(e.Berechnung)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotDraft
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update Erklärung: %w", err)
+	}
+	*e = *saved
+	return nil
+}
+
+func encode(e *Erklaerung) ([]byte, []byte, error) {
+	betriebsstaettenJSON, err := json.Marshal(e.Betriebsstaetten)
+	if err != nil {
+		return nil, nil, err
+	}
+	berechnungJSON, err := json.Marshal(e.Berechnung)
+	if err != nil {
+		return nil, nil, err
+	}
+	return betriebsstaettenJSON, berechnungJSON, nil
+}
+
+// dgaAbgabe returns the Dienstgeberabgabe of a year, NULL without
+// Betriebsstätte in Wien
+func dgaAbgabe(b *Berechnung) *int64 {
+	if b.Dienstgeberabgabe == nil {
+		return nil
+	}
+	return &b.Dienstgeberabgabe.Abgabe
+}
+
+// Get returns an Erklärung of a tenant
+func (r *Repository) Get(ctx context.Context, tenantID, id uuid.UUID) (*Erklaerung, error) {
+	e, err := scanErklaerung(r.db.QueryRow(ctx,
+		`SELECT `+erklaerungColumns+` FROM kommunalsteuer_erklaerungen WHERE id = $1 AND tenant_id = $2`, id, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrErklaerungNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Erklärung: %w", err)
+	}
+	return e, nil
+}
+
+// List returns the Erklärungen of a tenant, newest year first
+func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Erklaerung, int, error) {
+	where := "tenant_id = $1"
+	args := []interface{}{filter.TenantID}
+	if filter.Year != nil {
+		args = append(args, *filter.Year)
+		where += fmt.Sprintf(" AND year = $%d", len(args))
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM kommunalsteuer_erklaerungen WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count Erklärungen: %w", err)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT %s FROM kommunalsteuer_erklaerungen
+		WHERE %s
+		ORDER BY year DESC, created_at DESC
+		LIMIT $%d OFFSET $%d`, erklaerungColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list Erklärungen: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*Erklaerung
+	for rows.Next() {
+		e, err := scanErklaerung(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list Erklärungen: %w", err)
+		}
+		list = append(list, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list Erklärungen: %w", err)
+	}
+	return list, total, nil
+}
+
+// Delete removes an Erklärung that has not been submitted
+func (r *Repository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM kommunalsteuer_erklaerungen WHERE id = $1 AND tenant_id = $2 AND status <> 'submitted'`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete Erklärung: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.Get(ctx, tenantID, id); err != nil {
+			return err
+		}
+		return ErrNotDraft
+	}
+	return nil
+}
+
+// UpdateSubmissionResult records the answer of FinanzOnline
+func (r *Repository) UpdateSubmissionResult(ctx context.Context, tenantID, id, userID uuid.UUID, status, reference, message string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE kommunalsteuer_erklaerungen SET
+			status = $3, fon_reference = NULLIF($4, ''), response_message = NULLIF($5, ''),
+			submitted_at = CASE WHEN $3 = 'submitted' THEN NOW() ELSE submitted_at END,
+			submitted_by = $6, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2`,
+		id, tenantID, status, reference, message, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update submission result: %w", err)
+	}
+	return nil
+}
+
+// MarkDGAFiled records the filing of the Dienstgeberabgabe with the Stadt
+// Wien
+func (r *Repository) MarkDGAFiled(ctx context.Context, tenantID, id uuid.UUID, reference *string, filedAt time.Time) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE kommunalsteuer_erklaerungen SET dga_filed_at = $3, dga_reference = $4, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND dga_filed_at IS NULL`,
+		id, tenantID, filedAt, reference)
+	if err != nil {
+		return fmt.Errorf("failed to mark Dienstgeberabgabe filed: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDienstgeberabgabeFiled
+	}
+	return nil
+}
+
+// ELDAAccountBelongsToTenant reports whether an ELDA account is one of the
+// tenant's
+func (r *Repository) ELDAAccountBelongsToTenant(ctx context.Context, tenantID, eldaAccountID uuid.UUID) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM elda_accounts ea JOIN accounts a ON a.id = ea.account_id
+			WHERE ea.id = $1 AND a.tenant_id = $2)`, eldaAccountID, tenantID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("failed to check ELDA account: %w", err)
+	}
+	return ok, nil
+}
+
+// ListPositions returns the mBGM positions of an ELDA account in a year.
+// Of a corrected mBGM only the latest submitted correction counts.
+func (r *Repository) ListPositions(ctx context.Context, eldaAccountID uuid.UUID, year int) ([]Position, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT m.month, p.sv_nummer, p.geburtsdatum, p.beitragsgruppe,
+			p.beitragsgrundlage::float8, COALESCE(p.sonderzahlung, 0)::float8,
+			p.von_datum, p.bis_datum, p.wochenstunden::float8
+		FROM mbgm m
+		JOIN mbgm_positionen p ON p.mbgm_id = m.id
+		WHERE m.elda_account_id = $1 AND m.year = $2
+		  AND m.status IN ('submitted', 'accepted')
+		  AND NOT EXISTS (
+			SELECT 1 FROM mbgm c
+			WHERE c.corrects_id = m.id AND c.status IN ('submitted', 'accepted'))
+		ORDER BY m.month, p.position_index`, eldaAccountID, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mBGM positions: %w", err)
+	}
+	defer rows.Close()
+
+	var positions []Position
+	for rows.Next() {
+		var p Position
+		var grundlage, sonderzahlung float64
+		if err := rows.Scan(&p.Monat, &p.SVNummer, &p.Geburtsdatum, &p.Beitragsgruppe, &grundlage, &sonderzahlung,
+			&p.Von, &p.Bis, &p.Wochenstunden); err != nil {
+			return nil, fmt.Errorf("failed to scan mBGM position: %w", err)
+		}
+		p.Entgelt = int64(math.Round((grundlage + sonderzahlung) * 100))
+		positions = append(positions, p)
+	}
+	return positions, rows.Err()
+}
+
+// ListOffen returns the ELDA accounts with a submitted mBGM in a year whose
+// Erklärung of the year has not been submitted
+func (r *Repository) ListOffen(ctx context.Context, year int) ([]*Offen, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT a.tenant_id, ea.id, a.name
+		FROM elda_accounts ea
+		JOIN accounts a ON a.id = ea.account_id
+		JOIN mbgm m ON m.elda_account_id = ea.id AND m.year = $1 AND m.status IN ('submitted', 'accepted')
+		WHERE NOT EXISTS (
+			SELECT 1 FROM kommunalsteuer_erklaerungen k
+			WHERE k.elda_account_id = ea.id AND k.year = $1 AND k.status = 'submitted')
+		ORDER BY a.tenant_id, a.name`, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open Erklärungen: %w", err)
+	}
+	defer rows.Close()
+
+	var offen []*Offen
+	for rows.Next() {
+		o := &Offen{Year: year}
+		if err := rows.Scan(&o.TenantID, &o.ELDAAccountID, &o.AccountName); err != nil {
+			return nil, fmt.Errorf("failed to scan open Erklärung: %w", err)
+		}
+		offen = append(offen, o)
+	}
+	return offen, rows.Err()
+}
+
+// ListAdmins returns the active owners and admins of tenants
+func (r *Repository) ListAdmins(ctx context.Context, tenantIDs []uuid.UUID) ([]*Admin, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT tenant_id, name, email FROM users
+		WHERE tenant_id = ANY($1) AND role IN ('owner', 'admin') AND is_active
+		ORDER BY tenant_id, email`, tenantIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant admins: %w", err)
+	}
+	defer rows.Close()
+
+	var admins []*Admin
+	for rows.Next() {
+		var a Admin
+		if err := rows.Scan(&a.TenantID, &a.Name, &a.Email); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant admin: %w", err)
+		}
+		admins = append(admins, &a)
+	}
+	return admins, rows.Err()
+}
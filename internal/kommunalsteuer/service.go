@@ -0,0 +1,291 @@
+package kommunalsteuer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/account"
+	"austrian-business-infrastructure/internal/account/types"
+	"austrian-business-infrastructure/internal/endpoint"
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/rawpayload"
+)
+
+// Service handles Kommunalsteuer and Dienstgeberabgabe returns
+type Service struct {
+	repo           *Repository
+	accountService *account.Service
+	fonwsClient    fonws.Caller
+	payloads       *rawpayload.Service
+	notifier       FristNotifier
+	appURL         string
+	now            func() time.Time
+}
+
+// NewService creates a new Kommunalsteuer service. Erklärungen are
+// submitted with the FinanzOnline credentials of accountService.
+func NewService(repo *Repository, accountService *account.Service) *Service {
+	return &Service{
+		repo:           repo,
+		accountService: accountService,
+		fonwsClient:    fonws.NewClient(),
+		now:            time.Now,
+	}
+}
+
+// SetRawPayloads enables retention of the raw FinanzOnline exchanges of submissions
+func (s *Service) SetRawPayloads(p *rawpayload.Service) {
+	s.payloads = p
+}
+
+// SetEndpoints makes FinanzOnline calls use the active endpoint set and
+// fail fast during maintenance windows
+func (s *Service) SetEndpoints(r fonws.EndpointResolver) {
+	if c, ok := s.fonwsClient.(*fonws.Client); ok {
+		c.SetEndpointResolver(r)
+	}
+}
+
+// SetFinanzOnline replaces the FinanzOnline client, e.g. with a fake in tests
+func (s *Service) SetFinanzOnline(c fonws.Caller) {
+	s.fonwsClient = c
+}
+
+// Create calculates the Erklärung of an ELDA account for a year from its
+// submitted mBGM
+func (s *Service) Create(ctx context.Context, tenantID, userID uuid.UUID, input *Input) (*Erklaerung, error) {
+	if input.Year < 2000 || input.Year > 2100 {
+		return nil, ErrInvalidYear
+	}
+	betriebsstaetten, err := normalizeBetriebsstaetten(input.Betriebsstaetten)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.repo.ELDAAccountBelongsToTenant(ctx, tenantID, input.ELDAAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrELDAAccountNotFound
+	}
+
+	berechnung, err := s.berechne(ctx, input.ELDAAccountID, input.Year, betriebsstaetten)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Erklaerung{
+		TenantID:         tenantID,
+		ELDAAccountID:    input.ELDAAccountID,
+		AccountID:        input.AccountID,
+		Year:             input.Year,
+		Betriebsstaetten: betriebsstaetten,
+		Berechnung:       berechnung,
+		CreatedBy:        &userID,
+	}
+	if err := s.repo.Create(ctx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Recalculate recalculates an Erklärung that has not been submitted from
+// the current mBGM, with new Betriebsstätten and FinanzOnline account. The
+// ELDA account and year cannot be changed.
+func (s *Service) Recalculate(ctx context.Context, tenantID, id uuid.UUID, input *Input) (*Erklaerung, error) {
+	e, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if e.Status == StatusSubmitted {
+		return nil, ErrNotDraft
+	}
+	betriebsstaetten, err := normalizeBetriebsstaetten(input.Betriebsstaetten)
+	if err != nil {
+		return nil, err
+	}
+
+	berechnung, err := s.berechne(ctx, e.ELDAAccountID, e.Year, betriebsstaetten)
+	if err != nil {
+		return nil, err
+	}
+
+	e.AccountID = input.AccountID
+	e.Betriebsstaetten = betriebsstaetten
+	e.Berechnung = berechnung
+	if err := s.repo.UpdateBerechnung(ctx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (s *Service) berechne(ctx context.Context, eldaAccountID uuid.UUID, year int, betriebsstaetten []Betriebsstaette) (*Berechnung, error) {
+	positions, err := s.repo.ListPositions(ctx, eldaAccountID, year)
+	if err != nil {
+		return nil, err
+	}
+	berechnung := Berechne(year, positions, betriebsstaetten)
+	if berechnung.Bemessungsgrundlage == 0 {
+		return nil, ErrNoEntgelt
+	}
+	return berechnung, nil
+}
+
+// normalizeBetriebsstaetten checks the Betriebsstätten and drops the spaces
+// of their SV-Nummern
+func normalizeBetriebsstaetten(list []Betriebsstaette) ([]Betriebsstaette, error) {
+	if len(list) == 0 {
+		return nil, ErrNoBetriebsstaette
+	}
+	seen := make(map[string]bool)
+	normalized := make([]Betriebsstaette, 0, len(list))
+	for _, bs := range list {
+		bs.Gemeindekennziffer = strings.TrimSpace(bs.Gemeindekennziffer)
+		if len(bs.Gemeindekennziffer) != 5 || strings.Trim(bs.Gemeindekennziffer, "0123456789") != "" {
+			return nil, ErrInvalidGemeinde
+		}
+		if seen[bs.Gemeindekennziffer] {
+			return nil, ErrDuplicateGemeinde
+		}
+		seen[bs.Gemeindekennziffer] = true
+
+		bs.Bezeichnung = strings.TrimSpace(bs.Bezeichnung)
+		svNummern := make([]string, 0, len(bs.SVNummern))
+		for _, sv := range bs.SVNummern {
+			if sv = strings.Join(strings.Fields(sv), ""); sv != "" {
+				svNummern = append(svNummern, sv)
+			}
+		}
+		bs.SVNummern = svNummern
+		normalized = append(normalized, bs)
+	}
+	return normalized, nil
+}
+
+// Get returns an Erklärung of a tenant
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Erklaerung, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// List returns the Erklärungen of a tenant and their total
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]*Erklaerung, int, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Delete removes an Erklärung that has not been submitted
+func (s *Service) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+// KommStXML returns the Kommunalsteuererklärung XML of an Erklärung
+func (s *Service) KommStXML(ctx context.Context, tenantID, id uuid.UUID) ([]byte, error) {
+	e, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	return fonws.GenerateKommStXML(ToKommSt(e.Year, e.Berechnung))
+}
+
+// DGAXML returns the Dienstgeberabgabe-Erklärung XML of an Erklärung
+func (s *Service) DGAXML(ctx context.Context, tenantID, id uuid.UUID) ([]byte, error) {
+	e, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	return GenerateDGAXML(e.Year, e.Berechnung)
+}
+
+// Submit submits the Kommunalsteuererklärung to FinanzOnline with the
+// credentials of the Erklärung's FinanzOnline account
+func (s *Service) Submit(ctx context.Context, tenantID, id, userID uuid.UUID) (*Erklaerung, error) {
+	e, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if e.Status == StatusSubmitted {
+		return nil, ErrNotDraft
+	}
+	if e.AccountID == nil {
+		return nil, ErrAccountRequired
+	}
+	kommSt := ToKommSt(e.Year, e.Berechnung)
+	if err := kommSt.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Kommunalsteuererklärung: %w", err)
+	}
+
+	_, creds, err := s.accountService.GetAccountWithCredentials(ctx, *e.AccountID, tenantID)
+	if err != nil {
+		return nil, ErrAccountNotFound
+	}
+	foCreds, ok := creds.(*types.FinanzOnlineCredentials)
+	if !ok {
+		return nil, errors.New("invalid account credentials")
+	}
+
+	sessionService := fonws.NewSessionService(s.fonwsClient)
+	session, err := sessionService.Login(foCreds.TID, foCreds.BenID, foCreds.PIN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to login to FinanzOnline: %w", err)
+	}
+	defer sessionService.Logout(session)
+
+	rec := rawpayload.NewRecorder()
+	uploadService := fonws.NewFileUploadService(fonws.Recording(s.fonwsClient, rec))
+	resp, err := uploadService.SubmitKommSt(session.Token, foCreds.TID, foCreds.BenID, kommSt)
+	if errors.Is(err, endpoint.ErrMaintenanceWindow) {
+		// Nothing was sent; the Erklärung stays a draft
+		return nil, err
+	}
+
+	status, reference, message := StatusSubmitted, "", ""
+	if err != nil {
+		status = StatusError
+		if resp != nil {
+			message = resp.Msg
+		} else {
+			message = err.Error()
+		}
+	} else {
+		reference, message = resp.Belegnummer, resp.Msg
+	}
+
+	s.payloads.Save(ctx, rawpayload.Reference{
+		TenantID:          &tenantID,
+		Module:            rawpayload.ModuleKommSt,
+		ReferenceType:     "kommunalsteuer_erklaerung",
+		ReferenceID:       &id,
+		ProviderReference: reference,
+		Outcome:           rawpayload.OutcomeOf(status == StatusSubmitted, resp != nil),
+	}, rec)
+
+	if err := s.repo.UpdateSubmissionResult(ctx, tenantID, id, userID, status, reference, message); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// MarkDGAFiled records that the Dienstgeberabgabe-Erklärung was filed with
+// the Stadt Wien
+func (s *Service) MarkDGAFiled(ctx context.Context, tenantID, id uuid.UUID, reference string) (*Erklaerung, error) {
+	e, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if e.Berechnung.Dienstgeberabgabe == nil {
+		return nil, ErrNoDienstgeberabgabe
+	}
+
+	var ref *string
+	if reference = strings.TrimSpace(reference); reference != "" {
+		ref = &reference
+	}
+	if err := s.repo.MarkDGAFiled(ctx, tenantID, id, ref, s.now()); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, tenantID, id)
+}
@@ -0,0 +1,124 @@
+// Package kommunalsteuer calculates the annual Kommunalsteuererklärung and
+// the Wiener Dienstgeberabgabe of an employer from the Entgelt reported in
+// its monthly Beitragsgrundlagenmeldungen (mBGM), submits the
+// Kommunalsteuererklärung via FinanzOnline and tracks the filing and
+// payment deadlines of both.
+package kommunalsteuer
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrErklaerungNotFound     = errors.New("Erklärung not found")
+	ErrDuplicateErklaerung    = errors.New("an Erklärung for this ELDA account and year already exists")
+	ErrInvalidYear            = errors.New("year must be between 2000 and 2100")
+	ErrELDAAccountNotFound    = errors.New("ELDA account not found")
+	ErrNoBetriebsstaette      = errors.New("at least one Betriebsstätte is required")
+	ErrInvalidGemeinde        = errors.New("gemeindekennziffer must have 5 digits")
+	ErrDuplicateGemeinde      = errors.New("each Gemeinde can be listed once")
+	ErrNoEntgelt              = errors.New("no submitted mBGM with Entgelt in this year")
+	ErrNotDraft               = errors.New("Erklärung has already been submitted")
+	ErrAccountRequired        = errors.New("a FinanzOnline account is required to submit")
+	ErrAccountNotFound        = errors.New("account not found")
+	ErrNoDienstgeberabgabe    = errors.New("Erklärung has no Betriebsstätte in Wien")
+	ErrDienstgeberabgabeFiled = errors.New("Dienstgeberabgabe has already been filed")
+)
+
+// Status of an Erklärung
+const (
+	StatusDraft     = "draft"
+	StatusSubmitted = "submitted"
+	StatusError     = "error"
+)
+
+// GemeindeWien is the Gemeindekennziffer of Wien, the only Gemeinde that
+// levies the Dienstgeberabgabe
+const GemeindeWien = "90001"
+
+// Erklaerung is the Kommunalsteuererklärung, and for Betriebsstätten in
+// Wien the Dienstgeberabgabe, of the employer of an ELDA account for a year
+type Erklaerung struct {
+	ID            uuid.UUID  `json:"id"`
+	TenantID      uuid.UUID  `json:"tenant_id"`
+	ELDAAccountID uuid.UUID  `json:"elda_account_id"`
+	AccountID     *uuid.UUID `json:"account_id,omitempty"` // FinanzOnline account submitting it
+	Year          int        `json:"year"`
+
+	Betriebsstaetten []Betriebsstaette `json:"betriebsstaetten"`
+	Berechnung       *Berechnung       `json:"berechnung"`
+
+	Status          string     `json:"status"`
+	FONReference    *string    `json:"fon_reference,omitempty"`
+	ResponseMessage *string    `json:"response_message,omitempty"`
+	SubmittedAt     *time.Time `json:"submitted_at,omitempty"`
+	SubmittedBy     *uuid.UUID `json:"submitted_by,omitempty"`
+
+	// The Dienstgeberabgabe is filed with the Stadt Wien outside of
+	// FinanzOnline and recorded here
+	DGAFiledAt   *time.Time `json:"dga_filed_at,omitempty"`
+	DGAReference *string    `json:"dga_reference,omitempty"`
+
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Betriebsstaette is a permanent establishment of the employer. The Entgelt
+// of the employees listed by SV-Nummer is allocated to its Gemeinde; the
+// Entgelt of employees not listed anywhere goes to the first Betriebsstätte.
+type Betriebsstaette struct {
+	Gemeindekennziffer string   `json:"gemeindekennziffer"`
+	Bezeichnung        string   `json:"bezeichnung,omitempty"`
+	SVNummern          []string `json:"sv_nummern,omitempty"`
+}
+
+// Input creates an Erklärung or recalculates a draft
+type Input struct {
+	ELDAAccountID    uuid.UUID         `json:"elda_account_id"`
+	AccountID        *uuid.UUID        `json:"account_id"`
+	Year             int               `json:"year"`
+	Betriebsstaetten []Betriebsstaette `json:"betriebsstaetten"`
+}
+
+// ListFilter filters Erklärungen
+type ListFilter struct {
+	TenantID uuid.UUID
+	Year     *int
+	Limit    int
+	Offset   int
+}
+
+// Frist is a filing or payment deadline
+type Frist struct {
+	Art     string    `json:"art"`
+	Periode string    `json:"periode"` // e.g. 2025 or 03/2025
+	Faellig time.Time `json:"faellig"`
+}
+
+// Kinds of deadlines
+const (
+	FristKommStErklaerung = "kommunalsteuer_erklaerung"
+	FristKommStZahlung    = "kommunalsteuer_zahlung"
+	FristDGAErklaerung    = "dienstgeberabgabe_erklaerung"
+	FristDGAZahlung       = "dienstgeberabgabe_zahlung"
+)
+
+// Offen is an ELDA account with Entgelt in a year whose Erklärung has not
+// been submitted yet
+type Offen struct {
+	TenantID      uuid.UUID
+	ELDAAccountID uuid.UUID
+	AccountName   string
+	Year          int
+}
+
+// Admin is a tenant owner or admin reminded of deadlines
+type Admin struct {
+	TenantID uuid.UUID
+	Name     string
+	Email    string
+}
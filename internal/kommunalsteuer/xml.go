@@ -0,0 +1,86 @@
+package kommunalsteuer
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"austrian-business-infrastructure/internal/fonws"
+)
+
+// ToKommSt converts the calculation of an Erklärung to the
+// Kommunalsteuererklärung submitted via FinanzOnline
+func ToKommSt(year int, b *Berechnung) *fonws.KommSt {
+	k := &fonws.KommSt{Year: year}
+	index := make(map[string]int)
+	for _, g := range b.Gemeinden {
+		if g.Bemessungsgrundlage == 0 {
+			continue
+		}
+		index[g.Gemeindekennziffer] = len(k.Gemeinden)
+		k.Gemeinden = append(k.Gemeinden, fonws.KommStGemeinde{Gemeindekennziffer: g.Gemeindekennziffer})
+	}
+	for _, m := range b.Monate {
+		for _, g := range m.Gemeinden {
+			i, ok := index[g.Gemeindekennziffer]
+			if !ok {
+				continue
+			}
+			k.Gemeinden[i].Monate = append(k.Gemeinden[i].Monate, fonws.KommStMonat{
+				Monat:               m.Monat,
+				Bemessungsgrundlage: g.Bemessungsgrundlage,
+				Steuer:              g.Kommunalsteuer,
+			})
+		}
+	}
+	return k
+}
+
+// Dienstgeberabgabe-Erklärung XML structures, for the upload to the Stadt
+// Wien
+type dgaXML struct {
+	XMLName xml.Name      `xml:"DienstgeberabgabeErklaerung"`
+	Jahr    int           `xml:"Jahr"`
+	Monate  []dgaMonatXML `xml:"Monat"`
+	Summe   dgaSummeXML   `xml:"Summe"`
+}
+
+type dgaMonatXML struct {
+	Nr           string `xml:"nr,attr"`
+	Dienstnehmer int    `xml:"Dienstnehmer"`
+	Wochen       int    `xml:"Wochen"`
+	Abgabe       string `xml:"Abgabe"`
+}
+
+type dgaSummeXML struct {
+	Wochen int    `xml:"Wochen"`
+	Abgabe string `xml:"Abgabe"`
+}
+
+// GenerateDGAXML generates the Dienstgeberabgabe-Erklärung of a year. It is
+// filed with the Stadt Wien, not via FinanzOnline.
+func GenerateDGAXML(year int, b *Berechnung) ([]byte, error) {
+	if b.Dienstgeberabgabe == nil {
+		return nil, ErrNoDienstgeberabgabe
+	}
+
+	doc := dgaXML{Jahr: year}
+	for _, m := range b.Monate {
+		if m.Dienstgeberabgabe == nil {
+			continue
+		}
+		doc.Monate = append(doc.Monate, dgaMonatXML{
+			Nr:           fmt.Sprintf("%02d", m.Monat),
+			Dienstnehmer: m.Dienstgeberabgabe.Dienstnehmer,
+			Wochen:       m.Dienstgeberabgabe.Wochen,
+			Abgabe:       fonws.FormatCents(m.Dienstgeberabgabe.Abgabe),
+		})
+	}
+	doc.Summe = dgaSummeXML{Wochen: b.Dienstgeberabgabe.Wochen, Abgabe: fonws.FormatCents(b.Dienstgeberabgabe.Abgabe)}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal XML: %w", err)
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
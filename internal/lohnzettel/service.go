@@ -2,6 +2,7 @@ package lohnzettel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -10,6 +11,8 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/rawpayload"
+	"austrian-business-infrastructure/internal/xmlschema"
 )
 
 // Service handles L16 Lohnzettel business logic
@@ -18,10 +21,11 @@ type Service struct {
 	builder   *Builder
 	validator *Validator
 	eldaL16   *elda.L16Service
+	payloads  *rawpayload.Service
 }
 
 // NewService creates a new Lohnzettel service
-func NewService(pool *pgxpool.Pool, eldaClient *elda.Client) *Service {
+func NewService(pool *pgxpool.Pool, eldaClient elda.Caller) *Service {
 	return &Service{
 		repo:      NewRepository(pool),
 		builder:   NewBuilder(),
@@ -30,6 +34,43 @@ func NewService(pool *pgxpool.Pool, eldaClient *elda.Client) *Service {
 	}
 }
 
+// SetRawPayloads enables retention of the raw ELDA exchanges of submissions
+func (s *Service) SetRawPayloads(p *rawpayload.Service) {
+	s.payloads = p
+}
+
+// savePayloads retains the raw exchanges of a Lohnzettel submission
+func (s *Service) savePayloads(ctx context.Context, lz *elda.Lohnzettel, referenceType string, result *elda.L16SubmitResult, err error, rec *rawpayload.Recorder) {
+	ref := rawpayload.Reference{
+		ELDAAccountID: &lz.ELDAAccountID,
+		Module:        rawpayload.ModuleELDA,
+		ReferenceType: referenceType,
+		ReferenceID:   &lz.ID,
+		Outcome:       rawpayload.OutcomeOf(err == nil, result != nil && result.ErrorCode != ""),
+	}
+	if result != nil {
+		ref.ProviderReference = result.Protokollnummer
+	}
+	s.payloads.Save(ctx, ref, rec)
+}
+
+// checkSchema validates the L16 XML against the schema of the Lohnzettel year
+func (s *Service) checkSchema(lz *elda.Lohnzettel) error {
+	xmlData, err := s.builder.BuildXML(lz)
+	if err != nil {
+		return err
+	}
+	err = xmlschema.Check(xmlschema.FormatL16, time.Date(lz.Year, time.January, 1, 0, 0, 0, 0, time.UTC), xmlData)
+	var schemaErr *xmlschema.ValidationError
+	if errors.As(err, &schemaErr) {
+		return &ValidationError{
+			Message: "Lohnzettel entspricht nicht dem ELDA-Schema " + schemaErr.Version,
+			Errors:  schemaErr.Messages(),
+		}
+	}
+	return err
+}
+
 // Create creates a new Lohnzettel (L16)
 func (s *Service) Create(ctx context.Context, req *elda.LohnzettelCreateRequest) (*elda.Lohnzettel, error) {
 	// Validate the request
@@ -157,9 +198,14 @@ func (s *Service) Submit(ctx context.Context, id uuid.UUID) (*elda.L16SubmitResu
 
 	// Build the XML document
 	doc := s.builder.buildDocument(lohnzettel)
+	if err := s.checkSchema(lohnzettel); err != nil {
+		return nil, err
+	}
 
 	// Submit to ELDA
-	result, err := s.eldaL16.SubmitL16(ctx, doc)
+	rec := rawpayload.NewRecorder()
+	result, err := s.eldaL16.SubmitL16(rawpayload.WithRecorder(ctx, rec), doc)
+	s.savePayloads(ctx, lohnzettel, "l16", result, err, rec)
 
 	// Store request XML regardless of result
 	xmlData, _ := s.builder.BuildXML(lohnzettel)
@@ -276,6 +322,7 @@ func (s *Service) SubmitBatch(ctx context.Context, batchID uuid.UUID, maxConcurr
 
 	// Prepare batch items
 	var items []*elda.L16BatchItem
+	recorders := make(map[uuid.UUID]*rawpayload.Recorder)
 	for _, lz := range lohnzettel {
 		// Validate each
 		validation := s.validator.ValidateLohnzettel(lz)
@@ -284,9 +331,11 @@ func (s *Service) SubmitBatch(ctx context.Context, batchID uuid.UUID, maxConcurr
 		}
 
 		doc := s.builder.buildDocument(lz)
+		recorders[lz.ID] = rawpayload.NewRecorder()
 		items = append(items, &elda.L16BatchItem{
 			LohnzettelID: lz.ID,
 			Document:     doc,
+			Recorder:     recorders[lz.ID],
 		})
 	}
 
@@ -300,6 +349,15 @@ func (s *Service) SubmitBatch(ctx context.Context, batchID uuid.UUID, maxConcurr
 			continue
 		}
 
+		s.payloads.Save(ctx, rawpayload.Reference{
+			ELDAAccountID:     &lz.ELDAAccountID,
+			Module:            rawpayload.ModuleELDA,
+			ReferenceType:     "l16",
+			ReferenceID:       &lz.ID,
+			ProviderReference: itemResult.Protokollnummer,
+			Outcome:           rawpayload.OutcomeOf(itemResult.Success, itemResult.ErrorCode != ""),
+		}, recorders[lz.ID])
+
 		lz.UpdatedAt = time.Now()
 		if itemResult.Success {
 			lz.Status = elda.L16StatusSubmitted
@@ -401,9 +459,14 @@ func (s *Service) SubmitBerichtigung(ctx context.Context, correctionID uuid.UUID
 
 	// Build XML
 	doc := s.builder.buildDocument(correction)
+	if err := s.checkSchema(correction); err != nil {
+		return nil, err
+	}
 
 	// Submit to ELDA
-	result, err := s.eldaL16.SubmitL16Berichtigung(ctx, doc, original.Protokollnummer)
+	rec := rawpayload.NewRecorder()
+	result, err := s.eldaL16.SubmitL16Berichtigung(rawpayload.WithRecorder(ctx, rec), doc, original.Protokollnummer)
+	s.savePayloads(ctx, correction, "l16_berichtigung", result, err, rec)
 
 	// Store request XML
 	xmlData, _ := s.builder.BuildXML(correction)
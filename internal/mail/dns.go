@@ -0,0 +1,222 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+)
+
+// DNSConfig describes the records tenant domains need so mail from their
+// addresses passes SPF, DKIM and DMARC
+type DNSConfig struct {
+	// SPFInclude is the provider's SPF domain, e.g. mailgun.org
+	SPFInclude string
+	// DKIM is delegated: tenants point {DKIMSelector}._domainkey.{domain} by
+	// CNAME to {DKIMSelector}._domainkey.{DKIMDomain}, where the platform
+	// publishes the key its provider signs with
+	DKIMSelector string
+	DKIMDomain   string
+}
+
+// DefaultSPFInclude returns the SPF domain of a provider
+func DefaultSPFInclude(provider string) string {
+	switch provider {
+	case "mailgun":
+		return "mailgun.org"
+	case "ses":
+		return "amazonses.com"
+	default:
+		return ""
+	}
+}
+
+// DNS record states
+const (
+	RecordOK      = "ok"
+	RecordMissing = "missing"
+	RecordInvalid = "invalid"
+	RecordUnknown = "unchecked"
+)
+
+// DNSRecord is a record a tenant publishes for their sender domain
+type DNSRecord struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Purpose  string `json:"purpose"`
+	Required bool   `json:"required"`
+	Status   string `json:"status"`
+	Note     string `json:"note,omitempty"`
+}
+
+// Resolver looks up DNS records; *net.Resolver satisfies it
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
+// DNSCheck is the result of checking a sender domain
+type DNSCheck struct {
+	Domain  string      `json:"domain"`
+	SPF     bool        `json:"spf"`
+	DKIM    bool        `json:"dkim"`
+	DMARC   bool        `json:"dmarc"`
+	Records []DNSRecord `json:"records"`
+}
+
+// Verified reports whether SPF and DKIM pass, which DMARC alignment needs
+func (c *DNSCheck) Verified() bool {
+	return c.SPF && c.DKIM
+}
+
+// Guidance returns the records to publish for a domain, unchecked
+func (c DNSConfig) Guidance(domain string) []DNSRecord {
+	spfValue := "v=spf1 ~all"
+	spfNote := "Keep a single SPF record per domain and merge existing mechanisms into it."
+	if c.SPFInclude != "" {
+		spfValue = "v=spf1 include:" + c.SPFInclude + " ~all"
+		spfNote = "If the domain already has an SPF record, add include:" + c.SPFInclude + " to it instead of publishing a second one."
+	}
+
+	return []DNSRecord{
+		{
+			Type:     "TXT",
+			Name:     domain,
+			Value:    spfValue,
+			Purpose:  "SPF: authorizes the platform's mail servers to send for the domain",
+			Required: true,
+			Status:   RecordUnknown,
+			Note:     spfNote,
+		},
+		{
+			Type:     "CNAME",
+			Name:     c.dkimName(domain),
+			Value:    c.dkimTarget(),
+			Purpose:  "DKIM: delegates the signing key, so the platform can rotate it without DNS changes",
+			Required: true,
+			Status:   RecordUnknown,
+		},
+		{
+			Type:     "TXT",
+			Name:     "_dmarc." + domain,
+			Value:    "v=DMARC1; p=quarantine; adkim=r; aspf=r; rua=mailto:dmarc@" + domain,
+			Purpose:  "DMARC: tells receivers how to treat mail failing SPF and DKIM",
+			Required: false,
+			Status:   RecordUnknown,
+			Note:     "Recommended. Start with p=none to monitor if the domain also sends mail from elsewhere.",
+		},
+	}
+}
+
+// Check looks up a domain's records and reports which are in place
+func (c DNSConfig) Check(ctx context.Context, resolver Resolver, domain string) (*DNSCheck, error) {
+	records := c.Guidance(domain)
+	check := &DNSCheck{Domain: domain, Records: records}
+
+	// SPF
+	txt, err := lookupTXT(ctx, resolver, domain)
+	if err != nil {
+		return nil, err
+	}
+	records[0].Status = RecordMissing
+	for _, record := range txt {
+		if !strings.HasPrefix(strings.ToLower(record), "v=spf1") {
+			continue
+		}
+		records[0].Status = RecordInvalid
+		if c.SPFInclude == "" || spfIncludes(record, c.SPFInclude) {
+			records[0].Status = RecordOK
+			check.SPF = true
+		}
+		break
+	}
+
+	// DKIM: the delegating CNAME, or a key published directly
+	records[1].Status = RecordMissing
+	cname, err := resolver.LookupCNAME(ctx, records[1].Name)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	// Without a CNAME the resolver returns the name itself
+	if cname = strings.TrimSuffix(cname, "."); err == nil && !strings.EqualFold(cname, records[1].Name) {
+		records[1].Status = RecordInvalid
+		if strings.EqualFold(cname, records[1].Value) {
+			records[1].Status = RecordOK
+			check.DKIM = true
+		}
+	}
+	if !check.DKIM {
+		keys, err := lookupTXT(ctx, resolver, records[1].Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if strings.Contains(strings.ReplaceAll(key, " ", ""), "v=DKIM1") {
+				records[1].Status = RecordOK
+				records[1].Note = "A DKIM key is published directly; it must match the key the platform signs with."
+				check.DKIM = true
+				break
+			}
+		}
+	}
+
+	// DMARC
+	dmarc, err := lookupTXT(ctx, resolver, records[2].Name)
+	if err != nil {
+		return nil, err
+	}
+	records[2].Status = RecordMissing
+	for _, record := range dmarc {
+		if strings.HasPrefix(strings.ToUpper(strings.ReplaceAll(record, " ", "")), "V=DMARC1") {
+			records[2].Status = RecordOK
+			check.DMARC = true
+			break
+		}
+	}
+
+	return check, nil
+}
+
+func (c DNSConfig) dkimName(domain string) string {
+	return c.selector() + "._domainkey." + domain
+}
+
+func (c DNSConfig) dkimTarget() string {
+	return c.selector() + "._domainkey." + c.DKIMDomain
+}
+
+func (c DNSConfig) selector() string {
+	if c.DKIMSelector == "" {
+		return "abi1"
+	}
+	return c.DKIMSelector
+}
+
+// spfIncludes reports whether an SPF record includes a domain
+func spfIncludes(record, include string) bool {
+	for _, term := range strings.Fields(strings.ToLower(record)) {
+		term = strings.TrimLeft(term, "+")
+		if term == "include:"+strings.ToLower(include) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupTXT treats a missing name as no records
+func lookupTXT(ctx context.Context, resolver Resolver, name string) ([]string, error) {
+	records, err := resolver.LookupTXT(ctx, name)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	return records, nil
+}
+
+func isNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
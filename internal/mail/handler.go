@@ -0,0 +1,283 @@
+package mail
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// maxWebhookBody bounds provider webhook payloads
+const maxWebhookBody = 256 * 1024
+
+// WebhookConfig holds the credentials provider webhooks are verified with.
+// A webhook without credentials is not registered.
+type WebhookConfig struct {
+	MailgunSigningKey string
+	SNS               *SNSVerifier
+}
+
+// Handler handles mail HTTP requests
+type Handler struct {
+	service  *Service
+	webhooks WebhookConfig
+}
+
+// NewHandler creates a new mail handler
+func NewHandler(service *Service, webhooks WebhookConfig) *Handler {
+	return &Handler{service: service, webhooks: webhooks}
+}
+
+// RegisterRoutes registers mail routes. Sender identity and suppressions
+// are admin-only; the provider webhooks authenticate by signature.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	admin := func(fn http.HandlerFunc) http.Handler { return requireAuth(requireAdmin(fn)) }
+
+	router.Handle("GET /api/v1/mail/sender", admin(h.GetSender))
+	router.Handle("PUT /api/v1/mail/sender", admin(h.SetSender))
+	router.Handle("DELETE /api/v1/mail/sender", admin(h.DeleteSender))
+	router.Handle("POST /api/v1/mail/sender/verify", admin(h.VerifySender))
+
+	router.Handle("GET /api/v1/mail/suppressions", admin(h.ListSuppressions))
+	router.Handle("POST /api/v1/mail/suppressions", admin(h.AddSuppression))
+	router.Handle("DELETE /api/v1/mail/suppressions/{email}", admin(h.RemoveSuppression))
+
+	if h.webhooks.MailgunSigningKey != "" {
+		router.HandleFunc("POST /api/v1/mail/webhooks/mailgun", h.MailgunWebhook)
+	}
+	if h.webhooks.SNS != nil {
+		router.HandleFunc("POST /api/v1/mail/webhooks/ses", h.SESWebhook)
+	}
+}
+
+// GetSender handles GET /api/v1/mail/sender
+func (h *Handler) GetSender(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	view, err := h.service.GetSenderIdentity(r.Context(), tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	api.JSONResponse(w, http.StatusOK, view)
+}
+
+// SetSender handles PUT /api/v1/mail/sender
+func (h *Handler) SetSender(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	var input SenderIdentityInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	view, err := h.service.SetSenderIdentity(r.Context(), tenantID, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	api.JSONResponse(w, http.StatusOK, view)
+}
+
+// VerifySender handles POST /api/v1/mail/sender/verify
+func (h *Handler) VerifySender(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	view, err := h.service.VerifySenderIdentity(r.Context(), tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	api.JSONResponse(w, http.StatusOK, view)
+}
+
+// DeleteSender handles DELETE /api/v1/mail/sender
+func (h *Handler) DeleteSender(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteSenderIdentity(r.Context(), tenantID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSuppressions handles GET /api/v1/mail/suppressions. With ?email= it
+// reports whether mail to that address is suppressed, including global
+// bounces and complaints.
+func (h *Handler) ListSuppressions(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	if email := r.URL.Query().Get("email"); email != "" {
+		suppression, err := h.service.CheckSuppression(r.Context(), tenantID, email)
+		if err != nil {
+			h.handleError(w, err)
+			return
+		}
+		api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"suppressed":  suppression != nil,
+			"suppression": suppression,
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	suppressions, total, err := h.service.ListSuppressions(r.Context(), tenantID, limit, offset)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	if suppressions == nil {
+		suppressions = []*Suppression{}
+	}
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"suppressions": suppressions,
+		"total":        total,
+	})
+}
+
+// AddSuppression handles POST /api/v1/mail/suppressions
+func (h *Handler) AddSuppression(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	var input SuppressionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	suppression, err := h.service.Suppress(r.Context(), &tenantID, input.Email, ReasonManual, "admin", input.Details, nil)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	api.JSONResponse(w, http.StatusCreated, suppression)
+}
+
+// RemoveSuppression handles DELETE /api/v1/mail/suppressions/{email}
+func (h *Handler) RemoveSuppression(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := tenantFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	removed, err := h.service.RemoveSuppression(r.Context(), tenantID, r.PathValue("email"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	if !removed {
+		api.NotFound(w, "suppression not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MailgunWebhook handles POST /api/v1/mail/webhooks/mailgun
+func (h *Handler) MailgunWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	event, err := ParseMailgunWebhook(h.webhooks.MailgunSigningKey, body, time.Now())
+	if errors.Is(err, ErrInvalidSignature) {
+		h.handleError(w, err)
+		return
+	}
+	if err != nil {
+		api.BadRequest(w, "invalid webhook payload")
+		return
+	}
+	if event != nil {
+		if err := h.service.HandleEvent(r.Context(), event); err != nil {
+			// Mailgun retries on 5xx
+			api.InternalError(w)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// SESWebhook handles POST /api/v1/mail/webhooks/ses, the SNS subscription
+// receiving SES bounce and complaint notifications
+func (h *Handler) SESWebhook(w http.ResponseWriter, r *http.Request) {
+	var msg SNSMessage
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxWebhookBody)).Decode(&msg); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+	if err := h.webhooks.SNS.Verify(r.Context(), &msg); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	switch msg.Type {
+	case "SubscriptionConfirmation":
+		if err := h.webhooks.SNS.ConfirmSubscription(r.Context(), &msg); err != nil {
+			api.InternalError(w)
+			return
+		}
+	case "Notification":
+		events, err := ParseSESNotification([]byte(msg.Message))
+		if err != nil {
+			api.BadRequest(w, err.Error())
+			return
+		}
+		for i := range events {
+			if err := h.service.HandleEvent(r.Context(), &events[i]); err != nil {
+				api.InternalError(w)
+				return
+			}
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrIdentityNotFound):
+		api.NotFound(w, "no sender identity configured")
+	case errors.Is(err, ErrInvalidAddress):
+		api.BadRequest(w, err.Error())
+	case errors.Is(err, ErrInvalidSignature):
+		api.Unauthorized(w, "invalid webhook signature")
+	default:
+		api.InternalError(w)
+	}
+}
+
+func tenantFromContext(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
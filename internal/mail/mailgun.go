@@ -0,0 +1,192 @@
+package mail
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mailgun API base URLs
+const (
+	MailgunAPIBaseUS = "https://api.mailgun.net/v3"
+	MailgunAPIBaseEU = "https://api.eu.mailgun.net/v3"
+)
+
+// MailgunConfig holds Mailgun settings
+type MailgunConfig struct {
+	Domain  string // sending domain registered at Mailgun
+	APIKey  string
+	APIBase string // MailgunAPIBaseEU for EU-hosted domains
+	Timeout time.Duration
+}
+
+// MailgunProvider sends through the Mailgun messages API
+type MailgunProvider struct {
+	config     MailgunConfig
+	httpClient *http.Client
+}
+
+// NewMailgunProvider creates a Mailgun provider
+func NewMailgunProvider(config MailgunConfig) *MailgunProvider {
+	if config.APIBase == "" {
+		config.APIBase = MailgunAPIBaseEU
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &MailgunProvider{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Name returns "mailgun"
+func (p *MailgunProvider) Name() string {
+	return "mailgun"
+}
+
+// Send posts the message to Mailgun
+func (p *MailgunProvider) Send(ctx context.Context, msg *Message) (string, error) {
+	form := url.Values{}
+	form.Set("from", msg.From)
+	form.Set("to", formatAddress(msg.ToName, msg.To))
+	form.Set("subject", msg.Subject)
+	form.Set("text", msg.Text)
+	if msg.HTML != "" {
+		form.Set("html", msg.HTML)
+	}
+	if msg.ReplyTo != "" {
+		form.Set("h:Reply-To", msg.ReplyTo)
+	}
+	if msg.Category != "" {
+		form.Set("o:tag", msg.Category)
+	}
+	if msg.UnsubscribeURL != "" {
+		form.Set("h:List-Unsubscribe", "<"+msg.UnsubscribeURL+">")
+		form.Set("h:List-Unsubscribe-Post", ListUnsubscribePost)
+	}
+
+	endpoint := strings.TrimSuffix(p.config.APIBase, "/") + "/" + url.PathEscape(p.config.Domain) + "/messages"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("api", p.config.APIKey)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("mailgun request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("mailgun returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode mailgun response: %w", err)
+	}
+	return result.ID, nil
+}
+
+// MailgunWebhook is the payload of a Mailgun event webhook
+type MailgunWebhook struct {
+	Signature struct {
+		Timestamp string `json:"timestamp"`
+		Token     string `json:"token"`
+		Signature string `json:"signature"`
+	} `json:"signature"`
+	EventData struct {
+		Event     string `json:"event"`
+		Severity  string `json:"severity"`
+		Recipient string `json:"recipient"`
+		Reason    string `json:"reason"`
+		Message   struct {
+			Headers struct {
+				MessageID string `json:"message-id"`
+			} `json:"headers"`
+		} `json:"message"`
+		DeliveryStatus struct {
+			Code        int    `json:"code"`
+			Description string `json:"description"`
+			Message     string `json:"message"`
+		} `json:"delivery-status"`
+	} `json:"event-data"`
+}
+
+// mailgunWebhookMaxAge bounds replays of captured webhook requests
+const mailgunWebhookMaxAge = 15 * time.Minute
+
+// VerifyMailgunSignature checks the HMAC Mailgun computes over timestamp and
+// token with the webhook signing key
+func VerifyMailgunSignature(signingKey, timestamp, token, signature string, now time.Time) error {
+	if signingKey == "" || timestamp == "" || token == "" {
+		return ErrInvalidSignature
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > mailgunWebhookMaxAge || age < -mailgunWebhookMaxAge {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(timestamp + token))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ParseMailgunWebhook verifies a webhook body and converts it to an event.
+// Events that do not affect deliverability return nil.
+func ParseMailgunWebhook(signingKey string, body []byte, now time.Time) (*Event, error) {
+	var hook MailgunWebhook
+	if err := json.Unmarshal(body, &hook); err != nil {
+		return nil, fmt.Errorf("invalid mailgun webhook: %w", err)
+	}
+	if err := VerifyMailgunSignature(signingKey, hook.Signature.Timestamp, hook.Signature.Token, hook.Signature.Signature, now); err != nil {
+		return nil, err
+	}
+
+	data := hook.EventData
+	event := &Event{
+		Provider:  "mailgun",
+		Email:     data.Recipient,
+		MessageID: data.Message.Headers.MessageID,
+		Details:   strings.TrimSpace(fmt.Sprintf("%d %s %s", data.DeliveryStatus.Code, data.DeliveryStatus.Description, data.DeliveryStatus.Message)),
+	}
+	switch data.Event {
+	case "failed":
+		if data.Severity == "permanent" {
+			event.Type = EventHardBounce
+		} else {
+			event.Type = EventSoftBounce
+		}
+	case "complained":
+		event.Type = EventComplaint
+		event.Details = ""
+	case "unsubscribed":
+		event.Type = EventUnsubscribe
+		event.Details = ""
+	default:
+		return nil, nil
+	}
+	return event, nil
+}
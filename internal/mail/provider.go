@@ -0,0 +1,112 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+
+	"austrian-business-infrastructure/internal/config"
+)
+
+// Provider delivers messages through a mail service
+type Provider interface {
+	// Name identifies the provider in logs, events and suppressions
+	Name() string
+	// Send delivers a message whose From is set and returns the provider's
+	// message ID
+	Send(ctx context.Context, msg *Message) (string, error)
+}
+
+// NewProvider creates the provider selected by MAIL_PROVIDER
+func NewProvider(cfg *config.MailConfig, logger *slog.Logger) (Provider, error) {
+	switch cfg.Provider {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required for the smtp mail provider")
+		}
+		return NewSMTPProvider(SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			User:     cfg.SMTPUser,
+			Password: cfg.SMTPPassword,
+		}), nil
+	case "mailgun":
+		if cfg.MailgunDomain == "" || cfg.MailgunAPIKey == "" {
+			return nil, fmt.Errorf("MAILGUN_DOMAIN and MAILGUN_API_KEY are required for the mailgun mail provider")
+		}
+		apiBase := MailgunAPIBaseEU
+		if cfg.MailgunRegion == "us" {
+			apiBase = MailgunAPIBaseUS
+		}
+		return NewMailgunProvider(MailgunConfig{
+			Domain:  cfg.MailgunDomain,
+			APIKey:  cfg.MailgunAPIKey,
+			APIBase: apiBase,
+		}), nil
+	case "ses":
+		if cfg.SESAccessKeyID == "" || cfg.SESSecretAccessKey == "" {
+			return nil, fmt.Errorf("SES_ACCESS_KEY_ID and SES_SECRET_ACCESS_KEY are required for the ses mail provider")
+		}
+		return NewSESProvider(SESConfig{
+			Region:           cfg.SESRegion,
+			Credentials:      AWSCredentials{AccessKeyID: cfg.SESAccessKeyID, SecretAccessKey: cfg.SESSecretAccessKey},
+			ConfigurationSet: cfg.SESConfigurationSet,
+		}), nil
+	case "log":
+		return NewLogProvider(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown mail provider %q", cfg.Provider)
+	}
+}
+
+// LogProvider logs messages instead of sending them. It is the default when
+// no provider is configured, e.g. in development.
+type LogProvider struct {
+	logger *slog.Logger
+}
+
+// NewLogProvider creates a provider that only logs
+func NewLogProvider(logger *slog.Logger) *LogProvider {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LogProvider{logger: logger}
+}
+
+// Name returns "log"
+func (p *LogProvider) Name() string {
+	return "log"
+}
+
+// Send logs the envelope; bodies may contain tokens and are not logged
+func (p *LogProvider) Send(ctx context.Context, msg *Message) (string, error) {
+	id := newMessageID(msg.From)
+	p.logger.Info("mail not sent, no provider configured",
+		"to", msg.To, "from", msg.From, "subject", msg.Subject, "category", msg.Category, "message_id", id)
+	return id, nil
+}
+
+// formatAddress renders an address with an optional display name
+func formatAddress(name, address string) string {
+	if name == "" {
+		return address
+	}
+	return (&mail.Address{Name: name, Address: address}).String()
+}
+
+// newMessageID returns a Message-ID in the domain of the from address
+func newMessageID(from string) string {
+	domain := "localhost"
+	if parsed, err := mail.ParseAddress(from); err == nil {
+		if _, d, ok := strings.Cut(parsed.Address, "@"); ok {
+			domain = d
+		}
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
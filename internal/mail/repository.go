@@ -0,0 +1,225 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles sender identity, suppression and event database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new mail repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const identityColumns = `
+	tenant_id, from_address, COALESCE(from_name, ''), COALESCE(reply_to, ''), domain,
+	spf_verified, dkim_verified, dmarc_present, verified_at, last_checked_at, created_at, updated_at`
+
+func scanIdentity(row pgx.Row) (*SenderIdentity, error) {
+	var i SenderIdentity
+	err := row.Scan(&i.TenantID, &i.FromAddress, &i.FromName, &i.ReplyTo, &i.Domain,
+		&i.SPFVerified, &i.DKIMVerified, &i.DMARCPresent, &i.VerifiedAt, &i.LastCheckedAt, &i.CreatedAt, &i.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrIdentityNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// GetIdentity returns a tenant's sender identity
+func (r *Repository) GetIdentity(ctx context.Context, tenantID uuid.UUID) (*SenderIdentity, error) {
+	identity, err := scanIdentity(r.db.QueryRow(ctx, `SELECT `+identityColumns+` FROM mail_sender_identities WHERE tenant_id = $1`, tenantID))
+	if err != nil && !errors.Is(err, ErrIdentityNotFound) {
+		return nil, fmt.Errorf("get sender identity: %w", err)
+	}
+	return identity, err
+}
+
+// UpsertIdentity creates or replaces a tenant's sender identity. Changing the
+// domain resets its verification.
+func (r *Repository) UpsertIdentity(ctx context.Context, tenantID uuid.UUID, fromAddress, fromName, replyTo, domain string) (*SenderIdentity, error) {
+	identity, err := scanIdentity(r.db.QueryRow(ctx, `
+		INSERT INTO mail_sender_identities (tenant_id, from_address, from_name, reply_to, domain)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			from_address = EXCLUDED.from_address,
+			from_name = EXCLUDED.from_name,
+			reply_to = EXCLUDED.reply_to,
+			domain = EXCLUDED.domain,
+			spf_verified = mail_sender_identities.spf_verified AND mail_sender_identities.domain = EXCLUDED.domain,
+			dkim_verified = mail_sender_identities.dkim_verified AND mail_sender_identities.domain = EXCLUDED.domain,
+			dmarc_present = mail_sender_identities.dmarc_present AND mail_sender_identities.domain = EXCLUDED.domain,
+			verified_at = CASE WHEN mail_sender_identities.domain = EXCLUDED.domain THEN mail_sender_identities.verified_at END,
+			updated_at = NOW()
+		RETURNING `+identityColumns,
+		tenantID, fromAddress, fromName, replyTo, domain))
+	if err != nil {
+		return nil, fmt.Errorf("upsert sender identity: %w", err)
+	}
+	return identity, nil
+}
+
+// UpdateVerification stores the result of a DNS check
+func (r *Repository) UpdateVerification(ctx context.Context, tenantID uuid.UUID, check *DNSCheck) (*SenderIdentity, error) {
+	identity, err := scanIdentity(r.db.QueryRow(ctx, `
+		UPDATE mail_sender_identities SET
+			spf_verified = $2,
+			dkim_verified = $3,
+			dmarc_present = $4,
+			verified_at = CASE WHEN $2 AND $3 THEN COALESCE(verified_at, NOW()) END,
+			last_checked_at = NOW(),
+			updated_at = NOW()
+		WHERE tenant_id = $1 AND domain = $5
+		RETURNING `+identityColumns,
+		tenantID, check.SPF, check.DKIM, check.DMARC, check.Domain))
+	if err != nil && !errors.Is(err, ErrIdentityNotFound) {
+		return nil, fmt.Errorf("update sender verification: %w", err)
+	}
+	return identity, err
+}
+
+// DeleteIdentity removes a tenant's sender identity
+func (r *Repository) DeleteIdentity(ctx context.Context, tenantID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM mail_sender_identities WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("delete sender identity: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrIdentityNotFound
+	}
+	return nil
+}
+
+const suppressionColumns = `id, tenant_id, email, reason, source, COALESCE(details, ''), expires_at, created_at`
+
+func scanSuppression(row pgx.Row) (*Suppression, error) {
+	var s Suppression
+	if err := row.Scan(&s.ID, &s.TenantID, &s.Email, &s.Reason, &s.Source, &s.Details, &s.ExpiresAt, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// FindSuppression returns the active suppression of an address for a
+// tenant, global or tenant-scoped, or nil. Unsubscribes come last, as
+// required mails pass them.
+func (r *Repository) FindSuppression(ctx context.Context, tenantID *uuid.UUID, email string) (*Suppression, error) {
+	s, err := scanSuppression(r.db.QueryRow(ctx, `
+		SELECT `+suppressionColumns+`
+		FROM mail_suppressions
+		WHERE lower(email) = lower($1)
+		  AND (tenant_id IS NULL OR tenant_id = $2)
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		ORDER BY reason = 'unsubscribe', expires_at DESC NULLS FIRST
+		LIMIT 1
+	`, email, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find suppression: %w", err)
+	}
+	return s, nil
+}
+
+// AddSuppression suppresses an address. An existing permanent suppression
+// in the same scope is never replaced by a temporary one.
+func (r *Repository) AddSuppression(ctx context.Context, s *Suppression) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO mail_suppressions (tenant_id, email, reason, source, details, expires_at)
+		VALUES ($1, lower($2), $3, $4, NULLIF($5, ''), $6)
+		ON CONFLICT (lower(email), COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'::uuid)) DO UPDATE SET
+			reason = EXCLUDED.reason,
+			source = EXCLUDED.source,
+			details = EXCLUDED.details,
+			expires_at = EXCLUDED.expires_at,
+			created_at = NOW()
+		WHERE EXCLUDED.expires_at IS NULL
+		   OR mail_suppressions.expires_at IS NOT NULL
+		RETURNING id, created_at
+	`, s.TenantID, s.Email, s.Reason, s.Source, s.Details, s.ExpiresAt).Scan(&s.ID, &s.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// A permanent suppression already applies
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("add suppression: %w", err)
+	}
+	return nil
+}
+
+// ListSuppressions lists a tenant's own suppressions, newest first
+func (r *Repository) ListSuppressions(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*Suppression, int, error) {
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM mail_suppressions WHERE tenant_id = $1`, tenantID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count suppressions: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+suppressionColumns+`
+		FROM mail_suppressions
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, tenantID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list suppressions: %w", err)
+	}
+	defer rows.Close()
+
+	var suppressions []*Suppression
+	for rows.Next() {
+		s, err := scanSuppression(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan suppression: %w", err)
+		}
+		suppressions = append(suppressions, s)
+	}
+	return suppressions, total, rows.Err()
+}
+
+// DeleteSuppression removes a tenant's own suppression of an address
+func (r *Repository) DeleteSuppression(ctx context.Context, tenantID uuid.UUID, email string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM mail_suppressions WHERE tenant_id = $1 AND lower(email) = lower($2)`, tenantID, email)
+	if err != nil {
+		return false, fmt.Errorf("delete suppression: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RecordEvent stores a provider event
+func (r *Repository) RecordEvent(ctx context.Context, e *Event) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO mail_events (provider, event_type, email, message_id, details)
+		VALUES ($1, $2, lower($3), NULLIF($4, ''), NULLIF($5, ''))
+	`, e.Provider, e.Type, e.Email, e.MessageID, e.Details)
+	if err != nil {
+		return fmt.Errorf("record mail event: %w", err)
+	}
+	return nil
+}
+
+// CountEvents counts an address's events of a type since a time
+func (r *Repository) CountEvents(ctx context.Context, email, eventType string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM mail_events
+		WHERE lower(email) = lower($1) AND event_type = $2 AND created_at >= $3
+	`, email, eventType, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count mail events: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,444 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store persists sender identities, suppressions and provider events;
+// *Repository implements it
+type Store interface {
+	GetIdentity(ctx context.Context, tenantID uuid.UUID) (*SenderIdentity, error)
+	UpsertIdentity(ctx context.Context, tenantID uuid.UUID, fromAddress, fromName, replyTo, domain string) (*SenderIdentity, error)
+	UpdateVerification(ctx context.Context, tenantID uuid.UUID, check *DNSCheck) (*SenderIdentity, error)
+	DeleteIdentity(ctx context.Context, tenantID uuid.UUID) error
+	FindSuppression(ctx context.Context, tenantID *uuid.UUID, email string) (*Suppression, error)
+	AddSuppression(ctx context.Context, s *Suppression) error
+	ListSuppressions(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*Suppression, int, error)
+	DeleteSuppression(ctx context.Context, tenantID uuid.UUID, email string) (bool, error)
+	RecordEvent(ctx context.Context, e *Event) error
+	CountEvents(ctx context.Context, email, eventType string, since time.Time) (int, error)
+}
+
+// BrandProvider returns the brand of a tenant's mails, nil if the tenant
+// has none
+type BrandProvider interface {
+	MailBrand(ctx context.Context, tenantID uuid.UUID) (*Brand, error)
+}
+
+// Preference is a recipient's choice about a category of mail
+type Preference struct {
+	Allowed bool
+	// Required mails, such as password resets, are sent even to addresses
+	// that unsubscribed at the provider
+	Required       bool
+	UnsubscribeURL string // one-click unsubscribe link, if the mail has one
+}
+
+// Preferences returns the preference of a recipient for a category of
+// mail; notification.Service implements it
+type Preferences interface {
+	MailPreference(ctx context.Context, tenantID *uuid.UUID, email, category string) (*Preference, error)
+}
+
+// ServiceConfig holds the mail service configuration
+type ServiceConfig struct {
+	From     string // platform address, used until a tenant domain is verified
+	FromName string
+	DNS      DNSConfig
+	Resolver Resolver // defaults to net.DefaultResolver
+	Logger   *slog.Logger
+}
+
+// Service sends mail through the configured provider. Every message passes
+// the suppression list and gets its sender from the tenant's identity.
+type Service struct {
+	provider Provider
+	store    Store
+	renderer *Renderer
+	brands   BrandProvider
+	prefs    Preferences
+	config   ServiceConfig
+	logger   *slog.Logger
+}
+
+// NewService creates a mail service. A nil store disables suppressions and
+// tenant identities, e.g. in tools without a database.
+func NewService(provider Provider, store Store, renderer *Renderer, config ServiceConfig) *Service {
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	if config.Resolver == nil {
+		config.Resolver = net.DefaultResolver
+	}
+	return &Service{
+		provider: provider,
+		store:    store,
+		renderer: renderer,
+		config:   config,
+		logger:   config.Logger,
+	}
+}
+
+// SetBrandProvider renders tenant mails in the tenant's brand
+func (s *Service) SetBrandProvider(brands BrandProvider) {
+	s.brands = brands
+}
+
+// SetPreferences enforces the notification preferences of recipients and
+// adds unsubscribe links to notifications
+func (s *Service) SetPreferences(prefs Preferences) {
+	s.prefs = prefs
+}
+
+// ProviderName returns the name of the configured provider
+func (s *Service) ProviderName() string {
+	return s.provider.Name()
+}
+
+// Send delivers a message unless its recipient is suppressed or opted out
+func (s *Service) Send(ctx context.Context, msg *Message) error {
+	to, err := recipient(msg.To)
+	if err != nil {
+		return err
+	}
+	msg.To = to
+
+	pref, err := s.preference(ctx, msg.TenantID, to, msg.Category)
+	if err != nil {
+		return err
+	}
+	if msg.UnsubscribeURL == "" {
+		msg.UnsubscribeURL = pref.UnsubscribeURL
+	}
+	return s.deliver(ctx, msg, pref)
+}
+
+// SendTemplate renders a template and sends it. Notifications get the
+// unsubscribe link of the recipient in their footer.
+func (s *Service) SendTemplate(ctx context.Context, tenantID *uuid.UUID, to, template string, data any) error {
+	address, err := recipient(to)
+	if err != nil {
+		return err
+	}
+	pref, err := s.preference(ctx, tenantID, address, template)
+	if err != nil {
+		return err
+	}
+	if !pref.Allowed {
+		s.logger.Info("mail not sent, recipient opted out", "to", address, "category", template)
+		return ErrOptedOut
+	}
+
+	rendered, err := s.renderer.render(template, data, s.brand(ctx, tenantID), pref.UnsubscribeURL)
+	if err != nil {
+		return err
+	}
+	return s.deliver(ctx, &Message{
+		TenantID:       tenantID,
+		To:             address,
+		Subject:        rendered.Subject,
+		Text:           rendered.Text,
+		HTML:           rendered.HTML,
+		Category:       template,
+		UnsubscribeURL: pref.UnsubscribeURL,
+	}, pref)
+}
+
+// recipient normalizes the address of a message
+func recipient(to string) (string, error) {
+	if to == "" {
+		return "", ErrNoRecipient
+	}
+	return NormalizeAddress(to)
+}
+
+// preference returns the recipient's preference for a category; without
+// preferences every mail is allowed
+func (s *Service) preference(ctx context.Context, tenantID *uuid.UUID, to, category string) (*Preference, error) {
+	if s.prefs == nil {
+		return &Preference{Allowed: true}, nil
+	}
+	return s.prefs.MailPreference(ctx, tenantID, to, category)
+}
+
+// deliver sends a message through the provider unless the recipient opted
+// out or is suppressed. Unsubscribes reported by the provider do not stop
+// required mails; bounces and complaints do.
+func (s *Service) deliver(ctx context.Context, msg *Message, pref *Preference) error {
+	if !pref.Allowed {
+		s.logger.Info("mail not sent, recipient opted out", "to", msg.To, "category", msg.Category)
+		return ErrOptedOut
+	}
+
+	if s.store != nil {
+		suppression, err := s.store.FindSuppression(ctx, msg.TenantID, msg.To)
+		if err != nil {
+			return err
+		}
+		if suppression != nil && !(pref.Required && suppression.Reason == ReasonUnsubscribe) {
+			s.logger.Info("mail suppressed", "to", msg.To, "reason", suppression.Reason, "category", msg.Category)
+			return ErrSuppressed
+		}
+	}
+
+	s.applySender(ctx, msg)
+
+	id, err := s.provider.Send(ctx, msg)
+	if err != nil {
+		s.logger.Error("mail delivery failed", "provider", s.provider.Name(), "to", msg.To, "category", msg.Category, "error", err)
+		return err
+	}
+	s.logger.Debug("mail sent", "provider", s.provider.Name(), "to", msg.To, "category", msg.Category, "message_id", id)
+	return nil
+}
+
+// applySender sets From and Reply-To from the tenant's identity
+func (s *Service) applySender(ctx context.Context, msg *Message) {
+	var identity *SenderIdentity
+	if msg.TenantID != nil && s.store != nil {
+		var err error
+		identity, err = s.store.GetIdentity(ctx, *msg.TenantID)
+		if err != nil && !errors.Is(err, ErrIdentityNotFound) {
+			s.logger.Warn("failed to load sender identity, using platform address", "tenant_id", *msg.TenantID, "error", err)
+		}
+	}
+
+	senderName := ""
+	if brand := s.brand(ctx, msg.TenantID); brand != nil {
+		senderName = brand.SenderName
+	}
+
+	from, replyTo := s.sender(identity, senderName)
+	msg.From = from
+	if msg.ReplyTo == "" {
+		msg.ReplyTo = replyTo
+	}
+}
+
+// brand returns the brand of a tenant's mails; failures fall back to the
+// platform look
+func (s *Service) brand(ctx context.Context, tenantID *uuid.UUID) *Brand {
+	if tenantID == nil || s.brands == nil {
+		return nil
+	}
+	brand, err := s.brands.MailBrand(ctx, *tenantID)
+	if err != nil {
+		s.logger.Warn("failed to load mail brand, using platform look", "tenant_id", *tenantID, "error", err)
+		return nil
+	}
+	return brand
+}
+
+// sender returns From and Reply-To for an identity. Tenants with a verified
+// domain send from their own address; otherwise the platform address is
+// used with the tenant's address as Reply-To. The identity's name takes
+// precedence over the brand's sender name.
+func (s *Service) sender(identity *SenderIdentity, brandName string) (from, replyTo string) {
+	name := brandName
+	if identity != nil && identity.FromName != "" {
+		name = identity.FromName
+	}
+
+	if identity != nil && identity.Verified() {
+		if name == "" {
+			name = s.config.FromName
+		}
+		return formatAddress(name, identity.FromAddress), identity.ReplyTo
+	}
+
+	from = formatAddress(s.config.FromName, s.config.From)
+	if name != "" {
+		from = formatAddress(name+" via "+s.config.FromName, s.config.From)
+	}
+	if identity == nil {
+		return from, ""
+	}
+	replyTo = identity.ReplyTo
+	if replyTo == "" {
+		replyTo = identity.FromAddress
+	}
+	return from, replyTo
+}
+
+// SenderIdentityView is a sender identity with the DNS records its domain needs
+type SenderIdentityView struct {
+	*SenderIdentity
+	Verified      bool        `json:"verified"`
+	EffectiveFrom string      `json:"effective_from"`
+	Records       []DNSRecord `json:"dns_records"`
+}
+
+// GetSenderIdentity returns a tenant's identity and the records to publish
+func (s *Service) GetSenderIdentity(ctx context.Context, tenantID uuid.UUID) (*SenderIdentityView, error) {
+	identity, err := s.store.GetIdentity(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.view(ctx, identity, s.config.DNS.Guidance(identity.Domain)), nil
+}
+
+// SetSenderIdentity sets a tenant's from address
+func (s *Service) SetSenderIdentity(ctx context.Context, tenantID uuid.UUID, input *SenderIdentityInput) (*SenderIdentityView, error) {
+	from, err := NormalizeAddress(input.FromAddress)
+	if err != nil {
+		return nil, fmt.Errorf("%w: from_address", ErrInvalidAddress)
+	}
+	replyTo := ""
+	if strings.TrimSpace(input.ReplyTo) != "" {
+		if replyTo, err = NormalizeAddress(input.ReplyTo); err != nil {
+			return nil, fmt.Errorf("%w: reply_to", ErrInvalidAddress)
+		}
+	}
+	name := strings.Join(strings.Fields(input.FromName), " ")
+
+	identity, err := s.store.UpsertIdentity(ctx, tenantID, from, name, replyTo, addressDomain(from))
+	if err != nil {
+		return nil, err
+	}
+	return s.view(ctx, identity, s.config.DNS.Guidance(identity.Domain)), nil
+}
+
+// VerifySenderIdentity checks the DNS records of a tenant's domain and
+// stores the result
+func (s *Service) VerifySenderIdentity(ctx context.Context, tenantID uuid.UUID) (*SenderIdentityView, error) {
+	identity, err := s.store.GetIdentity(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	check, err := s.config.DNS.Check(ctx, s.config.Resolver, identity.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("dns lookup failed: %w", err)
+	}
+	identity, err = s.store.UpdateVerification(ctx, tenantID, check)
+	if err != nil {
+		return nil, err
+	}
+	return s.view(ctx, identity, check.Records), nil
+}
+
+// DeleteSenderIdentity removes a tenant's identity; mail is sent from the
+// platform address again
+func (s *Service) DeleteSenderIdentity(ctx context.Context, tenantID uuid.UUID) error {
+	return s.store.DeleteIdentity(ctx, tenantID)
+}
+
+func (s *Service) view(ctx context.Context, identity *SenderIdentity, records []DNSRecord) *SenderIdentityView {
+	senderName := ""
+	if brand := s.brand(ctx, &identity.TenantID); brand != nil {
+		senderName = brand.SenderName
+	}
+	from, _ := s.sender(identity, senderName)
+	return &SenderIdentityView{
+		SenderIdentity: identity,
+		Verified:       identity.Verified(),
+		EffectiveFrom:  from,
+		Records:        records,
+	}
+}
+
+// ListSuppressions lists a tenant's own suppressions
+func (s *Service) ListSuppressions(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*Suppression, int, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.store.ListSuppressions(ctx, tenantID, limit, offset)
+}
+
+// CheckSuppression returns the suppression applying to an address for a
+// tenant, or nil
+func (s *Service) CheckSuppression(ctx context.Context, tenantID uuid.UUID, email string) (*Suppression, error) {
+	address, err := NormalizeAddress(email)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.FindSuppression(ctx, &tenantID, address)
+}
+
+// Suppress adds a suppression. A nil tenant suppresses the address for
+// every sender.
+func (s *Service) Suppress(ctx context.Context, tenantID *uuid.UUID, email, reason, source, details string, expiresAt *time.Time) (*Suppression, error) {
+	address, err := NormalizeAddress(email)
+	if err != nil {
+		return nil, err
+	}
+	suppression := &Suppression{
+		TenantID:  tenantID,
+		Email:     address,
+		Reason:    reason,
+		Source:    source,
+		Details:   details,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.store.AddSuppression(ctx, suppression); err != nil {
+		return nil, err
+	}
+	return suppression, nil
+}
+
+// RemoveSuppression removes a tenant's own suppression. Bounces and
+// complaints are global and cannot be removed by tenants.
+func (s *Service) RemoveSuppression(ctx context.Context, tenantID uuid.UUID, email string) (bool, error) {
+	address, err := NormalizeAddress(email)
+	if err != nil {
+		return false, err
+	}
+	return s.store.DeleteSuppression(ctx, tenantID, address)
+}
+
+// HandleEvent records a provider event and suppresses the address: hard
+// bounces, complaints and unsubscribes permanently, soft bounces for a day
+// once they repeat
+func (s *Service) HandleEvent(ctx context.Context, e *Event) error {
+	address, err := NormalizeAddress(e.Email)
+	if err != nil {
+		s.logger.Warn("mail event for invalid address ignored", "provider", e.Provider, "type", e.Type)
+		return nil
+	}
+	e.Email = address
+
+	if err := s.store.RecordEvent(ctx, e); err != nil {
+		return err
+	}
+
+	var reason string
+	var expiresAt *time.Time
+	switch e.Type {
+	case EventHardBounce:
+		reason = ReasonHardBounce
+	case EventComplaint:
+		reason = ReasonComplaint
+	case EventUnsubscribe:
+		reason = ReasonUnsubscribe
+	case EventSoftBounce:
+		count, err := s.store.CountEvents(ctx, address, EventSoftBounce, time.Now().Add(-SoftBounceWindow))
+		if err != nil {
+			return err
+		}
+		if count < SoftBounceThreshold {
+			return nil
+		}
+		reason = ReasonSoftBounce
+		until := time.Now().Add(SoftBounceSuppression)
+		expiresAt = &until
+	default:
+		return nil
+	}
+
+	if _, err := s.Suppress(ctx, nil, address, reason, e.Provider, e.Details, expiresAt); err != nil {
+		return err
+	}
+	s.logger.Info("mail address suppressed", "provider", e.Provider, "reason", reason, "message_id", e.MessageID)
+	return nil
+}
@@ -0,0 +1,390 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SESConfig holds Amazon SES settings
+type SESConfig struct {
+	Region           string // e.g. eu-central-1
+	Credentials      AWSCredentials
+	ConfigurationSet string // optional, publishes bounces and complaints to SNS
+	Endpoint         string // overrides https://email.{region}.amazonaws.com
+	Timeout          time.Duration
+}
+
+// SESProvider sends through the Amazon SES v2 API
+type SESProvider struct {
+	config     SESConfig
+	httpClient *http.Client
+}
+
+// NewSESProvider creates an SES provider
+func NewSESProvider(config SESConfig) *SESProvider {
+	if config.Region == "" {
+		config.Region = "eu-central-1"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://email." + config.Region + ".amazonaws.com"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &SESProvider{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Name returns "ses"
+func (p *SESProvider) Name() string {
+	return "ses"
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+type sesHeader struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+type sesTag struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+type sesSendEmailRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	ReplyToAddresses []string `json:"ReplyToAddresses,omitempty"`
+	Content          struct {
+		Simple struct {
+			Subject sesContent `json:"Subject"`
+			Body    struct {
+				Text *sesContent `json:"Text,omitempty"`
+				HTML *sesContent `json:"Html,omitempty"`
+			} `json:"Body"`
+			Headers []sesHeader `json:"Headers,omitempty"`
+		} `json:"Simple"`
+	} `json:"Content"`
+	EmailTags            []sesTag `json:"EmailTags,omitempty"`
+	ConfigurationSetName string   `json:"ConfigurationSetName,omitempty"`
+}
+
+// Send calls SendEmail of the SES v2 API
+func (p *SESProvider) Send(ctx context.Context, msg *Message) (string, error) {
+	var payload sesSendEmailRequest
+	payload.FromEmailAddress = msg.From
+	payload.Destination.ToAddresses = []string{formatAddress(msg.ToName, msg.To)}
+	if msg.ReplyTo != "" {
+		payload.ReplyToAddresses = []string{msg.ReplyTo}
+	}
+	payload.Content.Simple.Subject = sesContent{Data: msg.Subject, Charset: "UTF-8"}
+	payload.Content.Simple.Body.Text = &sesContent{Data: msg.Text, Charset: "UTF-8"}
+	if msg.HTML != "" {
+		payload.Content.Simple.Body.HTML = &sesContent{Data: msg.HTML, Charset: "UTF-8"}
+	}
+	if msg.Category != "" {
+		payload.EmailTags = []sesTag{{Name: "category", Value: msg.Category}}
+	}
+	if msg.UnsubscribeURL != "" {
+		payload.Content.Simple.Headers = []sesHeader{
+			{Name: "List-Unsubscribe", Value: "<" + msg.UnsubscribeURL + ">"},
+			{Name: "List-Unsubscribe-Post", Value: ListUnsubscribePost},
+		}
+	}
+	payload.ConfigurationSetName = p.config.ConfigurationSet
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := strings.TrimSuffix(p.config.Endpoint, "/") + "/v2/email/outbound-emails"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SignV4(req, body, p.config.Credentials, p.config.Region, "ses", time.Now())
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ses request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ses returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var result struct {
+		MessageID string `json:"MessageId"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("failed to decode ses response: %w", err)
+	}
+	return result.MessageID, nil
+}
+
+// SNSMessage is an Amazon SNS HTTP(S) delivery
+type SNSMessage struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicARN         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL"`
+}
+
+// snsCertHost matches the hosts SNS serves its signing certificates from
+var snsCertHost = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// SNSVerifier checks SNS message signatures against the signing certificate
+type SNSVerifier struct {
+	topicARN   string
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	certs map[string]*x509.Certificate
+}
+
+// NewSNSVerifier creates a verifier accepting messages of one topic
+func NewSNSVerifier(topicARN string) *SNSVerifier {
+	return &SNSVerifier{
+		topicARN:   topicARN,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		certs:      make(map[string]*x509.Certificate),
+	}
+}
+
+// Verify checks the topic and the signature of a message
+func (v *SNSVerifier) Verify(ctx context.Context, msg *SNSMessage) error {
+	if v.topicARN == "" || msg.TopicARN != v.topicARN {
+		return ErrInvalidSignature
+	}
+
+	var hash crypto.Hash
+	switch msg.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return ErrInvalidSignature
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(msg.Signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	cert, err := v.certificate(ctx, msg.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return ErrInvalidSignature
+	}
+
+	canonical := []byte(snsStringToSign(msg))
+	var digest []byte
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum(canonical)
+		digest = sum[:]
+	} else {
+		sum := sha256.Sum256(canonical)
+		digest = sum[:]
+	}
+	if err := rsa.VerifyPKCS1v15(pub, hash, digest, signature); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// snsStringToSign builds the canonical form SNS signs
+func snsStringToSign(msg *SNSMessage) string {
+	var fields [][2]string
+	if msg.Type == "Notification" {
+		fields = [][2]string{{"Message", msg.Message}, {"MessageId", msg.MessageID}}
+		if msg.Subject != "" {
+			fields = append(fields, [2]string{"Subject", msg.Subject})
+		}
+		fields = append(fields, [][2]string{{"Timestamp", msg.Timestamp}, {"TopicArn", msg.TopicARN}, {"Type", msg.Type}}...)
+	} else {
+		fields = [][2]string{
+			{"Message", msg.Message}, {"MessageId", msg.MessageID}, {"SubscribeURL", msg.SubscribeURL},
+			{"Timestamp", msg.Timestamp}, {"Token", msg.Token}, {"TopicArn", msg.TopicARN}, {"Type", msg.Type},
+		}
+	}
+
+	var b strings.Builder
+	for _, f := range fields {
+		b.WriteString(f[0] + "\n" + f[1] + "\n")
+	}
+	return b.String()
+}
+
+// certificate fetches and caches an SNS signing certificate, refusing URLs
+// outside amazonaws.com
+func (v *SNSVerifier) certificate(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	u, err := url.Parse(certURL)
+	if err != nil || u.Scheme != "https" || !snsCertHost.MatchString(u.Hostname()) || !strings.HasSuffix(u.Path, ".pem") {
+		return nil, ErrInvalidSignature
+	}
+
+	v.mu.Lock()
+	cert, ok := v.certs[certURL]
+	v.mu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sns signing certificate: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch sns signing certificate: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid sns signing certificate")
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sns signing certificate: %w", err)
+	}
+
+	v.mu.Lock()
+	v.certs[certURL] = cert
+	v.mu.Unlock()
+	return cert, nil
+}
+
+// ConfirmSubscription visits the SubscribeURL of a verified subscription
+// confirmation
+func (v *SNSVerifier) ConfirmSubscription(ctx context.Context, msg *SNSMessage) error {
+	u, err := url.Parse(msg.SubscribeURL)
+	if err != nil || u.Scheme != "https" || !snsCertHost.MatchString(u.Hostname()) {
+		return ErrInvalidSignature
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, msg.SubscribeURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to confirm sns subscription: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to confirm sns subscription: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sesNotification is an SES bounce or complaint notification, either from
+// feedback forwarding (notificationType) or event publishing (eventType)
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Bounce           struct {
+		BounceType        string `json:"bounceType"`
+		BounceSubType     string `json:"bounceSubType"`
+		BouncedRecipients []struct {
+			EmailAddress   string `json:"emailAddress"`
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplainedRecipients []struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"complainedRecipients"`
+		ComplaintFeedbackType string `json:"complaintFeedbackType"`
+	} `json:"complaint"`
+	Mail struct {
+		MessageID string `json:"messageId"`
+	} `json:"mail"`
+}
+
+// ParseSESNotification converts the Message of an SNS notification into
+// events, one per affected recipient
+func ParseSESNotification(message []byte) ([]Event, error) {
+	var n sesNotification
+	if err := json.Unmarshal(message, &n); err != nil {
+		return nil, fmt.Errorf("invalid ses notification: %w", err)
+	}
+
+	kind := n.NotificationType
+	if kind == "" {
+		kind = n.EventType
+	}
+
+	var events []Event
+	switch kind {
+	case "Bounce":
+		eventType := EventSoftBounce
+		if n.Bounce.BounceType == "Permanent" {
+			eventType = EventHardBounce
+		}
+		for _, r := range n.Bounce.BouncedRecipients {
+			events = append(events, Event{
+				Provider:  "ses",
+				Type:      eventType,
+				Email:     r.EmailAddress,
+				MessageID: n.Mail.MessageID,
+				Details:   strings.TrimSpace(n.Bounce.BounceSubType + " " + r.DiagnosticCode),
+			})
+		}
+	case "Complaint":
+		for _, r := range n.Complaint.ComplainedRecipients {
+			events = append(events, Event{
+				Provider:  "ses",
+				Type:      EventComplaint,
+				Email:     r.EmailAddress,
+				MessageID: n.Mail.MessageID,
+				Details:   n.Complaint.ComplaintFeedbackType,
+			})
+		}
+	}
+	return events, nil
+}
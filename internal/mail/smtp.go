@@ -0,0 +1,157 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig holds SMTP relay settings
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Timeout  time.Duration
+}
+
+// SMTPProvider sends through an SMTP relay with STARTTLS
+type SMTPProvider struct {
+	config SMTPConfig
+}
+
+// NewSMTPProvider creates an SMTP provider
+func NewSMTPProvider(config SMTPConfig) *SMTPProvider {
+	if config.Port == 0 {
+		config.Port = 587
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &SMTPProvider{config: config}
+}
+
+// Name returns "smtp"
+func (p *SMTPProvider) Name() string {
+	return "smtp"
+}
+
+// Send delivers the message to the relay
+func (p *SMTPProvider) Send(ctx context.Context, msg *Message) (string, error) {
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return "", fmt.Errorf("%w: from", ErrInvalidAddress)
+	}
+
+	id := newMessageID(msg.From)
+	raw, err := BuildMIME(msg, id, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	addr := net.JoinHostPort(p.config.Host, strconv.Itoa(p.config.Port))
+	var auth smtp.Auth
+	if p.config.User != "" && p.config.Password != "" {
+		auth = smtp.PlainAuth("", p.config.User, p.config.Password, p.config.Host)
+	}
+
+	// net/smtp has no context support; bound the exchange by the timeout
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, from.Address, []string{msg.To}, raw)
+	}()
+
+	timer := time.NewTimer(p.config.Timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			return "", fmt.Errorf("smtp send failed: %w", err)
+		}
+		return id, nil
+	case <-timer.C:
+		return "", fmt.Errorf("smtp send timed out after %s", p.config.Timeout)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// BuildMIME renders a message as RFC 5322 with a quoted-printable text part
+// and, if set, an HTML alternative
+func BuildMIME(msg *Message, messageID string, date time.Time) ([]byte, error) {
+	if msg.To == "" {
+		return nil, ErrNoRecipient
+	}
+
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+
+	header("From", msg.From)
+	header("To", formatAddress(msg.ToName, msg.To))
+	if msg.ReplyTo != "" {
+		header("Reply-To", msg.ReplyTo)
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", messageID)
+	header("MIME-Version", "1.0")
+	if msg.Category != "" {
+		header("X-Category", msg.Category)
+	}
+	if msg.UnsubscribeURL != "" {
+		header("List-Unsubscribe", "<"+msg.UnsubscribeURL+">")
+		header("List-Unsubscribe-Post", ListUnsubscribePost)
+	}
+
+	if msg.HTML == "" {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	b := make([]byte, 12)
+	rand.Read(b)
+	boundary := "=_" + hex.EncodeToString(b)
+
+	header("Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		buf.WriteString("--" + boundary + "\r\n")
+		header("Content-Type", part.contentType)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, part.body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, body string) error {
+	w := quotedprintable.NewWriter(buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return err
+	}
+	return w.Close()
+}
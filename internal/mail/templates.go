@@ -0,0 +1,195 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Template names
+const (
+	TemplateInvitation          = "invitation"
+	TemplatePasswordReset       = "password_reset"
+	TemplateEmailVerification   = "email_verification"
+	TemplateSignatureRequest    = "signature_request"
+	TemplateSignatureReminder   = "signature_reminder"
+	TemplateSignatureCompleted  = "signature_completed"
+	TemplateSignatureExpired    = "signature_expired"
+	TemplateBreakGlassStarted   = "break_glass_started"
+	TemplateBreakGlassEnded     = "break_glass_ended"
+	TemplateSignatureLowBalance = "signature_low_balance"
+	TemplateUIDBatchCompleted   = "uid_batch_completed"
+	TemplatePartnerUIDInvalid   = "partner_uid_invalid"
+	TemplateFirmenbuchChange    = "firmenbuch_change"
+	TemplateFoerderungStatus    = "foerderung_status"
+	TemplateELDAMeldungRejected = "elda_meldung_rejected"
+	TemplateKommunalsteuerFrist = "kommunalsteuer_frist"
+	TemplateKammerumlageFrist   = "kammerumlage_frist"
+	TemplateWorkflowEscalation  = "workflow_escalation"
+)
+
+// Rendered is a rendered template
+type Rendered struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Renderer renders the embedded mail templates. Each template defines a
+// "subject" and a "text" block and may use the blocks of partials.tmpl; the
+// HTML part is the text laid out in the shared HTML layout, with URLs as links.
+type Renderer struct {
+	appName   string
+	templates map[string]*texttemplate.Template
+	layout    *htmltemplate.Template
+}
+
+// NewRenderer parses the embedded templates
+func NewRenderer(appName string) (*Renderer, error) {
+	funcs := texttemplate.FuncMap{"app": func() string { return appName }}
+
+	entries, err := templateFS.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Renderer{appName: appName, templates: make(map[string]*texttemplate.Template)}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".tmpl")
+		if !ok || name == "partials" {
+			continue
+		}
+		tmpl, err := texttemplate.New(name).Funcs(funcs).ParseFS(templateFS, "templates/partials.tmpl", "templates/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse mail template %s: %w", name, err)
+		}
+		r.templates[name] = tmpl
+	}
+
+	r.layout, err = htmltemplate.ParseFS(templateFS, "templates/layout.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mail layout: %w", err)
+	}
+	return r, nil
+}
+
+// Brand is a tenant's look of its mails. The name signs the mails and
+// heads the layout, replaced by the logo if there is one.
+type Brand struct {
+	Name       string
+	SenderName string // display name of the sender, if set
+	LogoURL    string
+	Color      string // #RRGGBB of the header rule and the links
+	Footer     string
+}
+
+// defaultColor is the header and link color of unbranded mails
+const defaultColor = "#c8102e"
+
+// Render renders a template with its data
+func (r *Renderer) Render(name string, data any) (*Rendered, error) {
+	return r.RenderBrand(name, data, nil)
+}
+
+// RenderBrand renders a template in a tenant's brand. A nil brand renders
+// the platform look.
+func (r *Renderer) RenderBrand(name string, data any, brand *Brand) (*Rendered, error) {
+	return r.render(name, data, brand, "")
+}
+
+// unsubscribeText introduces the unsubscribe link of notifications
+const unsubscribeText = "Diese Benachrichtigungen abbestellen:"
+
+// render renders a template in a brand, with the unsubscribe link of a
+// notification below the text if there is one
+func (r *Renderer) render(name string, data any, brand *Brand, unsubscribeURL string) (*Rendered, error) {
+	tmpl, ok := r.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+	}
+	if brand != nil && brand.Name != "" {
+		clone, err := tmpl.Clone()
+		if err != nil {
+			return nil, err
+		}
+		tmpl = clone.Funcs(texttemplate.FuncMap{"app": func() string { return brand.Name }})
+	}
+
+	var subject, text bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("failed to render subject of %s: %w", name, err)
+	}
+	if err := tmpl.ExecuteTemplate(&text, "text", data); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", name, err)
+	}
+
+	rendered := &Rendered{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}
+
+	html, err := r.renderHTML(rendered.Subject, rendered.Text, brand, unsubscribeURL)
+	if err != nil {
+		return nil, err
+	}
+	rendered.HTML = html
+	if unsubscribeURL != "" {
+		rendered.Text += "\n--\n" + unsubscribeText + "\n" + unsubscribeURL + "\n"
+	}
+	return rendered, nil
+}
+
+type htmlLine struct {
+	Text string
+	Link bool
+}
+
+// renderHTML lays out the text: blank lines separate paragraphs and lines
+// consisting of a URL become links. The unsubscribe link goes into the
+// footer.
+func (r *Renderer) renderHTML(subject, text string, brand *Brand, unsubscribeURL string) (string, error) {
+	var paragraphs [][]htmlLine
+	for _, block := range strings.Split(text, "\n\n") {
+		var lines []htmlLine
+		for _, line := range strings.Split(strings.TrimSpace(block), "\n") {
+			line = strings.TrimSpace(line)
+			link := strings.HasPrefix(line, "https://") || strings.HasPrefix(line, "http://")
+			lines = append(lines, htmlLine{Text: line, Link: link && !strings.ContainsAny(line, " \t")})
+		}
+		if len(lines) > 0 && lines[0].Text != "" {
+			paragraphs = append(paragraphs, lines)
+		}
+	}
+
+	layout := map[string]any{
+		"App":             r.appName,
+		"Color":           defaultColor,
+		"Subject":         subject,
+		"Paragraphs":      paragraphs,
+		"Unsubscribe":     unsubscribeURL,
+		"UnsubscribeText": unsubscribeText,
+	}
+	if brand != nil {
+		if brand.Name != "" {
+			layout["App"] = brand.Name
+		}
+		if brand.Color != "" {
+			layout["Color"] = brand.Color
+		}
+		layout["Logo"] = brand.LogoURL
+		layout["Footer"] = brand.Footer
+	}
+
+	var buf bytes.Buffer
+	err := r.layout.Execute(&buf, layout)
+	if err != nil {
+		return "", fmt.Errorf("failed to render mail layout: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,141 @@
+package mail
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Errors
+var (
+	ErrSuppressed       = errors.New("recipient is on the suppression list")
+	ErrInvalidAddress   = errors.New("invalid email address")
+	ErrNoRecipient      = errors.New("message has no recipient")
+	ErrIdentityNotFound = errors.New("sender identity not found")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrUnknownTemplate  = errors.New("unknown mail template")
+	ErrOptedOut         = errors.New("recipient opted out of this notification")
+)
+
+// Message is an outgoing email
+type Message struct {
+	// TenantID selects the tenant's sender identity and tenant-scoped
+	// suppressions; nil sends from the platform address
+	TenantID *uuid.UUID
+	To       string
+	ToName   string
+	Subject  string
+	Text     string
+	HTML     string // optional alternative part
+	// Category tags the message at the provider, e.g. "password_reset"
+	Category string
+	// UnsubscribeURL is the one-click unsubscribe link of notifications,
+	// sent as List-Unsubscribe header
+	UnsubscribeURL string
+
+	// Set by the service from the sender identity
+	From    string
+	ReplyTo string
+}
+
+// ListUnsubscribePost is the List-Unsubscribe-Post header of messages with
+// an unsubscribe link, announcing one-click unsubscribe (RFC 8058)
+const ListUnsubscribePost = "List-Unsubscribe=One-Click"
+
+// Suppression reasons
+const (
+	ReasonHardBounce  = "hard_bounce"
+	ReasonSoftBounce  = "soft_bounce"
+	ReasonComplaint   = "complaint"
+	ReasonUnsubscribe = "unsubscribe"
+	ReasonManual      = "manual"
+)
+
+// Suppression is an address no sender may mail
+type Suppression struct {
+	ID        uuid.UUID  `json:"id"`
+	TenantID  *uuid.UUID `json:"tenant_id,omitempty"`
+	Email     string     `json:"email"`
+	Reason    string     `json:"reason"`
+	Source    string     `json:"source"`
+	Details   string     `json:"details,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Soft bounces suppress an address for SoftBounceSuppression once
+// SoftBounceThreshold of them happened within SoftBounceWindow
+const (
+	SoftBounceThreshold   = 3
+	SoftBounceWindow      = 72 * time.Hour
+	SoftBounceSuppression = 24 * time.Hour
+)
+
+// Event types recorded from provider webhooks
+const (
+	EventHardBounce  = "hard_bounce"
+	EventSoftBounce  = "soft_bounce"
+	EventComplaint   = "complaint"
+	EventUnsubscribe = "unsubscribe"
+)
+
+// Event is a bounce, complaint or unsubscribe reported by a provider
+type Event struct {
+	Provider  string
+	Type      string
+	Email     string
+	MessageID string
+	Details   string
+}
+
+// SenderIdentity is a tenant's from address and the state of its domain
+type SenderIdentity struct {
+	TenantID      uuid.UUID  `json:"tenant_id"`
+	FromAddress   string     `json:"from_address"`
+	FromName      string     `json:"from_name,omitempty"`
+	ReplyTo       string     `json:"reply_to,omitempty"`
+	Domain        string     `json:"domain"`
+	SPFVerified   bool       `json:"spf_verified"`
+	DKIMVerified  bool       `json:"dkim_verified"`
+	DMARCPresent  bool       `json:"dmarc_present"`
+	VerifiedAt    *time.Time `json:"verified_at,omitempty"`
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Verified reports whether mail may be sent from the identity's address
+func (i *SenderIdentity) Verified() bool {
+	return i.SPFVerified && i.DKIMVerified
+}
+
+// SenderIdentityInput sets a tenant's sender identity
+type SenderIdentityInput struct {
+	FromAddress string `json:"from_address"`
+	FromName    string `json:"from_name"`
+	ReplyTo     string `json:"reply_to"`
+}
+
+// SuppressionInput adds a manual suppression
+type SuppressionInput struct {
+	Email   string `json:"email"`
+	Details string `json:"details"`
+}
+
+// NormalizeAddress validates a bare address and lowercases it
+func NormalizeAddress(address string) (string, error) {
+	parsed, err := mail.ParseAddress(strings.TrimSpace(address))
+	if err != nil || parsed.Name != "" {
+		return "", ErrInvalidAddress
+	}
+	return strings.ToLower(parsed.Address), nil
+}
+
+// addressDomain returns the domain of a normalized address
+func addressDomain(address string) string {
+	_, domain, _ := strings.Cut(address, "@")
+	return domain
+}
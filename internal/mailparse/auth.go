@@ -0,0 +1,172 @@
+package mailparse
+
+import (
+	"net/textproto"
+	"strings"
+)
+
+// Authentication verdicts
+const (
+	VerdictPass    = "pass"    // DMARC or aligned DKIM passed
+	VerdictFail    = "fail"    // DMARC failed, or SPF or DKIM failed without any pass
+	VerdictUnknown = "unknown" // no usable authentication results
+)
+
+// Authenticity holds the sender authentication results the receiving mail
+// server recorded in the message. They are hints, not proof: only the
+// topmost Authentication-Results header is used, which the recipient's own
+// server adds, but a forwarded file could have been edited before upload.
+type Authenticity struct {
+	SPF   string `json:"spf,omitempty"`
+	DKIM  string `json:"dkim,omitempty"`
+	DMARC string `json:"dmarc,omitempty"`
+
+	// AuthServID is the server that recorded the results
+	AuthServID string `json:"authserv_id,omitempty"`
+	// DKIMDomains are the signing domains of passing DKIM signatures, or of
+	// all signatures when there are no results
+	DKIMDomains []string `json:"dkim_domains,omitempty"`
+	FromDomain  string   `json:"from_domain,omitempty"`
+	// Aligned is set when a passing DKIM signature belongs to the From domain
+	Aligned bool   `json:"aligned"`
+	Verdict string `json:"verdict"`
+}
+
+// ParseAuthenticity reads the Authentication-Results, Received-SPF and
+// DKIM-Signature headers of a message from the given sender address
+func ParseAuthenticity(header textproto.MIMEHeader, from string) Authenticity {
+	a := Authenticity{}
+	if _, domain, ok := strings.Cut(from, "@"); ok {
+		a.FromDomain = strings.ToLower(domain)
+	}
+
+	if results := header.Values("Authentication-Results"); len(results) > 0 {
+		a.parseResults(results[0])
+	}
+	if a.SPF == "" {
+		if spf := header.Get("Received-Spf"); spf != "" {
+			// The result is the first word: "Pass (sender SPF authorized) ..."
+			if fields := strings.Fields(stripComments(spf)); len(fields) > 0 {
+				a.SPF = strings.ToLower(fields[0])
+			}
+		}
+	}
+	if len(a.DKIMDomains) == 0 && a.DKIM == "" {
+		for _, sig := range header.Values("Dkim-Signature") {
+			if d := tagValue(sig, "d"); d != "" {
+				a.DKIMDomains = appendUnique(a.DKIMDomains, strings.ToLower(d))
+			}
+		}
+	}
+
+	if a.DKIM == "pass" {
+		for _, d := range a.DKIMDomains {
+			if a.FromDomain != "" && (d == a.FromDomain || strings.HasSuffix(a.FromDomain, "."+d)) {
+				a.Aligned = true
+			}
+		}
+	}
+
+	switch {
+	case a.DMARC == "pass" || a.Aligned:
+		a.Verdict = VerdictPass
+	case a.DMARC == "fail",
+		isFailure(a.SPF) && a.DKIM != "pass",
+		isFailure(a.DKIM) && a.SPF != "pass":
+		a.Verdict = VerdictFail
+	default:
+		a.Verdict = VerdictUnknown
+	}
+	return a
+}
+
+// parseResults reads an RFC 8601 Authentication-Results header:
+// authserv-id; method=result property=value; ...
+func (a *Authenticity) parseResults(value string) {
+	statements := strings.Split(stripComments(value), ";")
+	fields := strings.Fields(statements[0])
+	if len(fields) > 0 {
+		a.AuthServID = fields[0]
+	}
+	for _, statement := range statements[1:] {
+		fields := strings.Fields(statement)
+		if len(fields) == 0 {
+			continue
+		}
+		method, result, ok := strings.Cut(fields[0], "=")
+		if !ok {
+			continue
+		}
+		result = strings.ToLower(result)
+		switch strings.ToLower(method) {
+		case "spf":
+			if a.SPF == "" {
+				a.SPF = result
+			}
+		case "dkim":
+			// One passing signature is enough
+			if a.DKIM == "" || result == "pass" {
+				a.DKIM = result
+			}
+			if result == "pass" {
+				for _, prop := range fields[1:] {
+					name, val, _ := strings.Cut(prop, "=")
+					switch strings.ToLower(name) {
+					case "header.d":
+						a.DKIMDomains = appendUnique(a.DKIMDomains, strings.ToLower(val))
+					case "header.i":
+						if _, domain, ok := strings.Cut(val, "@"); ok {
+							a.DKIMDomains = appendUnique(a.DKIMDomains, strings.ToLower(domain))
+						}
+					}
+				}
+			}
+		case "dmarc":
+			if a.DMARC == "" {
+				a.DMARC = result
+			}
+		}
+	}
+}
+
+// stripComments removes parenthesized comments, which may contain
+// semicolons and equals signs
+func stripComments(value string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range value {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// tagValue returns a tag of a DKIM-Signature header
+func tagValue(value, tag string) string {
+	for _, part := range strings.Split(value, ";") {
+		name, val, ok := strings.Cut(part, "=")
+		if ok && strings.TrimSpace(name) == tag {
+			return strings.Join(strings.Fields(val), "")
+		}
+	}
+	return ""
+}
+
+func isFailure(result string) bool {
+	return result == "fail" || result == "softfail" || result == "permerror"
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
@@ -0,0 +1,310 @@
+package mailparse
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+)
+
+// wordDecoder decodes RFC 2047 encoded words in any charset the WHATWG
+// encoding standard knows, which covers what Austrian mail clients send
+var wordDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+var addressParser = &mail.AddressParser{WordDecoder: wordDecoder}
+
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("unknown charset %q", charset)
+	}
+	return transform.NewReader(input, enc.NewDecoder()), nil
+}
+
+// ParseEML reads an e-mail in RFC 5322 format
+func ParseEML(data []byte) (*Message, error) {
+	if len(data) > MaxMessageSize {
+		return nil, ErrMessageTooLarge
+	}
+	// Skip the envelope line of mbox exports
+	if bytes.HasPrefix(data, []byte("From ")) {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+
+	raw, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	header := textproto.MIMEHeader(raw.Header)
+
+	m := &Message{
+		Format:     FormatEML,
+		Subject:    decodeHeader(header.Get("Subject")),
+		MessageID:  firstMessageID(header.Get("Message-Id")),
+		InReplyTo:  firstMessageID(header.Get("In-Reply-To")),
+		References: messageIDs(header.Get("References")),
+		To:         addressList(header.Get("To")),
+		Cc:         addressList(header.Get("Cc")),
+	}
+	if from, err := addressParser.Parse(header.Get("From")); err == nil {
+		m.From = strings.ToLower(from.Address)
+		m.FromName = from.Name
+	}
+	if date, err := raw.Header.Date(); err == nil {
+		m.Date = date
+	}
+	m.Authenticity = ParseAuthenticity(header, m.From)
+
+	if err := m.readPart(header, raw.Body, 0); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// readPart walks the MIME structure, collecting the body text and the
+// attachments
+func (m *Message) readPart(header textproto.MIMEHeader, body io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil || mediaType == "" {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= MaxPartDepth {
+			m.Warnings = append(m.Warnings, "multipart nesting too deep, inner parts skipped")
+			return nil
+		}
+		boundary := params["boundary"]
+		if boundary == "" {
+			return fmt.Errorf("%w: multipart without boundary", ErrMalformed)
+		}
+		reader := multipart.NewReader(body, boundary)
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				// Truncated messages keep the parts read so far
+				m.Warnings = append(m.Warnings, fmt.Sprintf("multipart body ends early: %v", err))
+				return nil
+			}
+			if err := m.readPart(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := decodeTransfer(header.Get("Content-Transfer-Encoding"), body)
+	if err != nil {
+		m.Warnings = append(m.Warnings, fmt.Sprintf("%s part not decoded: %v", mediaType, err))
+		return nil
+	}
+
+	disposition, dispParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	filename = decodeHeader(filename)
+
+	isText := mediaType == "text/plain" || mediaType == "text/html"
+	if isText && filename == "" && disposition != "attachment" {
+		text := decodeCharset(content, params["charset"])
+		if mediaType == "text/plain" {
+			m.TextBody = joinBody(m.TextBody, text)
+		} else {
+			m.HTMLBody = joinBody(m.HTMLBody, text)
+		}
+		return nil
+	}
+
+	if len(m.Attachments) >= MaxAttachments {
+		m.Warnings = append(m.Warnings, fmt.Sprintf("more than %d attachments, %q skipped", MaxAttachments, filename))
+		return nil
+	}
+	if mediaType == "application/octet-stream" && filename != "" {
+		if t := mime.TypeByExtension(strings.ToLower(path.Ext(filename))); t != "" {
+			mediaType, _, _ = strings.Cut(t, ";")
+		}
+	}
+	if mediaType == MIMEEML && filename == "" {
+		if inner, err := ParseEML(content); err == nil && inner.Subject != "" {
+			filename = inner.Subject + ".eml"
+		}
+	}
+	m.Attachments = append(m.Attachments, Attachment{
+		Filename:    attachmentName(filename, mediaType, len(m.Attachments)+1),
+		ContentType: mediaType,
+		ContentID:   strings.Trim(header.Get("Content-Id"), "<> "),
+		Inline:      disposition == "inline",
+		Data:        content,
+	})
+	return nil
+}
+
+func joinBody(body, text string) string {
+	if body == "" {
+		return text
+	}
+	return body + "\n" + text
+}
+
+// decodeTransfer undoes the content transfer encoding of a part
+func decodeTransfer(encoding string, body io.Reader) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, &base64Cleaner{r: body})
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	content, err := io.ReadAll(io.LimitReader(body, MaxMessageSize+1))
+	if err != nil {
+		return content, err
+	}
+	if len(content) > MaxMessageSize {
+		return nil, ErrMessageTooLarge
+	}
+	return content, nil
+}
+
+// base64Cleaner drops the whitespace and stray characters some mailers put
+// into base64 bodies, which the standard decoder rejects
+type base64Cleaner struct {
+	r io.Reader
+}
+
+func (c *base64Cleaner) Read(p []byte) (int, error) {
+	for {
+		n, err := c.r.Read(p)
+		kept := 0
+		for _, b := range p[:n] {
+			if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || b == '+' || b == '/' || b == '=' {
+				p[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+// decodeCharset converts a text part to UTF-8. Undeclared 8-bit text that is
+// not valid UTF-8 is read as Windows-1252, the usual default of older clients.
+func decodeCharset(content []byte, charset string) string {
+	charset = strings.ToLower(strings.TrimSpace(charset))
+	switch charset {
+	case "", "utf-8", "utf8", "us-ascii":
+		if utf8.Valid(content) {
+			return string(content)
+		}
+		charset = "windows-1252"
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		enc = charmap.Windows1252
+	}
+	decoded, err := enc.NewDecoder().Bytes(content)
+	if err != nil {
+		return strings.ToValidUTF8(string(content), "")
+	}
+	return string(decoded)
+}
+
+// decodeHeader decodes RFC 2047 encoded words, keeping the raw value when
+// the encoding is broken
+func decodeHeader(value string) string {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		decoded = value
+	}
+	return strings.TrimSpace(strings.ToValidUTF8(decoded, ""))
+}
+
+// addressList returns the addresses of an address header
+func addressList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	list, err := addressParser.ParseList(value)
+	if err != nil {
+		return nil
+	}
+	addresses := make([]string, 0, len(list))
+	for _, a := range list {
+		addresses = append(addresses, strings.ToLower(a.Address))
+	}
+	return addresses
+}
+
+// messageIDs returns the message IDs of a References or In-Reply-To header
+// without angle brackets
+func messageIDs(value string) []string {
+	var ids []string
+	for {
+		start := strings.IndexByte(value, '<')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(value[start:], '>')
+		if end < 0 {
+			break
+		}
+		if id := strings.TrimSpace(value[start+1 : start+end]); id != "" {
+			ids = append(ids, id)
+		}
+		value = value[start+end+1:]
+	}
+	if len(ids) == 0 {
+		// Some clients omit the brackets
+		for _, field := range strings.Fields(value) {
+			if strings.Contains(field, "@") {
+				ids = append(ids, field)
+			}
+		}
+	}
+	return ids
+}
+
+func firstMessageID(value string) string {
+	if ids := messageIDs(value); len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
+
+// attachmentName returns a usable file name for an attachment, deriving the
+// extension from the content type when the sender gave no name
+func attachmentName(filename, mediaType string, n int) string {
+	filename = path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	if filename != "" && filename != "." && filename != "/" {
+		return filename
+	}
+	name := "anhang-" + strconv.Itoa(n)
+	switch mediaType {
+	case MIMEEML:
+		return name + ".eml"
+	case "application/pdf":
+		return name + ".pdf"
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return name + exts[0]
+	}
+	return name
+}
@@ -0,0 +1,81 @@
+package mailparse
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// HTMLToText renders an HTML body as plain text: block elements and line
+// breaks become new lines, table cells are separated by tabs, and scripts,
+// styles and the document head are dropped
+func HTMLToText(body string) string {
+	var b strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	skip := 0
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return tidyLines(b.String())
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "script", "style", "head", "title":
+				skip++
+			case "br", "p", "div", "tr", "li", "h1", "h2", "h3", "h4", "h5", "h6", "table", "blockquote", "hr":
+				b.WriteByte('\n')
+			case "td", "th":
+				b.WriteByte('\t')
+			}
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "script", "style", "head", "title":
+				if skip > 0 {
+					skip--
+				}
+			case "p", "div", "tr", "li", "h1", "h2", "h3", "h4", "h5", "h6", "table", "blockquote":
+				b.WriteByte('\n')
+			}
+
+		case html.TextToken:
+			if skip == 0 {
+				// HTML collapses whitespace within text
+				raw := tokenizer.Raw()
+				if len(raw) > 0 && isSpace(raw[0]) {
+					b.WriteByte(' ')
+				}
+				b.WriteString(strings.Join(strings.Fields(string(tokenizer.Text())), " "))
+				if len(raw) > 1 && isSpace(raw[len(raw)-1]) {
+					b.WriteByte(' ')
+				}
+			}
+		}
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// tidyLines trims the lines and drops repeated blank lines
+func tidyLines(text string) string {
+	lines := strings.Split(text, "\n")
+	out := lines[:0]
+	blank := true
+	for _, line := range lines {
+		line = strings.Trim(line, " \t")
+		if line == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
@@ -0,0 +1,196 @@
+// Package mailparse reads e-mails saved as EML (RFC 5322) or Outlook MSG
+// files: headers, body text, attachments and the authentication results the
+// receiving mail server recorded.
+package mailparse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// MIME types of saved e-mails
+const (
+	MIMEEML = "message/rfc822"
+	MIMEMSG = "application/vnd.ms-outlook"
+)
+
+// Formats of saved e-mails
+const (
+	FormatEML = "eml"
+	FormatMSG = "msg"
+)
+
+// Parsing limits
+const (
+	MaxMessageSize = 50 << 20 // bytes of the saved e-mail
+	MaxAttachments = 100
+	MaxPartDepth   = 10 // nesting of multipart bodies
+)
+
+// Parsing errors
+var (
+	ErrUnsupportedFormat = errors.New("not an EML or MSG file")
+	ErrMessageTooLarge   = errors.New("e-mail exceeds the size limit")
+	ErrMalformed         = errors.New("malformed e-mail")
+)
+
+// Message is a parsed e-mail
+type Message struct {
+	Format     string
+	MessageID  string
+	InReplyTo  string
+	References []string
+	From       string // address
+	FromName   string
+	To         []string
+	Cc         []string
+	Subject    string
+	Date       time.Time
+
+	TextBody string
+	HTMLBody string
+
+	Attachments  []Attachment
+	Authenticity Authenticity
+
+	// Warnings lists parts that could not be read, such as embedded items
+	// of an Outlook message
+	Warnings []string
+}
+
+// Attachment is a file attached to an e-mail
+type Attachment struct {
+	Filename    string
+	ContentType string
+	ContentID   string
+	Inline      bool
+	Data        []byte
+}
+
+// IsEmail reports whether the MIME type is a saved e-mail
+func IsEmail(mimeType string) bool {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	switch strings.ToLower(strings.TrimSpace(mimeType)) {
+	case MIMEEML, MIMEMSG, "application/x-msg":
+		return true
+	}
+	return false
+}
+
+// Parse reads an EML or MSG file, detecting the format from the content
+func Parse(data []byte) (*Message, error) {
+	if len(data) > MaxMessageSize {
+		return nil, ErrMessageTooLarge
+	}
+	if bytes.HasPrefix(data, cfbSignature) {
+		return ParseMSG(data)
+	}
+	if !looksLikeEML(data) {
+		return nil, ErrUnsupportedFormat
+	}
+	return ParseEML(data)
+}
+
+// Detect returns the MIME type of a saved e-mail, or "" for other files
+func Detect(data []byte, filename string) string {
+	if bytes.HasPrefix(data, cfbSignature) {
+		// Word and Excel 97 files are compound files too
+		if strings.EqualFold(path.Ext(filename), ".msg") || hasMSGProperties(data) {
+			return MIMEMSG
+		}
+		return ""
+	}
+	if looksLikeEML(data) {
+		return MIMEEML
+	}
+	return ""
+}
+
+// looksLikeEML checks that the file starts with a header block containing
+// at least one of the headers every e-mail has
+func looksLikeEML(data []byte) bool {
+	head := data
+	if len(head) > 64<<10 {
+		head = head[:64<<10]
+	}
+	end := bytes.Index(head, []byte("\n\n"))
+	if crlf := bytes.Index(head, []byte("\r\n\r\n")); crlf >= 0 && (end < 0 || crlf < end) {
+		end = crlf
+	}
+	if end < 0 {
+		return false
+	}
+	found := false
+	for _, line := range strings.Split(string(head[:end]), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		name, _, ok := strings.Cut(line, ":")
+		if !ok || strings.ContainsAny(name, " \t") {
+			// "From " envelope lines of mbox exports are allowed first
+			if strings.HasPrefix(line, "From ") && !found {
+				continue
+			}
+			return false
+		}
+		switch strings.ToLower(name) {
+		case "from", "date", "message-id", "subject", "received":
+			found = true
+		}
+	}
+	return found
+}
+
+// Text renders the message for text analysis: the main headers followed by
+// the body, using the HTML body when there is no plain text
+func (m *Message) Text() string {
+	var b strings.Builder
+	writeHeader := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+	from := m.From
+	if m.FromName != "" {
+		from = fmt.Sprintf("%s <%s>", m.FromName, m.From)
+	}
+	writeHeader("Von", from)
+	writeHeader("An", strings.Join(m.To, ", "))
+	writeHeader("Cc", strings.Join(m.Cc, ", "))
+	if !m.Date.IsZero() {
+		writeHeader("Datum", m.Date.Format("02.01.2006 15:04"))
+	}
+	writeHeader("Betreff", m.Subject)
+	if len(m.Attachments) > 0 {
+		names := make([]string, 0, len(m.Attachments))
+		for _, a := range m.Attachments {
+			names = append(names, a.Filename)
+		}
+		writeHeader("Anhänge", strings.Join(names, ", "))
+	}
+	b.WriteByte('\n')
+
+	body := m.TextBody
+	if strings.TrimSpace(body) == "" && m.HTMLBody != "" {
+		body = HTMLToText(m.HTMLBody)
+	}
+	b.WriteString(body)
+	return b.String()
+}
+
+// ThreadID identifies the conversation of the message: the first message
+// it refers to, or its own ID when it starts a conversation
+func (m *Message) ThreadID() string {
+	if len(m.References) > 0 {
+		return m.References[0]
+	}
+	if m.InReplyTo != "" {
+		return m.InReplyTo
+	}
+	return m.MessageID
+}
@@ -0,0 +1,473 @@
+package mailparse
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/textproto"
+	"path"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Outlook stores a message as a compound file (MS-CFB) with one stream per
+// property (MS-OXMSG). Only the properties needed for ingestion are read.
+
+var cfbSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// MAPI property tags used in MSG files
+const (
+	propSubject          = 0x0037
+	propTransportHeaders = 0x007D
+	propSenderName       = 0x0C1A
+	propSenderEmail      = 0x0C1F
+	propDisplayTo        = 0x0E04
+	propDisplayCc        = 0x0E03
+	propBody             = 0x1000
+	propHTML             = 0x1013
+	propInternetID       = 0x1035
+	propReferences       = 0x1039
+	propInReplyTo        = 0x1042
+	propSenderSMTP       = 0x5D01
+	propAttachData       = 0x3701
+	propAttachFilename   = 0x3704
+	propAttachLongName   = 0x3707
+	propAttachMIME       = 0x370E
+	propAttachContentID  = 0x3712
+)
+
+// MAPI property types of streams
+const (
+	typeString8 = 0x001E
+	typeUnicode = 0x001F
+	typeBinary  = 0x0102
+	typeObject  = 0x000D
+)
+
+const (
+	cfbFreeSector   = 0xFFFFFFFF
+	cfbEndOfChain   = 0xFFFFFFFE
+	cfbNoStream     = 0xFFFFFFFF
+	cfbMaxDirectory = 1 << 16
+)
+
+// cfbEntry is a storage or stream of a compound file
+type cfbEntry struct {
+	name     string
+	typ      byte // 1 storage, 2 stream, 5 root
+	left     uint32
+	right    uint32
+	child    uint32
+	start    uint32
+	size     uint64
+	children map[string]*cfbEntry
+}
+
+// cfbFile reads streams from a compound file held in memory
+type cfbFile struct {
+	data          []byte
+	sectorSize    int
+	miniSize      int
+	miniCutoff    uint64
+	fat           []uint32
+	miniFAT       []uint32
+	entries       []*cfbEntry
+	miniStream    []byte
+	maxChainBytes int
+}
+
+func openCFB(data []byte) (*cfbFile, error) {
+	if len(data) < 512 || !bytes.HasPrefix(data, cfbSignature) {
+		return nil, fmt.Errorf("%w: not a compound file", ErrMalformed)
+	}
+	le := binary.LittleEndian
+	sectorShift := le.Uint16(data[0x1E:])
+	miniShift := le.Uint16(data[0x20:])
+	if (sectorShift != 9 && sectorShift != 12) || miniShift != 6 {
+		return nil, fmt.Errorf("%w: unsupported sector size", ErrMalformed)
+	}
+	f := &cfbFile{
+		data:          data,
+		sectorSize:    1 << sectorShift,
+		miniSize:      1 << miniShift,
+		miniCutoff:    uint64(le.Uint32(data[0x38:])),
+		maxChainBytes: len(data),
+	}
+
+	// The FAT sectors are listed in the header and in the DIFAT chain
+	var fatSectors []uint32
+	for i := 0; i < 109; i++ {
+		if s := le.Uint32(data[0x4C+4*i:]); s != cfbFreeSector {
+			fatSectors = append(fatSectors, s)
+		}
+	}
+	difat := le.Uint32(data[0x44:])
+	perSector := f.sectorSize/4 - 1
+	for seen := 0; difat != cfbEndOfChain && difat != cfbFreeSector; seen++ {
+		sector, err := f.sector(difat)
+		if err != nil || seen > len(data)/f.sectorSize {
+			return nil, fmt.Errorf("%w: broken DIFAT", ErrMalformed)
+		}
+		for i := 0; i < perSector; i++ {
+			if s := le.Uint32(sector[4*i:]); s != cfbFreeSector {
+				fatSectors = append(fatSectors, s)
+			}
+		}
+		difat = le.Uint32(sector[4*perSector:])
+	}
+	for _, s := range fatSectors {
+		sector, err := f.sector(s)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < f.sectorSize; i += 4 {
+			f.fat = append(f.fat, le.Uint32(sector[i:]))
+		}
+	}
+
+	dir, err := f.chain(le.Uint32(data[0x30:]), f.fat, f.sectorSize, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i+128 <= len(dir) && len(f.entries) < cfbMaxDirectory; i += 128 {
+		raw := dir[i : i+128]
+		nameLen := int(le.Uint16(raw[64:]))
+		if nameLen > 64 {
+			nameLen = 64
+		}
+		f.entries = append(f.entries, &cfbEntry{
+			name:  decodeUTF16(raw[:nameLen]),
+			typ:   raw[66],
+			left:  le.Uint32(raw[68:]),
+			right: le.Uint32(raw[72:]),
+			child: le.Uint32(raw[76:]),
+			start: le.Uint32(raw[116:]),
+			size:  le.Uint64(raw[120:]),
+		})
+	}
+	if len(f.entries) == 0 || f.entries[0].typ != 5 {
+		return nil, fmt.Errorf("%w: compound file without root entry", ErrMalformed)
+	}
+	if sectorShift == 9 {
+		// Version 3 files only use the low 32 bits of the size
+		for _, e := range f.entries {
+			e.size &= 0xFFFFFFFF
+		}
+	}
+
+	if miniFAT, err := f.chain(le.Uint32(data[0x3C:]), f.fat, f.sectorSize, nil); err == nil {
+		for i := 0; i+4 <= len(miniFAT); i += 4 {
+			f.miniFAT = append(f.miniFAT, le.Uint32(miniFAT[i:]))
+		}
+	}
+	root := f.entries[0]
+	if root.start != cfbEndOfChain {
+		if f.miniStream, err = f.chain(root.start, f.fat, f.sectorSize, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	visited := make(map[uint32]bool)
+	f.link(root, visited)
+	return f, nil
+}
+
+// sector returns the bytes of a regular sector
+func (f *cfbFile) sector(id uint32) ([]byte, error) {
+	offset := (int(id) + 1) * f.sectorSize
+	if id >= cfbEndOfChain-1 || offset < 0 || offset+f.sectorSize > len(f.data) {
+		return nil, fmt.Errorf("%w: sector %d out of range", ErrMalformed, id)
+	}
+	return f.data[offset : offset+f.sectorSize], nil
+}
+
+// chain concatenates a sector chain from the file or, with a mini stream,
+// from the mini stream
+func (f *cfbFile) chain(start uint32, table []uint32, size int, mini []byte) ([]byte, error) {
+	var out []byte
+	for id := start; id != cfbEndOfChain && id != cfbFreeSector; {
+		if len(out) > f.maxChainBytes {
+			return nil, fmt.Errorf("%w: sector chain loops", ErrMalformed)
+		}
+		if mini != nil {
+			offset := int(id) * size
+			if offset+size > len(mini) {
+				return nil, fmt.Errorf("%w: mini sector %d out of range", ErrMalformed, id)
+			}
+			out = append(out, mini[offset:offset+size]...)
+		} else {
+			sector, err := f.sector(id)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, sector...)
+		}
+		if int(id) >= len(table) {
+			return nil, fmt.Errorf("%w: sector %d outside the allocation table", ErrMalformed, id)
+		}
+		id = table[id]
+	}
+	return out, nil
+}
+
+// stream returns the content of a stream entry
+func (f *cfbFile) stream(e *cfbEntry) ([]byte, error) {
+	var data []byte
+	var err error
+	if e.size < f.miniCutoff {
+		data, err = f.chain(e.start, f.miniFAT, f.miniSize, f.miniStream)
+	} else {
+		data, err = f.chain(e.start, f.fat, f.sectorSize, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(data)) < e.size {
+		return nil, fmt.Errorf("%w: stream %s is truncated", ErrMalformed, e.name)
+	}
+	return data[:e.size], nil
+}
+
+// link resolves the children of a storage, which are kept in a tree of
+// siblings below the storage's child entry
+func (f *cfbFile) link(e *cfbEntry, visited map[uint32]bool) {
+	e.children = make(map[string]*cfbEntry)
+	f.addSiblings(e, e.child, visited)
+	for _, c := range e.children {
+		if c.typ == 1 {
+			f.link(c, visited)
+		}
+	}
+}
+
+func (f *cfbFile) addSiblings(parent *cfbEntry, id uint32, visited map[uint32]bool) {
+	if id == cfbNoStream || int(id) >= len(f.entries) || visited[id] {
+		return
+	}
+	visited[id] = true
+	e := f.entries[id]
+	parent.children[strings.ToLower(e.name)] = e
+	f.addSiblings(parent, e.left, visited)
+	f.addSiblings(parent, e.right, visited)
+}
+
+// hasMSGProperties tells Outlook messages apart from other compound files
+func hasMSGProperties(data []byte) bool {
+	f, err := openCFB(data)
+	if err != nil {
+		return false
+	}
+	_, ok := f.entries[0].children["__properties_version1.0"]
+	return ok && len(propertyStreams(f.entries[0])) > 0
+}
+
+// propertyStreams returns the property streams of a storage by tag
+func propertyStreams(storage *cfbEntry) map[uint32]*cfbEntry {
+	props := make(map[uint32]*cfbEntry)
+	for name, e := range storage.children {
+		var tag uint32
+		if e.typ == 0 || !strings.HasPrefix(name, "__substg1.0_") {
+			continue
+		}
+		if _, err := fmt.Sscanf(name[len("__substg1.0_"):], "%08x", &tag); err == nil {
+			props[tag] = e
+		}
+	}
+	return props
+}
+
+// msgProps reads the properties of a message or attachment storage
+type msgProps struct {
+	file  *cfbFile
+	props map[uint32]*cfbEntry
+}
+
+func (p msgProps) binary(id uint16) []byte {
+	e, ok := p.props[uint32(id)<<16|typeBinary]
+	if !ok {
+		return nil
+	}
+	data, err := p.file.stream(e)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func (p msgProps) string(id uint16) string {
+	if e, ok := p.props[uint32(id)<<16|typeUnicode]; ok {
+		if data, err := p.file.stream(e); err == nil {
+			return strings.TrimRight(decodeUTF16(data), "\x00")
+		}
+	}
+	if e, ok := p.props[uint32(id)<<16|typeString8]; ok {
+		if data, err := p.file.stream(e); err == nil {
+			// The code page is a message property; Western European
+			// Outlook installations use Windows-1252
+			decoded, _ := charmap.Windows1252.NewDecoder().Bytes(bytes.TrimRight(data, "\x00"))
+			return string(decoded)
+		}
+	}
+	return ""
+}
+
+func (p msgProps) has(id, typ uint16) bool {
+	_, ok := p.props[uint32(id)<<16|uint32(typ)]
+	return ok
+}
+
+// ParseMSG reads an Outlook message saved as .msg
+func ParseMSG(data []byte) (*Message, error) {
+	if len(data) > MaxMessageSize {
+		return nil, ErrMessageTooLarge
+	}
+	f, err := openCFB(data)
+	if err != nil {
+		return nil, err
+	}
+	root := f.entries[0]
+	props := msgProps{file: f, props: propertyStreams(root)}
+	if len(props.props) == 0 {
+		return nil, ErrUnsupportedFormat
+	}
+
+	m := &Message{
+		Format:   FormatMSG,
+		Subject:  props.string(propSubject),
+		FromName: props.string(propSenderName),
+		TextBody: props.string(propBody),
+	}
+	if html := props.binary(propHTML); html != nil {
+		m.HTMLBody = decodeCharset(html, "")
+	} else {
+		m.HTMLBody = props.string(propHTML)
+	}
+
+	// Messages received over SMTP keep the original headers, which give the
+	// addresses, thread references and authentication results
+	header := textproto.MIMEHeader{}
+	if raw := props.string(propTransportHeaders); raw != "" {
+		reader := textproto.NewReader(bufio.NewReader(strings.NewReader(strings.TrimRight(raw, "\r\n") + "\r\n\r\n")))
+		if h, err := reader.ReadMIMEHeader(); err == nil || len(h) > 0 {
+			header = h
+		}
+	}
+
+	m.From = strings.ToLower(props.string(propSenderSMTP))
+	if m.From == "" {
+		if addr := props.string(propSenderEmail); strings.Contains(addr, "@") {
+			m.From = strings.ToLower(addr)
+		}
+	}
+	if from, err := addressParser.Parse(header.Get("From")); err == nil {
+		if m.From == "" {
+			m.From = strings.ToLower(from.Address)
+		}
+		if m.FromName == "" {
+			m.FromName = from.Name
+		}
+	}
+
+	m.MessageID = firstMessageID(props.string(propInternetID))
+	if m.MessageID == "" {
+		m.MessageID = firstMessageID(header.Get("Message-Id"))
+	}
+	m.InReplyTo = firstMessageID(props.string(propInReplyTo))
+	if m.InReplyTo == "" {
+		m.InReplyTo = firstMessageID(header.Get("In-Reply-To"))
+	}
+	m.References = messageIDs(props.string(propReferences))
+	if len(m.References) == 0 {
+		m.References = messageIDs(header.Get("References"))
+	}
+
+	m.To = addressList(header.Get("To"))
+	if len(m.To) == 0 {
+		m.To = displayList(props.string(propDisplayTo))
+	}
+	m.Cc = addressList(header.Get("Cc"))
+	if len(m.Cc) == 0 {
+		m.Cc = displayList(props.string(propDisplayCc))
+	}
+	if date := header.Get("Date"); date != "" {
+		if d, err := mail.ParseDate(date); err == nil {
+			m.Date = d
+		}
+	}
+	m.Authenticity = ParseAuthenticity(header, m.From)
+
+	m.readMSGAttachments(f, root)
+	return m, nil
+}
+
+// readMSGAttachments reads the attachment storages in their stored order
+func (m *Message) readMSGAttachments(f *cfbFile, root *cfbEntry) {
+	for i := 0; ; i++ {
+		storage, ok := root.children[fmt.Sprintf("__attach_version1.0_#%08x", i)]
+		if !ok {
+			return
+		}
+		props := msgProps{file: f, props: propertyStreams(storage)}
+		filename := props.string(propAttachLongName)
+		if filename == "" {
+			filename = props.string(propAttachFilename)
+		}
+		if props.has(propAttachData, typeObject) {
+			m.Warnings = append(m.Warnings, fmt.Sprintf("embedded Outlook item %q not extracted", filename))
+			continue
+		}
+		data := props.binary(propAttachData)
+		if data == nil {
+			continue
+		}
+		if len(m.Attachments) >= MaxAttachments {
+			m.Warnings = append(m.Warnings, fmt.Sprintf("more than %d attachments, %q skipped", MaxAttachments, filename))
+			continue
+		}
+		contentType, _, _ := strings.Cut(strings.ToLower(props.string(propAttachMIME)), ";")
+		if contentType == "" || contentType == "application/octet-stream" {
+			if t := mime.TypeByExtension(strings.ToLower(path.Ext(filename))); t != "" {
+				contentType, _, _ = strings.Cut(t, ";")
+			}
+		}
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		contentID := props.string(propAttachContentID)
+		m.Attachments = append(m.Attachments, Attachment{
+			Filename:    attachmentName(filename, contentType, len(m.Attachments)+1),
+			ContentType: contentType,
+			ContentID:   strings.Trim(contentID, "<> "),
+			Inline:      contentID != "",
+			Data:        data,
+		})
+	}
+}
+
+// displayList splits the display recipients of an Outlook message, which
+// are names or addresses separated by semicolons
+func displayList(value string) []string {
+	var list []string
+	for _, name := range strings.Split(value, ";") {
+		if name = strings.TrimSpace(name); name != "" {
+			list = append(list, strings.Trim(name, "'\""))
+		}
+	}
+	return list
+}
+
+func decodeUTF16(data []byte) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	for len(units) > 0 && units[len(units)-1] == 0 {
+		units = units[:len(units)-1]
+	}
+	return string(utf16.Decode(units))
+}
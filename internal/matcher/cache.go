@@ -6,6 +6,8 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -196,7 +198,9 @@ func (c *CachingLLMClient) generateCacheKey(profile *ProfileInput, fd *foerderun
 	return fmt.Sprintf("llm:%s", hex.EncodeToString(hash[:16]))
 }
 
-// SearchResultCache caches entire search results
+// SearchResultCache caches entire search results. Results are keyed by the
+// hash of the normalized profile and the version of the Förderungen catalog,
+// so editing the profile or any active Förderung makes the next search miss.
 type SearchResultCache struct {
 	cache Cache
 	ttl   time.Duration
@@ -216,13 +220,18 @@ type CachedSearchResult struct {
 	TotalChecked  int                           `json:"total_checked"`
 	LLMTokensUsed int                           `json:"llm_tokens_used"`
 	LLMCostCents  int                           `json:"llm_cost_cents"`
+	LLMFallback   bool                          `json:"llm_fallback"`
 	CachedAt      time.Time                     `json:"cached_at"`
 }
 
-// Get retrieves a cached search result
-func (c *SearchResultCache) Get(ctx context.Context, profileID string) (*CachedSearchResult, bool) {
-	key := fmt.Sprintf("search:%s", profileID)
+// SearchResultKey returns the cache key of the results for a profile hash
+// and catalog version
+func SearchResultKey(profileHash, catalogVersion string) string {
+	return fmt.Sprintf("search:%s:%s", profileHash, catalogVersion)
+}
 
+// Get retrieves a cached search result
+func (c *SearchResultCache) Get(ctx context.Context, key string) (*CachedSearchResult, bool) {
 	data, ok := c.cache.Get(ctx, key)
 	if !ok {
 		return nil, false
@@ -237,8 +246,7 @@ func (c *SearchResultCache) Get(ctx context.Context, profileID string) (*CachedS
 }
 
 // Set stores a search result in cache
-func (c *SearchResultCache) Set(ctx context.Context, profileID string, result *CachedSearchResult) error {
-	key := fmt.Sprintf("search:%s", profileID)
+func (c *SearchResultCache) Set(ctx context.Context, key string, result *CachedSearchResult) error {
 	result.CachedAt = time.Now()
 
 	data, err := json.Marshal(result)
@@ -250,16 +258,68 @@ func (c *SearchResultCache) Set(ctx context.Context, profileID string, result *C
 }
 
 // Invalidate removes a cached search result
-func (c *SearchResultCache) Invalidate(ctx context.Context, profileID string) error {
-	key := fmt.Sprintf("search:%s", profileID)
+func (c *SearchResultCache) Invalidate(ctx context.Context, key string) error {
 	return c.cache.Delete(ctx, key)
 }
 
+// ProfileHash returns a hash of the profile fields the matching depends on.
+// Text is trimmed and lists are sorted and deduplicated first, so profiles
+// that only differ in whitespace or order share their cached results.
+func ProfileHash(profile *ProfileInput) string {
+	normalized := *profile
+	normalized.CompanyName = strings.TrimSpace(profile.CompanyName)
+	normalized.LegalForm = strings.TrimSpace(profile.LegalForm)
+	normalized.State = strings.ToLower(strings.TrimSpace(profile.State))
+	normalized.Industry = strings.TrimSpace(profile.Industry)
+	normalized.ProjectDescription = strings.Join(strings.Fields(profile.ProjectDescription), " ")
+	normalized.OnaceCodes = normalizeList(profile.OnaceCodes, false)
+	normalized.ProjectTopics = normalizeList(profile.ProjectTopics, true)
+
+	jsonData, _ := json.Marshal(normalized)
+	hash := sha256.Sum256(jsonData)
+	return hex.EncodeToString(hash[:16])
+}
+
+func normalizeList(values []string, lower bool) []string {
+	seen := make(map[string]bool, len(values))
+	list := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if lower {
+			v = strings.ToLower(v)
+		}
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		list = append(list, v)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// CatalogVersion returns a hash of the ids, statuses and update times of
+// the Förderungen searched. It changes whenever a Förderung is added,
+// edited, closed or reopened.
+func CatalogVersion(foerderungen []*foerderung.Foerderung) string {
+	entries := make([]string, 0, len(foerderungen))
+	for _, fd := range foerderungen {
+		entries = append(entries, fmt.Sprintf("%s|%s|%d", fd.ID, fd.Status, fd.UpdatedAt.UnixNano()))
+	}
+	sort.Strings(entries)
+
+	hash := sha256.Sum256([]byte(strings.Join(entries, "\n")))
+	return hex.EncodeToString(hash[:16])
+}
+
 // CacheStats provides cache statistics
 type CacheStats struct {
 	Hits   int64 `json:"hits"`
 	Misses int64 `json:"misses"`
 	Size   int64 `json:"size"`
+
+	// HitRate is the share of lookups answered from the cache
+	HitRate float64 `json:"hit_rate"`
 }
 
 // StatsCache wraps a cache with statistics tracking
@@ -298,8 +358,12 @@ func (c *StatsCache) Delete(ctx context.Context, key string) error {
 func (c *StatsCache) Stats() CacheStats {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	return CacheStats{
+	stats := CacheStats{
 		Hits:   c.hits,
 		Misses: c.misses,
 	}
+	if total := c.hits + c.misses; total > 0 {
+		stats.HitRate = float64(c.hits) / float64(total)
+	}
+	return stats
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -68,6 +69,8 @@ type SearchResponse struct {
 	LLMCostCents  int              `json:"llm_cost_cents"`
 	DurationMs    int64            `json:"duration_ms"`
 	LLMFallback   bool             `json:"llm_fallback"`
+	Cached        bool             `json:"cached"`
+	CachedAt      *time.Time       `json:"cached_at,omitempty"`
 }
 
 // MatchResponse represents a match in the search response
@@ -389,7 +392,7 @@ func (h *Handler) ListSearches(w http.ResponseWriter, r *http.Request) {
 			TotalFoerderungen: s.TotalFoerderungen,
 			TotalMatches:     s.TotalMatches,
 			Status:           s.Status,
-			CreatedAt:        s.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			CreatedAt:        s.CreatedAt.UTC().Format(time.RFC3339),
 		})
 	}
 
@@ -439,6 +442,8 @@ func toSearchResponse(output *SearchOutput) *SearchResponse {
 		LLMCostCents:  output.LLMCostCents,
 		DurationMs:    output.Duration.Milliseconds(),
 		LLMFallback:   output.LLMFallback,
+		Cached:        output.Cached,
+		CachedAt:      output.CachedAt,
 		Matches:       make([]MatchResponse, 0, len(output.Matches)),
 	}
 
@@ -25,6 +25,8 @@ type Service struct {
 	filter         *Filter
 	llmClient      LLMClient
 	config         *config.FoerderungConfig
+	results        *SearchResultCache
+	resultStats    *StatsCache
 }
 
 // NewService creates a new matcher service
@@ -43,6 +45,24 @@ func NewService(
 	}
 }
 
+// SetResultCache caches search results in c, e.g. Redis, for the configured
+// search cache TTL. A TTL of zero disables the cache.
+func (s *Service) SetResultCache(c Cache) {
+	if s.config.SearchCacheTTLHours <= 0 {
+		return
+	}
+	s.resultStats = NewStatsCache(c)
+	s.results = NewSearchResultCache(s.resultStats, s.config)
+}
+
+// CacheStats returns the hits and misses of the search result cache since start
+func (s *Service) CacheStats() CacheStats {
+	if s.resultStats == nil {
+		return CacheStats{}
+	}
+	return s.resultStats.Stats()
+}
+
 // SearchInput contains the input for a new search
 type SearchInput struct {
 	TenantID  uuid.UUID
@@ -61,6 +81,8 @@ type SearchOutput struct {
 	LLMCostCents   int                         `json:"llm_cost_cents"`
 	Duration       time.Duration               `json:"duration"`
 	LLMFallback    bool                        `json:"llm_fallback"` // True if LLM was skipped
+	Cached         bool                        `json:"cached"`       // True if the matches came from the result cache
+	CachedAt       *time.Time                  `json:"cached_at,omitempty"`
 }
 
 // RunSearch executes a complete search (rule filtering + LLM analysis)
@@ -91,6 +113,15 @@ func (s *Service) RunSearch(ctx context.Context, input *SearchInput) (*SearchOut
 
 	search.TotalFoerderungen = len(foerderungen)
 
+	// Profile and catalog unchanged since an earlier search: reuse its matches
+	var cacheKey string
+	if s.results != nil {
+		cacheKey = SearchResultKey(ProfileHash(input.Profile), CatalogVersion(foerderungen))
+		if cached, ok := s.results.Get(ctx, cacheKey); ok {
+			return s.completeFromCache(ctx, search, cached, startTime)
+		}
+	}
+
 	// Phase 1: Rule-based filtering
 	if err := s.searchRepo.UpdateStatus(ctx, search.ID, foerderung.SearchStatusRuleFiltering, 10); err != nil {
 		return nil, err
@@ -101,6 +132,7 @@ func (s *Service) RunSearch(ctx context.Context, input *SearchInput) (*SearchOut
 	// Phase 2: LLM analysis (if available)
 	var matches []foerderung.FoerderungsMatch
 	llmFallback := false
+	llmFailed := false
 	llmTokensUsed := 0
 	llmCostCents := 0
 
@@ -114,6 +146,7 @@ func (s *Service) RunSearch(ctx context.Context, input *SearchInput) (*SearchOut
 			// LLM failed - fall back to rule-only
 			if s.config.LLMFallbackEnabled {
 				llmFallback = true
+				llmFailed = true
 				matches = s.convertToRuleOnlyMatches(candidates)
 			} else {
 				s.updateSearchError(ctx, search, err)
@@ -149,6 +182,18 @@ func (s *Service) RunSearch(ctx context.Context, input *SearchInput) (*SearchOut
 		return nil, fmt.Errorf("failed to update search: %w", err)
 	}
 
+	// Rule-only results after an LLM failure are not cached, so the next
+	// search retries the analysis
+	if cacheKey != "" && !llmFailed {
+		s.results.Set(ctx, cacheKey, &CachedSearchResult{
+			Matches:       matches,
+			TotalChecked:  len(foerderungen),
+			LLMTokensUsed: llmTokensUsed,
+			LLMCostCents:  llmCostCents,
+			LLMFallback:   llmFallback,
+		})
+	}
+
 	return &SearchOutput{
 		SearchID:      search.ID,
 		TotalChecked:  len(foerderungen),
@@ -161,6 +206,34 @@ func (s *Service) RunSearch(ctx context.Context, input *SearchInput) (*SearchOut
 	}, nil
 }
 
+// completeFromCache completes the search record with cached matches. No LLM
+// tokens are used, so the search costs nothing.
+func (s *Service) completeFromCache(ctx context.Context, search *foerderung.FoerderungsSuche, cached *CachedSearchResult, startTime time.Time) (*SearchOutput, error) {
+	completedAt := time.Now()
+	search.Status = foerderung.SearchStatusCompleted
+	search.TotalMatches = len(cached.Matches)
+	search.CompletedAt = &completedAt
+
+	matchesJSON, _ := json.Marshal(cached.Matches)
+	search.Matches = matchesJSON
+
+	if err := s.searchRepo.Update(ctx, search); err != nil {
+		return nil, fmt.Errorf("failed to update search: %w", err)
+	}
+
+	cachedAt := cached.CachedAt
+	return &SearchOutput{
+		SearchID:     search.ID,
+		TotalChecked: cached.TotalChecked,
+		TotalMatches: len(cached.Matches),
+		Matches:      cached.Matches,
+		Duration:     time.Since(startTime),
+		LLMFallback:  cached.LLMFallback,
+		Cached:       true,
+		CachedAt:     &cachedAt,
+	}, nil
+}
+
 // runLLMAnalysis runs LLM analysis on candidates in parallel
 func (s *Service) runLLMAnalysis(
 	ctx context.Context,
@@ -2,6 +2,8 @@ package mbgm
 
 import (
 	"context"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -9,6 +11,8 @@ import (
 	"github.com/google/uuid"
 
 	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/rawpayload"
+	"austrian-business-infrastructure/internal/xmlschema"
 )
 
 // Service handles mBGM business logic
@@ -17,13 +21,15 @@ type Service struct {
 	validator   *Validator
 	builder     *Builder
 	eldaService *elda.MBGMService
+	payloads    *rawpayload.Service
 	logger      *slog.Logger
 }
 
 // ServiceConfig contains configuration for the mBGM service
 type ServiceConfig struct {
 	Repository  *Repository
-	ELDAClient  *elda.Client
+	ELDAClient  elda.Caller
+	RawPayloads *rawpayload.Service // Optional: retain raw ELDA exchanges
 	Logger      *slog.Logger
 }
 
@@ -39,6 +45,7 @@ func NewService(cfg ServiceConfig) *Service {
 		validator:   NewValidator(cfg.Repository),
 		builder:     NewBuilder(),
 		eldaService: elda.NewMBGMService(cfg.ELDAClient),
+		payloads:    cfg.RawPayloads,
 		logger:      logger,
 	}
 }
@@ -221,9 +228,21 @@ func (s *Service) Submit(ctx context.Context, id uuid.UUID, dienstgeberNr string
 
 	// Build XML document
 	doc := s.buildDocument(mbgm, dienstgeberNr)
+	if err := s.checkSchema(mbgm, doc); err != nil {
+		return nil, err
+	}
 
 	// Submit to ELDA
-	eldaResult, err := s.eldaService.SubmitMBGM(ctx, doc)
+	rec := rawpayload.NewRecorder()
+	eldaResult, err := s.eldaService.SubmitMBGM(rawpayload.WithRecorder(ctx, rec), doc)
+	s.payloads.Save(ctx, rawpayload.Reference{
+		ELDAAccountID:     &mbgm.ELDAAccountID,
+		Module:            rawpayload.ModuleELDA,
+		ReferenceType:     "mbgm",
+		ReferenceID:       &mbgm.ID,
+		ProviderReference: eldaResult.Protokollnummer,
+		Outcome:           rawpayload.OutcomeOf(err == nil, eldaResult.ErrorCode != ""),
+	}, rec)
 
 	// Update mBGM with result
 	now := time.Now()
@@ -314,6 +333,28 @@ func (s *Service) buildDocument(mbgm *elda.MBGM, dienstgeberNr string) *elda.MBG
 	return doc
 }
 
+// checkSchema validates the mBGM XML against the schema of the reporting month
+func (s *Service) checkSchema(mbgm *elda.MBGM, doc *elda.MBGMDocument) error {
+	xmlData, err := xml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mBGM XML: %w", err)
+	}
+	err = xmlschema.Check(xmlschema.FormatMBGM, time.Date(mbgm.Year, time.Month(mbgm.Month), 1, 0, 0, 0, 0, time.UTC), xmlData)
+	var schemaErr *xmlschema.ValidationError
+	if !errors.As(err, &schemaErr) {
+		return err
+	}
+
+	result := &ValidationResult{Valid: false}
+	for _, v := range schemaErr.Violations {
+		result.Errors = append(result.Errors, FieldValidationError{
+			Field:   v.Path,
+			Message: fmt.Sprintf("%s (ELDA-Schema %s)", v.Message, schemaErr.Version),
+		})
+	}
+	return &ValidationError{Result: result}
+}
+
 // SubmitResult contains the result of submitting an mBGM
 type SubmitResult struct {
 	Success         bool      `json:"success"`
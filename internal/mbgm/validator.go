@@ -52,7 +52,7 @@ func (v *Validator) ValidateMBGM(mbgm *elda.MBGM) *ValidationResult {
 
 	svNummern := make(map[string]int) // Track duplicate SV-Nummern
 	for i, pos := range mbgm.Positionen {
-		v.validatePosition(pos, i, result)
+		v.validatePosition(pos, mbgm.Year, i, result)
 
 		// Check for duplicates
 		if existing, ok := svNummern[pos.SVNummer]; ok {
@@ -77,7 +77,7 @@ func (v *Validator) ValidateMBGM(mbgm *elda.MBGM) *ValidationResult {
 // ValidatePosition validates a single mBGM position
 func (v *Validator) ValidatePosition(pos *elda.MBGMPosition, year, month int) *ValidationResult {
 	result := &ValidationResult{Valid: true}
-	v.validatePosition(pos, 0, result)
+	v.validatePosition(pos, year, 0, result)
 	result.Valid = len(result.Errors) == 0
 	return result
 }
@@ -102,7 +102,7 @@ func (v *Validator) validateHeader(mbgm *elda.MBGM, result *ValidationResult) {
 }
 
 // validatePosition validates a single position
-func (v *Validator) validatePosition(pos *elda.MBGMPosition, index int, result *ValidationResult) {
+func (v *Validator) validatePosition(pos *elda.MBGMPosition, year, index int, result *ValidationResult) {
 	posID := pos.ID.String()
 	if pos.ID == uuid.Nil {
 		posID = ""
@@ -140,7 +140,7 @@ func (v *Validator) validatePosition(pos *elda.MBGMPosition, index int, result *
 		result.addError("beitragsgrundlage", "Beitragsgrundlage darf nicht negativ sein", posID, index)
 	} else {
 		// Check against limits
-		v.validateBeitragsgrundlage(pos, index, result)
+		v.validateBeitragsgrundlage(pos, year, index, result)
 	}
 
 	// Validate Sonderzahlung
@@ -163,10 +163,10 @@ func (v *Validator) validatePosition(pos *elda.MBGMPosition, index int, result *
 	}
 }
 
-// validateBeitragsgrundlage checks the amount against SV limits
-func (v *Validator) validateBeitragsgrundlage(pos *elda.MBGMPosition, index int, result *ValidationResult) {
+// validateBeitragsgrundlage checks the amount against the SV limits of the reporting year
+func (v *Validator) validateBeitragsgrundlage(pos *elda.MBGMPosition, year, index int, result *ValidationResult) {
 	// Check Geringfügigkeitsgrenze
-	if elda.IsGeringfuegig(pos.Beitragsgrundlage, time.Now().Year()) {
+	if elda.IsGeringfuegig(pos.Beitragsgrundlage, year) {
 		// This is a warning, not an error - geringfügig is valid
 		result.Warnings = append(result.Warnings,
 			fmt.Sprintf("Position %d (%s): Beitragsgrundlage %.2f€ liegt unter der Geringfügigkeitsgrenze",
@@ -174,10 +174,10 @@ func (v *Validator) validateBeitragsgrundlage(pos *elda.MBGMPosition, index int,
 	}
 
 	// Check Höchstbeitragsgrundlage
-	if elda.ExceedsHoechstbeitrag(pos.Beitragsgrundlage, time.Now().Year()) {
+	if elda.ExceedsHoechstbeitrag(pos.Beitragsgrundlage, year) {
 		result.Warnings = append(result.Warnings,
 			fmt.Sprintf("Position %d (%s): Beitragsgrundlage %.2f€ übersteigt die Höchstbeitragsgrundlage - wird auf %.2f€ gekappt",
-				index+1, pos.Familienname, pos.Beitragsgrundlage, elda.HoechstbeitragsGrundlage2025))
+				index+1, pos.Familienname, pos.Beitragsgrundlage, elda.HoechstbeitragsGrundlage(year)))
 	}
 }
 
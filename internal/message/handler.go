@@ -173,6 +173,7 @@ func (h *Handler) GetThread(w http.ResponseWriter, r *http.Request) {
 
 	// Verify tenant access
 	if thread.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "message_thread", thread.ID.String())
 		http.Error(w, "thread not found", http.StatusNotFound)
 		return
 	}
@@ -209,6 +210,7 @@ func (h *Handler) ListMessages(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if thread.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "message_thread", thread.ID.String())
 		http.Error(w, "thread not found", http.StatusNotFound)
 		return
 	}
@@ -269,6 +271,7 @@ func (h *Handler) SendMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if thread.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "message_thread", thread.ID.String())
 		http.Error(w, "thread not found", http.StatusNotFound)
 		return
 	}
@@ -330,6 +333,7 @@ func (h *Handler) MarkAsRead(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if thread.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "message_thread", thread.ID.String())
 		http.Error(w, "thread not found", http.StatusNotFound)
 		return
 	}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -346,16 +347,16 @@ func toMonitorResponse(m *foerderung.ProfilMonitor) *MonitorResponse {
 		NotificationPortal: m.NotificationPortal,
 		DigestMode:         m.DigestMode,
 		MatchesFound:       m.MatchesFound,
-		CreatedAt:          m.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:          m.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		CreatedAt:          m.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:          m.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 
 	if m.LastCheckAt != nil {
-		s := m.LastCheckAt.Format("2006-01-02T15:04:05Z")
+		s := m.LastCheckAt.UTC().Format(time.RFC3339)
 		resp.LastCheckAt = &s
 	}
 	if m.LastNotificationAt != nil {
-		s := m.LastNotificationAt.Format("2006-01-02T15:04:05Z")
+		s := m.LastNotificationAt.UTC().Format(time.RFC3339)
 		resp.LastNotificationAt = &s
 	}
 
@@ -372,15 +373,15 @@ func toNotificationResponse(n *foerderung.MonitorNotification) *NotificationResp
 		EmailSent:    n.EmailSent,
 		PortalNotified: n.PortalNotified,
 		Dismissed:    n.Dismissed,
-		CreatedAt:    n.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		CreatedAt:    n.CreatedAt.UTC().Format(time.RFC3339),
 	}
 
 	if n.EmailSentAt != nil {
-		s := n.EmailSentAt.Format("2006-01-02T15:04:05Z")
+		s := n.EmailSentAt.UTC().Format(time.RFC3339)
 		resp.EmailSentAt = &s
 	}
 	if n.ViewedAt != nil {
-		s := n.ViewedAt.Format("2006-01-02T15:04:05Z")
+		s := n.ViewedAt.UTC().Format(time.RFC3339)
 		resp.ViewedAt = &s
 	}
 
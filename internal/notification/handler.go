@@ -2,6 +2,8 @@ package notification
 
 import (
 	"encoding/json"
+	"errors"
+	"html/template"
 	"net/http"
 
 	"austrian-business-infrastructure/internal/api"
@@ -22,6 +24,17 @@ func NewHandler(service *Service) *Handler {
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/notifications/preferences", h.GetPreferences)
 	mux.HandleFunc("PUT /api/v1/notifications/preferences", h.UpdatePreferences)
+	mux.HandleFunc("GET /api/v1/notifications/preferences/matrix", h.GetMatrix)
+	mux.HandleFunc("PUT /api/v1/notifications/preferences/matrix", h.UpdateMatrix)
+}
+
+// RegisterPublicRoutes registers the unsubscribe link of notification
+// emails, authorized by its signed token. GET shows a confirmation page so
+// that link scanners do not unsubscribe; POST unsubscribes, also as the
+// one-click unsubscribe of mail clients (RFC 8058).
+func (h *Handler) RegisterPublicRoutes(router *api.Router) {
+	router.HandleFunc("GET /api/v1/notifications/unsubscribe", h.UnsubscribePage)
+	router.HandleFunc("POST /api/v1/notifications/unsubscribe", h.Unsubscribe)
 }
 
 // PreferencesResponse represents notification preferences in API responses
@@ -31,6 +44,7 @@ type PreferencesResponse struct {
 	DigestTime    string   `json:"digest_time,omitempty"`
 	DocumentTypes []string `json:"document_types,omitempty"`
 	AccountIDs    []string `json:"account_ids,omitempty"`
+	Language      string   `json:"language"`
 }
 
 // GetPreferences returns notification preferences
@@ -74,6 +88,7 @@ func (h *Handler) GetPreferences(w http.ResponseWriter, r *http.Request) {
 		DigestTime:    prefs.DigestTime,
 		DocumentTypes: prefs.DocumentTypes,
 		AccountIDs:    accountIDs,
+		Language:      prefs.Language,
 	}
 
 	api.JSONResponse(w, http.StatusOK, response)
@@ -86,6 +101,9 @@ type UpdatePreferencesRequest struct {
 	DigestTime    string   `json:"digest_time,omitempty"`
 	DocumentTypes []string `json:"document_types,omitempty"`
 	AccountIDs    []string `json:"account_ids,omitempty"`
+	// Language of notifications, one of SupportedLanguages; defaults to
+	// German
+	Language string `json:"language,omitempty"`
 }
 
 // UpdatePreferences updates notification preferences
@@ -123,6 +141,14 @@ func (h *Handler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	language := DefaultLanguage
+	if req.Language != "" {
+		if language = NormalizeLanguage(req.Language); language == "" {
+			api.JSONError(w, http.StatusBadRequest, "unsupported language", api.ErrCodeValidation)
+			return
+		}
+	}
+
 	// Parse account IDs
 	accountIDs := make([]uuid.UUID, 0, len(req.AccountIDs))
 	for _, idStr := range req.AccountIDs {
@@ -142,6 +168,7 @@ func (h *Handler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
 		DigestTime:    req.DigestTime,
 		DocumentTypes: req.DocumentTypes,
 		AccountIDs:    accountIDs,
+		Language:      language,
 	}
 
 	if err := h.service.UpdatePreferences(ctx, prefs); err != nil {
@@ -151,3 +178,134 @@ func (h *Handler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
 
 	api.JSONResponse(w, http.StatusOK, map[string]string{"status": "updated"})
 }
+
+// MatrixResponse is the preference matrix of a user
+type MatrixResponse struct {
+	Matrix Matrix `json:"matrix"`
+	// Locked lists the event types that cannot be disabled
+	Locked []string `json:"locked"`
+}
+
+// UpdateMatrixRequest changes entries of the preference matrix, e.g.
+// {"matrix": {"analysis": {"email": false}}}
+type UpdateMatrixRequest struct {
+	Matrix Matrix `json:"matrix"`
+}
+
+// GetMatrix returns the preference matrix per event type and channel
+func (h *Handler) GetMatrix(w http.ResponseWriter, r *http.Request) {
+	userID, tenantID, ok := matrixUser(w, r)
+	if !ok {
+		return
+	}
+
+	m, err := h.service.GetMatrix(r.Context(), userID, tenantID)
+	if err != nil {
+		api.JSONError(w, http.StatusInternalServerError, "failed to get preferences", api.ErrCodeInternalError)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, &MatrixResponse{Matrix: m, Locked: []string{EventSecurity}})
+}
+
+// UpdateMatrix changes entries of the preference matrix
+func (h *Handler) UpdateMatrix(w http.ResponseWriter, r *http.Request) {
+	userID, tenantID, ok := matrixUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateMatrixRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid request body", api.ErrCodeBadRequest)
+		return
+	}
+
+	m, err := h.service.UpdateMatrix(r.Context(), userID, tenantID, req.Matrix)
+	if errors.Is(err, ErrExemptEvent) || errors.Is(err, ErrInvalidPreference) {
+		api.JSONError(w, http.StatusBadRequest, err.Error(), api.ErrCodeValidation)
+		return
+	}
+	if err != nil {
+		api.JSONError(w, http.StatusInternalServerError, "failed to update preferences", api.ErrCodeInternalError)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, &MatrixResponse{Matrix: m, Locked: []string{EventSecurity}})
+}
+
+// matrixUser returns the user and tenant of a request
+func matrixUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := uuid.Parse(api.GetUserID(r.Context()))
+	if err != nil {
+		api.JSONError(w, http.StatusUnauthorized, "unauthorized", api.ErrCodeUnauthorized)
+		return uuid.Nil, uuid.Nil, false
+	}
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.JSONError(w, http.StatusUnauthorized, "unauthorized", api.ErrCodeUnauthorized)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, tenantID, true
+}
+
+// eventLabels names the event types on the unsubscribe page
+var eventLabels = map[string]string{
+	EventDeadline:  "Fristen",
+	EventAnalysis:  "Dokumentanalysen",
+	EventSignature: "Signaturen",
+	EventBilling:   "Abrechnung",
+}
+
+var unsubscribeTemplate = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="de">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>E-Mail-Benachrichtigungen</title></head>
+<body>
+{{- if .Invalid}}
+<p>Dieser Abmeldelink ist ungültig.</p>
+{{- else if .Done}}
+<p>Sie erhalten keine E-Mails zu {{.Label}} mehr an {{.Email}}. Sie können das jederzeit in Ihren Benachrichtigungseinstellungen ändern.</p>
+{{- else}}
+<p>Keine E-Mails zu {{.Label}} mehr an {{.Email}} senden?</p>
+<form method="post"><button type="submit">Abmelden</button></form>
+{{- end}}
+</body>
+</html>
+`))
+
+type unsubscribePage struct {
+	Invalid bool
+	Done    bool
+	Label   string
+	Email   string
+}
+
+// UnsubscribePage shows the confirmation of an unsubscribe link
+func (h *Handler) UnsubscribePage(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.service.VerifyUnsubscribe(r.URL.Query().Get("token"))
+	if err != nil {
+		renderUnsubscribe(w, http.StatusBadRequest, &unsubscribePage{Invalid: true})
+		return
+	}
+	renderUnsubscribe(w, http.StatusOK, &unsubscribePage{Label: eventLabels[claims.EventType], Email: claims.Email})
+}
+
+// Unsubscribe disables the emails of an unsubscribe link
+func (h *Handler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.service.Unsubscribe(r.Context(), r.URL.Query().Get("token"))
+	if errors.Is(err, ErrInvalidUnsubscribeToken) {
+		renderUnsubscribe(w, http.StatusBadRequest, &unsubscribePage{Invalid: true})
+		return
+	}
+	if err != nil {
+		api.JSONError(w, http.StatusInternalServerError, "failed to unsubscribe", api.ErrCodeInternalError)
+		return
+	}
+	renderUnsubscribe(w, http.StatusOK, &unsubscribePage{Done: true, Label: eventLabels[claims.EventType], Email: claims.Email})
+}
+
+func renderUnsubscribe(w http.ResponseWriter, status int, page *unsubscribePage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	unsubscribeTemplate.Execute(w, page)
+}
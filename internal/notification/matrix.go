@@ -0,0 +1,316 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/mail"
+)
+
+// Event types of the preference matrix
+const (
+	EventDeadline  = "deadline"
+	EventAnalysis  = "analysis"
+	EventSignature = "signature"
+	EventBilling   = "billing"
+	EventSecurity  = "security"
+)
+
+// EventTypes lists the event types of the preference matrix
+var EventTypes = []string{EventDeadline, EventAnalysis, EventSignature, EventBilling, EventSecurity}
+
+// Channels of the preference matrix
+const (
+	ChannelEmail = "email"
+	ChannelInApp = "in_app"
+)
+
+// Channels lists the channels of the preference matrix
+var Channels = []string{ChannelEmail, ChannelInApp}
+
+// Sources of a matrix entry
+const (
+	SourceSettings    = "settings"
+	SourceUnsubscribe = "unsubscribe"
+)
+
+// Errors of the preference matrix
+var (
+	ErrExemptEvent       = errors.New("security notifications cannot be disabled")
+	ErrInvalidPreference = errors.New("unknown event type or channel")
+)
+
+// Exempt reports whether notifications of an event type are sent regardless
+// of preferences. Security notifications (password resets, email
+// verification, break-glass access) always go out.
+func Exempt(eventType string) bool {
+	return eventType == EventSecurity
+}
+
+// mailEvents maps mail templates to the event type of their preference.
+// Mails without an event type, such as invitations, are not notifications
+// and carry no unsubscribe link.
+var mailEvents = map[string]string{
+	mail.TemplatePasswordReset:       EventSecurity,
+	mail.TemplateEmailVerification:   EventSecurity,
+	mail.TemplateBreakGlassStarted:   EventSecurity,
+	mail.TemplateBreakGlassEnded:     EventSecurity,
+	mail.TemplateSignatureRequest:    EventSignature,
+	mail.TemplateSignatureReminder:   EventSignature,
+	mail.TemplateSignatureCompleted:  EventSignature,
+	mail.TemplateSignatureExpired:    EventSignature,
+	mail.TemplateSignatureLowBalance: EventBilling,
+	TypeAnalysisCompleted:            EventAnalysis,
+}
+
+// MailEvent returns the event type of a mail category, or "" if the mail
+// is not a notification
+func MailEvent(category string) string {
+	return mailEvents[category]
+}
+
+// Matrix holds whether each event type is enabled per channel. Missing
+// entries are enabled.
+type Matrix map[string]map[string]bool
+
+// DefaultMatrix returns the matrix with every notification enabled
+func DefaultMatrix() Matrix {
+	m := make(Matrix, len(EventTypes))
+	for _, event := range EventTypes {
+		m[event] = make(map[string]bool, len(Channels))
+		for _, channel := range Channels {
+			m[event][channel] = true
+		}
+	}
+	return m
+}
+
+// Enabled reports whether an event type is enabled on a channel
+func (m Matrix) Enabled(eventType, channel string) bool {
+	if Exempt(eventType) {
+		return true
+	}
+	enabled, ok := m[eventType][channel]
+	return !ok || enabled
+}
+
+// Validate checks that a matrix only has known event types and channels
+// and does not disable security notifications
+func (m Matrix) Validate() error {
+	for event, channels := range m {
+		if !slices.Contains(EventTypes, event) {
+			return fmt.Errorf("%w: %s", ErrInvalidPreference, event)
+		}
+		for channel, enabled := range channels {
+			if !slices.Contains(Channels, channel) {
+				return fmt.Errorf("%w: %s", ErrInvalidPreference, channel)
+			}
+			if Exempt(event) && !enabled {
+				return ErrExemptEvent
+			}
+		}
+	}
+	return nil
+}
+
+// GetMatrix returns the preference matrix of a user, with the defaults
+// for entries they never changed
+func (r *Repository) GetMatrix(ctx context.Context, userID, tenantID uuid.UUID) (Matrix, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT event_type, channel, enabled
+		FROM notification_channel_preferences
+		WHERE user_id = $1 AND tenant_id = $2
+	`, userID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get preference matrix: %w", err)
+	}
+	defer rows.Close()
+
+	m := DefaultMatrix()
+	for rows.Next() {
+		var event, channel string
+		var enabled bool
+		if err := rows.Scan(&event, &channel, &enabled); err != nil {
+			return nil, fmt.Errorf("scan preference: %w", err)
+		}
+		if _, ok := m[event]; ok && !Exempt(event) {
+			m[event][channel] = enabled
+		}
+	}
+	return m, rows.Err()
+}
+
+// SetMatrix stores the entries of a matrix for a user; entries it does not
+// contain stay as they are
+func (r *Repository) SetMatrix(ctx context.Context, userID, tenantID uuid.UUID, m Matrix) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for event, channels := range m {
+		if Exempt(event) {
+			continue
+		}
+		for channel, enabled := range channels {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO notification_channel_preferences (user_id, tenant_id, event_type, channel, enabled, source)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				ON CONFLICT (user_id, tenant_id, event_type, channel)
+				DO UPDATE SET enabled = EXCLUDED.enabled, source = EXCLUDED.source, updated_at = NOW()
+			`, userID, tenantID, event, channel, enabled, SourceSettings)
+			if err != nil {
+				return fmt.Errorf("set preference: %w", err)
+			}
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// EmailOptOut returns how many users have an email address and whether
+// all of them disabled emails of an event type. A nil tenant checks the
+// address in every tenant, as for platform mails.
+func (r *Repository) EmailOptOut(ctx context.Context, tenantID *uuid.UUID, email, eventType string) (int, bool, error) {
+	var users, optedOut int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE p.enabled = false)
+		FROM users u
+		LEFT JOIN notification_channel_preferences p
+		       ON p.user_id = u.id AND p.tenant_id = u.tenant_id
+		      AND p.event_type = $3 AND p.channel = 'email'
+		WHERE lower(u.email) = lower($1) AND ($2::uuid IS NULL OR u.tenant_id = $2)
+	`, email, tenantID, eventType).Scan(&users, &optedOut)
+	if err != nil {
+		return 0, false, fmt.Errorf("check email preference: %w", err)
+	}
+	return users, users > 0 && optedOut == users, nil
+}
+
+// Unsubscribe disables emails of an event type for the users with an email
+// address, in one tenant or, for a nil tenant, in all of them. It returns
+// the number of users.
+func (r *Repository) Unsubscribe(ctx context.Context, tenantID *uuid.UUID, email, eventType string) (int, error) {
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO notification_channel_preferences (user_id, tenant_id, event_type, channel, enabled, source)
+		SELECT u.id, u.tenant_id, $3, 'email', false, $4
+		FROM users u
+		WHERE lower(u.email) = lower($1) AND ($2::uuid IS NULL OR u.tenant_id = $2)
+		ON CONFLICT (user_id, tenant_id, event_type, channel)
+		DO UPDATE SET enabled = false, source = EXCLUDED.source, updated_at = NOW()
+	`, email, tenantID, eventType, SourceUnsubscribe)
+	if err != nil {
+		return 0, fmt.Errorf("unsubscribe: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// OptOuts returns the users of a tenant who disabled an event type on a
+// channel
+func (r *Repository) OptOuts(ctx context.Context, tenantID uuid.UUID, eventType, channel string) (map[uuid.UUID]bool, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT user_id
+		FROM notification_channel_preferences
+		WHERE tenant_id = $1 AND event_type = $2 AND channel = $3 AND enabled = false
+	`, tenantID, eventType, channel)
+	if err != nil {
+		return nil, fmt.Errorf("list opt-outs: %w", err)
+	}
+	defer rows.Close()
+
+	optOuts := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan opt-out: %w", err)
+		}
+		optOuts[userID] = true
+	}
+	return optOuts, rows.Err()
+}
+
+// SetUnsubscribeSigner adds one-click unsubscribe links to notification
+// emails
+func (s *Service) SetUnsubscribeSigner(signer *UnsubscribeSigner) {
+	s.unsubscribe = signer
+}
+
+// GetMatrix returns the preference matrix of a user
+func (s *Service) GetMatrix(ctx context.Context, userID, tenantID uuid.UUID) (Matrix, error) {
+	return s.repo.GetMatrix(ctx, userID, tenantID)
+}
+
+// UpdateMatrix changes entries of the preference matrix of a user and
+// returns the whole matrix
+func (s *Service) UpdateMatrix(ctx context.Context, userID, tenantID uuid.UUID, m Matrix) (Matrix, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetMatrix(ctx, userID, tenantID, m); err != nil {
+		return nil, err
+	}
+	return s.repo.GetMatrix(ctx, userID, tenantID)
+}
+
+// VerifyUnsubscribe returns the claims of an unsubscribe token
+func (s *Service) VerifyUnsubscribe(token string) (*UnsubscribeClaims, error) {
+	if s.unsubscribe == nil {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	return s.unsubscribe.Verify(token)
+}
+
+// Unsubscribe disables the emails of an unsubscribe token
+func (s *Service) Unsubscribe(ctx context.Context, token string) (*UnsubscribeClaims, error) {
+	claims, err := s.VerifyUnsubscribe(token)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.repo.Unsubscribe(ctx, claims.TenantID, claims.Email, claims.EventType)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("unsubscribed from notification emails", "event_type", claims.EventType, "users", users)
+	return claims, nil
+}
+
+// MailPreference implements mail.Preferences. Mails that are not
+// notifications are allowed, security notifications are required, and
+// the others are sent unless every user with the address disabled them,
+// with an unsubscribe link for addresses of users.
+func (s *Service) MailPreference(ctx context.Context, tenantID *uuid.UUID, email, category string) (*mail.Preference, error) {
+	event := MailEvent(category)
+	if event == "" {
+		return &mail.Preference{Allowed: true}, nil
+	}
+	if Exempt(event) {
+		return &mail.Preference{Allowed: true, Required: true}, nil
+	}
+
+	users, optedOut, err := s.repo.EmailOptOut(ctx, tenantID, email, event)
+	if err != nil {
+		return nil, err
+	}
+	pref := &mail.Preference{Allowed: !optedOut}
+	if pref.Allowed && users > 0 && s.unsubscribe != nil {
+		token, err := s.unsubscribe.Sign(&UnsubscribeClaims{Email: email, TenantID: tenantID, EventType: event})
+		if err != nil {
+			return nil, err
+		}
+		pref.UnsubscribeURL = UnsubscribeLink(s.appURL, token)
+	}
+	return pref, nil
+}
+
+// InAppOptOuts implements websocket.InAppPreferences; security
+// notifications reach everyone
+func (s *Service) InAppOptOuts(ctx context.Context, tenantID uuid.UUID, eventType string) (map[uuid.UUID]bool, error) {
+	if Exempt(eventType) || !slices.Contains(EventTypes, eventType) {
+		return nil, nil
+	}
+	return s.repo.OptOuts(ctx, tenantID, eventType, ChannelInApp)
+}
@@ -0,0 +1,104 @@
+package notification
+
+// messages holds the fixed texts of notifications in one language
+type messages struct {
+	Subject        string // format with the document title
+	Heading        string
+	Summary        string
+	KeyPoints      string
+	Deadlines      string
+	Binding        string
+	Amounts        string
+	AmountsHidden  string
+	ActionRequired string
+	NotTranslated  string
+	OnBehalfOf     string // format with the name of the absent user
+	OpenDocument   string
+	DateFormat     string
+}
+
+var catalog = map[string]messages{
+	"de": {
+		Subject:        "Dokument analysiert: %s",
+		Heading:        "Die Analyse eines Dokuments ist abgeschlossen",
+		Summary:        "Zusammenfassung",
+		KeyPoints:      "Wichtigste Punkte",
+		Deadlines:      "Fristen",
+		Binding:        "verbindlich",
+		Amounts:        "Beträge",
+		AmountsHidden:  "Beträge sind ausgeblendet, da Sie keine Berechtigung für Finanzdaten haben.",
+		ActionRequired: "Handlungsbedarf: Bitte prüfen Sie das Dokument.",
+		NotTranslated:  "Die Zusammenfassung ist nur in der Originalsprache verfügbar.",
+		OnBehalfOf:     "Sie erhalten diese Benachrichtigung als Vertretung von %s.",
+		OpenDocument:   "Dokument öffnen",
+		DateFormat:     "02.01.2006",
+	},
+	"en": {
+		Subject:        "Document analysed: %s",
+		Heading:        "The analysis of a document has finished",
+		Summary:        "Summary",
+		KeyPoints:      "Key points",
+		Deadlines:      "Deadlines",
+		Binding:        "binding",
+		Amounts:        "Amounts",
+		AmountsHidden:  "Amounts are hidden because you do not have the finance permission.",
+		ActionRequired: "Action required: please review the document.",
+		NotTranslated:  "The summary is only available in the original language.",
+		OnBehalfOf:     "You receive this notification as the deputy of %s.",
+		OpenDocument:   "Open document",
+		DateFormat:     "2006-01-02",
+	},
+	"tr": {
+		Subject:        "Belge analiz edildi: %s",
+		Heading:        "Bir belgenin analizi tamamlandı",
+		Summary:        "Özet",
+		KeyPoints:      "Önemli noktalar",
+		Deadlines:      "Son tarihler",
+		Binding:        "bağlayıcı",
+		Amounts:        "Tutarlar",
+		AmountsHidden:  "Finans yetkiniz olmadığı için tutarlar gizlenmiştir.",
+		ActionRequired: "İşlem gerekli: lütfen belgeyi inceleyin.",
+		NotTranslated:  "Özet yalnızca orijinal dilde mevcuttur.",
+		OnBehalfOf:     "Bu bildirimi %s adına vekil olarak alıyorsunuz.",
+		OpenDocument:   "Belgeyi aç",
+		DateFormat:     "02.01.2006",
+	},
+	"hr": {
+		Subject:        "Dokument analiziran: %s",
+		Heading:        "Analiza dokumenta je završena",
+		Summary:        "Sažetak",
+		KeyPoints:      "Ključne točke",
+		Deadlines:      "Rokovi",
+		Binding:        "obvezujuće",
+		Amounts:        "Iznosi",
+		AmountsHidden:  "Iznosi su skriveni jer nemate ovlaštenje za financijske podatke.",
+		ActionRequired: "Potrebna je radnja: molimo pregledajte dokument.",
+		NotTranslated:  "Sažetak je dostupan samo na izvornom jeziku.",
+		OnBehalfOf:     "Ovu obavijest primate kao zamjena za %s.",
+		OpenDocument:   "Otvori dokument",
+		DateFormat:     "02.01.2006.",
+	},
+	"hu": {
+		Subject:        "Dokumentum elemezve: %s",
+		Heading:        "Egy dokumentum elemzése befejeződött",
+		Summary:        "Összefoglaló",
+		KeyPoints:      "Fő pontok",
+		Deadlines:      "Határidők",
+		Binding:        "kötelező",
+		Amounts:        "Összegek",
+		AmountsHidden:  "Az összegek rejtve vannak, mert nincs pénzügyi jogosultsága.",
+		ActionRequired: "Teendő szükséges: kérjük, ellenőrizze a dokumentumot.",
+		NotTranslated:  "Az összefoglaló csak az eredeti nyelven érhető el.",
+		OnBehalfOf:     "Ezt az értesítést %s helyetteseként kapja.",
+		OpenDocument:   "Dokumentum megnyitása",
+		DateFormat:     "2006.01.02.",
+	},
+}
+
+// messagesFor returns the texts of a language, German if it is unknown
+func messagesFor(language string) messages {
+	if m, ok := catalog[language]; ok {
+		return m
+	}
+	return catalog[DefaultLanguage]
+}
@@ -0,0 +1,208 @@
+package notification
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/analysis"
+	"austrian-business-infrastructure/pkg/money"
+)
+
+// PayloadSchemaVersion is the version of the queued notification payload.
+// Bump it when fields change meaning; consumers such as the mailer and
+// webhooks read older payloads still in the queue.
+const PayloadSchemaVersion = 1
+
+// Notification types
+const (
+	TypeNewDocument       = "new_document"
+	TypeAnalysisCompleted = "analysis_completed"
+	TypeDigest            = "digest"
+)
+
+// DefaultLanguage is the language of documents and of users without a
+// language preference
+const DefaultLanguage = "de"
+
+// SupportedLanguages are the languages notifications are rendered in
+var SupportedLanguages = []string{"de", "en", "tr", "hr", "hu"}
+
+// NormalizeLanguage returns the supported language of a tag such as "en" or
+// "en-GB", or "" if it is not supported
+func NormalizeLanguage(tag string) string {
+	lang := strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	for _, l := range SupportedLanguages {
+		if l == lang {
+			return l
+		}
+	}
+	return ""
+}
+
+// Payload is the content of a queued notification, rendered per recipient
+// when it is queued so that delivery needs no further lookups
+type Payload struct {
+	SchemaVersion int              `json:"schema_version"`
+	Type          string           `json:"type"`
+	Language      string           `json:"language"`
+	Document      DocumentRef      `json:"document"`
+	Link          string           `json:"link"`
+	Analysis      *AnalysisExcerpt `json:"analysis,omitempty"`
+	// OnBehalfOf is the name of the absent user a deputy receives the
+	// notification for
+	OnBehalfOf string `json:"on_behalf_of,omitempty"`
+}
+
+// DocumentRef identifies the document a notification is about
+type DocumentRef struct {
+	ID         uuid.UUID `json:"id"`
+	Title      string    `json:"title"`
+	Type       string    `json:"type"`
+	Sender     string    `json:"sender,omitempty"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// AnalysisExcerpt is the part of a document analysis sent in notifications.
+// Language is the language of the summary, key points and deadline
+// descriptions; it differs from the payload language when the translation
+// was not available.
+type AnalysisExcerpt struct {
+	AnalysisID      uuid.UUID         `json:"analysis_id"`
+	Summary         string            `json:"summary"`
+	KeyPoints       []string          `json:"key_points"`
+	ActionRequired  bool              `json:"action_required"`
+	Deadlines       []DeadlineExcerpt `json:"deadlines,omitempty"`
+	Amounts         []AmountExcerpt   `json:"amounts,omitempty"`
+	AmountsRedacted bool              `json:"amounts_redacted,omitempty"`
+	Language        string            `json:"language"`
+	Translated      bool              `json:"translated,omitempty"`
+}
+
+// DeadlineExcerpt is an extracted deadline
+type DeadlineExcerpt struct {
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	IsHard      bool      `json:"is_hard"`
+}
+
+// AmountExcerpt is an extracted amount
+type AmountExcerpt struct {
+	Type        string      `json:"type"`
+	Amount      money.Money `json:"amount"`
+	Description string      `json:"description,omitempty"`
+}
+
+// NewAnalysisExcerpt builds the excerpt of a completed analysis
+func NewAnalysisExcerpt(result *analysis.FullAnalysisResult) *AnalysisExcerpt {
+	a := result.Analysis
+	excerpt := &AnalysisExcerpt{
+		AnalysisID: a.ID,
+		Summary:    a.Summary,
+		KeyPoints:  append([]string{}, a.KeyPoints...),
+		Language:   a.Language,
+	}
+	if excerpt.Language == "" {
+		excerpt.Language = DefaultLanguage
+	}
+	for _, d := range result.Deadlines {
+		excerpt.Deadlines = append(excerpt.Deadlines, DeadlineExcerpt{
+			Date:        d.Date,
+			Description: d.Description,
+			IsHard:      d.IsHard,
+		})
+	}
+	for _, amount := range result.Amounts {
+		excerpt.Amounts = append(excerpt.Amounts, AmountExcerpt{
+			Type:        amount.AmountType,
+			Amount:      amount.Amount,
+			Description: amount.Description,
+		})
+	}
+	for _, item := range result.ActionItems {
+		if item.Status == analysis.ActionStatusPending {
+			excerpt.ActionRequired = true
+			break
+		}
+	}
+	return excerpt
+}
+
+// texts returns the translatable texts: summary, key points and deadline
+// descriptions, in that order
+func (e *AnalysisExcerpt) texts() []string {
+	texts := append([]string{e.Summary}, e.KeyPoints...)
+	for _, d := range e.Deadlines {
+		texts = append(texts, d.Description)
+	}
+	return texts
+}
+
+// withTexts returns a copy with the texts replaced, as returned by texts
+func (e *AnalysisExcerpt) withTexts(texts []string, language string) *AnalysisExcerpt {
+	c := e.clone()
+	c.Summary = texts[0]
+	copy(c.KeyPoints, texts[1:1+len(c.KeyPoints)])
+	for i := range c.Deadlines {
+		c.Deadlines[i].Description = texts[1+len(c.KeyPoints)+i]
+	}
+	c.Language = language
+	c.Translated = true
+	return c
+}
+
+func (e *AnalysisExcerpt) clone() *AnalysisExcerpt {
+	c := *e
+	c.KeyPoints = append([]string{}, e.KeyPoints...)
+	c.Deadlines = append([]DeadlineExcerpt(nil), e.Deadlines...)
+	c.Amounts = append([]AmountExcerpt(nil), e.Amounts...)
+	return &c
+}
+
+// RedactedAmount replaces amounts in texts of recipients without the
+// finance permission
+const RedactedAmount = "***"
+
+// amountPattern matches euro amounts as written in Austrian documents and
+// in AI summaries: "EUR 1.234,56", "€ 300", "1.234,56 €", "500 Euro"
+var amountPattern = regexp.MustCompile(`(?i)(?:€|\bEUR\b)\s*-?\d(?:[\d.,]*\d)?|-?\d(?:[\d.,]*\d)?\s*(?:€|\bEUR\b|\bEuro\b)`)
+
+// RedactAmounts replaces euro amounts in text
+func RedactAmounts(text string) string {
+	return amountPattern.ReplaceAllString(text, RedactedAmount)
+}
+
+// Redacted returns a copy without the extracted amounts and with amounts in
+// the texts replaced
+func (e *AnalysisExcerpt) Redacted() *AnalysisExcerpt {
+	c := e.clone()
+	redacted := len(c.Amounts) > 0
+	redact := func(text string) string {
+		r := RedactAmounts(text)
+		if r != text {
+			redacted = true
+		}
+		return r
+	}
+	c.Summary = redact(c.Summary)
+	for i := range c.KeyPoints {
+		c.KeyPoints[i] = redact(c.KeyPoints[i])
+	}
+	for i := range c.Deadlines {
+		c.Deadlines[i].Description = redact(c.Deadlines[i].Description)
+	}
+	c.Amounts = nil
+	c.AmountsRedacted = redacted
+	return c
+}
+
+// DocumentLink returns the deep link to a document in the web app
+func DocumentLink(appURL string, documentID uuid.UUID) string {
+	return fmt.Sprintf("%s/documents/%s", strings.TrimRight(appURL, "/"), documentID)
+}
@@ -2,6 +2,7 @@ package notification
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -9,11 +10,14 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/internal/user"
 )
 
 // Errors
 var (
 	ErrPreferencesNotFound = errors.New("preferences not found")
+	ErrRecipientNotFound   = errors.New("recipient not found")
 )
 
 // NotificationMode constants
@@ -33,6 +37,7 @@ type NotificationPreferences struct {
 	DigestTime    string   // HH:MM format for daily digest
 	DocumentTypes []string // empty = all types
 	AccountIDs    []uuid.UUID // empty = all accounts
+	Language      string      // language of notifications, see SupportedLanguages
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
@@ -50,6 +55,7 @@ type NotificationQueueItem struct {
 	ScheduledFor time.Time
 	SentAt       *time.Time
 	CreatedAt    time.Time
+	Payload      *Payload // nil for items queued before payloads existed
 }
 
 // Repository handles notification database operations
@@ -66,7 +72,7 @@ func NewRepository(db *pgxpool.Pool) *Repository {
 func (r *Repository) GetPreferences(ctx context.Context, userID, tenantID uuid.UUID) (*NotificationPreferences, error) {
 	query := `
 		SELECT id, user_id, tenant_id, email_enabled, email_mode, digest_time,
-		       document_types, account_ids, created_at, updated_at
+		       document_types, account_ids, language, created_at, updated_at
 		FROM notification_preferences
 		WHERE user_id = $1 AND tenant_id = $2
 	`
@@ -75,7 +81,7 @@ func (r *Repository) GetPreferences(ctx context.Context, userID, tenantID uuid.U
 	err := r.db.QueryRow(ctx, query, userID, tenantID).Scan(
 		&prefs.ID, &prefs.UserID, &prefs.TenantID,
 		&prefs.EmailEnabled, &prefs.EmailMode, &prefs.DigestTime,
-		&prefs.DocumentTypes, &prefs.AccountIDs,
+		&prefs.DocumentTypes, &prefs.AccountIDs, &prefs.Language,
 		&prefs.CreatedAt, &prefs.UpdatedAt,
 	)
 
@@ -94,14 +100,15 @@ func (r *Repository) UpsertPreferences(ctx context.Context, prefs *NotificationP
 	query := `
 		INSERT INTO notification_preferences (
 			id, user_id, tenant_id, email_enabled, email_mode, digest_time,
-			document_types, account_ids, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			document_types, account_ids, language, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		ON CONFLICT (user_id, tenant_id) DO UPDATE SET
 			email_enabled = EXCLUDED.email_enabled,
 			email_mode = EXCLUDED.email_mode,
 			digest_time = EXCLUDED.digest_time,
 			document_types = EXCLUDED.document_types,
 			account_ids = EXCLUDED.account_ids,
+			language = EXCLUDED.language,
 			updated_at = EXCLUDED.updated_at
 	`
 
@@ -113,11 +120,14 @@ func (r *Repository) UpsertPreferences(ctx context.Context, prefs *NotificationP
 		prefs.CreatedAt = now
 	}
 	prefs.UpdatedAt = now
+	if prefs.Language == "" {
+		prefs.Language = DefaultLanguage
+	}
 
 	_, err := r.db.Exec(ctx, query,
 		prefs.ID, prefs.UserID, prefs.TenantID,
 		prefs.EmailEnabled, prefs.EmailMode, prefs.DigestTime,
-		prefs.DocumentTypes, prefs.AccountIDs,
+		prefs.DocumentTypes, prefs.AccountIDs, prefs.Language,
 		prefs.CreatedAt, prefs.UpdatedAt,
 	)
 
@@ -133,8 +143,8 @@ func (r *Repository) QueueNotification(ctx context.Context, item *NotificationQu
 	query := `
 		INSERT INTO notification_queue (
 			id, tenant_id, user_id, document_id, type, status,
-			attempts, last_error, scheduled_for, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			attempts, last_error, scheduled_for, created_at, payload
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	now := time.Now()
@@ -150,10 +160,17 @@ func (r *Repository) QueueNotification(ctx context.Context, item *NotificationQu
 	if item.Status == "" {
 		item.Status = "pending"
 	}
+	var payload []byte
+	if item.Payload != nil {
+		var err error
+		if payload, err = json.Marshal(item.Payload); err != nil {
+			return fmt.Errorf("marshal notification payload: %w", err)
+		}
+	}
 
 	_, err := r.db.Exec(ctx, query,
 		item.ID, item.TenantID, item.UserID, item.DocumentID, item.Type, item.Status,
-		item.Attempts, item.LastError, item.ScheduledFor, item.CreatedAt,
+		item.Attempts, item.LastError, item.ScheduledFor, item.CreatedAt, payload,
 	)
 
 	if err != nil {
@@ -167,9 +184,10 @@ func (r *Repository) QueueNotification(ctx context.Context, item *NotificationQu
 func (r *Repository) GetPendingNotifications(ctx context.Context, limit int) ([]*NotificationQueueItem, error) {
 	query := `
 		SELECT id, tenant_id, user_id, document_id, type, status,
-		       attempts, last_error, scheduled_for, sent_at, created_at
+		       attempts, last_error, scheduled_for, sent_at, created_at, payload
 		FROM notification_queue
 		WHERE status = 'pending' AND scheduled_for <= NOW() AND attempts < 3
+		  AND type <> 'digest'
 		ORDER BY scheduled_for ASC
 		LIMIT $1
 		FOR UPDATE SKIP LOCKED
@@ -183,14 +201,11 @@ func (r *Repository) GetPendingNotifications(ctx context.Context, limit int) ([]
 
 	var items []*NotificationQueueItem
 	for rows.Next() {
-		var item NotificationQueueItem
-		if err := rows.Scan(
-			&item.ID, &item.TenantID, &item.UserID, &item.DocumentID, &item.Type, &item.Status,
-			&item.Attempts, &item.LastError, &item.ScheduledFor, &item.SentAt, &item.CreatedAt,
-		); err != nil {
+		item, err := scanQueueItem(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan notification: %w", err)
 		}
-		items = append(items, &item)
+		items = append(items, item)
 	}
 
 	return items, nil
@@ -235,7 +250,7 @@ func (r *Repository) MarkNotificationFailed(ctx context.Context, id uuid.UUID, e
 func (r *Repository) GetDigestItems(ctx context.Context, userID, tenantID uuid.UUID, since time.Time) ([]*NotificationQueueItem, error) {
 	query := `
 		SELECT id, tenant_id, user_id, document_id, type, status,
-		       attempts, last_error, scheduled_for, sent_at, created_at
+		       attempts, last_error, scheduled_for, sent_at, created_at, payload
 		FROM notification_queue
 		WHERE user_id = $1 AND tenant_id = $2 AND created_at >= $3 AND type = 'digest'
 		ORDER BY created_at ASC
@@ -249,14 +264,11 @@ func (r *Repository) GetDigestItems(ctx context.Context, userID, tenantID uuid.U
 
 	var items []*NotificationQueueItem
 	for rows.Next() {
-		var item NotificationQueueItem
-		if err := rows.Scan(
-			&item.ID, &item.TenantID, &item.UserID, &item.DocumentID, &item.Type, &item.Status,
-			&item.Attempts, &item.LastError, &item.ScheduledFor, &item.SentAt, &item.CreatedAt,
-		); err != nil {
+		item, err := scanQueueItem(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan digest item: %w", err)
 		}
-		items = append(items, &item)
+		items = append(items, item)
 	}
 
 	return items, nil
@@ -266,7 +278,7 @@ func (r *Repository) GetDigestItems(ctx context.Context, userID, tenantID uuid.U
 func (r *Repository) GetUsersWithDigestEnabled(ctx context.Context, digestTime string) ([]NotificationPreferences, error) {
 	query := `
 		SELECT id, user_id, tenant_id, email_enabled, email_mode, digest_time,
-		       document_types, account_ids, created_at, updated_at
+		       document_types, account_ids, language, created_at, updated_at
 		FROM notification_preferences
 		WHERE email_enabled = true AND email_mode = 'digest' AND digest_time = $1
 	`
@@ -283,7 +295,7 @@ func (r *Repository) GetUsersWithDigestEnabled(ctx context.Context, digestTime s
 		if err := rows.Scan(
 			&p.ID, &p.UserID, &p.TenantID,
 			&p.EmailEnabled, &p.EmailMode, &p.DigestTime,
-			&p.DocumentTypes, &p.AccountIDs,
+			&p.DocumentTypes, &p.AccountIDs, &p.Language,
 			&p.CreatedAt, &p.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan preferences: %w", err)
@@ -293,3 +305,111 @@ func (r *Repository) GetUsersWithDigestEnabled(ctx context.Context, digestTime s
 
 	return prefs, nil
 }
+
+func scanQueueItem(rows pgx.Rows) (*NotificationQueueItem, error) {
+	var item NotificationQueueItem
+	var payload []byte
+	if err := rows.Scan(
+		&item.ID, &item.TenantID, &item.UserID, &item.DocumentID, &item.Type, &item.Status,
+		&item.Attempts, &item.LastError, &item.ScheduledFor, &item.SentAt, &item.CreatedAt, &payload,
+	); err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		item.Payload = &Payload{}
+		if err := json.Unmarshal(payload, item.Payload); err != nil {
+			return nil, fmt.Errorf("decode payload: %w", err)
+		}
+	}
+	return &item, nil
+}
+
+// Recipient is a user with notifications enabled
+type Recipient struct {
+	UserID        uuid.UUID
+	Email         string
+	Name          string
+	FinanceAccess bool
+	Preferences   NotificationPreferences
+}
+
+// ListRecipients returns the active users of a tenant with email
+// notifications enabled
+func (r *Repository) ListRecipients(ctx context.Context, tenantID uuid.UUID) ([]Recipient, error) {
+	query := `
+		SELECT u.id, u.email, u.name, u.role, u.finance_access,
+		       p.id, p.email_enabled, p.email_mode, p.digest_time,
+		       p.document_types, p.account_ids, p.language
+		FROM notification_preferences p
+		JOIN users u ON u.id = p.user_id AND u.tenant_id = p.tenant_id
+		WHERE p.tenant_id = $1 AND p.email_enabled = true AND p.email_mode <> 'off'
+		  AND u.is_active = true
+		ORDER BY u.created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []Recipient
+	for rows.Next() {
+		var rcpt Recipient
+		var role string
+		var financeAccess *bool
+		p := &rcpt.Preferences
+		if err := rows.Scan(
+			&rcpt.UserID, &rcpt.Email, &rcpt.Name, &role, &financeAccess,
+			&p.ID, &p.EmailEnabled, &p.EmailMode, &p.DigestTime,
+			&p.DocumentTypes, &p.AccountIDs, &p.Language,
+		); err != nil {
+			return nil, fmt.Errorf("scan recipient: %w", err)
+		}
+		rcpt.FinanceAccess = user.HasFinanceAccess(user.Role(role), financeAccess)
+		p.UserID = rcpt.UserID
+		p.TenantID = tenantID
+		recipients = append(recipients, rcpt)
+	}
+
+	return recipients, rows.Err()
+}
+
+// GetRecipient returns an active user of a tenant as a recipient, whether
+// or not they enabled notifications. Users without preferences get the
+// defaults with notifications off.
+func (r *Repository) GetRecipient(ctx context.Context, tenantID, userID uuid.UUID) (*Recipient, error) {
+	query := `
+		SELECT u.id, u.email, u.name, u.role, u.finance_access,
+		       p.id, COALESCE(p.email_enabled, false), COALESCE(p.email_mode, 'off'),
+		       COALESCE(p.digest_time, '08:00'), p.document_types, p.account_ids,
+		       COALESCE(p.language, '')
+		FROM users u
+		LEFT JOIN notification_preferences p ON p.user_id = u.id AND p.tenant_id = u.tenant_id
+		WHERE u.tenant_id = $1 AND u.id = $2 AND u.is_active = true
+	`
+
+	var rcpt Recipient
+	var role string
+	var financeAccess *bool
+	var prefsID *uuid.UUID
+	p := &rcpt.Preferences
+	err := r.db.QueryRow(ctx, query, tenantID, userID).Scan(
+		&rcpt.UserID, &rcpt.Email, &rcpt.Name, &role, &financeAccess,
+		&prefsID, &p.EmailEnabled, &p.EmailMode, &p.DigestTime,
+		&p.DocumentTypes, &p.AccountIDs, &p.Language,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRecipientNotFound
+		}
+		return nil, fmt.Errorf("get recipient: %w", err)
+	}
+	if prefsID != nil {
+		p.ID = *prefsID
+	}
+	rcpt.FinanceAccess = user.HasFinanceAccess(user.Role(role), financeAccess)
+	p.UserID = rcpt.UserID
+	p.TenantID = tenantID
+	return &rcpt, nil
+}
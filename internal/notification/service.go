@@ -3,13 +3,16 @@ package notification
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
 	"time"
 
+	"austrian-business-infrastructure/internal/analysis"
 	"austrian-business-infrastructure/internal/document"
 	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/mail"
 	"github.com/google/uuid"
 )
 
@@ -21,12 +24,40 @@ type Service struct {
 	logger     *slog.Logger
 	appURL     string
 	templates  *Templates
+	translator Translator
+	deputies   Deputies
+	mailer     Mailer
+
+	unsubscribe *UnsubscribeSigner
+}
+
+// Deputies tells who receives the notifications of each user who is away
+// at a time; team.Service implements it
+type Deputies interface {
+	NotificationDeputies(ctx context.Context, tenantID uuid.UUID, at time.Time) (map[uuid.UUID]uuid.UUID, error)
+}
+
+// ErrNoMailer is returned for notifications queued for email while no
+// mailer is set
+var ErrNoMailer = errors.New("no mailer configured")
+
+// Mailer sends notification emails; *mail.Service implements it, applying
+// the recipient's preferences and the suppression list
+type Mailer interface {
+	Send(ctx context.Context, msg *mail.Message) error
+}
+
+// Translator translates analysis texts into the language of a recipient.
+// It returns the texts in the same order.
+type Translator interface {
+	Translate(ctx context.Context, texts []string, from, to string) ([]string, error)
 }
 
 // Templates holds email templates
 type Templates struct {
-	NewDocument *template.Template
-	Digest      *template.Template
+	NewDocument       *template.Template
+	Digest            *template.Template
+	AnalysisCompleted *template.Template
 }
 
 // ServiceConfig holds service configuration
@@ -58,14 +89,33 @@ func NewService(repo *Repository, docRepo *document.Repository, emailSvc email.S
 	}
 }
 
+// SetTranslator enables analysis summaries in the language of each
+// recipient; without it they are sent in the document language
+func (s *Service) SetTranslator(t Translator) {
+	s.translator = t
+}
+
+// SetDeputies enables copies of notifications for the deputies of absent
+// users
+func (s *Service) SetDeputies(d Deputies) {
+	s.deputies = d
+}
+
+// SetMailer enables the delivery of queued analysis notifications
+func (s *Service) SetMailer(m Mailer) {
+	s.mailer = m
+}
+
 // loadTemplates loads email templates
 func loadTemplates() *Templates {
 	newDocTmpl := template.Must(template.New("new_document").Parse(newDocumentTemplate))
 	digestTmpl := template.Must(template.New("digest").Parse(digestTemplate))
+	analysisTmpl := template.Must(template.New("analysis_completed").Parse(analysisCompletedTemplate))
 
 	return &Templates{
-		NewDocument: newDocTmpl,
-		Digest:      digestTmpl,
+		NewDocument:       newDocTmpl,
+		Digest:            digestTmpl,
+		AnalysisCompleted: analysisTmpl,
 	}
 }
 
@@ -80,6 +130,7 @@ func (s *Service) GetPreferences(ctx context.Context, userID, tenantID uuid.UUID
 			EmailEnabled: false,
 			EmailMode:    ModeOff,
 			DigestTime:   "08:00",
+			Language:     DefaultLanguage,
 		}, nil
 	}
 	return prefs, err
@@ -100,7 +151,7 @@ func (s *Service) NotifyNewDocument(ctx context.Context, tenantID uuid.UUID, doc
 	item := &NotificationQueueItem{
 		TenantID:   tenantID,
 		DocumentID: doc.ID,
-		Type:       "new_document",
+		Type:       TypeNewDocument,
 		Status:     "pending",
 	}
 
@@ -119,6 +170,239 @@ func (s *Service) NotifyUsersAboutDocument(ctx context.Context, tenantID uuid.UU
 	return nil
 }
 
+// NotifyAnalysisCompleted queues a notification with the analysis summary
+// for every user of the tenant who is notified about the document. Each
+// payload is in the language of the recipient, falling back to the
+// document language when no translation is available, and has amounts
+// redacted for recipients without the finance permission.
+//
+// The deputy of a notified user who is away receives a copy marked as on
+// behalf of them, in the deputy's language and with the deputy's finance
+// permission, unless the deputy is notified already. Deputies receive the
+// copy even with notifications disabled, immediately unless they chose the
+// digest. Users who disabled analysis emails in their preference matrix
+// receive neither, and neither do recipients or deputies the document
+// access lists do not let see the document.
+func (s *Service) NotifyAnalysisCompleted(ctx context.Context, tenantID uuid.UUID, result *analysis.FullAnalysisResult) error {
+	doc, err := s.docRepo.GetByID(ctx, tenantID, result.Analysis.DocumentID)
+	if err != nil {
+		return fmt.Errorf("get document: %w", err)
+	}
+	recipients, err := s.repo.ListRecipients(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	optOuts, err := s.repo.OptOuts(ctx, tenantID, EventAnalysis, ChannelEmail)
+	if err != nil {
+		return err
+	}
+	var deputies map[uuid.UUID]uuid.UUID
+	if s.deputies != nil {
+		deputies, err = s.deputies.NotificationDeputies(ctx, tenantID, time.Now())
+		if err != nil {
+			// Deputies only get copies; the recipients are still notified
+			s.logger.Warn("failed to load notification deputies", "tenant_id", tenantID, "error", err)
+		}
+	}
+	userIDs := make([]uuid.UUID, 0, len(recipients)+len(deputies))
+	for _, rcpt := range recipients {
+		userIDs = append(userIDs, rcpt.UserID)
+	}
+	for _, deputyID := range deputies {
+		userIDs = append(userIDs, deputyID)
+	}
+	viewers, err := s.docRepo.FilterViewers(ctx, tenantID, doc.ID, userIDs)
+	if err != nil {
+		return err
+	}
+
+	source := NewAnalysisExcerpt(result)
+	excerpts := map[string]*AnalysisExcerpt{source.Language: source}
+	notified := make(map[uuid.UUID]bool)
+	var absent []Recipient
+	for _, rcpt := range recipients {
+		if optOuts[rcpt.UserID] || !viewers[rcpt.UserID] || !s.ShouldNotify(&rcpt.Preferences, doc) {
+			continue
+		}
+		payload := s.analysisPayload(ctx, rcpt, doc, source, excerpts)
+		if err := s.queueAnalysis(ctx, tenantID, rcpt, doc, payload); err != nil {
+			return err
+		}
+		notified[rcpt.UserID] = true
+		if _, away := deputies[rcpt.UserID]; away {
+			absent = append(absent, rcpt)
+		}
+	}
+
+	copies := 0
+	for _, rcpt := range absent {
+		deputyID := deputies[rcpt.UserID]
+		if notified[deputyID] || optOuts[deputyID] || !viewers[deputyID] {
+			continue
+		}
+		deputy, err := s.repo.GetRecipient(ctx, tenantID, deputyID)
+		if errors.Is(err, ErrRecipientNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !deputy.Preferences.EmailEnabled || deputy.Preferences.EmailMode == ModeOff {
+			deputy.Preferences.EmailMode = ModeImmediate
+		}
+		payload := s.analysisPayload(ctx, *deputy, doc, source, excerpts)
+		payload.OnBehalfOf = rcpt.Name
+		if err := s.queueAnalysis(ctx, tenantID, *deputy, doc, payload); err != nil {
+			return err
+		}
+		notified[deputyID] = true
+		copies++
+	}
+	queued := len(notified) - copies
+
+	s.logger.Info("queued analysis notifications",
+		"tenant_id", tenantID,
+		"document_id", doc.ID,
+		"recipients", queued,
+		"deputies", copies)
+	return nil
+}
+
+// queueAnalysis queues an analysis notification for a recipient, for the
+// digest if they chose it
+func (s *Service) queueAnalysis(ctx context.Context, tenantID uuid.UUID, rcpt Recipient, doc *document.Document, payload *Payload) error {
+	item := &NotificationQueueItem{
+		TenantID:   tenantID,
+		UserID:     rcpt.UserID,
+		DocumentID: doc.ID,
+		Type:       TypeAnalysisCompleted,
+		Payload:    payload,
+	}
+	if rcpt.Preferences.EmailMode == ModeDigest {
+		item.Type = TypeDigest
+	}
+	return s.repo.QueueNotification(ctx, item)
+}
+
+// AnalysisPayload builds the analysis notification payload for one
+// recipient
+func (s *Service) AnalysisPayload(ctx context.Context, rcpt Recipient, doc *document.Document, source *AnalysisExcerpt) *Payload {
+	return s.analysisPayload(ctx, rcpt, doc, source, map[string]*AnalysisExcerpt{source.Language: source})
+}
+
+// analysisPayload builds the payload for one recipient. excerpts caches
+// the excerpt per language so that each language is translated once.
+func (s *Service) analysisPayload(ctx context.Context, rcpt Recipient, doc *document.Document, source *AnalysisExcerpt, excerpts map[string]*AnalysisExcerpt) *Payload {
+	language := NormalizeLanguage(rcpt.Preferences.Language)
+	if language == "" {
+		language = DefaultLanguage
+	}
+
+	excerpt, ok := excerpts[language]
+	if !ok {
+		excerpt = source
+		if s.translator != nil {
+			texts, err := s.translator.Translate(ctx, source.texts(), source.Language, language)
+			if err != nil {
+				s.logger.Warn("analysis summary not translated, sending the original",
+					"analysis_id", source.AnalysisID,
+					"language", language,
+					"error", err)
+			} else {
+				excerpt = source.withTexts(texts, language)
+			}
+		}
+		excerpts[language] = excerpt
+	}
+	if !rcpt.FinanceAccess {
+		excerpt = excerpt.Redacted()
+	}
+
+	return &Payload{
+		SchemaVersion: PayloadSchemaVersion,
+		Type:          TypeAnalysisCompleted,
+		Language:      language,
+		Document: DocumentRef{
+			ID:         doc.ID,
+			Title:      doc.Title,
+			Type:       doc.Type,
+			Sender:     doc.Sender,
+			ReceivedAt: doc.ReceivedAt,
+		},
+		Link:     DocumentLink(s.appURL, doc.ID),
+		Analysis: excerpt,
+	}
+}
+
+// RenderAnalysisEmail renders the subject and body of an analysis
+// notification in the language of its payload
+func (s *Service) RenderAnalysisEmail(p *Payload) (subject, body string, err error) {
+	msgs := messagesFor(p.Language)
+	data := AnalysisEmailData{Payload: p, Text: msgs}
+	if p.Analysis != nil {
+		for _, d := range p.Analysis.Deadlines {
+			data.Deadlines = append(data.Deadlines, AnalysisDeadlineData{
+				Date:        d.Date.Format(msgs.DateFormat),
+				Description: d.Description,
+				IsHard:      d.IsHard,
+			})
+		}
+		for _, a := range p.Analysis.Amounts {
+			data.Amounts = append(data.Amounts, AnalysisAmountData{
+				Amount:      a.Amount.String(),
+				Description: a.Description,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := s.templates.AnalysisCompleted.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("execute analysis template: %w", err)
+	}
+	return fmt.Sprintf(msgs.Subject, p.Document.Title), buf.String(), nil
+}
+
+// sendAnalysis mails a queued analysis notification to its recipient.
+// Recipients who were deactivated since it was queued are skipped.
+func (s *Service) sendAnalysis(ctx context.Context, item *NotificationQueueItem) error {
+	if s.mailer == nil {
+		return ErrNoMailer
+	}
+	rcpt, err := s.repo.GetRecipient(ctx, item.TenantID, item.UserID)
+	if errors.Is(err, ErrRecipientNotFound) {
+		s.logger.Info("analysis notification skipped, recipient inactive", "id", item.ID, "user_id", item.UserID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.SendAnalysisEmail(ctx, item.TenantID, rcpt.Email, item.Payload)
+}
+
+// SendAnalysisEmail renders an analysis notification and mails it. The
+// mailer applies the recipient's preference matrix and the suppression
+// list; a recipient who opted out or is suppressed is not an error.
+func (s *Service) SendAnalysisEmail(ctx context.Context, tenantID uuid.UUID, to string, p *Payload) error {
+	if s.mailer == nil {
+		return ErrNoMailer
+	}
+	subject, body, err := s.RenderAnalysisEmail(p)
+	if err != nil {
+		return err
+	}
+	err = s.mailer.Send(ctx, &mail.Message{
+		TenantID: &tenantID,
+		To:       to,
+		Subject:  subject,
+		Text:     body,
+		Category: TypeAnalysisCompleted,
+	})
+	if errors.Is(err, mail.ErrOptedOut) || errors.Is(err, mail.ErrSuppressed) {
+		return nil
+	}
+	return err
+}
+
 // ShouldNotify checks if a user should be notified about a document
 func (s *Service) ShouldNotify(prefs *NotificationPreferences, doc *document.Document) bool {
 	if !prefs.EmailEnabled {
@@ -157,12 +441,14 @@ func (s *Service) ShouldNotify(prefs *NotificationPreferences, doc *document.Doc
 }
 
 // ProcessPendingNotifications processes pending notifications in the queue
-func (s *Service) ProcessPendingNotifications(ctx context.Context, batchSize int) error {
+// and returns how many were sent
+func (s *Service) ProcessPendingNotifications(ctx context.Context, batchSize int) (int, error) {
 	items, err := s.repo.GetPendingNotifications(ctx, batchSize)
 	if err != nil {
-		return fmt.Errorf("get pending: %w", err)
+		return 0, fmt.Errorf("get pending: %w", err)
 	}
 
+	sent := 0
 	for _, item := range items {
 		if err := s.sendNotification(ctx, item); err != nil {
 			s.logger.Error("failed to send notification",
@@ -171,14 +457,19 @@ func (s *Service) ProcessPendingNotifications(ctx context.Context, batchSize int
 			s.repo.MarkNotificationFailed(ctx, item.ID, err.Error())
 		} else {
 			s.repo.MarkNotificationSent(ctx, item.ID)
+			sent++
 		}
 	}
 
-	return nil
+	return sent, nil
 }
 
 // sendNotification sends a single notification
 func (s *Service) sendNotification(ctx context.Context, item *NotificationQueueItem) error {
+	if item.Type == TypeAnalysisCompleted && item.Payload != nil {
+		return s.sendAnalysis(ctx, item)
+	}
+
 	// Get document details with tenant isolation
 	doc, err := s.docRepo.GetByID(ctx, item.TenantID, item.DocumentID)
 	if err != nil {
@@ -220,20 +511,35 @@ func (s *Service) SendDigest(ctx context.Context, userID, tenantID uuid.UUID, us
 		return nil // No new documents, skip digest
 	}
 
-	// Get document details for each item with tenant isolation
-	var docs []DigestDocumentData
+	// Get document details for all items with tenant isolation; documents
+	// that can't be retrieved are skipped
+	ids := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		ids[i] = item.DocumentID
+	}
+	documents, err := s.docRepo.GetByIDs(ctx, tenantID, ids)
+	if err != nil {
+		return fmt.Errorf("get digest documents: %w", err)
+	}
+
+	// Analysis summaries queued for the digest, already in the user's
+	// language and redacted
+	summaries := make(map[uuid.UUID]string)
 	for _, item := range items {
-		doc, err := s.docRepo.GetByID(ctx, tenantID, item.DocumentID)
-		if err != nil {
-			continue // Skip documents that can't be retrieved
+		if item.Payload != nil && item.Payload.Analysis != nil {
+			summaries[item.DocumentID] = item.Payload.Analysis.Summary
 		}
+	}
 
+	var docs []DigestDocumentData
+	for _, doc := range documents {
 		docs = append(docs, DigestDocumentData{
 			Title:      doc.Title,
 			Type:       doc.Type,
 			Sender:     doc.Sender,
 			ReceivedAt: doc.ReceivedAt.Format("02.01.2006 15:04"),
-			URL:        fmt.Sprintf("%s/documents/%s", s.appURL, doc.ID),
+			URL:        DocumentLink(s.appURL, doc.ID),
+			Summary:    summaries[doc.ID],
 		})
 	}
 
@@ -284,6 +590,28 @@ type DigestDocumentData struct {
 	Sender     string
 	ReceivedAt string
 	URL        string
+	Summary    string
+}
+
+// AnalysisEmailData holds data for the analysis notification template
+type AnalysisEmailData struct {
+	*Payload
+	Text      messages
+	Deadlines []AnalysisDeadlineData
+	Amounts   []AnalysisAmountData
+}
+
+// AnalysisDeadlineData holds a deadline formatted for the recipient
+type AnalysisDeadlineData struct {
+	Date        string
+	Description string
+	IsHard      bool
+}
+
+// AnalysisAmountData holds an amount formatted for the recipient
+type AnalysisAmountData struct {
+	Amount      string
+	Description string
 }
 
 // Email templates
@@ -313,6 +641,9 @@ Sie haben {{.DocumentCount}} neue Dokumente erhalten:
 - {{.Title}} ({{.Type}})
   Von: {{.Sender}}
   Empfangen: {{.ReceivedAt}}
+{{- if .Summary}}
+  {{.Summary}}
+{{- end}}
   {{.URL}}
 
 {{end}}
@@ -322,3 +653,54 @@ Alle Dokumente ansehen: {{.DashboardURL}}
 --
 Austrian Business Platform
 `
+
+const analysisCompletedTemplate = `
+{{.Text.Heading}}
+{{- if .OnBehalfOf}}
+{{printf .Text.OnBehalfOf .OnBehalfOf}}
+{{- end}}
+
+{{.Document.Title}} ({{.Document.Type}})
+{{- with .Analysis}}
+
+{{$.Text.Summary}}:
+{{.Summary}}
+{{- if not .Translated}}{{if ne .Language $.Language}}
+({{$.Text.NotTranslated}})
+{{- end}}{{end}}
+{{- if .KeyPoints}}
+
+{{$.Text.KeyPoints}}:
+{{- range .KeyPoints}}
+- {{.}}
+{{- end}}
+{{- end}}
+{{- if $.Deadlines}}
+
+{{$.Text.Deadlines}}:
+{{- range $.Deadlines}}
+- {{.Date}}: {{.Description}}{{if .IsHard}} ({{$.Text.Binding}}){{end}}
+{{- end}}
+{{- end}}
+{{- if $.Amounts}}
+
+{{$.Text.Amounts}}:
+{{- range $.Amounts}}
+- {{.Amount}}{{if .Description}}: {{.Description}}{{end}}
+{{- end}}
+{{- end}}
+{{- if .AmountsRedacted}}
+
+{{$.Text.AmountsHidden}}
+{{- end}}
+{{- if .ActionRequired}}
+
+{{$.Text.ActionRequired}}
+{{- end}}
+{{- end}}
+
+{{.Text.OpenDocument}}: {{.Link}}
+
+--
+Austrian Business Platform
+`
@@ -0,0 +1,78 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidUnsubscribeToken is returned for a tampered or malformed
+// unsubscribe token
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+// UnsubscribeClaims identify what an unsubscribe link opts out of
+type UnsubscribeClaims struct {
+	Email     string     `json:"email"`
+	TenantID  *uuid.UUID `json:"tenant_id,omitempty"` // nil for platform mails
+	EventType string     `json:"event"`
+}
+
+// UnsubscribeSigner signs the tokens of one-click unsubscribe links. Tokens
+// do not expire: a link in an old email must keep working.
+type UnsubscribeSigner struct {
+	key []byte
+}
+
+// NewUnsubscribeSigner creates a signer with an HMAC key
+func NewUnsubscribeSigner(key []byte) *UnsubscribeSigner {
+	return &UnsubscribeSigner{key: key}
+}
+
+// Sign returns the token for claims: the base64url JSON claims and their
+// HMAC-SHA256, separated by a dot
+func (s *UnsubscribeSigner) Sign(claims *UnsubscribeClaims) (string, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + s.mac(payload), nil
+}
+
+// Verify checks a token and returns its claims
+func (s *UnsubscribeSigner) Verify(token string) (*UnsubscribeClaims, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.mac(payload))) {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	var claims UnsubscribeClaims
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	if claims.Email == "" || !slices.Contains(EventTypes, claims.EventType) || Exempt(claims.EventType) {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	return &claims, nil
+}
+
+func (s *UnsubscribeSigner) mac(payload string) string {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// UnsubscribeLink returns the one-click unsubscribe URL for a token
+func UnsubscribeLink(appURL, token string) string {
+	return strings.TrimRight(appURL, "/") + "/api/v1/notifications/unsubscribe?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,81 @@
+package ocr
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"austrian-business-infrastructure/internal/filetype"
+)
+
+// MIME types of documents text can be extracted from
+const (
+	MIMEPDF  = filetype.PDF
+	MIMEDOCX = filetype.DOCX
+	MIMEXLSX = filetype.XLSX
+	MIMEJPEG = filetype.JPEG
+	MIMEPNG  = filetype.PNG
+	MIMEHEIC = filetype.HEIC
+)
+
+// Extraction limits
+const (
+	MaxExtractInputSize = 50 << 20 // bytes of the original file
+	MaxExtractedText    = 1 << 20  // bytes of normalized text passed on to the AI stages
+)
+
+// Extraction errors
+var (
+	ErrUnsupportedFormat = errors.New("no text extraction for this format")
+	ErrInputTooLarge     = errors.New("document exceeds the extraction size limit")
+)
+
+// DetectMIME determines the type of a document from its content. The
+// declared type is only used when the content is not conclusive, so a DOCX
+// stored as application/octet-stream or a PNG named .pdf is still handled
+// correctly.
+func DetectMIME(data []byte, declared string) string {
+	declared, _, _ = strings.Cut(declared, ";")
+	declared = strings.ToLower(strings.TrimSpace(declared))
+
+	detected := filetype.Sniff(data)
+	switch detected {
+	case filetype.Unknown, filetype.OLE, "text/plain":
+		if declared != "" {
+			return declared
+		}
+	}
+	return detected
+}
+
+// IsImage reports whether the type is an image extracted with OCR
+func IsImage(mimeType string) bool {
+	return mimeType == MIMEJPEG || mimeType == MIMEPNG || mimeType == MIMEHEIC
+}
+
+// IsExtractable reports whether text can be extracted from the type
+func IsExtractable(mimeType string) bool {
+	switch mimeType {
+	case MIMEPDF, MIMEDOCX, MIMEXLSX:
+		return true
+	}
+	return IsImage(mimeType)
+}
+
+// NormalizeText brings extracted text into the form the classification and
+// extraction stages expect, whatever the source format: valid UTF-8, Unix
+// line breaks, no control characters or blank lines, and at most
+// MaxExtractedText bytes
+func NormalizeText(text string) string {
+	text = strings.ToValidUTF8(text, "")
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\u00a0", " ", "\u00ad", "").Replace(text)
+	text = cleanText(text)
+	if len(text) > MaxExtractedText {
+		cut := MaxExtractedText
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut]
+	}
+	return text
+}
@@ -0,0 +1,372 @@
+package ocr
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Limits for office documents, which are zip archives: entries are read
+// decompressed, so a small file could otherwise expand without bound
+const (
+	MaxArchiveEntrySize = 64 << 20  // decompressed bytes per XML part
+	MaxArchiveTotalSize = 256 << 20 // decompressed bytes per document
+	MaxSpreadsheetCells = 500000
+)
+
+// ErrArchiveTooLarge is returned when an office document expands beyond the limits
+var ErrArchiveTooLarge = errors.New("office document exceeds the extraction limits")
+
+// officeArchive reads the parts of an OOXML package within the size limits
+type officeArchive struct {
+	files map[string]*zip.File
+	read  int64
+}
+
+func openOfficeArchive(data []byte) (*officeArchive, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("open office document: %w", err)
+	}
+	a := &officeArchive{files: make(map[string]*zip.File, len(zr.File))}
+	for _, f := range zr.File {
+		a.files[f.Name] = f
+	}
+	return a, nil
+}
+
+func (a *officeArchive) has(name string) bool {
+	_, ok := a.files[name]
+	return ok
+}
+
+// open returns a reader of a part that fails once a limit is exceeded
+func (a *officeArchive) open(name string) (io.ReadCloser, error) {
+	f, ok := a.files[name]
+	if !ok {
+		return nil, fmt.Errorf("office document lacks %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", name, err)
+	}
+	return &limitedPart{rc: rc, archive: a, left: MaxArchiveEntrySize}, nil
+}
+
+type limitedPart struct {
+	rc      io.ReadCloser
+	archive *officeArchive
+	left    int64
+}
+
+func (p *limitedPart) Read(b []byte) (int, error) {
+	n, err := p.rc.Read(b)
+	p.left -= int64(n)
+	p.archive.read += int64(n)
+	if p.left < 0 || p.archive.read > MaxArchiveTotalSize {
+		return n, ErrArchiveTooLarge
+	}
+	return n, err
+}
+
+func (p *limitedPart) Close() error {
+	return p.rc.Close()
+}
+
+// ExtractDOCXText extracts the text of a Word document: body paragraphs and
+// tables, with cells separated by tabs
+func ExtractDOCXText(data []byte) (string, error) {
+	archive, err := openOfficeArchive(data)
+	if err != nil {
+		return "", err
+	}
+	part, err := archive.open("word/document.xml")
+	if err != nil {
+		return "", err
+	}
+	defer part.Close()
+
+	var text strings.Builder
+	decoder := xml.NewDecoder(part)
+	inText := false
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("parse document.xml: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				text.WriteByte('\t')
+			case "br", "cr":
+				text.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text.WriteByte('\n')
+			case "tc":
+				text.WriteByte('\t')
+			case "tr":
+				text.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+	return NormalizeText(text.String()), nil
+}
+
+// ExtractXLSXText extracts the cell values of a workbook, sheet by sheet in
+// workbook order, one row per line with cells separated by tabs. Formulas
+// contribute their cached value.
+func ExtractXLSXText(data []byte) (string, error) {
+	archive, err := openOfficeArchive(data)
+	if err != nil {
+		return "", err
+	}
+
+	shared, err := readSharedStrings(archive)
+	if err != nil {
+		return "", err
+	}
+	sheets, err := workbookSheets(archive)
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	cells := 0
+	for _, sheet := range sheets {
+		text.WriteString("# " + sheet.name + "\n")
+		if err := readSheet(archive, sheet.path, shared, &text, &cells); err != nil {
+			return "", err
+		}
+		text.WriteByte('\n')
+	}
+	return NormalizeText(text.String()), nil
+}
+
+// readSharedStrings reads the string table cells of type "s" refer to
+func readSharedStrings(archive *officeArchive) ([]string, error) {
+	const name = "xl/sharedStrings.xml"
+	if !archive.has(name) {
+		return nil, nil
+	}
+	part, err := archive.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer part.Close()
+
+	var strs []string
+	var current strings.Builder
+	inText, inPhonetic := false, false
+	decoder := xml.NewDecoder(part)
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse sharedStrings.xml: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				current.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				// Phonetic hints repeat the text in another script
+				inPhonetic = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				strs = append(strs, current.String())
+			case "t":
+				inText = false
+			case "rPh":
+				inPhonetic = false
+			}
+		case xml.CharData:
+			if inText && !inPhonetic {
+				current.Write(t)
+			}
+		}
+	}
+	return strs, nil
+}
+
+type workbookSheet struct {
+	name string
+	path string
+}
+
+// workbookSheets returns the worksheets in workbook order, resolved through
+// the workbook relationships
+func workbookSheets(archive *officeArchive) ([]workbookSheet, error) {
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(archive, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if archive.has("xl/_rels/workbook.xml.rels") {
+		if err := decodePart(archive, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+			return nil, err
+		}
+	}
+
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		targets[rel.ID] = target
+	}
+
+	var sheets []workbookSheet
+	for _, s := range workbook.Sheets {
+		if target, ok := targets[s.RID]; ok && archive.has(target) {
+			sheets = append(sheets, workbookSheet{name: s.Name, path: target})
+		}
+	}
+	if len(sheets) > 0 {
+		return sheets, nil
+	}
+
+	// Without usable relationships fall back to the worksheet parts
+	for name := range archive.files {
+		if strings.HasPrefix(name, "xl/worksheets/") && strings.HasSuffix(name, ".xml") {
+			base := strings.TrimSuffix(path.Base(name), ".xml")
+			sheets = append(sheets, workbookSheet{name: base, path: name})
+		}
+	}
+	sort.Slice(sheets, func(i, j int) bool { return sheets[i].path < sheets[j].path })
+	return sheets, nil
+}
+
+func decodePart(archive *officeArchive, name string, v interface{}) error {
+	part, err := archive.open(name)
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+	if err := xml.NewDecoder(part).Decode(v); err != nil {
+		return fmt.Errorf("parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// readSheet writes the non-empty rows of a worksheet
+func readSheet(archive *officeArchive, name string, shared []string, text *strings.Builder, cells *int) error {
+	part, err := archive.open(name)
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+
+	var row []string
+	var cellType string
+	var value strings.Builder
+	inValue := false
+	decoder := xml.NewDecoder(part)
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", name, err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				row = row[:0]
+			case "c":
+				cellType = ""
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "t" {
+						cellType = attr.Value
+					}
+				}
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				*cells++
+				if *cells > MaxSpreadsheetCells {
+					return ErrArchiveTooLarge
+				}
+				row = append(row, cellValue(cellType, value.String(), shared))
+			case "row":
+				line := strings.TrimRight(strings.Join(row, "\t"), "\t")
+				if strings.TrimSpace(line) != "" {
+					text.WriteString(line)
+					text.WriteByte('\n')
+				}
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+	}
+}
+
+// cellValue resolves the displayed value of a cell
+func cellValue(cellType, raw string, shared []string) string {
+	switch cellType {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil || i < 0 || i >= len(shared) {
+			return ""
+		}
+		return shared[i]
+	case "b":
+		if raw == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	default:
+		return raw
+	}
+}
@@ -34,11 +34,21 @@ type Service struct {
 	minConfidence float64
 }
 
+// Processor extracts text from scanned documents. *Service implements it
+// with HunyuanOCR and Tesseract; internal/fakes provides an in-memory one.
+type Processor interface {
+	ProcessBytes(ctx context.Context, data []byte) (*Result, error)
+	ProcessImage(ctx context.Context, data []byte, mimeType string) (*Result, error)
+}
+
+var _ Processor = (*Service)(nil)
+
 // ServiceConfig holds OCR service configuration
 type ServiceConfig struct {
 	Provider         Provider
 	HunyuanURL       string
 	TesseractPath    string
+	HEIFConvertPath  string
 	MinConfidence    float64
 }
 
@@ -64,6 +74,9 @@ func NewService(cfg ServiceConfig) (*Service, error) {
 		tesseractPath = "tesseract"
 	}
 	s.tesseract = NewTesseractClient(tesseractPath)
+	if cfg.HEIFConvertPath != "" {
+		s.tesseract.heifConvertPath = cfg.HEIFConvertPath
+	}
 
 	return s, nil
 }
@@ -202,6 +215,26 @@ func (s *Service) processWithFallback(ctx context.Context, reader io.ReadSeeker)
 	}, nil
 }
 
+// ProcessImage performs OCR on a JPEG, PNG or HEIC image with Tesseract;
+// HunyuanOCR only accepts PDFs
+func (s *Service) ProcessImage(ctx context.Context, data []byte, mimeType string) (*Result, error) {
+	if s.provider == ProviderNone {
+		return &Result{Provider: ProviderNone}, nil
+	}
+
+	result, err := s.tesseract.ProcessImage(ctx, data, mimeType)
+	if err != nil {
+		return nil, fmt.Errorf("tesseract OCR: %w", err)
+	}
+
+	return &Result{
+		Text:       result.Text,
+		Provider:   ProviderTesseract,
+		Confidence: result.Confidence,
+		PageTexts:  result.Pages,
+	}, nil
+}
+
 // ProcessBytes is a convenience wrapper that takes bytes directly
 func (s *Service) ProcessBytes(ctx context.Context, data []byte) (*Result, error) {
 	return s.Process(ctx, bytes.NewReader(data))
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"image"
+	_ "image/jpeg" // register the JPEG decoder for size checks
 	"image/png"
 	"os"
 	"os/exec"
@@ -16,8 +17,9 @@ import (
 
 // TesseractClient wraps Tesseract OCR
 type TesseractClient struct {
-	tesseractPath string
-	language      string
+	tesseractPath   string
+	heifConvertPath string
+	language        string
 }
 
 // TesseractResult contains the OCR result from Tesseract
@@ -33,8 +35,9 @@ func NewTesseractClient(tesseractPath string) *TesseractClient {
 		tesseractPath = "tesseract"
 	}
 	return &TesseractClient{
-		tesseractPath: tesseractPath,
-		language:      "deu", // German
+		tesseractPath:   tesseractPath,
+		heifConvertPath: "heif-convert",
+		language:        "deu", // German
 	}
 }
 
@@ -94,6 +97,83 @@ func (c *TesseractClient) ProcessPDF(ctx context.Context, pdfData []byte) (*Tess
 	}, nil
 }
 
+// MaxImagePixels limits the size of images passed to OCR; larger images are
+// most likely not document scans and would take minutes to process
+const MaxImagePixels = 100_000_000
+
+// ProcessImage runs OCR on a JPEG, PNG or HEIC image. HEIC, the format of
+// iPhone photos, is converted to PNG first since Tesseract cannot read it.
+func (c *TesseractClient) ProcessImage(ctx context.Context, data []byte, mimeType string) (*TesseractResult, error) {
+	tempDir, err := os.MkdirTemp("", "ocr-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	imgPath := filepath.Join(tempDir, "input.png")
+	switch mimeType {
+	case MIMEJPEG:
+		imgPath = filepath.Join(tempDir, "input.jpg")
+	case MIMEPNG:
+	case MIMEHEIC:
+		heicPath := filepath.Join(tempDir, "input.heic")
+		if err := os.WriteFile(heicPath, data, 0600); err != nil {
+			return nil, fmt.Errorf("write image: %w", err)
+		}
+		if err := c.convertHEIC(ctx, heicPath, imgPath); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	if mimeType != MIMEHEIC {
+		if err := checkImageSize(data); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(imgPath, data, 0600); err != nil {
+			return nil, fmt.Errorf("write image: %w", err)
+		}
+	}
+
+	text, conf, err := c.ocrImage(ctx, imgPath)
+	if err != nil {
+		return nil, err
+	}
+	return &TesseractResult{
+		Text:       text,
+		Pages:      []string{text},
+		Confidence: conf / 100.0,
+	}, nil
+}
+
+// checkImageSize rejects images whose dimensions exceed MaxImagePixels
+func checkImageSize(data []byte) error {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("decode image: %w", err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxImagePixels {
+		return fmt.Errorf("%w: image has %dx%d pixels", ErrInputTooLarge, cfg.Width, cfg.Height)
+	}
+	return nil
+}
+
+// convertHEIC converts a HEIC image to PNG with heif-convert from libheif
+func (c *TesseractClient) convertHEIC(ctx context.Context, src, dst string) error {
+	cmd := exec.CommandContext(ctx, c.heifConvertPath, src, dst)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("heif-convert failed: %w, stderr: %s", err, stderr.String())
+	}
+	png, err := os.ReadFile(dst)
+	if err != nil {
+		return fmt.Errorf("read converted image: %w", err)
+	}
+	return checkImageSize(png)
+}
+
 // pdfToImages converts PDF pages to PNG images
 func (c *TesseractClient) pdfToImages(pdfPath, outputDir string) ([]string, error) {
 	doc, err := fitz.New(pdfPath)
@@ -0,0 +1,230 @@
+package partner
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/uid"
+	"github.com/google/uuid"
+)
+
+// Handler handles business partner HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new partner handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers partner routes. Partners are kept by any user of
+// the tenant; the FinanzOnline account of the re-validation is set by admins.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/partners", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("POST /api/v1/partners", requireAuth(http.HandlerFunc(h.Create)))
+	router.Handle("GET /api/v1/partners/uid-revalidation", requireAuth(http.HandlerFunc(h.GetSettings)))
+	router.Handle("PUT /api/v1/partners/uid-revalidation", requireAuth(requireAdmin(http.HandlerFunc(h.UpdateSettings))))
+	router.Handle("GET /api/v1/partners/{id}", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("PUT /api/v1/partners/{id}", requireAuth(http.HandlerFunc(h.Update)))
+	router.Handle("DELETE /api/v1/partners/{id}", requireAuth(http.HandlerFunc(h.Delete)))
+}
+
+// List handles GET /api/v1/partners
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	filter := ListFilter{TenantID: tenantID, Limit: 50}
+	query := r.URL.Query()
+	if kind := query.Get("kind"); kind != "" {
+		if kind != KindCustomer && kind != KindSupplier {
+			api.BadRequest(w, "kind must be customer or supplier")
+			return
+		}
+		filter.Kind = kind
+	}
+	if status := query.Get("uid_status"); status != "" {
+		if status != UIDUnchecked && status != UIDValid && status != UIDInvalid {
+			api.BadRequest(w, "uid_status must be unchecked, valid or invalid")
+			return
+		}
+		filter.UIDStatus = status
+	}
+	filter.Search = strings.TrimSpace(query.Get("search"))
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	partners, total, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if partners == nil {
+		partners = []*Partner{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  partners,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// Create handles POST /api/v1/partners
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var input Input
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	partner, err := h.service.Create(r.Context(), tenantID, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, partner)
+}
+
+// Get handles GET /api/v1/partners/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	partner, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, partner)
+}
+
+// Update handles PUT /api/v1/partners/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var input Input
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	partner, err := h.service.Update(r.Context(), tenantID, id, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, partner)
+}
+
+// Delete handles DELETE /api/v1/partners/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), tenantID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSettings handles GET /api/v1/partners/uid-revalidation
+func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.service.GetSettings(r.Context(), tenantID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, settings)
+}
+
+// UpdateSettings handles PUT /api/v1/partners/uid-revalidation
+func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var input SettingsInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.AccountID == uuid.Nil {
+		api.BadRequest(w, "account_id is required")
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(r.Context(), tenantID, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, settings)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrPartnerNotFound):
+		api.NotFound(w, "partner not found")
+	case errors.Is(err, ErrSettingsNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, uid.ErrAccountNotFound):
+		api.BadRequest(w, "account not found")
+	case errors.Is(err, ErrDuplicateUID):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, ErrNameRequired), errors.Is(err, ErrInvalidKind), errors.Is(err, ErrInvalidUID),
+		errors.Is(err, ErrInvalidCountry), errors.Is(err, ErrInvalidLevel):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	default:
+		api.InternalError(w)
+	}
+}
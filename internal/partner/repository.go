@@ -0,0 +1,352 @@
+package partner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles partner database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new partner repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const partnerColumns = `id, tenant_id, name, kind, uid, street, post_code, city, country, email, notes,
+	uid_status, uid_checked_at, uid_invalid_since, uid_validation_id, created_at, updated_at`
+
+// scanPartner scans partnerColumns, followed by extra columns into extra
+func scanPartner(row pgx.Row, extra ...interface{}) (*Partner, error) {
+	var p Partner
+	dest := []interface{}{&p.ID, &p.TenantID, &p.Name, &p.Kind, &p.UID, &p.Street, &p.PostCode, &p.City,
+		&p.Country, &p.Email, &p.Notes, &p.UIDStatus, &p.UIDCheckedAt, &p.UIDInvalidSince,
+		&p.UIDValidationID, &p.CreatedAt, &p.UpdatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func collectPartners(rows pgx.Rows) ([]*Partner, error) {
+	defer rows.Close()
+	var partners []*Partner
+	for rows.Next() {
+		p, err := scanPartner(rows)
+		if err != nil {
+			return nil, err
+		}
+		partners = append(partners, p)
+	}
+	return partners, rows.Err()
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// Create inserts a partner
+func (r *Repository) Create(ctx context.Context, p *Partner) error {
+	saved, err := scanPartner(r.db.QueryRow(ctx, `
+		INSERT INTO business_partners (tenant_id, name, kind, uid, street, post_code, city, country, email, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING `+partnerColumns,
+		p.TenantID, p.Name, p.Kind, p.UID, p.Street, p.PostCode, p.City, p.Country, p.Email, p.Notes))
+	if isUniqueViolation(err) {
+		return ErrDuplicateUID
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create partner: %w", err)
+	}
+	*p = *saved
+	return nil
+}
+
+// Update replaces the master data of a partner. A changed UID starts over
+// unchecked.
+func (r *Repository) Update(ctx context.Context, p *Partner) error {
+	saved, err := scanPartner(r.db.QueryRow(ctx, `
+		UPDATE business_partners SET
+			name = $3, kind = $4, street = $6, post_code = $7, city = $8, country = $9, email = $10, notes = $11,
+			uid_status = CASE WHEN uid IS NOT DISTINCT FROM $5 THEN uid_status ELSE 'unchecked' END,
+			uid_checked_at = CASE WHEN uid IS NOT DISTINCT FROM $5 THEN uid_checked_at END,
+			uid_invalid_since = CASE WHEN uid IS NOT DISTINCT FROM $5 THEN uid_invalid_since END,
+			uid_validation_id = CASE WHEN uid IS NOT DISTINCT FROM $5 THEN uid_validation_id END,
+			uid = $5,
+			updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2
+		RETURNING `+partnerColumns,
+		p.ID, p.TenantID, p.Name, p.Kind, p.UID, p.Street, p.PostCode, p.City, p.Country, p.Email, p.Notes))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrPartnerNotFound
+	}
+	if isUniqueViolation(err) {
+		return ErrDuplicateUID
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update partner: %w", err)
+	}
+	*p = *saved
+	return nil
+}
+
+// Get returns a partner of a tenant
+func (r *Repository) Get(ctx context.Context, tenantID, id uuid.UUID) (*Partner, error) {
+	p, err := scanPartner(r.db.QueryRow(ctx,
+		`SELECT `+partnerColumns+` FROM business_partners WHERE id = $1 AND tenant_id = $2`, id, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPartnerNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partner: %w", err)
+	}
+	return p, nil
+}
+
+// GetByUID returns the partner of a tenant with a UID number
+func (r *Repository) GetByUID(ctx context.Context, tenantID uuid.UUID, uid string) (*Partner, error) {
+	p, err := scanPartner(r.db.QueryRow(ctx,
+		`SELECT `+partnerColumns+` FROM business_partners WHERE tenant_id = $1 AND uid = $2`, tenantID, uid))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPartnerNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partner: %w", err)
+	}
+	return p, nil
+}
+
+// List returns the partners of a tenant by name
+func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Partner, int, error) {
+	where := "tenant_id = $1"
+	args := []interface{}{filter.TenantID}
+	if filter.Kind != "" {
+		// A partner of both kinds is listed as customer and as supplier
+		args = append(args, filter.Kind)
+		where += fmt.Sprintf(" AND (kind = $%d OR kind = 'both')", len(args))
+	}
+	if filter.UIDStatus != "" {
+		args = append(args, filter.UIDStatus)
+		where += fmt.Sprintf(" AND uid IS NOT NULL AND uid_status = $%d", len(args))
+	}
+	if filter.Search != "" {
+		args = append(args, "%"+filter.Search+"%")
+		where += fmt.Sprintf(" AND (name ILIKE $%d OR uid ILIKE $%d)", len(args), len(args))
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM business_partners WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count partners: %w", err)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT %s FROM business_partners
+		WHERE %s
+		ORDER BY name, id
+		LIMIT $%d OFFSET $%d`, partnerColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list partners: %w", err)
+	}
+	partners, err := collectPartners(rows)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list partners: %w", err)
+	}
+	return partners, total, nil
+}
+
+// Delete removes a partner
+func (r *Repository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM business_partners WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete partner: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPartnerNotFound
+	}
+	return nil
+}
+
+// DueUIDs returns up to limit UIDs of a tenant's partners not confirmed
+// since since, those never checked or checked longest ago first
+func (r *Repository) DueUIDs(ctx context.Context, tenantID uuid.UUID, since time.Time, limit int) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT uid FROM business_partners
+		WHERE tenant_id = $1 AND uid IS NOT NULL AND (uid_checked_at IS NULL OR uid_checked_at < $2)
+		ORDER BY uid_checked_at NULLS FIRST, uid
+		LIMIT $3`, tenantID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due UIDs: %w", err)
+	}
+	defer rows.Close()
+
+	var uids []string
+	for rows.Next() {
+		var uid string
+		if err := rows.Scan(&uid); err != nil {
+			return nil, err
+		}
+		uids = append(uids, uid)
+	}
+	return uids, rows.Err()
+}
+
+// DueTenants returns the tenants with re-validation enabled that have a
+// re-validation in progress or partner UIDs not confirmed since since
+func (r *Repository) DueTenants(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT s.tenant_id FROM partner_uid_settings s
+		WHERE s.enabled AND (s.batch_id IS NOT NULL OR EXISTS (
+			SELECT 1 FROM business_partners p
+			WHERE p.tenant_id = s.tenant_id AND p.uid IS NOT NULL
+				AND (p.uid_checked_at IS NULL OR p.uid_checked_at < $1)))
+		ORDER BY s.tenant_id`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, id)
+	}
+	return tenants, rows.Err()
+}
+
+// ApplyValidation records the confirmation of a UID on the tenant's partner
+// with it and returns the partner if its UID turned invalid
+func (r *Repository) ApplyValidation(ctx context.Context, tenantID uuid.UUID, uid string, validationID uuid.UUID, valid bool, checkedAt time.Time) (*Partner, bool, error) {
+	status := UIDValid
+	if !valid {
+		status = UIDInvalid
+	}
+	var previous string
+	p, err := scanPartner(r.db.QueryRow(ctx, `
+		WITH old AS (
+			SELECT id AS old_id, uid_status AS old_status
+			FROM business_partners WHERE tenant_id = $1 AND uid = $2
+			FOR UPDATE
+		)
+		UPDATE business_partners SET
+			uid_status = $3,
+			uid_checked_at = $4,
+			uid_validation_id = $5,
+			uid_invalid_since = CASE
+				WHEN $3 = 'valid' THEN NULL
+				WHEN old_status = 'invalid' THEN uid_invalid_since
+				ELSE $4 END,
+			updated_at = NOW()
+		FROM old
+		WHERE id = old_id
+		RETURNING `+partnerColumns+`, old_status`,
+		tenantID, uid, status, checkedAt, validationID), &previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil // Partner removed while the batch ran
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to update partner UID: %w", err)
+	}
+	return p, status == UIDInvalid && previous != UIDInvalid, nil
+}
+
+const settingsColumns = `tenant_id, account_id, level, enabled, batch_id, last_run_at, updated_at`
+
+func scanSettings(row pgx.Row) (*Settings, error) {
+	var s Settings
+	if err := row.Scan(&s.TenantID, &s.AccountID, &s.Level, &s.Enabled, &s.BatchID, &s.LastRunAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetSettings returns the re-validation settings of a tenant
+func (r *Repository) GetSettings(ctx context.Context, tenantID uuid.UUID) (*Settings, error) {
+	s, err := scanSettings(r.db.QueryRow(ctx,
+		`SELECT `+settingsColumns+` FROM partner_uid_settings WHERE tenant_id = $1`, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSettingsNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partner UID settings: %w", err)
+	}
+	return s, nil
+}
+
+// SaveSettings stores the re-validation settings of a tenant
+func (r *Repository) SaveSettings(ctx context.Context, s *Settings) error {
+	saved, err := scanSettings(r.db.QueryRow(ctx, `
+		INSERT INTO partner_uid_settings (tenant_id, account_id, level, enabled)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			account_id = EXCLUDED.account_id,
+			level = EXCLUDED.level,
+			enabled = EXCLUDED.enabled,
+			updated_at = NOW()
+		RETURNING `+settingsColumns,
+		s.TenantID, s.AccountID, s.Level, s.Enabled))
+	if err != nil {
+		return fmt.Errorf("failed to save partner UID settings: %w", err)
+	}
+	*s = *saved
+	return nil
+}
+
+// SetBatch records the UID batch of a re-validation in progress; nil ends
+// the re-validation
+func (r *Repository) SetBatch(ctx context.Context, tenantID uuid.UUID, batchID *uuid.UUID) error {
+	query := `UPDATE partner_uid_settings SET batch_id = $2 WHERE tenant_id = $1`
+	if batchID == nil {
+		query = `UPDATE partner_uid_settings SET batch_id = NULL, last_run_at = NOW() WHERE tenant_id = $1`
+		_, err := r.db.Exec(ctx, query, tenantID)
+		if err != nil {
+			return fmt.Errorf("failed to finish partner UID re-validation: %w", err)
+		}
+		return nil
+	}
+	if _, err := r.db.Exec(ctx, query, tenantID, batchID); err != nil {
+		return fmt.Errorf("failed to start partner UID re-validation: %w", err)
+	}
+	return nil
+}
+
+// Recipient is a tenant admin notified of invalid partner UIDs
+type Recipient struct {
+	Name  string
+	Email string
+}
+
+// tenantAdmins returns the active owners and admins of a tenant
+func (r *Repository) tenantAdmins(ctx context.Context, tenantID uuid.UUID) ([]Recipient, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT name, email FROM users
+		WHERE tenant_id = $1 AND is_active AND role IN ('owner', 'admin')
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list tenant admins: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []Recipient
+	for rows.Next() {
+		var rc Recipient
+		if err := rows.Scan(&rc.Name, &rc.Email); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, rc)
+	}
+	return recipients, rows.Err()
+}
@@ -0,0 +1,371 @@
+package partner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/timezone"
+	"austrian-business-infrastructure/internal/uid"
+	"austrian-business-infrastructure/internal/webhook"
+	"github.com/google/uuid"
+)
+
+// BatchName names the UID batches of the re-validation in the UID batch list
+const BatchName = "Geschäftspartner"
+
+// Notifier mails the tenant admins about invalid partner UIDs;
+// email.Service implements it
+type Notifier interface {
+	SendPartnerUIDInvalid(ctx context.Context, to string, params email.PartnerUIDInvalidParams) error
+}
+
+// EventPublisher delivers webhook events; webhook.Service implements it
+type EventPublisher interface {
+	TriggerEvent(ctx context.Context, tenantID uuid.UUID, eventType string, data interface{}) error
+}
+
+// Service handles business partner logic
+type Service struct {
+	repo     *Repository
+	uids     *uid.Service
+	notifier Notifier
+	events   EventPublisher
+	appURL   string
+	now      func() time.Time
+}
+
+// NewService creates a new partner service. The UID service queries
+// FinanzOnline for the re-validation.
+func NewService(repo *Repository, uids *uid.Service) *Service {
+	return &Service{repo: repo, uids: uids, now: time.Now}
+}
+
+// SetNotifier enables the mail to tenant admins about invalid partner UIDs
+func (s *Service) SetNotifier(notifier Notifier, appURL string) {
+	s.notifier = notifier
+	s.appURL = strings.TrimSuffix(appURL, "/")
+}
+
+// SetEvents enables the partner_uid_invalid webhook event
+func (s *Service) SetEvents(events EventPublisher) {
+	s.events = events
+}
+
+// QuarterStart returns the first day of the calendar quarter of t, in the
+// location of t
+func QuarterStart(t time.Time) time.Time {
+	month := t.Month() - (t.Month()-1)%3
+	return time.Date(t.Year(), month, 1, 0, 0, 0, 0, t.Location())
+}
+
+// NormalizeUID returns a UID number in upper case without spaces
+func NormalizeUID(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
+
+// Create adds a partner
+func (s *Service) Create(ctx context.Context, tenantID uuid.UUID, input *Input) (*Partner, error) {
+	p := &Partner{TenantID: tenantID}
+	if err := apply(p, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Update replaces the master data of a partner
+func (s *Service) Update(ctx context.Context, tenantID, id uuid.UUID, input *Input) (*Partner, error) {
+	p := &Partner{ID: id, TenantID: tenantID}
+	if err := apply(p, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// apply validates the input and copies it to p
+func apply(p *Partner, input *Input) error {
+	p.Name = strings.TrimSpace(input.Name)
+	if p.Name == "" {
+		return ErrNameRequired
+	}
+	p.Kind = input.Kind
+	if p.Kind == "" {
+		p.Kind = KindCustomer
+	}
+	if p.Kind != KindCustomer && p.Kind != KindSupplier && p.Kind != KindBoth {
+		return ErrInvalidKind
+	}
+
+	p.UID = nil
+	if input.UID != nil {
+		if number := NormalizeUID(*input.UID); number != "" {
+			if !fonws.ValidateUIDFormat(number).Valid {
+				return ErrInvalidUID
+			}
+			p.UID = &number
+		}
+	}
+	p.Country = nil
+	if input.Country != nil {
+		if country := strings.ToUpper(strings.TrimSpace(*input.Country)); country != "" {
+			if len(country) != 2 {
+				return ErrInvalidCountry
+			}
+			p.Country = &country
+		}
+	}
+
+	p.Street = trimmed(input.Street)
+	p.PostCode = trimmed(input.PostCode)
+	p.City = trimmed(input.City)
+	p.Email = trimmed(input.Email)
+	p.Notes = trimmed(input.Notes)
+	return nil
+}
+
+// trimmed returns s without surrounding space, or nil if that is empty
+func trimmed(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+// Get returns a partner
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Partner, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// List returns the partners of a tenant
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]*Partner, int, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Delete removes a partner
+func (s *Service) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+// UIDInvalid reports whether a UID number belongs to a partner of the
+// tenant whose UID was found invalid. Unknown UIDs are not invalid.
+func (s *Service) UIDInvalid(ctx context.Context, tenantID uuid.UUID, number string) (bool, error) {
+	p, err := s.repo.GetByUID(ctx, tenantID, NormalizeUID(number))
+	if errors.Is(err, ErrPartnerNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return p.UIDStatus == UIDInvalid, nil
+}
+
+// GetSettings returns the re-validation settings of a tenant
+func (s *Service) GetSettings(ctx context.Context, tenantID uuid.UUID) (*Settings, error) {
+	return s.repo.GetSettings(ctx, tenantID)
+}
+
+// UpdateSettings sets the FinanzOnline account and level the partner UIDs
+// of a tenant are re-validated with
+func (s *Service) UpdateSettings(ctx context.Context, tenantID uuid.UUID, input *SettingsInput) (*Settings, error) {
+	if input.Level == 0 {
+		input.Level = uid.Level1
+	}
+	if input.Level != uid.Level1 && input.Level != uid.Level2 {
+		return nil, ErrInvalidLevel
+	}
+	if err := s.uids.CheckAccount(ctx, tenantID, input.AccountID); err != nil {
+		return nil, err
+	}
+
+	settings := &Settings{
+		TenantID:  tenantID,
+		AccountID: input.AccountID,
+		Level:     input.Level,
+		Enabled:   input.Enabled == nil || *input.Enabled,
+	}
+	if err := s.repo.SaveSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// DueTenants returns the tenants whose partner UIDs are due for the
+// quarterly re-validation
+func (s *Service) DueTenants(ctx context.Context) ([]uuid.UUID, error) {
+	return s.repo.DueTenants(ctx, s.quarterStart())
+}
+
+// quarterStart returns the start of the current quarter in Austria
+func (s *Service) quarterStart() time.Time {
+	return QuarterStart(s.now().In(timezone.Vienna))
+}
+
+// Revalidate confirms the partner UIDs of a tenant not confirmed this
+// quarter with FinanzOnline, as many as the daily limit allows, and flags
+// the partners whose UID is invalid. A re-validation interrupted by an
+// error continues with its UID batch on the next call; the UIDs left over
+// by the daily limit follow on the next day.
+func (s *Service) Revalidate(ctx context.Context, tenantID uuid.UUID) (*RevalidationResult, error) {
+	settings, err := s.repo.GetSettings(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled {
+		return nil, ErrRevalidationPaused
+	}
+
+	result := &RevalidationResult{BatchID: settings.BatchID}
+	if result.BatchID == nil {
+		remaining, err := s.uids.RemainingToday(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if remaining == 0 {
+			return result, nil
+		}
+		numbers, err := s.repo.DueUIDs(ctx, tenantID, s.quarterStart(), min(remaining, uid.MaxBatchSize))
+		if err != nil {
+			return nil, err
+		}
+		if len(numbers) == 0 {
+			return result, nil
+		}
+
+		batch, err := s.uids.CreateListBatch(ctx, tenantID, settings.AccountID, settings.Level, BatchName, numbers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create UID batch: %w", err)
+		}
+		if err := s.repo.SetBatch(ctx, tenantID, &batch.ID); err != nil {
+			return nil, err
+		}
+		result.BatchID = &batch.ID
+	}
+
+	if _, err := s.uids.ProcessBatch(ctx, tenantID, *result.BatchID); err != nil {
+		if errors.Is(err, uid.ErrBatchNotFound) {
+			return result, s.repo.SetBatch(ctx, tenantID, nil)
+		}
+		return nil, err
+	}
+	if err := s.applyBatch(ctx, tenantID, result); err != nil {
+		return nil, err
+	}
+	return result, s.repo.SetBatch(ctx, tenantID, nil)
+}
+
+// applyBatch records the confirmations of the result's UID batch on the
+// partners
+func (s *Service) applyBatch(ctx context.Context, tenantID uuid.UUID, result *RevalidationResult) error {
+	_, items, err := s.uids.BatchItems(ctx, *result.BatchID, tenantID)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		v := item.Validation
+		if v == nil {
+			continue // Not queried; still due
+		}
+		p, flagged, err := s.repo.ApplyValidation(ctx, tenantID, item.UID, v.ID, v.Valid, v.ValidatedAt)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			continue
+		}
+		result.Checked++
+		if v.Valid {
+			result.Valid++
+		} else {
+			result.Invalid++
+		}
+		if flagged {
+			result.Flagged = append(result.Flagged, p)
+		}
+	}
+	return nil
+}
+
+// AbortRevalidation fails the UID batch of a re-validation that cannot be
+// completed and records the confirmations obtained so far. The partners it
+// did not confirm are due again.
+func (s *Service) AbortRevalidation(ctx context.Context, tenantID uuid.UUID, reason string) (*RevalidationResult, error) {
+	settings, err := s.repo.GetSettings(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	result := &RevalidationResult{BatchID: settings.BatchID}
+	if result.BatchID == nil {
+		return result, nil
+	}
+
+	if _, err := s.uids.FailBatch(ctx, tenantID, *result.BatchID, reason); err != nil {
+		if errors.Is(err, uid.ErrBatchNotFound) {
+			return result, s.repo.SetBatch(ctx, tenantID, nil)
+		}
+		return nil, err
+	}
+	if err := s.applyBatch(ctx, tenantID, result); err != nil {
+		return nil, err
+	}
+	return result, s.repo.SetBatch(ctx, tenantID, nil)
+}
+
+// NotifyInvalid announces partners whose UID turned invalid by webhook and
+// mails the tenant admins
+func (s *Service) NotifyInvalid(ctx context.Context, tenantID uuid.UUID, flagged []*Partner) error {
+	if len(flagged) == 0 {
+		return nil
+	}
+
+	var errs []error
+	if s.events != nil {
+		event := InvalidUIDEvent{Partners: make([]InvalidUID, 0, len(flagged))}
+		for _, p := range flagged {
+			event.Partners = append(event.Partners, InvalidUID{
+				ID:           p.ID,
+				Name:         p.Name,
+				UID:          *p.UID,
+				CheckedAt:    p.UIDCheckedAt,
+				ValidationID: p.UIDValidationID,
+			})
+		}
+		if err := s.events.TriggerEvent(ctx, tenantID, webhook.EventPartnerUIDInvalid, event); err != nil {
+			errs = append(errs, fmt.Errorf("failed to trigger webhook: %w", err))
+		}
+	}
+
+	if s.notifier != nil {
+		recipients, err := s.repo.tenantAdmins(ctx, tenantID)
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		params := email.PartnerUIDInvalidParams{TenantID: &tenantID}
+		for _, p := range flagged {
+			params.Partners = append(params.Partners, email.PartnerUID{Name: p.Name, UID: *p.UID})
+		}
+		if s.appURL != "" {
+			params.PartnersURL = s.appURL + "/partners?uid_status=" + UIDInvalid
+		}
+		for _, rc := range recipients {
+			params.RecipientName = rc.Name
+			if err := s.notifier.SendPartnerUIDInvalid(ctx, rc.Email, params); err != nil {
+				errs = append(errs, fmt.Errorf("failed to mail %s: %w", rc.Email, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,125 @@
+// Package partner keeps the business partners (Stammdaten) of a tenant:
+// customers and suppliers with their UID numbers. The UIDs are re-validated
+// with the FinanzOnline UID-Bestätigungsservice once per quarter; a UID found
+// invalid is flagged, announced by webhook and mail, and blocks reverse
+// charge invoices to it.
+package partner
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPartnerNotFound    = errors.New("partner not found")
+	ErrNameRequired       = errors.New("name is required")
+	ErrInvalidKind        = errors.New("kind must be customer, supplier or both")
+	ErrInvalidUID         = errors.New("invalid UID number")
+	ErrInvalidCountry     = errors.New("country must be a two-letter ISO code")
+	ErrDuplicateUID       = errors.New("a partner with this UID number already exists")
+	ErrInvalidLevel       = errors.New("level must be 1 or 2")
+	ErrSettingsNotFound   = errors.New("UID re-validation is not configured")
+	ErrRevalidationPaused = errors.New("UID re-validation is disabled")
+)
+
+// Partner kinds
+const (
+	KindCustomer = "customer"
+	KindSupplier = "supplier"
+	KindBoth     = "both"
+)
+
+// UID states of a partner
+const (
+	UIDUnchecked = "unchecked"
+	UIDValid     = "valid"
+	UIDInvalid   = "invalid"
+)
+
+// Partner is a customer or supplier of a tenant
+type Partner struct {
+	ID              uuid.UUID  `json:"id"`
+	TenantID        uuid.UUID  `json:"tenant_id"`
+	Name            string     `json:"name"`
+	Kind            string     `json:"kind"`
+	UID             *string    `json:"uid,omitempty"`
+	Street          *string    `json:"street,omitempty"`
+	PostCode        *string    `json:"post_code,omitempty"`
+	City            *string    `json:"city,omitempty"`
+	Country         *string    `json:"country,omitempty"`
+	Email           *string    `json:"email,omitempty"`
+	Notes           *string    `json:"notes,omitempty"`
+	UIDStatus       string     `json:"uid_status"`
+	UIDCheckedAt    *time.Time `json:"uid_checked_at,omitempty"`
+	UIDInvalidSince *time.Time `json:"uid_invalid_since,omitempty"`
+	UIDValidationID *uuid.UUID `json:"uid_validation_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Input creates or replaces a partner
+type Input struct {
+	Name     string  `json:"name"`
+	Kind     string  `json:"kind"`
+	UID      *string `json:"uid"`
+	Street   *string `json:"street"`
+	PostCode *string `json:"post_code"`
+	City     *string `json:"city"`
+	Country  *string `json:"country"`
+	Email    *string `json:"email"`
+	Notes    *string `json:"notes"`
+}
+
+// ListFilter filters partners
+type ListFilter struct {
+	TenantID  uuid.UUID
+	Kind      string
+	UIDStatus string
+	Search    string // In name and UID
+	Limit     int
+	Offset    int
+}
+
+// Settings configure the re-validation of a tenant's partner UIDs
+type Settings struct {
+	TenantID  uuid.UUID  `json:"tenant_id"`
+	AccountID uuid.UUID  `json:"account_id"`
+	Level     int        `json:"level"`
+	Enabled   bool       `json:"enabled"`
+	BatchID   *uuid.UUID `json:"batch_id,omitempty"` // Re-validation in progress
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// SettingsInput updates the re-validation settings
+type SettingsInput struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Level     int       `json:"level"`
+	Enabled   *bool     `json:"enabled"`
+}
+
+// RevalidationResult is the outcome of re-validating a tenant's partners
+type RevalidationResult struct {
+	BatchID *uuid.UUID `json:"batch_id,omitempty"`
+	Checked int        `json:"checked"`
+	Valid   int        `json:"valid"`
+	Invalid int        `json:"invalid"`
+	// Flagged are the partners whose UID turned invalid in this run
+	Flagged []*Partner `json:"flagged,omitempty"`
+}
+
+// InvalidUIDEvent is the data of the partner_uid_invalid webhook event
+type InvalidUIDEvent struct {
+	Partners []InvalidUID `json:"partners"`
+}
+
+// InvalidUID is a partner in the partner_uid_invalid event
+type InvalidUID struct {
+	ID           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	UID          string     `json:"uid"`
+	CheckedAt    *time.Time `json:"checked_at,omitempty"`
+	ValidationID *uuid.UUID `json:"validation_id,omitempty"`
+}
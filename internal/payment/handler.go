@@ -2,11 +2,14 @@ package payment
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/pkg/money"
 	"github.com/google/uuid"
 )
 
@@ -28,7 +31,10 @@ func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin f
 	router.Handle("POST /api/v1/payments/batches/import", requireAuth(requireAdmin(http.HandlerFunc(h.ImportCSV))))
 	router.Handle("POST /api/v1/payments/statements", requireAuth(requireAdmin(http.HandlerFunc(h.ImportStatement))))
 	router.Handle("DELETE /api/v1/payments/statements/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.DeleteStatement))))
+	router.Handle("POST /api/v1/payments/statements/{id}/reconcile", requireAuth(requireAdmin(http.HandlerFunc(h.ReconcileStatement))))
 	router.Handle("POST /api/v1/payments/transactions/{id}/match", requireAuth(requireAdmin(http.HandlerFunc(h.MatchTransaction))))
+	router.Handle("POST /api/v1/payments/transactions/{id}/unmatch", requireAuth(requireAdmin(http.HandlerFunc(h.UnmatchTransaction))))
+	router.Handle("POST /api/v1/payments/transactions/{id}/ignore", requireAuth(requireAdmin(http.HandlerFunc(h.IgnoreTransaction))))
 
 	// Member access: read-only, validation, and generate operations
 	router.Handle("GET /api/v1/payments/batches", requireAuth(http.HandlerFunc(h.ListBatches)))
@@ -38,6 +44,8 @@ func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin f
 	router.Handle("GET /api/v1/payments/batches/{id}/xml", requireAuth(http.HandlerFunc(h.GetXML)))
 	router.Handle("GET /api/v1/payments/statements", requireAuth(http.HandlerFunc(h.ListStatements)))
 	router.Handle("GET /api/v1/payments/statements/{id}", requireAuth(http.HandlerFunc(h.GetStatement)))
+	router.Handle("GET /api/v1/payments/transactions/{id}/suggestions", requireAuth(http.HandlerFunc(h.GetSuggestions)))
+	router.Handle("GET /api/v1/payments/reconciliation", requireAuth(http.HandlerFunc(h.ListReconciliation)))
 }
 
 // CreateBatch handles POST /api/v1/payments/batches
@@ -104,6 +112,12 @@ func (h *Handler) ListBatches(w http.ResponseWriter, r *http.Request) {
 		filter.Status = &status
 	}
 
+	if projectIDStr := r.URL.Query().Get("project_id"); projectIDStr != "" {
+		if projectID, err := uuid.Parse(projectIDStr); err == nil {
+			filter.ProjectID = &projectID
+		}
+	}
+
 	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
 		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
 			filter.Limit = limit
@@ -314,7 +328,8 @@ func (h *Handler) ImportCSV(w http.ResponseWriter, r *http.Request) {
 	api.JSONResponse(w, http.StatusCreated, h.toBatchResponse(batch, nil))
 }
 
-// ImportStatement handles POST /api/v1/payments/statements
+// ImportStatement handles POST /api/v1/payments/statements. The file may be
+// camt.053 XML or MT940; its transactions are reconciled right away.
 func (h *Handler) ImportStatement(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := h.getTenantID(r)
 	if err != nil {
@@ -335,19 +350,21 @@ func (h *Handler) ImportStatement(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	xmlData, err := io.ReadAll(file)
+	data, err := io.ReadAll(file)
 	if err != nil {
 		api.BadRequest(w, "failed to read file")
 		return
 	}
 
-	stmt, err := h.service.ImportBankStatement(r.Context(), tenantID, xmlData)
+	stmt, result, err := h.service.ImportBankStatement(r.Context(), tenantID, data)
 	if err != nil {
 		h.handleError(w, err)
 		return
 	}
 
-	api.JSONResponse(w, http.StatusCreated, h.toStatementResponse(stmt, nil))
+	resp := h.toStatementResponse(stmt, nil)
+	resp.Reconciliation = result
+	api.JSONResponse(w, http.StatusCreated, resp)
 }
 
 // ListStatements handles GET /api/v1/payments/statements
@@ -437,8 +454,37 @@ func (h *Handler) DeleteStatement(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// ReconcileStatement handles POST /api/v1/payments/statements/{id}/reconcile
+func (h *Handler) ReconcileStatement(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid statement ID")
+		return
+	}
+
+	result, err := h.service.Reconcile(r.Context(), tenantID, id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, result)
+}
+
 // MatchTransaction handles POST /api/v1/payments/transactions/{id}/match
 func (h *Handler) MatchTransaction(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
 	id, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
 		api.BadRequest(w, "invalid transaction ID")
@@ -454,12 +500,137 @@ func (h *Handler) MatchTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.MatchTransaction(r.Context(), id, input.PaymentID, input.InvoiceID); err != nil {
+	txn, err := h.service.MatchTransaction(r.Context(), tenantID, id, h.optionalUserID(r), input.PaymentID, input.InvoiceID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, h.toTransactionResponse(txn))
+}
+
+// UnmatchTransaction handles POST /api/v1/payments/transactions/{id}/unmatch
+func (h *Handler) UnmatchTransaction(w http.ResponseWriter, r *http.Request) {
+	h.unmatch(w, r, false)
+}
+
+// IgnoreTransaction handles POST /api/v1/payments/transactions/{id}/ignore
+func (h *Handler) IgnoreTransaction(w http.ResponseWriter, r *http.Request) {
+	h.unmatch(w, r, true)
+}
+
+func (h *Handler) unmatch(w http.ResponseWriter, r *http.Request, ignore bool) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid transaction ID")
+		return
+	}
+
+	txn, err := h.service.UnmatchTransaction(r.Context(), tenantID, id, h.optionalUserID(r), ignore)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, h.toTransactionResponse(txn))
+}
+
+// GetSuggestions handles GET /api/v1/payments/transactions/{id}/suggestions
+func (h *Handler) GetSuggestions(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid transaction ID")
+		return
+	}
+
+	suggestions, err := h.service.Suggestions(r.Context(), tenantID, id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	items := make([]SuggestionResponse, 0, len(suggestions))
+	for _, sg := range suggestions {
+		items = append(items, SuggestionResponse{
+			Kind:      sg.Kind,
+			ID:        sg.ID,
+			Reference: sg.Reference,
+			Name:      sg.Name,
+			IBAN:      sg.IBAN,
+			Amount:    money.EUR(sg.Amount),
+			Score:     sg.Score,
+			Reasons:   sg.Reasons,
+		})
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items": items,
+		"total": len(items),
+	})
+}
+
+// ListReconciliation handles GET /api/v1/payments/reconciliation
+func (h *Handler) ListReconciliation(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", ReconciliationOpen, ReconciliationPartial, ReconciliationPaid, ReconciliationOverpaid:
+	default:
+		api.BadRequest(w, "status must be one of open, partial, paid, overpaid")
+		return
+	}
+
+	invoices, err := h.service.ListReconciliation(r.Context(), tenantID, status)
+	if err != nil {
 		api.InternalError(w)
 		return
 	}
 
-	api.JSONResponse(w, http.StatusOK, map[string]string{"status": "matched"})
+	items := make([]ReconciliationResponse, 0, len(invoices))
+	for _, inv := range invoices {
+		resp := ReconciliationResponse{
+			InvoiceID:     inv.InvoiceID,
+			InvoiceNumber: inv.InvoiceNumber,
+			BuyerName:     inv.BuyerName,
+			IssueDate:     inv.IssueDate.Format("2006-01-02"),
+			Payable:       money.New(inv.Payable, inv.Currency),
+			Paid:          money.New(inv.Paid, inv.Currency),
+			Open:          money.New(inv.Payable-inv.Paid, inv.Currency),
+			Transactions:  inv.Transactions,
+			Status:        inv.Status,
+		}
+		if inv.DueDate != nil {
+			d := inv.DueDate.Format("2006-01-02")
+			resp.DueDate = &d
+		}
+		if inv.LastPaymentAt != nil {
+			d := inv.LastPaymentAt.Format("2006-01-02")
+			resp.LastPaymentAt = &d
+		}
+		items = append(items, resp)
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items": items,
+		"total": len(items),
+	})
 }
 
 // Helper methods
@@ -480,7 +651,20 @@ func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
 	return uuid.Parse(userIDStr)
 }
 
+// optionalUserID returns the user of the request, if any
+func (h *Handler) optionalUserID(r *http.Request) *uuid.UUID {
+	if id, err := h.getUserID(r); err == nil {
+		return &id
+	}
+	return nil
+}
+
 func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrInvalidStatement) {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
 	switch err {
 	case ErrBatchNotFound:
 		api.NotFound(w, "batch not found")
@@ -492,6 +676,12 @@ func (h *Handler) handleError(w http.ResponseWriter, err error) {
 		api.BadRequest(w, "batch must have at least one item")
 	case ErrInvalidBatchType:
 		api.BadRequest(w, "invalid batch type, must be 'pain.001' or 'pain.008'")
+	case ErrTransactionNotFound:
+		api.NotFound(w, "transaction not found")
+	case ErrProjectNotFound:
+		api.BadRequest(w, "project not found")
+	case ErrInvoiceNotFound, ErrPaymentNotFound, ErrMatchTarget:
+		api.BadRequest(w, err.Error())
 	default:
 		api.InternalError(w)
 	}
@@ -504,12 +694,13 @@ func (h *Handler) toBatchResponse(batch *Batch, items []*Item) *BatchResponse {
 		Type:             batch.Type,
 		DebtorName:       batch.DebtorName,
 		DebtorIBAN:       batch.DebtorIBAN,
+		ProjectID:        batch.ProjectID,
 		ItemCount:        batch.ItemCount,
-		TotalAmount:      float64(batch.TotalAmount) / 100,
+		TotalAmount:      money.EUR(batch.TotalAmount),
 		Status:           batch.Status,
 		ValidationErrors: batch.ValidationErrors,
 		HasXML:           batch.GeneratedAt != nil,
-		CreatedAt:        batch.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		CreatedAt:        batch.CreatedAt.UTC().Format(time.RFC3339),
 	}
 
 	if batch.ExecutionDate != nil {
@@ -517,11 +708,11 @@ func (h *Handler) toBatchResponse(batch *Batch, items []*Item) *BatchResponse {
 		resp.ExecutionDate = &d
 	}
 	if batch.GeneratedAt != nil {
-		d := batch.GeneratedAt.Format("2006-01-02T15:04:05Z")
+		d := batch.GeneratedAt.UTC().Format(time.RFC3339)
 		resp.GeneratedAt = &d
 	}
 	if batch.SentAt != nil {
-		d := batch.SentAt.Format("2006-01-02T15:04:05Z")
+		d := batch.SentAt.UTC().Format(time.RFC3339)
 		resp.SentAt = &d
 	}
 
@@ -531,7 +722,7 @@ func (h *Handler) toBatchResponse(batch *Batch, items []*Item) *BatchResponse {
 			itemResp := ItemResponse{
 				ID:           item.ID,
 				EndToEndID:   item.EndToEndID,
-				Amount:       float64(item.Amount) / 100,
+				Amount:       money.New(item.Amount, item.Currency),
 				Currency:     item.Currency,
 				CreditorName: item.CreditorName,
 				CreditorIBAN: item.CreditorIBAN,
@@ -556,28 +747,62 @@ type StatementResponse struct {
 	IBAN           string                `json:"iban"`
 	StatementID    string                `json:"statement_id"`
 	StatementDate  string                `json:"statement_date"`
-	OpeningBalance float64               `json:"opening_balance"`
-	ClosingBalance float64               `json:"closing_balance"`
+	OpeningBalance money.Money           `json:"opening_balance"`
+	ClosingBalance money.Money           `json:"closing_balance"`
 	EntryCount     int                   `json:"entry_count"`
 	ImportedAt     string                `json:"imported_at"`
+	Format         string                `json:"format"`
+	Reconciliation *ReconcileResult      `json:"reconciliation,omitempty"`
 	Transactions   []TransactionResponse `json:"transactions,omitempty"`
 }
 
 // TransactionResponse is the API response format for transactions
 type TransactionResponse struct {
-	ID               uuid.UUID  `json:"id"`
-	Amount           float64    `json:"amount"`
-	Currency         string     `json:"currency"`
-	CreditDebit      string     `json:"credit_debit"`
-	BookingDate      string     `json:"booking_date"`
-	ValueDate        *string    `json:"value_date,omitempty"`
-	Reference        *string    `json:"reference,omitempty"`
-	EndToEndID       *string    `json:"end_to_end_id,omitempty"`
-	RemittanceInfo   *string    `json:"remittance_info,omitempty"`
-	CounterpartyName *string    `json:"counterparty_name,omitempty"`
-	CounterpartyIBAN *string    `json:"counterparty_iban,omitempty"`
-	MatchedPaymentID *uuid.UUID `json:"matched_payment_id,omitempty"`
-	MatchedInvoiceID *uuid.UUID `json:"matched_invoice_id,omitempty"`
+	ID               uuid.UUID   `json:"id"`
+	Amount           money.Money `json:"amount"`
+	Currency         string      `json:"currency"`
+	CreditDebit      string      `json:"credit_debit"`
+	BookingDate      string      `json:"booking_date"`
+	ValueDate        *string     `json:"value_date,omitempty"`
+	Reference        *string     `json:"reference,omitempty"`
+	EndToEndID       *string     `json:"end_to_end_id,omitempty"`
+	RemittanceInfo   *string     `json:"remittance_info,omitempty"`
+	CounterpartyName *string     `json:"counterparty_name,omitempty"`
+	CounterpartyIBAN *string     `json:"counterparty_iban,omitempty"`
+	MatchedPaymentID *uuid.UUID  `json:"matched_payment_id,omitempty"`
+	MatchedInvoiceID *uuid.UUID  `json:"matched_invoice_id,omitempty"`
+	MatchStatus      string      `json:"match_status"`
+	MatchScore       *int        `json:"match_score,omitempty"`
+	MatchReason      *string     `json:"match_reason,omitempty"`
+	MatchedAt        *string     `json:"matched_at,omitempty"`
+}
+
+// SuggestionResponse is the API response format for match suggestions
+type SuggestionResponse struct {
+	Kind      string      `json:"kind"` // invoice or payment
+	ID        uuid.UUID   `json:"id"`
+	Reference string      `json:"reference"`
+	Name      string      `json:"name,omitempty"`
+	IBAN      string      `json:"iban,omitempty"`
+	Amount    money.Money `json:"amount"` // open amount
+	Score     int         `json:"score"`
+	Reasons   []string    `json:"reasons"`
+}
+
+// ReconciliationResponse is the API response format for the payment state
+// of an invoice
+type ReconciliationResponse struct {
+	InvoiceID     uuid.UUID   `json:"invoice_id"`
+	InvoiceNumber string      `json:"invoice_number"`
+	BuyerName     string      `json:"buyer_name"`
+	IssueDate     string      `json:"issue_date"`
+	DueDate       *string     `json:"due_date,omitempty"`
+	Payable       money.Money `json:"payable"`
+	Paid          money.Money `json:"paid"`
+	Open          money.Money `json:"open"`
+	Transactions  int         `json:"transactions"`
+	LastPaymentAt *string     `json:"last_payment_at,omitempty"`
+	Status        string      `json:"status"`
 }
 
 func (h *Handler) toStatementResponse(stmt *BankStatement, txns []*Transaction) *StatementResponse {
@@ -586,36 +811,48 @@ func (h *Handler) toStatementResponse(stmt *BankStatement, txns []*Transaction)
 		IBAN:           stmt.IBAN,
 		StatementID:    stmt.StatementID,
 		StatementDate:  stmt.StatementDate.Format("2006-01-02"),
-		OpeningBalance: float64(stmt.OpeningBalance) / 100,
-		ClosingBalance: float64(stmt.ClosingBalance) / 100,
+		OpeningBalance: money.EUR(stmt.OpeningBalance),
+		ClosingBalance: money.EUR(stmt.ClosingBalance),
 		EntryCount:     stmt.EntryCount,
-		ImportedAt:     stmt.ImportedAt.Format("2006-01-02T15:04:05Z"),
+		ImportedAt:     stmt.ImportedAt.UTC().Format(time.RFC3339),
+		Format:         stmt.Format,
 	}
 
 	if txns != nil {
 		resp.Transactions = make([]TransactionResponse, 0, len(txns))
 		for _, txn := range txns {
-			txnResp := TransactionResponse{
-				ID:               txn.ID,
-				Amount:           float64(txn.Amount) / 100,
-				Currency:         txn.Currency,
-				CreditDebit:      txn.CreditDebit,
-				BookingDate:      txn.BookingDate.Format("2006-01-02"),
-				Reference:        txn.Reference,
-				EndToEndID:       txn.EndToEndID,
-				RemittanceInfo:   txn.RemittanceInfo,
-				CounterpartyName: txn.CounterpartyName,
-				CounterpartyIBAN: txn.CounterpartyIBAN,
-				MatchedPaymentID: txn.MatchedPaymentID,
-				MatchedInvoiceID: txn.MatchedInvoiceID,
-			}
-			if txn.ValueDate != nil {
-				d := txn.ValueDate.Format("2006-01-02")
-				txnResp.ValueDate = &d
-			}
-			resp.Transactions = append(resp.Transactions, txnResp)
+			resp.Transactions = append(resp.Transactions, h.toTransactionResponse(txn))
 		}
 	}
 
 	return resp
 }
+
+func (h *Handler) toTransactionResponse(txn *Transaction) TransactionResponse {
+	resp := TransactionResponse{
+		ID:               txn.ID,
+		Amount:           money.New(txn.Amount, txn.Currency),
+		Currency:         txn.Currency,
+		CreditDebit:      txn.CreditDebit,
+		BookingDate:      txn.BookingDate.Format("2006-01-02"),
+		Reference:        txn.Reference,
+		EndToEndID:       txn.EndToEndID,
+		RemittanceInfo:   txn.RemittanceInfo,
+		CounterpartyName: txn.CounterpartyName,
+		CounterpartyIBAN: txn.CounterpartyIBAN,
+		MatchedPaymentID: txn.MatchedPaymentID,
+		MatchedInvoiceID: txn.MatchedInvoiceID,
+		MatchStatus:      txn.MatchStatus,
+		MatchScore:       txn.MatchScore,
+		MatchReason:      txn.MatchReason,
+	}
+	if txn.ValueDate != nil {
+		d := txn.ValueDate.Format("2006-01-02")
+		resp.ValueDate = &d
+	}
+	if txn.MatchedAt != nil {
+		d := txn.MatchedAt.UTC().Format(time.RFC3339)
+		resp.MatchedAt = &d
+	}
+	return resp
+}
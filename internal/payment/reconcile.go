@@ -0,0 +1,451 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/sepa"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrInvoiceNotFound     = errors.New("invoice not found or not open for payment")
+	ErrPaymentNotFound     = errors.New("payment not found")
+	ErrMatchTarget         = errors.New("exactly one of payment_id and invoice_id is required")
+	ErrInvalidStatement    = errors.New("invalid bank statement")
+)
+
+// Statement file formats
+const (
+	FormatCamt053 = "camt.053"
+	FormatMT940   = "mt940"
+)
+
+// Match states of a bank transaction
+const (
+	MatchUnmatched = "unmatched"
+	MatchSuggested = "suggested"
+	MatchMatched   = "matched"
+	MatchIgnored   = "ignored"
+)
+
+// Reconciliation states of an invoice
+const (
+	ReconciliationOpen     = "open"
+	ReconciliationPartial  = "partial"
+	ReconciliationPaid     = "paid"
+	ReconciliationOverpaid = "overpaid"
+)
+
+// Candidate kinds
+const (
+	CandidateInvoice = "invoice"
+	CandidatePayment = "payment"
+)
+
+// Score thresholds: a unique best candidate from MatchThreshold on is
+// matched automatically, one from SuggestThreshold on is only suggested
+const (
+	MatchThreshold   = 80
+	SuggestThreshold = 50
+)
+
+// Candidate is an open invoice or a payment of a generated batch a bank
+// transaction can be matched to
+type Candidate struct {
+	Kind       string    `json:"kind"`
+	ID         uuid.UUID `json:"id"`
+	Reference  string    `json:"reference"`               // invoice number or end-to-end ID
+	EndToEndID string    `json:"end_to_end_id,omitempty"` // payments only
+	Amount     int64     `json:"amount"`                  // open amount in cents
+	IBAN       string    `json:"iban,omitempty"`          // counterparty IBAN, if known
+	Name       string    `json:"name,omitempty"`
+
+	// Credit is true if the money comes in: invoices and direct debits
+	Credit bool `json:"credit"`
+}
+
+// Suggestion is a candidate with its score for a transaction
+type Suggestion struct {
+	Candidate
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// ReconcileResult sums up an automatic reconciliation run
+type ReconcileResult struct {
+	Matched   int `json:"matched"`
+	Suggested int `json:"suggested"`
+	Unmatched int `json:"unmatched"`
+}
+
+// InvoiceReconciliation is the payment state of an invoice
+type InvoiceReconciliation struct {
+	InvoiceID     uuid.UUID  `json:"invoice_id"`
+	InvoiceNumber string     `json:"invoice_number"`
+	BuyerName     string     `json:"buyer_name"`
+	IssueDate     time.Time  `json:"issue_date"`
+	DueDate       *time.Time `json:"due_date,omitempty"`
+	Currency      string     `json:"currency"`
+	Payable       int64      `json:"payable"` // In cents
+	Paid          int64      `json:"paid"`    // In cents
+	Transactions  int        `json:"transactions"`
+	LastPaymentAt *time.Time `json:"last_payment_at,omitempty"`
+	Status        string     `json:"status"`
+}
+
+// ParseStatement detects the format of a bank statement file and parses it.
+// Errors wrap ErrInvalidStatement.
+func ParseStatement(data []byte) (*sepa.SEPAStatement, string, error) {
+	trimmed := bytes.TrimLeft(data, "\xef\xbb\xbf \t\r\n")
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		stmt, err := sepa.ParseCamt053(data)
+		if err != nil {
+			return nil, FormatCamt053, fmt.Errorf("%w: camt.053: %v", ErrInvalidStatement, err)
+		}
+		return stmt, FormatCamt053, nil
+	case bytes.HasPrefix(trimmed, []byte(":20:")), bytes.HasPrefix(trimmed, []byte("{1:")):
+		stmt, err := sepa.ParseMT940(data)
+		if err != nil {
+			return nil, FormatMT940, fmt.Errorf("%w: MT940: %v", ErrInvalidStatement, err)
+		}
+		return stmt, FormatMT940, nil
+	default:
+		return nil, "", fmt.Errorf("%w: unknown format, expected camt.053 or MT940", ErrInvalidStatement)
+	}
+}
+
+// ReconciliationStatus returns the payment state of an invoice from its
+// payable amount and the sum of the transactions matched to it
+func ReconciliationStatus(payable, paid int64) string {
+	switch {
+	case paid <= 0:
+		return ReconciliationOpen
+	case paid < payable:
+		return ReconciliationPartial
+	case paid == payable:
+		return ReconciliationPaid
+	default:
+		return ReconciliationOverpaid
+	}
+}
+
+// Score rates how well a candidate fits a transaction. The end-to-end ID of
+// a payment identifies it on its own (100); the invoice number or end-to-end
+// ID in the remittance information adds 60, the exact open amount 30 and the
+// counterparty IBAN 20. Candidates of the other direction score 0.
+func Score(txn *Transaction, c *Candidate) (int, []string) {
+	if (txn.CreditDebit == string(sepa.CreditDebitCredit)) != c.Credit {
+		return 0, nil
+	}
+
+	score := 0
+	var reasons []string
+	if c.EndToEndID != "" && txn.EndToEndID != nil && strings.EqualFold(*txn.EndToEndID, c.EndToEndID) {
+		score += 100
+		reasons = append(reasons, "end-to-end ID")
+	} else if containsReference(transactionText(txn), c.Reference) {
+		score += 60
+		reasons = append(reasons, "reference")
+	}
+	if txn.Amount == c.Amount {
+		score += 30
+		reasons = append(reasons, "amount")
+	}
+	if c.IBAN != "" && txn.CounterpartyIBAN != nil && normalizeReference(*txn.CounterpartyIBAN) == normalizeReference(c.IBAN) {
+		score += 20
+		reasons = append(reasons, "IBAN")
+	}
+	return score, reasons
+}
+
+// Suggest returns the candidates scoring at least SuggestThreshold for a
+// transaction, best first
+func Suggest(txn *Transaction, candidates []*Candidate) []*Suggestion {
+	var out []*Suggestion
+	for _, c := range candidates {
+		if score, reasons := Score(txn, c); score >= SuggestThreshold {
+			out = append(out, &Suggestion{Candidate: *c, Score: score, Reasons: reasons})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+// AutoMatch picks the candidate for a transaction. It returns MatchMatched
+// with the candidate only if the best one reaches MatchThreshold and no
+// other scores as high; otherwise MatchSuggested or MatchUnmatched with the
+// best suggestion, if any.
+func AutoMatch(txn *Transaction, candidates []*Candidate) (*Suggestion, string) {
+	suggestions := Suggest(txn, candidates)
+	if len(suggestions) == 0 {
+		return nil, MatchUnmatched
+	}
+	best := suggestions[0]
+	if best.Score >= MatchThreshold && (len(suggestions) == 1 || suggestions[1].Score < best.Score) {
+		return best, MatchMatched
+	}
+	return best, MatchSuggested
+}
+
+// transactionText is the text of a transaction a reference may appear in
+func transactionText(txn *Transaction) string {
+	var parts []string
+	for _, s := range []*string{txn.RemittanceInfo, txn.EndToEndID, txn.Reference} {
+		if s != nil {
+			parts = append(parts, *s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// normalizeReference keeps only letters and digits, upper-cased, so that
+// "RE-2024/001" matches "re 2024 001"
+func normalizeReference(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// containsReference reports whether the reference appears in the text,
+// ignoring separators. A reference starting or ending with a digit must not
+// run on into a longer number, so invoice "2024-1" is found in "2024-1 12,50"
+// but not in "2024-12".
+func containsReference(text, reference string) bool {
+	ref := normalizeReference(reference)
+	if len(ref) < 4 {
+		return false
+	}
+
+	// norm is the normalized text; split[k] is set if a separator preceded
+	// norm[k] in the text
+	var norm []byte
+	var split []bool
+	separated := false
+	for _, r := range strings.ToUpper(text) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			norm = append(norm, byte(r))
+			split = append(split, separated)
+			separated = false
+		} else {
+			separated = true
+		}
+	}
+	split = append(split, true)
+
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	for from := 0; from+len(ref) <= len(norm); from++ {
+		i := strings.Index(string(norm[from:]), ref)
+		if i < 0 {
+			return false
+		}
+		i += from
+		end := i + len(ref)
+		startOK := i == 0 || split[i] || !isDigit(ref[0]) || !isDigit(norm[i-1])
+		endOK := end == len(norm) || split[end] || !isDigit(ref[len(ref)-1]) || !isDigit(norm[end])
+		if startOK && endOK {
+			return true
+		}
+		from = i
+	}
+	return false
+}
+
+// Reconcile matches the open transactions of a statement against the open
+// invoices and the payments of generated batches. Unique matches are stored
+// as matched, the rest is marked suggested or unmatched for manual review.
+// Transactions matched by hand or ignored are left alone.
+func (s *Service) Reconcile(ctx context.Context, tenantID, statementID uuid.UUID) (*ReconcileResult, error) {
+	if _, err := s.repo.GetStatementByID(ctx, statementID, tenantID); err != nil {
+		return nil, err
+	}
+	txns, err := s.repo.GetStatementTransactions(ctx, statementID)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := s.repo.ListCandidates(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReconcileResult{}
+	for _, txn := range txns {
+		if txn.MatchStatus == MatchMatched || txn.MatchStatus == MatchIgnored ||
+			txn.MatchedInvoiceID != nil || txn.MatchedPaymentID != nil {
+			continue
+		}
+
+		best, status := AutoMatch(txn, candidates)
+		var score *int
+		var reason *string
+		if best != nil {
+			r := best.Kind + " " + best.Reference + ": " + strings.Join(best.Reasons, ", ")
+			score, reason = &best.Score, &r
+		}
+
+		switch status {
+		case MatchMatched:
+			var paymentID, invoiceID *uuid.UUID
+			if best.Kind == CandidateInvoice {
+				invoiceID = &best.ID
+			} else {
+				paymentID = &best.ID
+			}
+			if err := s.repo.SetTransactionMatch(ctx, tenantID, txn.ID, status, paymentID, invoiceID, score, reason, nil); err != nil {
+				return nil, err
+			}
+			if invoiceID != nil {
+				if err := s.refreshInvoice(ctx, tenantID, *invoiceID); err != nil {
+					return nil, err
+				}
+			}
+			candidates = consume(candidates, best, txn.Amount)
+			result.Matched++
+		default:
+			if err := s.repo.SetTransactionMatch(ctx, tenantID, txn.ID, status, nil, nil, score, reason, nil); err != nil {
+				return nil, err
+			}
+			if status == MatchSuggested {
+				result.Suggested++
+			} else {
+				result.Unmatched++
+			}
+		}
+	}
+	return result, nil
+}
+
+// consume takes a matched amount off a candidate; payments and fully paid
+// invoices are no longer candidates
+func consume(candidates []*Candidate, matched *Suggestion, amount int64) []*Candidate {
+	out := candidates[:0]
+	for _, c := range candidates {
+		if c.Kind == matched.Kind && c.ID == matched.ID {
+			if c.Kind == CandidatePayment || c.Amount <= amount {
+				continue
+			}
+			c.Amount -= amount
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// Suggestions returns the scored candidates for a transaction of the tenant
+func (s *Service) Suggestions(ctx context.Context, tenantID, txnID uuid.UUID) ([]*Suggestion, error) {
+	txn, err := s.repo.GetTransaction(ctx, tenantID, txnID)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := s.repo.ListCandidates(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return Suggest(txn, candidates), nil
+}
+
+// MatchTransaction matches a transaction of the tenant by hand to a payment
+// or an invoice, replacing any earlier match
+func (s *Service) MatchTransaction(ctx context.Context, tenantID, txnID uuid.UUID, userID *uuid.UUID, paymentID, invoiceID *uuid.UUID) (*Transaction, error) {
+	if (paymentID == nil) == (invoiceID == nil) {
+		return nil, ErrMatchTarget
+	}
+	txn, err := s.repo.GetTransaction(ctx, tenantID, txnID)
+	if err != nil {
+		return nil, err
+	}
+	if invoiceID != nil {
+		if err := s.repo.CheckInvoice(ctx, tenantID, *invoiceID); err != nil {
+			return nil, err
+		}
+	} else if err := s.repo.CheckPayment(ctx, tenantID, *paymentID); err != nil {
+		return nil, err
+	}
+
+	reason := "manual"
+	if err := s.repo.SetTransactionMatch(ctx, tenantID, txnID, MatchMatched, paymentID, invoiceID, nil, &reason, userID); err != nil {
+		return nil, err
+	}
+	if err := s.refreshInvoices(ctx, tenantID, txn.MatchedInvoiceID, invoiceID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTransaction(ctx, tenantID, txnID)
+}
+
+// UnmatchTransaction removes the match of a transaction. With ignore, the
+// transaction is marked as needing no match, e.g. bank fees, and skipped by
+// later reconciliation runs.
+func (s *Service) UnmatchTransaction(ctx context.Context, tenantID, txnID uuid.UUID, userID *uuid.UUID, ignore bool) (*Transaction, error) {
+	txn, err := s.repo.GetTransaction(ctx, tenantID, txnID)
+	if err != nil {
+		return nil, err
+	}
+	status, by := MatchUnmatched, (*uuid.UUID)(nil)
+	if ignore {
+		status, by = MatchIgnored, userID
+	}
+	if err := s.repo.SetTransactionMatch(ctx, tenantID, txnID, status, nil, nil, nil, nil, by); err != nil {
+		return nil, err
+	}
+	if err := s.refreshInvoices(ctx, tenantID, txn.MatchedInvoiceID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTransaction(ctx, tenantID, txnID)
+}
+
+// ListReconciliation returns the payment state of the tenant's issued
+// invoices, optionally only those in the given state
+func (s *Service) ListReconciliation(ctx context.Context, tenantID uuid.UUID, status string) ([]*InvoiceReconciliation, error) {
+	invoices, err := s.repo.ListInvoicePayments(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	out := invoices[:0]
+	for _, inv := range invoices {
+		inv.Status = ReconciliationStatus(inv.Payable, inv.Paid)
+		if status == "" || inv.Status == status {
+			out = append(out, inv)
+		}
+	}
+	return out, nil
+}
+
+func (s *Service) refreshInvoices(ctx context.Context, tenantID uuid.UUID, ids ...*uuid.UUID) error {
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range ids {
+		if id != nil && !seen[*id] {
+			seen[*id] = true
+			if err := s.refreshInvoice(ctx, tenantID, *id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// refreshInvoice marks an invoice paid once its matched transactions cover
+// the payable amount, and sets a paid invoice back to sent when they no
+// longer do
+func (s *Service) refreshInvoice(ctx context.Context, tenantID, id uuid.UUID) error {
+	payable, paid, err := s.repo.InvoicePayment(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	switch ReconciliationStatus(payable, paid) {
+	case ReconciliationPaid, ReconciliationOverpaid:
+		return s.repo.SetInvoicePaid(ctx, tenantID, id, true)
+	default:
+		return s.repo.SetInvoicePaid(ctx, tenantID, id, false)
+	}
+}
@@ -15,6 +15,7 @@ import (
 var (
 	ErrBatchNotFound     = errors.New("batch not found")
 	ErrStatementNotFound = errors.New("statement not found")
+	ErrProjectNotFound   = errors.New("project not found")
 )
 
 // Repository handles payment database operations
@@ -44,14 +45,14 @@ func (r *Repository) CreateBatch(ctx context.Context, batch *Batch, items []*Ite
 		INSERT INTO payment_batches (
 			id, tenant_id, name, type, debtor_name, debtor_iban, debtor_bic,
 			creditor_id, execution_date, item_count, total_amount, status,
-			created_by, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			created_by, created_at, updated_at, project_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		RETURNING id`
 
 	err = tx.QueryRow(ctx, query,
 		batch.ID, batch.TenantID, batch.Name, batch.Type, batch.DebtorName, batch.DebtorIBAN, batch.DebtorBIC,
 		batch.CreditorID, batch.ExecutionDate, len(items), batch.TotalAmount, batch.Status,
-		batch.CreatedBy, batch.CreatedAt, batch.UpdatedAt,
+		batch.CreatedBy, batch.CreatedAt, batch.UpdatedAt, batch.ProjectID,
 	).Scan(&batch.ID)
 
 	if err != nil {
@@ -106,21 +107,21 @@ func (r *Repository) GetBatchByID(ctx context.Context, id, tenantID uuid.UUID) (
 		SELECT id, tenant_id, name, type, debtor_name, debtor_iban, debtor_bic,
 			creditor_id, execution_date, item_count, total_amount, status,
 			validation_errors, xml_content IS NOT NULL as has_xml, generated_at, sent_at,
-			created_by, created_at, updated_at
+			created_by, created_at, updated_at, project_id
 		FROM payment_batches
 		WHERE id = $1 AND tenant_id = $2`
 
 	var batch Batch
 	var debtorBIC, creditorID sql.NullString
 	var executionDate, generatedAt, sentAt sql.NullTime
-	var createdBy uuid.NullUUID
+	var createdBy, projectID uuid.NullUUID
 	var hasXML bool
 
 	err := r.db.QueryRow(ctx, query, id, tenantID).Scan(
 		&batch.ID, &batch.TenantID, &batch.Name, &batch.Type, &batch.DebtorName, &batch.DebtorIBAN, &debtorBIC,
 		&creditorID, &executionDate, &batch.ItemCount, &batch.TotalAmount, &batch.Status,
 		&batch.ValidationErrors, &hasXML, &generatedAt, &sentAt,
-		&createdBy, &batch.CreatedAt, &batch.UpdatedAt,
+		&createdBy, &batch.CreatedAt, &batch.UpdatedAt, &projectID,
 	)
 
 	if err != nil {
@@ -148,6 +149,9 @@ func (r *Repository) GetBatchByID(ctx context.Context, id, tenantID uuid.UUID) (
 	if createdBy.Valid {
 		batch.CreatedBy = &createdBy.UUID
 	}
+	if projectID.Valid {
+		batch.ProjectID = &projectID.UUID
+	}
 
 	return &batch, nil
 }
@@ -229,6 +233,12 @@ func (r *Repository) ListBatches(ctx context.Context, filter ListFilter) ([]*Bat
 		argIdx++
 	}
 
+	if filter.ProjectID != nil {
+		baseQuery += fmt.Sprintf(" AND project_id = $%d", argIdx)
+		args = append(args, *filter.ProjectID)
+		argIdx++
+	}
+
 	if filter.DateFrom != nil {
 		baseQuery += fmt.Sprintf(" AND created_at >= $%d", argIdx)
 		args = append(args, *filter.DateFrom)
@@ -252,7 +262,7 @@ func (r *Repository) ListBatches(ctx context.Context, filter ListFilter) ([]*Bat
 	// Get paginated results
 	selectQuery := `
 		SELECT id, tenant_id, name, type, debtor_name, debtor_iban,
-			item_count, total_amount, status, generated_at, sent_at, created_at, updated_at
+			item_count, total_amount, status, generated_at, sent_at, created_at, updated_at, project_id
 		` + baseQuery + `
 		ORDER BY created_at DESC
 		LIMIT $` + fmt.Sprintf("%d", argIdx) + ` OFFSET $` + fmt.Sprintf("%d", argIdx+1)
@@ -269,10 +279,11 @@ func (r *Repository) ListBatches(ctx context.Context, filter ListFilter) ([]*Bat
 	for rows.Next() {
 		var batch Batch
 		var generatedAt, sentAt sql.NullTime
+		var projectID uuid.NullUUID
 
 		err := rows.Scan(
 			&batch.ID, &batch.TenantID, &batch.Name, &batch.Type, &batch.DebtorName, &batch.DebtorIBAN,
-			&batch.ItemCount, &batch.TotalAmount, &batch.Status, &generatedAt, &sentAt, &batch.CreatedAt, &batch.UpdatedAt,
+			&batch.ItemCount, &batch.TotalAmount, &batch.Status, &generatedAt, &sentAt, &batch.CreatedAt, &batch.UpdatedAt, &projectID,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan batch: %w", err)
@@ -284,6 +295,9 @@ func (r *Repository) ListBatches(ctx context.Context, filter ListFilter) ([]*Bat
 		if sentAt.Valid {
 			batch.SentAt = &sentAt.Time
 		}
+		if projectID.Valid {
+			batch.ProjectID = &projectID.UUID
+		}
 
 		batches = append(batches, &batch)
 	}
@@ -366,13 +380,13 @@ func (r *Repository) CreateBankStatement(ctx context.Context, stmt *BankStatemen
 	query := `
 		INSERT INTO bank_statements (
 			id, tenant_id, iban, statement_id, statement_date, opening_balance,
-			closing_balance, entry_count, imported_at, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			closing_balance, entry_count, imported_at, created_at, format
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id`
 
 	err = tx.QueryRow(ctx, query,
 		stmt.ID, stmt.TenantID, stmt.IBAN, stmt.StatementID, stmt.StatementDate, stmt.OpeningBalance,
-		stmt.ClosingBalance, len(txns), stmt.ImportedAt, stmt.CreatedAt,
+		stmt.ClosingBalance, len(txns), stmt.ImportedAt, stmt.CreatedAt, stmt.Format,
 	).Scan(&stmt.ID)
 
 	if err != nil {
@@ -415,14 +429,14 @@ func (r *Repository) CreateBankStatement(ctx context.Context, stmt *BankStatemen
 func (r *Repository) GetStatementByID(ctx context.Context, id, tenantID uuid.UUID) (*BankStatement, error) {
 	query := `
 		SELECT id, tenant_id, iban, statement_id, statement_date, opening_balance,
-			closing_balance, entry_count, imported_at, created_at
+			closing_balance, entry_count, imported_at, created_at, format
 		FROM bank_statements
 		WHERE id = $1 AND tenant_id = $2`
 
 	var stmt BankStatement
 	err := r.db.QueryRow(ctx, query, id, tenantID).Scan(
 		&stmt.ID, &stmt.TenantID, &stmt.IBAN, &stmt.StatementID, &stmt.StatementDate, &stmt.OpeningBalance,
-		&stmt.ClosingBalance, &stmt.EntryCount, &stmt.ImportedAt, &stmt.CreatedAt,
+		&stmt.ClosingBalance, &stmt.EntryCount, &stmt.ImportedAt, &stmt.CreatedAt, &stmt.Format,
 	)
 
 	if err != nil {
@@ -435,15 +449,63 @@ func (r *Repository) GetStatementByID(ctx context.Context, id, tenantID uuid.UUI
 	return &stmt, nil
 }
 
+// transactionColumns are the columns scanned by scanTransaction
+const transactionColumns = `t.id, t.statement_id, t.amount, t.currency, t.credit_debit, t.booking_date, t.value_date,
+	t.reference, t.end_to_end_id, t.remittance_info, t.counterparty_name, t.counterparty_iban,
+	t.matched_payment_id, t.matched_invoice_id, t.created_at,
+	COALESCE(t.match_status, 'unmatched'), t.match_score, t.match_reason, t.matched_at, t.matched_by`
+
+func scanTransaction(row pgx.Row) (*Transaction, error) {
+	var txn Transaction
+	var valueDate sql.NullTime
+	var reference, endToEndID, remittanceInfo, counterpartyName, counterpartyIBAN sql.NullString
+	var matchedPaymentID, matchedInvoiceID uuid.NullUUID
+
+	err := row.Scan(
+		&txn.ID, &txn.StatementID, &txn.Amount, &txn.Currency, &txn.CreditDebit, &txn.BookingDate, &valueDate,
+		&reference, &endToEndID, &remittanceInfo, &counterpartyName, &counterpartyIBAN,
+		&matchedPaymentID, &matchedInvoiceID, &txn.CreatedAt,
+		&txn.MatchStatus, &txn.MatchScore, &txn.MatchReason, &txn.MatchedAt, &txn.MatchedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if valueDate.Valid {
+		txn.ValueDate = &valueDate.Time
+	}
+	if reference.Valid {
+		txn.Reference = &reference.String
+	}
+	if endToEndID.Valid {
+		txn.EndToEndID = &endToEndID.String
+	}
+	if remittanceInfo.Valid {
+		txn.RemittanceInfo = &remittanceInfo.String
+	}
+	if counterpartyName.Valid {
+		txn.CounterpartyName = &counterpartyName.String
+	}
+	if counterpartyIBAN.Valid {
+		txn.CounterpartyIBAN = &counterpartyIBAN.String
+	}
+	if matchedPaymentID.Valid {
+		txn.MatchedPaymentID = &matchedPaymentID.UUID
+	}
+	if matchedInvoiceID.Valid {
+		txn.MatchedInvoiceID = &matchedInvoiceID.UUID
+	}
+
+	return &txn, nil
+}
+
 // GetStatementTransactions retrieves all transactions for a statement
 func (r *Repository) GetStatementTransactions(ctx context.Context, statementID uuid.UUID) ([]*Transaction, error) {
 	query := `
-		SELECT id, statement_id, amount, currency, credit_debit, booking_date, value_date,
-			reference, end_to_end_id, remittance_info, counterparty_name, counterparty_iban,
-			matched_payment_id, matched_invoice_id, created_at
-		FROM transactions
-		WHERE statement_id = $1
-		ORDER BY booking_date, created_at`
+		SELECT ` + transactionColumns + `
+		FROM transactions t
+		WHERE t.statement_id = $1
+		ORDER BY t.booking_date, t.created_at`
 
 	rows, err := r.db.Query(ctx, query, statementID)
 	if err != nil {
@@ -453,49 +515,14 @@ func (r *Repository) GetStatementTransactions(ctx context.Context, statementID u
 
 	var txns []*Transaction
 	for rows.Next() {
-		var txn Transaction
-		var valueDate sql.NullTime
-		var reference, endToEndID, remittanceInfo, counterpartyName, counterpartyIBAN sql.NullString
-		var matchedPaymentID, matchedInvoiceID uuid.NullUUID
-
-		err := rows.Scan(
-			&txn.ID, &txn.StatementID, &txn.Amount, &txn.Currency, &txn.CreditDebit, &txn.BookingDate, &valueDate,
-			&reference, &endToEndID, &remittanceInfo, &counterpartyName, &counterpartyIBAN,
-			&matchedPaymentID, &matchedInvoiceID, &txn.CreatedAt,
-		)
+		txn, err := scanTransaction(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan transaction: %w", err)
 		}
-
-		if valueDate.Valid {
-			txn.ValueDate = &valueDate.Time
-		}
-		if reference.Valid {
-			txn.Reference = &reference.String
-		}
-		if endToEndID.Valid {
-			txn.EndToEndID = &endToEndID.String
-		}
-		if remittanceInfo.Valid {
-			txn.RemittanceInfo = &remittanceInfo.String
-		}
-		if counterpartyName.Valid {
-			txn.CounterpartyName = &counterpartyName.String
-		}
-		if counterpartyIBAN.Valid {
-			txn.CounterpartyIBAN = &counterpartyIBAN.String
-		}
-		if matchedPaymentID.Valid {
-			txn.MatchedPaymentID = &matchedPaymentID.UUID
-		}
-		if matchedInvoiceID.Valid {
-			txn.MatchedInvoiceID = &matchedInvoiceID.UUID
-		}
-
-		txns = append(txns, &txn)
+		txns = append(txns, txn)
 	}
 
-	return txns, nil
+	return txns, rows.Err()
 }
 
 // ListStatements lists bank statements
@@ -511,7 +538,7 @@ func (r *Repository) ListStatements(ctx context.Context, tenantID uuid.UUID, lim
 	// Get paginated results
 	selectQuery := `
 		SELECT id, tenant_id, iban, statement_id, statement_date, opening_balance,
-			closing_balance, entry_count, imported_at, created_at
+			closing_balance, entry_count, imported_at, created_at, format
 		FROM bank_statements
 		WHERE tenant_id = $1
 		ORDER BY statement_date DESC, imported_at DESC
@@ -528,7 +555,7 @@ func (r *Repository) ListStatements(ctx context.Context, tenantID uuid.UUID, lim
 		var stmt BankStatement
 		err := rows.Scan(
 			&stmt.ID, &stmt.TenantID, &stmt.IBAN, &stmt.StatementID, &stmt.StatementDate, &stmt.OpeningBalance,
-			&stmt.ClosingBalance, &stmt.EntryCount, &stmt.ImportedAt, &stmt.CreatedAt,
+			&stmt.ClosingBalance, &stmt.EntryCount, &stmt.ImportedAt, &stmt.CreatedAt, &stmt.Format,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan statement: %w", err)
@@ -552,9 +579,219 @@ func (r *Repository) DeleteStatement(ctx context.Context, id, tenantID uuid.UUID
 	return nil
 }
 
-// MatchTransaction updates a transaction with matched payment/invoice
-func (r *Repository) MatchTransaction(ctx context.Context, txnID uuid.UUID, paymentID, invoiceID *uuid.UUID) error {
-	query := `UPDATE transactions SET matched_payment_id = $1, matched_invoice_id = $2 WHERE id = $3`
-	_, err := r.db.Exec(ctx, query, paymentID, invoiceID, txnID)
-	return err
+// GetTransaction retrieves a transaction of a tenant's statement
+func (r *Repository) GetTransaction(ctx context.Context, tenantID, id uuid.UUID) (*Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions t
+		JOIN bank_statements s ON s.id = t.statement_id
+		WHERE t.id = $1 AND s.tenant_id = $2`
+
+	txn, err := scanTransaction(r.db.QueryRow(ctx, query, id, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	return txn, nil
+}
+
+// SetTransactionMatch stores the match state of a transaction of a tenant's
+// statement, replacing the matched payment and invoice
+func (r *Repository) SetTransactionMatch(ctx context.Context, tenantID, id uuid.UUID, status string, paymentID, invoiceID *uuid.UUID, score *int, reason *string, by *uuid.UUID) error {
+	query := `
+		UPDATE transactions t SET
+			match_status = $3,
+			matched_payment_id = $4,
+			matched_invoice_id = $5,
+			match_score = $6,
+			match_reason = $7,
+			matched_by = $8,
+			matched_at = CASE WHEN $3 IN ('matched', 'ignored') THEN NOW() END
+		FROM bank_statements s
+		WHERE t.id = $1 AND s.id = t.statement_id AND s.tenant_id = $2`
+
+	result, err := r.db.Exec(ctx, query, id, tenantID, status, paymentID, invoiceID, score, reason, by)
+	if err != nil {
+		return fmt.Errorf("failed to update transaction match: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrTransactionNotFound
+	}
+	return nil
+}
+
+// invoicePaidSQL is the sum of the transactions matched to invoice i, with
+// refunds counted against it; for credit notes the other way round
+const invoicePaidSQL = `CASE WHEN i.invoice_type = '381' THEN -1 ELSE 1 END * COALESCE((
+	SELECT SUM(CASE WHEN t.credit_debit = 'CRDT' THEN t.amount ELSE -t.amount END)
+	FROM transactions t WHERE t.matched_invoice_id = i.id
+), 0)`
+
+// ListCandidates returns what bank transactions of a tenant can be matched
+// to: issued invoices with an open amount, oldest due first, and the
+// payments of generated or sent batches not matched yet. The IBAN of an
+// invoice is the one its buyer paid from last, if known.
+func (r *Repository) ListCandidates(ctx context.Context, tenantID uuid.UUID) ([]*Candidate, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT i.id, i.invoice_number, i.payable_amount - (`+invoicePaidSQL+`), i.buyer_name,
+			COALESCE((
+				SELECT t.counterparty_iban FROM transactions t
+				JOIN invoices i2 ON i2.id = t.matched_invoice_id
+				WHERE i2.tenant_id = i.tenant_id AND i2.buyer_name = i.buyer_name AND t.counterparty_iban IS NOT NULL
+				ORDER BY t.booking_date DESC LIMIT 1
+			), '')
+		FROM invoices i
+		WHERE i.tenant_id = $1 AND i.status IN ('validated', 'generated', 'sent')
+			AND i.invoice_type <> '381'
+			AND i.payable_amount > (`+invoicePaidSQL+`)
+		ORDER BY i.due_date NULLS LAST, i.issue_date`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load open invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []*Candidate
+	for rows.Next() {
+		c := &Candidate{Kind: CandidateInvoice, Credit: true}
+		if err := rows.Scan(&c.ID, &c.Reference, &c.Amount, &c.Name, &c.IBAN); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Query(ctx, `
+		SELECT pi.id, pi.end_to_end_id, pi.amount, pi.creditor_iban, pi.creditor_name, b.type
+		FROM payment_items pi
+		JOIN payment_batches b ON b.id = pi.batch_id
+		WHERE b.tenant_id = $1 AND b.status IN ('generated', 'sent', 'processed')
+			AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.matched_payment_id = pi.id)
+		ORDER BY b.execution_date NULLS LAST, pi.created_at`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load open payments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c := &Candidate{Kind: CandidatePayment}
+		var batchType string
+		if err := rows.Scan(&c.ID, &c.EndToEndID, &c.Amount, &c.IBAN, &c.Name, &batchType); err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		c.Reference = c.EndToEndID
+		c.Credit = batchType == TypeDirectDebit
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// CheckInvoice checks that an invoice of the tenant is issued and not cancelled
+func (r *Repository) CheckInvoice(ctx context.Context, tenantID, id uuid.UUID) error {
+	var exists bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM invoices
+			WHERE id = $1 AND tenant_id = $2 AND status NOT IN ('draft', 'cancelled')
+		)`, id, tenantID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check invoice: %w", err)
+	}
+	if !exists {
+		return ErrInvoiceNotFound
+	}
+	return nil
+}
+
+// CheckPayment checks that a payment item belongs to a batch of the tenant
+func (r *Repository) CheckPayment(ctx context.Context, tenantID, id uuid.UUID) error {
+	var exists bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM payment_items pi
+			JOIN payment_batches b ON b.id = pi.batch_id
+			WHERE pi.id = $1 AND b.tenant_id = $2
+		)`, id, tenantID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check payment: %w", err)
+	}
+	if !exists {
+		return ErrPaymentNotFound
+	}
+	return nil
+}
+
+// InvoicePayment returns the payable amount of an invoice and the sum of
+// the transactions matched to it
+func (r *Repository) InvoicePayment(ctx context.Context, tenantID, id uuid.UUID) (payable, paid int64, err error) {
+	err = r.db.QueryRow(ctx, `
+		SELECT i.payable_amount, `+invoicePaidSQL+`
+		FROM invoices i
+		WHERE i.id = $1 AND i.tenant_id = $2`, id, tenantID).Scan(&payable, &paid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, 0, ErrInvoiceNotFound
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load invoice payments: %w", err)
+	}
+	return payable, paid, nil
+}
+
+// SetInvoicePaid moves an issued invoice to paid, or a paid one back to sent
+func (r *Repository) SetInvoicePaid(ctx context.Context, tenantID, id uuid.UUID, paid bool) error {
+	query := `UPDATE invoices SET status = 'paid', updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status IN ('validated', 'generated', 'sent')`
+	if !paid {
+		query = `UPDATE invoices SET status = 'sent', updated_at = NOW()
+			WHERE id = $1 AND tenant_id = $2 AND status = 'paid'`
+	}
+	if _, err := r.db.Exec(ctx, query, id, tenantID); err != nil {
+		return fmt.Errorf("failed to update invoice status: %w", err)
+	}
+	return nil
+}
+
+// ListInvoicePayments returns the tenant's issued invoices with the sum,
+// count and last booking date of the transactions matched to them
+func (r *Repository) ListInvoicePayments(ctx context.Context, tenantID uuid.UUID) ([]*InvoiceReconciliation, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT i.id, i.invoice_number, i.buyer_name, i.issue_date, i.due_date, i.currency, i.payable_amount,
+			`+invoicePaidSQL+`,
+			(SELECT COUNT(*) FROM transactions t WHERE t.matched_invoice_id = i.id),
+			(SELECT MAX(t.booking_date) FROM transactions t WHERE t.matched_invoice_id = i.id)
+		FROM invoices i
+		WHERE i.tenant_id = $1 AND i.status NOT IN ('draft', 'cancelled')
+		ORDER BY i.issue_date DESC, i.invoice_number
+		LIMIT 500`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoice payments: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []*InvoiceReconciliation
+	for rows.Next() {
+		var inv InvoiceReconciliation
+		if err := rows.Scan(&inv.InvoiceID, &inv.InvoiceNumber, &inv.BuyerName, &inv.IssueDate, &inv.DueDate,
+			&inv.Currency, &inv.Payable, &inv.Paid, &inv.Transactions, &inv.LastPaymentAt); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		invoices = append(invoices, &inv)
+	}
+	return invoices, rows.Err()
+}
+
+// ProjectExists reports whether a project belongs to the tenant
+func (r *Repository) ProjectExists(ctx context.Context, projectID, tenantID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND tenant_id = $2)`,
+		projectID, tenantID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check project: %w", err)
+	}
+	return exists, nil
 }
@@ -40,6 +40,17 @@ func (s *Service) CreateBatch(ctx context.Context, tenantID, userID uuid.UUID, i
 		return nil, ErrInvalidBatchType
 	}
 
+	// Only the tenant's own projects can be referenced
+	if input.ProjectID != nil {
+		exists, err := s.repo.ProjectExists(ctx, *input.ProjectID, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrProjectNotFound
+		}
+	}
+
 	// Parse execution date
 	var executionDate *time.Time
 	if input.ExecutionDate != nil && *input.ExecutionDate != "" {
@@ -59,6 +70,7 @@ func (s *Service) CreateBatch(ctx context.Context, tenantID, userID uuid.UUID, i
 		DebtorBIC:     input.DebtorBIC,
 		CreditorID:    input.CreditorID,
 		ExecutionDate: executionDate,
+		ProjectID:     input.ProjectID,
 		CreatedBy:     &userID,
 	}
 
@@ -327,12 +339,12 @@ func (s *Service) GetBatchXML(ctx context.Context, id, tenantID uuid.UUID) ([]by
 	return s.repo.GetBatchXML(ctx, id, tenantID)
 }
 
-// ImportBankStatement imports a camt.053 bank statement
-func (s *Service) ImportBankStatement(ctx context.Context, tenantID uuid.UUID, xmlData []byte) (*BankStatement, error) {
-	// Parse camt.053
-	sepaStmt, err := sepa.ParseCamt053(xmlData)
+// ImportBankStatement imports a camt.053 or MT940 bank statement and
+// reconciles its transactions with open invoices and payments
+func (s *Service) ImportBankStatement(ctx context.Context, tenantID uuid.UUID, data []byte) (*BankStatement, *ReconcileResult, error) {
+	sepaStmt, format, err := ParseStatement(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse camt.053: %w", err)
+		return nil, nil, err
 	}
 
 	// Convert to our types
@@ -343,6 +355,7 @@ func (s *Service) ImportBankStatement(ctx context.Context, tenantID uuid.UUID, x
 		StatementDate:  sepaStmt.CreationTime,
 		OpeningBalance: sepaStmt.OpeningBalance,
 		ClosingBalance: sepaStmt.ClosingBalance,
+		Format:         format,
 	}
 
 	// Convert transactions
@@ -375,7 +388,16 @@ func (s *Service) ImportBankStatement(ctx context.Context, tenantID uuid.UUID, x
 		txns = append(txns, txn)
 	}
 
-	return s.repo.CreateBankStatement(ctx, stmt, txns)
+	stmt, err = s.repo.CreateBankStatement(ctx, stmt, txns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := s.Reconcile(ctx, tenantID, stmt.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("statement imported, reconciliation failed: %w", err)
+	}
+	return stmt, result, nil
 }
 
 // GetStatement retrieves a bank statement by ID
@@ -411,11 +433,6 @@ func (s *Service) DeleteStatement(ctx context.Context, id, tenantID uuid.UUID) e
 	return s.repo.DeleteStatement(ctx, id, tenantID)
 }
 
-// MatchTransaction matches a transaction to a payment or invoice
-func (s *Service) MatchTransaction(ctx context.Context, txnID uuid.UUID, paymentID, invoiceID *uuid.UUID) error {
-	return s.repo.MatchTransaction(ctx, txnID, paymentID, invoiceID)
-}
-
 // ImportCSVBatch imports payments from CSV
 func (s *Service) ImportCSVBatch(ctx context.Context, tenantID, userID uuid.UUID, name, debtorName, debtorIBAN string, csvData []byte) (*Batch, error) {
 	// Parse CSV using sepa library
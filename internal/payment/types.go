@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/pkg/money"
 )
 
 // Batch status constants
@@ -34,6 +36,7 @@ type Batch struct {
 	DebtorBIC        *string         `json:"debtor_bic,omitempty"`
 	CreditorID       *string         `json:"creditor_id,omitempty"` // For pain.008
 	ExecutionDate    *time.Time      `json:"execution_date,omitempty"`
+	ProjectID        *uuid.UUID      `json:"project_id,omitempty"`
 	ItemCount        int             `json:"item_count"`
 	TotalAmount      int64           `json:"total_amount"` // In cents
 	Status           string          `json:"status"`
@@ -75,6 +78,7 @@ type CreateBatchInput struct {
 	DebtorBIC     *string     `json:"debtor_bic,omitempty"`
 	CreditorID    *string     `json:"creditor_id,omitempty"`
 	ExecutionDate *string     `json:"execution_date,omitempty"`
+	ProjectID     *uuid.UUID  `json:"project_id,omitempty"`
 	Items         []ItemInput `json:"items"`
 }
 
@@ -95,13 +99,14 @@ type ItemInput struct {
 
 // ListFilter represents filtering options
 type ListFilter struct {
-	TenantID uuid.UUID
-	Type     *string
-	Status   *string
-	DateFrom *time.Time
-	DateTo   *time.Time
-	Limit    int
-	Offset   int
+	TenantID  uuid.UUID
+	Type      *string
+	Status    *string
+	ProjectID *uuid.UUID
+	DateFrom  *time.Time
+	DateTo    *time.Time
+	Limit     int
+	Offset    int
 }
 
 // BatchResponse is the API response format
@@ -112,8 +117,9 @@ type BatchResponse struct {
 	DebtorName       string          `json:"debtor_name"`
 	DebtorIBAN       string          `json:"debtor_iban"`
 	ExecutionDate    *string         `json:"execution_date,omitempty"`
+	ProjectID        *uuid.UUID      `json:"project_id,omitempty"`
 	ItemCount        int             `json:"item_count"`
-	TotalAmount      float64         `json:"total_amount"`
+	TotalAmount      money.Money     `json:"total_amount"`
 	Status           string          `json:"status"`
 	ValidationErrors json.RawMessage `json:"validation_errors,omitempty"`
 	HasXML           bool            `json:"has_xml"`
@@ -125,15 +131,15 @@ type BatchResponse struct {
 
 // ItemResponse is the API response format for items
 type ItemResponse struct {
-	ID             uuid.UUID `json:"id"`
-	EndToEndID     string    `json:"end_to_end_id"`
-	Amount         float64   `json:"amount"`
-	Currency       string    `json:"currency"`
-	CreditorName   string    `json:"creditor_name"`
-	CreditorIBAN   string    `json:"creditor_iban"`
-	RemittanceInfo *string   `json:"remittance_info,omitempty"`
-	Status         string    `json:"status"`
-	ErrorMessage   *string   `json:"error_message,omitempty"`
+	ID             uuid.UUID   `json:"id"`
+	EndToEndID     string      `json:"end_to_end_id"`
+	Amount         money.Money `json:"amount"`
+	Currency       string      `json:"currency"`
+	CreditorName   string      `json:"creditor_name"`
+	CreditorIBAN   string      `json:"creditor_iban"`
+	RemittanceInfo *string     `json:"remittance_info,omitempty"`
+	Status         string      `json:"status"`
+	ErrorMessage   *string     `json:"error_message,omitempty"`
 }
 
 // BankStatement represents an imported bank statement (camt.053)
@@ -148,6 +154,9 @@ type BankStatement struct {
 	EntryCount     int       `json:"entry_count"`
 	ImportedAt     time.Time `json:"imported_at"`
 	CreatedAt      time.Time `json:"created_at"`
+
+	// Format is the file format the statement was imported from
+	Format string `json:"format"`
 }
 
 // Transaction represents a bank statement transaction
@@ -167,4 +176,13 @@ type Transaction struct {
 	MatchedPaymentID *uuid.UUID `json:"matched_payment_id,omitempty"`
 	MatchedInvoiceID *uuid.UUID `json:"matched_invoice_id,omitempty"`
 	CreatedAt        time.Time  `json:"created_at"`
+
+	// Reconciliation: MatchStatus is one of the Match* states, MatchScore
+	// and MatchReason explain an automatic match or suggestion, MatchedBy
+	// is set for manual matches
+	MatchStatus string     `json:"match_status"`
+	MatchScore  *int       `json:"match_score,omitempty"`
+	MatchReason *string    `json:"match_reason,omitempty"`
+	MatchedAt   *time.Time `json:"matched_at,omitempty"`
+	MatchedBy   *uuid.UUID `json:"matched_by,omitempty"`
 }
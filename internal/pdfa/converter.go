@@ -0,0 +1,181 @@
+package pdfa
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Config configures the external tools used for conversion
+type Config struct {
+	GhostscriptPath string
+	LibreOfficePath string
+	// VeraPDFPath enables validation with veraPDF; empty uses the builtin
+	// structural check
+	VeraPDFPath string
+	// ICCProfile is the sRGB profile embedded as output intent
+	ICCProfile string
+	Timeout    time.Duration
+}
+
+// Converter converts documents to PDF/A-2b
+type Converter struct {
+	cfg Config
+}
+
+// NewConverter creates a converter, filling in defaults for empty settings
+func NewConverter(cfg Config) *Converter {
+	if cfg.GhostscriptPath == "" {
+		cfg.GhostscriptPath = "gs"
+	}
+	if cfg.LibreOfficePath == "" {
+		cfg.LibreOfficePath = "soffice"
+	}
+	if cfg.ICCProfile == "" {
+		cfg.ICCProfile = "/usr/share/color/icc/ghostscript/srgb.icc"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Minute
+	}
+	return &Converter{cfg: cfg}
+}
+
+// Convert returns the PDF/A-2b rendition of a PDF or office document
+func (c *Converter) Convert(ctx context.Context, content []byte, mimeType string) ([]byte, error) {
+	mimeType = normalizeMIME(mimeType)
+	if !Supported(mimeType) {
+		return nil, ErrUnsupportedFormat
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	tempDir, err := os.MkdirTemp("", "pdfa-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	pdfPath := filepath.Join(tempDir, "input.pdf")
+	if ext, office := officeFormats[mimeType]; office {
+		pdfPath, err = c.officeToPDF(ctx, tempDir, content, ext)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := os.WriteFile(pdfPath, content, 0600); err != nil {
+		return nil, fmt.Errorf("write input: %w", err)
+	}
+
+	return c.pdfToPDFA(ctx, tempDir, pdfPath)
+}
+
+// officeToPDF exports an office document to PDF with LibreOffice
+func (c *Converter) officeToPDF(ctx context.Context, dir string, content []byte, ext string) (string, error) {
+	inputPath := filepath.Join(dir, "document"+ext)
+	if err := os.WriteFile(inputPath, content, 0600); err != nil {
+		return "", fmt.Errorf("write input: %w", err)
+	}
+	outDir := filepath.Join(dir, "office")
+
+	// A private profile per run lets conversions run in parallel
+	profile := "file://" + filepath.ToSlash(filepath.Join(dir, "profile"))
+	if err := c.run(ctx, c.cfg.LibreOfficePath,
+		"--headless", "--norestore", "-env:UserInstallation="+profile,
+		"--convert-to", "pdf", "--outdir", outDir, inputPath,
+	); err != nil {
+		return "", fmt.Errorf("%w: office export: %v", ErrConversionFailed, err)
+	}
+
+	pdfPath := filepath.Join(outDir, "document.pdf")
+	if _, err := os.Stat(pdfPath); err != nil {
+		return "", fmt.Errorf("%w: office export produced no PDF", ErrConversionFailed)
+	}
+	return pdfPath, nil
+}
+
+// pdfToPDFA rewrites a PDF as PDF/A-2b with Ghostscript
+func (c *Converter) pdfToPDFA(ctx context.Context, dir, pdfPath string) ([]byte, error) {
+	defPath := filepath.Join(dir, "PDFA_def.ps")
+	if err := os.WriteFile(defPath, []byte(pdfaDef(c.cfg.ICCProfile)), 0600); err != nil {
+		return nil, fmt.Errorf("write PDF/A definition: %w", err)
+	}
+
+	outPath := filepath.Join(dir, "output.pdf")
+	if err := c.run(ctx, c.cfg.GhostscriptPath,
+		"-dPDFA=2", "-dBATCH", "-dNOPAUSE", "-dSAFER", "-dQUIET",
+		"--permit-file-read="+c.cfg.ICCProfile,
+		"-sColorConversionStrategy=RGB",
+		"-sDEVICE=pdfwrite",
+		"-dPDFACompatibilityPolicy=1",
+		"-sOutputFile="+outPath,
+		defPath, pdfPath,
+	); err != nil {
+		return nil, fmt.Errorf("%w: ghostscript: %v", ErrConversionFailed, err)
+	}
+
+	out, err := os.ReadFile(outPath)
+	if err != nil || len(out) == 0 {
+		return nil, fmt.Errorf("%w: ghostscript produced no output", ErrConversionFailed)
+	}
+	return out, nil
+}
+
+// pdfaDef is the PostScript prologue that declares the sRGB output intent
+// PDF/A requires
+func pdfaDef(iccProfile string) string {
+	return fmt.Sprintf(`%%!
+/ICCProfile (%s) def
+[/_objdef {icc_PDFA} /type /stream /OBJ pdfmark
+[{icc_PDFA} <</N 3>> /PUT pdfmark
+[{icc_PDFA} ICCProfile (r) file /PUT pdfmark
+[/_objdef {OutputIntent_PDFA} /type /dict /OBJ pdfmark
+[{OutputIntent_PDFA} <<
+  /Type /OutputIntent
+  /S /GTS_PDFA1
+  /DestOutputProfile {icc_PDFA}
+  /OutputConditionIdentifier (sRGB)
+>> /PUT pdfmark
+[{Catalog} <</OutputIntents [ {OutputIntent_PDFA} ]>> /PUT pdfmark
+`, escapePS(iccProfile))
+}
+
+func escapePS(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+	return r.Replace(s)
+}
+
+// run executes a tool and returns the tail of its stderr on failure
+func (c *Converter) run(ctx context.Context, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = 5 * time.Second
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.Stdout = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		var execErr *exec.Error
+		if errors.As(err, &execErr) || errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s not available: %w", name, err)
+		}
+		return fmt.Errorf("%w: %s", err, tail(stderr.String(), 300))
+	}
+	return nil
+}
+
+func tail(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) > n {
+		s = "..." + s[len(s)-n:]
+	}
+	return s
+}
@@ -0,0 +1,100 @@
+// Package pdfa holds what archival (PDF/A) files generated here embed.
+package pdfa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+var (
+	srgbOnce    sync.Once
+	srgbProfile []byte
+)
+
+// SRGBProfile returns a compact ICC v2 display profile for sRGB (D50
+// adapted primaries, gamma 2.2). PDF/A output intents must embed a profile,
+// and files generated here use it for their RGB output intent.
+func SRGBProfile() []byte {
+	srgbOnce.Do(func() { srgbProfile = buildSRGBProfile() })
+	return srgbProfile
+}
+
+func buildSRGBProfile() []byte {
+	s15 := func(v float64) uint32 { return uint32(int32(math.Round(v * 65536))) }
+	xyz := func(x, y, z float64) []byte {
+		b := make([]byte, 20)
+		copy(b, "XYZ ")
+		binary.BigEndian.PutUint32(b[8:], s15(x))
+		binary.BigEndian.PutUint32(b[12:], s15(y))
+		binary.BigEndian.PutUint32(b[16:], s15(z))
+		return b
+	}
+
+	const description = "sRGB"
+	desc := make([]byte, 12, 12+len(description)+1+79)
+	copy(desc, "desc")
+	binary.BigEndian.PutUint32(desc[8:], uint32(len(description)+1))
+	desc = append(desc, description...)
+	desc = append(desc, 0)
+	// Empty Unicode and ScriptCode descriptions
+	desc = append(desc, make([]byte, 4+4+2+1+67)...)
+
+	cprt := append([]byte("text\x00\x00\x00\x00"), "No copyright, use freely\x00"...)
+
+	// Gamma 2.2 as u8Fixed8
+	trc := []byte{'c', 'u', 'r', 'v', 0, 0, 0, 0, 0, 0, 0, 1, 0x02, 0x33}
+
+	tags := []struct {
+		sig  string
+		data []byte
+	}{
+		{"desc", desc},
+		{"cprt", cprt},
+		{"wtpt", xyz(0.9642, 1.0, 0.8249)},
+		{"rXYZ", xyz(0.4361, 0.2225, 0.0139)},
+		{"gXYZ", xyz(0.3851, 0.7169, 0.0971)},
+		{"bXYZ", xyz(0.1431, 0.0606, 0.7141)},
+		{"rTRC", trc},
+		{"gTRC", trc},
+		{"bTRC", trc},
+	}
+
+	// Tag data follows the header and the tag table, 4-byte aligned
+	var data bytes.Buffer
+	table := make([]byte, 4+12*len(tags))
+	binary.BigEndian.PutUint32(table, uint32(len(tags)))
+	offset := 128 + len(table)
+	for i, tag := range tags {
+		for data.Len()%4 != 0 {
+			data.WriteByte(0)
+		}
+		entry := table[4+12*i:]
+		copy(entry, tag.sig)
+		binary.BigEndian.PutUint32(entry[4:], uint32(offset+data.Len()))
+		binary.BigEndian.PutUint32(entry[8:], uint32(len(tag.data)))
+		data.Write(tag.data)
+	}
+
+	header := make([]byte, 128)
+	size := 128 + len(table) + data.Len()
+	binary.BigEndian.PutUint32(header[0:], uint32(size))
+	binary.BigEndian.PutUint32(header[8:], 0x02100000) // version 2.1
+	copy(header[12:], "mntr")
+	copy(header[16:], "RGB ")
+	copy(header[20:], "XYZ ")
+	// Creation date 2026-01-01 00:00:00
+	for i, v := range []uint16{2026, 1, 1, 0, 0, 0} {
+		binary.BigEndian.PutUint16(header[24+2*i:], v)
+	}
+	copy(header[36:], "acsp")
+	binary.BigEndian.PutUint32(header[68:], s15(0.9642)) // PCS illuminant D50
+	binary.BigEndian.PutUint32(header[72:], s15(1.0))
+	binary.BigEndian.PutUint32(header[76:], s15(0.8249))
+
+	profile := make([]byte, 0, size)
+	profile = append(profile, header...)
+	profile = append(profile, table...)
+	return append(profile, data.Bytes()...)
+}
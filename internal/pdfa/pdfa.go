@@ -0,0 +1,80 @@
+// Package pdfa converts stored documents to PDF/A-2b for long-term archiving
+// and checks the conformance of the result. PDFs are converted with
+// Ghostscript, office documents are first exported to PDF with LibreOffice.
+// Conformance is checked with veraPDF when it is installed; otherwise only a
+// structural check of the PDF/A markers is made.
+package pdfa
+
+import (
+	"errors"
+	"strings"
+)
+
+// Conformance is the PDF/A level of archival renditions
+const Conformance = "PDF/A-2b"
+
+// Validators
+const (
+	ValidatorVeraPDF = "verapdf"
+	ValidatorBuiltin = "builtin"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("format cannot be converted to PDF/A")
+	ErrConversionFailed  = errors.New("PDF/A conversion failed")
+)
+
+const mimePDF = "application/pdf"
+
+// officeFormats maps the office formats LibreOffice converts to the file
+// extension it recognizes them by
+var officeFormats = map[string]string{
+	"application/msword": ".doc",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": ".docx",
+	"application/vnd.ms-excel": ".xls",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         ".xlsx",
+	"application/vnd.ms-powerpoint":                                             ".ppt",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": ".pptx",
+	"application/vnd.oasis.opendocument.text":                                   ".odt",
+	"application/vnd.oasis.opendocument.spreadsheet":                            ".ods",
+	"application/vnd.oasis.opendocument.presentation":                           ".odp",
+	"application/rtf": ".rtf",
+	"text/rtf":        ".rtf",
+}
+
+// normalizeMIME strips parameters such as "; charset=binary"
+func normalizeMIME(mimeType string) string {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
+
+// Supported reports whether documents of the MIME type can be converted
+func Supported(mimeType string) bool {
+	mimeType = normalizeMIME(mimeType)
+	_, office := officeFormats[mimeType]
+	return mimeType == mimePDF || office
+}
+
+// SupportedTypes returns the MIME types that can be converted
+func SupportedTypes() []string {
+	types := []string{mimePDF}
+	for t := range officeFormats {
+		types = append(types, t)
+	}
+	return types
+}
+
+// Issue is a conformance problem of a rendition. Clause refers to ISO
+// 19005-2 where known.
+type Issue struct {
+	Clause  string `json:"clause,omitempty"`
+	Message string `json:"message"`
+}
+
+// Validation is the result of a conformance check
+type Validation struct {
+	Conformance string  `json:"conformance"`
+	Validator   string  `json:"validator"`
+	Compliant   bool    `json:"compliant"`
+	Issues      []Issue `json:"issues,omitempty"`
+}
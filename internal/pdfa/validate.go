@@ -0,0 +1,195 @@
+package pdfa
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Validate checks a rendition for PDF/A-2b conformance, with veraPDF if it
+// is configured and with the builtin structural check otherwise
+func (c *Converter) Validate(ctx context.Context, pdf []byte) (*Validation, error) {
+	if c.cfg.VeraPDFPath == "" {
+		return CheckStructure(pdf), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	tempDir, err := os.MkdirTemp("", "pdfa-validate-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "rendition.pdf")
+	if err := os.WriteFile(path, pdf, 0600); err != nil {
+		return nil, fmt.Errorf("write rendition: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, c.cfg.VeraPDFPath, "--flavour", "2b", "--format", "mrr", path)
+	cmd.WaitDelay = 5 * time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// veraPDF exits non-zero for non-compliant files, so the report decides
+	runErr := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	validation, err := ParseVeraPDFReport(stdout.Bytes())
+	if err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("verapdf: %w: %s", runErr, tail(stderr.String(), 300))
+		}
+		return nil, err
+	}
+	return validation, nil
+}
+
+// veraPDFReport is the part of the veraPDF machine readable report we read
+type veraPDFReport struct {
+	Jobs []struct {
+		ValidationReport *struct {
+			IsCompliant bool `xml:"isCompliant,attr"`
+			Details     struct {
+				Rules []struct {
+					Specification string `xml:"specification,attr"`
+					Clause        string `xml:"clause,attr"`
+					TestNumber    string `xml:"testNumber,attr"`
+					Status        string `xml:"status,attr"`
+					Description   string `xml:"description"`
+				} `xml:"rule"`
+			} `xml:"details"`
+		} `xml:"validationReport"`
+		TaskException *struct {
+			Message string `xml:"exceptionMessage"`
+		} `xml:"taskException"`
+	} `xml:"jobs>job"`
+}
+
+// ParseVeraPDFReport reads the result of the first job of a veraPDF report
+// in the "mrr" format
+func ParseVeraPDFReport(data []byte) (*Validation, error) {
+	var report veraPDFReport
+	if err := xml.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse verapdf report: %w", err)
+	}
+	if len(report.Jobs) == 0 {
+		return nil, fmt.Errorf("verapdf report contains no job")
+	}
+	job := report.Jobs[0]
+	if job.ValidationReport == nil {
+		msg := "no validation report"
+		if job.TaskException != nil {
+			msg = strings.TrimSpace(job.TaskException.Message)
+		}
+		return nil, fmt.Errorf("verapdf: %s", msg)
+	}
+
+	v := &Validation{
+		Conformance: Conformance,
+		Validator:   ValidatorVeraPDF,
+		Compliant:   job.ValidationReport.IsCompliant,
+	}
+	for _, rule := range job.ValidationReport.Details.Rules {
+		if rule.Status != "" && rule.Status != "failed" {
+			continue
+		}
+		clause := rule.Clause
+		if rule.TestNumber != "" {
+			clause += "-" + rule.TestNumber
+		}
+		v.Issues = append(v.Issues, Issue{
+			Clause:  clause,
+			Message: strings.TrimSpace(rule.Description),
+		})
+	}
+	return v, nil
+}
+
+// Limits of the builtin check, which inflates streams to find the XMP
+// metadata
+const (
+	maxInflatedStream = 4 << 20
+	maxInflatedTotal  = 32 << 20
+)
+
+var (
+	streamPattern          = regexp.MustCompile(`(?s)stream\r?\n(.*?)endstream`)
+	pdfaPartPattern        = regexp.MustCompile(`pdfaid:part(?:>|=["'])\s*(\d)`)
+	pdfaConformancePattern = regexp.MustCompile(`pdfaid:conformance(?:>|=["'])\s*([A-Za-z])`)
+)
+
+// CheckStructure checks the markers a PDF/A-2b file must carry: PDF header,
+// no encryption, no JavaScript, an output intent and the PDF/A
+// identification in the XMP metadata. It cannot replace a full validator
+// such as veraPDF, which also checks fonts, colour spaces and transparency.
+func CheckStructure(pdf []byte) *Validation {
+	v := &Validation{Conformance: Conformance, Validator: ValidatorBuiltin}
+	add := func(clause, msg string) {
+		v.Issues = append(v.Issues, Issue{Clause: clause, Message: msg})
+	}
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		add("6.1.2", "file header is not a PDF header")
+		return v
+	}
+
+	content := inflatedContent(pdf)
+	if bytes.Contains(content, []byte("/Encrypt")) {
+		add("6.1.3", "file is encrypted")
+	}
+	if bytes.Contains(content, []byte("/JavaScript")) || bytes.Contains(content, []byte("/JS ")) {
+		add("6.6.1", "file contains JavaScript")
+	}
+	if !bytes.Contains(content, []byte("/GTS_PDFA1")) {
+		add("6.2.3", "no PDF/A output intent")
+	}
+
+	part := pdfaPartPattern.FindSubmatch(content)
+	conformance := pdfaConformancePattern.FindSubmatch(content)
+	switch {
+	case part == nil:
+		add("6.6.4", "XMP metadata lacks the PDF/A identification")
+	case string(part[1]) != "2":
+		add("6.6.4", fmt.Sprintf("file identifies as PDF/A-%s, not PDF/A-2", part[1]))
+	case conformance == nil || !strings.ContainsAny(string(conformance[1]), "bBuUaA"):
+		add("6.6.4", "XMP metadata lacks a valid PDF/A conformance level")
+	}
+
+	v.Compliant = len(v.Issues) == 0
+	return v
+}
+
+// inflatedContent returns the file with its Flate encoded streams appended
+// in inflated form; streams that do not inflate are kept as they are
+func inflatedContent(pdf []byte) []byte {
+	content := append([]byte{}, pdf...)
+	total := 0
+	for _, m := range streamPattern.FindAllSubmatchIndex(pdf, -1) {
+		if total >= maxInflatedTotal {
+			break
+		}
+		r, err := zlib.NewReader(bytes.NewReader(pdf[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+		data, _ := io.ReadAll(io.LimitReader(r, maxInflatedStream))
+		r.Close()
+		total += len(data)
+		content = append(content, '\n')
+		content = append(content, data...)
+	}
+	return content
+}
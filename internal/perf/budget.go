@@ -0,0 +1,108 @@
+package perf
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+//go:embed budgets.json
+var budgetsJSON []byte
+
+// Budget is the latency budget of one hot path. HTTP budgets carry the
+// method and path the load-test profiles (tests/load) request; budgets
+// without a path cover service operations that have no endpoint of their own.
+type Budget struct {
+	Name         string  `json:"name"`
+	Method       string  `json:"method,omitempty"`
+	Path         string  `json:"path,omitempty"`
+	P95MS        int     `json:"p95_ms"`
+	P99MS        int     `json:"p99_ms,omitempty"`
+	MaxErrorRate float64 `json:"max_error_rate,omitempty"`
+}
+
+// P95 returns the 95th percentile budget
+func (b Budget) P95() time.Duration {
+	return time.Duration(b.P95MS) * time.Millisecond
+}
+
+// P99 returns the 99th percentile budget, zero if unset
+func (b Budget) P99() time.Duration {
+	return time.Duration(b.P99MS) * time.Millisecond
+}
+
+// Budgets is a set of budgets as stored in budgets.json
+type Budgets struct {
+	Budgets []Budget `json:"budgets"`
+}
+
+// Get returns the budget with the given name
+func (b *Budgets) Get(name string) (Budget, bool) {
+	for _, budget := range b.Budgets {
+		if budget.Name == name {
+			return budget, true
+		}
+	}
+	return Budget{}, false
+}
+
+// ParseBudgets parses and validates a budgets document
+func ParseBudgets(data []byte) (*Budgets, error) {
+	var b Budgets
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse budgets: %w", err)
+	}
+	if len(b.Budgets) == 0 {
+		return nil, errors.New("no budgets defined")
+	}
+
+	seen := make(map[string]bool, len(b.Budgets))
+	for _, budget := range b.Budgets {
+		switch {
+		case budget.Name == "":
+			return nil, errors.New("budget without name")
+		case seen[budget.Name]:
+			return nil, fmt.Errorf("budget %s: defined twice", budget.Name)
+		case budget.P95MS <= 0:
+			return nil, fmt.Errorf("budget %s: p95_ms must be positive", budget.Name)
+		case budget.P99MS != 0 && budget.P99MS < budget.P95MS:
+			return nil, fmt.Errorf("budget %s: p99_ms below p95_ms", budget.Name)
+		case budget.MaxErrorRate < 0 || budget.MaxErrorRate > 1:
+			return nil, fmt.Errorf("budget %s: max_error_rate must be between 0 and 1", budget.Name)
+		case (budget.Method == "") != (budget.Path == ""):
+			return nil, fmt.Errorf("budget %s: method and path must be set together", budget.Name)
+		}
+		seen[budget.Name] = true
+	}
+	return &b, nil
+}
+
+// DefaultBudgets returns the budgets shipped with the binary. An invalid
+// embedded document is a build defect and panics.
+func DefaultBudgets() *Budgets {
+	b, err := ParseBudgets(budgetsJSON)
+	if err != nil {
+		panic(fmt.Sprintf("perf: %v", err))
+	}
+	return b
+}
+
+// LoadBudgets reads budgets from a JSON file, or returns the defaults if
+// path is empty
+func LoadBudgets(path string) (*Budgets, error) {
+	if path == "" {
+		return DefaultBudgets(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read budgets: %w", err)
+	}
+	b, err := ParseBudgets(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,134 @@
+package perf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"text/tabwriter"
+	"time"
+)
+
+// Check is one measured operation. Run is called once per iteration and
+// returns an error if the operation failed.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context, iteration int) error
+}
+
+// HTTPCheck returns a check that serves the request of budget through handler
+// in-process, so the measured latency excludes the network. body may be nil;
+// it is called per iteration because request bodies are consumed. Responses
+// with a status of 400 and above count as failures.
+func HTTPCheck(budget Budget, handler http.Handler, token string, body func() io.Reader) Check {
+	return Check{
+		Name: budget.Name,
+		Run: func(ctx context.Context, _ int) error {
+			var r io.Reader
+			if body != nil {
+				r = body()
+			}
+			req := httptest.NewRequestWithContext(ctx, budget.Method, budget.Path, r)
+			if r != nil {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code >= http.StatusBadRequest {
+				return fmt.Errorf("%s %s: status %d", budget.Method, budget.Path, rec.Code)
+			}
+			return nil
+		},
+	}
+}
+
+// Runner runs checks sequentially and evaluates them against budgets
+type Runner struct {
+	Budgets    *Budgets
+	Iterations int // measured iterations per check
+	Warmup     int // unmeasured iterations per check, e.g. to fill caches
+}
+
+// Report is the outcome of a run
+type Report struct {
+	Results []Result `json:"results"`
+	Errors  []string `json:"errors,omitempty"` // first error of each failing check
+}
+
+// Passed reports whether every check met its budget
+func (r *Report) Passed() bool {
+	for _, res := range r.Results {
+		if !res.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Write prints the report as a table
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tN\tERRORS\tP50\tP95\tP99\tBUDGET P95\tRESULT")
+	for _, res := range r.Results {
+		status := "ok"
+		if !res.Passed() {
+			status = "FAIL"
+			for _, v := range res.Violations {
+				status += "; " + v
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n", res.Name, res.Count, res.Errors,
+			round(res.P50), round(res.P95), round(res.P99), res.Budget.P95(), status)
+	}
+	for _, e := range r.Errors {
+		fmt.Fprintf(tw, "error: %s\n", e)
+	}
+	return tw.Flush()
+}
+
+// Run measures every check. A check without a budget is a programming error
+// and aborts the run, as does cancellation of ctx.
+func (rn *Runner) Run(ctx context.Context, checks []Check) (*Report, error) {
+	report := &Report{}
+	for _, check := range checks {
+		budget, ok := rn.Budgets.Get(check.Name)
+		if !ok {
+			return nil, fmt.Errorf("no budget for check %s", check.Name)
+		}
+
+		for i := 0; i < rn.Warmup; i++ {
+			_ = check.Run(ctx, i)
+		}
+
+		latencies := make([]time.Duration, 0, rn.Iterations)
+		failures := 0
+		var firstErr error
+		for i := 0; i < rn.Iterations; i++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			start := time.Now()
+			err := check.Run(ctx, rn.Warmup+i)
+			elapsed := time.Since(start)
+			if err != nil {
+				failures++
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			latencies = append(latencies, elapsed)
+		}
+
+		report.Results = append(report.Results, Evaluate(budget, Summarize(check.Name, latencies, failures)))
+		if firstErr != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", check.Name, firstErr))
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,94 @@
+package perf
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Summary describes the latencies measured for one check
+type Summary struct {
+	Name   string        `json:"name"`
+	Count  int           `json:"count"`
+	Errors int           `json:"errors"`
+	P50    time.Duration `json:"p50"`
+	P95    time.Duration `json:"p95"`
+	P99    time.Duration `json:"p99"`
+	Max    time.Duration `json:"max"`
+}
+
+// ErrorRate returns the share of failed samples
+func (s Summary) ErrorRate() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Count)
+}
+
+// Summarize computes the percentiles of the given latencies. Failed requests
+// are counted in errors and are not part of latencies.
+func Summarize(name string, latencies []time.Duration, errors int) Summary {
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	s := Summary{Name: name, Count: len(sorted) + errors, Errors: errors}
+	if len(sorted) > 0 {
+		s.P50 = Percentile(sorted, 50)
+		s.P95 = Percentile(sorted, 95)
+		s.P99 = Percentile(sorted, 99)
+		s.Max = sorted[len(sorted)-1]
+	}
+	return s
+}
+
+// Percentile returns the p-th percentile of sorted latencies using the
+// nearest-rank method
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(len(sorted)) / 100))
+	rank = min(max(rank, 1), len(sorted))
+	return sorted[rank-1]
+}
+
+// Result is a summary checked against its budget
+type Result struct {
+	Summary
+	Budget     Budget   `json:"budget"`
+	Violations []string `json:"violations,omitempty"`
+}
+
+// Passed reports whether the budget was met
+func (r Result) Passed() bool {
+	return len(r.Violations) == 0
+}
+
+// Evaluate checks a summary against a budget
+func Evaluate(budget Budget, s Summary) Result {
+	r := Result{Summary: s, Budget: budget}
+	if s.Count == 0 {
+		r.Violations = append(r.Violations, "no samples")
+		return r
+	}
+	if s.ErrorRate() > budget.MaxErrorRate {
+		r.Violations = append(r.Violations, fmt.Sprintf("error rate %.1f%% above %.1f%%",
+			s.ErrorRate()*100, budget.MaxErrorRate*100))
+	}
+	if s.Errors < s.Count && s.P95 > budget.P95() {
+		r.Violations = append(r.Violations, fmt.Sprintf("p95 %s above %s", round(s.P95), budget.P95()))
+	}
+	if budget.P99MS > 0 && s.Errors < s.Count && s.P99 > budget.P99() {
+		r.Violations = append(r.Violations, fmt.Sprintf("p99 %s above %s", round(s.P99), budget.P99()))
+	}
+	return r
+}
+
+// round shortens a duration for reports
+func round(d time.Duration) time.Duration {
+	if d >= time.Millisecond {
+		return d.Round(100 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
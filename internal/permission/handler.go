@@ -0,0 +1,272 @@
+package permission
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler handles permission and custom role HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new permission handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the permission routes. Reading roles is open to
+// all users of the tenant; changing roles and assignments requires
+// roles:manage.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth func(http.Handler) http.Handler, requirePermission func(Permission) func(http.Handler) http.Handler) {
+	requireManage := requirePermission(RolesManage)
+
+	router.Handle("GET /api/v1/permissions", requireAuth(http.HandlerFunc(h.Catalog)))
+	router.Handle("GET /api/v1/roles", requireAuth(http.HandlerFunc(h.ListRoles)))
+	router.Handle("POST /api/v1/roles", requireAuth(requireManage(http.HandlerFunc(h.CreateRole))))
+	router.Handle("GET /api/v1/roles/{id}", requireAuth(http.HandlerFunc(h.GetRole)))
+	router.Handle("PUT /api/v1/roles/{id}", requireAuth(requireManage(http.HandlerFunc(h.UpdateRole))))
+	router.Handle("DELETE /api/v1/roles/{id}", requireAuth(requireManage(http.HandlerFunc(h.DeleteRole))))
+
+	router.Handle("GET /api/v1/users/me/permissions", requireAuth(http.HandlerFunc(h.MyPermissions)))
+	router.Handle("GET /api/v1/users/{id}/roles", requireAuth(http.HandlerFunc(h.GetUserRoles)))
+	router.Handle("PUT /api/v1/users/{id}/roles", requireAuth(requireManage(http.HandlerFunc(h.SetUserRoles))))
+}
+
+// requestActor returns the user of the request with their role, writing
+// 401 if there is none
+func requestActor(w http.ResponseWriter, r *http.Request) (Actor, bool) {
+	id, err := uuid.Parse(api.GetUserID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "user not found in context")
+		return Actor{}, false
+	}
+	return Actor{UserID: id, Role: api.GetUserRole(r.Context())}, true
+}
+
+// Catalog handles GET /api/v1/permissions
+func (h *Handler) Catalog(w http.ResponseWriter, r *http.Request) {
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"permissions":   Catalog,
+		"builtin_roles": BuiltinRoles(),
+	})
+}
+
+// ListRoles handles GET /api/v1/roles
+func (h *Handler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	roles, err := h.service.ListRoles(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if roles == nil {
+		roles = []*Role{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"builtin_roles": BuiltinRoles(),
+		"roles":         roles,
+	})
+}
+
+// CreateRole handles POST /api/v1/roles
+func (h *Handler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	var input RoleInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	role, err := h.service.CreateRole(r.Context(), tenantID, actor, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, role)
+}
+
+// GetRole handles GET /api/v1/roles/{id}
+func (h *Handler) GetRole(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	role, err := h.service.GetRole(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, role)
+}
+
+// UpdateRole handles PUT /api/v1/roles/{id}
+func (h *Handler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var input RoleInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	role, err := h.service.UpdateRole(r.Context(), tenantID, id, actor, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, role)
+}
+
+// DeleteRole handles DELETE /api/v1/roles/{id}
+func (h *Handler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRole(r.Context(), tenantID, id, actor); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MyPermissions handles GET /api/v1/users/me/permissions
+func (h *Handler) MyPermissions(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	perms, err := h.service.Effective(r.Context(), tenantID, actor.UserID, actor.Role)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"role":        actor.Role,
+		"permissions": perms,
+	})
+}
+
+// GetUserRoles handles GET /api/v1/users/{id}/roles
+func (h *Handler) GetUserRoles(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	roles, err := h.service.UserRoles(r.Context(), tenantID, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, roles)
+}
+
+// SetUserRolesRequest replaces the custom roles of a user
+type SetUserRolesRequest struct {
+	RoleIDs []uuid.UUID `json:"role_ids"`
+}
+
+// SetUserRoles handles PUT /api/v1/users/{id}/roles
+func (h *Handler) SetUserRoles(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req SetUserRolesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	roles, err := h.service.SetUserRoles(r.Context(), tenantID, userID, actor, req.RoleIDs)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, roles)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrRoleNotFound), errors.Is(err, ErrUserNotInTenant):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrRoleNameExists):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, ErrCannotGrant):
+		api.Forbidden(w, err.Error())
+	case errors.Is(err, ErrInvalidRole), errors.Is(err, ErrNoPermissions), errors.Is(err, ErrUnknownPermission):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	default:
+		api.InternalError(w)
+	}
+}
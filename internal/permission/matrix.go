@@ -0,0 +1,105 @@
+package permission
+
+import (
+	"strings"
+
+	"austrian-business-infrastructure/internal/user"
+)
+
+// Catalog lists all permissions
+var Catalog = []Definition{
+	{UVARead, "Read UVA filings, VAT schemes and input invoices, derive and validate"},
+	{UVAWrite, "Create, correct and delete UVA filings and batches, VAT schemes and input invoices"},
+	{UVASubmit, "Submit UVA filings to FinanzOnline"},
+	{InvoicesRead, "Read, export, validate and render invoices"},
+	{InvoicesWrite, "Create and delete invoices, clauses and custom field values"},
+	{InvoicesSend, "Send invoices to recipients"},
+	{RolesManage, "Manage custom roles and assign them to users"},
+}
+
+// builtin are the permissions of the built-in roles. They match the access
+// the roles had before the permission matrix: admins and owners hold all
+// permissions, members and viewers read.
+var builtin = map[user.Role][]Permission{
+	user.RoleOwner:  all(),
+	user.RoleAdmin:  all(),
+	user.RoleMember: {UVARead, InvoicesRead},
+	user.RoleViewer: {UVARead, InvoicesRead},
+}
+
+func all() []Permission {
+	perms := make([]Permission, len(Catalog))
+	for i, d := range Catalog {
+		perms[i] = d.Permission
+	}
+	return perms
+}
+
+// IsValid reports whether a permission is in the catalog
+func IsValid(p Permission) bool {
+	for _, d := range Catalog {
+		if d.Permission == p {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBuiltinRole reports whether a name is that of a built-in role, ignoring
+// case
+func IsBuiltinRole(name string) bool {
+	return user.IsValidRole(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// Builtin returns the permissions of a built-in role; none for unknown roles
+func Builtin(role string) []Permission {
+	return append([]Permission(nil), builtin[user.Role(role)]...)
+}
+
+// BuiltinRoles returns the built-in roles with their permissions, highest
+// first
+func BuiltinRoles() []BuiltinRole {
+	roles := make([]BuiltinRole, 0, len(user.ValidRoles))
+	for _, r := range user.ValidRoles {
+		roles = append(roles, BuiltinRole{Role: string(r), Permissions: Builtin(string(r))})
+	}
+	return roles
+}
+
+// Has reports whether p is among perms
+func Has(perms []Permission, p Permission) bool {
+	for _, have := range perms {
+		if have == p {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAll reports whether every permission of want is among perms
+func HasAll(perms, want []Permission) bool {
+	for _, p := range want {
+		if !Has(perms, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// Union returns the permissions of all sets in the order of the catalog,
+// without duplicates; permissions not in the catalog are dropped
+func Union(sets ...[]Permission) []Permission {
+	seen := make(map[Permission]bool)
+	for _, set := range sets {
+		for _, p := range set {
+			seen[p] = true
+		}
+	}
+	perms := make([]Permission, 0, len(seen))
+	for _, d := range Catalog {
+		if seen[d.Permission] {
+			perms = append(perms, d.Permission)
+		}
+	}
+	return perms
+}
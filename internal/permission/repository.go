@@ -0,0 +1,220 @@
+package permission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository provides custom role data access
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new permission repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const roleColumns = `r.id, r.tenant_id, r.name, r.description, r.permissions,
+	(SELECT COUNT(*) FROM user_role_assignments a WHERE a.role_id = r.id), r.created_by, r.created_at, r.updated_at`
+
+func scanRole(row pgx.Row) (*Role, error) {
+	r := &Role{}
+	var perms []string
+	err := row.Scan(&r.ID, &r.TenantID, &r.Name, &r.Description, &perms,
+		&r.UserCount, &r.CreatedBy, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("scan role: %w", err)
+	}
+	r.Permissions = toPermissions(perms)
+	return r, nil
+}
+
+func scanRoles(rows pgx.Rows) ([]*Role, error) {
+	defer rows.Close()
+	var roles []*Role
+	for rows.Next() {
+		r, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, r)
+	}
+	return roles, rows.Err()
+}
+
+func toPermissions(perms []string) []Permission {
+	out := make([]Permission, len(perms))
+	for i, p := range perms {
+		out[i] = Permission(p)
+	}
+	return out
+}
+
+func toStrings(perms []Permission) []string {
+	out := make([]string, len(perms))
+	for i, p := range perms {
+		out[i] = string(p)
+	}
+	return out
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// CreateRole inserts a custom role
+func (r *Repository) CreateRole(ctx context.Context, role *Role) error {
+	if role.ID == uuid.Nil {
+		role.ID = uuid.New()
+	}
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO tenant_roles (id, tenant_id, name, description, permissions, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at
+	`, role.ID, role.TenantID, role.Name, role.Description, toStrings(role.Permissions), role.CreatedBy).Scan(&role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrRoleNameExists
+		}
+		return fmt.Errorf("create role: %w", err)
+	}
+	return nil
+}
+
+// GetRole returns a custom role of a tenant
+func (r *Repository) GetRole(ctx context.Context, tenantID, id uuid.UUID) (*Role, error) {
+	return scanRole(r.db.QueryRow(ctx, `SELECT `+roleColumns+` FROM tenant_roles r WHERE r.tenant_id = $1 AND r.id = $2`, tenantID, id))
+}
+
+// ListRoles returns the custom roles of a tenant, by name
+func (r *Repository) ListRoles(ctx context.Context, tenantID uuid.UUID) ([]*Role, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+roleColumns+` FROM tenant_roles r WHERE r.tenant_id = $1 ORDER BY lower(r.name)`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list roles: %w", err)
+	}
+	return scanRoles(rows)
+}
+
+// UpdateRole replaces the name, description and permissions of a role
+func (r *Repository) UpdateRole(ctx context.Context, role *Role) error {
+	err := r.db.QueryRow(ctx, `
+		UPDATE tenant_roles SET name = $3, description = $4, permissions = $5, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING updated_at
+	`, role.TenantID, role.ID, role.Name, role.Description, toStrings(role.Permissions)).Scan(&role.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRoleNotFound
+		}
+		if isUniqueViolation(err) {
+			return ErrRoleNameExists
+		}
+		return fmt.Errorf("update role: %w", err)
+	}
+	return nil
+}
+
+// DeleteRole deletes a custom role with its assignments
+func (r *Repository) DeleteRole(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM tenant_roles WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("delete role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRoleNotFound
+	}
+	return nil
+}
+
+// GetUserRole returns the built-in role of an active user of a tenant
+func (r *Repository) GetUserRole(ctx context.Context, tenantID, userID uuid.UUID) (string, error) {
+	var role string
+	err := r.db.QueryRow(ctx, `
+		SELECT role FROM users WHERE id = $1 AND tenant_id = $2 AND is_active
+	`, userID, tenantID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrUserNotInTenant
+		}
+		return "", fmt.Errorf("get user role: %w", err)
+	}
+	return role, nil
+}
+
+// ListUserRoles returns the custom roles assigned to a user, by name
+func (r *Repository) ListUserRoles(ctx context.Context, tenantID, userID uuid.UUID) ([]*Role, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+roleColumns+`
+		FROM tenant_roles r
+		JOIN user_role_assignments ua ON ua.role_id = r.id
+		WHERE r.tenant_id = $1 AND ua.user_id = $2
+		ORDER BY lower(r.name)
+	`, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list user roles: %w", err)
+	}
+	return scanRoles(rows)
+}
+
+// UserPermissions returns the permissions a user holds through the custom
+// roles assigned to them
+func (r *Repository) UserPermissions(ctx context.Context, tenantID, userID uuid.UUID) ([]Permission, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT unnest(r.permissions)
+		FROM tenant_roles r
+		JOIN user_role_assignments ua ON ua.role_id = r.id
+		WHERE r.tenant_id = $1 AND ua.user_id = $2
+	`, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var perms []Permission
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, fmt.Errorf("scan permission: %w", err)
+		}
+		perms = append(perms, Permission(p))
+	}
+	return perms, rows.Err()
+}
+
+// SetUserRoles replaces the custom roles assigned to a user. The roles
+// must belong to the tenant.
+func (r *Repository) SetUserRoles(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID, assignedBy uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Assignments of roles kept are left as they are, so assigned_at stays
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM user_role_assignments ua
+		USING tenant_roles r
+		WHERE ua.role_id = r.id AND r.tenant_id = $1 AND ua.user_id = $2 AND NOT (ua.role_id = ANY($3))
+	`, tenantID, userID, roleIDs); err != nil {
+		return fmt.Errorf("remove user roles: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO user_role_assignments (user_id, role_id, assigned_by)
+		SELECT $2, r.id, $4 FROM tenant_roles r WHERE r.tenant_id = $1 AND r.id = ANY($3)
+		ON CONFLICT (user_id, role_id) DO NOTHING
+	`, tenantID, userID, roleIDs, assignedBy); err != nil {
+		return fmt.Errorf("assign user roles: %w", err)
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,206 @@
+package permission
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Service manages custom roles and resolves the permissions of users
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new permission service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// CustomPermissions returns the permissions a user holds through custom
+// roles; auth.AuthMiddleware consults it when the built-in role lacks a
+// permission
+func (s *Service) CustomPermissions(ctx context.Context, tenantID, userID string) ([]Permission, error) {
+	tid, err := uuid.Parse(tenantID)
+	if err != nil {
+		return nil, nil
+	}
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, nil
+	}
+	return s.repo.UserPermissions(ctx, tid, uid)
+}
+
+// Effective returns all permissions of a user with a built-in role
+func (s *Service) Effective(ctx context.Context, tenantID, userID uuid.UUID, role string) ([]Permission, error) {
+	custom, err := s.repo.UserPermissions(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return Union(Builtin(role), custom), nil
+}
+
+// ListRoles returns the custom roles of a tenant
+func (s *Service) ListRoles(ctx context.Context, tenantID uuid.UUID) ([]*Role, error) {
+	return s.repo.ListRoles(ctx, tenantID)
+}
+
+// GetRole returns a custom role of a tenant
+func (s *Service) GetRole(ctx context.Context, tenantID, id uuid.UUID) (*Role, error) {
+	return s.repo.GetRole(ctx, tenantID, id)
+}
+
+// CreateRole creates a custom role. The actor must hold every permission
+// of the role.
+func (s *Service) CreateRole(ctx context.Context, tenantID uuid.UUID, actor Actor, input *RoleInput) (*Role, error) {
+	role := &Role{TenantID: tenantID, CreatedBy: &actor.UserID}
+	if err := applyInput(role, input); err != nil {
+		return nil, err
+	}
+	if err := s.requireHolds(ctx, tenantID, actor, role.Permissions); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRole(ctx, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// UpdateRole replaces a custom role. The actor must hold every permission
+// of the role before and after the change.
+func (s *Service) UpdateRole(ctx context.Context, tenantID, id uuid.UUID, actor Actor, input *RoleInput) (*Role, error) {
+	role, err := s.repo.GetRole(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	before := role.Permissions
+	if err := applyInput(role, input); err != nil {
+		return nil, err
+	}
+	if err := s.requireHolds(ctx, tenantID, actor, Union(before, role.Permissions)); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateRole(ctx, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// DeleteRole deletes a custom role and its assignments. The actor must hold
+// every permission of the role.
+func (s *Service) DeleteRole(ctx context.Context, tenantID, id uuid.UUID, actor Actor) error {
+	role, err := s.repo.GetRole(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.requireHolds(ctx, tenantID, actor, role.Permissions); err != nil {
+		return err
+	}
+	return s.repo.DeleteRole(ctx, tenantID, id)
+}
+
+// UserRoles returns the built-in and custom roles of a user of the tenant
+// with the permissions they add up to
+func (s *Service) UserRoles(ctx context.Context, tenantID, userID uuid.UUID) (*UserRoles, error) {
+	role, err := s.repo.GetUserRole(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.repo.ListUserRoles(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := [][]Permission{Builtin(role)}
+	for _, r := range roles {
+		sets = append(sets, r.Permissions)
+	}
+	if roles == nil {
+		roles = []*Role{}
+	}
+	return &UserRoles{UserID: userID, Role: role, Roles: roles, Permissions: Union(sets...)}, nil
+}
+
+// SetUserRoles replaces the custom roles assigned to a user. The actor must
+// hold every permission of the roles assigned or removed.
+func (s *Service) SetUserRoles(ctx context.Context, tenantID, userID uuid.UUID, actor Actor, roleIDs []uuid.UUID) (*UserRoles, error) {
+	current, err := s.UserRoles(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[uuid.UUID]bool, len(roleIDs))
+	var changed []Permission
+	for _, id := range roleIDs {
+		if wanted[id] {
+			continue
+		}
+		wanted[id] = true
+		role, err := s.repo.GetRole(ctx, tenantID, id)
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, role.Permissions...)
+	}
+	for _, r := range current.Roles {
+		if !wanted[r.ID] {
+			changed = append(changed, r.Permissions...)
+		}
+	}
+	if err := s.requireHolds(ctx, tenantID, actor, changed); err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(wanted))
+	for id := range wanted {
+		ids = append(ids, id)
+	}
+	if err := s.repo.SetUserRoles(ctx, tenantID, userID, ids, actor.UserID); err != nil {
+		return nil, err
+	}
+	return s.UserRoles(ctx, tenantID, userID)
+}
+
+// requireHolds checks that the actor holds the permissions, so that custom
+// roles cannot be used to escalate one's own permissions
+func (s *Service) requireHolds(ctx context.Context, tenantID uuid.UUID, actor Actor, perms []Permission) error {
+	if HasAll(Builtin(actor.Role), perms) {
+		return nil
+	}
+	held, err := s.Effective(ctx, tenantID, actor.UserID, actor.Role)
+	if err != nil {
+		return err
+	}
+	if !HasAll(held, perms) {
+		return ErrCannotGrant
+	}
+	return nil
+}
+
+// applyInput validates the input and sets it on the role
+func applyInput(role *Role, input *RoleInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || utf8.RuneCountInString(name) > 100 || IsBuiltinRole(name) {
+		return ErrInvalidRole
+	}
+	if len(input.Permissions) == 0 {
+		return ErrNoPermissions
+	}
+	for _, p := range input.Permissions {
+		if !IsValid(p) {
+			return ErrUnknownPermission
+		}
+	}
+
+	role.Name = name
+	role.Description = nil
+	if input.Description != nil {
+		if d := strings.TrimSpace(*input.Description); d != "" {
+			role.Description = &d
+		}
+	}
+	role.Permissions = Union(input.Permissions)
+	return nil
+}
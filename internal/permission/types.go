@@ -0,0 +1,82 @@
+// Package permission provides granular permissions on top of the user
+// roles. The built-in roles owner, admin, member and viewer are fixed
+// permission bundles; tenants define custom roles as further bundles and
+// assign them to users. A user holds the permissions of their built-in
+// role and of every custom role assigned to them.
+package permission
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleNameExists    = errors.New("a role with this name already exists")
+	ErrInvalidRole       = errors.New("role name is required, at most 100 characters and not a built-in role")
+	ErrNoPermissions     = errors.New("a role needs at least one permission")
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrUserNotInTenant   = errors.New("user is not an active member of this tenant")
+	ErrCannotGrant       = errors.New("cannot grant or revoke permissions you do not hold yourself")
+)
+
+// Permission is a granular permission, named area:action
+type Permission string
+
+const (
+	UVARead       Permission = "uva:read"
+	UVAWrite      Permission = "uva:write"
+	UVASubmit     Permission = "uva:submit"
+	InvoicesRead  Permission = "invoices:read"
+	InvoicesWrite Permission = "invoices:write"
+	InvoicesSend  Permission = "invoices:send"
+	RolesManage   Permission = "roles:manage"
+)
+
+// Definition describes a permission
+type Definition struct {
+	Permission  Permission `json:"permission"`
+	Description string     `json:"description"`
+}
+
+// BuiltinRole is the permission bundle of a built-in role
+type BuiltinRole struct {
+	Role        string       `json:"role"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Role is a custom role of a tenant
+type Role struct {
+	ID          uuid.UUID    `json:"id"`
+	TenantID    uuid.UUID    `json:"tenant_id"`
+	Name        string       `json:"name"`
+	Description *string      `json:"description,omitempty"`
+	Permissions []Permission `json:"permissions"`
+	UserCount   int          `json:"user_count"`
+	CreatedBy   *uuid.UUID   `json:"created_by,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// RoleInput creates or replaces a custom role
+type RoleInput struct {
+	Name        string       `json:"name"`
+	Description *string      `json:"description,omitempty"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Actor is the user changing roles or assignments
+type Actor struct {
+	UserID uuid.UUID
+	Role   string
+}
+
+// UserRoles are the roles of a user and the permissions they add up to
+type UserRoles struct {
+	UserID      uuid.UUID    `json:"user_id"`
+	Role        string       `json:"role"`
+	Roles       []*Role      `json:"roles"`
+	Permissions []Permission `json:"permissions"`
+}
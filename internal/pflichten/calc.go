@@ -0,0 +1,316 @@
+package pflichten
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/kommunalsteuer"
+	"austrian-business-infrastructure/internal/uva"
+)
+
+// Gewicht is added to the score of an obligation by its category: missed
+// tax filings and payments cost Säumniszuschläge, late ELDA Meldungen a
+// penalty, the others "only" money or an opportunity
+var Gewicht = map[string]int{
+	KategorieUVA:     10,
+	KategorieZahlung: 10,
+	KategorieELDA:    5,
+	KategorieVertrag: 5,
+	KategorieAntrag:  0,
+}
+
+// AbgelehntGewicht is added to the score of an obligation whose filing has
+// been rejected
+const AbgelehntGewicht = 10
+
+// Score returns the severity score of an obligation, 0 to 100: the closer
+// the deadline, the higher, plus the weight of the category
+func Score(kategorie string, tageBisFaellig int, abgelehnt bool) int {
+	var score int
+	switch {
+	case tageBisFaellig < 0:
+		score = 90
+	case tageBisFaellig <= 3:
+		score = 70
+	case tageBisFaellig <= 7:
+		score = 55
+	case tageBisFaellig <= 14:
+		score = 40
+	case tageBisFaellig <= 30:
+		score = 25
+	default:
+		score = 10
+	}
+	score += Gewicht[kategorie]
+	if abgelehnt {
+		score += AbgelehntGewicht
+	}
+	if score > 100 {
+		score = 100
+	}
+	return score
+}
+
+// StufeFuer returns the severity tier of a score
+func StufeFuer(score int) string {
+	switch {
+	case score >= 80:
+		return StufeKritisch
+	case score >= 60:
+		return StufeHoch
+	case score >= 40:
+		return StufeMittel
+	default:
+		return StufeNiedrig
+	}
+}
+
+// Bewerten sets the days until the deadline, the score and the tier of an
+// obligation on a day
+func Bewerten(p *Pflicht, today time.Time) {
+	p.Faellig = datum(p.Faellig)
+	p.TageBisFaellig = int(p.Faellig.Sub(datum(today)).Hours() / 24)
+	p.Score = Score(p.Kategorie, p.TageBisFaellig, p.Abgelehnt)
+	p.Stufe = StufeFuer(p.Score)
+}
+
+// Auswerten scores the obligations and groups them per company. Companies
+// are ordered by their most urgent obligation, obligations by score and
+// deadline. Snoozed obligations are left out unless includeSnoozed is set;
+// their number is returned either way.
+func Auswerten(pflichten []*Pflicht, snoozes map[string]time.Time, today time.Time, includeSnoozed bool) ([]*Firma, int) {
+	byAccount := make(map[string]*Firma)
+	var firmen []*Firma
+	snoozed := 0
+	for _, p := range pflichten {
+		if until, ok := snoozes[p.Key]; ok && until.After(today) {
+			snoozed++
+			if !includeSnoozed {
+				continue
+			}
+			u := until
+			p.SnoozedUntil = &u
+		}
+		Bewerten(p, today)
+
+		f, ok := byAccount[p.AccountID.String()]
+		if !ok {
+			f = &Firma{AccountID: p.AccountID, AccountName: p.AccountName, Anzahl: make(map[string]int)}
+			byAccount[p.AccountID.String()] = f
+			firmen = append(firmen, f)
+		}
+		f.Pflichten = append(f.Pflichten, p)
+		f.Anzahl[p.Stufe]++
+		if p.Score > f.Score {
+			f.Score = p.Score
+		}
+	}
+
+	for _, f := range firmen {
+		f.Stufe = StufeFuer(f.Score)
+		sort.SliceStable(f.Pflichten, func(i, j int) bool {
+			a, b := f.Pflichten[i], f.Pflichten[j]
+			if a.Score != b.Score {
+				return a.Score > b.Score
+			}
+			if !a.Faellig.Equal(b.Faellig) {
+				return a.Faellig.Before(b.Faellig)
+			}
+			return a.Key < b.Key
+		})
+	}
+	sort.SliceStable(firmen, func(i, j int) bool {
+		a, b := firmen[i], firmen[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if !a.Pflichten[0].Faellig.Equal(b.Pflichten[0].Faellig) {
+			return a.Pflichten[0].Faellig.Before(b.Pflichten[0].Faellig)
+		}
+		return a.AccountName < b.AccountName
+	})
+	return firmen, snoozed
+}
+
+// UVAPeriode is a monthly or quarterly UVA period
+type UVAPeriode struct {
+	Year    int
+	Month   int
+	Quarter int
+}
+
+// Key formats the period for obligation keys as 2025-03 or 2025-Q1
+func (p UVAPeriode) Key() string {
+	if p.Quarter > 0 {
+		return fmt.Sprintf("%d-Q%d", p.Year, p.Quarter)
+	}
+	return fmt.Sprintf("%d-%02d", p.Year, p.Month)
+}
+
+// Label formats the period as 03/2025 or Q1/2025
+func (p UVAPeriode) Label() string {
+	if p.Quarter > 0 {
+		return fmt.Sprintf("Q%d/%d", p.Quarter, p.Year)
+	}
+	return fmt.Sprintf("%02d/%d", p.Month, p.Year)
+}
+
+// lastMonth returns the last month of the period counted from year 0
+func (p UVAPeriode) lastMonth() int {
+	if p.Quarter > 0 {
+		return p.Year*12 + p.Quarter*3
+	}
+	return p.Year*12 + p.Month
+}
+
+// Faellig returns the deadline of the UVA and its Zahllast: the 15th of the
+// second month after the period, or the next working day
+func (p UVAPeriode) Faellig() time.Time {
+	m := p.lastMonth()
+	return kommunalsteuer.NaechsterWerktag(time.Date(m/12, time.Month(m%12)+2, 15, 0, 0, 0, 0, time.UTC))
+}
+
+// Next returns the following period of the same type
+func (p UVAPeriode) Next() UVAPeriode {
+	if p.Quarter > 0 {
+		if p.Quarter == 4 {
+			return UVAPeriode{Year: p.Year + 1, Quarter: 1}
+		}
+		return UVAPeriode{Year: p.Year, Quarter: p.Quarter + 1}
+	}
+	if p.Month == 12 {
+		return UVAPeriode{Year: p.Year + 1, Month: 1}
+	}
+	return UVAPeriode{Year: p.Year, Month: p.Month + 1}
+}
+
+// UVAPflichten returns the UVA obligations of accounts from their filings,
+// ordered by account and period: filings not yet submitted or rejected,
+// and periods without a filing. Missing periods are those of the type the
+// account filed last, from its first filing of that type up to the last
+// period due by until.
+func UVAPflichten(filings []UVAFiling, until time.Time) []*Pflicht {
+	byAccount := make(map[string][]UVAFiling)
+	var order []string
+	for _, f := range filings {
+		key := f.AccountID.String()
+		if _, ok := byAccount[key]; !ok {
+			order = append(order, key)
+		}
+		byAccount[key] = append(byAccount[key], f)
+	}
+
+	var pflichten []*Pflicht
+	for _, key := range order {
+		list := byAccount[key]
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Periode.lastMonth() < list[j].Periode.lastMonth() })
+
+		latest := list[len(list)-1]
+		quarterly := latest.Periode.Quarter > 0
+		filed := make(map[string]bool, len(list))
+		var first *UVAPeriode
+		for i := range list {
+			f := list[i]
+			filed[f.Periode.Key()] = true
+			if (f.Periode.Quarter > 0) == quarterly && first == nil {
+				first = &list[i].Periode
+			}
+			switch f.Status {
+			case uva.StatusSubmitted, uva.StatusAccepted:
+				continue
+			}
+			if f.Periode.Faellig().After(until) {
+				continue
+			}
+			id := f.SubmissionID
+			p := &Pflicht{
+				Key:         "uva:" + key + ":" + f.Periode.Key(),
+				AccountID:   f.AccountID,
+				AccountName: f.AccountName,
+				Kategorie:   KategorieUVA,
+				Titel:       "UVA " + f.Periode.Label() + " einreichen",
+				Faellig:     f.Periode.Faellig(),
+				QuelleTyp:   QuelleUVA,
+				QuelleID:    &id,
+			}
+			if f.Status == uva.StatusRejected || f.Status == uva.StatusError {
+				p.Titel = "UVA " + f.Periode.Label() + " abgelehnt"
+				p.Hinweis = f.Fehler
+				p.Abgelehnt = true
+			}
+			pflichten = append(pflichten, p)
+		}
+
+		for periode := first.Next(); !periode.Faellig().After(until); periode = periode.Next() {
+			if filed[periode.Key()] {
+				continue
+			}
+			pflichten = append(pflichten, &Pflicht{
+				Key:         "uva:" + key + ":" + periode.Key(),
+				AccountID:   latest.AccountID,
+				AccountName: latest.AccountName,
+				Kategorie:   KategorieUVA,
+				Titel:       "UVA " + periode.Label() + " fehlt",
+				Faellig:     periode.Faellig(),
+				QuelleTyp:   QuelleUVAPeriod,
+			})
+		}
+	}
+	return pflichten
+}
+
+// ELDAFaelligkeit returns the deadline of an ELDA Meldung: an Anmeldung is
+// due before work starts, an Abmeldung within seven days of the end of
+// employment and an Änderung within seven days of the change. Other
+// Meldungen are due seven days after they were drafted.
+func ELDAFaelligkeit(m *ELDAOffen) time.Time {
+	switch elda.MeldungType(m.Type) {
+	case elda.MeldungTypeAnmeldung:
+		if m.Eintritt != nil {
+			return datum(*m.Eintritt)
+		}
+	case elda.MeldungTypeAbmeldung:
+		if m.Austritt != nil {
+			return datum(*m.Austritt).AddDate(0, 0, 7)
+		}
+	case elda.MeldungTypeAenderung:
+		if m.Aenderung != nil {
+			return datum(*m.Aenderung).AddDate(0, 0, 7)
+		}
+	}
+	return datum(m.CreatedAt).AddDate(0, 0, 7)
+}
+
+// ELDAPflicht returns the obligation of an ELDA Meldung not yet accepted
+func ELDAPflicht(m *ELDAOffen) *Pflicht {
+	art := "Meldung"
+	if m.Type != "" {
+		art = strings.ToUpper(m.Type[:1]) + strings.ToLower(m.Type[1:])
+	}
+	id := m.MeldungID
+	p := &Pflicht{
+		Key:         "elda:" + m.MeldungID.String(),
+		AccountID:   m.AccountID,
+		AccountName: m.AccountName,
+		Kategorie:   KategorieELDA,
+		Titel:       strings.TrimSpace("ELDA-" + art + " " + m.Name + " einreichen"),
+		Faellig:     ELDAFaelligkeit(m),
+		QuelleTyp:   QuelleELDA,
+		QuelleID:    &id,
+	}
+	if m.Status == string(elda.MeldungStatusRejected) {
+		p.Titel = strings.TrimSpace("ELDA-" + art + " " + m.Name + " abgelehnt")
+		p.Hinweis = m.Fehler
+		p.Abgelehnt = true
+	}
+	return p
+}
+
+// datum returns the day of t in UTC
+func datum(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
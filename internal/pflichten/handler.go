@@ -0,0 +1,127 @@
+package pflichten
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler handles Pflichten-Radar HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new Pflichten-Radar handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the Pflichten-Radar routes
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/pflichten", requireAuth(http.HandlerFunc(h.Radar)))
+	router.Handle("PUT /api/v1/pflichten/snooze", requireAuth(http.HandlerFunc(h.Snooze)))
+	router.Handle("DELETE /api/v1/pflichten/snooze/{key}", requireAuth(http.HandlerFunc(h.Unsnooze)))
+}
+
+// Radar handles GET /api/v1/pflichten
+func (h *Handler) Radar(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	filter := Filter{TenantID: tenantID}
+	q := r.URL.Query()
+	if accountIDStr := q.Get("account_id"); accountIDStr != "" {
+		accountID, err := uuid.Parse(accountIDStr)
+		if err != nil {
+			api.BadRequest(w, "invalid account_id")
+			return
+		}
+		filter.AccountID = &accountID
+	}
+	if horizonStr := q.Get("horizon_days"); horizonStr != "" {
+		horizon, err := strconv.Atoi(horizonStr)
+		if err != nil {
+			api.BadRequest(w, "invalid horizon_days")
+			return
+		}
+		filter.HorizonDays = horizon
+	}
+	filter.IncludeSnoozed = q.Get("include_snoozed") == "true"
+
+	radar, err := h.service.Radar(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, radar)
+}
+
+// Snooze handles PUT /api/v1/pflichten/snooze
+func (h *Handler) Snooze(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(api.GetUserID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "user not found in context")
+		return
+	}
+
+	var req struct {
+		Key   string `json:"key"`
+		Until string `json:"until"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+	until, err := time.Parse("2006-01-02", req.Until)
+	if err != nil {
+		api.BadRequest(w, "until must be a date (YYYY-MM-DD)")
+		return
+	}
+
+	snooze, err := h.service.Snooze(r.Context(), tenantID, userID, req.Key, until)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, snooze)
+}
+
+// Unsnooze handles DELETE /api/v1/pflichten/snooze/{key}
+func (h *Handler) Unsnooze(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Unsnooze(r.Context(), tenantID, r.PathValue("key")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrSnoozeNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrInvalidKey), errors.Is(err, ErrInvalidUntil), errors.Is(err, ErrInvalidHorizon):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	default:
+		api.InternalError(w)
+	}
+}
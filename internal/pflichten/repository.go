@@ -0,0 +1,305 @@
+package pflichten
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/internal/uva"
+)
+
+// Repository reads the obligations of a tenant from the modules they come
+// from and stores the snoozes
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new obligations repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// accountFilter appends the optional account condition on column to args
+func accountFilter(column string, accountID *uuid.UUID, args []interface{}) (string, []interface{}) {
+	if accountID == nil {
+		return "", args
+	}
+	args = append(args, *accountID)
+	return fmt.Sprintf(" AND %s = $%d", column, len(args)), args
+}
+
+// ListUVAFilings returns the latest UVA of each period of the tenant's
+// FinanzOnline accounts since a year
+func (r *Repository) ListUVAFilings(ctx context.Context, tenantID uuid.UUID, accountID *uuid.UUID, sinceYear int) ([]UVAFiling, error) {
+	cond, args := accountFilter("a.id", accountID, []interface{}{tenantID, sinceYear})
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT ON (u.account_id, u.period_year, u.period_type, COALESCE(u.period_month, u.period_quarter))
+			a.id, a.name, u.id, u.period_year, u.period_type, u.period_month, u.period_quarter,
+			u.status, COALESCE(u.fo_error, '')
+		FROM uva_submissions u
+		JOIN accounts a ON a.id = u.account_id
+		WHERE a.tenant_id = $1 AND u.period_year >= $2`+cond+`
+		ORDER BY u.account_id, u.period_year, u.period_type, COALESCE(u.period_month, u.period_quarter),
+			u.created_at DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list UVA filings: %w", err)
+	}
+	defer rows.Close()
+
+	var filings []UVAFiling
+	for rows.Next() {
+		var f UVAFiling
+		var periodType string
+		var month, quarter *int
+		if err := rows.Scan(&f.AccountID, &f.AccountName, &f.SubmissionID, &f.Periode.Year, &periodType,
+			&month, &quarter, &f.Status, &f.Fehler); err != nil {
+			return nil, fmt.Errorf("failed to scan UVA filing: %w", err)
+		}
+		if periodType == uva.PeriodTypeMonthly && month != nil {
+			f.Periode.Month = *month
+		} else if quarter != nil {
+			f.Periode.Quarter = *quarter
+		} else {
+			continue
+		}
+		filings = append(filings, f)
+	}
+	return filings, rows.Err()
+}
+
+// ListELDAOffen returns the ELDA Meldungen of the tenant not yet submitted
+// or rejected
+func (r *Repository) ListELDAOffen(ctx context.Context, tenantID uuid.UUID, accountID *uuid.UUID) ([]*ELDAOffen, error) {
+	cond, args := accountFilter("a.id", accountID, []interface{}{tenantID})
+	rows, err := r.db.Query(ctx, `
+		SELECT m.id, a.id, a.name, m.type, m.status,
+			TRIM(COALESCE(m.vorname, '') || ' ' || COALESCE(m.nachname, '')),
+			m.eintrittsdatum, m.austrittsdatum, m.aenderung_datum, m.created_at, COALESCE(m.error_message, '')
+		FROM elda_meldungen m
+		JOIN elda_accounts ea ON ea.id = m.elda_account_id
+		JOIN accounts a ON a.id = ea.account_id
+		WHERE a.tenant_id = $1 AND m.status IN ('draft', 'validated', 'rejected')`+cond+`
+		ORDER BY m.created_at`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open ELDA Meldungen: %w", err)
+	}
+	defer rows.Close()
+
+	var offen []*ELDAOffen
+	for rows.Next() {
+		var m ELDAOffen
+		if err := rows.Scan(&m.MeldungID, &m.AccountID, &m.AccountName, &m.Type, &m.Status, &m.Name,
+			&m.Eintritt, &m.Austritt, &m.Aenderung, &m.CreatedAt, &m.Fehler); err != nil {
+			return nil, fmt.Errorf("failed to scan ELDA Meldung: %w", err)
+		}
+		offen = append(offen, &m)
+	}
+	return offen, rows.Err()
+}
+
+// KU1Offen is a quarter with UVA filings whose KU1 has not been submitted
+type KU1Offen struct {
+	AccountID   uuid.UUID
+	AccountName string
+	Year        int
+	Quarter     int
+}
+
+// ListKU1Offen returns the quarters since a year in which the tenant's
+// accounts filed a UVA but have not submitted the KU1
+func (r *Repository) ListKU1Offen(ctx context.Context, tenantID uuid.UUID, accountID *uuid.UUID, sinceYear int) ([]KU1Offen, error) {
+	cond, args := accountFilter("a.id", accountID, []interface{}{tenantID, sinceYear})
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT a.id, a.name, u.period_year, COALESCE(u.period_quarter, (u.period_month + 2) / 3) AS quarter
+		FROM accounts a
+		JOIN uva_submissions u ON u.account_id = a.id AND u.period_year >= $2
+			AND u.status IN ('submitted', 'accepted')
+		WHERE a.tenant_id = $1`+cond+` AND NOT EXISTS (
+			SELECT 1 FROM kammerumlage_meldungen k
+			WHERE k.account_id = a.id AND k.year = u.period_year
+				AND k.quarter = COALESCE(u.period_quarter, (u.period_month + 2) / 3) AND k.status = 'submitted')
+		ORDER BY a.id, u.period_year, quarter`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open KU1: %w", err)
+	}
+	defer rows.Close()
+
+	var offen []KU1Offen
+	for rows.Next() {
+		var o KU1Offen
+		if err := rows.Scan(&o.AccountID, &o.AccountName, &o.Year, &o.Quarter); err != nil {
+			return nil, fmt.Errorf("failed to scan open KU1: %w", err)
+		}
+		offen = append(offen, o)
+	}
+	return offen, rows.Err()
+}
+
+// ListZahlungsfristen returns the active payment deadlines extracted from
+// the documents of the tenant's accounts up to a date, passed ones
+// included, leaving out documents the viewer of ctx may not see
+func (r *Repository) ListZahlungsfristen(ctx context.Context, tenantID uuid.UUID, accountID *uuid.UUID, until time.Time) ([]*Pflicht, error) {
+	cond, args := accountFilter("a.id", accountID, []interface{}{tenantID, until})
+	access, args := document.AccessCondition(ctx, "d", args)
+	rows, err := r.db.Query(ctx, `
+		SELECT ed.id, a.id, a.name, COALESCE(NULLIF(d.title, ''), d.type), ed.deadline_date,
+			COALESCE(ed.calculation_rule, '')
+		FROM extracted_deadlines ed
+		JOIN documents d ON d.id = ed.document_id
+		JOIN accounts a ON a.id = d.account_id
+		WHERE ed.tenant_id = $1 AND ed.deadline_type = 'payment' AND ed.status IN ('active', 'overdue')
+			AND ed.deadline_date <= $2`+cond+access+`
+		ORDER BY ed.deadline_date`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment deadlines: %w", err)
+	}
+	defer rows.Close()
+
+	var pflichten []*Pflicht
+	for rows.Next() {
+		var id uuid.UUID
+		var titel string
+		p := &Pflicht{Kategorie: KategorieZahlung, QuelleTyp: QuelleFrist}
+		if err := rows.Scan(&id, &p.AccountID, &p.AccountName, &titel, &p.Faellig, &p.Hinweis); err != nil {
+			return nil, fmt.Errorf("failed to scan payment deadline: %w", err)
+		}
+		p.Key = "zahlung:" + id.String()
+		p.Titel = "Zahlung: " + titel
+		p.QuelleID = &id
+		pflichten = append(pflichten, p)
+	}
+	return pflichten, rows.Err()
+}
+
+// ListKuendigungsfristen returns the active contracts of the tenant's
+// accounts whose notice has to be given between two dates, leaving out
+// those of documents the viewer of ctx may not see
+func (r *Repository) ListKuendigungsfristen(ctx context.Context, tenantID uuid.UUID, accountID *uuid.UUID, from, until time.Time) ([]*Pflicht, error) {
+	cond, args := accountFilter("a.id", accountID, []interface{}{tenantID, from, until})
+	access, args := document.AccessCondition(ctx, "d", args)
+	rows, err := r.db.Query(ctx, `
+		SELECT c.id, a.id, a.name, c.title, c.cancel_by, COALESCE(c.counterparty, '')
+		FROM contracts c
+		JOIN documents d ON d.id = c.document_id
+		JOIN accounts a ON a.id = d.account_id
+		WHERE c.tenant_id = $1 AND c.status = 'active' AND c.cancel_by BETWEEN $2 AND $3`+cond+access+`
+		ORDER BY c.cancel_by`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notice periods: %w", err)
+	}
+	defer rows.Close()
+
+	var pflichten []*Pflicht
+	for rows.Next() {
+		var id uuid.UUID
+		var titel string
+		p := &Pflicht{Kategorie: KategorieVertrag, QuelleTyp: QuelleVertrag}
+		if err := rows.Scan(&id, &p.AccountID, &p.AccountName, &titel, &p.Faellig, &p.Hinweis); err != nil {
+			return nil, fmt.Errorf("failed to scan contract: %w", err)
+		}
+		p.Key = "vertrag:" + id.String()
+		p.Titel = "Kündigungsfrist: " + titel
+		p.QuelleID = &id
+		pflichten = append(pflichten, p)
+	}
+	return pflichten, rows.Err()
+}
+
+// ListAntragFristen returns the Förderungsanträge of the tenant's accounts
+// that need action by a date: planned and drafting ones by the program's
+// Einreichfrist, submitted ones without a decision NachfassTage after
+// their submission
+func (r *Repository) ListAntragFristen(ctx context.Context, tenantID uuid.UUID, accountID *uuid.UUID, until time.Time) ([]*Pflicht, error) {
+	cond, args := accountFilter("a.id", accountID, []interface{}{tenantID, until, NachfassTage})
+	rows, err := r.db.Query(ctx, `
+		SELECT * FROM (
+			SELECT fa.id, a.id AS account_id, a.name, f.name AS program, fa.status,
+				CASE WHEN fa.status IN ('planned', 'drafting')
+					THEN COALESCE(f.application_deadline, f.call_end)
+					ELSE (COALESCE(fa.status_changed_at, fa.updated_at) + make_interval(days => $3))::date
+				END AS faellig
+			FROM foerderungs_antraege fa
+			JOIN foerderungen f ON f.id = fa.foerderung_id
+			JOIN accounts a ON a.id = fa.account_id
+			WHERE fa.tenant_id = $1 AND fa.status IN ('planned', 'drafting', 'submitted', 'in_review')`+cond+`
+		) antraege
+		WHERE faellig <= $2
+		ORDER BY faellig`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Antrag follow-ups: %w", err)
+	}
+	defer rows.Close()
+
+	var pflichten []*Pflicht
+	for rows.Next() {
+		var id uuid.UUID
+		var program, status string
+		p := &Pflicht{Kategorie: KategorieAntrag, QuelleTyp: QuelleAntrag}
+		if err := rows.Scan(&id, &p.AccountID, &p.AccountName, &program, &status, &p.Faellig); err != nil {
+			return nil, fmt.Errorf("failed to scan Antrag: %w", err)
+		}
+		if status == "planned" || status == "drafting" {
+			p.Key = "antrag:" + id.String() + ":einreichen"
+			p.Titel = "Förderantrag " + program + " einreichen"
+		} else {
+			p.Key = "antrag:" + id.String() + ":nachfassen"
+			p.Titel = "Förderantrag " + program + " nachfassen"
+		}
+		p.QuelleID = &id
+		pflichten = append(pflichten, p)
+	}
+	return pflichten, rows.Err()
+}
+
+// ListSnoozes returns the snoozes of a tenant still running on a day, by
+// obligation key
+func (r *Repository) ListSnoozes(ctx context.Context, tenantID uuid.UUID, today time.Time) (map[string]time.Time, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT pflicht_key, until FROM pflichten_snoozes WHERE tenant_id = $1 AND until > $2`, tenantID, today)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snoozes: %w", err)
+	}
+	defer rows.Close()
+
+	snoozes := make(map[string]time.Time)
+	for rows.Next() {
+		var key string
+		var until time.Time
+		if err := rows.Scan(&key, &until); err != nil {
+			return nil, fmt.Errorf("failed to scan snooze: %w", err)
+		}
+		snoozes[key] = until
+	}
+	return snoozes, rows.Err()
+}
+
+// SaveSnooze snoozes an obligation, replacing an earlier snooze of it
+func (r *Repository) SaveSnooze(ctx context.Context, tenantID uuid.UUID, s *Snooze) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO pflichten_snoozes (tenant_id, pflicht_key, until, created_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, pflicht_key) DO UPDATE SET
+			until = EXCLUDED.until, created_by = EXCLUDED.created_by, created_at = NOW()
+		RETURNING created_at`, tenantID, s.Key, s.Until, s.CreatedBy).Scan(&s.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save snooze: %w", err)
+	}
+	return nil
+}
+
+// DeleteSnooze ends the snooze of an obligation
+func (r *Repository) DeleteSnooze(ctx context.Context, tenantID uuid.UUID, key string) error {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM pflichten_snoozes WHERE tenant_id = $1 AND pflicht_key = $2`, tenantID, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete snooze: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSnoozeNotFound
+	}
+	return nil
+}
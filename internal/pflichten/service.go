@@ -0,0 +1,150 @@
+package pflichten
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/kammerumlage"
+	"austrian-business-infrastructure/internal/timezone"
+)
+
+// Service builds the Pflichten-Radar
+type Service struct {
+	repo      *Repository
+	timezones timezone.Source
+	now       func() time.Time
+}
+
+// NewService creates a new obligations service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// SetTimezones sets the source of the tenants' time zones the radar's
+// today is decided in; Europe/Vienna if not set
+func (s *Service) SetTimezones(src timezone.Source) {
+	s.timezones = src
+}
+
+// today returns the calendar day of a tenant
+func (s *Service) today(ctx context.Context, tenantID uuid.UUID) time.Time {
+	return timezone.Today(s.now(), timezone.Of(ctx, s.timezones, tenantID))
+}
+
+// Radar returns the outstanding obligations of the tenant's companies due
+// within the horizon, overdue ones included, scored and grouped per
+// company. Periods of the year before are considered so that a missed
+// December UVA still shows in January.
+func (s *Service) Radar(ctx context.Context, filter Filter) (*Radar, error) {
+	if filter.HorizonDays == 0 {
+		filter.HorizonDays = DefaultHorizonDays
+	}
+	if filter.HorizonDays < 1 || filter.HorizonDays > 365 {
+		return nil, ErrInvalidHorizon
+	}
+	today := s.today(ctx, filter.TenantID)
+	until := today.AddDate(0, 0, filter.HorizonDays)
+	sinceYear := today.Year() - 1
+
+	filings, err := s.repo.ListUVAFilings(ctx, filter.TenantID, filter.AccountID, sinceYear)
+	if err != nil {
+		return nil, err
+	}
+	pflichten := UVAPflichten(filings, until)
+
+	meldungen, err := s.repo.ListELDAOffen(ctx, filter.TenantID, filter.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range meldungen {
+		if p := ELDAPflicht(m); !p.Faellig.After(until) {
+			pflichten = append(pflichten, p)
+		}
+	}
+
+	ku1, err := s.repo.ListKU1Offen(ctx, filter.TenantID, filter.AccountID, sinceYear)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range ku1 {
+		faellig := kammerumlage.Faelligkeit(o.Year, o.Quarter)
+		if faellig.After(until) {
+			continue
+		}
+		periode := UVAPeriode{Year: o.Year, Quarter: o.Quarter}
+		pflichten = append(pflichten, &Pflicht{
+			Key:         "ku1:" + o.AccountID.String() + ":" + periode.Key(),
+			AccountID:   o.AccountID,
+			AccountName: o.AccountName,
+			Kategorie:   KategorieZahlung,
+			Titel:       "KU1 " + periode.Label() + " melden und bezahlen",
+			Faellig:     faellig,
+			QuelleTyp:   QuelleKU1,
+		})
+	}
+
+	zahlungen, err := s.repo.ListZahlungsfristen(ctx, filter.TenantID, filter.AccountID, until)
+	if err != nil {
+		return nil, err
+	}
+	pflichten = append(pflichten, zahlungen...)
+
+	// The worker rolls passed notice periods forward, so only upcoming
+	// ones are obligations
+	vertraege, err := s.repo.ListKuendigungsfristen(ctx, filter.TenantID, filter.AccountID, today, until)
+	if err != nil {
+		return nil, err
+	}
+	pflichten = append(pflichten, vertraege...)
+
+	antraege, err := s.repo.ListAntragFristen(ctx, filter.TenantID, filter.AccountID, until)
+	if err != nil {
+		return nil, err
+	}
+	pflichten = append(pflichten, antraege...)
+
+	snoozes, err := s.repo.ListSnoozes(ctx, filter.TenantID, today)
+	if err != nil {
+		return nil, err
+	}
+	firmen, snoozed := Auswerten(pflichten, snoozes, today, filter.IncludeSnoozed)
+	radar := &Radar{
+		Stichtag:    today,
+		HorizonDays: filter.HorizonDays,
+		Snoozed:     snoozed,
+		Firmen:      firmen,
+	}
+	if radar.Firmen == nil {
+		radar.Firmen = []*Firma{}
+	}
+	for _, f := range radar.Firmen {
+		radar.Total += len(f.Pflichten)
+	}
+	return radar, nil
+}
+
+// Snooze hides an obligation from the radar until a date. Obligations are
+// identified by their key only, so one that is gone by then simply stays
+// hidden.
+func (s *Service) Snooze(ctx context.Context, tenantID, userID uuid.UUID, key string, until time.Time) (*Snooze, error) {
+	if key == "" || len(key) > 200 {
+		return nil, ErrInvalidKey
+	}
+	until, today := datum(until), s.today(ctx, tenantID)
+	if !until.After(today) || until.After(today.AddDate(1, 0, 0)) {
+		return nil, ErrInvalidUntil
+	}
+
+	snooze := &Snooze{Key: key, Until: until, CreatedBy: &userID}
+	if err := s.repo.SaveSnooze(ctx, tenantID, snooze); err != nil {
+		return nil, err
+	}
+	return snooze, nil
+}
+
+// Unsnooze shows a snoozed obligation on the radar again
+func (s *Service) Unsnooze(ctx context.Context, tenantID uuid.UUID, key string) error {
+	return s.repo.DeleteSnooze(ctx, tenantID, key)
+}
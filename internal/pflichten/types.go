@@ -0,0 +1,134 @@
+// Package pflichten combines the outstanding obligations of the companies
+// of a tenant into one prioritized list, the Pflichten-Radar: UVA filings,
+// ELDA Meldungen, payment deadlines, notice periods of contracts and
+// follow-ups of Förderungsanträge. Each obligation is scored by how close
+// its deadline is and can be snoozed until a later date.
+package pflichten
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidKey     = errors.New("key is required and must be at most 200 characters")
+	ErrInvalidUntil   = errors.New("until must be after today and at most one year ahead")
+	ErrInvalidHorizon = errors.New("horizon_days must be between 1 and 365")
+	ErrSnoozeNotFound = errors.New("snooze not found")
+)
+
+// Categories of an obligation
+const (
+	KategorieUVA     = "uva"
+	KategorieELDA    = "elda"
+	KategorieZahlung = "zahlung"
+	KategorieVertrag = "vertrag"
+	KategorieAntrag  = "antrag"
+)
+
+// Severity tiers
+const (
+	StufeKritisch = "kritisch"
+	StufeHoch     = "hoch"
+	StufeMittel   = "mittel"
+	StufeNiedrig  = "niedrig"
+)
+
+// Sources of an obligation
+const (
+	QuelleUVA       = "uva_submission"
+	QuelleELDA      = "elda_meldung"
+	QuelleKU1       = "kammerumlage"
+	QuelleFrist     = "extracted_deadline"
+	QuelleVertrag   = "contract"
+	QuelleAntrag    = "foerderungs_antrag"
+	QuelleUVAPeriod = "uva_period"
+)
+
+// DefaultHorizonDays is how far ahead the radar looks without a horizon
+const DefaultHorizonDays = 30
+
+// NachfassTage is the number of days after which a submitted Antrag
+// without a decision should be followed up with the Förderstelle
+const NachfassTage = 30
+
+// Pflicht is an outstanding obligation of a company. The key identifies it
+// across requests and is what a snooze refers to.
+type Pflicht struct {
+	Key            string     `json:"key"`
+	AccountID      uuid.UUID  `json:"account_id"`
+	AccountName    string     `json:"account_name"`
+	Kategorie      string     `json:"kategorie"`
+	Titel          string     `json:"titel"`
+	Hinweis        string     `json:"hinweis,omitempty"`
+	Faellig        time.Time  `json:"faellig"`
+	TageBisFaellig int        `json:"tage_bis_faellig"`
+	Abgelehnt      bool       `json:"abgelehnt"`
+	Score          int        `json:"score"`
+	Stufe          string     `json:"stufe"`
+	QuelleTyp      string     `json:"quelle_typ"`
+	QuelleID       *uuid.UUID `json:"quelle_id,omitempty"`
+	SnoozedUntil   *time.Time `json:"snoozed_until,omitempty"`
+}
+
+// Firma is a company with its obligations, the most urgent first
+type Firma struct {
+	AccountID   uuid.UUID      `json:"account_id"`
+	AccountName string         `json:"account_name"`
+	Score       int            `json:"score"`
+	Stufe       string         `json:"stufe"`
+	Anzahl      map[string]int `json:"anzahl"`
+	Pflichten   []*Pflicht     `json:"pflichten"`
+}
+
+// Radar is the Pflichten-Radar of a tenant on a day
+type Radar struct {
+	Stichtag    time.Time `json:"stichtag"`
+	HorizonDays int       `json:"horizon_days"`
+	Total       int       `json:"total"`
+	Snoozed     int       `json:"snoozed"`
+	Firmen      []*Firma  `json:"firmen"`
+}
+
+// Filter selects the obligations of the radar
+type Filter struct {
+	TenantID       uuid.UUID
+	AccountID      *uuid.UUID
+	HorizonDays    int
+	IncludeSnoozed bool
+}
+
+// Snooze hides an obligation from the radar until a date
+type Snooze struct {
+	Key       string     `json:"key"`
+	Until     time.Time  `json:"until"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// UVAFiling is the latest UVA of an account for a period
+type UVAFiling struct {
+	AccountID    uuid.UUID
+	AccountName  string
+	SubmissionID uuid.UUID
+	Periode      UVAPeriode
+	Status       string
+	Fehler       string
+}
+
+// ELDAOffen is an ELDA Meldung not yet accepted
+type ELDAOffen struct {
+	MeldungID   uuid.UUID
+	AccountID   uuid.UUID
+	AccountName string
+	Type        string
+	Status      string
+	Name        string
+	Eintritt    *time.Time
+	Austritt    *time.Time
+	Aenderung   *time.Time
+	CreatedAt   time.Time
+	Fehler      string
+}
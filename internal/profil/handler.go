@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -339,8 +340,8 @@ func toProfileResponse(p *foerderung.Unternehmensprofil) *ProfileResponse {
 		CompanyAgeCategory: p.CompanyAgeCategory,
 		Status:             p.Status,
 		DerivedFromAccount: p.DerivedFromAccount,
-		CreatedAt:          p.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:          p.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		CreatedAt:          p.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:          p.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 
 	if p.AccountID != nil {
@@ -348,7 +349,7 @@ func toProfileResponse(p *foerderung.Unternehmensprofil) *ProfileResponse {
 		resp.AccountID = &s
 	}
 	if p.LastSearchAt != nil {
-		s := p.LastSearchAt.Format("2006-01-02T15:04:05Z")
+		s := p.LastSearchAt.UTC().Format(time.RFC3339)
 		resp.LastSearchAt = &s
 	}
 
@@ -0,0 +1,529 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/pkg/money"
+	"github.com/google/uuid"
+)
+
+// Handler handles project HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new project handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers project routes
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	// Admin-only: manage projects and cost centers
+	router.Handle("POST /api/v1/projects", requireAuth(requireAdmin(http.HandlerFunc(h.Create))))
+	router.Handle("PUT /api/v1/projects/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Update))))
+	router.Handle("DELETE /api/v1/projects/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Delete))))
+
+	// Member access: read, book time and expenses, reports
+	router.Handle("GET /api/v1/projects", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("GET /api/v1/projects/profitability", requireAuth(http.HandlerFunc(h.ProfitabilityAll)))
+	router.Handle("GET /api/v1/projects/{id}", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("GET /api/v1/projects/{id}/profitability", requireAuth(http.HandlerFunc(h.Profitability)))
+
+	router.Handle("GET /api/v1/projects/{id}/time-entries", requireAuth(http.HandlerFunc(h.ListTimeEntries)))
+	router.Handle("POST /api/v1/projects/{id}/time-entries", requireAuth(http.HandlerFunc(h.BookTime)))
+	router.Handle("DELETE /api/v1/time-entries/{id}", requireAuth(http.HandlerFunc(h.DeleteTimeEntry)))
+	router.Handle("GET /api/v1/time-entries", requireAuth(http.HandlerFunc(h.ListTimeEntries)))
+
+	router.Handle("GET /api/v1/projects/{id}/expenses", requireAuth(http.HandlerFunc(h.ListExpenses)))
+	router.Handle("POST /api/v1/projects/{id}/expenses", requireAuth(http.HandlerFunc(h.BookExpense)))
+	router.Handle("DELETE /api/v1/expenses/{id}", requireAuth(http.HandlerFunc(h.DeleteExpense)))
+	router.Handle("GET /api/v1/expenses", requireAuth(http.HandlerFunc(h.ListExpenses)))
+}
+
+// Create handles POST /api/v1/projects
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := h.getIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	var input CreateProjectInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+	if input.Code == "" {
+		api.BadRequest(w, "code is required")
+		return
+	}
+	if input.Name == "" {
+		api.BadRequest(w, "name is required")
+		return
+	}
+
+	p, err := h.service.Create(r.Context(), tenantID, userID, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, toResponse(p))
+}
+
+// List handles GET /api/v1/projects
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	filter := ListFilter{TenantID: tenantID, Limit: 50}
+	q := r.URL.Query()
+	if kind := q.Get("kind"); kind != "" {
+		filter.Kind = &kind
+	}
+	if status := q.Get("status"); status != "" {
+		filter.Status = &status
+	}
+	if search := q.Get("search"); search != "" {
+		filter.Search = &search
+	}
+	filter.Limit, filter.Offset = parsePagination(r)
+
+	projects, total, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	items := make([]*ProjectResponse, 0, len(projects))
+	for _, p := range projects {
+		items = append(items, toResponse(p))
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  items,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// Get handles GET /api/v1/projects/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.getTenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := h.service.Get(r.Context(), id, tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, toResponse(p))
+}
+
+// Update handles PUT /api/v1/projects/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.getTenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	var input UpdateProjectInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	p, err := h.service.Update(r.Context(), id, tenantID, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, toResponse(p))
+}
+
+// Delete handles DELETE /api/v1/projects/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.getTenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, tenantID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BookTime handles POST /api/v1/projects/{id}/time-entries
+func (h *Handler) BookTime(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := h.getIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	projectID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid project ID")
+		return
+	}
+
+	var input TimeEntryInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	entry, err := h.service.BookTime(r.Context(), projectID, tenantID, userID, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, entry)
+}
+
+// ListTimeEntries handles GET /api/v1/projects/{id}/time-entries and GET /api/v1/time-entries
+func (h *Handler) ListTimeEntries(w http.ResponseWriter, r *http.Request) {
+	filter, ok := h.entryFilter(w, r)
+	if !ok {
+		return
+	}
+
+	entries, total, err := h.service.ListTimeEntries(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if entries == nil {
+		entries = []*TimeEntry{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  entries,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// DeleteTimeEntry handles DELETE /api/v1/time-entries/{id}
+func (h *Handler) DeleteTimeEntry(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.getTenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTimeEntry(r.Context(), id, tenantID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BookExpense handles POST /api/v1/projects/{id}/expenses
+func (h *Handler) BookExpense(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := h.getIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	projectID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid project ID")
+		return
+	}
+
+	var input ExpenseInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	expense, err := h.service.BookExpense(r.Context(), projectID, tenantID, userID, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, expense)
+}
+
+// ListExpenses handles GET /api/v1/projects/{id}/expenses and GET /api/v1/expenses
+func (h *Handler) ListExpenses(w http.ResponseWriter, r *http.Request) {
+	filter, ok := h.entryFilter(w, r)
+	if !ok {
+		return
+	}
+
+	expenses, total, err := h.service.ListExpenses(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if expenses == nil {
+		expenses = []*Expense{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  expenses,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// DeleteExpense handles DELETE /api/v1/expenses/{id}
+func (h *Handler) DeleteExpense(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.getTenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteExpense(r.Context(), id, tenantID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Profitability handles GET /api/v1/projects/{id}/profitability
+func (h *Handler) Profitability(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := h.getTenantAndID(w, r)
+	if !ok {
+		return
+	}
+
+	from, to := parseDateRange(r)
+	reports, err := h.service.Profitability(r.Context(), tenantID, &id, from, to)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, toProfitabilityResponse(reports[0]))
+}
+
+// ProfitabilityAll handles GET /api/v1/projects/profitability
+func (h *Handler) ProfitabilityAll(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	from, to := parseDateRange(r)
+	reports, err := h.service.Profitability(r.Context(), tenantID, nil, from, to)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	items := make([]*ProfitabilityResponse, 0, len(reports))
+	for _, rep := range reports {
+		items = append(items, toProfitabilityResponse(rep))
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items": items,
+	})
+}
+
+// Helper methods
+
+func (h *Handler) getTenantID(r *http.Request) (uuid.UUID, error) {
+	tenantIDStr := api.GetTenantID(r.Context())
+	if tenantIDStr == "" {
+		return uuid.Nil, ErrProjectNotFound
+	}
+	return uuid.Parse(tenantIDStr)
+}
+
+func (h *Handler) getIdentity(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(api.GetUserID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "user not found in context")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return tenantID, userID, true
+}
+
+func (h *Handler) getTenantAndID(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return tenantID, id, true
+}
+
+func (h *Handler) entryFilter(w http.ResponseWriter, r *http.Request) (EntryFilter, bool) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return EntryFilter{}, false
+	}
+
+	filter := EntryFilter{TenantID: tenantID}
+	q := r.URL.Query()
+
+	projectIDStr := r.PathValue("id")
+	if projectIDStr == "" {
+		projectIDStr = q.Get("project_id")
+	}
+	if projectIDStr != "" {
+		projectID, err := uuid.Parse(projectIDStr)
+		if err != nil {
+			api.BadRequest(w, "invalid project ID")
+			return EntryFilter{}, false
+		}
+		filter.ProjectID = &projectID
+	}
+	if userIDStr := q.Get("user_id"); userIDStr != "" {
+		if userID, err := uuid.Parse(userIDStr); err == nil {
+			filter.UserID = &userID
+		}
+	}
+	filter.DateFrom, filter.DateTo = parseDateRange(r)
+	filter.Limit, filter.Offset = parsePagination(r)
+
+	return filter, true
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrProjectNotFound):
+		api.NotFound(w, "project not found")
+	case errors.Is(err, ErrEntryNotFound):
+		api.NotFound(w, "entry not found")
+	case errors.Is(err, ErrDuplicateCode):
+		api.Conflict(w, "project code already exists")
+	case errors.Is(err, ErrProjectClosed):
+		api.Conflict(w, "project is closed")
+	case errors.Is(err, ErrInvalidKind):
+		api.BadRequest(w, "kind must be 'project' or 'cost_center'")
+	case errors.Is(err, ErrInvalidStatus):
+		api.BadRequest(w, "status must be 'active' or 'closed'")
+	case errors.Is(err, ErrInvalidCategory):
+		api.BadRequest(w, "invalid expense category")
+	case errors.Is(err, ErrInvalidMinutes):
+		api.BadRequest(w, "minutes must be between 1 and 1440")
+	case errors.Is(err, ErrInvalidAmount):
+		api.BadRequest(w, "amount must be positive")
+	default:
+		api.InternalError(w)
+	}
+}
+
+func parsePagination(r *http.Request) (int, int) {
+	limit, offset := 50, 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+	return limit, offset
+}
+
+func parseDateRange(r *http.Request) (*time.Time, *time.Time) {
+	var from, to *time.Time
+	if fromStr := r.URL.Query().Get("date_from"); fromStr != "" {
+		if d, err := time.Parse("2006-01-02", fromStr); err == nil {
+			from = &d
+		}
+	}
+	if toStr := r.URL.Query().Get("date_to"); toStr != "" {
+		if d, err := time.Parse("2006-01-02", toStr); err == nil {
+			to = &d
+		}
+	}
+	return from, to
+}
+
+func centsPtr(v *int64) *money.Money {
+	if v == nil {
+		return nil
+	}
+	m := money.EUR(*v)
+	return &m
+}
+
+func toResponse(p *Project) *ProjectResponse {
+	resp := &ProjectResponse{
+		ID:           p.ID,
+		Code:         p.Code,
+		Name:         p.Name,
+		Kind:         p.Kind,
+		ClientName:   p.ClientName,
+		Description:  p.Description,
+		BudgetAmount: centsPtr(p.BudgetAmount),
+		HourlyRate:   centsPtr(p.HourlyRate),
+		Status:       p.Status,
+		CreatedAt:    p.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:    p.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if p.StartDate != nil {
+		d := p.StartDate.Format("2006-01-02")
+		resp.StartDate = &d
+	}
+	if p.EndDate != nil {
+		d := p.EndDate.Format("2006-01-02")
+		resp.EndDate = &d
+	}
+	return resp
+}
+
+func toProfitabilityResponse(rep *Profitability) *ProfitabilityResponse {
+	return &ProfitabilityResponse{
+		ProjectID:         rep.ProjectID,
+		Code:              rep.Code,
+		Name:              rep.Name,
+		Kind:              rep.Kind,
+		InvoicedNet:       money.EUR(rep.InvoicedNet),
+		PaymentsOut:       money.EUR(rep.PaymentsOut),
+		LaborCost:         money.EUR(rep.LaborCost),
+		ExpenseCost:       money.EUR(rep.ExpenseCost),
+		TotalCost:         money.EUR(rep.TotalCost),
+		Margin:            money.EUR(rep.Margin),
+		MarginPercent:     rep.MarginPercent,
+		HoursTotal:        float64(rep.MinutesTotal) / 60,
+		HoursBillable:     float64(rep.MinutesBillable) / 60,
+		BudgetAmount:      centsPtr(rep.BudgetAmount),
+		BudgetUsedPercent: rep.BudgetUsed,
+	}
+}
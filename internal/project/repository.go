@@ -0,0 +1,461 @@
+package project
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrProjectNotFound = errors.New("project not found")
+	ErrDuplicateCode   = errors.New("project code already exists")
+	ErrEntryNotFound   = errors.New("entry not found")
+)
+
+// Repository handles project database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new project repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Create creates a new project
+func (r *Repository) Create(ctx context.Context, p *Project) (*Project, error) {
+	p.ID = uuid.New()
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = p.CreatedAt
+	if p.Status == "" {
+		p.Status = StatusActive
+	}
+
+	query := `
+		INSERT INTO projects (
+			id, tenant_id, code, name, kind, client_name, description,
+			budget_amount, hourly_rate, start_date, end_date, status,
+			created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+	_, err := r.db.Exec(ctx, query,
+		p.ID, p.TenantID, p.Code, p.Name, p.Kind, p.ClientName, p.Description,
+		p.BudgetAmount, p.HourlyRate, p.StartDate, p.EndDate, p.Status,
+		p.CreatedBy, p.CreatedAt, p.UpdatedAt,
+	)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, ErrDuplicateCode
+		}
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return p, nil
+}
+
+const projectColumns = `
+	id, tenant_id, code, name, kind, client_name, description,
+	budget_amount, hourly_rate, start_date, end_date, status,
+	created_by, created_at, updated_at`
+
+func scanProject(row pgx.Row) (*Project, error) {
+	var p Project
+	var clientName, description sql.NullString
+	var budget, rate sql.NullInt64
+	var startDate, endDate sql.NullTime
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(
+		&p.ID, &p.TenantID, &p.Code, &p.Name, &p.Kind, &clientName, &description,
+		&budget, &rate, &startDate, &endDate, &p.Status,
+		&createdBy, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if clientName.Valid {
+		p.ClientName = &clientName.String
+	}
+	if description.Valid {
+		p.Description = &description.String
+	}
+	if budget.Valid {
+		p.BudgetAmount = &budget.Int64
+	}
+	if rate.Valid {
+		p.HourlyRate = &rate.Int64
+	}
+	if startDate.Valid {
+		p.StartDate = &startDate.Time
+	}
+	if endDate.Valid {
+		p.EndDate = &endDate.Time
+	}
+	if createdBy.Valid {
+		p.CreatedBy = &createdBy.UUID
+	}
+
+	return &p, nil
+}
+
+// GetByID retrieves a project by ID
+func (r *Repository) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE id = $1 AND tenant_id = $2`
+
+	p, err := scanProject(r.db.QueryRow(ctx, query, id, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	return p, nil
+}
+
+// List retrieves projects with filtering
+func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Project, int, error) {
+	baseQuery := ` FROM projects WHERE tenant_id = $1`
+	args := []interface{}{filter.TenantID}
+	argIdx := 2
+
+	if filter.Kind != nil {
+		baseQuery += fmt.Sprintf(" AND kind = $%d", argIdx)
+		args = append(args, *filter.Kind)
+		argIdx++
+	}
+
+	if filter.Status != nil {
+		baseQuery += fmt.Sprintf(" AND status = $%d", argIdx)
+		args = append(args, *filter.Status)
+		argIdx++
+	}
+
+	if filter.Search != nil {
+		baseQuery += fmt.Sprintf(" AND (code ILIKE $%d OR name ILIKE $%d OR client_name ILIKE $%d)", argIdx, argIdx, argIdx)
+		args = append(args, "%"+*filter.Search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count projects: %w", err)
+	}
+
+	selectQuery := `SELECT ` + projectColumns + baseQuery + `
+		ORDER BY code
+		LIMIT $` + fmt.Sprintf("%d", argIdx) + ` OFFSET $` + fmt.Sprintf("%d", argIdx+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projects []*Project
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+
+	return projects, total, nil
+}
+
+// Update updates a project
+func (r *Repository) Update(ctx context.Context, p *Project) error {
+	p.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE projects SET
+			name = $1, client_name = $2, description = $3, budget_amount = $4,
+			hourly_rate = $5, end_date = $6, status = $7, updated_at = $8
+		WHERE id = $9 AND tenant_id = $10`
+
+	result, err := r.db.Exec(ctx, query,
+		p.Name, p.ClientName, p.Description, p.BudgetAmount,
+		p.HourlyRate, p.EndDate, p.Status, p.UpdatedAt,
+		p.ID, p.TenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+// Delete deletes a project. References from invoices and payments are cleared.
+func (r *Repository) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM projects WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+// CreateTimeEntry books working time on a project
+func (r *Repository) CreateTimeEntry(ctx context.Context, e *TimeEntry) (*TimeEntry, error) {
+	e.ID = uuid.New()
+	e.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO project_time_entries (
+			id, tenant_id, project_id, user_id, work_date, minutes,
+			description, billable, hourly_rate, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := r.db.Exec(ctx, query,
+		e.ID, e.TenantID, e.ProjectID, e.UserID, e.WorkDate, e.Minutes,
+		e.Description, e.Billable, e.HourlyRate, e.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create time entry: %w", err)
+	}
+	return e, nil
+}
+
+// ListTimeEntries lists time entries with filtering
+func (r *Repository) ListTimeEntries(ctx context.Context, filter EntryFilter) ([]*TimeEntry, int, error) {
+	where, args := entryWhere(filter, "work_date")
+	argIdx := len(args) + 1
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM project_time_entries"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count time entries: %w", err)
+	}
+
+	query := `
+		SELECT id, tenant_id, project_id, user_id, work_date, minutes,
+			description, billable, hourly_rate, created_at
+		FROM project_time_entries` + where + `
+		ORDER BY work_date DESC, created_at DESC
+		LIMIT $` + fmt.Sprintf("%d", argIdx) + ` OFFSET $` + fmt.Sprintf("%d", argIdx+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list time entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*TimeEntry
+	for rows.Next() {
+		var e TimeEntry
+		var userID uuid.NullUUID
+		var description sql.NullString
+
+		if err := rows.Scan(
+			&e.ID, &e.TenantID, &e.ProjectID, &userID, &e.WorkDate, &e.Minutes,
+			&description, &e.Billable, &e.HourlyRate, &e.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan time entry: %w", err)
+		}
+		if userID.Valid {
+			e.UserID = &userID.UUID
+		}
+		if description.Valid {
+			e.Description = &description.String
+		}
+		entries = append(entries, &e)
+	}
+
+	return entries, total, nil
+}
+
+// DeleteTimeEntry deletes a time entry
+func (r *Repository) DeleteTimeEntry(ctx context.Context, id, tenantID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM project_time_entries WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete time entry: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+// CreateExpense books an expense on a project
+func (r *Repository) CreateExpense(ctx context.Context, e *Expense) (*Expense, error) {
+	e.ID = uuid.New()
+	e.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO project_expenses (
+			id, tenant_id, project_id, user_id, expense_date, category,
+			description, kilometers, amount, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := r.db.Exec(ctx, query,
+		e.ID, e.TenantID, e.ProjectID, e.UserID, e.ExpenseDate, e.Category,
+		e.Description, e.Kilometers, e.Amount, e.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create expense: %w", err)
+	}
+	return e, nil
+}
+
+// ListExpenses lists expenses with filtering
+func (r *Repository) ListExpenses(ctx context.Context, filter EntryFilter) ([]*Expense, int, error) {
+	where, args := entryWhere(filter, "expense_date")
+	argIdx := len(args) + 1
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM project_expenses"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count expenses: %w", err)
+	}
+
+	query := `
+		SELECT id, tenant_id, project_id, user_id, expense_date, category,
+			description, kilometers, amount, created_at
+		FROM project_expenses` + where + `
+		ORDER BY expense_date DESC, created_at DESC
+		LIMIT $` + fmt.Sprintf("%d", argIdx) + ` OFFSET $` + fmt.Sprintf("%d", argIdx+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var expenses []*Expense
+	for rows.Next() {
+		var e Expense
+		var userID uuid.NullUUID
+		var description sql.NullString
+		var km sql.NullFloat64
+
+		if err := rows.Scan(
+			&e.ID, &e.TenantID, &e.ProjectID, &userID, &e.ExpenseDate, &e.Category,
+			&description, &km, &e.Amount, &e.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan expense: %w", err)
+		}
+		if userID.Valid {
+			e.UserID = &userID.UUID
+		}
+		if description.Valid {
+			e.Description = &description.String
+		}
+		if km.Valid {
+			e.Kilometers = &km.Float64
+		}
+		expenses = append(expenses, &e)
+	}
+
+	return expenses, total, nil
+}
+
+// DeleteExpense deletes an expense
+func (r *Repository) DeleteExpense(ctx context.Context, id, tenantID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM project_expenses WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete expense: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+// Profitability aggregates revenue and costs per project. If projectID is nil,
+// all projects of the tenant are reported. Date bounds apply to invoice issue
+// dates, payment execution dates, work dates and expense dates.
+func (r *Repository) Profitability(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID, from, to *time.Time) ([]*Profitability, error) {
+	query := `
+		SELECT p.id, p.code, p.name, p.kind, p.budget_amount,
+			COALESCE((SELECT SUM(i.tax_exclusive_amount) FROM invoices i
+				WHERE i.project_id = p.id AND i.tenant_id = p.tenant_id AND i.status <> 'cancelled'
+				AND ($3::date IS NULL OR i.issue_date >= $3) AND ($4::date IS NULL OR i.issue_date <= $4)), 0),
+			COALESCE((SELECT SUM(b.total_amount) FROM payment_batches b
+				WHERE b.project_id = p.id AND b.tenant_id = p.tenant_id AND b.type = 'pain.001'
+				AND ($3::date IS NULL OR b.execution_date >= $3) AND ($4::date IS NULL OR b.execution_date <= $4)), 0),
+			COALESCE((SELECT SUM(t.minutes * t.hourly_rate / 60) FROM project_time_entries t
+				WHERE t.project_id = p.id
+				AND ($3::date IS NULL OR t.work_date >= $3) AND ($4::date IS NULL OR t.work_date <= $4)), 0),
+			COALESCE((SELECT SUM(t.minutes) FROM project_time_entries t
+				WHERE t.project_id = p.id
+				AND ($3::date IS NULL OR t.work_date >= $3) AND ($4::date IS NULL OR t.work_date <= $4)), 0),
+			COALESCE((SELECT SUM(t.minutes) FROM project_time_entries t
+				WHERE t.project_id = p.id AND t.billable
+				AND ($3::date IS NULL OR t.work_date >= $3) AND ($4::date IS NULL OR t.work_date <= $4)), 0),
+			COALESCE((SELECT SUM(e.amount) FROM project_expenses e
+				WHERE e.project_id = p.id
+				AND ($3::date IS NULL OR e.expense_date >= $3) AND ($4::date IS NULL OR e.expense_date <= $4)), 0)
+		FROM projects p
+		WHERE p.tenant_id = $1 AND ($2::uuid IS NULL OR p.id = $2)
+		ORDER BY p.code`
+
+	rows, err := r.db.Query(ctx, query, tenantID, projectID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute profitability: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*Profitability
+	for rows.Next() {
+		var rep Profitability
+		var budget sql.NullInt64
+
+		if err := rows.Scan(
+			&rep.ProjectID, &rep.Code, &rep.Name, &rep.Kind, &budget,
+			&rep.InvoicedNet, &rep.PaymentsOut, &rep.LaborCost,
+			&rep.MinutesTotal, &rep.MinutesBillable, &rep.ExpenseCost,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan profitability: %w", err)
+		}
+		if budget.Valid {
+			rep.BudgetAmount = &budget.Int64
+		}
+		reports = append(reports, &rep)
+	}
+
+	return reports, nil
+}
+
+// entryWhere builds the WHERE clause shared by time entry and expense listings
+func entryWhere(filter EntryFilter, dateColumn string) (string, []interface{}) {
+	clauses := []string{"tenant_id = $1"}
+	args := []interface{}{filter.TenantID}
+
+	if filter.ProjectID != nil {
+		args = append(args, *filter.ProjectID)
+		clauses = append(clauses, fmt.Sprintf("project_id = $%d", len(args)))
+	}
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		clauses = append(clauses, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filter.DateFrom != nil {
+		args = append(args, *filter.DateFrom)
+		clauses = append(clauses, fmt.Sprintf("%s >= $%d", dateColumn, len(args)))
+	}
+	if filter.DateTo != nil {
+		args = append(args, *filter.DateTo)
+		clauses = append(clauses, fmt.Sprintf("%s <= $%d", dateColumn, len(args)))
+	}
+
+	return " WHERE " + strings.Join(clauses, " AND "), args
+}
+
+// isDuplicateKeyError checks if error is a unique constraint violation
+func isDuplicateKeyError(err error) bool {
+	errStr := err.Error()
+	return strings.Contains(errStr, "23505") || strings.Contains(errStr, "unique constraint")
+}
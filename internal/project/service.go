@@ -0,0 +1,272 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidKind     = errors.New("invalid project kind")
+	ErrInvalidStatus   = errors.New("invalid project status")
+	ErrInvalidCategory = errors.New("invalid expense category")
+	ErrInvalidMinutes  = errors.New("minutes must be between 1 and 1440")
+	ErrInvalidAmount   = errors.New("amount must be positive")
+	ErrProjectClosed   = errors.New("project is closed")
+)
+
+// Service handles project business logic
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new project service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Create creates a new project or cost center
+func (s *Service) Create(ctx context.Context, tenantID, userID uuid.UUID, input *CreateProjectInput) (*Project, error) {
+	kind := input.Kind
+	if kind == "" {
+		kind = KindProject
+	}
+	if kind != KindProject && kind != KindCostCenter {
+		return nil, ErrInvalidKind
+	}
+
+	startDate, err := parseOptionalDate(input.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start_date format: %w", err)
+	}
+	endDate, err := parseOptionalDate(input.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end_date format: %w", err)
+	}
+
+	p := &Project{
+		TenantID:     tenantID,
+		Code:         input.Code,
+		Name:         input.Name,
+		Kind:         kind,
+		ClientName:   input.ClientName,
+		Description:  input.Description,
+		BudgetAmount: input.BudgetAmount,
+		HourlyRate:   input.HourlyRate,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		CreatedBy:    &userID,
+	}
+
+	return s.repo.Create(ctx, p)
+}
+
+// Get retrieves a project by ID
+func (s *Service) Get(ctx context.Context, id, tenantID uuid.UUID) (*Project, error) {
+	return s.repo.GetByID(ctx, id, tenantID)
+}
+
+// List lists projects with filtering
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]*Project, int, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// Update updates a project
+func (s *Service) Update(ctx context.Context, id, tenantID uuid.UUID, input *UpdateProjectInput) (*Project, error) {
+	p, err := s.repo.GetByID(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		p.Name = *input.Name
+	}
+	if input.ClientName != nil {
+		p.ClientName = input.ClientName
+	}
+	if input.Description != nil {
+		p.Description = input.Description
+	}
+	if input.BudgetAmount != nil {
+		p.BudgetAmount = input.BudgetAmount
+	}
+	if input.HourlyRate != nil {
+		p.HourlyRate = input.HourlyRate
+	}
+	if input.EndDate != nil {
+		endDate, err := parseOptionalDate(input.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end_date format: %w", err)
+		}
+		p.EndDate = endDate
+	}
+	if input.Status != nil {
+		if *input.Status != StatusActive && *input.Status != StatusClosed {
+			return nil, ErrInvalidStatus
+		}
+		p.Status = *input.Status
+	}
+
+	if err := s.repo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Delete deletes a project
+func (s *Service) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
+	return s.repo.Delete(ctx, id, tenantID)
+}
+
+// BookTime books working time on a project. The hourly rate defaults to the project's rate.
+func (s *Service) BookTime(ctx context.Context, projectID, tenantID, userID uuid.UUID, input *TimeEntryInput) (*TimeEntry, error) {
+	p, err := s.repo.GetByID(ctx, projectID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status == StatusClosed {
+		return nil, ErrProjectClosed
+	}
+	if input.Minutes <= 0 || input.Minutes > 24*60 {
+		return nil, ErrInvalidMinutes
+	}
+
+	workDate, err := time.Parse("2006-01-02", input.WorkDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid work_date format: %w", err)
+	}
+
+	entry := &TimeEntry{
+		TenantID:    tenantID,
+		ProjectID:   projectID,
+		UserID:      &userID,
+		WorkDate:    workDate,
+		Minutes:     input.Minutes,
+		Description: input.Description,
+		Billable:    true,
+	}
+	if input.Billable != nil {
+		entry.Billable = *input.Billable
+	}
+	switch {
+	case input.HourlyRate != nil:
+		entry.HourlyRate = *input.HourlyRate
+	case p.HourlyRate != nil:
+		entry.HourlyRate = *p.HourlyRate
+	}
+
+	return s.repo.CreateTimeEntry(ctx, entry)
+}
+
+// ListTimeEntries lists time entries
+func (s *Service) ListTimeEntries(ctx context.Context, filter EntryFilter) ([]*TimeEntry, int, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	return s.repo.ListTimeEntries(ctx, filter)
+}
+
+// DeleteTimeEntry deletes a time entry
+func (s *Service) DeleteTimeEntry(ctx context.Context, id, tenantID uuid.UUID) error {
+	return s.repo.DeleteTimeEntry(ctx, id, tenantID)
+}
+
+// BookExpense books a travel expense or other cost on a project.
+// Mileage expenses without an explicit amount are valued with the amtliches Kilometergeld.
+func (s *Service) BookExpense(ctx context.Context, projectID, tenantID, userID uuid.UUID, input *ExpenseInput) (*Expense, error) {
+	p, err := s.repo.GetByID(ctx, projectID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status == StatusClosed {
+		return nil, ErrProjectClosed
+	}
+
+	switch input.Category {
+	case ExpenseTravel, ExpenseMileage, ExpensePerDiem, ExpenseAccommodation, ExpenseOther:
+	default:
+		return nil, ErrInvalidCategory
+	}
+
+	expenseDate, err := time.Parse("2006-01-02", input.ExpenseDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expense_date format: %w", err)
+	}
+
+	amount := input.Amount
+	if input.Category == ExpenseMileage && amount == 0 && input.Kilometers != nil {
+		amount = int64(math.Round(*input.Kilometers * MileageRateCents))
+	}
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	expense := &Expense{
+		TenantID:    tenantID,
+		ProjectID:   projectID,
+		UserID:      &userID,
+		ExpenseDate: expenseDate,
+		Category:    input.Category,
+		Description: input.Description,
+		Kilometers:  input.Kilometers,
+		Amount:      amount,
+	}
+
+	return s.repo.CreateExpense(ctx, expense)
+}
+
+// ListExpenses lists expenses
+func (s *Service) ListExpenses(ctx context.Context, filter EntryFilter) ([]*Expense, int, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	return s.repo.ListExpenses(ctx, filter)
+}
+
+// DeleteExpense deletes an expense
+func (s *Service) DeleteExpense(ctx context.Context, id, tenantID uuid.UUID) error {
+	return s.repo.DeleteExpense(ctx, id, tenantID)
+}
+
+// Profitability returns profitability reports for one or all projects
+func (s *Service) Profitability(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID, from, to *time.Time) ([]*Profitability, error) {
+	reports, err := s.repo.Profitability(ctx, tenantID, projectID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if projectID != nil && len(reports) == 0 {
+		return nil, ErrProjectNotFound
+	}
+
+	for _, rep := range reports {
+		rep.TotalCost = rep.LaborCost + rep.ExpenseCost + rep.PaymentsOut
+		rep.Margin = rep.InvoicedNet - rep.TotalCost
+		if rep.InvoicedNet != 0 {
+			rep.MarginPercent = math.Round(float64(rep.Margin)/float64(rep.InvoicedNet)*10000) / 100
+		}
+		if rep.BudgetAmount != nil && *rep.BudgetAmount > 0 {
+			used := math.Round(float64(rep.TotalCost)/float64(*rep.BudgetAmount)*10000) / 100
+			rep.BudgetUsed = &used
+		}
+	}
+
+	return reports, nil
+}
+
+func parseOptionalDate(value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", *value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,198 @@
+package project
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/pkg/money"
+)
+
+// Kind constants
+const (
+	KindProject    = "project"
+	KindCostCenter = "cost_center" // Kostenstelle
+)
+
+// Status constants for projects
+const (
+	StatusActive = "active"
+	StatusClosed = "closed"
+)
+
+// Expense category constants
+const (
+	ExpenseTravel        = "travel"        // Fahrtkosten
+	ExpenseMileage       = "mileage"       // Kilometergeld
+	ExpensePerDiem       = "per_diem"      // Taggeld
+	ExpenseAccommodation = "accommodation" // Nächtigung
+	ExpenseOther         = "other"
+)
+
+// MileageRateCents is the amtliches Kilometergeld per km for cars (0,50 EUR since 2025)
+const MileageRateCents = 50
+
+// Project represents a project or cost center that business records can reference
+type Project struct {
+	ID           uuid.UUID  `json:"id"`
+	TenantID     uuid.UUID  `json:"tenant_id"`
+	Code         string     `json:"code"`
+	Name         string     `json:"name"`
+	Kind         string     `json:"kind"`
+	ClientName   *string    `json:"client_name,omitempty"`
+	Description  *string    `json:"description,omitempty"`
+	BudgetAmount *int64     `json:"budget_amount,omitempty"` // In cents
+	HourlyRate   *int64     `json:"hourly_rate,omitempty"`   // In cents, default for time entries
+	StartDate    *time.Time `json:"start_date,omitempty"`
+	EndDate      *time.Time `json:"end_date,omitempty"`
+	Status       string     `json:"status"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TimeEntry represents working time booked on a project
+type TimeEntry struct {
+	ID          uuid.UUID  `json:"id"`
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	ProjectID   uuid.UUID  `json:"project_id"`
+	UserID      *uuid.UUID `json:"user_id,omitempty"`
+	WorkDate    time.Time  `json:"work_date"`
+	Minutes     int        `json:"minutes"`
+	Description *string    `json:"description,omitempty"`
+	Billable    bool       `json:"billable"`
+	HourlyRate  int64      `json:"hourly_rate"` // In cents
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Expense represents a travel expense or other cost booked on a project
+type Expense struct {
+	ID          uuid.UUID  `json:"id"`
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	ProjectID   uuid.UUID  `json:"project_id"`
+	UserID      *uuid.UUID `json:"user_id,omitempty"`
+	ExpenseDate time.Time  `json:"expense_date"`
+	Category    string     `json:"category"`
+	Description *string    `json:"description,omitempty"`
+	Kilometers  *float64   `json:"kilometers,omitempty"`
+	Amount      int64      `json:"amount"` // In cents
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreateProjectInput represents input for creating a project
+type CreateProjectInput struct {
+	Code         string  `json:"code"`
+	Name         string  `json:"name"`
+	Kind         string  `json:"kind"`
+	ClientName   *string `json:"client_name,omitempty"`
+	Description  *string `json:"description,omitempty"`
+	BudgetAmount *int64  `json:"budget_amount,omitempty"`
+	HourlyRate   *int64  `json:"hourly_rate,omitempty"`
+	StartDate    *string `json:"start_date,omitempty"`
+	EndDate      *string `json:"end_date,omitempty"`
+}
+
+// UpdateProjectInput represents input for updating a project
+type UpdateProjectInput struct {
+	Name         *string `json:"name,omitempty"`
+	ClientName   *string `json:"client_name,omitempty"`
+	Description  *string `json:"description,omitempty"`
+	BudgetAmount *int64  `json:"budget_amount,omitempty"`
+	HourlyRate   *int64  `json:"hourly_rate,omitempty"`
+	EndDate      *string `json:"end_date,omitempty"`
+	Status       *string `json:"status,omitempty"`
+}
+
+// TimeEntryInput represents input for booking working time
+type TimeEntryInput struct {
+	WorkDate    string  `json:"work_date"`
+	Minutes     int     `json:"minutes"`
+	Description *string `json:"description,omitempty"`
+	Billable    *bool   `json:"billable,omitempty"`
+	HourlyRate  *int64  `json:"hourly_rate,omitempty"`
+}
+
+// ExpenseInput represents input for booking an expense
+type ExpenseInput struct {
+	ExpenseDate string   `json:"expense_date"`
+	Category    string   `json:"category"`
+	Description *string  `json:"description,omitempty"`
+	Kilometers  *float64 `json:"kilometers,omitempty"`
+	Amount      int64    `json:"amount"`
+}
+
+// ListFilter represents filtering options for listing projects
+type ListFilter struct {
+	TenantID uuid.UUID
+	Kind     *string
+	Status   *string
+	Search   *string
+	Limit    int
+	Offset   int
+}
+
+// EntryFilter represents filtering options for time entries and expenses
+type EntryFilter struct {
+	TenantID  uuid.UUID
+	ProjectID *uuid.UUID
+	UserID    *uuid.UUID
+	DateFrom  *time.Time
+	DateTo    *time.Time
+	Limit     int
+	Offset    int
+}
+
+// Profitability is the profitability report for a single project
+type Profitability struct {
+	ProjectID       uuid.UUID `json:"project_id"`
+	Code            string    `json:"code"`
+	Name            string    `json:"name"`
+	Kind            string    `json:"kind"`
+	InvoicedNet     int64     `json:"invoiced_net"` // Revenue from invoices (net)
+	PaymentsOut     int64     `json:"payments_out"` // Outgoing credit transfers
+	LaborCost       int64     `json:"labor_cost"`   // Time entries valued at their hourly rate
+	ExpenseCost     int64     `json:"expense_cost"` // Travel and other expenses
+	TotalCost       int64     `json:"total_cost"`
+	Margin          int64     `json:"margin"`
+	MarginPercent   float64   `json:"margin_percent"`
+	MinutesTotal    int       `json:"minutes_total"`
+	MinutesBillable int       `json:"minutes_billable"`
+	BudgetAmount    *int64    `json:"budget_amount,omitempty"`
+	BudgetUsed      *float64  `json:"budget_used_percent,omitempty"`
+}
+
+// ProjectResponse is the API response format
+type ProjectResponse struct {
+	ID           uuid.UUID    `json:"id"`
+	Code         string       `json:"code"`
+	Name         string       `json:"name"`
+	Kind         string       `json:"kind"`
+	ClientName   *string      `json:"client_name,omitempty"`
+	Description  *string      `json:"description,omitempty"`
+	BudgetAmount *money.Money `json:"budget_amount,omitempty"`
+	HourlyRate   *money.Money `json:"hourly_rate,omitempty"`
+	StartDate    *string      `json:"start_date,omitempty"`
+	EndDate      *string      `json:"end_date,omitempty"`
+	Status       string       `json:"status"`
+	CreatedAt    string       `json:"created_at"`
+	UpdatedAt    string       `json:"updated_at"`
+}
+
+// ProfitabilityResponse is the API response format for profitability reports (amounts in EUR)
+type ProfitabilityResponse struct {
+	ProjectID         uuid.UUID    `json:"project_id"`
+	Code              string       `json:"code"`
+	Name              string       `json:"name"`
+	Kind              string       `json:"kind"`
+	InvoicedNet       money.Money  `json:"invoiced_net"`
+	PaymentsOut       money.Money  `json:"payments_out"`
+	LaborCost         money.Money  `json:"labor_cost"`
+	ExpenseCost       money.Money  `json:"expense_cost"`
+	TotalCost         money.Money  `json:"total_cost"`
+	Margin            money.Money  `json:"margin"`
+	MarginPercent     float64      `json:"margin_percent"`
+	HoursTotal        float64      `json:"hours_total"`
+	HoursBillable     float64      `json:"hours_billable"`
+	BudgetAmount      *money.Money `json:"budget_amount,omitempty"`
+	BudgetUsedPercent *float64     `json:"budget_used_percent,omitempty"`
+}
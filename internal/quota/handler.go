@@ -0,0 +1,93 @@
+package quota
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// Handler handles storage quota HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new quota handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers storage routes. Changing the quota is admin-only.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/storage", requireAuth(http.HandlerFunc(h.GetUsage)))
+	router.Handle("PUT /api/v1/storage/quota", requireAuth(requireAdmin(http.HandlerFunc(h.SetQuota))))
+	router.Handle("DELETE /api/v1/storage/quota", requireAuth(requireAdmin(http.HandlerFunc(h.ResetQuota))))
+}
+
+// GetUsage handles GET /api/v1/storage
+func (h *Handler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	usage, err := h.service.GetUsage(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, usage)
+}
+
+// SetQuota handles PUT /api/v1/storage/quota
+func (h *Handler) SetQuota(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	var input SetQuotaInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	var updatedBy *uuid.UUID
+	if userID, err := uuid.Parse(api.GetUserID(r.Context())); err == nil {
+		updatedBy = &userID
+	}
+
+	q, err := h.service.SetQuota(r.Context(), tenantID, &input, updatedBy)
+	if err != nil {
+		if errors.Is(err, ErrInvalidQuota) {
+			api.BadRequest(w, err.Error())
+			return
+		}
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, q)
+}
+
+// ResetQuota handles DELETE /api/v1/storage/quota
+func (h *Handler) ResetQuota(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	q, err := h.service.ResetQuota(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, q)
+}
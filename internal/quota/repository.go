@@ -0,0 +1,177 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles storage quota and accounting database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new quota repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// GetQuota returns a tenant's quota override, or nil if it has none
+func (r *Repository) GetQuota(ctx context.Context, tenantID uuid.UUID) (*Quota, error) {
+	var q Quota
+	var updatedAt time.Time
+	err := r.db.QueryRow(ctx, `
+		SELECT quota_bytes, warn_percent, updated_at
+		FROM tenant_storage_quotas
+		WHERE tenant_id = $1
+	`, tenantID).Scan(&q.QuotaBytes, &q.WarnPercent, &updatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get storage quota: %w", err)
+	}
+	q.UpdatedAt = &updatedAt
+	return &q, nil
+}
+
+// SetQuota creates or replaces a tenant's quota override
+func (r *Repository) SetQuota(ctx context.Context, tenantID uuid.UUID, quotaBytes int64, warnPercent int, updatedBy *uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO tenant_storage_quotas (tenant_id, quota_bytes, warn_percent, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			quota_bytes = EXCLUDED.quota_bytes,
+			warn_percent = EXCLUDED.warn_percent,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+	`, tenantID, quotaBytes, warnPercent, updatedBy)
+	if err != nil {
+		return fmt.Errorf("set storage quota: %w", err)
+	}
+	return nil
+}
+
+// DeleteQuota removes a tenant's override so the default applies again
+func (r *Repository) DeleteQuota(ctx context.Context, tenantID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM tenant_storage_quotas WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("delete storage quota: %w", err)
+	}
+	return nil
+}
+
+// UsedBytes returns the bytes a tenant stores: documents, client uploads and
+// extracted analysis text
+func (r *Repository) UsedBytes(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	var used int64
+	err := r.db.QueryRow(ctx, `
+		SELECT
+			(SELECT COALESCE(SUM(file_size), 0) FROM documents WHERE tenant_id = $1)
+			+ (SELECT COALESCE(SUM(cu.file_size), 0)
+			   FROM client_uploads cu JOIN clients c ON c.id = cu.client_id
+			   WHERE c.tenant_id = $1)
+			+ (SELECT COALESCE(SUM(octet_length(extracted_text)), 0)
+			   FROM document_analyses WHERE tenant_id = $1)
+			+ (SELECT COALESCE(SUM(octet_length(content)), 0)
+			   FROM document_analysis_texts WHERE tenant_id = $1)
+	`, tenantID).Scan(&used)
+	if err != nil {
+		return 0, fmt.Errorf("get storage usage: %w", err)
+	}
+	return used, nil
+}
+
+// GetBreakdown returns a tenant's storage per document type, client uploads
+// and extracted text
+func (r *Repository) GetBreakdown(ctx context.Context, tenantID uuid.UUID) (*Breakdown, error) {
+	b := &Breakdown{
+		Documents:     []*TypeUsage{},
+		ClientUploads: &TypeUsage{Type: "client_uploads"},
+		ExtractedText: &TypeUsage{Type: "extracted_text"},
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT type, COUNT(*), COALESCE(SUM(file_size), 0)
+		FROM documents
+		WHERE tenant_id = $1
+		GROUP BY type
+		ORDER BY 3 DESC, type
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get document storage: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t := &TypeUsage{}
+		if err := rows.Scan(&t.Type, &t.Count, &t.Bytes); err != nil {
+			return nil, fmt.Errorf("scan document storage: %w", err)
+		}
+		b.Documents = append(b.Documents, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate document storage: %w", err)
+	}
+
+	err = r.db.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(cu.file_size), 0)
+		FROM client_uploads cu
+		JOIN clients c ON c.id = cu.client_id
+		WHERE c.tenant_id = $1
+	`, tenantID).Scan(&b.ClientUploads.Count, &b.ClientUploads.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("get client upload storage: %w", err)
+	}
+
+	// Compacted texts count with their preview and compressed full text
+	err = r.db.QueryRow(ctx, `
+		SELECT COUNT(*),
+			COALESCE(SUM(octet_length(extracted_text)), 0)
+			+ (SELECT COALESCE(SUM(octet_length(content)), 0)
+			   FROM document_analysis_texts WHERE tenant_id = $1)
+		FROM document_analyses
+		WHERE tenant_id = $1 AND extracted_text IS NOT NULL
+	`, tenantID).Scan(&b.ExtractedText.Count, &b.ExtractedText.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("get extracted text storage: %w", err)
+	}
+
+	return b, nil
+}
+
+// GetOldExtractedText returns the number and size of extracted texts of
+// analyses created before the given time that are not compacted yet
+func (r *Repository) GetOldExtractedText(ctx context.Context, tenantID uuid.UUID, before time.Time) (int64, int64, error) {
+	var count, bytes int64
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(octet_length(extracted_text)), 0)
+		FROM document_analyses
+		WHERE tenant_id = $1 AND extracted_text IS NOT NULL AND NOT text_compacted
+		  AND created_at < $2 AND char_length(extracted_text) > $3
+	`, tenantID, before, previewRunes).Scan(&count, &bytes)
+	if err != nil {
+		return 0, 0, fmt.Errorf("get old extracted text: %w", err)
+	}
+	return count, bytes, nil
+}
+
+// GetExpiredDocuments returns the number and size of documents past their
+// retention date
+func (r *Repository) GetExpiredDocuments(ctx context.Context, tenantID uuid.UUID, now time.Time) (int64, int64, error) {
+	var count, bytes int64
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(file_size), 0)
+		FROM documents
+		WHERE tenant_id = $1 AND retention_until IS NOT NULL AND retention_until < $2
+	`, tenantID, now).Scan(&count, &bytes)
+	if err != nil {
+		return 0, 0, fmt.Errorf("get expired documents: %w", err)
+	}
+	return count, bytes, nil
+}
@@ -0,0 +1,166 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Service accounts tenant storage and enforces the soft quota.
+//
+// The quota is soft: usage is summed from the stored file sizes when a file
+// comes in, without reserving space, so concurrent uploads can overshoot it
+// by a file each. Nothing already stored is touched when a tenant is over
+// its quota; only new files are refused.
+type Service struct {
+	repo         *Repository
+	defaultQuota int64
+	now          func() time.Time
+}
+
+// NewService creates a new quota service. defaultQuota applies to tenants
+// without an override; 0 means unlimited.
+func NewService(repo *Repository, defaultQuota int64) *Service {
+	return &Service{repo: repo, defaultQuota: defaultQuota, now: time.Now}
+}
+
+// GetQuota returns the quota that applies to a tenant
+func (s *Service) GetQuota(ctx context.Context, tenantID uuid.UUID) (*Quota, error) {
+	q, err := s.repo.GetQuota(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if q == nil {
+		return &Quota{QuotaBytes: s.defaultQuota, WarnPercent: DefaultWarnPercent, IsDefault: true}, nil
+	}
+	return q, nil
+}
+
+// SetQuota overrides a tenant's quota
+func (s *Service) SetQuota(ctx context.Context, tenantID uuid.UUID, input *SetQuotaInput, updatedBy *uuid.UUID) (*Quota, error) {
+	if input.QuotaBytes == nil || *input.QuotaBytes < 0 {
+		return nil, fmt.Errorf("%w: quota_bytes must be 0 (unlimited) or positive", ErrInvalidQuota)
+	}
+	warnPercent := DefaultWarnPercent
+	if input.WarnPercent != nil {
+		warnPercent = *input.WarnPercent
+	}
+	if warnPercent < 1 || warnPercent > 100 {
+		return nil, fmt.Errorf("%w: warn_percent must be between 1 and 100", ErrInvalidQuota)
+	}
+
+	if err := s.repo.SetQuota(ctx, tenantID, *input.QuotaBytes, warnPercent, updatedBy); err != nil {
+		return nil, err
+	}
+	return s.GetQuota(ctx, tenantID)
+}
+
+// ResetQuota removes a tenant's override so the default applies again
+func (s *Service) ResetQuota(ctx context.Context, tenantID uuid.UUID) (*Quota, error) {
+	if err := s.repo.DeleteQuota(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	return s.GetQuota(ctx, tenantID)
+}
+
+// CheckQuota returns an *ExceededError if storing size more bytes would
+// take the tenant over its quota
+func (s *Service) CheckQuota(ctx context.Context, tenantID uuid.UUID, size int64) error {
+	q, err := s.GetQuota(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if q.Unlimited() {
+		return nil
+	}
+	used, err := s.repo.UsedBytes(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return Check(q, used, size)
+}
+
+// Check returns an *ExceededError if used plus size exceeds the quota
+func Check(q *Quota, used, size int64) error {
+	if q.Unlimited() || used+size <= q.QuotaBytes {
+		return nil
+	}
+	return &ExceededError{UsedBytes: used, QuotaBytes: q.QuotaBytes, RequestedBytes: size}
+}
+
+// GetUsage returns a tenant's storage usage, breakdown and lifecycle
+// suggestions
+func (s *Service) GetUsage(ctx context.Context, tenantID uuid.UUID) (*Usage, error) {
+	q, err := s.GetQuota(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	breakdown, err := s.repo.GetBreakdown(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	textCount, textBytes, err := s.repo.GetOldExtractedText(ctx, tenantID, now.AddDate(0, 0, -CompressAfterDays))
+	if err != nil {
+		return nil, err
+	}
+	expiredCount, expiredBytes, err := s.repo.GetExpiredDocuments(ctx, tenantID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := Evaluate(q, breakdown)
+	usage.Suggestions = Suggest(textCount, textBytes, expiredCount, expiredBytes)
+	return usage, nil
+}
+
+// Evaluate compares a breakdown against the quota
+func Evaluate(q *Quota, breakdown *Breakdown) *Usage {
+	usage := &Usage{
+		UsedBytes:   breakdown.Total(),
+		Quota:       q,
+		Breakdown:   breakdown,
+		Suggestions: []*Suggestion{},
+	}
+	if q.Unlimited() {
+		return usage
+	}
+
+	percent := math.Round(float64(usage.UsedBytes)/float64(q.QuotaBytes)*1000) / 10
+	usage.PercentUsed = &percent
+	usage.Exceeded = usage.UsedBytes >= q.QuotaBytes
+	usage.Warning = usage.UsedBytes*100 >= q.QuotaBytes*int64(q.WarnPercent)
+	return usage
+}
+
+// Suggest lists the lifecycle actions worth taking, largest savings first
+func Suggest(textCount, textBytes, expiredCount, expiredBytes int64) []*Suggestion {
+	suggestions := []*Suggestion{}
+	if expiredCount > 0 {
+		suggestions = append(suggestions, &Suggestion{
+			Action:                ActionDeleteExpiredDocuments,
+			Description:           "Delete documents past their retention date",
+			Count:                 expiredCount,
+			Bytes:                 expiredBytes,
+			EstimatedSavingsBytes: expiredBytes,
+		})
+	}
+	if textCount > 0 {
+		suggestions = append(suggestions, &Suggestion{
+			Action:                ActionCompressExtractedText,
+			Description:           fmt.Sprintf("Compress the extracted text of analyses older than %d days (done by the daily analysis_text_compaction job)", CompressAfterDays),
+			Count:                 textCount,
+			Bytes:                 textBytes,
+			EstimatedSavingsBytes: int64(float64(textBytes) * textCompressionSavings),
+		})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].EstimatedSavingsBytes > suggestions[j].EstimatedSavingsBytes
+	})
+	return suggestions
+}
@@ -0,0 +1,135 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrQuotaExceeded = errors.New("storage quota exceeded")
+	ErrInvalidQuota  = errors.New("invalid storage quota")
+)
+
+// DefaultWarnPercent is the share of the quota at which usage is flagged
+const DefaultWarnPercent = 80
+
+// Lifecycle suggestion thresholds
+const (
+	// CompressAfterDays is the age from which an analysis' extracted text is
+	// compacted by the analysis_text_compaction job (analysis.TextCompactAfterDays)
+	CompressAfterDays = 180
+
+	// previewRunes is the text length kept uncompressed (analysis.TextPreviewRunes)
+	previewRunes = 2000
+
+	// textCompressionSavings is the share of bytes typically saved when
+	// compressing OCR and PDF text
+	textCompressionSavings = 0.7
+)
+
+// Suggestion actions
+const (
+	ActionCompressExtractedText  = "compress_extracted_text"
+	ActionDeleteExpiredDocuments = "delete_expired_documents"
+)
+
+// Quota is a tenant's storage quota. QuotaBytes 0 means unlimited.
+type Quota struct {
+	QuotaBytes  int64      `json:"quota_bytes"`
+	WarnPercent int        `json:"warn_percent"`
+	IsDefault   bool       `json:"is_default"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// Unlimited reports whether the quota is not enforced
+func (q *Quota) Unlimited() bool {
+	return q.QuotaBytes <= 0
+}
+
+// ExceededError is returned when storing a file would exceed the quota. It
+// matches ErrQuotaExceeded with errors.Is.
+type ExceededError struct {
+	UsedBytes      int64
+	QuotaBytes     int64
+	RequestedBytes int64
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("storage quota exceeded: %s of %s used, file needs %s",
+		FormatBytes(e.UsedBytes), FormatBytes(e.QuotaBytes), FormatBytes(e.RequestedBytes))
+}
+
+// Is makes errors.Is(err, ErrQuotaExceeded) work
+func (e *ExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// Usage is a tenant's storage usage against its quota
+type Usage struct {
+	UsedBytes   int64         `json:"used_bytes"`
+	Quota       *Quota        `json:"quota"`
+	PercentUsed *float64      `json:"percent_used,omitempty"` // nil when unlimited
+	Warning     bool          `json:"warning"`
+	Exceeded    bool          `json:"exceeded"`
+	Breakdown   *Breakdown    `json:"breakdown"`
+	Suggestions []*Suggestion `json:"suggestions"`
+}
+
+// Breakdown splits storage by what it is used for
+type Breakdown struct {
+	Documents     []*TypeUsage `json:"documents"` // per document type, largest first
+	ClientUploads *TypeUsage   `json:"client_uploads"`
+	ExtractedText *TypeUsage   `json:"extracted_text"`
+}
+
+// Total returns the sum of all parts of the breakdown
+func (b *Breakdown) Total() int64 {
+	var total int64
+	for _, d := range b.Documents {
+		total += d.Bytes
+	}
+	if b.ClientUploads != nil {
+		total += b.ClientUploads.Bytes
+	}
+	if b.ExtractedText != nil {
+		total += b.ExtractedText.Bytes
+	}
+	return total
+}
+
+// TypeUsage is the number and size of stored items of one kind
+type TypeUsage struct {
+	Type  string `json:"type"`
+	Count int64  `json:"count"`
+	Bytes int64  `json:"bytes"`
+}
+
+// Suggestion is a lifecycle action that would free storage
+type Suggestion struct {
+	Action                string `json:"action"`
+	Description           string `json:"description"`
+	Count                 int64  `json:"count"`
+	Bytes                 int64  `json:"bytes"`
+	EstimatedSavingsBytes int64  `json:"estimated_savings_bytes"`
+}
+
+// SetQuotaInput holds input for overriding a tenant's quota
+type SetQuotaInput struct {
+	QuotaBytes  *int64 `json:"quota_bytes"`
+	WarnPercent *int   `json:"warn_percent,omitempty"`
+}
+
+// FormatBytes formats a size with binary units, e.g. "1.5 GiB"
+func FormatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,168 @@
+package rawpayload
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// Handler handles raw payload HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new raw payload handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers raw payload routes. Payloads contain personal and
+// tax data, so all routes are admin-only.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/raw-payloads", requireAuth(requireAdmin(http.HandlerFunc(h.List))))
+	router.Handle("GET /api/v1/raw-payloads/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Get))))
+	router.Handle("GET /api/v1/raw-payloads/{id}/download", requireAuth(requireAdmin(http.HandlerFunc(h.Download))))
+}
+
+// List handles GET /api/v1/raw-payloads
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	filter := ListFilter{
+		TenantID: tenantID,
+		Limit:    50,
+	}
+
+	q := r.URL.Query()
+	if module := q.Get("module"); module != "" {
+		m := Module(module)
+		filter.Module = &m
+	}
+	if outcome := q.Get("outcome"); outcome != "" {
+		o := Outcome(outcome)
+		filter.Outcome = &o
+	}
+	if refStr := q.Get("reference_id"); refStr != "" {
+		refID, err := uuid.Parse(refStr)
+		if err != nil {
+			api.BadRequest(w, "invalid reference_id")
+			return
+		}
+		filter.ReferenceID = &refID
+	}
+	if sinceStr := q.Get("since"); sinceStr != "" {
+		since, err := time.Parse("2006-01-02", sinceStr)
+		if err != nil {
+			api.BadRequest(w, "invalid since date, expected YYYY-MM-DD")
+			return
+		}
+		filter.Since = &since
+	}
+	if limitStr := q.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := q.Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	payloads, total, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if payloads == nil {
+		payloads = []*Payload{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  payloads,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// Get handles GET /api/v1/raw-payloads/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid payload ID")
+		return
+	}
+
+	p, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, p)
+}
+
+// Download handles GET /api/v1/raw-payloads/{id}/download?part=request|response
+func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid payload ID")
+		return
+	}
+
+	part := Part(r.URL.Query().Get("part"))
+	if part == "" {
+		part = PartResponse
+	}
+
+	var userID *uuid.UUID
+	if uid, err := uuid.Parse(api.GetUserID(r.Context())); err == nil {
+		userID = &uid
+	}
+
+	p, body, err := h.service.Download(r.Context(), tenantID, id, part, userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s-%s-%d.xml", p.Module, p.Operation, part, p.Attempt)
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrPayloadNotFound):
+		api.NotFound(w, "raw payload not found")
+	case errors.Is(err, ErrInvalidPart):
+		api.BadRequest(w, err.Error())
+	default:
+		api.InternalError(w)
+	}
+}
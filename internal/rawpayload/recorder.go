@@ -0,0 +1,63 @@
+package rawpayload
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Exchange is a single raw request/response pair with an external provider
+type Exchange struct {
+	Operation  string
+	Endpoint   string
+	Request    []byte
+	Response   []byte
+	StatusCode int
+	Error      string
+	StartedAt  time.Time
+	Duration   time.Duration
+}
+
+// Recorder collects the exchanges of one submission, including retries
+type Recorder struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+}
+
+// NewRecorder creates an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Record appends an exchange. A nil recorder discards it.
+func (r *Recorder) Record(e Exchange) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, e)
+}
+
+// Exchanges returns a copy of the recorded exchanges
+func (r *Recorder) Exchanges() []Exchange {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Exchange(nil), r.exchanges...)
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a context that records provider exchanges into rec
+func WithRecorder(ctx context.Context, rec *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, rec)
+}
+
+// FromContext returns the recorder of the context, or nil
+func FromContext(ctx context.Context) *Recorder {
+	rec, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return rec
+}
@@ -0,0 +1,228 @@
+package rawpayload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrPayloadNotFound = errors.New("raw payload not found")
+)
+
+// Module identifies the submitting module
+type Module string
+
+const (
+	ModuleUVA    Module = "uva"
+	ModuleZM     Module = "zm"
+	ModuleUID    Module = "uid"
+	ModuleELDA   Module = "elda"
+	ModuleKommSt Module = "kommst"
+)
+
+// Outcome is the result of a submission as seen by the provider
+type Outcome string
+
+const (
+	OutcomeSuccess  Outcome = "success"
+	OutcomeRejected Outcome = "rejected" // Provider answered with a business error
+	OutcomeError    Outcome = "error"    // Transport or protocol failure
+)
+
+// Payload is a stored raw exchange. The XML bodies are encrypted at rest and
+// only returned by Download.
+type Payload struct {
+	ID                uuid.UUID  `json:"id"`
+	TenantID          uuid.UUID  `json:"tenant_id"`
+	ELDAAccountID     *uuid.UUID `json:"elda_account_id,omitempty"`
+	Module            Module     `json:"module"`
+	Operation         string     `json:"operation"`
+	Endpoint          string     `json:"endpoint,omitempty"`
+	ReferenceType     string     `json:"reference_type"`
+	ReferenceID       *uuid.UUID `json:"reference_id,omitempty"`
+	ProviderReference string     `json:"provider_reference,omitempty"` // Belegnummer, Protokollnummer
+	Outcome           Outcome    `json:"outcome"`
+	Attempt           int        `json:"attempt"`
+	StatusCode        int        `json:"status_code,omitempty"`
+	ErrorMessage      string     `json:"error_message,omitempty"`
+	RequestSize       int        `json:"request_size"`
+	ResponseSize      int        `json:"response_size"`
+	Truncated         bool       `json:"truncated"`
+	DurationMS        int64      `json:"duration_ms"`
+	CreatedAt         time.Time  `json:"created_at"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	DownloadCount     int        `json:"download_count"`
+	LastDownloadedAt  *time.Time `json:"last_downloaded_at,omitempty"`
+	LastDownloadedBy  *uuid.UUID `json:"last_downloaded_by,omitempty"`
+
+	requestEncrypted  []byte
+	responseEncrypted []byte
+}
+
+// ListFilter contains filter options for listing payloads
+type ListFilter struct {
+	TenantID    uuid.UUID
+	Module      *Module
+	Outcome     *Outcome
+	ReferenceID *uuid.UUID
+	Since       *time.Time
+	Limit       int
+	Offset      int
+}
+
+// Repository handles raw payload database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new raw payload repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const payloadColumns = `
+	id, tenant_id, elda_account_id, module, operation, endpoint,
+	reference_type, reference_id, provider_reference, outcome, attempt,
+	status_code, error_message, request_size, response_size, truncated,
+	duration_ms, created_at, expires_at, download_count, last_downloaded_at, last_downloaded_by`
+
+// Create stores a payload. ELDA payloads without a tenant are attributed to
+// the tenant owning the ELDA account.
+func (r *Repository) Create(ctx context.Context, p *Payload, tenantID *uuid.UUID) error {
+	query := `
+		INSERT INTO raw_payloads (
+			tenant_id, elda_account_id, module, operation, endpoint,
+			reference_type, reference_id, provider_reference, outcome, attempt,
+			status_code, error_message, request_encrypted, response_encrypted,
+			request_size, response_size, truncated, duration_ms, expires_at
+		) VALUES (
+			COALESCE($1, (
+				SELECT a.tenant_id FROM elda_accounts e
+				JOIN accounts a ON a.id = e.account_id
+				WHERE e.id = $2
+			)),
+			$2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
+		)
+		RETURNING id, tenant_id, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		tenantID, p.ELDAAccountID, p.Module, p.Operation, p.Endpoint,
+		p.ReferenceType, p.ReferenceID, p.ProviderReference, p.Outcome, p.Attempt,
+		p.StatusCode, p.ErrorMessage, p.requestEncrypted, p.responseEncrypted,
+		p.RequestSize, p.ResponseSize, p.Truncated, p.DurationMS, p.ExpiresAt,
+	).Scan(&p.ID, &p.TenantID, &p.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create raw payload: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a payload including its encrypted bodies
+func (r *Repository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*Payload, error) {
+	query := `SELECT ` + payloadColumns + `, request_encrypted, response_encrypted
+		FROM raw_payloads
+		WHERE id = $1 AND tenant_id = $2`
+
+	p := &Payload{}
+	dest := append(scanTargets(p), &p.requestEncrypted, &p.responseEncrypted)
+	if err := r.db.QueryRow(ctx, query, id, tenantID).Scan(dest...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPayloadNotFound
+		}
+		return nil, fmt.Errorf("get raw payload: %w", err)
+	}
+	return p, nil
+}
+
+// List returns payload metadata matching the filter, newest first
+func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Payload, int, error) {
+	where := ` WHERE tenant_id = $1`
+	args := []interface{}{filter.TenantID}
+	argIndex := 2
+
+	if filter.Module != nil {
+		where += fmt.Sprintf(" AND module = $%d", argIndex)
+		args = append(args, *filter.Module)
+		argIndex++
+	}
+	if filter.Outcome != nil {
+		where += fmt.Sprintf(" AND outcome = $%d", argIndex)
+		args = append(args, *filter.Outcome)
+		argIndex++
+	}
+	if filter.ReferenceID != nil {
+		where += fmt.Sprintf(" AND reference_id = $%d", argIndex)
+		args = append(args, *filter.ReferenceID)
+		argIndex++
+	}
+	if filter.Since != nil {
+		where += fmt.Sprintf(" AND created_at >= $%d", argIndex)
+		args = append(args, *filter.Since)
+		argIndex++
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM raw_payloads`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count raw payloads: %w", err)
+	}
+
+	query := `SELECT ` + payloadColumns + ` FROM raw_payloads` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list raw payloads: %w", err)
+	}
+	defer rows.Close()
+
+	var payloads []*Payload
+	for rows.Next() {
+		p := &Payload{}
+		if err := rows.Scan(scanTargets(p)...); err != nil {
+			return nil, 0, fmt.Errorf("scan raw payload: %w", err)
+		}
+		payloads = append(payloads, p)
+	}
+	return payloads, total, rows.Err()
+}
+
+// MarkDownloaded records an admin download of a payload
+func (r *Repository) MarkDownloaded(ctx context.Context, id uuid.UUID, userID *uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE raw_payloads SET
+			download_count = download_count + 1,
+			last_downloaded_at = NOW(),
+			last_downloaded_by = $2
+		WHERE id = $1
+	`, id, userID)
+	if err != nil {
+		return fmt.Errorf("mark raw payload downloaded: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes payloads past their retention period
+func (r *Repository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM raw_payloads WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired raw payloads: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+func scanTargets(p *Payload) []interface{} {
+	return []interface{}{
+		&p.ID, &p.TenantID, &p.ELDAAccountID, &p.Module, &p.Operation, &p.Endpoint,
+		&p.ReferenceType, &p.ReferenceID, &p.ProviderReference, &p.Outcome, &p.Attempt,
+		&p.StatusCode, &p.ErrorMessage, &p.RequestSize, &p.ResponseSize, &p.Truncated,
+		&p.DurationMS, &p.CreatedAt, &p.ExpiresAt, &p.DownloadCount, &p.LastDownloadedAt, &p.LastDownloadedBy,
+	}
+}
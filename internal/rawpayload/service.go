@@ -0,0 +1,222 @@
+package rawpayload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"austrian-business-infrastructure/internal/crypto"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultRetention keeps payloads for the BAO § 132 retention period, which
+	// also covers appeals against Bescheide based on the submission
+	DefaultRetention = 7 * 365 * 24 * time.Hour
+
+	// DefaultMaxPayloadSize caps each stored request or response body
+	DefaultMaxPayloadSize = 5 << 20
+)
+
+var (
+	ErrInvalidPart = errors.New("part must be 'request' or 'response'")
+)
+
+// Part selects the request or response body of a payload
+type Part string
+
+const (
+	PartRequest  Part = "request"
+	PartResponse Part = "response"
+)
+
+// Reference describes the submission a recorder belongs to
+type Reference struct {
+	TenantID          *uuid.UUID // Resolved from ELDAAccountID if nil
+	ELDAAccountID     *uuid.UUID
+	Module            Module
+	ReferenceType     string // e.g. uva_submission, elda_meldung, mbgm, l16
+	ReferenceID       *uuid.UUID
+	ProviderReference string
+	Outcome           Outcome
+}
+
+// ServiceConfig holds configuration for the raw payload service
+type ServiceConfig struct {
+	EncryptionKey  []byte // 32 bytes, AES-256-GCM
+	Retention      time.Duration
+	MaxPayloadSize int
+	Logger         *slog.Logger
+}
+
+// Service stores and retrieves encrypted raw provider exchanges
+type Service struct {
+	repo           *Repository
+	key            []byte
+	retention      time.Duration
+	maxPayloadSize int
+	logger         *slog.Logger
+}
+
+// NewService creates a new raw payload service
+func NewService(repo *Repository, cfg ServiceConfig) (*Service, error) {
+	if len(cfg.EncryptionKey) != crypto.KeySize {
+		return nil, crypto.ErrInvalidKeyLength
+	}
+
+	retention := cfg.Retention
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	maxSize := cfg.MaxPayloadSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxPayloadSize
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Service{
+		repo:           repo,
+		key:            cfg.EncryptionKey,
+		retention:      retention,
+		maxPayloadSize: maxSize,
+		logger:         logger,
+	}, nil
+}
+
+// Save stores every exchange of the recorder. Earlier attempts of a retried
+// call are stored as errors; the last one carries the submission outcome.
+// Failures are logged and never affect the submission itself. A nil service
+// or recorder is a no-op so callers need not check whether retention is enabled.
+func (s *Service) Save(ctx context.Context, ref Reference, rec *Recorder) {
+	if s == nil || rec == nil {
+		return
+	}
+
+	exchanges := rec.Exchanges()
+	for i, ex := range exchanges {
+		outcome := ref.Outcome
+		if i < len(exchanges)-1 || (outcome == OutcomeSuccess && ex.Error != "") {
+			outcome = OutcomeError
+		}
+
+		p, err := s.seal(ex)
+		if err != nil {
+			s.logger.Error("failed to encrypt raw payload", "module", ref.Module, "operation", ex.Operation, "error", err)
+			continue
+		}
+		p.ELDAAccountID = ref.ELDAAccountID
+		p.Module = ref.Module
+		p.ReferenceType = ref.ReferenceType
+		p.ReferenceID = ref.ReferenceID
+		p.ProviderReference = ref.ProviderReference
+		p.Outcome = outcome
+		p.Attempt = i + 1
+		p.ExpiresAt = time.Now().Add(s.retention)
+
+		if err := s.repo.Create(ctx, p, ref.TenantID); err != nil {
+			s.logger.Error("failed to store raw payload", "module", ref.Module, "operation", ex.Operation, "error", err)
+		}
+	}
+}
+
+// List returns payload metadata
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]*Payload, int, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Get returns payload metadata
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Payload, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
+}
+
+// Download decrypts the request or response body of a payload and records the download
+func (s *Service) Download(ctx context.Context, tenantID, id uuid.UUID, part Part, userID *uuid.UUID) (*Payload, []byte, error) {
+	var sealed []byte
+	p, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch part {
+	case PartRequest:
+		sealed = p.requestEncrypted
+	case PartResponse:
+		sealed = p.responseEncrypted
+	default:
+		return nil, nil, ErrInvalidPart
+	}
+
+	var body []byte
+	if len(sealed) > 0 {
+		body, err = crypto.Decrypt(sealed, s.key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("decrypt raw payload: %w", err)
+		}
+	}
+
+	if err := s.repo.MarkDownloaded(ctx, p.ID, userID); err != nil {
+		return nil, nil, err
+	}
+	return p, body, nil
+}
+
+// PurgeExpired deletes payloads past their retention period
+func (s *Service) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpired(ctx, time.Now())
+}
+
+func (s *Service) seal(ex Exchange) (*Payload, error) {
+	p := &Payload{
+		Operation:    ex.Operation,
+		Endpoint:     ex.Endpoint,
+		StatusCode:   ex.StatusCode,
+		ErrorMessage: ex.Error,
+		RequestSize:  len(ex.Request),
+		ResponseSize: len(ex.Response),
+		DurationMS:   ex.Duration.Milliseconds(),
+	}
+
+	request, truncReq := Truncate(ex.Request, s.maxPayloadSize)
+	response, truncResp := Truncate(ex.Response, s.maxPayloadSize)
+	p.Truncated = truncReq || truncResp
+
+	var err error
+	if len(request) > 0 {
+		if p.requestEncrypted, err = crypto.Encrypt(request, s.key); err != nil {
+			return nil, err
+		}
+	}
+	if len(response) > 0 {
+		if p.responseEncrypted, err = crypto.Encrypt(response, s.key); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// OutcomeOf classifies a submission result. answered reports whether the
+// provider returned a business response, which turns a failure into a rejection
+// rather than a transport error.
+func OutcomeOf(accepted, answered bool) Outcome {
+	switch {
+	case accepted:
+		return OutcomeSuccess
+	case answered:
+		return OutcomeRejected
+	default:
+		return OutcomeError
+	}
+}
+
+// Truncate caps a body at max bytes and reports whether it was cut
+func Truncate(b []byte, max int) ([]byte, bool) {
+	if max <= 0 || len(b) <= max {
+		return b, false
+	}
+	return b[:max], true
+}
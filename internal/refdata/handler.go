@@ -0,0 +1,84 @@
+package refdata
+
+import (
+	"net/http"
+	"time"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler exposes the reference parameters read-only. They are global to the
+// installation and changed by the operator through REFERENCE_DATA_FILE and
+// VAT_RATES_FILE.
+type Handler struct{}
+
+// NewHandler creates a new reference data handler
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// RegisterRoutes registers reference data routes
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/reference-data", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("GET /api/v1/reference-data/effective", requireAuth(http.HandlerFunc(h.Effective)))
+	router.Handle("GET /api/v1/reference-data/vat-rates", requireAuth(http.HandlerFunc(h.VATRates)))
+}
+
+// List handles GET /api/v1/reference-data
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"sets": Active().Sets(),
+	})
+}
+
+// Effective handles GET /api/v1/reference-data/effective?date=YYYY-MM-DD
+func (h *Handler) Effective(w http.ResponseWriter, r *http.Request) {
+	date := time.Now()
+	if s := r.URL.Query().Get("date"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			api.BadRequest(w, "invalid date, expected YYYY-MM-DD")
+			return
+		}
+		date = parsed
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"date":       date.Format("2006-01-02"),
+		"covered":    Active().Covers(date),
+		"parameters": For(date),
+	})
+}
+
+// VATRates handles GET /api/v1/reference-data/vat-rates. Without a country
+// all sets are listed; with ?country=DE&date=YYYY-MM-DD the rates in force on
+// the date (default today) are returned.
+func (h *Handler) VATRates(w http.ResponseWriter, r *http.Request) {
+	country := r.URL.Query().Get("country")
+	if country == "" {
+		api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"rates": ActiveVAT().Rates(),
+		})
+		return
+	}
+
+	date := time.Now()
+	if s := r.URL.Query().Get("date"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			api.BadRequest(w, "invalid date, expected YYYY-MM-DD")
+			return
+		}
+		date = parsed
+	}
+
+	rates, ok := VATRatesFor(country, date)
+	if !ok {
+		api.NotFound(w, "no VAT rates for country")
+		return
+	}
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"date":  date.Format("2006-01-02"),
+		"rates": rates,
+	})
+}
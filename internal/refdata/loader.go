@@ -0,0 +1,127 @@
+package refdata
+
+import (
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+//go:embed defaults.json
+var defaultsJSON []byte
+
+//go:embed vat_rates.json
+var vatRatesJSON []byte
+
+// OriginEmbedded marks the parameter sets shipped with the binary
+const OriginEmbedded = "embedded"
+
+// Defaults returns the table of the embedded parameter sets. An invalid
+// embedded set is a build defect and panics.
+func Defaults() *Table {
+	sets, err := ParseSets(defaultsJSON, OriginEmbedded)
+	if err != nil {
+		panic(fmt.Sprintf("refdata: %v", err))
+	}
+	t, err := NewTable(sets)
+	if err != nil {
+		panic(fmt.Sprintf("refdata: %v", err))
+	}
+	return t
+}
+
+// LoadFile reads additional parameter sets from a JSON file and merges them
+// over the embedded defaults. This is how yearly updates are rolled out
+// before a release containing them: a set for a year replaces the embedded set
+// starting on the same day.
+func LoadFile(path string) (*Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read reference data: %w", err)
+	}
+	sets, err := ParseSets(data, path)
+	if err != nil {
+		return nil, err
+	}
+	overlay, err := NewTable(sets)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return Defaults().Merge(overlay), nil
+}
+
+// Init activates the embedded defaults merged with the sets of path, if set,
+// and warns when no set has been published for the coming year. It is called
+// once at startup by the server and the worker.
+func Init(path string, logger *slog.Logger, now time.Time) error {
+	t := Defaults()
+	if path != "" {
+		var err error
+		if t, err = LoadFile(path); err != nil {
+			return err
+		}
+	}
+	SetActive(t)
+
+	latest := t.Sets()[len(t.Sets())-1]
+	logger.Info("reference parameters loaded", "sets", len(t.Sets()), "latest", latest.ValidFrom, "file", path)
+
+	if !t.Covers(now) {
+		logger.Warn("no reference parameters for the current year, calculations use the latest set",
+			"year", now.Year(), "latest", latest.ValidFrom)
+	} else if now.Month() >= time.November && !t.Covers(now.AddDate(1, 0, 0)) {
+		logger.Warn("no reference parameters for next year yet, add them before January",
+			"year", now.Year()+1, "latest", latest.ValidFrom)
+	}
+	return nil
+}
+
+// DefaultVATRates returns the table of the embedded VAT rates. Invalid
+// embedded rates are a build defect and panic.
+func DefaultVATRates() *VATTable {
+	rates, err := ParseVATRates(vatRatesJSON, OriginEmbedded)
+	if err != nil {
+		panic(fmt.Sprintf("refdata: %v", err))
+	}
+	t, err := NewVATTable(rates)
+	if err != nil {
+		panic(fmt.Sprintf("refdata: %v", err))
+	}
+	return t
+}
+
+// LoadVATFile reads additional VAT rates from a JSON file and merges them
+// over the embedded rates, so that a rate change is rolled out before a
+// release containing it: a set replaces the embedded set of the same country
+// starting on the same day.
+func LoadVATFile(path string) (*VATTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read VAT rates: %w", err)
+	}
+	rates, err := ParseVATRates(data, path)
+	if err != nil {
+		return nil, err
+	}
+	overlay, err := NewVATTable(rates)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return DefaultVATRates().Merge(overlay), nil
+}
+
+// InitVAT activates the embedded VAT rates merged with the rates of path, if
+// set. It is called once at startup by the server.
+func InitVAT(path string, logger *slog.Logger) error {
+	t := DefaultVATRates()
+	if path != "" {
+		var err error
+		if t, err = LoadVATFile(path); err != nil {
+			return err
+		}
+	}
+	SetActiveVAT(t)
+	logger.Info("VAT rates loaded", "countries", len(t.Countries()), "sets", len(t.Rates()), "file", path)
+	return nil
+}
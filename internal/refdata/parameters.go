@@ -0,0 +1,280 @@
+// Package refdata provides the statutory parameters that change yearly, such
+// as the Geringfügigkeitsgrenze, the Höchstbeitragsgrundlage, the Lohnsteuer
+// tariff, the Kleinunternehmer threshold and the BUAK Zuschläge. Each parameter set is valid from
+// a date until the next set starts; calculations look up the set for the date
+// they are about, never the current date, so past periods keep their values.
+package refdata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Parameters is a set of statutory parameters. Amounts are in cents, rates of
+// contributions in basis points.
+type Parameters struct {
+	ValidFrom string `json:"valid_from"` // YYYY-MM-DD
+	Source    string `json:"source,omitempty"`
+
+	// Sozialversicherung (ASVG), monthly
+	Geringfuegigkeitsgrenze  int64 `json:"geringfuegigkeitsgrenze"`
+	Hoechstbeitragsgrundlage int64 `json:"hoechstbeitragsgrundlage"`
+	SVDienstnehmerSatz       int64 `json:"sv_dienstnehmer_satz"` // Angestellte: KV, PV, AV, AK, WF
+	SVDienstgeberSatz        int64 `json:"sv_dienstgeber_satz"`  // Angestellte: KV, PV, AV, UV, IE, WF
+
+	// Lohnsteuer tariff (§ 33 EStG) on annual income
+	Lohnsteuerstufen []Stufe `json:"lohnsteuer_stufen"`
+
+	// Umsatzsteuer: annual gross turnover limit (§ 6 Abs. 1 Z 27 UStG)
+	KleinunternehmerGrenze int64 `json:"kleinunternehmer_grenze"`
+
+	// Deadlines: day of the following month the mBGM is due
+	MBGMFristTag int `json:"mbgm_frist_tag"`
+
+	// BUAK Zuschläge (BUAG). A set without them keeps those of the set
+	// before it, so yearly updates need not repeat unchanged rates.
+	BUAKZuschlagssaetze BUAKZuschlagssaetze `json:"buak_zuschlagssaetze"`
+
+	Origin string `json:"origin"` // "embedded" or the file it was loaded from
+
+	from time.Time
+}
+
+// BUAKZuschlagssaetze are the BUAK Zuschläge in basis points of the Lohnsumme
+type BUAKZuschlagssaetze struct {
+	Urlaub         int64 `json:"urlaub"`
+	Abfertigung    int64 `json:"abfertigung"`
+	Schlechtwetter int64 `json:"schlechtwetter"`
+	Ueberbrueckung int64 `json:"ueberbrueckung"`
+}
+
+// Stufe is a tax bracket. Bis is the upper limit of the bracket in cents; 0
+// marks the top bracket without limit.
+type Stufe struct {
+	Bis  int64   `json:"bis"`
+	Satz float64 `json:"satz"`
+}
+
+// From returns the first day the set applies to
+func (p *Parameters) From() time.Time {
+	return p.from
+}
+
+// EUR converts an amount in cents to euros
+func EUR(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+// Lohnsteuer applies the tariff to an annual taxable income in cents
+func (p *Parameters) Lohnsteuer(annual int64) int64 {
+	var steuer float64
+	var untergrenze int64
+	for _, s := range p.Lohnsteuerstufen {
+		if annual <= untergrenze {
+			break
+		}
+		obergrenze := s.Bis
+		if obergrenze == 0 {
+			obergrenze = math.MaxInt64
+		}
+		steuer += float64(min(annual, obergrenze)-untergrenze) * s.Satz
+		untergrenze = obergrenze
+	}
+	return int64(math.Round(steuer))
+}
+
+// validate checks a parameter set for plausibility
+func (p *Parameters) validate() error {
+	from, err := time.Parse("2006-01-02", p.ValidFrom)
+	if err != nil {
+		return fmt.Errorf("valid_from %q: expected YYYY-MM-DD", p.ValidFrom)
+	}
+	p.from = from
+
+	switch {
+	case p.Geringfuegigkeitsgrenze <= 0:
+		return errors.New("geringfuegigkeitsgrenze must be positive")
+	case p.Hoechstbeitragsgrundlage <= p.Geringfuegigkeitsgrenze:
+		return errors.New("hoechstbeitragsgrundlage must exceed the geringfuegigkeitsgrenze")
+	case p.SVDienstnehmerSatz <= 0 || p.SVDienstnehmerSatz >= 10000:
+		return errors.New("sv_dienstnehmer_satz must be between 1 and 9999 basis points")
+	case p.SVDienstgeberSatz <= 0 || p.SVDienstgeberSatz >= 10000:
+		return errors.New("sv_dienstgeber_satz must be between 1 and 9999 basis points")
+	case p.KleinunternehmerGrenze <= 0:
+		return errors.New("kleinunternehmer_grenze must be positive")
+	case p.MBGMFristTag < 1 || p.MBGMFristTag > 28:
+		return errors.New("mbgm_frist_tag must be between 1 and 28")
+	case len(p.Lohnsteuerstufen) == 0:
+		return errors.New("lohnsteuer_stufen must not be empty")
+	}
+	for name, bp := range map[string]int64{
+		"urlaub":         p.BUAKZuschlagssaetze.Urlaub,
+		"abfertigung":    p.BUAKZuschlagssaetze.Abfertigung,
+		"schlechtwetter": p.BUAKZuschlagssaetze.Schlechtwetter,
+		"ueberbrueckung": p.BUAKZuschlagssaetze.Ueberbrueckung,
+	} {
+		if bp < 0 || bp >= 10000 {
+			return fmt.Errorf("buak_zuschlagssaetze.%s must be between 0 and 9999 basis points", name)
+		}
+	}
+
+	var previous int64
+	for i, s := range p.Lohnsteuerstufen {
+		if s.Satz < 0 || s.Satz >= 1 {
+			return fmt.Errorf("lohnsteuer_stufen[%d]: satz must be between 0 and 1", i)
+		}
+		last := i == len(p.Lohnsteuerstufen)-1
+		if last != (s.Bis == 0) {
+			return fmt.Errorf("lohnsteuer_stufen[%d]: only the last bracket has no limit", i)
+		}
+		if !last && s.Bis <= previous {
+			return fmt.Errorf("lohnsteuer_stufen[%d]: limits must be ascending", i)
+		}
+		previous = s.Bis
+	}
+	return nil
+}
+
+// Table is an ordered collection of parameter sets
+type Table struct {
+	sets []*Parameters
+}
+
+// NewTable validates parameter sets and orders them by validity. Two sets
+// starting on the same day are rejected.
+func NewTable(sets []*Parameters) (*Table, error) {
+	if len(sets) == 0 {
+		return nil, errors.New("no parameter sets")
+	}
+	for _, p := range sets {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("parameter set %s: %w", p.ValidFrom, err)
+		}
+	}
+	sorted := append([]*Parameters(nil), sets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].from.Before(sorted[j].from) })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].from.Equal(sorted[i-1].from) {
+			return nil, fmt.Errorf("parameter set %s defined twice", sorted[i].ValidFrom)
+		}
+	}
+	carryOver(sorted)
+	return &Table{sets: sorted}, nil
+}
+
+// carryOver gives sets without BUAK Zuschläge those of the set before them
+func carryOver(sets []*Parameters) {
+	for i := 1; i < len(sets); i++ {
+		if sets[i].BUAKZuschlagssaetze == (BUAKZuschlagssaetze{}) {
+			sets[i].BUAKZuschlagssaetze = sets[i-1].BUAKZuschlagssaetze
+		}
+	}
+}
+
+// Merge returns a table in which the sets of overlay replace sets of t that
+// start on the same day
+func (t *Table) Merge(overlay *Table) *Table {
+	byDay := make(map[time.Time]*Parameters, len(t.sets)+len(overlay.sets))
+	for _, p := range t.sets {
+		byDay[p.from] = p
+	}
+	for _, p := range overlay.sets {
+		byDay[p.from] = p
+	}
+	merged := make([]*Parameters, 0, len(byDay))
+	for _, p := range byDay {
+		merged = append(merged, p)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].from.Before(merged[j].from) })
+	carryOver(merged)
+	return &Table{sets: merged}
+}
+
+// Sets returns all parameter sets in order of validity
+func (t *Table) Sets() []*Parameters {
+	return t.sets
+}
+
+// For returns the set in force on a date. Dates before the first set use the
+// first set; dates after the last set use the last one, see Covers.
+func (t *Table) For(date time.Time) *Parameters {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	current := t.sets[0]
+	for _, p := range t.sets[1:] {
+		if p.from.After(day) {
+			break
+		}
+		current = p
+	}
+	return current
+}
+
+// Covers reports whether a set has been published for the year of a date,
+// i.e. whether For returns values actually in force rather than carried over
+func (t *Table) Covers(date time.Time) bool {
+	return date.Year() <= t.sets[len(t.sets)-1].from.Year()
+}
+
+// ParseSets parses a JSON array of parameter sets, or a single set
+func ParseSets(data []byte, origin string) ([]*Parameters, error) {
+	var sets []*Parameters
+	if err := json.Unmarshal(data, &sets); err != nil {
+		var single Parameters
+		if err2 := json.Unmarshal(data, &single); err2 != nil {
+			return nil, fmt.Errorf("parse parameter sets: %w", err)
+		}
+		sets = []*Parameters{&single}
+	}
+	for _, p := range sets {
+		p.Origin = origin
+	}
+	return sets, nil
+}
+
+var (
+	active      atomic.Pointer[Table]
+	defaultOnce sync.Once
+	warned      sync.Map // year -> struct{}, carried-over years already logged
+)
+
+// Active returns the table used by For and ForYear. Until SetActive is called
+// this is the table of the embedded defaults.
+func Active() *Table {
+	if t := active.Load(); t != nil {
+		return t
+	}
+	defaultOnce.Do(func() {
+		active.CompareAndSwap(nil, Defaults())
+	})
+	return active.Load()
+}
+
+// SetActive replaces the table used by For and ForYear
+func SetActive(t *Table) {
+	active.Store(t)
+}
+
+// For returns the parameters in force on a date. If no set has been published
+// for the year yet the latest set is carried over and a warning is logged once.
+func For(date time.Time) *Parameters {
+	t := Active()
+	if !t.Covers(date) {
+		if _, seen := warned.LoadOrStore(date.Year(), struct{}{}); !seen {
+			slog.Warn("no reference parameters for year, using latest set",
+				"year", date.Year(), "valid_from", t.sets[len(t.sets)-1].ValidFrom)
+		}
+	}
+	return t.For(date)
+}
+
+// ForYear returns the parameters in force on January 1st of a year
+func ForYear(year int) *Parameters {
+	return For(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC))
+}
@@ -0,0 +1,229 @@
+package refdata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// VATRates are the VAT rates of a country, valid from a date until the next
+// set of the same country starts. Rates are in percent.
+type VATRates struct {
+	Country   string    `json:"country"`    // ISO 3166-1 alpha-2, GR for Greece
+	ValidFrom string    `json:"valid_from"` // YYYY-MM-DD
+	Standard  float64   `json:"standard"`
+	Reduced   []float64 `json:"reduced,omitempty"`
+	Source    string    `json:"source,omitempty"`
+
+	Origin string `json:"origin"` // "embedded" or the file it was loaded from
+
+	from time.Time
+}
+
+// From returns the first day the rates apply to
+func (r *VATRates) From() time.Time {
+	return r.from
+}
+
+// IsStandard reports whether rate is the standard rate
+func (r *VATRates) IsStandard(rate float64) bool {
+	return sameRate(r.Standard, rate)
+}
+
+// IsReduced reports whether rate is one of the reduced rates
+func (r *VATRates) IsReduced(rate float64) bool {
+	for _, reduced := range r.Reduced {
+		if sameRate(reduced, rate) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameRate compares two rates in percent, ignoring float noise
+func sameRate(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
+
+// validate checks a set of rates for plausibility
+func (r *VATRates) validate() error {
+	r.Country = normalizeCountry(r.Country)
+	if len(r.Country) != 2 {
+		return fmt.Errorf("country %q: expected an ISO 3166-1 alpha-2 code", r.Country)
+	}
+	from, err := time.Parse("2006-01-02", r.ValidFrom)
+	if err != nil {
+		return fmt.Errorf("valid_from %q: expected YYYY-MM-DD", r.ValidFrom)
+	}
+	r.from = from
+
+	if r.Standard <= 0 || r.Standard >= 100 {
+		return errors.New("standard must be between 0 and 100")
+	}
+	for i, reduced := range r.Reduced {
+		if reduced <= 0 || reduced >= r.Standard {
+			return fmt.Errorf("reduced[%d]: must be positive and below the standard rate", i)
+		}
+	}
+	return nil
+}
+
+// normalizeCountry upper-cases a country code and maps the VAT prefix EL of
+// Greece to its ISO code
+func normalizeCountry(country string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "EL" {
+		return "GR"
+	}
+	return country
+}
+
+// VATTable holds the VAT rates of each country, ordered by validity
+type VATTable struct {
+	countries map[string][]*VATRates
+}
+
+// NewVATTable validates sets of rates and orders them by country and
+// validity. Two sets of a country starting on the same day are rejected.
+func NewVATTable(rates []*VATRates) (*VATTable, error) {
+	if len(rates) == 0 {
+		return nil, errors.New("no VAT rates")
+	}
+	t := &VATTable{countries: make(map[string][]*VATRates)}
+	for _, r := range rates {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("VAT rates %s %s: %w", r.Country, r.ValidFrom, err)
+		}
+		t.countries[r.Country] = append(t.countries[r.Country], r)
+	}
+	for country, sets := range t.countries {
+		sort.Slice(sets, func(i, j int) bool { return sets[i].from.Before(sets[j].from) })
+		for i := 1; i < len(sets); i++ {
+			if sets[i].from.Equal(sets[i-1].from) {
+				return nil, fmt.Errorf("VAT rates %s %s defined twice", country, sets[i].ValidFrom)
+			}
+		}
+	}
+	return t, nil
+}
+
+// Merge returns a table in which the sets of overlay replace sets of t for
+// the same country starting on the same day
+func (t *VATTable) Merge(overlay *VATTable) *VATTable {
+	type key struct {
+		country string
+		from    time.Time
+	}
+	byDay := make(map[key]*VATRates)
+	for _, tables := range []*VATTable{t, overlay} {
+		for _, r := range tables.Rates() {
+			byDay[key{r.Country, r.from}] = r
+		}
+	}
+	merged := &VATTable{countries: make(map[string][]*VATRates)}
+	for k, r := range byDay {
+		merged.countries[k.country] = append(merged.countries[k.country], r)
+	}
+	for _, sets := range merged.countries {
+		sort.Slice(sets, func(i, j int) bool { return sets[i].from.Before(sets[j].from) })
+	}
+	return merged
+}
+
+// Countries returns the countries with VAT rates in alphabetical order
+func (t *VATTable) Countries() []string {
+	countries := make([]string, 0, len(t.countries))
+	for country := range t.countries {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+	return countries
+}
+
+// Rates returns all sets, by country and in order of validity
+func (t *VATTable) Rates() []*VATRates {
+	var rates []*VATRates
+	for _, country := range t.Countries() {
+		rates = append(rates, t.countries[country]...)
+	}
+	return rates
+}
+
+// For returns the rates of a country in force on a date. Dates before the
+// first set of the country use the first set. ok is false for countries
+// without rates, e.g. outside the EU.
+func (t *VATTable) For(country string, date time.Time) (rates *VATRates, ok bool) {
+	sets := t.countries[normalizeCountry(country)]
+	if len(sets) == 0 {
+		return nil, false
+	}
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	current := sets[0]
+	for _, r := range sets[1:] {
+		if r.from.After(day) {
+			break
+		}
+		current = r
+	}
+	return current, true
+}
+
+// ParseVATRates parses a JSON array of sets of VAT rates, or a single set
+func ParseVATRates(data []byte, origin string) ([]*VATRates, error) {
+	var rates []*VATRates
+	if err := json.Unmarshal(data, &rates); err != nil {
+		var single VATRates
+		if err2 := json.Unmarshal(data, &single); err2 != nil {
+			return nil, fmt.Errorf("parse VAT rates: %w", err)
+		}
+		rates = []*VATRates{&single}
+	}
+	for _, r := range rates {
+		r.Origin = origin
+	}
+	return rates, nil
+}
+
+var (
+	activeVAT      atomic.Pointer[VATTable]
+	vatDefaultOnce sync.Once
+)
+
+// ActiveVAT returns the table used by VATRatesFor. Until SetActiveVAT is
+// called this is the table of the embedded rates.
+func ActiveVAT() *VATTable {
+	if t := activeVAT.Load(); t != nil {
+		return t
+	}
+	vatDefaultOnce.Do(func() {
+		activeVAT.CompareAndSwap(nil, DefaultVATRates())
+	})
+	return activeVAT.Load()
+}
+
+// SetActiveVAT replaces the table used by VATRatesFor
+func SetActiveVAT(t *VATTable) {
+	activeVAT.Store(t)
+}
+
+// VATRatesFor returns the VAT rates of a country in force on the date of
+// supply. ok is false for countries without rates.
+func VATRatesFor(country string, date time.Time) (*VATRates, bool) {
+	return ActiveVAT().For(country, date)
+}
+
+// StandardVATRate returns the standard VAT rate of a country on the date of
+// supply
+func StandardVATRate(country string, date time.Time) (float64, bool) {
+	rates, ok := VATRatesFor(country, date)
+	if !ok {
+		return 0, false
+	}
+	return rates.Standard, true
+}
@@ -0,0 +1,308 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// maxDiffCells bounds the LCS table of a diff (lines stored × lines rendered)
+const maxDiffCells = 16_000_000
+
+var (
+	ErrDocumentTooLarge = errors.New("documents too large to diff")
+)
+
+// DiffLine is a changed line of a diff
+type DiffLine struct {
+	Op           string `json:"op"` // "+" only in the rendered document, "-" only in the baseline
+	Text         string `json:"text"`
+	BaselineLine int    `json:"baseline_line,omitempty"`
+	RenderedLine int    `json:"rendered_line,omitempty"`
+}
+
+// DiffResult is the line diff of two normalized XML documents
+type DiffResult struct {
+	Identical bool       `json:"identical"`
+	Added     int        `json:"added"`
+	Removed   int        `json:"removed"`
+	Changes   []DiffLine `json:"changes"`
+	Unified   string     `json:"unified"`
+}
+
+// Normalize pretty-prints an XML document into one element per line so that
+// documents differing only in formatting, attribute order, namespace
+// declarations order or the XML declaration compare equal. Elements whose
+// local name is in ignore are dropped, e.g. creation timestamps.
+func Normalize(doc []byte, ignore map[string]bool) ([]string, error) {
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	d.Strict = false
+
+	type open struct {
+		tag      string
+		line     int
+		children bool
+		text     string
+	}
+
+	var (
+		lines []string
+		stack []*open
+		skip  int
+	)
+
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse XML: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skip > 0 || ignore[t.Name.Local] {
+				skip++
+				continue
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = true
+				if parent.text != "" {
+					lines = append(lines, indent(len(stack))+parent.text)
+					parent.text = ""
+				}
+			}
+			lines = append(lines, indent(len(stack))+startTag(t))
+			stack = append(stack, &open{tag: qualified(t.Name), line: len(lines) - 1})
+
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if len(stack) == 0 {
+				return nil, errors.New("parse XML: unbalanced end element")
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !top.children {
+				// Leaf element on a single line
+				lines[top.line] += top.text + "</" + top.tag + ">"
+				continue
+			}
+			if top.text != "" {
+				lines = append(lines, indent(len(stack)+1)+top.text)
+			}
+			lines = append(lines, indent(len(stack))+"</"+top.tag+">")
+
+		case xml.CharData:
+			if skip > 0 || len(stack) == 0 {
+				continue
+			}
+			text := strings.TrimSpace(string(t))
+			if text == "" {
+				continue
+			}
+			var buf bytes.Buffer
+			xml.EscapeText(&buf, []byte(text))
+			stack[len(stack)-1].text += buf.String()
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, errors.New("parse XML: unexpected end of document")
+	}
+	return lines, nil
+}
+
+func indent(depth int) string {
+	return strings.Repeat("  ", depth)
+}
+
+func qualified(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+func startTag(t xml.StartElement) string {
+	attrs := make([]string, 0, len(t.Attr))
+	for _, a := range t.Attr {
+		var buf bytes.Buffer
+		xml.EscapeText(&buf, []byte(a.Value))
+		attrs = append(attrs, fmt.Sprintf("%s=%q", qualified(a.Name), buf.String()))
+	}
+	sort.Strings(attrs)
+
+	if len(attrs) == 0 {
+		return "<" + qualified(t.Name) + ">"
+	}
+	return "<" + qualified(t.Name) + " " + strings.Join(attrs, " ") + ">"
+}
+
+// Diff compares the normalized lines of a baseline and a rendered document
+func Diff(baseline, rendered []string) (*DiffResult, error) {
+	n, m := len(baseline), len(rendered)
+	if n*m > maxDiffCells {
+		return nil, ErrDocumentTooLarge
+	}
+
+	// lcs[i][j] is the LCS length of baseline[i:] and rendered[j:]
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if baseline[i] == rendered[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []DiffLine
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && baseline[i] == rendered[j]:
+			ops = append(ops, DiffLine{Op: " ", Text: baseline[i], BaselineLine: i + 1, RenderedLine: j + 1})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, DiffLine{Op: "-", Text: baseline[i], BaselineLine: i + 1})
+			i++
+		default:
+			ops = append(ops, DiffLine{Op: "+", Text: rendered[j], RenderedLine: j + 1})
+			j++
+		}
+	}
+
+	result := &DiffResult{Changes: []DiffLine{}}
+	for _, op := range ops {
+		switch op.Op {
+		case "+":
+			result.Added++
+			result.Changes = append(result.Changes, op)
+		case "-":
+			result.Removed++
+			result.Changes = append(result.Changes, op)
+		}
+	}
+	result.Identical = result.Added == 0 && result.Removed == 0
+	result.Unified = unified(ops, 3)
+	return result, nil
+}
+
+// unified renders the diff operations as unified diff hunks
+func unified(ops []DiffLine, context int) string {
+	var b strings.Builder
+	for start := 0; start < len(ops); {
+		// Find the next change
+		first := start
+		for first < len(ops) && ops[first].Op == " " {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are within 2*context lines of each other
+		last := first
+		for k := first; k < len(ops) && k-last <= 2*context; k++ {
+			if ops[k].Op != " " {
+				last = k
+			}
+		}
+
+		from := max(first-context, start)
+		to := min(last+context+1, len(ops))
+
+		baseStart, baseCount, rendStart, rendCount := 0, 0, 0, 0
+		for _, op := range ops[from:to] {
+			if op.Op != "+" {
+				if baseStart == 0 {
+					baseStart = op.BaselineLine
+				}
+				baseCount++
+			}
+			if op.Op != "-" {
+				if rendStart == 0 {
+					rendStart = op.RenderedLine
+				}
+				rendCount++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", baseStart, baseCount, rendStart, rendCount)
+		for _, op := range ops[from:to] {
+			b.WriteString(op.Op)
+			b.WriteString(op.Text)
+			b.WriteByte('\n')
+		}
+
+		start = to
+	}
+	return b.String()
+}
+
+// ExtractDocument returns the business document of a recorded SOAP request:
+// the base64 file of a FinanzOnline upload, the XML string of an ELDA
+// document submission, or otherwise the content of the SOAP body.
+func ExtractDocument(envelope []byte) ([]byte, error) {
+	var env struct {
+		Body struct {
+			Inner []byte `xml:",innerxml"`
+		} `xml:"Body"`
+	}
+	if err := xml.Unmarshal(envelope, &env); err != nil {
+		return nil, fmt.Errorf("parse SOAP envelope: %w", err)
+	}
+	body := bytes.TrimSpace(env.Body.Inner)
+	if len(body) == 0 {
+		return nil, errors.New("SOAP body is empty")
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "data":
+			var encoded string
+			if err := d.DecodeElement(&encoded, &start); err != nil {
+				return nil, fmt.Errorf("read upload data: %w", err)
+			}
+			doc, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+			if err != nil {
+				return nil, fmt.Errorf("decode upload data: %w", err)
+			}
+			return doc, nil
+		case "Document":
+			var inline string
+			if err := d.DecodeElement(&inline, &start); err != nil {
+				return nil, fmt.Errorf("read document: %w", err)
+			}
+			if strings.HasPrefix(strings.TrimSpace(inline), "<") {
+				return []byte(inline), nil
+			}
+		}
+	}
+
+	return body, nil
+}
@@ -0,0 +1,122 @@
+package replay
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// Handler handles submission replay HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new replay handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers replay routes. Replays expose full submission
+// documents, so all routes are admin-only.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/replay", requireAuth(requireAdmin(http.HandlerFunc(h.ListKinds))))
+	router.Handle("GET /api/v1/replay/{kind}/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Compare))))
+	router.Handle("POST /api/v1/replay/{kind}/{id}/sandbox", requireAuth(requireAdmin(http.HandlerFunc(h.SubmitSandbox))))
+}
+
+// ListKinds handles GET /api/v1/replay
+func (h *Handler) ListKinds(w http.ResponseWriter, r *http.Request) {
+	available := h.service.Kinds()
+	names := make([]string, 0, len(available))
+	for kind := range available {
+		names = append(names, string(kind))
+	}
+	sort.Strings(names)
+
+	kinds := []map[string]interface{}{}
+	for _, name := range names {
+		kinds = append(kinds, map[string]interface{}{
+			"kind":    name,
+			"sandbox": available[Kind(name)],
+		})
+	}
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"kinds": kinds})
+}
+
+// Compare handles GET /api/v1/replay/{kind}/{id}?baseline=payload|stored&ignore=Datum,...
+func (h *Handler) Compare(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid submission ID")
+		return
+	}
+
+	opts := CompareOptions{
+		Baseline: Baseline(r.URL.Query().Get("baseline")),
+	}
+	if ignore := r.URL.Query().Get("ignore"); ignore != "" {
+		for _, name := range strings.Split(ignore, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				opts.Ignore = append(opts.Ignore, name)
+			}
+		}
+	}
+	if userID, err := uuid.Parse(api.GetUserID(r.Context())); err == nil {
+		opts.UserID = &userID
+	}
+
+	cmp, err := h.service.Compare(r.Context(), tenantID, Kind(r.PathValue("kind")), id, opts)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	api.JSONResponse(w, http.StatusOK, cmp)
+}
+
+// SubmitSandbox handles POST /api/v1/replay/{kind}/{id}/sandbox
+func (h *Handler) SubmitSandbox(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid submission ID")
+		return
+	}
+
+	result, err := h.service.SubmitSandbox(r.Context(), tenantID, Kind(r.PathValue("kind")), id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	api.JSONResponse(w, http.StatusOK, result)
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownKind), errors.Is(err, ErrSubmissionNotFound), errors.Is(err, ErrNoBaseline):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrInvalidBaseline), errors.Is(err, ErrSandboxUnsupported),
+		errors.Is(err, ErrPayloadsUnavailable), errors.Is(err, ErrDocumentTooLarge):
+		api.BadRequest(w, err.Error())
+	default:
+		api.InternalError(w)
+	}
+}
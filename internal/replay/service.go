@@ -0,0 +1,242 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"austrian-business-infrastructure/internal/rawpayload"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownKind         = errors.New("unknown submission kind")
+	ErrSandboxUnsupported  = errors.New("sandbox submission is not available for this kind")
+	ErrNoBaseline          = errors.New("no stored XML or recorded payload for this submission")
+	ErrInvalidBaseline     = errors.New("baseline must be 'payload' or 'stored'")
+	ErrSubmissionNotFound  = errors.New("submission not found")
+	ErrPayloadsUnavailable = errors.New("raw payload retention is not configured")
+)
+
+// Kind identifies the kind of submission a replay targets
+type Kind string
+
+const (
+	KindUVA         Kind = "uva"
+	KindZM          Kind = "zm"
+	KindELDAMeldung Kind = "elda_meldung"
+)
+
+// Baseline selects what the re-rendered XML is compared against
+type Baseline string
+
+const (
+	// BaselinePayload is the document of the last recorded provider request
+	BaselinePayload Baseline = "payload"
+	// BaselineStored is the XML persisted on the submission record
+	BaselineStored Baseline = "stored"
+)
+
+// Rendition is a submission re-rendered with the current code
+type Rendition struct {
+	StoredXML []byte // XML persisted on the submission at submit time
+	XML       []byte // Re-rendered, comparable with StoredXML
+	WireXML   []byte // Re-rendered as sent to the provider; nil if identical to XML
+}
+
+// SandboxResult is the outcome of a sandbox re-submission
+type SandboxResult struct {
+	Success           bool      `json:"success"`
+	ProviderReference string    `json:"provider_reference,omitempty"`
+	ErrorCode         string    `json:"error_code,omitempty"`
+	ErrorMessage      string    `json:"error_message,omitempty"`
+	Warnings          []string  `json:"warnings,omitempty"`
+	Endpoint          string    `json:"endpoint,omitempty"`
+	StatusCode        int       `json:"status_code,omitempty"`
+	Request           string    `json:"request,omitempty"`
+	Response          string    `json:"response,omitempty"`
+	SubmittedAt       time.Time `json:"submitted_at"`
+}
+
+// Source re-renders the XML of submissions of one kind
+type Source interface {
+	Render(ctx context.Context, tenantID, id uuid.UUID) (*Rendition, error)
+}
+
+// SandboxSource is a source that can re-submit to the provider's test system
+type SandboxSource interface {
+	Source
+	SubmitSandbox(ctx context.Context, tenantID, id uuid.UUID) (*SandboxResult, error)
+}
+
+// CompareOptions controls a comparison
+type CompareOptions struct {
+	Baseline Baseline // Empty picks the recorded payload if there is one, else the stored XML
+	Ignore   []string // Element names left out of the diff, e.g. Datum
+	UserID   *uuid.UUID
+}
+
+// Comparison is the diff between a past submission and its re-rendering
+type Comparison struct {
+	Kind        Kind        `json:"kind"`
+	ID          uuid.UUID   `json:"id"`
+	Baseline    Baseline    `json:"baseline"`
+	PayloadID   *uuid.UUID  `json:"payload_id,omitempty"`
+	RecordedAt  *time.Time  `json:"recorded_at,omitempty"`
+	Ignored     []string    `json:"ignored,omitempty"`
+	Diff        *DiffResult `json:"diff"`
+	BaselineXML string      `json:"baseline_xml"`
+	RenderedXML string      `json:"rendered_xml"`
+	ComparedAt  time.Time   `json:"compared_at"`
+}
+
+// Service compares past submissions with the output of the current code
+type Service struct {
+	sources  map[Kind]Source
+	payloads *rawpayload.Service
+}
+
+// NewService creates a new replay service. payloads may be nil, in which case
+// only the stored XML can serve as baseline.
+func NewService(payloads *rawpayload.Service) *Service {
+	return &Service{
+		sources:  make(map[Kind]Source),
+		payloads: payloads,
+	}
+}
+
+// Register adds the source for a kind of submission
+func (s *Service) Register(kind Kind, source Source) {
+	s.sources[kind] = source
+}
+
+// Kinds lists the registered kinds and whether they support sandbox re-submission
+func (s *Service) Kinds() map[Kind]bool {
+	kinds := make(map[Kind]bool, len(s.sources))
+	for kind, source := range s.sources {
+		_, sandbox := source.(SandboxSource)
+		kinds[kind] = sandbox
+	}
+	return kinds
+}
+
+// Compare re-renders a submission and diffs it against the baseline
+func (s *Service) Compare(ctx context.Context, tenantID uuid.UUID, kind Kind, id uuid.UUID, opts CompareOptions) (*Comparison, error) {
+	source, ok := s.sources[kind]
+	if !ok {
+		return nil, ErrUnknownKind
+	}
+	if opts.Baseline != "" && opts.Baseline != BaselinePayload && opts.Baseline != BaselineStored {
+		return nil, ErrInvalidBaseline
+	}
+
+	rendition, err := source.Render(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := &Comparison{
+		Kind:       kind,
+		ID:         id,
+		Ignored:    opts.Ignore,
+		ComparedAt: time.Now(),
+	}
+
+	var baseline, rendered []byte
+	if opts.Baseline != BaselineStored {
+		payload, doc, err := s.recordedDocument(ctx, tenantID, id, opts.UserID)
+		switch {
+		case err == nil:
+			cmp.Baseline = BaselinePayload
+			cmp.PayloadID = &payload.ID
+			cmp.RecordedAt = &payload.CreatedAt
+			baseline = doc
+			rendered = rendition.WireXML
+			if rendered == nil {
+				rendered = rendition.XML
+			}
+		case opts.Baseline == BaselinePayload:
+			return nil, err
+		case !errors.Is(err, ErrNoBaseline) && !errors.Is(err, ErrPayloadsUnavailable):
+			return nil, err
+		}
+	}
+	if cmp.Baseline == "" {
+		if len(rendition.StoredXML) == 0 {
+			return nil, ErrNoBaseline
+		}
+		cmp.Baseline = BaselineStored
+		baseline = rendition.StoredXML
+		rendered = rendition.XML
+	}
+
+	ignore := make(map[string]bool, len(opts.Ignore))
+	for _, name := range opts.Ignore {
+		ignore[name] = true
+	}
+
+	baseLines, err := Normalize(baseline, ignore)
+	if err != nil {
+		return nil, fmt.Errorf("baseline: %w", err)
+	}
+	renderedLines, err := Normalize(rendered, ignore)
+	if err != nil {
+		return nil, fmt.Errorf("rendered: %w", err)
+	}
+
+	cmp.Diff, err = Diff(baseLines, renderedLines)
+	if err != nil {
+		return nil, err
+	}
+	cmp.BaselineXML = string(baseline)
+	cmp.RenderedXML = string(rendered)
+	return cmp, nil
+}
+
+// recordedDocument returns the document of the last recorded request for a submission
+func (s *Service) recordedDocument(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) (*rawpayload.Payload, []byte, error) {
+	if s.payloads == nil {
+		return nil, nil, ErrPayloadsUnavailable
+	}
+
+	payloads, _, err := s.payloads.List(ctx, rawpayload.ListFilter{
+		TenantID:    tenantID,
+		ReferenceID: &id,
+		Limit:       1,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(payloads) == 0 || payloads[0].RequestSize == 0 {
+		return nil, nil, ErrNoBaseline
+	}
+
+	payload, envelope, err := s.payloads.Download(ctx, tenantID, payloads[0].ID, rawpayload.PartRequest, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(envelope) < payload.RequestSize {
+		return nil, nil, fmt.Errorf("recorded request was truncated at %d of %d bytes", len(envelope), payload.RequestSize)
+	}
+
+	doc, err := ExtractDocument(envelope)
+	if err != nil {
+		return nil, nil, err
+	}
+	return payload, doc, nil
+}
+
+// SubmitSandbox re-submits a submission to the provider's test system. The
+// stored submission is not changed.
+func (s *Service) SubmitSandbox(ctx context.Context, tenantID uuid.UUID, kind Kind, id uuid.UUID) (*SandboxResult, error) {
+	source, ok := s.sources[kind]
+	if !ok {
+		return nil, ErrUnknownKind
+	}
+	sandbox, ok := source.(SandboxSource)
+	if !ok {
+		return nil, ErrSandboxUnsupported
+	}
+	return sandbox.SubmitSandbox(ctx, tenantID, id)
+}
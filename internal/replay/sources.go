@@ -0,0 +1,148 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/eldameldung"
+	"austrian-business-infrastructure/internal/rawpayload"
+	"austrian-business-infrastructure/internal/uva"
+	"austrian-business-infrastructure/internal/zm"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UVASource re-renders UVA submissions. FinanzOnline has no test system for
+// file uploads, so UVAs cannot be re-submitted in a sandbox.
+type UVASource struct {
+	service *uva.Service
+}
+
+// NewUVASource creates a UVA replay source
+func NewUVASource(service *uva.Service) *UVASource {
+	return &UVASource{service: service}
+}
+
+// Render re-renders the UVA XML
+func (s *UVASource) Render(ctx context.Context, tenantID, id uuid.UUID) (*Rendition, error) {
+	submission, xmlContent, err := s.service.RenderXML(ctx, id, tenantID)
+	if errors.Is(err, uva.ErrSubmissionNotFound) {
+		return nil, ErrSubmissionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Rendition{StoredXML: submission.XMLContent, XML: xmlContent}, nil
+}
+
+// ZMSource re-renders ZM submissions
+type ZMSource struct {
+	service *zm.Service
+}
+
+// NewZMSource creates a ZM replay source
+func NewZMSource(service *zm.Service) *ZMSource {
+	return &ZMSource{service: service}
+}
+
+// Render re-renders the ZM XML
+func (s *ZMSource) Render(ctx context.Context, tenantID, id uuid.UUID) (*Rendition, error) {
+	submission, xmlContent, err := s.service.RenderXML(ctx, id, tenantID)
+	if errors.Is(err, zm.ErrSubmissionNotFound) {
+		return nil, ErrSubmissionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Rendition{StoredXML: submission.XMLContent, XML: xmlContent}, nil
+}
+
+// ELDAMeldungSource re-renders ELDA An-, Ab- and Änderungsmeldungen and
+// re-submits them to the ELDA test endpoint
+type ELDAMeldungSource struct {
+	service *eldameldung.Service
+	sandbox *elda.Client
+	db      *pgxpool.Pool
+}
+
+// NewELDAMeldungSource creates an ELDA meldung replay source. sandbox must be
+// a client for the ELDA test endpoint; nil disables sandbox re-submission.
+func NewELDAMeldungSource(service *eldameldung.Service, sandbox *elda.Client, db *pgxpool.Pool) *ELDAMeldungSource {
+	return &ELDAMeldungSource{service: service, sandbox: sandbox, db: db}
+}
+
+// Render re-renders the meldung XML
+func (s *ELDAMeldungSource) Render(ctx context.Context, tenantID, id uuid.UUID) (*Rendition, error) {
+	rendition, err := s.service.Render(ctx, id)
+	if errors.Is(err, eldameldung.ErrMeldungNotFound) {
+		return nil, ErrSubmissionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTenant(ctx, tenantID, rendition.Meldung.ELDAAccountID); err != nil {
+		return nil, err
+	}
+
+	return &Rendition{
+		StoredXML: []byte(rendition.Meldung.RequestXML),
+		XML:       rendition.XML,
+		WireXML:   rendition.WireXML,
+	}, nil
+}
+
+// SubmitSandbox re-submits the meldung to the ELDA test endpoint
+func (s *ELDAMeldungSource) SubmitSandbox(ctx context.Context, tenantID, id uuid.UUID) (*SandboxResult, error) {
+	if s.sandbox == nil {
+		return nil, ErrSandboxUnsupported
+	}
+
+	// Render first so foreign meldungen are rejected before anything is sent
+	if _, err := s.Render(ctx, tenantID, id); err != nil {
+		return nil, err
+	}
+
+	rec := rawpayload.NewRecorder()
+	res, err := s.service.SubmitSandbox(rawpayload.WithRecorder(ctx, rec), id, s.sandbox)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SandboxResult{
+		Success:           res.Success,
+		ProviderReference: res.Protokollnummer,
+		ErrorCode:         res.ErrorCode,
+		ErrorMessage:      res.ErrorMessage,
+		Warnings:          res.Warnings,
+		SubmittedAt:       res.SubmittedAt,
+	}
+	if exchanges := rec.Exchanges(); len(exchanges) > 0 {
+		last := exchanges[len(exchanges)-1]
+		result.Endpoint = last.Endpoint
+		result.StatusCode = last.StatusCode
+		result.Request = string(last.Request)
+		result.Response = string(last.Response)
+	}
+	return result, nil
+}
+
+// checkTenant verifies that the ELDA account of a meldung belongs to the tenant
+func (s *ELDAMeldungSource) checkTenant(ctx context.Context, tenantID, eldaAccountID uuid.UUID) error {
+	var owned bool
+	err := s.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM elda_accounts e
+			JOIN accounts a ON a.id = e.account_id
+			WHERE e.id = $1 AND a.tenant_id = $2
+		)
+	`, eldaAccountID, tenantID).Scan(&owned)
+	if err != nil {
+		return fmt.Errorf("check ELDA account: %w", err)
+	}
+	if !owned {
+		return ErrSubmissionNotFound
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package retention
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler handles deletion protocol HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new retention handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the deletion protocol routes; they are open to
+// tenant admins only
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/retention/protocols", requireAuth(requireAdmin(http.HandlerFunc(h.List))))
+	router.Handle("GET /api/v1/retention/protocols/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Get))))
+	router.Handle("GET /api/v1/retention/protocols/{id}/download", requireAuth(requireAdmin(http.HandlerFunc(h.Download))))
+	router.Handle("GET /api/v1/retention/protocols/{id}/verify", requireAuth(requireAdmin(http.HandlerFunc(h.Verify))))
+}
+
+// List handles GET /api/v1/retention/protocols
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset := 50, 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	protocols, total, err := h.service.List(r.Context(), tenantID, limit, offset)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if protocols == nil {
+		protocols = []*Protocol{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"protocols": protocols,
+		"total":     total,
+		"limit":     limit,
+		"offset":    offset,
+	})
+}
+
+// ProtocolResponse is a deletion protocol with its signed content
+type ProtocolResponse struct {
+	*Protocol
+	Content *Content `json:"content"`
+}
+
+// Get handles GET /api/v1/retention/protocols/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	p, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	content, err := p.Decode()
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, ProtocolResponse{Protocol: p, Content: content})
+}
+
+// Download handles GET /api/v1/retention/protocols/{id}/download. It
+// returns the signed document byte for byte, with its hash and signature
+// in headers, so that it can be verified outside the platform.
+func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	p, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=loeschprotokoll_"+p.StartedAt.Format("2006-01-02")+"_"+p.ID.String()+".json")
+	w.Header().Set("X-Content-SHA256", p.SHA256)
+	w.Header().Set("X-Signature", "hmac-sha256="+p.Signature)
+	w.Header().Set("X-Signature-Key-ID", p.KeyID)
+	w.WriteHeader(http.StatusOK)
+	w.Write(p.Document)
+}
+
+// Verify handles GET /api/v1/retention/protocols/{id}/verify
+func (h *Handler) Verify(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	v, err := h.service.Verify(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrProtocolNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrNotConfigured):
+		api.JSONError(w, http.StatusServiceUnavailable, err.Error(), api.ErrCodeServiceUnavailable)
+	default:
+		api.InternalError(w)
+	}
+}
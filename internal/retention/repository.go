@@ -0,0 +1,118 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository provides deletion protocol data access. Protocols are only
+// ever inserted; the table rejects updates and deletes.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new retention repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const protocolColumns = `id, tenant_id, started_at, finished_at, deleted_count, failed_count,
+	sha256, signature, key_id, created_at`
+
+func scanProtocol(row pgx.Row, withDocument bool) (*Protocol, error) {
+	p := &Protocol{}
+	dest := []interface{}{&p.ID, &p.TenantID, &p.StartedAt, &p.FinishedAt, &p.DeletedCount, &p.FailedCount,
+		&p.SHA256, &p.Signature, &p.KeyID, &p.CreatedAt}
+	if withDocument {
+		dest = append(dest, &p.Document)
+	}
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrProtocolNotFound
+		}
+		return nil, fmt.Errorf("scan deletion protocol: %w", err)
+	}
+	return p, nil
+}
+
+// Create stores a deletion protocol
+func (r *Repository) Create(ctx context.Context, p *Protocol) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO retention_protocols (id, tenant_id, started_at, finished_at, deleted_count, failed_count,
+			document, sha256, signature, key_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at
+	`, p.ID, p.TenantID, p.StartedAt, p.FinishedAt, p.DeletedCount, p.FailedCount,
+		p.Document, p.SHA256, p.Signature, p.KeyID).Scan(&p.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create deletion protocol: %w", err)
+	}
+	return nil
+}
+
+// Get returns a deletion protocol of a tenant with its document
+func (r *Repository) Get(ctx context.Context, tenantID, id uuid.UUID) (*Protocol, error) {
+	return scanProtocol(r.db.QueryRow(ctx, `
+		SELECT `+protocolColumns+`, document FROM retention_protocols WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id), true)
+}
+
+// List returns the deletion protocols of a tenant without their documents,
+// newest first
+func (r *Repository) List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*Protocol, int, error) {
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM retention_protocols WHERE tenant_id = $1`, tenantID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count deletion protocols: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+protocolColumns+` FROM retention_protocols
+		WHERE tenant_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2 OFFSET $3
+	`, tenantID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list deletion protocols: %w", err)
+	}
+	defer rows.Close()
+
+	var protocols []*Protocol
+	for rows.Next() {
+		p, err := scanProtocol(rows, false)
+		if err != nil {
+			return nil, 0, err
+		}
+		protocols = append(protocols, p)
+	}
+	return protocols, total, rows.Err()
+}
+
+// TenantsWithExpiredDocuments returns the tenants that have documents past
+// their retention date
+func (r *Repository) TenantsWithExpiredDocuments(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT a.tenant_id
+		FROM documents d
+		JOIN accounts a ON d.account_id = a.id
+		WHERE d.retention_until < NOW()
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list tenants with expired documents: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan tenant: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,176 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/document"
+)
+
+// batchSize is the number of expired documents deleted per batch
+const batchSize = 100
+
+// Documents finds and deletes documents past their retention date;
+// document.Service implements it
+type Documents interface {
+	GetExpired(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*document.Document, int, error)
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+}
+
+// Service runs retention-based deletion and keeps its protocols
+type Service struct {
+	repo   *Repository
+	docs   Documents
+	signer *Signer
+}
+
+// NewService creates a new retention service. docs may be nil where
+// protocols are only read; without a signer, nothing is deleted and
+// protocols cannot be verified.
+func NewService(repo *Repository, docs Documents, signer *Signer) *Service {
+	return &Service{repo: repo, docs: docs, signer: signer}
+}
+
+// Configured reports whether deletion protocols can be signed
+func (s *Service) Configured() bool {
+	return s.signer != nil
+}
+
+// TenantsDue returns the tenants with documents past their retention date
+func (s *Service) TenantsDue(ctx context.Context) ([]uuid.UUID, error) {
+	return s.repo.TenantsWithExpiredDocuments(ctx)
+}
+
+// Run deletes the documents of a tenant past their retention date and
+// stores the protocol of the run. It returns nil if there was nothing to
+// delete. If the run is cut short, the protocol of what was deleted so far
+// is still stored and returned with the error.
+func (s *Service) Run(ctx context.Context, tenantID uuid.UUID) (*Protocol, error) {
+	if s.signer == nil || s.docs == nil {
+		return nil, ErrNotConfigured
+	}
+
+	content := &Content{
+		Version:       ProtocolVersion,
+		ProtocolID:    uuid.New(),
+		TenantID:      tenantID,
+		StartedAt:     time.Now().UTC(),
+		DeletionBasis: DeletionBasis,
+		Entries:       []Entry{},
+		Failures:      []Failure{},
+	}
+
+	var runErr error
+	for runErr == nil {
+		if runErr = ctx.Err(); runErr != nil {
+			break
+		}
+
+		// Documents that failed stay expired and come first again, so the
+		// next batch starts after them
+		docs, _, err := s.docs.GetExpired(ctx, tenantID, batchSize, len(content.Failures))
+		if err != nil {
+			runErr = err
+			break
+		}
+
+		for _, doc := range docs {
+			var retentionUntil time.Time
+			if doc.RetentionUntil != nil {
+				retentionUntil = doc.RetentionUntil.UTC()
+			}
+			if err := s.docs.Delete(ctx, tenantID, doc.ID); err != nil {
+				content.Failures = append(content.Failures, Failure{
+					DocumentID:     doc.ID,
+					Type:           doc.Type,
+					RetentionUntil: retentionUntil,
+					Error:          err.Error(),
+				})
+				continue
+			}
+
+			class, basis := Classify(doc.Type)
+			content.Entries = append(content.Entries, Entry{
+				DocumentID:     doc.ID,
+				AccountID:      doc.AccountID,
+				Type:           doc.Type,
+				Class:          class,
+				RetentionBasis: basis,
+				ReceivedAt:     doc.ReceivedAt.UTC(),
+				RetentionUntil: retentionUntil,
+				ContentHash:    doc.ContentHash,
+				FileSize:       doc.FileSize,
+				DeletedAt:      time.Now().UTC(),
+			})
+		}
+
+		if len(docs) < batchSize {
+			break
+		}
+	}
+
+	if len(content.Entries) == 0 && len(content.Failures) == 0 {
+		return nil, runErr
+	}
+
+	// Documents already deleted must not go without a protocol, even if
+	// the run was cancelled
+	p, err := s.store(context.WithoutCancel(ctx), content)
+	if err != nil {
+		return nil, err
+	}
+	return p, runErr
+}
+
+// store signs and stores the protocol content
+func (s *Service) store(ctx context.Context, content *Content) (*Protocol, error) {
+	content.FinishedAt = time.Now().UTC()
+	content.DeletedCount = len(content.Entries)
+	content.FailedCount = len(content.Failures)
+
+	doc, hash, signature, err := s.signer.Seal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Protocol{
+		ID:           content.ProtocolID,
+		TenantID:     content.TenantID,
+		StartedAt:    content.StartedAt,
+		FinishedAt:   content.FinishedAt,
+		DeletedCount: content.DeletedCount,
+		FailedCount:  content.FailedCount,
+		SHA256:       hash,
+		Signature:    signature,
+		KeyID:        s.signer.KeyID(),
+		Document:     doc,
+	}
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// List returns the deletion protocols of a tenant, newest first
+func (s *Service) List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*Protocol, int, error) {
+	return s.repo.List(ctx, tenantID, limit, offset)
+}
+
+// Get returns a deletion protocol of a tenant with its signed document
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Protocol, error) {
+	return s.repo.Get(ctx, tenantID, id)
+}
+
+// Verify checks that a stored protocol is unchanged since it was signed
+func (s *Service) Verify(ctx context.Context, tenantID, id uuid.UUID) (*Verification, error) {
+	if s.signer == nil {
+		return nil, ErrNotConfigured
+	}
+	p, err := s.repo.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.signer.Verify(p), nil
+}
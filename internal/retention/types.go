@@ -0,0 +1,97 @@
+// Package retention deletes documents past their retention date and keeps a
+// deletion protocol of every run. The protocol lists what was deleted,
+// under which retention class and legal basis, and when; it is signed and
+// cannot be changed or deleted afterwards, so that tenants can demonstrate
+// their deletion process in DSGVO audits.
+package retention
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrProtocolNotFound = errors.New("deletion protocol not found")
+	ErrNotConfigured    = errors.New("deletion protocols are not configured")
+)
+
+// DeletionBasis is the legal basis of deleting a document once its
+// retention period has ended
+const DeletionBasis = "Art. 5 Abs. 1 lit. e, Art. 17 Abs. 1 lit. a DSGVO"
+
+// ProtocolVersion is the version of the protocol format
+const ProtocolVersion = 1
+
+// Retention classes of documents
+const (
+	ClassAbgabenverfahren   = "abgabenverfahren"
+	ClassBelege             = "belege"
+	ClassGeschaeftsbriefe   = "geschaeftsbriefe"
+	ClassSonstigeUnterlagen = "sonstige_unterlagen"
+)
+
+// Entry is a document deleted in a run. It holds no title, sender or
+// content, so the protocol keeps no personal data of the deleted document.
+type Entry struct {
+	DocumentID     uuid.UUID `json:"document_id"`
+	AccountID      uuid.UUID `json:"account_id"`
+	Type           string    `json:"type"`
+	Class          string    `json:"retention_class"`
+	RetentionBasis string    `json:"retention_basis"`
+	ReceivedAt     time.Time `json:"received_at"`
+	RetentionUntil time.Time `json:"retention_until"`
+	ContentHash    string    `json:"content_hash,omitempty"`
+	FileSize       int       `json:"file_size"`
+	DeletedAt      time.Time `json:"deleted_at"`
+}
+
+// Failure is a document past its retention that could not be deleted; the
+// next run tries again
+type Failure struct {
+	DocumentID     uuid.UUID `json:"document_id"`
+	Type           string    `json:"type"`
+	RetentionUntil time.Time `json:"retention_until"`
+	Error          string    `json:"error"`
+}
+
+// Content is the signed content of a deletion protocol
+type Content struct {
+	Version       int       `json:"version"`
+	ProtocolID    uuid.UUID `json:"protocol_id"`
+	TenantID      uuid.UUID `json:"tenant_id"`
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at"`
+	DeletionBasis string    `json:"deletion_basis"`
+	DeletedCount  int       `json:"deleted_count"`
+	FailedCount   int       `json:"failed_count"`
+	Entries       []Entry   `json:"entries"`
+	Failures      []Failure `json:"failures"`
+}
+
+// Protocol is the stored deletion protocol of a run. Document is the signed
+// JSON document; SHA256 is its hash and Signature its HMAC-SHA256 under the
+// protocol key with the fingerprint KeyID.
+type Protocol struct {
+	ID           uuid.UUID `json:"id"`
+	TenantID     uuid.UUID `json:"tenant_id"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+	DeletedCount int       `json:"deleted_count"`
+	FailedCount  int       `json:"failed_count"`
+	SHA256       string    `json:"sha256"`
+	Signature    string    `json:"signature"`
+	KeyID        string    `json:"key_id"`
+	Document     []byte    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Verification is the result of checking a stored protocol
+type Verification struct {
+	Valid       bool   `json:"valid"`
+	HashValid   bool   `json:"hash_valid"`
+	SignatureOK bool   `json:"signature_valid"`
+	KeyMatches  bool   `json:"key_matches"` // Signed with the current protocol key
+	SHA256      string `json:"sha256"`
+}
@@ -0,0 +1,402 @@
+package salesdoc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/invoice"
+	"austrian-business-infrastructure/pkg/money"
+	"github.com/google/uuid"
+)
+
+// Handler handles sales document HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new sales document handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers sales document routes
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	// Admin-only: create, delete, status updates and conversions into
+	// follow-up documents and invoices
+	router.Handle("POST /api/v1/sales-documents", requireAuth(requireAdmin(http.HandlerFunc(h.Create))))
+	router.Handle("DELETE /api/v1/sales-documents/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Delete))))
+	router.Handle("POST /api/v1/sales-documents/{id}/status", requireAuth(requireAdmin(http.HandlerFunc(h.UpdateStatus))))
+	router.Handle("POST /api/v1/sales-documents/{id}/convert", requireAuth(requireAdmin(http.HandlerFunc(h.Convert))))
+	router.Handle("POST /api/v1/sales-documents/{id}/invoice", requireAuth(requireAdmin(http.HandlerFunc(h.ConvertToInvoice))))
+
+	// Member access: read
+	router.Handle("GET /api/v1/sales-documents", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("GET /api/v1/sales-documents/{id}", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("GET /api/v1/sales-documents/{id}/chain", requireAuth(http.HandlerFunc(h.Chain)))
+}
+
+// Create handles POST /api/v1/sales-documents
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := h.getIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	var input CreateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if input.SellerName == "" {
+		api.BadRequest(w, "seller_name is required")
+		return
+	}
+	if input.BuyerName == "" {
+		api.BadRequest(w, "buyer_name is required")
+		return
+	}
+
+	doc, err := h.service.Create(r.Context(), tenantID, userID, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, h.toResponse(doc, nil))
+}
+
+// List handles GET /api/v1/sales-documents
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	filter := ListFilter{
+		TenantID: tenantID,
+		Limit:    50,
+		Offset:   0,
+	}
+
+	q := r.URL.Query()
+	if docType := q.Get("type"); docType != "" {
+		filter.DocumentType = &docType
+	}
+	if status := q.Get("status"); status != "" {
+		filter.Status = &status
+	}
+	if buyerIDStr := q.Get("buyer_id"); buyerIDStr != "" {
+		if buyerID, err := uuid.Parse(buyerIDStr); err == nil {
+			filter.BuyerID = &buyerID
+		}
+	}
+	if invoiceIDStr := q.Get("invoice_id"); invoiceIDStr != "" {
+		if invoiceID, err := uuid.Parse(invoiceIDStr); err == nil {
+			filter.InvoiceID = &invoiceID
+		}
+	}
+	if search := q.Get("search"); search != "" {
+		filter.Search = &search
+	}
+	if limitStr := q.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := q.Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	docs, total, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	items := make([]*DocumentResponse, 0, len(docs))
+	for _, doc := range docs {
+		items = append(items, h.toResponse(doc, nil))
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  items,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// Get handles GET /api/v1/sales-documents/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid document ID")
+		return
+	}
+
+	doc, items, err := h.service.GetWithItems(r.Context(), id, tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, h.toResponse(doc, items))
+}
+
+// Delete handles DELETE /api/v1/sales-documents/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid document ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, tenantID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// StatusRequest represents a status change request
+type StatusRequest struct {
+	Status string `json:"status"`
+}
+
+// UpdateStatus handles POST /api/v1/sales-documents/{id}/status
+func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid document ID")
+		return
+	}
+
+	var req StatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	doc, err := h.service.UpdateStatus(r.Context(), id, tenantID, req.Status)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, h.toResponse(doc, nil))
+}
+
+// Convert handles POST /api/v1/sales-documents/{id}/convert
+func (h *Handler) Convert(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := h.getIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid document ID")
+		return
+	}
+
+	var input ConvertInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	doc, err := h.service.Convert(r.Context(), id, tenantID, userID, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, h.toResponse(doc, nil))
+}
+
+// ConvertToInvoice handles POST /api/v1/sales-documents/{id}/invoice
+func (h *Handler) ConvertToInvoice(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := h.getIdentity(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid document ID")
+		return
+	}
+
+	var input ConvertToInvoiceInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+	if input.InvoiceNumber == "" {
+		api.BadRequest(w, "invoice_number is required")
+		return
+	}
+
+	inv, err := h.service.ConvertToInvoice(r.Context(), id, tenantID, userID, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, map[string]interface{}{
+		"invoice_id":     inv.ID,
+		"invoice_number": inv.InvoiceNumber,
+		"status":         inv.Status,
+	})
+}
+
+// Chain handles GET /api/v1/sales-documents/{id}/chain
+func (h *Handler) Chain(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid document ID")
+		return
+	}
+
+	chain, err := h.service.Chain(r.Context(), id, tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items": chain,
+	})
+}
+
+// Helper methods
+
+func (h *Handler) getTenantID(r *http.Request) (uuid.UUID, error) {
+	tenantIDStr := api.GetTenantID(r.Context())
+	if tenantIDStr == "" {
+		return uuid.Nil, ErrDocumentNotFound
+	}
+	return uuid.Parse(tenantIDStr)
+}
+
+func (h *Handler) getIdentity(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(api.GetUserID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "user not found in context")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return tenantID, userID, true
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrDocumentNotFound):
+		api.NotFound(w, "sales document not found")
+	case errors.Is(err, ErrInvalidType):
+		api.BadRequest(w, "document_type must be 'offer', 'order_confirmation' or 'delivery_note'")
+	case errors.Is(err, ErrNoItems):
+		api.BadRequest(w, "document must have at least one item")
+	case errors.Is(err, ErrInvalidStatus):
+		api.BadRequest(w, "invalid status transition")
+	case errors.Is(err, ErrConversionNotAllowed):
+		api.BadRequest(w, "conversion not allowed for this document type")
+	case errors.Is(err, ErrNotConvertible):
+		api.Conflict(w, "document cannot be converted in its current status")
+	case errors.Is(err, ErrAlreadyInvoiced):
+		api.Conflict(w, "document has already been invoiced")
+	case errors.Is(err, invoice.ErrDuplicateNumber):
+		api.Conflict(w, "invoice number already exists")
+	default:
+		api.InternalError(w)
+	}
+}
+
+func (h *Handler) toResponse(doc *Document, items []*Item) *DocumentResponse {
+	resp := &DocumentResponse{
+		ID:                 doc.ID,
+		DocumentType:       doc.DocumentType,
+		DocumentNumber:     doc.DocumentNumber,
+		IssueDate:          doc.IssueDate.Format("2006-01-02"),
+		Currency:           doc.Currency,
+		SellerName:         doc.SellerName,
+		BuyerName:          doc.BuyerName,
+		BuyerReference:     doc.BuyerReference,
+		Subject:            doc.Subject,
+		TaxExclusiveAmount: money.New(doc.TaxExclusiveAmount, doc.Currency),
+		TaxAmount:          money.New(doc.TaxAmount, doc.Currency),
+		TaxInclusiveAmount: money.New(doc.TaxInclusiveAmount, doc.Currency),
+		Status:             doc.Status,
+		SourceDocumentID:   doc.SourceDocumentID,
+		InvoiceID:          doc.InvoiceID,
+		CreatedAt:          doc.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:          doc.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+
+	if doc.ValidUntil != nil {
+		d := doc.ValidUntil.Format("2006-01-02")
+		resp.ValidUntil = &d
+	}
+	if doc.DeliveryDate != nil {
+		d := doc.DeliveryDate.Format("2006-01-02")
+		resp.DeliveryDate = &d
+	}
+
+	if items != nil {
+		resp.Items = make([]ItemResponse, 0, len(items))
+		for _, item := range items {
+			resp.Items = append(resp.Items, ItemResponse{
+				ID:          item.ID,
+				LineNumber:  item.LineNumber,
+				Description: item.Description,
+				Quantity:    item.Quantity,
+				UnitCode:    item.UnitCode,
+				UnitPrice:   money.New(item.UnitPrice, doc.Currency),
+				LineTotal:   money.New(item.LineTotal, doc.Currency),
+				TaxCategory: item.TaxCategory,
+				TaxPercent:  item.TaxPercent,
+			})
+		}
+	}
+
+	return resp
+}
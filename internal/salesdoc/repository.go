@@ -0,0 +1,406 @@
+package salesdoc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrDocumentNotFound = errors.New("sales document not found")
+)
+
+// Repository handles sales document database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new sales document repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Create creates a new sales document with items, assigning the next number
+// from the tenant's number range for the document type and year.
+func (r *Repository) Create(ctx context.Context, doc *Document, items []*Item) (*Document, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	number, err := r.nextNumber(ctx, tx, doc.TenantID, doc.DocumentType, doc.IssueDate.Year())
+	if err != nil {
+		return nil, err
+	}
+
+	doc.ID = uuid.New()
+	doc.DocumentNumber = number
+	doc.CreatedAt = time.Now()
+	doc.UpdatedAt = doc.CreatedAt
+	if doc.Status == "" {
+		doc.Status = StatusDraft
+	}
+
+	query := `
+		INSERT INTO sales_documents (
+			id, tenant_id, document_type, document_number, issue_date, valid_until,
+			delivery_date, currency, seller_name, seller_vat, seller_address,
+			buyer_id, buyer_name, buyer_vat, buyer_address, buyer_reference,
+			subject, notes, tax_exclusive_amount, tax_amount, tax_inclusive_amount,
+			status, source_document_id, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26)`
+
+	_, err = tx.Exec(ctx, query,
+		doc.ID, doc.TenantID, doc.DocumentType, doc.DocumentNumber, doc.IssueDate, doc.ValidUntil,
+		doc.DeliveryDate, doc.Currency, doc.SellerName, doc.SellerVAT, doc.SellerAddress,
+		doc.BuyerID, doc.BuyerName, doc.BuyerVAT, doc.BuyerAddress, doc.BuyerReference,
+		doc.Subject, doc.Notes, doc.TaxExclusiveAmount, doc.TaxAmount, doc.TaxInclusiveAmount,
+		doc.Status, doc.SourceDocumentID, doc.CreatedBy, doc.CreatedAt, doc.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sales document: %w", err)
+	}
+
+	for i, item := range items {
+		item.ID = uuid.New()
+		item.DocumentID = doc.ID
+		item.LineNumber = i + 1
+		item.CreatedAt = time.Now()
+
+		itemQuery := `
+			INSERT INTO sales_document_items (
+				id, document_id, line_number, description, quantity, unit_code,
+				unit_price, line_total, tax_category, tax_percent, item_id, created_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+		_, err = tx.Exec(ctx, itemQuery,
+			item.ID, item.DocumentID, item.LineNumber, item.Description, item.Quantity, item.UnitCode,
+			item.UnitPrice, item.LineTotal, item.TaxCategory, item.TaxPercent, item.ItemID, item.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sales document item: %w", err)
+		}
+	}
+
+	// Mark the source document as converted in the same transaction. Only
+	// one of concurrent conversions finds it still convertible.
+	if doc.SourceDocumentID != nil {
+		result, err := tx.Exec(ctx, `
+			UPDATE sales_documents SET status = $1, updated_at = $2
+			WHERE id = $3 AND tenant_id = $4 AND status = ANY($5)`,
+			StatusConverted, doc.CreatedAt, *doc.SourceDocumentID, doc.TenantID, convertibleStatuses,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update source document: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return nil, ErrNotConvertible
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return doc, nil
+}
+
+// nextNumber reserves the next number in the tenant's range for a type and year
+func (r *Repository) nextNumber(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID, docType string, year int) (string, error) {
+	query := `
+		INSERT INTO sales_document_sequences (tenant_id, document_type, year, last_value)
+		VALUES ($1, $2, $3, 1)
+		ON CONFLICT (tenant_id, document_type, year)
+		DO UPDATE SET last_value = sales_document_sequences.last_value + 1
+		RETURNING last_value`
+
+	var value int
+	if err := tx.QueryRow(ctx, query, tenantID, docType, year).Scan(&value); err != nil {
+		return "", fmt.Errorf("failed to reserve document number: %w", err)
+	}
+
+	return fmt.Sprintf("%s-%d-%05d", numberPrefixes[docType], year, value), nil
+}
+
+const documentColumns = `
+	id, tenant_id, document_type, document_number, issue_date, valid_until,
+	delivery_date, currency, seller_name, seller_vat, seller_address,
+	buyer_id, buyer_name, buyer_vat, buyer_address, buyer_reference,
+	subject, notes, tax_exclusive_amount, tax_amount, tax_inclusive_amount,
+	status, source_document_id, invoice_id, created_by, created_at, updated_at`
+
+func scanDocument(row pgx.Row) (*Document, error) {
+	var doc Document
+	var validUntil, deliveryDate sql.NullTime
+	var buyerID, sourceID, invoiceID, createdBy uuid.NullUUID
+	var sellerVAT, buyerVAT, buyerRef, subject, notes sql.NullString
+
+	err := row.Scan(
+		&doc.ID, &doc.TenantID, &doc.DocumentType, &doc.DocumentNumber, &doc.IssueDate, &validUntil,
+		&deliveryDate, &doc.Currency, &doc.SellerName, &sellerVAT, &doc.SellerAddress,
+		&buyerID, &doc.BuyerName, &buyerVAT, &doc.BuyerAddress, &buyerRef,
+		&subject, &notes, &doc.TaxExclusiveAmount, &doc.TaxAmount, &doc.TaxInclusiveAmount,
+		&doc.Status, &sourceID, &invoiceID, &createdBy, &doc.CreatedAt, &doc.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if validUntil.Valid {
+		doc.ValidUntil = &validUntil.Time
+	}
+	if deliveryDate.Valid {
+		doc.DeliveryDate = &deliveryDate.Time
+	}
+	if buyerID.Valid {
+		doc.BuyerID = &buyerID.UUID
+	}
+	if sourceID.Valid {
+		doc.SourceDocumentID = &sourceID.UUID
+	}
+	if invoiceID.Valid {
+		doc.InvoiceID = &invoiceID.UUID
+	}
+	if createdBy.Valid {
+		doc.CreatedBy = &createdBy.UUID
+	}
+	if sellerVAT.Valid {
+		doc.SellerVAT = &sellerVAT.String
+	}
+	if buyerVAT.Valid {
+		doc.BuyerVAT = &buyerVAT.String
+	}
+	if buyerRef.Valid {
+		doc.BuyerReference = &buyerRef.String
+	}
+	if subject.Valid {
+		doc.Subject = &subject.String
+	}
+	if notes.Valid {
+		doc.Notes = &notes.String
+	}
+
+	return &doc, nil
+}
+
+// GetByID retrieves a sales document by ID
+func (r *Repository) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*Document, error) {
+	query := `SELECT ` + documentColumns + ` FROM sales_documents WHERE id = $1 AND tenant_id = $2`
+
+	doc, err := scanDocument(r.db.QueryRow(ctx, query, id, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, fmt.Errorf("failed to get sales document: %w", err)
+	}
+	return doc, nil
+}
+
+// GetItems retrieves all items for a sales document
+func (r *Repository) GetItems(ctx context.Context, documentID uuid.UUID) ([]*Item, error) {
+	query := `
+		SELECT id, document_id, line_number, description, quantity, unit_code,
+			unit_price, line_total, tax_category, tax_percent, item_id, created_at
+		FROM sales_document_items
+		WHERE document_id = $1
+		ORDER BY line_number`
+
+	rows, err := r.db.Query(ctx, query, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales document items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*Item
+	for rows.Next() {
+		var item Item
+		var itemID sql.NullString
+
+		err := rows.Scan(
+			&item.ID, &item.DocumentID, &item.LineNumber, &item.Description, &item.Quantity, &item.UnitCode,
+			&item.UnitPrice, &item.LineTotal, &item.TaxCategory, &item.TaxPercent, &itemID, &item.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sales document item: %w", err)
+		}
+		if itemID.Valid {
+			item.ItemID = &itemID.String
+		}
+		items = append(items, &item)
+	}
+
+	return items, nil
+}
+
+// List retrieves sales documents with filtering
+func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Document, int, error) {
+	baseQuery := ` FROM sales_documents WHERE tenant_id = $1`
+	args := []interface{}{filter.TenantID}
+	argIdx := 2
+
+	if filter.DocumentType != nil {
+		baseQuery += fmt.Sprintf(" AND document_type = $%d", argIdx)
+		args = append(args, *filter.DocumentType)
+		argIdx++
+	}
+
+	if filter.Status != nil {
+		baseQuery += fmt.Sprintf(" AND status = $%d", argIdx)
+		args = append(args, *filter.Status)
+		argIdx++
+	}
+
+	if filter.BuyerID != nil {
+		baseQuery += fmt.Sprintf(" AND buyer_id = $%d", argIdx)
+		args = append(args, *filter.BuyerID)
+		argIdx++
+	}
+
+	if filter.InvoiceID != nil {
+		baseQuery += fmt.Sprintf(" AND invoice_id = $%d", argIdx)
+		args = append(args, *filter.InvoiceID)
+		argIdx++
+	}
+
+	if filter.Search != nil {
+		baseQuery += fmt.Sprintf(" AND (document_number ILIKE $%d OR buyer_name ILIKE $%d)", argIdx, argIdx)
+		args = append(args, "%"+*filter.Search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*)"+baseQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count sales documents: %w", err)
+	}
+
+	selectQuery := `SELECT ` + documentColumns + baseQuery + `
+		ORDER BY issue_date DESC, created_at DESC
+		LIMIT $` + fmt.Sprintf("%d", argIdx) + ` OFFSET $` + fmt.Sprintf("%d", argIdx+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.Query(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list sales documents: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []*Document
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan sales document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, total, nil
+}
+
+// ListBySource retrieves all documents created from the given source document
+func (r *Repository) ListBySource(ctx context.Context, sourceID, tenantID uuid.UUID) ([]*Document, error) {
+	query := `SELECT ` + documentColumns + ` FROM sales_documents
+		WHERE source_document_id = $1 AND tenant_id = $2
+		ORDER BY created_at`
+
+	rows, err := r.db.Query(ctx, query, sourceID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list derived documents: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []*Document
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sales document: %w", err)
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+// UpdateStatus updates the status of a sales document
+func (r *Repository) UpdateStatus(ctx context.Context, id, tenantID uuid.UUID, status string) error {
+	query := `UPDATE sales_documents SET status = $1, updated_at = $2 WHERE id = $3 AND tenant_id = $4`
+
+	result, err := r.db.Exec(ctx, query, status, time.Now(), id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to update sales document status: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrDocumentNotFound
+	}
+	return nil
+}
+
+// ConvertToInvoice locks a document, calls create with it and its items
+// and records the returned invoice on the document. The lock is held until
+// the invoice is linked, so a concurrent conversion waits and then finds
+// the document invoiced.
+func (r *Repository) ConvertToInvoice(ctx context.Context, id, tenantID uuid.UUID, create func(doc *Document, items []*Item) (uuid.UUID, error)) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `SELECT ` + documentColumns + ` FROM sales_documents WHERE id = $1 AND tenant_id = $2 FOR UPDATE`
+	doc, err := scanDocument(tx.QueryRow(ctx, query, id, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrDocumentNotFound
+		}
+		return fmt.Errorf("failed to lock sales document: %w", err)
+	}
+	if doc.InvoiceID != nil {
+		return ErrAlreadyInvoiced
+	}
+
+	items, err := r.GetItems(ctx, doc.ID)
+	if err != nil {
+		return err
+	}
+	invoiceID, err := create(doc, items)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE sales_documents SET invoice_id = $1, status = $2, updated_at = $3
+		WHERE id = $4 AND tenant_id = $5 AND invoice_id IS NULL`,
+		invoiceID, StatusInvoiced, time.Now(), id, tenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to link invoice: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrAlreadyInvoiced
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a sales document (only drafts)
+func (r *Repository) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
+	query := `DELETE FROM sales_documents WHERE id = $1 AND tenant_id = $2 AND status = 'draft'`
+	result, err := r.db.Exec(ctx, query, id, tenantID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrDocumentNotFound
+	}
+	return nil
+}
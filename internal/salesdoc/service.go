@@ -0,0 +1,422 @@
+package salesdoc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"austrian-business-infrastructure/internal/invoice"
+	"austrian-business-infrastructure/pkg/money"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidType          = errors.New("invalid document type")
+	ErrInvalidStatus        = errors.New("invalid status transition")
+	ErrNoItems              = errors.New("document must have at least one item")
+	ErrConversionNotAllowed = errors.New("conversion not allowed for this document type")
+	ErrAlreadyInvoiced      = errors.New("document has already been invoiced")
+	ErrNotConvertible       = errors.New("document cannot be converted in its current status")
+)
+
+// convertibleStatuses are the statuses a document can be converted into a
+// follow-up document or an invoice in. A converted document is continued
+// by its follow-up.
+var convertibleStatuses = []string{StatusDraft, StatusSent, StatusAccepted}
+
+// statusTransitions defines the allowed manual status changes.
+// converted and invoiced are only set by the conversion flows.
+var statusTransitions = map[string][]string{
+	StatusDraft:    {StatusSent, StatusCancelled},
+	StatusSent:     {StatusAccepted, StatusRejected, StatusCancelled},
+	StatusAccepted: {StatusCancelled},
+	StatusRejected: {},
+}
+
+// Service handles sales document business logic
+type Service struct {
+	repo       *Repository
+	invoiceSvc *invoice.Service
+}
+
+// NewService creates a new sales document service
+func NewService(repo *Repository, invoiceSvc *invoice.Service) *Service {
+	return &Service{repo: repo, invoiceSvc: invoiceSvc}
+}
+
+// Create creates a new offer, order confirmation or delivery note
+func (s *Service) Create(ctx context.Context, tenantID, userID uuid.UUID, input *CreateInput) (*Document, error) {
+	if !IsValidType(input.DocumentType) {
+		return nil, ErrInvalidType
+	}
+	if len(input.Items) == 0 {
+		return nil, ErrNoItems
+	}
+
+	issueDate, err := time.Parse("2006-01-02", input.IssueDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issue_date format: %w", err)
+	}
+	validUntil, err := parseOptionalDate(input.ValidUntil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid valid_until format: %w", err)
+	}
+	deliveryDate, err := parseOptionalDate(input.DeliveryDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid delivery_date format: %w", err)
+	}
+
+	var sellerAddr, buyerAddr json.RawMessage
+	if input.SellerAddress != nil {
+		sellerAddr, _ = json.Marshal(input.SellerAddress)
+	}
+	if input.BuyerAddress != nil {
+		buyerAddr, _ = json.Marshal(input.BuyerAddress)
+	}
+
+	doc := &Document{
+		TenantID:       tenantID,
+		DocumentType:   input.DocumentType,
+		IssueDate:      issueDate,
+		ValidUntil:     validUntil,
+		DeliveryDate:   deliveryDate,
+		Currency:       input.Currency,
+		SellerName:     input.SellerName,
+		SellerVAT:      input.SellerVAT,
+		SellerAddress:  sellerAddr,
+		BuyerID:        input.BuyerID,
+		BuyerName:      input.BuyerName,
+		BuyerVAT:       input.BuyerVAT,
+		BuyerAddress:   buyerAddr,
+		BuyerReference: input.BuyerReference,
+		Subject:        input.Subject,
+		Notes:          input.Notes,
+		CreatedBy:      &userID,
+	}
+	if doc.Currency == "" {
+		doc.Currency = "EUR"
+	}
+
+	items := make([]*Item, 0, len(input.Items))
+	for _, in := range input.Items {
+		items = append(items, &Item{
+			Description: in.Description,
+			Quantity:    in.Quantity,
+			UnitCode:    in.UnitCode,
+			UnitPrice:   in.UnitPrice,
+			TaxCategory: in.TaxCategory,
+			TaxPercent:  in.TaxPercent,
+			ItemID:      in.ItemID,
+		})
+	}
+	calculateTotals(doc, items)
+
+	return s.repo.Create(ctx, doc, items)
+}
+
+// Get retrieves a sales document by ID
+func (s *Service) Get(ctx context.Context, id, tenantID uuid.UUID) (*Document, error) {
+	return s.repo.GetByID(ctx, id, tenantID)
+}
+
+// GetWithItems retrieves a sales document with its items
+func (s *Service) GetWithItems(ctx context.Context, id, tenantID uuid.UUID) (*Document, []*Item, error) {
+	doc, err := s.repo.GetByID(ctx, id, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, err := s.repo.GetItems(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return doc, items, nil
+}
+
+// List lists sales documents with filtering
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]*Document, int, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// UpdateStatus changes the status of a document (sent, accepted, rejected, cancelled)
+func (s *Service) UpdateStatus(ctx context.Context, id, tenantID uuid.UUID, status string) (*Document, error) {
+	doc, err := s.repo.GetByID(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := false
+	for _, next := range statusTransitions[doc.Status] {
+		if next == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, ErrInvalidStatus
+	}
+
+	if err := s.repo.UpdateStatus(ctx, id, tenantID, status); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, id, tenantID)
+}
+
+// Delete deletes a sales document (only drafts)
+func (s *Service) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
+	return s.repo.Delete(ctx, id, tenantID)
+}
+
+// Convert creates a follow-up document (e.g. offer → order confirmation)
+// carrying over parties and line items. The source is marked as converted.
+func (s *Service) Convert(ctx context.Context, id, tenantID, userID uuid.UUID, input *ConvertInput) (*Document, error) {
+	src, items, err := s.GetWithItems(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !CanConvert(src.DocumentType, input.TargetType) {
+		return nil, ErrConversionNotAllowed
+	}
+	if !isConvertible(src.Status) {
+		return nil, ErrNotConvertible
+	}
+
+	issueDate := time.Now().UTC().Truncate(24 * time.Hour)
+	if input.IssueDate != nil && *input.IssueDate != "" {
+		issueDate, err = time.Parse("2006-01-02", *input.IssueDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid issue_date format: %w", err)
+		}
+	}
+	deliveryDate, err := parseOptionalDate(input.DeliveryDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid delivery_date format: %w", err)
+	}
+
+	doc := &Document{
+		TenantID:         tenantID,
+		DocumentType:     input.TargetType,
+		IssueDate:        issueDate,
+		DeliveryDate:     deliveryDate,
+		Currency:         src.Currency,
+		SellerName:       src.SellerName,
+		SellerVAT:        src.SellerVAT,
+		SellerAddress:    src.SellerAddress,
+		BuyerID:          src.BuyerID,
+		BuyerName:        src.BuyerName,
+		BuyerVAT:         src.BuyerVAT,
+		BuyerAddress:     src.BuyerAddress,
+		BuyerReference:   src.BuyerReference,
+		Subject:          src.Subject,
+		Notes:            src.Notes,
+		SourceDocumentID: &src.ID,
+		CreatedBy:        &userID,
+	}
+
+	copied := make([]*Item, 0, len(items))
+	for _, item := range items {
+		copied = append(copied, &Item{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitCode:    item.UnitCode,
+			UnitPrice:   item.UnitPrice,
+			TaxCategory: item.TaxCategory,
+			TaxPercent:  item.TaxPercent,
+			ItemID:      item.ItemID,
+		})
+	}
+	calculateTotals(doc, copied)
+
+	return s.repo.Create(ctx, doc, copied)
+}
+
+// ConvertToInvoice creates a draft invoice from a sales document, carrying over
+// parties and line items, and links the invoice back to the document. A
+// document is invoiced once, also when converted concurrently.
+func (s *Service) ConvertToInvoice(ctx context.Context, id, tenantID, userID uuid.UUID, input *ConvertToInvoiceInput) (*invoice.Invoice, error) {
+	var inv *invoice.Invoice
+	err := s.repo.ConvertToInvoice(ctx, id, tenantID, func(doc *Document, items []*Item) (uuid.UUID, error) {
+		if !isConvertible(doc.Status) {
+			return uuid.Nil, ErrNotConvertible
+		}
+		var err error
+		inv, err = s.invoiceSvc.Create(ctx, tenantID, userID, invoiceInput(doc, items, input))
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return inv.ID, nil
+	})
+	if err != nil {
+		if inv != nil {
+			// The draft was created but not linked; don't leave it behind
+			if delErr := s.invoiceSvc.Delete(ctx, inv.ID, tenantID); delErr != nil {
+				return nil, errors.Join(err, delErr)
+			}
+		}
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// invoiceInput builds the input of the invoice a sales document is
+// converted into
+func invoiceInput(doc *Document, items []*Item, input *ConvertToInvoiceInput) *invoice.CreateInvoiceInput {
+	issueDate := time.Now().UTC().Format("2006-01-02")
+	if input.IssueDate != nil && *input.IssueDate != "" {
+		issueDate = *input.IssueDate
+	}
+
+	orderRef := doc.DocumentNumber
+	invInput := &invoice.CreateInvoiceInput{
+		InvoiceNumber:  input.InvoiceNumber,
+		IssueDate:      issueDate,
+		DueDate:        input.DueDate,
+		Currency:       doc.Currency,
+		SellerName:     doc.SellerName,
+		SellerVAT:      doc.SellerVAT,
+		SellerAddress:  toInvoiceAddress(doc.SellerAddress),
+		BuyerID:        doc.BuyerID,
+		BuyerName:      doc.BuyerName,
+		BuyerVAT:       doc.BuyerVAT,
+		BuyerAddress:   toInvoiceAddress(doc.BuyerAddress),
+		BuyerReference: doc.BuyerReference,
+		OrderReference: &orderRef,
+		PaymentTerms:   input.PaymentTerms,
+		PaymentIBAN:    input.PaymentIBAN,
+		PaymentBIC:     input.PaymentBIC,
+		Notes:          doc.Notes,
+		Items:          make([]invoice.ItemInput, 0, len(items)),
+	}
+
+	for _, item := range items {
+		invInput.Items = append(invInput.Items, invoice.ItemInput{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitCode:    item.UnitCode,
+			UnitPrice:   item.UnitPrice,
+			TaxCategory: item.TaxCategory,
+			TaxPercent:  item.TaxPercent,
+			ItemID:      item.ItemID,
+		})
+	}
+
+	return invInput
+}
+
+// Chain returns the full lifecycle of a document: all ancestors back to the
+// original offer, all derived documents and the resulting invoices.
+func (s *Service) Chain(ctx context.Context, id, tenantID uuid.UUID) ([]ChainEntry, error) {
+	doc, err := s.repo.GetByID(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk up to the root document
+	root := doc
+	for depth := 0; root.SourceDocumentID != nil && depth < 10; depth++ {
+		parent, err := s.repo.GetByID(ctx, *root.SourceDocumentID, tenantID)
+		if err != nil {
+			if errors.Is(err, ErrDocumentNotFound) {
+				break
+			}
+			return nil, err
+		}
+		root = parent
+	}
+
+	// Walk down breadth-first from the root
+	var chain []ChainEntry
+	queue := []*Document{root}
+	for len(queue) > 0 && len(chain) < 100 {
+		current := queue[0]
+		queue = queue[1:]
+
+		chain = append(chain, ChainEntry{
+			ID:        current.ID,
+			Kind:      current.DocumentType,
+			Number:    current.DocumentNumber,
+			Status:    current.Status,
+			IssueDate: current.IssueDate.Format("2006-01-02"),
+		})
+
+		if current.InvoiceID != nil {
+			inv, err := s.invoiceSvc.Get(ctx, *current.InvoiceID, tenantID)
+			if err == nil {
+				chain = append(chain, ChainEntry{
+					ID:        inv.ID,
+					Kind:      "invoice",
+					Number:    inv.InvoiceNumber,
+					Status:    inv.Status,
+					IssueDate: inv.IssueDate.Format("2006-01-02"),
+				})
+			} else if !errors.Is(err, invoice.ErrInvoiceNotFound) {
+				return nil, err
+			}
+		}
+
+		children, err := s.repo.ListBySource(ctx, current.ID, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, children...)
+	}
+
+	return chain, nil
+}
+
+// Helper functions
+
+// calculateTotals computes line totals and document totals the same way invoices do
+func calculateTotals(doc *Document, items []*Item) {
+	var taxExclusive, taxAmount int64
+	for _, item := range items {
+		lineTotal := money.New(item.UnitPrice, doc.Currency).Mul(item.Quantity)
+		item.LineTotal = lineTotal.Cents
+		taxExclusive += lineTotal.Cents
+		taxAmount += lineTotal.Percent(item.TaxPercent).Cents
+	}
+	doc.TaxExclusiveAmount = taxExclusive
+	doc.TaxAmount = taxAmount
+	doc.TaxInclusiveAmount = taxExclusive + taxAmount
+}
+
+func isConvertible(status string) bool {
+	return slices.Contains(convertibleStatuses, status)
+}
+
+func parseOptionalDate(value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", *value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func toInvoiceAddress(raw json.RawMessage) *invoice.Address {
+	if len(raw) == 0 {
+		return nil
+	}
+	var addr Address
+	if json.Unmarshal(raw, &addr) != nil {
+		return nil
+	}
+	return &invoice.Address{
+		Street:     addr.Street,
+		City:       addr.City,
+		PostalCode: addr.PostalCode,
+		Country:    addr.Country,
+	}
+}
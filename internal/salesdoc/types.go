@@ -0,0 +1,221 @@
+package salesdoc
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/pkg/money"
+)
+
+// Document type constants
+const (
+	TypeOffer             = "offer"              // Angebot
+	TypeOrderConfirmation = "order_confirmation" // Auftragsbestätigung
+	TypeDeliveryNote      = "delivery_note"      // Lieferschein
+)
+
+// Status constants for sales documents
+const (
+	StatusDraft     = "draft"
+	StatusSent      = "sent"
+	StatusAccepted  = "accepted"
+	StatusRejected  = "rejected"
+	StatusConverted = "converted"
+	StatusInvoiced  = "invoiced"
+	StatusCancelled = "cancelled"
+)
+
+// numberPrefixes holds the document number prefix per type.
+// Each type has its own number range, separate from invoice numbers.
+var numberPrefixes = map[string]string{
+	TypeOffer:             "AN",
+	TypeOrderConfirmation: "AB",
+	TypeDeliveryNote:      "LS",
+}
+
+// allowedConversions lists which target types a document type can be converted into.
+// Every type can additionally be converted into an invoice.
+var allowedConversions = map[string][]string{
+	TypeOffer:             {TypeOrderConfirmation, TypeDeliveryNote},
+	TypeOrderConfirmation: {TypeDeliveryNote},
+	TypeDeliveryNote:      {},
+}
+
+// IsValidType checks if a document type is known
+func IsValidType(docType string) bool {
+	_, ok := numberPrefixes[docType]
+	return ok
+}
+
+// CanConvert checks if a document of type from can be converted into type to
+func CanConvert(from, to string) bool {
+	for _, t := range allowedConversions[from] {
+		if t == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Document represents an offer, order confirmation or delivery note
+type Document struct {
+	ID                 uuid.UUID       `json:"id"`
+	TenantID           uuid.UUID       `json:"tenant_id"`
+	DocumentType       string          `json:"document_type"`
+	DocumentNumber     string          `json:"document_number"`
+	IssueDate          time.Time       `json:"issue_date"`
+	ValidUntil         *time.Time      `json:"valid_until,omitempty"`
+	DeliveryDate       *time.Time      `json:"delivery_date,omitempty"`
+	Currency           string          `json:"currency"`
+	SellerName         string          `json:"seller_name"`
+	SellerVAT          *string         `json:"seller_vat,omitempty"`
+	SellerAddress      json.RawMessage `json:"seller_address,omitempty"`
+	BuyerID            *uuid.UUID      `json:"buyer_id,omitempty"`
+	BuyerName          string          `json:"buyer_name"`
+	BuyerVAT           *string         `json:"buyer_vat,omitempty"`
+	BuyerAddress       json.RawMessage `json:"buyer_address,omitempty"`
+	BuyerReference     *string         `json:"buyer_reference,omitempty"`
+	Subject            *string         `json:"subject,omitempty"`
+	Notes              *string         `json:"notes,omitempty"`
+	TaxExclusiveAmount int64           `json:"tax_exclusive_amount"`
+	TaxAmount          int64           `json:"tax_amount"`
+	TaxInclusiveAmount int64           `json:"tax_inclusive_amount"`
+	Status             string          `json:"status"`
+	SourceDocumentID   *uuid.UUID      `json:"source_document_id,omitempty"`
+	InvoiceID          *uuid.UUID      `json:"invoice_id,omitempty"`
+	CreatedBy          *uuid.UUID      `json:"created_by,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+// Item represents a line item of a sales document
+type Item struct {
+	ID          uuid.UUID `json:"id"`
+	DocumentID  uuid.UUID `json:"document_id"`
+	LineNumber  int       `json:"line_number"`
+	Description string    `json:"description"`
+	Quantity    float64   `json:"quantity"`
+	UnitCode    string    `json:"unit_code"`
+	UnitPrice   int64     `json:"unit_price"`
+	LineTotal   int64     `json:"line_total"`
+	TaxCategory string    `json:"tax_category"`
+	TaxPercent  float64   `json:"tax_percent"`
+	ItemID      *string   `json:"item_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Address represents a postal address
+type Address struct {
+	Street     string `json:"street,omitempty"`
+	City       string `json:"city,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country"`
+}
+
+// CreateInput represents input for creating a sales document
+type CreateInput struct {
+	DocumentType   string      `json:"document_type"`
+	IssueDate      string      `json:"issue_date"`
+	ValidUntil     *string     `json:"valid_until,omitempty"`
+	DeliveryDate   *string     `json:"delivery_date,omitempty"`
+	Currency       string      `json:"currency"`
+	SellerName     string      `json:"seller_name"`
+	SellerVAT      *string     `json:"seller_vat,omitempty"`
+	SellerAddress  *Address    `json:"seller_address,omitempty"`
+	BuyerID        *uuid.UUID  `json:"buyer_id,omitempty"`
+	BuyerName      string      `json:"buyer_name"`
+	BuyerVAT       *string     `json:"buyer_vat,omitempty"`
+	BuyerAddress   *Address    `json:"buyer_address,omitempty"`
+	BuyerReference *string     `json:"buyer_reference,omitempty"`
+	Subject        *string     `json:"subject,omitempty"`
+	Notes          *string     `json:"notes,omitempty"`
+	Items          []ItemInput `json:"items"`
+}
+
+// ItemInput represents input for a sales document line item
+type ItemInput struct {
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	UnitCode    string  `json:"unit_code"`
+	UnitPrice   int64   `json:"unit_price"`
+	TaxCategory string  `json:"tax_category"`
+	TaxPercent  float64 `json:"tax_percent"`
+	ItemID      *string `json:"item_id,omitempty"`
+}
+
+// ConvertInput represents input for converting a document into another sales document
+type ConvertInput struct {
+	TargetType   string  `json:"target_type"`
+	IssueDate    *string `json:"issue_date,omitempty"`
+	DeliveryDate *string `json:"delivery_date,omitempty"`
+}
+
+// ConvertToInvoiceInput represents input for converting a document into an invoice
+type ConvertToInvoiceInput struct {
+	InvoiceNumber string  `json:"invoice_number"`
+	IssueDate     *string `json:"issue_date,omitempty"`
+	DueDate       *string `json:"due_date,omitempty"`
+	PaymentTerms  *string `json:"payment_terms,omitempty"`
+	PaymentIBAN   *string `json:"payment_iban,omitempty"`
+	PaymentBIC    *string `json:"payment_bic,omitempty"`
+}
+
+// ListFilter represents filtering options for listing sales documents
+type ListFilter struct {
+	TenantID     uuid.UUID
+	DocumentType *string
+	Status       *string
+	BuyerID      *uuid.UUID
+	InvoiceID    *uuid.UUID
+	Search       *string
+	Limit        int
+	Offset       int
+}
+
+// DocumentResponse is the API response format
+type DocumentResponse struct {
+	ID                 uuid.UUID      `json:"id"`
+	DocumentType       string         `json:"document_type"`
+	DocumentNumber     string         `json:"document_number"`
+	IssueDate          string         `json:"issue_date"`
+	ValidUntil         *string        `json:"valid_until,omitempty"`
+	DeliveryDate       *string        `json:"delivery_date,omitempty"`
+	Currency           string         `json:"currency"`
+	SellerName         string         `json:"seller_name"`
+	BuyerName          string         `json:"buyer_name"`
+	BuyerReference     *string        `json:"buyer_reference,omitempty"`
+	Subject            *string        `json:"subject,omitempty"`
+	TaxExclusiveAmount money.Money    `json:"tax_exclusive_amount"`
+	TaxAmount          money.Money    `json:"tax_amount"`
+	TaxInclusiveAmount money.Money    `json:"tax_inclusive_amount"`
+	Status             string         `json:"status"`
+	SourceDocumentID   *uuid.UUID     `json:"source_document_id,omitempty"`
+	InvoiceID          *uuid.UUID     `json:"invoice_id,omitempty"`
+	Items              []ItemResponse `json:"items,omitempty"`
+	CreatedAt          string         `json:"created_at"`
+	UpdatedAt          string         `json:"updated_at"`
+}
+
+// ItemResponse is the API response format for line items
+type ItemResponse struct {
+	ID          uuid.UUID   `json:"id"`
+	LineNumber  int         `json:"line_number"`
+	Description string      `json:"description"`
+	Quantity    float64     `json:"quantity"`
+	UnitCode    string      `json:"unit_code"`
+	UnitPrice   money.Money `json:"unit_price"`
+	LineTotal   money.Money `json:"line_total"`
+	TaxCategory string      `json:"tax_category"`
+	TaxPercent  float64     `json:"tax_percent"`
+}
+
+// ChainEntry is one step in the offer → invoice lifecycle of a document
+type ChainEntry struct {
+	ID        uuid.UUID `json:"id"`
+	Kind      string    `json:"kind"` // sales document type or "invoice"
+	Number    string    `json:"number"`
+	Status    string    `json:"status"`
+	IssueDate string    `json:"issue_date"`
+}
@@ -3,6 +3,7 @@ package schedule
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"austrian-business-infrastructure/internal/account"
 	"austrian-business-infrastructure/internal/api"
@@ -73,10 +74,10 @@ func (h *Handler) GetSchedule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if acc.LastSyncAt != nil {
-		response.LastSyncAt = acc.LastSyncAt.Format("2006-01-02T15:04:05Z")
+		response.LastSyncAt = acc.LastSyncAt.UTC().Format(time.RFC3339)
 	}
 	if acc.NextSyncAt != nil {
-		response.NextSyncAt = acc.NextSyncAt.Format("2006-01-02T15:04:05Z")
+		response.NextSyncAt = acc.NextSyncAt.UTC().Format(time.RFC3339)
 	}
 
 	api.JSONResponse(w, http.StatusOK, response)
@@ -161,10 +162,10 @@ func (h *Handler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if acc.LastSyncAt != nil {
-		response.LastSyncAt = acc.LastSyncAt.Format("2006-01-02T15:04:05Z")
+		response.LastSyncAt = acc.LastSyncAt.UTC().Format(time.RFC3339)
 	}
 	if acc.NextSyncAt != nil {
-		response.NextSyncAt = acc.NextSyncAt.Format("2006-01-02T15:04:05Z")
+		response.NextSyncAt = acc.NextSyncAt.UTC().Format(time.RFC3339)
 	}
 
 	api.JSONResponse(w, http.StatusOK, response)
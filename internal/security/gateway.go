@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/pkg/money"
 )
 
 var (
@@ -43,13 +45,13 @@ type AnalysisRequest struct {
 
 // AnalysisResponse represents the AI analysis response
 type AnalysisResponse struct {
-	Summary      string   `json:"summary"`
-	DocumentType string   `json:"document_type"`
-	Deadline     string   `json:"deadline,omitempty"`
-	Amount       *float64 `json:"amount,omitempty"`
-	ActionItems  []string `json:"action_items,omitempty"`
-	Confidence   float64  `json:"confidence"`
-	ProcessedAt  time.Time `json:"processed_at"`
+	Summary      string       `json:"summary"`
+	DocumentType string       `json:"document_type"`
+	Deadline     string       `json:"deadline,omitempty"`
+	Amount       *money.Money `json:"amount,omitempty"`
+	ActionItems  []string     `json:"action_items,omitempty"`
+	Confidence   float64      `json:"confidence"`
+	ProcessedAt  time.Time    `json:"processed_at"`
 }
 
 // TextAnalysisRequest represents a text-only analysis request
@@ -0,0 +1,291 @@
+package sepa
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/pkg/money"
+)
+
+// ErrNoMT940Statement is returned for input without a :20: record
+var ErrNoMT940Statement = errors.New("no MT940 statement found")
+
+// mt940Tag matches the start of a record such as ":61:" or ":60F:"
+var mt940Tag = regexp.MustCompile(`^:(\d{2}[A-Z]?):`)
+
+// mt940Line61 matches the statement line: value date, optional entry date,
+// debit/credit mark, optional funds code, amount, transaction type,
+// customer reference and optional bank reference
+var mt940Line61 = regexp.MustCompile(`^(\d{6})(\d{4})?(R?[CD])([A-Z])?(\d+,\d{0,2})([NFS][A-Z0-9]{3})([^/]*)(?://(.*))?$`)
+
+// mt940Balance matches a balance: mark, date, currency and amount
+var mt940Balance = regexp.MustCompile(`^([CD])(\d{6})([A-Z]{3})(\d+,\d{0,2})`)
+
+// mt940IBAN matches an IBAN in the account identification
+var mt940IBAN = regexp.MustCompile(`^[A-Z]{2}\d{2}[A-Z0-9]{10,30}$`)
+
+type mt940Record struct {
+	tag   string
+	lines []string
+}
+
+// ParseMT940 parses an MT940 (SWIFT customer statement) file. Files with
+// several statements of an account, e.g. one per day, are combined into one:
+// the opening balance of the first, the closing balance of the last and all
+// entries in order. Structured :86: details (?20 remittance, ?31 IBAN, ?32
+// name) and the SEPA keywords EREF+ and SVWZ+ are read where present.
+func ParseMT940(data []byte) (*SEPAStatement, error) {
+	records := splitMT940(string(data))
+
+	var result *SEPAStatement
+	var last *SEPAStatementEntry
+	closingSeen := false
+	for _, rec := range records {
+		value := strings.Join(rec.lines, "\n")
+		switch rec.tag {
+		case "20":
+			if result == nil {
+				result = &SEPAStatement{ID: strings.TrimSpace(value)}
+			}
+			last = nil
+		case "25":
+			if result != nil && result.Account.IBAN == "" {
+				account := strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+				if i := strings.LastIndex(account, "/"); i >= 0 && mt940IBAN.MatchString(account[i+1:]) {
+					account = account[i+1:]
+				}
+				result.Account.IBAN = account
+			}
+		case "60F", "60M":
+			if result == nil {
+				return nil, ErrNoMT940Statement
+			}
+			amount, currency, date, err := parseMT940Balance(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse opening balance: %w", err)
+			}
+			if result.CreationTime.IsZero() {
+				result.OpeningBalance = amount
+				result.Account.Currency = currency
+				result.CreationTime = date
+			}
+		case "62F", "62M":
+			if result == nil {
+				return nil, ErrNoMT940Statement
+			}
+			amount, _, date, err := parseMT940Balance(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse closing balance: %w", err)
+			}
+			result.ClosingBalance = amount
+			result.CreationTime = date
+			closingSeen = true
+		case "61":
+			if result == nil {
+				return nil, ErrNoMT940Statement
+			}
+			entry, err := parseMT940Line(rec.lines, result.Account.Currency)
+			if err != nil {
+				return nil, err
+			}
+			result.Entries = append(result.Entries, entry)
+			last = entry
+		case "86":
+			if last != nil {
+				applyMT940Details(last, rec.lines)
+				last = nil
+			}
+		}
+	}
+
+	if result == nil {
+		return nil, ErrNoMT940Statement
+	}
+	if !closingSeen {
+		return nil, errors.New("MT940 statement has no closing balance")
+	}
+	return result, nil
+}
+
+// splitMT940 splits the input into tagged records, dropping the SWIFT block
+// envelope ({1:...}{4: and -}) if present
+func splitMT940(s string) []mt940Record {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+
+	var records []mt940Record
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimRight(line, " ")
+		if i := strings.Index(line, "{4:"); i >= 0 {
+			line = line[i+3:]
+		}
+		if line == "" || line == "-" || line == "-}" || strings.HasPrefix(line, "{") {
+			continue
+		}
+		if m := mt940Tag.FindStringSubmatch(line); m != nil {
+			records = append(records, mt940Record{tag: m[1], lines: []string{line[len(m[0]):]}})
+			continue
+		}
+		if len(records) > 0 {
+			last := &records[len(records)-1]
+			last.lines = append(last.lines, line)
+		}
+	}
+	return records
+}
+
+// parseMT940Amount parses an amount such as "1234,56" into cents
+func parseMT940Amount(s string) (int64, error) {
+	m, err := money.Parse(strings.Replace(s, ",", ".", 1), "")
+	if err != nil {
+		return 0, err
+	}
+	return m.Cents, nil
+}
+
+func parseMT940Date(s string) (time.Time, error) {
+	return time.Parse("060102", s)
+}
+
+func parseMT940Balance(s string) (int64, string, time.Time, error) {
+	m := mt940Balance.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, "", time.Time{}, fmt.Errorf("invalid balance %q", s)
+	}
+	date, err := parseMT940Date(m[2])
+	if err != nil {
+		return 0, "", time.Time{}, err
+	}
+	amount, err := parseMT940Amount(m[4])
+	if err != nil {
+		return 0, "", time.Time{}, err
+	}
+	if m[1] == "D" {
+		amount = -amount
+	}
+	return amount, m[3], date, nil
+}
+
+func parseMT940Line(lines []string, currency string) (*SEPAStatementEntry, error) {
+	m := mt940Line61.FindStringSubmatch(strings.TrimSpace(lines[0]))
+	if m == nil {
+		return nil, fmt.Errorf("invalid MT940 statement line %q", lines[0])
+	}
+
+	valueDate, err := parseMT940Date(m[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid value date in %q", lines[0])
+	}
+	amount, err := parseMT940Amount(m[5])
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount in %q", lines[0])
+	}
+	if currency == "" {
+		currency = "EUR"
+	}
+
+	entry := &SEPAStatementEntry{
+		Amount:      amount,
+		Currency:    currency,
+		BookingDate: valueDate,
+		ValueDate:   valueDate,
+	}
+
+	// An entry date without year is in the year of the value date, or the
+	// adjacent one around the turn of the year
+	if m[2] != "" {
+		if booking, err := time.Parse("20060102", fmt.Sprintf("%04d%s", valueDate.Year(), m[2])); err == nil {
+			if d := booking.Sub(valueDate); d > 180*24*time.Hour {
+				booking = booking.AddDate(-1, 0, 0)
+			} else if d < -180*24*time.Hour {
+				booking = booking.AddDate(1, 0, 0)
+			}
+			entry.BookingDate = booking
+		}
+	}
+
+	// RC reverses a credit and RD a debit
+	switch m[3] {
+	case "C", "RD":
+		entry.CreditDebit = CreditDebitCredit
+	default:
+		entry.CreditDebit = CreditDebitDebit
+	}
+
+	if ref := strings.TrimSpace(m[7]); ref != "" && ref != "NONREF" {
+		entry.EndToEndID = ref
+	}
+	entry.Reference = strings.TrimSpace(m[8])
+	return entry, nil
+}
+
+// applyMT940Details reads the information to the account owner (:86:)
+func applyMT940Details(entry *SEPAStatementEntry, lines []string) {
+	text := strings.Join(lines, "")
+	if len(text) < 4 || !isDigits(text[:3]) || text[3] != '?' {
+		entry.RemittanceInfo = strings.TrimSpace(strings.Join(lines, " "))
+		applySEPAKeywords(entry)
+		return
+	}
+
+	var remittance, name strings.Builder
+	for _, field := range strings.Split(text[4:], "?") {
+		if len(field) < 2 || !isDigits(field[:2]) {
+			continue
+		}
+		code, value := field[:2], field[2:]
+		switch {
+		case code >= "20" && code <= "29", code >= "60" && code <= "63":
+			remittance.WriteString(value)
+		case code == "31":
+			if mt940IBAN.MatchString(value) {
+				entry.CounterpartyIBAN = value
+			}
+		case code == "32", code == "33":
+			name.WriteString(value)
+		}
+	}
+	entry.RemittanceInfo = strings.TrimSpace(remittance.String())
+	entry.CounterpartyName = strings.TrimSpace(name.String())
+	applySEPAKeywords(entry)
+}
+
+// sepaKeyword matches the SEPA keywords of a remittance such as "EREF+"
+var sepaKeyword = regexp.MustCompile(`(EREF|KREF|MREF|CRED|DEBT|SVWZ|ABWA|ABWE)\+`)
+
+// applySEPAKeywords splits remittance information with SEPA keywords into
+// the end-to-end reference (EREF+) and the remittance text (SVWZ+)
+func applySEPAKeywords(entry *SEPAStatementEntry) {
+	text := entry.RemittanceInfo
+	matches := sepaKeyword.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return
+	}
+	for i, m := range matches {
+		end := len(text)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		value := strings.TrimSpace(text[m[1]:end])
+		switch text[m[2]:m[3]] {
+		case "EREF":
+			if value != "NOTPROVIDED" {
+				entry.EndToEndID = value
+			}
+		case "SVWZ":
+			entry.RemittanceInfo = value
+		}
+	}
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,174 @@
+package sigbilling
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler handles signature billing HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new signature billing handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers signature billing routes. Adding packages,
+// changing the settings and generating statements is admin-only.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/signature-billing", requireAuth(http.HandlerFunc(h.GetBalance)))
+	router.Handle("PUT /api/v1/signature-billing/account", requireAuth(requireAdmin(http.HandlerFunc(h.SetAccount))))
+	router.Handle("GET /api/v1/signature-billing/packages", requireAuth(http.HandlerFunc(h.ListPackages)))
+	router.Handle("POST /api/v1/signature-billing/packages", requireAuth(requireAdmin(http.HandlerFunc(h.AddPackage))))
+	router.Handle("GET /api/v1/signature-billing/statements", requireAuth(http.HandlerFunc(h.ListStatements)))
+	router.Handle("GET /api/v1/signature-billing/statements/{period}", requireAuth(http.HandlerFunc(h.GetStatement)))
+	router.Handle("POST /api/v1/signature-billing/statements/{period}", requireAuth(requireAdmin(http.HandlerFunc(h.GenerateStatement))))
+}
+
+// GetBalance handles GET /api/v1/signature-billing
+func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	balance, err := h.service.GetBalance(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, balance)
+}
+
+// SetAccount handles PUT /api/v1/signature-billing/account
+func (h *Handler) SetAccount(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var input SetAccountInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	account, err := h.service.SetAccount(r.Context(), tenantID, &input, api.OptionalUser(r))
+	if err != nil {
+		if errors.Is(err, ErrInvalidAccount) {
+			api.BadRequest(w, err.Error())
+			return
+		}
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, account)
+}
+
+// ListPackages handles GET /api/v1/signature-billing/packages
+func (h *Handler) ListPackages(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	packages, err := h.service.ListPackages(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"packages": packages})
+}
+
+// AddPackage handles POST /api/v1/signature-billing/packages
+func (h *Handler) AddPackage(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var input AddPackageInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	p, err := h.service.AddPackage(r.Context(), tenantID, &input, api.OptionalUser(r))
+	if err != nil {
+		if errors.Is(err, ErrInvalidPackage) {
+			api.BadRequest(w, err.Error())
+			return
+		}
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, p)
+}
+
+// ListStatements handles GET /api/v1/signature-billing/statements
+func (h *Handler) ListStatements(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	statements, err := h.service.ListStatements(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"statements": statements})
+}
+
+// GetStatement handles GET /api/v1/signature-billing/statements/{period}
+func (h *Handler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	st, err := h.service.GetStatement(r.Context(), tenantID, r.PathValue("period"))
+	if err != nil {
+		writeStatementError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, st)
+}
+
+// GenerateStatement handles POST /api/v1/signature-billing/statements/{period}
+func (h *Handler) GenerateStatement(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	st, err := h.service.GenerateStatement(r.Context(), tenantID, r.PathValue("period"))
+	if err != nil {
+		writeStatementError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, st)
+}
+
+func writeStatementError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidPeriod):
+		api.BadRequest(w, err.Error())
+	case errors.Is(err, ErrStatementNotFound):
+		api.NotFound(w, "statement not found")
+	default:
+		api.InternalError(w)
+	}
+}
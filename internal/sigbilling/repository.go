@@ -0,0 +1,457 @@
+package sigbilling
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles signature billing database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new signature billing repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// accountRow is a tenant's stored settings; nil fields use the defaults
+type accountRow struct {
+	postpaid             *bool
+	lowBalanceThreshold  *int
+	lowBalanceNotifiedAt *time.Time
+	updatedAt            time.Time
+}
+
+// getAccount returns a tenant's stored settings, or nil if it has none
+func (r *Repository) getAccount(ctx context.Context, tenantID uuid.UUID) (*accountRow, error) {
+	var a accountRow
+	err := r.db.QueryRow(ctx, `
+		SELECT postpaid, low_balance_threshold, low_balance_notified_at, updated_at
+		FROM signature_billing_accounts
+		WHERE tenant_id = $1
+	`, tenantID).Scan(&a.postpaid, &a.lowBalanceThreshold, &a.lowBalanceNotifiedAt, &a.updatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get signature billing account: %w", err)
+	}
+	return &a, nil
+}
+
+// setAccount changes a tenant's settings; nil arguments keep their value
+func (r *Repository) setAccount(ctx context.Context, tenantID uuid.UUID, postpaid *bool, threshold *int, updatedBy *uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO signature_billing_accounts (tenant_id, postpaid, low_balance_threshold, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			postpaid = COALESCE(EXCLUDED.postpaid, signature_billing_accounts.postpaid),
+			low_balance_threshold = COALESCE(EXCLUDED.low_balance_threshold, signature_billing_accounts.low_balance_threshold),
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+	`, tenantID, postpaid, threshold, updatedBy)
+	if err != nil {
+		return fmt.Errorf("set signature billing account: %w", err)
+	}
+	return nil
+}
+
+// markLowBalanceNotified flags the low-balance mail as sent. It returns
+// false if it was already flagged, so only one caller sends the mail.
+func (r *Repository) markLowBalanceNotified(ctx context.Context, tenantID uuid.UUID) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO signature_billing_accounts (tenant_id, low_balance_notified_at)
+		VALUES ($1, NOW())
+		ON CONFLICT (tenant_id) DO UPDATE SET low_balance_notified_at = NOW()
+		WHERE signature_billing_accounts.low_balance_notified_at IS NULL
+	`, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("mark low balance notified: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+const packageColumns = `id, tenant_id, signatures, remaining, price_cents, reference, purchased_at, expires_at, created_by`
+
+func scanPackage(row pgx.Row) (*Package, error) {
+	var p Package
+	err := row.Scan(&p.ID, &p.TenantID, &p.Signatures, &p.Remaining, &p.PriceCents,
+		&p.Reference, &p.PurchasedAt, &p.ExpiresAt, &p.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func collectPackages(rows pgx.Rows) ([]*Package, error) {
+	defer rows.Close()
+	packages := []*Package{}
+	for rows.Next() {
+		p, err := scanPackage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan credit package: %w", err)
+		}
+		packages = append(packages, p)
+	}
+	return packages, rows.Err()
+}
+
+// openPackagesOrder is the consumption order: earliest expiry first, then
+// oldest purchase
+const openPackagesOrder = `ORDER BY expires_at ASC NULLS LAST, purchased_at ASC`
+
+// OpenPackages returns a tenant's usable packages in consumption order
+func (r *Repository) OpenPackages(ctx context.Context, tenantID uuid.UUID, now time.Time) ([]*Package, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+packageColumns+`
+		FROM signature_credit_packages
+		WHERE tenant_id = $1 AND remaining > 0 AND (expires_at IS NULL OR expires_at > $2)
+		`+openPackagesOrder, tenantID, now)
+	if err != nil {
+		return nil, fmt.Errorf("list open credit packages: %w", err)
+	}
+	return collectPackages(rows)
+}
+
+// ListPackages returns all of a tenant's packages, newest first
+func (r *Repository) ListPackages(ctx context.Context, tenantID uuid.UUID) ([]*Package, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+packageColumns+`
+		FROM signature_credit_packages
+		WHERE tenant_id = $1
+		ORDER BY purchased_at DESC
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list credit packages: %w", err)
+	}
+	return collectPackages(rows)
+}
+
+// AddPackage stores a package with its purchase ledger entry and clears the
+// low-balance flag, so the next drop below the threshold is mailed again
+func (r *Repository) AddPackage(ctx context.Context, p *Package) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO signature_credit_packages (tenant_id, signatures, remaining, price_cents, reference, expires_at, created_by)
+		VALUES ($1, $2, $2, $3, $4, $5, $6)
+		RETURNING id, remaining, purchased_at
+	`, p.TenantID, p.Signatures, p.PriceCents, p.Reference, p.ExpiresAt, p.CreatedBy).Scan(&p.ID, &p.Remaining, &p.PurchasedAt)
+	if err != nil {
+		return fmt.Errorf("insert credit package: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO signature_credit_ledger (tenant_id, package_id, reason, delta, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, p.TenantID, p.ID, ReasonPurchase, p.Signatures, p.PurchasedAt); err != nil {
+		return fmt.Errorf("insert purchase ledger entry: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE signature_billing_accounts SET low_balance_notified_at = NULL WHERE tenant_id = $1
+	`, p.TenantID); err != nil {
+		return fmt.Errorf("reset low balance flag: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// Consume debits count signatures of a usage from the tenant's packages.
+// The packages are locked while debiting, so concurrent usages never spend
+// the same credits. It returns the remaining balance and the signatures no
+// credits were left for.
+func (r *Repository) Consume(ctx context.Context, tenantID, usageID uuid.UUID, count int, now time.Time) (remaining, uncovered int, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT `+packageColumns+`
+		FROM signature_credit_packages
+		WHERE tenant_id = $1 AND remaining > 0 AND (expires_at IS NULL OR expires_at > $2)
+		`+openPackagesOrder+`
+		FOR UPDATE
+	`, tenantID, now)
+	if err != nil {
+		return 0, 0, fmt.Errorf("lock credit packages: %w", err)
+	}
+	packages, err := collectPackages(rows)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	debits, uncovered := Allocate(packages, count, now)
+	for _, d := range debits {
+		if _, err := tx.Exec(ctx, `
+			UPDATE signature_credit_packages SET remaining = remaining - $2 WHERE id = $1
+		`, d.PackageID, d.Signatures); err != nil {
+			return 0, 0, fmt.Errorf("debit credit package: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO signature_credit_ledger (tenant_id, package_id, reason, delta, usage_id)
+			VALUES ($1, $2, $3, $4, $5)
+		`, tenantID, d.PackageID, ReasonUsage, -d.Signatures, usageID); err != nil {
+			return 0, 0, fmt.Errorf("insert usage ledger entry: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("commit: %w", err)
+	}
+
+	for _, p := range packages {
+		remaining += p.Remaining
+	}
+	return remaining - (count - uncovered), uncovered, nil
+}
+
+// ExpirePackages zeroes the packages that expired before now and books the
+// forfeited credits at their expiry time. It returns the number of packages.
+func (r *Repository) ExpirePackages(ctx context.Context, now time.Time) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO signature_credit_ledger (tenant_id, package_id, reason, delta, created_at)
+		SELECT tenant_id, id, $2, -remaining, expires_at
+		FROM signature_credit_packages
+		WHERE remaining > 0 AND expires_at <= $1
+	`, now, ReasonExpiry); err != nil {
+		return 0, fmt.Errorf("insert expiry ledger entries: %w", err)
+	}
+	tag, err := tx.Exec(ctx, `
+		UPDATE signature_credit_packages SET remaining = 0 WHERE remaining > 0 AND expires_at <= $1
+	`, now)
+	if err != nil {
+		return 0, fmt.Errorf("expire credit packages: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// usageLines returns the usages of a tenant dated in [from, to) with the
+// signatures credits covered
+func (r *Repository) usageLines(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*StatementLine, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT u.id, u.usage_date, u.signature_request_id, u.batch_id, u.signature_count,
+			COALESCE(u.cost_cents, 0),
+			COALESCE((SELECT -SUM(l.delta) FROM signature_credit_ledger l
+			          WHERE l.usage_id = u.id AND l.reason = 'usage'), 0)
+		FROM signature_usage u
+		WHERE u.tenant_id = $1 AND u.usage_date >= $2::date AND u.usage_date < $3::date
+		ORDER BY u.usage_date, u.created_at
+	`, tenantID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("list signature usage: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []*StatementLine{}
+	for rows.Next() {
+		var l StatementLine
+		var date time.Time
+		if err := rows.Scan(&l.UsageID, &date, &l.SignatureRequestID, &l.BatchID, &l.Signatures, &l.CostCents, &l.Covered); err != nil {
+			return nil, fmt.Errorf("scan signature usage: %w", err)
+		}
+		l.Date = date.Format("2006-01-02")
+		lines = append(lines, &l)
+	}
+	return lines, rows.Err()
+}
+
+// ledgerTotals sums a tenant's ledger: the balance before from and before
+// to, and the purchases and expiries in [from, to)
+func (r *Repository) ledgerTotals(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (opening, closing, purchased, expired int, err error) {
+	err = r.db.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(delta) FILTER (WHERE created_at < $2), 0),
+			COALESCE(SUM(delta) FILTER (WHERE created_at < $3), 0),
+			COALESCE(SUM(delta) FILTER (WHERE reason = 'purchase' AND created_at >= $2 AND created_at < $3), 0),
+			COALESCE(-SUM(delta) FILTER (WHERE reason = 'expiry' AND created_at >= $2 AND created_at < $3), 0)
+		FROM signature_credit_ledger
+		WHERE tenant_id = $1
+	`, tenantID, from, to).Scan(&opening, &closing, &purchased, &expired)
+	if err != nil {
+		err = fmt.Errorf("sum credit ledger: %w", err)
+	}
+	return
+}
+
+// purchasedCents sums the price of the packages bought in [from, to)
+func (r *Repository) purchasedCents(ctx context.Context, tenantID uuid.UUID, from, to time.Time) (int, error) {
+	var cents int
+	err := r.db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(price_cents), 0)
+		FROM signature_credit_packages
+		WHERE tenant_id = $1 AND purchased_at >= $2 AND purchased_at < $3
+	`, tenantID, from, to).Scan(&cents)
+	if err != nil {
+		return 0, fmt.Errorf("sum package prices: %w", err)
+	}
+	return cents, nil
+}
+
+// SaveStatement creates or replaces the statement of a tenant's period
+func (r *Repository) SaveStatement(ctx context.Context, st *Statement) error {
+	lines, err := json.Marshal(st.Lines)
+	if err != nil {
+		return fmt.Errorf("marshal statement lines: %w", err)
+	}
+	err = r.db.QueryRow(ctx, `
+		INSERT INTO signature_statements (
+			tenant_id, period, signatures, cost_cents, covered_signatures, billable_signatures,
+			billable_cents, purchased_signatures, purchased_cents, expired_signatures,
+			opening_balance, closing_balance, lines, generated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW())
+		ON CONFLICT (tenant_id, period) DO UPDATE SET
+			signatures = EXCLUDED.signatures,
+			cost_cents = EXCLUDED.cost_cents,
+			covered_signatures = EXCLUDED.covered_signatures,
+			billable_signatures = EXCLUDED.billable_signatures,
+			billable_cents = EXCLUDED.billable_cents,
+			purchased_signatures = EXCLUDED.purchased_signatures,
+			purchased_cents = EXCLUDED.purchased_cents,
+			expired_signatures = EXCLUDED.expired_signatures,
+			opening_balance = EXCLUDED.opening_balance,
+			closing_balance = EXCLUDED.closing_balance,
+			lines = EXCLUDED.lines,
+			generated_at = NOW()
+		RETURNING id, generated_at
+	`, st.TenantID, st.Period, st.Signatures, st.CostCents, st.CoveredSignatures, st.BillableSignatures,
+		st.BillableCents, st.PurchasedSignatures, st.PurchasedCents, st.ExpiredSignatures,
+		st.OpeningBalance, st.ClosingBalance, lines).Scan(&st.ID, &st.GeneratedAt)
+	if err != nil {
+		return fmt.Errorf("save statement: %w", err)
+	}
+	return nil
+}
+
+// GetStatement returns the statement of a tenant's period
+func (r *Repository) GetStatement(ctx context.Context, tenantID uuid.UUID, period string) (*Statement, error) {
+	var st Statement
+	var lines []byte
+	err := r.db.QueryRow(ctx, `
+		SELECT id, tenant_id, period, signatures, cost_cents, covered_signatures, billable_signatures,
+			billable_cents, purchased_signatures, purchased_cents, expired_signatures,
+			opening_balance, closing_balance, lines, generated_at
+		FROM signature_statements
+		WHERE tenant_id = $1 AND period = $2
+	`, tenantID, period).Scan(&st.ID, &st.TenantID, &st.Period, &st.Signatures, &st.CostCents,
+		&st.CoveredSignatures, &st.BillableSignatures, &st.BillableCents, &st.PurchasedSignatures,
+		&st.PurchasedCents, &st.ExpiredSignatures, &st.OpeningBalance, &st.ClosingBalance, &lines, &st.GeneratedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrStatementNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get statement: %w", err)
+	}
+	if err := json.Unmarshal(lines, &st.Lines); err != nil {
+		return nil, fmt.Errorf("unmarshal statement lines: %w", err)
+	}
+	return &st, nil
+}
+
+// ListStatements returns a tenant's statements, newest first
+func (r *Repository) ListStatements(ctx context.Context, tenantID uuid.UUID) ([]*StatementSummary, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT period, signatures, billable_signatures, billable_cents, purchased_cents, closing_balance, generated_at
+		FROM signature_statements
+		WHERE tenant_id = $1
+		ORDER BY period DESC
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list statements: %w", err)
+	}
+	defer rows.Close()
+
+	statements := []*StatementSummary{}
+	for rows.Next() {
+		var s StatementSummary
+		if err := rows.Scan(&s.Period, &s.Signatures, &s.BillableSignatures, &s.BillableCents,
+			&s.PurchasedCents, &s.ClosingBalance, &s.GeneratedAt); err != nil {
+			return nil, fmt.Errorf("scan statement: %w", err)
+		}
+		statements = append(statements, &s)
+	}
+	return statements, rows.Err()
+}
+
+// ActiveTenants returns the tenants with signature usage or ledger entries
+// in [from, to)
+func (r *Repository) ActiveTenants(ctx context.Context, from, to time.Time) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT tenant_id FROM signature_usage
+		WHERE usage_date >= $1::date AND usage_date < $2::date
+		UNION
+		SELECT tenant_id FROM signature_credit_ledger
+		WHERE created_at >= $3 AND created_at < $4
+	`, from.Format("2006-01-02"), to.Format("2006-01-02"), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("list active tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, id)
+	}
+	return tenants, rows.Err()
+}
+
+// Recipient is a tenant admin receiving billing notifications
+type Recipient struct {
+	Name  string
+	Email string
+}
+
+// tenantAdmins returns the active owners and admins of a tenant
+func (r *Repository) tenantAdmins(ctx context.Context, tenantID uuid.UUID) ([]Recipient, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT name, email FROM users
+		WHERE tenant_id = $1 AND is_active AND role IN ('owner', 'admin')
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list tenant admins: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []Recipient
+	for rows.Next() {
+		var rc Recipient
+		if err := rows.Scan(&rc.Name, &rc.Email); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, rc)
+	}
+	return recipients, rows.Err()
+}
+
+// tenantName returns the name of a tenant
+func (r *Repository) tenantName(ctx context.Context, tenantID uuid.UUID) (string, error) {
+	var name string
+	if err := r.db.QueryRow(ctx, `SELECT name FROM tenants WHERE id = $1`, tenantID).Scan(&name); err != nil {
+		return "", fmt.Errorf("get tenant name: %w", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,411 @@
+// Package sigbilling bills qualified signatures: prepaid credit packages
+// with balance tracking, low-balance notifications, blocking of new
+// signature requests once a prepaid tenant's credits are exhausted, and
+// monthly statements of the usage recorded in signature_usage.
+package sigbilling
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/email"
+)
+
+// Notifier sends the low-balance mail to tenant admins; email.Service
+// implements it
+type Notifier interface {
+	SendSignatureLowBalance(ctx context.Context, to string, params email.SignatureLowBalanceParams) error
+}
+
+// Config holds the defaults of tenants without own settings
+type Config struct {
+	DefaultPostpaid            bool
+	DefaultLowBalanceThreshold int
+	// BillingURL is linked in the low-balance mail
+	BillingURL string
+	Logger     *slog.Logger
+}
+
+// Service tracks signature credits and generates statements.
+//
+// Credits are checked when a request is created and debited when its
+// signatures are recorded, without reserving them in between. Requests
+// admitted while credits were left are always completed; signatures the
+// credits no longer cover then appear as billable on the statement, like
+// the overage of postpaid tenants.
+type Service struct {
+	repo     *Repository
+	notifier Notifier
+	cfg      Config
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewService creates a new signature billing service
+func NewService(repo *Repository, cfg Config) *Service {
+	if cfg.DefaultLowBalanceThreshold < 0 {
+		cfg.DefaultLowBalanceThreshold = 0
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{repo: repo, cfg: cfg, logger: logger, now: time.Now}
+}
+
+// SetNotifier enables the low-balance mail to tenant admins
+func (s *Service) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
+// GetAccount returns the billing settings that apply to a tenant
+func (s *Service) GetAccount(ctx context.Context, tenantID uuid.UUID) (*Account, error) {
+	row, err := s.repo.getAccount(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolveAccount(row), nil
+}
+
+// resolveAccount fills the settings a tenant has not set with the defaults
+func (s *Service) resolveAccount(row *accountRow) *Account {
+	a := &Account{
+		Postpaid:            s.cfg.DefaultPostpaid,
+		LowBalanceThreshold: s.cfg.DefaultLowBalanceThreshold,
+		IsDefault:           true,
+	}
+	if row == nil {
+		return a
+	}
+	if row.postpaid != nil {
+		a.Postpaid = *row.postpaid
+		a.IsDefault = false
+	}
+	if row.lowBalanceThreshold != nil {
+		a.LowBalanceThreshold = *row.lowBalanceThreshold
+		a.IsDefault = false
+	}
+	a.LowBalanceNotifiedAt = row.lowBalanceNotifiedAt
+	a.UpdatedAt = &row.updatedAt
+	return a
+}
+
+// SetAccount changes a tenant's billing settings
+func (s *Service) SetAccount(ctx context.Context, tenantID uuid.UUID, input *SetAccountInput, updatedBy *uuid.UUID) (*Account, error) {
+	if input.Postpaid == nil && input.LowBalanceThreshold == nil {
+		return nil, fmt.Errorf("%w: nothing to change", ErrInvalidAccount)
+	}
+	if input.LowBalanceThreshold != nil && *input.LowBalanceThreshold < 0 {
+		return nil, fmt.Errorf("%w: low_balance_threshold must be 0 (disabled) or positive", ErrInvalidAccount)
+	}
+	if err := s.repo.setAccount(ctx, tenantID, input.Postpaid, input.LowBalanceThreshold, updatedBy); err != nil {
+		return nil, err
+	}
+	return s.GetAccount(ctx, tenantID)
+}
+
+// GetBalance returns a tenant's remaining credits
+func (s *Service) GetBalance(ctx context.Context, tenantID uuid.UUID) (*Balance, error) {
+	account, err := s.GetAccount(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	packages, err := s.repo.OpenPackages(ctx, tenantID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	return Evaluate(account, packages), nil
+}
+
+// Evaluate sums the usable packages against the tenant's settings
+func Evaluate(account *Account, packages []*Package) *Balance {
+	b := &Balance{Account: account, Packages: packages}
+	for _, p := range packages {
+		b.Remaining += p.Remaining
+	}
+	b.Low = account.LowBalanceThreshold > 0 && b.Remaining <= account.LowBalanceThreshold
+	b.Blocked = !account.Postpaid && b.Remaining == 0
+	return b
+}
+
+// CheckCredits returns an error matching ErrCreditsExhausted if a tenant
+// may not request n more signatures: it is not postpaid and its credits do
+// not cover them
+func (s *Service) CheckCredits(ctx context.Context, tenantID uuid.UUID, n int) error {
+	b, err := s.GetBalance(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return Admit(b, n)
+}
+
+// Admit returns an error matching ErrCreditsExhausted if the balance does
+// not allow n more signatures
+func Admit(b *Balance, n int) error {
+	if b.Account.Postpaid || b.Remaining >= n {
+		return nil
+	}
+	if b.Remaining == 0 {
+		return fmt.Errorf("%w: buy a signature package to sign again", ErrCreditsExhausted)
+	}
+	return fmt.Errorf("%w: %d signatures needed, %d left", ErrCreditsExhausted, n, b.Remaining)
+}
+
+// ConsumeCredits debits the signatures of a recorded usage from the
+// tenant's packages and mails the admins once the balance falls to the
+// low-balance threshold
+func (s *Service) ConsumeCredits(ctx context.Context, tenantID, usageID uuid.UUID, signatures int) error {
+	if signatures <= 0 {
+		return nil
+	}
+	remaining, uncovered, err := s.repo.Consume(ctx, tenantID, usageID, signatures, s.now())
+	if err != nil {
+		return err
+	}
+	if uncovered == signatures {
+		// No credits were spent, e.g. a postpaid tenant without packages
+		return nil
+	}
+
+	account, err := s.GetAccount(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if account.LowBalanceThreshold > 0 && remaining <= account.LowBalanceThreshold && account.LowBalanceNotifiedAt == nil {
+		s.notifyLowBalance(tenantID, account, remaining)
+	}
+	return nil
+}
+
+// Allocate splits count signatures over packages, earliest expiry first,
+// skipping packages that are empty or expired at now. It returns the debits
+// and the signatures no package covered; packages are not modified.
+func Allocate(packages []*Package, count int, now time.Time) ([]Debit, int) {
+	ordered := make([]*Package, len(packages))
+	copy(ordered, packages)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		switch {
+		case a.ExpiresAt != nil && b.ExpiresAt != nil && !a.ExpiresAt.Equal(*b.ExpiresAt):
+			return a.ExpiresAt.Before(*b.ExpiresAt)
+		case (a.ExpiresAt == nil) != (b.ExpiresAt == nil):
+			return a.ExpiresAt != nil
+		default:
+			return a.PurchasedAt.Before(b.PurchasedAt)
+		}
+	})
+
+	var debits []Debit
+	for _, p := range ordered {
+		if count == 0 {
+			break
+		}
+		if p.Remaining <= 0 || p.Expired(now) {
+			continue
+		}
+		n := min(p.Remaining, count)
+		debits = append(debits, Debit{PackageID: p.ID, Signatures: n})
+		count -= n
+	}
+	return debits, count
+}
+
+// notifyLowBalance mails the tenant's admins in the background
+func (s *Service) notifyLowBalance(tenantID uuid.UUID, account *Account, remaining int) {
+	if s.notifier == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		// Only the caller that sets the flag sends the mail
+		marked, err := s.repo.markLowBalanceNotified(ctx, tenantID)
+		if err != nil || !marked {
+			if err != nil {
+				s.logger.Error("failed to flag low signature balance", "tenant_id", tenantID, "error", err)
+			}
+			return
+		}
+		tenantName, err := s.repo.tenantName(ctx, tenantID)
+		if err != nil {
+			s.logger.Error("failed to load tenant for low balance notification", "tenant_id", tenantID, "error", err)
+			return
+		}
+		recipients, err := s.repo.tenantAdmins(ctx, tenantID)
+		if err != nil {
+			s.logger.Error("failed to load tenant admins for low balance notification", "tenant_id", tenantID, "error", err)
+			return
+		}
+		for _, rc := range recipients {
+			err := s.notifier.SendSignatureLowBalance(ctx, rc.Email, email.SignatureLowBalanceParams{
+				RecipientName: rc.Name,
+				TenantName:    tenantName,
+				Remaining:     remaining,
+				Threshold:     account.LowBalanceThreshold,
+				Postpaid:      account.Postpaid,
+				BillingURL:    s.cfg.BillingURL,
+			})
+			if err != nil {
+				s.logger.Error("failed to send low signature balance notification", "tenant_id", tenantID, "error", err)
+			}
+		}
+	}()
+}
+
+// AddPackage adds a prepaid package to a tenant's credits. Payment is
+// handled outside the platform; Reference records the order or invoice.
+func (s *Service) AddPackage(ctx context.Context, tenantID uuid.UUID, input *AddPackageInput, createdBy *uuid.UUID) (*Package, error) {
+	if input.Signatures <= 0 {
+		return nil, fmt.Errorf("%w: signatures must be positive", ErrInvalidPackage)
+	}
+	if input.PriceCents < 0 {
+		return nil, fmt.Errorf("%w: price_cents must not be negative", ErrInvalidPackage)
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(s.now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidPackage)
+	}
+	if len(input.Reference) > 100 {
+		return nil, fmt.Errorf("%w: reference must be at most 100 characters", ErrInvalidPackage)
+	}
+
+	p := &Package{
+		TenantID:   tenantID,
+		Signatures: input.Signatures,
+		PriceCents: input.PriceCents,
+		ExpiresAt:  input.ExpiresAt,
+		CreatedBy:  createdBy,
+	}
+	if input.Reference != "" {
+		p.Reference = &input.Reference
+	}
+	if err := s.repo.AddPackage(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListPackages returns all of a tenant's packages, newest first
+func (s *Service) ListPackages(ctx context.Context, tenantID uuid.UUID) ([]*Package, error) {
+	return s.repo.ListPackages(ctx, tenantID)
+}
+
+// GenerateStatement creates or replaces a tenant's statement of a period
+// (YYYY-MM). The current month may be generated as a preview; it is
+// replaced when the month is generated again.
+func (s *Service) GenerateStatement(ctx context.Context, tenantID uuid.UUID, period string) (*Statement, error) {
+	from, to, err := ParsePeriod(period)
+	if err != nil {
+		return nil, err
+	}
+	if from.After(s.now()) {
+		return nil, fmt.Errorf("%w: %s has not started", ErrInvalidPeriod, period)
+	}
+
+	lines, err := s.repo.usageLines(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	opening, closing, purchased, expired, err := s.repo.ledgerTotals(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	purchasedCents, err := s.repo.purchasedCents(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	st := BuildStatement(tenantID, period, lines)
+	st.PurchasedSignatures = purchased
+	st.PurchasedCents = purchasedCents
+	st.ExpiredSignatures = expired
+	st.OpeningBalance = opening
+	st.ClosingBalance = closing
+	if err := s.repo.SaveStatement(ctx, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// BuildStatement totals the usage lines of a period. The signatures of a
+// line that credits did not cover are billable at the line's recorded
+// per-signature cost.
+func BuildStatement(tenantID uuid.UUID, period string, lines []*StatementLine) *Statement {
+	st := &Statement{TenantID: tenantID, Period: period, Lines: lines}
+	for _, l := range lines {
+		l.Covered = min(max(l.Covered, 0), l.Signatures)
+		l.Billable = l.Signatures - l.Covered
+		l.BillableCents = 0
+		if l.Signatures > 0 {
+			l.BillableCents = l.CostCents * l.Billable / l.Signatures
+		}
+
+		st.Signatures += l.Signatures
+		st.CostCents += l.CostCents
+		st.CoveredSignatures += l.Covered
+		st.BillableSignatures += l.Billable
+		st.BillableCents += l.BillableCents
+	}
+	return st
+}
+
+// GetStatement returns a tenant's statement of a period
+func (s *Service) GetStatement(ctx context.Context, tenantID uuid.UUID, period string) (*Statement, error) {
+	if _, _, err := ParsePeriod(period); err != nil {
+		return nil, err
+	}
+	return s.repo.GetStatement(ctx, tenantID, period)
+}
+
+// ListStatements returns a tenant's statements, newest first
+func (s *Service) ListStatements(ctx context.Context, tenantID uuid.UUID) ([]*StatementSummary, error) {
+	return s.repo.ListStatements(ctx, tenantID)
+}
+
+// StatementRunResult summarizes a monthly statement run
+type StatementRunResult struct {
+	Period          string `json:"period"`
+	ExpiredPackages int    `json:"expired_packages"`
+	Statements      int    `json:"statements"`
+	Failed          int    `json:"failed"`
+}
+
+// PreviousPeriod returns the month before the one containing now
+func PreviousPeriod(now time.Time) string {
+	t := now.In(vienna)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, vienna).AddDate(0, -1, 0).Format(PeriodLayout)
+}
+
+// GenerateStatements expires lapsed packages and generates the statement
+// of period for every tenant with signature usage or credit changes in it
+func (s *Service) GenerateStatements(ctx context.Context, period string) (*StatementRunResult, error) {
+	from, to, err := ParsePeriod(period)
+	if err != nil {
+		return nil, err
+	}
+	result := &StatementRunResult{Period: period}
+
+	result.ExpiredPackages, err = s.repo.ExpirePackages(ctx, s.now())
+	if err != nil {
+		return nil, err
+	}
+	tenants, err := s.repo.ActiveTenants(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, tenantID := range tenants {
+		if _, err := s.GenerateStatement(ctx, tenantID, period); err != nil {
+			s.logger.Error("failed to generate signature statement", "tenant_id", tenantID, "period", period, "error", err)
+			result.Failed++
+			continue
+		}
+		result.Statements++
+	}
+	return result, nil
+}
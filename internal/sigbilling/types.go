@@ -0,0 +1,153 @@
+package sigbilling
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/timezone"
+)
+
+var (
+	ErrCreditsExhausted  = errors.New("signature credits exhausted")
+	ErrInvalidPackage    = errors.New("invalid credit package")
+	ErrInvalidAccount    = errors.New("invalid billing settings")
+	ErrInvalidPeriod     = errors.New("invalid statement period")
+	ErrStatementNotFound = errors.New("statement not found")
+)
+
+// PeriodLayout is the layout of statement periods, e.g. "2026-09"
+const PeriodLayout = "2006-01"
+
+// Ledger reasons
+const (
+	ReasonPurchase = "purchase"
+	ReasonUsage    = "usage"
+	ReasonExpiry   = "expiry"
+)
+
+// Account holds a tenant's billing settings
+type Account struct {
+	// Postpaid tenants may sign beyond their credits; the overage is billed
+	// on the monthly statement
+	Postpaid bool `json:"postpaid"`
+	// LowBalanceThreshold is the balance at or below which tenant admins are
+	// mailed; 0 disables the mail
+	LowBalanceThreshold  int        `json:"low_balance_threshold"`
+	LowBalanceNotifiedAt *time.Time `json:"low_balance_notified_at,omitempty"`
+	IsDefault            bool       `json:"is_default"`
+	UpdatedAt            *time.Time `json:"updated_at,omitempty"`
+}
+
+// Package is a prepaid bundle of signatures
+type Package struct {
+	ID          uuid.UUID  `json:"id"`
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	Signatures  int        `json:"signatures"`
+	Remaining   int        `json:"remaining"`
+	PriceCents  int        `json:"price_cents"`
+	Reference   *string    `json:"reference,omitempty"`
+	PurchasedAt time.Time  `json:"purchased_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty"`
+}
+
+// Expired reports whether the package can no longer be used at now
+func (p *Package) Expired(now time.Time) bool {
+	return p.ExpiresAt != nil && !p.ExpiresAt.After(now)
+}
+
+// Balance is a tenant's remaining credits and what they allow
+type Balance struct {
+	Remaining int      `json:"remaining"`
+	Account   *Account `json:"account"`
+	// Low is set at or below the low-balance threshold
+	Low bool `json:"low"`
+	// Blocked is set when new signature requests are refused
+	Blocked  bool       `json:"blocked"`
+	Packages []*Package `json:"packages"` // usable packages, in consumption order
+}
+
+// Debit is the part of a usage charged to one package
+type Debit struct {
+	PackageID  uuid.UUID
+	Signatures int
+}
+
+// AddPackageInput holds input for adding a credit package
+type AddPackageInput struct {
+	Signatures int        `json:"signatures"`
+	PriceCents int        `json:"price_cents"`
+	Reference  string     `json:"reference,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// SetAccountInput holds input for changing a tenant's billing settings.
+// Omitted fields keep their value.
+type SetAccountInput struct {
+	Postpaid            *bool `json:"postpaid,omitempty"`
+	LowBalanceThreshold *int  `json:"low_balance_threshold,omitempty"`
+}
+
+// Statement is a tenant's signature usage and credits in one month
+type Statement struct {
+	ID       uuid.UUID `json:"id"`
+	TenantID uuid.UUID `json:"tenant_id"`
+	Period   string    `json:"period"`
+	// Signatures and CostCents are all usage of the month at the recorded
+	// per-signature cost
+	Signatures int `json:"signatures"`
+	CostCents  int `json:"cost_cents"`
+	// CoveredSignatures were paid with credits, BillableSignatures were not
+	// and are billed at BillableCents
+	CoveredSignatures   int              `json:"covered_signatures"`
+	BillableSignatures  int              `json:"billable_signatures"`
+	BillableCents       int              `json:"billable_cents"`
+	PurchasedSignatures int              `json:"purchased_signatures"`
+	PurchasedCents      int              `json:"purchased_cents"`
+	ExpiredSignatures   int              `json:"expired_signatures"`
+	OpeningBalance      int              `json:"opening_balance"`
+	ClosingBalance      int              `json:"closing_balance"`
+	Lines               []*StatementLine `json:"lines"`
+	GeneratedAt         time.Time        `json:"generated_at"`
+}
+
+// StatementLine is one recorded usage on a statement
+type StatementLine struct {
+	UsageID            uuid.UUID  `json:"usage_id"`
+	Date               string     `json:"date"` // YYYY-MM-DD
+	SignatureRequestID *uuid.UUID `json:"signature_request_id,omitempty"`
+	BatchID            *uuid.UUID `json:"batch_id,omitempty"`
+	Signatures         int        `json:"signatures"`
+	CostCents          int        `json:"cost_cents"`
+	Covered            int        `json:"covered"`
+	Billable           int        `json:"billable"`
+	BillableCents      int        `json:"billable_cents"`
+}
+
+// StatementSummary is a statement without its lines, for listings
+type StatementSummary struct {
+	Period             string    `json:"period"`
+	Signatures         int       `json:"signatures"`
+	BillableSignatures int       `json:"billable_signatures"`
+	BillableCents      int       `json:"billable_cents"`
+	PurchasedCents     int       `json:"purchased_cents"`
+	ClosingBalance     int       `json:"closing_balance"`
+	GeneratedAt        time.Time `json:"generated_at"`
+}
+
+// ParsePeriod parses a statement period and returns the first day of the
+// month and of the month after in Europe/Vienna
+func ParsePeriod(period string) (start, end time.Time, err error) {
+	t, err := time.Parse(PeriodLayout, period)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q is not YYYY-MM", ErrInvalidPeriod, period)
+	}
+	start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, vienna)
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// vienna is the zone statement months are counted in
+var vienna = timezone.Vienna
@@ -49,6 +49,14 @@ func (s *BatchService) CreateBatch(ctx context.Context, input *CreateBatchInput)
 	if len(input.DocumentIDs) > 100 {
 		return nil, fmt.Errorf("batch size exceeds maximum of 100 documents")
 	}
+	if err := s.service.checkCredits(ctx, input.TenantID, len(input.DocumentIDs)); err != nil {
+		return nil, err
+	}
+	for _, docID := range input.DocumentIDs {
+		if err := s.service.checkDocument(ctx, docID); err != nil {
+			return nil, fmt.Errorf("document %s: %w", docID, err)
+		}
+	}
 
 	batch := &Batch{
 		ID:             uuid.New(),
@@ -230,7 +238,7 @@ func (s *BatchService) CompleteBatchSigning(ctx context.Context, input *Complete
 			cost := s.service.config.SignatureCostCents * signed
 			usage.CostCents = &cost
 		}
-		s.repo.RecordUsage(ctx, usage)
+		s.service.recordUsage(ctx, usage)
 	}
 
 	// Audit
@@ -0,0 +1,49 @@
+package signature
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// PageBranding is the tenant's look of the public signing and status pages
+type PageBranding struct {
+	CompanyName  string `json:"company_name"`
+	LogoURL      string `json:"logo_url,omitempty"`
+	PrimaryColor string `json:"primary_color"`
+	AccentColor  string `json:"accent_color,omitempty"`
+}
+
+// Branding provides the tenant's white-label settings
+type Branding interface {
+	// PublicBaseURL moves a portal base URL to the tenant's custom domain,
+	// if it has one
+	PublicBaseURL(ctx context.Context, tenantID uuid.UUID, defaultURL string) string
+	// PageBranding returns nil if the tenant has no branding
+	PageBranding(ctx context.Context, tenantID uuid.UUID) (*PageBranding, error)
+}
+
+// SetBranding builds the signers' links and pages with the tenant's branding
+func (s *Service) SetBranding(branding Branding) {
+	s.branding = branding
+}
+
+func (s *Service) publicBaseURL(ctx context.Context, tenantID uuid.UUID, defaultURL string) string {
+	if s.branding == nil {
+		return defaultURL
+	}
+	return s.branding.PublicBaseURL(ctx, tenantID, defaultURL)
+}
+
+// pageBranding returns the branding of a status page. The page is shown
+// without it if it cannot be loaded.
+func (s *Service) pageBranding(ctx context.Context, tenantID uuid.UUID) *PageBranding {
+	if s.branding == nil {
+		return nil
+	}
+	branding, err := s.branding.PageBranding(ctx, tenantID)
+	if err != nil {
+		return nil
+	}
+	return branding
+}
@@ -0,0 +1,193 @@
+package signature
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/tenant"
+)
+
+// A signer who cannot sign, e.g. on vacation, can be replaced by the request
+// creator or hand over from their status page. The replacement takes over the
+// signer's position in the signing order and their fields and gets their own
+// links; the signer's signing link stops working. The replaced signer stays on
+// the request with status delegated, so the audit trail shows the whole chain.
+
+// MaxDelegations is how often a signing position can be handed over
+const MaxDelegations = 3
+
+// DelegationInput is the replacement signer
+type DelegationInput struct {
+	Email  string `json:"email"`
+	Name   string `json:"name"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// DelegationChain returns the signers a signing position passed through,
+// from the original signer to signer
+func DelegationChain(signers []*Signer, signer *Signer) []*Signer {
+	byID := make(map[uuid.UUID]*Signer, len(signers))
+	for _, s := range signers {
+		byID[s.ID] = s
+	}
+
+	chain := []*Signer{signer}
+	for current := signer; current.DelegatedFromID != nil && len(chain) <= len(signers); {
+		previous, ok := byID[*current.DelegatedFromID]
+		if !ok {
+			break
+		}
+		chain = append([]*Signer{previous}, chain...)
+		current = previous
+	}
+	return chain
+}
+
+// CheckDelegation checks that signer can hand the request over to the
+// replacement: the request is still open, the signer has neither signed nor
+// delegated, the replacement is not a signer of the request already and the
+// position was delegated less than MaxDelegations times
+func CheckDelegation(req *SignatureRequest, signer *Signer, input DelegationInput, now time.Time) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return ErrInvalidReplacement
+	}
+	if _, err := mail.ParseAddress(input.Email); err != nil {
+		return ErrInvalidReplacement
+	}
+
+	open := (req.Status == RequestStatusPending || req.Status == RequestStatusInProgress) &&
+		now.Before(req.ExpiresAt)
+	if !open || !signer.Active() || signer.Status == SignerStatusSigned || signer.Status == SignerStatusExpired {
+		return ErrCannotDelegate
+	}
+
+	for _, s := range req.Signers {
+		if s.Active() && strings.EqualFold(strings.TrimSpace(s.Email), strings.TrimSpace(input.Email)) {
+			return ErrDelegateIsSigner
+		}
+	}
+
+	if len(DelegationChain(req.Signers, signer)) > MaxDelegations {
+		return ErrDelegationLimit
+	}
+	return nil
+}
+
+// DelegateSigner replaces a signer of a request of the tenant on behalf of
+// the request's creator or another user of the tenant
+func (s *Service) DelegateSigner(ctx context.Context, tenantID, requestID, signerID, userID uuid.UUID, input DelegationInput) (*Signer, error) {
+	req, err := s.repo.GetRequestWithSigners(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if req.TenantID != tenantID {
+		return nil, ErrRequestNotFound
+	}
+
+	for _, signer := range req.Signers {
+		if signer.ID == signerID {
+			return s.delegate(ctx, req, signer, input, "user", userID.String(), "", "")
+		}
+	}
+	return nil, ErrSignerNotFound
+}
+
+// DelegateBySigner lets the signer of a status token hand over to a
+// replacement
+func (s *Service) DelegateBySigner(ctx context.Context, statusToken string, input DelegationInput, ip, userAgent string) (*Signer, error) {
+	signer, err := s.repo.GetSignerByStatusToken(ctx, statusToken)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.repo.GetRequestWithSigners(ctx, signer.SignatureRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if !tenant.ServesTenant(ctx, req.TenantID) {
+		return nil, ErrInvalidToken
+	}
+
+	return s.delegate(ctx, req, signer, input, "signer", signer.Email, ip, userAgent)
+}
+
+// delegate replaces signer and notifies the replacement right away if the
+// signer had already been notified, i.e. it is their turn
+func (s *Service) delegate(ctx context.Context, req *SignatureRequest, signer *Signer, input DelegationInput, actorType, actorID, ip, userAgent string) (*Signer, error) {
+	input.Email = strings.TrimSpace(input.Email)
+	input.Name = strings.TrimSpace(input.Name)
+	input.Reason = strings.TrimSpace(input.Reason)
+
+	now := time.Now()
+	if err := CheckDelegation(req, signer, input, now); err != nil {
+		return nil, err
+	}
+
+	expiresAt := now.Add(s.config.SigningLinkExpiry())
+	if req.ExpiresAt.Before(expiresAt) {
+		expiresAt = req.ExpiresAt
+	}
+	replacement := &Signer{Email: input.Email, Name: input.Name, TokenExpiresAt: expiresAt}
+	var reason *string
+	if input.Reason != "" {
+		reason = &input.Reason
+	}
+
+	if err := s.repo.DelegateSigner(ctx, signer, replacement, reason); err != nil {
+		return nil, err
+	}
+
+	chain := DelegationChain(req.Signers, signer)
+	emails := make([]string, 0, len(chain)+1)
+	for _, c := range chain {
+		emails = append(emails, c.Email)
+	}
+	emails = append(emails, replacement.Email)
+
+	s.createAuditEvent(ctx, req.TenantID, &req.ID, &signer.ID, nil, nil, AuditEventSignerDelegated,
+		map[string]interface{}{
+			"from_email":       signer.Email,
+			"to_signer_id":     replacement.ID,
+			"to_email":         replacement.Email,
+			"to_name":          replacement.Name,
+			"order_index":      replacement.OrderIndex,
+			"reason":           input.Reason,
+			"delegation_chain": emails,
+		}, actorType, actorID, ip, userAgent)
+
+	if signer.Status == SignerStatusPending {
+		// The replacement is notified when it is their turn
+		return replacement, nil
+	}
+
+	if s.email != nil {
+		message := ""
+		if req.Message != nil {
+			message = *req.Message
+		}
+		docTitle := req.DocumentTitle
+		if req.Name != nil && *req.Name != "" {
+			docTitle = *req.Name
+		}
+		if err := s.email.SendSignatureRequest(ctx, replacement.Email, replacement.Name,
+			s.signingURL(ctx, req.TenantID, replacement), s.statusURL(ctx, req.TenantID, replacement),
+			docTitle, message, req.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to send notification to %s: %w", replacement.Email, err)
+		}
+	}
+
+	if err := s.repo.MarkSignerNotified(ctx, replacement.ID); err != nil {
+		return nil, fmt.Errorf("failed to mark signer as notified: %w", err)
+	}
+	replacement.Status = SignerStatusNotified
+
+	s.createAuditEvent(ctx, req.TenantID, &req.ID, &replacement.ID, nil, nil, AuditEventSignerNotified,
+		map[string]interface{}{"email": replacement.Email}, "system", "", "", "")
+
+	return replacement, nil
+}
@@ -2,11 +2,15 @@ package signature
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/filetype"
+	"austrian-business-infrastructure/internal/sigbilling"
 )
 
 // Handler provides HTTP handlers for signature operations
@@ -93,6 +97,10 @@ type SignerResponse struct {
 	CertificateIssuer  string     `json:"certificate_issuer,omitempty"`
 	ReminderCount      int        `json:"reminder_count"`
 	LastReminderAt     *time.Time `json:"last_reminder_at,omitempty"`
+	DelegatedFromID    string     `json:"delegated_from_id,omitempty"`
+	DelegatedToID      string     `json:"delegated_to_id,omitempty"`
+	DelegatedAt        *time.Time `json:"delegated_at,omitempty"`
+	DelegationReason   string     `json:"delegation_reason,omitempty"`
 }
 
 // FieldResponse is a field in a response
@@ -120,8 +128,9 @@ type ListResponse struct {
 
 // SigningInfoResponse is the response for signing info
 type SigningInfoResponse struct {
-	Request *RequestResponse `json:"request"`
-	Signer  *SignerResponse  `json:"signer"`
+	Request  *RequestResponse `json:"request"`
+	Signer   *SignerResponse  `json:"signer"`
+	Branding *PageBranding    `json:"branding,omitempty"`
 }
 
 // ===== Handlers =====
@@ -191,6 +200,14 @@ func (h *Handler) CreateRequest(w http.ResponseWriter, r *http.Request) {
 
 	req, err := h.service.CreateRequest(r.Context(), input)
 	if err != nil {
+		if errors.Is(err, sigbilling.ErrCreditsExhausted) {
+			writeError(w, http.StatusPaymentRequired, err.Error())
+			return
+		}
+		if errors.Is(err, ErrDocumentNotSignable) {
+			writeError(w, filetype.HTTPStatus(err), err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -386,6 +403,10 @@ func (h *Handler) CreateFromTemplate(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, "template not found")
 			return
 		}
+		if errors.Is(err, sigbilling.ErrCreditsExhausted) {
+			writeError(w, http.StatusPaymentRequired, err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -421,8 +442,9 @@ func (h *Handler) GetSigningInfo(w http.ResponseWriter, r *http.Request) {
 	}
 
 	writeJSON(w, http.StatusOK, SigningInfoResponse{
-		Request: toRequestResponse(req),
-		Signer:  toSignerResponse(signer),
+		Request:  toRequestResponse(req),
+		Signer:   toSignerResponse(signer),
+		Branding: h.service.pageBranding(r.Context(), req.TenantID),
 	})
 }
 
@@ -483,6 +505,129 @@ func (h *Handler) SigningCallback(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, successURL, http.StatusFound)
 }
 
+// GetSignerStatus handles GET /api/v1/sign-status/{token}
+func (h *Handler) GetSignerStatus(w http.ResponseWriter, r *http.Request) {
+	token := getPathParam(r, "token")
+	if token == "" {
+		writeError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	page, err := h.service.GetSignerStatus(r.Context(), token)
+	if err != nil {
+		if err == ErrInvalidToken {
+			writeError(w, http.StatusNotFound, "invalid or expired status link")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// RequestSigningLink handles POST /api/v1/sign-status/{token}/link
+func (h *Handler) RequestSigningLink(w http.ResponseWriter, r *http.Request) {
+	token := getPathParam(r, "token")
+	if token == "" {
+		writeError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	err := h.service.RequestSigningLink(r.Context(), token, getClientIP(r), r.UserAgent())
+	if err != nil {
+		switch err {
+		case ErrInvalidToken:
+			writeError(w, http.StatusNotFound, "invalid or expired status link")
+		case ErrLinkNotAvailable:
+			writeError(w, http.StatusConflict, err.Error())
+		case ErrLinkRequestLimit:
+			writeError(w, http.StatusTooManyRequests, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"message": "a new signing link has been sent to your email address"})
+}
+
+// DelegateSigner handles POST /api/v1/signatures/{id}/signers/{signerId}/delegate
+func (h *Handler) DelegateSigner(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := getContextIDs(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(getPathParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request id")
+		return
+	}
+	signerID, err := uuid.Parse(getPathParam(r, "signerId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid signer id")
+		return
+	}
+
+	var input DelegationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	replacement, err := h.service.DelegateSigner(r.Context(), tenantID, id, signerID, userID, input)
+	if err != nil {
+		writeDelegationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toSignerResponse(replacement))
+}
+
+// DelegateBySigner handles POST /api/v1/sign-status/{token}/delegate
+func (h *Handler) DelegateBySigner(w http.ResponseWriter, r *http.Request) {
+	token := getPathParam(r, "token")
+	if token == "" {
+		writeError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	var input DelegationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if _, err := h.service.DelegateBySigner(r.Context(), token, input, getClientIP(r), r.UserAgent()); err != nil {
+		writeDelegationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"message": "the request has been handed over to " + input.Name})
+}
+
+// writeDelegationError maps the errors of a delegation to a response
+func writeDelegationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidToken):
+		writeError(w, http.StatusNotFound, "invalid or expired status link")
+	case errors.Is(err, ErrRequestNotFound):
+		writeError(w, http.StatusNotFound, "request not found")
+	case errors.Is(err, ErrSignerNotFound):
+		writeError(w, http.StatusNotFound, "signer not found")
+	case errors.Is(err, ErrInvalidReplacement):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrCannotDelegate), errors.Is(err, ErrDelegateIsSigner):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrDelegationLimit):
+		writeError(w, http.StatusTooManyRequests, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
 // ===== Helper Functions =====
 
 func toRequestResponse(req *SignatureRequest) *RequestResponse {
@@ -544,6 +689,16 @@ func toSignerResponse(signer *Signer) *SignerResponse {
 	if signer.CertificateIssuer != nil {
 		resp.CertificateIssuer = *signer.CertificateIssuer
 	}
+	if signer.DelegatedFromID != nil {
+		resp.DelegatedFromID = signer.DelegatedFromID.String()
+	}
+	if signer.DelegatedToID != nil {
+		resp.DelegatedToID = signer.DelegatedToID.String()
+		resp.DelegatedAt = signer.DelegatedAt
+	}
+	if signer.DelegationReason != nil {
+		resp.DelegationReason = *signer.DelegationReason
+	}
 
 	return resp
 }
@@ -11,6 +11,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"austrian-business-infrastructure/pkg/database"
 )
 
 var (
@@ -22,6 +24,13 @@ var (
 	ErrVerificationNotFound = errors.New("verification not found")
 	ErrInvalidToken         = errors.New("invalid or expired signing token")
 	ErrAlreadySigned        = errors.New("document already signed by this signer")
+	ErrLinkNotAvailable     = errors.New("no signing link can be requested for this signer")
+	ErrLinkRequestLimit     = errors.New("too many signing link requests")
+	ErrDocumentNotSignable  = errors.New("document cannot be signed")
+	ErrCannotDelegate       = errors.New("signer can no longer delegate")
+	ErrDelegateIsSigner     = errors.New("replacement is already a signer of this request")
+	ErrDelegationLimit      = errors.New("too many delegations for this signer")
+	ErrInvalidReplacement   = errors.New("replacement needs a name and a valid email address")
 )
 
 // Repository provides signature data access
@@ -58,26 +67,34 @@ func (r *Repository) CreateRequest(ctx context.Context, req *SignatureRequest) e
 	return err
 }
 
-// GetRequestByID retrieves a signature request by ID
-func (r *Repository) GetRequestByID(ctx context.Context, id uuid.UUID) (*SignatureRequest, error) {
-	query := `
+// requestSelect selects signature requests with their document title
+const requestSelect = `
 		SELECT sr.id, sr.tenant_id, sr.document_id, sr.name, sr.message, sr.expires_at,
 			sr.status, sr.completed_at, sr.is_sequential, sr.current_signer_index,
 			sr.signed_document_id, sr.created_by, sr.created_at, sr.updated_at,
 			d.title as document_title
 		FROM signature_requests sr
 		LEFT JOIN documents d ON sr.document_id = d.id
-		WHERE sr.id = $1
-	`
+`
 
+// scanRequest scans a row of requestSelect
+func scanRequest(row pgx.Row) (*SignatureRequest, error) {
 	req := &SignatureRequest{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := row.Scan(
 		&req.ID, &req.TenantID, &req.DocumentID, &req.Name, &req.Message, &req.ExpiresAt,
 		&req.Status, &req.CompletedAt, &req.IsSequential, &req.CurrentSignerIdx,
 		&req.SignedDocumentID, &req.CreatedBy, &req.CreatedAt, &req.UpdatedAt,
 		&req.DocumentTitle,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
 
+// GetRequestByID retrieves a signature request by ID
+func (r *Repository) GetRequestByID(ctx context.Context, id uuid.UUID) (*SignatureRequest, error) {
+	req, err := scanRequest(r.pool.QueryRow(ctx, requestSelect+` WHERE sr.id = $1`, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrRequestNotFound
@@ -88,40 +105,105 @@ func (r *Repository) GetRequestByID(ctx context.Context, id uuid.UUID) (*Signatu
 	return req, nil
 }
 
-// GetRequestWithSigners retrieves a request with all its signers
+// GetRequestWithSigners retrieves a request with all its signers and fields
+// in a single round trip
 func (r *Repository) GetRequestWithSigners(ctx context.Context, id uuid.UUID) (*SignatureRequest, error) {
-	req, err := r.GetRequestByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
+	var req *SignatureRequest
+	var signers []*Signer
+	var fields []*Field
 
-	signers, err := r.ListSignersByRequest(ctx, id)
+	err := database.RunBatch(ctx, r.pool,
+		database.BatchQuery{
+			SQL:  requestSelect + ` WHERE sr.id = $1`,
+			Args: []any{id},
+			Scan: func(rows pgx.Rows) error {
+				if !rows.Next() {
+					return rows.Err()
+				}
+				var err error
+				req, err = scanRequest(rows)
+				return err
+			},
+		},
+		database.BatchQuery{
+			SQL:  signerSelect + ` WHERE signature_request_id = $1 ORDER BY order_index ASC, created_at ASC`,
+			Args: []any{id},
+			Scan: func(rows pgx.Rows) (err error) {
+				signers, err = scanSigners(rows)
+				return err
+			},
+		},
+		database.BatchQuery{
+			SQL:  fieldSelect + ` WHERE signature_request_id = $1 ORDER BY page ASC, y DESC`,
+			Args: []any{id},
+			Scan: func(rows pgx.Rows) (err error) {
+				fields, err = scanFields(rows)
+				return err
+			},
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
+	if req == nil {
+		return nil, ErrRequestNotFound
+	}
+
 	req.Signers = signers
+	req.Fields = fields
+	return req, nil
+}
+
+// LoadSignersAndFields attaches the signers and fields to each request with
+// one round trip for all of them, instead of one GetRequestWithSigners per
+// request
+func (r *Repository) LoadSignersAndFields(ctx context.Context, requests []*SignatureRequest) error {
+	ids := make([]uuid.UUID, len(requests))
+	for i, req := range requests {
+		ids[i] = req.ID
+	}
+	ids = database.UniqueIDs(ids)
+	if len(ids) == 0 {
+		return nil
+	}
 
-	fields, err := r.ListFieldsByRequest(ctx, id)
+	var signers []*Signer
+	var fields []*Field
+	err := database.RunBatch(ctx, r.pool,
+		database.BatchQuery{
+			SQL:  signerSelect + ` WHERE signature_request_id = ANY($1) ORDER BY signature_request_id, order_index ASC, created_at ASC`,
+			Args: []any{ids},
+			Scan: func(rows pgx.Rows) (err error) {
+				signers, err = scanSigners(rows)
+				return err
+			},
+		},
+		database.BatchQuery{
+			SQL:  fieldSelect + ` WHERE signature_request_id = ANY($1) ORDER BY signature_request_id, page ASC, y DESC`,
+			Args: []any{ids},
+			Scan: func(rows pgx.Rows) (err error) {
+				fields, err = scanFields(rows)
+				return err
+			},
+		},
+	)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	req.Fields = fields
 
-	return req, nil
+	signersByRequest := database.GroupBy(signers, func(s *Signer) uuid.UUID { return s.SignatureRequestID })
+	fieldsByRequest := database.GroupBy(fields, func(f *Field) uuid.UUID { return f.SignatureRequestID })
+	for _, req := range requests {
+		req.Signers = signersByRequest[req.ID]
+		req.Fields = fieldsByRequest[req.ID]
+	}
+	return nil
 }
 
 // ListRequestsByTenant lists signature requests for a tenant
 func (r *Repository) ListRequestsByTenant(ctx context.Context, tenantID uuid.UUID, status *RequestStatus, limit, offset int) ([]*SignatureRequest, int, error) {
 	countQuery := `SELECT COUNT(*) FROM signature_requests WHERE tenant_id = $1`
-	listQuery := `
-		SELECT sr.id, sr.tenant_id, sr.document_id, sr.name, sr.message, sr.expires_at,
-			sr.status, sr.completed_at, sr.is_sequential, sr.current_signer_index,
-			sr.signed_document_id, sr.created_by, sr.created_at, sr.updated_at,
-			d.title as document_title
-		FROM signature_requests sr
-		LEFT JOIN documents d ON sr.document_id = d.id
-		WHERE sr.tenant_id = $1
-	`
+	listQuery := requestSelect + ` WHERE sr.tenant_id = $1`
 
 	args := []interface{}{tenantID}
 	argNum := 2
@@ -157,13 +239,7 @@ func (r *Repository) ListRequestsByTenant(ctx context.Context, tenantID uuid.UUI
 
 	var requests []*SignatureRequest
 	for rows.Next() {
-		req := &SignatureRequest{}
-		err := rows.Scan(
-			&req.ID, &req.TenantID, &req.DocumentID, &req.Name, &req.Message, &req.ExpiresAt,
-			&req.Status, &req.CompletedAt, &req.IsSequential, &req.CurrentSignerIdx,
-			&req.SignedDocumentID, &req.CreatedBy, &req.CreatedAt, &req.UpdatedAt,
-			&req.DocumentTitle,
-		)
+		req, err := scanRequest(rows)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -238,43 +314,63 @@ func (r *Repository) CreateSigner(ctx context.Context, signer *Signer) error {
 	signer.SigningToken = token
 	signer.TokenExpiresAt = time.Now().Add(14 * 24 * time.Hour) // 14 days
 
+	// Generate status page token
+	statusToken, err := generateSecureToken(32)
+	if err != nil {
+		return err
+	}
+	signer.StatusToken = statusToken
+
 	query := `
 		INSERT INTO signers (
 			id, signature_request_id, email, name, order_index,
-			signing_token, token_expires_at
+			signing_token, token_expires_at, status_token
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING status, created_at
 	`
 
 	err = r.pool.QueryRow(ctx, query,
 		signer.ID, signer.SignatureRequestID, signer.Email, signer.Name,
-		signer.OrderIndex, signer.SigningToken, signer.TokenExpiresAt,
+		signer.OrderIndex, signer.SigningToken, signer.TokenExpiresAt, signer.StatusToken,
 	).Scan(&signer.Status, &signer.CreatedAt)
 
 	return err
 }
 
-// GetSignerByID retrieves a signer by ID
-func (r *Repository) GetSignerByID(ctx context.Context, id uuid.UUID) (*Signer, error) {
-	query := `
+// signerSecretSelect selects signers including their tokens
+const signerSecretSelect = `
 		SELECT id, signature_request_id, email, name, order_index,
-			signing_token, token_expires_at, token_used, status, notified_at,
+			signing_token, token_expires_at, token_used, status_token, status, notified_at,
 			signed_at, certificate_subject, certificate_serial, certificate_issuer,
 			signature_value, idaustria_subject, idaustria_bpk, reminder_count,
-			last_reminder_at, created_at
-		FROM signers WHERE id = $1
-	`
+			last_reminder_at, link_request_count, last_link_request_at,
+			delegated_from_id, delegated_to_id, delegated_at, delegation_reason, created_at
+		FROM signers
+`
 
+// scanSignerSecret scans a row of signerSecretSelect
+func scanSignerSecret(row pgx.Row) (*Signer, error) {
 	signer := &Signer{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := row.Scan(
 		&signer.ID, &signer.SignatureRequestID, &signer.Email, &signer.Name, &signer.OrderIndex,
-		&signer.SigningToken, &signer.TokenExpiresAt, &signer.TokenUsed, &signer.Status,
+		&signer.SigningToken, &signer.TokenExpiresAt, &signer.TokenUsed, &signer.StatusToken, &signer.Status,
 		&signer.NotifiedAt, &signer.SignedAt, &signer.CertificateSubject, &signer.CertificateSerial,
 		&signer.CertificateIssuer, &signer.SignatureValue, &signer.IDAustriaSubject,
-		&signer.IDAustriaBPK, &signer.ReminderCount, &signer.LastReminderAt, &signer.CreatedAt,
+		&signer.IDAustriaBPK, &signer.ReminderCount, &signer.LastReminderAt,
+		&signer.LinkRequestCount, &signer.LastLinkRequestAt,
+		&signer.DelegatedFromID, &signer.DelegatedToID, &signer.DelegatedAt, &signer.DelegationReason,
+		&signer.CreatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
 
+// GetSignerByID retrieves a signer by ID
+func (r *Repository) GetSignerByID(ctx context.Context, id uuid.UUID) (*Signer, error) {
+	signer, err := scanSignerSecret(r.pool.QueryRow(ctx, signerSecretSelect+` WHERE id = $1`, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrSignerNotFound
@@ -287,25 +383,29 @@ func (r *Repository) GetSignerByID(ctx context.Context, id uuid.UUID) (*Signer,
 
 // GetSignerByToken retrieves a signer by their signing token
 func (r *Repository) GetSignerByToken(ctx context.Context, token string) (*Signer, error) {
-	query := `
-		SELECT id, signature_request_id, email, name, order_index,
-			signing_token, token_expires_at, token_used, status, notified_at,
-			signed_at, certificate_subject, certificate_serial, certificate_issuer,
-			signature_value, idaustria_subject, idaustria_bpk, reminder_count,
-			last_reminder_at, created_at
-		FROM signers
-		WHERE signing_token = $1 AND NOT token_used AND token_expires_at > NOW()
-	`
+	signer, err := scanSignerSecret(r.pool.QueryRow(ctx,
+		signerSecretSelect+` WHERE signing_token = $1 AND NOT token_used AND token_expires_at > NOW()`, token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
 
-	signer := &Signer{}
-	err := r.pool.QueryRow(ctx, query, token).Scan(
-		&signer.ID, &signer.SignatureRequestID, &signer.Email, &signer.Name, &signer.OrderIndex,
-		&signer.SigningToken, &signer.TokenExpiresAt, &signer.TokenUsed, &signer.Status,
-		&signer.NotifiedAt, &signer.SignedAt, &signer.CertificateSubject, &signer.CertificateSerial,
-		&signer.CertificateIssuer, &signer.SignatureValue, &signer.IDAustriaSubject,
-		&signer.IDAustriaBPK, &signer.ReminderCount, &signer.LastReminderAt, &signer.CreatedAt,
-	)
+	return signer, nil
+}
 
+// GetSignerByStatusToken retrieves a signer by their status token. The token
+// is valid until StatusPageRetention after the request was completed or expired.
+func (r *Repository) GetSignerByStatusToken(ctx context.Context, token string) (*Signer, error) {
+	signer, err := scanSignerSecret(r.pool.QueryRow(ctx, signerSecretSelect+`
+		WHERE status_token = $1
+		  AND EXISTS (
+			SELECT 1 FROM signature_requests sr
+			WHERE sr.id = signers.signature_request_id
+			  AND COALESCE(sr.completed_at, sr.expires_at) > $2
+		  )
+	`, token, time.Now().Add(-StatusPageRetention)))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrInvalidToken
@@ -316,23 +416,60 @@ func (r *Repository) GetSignerByToken(ctx context.Context, token string) (*Signe
 	return signer, nil
 }
 
-// ListSignersByRequest lists all signers for a request
-func (r *Repository) ListSignersByRequest(ctx context.Context, requestID uuid.UUID) ([]*Signer, error) {
+// RotateSigningToken replaces a signer's signing token so that earlier links
+// stop working, and counts the request. It fails with ErrLinkRequestLimit when
+// the signer already requested MaxLinkRequests links or the last one after
+// notBefore.
+func (r *Repository) RotateSigningToken(ctx context.Context, id uuid.UUID, token string, expiresAt, notBefore time.Time) error {
 	query := `
-		SELECT id, signature_request_id, email, name, order_index,
-			status, notified_at, signed_at, certificate_subject, certificate_serial,
-			certificate_issuer, reminder_count, last_reminder_at, created_at
-		FROM signers
-		WHERE signature_request_id = $1
-		ORDER BY order_index ASC
+		UPDATE signers
+		SET signing_token = $2, token_expires_at = $3, token_used = FALSE,
+			link_request_count = link_request_count + 1, last_link_request_at = NOW()
+		WHERE id = $1 AND status NOT IN ('signed', 'delegated') AND link_request_count < $4
+		  AND (last_link_request_at IS NULL OR last_link_request_at < $5)
 	`
+	result, err := r.pool.Exec(ctx, query, id, token, expiresAt, MaxLinkRequests, notBefore)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrLinkRequestLimit
+	}
+	return nil
+}
 
-	rows, err := r.pool.Query(ctx, query, requestID)
+// GetRequestContact returns who external signers can contact about a request:
+// its creator and the tenant
+func (r *Repository) GetRequestContact(ctx context.Context, requestID uuid.UUID) (*StatusContact, error) {
+	query := `
+		SELECT COALESCE(u.name, ''), COALESCE(u.email, ''), t.name
+		FROM signature_requests sr
+		JOIN tenants t ON t.id = sr.tenant_id
+		LEFT JOIN users u ON u.id = sr.created_by
+		WHERE sr.id = $1
+	`
+	contact := &StatusContact{}
+	err := r.pool.QueryRow(ctx, query, requestID).Scan(&contact.Name, &contact.Email, &contact.Company)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRequestNotFound
+		}
 		return nil, err
 	}
-	defer rows.Close()
+	return contact, nil
+}
 
+// signerSelect selects signers without their signing secrets
+const signerSelect = `
+		SELECT id, signature_request_id, email, name, order_index,
+			status, notified_at, signed_at, certificate_subject, certificate_serial,
+			certificate_issuer, reminder_count, last_reminder_at,
+			delegated_from_id, delegated_to_id, delegated_at, delegation_reason, created_at
+		FROM signers
+`
+
+// scanSigners scans the rows of signerSelect
+func scanSigners(rows pgx.Rows) ([]*Signer, error) {
 	var signers []*Signer
 	for rows.Next() {
 		signer := &Signer{}
@@ -340,17 +477,29 @@ func (r *Repository) ListSignersByRequest(ctx context.Context, requestID uuid.UU
 			&signer.ID, &signer.SignatureRequestID, &signer.Email, &signer.Name,
 			&signer.OrderIndex, &signer.Status, &signer.NotifiedAt, &signer.SignedAt,
 			&signer.CertificateSubject, &signer.CertificateSerial, &signer.CertificateIssuer,
-			&signer.ReminderCount, &signer.LastReminderAt, &signer.CreatedAt,
+			&signer.ReminderCount, &signer.LastReminderAt,
+			&signer.DelegatedFromID, &signer.DelegatedToID, &signer.DelegatedAt, &signer.DelegationReason,
+			&signer.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
 		signers = append(signers, signer)
 	}
-
 	return signers, rows.Err()
 }
 
+// ListSignersByRequest lists all signers for a request
+func (r *Repository) ListSignersByRequest(ctx context.Context, requestID uuid.UUID) ([]*Signer, error) {
+	rows, err := r.pool.Query(ctx, signerSelect+` WHERE signature_request_id = $1 ORDER BY order_index ASC, created_at ASC`, requestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSigners(rows)
+}
+
 // UpdateSignerStatus updates a signer's status
 func (r *Repository) UpdateSignerStatus(ctx context.Context, id uuid.UUID, status SignerStatus) error {
 	query := `UPDATE signers SET status = $2 WHERE id = $1`
@@ -378,7 +527,7 @@ func (r *Repository) MarkSignerSigned(ctx context.Context, id uuid.UUID, certSub
 		SET status = 'signed', signed_at = NOW(), token_used = TRUE,
 			certificate_subject = $2, certificate_serial = $3, certificate_issuer = $4,
 			signature_value = $5, idaustria_subject = $6, idaustria_bpk = $7
-		WHERE id = $1
+		WHERE id = $1 AND status <> 'delegated'
 	`
 	result, err := r.pool.Exec(ctx, query, id, certSubject, certSerial, certIssuer, signatureValue, idSubject, bpkHash)
 	if err != nil {
@@ -390,6 +539,68 @@ func (r *Repository) MarkSignerSigned(ctx context.Context, id uuid.UUID, certSub
 	return nil
 }
 
+// DelegateSigner replaces a signer with the replacement in one transaction.
+// The replacement gets new tokens, the signer's order_index and fields; the
+// signer's signing token stops working. It fails with ErrCannotDelegate when
+// the signer has signed or delegated in the meantime.
+func (r *Repository) DelegateSigner(ctx context.Context, signer, replacement *Signer, reason *string) error {
+	if replacement.ID == uuid.Nil {
+		replacement.ID = uuid.New()
+	}
+	token, err := generateSecureToken(32)
+	if err != nil {
+		return err
+	}
+	statusToken, err := generateSecureToken(32)
+	if err != nil {
+		return err
+	}
+	replacement.SigningToken = token
+	replacement.StatusToken = statusToken
+	replacement.SignatureRequestID = signer.SignatureRequestID
+	replacement.OrderIndex = signer.OrderIndex
+	replacement.DelegatedFromID = &signer.ID
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO signers (
+			id, signature_request_id, email, name, order_index,
+			signing_token, token_expires_at, status_token, delegated_from_id
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING status, created_at
+	`, replacement.ID, replacement.SignatureRequestID, replacement.Email, replacement.Name, replacement.OrderIndex,
+		replacement.SigningToken, replacement.TokenExpiresAt, replacement.StatusToken, replacement.DelegatedFromID,
+	).Scan(&replacement.Status, &replacement.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE signers
+		SET status = 'delegated', token_used = TRUE, delegated_to_id = $2,
+			delegated_at = NOW(), delegation_reason = $3
+		WHERE id = $1 AND status NOT IN ('signed', 'delegated', 'expired')
+	`, signer.ID, replacement.ID, reason)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrCannotDelegate
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE signature_fields SET signer_id = $2 WHERE signer_id = $1`, signer.ID, replacement.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
 // UpdateReminderSent updates reminder tracking for a signer
 func (r *Repository) UpdateReminderSent(ctx context.Context, id uuid.UUID) error {
 	query := `
@@ -451,22 +662,15 @@ func (r *Repository) GetFieldByID(ctx context.Context, id uuid.UUID) (*Field, er
 	return field, nil
 }
 
-// ListFieldsByRequest lists all fields for a request
-func (r *Repository) ListFieldsByRequest(ctx context.Context, requestID uuid.UUID) ([]*Field, error) {
-	query := `
+// fieldSelect selects signature fields
+const fieldSelect = `
 		SELECT id, signature_request_id, signer_id, page, x, y, width, height,
 			show_name, show_date, show_reason, reason, background_image_url, created_at
 		FROM signature_fields
-		WHERE signature_request_id = $1
-		ORDER BY page ASC, y DESC
-	`
-
-	rows, err := r.pool.Query(ctx, query, requestID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+`
 
+// scanFields scans the rows of fieldSelect
+func scanFields(rows pgx.Rows) ([]*Field, error) {
 	var fields []*Field
 	for rows.Next() {
 		field := &Field{}
@@ -481,10 +685,20 @@ func (r *Repository) ListFieldsByRequest(ctx context.Context, requestID uuid.UUI
 		}
 		fields = append(fields, field)
 	}
-
 	return fields, rows.Err()
 }
 
+// ListFieldsByRequest lists all fields for a request
+func (r *Repository) ListFieldsByRequest(ctx context.Context, requestID uuid.UUID) ([]*Field, error) {
+	rows, err := r.pool.Query(ctx, fieldSelect+` WHERE signature_request_id = $1 ORDER BY page ASC, y DESC`, requestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanFields(rows)
+}
+
 // UpdateField updates a signature field
 func (r *Repository) UpdateField(ctx context.Context, field *Field) error {
 	query := `
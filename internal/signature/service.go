@@ -10,13 +10,15 @@ import (
 
 	"austrian-business-infrastructure/internal/atrust"
 	"austrian-business-infrastructure/internal/config"
+	"austrian-business-infrastructure/internal/filetype"
 	"austrian-business-infrastructure/internal/idaustria"
+	"austrian-business-infrastructure/internal/tenant"
 )
 
 // EmailSender interface for sending emails
 type EmailSender interface {
-	SendSignatureRequest(ctx context.Context, to, name, signingURL, documentTitle, message string, expiresAt time.Time) error
-	SendSignatureReminder(ctx context.Context, to, name, signingURL, documentTitle string, daysLeft int) error
+	SendSignatureRequest(ctx context.Context, to, name, signingURL, statusURL, documentTitle, message string, expiresAt time.Time) error
+	SendSignatureReminder(ctx context.Context, to, name, signingURL, statusURL, documentTitle string, daysLeft int) error
 	SendSignatureComplete(ctx context.Context, to, name, documentTitle string, signerCount int) error
 }
 
@@ -26,6 +28,15 @@ type DocumentStore interface {
 	StoreSignedDocument(ctx context.Context, tenantID, originalDocID uuid.UUID, content []byte, title string) (uuid.UUID, error)
 }
 
+// Billing enforces prepaid signature credits; sigbilling.Service implements it
+type Billing interface {
+	// CheckCredits returns an error if the tenant may not request n more
+	// signatures
+	CheckCredits(ctx context.Context, tenantID uuid.UUID, n int) error
+	// ConsumeCredits debits the signatures of a recorded usage
+	ConsumeCredits(ctx context.Context, tenantID, usageID uuid.UUID, signatures int) error
+}
+
 // Service provides signature business logic
 type Service struct {
 	repo       *Repository
@@ -34,6 +45,8 @@ type Service struct {
 	idaustria  *idaustria.Client
 	email      EmailSender
 	documents  DocumentStore
+	branding   Branding
+	billing    Billing
 }
 
 // NewService creates a new signature service
@@ -55,6 +68,29 @@ func NewService(
 	}
 }
 
+// SetBilling enables signature credits: requests are refused once a
+// prepaid tenant's credits are exhausted, and recorded usage is debited
+func (s *Service) SetBilling(billing Billing) {
+	s.billing = billing
+}
+
+// checkCredits returns an error if the tenant may not request n more
+// signatures
+func (s *Service) checkCredits(ctx context.Context, tenantID uuid.UUID, n int) error {
+	if s.billing == nil {
+		return nil
+	}
+	return s.billing.CheckCredits(ctx, tenantID, n)
+}
+
+// recordUsage stores a usage and debits it from the tenant's credits
+func (s *Service) recordUsage(ctx context.Context, usage *Usage) {
+	if err := s.repo.RecordUsage(ctx, usage); err != nil || s.billing == nil {
+		return
+	}
+	s.billing.ConsumeCredits(ctx, usage.TenantID, usage.ID, usage.SignatureCount)
+}
+
 // CreateRequestInput contains the input for creating a signature request
 type CreateRequestInput struct {
 	TenantID     uuid.UUID
@@ -89,11 +125,30 @@ type FieldInput struct {
 	Reason      string
 }
 
+// checkDocument makes sure a document is a PDF within the signature limits
+// before a request for it is created, whatever its stored content type says
+func (s *Service) checkDocument(ctx context.Context, documentID uuid.UUID) error {
+	content, err := s.documents.GetDocumentContent(ctx, documentID)
+	if err != nil {
+		return fmt.Errorf("failed to get document: %w", err)
+	}
+	if _, err := filetype.Signature.CheckBytes("", content); err != nil {
+		return fmt.Errorf("%w: %w", ErrDocumentNotSignable, err)
+	}
+	return nil
+}
+
 // CreateRequest creates a new signature request
 func (s *Service) CreateRequest(ctx context.Context, input *CreateRequestInput) (*SignatureRequest, error) {
 	if len(input.Signers) == 0 {
 		return nil, fmt.Errorf("at least one signer is required")
 	}
+	if err := s.checkCredits(ctx, input.TenantID, len(input.Signers)); err != nil {
+		return nil, err
+	}
+	if err := s.checkDocument(ctx, input.DocumentID); err != nil {
+		return nil, err
+	}
 
 	// Default expiry
 	expiryDays := input.ExpiryDays
@@ -193,12 +248,16 @@ func (s *Service) NotifySigners(ctx context.Context, requestID uuid.UUID) error
 
 	// For sequential signing, only notify the first pending signer
 	// For parallel signing, notify all pending signers
-	for _, signer := range req.Signers {
-		if signer.Status != SignerStatusPending {
+	for _, listed := range req.Signers {
+		if listed.Status != SignerStatusPending {
 			continue
 		}
 
-		signingURL := fmt.Sprintf("%s/%s", s.config.PortalSigningBasePath, signer.SigningToken)
+		// Listed signers carry no tokens
+		signer, err := s.repo.GetSignerByID(ctx, listed.ID)
+		if err != nil {
+			return err
+		}
 
 		message := ""
 		if req.Message != nil {
@@ -215,7 +274,8 @@ func (s *Service) NotifySigners(ctx context.Context, requestID uuid.UUID) error
 				ctx,
 				signer.Email,
 				signer.Name,
-				signingURL,
+				s.signingURL(ctx, req.TenantID, signer),
+				s.statusURL(ctx, req.TenantID, signer),
 				docTitle,
 				message,
 				req.ExpiresAt,
@@ -257,7 +317,6 @@ func (s *Service) SendReminder(ctx context.Context, signerID uuid.UUID) error {
 	}
 
 	daysLeft := int(time.Until(req.ExpiresAt).Hours() / 24)
-	signingURL := fmt.Sprintf("%s/%s", s.config.PortalSigningBasePath, signer.SigningToken)
 
 	docTitle := req.DocumentTitle
 	if req.Name != nil && *req.Name != "" {
@@ -265,7 +324,7 @@ func (s *Service) SendReminder(ctx context.Context, signerID uuid.UUID) error {
 	}
 
 	if s.email != nil {
-		if err := s.email.SendSignatureReminder(ctx, signer.Email, signer.Name, signingURL, docTitle, daysLeft); err != nil {
+		if err := s.email.SendSignatureReminder(ctx, signer.Email, signer.Name, s.signingURL(ctx, req.TenantID, signer), s.statusURL(ctx, req.TenantID, signer), docTitle, daysLeft); err != nil {
 			return fmt.Errorf("failed to send reminder: %w", err)
 		}
 	}
@@ -291,6 +350,9 @@ func (s *Service) GetSigningInfo(ctx context.Context, token string) (*SignatureR
 	if err != nil {
 		return nil, nil, err
 	}
+	if !tenant.ServesTenant(ctx, req.TenantID) {
+		return nil, nil, ErrInvalidToken
+	}
 
 	if req.Status == RequestStatusExpired || req.Status == RequestStatusCancelled {
 		return nil, nil, fmt.Errorf("signature request is no longer available")
@@ -303,7 +365,7 @@ func (s *Service) GetSigningInfo(ctx context.Context, token string) (*SignatureR
 	// For sequential signing, check if it's this signer's turn
 	if req.IsSequential {
 		for _, s := range req.Signers {
-			if s.OrderIndex < signer.OrderIndex && s.Status != SignerStatusSigned {
+			if s.Active() && s.OrderIndex < signer.OrderIndex && s.Status != SignerStatusSigned {
 				return nil, nil, fmt.Errorf("waiting for previous signer")
 			}
 		}
@@ -323,6 +385,9 @@ func (s *Service) StartSigning(ctx context.Context, token string) (string, error
 	if err != nil {
 		return "", err
 	}
+	if !tenant.ServesTenant(ctx, req.TenantID) {
+		return "", ErrInvalidToken
+	}
 
 	// Update signer status
 	if err := s.repo.UpdateSignerStatus(ctx, signer.ID, SignerStatusSigning); err != nil {
@@ -400,6 +465,9 @@ func (s *Service) CompleteSigning(ctx context.Context, input *CompleteSigningInp
 	if err != nil {
 		return nil, err
 	}
+	if !signer.Active() {
+		return nil, ErrInvalidToken
+	}
 
 	req, err := s.repo.GetRequestByID(ctx, signer.SignatureRequestID)
 	if err != nil {
@@ -468,8 +536,13 @@ func (s *Service) CompleteSigning(ctx context.Context, input *CompleteSigningInp
 	}
 
 	allSigned := true
+	signatureCount := 0
 	nextSigner := (*Signer)(nil)
 	for _, s := range signers {
+		if !s.Active() {
+			continue
+		}
+		signatureCount++
 		if s.Status != SignerStatusSigned {
 			allSigned = false
 			if req.IsSequential && nextSigner == nil {
@@ -494,14 +567,14 @@ func (s *Service) CompleteSigning(ctx context.Context, input *CompleteSigningInp
 		usage := &Usage{
 			TenantID:           req.TenantID,
 			SignatureRequestID: &req.ID,
-			SignatureCount:     len(signers),
+			SignatureCount:     signatureCount,
 			UsageDate:          time.Now(),
 		}
 		if s.config.SignatureCostCents > 0 {
-			cost := s.config.SignatureCostCents * len(signers)
+			cost := s.config.SignatureCostCents * signatureCount
 			usage.CostCents = &cost
 		}
-		s.repo.RecordUsage(ctx, usage)
+		s.recordUsage(ctx, usage)
 
 		// Send completion notification
 		if s.email != nil {
@@ -0,0 +1,223 @@
+package signature
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/tenant"
+)
+
+// External signers reach their status page with the status token from the
+// signature emails. The page shows the progress of the request and their own
+// step, but never the names, emails or signatures of the other signers.
+const (
+	// StatusPageRetention is how long the status page stays available after
+	// a request was completed or expired
+	StatusPageRetention = 90 * 24 * time.Hour
+
+	// MaxLinkRequests is how many new signing links a signer can request
+	MaxLinkRequests = 5
+
+	// LinkRequestInterval is the minimum time between two link requests
+	LinkRequestInterval = 15 * time.Minute
+)
+
+// SignerStatusPage is the status of a signature request as one external
+// signer sees it
+type SignerStatusPage struct {
+	RequestName   string        `json:"request_name"`
+	DocumentTitle string        `json:"document_title,omitempty"`
+	Status        RequestStatus `json:"status"`
+	IsSequential  bool          `json:"is_sequential"`
+	ExpiresAt     time.Time     `json:"expires_at"`
+	CompletedAt   *time.Time    `json:"completed_at,omitempty"`
+	TotalSigners  int           `json:"total_signers"`
+	SignedCount   int           `json:"signed_count"`
+	Steps         []StatusStep  `json:"steps"`
+	You           SignerStep    `json:"you"`
+	Contact       StatusContact `json:"contact"`
+	Branding      *PageBranding `json:"branding,omitempty"`
+}
+
+// StatusStep is one anonymous signer position of a request
+type StatusStep struct {
+	Position int          `json:"position"` // 1-based signing order
+	Status   SignerStatus `json:"status"`   // pending, signed or expired for other signers
+	IsYou    bool         `json:"is_you"`
+}
+
+// SignerStep is the viewing signer's own step
+type SignerStep struct {
+	Name           string       `json:"name"`
+	Position       int          `json:"position"`
+	Status         SignerStatus `json:"status"`
+	SignedAt       *time.Time   `json:"signed_at,omitempty"`
+	YourTurn       bool         `json:"your_turn"`
+	WaitingFor     int          `json:"waiting_for"` // earlier signers who still have to sign
+	CanRequestLink bool         `json:"can_request_link"`
+}
+
+// StatusContact is who a signer can contact about a request
+type StatusContact struct {
+	Name    string `json:"name,omitempty"`
+	Email   string `json:"email,omitempty"`
+	Company string `json:"company,omitempty"`
+}
+
+// BuildStatusPage builds the status page of a request for one of its signers.
+// The request's signers must be ordered by order_index. Signers who delegated
+// are left out; a signer who delegated has no step and it is never their turn.
+func BuildStatusPage(req *SignatureRequest, signer *Signer, contact StatusContact, now time.Time) *SignerStatusPage {
+	page := &SignerStatusPage{
+		RequestName:   req.DocumentTitle,
+		DocumentTitle: req.DocumentTitle,
+		Status:        req.Status,
+		IsSequential:  req.IsSequential,
+		ExpiresAt:     req.ExpiresAt,
+		CompletedAt:   req.CompletedAt,
+		Steps:         make([]StatusStep, 0, len(req.Signers)),
+		Contact:       contact,
+	}
+	if req.Name != nil && *req.Name != "" {
+		page.RequestName = *req.Name
+	}
+
+	you := SignerStep{Name: signer.Name, Status: signer.Status, SignedAt: signer.SignedAt}
+	for _, s := range req.Signers {
+		if !s.Active() {
+			continue
+		}
+		step := StatusStep{Position: len(page.Steps) + 1, Status: publicSignerStatus(s.Status)}
+		if s.ID == signer.ID {
+			step.IsYou = true
+			step.Status = s.Status
+			you.Position = step.Position
+		}
+		page.Steps = append(page.Steps, step)
+
+		if s.Status == SignerStatusSigned {
+			page.SignedCount++
+		} else if req.IsSequential && s.OrderIndex < signer.OrderIndex {
+			you.WaitingFor++
+		}
+	}
+	page.TotalSigners = len(page.Steps)
+
+	open := (req.Status == RequestStatusPending || req.Status == RequestStatusInProgress) &&
+		now.Before(req.ExpiresAt)
+	you.YourTurn = open && you.WaitingFor == 0 && signer.Active() &&
+		signer.Status != SignerStatusSigned && signer.Status != SignerStatusExpired
+	you.CanRequestLink = you.YourTurn && signer.LinkRequestCount < MaxLinkRequests
+	page.You = you
+
+	return page
+}
+
+// publicSignerStatus reduces another signer's status to what the status
+// page shows about them
+func publicSignerStatus(status SignerStatus) SignerStatus {
+	switch status {
+	case SignerStatusSigned, SignerStatusExpired:
+		return status
+	default:
+		return SignerStatusPending
+	}
+}
+
+// GetSignerStatus returns the status page for a status token
+func (s *Service) GetSignerStatus(ctx context.Context, statusToken string) (*SignerStatusPage, error) {
+	signer, err := s.repo.GetSignerByStatusToken(ctx, statusToken)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.repo.GetRequestWithSigners(ctx, signer.SignatureRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if !tenant.ServesTenant(ctx, req.TenantID) {
+		return nil, ErrInvalidToken
+	}
+
+	contact, err := s.repo.GetRequestContact(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := BuildStatusPage(req, signer, *contact, time.Now())
+	page.Branding = s.pageBranding(ctx, req.TenantID)
+	return page, nil
+}
+
+// RequestSigningLink sends a signer a new signing link. The previous link
+// stops working. The link only goes to the signer's own email address.
+func (s *Service) RequestSigningLink(ctx context.Context, statusToken, ip, userAgent string) error {
+	if s.email == nil {
+		return fmt.Errorf("email delivery is not configured")
+	}
+
+	signer, err := s.repo.GetSignerByStatusToken(ctx, statusToken)
+	if err != nil {
+		return err
+	}
+
+	req, err := s.repo.GetRequestWithSigners(ctx, signer.SignatureRequestID)
+	if err != nil {
+		return err
+	}
+	if !tenant.ServesTenant(ctx, req.TenantID) {
+		return ErrInvalidToken
+	}
+
+	now := time.Now()
+	page := BuildStatusPage(req, signer, StatusContact{}, now)
+	if !page.You.YourTurn {
+		return ErrLinkNotAvailable
+	}
+	if !page.You.CanRequestLink {
+		return ErrLinkRequestLimit
+	}
+
+	token, err := generateSecureToken(32)
+	if err != nil {
+		return err
+	}
+	expiresAt := now.Add(s.config.SigningLinkExpiry())
+	if req.ExpiresAt.Before(expiresAt) {
+		expiresAt = req.ExpiresAt
+	}
+
+	if err := s.repo.RotateSigningToken(ctx, signer.ID, token, expiresAt, now.Add(-LinkRequestInterval)); err != nil {
+		return err
+	}
+	signer.SigningToken = token
+
+	if err := s.email.SendSignatureRequest(ctx, signer.Email, signer.Name, s.signingURL(ctx, req.TenantID, signer), s.statusURL(ctx, req.TenantID, signer),
+		page.RequestName, "", req.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to send signing link: %w", err)
+	}
+
+	if signer.Status == SignerStatusPending {
+		if err := s.repo.MarkSignerNotified(ctx, signer.ID); err != nil {
+			return fmt.Errorf("failed to mark signer as notified: %w", err)
+		}
+	}
+
+	s.createAuditEvent(ctx, req.TenantID, &req.ID, &signer.ID, nil, nil, AuditEventLinkRequested,
+		map[string]interface{}{"link_request_count": signer.LinkRequestCount + 1}, "signer", signer.Email, ip, userAgent)
+
+	return nil
+}
+
+// signingURL returns the portal link that starts signing
+func (s *Service) signingURL(ctx context.Context, tenantID uuid.UUID, signer *Signer) string {
+	return fmt.Sprintf("%s/%s", s.publicBaseURL(ctx, tenantID, s.config.PortalSigningBasePath), signer.SigningToken)
+}
+
+// statusURL returns the portal link to the signer's status page
+func (s *Service) statusURL(ctx context.Context, tenantID uuid.UUID, signer *Signer) string {
+	return fmt.Sprintf("%s/%s", s.publicBaseURL(ctx, tenantID, s.config.PortalSigningStatusBasePath), signer.StatusToken)
+}
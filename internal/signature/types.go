@@ -27,6 +27,8 @@ const (
 	SignerStatusSigning  SignerStatus = "signing"
 	SignerStatusSigned   SignerStatus = "signed"
 	SignerStatusExpired  SignerStatus = "expired"
+	// Delegated signers handed their signature over to a replacement signer
+	SignerStatusDelegated SignerStatus = "delegated"
 )
 
 // BatchStatus represents the status of a batch signing
@@ -93,6 +95,7 @@ type Signer struct {
 	SigningToken        string       `json:"-"` // Never expose token in JSON
 	TokenExpiresAt      time.Time    `json:"-"`
 	TokenUsed           bool         `json:"-"`
+	StatusToken         string       `json:"-"` // Opens the signer's status page
 	Status              SignerStatus `json:"status"`
 	NotifiedAt          *time.Time   `json:"notified_at,omitempty"`
 	SignedAt            *time.Time   `json:"signed_at,omitempty"`
@@ -104,9 +107,21 @@ type Signer struct {
 	IDAustriaBPK        *string      `json:"-"` // Never expose BPK
 	ReminderCount       int          `json:"reminder_count"`
 	LastReminderAt      *time.Time   `json:"last_reminder_at,omitempty"`
+	LinkRequestCount    int          `json:"link_request_count"`
+	LastLinkRequestAt   *time.Time   `json:"last_link_request_at,omitempty"`
+	DelegatedFromID     *uuid.UUID   `json:"delegated_from_id,omitempty"`
+	DelegatedToID       *uuid.UUID   `json:"delegated_to_id,omitempty"`
+	DelegatedAt         *time.Time   `json:"delegated_at,omitempty"`
+	DelegationReason    *string      `json:"delegation_reason,omitempty"`
 	CreatedAt           time.Time    `json:"created_at"`
 }
 
+// Active reports whether the signer still takes part in the request, i.e.
+// has not delegated their signature
+func (s *Signer) Active() bool {
+	return s.Status != SignerStatusDelegated
+}
+
 // Field represents a visual signature field placement on a PDF
 type Field struct {
 	ID                 uuid.UUID  `json:"id"`
@@ -285,4 +300,6 @@ const (
 	AuditEventBatchStarted        = "batch_started"
 	AuditEventBatchCompleted      = "batch_completed"
 	AuditEventVerificationDone    = "verification_performed"
+	AuditEventLinkRequested       = "signing_link_requested"
+	AuditEventSignerDelegated     = "signer_delegated"
 )
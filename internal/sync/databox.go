@@ -13,11 +13,11 @@ import (
 
 // DataboxFetcher fetches documents from FinanzOnline databox
 type DataboxFetcher struct {
-	client *fonws.Client
+	client fonws.Caller
 }
 
 // NewDataboxFetcher creates a new databox fetcher
-func NewDataboxFetcher(client *fonws.Client) *DataboxFetcher {
+func NewDataboxFetcher(client fonws.Caller) *DataboxFetcher {
 	return &DataboxFetcher{client: client}
 }
 
@@ -121,7 +121,7 @@ type Syncer struct {
 }
 
 // NewSyncer creates a new syncer
-func NewSyncer(client *fonws.Client, docService *document.Service, docRepo *document.Repository) *Syncer {
+func NewSyncer(client fonws.Caller, docService *document.Service, docRepo *document.Repository) *Syncer {
 	return &Syncer{
 		fetcher:    NewDataboxFetcher(client),
 		docService: docService,
@@ -243,6 +243,16 @@ func detectContentType(filename string) string {
 		return "text/html"
 	case "txt":
 		return "text/plain"
+	case "jpg", "jpeg":
+		return "image/jpeg"
+	case "png":
+		return "image/png"
+	case "heic":
+		return "image/heic"
+	case "docx":
+		return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	case "xlsx":
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 	default:
 		return "application/pdf" // Default for FO documents
 	}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"austrian-business-infrastructure/internal/api"
 	"github.com/google/uuid"
@@ -57,16 +58,16 @@ func toResponse(job *SyncJob) *SyncJobResponse {
 		DocumentsNew:     job.DocumentsNew,
 		DocumentsSkipped: job.DocumentsSkipped,
 		ErrorMessage:     job.ErrorMessage,
-		CreatedAt:        job.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		CreatedAt:        job.CreatedAt.UTC().Format(time.RFC3339),
 	}
 
 	if job.StartedAt != nil {
-		s := job.StartedAt.Format("2006-01-02T15:04:05Z")
+		s := job.StartedAt.UTC().Format(time.RFC3339)
 		resp.StartedAt = &s
 	}
 
 	if job.CompletedAt != nil {
-		s := job.CompletedAt.Format("2006-01-02T15:04:05Z")
+		s := job.CompletedAt.UTC().Format(time.RFC3339)
 		resp.CompletedAt = &s
 	}
 
@@ -19,7 +19,7 @@ type Service struct {
 	docService  *document.Service
 	docRepo     *document.Repository
 	accountRepo *account.Repository
-	fonwsClient *fonws.Client
+	fonwsClient fonws.Caller
 	logger      *slog.Logger
 
 	// Concurrency control
@@ -43,7 +43,7 @@ func NewService(
 	docService *document.Service,
 	docRepo *document.Repository,
 	accountRepo *account.Repository,
-	fonwsClient *fonws.Client,
+	fonwsClient fonws.Caller,
 	cfg *ServiceConfig,
 ) *Service {
 	maxConcurrent := 5
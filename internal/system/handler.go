@@ -1,11 +1,16 @@
 package system
 
 import (
+	"context"
 	"net/http"
 	"runtime"
+	"strconv"
 	"time"
 
+	"austrian-business-infrastructure/internal/ai"
 	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/matcher"
+	"austrian-business-infrastructure/pkg/database"
 )
 
 var (
@@ -19,9 +24,35 @@ var (
 
 var startTime = time.Now()
 
+// PoolMetricsSource provides database connection pool metrics
+type PoolMetricsSource interface {
+	Metrics() database.PoolMetrics
+}
+
+// AbuseMetricsSource provides the decisions of the anti-automation guard
+type AbuseMetricsSource interface {
+	Metrics() map[string]api.AbuseMetrics
+}
+
+// MatcherCacheSource provides the hits and misses of the Förderungssuche
+// result cache; matcher.Service implements it
+type MatcherCacheSource interface {
+	CacheStats() matcher.CacheStats
+}
+
+// AIRouteSource provides the cost and quality of AI completions per
+// operation and target; ai.RouteMetrics implements it
+type AIRouteSource interface {
+	Stats(ctx context.Context, since time.Time) ([]*ai.RouteStats, error)
+}
+
 // Handler handles system HTTP requests
 type Handler struct {
-	metrics *Metrics
+	metrics  *Metrics
+	db       PoolMetricsSource
+	abuse    AbuseMetricsSource
+	matcher  MatcherCacheSource
+	aiRoutes AIRouteSource
 }
 
 // NewHandler creates a new system handler
@@ -29,10 +60,40 @@ func NewHandler(metrics *Metrics) *Handler {
 	return &Handler{metrics: metrics}
 }
 
+// WithDatabase adds the connection pool metrics to GET /api/v1/system/metrics
+func (h *Handler) WithDatabase(db PoolMetricsSource) *Handler {
+	h.db = db
+	return h
+}
+
+// WithAbuseGuard adds the blocked attempts on public endpoints to
+// GET /api/v1/system/metrics
+func (h *Handler) WithAbuseGuard(abuse AbuseMetricsSource) *Handler {
+	h.abuse = abuse
+	return h
+}
+
+// WithMatcherCache adds the Förderungssuche result cache hits to
+// GET /api/v1/system/metrics
+func (h *Handler) WithMatcherCache(m MatcherCacheSource) *Handler {
+	h.matcher = m
+	return h
+}
+
+// WithAIRoutes adds GET /api/v1/system/ai/routes, the cost and quality of
+// AI completions per routing target
+func (h *Handler) WithAIRoutes(routes AIRouteSource) *Handler {
+	h.aiRoutes = routes
+	return h
+}
+
 // RegisterRoutes registers system routes
 func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
 	router.Handle("GET /api/v1/system/info", requireAuth(requireAdmin(http.HandlerFunc(h.Info))))
 	router.Handle("GET /api/v1/system/metrics", requireAuth(requireAdmin(http.HandlerFunc(h.GetMetrics))))
+	if h.aiRoutes != nil {
+		router.Handle("GET /api/v1/system/ai/routes", requireAuth(requireAdmin(http.HandlerFunc(h.GetAIRoutes))))
+	}
 }
 
 // SystemInfo represents system information
@@ -87,8 +148,11 @@ func (h *Handler) Info(w http.ResponseWriter, r *http.Request) {
 
 // MetricsResponse represents the metrics response
 type MetricsResponse struct {
-	Requests       *RequestMetrics `json:"requests"`
-	ActiveSessions int64           `json:"active_sessions"`
+	Requests       *RequestMetrics             `json:"requests"`
+	ActiveSessions int64                       `json:"active_sessions"`
+	Database       *database.PoolMetrics       `json:"database,omitempty"`
+	AntiAutomation map[string]api.AbuseMetrics `json:"anti_automation,omitempty"`
+	MatcherCache   *matcher.CacheStats         `json:"matcher_cache,omitempty"`
 }
 
 // RequestMetrics represents request metrics
@@ -100,9 +164,29 @@ type RequestMetrics struct {
 
 // GetMetrics handles GET /api/v1/system/metrics
 func (h *Handler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	var dbMetrics *database.PoolMetrics
+	if h.db != nil {
+		m := h.db.Metrics()
+		dbMetrics = &m
+	}
+
+	var abuseMetrics map[string]api.AbuseMetrics
+	if h.abuse != nil {
+		abuseMetrics = h.abuse.Metrics()
+	}
+
+	var matcherCache *matcher.CacheStats
+	if h.matcher != nil {
+		stats := h.matcher.CacheStats()
+		matcherCache = &stats
+	}
+
 	if h.metrics == nil {
 		api.JSONResponse(w, http.StatusOK, MetricsResponse{
-			Requests: &RequestMetrics{},
+			Requests:       &RequestMetrics{},
+			Database:       dbMetrics,
+			AntiAutomation: abuseMetrics,
+			MatcherCache:   matcherCache,
 		})
 		return
 	}
@@ -114,6 +198,33 @@ func (h *Handler) GetMetrics(w http.ResponseWriter, r *http.Request) {
 			ErrorRate:   h.metrics.ErrorRate(),
 		},
 		ActiveSessions: h.metrics.ActiveSessions(),
+		Database:       dbMetrics,
+		AntiAutomation: abuseMetrics,
+		MatcherCache:   matcherCache,
+	})
+}
+
+// GetAIRoutes handles GET /api/v1/system/ai/routes. days is the period,
+// 7 by default and at most 90.
+func (h *Handler) GetAIRoutes(w http.ResponseWriter, r *http.Request) {
+	days := 7
+	if d, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && d > 0 && d <= 90 {
+		days = d
+	}
+	since := time.Now().AddDate(0, 0, -days)
+
+	routes, err := h.aiRoutes.Stats(r.Context(), since)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if routes == nil {
+		routes = []*ai.RouteStats{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"since":  since,
+		"routes": routes,
 	})
 }
 
@@ -201,6 +201,7 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	// Verify tenant access
 	if task.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "task", task.ID.String())
 		http.Error(w, "task not found", http.StatusNotFound)
 		return
 	}
@@ -237,6 +238,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if existing.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "task", existing.ID.String())
 		http.Error(w, "task not found", http.StatusNotFound)
 		return
 	}
@@ -285,6 +287,7 @@ func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if existing.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "task", existing.ID.String())
 		http.Error(w, "task not found", http.StatusNotFound)
 		return
 	}
@@ -325,6 +328,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if existing.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "task", existing.ID.String())
 		http.Error(w, "task not found", http.StatusNotFound)
 		return
 	}
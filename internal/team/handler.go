@@ -0,0 +1,366 @@
+package team
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// Handler handles team, deputy and user import HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new team handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers team routes. Reading teams and deputies is open
+// to all users of the tenant; changes and user imports are admin-only.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/teams", requireAuth(http.HandlerFunc(h.ListTeams)))
+	router.Handle("POST /api/v1/teams", requireAuth(requireAdmin(http.HandlerFunc(h.CreateTeam))))
+	router.Handle("GET /api/v1/teams/{id}", requireAuth(http.HandlerFunc(h.GetTeam)))
+	router.Handle("PATCH /api/v1/teams/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.UpdateTeam))))
+	router.Handle("DELETE /api/v1/teams/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.DeleteTeam))))
+	router.Handle("PUT /api/v1/teams/{id}/members/{userId}", requireAuth(requireAdmin(http.HandlerFunc(h.AddMember))))
+	router.Handle("DELETE /api/v1/teams/{id}/members/{userId}", requireAuth(requireAdmin(http.HandlerFunc(h.RemoveMember))))
+
+	router.Handle("GET /api/v1/deputies", requireAuth(http.HandlerFunc(h.ListDeputies)))
+	router.Handle("POST /api/v1/deputies", requireAuth(requireAdmin(http.HandlerFunc(h.CreateDeputy))))
+	router.Handle("DELETE /api/v1/deputies/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.DeleteDeputy))))
+
+	router.Handle("POST /api/v1/users/import/preview", requireAuth(requireAdmin(http.HandlerFunc(h.PreviewImport))))
+	router.Handle("POST /api/v1/users/import", requireAuth(requireAdmin(http.HandlerFunc(h.Import))))
+}
+
+// ListTeams handles GET /api/v1/teams
+func (h *Handler) ListTeams(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	teams, err := h.service.ListTeams(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"teams": teams})
+}
+
+// CreateTeam handles POST /api/v1/teams
+func (h *Handler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var input CreateTeamInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	t, err := h.service.CreateTeam(r.Context(), tenantID, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, t)
+}
+
+// GetTeam handles GET /api/v1/teams/{id}
+func (h *Handler) GetTeam(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	t, err := h.service.GetTeam(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	members, pending, err := h.service.ListMembers(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"team":            t,
+		"members":         members,
+		"pending_members": pending,
+	})
+}
+
+// UpdateTeam handles PATCH /api/v1/teams/{id}
+func (h *Handler) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var input UpdateTeamInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	t, err := h.service.UpdateTeam(r.Context(), tenantID, id, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, t)
+}
+
+// DeleteTeam handles DELETE /api/v1/teams/{id}
+func (h *Handler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTeam(r.Context(), tenantID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddMember handles PUT /api/v1/teams/{id}/members/{userId}
+func (h *Handler) AddMember(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	teamID, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	userID, ok := api.PathID(w, r, "userId")
+	if !ok {
+		return
+	}
+
+	var input struct {
+		IsLead bool `json:"is_lead"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && err != io.EOF {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.service.AddMember(r.Context(), tenantID, teamID, userID, input.IsLead); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveMember handles DELETE /api/v1/teams/{id}/members/{userId}
+func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	teamID, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	userID, ok := api.PathID(w, r, "userId")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveMember(r.Context(), tenantID, teamID, userID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeputies handles GET /api/v1/deputies
+func (h *Handler) ListDeputies(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var userID *uuid.UUID
+	if v := r.URL.Query().Get("user_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			api.BadRequest(w, "invalid user_id")
+			return
+		}
+		userID = &id
+	}
+
+	deputies, err := h.service.ListDeputies(r.Context(), tenantID, userID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"deputies": deputies})
+}
+
+// CreateDeputy handles POST /api/v1/deputies
+func (h *Handler) CreateDeputy(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var input CreateDeputyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	d, err := h.service.CreateDeputy(r.Context(), tenantID, &input, api.OptionalUser(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, d)
+}
+
+// DeleteDeputy handles DELETE /api/v1/deputies/{id}
+func (h *Handler) DeleteDeputy(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteDeputy(r.Context(), tenantID, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// importFile returns the uploaded CSV of an import request
+func importFile(w http.ResponseWriter, r *http.Request) (io.ReadCloser, bool) {
+	if err := r.ParseMultipartForm(10 << 20); err != nil { // 10MB max
+		api.BadRequest(w, "invalid multipart form")
+		return nil, false
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		api.BadRequest(w, "file is required")
+		return nil, false
+	}
+	return file, true
+}
+
+// PreviewImport handles POST /api/v1/users/import/preview
+func (h *Handler) PreviewImport(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	file, ok := importFile(w, r)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.PreviewImport(r.Context(), tenantID, file)
+	if err != nil {
+		writeImportError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, result)
+}
+
+// Import handles POST /api/v1/users/import
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID := api.OptionalUser(r)
+	if userID == nil {
+		api.Unauthorized(w, "user not found in context")
+		return
+	}
+	file, ok := importFile(w, r)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.Import(r.Context(), tenantID, *userID, file)
+	if err != nil {
+		writeImportError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, result)
+}
+
+func writeImportError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrEmptyFile), errors.Is(err, ErrMissingHeaders), errors.Is(err, ErrTooManyRows):
+		api.BadRequest(w, err.Error())
+	default:
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			api.BadRequest(w, "failed to parse CSV: "+err.Error())
+			return
+		}
+		api.InternalError(w)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrTeamNotFound):
+		api.NotFound(w, "team not found")
+	case errors.Is(err, ErrMemberNotFound):
+		api.NotFound(w, "team member not found")
+	case errors.Is(err, ErrDeputyNotFound):
+		api.NotFound(w, "deputy rule not found")
+	case errors.Is(err, ErrTeamNameExists), errors.Is(err, ErrDeputyOverlap):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, ErrInvalidTeam), errors.Is(err, ErrInvalidDeputy), errors.Is(err, ErrUserNotInTenant):
+		api.BadRequest(w, err.Error())
+	default:
+		api.InternalError(w)
+	}
+}
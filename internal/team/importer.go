@@ -0,0 +1,412 @@
+package team
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/invitation"
+	"austrian-business-infrastructure/internal/user"
+)
+
+var (
+	ErrEmptyFile      = errors.New("empty CSV file")
+	ErrMissingHeaders = errors.New("missing required CSV header: email")
+	ErrTooManyRows    = errors.New("CSV file exceeds maximum allowed rows")
+)
+
+// MaxImportRows is the most users one import may contain
+const MaxImportRows = 500
+
+// Inviter creates invitations; invitation.Service implements it
+type Inviter interface {
+	Create(ctx context.Context, input *invitation.CreateInvitationInput) (*invitation.CreateInvitationResult, error)
+}
+
+// Mailer sends invitation mails; email.Service implements it
+type Mailer interface {
+	SendInvitation(ctx context.Context, to, inviterName, tenantName, token, appURL string) error
+}
+
+// SetInviter enables inviting new users from imports, mailing the
+// invitations if mailer is set
+func (s *Service) SetInviter(inviter Inviter, mailer Mailer) {
+	s.inviter = inviter
+	s.mailer = mailer
+}
+
+// Import row actions
+const (
+	ActionInvite         = "invite"
+	ActionUpdate         = "update"
+	ActionAlreadyInvited = "already_invited"
+)
+
+// ImportRow is a parsed row of a user import
+type ImportRow struct {
+	RowNumber  int        `json:"row_number"`
+	Email      string     `json:"email"`
+	Name       string     `json:"name,omitempty"`
+	Role       string     `json:"role"`
+	Teams      []string   `json:"teams,omitempty"`
+	Department string     `json:"department,omitempty"`
+	Lead       bool       `json:"lead,omitempty"`
+	Action     string     `json:"action,omitempty"`
+	UserID     *uuid.UUID `json:"user_id,omitempty"`
+	Errors     []string   `json:"errors,omitempty"`
+	Valid      bool       `json:"valid"`
+}
+
+// ImportResult is the outcome, or with a preview the plan, of a user import
+type ImportResult struct {
+	Rows         []*ImportRow `json:"rows"`
+	TotalRows    int          `json:"total_rows"`
+	ValidCount   int          `json:"valid_count"`
+	ErrorCount   int          `json:"error_count"`
+	Invited      int          `json:"invited"`
+	Updated      int          `json:"updated"`
+	TeamsCreated []string     `json:"teams_created,omitempty"`
+}
+
+// ParseUsers parses and validates a user import. Columns are email
+// (required), name, role (admin, member or viewer; member if empty), teams
+// (separated by ";"), department and lead ("ja"/"yes"/"true"/"1" makes the
+// user lead of their teams). Rows with errors are returned invalid; a file
+// without an email column or with more than maxRows rows is rejected.
+func ParseUsers(r io.Reader, maxRows int) (*ImportResult, error) {
+	if maxRows <= 0 {
+		maxRows = MaxImportRows
+	}
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	headers, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrEmptyFile
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Spreadsheet exports often start with a byte order mark, and German
+	// ones separate columns with ";"
+	if len(headers) == 1 && strings.Contains(headers[0], ";") {
+		return nil, fmt.Errorf("%w (the file must be comma-separated)", ErrMissingHeaders)
+	}
+	headerMap := make(map[string]int)
+	for i, h := range headers {
+		headerMap[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	if _, ok := headerMap["email"]; !ok {
+		return nil, ErrMissingHeaders
+	}
+	column := func(record []string, name string) string {
+		if i, ok := headerMap[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	result := &ImportResult{Rows: make([]*ImportRow, 0)}
+	seen := make(map[string]int)
+	rowNum := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rowNum++
+		if rowNum > maxRows+1 {
+			return nil, ErrTooManyRows
+		}
+
+		row := &ImportRow{
+			RowNumber:  rowNum,
+			Email:      strings.ToLower(column(record, "email")),
+			Name:       column(record, "name"),
+			Role:       strings.ToLower(column(record, "role")),
+			Department: column(record, "department"),
+		}
+		if row.Email == "" && row.Name == "" && column(record, "teams") == "" {
+			// Blank lines at the end of spreadsheet exports
+			rowNum--
+			continue
+		}
+		for _, name := range strings.Split(column(record, "teams"), ";") {
+			if name = strings.TrimSpace(name); name != "" && !containsFold(row.Teams, name) {
+				row.Teams = append(row.Teams, name)
+			}
+		}
+		switch strings.ToLower(column(record, "lead")) {
+		case "ja", "yes", "true", "1", "x":
+			row.Lead = true
+		}
+		validateImportRow(row)
+		if first, ok := seen[row.Email]; ok && row.Email != "" {
+			row.Errors = append(row.Errors, fmt.Sprintf("email already in row %d", first))
+		} else {
+			seen[row.Email] = rowNum
+		}
+
+		row.Valid = len(row.Errors) == 0
+		if row.Valid {
+			result.ValidCount++
+		} else {
+			result.ErrorCount++
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if len(result.Rows) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	result.TotalRows = len(result.Rows)
+	return result, nil
+}
+
+func validateImportRow(row *ImportRow) {
+	if row.Email == "" {
+		row.Errors = append(row.Errors, "email is required")
+	} else if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email {
+		row.Errors = append(row.Errors, "invalid email")
+	}
+	if len(row.Name) > 255 {
+		row.Errors = append(row.Errors, "name must be at most 255 characters")
+	}
+	if row.Role == "" {
+		row.Role = string(user.RoleMember)
+	}
+	if row.Role == string(user.RoleOwner) {
+		row.Errors = append(row.Errors, "users cannot be imported as owner")
+	} else if !user.IsValidRole(row.Role) {
+		row.Errors = append(row.Errors, "role must be admin, member or viewer")
+	}
+	for _, name := range append(append([]string{}, row.Teams...), row.Department) {
+		if len(name) > 100 {
+			row.Errors = append(row.Errors, fmt.Sprintf("team name %.20q... is longer than 100 characters", name))
+		}
+	}
+	if row.Lead && len(row.Teams) == 0 && row.Department == "" {
+		row.Errors = append(row.Errors, "lead requires a team or department")
+	}
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// PreviewImport parses a user import and plans each valid row: existing
+// users of the tenant are updated, everyone else is invited. Nothing is
+// changed.
+func (s *Service) PreviewImport(ctx context.Context, tenantID uuid.UUID, r io.Reader) (*ImportResult, error) {
+	result, err := ParseUsers(r, MaxImportRows)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.planImport(ctx, tenantID, result); err != nil {
+		return nil, err
+	}
+
+	created := make(map[string]bool)
+	for _, row := range result.Rows {
+		if !row.Valid {
+			continue
+		}
+		for _, name := range row.names() {
+			if created[strings.ToLower(name)] {
+				continue
+			}
+			if _, err := s.repo.GetTeamByName(ctx, tenantID, name); err == ErrTeamNotFound {
+				created[strings.ToLower(name)] = true
+				result.TeamsCreated = append(result.TeamsCreated, name)
+			} else if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// planImport sets the action of each valid row
+func (s *Service) planImport(ctx context.Context, tenantID uuid.UUID, result *ImportResult) error {
+	for _, row := range result.Rows {
+		if !row.Valid {
+			continue
+		}
+		existing, err := s.userRepo.GetByEmail(ctx, tenantID, row.Email)
+		switch {
+		case err == nil && !existing.IsActive:
+			result.fail(row, "user is deactivated")
+		case err == nil:
+			row.Action = ActionUpdate
+			row.UserID = &existing.ID
+		case errors.Is(err, user.ErrUserNotFound):
+			row.Action = ActionInvite
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// fail marks a valid row as failed
+func (r *ImportResult) fail(row *ImportRow, msg string) {
+	row.Errors = append(row.Errors, msg)
+	row.Valid = false
+	r.ValidCount--
+	r.ErrorCount++
+}
+
+// names returns the department and teams of a row
+func (row *ImportRow) names() []string {
+	if row.Department == "" {
+		return row.Teams
+	}
+	return append([]string{row.Department}, row.Teams...)
+}
+
+// Import imports the valid rows of a user import. Missing teams and
+// departments are created, teams listed with a department are moved into
+// it, existing users are added to their teams and everyone else is invited
+// and joins their teams when accepting. Existing users keep their role.
+func (s *Service) Import(ctx context.Context, tenantID, invitedBy uuid.UUID, r io.Reader) (*ImportResult, error) {
+	result, err := ParseUsers(r, MaxImportRows)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.planImport(ctx, tenantID, result); err != nil {
+		return nil, err
+	}
+
+	teams := make(map[string]*Team)
+	team := func(name string, kind Kind) (*Team, error) {
+		key := strings.ToLower(name)
+		if t, ok := teams[key]; ok {
+			return t, nil
+		}
+		t, err := s.repo.GetTeamByName(ctx, tenantID, name)
+		if err == ErrTeamNotFound {
+			t = &Team{TenantID: tenantID, Name: name, Kind: kind}
+			if err = s.repo.CreateTeam(ctx, t); err == nil {
+				result.TeamsCreated = append(result.TeamsCreated, name)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		teams[key] = t
+		return t, nil
+	}
+
+	var inviterName, tenantName string
+	if s.mailer != nil {
+		if inviterName, tenantName, err = s.repo.names(ctx, tenantID, invitedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, row := range result.Rows {
+		if !row.Valid {
+			continue
+		}
+
+		var memberships []*Team
+		if row.Department != "" {
+			dept, err := team(row.Department, KindDepartment)
+			if err != nil {
+				return nil, err
+			}
+			memberships = append(memberships, dept)
+		}
+		for _, name := range row.Teams {
+			t, err := team(name, KindTeam)
+			if err != nil {
+				return nil, err
+			}
+			if len(memberships) > 0 && t.Kind == KindTeam && t.ParentID == nil {
+				t.ParentID = &memberships[0].ID
+				if err := s.repo.UpdateTeam(ctx, t); err != nil {
+					return nil, err
+				}
+			}
+			memberships = append(memberships, t)
+		}
+
+		if row.Action == ActionInvite {
+			if err := s.invite(ctx, tenantID, invitedBy, row, inviterName, tenantName); err != nil {
+				result.fail(row, err.Error())
+				continue
+			}
+			if row.Action == ActionInvite {
+				result.Invited++
+			}
+			for _, t := range memberships {
+				if err := s.repo.AddPendingMember(ctx, tenantID, t.ID, row.Email, row.Lead); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		for _, t := range memberships {
+			if err := s.repo.AddMember(ctx, tenantID, t.ID, *row.UserID, row.Lead); err != nil {
+				return nil, err
+			}
+		}
+		result.Updated++
+	}
+
+	s.logger.Info("imported users",
+		"tenant_id", tenantID,
+		"invited", result.Invited,
+		"updated", result.Updated,
+		"teams_created", len(result.TeamsCreated),
+		"errors", result.ErrorCount)
+	return result, nil
+}
+
+// invite invites the user of a row and mails the invitation. A row whose
+// user was invited before becomes ActionAlreadyInvited.
+func (s *Service) invite(ctx context.Context, tenantID, invitedBy uuid.UUID, row *ImportRow, inviterName, tenantName string) error {
+	if s.inviter == nil {
+		return errors.New("inviting users is not enabled")
+	}
+	created, err := s.inviter.Create(ctx, &invitation.CreateInvitationInput{
+		TenantID:  tenantID,
+		Email:     row.Email,
+		Role:      row.Role,
+		InvitedBy: invitedBy,
+	})
+	if errors.Is(err, invitation.ErrPendingInvitationExists) {
+		// Imported again before accepting: only the teams change
+		row.Action = ActionAlreadyInvited
+		return nil
+	}
+	if err != nil {
+		s.logger.Error("failed to invite imported user", "tenant_id", tenantID, "row", row.RowNumber, "error", err)
+		return fmt.Errorf("invitation failed: %w", err)
+	}
+
+	if s.mailer != nil {
+		if err := s.mailer.SendInvitation(ctx, row.Email, inviterName, tenantName, created.Token, s.cfg.AppURL); err != nil {
+			// The invitation exists; it can be resent from the invitations
+			s.logger.Error("failed to send invitation mail", "tenant_id", tenantID, "row", row.RowNumber, "error", err)
+			row.Errors = append(row.Errors, "invitation created, but the mail could not be sent")
+		}
+	}
+	return nil
+}
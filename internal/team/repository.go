@@ -0,0 +1,385 @@
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository provides team and deputy data access
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new team repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const teamColumns = `t.id, t.tenant_id, t.name, t.kind, t.parent_id, t.description,
+	(SELECT COUNT(*) FROM team_members m WHERE m.team_id = t.id), t.created_at, t.updated_at`
+
+func scanTeam(row pgx.Row) (*Team, error) {
+	t := &Team{}
+	err := row.Scan(&t.ID, &t.TenantID, &t.Name, &t.Kind, &t.ParentID, &t.Description,
+		&t.MemberCount, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("scan team: %w", err)
+	}
+	return t, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// CreateTeam inserts a team
+func (r *Repository) CreateTeam(ctx context.Context, t *Team) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO teams (id, tenant_id, name, kind, parent_id, description)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at
+	`, t.ID, t.TenantID, t.Name, t.Kind, t.ParentID, t.Description).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrTeamNameExists
+		}
+		return fmt.Errorf("create team: %w", err)
+	}
+	return nil
+}
+
+// GetTeam returns a team of a tenant
+func (r *Repository) GetTeam(ctx context.Context, tenantID, id uuid.UUID) (*Team, error) {
+	return scanTeam(r.db.QueryRow(ctx, `SELECT `+teamColumns+` FROM teams t WHERE t.tenant_id = $1 AND t.id = $2`, tenantID, id))
+}
+
+// GetTeamByName returns a team of a tenant by its name, ignoring case
+func (r *Repository) GetTeamByName(ctx context.Context, tenantID uuid.UUID, name string) (*Team, error) {
+	return scanTeam(r.db.QueryRow(ctx, `SELECT `+teamColumns+` FROM teams t WHERE t.tenant_id = $1 AND lower(t.name) = lower($2)`, tenantID, name))
+}
+
+// ListTeams returns the teams and departments of a tenant, by name
+func (r *Repository) ListTeams(ctx context.Context, tenantID uuid.UUID) ([]*Team, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+teamColumns+` FROM teams t WHERE t.tenant_id = $1 ORDER BY t.kind, lower(t.name)`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list teams: %w", err)
+	}
+	defer rows.Close()
+
+	var teams []*Team
+	for rows.Next() {
+		t, err := scanTeam(rows)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
+
+// UpdateTeam writes the mutable fields of a team
+func (r *Repository) UpdateTeam(ctx context.Context, t *Team) error {
+	err := r.db.QueryRow(ctx, `
+		UPDATE teams SET name = $3, parent_id = $4, description = $5, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING updated_at
+	`, t.TenantID, t.ID, t.Name, t.ParentID, t.Description).Scan(&t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTeamNotFound
+		}
+		if isUniqueViolation(err) {
+			return ErrTeamNameExists
+		}
+		return fmt.Errorf("update team: %w", err)
+	}
+	return nil
+}
+
+// DeleteTeam deletes a team with its memberships. Teams of a deleted
+// department no longer belong to one.
+func (r *Repository) DeleteTeam(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM teams WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("delete team: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTeamNotFound
+	}
+	return nil
+}
+
+// ListMembers returns the members of a team, leads first
+func (r *Repository) ListMembers(ctx context.Context, teamID uuid.UUID) ([]*Member, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT m.team_id, m.user_id, u.name, u.email, u.role, m.is_lead, m.added_at
+		FROM team_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.team_id = $1
+		ORDER BY m.is_lead DESC, lower(u.name)
+	`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("list team members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*Member
+	for rows.Next() {
+		m := &Member{}
+		if err := rows.Scan(&m.TeamID, &m.UserID, &m.Name, &m.Email, &m.Role, &m.IsLead, &m.AddedAt); err != nil {
+			return nil, fmt.Errorf("scan team member: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// AddMember adds a user to a team or changes whether they lead it
+func (r *Repository) AddMember(ctx context.Context, tenantID, teamID, userID uuid.UUID, isLead bool) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO team_members (team_id, user_id, tenant_id, is_lead)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (team_id, user_id) DO UPDATE SET is_lead = EXCLUDED.is_lead
+	`, teamID, userID, tenantID, isLead)
+	if err != nil {
+		return fmt.Errorf("add team member: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember removes a user from a team
+func (r *Repository) RemoveMember(ctx context.Context, teamID, userID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("remove team member: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// AddPendingMember records the membership of an invited user
+func (r *Repository) AddPendingMember(ctx context.Context, tenantID, teamID uuid.UUID, email string, isLead bool) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO team_pending_members (team_id, tenant_id, email, is_lead)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (team_id, email) DO UPDATE SET is_lead = EXCLUDED.is_lead
+	`, teamID, tenantID, strings.ToLower(email), isLead)
+	if err != nil {
+		return fmt.Errorf("add pending team member: %w", err)
+	}
+	return nil
+}
+
+// ListPendingMembers returns the invited users of a team who have not
+// joined yet
+func (r *Repository) ListPendingMembers(ctx context.Context, teamID uuid.UUID) ([]*PendingMember, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT team_id, email, is_lead, created_at
+		FROM team_pending_members
+		WHERE team_id = $1
+		ORDER BY email
+	`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("list pending team members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*PendingMember
+	for rows.Next() {
+		m := &PendingMember{}
+		if err := rows.Scan(&m.TeamID, &m.Email, &m.IsLead, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan pending team member: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// ClaimPendingMembers turns the pending memberships of an email into
+// memberships of the user who joined with it
+func (r *Repository) ClaimPendingMembers(ctx context.Context, tenantID, userID uuid.UUID, email string) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO team_members (team_id, user_id, tenant_id, is_lead)
+		SELECT team_id, $2, tenant_id, is_lead
+		FROM team_pending_members
+		WHERE tenant_id = $1 AND email = $3
+		ON CONFLICT (team_id, user_id) DO NOTHING
+	`, tenantID, userID, strings.ToLower(email))
+	if err != nil {
+		return 0, fmt.Errorf("claim pending team members: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM team_pending_members WHERE tenant_id = $1 AND email = $2`, tenantID, strings.ToLower(email)); err != nil {
+		return 0, fmt.Errorf("delete pending team members: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// TeamIDsOfUsers returns the teams any of the users belongs to
+func (r *Repository) TeamIDsOfUsers(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT team_id FROM team_members
+		WHERE tenant_id = $1 AND user_id = ANY($2)
+	`, tenantID, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list teams of users: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// IsActiveUser reports whether a user is an active member of a tenant
+func (r *Repository) IsActiveUser(ctx context.Context, tenantID, userID uuid.UUID) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND tenant_id = $2 AND is_active)
+	`, userID, tenantID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("check user: %w", err)
+	}
+	return ok, nil
+}
+
+const deputyColumns = `d.id, d.tenant_id, d.user_id, d.deputy_id, d.scope, d.valid_from, d.valid_until,
+	d.note, d.created_by, d.created_at, u.name, dep.name`
+
+const deputyJoins = `FROM user_deputies d
+	JOIN users u ON u.id = d.user_id
+	JOIN users dep ON dep.id = d.deputy_id`
+
+func scanDeputy(row pgx.Row) (*Deputy, error) {
+	d := &Deputy{}
+	err := row.Scan(&d.ID, &d.TenantID, &d.UserID, &d.DeputyID, &d.Scope, &d.ValidFrom, &d.ValidUntil,
+		&d.Note, &d.CreatedBy, &d.CreatedAt, &d.UserName, &d.DeputyName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDeputyNotFound
+		}
+		return nil, fmt.Errorf("scan deputy: %w", err)
+	}
+	return d, nil
+}
+
+func (r *Repository) queryDeputies(ctx context.Context, query string, args ...interface{}) ([]*Deputy, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list deputies: %w", err)
+	}
+	defer rows.Close()
+
+	var deputies []*Deputy
+	for rows.Next() {
+		d, err := scanDeputy(rows)
+		if err != nil {
+			return nil, err
+		}
+		deputies = append(deputies, d)
+	}
+	return deputies, rows.Err()
+}
+
+// CreateDeputy inserts a deputy rule
+func (r *Repository) CreateDeputy(ctx context.Context, d *Deputy) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO user_deputies (id, tenant_id, user_id, deputy_id, scope, valid_from, valid_until, note, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`, d.ID, d.TenantID, d.UserID, d.DeputyID, d.Scope, d.ValidFrom, d.ValidUntil, d.Note, d.CreatedBy).Scan(&d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create deputy: %w", err)
+	}
+	return nil
+}
+
+// GetDeputy returns a deputy rule of a tenant
+func (r *Repository) GetDeputy(ctx context.Context, tenantID, id uuid.UUID) (*Deputy, error) {
+	return scanDeputy(r.db.QueryRow(ctx, `SELECT `+deputyColumns+` `+deputyJoins+` WHERE d.tenant_id = $1 AND d.id = $2`, tenantID, id))
+}
+
+// ListDeputies returns the deputy rules of a tenant that end after since,
+// optionally only those of one absent user, by start
+func (r *Repository) ListDeputies(ctx context.Context, tenantID uuid.UUID, userID *uuid.UUID, since time.Time) ([]*Deputy, error) {
+	query := `SELECT ` + deputyColumns + ` ` + deputyJoins + ` WHERE d.tenant_id = $1 AND d.valid_until > $2`
+	args := []interface{}{tenantID, since}
+	if userID != nil {
+		query += ` AND d.user_id = $3`
+		args = append(args, *userID)
+	}
+	return r.queryDeputies(ctx, query+` ORDER BY d.valid_from, u.name`, args...)
+}
+
+// OverlappingDeputies returns the rules of a user whose validity overlaps
+// [from, until)
+func (r *Repository) OverlappingDeputies(ctx context.Context, tenantID, userID uuid.UUID, from, until time.Time) ([]*Deputy, error) {
+	return r.queryDeputies(ctx, `SELECT `+deputyColumns+` `+deputyJoins+`
+		WHERE d.tenant_id = $1 AND d.user_id = $2 AND d.valid_from < $4 AND d.valid_until > $3
+		ORDER BY d.valid_from`, tenantID, userID, from, until)
+}
+
+// ActiveDeputies returns the rules of a tenant that apply at t
+func (r *Repository) ActiveDeputies(ctx context.Context, tenantID uuid.UUID, at time.Time) ([]*Deputy, error) {
+	return r.queryDeputies(ctx, `SELECT `+deputyColumns+` `+deputyJoins+`
+		WHERE d.tenant_id = $1 AND d.valid_from <= $2 AND d.valid_until > $2
+		ORDER BY d.valid_from`, tenantID, at)
+}
+
+// DeleteDeputy deletes a deputy rule
+func (r *Repository) DeleteDeputy(ctx context.Context, tenantID, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM user_deputies WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("delete deputy: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDeputyNotFound
+	}
+	return nil
+}
+
+// names returns the names of a user and a tenant, for invitation mails
+func (r *Repository) names(ctx context.Context, tenantID, userID uuid.UUID) (userName, tenantName string, err error) {
+	err = r.db.QueryRow(ctx, `
+		SELECT COALESCE((SELECT name FROM users WHERE id = $2), ''), name
+		FROM tenants WHERE id = $1
+	`, tenantID, userID).Scan(&userName, &tenantName)
+	if err != nil {
+		return "", "", fmt.Errorf("get inviter and tenant names: %w", err)
+	}
+	return userName, tenantName, nil
+}
@@ -0,0 +1,348 @@
+// Package team organizes the users of a tenant into teams and departments
+// and manages deputies who stand in for absent users in approvals and
+// notifications. It also imports users from CSV, inviting new ones and
+// adding them to their teams.
+package team
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/user"
+)
+
+// Config holds the team service settings
+type Config struct {
+	// AppURL is linked in invitation mails of imported users
+	AppURL string
+	Logger *slog.Logger
+}
+
+// Service manages teams, memberships and deputies
+type Service struct {
+	repo     *Repository
+	userRepo *user.Repository
+	inviter  Inviter
+	mailer   Mailer
+	cfg      Config
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewService creates a new team service
+func NewService(repo *Repository, userRepo *user.Repository, cfg Config) *Service {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{repo: repo, userRepo: userRepo, cfg: cfg, logger: logger, now: time.Now}
+}
+
+// CreateTeam creates a team or department
+func (s *Service) CreateTeam(ctx context.Context, tenantID uuid.UUID, input *CreateTeamInput) (*Team, error) {
+	t := &Team{
+		TenantID:    tenantID,
+		Name:        strings.TrimSpace(input.Name),
+		Kind:        input.Kind,
+		ParentID:    input.ParentID,
+		Description: input.Description,
+	}
+	if t.Kind == "" {
+		t.Kind = KindTeam
+	}
+	if err := s.validateTeam(ctx, t); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateTeam(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// validateTeam checks the name, kind and department of a team
+func (s *Service) validateTeam(ctx context.Context, t *Team) error {
+	if t.Name == "" || len(t.Name) > 100 {
+		return fmt.Errorf("%w: name is required and must be at most 100 characters", ErrInvalidTeam)
+	}
+	if t.Kind != KindTeam && t.Kind != KindDepartment {
+		return fmt.Errorf("%w: kind must be team or department", ErrInvalidTeam)
+	}
+	if t.ParentID == nil {
+		return nil
+	}
+	if t.Kind == KindDepartment {
+		return fmt.Errorf("%w: departments cannot belong to another department", ErrInvalidTeam)
+	}
+	parent, err := s.repo.GetTeam(ctx, t.TenantID, *t.ParentID)
+	if err != nil {
+		if err == ErrTeamNotFound {
+			return fmt.Errorf("%w: department not found", ErrInvalidTeam)
+		}
+		return err
+	}
+	if parent.Kind != KindDepartment {
+		return fmt.Errorf("%w: parent_id must be a department", ErrInvalidTeam)
+	}
+	return nil
+}
+
+// GetTeam returns a team of a tenant
+func (s *Service) GetTeam(ctx context.Context, tenantID, id uuid.UUID) (*Team, error) {
+	return s.repo.GetTeam(ctx, tenantID, id)
+}
+
+// ListTeams returns the teams and departments of a tenant
+func (s *Service) ListTeams(ctx context.Context, tenantID uuid.UUID) ([]*Team, error) {
+	return s.repo.ListTeams(ctx, tenantID)
+}
+
+// UpdateTeam renames a team or moves it to another department
+func (s *Service) UpdateTeam(ctx context.Context, tenantID, id uuid.UUID, input *UpdateTeamInput) (*Team, error) {
+	t, err := s.repo.GetTeam(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != nil {
+		t.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.ClearParent {
+		t.ParentID = nil
+	} else if input.ParentID != nil {
+		t.ParentID = input.ParentID
+	}
+	if input.Description != nil {
+		t.Description = input.Description
+	}
+	if err := s.validateTeam(ctx, t); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateTeam(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// DeleteTeam deletes a team with its memberships
+func (s *Service) DeleteTeam(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.DeleteTeam(ctx, tenantID, id)
+}
+
+// ListMembers returns the members of a team and the invited users who
+// will join it
+func (s *Service) ListMembers(ctx context.Context, tenantID, teamID uuid.UUID) ([]*Member, []*PendingMember, error) {
+	if _, err := s.repo.GetTeam(ctx, tenantID, teamID); err != nil {
+		return nil, nil, err
+	}
+	members, err := s.repo.ListMembers(ctx, teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	pending, err := s.repo.ListPendingMembers(ctx, teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return members, pending, nil
+}
+
+// AddMember adds an active user of the tenant to a team
+func (s *Service) AddMember(ctx context.Context, tenantID, teamID, userID uuid.UUID, isLead bool) error {
+	if _, err := s.repo.GetTeam(ctx, tenantID, teamID); err != nil {
+		return err
+	}
+	if err := s.requireActiveUser(ctx, tenantID, userID); err != nil {
+		return err
+	}
+	return s.repo.AddMember(ctx, tenantID, teamID, userID, isLead)
+}
+
+// RemoveMember removes a user from a team
+func (s *Service) RemoveMember(ctx context.Context, tenantID, teamID, userID uuid.UUID) error {
+	if _, err := s.repo.GetTeam(ctx, tenantID, teamID); err != nil {
+		return err
+	}
+	return s.repo.RemoveMember(ctx, teamID, userID)
+}
+
+func (s *Service) requireActiveUser(ctx context.Context, tenantID, userID uuid.UUID) error {
+	ok, err := s.repo.IsActiveUser(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrUserNotInTenant
+	}
+	return nil
+}
+
+// UserJoined adds a user who accepted an invitation to the teams the
+// import assigned them; invitation.Service calls it
+func (s *Service) UserJoined(ctx context.Context, u *user.User) error {
+	n, err := s.repo.ClaimPendingMembers(ctx, u.TenantID, u.ID, u.Email)
+	if err != nil {
+		s.logger.Error("failed to add joined user to teams", "tenant_id", u.TenantID, "user_id", u.ID, "error", err)
+		return err
+	}
+	if n > 0 {
+		s.logger.Info("added joined user to teams", "tenant_id", u.TenantID, "user_id", u.ID, "teams", n)
+	}
+	return nil
+}
+
+// CreateDeputy adds a rule that a user stands in for another. Both must be
+// active users of the tenant, and the rule may not overlap another rule of
+// the same user whose scope covers the same things.
+func (s *Service) CreateDeputy(ctx context.Context, tenantID uuid.UUID, input *CreateDeputyInput, createdBy *uuid.UUID) (*Deputy, error) {
+	if input.Scope == "" {
+		input.Scope = ScopeAll
+	}
+	switch {
+	case input.UserID == uuid.Nil || input.DeputyID == uuid.Nil:
+		return nil, fmt.Errorf("%w: user_id and deputy_id are required", ErrInvalidDeputy)
+	case input.UserID == input.DeputyID:
+		return nil, fmt.Errorf("%w: a user cannot be their own deputy", ErrInvalidDeputy)
+	case !IsValidScope(string(input.Scope)):
+		return nil, fmt.Errorf("%w: scope must be all, approvals or notifications", ErrInvalidDeputy)
+	case input.ValidFrom.IsZero() || input.ValidUntil.IsZero():
+		return nil, fmt.Errorf("%w: valid_from and valid_until are required", ErrInvalidDeputy)
+	case !input.ValidUntil.After(input.ValidFrom):
+		return nil, fmt.Errorf("%w: valid_until must be after valid_from", ErrInvalidDeputy)
+	case !input.ValidUntil.After(s.now()):
+		return nil, fmt.Errorf("%w: valid_until must be in the future", ErrInvalidDeputy)
+	}
+	for _, id := range []uuid.UUID{input.UserID, input.DeputyID} {
+		if err := s.requireActiveUser(ctx, tenantID, id); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := s.repo.OverlappingDeputies(ctx, tenantID, input.UserID, input.ValidFrom, input.ValidUntil)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range existing {
+		if d.Scope.Covers(input.Scope) || input.Scope.Covers(d.Scope) {
+			return nil, fmt.Errorf("%w: %s stands in from %s to %s", ErrDeputyOverlap,
+				d.DeputyName, d.ValidFrom.Format(time.RFC3339), d.ValidUntil.Format(time.RFC3339))
+		}
+	}
+
+	d := &Deputy{
+		TenantID:   tenantID,
+		UserID:     input.UserID,
+		DeputyID:   input.DeputyID,
+		Scope:      input.Scope,
+		ValidFrom:  input.ValidFrom,
+		ValidUntil: input.ValidUntil,
+		Note:       input.Note,
+		CreatedBy:  createdBy,
+	}
+	if err := s.repo.CreateDeputy(ctx, d); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDeputy(ctx, tenantID, d.ID)
+}
+
+// ListDeputies returns the current and upcoming deputy rules of a tenant,
+// optionally only those of one user
+func (s *Service) ListDeputies(ctx context.Context, tenantID uuid.UUID, userID *uuid.UUID) ([]*Deputy, error) {
+	return s.repo.ListDeputies(ctx, tenantID, userID, s.now())
+}
+
+// DeleteDeputy deletes a deputy rule
+func (s *Service) DeleteDeputy(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.DeleteDeputy(ctx, tenantID, id)
+}
+
+// ResolveDeputy returns who stands in for a user at t for scope. If the
+// deputy is away as well, their own deputy is followed, up to
+// MaxDeputyChain steps. It returns false if the user is not away or the
+// chain leads back to someone already in it, since then nobody is present.
+func ResolveDeputy(rules []*Deputy, userID uuid.UUID, scope Scope, at time.Time) (uuid.UUID, bool) {
+	seen := map[uuid.UUID]bool{userID: true}
+	current := userID
+	for i := 0; i < MaxDeputyChain; i++ {
+		next, ok := activeRule(rules, current, scope, at)
+		if !ok {
+			break
+		}
+		if seen[next] {
+			return uuid.Nil, false
+		}
+		seen[next] = true
+		current = next
+	}
+	if current == userID {
+		return uuid.Nil, false
+	}
+	return current, true
+}
+
+// activeRule returns the deputy of the earliest starting rule of a user
+// that applies at t
+func activeRule(rules []*Deputy, userID uuid.UUID, scope Scope, at time.Time) (uuid.UUID, bool) {
+	var found *Deputy
+	for _, d := range rules {
+		if d.UserID != userID || !d.Scope.Covers(scope) || !d.ActiveAt(at) {
+			continue
+		}
+		if found == nil || d.ValidFrom.Before(found.ValidFrom) {
+			found = d
+		}
+	}
+	if found == nil {
+		return uuid.Nil, false
+	}
+	return found.DeputyID, true
+}
+
+// Deputies returns who stands in for each absent user of a tenant at t for
+// scope
+func (s *Service) Deputies(ctx context.Context, tenantID uuid.UUID, scope Scope, at time.Time) (map[uuid.UUID]uuid.UUID, error) {
+	rules, err := s.repo.ActiveDeputies(ctx, tenantID, at)
+	if err != nil {
+		return nil, err
+	}
+	deputies := make(map[uuid.UUID]uuid.UUID)
+	for _, d := range rules {
+		if _, done := deputies[d.UserID]; done {
+			continue
+		}
+		if deputy, ok := ResolveDeputy(rules, d.UserID, scope, at); ok {
+			deputies[d.UserID] = deputy
+		}
+	}
+	return deputies, nil
+}
+
+// NotificationDeputies returns who receives the notifications of each
+// absent user at t; notification.Service calls it
+func (s *Service) NotificationDeputies(ctx context.Context, tenantID uuid.UUID, at time.Time) (map[uuid.UUID]uuid.UUID, error) {
+	return s.Deputies(ctx, tenantID, ScopeNotifications, at)
+}
+
+// ApprovalPrincipals returns the user and everyone they currently stand in
+// for in approvals; approval.Service calls it
+func (s *Service) ApprovalPrincipals(ctx context.Context, tenantID, userID uuid.UUID) ([]uuid.UUID, error) {
+	deputies, err := s.Deputies(ctx, tenantID, ScopeApprovals, s.now())
+	if err != nil {
+		return nil, err
+	}
+	principals := []uuid.UUID{userID}
+	for absent, deputy := range deputies {
+		if deputy == userID {
+			principals = append(principals, absent)
+		}
+	}
+	return principals, nil
+}
+
+// TeamIDs returns the teams any of the users belongs to; approval.Service
+// calls it
+func (s *Service) TeamIDs(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	return s.repo.TeamIDsOfUsers(ctx, tenantID, userIDs)
+}
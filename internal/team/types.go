@@ -0,0 +1,137 @@
+package team
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTeamNotFound    = errors.New("team not found")
+	ErrTeamNameExists  = errors.New("a team with this name already exists")
+	ErrInvalidTeam     = errors.New("invalid team")
+	ErrMemberNotFound  = errors.New("team member not found")
+	ErrUserNotInTenant = errors.New("user is not an active member of this tenant")
+	ErrDeputyNotFound  = errors.New("deputy rule not found")
+	ErrInvalidDeputy   = errors.New("invalid deputy rule")
+	ErrDeputyOverlap   = errors.New("deputy rule overlaps an existing rule")
+)
+
+// Kind distinguishes teams from departments
+type Kind string
+
+const (
+	KindTeam       Kind = "team"
+	KindDepartment Kind = "department"
+)
+
+// Scope is what a deputy stands in for
+type Scope string
+
+const (
+	ScopeAll           Scope = "all"
+	ScopeApprovals     Scope = "approvals"
+	ScopeNotifications Scope = "notifications"
+)
+
+// IsValidScope checks if a scope is valid
+func IsValidScope(scope string) bool {
+	switch Scope(scope) {
+	case ScopeAll, ScopeApprovals, ScopeNotifications:
+		return true
+	}
+	return false
+}
+
+// Covers reports whether a rule with scope s applies to want
+func (s Scope) Covers(want Scope) bool {
+	return s == ScopeAll || s == want
+}
+
+// MaxDeputyChain is how many deputies are followed when a deputy is away
+// as well
+const MaxDeputyChain = 5
+
+// Team is a team or department of a tenant. Teams may belong to a
+// department through ParentID.
+type Team struct {
+	ID          uuid.UUID  `json:"id"`
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	Name        string     `json:"name"`
+	Kind        Kind       `json:"kind"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	MemberCount int        `json:"member_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Member is a user's membership in a team
+type Member struct {
+	TeamID  uuid.UUID `json:"team_id"`
+	UserID  uuid.UUID `json:"user_id"`
+	Name    string    `json:"name"`
+	Email   string    `json:"email"`
+	Role    string    `json:"role"`
+	IsLead  bool      `json:"is_lead"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// PendingMember is the membership of an invited user who has not joined yet
+type PendingMember struct {
+	TeamID    uuid.UUID `json:"team_id"`
+	Email     string    `json:"email"`
+	IsLead    bool      `json:"is_lead"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Deputy is a rule that deputyID stands in for userID between ValidFrom
+// and ValidUntil
+type Deputy struct {
+	ID         uuid.UUID  `json:"id"`
+	TenantID   uuid.UUID  `json:"tenant_id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	DeputyID   uuid.UUID  `json:"deputy_id"`
+	Scope      Scope      `json:"scope"`
+	ValidFrom  time.Time  `json:"valid_from"`
+	ValidUntil time.Time  `json:"valid_until"`
+	Note       *string    `json:"note,omitempty"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Joined fields
+	UserName   string `json:"user_name,omitempty"`
+	DeputyName string `json:"deputy_name,omitempty"`
+}
+
+// ActiveAt reports whether the rule applies at t
+func (d *Deputy) ActiveAt(t time.Time) bool {
+	return !t.Before(d.ValidFrom) && t.Before(d.ValidUntil)
+}
+
+// CreateTeamInput contains input for creating a team
+type CreateTeamInput struct {
+	Name        string     `json:"name"`
+	Kind        Kind       `json:"kind"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	Description *string    `json:"description,omitempty"`
+}
+
+// UpdateTeamInput contains the team fields to change
+type UpdateTeamInput struct {
+	Name        *string    `json:"name,omitempty"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	ClearParent bool       `json:"clear_parent,omitempty"`
+	Description *string    `json:"description,omitempty"`
+}
+
+// CreateDeputyInput contains input for creating a deputy rule
+type CreateDeputyInput struct {
+	UserID     uuid.UUID `json:"user_id"`
+	DeputyID   uuid.UUID `json:"deputy_id"`
+	Scope      Scope     `json:"scope"`
+	ValidFrom  time.Time `json:"valid_from"`
+	ValidUntil time.Time `json:"valid_until"`
+	Note       *string   `json:"note,omitempty"`
+}
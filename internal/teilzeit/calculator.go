@@ -0,0 +1,203 @@
+package teilzeit
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/refdata"
+)
+
+// Limits of the part-time models
+const (
+	ATZMinReduktion   = 40.0 // percent
+	ATZMaxReduktion   = 60.0
+	ATZMaxMonate      = 60
+	WIETZMinReduktion = 25.0
+	WIETZMaxReduktion = 75.0
+	WIETZMinStunden   = 12.0
+	WIETZMinMonate    = 1
+	WIETZMaxMonate    = 6
+
+	// Krankengeld from the 43rd day is 60% of the Bemessungsgrundlage, which
+	// includes the Sonderzahlungen pro rata (14 salaries over 12 months)
+	krankengeldSatz = 0.6 * 14 / 12
+)
+
+// Calculate computes a part-time scenario. It returns the validation errors of
+// the input instead of a result if the scenario is not permissible.
+func Calculate(e *Eingabe) (*Ergebnis, []string) {
+	if errs := Validate(e); len(errs) > 0 {
+		return nil, errs
+	}
+
+	// Contribution limits, rates and the tariff of the year the scenario starts
+	p := refdata.For(e.Von)
+	hbgl := p.Hoechstbeitragsgrundlage
+	ratio := e.NeueStunden / e.BisherStunden
+
+	r := &Ergebnis{
+		Reduktion: math.Round((1-ratio)*10000) / 100,
+		Monate:    Monate(e.Von, e.Bis),
+		Bisher:    monatswerte(p, e.BisherBrutto, min(e.BisherBrutto, hbgl)),
+	}
+
+	neuesBrutto := int64(math.Round(float64(e.BisherBrutto) * ratio))
+
+	switch e.Modell {
+	case ModellAltersteilzeit:
+		// The employer pays at least half of the pay reduction up to the
+		// Höchstbeitragsgrundlage and the contributions on the full previous basis
+		base := min(e.BisherBrutto, hbgl)
+		r.Lohnausgleich = max(0, base-neuesBrutto) / 2
+
+		brutto := neuesBrutto + r.Lohnausgleich
+		tzBasis := min(brutto, hbgl)
+		r.Teilzeit = monatswerte(p, brutto, tzBasis)
+
+		diff := max(0, base-tzBasis)
+		r.Mehrbeitraege = basisPoints(diff, p.SVDienstnehmerSatz+p.SVDienstgeberSatz)
+		r.Teilzeit.Beitragsgrundlage = base
+		r.Teilzeit.SVDienstgeber = basisPoints(base, p.SVDienstgeberSatz) + basisPoints(diff, p.SVDienstnehmerSatz)
+		r.Teilzeit.Dienstgeberkosten = brutto + r.Teilzeit.SVDienstgeber
+
+		r.AMSErsatzsatz = AMSErsatzsatz(e.Variante, e.Von.Year(), e.Ersatzkraft)
+		r.Altersteilzeitgeld = int64(math.Round(float64(r.Lohnausgleich+r.Mehrbeitraege) * r.AMSErsatzsatz / 100))
+		r.KostenDienstgeber = r.Teilzeit.Dienstgeberkosten - r.Altersteilzeitgeld
+		r.NettoDienstnehmer = r.Teilzeit.Netto
+
+		if e.Variante == VarianteBlock && !e.Ersatzkraft {
+			r.Hinweise = append(r.Hinweise, "Blockzeitvariante ohne Ersatzkraft: kein Altersteilzeitgeld")
+		}
+		if e.BisherBrutto > hbgl {
+			r.Hinweise = append(r.Hinweise, "Lohnausgleich nur bis zur Höchstbeitragsgrundlage berücksichtigt")
+		}
+
+	case ModellWiedereingliederung:
+		// The employer pays the reduced pay; the ÖGK pays Wiedereingliederungsgeld
+		// as the share of the increased Krankengeld matching the reduction
+		r.Teilzeit = monatswerte(p, neuesBrutto, min(neuesBrutto, hbgl))
+		r.Wiedereingliederungsgeld = int64(math.Round(float64(min(e.BisherBrutto, hbgl)) * krankengeldSatz * (1 - ratio)))
+		r.KostenDienstgeber = r.Teilzeit.Dienstgeberkosten
+		r.NettoDienstnehmer = r.Teilzeit.Netto + r.Wiedereingliederungsgeld
+	}
+
+	r.NettoDifferenz = r.NettoDienstnehmer - r.Bisher.Netto
+	r.KostenDifferenz = r.KostenDienstgeber - r.Bisher.Dienstgeberkosten
+	r.KostenGesamtdauer = r.KostenDifferenz * int64(r.Monate)
+
+	return r, nil
+}
+
+// Validate checks the legal limits of a part-time scenario
+func Validate(e *Eingabe) []string {
+	var errs []string
+
+	if e.BisherStunden <= 0 {
+		errs = append(errs, "bisher_stunden muss größer als 0 sein")
+	}
+	if e.NeueStunden <= 0 || (e.BisherStunden > 0 && e.NeueStunden >= e.BisherStunden) {
+		errs = append(errs, "neue_stunden muss größer als 0 und kleiner als bisher_stunden sein")
+	}
+	if e.BisherBrutto <= 0 {
+		errs = append(errs, "bisher_brutto muss größer als 0 sein")
+	}
+	if e.Von.IsZero() || e.Bis.IsZero() || !e.Bis.After(e.Von) {
+		errs = append(errs, "bis muss nach von liegen")
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	reduktion := (1 - e.NeueStunden/e.BisherStunden) * 100
+	monate := Monate(e.Von, e.Bis)
+
+	switch e.Modell {
+	case ModellAltersteilzeit:
+		if reduktion < ATZMinReduktion || reduktion > ATZMaxReduktion {
+			errs = append(errs, fmt.Sprintf("Altersteilzeit erfordert eine Reduktion um %.0f bis %.0f %%", ATZMinReduktion, ATZMaxReduktion))
+		}
+		if monate > ATZMaxMonate {
+			errs = append(errs, "Altersteilzeit ist auf 5 Jahre begrenzt")
+		}
+		if e.Variante != VarianteKontinuierlich && e.Variante != VarianteBlock {
+			errs = append(errs, "variante muss kontinuierlich oder block sein")
+		}
+	case ModellWiedereingliederung:
+		if reduktion < WIETZMinReduktion || reduktion > WIETZMaxReduktion {
+			errs = append(errs, fmt.Sprintf("Wiedereingliederungsteilzeit erfordert eine Reduktion um %.0f bis %.0f %%", WIETZMinReduktion, WIETZMaxReduktion))
+		}
+		if e.NeueStunden < WIETZMinStunden {
+			errs = append(errs, fmt.Sprintf("Wiedereingliederungsteilzeit erfordert mindestens %.0f Wochenstunden", WIETZMinStunden))
+		}
+		if monate < WIETZMinMonate || monate > WIETZMaxMonate {
+			errs = append(errs, fmt.Sprintf("Wiedereingliederungsteilzeit dauert %d bis %d Monate", WIETZMinMonate, WIETZMaxMonate))
+		}
+		neuesBrutto := float64(e.BisherBrutto) * e.NeueStunden / e.BisherStunden / 100
+		if elda.IsGeringfuegig(neuesBrutto, e.Von.Year()) {
+			errs = append(errs, "Entgelt während der Wiedereingliederungsteilzeit muss über der Geringfügigkeitsgrenze liegen")
+		}
+	default:
+		errs = append(errs, "modell muss altersteilzeit oder wiedereingliederung sein")
+	}
+
+	return errs
+}
+
+// AMSErsatzsatz returns the share in percent of the additional Altersteilzeit
+// costs refunded by the AMS. The block variant requires a replacement hire and
+// is phased out from 2024 until 2029.
+func AMSErsatzsatz(variante Variante, year int, ersatzkraft bool) float64 {
+	if variante == VarianteKontinuierlich {
+		return 90
+	}
+	if !ersatzkraft {
+		return 0
+	}
+	switch {
+	case year <= 2023:
+		return 50
+	case year >= 2029:
+		return 0
+	default:
+		return 50 - float64(year-2023)*7.5
+	}
+}
+
+// Monate returns the number of started months between von and bis (inclusive)
+func Monate(von, bis time.Time) int {
+	end := bis.AddDate(0, 0, 1)
+	months := (end.Year()-von.Year())*12 + int(end.Month()) - int(von.Month())
+	if von.AddDate(0, months, 0).After(end) {
+		months--
+	}
+	if von.AddDate(0, months, 0).Before(end) {
+		months++
+	}
+	return months
+}
+
+// MonthlyLohnsteuer estimates the Lohnsteuer of a monthly taxable income by
+// applying the annual tariff of the parameter set to twelve times the amount.
+// Sonderzahlungen and Absetzbeträge are not considered.
+func MonthlyLohnsteuer(p *refdata.Parameters, bemessung int64) int64 {
+	return int64(math.Round(float64(p.Lohnsteuer(bemessung*12)) / 12))
+}
+
+func monatswerte(p *refdata.Parameters, brutto, beitragsgrundlage int64) Monatswerte {
+	m := Monatswerte{
+		Brutto:            brutto,
+		Beitragsgrundlage: beitragsgrundlage,
+		SVDienstnehmer:    basisPoints(beitragsgrundlage, p.SVDienstnehmerSatz),
+		SVDienstgeber:     basisPoints(beitragsgrundlage, p.SVDienstgeberSatz),
+	}
+	m.Lohnsteuer = MonthlyLohnsteuer(p, brutto-m.SVDienstnehmer)
+	m.Netto = brutto - m.SVDienstnehmer - m.Lohnsteuer
+	m.Dienstgeberkosten = brutto + m.SVDienstgeber
+	return m
+}
+
+func basisPoints(amount int64, bp int64) int64 {
+	return int64(math.Round(float64(amount) * float64(bp) / 10000))
+}
@@ -0,0 +1,193 @@
+package teilzeit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/eldameldung"
+)
+
+// Handler handles HTTP requests for part-time scenarios
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new part-time scenario HTTP handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers part-time scenario routes with the router
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/teilzeit", func(r chi.Router) {
+		r.Post("/berechnen", h.Berechnen)
+
+		r.Post("/szenarien", h.Create)
+		r.Get("/szenarien", h.List)
+		r.Get("/szenarien/{id}", h.Get)
+		r.Delete("/szenarien/{id}", h.Delete)
+		r.Post("/szenarien/{id}/aenderungen", h.DraftAenderungen)
+	})
+}
+
+// Berechnen handles POST /api/v1/teilzeit/berechnen
+func (h *Handler) Berechnen(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var req BerechnungRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	sz, err := h.service.Berechnen(r.Context(), tenantID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to calculate scenario")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, sz)
+}
+
+// Create handles POST /api/v1/teilzeit/szenarien
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	var req BerechnungRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	sz, err := h.service.Create(r.Context(), tenantID, &req, api.OptionalUser(r))
+	if err != nil {
+		h.handleError(w, err, "Failed to store scenario")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusCreated, sz)
+}
+
+// List handles GET /api/v1/teilzeit/szenarien
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	accountID, err := uuid.Parse(r.URL.Query().Get("elda_account_id"))
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "elda_account_id is required", err)
+		return
+	}
+
+	szenarien, err := h.service.List(r.Context(), tenantID, accountID, r.URL.Query().Get("sv_nummer"))
+	if err != nil {
+		h.handleError(w, err, "Failed to list scenarios")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"szenarien": szenarien,
+		"count":     len(szenarien),
+	})
+}
+
+// Get handles GET /api/v1/teilzeit/szenarien/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid scenario ID", err)
+		return
+	}
+
+	sz, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		h.handleError(w, err, "Failed to get scenario")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusOK, sz)
+}
+
+// Delete handles DELETE /api/v1/teilzeit/szenarien/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid scenario ID", err)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), tenantID, id); err != nil {
+		h.handleError(w, err, "Failed to delete scenario")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DraftAenderungen handles POST /api/v1/teilzeit/szenarien/{id}/aenderungen
+func (h *Handler) DraftAenderungen(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, "Invalid scenario ID", err)
+		return
+	}
+
+	meldungen, err := h.service.DraftAenderungen(r.Context(), tenantID, id, api.OptionalUser(r))
+	if err != nil {
+		h.handleError(w, err, "Failed to draft Änderungsmeldungen")
+		return
+	}
+
+	api.RespondJSON(w, http.StatusCreated, map[string]interface{}{
+		"meldungen": meldungen,
+		"count":     len(meldungen),
+	})
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error, message string) {
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		api.RespondJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":  "Validation failed",
+			"errors": validationErr.Errors,
+		})
+	case errors.Is(err, ErrSzenarioNotFound), errors.Is(err, eldameldung.ErrMeldungNotFound),
+		errors.Is(err, ErrAccountNotFound):
+		api.RespondErrorWithDetails(w, http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, ErrAlreadyDrafted):
+		api.RespondErrorWithDetails(w, http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrNotEmployed), errors.Is(err, ErrMissingStammdaten):
+		api.RespondErrorWithDetails(w, http.StatusBadRequest, err.Error(), err)
+	default:
+		api.RespondErrorWithDetails(w, http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,187 @@
+package teilzeit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrSzenarioNotFound = errors.New("part-time scenario not found")
+	ErrAccountNotFound  = errors.New("ELDA account not found")
+)
+
+// Repository handles part-time scenario database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new part-time scenario repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const szenarioColumns = `
+	id, elda_account_id, sv_nummer, vorname, nachname, bezeichnung,
+	modell, eingabe, ergebnis, meldung_ids, created_by, created_at, updated_at`
+
+// tenantAccounts limits scenarios to the ELDA accounts of the tenant in the
+// given parameter
+const tenantAccounts = `elda_account_id IN (
+	SELECT ea.id FROM elda_accounts ea JOIN accounts a ON a.id = ea.account_id
+	WHERE a.tenant_id = %s)`
+
+// ELDAAccountBelongsToTenant reports whether an ELDA account is one of the
+// tenant's
+func (r *Repository) ELDAAccountBelongsToTenant(ctx context.Context, tenantID, eldaAccountID uuid.UUID) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM elda_accounts ea JOIN accounts a ON a.id = ea.account_id
+			WHERE ea.id = $1 AND a.tenant_id = $2)`, eldaAccountID, tenantID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("check ELDA account: %w", err)
+	}
+	return ok, nil
+}
+
+// Create stores a scenario
+func (r *Repository) Create(ctx context.Context, s *Szenario) error {
+	eingabeJSON, err := json.Marshal(s.Eingabe)
+	if err != nil {
+		return fmt.Errorf("marshal eingabe: %w", err)
+	}
+	ergebnisJSON, err := json.Marshal(s.Ergebnis)
+	if err != nil {
+		return fmt.Errorf("marshal ergebnis: %w", err)
+	}
+
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	if s.MeldungIDs == nil {
+		s.MeldungIDs = []uuid.UUID{}
+	}
+	now := time.Now()
+	s.CreatedAt = now
+	s.UpdatedAt = now
+
+	query := `
+		INSERT INTO teilzeit_szenarien (` + szenarioColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err = r.db.Exec(ctx, query,
+		s.ID, s.ELDAAccountID, s.SVNummer, s.Vorname, s.Nachname, s.Bezeichnung,
+		s.Eingabe.Modell, eingabeJSON, ergebnisJSON, s.MeldungIDs, s.CreatedBy, s.CreatedAt, s.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create part-time scenario: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a scenario of the tenant by ID
+func (r *Repository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*Szenario, error) {
+	query := `SELECT ` + szenarioColumns + ` FROM teilzeit_szenarien
+		WHERE id = $1 AND ` + fmt.Sprintf(tenantAccounts, "$2")
+
+	s, err := scanSzenario(r.db.QueryRow(ctx, query, id, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSzenarioNotFound
+		}
+		return nil, fmt.Errorf("get part-time scenario: %w", err)
+	}
+
+	return s, nil
+}
+
+// List lists the scenarios of one of the tenant's ELDA accounts, optionally
+// for one employee
+func (r *Repository) List(ctx context.Context, tenantID, accountID uuid.UUID, svNummer string) ([]*Szenario, error) {
+	query := `SELECT ` + szenarioColumns + `
+		FROM teilzeit_szenarien
+		WHERE elda_account_id = $1 AND ($2 = '' OR sv_nummer = $2)
+			AND ` + fmt.Sprintf(tenantAccounts, "$3") + `
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, accountID, svNummer, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list part-time scenarios: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*Szenario
+	for rows.Next() {
+		s, err := scanSzenario(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan part-time scenario: %w", err)
+		}
+		results = append(results, s)
+	}
+
+	return results, rows.Err()
+}
+
+// SetMeldungIDs records the Änderungsmeldungen drafted for a scenario
+func (r *Repository) SetMeldungIDs(ctx context.Context, id uuid.UUID, meldungIDs []uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `
+		UPDATE teilzeit_szenarien SET meldung_ids = $2, updated_at = NOW()
+		WHERE id = $1
+	`, id, meldungIDs)
+	if err != nil {
+		return fmt.Errorf("update part-time scenario: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrSzenarioNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a scenario of the tenant
+func (r *Repository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM teilzeit_szenarien WHERE id = $1 AND `+
+		fmt.Sprintf(tenantAccounts, "$2"), id, tenantID)
+	if err != nil {
+		return fmt.Errorf("delete part-time scenario: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrSzenarioNotFound
+	}
+
+	return nil
+}
+
+func scanSzenario(row pgx.Row) (*Szenario, error) {
+	s := &Szenario{}
+	var modell Modell
+	var eingabeJSON, ergebnisJSON []byte
+
+	err := row.Scan(
+		&s.ID, &s.ELDAAccountID, &s.SVNummer, &s.Vorname, &s.Nachname, &s.Bezeichnung,
+		&modell, &eingabeJSON, &ergebnisJSON, &s.MeldungIDs, &s.CreatedBy, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(eingabeJSON, &s.Eingabe); err != nil {
+		return nil, fmt.Errorf("unmarshal eingabe: %w", err)
+	}
+	if err := json.Unmarshal(ergebnisJSON, &s.Ergebnis); err != nil {
+		return nil, fmt.Errorf("unmarshal ergebnis: %w", err)
+	}
+
+	return s, nil
+}
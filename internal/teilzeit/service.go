@@ -0,0 +1,313 @@
+package teilzeit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/abwesenheit"
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/eldameldung"
+)
+
+var (
+	ErrNotEmployed       = errors.New("no employment according to ELDA at the start of the part-time")
+	ErrAlreadyDrafted    = errors.New("scenario already has drafted Änderungsmeldungen")
+	ErrMissingStammdaten = errors.New("previous hours and gross pay are not known from ELDA; provide them explicitly")
+)
+
+// Service handles part-time scenario business logic
+type Service struct {
+	repo            *Repository
+	meldungRepo     *eldameldung.Repository
+	abwesenheitRepo *abwesenheit.Repository
+	logger          *slog.Logger
+}
+
+// ServiceConfig contains configuration for the part-time scenario service
+type ServiceConfig struct {
+	Repository            *Repository
+	MeldungRepository     *eldameldung.Repository
+	AbwesenheitRepository *abwesenheit.Repository // Optional, checks the Krankenstand before a Wiedereingliederung
+	Logger                *slog.Logger
+}
+
+// NewService creates a new part-time scenario service
+func NewService(cfg ServiceConfig) *Service {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Service{
+		repo:            cfg.Repository,
+		meldungRepo:     cfg.MeldungRepository,
+		abwesenheitRepo: cfg.AbwesenheitRepository,
+		logger:          logger,
+	}
+}
+
+// ValidationError holds the messages of a failed scenario validation
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 0 {
+		return "validation failed"
+	}
+	return fmt.Sprintf("validation failed: %s", e.Errors[0])
+}
+
+// Berechnen calculates a scenario of one of the tenant's employees without
+// storing it
+func (s *Service) Berechnen(ctx context.Context, tenantID uuid.UUID, req *BerechnungRequest) (*Szenario, error) {
+	ok, err := s.repo.ELDAAccountBelongsToTenant(ctx, tenantID, req.ELDAAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+
+	von, err := time.Parse("2006-01-02", req.Von)
+	if err != nil {
+		return nil, &ValidationError{Errors: []string{"von: ungültiges Datum"}}
+	}
+	bis, err := time.Parse("2006-01-02", req.Bis)
+	if err != nil {
+		return nil, &ValidationError{Errors: []string{"bis: ungültiges Datum"}}
+	}
+
+	history, err := s.meldungRepo.GetHistoryBySVNummer(ctx, req.ELDAAccountID, req.SVNummer)
+	if err != nil {
+		return nil, err
+	}
+
+	employed := false
+	for _, p := range abwesenheit.Beschaeftigungszeitraeume(history, req.SVNummer) {
+		if p.Contains(von, &bis) {
+			employed = true
+			break
+		}
+	}
+	if !employed {
+		return nil, ErrNotEmployed
+	}
+
+	sz := &Szenario{
+		ELDAAccountID: req.ELDAAccountID,
+		SVNummer:      req.SVNummer,
+		Bezeichnung:   req.Bezeichnung,
+		Eingabe: Eingabe{
+			Modell:        req.Modell,
+			Variante:      req.Variante,
+			Von:           von,
+			Bis:           bis,
+			BisherStunden: req.BisherStunden,
+			NeueStunden:   req.NeueStunden,
+			BisherBrutto:  req.BisherBrutto,
+			Ersatzkraft:   req.Ersatzkraft,
+		},
+	}
+
+	if last := lastStammdaten(history); last != nil {
+		sz.Vorname = last.Vorname
+		sz.Nachname = last.Nachname
+		if sz.Eingabe.BisherStunden == 0 && last.Arbeitszeit != nil {
+			sz.Eingabe.BisherStunden = last.Arbeitszeit.WochenStunden
+		}
+		if sz.Eingabe.BisherBrutto == 0 && last.Entgelt != nil {
+			sz.Eingabe.BisherBrutto = last.Entgelt.BruttoMonatlich
+		}
+	}
+	if sz.Eingabe.BisherStunden == 0 || sz.Eingabe.BisherBrutto == 0 {
+		return nil, ErrMissingStammdaten
+	}
+
+	ergebnis, errs := Calculate(&sz.Eingabe)
+	if len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+	sz.Ergebnis = *ergebnis
+
+	if sz.Eingabe.Modell == ModellWiedereingliederung {
+		hinweis, err := s.checkKrankenstand(ctx, sz)
+		if err != nil {
+			return nil, err
+		}
+		if hinweis != "" {
+			sz.Ergebnis.Hinweise = append(sz.Ergebnis.Hinweise, hinweis)
+		}
+	}
+
+	return sz, nil
+}
+
+// lastStammdaten returns the latest accepted Anmeldung or Änderungsmeldung
+// carrying working time or pay. History is ordered newest first.
+func lastStammdaten(history []*elda.ELDAMeldung) *elda.ELDAMeldung {
+	var anmeldung *elda.ELDAMeldung
+	for _, m := range history {
+		if m.Status != elda.MeldungStatusAccepted {
+			continue
+		}
+		if m.Type == elda.MeldungTypeAenderung && (m.Arbeitszeit != nil || m.Entgelt != nil) {
+			return m
+		}
+		if m.Type == elda.MeldungTypeAnmeldung {
+			anmeldung = m
+			break
+		}
+	}
+	return anmeldung
+}
+
+// checkKrankenstand returns a hint if no Arbeitsunfähigkeit of at least six
+// weeks ends right before the Wiedereingliederungsteilzeit
+func (s *Service) checkKrankenstand(ctx context.Context, sz *Szenario) (string, error) {
+	if s.abwesenheitRepo == nil {
+		return "", nil
+	}
+
+	absences, err := s.abwesenheitRepo.List(ctx, abwesenheit.ListFilter{
+		ELDAAccountID: sz.ELDAAccountID,
+		SVNummer:      sz.SVNummer,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, a := range absences {
+		if !a.Art.IsArbeitsunfaehigkeit() || a.Bis == nil || !a.Bis.Before(sz.Eingabe.Von) {
+			continue
+		}
+		if a.Bis.Sub(a.Von) >= 41*24*time.Hour && sz.Eingabe.Von.Sub(*a.Bis) <= 31*24*time.Hour {
+			return "", nil
+		}
+	}
+
+	return "Kein erfasster Krankenstand von mindestens 6 Wochen unmittelbar vor der Wiedereingliederungsteilzeit", nil
+}
+
+// Create calculates and stores a scenario
+func (s *Service) Create(ctx context.Context, tenantID uuid.UUID, req *BerechnungRequest, createdBy *uuid.UUID) (*Szenario, error) {
+	sz, err := s.Berechnen(ctx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+	sz.CreatedBy = createdBy
+
+	if err := s.repo.Create(ctx, sz); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("part-time scenario stored",
+		"id", sz.ID,
+		"elda_account_id", sz.ELDAAccountID,
+		"modell", sz.Eingabe.Modell)
+
+	return sz, nil
+}
+
+// Get returns a scenario of the tenant
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Szenario, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
+}
+
+// List lists the scenarios of one of the tenant's ELDA accounts
+func (s *Service) List(ctx context.Context, tenantID, accountID uuid.UUID, svNummer string) ([]*Szenario, error) {
+	ok, err := s.repo.ELDAAccountBelongsToTenant(ctx, tenantID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+	return s.repo.List(ctx, tenantID, accountID, svNummer)
+}
+
+// Delete deletes a scenario of the tenant; drafted Änderungsmeldungen are kept
+func (s *Service) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+// DraftAenderungen pre-drafts the Änderungsmeldungen of a scenario: one at the
+// start of the part-time and, for a Wiedereingliederung, one for the return to
+// the previous hours. The drafts go through the regular ELDA Meldung workflow.
+func (s *Service) DraftAenderungen(ctx context.Context, tenantID, id uuid.UUID, createdBy *uuid.UUID) ([]*elda.ELDAMeldung, error) {
+	sz, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(sz.MeldungIDs) > 0 {
+		return nil, ErrAlreadyDrafted
+	}
+
+	history, err := s.meldungRepo.GetHistoryBySVNummer(ctx, sz.ELDAAccountID, sz.SVNummer)
+	if err != nil {
+		return nil, err
+	}
+
+	meldungen := BuildAenderungen(sz, lastStammdaten(history), createdBy)
+
+	ids := make([]uuid.UUID, 0, len(meldungen))
+	for _, m := range meldungen {
+		if err := s.meldungRepo.Create(ctx, m); err != nil {
+			return nil, fmt.Errorf("failed to create Änderungsmeldung: %w", err)
+		}
+		ids = append(ids, m.ID)
+	}
+
+	if err := s.repo.SetMeldungIDs(ctx, sz.ID, ids); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Änderungsmeldungen drafted for part-time scenario",
+		"id", sz.ID,
+		"count", len(ids))
+
+	return meldungen, nil
+}
+
+// BuildAenderungen builds the draft Änderungsmeldungen of a scenario
+func BuildAenderungen(sz *Szenario, stammdaten *elda.ELDAMeldung, createdBy *uuid.UUID) []*elda.ELDAMeldung {
+	newMeldung := func(datum time.Time, stunden float64, brutto int64) *elda.ELDAMeldung {
+		m := &elda.ELDAMeldung{
+			ID:             uuid.New(),
+			ELDAAccountID:  sz.ELDAAccountID,
+			Type:           elda.MeldungTypeAenderung,
+			Status:         elda.MeldungStatusDraft,
+			SVNummer:       sz.SVNummer,
+			Vorname:        sz.Vorname,
+			Nachname:       sz.Nachname,
+			AenderungArt:   "MEHRFACH",
+			AenderungDatum: &datum,
+			Arbeitszeit:    &elda.ExtendedArbeitszeit{WochenStunden: stunden},
+			Entgelt:        &elda.ExtendedEntgelt{BruttoMonatlich: brutto},
+			CreatedBy:      createdBy,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}
+		if stammdaten != nil {
+			m.Geburtsdatum = stammdaten.Geburtsdatum
+			m.Geschlecht = stammdaten.Geschlecht
+			m.OriginalMeldungID = &stammdaten.ID
+		}
+		return m
+	}
+
+	meldungen := []*elda.ELDAMeldung{
+		newMeldung(sz.Eingabe.Von, sz.Eingabe.NeueStunden, sz.Ergebnis.Teilzeit.Brutto),
+	}
+	if sz.Eingabe.Modell == ModellWiedereingliederung {
+		meldungen = append(meldungen,
+			newMeldung(sz.Eingabe.Bis.AddDate(0, 0, 1), sz.Eingabe.BisherStunden, sz.Eingabe.BisherBrutto))
+	}
+
+	return meldungen
+}
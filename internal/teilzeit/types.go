@@ -0,0 +1,106 @@
+package teilzeit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Modell is the part-time model of a scenario
+type Modell string
+
+const (
+	ModellAltersteilzeit      Modell = "altersteilzeit"      // § 27 AlVG
+	ModellWiedereingliederung Modell = "wiedereingliederung" // § 13a AVRAG
+)
+
+// Variante is the working time distribution of an Altersteilzeit
+type Variante string
+
+const (
+	VarianteKontinuierlich Variante = "kontinuierlich"
+	VarianteBlock          Variante = "block"
+)
+
+// Eingabe contains the inputs of a part-time calculation. Amounts are in cents.
+type Eingabe struct {
+	Modell        Modell    `json:"modell"`
+	Variante      Variante  `json:"variante,omitempty"` // Altersteilzeit only
+	Von           time.Time `json:"von"`
+	Bis           time.Time `json:"bis"`
+	BisherStunden float64   `json:"bisher_stunden"` // Weekly hours before the reduction
+	NeueStunden   float64   `json:"neue_stunden"`   // Weekly hours during the part-time
+	BisherBrutto  int64     `json:"bisher_brutto"`  // Monthly gross before the reduction
+	Ersatzkraft   bool      `json:"ersatzkraft,omitempty"`
+}
+
+// Monatswerte are the monthly amounts of one side of a comparison, in cents
+type Monatswerte struct {
+	Brutto            int64 `json:"brutto"`
+	Beitragsgrundlage int64 `json:"beitragsgrundlage"`
+	SVDienstnehmer    int64 `json:"sv_dienstnehmer"`
+	Lohnsteuer        int64 `json:"lohnsteuer"`
+	Netto             int64 `json:"netto"`
+	SVDienstgeber     int64 `json:"sv_dienstgeber"`
+	Dienstgeberkosten int64 `json:"dienstgeberkosten"`
+}
+
+// Ergebnis is the outcome of a part-time calculation. Amounts are monthly in cents.
+type Ergebnis struct {
+	Reduktion float64     `json:"reduktion"` // Reduction of working time in percent
+	Monate    int         `json:"monate"`
+	Bisher    Monatswerte `json:"bisher"`
+	Teilzeit  Monatswerte `json:"teilzeit"`
+
+	// Altersteilzeit
+	Lohnausgleich      int64   `json:"lohnausgleich,omitempty"`
+	Mehrbeitraege      int64   `json:"mehrbeitraege,omitempty"`      // SV on the difference to the previous Beitragsgrundlage, borne by the employer
+	AMSErsatzsatz      float64 `json:"ams_ersatzsatz,omitempty"`     // Share of the additional costs refunded by the AMS, in percent
+	Altersteilzeitgeld int64   `json:"altersteilzeitgeld,omitempty"` // AMS refund to the employer
+
+	// Wiedereingliederungsteilzeit
+	Wiedereingliederungsgeld int64 `json:"wiedereingliederungsgeld,omitempty"` // Paid by the ÖGK to the employee
+
+	// Net effects
+	NettoDienstnehmer int64 `json:"netto_dienstnehmer"` // Net pay plus Wiedereingliederungsgeld
+	NettoDifferenz    int64 `json:"netto_differenz"`    // Compared to before the reduction
+	KostenDienstgeber int64 `json:"kosten_dienstgeber"` // After AMS refund
+	KostenDifferenz   int64 `json:"kosten_differenz"`   // Compared to before the reduction
+	KostenGesamtdauer int64 `json:"kosten_gesamtdauer"` // KostenDifferenz over the whole period
+
+	Hinweise []string `json:"hinweise,omitempty"`
+}
+
+// Szenario is a stored part-time scenario of an employee
+type Szenario struct {
+	ID            uuid.UUID   `json:"id" db:"id"`
+	ELDAAccountID uuid.UUID   `json:"elda_account_id" db:"elda_account_id"`
+	SVNummer      string      `json:"sv_nummer" db:"sv_nummer"`
+	Vorname       string      `json:"vorname" db:"vorname"`
+	Nachname      string      `json:"nachname" db:"nachname"`
+	Bezeichnung   string      `json:"bezeichnung" db:"bezeichnung"`
+	Eingabe       Eingabe     `json:"eingabe" db:"eingabe"`
+	Ergebnis      Ergebnis    `json:"ergebnis" db:"ergebnis"`
+	MeldungIDs    []uuid.UUID `json:"meldung_ids,omitempty" db:"meldung_ids"` // Pre-drafted Änderungsmeldungen
+
+	// Audit
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// BerechnungRequest is the request of a part-time calculation. Hours and gross
+// pay default to the last accepted Anmeldung or Änderungsmeldung.
+type BerechnungRequest struct {
+	ELDAAccountID uuid.UUID `json:"elda_account_id"`
+	SVNummer      string    `json:"sv_nummer"`
+	Bezeichnung   string    `json:"bezeichnung,omitempty"`
+	Modell        Modell    `json:"modell"`
+	Variante      Variante  `json:"variante,omitempty"`
+	Von           string    `json:"von"` // YYYY-MM-DD
+	Bis           string    `json:"bis"` // YYYY-MM-DD
+	NeueStunden   float64   `json:"neue_stunden"`
+	BisherStunden float64   `json:"bisher_stunden,omitempty"`
+	BisherBrutto  int64     `json:"bisher_brutto,omitempty"` // cents
+	Ersatzkraft   bool      `json:"ersatzkraft,omitempty"`
+}
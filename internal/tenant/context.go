@@ -27,6 +27,7 @@ const (
 	tenantIDKey contextKey = iota
 	userIDKey
 	roleKey
+	hostTenantIDKey
 )
 
 // WithTenantID returns a new context with the tenant ID set.
@@ -64,6 +65,29 @@ func RequireTenantID(ctx context.Context) (uuid.UUID, error) {
 	return id, nil
 }
 
+// WithHostTenantID returns a new context with the tenant whose custom
+// domain the request was made to.
+func WithHostTenantID(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return context.WithValue(ctx, hostTenantIDKey, tenantID)
+}
+
+// GetHostTenantID retrieves the tenant whose custom domain the request was
+// made to. Returns uuid.Nil for requests to the platform's own hosts.
+func GetHostTenantID(ctx context.Context) uuid.UUID {
+	if id, ok := ctx.Value(hostTenantIDKey).(uuid.UUID); ok {
+		return id
+	}
+	return uuid.Nil
+}
+
+// ServesTenant reports whether a public request may see resources of a
+// tenant: a custom domain serves only its own tenant, the platform's hosts
+// serve every tenant.
+func ServesTenant(ctx context.Context, tenantID uuid.UUID) bool {
+	host := GetHostTenantID(ctx)
+	return host == uuid.Nil || host == tenantID
+}
+
 // WithUserID returns a new context with the user ID set.
 func WithUserID(ctx context.Context, userID uuid.UUID) context.Context {
 	return context.WithValue(ctx, userIDKey, userID)
@@ -0,0 +1,110 @@
+package tenant
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// Handler handles the tenant settings HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new tenant handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the tenant settings routes
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/tenant/timezone", requireAuth(http.HandlerFunc(h.GetTimezone)))
+	router.Handle("PUT /api/v1/tenant/timezone", requireAuth(requireAdmin(http.HandlerFunc(h.UpdateTimezone))))
+}
+
+// TimezoneResponse is a tenant's time zone and the current time there
+type TimezoneResponse struct {
+	Timezone  string `json:"timezone"`
+	IsDefault bool   `json:"is_default"`
+	Today     string `json:"today"`
+	LocalTime string `json:"local_time"`
+}
+
+// UpdateTimezoneRequest sets a tenant's time zone; "" resets it to the default
+type UpdateTimezoneRequest struct {
+	Timezone string `json:"timezone"`
+}
+
+// GetTimezone handles GET /api/v1/tenant/timezone
+func (h *Handler) GetTimezone(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.getTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	t, err := h.service.GetByID(r.Context(), tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, toTimezoneResponse(t))
+}
+
+// UpdateTimezone handles PUT /api/v1/tenant/timezone
+func (h *Handler) UpdateTimezone(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := h.getTenantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateTimezoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	t, err := h.service.UpdateTimezone(r.Context(), tenantID, req.Timezone)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, toTimezoneResponse(t))
+}
+
+func (h *Handler) getTenantID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return uuid.Nil, false
+	}
+	return tenantID, true
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrTenantNotFound):
+		api.NotFound(w, "tenant not found")
+	case errors.Is(err, ErrInvalidTimezone):
+		api.BadRequest(w, "timezone must be an IANA time zone such as Europe/Vienna")
+	default:
+		api.InternalError(w)
+	}
+}
+
+func toTimezoneResponse(t *Tenant) *TimezoneResponse {
+	loc := t.Location()
+	now := time.Now().In(loc)
+	_, set := t.Settings[SettingTimezone]
+	return &TimezoneResponse{
+		Timezone:  loc.String(),
+		IsDefault: !set,
+		Today:     now.Format("2006-01-02"),
+		LocalTime: now.Format(time.RFC3339),
+	}
+}
@@ -14,6 +14,7 @@ var (
 	ErrTenantNotFound      = errors.New("tenant not found")
 	ErrTenantSlugExists    = errors.New("tenant slug already exists")
 	ErrInvalidTenantSlug   = errors.New("invalid tenant slug format")
+	ErrInvalidTimezone     = errors.New("invalid time zone")
 )
 
 // Tenant represents a tenant/organization
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
+	"austrian-business-infrastructure/internal/analytics"
+	"austrian-business-infrastructure/internal/timezone"
 	"austrian-business-infrastructure/internal/user"
 	"austrian-business-infrastructure/pkg/crypto"
 	"github.com/google/uuid"
@@ -34,6 +37,7 @@ type Service struct {
 	tenantRepo *Repository
 	userRepo   *user.Repository
 	pool       *pgxpool.Pool
+	analytics  *analytics.Emitter
 }
 
 // NewService creates a new tenant service
@@ -45,6 +49,11 @@ func NewService(pool *pgxpool.Pool, tenantRepo *Repository, userRepo *user.Repos
 	}
 }
 
+// SetAnalytics reports newly registered tenants to the product analytics
+func (s *Service) SetAnalytics(emitter *analytics.Emitter) {
+	s.analytics = emitter
+}
+
 // CreateWithOwner creates a new tenant with an owner user in a transaction
 func (s *Service) CreateWithOwner(ctx context.Context, input *CreateTenantInput) (*CreateTenantResult, error) {
 	// Validate tenant slug
@@ -138,6 +147,7 @@ func (s *Service) CreateWithOwner(ctx context.Context, input *CreateTenantInput)
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	s.analytics.Track(ctx, tenant.ID, owner.ID, analytics.EventTenantCreated, nil)
 	return &CreateTenantResult{
 		Tenant: tenant,
 		Owner:  owner,
@@ -149,6 +159,76 @@ func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*Tenant, error) {
 	return s.tenantRepo.GetByID(ctx, id)
 }
 
+// SettingLocale is the tenant setting holding its locale, e.g. "de-AT"
+const SettingLocale = "locale"
+
+// DefaultLocale is the locale of tenants without a locale setting
+const DefaultLocale = "de-AT"
+
+// Locale returns the locale a tenant's exports are formatted in. Tenants
+// without a setting, or that cannot be loaded, get the default.
+func (s *Service) Locale(ctx context.Context, id uuid.UUID) string {
+	t, err := s.tenantRepo.GetByID(ctx, id)
+	if err != nil {
+		return DefaultLocale
+	}
+	if locale, ok := t.Settings[SettingLocale].(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// SettingTimezone is the tenant setting holding its IANA time zone, e.g.
+// "Europe/Vienna"
+const SettingTimezone = "timezone"
+
+// Location returns the time zone a tenant's calendar days are counted in:
+// deadlines, "today" and day boundaries. Tenants without a valid setting,
+// or that cannot be loaded, get Europe/Vienna.
+func (s *Service) Location(ctx context.Context, id uuid.UUID) *time.Location {
+	t, err := s.tenantRepo.GetByID(ctx, id)
+	if err != nil {
+		return timezone.Vienna
+	}
+	return t.Location()
+}
+
+// Location returns the tenant's time zone setting, Europe/Vienna if it has
+// none or an invalid one
+func (t *Tenant) Location() *time.Location {
+	name, _ := t.Settings[SettingTimezone].(string)
+	loc, err := timezone.Load(name)
+	if err != nil {
+		return timezone.Vienna
+	}
+	return loc
+}
+
+// UpdateTimezone sets a tenant's time zone; "" resets it to Europe/Vienna
+func (s *Service) UpdateTimezone(ctx context.Context, id uuid.UUID, name string) (*Tenant, error) {
+	if _, err := timezone.Load(name); err != nil {
+		return nil, ErrInvalidTimezone
+	}
+
+	t, err := s.tenantRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Settings == nil {
+		t.Settings = make(map[string]interface{})
+	}
+	if name == "" {
+		delete(t.Settings, SettingTimezone)
+	} else {
+		t.Settings[SettingTimezone] = name
+	}
+
+	if err := s.tenantRepo.Update(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
 // GetBySlug retrieves a tenant by slug
 func (s *Service) GetBySlug(ctx context.Context, slug string) (*Tenant, error) {
 	return s.tenantRepo.GetBySlug(ctx, normalizeSlug(slug))
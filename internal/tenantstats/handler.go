@@ -0,0 +1,99 @@
+package tenantstats
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/audit"
+)
+
+// SuperAdmins tells the users allowed to read any tenant's stats;
+// breakglass.Service implements it
+type SuperAdmins interface {
+	IsSuperAdmin(userID uuid.UUID) bool
+}
+
+// Handler handles tenant stats HTTP requests
+type Handler struct {
+	service     *Service
+	superAdmins SuperAdmins
+	auditLogger *audit.Logger
+}
+
+// NewHandler creates a new tenant stats handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// SetSuperAdmins lets super-admins read the stats of every tenant. Each
+// read is written to the audit log of the tenant read, so it needs the
+// audit logger.
+func (h *Handler) SetSuperAdmins(superAdmins SuperAdmins, auditLogger *audit.Logger) {
+	h.superAdmins = superAdmins
+	h.auditLogger = auditLogger
+}
+
+// RegisterRoutes registers tenant stats routes. Tenant admins read their
+// own tenant; super-admins are checked by the handler.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/admin/tenants/{id}/stats", requireAuth(http.HandlerFunc(h.GetStats)))
+}
+
+// GetStats handles GET /api/v1/admin/tenants/{id}/stats
+func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid tenant ID")
+		return
+	}
+
+	if err := h.authorize(r, tenantID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	stats, err := h.service.Get(r.Context(), tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, stats)
+}
+
+// authorize allows the admins of the tenant and super-admins. A super-admin
+// reading another tenant is audit-logged in that tenant.
+func (h *Handler) authorize(r *http.Request, tenantID uuid.UUID) error {
+	role := api.GetUserRole(r.Context())
+	if api.GetTenantID(r.Context()) == tenantID.String() && (role == "owner" || role == "admin") {
+		return nil
+	}
+
+	userID, err := uuid.Parse(api.GetUserID(r.Context()))
+	if err != nil || h.superAdmins == nil || h.auditLogger == nil || !h.superAdmins.IsSuperAdmin(userID) {
+		return ErrForbidden
+	}
+
+	logCtx := audit.ContextFromRequest(r)
+	logCtx.TenantID = &tenantID
+	resourceType := "tenant"
+	logCtx.ResourceType = &resourceType
+	logCtx.ResourceID = &tenantID
+	return h.auditLogger.Log(r.Context(), logCtx, ActionViewed, map[string]interface{}{
+		"caller_tenant_id": api.GetTenantID(r.Context()),
+	})
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrForbidden):
+		api.Forbidden(w, "access denied")
+	case errors.Is(err, ErrTenantNotFound):
+		api.NotFound(w, "tenant not found")
+	default:
+		api.InternalError(w)
+	}
+}
@@ -0,0 +1,179 @@
+package tenantstats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository reads the stats of a tenant across the other modules' tables
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new tenant stats repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// entityCounts count the entities of a tenant ($1) by name
+var entityCounts = []struct{ name, query string }{
+	{"users", `SELECT COUNT(*) FROM users WHERE tenant_id = $1 AND is_active`},
+	{"accounts", `SELECT COUNT(*) FROM accounts WHERE tenant_id = $1 AND deleted_at IS NULL`},
+	{"documents", `SELECT COUNT(*) FROM documents WHERE tenant_id = $1`},
+	{"clients", `SELECT COUNT(*) FROM clients WHERE tenant_id = $1`},
+	{"invoices", `SELECT COUNT(*) FROM invoices WHERE tenant_id = $1`},
+	{"sales_documents", `SELECT COUNT(*) FROM sales_documents WHERE tenant_id = $1`},
+	{"projects", `SELECT COUNT(*) FROM projects WHERE tenant_id = $1`},
+	{"contracts", `SELECT COUNT(*) FROM contracts WHERE tenant_id = $1`},
+	{"business_partners", `SELECT COUNT(*) FROM business_partners WHERE tenant_id = $1`},
+	{"teams", `SELECT COUNT(*) FROM teams WHERE tenant_id = $1`},
+	{"api_keys", `SELECT COUNT(*) FROM api_keys WHERE tenant_id = $1 AND is_active`},
+	{"webhooks", `SELECT COUNT(*) FROM webhooks WHERE tenant_id = $1`},
+}
+
+// GetTenant returns the name and creation time of a tenant
+func (r *Repository) GetTenant(ctx context.Context, tenantID uuid.UUID) (string, time.Time, error) {
+	var name string
+	var createdAt time.Time
+	err := r.db.QueryRow(ctx, `SELECT name, created_at FROM tenants WHERE id = $1`, tenantID).Scan(&name, &createdAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", time.Time{}, ErrTenantNotFound
+	}
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("get tenant: %w", err)
+	}
+	return name, createdAt, nil
+}
+
+// CountEntities returns the number of each entity of a tenant
+func (r *Repository) CountEntities(ctx context.Context, tenantID uuid.UUID) (map[string]int64, error) {
+	parts := make([]string, 0, len(entityCounts))
+	for _, e := range entityCounts {
+		parts = append(parts, fmt.Sprintf(`SELECT '%s', (%s)`, e.name, e.query))
+	}
+	rows, err := r.db.Query(ctx, strings.Join(parts, "\n\t\tUNION ALL "), tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("count entities: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64, len(entityCounts))
+	for rows.Next() {
+		var name string
+		var count int64
+		if err := rows.Scan(&name, &count); err != nil {
+			return nil, fmt.Errorf("scan entity count: %w", err)
+		}
+		counts[name] = count
+	}
+	return counts, rows.Err()
+}
+
+// GetActivity returns the last activity timestamps of a tenant
+func (r *Repository) GetActivity(ctx context.Context, tenantID uuid.UUID) (*Activity, error) {
+	a := &Activity{}
+	err := r.db.QueryRow(ctx, `
+		SELECT
+			(SELECT MAX(last_login_at) FROM users WHERE tenant_id = $1),
+			(SELECT MAX(last_used_at) FROM api_keys WHERE tenant_id = $1),
+			(SELECT MAX(created_at) FROM audit_logs WHERE tenant_id = $1),
+			(SELECT MAX(created_at) FROM documents WHERE tenant_id = $1),
+			(SELECT MAX(last_sync_at) FROM accounts WHERE tenant_id = $1 AND deleted_at IS NULL),
+			(SELECT MAX(completed_at) FROM jobs WHERE tenant_id = $1 AND status = 'completed')`,
+		tenantID,
+	).Scan(&a.LastLoginAt, &a.LastAPIKeyUseAt, &a.LastAuditEventAt, &a.LastDocumentAt, &a.LastSyncAt, &a.LastJobAt)
+	if err != nil {
+		return nil, fmt.Errorf("get activity: %w", err)
+	}
+	return a, nil
+}
+
+// ListIntegrations returns the accounts and webhooks of a tenant with their
+// connection state. A webhook whose last delivery failed is failing.
+func (r *Repository) ListIntegrations(ctx context.Context, tenantID uuid.UUID) ([]*Integration, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, type, name, COALESCE(status, ''), last_verified_at, last_sync_at, COALESCE(error_message, '')
+		FROM accounts
+		WHERE tenant_id = $1 AND deleted_at IS NULL
+		UNION ALL
+		SELECT w.id, '`+IntegrationWebhook+`', w.name,
+			CASE
+				WHEN NOT w.enabled THEN '`+WebhookDisabled+`'
+				WHEN d.status = 'failed' THEN '`+WebhookFailing+`'
+				ELSE '`+WebhookOK+`'
+			END,
+			NULL, d.delivered_at, CASE WHEN d.status = 'failed' THEN COALESCE(d.last_error, '') ELSE '' END
+		FROM webhooks w
+		LEFT JOIN LATERAL (
+			SELECT status, last_error, delivered_at FROM webhook_deliveries
+			WHERE webhook_id = w.id AND status <> 'pending'
+			ORDER BY created_at DESC LIMIT 1
+		) d ON true
+		WHERE w.tenant_id = $1
+		ORDER BY 2, 3`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list integrations: %w", err)
+	}
+	defer rows.Close()
+
+	integrations := []*Integration{}
+	for rows.Next() {
+		i := &Integration{}
+		if err := rows.Scan(&i.ID, &i.Type, &i.Name, &i.Status, &i.LastVerifiedAt, &i.LastSyncAt, &i.Error); err != nil {
+			return nil, fmt.Errorf("scan integration: %w", err)
+		}
+		integrations = append(integrations, i)
+	}
+	return integrations, rows.Err()
+}
+
+// GetJobBacklog counts the pending, running, failed and dead jobs of a
+// tenant, in total and per type. Failures count for the last 24 hours.
+func (r *Repository) GetJobBacklog(ctx context.Context, tenantID uuid.UUID, now time.Time) (*JobBacklog, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT type,
+			COUNT(*) FILTER (WHERE status = 'pending'),
+			COUNT(*) FILTER (WHERE status = 'pending' AND run_at <= $2),
+			COUNT(*) FILTER (WHERE status = 'running'),
+			COUNT(*) FILTER (WHERE status = 'failed' AND updated_at >= $2 - INTERVAL '24 hours'),
+			COUNT(*) FILTER (WHERE status = 'dead'),
+			MIN(run_at) FILTER (WHERE status = 'pending')
+		FROM jobs
+		WHERE tenant_id = $1 AND status IN ('pending', 'running', 'failed', 'dead')
+		GROUP BY type
+		ORDER BY type`, tenantID, now)
+	if err != nil {
+		return nil, fmt.Errorf("get job backlog: %w", err)
+	}
+	defer rows.Close()
+
+	backlog := &JobBacklog{ByType: []*JobTypeCount{}}
+	for rows.Next() {
+		t := &JobTypeCount{}
+		var overdue int64
+		var oldest *time.Time
+		if err := rows.Scan(&t.Type, &t.Pending, &overdue, &t.Running, &t.Failed24h, &t.Dead, &oldest); err != nil {
+			return nil, fmt.Errorf("scan job backlog: %w", err)
+		}
+		if t.Pending+t.Running+t.Failed24h+t.Dead == 0 {
+			continue // Only failures older than a day
+		}
+		backlog.Pending += t.Pending
+		backlog.Overdue += overdue
+		backlog.Running += t.Running
+		backlog.Failed24h += t.Failed24h
+		backlog.Dead += t.Dead
+		if oldest != nil && (backlog.OldestPendingAt == nil || oldest.Before(*backlog.OldestPendingAt)) {
+			backlog.OldestPendingAt = oldest
+		}
+		backlog.ByType = append(backlog.ByType, t)
+	}
+	return backlog, rows.Err()
+}
@@ -0,0 +1,63 @@
+package tenantstats
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/quota"
+)
+
+// StorageUsage reports a tenant's storage against its quota;
+// quota.Service implements it
+type StorageUsage interface {
+	GetUsage(ctx context.Context, tenantID uuid.UUID) (*quota.Usage, error)
+}
+
+// Service collects the stats of a tenant
+type Service struct {
+	repo    *Repository
+	storage StorageUsage
+	now     func() time.Time
+}
+
+// NewService creates a new tenant stats service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// SetStorage adds the storage usage to the stats
+func (s *Service) SetStorage(storage StorageUsage) {
+	s.storage = storage
+}
+
+// Get returns the entity counts, storage usage, last activity, integration
+// states and job backlog of a tenant
+func (s *Service) Get(ctx context.Context, tenantID uuid.UUID) (*Stats, error) {
+	name, createdAt, err := s.repo.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	stats := &Stats{TenantID: tenantID, TenantName: name, CreatedAt: createdAt, GeneratedAt: now}
+
+	if stats.Entities, err = s.repo.CountEntities(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	if s.storage != nil {
+		if stats.Storage, err = s.storage.GetUsage(ctx, tenantID); err != nil {
+			return nil, err
+		}
+	}
+	if stats.Activity, err = s.repo.GetActivity(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	if stats.Integrations, err = s.repo.ListIntegrations(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	if stats.Jobs, err = s.repo.GetJobBacklog(ctx, tenantID, now); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
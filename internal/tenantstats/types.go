@@ -0,0 +1,87 @@
+package tenantstats
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/quota"
+)
+
+var (
+	ErrTenantNotFound = errors.New("tenant not found")
+	ErrForbidden      = errors.New("tenant stats require an admin of the tenant or a super-admin")
+)
+
+// ActionViewed is the audit action written to a tenant's audit log when a
+// super-admin of another tenant reads its stats
+const ActionViewed = "tenant_stats_viewed"
+
+// Integration types
+const (
+	IntegrationWebhook = "webhook"
+)
+
+// Webhook integration states; accounts report their own status
+const (
+	WebhookDisabled = "disabled"
+	WebhookFailing  = "failing"
+	WebhookOK       = "ok"
+)
+
+// Stats is the overview of a tenant support uses to triage problems
+type Stats struct {
+	TenantID     uuid.UUID        `json:"tenant_id"`
+	TenantName   string           `json:"tenant_name"`
+	CreatedAt    time.Time        `json:"created_at"`
+	Entities     map[string]int64 `json:"entities"`
+	Storage      *quota.Usage     `json:"storage,omitempty"`
+	Activity     *Activity        `json:"activity"`
+	Integrations []*Integration   `json:"integrations"`
+	Jobs         *JobBacklog      `json:"jobs"`
+	GeneratedAt  time.Time        `json:"generated_at"`
+}
+
+// Activity holds the last time something happened in a tenant
+type Activity struct {
+	LastLoginAt      *time.Time `json:"last_login_at"`
+	LastAPIKeyUseAt  *time.Time `json:"last_api_key_use_at"`
+	LastAuditEventAt *time.Time `json:"last_audit_event_at"`
+	LastDocumentAt   *time.Time `json:"last_document_at"`
+	LastSyncAt       *time.Time `json:"last_sync_at"`
+	LastJobAt        *time.Time `json:"last_job_completed_at"`
+}
+
+// Integration is the connection state of an account (FinanzOnline, ELDA,
+// Firmenbuch) or a webhook
+type Integration struct {
+	ID             uuid.UUID  `json:"id"`
+	Type           string     `json:"type"`
+	Name           string     `json:"name"`
+	Status         string     `json:"status"`
+	LastVerifiedAt *time.Time `json:"last_verified_at,omitempty"`
+	LastSyncAt     *time.Time `json:"last_sync_at,omitempty"`
+	Error          string     `json:"error,omitempty"`
+}
+
+// JobBacklog counts the background jobs of a tenant. Overdue jobs are
+// pending past their run time, i.e. waiting for a worker.
+type JobBacklog struct {
+	Pending         int64           `json:"pending"`
+	Overdue         int64           `json:"overdue"`
+	Running         int64           `json:"running"`
+	Failed24h       int64           `json:"failed_24h"`
+	Dead            int64           `json:"dead"`
+	OldestPendingAt *time.Time      `json:"oldest_pending_at"`
+	ByType          []*JobTypeCount `json:"by_type"`
+}
+
+// JobTypeCount is the backlog of one job type
+type JobTypeCount struct {
+	Type      string `json:"type"`
+	Pending   int64  `json:"pending"`
+	Running   int64  `json:"running"`
+	Failed24h int64  `json:"failed_24h"`
+	Dead      int64  `json:"dead"`
+}
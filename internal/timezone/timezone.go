@@ -0,0 +1,77 @@
+// Package timezone holds the rules for turning stored instants into
+// calendar days. Timestamps are stored and compared in UTC; calendar days
+// (deadlines, "today", day buckets) are those of a tenant's time zone,
+// Europe/Vienna unless the tenant configured another.
+package timezone
+
+import (
+	"context"
+	"errors"
+	"time"
+	_ "time/tzdata" // Zone data for hosts and containers without /usr/share/zoneinfo
+
+	"github.com/google/uuid"
+)
+
+// Default is the zone of tenants without a time zone setting
+const Default = "Europe/Vienna"
+
+// Vienna is the Default zone
+var Vienna = mustLoad(Default)
+
+// ErrInvalid is returned for names that are not IANA time zones
+var ErrInvalid = errors.New("invalid time zone")
+
+func mustLoad(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// Load returns the zone of an IANA name such as "Europe/Berlin". An empty
+// name is the Default zone. "Local" is rejected since it depends on the
+// host the server runs on.
+func Load(name string) (*time.Location, error) {
+	if name == "" {
+		return Vienna, nil
+	}
+	if name == "Local" {
+		return nil, ErrInvalid
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	return loc, nil
+}
+
+// Source returns the time zone of a tenant; tenant.Service implements it
+type Source interface {
+	Location(ctx context.Context, tenantID uuid.UUID) *time.Location
+}
+
+// Of returns the zone of a tenant from src, Vienna if src is nil
+func Of(ctx context.Context, src Source, tenantID uuid.UUID) *time.Location {
+	if src == nil {
+		return Vienna
+	}
+	return src.Location(ctx, tenantID)
+}
+
+// Today returns the calendar day t falls on in loc, as midnight UTC. That
+// is the form DATE columns are scanned into and compared with, so the
+// result can be passed to queries in place of CURRENT_DATE, which follows
+// the database session's zone.
+func Today(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// DayStart returns the instant the calendar day of day (a date as returned
+// by Today) begins in loc. The day ends at DayStart of the next day, which
+// is 23 or 25 hours later on days the clocks change.
+func DayStart(day time.Time, loc *time.Location) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,421 @@
+package uid
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/account/types"
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/endpoint"
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/rawpayload"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrBatchesDisabled = errors.New("batch verification is not available")
+	ErrInvalidCSV      = errors.New("invalid CSV")
+	ErrEmptyBatch      = errors.New("no UIDs found in CSV")
+	ErrBatchTooLarge   = fmt.Errorf("maximum %d UIDs per batch", MaxBatchSize)
+
+	errSessionExpired = errors.New("FinanzOnline session expired")
+)
+
+// MaxBatchSize is the most UIDs of a batch, a day's validations
+const MaxBatchSize = DailyValidationLimit
+
+// rcSessionExpired is the return code of queries in an expired session
+const rcSessionExpired = -2
+
+// BatchScheduler queues the verification of a batch for the worker;
+// jobs.UIDBatchScheduler implements it
+type BatchScheduler interface {
+	ScheduleBatch(ctx context.Context, tenantID, batchID uuid.UUID) error
+}
+
+// Notifier sends the mail of a finished batch to its uploader;
+// email.Service implements it
+type Notifier interface {
+	SendUIDBatchCompleted(ctx context.Context, to string, params email.UIDBatchCompletedParams) error
+}
+
+// ParseBatchCSV returns the distinct UIDs of the "uid" column of a CSV in
+// their order, normalized to upper case
+func ParseBatchCSV(data []byte) ([]string, error) {
+	uids, err := fonws.ParseUIDCSV(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+	}
+
+	seen := make(map[string]bool, len(uids))
+	distinct := uids[:0]
+	for _, uid := range uids {
+		uid = strings.ReplaceAll(uid, " ", "")
+		if seen[uid] {
+			continue
+		}
+		seen[uid] = true
+		distinct = append(distinct, uid)
+	}
+
+	if len(distinct) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(distinct) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+	return distinct, nil
+}
+
+// CreateBatch stores the UIDs of an uploaded CSV and queues their
+// verification. The batch counts against the daily limit when it is
+// created, so that the worker does not stop halfway.
+func (s *Service) CreateBatch(ctx context.Context, tenantID, userID uuid.UUID, input *CreateBatchInput) (*Batch, error) {
+	if s.scheduler == nil {
+		return nil, ErrBatchesDisabled
+	}
+	if input.Level != Level1 && input.Level != Level2 {
+		return nil, ErrInvalidLevel
+	}
+
+	uids, err := ParseBatchCSV(input.CSV)
+	if err != nil {
+		return nil, err
+	}
+
+	batch, err := s.createBatch(ctx, tenantID, &userID, input.AccountID, input.Level, input.FileName, uids)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduler.ScheduleBatch(ctx, tenantID, batch.ID); err != nil {
+		s.repo.FailBatch(ctx, batch.ID, "batch could not be queued")
+		return nil, fmt.Errorf("failed to queue batch: %w", err)
+	}
+
+	return batch, nil
+}
+
+// CreateListBatch stores a batch of UIDs that the caller verifies itself
+// with ProcessBatch, such as the re-validation of saved business partners.
+// The UIDs must be normalized and distinct.
+func (s *Service) CreateListBatch(ctx context.Context, tenantID, accountID uuid.UUID, level int, name string, uids []string) (*Batch, error) {
+	if level != Level1 && level != Level2 {
+		return nil, ErrInvalidLevel
+	}
+	if len(uids) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(uids) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+	return s.createBatch(ctx, tenantID, nil, accountID, level, name, uids)
+}
+
+// RemainingToday returns how many validations the tenant has left today
+func (s *Service) RemainingToday(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	count, err := s.repo.CountToday(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return max(DailyValidationLimit-count, 0), nil
+}
+
+// CheckAccount verifies that an account of the tenant has FinanzOnline
+// credentials to query the UID-Bestätigungsservice with
+func (s *Service) CheckAccount(ctx context.Context, tenantID, accountID uuid.UUID) error {
+	_, creds, err := s.accountService.GetAccountWithCredentials(ctx, accountID, tenantID)
+	if err != nil {
+		return ErrAccountNotFound
+	}
+	if _, ok := creds.(*types.FinanzOnlineCredentials); !ok {
+		return errors.New("invalid account credentials")
+	}
+	return nil
+}
+
+func (s *Service) createBatch(ctx context.Context, tenantID uuid.UUID, createdBy *uuid.UUID, accountID uuid.UUID, level int, name string, uids []string) (*Batch, error) {
+	remaining, err := s.RemainingToday(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(uids) > remaining {
+		return nil, ErrDailyLimit
+	}
+
+	if err := s.CheckAccount(ctx, tenantID, accountID); err != nil {
+		return nil, err
+	}
+
+	batch := &Batch{
+		TenantID:  tenantID,
+		AccountID: accountID,
+		Level:     level,
+		CreatedBy: createdBy,
+	}
+	if name = strings.TrimSpace(name); name != "" {
+		if len(name) > 255 {
+			name = name[:255]
+		}
+		batch.FileName = &name
+	}
+
+	if err := s.repo.CreateBatch(ctx, batch, uids); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// GetBatch retrieves a batch by ID
+func (s *Service) GetBatch(ctx context.Context, id, tenantID uuid.UUID) (*Batch, error) {
+	return s.repo.GetBatch(ctx, id, tenantID)
+}
+
+// ListBatches lists the batches of a tenant, newest first
+func (s *Service) ListBatches(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*Batch, int, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	return s.repo.ListBatches(ctx, tenantID, limit, offset)
+}
+
+// BatchItems returns the UIDs of a batch with their confirmations
+func (s *Service) BatchItems(ctx context.Context, id, tenantID uuid.UUID) (*Batch, []*BatchItem, error) {
+	batch, err := s.repo.GetBatch(ctx, id, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := s.repo.ListItems(ctx, batch.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return batch, items, nil
+}
+
+// ProcessBatch verifies the UIDs of a batch that have no confirmation yet
+// and completes it. UIDs that could not be queried are recorded with the
+// error and queried again if the batch runs again. Errors of the batch as
+// a whole, such as a failed login or a maintenance window, are returned
+// with the confirmations obtained so far kept.
+func (s *Service) ProcessBatch(ctx context.Context, tenantID, batchID uuid.UUID) (*Batch, error) {
+	batch, err := s.repo.GetBatch(ctx, batchID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Done() {
+		return batch, nil
+	}
+
+	if started, err := s.repo.StartBatch(ctx, batch.ID); err != nil || !started {
+		if err != nil {
+			return nil, err
+		}
+		return s.repo.GetBatch(ctx, batch.ID, tenantID)
+	}
+
+	items, err := s.repo.PendingItems(ctx, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > 0 {
+		if err := s.verifyItems(ctx, batch, items); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.FinishBatch(ctx, batch.ID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetBatch(ctx, batch.ID, tenantID)
+}
+
+// FailBatch stops a batch that cannot be completed
+func (s *Service) FailBatch(ctx context.Context, tenantID, batchID uuid.UUID, reason string) (*Batch, error) {
+	if err := s.repo.FailBatch(ctx, batchID, reason); err != nil {
+		return nil, err
+	}
+	return s.repo.GetBatch(ctx, batchID, tenantID)
+}
+
+// verifyItems queries FinanzOnline for each item in one session
+func (s *Service) verifyItems(ctx context.Context, batch *Batch, items []*BatchItem) error {
+	_, creds, err := s.accountService.GetAccountWithCredentials(ctx, batch.AccountID, batch.TenantID)
+	if err != nil {
+		return ErrAccountNotFound
+	}
+	foCreds, ok := creds.(*types.FinanzOnlineCredentials)
+	if !ok {
+		return errors.New("invalid account credentials")
+	}
+
+	sessionService := fonws.NewSessionService(s.fonwsClient)
+	session, err := sessionService.Login(foCreds.TID, foCreds.BenID, foCreds.PIN)
+	if err != nil {
+		return fmt.Errorf("failed to login to FinanzOnline: %w", err)
+	}
+	defer func() { sessionService.Logout(session) }()
+
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		v, err := s.verifyItem(ctx, batch, session, foCreds, item.UID)
+		if errors.Is(err, errSessionExpired) {
+			// Long batches outlive the session
+			sessionService.Logout(session)
+			session, err = sessionService.Login(foCreds.TID, foCreds.BenID, foCreds.PIN)
+			if err != nil {
+				return fmt.Errorf("failed to login to FinanzOnline: %w", err)
+			}
+			v, err = s.verifyItem(ctx, batch, session, foCreds, item.UID)
+		}
+		if errors.Is(err, endpoint.ErrMaintenanceWindow) {
+			return err
+		}
+
+		var validationID *uuid.UUID
+		var errorMsg *string
+		if err != nil {
+			msg := err.Error()
+			errorMsg = &msg
+		} else {
+			validationID = &v.ID
+		}
+		if err := s.repo.CompleteItem(ctx, batch.ID, item.Position, validationID, errorMsg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyItem returns the confirmation of a UID: a recent validation of at
+// least the batch's level, or a new one
+func (s *Service) verifyItem(ctx context.Context, batch *Batch, session *fonws.Session, creds *types.FinanzOnlineCredentials, uid string) (*Validation, error) {
+	cached, err := s.repo.GetRecentByUID(ctx, batch.TenantID, uid, s.cacheDuration)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil && cached.Level >= batch.Level {
+		return cached, nil
+	}
+
+	formatResult := fonws.ValidateUIDFormat(uid)
+	if !formatResult.Valid {
+		return s.createValidation(ctx, batch.TenantID, batch.CreatedBy, batch.AccountID, uid, formatResult.CountryCode, false, batch.Level, nil, formatResult.Error)
+	}
+
+	rec := rawpayload.NewRecorder()
+	uidService := fonws.NewUIDService(fonws.Recording(s.fonwsClient, rec))
+	result, err := uidService.Validate(session.Token, creds.TID, creds.BenID, uid, batch.Level)
+	if err != nil {
+		s.savePayloads(ctx, batch.TenantID, nil, err, rec)
+		return nil, err
+	}
+	if result.ErrorCode == rcSessionExpired {
+		return nil, errSessionExpired
+	}
+
+	v, err := s.createValidationFromResult(ctx, batch.TenantID, batch.CreatedBy, batch.AccountID, batch.Level, result)
+	if v != nil {
+		s.savePayloads(ctx, batch.TenantID, &v.ID, nil, rec)
+	}
+	return v, err
+}
+
+// NotifyBatch mails the outcome of a finished batch to its uploader
+func (s *Service) NotifyBatch(ctx context.Context, batch *Batch) error {
+	if s.notifier == nil || batch.CreatedBy == nil || !batch.Done() {
+		return nil
+	}
+
+	name, to, err := s.repo.userContact(ctx, *batch.CreatedBy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil // Uploader left the tenant
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load uploader: %w", err)
+	}
+
+	params := email.UIDBatchCompletedParams{
+		TenantID:      &batch.TenantID,
+		RecipientName: name,
+		Level:         batch.Level,
+		Total:         batch.Total,
+		Valid:         batch.ValidCount,
+		Invalid:       batch.InvalidCount,
+		Failed:        batch.FailedCount,
+	}
+	if batch.FileName != nil {
+		params.FileName = *batch.FileName
+	}
+	if batch.Error != nil {
+		params.Error = *batch.Error
+	}
+	if s.appURL != "" {
+		params.ResultsURL = fmt.Sprintf("%s/uid/batches/%s", s.appURL, batch.ID)
+	}
+	return s.notifier.SendUIDBatchCompleted(ctx, to, params)
+}
+
+// ExportBatchCSV exports the confirmations of a batch in upload order. The
+// time of each confirmation is the time FinanzOnline was queried.
+func (s *Service) ExportBatchCSV(ctx context.Context, id, tenantID uuid.UUID) ([]byte, error) {
+	_, items, err := s.BatchItems(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return WriteBatchCSV(items)
+}
+
+// WriteBatchCSV writes the items of a batch as CSV
+func WriteBatchCSV(items []*BatchItem) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{"position", "uid", "level", "valid", "company_name", "street", "post_code", "city", "error", "validated_at", "validation_id", "consultation_number"}
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, item := range items {
+		row := make([]string, len(header))
+		row[0] = strconv.Itoa(item.Position)
+		row[1] = item.UID
+		if item.Error != nil {
+			row[8] = *item.Error
+		}
+		if v := item.Validation; v != nil {
+			row[2] = strconv.Itoa(v.Level)
+			row[3] = strconv.FormatBool(v.Valid)
+			row[4] = deref(v.CompanyName)
+			row[5] = deref(v.Street)
+			row[6] = deref(v.PostCode)
+			row[7] = deref(v.City)
+			row[8] = deref(v.ErrorMessage)
+			row[9] = v.ValidatedAt.UTC().Format(time.RFC3339)
+			row[10] = v.ID.String()
+			row[11] = deref(v.ConsultationNumber)
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
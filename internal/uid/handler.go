@@ -2,16 +2,22 @@ package uid
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 	"time"
 
 	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/endpoint"
 	"austrian-business-infrastructure/internal/fonws"
 	"github.com/google/uuid"
 )
 
+// maxCSVSize limits uploaded UID lists
+const maxCSVSize = 10 << 20
+
 // Handler handles UID validation HTTP requests
 type Handler struct {
 	service *Service
@@ -27,6 +33,7 @@ func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin f
 	// Admin-only: batch operations and imports (consume API quota)
 	router.Handle("POST /api/v1/uid/validate/batch", requireAuth(requireAdmin(http.HandlerFunc(h.ValidateBatch))))
 	router.Handle("POST /api/v1/uid/import", requireAuth(requireAdmin(http.HandlerFunc(h.ImportCSV))))
+	router.Handle("POST /api/v1/uid/batches", requireAuth(requireAdmin(http.HandlerFunc(h.CreateBatch))))
 
 	// Member access: single validation, format check, read operations
 	router.Handle("POST /api/v1/uid/validate", requireAuth(http.HandlerFunc(h.Validate)))
@@ -34,6 +41,11 @@ func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin f
 	router.Handle("GET /api/v1/uid/validations", requireAuth(http.HandlerFunc(h.List)))
 	router.Handle("GET /api/v1/uid/validations/{id}", requireAuth(http.HandlerFunc(h.Get)))
 	router.Handle("GET /api/v1/uid/validations/export", requireAuth(http.HandlerFunc(h.Export)))
+	router.Handle("GET /api/v1/uid/proof", requireAuth(http.HandlerFunc(h.Proof)))
+	router.Handle("GET /api/v1/uid/batches", requireAuth(http.HandlerFunc(h.ListBatches)))
+	router.Handle("GET /api/v1/uid/batches/{id}", requireAuth(http.HandlerFunc(h.GetBatch)))
+	router.Handle("GET /api/v1/uid/batches/{id}/items", requireAuth(http.HandlerFunc(h.BatchItems)))
+	router.Handle("GET /api/v1/uid/batches/{id}/export", requireAuth(http.HandlerFunc(h.ExportBatch)))
 }
 
 // ValidateRequest represents the validate UID request
@@ -160,7 +172,7 @@ func (h *Handler) ValidateBatch(w http.ResponseWriter, r *http.Request) {
 		Valid:       validCount,
 		Invalid:     invalidCount,
 		Results:     results,
-		ProcessedAt: time.Now().Format("2006-01-02T15:04:05Z"),
+		ProcessedAt: time.Now().UTC().Format(time.RFC3339),
 	})
 }
 
@@ -341,6 +353,63 @@ func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
 	w.Write(csvData)
 }
 
+// Proof handles GET /api/v1/uid/proof?uid=&date_from=&date_to=&format=pdf|csv
+func (h *Handler) Proof(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "pdf"
+	}
+	if format != "pdf" && format != "csv" {
+		api.BadRequest(w, "format must be pdf or csv")
+		return
+	}
+
+	var dateFrom, dateTo time.Time
+	if v := query.Get("date_from"); v != "" {
+		if dateFrom, err = time.Parse("2006-01-02", v); err != nil {
+			api.BadRequest(w, "invalid date_from")
+			return
+		}
+	}
+	if v := query.Get("date_to"); v != "" {
+		if dateTo, err = time.Parse("2006-01-02", v); err != nil {
+			api.BadRequest(w, "invalid date_to")
+			return
+		}
+	}
+
+	proof, err := h.service.Proof(r.Context(), tenantID, query.Get("uid"), dateFrom, dateTo)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("uid_nachweis_%s_%s_%s", proof.UID, proof.DateFrom.Format("2006-01-02"), proof.DateTo.Format("2006-01-02"))
+	content, contentType := []byte(nil), "application/pdf"
+	if format == "csv" {
+		content, err = WriteProofCSV(proof)
+		contentType = "text/csv"
+	} else {
+		content, err = GenerateProofPDF(proof)
+	}
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename="+fileName+"."+format)
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
+
 // ImportCSV handles POST /api/v1/uid/import
 func (h *Handler) ImportCSV(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := h.getTenantID(r)
@@ -370,34 +439,9 @@ func (h *Handler) ImportCSV(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	var csvData []byte
-
-	contentType := r.Header.Get("Content-Type")
-	if contentType == "text/csv" || contentType == "application/csv" {
-		csvData, err = io.ReadAll(r.Body)
-		if err != nil {
-			api.BadRequest(w, "failed to read CSV data")
-			return
-		}
-	} else {
-		// Multipart form
-		if err := r.ParseMultipartForm(10 << 20); err != nil {
-			api.BadRequest(w, "failed to parse multipart form")
-			return
-		}
-
-		file, _, err := r.FormFile("file")
-		if err != nil {
-			api.BadRequest(w, "file is required")
-			return
-		}
-		defer file.Close()
-
-		csvData, err = io.ReadAll(file)
-		if err != nil {
-			api.BadRequest(w, "failed to read file")
-			return
-		}
+	csvData, _, ok := h.readCSV(w, r)
+	if !ok {
+		return
 	}
 
 	// Parse UIDs from CSV
@@ -445,12 +489,218 @@ func (h *Handler) ImportCSV(w http.ResponseWriter, r *http.Request) {
 		Valid:       validCount,
 		Invalid:     invalidCount,
 		Results:     results,
-		ProcessedAt: time.Now().Format("2006-01-02T15:04:05Z"),
+		ProcessedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// CreateBatch handles POST /api/v1/uid/batches. The CSV is uploaded as
+// "file" of a multipart form or as text/csv body; account_id and level
+// are form fields or query parameters.
+func (h *Handler) CreateBatch(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	userID, err := h.getUserID(r)
+	if err != nil {
+		api.Unauthorized(w, "user not found in context")
+		return
+	}
+
+	csvData, fileName, ok := h.readCSV(w, r)
+	if !ok {
+		return
+	}
+
+	accountID, err := uuid.Parse(r.FormValue("account_id"))
+	if err != nil {
+		api.BadRequest(w, "invalid account_id")
+		return
+	}
+
+	level := Level1
+	if levelStr := r.FormValue("level"); levelStr != "" {
+		if level, err = strconv.Atoi(levelStr); err != nil {
+			api.BadRequest(w, "level must be 1 or 2")
+			return
+		}
+	}
+
+	batch, err := h.service.CreateBatch(r.Context(), tenantID, userID, &CreateBatchInput{
+		CSV:       csvData,
+		FileName:  fileName,
+		Level:     level,
+		AccountID: accountID,
+	})
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusAccepted, toBatchResponse(batch))
+}
+
+// ListBatches handles GET /api/v1/uid/batches
+func (h *Handler) ListBatches(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	batches, total, err := h.service.ListBatches(r.Context(), tenantID, limit, offset)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	items := make([]*BatchResponse, 0, len(batches))
+	for _, b := range batches {
+		items = append(items, toBatchResponse(b))
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  items,
+		"total":  total,
+		"offset": offset,
 	})
 }
 
+// GetBatch handles GET /api/v1/uid/batches/{id}
+func (h *Handler) GetBatch(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid batch ID")
+		return
+	}
+
+	batch, err := h.service.GetBatch(r.Context(), id, tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, toBatchResponse(batch))
+}
+
+// BatchItems handles GET /api/v1/uid/batches/{id}/items
+func (h *Handler) BatchItems(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid batch ID")
+		return
+	}
+
+	batch, items, err := h.service.BatchItems(r.Context(), id, tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	results := make([]*BatchItemResponse, 0, len(items))
+	for _, item := range items {
+		resp := &BatchItemResponse{
+			Position:    item.Position,
+			UID:         item.UID,
+			Error:       item.Error,
+			ProcessedAt: formatTime(item.ProcessedAt),
+		}
+		if item.Validation != nil {
+			resp.Validation = h.toResponse(item.Validation)
+		}
+		results = append(results, resp)
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"batch": toBatchResponse(batch),
+		"items": results,
+	})
+}
+
+// ExportBatch handles GET /api/v1/uid/batches/{id}/export
+func (h *Handler) ExportBatch(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid batch ID")
+		return
+	}
+
+	csvData, err := h.service.ExportBatchCSV(r.Context(), id, tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=uid_batch_"+id.String()+".csv")
+	w.WriteHeader(http.StatusOK)
+	w.Write(csvData)
+}
+
 // Helper methods
 
+// readCSV reads an uploaded CSV, sent as text/csv body or as "file" of a
+// multipart form, and returns it with the uploaded file name
+func (h *Handler) readCSV(w http.ResponseWriter, r *http.Request) ([]byte, string, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxCSVSize)
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "text/csv" || contentType == "application/csv" {
+		csvData, err := io.ReadAll(r.Body)
+		if err != nil {
+			api.BadRequest(w, "failed to read CSV data")
+			return nil, "", false
+		}
+		return csvData, "", true
+	}
+
+	// Multipart form
+	if err := r.ParseMultipartForm(maxCSVSize); err != nil {
+		api.BadRequest(w, "failed to parse multipart form")
+		return nil, "", false
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		api.BadRequest(w, "file is required")
+		return nil, "", false
+	}
+	defer file.Close()
+
+	csvData, err := io.ReadAll(file)
+	if err != nil {
+		api.BadRequest(w, "failed to read file")
+		return nil, "", false
+	}
+	return csvData, header.Filename, true
+}
+
 func (h *Handler) getTenantID(r *http.Request) (uuid.UUID, error) {
 	tenantIDStr := api.GetTenantID(r.Context())
 	if tenantIDStr == "" {
@@ -468,6 +718,16 @@ func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
 }
 
 func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	if m, ok := endpoint.AsMaintenance(err); ok {
+		api.ServiceUnavailable(w, m.RetryAfter(), "FinanzOnline is in a maintenance window, retry later")
+		return
+	}
+
+	if errors.Is(err, ErrInvalidCSV) {
+		api.BadRequest(w, err.Error())
+		return
+	}
+
 	switch err {
 	case ErrValidationNotFound:
 		api.NotFound(w, "validation not found")
@@ -479,6 +739,14 @@ func (h *Handler) handleError(w http.ResponseWriter, err error) {
 		api.JSONError(w, http.StatusTooManyRequests, "daily validation limit exceeded", "DAILY_LIMIT")
 	case ErrInvalidUID:
 		api.BadRequest(w, "invalid UID format")
+	case ErrInvalidPeriod:
+		api.BadRequest(w, err.Error())
+	case ErrBatchNotFound:
+		api.NotFound(w, "batch not found")
+	case ErrEmptyBatch, ErrBatchTooLarge:
+		api.BadRequest(w, err.Error())
+	case ErrBatchesDisabled:
+		api.JSONError(w, http.StatusServiceUnavailable, "batch verification is not available", "BATCHES_DISABLED")
 	default:
 		api.InternalError(w)
 	}
@@ -486,21 +754,49 @@ func (h *Handler) handleError(w http.ResponseWriter, err error) {
 
 func (h *Handler) toResponse(v *Validation) *ValidationResponse {
 	resp := &ValidationResponse{
-		ID:           v.ID,
-		UID:          v.UID,
-		CountryCode:  v.CountryCode,
-		Valid:        v.Valid,
-		Level:        v.Level,
-		CompanyName:  v.CompanyName,
-		Street:       v.Street,
-		PostCode:     v.PostCode,
-		City:         v.City,
-		Country:      v.Country,
-		ErrorMessage: v.ErrorMessage,
-		Source:       v.Source,
-		ValidatedAt:  v.ValidatedAt.Format("2006-01-02T15:04:05Z"),
-		CreatedAt:    v.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		ID:                 v.ID,
+		UID:                v.UID,
+		CountryCode:        v.CountryCode,
+		Valid:              v.Valid,
+		Level:              v.Level,
+		CompanyName:        v.CompanyName,
+		Street:             v.Street,
+		PostCode:           v.PostCode,
+		City:               v.City,
+		Country:            v.Country,
+		ErrorMessage:       v.ErrorMessage,
+		Source:             v.Source,
+		ConsultationNumber: v.ConsultationNumber,
+		ValidatedAt:        v.ValidatedAt.UTC().Format(time.RFC3339),
+		CreatedAt:          v.CreatedAt.UTC().Format(time.RFC3339),
 	}
 
 	return resp
 }
+
+func toBatchResponse(b *Batch) *BatchResponse {
+	return &BatchResponse{
+		ID:           b.ID,
+		AccountID:    b.AccountID,
+		Level:        b.Level,
+		FileName:     b.FileName,
+		Status:       b.Status,
+		Total:        b.Total,
+		ValidCount:   b.ValidCount,
+		InvalidCount: b.InvalidCount,
+		FailedCount:  b.FailedCount,
+		Error:        b.Error,
+		CreatedBy:    b.CreatedBy,
+		CreatedAt:    b.CreatedAt.UTC().Format(time.RFC3339),
+		StartedAt:    formatTime(b.StartedAt),
+		CompletedAt:  formatTime(b.CompletedAt),
+	}
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.UTC().Format(time.RFC3339)
+	return &s
+}
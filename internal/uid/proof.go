@@ -0,0 +1,277 @@
+package uid
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/timezone"
+)
+
+// ErrInvalidPeriod is returned for proof periods without both dates or
+// ending before they start
+var ErrInvalidPeriod = errors.New("date_from and date_to are required and date_from must not be after date_to")
+
+// Proof returns the checks of a counterparty's UID from the first to the
+// last day of a period, calendar days in Europe/Vienna
+func (s *Service) Proof(ctx context.Context, tenantID uuid.UUID, uid string, dateFrom, dateTo time.Time) (*Proof, error) {
+	uid = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(uid), " ", ""))
+	if !fonws.ValidateUIDFormat(uid).Valid {
+		return nil, ErrInvalidUID
+	}
+	if dateFrom.IsZero() || dateTo.IsZero() || dateTo.Before(dateFrom) {
+		return nil, ErrInvalidPeriod
+	}
+
+	tenantName, err := s.repo.tenantName(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	from := timezone.DayStart(dateFrom, timezone.Vienna)
+	to := timezone.DayStart(dateTo.AddDate(0, 0, 1), timezone.Vienna)
+	checks, err := s.repo.ListProofChecks(ctx, tenantID, uid, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Proof{
+		TenantName:  tenantName,
+		UID:         uid,
+		DateFrom:    dateFrom,
+		DateTo:      dateTo,
+		Checks:      checks,
+		GeneratedAt: time.Now(),
+	}, nil
+}
+
+// WriteProofCSV writes the checks of a proof as CSV, one row per query
+func WriteProofCSV(p *Proof) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{"uid", "validated_at", "level", "valid", "consultation_number", "company_name", "street", "post_code", "city", "country", "source", "checked_by", "account", "error", "validation_id"}
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, c := range p.Checks {
+		row := []string{
+			c.UID,
+			c.ValidatedAt.UTC().Format(time.RFC3339),
+			strconv.Itoa(c.Level),
+			strconv.FormatBool(c.Valid),
+			deref(c.ConsultationNumber),
+			deref(c.CompanyName),
+			deref(c.Street),
+			deref(c.PostCode),
+			deref(c.City),
+			deref(c.Country),
+			c.Source,
+			c.CheckedBy,
+			c.AccountName,
+			deref(c.ErrorMessage),
+			c.ID.String(),
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// proofLine is a rendered text line of the proof PDF
+type proofLine struct {
+	size int
+	gap  int // Vertical distance to the previous line
+	text string
+}
+
+const (
+	proofTop    = 800
+	proofBottom = 60
+)
+
+// GenerateProofPDF renders the proof report: the tenant, the counterparty
+// and period, then every check with its result, the confirmed name and
+// address and the consultation number. Times are in Europe/Vienna. This is
+// a text-based PDF implementation like the invoice PDF.
+func GenerateProofPDF(p *Proof) ([]byte, error) {
+	lines := proofPDFLines(p)
+
+	var pages [][]proofLine
+	var page []proofLine
+	y := proofTop
+	for _, l := range lines {
+		if y-l.gap < proofBottom && len(page) > 0 {
+			pages = append(pages, page)
+			page = nil
+			y = proofTop
+			l.gap = 0
+		}
+		y -= l.gap
+		page = append(page, l)
+	}
+	pages = append(pages, page)
+
+	// Object layout: 1 catalog, 2 pages, 3 font, then page/content pairs
+	objects := []string{
+		"1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n",
+		"",
+		"3 0 obj\n<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>\nendobj\n",
+	}
+	kids := make([]string, 0, len(pages))
+	for i, pg := range pages {
+		pageNum := 4 + i*2
+		contentNum := pageNum + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageNum))
+
+		content := proofPageContent(pg, i+1, len(pages))
+		objects = append(objects,
+			fmt.Sprintf("%d 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Contents %d 0 R /Resources << /Font << /F1 3 0 R >> >> >>\nendobj\n", pageNum, contentNum),
+			fmt.Sprintf("%d 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n", contentNum, len(content), content),
+		)
+	}
+	objects[1] = fmt.Sprintf("2 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, 0, len(objects))
+	for _, obj := range objects {
+		offsets = append(offsets, buf.Len())
+		buf.WriteString(obj)
+	}
+
+	xrefOffset := buf.Len()
+	buf.WriteString("xref\n")
+	buf.WriteString(fmt.Sprintf("0 %d\n", len(objects)+1))
+	buf.WriteString("0000000000 65535 f \n")
+	for _, offset := range offsets {
+		buf.WriteString(fmt.Sprintf("%010d 00000 n \n", offset))
+	}
+	buf.WriteString("trailer\n")
+	buf.WriteString(fmt.Sprintf("<< /Size %d /Root 1 0 R >>\n", len(objects)+1))
+	buf.WriteString("startxref\n")
+	buf.WriteString(fmt.Sprintf("%d\n", xrefOffset))
+	buf.WriteString("%%EOF\n")
+
+	return buf.Bytes(), nil
+}
+
+func proofPDFLines(p *Proof) []proofLine {
+	lines := []proofLine{
+		{size: 16, text: "Nachweis der UID-Überprüfung"},
+		{size: 10, gap: 28, text: "Unternehmen: " + p.TenantName},
+		{size: 10, gap: 14, text: "UID des Geschäftspartners: " + p.UID},
+		{size: 10, gap: 14, text: fmt.Sprintf("Zeitraum: %s – %s", p.DateFrom.Format("02.01.2006"), p.DateTo.Format("02.01.2006"))},
+		{size: 10, gap: 14, text: "Erstellt am: " + p.GeneratedAt.In(timezone.Vienna).Format("02.01.2006 15:04")},
+	}
+
+	stufe2 := 0
+	for _, c := range p.Checks {
+		if c.Level == Level2 && c.ConsultationNumber != nil {
+			stufe2++
+		}
+	}
+	lines = append(lines, proofLine{size: 10, gap: 14,
+		text: fmt.Sprintf("Abfragen: %d, davon Stufe 2 mit Abfragenummer: %d", len(p.Checks), stufe2)})
+
+	if len(p.Checks) == 0 {
+		return append(lines, proofLine{size: 10, gap: 28, text: "Keine Abfragen im Zeitraum."})
+	}
+
+	for i, c := range p.Checks {
+		result := "ungültig"
+		if c.Valid {
+			result = "gültig"
+		}
+		head := fmt.Sprintf("%s   Stufe %d   %s", c.ValidatedAt.In(timezone.Vienna).Format("02.01.2006 15:04:05"), c.Level, result)
+		if c.ConsultationNumber != nil {
+			head += "   Abfragenummer: " + *c.ConsultationNumber
+		}
+		gap := 16
+		if i == 0 {
+			gap = 28
+		}
+		lines = append(lines, proofLine{size: 10, gap: gap, text: head})
+
+		var address []string
+		for _, part := range []*string{c.CompanyName, c.Street} {
+			if part != nil && *part != "" {
+				address = append(address, *part)
+			}
+		}
+		if city := strings.TrimSpace(deref(c.PostCode) + " " + deref(c.City)); city != "" {
+			address = append(address, city)
+		}
+		if len(address) > 0 {
+			lines = append(lines, proofLine{size: 9, gap: 12, text: strings.Join(address, ", ")})
+		}
+		if c.ErrorMessage != nil && !c.Valid {
+			lines = append(lines, proofLine{size: 9, gap: 12, text: "Meldung: " + *c.ErrorMessage})
+		}
+
+		by := "Abgefragt über " + c.Source
+		if c.AccountName != "" {
+			by += ", Konto " + c.AccountName
+		}
+		if c.CheckedBy != "" {
+			by += ", von " + c.CheckedBy
+		}
+		lines = append(lines, proofLine{size: 9, gap: 12, text: by})
+	}
+	return lines
+}
+
+func proofPageContent(lines []proofLine, page, total int) string {
+	var buf bytes.Buffer
+	buf.WriteString("BT\n")
+	buf.WriteString(fmt.Sprintf("50 %d Td\n", proofTop))
+
+	for i, l := range lines {
+		buf.WriteString(fmt.Sprintf("/F1 %d Tf\n", l.size))
+		if i > 0 {
+			buf.WriteString(fmt.Sprintf("0 -%d Td\n", l.gap))
+		}
+		buf.WriteString(fmt.Sprintf("(%s) Tj\n", escapeProofText(l.text)))
+	}
+	buf.WriteString("ET\n")
+
+	if total > 1 {
+		buf.WriteString("BT\n/F1 8 Tf\n")
+		buf.WriteString(fmt.Sprintf("500 30 Td\n(Seite %d/%d) Tj\nET\n", page, total))
+	}
+
+	return buf.String()
+}
+
+// escapeProofText escapes a string for a PDF literal in WinAnsiEncoding.
+// Latin-1 characters (umlauts, ß) are written as octal escapes.
+func escapeProofText(s string) string {
+	var buf strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r == '–':
+			buf.WriteString("\\226")
+		case r < 0x80:
+			buf.WriteRune(r)
+		case r <= 0xFF:
+			buf.WriteString(fmt.Sprintf("\\%03o", r))
+		default:
+			buf.WriteByte('?')
+		}
+	}
+	return buf.String()
+}
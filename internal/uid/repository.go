@@ -30,21 +30,23 @@ func NewRepository(db *pgxpool.Pool) *Repository {
 func (r *Repository) Create(ctx context.Context, v *Validation) (*Validation, error) {
 	v.ID = uuid.New()
 	v.CreatedAt = time.Now()
-	v.ValidatedAt = time.Now()
+	if v.ValidatedAt.IsZero() {
+		v.ValidatedAt = v.CreatedAt
+	}
 
 	query := `
 		INSERT INTO uid_validations (
 			id, tenant_id, uid, country_code, valid, level,
 			company_name, street, post_code, city, country,
-			error_code, error_message, source, validated_at, validated_by,
+			error_code, error_message, source, consultation_number, validated_at, validated_by,
 			account_id, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 		RETURNING id, created_at`
 
 	err := r.db.QueryRow(ctx, query,
 		v.ID, v.TenantID, v.UID, v.CountryCode, v.Valid, v.Level,
 		v.CompanyName, v.Street, v.PostCode, v.City, v.Country,
-		v.ErrorCode, v.ErrorMessage, v.Source, v.ValidatedAt, v.ValidatedBy,
+		v.ErrorCode, v.ErrorMessage, v.Source, v.ConsultationNumber, v.ValidatedAt, v.ValidatedBy,
 		v.AccountID, v.CreatedAt,
 	).Scan(&v.ID, &v.CreatedAt)
 
@@ -60,7 +62,7 @@ func (r *Repository) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*Vali
 	query := `
 		SELECT id, tenant_id, uid, country_code, valid, level,
 			company_name, street, post_code, city, country,
-			error_code, error_message, source, validated_at, validated_by,
+			error_code, error_message, source, consultation_number, validated_at, validated_by,
 			account_id, created_at
 		FROM uid_validations
 		WHERE id = $1 AND tenant_id = $2`
@@ -73,7 +75,7 @@ func (r *Repository) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*Vali
 	err := r.db.QueryRow(ctx, query, id, tenantID).Scan(
 		&v.ID, &v.TenantID, &v.UID, &v.CountryCode, &v.Valid, &v.Level,
 		&companyName, &street, &postCode, &city, &country,
-		&errorCode, &errorMessage, &v.Source, &v.ValidatedAt, &validatedBy,
+		&errorCode, &errorMessage, &v.Source, &v.ConsultationNumber, &v.ValidatedAt, &validatedBy,
 		&accountID, &v.CreatedAt,
 	)
 
@@ -173,7 +175,7 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Validation
 	selectQuery := `
 		SELECT id, tenant_id, uid, country_code, valid, level,
 			company_name, street, post_code, city, country,
-			error_code, error_message, source, validated_at, validated_by,
+			error_code, error_message, source, consultation_number, validated_at, validated_by,
 			account_id, created_at
 		` + baseQuery + `
 		ORDER BY validated_at DESC
@@ -197,7 +199,7 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Validation
 		err := rows.Scan(
 			&v.ID, &v.TenantID, &v.UID, &v.CountryCode, &v.Valid, &v.Level,
 			&companyName, &street, &postCode, &city, &country,
-			&errorCode, &errorMessage, &v.Source, &v.ValidatedAt, &validatedBy,
+			&errorCode, &errorMessage, &v.Source, &v.ConsultationNumber, &v.ValidatedAt, &validatedBy,
 			&accountID, &v.CreatedAt,
 		)
 		if err != nil {
@@ -246,7 +248,7 @@ func (r *Repository) GetRecentByUID(ctx context.Context, tenantID uuid.UUID, uid
 	query := `
 		SELECT id, tenant_id, uid, country_code, valid, level,
 			company_name, street, post_code, city, country,
-			error_code, error_message, source, validated_at, validated_by,
+			error_code, error_message, source, consultation_number, validated_at, validated_by,
 			account_id, created_at
 		FROM uid_validations
 		WHERE tenant_id = $1 AND uid = $2 AND validated_at >= $3
@@ -261,7 +263,7 @@ func (r *Repository) GetRecentByUID(ctx context.Context, tenantID uuid.UUID, uid
 	err := r.db.QueryRow(ctx, query, tenantID, uid, cutoff).Scan(
 		&v.ID, &v.TenantID, &v.UID, &v.CountryCode, &v.Valid, &v.Level,
 		&companyName, &street, &postCode, &city, &country,
-		&errorCode, &errorMessage, &v.Source, &v.ValidatedAt, &validatedBy,
+		&errorCode, &errorMessage, &v.Source, &v.ConsultationNumber, &v.ValidatedAt, &validatedBy,
 		&accountID, &v.CreatedAt,
 	)
 
@@ -320,3 +322,309 @@ func (r *Repository) CountToday(ctx context.Context, tenantID uuid.UUID) (int, e
 
 	return count, nil
 }
+
+// ErrBatchNotFound is returned for unknown batches
+var ErrBatchNotFound = errors.New("batch not found")
+
+const batchColumns = `id, tenant_id, account_id, level, file_name, status, total,
+	valid_count, invalid_count, failed_count, error, created_by, created_at,
+	started_at, completed_at`
+
+func scanBatch(row pgx.Row) (*Batch, error) {
+	var b Batch
+	err := row.Scan(
+		&b.ID, &b.TenantID, &b.AccountID, &b.Level, &b.FileName, &b.Status, &b.Total,
+		&b.ValidCount, &b.InvalidCount, &b.FailedCount, &b.Error, &b.CreatedBy, &b.CreatedAt,
+		&b.StartedAt, &b.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// CreateBatch stores a batch and its UIDs in upload order
+func (r *Repository) CreateBatch(ctx context.Context, b *Batch, uids []string) error {
+	b.ID = uuid.New()
+	b.Status = BatchPending
+	b.Total = len(uids)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO uid_batches (id, tenant_id, account_id, level, file_name, status, total, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at`,
+		b.ID, b.TenantID, b.AccountID, b.Level, b.FileName, b.Status, b.Total, b.CreatedBy,
+	).Scan(&b.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create batch: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO uid_batch_items (batch_id, position, uid)
+		SELECT $1, t.position, t.uid
+		FROM unnest($2::text[]) WITH ORDINALITY AS t(uid, position)`,
+		b.ID, uids)
+	if err != nil {
+		return fmt.Errorf("failed to create batch items: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetBatch retrieves a batch by ID
+func (r *Repository) GetBatch(ctx context.Context, id, tenantID uuid.UUID) (*Batch, error) {
+	b, err := scanBatch(r.db.QueryRow(ctx,
+		`SELECT `+batchColumns+` FROM uid_batches WHERE id = $1 AND tenant_id = $2`, id, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBatchNotFound
+		}
+		return nil, fmt.Errorf("failed to get batch: %w", err)
+	}
+	return b, nil
+}
+
+// ListBatches retrieves the batches of a tenant, newest first
+func (r *Repository) ListBatches(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*Batch, int, error) {
+	var total int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM uid_batches WHERE tenant_id = $1`, tenantID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count batches: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+batchColumns+` FROM uid_batches
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`, tenantID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list batches: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []*Batch
+	for rows.Next() {
+		b, err := scanBatch(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan batch: %w", err)
+		}
+		batches = append(batches, b)
+	}
+	return batches, total, rows.Err()
+}
+
+// StartBatch marks a batch as running; a batch that ran before keeps its
+// start time. It returns false if the batch has finished.
+func (r *Repository) StartBatch(ctx context.Context, id uuid.UUID) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE uid_batches SET status = $2, started_at = COALESCE(started_at, NOW())
+		WHERE id = $1 AND status IN ($3, $2)`, id, BatchRunning, BatchPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to start batch: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// PendingItems returns the items of a batch without a confirmation
+func (r *Repository) PendingItems(ctx context.Context, batchID uuid.UUID) ([]*BatchItem, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT position, uid FROM uid_batch_items
+		WHERE batch_id = $1 AND validation_id IS NULL
+		ORDER BY position`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending batch items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*BatchItem
+	for rows.Next() {
+		var item BatchItem
+		if err := rows.Scan(&item.Position, &item.UID); err != nil {
+			return nil, fmt.Errorf("failed to scan batch item: %w", err)
+		}
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
+
+// CompleteItem records the confirmation of a batch item, or why the UID
+// could not be queried
+func (r *Repository) CompleteItem(ctx context.Context, batchID uuid.UUID, position int, validationID *uuid.UUID, errorMsg *string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE uid_batch_items SET validation_id = $3, error = $4, processed_at = NOW()
+		WHERE batch_id = $1 AND position = $2`, batchID, position, validationID, errorMsg)
+	if err != nil {
+		return fmt.Errorf("failed to update batch item: %w", err)
+	}
+	return nil
+}
+
+// FinishBatch marks a batch as completed and counts its outcomes
+func (r *Repository) FinishBatch(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE uid_batches b
+		SET status = $2, completed_at = NOW(), error = NULL,
+			valid_count = c.valid, invalid_count = c.invalid, failed_count = c.failed
+		FROM (
+			SELECT COUNT(*) FILTER (WHERE v.valid) AS valid,
+				COUNT(*) FILTER (WHERE v.id IS NOT NULL AND NOT v.valid) AS invalid,
+				COUNT(*) FILTER (WHERE v.id IS NULL) AS failed
+			FROM uid_batch_items i
+			LEFT JOIN uid_validations v ON v.id = i.validation_id
+			WHERE i.batch_id = $1
+		) c
+		WHERE b.id = $1`, id, BatchCompleted)
+	if err != nil {
+		return fmt.Errorf("failed to finish batch: %w", err)
+	}
+	return nil
+}
+
+// FailBatch marks a batch as failed; its items keep the confirmations
+// obtained so far
+func (r *Repository) FailBatch(ctx context.Context, id uuid.UUID, errorMsg string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE uid_batches SET status = $2, completed_at = NOW(), error = $3,
+			failed_count = (SELECT COUNT(*) FROM uid_batch_items WHERE batch_id = $1 AND validation_id IS NULL)
+		WHERE id = $1`, id, BatchFailed, errorMsg)
+	if err != nil {
+		return fmt.Errorf("failed to fail batch: %w", err)
+	}
+	return nil
+}
+
+// ListItems returns the items of a batch with their confirmations
+func (r *Repository) ListItems(ctx context.Context, batchID uuid.UUID) ([]*BatchItem, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT i.position, i.uid, i.error, i.processed_at,
+			v.id, v.tenant_id, v.country_code, v.valid, v.level,
+			v.company_name, v.street, v.post_code, v.city, v.country,
+			v.error_code, v.error_message, v.source, v.consultation_number, v.validated_at, v.validated_by,
+			v.account_id, v.created_at
+		FROM uid_batch_items i
+		LEFT JOIN uid_validations v ON v.id = i.validation_id
+		WHERE i.batch_id = $1
+		ORDER BY i.position`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list batch items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*BatchItem
+	for rows.Next() {
+		var item BatchItem
+		var id, tenantID, validatedBy, accountID uuid.NullUUID
+		var countryCode, source sql.NullString
+		var valid sql.NullBool
+		var level, errorCode sql.NullInt32
+		var validatedAt, createdAt sql.NullTime
+		v := &Validation{}
+
+		err := rows.Scan(&item.Position, &item.UID, &item.Error, &item.ProcessedAt,
+			&id, &tenantID, &countryCode, &valid, &level,
+			&v.CompanyName, &v.Street, &v.PostCode, &v.City, &v.Country,
+			&errorCode, &v.ErrorMessage, &source, &v.ConsultationNumber, &validatedAt, &validatedBy,
+			&accountID, &createdAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan batch item: %w", err)
+		}
+
+		if id.Valid {
+			v.ID = id.UUID
+			v.TenantID = tenantID.UUID
+			v.UID = item.UID
+			v.CountryCode = countryCode.String
+			v.Valid = valid.Bool
+			v.Level = int(level.Int32)
+			v.Source = source.String
+			v.ValidatedAt = validatedAt.Time
+			v.CreatedAt = createdAt.Time
+			if errorCode.Valid {
+				c := int(errorCode.Int32)
+				v.ErrorCode = &c
+			}
+			if validatedBy.Valid {
+				v.ValidatedBy = &validatedBy.UUID
+			}
+			if accountID.Valid {
+				v.AccountID = &accountID.UUID
+			}
+			item.Validation = v
+		}
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
+
+// userContact returns the name and address of an active user
+func (r *Repository) userContact(ctx context.Context, userID uuid.UUID) (name, email string, err error) {
+	err = r.db.QueryRow(ctx, `SELECT name, email FROM users WHERE id = $1 AND is_active`, userID).Scan(&name, &email)
+	return name, email, err
+}
+
+// ListProofChecks returns the validations of a UID in [from, to), oldest
+// first, with the names of the user and FinanzOnline account that queried it
+func (r *Repository) ListProofChecks(ctx context.Context, tenantID uuid.UUID, uid string, from, to time.Time) ([]*ProofCheck, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT v.id, v.tenant_id, v.uid, v.country_code, v.valid, v.level,
+			v.company_name, v.street, v.post_code, v.city, v.country,
+			v.error_code, v.error_message, v.source, v.consultation_number, v.validated_at, v.validated_by,
+			v.account_id, v.created_at, COALESCE(u.name, ''), COALESCE(a.name, '')
+		FROM uid_validations v
+		LEFT JOIN users u ON u.id = v.validated_by
+		LEFT JOIN accounts a ON a.id = v.account_id
+		WHERE v.tenant_id = $1 AND v.uid = $2 AND v.validated_at >= $3 AND v.validated_at < $4
+		ORDER BY v.validated_at
+		LIMIT 10000`, tenantID, uid, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list proof checks: %w", err)
+	}
+	defer rows.Close()
+
+	var checks []*ProofCheck
+	for rows.Next() {
+		var c ProofCheck
+		v := &c.Validation
+		var errorCode sql.NullInt32
+		var validatedBy, accountID uuid.NullUUID
+
+		err := rows.Scan(
+			&v.ID, &v.TenantID, &v.UID, &v.CountryCode, &v.Valid, &v.Level,
+			&v.CompanyName, &v.Street, &v.PostCode, &v.City, &v.Country,
+			&errorCode, &v.ErrorMessage, &v.Source, &v.ConsultationNumber, &v.ValidatedAt, &validatedBy,
+			&accountID, &v.CreatedAt, &c.CheckedBy, &c.AccountName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan proof check: %w", err)
+		}
+		if errorCode.Valid {
+			code := int(errorCode.Int32)
+			v.ErrorCode = &code
+		}
+		if validatedBy.Valid {
+			v.ValidatedBy = &validatedBy.UUID
+		}
+		if accountID.Valid {
+			v.AccountID = &accountID.UUID
+		}
+		checks = append(checks, &c)
+	}
+	return checks, rows.Err()
+}
+
+// tenantName returns the name of a tenant
+func (r *Repository) tenantName(ctx context.Context, tenantID uuid.UUID) (string, error) {
+	var name string
+	err := r.db.QueryRow(ctx, `SELECT name FROM tenants WHERE id = $1`, tenantID).Scan(&name)
+	if err != nil {
+		return "", fmt.Errorf("failed to load tenant: %w", err)
+	}
+	return name, nil
+}
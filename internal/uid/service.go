@@ -9,7 +9,9 @@ import (
 
 	"austrian-business-infrastructure/internal/account"
 	"austrian-business-infrastructure/internal/account/types"
+	"austrian-business-infrastructure/internal/endpoint"
 	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/rawpayload"
 	"github.com/google/uuid"
 )
 
@@ -30,8 +32,12 @@ const DailyValidationLimit = 1000
 type Service struct {
 	repo           *Repository
 	accountService *account.Service
-	fonwsClient    *fonws.Client
+	fonwsClient    fonws.Caller
 	cacheDuration  time.Duration
+	payloads       *rawpayload.Service
+	scheduler      BatchScheduler
+	notifier       Notifier
+	appURL         string
 }
 
 // NewService creates a new UID validation service
@@ -49,6 +55,37 @@ func (s *Service) SetCacheDuration(d time.Duration) {
 	s.cacheDuration = d
 }
 
+// SetRawPayloads enables retention of the raw FinanzOnline exchanges of UID queries
+func (s *Service) SetRawPayloads(p *rawpayload.Service) {
+	s.payloads = p
+}
+
+// SetEndpoints makes FinanzOnline calls use the active endpoint set and
+// fail fast during maintenance windows
+func (s *Service) SetEndpoints(r fonws.EndpointResolver) {
+	if c, ok := s.fonwsClient.(*fonws.Client); ok {
+		c.SetEndpointResolver(r)
+	}
+}
+
+// SetBatchScheduler enables batch verification; batches are verified by
+// the worker
+func (s *Service) SetBatchScheduler(scheduler BatchScheduler) {
+	s.scheduler = scheduler
+}
+
+// SetNotifier enables the mail to the uploader of a finished batch, which
+// links the results in the web app at appURL
+func (s *Service) SetNotifier(notifier Notifier, appURL string) {
+	s.notifier = notifier
+	s.appURL = appURL
+}
+
+// SetFinanzOnline replaces the FinanzOnline client, e.g. with a fake in tests
+func (s *Service) SetFinanzOnline(c fonws.Caller) {
+	s.fonwsClient = c
+}
+
 // Validate validates a UID
 func (s *Service) Validate(ctx context.Context, tenantID, userID uuid.UUID, input *ValidateInput) (*Validation, error) {
 	// Validate level
@@ -62,7 +99,7 @@ func (s *Service) Validate(ctx context.Context, tenantID, userID uuid.UUID, inpu
 	// Check format first
 	formatResult := fonws.ValidateUIDFormat(uid)
 	if !formatResult.Valid {
-		return s.createValidation(ctx, tenantID, userID, input.AccountID, uid, formatResult.CountryCode, false, input.Level, nil, formatResult.Error)
+		return s.createValidation(ctx, tenantID, &userID, input.AccountID, uid, formatResult.CountryCode, false, input.Level, nil, formatResult.Error)
 	}
 
 	// Check cache
@@ -104,14 +141,20 @@ func (s *Service) Validate(ctx context.Context, tenantID, userID uuid.UUID, inpu
 	defer sessionService.Logout(session)
 
 	// Validate UID
-	uidService := fonws.NewUIDService(s.fonwsClient)
+	rec := rawpayload.NewRecorder()
+	uidService := fonws.NewUIDService(fonws.Recording(s.fonwsClient, rec))
 	result, err := uidService.Validate(session.Token, foCreds.TID, foCreds.BenID, uid, input.Level)
 	if err != nil {
+		s.savePayloads(ctx, tenantID, nil, err, rec)
 		return nil, fmt.Errorf("UID validation failed: %w", err)
 	}
 
 	// Store result
-	return s.createValidationFromResult(ctx, tenantID, userID, input.AccountID, input.Level, result)
+	v, err := s.createValidationFromResult(ctx, tenantID, &userID, input.AccountID, input.Level, result)
+	if v != nil {
+		s.savePayloads(ctx, tenantID, &v.ID, nil, rec)
+	}
+	return v, err
 }
 
 // ValidateBatch validates multiple UIDs
@@ -149,8 +192,6 @@ func (s *Service) ValidateBatch(ctx context.Context, tenantID, userID uuid.UUID,
 	}
 	defer sessionService.Logout(session)
 
-	uidService := fonws.NewUIDService(s.fonwsClient)
-
 	var validations []*Validation
 	for _, uid := range input.UIDs {
 		uid = strings.ToUpper(strings.TrimSpace(uid))
@@ -165,7 +206,7 @@ func (s *Service) ValidateBatch(ctx context.Context, tenantID, userID uuid.UUID,
 		// Check format
 		formatResult := fonws.ValidateUIDFormat(uid)
 		if !formatResult.Valid {
-			v, _ := s.createValidation(ctx, tenantID, userID, input.AccountID, uid, formatResult.CountryCode, false, input.Level, nil, formatResult.Error)
+			v, _ := s.createValidation(ctx, tenantID, &userID, input.AccountID, uid, formatResult.CountryCode, false, input.Level, nil, formatResult.Error)
 			if v != nil {
 				validations = append(validations, v)
 			}
@@ -173,24 +214,42 @@ func (s *Service) ValidateBatch(ctx context.Context, tenantID, userID uuid.UUID,
 		}
 
 		// Validate against FO
+		rec := rawpayload.NewRecorder()
+		uidService := fonws.NewUIDService(fonws.Recording(s.fonwsClient, rec))
 		result, err := uidService.Validate(session.Token, foCreds.TID, foCreds.BenID, uid, input.Level)
+		if errors.Is(err, endpoint.ErrMaintenanceWindow) {
+			return nil, err
+		}
 		if err != nil {
-			v, _ := s.createValidation(ctx, tenantID, userID, input.AccountID, uid, formatResult.CountryCode, false, input.Level, nil, err.Error())
+			v, _ := s.createValidation(ctx, tenantID, &userID, input.AccountID, uid, formatResult.CountryCode, false, input.Level, nil, err.Error())
 			if v != nil {
 				validations = append(validations, v)
+				s.savePayloads(ctx, tenantID, &v.ID, err, rec)
 			}
 			continue
 		}
 
-		v, _ := s.createValidationFromResult(ctx, tenantID, userID, input.AccountID, input.Level, result)
+		v, _ := s.createValidationFromResult(ctx, tenantID, &userID, input.AccountID, input.Level, result)
 		if v != nil {
 			validations = append(validations, v)
+			s.savePayloads(ctx, tenantID, &v.ID, nil, rec)
 		}
 	}
 
 	return validations, nil
 }
 
+// savePayloads retains the raw exchanges of a UID query
+func (s *Service) savePayloads(ctx context.Context, tenantID uuid.UUID, validationID *uuid.UUID, err error, rec *rawpayload.Recorder) {
+	s.payloads.Save(ctx, rawpayload.Reference{
+		TenantID:      &tenantID,
+		Module:        rawpayload.ModuleUID,
+		ReferenceType: "uid_validation",
+		ReferenceID:   validationID,
+		Outcome:       rawpayload.OutcomeOf(err == nil, false),
+	}, rec)
+}
+
 // ValidateFormat validates a UID format without querying FinanzOnline
 func (s *Service) ValidateFormat(uid string) *FormatValidationResult {
 	uid = strings.ToUpper(strings.TrimSpace(uid))
@@ -219,7 +278,7 @@ func (s *Service) List(ctx context.Context, filter ListFilter) ([]*Validation, i
 
 // Helper methods
 
-func (s *Service) createValidation(ctx context.Context, tenantID, userID, accountID uuid.UUID, uid, countryCode string, valid bool, level int, result *fonws.UIDValidationResult, errorMsg string) (*Validation, error) {
+func (s *Service) createValidation(ctx context.Context, tenantID uuid.UUID, userID *uuid.UUID, accountID uuid.UUID, uid, countryCode string, valid bool, level int, result *fonws.UIDValidationResult, errorMsg string) (*Validation, error) {
 	v := &Validation{
 		TenantID:    tenantID,
 		UID:         uid,
@@ -227,7 +286,7 @@ func (s *Service) createValidation(ctx context.Context, tenantID, userID, accoun
 		Valid:       valid,
 		Level:       level,
 		Source:      "finanzonline",
-		ValidatedBy: &userID,
+		ValidatedBy: userID,
 		AccountID:   &accountID,
 	}
 
@@ -238,7 +297,7 @@ func (s *Service) createValidation(ctx context.Context, tenantID, userID, accoun
 	return s.repo.Create(ctx, v)
 }
 
-func (s *Service) createValidationFromResult(ctx context.Context, tenantID, userID, accountID uuid.UUID, level int, result *fonws.UIDValidationResult) (*Validation, error) {
+func (s *Service) createValidationFromResult(ctx context.Context, tenantID uuid.UUID, userID *uuid.UUID, accountID uuid.UUID, level int, result *fonws.UIDValidationResult) (*Validation, error) {
 	v := &Validation{
 		TenantID:    tenantID,
 		UID:         result.UID,
@@ -246,7 +305,8 @@ func (s *Service) createValidationFromResult(ctx context.Context, tenantID, user
 		Valid:       result.Valid,
 		Level:       level,
 		Source:      result.Source,
-		ValidatedBy: &userID,
+		ValidatedAt: result.QueryTime,
+		ValidatedBy: userID,
 		AccountID:   &accountID,
 	}
 
@@ -271,6 +331,9 @@ func (s *Service) createValidationFromResult(ctx context.Context, tenantID, user
 	if result.ErrorMessage != "" {
 		v.ErrorMessage = &result.ErrorMessage
 	}
+	if result.ConsultationNumber != "" && level == Level2 {
+		v.ConsultationNumber = &result.ConsultationNumber
+	}
 
 	return s.repo.Create(ctx, v)
 }
@@ -309,6 +372,9 @@ func (s *Service) ExportCSV(ctx context.Context, tenantID uuid.UUID, filter List
 		if v.ErrorMessage != nil {
 			r.ErrorMessage = *v.ErrorMessage
 		}
+		if v.ConsultationNumber != nil {
+			r.ConsultationNumber = *v.ConsultationNumber
+		}
 		results = append(results, r)
 	}
 
@@ -14,24 +14,27 @@ const (
 
 // Validation represents a UID validation record
 type Validation struct {
-	ID           uuid.UUID  `json:"id"`
-	TenantID     uuid.UUID  `json:"tenant_id"`
-	UID          string     `json:"uid"`
-	CountryCode  string     `json:"country_code"`
-	Valid        bool       `json:"valid"`
-	Level        int        `json:"level"`
-	CompanyName  *string    `json:"company_name,omitempty"`
-	Street       *string    `json:"street,omitempty"`
-	PostCode     *string    `json:"post_code,omitempty"`
-	City         *string    `json:"city,omitempty"`
-	Country      *string    `json:"country,omitempty"`
-	ErrorCode    *int       `json:"error_code,omitempty"`
-	ErrorMessage *string    `json:"error_message,omitempty"`
-	Source       string     `json:"source"` // "finanzonline" or "vies"
-	ValidatedAt  time.Time  `json:"validated_at"`
-	ValidatedBy  *uuid.UUID `json:"validated_by,omitempty"`
-	AccountID    *uuid.UUID `json:"account_id,omitempty"`
-	CreatedAt    time.Time  `json:"created_at"`
+	ID           uuid.UUID `json:"id"`
+	TenantID     uuid.UUID `json:"tenant_id"`
+	UID          string    `json:"uid"`
+	CountryCode  string    `json:"country_code"`
+	Valid        bool      `json:"valid"`
+	Level        int       `json:"level"`
+	CompanyName  *string   `json:"company_name,omitempty"`
+	Street       *string   `json:"street,omitempty"`
+	PostCode     *string   `json:"post_code,omitempty"`
+	City         *string   `json:"city,omitempty"`
+	Country      *string   `json:"country,omitempty"`
+	ErrorCode    *int      `json:"error_code,omitempty"`
+	ErrorMessage *string   `json:"error_message,omitempty"`
+	Source       string    `json:"source"` // "finanzonline" or "vies"
+	// ConsultationNumber is the reference number of a Stufe 2 query, the
+	// proof of the check in a tax audit
+	ConsultationNumber *string    `json:"consultation_number,omitempty"`
+	ValidatedAt        time.Time  `json:"validated_at"`
+	ValidatedBy        *uuid.UUID `json:"validated_by,omitempty"`
+	AccountID          *uuid.UUID `json:"account_id,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
 }
 
 // ValidateInput represents input for validating a UID
@@ -63,20 +66,21 @@ type ListFilter struct {
 
 // ValidationResponse is the API response format
 type ValidationResponse struct {
-	ID           uuid.UUID `json:"id"`
-	UID          string    `json:"uid"`
-	CountryCode  string    `json:"country_code"`
-	Valid        bool      `json:"valid"`
-	Level        int       `json:"level"`
-	CompanyName  *string   `json:"company_name,omitempty"`
-	Street       *string   `json:"street,omitempty"`
-	PostCode     *string   `json:"post_code,omitempty"`
-	City         *string   `json:"city,omitempty"`
-	Country      *string   `json:"country,omitempty"`
-	ErrorMessage *string   `json:"error_message,omitempty"`
-	Source       string    `json:"source"`
-	ValidatedAt  string    `json:"validated_at"`
-	CreatedAt    string    `json:"created_at"`
+	ID                 uuid.UUID `json:"id"`
+	UID                string    `json:"uid"`
+	CountryCode        string    `json:"country_code"`
+	Valid              bool      `json:"valid"`
+	Level              int       `json:"level"`
+	CompanyName        *string   `json:"company_name,omitempty"`
+	Street             *string   `json:"street,omitempty"`
+	PostCode           *string   `json:"post_code,omitempty"`
+	City               *string   `json:"city,omitempty"`
+	Country            *string   `json:"country,omitempty"`
+	ErrorMessage       *string   `json:"error_message,omitempty"`
+	Source             string    `json:"source"`
+	ConsultationNumber *string   `json:"consultation_number,omitempty"`
+	ValidatedAt        string    `json:"validated_at"`
+	CreatedAt          string    `json:"created_at"`
 }
 
 // FormatValidationResult represents a format check result
@@ -89,9 +93,104 @@ type FormatValidationResult struct {
 
 // BatchValidationResponse is the API response for batch validation
 type BatchValidationResponse struct {
-	Total      int                   `json:"total"`
-	Valid      int                   `json:"valid"`
-	Invalid    int                   `json:"invalid"`
-	Results    []*ValidationResponse `json:"results"`
-	ProcessedAt string               `json:"processed_at"`
+	Total       int                   `json:"total"`
+	Valid       int                   `json:"valid"`
+	Invalid     int                   `json:"invalid"`
+	Results     []*ValidationResponse `json:"results"`
+	ProcessedAt string                `json:"processed_at"`
+}
+
+// Batch statuses
+const (
+	BatchPending   = "pending"
+	BatchRunning   = "running"
+	BatchCompleted = "completed"
+	BatchFailed    = "failed"
+)
+
+// Batch is an uploaded list of UIDs verified in the background
+type Batch struct {
+	ID           uuid.UUID  `json:"id"`
+	TenantID     uuid.UUID  `json:"tenant_id"`
+	AccountID    uuid.UUID  `json:"account_id"`
+	Level        int        `json:"level"`
+	FileName     *string    `json:"file_name,omitempty"`
+	Status       string     `json:"status"`
+	Total        int        `json:"total"`
+	ValidCount   int        `json:"valid_count"`
+	InvalidCount int        `json:"invalid_count"`
+	FailedCount  int        `json:"failed_count"`
+	Error        *string    `json:"error,omitempty"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
+
+// Done reports whether a batch has finished
+func (b *Batch) Done() bool {
+	return b.Status == BatchCompleted || b.Status == BatchFailed
+}
+
+// BatchItem is a UID of a batch with its confirmation, the validation
+// record of the query. Items without one could not be queried.
+type BatchItem struct {
+	Position    int         `json:"position"`
+	UID         string      `json:"uid"`
+	Validation  *Validation `json:"validation,omitempty"`
+	Error       *string     `json:"error,omitempty"`
+	ProcessedAt *time.Time  `json:"processed_at,omitempty"`
+}
+
+// CreateBatchInput represents input for creating a batch from a CSV upload
+type CreateBatchInput struct {
+	CSV       []byte
+	FileName  string
+	Level     int
+	AccountID uuid.UUID
+}
+
+// BatchResponse is the API response format of a batch
+type BatchResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	AccountID    uuid.UUID  `json:"account_id"`
+	Level        int        `json:"level"`
+	FileName     *string    `json:"file_name,omitempty"`
+	Status       string     `json:"status"`
+	Total        int        `json:"total"`
+	ValidCount   int        `json:"valid_count"`
+	InvalidCount int        `json:"invalid_count"`
+	FailedCount  int        `json:"failed_count"`
+	Error        *string    `json:"error,omitempty"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt    string     `json:"created_at"`
+	StartedAt    *string    `json:"started_at,omitempty"`
+	CompletedAt  *string    `json:"completed_at,omitempty"`
+}
+
+// BatchItemResponse is the API response format of a batch item
+type BatchItemResponse struct {
+	Position    int                 `json:"position"`
+	UID         string              `json:"uid"`
+	Validation  *ValidationResponse `json:"validation,omitempty"`
+	Error       *string             `json:"error,omitempty"`
+	ProcessedAt *string             `json:"processed_at,omitempty"`
+}
+
+// Proof is the evidence of due diligence for a counterparty: the checks of
+// its UID in a period, as tax audits ask for it
+type Proof struct {
+	TenantName  string        `json:"tenant_name"`
+	UID         string        `json:"uid"`
+	DateFrom    time.Time     `json:"date_from"` // First day
+	DateTo      time.Time     `json:"date_to"`   // Last day
+	Checks      []*ProofCheck `json:"checks"`
+	GeneratedAt time.Time     `json:"generated_at"`
+}
+
+// ProofCheck is a check of a proof with who made it
+type ProofCheck struct {
+	Validation
+	CheckedBy   string `json:"checked_by,omitempty"`   // Empty for checks of automatic re-validations
+	AccountName string `json:"account_name,omitempty"` // FinanzOnline account queried with
 }
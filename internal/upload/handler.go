@@ -13,6 +13,8 @@ import (
 	"github.com/google/uuid"
 
 	"austrian-business-infrastructure/internal/client"
+	"austrian-business-infrastructure/internal/filetype"
+	"austrian-business-infrastructure/internal/quota"
 	"austrian-business-infrastructure/internal/tenant"
 )
 
@@ -132,21 +134,22 @@ func (h *Handler) Upload(w http.ResponseWriter, r *http.Request) {
 		note = &n
 	}
 
-	// Detect content type
-	buffer := make([]byte, 512)
-	n, _ := file.Read(buffer)
-	mimeType := http.DetectContentType(buffer[:n])
-
-	// Reset file position
-	file.Seek(0, 0)
+	// Detect content type from the content and check it, with the page
+	// count and image size, before anything is stored
+	info, err := filetype.PortalUpload.Check(header.Filename, file, header.Size)
+	if err != nil {
+		http.Error(w, err.Error(), filetype.HTTPStatus(err))
+		return
+	}
 
 	// Create upload
 	req := &UploadRequest{
+		TenantID:  claims.TenantID,
 		ClientID:  claims.ClientID,
 		AccountID: accountID,
 		Filename:  header.Filename,
 		FileSize:  header.Size,
-		MimeType:  mimeType,
+		MimeType:  info.MIMEType,
 		Category:  category,
 		Note:      note,
 		Reader:    file,
@@ -162,6 +165,10 @@ func (h *Handler) Upload(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "file type not allowed", http.StatusBadRequest)
 			return
 		}
+		if errors.Is(err, quota.ErrQuotaExceeded) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
 		http.Error(w, "upload failed", http.StatusInternalServerError)
 		return
 	}
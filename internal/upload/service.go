@@ -12,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"austrian-business-infrastructure/internal/filetype"
 	"austrian-business-infrastructure/internal/storage"
 )
 
@@ -22,8 +23,14 @@ var (
 	ErrNoAccountAccess  = errors.New("no access to this account")
 )
 
+// QuotaChecker enforces the tenant storage quota
+type QuotaChecker interface {
+	CheckQuota(ctx context.Context, tenantID uuid.UUID, size int64) error
+}
+
 // UploadRequest contains data for creating an upload
 type UploadRequest struct {
+	TenantID  uuid.UUID
 	ClientID  uuid.UUID
 	AccountID uuid.UUID
 	Filename  string
@@ -39,8 +46,9 @@ type Service struct {
 	repo             *Repository
 	storage          storage.Client
 	maxFileSize      int64
-	allowedMimeTypes map[string]bool
+	policy           filetype.Policy
 	uploadPath       string
+	quotaChecker     QuotaChecker
 }
 
 // NewService creates a new upload service
@@ -50,18 +58,15 @@ func NewService(pool *pgxpool.Pool, storageClient storage.Client, maxFileSize in
 		storage:     storageClient,
 		maxFileSize: maxFileSize,
 		uploadPath:  uploadPath,
-		allowedMimeTypes: map[string]bool{
-			"application/pdf":                                                       true,
-			"image/jpeg":                                                             true,
-			"image/png":                                                              true,
-			"application/msword":                                                     true,
-			"application/vnd.openxmlformats-officedocument.wordprocessingml.document": true,
-			"application/vnd.ms-excel":                                               true,
-			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":      true,
-		},
+		policy:      filetype.PortalUpload,
 	}
 }
 
+// SetQuotaChecker sets the storage quota checker
+func (s *Service) SetQuotaChecker(checker QuotaChecker) {
+	s.quotaChecker = checker
+}
+
 // Repository returns the underlying repository
 func (s *Service) Repository() *Repository {
 	return s.repo
@@ -75,10 +80,17 @@ func (s *Service) Upload(ctx context.Context, req *UploadRequest) (*Upload, erro
 	}
 
 	// Validate file type
-	if !s.allowedMimeTypes[req.MimeType] {
+	if !s.policy.Allows(req.MimeType) {
 		return nil, ErrInvalidFileType
 	}
 
+	// Validate storage quota
+	if s.quotaChecker != nil {
+		if err := s.quotaChecker.CheckQuota(ctx, req.TenantID, req.FileSize); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate storage path
 	uploadID := uuid.New()
 	storagePath := s.generateStoragePath(req.ClientID, req.AccountID, uploadID, req.Filename)
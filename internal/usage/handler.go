@@ -0,0 +1,64 @@
+package usage
+
+import (
+	"errors"
+	"net/http"
+
+	"austrian-business-infrastructure/internal/api"
+	"github.com/google/uuid"
+)
+
+// Handler handles usage HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new usage handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers usage routes. Usage includes costs, so it is
+// admin-only.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/usage", requireAuth(requireAdmin(http.HandlerFunc(h.ListMetrics))))
+	router.Handle("GET /api/v1/usage/{metric}", requireAuth(requireAdmin(http.HandlerFunc(h.GetSeries))))
+}
+
+// ListMetrics handles GET /api/v1/usage
+func (h *Handler) ListMetrics(w http.ResponseWriter, r *http.Request) {
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"metrics": Metrics,
+	})
+}
+
+// GetSeries handles GET /api/v1/usage/{metric}?granularity=day|month&from=&to=
+func (h *Handler) GetSeries(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(api.GetTenantID(r.Context()))
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	q := r.URL.Query()
+	series, err := h.service.Series(r.Context(), tenantID, r.PathValue("metric"), q.Get("granularity"), q.Get("from"), q.Get("to"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, series)
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownMetric):
+		api.NotFound(w, "unknown usage metric")
+	case errors.Is(err, ErrInvalidGranularity), errors.Is(err, ErrInvalidRange):
+		api.BadRequest(w, err.Error())
+	case errors.Is(err, ErrRangeTooLarge):
+		api.BadRequest(w, "range too large (max 366 days or 120 months)")
+	default:
+		api.InternalError(w)
+	}
+}
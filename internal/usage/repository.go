@@ -0,0 +1,162 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles usage time-series database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new usage repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Source timestamps are bucketed into days in Europe/Vienna. $1 and $2 are
+// the first and last day; the bounds keep the created_at indexes usable.
+const (
+	dayOf    = `(created_at AT TIME ZONE 'Europe/Vienna')::date`
+	dayStart = `($1::date::timestamp AT TIME ZONE 'Europe/Vienna')`
+	dayEnd   = `(($2::date + 1)::timestamp AT TIME ZONE 'Europe/Vienna')`
+)
+
+// metricSources select (tenant_id, metric, day, value) rows for a range of days
+var metricSources = []string{
+	`
+		SELECT tenant_id, '` + MetricDocumentsUploaded + `', ` + dayOf + `, COUNT(*)
+		FROM documents
+		WHERE created_at >= ` + dayStart + ` AND created_at < ` + dayEnd + `
+		GROUP BY 1, 3`,
+	`
+		SELECT tenant_id, '` + MetricDocumentsAnalyzed + `', ` + dayOf + `, COUNT(*)
+		FROM document_analyses
+		WHERE status IN ('completed', 'needs_review')
+		  AND created_at >= ` + dayStart + ` AND created_at < ` + dayEnd + `
+		GROUP BY 1, 3`,
+	`
+		SELECT tenant_id, m.metric, ` + dayOf + `,
+		       CASE m.metric
+		           WHEN '` + MetricAIRequests + `' THEN COUNT(*)
+		           WHEN '` + MetricAITokens + `' THEN SUM(total_tokens)
+		           ELSE SUM(cost_cents)
+		       END
+		FROM ai_usage_logs
+		CROSS JOIN (VALUES ('` + MetricAIRequests + `'), ('` + MetricAITokens + `'), ('` + MetricAICostCents + `')) AS m(metric)
+		WHERE success
+		  AND created_at >= ` + dayStart + ` AND created_at < ` + dayEnd + `
+		GROUP BY 1, 2, 3`,
+	`
+		SELECT tenant_id, m.metric, usage_date,
+		       CASE m.metric
+		           WHEN '` + MetricSignatures + `' THEN SUM(signature_count)
+		           ELSE SUM(COALESCE(cost_cents, 0))
+		       END
+		FROM signature_usage
+		CROSS JOIN (VALUES ('` + MetricSignatures + `'), ('` + MetricSignatureCostCents + `')) AS m(metric)
+		WHERE usage_date BETWEEN $1::date AND $2::date
+		GROUP BY 1, 2, 3`,
+}
+
+// Aggregate recomputes the daily values of all tenants for a range of days
+// and the monthly values of the months it touches, in one transaction.
+// Days without source rows end up without a stored value, which reads as zero.
+func (r *Repository) Aggregate(ctx context.Context, from, to time.Time) (int64, error) {
+	startedAt := time.Now()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM usage_metrics_daily WHERE day BETWEEN $1 AND $2`, from, to); err != nil {
+		return 0, fmt.Errorf("failed to clear daily usage: %w", err)
+	}
+
+	var written int64
+	for _, source := range metricSources {
+		result, err := tx.Exec(ctx, `
+			INSERT INTO usage_metrics_daily (tenant_id, metric, day, value, computed_at)
+			SELECT s.tenant_id, s.metric, s.day, s.value, NOW()
+			FROM (`+source+`) AS s(tenant_id, metric, day, value)
+			WHERE s.value > 0`, from, to)
+		if err != nil {
+			return 0, fmt.Errorf("failed to aggregate daily usage: %w", err)
+		}
+		written += result.RowsAffected()
+	}
+
+	monthFrom := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthTo := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, -1)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM usage_metrics_monthly WHERE month BETWEEN $1 AND $2`, monthFrom, monthTo); err != nil {
+		return 0, fmt.Errorf("failed to clear monthly usage: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO usage_metrics_monthly (tenant_id, metric, month, value, computed_at)
+		SELECT tenant_id, metric, date_trunc('month', day)::date, SUM(value), NOW()
+		FROM usage_metrics_daily
+		WHERE day BETWEEN $1 AND $2
+		GROUP BY 1, 2, 3`, monthFrom, monthTo); err != nil {
+		return 0, fmt.Errorf("failed to roll up monthly usage: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO usage_aggregation_runs (day_from, day_to, rows_written, started_at)
+		VALUES ($1, $2, $3, $4)`, from, to, written, startedAt); err != nil {
+		return 0, fmt.Errorf("failed to record aggregation run: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit usage aggregation: %w", err)
+	}
+	return written, nil
+}
+
+// AsOf returns the last aggregated day, or nil before the first run
+func (r *Repository) AsOf(ctx context.Context) (*time.Time, error) {
+	var asOf *time.Time
+	err := r.db.QueryRow(ctx, `SELECT MAX(day_to) FROM usage_aggregation_runs`).Scan(&asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last aggregation: %w", err)
+	}
+	return asOf, nil
+}
+
+// Values returns the stored values of a tenant's metric in a range, keyed
+// by period
+func (r *Repository) Values(ctx context.Context, tenantID uuid.UUID, metric string, g Granularity, rng Range) (map[string]int64, error) {
+	query := `
+		SELECT day, value FROM usage_metrics_daily
+		WHERE tenant_id = $1 AND metric = $2 AND day BETWEEN $3 AND $4`
+	if g == GranularityMonth {
+		query = `
+			SELECT month, value FROM usage_metrics_monthly
+			WHERE tenant_id = $1 AND metric = $2 AND month BETWEEN $3 AND $4`
+	}
+
+	rows, err := r.db.Query(ctx, query, tenantID, metric, rng.From, rng.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+
+	values := make(map[string]int64)
+	var period time.Time
+	var value int64
+	_, err = pgx.ForEachRow(rows, []any{&period, &value}, func() error {
+		values[Period(g, period)] = value
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan usage: %w", err)
+	}
+	return values, nil
+}
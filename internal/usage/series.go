@@ -0,0 +1,108 @@
+package usage
+
+import (
+	"time"
+)
+
+// ParseGranularity validates a granularity, defaulting to day
+func ParseGranularity(s string) (Granularity, error) {
+	switch Granularity(s) {
+	case "", GranularityDay:
+		return GranularityDay, nil
+	case GranularityMonth:
+		return GranularityMonth, nil
+	default:
+		return "", ErrInvalidGranularity
+	}
+}
+
+// ParseRange parses the from/to parameters of a series request. Days are
+// YYYY-MM-DD, months YYYY-MM. Missing ends default to the last 30 days or
+// 12 months up to today.
+func ParseRange(g Granularity, from, to string, today time.Time) (Range, error) {
+	layout, maxBuckets := DayLayout, MaxDays
+	if g == GranularityMonth {
+		layout, maxBuckets = MonthLayout, MaxMonths
+	}
+
+	var rng Range
+	var err error
+	if to == "" {
+		rng.To = truncate(g, today)
+	} else if rng.To, err = time.Parse(layout, to); err != nil {
+		return Range{}, ErrInvalidRange
+	}
+	if from == "" {
+		if g == GranularityMonth {
+			rng.From = rng.To.AddDate(0, -(DefaultMonths - 1), 0)
+		} else {
+			rng.From = rng.To.AddDate(0, 0, -(DefaultDays - 1))
+		}
+	} else if rng.From, err = time.Parse(layout, from); err != nil {
+		return Range{}, ErrInvalidRange
+	}
+
+	if rng.From.After(rng.To) {
+		return Range{}, ErrInvalidRange
+	}
+	if buckets(g, rng) > maxBuckets {
+		return Range{}, ErrRangeTooLarge
+	}
+	return rng, nil
+}
+
+// FillSeries builds a series over the range from the stored values, keyed
+// by period. Buckets without a stored value are zero.
+func FillSeries(m Metric, g Granularity, rng Range, values map[string]int64) *Series {
+	s := &Series{
+		Metric:      m.Name,
+		Unit:        m.Unit,
+		Granularity: g,
+		From:        Period(g, rng.From),
+		To:          Period(g, rng.To),
+		Points:      make([]Point, 0, buckets(g, rng)),
+	}
+	for t := rng.From; !t.After(rng.To); t = next(g, t) {
+		p := Point{Period: Period(g, t), Value: values[Period(g, t)]}
+		s.Points = append(s.Points, p)
+		s.Total += p.Value
+	}
+	return s
+}
+
+// Period formats the bucket containing t
+func Period(g Granularity, t time.Time) string {
+	if g == GranularityMonth {
+		return t.Format(MonthLayout)
+	}
+	return t.Format(DayLayout)
+}
+
+// LastDay returns the last day covered by the range
+func (r Range) LastDay(g Granularity) time.Time {
+	if g == GranularityMonth {
+		return r.To.AddDate(0, 1, -1)
+	}
+	return r.To
+}
+
+func truncate(g Granularity, t time.Time) time.Time {
+	if g == GranularityMonth {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func next(g Granularity, t time.Time) time.Time {
+	if g == GranularityMonth {
+		return t.AddDate(0, 1, 0)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+func buckets(g Granularity, r Range) int {
+	if g == GranularityMonth {
+		return (r.To.Year()-r.From.Year())*12 + int(r.To.Month()-r.From.Month()) + 1
+	}
+	return int(r.To.Sub(r.From).Hours()/24) + 1
+}
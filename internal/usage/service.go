@@ -0,0 +1,91 @@
+package usage
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/timezone"
+)
+
+// vienna is the zone days are counted in, matching the aggregation queries
+var vienna = timezone.Vienna
+
+// Service aggregates usage and serves the per-tenant series
+type Service struct {
+	repo *Repository
+	now  func() time.Time
+}
+
+// NewService creates a new usage service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// Today returns the current day in Europe/Vienna
+func (s *Service) Today() time.Time {
+	t := s.now().In(vienna)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Series returns a tenant's metric per day or month. from and to are
+// request parameters and may be empty.
+func (s *Service) Series(ctx context.Context, tenantID uuid.UUID, metric string, granularity, from, to string) (*Series, error) {
+	m, err := LookupMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+	g, err := ParseGranularity(granularity)
+	if err != nil {
+		return nil, err
+	}
+	rng, err := ParseRange(g, from, to, s.Today())
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := s.repo.Values(ctx, tenantID, m.Name, g, rng)
+	if err != nil {
+		return nil, err
+	}
+	series := FillSeries(m, g, rng, values)
+
+	asOf, err := s.repo.AsOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if asOf != nil {
+		day := asOf.Format(DayLayout)
+		series.AsOf = &day
+	}
+	return series, nil
+}
+
+// AggregateSettled recomputes the last SettleDays days up to yesterday.
+// Older days are left as they are, so published values stay stable.
+func (s *Service) AggregateSettled(ctx context.Context) (*AggregationResult, error) {
+	to := s.Today().AddDate(0, 0, -1)
+	return s.Aggregate(ctx, to.AddDate(0, 0, -(SettleDays-1)), to)
+}
+
+// Aggregate recomputes an inclusive range of days, e.g. to backfill after
+// deploying or to correct source data
+func (s *Service) Aggregate(ctx context.Context, from, to time.Time) (*AggregationResult, error) {
+	if from.After(to) {
+		return nil, ErrInvalidRange
+	}
+	if int(to.Sub(from).Hours()/24)+1 > MaxDays {
+		return nil, ErrRangeTooLarge
+	}
+
+	written, err := s.repo.Aggregate(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &AggregationResult{
+		From:        from.Format(DayLayout),
+		To:          to.Format(DayLayout),
+		RowsWritten: written,
+	}, nil
+}
@@ -0,0 +1,110 @@
+package usage
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnknownMetric      = errors.New("unknown usage metric")
+	ErrInvalidGranularity = errors.New("invalid granularity, expected day or month")
+	ErrInvalidRange       = errors.New("invalid range, expected from <= to in YYYY-MM-DD (day) or YYYY-MM (month)")
+	ErrRangeTooLarge      = errors.New("range too large")
+)
+
+// Metric names
+const (
+	MetricDocumentsUploaded  = "documents_uploaded"
+	MetricDocumentsAnalyzed  = "documents_analyzed"
+	MetricAIRequests         = "ai_requests"
+	MetricAITokens           = "ai_tokens"
+	MetricAICostCents        = "ai_cost_cents"
+	MetricSignatures         = "signatures"
+	MetricSignatureCostCents = "signature_cost_cents"
+)
+
+// Metric describes a usage metric
+type Metric struct {
+	Name        string `json:"name"`
+	Unit        string `json:"unit"`
+	Description string `json:"description"`
+}
+
+// Metrics are the metrics that are aggregated, in display order
+var Metrics = []Metric{
+	{MetricDocumentsUploaded, "count", "Documents received from the Databox or uploaded"},
+	{MetricDocumentsAnalyzed, "count", "Documents analyzed by the AI pipeline"},
+	{MetricAIRequests, "count", "Successful AI requests"},
+	{MetricAITokens, "tokens", "Input and output tokens of successful AI requests"},
+	{MetricAICostCents, "cents", "AI cost in euro cents"},
+	{MetricSignatures, "count", "Qualified electronic signatures"},
+	{MetricSignatureCostCents, "cents", "Signature cost in euro cents"},
+}
+
+// LookupMetric returns a metric by name
+func LookupMetric(name string) (Metric, error) {
+	for _, m := range Metrics {
+		if m.Name == name {
+			return m, nil
+		}
+	}
+	return Metric{}, ErrUnknownMetric
+}
+
+// Granularity is the bucket size of a series
+type Granularity string
+
+const (
+	GranularityDay   Granularity = "day"
+	GranularityMonth Granularity = "month"
+)
+
+// Defaults and limits of series requests
+const (
+	DefaultDays   = 30
+	DefaultMonths = 12
+	MaxDays       = 366
+	MaxMonths     = 120
+
+	// SettleDays is how many days back the nightly run recomputes, so that
+	// late rows (retried jobs, backdated signatures) still land in their day
+	SettleDays = 3
+)
+
+// Layouts of the from/to parameters and series periods
+const (
+	DayLayout   = "2006-01-02"
+	MonthLayout = "2006-01"
+)
+
+// Point is one bucket of a series
+type Point struct {
+	Period string `json:"period"`
+	Value  int64  `json:"value"`
+}
+
+// Series is a metric over a range of buckets. Buckets without data are zero.
+type Series struct {
+	Metric      string      `json:"metric"`
+	Unit        string      `json:"unit"`
+	Granularity Granularity `json:"granularity"`
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	AsOf        *string     `json:"as_of"`
+	Points      []Point     `json:"points"`
+	Total       int64       `json:"total"`
+}
+
+// Range is an inclusive range of days. For month series both ends are the
+// first day of their month.
+type Range struct {
+	From time.Time
+	To   time.Time
+}
+
+// AggregationResult summarizes an aggregation run
+type AggregationResult struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	RowsWritten int64  `json:"rows_written"`
+}
@@ -43,6 +43,7 @@ type UserDTO struct {
 	EmailVerified bool    `json:"email_verified"`
 	AvatarURL     *string `json:"avatar_url,omitempty"`
 	IsActive      bool    `json:"is_active"`
+	FinanceAccess bool    `json:"finance_access"`
 	LastLoginAt   *string `json:"last_login_at,omitempty"`
 	CreatedAt     string  `json:"created_at"`
 }
@@ -96,6 +97,7 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if u.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "user", u.ID.String())
 		api.NotFound(w, "User not found")
 		return
 	}
@@ -107,6 +109,9 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 type UpdateRequest struct {
 	Name *string `json:"name,omitempty"`
 	Role *string `json:"role,omitempty"`
+	// FinanceAccess grants or revokes seeing amounts, e.g. in analysis
+	// notifications; it has no effect on admins and owners
+	FinanceAccess *bool `json:"finance_access,omitempty"`
 }
 
 // Update handles PATCH /api/v1/users/{id}
@@ -140,6 +145,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if u.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "user", u.ID.String())
 		api.NotFound(w, "User not found")
 		return
 	}
@@ -164,8 +170,12 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		u.Role = newRole
-	} else if req.Name != nil {
-		// Just update name
+	}
+
+	if req.FinanceAccess != nil {
+		u.FinanceAccess = req.FinanceAccess
+	}
+	if req.Name != nil || req.FinanceAccess != nil {
 		if err := h.service.repo.Update(r.Context(), u); err != nil {
 			h.logger.Error("failed to update user", "error", err)
 			api.InternalError(w)
@@ -221,6 +231,7 @@ func (h *Handler) Deactivate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if u.TenantID != tenantID {
+		api.ReportTenantMismatch(r, "user", u.ID.String())
 		api.NotFound(w, "User not found")
 		return
 	}
@@ -318,6 +329,7 @@ func toUserDTO(u *User) *UserDTO {
 		EmailVerified: u.EmailVerified,
 		AvatarURL:     u.AvatarURL,
 		IsActive:      u.IsActive,
+		FinanceAccess: HasFinanceAccess(u.Role, u.FinanceAccess),
 		CreatedAt:     u.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 
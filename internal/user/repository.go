@@ -39,6 +39,19 @@ func IsValidRole(role string) bool {
 	return false
 }
 
+// HasFinanceAccess reports whether users with the role and the stored
+// override may see amounts. Admins and owners always may; otherwise the
+// override decides and without one members may, viewers may not.
+func HasFinanceAccess(role Role, override *bool) bool {
+	if role == RoleOwner || role == RoleAdmin {
+		return true
+	}
+	if override != nil {
+		return *override
+	}
+	return role == RoleMember
+}
+
 // User represents a user in the system
 type User struct {
 	ID              uuid.UUID  `json:"id"`
@@ -59,6 +72,9 @@ type User struct {
 	TOTPEnabled       bool   `json:"totp_enabled"`
 	RecoveryCodes     []byte `json:"-"` // Encrypted recovery codes
 	RecoveryCodesUsed int    `json:"recovery_codes_used,omitempty"`
+	// FinanceAccess overrides the finance permission of the role; nil
+	// keeps the role default (see HasFinanceAccess)
+	FinanceAccess *bool     `json:"finance_access,omitempty"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
 }
@@ -120,7 +136,7 @@ func (r *Repository) Create(ctx context.Context, user *User) error {
 const userColumns = `id, tenant_id, email, password_hash, name, role,
 	email_verified, email_verified_at, oauth_provider, oauth_id,
 	avatar_url, last_login_at, is_active, totp_secret, totp_enabled,
-	recovery_codes, recovery_codes_used, created_at, updated_at`
+	recovery_codes, recovery_codes_used, finance_access, created_at, updated_at`
 
 // GetByID retrieves a user by ID
 func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
@@ -177,7 +193,7 @@ func (r *Repository) Update(ctx context.Context, user *User) error {
 	query := `
 		UPDATE users
 		SET email = $2, name = $3, role = $4, email_verified = $5,
-			avatar_url = $6, is_active = $7, updated_at = NOW()
+			avatar_url = $6, is_active = $7, finance_access = $8, updated_at = NOW()
 		WHERE id = $1
 		RETURNING updated_at
 	`
@@ -190,6 +206,7 @@ func (r *Repository) Update(ctx context.Context, user *User) error {
 		user.EmailVerified,
 		user.AvatarURL,
 		user.IsActive,
+		user.FinanceAccess,
 	).Scan(&user.UpdatedAt)
 
 	if err != nil {
@@ -310,6 +327,7 @@ func (r *Repository) scanUser(row pgx.Row) (*User, error) {
 		&user.TOTPEnabled,
 		&user.RecoveryCodes,
 		&user.RecoveryCodesUsed,
+		&user.FinanceAccess,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -345,6 +363,7 @@ func (r *Repository) scanUserFromRows(rows pgx.Rows) (*User, error) {
 		&user.TOTPEnabled,
 		&user.RecoveryCodes,
 		&user.RecoveryCodesUsed,
+		&user.FinanceAccess,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
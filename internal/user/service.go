@@ -28,9 +28,16 @@ type CreateUserInput struct {
 	Role     Role
 }
 
+// PasswordPolicySource returns the password requirements of a tenant;
+// auth.SecurityPolicyService implements it
+type PasswordPolicySource interface {
+	PasswordPolicy(ctx context.Context, tenantID uuid.UUID) (*crypto.PasswordPolicy, error)
+}
+
 // Service provides user business logic
 type Service struct {
-	repo *Repository
+	repo             *Repository
+	passwordPolicies PasswordPolicySource
 }
 
 // NewService creates a new user service
@@ -38,6 +45,21 @@ func NewService(repo *Repository) *Service {
 	return &Service{repo: repo}
 }
 
+// SetPasswordPolicies makes passwords meet the policy of the user's
+// tenant. Without it the default policy applies.
+func (s *Service) SetPasswordPolicies(policies PasswordPolicySource) {
+	s.passwordPolicies = policies
+}
+
+// passwordPolicy returns the password requirements of a tenant; nil is
+// the default policy
+func (s *Service) passwordPolicy(ctx context.Context, tenantID uuid.UUID) (*crypto.PasswordPolicy, error) {
+	if s.passwordPolicies == nil {
+		return nil, nil
+	}
+	return s.passwordPolicies.PasswordPolicy(ctx, tenantID)
+}
+
 // Create creates a new user with password
 func (s *Service) Create(ctx context.Context, input *CreateUserInput) (*User, error) {
 	// Validate email
@@ -51,7 +73,11 @@ func (s *Service) Create(ctx context.Context, input *CreateUserInput) (*User, er
 	}
 
 	// Validate and hash password
-	if err := crypto.ValidatePassword(input.Password, nil); err != nil {
+	policy, err := s.passwordPolicy(ctx, input.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := crypto.ValidatePassword(input.Password, policy); err != nil {
 		return nil, fmt.Errorf("invalid password: %w", err)
 	}
 
@@ -101,6 +127,35 @@ func (s *Service) CreateOAuthUser(ctx context.Context, tenantID uuid.UUID, email
 	return user, nil
 }
 
+// CreateSSOUser creates a user provisioned on first single sign-on. The
+// user has no password; subject identifies them at the SSO provider.
+func (s *Service) CreateSSOUser(ctx context.Context, tenantID uuid.UUID, email, name string, role Role, subject string) (*User, error) {
+	if !isValidEmail(email) {
+		return nil, ErrInvalidEmail
+	}
+	if role == RoleOwner {
+		return nil, ErrInvalidRole
+	}
+
+	provider := "sso"
+	user := &User{
+		TenantID:      tenantID,
+		Email:         normalizeEmail(email),
+		Name:          name,
+		Role:          role,
+		OAuthProvider: &provider,
+		OAuthID:       &subject,
+		EmailVerified: true, // Only verified or domain-restricted emails are provisioned
+		IsActive:      true,
+	}
+
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
 // GetByID retrieves a user by ID
 func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
 	return s.repo.GetByID(ctx, id)
@@ -202,7 +257,11 @@ func (s *Service) UpdatePassword(ctx context.Context, userID uuid.UUID, currentP
 	}
 
 	// Validate new password
-	if err := crypto.ValidatePassword(newPassword, nil); err != nil {
+	policy, err := s.passwordPolicy(ctx, user.TenantID)
+	if err != nil {
+		return err
+	}
+	if err := crypto.ValidatePassword(newPassword, policy); err != nil {
 		return err
 	}
 
@@ -0,0 +1,158 @@
+package uva
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotCorrectable   = errors.New("only submitted or accepted filings can be corrected")
+	ErrAlreadyCorrected = errors.New("filing was already corrected, correct the latest filing of the chain")
+	ErrNoChanges        = errors.New("correction does not change any Kennzahl")
+)
+
+// KennzahlDelta is the change of a Kennzahl by a Berichtigung, in cents
+type KennzahlDelta struct {
+	Kennzahl   string `json:"kennzahl"` // e.g. "kz017"
+	Before     int64  `json:"before"`
+	After      int64  `json:"after"`
+	Difference int64  `json:"difference"`
+}
+
+// ChainEntry is a filing of a correction chain with its changes against
+// the filing it corrects; the original has no delta
+type ChainEntry struct {
+	Submission *Submission
+	Delta      []KennzahlDelta
+}
+
+// kennzahl is the value of a Kennzahl, by its JSON field name
+type kennzahl struct {
+	name  string
+	value int64
+}
+
+// kennzahlen returns the Kennzahlen of a UVA in form order
+func (d *UVAData) kennzahlen() []kennzahl {
+	return []kennzahl{
+		{"kz000", d.KZ000}, {"kz001", d.KZ001}, {"kz011", d.KZ011}, {"kz017", d.KZ017},
+		{"kz018", d.KZ018}, {"kz019", d.KZ019}, {"kz020", d.KZ020}, {"kz022", d.KZ022},
+		{"kz029", d.KZ029}, {"kz060", d.KZ060}, {"kz065", d.KZ065}, {"kz066", d.KZ066},
+		{"kz070", d.KZ070}, {"kz095", d.KZ095},
+	}
+}
+
+// Delta returns the Kennzahlen a Berichtigung changes, in form order
+func Delta(before, after *UVAData) []KennzahlDelta {
+	var delta []KennzahlDelta
+	a := after.kennzahlen()
+	for i, b := range before.kennzahlen() {
+		if b.value != a[i].value {
+			delta = append(delta, KennzahlDelta{
+				Kennzahl:   b.name,
+				Before:     b.value,
+				After:      a[i].value,
+				Difference: a[i].value - b.value,
+			})
+		}
+	}
+	return delta
+}
+
+// CreateCorrection creates a Berichtigung of a filing: a draft for the same
+// period with the corrected Kennzahlen, linked to the filing it corrects.
+// Only the latest submitted filing of a chain can be corrected. The
+// Berichtigung is validated and submitted like any other UVA and resubmits
+// all Kennzahlen of the period.
+func (s *Service) CreateCorrection(ctx context.Context, id, tenantID uuid.UUID, input *UpdateSubmissionInput) (*Submission, error) {
+	original, err := s.repo.GetByID(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if original.Status != StatusSubmitted && original.Status != StatusAccepted {
+		return nil, ErrNotCorrectable
+	}
+
+	corrected, err := s.repo.HasCorrection(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if corrected {
+		return nil, ErrAlreadyCorrected
+	}
+
+	if input.Data.KZ095 == 0 {
+		input.Data.KZ095 = CalculateKZ095(&input.Data)
+	}
+	dataJSON, err := json.Marshal(input.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize data: %w", err)
+	}
+
+	correction, err := s.repo.Create(ctx, &Submission{
+		TenantID:      tenantID,
+		AccountID:     original.AccountID,
+		PeriodYear:    original.PeriodYear,
+		PeriodMonth:   original.PeriodMonth,
+		PeriodQuarter: original.PeriodQuarter,
+		PeriodType:    original.PeriodType,
+		Data:          dataJSON,
+		CorrectsID:    &original.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, correction.ID, tenantID)
+}
+
+// Chain returns the correction chain a filing belongs to, from the original
+// to the latest Berichtigung
+func (s *Service) Chain(ctx context.Context, id, tenantID uuid.UUID) ([]*ChainEntry, error) {
+	submissions, err := s.repo.ListChain(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[uuid.UUID]*UVAData, len(submissions))
+	chain := make([]*ChainEntry, 0, len(submissions))
+	for _, submission := range submissions {
+		d, err := ParseData(submission.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse submission data: %w", err)
+		}
+		data[submission.ID] = d
+
+		entry := &ChainEntry{Submission: submission}
+		if submission.CorrectsID != nil {
+			if before, ok := data[*submission.CorrectsID]; ok {
+				entry.Delta = Delta(before, d)
+			}
+		}
+		chain = append(chain, entry)
+	}
+	return chain, nil
+}
+
+// checkCorrection rejects a Berichtigung that does not change the filing it
+// corrects
+func (s *Service) checkCorrection(ctx context.Context, submission *Submission, data *UVAData) error {
+	if submission.CorrectsID == nil {
+		return nil
+	}
+	original, err := s.repo.GetByID(ctx, *submission.CorrectsID, submission.TenantID)
+	if err != nil {
+		return err
+	}
+	before, err := ParseData(original.Data)
+	if err != nil {
+		return fmt.Errorf("failed to parse submission data: %w", err)
+	}
+	if len(Delta(before, data)) == 0 {
+		return ErrNoChanges
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package uva
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"austrian-business-infrastructure/internal/account"
+	"austrian-business-infrastructure/internal/dryrun"
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/xmlschema"
+	"github.com/google/uuid"
+)
+
+// CheckInput adds the findings of the field and business rules of a UVA to
+// the report: the period, non-negative Kennzahlen, the Zahllast and the
+// filing deadline. A zero KZ095 is filled in as on Create.
+func CheckInput(report *dryrun.Report, input *CreateSubmissionInput, now time.Time) {
+	if input.PeriodYear < 2000 || input.PeriodYear > 2100 {
+		report.Error("invalid_year", "period_year", ErrInvalidYear.Error())
+	}
+	switch input.PeriodType {
+	case PeriodTypeMonthly:
+		if input.PeriodMonth == nil {
+			report.Error("missing_month", "period_month", "month is required for monthly period")
+		} else if *input.PeriodMonth < 1 || *input.PeriodMonth > 12 {
+			report.Error("invalid_month", "period_month", ErrInvalidMonth.Error())
+		}
+	case PeriodTypeQuarterly:
+		if input.PeriodQuarter == nil {
+			report.Error("missing_quarter", "period_quarter", "quarter is required for quarterly period")
+		} else if *input.PeriodQuarter < 1 || *input.PeriodQuarter > 4 {
+			report.Error("invalid_quarter", "period_quarter", ErrInvalidQuarter.Error())
+		}
+	default:
+		report.Error("invalid_period_type", "period_type", "period type must be 'monthly' or 'quarterly'")
+	}
+
+	d := &input.Data
+	// KZ095 (Zahllast/Gutschrift) may be negative for a refund
+	for _, kz := range []struct {
+		field string
+		value int64
+	}{
+		{"kz000", d.KZ000}, {"kz001", d.KZ001}, {"kz011", d.KZ011}, {"kz017", d.KZ017},
+		{"kz018", d.KZ018}, {"kz019", d.KZ019}, {"kz020", d.KZ020}, {"kz022", d.KZ022},
+		{"kz029", d.KZ029}, {"kz060", d.KZ060}, {"kz065", d.KZ065}, {"kz066", d.KZ066},
+		{"kz070", d.KZ070},
+	} {
+		if kz.value < 0 {
+			report.Error("negative_amount", "data."+kz.field, fmt.Sprintf("%s must be non-negative", kz.field))
+		}
+	}
+
+	if d.KZ001+d.KZ011 > d.KZ000 {
+		report.Warn("exempt_exceeds_total", "data.kz000", "tax-exempt supplies (KZ001, KZ011) exceed the total of all supplies (KZ000)")
+	}
+	calculated := CalculateKZ095(d)
+	if d.KZ095 == 0 {
+		d.KZ095 = calculated
+	} else if d.KZ095 != calculated {
+		report.Warn("kz095_mismatch", "data.kz095",
+			fmt.Sprintf("Zahllast %d differs from the %d calculated from the Kennzahlen", d.KZ095, calculated))
+	}
+
+	_, to, err := PeriodBounds(input.PeriodYear, input.PeriodType, input.PeriodMonth, input.PeriodQuarter)
+	if err != nil {
+		return
+	}
+	if now.Before(to.AddDate(0, 0, 1)) {
+		report.Warn("period_open", "", fmt.Sprintf("the period ends on %s", to.Format("2006-01-02")))
+	}
+	// Due on the 15th of the second month after the period
+	due := time.Date(to.Year(), to.Month()+2, 15, 0, 0, 0, 0, time.UTC)
+	if now.After(due.AddDate(0, 0, 1)) {
+		report.Warn("deadline_passed", "", fmt.Sprintf("the filing deadline was %s", due.Format("2006-01-02")))
+	}
+}
+
+// DryRun runs the full validation of a UVA as Create and Submit would,
+// including the account, duplicate and schema checks, without storing
+// anything or contacting FinanzOnline
+func (s *Service) DryRun(ctx context.Context, tenantID uuid.UUID, input *CreateSubmissionInput) (*dryrun.Report, error) {
+	report := dryrun.NewReport(dryrun.KindUVA)
+	now := time.Now()
+	CheckInput(report, input, now)
+
+	if input.AccountID == uuid.Nil {
+		report.Error("missing_account", "account_id", "account_id is required")
+	} else if acc, err := s.accountService.GetAccount(ctx, input.AccountID, tenantID); err != nil {
+		report.Error("account_not_found", "account_id", ErrAccountNotFound.Error())
+	} else if acc.Type != account.AccountTypeFinanzOnline {
+		report.Error("wrong_account_type", "account_id", "account must be a FinanzOnline account")
+	}
+	if report.HasErrors() {
+		return report.Finish(now), nil
+	}
+
+	periodValue := 0
+	if input.PeriodType == PeriodTypeMonthly {
+		periodValue = *input.PeriodMonth
+	} else {
+		periodValue = *input.PeriodQuarter
+	}
+	exists, err := s.repo.CheckDuplicatePeriod(ctx, tenantID, input.AccountID, input.PeriodYear, input.PeriodType, periodValue, nil)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		report.Error("duplicate_period", "", ErrDuplicatePeriod.Error())
+	}
+
+	submission := &Submission{
+		PeriodYear:    input.PeriodYear,
+		PeriodMonth:   input.PeriodMonth,
+		PeriodQuarter: input.PeriodQuarter,
+		PeriodType:    input.PeriodType,
+	}
+	uva := s.dataToFonwsUVA(submission, &input.Data)
+	if err := fonws.ValidateUVA(uva); err != nil {
+		report.Error("invalid", "", err.Error())
+		return report.Finish(now), nil
+	}
+	xmlContent, err := fonws.GenerateUVAXML(uva)
+	if err != nil {
+		report.Error("xml", "", err.Error())
+		return report.Finish(now), nil
+	}
+	periodStart, _, _ := PeriodBounds(input.PeriodYear, input.PeriodType, input.PeriodMonth, input.PeriodQuarter)
+	if err := report.Schema(xmlschema.FormatU30, periodStart, xmlContent); err != nil {
+		return nil, err
+	}
+	return report.Finish(now), nil
+}
@@ -2,10 +2,15 @@ package uva
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/endpoint"
+	"austrian-business-infrastructure/internal/entitychange"
+	"austrian-business-infrastructure/internal/permission"
 	"github.com/google/uuid"
 )
 
@@ -19,23 +24,38 @@ func NewHandler(service *Service) *Handler {
 	return &Handler{service: service}
 }
 
-// RegisterRoutes registers UVA routes
-func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
-	// Admin-only: create, update, delete, submit (financial submissions)
-	router.Handle("POST /api/v1/uva", requireAuth(requireAdmin(http.HandlerFunc(h.Create))))
-	router.Handle("PUT /api/v1/uva/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Update))))
-	router.Handle("DELETE /api/v1/uva/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Delete))))
-	router.Handle("POST /api/v1/uva/{id}/submit", requireAuth(requireAdmin(http.HandlerFunc(h.Submit))))
-	router.Handle("POST /api/v1/uva/batches", requireAuth(requireAdmin(http.HandlerFunc(h.CreateBatch))))
-
-	// Member access: read-only and validation
+// RegisterRoutes registers UVA routes. Submitting to FinanzOnline
+// requires uva:submit, every other change uva:write.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth func(http.Handler) http.Handler, requirePermission func(permission.Permission) func(http.Handler) http.Handler) {
+	requireRead := requirePermission(permission.UVARead)
+	requireWrite := requirePermission(permission.UVAWrite)
+
+	router.Handle("POST /api/v1/uva", requireAuth(requireWrite(http.HandlerFunc(h.Create))))
+	router.Handle("PUT /api/v1/uva/{id}", requireAuth(requireWrite(http.HandlerFunc(h.Update))))
+	router.Handle("DELETE /api/v1/uva/{id}", requireAuth(requireWrite(http.HandlerFunc(h.Delete))))
+	router.Handle("POST /api/v1/uva/{id}/submit", requireAuth(requirePermission(permission.UVASubmit)(http.HandlerFunc(h.Submit))))
+	router.Handle("POST /api/v1/uva/{id}/corrections", requireAuth(requireWrite(http.HandlerFunc(h.CreateCorrection))))
+	router.Handle("POST /api/v1/uva/batches", requireAuth(requireWrite(http.HandlerFunc(h.CreateBatch))))
+	router.Handle("POST /api/v1/uva/vat-schemes", requireAuth(requireWrite(http.HandlerFunc(h.SetScheme))))
+	router.Handle("DELETE /api/v1/uva/vat-schemes/{schemeID}", requireAuth(requireWrite(http.HandlerFunc(h.DeleteScheme))))
+	router.Handle("POST /api/v1/uva/compute", requireAuth(requireWrite(http.HandlerFunc(h.Compute))))
+	router.Handle("POST /api/v1/uva/input-invoices", requireAuth(requireWrite(http.HandlerFunc(h.CreateInputInvoice))))
+	router.Handle("PUT /api/v1/uva/input-invoices/{invoiceID}/paid", requireAuth(requireWrite(http.HandlerFunc(h.SetInputInvoicePaid))))
+	router.Handle("DELETE /api/v1/uva/input-invoices/{invoiceID}", requireAuth(requireWrite(http.HandlerFunc(h.DeleteInputInvoice))))
+
+	// Read-only and validation
 	// Batches use separate path to avoid conflict with {id} wildcard
-	router.Handle("GET /api/v1/uva", requireAuth(http.HandlerFunc(h.List)))
-	router.Handle("GET /api/v1/uva/batches", requireAuth(http.HandlerFunc(h.ListBatches)))
-	router.Handle("GET /api/v1/uva-batches/{batchID}", requireAuth(http.HandlerFunc(h.GetBatch)))
-	router.Handle("GET /api/v1/uva/{id}", requireAuth(http.HandlerFunc(h.Get)))
-	router.Handle("POST /api/v1/uva/{id}/validate", requireAuth(http.HandlerFunc(h.Validate)))
-	router.Handle("GET /api/v1/uva/{id}/xml", requireAuth(http.HandlerFunc(h.GetXML)))
+	router.Handle("GET /api/v1/uva", requireAuth(requireRead(http.HandlerFunc(h.List))))
+	router.Handle("GET /api/v1/uva/batches", requireAuth(requireRead(http.HandlerFunc(h.ListBatches))))
+	router.Handle("GET /api/v1/uva/vat-schemes", requireAuth(requireRead(http.HandlerFunc(h.ListSchemes))))
+	router.Handle("GET /api/v1/uva/input-invoices", requireAuth(requireRead(http.HandlerFunc(h.ListInputInvoices))))
+	router.Handle("POST /api/v1/uva/derive", requireAuth(requireRead(http.HandlerFunc(h.Derive))))
+	router.Handle("POST /api/v1/uva/dry-run", requireAuth(requireRead(http.HandlerFunc(h.DryRun))))
+	router.Handle("GET /api/v1/uva-batches/{batchID}", requireAuth(requireRead(http.HandlerFunc(h.GetBatch))))
+	router.Handle("GET /api/v1/uva/{id}", requireAuth(requireRead(http.HandlerFunc(h.Get))))
+	router.Handle("POST /api/v1/uva/{id}/validate", requireAuth(requireRead(http.HandlerFunc(h.Validate))))
+	router.Handle("GET /api/v1/uva/{id}/xml", requireAuth(requireRead(http.HandlerFunc(h.GetXML))))
+	router.Handle("GET /api/v1/uva/{id}/corrections", requireAuth(requireRead(http.HandlerFunc(h.ListCorrections))))
 }
 
 // CreateRequest represents the create UVA request
@@ -230,6 +250,67 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// CreateCorrection handles POST /api/v1/uva/{id}/corrections
+func (h *Handler) CreateCorrection(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid submission ID")
+		return
+	}
+
+	var req UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	submission, err := h.service.CreateCorrection(r.Context(), id, tenantID, &UpdateSubmissionInput{Data: req.Data})
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, h.toResponse(submission))
+}
+
+// ListCorrections handles GET /api/v1/uva/{id}/corrections: the correction
+// chain of a filing, from the original to the latest Berichtigung
+func (h *Handler) ListCorrections(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid submission ID")
+		return
+	}
+
+	chain, err := h.service.Chain(r.Context(), id, tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	resp := &ChainResponse{Filings: make([]*ChainFilingResponse, 0, len(chain))}
+	for _, entry := range chain {
+		resp.Filings = append(resp.Filings, &ChainFilingResponse{
+			SubmissionResponse: h.toResponse(entry.Submission),
+			Delta:              entry.Delta,
+		})
+	}
+
+	api.JSONResponse(w, http.StatusOK, resp)
+}
+
 // Validate handles POST /api/v1/uva/{id}/validate
 func (h *Handler) Validate(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := h.getTenantID(r)
@@ -253,6 +334,45 @@ func (h *Handler) Validate(w http.ResponseWriter, r *http.Request) {
 	api.JSONResponse(w, http.StatusOK, h.toResponse(submission))
 }
 
+// DryRun handles POST /api/v1/uva/dry-run. The body is that of Create;
+// nothing is stored.
+func (h *Handler) DryRun(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	var accountID uuid.UUID
+	if req.AccountID != "" {
+		if accountID, err = uuid.Parse(req.AccountID); err != nil {
+			api.BadRequest(w, "invalid account_id")
+			return
+		}
+	}
+
+	report, err := h.service.DryRun(r.Context(), tenantID, &CreateSubmissionInput{
+		AccountID:     accountID,
+		PeriodYear:    req.PeriodYear,
+		PeriodMonth:   req.PeriodMonth,
+		PeriodQuarter: req.PeriodQuarter,
+		PeriodType:    req.PeriodType,
+		Data:          req.Data,
+	})
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, report)
+}
+
 // SubmitRequest represents the submit UVA request
 type SubmitRequest struct {
 	DryRun bool `json:"dry_run"`
@@ -442,6 +562,259 @@ func (h *Handler) GetBatch(w http.ResponseWriter, r *http.Request) {
 	api.JSONResponse(w, http.StatusOK, h.toBatchResponse(batch))
 }
 
+// SetScheme handles POST /api/v1/uva/vat-schemes
+func (h *Handler) SetScheme(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		api.Unauthorized(w, "user not found in context")
+		return
+	}
+
+	var input SetSchemeInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	scheme, err := h.service.SetScheme(r.Context(), tenantID, userID, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, scheme)
+}
+
+// ListSchemes handles GET /api/v1/uva/vat-schemes
+func (h *Handler) ListSchemes(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	var accountID *uuid.UUID
+	if accountIDStr := r.URL.Query().Get("account_id"); accountIDStr != "" {
+		id, err := uuid.Parse(accountIDStr)
+		if err != nil {
+			api.BadRequest(w, "invalid account_id")
+			return
+		}
+		accountID = &id
+	}
+
+	schemes, err := h.service.ListSchemes(r.Context(), tenantID, accountID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if schemes == nil {
+		schemes = []*VATScheme{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items": schemes,
+	})
+}
+
+// DeleteScheme handles DELETE /api/v1/uva/vat-schemes/{schemeID}
+func (h *Handler) DeleteScheme(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("schemeID"))
+	if err != nil {
+		api.BadRequest(w, "invalid scheme ID")
+		return
+	}
+
+	if err := h.service.DeleteScheme(r.Context(), id, tenantID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Derive handles POST /api/v1/uva/derive
+func (h *Handler) Derive(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	var input DeriveInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	derivation, err := h.service.Derive(r.Context(), tenantID, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, derivation)
+}
+
+// Compute handles POST /api/v1/uva/compute?period=2025-03&account_id=...
+func (h *Handler) Compute(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	accountID, err := uuid.Parse(r.URL.Query().Get("account_id"))
+	if err != nil {
+		api.BadRequest(w, "invalid account_id")
+		return
+	}
+
+	submission, derivation, err := h.service.Compute(r.Context(), tenantID, accountID, r.URL.Query().Get("period"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, map[string]interface{}{
+		"submission": h.toResponse(submission),
+		"derivation": derivation,
+	})
+}
+
+// CreateInputInvoice handles POST /api/v1/uva/input-invoices
+func (h *Handler) CreateInputInvoice(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+	userID, err := h.getUserID(r)
+	if err != nil {
+		api.Unauthorized(w, "user not found in context")
+		return
+	}
+
+	var input CreateInputInvoiceInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	inv, err := h.service.CreateInputInvoice(r.Context(), tenantID, userID, &input)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, inv)
+}
+
+// ListInputInvoices handles GET /api/v1/uva/input-invoices?from=...&to=...
+func (h *Handler) ListInputInvoices(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", r.URL.Query().Get("from"))
+	if err != nil {
+		api.BadRequest(w, "invalid from, expected YYYY-MM-DD")
+		return
+	}
+	to, err := time.Parse("2006-01-02", r.URL.Query().Get("to"))
+	if err != nil {
+		api.BadRequest(w, "invalid to, expected YYYY-MM-DD")
+		return
+	}
+
+	invoices, err := h.service.ListInputInvoices(r.Context(), tenantID, from, to)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if invoices == nil {
+		invoices = []*InputInvoice{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items": invoices,
+	})
+}
+
+// SetInputInvoicePaid handles PUT /api/v1/uva/input-invoices/{invoiceID}/paid
+func (h *Handler) SetInputInvoicePaid(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("invoiceID"))
+	if err != nil {
+		api.BadRequest(w, "invalid input invoice ID")
+		return
+	}
+
+	var req struct {
+		PaidDate *string `json:"paid_date"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+	var paidDate *time.Time
+	if req.PaidDate != nil && *req.PaidDate != "" {
+		date, err := time.Parse("2006-01-02", *req.PaidDate)
+		if err != nil {
+			api.BadRequest(w, "invalid paid_date, expected YYYY-MM-DD")
+			return
+		}
+		paidDate = &date
+	}
+
+	if err := h.service.SetInputInvoicePaid(r.Context(), id, tenantID, paidDate); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteInputInvoice handles DELETE /api/v1/uva/input-invoices/{invoiceID}
+func (h *Handler) DeleteInputInvoice(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("invoiceID"))
+	if err != nil {
+		api.BadRequest(w, "invalid input invoice ID")
+		return
+	}
+
+	if err := h.service.DeleteInputInvoice(r.Context(), id, tenantID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // Helper methods
 
 func (h *Handler) getTenantID(r *http.Request) (uuid.UUID, error) {
@@ -461,6 +834,15 @@ func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
 }
 
 func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	if m, ok := endpoint.AsMaintenance(err); ok {
+		api.ServiceUnavailable(w, m.RetryAfter(), "FinanzOnline is in a maintenance window, retry later")
+		return
+	}
+	if errors.Is(err, entitychange.ErrWrongEntity) {
+		api.Conflict(w, err.Error())
+		return
+	}
+
 	switch err {
 	case ErrSubmissionNotFound:
 		api.NotFound(w, "submission not found")
@@ -482,6 +864,34 @@ func (h *Handler) handleError(w http.ResponseWriter, err error) {
 		api.NotFound(w, "account not found")
 	case ErrValidationFailed:
 		api.BadRequest(w, "validation failed")
+	case ErrSchemeNotFound:
+		api.NotFound(w, "vat scheme not found")
+	case ErrDuplicateScheme:
+		api.Conflict(w, "vat scheme already configured for this date")
+	case ErrInvalidScheme:
+		api.BadRequest(w, "invalid vat scheme, must be 'soll' or 'ist'")
+	case ErrInvalidEffective:
+		api.BadRequest(w, "effective_from must be the first day of a month")
+	case ErrPeriodAlreadyFiled:
+		api.Conflict(w, "a UVA was already submitted for a period affected by this change")
+	case ErrNotSubmittable:
+		api.Conflict(w, "submission must be in draft or validated status")
+	case ErrNotCorrectable:
+		api.Conflict(w, "only submitted or accepted filings can be corrected")
+	case ErrAlreadyCorrected:
+		api.Conflict(w, "filing was already corrected, correct the latest filing of the chain")
+	case ErrNoChanges:
+		api.BadRequest(w, "correction does not change any Kennzahl")
+	case ErrInvalidPeriod:
+		api.BadRequest(w, "period must be YYYY-MM or YYYY-Qn")
+	case ErrSeveralCompanies:
+		api.Conflict(w, "invoices are not assigned to companies, so they cannot be derived for one of several FinanzOnline accounts; enter the Kennzahlen instead")
+	case ErrInputInvoiceNotFound:
+		api.NotFound(w, "input invoice not found")
+	case ErrInvalidInputInvoice:
+		api.BadRequest(w, "supplier_name, invoice_number, invoice_date (YYYY-MM-DD) and at least one line are required")
+	case ErrInvalidInputKind:
+		api.BadRequest(w, "invalid kind, must be 'domestic', 'import', 'import_deferred' or 'ig_acquisition'")
 	case ErrSubmissionFailed:
 		api.JSONError(w, http.StatusBadGateway, "submission to FinanzOnline failed", "FO_ERROR")
 	default:
@@ -501,8 +911,9 @@ func (h *Handler) toResponse(s *Submission) *SubmissionResponse {
 		ValidationErrors: s.ValidationErrors,
 		Status:           s.Status,
 		FOReference:      s.FOReference,
-		CreatedAt:        s.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:        s.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		CorrectsID:       s.CorrectsID,
+		CreatedAt:        s.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:        s.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 
 	// Parse data
@@ -512,7 +923,7 @@ func (h *Handler) toResponse(s *Submission) *SubmissionResponse {
 	}
 
 	if s.SubmittedAt != nil {
-		t := s.SubmittedAt.Format("2006-01-02T15:04:05Z")
+		t := s.SubmittedAt.UTC().Format(time.RFC3339)
 		resp.SubmittedAt = &t
 	}
 
@@ -531,16 +942,16 @@ func (h *Handler) toBatchResponse(b *Batch) *BatchResponse {
 		SuccessCount:  b.SuccessCount,
 		FailedCount:   b.FailedCount,
 		Status:        b.Status,
-		CreatedAt:     b.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		CreatedAt:     b.CreatedAt.UTC().Format(time.RFC3339),
 	}
 
 	if b.StartedAt != nil {
-		t := b.StartedAt.Format("2006-01-02T15:04:05Z")
+		t := b.StartedAt.UTC().Format(time.RFC3339)
 		resp.StartedAt = &t
 	}
 
 	if b.CompletedAt != nil {
-		t := b.CompletedAt.Format("2006-01-02T15:04:05Z")
+		t := b.CompletedAt.UTC().Format(time.RFC3339)
 		resp.CompletedAt = &t
 	}
 
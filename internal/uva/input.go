@@ -0,0 +1,200 @@
+package uva
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Input invoice kinds, deciding which Kennzahlen the input tax goes to
+const (
+	InputDomestic       = "domestic"        // Domestic supplier invoice, KZ060
+	InputImport         = "import"          // Einfuhrumsatzsteuer paid to customs, KZ065
+	InputImportDeferred = "import_deferred" // EUSt booked on the tax account (§ 26 Abs. 3 Z 2 UStG), KZ022 and KZ065
+	InputIGAcquisition  = "ig_acquisition"  // Innergemeinschaftlicher Erwerb, KZ029 and KZ066
+)
+
+var (
+	ErrInputInvoiceNotFound = errors.New("input invoice not found")
+	ErrInvalidInputInvoice  = errors.New("supplier_name, invoice_number, invoice_date and at least one line are required")
+	ErrInvalidInputKind     = errors.New("invalid input invoice kind")
+)
+
+// IsValidInputKind checks if an input invoice kind is known
+func IsValidInputKind(kind string) bool {
+	switch kind {
+	case InputDomestic, InputImport, InputImportDeferred, InputIGAcquisition:
+		return true
+	}
+	return false
+}
+
+// InputInvoice is an incoming invoice (Eingangsrechnung) or customs notice
+// recorded for the input tax of the UVA
+type InputInvoice struct {
+	ID            uuid.UUID          `json:"id"`
+	TenantID      uuid.UUID          `json:"tenant_id"`
+	SupplierName  string             `json:"supplier_name"`
+	SupplierUID   *string            `json:"supplier_uid,omitempty"`
+	InvoiceNumber string             `json:"invoice_number"`
+	InvoiceDate   time.Time          `json:"invoice_date"`
+	Kind          string             `json:"kind"`
+	CreditNote    bool               `json:"credit_note"`
+	PaidDate      *time.Time         `json:"paid_date,omitempty"`
+	Lines         []InputInvoiceLine `json:"lines"`
+	CreatedBy     *uuid.UUID         `json:"created_by,omitempty"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+// InputInvoiceLine is the net amount and input tax of an input invoice per rate
+type InputInvoiceLine struct {
+	TaxPercent float64 `json:"tax_percent"`
+	Net        int64   `json:"net"` // In cents
+	Tax        int64   `json:"tax"` // In cents
+}
+
+// CreateInputInvoiceInput represents input for recording an input invoice
+type CreateInputInvoiceInput struct {
+	SupplierName  string             `json:"supplier_name"`
+	SupplierUID   *string            `json:"supplier_uid,omitempty"`
+	InvoiceNumber string             `json:"invoice_number"`
+	InvoiceDate   string             `json:"invoice_date"` // YYYY-MM-DD
+	Kind          string             `json:"kind"`
+	CreditNote    bool               `json:"credit_note"`
+	PaidDate      *string            `json:"paid_date,omitempty"` // YYYY-MM-DD
+	Lines         []InputInvoiceLine `json:"lines"`
+}
+
+// DeriveInputTax books the input tax of the period [from, to] into data.
+//
+// Domestic input tax follows the scheme in effect on the invoice date: under
+// Sollbesteuerung it is deducted in the period of the invoice, under
+// Istbesteuerung in the period it was paid (§ 12 Abs. 1 Z 1 lit. a UStG).
+// Import VAT and intra-community acquisitions do not depend on payment and
+// always count in the period of the invoice or customs notice. Lines without
+// tax get it from the rate, as supplier invoices for acquisitions carry none.
+func DeriveInputTax(data *UVAData, from, to time.Time, invoices []*InputInvoice, history SchemeHistory) []Contribution {
+	var contributions []Contribution
+
+	for _, inv := range invoices {
+		scheme := history.At(inv.InvoiceDate)
+		basis, date := "issue_date", inv.InvoiceDate
+		if inv.Kind == InputDomestic && scheme == SchemeIst {
+			if inv.PaidDate == nil {
+				continue
+			}
+			basis, date = "payment", *inv.PaidDate
+		}
+		if !inPeriod(date, from, to) {
+			continue
+		}
+
+		sign := int64(1)
+		if inv.CreditNote {
+			sign = -1
+		}
+		var net, tax int64
+		for _, line := range inv.Lines {
+			lineTax := line.Tax
+			if lineTax == 0 {
+				lineTax = TaxOf(line.Net, line.TaxPercent)
+			}
+			addInputLine(data, inv.Kind, sign*line.Net, sign*lineTax)
+			net += sign * line.Net
+			tax += sign * lineTax
+		}
+
+		contributions = append(contributions, Contribution{
+			InvoiceID:     inv.ID,
+			InvoiceNumber: inv.InvoiceNumber,
+			Scheme:        scheme,
+			Basis:         basis,
+			Date:          date.Format("2006-01-02"),
+			Net:           net,
+			Kind:          inv.Kind,
+			InputTax:      tax,
+		})
+	}
+
+	return contributions
+}
+
+// addInputLine books the net amount and tax of an input invoice line into the matching Kennzahlen
+func addInputLine(data *UVAData, kind string, net, tax int64) {
+	switch kind {
+	case InputImport:
+		data.KZ065 += tax
+	case InputImportDeferred:
+		data.KZ022 += tax
+		data.KZ065 += tax
+	case InputIGAcquisition:
+		data.KZ029 += net
+		data.KZ066 += tax
+	default:
+		data.KZ060 += tax
+	}
+}
+
+// TaxOf returns the tax on a net amount in cents, rounded half away from zero
+func TaxOf(net int64, percent float64) int64 {
+	return int64(math.Round(float64(net) * percent / 100))
+}
+
+// CreateInputInvoice records an incoming invoice for the input tax derivation
+func (s *Service) CreateInputInvoice(ctx context.Context, tenantID, userID uuid.UUID, input *CreateInputInvoiceInput) (*InputInvoice, error) {
+	if strings.TrimSpace(input.SupplierName) == "" || strings.TrimSpace(input.InvoiceNumber) == "" || len(input.Lines) == 0 {
+		return nil, ErrInvalidInputInvoice
+	}
+	if !IsValidInputKind(input.Kind) {
+		return nil, ErrInvalidInputKind
+	}
+	invoiceDate, err := time.Parse("2006-01-02", input.InvoiceDate)
+	if err != nil {
+		return nil, ErrInvalidInputInvoice
+	}
+
+	inv := &InputInvoice{
+		TenantID:      tenantID,
+		SupplierName:  strings.TrimSpace(input.SupplierName),
+		SupplierUID:   input.SupplierUID,
+		InvoiceNumber: strings.TrimSpace(input.InvoiceNumber),
+		InvoiceDate:   invoiceDate,
+		Kind:          input.Kind,
+		CreditNote:    input.CreditNote,
+		Lines:         input.Lines,
+		CreatedBy:     &userID,
+	}
+	if input.PaidDate != nil && *input.PaidDate != "" {
+		paid, err := time.Parse("2006-01-02", *input.PaidDate)
+		if err != nil {
+			return nil, ErrInvalidInputInvoice
+		}
+		inv.PaidDate = &paid
+	}
+	for i := range inv.Lines {
+		if inv.Lines[i].Tax == 0 {
+			inv.Lines[i].Tax = TaxOf(inv.Lines[i].Net, inv.Lines[i].TaxPercent)
+		}
+	}
+
+	return s.repo.CreateInputInvoice(ctx, inv)
+}
+
+// ListInputInvoices lists the input invoices dated or paid within [from, to]
+func (s *Service) ListInputInvoices(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*InputInvoice, error) {
+	return s.repo.ListInputInvoices(ctx, tenantID, from, to)
+}
+
+// SetInputInvoicePaid records the payment date of an input invoice, nil clears it
+func (s *Service) SetInputInvoicePaid(ctx context.Context, id, tenantID uuid.UUID, paidDate *time.Time) error {
+	return s.repo.SetInputInvoicePaid(ctx, id, tenantID, paidDate)
+}
+
+// DeleteInputInvoice removes an input invoice
+func (s *Service) DeleteInputInvoice(ctx context.Context, id, tenantID uuid.UUID) error {
+	return s.repo.DeleteInputInvoice(ctx, id, tenantID)
+}
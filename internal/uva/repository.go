@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +18,8 @@ var (
 	ErrSubmissionNotFound = errors.New("submission not found")
 	ErrBatchNotFound      = errors.New("batch not found")
 	ErrDuplicatePeriod    = errors.New("submission for this period already exists")
+	ErrSchemeNotFound     = errors.New("vat scheme not found")
+	ErrDuplicateScheme    = errors.New("vat scheme already configured for this date")
 )
 
 // Repository handles UVA database operations
@@ -40,13 +43,13 @@ func (r *Repository) Create(ctx context.Context, s *Submission) (*Submission, er
 	query := `
 		INSERT INTO uva_submissions (
 			id, tenant_id, account_id, period_year, period_month, period_quarter,
-			period_type, data, validation_status, status, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			period_type, data, validation_status, status, corrects_id, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id, created_at, updated_at`
 
 	err := r.db.QueryRow(ctx, query,
 		s.ID, s.TenantID, s.AccountID, s.PeriodYear, s.PeriodMonth, s.PeriodQuarter,
-		s.PeriodType, s.Data, s.ValidationStatus, s.Status, s.CreatedAt, s.UpdatedAt,
+		s.PeriodType, s.Data, s.ValidationStatus, s.Status, s.CorrectsID, s.CreatedAt, s.UpdatedAt,
 	).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
 
 	if err != nil {
@@ -62,13 +65,16 @@ func (r *Repository) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*Subm
 		SELECT id, tenant_id, account_id, period_year, period_month, period_quarter,
 			period_type, data, validation_status, validation_errors, status,
 			fo_reference, xml_content, submitted_at, submitted_by,
-			response_code, response_message, created_at, updated_at
-		FROM uva_submissions
+			response_code, response_message, corrects_id,
+			(SELECT o.fo_reference FROM uva_submissions o WHERE o.id = s.corrects_id),
+			created_at, updated_at
+		FROM uva_submissions s
 		WHERE id = $1 AND tenant_id = $2`
 
 	var s Submission
 	var periodMonth, periodQuarter sql.NullInt32
-	var foRef, respMsg sql.NullString
+	var foRef, respMsg, correctsRef sql.NullString
+	var correctsID uuid.NullUUID
 	var respCode sql.NullInt32
 	var submittedAt sql.NullTime
 	var submittedBy uuid.NullUUID
@@ -78,7 +84,8 @@ func (r *Repository) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*Subm
 		&s.ID, &s.TenantID, &s.AccountID, &s.PeriodYear, &periodMonth, &periodQuarter,
 		&s.PeriodType, &s.Data, &s.ValidationStatus, &validationErrors, &s.Status,
 		&foRef, &xmlContent, &submittedAt, &submittedBy,
-		&respCode, &respMsg, &s.CreatedAt, &s.UpdatedAt,
+		&respCode, &respMsg, &correctsID, &correctsRef,
+		&s.CreatedAt, &s.UpdatedAt,
 	)
 
 	if err != nil {
@@ -112,6 +119,12 @@ func (r *Repository) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*Subm
 	if submittedBy.Valid {
 		s.SubmittedBy = &submittedBy.UUID
 	}
+	if correctsID.Valid {
+		s.CorrectsID = &correctsID.UUID
+	}
+	if correctsRef.Valid {
+		s.CorrectsRef = &correctsRef.String
+	}
 	if len(validationErrors) > 0 {
 		s.ValidationErrors = validationErrors
 	}
@@ -164,11 +177,7 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Submission
 	}
 
 	// Get paginated results
-	selectQuery := `
-		SELECT id, tenant_id, account_id, period_year, period_month, period_quarter,
-			period_type, data, validation_status, validation_errors, status,
-			fo_reference, submitted_at, created_at, updated_at
-		` + baseQuery + `
+	selectQuery := `SELECT ` + summaryColumns + baseQuery + `
 		ORDER BY created_at DESC
 		LIMIT $` + fmt.Sprintf("%d", argIdx) + ` OFFSET $` + fmt.Sprintf("%d", argIdx+1)
 
@@ -182,43 +191,63 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Submission
 
 	var submissions []*Submission
 	for rows.Next() {
-		var s Submission
-		var periodMonth, periodQuarter sql.NullInt32
-		var foRef sql.NullString
-		var submittedAt sql.NullTime
-		var validationErrors []byte
-
-		err := rows.Scan(
-			&s.ID, &s.TenantID, &s.AccountID, &s.PeriodYear, &periodMonth, &periodQuarter,
-			&s.PeriodType, &s.Data, &s.ValidationStatus, &validationErrors, &s.Status,
-			&foRef, &submittedAt, &s.CreatedAt, &s.UpdatedAt,
-		)
+		s, err := scanSummary(rows)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan submission: %w", err)
+			return nil, 0, err
 		}
+		submissions = append(submissions, s)
+	}
 
-		if periodMonth.Valid {
-			m := int(periodMonth.Int32)
-			s.PeriodMonth = &m
-		}
-		if periodQuarter.Valid {
-			q := int(periodQuarter.Int32)
-			s.PeriodQuarter = &q
-		}
-		if foRef.Valid {
-			s.FOReference = &foRef.String
-		}
-		if submittedAt.Valid {
-			s.SubmittedAt = &submittedAt.Time
-		}
-		if len(validationErrors) > 0 {
-			s.ValidationErrors = validationErrors
-		}
+	return submissions, total, nil
+}
+
+// summaryColumns are the columns of a submission without its XML and
+// FinanzOnline response, as read by scanSummary
+const summaryColumns = `
+		id, tenant_id, account_id, period_year, period_month, period_quarter,
+		period_type, data, validation_status, validation_errors, status,
+		fo_reference, submitted_at, corrects_id, created_at, updated_at
+	`
 
-		submissions = append(submissions, &s)
+func scanSummary(row pgx.Row) (*Submission, error) {
+	var s Submission
+	var periodMonth, periodQuarter sql.NullInt32
+	var foRef sql.NullString
+	var submittedAt sql.NullTime
+	var correctsID uuid.NullUUID
+	var validationErrors []byte
+
+	err := row.Scan(
+		&s.ID, &s.TenantID, &s.AccountID, &s.PeriodYear, &periodMonth, &periodQuarter,
+		&s.PeriodType, &s.Data, &s.ValidationStatus, &validationErrors, &s.Status,
+		&foRef, &submittedAt, &correctsID, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan submission: %w", err)
 	}
 
-	return submissions, total, nil
+	if periodMonth.Valid {
+		m := int(periodMonth.Int32)
+		s.PeriodMonth = &m
+	}
+	if periodQuarter.Valid {
+		q := int(periodQuarter.Int32)
+		s.PeriodQuarter = &q
+	}
+	if foRef.Valid {
+		s.FOReference = &foRef.String
+	}
+	if submittedAt.Valid {
+		s.SubmittedAt = &submittedAt.Time
+	}
+	if correctsID.Valid {
+		s.CorrectsID = &correctsID.UUID
+	}
+	if len(validationErrors) > 0 {
+		s.ValidationErrors = validationErrors
+	}
+
+	return &s, nil
 }
 
 // Update updates a submission
@@ -249,6 +278,38 @@ func (r *Repository) Update(ctx context.Context, s *Submission) error {
 	return nil
 }
 
+// ClaimSubmission moves a draft or validated submission to submitted before
+// it is transmitted. It fails with ErrNotSubmittable if the submission has
+// been claimed or submitted in the meantime.
+func (r *Repository) ClaimSubmission(ctx context.Context, id, tenantID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `
+		UPDATE uva_submissions SET status = 'submitted', updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status IN ('draft', 'validated')`,
+		id, tenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to claim submission: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotSubmittable
+	}
+	return nil
+}
+
+// ReleaseSubmission returns a claimed submission that was not transmitted to
+// validated
+func (r *Repository) ReleaseSubmission(ctx context.Context, id, tenantID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE uva_submissions SET status = 'validated', updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status = 'submitted'`,
+		id, tenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to release submission: %w", err)
+	}
+	return nil
+}
+
 // UpdateSubmissionResult updates the submission with FO result
 func (r *Repository) UpdateSubmissionResult(ctx context.Context, id, tenantID uuid.UUID, foRef string, respCode int, respMsg string, status string) error {
 	now := time.Now()
@@ -315,7 +376,8 @@ func (r *Repository) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
 	return nil
 }
 
-// CheckDuplicatePeriod checks if a submission for the same period exists
+// CheckDuplicatePeriod checks if a submission for the same period exists;
+// Berichtigungen do not count, they share the period of the original
 func (r *Repository) CheckDuplicatePeriod(ctx context.Context, tenantID, accountID uuid.UUID, year int, periodType string, periodValue int, excludeID *uuid.UUID) (bool, error) {
 	query := `
 		SELECT EXISTS (
@@ -325,7 +387,8 @@ func (r *Repository) CheckDuplicatePeriod(ctx context.Context, tenantID, account
 				WHEN period_type = 'monthly' THEN period_month = $5
 				ELSE period_quarter = $5
 			END
-			AND status NOT IN ('rejected', 'error')`
+			AND status NOT IN ('rejected', 'error')
+			AND corrects_id IS NULL`
 
 	args := []interface{}{tenantID, accountID, year, periodType, periodValue}
 
@@ -345,6 +408,61 @@ func (r *Repository) CheckDuplicatePeriod(ctx context.Context, tenantID, account
 	return exists, nil
 }
 
+// HasCorrection checks if a filing has a Berichtigung that was not rejected
+func (r *Repository) HasCorrection(ctx context.Context, id, tenantID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM uva_submissions
+			WHERE corrects_id = $1 AND tenant_id = $2 AND status NOT IN ('rejected', 'error')
+		)`, id, tenantID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check correction: %w", err)
+	}
+	return exists, nil
+}
+
+// ListChain returns the correction chain of a filing: the original and all
+// Berichtigungen of it, in the order they were created
+func (r *Repository) ListChain(ctx context.Context, id, tenantID uuid.UUID) ([]*Submission, error) {
+	query := `
+		WITH RECURSIVE up AS (
+			SELECT id, corrects_id FROM uva_submissions WHERE id = $1 AND tenant_id = $2
+			UNION ALL
+			SELECT s.id, s.corrects_id FROM uva_submissions s JOIN up ON s.id = up.corrects_id
+		), chain AS (
+			SELECT id FROM up WHERE corrects_id IS NULL
+			UNION ALL
+			SELECT s.id FROM uva_submissions s JOIN chain ON s.corrects_id = chain.id
+		)
+		SELECT ` + summaryColumns + `
+		FROM uva_submissions
+		WHERE tenant_id = $2 AND id IN (SELECT id FROM chain)
+		ORDER BY created_at, id`
+
+	rows, err := r.db.Query(ctx, query, id, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list correction chain: %w", err)
+	}
+	defer rows.Close()
+
+	var submissions []*Submission
+	for rows.Next() {
+		s, err := scanSummary(rows)
+		if err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(submissions) == 0 {
+		return nil, ErrSubmissionNotFound
+	}
+	return submissions, nil
+}
+
 // Batch operations
 
 // CreateBatch creates a new batch
@@ -583,3 +701,320 @@ func (r *Repository) GetByPeriod(ctx context.Context, tenantID, accountID uuid.U
 
 	return &s, nil
 }
+
+// VAT scheme and invoice source operations
+
+// CreateScheme stores a VAT scheme history entry
+func (r *Repository) CreateScheme(ctx context.Context, v *VATScheme) (*VATScheme, error) {
+	v.ID = uuid.New()
+	v.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO uva_vat_schemes (id, tenant_id, account_id, scheme, effective_from, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := r.db.Exec(ctx, query, v.ID, v.TenantID, v.AccountID, v.Scheme, v.EffectiveFrom, v.CreatedBy, v.CreatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, ErrDuplicateScheme
+		}
+		return nil, fmt.Errorf("failed to create vat scheme: %w", err)
+	}
+
+	return v, nil
+}
+
+// ListSchemes lists the VAT scheme history of the tenant default (accountID nil) or of a company
+func (r *Repository) ListSchemes(ctx context.Context, tenantID uuid.UUID, accountID *uuid.UUID) ([]*VATScheme, error) {
+	query := `
+		SELECT id, tenant_id, account_id, scheme, effective_from, created_by, created_at
+		FROM uva_vat_schemes
+		WHERE tenant_id = $1 AND account_id IS NOT DISTINCT FROM $2
+		ORDER BY effective_from`
+
+	rows, err := r.db.Query(ctx, query, tenantID, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vat schemes: %w", err)
+	}
+	defer rows.Close()
+
+	var schemes []*VATScheme
+	for rows.Next() {
+		var v VATScheme
+		var accID, createdBy uuid.NullUUID
+		if err := rows.Scan(&v.ID, &v.TenantID, &accID, &v.Scheme, &v.EffectiveFrom, &createdBy, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan vat scheme: %w", err)
+		}
+		if accID.Valid {
+			v.AccountID = &accID.UUID
+		}
+		if createdBy.Valid {
+			v.CreatedBy = &createdBy.UUID
+		}
+		schemes = append(schemes, &v)
+	}
+
+	return schemes, rows.Err()
+}
+
+// DeleteScheme deletes a VAT scheme history entry
+func (r *Repository) DeleteScheme(ctx context.Context, id, tenantID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM uva_vat_schemes WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrSchemeNotFound
+	}
+	return nil
+}
+
+// HasSubmittedSince checks if a UVA for a period ending on or after the date was already submitted
+func (r *Repository) HasSubmittedSince(ctx context.Context, tenantID uuid.UUID, accountID *uuid.UUID, date time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM uva_submissions
+			WHERE tenant_id = $1 AND ($2::uuid IS NULL OR account_id = $2)
+			AND status IN ('submitted', 'accepted')
+			AND make_date(period_year,
+				CASE WHEN period_type = 'monthly' THEN period_month ELSE period_quarter * 3 END, 1)
+				>= date_trunc('month', $3::date)
+		)`
+
+	var exists bool
+	if err := r.db.QueryRow(ctx, query, tenantID, accountID, date).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check submitted periods: %w", err)
+	}
+	return exists, nil
+}
+
+// ListTaxableInvoices loads the outgoing invoices relevant for the period: invoices issued
+// in the period and invoices with payments received in the period, with their tax lines
+// and the receipts booked in the period.
+func (r *Repository) ListTaxableInvoices(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*TaxableInvoice, error) {
+	query := `
+		SELECT i.id, i.invoice_number, i.invoice_type, i.issue_date, i.tax_inclusive_amount
+		FROM invoices i
+		WHERE i.tenant_id = $1
+		AND i.status NOT IN ('draft', 'cancelled')
+		AND i.issue_date <= $3
+		AND (i.issue_date >= $2 OR EXISTS (
+			SELECT 1 FROM transactions t
+			WHERE t.matched_invoice_id = i.id AND t.booking_date BETWEEN $2 AND $3
+		))
+		ORDER BY i.issue_date, i.invoice_number`
+
+	rows, err := r.db.Query(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list taxable invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []*TaxableInvoice
+	byID := make(map[uuid.UUID]*TaxableInvoice)
+	for rows.Next() {
+		var inv TaxableInvoice
+		var invoiceType string
+		if err := rows.Scan(&inv.ID, &inv.InvoiceNumber, &invoiceType, &inv.IssueDate, &inv.Gross); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		inv.CreditNote = invoiceType == "381"
+		invoices = append(invoices, &inv)
+		byID[inv.ID] = &inv
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(invoices) == 0 {
+		return invoices, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(invoices))
+	for _, inv := range invoices {
+		ids = append(ids, inv.ID)
+	}
+
+	lineRows, err := r.db.Query(ctx, `
+		SELECT invoice_id, tax_category, tax_percent, SUM(line_total)
+		FROM invoice_items
+		WHERE invoice_id = ANY($1)
+		GROUP BY invoice_id, tax_category, tax_percent`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tax lines: %w", err)
+	}
+	defer lineRows.Close()
+
+	for lineRows.Next() {
+		var invoiceID uuid.UUID
+		var line TaxLine
+		if err := lineRows.Scan(&invoiceID, &line.TaxCategory, &line.TaxPercent, &line.Net); err != nil {
+			return nil, fmt.Errorf("failed to scan tax line: %w", err)
+		}
+		if inv, ok := byID[invoiceID]; ok {
+			inv.Lines = append(inv.Lines, line)
+		}
+	}
+	if err := lineRows.Err(); err != nil {
+		return nil, err
+	}
+
+	receiptRows, err := r.db.Query(ctx, `
+		SELECT matched_invoice_id, booking_date, ABS(amount)
+		FROM transactions
+		WHERE matched_invoice_id = ANY($1) AND booking_date BETWEEN $2 AND $3
+		ORDER BY booking_date`, ids, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load receipts: %w", err)
+	}
+	defer receiptRows.Close()
+
+	for receiptRows.Next() {
+		var invoiceID uuid.UUID
+		var receipt Receipt
+		if err := receiptRows.Scan(&invoiceID, &receipt.Date, &receipt.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan receipt: %w", err)
+		}
+		if inv, ok := byID[invoiceID]; ok {
+			inv.Receipts = append(inv.Receipts, receipt)
+		}
+	}
+
+	return invoices, receiptRows.Err()
+}
+
+// Input invoice operations
+
+// CreateInputInvoice stores an input invoice with its lines
+func (r *Repository) CreateInputInvoice(ctx context.Context, inv *InputInvoice) (*InputInvoice, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	inv.ID = uuid.New()
+	inv.CreatedAt = time.Now()
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO uva_input_invoices (
+			id, tenant_id, supplier_name, supplier_uid, invoice_number, invoice_date,
+			kind, credit_note, paid_date, created_by, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		inv.ID, inv.TenantID, inv.SupplierName, inv.SupplierUID, inv.InvoiceNumber, inv.InvoiceDate,
+		inv.Kind, inv.CreditNote, inv.PaidDate, inv.CreatedBy, inv.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create input invoice: %w", err)
+	}
+
+	for _, line := range inv.Lines {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO uva_input_invoice_lines (input_invoice_id, tax_percent, net_amount, tax_amount)
+			VALUES ($1, $2, $3, $4)`, inv.ID, line.TaxPercent, line.Net, line.Tax)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create input invoice line: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit input invoice: %w", err)
+	}
+	return inv, nil
+}
+
+// ListInputInvoices loads the input invoices dated or paid within [from, to] with their lines
+func (r *Repository) ListInputInvoices(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*InputInvoice, error) {
+	query := `
+		SELECT id, tenant_id, supplier_name, supplier_uid, invoice_number, invoice_date,
+			kind, credit_note, paid_date, created_by, created_at
+		FROM uva_input_invoices
+		WHERE tenant_id = $1
+		AND (invoice_date BETWEEN $2 AND $3 OR paid_date BETWEEN $2 AND $3)
+		ORDER BY invoice_date, invoice_number`
+
+	rows, err := r.db.Query(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list input invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []*InputInvoice
+	byID := make(map[uuid.UUID]*InputInvoice)
+	for rows.Next() {
+		var inv InputInvoice
+		var createdBy uuid.NullUUID
+		if err := rows.Scan(&inv.ID, &inv.TenantID, &inv.SupplierName, &inv.SupplierUID, &inv.InvoiceNumber, &inv.InvoiceDate,
+			&inv.Kind, &inv.CreditNote, &inv.PaidDate, &createdBy, &inv.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan input invoice: %w", err)
+		}
+		if createdBy.Valid {
+			inv.CreatedBy = &createdBy.UUID
+		}
+		inv.Lines = []InputInvoiceLine{}
+		invoices = append(invoices, &inv)
+		byID[inv.ID] = &inv
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(invoices) == 0 {
+		return invoices, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(invoices))
+	for _, inv := range invoices {
+		ids = append(ids, inv.ID)
+	}
+
+	lineRows, err := r.db.Query(ctx, `
+		SELECT input_invoice_id, tax_percent, net_amount, tax_amount
+		FROM uva_input_invoice_lines
+		WHERE input_invoice_id = ANY($1)
+		ORDER BY tax_percent DESC`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load input invoice lines: %w", err)
+	}
+	defer lineRows.Close()
+
+	for lineRows.Next() {
+		var invoiceID uuid.UUID
+		var line InputInvoiceLine
+		if err := lineRows.Scan(&invoiceID, &line.TaxPercent, &line.Net, &line.Tax); err != nil {
+			return nil, fmt.Errorf("failed to scan input invoice line: %w", err)
+		}
+		if inv, ok := byID[invoiceID]; ok {
+			inv.Lines = append(inv.Lines, line)
+		}
+	}
+
+	return invoices, lineRows.Err()
+}
+
+// SetInputInvoicePaid sets or clears the payment date of an input invoice
+func (r *Repository) SetInputInvoicePaid(ctx context.Context, id, tenantID uuid.UUID, paidDate *time.Time) error {
+	result, err := r.db.Exec(ctx, `UPDATE uva_input_invoices SET paid_date = $3 WHERE id = $1 AND tenant_id = $2`, id, tenantID, paidDate)
+	if err != nil {
+		return fmt.Errorf("failed to update input invoice: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrInputInvoiceNotFound
+	}
+	return nil
+}
+
+// DeleteInputInvoice deletes an input invoice and its lines
+func (r *Repository) DeleteInputInvoice(ctx context.Context, id, tenantID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM uva_input_invoices WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrInputInvoiceNotFound
+	}
+	return nil
+}
+
+// isDuplicateKeyError checks if error is a unique constraint violation
+func isDuplicateKeyError(err error) bool {
+	errStr := err.Error()
+	return strings.Contains(errStr, "23505") || strings.Contains(errStr, "unique constraint")
+}
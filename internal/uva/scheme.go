@@ -0,0 +1,218 @@
+package uva
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VAT scheme constants (Besteuerungsart)
+const (
+	SchemeSoll = "soll" // Sollbesteuerung: tax arises with the invoiced supply
+	SchemeIst  = "ist"  // Istbesteuerung: tax arises when the payment is received
+)
+
+// IsValidScheme checks if a VAT scheme is known
+func IsValidScheme(scheme string) bool {
+	return scheme == SchemeSoll || scheme == SchemeIst
+}
+
+// VATScheme is one entry in the VAT scheme history of a tenant or company.
+// An entry applies from EffectiveFrom until the next entry takes over.
+type VATScheme struct {
+	ID            uuid.UUID  `json:"id"`
+	TenantID      uuid.UUID  `json:"tenant_id"`
+	AccountID     *uuid.UUID `json:"account_id,omitempty"` // nil = tenant default
+	Scheme        string     `json:"scheme"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// SchemeHistory is a list of VAT scheme entries sorted by EffectiveFrom
+type SchemeHistory []*VATScheme
+
+// NewSchemeHistory sorts the entries by effective date
+func NewSchemeHistory(entries []*VATScheme) SchemeHistory {
+	h := make(SchemeHistory, len(entries))
+	copy(h, entries)
+	sort.Slice(h, func(i, j int) bool { return h[i].EffectiveFrom.Before(h[j].EffectiveFrom) })
+	return h
+}
+
+// At returns the scheme in effect on the given date.
+// Sollbesteuerung is the statutory default when nothing is configured.
+func (h SchemeHistory) At(date time.Time) string {
+	scheme := SchemeSoll
+	for _, e := range h {
+		if e.EffectiveFrom.After(date) {
+			break
+		}
+		scheme = e.Scheme
+	}
+	return scheme
+}
+
+// ChangedWithin reports whether the scheme switches inside the period [from, to]
+func (h SchemeHistory) ChangedWithin(from, to time.Time) bool {
+	for _, e := range h {
+		if e.EffectiveFrom.After(from) && !e.EffectiveFrom.After(to) && e.Scheme != h.At(from) {
+			return true
+		}
+	}
+	return false
+}
+
+// TaxableInvoice is an outgoing invoice prepared for Kennzahlen derivation
+type TaxableInvoice struct {
+	ID            uuid.UUID
+	InvoiceNumber string
+	IssueDate     time.Time
+	CreditNote    bool
+	Gross         int64 // In cents
+	Lines         []TaxLine
+	Receipts      []Receipt
+}
+
+// TaxLine is the net amount of an invoice per tax category and rate
+type TaxLine struct {
+	TaxCategory string
+	TaxPercent  float64
+	Net         int64 // In cents
+}
+
+// Receipt is a payment received for an invoice
+type Receipt struct {
+	Date   time.Time
+	Amount int64 // In cents
+}
+
+// Contribution documents how a single outgoing or input invoice entered the derived Kennzahlen
+type Contribution struct {
+	InvoiceID     uuid.UUID `json:"invoice_id"`
+	InvoiceNumber string    `json:"invoice_number"`
+	Scheme        string    `json:"scheme"` // Scheme that applied when the invoice was issued
+	Basis         string    `json:"basis"`  // issue_date or payment
+	Date          string    `json:"date"`
+	Net           int64     `json:"net"`                 // In cents, negative for credit notes
+	Kind          string    `json:"kind,omitempty"`      // Input invoice kind, empty for outgoing invoices
+	InputTax      int64     `json:"input_tax,omitempty"` // In cents, input invoices only
+}
+
+// DeriveKennzahlen derives the output-tax Kennzahlen for the period [from, to] from invoices.
+//
+// The scheme in effect on the invoice date decides how an invoice is taxed:
+// under Sollbesteuerung the invoice is taxed in the period it was issued, under
+// Istbesteuerung each receipt is taxed in the period it was received, pro rata
+// to the invoice's tax lines. This covers the switch-over cases of § 17 Abs. 4 UStG:
+// invoices already taxed under Soll are not taxed again when paid after a switch
+// to Ist, and receipts for Ist invoices that come in after a switch to Soll are
+// still taxed on receipt.
+func DeriveKennzahlen(from, to time.Time, invoices []*TaxableInvoice, history SchemeHistory) (*UVAData, []Contribution) {
+	data := &UVAData{}
+	var contributions []Contribution
+
+	for _, inv := range invoices {
+		scheme := history.At(inv.IssueDate)
+		sign := int64(1)
+		if inv.CreditNote {
+			sign = -1
+		}
+
+		if scheme == SchemeSoll {
+			if !inPeriod(inv.IssueDate, from, to) {
+				continue
+			}
+			var net int64
+			for _, line := range inv.Lines {
+				addTaxLine(data, line.TaxCategory, line.TaxPercent, sign*line.Net)
+				net += sign * line.Net
+			}
+			contributions = append(contributions, Contribution{
+				InvoiceID:     inv.ID,
+				InvoiceNumber: inv.InvoiceNumber,
+				Scheme:        scheme,
+				Basis:         "issue_date",
+				Date:          inv.IssueDate.Format("2006-01-02"),
+				Net:           net,
+			})
+			continue
+		}
+
+		if inv.Gross == 0 {
+			continue
+		}
+		for _, receipt := range inv.Receipts {
+			if !inPeriod(receipt.Date, from, to) {
+				continue
+			}
+			var net int64
+			for _, line := range inv.Lines {
+				share := line.Net * receipt.Amount / inv.Gross
+				addTaxLine(data, line.TaxCategory, line.TaxPercent, sign*share)
+				net += sign * share
+			}
+			contributions = append(contributions, Contribution{
+				InvoiceID:     inv.ID,
+				InvoiceNumber: inv.InvoiceNumber,
+				Scheme:        scheme,
+				Basis:         "payment",
+				Date:          receipt.Date.Format("2006-01-02"),
+				Net:           net,
+			})
+		}
+	}
+
+	return data, contributions
+}
+
+// addTaxLine books a net amount into the matching Kennzahl (EN 16931 tax categories)
+func addTaxLine(data *UVAData, category string, percent float64, net int64) {
+	data.KZ000 += net
+
+	switch category {
+	case "K": // Innergemeinschaftliche Lieferung
+		data.KZ001 += net
+	case "E", "Z", "O":
+		data.KZ011 += net
+	case "AE":
+		// Reverse charge: only part of the total, the recipient owes the tax
+	default:
+		switch percent {
+		case 20:
+			data.KZ017 += net
+		case 10:
+			data.KZ018 += net
+		case 13:
+			data.KZ019 += net
+		default:
+			data.KZ020 += net
+		}
+	}
+}
+
+// PeriodBounds returns the first and last day of a UVA period
+func PeriodBounds(year int, periodType string, month, quarter *int) (time.Time, time.Time, error) {
+	switch periodType {
+	case PeriodTypeMonthly:
+		if month == nil || *month < 1 || *month > 12 {
+			return time.Time{}, time.Time{}, ErrInvalidMonth
+		}
+		from := time.Date(year, time.Month(*month), 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(0, 1, -1), nil
+	case PeriodTypeQuarterly:
+		if quarter == nil || *quarter < 1 || *quarter > 4 {
+			return time.Time{}, time.Time{}, ErrInvalidQuarter
+		}
+		from := time.Date(year, time.Month((*quarter-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(0, 3, -1), nil
+	default:
+		return time.Time{}, time.Time{}, ErrInvalidPeriodType
+	}
+}
+
+func inPeriod(date, from, to time.Time) bool {
+	d := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return !d.Before(from) && !d.After(to)
+}
@@ -5,10 +5,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"austrian-business-infrastructure/internal/account"
 	"austrian-business-infrastructure/internal/account/types"
+	"austrian-business-infrastructure/internal/analytics"
+	"austrian-business-infrastructure/internal/endpoint"
+	"austrian-business-infrastructure/internal/entitychange"
 	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/rawpayload"
+	"austrian-business-infrastructure/internal/xmlschema"
 	"github.com/google/uuid"
 )
 
@@ -21,13 +29,22 @@ var (
 	ErrAccountNotFound     = errors.New("account not found")
 	ErrValidationFailed    = errors.New("validation failed")
 	ErrSubmissionFailed    = errors.New("submission to FinanzOnline failed")
+	ErrInvalidScheme       = errors.New("invalid vat scheme")
+	ErrInvalidEffective    = errors.New("effective_from must be the first day of a month")
+	ErrPeriodAlreadyFiled  = errors.New("a UVA was already submitted for a period affected by this change")
+	ErrNotSubmittable      = errors.New("submission must be in draft or validated status")
+	ErrInvalidPeriod       = errors.New("period must be YYYY-MM or YYYY-Qn")
+	ErrSeveralCompanies    = errors.New("invoices cannot be derived for one of several companies")
 )
 
 // Service handles UVA business logic
 type Service struct {
 	repo           *Repository
 	accountService *account.Service
-	fonwsClient    *fonws.Client
+	fonwsClient    fonws.Caller
+	payloads       *rawpayload.Service
+	analytics      *analytics.Emitter
+	entityChanges  *entitychange.Service
 }
 
 // NewService creates a new UVA service
@@ -39,6 +56,35 @@ func NewService(repo *Repository, accountService *account.Service) *Service {
 	}
 }
 
+// SetRawPayloads enables retention of the raw FinanzOnline exchanges of submissions
+func (s *Service) SetRawPayloads(p *rawpayload.Service) {
+	s.payloads = p
+}
+
+// SetEndpoints makes FinanzOnline calls use the active endpoint set and
+// fail fast during maintenance windows
+func (s *Service) SetEndpoints(r fonws.EndpointResolver) {
+	if c, ok := s.fonwsClient.(*fonws.Client); ok {
+		c.SetEndpointResolver(r)
+	}
+}
+
+// SetAnalytics reports submitted UVAs to the product analytics
+func (s *Service) SetAnalytics(emitter *analytics.Emitter) {
+	s.analytics = emitter
+}
+
+// SetEntityChanges makes Create refuse periods that belong to the other
+// side of a legal entity change of the account
+func (s *Service) SetEntityChanges(c *entitychange.Service) {
+	s.entityChanges = c
+}
+
+// SetFinanzOnline replaces the FinanzOnline client, e.g. with a fake in tests
+func (s *Service) SetFinanzOnline(c fonws.Caller) {
+	s.fonwsClient = c
+}
+
 // Create creates a new UVA submission
 func (s *Service) Create(ctx context.Context, tenantID uuid.UUID, input *CreateSubmissionInput) (*Submission, error) {
 	// Validate period
@@ -54,6 +100,15 @@ func (s *Service) Create(ctx context.Context, tenantID uuid.UUID, input *CreateS
 	if acc.Type != account.AccountTypeFinanzOnline {
 		return nil, errors.New("account must be a FinanzOnline account")
 	}
+	if s.entityChanges != nil {
+		from, to, err := PeriodBounds(input.PeriodYear, input.PeriodType, input.PeriodMonth, input.PeriodQuarter)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.entityChanges.CheckPeriod(ctx, tenantID, input.AccountID, from, to); err != nil {
+			return nil, err
+		}
+	}
 
 	// Get period value
 	periodValue := 0
@@ -74,7 +129,7 @@ func (s *Service) Create(ctx context.Context, tenantID uuid.UUID, input *CreateS
 
 	// Calculate KZ095 if not provided
 	if input.Data.KZ095 == 0 {
-		input.Data.KZ095 = s.calculateKZ095(&input.Data)
+		input.Data.KZ095 = CalculateKZ095(&input.Data)
 	}
 
 	// Serialize data
@@ -122,7 +177,7 @@ func (s *Service) Update(ctx context.Context, id, tenantID uuid.UUID, input *Upd
 
 	// Calculate KZ095 if not provided
 	if input.Data.KZ095 == 0 {
-		input.Data.KZ095 = s.calculateKZ095(&input.Data)
+		input.Data.KZ095 = CalculateKZ095(&input.Data)
 	}
 
 	// Serialize data
@@ -195,7 +250,12 @@ func (s *Service) Validate(ctx context.Context, id, tenantID uuid.UUID) (*Submis
 	return s.repo.GetByID(ctx, id, tenantID)
 }
 
-// Submit submits a UVA to FinanzOnline
+// Submit submits a UVA to FinanzOnline: the U30 XML is generated and
+// checked against the schema of the period, then uploaded through a
+// FinanzOnline session. The status moves from draft or validated to
+// submitted while the file is transmitted and then to accepted, with the
+// Belegnummer as fo_reference, rejected or error. A dry run stops at
+// validated.
 func (s *Service) Submit(ctx context.Context, id, tenantID, userID uuid.UUID, dryRun bool) (*Submission, error) {
 	submission, err := s.repo.GetByID(ctx, id, tenantID)
 	if err != nil {
@@ -204,7 +264,7 @@ func (s *Service) Submit(ctx context.Context, id, tenantID, userID uuid.UUID, dr
 
 	// Must be validated or draft (will validate first)
 	if submission.Status != StatusDraft && submission.Status != StatusValidated {
-		return nil, errors.New("submission must be in draft or validated status")
+		return nil, ErrNotSubmittable
 	}
 
 	// Parse the data
@@ -213,6 +273,10 @@ func (s *Service) Submit(ctx context.Context, id, tenantID, userID uuid.UUID, dr
 		return nil, fmt.Errorf("failed to parse submission data: %w", err)
 	}
 
+	if err := s.checkCorrection(ctx, submission, &data); err != nil {
+		return nil, err
+	}
+
 	// Create fonws UVA struct
 	uva := s.dataToFonwsUVA(submission, &data)
 
@@ -233,6 +297,25 @@ func (s *Service) Submit(ctx context.Context, id, tenantID, userID uuid.UUID, dr
 		return nil, fmt.Errorf("failed to generate XML: %w", err)
 	}
 
+	// Validate against the U30 schema of the reporting period
+	periodStart, _, err := PeriodBounds(submission.PeriodYear, submission.PeriodType, submission.PeriodMonth, submission.PeriodQuarter)
+	if err != nil {
+		return nil, err
+	}
+	if err := xmlschema.Check(xmlschema.FormatU30, periodStart, xmlContent); err != nil {
+		var schemaErr *xmlschema.ValidationError
+		if !errors.As(err, &schemaErr) {
+			return nil, err
+		}
+		validationErrors, _ := json.Marshal(map[string]interface{}{"error": schemaErr.Error(), "schema": schemaErr})
+		submission.ValidationStatus = "failed"
+		submission.ValidationErrors = validationErrors
+		if updateErr := s.repo.Update(ctx, submission); updateErr != nil {
+			return nil, updateErr
+		}
+		return nil, ErrValidationFailed
+	}
+
 	// Save XML content
 	if err := s.repo.SaveXMLContent(ctx, id, tenantID, xmlContent); err != nil {
 		return nil, err
@@ -267,29 +350,60 @@ func (s *Service) Submit(ctx context.Context, id, tenantID, userID uuid.UUID, dr
 	}
 	defer sessionService.Logout(session)
 
+	// Claim the submission while it is transmitted, so that a concurrent
+	// submit of the same UVA cannot send it twice
+	if err := s.repo.ClaimSubmission(ctx, id, tenantID); err != nil {
+		return nil, err
+	}
+
 	// Submit to FinanzOnline
-	uploadService := fonws.NewFileUploadService(s.fonwsClient)
+	rec := rawpayload.NewRecorder()
+	uploadService := fonws.NewFileUploadService(fonws.Recording(s.fonwsClient, rec))
 	resp, err := uploadService.SubmitUVA(session.Token, foCreds.TID, foCreds.BenID, uva)
+	if errors.Is(err, endpoint.ErrMaintenanceWindow) {
+		// Nothing was sent; the submission can be submitted again
+		if releaseErr := s.repo.ReleaseSubmission(ctx, id, tenantID); releaseErr != nil {
+			return nil, releaseErr
+		}
+		return nil, err
+	}
 
+	// FinanzOnline accepts the U30 with a Belegnummer, the protocol number of
+	// the filing, or rejects it with an error code. Session and technical
+	// errors leave the outcome open: the UVA is marked error for review.
 	var status string
 	var foRef string
 	var respCode int
 	var respMsg string
 
-	if err != nil {
+	switch {
+	case err == nil:
+		status = StatusAccepted
+		foRef = resp.Belegnummer
+		respCode = resp.RC
+		respMsg = resp.Msg
+	case resp != nil && !fonws.IsRetryable(err):
+		status = StatusRejected
+		respCode = resp.RC
+		respMsg = resp.Msg
+	default:
 		status = StatusError
 		respMsg = err.Error()
 		if resp != nil {
 			respCode = resp.RC
 			respMsg = resp.Msg
 		}
-	} else {
-		status = StatusSubmitted
-		foRef = resp.Belegnummer
-		respCode = resp.RC
-		respMsg = resp.Msg
 	}
 
+	s.payloads.Save(ctx, rawpayload.Reference{
+		TenantID:          &tenantID,
+		Module:            rawpayload.ModuleUVA,
+		ReferenceType:     "uva_submission",
+		ReferenceID:       &id,
+		ProviderReference: foRef,
+		Outcome:           rawpayload.OutcomeOf(err == nil, resp != nil),
+	}, rec)
+
 	// Update submission with result
 	if err := s.repo.UpdateSubmissionResult(ctx, id, tenantID, foRef, respCode, respMsg, status); err != nil {
 		return nil, err
@@ -299,6 +413,9 @@ func (s *Service) Submit(ctx context.Context, id, tenantID, userID uuid.UUID, dr
 	if err := s.repo.SetSubmittedBy(ctx, id, tenantID, userID); err != nil {
 		return nil, err
 	}
+	if status == StatusAccepted {
+		s.analytics.Track(ctx, tenantID, userID, analytics.EventFilingSubmitted, analytics.Properties{"kind": "uva", "period_type": submission.PeriodType})
+	}
 
 	return s.repo.GetByID(ctx, id, tenantID)
 }
@@ -324,6 +441,26 @@ func (s *Service) GetXML(ctx context.Context, id, tenantID uuid.UUID) ([]byte, e
 	return fonws.GenerateUVAXML(uva)
 }
 
+// RenderXML re-renders the XML of a submission with the current generator,
+// ignoring the XML stored at submission time
+func (s *Service) RenderXML(ctx context.Context, id, tenantID uuid.UUID) (*Submission, []byte, error) {
+	submission, err := s.repo.GetByID(ctx, id, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var data UVAData
+	if err := json.Unmarshal(submission.Data, &data); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse submission data: %w", err)
+	}
+
+	xmlContent, err := fonws.GenerateUVAXML(s.dataToFonwsUVA(submission, &data))
+	if err != nil {
+		return nil, nil, err
+	}
+	return submission, xmlContent, nil
+}
+
 // Batch operations
 
 // CreateBatch creates a new batch UVA submission
@@ -366,6 +503,191 @@ func (s *Service) ListBatches(ctx context.Context, tenantID uuid.UUID, limit, of
 	return s.repo.ListBatches(ctx, tenantID, limit, offset)
 }
 
+// SetScheme records a change of the VAT scheme for the tenant or a single company.
+// Changes take effect at the start of a month and cannot reach back into periods
+// for which a UVA was already submitted; those need a Berichtigung instead.
+func (s *Service) SetScheme(ctx context.Context, tenantID, userID uuid.UUID, input *SetSchemeInput) (*VATScheme, error) {
+	if !IsValidScheme(input.Scheme) {
+		return nil, ErrInvalidScheme
+	}
+
+	effectiveFrom, err := time.Parse("2006-01-02", input.EffectiveFrom)
+	if err != nil || effectiveFrom.Day() != 1 {
+		return nil, ErrInvalidEffective
+	}
+
+	if input.AccountID != nil {
+		if _, err := s.accountService.GetAccount(ctx, *input.AccountID, tenantID); err != nil {
+			return nil, ErrAccountNotFound
+		}
+	}
+
+	filed, err := s.repo.HasSubmittedSince(ctx, tenantID, input.AccountID, effectiveFrom)
+	if err != nil {
+		return nil, err
+	}
+	if filed {
+		return nil, ErrPeriodAlreadyFiled
+	}
+
+	return s.repo.CreateScheme(ctx, &VATScheme{
+		TenantID:      tenantID,
+		AccountID:     input.AccountID,
+		Scheme:        input.Scheme,
+		EffectiveFrom: effectiveFrom,
+		CreatedBy:     &userID,
+	})
+}
+
+// ListSchemes returns the VAT scheme history of the tenant default or of a company
+func (s *Service) ListSchemes(ctx context.Context, tenantID uuid.UUID, accountID *uuid.UUID) ([]*VATScheme, error) {
+	return s.repo.ListSchemes(ctx, tenantID, accountID)
+}
+
+// DeleteScheme removes a VAT scheme history entry
+func (s *Service) DeleteScheme(ctx context.Context, id, tenantID uuid.UUID) error {
+	return s.repo.DeleteScheme(ctx, id, tenantID)
+}
+
+// Derive calculates the Kennzahlen of a period from the tenant's outgoing and input
+// invoices, using invoice dates under Sollbesteuerung and payment dates under
+// Istbesteuerung. Other corrections (KZ070) have to be added by the user.
+//
+// Invoices are not assigned to a company, so a tenant with several
+// FinanzOnline accounts cannot derive them: the Kennzahlen of one company
+// would include the invoices of all others.
+func (s *Service) Derive(ctx context.Context, tenantID uuid.UUID, input *DeriveInput) (*Derivation, error) {
+	if input.PeriodYear < 2000 || input.PeriodYear > 2100 {
+		return nil, ErrInvalidYear
+	}
+	from, to, err := PeriodBounds(input.PeriodYear, input.PeriodType, input.PeriodMonth, input.PeriodQuarter)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.accountService.GetAccount(ctx, input.AccountID, tenantID); err != nil {
+		return nil, ErrAccountNotFound
+	}
+	_, companies, err := s.accountService.ListAccounts(ctx, account.ListFilter{
+		TenantID: tenantID,
+		Type:     account.AccountTypeFinanzOnline,
+		Limit:    1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if companies > 1 {
+		return nil, ErrSeveralCompanies
+	}
+
+	history, err := s.schemeHistory(ctx, tenantID, input.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices, err := s.repo.ListTaxableInvoices(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	inputInvoices, err := s.repo.ListInputInvoices(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	data, contributions := DeriveKennzahlen(from, to, invoices, history)
+	contributions = append(contributions, DeriveInputTax(data, from, to, inputInvoices, history)...)
+	data.KZ095 = CalculateKZ095(data)
+	if contributions == nil {
+		contributions = []Contribution{}
+	}
+
+	return &Derivation{
+		AccountID:     input.AccountID,
+		PeriodStart:   from.Format("2006-01-02"),
+		PeriodEnd:     to.Format("2006-01-02"),
+		Scheme:        history.At(to),
+		SchemeChanged: history.ChangedWithin(from, to),
+		Data:          *data,
+		Contributions: contributions,
+	}, nil
+}
+
+// Compute derives the Kennzahlen of a period ("2025-03" or "2025-Q1") and creates a
+// draft submission prefilled with them. The draft can be adjusted like any other
+// before it is validated and submitted.
+func (s *Service) Compute(ctx context.Context, tenantID, accountID uuid.UUID, period string) (*Submission, *Derivation, error) {
+	input, err := ParsePeriod(period)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.AccountID = accountID
+
+	derivation, err := s.Derive(ctx, tenantID, input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	submission, err := s.Create(ctx, tenantID, &CreateSubmissionInput{
+		AccountID:     accountID,
+		PeriodYear:    input.PeriodYear,
+		PeriodMonth:   input.PeriodMonth,
+		PeriodQuarter: input.PeriodQuarter,
+		PeriodType:    input.PeriodType,
+		Data:          derivation.Data,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return submission, derivation, nil
+}
+
+// ParsePeriod parses a monthly ("2025-03") or quarterly ("2025-Q1") UVA period
+func ParsePeriod(period string) (*DeriveInput, error) {
+	year, rest, ok := strings.Cut(strings.ToUpper(strings.TrimSpace(period)), "-")
+	if !ok || len(year) != 4 {
+		return nil, ErrInvalidPeriod
+	}
+	y, err := strconv.Atoi(year)
+	if err != nil {
+		return nil, ErrInvalidPeriod
+	}
+
+	input := &DeriveInput{PeriodYear: y}
+	if q, ok := strings.CutPrefix(rest, "Q"); ok {
+		quarter, err := strconv.Atoi(q)
+		if err != nil || len(q) != 1 || quarter < 1 || quarter > 4 {
+			return nil, ErrInvalidPeriod
+		}
+		input.PeriodType = PeriodTypeQuarterly
+		input.PeriodQuarter = &quarter
+		return input, nil
+	}
+
+	month, err := strconv.Atoi(rest)
+	if err != nil || len(rest) != 2 || month < 1 || month > 12 {
+		return nil, ErrInvalidPeriod
+	}
+	input.PeriodType = PeriodTypeMonthly
+	input.PeriodMonth = &month
+	return input, nil
+}
+
+// schemeHistory resolves the scheme history of a company, falling back to the tenant default
+func (s *Service) schemeHistory(ctx context.Context, tenantID, accountID uuid.UUID) (SchemeHistory, error) {
+	entries, err := s.repo.ListSchemes(ctx, tenantID, &accountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		entries, err = s.repo.ListSchemes(ctx, tenantID, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewSchemeHistory(entries), nil
+}
+
 // Helper methods
 
 func (s *Service) validatePeriod(input *CreateSubmissionInput) error {
@@ -395,7 +717,9 @@ func (s *Service) validatePeriod(input *CreateSubmissionInput) error {
 	return nil
 }
 
-func (s *Service) calculateKZ095(data *UVAData) int64 {
+// CalculateKZ095 returns the Zahllast (positive) or Gutschrift (negative)
+// of the Kennzahlen
+func CalculateKZ095(data *UVAData) int64 {
 	// Tax payable: 20% of KZ017 + 10% of KZ018 + 13% of KZ019 + other taxes
 	taxPayable := int64(0)
 	taxPayable += data.KZ017 * 20 / 100
@@ -436,6 +760,13 @@ func (s *Service) dataToFonwsUVA(submission *Submission, data *UVAData) *fonws.U
 		uva.Period = fonws.UVAPeriod{Type: fonws.PeriodTypeQuarterly, Value: *submission.PeriodQuarter}
 	}
 
+	if submission.CorrectsID != nil {
+		uva.Berichtigung = &fonws.Berichtigung{}
+		if submission.CorrectsRef != nil {
+			uva.Berichtigung.Bezugsnummer = *submission.CorrectsRef
+		}
+	}
+
 	return uva
 }
 
@@ -42,6 +42,8 @@ type Submission struct {
 	SubmittedBy      *uuid.UUID       `json:"submitted_by,omitempty"`
 	ResponseCode     *int             `json:"response_code,omitempty"`
 	ResponseMessage  *string          `json:"response_message,omitempty"`
+	CorrectsID       *uuid.UUID       `json:"corrects_id,omitempty"`  // Filing this Berichtigung corrects
+	CorrectsRef      *string          `json:"corrects_ref,omitempty"` // FO reference of the corrected filing
 	CreatedAt        time.Time        `json:"created_at"`
 	UpdatedAt        time.Time        `json:"updated_at"`
 }
@@ -138,6 +140,7 @@ type SubmissionResponse struct {
 	ValidationErrors json.RawMessage `json:"validation_errors,omitempty"`
 	Status           string          `json:"status"`
 	FOReference      *string         `json:"fo_reference,omitempty"`
+	CorrectsID       *uuid.UUID      `json:"corrects_id,omitempty"`
 	SubmittedAt      *string         `json:"submitted_at,omitempty"`
 	CreatedAt        string          `json:"created_at"`
 	UpdatedAt        string          `json:"updated_at"`
@@ -159,3 +162,42 @@ type BatchResponse struct {
 	CompletedAt   *string   `json:"completed_at,omitempty"`
 	CreatedAt     string    `json:"created_at"`
 }
+
+// SetSchemeInput represents input for configuring the VAT scheme of a tenant or company
+type SetSchemeInput struct {
+	AccountID     *uuid.UUID `json:"account_id,omitempty"` // nil = tenant default
+	Scheme        string     `json:"scheme"`
+	EffectiveFrom string     `json:"effective_from"` // First day of a month, YYYY-MM-DD
+}
+
+// DeriveInput represents input for deriving Kennzahlen from invoices
+type DeriveInput struct {
+	AccountID     uuid.UUID `json:"account_id"`
+	PeriodYear    int       `json:"period_year"`
+	PeriodMonth   *int      `json:"period_month,omitempty"`
+	PeriodQuarter *int      `json:"period_quarter,omitempty"`
+	PeriodType    string    `json:"period_type"`
+}
+
+// Derivation is the result of deriving Kennzahlen from invoices
+type Derivation struct {
+	AccountID     uuid.UUID      `json:"account_id"`
+	PeriodStart   string         `json:"period_start"`
+	PeriodEnd     string         `json:"period_end"`
+	Scheme        string         `json:"scheme"` // Scheme in effect at the end of the period
+	SchemeChanged bool           `json:"scheme_changed"`
+	Data          UVAData        `json:"data"`
+	Contributions []Contribution `json:"contributions"`
+}
+
+// ChainResponse is the API response format of a correction chain
+type ChainResponse struct {
+	Filings []*ChainFilingResponse `json:"filings"`
+}
+
+// ChainFilingResponse is a filing of a correction chain with the Kennzahlen
+// it changed
+type ChainFilingResponse struct {
+	*SubmissionResponse
+	Delta []KennzahlDelta `json:"delta,omitempty"`
+}
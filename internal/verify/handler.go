@@ -7,6 +7,8 @@ import (
 	"net/http"
 
 	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/filetype"
 )
 
 // Handler provides HTTP handlers for signature verification
@@ -54,15 +56,10 @@ func (h *Handler) VerifyUpload(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	// Check file type
-	contentType := header.Header.Get("Content-Type")
-	if contentType != "application/pdf" && contentType != "" {
-		// Try to detect from filename
-		filename := header.Filename
-		if len(filename) < 4 || filename[len(filename)-4:] != ".pdf" {
-			writeError(w, http.StatusBadRequest, "only PDF files are supported")
-			return
-		}
+	// Check file type from the content, not the declared Content-Type
+	if _, err := filetype.Signature.Check(header.Filename, file, header.Size); err != nil {
+		writeError(w, filetype.HTTPStatus(err), err.Error())
+		return
 	}
 
 	// Read file content
@@ -13,11 +13,11 @@ import (
 // Handler handles watchlist HTTP requests
 type Handler struct {
 	repo     *Repository
-	fbClient *fb.Client
+	fbClient fb.Registry
 }
 
 // NewHandler creates a new watchlist handler
-func NewHandler(repo *Repository, fbClient *fb.Client) *Handler {
+func NewHandler(repo *Repository, fbClient fb.Registry) *Handler {
 	return &Handler{
 		repo:     repo,
 		fbClient: fbClient,
@@ -253,6 +253,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 
 	// Verify tenant
 	if item.TenantID != tenantUUID {
+		api.ReportTenantMismatch(r, "watchlist_item", item.ID.String())
 		api.JSONError(w, http.StatusNotFound, "watchlist item not found", api.ErrCodeNotFound)
 		return
 	}
@@ -357,6 +358,7 @@ func (h *Handler) CheckNow(w http.ResponseWriter, r *http.Request) {
 
 	// Verify tenant
 	if item.TenantID != tenantUUID {
+		api.ReportTenantMismatch(r, "watchlist_item", item.ID.String())
 		api.JSONError(w, http.StatusNotFound, "watchlist item not found", api.ErrCodeNotFound)
 		return
 	}
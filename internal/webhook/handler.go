@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -34,6 +35,8 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("PUT /api/v1/webhooks/{id}", h.Update)
 	mux.HandleFunc("DELETE /api/v1/webhooks/{id}", h.Delete)
 	mux.HandleFunc("POST /api/v1/webhooks/{id}/rotate-secret", h.RotateSecret)
+	mux.HandleFunc("GET /api/v1/webhooks/{id}/keys", h.ListKeys)
+	mux.HandleFunc("DELETE /api/v1/webhooks/{id}/keys/{keyId}", h.RetireKey)
 	mux.HandleFunc("GET /api/v1/webhooks/{id}/deliveries", h.ListDeliveries)
 	mux.HandleFunc("POST /api/v1/webhooks/{id}/test", h.TestWebhook)
 }
@@ -132,11 +135,12 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	// Validate events
 	validEvents := map[string]bool{
-		EventNewDocument:     true,
-		EventDeadlineWarning: true,
-		EventFBChange:        true,
-		EventSyncComplete:    true,
-		EventDocumentRead:    true,
+		EventNewDocument:       true,
+		EventDeadlineWarning:   true,
+		EventFBChange:          true,
+		EventSyncComplete:      true,
+		EventDocumentRead:      true,
+		EventPartnerUIDInvalid: true,
 	}
 	for _, event := range req.Events {
 		if !validEvents[event] {
@@ -256,6 +260,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 
 	// Verify tenant
 	if webhook.TenantID != tenantUUID {
+		api.ReportTenantMismatch(r, "webhook", webhook.ID.String())
 		api.JSONError(w, http.StatusNotFound, "webhook not found", api.ErrCodeNotFound)
 		return
 	}
@@ -325,39 +330,153 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	api.JSONResponse(w, http.StatusOK, map[string]string{"status": "deleted"})
 }
 
-// RotateSecret generates a new secret for a webhook
+// RotateSecret adds a new signing key to a webhook. The previous key stays
+// valid for the overlap period, 24 hours unless the request sets
+// overlap_hours; 0 retires it at once.
 func (h *Handler) RotateSecret(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	tenantID := api.GetTenantID(ctx)
 
-	if tenantID == "" {
-		api.JSONError(w, http.StatusUnauthorized, "unauthorized", api.ErrCodeUnauthorized)
+	webhook, ok := h.tenantWebhook(w, r)
+	if !ok {
 		return
 	}
 
-	webhookID, err := uuid.Parse(r.PathValue("id"))
+	var req struct {
+		OverlapHours *int `json:"overlap_hours"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			api.JSONError(w, http.StatusBadRequest, "invalid request body", api.ErrCodeBadRequest)
+			return
+		}
+	}
+
+	overlap := DefaultKeyOverlap
+	if req.OverlapHours != nil {
+		overlap = time.Duration(*req.OverlapHours) * time.Hour
+	}
+
+	key, previous, err := h.service.RotateKey(ctx, webhook.ID, overlap)
 	if err != nil {
-		api.JSONError(w, http.StatusBadRequest, "invalid webhook ID", api.ErrCodeBadRequest)
+		if errors.Is(err, ErrInvalidOverlap) {
+			api.JSONError(w, http.StatusBadRequest, "overlap_hours must be between 0 and 168", api.ErrCodeValidation)
+			return
+		}
+		if errors.Is(err, ErrWebhookNotFound) {
+			api.JSONError(w, http.StatusNotFound, "webhook not found", api.ErrCodeNotFound)
+			return
+		}
+		api.JSONError(w, http.StatusInternalServerError, "failed to rotate secret", api.ErrCodeInternalError)
 		return
 	}
 
-	// Generate new secret
-	newSecret, err := generateSecret(32)
-	if err != nil {
-		api.JSONError(w, http.StatusInternalServerError, "failed to generate secret", api.ErrCodeInternalError)
+	response := map[string]interface{}{
+		"secret":     key.Secret, // Only returned on rotation
+		"key_id":     key.ID.String(),
+		"valid_from": key.ValidFrom.Format(time.RFC3339),
+	}
+	if previous != nil {
+		response["previous_key_id"] = previous.ID.String()
+		response["previous_key_valid_until"] = previous.ValidUntil.Format(time.RFC3339)
+	}
+
+	api.JSONResponse(w, http.StatusOK, response)
+}
+
+// ListKeys lists the signing keys of a webhook, without their secrets
+func (h *Handler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.tenantWebhook(w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.repo.UpdateSecret(ctx, webhookID, newSecret); err != nil {
-		api.JSONError(w, http.StatusInternalServerError, "failed to update secret", api.ErrCodeInternalError)
+	keys, err := h.service.ListKeys(r.Context(), webhook.ID)
+	if err != nil {
+		api.JSONError(w, http.StatusInternalServerError, "failed to list signing keys", api.ErrCodeInternalError)
 		return
 	}
 
-	api.JSONResponse(w, http.StatusOK, map[string]string{
-		"secret": newSecret,
+	response := make([]map[string]interface{}, len(keys))
+	for i, k := range keys {
+		response[i] = map[string]interface{}{
+			"id":         k.ID.String(),
+			"current":    k.Current(),
+			"valid_from": k.ValidFrom.Format(time.RFC3339),
+			"created_at": k.CreatedAt.Format(time.RFC3339),
+		}
+		if k.ValidUntil != nil {
+			response[i]["valid_until"] = k.ValidUntil.Format(time.RFC3339)
+		}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"keys": response,
 	})
 }
 
+// RetireKey retires the previous signing key of a webhook before its
+// overlap period ends
+func (h *Handler) RetireKey(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.tenantWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	keyID, err := uuid.Parse(r.PathValue("keyId"))
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid key ID", api.ErrCodeBadRequest)
+		return
+	}
+
+	if err := h.service.RetireKey(r.Context(), webhook.ID, keyID); err != nil {
+		switch {
+		case errors.Is(err, ErrSigningKeyNotFound):
+			api.JSONError(w, http.StatusNotFound, "signing key not found", api.ErrCodeNotFound)
+		case errors.Is(err, ErrCurrentSigningKey):
+			api.JSONError(w, http.StatusConflict, err.Error(), api.ErrCodeConflict)
+		default:
+			api.JSONError(w, http.StatusInternalServerError, "failed to retire signing key", api.ErrCodeInternalError)
+		}
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]string{"status": "retired"})
+}
+
+// tenantWebhook loads the webhook of the request path and writes an error
+// unless it belongs to the tenant of the request
+func (h *Handler) tenantWebhook(w http.ResponseWriter, r *http.Request) (*Webhook, bool) {
+	tenantID := api.GetTenantID(r.Context())
+	if tenantID == "" {
+		api.JSONError(w, http.StatusUnauthorized, "unauthorized", api.ErrCodeUnauthorized)
+		return nil, false
+	}
+
+	webhookID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.JSONError(w, http.StatusBadRequest, "invalid webhook ID", api.ErrCodeBadRequest)
+		return nil, false
+	}
+
+	webhook, err := h.repo.GetByID(r.Context(), webhookID)
+	if err != nil {
+		if errors.Is(err, ErrWebhookNotFound) {
+			api.JSONError(w, http.StatusNotFound, "webhook not found", api.ErrCodeNotFound)
+			return nil, false
+		}
+		api.JSONError(w, http.StatusInternalServerError, "failed to get webhook", api.ErrCodeInternalError)
+		return nil, false
+	}
+
+	if webhook.TenantID.String() != tenantID {
+		api.ReportTenantMismatch(r, "webhook", webhookID.String())
+		api.JSONError(w, http.StatusNotFound, "webhook not found", api.ErrCodeNotFound)
+		return nil, false
+	}
+
+	return webhook, true
+}
+
 // ListDeliveries lists deliveries for a webhook
 func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -451,6 +570,7 @@ func (h *Handler) TestWebhook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if webhook.TenantID != tenantUUID {
+		api.ReportTenantMismatch(r, "webhook", webhook.ID.String())
 		api.JSONError(w, http.StatusNotFound, "webhook not found", api.ErrCodeNotFound)
 		return
 	}
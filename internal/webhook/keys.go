@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Signing key errors
+var (
+	ErrSigningKeyNotFound = errors.New("signing key not found")
+	ErrCurrentSigningKey  = errors.New("the current signing key cannot be retired, rotate it instead")
+	ErrInvalidOverlap     = errors.New("invalid overlap period")
+)
+
+// Rotation overlap periods
+const (
+	DefaultKeyOverlap = 24 * time.Hour
+	MaxKeyOverlap     = 7 * 24 * time.Hour
+)
+
+// Signature headers. SignatureHeader carries one signature by the oldest
+// valid key, the one consumers that have not picked up a rotation still
+// hold; SignaturesHeader carries one per valid key.
+const (
+	SignatureHeader  = "X-Webhook-Signature"
+	KeyIDHeader      = "X-Webhook-Key-ID"
+	SignaturesHeader = "X-Webhook-Signatures"
+)
+
+// SigningKey is a secret deliveries of a webhook are signed with while it
+// is valid. The current key has no end of validity; the previous one is
+// valid until the overlap period of the rotation that replaced it ends.
+type SigningKey struct {
+	ID         uuid.UUID  `json:"id"`
+	WebhookID  uuid.UUID  `json:"webhook_id"`
+	Secret     string     `json:"-"`
+	ValidFrom  time.Time  `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ValidAt reports whether deliveries are signed with the key at t
+func (k *SigningKey) ValidAt(t time.Time) bool {
+	return !t.Before(k.ValidFrom) && (k.ValidUntil == nil || t.Before(*k.ValidUntil))
+}
+
+// Current reports whether the key is the current one
+func (k *SigningKey) Current() bool {
+	return k.ValidUntil == nil
+}
+
+// SignRequest sets the signature headers of a delivery signed with keys,
+// oldest first
+func SignRequest(req *http.Request, payload []byte, keys []*SigningKey) {
+	if len(keys) == 0 {
+		return
+	}
+	entries := make([]string, len(keys))
+	for i, k := range keys {
+		entries[i] = fmt.Sprintf("key=%s;sha256=%s", k.ID, hex.EncodeToString(hmacSHA256(payload, k.Secret)))
+	}
+	req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(hmacSHA256(payload, keys[0].Secret)))
+	req.Header.Set(KeyIDHeader, keys[0].ID.String())
+	req.Header.Set(SignaturesHeader, strings.Join(entries, ", "))
+}
+
+// VerifySignatures verifies the X-Webhook-Signatures header of a delivery
+// against a secret. It holds if any of the signatures was made with it, so
+// that consumers verify deliveries during an overlap period with either
+// the old or the new secret.
+func VerifySignatures(payload []byte, header, secret string) bool {
+	expected := []byte(hex.EncodeToString(hmacSHA256(payload, secret)))
+	for _, entry := range strings.Split(header, ",") {
+		for _, part := range strings.Split(strings.TrimSpace(entry), ";") {
+			if sig, ok := strings.CutPrefix(part, "sha256="); ok && hmac.Equal([]byte(sig), expected) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ListKeys returns the signing keys of a webhook that have not been
+// retired, oldest first
+func (s *Service) ListKeys(ctx context.Context, webhookID uuid.UUID) ([]*SigningKey, error) {
+	if _, err := s.repo.DeleteExpiredKeys(ctx); err != nil {
+		s.logger.Warn("failed to delete expired signing keys", "error", err)
+	}
+	return s.repo.ListKeys(ctx, webhookID)
+}
+
+// RotateKey adds a new current signing key to a webhook. The previous
+// current key stays valid for overlap, and a key still valid from an
+// earlier rotation is retired at once, so that at most two keys are
+// valid. An overlap of zero retires the previous key at once, for a
+// leaked secret.
+func (s *Service) RotateKey(ctx context.Context, webhookID uuid.UUID, overlap time.Duration) (*SigningKey, *SigningKey, error) {
+	if overlap < 0 || overlap > MaxKeyOverlap {
+		return nil, nil, fmt.Errorf("%w: at most %s", ErrInvalidOverlap, MaxKeyOverlap)
+	}
+
+	secret, err := generateSecret(32)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate secret: %w", err)
+	}
+
+	now := time.Now()
+	key := &SigningKey{WebhookID: webhookID, Secret: secret, ValidFrom: now}
+	previous, err := s.repo.RotateKey(ctx, key, now.Add(overlap))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.logger.Info("webhook signing key rotated",
+		"webhook_id", webhookID,
+		"key_id", key.ID,
+		"overlap", overlap)
+
+	return key, previous, nil
+}
+
+// RetireKey ends the overlap period of a previous signing key early
+func (s *Service) RetireKey(ctx context.Context, webhookID, keyID uuid.UUID) error {
+	keys, err := s.repo.ListKeys(ctx, webhookID)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if k.ID != keyID {
+			continue
+		}
+		if k.Current() {
+			return ErrCurrentSigningKey
+		}
+		return s.repo.DeleteKey(ctx, webhookID, keyID)
+	}
+	return ErrSigningKeyNotFound
+}
@@ -23,7 +23,7 @@ type Webhook struct {
 	TenantID       uuid.UUID         `json:"tenant_id"`
 	Name           string            `json:"name"`
 	URL            string            `json:"url"`
-	Secret         string            `json:"-"` // Never expose secret
+	Secret         string            `json:"-"` // First signing key on Create, not loaded otherwise
 	Events         []string          `json:"events"`
 	Enabled        bool              `json:"enabled"`
 	TimeoutSeconds int               `json:"timeout_seconds"`
@@ -61,7 +61,9 @@ func NewRepository(db *pgxpool.Pool) *Repository {
 	return &Repository{db: db}
 }
 
-// Create creates a new webhook
+// Create creates a new webhook with its secret as the first signing key.
+// webhooks.secret is still written with the current key for the previous
+// release; see migration 098.
 func (r *Repository) Create(ctx context.Context, wh *Webhook) error {
 	now := time.Now()
 	if wh.ID == uuid.Nil {
@@ -77,6 +79,12 @@ func (r *Repository) Create(ctx context.Context, wh *Webhook) error {
 		wh.MaxRetries = 3
 	}
 
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 		INSERT INTO webhooks (
 			id, tenant_id, name, url, secret, events, enabled,
@@ -84,7 +92,7 @@ func (r *Repository) Create(ctx context.Context, wh *Webhook) error {
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 
-	_, err := r.db.Exec(ctx, query,
+	_, err = tx.Exec(ctx, query,
 		wh.ID, wh.TenantID, wh.Name, wh.URL, wh.Secret, wh.Events, wh.Enabled,
 		wh.TimeoutSeconds, wh.MaxRetries, wh.Headers, wh.CreatedAt, wh.UpdatedAt,
 	)
@@ -92,20 +100,28 @@ func (r *Repository) Create(ctx context.Context, wh *Webhook) error {
 		return fmt.Errorf("create webhook: %w", err)
 	}
 
-	return nil
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webhook_signing_keys (webhook_id, secret, valid_from, created_at)
+		VALUES ($1, $2, $3, $3)
+	`, wh.ID, wh.Secret, now)
+	if err != nil {
+		return fmt.Errorf("create signing key: %w", err)
+	}
+
+	return tx.Commit(ctx)
 }
 
 // GetByID retrieves a webhook by ID
 func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*Webhook, error) {
 	query := `
-		SELECT id, tenant_id, name, url, secret, events, enabled,
+		SELECT id, tenant_id, name, url, events, enabled,
 		       timeout_seconds, max_retries, headers, created_at, updated_at
 		FROM webhooks WHERE id = $1
 	`
 
 	wh := &Webhook{}
 	err := r.db.QueryRow(ctx, query, id).Scan(
-		&wh.ID, &wh.TenantID, &wh.Name, &wh.URL, &wh.Secret, &wh.Events, &wh.Enabled,
+		&wh.ID, &wh.TenantID, &wh.Name, &wh.URL, &wh.Events, &wh.Enabled,
 		&wh.TimeoutSeconds, &wh.MaxRetries, &wh.Headers, &wh.CreatedAt, &wh.UpdatedAt,
 	)
 
@@ -122,7 +138,7 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*Webhook, error
 // List retrieves webhooks for a tenant
 func (r *Repository) List(ctx context.Context, tenantID uuid.UUID, enabledOnly bool) ([]*Webhook, error) {
 	query := `
-		SELECT id, tenant_id, name, url, secret, events, enabled,
+		SELECT id, tenant_id, name, url, events, enabled,
 		       timeout_seconds, max_retries, headers, created_at, updated_at
 		FROM webhooks WHERE tenant_id = $1
 	`
@@ -143,7 +159,7 @@ func (r *Repository) List(ctx context.Context, tenantID uuid.UUID, enabledOnly b
 	for rows.Next() {
 		wh := &Webhook{}
 		err := rows.Scan(
-			&wh.ID, &wh.TenantID, &wh.Name, &wh.URL, &wh.Secret, &wh.Events, &wh.Enabled,
+			&wh.ID, &wh.TenantID, &wh.Name, &wh.URL, &wh.Events, &wh.Enabled,
 			&wh.TimeoutSeconds, &wh.MaxRetries, &wh.Headers, &wh.CreatedAt, &wh.UpdatedAt,
 		)
 		if err != nil {
@@ -158,7 +174,7 @@ func (r *Repository) List(ctx context.Context, tenantID uuid.UUID, enabledOnly b
 // ListByEvent retrieves enabled webhooks subscribed to a specific event
 func (r *Repository) ListByEvent(ctx context.Context, tenantID uuid.UUID, eventType string) ([]*Webhook, error) {
 	query := `
-		SELECT id, tenant_id, name, url, secret, events, enabled,
+		SELECT id, tenant_id, name, url, events, enabled,
 		       timeout_seconds, max_retries, headers, created_at, updated_at
 		FROM webhooks
 		WHERE tenant_id = $1 AND enabled = TRUE AND $2 = ANY(events)
@@ -175,7 +191,7 @@ func (r *Repository) ListByEvent(ctx context.Context, tenantID uuid.UUID, eventT
 	for rows.Next() {
 		wh := &Webhook{}
 		err := rows.Scan(
-			&wh.ID, &wh.TenantID, &wh.Name, &wh.URL, &wh.Secret, &wh.Events, &wh.Enabled,
+			&wh.ID, &wh.TenantID, &wh.Name, &wh.URL, &wh.Events, &wh.Enabled,
 			&wh.TimeoutSeconds, &wh.MaxRetries, &wh.Headers, &wh.CreatedAt, &wh.UpdatedAt,
 		)
 		if err != nil {
@@ -214,13 +230,6 @@ func (r *Repository) Update(ctx context.Context, wh *Webhook) error {
 	return nil
 }
 
-// UpdateSecret updates the webhook secret
-func (r *Repository) UpdateSecret(ctx context.Context, id uuid.UUID, secret string) error {
-	query := `UPDATE webhooks SET secret = $1, updated_at = NOW() WHERE id = $2`
-	_, err := r.db.Exec(ctx, query, secret, id)
-	return err
-}
-
 // Delete deletes a webhook
 func (r *Repository) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
 	query := `DELETE FROM webhooks WHERE id = $1 AND tenant_id = $2`
@@ -367,3 +376,134 @@ func (r *Repository) ListDeliveries(ctx context.Context, webhookID uuid.UUID, li
 
 	return deliveries, total, rows.Err()
 }
+
+// ListKeys lists the signing keys of a webhook that have not been deleted,
+// oldest first
+func (r *Repository) ListKeys(ctx context.Context, webhookID uuid.UUID) ([]*SigningKey, error) {
+	query := `
+		SELECT id, webhook_id, secret, valid_from, valid_until, created_at
+		FROM webhook_signing_keys WHERE webhook_id = $1
+		ORDER BY valid_from, created_at
+	`
+	return r.queryKeys(ctx, query, webhookID)
+}
+
+// ActiveKeys lists the signing keys of a webhook valid now, oldest first
+func (r *Repository) ActiveKeys(ctx context.Context, webhookID uuid.UUID) ([]*SigningKey, error) {
+	query := `
+		SELECT id, webhook_id, secret, valid_from, valid_until, created_at
+		FROM webhook_signing_keys
+		WHERE webhook_id = $1 AND valid_from <= NOW() AND (valid_until IS NULL OR valid_until > NOW())
+		ORDER BY valid_from, created_at
+	`
+	return r.queryKeys(ctx, query, webhookID)
+}
+
+func (r *Repository) queryKeys(ctx context.Context, query string, args ...any) ([]*SigningKey, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*SigningKey
+	for rows.Next() {
+		k := &SigningKey{}
+		if err := rows.Scan(&k.ID, &k.WebhookID, &k.Secret, &k.ValidFrom, &k.ValidUntil, &k.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan signing key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+// RotateKey makes key the current signing key of its webhook. The previous
+// current key stays valid until previousUntil and is returned; keys still
+// valid from earlier rotations are deleted.
+func (r *Repository) RotateKey(ctx context.Context, key *SigningKey, previousUntil time.Time) (*SigningKey, error) {
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+	key.CreatedAt = key.ValidFrom
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialize rotations of the webhook
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT id FROM webhooks WHERE id = $1 FOR UPDATE`, key.WebhookID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lock webhook: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM webhook_signing_keys
+		WHERE webhook_id = $1 AND valid_until IS NOT NULL
+	`, key.WebhookID)
+	if err != nil {
+		return nil, fmt.Errorf("retire signing keys: %w", err)
+	}
+
+	previous := &SigningKey{}
+	err = tx.QueryRow(ctx, `
+		UPDATE webhook_signing_keys SET valid_until = GREATEST($2, valid_from)
+		WHERE webhook_id = $1 AND valid_until IS NULL
+		RETURNING id, webhook_id, secret, valid_from, valid_until, created_at
+	`, key.WebhookID, previousUntil).Scan(
+		&previous.ID, &previous.WebhookID, &previous.Secret,
+		&previous.ValidFrom, &previous.ValidUntil, &previous.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		previous = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("end signing key: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webhook_signing_keys (id, webhook_id, secret, valid_from, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, key.ID, key.WebhookID, key.Secret, key.ValidFrom, key.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("create signing key: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE webhooks SET secret = $2, updated_at = NOW() WHERE id = $1`, key.WebhookID, key.Secret); err != nil {
+		return nil, fmt.Errorf("update webhook: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit rotation: %w", err)
+	}
+
+	return previous, nil
+}
+
+// DeleteKey deletes a signing key of a webhook
+func (r *Repository) DeleteKey(ctx context.Context, webhookID, keyID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM webhook_signing_keys WHERE id = $1 AND webhook_id = $2`, keyID, webhookID)
+	if err != nil {
+		return fmt.Errorf("delete signing key: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrSigningKeyNotFound
+	}
+
+	return nil
+}
+
+// DeleteExpiredKeys deletes the signing keys whose overlap period has ended
+func (r *Repository) DeleteExpiredKeys(ctx context.Context) (int64, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM webhook_signing_keys WHERE valid_until <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired signing keys: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
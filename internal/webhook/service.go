@@ -7,6 +7,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -18,11 +19,12 @@ import (
 
 // Event types
 const (
-	EventNewDocument     = "new_document"
-	EventDeadlineWarning = "deadline_warning"
-	EventFBChange        = "fb_change"
-	EventSyncComplete    = "sync_complete"
-	EventDocumentRead    = "document_read"
+	EventNewDocument       = "new_document"
+	EventDeadlineWarning   = "deadline_warning"
+	EventFBChange          = "fb_change"
+	EventSyncComplete      = "sync_complete"
+	EventDocumentRead      = "document_read"
+	EventPartnerUIDInvalid = "partner_uid_invalid"
 )
 
 // Event represents a webhook event payload
@@ -126,6 +128,13 @@ func (s *Service) TriggerEvent(ctx context.Context, tenantID uuid.UUID, eventTyp
 
 // ProcessPendingDeliveries processes pending webhook deliveries
 func (s *Service) ProcessPendingDeliveries(ctx context.Context, batchSize int) (int, error) {
+	// Retire signing keys whose overlap period has ended
+	if n, err := s.repo.DeleteExpiredKeys(ctx); err != nil {
+		s.logger.Warn("failed to delete expired signing keys", "error", err)
+	} else if n > 0 {
+		s.logger.Info("expired webhook signing keys deleted", "count", n)
+	}
+
 	deliveries, err := s.repo.GetPendingDeliveries(ctx, batchSize)
 	if err != nil {
 		return 0, fmt.Errorf("get pending deliveries: %w", err)
@@ -169,9 +178,16 @@ func (s *Service) deliverWebhook(ctx context.Context, d *Delivery) error {
 	req.Header.Set("X-Delivery-ID", d.ID.String())
 	req.Header.Set("X-Event-Type", d.EventType)
 
-	// Add signature
-	signature := s.generateSignature(payloadBytes, wh.Secret)
-	req.Header.Set("X-Webhook-Signature", signature)
+	// Sign with every valid key, so that consumers verify deliveries with
+	// the old or the new secret during the overlap period of a rotation
+	keys, err := s.repo.ActiveKeys(ctx, wh.ID)
+	if err != nil {
+		return fmt.Errorf("get signing keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return s.handleDeliveryError(ctx, d, wh, errors.New("webhook has no valid signing key"), nil)
+	}
+	SignRequest(req, payloadBytes, keys)
 
 	// Add custom headers
 	for k, v := range wh.Headers {
@@ -252,13 +268,6 @@ func (s *Service) handleDeliveryError(ctx context.Context, d *Delivery, wh *Webh
 	return err
 }
 
-// generateSignature generates HMAC-SHA256 signature for the payload
-func (s *Service) generateSignature(payload []byte, secret string) string {
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write(payload)
-	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
-}
-
 // VerifySignature verifies a webhook signature
 func VerifySignature(payload []byte, signature, secret string) bool {
 	expected := "sha256=" + hex.EncodeToString(hmacSHA256(payload, secret))
@@ -1,13 +1,23 @@
 package websocket
 
 import (
+	"context"
+	"log/slog"
+
 	"github.com/google/uuid"
 	"austrian-business-infrastructure/internal/document"
 )
 
 // Broadcaster provides methods to broadcast events to connected clients
 type Broadcaster struct {
-	hub *Hub
+	hub   *Hub
+	prefs InAppPreferences
+}
+
+// InAppPreferences returns the users of a tenant who disabled in-app
+// notifications of a type; notification.Service implements it
+type InAppPreferences interface {
+	InAppOptOuts(ctx context.Context, tenantID uuid.UUID, notificationType string) (map[uuid.UUID]bool, error)
 }
 
 // NewBroadcaster creates a new broadcaster
@@ -15,6 +25,11 @@ func NewBroadcaster(hub *Hub) *Broadcaster {
 	return &Broadcaster{hub: hub}
 }
 
+// SetPreferences skips notifications for users who disabled them in-app
+func (b *Broadcaster) SetPreferences(prefs InAppPreferences) {
+	b.prefs = prefs
+}
+
 // BroadcastNewDocument broadcasts a new document event
 func (b *Broadcaster) BroadcastNewDocument(tenantID uuid.UUID, doc *document.Document) {
 	if b.hub == nil {
@@ -88,12 +103,23 @@ func (b *Broadcaster) BroadcastSyncFailed(tenantID, jobID uuid.UUID, accountID *
 	b.hub.Broadcast(tenantID, event)
 }
 
-// BroadcastNotification broadcasts a notification
+// BroadcastNotification broadcasts a notification to the users of a tenant
+// who did not disable its type in-app
 func (b *Broadcaster) BroadcastNotification(tenantID uuid.UUID, notificationID uuid.UUID, notificationType, title, message string) {
 	if b.hub == nil {
 		return
 	}
 
+	var exclude map[uuid.UUID]bool
+	if b.prefs != nil {
+		var err error
+		exclude, err = b.prefs.InAppOptOuts(context.Background(), tenantID, notificationType)
+		if err != nil {
+			// Without the preferences nobody is skipped
+			slog.Warn("failed to load in-app notification preferences", "tenant_id", tenantID, "error", err)
+		}
+	}
+
 	event := NotificationEvent(&NotificationData{
 		ID:      notificationID,
 		Type:    notificationType,
@@ -101,5 +127,5 @@ func (b *Broadcaster) BroadcastNotification(tenantID uuid.UUID, notificationID u
 		Message: message,
 	})
 
-	b.hub.Broadcast(tenantID, event)
+	b.hub.BroadcastExcept(tenantID, event, exclude)
 }
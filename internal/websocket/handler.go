@@ -232,5 +232,15 @@ func (h *Handler) validateToken(token string) (tenantID, userID string) {
 		return "", ""
 	}
 
+	// Break-glass access is checked per request; a connection would
+	// outlive its grant
+	if claims.BreakGlass != "" {
+		return "", ""
+	}
+	// Tokens only good for setting up 2FA open no connection
+	if claims.Enroll2FA {
+		return "", ""
+	}
+
 	return claims.TenantID, claims.UserID
 }
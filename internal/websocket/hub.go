@@ -36,6 +36,7 @@ type Hub struct {
 type BroadcastMessage struct {
 	TenantID uuid.UUID
 	Event    *Event
+	Exclude  map[uuid.UUID]bool // users who do not receive the event
 }
 
 // NewHub creates a new WebSocket hub
@@ -89,8 +90,14 @@ func (h *Hub) Unregister(client *Client) {
 
 // Broadcast sends an event to all clients of a tenant
 func (h *Hub) Broadcast(tenantID uuid.UUID, event *Event) {
+	h.BroadcastExcept(tenantID, event, nil)
+}
+
+// BroadcastExcept sends an event to all clients of a tenant except those of
+// the excluded users
+func (h *Hub) BroadcastExcept(tenantID uuid.UUID, event *Event, exclude map[uuid.UUID]bool) {
 	select {
-	case h.broadcast <- &BroadcastMessage{TenantID: tenantID, Event: event}:
+	case h.broadcast <- &BroadcastMessage{TenantID: tenantID, Event: event, Exclude: exclude}:
 	default:
 		h.logger.Warn("broadcast channel full, dropping message",
 			"tenant_id", tenantID,
@@ -147,7 +154,9 @@ func (h *Hub) broadcastToTenant(message *BroadcastMessage) {
 	// Copy slice to avoid holding lock during send
 	clientList := make([]*Client, 0, len(clients))
 	for client := range clients {
-		clientList = append(clientList, client)
+		if !message.Exclude[client.UserID] {
+			clientList = append(clientList, client)
+		}
 	}
 	h.mu.RUnlock()
 
@@ -0,0 +1,216 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Definition describes a step-based process: its states, the transitions
+// between them and the timers and SLAs of the states. Modules define
+// theirs in code; tenants can add their own.
+type Definition struct {
+	Key         string       `json:"key"`
+	Name        string       `json:"name"`
+	Initial     string       `json:"initial"`
+	States      []State      `json:"states"`
+	Transitions []Transition `json:"transitions"`
+	Builtin     bool         `json:"builtin,omitempty"`
+}
+
+// State is a step of a workflow. A workflow in a final state is completed.
+type State struct {
+	Name  string `json:"name"`
+	Label string `json:"label,omitempty"`
+	Final bool   `json:"final,omitempty"`
+	Timer *Timer `json:"timer,omitempty"`
+	SLA   *SLA   `json:"sla,omitempty"`
+}
+
+// Timer fires a transition when a state was not left within Hours
+type Timer struct {
+	Hours      int    `json:"hours"`
+	Transition string `json:"transition"`
+}
+
+// SLA is the time within which a state should be left. A workflow that
+// exceeds it is escalated once per state: reassigned to EscalateTo, if set,
+// and its assignee and the tenant admins are mailed.
+type SLA struct {
+	Hours      int        `json:"hours"`
+	EscalateTo *uuid.UUID `json:"escalate_to,omitempty"`
+}
+
+// Transition moves a workflow from one of the From states to To. MinRole
+// is the lowest user role allowed to perform it (viewer, member, admin,
+// owner); empty allows everyone.
+type Transition struct {
+	Name    string   `json:"name"`
+	Label   string   `json:"label,omitempty"`
+	From    []string `json:"from"`
+	To      string   `json:"to"`
+	MinRole string   `json:"min_role,omitempty"`
+}
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,99}$`)
+
+// roleLevels ranks the user roles like the auth middleware
+var roleLevels = map[string]int{
+	"viewer": 1,
+	"member": 2,
+	"admin":  3,
+	"owner":  4,
+}
+
+// Validate checks that a definition is complete and consistent
+func (d *Definition) Validate() error {
+	if !keyPattern.MatchString(d.Key) {
+		return fmt.Errorf("%w: key must be lower case letters, digits and underscores", ErrInvalidDefinition)
+	}
+	if d.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidDefinition)
+	}
+
+	states := make(map[string]*State, len(d.States))
+	for i := range d.States {
+		st := &d.States[i]
+		if !keyPattern.MatchString(st.Name) {
+			return fmt.Errorf("%w: invalid state name %q", ErrInvalidDefinition, st.Name)
+		}
+		if states[st.Name] != nil {
+			return fmt.Errorf("%w: state %s defined twice", ErrInvalidDefinition, st.Name)
+		}
+		if st.Final && (st.Timer != nil || st.SLA != nil) {
+			return fmt.Errorf("%w: final state %s cannot have a timer or SLA", ErrInvalidDefinition, st.Name)
+		}
+		if st.Timer != nil && st.Timer.Hours <= 0 || st.SLA != nil && st.SLA.Hours <= 0 {
+			return fmt.Errorf("%w: timers and SLAs of state %s need positive hours", ErrInvalidDefinition, st.Name)
+		}
+		states[st.Name] = st
+	}
+	if states[d.Initial] == nil {
+		return fmt.Errorf("%w: initial state %q is not defined", ErrInvalidDefinition, d.Initial)
+	}
+
+	names := make(map[string]bool, len(d.Transitions))
+	for _, t := range d.Transitions {
+		if !keyPattern.MatchString(t.Name) {
+			return fmt.Errorf("%w: invalid transition name %q", ErrInvalidDefinition, t.Name)
+		}
+		if names[t.Name] {
+			return fmt.Errorf("%w: transition %s defined twice", ErrInvalidDefinition, t.Name)
+		}
+		names[t.Name] = true
+		if states[t.To] == nil {
+			return fmt.Errorf("%w: transition %s leads to unknown state %q", ErrInvalidDefinition, t.Name, t.To)
+		}
+		if len(t.From) == 0 {
+			return fmt.Errorf("%w: transition %s has no source state", ErrInvalidDefinition, t.Name)
+		}
+		for _, from := range t.From {
+			st := states[from]
+			if st == nil {
+				return fmt.Errorf("%w: transition %s starts in unknown state %q", ErrInvalidDefinition, t.Name, from)
+			}
+			if st.Final {
+				return fmt.Errorf("%w: transition %s leaves final state %s", ErrInvalidDefinition, t.Name, from)
+			}
+		}
+		if _, ok := roleLevels[t.MinRole]; t.MinRole != "" && !ok {
+			return fmt.Errorf("%w: transition %s has unknown role %q", ErrInvalidDefinition, t.Name, t.MinRole)
+		}
+	}
+
+	for _, st := range states {
+		if st.Timer != nil {
+			if _, err := d.Transition(st.Name, st.Timer.Transition); err != nil {
+				return fmt.Errorf("%w: timer of state %s fires %q, which does not leave it", ErrInvalidDefinition, st.Name, st.Timer.Transition)
+			}
+		}
+	}
+	return nil
+}
+
+// State returns a state of the definition
+func (d *Definition) State(name string) (*State, bool) {
+	for i := range d.States {
+		if d.States[i].Name == name {
+			return &d.States[i], true
+		}
+	}
+	return nil, false
+}
+
+// Transition returns the transition of that name leaving a state
+func (d *Definition) Transition(from, name string) (*Transition, error) {
+	if _, ok := d.State(from); !ok {
+		return nil, ErrUnknownState
+	}
+	for i := range d.Transitions {
+		t := &d.Transitions[i]
+		if t.Name != name {
+			continue
+		}
+		for _, f := range t.From {
+			if f == from {
+				return t, nil
+			}
+		}
+	}
+	return nil, ErrInvalidTransition
+}
+
+// Available returns the names of the transitions leaving a state
+func (d *Definition) Available(from string) []string {
+	var names []string
+	for _, t := range d.Transitions {
+		for _, f := range t.From {
+			if f == from {
+				names = append(names, t.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// Permits reports whether a user role may perform a transition
+func (t *Transition) Permits(role string) bool {
+	if t.MinRole == "" {
+		return true
+	}
+	return roleLevels[role] >= roleLevels[t.MinRole]
+}
+
+// Enter moves an instance into a state at now: the timer and SLA of the
+// state start, an escalation of the previous state is cleared and a final
+// state completes the instance
+func Enter(inst *Instance, st *State, now time.Time) {
+	inst.State = st.Name
+	inst.StateEnteredAt = now
+	inst.TimerAt, inst.DueAt, inst.EscalatedAt, inst.CompletedAt = nil, nil, nil, nil
+	if st.Timer != nil {
+		at := now.Add(time.Duration(st.Timer.Hours) * time.Hour)
+		inst.TimerAt = &at
+	}
+	if st.SLA != nil {
+		due := now.Add(time.Duration(st.SLA.Hours) * time.Hour)
+		inst.DueAt = &due
+	}
+	if st.Final {
+		inst.CompletedAt = &now
+	}
+}
+
+// TimerDue reports whether the timer of an instance's state has expired
+func TimerDue(inst *Instance, now time.Time) bool {
+	return inst.CompletedAt == nil && inst.TimerAt != nil && !now.Before(*inst.TimerAt)
+}
+
+// Overdue reports whether an instance exceeded the SLA of its state and has
+// not been escalated yet
+func Overdue(inst *Instance, now time.Time) bool {
+	return inst.CompletedAt == nil && inst.DueAt != nil && inst.EscalatedAt == nil && !now.Before(*inst.DueAt)
+}
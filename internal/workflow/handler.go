@@ -0,0 +1,312 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+// Handler handles workflow HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new workflow handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the workflow routes. Definitions are managed by
+// admins; who may perform a transition is part of its definition.
+func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin func(http.Handler) http.Handler) {
+	router.Handle("GET /api/v1/workflows/definitions", requireAuth(http.HandlerFunc(h.ListDefinitions)))
+	router.Handle("GET /api/v1/workflows/definitions/{key}", requireAuth(http.HandlerFunc(h.GetDefinition)))
+	router.Handle("PUT /api/v1/workflows/definitions/{key}", requireAuth(requireAdmin(http.HandlerFunc(h.SaveDefinition))))
+	router.Handle("DELETE /api/v1/workflows/definitions/{key}", requireAuth(requireAdmin(http.HandlerFunc(h.DeleteDefinition))))
+	router.Handle("GET /api/v1/workflows", requireAuth(http.HandlerFunc(h.List)))
+	router.Handle("POST /api/v1/workflows", requireAuth(http.HandlerFunc(h.Start)))
+	router.Handle("GET /api/v1/workflows/{id}", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("POST /api/v1/workflows/{id}/transitions", requireAuth(http.HandlerFunc(h.Fire)))
+	router.Handle("PUT /api/v1/workflows/{id}/assignee", requireAuth(http.HandlerFunc(h.Assign)))
+}
+
+// ListDefinitions handles GET /api/v1/workflows/definitions
+func (h *Handler) ListDefinitions(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	defs, err := h.service.ListDefinitions(r.Context(), tenantID)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{"items": defs})
+}
+
+// GetDefinition handles GET /api/v1/workflows/definitions/{key}
+func (h *Handler) GetDefinition(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	d, err := h.service.GetDefinition(r.Context(), tenantID, r.PathValue("key"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, d)
+}
+
+// SaveDefinition handles PUT /api/v1/workflows/definitions/{key}
+func (h *Handler) SaveDefinition(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.RequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var d Definition
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+	d.Key = r.PathValue("key")
+
+	saved, err := h.service.SaveDefinition(r.Context(), tenantID, userID, &d)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, saved)
+}
+
+// DeleteDefinition handles DELETE /api/v1/workflows/definitions/{key}
+func (h *Handler) DeleteDefinition(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteDefinition(r.Context(), tenantID, r.PathValue("key")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// List handles GET /api/v1/workflows
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	filter := ListFilter{
+		TenantID: tenantID,
+		Open:     q.Get("open") == "true",
+		Overdue:  q.Get("overdue") == "true",
+		Limit:    50,
+	}
+	if v := q.Get("definition"); v != "" {
+		filter.DefinitionKey = &v
+	}
+	if v := q.Get("state"); v != "" {
+		filter.State = &v
+	}
+	if v := q.Get("subject_type"); v != "" {
+		filter.SubjectType = &v
+	}
+	if v := q.Get("subject_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			api.BadRequest(w, "invalid subject_id")
+			return
+		}
+		filter.SubjectID = &id
+	}
+	if v := q.Get("assignee_id"); v == "me" {
+		userID, ok := api.RequestUser(w, r)
+		if !ok {
+			return
+		}
+		filter.AssigneeID = &userID
+	} else if v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			api.BadRequest(w, "invalid assignee_id")
+			return
+		}
+		filter.AssigneeID = &id
+	}
+	if limitStr := q.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := q.Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	list, total, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		api.InternalError(w)
+		return
+	}
+	if list == nil {
+		list = []*Instance{}
+	}
+
+	api.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"items":  list,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// Start handles POST /api/v1/workflows
+func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.RequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var input StartInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	inst, err := h.service.Start(r.Context(), tenantID, userID, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, inst)
+}
+
+// Get handles GET /api/v1/workflows/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	inst, err := h.service.Get(r.Context(), tenantID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, inst)
+}
+
+// Fire handles POST /api/v1/workflows/{id}/transitions
+func (h *Handler) Fire(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.RequestUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Transition string `json:"transition"`
+		Note       string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	inst, err := h.service.Fire(r.Context(), tenantID, id, userID, api.GetUserRole(r.Context()), req.Transition, req.Note)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, inst)
+}
+
+// Assign handles PUT /api/v1/workflows/{id}/assignee
+func (h *Handler) Assign(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := api.RequestTenant(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := api.RequestUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := api.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		AssigneeID *uuid.UUID `json:"assignee_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	inst, err := h.service.Assign(r.Context(), tenantID, id, userID, api.GetUserRole(r.Context()), req.AssigneeID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, inst)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrDefinitionNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrInstanceNotFound):
+		api.NotFound(w, err.Error())
+	case errors.Is(err, ErrForbidden):
+		api.Forbidden(w, err.Error())
+	case errors.Is(err, ErrBuiltinDefinition), errors.Is(err, ErrCompleted), errors.Is(err, ErrConflict):
+		api.Conflict(w, err.Error())
+	case errors.Is(err, ErrInvalidDefinition), errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrUnknownState),
+		errors.Is(err, ErrAssigneeNotFound):
+		api.JSONError(w, http.StatusUnprocessableEntity, err.Error(), api.ErrCodeValidation)
+	default:
+		api.InternalError(w)
+	}
+}
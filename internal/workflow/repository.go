@@ -0,0 +1,354 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository handles workflow database operations
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new workflow repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// ListDefinitions returns the workflow definitions of a tenant
+func (r *Repository) ListDefinitions(ctx context.Context, tenantID uuid.UUID) ([]*Definition, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT definition FROM workflow_definitions WHERE tenant_id = $1 ORDER BY name`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflow definitions: %w", err)
+	}
+	defer rows.Close()
+
+	var defs []*Definition
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow definition: %w", err)
+		}
+		var d Definition
+		if err := json.Unmarshal(data, &d); err != nil {
+			return nil, fmt.Errorf("invalid workflow definition: %w", err)
+		}
+		defs = append(defs, &d)
+	}
+	return defs, rows.Err()
+}
+
+// GetDefinition returns a workflow definition of a tenant
+func (r *Repository) GetDefinition(ctx context.Context, tenantID uuid.UUID, key string) (*Definition, error) {
+	var data []byte
+	err := r.db.QueryRow(ctx, `
+		SELECT definition FROM workflow_definitions WHERE tenant_id = $1 AND key = $2`, tenantID, key).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDefinitionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow definition: %w", err)
+	}
+	var d Definition
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("invalid workflow definition: %w", err)
+	}
+	return &d, nil
+}
+
+// SaveDefinition creates or replaces a workflow definition of a tenant
+func (r *Repository) SaveDefinition(ctx context.Context, tenantID, userID uuid.UUID, d *Definition) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("failed to save workflow definition: %w", err)
+	}
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO workflow_definitions (tenant_id, key, name, definition, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, key) DO UPDATE SET
+			name = EXCLUDED.name, definition = EXCLUDED.definition, updated_at = NOW()`,
+		tenantID, d.Key, d.Name, data, userID)
+	if err != nil {
+		return fmt.Errorf("failed to save workflow definition: %w", err)
+	}
+	return nil
+}
+
+// DeleteDefinition removes a workflow definition of a tenant. Running
+// instances keep their copy of it.
+func (r *Repository) DeleteDefinition(ctx context.Context, tenantID uuid.UUID, key string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM workflow_definitions WHERE tenant_id = $1 AND key = $2`, tenantID, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete workflow definition: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDefinitionNotFound
+	}
+	return nil
+}
+
+const instanceColumns = `id, tenant_id, definition_key, definition, subject_type, subject_id, title, state,
+	assignee_id, state_entered_at, timer_at, due_at, escalated_at, completed_at, created_by, created_at, updated_at`
+
+func scanInstance(row pgx.Row) (*Instance, error) {
+	var inst Instance
+	var data []byte
+	if err := row.Scan(&inst.ID, &inst.TenantID, &inst.DefinitionKey, &data, &inst.SubjectType, &inst.SubjectID,
+		&inst.Title, &inst.State, &inst.AssigneeID, &inst.StateEnteredAt, &inst.TimerAt, &inst.DueAt,
+		&inst.EscalatedAt, &inst.CompletedAt, &inst.CreatedBy, &inst.CreatedAt, &inst.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &inst.Definition); err != nil {
+		return nil, fmt.Errorf("invalid workflow definition: %w", err)
+	}
+	return &inst, nil
+}
+
+// CreateInstance inserts a workflow with the event that started it
+func (r *Repository) CreateInstance(ctx context.Context, inst *Instance, ev *Event) error {
+	data, err := json.Marshal(inst.Definition)
+	if err != nil {
+		return fmt.Errorf("failed to create workflow: %w", err)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	saved, err := scanInstance(tx.QueryRow(ctx, `
+		INSERT INTO workflow_instances (tenant_id, definition_key, definition, subject_type, subject_id, title,
+			state, assignee_id, state_entered_at, timer_at, due_at, completed_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING `+instanceColumns,
+		inst.TenantID, inst.DefinitionKey, data, inst.SubjectType, inst.SubjectID, inst.Title,
+		inst.State, inst.AssigneeID, inst.StateEnteredAt, inst.TimerAt, inst.DueAt, inst.CompletedAt, inst.CreatedBy))
+	if err != nil {
+		return fmt.Errorf("failed to create workflow: %w", err)
+	}
+	ev.InstanceID = saved.ID
+	if err := insertEvent(ctx, tx, ev); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit workflow: %w", err)
+	}
+	*inst = *saved
+	return nil
+}
+
+// UpdateInstance stores the changed state and assignment of a workflow with
+// the event that changed them. It fails with ErrConflict if the workflow
+// left the state it was loaded in.
+func (r *Repository) UpdateInstance(ctx context.Context, inst *Instance, fromState string, enteredAt time.Time, ev *Event) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	saved, err := scanInstance(tx.QueryRow(ctx, `
+		UPDATE workflow_instances SET
+			state = $5, assignee_id = $6, state_entered_at = $7, timer_at = $8, due_at = $9,
+			escalated_at = $10, completed_at = $11, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND state = $3 AND state_entered_at = $4
+		RETURNING `+instanceColumns,
+		inst.ID, inst.TenantID, fromState, enteredAt,
+		inst.State, inst.AssigneeID, inst.StateEnteredAt, inst.TimerAt, inst.DueAt, inst.EscalatedAt, inst.CompletedAt))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update workflow: %w", err)
+	}
+	ev.InstanceID = saved.ID
+	if err := insertEvent(ctx, tx, ev); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit workflow: %w", err)
+	}
+	*inst = *saved
+	return nil
+}
+
+func insertEvent(ctx context.Context, tx pgx.Tx, ev *Event) error {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO workflow_events (instance_id, kind, transition, from_state, to_state, assignee_id, actor_id, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`,
+		ev.InstanceID, ev.Kind, ev.Transition, ev.FromState, ev.ToState, ev.AssigneeID, ev.ActorID, ev.Note,
+	).Scan(&ev.ID, &ev.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record workflow event: %w", err)
+	}
+	return nil
+}
+
+// GetInstance returns a workflow of a tenant
+func (r *Repository) GetInstance(ctx context.Context, tenantID, id uuid.UUID) (*Instance, error) {
+	inst, err := scanInstance(r.db.QueryRow(ctx,
+		`SELECT `+instanceColumns+` FROM workflow_instances WHERE id = $1 AND tenant_id = $2`, id, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInstanceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+	return inst, nil
+}
+
+// ListEvents returns the history of a workflow, oldest first
+func (r *Repository) ListEvents(ctx context.Context, instanceID uuid.UUID) ([]*Event, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, instance_id, kind, transition, from_state, to_state, assignee_id, actor_id, note, created_at
+		FROM workflow_events WHERE instance_id = $1 ORDER BY created_at, id`, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflow events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		var ev Event
+		if err := rows.Scan(&ev.ID, &ev.InstanceID, &ev.Kind, &ev.Transition, &ev.FromState, &ev.ToState,
+			&ev.AssigneeID, &ev.ActorID, &ev.Note, &ev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow event: %w", err)
+		}
+		events = append(events, &ev)
+	}
+	return events, rows.Err()
+}
+
+// ListInstances returns the workflows of a tenant, the most recently
+// changed first
+func (r *Repository) ListInstances(ctx context.Context, filter ListFilter) ([]*Instance, int, error) {
+	where := "tenant_id = $1"
+	args := []interface{}{filter.TenantID}
+	if filter.DefinitionKey != nil {
+		args = append(args, *filter.DefinitionKey)
+		where += fmt.Sprintf(" AND definition_key = $%d", len(args))
+	}
+	if filter.State != nil {
+		args = append(args, *filter.State)
+		where += fmt.Sprintf(" AND state = $%d", len(args))
+	}
+	if filter.AssigneeID != nil {
+		args = append(args, *filter.AssigneeID)
+		where += fmt.Sprintf(" AND assignee_id = $%d", len(args))
+	}
+	if filter.SubjectType != nil {
+		args = append(args, *filter.SubjectType)
+		where += fmt.Sprintf(" AND subject_type = $%d", len(args))
+	}
+	if filter.SubjectID != nil {
+		args = append(args, *filter.SubjectID)
+		where += fmt.Sprintf(" AND subject_id = $%d", len(args))
+	}
+	if filter.Open || filter.Overdue {
+		where += " AND completed_at IS NULL"
+	}
+	if filter.Overdue {
+		where += " AND due_at <= NOW()"
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM workflow_instances WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count workflows: %w", err)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT %s FROM workflow_instances
+		WHERE %s
+		ORDER BY updated_at DESC
+		LIMIT $%d OFFSET $%d`, instanceColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list workflows: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*Instance
+	for rows.Next() {
+		inst, err := scanInstance(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list workflows: %w", err)
+		}
+		list = append(list, inst)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list workflows: %w", err)
+	}
+	return list, total, nil
+}
+
+// ListDue returns open workflows of all tenants whose timer expired or
+// that exceeded their SLA without having been escalated
+func (r *Repository) ListDue(ctx context.Context, now time.Time, limit int) ([]*Instance, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+instanceColumns+` FROM workflow_instances
+		WHERE completed_at IS NULL
+		AND (timer_at <= $1 OR (due_at <= $1 AND escalated_at IS NULL))
+		ORDER BY LEAST(timer_at, due_at)
+		LIMIT $2`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due workflows: %w", err)
+	}
+	defer rows.Close()
+
+	var list []*Instance
+	for rows.Next() {
+		inst, err := scanInstance(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list due workflows: %w", err)
+		}
+		list = append(list, inst)
+	}
+	return list, rows.Err()
+}
+
+// GetUser returns an active user of a tenant
+func (r *Repository) GetUser(ctx context.Context, tenantID, userID uuid.UUID) (*Recipient, error) {
+	var u Recipient
+	err := r.db.QueryRow(ctx, `
+		SELECT id, name, email FROM users WHERE id = $1 AND tenant_id = $2 AND is_active`,
+		userID, tenantID).Scan(&u.ID, &u.Name, &u.Email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAssigneeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &u, nil
+}
+
+// ListAdmins returns the active owners and admins of a tenant
+func (r *Repository) ListAdmins(ctx context.Context, tenantID uuid.UUID) ([]*Recipient, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, name, email FROM users
+		WHERE tenant_id = $1 AND role IN ('owner', 'admin') AND is_active
+		ORDER BY email`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant admins: %w", err)
+	}
+	defer rows.Close()
+
+	var admins []*Recipient
+	for rows.Next() {
+		var a Recipient
+		if err := rows.Scan(&a.ID, &a.Name, &a.Email); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant admin: %w", err)
+		}
+		admins = append(admins, &a)
+	}
+	return admins, rows.Err()
+}
@@ -0,0 +1,242 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Hook is called after a workflow of a built-in definition changed its
+// state, so the module owning it can follow up. Timers fire in the worker,
+// so register built-ins with hooks there as well.
+type Hook func(ctx context.Context, inst *Instance, ev *Event) error
+
+// Service runs workflows: the step-based processes shared by modules
+// instead of each keeping its own state machine, timers and escalations
+type Service struct {
+	repo     *Repository
+	builtins map[string]*Definition
+	hooks    map[string]Hook
+	notifier EscalationNotifier
+	appURL   string
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewService creates a new workflow service
+func NewService(repo *Repository) *Service {
+	return &Service{
+		repo:     repo,
+		builtins: make(map[string]*Definition),
+		hooks:    make(map[string]Hook),
+		logger:   slog.Default(),
+		now:      time.Now,
+	}
+}
+
+// Register adds the built-in definition of a module, available to all
+// tenants. The hook may be nil.
+func (s *Service) Register(d *Definition, hook Hook) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	if _, ok := s.builtins[d.Key]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateDefinition, d.Key)
+	}
+	d.Builtin = true
+	s.builtins[d.Key] = d
+	if hook != nil {
+		s.hooks[d.Key] = hook
+	}
+	return nil
+}
+
+// ListDefinitions returns the built-in definitions and those of a tenant
+func (s *Service) ListDefinitions(ctx context.Context, tenantID uuid.UUID) ([]*Definition, error) {
+	defs := make([]*Definition, 0, len(s.builtins))
+	for _, d := range s.builtins {
+		defs = append(defs, d)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+
+	own, err := s.repo.ListDefinitions(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return append(defs, own...), nil
+}
+
+// GetDefinition returns a built-in definition or one of a tenant
+func (s *Service) GetDefinition(ctx context.Context, tenantID uuid.UUID, key string) (*Definition, error) {
+	if d, ok := s.builtins[key]; ok {
+		return d, nil
+	}
+	return s.repo.GetDefinition(ctx, tenantID, key)
+}
+
+// SaveDefinition creates or replaces a definition of a tenant. Workflows
+// already running keep the definition they were started with.
+func (s *Service) SaveDefinition(ctx context.Context, tenantID, userID uuid.UUID, d *Definition) (*Definition, error) {
+	d.Builtin = false
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	if _, ok := s.builtins[d.Key]; ok {
+		return nil, ErrBuiltinDefinition
+	}
+	for _, st := range d.States {
+		if st.SLA != nil && st.SLA.EscalateTo != nil {
+			if _, err := s.repo.GetUser(ctx, tenantID, *st.SLA.EscalateTo); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := s.repo.SaveDefinition(ctx, tenantID, userID, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// DeleteDefinition removes a definition of a tenant
+func (s *Service) DeleteDefinition(ctx context.Context, tenantID uuid.UUID, key string) error {
+	if _, ok := s.builtins[key]; ok {
+		return ErrBuiltinDefinition
+	}
+	return s.repo.DeleteDefinition(ctx, tenantID, key)
+}
+
+// Start starts a workflow in the initial state of its definition
+func (s *Service) Start(ctx context.Context, tenantID, userID uuid.UUID, input *StartInput) (*Instance, error) {
+	d, err := s.GetDefinition(ctx, tenantID, input.DefinitionKey)
+	if err != nil {
+		return nil, err
+	}
+	if input.AssigneeID != nil {
+		if _, err := s.repo.GetUser(ctx, tenantID, *input.AssigneeID); err != nil {
+			return nil, err
+		}
+	}
+	initial, _ := d.State(d.Initial)
+
+	title := strings.TrimSpace(input.Title)
+	if title == "" {
+		title = d.Name
+	}
+	inst := &Instance{
+		TenantID:      tenantID,
+		DefinitionKey: d.Key,
+		Definition:    d,
+		SubjectType:   input.SubjectType,
+		SubjectID:     input.SubjectID,
+		Title:         title,
+		AssigneeID:    input.AssigneeID,
+		CreatedBy:     &userID,
+	}
+	Enter(inst, initial, s.now())
+
+	ev := &Event{Kind: EventStart, ToState: &initial.Name, AssigneeID: input.AssigneeID, ActorID: &userID}
+	if err := s.repo.CreateInstance(ctx, inst, ev); err != nil {
+		return nil, err
+	}
+	s.runHook(ctx, inst, ev)
+	return inst, nil
+}
+
+// Get returns a workflow with its history and the transitions leaving its
+// state
+func (s *Service) Get(ctx context.Context, tenantID, id uuid.UUID) (*Instance, error) {
+	inst, err := s.repo.GetInstance(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	inst.Events, err = s.repo.ListEvents(ctx, inst.ID)
+	if err != nil {
+		return nil, err
+	}
+	inst.Transitions = inst.Definition.Available(inst.State)
+	return inst, nil
+}
+
+// List returns the workflows of a tenant
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]*Instance, int, error) {
+	return s.repo.ListInstances(ctx, filter)
+}
+
+// Fire performs a transition of a workflow on behalf of a user with the
+// given role
+func (s *Service) Fire(ctx context.Context, tenantID, id, userID uuid.UUID, role, transition, note string) (*Instance, error) {
+	inst, err := s.repo.GetInstance(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if inst.CompletedAt != nil {
+		return nil, ErrCompleted
+	}
+	t, err := inst.Definition.Transition(inst.State, transition)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Permits(role) {
+		return nil, ErrForbidden
+	}
+	return s.fire(ctx, inst, t, EventTransition, &userID, note)
+}
+
+func (s *Service) fire(ctx context.Context, inst *Instance, t *Transition, kind string, actorID *uuid.UUID, note string) (*Instance, error) {
+	from, enteredAt := inst.State, inst.StateEnteredAt
+	to, _ := inst.Definition.State(t.To)
+	Enter(inst, to, s.now())
+
+	ev := &Event{Kind: kind, Transition: &t.Name, FromState: &from, ToState: &to.Name, ActorID: actorID}
+	if note = strings.TrimSpace(note); note != "" {
+		ev.Note = &note
+	}
+	if err := s.repo.UpdateInstance(ctx, inst, from, enteredAt, ev); err != nil {
+		return nil, err
+	}
+	s.runHook(ctx, inst, ev)
+	return inst, nil
+}
+
+// Assign hands a workflow to a user of the tenant, or unassigns it with a
+// nil assignee. Admins may reassign any workflow, others only those
+// unassigned or assigned to them.
+func (s *Service) Assign(ctx context.Context, tenantID, id, userID uuid.UUID, role string, assigneeID *uuid.UUID) (*Instance, error) {
+	inst, err := s.repo.GetInstance(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if inst.CompletedAt != nil {
+		return nil, ErrCompleted
+	}
+	if roleLevels[role] < roleLevels["admin"] && inst.AssigneeID != nil && *inst.AssigneeID != userID {
+		return nil, ErrForbidden
+	}
+	if assigneeID != nil {
+		if _, err := s.repo.GetUser(ctx, tenantID, *assigneeID); err != nil {
+			return nil, err
+		}
+	}
+
+	inst.AssigneeID = assigneeID
+	ev := &Event{Kind: EventAssign, AssigneeID: assigneeID, ActorID: &userID}
+	if err := s.repo.UpdateInstance(ctx, inst, inst.State, inst.StateEnteredAt, ev); err != nil {
+		return nil, err
+	}
+	return inst, nil
+}
+
+func (s *Service) runHook(ctx context.Context, inst *Instance, ev *Event) {
+	hook, ok := s.hooks[inst.DefinitionKey]
+	if !ok {
+		return
+	}
+	if err := hook(ctx, inst, ev); err != nil {
+		s.logger.Error("workflow hook failed", "workflow_id", inst.ID, "definition", inst.DefinitionKey, "event", ev.Kind, "error", err)
+	}
+}
@@ -0,0 +1,130 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/timezone"
+)
+
+// TimerBatch is the number of due workflows handled per run of
+// ProcessTimers
+const TimerBatch = 200
+
+// EscalationNotifier mails the escalations of workflows past their SLA;
+// email.Service implements it
+type EscalationNotifier interface {
+	SendWorkflowEscalation(ctx context.Context, to string, params email.WorkflowEscalationParams) error
+}
+
+// SetNotifier enables the escalation mails; appURL is linked in them
+func (s *Service) SetNotifier(n EscalationNotifier, appURL string) {
+	s.notifier = n
+	s.appURL = appURL
+}
+
+// ProcessTimers fires the transitions of expired timers and escalates the
+// workflows past the SLA of their state. Run it every few minutes. It
+// returns the timers fired and the workflows escalated.
+func (s *Service) ProcessTimers(ctx context.Context) (int, int, error) {
+	now := s.now()
+	due, err := s.repo.ListDue(ctx, now, TimerBatch)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fired, escalated := 0, 0
+	var lastErr error
+	for _, inst := range due {
+		switch {
+		case TimerDue(inst, now):
+			st, _ := inst.Definition.State(inst.State)
+			t, err := inst.Definition.Transition(inst.State, st.Timer.Transition)
+			if err == nil {
+				_, err = s.fire(ctx, inst, t, EventTimer, nil, "")
+			}
+			if errors.Is(err, ErrConflict) {
+				continue // moved on in the meantime
+			}
+			if err != nil {
+				lastErr = fmt.Errorf("failed to fire timer of workflow %s: %w", inst.ID, err)
+				continue
+			}
+			fired++
+		case Overdue(inst, now):
+			err := s.escalate(ctx, inst)
+			if errors.Is(err, ErrConflict) {
+				continue
+			}
+			if err != nil {
+				lastErr = fmt.Errorf("failed to escalate workflow %s: %w", inst.ID, err)
+			}
+			escalated++
+		}
+	}
+	return fired, escalated, lastErr
+}
+
+// escalate marks a workflow escalated, hands it to the escalation target
+// of the SLA and mails its assignee and the tenant admins
+func (s *Service) escalate(ctx context.Context, inst *Instance) error {
+	st, _ := inst.Definition.State(inst.State)
+	now := s.now()
+	inst.EscalatedAt = &now
+	if st.SLA != nil && st.SLA.EscalateTo != nil {
+		if _, err := s.repo.GetUser(ctx, inst.TenantID, *st.SLA.EscalateTo); err == nil {
+			inst.AssigneeID = st.SLA.EscalateTo
+		}
+	}
+
+	ev := &Event{Kind: EventEscalate, AssigneeID: inst.AssigneeID}
+	if err := s.repo.UpdateInstance(ctx, inst, inst.State, inst.StateEnteredAt, ev); err != nil {
+		return err
+	}
+	if s.notifier == nil {
+		return nil
+	}
+
+	recipients, err := s.repo.ListAdmins(ctx, inst.TenantID)
+	if err != nil {
+		return err
+	}
+	params := email.WorkflowEscalationParams{
+		TenantID: &inst.TenantID,
+		Workflow: inst.Definition.Name,
+		Title:    inst.Title,
+		State:    st.Name,
+		Since:    inst.StateEnteredAt.In(timezone.Vienna).Format("02.01.2006 15:04"),
+	}
+	if st.Label != "" {
+		params.State = st.Label
+	}
+	if inst.DueAt != nil {
+		params.Due = inst.DueAt.In(timezone.Vienna).Format("02.01.2006 15:04")
+	}
+	if inst.AssigneeID != nil {
+		if assignee, err := s.repo.GetUser(ctx, inst.TenantID, *inst.AssigneeID); err == nil {
+			params.Assignee = assignee.Name
+			recipients = append([]*Recipient{assignee}, recipients...)
+		}
+	}
+	if s.appURL != "" {
+		params.URL = fmt.Sprintf("%s/workflows/%s", s.appURL, inst.ID)
+	}
+
+	mailed := make(map[string]bool)
+	var lastErr error
+	for _, r := range recipients {
+		if mailed[r.Email] {
+			continue
+		}
+		mailed[r.Email] = true
+		params.RecipientName = r.Name
+		if err := s.notifier.SendWorkflowEscalation(ctx, r.Email, params); err != nil {
+			lastErr = fmt.Errorf("failed to notify %s: %w", r.Email, err)
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,102 @@
+package workflow
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDefinitionNotFound  = errors.New("workflow definition not found")
+	ErrDuplicateDefinition = errors.New("a built-in workflow with this key exists")
+	ErrBuiltinDefinition   = errors.New("built-in workflows cannot be changed")
+	ErrInvalidDefinition   = errors.New("invalid workflow definition")
+	ErrInstanceNotFound    = errors.New("workflow not found")
+	ErrUnknownState        = errors.New("unknown workflow state")
+	ErrInvalidTransition   = errors.New("transition not allowed in this state")
+	ErrForbidden           = errors.New("not allowed for this role")
+	ErrCompleted           = errors.New("workflow is completed")
+	ErrConflict            = errors.New("workflow was changed in the meantime")
+	ErrAssigneeNotFound    = errors.New("assignee not found")
+)
+
+// Event kinds of the history of a workflow
+const (
+	EventStart      = "start"
+	EventTransition = "transition"
+	EventTimer      = "timer"
+	EventAssign     = "assign"
+	EventEscalate   = "escalate"
+)
+
+// Instance is a running or completed workflow. It keeps the definition it
+// was started with, so changing a definition does not break instances
+// already running.
+type Instance struct {
+	ID             uuid.UUID   `json:"id"`
+	TenantID       uuid.UUID   `json:"tenant_id"`
+	DefinitionKey  string      `json:"definition_key"`
+	Definition     *Definition `json:"-"`
+	SubjectType    *string     `json:"subject_type,omitempty"`
+	SubjectID      *uuid.UUID  `json:"subject_id,omitempty"`
+	Title          string      `json:"title"`
+	State          string      `json:"state"`
+	AssigneeID     *uuid.UUID  `json:"assignee_id,omitempty"`
+	StateEnteredAt time.Time   `json:"state_entered_at"`
+	TimerAt        *time.Time  `json:"timer_at,omitempty"`
+	DueAt          *time.Time  `json:"due_at,omitempty"`
+	EscalatedAt    *time.Time  `json:"escalated_at,omitempty"`
+	CompletedAt    *time.Time  `json:"completed_at,omitempty"`
+	CreatedBy      *uuid.UUID  `json:"created_by,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+
+	// Set on single instances
+	Transitions []string `json:"transitions,omitempty"`
+	Events      []*Event `json:"events,omitempty"`
+}
+
+// Event is an entry of the history of a workflow
+type Event struct {
+	ID         uuid.UUID  `json:"id"`
+	InstanceID uuid.UUID  `json:"instance_id"`
+	Kind       string     `json:"kind"`
+	Transition *string    `json:"transition,omitempty"`
+	FromState  *string    `json:"from_state,omitempty"`
+	ToState    *string    `json:"to_state,omitempty"`
+	AssigneeID *uuid.UUID `json:"assignee_id,omitempty"`
+	ActorID    *uuid.UUID `json:"actor_id,omitempty"` // nil for timers and escalations
+	Note       *string    `json:"note,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// StartInput contains input for starting a workflow
+type StartInput struct {
+	DefinitionKey string     `json:"definition"`
+	SubjectType   *string    `json:"subject_type,omitempty"`
+	SubjectID     *uuid.UUID `json:"subject_id,omitempty"`
+	Title         string     `json:"title"`
+	AssigneeID    *uuid.UUID `json:"assignee_id,omitempty"`
+}
+
+// ListFilter filters workflows
+type ListFilter struct {
+	TenantID      uuid.UUID
+	DefinitionKey *string
+	State         *string
+	AssigneeID    *uuid.UUID
+	SubjectType   *string
+	SubjectID     *uuid.UUID
+	Open          bool // not completed
+	Overdue       bool // open and past the SLA of the state
+	Limit         int
+	Offset        int
+}
+
+// Recipient is a user mailed on an escalation
+type Recipient struct {
+	ID    uuid.UUID
+	Name  string
+	Email string
+}
@@ -0,0 +1,448 @@
+// Package xlsx writes simple formatted Office Open XML spreadsheets: typed
+// cells, a fixed set of styles, frozen panes, merged cells and print
+// header and footer. Strings are stored inline, so no shared string table
+// is needed.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Style is the look of a cell
+type Style int
+
+// The styles of a workbook. Their order is the order of the cellXfs in
+// styles.xml.
+const (
+	StyleDefault  Style = iota
+	StyleTitle          // large bold text
+	StyleHeader         // bold white text on the accent color
+	StyleLabel          // bold text on light grey, for row labels
+	StyleText           // wrapped text
+	StyleCurrency       // whole amounts in the currency format
+	StylePercent        // fractions as whole percent
+	StyleDate           // dates in the date format
+	StyleNote           // small grey italic text
+	styleCount
+)
+
+// Cell is a cell value with its style. Value is a string, an integer, a
+// float, a time.Time or nil for an empty styled cell.
+type Cell struct {
+	Value interface{}
+	Style Style
+}
+
+// Text returns a text cell
+func Text(s string, style Style) Cell {
+	return Cell{Value: s, Style: style}
+}
+
+// Sheet is a worksheet
+type Sheet struct {
+	Name string
+	Rows [][]Cell
+	// ColumnWidths are in characters; missing columns use the default width
+	ColumnWidths []float64
+	// FreezeRows and FreezeColumns keep the top rows and left columns in
+	// view while scrolling
+	FreezeRows    int
+	FreezeColumns int
+	// Merges are cell ranges such as "A1:D1"
+	Merges []string
+	// Header and Footer are printed on every page
+	Header string
+	Footer string
+	// Landscape prints the sheet in landscape, scaled to the page width
+	Landscape bool
+}
+
+// Workbook is a spreadsheet with one or more sheets
+type Workbook struct {
+	Title   string
+	Creator string
+	Sheets  []*Sheet
+	// AccentColor is the fill of header cells as RRGGBB; default dark blue
+	AccentColor string
+	// CurrencyFormat and DateFormat are Excel number format codes
+	CurrencyFormat string
+	DateFormat     string
+	// Created defaults to the current time
+	Created time.Time
+}
+
+// MaxSheetNameLength is the longest sheet name Excel accepts
+const MaxSheetNameLength = 31
+
+// Defaults of a workbook
+const (
+	DefaultAccentColor    = "1F4E79"
+	DefaultCurrencyFormat = `#,##0 "€"`
+	DefaultDateFormat     = "DD.MM.YYYY"
+)
+
+// Write writes the workbook as .xlsx
+func (wb *Workbook) Write(w io.Writer) error {
+	if len(wb.Sheets) == 0 {
+		return errors.New("xlsx: workbook has no sheets")
+	}
+
+	zw := zip.NewWriter(w)
+	parts := []struct {
+		name    string
+		content []byte
+	}{
+		{"[Content_Types].xml", wb.contentTypes()},
+		{"_rels/.rels", []byte(rootRels)},
+		{"docProps/core.xml", wb.coreProperties()},
+		{"xl/workbook.xml", wb.workbook()},
+		{"xl/_rels/workbook.xml.rels", wb.workbookRels()},
+		{"xl/styles.xml", wb.styles()},
+	}
+	for i, sheet := range wb.Sheets {
+		content, err := sheet.xml()
+		if err != nil {
+			return err
+		}
+		parts = append(parts, struct {
+			name    string
+			content []byte
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), content})
+	}
+
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(part.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Bytes returns the workbook as .xlsx
+func (wb *Workbook) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := wb.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ColumnName returns the letters of a zero-based column index
+func ColumnName(col int) string {
+	name := ""
+	for col >= 0 {
+		name = string(rune('A'+col%26)) + name
+		col = col/26 - 1
+	}
+	return name
+}
+
+// CellRef returns the reference of a zero-based row and column, e.g. "B3"
+func CellRef(row, col int) string {
+	return ColumnName(col) + strconv.Itoa(row+1)
+}
+
+// SheetName makes a name valid as a sheet name: without the characters
+// Excel rejects and at most 31 characters long
+func SheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if runes := []rune(name); len(runes) > MaxSheetNameLength {
+		name = string(runes[:MaxSheetNameLength])
+	}
+	if name == "" {
+		name = "Sheet"
+	}
+	return name
+}
+
+// serialDate returns the Excel serial number of a date
+func serialDate(t time.Time) float64 {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	local := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	return local.Sub(epoch).Hours() / 24
+}
+
+const rootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+	`</Relationships>`
+
+func (wb *Workbook) contentTypes() []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	b.WriteString(`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>`)
+	for i := range wb.Sheets {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString(`</Types>`)
+	return b.Bytes()
+}
+
+func (wb *Workbook) coreProperties() []byte {
+	created := wb.Created
+	if created.IsZero() {
+		created = time.Now()
+	}
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" ` +
+		`xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" ` +
+		`xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">`)
+	b.WriteString(`<dc:title>`)
+	escape(&b, wb.Title)
+	b.WriteString(`</dc:title><dc:creator>`)
+	escape(&b, wb.Creator)
+	b.WriteString(`</dc:creator><dcterms:created xsi:type="dcterms:W3CDTF">`)
+	b.WriteString(created.UTC().Format(time.RFC3339))
+	b.WriteString(`</dcterms:created></cp:coreProperties>`)
+	return b.Bytes()
+}
+
+func (wb *Workbook) workbook() []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	b.WriteString(`<bookViews><workbookView/></bookViews><sheets>`)
+	used := make(map[string]bool)
+	for i, sheet := range wb.Sheets {
+		base := SheetName(sheet.Name)
+		name := base
+		// Sheet names are unique regardless of case
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			suffix := fmt.Sprintf(" (%d)", n)
+			runes := []rune(base)
+			if len(runes) > MaxSheetNameLength-len(suffix) {
+				runes = runes[:MaxSheetNameLength-len(suffix)]
+			}
+			name = string(runes) + suffix
+		}
+		used[strings.ToLower(name)] = true
+		b.WriteString(`<sheet name="`)
+		escape(&b, name)
+		fmt.Fprintf(&b, `" sheetId="%d" r:id="rId%d"/>`, i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.Bytes()
+}
+
+func (wb *Workbook) workbookRels() []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range wb.Sheets {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(wb.Sheets)+1)
+	b.WriteString(`</Relationships>`)
+	return b.Bytes()
+}
+
+func (wb *Workbook) styles() []byte {
+	accent := strings.ToUpper(strings.TrimPrefix(wb.AccentColor, "#"))
+	if len(accent) != 6 {
+		accent = DefaultAccentColor
+	}
+	currency := wb.CurrencyFormat
+	if currency == "" {
+		currency = DefaultCurrencyFormat
+	}
+	date := wb.DateFormat
+	if date == "" {
+		date = DefaultDateFormat
+	}
+
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<numFmts count="2"><numFmt numFmtId="164" formatCode="`)
+	escape(&b, currency)
+	b.WriteString(`"/><numFmt numFmtId="165" formatCode="`)
+	escape(&b, date)
+	b.WriteString(`"/></numFmts>`)
+	b.WriteString(`<fonts count="5">` +
+		`<font><sz val="11"/><name val="Calibri"/></font>` +
+		`<font><b/><sz val="14"/><name val="Calibri"/></font>` +
+		`<font><b/><sz val="11"/><color rgb="FFFFFFFF"/><name val="Calibri"/></font>` +
+		`<font><b/><sz val="11"/><name val="Calibri"/></font>` +
+		`<font><i/><sz val="9"/><color rgb="FF7F7F7F"/><name val="Calibri"/></font>` +
+		`</fonts>`)
+	fmt.Fprintf(&b, `<fills count="4">`+
+		`<fill><patternFill patternType="none"/></fill>`+
+		`<fill><patternFill patternType="gray125"/></fill>`+
+		`<fill><patternFill patternType="solid"><fgColor rgb="FF%s"/><bgColor indexed="64"/></patternFill></fill>`+
+		`<fill><patternFill patternType="solid"><fgColor rgb="FFF2F2F2"/><bgColor indexed="64"/></patternFill></fill>`+
+		`</fills>`, accent)
+	b.WriteString(`<borders count="2"><border><left/><right/><top/><bottom/><diagonal/></border>` +
+		`<border><left style="thin"><color rgb="FFD9D9D9"/></left><right style="thin"><color rgb="FFD9D9D9"/></right>` +
+		`<top style="thin"><color rgb="FFD9D9D9"/></top><bottom style="thin"><color rgb="FFD9D9D9"/></bottom><diagonal/></border></borders>`)
+	b.WriteString(`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`)
+	fmt.Fprintf(&b, `<cellXfs count="%d">`, styleCount)
+	top := `<alignment vertical="top" wrapText="1"/>`
+	b.WriteString(`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>`)
+	b.WriteString(`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>`)
+	b.WriteString(`<xf numFmtId="0" fontId="2" fillId="2" borderId="1" xfId="0" applyFont="1" applyFill="1" applyBorder="1" applyAlignment="1"><alignment vertical="center" wrapText="1"/></xf>`)
+	b.WriteString(`<xf numFmtId="0" fontId="3" fillId="3" borderId="1" xfId="0" applyFont="1" applyFill="1" applyBorder="1" applyAlignment="1">` + top + `</xf>`)
+	b.WriteString(`<xf numFmtId="0" fontId="0" fillId="0" borderId="1" xfId="0" applyBorder="1" applyAlignment="1">` + top + `</xf>`)
+	b.WriteString(`<xf numFmtId="164" fontId="0" fillId="0" borderId="1" xfId="0" applyNumberFormat="1" applyBorder="1" applyAlignment="1">` + top + `</xf>`)
+	b.WriteString(`<xf numFmtId="9" fontId="0" fillId="0" borderId="1" xfId="0" applyNumberFormat="1" applyBorder="1" applyAlignment="1">` + top + `</xf>`)
+	b.WriteString(`<xf numFmtId="165" fontId="0" fillId="0" borderId="1" xfId="0" applyNumberFormat="1" applyBorder="1" applyAlignment="1">` + top + `</xf>`)
+	b.WriteString(`<xf numFmtId="0" fontId="4" fillId="0" borderId="0" xfId="0" applyFont="1" applyAlignment="1"><alignment wrapText="1"/></xf>`)
+	b.WriteString(`</cellXfs><cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`)
+	return b.Bytes()
+}
+
+func (s *Sheet) xml() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	if s.Landscape {
+		b.WriteString(`<sheetPr><pageSetUpPr fitToPage="1"/></sheetPr>`)
+	}
+
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0">`)
+	if s.FreezeRows > 0 || s.FreezeColumns > 0 {
+		b.WriteString(`<pane`)
+		if s.FreezeColumns > 0 {
+			fmt.Fprintf(&b, ` xSplit="%d"`, s.FreezeColumns)
+		}
+		if s.FreezeRows > 0 {
+			fmt.Fprintf(&b, ` ySplit="%d"`, s.FreezeRows)
+		}
+		pane := "bottomRight"
+		switch {
+		case s.FreezeColumns == 0:
+			pane = "bottomLeft"
+		case s.FreezeRows == 0:
+			pane = "topRight"
+		}
+		fmt.Fprintf(&b, ` topLeftCell="%s" activePane="%s" state="frozen"/>`, CellRef(s.FreezeRows, s.FreezeColumns), pane)
+	}
+	b.WriteString(`</sheetView></sheetViews><sheetFormatPr defaultRowHeight="15"/>`)
+
+	if len(s.ColumnWidths) > 0 {
+		b.WriteString(`<cols>`)
+		for i, width := range s.ColumnWidths {
+			if width <= 0 {
+				continue
+			}
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, i+1, i+1, strconv.FormatFloat(width, 'f', -1, 64))
+		}
+		b.WriteString(`</cols>`)
+	}
+
+	b.WriteString(`<sheetData>`)
+	for r, row := range s.Rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			if err := writeCell(&b, CellRef(r, c), cell); err != nil {
+				return nil, err
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData>`)
+
+	if len(s.Merges) > 0 {
+		fmt.Fprintf(&b, `<mergeCells count="%d">`, len(s.Merges))
+		for _, ref := range s.Merges {
+			b.WriteString(`<mergeCell ref="`)
+			escape(&b, ref)
+			b.WriteString(`"/>`)
+		}
+		b.WriteString(`</mergeCells>`)
+	}
+
+	b.WriteString(`<pageMargins left="0.5" right="0.5" top="0.75" bottom="0.75" header="0.3" footer="0.3"/>`)
+	if s.Landscape {
+		b.WriteString(`<pageSetup paperSize="9" orientation="landscape" fitToWidth="1" fitToHeight="0"/>`)
+	}
+	if s.Header != "" || s.Footer != "" {
+		b.WriteString(`<headerFooter>`)
+		if s.Header != "" {
+			b.WriteString(`<oddHeader>&amp;L`)
+			escape(&b, headerText(s.Header))
+			b.WriteString(`</oddHeader>`)
+		}
+		b.WriteString(`<oddFooter>&amp;L`)
+		escape(&b, headerText(s.Footer))
+		b.WriteString(`&amp;R&amp;P / &amp;N</oddFooter></headerFooter>`)
+	}
+	b.WriteString(`</worksheet>`)
+	return b.Bytes(), nil
+}
+
+func writeCell(b *bytes.Buffer, ref string, cell Cell) error {
+	if cell.Style < 0 || cell.Style >= styleCount {
+		return fmt.Errorf("xlsx: invalid style %d in %s", cell.Style, ref)
+	}
+	style := ""
+	if cell.Style != StyleDefault {
+		style = fmt.Sprintf(` s="%d"`, cell.Style)
+	}
+
+	var number float64
+	switch v := cell.Value.(type) {
+	case nil:
+		if style != "" {
+			fmt.Fprintf(b, `<c r="%s"%s/>`, ref, style)
+		}
+		return nil
+	case string:
+		if v == "" && style == "" {
+			return nil
+		}
+		fmt.Fprintf(b, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+		escape(b, v)
+		b.WriteString(`</t></is></c>`)
+		return nil
+	case int:
+		number = float64(v)
+	case int64:
+		number = float64(v)
+	case float64:
+		number = v
+	case time.Time:
+		number = serialDate(v)
+	default:
+		return fmt.Errorf("xlsx: unsupported value %T in %s", cell.Value, ref)
+	}
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return fmt.Errorf("xlsx: invalid number in %s", ref)
+	}
+	fmt.Fprintf(b, `<c r="%s"%s><v>%s</v></c>`, ref, style, strconv.FormatFloat(number, 'f', -1, 64))
+	return nil
+}
+
+// headerText escapes the ampersand, the control character of print headers
+func headerText(s string) string {
+	return strings.ReplaceAll(s, "&", "&&")
+}
+
+func escape(b *bytes.Buffer, s string) {
+	_ = xml.EscapeText(b, []byte(s))
+}
@@ -0,0 +1,269 @@
+// Package xmlschema validates generated XML documents against the official
+// schemas of FinanzOnline and ELDA before they are submitted. The schemas
+// change yearly; each version is embedded with the period it applies to and
+// the right one is picked by the reporting period of the document.
+package xmlschema
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed schemas/*.xsd
+var schemaFiles embed.FS
+
+// ExpiryWarningPeriod is how long before a schema's validity ends the startup check warns
+const ExpiryWarningPeriod = 90 * 24 * time.Hour
+
+var (
+	ErrNoSchema      = errors.New("no schema registered for this format and date")
+	ErrUnknownFormat = errors.New("unknown XML format")
+)
+
+// Format identifies an official XML format
+type Format string
+
+const (
+	FormatU30  Format = "u30"  // Umsatzsteuervoranmeldung (FinanzOnline)
+	FormatZM   Format = "zm"   // Zusammenfassende Meldung (FinanzOnline)
+	FormatL16  Format = "l16"  // Lohnzettel (ELDA)
+	FormatMBGM Format = "mbgm" // Monatliche Beitragsgrundlagenmeldung (ELDA)
+)
+
+// manifest lists the embedded schemas. When a provider publishes the schema
+// for a new year, add the XSD under schemas/ and an entry here; the previous
+// version keeps applying to earlier reporting periods.
+var manifest = []struct {
+	format     Format
+	version    string
+	validFrom  string
+	validUntil string
+	file       string
+}{
+	{FormatU30, "2024.1", "2024-01-01", "2026-12-31", "schemas/u30-2024.xsd"},
+	{FormatZM, "2024.1", "2024-01-01", "2026-12-31", "schemas/zm-2024.xsd"},
+	{FormatL16, "2024.1", "2024-01-01", "2026-12-31", "schemas/l16-2024.xsd"},
+	{FormatMBGM, "2024.1", "2024-01-01", "2026-12-31", "schemas/mbgm-2024.xsd"},
+}
+
+// Entry is a version of a schema and the reporting periods it applies to
+type Entry struct {
+	Format     Format    `json:"format"`
+	Version    string    `json:"version"`
+	ValidFrom  time.Time `json:"valid_from"`
+	ValidUntil time.Time `json:"valid_until"` // Inclusive, last day the schema applies to
+	File       string    `json:"file"`
+
+	schema *Schema
+}
+
+// Covers reports whether the entry applies to a reporting date
+func (e *Entry) Covers(date time.Time) bool {
+	day := truncateDay(date)
+	return !day.Before(e.ValidFrom) && !day.After(e.ValidUntil)
+}
+
+// ValidationError reports the schema violations of a document
+type ValidationError struct {
+	Format     Format      `json:"format"`
+	Version    string      `json:"version"`
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Violations) == 0 {
+		return fmt.Sprintf("%s document does not conform to schema %s", e.Format, e.Version)
+	}
+	return fmt.Sprintf("%s document does not conform to schema %s: %s (%d violations)",
+		e.Format, e.Version, e.Violations[0], len(e.Violations))
+}
+
+// Messages returns the violations as strings
+func (e *ValidationError) Messages() []string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.String()
+	}
+	return messages
+}
+
+// Registry holds the schema versions of the supported formats
+type Registry struct {
+	entries map[Format][]*Entry
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[Format][]*Entry)}
+}
+
+// Add compiles and registers a schema version. validFrom and validUntil are
+// the first and last day of the reporting periods the schema applies to.
+func (r *Registry) Add(format Format, version string, validFrom, validUntil time.Time, xsd []byte) (*Entry, error) {
+	validFrom, validUntil = truncateDay(validFrom), truncateDay(validUntil)
+	if validUntil.Before(validFrom) {
+		return nil, fmt.Errorf("%s %s: validity ends before it starts", format, version)
+	}
+	for _, e := range r.entries[format] {
+		if !validFrom.After(e.ValidUntil) && !validUntil.Before(e.ValidFrom) {
+			return nil, fmt.Errorf("%s %s: validity overlaps version %s", format, version, e.Version)
+		}
+	}
+
+	schema, err := Compile(xsd)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", format, version, err)
+	}
+
+	entry := &Entry{
+		Format:     format,
+		Version:    version,
+		ValidFrom:  validFrom,
+		ValidUntil: validUntil,
+		schema:     schema,
+	}
+	r.entries[format] = append(r.entries[format], entry)
+	sort.Slice(r.entries[format], func(i, j int) bool {
+		return r.entries[format][i].ValidFrom.Before(r.entries[format][j].ValidFrom)
+	})
+	return entry, nil
+}
+
+// Lookup returns the schema version that applies to a reporting date
+func (r *Registry) Lookup(format Format, date time.Time) (*Entry, error) {
+	entries, ok := r.entries[format]
+	if !ok {
+		return nil, ErrUnknownFormat
+	}
+	for _, e := range entries {
+		if e.Covers(date) {
+			return e, nil
+		}
+	}
+	return nil, ErrNoSchema
+}
+
+// Validate validates a document against the schema version for the reporting
+// date. Violations are returned as *ValidationError.
+func (r *Registry) Validate(format Format, date time.Time, doc []byte) (*Entry, error) {
+	entry, err := r.Lookup(format, date)
+	if err != nil {
+		return nil, err
+	}
+
+	violations, err := entry.schema.Validate(doc)
+	if err != nil {
+		return entry, &ValidationError{
+			Format:     format,
+			Version:    entry.Version,
+			Violations: []Violation{{Path: "/", Message: err.Error()}},
+		}
+	}
+	if len(violations) > 0 {
+		return entry, &ValidationError{Format: format, Version: entry.Version, Violations: violations}
+	}
+	return entry, nil
+}
+
+// Entries returns all registered schema versions ordered by format and validity
+func (r *Registry) Entries() []*Entry {
+	var all []*Entry
+	for _, entries := range r.entries {
+		all = append(all, entries...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Format != all[j].Format {
+			return all[i].Format < all[j].Format
+		}
+		return all[i].ValidFrom.Before(all[j].ValidFrom)
+	})
+	return all
+}
+
+// Expiring returns, per format, the latest schema version if its validity
+// ends within the given duration from now or has already ended, i.e. the
+// formats for which no schema is registered for upcoming reporting periods.
+func (r *Registry) Expiring(now time.Time, within time.Duration) []*Entry {
+	deadline := truncateDay(now.Add(within))
+	var expiring []*Entry
+	for _, entry := range r.Entries() {
+		latest := r.entries[entry.Format][len(r.entries[entry.Format])-1]
+		if entry == latest && latest.ValidUntil.Before(deadline) {
+			expiring = append(expiring, latest)
+		}
+	}
+	return expiring
+}
+
+// WarnExpiring logs a warning for every format whose schema validity lapses
+// within the given duration
+func (r *Registry) WarnExpiring(logger *slog.Logger, now time.Time, within time.Duration) {
+	for _, e := range r.Expiring(now, within) {
+		if e.ValidUntil.Before(truncateDay(now)) {
+			logger.Warn("XML schema validity has lapsed, generated documents are no longer validated",
+				"format", e.Format, "version", e.Version, "valid_until", e.ValidUntil.Format("2006-01-02"))
+			continue
+		}
+		logger.Warn("XML schema validity lapses soon, add the schema for the next period",
+			"format", e.Format, "version", e.Version, "valid_until", e.ValidUntil.Format("2006-01-02"),
+			"days_left", int(e.ValidUntil.Sub(truncateDay(now)).Hours()/24))
+	}
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultRegistry *Registry
+)
+
+// Default returns the registry of the embedded schemas. An invalid embedded
+// schema is a build defect and panics.
+func Default() *Registry {
+	defaultOnce.Do(func() {
+		r := NewRegistry()
+		for _, m := range manifest {
+			xsd, err := schemaFiles.ReadFile(m.file)
+			if err != nil {
+				panic(fmt.Sprintf("xmlschema: %v", err))
+			}
+			from, err := time.Parse("2006-01-02", m.validFrom)
+			if err != nil {
+				panic(fmt.Sprintf("xmlschema: %s: %v", m.file, err))
+			}
+			until, err := time.Parse("2006-01-02", m.validUntil)
+			if err != nil {
+				panic(fmt.Sprintf("xmlschema: %s: %v", m.file, err))
+			}
+			entry, err := r.Add(m.format, m.version, from, until, xsd)
+			if err != nil {
+				panic(fmt.Sprintf("xmlschema: %s: %v", m.file, err))
+			}
+			entry.File = strings.TrimPrefix(m.file, "schemas/")
+		}
+		defaultRegistry = r
+	})
+	return defaultRegistry
+}
+
+// Check validates a generated document against the embedded schema for the
+// reporting date before submission. If no schema covers the date a warning
+// is logged and the document passes, so an outdated registry never blocks
+// filing; the startup check warns before that happens.
+func Check(format Format, date time.Time, doc []byte) error {
+	_, err := Default().Validate(format, date, doc)
+	if errors.Is(err, ErrNoSchema) {
+		slog.Warn("no XML schema for reporting period, skipping validation",
+			"format", format, "date", date.Format("2006-01-02"))
+		return nil
+	}
+	return err
+}
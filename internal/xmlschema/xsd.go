@@ -0,0 +1,619 @@
+package xmlschema
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Schema is a compiled XML Schema. Only the subset of XSD used by the
+// official Austrian formats is supported: global and local element
+// declarations, named and anonymous complex types with sequence, all and
+// choice models, attributes, and simple types restricting the built-in types
+// with enumeration, pattern, length, range and digit facets.
+type Schema struct {
+	targetNamespace string
+	elements        map[string]*xsdElement
+	complexTypes    map[string]*xsdComplexType
+	simpleTypes     map[string]*xsdSimpleType
+	patterns        map[string]*regexp.Regexp
+}
+
+type xsdSchema struct {
+	TargetNamespace string           `xml:"targetNamespace,attr"`
+	Elements        []xsdElement     `xml:"element"`
+	ComplexTypes    []xsdComplexType `xml:"complexType"`
+	SimpleTypes     []xsdSimpleType  `xml:"simpleType"`
+}
+
+type xsdElement struct {
+	Name        string          `xml:"name,attr"`
+	Type        string          `xml:"type,attr"`
+	MinOccurs   string          `xml:"minOccurs,attr"`
+	MaxOccurs   string          `xml:"maxOccurs,attr"`
+	ComplexType *xsdComplexType `xml:"complexType"`
+	SimpleType  *xsdSimpleType  `xml:"simpleType"`
+}
+
+type xsdComplexType struct {
+	Name       string         `xml:"name,attr"`
+	Sequence   *xsdGroup      `xml:"sequence"`
+	All        *xsdGroup      `xml:"all"`
+	Choice     *xsdGroup      `xml:"choice"`
+	Attributes []xsdAttribute `xml:"attribute"`
+}
+
+type xsdGroup struct {
+	MinOccurs string       `xml:"minOccurs,attr"`
+	Elements  []xsdElement `xml:"element"`
+}
+
+type xsdAttribute struct {
+	Name       string         `xml:"name,attr"`
+	Type       string         `xml:"type,attr"`
+	Use        string         `xml:"use,attr"`
+	SimpleType *xsdSimpleType `xml:"simpleType"`
+}
+
+type xsdSimpleType struct {
+	Name        string         `xml:"name,attr"`
+	Restriction xsdRestriction `xml:"restriction"`
+}
+
+type xsdRestriction struct {
+	Base           string     `xml:"base,attr"`
+	Enumeration    []xsdFacet `xml:"enumeration"`
+	Pattern        []xsdFacet `xml:"pattern"`
+	Length         *xsdFacet  `xml:"length"`
+	MinLength      *xsdFacet  `xml:"minLength"`
+	MaxLength      *xsdFacet  `xml:"maxLength"`
+	MinInclusive   *xsdFacet  `xml:"minInclusive"`
+	MaxInclusive   *xsdFacet  `xml:"maxInclusive"`
+	TotalDigits    *xsdFacet  `xml:"totalDigits"`
+	FractionDigits *xsdFacet  `xml:"fractionDigits"`
+}
+
+type xsdFacet struct {
+	Value string `xml:"value,attr"`
+}
+
+// Violation is a single schema violation in a document
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+var builtinTypes = map[string]bool{
+	"string": true, "normalizedString": true, "token": true, "boolean": true,
+	"int": true, "integer": true, "long": true, "short": true,
+	"nonNegativeInteger": true, "positiveInteger": true,
+	"decimal": true, "date": true, "dateTime": true, "gYear": true,
+}
+
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// Compile parses an XSD document
+func Compile(xsd []byte) (*Schema, error) {
+	var raw xsdSchema
+	if err := xml.Unmarshal(xsd, &raw); err != nil {
+		return nil, fmt.Errorf("parse XSD: %w", err)
+	}
+
+	s := &Schema{
+		targetNamespace: raw.TargetNamespace,
+		elements:        make(map[string]*xsdElement),
+		complexTypes:    make(map[string]*xsdComplexType),
+		simpleTypes:     make(map[string]*xsdSimpleType),
+		patterns:        make(map[string]*regexp.Regexp),
+	}
+	for i := range raw.Elements {
+		s.elements[raw.Elements[i].Name] = &raw.Elements[i]
+	}
+	for i := range raw.ComplexTypes {
+		s.complexTypes[raw.ComplexTypes[i].Name] = &raw.ComplexTypes[i]
+	}
+	for i := range raw.SimpleTypes {
+		s.simpleTypes[raw.SimpleTypes[i].Name] = &raw.SimpleTypes[i]
+	}
+	if len(s.elements) == 0 {
+		return nil, errors.New("XSD declares no global element")
+	}
+
+	// Resolve all type references and compile patterns up front so that a
+	// broken schema fails at load time rather than during a submission
+	for _, el := range raw.Elements {
+		if err := s.check(&el); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Schema) check(el *xsdElement) error {
+	if el.SimpleType != nil {
+		return s.checkSimple(el.SimpleType)
+	}
+	if el.ComplexType != nil {
+		return s.checkComplex(el.ComplexType)
+	}
+	name := localType(el.Type)
+	switch {
+	case el.Type == "" || builtinTypes[name] && isBuiltin(el.Type):
+		return nil
+	case s.simpleTypes[name] != nil:
+		return s.checkSimple(s.simpleTypes[name])
+	case s.complexTypes[name] != nil:
+		return s.checkComplex(s.complexTypes[name])
+	default:
+		return fmt.Errorf("element %s: unknown type %s", el.Name, el.Type)
+	}
+}
+
+func (s *Schema) checkComplex(ct *xsdComplexType) error {
+	if g := ct.group(); g != nil {
+		for i := range g.Elements {
+			if err := s.check(&g.Elements[i]); err != nil {
+				return err
+			}
+		}
+	}
+	for _, a := range ct.Attributes {
+		if a.SimpleType != nil {
+			if err := s.checkSimple(a.SimpleType); err != nil {
+				return err
+			}
+		} else if a.Type != "" && !isBuiltin(a.Type) && s.simpleTypes[localType(a.Type)] == nil {
+			return fmt.Errorf("attribute %s: unknown type %s", a.Name, a.Type)
+		}
+	}
+	return nil
+}
+
+func (s *Schema) checkSimple(st *xsdSimpleType) error {
+	r := st.Restriction
+	if !isBuiltin(r.Base) && s.simpleTypes[localType(r.Base)] == nil {
+		return fmt.Errorf("simple type %s: unknown base %s", st.Name, r.Base)
+	}
+	for _, p := range r.Pattern {
+		if _, err := s.pattern(p.Value); err != nil {
+			return fmt.Errorf("simple type %s: %w", st.Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *Schema) pattern(p string) (*regexp.Regexp, error) {
+	if re, ok := s.patterns[p]; ok {
+		return re, nil
+	}
+	// XSD patterns always match the whole value
+	re, err := regexp.Compile(`^(?:` + p + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+	}
+	s.patterns[p] = re
+	return re, nil
+}
+
+func (ct *xsdComplexType) group() *xsdGroup {
+	switch {
+	case ct.Sequence != nil:
+		return ct.Sequence
+	case ct.All != nil:
+		return ct.All
+	default:
+		return ct.Choice
+	}
+}
+
+func isBuiltin(typ string) bool {
+	prefix, name, found := strings.Cut(typ, ":")
+	if !found {
+		return false
+	}
+	return (prefix == "xs" || prefix == "xsd") && builtinTypes[name]
+}
+
+func localType(typ string) string {
+	if _, name, found := strings.Cut(typ, ":"); found {
+		return name
+	}
+	return typ
+}
+
+func occurs(value string, def int) int {
+	if value == "unbounded" {
+		return -1
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		return n
+	}
+	return def
+}
+
+// node is a parsed instance element
+type node struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*node
+	text     strings.Builder
+}
+
+func parseDocument(doc []byte) (*node, error) {
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	var root *node
+	var stack []*node
+
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name, attrs: t.Attr}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, errors.New("multiple root elements")
+				}
+				root = n
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("document has no root element")
+	}
+	return root, nil
+}
+
+// Validate checks a document against the schema and returns all violations
+func (s *Schema) Validate(doc []byte) ([]Violation, error) {
+	root, err := parseDocument(doc)
+	if err != nil {
+		return nil, fmt.Errorf("parse XML: %w", err)
+	}
+
+	v := &validator{schema: s}
+	path := "/" + root.name.Local
+	decl, ok := s.elements[root.name.Local]
+	if !ok {
+		v.add(path, "root element is not declared in the schema")
+		return v.violations, nil
+	}
+	if root.name.Space != s.targetNamespace {
+		v.add(path, fmt.Sprintf("namespace %q, expected %q", root.name.Space, s.targetNamespace))
+	}
+	v.element(root, decl, path)
+	return v.violations, nil
+}
+
+type validator struct {
+	schema     *Schema
+	violations []Violation
+}
+
+func (v *validator) add(path, message string) {
+	v.violations = append(v.violations, Violation{Path: path, Message: message})
+}
+
+func (v *validator) element(n *node, decl *xsdElement, path string) {
+	if decl.SimpleType != nil {
+		v.simpleContent(n, decl.SimpleType, path)
+		return
+	}
+	if decl.ComplexType != nil {
+		v.complexContent(n, decl.ComplexType, path)
+		return
+	}
+
+	name := localType(decl.Type)
+	switch {
+	case decl.Type == "":
+		// xs:anyType
+	case isBuiltin(decl.Type):
+		if len(n.children) > 0 {
+			v.add(path, "element must not have child elements")
+		}
+		if msg := checkBuiltin(name, n.text.String()); msg != "" {
+			v.add(path, msg)
+		}
+	case v.schema.simpleTypes[name] != nil:
+		v.simpleContent(n, v.schema.simpleTypes[name], path)
+	default:
+		v.complexContent(n, v.schema.complexTypes[name], path)
+	}
+}
+
+func (v *validator) simpleContent(n *node, st *xsdSimpleType, path string) {
+	if len(n.children) > 0 {
+		v.add(path, "element must not have child elements")
+		return
+	}
+	if msg := v.checkSimple(st, n.text.String()); msg != "" {
+		v.add(path, msg)
+	}
+}
+
+func (v *validator) complexContent(n *node, ct *xsdComplexType, path string) {
+	v.attributes(n, ct, path)
+
+	g := ct.group()
+	if g == nil {
+		if len(n.children) > 0 {
+			v.add(path, "element must be empty")
+		}
+		return
+	}
+
+	child := func(c *node) string { return path + "/" + c.name.Local }
+
+	switch {
+	case ct.Sequence != nil:
+		i := 0
+		for k := range g.Elements {
+			decl := &g.Elements[k]
+			count := 0
+			for i < len(n.children) && n.children[i].name.Local == decl.Name {
+				v.element(n.children[i], decl, child(n.children[i]))
+				count++
+				i++
+			}
+			v.occurrences(decl, count, path)
+		}
+		for ; i < len(n.children); i++ {
+			v.add(child(n.children[i]), "unexpected element")
+		}
+
+	case ct.All != nil:
+		counts := make(map[string]int)
+		for _, c := range n.children {
+			decl := g.find(c.name.Local)
+			if decl == nil {
+				v.add(child(c), "unexpected element")
+				continue
+			}
+			counts[c.name.Local]++
+			v.element(c, decl, child(c))
+		}
+		for k := range g.Elements {
+			v.occurrences(&g.Elements[k], counts[g.Elements[k].Name], path)
+		}
+
+	default: // choice
+		if len(n.children) == 0 {
+			if occurs(g.MinOccurs, 1) > 0 {
+				v.add(path, "one of the choice elements is required")
+			}
+			return
+		}
+		decl := g.find(n.children[0].name.Local)
+		if decl == nil {
+			v.add(child(n.children[0]), "unexpected element")
+			return
+		}
+		count := 0
+		for _, c := range n.children {
+			if c.name.Local != decl.Name {
+				v.add(child(c), "unexpected element in choice of "+decl.Name)
+				continue
+			}
+			count++
+			v.element(c, decl, child(c))
+		}
+		v.occurrences(decl, count, path)
+	}
+}
+
+func (g *xsdGroup) find(name string) *xsdElement {
+	for i := range g.Elements {
+		if g.Elements[i].Name == name {
+			return &g.Elements[i]
+		}
+	}
+	return nil
+}
+
+func (v *validator) occurrences(decl *xsdElement, count int, path string) {
+	min, max := occurs(decl.MinOccurs, 1), occurs(decl.MaxOccurs, 1)
+	if count < min {
+		if min == 1 {
+			v.add(path, fmt.Sprintf("missing required element %s", decl.Name))
+		} else {
+			v.add(path, fmt.Sprintf("element %s occurs %d times, at least %d required", decl.Name, count, min))
+		}
+	}
+	if max >= 0 && count > max {
+		v.add(path, fmt.Sprintf("element %s occurs %d times, at most %d allowed", decl.Name, count, max))
+	}
+}
+
+func (v *validator) attributes(n *node, ct *xsdComplexType, path string) {
+	values := make(map[string]string)
+	for _, a := range n.attrs {
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+			continue
+		}
+		values[a.Name.Local] = a.Value
+	}
+
+	for _, decl := range ct.Attributes {
+		value, ok := values[decl.Name]
+		if !ok {
+			if decl.Use == "required" {
+				v.add(path, fmt.Sprintf("missing required attribute %s", decl.Name))
+			}
+			continue
+		}
+
+		var msg string
+		switch {
+		case decl.SimpleType != nil:
+			msg = v.checkSimple(decl.SimpleType, value)
+		case isBuiltin(decl.Type):
+			msg = checkBuiltin(localType(decl.Type), value)
+		case decl.Type != "":
+			msg = v.checkSimple(v.schema.simpleTypes[localType(decl.Type)], value)
+		}
+		if msg != "" {
+			v.add(path+"/@"+decl.Name, msg)
+		}
+	}
+}
+
+// checkSimple validates a value against a simple type and returns a message, or "" if valid
+func (v *validator) checkSimple(st *xsdSimpleType, value string) string {
+	r := st.Restriction
+	if isBuiltin(r.Base) {
+		if msg := checkBuiltin(localType(r.Base), value); msg != "" {
+			return msg
+		}
+	} else if msg := v.checkSimple(v.schema.simpleTypes[localType(r.Base)], value); msg != "" {
+		return msg
+	}
+
+	value = strings.TrimSpace(value)
+
+	if len(r.Enumeration) > 0 {
+		allowed := make([]string, 0, len(r.Enumeration))
+		found := false
+		for _, e := range r.Enumeration {
+			allowed = append(allowed, e.Value)
+			if e.Value == value {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Sprintf("value %q is not one of %s", value, strings.Join(allowed, ", "))
+		}
+	}
+
+	if len(r.Pattern) > 0 {
+		matched := false
+		for _, p := range r.Pattern {
+			re, _ := v.schema.pattern(p.Value)
+			if re.MatchString(value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Sprintf("value %q does not match the required pattern", value)
+		}
+	}
+
+	length := utf8.RuneCountInString(value)
+	if r.Length != nil && length != atoi(r.Length.Value) {
+		return fmt.Sprintf("length %d, expected %s", length, r.Length.Value)
+	}
+	if r.MinLength != nil && length < atoi(r.MinLength.Value) {
+		return fmt.Sprintf("length %d, at least %s required", length, r.MinLength.Value)
+	}
+	if r.MaxLength != nil && length > atoi(r.MaxLength.Value) {
+		return fmt.Sprintf("length %d, at most %s allowed", length, r.MaxLength.Value)
+	}
+
+	if r.MinInclusive != nil || r.MaxInclusive != nil {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Sprintf("value %q is not a number", value)
+		}
+		if r.MinInclusive != nil {
+			if min, _ := strconv.ParseFloat(r.MinInclusive.Value, 64); number < min {
+				return fmt.Sprintf("value %s is below the minimum %s", value, r.MinInclusive.Value)
+			}
+		}
+		if r.MaxInclusive != nil {
+			if max, _ := strconv.ParseFloat(r.MaxInclusive.Value, 64); number > max {
+				return fmt.Sprintf("value %s exceeds the maximum %s", value, r.MaxInclusive.Value)
+			}
+		}
+	}
+
+	if r.TotalDigits != nil || r.FractionDigits != nil {
+		digits := strings.TrimLeft(value, "+-")
+		intPart, fracPart, _ := strings.Cut(digits, ".")
+		intPart = strings.TrimLeft(intPart, "0")
+		fracPart = strings.TrimRight(fracPart, "0")
+		if r.TotalDigits != nil && len(intPart)+len(fracPart) > atoi(r.TotalDigits.Value) {
+			return fmt.Sprintf("value %s has more than %s digits", value, r.TotalDigits.Value)
+		}
+		if r.FractionDigits != nil && len(fracPart) > atoi(r.FractionDigits.Value) {
+			return fmt.Sprintf("value %s has more than %s decimal places", value, r.FractionDigits.Value)
+		}
+	}
+
+	return ""
+}
+
+// checkBuiltin validates a value against a built-in XSD type
+func checkBuiltin(name, value string) string {
+	value = strings.TrimSpace(value)
+	switch name {
+	case "boolean":
+		if value != "true" && value != "false" && value != "1" && value != "0" {
+			return fmt.Sprintf("value %q is not a boolean", value)
+		}
+	case "int", "integer", "long", "short", "nonNegativeInteger", "positiveInteger":
+		n, err := strconv.ParseInt(value, 10, 64)
+		switch {
+		case err != nil:
+			return fmt.Sprintf("value %q is not an integer", value)
+		case name == "nonNegativeInteger" && n < 0:
+			return fmt.Sprintf("value %d must not be negative", n)
+		case name == "positiveInteger" && n <= 0:
+			return fmt.Sprintf("value %d must be positive", n)
+		case name == "int" && (n < -1<<31 || n > 1<<31-1), name == "short" && (n < -1<<15 || n > 1<<15-1):
+			return fmt.Sprintf("value %d is out of range for %s", n, name)
+		}
+	case "decimal":
+		if !decimalPattern.MatchString(value) {
+			return fmt.Sprintf("value %q is not a decimal", value)
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return fmt.Sprintf("value %q is not a date (YYYY-MM-DD)", value)
+		}
+	case "dateTime":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			if _, err := time.Parse("2006-01-02T15:04:05", value); err != nil {
+				return fmt.Sprintf("value %q is not a dateTime", value)
+			}
+		}
+	case "gYear":
+		if len(value) != 4 || strings.Trim(value, "0123456789") != "" {
+			return fmt.Sprintf("value %q is not a year (YYYY)", value)
+		}
+	}
+	return ""
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,203 @@
+package zm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"austrian-business-infrastructure/internal/fonws"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotCorrectable   = errors.New("only submitted or accepted filings can be corrected")
+	ErrAlreadyCorrected = errors.New("filing was already corrected, correct the latest filing of the chain")
+	ErrNoChanges        = errors.New("correction does not change any entry")
+)
+
+// EntryDelta is the change of a ZM line, a partner and delivery type, by a
+// Berichtigung. Amounts are in cents; an added line has a Before of 0 and a
+// withdrawn line an After of 0.
+type EntryDelta struct {
+	PartnerUID   string `json:"partner_uid"`
+	CountryCode  string `json:"country_code"`
+	DeliveryType string `json:"delivery_type"`
+	Before       int64  `json:"before"`
+	After        int64  `json:"after"`
+	Difference   int64  `json:"difference"`
+}
+
+// ChainEntry is a filing of a correction chain with its changes against
+// the filing it corrects; the original has no delta
+type ChainEntry struct {
+	Submission *Submission
+	Delta      []EntryDelta
+}
+
+// Delta returns the lines a Berichtigung changes, ordered by partner UID and
+// delivery type. Entries of the same line are summed.
+func Delta(before, after []Entry) []EntryDelta {
+	type key struct{ uid, deliveryType string }
+	lines := make(map[key]*EntryDelta)
+	line := func(e Entry) *EntryDelta {
+		k := key{strings.ToUpper(strings.ReplaceAll(e.PartnerUID, " ", "")), strings.ToUpper(e.DeliveryType)}
+		if lines[k] == nil {
+			lines[k] = &EntryDelta{PartnerUID: k.uid, CountryCode: strings.ToUpper(e.CountryCode), DeliveryType: k.deliveryType}
+		}
+		return lines[k]
+	}
+	for _, e := range before {
+		line(e).Before += e.Amount
+	}
+	for _, e := range after {
+		line(e).After += e.Amount
+	}
+
+	var delta []EntryDelta
+	for _, d := range lines {
+		if d.Before != d.After {
+			d.Difference = d.After - d.Before
+			delta = append(delta, *d)
+		}
+	}
+	sort.Slice(delta, func(i, j int) bool {
+		if delta[i].PartnerUID != delta[j].PartnerUID {
+			return delta[i].PartnerUID < delta[j].PartnerUID
+		}
+		return delta[i].DeliveryType < delta[j].DeliveryType
+	})
+	return delta
+}
+
+// CreateCorrection creates a Berichtigung of a filing: a draft for the same
+// quarter with the corrected entries, linked to the filing it corrects.
+// Only the latest submitted filing of a chain can be corrected. The draft
+// holds all entries of the quarter; only the changed lines are submitted.
+func (s *Service) CreateCorrection(ctx context.Context, id, tenantID uuid.UUID, input *UpdateSubmissionInput) (*Submission, error) {
+	original, err := s.repo.GetByID(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if original.Status != StatusSubmitted && original.Status != StatusAccepted {
+		return nil, ErrNotCorrectable
+	}
+
+	corrected, err := s.repo.HasCorrection(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if corrected {
+		return nil, ErrAlreadyCorrected
+	}
+
+	if len(input.Entries) == 0 {
+		return nil, ErrNoEntries
+	}
+	if err := normalizeEntries(input.Entries); err != nil {
+		return nil, err
+	}
+
+	var totalAmount int64
+	for _, e := range input.Entries {
+		totalAmount += e.Amount
+	}
+	entriesJSON, err := json.Marshal(input.Entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize entries: %w", err)
+	}
+
+	correction, err := s.repo.Create(ctx, &Submission{
+		TenantID:      tenantID,
+		AccountID:     original.AccountID,
+		PeriodYear:    original.PeriodYear,
+		PeriodQuarter: original.PeriodQuarter,
+		PeriodMonth:   original.PeriodMonth,
+		Entries:       entriesJSON,
+		EntryCount:    len(input.Entries),
+		TotalAmount:   totalAmount,
+		CorrectsID:    &original.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, correction.ID, tenantID)
+}
+
+// Chain returns the correction chain a filing belongs to, from the original
+// to the latest Berichtigung
+func (s *Service) Chain(ctx context.Context, id, tenantID uuid.UUID) ([]*ChainEntry, error) {
+	submissions, err := s.repo.ListChain(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[uuid.UUID][]Entry, len(submissions))
+	chain := make([]*ChainEntry, 0, len(submissions))
+	for _, submission := range submissions {
+		e, err := ParseEntries(submission.Entries)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse entries: %w", err)
+		}
+		entries[submission.ID] = e
+
+		entry := &ChainEntry{Submission: submission}
+		if submission.CorrectsID != nil {
+			if before, ok := entries[*submission.CorrectsID]; ok {
+				entry.Delta = Delta(before, e)
+			}
+		}
+		chain = append(chain, entry)
+	}
+	return chain, nil
+}
+
+// toFonwsZM converts a submission to the ZM that is uploaded. A Berichtigung
+// carries only the lines it changes against the filing it corrects.
+func (s *Service) toFonwsZM(ctx context.Context, submission *Submission, entries []Entry) (*fonws.ZM, error) {
+	if submission.CorrectsID == nil {
+		return s.entriesToFonwsZM(submission.PeriodYear, submission.PeriodQuarter, submission.PeriodMonth, entries), nil
+	}
+
+	original, err := s.repo.GetByID(ctx, *submission.CorrectsID, submission.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	before, err := ParseEntries(original.Entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse entries: %w", err)
+	}
+	zm, err := CorrectionZM(submission.PeriodYear, submission.PeriodQuarter, original.FOReference, Delta(before, entries))
+	if err != nil {
+		return nil, err
+	}
+	if submission.PeriodMonth != nil {
+		zm.Month = *submission.PeriodMonth
+	}
+	return zm, nil
+}
+
+// CorrectionZM builds the ZM of a Berichtigung from its delta, referencing
+// the Belegnummer of the corrected filing if known
+func CorrectionZM(year, quarter int, reference *string, delta []EntryDelta) (*fonws.ZM, error) {
+	if len(delta) == 0 {
+		return nil, ErrNoChanges
+	}
+
+	zm := fonws.NewZM(year, quarter)
+	zm.Berichtigung = &fonws.Berichtigung{}
+	if reference != nil {
+		zm.Berichtigung.Bezugsnummer = *reference
+	}
+	for _, d := range delta {
+		zm.Entries = append(zm.Entries, fonws.ZMEntry{
+			PartnerUID:   d.PartnerUID,
+			CountryCode:  d.CountryCode,
+			DeliveryType: fonws.ZMDeliveryType(d.DeliveryType),
+			Amount:       d.After,
+		})
+	}
+	return zm, nil
+}
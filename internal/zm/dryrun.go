@@ -0,0 +1,123 @@
+package zm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"austrian-business-infrastructure/internal/account"
+	"austrian-business-infrastructure/internal/dryrun"
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/xmlschema"
+	"github.com/google/uuid"
+)
+
+// CheckInput adds the findings of the field and business rules of a ZM to
+// the report. Unlike fonws.ZM.Validate it reports every entry, not only the
+// first invalid one.
+func CheckInput(report *dryrun.Report, input *CreateSubmissionInput, now time.Time) {
+	if input.PeriodYear < 2000 || input.PeriodYear > 2100 {
+		report.Error("invalid_year", "period_year", ErrInvalidYear.Error())
+	}
+	quarter, periodValid := input.PeriodQuarter, true
+	if input.PeriodMonth != nil {
+		if m := *input.PeriodMonth; m < 1 || m > 12 || (quarter != 0 && quarter != quarterOf(m)) {
+			report.Error("invalid_month", "period_month", ErrInvalidMonth.Error())
+			periodValid = false
+		} else {
+			quarter = quarterOf(m)
+		}
+	} else if quarter < 1 || quarter > 4 {
+		report.Error("invalid_quarter", "period_quarter", ErrInvalidQuarter.Error())
+		periodValid = false
+	}
+	if len(input.Entries) == 0 {
+		report.Error("no_entries", "entries", ErrNoEntries.Error())
+	}
+
+	seen := make(map[string]int)
+	for i, e := range input.Entries {
+		field := fmt.Sprintf("entries[%d]", i)
+		if e.Triangular && e.DeliveryType == DeliveryTypeServices {
+			report.Error("triangular_services", field+".triangular", ErrTriangularServices.Error())
+			continue
+		}
+		entry := fonws.ZMEntry{
+			PartnerUID:   e.PartnerUID,
+			CountryCode:  e.CountryCode,
+			DeliveryType: fonws.ZMDeliveryType(e.deliveryType()),
+			Amount:       e.Amount,
+		}
+		if err := entry.Validate(); err != nil {
+			report.Error("invalid_entry", field, err.Error())
+			continue
+		}
+
+		uid := strings.ToUpper(strings.Join(strings.Fields(e.PartnerUID), ""))
+		if e.Amount < 100 {
+			report.Warn("amount_rounds_to_zero", field+".amount", "amounts are reported in whole euros; this entry is reported as 0")
+		}
+
+		key := uid + "/" + e.deliveryType()
+		if first, ok := seen[key]; ok {
+			report.Warn("duplicate_entry", field,
+				fmt.Sprintf("same partner and delivery type as entries[%d]; FinanzOnline expects one summed line", first))
+		} else {
+			seen[key] = i
+		}
+	}
+
+	if periodValid {
+		start := periodStart(input.PeriodYear, quarter, input.PeriodMonth)
+		end, unit := start.AddDate(0, 3, 0), "quarter"
+		if input.PeriodMonth != nil {
+			end, unit = start.AddDate(0, 1, 0), "month"
+		}
+		if now.Before(end) {
+			report.Warn("period_open", "", fmt.Sprintf("the %s ends on %s", unit, end.AddDate(0, 0, -1).Format("2006-01-02")))
+		}
+	}
+}
+
+// DryRun runs the full validation of a ZM as Create and Submit would,
+// including the account, duplicate and schema checks, without storing
+// anything or contacting FinanzOnline
+func (s *Service) DryRun(ctx context.Context, tenantID uuid.UUID, input *CreateSubmissionInput) (*dryrun.Report, error) {
+	report := dryrun.NewReport(dryrun.KindZM)
+	now := time.Now()
+	CheckInput(report, input, now)
+
+	if input.AccountID == uuid.Nil {
+		report.Error("missing_account", "account_id", "account_id is required")
+	} else if acc, err := s.accountService.GetAccount(ctx, input.AccountID, tenantID); err != nil {
+		report.Error("account_not_found", "account_id", ErrAccountNotFound.Error())
+	} else if acc.Type != account.AccountTypeFinanzOnline {
+		report.Error("wrong_account_type", "account_id", "account must be a FinanzOnline account")
+	}
+	if report.HasErrors() {
+		return report.Finish(now), nil
+	}
+
+	quarter := input.PeriodQuarter
+	if input.PeriodMonth != nil {
+		quarter = quarterOf(*input.PeriodMonth)
+	}
+	exists, err := s.repo.CheckDuplicatePeriod(ctx, tenantID, input.AccountID, input.PeriodYear, quarter, input.PeriodMonth, nil)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		report.Error("duplicate_period", "", ErrDuplicatePeriod.Error())
+	}
+
+	xmlContent, err := fonws.GenerateZMXML(s.entriesToFonwsZM(input.PeriodYear, quarter, input.PeriodMonth, input.Entries))
+	if err != nil {
+		report.Error("xml", "", err.Error())
+		return report.Finish(now), nil
+	}
+	if err := report.Schema(xmlschema.FormatZM, periodStart(input.PeriodYear, quarter, input.PeriodMonth), xmlContent); err != nil {
+		return nil, err
+	}
+	return report.Finish(now), nil
+}
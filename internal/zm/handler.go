@@ -2,11 +2,15 @@ package zm
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/endpoint"
+	"austrian-business-infrastructure/internal/entitychange"
 	"github.com/google/uuid"
 )
 
@@ -27,13 +31,17 @@ func (h *Handler) RegisterRoutes(router *api.Router, requireAuth, requireAdmin f
 	router.Handle("PUT /api/v1/zm/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Update))))
 	router.Handle("DELETE /api/v1/zm/{id}", requireAuth(requireAdmin(http.HandlerFunc(h.Delete))))
 	router.Handle("POST /api/v1/zm/{id}/submit", requireAuth(requireAdmin(http.HandlerFunc(h.Submit))))
+	router.Handle("POST /api/v1/zm/{id}/corrections", requireAuth(requireAdmin(http.HandlerFunc(h.CreateCorrection))))
 	router.Handle("POST /api/v1/zm/import", requireAuth(requireAdmin(http.HandlerFunc(h.ImportCSV))))
 
 	// Member access: read-only and validation
 	router.Handle("GET /api/v1/zm", requireAuth(http.HandlerFunc(h.List)))
 	router.Handle("GET /api/v1/zm/{id}", requireAuth(http.HandlerFunc(h.Get)))
+	router.Handle("POST /api/v1/zm/dry-run", requireAuth(http.HandlerFunc(h.DryRun)))
 	router.Handle("POST /api/v1/zm/{id}/validate", requireAuth(http.HandlerFunc(h.Validate)))
 	router.Handle("GET /api/v1/zm/{id}/xml", requireAuth(http.HandlerFunc(h.GetXML)))
+	router.Handle("GET /api/v1/zm/{id}/preview", requireAuth(http.HandlerFunc(h.Preview)))
+	router.Handle("GET /api/v1/zm/{id}/corrections", requireAuth(http.HandlerFunc(h.ListCorrections)))
 }
 
 // CreateRequest represents the create ZM request
@@ -41,6 +49,7 @@ type CreateRequest struct {
 	AccountID     string  `json:"account_id"`
 	PeriodYear    int     `json:"period_year"`
 	PeriodQuarter int     `json:"period_quarter"`
+	PeriodMonth   *int    `json:"period_month,omitempty"`
 	Entries       []Entry `json:"entries"`
 }
 
@@ -68,6 +77,7 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		AccountID:     accountID,
 		PeriodYear:    req.PeriodYear,
 		PeriodQuarter: req.PeriodQuarter,
+		PeriodMonth:   req.PeriodMonth,
 		Entries:       req.Entries,
 	}
 
@@ -198,6 +208,67 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	api.JSONResponse(w, http.StatusOK, h.toResponse(submission))
 }
 
+// CreateCorrection handles POST /api/v1/zm/{id}/corrections
+func (h *Handler) CreateCorrection(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid submission ID")
+		return
+	}
+
+	var req UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	submission, err := h.service.CreateCorrection(r.Context(), id, tenantID, &UpdateSubmissionInput{Entries: req.Entries})
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusCreated, h.toResponse(submission))
+}
+
+// ListCorrections handles GET /api/v1/zm/{id}/corrections: the correction
+// chain of a filing, from the original to the latest Berichtigung
+func (h *Handler) ListCorrections(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid submission ID")
+		return
+	}
+
+	chain, err := h.service.Chain(r.Context(), id, tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	resp := &ChainResponse{Filings: make([]*ChainFilingResponse, 0, len(chain))}
+	for _, entry := range chain {
+		resp.Filings = append(resp.Filings, &ChainFilingResponse{
+			SubmissionResponse: h.toResponse(entry.Submission),
+			Delta:              entry.Delta,
+		})
+	}
+
+	api.JSONResponse(w, http.StatusOK, resp)
+}
+
 // Delete handles DELETE /api/v1/zm/{id}
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := h.getTenantID(r)
@@ -243,6 +314,44 @@ func (h *Handler) Validate(w http.ResponseWriter, r *http.Request) {
 	api.JSONResponse(w, http.StatusOK, h.toResponse(submission))
 }
 
+// DryRun handles POST /api/v1/zm/dry-run. The body is that of Create;
+// nothing is stored.
+func (h *Handler) DryRun(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.BadRequest(w, "invalid request body")
+		return
+	}
+
+	var accountID uuid.UUID
+	if req.AccountID != "" {
+		if accountID, err = uuid.Parse(req.AccountID); err != nil {
+			api.BadRequest(w, "invalid account_id")
+			return
+		}
+	}
+
+	report, err := h.service.DryRun(r.Context(), tenantID, &CreateSubmissionInput{
+		AccountID:     accountID,
+		PeriodYear:    req.PeriodYear,
+		PeriodQuarter: req.PeriodQuarter,
+		PeriodMonth:   req.PeriodMonth,
+		Entries:       req.Entries,
+	})
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, report)
+}
+
 // SubmitRequest represents the submit ZM request
 type SubmitRequest struct {
 	DryRun bool `json:"dry_run"`
@@ -311,6 +420,41 @@ func (h *Handler) GetXML(w http.ResponseWriter, r *http.Request) {
 	w.Write(xmlContent)
 }
 
+// Preview handles GET /api/v1/zm/{id}/preview: the XML that submitting
+// would send, with all validation errors. ?format=xml returns the XML only.
+func (h *Handler) Preview(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := h.getTenantID(r)
+	if err != nil {
+		api.Unauthorized(w, "tenant not found in context")
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		api.BadRequest(w, "invalid submission ID")
+		return
+	}
+
+	preview, err := h.service.Preview(r.Context(), id, tenantID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "xml" {
+		if preview.XML == "" {
+			api.JSONResponse(w, http.StatusUnprocessableEntity, preview)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(preview.XML))
+		return
+	}
+
+	api.JSONResponse(w, http.StatusOK, preview)
+}
+
 // ImportCSVRequest represents the import CSV request
 type ImportCSVRequest struct {
 	AccountID     string `json:"account_id"`
@@ -437,6 +581,15 @@ func (h *Handler) getUserID(r *http.Request) (uuid.UUID, error) {
 }
 
 func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	if m, ok := endpoint.AsMaintenance(err); ok {
+		api.ServiceUnavailable(w, m.RetryAfter(), "FinanzOnline is in a maintenance window, retry later")
+		return
+	}
+	if errors.Is(err, entitychange.ErrWrongEntity) {
+		api.Conflict(w, err.Error())
+		return
+	}
+
 	switch err {
 	case ErrSubmissionNotFound:
 		api.NotFound(w, "submission not found")
@@ -446,6 +599,10 @@ func (h *Handler) handleError(w http.ResponseWriter, err error) {
 		api.BadRequest(w, "quarter must be between 1 and 4")
 	case ErrInvalidYear:
 		api.BadRequest(w, "year must be between 2000 and 2100")
+	case ErrInvalidMonth:
+		api.BadRequest(w, "month must be between 1 and 12 and in the given quarter")
+	case ErrTriangularServices:
+		api.BadRequest(w, "a Dreiecksgeschäft must be a delivery of goods")
 	case ErrSubmissionNotDraft:
 		api.BadRequest(w, "submission is not in draft status")
 	case ErrAccountNotFound:
@@ -454,6 +611,12 @@ func (h *Handler) handleError(w http.ResponseWriter, err error) {
 		api.BadRequest(w, "validation failed")
 	case ErrNoEntries:
 		api.BadRequest(w, "ZM must have at least one entry")
+	case ErrNotCorrectable:
+		api.Conflict(w, "only submitted or accepted filings can be corrected")
+	case ErrAlreadyCorrected:
+		api.Conflict(w, "filing was already corrected, correct the latest filing of the chain")
+	case ErrNoChanges:
+		api.BadRequest(w, "correction does not change any entry")
 	case ErrSubmissionFailed:
 		api.JSONError(w, http.StatusBadGateway, "submission to FinanzOnline failed", "FO_ERROR")
 	default:
@@ -467,6 +630,7 @@ func (h *Handler) toResponse(s *Submission) *SubmissionResponse {
 		AccountID:        s.AccountID,
 		PeriodYear:       s.PeriodYear,
 		PeriodQuarter:    s.PeriodQuarter,
+		PeriodMonth:      s.PeriodMonth,
 		EntryCount:       s.EntryCount,
 		TotalAmount:      s.TotalAmount,
 		TotalAmountEUR:   float64(s.TotalAmount) / 100.0,
@@ -474,8 +638,9 @@ func (h *Handler) toResponse(s *Submission) *SubmissionResponse {
 		ValidationErrors: s.ValidationErrors,
 		Status:           s.Status,
 		FOReference:      s.FOReference,
-		CreatedAt:        s.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:        s.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		CorrectsID:       s.CorrectsID,
+		CreatedAt:        s.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:        s.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 
 	// Parse entries
@@ -485,7 +650,7 @@ func (h *Handler) toResponse(s *Submission) *SubmissionResponse {
 	}
 
 	if s.SubmittedAt != nil {
-		t := s.SubmittedAt.Format("2006-01-02T15:04:05Z")
+		t := s.SubmittedAt.UTC().Format(time.RFC3339)
 		resp.SubmittedAt = &t
 	}
 
@@ -0,0 +1,76 @@
+package zm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/xmlschema"
+	"github.com/google/uuid"
+)
+
+// Preview is the XML a submission would send to FinanzOnline, with the
+// validation result. XML is empty if the ZM is invalid.
+type Preview struct {
+	XML          string                     `json:"xml,omitempty"`
+	Period       string                     `json:"period"`
+	Berichtigung bool                       `json:"berichtigung"`
+	Valid        bool                       `json:"valid"`
+	Errors       []string                   `json:"errors,omitempty"`
+	LineErrors   []fonws.ZMLineError        `json:"line_errors,omitempty"`
+	Schema       *xmlschema.ValidationError `json:"schema,omitempty"`
+	GeneratedAt  time.Time                  `json:"generated_at"`
+}
+
+// Preview generates the XML of a submission without submitting or storing
+// anything. All invalid lines are reported, not just the first.
+func (s *Service) Preview(ctx context.Context, id, tenantID uuid.UUID) (*Preview, error) {
+	submission, err := s.repo.GetByID(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := ParseEntries(submission.Entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse entries: %w", err)
+	}
+
+	zm, err := s.toFonwsZM(ctx, submission, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &Preview{
+		Period:       submission.PeriodString(),
+		Berichtigung: zm.Berichtigung != nil,
+		GeneratedAt:  time.Now(),
+	}
+	if err := zm.ValidatePeriod(); err != nil {
+		preview.Errors = append(preview.Errors, err.Error())
+	}
+	if len(zm.Entries) == 0 {
+		preview.Errors = append(preview.Errors, ErrNoEntries.Error())
+	}
+	preview.LineErrors = zm.ValidateLines()
+	if len(preview.Errors) > 0 || len(preview.LineErrors) > 0 {
+		return preview, nil
+	}
+
+	xmlContent, err := fonws.GenerateZMXML(zm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate XML: %w", err)
+	}
+	preview.XML = string(xmlContent)
+
+	if err := xmlschema.Check(xmlschema.FormatZM, submission.PeriodStart(), xmlContent); err != nil {
+		if !errors.As(err, &preview.Schema) {
+			return nil, err
+		}
+		return preview, nil
+	}
+
+	preview.Valid = true
+	return preview, nil
+}
@@ -38,16 +38,16 @@ func (r *Repository) Create(ctx context.Context, s *Submission) (*Submission, er
 
 	query := `
 		INSERT INTO zm_submissions (
-			id, tenant_id, account_id, period_year, period_quarter,
+			id, tenant_id, account_id, period_year, period_quarter, period_month,
 			entries, entry_count, total_amount, validation_status, status,
-			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			corrects_id, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id, created_at, updated_at`
 
 	err := r.db.QueryRow(ctx, query,
-		s.ID, s.TenantID, s.AccountID, s.PeriodYear, s.PeriodQuarter,
+		s.ID, s.TenantID, s.AccountID, s.PeriodYear, s.PeriodQuarter, s.PeriodMonth,
 		s.Entries, s.EntryCount, s.TotalAmount, s.ValidationStatus, s.Status,
-		s.CreatedAt, s.UpdatedAt,
+		s.CorrectsID, s.CreatedAt, s.UpdatedAt,
 	).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
 
 	if err != nil {
@@ -60,25 +60,29 @@ func (r *Repository) Create(ctx context.Context, s *Submission) (*Submission, er
 // GetByID retrieves a submission by ID
 func (r *Repository) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*Submission, error) {
 	query := `
-		SELECT id, tenant_id, account_id, period_year, period_quarter,
+		SELECT id, tenant_id, account_id, period_year, period_quarter, period_month,
 			entries, entry_count, total_amount, validation_status, validation_errors,
 			status, fo_reference, xml_content, submitted_at, submitted_by,
-			response_code, response_message, created_at, updated_at
-		FROM zm_submissions
+			response_code, response_message, corrects_id,
+			(SELECT o.fo_reference FROM zm_submissions o WHERE o.id = s.corrects_id),
+			created_at, updated_at
+		FROM zm_submissions s
 		WHERE id = $1 AND tenant_id = $2`
 
 	var s Submission
-	var foRef, respMsg sql.NullString
+	var foRef, respMsg, correctsRef sql.NullString
+	var correctsID uuid.NullUUID
 	var respCode sql.NullInt32
 	var submittedAt sql.NullTime
 	var submittedBy uuid.NullUUID
 	var validationErrors, xmlContent []byte
 
 	err := r.db.QueryRow(ctx, query, id, tenantID).Scan(
-		&s.ID, &s.TenantID, &s.AccountID, &s.PeriodYear, &s.PeriodQuarter,
+		&s.ID, &s.TenantID, &s.AccountID, &s.PeriodYear, &s.PeriodQuarter, &s.PeriodMonth,
 		&s.Entries, &s.EntryCount, &s.TotalAmount, &s.ValidationStatus, &validationErrors,
 		&s.Status, &foRef, &xmlContent, &submittedAt, &submittedBy,
-		&respCode, &respMsg, &s.CreatedAt, &s.UpdatedAt,
+		&respCode, &respMsg, &correctsID, &correctsRef,
+		&s.CreatedAt, &s.UpdatedAt,
 	)
 
 	if err != nil {
@@ -104,6 +108,12 @@ func (r *Repository) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*Subm
 	if submittedBy.Valid {
 		s.SubmittedBy = &submittedBy.UUID
 	}
+	if correctsID.Valid {
+		s.CorrectsID = &correctsID.UUID
+	}
+	if correctsRef.Valid {
+		s.CorrectsRef = &correctsRef.String
+	}
 	if len(validationErrors) > 0 {
 		s.ValidationErrors = validationErrors
 	}
@@ -150,11 +160,7 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Submission
 	}
 
 	// Get paginated results
-	selectQuery := `
-		SELECT id, tenant_id, account_id, period_year, period_quarter,
-			entries, entry_count, total_amount, validation_status, validation_errors,
-			status, fo_reference, submitted_at, created_at, updated_at
-		` + baseQuery + `
+	selectQuery := `SELECT ` + summaryColumns + baseQuery + `
 		ORDER BY created_at DESC
 		LIMIT $` + fmt.Sprintf("%d", argIdx) + ` OFFSET $` + fmt.Sprintf("%d", argIdx+1)
 
@@ -168,34 +174,54 @@ func (r *Repository) List(ctx context.Context, filter ListFilter) ([]*Submission
 
 	var submissions []*Submission
 	for rows.Next() {
-		var s Submission
-		var foRef sql.NullString
-		var submittedAt sql.NullTime
-		var validationErrors []byte
-
-		err := rows.Scan(
-			&s.ID, &s.TenantID, &s.AccountID, &s.PeriodYear, &s.PeriodQuarter,
-			&s.Entries, &s.EntryCount, &s.TotalAmount, &s.ValidationStatus, &validationErrors,
-			&s.Status, &foRef, &submittedAt, &s.CreatedAt, &s.UpdatedAt,
-		)
+		s, err := scanSummary(rows)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan submission: %w", err)
+			return nil, 0, err
 		}
+		submissions = append(submissions, s)
+	}
 
-		if foRef.Valid {
-			s.FOReference = &foRef.String
-		}
-		if submittedAt.Valid {
-			s.SubmittedAt = &submittedAt.Time
-		}
-		if len(validationErrors) > 0 {
-			s.ValidationErrors = validationErrors
-		}
+	return submissions, total, nil
+}
 
-		submissions = append(submissions, &s)
+// summaryColumns are the columns of a submission without its XML and
+// FinanzOnline response, as read by scanSummary
+const summaryColumns = `
+		id, tenant_id, account_id, period_year, period_quarter, period_month,
+		entries, entry_count, total_amount, validation_status, validation_errors,
+		status, fo_reference, submitted_at, corrects_id, created_at, updated_at
+	`
+
+func scanSummary(row pgx.Row) (*Submission, error) {
+	var s Submission
+	var foRef sql.NullString
+	var submittedAt sql.NullTime
+	var correctsID uuid.NullUUID
+	var validationErrors []byte
+
+	err := row.Scan(
+		&s.ID, &s.TenantID, &s.AccountID, &s.PeriodYear, &s.PeriodQuarter, &s.PeriodMonth,
+		&s.Entries, &s.EntryCount, &s.TotalAmount, &s.ValidationStatus, &validationErrors,
+		&s.Status, &foRef, &submittedAt, &correctsID, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan submission: %w", err)
 	}
 
-	return submissions, total, nil
+	if foRef.Valid {
+		s.FOReference = &foRef.String
+	}
+	if submittedAt.Valid {
+		s.SubmittedAt = &submittedAt.Time
+	}
+	if correctsID.Valid {
+		s.CorrectsID = &correctsID.UUID
+	}
+	if len(validationErrors) > 0 {
+		s.ValidationErrors = validationErrors
+	}
+
+	return &s, nil
 }
 
 // Update updates a submission
@@ -295,18 +321,22 @@ func (r *Repository) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
 	return nil
 }
 
-// CheckDuplicatePeriod checks if a submission for the same period exists
-func (r *Repository) CheckDuplicatePeriod(ctx context.Context, tenantID, accountID uuid.UUID, year, quarter int, excludeID *uuid.UUID) (bool, error) {
+// CheckDuplicatePeriod checks if a submission for the same period exists;
+// Berichtigungen do not count, they share the period of the original. A
+// quarterly ZM overlaps the monthly ones of its quarter.
+func (r *Repository) CheckDuplicatePeriod(ctx context.Context, tenantID, accountID uuid.UUID, year, quarter int, month *int, excludeID *uuid.UUID) (bool, error) {
 	query := `
 		SELECT EXISTS (
 			SELECT 1 FROM zm_submissions
 			WHERE tenant_id = $1 AND account_id = $2 AND period_year = $3 AND period_quarter = $4
-			AND status NOT IN ('rejected', 'error')`
+			AND (period_month IS NULL OR $5::int IS NULL OR period_month = $5)
+			AND status NOT IN ('rejected', 'error')
+			AND corrects_id IS NULL`
 
-	args := []interface{}{tenantID, accountID, year, quarter}
+	args := []interface{}{tenantID, accountID, year, quarter, month}
 
 	if excludeID != nil {
-		query += ` AND id != $5`
+		query += ` AND id != $6`
 		args = append(args, *excludeID)
 	}
 
@@ -321,6 +351,61 @@ func (r *Repository) CheckDuplicatePeriod(ctx context.Context, tenantID, account
 	return exists, nil
 }
 
+// HasCorrection checks if a filing has a Berichtigung that was not rejected
+func (r *Repository) HasCorrection(ctx context.Context, id, tenantID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM zm_submissions
+			WHERE corrects_id = $1 AND tenant_id = $2 AND status NOT IN ('rejected', 'error')
+		)`, id, tenantID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check correction: %w", err)
+	}
+	return exists, nil
+}
+
+// ListChain returns the correction chain of a filing: the original and all
+// Berichtigungen of it, in the order they were created
+func (r *Repository) ListChain(ctx context.Context, id, tenantID uuid.UUID) ([]*Submission, error) {
+	query := `
+		WITH RECURSIVE up AS (
+			SELECT id, corrects_id FROM zm_submissions WHERE id = $1 AND tenant_id = $2
+			UNION ALL
+			SELECT s.id, s.corrects_id FROM zm_submissions s JOIN up ON s.id = up.corrects_id
+		), chain AS (
+			SELECT id FROM up WHERE corrects_id IS NULL
+			UNION ALL
+			SELECT s.id FROM zm_submissions s JOIN chain ON s.corrects_id = chain.id
+		)
+		SELECT ` + summaryColumns + `
+		FROM zm_submissions
+		WHERE tenant_id = $2 AND id IN (SELECT id FROM chain)
+		ORDER BY created_at, id`
+
+	rows, err := r.db.Query(ctx, query, id, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list correction chain: %w", err)
+	}
+	defer rows.Close()
+
+	var submissions []*Submission
+	for rows.Next() {
+		s, err := scanSummary(rows)
+		if err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(submissions) == 0 {
+		return nil, ErrSubmissionNotFound
+	}
+	return submissions, nil
+}
+
 // SetSubmittedBy marks who submitted the ZM
 func (r *Repository) SetSubmittedBy(ctx context.Context, id, tenantID, userID uuid.UUID) error {
 	query := `
@@ -334,7 +419,7 @@ func (r *Repository) SetSubmittedBy(ctx context.Context, id, tenantID, userID uu
 // GetByPeriod retrieves a submission by period
 func (r *Repository) GetByPeriod(ctx context.Context, tenantID, accountID uuid.UUID, year, quarter int) (*Submission, error) {
 	query := `
-		SELECT id, tenant_id, account_id, period_year, period_quarter,
+		SELECT id, tenant_id, account_id, period_year, period_quarter, period_month,
 			entries, entry_count, total_amount, validation_status, validation_errors,
 			status, fo_reference, submitted_at, created_at, updated_at
 		FROM zm_submissions
@@ -349,7 +434,7 @@ func (r *Repository) GetByPeriod(ctx context.Context, tenantID, accountID uuid.U
 	var validationErrors []byte
 
 	err := r.db.QueryRow(ctx, query, tenantID, accountID, year, quarter).Scan(
-		&s.ID, &s.TenantID, &s.AccountID, &s.PeriodYear, &s.PeriodQuarter,
+		&s.ID, &s.TenantID, &s.AccountID, &s.PeriodYear, &s.PeriodQuarter, &s.PeriodMonth,
 		&s.Entries, &s.EntryCount, &s.TotalAmount, &s.ValidationStatus, &validationErrors,
 		&s.Status, &foRef, &submittedAt, &s.CreatedAt, &s.UpdatedAt,
 	)
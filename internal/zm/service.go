@@ -8,7 +8,12 @@ import (
 
 	"austrian-business-infrastructure/internal/account"
 	"austrian-business-infrastructure/internal/account/types"
+	"austrian-business-infrastructure/internal/analytics"
+	"austrian-business-infrastructure/internal/endpoint"
+	"austrian-business-infrastructure/internal/entitychange"
 	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/rawpayload"
+	"austrian-business-infrastructure/internal/xmlschema"
 	"github.com/google/uuid"
 )
 
@@ -20,13 +25,18 @@ var (
 	ErrValidationFailed    = errors.New("validation failed")
 	ErrSubmissionFailed    = errors.New("submission to FinanzOnline failed")
 	ErrNoEntries           = errors.New("ZM must have at least one entry")
+	ErrInvalidMonth        = errors.New("month must be between 1 and 12 and in the given quarter")
+	ErrTriangularServices  = errors.New("a Dreiecksgeschäft must be a delivery of goods")
 )
 
 // Service handles ZM business logic
 type Service struct {
 	repo           *Repository
 	accountService *account.Service
-	fonwsClient    *fonws.Client
+	fonwsClient    fonws.Caller
+	payloads       *rawpayload.Service
+	analytics      *analytics.Emitter
+	entityChanges  *entitychange.Service
 }
 
 // NewService creates a new ZM service
@@ -38,17 +48,51 @@ func NewService(repo *Repository, accountService *account.Service) *Service {
 	}
 }
 
+// SetRawPayloads enables retention of the raw FinanzOnline exchanges of submissions
+func (s *Service) SetRawPayloads(p *rawpayload.Service) {
+	s.payloads = p
+}
+
+// SetEndpoints makes FinanzOnline calls use the active endpoint set and
+// fail fast during maintenance windows
+func (s *Service) SetEndpoints(r fonws.EndpointResolver) {
+	if c, ok := s.fonwsClient.(*fonws.Client); ok {
+		c.SetEndpointResolver(r)
+	}
+}
+
+// SetAnalytics reports submitted ZMs to the product analytics
+func (s *Service) SetAnalytics(emitter *analytics.Emitter) {
+	s.analytics = emitter
+}
+
+// SetEntityChanges makes Create refuse periods that belong to the other
+// side of a legal entity change of the account
+func (s *Service) SetEntityChanges(c *entitychange.Service) {
+	s.entityChanges = c
+}
+
+// SetFinanzOnline replaces the FinanzOnline client, e.g. with a fake in tests
+func (s *Service) SetFinanzOnline(c fonws.Caller) {
+	s.fonwsClient = c
+}
+
 // Create creates a new ZM submission
 func (s *Service) Create(ctx context.Context, tenantID uuid.UUID, input *CreateSubmissionInput) (*Submission, error) {
 	// Validate period
-	if err := s.validatePeriod(input.PeriodYear, input.PeriodQuarter); err != nil {
+	quarter, err := s.validatePeriod(input.PeriodYear, input.PeriodQuarter, input.PeriodMonth)
+	if err != nil {
 		return nil, err
 	}
+	input.PeriodQuarter = quarter
 
 	// Validate entries
 	if len(input.Entries) == 0 {
 		return nil, ErrNoEntries
 	}
+	if err := normalizeEntries(input.Entries); err != nil {
+		return nil, err
+	}
 
 	// Verify account exists and belongs to tenant
 	acc, err := s.accountService.GetAccount(ctx, input.AccountID, tenantID)
@@ -58,9 +102,19 @@ func (s *Service) Create(ctx context.Context, tenantID uuid.UUID, input *CreateS
 	if acc.Type != account.AccountTypeFinanzOnline {
 		return nil, errors.New("account must be a FinanzOnline account")
 	}
+	if s.entityChanges != nil {
+		from := periodStart(input.PeriodYear, input.PeriodQuarter, input.PeriodMonth)
+		to := from.AddDate(0, 3, -1)
+		if input.PeriodMonth != nil {
+			to = from.AddDate(0, 1, -1)
+		}
+		if err := s.entityChanges.CheckPeriod(ctx, tenantID, input.AccountID, from, to); err != nil {
+			return nil, err
+		}
+	}
 
 	// Check for duplicate
-	exists, err := s.repo.CheckDuplicatePeriod(ctx, tenantID, input.AccountID, input.PeriodYear, input.PeriodQuarter, nil)
+	exists, err := s.repo.CheckDuplicatePeriod(ctx, tenantID, input.AccountID, input.PeriodYear, input.PeriodQuarter, input.PeriodMonth, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -85,6 +139,7 @@ func (s *Service) Create(ctx context.Context, tenantID uuid.UUID, input *CreateS
 		AccountID:     input.AccountID,
 		PeriodYear:    input.PeriodYear,
 		PeriodQuarter: input.PeriodQuarter,
+		PeriodMonth:   input.PeriodMonth,
 		Entries:       entriesJSON,
 		EntryCount:    len(input.Entries),
 		TotalAmount:   totalAmount,
@@ -121,6 +176,9 @@ func (s *Service) Update(ctx context.Context, id, tenantID uuid.UUID, input *Upd
 	if len(input.Entries) == 0 {
 		return nil, ErrNoEntries
 	}
+	if err := normalizeEntries(input.Entries); err != nil {
+		return nil, err
+	}
 
 	// Calculate total amount
 	var totalAmount int64
@@ -179,7 +237,10 @@ func (s *Service) Validate(ctx context.Context, id, tenantID uuid.UUID) (*Submis
 	}
 
 	// Convert to fonws format and validate
-	zm := s.entriesToFonwsZM(submission.PeriodYear, submission.PeriodQuarter, entries)
+	zm, err := s.toFonwsZM(ctx, submission, entries)
+	if err != nil {
+		return nil, err
+	}
 	validationErr := zm.Validate()
 
 	if validationErr != nil {
@@ -219,7 +280,10 @@ func (s *Service) Submit(ctx context.Context, id, tenantID, userID uuid.UUID, dr
 	}
 
 	// Create fonws ZM struct
-	zm := s.entriesToFonwsZM(submission.PeriodYear, submission.PeriodQuarter, entries)
+	zm, err := s.toFonwsZM(ctx, submission, entries)
+	if err != nil {
+		return nil, err
+	}
 
 	// Validate first
 	if err := zm.Validate(); err != nil {
@@ -238,6 +302,21 @@ func (s *Service) Submit(ctx context.Context, id, tenantID, userID uuid.UUID, dr
 		return nil, fmt.Errorf("failed to generate XML: %w", err)
 	}
 
+	// Validate against the ZM schema of the reporting period
+	if err := xmlschema.Check(xmlschema.FormatZM, submission.PeriodStart(), xmlContent); err != nil {
+		var schemaErr *xmlschema.ValidationError
+		if !errors.As(err, &schemaErr) {
+			return nil, err
+		}
+		validationErrors, _ := json.Marshal(map[string]interface{}{"error": schemaErr.Error(), "schema": schemaErr})
+		submission.ValidationStatus = "failed"
+		submission.ValidationErrors = validationErrors
+		if updateErr := s.repo.Update(ctx, submission); updateErr != nil {
+			return nil, updateErr
+		}
+		return nil, ErrValidationFailed
+	}
+
 	// Save XML content
 	if err := s.repo.SaveXMLContent(ctx, id, tenantID, xmlContent); err != nil {
 		return nil, err
@@ -273,8 +352,13 @@ func (s *Service) Submit(ctx context.Context, id, tenantID, userID uuid.UUID, dr
 	defer sessionService.Logout(session)
 
 	// Submit to FinanzOnline
-	uploadService := fonws.NewFileUploadService(s.fonwsClient)
+	rec := rawpayload.NewRecorder()
+	uploadService := fonws.NewFileUploadService(fonws.Recording(s.fonwsClient, rec))
 	result, err := uploadService.SubmitZM(session.Token, foCreds.TID, foCreds.BenID, zm)
+	if errors.Is(err, endpoint.ErrMaintenanceWindow) {
+		// Nothing was sent; the submission stays a draft
+		return nil, err
+	}
 
 	var status string
 	var foRef string
@@ -294,6 +378,15 @@ func (s *Service) Submit(ctx context.Context, id, tenantID, userID uuid.UUID, dr
 		respMsg = result.Message
 	}
 
+	s.payloads.Save(ctx, rawpayload.Reference{
+		TenantID:          &tenantID,
+		Module:            rawpayload.ModuleZM,
+		ReferenceType:     "zm_submission",
+		ReferenceID:       &id,
+		ProviderReference: foRef,
+		Outcome:           rawpayload.OutcomeOf(status == StatusSubmitted, result != nil),
+	}, rec)
+
 	// Update submission with result
 	if err := s.repo.UpdateSubmissionResult(ctx, id, tenantID, foRef, respCode, respMsg, status); err != nil {
 		return nil, err
@@ -303,6 +396,9 @@ func (s *Service) Submit(ctx context.Context, id, tenantID, userID uuid.UUID, dr
 	if err := s.repo.SetSubmittedBy(ctx, id, tenantID, userID); err != nil {
 		return nil, err
 	}
+	if status == StatusSubmitted {
+		s.analytics.Track(ctx, tenantID, userID, analytics.EventFilingSubmitted, analytics.Properties{"kind": "zm"})
+	}
 
 	return s.repo.GetByID(ctx, id, tenantID)
 }
@@ -324,10 +420,37 @@ func (s *Service) GetXML(ctx context.Context, id, tenantID uuid.UUID) ([]byte, e
 		return nil, fmt.Errorf("failed to parse entries: %w", err)
 	}
 
-	zm := s.entriesToFonwsZM(submission.PeriodYear, submission.PeriodQuarter, entries)
+	zm, err := s.toFonwsZM(ctx, submission, entries)
+	if err != nil {
+		return nil, err
+	}
 	return fonws.GenerateZMXML(zm)
 }
 
+// RenderXML re-renders the XML of a submission with the current generator,
+// ignoring the XML stored at submission time
+func (s *Service) RenderXML(ctx context.Context, id, tenantID uuid.UUID) (*Submission, []byte, error) {
+	submission, err := s.repo.GetByID(ctx, id, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(submission.Entries, &entries); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse entries: %w", err)
+	}
+
+	zm, err := s.toFonwsZM(ctx, submission, entries)
+	if err != nil {
+		return nil, nil, err
+	}
+	xmlContent, err := fonws.GenerateZMXML(zm)
+	if err != nil {
+		return nil, nil, err
+	}
+	return submission, xmlContent, nil
+}
+
 // ImportCSV imports ZM entries from CSV
 func (s *Service) ImportCSV(ctx context.Context, tenantID, accountID uuid.UUID, year, quarter int, csvData []byte) (*Submission, error) {
 	entries, err := fonws.ParseZMFromCSV(csvData)
@@ -343,6 +466,7 @@ func (s *Service) ImportCSV(ctx context.Context, tenantID, accountID uuid.UUID,
 			CountryCode:  e.CountryCode,
 			DeliveryType: string(e.DeliveryType),
 			Amount:       e.Amount,
+			Triangular:   e.DeliveryType == fonws.ZMDeliveryTypeTriangular,
 		}
 	}
 
@@ -358,23 +482,53 @@ func (s *Service) ImportCSV(ctx context.Context, tenantID, accountID uuid.UUID,
 
 // Helper methods
 
-func (s *Service) validatePeriod(year, quarter int) error {
+// validatePeriod validates the period of a ZM and returns its quarter. For a
+// monthly ZM the quarter may be 0 and is derived from the month.
+func (s *Service) validatePeriod(year, quarter int, month *int) (int, error) {
 	if year < 2000 || year > 2100 {
-		return ErrInvalidYear
+		return 0, ErrInvalidYear
+	}
+	if month != nil {
+		if *month < 1 || *month > 12 || (quarter != 0 && quarter != quarterOf(*month)) {
+			return 0, ErrInvalidMonth
+		}
+		return quarterOf(*month), nil
 	}
 	if quarter < 1 || quarter > 4 {
-		return ErrInvalidQuarter
+		return 0, ErrInvalidQuarter
+	}
+	return quarter, nil
+}
+
+// quarterOf returns the quarter of a month
+func quarterOf(month int) int {
+	return (month-1)/3 + 1
+}
+
+// normalizeEntries reports flagged Dreiecksgeschäfte with delivery type D
+// and flags the entries of type D
+func normalizeEntries(entries []Entry) error {
+	for i := range entries {
+		e := &entries[i]
+		if e.Triangular && e.DeliveryType == DeliveryTypeServices {
+			return ErrTriangularServices
+		}
+		e.DeliveryType = e.deliveryType()
+		e.Triangular = e.DeliveryType == DeliveryTypeTriangular
 	}
 	return nil
 }
 
-func (s *Service) entriesToFonwsZM(year, quarter int, entries []Entry) *fonws.ZM {
+func (s *Service) entriesToFonwsZM(year, quarter int, month *int, entries []Entry) *fonws.ZM {
 	zm := fonws.NewZM(year, quarter)
+	if month != nil {
+		zm.Month = *month
+	}
 	for _, e := range entries {
 		zm.Entries = append(zm.Entries, fonws.ZMEntry{
 			PartnerUID:   e.PartnerUID,
 			CountryCode:  e.CountryCode,
-			DeliveryType: fonws.ZMDeliveryType(e.DeliveryType),
+			DeliveryType: fonws.ZMDeliveryType(e.deliveryType()),
 			Amount:       e.Amount,
 		})
 	}
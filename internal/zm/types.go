@@ -2,6 +2,7 @@ package zm
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -31,6 +32,7 @@ type Submission struct {
 	AccountID        uuid.UUID       `json:"account_id"`
 	PeriodYear       int             `json:"period_year"`
 	PeriodQuarter    int             `json:"period_quarter"`
+	PeriodMonth      *int            `json:"period_month,omitempty"` // Set for a monthly ZM
 	Entries          json.RawMessage `json:"entries"`
 	EntryCount       int             `json:"entry_count"`
 	TotalAmount      int64           `json:"total_amount"` // In cents
@@ -43,6 +45,8 @@ type Submission struct {
 	SubmittedBy      *uuid.UUID      `json:"submitted_by,omitempty"`
 	ResponseCode     *int            `json:"response_code,omitempty"`
 	ResponseMessage  *string         `json:"response_message,omitempty"`
+	CorrectsID       *uuid.UUID      `json:"corrects_id,omitempty"`  // Filing this Berichtigung corrects
+	CorrectsRef      *string         `json:"corrects_ref,omitempty"` // FO reference of the corrected filing
 	CreatedAt        time.Time       `json:"created_at"`
 	UpdatedAt        time.Time       `json:"updated_at"`
 }
@@ -53,13 +57,27 @@ type Entry struct {
 	CountryCode  string `json:"country_code"`
 	DeliveryType string `json:"delivery_type"` // L, D, or S
 	Amount       int64  `json:"amount"`        // In cents
+
+	// Triangular flags a delivery of goods as Dreiecksgeschäft; it is
+	// reported with delivery type D
+	Triangular bool `json:"triangular,omitempty"`
+}
+
+// deliveryType returns the delivery type the entry is reported with
+func (e *Entry) deliveryType() string {
+	if e.Triangular && e.DeliveryType != DeliveryTypeServices {
+		return DeliveryTypeTriangular
+	}
+	return e.DeliveryType
 }
 
-// CreateSubmissionInput represents input for creating a new ZM submission
+// CreateSubmissionInput represents input for creating a new ZM submission.
+// A monthly ZM has PeriodMonth set; PeriodQuarter may then be omitted.
 type CreateSubmissionInput struct {
 	AccountID     uuid.UUID `json:"account_id"`
 	PeriodYear    int       `json:"period_year"`
 	PeriodQuarter int       `json:"period_quarter"`
+	PeriodMonth   *int      `json:"period_month,omitempty"`
 	Entries       []Entry   `json:"entries"`
 }
 
@@ -84,6 +102,7 @@ type SubmissionResponse struct {
 	AccountID        uuid.UUID       `json:"account_id"`
 	PeriodYear       int             `json:"period_year"`
 	PeriodQuarter    int             `json:"period_quarter"`
+	PeriodMonth      *int            `json:"period_month,omitempty"`
 	Entries          []Entry         `json:"entries"`
 	EntryCount       int             `json:"entry_count"`
 	TotalAmount      int64           `json:"total_amount"`
@@ -92,12 +111,42 @@ type SubmissionResponse struct {
 	ValidationErrors json.RawMessage `json:"validation_errors,omitempty"`
 	Status           string          `json:"status"`
 	FOReference      *string         `json:"fo_reference,omitempty"`
+	CorrectsID       *uuid.UUID      `json:"corrects_id,omitempty"`
 	SubmittedAt      *string         `json:"submitted_at,omitempty"`
 	CreatedAt        string          `json:"created_at"`
 	UpdatedAt        string          `json:"updated_at"`
 }
 
-// PeriodString returns the period in format "Q1/2025"
+// ChainResponse is the API response format of a correction chain
+type ChainResponse struct {
+	Filings []*ChainFilingResponse `json:"filings"`
+}
+
+// ChainFilingResponse is a filing of a correction chain with the lines it
+// changed
+type ChainFilingResponse struct {
+	*SubmissionResponse
+	Delta []EntryDelta `json:"delta,omitempty"`
+}
+
+// PeriodStart returns the first day of the period
+func (s *Submission) PeriodStart() time.Time {
+	return periodStart(s.PeriodYear, s.PeriodQuarter, s.PeriodMonth)
+}
+
+// periodStart returns the first day of a quarter, or of the month if set
+func periodStart(year, quarter int, month *int) time.Time {
+	if month != nil {
+		return time.Date(year, time.Month(*month), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// PeriodString returns the period in format "Q1/2025", or "01/2025" for a
+// monthly ZM
 func (s *Submission) PeriodString() string {
+	if s.PeriodMonth != nil {
+		return fmt.Sprintf("%02d/%d", *s.PeriodMonth, s.PeriodYear)
+	}
 	return "Q" + string(rune('0'+s.PeriodQuarter)) + "/" + string(rune('0'+s.PeriodYear/1000)) + string(rune('0'+(s.PeriodYear/100)%10)) + string(rune('0'+(s.PeriodYear/10)%10)) + string(rune('0'+s.PeriodYear%10))
 }
@@ -0,0 +1,117 @@
+-- Migration: 023_sales_documents
+-- Description: Pre-invoice documents - Angebot, Auftragsbestätigung, Lieferschein
+-- Documents carry their own number ranges and can be converted into each other
+-- and into invoices. source_document_id / invoice_id track the lifecycle.
+
+-- =============================================================================
+-- SALES_DOCUMENTS TABLE - Offers, order confirmations and delivery notes
+-- =============================================================================
+
+CREATE TABLE sales_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    document_type VARCHAR(30) NOT NULL CHECK (document_type IN ('offer', 'order_confirmation', 'delivery_note')),
+    document_number VARCHAR(100) NOT NULL,
+    issue_date DATE NOT NULL,
+    valid_until DATE,   -- Offers: Angebot gültig bis
+    delivery_date DATE, -- Delivery notes / order confirmations
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+
+    -- Seller
+    seller_name VARCHAR(500) NOT NULL,
+    seller_vat VARCHAR(20),
+    seller_address JSONB,
+
+    -- Buyer
+    buyer_id UUID,
+    buyer_name VARCHAR(500) NOT NULL,
+    buyer_vat VARCHAR(20),
+    buyer_address JSONB,
+    buyer_reference VARCHAR(200),
+
+    subject VARCHAR(500),
+    notes TEXT,
+
+    -- Amounts (in cents)
+    tax_exclusive_amount BIGINT NOT NULL DEFAULT 0,
+    tax_amount BIGINT NOT NULL DEFAULT 0,
+    tax_inclusive_amount BIGINT NOT NULL DEFAULT 0,
+
+    status VARCHAR(30) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'sent', 'accepted', 'rejected', 'converted', 'invoiced', 'cancelled')),
+
+    -- Lifecycle links
+    source_document_id UUID REFERENCES sales_documents(id) ON DELETE SET NULL,
+    invoice_id UUID REFERENCES invoices(id) ON DELETE SET NULL,
+
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE(tenant_id, document_type, document_number)
+);
+
+CREATE INDEX idx_sales_documents_tenant ON sales_documents(tenant_id, document_type, issue_date DESC);
+CREATE INDEX idx_sales_documents_status ON sales_documents(tenant_id, status);
+CREATE INDEX idx_sales_documents_source ON sales_documents(source_document_id) WHERE source_document_id IS NOT NULL;
+CREATE INDEX idx_sales_documents_invoice ON sales_documents(invoice_id) WHERE invoice_id IS NOT NULL;
+
+-- =============================================================================
+-- SALES_DOCUMENT_ITEMS TABLE - Line items
+-- =============================================================================
+
+CREATE TABLE sales_document_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    document_id UUID NOT NULL REFERENCES sales_documents(id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL,
+
+    description VARCHAR(1000) NOT NULL,
+    quantity DECIMAL(12, 4) NOT NULL DEFAULT 1,
+    unit_code VARCHAR(20) DEFAULT 'C62',
+    unit_price BIGINT NOT NULL,
+    line_total BIGINT NOT NULL,
+    tax_category VARCHAR(10) DEFAULT 'S',
+    tax_percent DECIMAL(5, 2) NOT NULL DEFAULT 20.00,
+    item_id VARCHAR(100),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sales_document_items_document ON sales_document_items(document_id, line_number);
+
+-- =============================================================================
+-- SALES_DOCUMENT_SEQUENCES TABLE - Separate number ranges per type and year
+-- =============================================================================
+
+CREATE TABLE sales_document_sequences (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    document_type VARCHAR(30) NOT NULL,
+    year INTEGER NOT NULL,
+    last_value INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant_id, document_type, year)
+);
+
+-- =============================================================================
+-- RLS POLICIES
+-- =============================================================================
+
+ALTER TABLE sales_documents ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sales_document_items ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sales_document_sequences ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY sales_documents_tenant_isolation ON sales_documents
+    FOR ALL
+    USING (tenant_id = current_setting('app.tenant_id', true)::uuid);
+
+CREATE POLICY sales_document_items_tenant_isolation ON sales_document_items
+    FOR ALL
+    USING (
+        document_id IN (
+            SELECT id FROM sales_documents
+            WHERE tenant_id = current_setting('app.tenant_id', true)::uuid
+        )
+    );
+
+CREATE POLICY sales_document_sequences_tenant_isolation ON sales_document_sequences
+    FOR ALL
+    USING (tenant_id = current_setting('app.tenant_id', true)::uuid);
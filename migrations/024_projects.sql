@@ -0,0 +1,107 @@
+-- Migration: 024_projects
+-- Description: Project / cost-center dimension (Projekte, Kostenstellen)
+-- Invoices and payment batches can reference a project; working time and
+-- travel expenses are booked directly on projects for profitability reports.
+
+-- =============================================================================
+-- PROJECTS TABLE
+-- =============================================================================
+
+CREATE TABLE projects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL DEFAULT 'project' CHECK (kind IN ('project', 'cost_center')),
+    client_name VARCHAR(500),
+    description TEXT,
+
+    budget_amount BIGINT, -- In cents
+    hourly_rate BIGINT,   -- In cents, default for time entries
+
+    start_date DATE,
+    end_date DATE,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'closed')),
+
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE(tenant_id, code)
+);
+
+CREATE INDEX idx_projects_tenant ON projects(tenant_id, status);
+
+-- =============================================================================
+-- PROJECT_TIME_ENTRIES TABLE - Working time
+-- =============================================================================
+
+CREATE TABLE project_time_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    work_date DATE NOT NULL,
+    minutes INTEGER NOT NULL CHECK (minutes > 0 AND minutes <= 1440),
+    description TEXT,
+    billable BOOLEAN NOT NULL DEFAULT TRUE,
+    hourly_rate BIGINT NOT NULL DEFAULT 0, -- In cents, snapshot at booking time
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_project_time_entries_project ON project_time_entries(project_id, work_date DESC);
+CREATE INDEX idx_project_time_entries_user ON project_time_entries(tenant_id, user_id, work_date DESC);
+
+-- =============================================================================
+-- PROJECT_EXPENSES TABLE - Travel expenses (Reisekosten) and other costs
+-- =============================================================================
+
+CREATE TABLE project_expenses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    expense_date DATE NOT NULL,
+    category VARCHAR(30) NOT NULL CHECK (category IN ('travel', 'mileage', 'per_diem', 'accommodation', 'other')),
+    description TEXT,
+    kilometers DECIMAL(10, 2),
+    amount BIGINT NOT NULL CHECK (amount > 0), -- In cents
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_project_expenses_project ON project_expenses(project_id, expense_date DESC);
+
+-- =============================================================================
+-- PROJECT REFERENCES ON INVOICES AND PAYMENTS
+-- =============================================================================
+
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS project_id UUID REFERENCES projects(id) ON DELETE SET NULL;
+ALTER TABLE payment_batches ADD COLUMN IF NOT EXISTS project_id UUID REFERENCES projects(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_invoices_project ON invoices(project_id) WHERE project_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_payment_batches_project ON payment_batches(project_id) WHERE project_id IS NOT NULL;
+
+-- =============================================================================
+-- RLS POLICIES
+-- =============================================================================
+
+ALTER TABLE projects ENABLE ROW LEVEL SECURITY;
+ALTER TABLE project_time_entries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE project_expenses ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY projects_tenant_isolation ON projects
+    FOR ALL
+    USING (tenant_id = current_setting('app.tenant_id', true)::uuid);
+
+CREATE POLICY project_time_entries_tenant_isolation ON project_time_entries
+    FOR ALL
+    USING (tenant_id = current_setting('app.tenant_id', true)::uuid);
+
+CREATE POLICY project_expenses_tenant_isolation ON project_expenses
+    FOR ALL
+    USING (tenant_id = current_setting('app.tenant_id', true)::uuid);
@@ -0,0 +1,38 @@
+-- Migration: 025_vat_schemes
+-- Description: VAT scheme (Ist- vs. Sollbesteuerung) per tenant and company
+-- The scheme decides whether UVA Kennzahlen are derived from invoice dates
+-- (Soll) or payment dates (Ist). Entries form a history so that switch-overs
+-- (§ 17 Abs. 4 UStG) are evaluated against the scheme in effect at the time.
+
+-- =============================================================================
+-- UVA_VAT_SCHEMES TABLE
+-- =============================================================================
+
+CREATE TABLE uva_vat_schemes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    account_id UUID REFERENCES accounts(id) ON DELETE CASCADE, -- NULL = tenant default
+
+    scheme VARCHAR(10) NOT NULL CHECK (scheme IN ('soll', 'ist')),
+    effective_from DATE NOT NULL CHECK (EXTRACT(DAY FROM effective_from) = 1),
+
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_uva_vat_schemes_unique
+    ON uva_vat_schemes(tenant_id, COALESCE(account_id, '00000000-0000-0000-0000-000000000000'::uuid), effective_from);
+
+-- Receipts for Istbesteuerung are looked up by matched invoice and booking date
+CREATE INDEX IF NOT EXISTS idx_transactions_matched_invoice
+    ON transactions(matched_invoice_id, booking_date) WHERE matched_invoice_id IS NOT NULL;
+
+-- =============================================================================
+-- RLS POLICIES
+-- =============================================================================
+
+ALTER TABLE uva_vat_schemes ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY uva_vat_schemes_tenant_isolation ON uva_vat_schemes
+    FOR ALL
+    USING (tenant_id = current_setting('app.tenant_id', true)::uuid);
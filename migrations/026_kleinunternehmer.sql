@@ -0,0 +1,52 @@
+-- Migration: 026_kleinunternehmer
+-- Description: Kleinunternehmer threshold monitoring (§ 6 Abs. 1 Z 27 UStG)
+-- Tenants using the Kleinunternehmerregelung are checked against the 55.000 EUR
+-- gross turnover limit; each alert level raises one action item per year.
+
+-- =============================================================================
+-- KLEINUNTERNEHMER_SETTINGS TABLE
+-- =============================================================================
+
+CREATE TABLE kleinunternehmer_settings (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    threshold_cents BIGINT NOT NULL DEFAULT 5500000 CHECK (threshold_cents > 0),
+    warn_percent INTEGER NOT NULL DEFAULT 80 CHECK (warn_percent BETWEEN 1 AND 99),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_kleinunternehmer_settings_enabled ON kleinunternehmer_settings(enabled) WHERE enabled = TRUE;
+
+-- =============================================================================
+-- KLEINUNTERNEHMER_ALERTS TABLE - Raised alert levels per year
+-- =============================================================================
+
+CREATE TABLE kleinunternehmer_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    year INTEGER NOT NULL,
+    level VARCHAR(20) NOT NULL CHECK (level IN ('watch', 'warning', 'critical', 'exceeded')),
+    severity INTEGER NOT NULL,
+    revenue BIGINT NOT NULL, -- Gross revenue year-to-date in cents when raised
+    action_item_id UUID REFERENCES action_items(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE(tenant_id, year, level)
+);
+
+CREATE INDEX idx_kleinunternehmer_alerts_tenant ON kleinunternehmer_alerts(tenant_id, year, severity DESC);
+
+-- =============================================================================
+-- RLS POLICIES
+-- =============================================================================
+
+ALTER TABLE kleinunternehmer_settings ENABLE ROW LEVEL SECURITY;
+ALTER TABLE kleinunternehmer_alerts ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY kleinunternehmer_settings_tenant_isolation ON kleinunternehmer_settings
+    FOR ALL
+    USING (tenant_id = current_setting('app.tenant_id', true)::uuid);
+
+CREATE POLICY kleinunternehmer_alerts_tenant_isolation ON kleinunternehmer_alerts
+    FOR ALL
+    USING (tenant_id = current_setting('app.tenant_id', true)::uuid);
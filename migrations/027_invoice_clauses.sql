@@ -0,0 +1,42 @@
+-- Migration: 027_invoice_clauses
+-- Description: Sector-specific invoice clauses (Reverse Charge, Differenzbesteuerung, ...)
+-- The built-in clause library lives in code; tenants can add their own clauses or
+-- override a built-in clause by reusing its code.
+
+-- =============================================================================
+-- INVOICES - Branch, special tax code and applied clauses
+-- =============================================================================
+
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS branch VARCHAR(50);
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS tax_code VARCHAR(50);
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS applied_clauses TEXT[];
+
+-- =============================================================================
+-- INVOICE_CLAUSES TABLE - Tenant clause library
+-- =============================================================================
+
+CREATE TABLE invoice_clauses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    code VARCHAR(100) NOT NULL,
+    clause_group VARCHAR(100) NOT NULL DEFAULT '', -- Only the most specific clause per group applies
+    branch VARCHAR(50) NOT NULL DEFAULT '',
+    tax_code VARCHAR(50) NOT NULL DEFAULT '',
+    tax_category VARCHAR(10) NOT NULL DEFAULT '', -- EN 16931 tax category (AE, K, G, ...)
+    text TEXT NOT NULL,
+    legal_basis VARCHAR(255) NOT NULL DEFAULT '',
+    mandatory BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE(tenant_id, code)
+);
+
+-- =============================================================================
+-- RLS POLICIES
+-- =============================================================================
+
+ALTER TABLE invoice_clauses ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY invoice_clauses_tenant_isolation ON invoice_clauses
+    FOR ALL
+    USING (tenant_id = current_setting('app.tenant_id', true)::uuid);
@@ -0,0 +1,115 @@
+-- Migration: 028_buak
+-- Description: BUAK Zuschlagsmeldungen for construction workers (BUAG) including
+-- leased workers under the AÜG. Workers build on the ELDA Anmeldungen.
+
+-- ============================================================================
+-- BUAK Arbeitnehmer
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS buak_arbeitnehmer (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    elda_account_id UUID NOT NULL REFERENCES elda_accounts(id) ON DELETE CASCADE,
+    meldung_id UUID REFERENCES elda_meldungen(id) ON DELETE SET NULL,  -- ELDA Anmeldung
+
+    -- Dienstnehmer
+    sv_nummer VARCHAR(10) NOT NULL,
+    vorname VARCHAR(100) NOT NULL,
+    nachname VARCHAR(100) NOT NULL,
+    buak_nummer VARCHAR(20) NOT NULL DEFAULT '',  -- Arbeitnehmernummer der BUAK
+
+    -- Beschäftigung
+    kategorie VARCHAR(20) NOT NULL DEFAULT 'arbeiter',  -- arbeiter, lehrling, ueberlassen
+    taetigkeit VARCHAR(255) NOT NULL DEFAULT '',
+    stundenlohn BIGINT NOT NULL,  -- KV-Stundenlohn in cents
+    wochenstunden DECIMAL(5,2) NOT NULL DEFAULT 0,
+    eintrittsdatum DATE NOT NULL,
+    austrittsdatum DATE,
+
+    -- Arbeitskräfteüberlassung (Beschäftiger, Zeitraum)
+    ueberlassung JSONB,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE(elda_account_id, sv_nummer)
+);
+
+CREATE INDEX IF NOT EXISTS idx_buak_arbeitnehmer_account ON buak_arbeitnehmer(elda_account_id);
+
+-- ============================================================================
+-- BUAK Zuschlagsmeldungen
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS buak_meldungen (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    elda_account_id UUID NOT NULL REFERENCES elda_accounts(id) ON DELETE CASCADE,
+
+    -- Period
+    year INTEGER NOT NULL,
+    month INTEGER NOT NULL CHECK (month BETWEEN 1 AND 12),
+
+    -- Status
+    status VARCHAR(50) NOT NULL DEFAULT 'draft',  -- draft, validated, submitted, confirmed, rejected
+    protokollnummer VARCHAR(50) NOT NULL DEFAULT '',
+
+    -- Totals in cents
+    total_arbeitnehmer INTEGER NOT NULL DEFAULT 0,
+    total_lohnsumme BIGINT NOT NULL DEFAULT 0,
+    total_zuschlag BIGINT NOT NULL DEFAULT 0,
+
+    -- Response / confirmation
+    submitted_at TIMESTAMPTZ,
+    confirmed_at TIMESTAMPTZ,
+    confirmation_number VARCHAR(50) NOT NULL DEFAULT '',
+    request_xml TEXT NOT NULL DEFAULT '',
+    response_xml TEXT NOT NULL DEFAULT '',
+    error_message TEXT NOT NULL DEFAULT '',
+    error_code VARCHAR(20) NOT NULL DEFAULT '',
+
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE(elda_account_id, year, month)
+);
+
+CREATE INDEX IF NOT EXISTS idx_buak_meldungen_account ON buak_meldungen(elda_account_id);
+CREATE INDEX IF NOT EXISTS idx_buak_meldungen_status ON buak_meldungen(status);
+
+-- ============================================================================
+-- BUAK Positionen
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS buak_positionen (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    meldung_id UUID NOT NULL REFERENCES buak_meldungen(id) ON DELETE CASCADE,
+    arbeitnehmer_id UUID REFERENCES buak_arbeitnehmer(id) ON DELETE SET NULL,
+
+    -- Dienstnehmer (snapshot)
+    sv_nummer VARCHAR(10) NOT NULL,
+    vorname VARCHAR(100) NOT NULL,
+    nachname VARCHAR(100) NOT NULL,
+    buak_nummer VARCHAR(20) NOT NULL DEFAULT '',
+    kategorie VARCHAR(20) NOT NULL,
+
+    -- Stunden und Lohn (cents)
+    stunden DECIMAL(7,2) NOT NULL,
+    stundenlohn BIGINT NOT NULL,
+    lohnsumme BIGINT NOT NULL,
+
+    -- Zuschläge (cents)
+    zuschlag_urlaub BIGINT NOT NULL DEFAULT 0,
+    zuschlag_abfertigung BIGINT NOT NULL DEFAULT 0,
+    zuschlag_schlechtwetter BIGINT NOT NULL DEFAULT 0,
+    zuschlag_ueberbrueckung BIGINT NOT NULL DEFAULT 0,
+
+    -- AÜG
+    beschaeftiger_name VARCHAR(255) NOT NULL DEFAULT '',
+    beschaeftiger_uid VARCHAR(20) NOT NULL DEFAULT '',
+
+    position_index INTEGER NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_buak_positionen_meldung ON buak_positionen(meldung_id);
+CREATE INDEX IF NOT EXISTS idx_buak_positionen_sv ON buak_positionen(sv_nummer);
@@ -0,0 +1,76 @@
+-- Migration: 029_abwesenheiten
+-- Description: Absence tracking (Krankenstand, Arbeitsunfall, Urlaub, Pflegeurlaub)
+-- and Arbeits- und Entgeltbestätigungen (AUA) requested by the ÖGK.
+
+-- ============================================================================
+-- Abwesenheiten
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS abwesenheiten (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    elda_account_id UUID NOT NULL REFERENCES elda_accounts(id) ON DELETE CASCADE,
+
+    -- Dienstnehmer
+    sv_nummer VARCHAR(10) NOT NULL,
+    vorname VARCHAR(100) NOT NULL,
+    nachname VARCHAR(100) NOT NULL,
+
+    -- Abwesenheit
+    art VARCHAR(20) NOT NULL CHECK (art IN ('krankenstand', 'arbeitsunfall', 'urlaub', 'pflegeurlaub')),
+    von DATE NOT NULL,
+    bis DATE,  -- NULL while a Krankenstand is ongoing
+    bestaetigung BOOLEAN NOT NULL DEFAULT FALSE,  -- Arbeitsunfähigkeitsbestätigung vorgelegt
+    bemerkung TEXT,
+
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    CHECK (bis IS NULL OR bis >= von)
+);
+
+CREATE INDEX IF NOT EXISTS idx_abwesenheiten_account ON abwesenheiten(elda_account_id);
+CREATE INDEX IF NOT EXISTS idx_abwesenheiten_sv ON abwesenheiten(elda_account_id, sv_nummer, von);
+
+-- ============================================================================
+-- Arbeits- und Entgeltbestätigungen
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS aua_meldungen (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    abwesenheit_id UUID NOT NULL REFERENCES abwesenheiten(id) ON DELETE CASCADE,
+    elda_account_id UUID NOT NULL REFERENCES elda_accounts(id) ON DELETE CASCADE,
+    sv_nummer VARCHAR(10) NOT NULL,
+
+    -- Status
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',  -- draft, submitted, accepted, rejected
+
+    -- Anforderung der ÖGK
+    anforderungsdatum DATE NOT NULL,
+    anforderungsreferenz VARCHAR(50),
+
+    -- Arbeitsbestätigung
+    arbeitsunfall BOOLEAN NOT NULL DEFAULT FALSE,
+    letzter_arbeitstag DATE NOT NULL,
+    arbeitsunfaehig_ab DATE NOT NULL,
+    entgelt_voll_bis DATE NOT NULL,
+    entgelt_halb_bis DATE,
+
+    -- Entgeltbestätigung (cents)
+    beitragsgrundlage BIGINT NOT NULL,
+    sonderzahlungen BIGINT NOT NULL DEFAULT 0,
+
+    -- ELDA Response
+    protokollnummer VARCHAR(50),
+    submitted_at TIMESTAMPTZ,
+    request_xml TEXT,
+    error_code VARCHAR(20),
+    error_message TEXT,
+
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_aua_meldungen_abwesenheit ON aua_meldungen(abwesenheit_id);
+CREATE INDEX IF NOT EXISTS idx_aua_meldungen_account ON aua_meldungen(elda_account_id);
@@ -0,0 +1,29 @@
+-- Migration: 030_teilzeit_szenarien
+-- Description: Altersteilzeit and Wiedereingliederungsteilzeit scenarios stored
+-- per employee, with links to the pre-drafted ELDA Änderungsmeldungen.
+
+CREATE TABLE IF NOT EXISTS teilzeit_szenarien (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    elda_account_id UUID NOT NULL REFERENCES elda_accounts(id) ON DELETE CASCADE,
+
+    -- Dienstnehmer
+    sv_nummer VARCHAR(10) NOT NULL,
+    vorname VARCHAR(100) NOT NULL DEFAULT '',
+    nachname VARCHAR(100) NOT NULL DEFAULT '',
+
+    bezeichnung VARCHAR(255) NOT NULL DEFAULT '',
+    modell VARCHAR(30) NOT NULL CHECK (modell IN ('altersteilzeit', 'wiedereingliederung')),
+
+    -- Calculation inputs and results (amounts in cents)
+    eingabe JSONB NOT NULL,
+    ergebnis JSONB NOT NULL,
+
+    -- Pre-drafted Änderungsmeldungen (elda_meldungen)
+    meldung_ids UUID[] NOT NULL DEFAULT '{}',
+
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_teilzeit_szenarien_sv ON teilzeit_szenarien(elda_account_id, sv_nummer);
@@ -0,0 +1,46 @@
+-- Migration: 031_classification_feedback
+-- Description: Per-tenant document type taxonomy extensions and user
+-- re-classifications captured as feedback for accuracy reporting.
+
+CREATE TABLE IF NOT EXISTS document_type_definitions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    code VARCHAR(50) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    description TEXT,
+    keywords TEXT[] NOT NULL DEFAULT '{}',
+    base_type VARCHAR(50), -- built-in type the custom type refines
+    requires_action BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    UNIQUE (tenant_id, code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_type_definitions_tenant ON document_type_definitions(tenant_id) WHERE is_active;
+
+CREATE TABLE IF NOT EXISTS classification_feedback (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    analysis_id UUID NOT NULL REFERENCES document_analyses(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+
+    -- Original model prediction
+    predicted_type VARCHAR(50) NOT NULL DEFAULT '',
+    predicted_subtype VARCHAR(50),
+    predicted_confidence DECIMAL(5,4) NOT NULL DEFAULT 0,
+
+    -- User correction
+    corrected_type VARCHAR(50) NOT NULL,
+    corrected_subtype VARCHAR(50),
+    corrected_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    notes TEXT,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_classification_feedback_tenant ON classification_feedback(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_classification_feedback_analysis ON classification_feedback(analysis_id, created_at);
@@ -0,0 +1,42 @@
+-- Migration: 032_raw_payloads
+-- Description: Encrypted retention of raw FinanzOnline and ELDA request and
+-- response bodies, kept as evidence for disputed submissions.
+
+CREATE TABLE IF NOT EXISTS raw_payloads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    elda_account_id UUID REFERENCES elda_accounts(id) ON DELETE SET NULL,
+
+    -- Exchange
+    module VARCHAR(10) NOT NULL CHECK (module IN ('uva', 'zm', 'uid', 'elda')),
+    operation VARCHAR(100) NOT NULL DEFAULT '',
+    endpoint TEXT NOT NULL DEFAULT '',
+    attempt INTEGER NOT NULL DEFAULT 1,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+
+    -- Business reference
+    reference_type VARCHAR(50) NOT NULL,
+    reference_id UUID,
+    provider_reference VARCHAR(100) NOT NULL DEFAULT '',
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('success', 'rejected', 'error')),
+
+    -- Bodies, AES-256-GCM encrypted
+    request_encrypted BYTEA,
+    response_encrypted BYTEA,
+    request_size INTEGER NOT NULL DEFAULT 0,
+    response_size INTEGER NOT NULL DEFAULT 0,
+    truncated BOOLEAN NOT NULL DEFAULT FALSE,
+
+    -- Retention and access
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    download_count INTEGER NOT NULL DEFAULT 0,
+    last_downloaded_at TIMESTAMP WITH TIME ZONE,
+    last_downloaded_by UUID REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_raw_payloads_tenant ON raw_payloads(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_raw_payloads_reference ON raw_payloads(reference_id) WHERE reference_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_raw_payloads_expires ON raw_payloads(expires_at);
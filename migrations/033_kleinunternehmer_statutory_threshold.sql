@@ -0,0 +1,11 @@
+-- Migration: 033_kleinunternehmer_statutory_threshold
+-- Description: The Kleinunternehmer threshold follows the statutory value of
+-- each year from the reference parameters; threshold_cents only stores a
+-- custom threshold chosen by the tenant.
+
+ALTER TABLE kleinunternehmer_settings
+    ALTER COLUMN threshold_cents DROP NOT NULL,
+    ALTER COLUMN threshold_cents DROP DEFAULT;
+
+-- Rows still on the former default follow the statutory threshold from now on
+UPDATE kleinunternehmer_settings SET threshold_cents = NULL WHERE threshold_cents = 5500000;
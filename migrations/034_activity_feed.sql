@@ -0,0 +1,61 @@
+-- Migration: 034_activity_feed
+-- Description: Status history and comments behind the per-entity activity feed
+-- ("who changed this invoice and when").
+
+-- Status history of invoices and documents. Rows are written by trigger, so
+-- changes made by the API, the databox sync and the worker are all recorded.
+-- Förderungsanträge keep their history in foerderungs_antraege.timeline.
+CREATE TABLE IF NOT EXISTS entity_status_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id UUID NOT NULL,
+    old_status VARCHAR(50), -- NULL when the entity was created
+    new_status VARCHAR(50) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_status_changes_entity
+    ON entity_status_changes(entity_type, entity_id, changed_at DESC);
+
+CREATE OR REPLACE FUNCTION record_entity_status_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO entity_status_changes (tenant_id, entity_type, entity_id, old_status, new_status)
+        VALUES (NEW.tenant_id, TG_ARGV[0], NEW.id, NULL, NEW.status);
+    ELSIF NEW.status IS DISTINCT FROM OLD.status THEN
+        INSERT INTO entity_status_changes (tenant_id, entity_type, entity_id, old_status, new_status)
+        VALUES (NEW.tenant_id, TG_ARGV[0], NEW.id, OLD.status, NEW.status);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS invoices_status_history ON invoices;
+CREATE TRIGGER invoices_status_history
+    AFTER INSERT OR UPDATE OF status ON invoices
+    FOR EACH ROW EXECUTE FUNCTION record_entity_status_change('invoice');
+
+DROP TRIGGER IF EXISTS documents_status_history ON documents;
+CREATE TRIGGER documents_status_history
+    AFTER INSERT OR UPDATE OF status ON documents
+    FOR EACH ROW EXECUTE FUNCTION record_entity_status_change('document');
+
+-- Comments on invoices, documents and Förderungsanträge
+CREATE TABLE IF NOT EXISTS entity_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('invoice', 'document', 'antrag')),
+    entity_id UUID NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_comments_entity
+    ON entity_comments(entity_type, entity_id, created_at DESC);
+
+-- Webhook deliveries are matched on the entity ID in the event data
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_data
+    ON webhook_deliveries USING GIN ((payload -> 'data'));
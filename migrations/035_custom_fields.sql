@@ -0,0 +1,41 @@
+-- Migration: 035_custom_fields
+-- Description: Tenant-configurable custom fields for invoices, clients and
+-- Förderungsanträge (internal reference, branch code, ...).
+
+-- Field definitions per tenant and entity type. The key is the name of the
+-- value in the entity's custom_fields object and cannot change once values
+-- may exist; label, required flag, options and position can.
+CREATE TABLE IF NOT EXISTS custom_field_definitions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    entity_type VARCHAR(20) NOT NULL,
+    key VARCHAR(50) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    field_type VARCHAR(20) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    options JSONB NOT NULL DEFAULT '[]', -- allowed values of enum fields
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT custom_field_definitions_entity_check CHECK (entity_type IN ('invoice', 'client', 'antrag')),
+    CONSTRAINT custom_field_definitions_type_check CHECK (field_type IN ('text', 'number', 'date', 'enum')),
+    CONSTRAINT custom_field_definitions_key_unique UNIQUE (tenant_id, entity_type, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_custom_field_definitions_tenant
+    ON custom_field_definitions(tenant_id, entity_type, position);
+
+-- Values are stored as one JSON object per row, keyed by definition key.
+-- Numbers are JSON numbers, dates YYYY-MM-DD strings. The GIN indexes serve
+-- the containment (@>) filters of the list endpoints.
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE foerderungs_antraege ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_invoices_custom_fields
+    ON invoices USING GIN (custom_fields jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_clients_custom_fields
+    ON clients USING GIN (custom_fields jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_foerderungs_antraege_custom_fields
+    ON foerderungs_antraege USING GIN (custom_fields jsonb_path_ops);
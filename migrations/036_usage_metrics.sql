@@ -0,0 +1,35 @@
+-- Migration: 036_usage_metrics
+-- Description: Downsampled per-tenant usage time series for tenant-facing graphs
+
+-- Daily values per tenant and metric, days in Europe/Vienna
+CREATE TABLE IF NOT EXISTS usage_metrics_daily (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    metric VARCHAR(50) NOT NULL,
+    day DATE NOT NULL,
+    value BIGINT NOT NULL DEFAULT 0,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, metric, day)
+);
+
+-- Monthly roll-up of the daily values, month is the first day of the month
+CREATE TABLE IF NOT EXISTS usage_metrics_monthly (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    metric VARCHAR(50) NOT NULL,
+    month DATE NOT NULL CHECK (EXTRACT(DAY FROM month) = 1),
+    value BIGINT NOT NULL DEFAULT 0,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, metric, month)
+);
+
+-- Aggregation runs; the latest day_to is the "as of" date of the series
+CREATE TABLE IF NOT EXISTS usage_aggregation_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    day_from DATE NOT NULL,
+    day_to DATE NOT NULL,
+    rows_written BIGINT NOT NULL DEFAULT 0,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (day_to >= day_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_aggregation_runs_day_to ON usage_aggregation_runs(day_to DESC);
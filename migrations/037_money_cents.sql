@@ -0,0 +1,47 @@
+-- Migration: 037_money_cents
+-- Description: Store extracted amounts and analysis costs as integer cents
+
+-- Extracted amounts: DECIMAL euros -> BIGINT cents. The old columns stay
+-- nullable for one release so that a rollback can still read them.
+ALTER TABLE extracted_amounts ADD COLUMN IF NOT EXISTS amount_cents BIGINT;
+ALTER TABLE extracted_amounts ADD COLUMN IF NOT EXISTS original_amount_cents BIGINT;
+
+UPDATE extracted_amounts
+SET amount_cents = ROUND(amount * 100)::BIGINT
+WHERE amount_cents IS NULL AND amount IS NOT NULL;
+
+UPDATE extracted_amounts
+SET original_amount_cents = ROUND(original_amount * 100)::BIGINT
+WHERE original_amount_cents IS NULL AND original_amount IS NOT NULL;
+
+UPDATE extracted_amounts SET amount_cents = 0 WHERE amount_cents IS NULL;
+
+ALTER TABLE extracted_amounts ALTER COLUMN amount_cents SET DEFAULT 0;
+ALTER TABLE extracted_amounts ALTER COLUMN amount_cents SET NOT NULL;
+ALTER TABLE extracted_amounts ALTER COLUMN amount DROP NOT NULL;
+
+COMMENT ON COLUMN extracted_amounts.amount IS 'Deprecated: use amount_cents';
+COMMENT ON COLUMN extracted_amounts.original_amount IS 'Deprecated: use original_amount_cents';
+
+-- Analysis and AI usage costs are kept in cost_cents. Installations that
+-- recorded a float estimated_cost in euros get it carried over.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'document_analyses' AND column_name = 'estimated_cost'
+    ) THEN
+        UPDATE document_analyses
+        SET cost_cents = ROUND(estimated_cost * 100)::INTEGER
+        WHERE cost_cents IS NULL AND estimated_cost IS NOT NULL;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'ai_usage_logs' AND column_name = 'estimated_cost'
+    ) THEN
+        UPDATE ai_usage_logs
+        SET cost_cents = ROUND(estimated_cost * 100)::INTEGER
+        WHERE cost_cents = 0 AND estimated_cost IS NOT NULL;
+    END IF;
+END $$;
@@ -0,0 +1,19 @@
+-- Migration: 038_storage_quota
+-- Description: Per-tenant soft quota on document storage
+
+-- Tenants without a row get the server default (STORAGE_DEFAULT_QUOTA_BYTES)
+CREATE TABLE IF NOT EXISTS tenant_storage_quotas (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    quota_bytes BIGINT NOT NULL CHECK (quota_bytes >= 0), -- 0 = unlimited
+    warn_percent INTEGER NOT NULL DEFAULT 80 CHECK (warn_percent BETWEEN 1 AND 100),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Storage accounting sums file sizes per tenant and type
+CREATE INDEX IF NOT EXISTS idx_documents_tenant_type ON documents(tenant_id, type);
+
+-- Lifecycle suggestions look for old analyses that still carry their text
+CREATE INDEX IF NOT EXISTS idx_document_analyses_tenant_created
+    ON document_analyses(tenant_id, created_at)
+    WHERE extracted_text IS NOT NULL;
@@ -0,0 +1,24 @@
+-- Migration: 039_analysis_text_compaction
+-- Description: Move large extracted analysis texts into a zstd-compressed side table
+
+-- Full text of compacted analyses; the analysis row keeps a preview
+CREATE TABLE IF NOT EXISTS document_analysis_texts (
+    analysis_id UUID PRIMARY KEY REFERENCES document_analyses(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    encoding VARCHAR(10) NOT NULL DEFAULT 'zstd' CHECK (encoding IN ('zstd')),
+    content BYTEA NOT NULL,
+    original_bytes INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_analysis_texts_tenant ON document_analysis_texts(tenant_id);
+
+-- The content is already compressed, TOAST compression would only cost CPU
+ALTER TABLE document_analysis_texts ALTER COLUMN content SET STORAGE EXTERNAL;
+
+ALTER TABLE document_analyses ADD COLUMN IF NOT EXISTS text_compacted BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Rows the analysis_text_compaction job still has to look at
+CREATE INDEX IF NOT EXISTS idx_document_analyses_uncompacted
+    ON document_analyses(created_at)
+    WHERE NOT text_compacted AND extracted_text IS NOT NULL;
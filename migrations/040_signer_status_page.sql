@@ -0,0 +1,16 @@
+-- Migration: 040_signer_status_page
+-- Description: Status token and signing link re-requests for external signers
+
+-- The status token opens the signer's status page. Unlike the signing token
+-- it is not rotated and stays valid after signing.
+ALTER TABLE signers ADD COLUMN IF NOT EXISTS status_token VARCHAR(255);
+
+UPDATE signers SET status_token = encode(gen_random_bytes(32), 'hex') WHERE status_token IS NULL;
+
+ALTER TABLE signers ALTER COLUMN status_token SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signers_status_token ON signers(status_token);
+
+-- Signing links re-requested from the status page
+ALTER TABLE signers ADD COLUMN IF NOT EXISTS link_request_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE signers ADD COLUMN IF NOT EXISTS last_link_request_at TIMESTAMPTZ;
@@ -0,0 +1,52 @@
+-- Migration: 041_mail
+-- Description: Outgoing mail: tenant sender identities, suppression list and provider events
+
+-- Per-tenant from address. Mail is sent from it only once the domain's SPF
+-- and DKIM records are verified; until then the platform address is used
+-- with the tenant address as Reply-To.
+CREATE TABLE IF NOT EXISTS mail_sender_identities (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    from_address VARCHAR(255) NOT NULL,
+    from_name VARCHAR(255),
+    reply_to VARCHAR(255),
+    domain VARCHAR(255) NOT NULL,
+    spf_verified BOOLEAN NOT NULL DEFAULT false,
+    dkim_verified BOOLEAN NOT NULL DEFAULT false,
+    dmarc_present BOOLEAN NOT NULL DEFAULT false,
+    verified_at TIMESTAMPTZ,
+    last_checked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Addresses no sender may mail. Bounces and complaints are global
+-- (tenant_id NULL); manual entries and unsubscribes belong to a tenant.
+-- Temporary suppressions (soft bounces) carry an expiry.
+CREATE TABLE IF NOT EXISTS mail_suppressions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('hard_bounce', 'soft_bounce', 'complaint', 'unsubscribe', 'manual')),
+    source VARCHAR(50) NOT NULL,
+    details TEXT,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mail_suppressions_scope
+    ON mail_suppressions(lower(email), COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'::uuid));
+CREATE INDEX IF NOT EXISTS idx_mail_suppressions_tenant ON mail_suppressions(tenant_id) WHERE tenant_id IS NOT NULL;
+
+-- Bounce and complaint events reported by the provider webhooks. Soft
+-- bounces only suppress an address once they repeat.
+CREATE TABLE IF NOT EXISTS mail_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider VARCHAR(20) NOT NULL,
+    event_type VARCHAR(20) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    message_id VARCHAR(255),
+    details TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mail_events_email ON mail_events(lower(email), created_at DESC);
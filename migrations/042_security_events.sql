@@ -0,0 +1,33 @@
+-- Migration: 042_security_events
+-- Description: Security event stream for SIEM export, separate from the business audit log
+
+-- Authentication failures, permission denials, cross-tenant access attempts,
+-- rate-limit hits and threshold alerts. seq is the export cursor: SIEM
+-- collectors poll for events after the last sequence number they saw.
+-- No foreign keys: events must survive the deletion of the tenant or user
+-- they name, and attackers send identifiers that do not exist.
+CREATE TABLE IF NOT EXISTS security_events (
+    seq BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    severity VARCHAR(20) NOT NULL CHECK (severity IN ('info', 'low', 'medium', 'high', 'critical')),
+    outcome VARCHAR(20) NOT NULL,
+    tenant_id UUID,
+    user_id UUID,
+    user_email VARCHAR(255),
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(500),
+    request_id VARCHAR(100),
+    method VARCHAR(10),
+    path VARCHAR(500),
+    resource VARCHAR(100),
+    action VARCHAR(100),
+    message TEXT,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_occurred_at ON security_events(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_security_events_type ON security_events(event_type, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_security_events_ip ON security_events(ip_address, occurred_at DESC);
@@ -0,0 +1,38 @@
+-- Migration: 043_backups
+-- Description: Backup manifests, restore checks and job queue pauses for consistent backups
+
+-- A pause stops all workers from claiming jobs until it is lifted or
+-- expires. The expiry bounds the pause if the process holding it dies.
+CREATE TABLE IF NOT EXISTS job_queue_pauses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reason VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_queue_pauses_expires ON job_queue_pauses(expires_at);
+
+-- One row per backup run. The manifest records what the snapshot should
+-- contain; the restore check restores it into a scratch database and
+-- compares.
+CREATE TABLE IF NOT EXISTS backups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    label VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed')),
+    schema_version VARCHAR(255),
+    object_counts JSONB NOT NULL DEFAULT '{}',
+    db_snapshot TEXT,
+    storage_snapshot TEXT,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    verification_status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (verification_status IN ('pending', 'running', 'verified', 'drift', 'failed', 'skipped')),
+    verification_details JSONB,
+    verified_at TIMESTAMPTZ
+);
+
+-- At most one backup runs at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_backups_one_running ON backups((true)) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_backups_started ON backups(started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_backups_verification ON backups(completed_at) WHERE status = 'completed' AND verification_status = 'pending';
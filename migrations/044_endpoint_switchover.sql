@@ -0,0 +1,31 @@
+-- Migration: 044_endpoint_switchover
+-- Description: Scheduled endpoint switchovers and maintenance windows for external integrations
+
+-- Endpoint sets (e.g. the current and the migrated FinanzOnline endpoint)
+-- are configured per deployment; these rows only select between them. The
+-- latest switchover that is due decides the active set, so an immediate
+-- toggle is a switchover at NOW().
+CREATE TABLE IF NOT EXISTS endpoint_switchovers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    integration VARCHAR(50) NOT NULL,
+    target_set VARCHAR(50) NOT NULL,
+    switch_at TIMESTAMPTZ NOT NULL,
+    note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_endpoint_switchovers_integration ON endpoint_switchovers(integration, switch_at DESC);
+
+-- Announced maintenance windows. Calls to the integration fail fast with a
+-- retry time at the end of the window instead of hitting the provider.
+CREATE TABLE IF NOT EXISTS endpoint_maintenance_windows (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    integration VARCHAR(50) NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_endpoint_maintenance_windows_ends ON endpoint_maintenance_windows(integration, ends_at);
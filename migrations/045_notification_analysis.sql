@@ -0,0 +1,30 @@
+-- Migration: 045_notification_analysis
+-- Description: Analysis excerpts in notifications with per-user language and finance access
+
+-- Language of notifications for the user; analysis summaries are translated
+-- into it when the AI service is available.
+ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS language VARCHAR(5) NOT NULL DEFAULT 'de';
+
+-- Whether the user may see amounts (tax assessments, payments due). NULL
+-- keeps the default of the role: viewers do not, members do; admins and
+-- owners always do.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS finance_access BOOLEAN;
+
+COMMENT ON COLUMN users.finance_access IS 'Explicit finance permission; NULL uses the role default (viewers without)';
+
+-- The queue columns used by the notification service; 003 created the
+-- table with different names.
+ALTER TABLE notification_queue ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE;
+ALTER TABLE notification_queue ADD COLUMN IF NOT EXISTS type VARCHAR(50) NOT NULL DEFAULT 'new_document';
+ALTER TABLE notification_queue ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE notification_queue ADD COLUMN IF NOT EXISTS last_error TEXT NOT NULL DEFAULT '';
+ALTER TABLE notification_queue ADD COLUMN IF NOT EXISTS scheduled_for TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE notification_queue q SET tenant_id = u.tenant_id
+FROM users u WHERE q.user_id = u.id AND q.tenant_id IS NULL;
+
+-- Rendered per recipient when queued: language, deep link and the analysis
+-- excerpt with amounts already redacted where needed
+ALTER TABLE notification_queue ADD COLUMN IF NOT EXISTS payload JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_notification_queue_due ON notification_queue(scheduled_for) WHERE status = 'pending';
@@ -0,0 +1,41 @@
+-- Migration: 046_document_renditions
+-- Description: PDF/A archival renditions of stored documents
+
+-- One rendition per document and kind. A row records the outcome of the last
+-- conversion: the stored file when it validated, otherwise why it failed, so
+-- that documents which could not be archived as PDF/A can be listed.
+CREATE TABLE IF NOT EXISTS document_renditions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL DEFAULT 'pdfa',
+    status VARCHAR(20) NOT NULL CHECK (status IN ('converted', 'failed', 'unsupported')),
+    storage_path VARCHAR(500) NOT NULL DEFAULT '',
+    file_size BIGINT NOT NULL DEFAULT 0,
+    content_hash VARCHAR(64) NOT NULL DEFAULT '',
+    conformance VARCHAR(20) NOT NULL DEFAULT '',
+    validator VARCHAR(20) NOT NULL DEFAULT '',
+    issues JSONB NOT NULL DEFAULT '[]',
+    error TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (document_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_renditions_tenant_status ON document_renditions(tenant_id, kind, status);
+
+COMMENT ON TABLE document_renditions IS 'Archival renditions (PDF/A-2b) stored alongside the original documents';
+
+-- Job.Enqueue relies on ON CONFLICT (idempotency_key), which needs a unique
+-- index; 004 created a plain one. Keep the oldest job of duplicate keys.
+UPDATE jobs j SET idempotency_key = NULL
+WHERE idempotency_key IS NOT NULL
+  AND EXISTS (
+      SELECT 1 FROM jobs o
+      WHERE o.idempotency_key = j.idempotency_key
+        AND (o.created_at, o.id) < (j.created_at, j.id)
+  );
+
+DROP INDEX IF EXISTS idx_jobs_idempotency;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_idempotency ON jobs(idempotency_key) WHERE idempotency_key IS NOT NULL;
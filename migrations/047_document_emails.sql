@@ -0,0 +1,45 @@
+-- Migration: 047_document_emails
+-- Description: E-mails uploaded as EML/MSG with their attachments as child documents
+
+-- The stored e-mail is a regular document; this table keeps what was read
+-- from it. E-mails attached to another e-mail point to it as their parent.
+CREATE TABLE IF NOT EXISTS document_emails (
+    document_id UUID PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    parent_document_id UUID REFERENCES documents(id) ON DELETE SET NULL,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('eml', 'msg')),
+    message_id VARCHAR(998) NOT NULL DEFAULT '',
+    in_reply_to VARCHAR(998) NOT NULL DEFAULT '',
+    "references" TEXT[] NOT NULL DEFAULT '{}',
+    thread_id VARCHAR(998) NOT NULL,
+    from_address VARCHAR(320) NOT NULL DEFAULT '',
+    from_name VARCHAR(500) NOT NULL DEFAULT '',
+    to_addresses TEXT[] NOT NULL DEFAULT '{}',
+    cc_addresses TEXT[] NOT NULL DEFAULT '{}',
+    subject TEXT NOT NULL DEFAULT '',
+    sent_at TIMESTAMPTZ,
+    authenticity JSONB NOT NULL DEFAULT '{}',
+    warnings JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_emails_thread ON document_emails(tenant_id, thread_id);
+CREATE INDEX IF NOT EXISTS idx_document_emails_message_id ON document_emails(tenant_id, message_id) WHERE message_id <> '';
+
+-- Attachments stored as documents of their own. The same file attached to
+-- several e-mails is stored once and linked from each.
+CREATE TABLE IF NOT EXISTS document_email_attachments (
+    email_document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    filename VARCHAR(500) NOT NULL,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    position INTEGER NOT NULL,
+    PRIMARY KEY (email_document_id, document_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_email_attachments_document ON document_email_attachments(document_id);
+
+COMMENT ON TABLE document_emails IS 'Headers, thread and sender authentication results of uploaded e-mails';
+COMMENT ON COLUMN document_emails.authenticity IS 'SPF/DKIM/DMARC results recorded by the receiving mail server, as hints';
+COMMENT ON TABLE document_email_attachments IS 'Attachments of uploaded e-mails, stored as child documents';
@@ -0,0 +1,23 @@
+-- Migration: 048_tenant_branding
+-- Description: White-label settings for client-facing mails, pages and PDFs
+
+ALTER TABLE tenant_branding
+    ADD COLUMN IF NOT EXISTS company_name VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS sender_name VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS custom_css TEXT,
+    ADD COLUMN IF NOT EXISTS support_email VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS support_phone VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS custom_domain VARCHAR(253),
+    ADD COLUMN IF NOT EXISTS logo_data BYTEA,
+    ADD COLUMN IF NOT EXISTS logo_content_type VARCHAR(50);
+
+ALTER TABLE tenant_branding
+    ALTER COLUMN primary_color SET DEFAULT '#3B82F6';
+
+-- One tenant per domain; public links are built with it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_branding_custom_domain ON tenant_branding(custom_domain)
+    WHERE custom_domain IS NOT NULL;
+
+COMMENT ON COLUMN tenant_branding.sender_name IS 'Display name of tenant mails unless the mail sender identity sets one';
+COMMENT ON COLUMN tenant_branding.custom_domain IS 'Host of public links (signing and status pages), lower case without scheme';
+COMMENT ON COLUMN tenant_branding.logo_data IS 'Uploaded PNG or JPEG logo, served publicly and embedded in PDFs';
@@ -0,0 +1,23 @@
+-- Migration: 049_custom_domains
+-- Description: Verification and certificate state of tenant custom domains
+
+-- A custom domain serves public links once the tenant proved control over
+-- it with a DNS TXT record. Changing the domain resets its state.
+ALTER TABLE tenant_branding
+    ADD COLUMN IF NOT EXISTS domain_verification_token VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS domain_verified_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS domain_checked_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS domain_check_error TEXT,
+    ADD COLUMN IF NOT EXISTS certificate_status VARCHAR(20) NOT NULL DEFAULT 'none'
+        CHECK (certificate_status IN ('none', 'pending', 'on_demand', 'issued', 'failed')),
+    ADD COLUMN IF NOT EXISTS certificate_error TEXT,
+    ADD COLUMN IF NOT EXISTS certificate_updated_at TIMESTAMPTZ;
+
+-- Several tenants may enter the same domain, only one can verify it. This
+-- keeps a tenant from blocking a domain it does not control.
+DROP INDEX IF EXISTS idx_branding_custom_domain;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_branding_verified_domain ON tenant_branding(custom_domain)
+    WHERE domain_verified_at IS NOT NULL;
+
+COMMENT ON COLUMN tenant_branding.domain_verification_token IS 'Expected in the TXT record _abi-domain.<custom_domain>';
+COMMENT ON COLUMN tenant_branding.certificate_status IS 'none, pending (hook running), on_demand (issued by the TLS proxy on first request), issued or failed';
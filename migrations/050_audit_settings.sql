@@ -0,0 +1,15 @@
+-- Migration: 050_audit_settings
+-- Description: Per-tenant IP anonymization and retention of audit logs
+
+-- A NULL column means the platform default applies
+CREATE TABLE IF NOT EXISTS tenant_audit_settings (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    ip_anonymization VARCHAR(20) CHECK (ip_anonymization IN ('full', 'truncate', 'hash')),
+    retention_days INTEGER CHECK (retention_days > 0),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE tenant_audit_settings IS 'DSGVO settings of audit logs; the audit archive job enforces the retention';
+COMMENT ON COLUMN tenant_audit_settings.ip_anonymization IS 'full: no IP stored, truncate: /24 or /48 prefix, hash: keyed hash with rotating salt';
+COMMENT ON COLUMN tenant_audit_settings.retention_days IS 'Days audit logs stay in the database before they are archived and deleted';
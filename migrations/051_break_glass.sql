@@ -0,0 +1,27 @@
+-- Migration: 051_break_glass
+-- Description: Time-boxed break-glass access of platform super-admins to tenants
+
+CREATE TABLE IF NOT EXISTS break_glass_grants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    -- No foreign key: grants outlive the super-admin's account
+    admin_user_id UUID NOT NULL,
+    admin_name VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'admin',
+    granted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    revoked_by UUID,
+    -- Set once the end was audited and the tenant admins were notified
+    ended_at TIMESTAMPTZ,
+    request_count INTEGER NOT NULL DEFAULT 0,
+    last_used_at TIMESTAMPTZ,
+    CHECK (expires_at > granted_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_break_glass_tenant ON break_glass_grants(tenant_id, granted_at DESC);
+CREATE INDEX IF NOT EXISTS idx_break_glass_admin ON break_glass_grants(admin_user_id, granted_at DESC);
+CREATE INDEX IF NOT EXISTS idx_break_glass_open ON break_glass_grants(expires_at) WHERE ended_at IS NULL;
+
+COMMENT ON TABLE break_glass_grants IS 'Break-glass access to a tenant; every request under a grant is written to the tenant audit log';
@@ -0,0 +1,27 @@
+-- Migration: 052_schema_backfills
+-- Description: Progress and dual-write flags of online backfills for large schema changes
+
+CREATE TABLE IF NOT EXISTS schema_backfills (
+    name VARCHAR(100) PRIMARY KEY,
+    phase VARCHAR(20) NOT NULL DEFAULT 'pending',
+    -- Application code writes old and new columns while set
+    dual_write BOOLEAN NOT NULL DEFAULT FALSE,
+    dual_write_since TIMESTAMPTZ,
+    -- Key of the last processed row; batches continue after it
+    cursor TEXT NOT NULL DEFAULT '',
+    rows_done BIGINT NOT NULL DEFAULT 0,
+    rows_total BIGINT,
+    batches INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ,
+    last_batch_at TIMESTAMPTZ,
+    backfilled_at TIMESTAMPTZ,
+    verification JSONB,
+    verified_at TIMESTAMPTZ,
+    cut_over_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    error TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (phase IN ('pending', 'running', 'paused', 'failed', 'backfilled', 'cut_over', 'completed'))
+);
+
+COMMENT ON TABLE schema_backfills IS 'Online backfills defined in code; platform-wide, not tenant scoped';
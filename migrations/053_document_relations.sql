@@ -0,0 +1,28 @@
+-- Migration: 053_document_relations
+-- Description: Typed edges from documents to related documents, invoices and UVA submissions
+
+CREATE TABLE IF NOT EXISTS document_relations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    -- No foreign key: the target is a document, invoice or UVA submission;
+    -- edges to deleted targets are dropped when the graph is read
+    target_type VARCHAR(30) NOT NULL,
+    target_id UUID NOT NULL,
+    relation_type VARCHAR(30) NOT NULL,
+    -- manual or extraction (found by document analysis)
+    origin VARCHAR(20) NOT NULL DEFAULT 'manual',
+    confidence NUMERIC(3,2),
+    evidence TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (document_id, target_type, target_id, relation_type),
+    CHECK (target_type IN ('document', 'invoice', 'uva_submission')),
+    CHECK (origin IN ('manual', 'extraction')),
+    CHECK (NOT (target_type = 'document' AND target_id = document_id))
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_relations_tenant ON document_relations(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_document_relations_target ON document_relations(target_type, target_id);
+
+COMMENT ON TABLE document_relations IS 'Document link graph; e-mail attachments and signed versions are derived from their own tables';
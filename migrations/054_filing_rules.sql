@@ -0,0 +1,43 @@
+-- Migration: 054_filing_rules
+-- Description: Folders, labels and assignees of documents, and the per-tenant rules that set them after analysis
+
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS folder VARCHAR(255);
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS labels TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS assigned_to UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_documents_folder ON documents(tenant_id, folder) WHERE folder IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_documents_labels ON documents USING GIN (labels);
+CREATE INDEX IF NOT EXISTS idx_documents_assigned_to ON documents(assigned_to) WHERE assigned_to IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS filing_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    -- Rules are evaluated in ascending position
+    position INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    -- Skip the rules after this one when it matches
+    stop_processing BOOLEAN NOT NULL DEFAULT FALSE,
+    conditions JSONB NOT NULL DEFAULT '{}',
+    actions JSONB NOT NULL DEFAULT '{}',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_filing_rules_tenant ON filing_rules(tenant_id, position);
+
+-- Rules that were applied to a document; a rule acts on a document only once,
+-- so re-analysis does not undo manual changes or repeat action items
+CREATE TABLE IF NOT EXISTS filing_rule_matches (
+    rule_id UUID NOT NULL REFERENCES filing_rules(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    analysis_id UUID REFERENCES document_analyses(id) ON DELETE SET NULL,
+    matched_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (rule_id, document_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_filing_rule_matches_document ON filing_rule_matches(document_id);
@@ -0,0 +1,70 @@
+-- Migration: 055_signature_billing
+-- Description: Prepaid signature credit packages, their ledger, per-tenant billing settings and monthly signature statements
+
+-- Billing settings of a tenant; NULL columns use the configured defaults
+CREATE TABLE IF NOT EXISTS signature_billing_accounts (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    -- Postpaid tenants may sign beyond their credits; the overage is billed
+    -- on the monthly statement
+    postpaid BOOLEAN,
+    low_balance_threshold INTEGER CHECK (low_balance_threshold >= 0),
+    -- Set when the low-balance mail went out, cleared by the next purchase
+    low_balance_notified_at TIMESTAMPTZ,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Prepaid signature bundles, consumed earliest expiry first
+CREATE TABLE IF NOT EXISTS signature_credit_packages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    signatures INTEGER NOT NULL CHECK (signatures > 0),
+    remaining INTEGER NOT NULL CHECK (remaining >= 0),
+    price_cents INTEGER NOT NULL DEFAULT 0 CHECK (price_cents >= 0),
+    -- Order or invoice number of the purchase
+    reference VARCHAR(100),
+    purchased_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    CHECK (remaining <= signatures)
+);
+
+CREATE INDEX IF NOT EXISTS idx_signature_credit_packages_open
+    ON signature_credit_packages(tenant_id, expires_at) WHERE remaining > 0;
+
+-- Every change of a package's remaining credits; the sum of delta up to a
+-- point in time is the balance at that time
+CREATE TABLE IF NOT EXISTS signature_credit_ledger (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    package_id UUID NOT NULL REFERENCES signature_credit_packages(id) ON DELETE CASCADE,
+    -- purchase, usage or expiry
+    reason VARCHAR(20) NOT NULL,
+    delta INTEGER NOT NULL,
+    usage_id UUID REFERENCES signature_usage(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_signature_credit_ledger_tenant ON signature_credit_ledger(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_signature_credit_ledger_usage ON signature_credit_ledger(usage_id) WHERE usage_id IS NOT NULL;
+
+-- Monthly statements; regenerating a month replaces its statement
+CREATE TABLE IF NOT EXISTS signature_statements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    -- YYYY-MM
+    period CHAR(7) NOT NULL,
+    signatures INTEGER NOT NULL DEFAULT 0,
+    cost_cents INTEGER NOT NULL DEFAULT 0,
+    covered_signatures INTEGER NOT NULL DEFAULT 0,
+    billable_signatures INTEGER NOT NULL DEFAULT 0,
+    billable_cents INTEGER NOT NULL DEFAULT 0,
+    purchased_signatures INTEGER NOT NULL DEFAULT 0,
+    purchased_cents INTEGER NOT NULL DEFAULT 0,
+    expired_signatures INTEGER NOT NULL DEFAULT 0,
+    opening_balance INTEGER NOT NULL DEFAULT 0,
+    closing_balance INTEGER NOT NULL DEFAULT 0,
+    lines JSONB NOT NULL DEFAULT '[]',
+    generated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, period)
+);
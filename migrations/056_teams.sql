@@ -0,0 +1,66 @@
+-- Migration: 056_teams
+-- Description: Teams and departments with their members, deputy rules for absences, and the team of approval requests
+
+CREATE TABLE IF NOT EXISTS teams (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    -- team or department; teams may belong to a department
+    kind VARCHAR(20) NOT NULL DEFAULT 'team',
+    parent_id UUID REFERENCES teams(id) ON DELETE SET NULL,
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, name),
+    CONSTRAINT teams_kind_check CHECK (kind IN ('team', 'department'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_teams_parent ON teams(parent_id) WHERE parent_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    is_lead BOOLEAN NOT NULL DEFAULT FALSE,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_members_user ON team_members(user_id);
+
+-- Memberships of invited users, moved to team_members when they join
+CREATE TABLE IF NOT EXISTS team_pending_members (
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    is_lead BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (team_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_pending_members_email ON team_pending_members(tenant_id, email);
+
+-- Who stands in for a user while they are away; scope is all, approvals or
+-- notifications
+CREATE TABLE IF NOT EXISTS user_deputies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    deputy_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scope VARCHAR(20) NOT NULL DEFAULT 'all',
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_until TIMESTAMPTZ NOT NULL,
+    note TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT user_deputies_scope_check CHECK (scope IN ('all', 'approvals', 'notifications')),
+    CONSTRAINT user_deputies_self_check CHECK (user_id <> deputy_id),
+    CONSTRAINT user_deputies_range_check CHECK (valid_until > valid_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_deputies_tenant_range ON user_deputies(tenant_id, valid_until);
+CREATE INDEX IF NOT EXISTS idx_user_deputies_user ON user_deputies(user_id);
+
+-- Approval requests may be handled by a team instead of the requester alone
+ALTER TABLE approval_requests ADD COLUMN IF NOT EXISTS team_id UUID REFERENCES teams(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_approval_requests_team ON approval_requests(team_id) WHERE team_id IS NOT NULL;
@@ -0,0 +1,136 @@
+-- Migration: 057_document_list_summaries
+-- Description: Precomputed analysis badges of the documents list (analysis status, open deadlines, most urgent action item)
+
+-- The badges computed from the analysis tables. The documents list reads
+-- them from here until the document_list_summaries backfill is cut over;
+-- the backfill and its verification use the view as well.
+CREATE OR REPLACE VIEW document_list_badges AS
+SELECT
+    d.id AS document_id,
+    d.tenant_id,
+    da.id AS analysis_id,
+    da.status AS analysis_status,
+    COALESCE(dl.open_count, 0) AS deadline_count,
+    dl.next_date AS next_deadline,
+    COALESCE(ai.open_count, 0) AS open_action_count,
+    top.id AS top_action_id,
+    top.title AS top_action_title,
+    top.priority AS top_action_priority,
+    top.due_date AS top_action_due_date
+FROM documents d
+LEFT JOIN document_analyses da ON da.document_id = d.id
+LEFT JOIN LATERAL (
+    SELECT COUNT(*)::INTEGER AS open_count, MIN(deadline_date) AS next_date
+    FROM extracted_deadlines
+    WHERE document_id = d.id AND status IN ('active', 'overdue')
+) dl ON TRUE
+LEFT JOIN LATERAL (
+    SELECT COUNT(*)::INTEGER AS open_count
+    FROM action_items
+    WHERE document_id = d.id AND status IN ('pending', 'in_progress')
+) ai ON TRUE
+LEFT JOIN LATERAL (
+    SELECT id, title, priority, due_date
+    FROM action_items
+    WHERE document_id = d.id AND status IN ('pending', 'in_progress')
+    ORDER BY CASE priority WHEN 'critical' THEN 0 WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END,
+        due_date NULLS LAST, created_at, id
+    LIMIT 1
+) top ON TRUE;
+
+-- The read model: one row per document, kept current by the triggers below
+-- while the backfill's dual-write is on
+CREATE TABLE IF NOT EXISTS document_list_summaries (
+    document_id UUID PRIMARY KEY REFERENCES documents(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    analysis_id UUID,
+    analysis_status VARCHAR(20),
+    deadline_count INTEGER NOT NULL DEFAULT 0,
+    next_deadline DATE,
+    open_action_count INTEGER NOT NULL DEFAULT 0,
+    top_action_id UUID,
+    top_action_title VARCHAR(255),
+    top_action_priority VARCHAR(20),
+    top_action_due_date DATE,
+    refreshed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_list_summaries_tenant ON document_list_summaries(tenant_id);
+
+COMMENT ON TABLE document_list_summaries IS 'Read model of the documents list; rebuilt from document_list_badges';
+
+-- Recomputes the summaries of the given documents and returns how many
+-- were written. Deleted documents are skipped; their summaries cascade.
+CREATE OR REPLACE FUNCTION refresh_document_list_summaries(ids UUID[])
+RETURNS INTEGER AS $$
+DECLARE
+    refreshed INTEGER;
+BEGIN
+    INSERT INTO document_list_summaries (
+        document_id, tenant_id, analysis_id, analysis_status, deadline_count, next_deadline,
+        open_action_count, top_action_id, top_action_title, top_action_priority, top_action_due_date
+    )
+    SELECT document_id, tenant_id, analysis_id, analysis_status, deadline_count, next_deadline,
+        open_action_count, top_action_id, top_action_title, top_action_priority, top_action_due_date
+    FROM document_list_badges
+    WHERE document_id = ANY(ids)
+    ON CONFLICT (document_id) DO UPDATE SET
+        analysis_id = EXCLUDED.analysis_id,
+        analysis_status = EXCLUDED.analysis_status,
+        deadline_count = EXCLUDED.deadline_count,
+        next_deadline = EXCLUDED.next_deadline,
+        open_action_count = EXCLUDED.open_action_count,
+        top_action_id = EXCLUDED.top_action_id,
+        top_action_title = EXCLUDED.top_action_title,
+        top_action_priority = EXCLUDED.top_action_priority,
+        top_action_due_date = EXCLUDED.top_action_due_date,
+        refreshed_at = NOW();
+    GET DIAGNOSTICS refreshed = ROW_COUNT;
+    RETURN refreshed;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Dual-write shim of the analysis pipeline: every change to an analysis,
+-- deadline or action item refreshes the summary of its document once
+-- dual-write is switched on for the backfill
+CREATE OR REPLACE FUNCTION sync_document_list_summary()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM schema_backfills
+        WHERE name = 'document_list_summaries'
+          AND (dual_write OR phase IN ('cut_over', 'completed'))
+    ) THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP = 'INSERT' THEN
+        PERFORM refresh_document_list_summaries(ARRAY[NEW.document_id]);
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM refresh_document_list_summaries(ARRAY[OLD.document_id]);
+    ELSE
+        PERFORM refresh_document_list_summaries(ARRAY[OLD.document_id, NEW.document_id]);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS document_analyses_list_summary ON document_analyses;
+CREATE TRIGGER document_analyses_list_summary
+    AFTER INSERT OR DELETE OR UPDATE OF status, document_id ON document_analyses
+    FOR EACH ROW EXECUTE FUNCTION sync_document_list_summary();
+
+DROP TRIGGER IF EXISTS extracted_deadlines_list_summary ON extracted_deadlines;
+CREATE TRIGGER extracted_deadlines_list_summary
+    AFTER INSERT OR DELETE OR UPDATE OF status, deadline_date, document_id ON extracted_deadlines
+    FOR EACH ROW EXECUTE FUNCTION sync_document_list_summary();
+
+DROP TRIGGER IF EXISTS action_items_list_summary ON action_items;
+CREATE TRIGGER action_items_list_summary
+    AFTER INSERT OR DELETE OR UPDATE OF status, priority, title, due_date, document_id ON action_items
+    FOR EACH ROW EXECUTE FUNCTION sync_document_list_summary();
+
+-- The documents list counts and pages the active documents of a tenant
+CREATE INDEX IF NOT EXISTS idx_documents_tenant_received_active
+    ON documents(tenant_id, received_at DESC)
+    WHERE archived_at IS NULL;
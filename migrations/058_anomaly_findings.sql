@@ -0,0 +1,34 @@
+-- Migration: 058_anomaly_findings
+-- Description: Review items raised by the anomaly detection on payments, invoices and bank lines
+
+CREATE TABLE IF NOT EXISTS anomaly_findings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    -- duplicate_payment, unusual_invoice_amount or unknown_counterparty
+    kind VARCHAR(40) NOT NULL,
+    severity VARCHAR(10) NOT NULL DEFAULT 'medium',
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    -- Identifies what was flagged, so a reviewed finding is not raised again
+    fingerprint VARCHAR(200) NOT NULL,
+    -- payment_item, invoice or bank_transaction; no foreign key, since the
+    -- finding outlives deleted batches and statements
+    subject_type VARCHAR(30) NOT NULL,
+    subject_id UUID NOT NULL,
+    related_ids UUID[] NOT NULL DEFAULT '{}',
+    title VARCHAR(500) NOT NULL,
+    amount_cents BIGINT,
+    counterparty VARCHAR(500),
+    details JSONB NOT NULL DEFAULT '{}',
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    review_note TEXT,
+    UNIQUE (tenant_id, kind, fingerprint),
+    CONSTRAINT anomaly_findings_kind_check CHECK (kind IN ('duplicate_payment', 'unusual_invoice_amount', 'unknown_counterparty')),
+    CONSTRAINT anomaly_findings_severity_check CHECK (severity IN ('high', 'medium', 'low')),
+    CONSTRAINT anomaly_findings_status_check CHECK (status IN ('open', 'confirmed', 'dismissed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_anomaly_findings_open
+    ON anomaly_findings(tenant_id, detected_at DESC) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_anomaly_findings_tenant ON anomaly_findings(tenant_id, kind, detected_at DESC);
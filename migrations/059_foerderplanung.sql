@@ -0,0 +1,31 @@
+-- Migration: 059_foerderplanung
+-- Description: Advisor assignment of Förderungsanträge, effort estimates per program type and advisor capacities for workload planning
+
+ALTER TABLE foerderungs_antraege ADD COLUMN IF NOT EXISTS advisor_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_antraege_open_advisor
+    ON foerderungs_antraege(tenant_id, advisor_id) WHERE status IN ('planned', 'drafting');
+
+-- Tenant overrides of the built-in effort estimates; one row per Förderung type
+CREATE TABLE IF NOT EXISTS foerderung_effort_estimates (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    foerderung_type VARCHAR(50) NOT NULL,
+    hours NUMERIC(6,1) NOT NULL,
+    -- Weeks before the Einreichfrist the work is spread over
+    lead_weeks INTEGER NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, foerderung_type),
+    CONSTRAINT effort_estimates_type_check CHECK (foerderung_type IN ('zuschuss', 'kredit', 'garantie', 'beratung', 'kombination')),
+    CONSTRAINT effort_estimates_hours_check CHECK (hours > 0),
+    CONSTRAINT effort_estimates_lead_check CHECK (lead_weeks BETWEEN 1 AND 26)
+);
+
+-- Weekly hours an advisor has for Förderung work; users without a row get the default
+CREATE TABLE IF NOT EXISTS advisor_capacities (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    weekly_hours NUMERIC(5,1) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, user_id),
+    CONSTRAINT advisor_capacities_hours_check CHECK (weekly_hours >= 0)
+);
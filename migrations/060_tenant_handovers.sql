@@ -0,0 +1,56 @@
+-- Migration: 060_tenant_handovers
+-- Description: Handover of a client's accounts, documents and filings from one tenant to another (Steuerberaterwechsel)
+
+CREATE TABLE IF NOT EXISTS tenant_handovers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source_tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    target_tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    -- pending until the target accepts or rejects, or the source cancels
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    -- copy keeps the source's documents and filings; transfer removes them
+    mode VARCHAR(20) NOT NULL DEFAULT 'copy',
+    include_documents BOOLEAN NOT NULL DEFAULT TRUE,
+    include_filings BOOLEAN NOT NULL DEFAULT TRUE,
+    -- Snapshot of the source accounts: [{id, name, type, documents, filings}]
+    accounts JSONB NOT NULL DEFAULT '[]',
+    -- Client consent to the handover
+    client_name VARCHAR(255) NOT NULL,
+    consent_reference VARCHAR(500) NOT NULL,
+    consent_given_at DATE NOT NULL,
+    message TEXT,
+    initiated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    -- Set when the target accepts: source account ID -> target account ID
+    account_mapping JSONB NOT NULL DEFAULT '{}',
+    responded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    response_note TEXT,
+    -- Counts of copied, transferred and skipped items
+    summary JSONB NOT NULL DEFAULT '{}',
+    error TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    responded_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    CONSTRAINT tenant_handovers_status_check CHECK (status IN ('pending', 'completed', 'rejected', 'cancelled', 'expired', 'failed')),
+    CONSTRAINT tenant_handovers_mode_check CHECK (mode IN ('copy', 'transfer')),
+    CONSTRAINT tenant_handovers_tenants_check CHECK (source_tenant_id <> target_tenant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_handovers_source ON tenant_handovers(source_tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_tenant_handovers_target ON tenant_handovers(target_tenant_id, created_at DESC);
+
+-- Audit trail of a handover: every document and filing moved, and what became of it
+CREATE TABLE IF NOT EXISTS tenant_handover_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    handover_id UUID NOT NULL REFERENCES tenant_handovers(id) ON DELETE CASCADE,
+    -- document, uva_submission or zm_submission
+    item_type VARCHAR(30) NOT NULL,
+    source_account_id UUID NOT NULL,
+    source_id UUID NOT NULL,
+    target_id UUID,
+    -- copied, transferred or skipped (already present at the target)
+    action VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT tenant_handover_items_action_check CHECK (action IN ('copied', 'transferred', 'skipped'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_handover_items_handover ON tenant_handover_items(handover_id, item_type);
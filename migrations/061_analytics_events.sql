@@ -0,0 +1,17 @@
+-- Migration: 061_analytics_events
+-- Description: Pseudonymized product analytics events for funnel and adoption metrics
+
+-- Tenants and users are stored as keyed hashes only, and properties hold
+-- categorical values and numbers, never personal data. No foreign keys:
+-- the hashes cannot be joined to tenants or users.
+CREATE TABLE IF NOT EXISTS analytics_events (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    tenant_hash VARCHAR(64) NOT NULL,
+    user_hash VARCHAR(64),
+    properties JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_events_occurred_at ON analytics_events(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_analytics_events_name_tenant ON analytics_events(name, tenant_hash, occurred_at);
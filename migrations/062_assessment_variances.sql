@@ -0,0 +1,31 @@
+-- Migration: 062_assessment_variances
+-- Description: Variances between an assessment (Bescheid) and the submitted UVA
+
+-- One row per Bescheid: the Kennzahlen the Bescheid assesses, compared with
+-- the values of the UVA it is linked to (document_relations 'assessment_of').
+-- A significant variance with an open appeal period gets a Beschwerde draft
+-- as an action item of the document.
+CREATE TABLE IF NOT EXISTS assessment_variances (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    uva_submission_id UUID NOT NULL REFERENCES uva_submissions(id) ON DELETE CASCADE,
+    period VARCHAR(20) NOT NULL,
+    lines JSONB NOT NULL DEFAULT '[]',
+    significant BOOLEAN NOT NULL DEFAULT FALSE,
+    difference_cents BIGINT NOT NULL DEFAULT 0,
+    appeal_deadline DATE,
+    appeal_deadline_source VARCHAR(20),
+    draft TEXT,
+    action_item_id UUID REFERENCES action_items(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'accepted', 'appealed')),
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    review_note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (document_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_assessment_variances_tenant ON assessment_variances(tenant_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_assessment_variances_submission ON assessment_variances(uva_submission_id);
@@ -0,0 +1,29 @@
+-- Migration: 063_job_sla
+-- Description: Job execution history per attempt and SLA alerts per job type
+
+-- Every attempt is recorded now, not only the final outcome: 'retried' is a
+-- failed attempt that will run again, 'failed' the last attempt of a job
+-- moved to the dead letter queue
+ALTER TABLE job_history DROP CONSTRAINT IF EXISTS job_history_status_check;
+ALTER TABLE job_history ADD CONSTRAINT job_history_status_check
+    CHECK (status IN ('completed', 'retried', 'failed'));
+ALTER TABLE job_history ADD COLUMN IF NOT EXISTS attempt INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE job_history ADD COLUMN IF NOT EXISTS handler_version VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_job_history_type_started ON job_history(type, started_at);
+
+-- One open alert per job type and metric; resolved alerts are kept
+CREATE TABLE IF NOT EXISTS job_sla_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_type VARCHAR(100) NOT NULL,
+    metric VARCHAR(20) NOT NULL CHECK (metric IN ('failure_rate', 'p95_duration')),
+    value DOUBLE PRECISION NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    runs INTEGER NOT NULL,
+    window_seconds INTEGER NOT NULL,
+    raised_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_job_sla_alerts_open ON job_sla_alerts(job_type, metric) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_job_sla_alerts_raised ON job_sla_alerts(raised_at DESC);
@@ -0,0 +1,5 @@
+-- Migration: 064_storage_residency
+-- Description: Data residency region of a tenant's documents
+
+-- NULL stores documents in the default region (STORAGE_REGION)
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS storage_region VARCHAR(50);
@@ -0,0 +1,20 @@
+-- Migration: 065_notification_matrix
+-- Description: Per event type and channel notification preferences
+
+-- A missing row means the notification is enabled. Security notifications
+-- cannot be disabled and are never stored.
+CREATE TABLE IF NOT EXISTS notification_channel_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL CHECK (event_type IN ('deadline', 'analysis', 'signature', 'billing')),
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('email', 'in_app')),
+    enabled BOOLEAN NOT NULL,
+    -- settings or unsubscribe (one-click link in an email)
+    source VARCHAR(20) NOT NULL DEFAULT 'settings',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, tenant_id, event_type, channel)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_channel_prefs_tenant
+    ON notification_channel_preferences(tenant_id, event_type, channel)
+    WHERE enabled = false;
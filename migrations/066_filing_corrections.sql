@@ -0,0 +1,22 @@
+-- Migration: 066_filing_corrections
+-- Description: Berichtigungen of submitted UVA and ZM filings
+
+-- A Berichtigung is a filing for the same period that references the filing
+-- it corrects; corrects_id is NULL for the original filing. The period of a
+-- correction repeats the original, so the period is no longer unique: the
+-- services reject a second original for a period instead.
+ALTER TABLE uva_submissions ADD COLUMN IF NOT EXISTS corrects_id UUID REFERENCES uva_submissions(id);
+ALTER TABLE zm_submissions ADD COLUMN IF NOT EXISTS corrects_id UUID REFERENCES zm_submissions(id);
+
+ALTER TABLE uva_submissions DROP CONSTRAINT IF EXISTS uva_submissions_account_id_period_year_period_month_key;
+ALTER TABLE uva_submissions DROP CONSTRAINT IF EXISTS uva_submissions_account_id_period_year_period_quarter_key;
+ALTER TABLE zm_submissions DROP CONSTRAINT IF EXISTS zm_submissions_account_id_period_year_period_month_key;
+
+-- A chain has no branches: a filing has at most one correction that was not
+-- rejected
+CREATE UNIQUE INDEX IF NOT EXISTS idx_uva_submissions_corrects
+    ON uva_submissions(corrects_id)
+    WHERE corrects_id IS NOT NULL AND status NOT IN ('rejected', 'error');
+CREATE UNIQUE INDEX IF NOT EXISTS idx_zm_submissions_corrects
+    ON zm_submissions(corrects_id)
+    WHERE corrects_id IS NOT NULL AND status NOT IN ('rejected', 'error');
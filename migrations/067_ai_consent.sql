@@ -0,0 +1,20 @@
+-- Migration: 067_ai_consent
+-- Description: Versioned per-tenant consent to AI processing by document type and purpose
+
+-- Every change of a tenant's consent is a new version; the latest version is
+-- in force. Without any version AI processing is allowed for all documents.
+-- rules is an array of {"document_type": "bescheid" | "*", "purposes": [...]}.
+CREATE TABLE IF NOT EXISTS ai_consent_versions (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL CHECK (version > 0),
+    rules JSONB NOT NULL DEFAULT '[]',
+    note TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, version)
+);
+
+-- Analyses the consent does not cover are recorded as skipped
+ALTER TABLE document_analyses DROP CONSTRAINT IF EXISTS document_analyses_status_check;
+ALTER TABLE document_analyses ADD CONSTRAINT document_analyses_status_check
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'needs_review', 'skipped'));
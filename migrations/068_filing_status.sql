@@ -0,0 +1,28 @@
+-- Migration: 068_filing_status
+-- Description: Align the UVA and ZM status checks with the submission flow
+
+-- A filing moves from draft or validated to submitted while it is
+-- transmitted, then to accepted (FinanzOnline returned a Belegnummer),
+-- rejected (FinanzOnline refused it) or error (the outcome is unknown).
+-- Validation results are stored as passed or failed.
+ALTER TABLE uva_submissions DROP CONSTRAINT IF EXISTS uva_submissions_status_check;
+UPDATE uva_submissions SET status = 'accepted' WHERE status = 'confirmed';
+UPDATE uva_submissions SET status = 'error' WHERE status = 'failed';
+ALTER TABLE uva_submissions ADD CONSTRAINT uva_submissions_status_check
+    CHECK (status IN ('draft', 'validated', 'submitted', 'accepted', 'rejected', 'error'));
+ALTER TABLE zm_submissions DROP CONSTRAINT IF EXISTS zm_submissions_status_check;
+UPDATE zm_submissions SET status = 'accepted' WHERE status = 'confirmed';
+UPDATE zm_submissions SET status = 'error' WHERE status = 'failed';
+ALTER TABLE zm_submissions ADD CONSTRAINT zm_submissions_status_check
+    CHECK (status IN ('draft', 'validated', 'submitted', 'accepted', 'rejected', 'error'));
+
+ALTER TABLE uva_submissions DROP CONSTRAINT IF EXISTS uva_submissions_validation_status_check;
+UPDATE uva_submissions SET validation_status = 'passed' WHERE validation_status = 'valid';
+UPDATE uva_submissions SET validation_status = 'failed' WHERE validation_status = 'invalid';
+ALTER TABLE uva_submissions ADD CONSTRAINT uva_submissions_validation_status_check
+    CHECK (validation_status IN ('pending', 'passed', 'failed'));
+ALTER TABLE zm_submissions DROP CONSTRAINT IF EXISTS zm_submissions_validation_status_check;
+UPDATE zm_submissions SET validation_status = 'passed' WHERE validation_status = 'valid';
+UPDATE zm_submissions SET validation_status = 'failed' WHERE validation_status = 'invalid';
+ALTER TABLE zm_submissions ADD CONSTRAINT zm_submissions_validation_status_check
+    CHECK (validation_status IN ('pending', 'passed', 'failed'));
@@ -0,0 +1,23 @@
+-- Migration: 069_extraction_corrections
+-- Description: Change history of user corrections to extracted deadlines and amounts
+
+-- Every correction stores the changed fields with their old and new values.
+-- Undoing a correction restores the old values and sets undone_at; the
+-- latest correction that is not undone is the next one to undo.
+CREATE TABLE IF NOT EXISTS extraction_corrections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL,
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('deadline', 'amount')),
+    entity_id UUID NOT NULL,
+    changes JSONB NOT NULL,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    undone_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    undone_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_extraction_corrections_entity
+    ON extraction_corrections(entity_type, entity_id, changed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_extraction_corrections_document
+    ON extraction_corrections(document_id);
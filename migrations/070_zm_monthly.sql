@@ -0,0 +1,10 @@
+-- Migration: 070_zm_monthly
+-- Description: Monthly ZM periods
+
+-- A monthly ZM stores its month in period_month; a quarterly ZM leaves it
+-- NULL. period_quarter is set for both.
+ALTER TABLE zm_submissions ADD COLUMN IF NOT EXISTS period_month INTEGER;
+ALTER TABLE zm_submissions ALTER COLUMN period_month DROP NOT NULL;
+ALTER TABLE zm_submissions DROP CONSTRAINT IF EXISTS zm_submissions_period_month_check;
+ALTER TABLE zm_submissions ADD CONSTRAINT zm_submissions_period_month_check
+    CHECK (period_month IS NULL OR period_month BETWEEN 1 AND 12);
@@ -0,0 +1,54 @@
+-- Migration: 071_entity_changes
+-- Description: Legal entity changes of clients (Rechtsformwechsel, merger) and the successor accounts
+
+CREATE TABLE IF NOT EXISTS entity_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    -- conversion (Rechtsformwechsel, e.g. Einzelunternehmen to GmbH) or
+    -- merger (Verschmelzung into another company)
+    kind VARCHAR(20) NOT NULL,
+    entity_name VARCHAR(255) NOT NULL,
+    old_legal_form VARCHAR(20) NOT NULL,
+    successor_name VARCHAR(255) NOT NULL,
+    new_legal_form VARCHAR(20) NOT NULL,
+    -- First day the successor acts; earlier periods stay with the old entity
+    effective_date DATE NOT NULL,
+    -- draft while the successor accounts are linked, then completed or cancelled
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    note TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    completed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    CONSTRAINT entity_changes_kind_check CHECK (kind IN ('conversion', 'merger')),
+    CONSTRAINT entity_changes_status_check CHECK (status IN ('draft', 'completed', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_changes_tenant ON entity_changes(tenant_id, created_at DESC);
+
+-- The accounts of the old entity as they were when the change was created,
+-- what has to happen to each and the account of the successor
+CREATE TABLE IF NOT EXISTS entity_change_accounts (
+    change_id UUID NOT NULL REFERENCES entity_changes(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    account_name VARCHAR(255) NOT NULL,
+    account_type VARCHAR(50) NOT NULL,
+    account_status VARCHAR(50) NOT NULL,
+    documents INTEGER NOT NULL DEFAULT 0,
+    filings INTEGER NOT NULL DEFAULT 0,
+    -- keep (the registration continues), reregister (the successor needs a
+    -- new one) or close (the registration ends, the successor's takes over)
+    action VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    successor_account_id UUID REFERENCES accounts(id) ON DELETE SET NULL,
+    done_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    done_at TIMESTAMPTZ,
+    PRIMARY KEY (change_id, account_id),
+    CONSTRAINT entity_change_accounts_action_check CHECK (action IN ('keep', 'reregister', 'close')),
+    CONSTRAINT entity_change_accounts_successor_check CHECK (successor_account_id IS NULL OR successor_account_id <> account_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_change_accounts_account ON entity_change_accounts(account_id);
+CREATE INDEX IF NOT EXISTS idx_entity_change_accounts_successor ON entity_change_accounts(successor_account_id)
+    WHERE successor_account_id IS NOT NULL;
@@ -0,0 +1,23 @@
+-- Migration: 072_bank_reconciliation
+-- Description: MT940 statement import and reconciliation of bank transactions with invoices and payments
+
+-- camt.053 or mt940
+ALTER TABLE bank_statements ADD COLUMN IF NOT EXISTS format VARCHAR(20) NOT NULL DEFAULT 'camt.053';
+
+-- match_status: unmatched, suggested (best candidate below the auto-match
+-- score or not unique), matched (automatically or by hand) or ignored
+-- (needs no match, e.g. bank fees). match_score and match_reason explain
+-- the automatic result; matched_by is set for manual matches.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS match_status VARCHAR(50) DEFAULT 'unmatched';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS match_score INTEGER;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS match_reason TEXT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS matched_at TIMESTAMPTZ;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS matched_by UUID REFERENCES users(id);
+
+UPDATE transactions SET match_status = 'matched'
+WHERE (matched_invoice_id IS NOT NULL OR matched_payment_id IS NOT NULL)
+    AND COALESCE(match_status, 'unmatched') = 'unmatched';
+
+CREATE INDEX IF NOT EXISTS idx_transactions_match ON transactions(match_status);
+CREATE INDEX IF NOT EXISTS idx_transactions_matched_payment
+    ON transactions(matched_payment_id) WHERE matched_payment_id IS NOT NULL;
@@ -0,0 +1,26 @@
+-- Migration: 073_vienna_days
+-- Description: Count calendar days in Europe/Vienna in views and defaults
+-- instead of the session zone, which the application now sets to UTC
+
+-- Förderung deadlines are Austrian calendar days
+CREATE OR REPLACE VIEW v_active_foerderungen AS
+SELECT
+    f.*,
+    CASE
+        WHEN f.application_deadline IS NULL THEN 'rolling'
+        WHEN f.application_deadline > (NOW() AT TIME ZONE 'Europe/Vienna')::date + 30 THEN 'open'
+        WHEN f.application_deadline > (NOW() AT TIME ZONE 'Europe/Vienna')::date THEN 'closing_soon'
+        ELSE 'closed'
+    END AS deadline_status,
+    CASE
+        WHEN f.application_deadline IS NULL THEN NULL
+        ELSE f.application_deadline - (NOW() AT TIME ZONE 'Europe/Vienna')::date
+    END AS days_until_deadline
+FROM foerderungen f
+WHERE f.status = 'active'
+ORDER BY
+    CASE WHEN f.application_deadline IS NULL THEN 1 ELSE 0 END,
+    f.application_deadline ASC;
+
+-- Signature usage is billed per Austrian day, like the usage metrics
+ALTER TABLE signature_usage ALTER COLUMN usage_date SET DEFAULT (NOW() AT TIME ZONE 'Europe/Vienna')::date;
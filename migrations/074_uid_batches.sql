@@ -0,0 +1,43 @@
+-- Migration: 074_uid_batches
+-- Description: Uploaded lists of UID numbers verified in the background, and their confirmations
+
+CREATE TABLE IF NOT EXISTS uid_batches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    -- FinanzOnline account whose credentials query the UID-Bestätigungsservice
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    -- Stufe 1 (valid/invalid) or Stufe 2 (with name and address)
+    level INTEGER NOT NULL,
+    file_name VARCHAR(255),
+    -- pending until the worker picks it up, running, then completed or failed
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    total INTEGER NOT NULL,
+    valid_count INTEGER NOT NULL DEFAULT 0,
+    invalid_count INTEGER NOT NULL DEFAULT 0,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    CONSTRAINT uid_batches_level_check CHECK (level IN (1, 2)),
+    CONSTRAINT uid_batches_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_uid_batches_tenant ON uid_batches(tenant_id, created_at DESC);
+
+-- The UIDs of a batch in upload order. The validation is the confirmation
+-- of the UID; items without one could not be queried and are retried when
+-- the batch runs again.
+CREATE TABLE IF NOT EXISTS uid_batch_items (
+    batch_id UUID NOT NULL REFERENCES uid_batches(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    uid VARCHAR(20) NOT NULL,
+    validation_id UUID REFERENCES uid_validations(id) ON DELETE SET NULL,
+    error TEXT,
+    processed_at TIMESTAMPTZ,
+    PRIMARY KEY (batch_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_uid_batch_items_validation ON uid_batch_items(validation_id)
+    WHERE validation_id IS NOT NULL;
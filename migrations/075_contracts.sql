@@ -0,0 +1,38 @@
+-- Migration: 075_contracts
+-- Description: Register of contracts with term, renewal and notice period
+
+-- One row per contract document. Periods are ISO 8601 durations of a single
+-- unit (P30D, P4W, P3M, P1Y). term_end is the end of the current term and
+-- cancel_by the last day to give notice before the contract renews; the
+-- worker rolls both forward once cancel_by has passed. The cancellation
+-- deadline is kept as an extracted deadline of the document.
+CREATE TABLE IF NOT EXISTS contracts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    analysis_id UUID REFERENCES document_analyses(id) ON DELETE SET NULL,
+    title VARCHAR(500) NOT NULL,
+    counterparty VARCHAR(255),
+    start_date DATE,
+    end_date DATE,
+    initial_term VARCHAR(10),
+    auto_renewal BOOLEAN NOT NULL DEFAULT FALSE,
+    renewal_term VARCHAR(10),
+    notice_period VARCHAR(10),
+    source_text TEXT,
+    term_end DATE,
+    cancel_by DATE,
+    -- extracted from the document, or manual once a user corrected the terms
+    source VARCHAR(20) NOT NULL DEFAULT 'extracted' CHECK (source IN ('extracted', 'manual')),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'cancelled', 'expired')),
+    deadline_id UUID REFERENCES extracted_deadlines(id) ON DELETE SET NULL,
+    cancelled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    cancelled_at TIMESTAMPTZ,
+    note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (document_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contracts_tenant ON contracts(tenant_id, status, cancel_by);
+CREATE INDEX IF NOT EXISTS idx_contracts_due ON contracts(cancel_by) WHERE status = 'active';
@@ -0,0 +1,40 @@
+-- Migration: 076_business_partners
+-- Description: Customers and suppliers of a tenant with their UID numbers, re-validated quarterly
+
+CREATE TABLE IF NOT EXISTS business_partners (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL DEFAULT 'customer' CHECK (kind IN ('customer', 'supplier', 'both')),
+    uid VARCHAR(20),
+    street VARCHAR(255),
+    post_code VARCHAR(20),
+    city VARCHAR(100),
+    country VARCHAR(2),
+    email VARCHAR(255),
+    notes TEXT,
+    -- unchecked until the first confirmation, then valid or invalid
+    uid_status VARCHAR(20) NOT NULL DEFAULT 'unchecked' CHECK (uid_status IN ('unchecked', 'valid', 'invalid')),
+    uid_checked_at TIMESTAMPTZ,
+    uid_invalid_since TIMESTAMPTZ,
+    uid_validation_id UUID REFERENCES uid_validations(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_business_partners_tenant ON business_partners(tenant_id, name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_business_partners_uid ON business_partners(tenant_id, uid) WHERE uid IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_business_partners_due ON business_partners(tenant_id, uid_checked_at NULLS FIRST)
+    WHERE uid IS NOT NULL;
+
+-- The FinanzOnline account a tenant's partner UIDs are re-validated with.
+-- batch_id is the UID batch of a re-validation in progress.
+CREATE TABLE IF NOT EXISTS partner_uid_settings (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    level INTEGER NOT NULL DEFAULT 1 CHECK (level IN (1, 2)),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    batch_id UUID REFERENCES uid_batches(id) ON DELETE SET NULL,
+    last_run_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,22 @@
+-- Migration: 077_document_access
+-- Description: Access lists restricting documents and folders to users and teams of a tenant
+
+-- A document is visible to the whole tenant unless it or one of its folders
+-- has entries; then only to the listed users and members of the listed teams.
+-- Folder entries apply to the folder and its subfolders.
+CREATE TABLE IF NOT EXISTS document_access (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    document_id UUID REFERENCES documents(id) ON DELETE CASCADE,
+    folder VARCHAR(255),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    team_id UUID REFERENCES teams(id) ON DELETE CASCADE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT document_access_target_check CHECK ((document_id IS NULL) <> (folder IS NULL)),
+    CONSTRAINT document_access_grantee_check CHECK ((user_id IS NULL) <> (team_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_access_document ON document_access(tenant_id, document_id) WHERE document_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_document_access_folder ON document_access(tenant_id, folder) WHERE folder IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_document_access_team ON document_access(team_id) WHERE team_id IS NOT NULL;
@@ -0,0 +1,62 @@
+-- Migration: 078_firmenbuch_watchlist
+-- Description: Firmenbuch company cache, watchlist and change history in the shape of internal/firmenbuch
+
+-- The cache and history tables of 005 were never written; they are replaced
+-- by the tables the firmenbuch module reads and writes.
+DROP TABLE IF EXISTS firmenbuch_history;
+DROP TABLE IF EXISTS firmenbuch_cache;
+
+CREATE TABLE IF NOT EXISTS firmenbuch_cache (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    fn VARCHAR(20) NOT NULL,
+    name VARCHAR(500) NOT NULL,
+    rechtsform VARCHAR(100) NOT NULL DEFAULT '',
+    sitz VARCHAR(255) NOT NULL DEFAULT '',
+    adresse JSONB,
+    -- in cents
+    stammkapital BIGINT,
+    waehrung VARCHAR(3),
+    status VARCHAR(50) NOT NULL DEFAULT '',
+    gruendungsdatum DATE,
+    uid VARCHAR(20),
+    gegenstand TEXT,
+    -- the full extract, the base of the next diff
+    extract_data JSONB,
+    last_fetched_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, fn)
+);
+
+CREATE TABLE IF NOT EXISTS firmenbuch_watchlist (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    company_id UUID NOT NULL REFERENCES firmenbuch_cache(id) ON DELETE CASCADE,
+    fn VARCHAR(20) NOT NULL,
+    name VARCHAR(500) NOT NULL,
+    last_status VARCHAR(50) NOT NULL DEFAULT '',
+    last_checked TIMESTAMPTZ,
+    notes TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, fn)
+);
+
+CREATE INDEX IF NOT EXISTS idx_firmenbuch_watchlist_due ON firmenbuch_watchlist(last_checked NULLS FIRST);
+
+-- Changes of watched companies found by the firmenbuch_watch job
+CREATE TABLE IF NOT EXISTS firmenbuch_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    company_id UUID NOT NULL REFERENCES firmenbuch_cache(id) ON DELETE CASCADE,
+    -- geschaeftsfuehrer, kapital, sitz, insolvenz or status_change
+    change_type VARCHAR(50) NOT NULL,
+    old_value JSONB,
+    new_value JSONB,
+    summary TEXT NOT NULL DEFAULT '',
+    detected_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_firmenbuch_history_company ON firmenbuch_history(company_id, detected_at DESC);
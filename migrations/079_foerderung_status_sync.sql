@@ -0,0 +1,26 @@
+-- Migration: 079_foerderung_status_sync
+-- Description: Stand date and review flag of Förderungen and the history of their status changes
+
+-- The date the program information was last confirmed by its provider
+ALTER TABLE foerderungen ADD COLUMN IF NOT EXISTS stand_datum DATE;
+-- Set when the stand date is too old; cleared when the stand date changes
+ALTER TABLE foerderungen ADD COLUMN IF NOT EXISTS review_required_at TIMESTAMPTZ;
+
+UPDATE foerderungen SET stand_datum = COALESCE(last_updated_at, created_at)::date WHERE stand_datum IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_foerderungen_review_required ON foerderungen(review_required_at) WHERE review_required_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS foerderung_status_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    foerderung_id UUID NOT NULL REFERENCES foerderungen(id) ON DELETE CASCADE,
+    old_status VARCHAR(50) NOT NULL,
+    new_status VARCHAR(50) NOT NULL,
+    -- deadline_passed, updated, deleted
+    reason VARCHAR(50) NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- set once the watchers were notified
+    notified_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_foerderung_status_changes_foerderung ON foerderung_status_changes(foerderung_id, changed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_foerderung_status_changes_pending ON foerderung_status_changes(changed_at) WHERE notified_at IS NULL;
@@ -0,0 +1,11 @@
+-- Migration: 080_elda_rueckmeldung
+-- Description: Rückmeldung (Protokoll) of submitted ELDA meldungen, polled by the worker
+
+-- When ELDA processed the meldung, as reported in its Protokoll
+ALTER TABLE elda_meldungen ADD COLUMN IF NOT EXISTS processed_at TIMESTAMPTZ;
+-- The errors and warnings of the Protokoll: [{code, text, field}]
+ALTER TABLE elda_meldungen ADD COLUMN IF NOT EXISTS rueckmeldung_errors JSONB;
+-- The last time the worker asked ELDA for the Protokoll
+ALTER TABLE elda_meldungen ADD COLUMN IF NOT EXISTS last_polled_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_elda_meldungen_awaiting_rueckmeldung ON elda_meldungen(last_polled_at NULLS FIRST, submitted_at) WHERE status = 'submitted';
@@ -0,0 +1,25 @@
+-- Migration: 081_dienstnehmer
+-- Description: Employee master data of a tenant, linked to the ELDA meldungen created for them
+
+CREATE TABLE IF NOT EXISTS dienstnehmer (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    sv_nummer VARCHAR(10) NOT NULL,
+    vorname VARCHAR(100) NOT NULL,
+    nachname VARCHAR(100) NOT NULL,
+    geburtsdatum DATE,
+    -- M, W, D or empty if unknown
+    geschlecht VARCHAR(1) NOT NULL DEFAULT '',
+    adresse JSONB,
+    bankverbindung JSONB,
+    notes TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dienstnehmer_sv_nummer ON dienstnehmer(tenant_id, sv_nummer);
+CREATE INDEX IF NOT EXISTS idx_dienstnehmer_tenant ON dienstnehmer(tenant_id, nachname, vorname);
+
+-- The Dienstnehmer a meldung was prefilled from; the employment history is
+-- derived from all meldungen of the SV-Nummer
+ALTER TABLE elda_meldungen ADD COLUMN IF NOT EXISTS dienstnehmer_id UUID REFERENCES dienstnehmer(id) ON DELETE SET NULL;
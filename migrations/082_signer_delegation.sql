@@ -0,0 +1,12 @@
+-- Migration: 082_signer_delegation
+-- Description: Signers can hand their signature over to a replacement signer
+
+-- A delegated signer stays on the request with status 'delegated' and a used
+-- signing token; the replacement takes over its order_index and fields. The
+-- two links form the delegation chain of a signing position.
+ALTER TABLE signers ADD COLUMN IF NOT EXISTS delegated_from_id UUID REFERENCES signers(id) ON DELETE SET NULL;
+ALTER TABLE signers ADD COLUMN IF NOT EXISTS delegated_to_id UUID REFERENCES signers(id) ON DELETE SET NULL;
+ALTER TABLE signers ADD COLUMN IF NOT EXISTS delegated_at TIMESTAMPTZ;
+ALTER TABLE signers ADD COLUMN IF NOT EXISTS delegation_reason TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_signers_delegated_from ON signers(delegated_from_id) WHERE delegated_from_id IS NOT NULL;
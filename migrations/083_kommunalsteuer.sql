@@ -0,0 +1,45 @@
+-- Migration: 083_kommunalsteuer
+-- Description: Annual Kommunalsteuer and Wiener Dienstgeberabgabe returns calculated from the mBGM of an ELDA account
+
+CREATE TABLE IF NOT EXISTS kommunalsteuer_erklaerungen (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    elda_account_id UUID NOT NULL REFERENCES elda_accounts(id) ON DELETE CASCADE,
+    -- FinanzOnline account the Kommunalsteuererklärung is submitted with
+    account_id UUID REFERENCES accounts(id) ON DELETE SET NULL,
+    year INTEGER NOT NULL CHECK (year BETWEEN 2000 AND 2100),
+
+    -- [{gemeindekennziffer, bezeichnung, sv_nummern}], the first takes the
+    -- employees not listed anywhere
+    betriebsstaetten JSONB NOT NULL DEFAULT '[]',
+    -- Months and Gemeinden of the calculation; amounts in cents
+    berechnung JSONB NOT NULL,
+    bemessungsgrundlage BIGINT NOT NULL DEFAULT 0,
+    kommunalsteuer BIGINT NOT NULL DEFAULT 0,
+    -- NULL without Betriebsstätte in Wien
+    dienstgeberabgabe BIGINT,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'submitted', 'error')),
+    fon_reference VARCHAR(100),
+    response_message TEXT,
+    submitted_at TIMESTAMPTZ,
+    submitted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    -- The Dienstgeberabgabe is filed with the Stadt Wien outside of FinanzOnline
+    dga_filed_at TIMESTAMPTZ,
+    dga_reference VARCHAR(100),
+
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (tenant_id, elda_account_id, year)
+);
+
+CREATE INDEX IF NOT EXISTS idx_kommunalsteuer_tenant ON kommunalsteuer_erklaerungen(tenant_id, year DESC);
+CREATE INDEX IF NOT EXISTS idx_kommunalsteuer_open ON kommunalsteuer_erklaerungen(year, elda_account_id) WHERE status <> 'submitted';
+
+-- Raw FinanzOnline exchanges of Kommunalsteuer submissions
+ALTER TABLE raw_payloads DROP CONSTRAINT IF EXISTS raw_payloads_module_check;
+ALTER TABLE raw_payloads ADD CONSTRAINT raw_payloads_module_check
+    CHECK (module IN ('uva', 'zm', 'uid', 'elda', 'kommst'));
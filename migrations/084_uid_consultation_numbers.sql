@@ -0,0 +1,4 @@
+-- Migration: 084_uid_consultation_numbers
+-- Description: Consultation numbers of Stufe 2 UID confirmations, the proof of the check in tax audits
+
+ALTER TABLE uid_validations ADD COLUMN IF NOT EXISTS consultation_number VARCHAR(64);
@@ -0,0 +1,41 @@
+-- Migration: 085_uva_input_invoices
+-- Description: Incoming invoices (Eingangsrechnungen) with their input tax per rate,
+-- the source of the Vorsteuer Kennzahlen when UVA Kennzahlen are computed. Under
+-- Istbesteuerung domestic input tax is deducted when the invoice is paid.
+
+-- =============================================================================
+-- UVA_INPUT_INVOICES TABLE
+-- =============================================================================
+
+CREATE TABLE uva_input_invoices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    supplier_name VARCHAR(500) NOT NULL,
+    supplier_uid VARCHAR(20),
+    invoice_number VARCHAR(100) NOT NULL,
+    invoice_date DATE NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('domestic', 'import', 'import_deferred', 'ig_acquisition')),
+    credit_note BOOLEAN NOT NULL DEFAULT FALSE,
+    paid_date DATE,
+
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_uva_input_invoices_tenant_date ON uva_input_invoices(tenant_id, invoice_date);
+CREATE INDEX idx_uva_input_invoices_tenant_paid ON uva_input_invoices(tenant_id, paid_date) WHERE paid_date IS NOT NULL;
+
+-- =============================================================================
+-- UVA_INPUT_INVOICE_LINES TABLE - Net amount and input tax per rate
+-- =============================================================================
+
+CREATE TABLE uva_input_invoice_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    input_invoice_id UUID NOT NULL REFERENCES uva_input_invoices(id) ON DELETE CASCADE,
+    tax_percent DECIMAL(5, 2) NOT NULL,
+    net_amount BIGINT NOT NULL,
+    tax_amount BIGINT NOT NULL
+);
+
+CREATE INDEX idx_uva_input_invoice_lines_invoice ON uva_input_invoice_lines(input_invoice_id);
@@ -0,0 +1,31 @@
+-- Migration: 086_kammerumlage
+-- Description: Quarterly Kammerumlage 1 (KU1) of a company, calculated from the input tax of its UVA filings
+
+CREATE TABLE IF NOT EXISTS kammerumlage_meldungen (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    year INTEGER NOT NULL CHECK (year BETWEEN 2000 AND 2100),
+    quarter INTEGER NOT NULL CHECK (quarter BETWEEN 1 AND 4),
+
+    -- UVA filings, assessment basis and KU1 of the year up to the quarter;
+    -- amounts in cents
+    berechnung JSONB NOT NULL,
+    bemessungsgrundlage BIGINT NOT NULL DEFAULT 0,
+    umlage BIGINT NOT NULL DEFAULT 0,
+
+    -- The KU1 is reported with its payment to the Finanzamt and recorded here
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'submitted')),
+    reference VARCHAR(100),
+    submitted_at TIMESTAMPTZ,
+    submitted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (tenant_id, account_id, year, quarter)
+);
+
+CREATE INDEX IF NOT EXISTS idx_kammerumlage_tenant ON kammerumlage_meldungen(tenant_id, year DESC, quarter DESC);
+CREATE INDEX IF NOT EXISTS idx_kammerumlage_open ON kammerumlage_meldungen(year, quarter, account_id) WHERE status <> 'submitted';
@@ -0,0 +1,67 @@
+-- Migration: 087_workflows
+-- Description: Workflow engine shared by modules: tenant definitions, running instances with assignments, timers and SLAs, and their history
+
+CREATE TABLE IF NOT EXISTS workflow_definitions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    key VARCHAR(100) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+
+    -- States, transitions, timers and SLAs; built-in definitions of modules
+    -- live in code
+    definition JSONB NOT NULL,
+
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (tenant_id, key)
+);
+
+CREATE TABLE IF NOT EXISTS workflow_instances (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    definition_key VARCHAR(100) NOT NULL,
+
+    -- Copy of the definition the workflow was started with, so changing or
+    -- deleting it does not break running workflows
+    definition JSONB NOT NULL,
+
+    -- What the workflow is about, e.g. an application or a handover
+    subject_type VARCHAR(50),
+    subject_id UUID,
+    title VARCHAR(255) NOT NULL,
+
+    state VARCHAR(100) NOT NULL,
+    assignee_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    state_entered_at TIMESTAMPTZ NOT NULL,
+    timer_at TIMESTAMPTZ,
+    due_at TIMESTAMPTZ,
+    escalated_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_workflow_instances_tenant ON workflow_instances(tenant_id, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_workflow_instances_assignee ON workflow_instances(assignee_id) WHERE completed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_workflow_instances_subject ON workflow_instances(subject_type, subject_id);
+CREATE INDEX IF NOT EXISTS idx_workflow_instances_timer ON workflow_instances(timer_at) WHERE completed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_workflow_instances_due ON workflow_instances(due_at) WHERE completed_at IS NULL AND escalated_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS workflow_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    instance_id UUID NOT NULL REFERENCES workflow_instances(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('start', 'transition', 'timer', 'assign', 'escalate')),
+    transition VARCHAR(100),
+    from_state VARCHAR(100),
+    to_state VARCHAR(100),
+    assignee_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_workflow_events_instance ON workflow_events(instance_id, created_at);
@@ -0,0 +1,28 @@
+-- Migration: 088_jahreserklaerungen
+-- Description: Draft annual Umsatzsteuererklärung (U1) of a company, aggregated from its UVA filings and reconciled with them
+
+CREATE TABLE IF NOT EXISTS jahreserklaerungen (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    year INTEGER NOT NULL CHECK (year BETWEEN 2000 AND 2100),
+    art VARCHAR(10) NOT NULL DEFAULT 'U1' CHECK (art IN ('U1')),
+
+    -- Kennzahlen of the Erklärung, and the UVA filings with their sum and
+    -- the differences to it; amounts in cents
+    kennzahlen JSONB NOT NULL,
+    abgleich JSONB NOT NULL,
+
+    -- A final Erklärung has been released to the Steuerberater
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'final')),
+    finalized_at TIMESTAMPTZ,
+    finalized_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (tenant_id, account_id, year, art)
+);
+
+CREATE INDEX IF NOT EXISTS idx_jahreserklaerungen_tenant ON jahreserklaerungen(tenant_id, year DESC);
@@ -0,0 +1,15 @@
+-- Migration: 089_pflichten_snoozes
+-- Description: Snoozed obligations of the Pflichten-Radar
+
+-- The radar derives its obligations from UVA, ELDA, payment deadlines,
+-- contracts and Förderungsanträge on every request; only the snoozes are
+-- stored. pflicht_key identifies an obligation across requests, e.g.
+-- uva:<account>:2025-03 or vertrag:<contract>.
+CREATE TABLE IF NOT EXISTS pflichten_snoozes (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    pflicht_key VARCHAR(200) NOT NULL,
+    until DATE NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, pflicht_key)
+);
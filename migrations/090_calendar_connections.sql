@@ -0,0 +1,46 @@
+-- Migration: 090_calendar_connections
+-- Description: Users' Google and Microsoft 365 calendar connections and the events pushed to them
+
+CREATE TABLE IF NOT EXISTS calendar_connections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('google', 'microsoft')),
+    account_email VARCHAR(255) NOT NULL,
+    calendar_id VARCHAR(255) NOT NULL DEFAULT 'primary',
+
+    -- OAuth token (access and refresh token) as JSON, encrypted with the
+    -- application key
+    token BYTEA NOT NULL,
+
+    push_deadlines BOOLEAN NOT NULL DEFAULT TRUE,
+    push_action_items BOOLEAN NOT NULL DEFAULT TRUE,
+    read_busy BOOLEAN NOT NULL DEFAULT FALSE,
+
+    -- error once the provider rejects the token; connecting again resets it
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'error')),
+    last_error TEXT,
+    last_synced_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (user_id, provider, account_email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_connections_tenant ON calendar_connections(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_calendar_connections_status ON calendar_connections(status);
+
+CREATE TABLE IF NOT EXISTS calendar_events (
+    connection_id UUID NOT NULL REFERENCES calendar_connections(id) ON DELETE CASCADE,
+    source_type VARCHAR(20) NOT NULL CHECK (source_type IN ('deadline', 'action_item')),
+    source_id UUID NOT NULL,
+    event_id VARCHAR(1024) NOT NULL,
+
+    -- Hash of the pushed content, to skip unchanged items
+    hash VARCHAR(64) NOT NULL,
+    event_date DATE NOT NULL,
+    synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (connection_id, source_type, source_id)
+);
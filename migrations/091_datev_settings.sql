@@ -0,0 +1,17 @@
+-- Migration: 091_datev_settings
+-- Description: DATEV export settings per tenant: Berater- and Mandantennummer at the parent company, Kontenrahmen and account mapping
+
+CREATE TABLE IF NOT EXISTS datev_settings (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    berater_nr INTEGER NOT NULL CHECK (berater_nr BETWEEN 1001 AND 9999999),
+    mandant_nr INTEGER NOT NULL CHECK (mandant_nr BETWEEN 1 AND 99999),
+    kontenrahmen VARCHAR(5) NOT NULL CHECK (kontenrahmen IN ('SKR03', 'SKR04')),
+    wj_beginn_monat SMALLINT NOT NULL DEFAULT 1 CHECK (wj_beginn_monat BETWEEN 1 AND 12),
+    sachkontenlaenge SMALLINT NOT NULL DEFAULT 4 CHECK (sachkontenlaenge BETWEEN 4 AND 8),
+
+    -- EKR account to DATEV account, where it differs from the default mapping
+    konten JSONB NOT NULL DEFAULT '{}',
+
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
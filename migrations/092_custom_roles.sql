@@ -0,0 +1,31 @@
+-- Migration: 092_custom_roles
+-- Description: Custom roles per tenant as permission bundles and their assignment to users
+
+CREATE TABLE IF NOT EXISTS tenant_roles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+
+    -- Permissions of the role, e.g. uva:submit; see internal/permission
+    permissions TEXT[] NOT NULL CHECK (cardinality(permissions) > 0),
+
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_roles_name ON tenant_roles(tenant_id, lower(name));
+
+-- A user holds the permissions of their built-in role (users.role) and of
+-- every custom role assigned here
+CREATE TABLE IF NOT EXISTS user_role_assignments (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES tenant_roles(id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, role_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_role_assignments_role ON user_role_assignments(role_id);
@@ -0,0 +1,38 @@
+-- Migration: 093_retention_protocols
+-- Description: Signed, immutable deletion protocols of retention-based document deletion runs
+
+CREATE TABLE IF NOT EXISTS retention_protocols (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL,
+    deleted_count INTEGER NOT NULL DEFAULT 0,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+
+    -- The signed JSON document exactly as signed; see internal/retention
+    document BYTEA NOT NULL,
+    sha256 VARCHAR(64) NOT NULL,
+    signature VARCHAR(64) NOT NULL,
+    key_id VARCHAR(16) NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_protocols_tenant ON retention_protocols(tenant_id, started_at DESC);
+
+-- Protocols are never changed, and deleted only together with their tenant
+CREATE OR REPLACE FUNCTION protect_retention_protocols()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND NOT EXISTS (SELECT 1 FROM tenants WHERE id = OLD.tenant_id) THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'retention protocols are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS protect_retention_protocols_trigger ON retention_protocols;
+CREATE TRIGGER protect_retention_protocols_trigger
+    BEFORE UPDATE OR DELETE ON retention_protocols
+    FOR EACH ROW
+    EXECUTE FUNCTION protect_retention_protocols();
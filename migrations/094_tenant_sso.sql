@@ -0,0 +1,48 @@
+-- Migration: 094_tenant_sso
+-- Description: OIDC identity providers per tenant for single sign-on and the identities linked to users
+
+CREATE TABLE IF NOT EXISTS tenant_sso_providers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('id_austria', 'entra', 'google', 'oidc')),
+    issuer VARCHAR(500) NOT NULL,
+    client_id VARCHAR(255) NOT NULL,
+
+    -- Encrypted with ENCRYPTION_KEY; NULL for public clients
+    client_secret_encrypted BYTEA,
+    scopes TEXT[] NOT NULL,
+
+    -- Claim mapping
+    email_claim VARCHAR(100) NOT NULL DEFAULT 'email',
+    name_claim VARCHAR(100) NOT NULL DEFAULT 'name',
+    role_claim VARCHAR(100),
+    role_mapping JSONB NOT NULL DEFAULT '{}',
+    allowed_domains TEXT[] NOT NULL DEFAULT '{}',
+
+    -- Just-in-time provisioning of users unknown to the tenant
+    jit_provisioning BOOLEAN NOT NULL DEFAULT FALSE,
+    default_role VARCHAR(50) NOT NULL DEFAULT 'member' CHECK (default_role IN ('admin', 'member', 'viewer')),
+
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_sso_providers_name ON tenant_sso_providers(tenant_id, lower(name));
+
+-- A user signs in with an identity once it is linked: on first login by
+-- a verified email address of the tenant, or when provisioned. Users keep
+-- their password, if they have one.
+CREATE TABLE IF NOT EXISTS user_sso_identities (
+    provider_id UUID NOT NULL REFERENCES tenant_sso_providers(id) ON DELETE CASCADE,
+    subject VARCHAR(255) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_login_at TIMESTAMPTZ,
+
+    PRIMARY KEY (provider_id, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sso_identities_user ON user_sso_identities(user_id);
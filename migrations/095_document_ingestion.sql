@@ -0,0 +1,58 @@
+-- Migration: 095_document_ingestion
+-- Description: Asynchronous document ingestion via the API, processed by the worker with fair scheduling per source
+
+-- A source is the API key, or the user of a user token, that submitted
+-- items. The worker serves the source it served longest ago first, so one
+-- partner's overnight push does not hold up the others.
+CREATE TABLE IF NOT EXISTS ingestion_sources (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    last_served_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ingestion_batches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    source_id UUID NOT NULL REFERENCES ingestion_sources(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingestion_batches_tenant ON ingestion_batches(tenant_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS ingestion_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    source_id UUID NOT NULL REFERENCES ingestion_sources(id) ON DELETE CASCADE,
+    batch_id UUID REFERENCES ingestion_batches(id) ON DELETE CASCADE,
+
+    -- Metadata of the document to create
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    external_id VARCHAR(255),
+    type VARCHAR(100) NOT NULL,
+    title VARCHAR(500) NOT NULL,
+    sender VARCHAR(255),
+    received_at TIMESTAMPTZ,
+    content_type VARCHAR(100),
+    metadata JSONB NOT NULL DEFAULT '{}',
+
+    -- Content staged in document storage until processed
+    staging_path VARCHAR(1000),
+    size BIGINT,
+    upload_expires_at TIMESTAMPTZ,
+
+    status VARCHAR(20) NOT NULL CHECK (status IN ('awaiting_upload', 'queued', 'processing', 'completed', 'duplicate', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    not_before TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    document_id UUID REFERENCES documents(id) ON DELETE SET NULL,
+    error TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingestion_items_pending ON ingestion_items(source_id, created_at)
+    WHERE status IN ('queued', 'processing');
+CREATE INDEX IF NOT EXISTS idx_ingestion_items_batch ON ingestion_items(batch_id, created_at) WHERE batch_id IS NOT NULL;
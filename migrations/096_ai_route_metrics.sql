@@ -0,0 +1,30 @@
+-- Migration: 096_ai_route_metrics
+-- Description: Hourly cost and quality of AI completions per operation and target, for tuning the routing policy
+
+-- One row per hour, operation and target. Calls are counted when made;
+-- quality when the caller has parsed the answer. Platform-wide: routing
+-- policies are not per tenant.
+CREATE TABLE IF NOT EXISTS ai_route_metrics (
+    bucket TIMESTAMPTZ NOT NULL,
+    operation VARCHAR(50) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+
+    requests INTEGER NOT NULL DEFAULT 0,
+    failures INTEGER NOT NULL DEFAULT 0,
+    -- Calls served by a target other than the first of the route
+    fallbacks INTEGER NOT NULL DEFAULT 0,
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+    cost_cents NUMERIC(14, 4) NOT NULL DEFAULT 0,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+
+    -- Answers the caller rated, those it could use, and the confidence they
+    -- claimed where they claim one
+    rated INTEGER NOT NULL DEFAULT 0,
+    valid INTEGER NOT NULL DEFAULT 0,
+    confidence_sum DOUBLE PRECISION NOT NULL DEFAULT 0,
+    confidence_count INTEGER NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (bucket, operation, provider, model)
+);
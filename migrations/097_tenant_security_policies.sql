@@ -0,0 +1,21 @@
+-- Migration: 097_tenant_security_policies
+-- Description: Per-tenant security policy enforced on login, token refresh and password changes
+
+-- Tenants without a row get the platform defaults. Policies can only
+-- tighten them: the minimum password length never drops below 12.
+CREATE TABLE IF NOT EXISTS tenant_security_policies (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    min_password_length INTEGER NOT NULL DEFAULT 12 CHECK (min_password_length BETWEEN 12 AND 128),
+    require_2fa BOOLEAN NOT NULL DEFAULT FALSE,
+
+    -- How long a session can be refreshed after login; NULL keeps the
+    -- lifetime of the refresh token
+    session_duration_minutes INTEGER CHECK (session_duration_minutes > 0),
+
+    -- Client IPs logins and refreshes are accepted from; empty allows all
+    allowed_ip_ranges CIDR[] NOT NULL DEFAULT '{}',
+
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
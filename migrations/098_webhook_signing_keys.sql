@@ -0,0 +1,28 @@
+-- Migration: 098_webhook_signing_keys
+-- Description: Signing keys of webhooks with validity windows, so that secrets can be rotated with an overlap
+
+-- Deliveries are signed with every key valid at the time. A rotation adds
+-- a key and ends the validity of the previous one after the overlap
+-- period, so that at most two keys are valid at once. Keys past their
+-- validity are deleted.
+CREATE TABLE IF NOT EXISTS webhook_signing_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    secret VARCHAR(255) NOT NULL,
+    valid_from TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    valid_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (valid_until IS NULL OR valid_until >= valid_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_signing_keys_webhook ON webhook_signing_keys(webhook_id, valid_from);
+CREATE INDEX IF NOT EXISTS idx_webhook_signing_keys_expiry ON webhook_signing_keys(valid_until) WHERE valid_until IS NOT NULL;
+
+-- The secret of each webhook becomes its first key
+INSERT INTO webhook_signing_keys (webhook_id, secret, valid_from, created_at)
+SELECT id, secret, COALESCE(created_at, NOW()), COALESCE(created_at, NOW())
+FROM webhooks;
+
+-- webhooks.secret stays and is written with the current key, so that the
+-- previous release still signs deliveries after a rollback. A later
+-- migration drops it once the signing keys have shipped.
@@ -0,0 +1,26 @@
+-- Migration: 099_sso_link_approvals
+-- Description: Trusted email claims of SSO providers and admin approval before an identity is linked to an existing user
+
+-- Without trust_email_claim, an identity signs in only with an email the
+-- provider marks as verified. With it, the configured claim is taken as
+-- verified; it may only be a directory-managed sign-in name such as
+-- preferred_username or upn. Entra providers restricted to allowed domains
+-- relied on that before, so they keep working.
+ALTER TABLE tenant_sso_providers ADD COLUMN IF NOT EXISTS trust_email_claim BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE tenant_sso_providers SET trust_email_claim = TRUE
+WHERE kind = 'entra' AND email_claim IN ('preferred_username', 'upn') AND cardinality(allowed_domains) > 0;
+
+-- An identity is linked to an existing user of the tenant on first login
+-- only if an admin approved it for that user. The approval is used up by
+-- the link.
+CREATE TABLE IF NOT EXISTS sso_link_approvals (
+    provider_id UUID NOT NULL REFERENCES tenant_sso_providers(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (provider_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sso_link_approvals_user ON sso_link_approvals(user_id);
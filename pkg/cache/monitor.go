@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable is returned by features that need Redis while it is down
+var ErrUnavailable = errors.New("redis unavailable")
+
+// Availability reports whether Redis is reachable. Features that depend on
+// Redis check it to degrade at once instead of waiting for a timeout.
+type Availability interface {
+	Available() bool
+}
+
+// MonitorStatus is the state of Redis as last seen by a Monitor
+type MonitorStatus struct {
+	Available bool
+	// Since is when Redis became available or unavailable
+	Since time.Time
+	// CheckedAt is the time of the last ping
+	CheckedAt time.Time
+}
+
+// Monitor pings Redis periodically and tracks whether it is reachable.
+// State changes are logged, so an outage and the recovery show up once.
+type Monitor struct {
+	client   *Client
+	interval time.Duration
+	timeout  time.Duration
+	logger   *slog.Logger
+
+	mu     sync.RWMutex
+	status MonitorStatus
+}
+
+// NewMonitor creates a monitor; Redis counts as available until the first
+// check says otherwise
+func NewMonitor(client *Client, interval time.Duration, logger *slog.Logger) *Monitor {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	now := time.Now()
+	return &Monitor{
+		client:   client,
+		interval: interval,
+		timeout:  2 * time.Second,
+		logger:   logger,
+		status:   MonitorStatus{Available: true, Since: now},
+	}
+}
+
+// Available reports whether the last check reached Redis
+func (m *Monitor) Available() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status.Available
+}
+
+// Status returns the state of the last check
+func (m *Monitor) Status() MonitorStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// Check pings Redis once and records the result
+func (m *Monitor) Check(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	err := m.client.Health(ctx)
+	cancel()
+
+	now := time.Now()
+	m.mu.Lock()
+	changed := m.status.Available != (err == nil)
+	m.status.CheckedAt = now
+	if changed {
+		m.status.Available = err == nil
+		m.status.Since = now
+	}
+	m.mu.Unlock()
+
+	switch {
+	case changed && err != nil:
+		m.logger.Error("redis unavailable, running in degraded mode", "error", err)
+	case changed:
+		m.logger.Info("redis available again, leaving degraded mode")
+	}
+	return err == nil
+}
+
+// Run checks Redis every interval until ctx is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check(ctx)
+		}
+	}
+}
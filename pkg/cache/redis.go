@@ -35,8 +35,26 @@ type Client struct {
 	*redis.Client
 }
 
-// NewClient creates a new Redis client
+// NewClient creates a new Redis client and verifies the connection
 func NewClient(ctx context.Context, cfg *RedisConfig) (*Client, error) {
+	client, err := Dial(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify connection
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to ping redis: %w", err)
+	}
+
+	return client, nil
+}
+
+// Dial creates a Redis client without verifying the connection. Commands
+// fail while Redis is unreachable and succeed again once it returns, since
+// the client reconnects on its own.
+func Dial(cfg *RedisConfig) (*Client, error) {
 	if cfg.URL == "" {
 		return nil, fmt.Errorf("redis URL is required")
 	}
@@ -53,15 +71,7 @@ func NewClient(ctx context.Context, cfg *RedisConfig) (*Client, error) {
 	opt.ReadTimeout = cfg.ReadTimeout
 	opt.WriteTimeout = cfg.WriteTimeout
 
-	client := redis.NewClient(opt)
-
-	// Verify connection
-	if err := client.Ping(ctx).Err(); err != nil {
-		client.Close()
-		return nil, fmt.Errorf("failed to ping redis: %w", err)
-	}
-
-	return &Client{Client: client}, nil
+	return &Client{Client: redis.NewClient(opt)}, nil
 }
 
 // Close closes the Redis client
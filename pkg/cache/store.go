@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store caches byte values in Redis under a key prefix. It is best-effort:
+// while Redis is unreachable every Get misses and Set returns the error, so
+// callers fall back to the source of the data.
+type Store struct {
+	client *Client
+	prefix string
+}
+
+// NewStore creates a store keeping its values under prefix
+func NewStore(client *Client, prefix string) *Store {
+	return &Store{client: client, prefix: prefix}
+}
+
+// Get returns the value of key, false if it is missing, expired or Redis
+// is unreachable
+func (s *Store) Get(ctx context.Context, key string) ([]byte, bool) {
+	value, err := s.client.Client.Get(ctx, s.prefix+key).Bytes()
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set stores value under key for ttl
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Client.Set(ctx, s.prefix+key, value, ttl).Err()
+}
+
+// Delete removes key
+func (s *Store) Delete(ctx context.Context, key string) error {
+	return s.client.Client.Del(ctx, s.prefix+key).Err()
+}
@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"errors"
+	"fmt"
 	"unicode"
 
 	"golang.org/x/crypto/bcrypt"
@@ -22,6 +23,21 @@ var (
 	ErrPasswordInvalid     = errors.New("invalid password")
 )
 
+// PasswordTooShortError is returned for a password shorter than the
+// policy's minimum length. It matches ErrPasswordTooShort.
+type PasswordTooShortError struct {
+	MinLength int
+}
+
+func (e *PasswordTooShortError) Error() string {
+	return fmt.Sprintf("password must be at least %d characters", e.MinLength)
+}
+
+// Is reports whether target is ErrPasswordTooShort
+func (e *PasswordTooShortError) Is(target error) bool {
+	return target == ErrPasswordTooShort
+}
+
 // PasswordPolicy defines password requirements
 type PasswordPolicy struct {
 	MinLength        int
@@ -49,7 +65,7 @@ func ValidatePassword(password string, policy *PasswordPolicy) error {
 	}
 
 	if len(password) < policy.MinLength {
-		return ErrPasswordTooShort
+		return &PasswordTooShortError{MinLength: policy.MinLength}
 	}
 
 	var hasUppercase, hasLowercase, hasDigit, hasSpecial bool
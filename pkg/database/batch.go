@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Batcher sends a pgx batch; *pgxpool.Pool, *pgx.Conn and pgx.Tx implement it
+type Batcher interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// BatchQuery is one query of a batch with the function reading its rows.
+// Scan must consume the rows; they are closed afterwards.
+type BatchQuery struct {
+	SQL  string
+	Args []any
+	Scan func(rows pgx.Rows) error
+}
+
+// RunBatch sends the queries in a single round trip and calls their Scan
+// functions in order. It returns the first error. Use it where a request
+// needs several independent result sets, instead of querying them serially.
+func RunBatch(ctx context.Context, db Batcher, queries ...BatchQuery) error {
+	b := &pgx.Batch{}
+	for _, q := range queries {
+		b.Queue(q.SQL, q.Args...).Query(q.Scan)
+	}
+	return db.SendBatch(ctx, b).Close()
+}
+
+// UniqueIDs returns ids without duplicates and nil UUIDs, in first-seen
+// order, for use as an = ANY($1) parameter
+func UniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if id == uuid.Nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+// GroupBy groups items by key, keeping their order within each group. It
+// distributes the rows of an = ANY($1) loader to their parents.
+func GroupBy[K comparable, V any](items []V, key func(V) K) map[K][]V {
+	groups := make(map[K][]V)
+	for _, item := range items {
+		k := key(item)
+		groups[k] = append(groups[k], item)
+	}
+	return groups
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// DefaultPoolMetricsInterval is how often LogPoolMetrics logs the pool state
+const DefaultPoolMetricsInterval = time.Minute
+
+// PoolMetrics is a snapshot of the connection pool and the per-module
+// statement counters
+type PoolMetrics struct {
+	MaxConns             int32                    `json:"max_conns"`
+	TotalConns           int32                    `json:"total_conns"`
+	AcquiredConns        int32                    `json:"acquired_conns"`
+	IdleConns            int32                    `json:"idle_conns"`
+	ConstructingConns    int32                    `json:"constructing_conns"`
+	AcquireCount         int64                    `json:"acquire_count"`
+	EmptyAcquireCount    int64                    `json:"empty_acquire_count"` // acquires without an idle connection
+	CanceledAcquireCount int64                    `json:"canceled_acquire_count"`
+	AcquireDurationMS    int64                    `json:"acquire_duration_ms"` // total time spent acquiring
+	NewConnsCount        int64                    `json:"new_conns_count"`
+	Modules              map[string]ModuleMetrics `json:"modules,omitempty"`
+}
+
+// Saturated reports whether all connections are in use
+func (m PoolMetrics) Saturated() bool {
+	return m.MaxConns > 0 && m.AcquiredConns >= m.MaxConns
+}
+
+// Metrics returns a snapshot of the pool metrics
+func (p *Pool) Metrics() PoolMetrics {
+	s := p.Pool.Stat()
+	return PoolMetrics{
+		MaxConns:             s.MaxConns(),
+		TotalConns:           s.TotalConns(),
+		AcquiredConns:        s.AcquiredConns(),
+		IdleConns:            s.IdleConns(),
+		ConstructingConns:    s.ConstructingConns(),
+		AcquireCount:         s.AcquireCount(),
+		EmptyAcquireCount:    s.EmptyAcquireCount(),
+		CanceledAcquireCount: s.CanceledAcquireCount(),
+		AcquireDurationMS:    s.AcquireDuration().Milliseconds(),
+		NewConnsCount:        s.NewConnsCount(),
+		Modules:              ModuleMetricsSnapshot(),
+	}
+}
+
+// LogPoolMetrics logs the pool metrics every interval until ctx is done, so
+// they reach the log pipeline without a metrics endpoint. It warns when the
+// pool is saturated or acquires were cancelled waiting for a connection
+// since the last interval.
+func (p *Pool) LogPoolMetrics(ctx context.Context, logger *slog.Logger, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastCanceled int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m := p.Metrics()
+		attrs := []any{
+			"max_conns", m.MaxConns,
+			"total_conns", m.TotalConns,
+			"acquired_conns", m.AcquiredConns,
+			"idle_conns", m.IdleConns,
+			"acquire_count", m.AcquireCount,
+			"empty_acquire_count", m.EmptyAcquireCount,
+			"canceled_acquire_count", m.CanceledAcquireCount,
+			"acquire_duration_ms", m.AcquireDurationMS,
+		}
+		for name, mod := range m.Modules {
+			attrs = append(attrs, slog.Group("module_"+name,
+				"slow_queries", mod.SlowQueries,
+				"statement_timeouts", mod.StatementTimeouts,
+			))
+		}
+
+		canceled := m.CanceledAcquireCount - lastCanceled
+		lastCanceled = m.CanceledAcquireCount
+		if m.Saturated() || canceled > 0 {
+			logger.Warn("database pool saturated", append(attrs, "canceled_acquires", canceled)...)
+		} else {
+			logger.Info("database pool", attrs...)
+		}
+	}
+}
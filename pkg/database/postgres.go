@@ -3,10 +3,12 @@ package database
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"austrian-business-infrastructure/internal/security"
 
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,6 +19,14 @@ type PostgresConfig struct {
 	MinConns        int32
 	MaxConnLifetime time.Duration
 	MaxConnIdleTime time.Duration
+
+	// Statement timeouts applied by Module.WithTimeout; see SetStatementTimeouts
+	StatementTimeout time.Duration
+	ModuleTimeouts   map[string]time.Duration
+
+	// Statements slower than this are logged with redacted parameters; 0 disables
+	SlowQueryThreshold time.Duration
+	Logger             *slog.Logger
 }
 
 // DefaultPostgresConfig returns sensible defaults for PostgreSQL connection pool
@@ -27,6 +37,9 @@ func DefaultPostgresConfig(url string) *PostgresConfig {
 		MinConns:        5,
 		MaxConnLifetime: time.Hour,
 		MaxConnIdleTime: 30 * time.Minute,
+
+		StatementTimeout:   DefaultStatementTimeout,
+		SlowQueryThreshold: DefaultSlowQueryThreshold,
 	}
 }
 
@@ -52,10 +65,26 @@ func NewPool(ctx context.Context, cfg *PostgresConfig) (*Pool, error) {
 	poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
 	poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
 
+	// Sessions run in UTC whatever the server's TimeZone setting, so NOW()
+	// and timestamp casts do not depend on the database host. Calendar days
+	// are taken in the tenant's zone by the application (see timezone.Today).
+	poolConfig.ConnConfig.RuntimeParams["timezone"] = "UTC"
+
 	// Configure RLS middleware for automatic tenant context setting
 	// This ensures app.tenant_id is set on each connection when tenant ID is in context
 	security.ConfigurePoolWithRLS(poolConfig)
 
+	// Count queries per context for tests and log slow statements
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	poolConfig.ConnConfig.Tracer = multitracer.New(
+		QueryCounter{},
+		&SlowQueryLogger{Threshold: cfg.SlowQueryThreshold, Logger: logger},
+	)
+	SetStatementTimeouts(cfg.StatementTimeout, cfg.ModuleTimeouts)
+
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
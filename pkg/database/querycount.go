@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// QueryCount counts the statements and database round trips issued with a
+// context. Tests use it to assert that a code path does not query per entity.
+type QueryCount struct {
+	queries    atomic.Int64
+	roundTrips atomic.Int64
+}
+
+// Queries returns the number of statements, counting each query of a batch
+func (c *QueryCount) Queries() int {
+	return int(c.queries.Load())
+}
+
+// RoundTrips returns the number of round trips; a batch is one round trip
+func (c *QueryCount) RoundTrips() int {
+	return int(c.roundTrips.Load())
+}
+
+type queryCountKey struct{}
+
+// WithQueryCount returns a context whose queries are counted. Counting only
+// works on pools created with NewPool or configured with CountQueries.
+func WithQueryCount(ctx context.Context) (context.Context, *QueryCount) {
+	c := &QueryCount{}
+	return context.WithValue(ctx, queryCountKey{}, c), c
+}
+
+// QueryCounter is the pgx tracer behind WithQueryCount. It costs a context
+// lookup per query when no count is requested.
+type QueryCounter struct{}
+
+func countFrom(ctx context.Context) *QueryCount {
+	c, _ := ctx.Value(queryCountKey{}).(*QueryCount)
+	return c
+}
+
+// TraceQueryStart implements pgx.QueryTracer
+func (QueryCounter) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	if c := countFrom(ctx); c != nil {
+		c.queries.Add(1)
+		c.roundTrips.Add(1)
+	}
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer
+func (QueryCounter) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+// TraceBatchStart implements pgx.BatchTracer
+func (QueryCounter) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	if c := countFrom(ctx); c != nil {
+		c.roundTrips.Add(1)
+	}
+	return ctx
+}
+
+// TraceBatchQuery implements pgx.BatchTracer
+func (QueryCounter) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchQueryData) {
+	if c := countFrom(ctx); c != nil {
+		c.queries.Add(1)
+	}
+}
+
+// TraceBatchEnd implements pgx.BatchTracer
+func (QueryCounter) TraceBatchEnd(context.Context, *pgx.Conn, pgx.TraceBatchEndData) {}
+
+// CountQueries installs the QueryCounter on a connection config unless it
+// already has a tracer
+func CountQueries(cfg *pgx.ConnConfig) {
+	if cfg.Tracer == nil {
+		cfg.Tracer = QueryCounter{}
+	}
+}
@@ -0,0 +1,199 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DefaultSlowQueryThreshold is the duration above which a statement is logged
+const DefaultSlowQueryThreshold = 500 * time.Millisecond
+
+// maxLoggedSQL limits the statement text in slow-query logs
+const maxLoggedSQL = 1000
+
+// SlowQueryLogger is a pgx tracer that logs statements slower than Threshold
+// and statements cancelled by a timeout. Bound parameters are never logged;
+// only their types and lengths are (see RedactArgs).
+type SlowQueryLogger struct {
+	Threshold time.Duration
+	Logger    *slog.Logger
+}
+
+type traceStart struct {
+	at   time.Time
+	sql  string
+	args []any
+}
+
+type traceStartKey struct{}
+
+// TraceQueryStart implements pgx.QueryTracer
+func (l *SlowQueryLogger) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, traceStartKey{}, &traceStart{at: time.Now(), sql: data.SQL, args: data.Args})
+}
+
+// TraceQueryEnd implements pgx.QueryTracer
+func (l *SlowQueryLogger) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(traceStartKey{}).(*traceStart)
+	if !ok {
+		return
+	}
+	l.observe(ctx, "query", start, data.CommandTag.RowsAffected(), data.Err)
+}
+
+// TraceBatchStart implements pgx.BatchTracer
+func (l *SlowQueryLogger) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	var sql string
+	if data.Batch != nil && len(data.Batch.QueuedQueries) > 0 {
+		sql = data.Batch.QueuedQueries[0].SQL
+	}
+	return context.WithValue(ctx, traceStartKey{}, &traceStart{at: time.Now(), sql: sql})
+}
+
+// TraceBatchQuery implements pgx.BatchTracer
+func (l *SlowQueryLogger) TraceBatchQuery(context.Context, *pgx.Conn, pgx.TraceBatchQueryData) {}
+
+// TraceBatchEnd implements pgx.BatchTracer
+func (l *SlowQueryLogger) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	start, ok := ctx.Value(traceStartKey{}).(*traceStart)
+	if !ok {
+		return
+	}
+	l.observe(ctx, "batch", start, -1, data.Err)
+}
+
+func (l *SlowQueryLogger) observe(ctx context.Context, kind string, start *traceStart, rows int64, err error) {
+	elapsed := time.Since(start.at)
+	module := ModuleFrom(ctx)
+	timedOut := IsTimeout(err)
+	slow := l.Threshold > 0 && elapsed >= l.Threshold
+	if !timedOut && !slow {
+		return
+	}
+
+	stats := moduleStatsFor(module)
+	msg := "slow query"
+	if timedOut {
+		stats.timeouts.Add(1)
+		msg = "statement timeout"
+	} else {
+		stats.slow.Add(1)
+	}
+
+	if l.Logger == nil {
+		return
+	}
+	attrs := []any{
+		"kind", kind,
+		"module", module,
+		"duration_ms", elapsed.Milliseconds(),
+		"sql", compactSQL(start.sql),
+		"args", RedactArgs(start.args),
+	}
+	if rows >= 0 {
+		attrs = append(attrs, "rows", rows)
+	}
+	if err != nil {
+		attrs = append(attrs, "error", err.Error())
+	}
+	l.Logger.WarnContext(ctx, msg, attrs...)
+}
+
+// IsTimeout reports whether err is a context deadline or a server-side
+// statement_timeout cancellation
+func IsTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "57014" // query_canceled
+}
+
+// RedactArgs describes bound parameters without their values, e.g.
+// "string(12)" or "uuid.UUID", so slow-query logs carry no personal data
+func RedactArgs(args []any) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+			redacted[i] = "nil"
+		case string:
+			redacted[i] = fmt.Sprintf("string(%d)", len(v))
+		case []byte:
+			redacted[i] = fmt.Sprintf("[]byte(%d)", len(v))
+		case *string:
+			if v == nil {
+				redacted[i] = "*string(nil)"
+			} else {
+				redacted[i] = fmt.Sprintf("*string(%d)", len(*v))
+			}
+		default:
+			redacted[i] = fmt.Sprintf("%T", arg)
+		}
+	}
+	return redacted
+}
+
+// compactSQL collapses whitespace and truncates a statement for logging
+func compactSQL(sql string) string {
+	sql = strings.Join(strings.Fields(sql), " ")
+	if len(sql) > maxLoggedSQL {
+		sql = sql[:maxLoggedSQL] + "..."
+	}
+	return sql
+}
+
+// moduleStats counts slow statements and timeouts of a module
+type moduleStats struct {
+	slow     atomic.Int64
+	timeouts atomic.Int64
+}
+
+var modules sync.Map // module name -> *moduleStats
+
+func moduleStatsFor(module string) *moduleStats {
+	if module == "" {
+		module = "other"
+	}
+	if s, ok := modules.Load(module); ok {
+		return s.(*moduleStats)
+	}
+	s, _ := modules.LoadOrStore(module, &moduleStats{})
+	return s.(*moduleStats)
+}
+
+// ModuleMetrics are the slow statements and timeouts of a module since start
+type ModuleMetrics struct {
+	SlowQueries       int64 `json:"slow_queries"`
+	StatementTimeouts int64 `json:"statement_timeouts"`
+}
+
+// ModuleMetricsSnapshot returns the counters of all modules that had a slow
+// statement or a timeout
+func ModuleMetricsSnapshot() map[string]ModuleMetrics {
+	snapshot := make(map[string]ModuleMetrics)
+	modules.Range(func(key, value any) bool {
+		s := value.(*moduleStats)
+		snapshot[key.(string)] = ModuleMetrics{
+			SlowQueries:       s.slow.Load(),
+			StatementTimeouts: s.timeouts.Load(),
+		}
+		return true
+	})
+	return snapshot
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStatementTimeout bounds the statements of modules without an
+// explicit timeout
+const DefaultStatementTimeout = 30 * time.Second
+
+// DefaultModuleTimeouts are the timeouts of modules known for long queries.
+// They are overridden per deployment with DB_MODULE_TIMEOUTS.
+var DefaultModuleTimeouts = map[string]time.Duration{
+	"firmenbuch": 10 * time.Second,
+	"analysis":   15 * time.Second,
+}
+
+var (
+	timeoutsMu     sync.RWMutex
+	defaultTimeout = DefaultStatementTimeout
+	moduleTimeouts = copyTimeouts(DefaultModuleTimeouts)
+)
+
+// SetStatementTimeouts sets the default and per-module statement timeouts.
+// Modules missing from modules keep their DefaultModuleTimeouts entry; a
+// zero duration disables the timeout.
+func SetStatementTimeouts(def time.Duration, modules map[string]time.Duration) {
+	merged := copyTimeouts(DefaultModuleTimeouts)
+	for name, d := range modules {
+		merged[name] = d
+	}
+
+	timeoutsMu.Lock()
+	defer timeoutsMu.Unlock()
+	defaultTimeout = def
+	moduleTimeouts = merged
+}
+
+// StatementTimeout returns the statement timeout of a module
+func StatementTimeout(module string) time.Duration {
+	timeoutsMu.RLock()
+	defer timeoutsMu.RUnlock()
+	if d, ok := moduleTimeouts[module]; ok {
+		return d
+	}
+	return defaultTimeout
+}
+
+// ParseModuleTimeouts parses a list like "firmenbuch=10s,analysis=20s"
+func ParseModuleTimeouts(s string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid module timeout %q, expected module=duration", part)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid timeout for module %s: %q", name, value)
+		}
+		timeouts[strings.TrimSpace(name)] = d
+	}
+	return timeouts, nil
+}
+
+// FormatModuleTimeouts formats the effective module timeouts for logging
+func FormatModuleTimeouts() string {
+	timeoutsMu.RLock()
+	defer timeoutsMu.RUnlock()
+
+	names := make([]string, 0, len(moduleTimeouts))
+	for name := range moduleTimeouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := []string{"default=" + defaultTimeout.String()}
+	for _, name := range names {
+		parts = append(parts, name+"="+moduleTimeouts[name].String())
+	}
+	return strings.Join(parts, ",")
+}
+
+func copyTimeouts(m map[string]time.Duration) map[string]time.Duration {
+	c := make(map[string]time.Duration, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// Module is the repository base helper for statement timeouts. A repository
+// holds one and bounds each method's context with it:
+//
+//	ctx, cancel := r.module.WithTimeout(ctx)
+//	defer cancel()
+//
+// The context also carries the module name, so slow-query logs and timeout
+// counts are attributed to the module.
+type Module struct {
+	Name string
+}
+
+// NewModule returns the helper for a module
+func NewModule(name string) Module {
+	return Module{Name: name}
+}
+
+type moduleKey struct{}
+
+// WithTimeout returns ctx bounded by the module's statement timeout. An
+// earlier deadline of ctx is kept.
+func (m Module) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx = context.WithValue(ctx, moduleKey{}, m.Name)
+	if d := StatementTimeout(m.Name); d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return context.WithCancel(ctx)
+}
+
+// ModuleFrom returns the module a context was bounded for, or "" if none
+func ModuleFrom(ctx context.Context) string {
+	name, _ := ctx.Value(moduleKey{}).(string)
+	return name
+}
@@ -0,0 +1,57 @@
+package httpclient
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Backoff configures the wait between attempts: Initial, growing by
+// Multiplier per attempt up to Max, with ±Jitter (a fraction) applied so
+// that clients failing together do not retry together
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	Jitter     float64
+}
+
+// Delay returns the wait before retry n (0 for the first retry)
+func (b Backoff) Delay(n int) time.Duration {
+	d := float64(b.Initial)
+	for i := 0; i < n; i++ {
+		d *= b.Multiplier
+		if b.Max > 0 && d >= float64(b.Max) {
+			break
+		}
+	}
+	if b.Max > 0 && d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	if b.Jitter > 0 {
+		d += d * b.Jitter * (2*rand.Float64() - 1)
+	}
+	if d < 0 {
+		return 0
+	}
+	return time.Duration(d)
+}
+
+// retryAfter returns the wait a 429 or 503 response asks for
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
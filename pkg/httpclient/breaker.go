@@ -0,0 +1,190 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit
+// breaker of the host is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// State is the state of a circuit breaker
+type State string
+
+const (
+	// StateClosed lets requests through
+	StateClosed State = "closed"
+	// StateOpen rejects requests until the cooldown has passed
+	StateOpen State = "open"
+	// StateHalfOpen lets one probe through; its outcome closes or reopens
+	// the breaker
+	StateHalfOpen State = "half_open"
+)
+
+// BreakerConfig configures circuit breakers
+type BreakerConfig struct {
+	// Threshold is the number of consecutive failures that opens a breaker
+	Threshold int
+	// Cooldown is how long a breaker stays open before a probe is let through
+	Cooldown time.Duration
+}
+
+// DefaultBreakerConfig returns the default breaker configuration
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		Threshold: 5,
+		Cooldown:  30 * time.Second,
+	}
+}
+
+// Breakers holds one circuit breaker per host. A failure is a transport
+// error or a 502, 503 or 504 response; other responses count as success.
+type Breakers struct {
+	config BreakerConfig
+	now    func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]*breaker
+}
+
+// Shared is the breaker set clients use unless configured otherwise, so
+// that all clients of a host see the same outage
+var Shared = NewBreakers(DefaultBreakerConfig())
+
+// NewBreakers creates a breaker set
+func NewBreakers(config BreakerConfig) *Breakers {
+	if config.Threshold <= 0 {
+		config.Threshold = DefaultBreakerConfig().Threshold
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = DefaultBreakerConfig().Cooldown
+	}
+	return &Breakers{
+		config: config,
+		now:    time.Now,
+		hosts:  make(map[string]*breaker),
+	}
+}
+
+type breaker struct {
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// outcome of an attempt as seen by a breaker
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeFailure
+	// outcomeIgnored is an attempt cancelled by the caller
+	outcomeIgnored
+)
+
+// allow reports whether a request to host may be sent
+func (b *Breakers) allow(host string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br := b.hosts[host]
+	if br == nil {
+		return true
+	}
+	switch br.state {
+	case StateOpen:
+		if b.now().Sub(br.openedAt) < b.config.Cooldown {
+			return false
+		}
+		br.state = StateHalfOpen
+		br.probing = true
+		return true
+	case StateHalfOpen:
+		if br.probing {
+			return false
+		}
+		br.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the breaker of host with the outcome of an attempt
+func (b *Breakers) record(host string, o outcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br := b.hosts[host]
+	if br == nil {
+		if o != outcomeFailure {
+			return
+		}
+		br = &breaker{state: StateClosed}
+		b.hosts[host] = br
+	}
+
+	switch o {
+	case outcomeSuccess:
+		br.state = StateClosed
+		br.failures = 0
+		br.probing = false
+	case outcomeFailure:
+		br.failures++
+		br.probing = false
+		if br.state == StateHalfOpen || br.failures >= b.config.Threshold {
+			br.state = StateOpen
+			br.openedAt = b.now()
+		}
+	case outcomeIgnored:
+		br.probing = false
+	}
+}
+
+// Allow reports whether a call may be made. Together with Record it lets a
+// breaker set guard calls other than HTTP attempts, keyed by any name
+// instead of a host: a half-open breaker lets one caller through, which
+// must then record its outcome.
+func (b *Breakers) Allow(key string) bool {
+	return b.allow(key)
+}
+
+// Record updates the breaker of key with the outcome of a call let through
+// by Allow. A nil err is a success. A call the caller cancelled counts as
+// neither, and neither does a failure that failed(err) rejects; failed may
+// be nil to count every error.
+func (b *Breakers) Record(key string, err error, failed func(error) bool) {
+	switch {
+	case err == nil:
+		b.record(key, outcomeSuccess)
+	case errors.Is(err, context.Canceled), failed != nil && !failed(err):
+		b.record(key, outcomeIgnored)
+	default:
+		b.record(key, outcomeFailure)
+	}
+}
+
+// State returns the breaker state of a host
+func (b *Breakers) State(host string) State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if br := b.hosts[host]; br != nil {
+		return br.state
+	}
+	return StateClosed
+}
+
+// States returns the state of every host that has failed at least once
+func (b *Breakers) States() map[string]State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	states := make(map[string]State, len(b.hosts))
+	for host, br := range b.hosts {
+		states[host] = br.state
+	}
+	return states
+}
@@ -0,0 +1,299 @@
+// Package httpclient is the HTTP client of the outbound integrations
+// (FinanzOnline, ELDA, Firmenbuch, AI). It retries transient failures with
+// jittered backoff, keeps a circuit breaker per host, bounds each request
+// by a time budget across all attempts and reports every attempt to hooks.
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Config configures a client
+type Config struct {
+	// Name identifies the client in logs, e.g. "elda"
+	Name string
+	// Timeout bounds a single attempt, including reading the response
+	Timeout time.Duration
+	// Budget bounds the whole request across all attempts and waits;
+	// zero means only the context deadline applies
+	Budget time.Duration
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	Backoff    Backoff
+	// Retryable decides whether an attempt is retried; DefaultRetryable
+	// if nil
+	Retryable func(resp *http.Response, err error) bool
+	// Breakers holds the circuit breakers; Shared if nil
+	Breakers  *Breakers
+	Transport http.RoundTripper
+	Hooks     Hooks
+}
+
+// Hooks are called around every attempt
+type Hooks struct {
+	// BeforeAttempt may adjust the request of an attempt, e.g. its URL. An
+	// error aborts the request without sending it.
+	BeforeAttempt func(req *http.Request) error
+	// AfterAttempt receives the outcome of an attempt
+	AfterAttempt func(a *Attempt)
+}
+
+// Attempt is one try of a request, as reported to hooks and observers
+type Attempt struct {
+	Client      string
+	Number      int
+	Request     *http.Request
+	RequestBody []byte
+	// Response is nil if the request failed; its body is ResponseBody
+	Response     *http.Response
+	ResponseBody []byte
+	Err          error
+	StartedAt    time.Time
+	Duration     time.Duration
+	// Retry is set when another attempt follows after Wait
+	Retry bool
+	Wait  time.Duration
+}
+
+// DefaultConfig returns the default configuration of a client
+func DefaultConfig(name string) Config {
+	return Config{
+		Name:       name,
+		Timeout:    30 * time.Second,
+		Budget:     2 * time.Minute,
+		MaxRetries: 3,
+		Backoff: Backoff{
+			Initial:    500 * time.Millisecond,
+			Max:        30 * time.Second,
+			Multiplier: 2,
+			Jitter:     0.2,
+		},
+	}
+}
+
+// DefaultRetryable retries transport errors and 408, 429, 502, 503 and 504
+// responses
+func DefaultRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// RetryServerErrors retries transport errors, 429 and all 5xx responses
+func RetryServerErrors(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// Client sends requests with retries, circuit breaking and a time budget.
+// Responses are read into memory, so it suits API calls, not downloads.
+type Client struct {
+	name       string
+	http       *http.Client
+	budget     time.Duration
+	maxRetries int
+	backoff    Backoff
+	retryable  func(resp *http.Response, err error) bool
+	breakers   *Breakers
+	hooks      Hooks
+}
+
+// New creates a client
+func New(cfg Config) *Client {
+	if cfg.Retryable == nil {
+		cfg.Retryable = DefaultRetryable
+	}
+	if cfg.Breakers == nil {
+		cfg.Breakers = Shared
+	}
+	return &Client{
+		name:       cfg.Name,
+		http:       &http.Client{Timeout: cfg.Timeout, Transport: cfg.Transport},
+		budget:     cfg.Budget,
+		maxRetries: cfg.MaxRetries,
+		backoff:    cfg.Backoff,
+		retryable:  cfg.Retryable,
+		breakers:   cfg.Breakers,
+		hooks:      cfg.Hooks,
+	}
+}
+
+// SetTransport replaces the transport, e.g. for a new client certificate
+func (c *Client) SetTransport(t http.RoundTripper) {
+	c.http.Transport = t
+}
+
+// Breakers returns the circuit breakers of the client
+func (c *Client) Breakers() *Breakers {
+	return c.breakers
+}
+
+type maxRetriesKey struct{}
+
+type observerKey struct{}
+
+// WithMaxRetries overrides the number of retries for requests with ctx
+func WithMaxRetries(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, maxRetriesKey{}, n)
+}
+
+// WithoutRetry sends requests with ctx exactly once, for submissions that
+// must not be sent twice
+func WithoutRetry(ctx context.Context) context.Context {
+	return WithMaxRetries(ctx, 0)
+}
+
+// WithObserver reports the attempts of requests with ctx to fn, after the
+// hooks of the client
+func WithObserver(ctx context.Context, fn func(a *Attempt)) context.Context {
+	return context.WithValue(ctx, observerKey{}, fn)
+}
+
+// Do sends a request. A retryable response is retried until MaxRetries or
+// the budget runs out; the last response is then returned like any other,
+// so callers check the status code as with http.Client.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+	}
+
+	ctx := req.Context()
+	if c.budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.budget)
+		defer cancel()
+	}
+	maxRetries := c.maxRetries
+	if n, ok := ctx.Value(maxRetriesKey{}).(int); ok {
+		maxRetries = n
+	}
+	observer, _ := ctx.Value(observerKey{}).(func(a *Attempt))
+
+	for n := 0; ; n++ {
+		attemptReq := req.Clone(ctx)
+		if body != nil {
+			attemptReq.Body = io.NopCloser(bytes.NewReader(body))
+			attemptReq.ContentLength = int64(len(body))
+		}
+		if c.hooks.BeforeAttempt != nil {
+			if err := c.hooks.BeforeAttempt(attemptReq); err != nil {
+				return nil, err
+			}
+		}
+		host := attemptReq.URL.Host
+		if !c.breakers.allow(host) {
+			return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+		}
+
+		a := &Attempt{Client: c.name, Number: n + 1, Request: attemptReq, RequestBody: body, StartedAt: time.Now()}
+		a.Response, a.ResponseBody, a.Err = c.send(attemptReq)
+		a.Duration = time.Since(a.StartedAt)
+		c.breakers.record(host, classify(ctx, a.Response, a.Err))
+
+		if n < maxRetries && ctx.Err() == nil && c.retryable(a.Response, a.Err) {
+			a.Wait = c.backoff.Delay(n)
+			if ra := retryAfter(a.Response); ra > a.Wait {
+				a.Wait = ra
+			}
+			deadline, ok := ctx.Deadline()
+			a.Retry = !ok || time.Now().Add(a.Wait).Before(deadline)
+		}
+		if c.hooks.AfterAttempt != nil {
+			c.hooks.AfterAttempt(a)
+		}
+		if observer != nil {
+			observer(a)
+		}
+		if !a.Retry {
+			return a.Response, a.Err
+		}
+
+		select {
+		case <-time.After(a.Wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// send makes one attempt and reads the response into memory
+func (c *Client) send(req *http.Request) (*http.Response, []byte, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, body, nil
+}
+
+// classify maps the result of an attempt to a breaker outcome
+func classify(ctx context.Context, resp *http.Response, err error) outcome {
+	switch {
+	case err != nil && (ctx.Err() != nil || errors.Is(err, context.Canceled)):
+		return outcomeIgnored
+	case err != nil:
+		return outcomeFailure
+	case resp.StatusCode == http.StatusBadGateway, resp.StatusCode == http.StatusServiceUnavailable,
+		resp.StatusCode == http.StatusGatewayTimeout:
+		return outcomeFailure
+	default:
+		return outcomeSuccess
+	}
+}
+
+// LogAttempts returns an AfterAttempt hook that logs failed attempts as
+// warnings and others at debug level. Bodies are never logged, since they
+// carry credentials and personal data.
+func LogAttempts(logger *slog.Logger) func(a *Attempt) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(a *Attempt) {
+		attrs := []any{
+			"client", a.Client,
+			"method", a.Request.Method,
+			"host", a.Request.URL.Host,
+			"path", a.Request.URL.Path,
+			"attempt", a.Number,
+			"duration", a.Duration,
+		}
+		if a.Response != nil {
+			attrs = append(attrs, "status", a.Response.StatusCode)
+		}
+		if a.Err != nil {
+			attrs = append(attrs, "error", a.Err)
+		}
+		switch {
+		case a.Retry:
+			logger.Warn("outbound request failed, retrying", append(attrs, "wait", a.Wait)...)
+		case a.Err != nil || a.Response.StatusCode >= 500:
+			logger.Warn("outbound request failed", attrs...)
+		default:
+			logger.Debug("outbound request", attrs...)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used when no currency is given
+const DefaultCurrency = "EUR"
+
+// decimalPattern matches plain decimals; no exponents, fractions or prefixes
+var decimalPattern = regexp.MustCompile(`^[-+]?(\d+(\.\d*)?|\.\d+)$`)
+
+var (
+	ErrInvalidAmount = errors.New("invalid amount")
+	ErrOutOfRange    = errors.New("amount out of range")
+)
+
+// Money is an amount in the minor unit (cents) of a currency.
+//
+// In JSON it is a decimal number of the major unit, e.g. 1234.56, which is
+// what float64 fields produced before. Decoding reads the number's text
+// exactly instead of going through float64, and also accepts a string
+// ("1234.56") and an object ({"cents": 123456, "currency": "EUR"}).
+// In the database it is stored as BIGINT cents; the currency lives in its
+// own column.
+type Money struct {
+	Cents    int64
+	Currency string
+}
+
+// New creates an amount in cents. An empty currency means EUR.
+func New(cents int64, currency string) Money {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return Money{Cents: cents, Currency: currency}
+}
+
+// EUR creates an amount in euro cents
+func EUR(cents int64) Money {
+	return Money{Cents: cents, Currency: DefaultCurrency}
+}
+
+// Parse parses a decimal amount of the major unit such as "1234.56" or
+// "-0.5". More than two decimals are rounded half away from zero.
+func Parse(s, currency string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return Money{}, ErrInvalidAmount
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Money{}, ErrInvalidAmount
+	}
+	cents, err := round(r.Mul(r, big.NewRat(100, 1)))
+	if err != nil {
+		return Money{}, err
+	}
+	return New(cents, currency), nil
+}
+
+// FromFloat converts a float amount of the major unit, e.g. from a legacy
+// source. The float's shortest decimal representation is used, so 1.005
+// becomes 1.01 rather than 1.00.
+func FromFloat(amount float64, currency string) Money {
+	m, err := Parse(strconv.FormatFloat(amount, 'f', -1, 64), currency)
+	if err != nil {
+		return New(0, currency)
+	}
+	return m
+}
+
+// Add returns m + o. Both must be in the same currency.
+func (m Money) Add(o Money) Money {
+	return Money{Cents: m.Cents + o.Cents, Currency: m.currency()}
+}
+
+// Sub returns m - o. Both must be in the same currency.
+func (m Money) Sub(o Money) Money {
+	return Money{Cents: m.Cents - o.Cents, Currency: m.currency()}
+}
+
+// Mul multiplies by a quantity, e.g. a unit price by 2.5 hours, rounding
+// half away from zero to whole cents
+func (m Money) Mul(quantity float64) Money {
+	return m.scale(quantity, 1)
+}
+
+// Percent returns percent % of m, e.g. the 20% VAT of a net amount, rounding
+// half away from zero to whole cents
+func (m Money) Percent(percent float64) Money {
+	return m.scale(percent, 100)
+}
+
+func (m Money) scale(factor float64, divisor int64) Money {
+	f, ok := new(big.Rat).SetString(strconv.FormatFloat(factor, 'f', -1, 64))
+	if !ok {
+		return Money{Currency: m.currency()}
+	}
+	r := new(big.Rat).SetInt64(m.Cents)
+	r.Mul(r, f).Quo(r, big.NewRat(divisor, 1))
+	cents, err := round(r)
+	if err != nil {
+		return Money{Currency: m.currency()}
+	}
+	return Money{Cents: cents, Currency: m.currency()}
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Cents == 0
+}
+
+// Float returns the amount in the major unit. Only use it for display or
+// ratios, never to compute amounts.
+func (m Money) Float() float64 {
+	return float64(m.Cents) / 100
+}
+
+// Decimal formats the amount of the major unit with two decimals, e.g.
+// -1050 cents as "-10.50"
+func (m Money) Decimal() string {
+	cents := m.Cents
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// String formats the amount with its currency, e.g. "10.50 EUR"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.currency()
+}
+
+func (m Money) currency() string {
+	if m.Currency == "" {
+		return DefaultCurrency
+	}
+	return m.Currency
+}
+
+// MarshalJSON encodes the amount as a decimal number of the major unit
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.Decimal()), nil
+}
+
+// UnmarshalJSON decodes a number, a decimal string or a cents object. The
+// currency is kept unless the object names one.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '{' {
+		var obj struct {
+			Cents    *int64 `json:"cents"`
+			Currency string `json:"currency"`
+		}
+		if err := json.Unmarshal(data, &obj); err != nil || obj.Cents == nil {
+			return ErrInvalidAmount
+		}
+		currency := obj.Currency
+		if currency == "" {
+			currency = m.Currency
+		}
+		*m = New(*obj.Cents, currency)
+		return nil
+	}
+
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return ErrInvalidAmount
+		}
+	}
+	parsed, err := Parse(s, m.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value stores the amount as cents
+func (m Money) Value() (driver.Value, error) {
+	return m.Cents, nil
+}
+
+// Scan reads cents. The currency defaults to EUR; callers set it from the
+// row's currency column where there is one.
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = New(0, m.Currency)
+	case int64:
+		*m = New(v, m.Currency)
+	case int32:
+		*m = New(int64(v), m.Currency)
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+	return nil
+}
+
+// round rounds a rational to an int64, half away from zero
+func round(r *big.Rat) (int64, error) {
+	num := new(big.Int).Set(r.Num())
+	den := r.Denom()
+
+	neg := num.Sign() < 0
+	num.Abs(num)
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if neg {
+		q.Neg(q)
+	}
+	if !q.IsInt64() {
+		return 0, ErrOutOfRange
+	}
+	return q.Int64(), nil
+}
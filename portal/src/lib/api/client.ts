@@ -38,6 +38,7 @@ class PortalApiClient {
 
 		const headers: Record<string, string> = {
 			'Content-Type': 'application/json',
+			'X-Requested-With': 'XMLHttpRequest', // Required by the API's CSRF check
 			...(options.headers as Record<string, string>)
 		};
 
@@ -100,7 +101,8 @@ class PortalApiClient {
 				method: 'POST',
 				credentials: 'include', // Send httpOnly refresh cookie
 				headers: {
-					'Content-Type': 'application/json'
+					'Content-Type': 'application/json',
+					'X-Requested-With': 'XMLHttpRequest'
 				}
 			});
 
@@ -123,7 +125,8 @@ class PortalApiClient {
 			method: 'POST',
 			credentials: 'include',
 			headers: {
-				'Content-Type': 'application/json'
+				'Content-Type': 'application/json',
+				'X-Requested-With': 'XMLHttpRequest'
 			},
 			body: JSON.stringify({ email, password })
 		});
@@ -157,7 +160,8 @@ class PortalApiClient {
 			method: 'POST',
 			credentials: 'include',
 			headers: {
-				'Content-Type': 'application/json'
+				'Content-Type': 'application/json',
+				'X-Requested-With': 'XMLHttpRequest'
 			}
 		});
 
@@ -227,7 +231,7 @@ class PortalApiClient {
 		formData.append('category', category);
 		if (note) formData.append('note', note);
 
-		const headers: Record<string, string> = {};
+		const headers: Record<string, string> = { 'X-Requested-With': 'XMLHttpRequest' };
 		if (this.accessToken) {
 			headers['Authorization'] = `Bearer ${this.accessToken}`;
 		}
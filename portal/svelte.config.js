@@ -9,6 +9,23 @@ const config = {
 		alias: {
 			$lib: './src/lib',
 			$components: './src/lib/components'
+		},
+		// Server-rendered pages (signing links, status pages) get a per-request
+		// nonce for SvelteKit's inline hydration script instead of 'unsafe-inline'
+		csp: {
+			mode: 'auto',
+			directives: {
+				'default-src': ['self'],
+				'script-src': ['self'],
+				'style-src': ['self', 'unsafe-inline'],
+				'img-src': ['self', 'data:', 'blob:'],
+				'connect-src': ['self'],
+				'object-src': ['none'],
+				'frame-ancestors': ['none'],
+				'base-uri': ['self'],
+				'form-action': ['self'],
+				'report-uri': ['/api/v1/csp-report']
+			}
 		}
 	}
 };
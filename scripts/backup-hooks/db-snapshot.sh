@@ -0,0 +1,25 @@
+#!/bin/bash
+# =============================================================================
+# Database snapshot hook (BACKUP_DB_SNAPSHOT_HOOK)
+# =============================================================================
+# Called by the server while the job queue is paused. Dumps the database in
+# pg_dump custom format and prints the dump location on the last line of
+# stdout; the server records it in the backup manifest.
+#
+# Environment:
+#   DATABASE_URL       database to dump (inherited from the server)
+#   BACKUP_ID          id of the backup run
+#   BACKUP_SNAPSHOT_DIR target directory (default ./backups)
+# =============================================================================
+
+set -euo pipefail
+
+SNAPSHOT_DIR="${BACKUP_SNAPSHOT_DIR:-./backups}/db"
+mkdir -p "$SNAPSHOT_DIR"
+TARGET="$SNAPSHOT_DIR/${BACKUP_ID}.dump"
+
+# Logs go to stderr; stdout carries the result
+echo "dumping database to $TARGET" >&2
+pg_dump --format=custom --no-owner --file="$TARGET" "$DATABASE_URL"
+
+echo "$TARGET"
@@ -0,0 +1,34 @@
+#!/bin/bash
+# =============================================================================
+# Restore check hook (BACKUP_RESTORE_HOOK)
+# =============================================================================
+# Called by the worker for every completed backup. Restores the database dump
+# into the scratch database; the worker then compares the restored schema
+# version and row counts with the backup manifest. Optionally unpacks the
+# storage snapshot and reports its object count.
+#
+# WARNING: the scratch database is overwritten. Never point
+# BACKUP_SCRATCH_DATABASE_URL at a database holding real data.
+#
+# Environment:
+#   BACKUP_ID                id of the backup run
+#   BACKUP_DB_SNAPSHOT       location printed by the database snapshot hook
+#   BACKUP_STORAGE_SNAPSHOT  location printed by the storage snapshot hook
+#   SCRATCH_DATABASE_URL     database to restore into
+# =============================================================================
+
+set -euo pipefail
+
+echo "restoring $BACKUP_DB_SNAPSHOT into scratch database" >&2
+pg_restore --clean --if-exists --no-owner --exit-on-error \
+    --dbname="$SCRATCH_DATABASE_URL" "$BACKUP_DB_SNAPSHOT"
+
+if [ -n "${BACKUP_STORAGE_SNAPSHOT:-}" ]; then
+    WORK_DIR=$(mktemp -d)
+    trap 'rm -rf "$WORK_DIR"' EXIT
+    tar -xzf "$BACKUP_STORAGE_SNAPSHOT" -C "$WORK_DIR"
+    OBJECTS=$(find "$WORK_DIR" -type f | wc -l)
+    printf '{"location":"scratch","objects":%d}\n' "$OBJECTS"
+else
+    echo "scratch"
+fi
@@ -0,0 +1,29 @@
+#!/bin/bash
+# =============================================================================
+# Storage snapshot hook (BACKUP_STORAGE_SNAPSHOT_HOOK)
+# =============================================================================
+# Copies local document storage and prints a JSON result with the snapshot
+# location and the number of objects copied. The object count is part of the
+# manifest and compared by the restore check.
+#
+# For S3 storage, replace the copy with a bucket sync or a versioned-bucket
+# marker and report the object count the same way.
+#
+# Environment:
+#   STORAGE_LOCAL_PATH  document storage (inherited from the server)
+#   BACKUP_ID           id of the backup run
+#   BACKUP_SNAPSHOT_DIR target directory (default ./backups)
+# =============================================================================
+
+set -euo pipefail
+
+SOURCE="${STORAGE_LOCAL_PATH:-./data/documents}"
+SNAPSHOT_DIR="${BACKUP_SNAPSHOT_DIR:-./backups}/storage"
+mkdir -p "$SNAPSHOT_DIR"
+TARGET="$SNAPSHOT_DIR/${BACKUP_ID}.tar.gz"
+
+echo "archiving $SOURCE to $TARGET" >&2
+tar -czf "$TARGET" -C "$SOURCE" .
+OBJECTS=$(find "$SOURCE" -type f | wc -l)
+
+printf '{"location":"%s","objects":%d}\n' "$TARGET" "$OBJECTS"
@@ -0,0 +1,114 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/abwesenheit"
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/eldameldung"
+	"austrian-business-infrastructure/tests/integration/platform"
+)
+
+// TestAbwesenheitRoutesStayInTenant checks that the absence and AUA routes
+// only serve the records of the tenant's own ELDA accounts.
+func TestAbwesenheitRoutesStayInTenant(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	env := platform.Setup(t)
+	defer env.Cleanup()
+	ctx := context.Background()
+
+	demoSvc, err := demo.NewService(env.DB, []byte("abwesenheit-tenant-test-key-32b!"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed := func(name string, seed int64) (tenantID, eldaAccountID uuid.UUID) {
+		seeded, err := demoSvc.Seed(ctx, demo.Options{Name: name, Seed: seed, Employees: 1, Documents: 1, Invoices: 1}, "test", nil)
+		if err != nil {
+			t.Fatalf("seed %s: %v", name, err)
+		}
+		t.Cleanup(func() {
+			if err := demoSvc.Teardown(context.Background(), seeded.TenantID, nil); err != nil {
+				t.Errorf("teardown: %v", err)
+			}
+		})
+		if err := env.DB.QueryRow(ctx, `
+			SELECT ea.id FROM elda_accounts ea JOIN accounts a ON a.id = ea.account_id
+			WHERE a.tenant_id = $1`, seeded.TenantID).Scan(&eldaAccountID); err != nil {
+			t.Fatalf("find ELDA account: %v", err)
+		}
+		return seeded.TenantID, eldaAccountID
+	}
+	tenantA, accountA := seed("Abwesenheit A GmbH", 78)
+	_, accountB := seed("Abwesenheit B GmbH", 79)
+
+	// An absence of tenant B with its AUA
+	repo := abwesenheit.NewRepository(env.DB)
+	absence := &elda.Abwesenheit{
+		ELDAAccountID: accountB,
+		SVNummer:      "1237010180",
+		Vorname:       "Maria",
+		Nachname:      "Huber",
+		Art:           elda.AbwesenheitKrankenstand,
+		Von:           time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC),
+		Bestaetigung:  true,
+	}
+	if err := repo.Create(ctx, absence); err != nil {
+		t.Fatalf("create absence: %v", err)
+	}
+	aua := abwesenheit.BuildAUA(absence, time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC))
+	aua.Anforderungsdatum = time.Date(2025, time.April, 2, 0, 0, 0, 0, time.UTC)
+	if err := repo.CreateAUA(ctx, aua); err != nil {
+		t.Fatalf("create AUA: %v", err)
+	}
+
+	router := chi.NewRouter()
+	router.Route("/api/v1", abwesenheit.NewHandler(abwesenheit.NewService(abwesenheit.ServiceConfig{
+		Repository:        repo,
+		MeldungRepository: eldameldung.NewRepository(env.DB),
+	})).RegisterRoutes)
+
+	do := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), api.TenantIDKey, tenantA.String()))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := do("GET", "/api/v1/abwesenheiten?elda_account_id="+accountA.String(), ""); code != http.StatusOK {
+		t.Errorf("own absences: got %d, want 200", code)
+	}
+	for _, c := range []struct{ method, path, body string }{
+		{"GET", "/api/v1/abwesenheiten?elda_account_id=" + accountB.String(), ""},
+		{"POST", "/api/v1/abwesenheiten", `{"elda_account_id": "` + accountB.String() + `", "sv_nummer": "1237010180", "art": "urlaub", "von": "2025-08-04"}`},
+		{"GET", "/api/v1/abwesenheiten/" + absence.ID.String(), ""},
+		{"PUT", "/api/v1/abwesenheiten/" + absence.ID.String(), `{"bis": "2025-03-20"}`},
+		{"DELETE", "/api/v1/abwesenheiten/" + absence.ID.String(), ""},
+		{"POST", "/api/v1/abwesenheiten/" + absence.ID.String() + "/aua", `{"anforderungsdatum": "2025-04-02"}`},
+		{"GET", "/api/v1/abwesenheiten/" + absence.ID.String() + "/aua", ""},
+		{"GET", "/api/v1/aua/" + aua.ID.String(), ""},
+		{"GET", "/api/v1/aua/" + aua.ID.String() + "/preview?dienstgeber_nr=123", ""},
+		{"POST", "/api/v1/aua/" + aua.ID.String() + "/send", `{"dienstgeber_nr": "123"}`},
+	} {
+		if code := do(c.method, c.path, c.body); code != http.StatusNotFound {
+			t.Errorf("%s %s: got %d, want 404", c.method, c.path, code)
+		}
+	}
+
+	if _, err := repo.GetByID(ctx, absence.ID); err != nil {
+		t.Errorf("the foreign absence should be left alone: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/activity"
+	"austrian-business-infrastructure/internal/analysis"
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/assessment"
+	"austrian-business-infrastructure/internal/contract"
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/internal/pflichten"
+	"austrian-business-infrastructure/tests/integration/platform"
+
+	"github.com/google/uuid"
+)
+
+// TestAnalysisRespectsDocumentAccess checks that the analyses, deadlines,
+// action items, contracts, Bescheid comparisons, Pflichten-Radar entries and
+// activity feed of a restricted document are hidden from users outside its
+// access list and that only listed users are notified about it.
+func TestAnalysisRespectsDocumentAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	env := platform.Setup(t)
+	defer env.Cleanup()
+	ctx := context.Background()
+
+	demoSvc, err := demo.NewService(env.DB, []byte("analysis-access-encryption-key32"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeded, err := demoSvc.Seed(ctx, demo.Options{Name: "Zugriff GmbH", Seed: 73, Employees: 1, Documents: 4, Invoices: 1}, "test", nil)
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := demoSvc.Teardown(context.Background(), seeded.TenantID, nil); err != nil {
+			t.Errorf("teardown: %v", err)
+		}
+	})
+	tenantID := seeded.TenantID
+	var ownerID, documentID uuid.UUID
+	if err := env.DB.QueryRow(ctx, `SELECT id FROM users WHERE email = $1`, seeded.OwnerEmail).Scan(&ownerID); err != nil {
+		t.Fatalf("find owner: %v", err)
+	}
+	if err := env.DB.QueryRow(ctx, `
+		SELECT document_id FROM extracted_deadlines WHERE tenant_id = $1 LIMIT 1`, tenantID).Scan(&documentID); err != nil {
+		t.Fatalf("find document with deadline: %v", err)
+	}
+
+	docs := document.NewRepository(env.DB)
+	if err := docs.SetAccess(ctx, tenantID, &document.Access{DocumentID: &documentID, UserIDs: []uuid.UUID{ownerID}}, &ownerID); err != nil {
+		t.Fatalf("restrict document: %v", err)
+	}
+
+	otherID := uuid.New()
+	asOwner := context.WithValue(ctx, api.UserIDKey, ownerID.String())
+	asOther := context.WithValue(ctx, api.UserIDKey, otherID.String())
+	repo := analysis.NewRepository(env.DB)
+
+	_, all, err := repo.ListAnalyses(asOwner, tenantID, 100, 0, false)
+	if err != nil {
+		t.Fatalf("list analyses: %v", err)
+	}
+	_, visible, err := repo.ListAnalyses(asOther, tenantID, 100, 0, false)
+	if err != nil {
+		t.Fatalf("list analyses: %v", err)
+	}
+	if visible != all-1 {
+		t.Errorf("other user sees %d of %d analyses, want all but the restricted one", visible, all)
+	}
+
+	if _, err := repo.GetFullAnalysis(asOther, documentID); !errors.Is(err, analysis.ErrAnalysisNotFound) {
+		t.Errorf("full analysis for another user: got %v, want ErrAnalysisNotFound", err)
+	}
+	for name, c := range map[string]context.Context{"owner": asOwner, "job": ctx} {
+		if _, err := repo.GetFullAnalysis(c, documentID); err != nil {
+			t.Errorf("full analysis for %s: %v", name, err)
+		}
+	}
+
+	deadlines, err := repo.GetDeadlinesByDocument(asOther, documentID)
+	if err != nil {
+		t.Fatalf("get deadlines: %v", err)
+	}
+	items, err := repo.GetActionItemsByDocument(asOther, documentID)
+	if err != nil {
+		t.Fatalf("get action items: %v", err)
+	}
+	if len(deadlines) != 0 || len(items) != 0 {
+		t.Errorf("other user sees %d deadlines and %d action items of the restricted document", len(deadlines), len(items))
+	}
+	if deadlines, err := repo.GetDeadlinesByDocument(asOwner, documentID); err != nil || len(deadlines) == 0 {
+		t.Errorf("owner deadlines = %d, %v; want the document's deadlines", len(deadlines), err)
+	}
+
+	viewers, err := docs.FilterViewers(ctx, tenantID, documentID, []uuid.UUID{ownerID, otherID})
+	if err != nil {
+		t.Fatalf("filter viewers: %v", err)
+	}
+	if !viewers[ownerID] || viewers[otherID] {
+		t.Errorf("viewers = %v, want only the owner", viewers)
+	}
+
+	// Action items without a document stay visible
+	var manualID uuid.UUID
+	if err := env.DB.QueryRow(ctx, `
+		INSERT INTO action_items (tenant_id, title, action_type, status)
+		VALUES ($1, 'Rückruf Steuerberater', 'other', 'pending')
+		RETURNING id`, tenantID).Scan(&manualID); err != nil {
+		t.Fatalf("create manual action item: %v", err)
+	}
+	pending, err := repo.GetPendingActionItems(asOther, tenantID)
+	if err != nil {
+		t.Fatalf("get pending action items: %v", err)
+	}
+	found := false
+	for _, item := range pending {
+		found = found || item.ID == manualID
+	}
+	if !found {
+		t.Error("other user should see action items without a document")
+	}
+
+	// Contracts
+	contracts := contract.NewRepository(env.DB)
+	c := &contract.Contract{TenantID: tenantID, DocumentID: documentID, Title: "Mietvertrag",
+		Source: contract.SourceManual, Status: contract.StatusActive}
+	if err := contracts.Save(ctx, c); err != nil {
+		t.Fatalf("save contract: %v", err)
+	}
+	if _, err := contracts.Get(asOther, tenantID, c.ID); !errors.Is(err, contract.ErrContractNotFound) {
+		t.Errorf("contract for another user: got %v, want ErrContractNotFound", err)
+	}
+	if _, err := contracts.Get(asOwner, tenantID, c.ID); err != nil {
+		t.Errorf("contract for the owner: %v", err)
+	}
+	filter := contract.ListFilter{TenantID: tenantID, Today: time.Now(), Limit: 100}
+	if _, total, err := contracts.List(asOther, filter); err != nil || total != 0 {
+		t.Errorf("other user lists %d contracts (%v), want none", total, err)
+	}
+	if _, total, err := contracts.List(asOwner, filter); err != nil || total != 1 {
+		t.Errorf("owner lists %d contracts (%v), want 1", total, err)
+	}
+	if _, err := contracts.Cancel(asOther, tenantID, c.ID, &otherID, ""); !errors.Is(err, contract.ErrContractNotFound) {
+		t.Errorf("cancel by another user: got %v, want ErrContractNotFound", err)
+	}
+
+	// Bescheid comparison
+	if _, err := env.DB.Exec(ctx, `
+		INSERT INTO document_relations (tenant_id, document_id, target_type, target_id, relation_type)
+		VALUES ($1, $2, 'uva_submission', $3, 'assessment_of')`, tenantID, documentID, uuid.New()); err != nil {
+		t.Fatalf("link Bescheid: %v", err)
+	}
+	assessments := assessment.NewRepository(env.DB)
+	if _, err := assessments.Bescheid(asOther, tenantID, documentID); !errors.Is(err, assessment.ErrNoSubmission) {
+		t.Errorf("Bescheid for another user: got %v, want ErrNoSubmission", err)
+	}
+	if _, err := assessments.Bescheid(asOwner, tenantID, documentID); err != nil {
+		t.Errorf("Bescheid for the owner: %v", err)
+	}
+
+	// Payment deadlines on the Pflichten-Radar
+	var deadlineID uuid.UUID
+	if err := env.DB.QueryRow(ctx, `
+		UPDATE extracted_deadlines SET deadline_type = 'payment', status = 'active'
+		WHERE id = (SELECT id FROM extracted_deadlines WHERE document_id = $1 LIMIT 1)
+		RETURNING id`, documentID).Scan(&deadlineID); err != nil {
+		t.Fatalf("make payment deadline: %v", err)
+	}
+	radar := pflichten.NewRepository(env.DB)
+	onRadar := func(c context.Context) bool {
+		zahlungen, err := radar.ListZahlungsfristen(c, tenantID, nil, time.Now().AddDate(10, 0, 0))
+		if err != nil {
+			t.Fatalf("list payment deadlines: %v", err)
+		}
+		for _, p := range zahlungen {
+			if p.QuelleID != nil && *p.QuelleID == deadlineID {
+				return true
+			}
+		}
+		return false
+	}
+	if onRadar(asOther) || !onRadar(asOwner) {
+		t.Error("the payment deadline of the restricted document should only be on the owner's radar")
+	}
+
+	// Activity feed
+	feeds := activity.NewService(activity.NewRepository(env.DB))
+	feedFilter := activity.FeedFilter{TenantID: tenantID, EntityType: activity.EntityDocument, EntityID: documentID}
+	if _, err := feeds.Feed(asOther, feedFilter); !errors.Is(err, activity.ErrEntityNotFound) {
+		t.Errorf("feed for another user: got %v, want ErrEntityNotFound", err)
+	}
+	if _, err := feeds.Feed(asOwner, feedFilter); err != nil {
+		t.Errorf("feed for the owner: %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"austrian-business-infrastructure/internal/analysis"
+	"austrian-business-infrastructure/pkg/money"
 	"github.com/google/uuid"
 )
 
@@ -87,7 +88,7 @@ func TestAnalysisRepository(t *testing.T) {
 			DocumentID:  uuid.New(),
 			TenantID:    uuid.New(),
 			AmountType:  "nachzahlung",
-			Amount:      1234.56,
+			Amount:      money.EUR(123456),
 			Currency:    "EUR",
 			Description: "Einkommensteuer Nachzahlung",
 			Confidence:  0.88,
@@ -95,7 +96,7 @@ func TestAnalysisRepository(t *testing.T) {
 		}
 
 		// Verify amount is positive
-		if amount.Amount <= 0 {
+		if amount.Amount.Cents <= 0 {
 			t.Error("Amount should be positive")
 		}
 
@@ -0,0 +1,103 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/buak"
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/eldameldung"
+	"austrian-business-infrastructure/tests/integration/platform"
+)
+
+// TestBUAKRoutesStayInTenant checks that the BUAK routes only serve the
+// workers and Zuschlagsmeldungen of the tenant's own ELDA accounts.
+func TestBUAKRoutesStayInTenant(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	env := platform.Setup(t)
+	defer env.Cleanup()
+	ctx := context.Background()
+
+	demoSvc, err := demo.NewService(env.DB, []byte("buak-tenant-test-encryption-key!"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed := func(name string, seed int64) (tenantID, eldaAccountID uuid.UUID) {
+		seeded, err := demoSvc.Seed(ctx, demo.Options{Name: name, Seed: seed, Employees: 2, Documents: 1, Invoices: 1}, "test", nil)
+		if err != nil {
+			t.Fatalf("seed %s: %v", name, err)
+		}
+		t.Cleanup(func() {
+			if err := demoSvc.Teardown(context.Background(), seeded.TenantID, nil); err != nil {
+				t.Errorf("teardown: %v", err)
+			}
+		})
+		if err := env.DB.QueryRow(ctx, `
+			SELECT ea.id FROM elda_accounts ea JOIN accounts a ON a.id = ea.account_id
+			WHERE a.tenant_id = $1`, seeded.TenantID).Scan(&eldaAccountID); err != nil {
+			t.Fatalf("find ELDA account: %v", err)
+		}
+		return seeded.TenantID, eldaAccountID
+	}
+	tenantA, accountA := seed("BUAK A Bau GmbH", 75)
+	_, accountB := seed("BUAK B Bau GmbH", 76)
+
+	repo := buak.NewRepository(env.DB)
+	router := chi.NewRouter()
+	router.Route("/api/v1", buak.NewHandler(buak.NewService(buak.ServiceConfig{
+		Repository:        repo,
+		MeldungRepository: eldameldung.NewRepository(env.DB),
+	})).RegisterRoutes)
+
+	do := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), api.TenantIDKey, tenantA.String()))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := do("GET", "/api/v1/buak/arbeitnehmer?elda_account_id="+accountA.String(), ""); code != http.StatusOK {
+		t.Errorf("own workers: got %d, want 200", code)
+	}
+	for _, path := range []string{
+		"/api/v1/buak/arbeitnehmer?elda_account_id=" + accountB.String(),
+		"/api/v1/buak/meldungen?elda_account_id=" + accountB.String(),
+	} {
+		if code := do("GET", path, ""); code != http.StatusNotFound {
+			t.Errorf("GET %s: got %d, want 404", path, code)
+		}
+	}
+	if code := do("POST", "/api/v1/buak/meldungen", `{"elda_account_id": "`+accountB.String()+`", "year": 2025, "month": 3}`); code != http.StatusNotFound {
+		t.Errorf("Zuschlagsmeldung for a foreign account: got %d, want 404", code)
+	}
+
+	// A worker of the other tenant cannot be read, changed or deleted
+	result, err := buak.NewService(buak.ServiceConfig{Repository: repo, MeldungRepository: eldameldung.NewRepository(env.DB)}).
+		SyncFromELDA(ctx, accountB)
+	if err != nil {
+		t.Fatalf("sync workers of tenant B: %v", err)
+	}
+	if result.Created == 0 {
+		t.Skip("demo tenant has no BUAK workers")
+	}
+	var workerID uuid.UUID
+	if err := env.DB.QueryRow(ctx, `SELECT id FROM buak_arbeitnehmer WHERE elda_account_id = $1 LIMIT 1`, accountB).Scan(&workerID); err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []string{"GET", "PUT", "DELETE"} {
+		if code := do(method, "/api/v1/buak/arbeitnehmer/"+workerID.String(), `{"stundenlohn": 1}`); code != http.StatusNotFound {
+			t.Errorf("%s foreign worker: got %d, want 404", method, code)
+		}
+	}
+}
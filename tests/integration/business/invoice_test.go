@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"austrian-business-infrastructure/internal/invoice"
+	"austrian-business-infrastructure/pkg/money"
 	"github.com/google/uuid"
 )
 
@@ -227,10 +228,10 @@ func TestInvoiceResponse(t *testing.T) {
 			SellerVAT:          &sellerVAT,
 			BuyerName:          "Buyer AG",
 			BuyerVAT:           &buyerVAT,
-			TaxExclusiveAmount: 10000000,
-			TaxAmount:          2000000,
-			TaxInclusiveAmount: 12000000,
-			PayableAmount:      12000000,
+			TaxExclusiveAmount: money.EUR(10000000),
+			TaxAmount:          money.EUR(2000000),
+			TaxInclusiveAmount: money.EUR(12000000),
+			PayableAmount:      money.EUR(12000000),
 			ValidationStatus:   "passed",
 			Status:             "validated",
 			CreatedAt:          "2025-01-15T10:00:00Z",
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"austrian-business-infrastructure/internal/payment"
+	"austrian-business-infrastructure/pkg/money"
 	"github.com/google/uuid"
 )
 
@@ -216,7 +217,7 @@ func TestPaymentBatchResponse(t *testing.T) {
 			Status:      "generated",
 			DebtorName:  "Company GmbH",
 			DebtorIBAN:  "AT611904300234573201",
-			TotalAmount: 100000000.00,
+			TotalAmount: money.EUR(10000000000),
 			ItemCount:   50,
 			HasXML:      true,
 			GeneratedAt: &generatedAt,
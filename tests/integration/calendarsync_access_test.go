@@ -0,0 +1,77 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/calendarsync"
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/tests/integration/platform"
+
+	"github.com/google/uuid"
+)
+
+// TestCalendarSyncRespectsDocumentAccess checks that the deadlines pushed to
+// a user's calendar leave out those of documents the user may not see.
+func TestCalendarSyncRespectsDocumentAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	env := platform.Setup(t)
+	defer env.Cleanup()
+	ctx := context.Background()
+
+	demoSvc, err := demo.NewService(env.DB, []byte("calendar-access-encryption-key32"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeded, err := demoSvc.Seed(ctx, demo.Options{Name: "Kalender GmbH", Seed: 77, Employees: 1, Documents: 4, Invoices: 1}, "test", nil)
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := demoSvc.Teardown(context.Background(), seeded.TenantID, nil); err != nil {
+			t.Errorf("teardown: %v", err)
+		}
+	})
+	tenantID := seeded.TenantID
+	var ownerID, documentID uuid.UUID
+	if err := env.DB.QueryRow(ctx, `SELECT id FROM users WHERE email = $1`, seeded.OwnerEmail).Scan(&ownerID); err != nil {
+		t.Fatalf("find owner: %v", err)
+	}
+	if err := env.DB.QueryRow(ctx, `
+		UPDATE extracted_deadlines SET status = 'active'
+		WHERE id = (SELECT id FROM extracted_deadlines WHERE tenant_id = $1 LIMIT 1)
+		RETURNING document_id`, tenantID).Scan(&documentID); err != nil {
+		t.Fatalf("find document with deadline: %v", err)
+	}
+	if err := document.NewRepository(env.DB).SetAccess(ctx, tenantID,
+		&document.Access{DocumentID: &documentID, UserIDs: []uuid.UUID{ownerID}}, &ownerID); err != nil {
+		t.Fatalf("restrict document: %v", err)
+	}
+
+	repo := calendarsync.NewRepository(env.DB)
+	from := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC)
+	synced := func(userID uuid.UUID) bool {
+		deadlines, err := repo.ListDeadlines(ctx, tenantID, userID, from, until)
+		if err != nil {
+			t.Fatalf("list deadlines: %v", err)
+		}
+		for _, d := range deadlines {
+			if d.DocumentID == documentID {
+				return true
+			}
+		}
+		return false
+	}
+	if !synced(ownerID) {
+		t.Error("the deadline should be synced to the calendar of a listed user")
+	}
+	if synced(uuid.New()) {
+		t.Error("the deadline of a restricted document must not be synced to other users' calendars")
+	}
+}
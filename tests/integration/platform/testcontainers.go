@@ -10,6 +10,8 @@ import (
 	"testing"
 	"time"
 
+	"austrian-business-infrastructure/pkg/database"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 )
@@ -26,7 +28,7 @@ type TestEnvironment struct {
 // Setup creates a test environment with PostgreSQL and Redis
 // For CI/CD, expects POSTGRES_URL and REDIS_URL environment variables
 // For local development, uses testcontainers if available
-func Setup(t *testing.T) *TestEnvironment {
+func Setup(t testing.TB) *TestEnvironment {
 	t.Helper()
 
 	env := &TestEnvironment{}
@@ -43,7 +45,15 @@ func Setup(t *testing.T) *TestEnvironment {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	pool, err := pgxpool.New(ctx, postgresURL)
+	poolConfig, err := pgxpool.ParseConfig(postgresURL)
+	if err != nil {
+		t.Skipf("PostgreSQL not available: %v", err)
+		return nil
+	}
+	// Let tests assert query counts with database.WithQueryCount
+	database.CountQueries(poolConfig.ConnConfig)
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		t.Skipf("PostgreSQL not available: %v", err)
 		return nil
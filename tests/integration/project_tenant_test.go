@@ -0,0 +1,103 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"austrian-business-infrastructure/internal/customfield"
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/invoice"
+	"austrian-business-infrastructure/internal/payment"
+	"austrian-business-infrastructure/internal/project"
+	"austrian-business-infrastructure/tests/integration/platform"
+
+	"github.com/google/uuid"
+)
+
+// TestProjectReferencesStayInTenant checks that invoices and payment batches
+// cannot reference another tenant's project and that profitability only
+// counts the project tenant's own invoices and batches.
+func TestProjectReferencesStayInTenant(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	env := platform.Setup(t)
+	defer env.Cleanup()
+	ctx := context.Background()
+
+	demoSvc, err := demo.NewService(env.DB, []byte("project-tenant-encryption-key-32"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed := func(name string, seed int64) (tenantID, ownerID uuid.UUID) {
+		seeded, err := demoSvc.Seed(ctx, demo.Options{Name: name, Seed: seed, Employees: 1, Documents: 1, Invoices: 1}, "test", nil)
+		if err != nil {
+			t.Fatalf("seed %s: %v", name, err)
+		}
+		t.Cleanup(func() {
+			if err := demoSvc.Teardown(context.Background(), seeded.TenantID, nil); err != nil {
+				t.Errorf("teardown: %v", err)
+			}
+		})
+		if err := env.DB.QueryRow(ctx, `SELECT id FROM users WHERE email = $1`, seeded.OwnerEmail).Scan(&ownerID); err != nil {
+			t.Fatalf("find owner: %v", err)
+		}
+		return seeded.TenantID, ownerID
+	}
+	tenantA, ownerA := seed("Projekt A GmbH", 71)
+	tenantB, ownerB := seed("Projekt B GmbH", 72)
+
+	projects := project.NewService(project.NewRepository(env.DB))
+	p, err := projects.Create(ctx, tenantA, ownerA, &project.CreateProjectInput{Code: "P-1", Name: "Umbau", Kind: project.KindProject})
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	invoices := invoice.NewService(invoice.NewRepository(env.DB))
+	invoices.SetCustomFields(customfield.NewService(customfield.NewRepository(env.DB)))
+	invoiceInput := func(number string) *invoice.CreateInvoiceInput {
+		return &invoice.CreateInvoiceInput{
+			InvoiceNumber: number,
+			IssueDate:     "2026-10-01",
+			SellerName:    "Seller GmbH",
+			BuyerName:     "Buyer AG",
+			ProjectID:     &p.ID,
+			Items: []invoice.ItemInput{
+				{Description: "Leistung", Quantity: 1, UnitCode: "C62", UnitPrice: 10000, TaxCategory: "S", TaxPercent: 20},
+			},
+		}
+	}
+
+	if _, err := invoices.Create(ctx, tenantB, ownerB, invoiceInput("B-1")); !errors.Is(err, invoice.ErrProjectNotFound) {
+		t.Errorf("invoice on a foreign project: got %v, want ErrProjectNotFound", err)
+	}
+	if _, err := invoices.Create(ctx, tenantA, ownerA, invoiceInput("A-1")); err != nil {
+		t.Fatalf("invoice on an own project: %v", err)
+	}
+
+	payments := payment.NewService(payment.NewRepository(env.DB))
+	_, err = payments.CreateBatch(ctx, tenantB, ownerB, &payment.CreateBatchInput{
+		Name: "Lieferanten", Type: payment.TypeCreditTransfer,
+		DebtorName: "Projekt B GmbH", DebtorIBAN: "AT611904300234573201",
+		ProjectID: &p.ID,
+		Items:     []payment.ItemInput{{Amount: 5000, CreditorName: "Lieferant", CreditorIBAN: "AT483200000012345864"}},
+	})
+	if !errors.Is(err, payment.ErrProjectNotFound) {
+		t.Errorf("batch on a foreign project: got %v, want ErrProjectNotFound", err)
+	}
+
+	// A foreign invoice that references the project anyway, as stored
+	// before the check, does not count
+	if _, err := env.DB.Exec(ctx, `UPDATE invoices SET project_id = $1 WHERE tenant_id = $2`, p.ID, tenantB); err != nil {
+		t.Fatal(err)
+	}
+	reports, err := projects.Profitability(ctx, tenantA, &p.ID, nil, nil)
+	if err != nil {
+		t.Fatalf("profitability: %v", err)
+	}
+	if len(reports) != 1 || reports[0].InvoicedNet != 10000 {
+		t.Errorf("profitability = %+v, want 10000 invoiced from tenant A only", reports)
+	}
+}
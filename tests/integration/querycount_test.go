@@ -0,0 +1,164 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/internal/signature"
+	"austrian-business-infrastructure/pkg/database"
+	"austrian-business-infrastructure/tests/integration/platform"
+
+	"github.com/google/uuid"
+)
+
+// Query-count tests for the batch loaders. They run against PostgreSQL and
+// assert that loading N entities costs a constant number of round trips.
+
+func TestBatchLoaderQueryCounts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	env := platform.Setup(t)
+	defer env.Cleanup()
+	ctx := context.Background()
+
+	svc, err := demo.NewService(env.DB, []byte("querycount-encryption-key-32byte"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeded, err := svc.Seed(ctx, demo.Options{Name: "Querycount GmbH", Seed: 42, Employees: 5, Documents: 10}, "test", nil)
+	if err != nil {
+		t.Fatalf("seed tenant: %v", err)
+	}
+	defer func() {
+		if err := svc.Teardown(context.Background(), seeded.TenantID, nil); err != nil {
+			t.Errorf("teardown: %v", err)
+		}
+	}()
+
+	var ownerID uuid.UUID
+	if err := env.DB.QueryRow(ctx, `SELECT id FROM users WHERE email = $1`, seeded.OwnerEmail).Scan(&ownerID); err != nil {
+		t.Fatalf("find owner: %v", err)
+	}
+	var documentIDs []uuid.UUID
+	rows, err := env.DB.Query(ctx, `SELECT id FROM documents WHERE tenant_id = $1 ORDER BY id LIMIT 5`, seeded.TenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		documentIDs = append(documentIDs, id)
+	}
+	rows.Close()
+	if len(documentIDs) != 5 {
+		t.Fatalf("expected 5 documents, got %d", len(documentIDs))
+	}
+
+	sigRepo := signature.NewRepository(env.DB)
+	var requests []*signature.SignatureRequest
+	for _, docID := range documentIDs {
+		req := &signature.SignatureRequest{
+			TenantID:     seeded.TenantID,
+			DocumentID:   docID,
+			ExpiresAt:    time.Now().Add(14 * 24 * time.Hour),
+			IsSequential: true,
+			CreatedBy:    ownerID,
+		}
+		if err := sigRepo.CreateRequest(ctx, req); err != nil {
+			t.Fatalf("create request: %v", err)
+		}
+		for i := 0; i < 3; i++ {
+			signer := &signature.Signer{
+				SignatureRequestID: req.ID,
+				Email:              fmt.Sprintf("signer%d@example.com", i),
+				Name:               fmt.Sprintf("Signer %d", i),
+				OrderIndex:         i,
+			}
+			if err := sigRepo.CreateSigner(ctx, signer); err != nil {
+				t.Fatalf("create signer: %v", err)
+			}
+			field := &signature.Field{
+				SignatureRequestID: req.ID,
+				SignerID:           &signer.ID,
+				Page:               1,
+				X:                  50,
+				Y:                  float64(100 + i*60),
+				Width:              200,
+				Height:             50,
+			}
+			if err := sigRepo.CreateField(ctx, field); err != nil {
+				t.Fatalf("create field: %v", err)
+			}
+		}
+		requests = append(requests, &signature.SignatureRequest{ID: req.ID})
+	}
+
+	t.Run("GetRequestWithSigners", func(t *testing.T) {
+		qctx, count := database.WithQueryCount(ctx)
+		req, err := sigRepo.GetRequestWithSigners(qctx, requests[0].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(req.Signers) != 3 || len(req.Fields) != 3 {
+			t.Errorf("expected 3 signers and 3 fields, got %d and %d", len(req.Signers), len(req.Fields))
+		}
+		if count.RoundTrips() != 1 {
+			t.Errorf("expected 1 round trip, got %d", count.RoundTrips())
+		}
+
+		if _, err := sigRepo.GetRequestWithSigners(ctx, uuid.New()); err != signature.ErrRequestNotFound {
+			t.Errorf("expected ErrRequestNotFound, got %v", err)
+		}
+	})
+
+	t.Run("LoadSignersAndFields", func(t *testing.T) {
+		qctx, count := database.WithQueryCount(ctx)
+		if err := sigRepo.LoadSignersAndFields(qctx, requests); err != nil {
+			t.Fatal(err)
+		}
+		for _, req := range requests {
+			if len(req.Signers) != 3 || len(req.Fields) != 3 {
+				t.Errorf("request %s: expected 3 signers and 3 fields, got %d and %d", req.ID, len(req.Signers), len(req.Fields))
+			}
+		}
+		if count.RoundTrips() != 1 {
+			t.Errorf("expected 1 round trip for %d requests, got %d", len(requests), count.RoundTrips())
+		}
+	})
+
+	t.Run("DocumentGetByIDs", func(t *testing.T) {
+		docRepo := document.NewRepository(env.DB)
+		ids := append([]uuid.UUID{uuid.New()}, documentIDs...)
+
+		qctx, count := database.WithQueryCount(ctx)
+		docs, err := docRepo.GetByIDs(qctx, seeded.TenantID, ids)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(docs) != len(documentIDs) {
+			t.Fatalf("expected %d documents, got %d", len(documentIDs), len(docs))
+		}
+		for i, doc := range docs {
+			if doc.ID != documentIDs[i] {
+				t.Errorf("document %d: expected %s, got %s", i, documentIDs[i], doc.ID)
+			}
+		}
+		if count.Queries() != 1 {
+			t.Errorf("expected 1 query, got %d", count.Queries())
+		}
+
+		// Documents of another tenant are not returned
+		other, err := docRepo.GetByIDs(ctx, uuid.New(), documentIDs)
+		if err != nil || len(other) != 0 {
+			t.Errorf("expected no documents for another tenant, got %d (%v)", len(other), err)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"austrian-business-infrastructure/internal/customfield"
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/invoice"
+	"austrian-business-infrastructure/internal/salesdoc"
+	"austrian-business-infrastructure/tests/integration/platform"
+
+	"github.com/google/uuid"
+)
+
+// TestSalesDocumentConversion checks the number ranges of sales documents,
+// which documents can be converted, and that concurrent conversions into an
+// invoice create one invoice.
+func TestSalesDocumentConversion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	env := platform.Setup(t)
+	defer env.Cleanup()
+	ctx := context.Background()
+
+	demoSvc, err := demo.NewService(env.DB, []byte("salesdoc-test-encryption-key-32b"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeded, err := demoSvc.Seed(ctx, demo.Options{Name: "Angebot GmbH", Seed: 73, Employees: 1, Documents: 1}, "test", nil)
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := demoSvc.Teardown(context.Background(), seeded.TenantID, nil); err != nil {
+			t.Errorf("teardown: %v", err)
+		}
+	})
+	tenantID := seeded.TenantID
+	var userID uuid.UUID
+	if err := env.DB.QueryRow(ctx, `SELECT id FROM users WHERE email = $1`, seeded.OwnerEmail).Scan(&userID); err != nil {
+		t.Fatalf("find owner: %v", err)
+	}
+
+	invoices := invoice.NewService(invoice.NewRepository(env.DB))
+	invoices.SetCustomFields(customfield.NewService(customfield.NewRepository(env.DB)))
+	svc := salesdoc.NewService(salesdoc.NewRepository(env.DB), invoices)
+
+	create := func(docType string) *salesdoc.Document {
+		t.Helper()
+		doc, err := svc.Create(ctx, tenantID, userID, &salesdoc.CreateInput{
+			DocumentType: docType,
+			IssueDate:    "2026-10-01",
+			SellerName:   "Angebot GmbH",
+			BuyerName:    "Kunde AG",
+			Items: []salesdoc.ItemInput{
+				{Description: "Beratung", Quantity: 2, UnitCode: "HUR", UnitPrice: 12000, TaxCategory: "S", TaxPercent: 20},
+			},
+		})
+		if err != nil {
+			t.Fatalf("create %s: %v", docType, err)
+		}
+		return doc
+	}
+
+	// Each type has its own number range per year
+	offer := create(salesdoc.TypeOffer)
+	second := create(salesdoc.TypeOffer)
+	note := create(salesdoc.TypeDeliveryNote)
+	if offer.DocumentNumber != "AN-2026-00001" || second.DocumentNumber != "AN-2026-00002" || note.DocumentNumber != "LS-2026-00001" {
+		t.Errorf("numbers = %s, %s, %s", offer.DocumentNumber, second.DocumentNumber, note.DocumentNumber)
+	}
+	if offer.TaxExclusiveAmount != 24000 || offer.TaxInclusiveAmount != 28800 {
+		t.Errorf("totals = %d / %d", offer.TaxExclusiveAmount, offer.TaxInclusiveAmount)
+	}
+
+	// Conversions follow the chain offer → order confirmation → delivery note
+	if _, err := svc.Convert(ctx, note.ID, tenantID, userID, &salesdoc.ConvertInput{TargetType: salesdoc.TypeOffer}); !errors.Is(err, salesdoc.ErrConversionNotAllowed) {
+		t.Errorf("delivery note to offer: got %v, want ErrConversionNotAllowed", err)
+	}
+	confirmation, err := svc.Convert(ctx, offer.ID, tenantID, userID, &salesdoc.ConvertInput{TargetType: salesdoc.TypeOrderConfirmation})
+	if err != nil {
+		t.Fatalf("convert offer: %v", err)
+	}
+	if confirmation.DocumentNumber != "AB-2026-00001" || confirmation.SourceDocumentID == nil || *confirmation.SourceDocumentID != offer.ID {
+		t.Errorf("unexpected confirmation: %+v", confirmation)
+	}
+	if got, _ := svc.Get(ctx, offer.ID, tenantID); got.Status != salesdoc.StatusConverted {
+		t.Errorf("offer status = %s, want converted", got.Status)
+	}
+
+	// A converted document is continued by its follow-up
+	if _, err := svc.Convert(ctx, offer.ID, tenantID, userID, &salesdoc.ConvertInput{TargetType: salesdoc.TypeDeliveryNote}); !errors.Is(err, salesdoc.ErrNotConvertible) {
+		t.Errorf("converting a converted offer again: got %v, want ErrNotConvertible", err)
+	}
+	if _, err := svc.ConvertToInvoice(ctx, offer.ID, tenantID, userID, &salesdoc.ConvertToInvoiceInput{InvoiceNumber: "SD-0"}); !errors.Is(err, salesdoc.ErrNotConvertible) {
+		t.Errorf("invoicing a converted offer: got %v, want ErrNotConvertible", err)
+	}
+
+	// A rejected document cannot be converted
+	if _, err := svc.UpdateStatus(ctx, second.ID, tenantID, salesdoc.StatusSent); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.UpdateStatus(ctx, second.ID, tenantID, salesdoc.StatusRejected); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Convert(ctx, second.ID, tenantID, userID, &salesdoc.ConvertInput{TargetType: salesdoc.TypeOrderConfirmation}); !errors.Is(err, salesdoc.ErrNotConvertible) {
+		t.Errorf("converting a rejected offer: got %v, want ErrNotConvertible", err)
+	}
+
+	// Concurrent conversions into an invoice create one invoice
+	var wg sync.WaitGroup
+	results := make([]error, 4)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, results[i] = svc.ConvertToInvoice(ctx, confirmation.ID, tenantID, userID, &salesdoc.ConvertToInvoiceInput{InvoiceNumber: fmt.Sprintf("SD-%d", i+1)})
+		}(i)
+	}
+	wg.Wait()
+	succeeded := 0
+	for i, err := range results {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, salesdoc.ErrAlreadyInvoiced):
+			t.Errorf("conversion %d: %v", i, err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d conversions succeeded, want 1", succeeded)
+	}
+	var count int
+	if err := env.DB.QueryRow(ctx, `SELECT COUNT(*) FROM invoices WHERE tenant_id = $1 AND order_reference = $2`, tenantID, confirmation.DocumentNumber).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("%d invoices for %s, want 1", count, confirmation.DocumentNumber)
+	}
+	if got, _ := svc.Get(ctx, confirmation.ID, tenantID); got.Status != salesdoc.StatusInvoiced || got.InvoiceID == nil {
+		t.Errorf("confirmation not invoiced: %+v", got)
+	}
+}
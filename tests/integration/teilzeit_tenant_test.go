@@ -0,0 +1,114 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/eldameldung"
+	"austrian-business-infrastructure/internal/teilzeit"
+	"austrian-business-infrastructure/tests/integration/platform"
+)
+
+// TestTeilzeitRoutesStayInTenant checks that part-time scenarios of another
+// tenant's ELDA accounts are neither calculated, listed, read, drafted nor
+// deleted.
+func TestTeilzeitRoutesStayInTenant(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	env := platform.Setup(t)
+	defer env.Cleanup()
+	ctx := context.Background()
+
+	demoSvc, err := demo.NewService(env.DB, []byte("teilzeit-tenant-test-key-32byte!"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed := func(name string, seed int64) (tenantID, eldaAccountID uuid.UUID) {
+		seeded, err := demoSvc.Seed(ctx, demo.Options{Name: name, Seed: seed, Employees: 1, Documents: 1, Invoices: 1}, "test", nil)
+		if err != nil {
+			t.Fatalf("seed %s: %v", name, err)
+		}
+		t.Cleanup(func() {
+			if err := demoSvc.Teardown(context.Background(), seeded.TenantID, nil); err != nil {
+				t.Errorf("teardown: %v", err)
+			}
+		})
+		if err := env.DB.QueryRow(ctx, `
+			SELECT ea.id FROM elda_accounts ea JOIN accounts a ON a.id = ea.account_id
+			WHERE a.tenant_id = $1`, seeded.TenantID).Scan(&eldaAccountID); err != nil {
+			t.Fatalf("find ELDA account: %v", err)
+		}
+		return seeded.TenantID, eldaAccountID
+	}
+	tenantA, accountA := seed("Teilzeit A GmbH", 80)
+	_, accountB := seed("Teilzeit B GmbH", 81)
+
+	// A scenario of tenant B
+	repo := teilzeit.NewRepository(env.DB)
+	sz := &teilzeit.Szenario{
+		ELDAAccountID: accountB,
+		SVNummer:      "1237010180",
+		Vorname:       "Maria",
+		Nachname:      "Huber",
+		Eingabe: teilzeit.Eingabe{
+			Modell:        teilzeit.ModellAltersteilzeit,
+			Variante:      teilzeit.VarianteKontinuierlich,
+			Von:           time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC),
+			Bis:           time.Date(2028, time.June, 30, 0, 0, 0, 0, time.UTC),
+			BisherStunden: 40,
+			NeueStunden:   20,
+			BisherBrutto:  400000,
+		},
+	}
+	if err := repo.Create(ctx, sz); err != nil {
+		t.Fatalf("create scenario: %v", err)
+	}
+
+	router := chi.NewRouter()
+	router.Route("/api/v1", teilzeit.NewHandler(teilzeit.NewService(teilzeit.ServiceConfig{
+		Repository:        repo,
+		MeldungRepository: eldameldung.NewRepository(env.DB),
+	})).RegisterRoutes)
+
+	do := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), api.TenantIDKey, tenantA.String()))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := do("GET", "/api/v1/teilzeit/szenarien?elda_account_id="+accountA.String(), ""); code != http.StatusOK {
+		t.Errorf("own scenarios: got %d, want 200", code)
+	}
+	berechnung := `{"elda_account_id": "` + accountB.String() + `", "sv_nummer": "1237010180", "modell": "altersteilzeit",
+		"variante": "kontinuierlich", "von": "2025-07-01", "bis": "2028-06-30", "neue_stunden": 20}`
+	for _, c := range []struct{ method, path, body string }{
+		{"POST", "/api/v1/teilzeit/berechnen", berechnung},
+		{"POST", "/api/v1/teilzeit/szenarien", berechnung},
+		{"GET", "/api/v1/teilzeit/szenarien?elda_account_id=" + accountB.String(), ""},
+		{"GET", "/api/v1/teilzeit/szenarien/" + sz.ID.String(), ""},
+		{"POST", "/api/v1/teilzeit/szenarien/" + sz.ID.String() + "/aenderungen", ""},
+		{"DELETE", "/api/v1/teilzeit/szenarien/" + sz.ID.String(), ""},
+	} {
+		if code := do(c.method, c.path, c.body); code != http.StatusNotFound {
+			t.Errorf("%s %s: got %d, want 404", c.method, c.path, code)
+		}
+	}
+
+	var left bool
+	if err := env.DB.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM teilzeit_szenarien WHERE id = $1)`, sz.ID).Scan(&left); err != nil || !left {
+		t.Errorf("the foreign scenario should be left alone: exists=%v, %v", left, err)
+	}
+}
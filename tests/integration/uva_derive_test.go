@@ -0,0 +1,83 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"austrian-business-infrastructure/internal/account"
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/uva"
+	"austrian-business-infrastructure/tests/integration/platform"
+
+	"github.com/google/uuid"
+)
+
+// TestUVADeriveNeedsSingleCompany checks that invoices, which are kept per
+// tenant, are only derived into the Kennzahlen of a tenant with a single
+// FinanzOnline account.
+func TestUVADeriveNeedsSingleCompany(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	env := platform.Setup(t)
+	defer env.Cleanup()
+	ctx := context.Background()
+
+	key := []byte("uva-derive-test-encryption-key32")
+	demoSvc, err := demo.NewService(env.DB, key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeded, err := demoSvc.Seed(ctx, demo.Options{Name: "Ableitung GmbH", Seed: 74, Employees: 1, Documents: 1, Invoices: 3}, "test", nil)
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := demoSvc.Teardown(context.Background(), seeded.TenantID, nil); err != nil {
+			t.Errorf("teardown: %v", err)
+		}
+	})
+	tenantID := seeded.TenantID
+
+	var accountID uuid.UUID
+	if err := env.DB.QueryRow(ctx, `SELECT id FROM accounts WHERE tenant_id = $1 AND type = $2`,
+		tenantID, account.AccountTypeFinanzOnline).Scan(&accountID); err != nil {
+		t.Fatalf("find FinanzOnline account: %v", err)
+	}
+
+	accounts, err := account.NewService(account.NewRepository(env.DB), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := uva.NewService(uva.NewRepository(env.DB), accounts)
+	month := 3
+	input := &uva.DeriveInput{AccountID: accountID, PeriodYear: 2026, PeriodType: uva.PeriodTypeMonthly, PeriodMonth: &month}
+
+	if _, err := svc.Derive(ctx, tenantID, input); err != nil {
+		t.Fatalf("derive with one company: %v", err)
+	}
+
+	var secondID uuid.UUID
+	if err := env.DB.QueryRow(ctx, `
+		INSERT INTO accounts (tenant_id, name, type, credentials, credentials_iv, status, sync_interval, auto_sync_enabled)
+		SELECT tenant_id, 'FinanzOnline Zweite GmbH', type, credentials, credentials_iv, status, sync_interval, auto_sync_enabled
+		FROM accounts WHERE id = $1
+		RETURNING id`, accountID).Scan(&secondID); err != nil {
+		t.Fatalf("add second company: %v", err)
+	}
+	if _, err := svc.Derive(ctx, tenantID, input); !errors.Is(err, uva.ErrSeveralCompanies) {
+		t.Errorf("derive with two companies: got %v, want ErrSeveralCompanies", err)
+	}
+	if _, _, err := svc.Compute(ctx, tenantID, accountID, "2026-03"); !errors.Is(err, uva.ErrSeveralCompanies) {
+		t.Errorf("compute with two companies: got %v, want ErrSeveralCompanies", err)
+	}
+
+	if _, err := env.DB.Exec(ctx, `UPDATE accounts SET deleted_at = NOW() WHERE id = $1`, secondID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Derive(ctx, tenantID, input); err != nil {
+		t.Errorf("derive after deleting the second company: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/webhook"
+	"austrian-business-infrastructure/tests/integration/platform"
+)
+
+// TestWebhookSecretFollowsCurrentKey checks that webhooks.secret, which the
+// previous release signs with, keeps the secret of the current signing key.
+func TestWebhookSecretFollowsCurrentKey(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	env := platform.Setup(t)
+	defer env.Cleanup()
+	ctx := context.Background()
+
+	demoSvc, err := demo.NewService(env.DB, []byte("webhook-keys-test-encryption-k32"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeded, err := demoSvc.Seed(ctx, demo.Options{Name: "Signatur GmbH", Seed: 82, Employees: 1, Documents: 1, Invoices: 1}, "test", nil)
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := demoSvc.Teardown(context.Background(), seeded.TenantID, nil); err != nil {
+			t.Errorf("teardown: %v", err)
+		}
+	})
+
+	repo := webhook.NewRepository(env.DB)
+	wh := &webhook.Webhook{TenantID: seeded.TenantID, Name: "ERP", URL: "https://erp.example.at/hooks",
+		Secret: "first-secret", Events: []string{"document.created"}, Enabled: true}
+	if err := repo.Create(ctx, wh); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	secret := func() string {
+		var s string
+		if err := env.DB.QueryRow(ctx, `SELECT secret FROM webhooks WHERE id = $1`, wh.ID).Scan(&s); err != nil {
+			t.Fatalf("read webhook secret: %v", err)
+		}
+		return s
+	}
+	if got := secret(); got != "first-secret" {
+		t.Errorf("secret after create = %q, want the first key", got)
+	}
+
+	now := time.Now()
+	key := &webhook.SigningKey{WebhookID: wh.ID, Secret: "second-secret", ValidFrom: now}
+	if _, err := repo.RotateKey(ctx, key, now.Add(time.Hour)); err != nil {
+		t.Fatalf("rotate key: %v", err)
+	}
+	if got := secret(); got != "second-secret" {
+		t.Errorf("secret after rotation = %q, want the current key", got)
+	}
+}
@@ -0,0 +1,164 @@
+package load
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/account"
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/internal/invoice"
+	"austrian-business-infrastructure/internal/job"
+	"austrian-business-infrastructure/internal/user"
+	"austrian-business-infrastructure/tests/integration/platform"
+
+	"github.com/google/uuid"
+)
+
+// Benchmarks of the hot paths behind the load-test profiles, run against
+// PostgreSQL (TEST_POSTGRES_URL, TEST_REDIS_URL). They are skipped when no
+// database is available:
+//
+//	go test -run '^$' -bench . -benchmem ./tests/load/
+//
+// Compare runs with benchstat before and after a change to a repository query.
+
+const benchEncryptionKey = "bench-encryption-key-32-bytes!!!"
+
+// benchTenant is a seeded synthetic tenant
+type benchTenant struct {
+	env        *platform.TestEnvironment
+	seeded     *demo.Result
+	accountID  uuid.UUID
+	documentID uuid.UUID
+}
+
+// setupTenant seeds a synthetic tenant with list volumes similar to the
+// server's performance smoke mode and removes it when the benchmark ends
+func setupTenant(b *testing.B) *benchTenant {
+	b.Helper()
+	env := platform.Setup(b)
+	b.Cleanup(env.Cleanup)
+	ctx := context.Background()
+
+	svc, err := demo.NewService(env.DB, []byte(benchEncryptionKey), nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	seeded, err := svc.Seed(ctx, demo.Options{
+		Name:      "Bench GmbH",
+		Seed:      time.Now().UnixNano() % 1e9,
+		Employees: 20,
+		Documents: 300,
+		Invoices:  300,
+	}, "bench", nil)
+	if err != nil {
+		b.Fatalf("seed tenant: %v", err)
+	}
+	b.Cleanup(func() {
+		if err := svc.Teardown(context.Background(), seeded.TenantID, nil); err != nil {
+			b.Errorf("teardown: %v", err)
+		}
+	})
+
+	t := &benchTenant{env: env, seeded: seeded}
+	err = env.DB.QueryRow(ctx, `
+		SELECT account_id, id FROM documents WHERE tenant_id = $1 ORDER BY received_at DESC LIMIT 1
+	`, seeded.TenantID).Scan(&t.accountID, &t.documentID)
+	if err != nil {
+		b.Fatalf("find document: %v", err)
+	}
+	return t
+}
+
+func BenchmarkLogin(b *testing.B) {
+	t := setupTenant(b)
+	svc := user.NewService(user.NewRepository(t.env.DB))
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := svc.Authenticate(ctx, t.seeded.OwnerEmail, t.seeded.OwnerPassword); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDocumentList(b *testing.B) {
+	t := setupTenant(b)
+	svc := document.NewService(document.NewRepository(t.env.DB), nil)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		docs, _, err := svc.List(ctx, &document.DocumentFilter{TenantID: t.seeded.TenantID, Limit: 50})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(docs) == 0 {
+			b.Fatal("no documents listed")
+		}
+	}
+}
+
+func BenchmarkInvoiceList(b *testing.B) {
+	t := setupTenant(b)
+	svc := invoice.NewService(invoice.NewRepository(t.env.DB))
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := svc.List(ctx, invoice.ListFilter{TenantID: t.seeded.TenantID, Limit: 50}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDocumentUpload(b *testing.B) {
+	t := setupTenant(b)
+	storage, err := document.NewLocalStorage(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+	svc := document.NewServiceWithAccountVerifier(document.NewRepository(t.env.DB), storage, account.NewRepository(t.env.DB))
+	ctx := context.Background()
+	body := strings.Repeat("BT /F1 10 Tf (Bescheid ueber die Festsetzung) Tj ET\n", 1200)
+
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		content := fmt.Sprintf("%%PDF-1.4\n%% bench %d %d\n%s%%%%EOF\n", i, time.Now().UnixNano(), body)
+		_, err := svc.Create(ctx, t.seeded.TenantID.String(), &document.CreateDocumentInput{
+			AccountID:   t.accountID,
+			ExternalID:  fmt.Sprintf("bench-%d-%d", b.N, i),
+			Type:        "bescheid",
+			Title:       "Benchmark document",
+			ReceivedAt:  time.Now(),
+			Content:     bytes.NewReader([]byte(content)),
+			ContentType: "application/pdf",
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAnalysisEnqueue(b *testing.B) {
+	t := setupTenant(b)
+	queue := job.NewQueue(t.env.DB, &job.QueueConfig{WorkerID: "bench"})
+	ctx := context.Background()
+	payload := map[string]interface{}{"document_id": t.documentID, "tenant_id": t.seeded.TenantID}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		opts := job.DefaultEnqueueOptions()
+		opts.RunAt = time.Now().Add(24 * time.Hour) // never picked up by a worker
+		if _, err := queue.Enqueue(ctx, t.seeded.TenantID, job.TypeDocumentAnalysis, payload, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
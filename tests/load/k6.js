@@ -0,0 +1,88 @@
+// k6 load profile for the HTTP hot paths. Latency thresholds come from the
+// same budgets the server's performance smoke mode asserts
+// (internal/perf/budgets.json); budgets without a path are service
+// operations measured by the smoke mode and the Go benchmarks only.
+//
+//   k6 run -e BASE_URL=https://staging.example.com \
+//          -e EMAIL=owner@example.com -e PASSWORD=... tests/load/k6.js
+//
+// Optional: RATE (list requests per second, default 20), DURATION (default 2m).
+// Run against a demo tenant (fo demo seed), never against production data.
+
+import http from 'k6/http';
+import { check, fail } from 'k6';
+
+const budgets = JSON.parse(open('../../internal/perf/budgets.json')).budgets
+  .filter((b) => b.path);
+
+const BASE_URL = (__ENV.BASE_URL || 'http://localhost:8080').replace(/\/$/, '');
+const RATE = parseInt(__ENV.RATE || '20', 10);
+const DURATION = __ENV.DURATION || '2m';
+
+const thresholds = { http_req_failed: ['rate<0.01'] };
+for (const b of budgets) {
+  const limits = [`p(95)<${b.p95_ms}`];
+  if (b.p99_ms) {
+    limits.push(`p(99)<${b.p99_ms}`);
+  }
+  thresholds[`http_req_duration{name:${b.name}}`] = limits;
+}
+
+export const options = {
+  thresholds,
+  scenarios: {
+    lists: {
+      executor: 'constant-arrival-rate',
+      exec: 'lists',
+      rate: RATE,
+      timeUnit: '1s',
+      duration: DURATION,
+      preAllocatedVUs: 10,
+      maxVUs: 50,
+    },
+    // Logins are rate limited per client IP, keep them well below the limit
+    login: {
+      executor: 'constant-arrival-rate',
+      exec: 'login',
+      rate: 6,
+      timeUnit: '1m',
+      duration: DURATION,
+      preAllocatedVUs: 1,
+    },
+  },
+};
+
+function credentials() {
+  if (!__ENV.EMAIL || !__ENV.PASSWORD) {
+    fail('EMAIL and PASSWORD must be set');
+  }
+  return JSON.stringify({ email: __ENV.EMAIL, password: __ENV.PASSWORD });
+}
+
+export function setup() {
+  const res = http.post(`${BASE_URL}/api/v1/auth/login`, credentials(), {
+    headers: { 'Content-Type': 'application/json' },
+  });
+  if (res.status !== 200 || !res.json('access_token')) {
+    fail(`login failed with status ${res.status}`);
+  }
+  return { token: res.json('access_token') };
+}
+
+export function lists(data) {
+  const gets = budgets.filter((b) => b.method === 'GET');
+  const b = gets[Math.floor(Math.random() * gets.length)];
+  const res = http.get(`${BASE_URL}${b.path}`, {
+    headers: { Authorization: `Bearer ${data.token}` },
+    tags: { name: b.name },
+  });
+  check(res, { [`${b.name} 200`]: (r) => r.status === 200 });
+}
+
+export function login() {
+  const res = http.post(`${BASE_URL}/api/v1/auth/login`, credentials(), {
+    headers: { 'Content-Type': 'application/json' },
+    tags: { name: 'login' },
+  });
+  check(res, { 'login 200': (r) => r.status === 200 });
+}
@@ -0,0 +1,45 @@
+#!/usr/bin/env bash
+# Vegeta load profile for the list endpoints. Attacks every GET budget of
+# internal/perf/budgets.json in turn and fails if its p95 exceeds the budget.
+#
+#   BASE_URL=https://staging.example.com EMAIL=owner@example.com PASSWORD=... \
+#     tests/load/vegeta.sh
+#
+# Optional: RATE (requests per second, default 20), DURATION (default 60s).
+# Requires vegeta, curl and jq. Run against a demo tenant (fo demo seed).
+set -euo pipefail
+
+cd "$(dirname "$0")"
+BUDGETS=../../internal/perf/budgets.json
+BASE_URL=${BASE_URL:-http://localhost:8080}
+BASE_URL=${BASE_URL%/}
+RATE=${RATE:-20}
+DURATION=${DURATION:-60s}
+: "${EMAIL:?EMAIL must be set}"
+: "${PASSWORD:?PASSWORD must be set}"
+
+TOKEN=$(curl -sf -X POST "$BASE_URL/api/v1/auth/login" \
+  -H 'Content-Type: application/json' \
+  -d "$(jq -n --arg e "$EMAIL" --arg p "$PASSWORD" '{email: $e, password: $p}')" |
+  jq -r .access_token)
+if [ -z "$TOKEN" ] || [ "$TOKEN" = "null" ]; then
+  echo "login failed" >&2
+  exit 1
+fi
+
+failed=0
+while read -r name path p95_ms; do
+  report=$(printf 'GET %s%s\nAuthorization: Bearer %s\n' "$BASE_URL" "$path" "$TOKEN" |
+    vegeta attack -rate="$RATE" -duration="$DURATION" | vegeta report -type=json)
+  p95=$(jq '.latencies["95th"] / 1000000 | floor' <<<"$report")
+  success=$(jq '.success' <<<"$report")
+
+  status=ok
+  if [ "$p95" -gt "$p95_ms" ] || [ "$(jq -n "$success < 0.99")" = true ]; then
+    status=FAIL
+    failed=1
+  fi
+  printf '%-16s p95=%sms budget=%sms success=%s %s\n' "$name" "$p95" "$p95_ms" "$success" "$status"
+done < <(jq -r '.budgets[] | select(.method == "GET") | "\(.name) \(.path) \(.p95_ms)"' "$BUDGETS")
+
+exit $failed
@@ -0,0 +1,132 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/abwesenheit"
+	"austrian-business-infrastructure/internal/elda"
+)
+
+func abwDate(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestEntgeltfortzahlung(t *testing.T) {
+	eintritt := abwDate(2010, 3, 1)
+
+	tests := []struct {
+		name          string
+		ab            time.Time
+		arbeitsunfall bool
+		want          elda.Entgeltfortzahlung
+	}{
+		{"first year", abwDate(2010, 12, 1), false, elda.Entgeltfortzahlung{VollWochen: 6, HalbWochen: 4}},
+		{"after one year", abwDate(2011, 3, 1), false, elda.Entgeltfortzahlung{VollWochen: 8, HalbWochen: 4}},
+		{"day before 15 years", abwDate(2025, 2, 28), false, elda.Entgeltfortzahlung{VollWochen: 8, HalbWochen: 4}},
+		{"after 15 years", abwDate(2025, 3, 1), false, elda.Entgeltfortzahlung{VollWochen: 10, HalbWochen: 4}},
+		{"after 25 years", abwDate(2035, 3, 1), false, elda.Entgeltfortzahlung{VollWochen: 12, HalbWochen: 4}},
+		{"Arbeitsunfall", abwDate(2012, 1, 1), true, elda.Entgeltfortzahlung{VollWochen: 8}},
+		{"Arbeitsunfall after 15 years", abwDate(2026, 1, 1), true, elda.Entgeltfortzahlung{VollWochen: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := elda.CalculateEntgeltfortzahlung(eintritt, tt.ab, tt.arbeitsunfall); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBeschaeftigungszeitraeume(t *testing.T) {
+	sv := "1234150189"
+	ein1, aus1, ein2 := abwDate(2020, 1, 1), abwDate(2022, 6, 30), abwDate(2024, 2, 1)
+
+	meldungen := []*elda.ELDAMeldung{
+		{SVNummer: sv, Type: elda.MeldungTypeAnmeldung, Status: elda.MeldungStatusAccepted, Eintrittsdatum: &ein2},
+		{SVNummer: sv, Type: elda.MeldungTypeAbmeldung, Status: elda.MeldungStatusAccepted, Austrittsdatum: &aus1},
+		{SVNummer: sv, Type: elda.MeldungTypeAnmeldung, Status: elda.MeldungStatusAccepted, Eintrittsdatum: &ein1},
+		{SVNummer: sv, Type: elda.MeldungTypeAbmeldung, Status: elda.MeldungStatusRejected, Austrittsdatum: &ein2},
+		{SVNummer: "9999999999", Type: elda.MeldungTypeAnmeldung, Status: elda.MeldungStatusAccepted, Eintrittsdatum: &ein1},
+	}
+
+	periods := abwesenheit.Beschaeftigungszeitraeume(meldungen, sv)
+	if len(periods) != 2 {
+		t.Fatalf("expected 2 periods, got %d", len(periods))
+	}
+	if !periods[0].Von.Equal(ein1) || periods[0].Bis == nil || !periods[0].Bis.Equal(aus1) {
+		t.Errorf("unexpected first period: %+v", periods[0])
+	}
+	if !periods[1].Von.Equal(ein2) || periods[1].Bis != nil {
+		t.Errorf("unexpected second period: %+v", periods[1])
+	}
+}
+
+func TestValidateAbwesenheit(t *testing.T) {
+	aus := abwDate(2022, 6, 30)
+	periods := []elda.Beschaeftigungszeitraum{
+		{Von: abwDate(2020, 1, 1), Bis: &aus},
+		{Von: abwDate(2024, 2, 1)},
+	}
+
+	bis := abwDate(2025, 3, 14)
+	existing := []*elda.Abwesenheit{
+		{ID: uuid.New(), Art: elda.AbwesenheitUrlaub, Von: abwDate(2025, 3, 10), Bis: &bis},
+	}
+
+	ongoing := &elda.Abwesenheit{ID: uuid.New(), Art: elda.AbwesenheitKrankenstand, Von: abwDate(2025, 4, 1)}
+	if errs := abwesenheit.ValidateAbwesenheit(ongoing, periods, existing); len(errs) != 0 {
+		t.Errorf("expected ongoing Krankenstand to be valid, got %v", errs)
+	}
+
+	overlapping := &elda.Abwesenheit{ID: uuid.New(), Art: elda.AbwesenheitKrankenstand, Von: abwDate(2025, 3, 12)}
+	if errs := abwesenheit.ValidateAbwesenheit(overlapping, periods, existing); len(errs) != 1 || !strings.Contains(errs[0], "überschneidet") {
+		t.Errorf("expected overlap error, got %v", errs)
+	}
+
+	gapBis := abwDate(2023, 1, 10)
+	gap := &elda.Abwesenheit{ID: uuid.New(), Art: elda.AbwesenheitUrlaub, Von: abwDate(2023, 1, 2), Bis: &gapBis}
+	if errs := abwesenheit.ValidateAbwesenheit(gap, periods, nil); len(errs) != 1 || !strings.Contains(errs[0], "außerhalb") {
+		t.Errorf("expected employment period error, got %v", errs)
+	}
+
+	openUrlaub := &elda.Abwesenheit{ID: uuid.New(), Art: elda.AbwesenheitPflegeurlaub, Von: abwDate(2025, 5, 5)}
+	if errs := abwesenheit.ValidateAbwesenheit(openUrlaub, periods, nil); len(errs) != 1 {
+		t.Errorf("expected missing bis error, got %v", errs)
+	}
+}
+
+func TestBuildAUA(t *testing.T) {
+	a := &elda.Abwesenheit{
+		ID: uuid.New(), SVNummer: "1234150189", Vorname: "Anna", Nachname: "Huber",
+		Art: elda.AbwesenheitKrankenstand, Von: abwDate(2025, 3, 10),
+	}
+
+	m := abwesenheit.BuildAUA(a, abwDate(2020, 1, 1))
+	m.Beitragsgrundlage = 350000
+	m.Anforderungsreferenz = "OEGK-4711"
+
+	if !m.LetzterArbeitstag.Equal(abwDate(2025, 3, 9)) {
+		t.Errorf("unexpected letzter Arbeitstag %s", m.LetzterArbeitstag)
+	}
+	if !m.EntgeltVollBis.Equal(abwDate(2025, 5, 4)) { // 8 weeks
+		t.Errorf("unexpected EntgeltVollBis %s", m.EntgeltVollBis)
+	}
+	if m.EntgeltHalbBis == nil || !m.EntgeltHalbBis.Equal(abwDate(2025, 6, 1)) { // + 4 weeks
+		t.Errorf("unexpected EntgeltHalbBis %v", m.EntgeltHalbBis)
+	}
+
+	xmlData, err := elda.MarshalAUADocument(abwesenheit.BuildAUADocument(m, a, "12345678"))
+	if err != nil {
+		t.Fatalf("marshal AUA: %v", err)
+	}
+	for _, want := range []string{"<ArbeitsEntgeltbestaetigung", "<MeldungsArt>AUA</MeldungsArt>", "<Beitragsgrundlage>3500.00</Beitragsgrundlage>", "<HalbBis>2025-06-01</HalbBis>"} {
+		if !strings.Contains(string(xmlData), want) {
+			t.Errorf("expected %q in XML:\n%s", want, xmlData)
+		}
+	}
+}
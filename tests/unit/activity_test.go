@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/activity"
+	"austrian-business-infrastructure/internal/api"
+
+	"github.com/google/uuid"
+)
+
+func TestActivityMergeEntries(t *testing.T) {
+	base := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	entries := []*activity.Entry{
+		{Kind: activity.KindComment, OccurredAt: base.Add(time.Minute), Summary: "comment"},
+		{Kind: activity.KindAudit, OccurredAt: base, Summary: "audit"},
+		{Kind: activity.KindStatusChange, OccurredAt: base.Add(2 * time.Minute), Summary: "status"},
+		{Kind: activity.KindWebhook, OccurredAt: base, Summary: "webhook"},
+	}
+
+	merged := activity.MergeEntries(entries, 3)
+	want := []string{"status", "comment", "audit"}
+	if len(merged) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(merged))
+	}
+	for i, s := range want {
+		if merged[i].Summary != s {
+			t.Errorf("entry %d: expected %s, got %s", i, s, merged[i].Summary)
+		}
+	}
+
+	if empty := activity.MergeEntries(nil, 10); empty == nil || len(empty) != 0 {
+		t.Errorf("expected an empty, non-nil slice, got %v", empty)
+	}
+}
+
+func TestActivityEntityTypes(t *testing.T) {
+	for _, s := range []string{"invoice", "document", "antrag"} {
+		if _, err := activity.ParseEntityType(s); err != nil {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+	for _, s := range []string{"", "invoices", "user"} {
+		if _, err := activity.ParseEntityType(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestActivityHandlerValidation(t *testing.T) {
+	router := api.NewRouter(nil)
+	passthrough := func(next http.Handler) http.Handler { return next }
+	activity.NewHandler(nil).RegisterRoutes(router, passthrough)
+
+	entityID := uuid.New().String()
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{"unknown entity type", http.MethodGet, "/api/v1/activity/user/" + entityID, http.StatusBadRequest},
+		{"invalid entity ID", http.MethodGet, "/api/v1/activity/invoice/not-a-uuid", http.StatusBadRequest},
+		{"unknown kind", http.MethodGet, "/api/v1/activity/invoice/" + entityID + "?kinds=audit,email", http.StatusBadRequest},
+		{"invalid cursor", http.MethodGet, "/api/v1/activity/document/" + entityID + "?before=yesterday", http.StatusBadRequest},
+		{"invalid comment ID", http.MethodDelete, "/api/v1/activity/antrag/" + entityID + "/comments/x", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			ctx := context.WithValue(req.Context(), api.TenantIDKey, uuid.New().String())
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req.WithContext(ctx))
+			if rec.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
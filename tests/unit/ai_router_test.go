@@ -0,0 +1,219 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/ai"
+	"austrian-business-infrastructure/internal/config"
+	"austrian-business-infrastructure/internal/fakes"
+	"austrian-business-infrastructure/pkg/httpclient"
+)
+
+var (
+	haiku  = ai.Target{Provider: ai.ProviderClaude, Model: "claude-3-5-haiku-20241022"}
+	sonnet = ai.Target{Provider: ai.ProviderClaude, Model: "claude-sonnet-4-20250514"}
+	gpt4o  = ai.Target{Provider: ai.ProviderOpenAI, Model: "gpt-4o"}
+)
+
+type routeRecorder struct {
+	mu      sync.Mutex
+	calls   []ai.RouteCall
+	quality []bool
+}
+
+func (r *routeRecorder) RecordCall(ctx context.Context, call *ai.RouteCall) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, *call)
+	return nil
+}
+
+func (r *routeRecorder) RecordQuality(ctx context.Context, op ai.Operation, target ai.Target, valid bool, confidence float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.quality = append(r.quality, valid)
+	return nil
+}
+
+func TestAIParsePolicy(t *testing.T) {
+	p, err := ai.ParsePolicy("classification=claude:claude-3-5-haiku-20241022, claude:claude-sonnet-4-20250514;default=claude:claude-sonnet-4-20250514,openai:gpt-4o")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r := p.Route(ai.OpClassification); len(r) != 2 || r[0] != haiku || r[1] != sonnet {
+		t.Errorf("classification route = %v", r)
+	}
+	if r := p.Route(ai.OpSummary); len(r) != 2 || r[0] != sonnet || r[1] != gpt4o {
+		t.Errorf("summary should take the default route, got %v", r)
+	}
+	if targets := p.Targets(); len(targets) != 3 {
+		t.Errorf("targets = %v, want each once", targets)
+	}
+
+	for _, spec := range []string{
+		"classification=claude:claude-3-5-haiku-20241022",
+		"default=claude",
+		"ocr=claude:x;default=claude:y",
+		"default=claude:x;default=claude:y",
+		"",
+	} {
+		if _, err := ai.ParsePolicy(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+func TestAIPolicyFromConfig(t *testing.T) {
+	cfg := &config.AIConfig{
+		Enabled:         true,
+		ClaudeAPIKey:    "key",
+		ClaudeModel:     sonnet.Model,
+		ClaudeFastModel: haiku.Model,
+		OpenAIModel:     gpt4o.Model,
+	}
+	p, err := ai.PolicyFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r := p.Route(ai.OpClassification); len(r) != 2 || r[0] != haiku || r[1] != sonnet {
+		t.Errorf("classification route = %v", r)
+	}
+	if r := p.Route(ai.OpExtraction); len(r) != 1 || r[0] != sonnet {
+		t.Errorf("extraction route = %v", r)
+	}
+
+	// The OpenAI-compatible provider is the last fallback of every route
+	cfg.OpenAIAPIKey = "key"
+	p, _ = ai.PolicyFromConfig(cfg)
+	if r := p.Route(ai.OpClassification); len(r) != 3 || r[2] != gpt4o {
+		t.Errorf("classification route = %v", r)
+	}
+	if r := p.Route(ai.OpExtraction); len(r) != 2 || r[1] != gpt4o {
+		t.Errorf("extraction route = %v", r)
+	}
+
+	cfg.ClaudeAPIKey = ""
+	p, _ = ai.PolicyFromConfig(cfg)
+	if r := p.Route(ai.OpClassification); len(r) != 1 || r[0] != gpt4o {
+		t.Errorf("OpenAI only: classification route = %v", r)
+	}
+
+	cfg.OpenAIAPIKey = ""
+	if p, err := ai.PolicyFromConfig(cfg); p != nil || err != nil {
+		t.Errorf("no provider: got %v, %v", p, err)
+	}
+	cfg.ClaudeAPIKey, cfg.Enabled = "key", false
+	if p, err := ai.PolicyFromConfig(cfg); p != nil || err != nil {
+		t.Errorf("disabled: got %v, %v", p, err)
+	}
+
+	cfg.Enabled, cfg.Routes = true, "default=mistral:mistral-large-latest"
+	if _, err := ai.NewRouterFromConfig(cfg); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
+
+func newTestRouter(t *testing.T, policy ai.Policy, clients map[ai.Target]ai.Completer) (*ai.Router, *routeRecorder) {
+	t.Helper()
+	router, err := ai.NewRouter(policy, clients, httpclient.BreakerConfig{Threshold: 2, Cooldown: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recorder := &routeRecorder{}
+	router.SetRecorder(recorder)
+	return router, recorder
+}
+
+func TestAIRouterFailover(t *testing.T) {
+	primary, secondary := fakes.NewAI(), fakes.NewAI()
+	primary.Always(fakes.AIComplete, fakes.Fail(&ai.APIError{StatusCode: 529, Type: "overloaded_error"}))
+	secondary.Always(fakes.AIComplete, fakes.Respond(`{"document_type":"bescheid"}`))
+
+	policy := ai.Policy{ai.OpDefault: {sonnet, gpt4o}}
+	router, recorder := newTestRouter(t, policy, map[ai.Target]ai.Completer{sonnet: primary, gpt4o: secondary})
+	ctx := ai.WithOperation(context.Background(), ai.OpClassification)
+
+	resp, err := router.CompleteWithRetry(ctx, "system", "user", 0.1, 2)
+	if err != nil || resp.GetText() != `{"document_type":"bescheid"}` {
+		t.Fatalf("completion = %+v, %v", resp, err)
+	}
+	resp.ReportQuality(true, 0.9)
+
+	if len(recorder.calls) != 2 {
+		t.Fatalf("recorded %d calls, want 2", len(recorder.calls))
+	}
+	if c := recorder.calls[0]; c.Target != sonnet || c.Err == nil || c.Operation != ai.OpClassification {
+		t.Errorf("first call = %+v", c)
+	}
+	if c := recorder.calls[1]; c.Target != gpt4o || c.Err != nil || !c.Fallback || c.CostCents <= 0 {
+		t.Errorf("second call = %+v", c)
+	}
+	if len(recorder.quality) != 1 || !recorder.quality[0] {
+		t.Errorf("quality = %v", recorder.quality)
+	}
+
+	// After the threshold the primary is skipped until the cooldown has passed
+	router.CompleteWithRetry(ctx, "system", "user", 0.1, 2)
+	router.CompleteWithRetry(ctx, "system", "user", 0.1, 2)
+	if n := len(primary.Calls(fakes.AIComplete)); n != 2 {
+		t.Errorf("primary called %d times, want 2", n)
+	}
+	if n := len(secondary.Calls(fakes.AIComplete)); n != 3 {
+		t.Errorf("secondary called %d times, want 3", n)
+	}
+	for _, h := range router.Health() {
+		want := httpclient.StateClosed
+		if h.Target == sonnet {
+			want = httpclient.StateOpen
+		}
+		if h.State != want {
+			t.Errorf("%s: state %s, want %s", h.Target, h.State, want)
+		}
+	}
+}
+
+func TestAIRouterNoFailover(t *testing.T) {
+	primary, secondary := fakes.NewAI(), fakes.NewAI()
+	secondary.Always(fakes.AIComplete, fakes.Respond("ok"))
+	policy := ai.Policy{ai.OpDefault: {sonnet, gpt4o}}
+	router, _ := newTestRouter(t, policy, map[ai.Target]ai.Completer{sonnet: primary, gpt4o: secondary})
+
+	// A malformed request fails the same everywhere
+	primary.Then(fakes.AIComplete, fakes.Fail(&ai.APIError{StatusCode: 400, Type: "invalid_request_error"}))
+	var apiErr *ai.APIError
+	if _, err := router.Complete(context.Background(), "system", "user", 0.1); !errors.As(err, &apiErr) {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+	if n := len(secondary.Calls(fakes.AIComplete)); n != 0 {
+		t.Errorf("secondary called %d times, want 0", n)
+	}
+
+	// Every target failing
+	primary.Always(fakes.AIComplete, fakes.Fail(errors.New("connection refused")))
+	secondary.Always(fakes.AIComplete, fakes.Fail(errors.New("connection refused")))
+	if _, err := router.Complete(context.Background(), "system", "user", 0.1); !errors.Is(err, ai.ErrNoHealthyTarget) {
+		t.Errorf("expected ErrNoHealthyTarget, got %v", err)
+	}
+
+	if _, err := ai.NewRouter(policy, map[ai.Target]ai.Completer{sonnet: primary}, httpclient.BreakerConfig{}); err == nil {
+		t.Error("expected an error for a target without client")
+	}
+}
+
+func TestAIEstimateModelCost(t *testing.T) {
+	cheap := ai.EstimateModelCost(haiku.Model, 1000, 200)
+	strong := ai.EstimateModelCost(sonnet.Model, 1000, 200)
+	if cheap <= 0 || cheap >= strong {
+		t.Errorf("haiku %.4f, sonnet %.4f: want haiku cheaper", cheap, strong)
+	}
+	if unknown := ai.EstimateModelCost("some-model", 1000, 200); unknown != strong {
+		t.Errorf("unknown model %.4f, want priced like sonnet %.4f", unknown, strong)
+	}
+	if mini := ai.EstimateModelCost("gpt-4o-mini", 1000, 200); mini >= ai.EstimateModelCost(gpt4o.Model, 1000, 200) {
+		t.Error("gpt-4o-mini should be cheaper than gpt-4o")
+	}
+}
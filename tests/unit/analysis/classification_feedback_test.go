@@ -0,0 +1,97 @@
+package analysis_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"austrian-business-infrastructure/internal/ai"
+	"austrian-business-infrastructure/internal/analysis"
+)
+
+func TestTaxonomyTypeValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     analysis.TaxonomyType
+		wantErr error
+	}{
+		{"valid", analysis.TaxonomyType{Code: "leasingvertrag", Label: "Leasingvertrag"}, nil},
+		{"umlaut code", analysis.TaxonomyType{Code: "förderzusage", Label: "Förderzusage", BaseType: analysis.DocTypeBescheid}, nil},
+		{"built-in collision", analysis.TaxonomyType{Code: "bescheid", Label: "Bescheid"}, analysis.ErrTaxonomyTypeExists},
+		{"invalid code", analysis.TaxonomyType{Code: "Leasing Vertrag", Label: "Leasingvertrag"}, analysis.ErrInvalidTaxonomyCode},
+		{"missing label", analysis.TaxonomyType{Code: "leasingvertrag"}, analysis.ErrInvalidTaxonomyCode},
+		{"unknown base type", analysis.TaxonomyType{Code: "leasingvertrag", Label: "Leasingvertrag", BaseType: "leasing"}, analysis.ErrUnknownDocumentType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := analysis.ValidateTaxonomyType(&tt.typ)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTaxonomyPromptAndKeywordMatch(t *testing.T) {
+	custom := []analysis.TaxonomyType{
+		{Code: "leasingvertrag", Label: "Leasingvertrag", Keywords: []string{"Leasingnehmer"}},
+		{Code: "foerderzusage", Label: "Förderzusage", Description: "Zusage einer Förderstelle", Keywords: []string{"förderzusage", "fördervertrag"}},
+	}
+
+	if got := analysis.BuildTaxonomyPrompt("base", nil); got != "base" {
+		t.Errorf("prompt without custom types changed: %q", got)
+	}
+	prompt := analysis.BuildTaxonomyPrompt("base", custom)
+	for _, want := range []string{"leasingvertrag: Leasingvertrag", "foerderzusage: Förderzusage – Zusage einer Förderstelle", "förderzusage, fördervertrag"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+
+	if m := analysis.MatchCustomType("Der Leasingnehmer verpflichtet sich ...", "", custom); m == nil || m.Code != "leasingvertrag" {
+		t.Errorf("expected leasingvertrag match, got %+v", m)
+	}
+	if m := analysis.MatchCustomType("", "FÖRDERZUSAGE aws", custom); m == nil || m.Code != "foerderzusage" {
+		t.Errorf("expected foerderzusage match on title, got %+v", m)
+	}
+	if m := analysis.MatchCustomType("Einkommensteuerbescheid 2024", "", custom); m != nil {
+		t.Errorf("expected no match, got %s", m.Code)
+	}
+}
+
+func TestParseClassificationWithCustomTypes(t *testing.T) {
+	response := `{"document_type": "leasingvertrag", "confidence": 0.9, "priority": "low"}`
+
+	if _, err := ai.ParseClassification(response); err == nil {
+		t.Error("expected unknown type to be rejected without tenant taxonomy")
+	}
+
+	parsed, err := ai.ParseClassificationWithTypes(response, []string{"leasingvertrag"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.DocumentType != "leasingvertrag" {
+		t.Errorf("expected leasingvertrag, got %s", parsed.DocumentType)
+	}
+}
+
+func TestClassificationAccuracy(t *testing.T) {
+	tests := []struct {
+		classified, corrected int
+		want                  float64
+	}{
+		{0, 0, 0},
+		{10, 0, 1},
+		{10, 3, 0.7},
+		{4, 6, 0},
+	}
+	for _, tt := range tests {
+		if got := analysis.Accuracy(tt.classified, tt.corrected); got != tt.want {
+			t.Errorf("Accuracy(%d, %d) = %v, want %v", tt.classified, tt.corrected, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package analysis_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"austrian-business-infrastructure/internal/analysis"
+)
+
+func TestConsentRules(t *testing.T) {
+	rules := []analysis.ConsentRule{
+		{DocumentType: " Rechnung ", Purposes: []string{"Summary", analysis.PurposeClassification, analysis.PurposeSummary}},
+		{DocumentType: "lohnzettel", Purposes: []string{}},
+		{DocumentType: "*", Purposes: []string{analysis.PurposeClassification}},
+	}
+	if err := analysis.ValidateConsentRules(rules); err != nil {
+		t.Fatalf("ValidateConsentRules() error = %v", err)
+	}
+	if rules[0].DocumentType != "rechnung" || !slices.Equal(rules[0].Purposes, []string{"summary", "classification"}) {
+		t.Errorf("expected normalized rules, got %+v", rules[0])
+	}
+
+	policy := &analysis.ConsentPolicy{Version: 3, Rules: rules}
+	for docType, want := range map[string][]string{
+		"RECHNUNG":   {analysis.PurposeClassification, analysis.PurposeSummary},
+		"lohnzettel": {},
+		"bescheid":   {analysis.PurposeClassification},
+	} {
+		if got := policy.Allowed(docType); !slices.Equal(got, want) {
+			t.Errorf("Allowed(%s) = %v, want %v", docType, got, want)
+		}
+	}
+
+	opts := analysis.DefaultOptions()
+	denied := policy.Restrict(&opts, "rechnung")
+	if !slices.Equal(denied, []string{analysis.PurposeExtraction, analysis.PurposeSuggestions}) {
+		t.Errorf("Restrict() denied = %v", denied)
+	}
+	if !opts.IncludeClassify || !opts.IncludeSummary || opts.IncludeDeadlines || opts.IncludeAmounts ||
+		opts.IncludeActionItems || opts.IncludeSuggestions || !opts.IncludeOCR || !opts.IncludeFiling {
+		t.Errorf("Restrict() left %+v", opts)
+	}
+
+	// Without a configured consent everything is allowed; with one, types
+	// without a rule are not processed
+	if got := (&analysis.ConsentPolicy{}).Allowed("lohnzettel"); !slices.Equal(got, analysis.ConsentPurposes) {
+		t.Errorf("Allowed() without consent = %v", got)
+	}
+	if got := (&analysis.ConsentPolicy{Version: 1, Rules: rules[:1]}).Allowed("bescheid"); len(got) != 0 {
+		t.Errorf("Allowed() of a type without rule = %v", got)
+	}
+
+	for name, invalid := range map[string][]analysis.ConsentRule{
+		"unknown purpose": {{DocumentType: "rechnung", Purposes: []string{"training"}}},
+		"duplicate type":  {{DocumentType: "rechnung"}, {DocumentType: "Rechnung"}},
+		"missing type":    {{DocumentType: " ", Purposes: []string{analysis.PurposeSummary}}},
+	} {
+		if err := analysis.ValidateConsentRules(invalid); !errors.Is(err, analysis.ErrInvalidConsent) {
+			t.Errorf("%s: error = %v, want ErrInvalidConsent", name, err)
+		}
+	}
+}
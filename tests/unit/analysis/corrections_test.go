@@ -0,0 +1,56 @@
+package analysis_test
+
+import (
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/analysis"
+	"austrian-business-infrastructure/pkg/money"
+)
+
+func TestCorrectionUndo(t *testing.T) {
+	type fields struct {
+		Date   time.Time   `json:"date"`
+		Amount money.Money `json:"amount"`
+		Due    *time.Time  `json:"due_date"`
+		Notes  string      `json:"notes"`
+	}
+
+	due := time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC)
+	v0 := fields{Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Amount: money.New(120500, "EUR"), Notes: "OCR"}
+	v1 := v0
+	v1.Amount = money.New(125000, "EUR")
+	v1.Due = &due
+	v2 := v1
+	v2.Notes = "checked"
+
+	first, err := analysis.DiffFields(v0, v1)
+	if err != nil {
+		t.Fatalf("DiffFields() error = %v", err)
+	}
+	if len(first) != 2 || first[0].Field != "amount" || first[1].Field != "due_date" ||
+		string(first[0].Old) != "1205.00" || string(first[1].Old) != "null" {
+		t.Fatalf("DiffFields() = %+v", first)
+	}
+	second, _ := analysis.DiffFields(v1, v2)
+	if same, _ := analysis.DiffFields(v2, v2); len(same) != 0 {
+		t.Errorf("DiffFields() of equal values = %+v", same)
+	}
+
+	// Undo steps back one correction at a time and keeps fields the
+	// correction did not change
+	current := v2
+	current.Date = v2.Date.AddDate(0, 0, 1)
+	if err := analysis.RevertFields(&current, second); err != nil {
+		t.Fatalf("RevertFields() error = %v", err)
+	}
+	if current.Notes != "OCR" || current.Amount != v1.Amount || !current.Date.Equal(v2.Date.AddDate(0, 0, 1)) {
+		t.Errorf("after first undo %+v", current)
+	}
+	if err := analysis.RevertFields(&current, first); err != nil {
+		t.Fatalf("RevertFields() error = %v", err)
+	}
+	if current.Amount != v0.Amount || current.Due != nil || current.Notes != "OCR" {
+		t.Errorf("after second undo %+v", current)
+	}
+}
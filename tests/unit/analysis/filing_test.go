@@ -0,0 +1,108 @@
+package analysis_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/analysis"
+	"austrian-business-infrastructure/pkg/money"
+)
+
+func filingRule(name string, position int, conditions analysis.FilingConditions, actions analysis.FilingActions) analysis.FilingRule {
+	return analysis.FilingRule{
+		ID:         uuid.New(),
+		Name:       name,
+		Position:   position,
+		Enabled:    true,
+		Conditions: conditions,
+		Actions:    actions,
+	}
+}
+
+func TestMatchFilingRules(t *testing.T) {
+	threshold := money.EUR(100000)
+	in := &analysis.FilingInput{
+		DocumentType: "bescheid",
+		Sender:       "Finanzamt Österreich",
+		Amounts:      []*analysis.Amount{{AmountType: "nachzahlung", Amount: money.EUR(250000)}},
+		Period:       time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	general := filingRule("Bescheide", 10, analysis.FilingConditions{DocumentTypes: []string{"bescheid"}},
+		analysis.FilingActions{Folder: "Steuern/{year}/{type}", Labels: []string{"Finanzamt"}})
+	large := filingRule("Große Nachzahlung", 0, analysis.FilingConditions{
+		SenderContains: []string{"finanzamt"},
+		AmountTypes:    []string{"nachzahlung"},
+		AmountMin:      &threshold,
+		PeriodFrom:     "2026-01",
+		PeriodTo:       "2026-03",
+	}, analysis.FilingActions{
+		Labels:     []string{"Nachzahlung", "Finanzamt"},
+		ActionItem: &analysis.FilingActionItem{Title: "Nachzahlung {month}/{year} prüfen", DueInDays: 7},
+	})
+	mahnungen := filingRule("Mahnungen", 5, analysis.FilingConditions{DocumentTypes: []string{"mahnung"}},
+		analysis.FilingActions{Folder: "Mahnungen"})
+	disabled := filingRule("Aus", 1, analysis.FilingConditions{}, analysis.FilingActions{Folder: "Aus"})
+	disabled.Enabled = false
+
+	matched := analysis.MatchFilingRules([]analysis.FilingRule{general, large, mahnungen, disabled}, in)
+	if len(matched) != 2 || matched[0].Name != large.Name || matched[1].Name != general.Name {
+		t.Fatalf("matched %+v, want the large Nachzahlung rule before the Bescheid rule", matched)
+	}
+
+	plan := analysis.BuildFilingPlan(matched, in)
+	if plan.Folder != "Steuern/2026/bescheid" {
+		t.Errorf("folder = %q", plan.Folder)
+	}
+	if !slices.Equal(plan.Labels, []string{"Nachzahlung", "Finanzamt"}) {
+		t.Errorf("labels = %v", plan.Labels)
+	}
+	if len(plan.ActionItems) != 1 || plan.ActionItems[0].Title != "Nachzahlung 03/2026 prüfen" || plan.ActionItems[0].Priority != analysis.PriorityMedium {
+		t.Errorf("action items = %+v", plan.ActionItems)
+	}
+
+	large.StopProcessing = true
+	matched = analysis.MatchFilingRules([]analysis.FilingRule{general, large}, in)
+	if len(matched) != 1 || matched[0].Name != large.Name {
+		t.Errorf("stop_processing did not stop: %+v", matched)
+	}
+
+	in.Period = time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	if large.Conditions.Matches(in) {
+		t.Error("rule matched a period after period_to")
+	}
+	in.Period = time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	in.Amounts[0].Amount = money.EUR(50000)
+	if large.Conditions.Matches(in) {
+		t.Error("rule matched an amount below amount_min")
+	}
+}
+
+func TestValidateFilingRule(t *testing.T) {
+	valid := filingRule("Bescheide", 0, analysis.FilingConditions{PeriodFrom: "2026-01"},
+		analysis.FilingActions{Folder: "Steuern/{year}"})
+	if err := analysis.ValidateFilingRule(&valid); err != nil {
+		t.Fatalf("valid rule rejected: %v", err)
+	}
+
+	low, high := money.EUR(200), money.EUR(100)
+	cases := map[string]analysis.FilingRule{
+		"no name":             filingRule(" ", 0, analysis.FilingConditions{}, analysis.FilingActions{Folder: "A"}),
+		"no action":           filingRule("Leer", 0, analysis.FilingConditions{}, analysis.FilingActions{}),
+		"bad period":          filingRule("Periode", 0, analysis.FilingConditions{PeriodFrom: "03/2026"}, analysis.FilingActions{Folder: "A"}),
+		"reversed period":     filingRule("Periode", 0, analysis.FilingConditions{PeriodFrom: "2026-04", PeriodTo: "2026-01"}, analysis.FilingActions{Folder: "A"}),
+		"reversed amounts":    filingRule("Betrag", 0, analysis.FilingConditions{AmountMin: &low, AmountMax: &high}, analysis.FilingActions{Folder: "A"}),
+		"unknown placeholder": filingRule("Platzhalter", 0, analysis.FilingConditions{}, analysis.FilingActions{Folder: "{sender}"}),
+		"empty label":         filingRule("Label", 0, analysis.FilingConditions{}, analysis.FilingActions{Labels: []string{""}}),
+		"bad priority":        filingRule("Aufgabe", 0, analysis.FilingConditions{}, analysis.FilingActions{ActionItem: &analysis.FilingActionItem{Title: "X", Priority: "urgent"}}),
+	}
+	for name, rule := range cases {
+		if err := analysis.ValidateFilingRule(&rule); !errors.Is(err, analysis.ErrInvalidFilingRule) {
+			t.Errorf("%s: got %v, want ErrInvalidFilingRule", name, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package analysis_test
+
+import (
+	"testing"
+
+	"austrian-business-infrastructure/internal/analysis"
+	"austrian-business-infrastructure/internal/document"
+)
+
+func TestExtractReferences(t *testing.T) {
+	text := `Zahlungserinnerung
+Zu unserer Rechnung Nr. 2026-0042 vom 3. März ist noch offen. Siehe auch Rechnungsnummer: RE-17/2026.
+Die Rechnung Nummer vom Vormonat wurde beglichen.
+Bescheid über die Festsetzung der Umsatzsteuer für den Voranmeldungszeitraum März 2026.
+Betrifft: UVA Q1/2026, UVA 1. Quartal 2026`
+
+	refs := analysis.ExtractReferences(text)
+	want := []analysis.ExtractedReference{
+		{Kind: document.ReferenceInvoiceNumber, Value: "2026-0042"},
+		{Kind: document.ReferenceInvoiceNumber, Value: "RE-17/2026"},
+		{Kind: document.ReferenceUVAPeriod, Value: "3/2026", Year: 2026, Month: 3},
+		{Kind: document.ReferenceUVAPeriod, Value: "Q1/2026", Year: 2026, Quarter: 1},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("got %d references, want %d: %+v", len(refs), len(want), refs)
+	}
+	for i, w := range want {
+		got := refs[i]
+		if got.Kind != w.Kind || got.Value != w.Value || got.Year != w.Year || got.Month != w.Month || got.Quarter != w.Quarter {
+			t.Errorf("reference %d = %+v, want %+v", i, got, w)
+		}
+		if got.SourceText == "" || got.Confidence == 0 {
+			t.Errorf("reference %d lacks source text or confidence", i)
+		}
+	}
+}
+
+func TestExtractReferencesIgnoresDates(t *testing.T) {
+	refs := analysis.ExtractReferences("Frist bis 15.04.2026. Zahlbar binnen 14 Tagen ab 03/2026.")
+	if len(refs) != 0 {
+		t.Errorf("expected no references, got %+v", refs)
+	}
+}
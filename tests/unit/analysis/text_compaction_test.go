@@ -0,0 +1,47 @@
+package analysis_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"austrian-business-infrastructure/internal/analysis"
+)
+
+func TestCompressTextRoundTrip(t *testing.T) {
+	text := strings.Repeat("Bescheid über die Festsetzung der Umsatzsteuer für 2024. ", 5000)
+
+	compressed := analysis.CompressText(text)
+	if len(compressed) >= len(text)/4 {
+		t.Errorf("expected repetitive text to compress well, got %d of %d bytes", len(compressed), len(text))
+	}
+
+	restored, err := analysis.DecompressText(compressed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if restored != text {
+		t.Error("expected the restored text to equal the original")
+	}
+
+	if _, err := analysis.DecompressText([]byte("not zstd")); err == nil {
+		t.Error("expected an error for corrupt data")
+	}
+}
+
+func TestTextPreview(t *testing.T) {
+	short := "Ergänzungsersuchen"
+	if got := analysis.TextPreview(short); got != short {
+		t.Errorf("expected short text unchanged, got %q", got)
+	}
+
+	// Multi-byte characters must not be cut in half
+	long := strings.Repeat("ä", analysis.TextPreviewRunes+10)
+	preview := analysis.TextPreview(long)
+	if !utf8.ValidString(preview) || utf8.RuneCountInString(preview) != analysis.TextPreviewRunes {
+		t.Errorf("expected %d valid runes, got %d", analysis.TextPreviewRunes, utf8.RuneCountInString(preview))
+	}
+	if len(preview) >= analysis.TextCompactThreshold {
+		t.Error("expected the preview to stay below the compaction threshold")
+	}
+}
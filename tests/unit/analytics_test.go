@@ -0,0 +1,126 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/analytics"
+	"github.com/google/uuid"
+)
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []*analytics.Event
+}
+
+func (s *recordingSink) Send(ctx context.Context, events []*analytics.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func TestAnalyticsSanitize(t *testing.T) {
+	got := analytics.Sanitize(analytics.Properties{
+		"account_type": "finanzonline",
+		"items":        3,
+		"dry_run":      false,
+		"email":        "max@example.at",
+		"title":        "Bescheid Einkommensteuer 2025",
+		"BadKey":       "x",
+		"nested":       map[string]any{"a": 1},
+		"long":         string(make([]byte, 65)),
+	})
+	want := []string{"account_type", "items", "dry_run"}
+	if len(got) != len(want) {
+		t.Fatalf("Sanitize() = %v, want keys %v", got, want)
+	}
+	for _, key := range want {
+		if _, ok := got[key]; !ok {
+			t.Errorf("Sanitize() dropped %q", key)
+		}
+	}
+}
+
+func TestAnalyticsPseudonymizer(t *testing.T) {
+	id := uuid.New()
+	p := analytics.NewPseudonymizer([]byte("key-1"))
+
+	tenant := p.Tenant(id)
+	if tenant != p.Tenant(id) || len(tenant) != 32 {
+		t.Errorf("Tenant() = %q, want a stable 32 character pseudonym", tenant)
+	}
+	if tenant == p.User(id) {
+		t.Error("tenant and user pseudonyms of the same ID are equal")
+	}
+	if tenant == analytics.NewPseudonymizer([]byte("key-2")).Tenant(id) {
+		t.Error("pseudonyms do not depend on the key")
+	}
+	if p.User(uuid.Nil) != "" {
+		t.Error("User(uuid.Nil) is not empty")
+	}
+}
+
+func TestAnalyticsEmitterFlushesOnClose(t *testing.T) {
+	sink := &recordingSink{}
+	e := analytics.NewEmitter(sink, analytics.Config{Key: []byte("key"), FlushInterval: time.Hour}, nil)
+
+	tenantID, userID := uuid.New(), uuid.New()
+	e.Track(context.Background(), tenantID, userID, analytics.EventAccountCreated, analytics.Properties{"account_type": "elda"})
+	e.Track(context.Background(), uuid.Nil, userID, analytics.EventAccountCreated, nil)
+	e.Close()
+
+	if len(sink.events) != 1 {
+		t.Fatalf("delivered %d events, want 1", len(sink.events))
+	}
+	event := sink.events[0]
+	p := analytics.NewPseudonymizer([]byte("key"))
+	if event.Tenant != p.Tenant(tenantID) || event.User != p.User(userID) {
+		t.Errorf("event = %+v, want pseudonymized tenant and user", event)
+	}
+	if event.Properties["account_type"] != "elda" {
+		t.Errorf("properties = %v", event.Properties)
+	}
+
+	// A disabled emitter is nil and ignores everything
+	var disabled *analytics.Emitter
+	disabled.Track(context.Background(), tenantID, userID, analytics.EventTenantCreated, nil)
+	disabled.Close()
+}
+
+func TestAnalyticsSegmentBatch(t *testing.T) {
+	events := []*analytics.Event{
+		{ID: uuid.New(), Name: analytics.EventTenantCreated, Tenant: "t1", User: "u1", OccurredAt: time.Now()},
+		{ID: uuid.New(), Name: analytics.EventAnalysisCompleted, Tenant: "t1", OccurredAt: time.Now()},
+	}
+	body, err := analytics.EncodeSegmentBatch(events, time.Now())
+	if err != nil {
+		t.Fatalf("EncodeSegmentBatch() error = %v", err)
+	}
+
+	var decoded struct {
+		Batch []struct {
+			Type        string            `json:"type"`
+			Event       string            `json:"event"`
+			UserID      string            `json:"userId"`
+			AnonymousID string            `json:"anonymousId"`
+			Context     map[string]string `json:"context"`
+		} `json:"batch"`
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(decoded.Batch) != 2 {
+		t.Fatalf("batch has %d messages, want 2", len(decoded.Batch))
+	}
+	first, second := decoded.Batch[0], decoded.Batch[1]
+	if first.Type != "track" || first.UserID != "u1" || first.AnonymousID != "" || first.Context["groupId"] != "t1" {
+		t.Errorf("first message = %+v", first)
+	}
+	if second.UserID != "" || second.AnonymousID != "t1" {
+		t.Errorf("message without user = %+v, want the tenant as anonymous ID", second)
+	}
+}
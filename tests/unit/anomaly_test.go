@@ -0,0 +1,125 @@
+package unit
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/anomaly"
+	"github.com/google/uuid"
+)
+
+func anomalyDay(d int) time.Time {
+	return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAnomalyDuplicatePayments(t *testing.T) {
+	first := anomaly.Payment{ID: uuid.New(), Source: anomaly.SubjectPaymentItem, IBAN: "AT61 1904 3002 3457 3201", Amount: 125000, Date: anomalyDay(2)}
+	sameDay := anomaly.Payment{ID: uuid.New(), Source: anomaly.SubjectPaymentItem, IBAN: "at611904300234573201", Amount: 125000, Date: anomalyDay(2)}
+	nextWeek := anomaly.Payment{ID: uuid.New(), Source: anomaly.SubjectPaymentItem, IBAN: "AT611904300234573201", Amount: 125000, Date: anomalyDay(8)}
+	monthly := anomaly.Payment{ID: uuid.New(), Source: anomaly.SubjectPaymentItem, IBAN: "AT611904300234573201", Amount: 125000, Date: anomalyDay(30)}
+	// The bank debit of the same transfer is not a duplicate of the item
+	debit := anomaly.Payment{ID: uuid.New(), Source: anomaly.SubjectBankTransaction, IBAN: "AT611904300234573201", Amount: 125000, Date: anomalyDay(2)}
+	otherAmount := anomaly.Payment{ID: uuid.New(), Source: anomaly.SubjectPaymentItem, IBAN: "AT611904300234573201", Amount: 125001, Date: anomalyDay(2)}
+
+	findings := anomaly.DuplicatePayments(
+		[]anomaly.Payment{first, sameDay, nextWeek, monthly, debit, otherAmount},
+		anomalyDay(1), 7*24*time.Hour)
+
+	bySubject := make(map[uuid.UUID]*anomaly.Finding)
+	for _, f := range findings {
+		bySubject[f.SubjectID] = f
+	}
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2", len(findings))
+	}
+
+	// Of two payments on the same day only the later one in order is flagged
+	flagged, other := first, sameDay
+	if first.ID.String() < sameDay.ID.String() {
+		flagged, other = sameDay, first
+	}
+	f := bySubject[flagged.ID]
+	if f == nil || f.Severity != anomaly.SeverityHigh || len(f.RelatedIDs) != 1 || f.RelatedIDs[0] != other.ID {
+		t.Errorf("same-day duplicate = %+v", f)
+	}
+	if f := bySubject[nextWeek.ID]; f == nil || f.Severity != anomaly.SeverityMedium || len(f.RelatedIDs) != 2 {
+		t.Errorf("duplicate within window = %+v", f)
+	}
+	if bySubject[monthly.ID] != nil {
+		t.Error("payment outside the window flagged")
+	}
+
+	// Earlier payments before since are history only
+	if got := anomaly.DuplicatePayments([]anomaly.Payment{first, sameDay}, anomalyDay(3), 7*24*time.Hour); len(got) != 0 {
+		t.Errorf("got %d findings for payments before since", len(got))
+	}
+}
+
+func TestAnomalyModifiedZScore(t *testing.T) {
+	score, median := anomaly.ModifiedZScore(1000, []float64{100, 110, 90, 105, 95})
+	if median != 100 {
+		t.Errorf("median = %v, want 100", median)
+	}
+	if score < 3.5 {
+		t.Errorf("score = %v, want above 3.5", score)
+	}
+
+	if score, _ := anomaly.ModifiedZScore(104, []float64{100, 110, 90, 105, 95}); math.Abs(score) > 3.5 {
+		t.Errorf("score of a usual amount = %v", score)
+	}
+
+	// Mostly equal history falls back to the mean absolute deviation
+	if score, _ := anomaly.ModifiedZScore(200, []float64{100, 100, 100, 100, 150}); math.IsInf(score, 0) || score < 3.5 {
+		t.Errorf("score with zero MAD = %v", score)
+	}
+
+	if score, _ := anomaly.ModifiedZScore(99, []float64{100, 100, 100}); !math.IsInf(score, -1) {
+		t.Errorf("score against a constant history = %v, want -Inf", score)
+	}
+}
+
+func TestAnomalyUnusualAmounts(t *testing.T) {
+	var invoices []anomaly.Invoice
+	for i, amount := range []int64{100000, 110000, 95000, 105000, 100000} {
+		invoices = append(invoices, anomaly.Invoice{
+			ID: uuid.New(), Number: fmt.Sprintf("RE-%d", i+1), Counterparty: "muster gmbh",
+			BuyerName: "Muster GmbH", Amount: amount, IssueDate: anomalyDay(1).AddDate(0, -i-1, 0),
+		})
+	}
+	high := anomaly.Invoice{ID: uuid.New(), Number: "RE-9", Counterparty: "muster gmbh", BuyerName: "Muster GmbH", Amount: 1000000, IssueDate: anomalyDay(10)}
+	usual := anomaly.Invoice{ID: uuid.New(), Number: "RE-10", Counterparty: "muster gmbh", BuyerName: "Muster GmbH", Amount: 102000, IssueDate: anomalyDay(11)}
+	newBuyer := anomaly.Invoice{ID: uuid.New(), Number: "RE-11", Counterparty: "neu ag", BuyerName: "Neu AG", Amount: 9999999, IssueDate: anomalyDay(12)}
+
+	findings := anomaly.UnusualAmounts(append(invoices, high, usual, newBuyer), anomalyDay(1), 5, 3.5)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(findings))
+	}
+	f := findings[0]
+	if f.SubjectID != high.ID || f.Kind != anomaly.KindUnusualAmount || f.Severity != anomaly.SeverityHigh {
+		t.Errorf("finding = %+v", f)
+	}
+}
+
+func TestAnomalyUnknownCounterparties(t *testing.T) {
+	lines := []anomaly.BankLine{
+		{ID: uuid.New(), IBAN: "DE89 3704 0044 0532 0130 00", Counterparty: "Unbekannt", Amount: 5000, BookingDate: anomalyDay(5)},
+		{ID: uuid.New(), IBAN: "DE89370400440532013000", Amount: 20000, Debit: true, BookingDate: anomalyDay(3)},
+		{ID: uuid.New(), IBAN: "AT611904300234573201", Amount: 7000, BookingDate: anomalyDay(4)},
+		{ID: uuid.New(), IBAN: "", Amount: 100, BookingDate: anomalyDay(4)},
+	}
+	known := map[string]bool{"AT611904300234573201": true}
+
+	findings := anomaly.UnknownCounterparties(lines, known)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(findings))
+	}
+	f := findings[0]
+	if f.Fingerprint != "DE89370400440532013000" || f.SubjectID != lines[1].ID || len(f.RelatedIDs) != 1 {
+		t.Errorf("finding = %+v", f)
+	}
+	if f.Severity != anomaly.SeverityMedium || f.AmountCents == nil || *f.AmountCents != -15000 {
+		t.Errorf("severity %s, amount %v", f.Severity, f.AmountCents)
+	}
+}
@@ -0,0 +1,184 @@
+package api_test
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/pkg/cache"
+)
+
+func newTestCache(t *testing.T) *cache.Client {
+	t.Helper()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+	return &cache.Client{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// guardedEcho returns the email the handler decoded, proving the body survives the guard
+func guardedEcho(guard *api.AbuseGuard, endpoint string) http.Handler {
+	return guard.Protect(endpoint)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Email string `json:"email"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(body.Email))
+	}))
+}
+
+func postJSON(handler http.Handler, remoteAddr, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAbuseGuard_IPLimit(t *testing.T) {
+	guard := api.NewAbuseGuard(newTestCache(t), map[string]api.AbuseRule{
+		"register": {IPLimit: 2, Window: time.Hour},
+	}, discardLogger())
+	handler := guardedEcho(guard, "register")
+
+	for i := 0; i < 2; i++ {
+		if rec := postJSON(handler, "198.51.100.7:1234", `{}`, nil); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i+1, rec.Code)
+		}
+	}
+	rec := postJSON(handler, "198.51.100.7:1234", `{}`, nil)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("expected 429 with Retry-After, got %d", rec.Code)
+	}
+	if rec := postJSON(handler, "198.51.100.8:1234", `{}`, nil); rec.Code != http.StatusOK {
+		t.Errorf("expected other IP to pass, got %d", rec.Code)
+	}
+
+	m := guard.Metrics()["register"]
+	if m.Allowed != 3 || m.BlockedIP != 1 {
+		t.Errorf("unexpected metrics: %+v", m)
+	}
+}
+
+func TestAbuseGuard_EmailLimit(t *testing.T) {
+	guard := api.NewAbuseGuard(newTestCache(t), map[string]api.AbuseRule{
+		"forgot_password": {EmailLimit: 1, Window: time.Hour},
+	}, discardLogger())
+	handler := guardedEcho(guard, "forgot_password")
+
+	rec := postJSON(handler, "198.51.100.7:1", `{"email":"Max@Example.at"}`, nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "Max@Example.at" {
+		t.Fatalf("expected body to reach the handler, got %d %q", rec.Code, rec.Body.String())
+	}
+	// Same address from another IP, differently cased
+	if rec := postJSON(handler, "203.0.113.9:1", `{"email":" max@example.at"}`, nil); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 for repeated email, got %d", rec.Code)
+	}
+	if rec := postJSON(handler, "203.0.113.9:1", `{"email":"other@example.at"}`, nil); rec.Code != http.StatusOK {
+		t.Errorf("expected other email to pass, got %d", rec.Code)
+	}
+	if m := guard.Metrics()["forgot_password"]; m.BlockedEmail != 1 {
+		t.Errorf("expected one blocked email, got %+v", m)
+	}
+}
+
+func TestAbuseGuard_UnknownEndpoint(t *testing.T) {
+	guard := api.NewAbuseGuard(newTestCache(t), nil, discardLogger())
+	if rec := postJSON(guardedEcho(guard, "register"), "198.51.100.7:1", `{}`, nil); rec.Code != http.StatusOK {
+		t.Errorf("expected endpoint without rule to pass, got %d", rec.Code)
+	}
+}
+
+func solvePoW(challenge string, difficulty int) string {
+	for i := 0; ; i++ {
+		nonce := strconv.Itoa(i)
+		if api.LeadingZeroBits(sha256.Sum256([]byte(challenge+":"+nonce))) >= difficulty {
+			return challenge + ":" + nonce
+		}
+	}
+}
+
+func TestAbuseGuard_ProofOfWork(t *testing.T) {
+	redisClient := newTestCache(t)
+	pow := api.NewProofOfWork([]byte("secret"), 8, 5*time.Minute, redisClient)
+	guard := api.NewAbuseGuard(redisClient, map[string]api.AbuseRule{
+		"register": {Challenge: true},
+	}, discardLogger())
+	guard.SetChallengeVerifier(pow)
+	handler := guardedEcho(guard, "register")
+
+	if rec := postJSON(handler, "198.51.100.7:1", `{}`, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without solution, got %d", rec.Code)
+	}
+
+	challenge, err := pow.Issue(time.Now())
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	solution := solvePoW(challenge.Challenge, challenge.Difficulty)
+	headers := map[string]string{api.ChallengeHeader: solution}
+	if rec := postJSON(handler, "198.51.100.7:1", `{}`, headers); rec.Code != http.StatusOK {
+		t.Errorf("expected solved challenge to pass, got %d", rec.Code)
+	}
+	if rec := postJSON(handler, "198.51.100.7:1", `{}`, headers); rec.Code != http.StatusForbidden {
+		t.Errorf("expected replayed solution to fail, got %d", rec.Code)
+	}
+
+	// A challenge signed with another secret is rejected
+	forged, _ := api.NewProofOfWork([]byte("other"), 8, 5*time.Minute, redisClient).Issue(time.Now())
+	headers = map[string]string{api.ChallengeHeader: solvePoW(forged.Challenge, 8)}
+	if rec := postJSON(handler, "198.51.100.7:1", `{}`, headers); rec.Code != http.StatusForbidden {
+		t.Errorf("expected forged challenge to fail, got %d", rec.Code)
+	}
+
+	// An expired challenge is rejected
+	expired, _ := pow.Issue(time.Now().Add(-10 * time.Minute))
+	headers = map[string]string{api.ChallengeHeader: solvePoW(expired.Challenge, 8)}
+	if rec := postJSON(handler, "198.51.100.7:1", `{}`, headers); rec.Code != http.StatusForbidden {
+		t.Errorf("expected expired challenge to fail, got %d", rec.Code)
+	}
+
+	if m := guard.Metrics()["register"]; m.BlockedChallenge != 4 || m.Allowed != 1 {
+		t.Errorf("unexpected metrics: %+v", m)
+	}
+}
+
+func TestCaptchaVerifier(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		ok := r.PostForm.Get("secret") == "s3cret" && r.PostForm.Get("response") == "good-token"
+		json.NewEncoder(w).Encode(map[string]bool{"success": ok})
+	}))
+	defer provider.Close()
+
+	verifier := api.NewCaptchaVerifier(provider.URL, "s3cret")
+	if err := verifier.Verify(t.Context(), "good-token", "198.51.100.7"); err != nil {
+		t.Errorf("expected valid token, got %v", err)
+	}
+	if err := verifier.Verify(t.Context(), "bad-token", "198.51.100.7"); err == nil {
+		t.Error("expected invalid token to fail")
+	}
+	if err := verifier.Verify(t.Context(), "", "198.51.100.7"); err == nil {
+		t.Error("expected missing token to fail")
+	}
+}
@@ -0,0 +1,191 @@
+package api_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{api.EncodingZstd, api.EncodingGzip}
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip, deflate, br", "gzip"},
+		{"gzip, deflate, br, zstd", "zstd"},
+		{"zstd;q=0.5, gzip", "gzip"},
+		{"zstd;q=0, gzip;q=0", ""},
+		{"*", "zstd"},
+		{"*;q=0.1, gzip", "gzip"},
+		{"GZIP;q=0.8", "gzip"},
+	}
+	for _, tt := range tests {
+		if got := api.NegotiateEncoding(tt.header, supported); got != tt.want {
+			t.Errorf("NegotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func serveCompressed(t *testing.T, acceptEncoding string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/analyses", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	api.Compress(api.DefaultCompressionConfig())(handler).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCompressResponses(t *testing.T) {
+	large := strings.Repeat(`{"extracted_text":"Bescheid über die Festsetzung der Umsatzsteuer"},`, 100)
+	writeLarge := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "999")
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, large)
+	}
+
+	rec := serveCompressed(t, "gzip", writeLarge)
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Content-Length") != "" {
+		t.Fatalf("headers = %v", rec.Header())
+	}
+	if rec.Header().Get("ETag") != `W/"v1"` || rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("ETag = %q, Vary = %q", rec.Header().Get("ETag"), rec.Header().Get("Vary"))
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != large {
+		t.Errorf("gzip body differs, %d bytes", len(body))
+	}
+
+	rec = serveCompressed(t, "gzip, zstd", writeLarge)
+	if rec.Header().Get("Content-Encoding") != "zstd" {
+		t.Fatalf("Content-Encoding = %q, want zstd", rec.Header().Get("Content-Encoding"))
+	}
+	dec, err := zstd.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+	if body, _ := io.ReadAll(dec); string(body) != large {
+		t.Errorf("zstd body differs, %d bytes", len(body))
+	}
+
+	// Small bodies, already compressed types and clients without
+	// Accept-Encoding get the response unchanged
+	rec = serveCompressed(t, "gzip", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":"1"}`)
+	})
+	if rec.Code != http.StatusCreated || rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != `{"id":"1"}` {
+		t.Errorf("small response = %d %v %q", rec.Code, rec.Header(), rec.Body.String())
+	}
+	rec = serveCompressed(t, "gzip", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		io.WriteString(w, large)
+	})
+	if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != large {
+		t.Errorf("PDF was compressed: %v", rec.Header())
+	}
+	if rec = serveCompressed(t, "", writeLarge); rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("compressed without Accept-Encoding")
+	}
+}
+
+func TestFieldSelection(t *testing.T) {
+	item := map[string]interface{}{"id": "a1", "status": "completed", "extracted_text": "…"}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/analyses?fields=-extracted_text", nil)
+	fields := api.ParseFields(req)
+	if fields.Has("extracted_text") || !fields.Has("status") {
+		t.Errorf("exclusion: Has = %v, %v", fields.Has("extracted_text"), fields.Has("status"))
+	}
+	if data, _ := fields.Marshal(item); string(data) != `{"id":"a1","status":"completed"}` {
+		t.Errorf("exclusion = %s", data)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/analyses?fields=id,%20status", nil)
+	if data, _ := api.ParseFields(req).Marshal(item); string(data) != `{"id":"a1","status":"completed"}` {
+		t.Errorf("inclusion = %s", data)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/analyses", nil)
+	if fields := api.ParseFields(req); !fields.IsZero() || !fields.Has("extracted_text") {
+		t.Error("no ?fields= must send every field")
+	}
+}
+
+func TestJSONStream(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/audit-logs/export?fields=-details", nil)
+	handler := func(fail bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			stream, err := api.NewJSONStream(w, http.StatusOK, "logs", api.ParseFields(r), map[string]interface{}{"exported_at": "2026-10-16T08:00:00Z"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 250; i++ {
+				stream.Write(map[string]interface{}{"id": i, "details": map[string]string{"x": "y"}})
+			}
+			if fail {
+				stream.Fail("export failed")
+				return
+			}
+			stream.Close(map[string]interface{}{"count": stream.Count()})
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler(false).ServeHTTP(rec, req)
+	var doc struct {
+		ExportedAt string                   `json:"exported_at"`
+		Logs       []map[string]interface{} `json:"logs"`
+		Count      int                      `json:"count"`
+		Incomplete bool                     `json:"incomplete"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(doc.Logs) != 250 || doc.Count != 250 || doc.ExportedAt == "" || doc.Incomplete {
+		t.Errorf("document = %d logs, count %d, exported_at %q", len(doc.Logs), doc.Count, doc.ExportedAt)
+	}
+	if _, ok := doc.Logs[0]["details"]; ok {
+		t.Error("excluded field was sent")
+	}
+
+	rec = httptest.NewRecorder()
+	handler(true).ServeHTTP(rec, req)
+	doc.Incomplete = false
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil || !doc.Incomplete {
+		t.Errorf("failed stream: %v, incomplete = %v", err, doc.Incomplete)
+	}
+
+	// Streams are compressed as they are flushed
+	rec = httptest.NewRecorder()
+	req.Header.Set("Accept-Encoding", "gzip")
+	api.Compress(api.DefaultCompressionConfig())(handler(false)).ServeHTTP(rec, req)
+	if rec.Header().Get("Content-Encoding") != "gzip" || !rec.Flushed {
+		t.Fatalf("stream not compressed: %v", rec.Header())
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(zr)
+	if err := json.Unmarshal(body, &doc); err != nil || len(doc.Logs) != 250 {
+		t.Errorf("compressed stream: %v, %d logs", err, len(doc.Logs))
+	}
+}
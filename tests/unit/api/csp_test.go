@@ -0,0 +1,106 @@
+package api_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+func serveCSP(t *testing.T, mw api.Middleware, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	handler := api.SecureHeaders(mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestContentSecurityPolicy_RouteOverride(t *testing.T) {
+	viewer := api.EmbeddedViewerCSPConfig([]string{"https://app.example.at", "*"})
+	mw := api.ContentSecurityPolicy(api.DefaultCSPConfig(),
+		api.CSPRoute{Pattern: "/api/v1/documents/*/content", Config: viewer},
+	)
+
+	rec := serveCSP(t, mw, "/api/v1/documents")
+	csp := rec.Header().Get("Content-Security-Policy")
+	if !strings.Contains(csp, "object-src 'none'") || !strings.Contains(csp, "frame-ancestors 'none'") {
+		t.Errorf("unexpected default policy: %s", csp)
+	}
+	if got := rec.Header().Get("X-Frame-Options"); got != "DENY" {
+		t.Errorf("expected X-Frame-Options DENY, got %q", got)
+	}
+
+	rec = serveCSP(t, mw, "/api/v1/documents/0b7c/content")
+	csp = rec.Header().Get("Content-Security-Policy")
+	if !strings.Contains(csp, "object-src 'self'") {
+		t.Errorf("expected viewer to allow object-src 'self': %s", csp)
+	}
+	if !strings.Contains(csp, "frame-ancestors 'self' https://app.example.at;") {
+		t.Errorf("expected viewer frame-ancestors without wildcard: %s", csp)
+	}
+	if got := rec.Header().Get("X-Frame-Options"); got != "SAMEORIGIN" {
+		t.Errorf("expected X-Frame-Options SAMEORIGIN, got %q", got)
+	}
+}
+
+func TestContentSecurityPolicy_ReportOnly(t *testing.T) {
+	config := api.DefaultCSPConfig()
+	config.ReportOnly = true
+	config.ReportURI = "/api/v1/csp-report"
+
+	rec := serveCSP(t, api.ContentSecurityPolicy(config), "/")
+	if rec.Header().Get("Content-Security-Policy") != "" {
+		t.Error("expected no enforced policy in report-only mode")
+	}
+	csp := rec.Header().Get("Content-Security-Policy-Report-Only")
+	if !strings.HasSuffix(csp, "report-uri /api/v1/csp-report") {
+		t.Errorf("expected report-uri in policy: %s", csp)
+	}
+}
+
+func TestParseCSPReports(t *testing.T) {
+	legacy := `{"csp-report":{"document-uri":"https://app.example.at/sign/x","blocked-uri":"inline","violated-directive":"script-src-elem 'self'","line-number":12}}`
+	violations, err := api.ParseCSPReports([]byte(legacy))
+	if err != nil {
+		t.Fatalf("ParseCSPReports failed: %v", err)
+	}
+	if len(violations) != 1 || violations[0].EffectiveDirective != "script-src-elem" || violations[0].LineNumber != 12 {
+		t.Errorf("unexpected legacy violations: %+v", violations)
+	}
+
+	reporting := `[{"type":"deprecation","body":{}},{"type":"csp-violation","url":"https://app.example.at/","body":{"blockedURL":"https://evil.example/x.js","effectiveDirective":"script-src-elem","disposition":"report"}}]`
+	violations, err = api.ParseCSPReports([]byte(reporting))
+	if err != nil {
+		t.Fatalf("ParseCSPReports failed: %v", err)
+	}
+	if len(violations) != 1 || violations[0].DocumentURI != "https://app.example.at/" || violations[0].BlockedURI != "https://evil.example/x.js" {
+		t.Errorf("unexpected reporting API violations: %+v", violations)
+	}
+
+	if _, err := api.ParseCSPReports([]byte("not json")); err == nil {
+		t.Error("expected error for invalid report")
+	}
+}
+
+func TestCSPReportHandler(t *testing.T) {
+	handler := api.CSPReportHandler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/csp-report", strings.NewReader(`{"csp-report":{"blocked-uri":"eval"}}`)))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/csp-report", strings.NewReader("{")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,60 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+func newCSRFHandler() http.Handler {
+	csrf := api.CSRF(&api.CSRFConfig{
+		TrustedOrigins: []string{"https://app.example.at"},
+		CookieNames:    []string{"refresh_token"},
+	})
+	return csrf(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestCSRF(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		cookie  bool
+		headers map[string]string
+		want    int
+	}{
+		{"safe method", http.MethodGet, true, nil, http.StatusOK},
+		{"no auth cookie", http.MethodPost, false, nil, http.StatusOK},
+		{"bearer token", http.MethodPost, true, map[string]string{"Authorization": "Bearer x"}, http.StatusOK},
+		{"cookie without header", http.MethodPost, true, nil, http.StatusForbidden},
+		{"cookie with header", http.MethodPost, true, map[string]string{api.CSRFHeader: "XMLHttpRequest"}, http.StatusOK},
+		{"trusted origin", http.MethodPost, true, map[string]string{api.CSRFHeader: "1", "Origin": "https://app.example.at"}, http.StatusOK},
+		{"same origin", http.MethodPost, true, map[string]string{api.CSRFHeader: "1", "Origin": "https://api.example.at"}, http.StatusOK},
+		{"foreign origin", http.MethodPost, true, map[string]string{api.CSRFHeader: "1", "Origin": "https://evil.example"}, http.StatusForbidden},
+		{"opaque origin", http.MethodDelete, true, map[string]string{api.CSRFHeader: "1", "Origin": "null"}, http.StatusForbidden},
+		{"foreign referer", http.MethodPost, true, map[string]string{api.CSRFHeader: "1", "Referer": "https://evil.example/page"}, http.StatusForbidden},
+		{"trusted referer", http.MethodPost, true, map[string]string{api.CSRFHeader: "1", "Referer": "https://app.example.at/login"}, http.StatusOK},
+	}
+
+	handler := newCSRFHandler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "https://api.example.at/api/v1/auth/refresh", nil)
+			if tt.cookie {
+				req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "token"})
+			}
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
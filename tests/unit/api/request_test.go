@@ -0,0 +1,75 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+)
+
+func TestRequestTenant(t *testing.T) {
+	tenantID := uuid.New()
+	req := httptest.NewRequest("GET", "/", nil)
+
+	rec := httptest.NewRecorder()
+	if _, ok := api.RequestTenant(rec, req); ok || rec.Code != http.StatusUnauthorized {
+		t.Errorf("without a tenant: ok=%v, status %d, want 401", ok, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req = req.WithContext(context.WithValue(req.Context(), api.TenantIDKey, tenantID.String()))
+	if id, ok := api.RequestTenant(rec, req); !ok || id != tenantID {
+		t.Errorf("got %s, %v, want %s", id, ok, tenantID)
+	}
+}
+
+func TestRequestUser(t *testing.T) {
+	userID := uuid.New()
+	req := httptest.NewRequest("GET", "/", nil)
+
+	rec := httptest.NewRecorder()
+	if _, ok := api.RequestUser(rec, req); ok || rec.Code != http.StatusUnauthorized {
+		t.Errorf("without a user: ok=%v, status %d, want 401", ok, rec.Code)
+	}
+	if id := api.OptionalUser(req); id != nil {
+		t.Errorf("optional user without a user = %s, want nil", id)
+	}
+
+	req = req.WithContext(context.WithValue(req.Context(), api.UserIDKey, userID.String()))
+	if id, ok := api.RequestUser(httptest.NewRecorder(), req); !ok || id != userID {
+		t.Errorf("got %s, %v, want %s", id, ok, userID)
+	}
+	if id := api.OptionalUser(req); id == nil || *id != userID {
+		t.Errorf("optional user = %v, want %s", id, userID)
+	}
+}
+
+func TestPathID(t *testing.T) {
+	id := uuid.New()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /teams/{id}", func(w http.ResponseWriter, r *http.Request) {
+		got, ok := api.PathID(w, r, "id")
+		if !ok {
+			return
+		}
+		if got != id {
+			t.Errorf("got %s, want %s", got, id)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	for path, want := range map[string]int{
+		"/teams/" + id.String(): http.StatusNoContent,
+		"/teams/not-a-uuid":     http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s: status %d, want %d", path, rec.Code, want)
+		}
+	}
+}
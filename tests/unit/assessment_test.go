@@ -0,0 +1,103 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/assessment"
+)
+
+const sampleUVABescheid = `Bescheid über die Festsetzung der Umsatzsteuer für 09/2026
+Kennzahl 000 Gesamtbetrag der Lieferungen 50.000,00
+KZ 017 Normalsteuersatz 20 % 50.000,00 10.000,00
+KZ 060 Vorsteuern 2.400,00
+Festgesetzte Gutschrift: 300,00
+Gegen diesen Bescheid ist binnen eines Monats ab Zustellung die Beschwerde zulässig.`
+
+func TestAssessmentParseAssessed(t *testing.T) {
+	got := assessment.ParseAssessed(sampleUVABescheid)
+	want := map[string]int64{"000": 5000000, "017": 5000000, "060": 240000, "095": -30000}
+	if len(got) != len(want) {
+		t.Fatalf("ParseAssessed() = %v, want %v", got, want)
+	}
+	for code, amount := range want {
+		if got[code] != amount {
+			t.Errorf("KZ %s = %d, want %d", code, got[code], amount)
+		}
+	}
+
+	if got := assessment.ParseAssessed("KZ 095 Zahllast 1.234,56-"); got["095"] != -123456 {
+		t.Errorf("trailing minus: KZ 095 = %d, want -123456", got["095"])
+	}
+	if got := assessment.ParseAssessed("Bescheid vom 15.10.2026, KZ 999 100,00"); len(got) != 0 {
+		t.Errorf("ParseAssessed() = %v, want nothing", got)
+	}
+}
+
+func TestAssessmentCompare(t *testing.T) {
+	submitted := map[string]int64{"000": 5000000, "017": 5000000, "060": 150000, "070": 0, "095": 700000}
+	assessed := map[string]int64{"000": 5000050, "060": 148000, "070": 20000, "095": 760000}
+
+	lines := assessment.Compare(submitted, assessed, assessment.DefaultConfig())
+	if len(lines) != 4 {
+		t.Fatalf("Compare() returned %d lines, want 4", len(lines))
+	}
+	tests := []struct {
+		kennzahl    string
+		difference  int64
+		significant bool
+	}{
+		{"000", 50, false},   // rounding
+		{"060", -2000, true}, // below 100 EUR but over 1 %
+		{"070", 20000, true}, // not submitted at all
+		{"095", 60000, true}, // over 100 EUR
+	}
+	for i, tt := range tests {
+		line := lines[i]
+		if line.Kennzahl != tt.kennzahl || line.Difference != tt.difference || line.Significant != tt.significant {
+			t.Errorf("line %d = %+v, want KZ %s difference %d significant %v", i, line, tt.kennzahl, tt.difference, tt.significant)
+		}
+	}
+}
+
+func TestAssessmentAppealDeadline(t *testing.T) {
+	// Delivered on 31 Aug: the month ends on 30 Sep, a Wednesday
+	received := time.Date(2026, 8, 31, 9, 30, 0, 0, time.UTC)
+	deadline, source := assessment.AppealDeadline(received, nil)
+	if want := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC); !deadline.Equal(want) || source != assessment.DeadlineStatutory {
+		t.Errorf("AppealDeadline() = %v %s, want %v statutory", deadline, source, want)
+	}
+
+	// Delivered on 17 Sep: 17 Oct is a Saturday, the deadline moves to Monday
+	deadline, _ = assessment.AppealDeadline(time.Date(2026, 9, 17, 0, 0, 0, 0, time.UTC), nil)
+	if want := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC); !deadline.Equal(want) {
+		t.Errorf("AppealDeadline() = %v, want %v", deadline, want)
+	}
+
+	extracted := []time.Time{time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)}
+	deadline, source = assessment.AppealDeadline(received, extracted)
+	if !deadline.Equal(extracted[1]) || source != assessment.DeadlineExtracted {
+		t.Errorf("AppealDeadline() = %v %s, want the earliest extracted deadline", deadline, source)
+	}
+}
+
+func TestAssessmentDraft(t *testing.T) {
+	lines := assessment.Compare(
+		map[string]int64{"060": 300000, "095": -50000},
+		map[string]int64{"060": 240000, "095": 10000},
+		assessment.DefaultConfig())
+	draft := assessment.Draft(&assessment.Bescheid{ReceivedAt: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)}, "09/2026", lines)
+
+	for _, want := range []string{
+		"Beschwerde gemäß § 243 BAO",
+		"Zeitraum 09/2026, zugestellt am 02.10.2026",
+		"KZ 060 (Vorsteuern): erklärt EUR 3.000,00, festgesetzt EUR 2.400,00, Differenz EUR -600,00",
+		"erklärungsgemäß festgesetzt wird (Gutschrift EUR 500,00)",
+		"[Begründung ergänzen]",
+	} {
+		if !strings.Contains(draft, want) {
+			t.Errorf("draft lacks %q:\n%s", want, draft)
+		}
+	}
+}
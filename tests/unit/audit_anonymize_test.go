@@ -0,0 +1,79 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/audit"
+)
+
+func TestAnonymizeIPTruncate(t *testing.T) {
+	a := audit.NewAnonymizer([]byte("key"), time.Hour)
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"192.168.1.123", "192.168.1.0"},
+		{" 10.20.30.40 ", "10.20.30.0"},
+		{"::ffff:192.168.1.123", "192.168.1.0"},
+		{"2001:db8:85a3:8d3:1319:8a2e:370:7348", "2001:db8:85a3::"},
+		{"fe80::1%eth0", "fe80::"},
+		{"not-an-ip", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := a.Anonymize(tt.in, audit.IPModeTruncate); got != tt.want {
+			t.Errorf("Anonymize(%q, truncate) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAnonymizeIPFull(t *testing.T) {
+	a := audit.NewAnonymizer(nil, 0)
+	if got := a.Anonymize("192.168.1.123", audit.IPModeFull); got != "" {
+		t.Errorf("full anonymization stored %q", got)
+	}
+}
+
+func TestAnonymizeIPHashRotatesSalt(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	a := audit.NewAnonymizer([]byte("key"), 24*time.Hour)
+	a.SetClock(func() time.Time { return now })
+
+	first := a.Anonymize("192.168.1.123", audit.IPModeHash)
+	if !strings.HasPrefix(first, "h:") || len(first) > 45 {
+		t.Fatalf("unexpected hash %q", first)
+	}
+	if strings.Contains(first, "192.168") {
+		t.Fatalf("hash leaks the address: %q", first)
+	}
+	if again := a.Anonymize("192.168.1.123", audit.IPModeHash); again != first {
+		t.Errorf("same address and period hashed differently: %q, %q", first, again)
+	}
+	if other := a.Anonymize("192.168.1.124", audit.IPModeHash); other == first {
+		t.Error("different addresses hashed equally")
+	}
+
+	now = now.Add(24 * time.Hour)
+	if next := a.Anonymize("192.168.1.123", audit.IPModeHash); next == first {
+		t.Error("hash did not change after the salt rotation")
+	}
+
+	b := audit.NewAnonymizer([]byte("other key"), 24*time.Hour)
+	b.SetClock(func() time.Time { return now })
+	if a.Anonymize("192.168.1.123", audit.IPModeHash) == b.Anonymize("192.168.1.123", audit.IPModeHash) {
+		t.Error("different keys produced the same hash")
+	}
+}
+
+func TestValidIPMode(t *testing.T) {
+	for _, mode := range []string{audit.IPModeFull, audit.IPModeTruncate, audit.IPModeHash} {
+		if !audit.ValidIPMode(mode) {
+			t.Errorf("ValidIPMode(%q) = false", mode)
+		}
+	}
+	if audit.ValidIPMode("none") {
+		t.Error(`ValidIPMode("none") = true`)
+	}
+}
@@ -0,0 +1,87 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"austrian-business-infrastructure/internal/backfill"
+	"github.com/jackc/pgx/v5"
+)
+
+func noopBatch(ctx context.Context, tx pgx.Tx, cursor string, limit int) (string, int, error) {
+	return cursor, 0, nil
+}
+
+func TestBackfillPhaseFlags(t *testing.T) {
+	tests := []struct {
+		phase     backfill.Phase
+		dualWrite bool
+		want      backfill.Flags
+	}{
+		{backfill.PhasePending, false, backfill.Flags{WriteOld: true}},
+		{backfill.PhasePending, true, backfill.Flags{WriteOld: true, WriteNew: true}},
+		{backfill.PhaseRunning, true, backfill.Flags{WriteOld: true, WriteNew: true}},
+		{backfill.PhaseBackfilled, true, backfill.Flags{WriteOld: true, WriteNew: true}},
+		{backfill.PhaseCutOver, true, backfill.Flags{WriteOld: true, WriteNew: true, ReadNew: true}},
+		{backfill.PhaseCompleted, false, backfill.Flags{WriteNew: true, ReadNew: true}},
+	}
+	for _, tt := range tests {
+		if got := backfill.PhaseFlags(tt.phase, tt.dualWrite); got != tt.want {
+			t.Errorf("PhaseFlags(%s, %v) = %+v, want %+v", tt.phase, tt.dualWrite, got, tt.want)
+		}
+	}
+}
+
+func TestBackfillDefinitionsValidated(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	tests := map[string][]backfill.Definition{
+		"invalid backfill name": {{Name: "Invoice-Cents", Batch: noopBatch}},
+		"no batch function":     {{Name: "invoice_cents"}},
+		"incomplete check":      {{Name: "invoice_cents", Batch: noopBatch, Checks: []backfill.Check{{Name: "totals"}}}},
+		"duplicate backfill":    {{Name: "invoice_cents", Batch: noopBatch}, {Name: "invoice_cents", Batch: noopBatch}},
+	}
+	for want, defs := range tests {
+		_, err := backfill.NewService(nil, backfill.Config{Definitions: defs}, logger)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestBackfillServiceWithoutRepository(t *testing.T) {
+	s, err := backfill.NewService(nil, backfill.Config{
+		Definitions: []backfill.Definition{{Name: "invoice_cents", Batch: noopBatch}},
+	}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	defer s.Close()
+
+	if got := s.Flags("invoice_cents"); got != (backfill.Flags{WriteOld: true}) {
+		t.Errorf("Flags = %+v, want the old schema", got)
+	}
+	if _, err := s.Start(context.Background(), "unknown"); !errors.Is(err, backfill.ErrUnknownBackfill) {
+		t.Errorf("Start(unknown) error = %v", err)
+	}
+}
+
+func TestBackfillRegisteredDefinitions(t *testing.T) {
+	s, err := backfill.NewService(nil, backfill.Config{Definitions: backfill.Registered()}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("registered backfills are invalid: %v", err)
+	}
+	defer s.Close()
+
+	// The documents list keeps computing its badges until cutover
+	if got := s.Flags(backfill.DocumentListSummaries); got.ReadNew || got.WriteNew {
+		t.Errorf("Flags(%s) = %+v before the rollout", backfill.DocumentListSummaries, got)
+	}
+	for _, def := range backfill.Registered() {
+		if def.Name == backfill.DocumentListSummaries && len(def.Checks) == 0 {
+			t.Errorf("%s is cut over without verification", def.Name)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/backup"
+)
+
+func TestBackupCompareVerified(t *testing.T) {
+	expected := &backup.Manifest{SchemaVersion: "043", ObjectCounts: map[string]int64{"tenants": 3, "documents": 120, "storage_objects": 118}}
+	restored := &backup.Manifest{SchemaVersion: "043", ObjectCounts: map[string]int64{"tenants": 3, "documents": 120}}
+
+	v := backup.Compare(expected, restored)
+	if v.Status != backup.VerificationVerified {
+		t.Fatalf("expected verified, got %s (%+v)", v.Status, v)
+	}
+	if _, ok := v.Counts["storage_objects"]; ok {
+		t.Error("storage objects should only be compared when the restore reports them")
+	}
+}
+
+func TestBackupCompareDrift(t *testing.T) {
+	expected := &backup.Manifest{SchemaVersion: "043", ObjectCounts: map[string]int64{"tenants": 3, "documents": 120}}
+	restored := &backup.Manifest{SchemaVersion: "043", ObjectCounts: map[string]int64{"tenants": 3, "documents": 121}}
+
+	v := backup.Compare(expected, restored)
+	if v.Status != backup.VerificationDrift {
+		t.Fatalf("expected drift, got %s", v.Status)
+	}
+	if c := v.Counts["documents"]; c.Expected != 120 || c.Restored != 121 {
+		t.Errorf("unexpected comparison: %+v", c)
+	}
+}
+
+func TestBackupCompareFailed(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected *backup.Manifest
+		restored *backup.Manifest
+	}{
+		{
+			name:     "schema mismatch",
+			expected: &backup.Manifest{SchemaVersion: "043", ObjectCounts: map[string]int64{"tenants": 3}},
+			restored: &backup.Manifest{SchemaVersion: "041", ObjectCounts: map[string]int64{"tenants": 3}},
+		},
+		{
+			name:     "missing table",
+			expected: &backup.Manifest{SchemaVersion: "043", ObjectCounts: map[string]int64{"tenants": 3, "users": 9}},
+			restored: &backup.Manifest{SchemaVersion: "043", ObjectCounts: map[string]int64{"tenants": 3}},
+		},
+		{
+			name:     "empty restore",
+			expected: &backup.Manifest{SchemaVersion: "043", ObjectCounts: map[string]int64{"tenants": 3}},
+			restored: &backup.Manifest{SchemaVersion: "043", ObjectCounts: map[string]int64{"tenants": 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if v := backup.Compare(tt.expected, tt.restored); v.Status != backup.VerificationFailed {
+				t.Errorf("expected failed, got %s", v.Status)
+			}
+		})
+	}
+
+	v := backup.Compare(
+		&backup.Manifest{ObjectCounts: map[string]int64{"users": 1, "accounts": 1}},
+		&backup.Manifest{ObjectCounts: map[string]int64{}},
+	)
+	if strings.Join(v.Missing, ",") != "accounts,users" {
+		t.Errorf("expected sorted missing tables, got %v", v.Missing)
+	}
+}
+
+func writeHook(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hook.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatalf("write hook: %v", err)
+	}
+	return path
+}
+
+func TestRunHookResult(t *testing.T) {
+	hook := writeHook(t, `echo "dumping $BACKUP_ID" >&2
+echo "progress"
+echo '{"location":"s3://backups/'$BACKUP_ID'.tar","objects":42}'
+`)
+	result, err := backup.RunHook(context.Background(), hook, 5*time.Second, map[string]string{"BACKUP_ID": "b1"})
+	if err != nil {
+		t.Fatalf("RunHook failed: %v", err)
+	}
+	if result.Location != "s3://backups/b1.tar" || result.Objects != 42 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	hook = writeHook(t, `echo "/var/backups/db.dump"`)
+	result, err = backup.RunHook(context.Background(), hook, 5*time.Second, nil)
+	if err != nil {
+		t.Fatalf("RunHook failed: %v", err)
+	}
+	if result.Location != "/var/backups/db.dump" || result.Objects != 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestRunHookFailure(t *testing.T) {
+	hook := writeHook(t, `echo "pg_dump: connection refused" >&2
+exit 3
+`)
+	_, err := backup.RunHook(context.Background(), hook, 5*time.Second, nil)
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected error with stderr, got %v", err)
+	}
+
+	hook = writeHook(t, "sleep 5\n")
+	_, err = backup.RunHook(context.Background(), hook, 100*time.Millisecond, nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/branding"
+	"austrian-business-infrastructure/internal/invoice"
+	"austrian-business-infrastructure/internal/tenant"
+)
+
+func TestBrandingNormalizeColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "#1a2b3c", want: "#1A2B3C"},
+		{in: " #abc ", want: "#AABBCC"},
+		{in: "1a2b3c", wantErr: true},
+		{in: "#1a2b3", wantErr: true},
+		{in: "red", wantErr: true},
+		{in: "#12345g", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := branding.NormalizeColor(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, branding.ErrInvalidColor) {
+				t.Errorf("NormalizeColor(%q): expected ErrInvalidColor, got %v", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeColor(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestBrandingNormalizeDomain(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "Portal.Kanzlei-Huber.AT", want: "portal.kanzlei-huber.at"},
+		{in: "portal.kanzlei-huber.at.", want: "portal.kanzlei-huber.at"},
+		{in: "  ", want: ""},
+		{in: "https://portal.example.at", wantErr: true},
+		{in: "portal.example.at:8443", wantErr: true},
+		{in: "localhost", wantErr: true},
+		{in: "-portal.example.at", wantErr: true},
+		{in: "portal.example.at/sign", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := branding.NormalizeDomain(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, branding.ErrInvalidDomain) {
+				t.Errorf("NormalizeDomain(%q): expected ErrInvalidDomain, got %v", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeDomain(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestGenerateBrandedInvoicePDF(t *testing.T) {
+	inv := &invoice.Invoice{
+		InvoiceNumber:      "RE-2026-0042",
+		InvoiceType:        "380",
+		IssueDate:          time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Currency:           "EUR",
+		SellerName:         "Kanzlei Huber",
+		BuyerName:          "Muster GmbH",
+		TaxExclusiveAmount: 10000,
+		TaxAmount:          2000,
+		PayableAmount:      12000,
+	}
+	items := []*invoice.InvoiceItem{{LineNumber: 1, Description: "Buchhaltung", Quantity: 1, UnitPrice: 10000, LineTotal: 10000, TaxPercent: 20}}
+	logo := []byte{0xFF, 0xD8, 0xFF, 0xE0, 'l', 'o', 'g', 'o', 0xFF, 0xD9}
+
+	pdf, err := invoice.GenerateBrandedPDF(inv, items, nil, &invoice.PDFBrand{
+		Color:      "#1A5E20",
+		Logo:       logo,
+		LogoWidth:  280,
+		LogoHeight: 40,
+	})
+	if err != nil {
+		t.Fatalf("GenerateBrandedPDF failed: %v", err)
+	}
+	for _, want := range []string{
+		"0.102 0.369 0.125 RG",
+		"/XObject << /Logo ",
+		"/Width 280 /Height 40 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode /Length 10",
+		// Scaled to the 140pt wide logo box in the top right corner
+		"140.00 0 0 20.00 405.00 795.00 cm /Logo Do",
+	} {
+		if !bytes.Contains(pdf, []byte(want)) {
+			t.Errorf("expected %q in branded PDF", want)
+		}
+	}
+	if !bytes.Contains(pdf, logo) {
+		t.Error("expected the logo to be embedded as is")
+	}
+
+	plain, err := invoice.GeneratePDF(inv, items, nil)
+	if err != nil {
+		t.Fatalf("GeneratePDF failed: %v", err)
+	}
+	if bytes.Contains(plain, []byte("/XObject")) || bytes.Contains(plain, []byte(" RG ")) {
+		t.Error("expected the unbranded PDF without logo and color rule")
+	}
+}
+
+func TestCommandCertificateHook(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "calls")
+	hook := &branding.CommandCertificateHook{
+		Path:    writeHook(t, `echo "$CUSTOM_DOMAIN_ACTION $CUSTOM_DOMAIN $TENANT_ID" >> `+out),
+		Timeout: 5 * time.Second,
+	}
+	tenantID := uuid.New()
+	ctx := context.Background()
+	if err := hook.Provision(ctx, tenantID, "sign.kanzlei.at"); err != nil {
+		t.Fatalf("Provision failed: %v", err)
+	}
+	if err := hook.Remove(ctx, tenantID, "sign.kanzlei.at"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	calls, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read calls: %v", err)
+	}
+	want := "provision sign.kanzlei.at " + tenantID.String() + "\nremove sign.kanzlei.at " + tenantID.String() + "\n"
+	if string(calls) != want {
+		t.Errorf("hook calls = %q, want %q", calls, want)
+	}
+
+	failing := &branding.CommandCertificateHook{Path: writeHook(t, "exit 1\n"), Timeout: 5 * time.Second}
+	if err := failing.Provision(ctx, tenantID, "sign.kanzlei.at"); err == nil {
+		t.Error("expected failing hook to return an error")
+	}
+}
+
+func TestHostMiddlewarePlatformHosts(t *testing.T) {
+	// The platform's own host and IP addresses never need a domain lookup
+	service := branding.NewService(nil, "https://app.example.at")
+	handler := service.HostMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tenant.GetHostTenantID(r.Context()) != uuid.Nil {
+			t.Errorf("unexpected host tenant for %s", r.Host)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for _, host := range []string{"app.example.at", "APP.example.at:443", "10.0.0.5:8080", "[::1]:8080"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("host %s: status = %d, want %d", host, rec.Code, http.StatusNoContent)
+		}
+	}
+}
+
+func TestServesTenant(t *testing.T) {
+	own, other := uuid.New(), uuid.New()
+	ctx := context.Background()
+	if !tenant.ServesTenant(ctx, other) {
+		t.Error("platform hosts must serve every tenant")
+	}
+	ctx = tenant.WithHostTenantID(ctx, own)
+	if !tenant.ServesTenant(ctx, own) {
+		t.Error("custom domain must serve its own tenant")
+	}
+	if tenant.ServesTenant(ctx, other) {
+		t.Error("custom domain must not serve another tenant")
+	}
+}
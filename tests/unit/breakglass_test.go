@@ -0,0 +1,171 @@
+package unit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/auth"
+	"austrian-business-infrastructure/internal/breakglass"
+	"github.com/google/uuid"
+)
+
+func newBreakGlassJWTManager(t *testing.T) *auth.JWTManager {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ECDSA key: %v", err)
+	}
+	km := auth.NewECDSAKeyManager()
+	if err := km.LoadKey(privateKey); err != nil {
+		t.Fatalf("failed to load key: %v", err)
+	}
+	return auth.NewJWTManagerWithKeyManager(&auth.JWTConfig{
+		AccessTokenExpiry:  15 * time.Minute,
+		RefreshTokenExpiry: time.Hour,
+		Issuer:             "test-issuer",
+		UseES256:           true,
+	}, km)
+}
+
+type fakeBreakGlassVerifier struct {
+	err   error
+	calls int
+}
+
+func (v *fakeBreakGlassVerifier) VerifyBreakGlass(ctx context.Context, grantID, userID, tenantID string, r *http.Request) error {
+	v.calls++
+	return v.err
+}
+
+func TestBreakGlassTokenCarriesGrant(t *testing.T) {
+	m := newBreakGlassJWTManager(t)
+	expiry := time.Now().Add(30 * time.Minute)
+
+	if _, err := m.GenerateBreakGlassToken(&auth.UserInfo{UserID: "u", TenantID: "t", Role: "admin"}, expiry); err == nil {
+		t.Fatal("break-glass token without grant was issued")
+	}
+
+	token, err := m.GenerateBreakGlassToken(&auth.UserInfo{
+		UserID: "u", TenantID: "t", Role: "admin", BreakGlass: "grant-1",
+	}, expiry)
+	if err != nil {
+		t.Fatalf("GenerateBreakGlassToken: %v", err)
+	}
+	claims, err := m.ValidateAccessToken(token)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken: %v", err)
+	}
+	if claims.BreakGlass != "grant-1" {
+		t.Errorf("BreakGlass = %q, want grant-1", claims.BreakGlass)
+	}
+	if got := claims.ExpiresAt.Time; got.Sub(expiry) > time.Second || expiry.Sub(got) > time.Second {
+		t.Errorf("token expires at %v, want the grant expiry %v", got, expiry)
+	}
+}
+
+func TestRequireAuthVerifiesBreakGlassTokens(t *testing.T) {
+	m := newBreakGlassJWTManager(t)
+	token, err := m.GenerateBreakGlassToken(&auth.UserInfo{
+		UserID: uuid.NewString(), TenantID: uuid.NewString(), Role: "admin", BreakGlass: "grant-1",
+	}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GenerateBreakGlassToken: %v", err)
+	}
+
+	var grantID string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		grantID = api.GetBreakGlassGrantID(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+	serve := func(mw *auth.AuthMiddleware) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		mw.RequireAuth(next).ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	mw := auth.NewAuthMiddleware(m)
+	if code := serve(mw); code != http.StatusUnauthorized {
+		t.Errorf("without verifier: status %d, want 401", code)
+	}
+
+	ended := &fakeBreakGlassVerifier{err: errors.New("grant ended")}
+	mw.SetBreakGlassVerifier(ended)
+	if code := serve(mw); code != http.StatusUnauthorized {
+		t.Errorf("ended grant: status %d, want 401", code)
+	}
+
+	active := &fakeBreakGlassVerifier{}
+	mw.SetBreakGlassVerifier(active)
+	if code := serve(mw); code != http.StatusNoContent {
+		t.Fatalf("active grant: status %d, want 204", code)
+	}
+	if active.calls != 1 || grantID != "grant-1" {
+		t.Errorf("verifier calls = %d, grant in context = %q", active.calls, grantID)
+	}
+}
+
+func TestBreakGlassValidateReason(t *testing.T) {
+	if _, err := breakglass.ValidateReason("  incident  "); !errors.Is(err, breakglass.ErrReasonRequired) {
+		t.Errorf("short reason accepted: %v", err)
+	}
+	got, err := breakglass.ValidateReason("  INC-4711: Belege werden nicht importiert ")
+	if err != nil || got != "INC-4711: Belege werden nicht importiert" {
+		t.Errorf("ValidateReason = %q, %v", got, err)
+	}
+}
+
+func TestBreakGlassDuration(t *testing.T) {
+	s := breakglass.NewService(nil, nil, nil, breakglass.Config{MaxDuration: 2 * time.Hour})
+	if d, err := s.Duration(0); err != nil || d != time.Hour {
+		t.Errorf("default duration = %v, %v", d, err)
+	}
+	if d, err := s.Duration(90); err != nil || d != 90*time.Minute {
+		t.Errorf("Duration(90) = %v, %v", d, err)
+	}
+	for _, minutes := range []int{1, -10, 121} {
+		if _, err := s.Duration(minutes); !errors.Is(err, breakglass.ErrInvalidDuration) {
+			t.Errorf("Duration(%d) error = %v", minutes, err)
+		}
+	}
+}
+
+func TestBreakGlassGrantPreconditions(t *testing.T) {
+	superAdmin := uuid.New()
+	ownTenant := uuid.New()
+	s := breakglass.NewService(nil, nil, nil, breakglass.Config{SuperAdmins: []uuid.UUID{superAdmin}})
+	req := &breakglass.GrantRequest{
+		TenantID: uuid.New(),
+		Reason:   strings.Repeat("x", breakglass.MinReasonLength),
+	}
+
+	tests := []struct {
+		name   string
+		caller breakglass.Caller
+		req    *breakglass.GrantRequest
+		want   error
+	}{
+		{"not a super-admin", breakglass.Caller{UserID: uuid.New(), TenantID: ownTenant}, req, breakglass.ErrNotSuperAdmin},
+		{"nested", breakglass.Caller{UserID: superAdmin, TenantID: ownTenant, BreakGlass: true}, req, breakglass.ErrNested},
+		{"own tenant", breakglass.Caller{UserID: superAdmin, TenantID: req.TenantID}, req, breakglass.ErrOwnTenant},
+		{"no reason", breakglass.Caller{UserID: superAdmin, TenantID: ownTenant},
+			&breakglass.GrantRequest{TenantID: req.TenantID}, breakglass.ErrReasonRequired},
+		{"too long", breakglass.Caller{UserID: superAdmin, TenantID: ownTenant},
+			&breakglass.GrantRequest{TenantID: req.TenantID, Reason: req.Reason, DurationMinutes: 24 * 60}, breakglass.ErrInvalidDuration},
+	}
+	for _, tt := range tests {
+		if _, err := s.Grant(context.Background(), tt.caller, tt.req, nil); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/buak"
+	"austrian-business-infrastructure/internal/elda"
+)
+
+func TestBUAKZuschlaegeCalculation(t *testing.T) {
+	rates := elda.BUAKZuschlagssaetze{Urlaub: 1540, Abfertigung: 460, Schlechtwetter: 140, Ueberbrueckung: 60}
+
+	z := elda.CalculateBUAKZuschlaege(300000, rates) // 3.000,00 EUR
+	if z.Urlaub != 46200 || z.Abfertigung != 13800 || z.Schlechtwetter != 4200 || z.Ueberbrueckung != 1800 {
+		t.Errorf("unexpected Zuschläge: %+v", z)
+	}
+	if z.Total() != 66000 {
+		t.Errorf("expected total 66000, got %d", z.Total())
+	}
+}
+
+func TestBUAKBuildPositionen(t *testing.T) {
+	rates := elda.GetBUAKZuschlagssaetze(2025)
+	austritt := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	full := &elda.BUAKArbeitnehmer{
+		ID: uuid.New(), SVNummer: "1234150189", Vorname: "Max", Nachname: "Maurer",
+		Kategorie: elda.BUAKKategorieArbeiter, Stundenlohn: 2000, Wochenstunden: 39,
+		Eintrittsdatum: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	leaving := &elda.BUAKArbeitnehmer{
+		ID: uuid.New(), SVNummer: "1234150189", Vorname: "Eva", Nachname: "Polier",
+		Kategorie: elda.BUAKKategorieArbeiter, Stundenlohn: 2000, Wochenstunden: 39,
+		Eintrittsdatum: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Austrittsdatum: &austritt,
+	}
+	leased := &elda.BUAKArbeitnehmer{
+		ID: uuid.New(), SVNummer: "1234150189", Vorname: "Ali", Nachname: "Schalung",
+		Kategorie: elda.BUAKKategorieUeberlass, Stundenlohn: 1800, Wochenstunden: 39,
+		Eintrittsdatum: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Ueberlassung:   &elda.AUEGUeberlassung{BeschaeftigerName: "Bau AG", BeschaeftigerUID: "ATU12345678", Von: "2025-02-01"},
+	}
+	notYet := &elda.BUAKArbeitnehmer{
+		ID: uuid.New(), SVNummer: "1234150189", Vorname: "Neu", Nachname: "Eintritt",
+		Kategorie: elda.BUAKKategorieArbeiter, Stundenlohn: 2000, Wochenstunden: 39,
+		Eintrittsdatum: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	overrides := map[uuid.UUID]elda.BUAKStundenItem{
+		full.ID: {ArbeitnehmerID: full.ID, Stunden: 170},
+	}
+
+	positionen := buak.BuildPositionen([]*elda.BUAKArbeitnehmer{full, leaving, leased, notYet}, 2025, 3, overrides, rates)
+	if len(positionen) != 3 {
+		t.Fatalf("expected 3 positions, got %d", len(positionen))
+	}
+
+	if positionen[0].Stunden != 170 || positionen[0].Lohnsumme != 340000 {
+		t.Errorf("override not applied: %.2f h, %d cents", positionen[0].Stunden, positionen[0].Lohnsumme)
+	}
+	if positionen[0].Zuschlaege != elda.CalculateBUAKZuschlaege(340000, rates) {
+		t.Error("Zuschläge do not match Lohnsumme")
+	}
+
+	// 15 of 31 days with 39 h/week
+	if positionen[1].Stunden <= 0 || positionen[1].Stunden >= 39*buak.WeeksPerMonth/2 {
+		t.Errorf("expected prorated hours for partial month, got %.2f", positionen[1].Stunden)
+	}
+
+	if positionen[2].BeschaeftigerName != "Bau AG" || positionen[2].BeschaeftigerUID != "ATU12345678" {
+		t.Error("expected Beschäftiger on leased worker position")
+	}
+}
+
+func TestBUAKValidateArbeitnehmerUeberlassung(t *testing.T) {
+	a := &elda.BUAKArbeitnehmer{
+		SVNummer: "1234150189", Vorname: "Ali", Nachname: "Schalung",
+		Kategorie: elda.BUAKKategorieUeberlass, Stundenlohn: 1800,
+		Eintrittsdatum: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	if errs := buak.ValidateArbeitnehmer(a); len(errs) == 0 {
+		t.Error("expected error for leased worker without Überlassung")
+	}
+
+	a.Ueberlassung = &elda.AUEGUeberlassung{BeschaeftigerName: "Bau AG", Von: "2025-02-01", Bis: "2025-01-15"}
+	if errs := buak.ValidateArbeitnehmer(a); len(errs) != 1 {
+		t.Errorf("expected one error for bis before von, got %v", errs)
+	}
+
+	a.Ueberlassung.Bis = "2025-06-30"
+	if errs := buak.ValidateArbeitnehmer(a); len(errs) != 0 {
+		t.Errorf("expected valid worker, got %v", errs)
+	}
+}
+
+func TestBUAKDocumentXML(t *testing.T) {
+	m := &elda.BUAKMeldung{
+		Year: 2025, Month: 3, TotalArbeitnehmer: 1, TotalLohnsumme: 340000, TotalZuschlag: 73440,
+		Positionen: []*elda.BUAKPosition{{
+			SVNummer: "1234150189", Vorname: "Ali", Nachname: "Schalung", Kategorie: elda.BUAKKategorieUeberlass,
+			Stunden: 170, Stundenlohn: 2000, Lohnsumme: 340000,
+			Zuschlaege:        elda.CalculateBUAKZuschlaege(340000, elda.GetBUAKZuschlagssaetze(2025)),
+			BeschaeftigerName: "Bau AG", PositionIndex: 1,
+		}},
+	}
+
+	data, err := elda.MarshalBUAKDocument(buak.BuildDocument(m, "12345678"))
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	xmlStr := string(data)
+	for _, want := range []string{
+		"<DienstgeberNummer>12345678</DienstgeberNummer>",
+		"<SummeLohn>3400.00</SummeLohn>",
+		"<Lohnsumme>3400.00</Lohnsumme>",
+		"<BeschaeftigerName>Bau AG</BeschaeftigerName>",
+	} {
+		if !strings.Contains(xmlStr, want) {
+			t.Errorf("expected %s in XML", want)
+		}
+	}
+
+	if errs := buak.ValidateMeldung(m); len(errs) != 0 {
+		t.Errorf("expected valid Zuschlagsmeldung, got %v", errs)
+	}
+}
@@ -0,0 +1,92 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/calendarsync"
+	"austrian-business-infrastructure/internal/foerderplanung"
+	"austrian-business-infrastructure/internal/foerderung"
+	"github.com/google/uuid"
+)
+
+func TestCalendarSyncPlan(t *testing.T) {
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	item := func(title string) calendarsync.Item {
+		return calendarsync.Item{SourceType: calendarsync.SourceDeadline, SourceID: uuid.New(), Title: title, Date: day}
+	}
+	unchanged, changed, added := item("Antwortfrist: Bescheid"), item("Zahlungsfrist: Vorschreibung"), item("Einreichfrist: Antrag")
+	gone := calendarsync.Event{SourceType: calendarsync.SourceActionItem, SourceID: uuid.New(), EventID: "e3"}
+
+	events := []calendarsync.Event{
+		{SourceType: unchanged.SourceType, SourceID: unchanged.SourceID, EventID: "e1", Hash: calendarsync.Hash(unchanged)},
+		{SourceType: changed.SourceType, SourceID: changed.SourceID, EventID: "e2", Hash: calendarsync.Hash(changed)},
+		gone,
+	}
+	changed.Date = day.AddDate(0, 0, 7)
+
+	create, update, remove := calendarsync.Plan([]calendarsync.Item{unchanged, changed, added}, events)
+	if len(create) != 1 || create[0].SourceID != added.SourceID {
+		t.Errorf("create = %+v", create)
+	}
+	if len(update) != 1 || update[0].EventID != "e2" || !update[0].Item.Date.Equal(changed.Date) {
+		t.Errorf("update = %+v", update)
+	}
+	if len(remove) != 1 || remove[0].EventID != "e3" {
+		t.Errorf("remove = %+v", remove)
+	}
+}
+
+func TestCalendarSyncItems(t *testing.T) {
+	doc := uuid.New()
+	d := calendarsync.DeadlineItem(calendarsync.Deadline{
+		ID: uuid.New(), DocumentID: doc, Type: "appeal", DocumentTitle: "Einkommensteuerbescheid 2025",
+		Rule: "1 Monat ab Zustellung", Date: time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC),
+	}, "https://app.example.com/")
+	if d.Title != "Rechtsmittelfrist: Einkommensteuerbescheid 2025" || d.URL != "https://app.example.com/documents/"+doc.String() {
+		t.Errorf("deadline item = %+v", d)
+	}
+
+	a := calendarsync.ActionItemItem(calendarsync.ActionItem{ID: uuid.New(), Title: "Beleg nachreichen"}, "https://app.example.com")
+	if a.Title != "Aufgabe: Beleg nachreichen" || a.URL != "" || a.SourceType != calendarsync.SourceActionItem {
+		t.Errorf("action item = %+v", a)
+	}
+}
+
+func TestCalendarSyncBusyPerWeek(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC) }
+	busy := calendarsync.BusyPerWeek([]calendarsync.Interval{
+		{Start: at(2, 9), End: at(2, 12)},  // Monday 3 h
+		{Start: at(2, 11), End: at(2, 13)}, // overlaps: 1 h more
+		{Start: at(4, 0), End: at(6, 0)},   // two all-day events: 8 h each
+		{Start: at(8, 22), End: at(9, 2)},  // Sunday night into Monday of the next week
+		{Start: at(10, 9), End: at(10, 9)}, // empty
+	})
+	if busy["2026-03-02"] != 22 || busy["2026-03-09"] != 2 || len(busy) != 2 {
+		t.Errorf("busy = %v", busy)
+	}
+}
+
+func TestFoerderplanungCalendarBusyHours(t *testing.T) {
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	efforts := foerderplanung.DefaultEfforts()
+	anna := uuid.New()
+	advisors := map[uuid.UUID]foerderplanung.Advisor{anna: {
+		UserID: anna, Name: "Anna", WeeklyHours: 10,
+		BusyHours: map[string]float64{"2026-03-02": 36, "2026-03-09": 20},
+	}}
+	d := foerderplanung.Deadline{
+		Source: foerderplanung.SourceAntrag, FoerderungType: string(foerderung.TypeBeratung),
+		Status: foerderung.AntragStatusPlanned, Deadline: time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC), AdvisorID: &anna,
+	}
+	foerderplanung.Estimate(&d, efforts)
+
+	cal := foerderplanung.BuildCalendar([]foerderplanung.Deadline{d}, advisors, efforts, from, 2)
+	first, second := cal.Weeks[0].Advisors[0], cal.Weeks[1].Advisors[0]
+	if first.CapacityHours != 4 || first.BusyHours != 36 || first.OverAllocated {
+		t.Errorf("first week = %+v", first)
+	}
+	if second.CapacityHours != 10 || second.BusyHours != 20 {
+		t.Errorf("second week = %+v", second)
+	}
+}
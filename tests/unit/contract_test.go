@@ -0,0 +1,156 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/contract"
+)
+
+func contractDay(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestContractParseTerms(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		start   time.Time
+		initial string
+		renews  bool
+		renewal string
+		notice  string
+	}{
+		{
+			name: "months and renewal",
+			text: "§ 3 Vertragsdauer\nDer Vertrag beginnt am 1.3.2025 und hat eine Laufzeit von 24 Monaten. Er verlängert sich " +
+				"jeweils um ein weiteres Jahr, wenn er nicht unter Einhaltung einer Kündigungsfrist von drei Monaten zum Ende der Laufzeit gekündigt wird.",
+			start: contractDay(2025, 3, 1), initial: "P24M", renews: true, renewal: "P1Y", notice: "P3M",
+		},
+		{
+			name: "adjectives",
+			text: "Wartungsvertrag, Vertragsbeginn: 01.01.2026. Zwölfmonatige Mindestlaufzeit. Danach verlängert sich der Vertrag " +
+				"stillschweigend. Kündigung mit einer 6-wöchigen Kündigungsfrist.",
+			start: contractDay(2026, 1, 1), initial: "P12M", renews: true, notice: "P6W",
+		},
+		{
+			name:    "notice before end, no renewal",
+			text:    "Der Mietvertrag wird mit Wirksam ab 15.04.2026 auf die Laufzeit von drei Jahren abgeschlossen; Kündigung bis spätestens 30 Tage vor Ablauf.",
+			start:   contractDay(2026, 4, 15),
+			initial: "P3Y", notice: "P30D",
+		},
+	}
+	for _, tt := range tests {
+		terms := contract.ParseTerms(tt.text)
+		if terms.StartDate == nil || !terms.StartDate.Equal(tt.start) {
+			t.Errorf("%s: start = %v, want %v", tt.name, terms.StartDate, tt.start)
+		}
+		if terms.InitialTerm.String() != tt.initial || terms.AutoRenewal != tt.renews ||
+			terms.RenewalTerm.String() != tt.renewal || terms.NoticePeriod.String() != tt.notice {
+			t.Errorf("%s: terms = %s renews=%v by %s, notice %s", tt.name,
+				terms.InitialTerm, terms.AutoRenewal, terms.RenewalTerm, terms.NoticePeriod)
+		}
+	}
+
+	if terms := contract.ParseTerms("Sehr geehrte Damen und Herren, anbei die Rechnung vom 3.2.2026."); !terms.Empty() {
+		t.Errorf("unexpected terms %+v", terms)
+	}
+}
+
+func TestContractSchedule(t *testing.T) {
+	start := contractDay(2025, 3, 1)
+	terms := contract.Terms{
+		StartDate:    &start,
+		InitialTerm:  contract.Period{Count: 24, Unit: contract.UnitMonths},
+		AutoRenewal:  true,
+		RenewalTerm:  contract.Period{Count: 1, Unit: contract.UnitYears},
+		NoticePeriod: contract.Period{Count: 3, Unit: contract.UnitMonths},
+	}
+	tests := []struct {
+		today    time.Time
+		termEnd  time.Time
+		cancelBy time.Time
+	}{
+		// First term ends 28.02.2027; notice must be given by 30.11.2026
+		{contractDay(2026, 10, 16), contractDay(2027, 2, 28), contractDay(2026, 11, 30)},
+		{contractDay(2026, 11, 30), contractDay(2027, 2, 28), contractDay(2026, 11, 30)},
+		// Once the day has passed, the contract has renewed to 29.02.2028
+		{contractDay(2026, 12, 1), contractDay(2028, 2, 29), contractDay(2027, 11, 30)},
+		{contractDay(2029, 1, 1), contractDay(2030, 2, 28), contractDay(2029, 11, 30)},
+	}
+	for _, tt := range tests {
+		termEnd, cancelBy, status := terms.Schedule(tt.today)
+		if status != contract.StatusActive || termEnd == nil || cancelBy == nil ||
+			!termEnd.Equal(tt.termEnd) || !cancelBy.Equal(tt.cancelBy) {
+			t.Errorf("on %s: %v, %v, %s; want %s, %s", tt.today.Format("2006-01-02"),
+				termEnd, cancelBy, status, tt.termEnd.Format("2006-01-02"), tt.cancelBy.Format("2006-01-02"))
+		}
+	}
+
+	// Without renewal the contract just ends; there is nothing to cancel
+	terms.AutoRenewal = false
+	if termEnd, cancelBy, status := terms.Schedule(contractDay(2026, 12, 1)); status != contract.StatusActive || cancelBy != nil || !termEnd.Equal(contractDay(2027, 2, 28)) {
+		t.Errorf("fixed term = %v, %v, %s", termEnd, cancelBy, status)
+	}
+	if _, _, status := terms.Schedule(contractDay(2027, 3, 1)); status != contract.StatusExpired {
+		t.Errorf("fixed term after its end = %s, want expired", status)
+	}
+
+	// A renewal clause without a period renews by the initial term
+	terms = contract.Terms{StartDate: &start, InitialTerm: contract.Period{Count: 6, Unit: contract.UnitMonths}, AutoRenewal: true}
+	if termEnd, cancelBy, _ := terms.Schedule(contractDay(2025, 9, 1)); !termEnd.Equal(contractDay(2026, 2, 28)) || !cancelBy.Equal(*termEnd) {
+		t.Errorf("renewal by initial term = %v, %v", termEnd, cancelBy)
+	}
+
+	if termEnd, cancelBy, _ := (contract.Terms{AutoRenewal: true}).Schedule(contractDay(2026, 1, 1)); termEnd != nil || cancelBy != nil {
+		t.Errorf("without a term = %v, %v", termEnd, cancelBy)
+	}
+}
+
+func TestContractCancelBy(t *testing.T) {
+	tests := []struct {
+		termEnd time.Time
+		notice  contract.Period
+		want    time.Time
+	}{
+		{contractDay(2026, 12, 31), contract.Period{Count: 3, Unit: contract.UnitMonths}, contractDay(2026, 9, 30)},
+		{contractDay(2026, 12, 31), contract.Period{Count: 30, Unit: contract.UnitDays}, contractDay(2026, 12, 1)},
+		{contractDay(2026, 6, 30), contract.Period{Count: 4, Unit: contract.UnitWeeks}, contractDay(2026, 6, 2)},
+		{contractDay(2026, 6, 30), contract.Period{}, contractDay(2026, 6, 30)},
+	}
+	for _, tt := range tests {
+		if got := contract.CancelBy(tt.termEnd, tt.notice); !got.Equal(tt.want) {
+			t.Errorf("CancelBy(%s, %s) = %s, want %s", tt.termEnd.Format("2006-01-02"), tt.notice,
+				got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestContractPeriodJSON(t *testing.T) {
+	var input contract.UpdateInput
+	if err := json.Unmarshal([]byte(`{"notice_period":"P3M","renewal_term":""}`), &input); err != nil {
+		t.Fatal(err)
+	}
+	if input.NoticePeriod == nil || *input.NoticePeriod != (contract.Period{Count: 3, Unit: contract.UnitMonths}) {
+		t.Errorf("notice_period = %v", input.NoticePeriod)
+	}
+	if input.RenewalTerm == nil || !input.RenewalTerm.IsZero() || input.InitialTerm != nil {
+		t.Errorf("renewal_term = %v, initial_term = %v", input.RenewalTerm, input.InitialTerm)
+	}
+
+	for _, s := range []string{"3M", "P0M", "P1H", "P1Y2M"} {
+		if _, err := contract.ParsePeriod(s); !errors.Is(err, contract.ErrInvalidPeriod) {
+			t.Errorf("ParsePeriod(%q) = %v, want ErrInvalidPeriod", s, err)
+		}
+	}
+
+	data, err := json.Marshal(contract.Terms{NoticePeriod: contract.Period{Count: 30, Unit: contract.UnitDays}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"initial_term":"","auto_renewal":false,"renewal_term":"","notice_period":"P30D"}`; string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,200 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/customfield"
+	"austrian-business-infrastructure/internal/invoice"
+	"austrian-business-infrastructure/internal/permission"
+
+	"github.com/google/uuid"
+)
+
+func customFieldDefs() []*customfield.Definition {
+	return []*customfield.Definition{
+		{Key: "internal_ref", Label: "Interne Referenz", FieldType: customfield.TypeText, Required: true},
+		{Key: "cost", Label: "Kosten", FieldType: customfield.TypeNumber},
+		{Key: "deadline", Label: "Frist", FieldType: customfield.TypeDate},
+		{Key: "branch", Label: "Filiale", FieldType: customfield.TypeEnum, Options: []string{"W01", "G02"}},
+	}
+}
+
+func TestCustomFieldValidateDefinition(t *testing.T) {
+	valid := &customfield.Definition{
+		EntityType: customfield.EntityInvoice, Key: "branch_code", Label: " Filiale ",
+		FieldType: customfield.TypeEnum, Options: []string{" W01", "G02 "},
+	}
+	if err := customfield.ValidateDefinition(valid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if valid.Label != "Filiale" || valid.Options[0] != "W01" || valid.Options[1] != "G02" {
+		t.Errorf("expected trimmed label and options, got %q %v", valid.Label, valid.Options)
+	}
+
+	tests := []struct {
+		name string
+		def  customfield.Definition
+		want error
+	}{
+		{"unknown entity", customfield.Definition{EntityType: "document", Key: "a", Label: "A", FieldType: customfield.TypeText}, customfield.ErrInvalidEntityType},
+		{"uppercase key", customfield.Definition{EntityType: customfield.EntityClient, Key: "Ref", Label: "A", FieldType: customfield.TypeText}, customfield.ErrInvalidKey},
+		{"key with dot", customfield.Definition{EntityType: customfield.EntityClient, Key: "a.b", Label: "A", FieldType: customfield.TypeText}, customfield.ErrInvalidKey},
+		{"empty label", customfield.Definition{EntityType: customfield.EntityAntrag, Key: "a", Label: "  ", FieldType: customfield.TypeText}, customfield.ErrMissingLabel},
+		{"unknown type", customfield.Definition{EntityType: customfield.EntityAntrag, Key: "a", Label: "A", FieldType: "bool"}, customfield.ErrInvalidFieldType},
+		{"enum without options", customfield.Definition{EntityType: customfield.EntityInvoice, Key: "a", Label: "A", FieldType: customfield.TypeEnum}, customfield.ErrInvalidOptions},
+		{"duplicate options", customfield.Definition{EntityType: customfield.EntityInvoice, Key: "a", Label: "A", FieldType: customfield.TypeEnum, Options: []string{"x", " x"}}, customfield.ErrInvalidOptions},
+		{"options on text", customfield.Definition{EntityType: customfield.EntityInvoice, Key: "a", Label: "A", FieldType: customfield.TypeText, Options: []string{"x"}}, customfield.ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := customfield.ValidateDefinition(&tt.def); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCustomFieldValidateValues(t *testing.T) {
+	defs := customFieldDefs()
+
+	values, err := customfield.Validate(defs, map[string]interface{}{
+		"internal_ref": " PRJ-7 ",
+		"cost":         float64(12.5),
+		"deadline":     "2025-06-30",
+		"branch":       "W01",
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values["internal_ref"] != "PRJ-7" || values["cost"] != 12.5 || values["deadline"] != "2025-06-30" || values["branch"] != "W01" {
+		t.Errorf("unexpected values: %v", values)
+	}
+
+	// Updates merge; null and empty strings remove a field
+	merged, err := customfield.Validate(defs, map[string]interface{}{"cost": nil, "branch": ""}, values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := merged["cost"]; ok {
+		t.Error("expected cost to be removed")
+	}
+	if _, ok := merged["branch"]; ok {
+		t.Error("expected branch to be removed")
+	}
+	if merged["internal_ref"] != "PRJ-7" {
+		t.Error("expected untouched values to be kept")
+	}
+	if values["cost"] != 12.5 {
+		t.Error("expected existing values not to be modified")
+	}
+
+	invalid := []struct {
+		name  string
+		input map[string]interface{}
+	}{
+		{"unknown key", map[string]interface{}{"internal_ref": "x", "colour": "red"}},
+		{"missing required", map[string]interface{}{"cost": float64(1)}},
+		{"removing required", map[string]interface{}{"internal_ref": nil}},
+		{"number as string", map[string]interface{}{"internal_ref": "x", "cost": "12"}},
+		{"text as number", map[string]interface{}{"internal_ref": float64(7)}},
+		{"bad date", map[string]interface{}{"internal_ref": "x", "deadline": "30.06.2025"}},
+		{"unknown option", map[string]interface{}{"internal_ref": "x", "branch": "L03"}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := customfield.Values(nil)
+			if tt.name == "removing required" {
+				existing = values
+			}
+			_, err := customfield.Validate(defs, tt.input, existing)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !customfield.IsInvalid(err) {
+				t.Errorf("expected a validation error, got %v", err)
+			}
+		})
+	}
+
+	// Without definitions, only empty input is accepted
+	if v, err := customfield.Validate(nil, nil, nil); err != nil || v == nil || len(v) != 0 {
+		t.Errorf("expected empty values, got %v, %v", v, err)
+	}
+}
+
+func TestCustomFieldFilters(t *testing.T) {
+	defs := customFieldDefs()
+	q := url.Values{
+		"status":    {"draft"},
+		"cf.cost":   {"10"},
+		"cf.branch": {"W01"},
+	}
+
+	params := customfield.ParseFilterParams(q)
+	if len(params) != 2 || params["cost"] != "10" || params["branch"] != "W01" {
+		t.Fatalf("unexpected params: %v", params)
+	}
+
+	filters, err := customfield.BuildFilters(defs, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := string(customfield.FilterDocument(filters)); got != `{"branch":"W01","cost":10}` {
+		t.Errorf("unexpected filter document: %s", got)
+	}
+	if customfield.FilterDocument(nil) != nil {
+		t.Error("expected no document without filters")
+	}
+
+	for _, bad := range []map[string]string{
+		{"colour": "red"},
+		{"cost": "ten"},
+		{"branch": "L03"},
+		{"deadline": ""},
+	} {
+		if _, err := customfield.BuildFilters(defs, bad); !customfield.IsInvalid(err) {
+			t.Errorf("%v: expected a validation error, got %v", bad, err)
+		}
+	}
+}
+
+func TestCustomFieldCSV(t *testing.T) {
+	defs := customFieldDefs()
+	values := customfield.Values{"internal_ref": "=HYPERLINK(\"x\")", "cost": float64(1250), "branch": "G02"}
+
+	header := customfield.CSVHeader(defs)
+	if len(header) != 4 || header[0] != "Interne Referenz" || header[3] != "Filiale" {
+		t.Errorf("unexpected header: %v", header)
+	}
+
+	cells := customfield.CSVValues(defs, values)
+	want := []string{"'=HYPERLINK(\"x\")", "1250", "", "G02"}
+	for i := range want {
+		if cells[i] != want[i] {
+			t.Errorf("cell %d: expected %q, got %q", i, want[i], cells[i])
+		}
+	}
+}
+
+func TestInvoiceListRejectsUnknownCustomFieldFilter(t *testing.T) {
+	router := api.NewRouter(nil)
+	passthrough := func(next http.Handler) http.Handler { return next }
+	invoice.NewHandler(invoice.NewService(nil)).RegisterRoutes(router, passthrough,
+		func(permission.Permission) func(http.Handler) http.Handler { return passthrough })
+
+	for _, path := range []string{"/api/v1/invoices?cf.colour=red", "/api/v1/invoices/export?cf.colour=red"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		ctx := context.WithValue(req.Context(), api.TenantIDKey, uuid.New().String())
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req.WithContext(ctx))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+	}
+}
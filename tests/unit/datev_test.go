@@ -0,0 +1,164 @@
+package unit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+
+	"austrian-business-infrastructure/internal/datev"
+	"austrian-business-infrastructure/internal/uva"
+)
+
+func TestDATEVBuchen(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC) }
+	paid := func(t time.Time) *time.Time { return &t }
+
+	invoices := []*uva.TaxableInvoice{
+		{
+			InvoiceNumber: "RE-1", IssueDate: day(1, 15), Gross: 17506,
+			Lines: []uva.TaxLine{
+				{TaxCategory: "S", TaxPercent: 20, Net: 10001},
+				{TaxCategory: "S", TaxPercent: 10, Net: 5005},
+			},
+			Receipts: []uva.Receipt{{Date: day(2, 1), Amount: 17506}},
+		},
+		{
+			InvoiceNumber: "GS-1", IssueDate: day(2, 10), CreditNote: true, Gross: 1200,
+			Lines: []uva.TaxLine{{TaxCategory: "S", TaxPercent: 20, Net: 1000}},
+		},
+	}
+	inputs := []*uva.InputInvoice{
+		{
+			SupplierName: "Alt", InvoiceNumber: "ER-0", InvoiceDate: time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+			Kind: uva.InputDomestic, PaidDate: paid(day(1, 10)),
+			Lines: []uva.InputInvoiceLine{{TaxPercent: 20, Net: 100, Tax: 20}},
+		},
+		{
+			SupplierName: "Lieferant", InvoiceNumber: "ER-1", InvoiceDate: day(1, 20),
+			Kind: uva.InputDomestic, PaidDate: paid(day(2, 5)),
+			Lines: []uva.InputInvoiceLine{{TaxPercent: 20, Net: 5000, Tax: 1000}},
+		},
+		{
+			SupplierName: "EU GmbH", InvoiceNumber: "ER-2", InvoiceDate: day(3, 1),
+			Kind:  uva.InputIGAcquisition,
+			Lines: []uva.InputInvoiceLine{{TaxPercent: 20, Net: 2000}},
+		},
+	}
+
+	got := datev.Buchen(invoices, inputs, day(1, 1), day(3, 31))
+
+	want := []struct {
+		datum        time.Time
+		betrag       int64
+		soll, haben  string
+		buSchluessel string
+	}{
+		{day(1, 10), 120, datev.EKRVerbindlichkeiten, datev.EKRBank, ""},
+		{day(1, 15), 10001, datev.EKRForderungen, datev.EKRErloese20, "40"},
+		{day(1, 15), 2000, datev.EKRForderungen, datev.EKRUmsatzsteuer, ""},
+		{day(1, 15), 5005, datev.EKRForderungen, datev.EKRErloese10, "40"},
+		{day(1, 15), 500, datev.EKRForderungen, datev.EKRUmsatzsteuer, ""}, // 501 less the rounding difference to the gross
+		{day(1, 20), 5000, datev.EKRWareneinkauf20, datev.EKRVerbindlichkeiten, "40"},
+		{day(1, 20), 1000, datev.EKRVorsteuer, datev.EKRVerbindlichkeiten, ""},
+		{day(2, 1), 17506, datev.EKRBank, datev.EKRForderungen, ""},
+		{day(2, 5), 6000, datev.EKRVerbindlichkeiten, datev.EKRBank, ""},
+		{day(2, 10), 1000, datev.EKRErloese20, datev.EKRForderungen, "40"},
+		{day(2, 10), 200, datev.EKRUmsatzsteuer, datev.EKRForderungen, ""},
+		{day(3, 1), 2000, datev.EKRWareneinkaufIGE, datev.EKRVerbindlichkeiten, "40"},
+		{day(3, 1), 400, datev.EKRVorsteuerIGE, datev.EKRUmsatzsteuerIGE, ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d postings, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		b := got[i]
+		if !b.Datum.Equal(w.datum) || b.Betrag != w.betrag || b.Soll != w.soll || b.Haben != w.haben || b.BUSchluessel != w.buSchluessel {
+			t.Errorf("posting %d = %s %d %s/%s %q, want %s %d %s/%s %q", i, b.Datum.Format("2006-01-02"), b.Betrag, b.Soll, b.Haben, b.BUSchluessel,
+				w.datum.Format("2006-01-02"), w.betrag, w.soll, w.haben, w.buSchluessel)
+		}
+	}
+}
+
+func TestDATEVZuordnungen(t *testing.T) {
+	s := &datev.Settings{Kontenrahmen: datev.SKR04, Sachkontenlaenge: 5, Konten: map[string]string{datev.EKRErloese20: "44010"}}
+
+	konten := map[string]datev.Zuordnung{}
+	for _, z := range datev.Zuordnungen(s) {
+		konten[z.EKRKonto] = z
+	}
+	if z := konten[datev.EKRForderungen]; z.Konto != "12000" || z.Custom {
+		t.Errorf("Forderungen = %+v, want default 12000", z)
+	}
+	if z := konten[datev.EKRErloese20]; z.Konto != "44010" || !z.Custom {
+		t.Errorf("Erlöse 20 %% = %+v, want custom 44010", z)
+	}
+
+	mapped := datev.Zuordnen([]datev.Buchung{{Soll: datev.EKRForderungen, Haben: datev.EKRErloese20, Betrag: 100}}, s)
+	if b := mapped[0]; b.Soll != "12000" || b.Haben != "44010" || b.SollEKR != datev.EKRForderungen || b.HabenEKR != datev.EKRErloese20 {
+		t.Errorf("mapped = %+v", b)
+	}
+
+	s.Kontenrahmen, s.Sachkontenlaenge, s.Konten = datev.SKR03, 4, nil
+	for _, z := range datev.Zuordnungen(s) {
+		if z.EKRKonto == datev.EKRBank && z.Konto != "1200" {
+			t.Errorf("SKR03 Bank = %s, want 1200", z.Konto)
+		}
+	}
+}
+
+func TestDATEVWriteEXTF(t *testing.T) {
+	s := &datev.Settings{BeraterNr: 29098, MandantNr: 55003, Kontenrahmen: datev.SKR04, WJBeginnMonat: 1, Sachkontenlaenge: 4}
+	stapel := &datev.Stapel{
+		Von: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Bis: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+		Buchungen: []datev.Buchung{{
+			Datum: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), Betrag: 123456, Soll: "1200", Haben: "4400",
+			BUSchluessel: "40", Beleg: "RE 2026/001", Text: "Rechnung für Kunde",
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := datev.WriteEXTF(&buf, s, stapel, time.Date(2026, 4, 2, 9, 30, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WriteEXTF: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte{0xFC}) {
+		t.Error("ü is not Windows-1252 encoded")
+	}
+	content, err := charmap.Windows1252.NewDecoder().String(buf.String())
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, "\r\n"), "\r\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), content)
+	}
+	header := strings.Split(lines[0], ";")
+	if len(header) != 31 {
+		t.Errorf("header has %d fields, want 31", len(header))
+	}
+	if !strings.HasPrefix(lines[0], `"EXTF";700;21;"Buchungsstapel";13;20260402093000000;`) {
+		t.Errorf("header = %s", lines[0])
+	}
+	if header[10] != "29098" || header[11] != "55003" || header[12] != "20260101" || header[13] != "4" || header[26] != `"04"` {
+		t.Errorf("header = %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], `"Umsatz (ohne Soll/Haben-Kz)";"Soll/Haben-Kennzeichen"`) {
+		t.Errorf("columns = %s", lines[1])
+	}
+	if want := `1234,56;"S";"EUR";;;"";1200;4400;"40";1501;"RE-2026/001";"";;"Rechnung für Kunde"`; lines[2] != want {
+		t.Errorf("posting = %s, want %s", lines[2], want)
+	}
+}
+
+func TestDATEVBelegfeld(t *testing.T) {
+	if got := datev.Belegfeld("RE#2026/ä_1"); got != "RE-2026/-_1" {
+		t.Errorf("Belegfeld = %q", got)
+	}
+	if got := datev.Belegfeld(strings.Repeat("A", 40)); len(got) != 36 {
+		t.Errorf("Belegfeld length = %d, want 36", len(got))
+	}
+}
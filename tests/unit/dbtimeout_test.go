@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/pkg/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestModuleStatementTimeouts(t *testing.T) {
+	timeouts, err := database.ParseModuleTimeouts("firmenbuch=2s, reports=1m,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timeouts["firmenbuch"] != 2*time.Second || timeouts["reports"] != time.Minute {
+		t.Errorf("unexpected timeouts: %v", timeouts)
+	}
+	for _, invalid := range []string{"firmenbuch", "=1s", "analysis=soon", "analysis=-1s"} {
+		if _, err := database.ParseModuleTimeouts(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+
+	database.SetStatementTimeouts(20*time.Second, timeouts)
+	defer database.SetStatementTimeouts(database.DefaultStatementTimeout, nil)
+
+	if d := database.StatementTimeout("firmenbuch"); d != 2*time.Second {
+		t.Errorf("firmenbuch: expected override 2s, got %s", d)
+	}
+	if d := database.StatementTimeout("analysis"); d != database.DefaultModuleTimeouts["analysis"] {
+		t.Errorf("analysis: expected built-in default, got %s", d)
+	}
+	if d := database.StatementTimeout("invoice"); d != 20*time.Second {
+		t.Errorf("invoice: expected default 20s, got %s", d)
+	}
+
+	module := database.NewModule("firmenbuch")
+	ctx, cancel := module.WithTimeout(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > 2*time.Second {
+		t.Errorf("expected a deadline within 2s, got %v (%v)", deadline, ok)
+	}
+	if database.ModuleFrom(ctx) != "firmenbuch" {
+		t.Errorf("expected module firmenbuch, got %q", database.ModuleFrom(ctx))
+	}
+
+	// An earlier deadline of the caller is kept
+	parent, cancelParent := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancelParent()
+	ctx, cancel = module.WithTimeout(parent)
+	defer cancel()
+	if d, _ := ctx.Deadline(); time.Until(d) > 100*time.Millisecond {
+		t.Errorf("caller deadline was extended to %v", d)
+	}
+
+	// A zero timeout disables the deadline
+	database.SetStatementTimeouts(0, map[string]time.Duration{"firmenbuch": 0})
+	ctx, cancel = module.WithTimeout(context.Background())
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("expected no deadline for a disabled timeout")
+	}
+}
+
+func TestSlowQueryLoggerRedactsParameters(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	tracer := &database.SlowQueryLogger{Threshold: time.Nanosecond, Logger: logger}
+
+	module := database.NewModule("slowquery-test")
+	ctx, cancel := module.WithTimeout(context.Background())
+	defer cancel()
+
+	ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{
+		SQL:  "SELECT *\n\t\tFROM users\n\t\tWHERE email = $1 AND tenant_id = $2",
+		Args: []any{"max.mustermann@example.at", 42},
+	})
+	time.Sleep(time.Millisecond)
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+
+	out := buf.String()
+	if !strings.Contains(out, `"msg":"slow query"`) || !strings.Contains(out, `"module":"slowquery-test"`) {
+		t.Errorf("missing slow query log: %s", out)
+	}
+	if strings.Contains(out, "mustermann") || strings.Contains(out, ",42]") {
+		t.Errorf("bound parameters leaked into the log: %s", out)
+	}
+	if !strings.Contains(out, "string(25)") || !strings.Contains(out, "SELECT * FROM users WHERE") {
+		t.Errorf("expected redacted args and compacted SQL: %s", out)
+	}
+
+	// A cancelled statement counts as a timeout of the module
+	buf.Reset()
+	ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT pg_sleep(60)"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: &pgconn.PgError{Code: "57014"}})
+	if !strings.Contains(buf.String(), `"msg":"statement timeout"`) {
+		t.Errorf("missing timeout log: %s", buf.String())
+	}
+
+	m := database.ModuleMetricsSnapshot()["slowquery-test"]
+	if m.SlowQueries != 1 || m.StatementTimeouts != 1 {
+		t.Errorf("unexpected module metrics: %+v", m)
+	}
+
+	if !database.IsTimeout(context.DeadlineExceeded) || database.IsTimeout(pgx.ErrNoRows) {
+		t.Error("IsTimeout misclassifies errors")
+	}
+	if got := database.RedactArgs([]any{nil, []byte("abc"), time.Time{}}); strings.Join(got, ",") != "nil,[]byte(3),time.Time" {
+		t.Errorf("unexpected redaction: %v", got)
+	}
+}
@@ -0,0 +1,139 @@
+package unit
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/account"
+	"austrian-business-infrastructure/internal/demo"
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/mbgm"
+	"austrian-business-infrastructure/internal/sepa"
+)
+
+func demoOptions(seed int64) demo.Options {
+	opts := demo.Options{Seed: seed, Now: time.Date(2025, time.June, 15, 10, 0, 0, 0, time.UTC)}
+	if err := opts.Normalize(); err != nil {
+		panic(err)
+	}
+	return opts
+}
+
+func TestDemoGenerateIsDeterministic(t *testing.T) {
+	a := demo.Generate(demoOptions(42))
+	b := demo.Generate(demoOptions(42))
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("same seed must produce the same dataset")
+	}
+
+	c := demo.Generate(demoOptions(43))
+	if c.Company.Name == a.Company.Name && c.Employees[0].SVNummer == a.Employees[0].SVNummer {
+		t.Error("different seeds should produce different data")
+	}
+
+	if len(a.Employees) != demo.DefaultEmployees || len(a.Documents) != demo.DefaultDocuments ||
+		len(a.Invoices) != demo.DefaultInvoices || len(a.Applications) != demo.DefaultApplications {
+		t.Errorf("unexpected sizes: %d employees, %d documents, %d invoices, %d applications",
+			len(a.Employees), len(a.Documents), len(a.Invoices), len(a.Applications))
+	}
+	if !regexp.MustCompile(`^[a-z0-9][a-z0-9-]*[a-z0-9]$`).MatchString(a.Slug) {
+		t.Errorf("slug %q does not match the tenant slug format", a.Slug)
+	}
+
+	named := demoOptions(7)
+	named.Name = "Müller & Söhne Bau GmbH"
+	if got := demo.Generate(named).Slug; got != "demo-mueller-soehne-bau-gmbh-7" {
+		t.Errorf("slug = %q", got)
+	}
+}
+
+func TestDemoGeneratedIdentifiersAreValid(t *testing.T) {
+	data := demo.Generate(demoOptions(3))
+
+	if err := account.ValidateTID(data.TID); err != nil {
+		t.Errorf("TID %s: %v", data.TID, err)
+	}
+	if err := account.ValidateDienstgebernummer(data.Dienstgebernummer); err != nil {
+		t.Errorf("Dienstgebernummer %s: %v", data.Dienstgebernummer, err)
+	}
+	if r := fonws.ValidateUIDFormat(data.Company.UID); !r.Valid {
+		t.Errorf("UID %s: %s", data.Company.UID, r.Error)
+	}
+	if err := sepa.ValidateIBAN(data.Company.IBAN); err != nil {
+		t.Errorf("IBAN %s: %v", data.Company.IBAN, err)
+	}
+
+	geringfuegig := 0
+	for _, e := range data.Employees {
+		if err := mbgm.ValidateSVNummer(e.SVNummer); err != nil {
+			t.Errorf("SV-Nummer %s: %v", e.SVNummer, err)
+		}
+		if e.Beitragsgruppe == "D3" {
+			geringfuegig++
+			if !elda.IsGeringfuegig(float64(e.BruttoCents)/100, 2025) {
+				t.Errorf("geringfügig employee earns %d cents", e.BruttoCents)
+			}
+		}
+		if !e.Eintritt.Before(time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Eintritt %s after the reference month", e.Eintritt)
+		}
+	}
+	if geringfuegig == 0 {
+		t.Error("expected a geringfügig employee")
+	}
+}
+
+func TestDemoInvoicesAndDocuments(t *testing.T) {
+	data := demo.Generate(demoOptions(5))
+
+	numbers := map[string]bool{}
+	for _, inv := range data.Invoices {
+		if numbers[inv.Number] {
+			t.Errorf("duplicate invoice number %s", inv.Number)
+		}
+		numbers[inv.Number] = true
+
+		var net, tax int64
+		for _, item := range inv.Items {
+			if item.Gross != item.Net+item.Tax {
+				t.Errorf("%s: item gross %d != %d + %d", inv.Number, item.Gross, item.Net, item.Tax)
+			}
+			net += item.Net
+			tax += item.Tax
+		}
+		if inv.Net != net || inv.Tax != tax || inv.Gross != net+tax {
+			t.Errorf("%s: totals %d/%d/%d do not match items", inv.Number, inv.Net, inv.Tax, inv.Gross)
+		}
+	}
+	if last := data.Invoices[len(data.Invoices)-1]; last.Status != "draft" {
+		t.Errorf("latest invoice should be a draft, got %s", last.Status)
+	}
+
+	deadlines := 0
+	for _, doc := range data.Documents {
+		if doc.Text == "" || doc.Summary == "" {
+			t.Errorf("%s: missing text or summary", doc.ExternalID)
+		}
+		if doc.Deadline != nil {
+			deadlines++
+			if doc.Action == "" || !doc.Deadline.After(doc.ReceivedAt) {
+				t.Errorf("%s: invalid deadline", doc.ExternalID)
+			}
+		}
+	}
+	if deadlines == 0 {
+		t.Error("expected documents with deadlines")
+	}
+
+	tooMany := demo.Options{Invoices: demo.MaxItems + 1}
+	if err := tooMany.Normalize(); err == nil {
+		t.Error("expected limit error")
+	}
+	weak := demo.Options{OwnerPassword: "short"}
+	if err := weak.Normalize(); err == nil {
+		t.Error("expected password policy error")
+	}
+}
@@ -0,0 +1,113 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/dienstnehmer"
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/eldameldung"
+)
+
+func TestCheckSVNummer(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	birth := time.Date(1989, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	check := dienstnehmer.CheckSVNummer("1234 150189", &birth, now)
+	if !check.Valid || check.SVNummer != "1234150189" || len(check.Warnings) != 0 {
+		t.Fatalf("expected a valid SV-Nummer without warnings, got %+v", check)
+	}
+	if check.Geburtsdatum == nil || *check.Geburtsdatum != "1989-01-15" {
+		t.Errorf("expected the encoded birth date, got %v", check.Geburtsdatum)
+	}
+
+	other := birth.AddDate(0, 0, 1)
+	if check := dienstnehmer.CheckSVNummer("1234150189", &other, now); !check.Valid || len(check.Warnings) != 1 {
+		t.Errorf("expected a birth date mismatch to be a warning, got %+v", check)
+	}
+	if check := dienstnehmer.CheckSVNummer("1234321386", nil, now); !check.Valid || check.Geburtsdatum != nil || len(check.Warnings) != 1 {
+		t.Errorf("expected a warning for the encoded date 32.13.86, got %+v", check)
+	}
+	if check := dienstnehmer.CheckSVNummer("1234150188", nil, now); check.Valid || check.Error == "" {
+		t.Errorf("expected a wrong check digit to be invalid, got %+v", check)
+	}
+}
+
+func TestPrefillMeldungRequest(t *testing.T) {
+	birth := time.Date(1989, 1, 15, 0, 0, 0, 0, time.UTC)
+	d := &dienstnehmer.Dienstnehmer{
+		SVNummer:     "1234150189",
+		Vorname:      "Maria",
+		Nachname:     "Muster",
+		Geburtsdatum: &birth,
+		Geschlecht:   "W",
+		Adresse:      &elda.DienstnehmerAdresse{Strasse: "Hauptstraße", Hausnummer: "1", PLZ: "1010", Ort: "Wien"},
+	}
+
+	req := &elda.MeldungCreateRequest{Type: elda.MeldungTypeAnmeldung, Nachname: "Huber"}
+	if err := dienstnehmer.PrefillRequest(req, d); err != nil {
+		t.Fatal(err)
+	}
+	if req.SVNummer != "1234150189" || req.Vorname != "Maria" || req.Geburtsdatum != "1989-01-15" || req.Geschlecht != "W" {
+		t.Errorf("expected the employee data to be prefilled, got %+v", req)
+	}
+	if req.Nachname != "Huber" {
+		t.Errorf("expected the request's Nachname to take precedence, got %q", req.Nachname)
+	}
+	if req.Adresse == nil || req.Adresse == d.Adresse || req.Adresse.Ort != "Wien" {
+		t.Errorf("expected a copy of the address, got %+v", req.Adresse)
+	}
+
+	req = &elda.MeldungCreateRequest{SVNummer: "1237010180"}
+	var ve *eldameldung.ValidationError
+	if err := dienstnehmer.PrefillRequest(req, d); !errors.As(err, &ve) {
+		t.Errorf("expected a validation error for a different SV-Nummer, got %v", err)
+	}
+}
+
+func TestDeriveBeschaeftigungen(t *testing.T) {
+	date := func(y int, m time.Month, d int) *time.Time {
+		t := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	accountA, accountB, accountC := uuid.New(), uuid.New(), uuid.New()
+	anmeldungA := &elda.ELDAMeldung{ID: uuid.New(), ELDAAccountID: accountA, Type: elda.MeldungTypeAnmeldung, Status: elda.MeldungStatusAccepted,
+		Eintrittsdatum: date(2024, 1, 15), Beschaeftigung: &elda.ExtendedBeschaeftigung{Art: "vollzeit", Taetigkeit: "Buchhalterin"},
+		Arbeitszeit: &elda.ExtendedArbeitszeit{WochenStunden: 38.5}}
+	meldungen := []*elda.ELDAMeldung{
+		// Listed newest first like the repository does
+		{ID: uuid.New(), ELDAAccountID: accountB, Type: elda.MeldungTypeAbmeldung, Status: elda.MeldungStatusRejected, Austrittsdatum: date(2025, 3, 31)},
+		{ID: uuid.New(), ELDAAccountID: accountB, Type: elda.MeldungTypeAnmeldung, Status: elda.MeldungStatusSubmitted, Eintrittsdatum: date(2025, 2, 1)},
+		{ID: uuid.New(), ELDAAccountID: accountA, Type: elda.MeldungTypeAbmeldung, Status: elda.MeldungStatusAccepted, Austrittsdatum: date(2024, 12, 31), AustrittGrund: elda.ELDAGrundKuendigung},
+		{ID: uuid.New(), ELDAAccountID: accountA, Type: elda.MeldungTypeAenderung, Status: elda.MeldungStatusAccepted, AenderungDatum: date(2024, 6, 1),
+			Beschaeftigung: &elda.ExtendedBeschaeftigung{Art: "teilzeit"}, Arbeitszeit: &elda.ExtendedArbeitszeit{WochenStunden: 20}},
+		{ID: uuid.New(), ELDAAccountID: accountA, Type: elda.MeldungTypeAnmeldung, Status: elda.MeldungStatusDraft, Eintrittsdatum: date(2026, 1, 1)},
+		anmeldungA,
+		{ID: uuid.New(), ELDAAccountID: accountC, Type: elda.MeldungTypeAbmeldung, Status: elda.MeldungStatusAccepted, Austrittsdatum: date(2023, 6, 30)},
+	}
+
+	employments := dienstnehmer.DeriveBeschaeftigungen(meldungen)
+	if len(employments) != 3 {
+		t.Fatalf("expected 3 employments, got %d", len(employments))
+	}
+
+	current, past, unknownStart := employments[0], employments[1], employments[2]
+	if current.ELDAAccountID != accountB || current.Austrittsdatum != nil {
+		t.Errorf("expected the open employment first, got %+v", current)
+	}
+	if past.ELDAAccountID != accountA || past.AnmeldungID == nil || *past.AnmeldungID != anmeldungA.ID || past.AbmeldungID == nil {
+		t.Errorf("expected the closed employment with account A, got %+v", past)
+	}
+	if past.Art != "teilzeit" || past.Taetigkeit != "Buchhalterin" || past.WochenStunden != 20 || past.Aenderungen != 1 {
+		t.Errorf("expected the Änderungsmeldung to update the employment, got %+v", past)
+	}
+	if !past.Austrittsdatum.Equal(*date(2024, 12, 31)) || past.AustrittGrund != elda.ELDAGrundKuendigung {
+		t.Errorf("unexpected end of employment %v %q", past.Austrittsdatum, past.AustrittGrund)
+	}
+	if unknownStart.ELDAAccountID != accountC || unknownStart.Eintrittsdatum != nil || unknownStart.Austrittsdatum == nil {
+		t.Errorf("expected an Abmeldung without Anmeldung to end an employment of unknown start, got %+v", unknownStart)
+	}
+}
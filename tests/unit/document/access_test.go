@@ -0,0 +1,45 @@
+package document_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/document"
+)
+
+func TestAccessOverrideRequiresAdmin(t *testing.T) {
+	h := document.NewHandler(nil)
+	var reached bool
+	next := h.AccessOverride(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+
+	tests := []struct {
+		name, target, role string
+		want               int
+		reached            bool
+	}{
+		{"no override", "/api/v1/documents", "member", http.StatusOK, true},
+		{"member override", "/api/v1/documents?override=true", "member", http.StatusForbidden, false},
+		// Without an audit logger the override cannot be recorded
+		{"admin without audit", "/api/v1/documents?override=true", "admin", http.StatusForbidden, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached = false
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req = req.WithContext(context.WithValue(req.Context(), api.UserRoleKey, tt.role))
+			rec := httptest.NewRecorder()
+			next.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if reached != tt.reached {
+				t.Errorf("reached = %v, want %v", reached, tt.reached)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package document_test
+
+import (
+	"errors"
+	"testing"
+
+	"austrian-business-infrastructure/internal/document"
+)
+
+func TestValidateRelation(t *testing.T) {
+	tests := []struct {
+		relation, target string
+		valid            bool
+	}{
+		{document.RelationReferences, document.EntityInvoice, true},
+		{document.RelationAssessmentOf, document.EntityUVASubmission, true},
+		{document.RelationReminderFor, document.EntityInvoice, true},
+		{document.RelationSupersedes, document.EntityDocument, true},
+		{document.RelationReminderFor, document.EntityUVASubmission, false},
+		{document.RelationSupersedes, document.EntityInvoice, false},
+		{document.RelationSignedVersionOf, document.EntityDocument, false},
+		{document.RelationAttachmentOf, document.EntityDocument, false},
+		{"cites", document.EntityDocument, false},
+	}
+	for _, tt := range tests {
+		err := document.ValidateRelation(tt.relation, tt.target)
+		if tt.valid && err != nil {
+			t.Errorf("%s -> %s: unexpected error %v", tt.relation, tt.target, err)
+		}
+		if !tt.valid && !errors.Is(err, document.ErrInvalidRelation) {
+			t.Errorf("%s -> %s: error = %v, want ErrInvalidRelation", tt.relation, tt.target, err)
+		}
+	}
+}
+
+func TestCleanFolder(t *testing.T) {
+	tests := map[string]string{
+		"/Steuern//2026/ UVA /": "Steuern/2026/UVA",
+		"Mahnungen":             "Mahnungen",
+		" / ":                   "",
+	}
+	for in, want := range tests {
+		if got := document.CleanFolder(in); got != want {
+			t.Errorf("CleanFolder(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package document_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/document"
+)
+
+type staticRegions map[uuid.UUID]string
+
+func (r staticRegions) StorageRegion(ctx context.Context, tenantID uuid.UUID) (string, error) {
+	return r[tenantID], nil
+}
+
+func TestRegionalStorage(t *testing.T) {
+	ctx := context.Background()
+	swiss, austrian := uuid.New(), uuid.New()
+
+	cfg := &document.StorageConfig{
+		Type:      document.StorageTypeLocal,
+		LocalPath: t.TempDir(),
+		Region:    "at",
+		Regions: map[string]*document.StorageConfig{
+			"ch": {Type: document.StorageTypeLocal, LocalPath: t.TempDir(), Region: "ch"},
+		},
+	}
+	if names := cfg.RegionNames(); len(names) != 2 || names[0] != "at" || names[1] != "ch" {
+		t.Fatalf("RegionNames() = %v, want [at ch]", names)
+	}
+
+	resolver := staticRegions{swiss: "ch"}
+	storage, err := document.NewRegionalStorage(cfg, resolver)
+	if err != nil {
+		t.Fatalf("NewRegionalStorage() error = %v", err)
+	}
+
+	swissInfo, err := storage.Store(ctx, swiss.String(), uuid.NewString(), "bescheid.pdf", strings.NewReader("swiss"), "application/pdf")
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if !strings.HasPrefix(swissInfo.Path, "ch:"+swiss.String()+"/") {
+		t.Errorf("Store() path = %q, want it in region ch", swissInfo.Path)
+	}
+	austrianInfo, err := storage.Store(ctx, austrian.String(), uuid.NewString(), "bescheid.pdf", strings.NewReader("austrian"), "application/pdf")
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if !strings.HasPrefix(austrianInfo.Path, austrian.String()+"/") {
+		t.Errorf("Store() path = %q, want it in the default region without a prefix", austrianInfo.Path)
+	}
+
+	// The document stays readable in its region after the tenant moved
+	delete(resolver, swiss)
+	content, info, err := storage.Get(ctx, swissInfo.Path)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, _ := io.ReadAll(content)
+	content.Close()
+	if string(data) != "swiss" || info.Path != swissInfo.Path {
+		t.Errorf("Get() = %q at %q, want the swiss document", data, info.Path)
+	}
+
+	// The default region does not have it
+	defaultOnly, err := document.NewStorage(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := defaultOnly.Exists(ctx, strings.TrimPrefix(swissInfo.Path, "ch:")); exists {
+		t.Error("swiss document was stored in the default region")
+	}
+
+	listed, err := storage.List(ctx, swiss.String()+"/")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(listed) != 1 || listed[0].Path != swissInfo.Path {
+		t.Errorf("List() = %+v, want the swiss document with its region", listed)
+	}
+	if usage, err := storage.GetUsage(ctx, swiss.String()); err != nil || usage != 5 {
+		t.Errorf("GetUsage() = %d, %v, want 5", usage, err)
+	}
+
+	if _, _, err := storage.Get(ctx, "us:"+swiss.String()+"/x"); !errors.Is(err, document.ErrUnknownRegion) {
+		t.Error("Get() from an unconfigured region succeeded")
+	}
+}
+
+// fakeBlobService is an in-memory Azure Blob service for one container
+type fakeBlobService struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+	types map[string]string
+}
+
+func (f *fakeBlobService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey devstoreaccount1:") || r.Header.Get("x-ms-date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := strings.TrimPrefix(r.URL.Path, "/devstoreaccount1/documents")
+	name = strings.TrimPrefix(name, "/")
+	switch {
+	case r.URL.Query().Get("restype") == "container" && r.URL.Query().Get("comp") == "list":
+		var b strings.Builder
+		b.WriteString("<EnumerationResults><Blobs>")
+		for blob, data := range f.blobs {
+			if strings.HasPrefix(blob, r.URL.Query().Get("prefix")) {
+				b.WriteString("<Blob><Name>" + blob + "</Name><Properties><Content-Length>" +
+					strconv.Itoa(len(data)) + "</Content-Length></Properties></Blob>")
+			}
+		}
+		b.WriteString("</Blobs><NextMarker/></EnumerationResults>")
+		io.WriteString(w, b.String())
+	case r.URL.Query().Get("restype") == "container":
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut:
+		if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		f.blobs[name] = data
+		f.types[name] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", `"0x1"`)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := f.blobs[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", f.types[name])
+		w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(f.blobs, name)
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func TestAzureStorage(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeBlobService{blobs: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	storage, err := document.NewAzureStorage(&document.StorageConfig{
+		AzureAccountName: "devstoreaccount1",
+		AzureAccountKey:  base64.StdEncoding.EncodeToString([]byte("secret")),
+		AzureContainer:   "documents",
+		AzureEndpoint:    server.URL + "/devstoreaccount1",
+	})
+	if err != nil {
+		t.Fatalf("NewAzureStorage() error = %v", err)
+	}
+
+	tenantID := uuid.NewString()
+	info, err := storage.Store(ctx, tenantID, uuid.NewString(), "uva.xml", bytes.NewReader([]byte("<uva/>")), "application/xml")
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if info.Size != 6 || info.ETag != "0x1" {
+		t.Errorf("Store() = %+v", info)
+	}
+
+	content, got, err := storage.Get(ctx, info.Path)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, _ := io.ReadAll(content)
+	content.Close()
+	if string(data) != "<uva/>" || got.ContentType != "application/xml" {
+		t.Errorf("Get() = %q %q", data, got.ContentType)
+	}
+
+	if usage, err := storage.GetUsage(ctx, tenantID); err != nil || usage != 6 {
+		t.Errorf("GetUsage() = %d, %v, want 6", usage, err)
+	}
+
+	signed, err := storage.GetSignedURL(ctx, info.Path, time.Hour)
+	if err != nil {
+		t.Fatalf("GetSignedURL() error = %v", err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if !strings.HasSuffix(u.Path, "/devstoreaccount1/documents/"+info.Path) || q.Get("sp") != "r" || q.Get("sr") != "b" || q.Get("sig") == "" || q.Has("spr") {
+		t.Errorf("GetSignedURL() = %s", signed)
+	}
+
+	if err := storage.Delete(ctx, info.Path); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if exists, err := storage.Exists(ctx, info.Path); err != nil || exists {
+		t.Errorf("Exists() after Delete() = %v, %v", exists, err)
+	}
+	if _, _, err := storage.Get(ctx, info.Path); !errors.Is(err, document.ErrStorageNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrStorageNotFound", err)
+	}
+}
@@ -0,0 +1,182 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/dryrun"
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/eldameldung"
+	"austrian-business-infrastructure/internal/uva"
+	"austrian-business-infrastructure/internal/zm"
+	"github.com/google/uuid"
+)
+
+// findingCodes maps the codes of a report to their severity
+func findingCodes(r *dryrun.Report) map[string]string {
+	codes := make(map[string]string)
+	for _, f := range r.Findings {
+		codes[f.Code] = f.Severity
+	}
+	return codes
+}
+
+func TestDryRunReportFinish(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	r := dryrun.NewReport(dryrun.KindUVA)
+	r.Warn("period_open", "", "open")
+	if !r.Finish(now).Valid || !r.CheckedAt.Equal(now) {
+		t.Errorf("report with warnings only: valid = %v, checked_at = %v", r.Valid, r.CheckedAt)
+	}
+
+	r.Error("negative_amount", "data.kz022", "negative")
+	if r.Finish(now).Valid {
+		t.Error("report with an error is valid")
+	}
+}
+
+func TestUVADryRunCheckInput(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	month := 9
+	input := &uva.CreateSubmissionInput{
+		PeriodYear:  2026,
+		PeriodMonth: &month,
+		PeriodType:  uva.PeriodTypeMonthly,
+		Data:        uva.UVAData{KZ000: 100000, KZ017: 100000, KZ060: 5000},
+	}
+
+	report := dryrun.NewReport(dryrun.KindUVA)
+	uva.CheckInput(report, input, now)
+	if len(report.Findings) != 0 {
+		t.Fatalf("findings = %+v, want none", report.Findings)
+	}
+	if input.Data.KZ095 != 15000 {
+		t.Errorf("KZ095 = %d, want 15000", input.Data.KZ095)
+	}
+
+	// A negative Kennzahl, a Zahllast that does not add up and a missed deadline
+	month = 6
+	input.Data.KZ022 = -100
+	input.Data.KZ095 = 1
+	report = dryrun.NewReport(dryrun.KindUVA)
+	uva.CheckInput(report, input, now)
+	codes := findingCodes(report)
+	for code, severity := range map[string]string{
+		"negative_amount": dryrun.SeverityError,
+		"kz095_mismatch":  dryrun.SeverityWarning,
+		"deadline_passed": dryrun.SeverityWarning,
+	} {
+		if codes[code] != severity {
+			t.Errorf("%s = %q, want %q", code, codes[code], severity)
+		}
+	}
+
+	// The current month has not ended yet
+	month = 10
+	input.Data = uva.UVAData{}
+	report = dryrun.NewReport(dryrun.KindUVA)
+	uva.CheckInput(report, input, now)
+	if codes := findingCodes(report); codes["period_open"] != dryrun.SeverityWarning || codes["deadline_passed"] != "" {
+		t.Errorf("findings = %+v, want only period_open", report.Findings)
+	}
+}
+
+func TestZMDryRunCheckInput(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	input := &zm.CreateSubmissionInput{
+		PeriodYear:    2026,
+		PeriodQuarter: 2,
+		Entries: []zm.Entry{
+			{PartnerUID: "DE123456789", CountryCode: "DE", DeliveryType: zm.DeliveryTypeGoods, Amount: 500000},
+			{PartnerUID: "FR12345678901", CountryCode: "FR", DeliveryType: zm.DeliveryTypeServices, Amount: 120000},
+		},
+	}
+
+	report := dryrun.NewReport(dryrun.KindZM)
+	zm.CheckInput(report, input, now)
+	if len(report.Findings) != 0 {
+		t.Fatalf("findings = %+v, want none", report.Findings)
+	}
+
+	input.Entries = append(input.Entries,
+		zm.Entry{PartnerUID: "DE123456789", CountryCode: "DE", DeliveryType: zm.DeliveryTypeGoods, Amount: 50},
+		zm.Entry{PartnerUID: "IT12345678901", CountryCode: "IT", DeliveryType: "X", Amount: 1000},
+	)
+	report = dryrun.NewReport(dryrun.KindZM)
+	zm.CheckInput(report, input, now)
+
+	fields := make(map[string]string)
+	for _, f := range report.Findings {
+		fields[f.Code] = f.Field
+	}
+	if fields["duplicate_entry"] != "entries[2]" || fields["amount_rounds_to_zero"] != "entries[2].amount" {
+		t.Errorf("findings = %+v", report.Findings)
+	}
+	if fields["invalid_entry"] != "entries[3]" || report.Finish(now).Valid {
+		t.Errorf("invalid delivery type not reported: %+v", report.Findings)
+	}
+
+	// Both errors of an empty ZM in an open quarter are reported
+	report = dryrun.NewReport(dryrun.KindZM)
+	zm.CheckInput(report, &zm.CreateSubmissionInput{PeriodYear: 2026, PeriodQuarter: 5}, now)
+	codes := findingCodes(report)
+	if codes["invalid_quarter"] != dryrun.SeverityError || codes["no_entries"] != dryrun.SeverityError {
+		t.Errorf("findings = %+v", report.Findings)
+	}
+}
+
+func TestELDADryRunCheckRequest(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	req := &elda.MeldungCreateRequest{
+		ELDAAccountID:  uuid.New(),
+		Type:           elda.MeldungTypeAnmeldung,
+		SVNummer:       "1234150189",
+		Vorname:        "Max",
+		Nachname:       "Mustermann",
+		Geburtsdatum:   "1989-01-15",
+		Eintrittsdatum: "2026-11-01",
+	}
+
+	report := dryrun.NewReport(dryrun.KindELDAMeldung)
+	eldameldung.CheckRequest(report, req, now)
+	if len(report.Findings) != 0 {
+		t.Fatalf("findings = %+v, want none", report.Findings)
+	}
+
+	req.Eintrittsdatum = "2026-10-01"
+	req.Geburtsdatum = "1990-05-20"
+	req.Vorname = ""
+	report = dryrun.NewReport(dryrun.KindELDAMeldung)
+	eldameldung.CheckRequest(report, req, now)
+	codes := findingCodes(report)
+	for code, severity := range map[string]string{
+		"invalid":             dryrun.SeverityError,
+		"late_anmeldung":      dryrun.SeverityWarning,
+		"birth_date_mismatch": dryrun.SeverityWarning,
+	} {
+		if codes[code] != severity {
+			t.Errorf("%s = %q, want %q", code, codes[code], severity)
+		}
+	}
+	if report.Findings[0].Field != "vorname" {
+		t.Errorf("field = %q, want vorname", report.Findings[0].Field)
+	}
+
+	abmeldung := &elda.MeldungCreateRequest{
+		ELDAAccountID:  uuid.New(),
+		Type:           elda.MeldungTypeAbmeldung,
+		SVNummer:       "1234150189",
+		Vorname:        "Max",
+		Nachname:       "Mustermann",
+		Eintrittsdatum: "2026-10-01",
+		Austrittsdatum: "2026-09-30",
+		AustrittGrund:  elda.ELDAGrundKuendigung,
+	}
+	report = dryrun.NewReport(dryrun.KindELDAMeldung)
+	eldameldung.CheckRequest(report, abmeldung, now)
+	codes = findingCodes(report)
+	if codes["late_abmeldung"] != dryrun.SeverityWarning || codes["invalid_period"] != dryrun.SeverityError {
+		t.Errorf("findings = %+v", report.Findings)
+	}
+}
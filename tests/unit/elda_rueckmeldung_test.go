@@ -0,0 +1,80 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/fakes"
+	"austrian-business-infrastructure/internal/mail"
+)
+
+func TestELDAQueryProtokoll(t *testing.T) {
+	ctx := context.Background()
+	fehler := elda.ProtokollFehler{Code: "E123", Text: "SV-Nummer ungültig", Field: "SVNummer"}
+	fake := fakes.NewELDA().
+		Protokoll("P-1", elda.ProtokollInBearbeitung).
+		Protokoll("P-1", elda.ProtokollAngenommen).
+		Protokoll("P-2", elda.ProtokollAbgelehnt, fehler)
+
+	rm, err := elda.QueryProtokoll(ctx, fake, "123456", "P-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rm.Status != elda.MeldungStatusSubmitted || rm.ProcessedAt != nil {
+		t.Errorf("expected a meldung in processing to stay submitted, got %+v", rm)
+	}
+
+	rm, err = elda.QueryProtokoll(ctx, fake, "123456", "P-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rm.Status != elda.MeldungStatusAccepted {
+		t.Errorf("status = %s, want accepted", rm.Status)
+	}
+	// 09:30 in Vienna is 07:30 UTC in summer time
+	if want := time.Date(2026, 10, 16, 7, 30, 0, 0, time.UTC); rm.ProcessedAt == nil || !rm.ProcessedAt.Equal(want) {
+		t.Errorf("processed at = %v, want %v", rm.ProcessedAt, want)
+	}
+
+	rm, err = elda.QueryProtokoll(ctx, fake, "123456", "P-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rm.Status != elda.MeldungStatusRejected || len(rm.Errors) != 1 || rm.Errors[0] != fehler {
+		t.Errorf("expected a rejection with the Protokoll's error, got %+v", rm)
+	}
+}
+
+func TestELDAMeldungRejectedMail(t *testing.T) {
+	renderer, err := mail.NewRenderer("Austrian Business Platform")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, err := renderer.Render(mail.TemplateELDAMeldungRejected, email.ELDAMeldungRejectedParams{
+		RecipientName:   "Anna Huber",
+		MeldungType:     "Anmeldung",
+		Dienstnehmer:    "Max Mustermann",
+		Protokollnummer: "P-2",
+		SubmittedAt:     "15.10.2026",
+		Errors: []email.ELDAProtokollError{
+			{Code: "E123", Text: "SV-Nummer ungültig", Field: "SVNummer"},
+			{Code: "E200", Text: "Beschäftigungsbeginn fehlt"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered.Subject != "ELDA-Meldung abgelehnt: Anmeldung Max Mustermann" {
+		t.Errorf("unexpected subject %q", rendered.Subject)
+	}
+	for _, want := range []string{"Anna Huber", "vom 15.10.2026", "Protokoll P-2", "- E123: SV-Nummer ungültig (Feld SVNummer)", "- E200: Beschäftigungsbeginn fehlt\n"} {
+		if !strings.Contains(rendered.Text, want) {
+			t.Errorf("expected %q in text:\n%s", want, rendered.Text)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package unit
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/endpoint"
+	"austrian-business-infrastructure/internal/fonws"
+)
+
+func TestNewIntegration(t *testing.T) {
+	integration, err := endpoint.NewIntegration(endpoint.FinanzOnline, []string{
+		"blue=https://finanzonline.bmf.gv.at/fonws/ws",
+		"green=https://fonws-neu.bmf.gv.at/fonws/ws",
+	}, "green")
+	if err != nil {
+		t.Fatalf("NewIntegration failed: %v", err)
+	}
+	if integration.Default != "green" || len(integration.Sets) != 2 {
+		t.Errorf("unexpected integration: %+v", integration)
+	}
+
+	invalid := map[string][]string{
+		"missing url":   {"blue="},
+		"no scheme":     {"blue=finanzonline.bmf.gv.at"},
+		"duplicate set": {"blue=https://a", "blue=https://b"},
+		"no sets":       nil,
+	}
+	for name, sets := range invalid {
+		if _, err := endpoint.NewIntegration(endpoint.FinanzOnline, sets, ""); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := endpoint.NewIntegration(endpoint.FinanzOnline, []string{"blue=https://a"}, "green"); !errors.Is(err, endpoint.ErrUnknownSet) {
+		t.Errorf("expected ErrUnknownSet, got %v", err)
+	}
+}
+
+func TestParseSwitchoversAndWindows(t *testing.T) {
+	switchovers, err := endpoint.ParseSwitchovers([]string{"finanzonline:green@2026-11-02T06:00:00+01:00"})
+	if err != nil {
+		t.Fatalf("ParseSwitchovers failed: %v", err)
+	}
+	if s := switchovers[0]; s.Integration != "finanzonline" || s.Set != "green" || !s.At.Equal(time.Date(2026, 11, 2, 5, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected switchover: %+v", s)
+	}
+
+	windows, err := endpoint.ParseWindows([]string{"elda@2026-11-01T22:00:00+01:00/2026-11-02T06:00:00+01:00"})
+	if err != nil {
+		t.Fatalf("ParseWindows failed: %v", err)
+	}
+	if w := windows[0]; w.Integration != "elda" || w.EndsAt.Sub(w.StartsAt) != 8*time.Hour {
+		t.Errorf("unexpected window: %+v", w)
+	}
+
+	for _, invalid := range []string{"finanzonline@2026-11-02T06:00:00Z", "finanzonline:green@tomorrow"} {
+		if _, err := endpoint.ParseSwitchovers([]string{invalid}); err == nil {
+			t.Errorf("expected error for switchover %q", invalid)
+		}
+	}
+	for _, invalid := range []string{"elda@2026-11-02T06:00:00Z", "elda@2026-11-02T06:00:00Z/2026-11-01T06:00:00Z"} {
+		if _, err := endpoint.ParseWindows([]string{invalid}); err == nil {
+			t.Errorf("expected error for window %q", invalid)
+		}
+	}
+}
+
+func newEndpointRegistry(t *testing.T, switchovers []endpoint.Switchover, windows []endpoint.Window) *endpoint.Registry {
+	t.Helper()
+	integration, err := endpoint.NewIntegration(endpoint.FinanzOnline, []string{"blue=https://blue.example", "green=https://green.example"}, "")
+	if err != nil {
+		t.Fatalf("NewIntegration failed: %v", err)
+	}
+	registry, err := endpoint.NewRegistry(nil, endpoint.Config{
+		Integrations: []endpoint.Integration{integration},
+		Switchovers:  switchovers,
+		Windows:      windows,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	t.Cleanup(registry.Close)
+	return registry
+}
+
+func TestEndpointRegistrySwitchover(t *testing.T) {
+	now := time.Now()
+
+	registry := newEndpointRegistry(t, nil, nil)
+	if url, err := registry.Resolve(endpoint.FinanzOnline); err != nil || url != "https://blue.example" {
+		t.Errorf("expected default set, got %q, %v", url, err)
+	}
+
+	registry = newEndpointRegistry(t, []endpoint.Switchover{
+		{Integration: endpoint.FinanzOnline, Set: "green", At: now.Add(-time.Hour)},
+		{Integration: endpoint.FinanzOnline, Set: "blue", At: now.Add(time.Hour)},
+	}, nil)
+	if url, err := registry.Resolve(endpoint.FinanzOnline); err != nil || url != "https://green.example" {
+		t.Errorf("expected switched set, got %q, %v", url, err)
+	}
+	status := registry.Status()[0]
+	if status.Active != "green" || status.ActiveSince == nil || len(status.Switchovers) != 1 || status.Switchovers[0].Set != "blue" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	if _, err := registry.Resolve(endpoint.ELDA); !errors.Is(err, endpoint.ErrUnknownIntegration) {
+		t.Errorf("expected ErrUnknownIntegration, got %v", err)
+	}
+
+	integration, _ := endpoint.NewIntegration(endpoint.FinanzOnline, []string{"blue=https://blue.example"}, "")
+	_, err := endpoint.NewRegistry(nil, endpoint.Config{
+		Integrations: []endpoint.Integration{integration},
+		Switchovers:  []endpoint.Switchover{{Integration: endpoint.FinanzOnline, Set: "green", At: now}},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if !errors.Is(err, endpoint.ErrUnknownSet) {
+		t.Errorf("expected ErrUnknownSet for a switchover to an unconfigured set, got %v", err)
+	}
+}
+
+func TestEndpointRegistryMaintenanceWindow(t *testing.T) {
+	now := time.Now()
+	registry := newEndpointRegistry(t, nil, []endpoint.Window{
+		{Integration: endpoint.FinanzOnline, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(30 * time.Minute), Reason: "BMF release"},
+		{Integration: endpoint.FinanzOnline, StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour)},
+		{Integration: endpoint.FinanzOnline, StartsAt: now.Add(2 * time.Hour), EndsAt: now.Add(3 * time.Hour)},
+	})
+
+	_, err := registry.Resolve(endpoint.FinanzOnline)
+	if !errors.Is(err, endpoint.ErrMaintenanceWindow) {
+		t.Fatalf("expected maintenance window, got %v", err)
+	}
+	m, ok := endpoint.AsMaintenance(err)
+	if !ok || !m.RetryAt().Equal(now.Add(time.Hour)) {
+		t.Errorf("expected retry at the latest end of overlapping windows, got %+v", m)
+	}
+	if after := m.RetryAfter(); after < 3590 || after > 3600 {
+		t.Errorf("unexpected Retry-After %d", after)
+	}
+
+	status := registry.Status()[0]
+	if !status.InMaintenance || len(status.Windows) != 3 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestFinanzOnlineClientEndpointResolver(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`<?xml version="1.0"?><soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><LoginResponse><rc>0</rc><id>session-1</id></LoginResponse></soap:Body></soap:Envelope>`))
+	}))
+	defer server.Close()
+
+	var resolveErr error
+	client := fonws.NewClient()
+	client.SetRetry(0, 0)
+	client.SetEndpointResolver(func() (string, error) {
+		return server.URL + "/fonws/ws/", resolveErr
+	})
+
+	var resp fonws.LoginResponse
+	if err := client.Call(fonws.SessionServiceURL, fonws.LoginRequest{Xmlns: fonws.SessionNS}, &resp); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if path != "/fonws/ws/sessionService" {
+		t.Errorf("expected request to the resolved endpoint, got path %q", path)
+	}
+
+	path = ""
+	resolveErr = &endpoint.MaintenanceError{Integration: endpoint.FinanzOnline, Until: time.Now().Add(time.Hour)}
+	err := client.Call(fonws.SessionServiceURL, fonws.LoginRequest{Xmlns: fonws.SessionNS}, &resp)
+	if !errors.Is(err, endpoint.ErrMaintenanceWindow) || !strings.Contains(err.Error(), "maintenance window") {
+		t.Errorf("expected maintenance error, got %v", err)
+	}
+	if path != "" {
+		t.Error("no request should be sent during a maintenance window")
+	}
+}
@@ -0,0 +1,75 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"austrian-business-infrastructure/internal/account"
+	"austrian-business-infrastructure/internal/entitychange"
+	"github.com/google/uuid"
+)
+
+func TestEntityChangePlan(t *testing.T) {
+	tests := []struct {
+		name        string
+		kind        string
+		from, to    string
+		accountType string
+		want        string
+	}{
+		{"EPU to GmbH needs a new Steuernummer", entitychange.KindConversion, entitychange.FormEU, entitychange.FormGmbH, account.AccountTypeFinanzOnline, entitychange.ActionReregister},
+		{"EPU to GmbH needs a new Dienstgeberkonto", entitychange.KindConversion, entitychange.FormEU, entitychange.FormGmbH, account.AccountTypeELDA, entitychange.ActionReregister},
+		{"OG to GmbH", entitychange.KindConversion, entitychange.FormOG, entitychange.FormGmbH, account.AccountTypeFinanzOnline, entitychange.ActionReregister},
+		{"GmbH to AG keeps the entity", entitychange.KindConversion, entitychange.FormGmbH, entitychange.FormAG, account.AccountTypeFinanzOnline, entitychange.ActionKeep},
+		{"OG to KG keeps the entity", entitychange.KindConversion, entitychange.FormOG, entitychange.FormKG, account.AccountTypeELDA, entitychange.ActionKeep},
+		{"merger closes the absorbed company", entitychange.KindMerger, entitychange.FormGmbH, entitychange.FormGmbH, account.AccountTypeFinanzOnline, entitychange.ActionClose},
+	}
+	for _, tt := range tests {
+		action, reason := entitychange.Plan(tt.kind, tt.from, tt.to, tt.accountType)
+		if action != tt.want || reason == "" {
+			t.Errorf("%s: Plan() = %s (%q), want %s", tt.name, action, reason, tt.want)
+		}
+	}
+}
+
+func TestEntityChangeInput(t *testing.T) {
+	valid := func() *entitychange.CreateInput {
+		return &entitychange.CreateInput{
+			Kind:          " Conversion ",
+			EntityName:    "Maria Huber e.U.",
+			OldLegalForm:  "EU",
+			SuccessorName: "Huber GmbH",
+			NewLegalForm:  "GmbH",
+			EffectiveDate: "2025-07-01",
+			AccountIDs:    []uuid.UUID{uuid.New()},
+		}
+	}
+
+	in := valid()
+	effective, err := in.Validate()
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if effective.Format("2006-01-02") != "2025-07-01" || in.Kind != entitychange.KindConversion ||
+		in.OldLegalForm != entitychange.FormEU || in.NewLegalForm != entitychange.FormGmbH {
+		t.Errorf("Validate() normalized to %+v, effective %v", in, effective)
+	}
+
+	for name, tc := range map[string]struct {
+		change func(*entitychange.CreateInput)
+		want   error
+	}{
+		"unknown kind":       {func(in *entitychange.CreateInput) { in.Kind = "split" }, entitychange.ErrInvalidKind},
+		"unknown legal form": {func(in *entitychange.CreateInput) { in.NewLegalForm = "ltd" }, entitychange.ErrInvalidLegalForm},
+		"same legal form":    {func(in *entitychange.CreateInput) { in.NewLegalForm = "eu" }, entitychange.ErrSameLegalForm},
+		"missing successor":  {func(in *entitychange.CreateInput) { in.SuccessorName = " " }, entitychange.ErrNamesRequired},
+		"invalid date":       {func(in *entitychange.CreateInput) { in.EffectiveDate = "01.07.2025" }, entitychange.ErrInvalidEffective},
+		"no accounts":        {func(in *entitychange.CreateInput) { in.AccountIDs = nil }, entitychange.ErrNoAccounts},
+	} {
+		in := valid()
+		tc.change(in)
+		if _, err := in.Validate(); !errors.Is(err, tc.want) {
+			t.Errorf("%s: Validate() error = %v, want %v", name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+
+	"austrian-business-infrastructure/internal/invoice"
+	"austrian-business-infrastructure/internal/pdfa"
+)
+
+func TestInvoiceFacturXPDF(t *testing.T) {
+	inv := &invoice.Invoice{
+		InvoiceNumber:      "RE-2026-0042",
+		InvoiceType:        "380",
+		IssueDate:          time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Currency:           "EUR",
+		SellerName:         "Müller & Söhne GmbH",
+		BuyerName:          "Kunde GmbH",
+		TaxExclusiveAmount: 100000,
+		TaxAmount:          20000,
+		PayableAmount:      120000,
+	}
+	items := []*invoice.InvoiceItem{{
+		LineNumber:  1,
+		Description: "Beratung Jänner",
+		Quantity:    1,
+		UnitPrice:   100000,
+		LineTotal:   100000,
+		TaxCategory: "S",
+	}}
+	ciiXML := []byte(`<?xml version="1.0" encoding="UTF-8"?><rsm:CrossIndustryInvoice/>`)
+	created := time.Date(2026, 10, 2, 9, 30, 0, 0, time.UTC)
+
+	pdf, err := invoice.GenerateFacturXPDF(inv, items, nil, nil, ciiXML, created)
+	if err != nil {
+		t.Fatalf("GenerateFacturXPDF: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3")) {
+		t.Error("expected PDF 1.7 header with binary marker")
+	}
+	for _, marker := range []string{
+		"<pdfaid:part>3</pdfaid:part><pdfaid:conformance>B</pdfaid:conformance>",
+		"<fx:ConformanceLevel>EXTENDED</fx:ConformanceLevel>",
+		"/S /GTS_PDFA1",
+		"/FontFile2",
+		"/AFRelationship /Alternative",
+		"/ID [<",
+		"<rdf:li>Müller &amp; Söhne GmbH</rdf:li>",
+	} {
+		if !bytes.Contains(pdf, []byte(marker)) {
+			t.Errorf("missing %q", marker)
+		}
+	}
+	if bytes.Contains(pdf, []byte("/Helvetica")) {
+		t.Error("PDF/A must not use the unembedded standard font")
+	}
+
+	// pdfcpu reads the file and finds the XML as attachment
+	ctx, err := api.ReadContext(bytes.NewReader(pdf), model.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("pdfcpu: %v", err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil || ctx.PageCount != 1 {
+		t.Errorf("page count = %d, %v", ctx.PageCount, err)
+	}
+	attachments, err := api.ExtractAttachmentsRaw(bytes.NewReader(pdf), "", nil, nil)
+	if err != nil || len(attachments) != 1 || attachments[0].FileName != invoice.FacturXFileName {
+		t.Fatalf("attachments = %+v, %v", attachments, err)
+	}
+	if embedded, _ := io.ReadAll(attachments[0]); !bytes.Equal(embedded, ciiXML) {
+		t.Errorf("embedded XML = %q", embedded)
+	}
+
+	// Same invoice, same file
+	again, _ := invoice.GenerateFacturXPDF(inv, items, nil, nil, ciiXML, created)
+	if !bytes.Equal(pdf, again) {
+		t.Error("rendering is not deterministic")
+	}
+	if _, err := invoice.GenerateFacturXPDF(inv, items, nil, nil, nil, created); err == nil {
+		t.Error("expected error without XML")
+	}
+}
+
+func TestSRGBProfile(t *testing.T) {
+	icc := pdfa.SRGBProfile()
+	if len(icc) < 128 || int(binary.BigEndian.Uint32(icc)) != len(icc) {
+		t.Fatalf("profile size = %d", len(icc))
+	}
+	if string(icc[12:16]) != "mntr" || string(icc[16:20]) != "RGB " || string(icc[36:40]) != "acsp" {
+		t.Errorf("header = %q", icc[12:40])
+	}
+	// Every tag lies within the profile
+	count := int(binary.BigEndian.Uint32(icc[128:]))
+	for i := 0; i < count; i++ {
+		entry := icc[132+12*i:]
+		offset, size := binary.BigEndian.Uint32(entry[4:]), binary.BigEndian.Uint32(entry[8:])
+		if int(offset+size) > len(icc) || offset%4 != 0 {
+			t.Errorf("tag %q at %d+%d", entry[:4], offset, size)
+		}
+	}
+}
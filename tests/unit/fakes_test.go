@@ -0,0 +1,161 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/account"
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/fakes"
+	"austrian-business-infrastructure/internal/fb"
+	"austrian-business-infrastructure/internal/fixtures"
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/mbgm"
+	"austrian-business-infrastructure/internal/sepa"
+)
+
+func TestFixturesAreValid(t *testing.T) {
+	f := fixtures.NewFaker(11)
+	for i := 0; i < 50; i++ {
+		c := f.Company()
+		if r := fonws.ValidateUIDFormat(c.UID); !r.Valid {
+			t.Errorf("UID %s: %s", c.UID, r.Error)
+		}
+		if err := sepa.ValidateIBAN(c.IBAN); err != nil {
+			t.Errorf("IBAN %s: %v", c.IBAN, err)
+		}
+		if err := fb.ValidateFN(f.FN()); err != nil {
+			t.Error(err)
+		}
+		creds := f.FinanzOnlineCredentials()
+		if err := account.ValidateFinanzOnlineCredentials(creds.TID, creds.BenID, creds.PIN); err != nil {
+			t.Errorf("credentials %+v: %v", creds, err)
+		}
+
+		m := f.Anmeldung(uuid.New(), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+		if err := mbgm.ValidateSVNummer(m.SVNummer); err != nil || m.SVNummer[4:] != m.Geburtsdatum.Format("020106") {
+			t.Errorf("SV-Nummer %s for %s: %v", m.SVNummer, m.Geburtsdatum.Format("2006-01-02"), err)
+		}
+		if m.Vorname == "" || m.Nachname == "" || m.Eintrittsdatum == nil || m.Type != elda.MeldungTypeAnmeldung {
+			t.Errorf("Anmeldung incomplete: %+v", m)
+		}
+
+		uva := f.UVA(2026, 1+i%12)
+		if err := fonws.ValidateUVA(uva); err != nil || uva.KZ095 != uva.CalculateKZ095() {
+			t.Errorf("UVA %+v: %v", uva, err)
+		}
+	}
+
+	a, b := fixtures.NewFaker(5), fixtures.NewFaker(5)
+	if !reflect.DeepEqual(a.FirmenbuchExtract(a.Company()), b.FirmenbuchExtract(b.Company())) {
+		t.Error("same seed must produce the same Firmenbuch extract")
+	}
+}
+
+func TestFinanzOnlineFake(t *testing.T) {
+	f := fixtures.NewFaker(1)
+	creds := f.FinanzOnlineCredentials()
+	fon := fakes.NewFinanzOnline().RejectLogin(fonws.ErrCodeInvalidCredentials, "Zugangsdaten ungültig")
+
+	sessions := fonws.NewSessionService(fon)
+	if _, err := sessions.Login(creds.TID, creds.BenID, creds.PIN); err == nil {
+		t.Fatal("scripted login rejection did not fail")
+	}
+	session, err := sessions.Login(creds.TID, creds.BenID, creds.PIN)
+	if err != nil {
+		t.Fatalf("default login failed: %v", err)
+	}
+
+	uploads := fonws.NewFileUploadService(fon)
+	resp, err := uploads.SubmitUVA(session.Token, creds.TID, creds.BenID, f.UVA(2026, 3))
+	if err != nil || resp.Belegnummer == "" {
+		t.Fatalf("upload = %+v, %v", resp, err)
+	}
+	if got := fon.Uploads(); len(got) != 1 || got[0].ID != session.Token || got[0].TID != creds.TID {
+		t.Errorf("recorded uploads %+v", got)
+	}
+
+	// A rejection comes back with the response and is not retried
+	fon.RejectUpload(-4, "Steuernummer nicht berechtigt")
+	resp, err = uploads.SubmitUVA(session.Token, creds.TID, creds.BenID, f.UVA(2026, 4))
+	if err == nil || resp == nil || resp.RC != -4 || fonws.IsRetryable(err) {
+		t.Errorf("rejected upload = %+v, %v", resp, err)
+	}
+
+	company := f.Company()
+	fon.Then(fakes.FONUIDAbfrage, fakes.Respond(fixtures.UIDResponse(company)))
+	result, err := fonws.NewUIDService(fon).Validate(session.Token, creds.TID, creds.BenID, company.UID, 2)
+	if err != nil || !result.Valid || result.CompanyName != company.Name {
+		t.Errorf("UID result %+v, %v", result, err)
+	}
+	if fon.Pending() != 0 {
+		t.Errorf("%d scripted answers left", fon.Pending())
+	}
+}
+
+func TestELDAFakeScenario(t *testing.T) {
+	ctx := context.Background()
+	f := fixtures.NewFaker(2)
+
+	// Transient failures are retried by services that use CallWithRetry
+	fake := fakes.NewELDA().Unreachable("SubmitMBGM", 2)
+	fake.Then("SubmitMBGM", fakes.Respond(&elda.MBGMResponse{Erfolg: true, Protokollnummer: "P-1"}))
+	result, err := elda.NewMBGMService(fake).SubmitMBGM(ctx, &elda.MBGMDocument{})
+	if err != nil || result.Protokollnummer != "P-1" {
+		t.Fatalf("mBGM = %+v, %v", result, err)
+	}
+	if n := len(fake.Calls("SubmitMBGM")); n != 3 {
+		t.Errorf("SubmitMBGM called %d times, want 3", n)
+	}
+
+	// Meldungen are submitted exactly once
+	m := f.Anmeldung(uuid.New(), time.Now())
+	fake.Unreachable("SubmitAnmeldung", 1).AcceptMeldung("SubmitAnmeldung", "P-2")
+	if _, err := elda.SubmitExtendedMeldung(ctx, fake, f.ELDACredentials(), m); !errors.Is(err, elda.ErrELDAConnection) {
+		t.Fatalf("got %v, want the connection error without retry", err)
+	}
+	resp, err := elda.SubmitExtendedMeldung(ctx, fake, f.ELDACredentials(), m)
+	if err != nil || !resp.Erfolg || resp.Protokollnummer != "P-2" {
+		t.Errorf("meldung = %+v, %v", resp, err)
+	}
+
+	if _, err := elda.NewBUAKService(fake).QueryBUAKStatus(ctx, "123456", "P-3"); !errors.Is(err, fakes.ErrNotScripted) {
+		t.Errorf("unscripted action: got %v", err)
+	}
+}
+
+func TestFirmenbuchAndAIFakes(t *testing.T) {
+	f := fixtures.NewFaker(3)
+	extract := f.FirmenbuchExtract(f.Company())
+	registry := fakes.NewFirmenbuch(extract)
+
+	found, err := registry.Search(&fb.FBSearchRequest{Name: strings.ToUpper(extract.Firma[:5])})
+	if err != nil || found.TotalCount != 1 || found.Results[0].FN != extract.FN {
+		t.Errorf("search = %+v, %v", found, err)
+	}
+	if got, err := registry.Extract(extract.FN); err != nil || got.Firma != extract.Firma {
+		t.Errorf("extract = %+v, %v", got, err)
+	}
+	if _, err := registry.Extract("FN1a"); !errors.Is(err, fakes.ErrCompanyNotFound) {
+		t.Errorf("unknown FN: got %v", err)
+	}
+
+	model := fakes.NewAI().Reply(`{"document_type":"bescheid"}`)
+	model.Then(fakes.AIComplete, fakes.Fail(errors.New("overloaded")))
+	resp, err := model.CompleteWithRetry(context.Background(), "system", "user", 0.1, 2)
+	if err != nil || resp.GetText() != `{"document_type":"bescheid"}` {
+		t.Errorf("completion = %+v, %v", resp, err)
+	}
+	if _, err := model.Complete(context.Background(), "system", "user", 0.1); err == nil {
+		t.Error("scripted failure did not fail")
+	}
+	if p := model.Prompts(); len(p) != 2 || p[0].User != "user" {
+		t.Errorf("prompts = %+v", p)
+	}
+}
@@ -0,0 +1,160 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"austrian-business-infrastructure/internal/filetype"
+)
+
+// pagedPDF returns a PDF with n empty pages of the given size in points and
+// a correct cross-reference table
+func pagedPDF(n int, width, height float64) []byte {
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>"}
+	kids := ""
+	for i := 0; i < n; i++ {
+		kids += fmt.Sprintf("%d 0 R ", i+3)
+	}
+	objects = append(objects, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, n))
+	for i := 0; i < n; i++ {
+		objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] >>", width, height))
+	}
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.7\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = pdf.Len()
+		fmt.Fprintf(&pdf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := pdf.Len()
+	fmt.Fprintf(&pdf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&pdf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&pdf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return pdf.Bytes()
+}
+
+func pngImage(width, height int) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height)))
+	return buf.Bytes()
+}
+
+func TestFileTypeSniff(t *testing.T) {
+	ole := append([]byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}, make([]byte, 504)...)
+	cases := map[string][]byte{
+		filetype.PDF:     pagedPDF(1, 595, 842),
+		filetype.PNG:     pngImage(2, 2),
+		filetype.OLE:     ole,
+		filetype.Unknown: {0x00, 0x01, 0x02},
+	}
+	for want, data := range cases {
+		if got := filetype.Sniff(data); got != want {
+			t.Errorf("Sniff = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestFileTypePolicy(t *testing.T) {
+	a4 := pagedPDF(2, 595, 842)
+
+	info, err := filetype.Signature.CheckBytes("Vertrag.PDF", a4)
+	if err != nil || info.MIMEType != filetype.PDF || info.Pages != 2 {
+		t.Fatalf("A4 PDF: %+v, %v", info, err)
+	}
+	if info, err := filetype.Analysis.CheckBytes("scan.png", pngImage(40, 30)); err != nil || info.Width != 40 || info.Height != 30 {
+		t.Errorf("PNG: %+v, %v", info, err)
+	}
+	// Without an extension the content decides
+	if _, err := filetype.Signature.CheckBytes("", a4); err != nil {
+		t.Errorf("PDF without file name: %v", err)
+	}
+
+	rejected := []struct {
+		name   string
+		policy filetype.Policy
+		file   string
+		data   []byte
+		want   error
+		status int
+	}{
+		{"image for signature", filetype.Signature, "scan.png", pngImage(2, 2), filetype.ErrNotAllowed, http.StatusUnsupportedMediaType},
+		{"PNG named .pdf", filetype.Analysis, "Bescheid.pdf", pngImage(2, 2), filetype.ErrExtensionMismatch, http.StatusUnsupportedMediaType},
+		{"PDF named .docx", filetype.Analysis, "Brief.docx", a4, filetype.ErrExtensionMismatch, http.StatusUnsupportedMediaType},
+		{"text", filetype.Analysis, "notes", []byte("just some text"), filetype.ErrNotAllowed, http.StatusUnsupportedMediaType},
+		{"too many pages", filetype.Policy{Types: []string{filetype.PDF}, MaxPages: 1}, "a.pdf", a4, filetype.ErrTooManyPages, http.StatusUnprocessableEntity},
+		{"oversized page", filetype.Signature, "plan.pdf", pagedPDF(1, 20000, 842), filetype.ErrTooLarge, http.StatusUnprocessableEntity},
+		{"oversized image", filetype.Policy{Types: []string{filetype.PNG}, MaxDimension: 100}, "a.png", pngImage(101, 10), filetype.ErrTooLarge, http.StatusUnprocessableEntity},
+		{"broken PDF", filetype.Signature, "a.pdf", []byte("%PDF-1.7\nnot really"), filetype.ErrUnreadable, http.StatusBadRequest},
+	}
+	for _, c := range rejected {
+		_, err := c.policy.CheckBytes(c.file, c.data)
+		if !errors.Is(err, c.want) {
+			t.Errorf("%s: err = %v, want %v", c.name, err, c.want)
+		}
+		if got := filetype.HTTPStatus(err); got != c.status {
+			t.Errorf("%s: status = %d, want %d", c.name, got, c.status)
+		}
+	}
+
+	// Legacy Office files take their type from the extension
+	ole := append([]byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}, make([]byte, 504)...)
+	if info, err := filetype.PortalUpload.CheckBytes("Lohnliste.xls", ole); err != nil || info.MIMEType != filetype.XLS {
+		t.Errorf("XLS: %+v, %v", info, err)
+	}
+	if _, err := filetype.PortalUpload.CheckBytes("Lohnliste.pdf", ole); !errors.Is(err, filetype.ErrExtensionMismatch) {
+		t.Errorf("OLE file named .pdf: %v", err)
+	}
+}
+
+func TestFileTypeCheckDeclared(t *testing.T) {
+	a4 := pagedPDF(1, 595, 842)
+
+	for _, declared := range []string{filetype.PDF, "", filetype.Unknown} {
+		if info, err := filetype.Ingestion.CheckBytesDeclared(declared, a4); err != nil || info.MIMEType != filetype.PDF {
+			t.Errorf("PDF declared %q: %+v, %v", declared, info, err)
+		}
+	}
+	if info, err := filetype.Ingestion.CheckBytesDeclared("", pngImage(10, 10)); err != nil || info.MIMEType != filetype.PNG {
+		t.Errorf("PNG: %+v, %v", info, err)
+	}
+
+	rejected := []struct {
+		name     string
+		declared string
+		data     []byte
+		want     error
+		status   int
+	}{
+		{"PNG declared as PDF", filetype.PDF, pngImage(2, 2), filetype.ErrTypeMismatch, http.StatusUnsupportedMediaType},
+		{"HTML declared as PDF", filetype.PDF, []byte("<html><script>alert(1)</script></html>"), filetype.ErrTypeMismatch, http.StatusUnsupportedMediaType},
+		{"HTML", "", []byte("<html><script>alert(1)</script></html>"), filetype.ErrNotAllowed, http.StatusUnsupportedMediaType},
+		{"executable", "", []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff"), filetype.ErrNotAllowed, http.StatusUnsupportedMediaType},
+		{"oversized image", filetype.PNG, pngImage(20001, 1), filetype.ErrTooLarge, http.StatusUnprocessableEntity},
+	}
+	for _, c := range rejected {
+		_, err := filetype.Ingestion.CheckBytesDeclared(c.declared, c.data)
+		if !errors.Is(err, c.want) {
+			t.Errorf("%s: err = %v, want %v", c.name, err, c.want)
+		}
+		if got := filetype.HTTPStatus(err); got != c.status {
+			t.Errorf("%s: status = %d, want %d", c.name, got, c.status)
+		}
+	}
+
+	// Legacy Office files take their type from the declaration
+	ole := append([]byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}, make([]byte, 504)...)
+	if info, err := filetype.Ingestion.CheckBytesDeclared(filetype.DOC, ole); err != nil || info.MIMEType != filetype.DOC {
+		t.Errorf("DOC: %+v, %v", info, err)
+	}
+	if _, err := filetype.Ingestion.CheckBytesDeclared("", ole); !errors.Is(err, filetype.ErrNotAllowed) {
+		t.Errorf("undeclared OLE file: %v", err)
+	}
+}
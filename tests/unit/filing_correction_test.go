@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/uva"
+	"austrian-business-infrastructure/internal/xmlschema"
+	"austrian-business-infrastructure/internal/zm"
+)
+
+func TestUVACorrectionDelta(t *testing.T) {
+	before := &uva.UVAData{KZ000: 100000, KZ017: 100000, KZ060: 5000, KZ095: 15000}
+	after := &uva.UVAData{KZ000: 120000, KZ017: 120000, KZ060: 5000, KZ095: 19000}
+
+	delta := uva.Delta(before, after)
+	want := []uva.KennzahlDelta{
+		{Kennzahl: "kz000", Before: 100000, After: 120000, Difference: 20000},
+		{Kennzahl: "kz017", Before: 100000, After: 120000, Difference: 20000},
+		{Kennzahl: "kz095", Before: 15000, After: 19000, Difference: 4000},
+	}
+	if len(delta) != len(want) {
+		t.Fatalf("Delta() = %+v, want %+v", delta, want)
+	}
+	for i := range want {
+		if delta[i] != want[i] {
+			t.Errorf("Delta()[%d] = %+v, want %+v", i, delta[i], want[i])
+		}
+	}
+	if delta := uva.Delta(before, before); len(delta) != 0 {
+		t.Errorf("Delta() of an unchanged UVA = %+v", delta)
+	}
+
+	doc, err := fonws.GenerateUVAXML(&fonws.UVA{
+		Year:         2025,
+		Period:       fonws.UVAPeriod{Type: fonws.PeriodTypeMonthly, Value: 3},
+		KZ000:        after.KZ000,
+		KZ017:        after.KZ017,
+		KZ060:        after.KZ060,
+		KZ095:        after.KZ095,
+		Berichtigung: &fonws.Berichtigung{Bezugsnummer: "FO-2025-0042"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(doc), "<Berichtigung>\n    <Bezugsnummer>FO-2025-0042</Bezugsnummer>") {
+		t.Errorf("expected the Berichtigung in the U30:\n%s", doc)
+	}
+	if err := xmlschema.Check(xmlschema.FormatU30, time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), doc); err != nil {
+		t.Errorf("U30 Berichtigung: %v", err)
+	}
+	parsed, err := fonws.ParseUVAFromXML(doc)
+	if err != nil || parsed.Berichtigung == nil || parsed.Berichtigung.Bezugsnummer != "FO-2025-0042" {
+		t.Errorf("ParseUVAFromXML() = %+v, %v", parsed, err)
+	}
+}
+
+func TestZMCorrection(t *testing.T) {
+	before := []zm.Entry{
+		{PartnerUID: "DE123456789", CountryCode: "DE", DeliveryType: "L", Amount: 1250000},
+		{PartnerUID: "IT12345678901", CountryCode: "IT", DeliveryType: "S", Amount: 300000},
+		{PartnerUID: "FR12345678901", CountryCode: "FR", DeliveryType: "L", Amount: 80000},
+	}
+	after := []zm.Entry{
+		{PartnerUID: "DE 123456789", CountryCode: "DE", DeliveryType: "L", Amount: 1250000},
+		{PartnerUID: "IT12345678901", CountryCode: "IT", DeliveryType: "S", Amount: 350000},
+		{PartnerUID: "NL123456789B01", CountryCode: "NL", DeliveryType: "D", Amount: 40000},
+	}
+
+	delta := zm.Delta(before, after)
+	want := []zm.EntryDelta{
+		{PartnerUID: "FR12345678901", CountryCode: "FR", DeliveryType: "L", Before: 80000, After: 0, Difference: -80000},
+		{PartnerUID: "IT12345678901", CountryCode: "IT", DeliveryType: "S", Before: 300000, After: 350000, Difference: 50000},
+		{PartnerUID: "NL123456789B01", CountryCode: "NL", DeliveryType: "D", Before: 0, After: 40000, Difference: 40000},
+	}
+	if len(delta) != len(want) {
+		t.Fatalf("Delta() = %+v, want %+v", delta, want)
+	}
+	for i := range want {
+		if delta[i] != want[i] {
+			t.Errorf("Delta()[%d] = %+v, want %+v", i, delta[i], want[i])
+		}
+	}
+
+	reference := "FO-2025-0099"
+	correction, err := zm.CorrectionZM(2025, 1, &reference, delta)
+	if err != nil {
+		t.Fatalf("CorrectionZM() error = %v", err)
+	}
+	doc, err := fonws.GenerateZMXML(correction)
+	if err != nil {
+		t.Fatalf("GenerateZMXML() error = %v", err)
+	}
+	xml := string(doc)
+	if strings.Contains(xml, "DE123456789") || strings.Count(xml, "<Position>") != 3 {
+		t.Errorf("expected only the changed lines in the correction:\n%s", xml)
+	}
+	if !strings.Contains(xml, "<Bezugsnummer>FO-2025-0099</Bezugsnummer>") ||
+		!strings.Contains(xml, "<PartnerUID>FR12345678901</PartnerUID>\n    <LandCode>FR</LandCode>\n    <Lieferart>L</Lieferart>\n    <Bemessungsgrundlage>0</Bemessungsgrundlage>") {
+		t.Errorf("expected the reference and the withdrawn line with 0:\n%s", xml)
+	}
+	if err := xmlschema.Check(xmlschema.FormatZM, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), doc); err != nil {
+		t.Errorf("ZM Berichtigung: %v", err)
+	}
+
+	// Only a Berichtigung may report a zero amount
+	original := fonws.NewZM(2025, 1)
+	original.Entries = correction.Entries
+	if _, err := fonws.GenerateZMXML(original); err == nil {
+		t.Error("expected a zero amount to be rejected outside a Berichtigung")
+	}
+
+	if _, err := zm.CorrectionZM(2025, 1, &reference, zm.Delta(before, before)); !errors.Is(err, zm.ErrNoChanges) {
+		t.Errorf("CorrectionZM() without changes error = %v, want ErrNoChanges", err)
+	}
+}
@@ -0,0 +1,99 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/fakes"
+	"austrian-business-infrastructure/internal/fb"
+	"austrian-business-infrastructure/internal/firmenbuch"
+	"austrian-business-infrastructure/internal/matcher"
+)
+
+func searchRegistry() *fakes.Firmenbuch {
+	return fakes.NewFirmenbuch(
+		&fb.FBExtract{FN: "FN100001a", Firma: "Muster GmbH", Sitz: "Wien", UID: "ATU12345678",
+			Adresse: fb.FBAdresse{Strasse: "Hauptstraße 1", PLZ: "1010", Ort: "Wien"}},
+		&fb.FBExtract{FN: "FN100002b", Firma: "Muster Bau GmbH", Sitz: "Graz",
+			Adresse: fb.FBAdresse{Strasse: "Murgasse 5", PLZ: "8010", Ort: "Graz"}},
+		&fb.FBExtract{FN: "FN100003c", Firma: "Muster Handel OG", Sitz: "Wien",
+			Adresse: fb.FBAdresse{Strasse: "Ringstraße 9", PLZ: "1010", Ort: "Wien"}},
+	)
+}
+
+func TestFirmenbuchSearchFilters(t *testing.T) {
+	svc := firmenbuch.NewService(nil, searchRegistry())
+	ctx, tenantID := context.Background(), uuid.New()
+
+	tests := []struct {
+		name  string
+		input firmenbuch.SearchInput
+		want  []string
+	}{
+		{"full text", firmenbuch.SearchInput{Query: "muster wien"}, []string{"FN100001a", "FN100003c"}},
+		{"uid", firmenbuch.SearchInput{UID: "atu 12345678"}, []string{"FN100001a"}},
+		{"address", firmenbuch.SearchInput{Name: "Muster", PLZ: "1010", Strasse: "ring"}, []string{"FN100003c"}},
+		{"second page", firmenbuch.SearchInput{Query: "Muster", MaxHits: 2, Offset: 2}, []string{"FN100003c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input
+			resp, err := svc.Search(ctx, tenantID, &input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range resp.Results {
+				got = append(got, r.FN)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	if _, err := svc.Search(ctx, tenantID, &firmenbuch.SearchInput{UID: "DE123456789"}); !errors.Is(err, firmenbuch.ErrInvalidUID) {
+		t.Errorf("expected ErrInvalidUID, got %v", err)
+	}
+	if _, err := firmenbuch.NewService(nil, nil).Search(ctx, tenantID, &firmenbuch.SearchInput{Query: "Muster"}); !errors.Is(err, firmenbuch.ErrNotConfigured) {
+		t.Errorf("expected ErrNotConfigured, got %v", err)
+	}
+}
+
+func TestFirmenbuchSearchCache(t *testing.T) {
+	registry := searchRegistry()
+	svc := firmenbuch.NewService(nil, registry)
+	searchCache := matcher.NewInMemoryCache()
+	defer searchCache.Close()
+	svc.SetSearchCache(searchCache, time.Hour)
+	ctx := context.Background()
+
+	first, err := svc.Search(ctx, uuid.New(), &firmenbuch.SearchInput{Query: "Muster", MaxHits: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Cached || first.TotalCount != 3 || len(first.Results) != 2 || first.Limit != 2 {
+		t.Fatalf("unexpected first response %+v", first)
+	}
+
+	// Another tenant gets the cached response without a registry call
+	second, err := svc.Search(ctx, uuid.New(), &firmenbuch.SearchInput{Query: "Muster", MaxHits: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Cached || len(second.Results) != 2 || second.Results[0].UID != "ATU12345678" {
+		t.Errorf("unexpected cached response %+v", second)
+	}
+	if n := len(registry.Calls(fakes.FBSearch)); n != 1 {
+		t.Errorf("expected 1 registry search, got %d", n)
+	}
+}
@@ -0,0 +1,110 @@
+package unit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/fb"
+	"austrian-business-infrastructure/internal/firmenbuch"
+	"austrian-business-infrastructure/internal/mail"
+)
+
+func watchedCompany() *fb.FBExtract {
+	return &fb.FBExtract{
+		FN:           "123456a",
+		Firma:        "Muster GmbH",
+		Sitz:         "Wien",
+		Stammkapital: 3500000,
+		Waehrung:     "EUR",
+		Status:       fb.FBStatusAktiv,
+		Geschaeftsfuehrer: []fb.FBPerson{
+			{Vorname: "Anna", Nachname: "Huber", Funktion: fb.FunktionGeschaeftsfuehrer},
+		},
+	}
+}
+
+func TestFirmenbuchDiff(t *testing.T) {
+	detectedAt := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+
+	if changes := firmenbuch.Diff(watchedCompany(), watchedCompany(), detectedAt); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %d", len(changes))
+	}
+
+	current := watchedCompany()
+	left := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+	current.Geschaeftsfuehrer = []fb.FBPerson{
+		{Vorname: "Anna", Nachname: "Huber", Funktion: fb.FunktionGeschaeftsfuehrer, Bis: &left},
+		{Vorname: "Eva", Nachname: "Berger", Funktion: fb.FunktionGeschaeftsfuehrer},
+	}
+	current.Stammkapital = 10000000
+	current.Sitz = "Graz"
+	current.Status = fb.FBStatusInsolvent
+
+	changes := firmenbuch.Diff(watchedCompany(), current, detectedAt)
+	byType := make(map[string]*firmenbuch.HistoryEntry)
+	for _, c := range changes {
+		byType[c.ChangeType] = c
+		if !c.DetectedAt.Equal(detectedAt) {
+			t.Errorf("%s: detected at %s", c.ChangeType, c.DetectedAt)
+		}
+	}
+	if len(changes) != 4 {
+		t.Fatalf("expected 4 changes, got %d", len(changes))
+	}
+
+	gf := byType[firmenbuch.ChangeGeschaeftsfuehrer]
+	if gf == nil || gf.Summary != "Geschäftsführung neu: Eva Berger; ausgeschieden: Anna Huber" {
+		t.Fatalf("unexpected Geschäftsführer change %+v", gf)
+	}
+	var officers []firmenbuch.Officer
+	if err := json.Unmarshal(gf.NewValue, &officers); err != nil || len(officers) != 1 || officers[0].Name != "Eva Berger" {
+		t.Errorf("unexpected new Geschäftsführer %s", gf.NewValue)
+	}
+
+	if c := byType[firmenbuch.ChangeKapital]; c == nil || c.Summary != "Stammkapital von 35000.00 EUR auf 100000.00 EUR" {
+		t.Errorf("unexpected capital change %+v", c)
+	}
+	if c := byType[firmenbuch.ChangeSitz]; c == nil || string(c.NewValue) != `{"sitz":"Graz"}` {
+		t.Errorf("unexpected seat change %+v", c)
+	}
+	if c := byType[firmenbuch.ChangeInsolvenz]; c == nil || c.Summary != "Insolvenzverfahren eröffnet" {
+		t.Errorf("unexpected insolvency change %+v", c)
+	}
+
+	// Deletion is a status change, not an insolvency
+	deleted := watchedCompany()
+	deleted.Status = fb.FBStatusGeloescht
+	changes = firmenbuch.Diff(watchedCompany(), deleted, detectedAt)
+	if len(changes) != 1 || changes[0].ChangeType != firmenbuch.ChangeStatus {
+		t.Errorf("expected a status change, got %+v", changes)
+	}
+}
+
+func TestFirmenbuchChangeMail(t *testing.T) {
+	renderer, err := mail.NewRenderer("Austrian Business Platform")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, err := renderer.Render(mail.TemplateFirmenbuchChange, email.FirmenbuchChangeParams{
+		RecipientName: "Anna Huber",
+		CompanyName:   "Muster GmbH",
+		FN:            "123456a",
+		Changes:       []string{"Sitz von Wien nach Graz verlegt", "Insolvenzverfahren eröffnet"},
+		HistoryURL:    "https://app.example/firmenbuch/123456a",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered.Subject != "Firmenbuch-Änderung: Muster GmbH" {
+		t.Errorf("unexpected subject %q", rendered.Subject)
+	}
+	for _, want := range []string{"Muster GmbH (123456a)", "- Sitz von Wien nach Graz verlegt", "- Insolvenzverfahren eröffnet", "https://app.example/firmenbuch/123456a"} {
+		if !strings.Contains(rendered.Text, want) {
+			t.Errorf("expected %q in text:\n%s", want, rendered.Text)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/foerderplanung"
+	"austrian-business-infrastructure/internal/foerderung"
+	"github.com/google/uuid"
+)
+
+func TestFoerderplanungWeekStart(t *testing.T) {
+	for _, d := range []time.Time{
+		time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 4, 15, 30, 0, 0, time.UTC),
+		time.Date(2026, 3, 8, 23, 0, 0, 0, time.UTC),
+	} {
+		if got := foerderplanung.WeekStart(d); !got.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("WeekStart(%s) = %s", d, got)
+		}
+	}
+}
+
+func TestFoerderplanungCalendar(t *testing.T) {
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC) // Monday
+	efforts := foerderplanung.DefaultEfforts()
+	anna := uuid.New()
+	advisors := map[uuid.UUID]foerderplanung.Advisor{anna: {UserID: anna, Name: "Anna", WeeklyHours: 6}}
+
+	antrag := func(typ, status string, deadline time.Time, advisor *uuid.UUID) foerderplanung.Deadline {
+		id := uuid.New()
+		d := foerderplanung.Deadline{
+			Source: foerderplanung.SourceAntrag, AntragID: &id, Program: typ, FoerderungType: typ,
+			Status: status, Deadline: deadline, AdvisorID: advisor,
+		}
+		foerderplanung.Estimate(&d, efforts)
+		return d
+	}
+
+	deadlines := []foerderplanung.Deadline{
+		// 16 h over 4 weeks ending in the week of March 23rd: 4 h per week
+		antrag(string(foerderung.TypeZuschuss), foerderung.AntragStatusPlanned, time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC), &anna),
+		// 8 h, half of it left while drafting, over the 3 weeks ending in the week of March 16th
+		antrag(string(foerderung.TypeKredit), foerderung.AntragStatusDrafting, time.Date(2026, 3, 17, 0, 0, 0, 0, time.UTC), &anna),
+		antrag(string(foerderung.TypeBeratung), foerderung.AntragStatusPlanned, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), nil),
+		antrag(string(foerderung.TypeBeratung), foerderung.AntragStatusPlanned, time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC), &anna),
+	}
+	watched := foerderplanung.Deadline{Source: foerderplanung.SourceWatched, FoerderungType: string(foerderung.TypeGarantie), Deadline: time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)}
+	foerderplanung.Estimate(&watched, efforts)
+	deadlines = append(deadlines, watched)
+
+	cal := foerderplanung.BuildCalendar(deadlines, advisors, efforts, from, 4)
+	if len(cal.Weeks) != 4 || cal.From != "2026-03-02" {
+		t.Fatalf("calendar = %+v", cal)
+	}
+
+	wantAnna := []float64{5.3, 5.3, 5.3, 4}
+	for i, week := range cal.Weeks {
+		if len(week.Advisors) != 1 || week.Advisors[0].UserID != anna {
+			t.Fatalf("week %s advisors = %+v", week.Start, week.Advisors)
+		}
+		if got := week.Advisors[0].PlannedHours; got != wantAnna[i] {
+			t.Errorf("week %s planned = %v, want %v", week.Start, got, wantAnna[i])
+		}
+		if week.Advisors[0].OverAllocated {
+			t.Errorf("week %s over-allocated at %v h", week.Start, week.Advisors[0].PlannedHours)
+		}
+	}
+	if cal.Weeks[0].UnassignedHours != 2 || cal.Weeks[1].UnassignedHours != 2 {
+		t.Errorf("unassigned hours = %v, %v", cal.Weeks[0].UnassignedHours, cal.Weeks[1].UnassignedHours)
+	}
+	if cal.Weeks[0].WatchedHours != 3 || cal.Weeks[1].WatchedHours != 3 {
+		t.Errorf("watched hours = %v, %v", cal.Weeks[0].WatchedHours, cal.Weeks[1].WatchedHours)
+	}
+
+	kinds := map[string]int{}
+	for _, w := range cal.Warnings {
+		kinds[w.Kind]++
+	}
+	if kinds[foerderplanung.WarningDeadlinePassed] != 1 || kinds[foerderplanung.WarningUnassigned] != 1 ||
+		kinds[foerderplanung.WarningShortLeadTime] != 0 || kinds[foerderplanung.WarningOverAllocated] != 0 {
+		t.Errorf("warnings = %+v", cal.Warnings)
+	}
+
+	// With less capacity the same plan over-allocates
+	advisors[anna] = foerderplanung.Advisor{UserID: anna, Name: "Anna", WeeklyHours: 5}
+	cal = foerderplanung.BuildCalendar(deadlines, advisors, efforts, from, 4)
+	over := 0
+	for _, w := range cal.Warnings {
+		if w.Kind == foerderplanung.WarningOverAllocated {
+			over++
+		}
+	}
+	if over != 3 {
+		t.Errorf("over-allocated weeks = %d, want 3", over)
+	}
+}
@@ -0,0 +1,62 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/foerderung"
+	"austrian-business-infrastructure/internal/mail"
+)
+
+func TestFoerderungReviewCutoff(t *testing.T) {
+	// 23:30 UTC is already the next day in Vienna
+	now := time.Date(2026, 8, 31, 23, 30, 0, 0, time.UTC)
+
+	cutoff := foerderung.NewStatusSync(nil, 0).ReviewCutoff(now)
+	if want := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC); !cutoff.Equal(want) {
+		t.Errorf("default cutoff = %v, want %v", cutoff, want)
+	}
+	cutoff = foerderung.NewStatusSync(nil, 12).ReviewCutoff(now)
+	if want := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC); !cutoff.Equal(want) {
+		t.Errorf("cutoff = %v, want %v", cutoff, want)
+	}
+}
+
+func TestFoerderungStatusMail(t *testing.T) {
+	renderer, err := mail.NewRenderer("Austrian Business Platform")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	single := email.FoerderungStatusParams{
+		RecipientName: "Anna Huber",
+		Changes: []email.FoerderungStatusChange{
+			{Name: "aws Preseed", OldStatus: "aktiv", NewStatus: "geschlossen", Reason: "Einreichfrist abgelaufen", URL: "https://app.example/foerderungen/1"},
+		},
+		FoerderungenURL: "https://app.example/foerderungen",
+	}
+	rendered, err := renderer.Render(mail.TemplateFoerderungStatus, single)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered.Subject != "Förderung aws Preseed: Status geschlossen" {
+		t.Errorf("unexpected subject %q", rendered.Subject)
+	}
+	for _, want := range []string{"Anna Huber", "- aws Preseed: aktiv → geschlossen (Einreichfrist abgelaufen)", "https://app.example/foerderungen/1"} {
+		if !strings.Contains(rendered.Text, want) {
+			t.Errorf("expected %q in text:\n%s", want, rendered.Text)
+		}
+	}
+
+	multiple := single
+	multiple.Changes = append(multiple.Changes, email.FoerderungStatusChange{Name: "FFG Basisprogramm", OldStatus: "aktiv", NewStatus: "pausiert", Reason: "Programm aktualisiert"})
+	rendered, err = renderer.Render(mail.TemplateFoerderungStatus, multiple)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered.Subject != "Status von 2 Förderungen geändert" {
+		t.Errorf("unexpected subject %q", rendered.Subject)
+	}
+}
@@ -0,0 +1,95 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"austrian-business-infrastructure/internal/handover"
+	"github.com/google/uuid"
+)
+
+func TestHandoverCreateInputValidate(t *testing.T) {
+	valid := func() handover.CreateInput {
+		return handover.CreateInput{
+			TargetTenant:     " kanzlei-huber ",
+			AccountIDs:       []uuid.UUID{uuid.New()},
+			ClientName:       "Muster GmbH",
+			ConsentReference: "Vollmachtswiderruf",
+			ConsentGivenAt:   "2026-10-01",
+		}
+	}
+
+	in := valid()
+	given, err := in.Validate()
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if given.Format("2006-01-02") != "2026-10-01" || in.TargetTenant != "kanzlei-huber" {
+		t.Errorf("consent = %s, target = %q", given, in.TargetTenant)
+	}
+	if in.Mode != handover.ModeCopy || !*in.IncludeDocuments || !*in.IncludeFilings {
+		t.Errorf("defaults = %q, %v, %v", in.Mode, *in.IncludeDocuments, *in.IncludeFilings)
+	}
+
+	no := false
+	tests := []struct {
+		name   string
+		modify func(*handover.CreateInput)
+		want   error
+	}{
+		{"no accounts", func(in *handover.CreateInput) { in.AccountIDs = nil }, handover.ErrNoAccounts},
+		{"no consent", func(in *handover.CreateInput) { in.ConsentReference = " " }, handover.ErrConsentRequired},
+		{"bad consent date", func(in *handover.CreateInput) { in.ConsentGivenAt = "01.10.2026" }, handover.ErrInvalidConsentDate},
+		{"bad mode", func(in *handover.CreateInput) { in.Mode = "move" }, handover.ErrInvalidMode},
+		{"nothing included", func(in *handover.CreateInput) { in.IncludeDocuments, in.IncludeFilings = &no, &no }, handover.ErrNothingIncluded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := valid()
+			tt.modify(&in)
+			if _, err := in.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandoverCheckMapping(t *testing.T) {
+	fon, elda := uuid.New(), uuid.New()
+	accounts := []handover.AccountSummary{
+		{ID: fon, Name: "FinanzOnline", Type: "finanzonline"},
+		{ID: elda, Name: "ELDA", Type: "elda"},
+	}
+	targetFon, targetElda := uuid.New(), uuid.New()
+	types := map[uuid.UUID]string{targetFon: "finanzonline", targetElda: "elda"}
+
+	if err := handover.CheckMapping(accounts, map[uuid.UUID]uuid.UUID{fon: targetFon, elda: targetElda}, types); err != nil {
+		t.Fatalf("CheckMapping() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		mapping map[uuid.UUID]uuid.UUID
+		want    error
+	}{
+		{"missing account", map[uuid.UUID]uuid.UUID{fon: targetFon}, handover.ErrMappingIncomplete},
+		{"wrong type", map[uuid.UUID]uuid.UUID{fon: targetElda, elda: targetFon}, handover.ErrTargetAccount},
+		{"unknown target", map[uuid.UUID]uuid.UUID{fon: targetFon, elda: uuid.New()}, handover.ErrTargetAccount},
+		{"unknown source", map[uuid.UUID]uuid.UUID{fon: targetFon, elda: targetElda, uuid.New(): targetFon}, handover.ErrAccountNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := handover.CheckMapping(accounts, tt.mapping, types); !errors.Is(err, tt.want) {
+				t.Errorf("CheckMapping() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// Two source accounts of the same type cannot share a target
+	second := uuid.New()
+	twoFon := append(accounts[:1:1], handover.AccountSummary{ID: second, Name: "FinanzOnline 2", Type: "finanzonline"})
+	err := handover.CheckMapping(twoFon, map[uuid.UUID]uuid.UUID{fon: targetFon, second: targetFon}, types)
+	if !errors.Is(err, handover.ErrTargetAccountTwice) {
+		t.Errorf("shared target error = %v", err)
+	}
+}
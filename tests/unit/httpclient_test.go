@@ -0,0 +1,193 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/pkg/httpclient"
+)
+
+func testHTTPConfig(breakers *httpclient.Breakers) httpclient.Config {
+	cfg := httpclient.DefaultConfig("test")
+	cfg.Backoff = httpclient.Backoff{Initial: time.Millisecond, Max: 5 * time.Millisecond, Multiplier: 2}
+	cfg.Breakers = breakers
+	return cfg
+}
+
+func TestHTTPClientRetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("attempt %d sent body %q", calls.Load()+1, body)
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var attempts []*httpclient.Attempt
+	cfg := testHTTPConfig(httpclient.NewBreakers(httpclient.DefaultBreakerConfig()))
+	cfg.Hooks.AfterAttempt = func(a *httpclient.Attempt) { attempts = append(attempts, a) }
+	client := httpclient.New(cfg)
+
+	req, _ := http.NewRequest("POST", srv.URL, strings.NewReader("payload"))
+	resp, err := client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Do = %v, %v", resp, err)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "ok" {
+		t.Errorf("body %q", body)
+	}
+	if len(attempts) != 3 || !attempts[0].Retry || attempts[2].Retry || attempts[2].Number != 3 {
+		t.Errorf("unexpected attempts: %+v", attempts)
+	}
+
+	// Submissions are sent once; the failed response is returned as is
+	calls.Store(0)
+	req, _ = http.NewRequestWithContext(httpclient.WithoutRetry(context.Background()), "POST", srv.URL, strings.NewReader("payload"))
+	resp, err = client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("without retry: %v, %v after %d calls", resp, err, calls.Load())
+	}
+
+	// Client errors are not retried
+	calls.Store(0)
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer bad.Close()
+	req, _ = http.NewRequest("GET", bad.URL, nil)
+	if resp, err := client.Do(req); err != nil || resp.StatusCode != http.StatusBadRequest || calls.Load() != 1 {
+		t.Errorf("400: %v, %v after %d calls", resp, err, calls.Load())
+	}
+}
+
+func TestHTTPClientBudget(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	cfg := testHTTPConfig(httpclient.NewBreakers(httpclient.DefaultBreakerConfig()))
+	cfg.Budget = time.Second
+	client := httpclient.New(cfg)
+
+	// Retry-After exceeds the budget, so the 429 is returned at once
+	start := time.Now()
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Do = %v, %v", resp, err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("waited %v beyond the budget", elapsed)
+	}
+}
+
+func TestHTTPClientCircuitBreaker(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	breakers := httpclient.NewBreakers(httpclient.BreakerConfig{Threshold: 3, Cooldown: 50 * time.Millisecond})
+	cfg := testHTTPConfig(breakers)
+	cfg.MaxRetries = 1
+	client := httpclient.New(cfg)
+	get := func() (*http.Response, error) {
+		req, _ := http.NewRequest("GET", srv.URL, nil)
+		return client.Do(req)
+	}
+
+	// Two attempts, then the third failure opens the breaker
+	get()
+	if _, err := get(); !errors.Is(err, httpclient.ErrCircuitOpen) {
+		t.Fatalf("got %v, want ErrCircuitOpen", err)
+	}
+	if calls.Load() != 3 || breakers.State(host) != httpclient.StateOpen {
+		t.Fatalf("%d calls, state %s", calls.Load(), breakers.State(host))
+	}
+	if _, err := get(); !errors.Is(err, httpclient.ErrCircuitOpen) || calls.Load() != 3 {
+		t.Errorf("open breaker let a request through: %v", err)
+	}
+
+	// After the cooldown one probe is let through and closes it again
+	time.Sleep(60 * time.Millisecond)
+	down.Store(false)
+	if resp, err := get(); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("probe = %v, %v", resp, err)
+	}
+	if state := breakers.States()[host]; state != httpclient.StateClosed {
+		t.Errorf("state after probe = %s", state)
+	}
+}
+
+func TestHTTPClientBeforeAttemptAborts(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+
+	maintenance := errors.New("maintenance window")
+	var inMaintenance bool
+	cfg := testHTTPConfig(httpclient.NewBreakers(httpclient.DefaultBreakerConfig()))
+	cfg.Hooks.BeforeAttempt = func(req *http.Request) error {
+		if inMaintenance {
+			return maintenance
+		}
+		req.URL, _ = url.Parse(srv.URL + "/moved")
+		return nil
+	}
+	client := httpclient.New(cfg)
+
+	req, _ := http.NewRequest("GET", "http://example.invalid/original", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "/moved" {
+		t.Errorf("request not moved: %q", body)
+	}
+
+	inMaintenance = true
+	req, _ = http.NewRequest("GET", "http://example.invalid/original", nil)
+	if _, err := client.Do(req); !errors.Is(err, maintenance) || calls.Load() != 1 {
+		t.Errorf("got %v after %d calls", err, calls.Load())
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	b := httpclient.Backoff{Initial: time.Second, Max: 10 * time.Second, Multiplier: 2, Jitter: 0.2}
+	for n, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second} {
+		for i := 0; i < 20; i++ {
+			if d := b.Delay(n); d < want*8/10 || d > want*12/10 {
+				t.Fatalf("Delay(%d) = %v, want %v ±20%%", n, d, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"austrian-business-infrastructure/internal/atrust"
+	"austrian-business-infrastructure/internal/idaustria"
+)
+
+const mockRedirectURL = "http://localhost:8080/api/v1/sign/callback"
+
+func newMockIdP(t *testing.T) (*idaustria.MockProvider, *idaustria.Client) {
+	t.Helper()
+
+	var provider *idaustria.MockProvider
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provider.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	issuer := srv.URL + "/dev/idaustria"
+	provider, err := idaustria.NewMockProvider(issuer, "dev-client", "dev-secret", mockRedirectURL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return provider, idaustria.NewClient(issuer, "dev-client", "dev-secret", mockRedirectURL)
+}
+
+// mockLogin picks a persona on the mock login page and returns the callback query
+func mockLogin(t *testing.T, authURL, persona string) url.Values {
+	t.Helper()
+
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := noRedirect.Get(authURL + "&persona=" + persona)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("expected redirect, got %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return location.Query()
+}
+
+func TestMockIdPAuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	provider, client := newMockIdP(t)
+
+	authReq, err := client.CreateAuthorizationRequest("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	authURL, err := client.AuthorizationURL(ctx, authReq)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	callback := mockLogin(t, authURL, "erika")
+	if err := client.ValidateCallback(authReq.State, callback.Get("state"), callback.Get("code"), callback.Get("error"), ""); err != nil {
+		t.Fatalf("unexpected callback error: %v", err)
+	}
+
+	token, err := client.ExchangeCode(ctx, callback.Get("code"), authReq.CodeVerifier)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.IDToken == "" {
+		t.Error("expected an ID token")
+	}
+
+	info, err := client.GetUserInfo(ctx, token.AccessToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Name != "Erika Musterfrau" || info.DateOfBirth != "1975-06-15" {
+		t.Errorf("unexpected user info: %+v", info)
+	}
+	if len(info.BPK) != 28 || info.BPKType != idaustria.MockBPKType {
+		t.Errorf("expected a bPK in the real format, got %q (%s)", info.BPK, info.BPKType)
+	}
+	if provider.SubjectName(info.Subject) != "Erika Musterfrau" {
+		t.Error("expected the subject to resolve to the persona")
+	}
+
+	// Codes are single use
+	if _, err := client.ExchangeCode(ctx, callback.Get("code"), authReq.CodeVerifier); err == nil {
+		t.Error("expected a reused code to be rejected")
+	}
+}
+
+func TestMockIdPRejectsWrongVerifier(t *testing.T) {
+	ctx := context.Background()
+	_, client := newMockIdP(t)
+
+	authReq, _ := client.CreateAuthorizationRequest("")
+	authURL, err := client.AuthorizationURL(ctx, authReq)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	callback := mockLogin(t, authURL, "max")
+	_, err = client.ExchangeCode(ctx, callback.Get("code"), "wrong-verifier-wrong-verifier-wrong-verifier")
+	oidcErr, ok := err.(*idaustria.OIDCError)
+	if !ok || oidcErr.Code != idaustria.ErrCodeInvalidGrant {
+		t.Errorf("expected invalid_grant, got %v", err)
+	}
+
+	cancelled := mockLogin(t, authURL, "cancel")
+	if cancelled.Get("error") != idaustria.ErrCodeAccessDenied || cancelled.Get("state") != authReq.State {
+		t.Errorf("expected access_denied with state, got %v", cancelled)
+	}
+}
+
+func TestDevClientSignsWithTestCertificate(t *testing.T) {
+	ctx := context.Background()
+	provider, _ := newMockIdP(t)
+
+	dev, err := atrust.NewDevClient(atrust.WithSubjectNames(provider.SubjectName))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	doc := []byte("%PDF-1.7 test document")
+	digest := sha256.Sum256(doc)
+	resp, err := dev.Sign(ctx, &atrust.SignRequest{
+		DocumentHash:  atrust.HashDocument(doc),
+		HashAlgorithm: atrust.HashAlgoSHA256,
+		SignerCertID:  "subject-1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	der, _ := base64.StdEncoding.DecodeString(resp.Certificate)
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("expected a real certificate: %v", err)
+	}
+	if err := cert.CheckSignatureFrom(dev.CACertificate()); err != nil {
+		t.Errorf("expected the certificate to be issued by the test CA: %v", err)
+	}
+	if cert.KeyUsage&x509.KeyUsageContentCommitment == 0 {
+		t.Error("expected nonRepudiation key usage")
+	}
+
+	info, err := dev.GetCertificateInfo(ctx, "subject-1")
+	if err != nil || info.SubjectCN != cert.Subject.CommonName {
+		t.Errorf("expected certificate info of the same certificate, got %+v (%v)", info, err)
+	}
+
+	// Verify the detached CMS signature over its signed attributes
+	cms, _ := base64.StdEncoding.DecodeString(resp.Signature)
+	var contentInfo struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue `asn1:"explicit,tag:0"`
+	}
+	if _, err := asn1.Unmarshal(cms, &contentInfo); err != nil {
+		t.Fatalf("expected CMS ContentInfo: %v", err)
+	}
+	var signedData struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		EncapContentInfo asn1.RawValue
+		Certificates     asn1.RawValue
+		SignerInfos      []struct {
+			Version            int
+			SID                asn1.RawValue
+			DigestAlgorithm    asn1.RawValue
+			SignedAttrs        asn1.RawValue
+			SignatureAlgorithm asn1.RawValue
+			Signature          []byte
+		} `asn1:"set"`
+	}
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
+		t.Fatalf("expected CMS SignedData: %v", err)
+	}
+	if len(signedData.SignerInfos) != 1 {
+		t.Fatalf("expected one signer, got %d", len(signedData.SignerInfos))
+	}
+
+	signer := signedData.SignerInfos[0]
+	if !bytes.Contains(signer.SignedAttrs.Bytes, digest[:]) {
+		t.Error("expected the message digest in the signed attributes")
+	}
+	attrsSet := append([]byte{0x31}, signer.SignedAttrs.FullBytes[1:]...)
+	attrsHash := sha256.Sum256(attrsSet)
+	if !ecdsa.VerifyASN1(cert.PublicKey.(*ecdsa.PublicKey), attrsHash[:], signer.Signature) {
+		t.Error("expected the CMS signature to verify with the signer certificate")
+	}
+
+	if _, err := dev.Sign(ctx, &atrust.SignRequest{DocumentHash: hex.EncodeToString([]byte("short")), SignerCertID: "subject-1"}); err == nil {
+		t.Error("expected an invalid hash to be rejected")
+	}
+}
@@ -0,0 +1,103 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/config"
+	"austrian-business-infrastructure/internal/ingest"
+)
+
+func TestIngestValidateInput(t *testing.T) {
+	valid := func() *ingest.ItemInput {
+		return &ingest.ItemInput{AccountID: uuid.New(), Type: " beleg ", Title: "Rechnung 4711"}
+	}
+
+	in := valid()
+	if err := ingest.ValidateInput(in); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if in.Type != "beleg" {
+		t.Errorf("type = %q, want trimmed", in.Type)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*ingest.ItemInput)
+	}{
+		{"no account", func(in *ingest.ItemInput) { in.AccountID = uuid.Nil }},
+		{"no type", func(in *ingest.ItemInput) { in.Type = " " }},
+		{"no title", func(in *ingest.ItemInput) { in.Title = "" }},
+		{"long title", func(in *ingest.ItemInput) { in.Title = strings.Repeat("x", 501) }},
+		{"long external id", func(in *ingest.ItemInput) { in.ExternalID = strings.Repeat("x", 256) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := valid()
+			tt.mutate(in)
+			if err := ingest.ValidateInput(in); !errors.Is(err, ingest.ErrInvalidItem) {
+				t.Fatalf("expected ErrInvalidItem, got %v", err)
+			}
+		})
+	}
+}
+
+func TestIngestUploadURLSignature(t *testing.T) {
+	svc := ingest.NewService(nil, nil, nil, ingest.Config{
+		UploadBaseURL: "https://api.example.at/",
+		UploadKey:     []byte("upload-key"),
+	})
+	ctx := context.Background()
+	itemID := uuid.New()
+
+	raw := svc.UploadURL(itemID, time.Now().Add(time.Hour))
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u.Host != "api.example.at" || u.Path != "/api/v1/ingest/uploads/"+itemID.String() {
+		t.Fatalf("unexpected upload URL %s", raw)
+	}
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+	// The signature covers the item and the expiry
+	if _, err := svc.Upload(ctx, uuid.New(), expires, signature, "", strings.NewReader("x")); !errors.Is(err, ingest.ErrInvalidUploadURL) {
+		t.Errorf("other item: expected ErrInvalidUploadURL, got %v", err)
+	}
+	if _, err := svc.Upload(ctx, itemID, expires+"0", signature, "", strings.NewReader("x")); !errors.Is(err, ingest.ErrInvalidUploadURL) {
+		t.Errorf("extended expiry: expected ErrInvalidUploadURL, got %v", err)
+	}
+
+	other := ingest.NewService(nil, nil, nil, ingest.Config{UploadKey: []byte("other-key")})
+	if _, err := other.Upload(ctx, itemID, expires, signature, "", strings.NewReader("x")); !errors.Is(err, ingest.ErrInvalidUploadURL) {
+		t.Errorf("other key: expected ErrInvalidUploadURL, got %v", err)
+	}
+
+	// An expired URL is refused even with a valid signature
+	past := svc.UploadURL(itemID, time.Now().Add(-time.Minute))
+	u, _ = url.Parse(past)
+	if _, err := svc.Upload(ctx, itemID, u.Query().Get("expires"), u.Query().Get("signature"), "", strings.NewReader("x")); !errors.Is(err, ingest.ErrInvalidUploadURL) {
+		t.Errorf("expired: expected ErrInvalidUploadURL, got %v", err)
+	}
+}
+
+func TestIngestConfigUploadKey(t *testing.T) {
+	cfg := &config.IngestConfig{}
+	if cfg.UploadKey("") != nil {
+		t.Error("expected no key without secret and encryption key")
+	}
+	derived := cfg.UploadKey("0123456789abcdef0123456789abcdef")
+	if len(derived) != 32 || string(derived) == "0123456789abcdef0123456789abcdef" {
+		t.Error("expected a key derived from, not equal to, the encryption key")
+	}
+	cfg.UploadSecret = "secret"
+	if string(cfg.UploadKey("0123456789abcdef0123456789abcdef")) != "secret" {
+		t.Error("expected the upload secret to take precedence")
+	}
+}
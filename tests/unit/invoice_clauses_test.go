@@ -0,0 +1,126 @@
+package unit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/invoice"
+)
+
+func clauseCodes(clauses []*invoice.Clause) []string {
+	codes := make([]string, 0, len(clauses))
+	for _, c := range clauses {
+		codes = append(codes, c.Code)
+	}
+	return codes
+}
+
+func TestInvoiceRequiredClauses(t *testing.T) {
+	construction := invoice.BranchConstruction
+	margin := invoice.TaxCodeMarginUsedGoods
+
+	tests := []struct {
+		name     string
+		branch   *string
+		taxCode  *string
+		category string
+		expected []string
+	}{
+		{"standard rated", nil, nil, "S", nil},
+		{"reverse charge", nil, nil, "AE", []string{"reverse_charge"}},
+		{"reverse charge construction", &construction, nil, "AE", []string{"reverse_charge_construction"}},
+		{"intra-community supply", nil, nil, "K", []string{"intra_community_supply"}},
+		{"margin scheme", nil, &margin, "S", []string{"margin_used_goods"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := &invoice.Invoice{Branch: tt.branch, TaxCode: tt.taxCode}
+			items := []*invoice.InvoiceItem{{TaxCategory: tt.category}}
+
+			got := clauseCodes(invoice.RequiredClauses(inv, items, nil))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected clauses %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("expected clauses %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestInvoiceTenantClauseOverride(t *testing.T) {
+	inv := &invoice.Invoice{}
+	items := []*invoice.InvoiceItem{{TaxCategory: "AE"}}
+	tenantClauses := []*invoice.Clause{{
+		Code:        "reverse_charge",
+		Group:       "reverse_charge",
+		TaxCategory: "AE",
+		Text:        "Reverse Charge – die Steuerschuld geht auf den Leistungsempfänger über.",
+		Mandatory:   true,
+	}}
+
+	required := invoice.RequiredClauses(inv, items, tenantClauses)
+	if len(required) != 1 || required[0].Text != tenantClauses[0].Text {
+		t.Fatalf("expected tenant clause to override built-in, got %v", clauseCodes(required))
+	}
+	if required[0].BuiltIn {
+		t.Error("tenant clause must not be marked as built-in")
+	}
+}
+
+func TestInvoiceMissingClauses(t *testing.T) {
+	required := []*invoice.Clause{
+		{Code: "reverse_charge", Mandatory: true},
+		{Code: "hint", Mandatory: false},
+	}
+
+	if missing := invoice.MissingClauses(required, nil); len(missing) != 1 || missing[0] != "reverse_charge" {
+		t.Errorf("expected reverse_charge to be missing, got %v", missing)
+	}
+	if missing := invoice.MissingClauses(required, []string{"reverse_charge"}); len(missing) != 0 {
+		t.Errorf("expected no missing clauses, got %v", missing)
+	}
+}
+
+func TestInvoiceGeneratePDFContainsClauses(t *testing.T) {
+	construction := invoice.BranchConstruction
+	inv := &invoice.Invoice{
+		InvoiceNumber:      "RE-2026-0001",
+		InvoiceType:        "380",
+		IssueDate:          time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		Currency:           "EUR",
+		SellerName:         "Bau GmbH",
+		BuyerName:          "Generalunternehmer AG",
+		Branch:             &construction,
+		TaxExclusiveAmount: 123456,
+		PayableAmount:      123456,
+	}
+	items := []*invoice.InvoiceItem{{
+		LineNumber:  1,
+		Description: "Estricharbeiten",
+		Quantity:    1,
+		UnitPrice:   123456,
+		LineTotal:   123456,
+		TaxCategory: "AE",
+	}}
+
+	pdf, err := invoice.GeneratePDF(inv, items, invoice.RequiredClauses(inv, items, nil))
+	if err != nil {
+		t.Fatalf("GeneratePDF failed: %v", err)
+	}
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) {
+		t.Error("expected PDF header")
+	}
+	// "§ 19 Abs. 1a UStG" with § as WinAnsi octal escape
+	if !bytes.Contains(pdf, []byte(`\247 19 Abs. 1a UStG`)) {
+		t.Error("expected construction reverse charge clause in PDF")
+	}
+	if !bytes.Contains(pdf, []byte("1.234,56 EUR")) {
+		t.Error("expected Austrian amount formatting in PDF")
+	}
+}
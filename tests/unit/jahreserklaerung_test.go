@@ -0,0 +1,94 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/jahreserklaerung"
+	"austrian-business-infrastructure/internal/uva"
+)
+
+func jahresPerioden() []jahreserklaerung.Periode {
+	perioden := []jahreserklaerung.Periode{
+		{Periode: "Q1/2025", Quarter: 1, Kennzahlen: uva.UVAData{KZ000: 1003, KZ017: 1003, KZ060: 100}},
+		{Periode: "Q2/2025", Quarter: 2, Kennzahlen: uva.UVAData{KZ000: 1003, KZ017: 1003, KZ060: 100}},
+		{Periode: "07/2025", Month: 7, Kennzahlen: uva.UVAData{KZ000: 50_000, KZ001: 50_000}},
+	}
+	for i := range perioden {
+		perioden[i].SubmissionID = uuid.New()
+		perioden[i].Kennzahlen.KZ095 = uva.CalculateKZ095(&perioden[i].Kennzahlen)
+	}
+	return perioden
+}
+
+func TestJahreserklaerungEntwurfAndAbgleich(t *testing.T) {
+	perioden := jahresPerioden()
+
+	summe := jahreserklaerung.Summe(perioden)
+	if summe.KZ000 != 52_006 || summe.KZ017 != 2006 || summe.KZ060 != 200 {
+		t.Errorf("unexpected sum %+v", summe)
+	}
+	// 20% of 10.03 € is 2.00 € in each quarter, 4.01 € on the year
+	if summe.KZ095 != 2*(200-100) {
+		t.Errorf("expected the sum of the Zahllasten, got %d", summe.KZ095)
+	}
+
+	entwurf := jahreserklaerung.Entwurf(perioden)
+	if entwurf.KZ095 != 401-200 {
+		t.Errorf("expected the Zahllast calculated on the year, got %d", entwurf.KZ095)
+	}
+
+	entwurf.KZ060 += 50
+	entwurf.KZ095 = uva.CalculateKZ095(&entwurf)
+	abgleich := jahreserklaerung.Abgleichen(2025, entwurf, perioden)
+	if len(abgleich.Abweichungen) != 2 {
+		t.Fatalf("expected KZ060 and KZ095 to differ, got %+v", abgleich.Abweichungen)
+	}
+	if a := abgleich.Abweichungen[0]; a.Kennzahl != "KZ060" || a.Differenz != 50 || a.UVASumme != 200 {
+		t.Errorf("unexpected KZ060 difference %+v", a)
+	}
+	if a := abgleich.Abweichungen[1]; a.Kennzahl != "KZ095" || a.Differenz != 151-200 {
+		t.Errorf("unexpected KZ095 difference %+v", a)
+	}
+	if want := "08/2025,09/2025,10/2025,11/2025,12/2025"; strings.Join(abgleich.FehlendePerioden, ",") != want {
+		t.Errorf("expected missing periods %s, got %v", want, abgleich.FehlendePerioden)
+	}
+}
+
+func TestJahreserklaerungExport(t *testing.T) {
+	perioden := jahresPerioden()
+	e := &jahreserklaerung.Erklaerung{
+		Year:       2025,
+		Art:        jahreserklaerung.ArtU1,
+		Kennzahlen: jahreserklaerung.Entwurf(perioden),
+	}
+	e.Abgleich = jahreserklaerung.Abgleichen(2025, e.Kennzahlen, perioden)
+
+	xmlContent, err := fonws.GenerateU1XML(jahreserklaerung.U1(e))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<Jahr>2025</Jahr>", `<Kennzahl nr="000">520.06</Kennzahl>`, `<Kennzahl nr="095">2.01</Kennzahl>`} {
+		if !strings.Contains(string(xmlContent), want) {
+			t.Errorf("expected %s in XML:\n%s", want, xmlContent)
+		}
+	}
+	if strings.Contains(string(xmlContent), `nr="011"`) {
+		t.Error("expected Kennzahlen without amount to be left out")
+	}
+
+	csvContent, err := jahreserklaerung.WriteCSV(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(csvContent), "2025,U1,095,Zahllast/Gutschrift,2.01,2.00,0.01") {
+		t.Errorf("unexpected CSV:\n%s", csvContent)
+	}
+
+	if _, err := fonws.GenerateU1XML(&fonws.U1{Year: 2025, Kennzahlen: map[string]int64{"KZ060": -1}}); err == nil {
+		t.Error("expected negative input tax to be rejected")
+	}
+}
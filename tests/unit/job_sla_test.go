@@ -0,0 +1,56 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/job"
+)
+
+func TestParseSLAOverrides(t *testing.T) {
+	overrides, err := job.ParseSLAOverrides(" document_analysis=0.1/15m, databox_sync=0.02/2m,")
+	if err != nil {
+		t.Fatalf("ParseSLAOverrides() error = %v", err)
+	}
+	if len(overrides) != 2 {
+		t.Fatalf("ParseSLAOverrides() = %v, want 2 entries", overrides)
+	}
+	if sla := overrides["document_analysis"]; sla.MaxFailureRate != 0.1 || sla.MaxP95 != 15*time.Minute {
+		t.Errorf("document_analysis = %+v", sla)
+	}
+
+	for _, invalid := range []string{"databox_sync", "=0.1/1m", "databox_sync=0.1", "databox_sync=1.5/1m", "databox_sync=0.1/soon"} {
+		if _, err := job.ParseSLAOverrides(invalid); err == nil {
+			t.Errorf("ParseSLAOverrides(%q) succeeded, want error", invalid)
+		}
+	}
+}
+
+func TestSLAEvaluate(t *testing.T) {
+	config := job.SLAConfig{
+		Default: job.SLA{MaxFailureRate: 0.05, MaxP95: 10 * time.Minute},
+		Types:   map[string]job.SLA{"document_analysis": {MaxFailureRate: 0.2, MaxP95: 0}},
+		Window:  24 * time.Hour,
+		MinRuns: 20,
+	}
+	statuses := config.Evaluate([]job.TypeStats{
+		{Type: "databox_sync", Runs: 100, FailureRate: 0.08, P95Ms: 11 * 60 * 1000},
+		{Type: "document_analysis", Runs: 100, FailureRate: 0.08, P95Ms: 60 * 60 * 1000},
+		{Type: "watchlist_check", Runs: 3, FailureRate: 1},
+	})
+	if len(statuses) != 3 {
+		t.Fatalf("Evaluate() returned %d statuses, want 3", len(statuses))
+	}
+
+	if b := statuses[0].Breaches; len(b) != 2 || b[0].Metric != job.MetricFailureRate || b[1].Metric != job.MetricP95Duration || b[1].Threshold != 600000 {
+		t.Errorf("databox_sync breaches = %+v, want failure rate and P95", b)
+	}
+	// The override allows more failures and disables the P95 check
+	if !statuses[1].Judged || len(statuses[1].Breaches) != 0 {
+		t.Errorf("document_analysis = %+v, want judged without breaches", statuses[1])
+	}
+	// Too few runs to be judged
+	if statuses[2].Judged || len(statuses[2].Breaches) != 0 {
+		t.Errorf("watchlist_check = %+v, want not judged", statuses[2])
+	}
+}
@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/kammerumlage"
+	"austrian-business-infrastructure/internal/mail"
+)
+
+func TestKammerumlageUmlage(t *testing.T) {
+	if got := kammerumlage.Umlage(kammerumlage.Freigrenze); got != 0 {
+		t.Errorf("expected no KU1 within the Freigrenze, got %d", got)
+	}
+	if got := kammerumlage.Umlage(kammerumlage.Freigrenze + 1); got != 4350 {
+		t.Errorf("expected 0.29%% of the whole basis above the Freigrenze, got %d", got)
+	}
+	// 3 Mio. € at 0.29%, the next 1 Mio. € at 0.2755%
+	if got := kammerumlage.Umlage(400_000_000); got != 1_145_500 {
+		t.Errorf("expected 1145500, got %d", got)
+	}
+}
+
+func TestKammerumlageBerechne(t *testing.T) {
+	perioden := []kammerumlage.Periode{
+		{Periode: "01/2025", Quarter: 1, SubmissionID: uuid.New(), Vorsteuer: 500_000},
+		{Periode: "02/2025", Quarter: 1, SubmissionID: uuid.New(), Vorsteuer: 400_000},
+		{Periode: "03/2025", Quarter: 1, SubmissionID: uuid.New(), Vorsteuer: 300_000},
+		{Periode: "04/2025", Quarter: 2, SubmissionID: uuid.New(), Vorsteuer: 600_000},
+		{Periode: "06/2025", Quarter: 2, SubmissionID: uuid.New(), Vorsteuer: 200_000},
+	}
+
+	q1 := kammerumlage.Berechne(2025, 1, perioden)
+	if !q1.Freigrenze || q1.Umlage != 0 || q1.Bemessungsgrundlage != 1_200_000 || len(q1.Perioden) != 3 {
+		t.Errorf("Q1: expected 12,000 € within the Freigrenze, got %+v", q1)
+	}
+
+	// The year exceeds the Freigrenze in Q2, which pays the KU1 of Q1 as well
+	q2 := kammerumlage.Berechne(2025, 2, perioden)
+	if q2.Freigrenze || q2.Bemessungsgrundlage != 800_000 || q2.BemessungsgrundlageJahr != 2_000_000 {
+		t.Errorf("Q2: unexpected basis %+v", q2)
+	}
+	if q2.UmlageJahr != 5800 || q2.UmlageVorquartale != 0 || q2.Umlage != 5800 {
+		t.Errorf("Q2: expected a KU1 of 5800 for the year so far, got %+v", q2)
+	}
+	if len(q2.FehlendePerioden) != 1 || q2.FehlendePerioden[0] != "05/2025" {
+		t.Errorf("Q2: expected 05/2025 to be missing, got %v", q2.FehlendePerioden)
+	}
+
+	quarterly := kammerumlage.Berechne(2025, 1, []kammerumlage.Periode{{Periode: "Q1/2025", Quarter: 1, Vorsteuer: 2_000_000}})
+	if quarterly.Umlage != 5800 || len(quarterly.FehlendePerioden) != 0 {
+		t.Errorf("quarterly filer: unexpected %+v", quarterly)
+	}
+}
+
+func TestKammerumlageFristen(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	for _, tc := range []struct {
+		quarter int
+		want    time.Time
+	}{
+		{1, date(2025, 5, 15)},
+		{2, date(2025, 8, 18)}, // 15 August is a holiday, followed by the weekend
+		{4, date(2026, 2, 16)}, // 15 February 2026 is a Sunday
+	} {
+		if got := kammerumlage.Faelligkeit(2025, tc.quarter); !got.Equal(tc.want) {
+			t.Errorf("Q%d: expected %s, got %s", tc.quarter, tc.want.Format("2006-01-02"), got.Format("2006-01-02"))
+		}
+	}
+
+	if year, quarter, days, ok := kammerumlage.ReminderQuarter(date(2026, 2, 2)); !ok || year != 2025 || quarter != 4 || days != 14 {
+		t.Errorf("expected a reminder for Q4/2025 14 days ahead, got %d Q%d %d %v", year, quarter, days, ok)
+	}
+	if _, _, _, ok := kammerumlage.ReminderQuarter(date(2025, 5, 2)); ok {
+		t.Error("expected no reminder 13 days before the deadline")
+	}
+
+	renderer, err := mail.NewRenderer("Austrian Business Platform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := renderer.Render(mail.TemplateKammerumlageFrist, email.KammerumlageFristParams{
+		RecipientName: "Anna Huber",
+		Periode:       "Q1/2025",
+		Faellig:       "15.05.2025",
+		DaysLeft:      7,
+		Umlagen:       []email.KammerumlageBetrag{{Name: "Muster GmbH", Umlage: "58.00 EUR"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered.Subject != "Kammerumlage Q1/2025: fällig am 15.05.2025" {
+		t.Errorf("unexpected subject %q", rendered.Subject)
+	}
+	for _, want := range []string{"in 7 Tagen, am 15.05.2025", "- Muster GmbH: 58.00 EUR"} {
+		if !strings.Contains(rendered.Text, want) {
+			t.Errorf("expected %q in text:\n%s", want, rendered.Text)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/kleinunternehmer"
+	"austrian-business-infrastructure/internal/refdata"
+)
+
+func TestKleinunternehmerEvaluate(t *testing.T) {
+	threshold := refdata.ForYear(2025).KleinunternehmerGrenze
+
+	tests := []struct {
+		name     string
+		revenue  int64
+		previous int64
+		asOf     time.Time
+		level    string
+		breach   bool
+	}{
+		{"low run-rate", 1000000, 0, time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), kleinunternehmer.LevelOK, false},
+		{"run-rate projects breach", 3000000, 0, time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), kleinunternehmer.LevelWatch, true},
+		{"warn percent reached", 4500000, 0, time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), kleinunternehmer.LevelWarning, false},
+		{"breach within 30 days", 5000000, 0, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), kleinunternehmer.LevelCritical, true},
+		{"threshold crossed", 5500001, 0, time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), kleinunternehmer.LevelExceeded, false},
+		{"previous year crossed", 100000, 6000000, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), kleinunternehmer.LevelExceeded, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := kleinunternehmer.Evaluate(tt.revenue, tt.previous, threshold, kleinunternehmer.DefaultWarnPercent, tt.asOf)
+			if status.Level != tt.level {
+				t.Errorf("expected level %s, got %s (used %.2f%%)", tt.level, status.Level, status.UsedPercent)
+			}
+			if (status.ProjectedBreachDate != nil) != tt.breach {
+				t.Errorf("expected breach projection %v, got %v", tt.breach, status.ProjectedBreachDate)
+			}
+			if status.Level != kleinunternehmer.LevelOK && len(status.Guidance) == 0 {
+				t.Error("expected guidance for non-ok level")
+			}
+		})
+	}
+}
+
+func TestKleinunternehmerBreachDateProjection(t *testing.T) {
+	// 100 days at 100 EUR/day: 10.000 EUR, remaining 45.000 EUR need 451 more days -> no breach this year
+	asOf := time.Date(2025, 4, 10, 0, 0, 0, 0, time.UTC)
+	status := kleinunternehmer.Evaluate(1000000, 0, refdata.ForYear(2025).KleinunternehmerGrenze, 80, asOf)
+	if status.DailyRunRate != 10000 {
+		t.Errorf("expected daily run-rate 10000, got %d", status.DailyRunRate)
+	}
+	if status.ProjectedBreachDate != nil {
+		t.Errorf("expected no breach this year, got %s", *status.ProjectedBreachDate)
+	}
+
+	// 200 days at 200 EUR/day: 40.000 EUR, remaining 15.000,01 EUR need 76 more days
+	asOf = time.Date(2025, 7, 19, 0, 0, 0, 0, time.UTC)
+	status = kleinunternehmer.Evaluate(4000000, 0, refdata.ForYear(2025).KleinunternehmerGrenze, 80, asOf)
+	if status.ProjectedBreachDate == nil || *status.ProjectedBreachDate != "2025-10-03" {
+		t.Errorf("expected breach on 2025-10-03, got %v", status.ProjectedBreachDate)
+	}
+}
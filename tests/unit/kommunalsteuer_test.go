@@ -0,0 +1,166 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/kommunalsteuer"
+	"austrian-business-infrastructure/internal/mail"
+)
+
+func kommStDate(y int, m time.Month, d int) *time.Time {
+	t := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestBerechneKommunalsteuer(t *testing.T) {
+	betriebsstaetten := []kommunalsteuer.Betriebsstaette{
+		{Gemeindekennziffer: "40101", Bezeichnung: "Linz"},
+		{Gemeindekennziffer: kommunalsteuer.GemeindeWien, Bezeichnung: "Wien", SVNummern: []string{"1234150189", "2222", "3333", "4444"}},
+	}
+	positions := []kommunalsteuer.Position{
+		// Not listed, so in Linz
+		{Monat: 1, SVNummer: "1111", Beitragsgruppe: "A1", Entgelt: 300000},
+		// Born 1989 (from the SV-Nummer), the whole month
+		{Monat: 1, SVNummer: "1234150189", Beitragsgruppe: "A1", Entgelt: 250000},
+		{Monat: 1, SVNummer: "2222", Beitragsgruppe: "L1", Entgelt: 80000},
+		{Monat: 1, SVNummer: "3333", Beitragsgruppe: "A1", Entgelt: 100000, Geburtsdatum: kommStDate(1990, 5, 1), Von: kommStDate(2025, 1, 15)},
+		// 55 years old on 31 December
+		{Monat: 1, SVNummer: "4444", Beitragsgruppe: "A1", Entgelt: 50000, Geburtsdatum: kommStDate(1969, 12, 31)},
+		// Below the Freigrenze
+		{Monat: 2, SVNummer: "1111", Beitragsgruppe: "A1", Entgelt: 120000},
+	}
+
+	b := kommunalsteuer.Berechne(2025, positions, betriebsstaetten)
+	if len(b.Monate) != 2 {
+		t.Fatalf("expected 2 months, got %d", len(b.Monate))
+	}
+
+	jan, feb := b.Monate[0], b.Monate[1]
+	if jan.Bemessungsgrundlage != 780000 || jan.Freibetrag != 0 || jan.Kommunalsteuer != 23400 {
+		t.Errorf("unexpected January %+v", jan)
+	}
+	if dga := jan.Dienstgeberabgabe; dga == nil || dga.Dienstnehmer != 2 || dga.Befreit != 2 || dga.Wochen != 7 || dga.Abgabe != 4200 {
+		t.Errorf("expected 4 + 3 weeks of 2 employees at 6 €, got %+v", dga)
+	}
+	if feb.Freibetrag != kommunalsteuer.KommStFreibetrag || feb.Kommunalsteuer != 315 {
+		t.Errorf("expected the Freibetrag in February, got %+v", feb)
+	}
+
+	if b.Bemessungsgrundlage != 900000 || b.Kommunalsteuer != 23715 {
+		t.Errorf("unexpected year totals %d %d", b.Bemessungsgrundlage, b.Kommunalsteuer)
+	}
+	if b.Gemeinden[0].Kommunalsteuer != 9315 || b.Gemeinden[1].Bemessungsgrundlage != 480000 || b.Gemeinden[1].Kommunalsteuer != 14400 {
+		t.Errorf("unexpected Gemeinden %+v", b.Gemeinden)
+	}
+	if b.Dienstgeberabgabe == nil || b.Dienstgeberabgabe.Abgabe != 4200 {
+		t.Errorf("unexpected Dienstgeberabgabe %+v", b.Dienstgeberabgabe)
+	}
+
+	// The Freibetrag is split by share, so the Gemeinden add up to 3% of
+	// the reduced total
+	split := kommunalsteuer.Berechne(2025, []kommunalsteuer.Position{
+		{Monat: 3, SVNummer: "1111", Entgelt: 100000},
+		{Monat: 3, SVNummer: "1234150189", Entgelt: 40000},
+	}, betriebsstaetten)
+	if m := split.Monate[0]; m.Gemeinden[0].Kommunalsteuer != 654 || m.Gemeinden[1].Kommunalsteuer != 261 || m.Kommunalsteuer != 915 {
+		t.Errorf("unexpected split of the Freibetrag %+v", m)
+	}
+
+	xmlContent, err := fonws.GenerateKommStXML(kommunalsteuer.ToKommSt(2025, b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<Gemeindekennziffer>40101</Gemeindekennziffer>", "<Kommunalsteuer>93.15</Kommunalsteuer>", `<Monat nr="02">`} {
+		if !strings.Contains(string(xmlContent), want) {
+			t.Errorf("expected %q in XML:\n%s", want, xmlContent)
+		}
+	}
+	if _, err := kommunalsteuer.GenerateDGAXML(2025, kommunalsteuer.Berechne(2025, positions, betriebsstaetten[:1])); !errors.Is(err, kommunalsteuer.ErrNoDienstgeberabgabe) {
+		t.Errorf("expected no Dienstgeberabgabe outside of Wien, got %v", err)
+	}
+}
+
+func TestDienstgeberabgabeWochen(t *testing.T) {
+	cases := []struct {
+		von, bis *time.Time
+		want     int
+	}{
+		{nil, nil, 4},
+		{kommStDate(2025, 2, 5), nil, 4}, // started week and 3 Mondays
+		{kommStDate(2025, 2, 5), kommStDate(2025, 2, 9), 1},
+		{kommStDate(2025, 2, 10), nil, 3},
+		{kommStDate(2025, 2, 1), nil, 4},
+		{nil, kommStDate(2025, 1, 31), 0},
+	}
+	for _, c := range cases {
+		if got := kommunalsteuer.Wochen(2025, 2, c.von, c.bis); got != c.want {
+			t.Errorf("Wochen(%v, %v) = %d, want %d", c.von, c.bis, got, c.want)
+		}
+	}
+
+	if kommunalsteuer.DGAWochensatz(2024) != 200 || kommunalsteuer.DGAWochensatz(2025) != 600 {
+		t.Error("expected 2 € until 2024 and 6 € from 2025")
+	}
+	hours := 10.0
+	if !kommunalsteuer.DGABefreit(kommunalsteuer.Position{SVNummer: "1111", Beitragsgruppe: "A1", Wochenstunden: &hours}, *kommStDate(2025, 2, 1)) {
+		t.Error("expected employees working 10 hours a week to be exempt")
+	}
+}
+
+func TestKommunalsteuerFristen(t *testing.T) {
+	cases := []struct {
+		got, want time.Time
+	}{
+		{kommunalsteuer.ErklaerungFrist(2025), *kommStDate(2026, 3, 31)},
+		// Saturday, then Ostermontag
+		{kommunalsteuer.ErklaerungFrist(2028), *kommStDate(2029, 4, 3)},
+		// Mariä Himmelfahrt is a Friday
+		{kommunalsteuer.ZahlungFrist(2025, 7), *kommStDate(2025, 8, 18)},
+		{kommunalsteuer.ZahlungFrist(2025, 12), *kommStDate(2026, 1, 15)},
+	}
+	for _, c := range cases {
+		if !c.got.Equal(c.want) {
+			t.Errorf("expected %s, got %s", c.want.Format("2006-01-02"), c.got.Format("2006-01-02"))
+		}
+	}
+
+	fristen := kommunalsteuer.Fristen(2025)
+	if len(fristen) != 26 || fristen[25].Art != kommunalsteuer.FristDGAErklaerung || fristen[25].Periode != "2025" {
+		t.Errorf("expected the monthly payments and the returns of 2025, got %d deadlines", len(fristen))
+	}
+
+	if year, days, ok := kommunalsteuer.ReminderYear(*kommStDate(2026, 3, 1)); !ok || year != 2025 || days != 30 {
+		t.Errorf("expected a reminder 30 days before the deadline, got %d %d %v", year, days, ok)
+	}
+	if _, _, ok := kommunalsteuer.ReminderYear(*kommStDate(2026, 3, 2)); ok {
+		t.Error("expected no reminder 29 days before the deadline")
+	}
+
+	renderer, err := mail.NewRenderer("Austrian Business Platform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := renderer.Render(mail.TemplateKommunalsteuerFrist, email.KommunalsteuerFristParams{
+		RecipientName: "Anna Huber",
+		Year:          2025,
+		Faellig:       "31.03.2026",
+		DaysLeft:      1,
+		Dienstgeber:   []string{"Muster GmbH"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered.Subject != "Kommunalsteuer 2025: Erklärung fällig am 31.03.2026" {
+		t.Errorf("unexpected subject %q", rendered.Subject)
+	}
+	for _, want := range []string{"morgen, am 31.03.2026", "- Muster GmbH"} {
+		if !strings.Contains(rendered.Text, want) {
+			t.Errorf("expected %q in text:\n%s", want, rendered.Text)
+		}
+	}
+}
@@ -0,0 +1,459 @@
+package unit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/mail"
+	"github.com/google/uuid"
+)
+
+// fakeMailProvider records sent messages
+type fakeMailProvider struct {
+	sent []*mail.Message
+}
+
+func (p *fakeMailProvider) Name() string { return "fake" }
+
+func (p *fakeMailProvider) Send(ctx context.Context, msg *mail.Message) (string, error) {
+	p.sent = append(p.sent, msg)
+	return fmt.Sprintf("<%d@test>", len(p.sent)), nil
+}
+
+// fakeMailStore keeps identities, suppressions and events in memory
+type fakeMailStore struct {
+	identities   map[uuid.UUID]*mail.SenderIdentity
+	suppressions []*mail.Suppression
+	events       []*mail.Event
+}
+
+func newFakeMailStore() *fakeMailStore {
+	return &fakeMailStore{identities: make(map[uuid.UUID]*mail.SenderIdentity)}
+}
+
+func (s *fakeMailStore) GetIdentity(ctx context.Context, tenantID uuid.UUID) (*mail.SenderIdentity, error) {
+	if i, ok := s.identities[tenantID]; ok {
+		return i, nil
+	}
+	return nil, mail.ErrIdentityNotFound
+}
+
+func (s *fakeMailStore) UpsertIdentity(ctx context.Context, tenantID uuid.UUID, fromAddress, fromName, replyTo, domain string) (*mail.SenderIdentity, error) {
+	i := &mail.SenderIdentity{TenantID: tenantID, FromAddress: fromAddress, FromName: fromName, ReplyTo: replyTo, Domain: domain}
+	s.identities[tenantID] = i
+	return i, nil
+}
+
+func (s *fakeMailStore) UpdateVerification(ctx context.Context, tenantID uuid.UUID, check *mail.DNSCheck) (*mail.SenderIdentity, error) {
+	i := s.identities[tenantID]
+	i.SPFVerified, i.DKIMVerified, i.DMARCPresent = check.SPF, check.DKIM, check.DMARC
+	return i, nil
+}
+
+func (s *fakeMailStore) DeleteIdentity(ctx context.Context, tenantID uuid.UUID) error {
+	delete(s.identities, tenantID)
+	return nil
+}
+
+func (s *fakeMailStore) FindSuppression(ctx context.Context, tenantID *uuid.UUID, email string) (*mail.Suppression, error) {
+	for _, sup := range s.suppressions {
+		scoped := sup.TenantID == nil || (tenantID != nil && *sup.TenantID == *tenantID)
+		active := sup.ExpiresAt == nil || sup.ExpiresAt.After(time.Now())
+		if scoped && active && strings.EqualFold(sup.Email, email) {
+			return sup, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *fakeMailStore) AddSuppression(ctx context.Context, sup *mail.Suppression) error {
+	s.suppressions = append(s.suppressions, sup)
+	return nil
+}
+
+func (s *fakeMailStore) ListSuppressions(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]*mail.Suppression, int, error) {
+	return s.suppressions, len(s.suppressions), nil
+}
+
+func (s *fakeMailStore) DeleteSuppression(ctx context.Context, tenantID uuid.UUID, email string) (bool, error) {
+	return false, nil
+}
+
+func (s *fakeMailStore) RecordEvent(ctx context.Context, e *mail.Event) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *fakeMailStore) CountEvents(ctx context.Context, email, eventType string, since time.Time) (int, error) {
+	count := 0
+	for _, e := range s.events {
+		if e.Email == email && e.Type == eventType {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func newTestMailService(t *testing.T) (*mail.Service, *fakeMailProvider, *fakeMailStore) {
+	t.Helper()
+	renderer, err := mail.NewRenderer("Austrian Business Platform")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	provider := &fakeMailProvider{}
+	store := newFakeMailStore()
+	return mail.NewService(provider, store, renderer, mail.ServiceConfig{
+		From:     "noreply@platform.example",
+		FromName: "Austrian Business Platform",
+	}), provider, store
+}
+
+func TestMailServiceRespectsSuppressions(t *testing.T) {
+	ctx := context.Background()
+	service, provider, store := newTestMailService(t)
+	tenantA, tenantB := uuid.New(), uuid.New()
+
+	store.suppressions = append(store.suppressions,
+		&mail.Suppression{Email: "bounced@kunde.at", Reason: mail.ReasonHardBounce},
+		&mail.Suppression{TenantID: &tenantA, Email: "optout@kunde.at", Reason: mail.ReasonManual},
+	)
+
+	err := service.Send(ctx, &mail.Message{TenantID: &tenantB, To: "Bounced@Kunde.at", Subject: "Test", Text: "x"})
+	if !errors.Is(err, mail.ErrSuppressed) {
+		t.Errorf("expected a global suppression to apply to every tenant, got %v", err)
+	}
+	if err := service.Send(ctx, &mail.Message{TenantID: &tenantA, To: "optout@kunde.at", Subject: "Test", Text: "x"}); !errors.Is(err, mail.ErrSuppressed) {
+		t.Errorf("expected the tenant's suppression to apply, got %v", err)
+	}
+	if err := service.Send(ctx, &mail.Message{TenantID: &tenantB, To: "optout@kunde.at", Subject: "Test", Text: "x"}); err != nil {
+		t.Errorf("expected another tenant's suppression not to apply, got %v", err)
+	}
+	if len(provider.sent) != 1 {
+		t.Fatalf("expected 1 message sent, got %d", len(provider.sent))
+	}
+
+	// The email service goes through the same check
+	emailService := email.NewMailService(service)
+	if err := emailService.SendPasswordReset(ctx, "bounced@kunde.at", email.PasswordResetParams{ResetURL: "https://app.example/reset"}); !errors.Is(err, mail.ErrSuppressed) {
+		t.Errorf("expected the email service to respect suppressions, got %v", err)
+	}
+
+	if err := service.Send(ctx, &mail.Message{To: "kein-email", Subject: "Test"}); !errors.Is(err, mail.ErrInvalidAddress) {
+		t.Errorf("expected an invalid address to be rejected, got %v", err)
+	}
+}
+
+func TestMailServiceSenderIdentity(t *testing.T) {
+	ctx := context.Background()
+	service, provider, store := newTestMailService(t)
+	tenantID := uuid.New()
+
+	view, err := service.SetSenderIdentity(ctx, tenantID, &mail.SenderIdentityInput{
+		FromAddress: "Office@Steuerberater-Huber.at",
+		FromName:    "Kanzlei  Huber",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if view.Domain != "steuerberater-huber.at" || view.FromName != "Kanzlei Huber" || len(view.Records) != 3 {
+		t.Errorf("unexpected identity: %+v", view)
+	}
+
+	// Unverified: platform address, tenant address as Reply-To
+	if err := service.Send(ctx, &mail.Message{TenantID: &tenantID, To: "klient@example.at", Subject: "Test", Text: "x"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg := provider.sent[0]
+	if !strings.Contains(msg.From, "noreply@platform.example") || !strings.Contains(msg.From, "Kanzlei Huber via") {
+		t.Errorf("expected the platform address until the domain is verified, got %q", msg.From)
+	}
+	if msg.ReplyTo != "office@steuerberater-huber.at" {
+		t.Errorf("expected the tenant address as Reply-To, got %q", msg.ReplyTo)
+	}
+
+	// Verified: tenant address
+	store.identities[tenantID].SPFVerified = true
+	store.identities[tenantID].DKIMVerified = true
+	if err := service.Send(ctx, &mail.Message{TenantID: &tenantID, To: "klient@example.at", Subject: "Test", Text: "x"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if from := provider.sent[1].From; from != `"Kanzlei Huber" <office@steuerberater-huber.at>` {
+		t.Errorf("expected the tenant address once verified, got %q", from)
+	}
+}
+
+func TestMailServiceHandleEvents(t *testing.T) {
+	ctx := context.Background()
+	service, _, store := newTestMailService(t)
+
+	for i := 0; i < mail.SoftBounceThreshold-1; i++ {
+		if err := service.HandleEvent(ctx, &mail.Event{Provider: "ses", Type: mail.EventSoftBounce, Email: "voll@example.at"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(store.suppressions) != 0 {
+		t.Fatal("expected soft bounces below the threshold not to suppress")
+	}
+	service.HandleEvent(ctx, &mail.Event{Provider: "ses", Type: mail.EventSoftBounce, Email: "voll@example.at"})
+	if len(store.suppressions) != 1 || store.suppressions[0].ExpiresAt == nil {
+		t.Fatalf("expected a temporary suppression after repeated soft bounces, got %+v", store.suppressions)
+	}
+
+	service.HandleEvent(ctx, &mail.Event{Provider: "mailgun", Type: mail.EventComplaint, Email: "Spam@Example.at"})
+	last := store.suppressions[len(store.suppressions)-1]
+	if last.Reason != mail.ReasonComplaint || last.TenantID != nil || last.ExpiresAt != nil || last.Email != "spam@example.at" {
+		t.Errorf("expected a permanent global complaint suppression, got %+v", last)
+	}
+}
+
+func TestMailRendererTemplates(t *testing.T) {
+	renderer, err := mail.NewRenderer("Austrian Business Platform")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rendered, err := renderer.Render(mail.TemplateSignatureRequest, email.SignatureRequestParams{
+		SignerName:     "Max Mustermann",
+		RequesterName:  "Anna Huber",
+		CompanyName:    "Huber & Partner",
+		DocumentTitle:  "Werkvertrag <2026>",
+		SigningURL:     "https://portal.example/sign/abc",
+		ExpiresAt:      "30.10.2026",
+		SignerPosition: 1,
+		TotalSigners:   2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rendered.Subject != "Signaturanfrage: Werkvertrag <2026>" {
+		t.Errorf("unexpected subject %q", rendered.Subject)
+	}
+	for _, want := range []string{"Guten Tag Max Mustermann,", "Sie sind Unterzeichner 1 von 2.", "gültig bis: 30.10.2026", "Mit freundlichen Grüßen"} {
+		if !strings.Contains(rendered.Text, want) {
+			t.Errorf("expected %q in text:\n%s", want, rendered.Text)
+		}
+	}
+	if strings.Contains(rendered.Text, "Nachricht von") || strings.Contains(rendered.Text, "Stand der Signaturanfrage") {
+		t.Error("expected optional sections to be omitted")
+	}
+	if !strings.Contains(rendered.HTML, "Werkvertrag &lt;2026&gt;") || strings.Contains(rendered.HTML, "<2026>") {
+		t.Error("expected the HTML part to escape the text")
+	}
+	if !strings.Contains(rendered.HTML, `<a href="https://portal.example/sign/abc"`) {
+		t.Error("expected URLs to become links in the HTML part")
+	}
+
+	if _, err := renderer.Render("unknown", nil); !errors.Is(err, mail.ErrUnknownTemplate) {
+		t.Errorf("expected ErrUnknownTemplate, got %v", err)
+	}
+}
+
+// fakeBrandProvider returns a fixed brand per tenant
+type fakeBrandProvider map[uuid.UUID]*mail.Brand
+
+func (p fakeBrandProvider) MailBrand(ctx context.Context, tenantID uuid.UUID) (*mail.Brand, error) {
+	return p[tenantID], nil
+}
+
+func TestMailServiceTenantBrand(t *testing.T) {
+	ctx := context.Background()
+	service, provider, store := newTestMailService(t)
+	tenantID := uuid.New()
+	service.SetBrandProvider(fakeBrandProvider{tenantID: {
+		Name:       "Kanzlei Huber",
+		SenderName: "Kanzlei Huber Steuerberatung",
+		LogoURL:    "https://api.example/api/v1/public/branding/x/logo?v=1",
+		Color:      "#1A5E20",
+		Footer:     "Kanzlei Huber, Wien",
+	}})
+
+	emailService := email.NewMailService(service)
+	params := email.SignatureExpiredParams{TenantID: &tenantID, RecipientName: "Max", DocumentTitle: "Vertrag", ExpiredAt: "01.11.2026"}
+	if err := emailService.SendSignatureExpired(ctx, "klient@example.at", params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg := provider.sent[0]
+	if msg.From != `"Kanzlei Huber Steuerberatung via Austrian Business Platform" <noreply@platform.example>` {
+		t.Errorf("expected the brand's sender name on the platform address, got %q", msg.From)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(msg.Text), "Kanzlei Huber") {
+		t.Errorf("expected the brand to sign the mail:\n%s", msg.Text)
+	}
+	for _, want := range []string{`<img src="https://api.example/api/v1/public/branding/x/logo?v=1" alt="Kanzlei Huber"`, "solid #1A5E20", "Kanzlei Huber, Wien"} {
+		if !strings.Contains(msg.HTML, want) {
+			t.Errorf("expected %q in the HTML part", want)
+		}
+	}
+
+	// The identity's name wins over the brand's sender name
+	store.identities[tenantID] = &mail.SenderIdentity{TenantID: tenantID, FromAddress: "office@huber.at", FromName: "Huber"}
+	if err := emailService.SendSignatureExpired(ctx, "klient@example.at", params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if from := provider.sent[1].From; !strings.HasPrefix(from, `"Huber via`) {
+		t.Errorf("expected the identity's name, got %q", from)
+	}
+
+	// Platform mails keep the platform look
+	params.TenantID = nil
+	if err := emailService.SendSignatureExpired(ctx, "klient@example.at", params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if html := provider.sent[2].HTML; strings.Contains(html, "<img") || !strings.Contains(html, "#c8102e") {
+		t.Error("expected mails without tenant in the platform look")
+	}
+}
+
+func TestBuildMIME(t *testing.T) {
+	raw, err := mail.BuildMIME(&mail.Message{
+		From:    `"Kanzlei Huber" <office@huber.at>`,
+		To:      "max@example.at",
+		ToName:  "Max Müller",
+		Subject: "Bestätigung\r\nBcc: victim@example.com",
+		Text:    "Grüße",
+		HTML:    "<p>Grüße</p>",
+	}, "<1@huber.at>", time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	header, body, _ := strings.Cut(string(raw), "\r\n\r\n")
+	if strings.Contains(header, "\r\nBcc:") {
+		t.Error("expected line breaks in the subject not to inject headers")
+	}
+	if !strings.Contains(header, "Subject: =?utf-8?q?") || !strings.Contains(header, "To: =?utf-8?q?Max_M=C3=BCller?= <max@example.at>") {
+		t.Errorf("expected encoded headers:\n%s", header)
+	}
+	if !strings.Contains(header, "multipart/alternative") || !strings.Contains(body, "text/html; charset=utf-8") {
+		t.Error("expected a multipart/alternative body with an HTML part")
+	}
+	if !strings.Contains(body, "Gr=C3=BC=C3=9Fe") {
+		t.Error("expected quoted-printable bodies")
+	}
+}
+
+func TestVerifyMailgunSignature(t *testing.T) {
+	now := time.Unix(1760600000, 0)
+	timestamp := "1760600000"
+	mac := hmac.New(sha256.New, []byte("signing-key"))
+	mac.Write([]byte(timestamp + "token-1"))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	if err := mail.VerifyMailgunSignature("signing-key", timestamp, "token-1", signature, now); err != nil {
+		t.Errorf("expected a valid signature, got %v", err)
+	}
+	if err := mail.VerifyMailgunSignature("other-key", timestamp, "token-1", signature, now); !errors.Is(err, mail.ErrInvalidSignature) {
+		t.Error("expected a signature with another key to be rejected")
+	}
+	if err := mail.VerifyMailgunSignature("signing-key", timestamp, "token-1", signature, now.Add(time.Hour)); !errors.Is(err, mail.ErrInvalidSignature) {
+		t.Error("expected a stale timestamp to be rejected")
+	}
+
+	body := fmt.Sprintf(`{"signature":{"timestamp":%q,"token":"token-1","signature":%q},
+		"event-data":{"event":"failed","severity":"permanent","recipient":"weg@example.at",
+		"delivery-status":{"code":550,"description":"No such user"}}}`, timestamp, signature)
+	event, err := mail.ParseMailgunWebhook("signing-key", []byte(body), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Type != mail.EventHardBounce || event.Email != "weg@example.at" {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestParseSESNotification(t *testing.T) {
+	events, err := mail.ParseSESNotification([]byte(`{
+		"notificationType": "Bounce",
+		"bounce": {"bounceType": "Transient", "bounceSubType": "MailboxFull",
+			"bouncedRecipients": [{"emailAddress": "a@example.at"}, {"emailAddress": "b@example.at"}]},
+		"mail": {"messageId": "0100abc"}
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 || events[0].Type != mail.EventSoftBounce || events[1].Email != "b@example.at" || events[0].MessageID != "0100abc" {
+		t.Errorf("unexpected events %+v", events)
+	}
+
+	events, _ = mail.ParseSESNotification([]byte(`{"eventType": "Complaint", "complaint": {"complainedRecipients": [{"emailAddress": "c@example.at"}]}}`))
+	if len(events) != 1 || events[0].Type != mail.EventComplaint {
+		t.Errorf("expected a complaint from event publishing, got %+v", events)
+	}
+}
+
+// TestSignV4 uses the example request of the AWS Signature Version 4 documentation
+func TestSignV4(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	mail.SignV4(req, nil, mail.AWSCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}, "us-east-1", "iam", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("unexpected authorization\n got: %s\nwant: %s", got, want)
+	}
+}
+
+// fakeResolver answers DNS lookups from maps
+type fakeResolver struct {
+	txt   map[string][]string
+	cname map[string]string
+}
+
+func (r *fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if records, ok := r.txt[name]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r *fakeResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	if target, ok := r.cname[host]; ok {
+		return target + ".", nil
+	}
+	return host + ".", nil
+}
+
+func TestDNSCheck(t *testing.T) {
+	cfg := mail.DNSConfig{SPFInclude: "mailgun.org", DKIMSelector: "abi1", DKIMDomain: "platform.example"}
+	resolver := &fakeResolver{
+		txt: map[string][]string{
+			"huber.at": {"google-site-verification=x", "v=spf1 include:_spf.google.com include:mailgun.org ~all"},
+		},
+		cname: map[string]string{"abi1._domainkey.huber.at": "abi1._domainkey.platform.example"},
+	}
+
+	check, err := cfg.Check(context.Background(), resolver, "huber.at")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !check.SPF || !check.DKIM || check.DMARC || !check.Verified() {
+		t.Errorf("unexpected check %+v", check)
+	}
+	if check.Records[2].Status != mail.RecordMissing || check.Records[2].Required {
+		t.Errorf("expected DMARC to be reported as missing but optional, got %+v", check.Records[2])
+	}
+
+	// SPF without the provider's include
+	resolver.txt["huber.at"] = []string{"v=spf1 mx -all"}
+	delete(resolver.cname, "abi1._domainkey.huber.at")
+	check, _ = cfg.Check(context.Background(), resolver, "huber.at")
+	if check.SPF || check.DKIM || check.Records[0].Status != mail.RecordInvalid || check.Records[1].Status != mail.RecordMissing {
+		t.Errorf("expected SPF invalid and DKIM missing, got %+v", check.Records)
+	}
+}
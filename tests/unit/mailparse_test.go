@@ -0,0 +1,436 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"net/textproto"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"austrian-business-infrastructure/internal/mailparse"
+)
+
+const forwardedBescheid = "Return-Path: <kanzlei@example.at>\r\n" +
+	"Authentication-Results: mx.kanzlei.at; dkim=pass (2048-bit key) header.d=example.at header.s=s1;\r\n" +
+	" spf=pass (mx.kanzlei.at: domain of kanzlei@example.at designates 192.0.2.1 as permitted sender) smtp.mailfrom=kanzlei@example.at;\r\n" +
+	" dmarc=pass (p=REJECT) header.from=example.at\r\n" +
+	"Authentication-Results: relay.example.at; spf=fail smtp.mailfrom=example.at\r\n" +
+	"DKIM-Signature: v=1; a=rsa-sha256; d=example.at; s=s1; h=from:subject; b=abc\r\n" +
+	"From: =?iso-8859-1?q?Maria_M=FCller?= <Kanzlei@Example.at>\r\n" +
+	"To: buchhaltung@firma.at, \"Büro\" <office@firma.at>\r\n" +
+	"Subject: =?utf-8?q?WG=3A_Bescheid_=C3=BCber_Umsatzsteuer?=\r\n" +
+	"Date: Mon, 12 Oct 2026 09:15:00 +0200\r\n" +
+	"Message-ID: <reply-2@example.at>\r\n" +
+	"In-Reply-To: <start-1@example.at>\r\n" +
+	"References: <start-1@example.at>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/related; boundary=\"inner\"\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=iso-8859-1\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Anbei der Bescheid, Zahlung f=FCr 15.11.2026 vormerken.\r\n" +
+	"--inner\r\n" +
+	"Content-Type: image/png\r\n" +
+	"Content-Disposition: inline\r\n" +
+	"Content-ID: <logo@example.at>\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"iVBORw0KGgo=\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/octet-stream; name=\"Bescheid.pdf\"\r\n" +
+	"Content-Disposition: attachment; filename*=UTF-8''Bescheid%20%C3%BCber%20USt.pdf\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0xLjQK\r\n" +
+	"JSVFT0YK\r\n" +
+	"--outer\r\n" +
+	"Content-Type: message/rfc822\r\n" +
+	"\r\n" +
+	"From: finanzamt@bmf.gv.at\r\n" +
+	"Subject: Bescheid\r\n" +
+	"Message-ID: <start-1@example.at>\r\n" +
+	"\r\n" +
+	"Original\r\n" +
+	"--outer--\r\n"
+
+func TestParseEML(t *testing.T) {
+	msg, err := mailparse.Parse([]byte(forwardedBescheid))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if msg.Format != mailparse.FormatEML {
+		t.Errorf("Format = %q", msg.Format)
+	}
+	if msg.Subject != "WG: Bescheid über Umsatzsteuer" {
+		t.Errorf("Subject = %q", msg.Subject)
+	}
+	if msg.From != "kanzlei@example.at" || msg.FromName != "Maria Müller" {
+		t.Errorf("From = %q %q", msg.FromName, msg.From)
+	}
+	if strings.Join(msg.To, ",") != "buchhaltung@firma.at,office@firma.at" {
+		t.Errorf("To = %v", msg.To)
+	}
+	if msg.MessageID != "reply-2@example.at" || msg.InReplyTo != "start-1@example.at" {
+		t.Errorf("MessageID = %q, InReplyTo = %q", msg.MessageID, msg.InReplyTo)
+	}
+	if msg.ThreadID() != "start-1@example.at" {
+		t.Errorf("ThreadID = %q", msg.ThreadID())
+	}
+	if msg.Date.IsZero() || msg.Date.UTC().Hour() != 7 {
+		t.Errorf("Date = %v", msg.Date)
+	}
+	if !strings.Contains(msg.TextBody, "Zahlung für 15.11.2026") {
+		t.Errorf("TextBody = %q", msg.TextBody)
+	}
+
+	if len(msg.Attachments) != 3 {
+		t.Fatalf("got %d attachments, want 3", len(msg.Attachments))
+	}
+	logo, pdf, inner := msg.Attachments[0], msg.Attachments[1], msg.Attachments[2]
+	if !logo.Inline || logo.ContentID != "logo@example.at" {
+		t.Errorf("logo = %+v", logo)
+	}
+	if pdf.Filename != "Bescheid über USt.pdf" || pdf.ContentType != "application/pdf" {
+		t.Errorf("pdf attachment = %q %q", pdf.Filename, pdf.ContentType)
+	}
+	if !bytes.HasPrefix(pdf.Data, []byte("%PDF-1.4\n%%EOF")) {
+		t.Errorf("pdf data = %q", pdf.Data)
+	}
+	if inner.ContentType != mailparse.MIMEEML || inner.Filename != "Bescheid.eml" {
+		t.Errorf("forwarded message = %q %q", inner.Filename, inner.ContentType)
+	}
+	if mailparse.Detect(inner.Data, inner.Filename) != mailparse.MIMEEML {
+		t.Error("forwarded message not detected as e-mail")
+	}
+
+	text := msg.Text()
+	for _, want := range []string{"Von: Maria Müller <kanzlei@example.at>", "Betreff: WG: Bescheid über Umsatzsteuer", "Anhänge: ", "Zahlung für"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() lacks %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestParseAuthenticity(t *testing.T) {
+	msg, err := mailparse.Parse([]byte(forwardedBescheid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := msg.Authenticity
+	// Only the topmost header counts, the relay's spf=fail is ignored
+	if a.SPF != "pass" || a.DKIM != "pass" || a.DMARC != "pass" || a.AuthServID != "mx.kanzlei.at" {
+		t.Errorf("results = %+v", a)
+	}
+	if !a.Aligned || a.Verdict != mailparse.VerdictPass || a.FromDomain != "example.at" {
+		t.Errorf("alignment = %+v", a)
+	}
+
+	cases := []struct {
+		name    string
+		header  textproto.MIMEHeader
+		from    string
+		verdict string
+		spf     string
+	}{
+		{
+			name:    "received-spf softfail without dkim",
+			header:  textproto.MIMEHeader{"Received-Spf": {"Softfail (mx: domain transitioning) client-ip=192.0.2.9"}},
+			from:    "info@bmf.gv.at",
+			verdict: mailparse.VerdictFail,
+			spf:     "softfail",
+		},
+		{
+			name:    "dkim pass for another domain",
+			header:  textproto.MIMEHeader{"Authentication-Results": {"mx; dkim=pass header.d=mailer.example; spf=none"}},
+			from:    "office@bmf.gv.at",
+			verdict: mailparse.VerdictUnknown,
+			spf:     "none",
+		},
+		{
+			name:    "subdomain sender aligned with signing domain",
+			header:  textproto.MIMEHeader{"Authentication-Results": {"mx; dkim=pass header.i=@gv.at"}},
+			from:    "noreply@bmf.gv.at",
+			verdict: mailparse.VerdictPass,
+		},
+		{
+			name:    "dmarc fail",
+			header:  textproto.MIMEHeader{"Authentication-Results": {"mx; spf=pass; dmarc=fail (p=none)"}},
+			from:    "office@bmf.gv.at",
+			verdict: mailparse.VerdictFail,
+			spf:     "pass",
+		},
+		{
+			name:    "no results",
+			header:  textproto.MIMEHeader{"Dkim-Signature": {"v=1; d=bmf.gv.at; s=x"}},
+			from:    "office@bmf.gv.at",
+			verdict: mailparse.VerdictUnknown,
+		},
+	}
+	for _, c := range cases {
+		a := mailparse.ParseAuthenticity(c.header, c.from)
+		if a.Verdict != c.verdict || a.SPF != c.spf {
+			t.Errorf("%s: verdict %q spf %q, want %q %q", c.name, a.Verdict, a.SPF, c.verdict, c.spf)
+		}
+	}
+}
+
+func TestParseEMLHTMLOnly(t *testing.T) {
+	eml := "From: info@example.at\nSubject: Info\nContent-Type: text/html; charset=windows-1252\n\n" +
+		"<html><head><style>p{color:red}</style></head><body><p>Sehr geehrte Damen und Herren,</p>" +
+		"<table><tr><td>Betrag</td><td>1.234,56 \x80</td></tr></table><script>alert(1)</script>Gr\xfc&szlig;e</body></html>"
+	msg, err := mailparse.Parse([]byte(eml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Blocks are separated by at most one blank line
+	want := "Sehr geehrte Damen und Herren,\n\nBetrag\t1.234,56 €\n\nGrüße"
+	if got := mailparse.HTMLToText(msg.HTMLBody); got != want {
+		t.Errorf("HTMLToText = %q, want %q", got, want)
+	}
+	if !strings.HasSuffix(msg.Text(), want) {
+		t.Errorf("Text() does not fall back to the HTML body:\n%s", msg.Text())
+	}
+}
+
+func TestDetectEmail(t *testing.T) {
+	if mailparse.Detect([]byte("%PDF-1.7\n"), "mail.eml") != "" {
+		t.Error("PDF detected as e-mail")
+	}
+	if mailparse.Detect([]byte("Hallo Welt\n\nText"), "notiz.txt") != "" {
+		t.Error("plain text detected as e-mail")
+	}
+	if _, err := mailparse.Parse([]byte("not a mail")); err != mailparse.ErrUnsupportedFormat {
+		t.Errorf("Parse of text: %v", err)
+	}
+	if !mailparse.IsEmail("message/rfc822") || !mailparse.IsEmail("application/vnd.ms-outlook") || mailparse.IsEmail("text/plain") {
+		t.Error("IsEmail mismatch")
+	}
+}
+
+// cfbNode is a storage or stream of a compound file built for tests
+type cfbNode struct {
+	name     string
+	data     []byte
+	children []*cfbNode
+}
+
+func utf16le(s string) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, u)
+	}
+	return b
+}
+
+// buildCFB writes a version 3 compound file. The mini stream cutoff is set
+// to zero so every stream lives in regular sectors.
+func buildCFB(root []*cfbNode) []byte {
+	const sector = 512
+	type entry struct {
+		node  *cfbNode
+		typ   byte
+		child uint32
+		right uint32
+		start uint32
+		size  uint32
+	}
+	const none = 0xFFFFFFFF
+	entries := []*entry{{node: &cfbNode{name: "Root Entry"}, typ: 5, child: none, right: none, start: 0xFFFFFFFE}}
+	var add func(parent *entry, nodes []*cfbNode)
+	add = func(parent *entry, nodes []*cfbNode) {
+		var prev *entry
+		for _, n := range nodes {
+			e := &entry{node: n, typ: 2, child: none, right: none}
+			if n.children != nil {
+				e.typ = 1
+			}
+			entries = append(entries, e)
+			id := uint32(len(entries) - 1)
+			if prev == nil {
+				parent.child = id
+			} else {
+				prev.right = id
+			}
+			prev = e
+			if n.children != nil {
+				add(e, n.children)
+			}
+		}
+	}
+	add(entries[0], root)
+
+	dirSectors := (len(entries)*128 + sector - 1) / sector
+	next := uint32(1 + dirSectors) // sector 0 holds the FAT
+	var fat []uint32
+	fat = append(fat, 0xFFFFFFFD)
+	for i := 0; i < dirSectors; i++ {
+		if i == dirSectors-1 {
+			fat = append(fat, 0xFFFFFFFE)
+		} else {
+			fat = append(fat, uint32(i+2))
+		}
+	}
+	var data []byte
+	for _, e := range entries {
+		if e.typ != 2 {
+			continue
+		}
+		e.size = uint32(len(e.node.data))
+		e.start = 0xFFFFFFFE
+		n := (len(e.node.data) + sector - 1) / sector
+		if n == 0 {
+			continue
+		}
+		e.start = next
+		for i := 0; i < n; i++ {
+			if i == n-1 {
+				fat = append(fat, 0xFFFFFFFE)
+			} else {
+				fat = append(fat, next+uint32(i)+1)
+			}
+		}
+		padded := make([]byte, n*sector)
+		copy(padded, e.node.data)
+		data = append(data, padded...)
+		next += uint32(n)
+	}
+
+	header := make([]byte, sector)
+	copy(header, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1})
+	le := binary.LittleEndian
+	le.PutUint16(header[0x18:], 0x3E)
+	le.PutUint16(header[0x1A:], 3)
+	le.PutUint16(header[0x1C:], 0xFFFE)
+	le.PutUint16(header[0x1E:], 9)
+	le.PutUint16(header[0x20:], 6)
+	le.PutUint32(header[0x2C:], 1)
+	le.PutUint32(header[0x30:], 1)
+	le.PutUint32(header[0x38:], 0)
+	le.PutUint32(header[0x3C:], 0xFFFFFFFE)
+	le.PutUint32(header[0x44:], 0xFFFFFFFE)
+	for i := 0; i < 109; i++ {
+		le.PutUint32(header[0x4C+4*i:], 0xFFFFFFFF)
+	}
+	le.PutUint32(header[0x4C:], 0)
+
+	fatSector := make([]byte, sector)
+	for i := range fatSector {
+		fatSector[i] = 0xFF
+	}
+	for i, v := range fat {
+		le.PutUint32(fatSector[4*i:], v)
+	}
+
+	dir := make([]byte, dirSectors*sector)
+	for i, e := range entries {
+		raw := dir[i*128 : (i+1)*128]
+		name := utf16le(e.node.name + "\x00")
+		copy(raw, name)
+		le.PutUint16(raw[64:], uint16(len(name)))
+		raw[66] = e.typ
+		le.PutUint32(raw[68:], none)
+		le.PutUint32(raw[72:], e.right)
+		le.PutUint32(raw[76:], e.child)
+		le.PutUint32(raw[116:], e.start)
+		le.PutUint32(raw[120:], e.size)
+	}
+
+	out := append(header, fatSector...)
+	out = append(out, dir...)
+	return append(out, data...)
+}
+
+func TestParseMSG(t *testing.T) {
+	headers := "Message-ID: <msg-7@bmf.gv.at>\r\nIn-Reply-To: <req-1@firma.at>\r\n" +
+		"Date: Tue, 13 Oct 2026 10:00:00 +0200\r\nTo: office@firma.at\r\n" +
+		"Authentication-Results: mx.firma.at; spf=pass smtp.mailfrom=bmf.gv.at; dkim=pass header.d=bmf.gv.at\r\n"
+	msgFile := buildCFB([]*cfbNode{
+		{name: "__properties_version1.0", data: make([]byte, 32)},
+		{name: "__substg1.0_0037001F", data: utf16le("Ergänzungsersuchen")},
+		{name: "__substg1.0_1000001F", data: utf16le("Bitte Unterlagen bis 30.11.2026 nachreichen.")},
+		{name: "__substg1.0_0C1A001F", data: utf16le("Finanzamt Österreich")},
+		{name: "__substg1.0_5D01001F", data: utf16le("Post@BMF.gv.at")},
+		{name: "__substg1.0_007D001F", data: utf16le(headers)},
+		{name: "__attach_version1.0_#00000000", children: []*cfbNode{
+			{name: "__properties_version1.0", data: make([]byte, 16)},
+			{name: "__substg1.0_3707001F", data: utf16le("Ersuchen.pdf")},
+			{name: "__substg1.0_37010102", data: []byte("%PDF-1.4\n" + strings.Repeat("x", 600))},
+		}},
+		{name: "__attach_version1.0_#00000001", children: []*cfbNode{
+			{name: "__substg1.0_3707001F", data: utf16le("Weitergeleitet.msg")},
+			{name: "__substg1.0_3701000D", children: []*cfbNode{}},
+		}},
+	})
+
+	if got := mailparse.Detect(msgFile, "upload.bin"); got != mailparse.MIMEMSG {
+		t.Fatalf("Detect = %q", got)
+	}
+	msg, err := mailparse.Parse(msgFile)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if msg.Format != mailparse.FormatMSG || msg.Subject != "Ergänzungsersuchen" {
+		t.Errorf("Format %q, Subject %q", msg.Format, msg.Subject)
+	}
+	if msg.From != "post@bmf.gv.at" || msg.FromName != "Finanzamt Österreich" {
+		t.Errorf("From = %q %q", msg.FromName, msg.From)
+	}
+	if msg.MessageID != "msg-7@bmf.gv.at" || msg.ThreadID() != "req-1@firma.at" {
+		t.Errorf("MessageID %q, ThreadID %q", msg.MessageID, msg.ThreadID())
+	}
+	if msg.Date.IsZero() || len(msg.To) != 1 || msg.To[0] != "office@firma.at" {
+		t.Errorf("Date %v, To %v", msg.Date, msg.To)
+	}
+	if msg.Authenticity.Verdict != mailparse.VerdictPass || !msg.Authenticity.Aligned {
+		t.Errorf("Authenticity = %+v", msg.Authenticity)
+	}
+	if len(msg.Attachments) != 1 {
+		t.Fatalf("got %d attachments, want 1", len(msg.Attachments))
+	}
+	pdf := msg.Attachments[0]
+	if pdf.Filename != "Ersuchen.pdf" || pdf.ContentType != "application/pdf" || len(pdf.Data) != 609 {
+		t.Errorf("attachment = %q %q %d bytes", pdf.Filename, pdf.ContentType, len(pdf.Data))
+	}
+	if len(msg.Warnings) != 1 || !strings.Contains(msg.Warnings[0], "Weitergeleitet.msg") {
+		t.Errorf("Warnings = %v", msg.Warnings)
+	}
+	if !strings.Contains(msg.Text(), "Bitte Unterlagen bis 30.11.2026") {
+		t.Errorf("Text() = %q", msg.Text())
+	}
+
+	// A Word 97 document is a compound file without message properties
+	doc := buildCFB([]*cfbNode{{name: "WordDocument", data: []byte("x")}})
+	if mailparse.Detect(doc, "brief.doc") != "" {
+		t.Error("Word document detected as e-mail")
+	}
+}
+
+func TestParseEMLLimits(t *testing.T) {
+	// Attachments beyond the limit are skipped with a warning
+	var b strings.Builder
+	b.WriteString("From: a@example.at\r\nSubject: viele\r\nContent-Type: multipart/mixed; boundary=b\r\n\r\n")
+	for i := 0; i <= mailparse.MaxAttachments; i++ {
+		b.WriteString("--b\r\nContent-Type: application/pdf\r\nContent-Transfer-Encoding: base64\r\n\r\n")
+		b.WriteString(base64.StdEncoding.EncodeToString([]byte("%PDF-1.4")) + "\r\n")
+	}
+	b.WriteString("--b--\r\n")
+	msg, err := mailparse.Parse([]byte(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.Attachments) != mailparse.MaxAttachments || len(msg.Warnings) != 1 {
+		t.Errorf("%d attachments, warnings %v", len(msg.Attachments), msg.Warnings)
+	}
+	if msg.Attachments[0].Filename != "anhang-1.pdf" {
+		t.Errorf("generated name = %q", msg.Attachments[0].Filename)
+	}
+}
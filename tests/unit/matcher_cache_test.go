@@ -0,0 +1,89 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/config"
+	"austrian-business-infrastructure/internal/foerderung"
+	"austrian-business-infrastructure/internal/matcher"
+)
+
+func TestMatcherProfileHash(t *testing.T) {
+	employees := 12
+	profile := &matcher.ProfileInput{
+		CompanyName:    "Muster GmbH",
+		State:          "Wien",
+		EmployeesCount: &employees,
+		OnaceCodes:     []string{"62.01", "62.02"},
+		ProjectTopics:  []string{"digitalisierung", "innovation"},
+	}
+	same := &matcher.ProfileInput{
+		CompanyName:    " Muster GmbH ",
+		State:          "wien",
+		EmployeesCount: &employees,
+		OnaceCodes:     []string{"62.02", "62.01"},
+		ProjectTopics:  []string{"Innovation", "digitalisierung", "innovation"},
+	}
+	if matcher.ProfileHash(profile) != matcher.ProfileHash(same) {
+		t.Error("normalized profiles should share their hash")
+	}
+
+	more := 13
+	changed := *profile
+	changed.EmployeesCount = &more
+	if matcher.ProfileHash(profile) == matcher.ProfileHash(&changed) {
+		t.Error("changing the profile should change its hash")
+	}
+}
+
+func TestMatcherCatalogVersion(t *testing.T) {
+	updated := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	a := &foerderung.Foerderung{ID: uuid.New(), Status: foerderung.StatusActive, UpdatedAt: updated}
+	b := &foerderung.Foerderung{ID: uuid.New(), Status: foerderung.StatusActive, UpdatedAt: updated}
+
+	version := matcher.CatalogVersion([]*foerderung.Foerderung{a, b})
+	if matcher.CatalogVersion([]*foerderung.Foerderung{b, a}) != version {
+		t.Error("catalog version should not depend on the order")
+	}
+
+	edited := *b
+	edited.UpdatedAt = updated.Add(time.Minute)
+	if matcher.CatalogVersion([]*foerderung.Foerderung{a, &edited}) == version {
+		t.Error("editing a Förderung should change the catalog version")
+	}
+	if matcher.CatalogVersion([]*foerderung.Foerderung{a}) == version {
+		t.Error("removing a Förderung should change the catalog version")
+	}
+}
+
+func TestMatcherSearchResultCacheStats(t *testing.T) {
+	store := matcher.NewInMemoryCache()
+	defer store.Close()
+	stats := matcher.NewStatsCache(store)
+	results := matcher.NewSearchResultCache(stats, &config.FoerderungConfig{SearchCacheTTLHours: 24})
+	ctx := context.Background()
+
+	key := matcher.SearchResultKey(matcher.ProfileHash(&matcher.ProfileInput{CompanyName: "Muster GmbH"}), "v1")
+	if _, ok := results.Get(ctx, key); ok {
+		t.Fatal("expected a miss before the first search")
+	}
+
+	match := foerderung.FoerderungsMatch{FoerderungID: uuid.New(), FoerderungName: "aws Digitalisierung", TotalScore: 0.8}
+	if err := results.Set(ctx, key, &matcher.CachedSearchResult{Matches: []foerderung.FoerderungsMatch{match}, TotalChecked: 74, LLMCostCents: 30}); err != nil {
+		t.Fatal(err)
+	}
+
+	cached, ok := results.Get(ctx, key)
+	if !ok || len(cached.Matches) != 1 || cached.Matches[0].FoerderungID != match.FoerderungID || cached.TotalChecked != 74 || cached.CachedAt.IsZero() {
+		t.Fatalf("unexpected cached result %+v", cached)
+	}
+
+	got := stats.Stats()
+	if got.Hits != 1 || got.Misses != 1 || got.HitRate != 0.5 {
+		t.Errorf("unexpected stats %+v", got)
+	}
+}
@@ -0,0 +1,148 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"austrian-business-infrastructure/internal/erechnung"
+	"austrian-business-infrastructure/pkg/money"
+)
+
+func TestMoneyParse(t *testing.T) {
+	tests := []struct {
+		input string
+		cents int64
+	}{
+		{"1234.56", 123456},
+		{"-0.5", -50},
+		{"12", 1200},
+		{"0.005", 1},
+		{"-0.005", -1},
+		{"0.004", 0},
+		{" 7.10 ", 710},
+	}
+	for _, tt := range tests {
+		m, err := money.Parse(tt.input, "")
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.input, err)
+			continue
+		}
+		if m.Cents != tt.cents || m.Currency != "EUR" {
+			t.Errorf("%q: expected %d EUR, got %v", tt.input, tt.cents, m)
+		}
+	}
+
+	for _, bad := range []string{"", "1,50", "1e3", "1/3", "0x10", "abc"} {
+		if _, err := money.Parse(bad, ""); !errors.Is(err, money.ErrInvalidAmount) {
+			t.Errorf("%q: expected ErrInvalidAmount, got %v", bad, err)
+		}
+	}
+	if _, err := money.Parse("1000000000000000000000", ""); !errors.Is(err, money.ErrOutOfRange) {
+		t.Errorf("expected ErrOutOfRange, got %v", err)
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	// 1.005 is 1.00499999... as a float; the shortest representation rounds up
+	if got := money.FromFloat(1.005, "EUR").Cents; got != 101 {
+		t.Errorf("FromFloat(1.005): expected 101, got %d", got)
+	}
+
+	// 3 x 0.1 h at 33.33 EUR: the float product 999.9000000000001 used to be truncated
+	if got := money.EUR(3333).Mul(0.3).Cents; got != 1000 {
+		t.Errorf("Mul: expected 1000, got %d", got)
+	}
+	if got := money.EUR(1999).Mul(1.5).Cents; got != 2999 {
+		t.Errorf("Mul: expected 2999 (2998.5 rounded), got %d", got)
+	}
+	if got := money.EUR(-1999).Mul(1.5).Cents; got != -2999 {
+		t.Errorf("Mul: expected -2999, got %d", got)
+	}
+
+	// 20% of 0.57 EUR is 11.4 cents, 10% of 0.85 EUR 8.5 cents
+	if got := money.EUR(57).Percent(20).Cents; got != 11 {
+		t.Errorf("Percent: expected 11, got %d", got)
+	}
+	if got := money.EUR(85).Percent(10).Cents; got != 9 {
+		t.Errorf("Percent: expected 9, got %d", got)
+	}
+
+	sum := money.EUR(1050).Add(money.EUR(-75)).Sub(money.EUR(25))
+	if sum.Cents != 950 || sum.Decimal() != "9.50" || sum.String() != "9.50 EUR" {
+		t.Errorf("unexpected sum %v", sum)
+	}
+	if money.EUR(-5).Decimal() != "-0.05" {
+		t.Errorf("expected -0.05, got %s", money.EUR(-5).Decimal())
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	type doc struct {
+		Amount money.Money  `json:"amount"`
+		Budget *money.Money `json:"budget,omitempty"`
+	}
+
+	out, err := json.Marshal(doc{Amount: money.EUR(123450)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(out) != `{"amount":1234.50}` {
+		t.Errorf("unexpected JSON: %s", out)
+	}
+
+	// Clients sending the float amounts of the old API keep working
+	inputs := map[string]int64{
+		`{"amount": 1234.5}`:                             123450,
+		`{"amount": 0.1}`:                                10,
+		`{"amount": "99.99"}`:                            9999,
+		`{"amount": {"cents": 4200, "currency": "CHF"}}`: 4200,
+		`{"amount": null}`:                               0,
+	}
+	for input, cents := range inputs {
+		var d doc
+		if err := json.Unmarshal([]byte(input), &d); err != nil {
+			t.Errorf("%s: unexpected error: %v", input, err)
+			continue
+		}
+		if d.Amount.Cents != cents {
+			t.Errorf("%s: expected %d cents, got %d", input, cents, d.Amount.Cents)
+		}
+	}
+
+	var d doc
+	if err := json.Unmarshal([]byte(`{"amount": {"cents": 1, "currency": "CHF"}}`), &d); err != nil || d.Amount.Currency != "CHF" {
+		t.Errorf("expected CHF from object, got %v, %v", d.Amount, err)
+	}
+	if err := json.Unmarshal([]byte(`{"budget": 12.5}`), &d); err != nil || d.Budget == nil || d.Budget.Cents != 1250 {
+		t.Errorf("expected pointer amount, got %v, %v", d.Budget, err)
+	}
+	for _, bad := range []string{`{"amount": "1,5"}`, `{"amount": true}`, `{"amount": {"currency": "EUR"}}`, `{"amount": 1e3}`} {
+		if err := json.Unmarshal([]byte(bad), &d); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+}
+
+func TestInvoiceLineTotalsRound(t *testing.T) {
+	// 0.3 h at 33.33 EUR: truncating the float product gave 9.99
+	inv := &erechnung.Invoice{
+		Lines: []*erechnung.InvoiceLine{
+			{UnitPrice: 3333, Quantity: 0.3, TaxCategory: "S", TaxPercent: 20},
+			{UnitPrice: 57, Quantity: 1, TaxCategory: "S", TaxPercent: 20},
+		},
+	}
+	for _, line := range inv.Lines {
+		line.CalculateTotal()
+	}
+	if inv.Lines[0].LineTotal != 1000 {
+		t.Errorf("expected line total 1000, got %d", inv.Lines[0].LineTotal)
+	}
+	if err := inv.CalculateTotals(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 20% of 10.57 EUR is 2.114 EUR
+	if inv.TaxAmount != 211 || inv.TaxInclusiveAmount != 1268 {
+		t.Errorf("expected tax 211 and total 1268, got %d and %d", inv.TaxAmount, inv.TaxInclusiveAmount)
+	}
+}
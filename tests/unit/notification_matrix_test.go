@@ -0,0 +1,163 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/mail"
+	"austrian-business-infrastructure/internal/notification"
+)
+
+func TestUnsubscribeToken(t *testing.T) {
+	signer := notification.NewUnsubscribeSigner([]byte("secret"))
+	tenantID := uuid.New()
+
+	token, err := signer.Sign(&notification.UnsubscribeClaims{Email: "max@kunde.at", TenantID: &tenantID, EventType: notification.EventSignature})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	claims, err := signer.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if claims.Email != "max@kunde.at" || claims.TenantID == nil || *claims.TenantID != tenantID || claims.EventType != notification.EventSignature {
+		t.Errorf("Verify() = %+v", claims)
+	}
+
+	payload, signature, _ := strings.Cut(token, ".")
+	forged, _ := notification.NewUnsubscribeSigner([]byte("other")).Sign(&notification.UnsubscribeClaims{Email: "max@kunde.at", EventType: notification.EventBilling})
+	security, _ := signer.Sign(&notification.UnsubscribeClaims{Email: "max@kunde.at", EventType: notification.EventSecurity})
+	for name, token := range map[string]string{
+		"tampered payload": payload + "x." + signature,
+		"no signature":     payload,
+		"other key":        forged,
+		"security event":   security,
+	} {
+		if _, err := signer.Verify(token); !errors.Is(err, notification.ErrInvalidUnsubscribeToken) {
+			t.Errorf("Verify(%s) error = %v, want ErrInvalidUnsubscribeToken", name, err)
+		}
+	}
+
+	link, _ := url.Parse(notification.UnsubscribeLink("https://app.example/", token))
+	if link.Path != "/api/v1/notifications/unsubscribe" || link.Query().Get("token") != token {
+		t.Errorf("UnsubscribeLink() = %s", link)
+	}
+}
+
+func TestPreferenceMatrix(t *testing.T) {
+	m := notification.DefaultMatrix()
+	for _, event := range notification.EventTypes {
+		for _, channel := range notification.Channels {
+			if !m.Enabled(event, channel) {
+				t.Errorf("%s/%s disabled by default", event, channel)
+			}
+		}
+	}
+
+	m[notification.EventAnalysis][notification.ChannelEmail] = false
+	if m.Enabled(notification.EventAnalysis, notification.ChannelEmail) || !m.Enabled(notification.EventAnalysis, notification.ChannelInApp) {
+		t.Error("expected only analysis emails to be disabled")
+	}
+	if err := m.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	if err := (notification.Matrix{notification.EventSecurity: {notification.ChannelEmail: false}}).Validate(); !errors.Is(err, notification.ErrExemptEvent) {
+		t.Errorf("Validate() disabling security = %v, want ErrExemptEvent", err)
+	}
+	if !(notification.Matrix{notification.EventSecurity: {notification.ChannelInApp: false}}).Enabled(notification.EventSecurity, notification.ChannelInApp) {
+		t.Error("expected security notifications to stay enabled")
+	}
+	for _, invalid := range []notification.Matrix{{"newsletter": {notification.ChannelEmail: false}}, {notification.EventBilling: {"sms": false}}} {
+		if err := invalid.Validate(); !errors.Is(err, notification.ErrInvalidPreference) {
+			t.Errorf("Validate(%v) = %v, want ErrInvalidPreference", invalid, err)
+		}
+	}
+
+	for category, want := range map[string]string{
+		mail.TemplatePasswordReset:         notification.EventSecurity,
+		mail.TemplateBreakGlassStarted:     notification.EventSecurity,
+		mail.TemplateSignatureReminder:     notification.EventSignature,
+		mail.TemplateSignatureLowBalance:   notification.EventBilling,
+		notification.TypeAnalysisCompleted: notification.EventAnalysis,
+		mail.TemplateInvitation:            "",
+	} {
+		if got := notification.MailEvent(category); got != want {
+			t.Errorf("MailEvent(%s) = %q, want %q", category, got, want)
+		}
+	}
+}
+
+// fakeMailPreferences returns a fixed preference per category, allowing
+// the others
+type fakeMailPreferences map[string]*mail.Preference
+
+func (p fakeMailPreferences) MailPreference(ctx context.Context, tenantID *uuid.UUID, to, category string) (*mail.Preference, error) {
+	if pref, ok := p[category]; ok {
+		return pref, nil
+	}
+	return &mail.Preference{Allowed: true}, nil
+}
+
+func TestMailServiceEnforcesPreferences(t *testing.T) {
+	ctx := context.Background()
+	service, provider, store := newTestMailService(t)
+	emailService := email.NewMailService(service)
+	unsubscribeURL := "https://app.example/api/v1/notifications/unsubscribe?token=abc.def"
+	service.SetPreferences(fakeMailPreferences{
+		mail.TemplateSignatureExpired:  {Allowed: false},
+		mail.TemplateSignatureReminder: {Allowed: true, UnsubscribeURL: unsubscribeURL},
+		mail.TemplatePasswordReset:     {Allowed: true, Required: true},
+	})
+
+	err := emailService.SendSignatureExpired(ctx, "max@kunde.at", email.SignatureExpiredParams{RecipientName: "Max", DocumentTitle: "Vertrag", ExpiredAt: "01.11.2026"})
+	if !errors.Is(err, mail.ErrOptedOut) {
+		t.Errorf("expected an opted-out notification not to be sent, got %v", err)
+	}
+
+	err = emailService.SendSignatureReminder(ctx, "max@kunde.at", email.SignatureReminderParams{SignerName: "Max", DocumentTitle: "Vertrag", SigningURL: "https://portal.example/sign/abc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(provider.sent) != 1 {
+		t.Fatalf("expected 1 message sent, got %d", len(provider.sent))
+	}
+	msg := provider.sent[0]
+	if msg.UnsubscribeURL != unsubscribeURL || !strings.HasSuffix(msg.Text, unsubscribeURL+"\n") || !strings.Contains(msg.HTML, `href="https://app.example/api/v1/notifications/unsubscribe?token=abc.def"`) {
+		t.Errorf("expected the unsubscribe link in the message:\n%s", msg.Text)
+	}
+
+	raw, err := mail.BuildMIME(msg, "<1@test>", time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	header, _, _ := strings.Cut(string(raw), "\r\n\r\n")
+	if !strings.Contains(header, "List-Unsubscribe: <"+unsubscribeURL+">\r\n") || !strings.Contains(header, "List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n") {
+		t.Errorf("expected one-click unsubscribe headers:\n%s", header)
+	}
+
+	// Required mails pass an unsubscribe at the provider, but not a bounce
+	store.suppressions = append(store.suppressions,
+		&mail.Suppression{Email: "max@kunde.at", Reason: mail.ReasonUnsubscribe},
+		&mail.Suppression{Email: "bounced@kunde.at", Reason: mail.ReasonHardBounce},
+	)
+	if err := emailService.SendPasswordReset(ctx, "max@kunde.at", email.PasswordResetParams{ResetURL: "https://app.example/reset"}); err != nil {
+		t.Errorf("expected the password reset to be sent despite the unsubscribe, got %v", err)
+	}
+	if strings.Contains(provider.sent[len(provider.sent)-1].Text, "abbestellen") {
+		t.Error("expected no unsubscribe link in a security mail")
+	}
+	if err := emailService.SendPasswordReset(ctx, "bounced@kunde.at", email.PasswordResetParams{ResetURL: "https://app.example/reset"}); !errors.Is(err, mail.ErrSuppressed) {
+		t.Errorf("expected a bounced address to stay suppressed, got %v", err)
+	}
+	if err := emailService.SendSignatureReminder(ctx, "max@kunde.at", email.SignatureReminderParams{SignerName: "Max", DocumentTitle: "Vertrag", SigningURL: "https://portal.example/sign/abc"}); !errors.Is(err, mail.ErrSuppressed) {
+		t.Errorf("expected other mails to respect the unsubscribe, got %v", err)
+	}
+}
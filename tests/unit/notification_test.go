@@ -0,0 +1,309 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/analysis"
+	"austrian-business-infrastructure/internal/document"
+	"austrian-business-infrastructure/internal/mail"
+	"austrian-business-infrastructure/internal/notification"
+	"austrian-business-infrastructure/internal/user"
+	"austrian-business-infrastructure/pkg/money"
+)
+
+type fakeTranslator struct {
+	calls int
+	err   error
+}
+
+func (f *fakeTranslator) Translate(ctx context.Context, texts []string, from, to string) ([]string, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := make([]string, len(texts))
+	for i, text := range texts {
+		out[i] = "[" + to + "] " + text
+	}
+	return out, nil
+}
+
+func newAnalysisFixture() (*document.Document, *notification.AnalysisExcerpt) {
+	doc := &document.Document{
+		ID:         uuid.New(),
+		Type:       "bescheid",
+		Title:      "Einkommensteuerbescheid 2025",
+		Sender:     "Finanzamt Österreich",
+		ReceivedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+	}
+	result := &analysis.FullAnalysisResult{
+		Analysis: &analysis.Analysis{
+			ID:         uuid.New(),
+			DocumentID: doc.ID,
+			Summary:    "Nachzahlung von EUR 1.234,56 festgesetzt.",
+			KeyPoints:  []string{"Zahlbar bis 15.04.2026", "Gutschrift 300 € aus 2024 verrechnet"},
+		},
+		Deadlines: []*analysis.Deadline{{
+			Date:        time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC),
+			Description: "Zahlungsfrist",
+			IsHard:      true,
+		}},
+		Amounts: []*analysis.Amount{{
+			AmountType:  "nachzahlung",
+			Amount:      money.EUR(123456),
+			Description: "Nachzahlung",
+		}},
+		ActionItems: []*analysis.ActionItem{{Status: analysis.ActionStatusPending}},
+	}
+	return doc, notification.NewAnalysisExcerpt(result)
+}
+
+func newNotificationService() *notification.Service {
+	return notification.NewService(nil, nil, nil, &notification.ServiceConfig{
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		AppURL: "https://app.example.at/",
+	})
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	cases := map[string]string{"de": "de", "EN": "en", "en-GB": "en", "hr_HR": "hr", " tr ": "tr", "fr": "", "": ""}
+	for tag, want := range cases {
+		if got := notification.NormalizeLanguage(tag); got != want {
+			t.Errorf("NormalizeLanguage(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestRedactAmounts(t *testing.T) {
+	cases := map[string]string{
+		"Nachzahlung EUR 1.234,56 bis Ende":    "Nachzahlung *** bis Ende",
+		"Gutschrift € 300":                     "Gutschrift ***",
+		"Betrag 1.234,56 € fällig":             "Betrag *** fällig",
+		"Zahlung von 500 Euro":                 "Zahlung von ***",
+		"Frist 15.04.2026, Steuernummer 12345": "Frist 15.04.2026, Steuernummer 12345",
+	}
+	for in, want := range cases {
+		if got := notification.RedactAmounts(in); got != want {
+			t.Errorf("RedactAmounts(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNewAnalysisExcerpt(t *testing.T) {
+	_, excerpt := newAnalysisFixture()
+	if excerpt.Language != "de" {
+		t.Errorf("language = %q, want de by default", excerpt.Language)
+	}
+	if !excerpt.ActionRequired {
+		t.Error("pending action item should require action")
+	}
+	if len(excerpt.Deadlines) != 1 || len(excerpt.Amounts) != 1 || len(excerpt.KeyPoints) != 2 {
+		t.Errorf("unexpected excerpt: %+v", excerpt)
+	}
+
+	redacted := excerpt.Redacted()
+	if redacted.Amounts != nil || !redacted.AmountsRedacted {
+		t.Errorf("amounts not redacted: %+v", redacted)
+	}
+	if strings.Contains(redacted.Summary, "1.234,56") || strings.Contains(redacted.KeyPoints[1], "300") {
+		t.Errorf("amounts left in texts: %q %q", redacted.Summary, redacted.KeyPoints)
+	}
+	if redacted.KeyPoints[0] != "Zahlbar bis 15.04.2026" {
+		t.Errorf("dates must stay: %q", redacted.KeyPoints[0])
+	}
+	if len(excerpt.Amounts) != 1 || !strings.Contains(excerpt.Summary, "1.234,56") {
+		t.Error("Redacted must not modify the original excerpt")
+	}
+}
+
+func TestAnalysisPayloadTranslatesPerRecipient(t *testing.T) {
+	doc, source := newAnalysisFixture()
+	svc := newNotificationService()
+	translator := &fakeTranslator{}
+	svc.SetTranslator(translator)
+
+	rcpt := notification.Recipient{
+		UserID:        uuid.New(),
+		FinanceAccess: true,
+		Preferences:   notification.NotificationPreferences{Language: "en-GB"},
+	}
+	p := svc.AnalysisPayload(context.Background(), rcpt, doc, source)
+
+	if p.SchemaVersion != notification.PayloadSchemaVersion || p.Type != notification.TypeAnalysisCompleted {
+		t.Errorf("unexpected header: %+v", p)
+	}
+	if p.Language != "en" || p.Analysis.Language != "en" || !p.Analysis.Translated {
+		t.Errorf("expected English payload, got %q/%q", p.Language, p.Analysis.Language)
+	}
+	if p.Analysis.Summary != "[en] "+source.Summary || p.Analysis.Deadlines[0].Description != "[en] Zahlungsfrist" {
+		t.Errorf("texts not translated: %+v", p.Analysis)
+	}
+	if p.Link != "https://app.example.at/documents/"+doc.ID.String() {
+		t.Errorf("link = %q", p.Link)
+	}
+	if len(p.Analysis.Amounts) != 1 {
+		t.Error("finance users get the amounts")
+	}
+
+	// German recipients get the original without a translation call
+	translator.calls = 0
+	rcpt.Preferences.Language = ""
+	p = svc.AnalysisPayload(context.Background(), rcpt, doc, source)
+	if translator.calls != 0 || p.Language != "de" || p.Analysis.Translated {
+		t.Errorf("German payload translated: calls=%d %+v", translator.calls, p.Analysis)
+	}
+}
+
+func TestAnalysisPayloadFallbackAndRedaction(t *testing.T) {
+	doc, source := newAnalysisFixture()
+	svc := newNotificationService()
+	svc.SetTranslator(&fakeTranslator{err: errors.New("ai unavailable")})
+
+	rcpt := notification.Recipient{
+		UserID:      uuid.New(),
+		Preferences: notification.NotificationPreferences{Language: "tr"},
+	}
+	p := svc.AnalysisPayload(context.Background(), rcpt, doc, source)
+
+	if p.Language != "tr" || p.Analysis.Language != "de" || p.Analysis.Translated {
+		t.Errorf("expected Turkish payload with German summary, got %q/%q", p.Language, p.Analysis.Language)
+	}
+	if p.Analysis.Amounts != nil || !p.Analysis.AmountsRedacted || strings.Contains(p.Analysis.Summary, "1.234") {
+		t.Errorf("amounts not redacted for recipient without finance access: %+v", p.Analysis)
+	}
+
+	subject, body, err := svc.RenderAnalysisEmail(p)
+	if err != nil {
+		t.Fatalf("RenderAnalysisEmail failed: %v", err)
+	}
+	if subject != "Belge analiz edildi: "+doc.Title {
+		t.Errorf("subject = %q", subject)
+	}
+	for _, want := range []string{
+		"Özet yalnızca orijinal dilde mevcuttur.",
+		"15.04.2026: Zahlungsfrist (bağlayıcı)",
+		"Finans yetkiniz olmadığı için tutarlar gizlenmiştir.",
+		"İşlem gerekli",
+		p.Link,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body misses %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "1.234,56") || strings.Contains(body, "Tutarlar:") {
+		t.Errorf("body shows amounts:\n%s", body)
+	}
+}
+
+func TestRenderAnalysisEmailWithAmounts(t *testing.T) {
+	doc, source := newAnalysisFixture()
+	svc := newNotificationService()
+	rcpt := notification.Recipient{FinanceAccess: true, Preferences: notification.NotificationPreferences{Language: "de"}}
+
+	_, body, err := svc.RenderAnalysisEmail(svc.AnalysisPayload(context.Background(), rcpt, doc, source))
+	if err != nil {
+		t.Fatalf("RenderAnalysisEmail failed: %v", err)
+	}
+	if !strings.Contains(body, "Beträge:") || !strings.Contains(body, "Nachzahlung") || strings.Contains(body, "Originalsprache") {
+		t.Errorf("unexpected body:\n%s", body)
+	}
+}
+
+func TestRenderAnalysisEmailOnBehalfOf(t *testing.T) {
+	doc, source := newAnalysisFixture()
+	svc := newNotificationService()
+	rcpt := notification.Recipient{Preferences: notification.NotificationPreferences{Language: "de"}}
+
+	p := svc.AnalysisPayload(context.Background(), rcpt, doc, source)
+	_, body, err := svc.RenderAnalysisEmail(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(body, "Vertretung") {
+		t.Errorf("unexpected deputy note:\n%s", body)
+	}
+
+	p.OnBehalfOf = "Anna Huber"
+	_, body, err = svc.RenderAnalysisEmail(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Die Analyse eines Dokuments ist abgeschlossen\nSie erhalten diese Benachrichtigung als Vertretung von Anna Huber.\n\n"
+	if !strings.Contains(body, want) {
+		t.Errorf("expected %q in body:\n%s", want, body)
+	}
+}
+
+func TestHasFinanceAccess(t *testing.T) {
+	yes, no := true, false
+	cases := []struct {
+		role     user.Role
+		override *bool
+		want     bool
+	}{
+		{user.RoleOwner, &no, true},
+		{user.RoleAdmin, nil, true},
+		{user.RoleMember, nil, true},
+		{user.RoleMember, &no, false},
+		{user.RoleViewer, nil, false},
+		{user.RoleViewer, &yes, true},
+	}
+	for _, c := range cases {
+		if got := user.HasFinanceAccess(c.role, c.override); got != c.want {
+			t.Errorf("HasFinanceAccess(%s, %v) = %v, want %v", c.role, c.override, got, c.want)
+		}
+	}
+}
+
+func TestSendAnalysisEmail(t *testing.T) {
+	ctx := context.Background()
+	doc, source := newAnalysisFixture()
+	svc := newNotificationService()
+	tenantID := uuid.New()
+	rcpt := notification.Recipient{Email: "anna@kanzlei.at", Preferences: notification.NotificationPreferences{Language: "de"}}
+	p := svc.AnalysisPayload(ctx, rcpt, doc, source)
+
+	if err := svc.SendAnalysisEmail(ctx, tenantID, rcpt.Email, p); !errors.Is(err, notification.ErrNoMailer) {
+		t.Errorf("expected ErrNoMailer without a mailer, got %v", err)
+	}
+
+	mailService, provider, store := newTestMailService(t)
+	svc.SetMailer(mailService)
+	if err := svc.SendAnalysisEmail(ctx, tenantID, rcpt.Email, p); err != nil {
+		t.Fatalf("SendAnalysisEmail failed: %v", err)
+	}
+	if len(provider.sent) != 1 {
+		t.Fatalf("expected 1 message sent, got %d", len(provider.sent))
+	}
+	msg := provider.sent[0]
+	subject, body, _ := svc.RenderAnalysisEmail(p)
+	if msg.To != rcpt.Email || msg.Subject != subject || msg.Text != body || msg.Category != notification.TypeAnalysisCompleted {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+	if msg.TenantID == nil || *msg.TenantID != tenantID {
+		t.Errorf("message not sent for the tenant: %v", msg.TenantID)
+	}
+
+	// Opted-out and suppressed recipients are skipped without an error, so
+	// the notification is not retried
+	mailService.SetPreferences(fakeMailPreferences{notification.TypeAnalysisCompleted: {Allowed: false}})
+	if err := svc.SendAnalysisEmail(ctx, tenantID, rcpt.Email, p); err != nil {
+		t.Errorf("opted-out recipient: %v", err)
+	}
+	mailService.SetPreferences(nil)
+	store.suppressions = append(store.suppressions, &mail.Suppression{Email: rcpt.Email, Reason: mail.ReasonHardBounce})
+	if err := svc.SendAnalysisEmail(ctx, tenantID, rcpt.Email, p); err != nil {
+		t.Errorf("suppressed recipient: %v", err)
+	}
+	if len(provider.sent) != 1 {
+		t.Errorf("expected no further messages, got %d", len(provider.sent))
+	}
+}
@@ -0,0 +1,85 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/mail"
+	"austrian-business-infrastructure/internal/partner"
+	"austrian-business-infrastructure/internal/timezone"
+)
+
+func TestPartnerQuarterStart(t *testing.T) {
+	tests := []struct {
+		at   time.Time
+		want time.Time
+	}{
+		{time.Date(2026, 10, 16, 9, 30, 0, 0, timezone.Vienna), time.Date(2026, 10, 1, 0, 0, 0, 0, timezone.Vienna)},
+		{time.Date(2026, 3, 31, 23, 59, 0, 0, timezone.Vienna), time.Date(2026, 1, 1, 0, 0, 0, 0, timezone.Vienna)},
+		{time.Date(2026, 4, 1, 0, 0, 0, 0, timezone.Vienna), time.Date(2026, 4, 1, 0, 0, 0, 0, timezone.Vienna)},
+		{time.Date(2026, 8, 15, 12, 0, 0, 0, timezone.Vienna), time.Date(2026, 7, 1, 0, 0, 0, 0, timezone.Vienna)},
+	}
+	for _, tt := range tests {
+		if got := partner.QuarterStart(tt.at); !got.Equal(tt.want) {
+			t.Errorf("QuarterStart(%s) = %s, want %s", tt.at, got, tt.want)
+		}
+	}
+
+	// 30.06. 23:30 UTC is already the third quarter in Vienna
+	at := time.Date(2026, 6, 30, 23, 30, 0, 0, time.UTC).In(timezone.Vienna)
+	if got := partner.QuarterStart(at); got.Month() != time.July {
+		t.Errorf("QuarterStart(%s) = %s, want 1 July", at, got)
+	}
+}
+
+func TestPartnerNormalizeUID(t *testing.T) {
+	for in, want := range map[string]string{
+		"atu 123 456 78":  "ATU12345678",
+		" DE123456789\t":  "DE123456789",
+		"fr 12 345678901": "FR12345678901",
+		"":                "",
+	} {
+		if got := partner.NormalizeUID(in); got != want {
+			t.Errorf("NormalizeUID(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPartnerUIDInvalidMail(t *testing.T) {
+	renderer, err := mail.NewRenderer("Austrian Business Platform")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, err := renderer.Render(mail.TemplatePartnerUIDInvalid, email.PartnerUIDInvalidParams{
+		RecipientName: "Anna Huber",
+		Partners: []email.PartnerUID{
+			{Name: "Muster GmbH", UID: "DE123456789"},
+			{Name: "Beispiel S.r.l.", UID: "IT12345678901"},
+		},
+		PartnersURL: "https://app.example/partners?uid_status=invalid",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered.Subject != "UID-Nummern von 2 Geschäftspartnern ungültig" {
+		t.Errorf("unexpected subject %q", rendered.Subject)
+	}
+	for _, want := range []string{"Guten Tag Anna Huber,", "- Muster GmbH: DE123456789", "- Beispiel S.r.l.: IT12345678901", "Reverse Charge", "https://app.example/partners?uid_status=invalid"} {
+		if !strings.Contains(rendered.Text, want) {
+			t.Errorf("expected %q in text:\n%s", want, rendered.Text)
+		}
+	}
+
+	rendered, err = renderer.Render(mail.TemplatePartnerUIDInvalid, email.PartnerUIDInvalidParams{
+		Partners: []email.PartnerUID{{Name: "Muster GmbH", UID: "DE123456789"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered.Subject != "UID-Nummer eines Geschäftspartners ungültig" {
+		t.Errorf("unexpected subject %q", rendered.Subject)
+	}
+}
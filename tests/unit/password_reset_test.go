@@ -0,0 +1,131 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/auth"
+)
+
+func TestPasswordResetStore_StoresOnlyHash(t *testing.T) {
+	mr, client := setupTestRedis(t)
+	defer mr.Close()
+
+	store := auth.NewPasswordResetStore(client)
+	userID := uuid.New()
+
+	token, err := store.Issue(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+
+	for _, key := range mr.Keys() {
+		if strings.Contains(key, token) {
+			t.Errorf("raw token found in key %q", key)
+		}
+		if value, err := mr.Get(key); err == nil && strings.Contains(value, token) {
+			t.Errorf("raw token found in value of %q", key)
+		}
+	}
+}
+
+func TestPasswordResetStore_ConsumeOnce(t *testing.T) {
+	mr, client := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	store := auth.NewPasswordResetStore(client)
+	userID := uuid.New()
+
+	token, err := store.Issue(ctx, userID)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+
+	got, err := store.Consume(ctx, token)
+	if err != nil {
+		t.Fatalf("Consume failed: %v", err)
+	}
+	if got != userID {
+		t.Errorf("expected user %s, got %s", userID, got)
+	}
+
+	if _, err := store.Consume(ctx, token); !errors.Is(err, auth.ErrResetTokenInvalid) {
+		t.Errorf("expected ErrResetTokenInvalid on reuse, got %v", err)
+	}
+}
+
+func TestPasswordResetStore_NewTokenInvalidatesPrevious(t *testing.T) {
+	mr, client := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	store := auth.NewPasswordResetStore(client)
+	userID := uuid.New()
+
+	first, err := store.Issue(ctx, userID)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	mr.FastForward(auth.PasswordResetCooldown)
+	second, err := store.Issue(ctx, userID)
+	if err != nil {
+		t.Fatalf("second Issue failed: %v", err)
+	}
+
+	if _, err := store.Consume(ctx, first); !errors.Is(err, auth.ErrResetTokenInvalid) {
+		t.Errorf("expected first token to be invalid, got %v", err)
+	}
+	if _, err := store.Consume(ctx, second); err != nil {
+		t.Errorf("expected second token to be valid, got %v", err)
+	}
+}
+
+func TestPasswordResetStore_Throttling(t *testing.T) {
+	mr, client := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	store := auth.NewPasswordResetStore(client)
+	userID := uuid.New()
+
+	if _, err := store.Issue(ctx, userID); err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	if _, err := store.Issue(ctx, userID); !errors.Is(err, auth.ErrResetThrottled) {
+		t.Errorf("expected cooldown to throttle, got %v", err)
+	}
+
+	// Other accounts are not affected
+	if _, err := store.Issue(ctx, uuid.New()); err != nil {
+		t.Errorf("expected other user to be allowed, got %v", err)
+	}
+
+	for i := 1; i < auth.PasswordResetMaxPerHour; i++ {
+		mr.FastForward(auth.PasswordResetCooldown)
+		if _, err := store.Issue(ctx, userID); err != nil {
+			t.Fatalf("Issue %d failed: %v", i+1, err)
+		}
+	}
+
+	mr.FastForward(auth.PasswordResetCooldown)
+	if _, err := store.Issue(ctx, userID); !errors.Is(err, auth.ErrResetThrottled) {
+		t.Errorf("expected hourly limit to throttle, got %v", err)
+	}
+}
+
+func TestPasswordResetStore_UnknownToken(t *testing.T) {
+	mr, client := setupTestRedis(t)
+	defer mr.Close()
+
+	store := auth.NewPasswordResetStore(client)
+	for _, token := range []string{"", "does-not-exist"} {
+		if _, err := store.Consume(context.Background(), token); !errors.Is(err, auth.ErrResetTokenInvalid) {
+			t.Errorf("Consume(%q): expected ErrResetTokenInvalid, got %v", token, err)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package unit
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/pdfa"
+)
+
+const pdfaXMP = `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF>
+<rdf:Description xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/" pdfaid:part="2" pdfaid:conformance="B"/>
+</rdf:RDF></x:xmpmeta>`
+
+// buildPDF returns a minimal PDF with the given catalog entries and an XMP
+// metadata stream, compressed if requested
+func buildPDF(t *testing.T, catalog, xmp string, compress bool) []byte {
+	t.Helper()
+	stream := []byte(xmp)
+	filter := ""
+	if compress {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		zw.Write(stream)
+		zw.Close()
+		stream = buf.Bytes()
+		filter = " /Filter /FlateDecode"
+	}
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	pdf.WriteString("1 0 obj << /Type /Catalog /Metadata 2 0 R " + catalog + " >> endobj\n")
+	pdf.WriteString("2 0 obj << /Type /Metadata /Subtype /XML" + filter + " >>\nstream\n")
+	pdf.Write(stream)
+	pdf.WriteString("\nendstream\nendobj\ntrailer << /Root 1 0 R >>\n%%EOF\n")
+	return pdf.Bytes()
+}
+
+const outputIntent = "/OutputIntents [<< /Type /OutputIntent /S /GTS_PDFA1 >>]"
+
+func issueClauses(v *pdfa.Validation) []string {
+	var clauses []string
+	for _, issue := range v.Issues {
+		clauses = append(clauses, issue.Clause)
+	}
+	return clauses
+}
+
+func TestPDFASupported(t *testing.T) {
+	for _, mime := range []string{
+		"application/pdf",
+		"application/PDF; charset=binary",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.oasis.opendocument.spreadsheet",
+	} {
+		if !pdfa.Supported(mime) {
+			t.Errorf("Supported(%q) = false", mime)
+		}
+	}
+	for _, mime := range []string{"image/png", "application/xml", "text/plain", ""} {
+		if pdfa.Supported(mime) {
+			t.Errorf("Supported(%q) = true", mime)
+		}
+	}
+}
+
+func TestCheckStructureCompliant(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		v := pdfa.CheckStructure(buildPDF(t, outputIntent, pdfaXMP, compress))
+		if !v.Compliant || v.Validator != pdfa.ValidatorBuiltin || v.Conformance != pdfa.Conformance {
+			t.Errorf("compress=%v: expected compliant, got %+v", compress, v)
+		}
+	}
+}
+
+func TestCheckStructureIssues(t *testing.T) {
+	cases := []struct {
+		name string
+		pdf  []byte
+		want []string
+	}{
+		{"not a pdf", []byte("PK\x03\x04 docx"), []string{"6.1.2"}},
+		{"plain pdf", buildPDF(t, "", "<x:xmpmeta/>", true), []string{"6.2.3", "6.6.4"}},
+		{"pdf/a-1", buildPDF(t, outputIntent, strings.Replace(pdfaXMP, `part="2"`, `part="1"`, 1), false), []string{"6.6.4"}},
+		{"encrypted", buildPDF(t, outputIntent+" /Encrypt 5 0 R", pdfaXMP, false), []string{"6.1.3"}},
+		{"javascript", buildPDF(t, outputIntent+" /Names << /JavaScript 6 0 R >>", pdfaXMP, false), []string{"6.6.1"}},
+	}
+	for _, c := range cases {
+		v := pdfa.CheckStructure(c.pdf)
+		if v.Compliant {
+			t.Errorf("%s: expected non-compliant", c.name)
+		}
+		if got := strings.Join(issueClauses(v), ","); got != strings.Join(c.want, ",") {
+			t.Errorf("%s: clauses = %s, want %s", c.name, got, strings.Join(c.want, ","))
+		}
+	}
+}
+
+func TestParseVeraPDFReport(t *testing.T) {
+	report := `<?xml version="1.0" encoding="utf-8"?>
+<report>
+  <jobs>
+    <job>
+      <item size="1234"><name>/tmp/rendition.pdf</name></item>
+      <validationReport profileName="PDF/A-2B validation profile" isCompliant="false">
+        <details passedRules="120" failedRules="2" passedChecks="800" failedChecks="3">
+          <rule specification="ISO 19005-2:2011" clause="6.2.4.3" testNumber="2" status="failed" failedChecks="2">
+            <description>DeviceRGB shall only be used if a device independent DefaultRGB colour space has been set</description>
+          </rule>
+          <rule specification="ISO 19005-2:2011" clause="6.2.11.4.1" testNumber="1" status="failed" failedChecks="1">
+            <description>The font programs for all fonts used for rendering within a conforming file shall be embedded</description>
+          </rule>
+        </details>
+      </validationReport>
+    </job>
+  </jobs>
+</report>`
+
+	v, err := pdfa.ParseVeraPDFReport([]byte(report))
+	if err != nil {
+		t.Fatalf("ParseVeraPDFReport failed: %v", err)
+	}
+	if v.Compliant || v.Validator != pdfa.ValidatorVeraPDF {
+		t.Errorf("unexpected validation: %+v", v)
+	}
+	if got := strings.Join(issueClauses(v), ","); got != "6.2.4.3-2,6.2.11.4.1-1" {
+		t.Errorf("clauses = %s", got)
+	}
+	if !strings.HasPrefix(v.Issues[1].Message, "The font programs") {
+		t.Errorf("message = %q", v.Issues[1].Message)
+	}
+
+	compliant := `<report><jobs><job><validationReport isCompliant="true"><details/></validationReport></job></jobs></report>`
+	if v, err := pdfa.ParseVeraPDFReport([]byte(compliant)); err != nil || !v.Compliant || len(v.Issues) != 0 {
+		t.Errorf("compliant report: %+v, %v", v, err)
+	}
+
+	failed := `<report><jobs><job><taskException><exceptionMessage>Couldn't parse stream</exceptionMessage></taskException></job></jobs></report>`
+	if _, err := pdfa.ParseVeraPDFReport([]byte(failed)); err == nil || !strings.Contains(err.Error(), "Couldn't parse stream") {
+		t.Errorf("expected task exception, got %v", err)
+	}
+}
+
+// writeTool writes an executable shell script standing in for an external tool
+func writeTool(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newFakeConverter(t *testing.T) (*pdfa.Converter, string) {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "calls.log")
+
+	// Ghostscript: copy the last argument (the input PDF) to -sOutputFile
+	gs := writeTool(t, dir, "gs", `out=""; for a in "$@"; do case "$a" in -sOutputFile=*) out="${a#-sOutputFile=}";; esac; last="$a"; done
+echo "gs $*" >> `+log+`
+grep -q BROKEN "$last" && { echo "Error: /syntaxerror" >&2; exit 1; }
+cp "$last" "$out"
+`)
+	// LibreOffice: write a PDF named after the input into --outdir
+	soffice := writeTool(t, dir, "soffice", `outdir=""; prev=""; for a in "$@"; do [ "$prev" = "--outdir" ] && outdir="$a"; prev="$a"; last="$a"; done
+echo "soffice $*" >> `+log+`
+mkdir -p "$outdir"; base=$(basename "$last"); printf '%%PDF-1.7 from %s' "$base" > "$outdir/${base%.*}.pdf"
+`)
+
+	return pdfa.NewConverter(pdfa.Config{
+		GhostscriptPath: gs,
+		LibreOfficePath: soffice,
+		ICCProfile:      "/profiles/srgb.icc",
+		Timeout:         10 * time.Second,
+	}), log
+}
+
+func TestPDFAConvert(t *testing.T) {
+	converter, log := newFakeConverter(t)
+	ctx := context.Background()
+
+	out, err := converter.Convert(ctx, []byte("%PDF-1.4 original"), "application/pdf")
+	if err != nil {
+		t.Fatalf("Convert PDF failed: %v", err)
+	}
+	if string(out) != "%PDF-1.4 original" {
+		t.Errorf("output = %q", out)
+	}
+
+	out, err = converter.Convert(ctx, []byte("PK docx"), "application/vnd.openxmlformats-officedocument.wordprocessingml.document")
+	if err != nil {
+		t.Fatalf("Convert DOCX failed: %v", err)
+	}
+	if string(out) != "%PDF-1.7 from document.docx" {
+		t.Errorf("output = %q", out)
+	}
+
+	calls, _ := os.ReadFile(log)
+	for _, want := range []string{"-dPDFA=2", "-dPDFACompatibilityPolicy=1", "--permit-file-read=/profiles/srgb.icc", "PDFA_def.ps", "--headless", "--convert-to pdf"} {
+		if !strings.Contains(string(calls), want) {
+			t.Errorf("tool calls miss %q:\n%s", want, calls)
+		}
+	}
+	if n := strings.Count(string(calls), "soffice "); n != 1 {
+		t.Errorf("LibreOffice called %d times, want once for the DOCX", n)
+	}
+
+	_, err = converter.Convert(ctx, []byte("%PDF-1.4 BROKEN"), "application/pdf")
+	if !errors.Is(err, pdfa.ErrConversionFailed) || !strings.Contains(err.Error(), "syntaxerror") {
+		t.Errorf("expected conversion failure with tool output, got %v", err)
+	}
+
+	if _, err := converter.Convert(ctx, []byte("PNG"), "image/png"); !errors.Is(err, pdfa.ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+
+	// Without veraPDF the builtin check validates the rendition
+	v, err := converter.Validate(ctx, out)
+	if err != nil || v.Validator != pdfa.ValidatorBuiltin || v.Compliant {
+		t.Errorf("fake rendition should fail the builtin check: %+v, %v", v, err)
+	}
+}
+
+func TestPDFAConvertMissingTool(t *testing.T) {
+	converter := pdfa.NewConverter(pdfa.Config{GhostscriptPath: filepath.Join(t.TempDir(), "missing-gs")})
+	_, err := converter.Convert(context.Background(), []byte("%PDF-1.4"), "application/pdf")
+	if !errors.Is(err, pdfa.ErrConversionFailed) || !strings.Contains(err.Error(), "not available") {
+		t.Errorf("expected missing tool error, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/perf"
+)
+
+func TestPerfPercentileAndBudget(t *testing.T) {
+	var latencies []time.Duration
+	for i := 20; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*10*time.Millisecond)
+	}
+
+	s := perf.Summarize("documents_list", latencies, 0)
+	if s.P50 != 100*time.Millisecond || s.P95 != 190*time.Millisecond || s.P99 != 200*time.Millisecond || s.Max != 200*time.Millisecond {
+		t.Errorf("unexpected percentiles: p50=%s p95=%s p99=%s max=%s", s.P50, s.P95, s.P99, s.Max)
+	}
+
+	budget := perf.Budget{Name: "documents_list", P95MS: 200, P99MS: 250}
+	if r := perf.Evaluate(budget, s); !r.Passed() {
+		t.Errorf("expected budget to be met: %v", r.Violations)
+	}
+
+	budget.P95MS = 150
+	if r := perf.Evaluate(budget, s); r.Passed() || !strings.Contains(r.Violations[0], "p95") {
+		t.Errorf("expected p95 violation, got %v", r.Violations)
+	}
+
+	failing := perf.Summarize("documents_list", latencies, 1)
+	if r := perf.Evaluate(perf.Budget{Name: "documents_list", P95MS: 500}, failing); r.Passed() {
+		t.Error("errors above max_error_rate must fail the budget")
+	}
+	if r := perf.Evaluate(perf.Budget{Name: "documents_list", P95MS: 500}, perf.Summary{}); r.Passed() {
+		t.Error("a check without samples must fail")
+	}
+}
+
+func TestPerfDefaultBudgets(t *testing.T) {
+	budgets := perf.DefaultBudgets()
+	for _, name := range []string{"login", "documents_list", "invoices_list", "document_upload", "analysis_enqueue"} {
+		b, ok := budgets.Get(name)
+		if !ok {
+			t.Errorf("missing budget %s", name)
+			continue
+		}
+		if strings.HasSuffix(name, "_list") && b.P95() >= time.Second {
+			t.Errorf("%s: list budget %s is too lax", name, b.P95())
+		}
+	}
+
+	invalid := []string{
+		`{"budgets": []}`,
+		`{"budgets": [{"name": "a", "p95_ms": 0}]}`,
+		`{"budgets": [{"name": "a", "p95_ms": 100, "p99_ms": 50}]}`,
+		`{"budgets": [{"name": "a", "p95_ms": 100}, {"name": "a", "p95_ms": 100}]}`,
+		`{"budgets": [{"name": "a", "method": "GET", "p95_ms": 100}]}`,
+	}
+	for _, doc := range invalid {
+		if _, err := perf.ParseBudgets([]byte(doc)); err == nil {
+			t.Errorf("expected error for %s", doc)
+		}
+	}
+}
+
+func TestPerfRunner(t *testing.T) {
+	budgets, err := perf.ParseBudgets([]byte(`{"budgets": [
+		{"name": "health", "method": "GET", "path": "/health", "p95_ms": 100},
+		{"name": "broken", "p95_ms": 100}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	health, _ := budgets.Get("health")
+	checks := []perf.Check{
+		perf.HTTPCheck(health, handler, "token", nil),
+		{Name: "broken", Run: func(context.Context, int) error { return errors.New("boom") }},
+	}
+
+	runner := &perf.Runner{Budgets: budgets, Iterations: 10, Warmup: 2}
+	report, err := runner.Run(context.Background(), checks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed() || !report.Results[0].Passed() || report.Results[1].Passed() {
+		t.Errorf("unexpected results: %+v", report.Results)
+	}
+	if report.Results[0].Count != 10 || report.Results[1].Errors != 10 || len(report.Errors) != 1 {
+		t.Errorf("unexpected counts: %+v, errors %v", report.Results, report.Errors)
+	}
+
+	var out strings.Builder
+	if err := report.Write(&out); err != nil || !strings.Contains(out.String(), "FAIL") {
+		t.Errorf("report misses the failure:\n%s", out.String())
+	}
+
+	if _, err := runner.Run(context.Background(), []perf.Check{{Name: "unknown"}}); err == nil {
+		t.Error("expected error for a check without budget")
+	}
+}
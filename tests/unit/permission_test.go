@@ -0,0 +1,98 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/auth"
+	"austrian-business-infrastructure/internal/permission"
+)
+
+type fakePermissionResolver struct {
+	perms []permission.Permission
+	err   error
+	calls int
+}
+
+func (f *fakePermissionResolver) CustomPermissions(ctx context.Context, tenantID, userID string) ([]permission.Permission, error) {
+	f.calls++
+	return f.perms, f.err
+}
+
+func TestPermissionBuiltinRoles(t *testing.T) {
+	for _, role := range []string{"owner", "admin"} {
+		if !permission.HasAll(permission.Builtin(role), []permission.Permission{permission.UVASubmit, permission.InvoicesSend, permission.RolesManage}) {
+			t.Errorf("%s lacks permissions: %v", role, permission.Builtin(role))
+		}
+	}
+	for _, role := range []string{"member", "viewer"} {
+		perms := permission.Builtin(role)
+		if !permission.Has(perms, permission.UVARead) || permission.Has(perms, permission.UVAWrite) || permission.Has(perms, permission.RolesManage) {
+			t.Errorf("%s = %v, want read only", role, perms)
+		}
+	}
+	if perms := permission.Builtin("superuser"); len(perms) != 0 {
+		t.Errorf("unknown role = %v, want none", perms)
+	}
+	if !permission.IsBuiltinRole(" Admin ") || permission.IsBuiltinRole("UVA-Einreichung") {
+		t.Error("IsBuiltinRole")
+	}
+}
+
+func TestPermissionUnion(t *testing.T) {
+	got := permission.Union(
+		[]permission.Permission{permission.InvoicesRead, permission.UVARead},
+		[]permission.Permission{permission.UVASubmit, permission.UVARead, "ledger:export"},
+	)
+	want := []permission.Permission{permission.UVARead, permission.UVASubmit, permission.InvoicesRead}
+	if len(got) != len(want) {
+		t.Fatalf("Union = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Union = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	serve := func(mw *auth.AuthMiddleware, role string, p permission.Permission) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/uva/x/submit", nil)
+		ctx := context.WithValue(req.Context(), api.UserRoleKey, role)
+		ctx = context.WithValue(ctx, api.TenantIDKey, "tenant-1")
+		ctx = context.WithValue(ctx, api.UserIDKey, "user-1")
+		rec := httptest.NewRecorder()
+		mw.RequirePermission(p)(next).ServeHTTP(rec, req.WithContext(ctx))
+		return rec.Code
+	}
+
+	mw := auth.NewAuthMiddleware(nil)
+	if code := serve(mw, "", permission.UVARead); code != http.StatusUnauthorized {
+		t.Errorf("no role: status %d, want 401", code)
+	}
+	if code := serve(mw, "member", permission.UVASubmit); code != http.StatusForbidden {
+		t.Errorf("member without resolver: status %d, want 403", code)
+	}
+
+	resolver := &fakePermissionResolver{perms: []permission.Permission{permission.UVAWrite, permission.UVASubmit}}
+	mw.SetPermissionResolver(resolver)
+	if code := serve(mw, "admin", permission.UVASubmit); code != http.StatusNoContent || resolver.calls != 0 {
+		t.Errorf("admin: status %d, resolver calls %d; want 204 without resolving", code, resolver.calls)
+	}
+	if code := serve(mw, "member", permission.UVASubmit); code != http.StatusNoContent {
+		t.Errorf("member with custom role: status %d, want 204", code)
+	}
+	if code := serve(mw, "member", permission.InvoicesSend); code != http.StatusForbidden {
+		t.Errorf("member without custom permission: status %d, want 403", code)
+	}
+
+	mw.SetPermissionResolver(&fakePermissionResolver{perms: resolver.perms, err: errors.New("db down")})
+	if code := serve(mw, "member", permission.UVASubmit); code != http.StatusForbidden {
+		t.Errorf("resolver error: status %d, want 403", code)
+	}
+}
@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/pflichten"
+	"austrian-business-infrastructure/internal/uva"
+)
+
+func TestPflichtenScore(t *testing.T) {
+	cases := []struct {
+		kategorie string
+		tage      int
+		abgelehnt bool
+		score     int
+		stufe     string
+	}{
+		{pflichten.KategorieUVA, -1, false, 100, pflichten.StufeKritisch},
+		{pflichten.KategorieUVA, -1, true, 100, pflichten.StufeKritisch},
+		{pflichten.KategorieELDA, 3, false, 75, pflichten.StufeHoch},
+		{pflichten.KategorieELDA, 3, true, 85, pflichten.StufeKritisch},
+		{pflichten.KategorieVertrag, 10, false, 45, pflichten.StufeMittel},
+		{pflichten.KategorieAntrag, 20, false, 25, pflichten.StufeNiedrig},
+		{pflichten.KategorieZahlung, 60, false, 20, pflichten.StufeNiedrig},
+	}
+	for _, c := range cases {
+		score := pflichten.Score(c.kategorie, c.tage, c.abgelehnt)
+		if score != c.score || pflichten.StufeFuer(score) != c.stufe {
+			t.Errorf("%s in %d days (abgelehnt %v): expected %d/%s, got %d/%s",
+				c.kategorie, c.tage, c.abgelehnt, c.score, c.stufe, score, pflichten.StufeFuer(score))
+		}
+	}
+}
+
+func TestPflichtenUVAPeriode(t *testing.T) {
+	// 15 March 2025 is a Saturday
+	if got := (pflichten.UVAPeriode{Year: 2025, Month: 1}).Faellig(); !got.Equal(time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the January UVA due on Monday 17 March, got %s", got)
+	}
+	if got := (pflichten.UVAPeriode{Year: 2025, Quarter: 4}).Faellig(); !got.Equal(time.Date(2026, 2, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected Q4/2025 due on 16 February 2026, got %s", got)
+	}
+	if next := (pflichten.UVAPeriode{Year: 2025, Month: 12}).Next(); next.Key() != "2026-01" {
+		t.Errorf("expected 2026-01 after December, got %s", next.Key())
+	}
+}
+
+func TestPflichtenUVA(t *testing.T) {
+	accountID := uuid.New()
+	filings := []pflichten.UVAFiling{
+		{AccountID: accountID, AccountName: "Muster GmbH", Periode: pflichten.UVAPeriode{Year: 2025, Month: 1}, Status: uva.StatusAccepted},
+		{AccountID: accountID, AccountName: "Muster GmbH", Periode: pflichten.UVAPeriode{Year: 2025, Month: 3}, Status: uva.StatusRejected, Fehler: "KZ060 fehlt"},
+		{AccountID: accountID, AccountName: "Muster GmbH", Periode: pflichten.UVAPeriode{Year: 2025, Month: 4}, Status: uva.StatusDraft},
+	}
+
+	// due by 16 June: the UVA up to April
+	list := pflichten.UVAPflichten(filings, time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC))
+	titles := make(map[string]*pflichten.Pflicht)
+	for _, p := range list {
+		titles[p.Titel] = p
+	}
+	if len(list) != 3 {
+		t.Fatalf("expected 3 UVA obligations, got %d: %v", len(list), titles)
+	}
+	if p := titles["UVA 02/2025 fehlt"]; p == nil || p.Key != "uva:"+accountID.String()+":2025-02" || p.QuelleID != nil {
+		t.Errorf("expected the missing February UVA, got %+v", p)
+	}
+	if p := titles["UVA 03/2025 abgelehnt"]; p == nil || !p.Abgelehnt || p.Hinweis != "KZ060 fehlt" {
+		t.Errorf("expected the rejected March UVA, got %+v", p)
+	}
+	if p := titles["UVA 04/2025 einreichen"]; p == nil || !p.Faellig.Equal(time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the April draft due on 16 June, got %+v", p)
+	}
+
+	// a month later May is missing as well
+	list = pflichten.UVAPflichten(filings, time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC))
+	if last := list[len(list)-1]; last.Titel != "UVA 05/2025 fehlt" {
+		t.Errorf("expected the missing May UVA, got %s", last.Titel)
+	}
+}
+
+func TestPflichtenELDAFaelligkeit(t *testing.T) {
+	eintritt := time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC)
+	austritt := time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2025, 5, 20, 14, 30, 0, 0, time.UTC)
+
+	anmeldung := &pflichten.ELDAOffen{MeldungID: uuid.New(), Type: "ANMELDUNG", Status: "draft", Name: "Max Muster", Eintritt: &eintritt, CreatedAt: created}
+	p := pflichten.ELDAPflicht(anmeldung)
+	if !p.Faellig.Equal(eintritt) || p.Titel != "ELDA-Anmeldung Max Muster einreichen" {
+		t.Errorf("expected the Anmeldung due when work starts, got %s: %s", p.Faellig, p.Titel)
+	}
+
+	abmeldung := &pflichten.ELDAOffen{MeldungID: uuid.New(), Type: "ABMELDUNG", Status: "rejected", Austritt: &austritt, CreatedAt: created}
+	p = pflichten.ELDAPflicht(abmeldung)
+	if !p.Faellig.Equal(time.Date(2025, 6, 7, 0, 0, 0, 0, time.UTC)) || !p.Abgelehnt {
+		t.Errorf("expected the rejected Abmeldung due seven days after the end, got %+v", p)
+	}
+
+	korrektur := &pflichten.ELDAOffen{MeldungID: uuid.New(), Type: "KORREKTUR", Status: "draft", CreatedAt: created}
+	if got := pflichten.ELDAFaelligkeit(korrektur); !got.Equal(time.Date(2025, 5, 27, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected a Korrektur due seven days after it was drafted, got %s", got)
+	}
+}
+
+func TestPflichtenAuswerten(t *testing.T) {
+	today := time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC)
+	muster, beispiel := uuid.New(), uuid.New()
+	list := []*pflichten.Pflicht{
+		{Key: "vertrag:1", AccountID: muster, AccountName: "Muster GmbH", Kategorie: pflichten.KategorieVertrag, Faellig: today.AddDate(0, 0, 10)},
+		{Key: "antrag:1:nachfassen", AccountID: beispiel, AccountName: "Beispiel KG", Kategorie: pflichten.KategorieAntrag, Faellig: today.AddDate(0, 0, 20)},
+		{Key: "uva:1:2025-03", AccountID: muster, AccountName: "Muster GmbH", Kategorie: pflichten.KategorieUVA, Faellig: today.AddDate(0, 0, -5)},
+		{Key: "elda:1", AccountID: beispiel, AccountName: "Beispiel KG", Kategorie: pflichten.KategorieELDA, Faellig: today.AddDate(0, 0, 2)},
+	}
+	snoozes := map[string]time.Time{
+		"elda:1":    today.AddDate(0, 0, 7),
+		"vertrag:1": today, // ended
+	}
+
+	firmen, snoozed := pflichten.Auswerten(list, snoozes, today, false)
+	if snoozed != 1 || len(firmen) != 2 {
+		t.Fatalf("expected 2 companies with one obligation snoozed, got %d and %d", len(firmen), snoozed)
+	}
+	if f := firmen[0]; f.AccountID != muster || f.Stufe != pflichten.StufeKritisch || f.Pflichten[0].Key != "uva:1:2025-03" {
+		t.Errorf("expected Muster GmbH with the overdue UVA first, got %+v", f)
+	}
+	if p := firmen[0].Pflichten[0]; p.TageBisFaellig != -5 {
+		t.Errorf("expected the UVA 5 days overdue, got %d", p.TageBisFaellig)
+	}
+	if f := firmen[1]; len(f.Pflichten) != 1 || f.Stufe != pflichten.StufeNiedrig {
+		t.Errorf("expected Beispiel KG with only the Antrag, got %+v", f)
+	}
+
+	firmen, snoozed = pflichten.Auswerten(list, snoozes, today, true)
+	if snoozed != 1 || firmen[1].Pflichten[0].Key != "elda:1" || firmen[1].Pflichten[0].SnoozedUntil == nil {
+		t.Errorf("expected the snoozed ELDA Meldung listed with its snooze, got %+v", firmen[1].Pflichten[0])
+	}
+}
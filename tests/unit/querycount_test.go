@@ -0,0 +1,64 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"austrian-business-infrastructure/pkg/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestQueryCounter(t *testing.T) {
+	var tracer database.QueryCounter
+	ctx, count := database.WithQueryCount(context.Background())
+
+	// Two plain queries and one batch of three queries
+	tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 2"})
+	tracer.TraceBatchStart(ctx, nil, pgx.TraceBatchStartData{})
+	for i := 0; i < 3; i++ {
+		tracer.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "SELECT 3"})
+	}
+
+	if count.Queries() != 5 {
+		t.Errorf("expected 5 queries, got %d", count.Queries())
+	}
+	if count.RoundTrips() != 3 {
+		t.Errorf("expected 3 round trips, got %d", count.RoundTrips())
+	}
+
+	// Queries without a counting context are not counted
+	tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 4"})
+	if count.Queries() != 5 {
+		t.Errorf("query without counting context was counted")
+	}
+
+	cfg := &pgx.ConnConfig{}
+	database.CountQueries(cfg)
+	if _, ok := cfg.Tracer.(database.QueryCounter); !ok {
+		t.Errorf("expected QueryCounter tracer, got %T", cfg.Tracer)
+	}
+}
+
+func TestBatchHelpers(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	ids := database.UniqueIDs([]uuid.UUID{a, uuid.Nil, b, a, b})
+	if len(ids) != 2 || ids[0] != a || ids[1] != b {
+		t.Errorf("unexpected unique ids: %v", ids)
+	}
+
+	type row struct {
+		parent uuid.UUID
+		n      int
+	}
+	rows := []row{{a, 1}, {b, 2}, {a, 3}}
+	groups := database.GroupBy(rows, func(r row) uuid.UUID { return r.parent })
+	if len(groups[a]) != 2 || groups[a][0].n != 1 || groups[a][1].n != 3 {
+		t.Errorf("unexpected group for a: %v", groups[a])
+	}
+	if len(groups[b]) != 1 || len(groups[uuid.New()]) != 0 {
+		t.Errorf("unexpected groups: %v", groups)
+	}
+}
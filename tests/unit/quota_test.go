@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/quota"
+
+	"github.com/google/uuid"
+)
+
+func TestQuotaCheck(t *testing.T) {
+	q := &quota.Quota{QuotaBytes: 10 << 30, WarnPercent: 80}
+
+	if err := quota.Check(q, 10<<30-1024, 1024); err != nil {
+		t.Errorf("expected a file filling the quota exactly to pass, got %v", err)
+	}
+
+	err := quota.Check(q, 10<<30-1024, 2*1024*1024)
+	if !errors.Is(err, quota.ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
+	}
+	var exceeded *quota.ExceededError
+	if !errors.As(err, &exceeded) || exceeded.RequestedBytes != 2*1024*1024 {
+		t.Errorf("expected an ExceededError with the requested size, got %v", err)
+	}
+	if want := "storage quota exceeded: 10.0 GiB of 10.0 GiB used, file needs 2.0 MiB"; err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+
+	if err := quota.Check(&quota.Quota{}, 1<<40, 1<<30); err != nil {
+		t.Errorf("expected no limit for quota 0, got %v", err)
+	}
+}
+
+func TestQuotaEvaluate(t *testing.T) {
+	breakdown := &quota.Breakdown{
+		Documents: []*quota.TypeUsage{
+			{Type: "bescheid", Count: 3, Bytes: 500},
+			{Type: "mitteilung", Count: 1, Bytes: 200},
+		},
+		ClientUploads: &quota.TypeUsage{Type: "client_uploads", Count: 1, Bytes: 80},
+		ExtractedText: &quota.TypeUsage{Type: "extracted_text", Count: 3, Bytes: 20},
+	}
+
+	usage := quota.Evaluate(&quota.Quota{QuotaBytes: 1000, WarnPercent: 80}, breakdown)
+	if usage.UsedBytes != 800 || !usage.Warning || usage.Exceeded {
+		t.Errorf("expected 800 bytes with warning only, got %+v", usage)
+	}
+	if usage.PercentUsed == nil || *usage.PercentUsed != 80 {
+		t.Errorf("expected 80 percent used, got %v", usage.PercentUsed)
+	}
+
+	usage = quota.Evaluate(&quota.Quota{QuotaBytes: 800, WarnPercent: 80}, breakdown)
+	if !usage.Exceeded {
+		t.Error("expected a fully used quota to be exceeded")
+	}
+
+	usage = quota.Evaluate(&quota.Quota{WarnPercent: 80}, breakdown)
+	if usage.PercentUsed != nil || usage.Warning || usage.Exceeded {
+		t.Errorf("expected no percentage or flags when unlimited, got %+v", usage)
+	}
+}
+
+func TestQuotaSuggest(t *testing.T) {
+	if s := quota.Suggest(0, 0, 0, 0); len(s) != 0 {
+		t.Errorf("expected no suggestions, got %d", len(s))
+	}
+
+	s := quota.Suggest(640, 10_000_000, 2, 1_000_000)
+	if len(s) != 2 {
+		t.Fatalf("expected 2 suggestions, got %d", len(s))
+	}
+	if s[0].Action != quota.ActionCompressExtractedText || s[0].EstimatedSavingsBytes != 7_000_000 {
+		t.Errorf("expected text compression first with 7000000 bytes saved, got %+v", s[0])
+	}
+	if s[1].Action != quota.ActionDeleteExpiredDocuments || s[1].EstimatedSavingsBytes != 1_000_000 {
+		t.Errorf("expected expired documents second, got %+v", s[1])
+	}
+}
+
+func TestQuotaHandlerValidation(t *testing.T) {
+	router := api.NewRouter(nil)
+	passthrough := func(next http.Handler) http.Handler { return next }
+	quota.NewHandler(quota.NewService(nil, 0)).RegisterRoutes(router, passthrough, passthrough)
+
+	for _, body := range []string{`{}`, `{"quota_bytes": -1}`, `{"quota_bytes": 1024, "warn_percent": 0}`, `{"quota_bytes": 1024, "warn_percent": 101}`, `not json`} {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/storage/quota", strings.NewReader(body))
+		ctx := context.WithValue(req.Context(), api.TenantIDKey, uuid.New().String())
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req.WithContext(ctx))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d: %s", body, rec.Code, rec.Body.String())
+		}
+	}
+}
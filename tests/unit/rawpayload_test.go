@@ -0,0 +1,122 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/rawpayload"
+)
+
+func TestRawPayloadOutcomeAndTruncate(t *testing.T) {
+	tests := []struct {
+		accepted, answered bool
+		want               rawpayload.Outcome
+	}{
+		{true, true, rawpayload.OutcomeSuccess},
+		{true, false, rawpayload.OutcomeSuccess},
+		{false, true, rawpayload.OutcomeRejected},
+		{false, false, rawpayload.OutcomeError},
+	}
+	for _, tt := range tests {
+		if got := rawpayload.OutcomeOf(tt.accepted, tt.answered); got != tt.want {
+			t.Errorf("OutcomeOf(%v, %v) = %s, want %s", tt.accepted, tt.answered, got, tt.want)
+		}
+	}
+
+	b, cut := rawpayload.Truncate([]byte("<xml/>"), 3)
+	if string(b) != "<xm" || !cut {
+		t.Errorf("Truncate = %q, %v", b, cut)
+	}
+	b, cut = rawpayload.Truncate([]byte("<xml/>"), 0)
+	if string(b) != "<xml/>" || cut {
+		t.Errorf("Truncate without limit = %q, %v", b, cut)
+	}
+
+	if _, err := rawpayload.NewService(nil, rawpayload.ServiceConfig{EncryptionKey: []byte("short")}); err == nil {
+		t.Error("expected an error for an invalid encryption key")
+	}
+
+	// Nil service and recorder are no-ops
+	var svc *rawpayload.Service
+	svc.Save(context.Background(), rawpayload.Reference{Module: rawpayload.ModuleUVA}, rawpayload.NewRecorder())
+	var rec *rawpayload.Recorder
+	rec.Record(rawpayload.Exchange{Operation: "x"})
+	if rec.Exchanges() != nil {
+		t.Error("nil recorder returned exchanges")
+	}
+}
+
+func TestFonwsClientRecordsExchanges(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("busy"))
+			return
+		}
+		w.Write([]byte(`<Envelope><Body><ok/></Body></Envelope>`))
+	}))
+	defer srv.Close()
+
+	base := fonws.NewClient()
+	base.SetRetry(1, time.Millisecond)
+
+	rec := rawpayload.NewRecorder()
+	if _, err := base.WithRecorder(rec).Post(srv.URL, struct {
+		Value string `xml:"value"`
+	}{Value: "U12345678"}); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+
+	exchanges := rec.Exchanges()
+	if len(exchanges) != 2 {
+		t.Fatalf("expected 2 exchanges (retry included), got %d", len(exchanges))
+	}
+	if exchanges[0].StatusCode != http.StatusServiceUnavailable || string(exchanges[0].Response) != "busy" || exchanges[0].Error == "" {
+		t.Errorf("unexpected first exchange: %+v", exchanges[0])
+	}
+	if exchanges[1].StatusCode != http.StatusOK || !strings.Contains(string(exchanges[1].Request), "U12345678") {
+		t.Errorf("unexpected second exchange: %+v", exchanges[1])
+	}
+
+	// The original client stays unrecorded
+	if _, err := base.Post(srv.URL, struct{}{}); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	if len(rec.Exchanges()) != 2 {
+		t.Error("base client must not record")
+	}
+}
+
+func TestELDAClientRecordsFromContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("<Fault/>"))
+	}))
+	defer srv.Close()
+
+	client := elda.NewClientWithConfig(elda.ClientConfig{Endpoint: srv.URL})
+	rec := rawpayload.NewRecorder()
+	if _, err := client.TestConnection(rawpayload.WithRecorder(context.Background(), rec)); err == nil {
+		t.Fatal("expected an error for HTTP 500")
+	}
+
+	exchanges := rec.Exchanges()
+	if len(exchanges) != 1 {
+		t.Fatalf("expected 1 exchange, got %d", len(exchanges))
+	}
+	ex := exchanges[0]
+	if ex.Operation != "Ping" || ex.StatusCode != http.StatusInternalServerError || string(ex.Response) != "<Fault/>" {
+		t.Errorf("unexpected exchange: %+v", ex)
+	}
+	if !strings.Contains(string(ex.Request), "Ping") {
+		t.Errorf("request body not recorded: %s", ex.Request)
+	}
+}
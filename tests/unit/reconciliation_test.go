@@ -0,0 +1,128 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"austrian-business-infrastructure/internal/payment"
+	"austrian-business-infrastructure/internal/sepa"
+	"github.com/google/uuid"
+)
+
+const testMT940 = `{1:F01GIBAATWWAXXX0000000000}{2:O9400000000000GIBAATWWXXXX00000000000000000000N}{4:
+:20:STMT2026041
+:25:GIBAATWWXXX/AT611904300234573201
+:28C:00041/001
+:60F:C260414EUR10000,00
+:61:2604140414CR1200,00NTRFNONREF//BANKREF1
+:86:166?00SEPA-UEBERWEISUNG?20EREF+E2E-RE-2026-0042?21SVWZ+Rechnung RE-2026-0042?31AT483200000012345864?32Muster GmbH
+:61:2604150415DR35,50NCHGNONREF
+:86:Kontofuehrung April
+:62F:C260415EUR11164,50
+-}`
+
+func TestParseMT940(t *testing.T) {
+	stmt, err := sepa.ParseMT940([]byte(testMT940))
+	if err != nil {
+		t.Fatalf("ParseMT940: %v", err)
+	}
+	if stmt.ID != "STMT2026041" || stmt.Account.IBAN != "AT611904300234573201" || stmt.Account.Currency != "EUR" {
+		t.Errorf("header = %q %q %q", stmt.ID, stmt.Account.IBAN, stmt.Account.Currency)
+	}
+	if stmt.OpeningBalance != 1000000 || stmt.ClosingBalance != 1116450 {
+		t.Errorf("balances = %d, %d", stmt.OpeningBalance, stmt.ClosingBalance)
+	}
+	if len(stmt.Entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(stmt.Entries))
+	}
+
+	in := stmt.Entries[0]
+	if !in.IsCredit() || in.Amount != 120000 || in.BookingDate.Format("2006-01-02") != "2026-04-14" {
+		t.Errorf("credit entry = %+v", in)
+	}
+	if in.EndToEndID != "E2E-RE-2026-0042" || in.RemittanceInfo != "Rechnung RE-2026-0042" {
+		t.Errorf("remittance = %q / %q", in.EndToEndID, in.RemittanceInfo)
+	}
+	if in.CounterpartyIBAN != "AT483200000012345864" || in.CounterpartyName != "Muster GmbH" || in.Reference != "BANKREF1" {
+		t.Errorf("counterparty = %q %q %q", in.CounterpartyIBAN, in.CounterpartyName, in.Reference)
+	}
+
+	fee := stmt.Entries[1]
+	if !fee.IsDebit() || fee.Amount != 3550 || fee.RemittanceInfo != "Kontofuehrung April" {
+		t.Errorf("debit entry = %+v", fee)
+	}
+
+	if _, err := sepa.ParseMT940([]byte(":20:X\n:60F:C260414EUR1,00\n")); err == nil {
+		t.Error("statement without closing balance accepted")
+	}
+}
+
+func TestParseStatementFormat(t *testing.T) {
+	if _, format, err := payment.ParseStatement([]byte(testMT940)); err != nil || format != payment.FormatMT940 {
+		t.Errorf("MT940 detected as %q, %v", format, err)
+	}
+	_, _, err := payment.ParseStatement([]byte("date;amount\n"))
+	if !errors.Is(err, payment.ErrInvalidStatement) {
+		t.Errorf("CSV error = %v, want ErrInvalidStatement", err)
+	}
+}
+
+func TestReconciliationMatching(t *testing.T) {
+	str := func(s string) *string { return &s }
+	invoice := &payment.Candidate{Kind: payment.CandidateInvoice, ID: uuid.New(), Reference: "RE-2026-0042", Amount: 120000, Credit: true}
+	other := &payment.Candidate{Kind: payment.CandidateInvoice, ID: uuid.New(), Reference: "RE-2026-0043", Amount: 120000, Credit: true}
+	transfer := &payment.Candidate{Kind: payment.CandidatePayment, ID: uuid.New(), Reference: "PAY-7", EndToEndID: "PAY-7", Amount: 50000, IBAN: "DE89370400440532013000"}
+
+	// Invoice number and amount identify the invoice
+	txn := &payment.Transaction{CreditDebit: "CRDT", Amount: 120000, RemittanceInfo: str("Rechnung re 2026 0042")}
+	best, status := payment.AutoMatch(txn, []*payment.Candidate{invoice, other, transfer})
+	if status != payment.MatchMatched || best.ID != invoice.ID || best.Score != 90 {
+		t.Errorf("reference match = %v %+v", status, best)
+	}
+
+	// The amount alone fits both invoices: nothing to suggest
+	txn = &payment.Transaction{CreditDebit: "CRDT", Amount: 120000, RemittanceInfo: str("Danke")}
+	if best, status := payment.AutoMatch(txn, []*payment.Candidate{invoice, other}); status != payment.MatchUnmatched || best != nil {
+		t.Errorf("amount only = %v %+v", status, best)
+	}
+
+	// A longer number is not the invoice number
+	txn = &payment.Transaction{CreditDebit: "CRDT", Amount: 100, RemittanceInfo: str("RE-2026-00421")}
+	if score, _ := payment.Score(txn, invoice); score != 0 {
+		t.Errorf("RE-2026-00421 scored %d for RE-2026-0042", score)
+	}
+
+	// The end-to-end ID of an outgoing payment matches only debits
+	txn = &payment.Transaction{CreditDebit: "DBIT", Amount: 50000, EndToEndID: str("PAY-7"), CounterpartyIBAN: str("DE89 3704 0044 0532 0130 00")}
+	if score, reasons := payment.Score(txn, transfer); score != 150 || len(reasons) != 3 {
+		t.Errorf("payment score = %d %v, want 150", score, reasons)
+	}
+	txn.CreditDebit = "CRDT"
+	if score, _ := payment.Score(txn, transfer); score != 0 {
+		t.Errorf("credit scored %d against an outgoing payment", score)
+	}
+
+	// Reference without amount is a suggestion, e.g. a partial payment
+	txn = &payment.Transaction{CreditDebit: "CRDT", Amount: 60000, RemittanceInfo: str("RE-2026-0042 Teilzahlung")}
+	if best, status := payment.AutoMatch(txn, []*payment.Candidate{invoice}); status != payment.MatchSuggested || best.ID != invoice.ID {
+		t.Errorf("partial payment = %v %+v", status, best)
+	}
+}
+
+func TestReconciliationStatus(t *testing.T) {
+	tests := []struct {
+		paid int64
+		want string
+	}{
+		{0, payment.ReconciliationOpen},
+		{-500, payment.ReconciliationOpen},
+		{60000, payment.ReconciliationPartial},
+		{120000, payment.ReconciliationPaid},
+		{120100, payment.ReconciliationOverpaid},
+	}
+	for _, tt := range tests {
+		if got := payment.ReconciliationStatus(120000, tt.paid); got != tt.want {
+			t.Errorf("ReconciliationStatus(120000, %d) = %q, want %q", tt.paid, got, tt.want)
+		}
+	}
+}
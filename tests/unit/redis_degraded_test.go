@@ -0,0 +1,118 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"austrian-business-infrastructure/internal/auth"
+	"austrian-business-infrastructure/pkg/cache"
+)
+
+func TestRedisMonitorRecovers(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to create miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	client, err := cache.Dial(cache.DefaultRedisConfig("redis://" + mr.Addr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	monitor := cache.NewMonitor(client, 0, nil)
+	if !monitor.Check(ctx) {
+		t.Fatal("redis should be available")
+	}
+
+	mr.Close()
+	if monitor.Check(ctx) || monitor.Available() {
+		t.Fatal("redis should be unavailable after it stopped")
+	}
+	down := monitor.Status()
+
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	// The client backs off after failed dials, so recovery can take a second
+	deadline := time.Now().Add(5 * time.Second)
+	for !monitor.Check(ctx) {
+		if time.Now().After(deadline) {
+			t.Fatal("redis should be available again after it restarted")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if status := monitor.Status(); !status.Since.After(down.Since) {
+		t.Errorf("recovery not recorded: %+v after %+v", status, down)
+	}
+}
+
+type redisState bool
+
+func (s *redisState) Available() bool { return bool(*s) }
+
+func TestRevocationFlaggedInDegradedMode(t *testing.T) {
+	mr, client := setupTestRedis(t)
+	defer mr.Close()
+
+	available := redisState(true)
+	revList := auth.NewTokenRevocationList(client)
+	revList.SetAvailability(&available)
+	config := auth.DefaultJWTConfig("test-secret")
+	config.UseES256 = false
+	jwtManager := auth.NewJWTManager(config)
+	jwtManager.SetRevocationList(revList)
+
+	ctx := context.Background()
+	tokens, err := jwtManager.GenerateTokenPair(&auth.UserInfo{UserID: "user-123", TenantID: "tenant-456", Role: "member"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := jwtManager.ValidateAccessTokenWithContext(ctx, tokens.AccessToken)
+	if err != nil || claims.RevocationUnchecked {
+		t.Fatalf("claims = %+v, %v", claims, err)
+	}
+
+	// Known outage: accepted, but flagged
+	available = false
+	mr.Close()
+	if _, _, err := revList.CheckRevocation(ctx, claims); !errors.Is(err, auth.ErrRevocationUnchecked) {
+		t.Errorf("got %v, want ErrRevocationUnchecked", err)
+	}
+	claims, err = jwtManager.ValidateAccessTokenWithContext(ctx, tokens.AccessToken)
+	if err != nil || !claims.RevocationUnchecked {
+		t.Fatalf("degraded validation: claims = %+v, %v", claims, err)
+	}
+	if revList.Unchecked() != 2 {
+		t.Errorf("unchecked = %d, want 2", revList.Unchecked())
+	}
+
+	// Redis failing while reported available still fails closed
+	available = true
+	if _, err := jwtManager.ValidateAccessTokenWithContext(ctx, tokens.AccessToken); err != auth.ErrTokenRevoked {
+		t.Errorf("got %v, want ErrTokenRevoked", err)
+	}
+}
+
+func TestLoginRateLimitFailsClosedInDegradedMode(t *testing.T) {
+	mr, client := setupTestRedis(t)
+	defer mr.Close()
+
+	available := redisState(false)
+	limiter := auth.NewRateLimiter(client)
+	limiter.SetAvailability(&available)
+
+	if err := limiter.CheckLogin(context.Background(), "192.0.2.1"); !errors.Is(err, cache.ErrUnavailable) {
+		t.Errorf("got %v, want cache.ErrUnavailable", err)
+	}
+	available = true
+	if err := limiter.CheckLogin(context.Background(), "192.0.2.1"); err != nil {
+		t.Errorf("login limit with redis available: %v", err)
+	}
+}
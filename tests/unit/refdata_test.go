@@ -0,0 +1,277 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/erechnung"
+	"austrian-business-infrastructure/internal/invoice"
+	"austrian-business-infrastructure/internal/refdata"
+)
+
+func TestRefdataSelectsSetByDate(t *testing.T) {
+	table := refdata.Defaults()
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	if got := table.For(day(2024, time.December, 31)).ValidFrom; got != "2024-01-01" {
+		t.Errorf("31.12.2024: got set %s", got)
+	}
+	if got := table.For(day(2025, time.January, 1)).ValidFrom; got != "2025-01-01" {
+		t.Errorf("01.01.2025: got set %s", got)
+	}
+	// Before the first set the first one applies, after the last the last one
+	if got := table.For(day(2020, time.June, 1)).ValidFrom; got != "2024-01-01" {
+		t.Errorf("2020: got set %s", got)
+	}
+	last := table.Sets()[len(table.Sets())-1]
+	later := last.From().AddDate(3, 0, 0)
+	if got := table.For(later); got != last {
+		t.Errorf("%d: got set %s, want %s", later.Year(), got.ValidFrom, last.ValidFrom)
+	}
+	if table.Covers(later) || !table.Covers(last.From()) {
+		t.Error("Covers should only report years with a published set")
+	}
+
+	// Values depend on the reporting year, not on today
+	if elda.IsGeringfuegig(530, 2024) || !elda.IsGeringfuegig(530, 2025) {
+		t.Error("530 EUR is geringfügig in 2025 but not in 2024")
+	}
+	if !elda.ExceedsHoechstbeitrag(6200, 2024) || elda.ExceedsHoechstbeitrag(6200, 2025) {
+		t.Error("6.200 EUR exceeds the Höchstbeitragsgrundlage of 2024 but not of 2025")
+	}
+	if refdata.ForYear(2024).KleinunternehmerGrenze != 3500000 || refdata.ForYear(2025).KleinunternehmerGrenze != 5500000 {
+		t.Error("unexpected Kleinunternehmer thresholds")
+	}
+}
+
+func TestRefdataLohnsteuer(t *testing.T) {
+	p := refdata.ForYear(2025)
+
+	// 30.000 EUR: 20 % from 13.308 to 21.617, 30 % from 21.617
+	if got := p.Lohnsteuer(3000000); got != 417670 {
+		t.Errorf("Lohnsteuer 30.000 EUR = %d, want 417670", got)
+	}
+	if got := p.Lohnsteuer(1330800); got != 0 {
+		t.Errorf("income up to the first bracket must be tax free, got %d", got)
+	}
+	if got := p.Lohnsteuer(0); got != 0 {
+		t.Errorf("Lohnsteuer 0 = %d", got)
+	}
+}
+
+func TestRefdataParseAndMerge(t *testing.T) {
+	overlay := []byte(`{
+		"valid_from": "2025-01-01",
+		"geringfuegigkeitsgrenze": 60000,
+		"hoechstbeitragsgrundlage": 650000,
+		"sv_dienstnehmer_satz": 1807,
+		"sv_dienstgeber_satz": 2098,
+		"lohnsteuer_stufen": [{"bis": 1300000, "satz": 0}, {"bis": 0, "satz": 0.5}],
+		"kleinunternehmer_grenze": 5500000,
+		"mbgm_frist_tag": 15
+	}`)
+	sets, err := refdata.ParseSets(overlay, "update.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, err := refdata.NewTable(sets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged := refdata.Defaults().Merge(patch)
+	if len(merged.Sets()) != len(refdata.Defaults().Sets()) {
+		t.Errorf("a set starting on the same day must replace the embedded one, got %d sets", len(merged.Sets()))
+	}
+	p := merged.For(time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC))
+	if p.Geringfuegigkeitsgrenze != 60000 || p.Origin != "update.json" {
+		t.Errorf("overlay not applied: %+v", p)
+	}
+	if merged.For(time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)).Origin != refdata.OriginEmbedded {
+		t.Error("2024 set should stay embedded")
+	}
+
+	invalid := map[string]string{
+		"brackets not ascending": `{"valid_from": "2027-01-01", "geringfuegigkeitsgrenze": 1, "hoechstbeitragsgrundlage": 2, "sv_dienstnehmer_satz": 1, "sv_dienstgeber_satz": 1, "kleinunternehmer_grenze": 1, "mbgm_frist_tag": 15, "lohnsteuer_stufen": [{"bis": 200, "satz": 0}, {"bis": 100, "satz": 0.2}, {"bis": 0, "satz": 0.5}]}`,
+		"open bracket not last":  `{"valid_from": "2027-01-01", "geringfuegigkeitsgrenze": 1, "hoechstbeitragsgrundlage": 2, "sv_dienstnehmer_satz": 1, "sv_dienstgeber_satz": 1, "kleinunternehmer_grenze": 1, "mbgm_frist_tag": 15, "lohnsteuer_stufen": [{"bis": 0, "satz": 0}, {"bis": 100, "satz": 0.2}]}`,
+		"invalid date":           `{"valid_from": "01.01.2027"}`,
+		"defined twice":          `[{"valid_from": "2027-01-01", "geringfuegigkeitsgrenze": 1, "hoechstbeitragsgrundlage": 2, "sv_dienstnehmer_satz": 1, "sv_dienstgeber_satz": 1, "kleinunternehmer_grenze": 1, "mbgm_frist_tag": 15, "lohnsteuer_stufen": [{"bis": 0, "satz": 0.5}]}, {"valid_from": "2027-01-01", "geringfuegigkeitsgrenze": 1, "hoechstbeitragsgrundlage": 2, "sv_dienstnehmer_satz": 1, "sv_dienstgeber_satz": 1, "kleinunternehmer_grenze": 1, "mbgm_frist_tag": 15, "lohnsteuer_stufen": [{"bis": 0, "satz": 0.5}]}]`,
+	}
+	for name, data := range invalid {
+		sets, err := refdata.ParseSets([]byte(data), "bad.json")
+		if err == nil {
+			_, err = refdata.NewTable(sets)
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		} else if name == "defined twice" && !strings.Contains(err.Error(), "twice") {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+}
+
+func TestRefdataBUAKZuschlagssaetze(t *testing.T) {
+	want := refdata.BUAKZuschlagssaetze{Urlaub: 1540, Abfertigung: 460, Schlechtwetter: 140, Ueberbrueckung: 60}
+	if got := elda.GetBUAKZuschlagssaetze(2025); got != want {
+		t.Errorf("2025 rates = %+v, want %+v", got, want)
+	}
+
+	// A yearly update without BUAK rates keeps those of the year before
+	overlay := []byte(`[{
+		"valid_from": "2027-01-01",
+		"geringfuegigkeitsgrenze": 56500,
+		"hoechstbeitragsgrundlage": 720000,
+		"sv_dienstnehmer_satz": 1807,
+		"sv_dienstgeber_satz": 2098,
+		"lohnsteuer_stufen": [{"bis": 1380000, "satz": 0}, {"bis": 0, "satz": 0.55}],
+		"kleinunternehmer_grenze": 5500000,
+		"mbgm_frist_tag": 15
+	}, {
+		"valid_from": "2028-01-01",
+		"geringfuegigkeitsgrenze": 57000,
+		"hoechstbeitragsgrundlage": 740000,
+		"sv_dienstnehmer_satz": 1807,
+		"sv_dienstgeber_satz": 2098,
+		"lohnsteuer_stufen": [{"bis": 1400000, "satz": 0}, {"bis": 0, "satz": 0.55}],
+		"kleinunternehmer_grenze": 5500000,
+		"mbgm_frist_tag": 15,
+		"buak_zuschlagssaetze": {"urlaub": 1560, "abfertigung": 470, "schlechtwetter": 140, "ueberbrueckung": 60}
+	}]`)
+	sets, err := refdata.ParseSets(overlay, "update.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, err := refdata.NewTable(sets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := refdata.Defaults().Merge(patch)
+	if got := merged.For(time.Date(2027, time.March, 1, 0, 0, 0, 0, time.UTC)).BUAKZuschlagssaetze; got != want {
+		t.Errorf("2027 rates = %+v, want those of 2026", got)
+	}
+	if got := merged.For(time.Date(2028, time.March, 1, 0, 0, 0, 0, time.UTC)).BUAKZuschlagssaetze; got.Urlaub != 1560 || got.Abfertigung != 470 {
+		t.Errorf("2028 rates = %+v, want the published ones", got)
+	}
+
+	invalid := `{"valid_from": "2027-01-01", "geringfuegigkeitsgrenze": 1, "hoechstbeitragsgrundlage": 2, "sv_dienstnehmer_satz": 1, "sv_dienstgeber_satz": 1, "kleinunternehmer_grenze": 1, "mbgm_frist_tag": 15, "lohnsteuer_stufen": [{"bis": 0, "satz": 0.5}], "buak_zuschlagssaetze": {"urlaub": 15400}}`
+	if sets, err := refdata.ParseSets([]byte(invalid), "bad.json"); err != nil {
+		t.Fatal(err)
+	} else if _, err := refdata.NewTable(sets); err == nil || !strings.Contains(err.Error(), "buak_zuschlagssaetze.urlaub") {
+		t.Errorf("a rate of 154 %% should be rejected, got %v", err)
+	}
+}
+
+func TestRefdataVATRates(t *testing.T) {
+	table := refdata.DefaultVATRates()
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	if len(table.Countries()) != 27 {
+		t.Errorf("expected the rates of the 27 member states, got %v", table.Countries())
+	}
+	for _, tc := range []struct {
+		country  string
+		date     time.Time
+		standard float64
+	}{
+		{"DE", day(2020, time.June, 30), 19},
+		{"DE", day(2020, time.July, 1), 16},
+		{"de", day(2021, time.January, 1), 19},
+		{"AT", day(2025, time.March, 1), 20},
+		{"FI", day(2024, time.August, 31), 24},
+		{"FI", day(2024, time.September, 1), 25.5},
+		{"EL", day(2025, time.January, 1), 24},
+	} {
+		if got, ok := table.For(tc.country, tc.date); !ok || got.Standard != tc.standard {
+			t.Errorf("%s on %s: got %+v, want standard %v", tc.country, tc.date.Format("2006-01-02"), got, tc.standard)
+		}
+	}
+	if at, _ := table.For("AT", day(2021, time.June, 1)); !at.IsReduced(5) {
+		t.Error("5 % was a reduced rate in Austria in 2021")
+	}
+	if at, _ := table.For("AT", day(2022, time.January, 1)); at.IsReduced(5) || !at.IsReduced(13) {
+		t.Errorf("unexpected Austrian reduced rates in 2022: %v", at.Reduced)
+	}
+	if _, ok := table.For("CH", day(2025, time.January, 1)); ok {
+		t.Error("expected no rates outside the EU")
+	}
+
+	rates, err := refdata.ParseVATRates([]byte(`{"country": "EE", "valid_from": "2025-07-01", "standard": 25, "reduced": [9, 13]}`), "update.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, err := refdata.NewVATTable(rates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := table.Merge(patch)
+	if len(merged.Rates()) != len(table.Rates()) {
+		t.Errorf("a set starting on the same day must replace the embedded one, got %d sets", len(merged.Rates()))
+	}
+	if ee, _ := merged.For("EE", day(2025, time.August, 1)); ee.Standard != 25 || ee.Origin != "update.json" {
+		t.Errorf("overlay not applied: %+v", ee)
+	}
+
+	for name, data := range map[string]string{
+		"reduced above standard": `{"country": "AT", "valid_from": "2027-01-01", "standard": 20, "reduced": [25]}`,
+		"invalid country":        `{"country": "AUT", "valid_from": "2027-01-01", "standard": 20}`,
+		"defined twice":          `[{"country": "AT", "valid_from": "2027-01-01", "standard": 20}, {"country": "at", "valid_from": "2027-01-01", "standard": 21}]`,
+	} {
+		rates, err := refdata.ParseVATRates([]byte(data), "bad.json")
+		if err == nil {
+			_, err = refdata.NewVATTable(rates)
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestInvoiceVATRateConsistency(t *testing.T) {
+	oss := invoice.TaxCodeOSS
+	line := func(id, category string, percent float64) *erechnung.InvoiceLine {
+		return &erechnung.InvoiceLine{ID: id, TaxCategory: category, TaxPercent: percent}
+	}
+	inv := &erechnung.Invoice{
+		IssueDate: time.Date(2020, time.October, 1, 0, 0, 0, 0, time.UTC),
+		Seller:    &erechnung.InvoiceParty{Country: "AT"},
+		Buyer:     &erechnung.InvoiceParty{Country: "DE"},
+		Lines: []*erechnung.InvoiceLine{
+			line("1", erechnung.TaxCategoryStandard, 20),
+			line("2", erechnung.TaxCategoryReduced, 5),
+			line("3", erechnung.TaxCategoryReduced, 7),
+		},
+	}
+
+	result := &erechnung.InvoiceValidationResult{Valid: true}
+	invoice.CheckVATRates(result, inv, nil)
+	if result.Valid || len(result.Errors) != 1 || result.Errors[0].Code != "VAT-AA-RATE" || result.Errors[0].Field != "lines[3].tax_percent" {
+		t.Errorf("expected only the 7 %% line to fail in Austria: %+v", result.Errors)
+	}
+
+	// OSS sales are taxed at the rates of the buyer's country
+	inv.Lines = []*erechnung.InvoiceLine{line("1", erechnung.TaxCategoryStandard, 16), line("2", erechnung.TaxCategoryReduced, 5)}
+	result = &erechnung.InvoiceValidationResult{Valid: true}
+	invoice.CheckVATRates(result, inv, &oss)
+	if !result.Valid {
+		t.Errorf("expected the German rates of October 2020 to pass: %+v", result.Errors)
+	}
+	inv.IssueDate = time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	result = &erechnung.InvoiceValidationResult{Valid: true}
+	invoice.CheckVATRates(result, inv, &oss)
+	if len(result.Errors) != 2 || result.Errors[0].Code != "VAT-S-RATE" {
+		t.Errorf("expected both lines to fail after the rates returned to 19/7 %%: %+v", result.Errors)
+	}
+
+	inv.Buyer.Country = "CH"
+	result = &erechnung.InvoiceValidationResult{Valid: true}
+	invoice.CheckVATRates(result, inv, &oss)
+	if !result.Valid || len(result.Warnings) != 1 || result.Warnings[0].Code != "VAT-COUNTRY" {
+		t.Errorf("expected a warning for a country without rates: %+v", result)
+	}
+}
@@ -0,0 +1,139 @@
+package unit
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/replay"
+	"github.com/google/uuid"
+)
+
+func TestReplayNormalizeIgnoresFormatting(t *testing.T) {
+	a := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Meldung xmlns="http://elda.at" version="1"><Kopf><Datum>2025-01-01</Datum></Kopf><Betrag>100,00</Betrag></Meldung>`)
+	b := []byte(`<Meldung version="1"  xmlns="http://elda.at">
+    <Kopf>
+        <Datum>2026-10-16</Datum>
+    </Kopf>
+    <Betrag>100,00</Betrag>
+</Meldung>`)
+
+	la, err := replay.Normalize(a, map[string]bool{"Datum": true})
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	lb, err := replay.Normalize(b, map[string]bool{"Datum": true})
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+
+	diff, err := replay.Diff(la, lb)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if !diff.Identical {
+		t.Errorf("expected identical documents, got:\n%s", diff.Unified)
+	}
+
+	// Without ignoring Datum the changed date is the only difference
+	la, _ = replay.Normalize(a, nil)
+	lb, _ = replay.Normalize(b, nil)
+	diff, _ = replay.Diff(la, lb)
+	if diff.Added != 1 || diff.Removed != 1 {
+		t.Fatalf("expected one changed line, got +%d -%d", diff.Added, diff.Removed)
+	}
+	if diff.Changes[0].Op != "-" || !strings.Contains(diff.Changes[0].Text, "2025-01-01") {
+		t.Errorf("unexpected change: %+v", diff.Changes[0])
+	}
+	if !strings.Contains(diff.Unified, "@@ -1,") || !strings.Contains(diff.Unified, "+    <Datum>2026-10-16</Datum>") {
+		t.Errorf("unexpected unified diff:\n%s", diff.Unified)
+	}
+}
+
+func TestReplayExtractDocument(t *testing.T) {
+	doc := `<?xml version="1.0"?><ERKLAERUNG art="U30"><KZ000>100</KZ000></ERKLAERUNG>`
+
+	// FinanzOnline file upload: base64 data
+	upload, err := fonws.BuildEnvelope(&fonws.FileUploadRequest{
+		Xmlns: fonws.FileUploadNS,
+		Art:   "U30",
+		Data:  base64.StdEncoding.EncodeToString([]byte(doc)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := replay.ExtractDocument(upload)
+	if err != nil {
+		t.Fatalf("ExtractDocument(upload): %v", err)
+	}
+	if string(got) != doc {
+		t.Errorf("upload document = %q", got)
+	}
+
+	// ELDA document submission: XML string
+	eldaEnv := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+		`<SubmitMBGM><Document>&lt;MBGM&gt;&lt;Jahr&gt;2026&lt;/Jahr&gt;&lt;/MBGM&gt;</Document></SubmitMBGM>` +
+		`</soap:Body></soap:Envelope>`
+	got, err = replay.ExtractDocument([]byte(eldaEnv))
+	if err != nil {
+		t.Fatalf("ExtractDocument(elda): %v", err)
+	}
+	if string(got) != "<MBGM><Jahr>2026</Jahr></MBGM>" {
+		t.Errorf("elda document = %q", got)
+	}
+
+	// ELDA meldung: document is the body itself
+	meldungEnv := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><Anmeldung><SVNummer>1234</SVNummer></Anmeldung></soap:Body></soap:Envelope>`
+	got, err = replay.ExtractDocument([]byte(meldungEnv))
+	if err != nil {
+		t.Fatalf("ExtractDocument(meldung): %v", err)
+	}
+	if string(got) != "<Anmeldung><SVNummer>1234</SVNummer></Anmeldung>" {
+		t.Errorf("meldung document = %q", got)
+	}
+}
+
+type fakeReplaySource struct {
+	rendition *replay.Rendition
+}
+
+func (f *fakeReplaySource) Render(ctx context.Context, tenantID, id uuid.UUID) (*replay.Rendition, error) {
+	if f.rendition == nil {
+		return nil, replay.ErrSubmissionNotFound
+	}
+	return f.rendition, nil
+}
+
+func TestReplayServiceCompareStored(t *testing.T) {
+	svc := replay.NewService(nil)
+	svc.Register(replay.KindUVA, &fakeReplaySource{rendition: &replay.Rendition{
+		StoredXML: []byte(`<UVA><KZ000>100</KZ000></UVA>`),
+		XML:       []byte(`<UVA><KZ000>100</KZ000><KZ095>20</KZ095></UVA>`),
+	}})
+
+	ctx := context.Background()
+	cmp, err := svc.Compare(ctx, uuid.New(), replay.KindUVA, uuid.New(), replay.CompareOptions{})
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if cmp.Baseline != replay.BaselineStored {
+		t.Errorf("baseline = %s, want stored without payload retention", cmp.Baseline)
+	}
+	if cmp.Diff.Identical || cmp.Diff.Added != 1 || cmp.Diff.Changes[0].Text != "  <KZ095>20</KZ095>" {
+		t.Errorf("unexpected diff: %+v", cmp.Diff)
+	}
+
+	if _, err := svc.Compare(ctx, uuid.New(), replay.KindUVA, uuid.New(), replay.CompareOptions{Baseline: replay.BaselinePayload}); !errors.Is(err, replay.ErrPayloadsUnavailable) {
+		t.Errorf("payload baseline without retention: got %v", err)
+	}
+	if _, err := svc.Compare(ctx, uuid.New(), replay.KindZM, uuid.New(), replay.CompareOptions{}); !errors.Is(err, replay.ErrUnknownKind) {
+		t.Errorf("unknown kind: got %v", err)
+	}
+	if _, err := svc.SubmitSandbox(ctx, uuid.New(), replay.KindUVA, uuid.New()); !errors.Is(err, replay.ErrSandboxUnsupported) {
+		t.Errorf("sandbox for render-only source: got %v", err)
+	}
+}
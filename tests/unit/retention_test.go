@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/config"
+	"austrian-business-infrastructure/internal/retention"
+)
+
+func TestRetentionClassify(t *testing.T) {
+	tests := []struct {
+		docType, class, basis string
+	}{
+		{"bescheid", retention.ClassAbgabenverfahren, "§ 132 Abs. 1 BAO"},
+		{" Vorhalt ", retention.ClassAbgabenverfahren, "§ 132 Abs. 1 BAO"},
+		{"rechnung", retention.ClassBelege, "§ 132 Abs. 1 BAO, § 11 UStG"},
+		{"email_attachment", retention.ClassGeschaeftsbriefe, "§ 212 Abs. 1 UGB"},
+		{"sonstige", retention.ClassSonstigeUnterlagen, "Art. 5 Abs. 1 lit. e DSGVO"},
+		{"", retention.ClassSonstigeUnterlagen, "Art. 5 Abs. 1 lit. e DSGVO"},
+	}
+	for _, tt := range tests {
+		class, basis := retention.Classify(tt.docType)
+		if class != tt.class || basis != tt.basis {
+			t.Errorf("Classify(%q) = %q, %q; want %q, %q", tt.docType, class, basis, tt.class, tt.basis)
+		}
+	}
+}
+
+func TestRetentionProtocolSignature(t *testing.T) {
+	signer := retention.NewSigner([]byte("protocol-key"))
+	started := time.Date(2026, 3, 2, 2, 0, 0, 0, time.UTC)
+	content := &retention.Content{
+		Version:       retention.ProtocolVersion,
+		ProtocolID:    uuid.New(),
+		TenantID:      uuid.New(),
+		StartedAt:     started,
+		FinishedAt:    started.Add(time.Minute),
+		DeletionBasis: retention.DeletionBasis,
+		DeletedCount:  1,
+		Entries: []retention.Entry{{
+			DocumentID:     uuid.New(),
+			Type:           "bescheid",
+			Class:          retention.ClassAbgabenverfahren,
+			RetentionUntil: started.AddDate(0, -2, 0),
+			DeletedAt:      started,
+		}},
+		Failures: []retention.Failure{},
+	}
+
+	doc, hash, signature, err := signer.Seal(content)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	p := &retention.Protocol{Document: doc, SHA256: hash, Signature: signature, KeyID: signer.KeyID()}
+	if v := signer.Verify(p); !v.Valid || !v.KeyMatches {
+		t.Fatalf("Verify = %+v, want valid", v)
+	}
+
+	decoded, err := p.Decode()
+	if err != nil || decoded.ProtocolID != content.ProtocolID || len(decoded.Entries) != 1 || decoded.Entries[0].Class != retention.ClassAbgabenverfahren {
+		t.Fatalf("Decode = %+v, %v", decoded, err)
+	}
+
+	tampered := *p
+	tampered.Document = bytes.Replace(doc, []byte(`"deleted_count": 1`), []byte(`"deleted_count": 2`), 1)
+	if bytes.Equal(tampered.Document, doc) {
+		t.Fatal("tampering did not change the document")
+	}
+	if v := signer.Verify(&tampered); v.Valid || v.HashValid || v.SignatureOK {
+		t.Errorf("tampered document verified: %+v", v)
+	}
+
+	other := retention.NewSigner([]byte("another-key"))
+	if v := other.Verify(p); v.Valid || v.KeyMatches || !v.HashValid {
+		t.Errorf("Verify with another key = %+v, want hash valid only", v)
+	}
+}
+
+func TestRetentionProtocolKey(t *testing.T) {
+	explicit := &config.RetentionConfig{ProtocolSecret: "secret"}
+	if got := explicit.ProtocolKey("12345678901234567890123456789012"); string(got) != "secret" {
+		t.Errorf("ProtocolKey = %q, want the protocol secret", got)
+	}
+
+	derived := &config.RetentionConfig{}
+	a, b := derived.ProtocolKey("key-a"), derived.ProtocolKey("key-a")
+	if len(a) != 32 || !bytes.Equal(a, b) || bytes.Equal(a, derived.ProtocolKey("key-b")) {
+		t.Errorf("derived keys are not stable per encryption key")
+	}
+	if derived.ProtocolKey("") != nil {
+		t.Error("ProtocolKey without any secret should be nil")
+	}
+}
@@ -0,0 +1,61 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/salesdoc"
+)
+
+func TestSalesDocumentConversionRules(t *testing.T) {
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{salesdoc.TypeOffer, salesdoc.TypeOrderConfirmation, true},
+		{salesdoc.TypeOffer, salesdoc.TypeDeliveryNote, true},
+		{salesdoc.TypeOrderConfirmation, salesdoc.TypeDeliveryNote, true},
+		{salesdoc.TypeOrderConfirmation, salesdoc.TypeOffer, false},
+		{salesdoc.TypeDeliveryNote, salesdoc.TypeOrderConfirmation, false},
+		{salesdoc.TypeOffer, salesdoc.TypeOffer, false},
+		{"invoice", salesdoc.TypeOffer, false},
+	}
+	for _, c := range cases {
+		if got := salesdoc.CanConvert(c.from, c.to); got != c.want {
+			t.Errorf("CanConvert(%s, %s) = %v, want %v", c.from, c.to, got, c.want)
+		}
+	}
+	if !salesdoc.IsValidType(salesdoc.TypeDeliveryNote) || salesdoc.IsValidType("invoice") {
+		t.Error("unexpected document types")
+	}
+}
+
+func TestSalesDocumentWritesRequireAdmin(t *testing.T) {
+	router := api.NewRouter(nil)
+	passthrough := func(next http.Handler) http.Handler { return next }
+	denied := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			api.Forbidden(w, "admin only")
+		})
+	}
+	salesdoc.NewHandler(salesdoc.NewService(nil, nil)).RegisterRoutes(router, passthrough, denied)
+
+	id := "3f1c6a2e-8d4b-4c39-9a77-0f5e2b6d1c11"
+	for _, route := range []string{
+		"POST /api/v1/sales-documents",
+		"DELETE /api/v1/sales-documents/" + id,
+		"POST /api/v1/sales-documents/" + id + "/status",
+		"POST /api/v1/sales-documents/" + id + "/convert",
+		"POST /api/v1/sales-documents/" + id + "/invoice",
+	} {
+		method, path, _ := strings.Cut(route, " ")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader("{}")))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403 for non-admins, got %d", route, rec.Code)
+		}
+	}
+}
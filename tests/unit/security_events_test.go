@@ -0,0 +1,158 @@
+package unit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/audit"
+	"austrian-business-infrastructure/pkg/cache"
+)
+
+func TestParseAlertThresholds(t *testing.T) {
+	thresholds, err := audit.ParseAlertThresholds("security.auth_failed=20/5m, security.cross_tenant_attempt=5/10m:critical")
+	if err != nil {
+		t.Fatalf("ParseAlertThresholds failed: %v", err)
+	}
+	if len(thresholds) != 2 {
+		t.Fatalf("expected 2 thresholds, got %d", len(thresholds))
+	}
+	if th := thresholds[0]; th.EventType != "security.auth_failed" || th.Count != 20 || th.Window != 5*time.Minute || th.Severity != audit.SeverityHigh {
+		t.Errorf("unexpected threshold: %+v", th)
+	}
+	if th := thresholds[1]; th.Count != 5 || th.Severity != audit.SeverityCritical {
+		t.Errorf("unexpected threshold: %+v", th)
+	}
+
+	for _, invalid := range []string{"security.auth_failed", "x=0/5m", "x=5/soon", "x=5/5m:urgent"} {
+		if _, err := audit.ParseAlertThresholds(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+
+	if !audit.SeverityHigh.AtLeast(audit.SeverityMedium) || audit.SeverityLow.AtLeast(audit.SeverityMedium) {
+		t.Error("unexpected severity ordering")
+	}
+}
+
+type webhookCollector struct {
+	mu     sync.Mutex
+	events []audit.SecurityEvent
+	ok     bool
+}
+
+func (c *webhookCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	signature := audit.SignSecurityWebhook("whsec", r.Header.Get(audit.SecurityWebhookTimestampHeader), body)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ok = r.Header.Get(audit.SecurityWebhookSignatureHeader) == signature &&
+		r.Header.Get("Content-Type") == "application/x-ndjson"
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		var e audit.SecurityEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err == nil {
+			c.events = append(c.events, e)
+		}
+	}
+}
+
+func TestSecurityStream_WebhookAndThreshold(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+
+	collector := &webhookCollector{}
+	siem := httptest.NewServer(collector)
+	defer siem.Close()
+
+	stream := audit.NewSecurityStream(nil, &cache.Client{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}, audit.SecurityStreamConfig{
+		WebhookURL:         siem.URL,
+		WebhookSecret:      "whsec",
+		WebhookMinSeverity: audit.SeverityHigh,
+		Thresholds: []audit.AlertThreshold{
+			{EventType: audit.EventCrossTenantAttempt, Count: 3, Window: 10 * time.Minute, Severity: audit.SeverityCritical},
+		},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	handler := api.SecuritySignals(stream)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/limited" {
+			api.ReportSecurity(r, api.SecuritySignal{Kind: api.SignalRateLimited})
+			return
+		}
+		api.ReportTenantMismatch(r, "client", "0b7c")
+	}))
+	for _, path := range []string{"/clients/0b7c", "/limited", "/clients/0b7c", "/clients/0b7c", "/clients/0b7c"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "198.51.100.7:1234"
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	stream.Close()
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	if !collector.ok {
+		t.Error("expected signed NDJSON delivery")
+	}
+
+	// Four cross-tenant attempts and one alert; the low-severity rate-limit
+	// event stays below the webhook's minimum severity
+	var attempts, alerts int
+	for _, e := range collector.events {
+		switch e.EventType {
+		case audit.EventCrossTenantAttempt:
+			attempts++
+			if e.IPAddress != "198.51.100.7" || e.Metadata["resource_id"] != "0b7c" {
+				t.Errorf("unexpected attempt event: %+v", e)
+			}
+		case audit.EventAlertThresholdExceeded:
+			alerts++
+			if e.Severity != audit.SeverityCritical || e.Metadata["trigger_event_type"] != audit.EventCrossTenantAttempt {
+				t.Errorf("unexpected alert event: %+v", e)
+			}
+		default:
+			t.Errorf("unexpected event pushed: %s", e.EventType)
+		}
+	}
+	if attempts != 4 || alerts != 1 {
+		t.Errorf("expected 4 attempts and 1 alert, got %d and %d", attempts, alerts)
+	}
+}
+
+func TestSecurityEventHandler_RequiresToken(t *testing.T) {
+	handler := audit.NewSecurityEventHandler(nil, "export-token", slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for _, auth := range []string{"", "Bearer wrong", "export-token"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/security/events", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		handler.Export(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: expected 401, got %d", auth, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/security/events?after=-1", nil)
+	req.Header.Set("Authorization", "Bearer export-token")
+	rec := httptest.NewRecorder()
+	handler.Export(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid cursor, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,138 @@
+package unit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/auth"
+	"github.com/google/uuid"
+)
+
+func TestApplySecurityPolicyInput(t *testing.T) {
+	p := auth.DefaultSecurityPolicy(uuid.New())
+	duration := 480
+	err := auth.ApplySecurityPolicyInput(p, &auth.SecurityPolicyInput{
+		MinPasswordLength:      16,
+		Require2FA:             true,
+		SessionDurationMinutes: &duration,
+		AllowedIPRanges:        []string{"10.1.2.3/8", " 203.0.113.7 ", "2001:db8::/32", "::ffff:192.0.2.0/120"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"10.0.0.0/8", "203.0.113.7/32", "2001:db8::/32", "192.0.2.0/24"}
+	if len(p.AllowedIPRanges) != len(want) {
+		t.Fatalf("ranges = %v, want %v", p.AllowedIPRanges, want)
+	}
+	for i := range want {
+		if p.AllowedIPRanges[i] != want[i] {
+			t.Errorf("range %d = %q, want %q", i, p.AllowedIPRanges[i], want[i])
+		}
+	}
+	if p.MinPasswordLength != 16 || !p.Require2FA || p.SessionDurationMinutes == nil || *p.SessionDurationMinutes != 480 {
+		t.Errorf("policy = %+v", p)
+	}
+
+	// Zero keeps the platform minimum
+	if err := auth.ApplySecurityPolicyInput(p, &auth.SecurityPolicyInput{}); err != nil || p.MinPasswordLength != 12 {
+		t.Errorf("empty input: length %d, error %v", p.MinPasswordLength, err)
+	}
+
+	short, long := 5, auth.MaxSessionDurationMinutes+1
+	for name, input := range map[string]*auth.SecurityPolicyInput{
+		"below platform minimum": {MinPasswordLength: 8},
+		"too long":               {MinPasswordLength: auth.MaxPasswordLength + 1},
+		"session too short":      {SessionDurationMinutes: &short},
+		"session too long":       {SessionDurationMinutes: &long},
+		"not an address":         {AllowedIPRanges: []string{"office"}},
+		"every address":          {AllowedIPRanges: []string{"0.0.0.0/0"}},
+	} {
+		if err := auth.ApplySecurityPolicyInput(p, input); !errors.Is(err, auth.ErrInvalidSecurityPolicy) {
+			t.Errorf("%s: got %v, want ErrInvalidSecurityPolicy", name, err)
+		}
+	}
+}
+
+func TestSecurityPolicyEnforcement(t *testing.T) {
+	p := auth.DefaultSecurityPolicy(uuid.New())
+	if !p.AllowsIP("198.51.100.1") || !p.AllowsIP("not an ip") {
+		t.Error("a policy without ranges should allow every client")
+	}
+	if p.SessionExpired(time.Now().Add(-30*24*time.Hour), time.Now()) {
+		t.Error("a policy without session duration should not expire sessions")
+	}
+
+	duration := 60
+	if err := auth.ApplySecurityPolicyInput(p, &auth.SecurityPolicyInput{
+		MinPasswordLength:      14,
+		SessionDurationMinutes: &duration,
+		AllowedIPRanges:        []string{"10.0.0.0/8", "2001:db8::/32"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for ip, want := range map[string]bool{
+		"10.20.30.40":      true,
+		"::ffff:10.0.0.1":  true,
+		"2001:db8::1":      true,
+		"192.168.1.1":      false,
+		"2001:db9::1":      false,
+		"":                 false,
+		"10.0.0.1:443":     false,
+		"unknown-resolver": false,
+	} {
+		if got := p.AllowsIP(ip); got != want {
+			t.Errorf("AllowsIP(%q) = %v, want %v", ip, got, want)
+		}
+	}
+
+	login := time.Now()
+	if p.SessionExpired(login, login.Add(59*time.Minute)) || !p.SessionExpired(login, login.Add(61*time.Minute)) {
+		t.Error("sessions should expire an hour after login")
+	}
+
+	policy := p.PasswordPolicy()
+	if err := auth.ValidatePassword("Secure-Pass1", policy); !errors.Is(err, auth.ErrPasswordTooShort) {
+		t.Fatalf("12 characters: got %v, want ErrPasswordTooShort", err)
+	}
+	var short *auth.PasswordTooShortError
+	if err := auth.ValidatePassword("Secure-Pass1", policy); !errors.As(err, &short) || short.MinLength != 14 ||
+		err.Error() != "password must be at least 14 characters" {
+		t.Errorf("too short error = %v", err)
+	}
+	if err := auth.ValidatePassword("Secure-Pass123", policy); err != nil {
+		t.Errorf("14 characters: %v", err)
+	}
+}
+
+func TestRequireAuthRestrictsEnroll2FATokens(t *testing.T) {
+	m := newBreakGlassJWTManager(t)
+	tokens, err := m.GenerateTokenPair(&auth.UserInfo{
+		UserID: uuid.NewString(), TenantID: uuid.NewString(), Role: "member", Enroll2FA: true,
+	})
+	if err != nil {
+		t.Fatalf("GenerateTokenPair: %v", err)
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mw := auth.NewAuthMiddleware(m)
+	for path, want := range map[string]int{
+		"/api/v1/auth/2fa/setup":  http.StatusNoContent,
+		"/api/v1/auth/2fa/status": http.StatusNoContent,
+		"/api/v1/auth/me":         http.StatusNoContent,
+		"/api/v1/accounts":        http.StatusForbidden,
+		"/api/v1/auth/2fax":       http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+		rec := httptest.NewRecorder()
+		mw.RequireAuth(next).ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("%s: status %d, want %d", path, rec.Code, want)
+		}
+	}
+}
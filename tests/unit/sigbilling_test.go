@@ -0,0 +1,131 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/mail"
+	"austrian-business-infrastructure/internal/sigbilling"
+)
+
+func TestSignatureCreditsAllocateEarliestExpiryFirst(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time { t := now.AddDate(0, 0, days); return &t }
+
+	unlimited := &sigbilling.Package{ID: uuid.New(), Remaining: 50, PurchasedAt: now.AddDate(0, -2, 0)}
+	later := &sigbilling.Package{ID: uuid.New(), Remaining: 5, ExpiresAt: at(60), PurchasedAt: now.AddDate(0, -1, 0)}
+	sooner := &sigbilling.Package{ID: uuid.New(), Remaining: 3, ExpiresAt: at(10), PurchasedAt: now}
+	expired := &sigbilling.Package{ID: uuid.New(), Remaining: 20, ExpiresAt: at(-1), PurchasedAt: now.AddDate(-1, 0, 0)}
+	packages := []*sigbilling.Package{unlimited, later, expired, sooner}
+
+	debits, uncovered := sigbilling.Allocate(packages, 10, now)
+	want := []sigbilling.Debit{{PackageID: sooner.ID, Signatures: 3}, {PackageID: later.ID, Signatures: 5}, {PackageID: unlimited.ID, Signatures: 2}}
+	if uncovered != 0 || len(debits) != len(want) {
+		t.Fatalf("got %+v, %d uncovered", debits, uncovered)
+	}
+	for i := range want {
+		if debits[i] != want[i] {
+			t.Errorf("debit %d = %+v, want %+v", i, debits[i], want[i])
+		}
+	}
+	if sooner.Remaining != 3 || packages[0] != unlimited {
+		t.Error("Allocate modified its input")
+	}
+
+	if _, uncovered := sigbilling.Allocate(packages, 60, now); uncovered != 2 {
+		t.Errorf("uncovered = %d, want 2", uncovered)
+	}
+}
+
+func TestSignatureCreditsAdmit(t *testing.T) {
+	prepaid := &sigbilling.Account{LowBalanceThreshold: 10}
+	packages := []*sigbilling.Package{{Remaining: 2}, {Remaining: 1}}
+
+	b := sigbilling.Evaluate(prepaid, packages)
+	if b.Remaining != 3 || !b.Low || b.Blocked {
+		t.Fatalf("unexpected balance %+v", b)
+	}
+	if err := sigbilling.Admit(b, 3); err != nil {
+		t.Errorf("3 signatures with 3 credits: %v", err)
+	}
+	if err := sigbilling.Admit(b, 4); !errors.Is(err, sigbilling.ErrCreditsExhausted) || !strings.Contains(err.Error(), "4 signatures needed, 3 left") {
+		t.Errorf("4 signatures with 3 credits: %v", err)
+	}
+
+	empty := sigbilling.Evaluate(prepaid, nil)
+	if !empty.Blocked || !errors.Is(sigbilling.Admit(empty, 1), sigbilling.ErrCreditsExhausted) {
+		t.Errorf("exhausted prepaid tenant not blocked: %+v", empty)
+	}
+
+	postpaid := sigbilling.Evaluate(&sigbilling.Account{Postpaid: true}, nil)
+	if postpaid.Blocked || postpaid.Low || sigbilling.Admit(postpaid, 100) != nil {
+		t.Errorf("postpaid tenant blocked: %+v", postpaid)
+	}
+}
+
+func TestSignatureStatementTotals(t *testing.T) {
+	tenantID := uuid.New()
+	st := sigbilling.BuildStatement(tenantID, "2026-03", []*sigbilling.StatementLine{
+		{Signatures: 3, CostCents: 90, Covered: 3},
+		{Signatures: 4, CostCents: 120, Covered: 1},
+		{Signatures: 2, CostCents: 0},
+	})
+	if st.Signatures != 9 || st.CostCents != 210 || st.CoveredSignatures != 4 || st.BillableSignatures != 5 || st.BillableCents != 90 {
+		t.Errorf("unexpected totals %+v", st)
+	}
+	if l := st.Lines[1]; l.Billable != 3 || l.BillableCents != 90 {
+		t.Errorf("unexpected line %+v", l)
+	}
+}
+
+func TestSignatureStatementPeriods(t *testing.T) {
+	start, end, err := sigbilling.ParsePeriod("2026-03")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// March starts at midnight Vienna time, before the switch to summer time
+	if got := start.UTC(); !got.Equal(time.Date(2026, 2, 28, 23, 0, 0, 0, time.UTC)) {
+		t.Errorf("start = %v", got)
+	}
+	if got := end.UTC(); !got.Equal(time.Date(2026, 3, 31, 22, 0, 0, 0, time.UTC)) {
+		t.Errorf("end = %v", got)
+	}
+	if _, _, err := sigbilling.ParsePeriod("2026-3"); !errors.Is(err, sigbilling.ErrInvalidPeriod) {
+		t.Errorf("got %v, want ErrInvalidPeriod", err)
+	}
+
+	// 23:30 UTC on 31 January is already February in Vienna
+	if got := sigbilling.PreviousPeriod(time.Date(2026, 1, 31, 23, 30, 0, 0, time.UTC)); got != "2026-01" {
+		t.Errorf("PreviousPeriod = %s, want 2026-01", got)
+	}
+}
+
+func TestSignatureLowBalanceMail(t *testing.T) {
+	renderer, err := mail.NewRenderer("Austrian Business Platform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := renderer.Render(mail.TemplateSignatureLowBalance, email.SignatureLowBalanceParams{
+		RecipientName: "Anna Huber",
+		TenantName:    "Huber & Partner",
+		Remaining:     8,
+		Threshold:     10,
+		BillingURL:    "https://app.example/signatures",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered.Subject != "Nur noch 8 Signaturen für Huber & Partner" {
+		t.Errorf("unexpected subject %q", rendered.Subject)
+	}
+	for _, want := range []string{"Guten Tag Anna Huber,", "Warnschwelle: 10", "keine neuen Signaturanfragen", "https://app.example/signatures"} {
+		if !strings.Contains(rendered.Text, want) {
+			t.Errorf("expected %q in text:\n%s", want, rendered.Text)
+		}
+	}
+}
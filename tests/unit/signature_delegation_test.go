@@ -0,0 +1,102 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/signature"
+)
+
+// delegateTestSigner replaces signer in req like the repository does
+func delegateTestSigner(req *signature.SignatureRequest, signer *signature.Signer, email string) *signature.Signer {
+	replacement := &signature.Signer{
+		ID:              uuid.New(),
+		Email:           email,
+		Name:            "Replacement " + email,
+		OrderIndex:      signer.OrderIndex,
+		Status:          signature.SignerStatusPending,
+		DelegatedFromID: &signer.ID,
+	}
+	signer.Status = signature.SignerStatusDelegated
+	signer.DelegatedToID = &replacement.ID
+
+	for i, s := range req.Signers {
+		if s == signer {
+			req.Signers = append(req.Signers[:i+1], append([]*signature.Signer{replacement}, req.Signers[i+1:]...)...)
+			break
+		}
+	}
+	return replacement
+}
+
+func TestCheckDelegation(t *testing.T) {
+	req := statusTestRequest(true, signature.SignerStatusSigned, signature.SignerStatusNotified, signature.SignerStatusPending)
+	now := time.Now()
+	input := signature.DelegationInput{Email: "vertretung@example.at", Name: "Vertretung"}
+
+	if err := signature.CheckDelegation(req, req.Signers[1], input, now); err != nil {
+		t.Errorf("expected the notified signer to be able to delegate, got %v", err)
+	}
+	if err := signature.CheckDelegation(req, req.Signers[2], input, now); err != nil {
+		t.Errorf("expected a waiting signer to be able to delegate, got %v", err)
+	}
+	if err := signature.CheckDelegation(req, req.Signers[0], input, now); !errors.Is(err, signature.ErrCannotDelegate) {
+		t.Errorf("expected a signer who signed not to delegate, got %v", err)
+	}
+	if err := signature.CheckDelegation(req, req.Signers[1], signature.DelegationInput{Email: "SIGNERC@example.at", Name: "C"}, now); !errors.Is(err, signature.ErrDelegateIsSigner) {
+		t.Errorf("expected delegating to another signer to fail, got %v", err)
+	}
+	if err := signature.CheckDelegation(req, req.Signers[1], signature.DelegationInput{Email: "no-address", Name: "X"}, now); !errors.Is(err, signature.ErrInvalidReplacement) {
+		t.Errorf("expected an invalid email to fail, got %v", err)
+	}
+	if err := signature.CheckDelegation(req, req.Signers[1], input, req.ExpiresAt.Add(time.Minute)); !errors.Is(err, signature.ErrCannotDelegate) {
+		t.Errorf("expected no delegation after expiry, got %v", err)
+	}
+
+	// The limit counts the handovers of a position
+	current := req.Signers[1]
+	for i := 0; i < signature.MaxDelegations; i++ {
+		if err := signature.CheckDelegation(req, current, input, now); err != nil {
+			t.Fatalf("delegation %d: %v", i+1, err)
+		}
+		current = delegateTestSigner(req, current, "vertretung"+string(rune('a'+i))+"@example.at")
+	}
+	if err := signature.CheckDelegation(req, current, input, now); !errors.Is(err, signature.ErrDelegationLimit) {
+		t.Errorf("expected the delegation limit, got %v", err)
+	}
+	if err := signature.CheckDelegation(req, req.Signers[1], input, now); !errors.Is(err, signature.ErrCannotDelegate) {
+		t.Errorf("expected a delegated signer not to delegate again, got %v", err)
+	}
+
+	chain := signature.DelegationChain(req.Signers, current)
+	if len(chain) != signature.MaxDelegations+1 || chain[0] != req.Signers[1] || chain[len(chain)-1] != current {
+		t.Errorf("expected the chain from the original signer, got %d signers", len(chain))
+	}
+}
+
+func TestStatusPageSkipsDelegatedSigners(t *testing.T) {
+	req := statusTestRequest(true, signature.SignerStatusNotified, signature.SignerStatusPending)
+	replacement := delegateTestSigner(req, req.Signers[0], "vertretung@example.at")
+	now := time.Now()
+
+	page := signature.BuildStatusPage(req, replacement, signature.StatusContact{}, now)
+	if page.TotalSigners != 2 || len(page.Steps) != 2 {
+		t.Errorf("expected 2 signing positions, got %d", page.TotalSigners)
+	}
+	if page.You.Position != 1 || !page.You.YourTurn {
+		t.Errorf("expected the replacement to take over the first position, got %+v", page.You)
+	}
+
+	second := signature.BuildStatusPage(req, req.Signers[2], signature.StatusContact{}, now)
+	if second.You.WaitingFor != 1 || second.You.Position != 2 {
+		t.Errorf("expected the second signer to wait only for the replacement, got %+v", second.You)
+	}
+
+	delegated := signature.BuildStatusPage(req, req.Signers[0], signature.StatusContact{}, now)
+	if delegated.You.YourTurn || delegated.You.CanRequestLink || delegated.You.Position != 0 {
+		t.Errorf("expected no turn for the delegated signer, got %+v", delegated.You)
+	}
+}
@@ -0,0 +1,89 @@
+package unit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/signature"
+)
+
+func statusTestRequest(sequential bool, statuses ...signature.SignerStatus) *signature.SignatureRequest {
+	name := "Jahresabschluss 2025"
+	req := &signature.SignatureRequest{
+		ID:           uuid.New(),
+		Name:         &name,
+		Status:       signature.RequestStatusInProgress,
+		IsSequential: sequential,
+		ExpiresAt:    time.Now().Add(7 * 24 * time.Hour),
+	}
+	for i, status := range statuses {
+		req.Signers = append(req.Signers, &signature.Signer{
+			ID:         uuid.New(),
+			Email:      "signer" + string(rune('a'+i)) + "@example.at",
+			Name:       "Signer " + string(rune('A'+i)),
+			OrderIndex: i,
+			Status:     status,
+		})
+	}
+	return req
+}
+
+func TestStatusPageHidesOtherSigners(t *testing.T) {
+	req := statusTestRequest(false, signature.SignerStatusSigned, signature.SignerStatusSigning, signature.SignerStatusNotified)
+	you := req.Signers[2]
+
+	page := signature.BuildStatusPage(req, you, signature.StatusContact{Name: "Kanzlei Huber"}, time.Now())
+
+	if page.TotalSigners != 3 || page.SignedCount != 1 {
+		t.Errorf("expected 1 of 3 signed, got %d of %d", page.SignedCount, page.TotalSigners)
+	}
+	if page.You.Position != 3 || !page.Steps[2].IsYou {
+		t.Errorf("expected the viewer at position 3, got %d", page.You.Position)
+	}
+	// Another signer's signing attempt shows only as pending
+	if page.Steps[1].Status != signature.SignerStatusPending {
+		t.Errorf("expected other signer to show as pending, got %s", page.Steps[1].Status)
+	}
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, other := range req.Signers[:2] {
+		if strings.Contains(string(data), other.Email) || strings.Contains(string(data), other.Name) {
+			t.Errorf("status page exposes other signer %s", other.Name)
+		}
+	}
+}
+
+func TestStatusPageSequentialTurn(t *testing.T) {
+	req := statusTestRequest(true, signature.SignerStatusNotified, signature.SignerStatusPending)
+	now := time.Now()
+
+	second := signature.BuildStatusPage(req, req.Signers[1], signature.StatusContact{}, now)
+	if second.You.YourTurn || second.You.CanRequestLink || second.You.WaitingFor != 1 {
+		t.Errorf("expected second signer to wait for one signer, got %+v", second.You)
+	}
+
+	first := signature.BuildStatusPage(req, req.Signers[0], signature.StatusContact{}, now)
+	if !first.You.YourTurn || !first.You.CanRequestLink {
+		t.Errorf("expected first signer's turn, got %+v", first.You)
+	}
+
+	req.Signers[0].LinkRequestCount = signature.MaxLinkRequests
+	first = signature.BuildStatusPage(req, req.Signers[0], signature.StatusContact{}, now)
+	if first.You.CanRequestLink {
+		t.Error("expected no further link requests after the limit")
+	}
+
+	// Nothing to sign once the request has expired
+	req.Signers[0].LinkRequestCount = 0
+	expired := signature.BuildStatusPage(req, req.Signers[0], signature.StatusContact{}, req.ExpiresAt.Add(time.Hour))
+	if expired.You.YourTurn {
+		t.Error("expected no turn after expiry")
+	}
+}
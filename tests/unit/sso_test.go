@@ -0,0 +1,241 @@
+package unit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"austrian-business-infrastructure/internal/auth"
+	"austrian-business-infrastructure/internal/idaustria"
+	"austrian-business-infrastructure/internal/user"
+)
+
+func TestApplySSOProviderInputPresets(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      auth.SSOProviderInput
+		issuer     string
+		emailClaim string
+	}{
+		{"google", auth.SSOProviderInput{Name: "Google", Kind: "google", Issuer: "https://evil.example", ClientID: "c"}, auth.GoogleIssuer, "email"},
+		{"id austria", auth.SSOProviderInput{Name: "ID Austria", Kind: "id_austria", ClientID: "c"}, auth.IDAustriaIssuer, "email"},
+		{"entra", auth.SSOProviderInput{Name: "M365", Kind: "entra", Issuer: "https://login.microsoftonline.com/abc/v2.0/", ClientID: "c"}, "https://login.microsoftonline.com/abc/v2.0", "preferred_username"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &auth.SSOProvider{Enabled: true}
+			if err := auth.ApplySSOProviderInput(p, &tt.input); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Issuer != tt.issuer {
+				t.Errorf("issuer = %q, want %q", p.Issuer, tt.issuer)
+			}
+			if p.EmailClaim != tt.emailClaim {
+				t.Errorf("email claim = %q, want %q", p.EmailClaim, tt.emailClaim)
+			}
+			if len(p.Scopes) != 3 || p.Scopes[0] != "openid" {
+				t.Errorf("scopes = %v, want openid email profile", p.Scopes)
+			}
+			if p.DefaultRole != "member" {
+				t.Errorf("default role = %q, want member", p.DefaultRole)
+			}
+		})
+	}
+}
+
+func TestApplySSOProviderInputRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input auth.SSOProviderInput
+	}{
+		{"unknown kind", auth.SSOProviderInput{Name: "X", Kind: "saml", Issuer: "https://idp.example", ClientID: "c"}},
+		{"plain http", auth.SSOProviderInput{Name: "X", Kind: "oidc", Issuer: "http://idp.example", ClientID: "c"}},
+		{"entra common", auth.SSOProviderInput{Name: "X", Kind: "entra", Issuer: "https://login.microsoftonline.com/common/v2.0", ClientID: "c"}},
+		{"missing client", auth.SSOProviderInput{Name: "X", Kind: "oidc", Issuer: "https://idp.example"}},
+		{"owner mapping", auth.SSOProviderInput{Name: "X", Kind: "oidc", Issuer: "https://idp.example", ClientID: "c", RoleMapping: map[string]string{"boss": "owner"}}},
+		{"owner default", auth.SSOProviderInput{Name: "X", Kind: "oidc", Issuer: "https://idp.example", ClientID: "c", DefaultRole: "owner"}},
+		{"bad domain", auth.SSOProviderInput{Name: "X", Kind: "oidc", Issuer: "https://idp.example", ClientID: "c", AllowedDomains: []string{"user@kanzlei.at"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := auth.ApplySSOProviderInput(&auth.SSOProvider{}, &tt.input)
+			if !errors.Is(err, auth.ErrInvalidSSOProvider) {
+				t.Fatalf("expected ErrInvalidSSOProvider, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMapSSOIdentity(t *testing.T) {
+	roleClaim := "groups"
+	p := &auth.SSOProvider{
+		EmailClaim:  "email",
+		NameClaim:   "name",
+		RoleClaim:   &roleClaim,
+		RoleMapping: map[string]string{"staff": "member", "partners": "admin"},
+		DefaultRole: "viewer",
+	}
+
+	id, err := auth.MapSSOIdentity(p, auth.IDClaims{
+		"sub": "s1", "email": "Anna@Kanzlei.at", "email_verified": true,
+		"given_name": "Anna", "family_name": "Berger", "groups": []any{"staff", "partners"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id.Email != "anna@kanzlei.at" || id.Name != "Anna Berger" || id.Role != user.RoleAdmin {
+		t.Errorf("unexpected identity %+v", id)
+	}
+
+	id, err = auth.MapSSOIdentity(p, auth.IDClaims{"sub": "s2", "email": "bob@kanzlei.at", "email_verified": "true"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id.Role != user.RoleViewer || id.Name != "bob" {
+		t.Errorf("unexpected identity %+v", id)
+	}
+
+	if _, err := auth.MapSSOIdentity(p, auth.IDClaims{"sub": "s3", "email": "eve@kanzlei.at"}); !errors.Is(err, auth.ErrSSOEmailUnverified) {
+		t.Errorf("expected ErrSSOEmailUnverified, got %v", err)
+	}
+	if _, err := auth.MapSSOIdentity(p, auth.IDClaims{"sub": "s4"}); !errors.Is(err, auth.ErrSSONoEmail) {
+		t.Errorf("expected ErrSSONoEmail, got %v", err)
+	}
+
+	// With allowed domains, addresses of other domains are rejected even if
+	// verified, and those of the domains still need to be verified
+	p.AllowedDomains = []string{"kanzlei.at"}
+	if _, err := auth.MapSSOIdentity(p, auth.IDClaims{"sub": "s5", "email": "eve@example.com", "email_verified": true}); !errors.Is(err, auth.ErrSSODomainNotAllowed) {
+		t.Errorf("expected ErrSSODomainNotAllowed, got %v", err)
+	}
+	if _, err := auth.MapSSOIdentity(p, auth.IDClaims{"sub": "s6", "email": "carl@kanzlei.at"}); !errors.Is(err, auth.ErrSSOEmailUnverified) {
+		t.Errorf("expected ErrSSOEmailUnverified, got %v", err)
+	}
+}
+
+func TestMapSSOIdentityTrustedEmailClaim(t *testing.T) {
+	p := &auth.SSOProvider{Enabled: true}
+	err := auth.ApplySSOProviderInput(p, &auth.SSOProviderInput{
+		Name: "M365", Kind: "entra", Issuer: "https://login.microsoftonline.com/abc/v2.0", ClientID: "c",
+		AllowedDomains: []string{"kanzlei.at"}, TrustEmailClaim: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The directory-managed sign-in name is taken as verified
+	id, err := auth.MapSSOIdentity(p, auth.IDClaims{"sub": "s1", "preferred_username": "Anna@Kanzlei.at"})
+	if err != nil || id.Email != "anna@kanzlei.at" {
+		t.Fatalf("identity %+v, error %v", id, err)
+	}
+
+	// The user-editable email claim is not, even when it is the fallback
+	if _, err := auth.MapSSOIdentity(p, auth.IDClaims{"sub": "s2", "email": "owner@kanzlei.at"}); !errors.Is(err, auth.ErrSSOEmailUnverified) {
+		t.Errorf("expected ErrSSOEmailUnverified for the email fallback, got %v", err)
+	}
+
+	// Only sign-in name claims can be trusted
+	err = auth.ApplySSOProviderInput(&auth.SSOProvider{}, &auth.SSOProviderInput{
+		Name: "X", Kind: "oidc", Issuer: "https://idp.example", ClientID: "c", TrustEmailClaim: true,
+	})
+	if !errors.Is(err, auth.ErrInvalidSSOProvider) {
+		t.Errorf("trusting the email claim: expected ErrInvalidSSOProvider, got %v", err)
+	}
+}
+
+func newSSOTestClient(t *testing.T) *auth.OIDCClient {
+	t.Helper()
+
+	var provider *idaustria.MockProvider
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provider.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	issuer := srv.URL + "/idp"
+	provider, err := idaustria.NewMockProvider(issuer, "sso-client", "sso-secret", mockRedirectURL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return auth.NewOIDCClient(auth.OIDCClientConfig{
+		Issuer:       issuer,
+		ClientID:     "sso-client",
+		ClientSecret: "sso-secret",
+		RedirectURL:  mockRedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	})
+}
+
+func TestOIDCClientAuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	client := newSSOTestClient(t)
+
+	authz, err := client.Authorize(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	callback := mockLogin(t, authz.URL, "max")
+	if callback.Get("state") != authz.State {
+		t.Fatalf("state = %q, want %q", callback.Get("state"), authz.State)
+	}
+
+	claims, err := client.Exchange(ctx, callback.Get("code"), authz.Verifier, authz.Nonce)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claims.String("email") != "max.mustermann@example.at" || !claims.Bool("email_verified") {
+		t.Errorf("unexpected claims %v", claims)
+	}
+	if claims.String("sub") == "" {
+		t.Error("expected a subject")
+	}
+}
+
+func TestOIDCClientRejectsNonceMismatch(t *testing.T) {
+	ctx := context.Background()
+	client := newSSOTestClient(t)
+
+	authz, err := client.Authorize(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	callback := mockLogin(t, authz.URL, "erika")
+
+	_, err = client.Exchange(ctx, callback.Get("code"), authz.Verifier, "another-nonce")
+	if !errors.Is(err, auth.ErrInvalidIDToken) {
+		t.Fatalf("expected ErrInvalidIDToken, got %v", err)
+	}
+}
+
+func TestOIDCClientRejectsForeignSignature(t *testing.T) {
+	ctx := context.Background()
+	client := newSSOTestClient(t)
+	if _, err := client.Authorize(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": "attacker", "aud": "sso-client", "nonce": "n",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = "forged"
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := client.VerifyIDToken(ctx, raw, "n"); !errors.Is(err, auth.ErrInvalidIDToken) {
+		t.Fatalf("expected ErrInvalidIDToken, got %v", err)
+	}
+}
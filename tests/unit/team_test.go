@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/team"
+)
+
+func TestResolveDeputy(t *testing.T) {
+	now := time.Date(2026, 7, 10, 9, 0, 0, 0, time.UTC)
+	anna, ben, clara, dora := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	rule := func(user, deputy uuid.UUID, scope team.Scope, fromDays, untilDays int) *team.Deputy {
+		return &team.Deputy{
+			UserID:     user,
+			DeputyID:   deputy,
+			Scope:      scope,
+			ValidFrom:  now.AddDate(0, 0, fromDays),
+			ValidUntil: now.AddDate(0, 0, untilDays),
+		}
+	}
+
+	rules := []*team.Deputy{
+		rule(anna, ben, team.ScopeAll, -2, 5),
+		rule(ben, clara, team.ScopeApprovals, -1, 3),
+		rule(clara, dora, team.ScopeAll, 1, 4),
+	}
+
+	// Ben is away for approvals only
+	if got, ok := team.ResolveDeputy(rules, anna, team.ScopeNotifications, now); !ok || got != ben {
+		t.Errorf("notifications of Anna go to %v, want Ben", got)
+	}
+	if got, ok := team.ResolveDeputy(rules, anna, team.ScopeApprovals, now); !ok || got != clara {
+		t.Errorf("approvals of Anna go to %v, want Clara", got)
+	}
+	// Clara's absence starts tomorrow
+	if got, _ := team.ResolveDeputy(rules, anna, team.ScopeApprovals, now.AddDate(0, 0, 2)); got != dora {
+		t.Errorf("approvals of Anna go to %v on day 2, want Dora", got)
+	}
+	// Anna's rule has ended
+	if _, ok := team.ResolveDeputy(rules, anna, team.ScopeAll, now.AddDate(0, 0, 5)); ok {
+		t.Error("deputy after the rule ended")
+	}
+	if _, ok := team.ResolveDeputy(rules, dora, team.ScopeAll, now); ok {
+		t.Error("deputy for a user who is not away")
+	}
+
+	// Everyone in the chain is away
+	cycle := append(rules, rule(clara, anna, team.ScopeApprovals, -1, 1))
+	if got, ok := team.ResolveDeputy(cycle, anna, team.ScopeApprovals, now); ok {
+		t.Errorf("cycle resolved to %v", got)
+	}
+}
+
+func TestParseUsersImport(t *testing.T) {
+	csv := "\ufeffEmail,Name,Role,Teams,Department,Lead\n" +
+		"Anna.Huber@Kanzlei.at,Anna Huber,admin,Lohn; Buchhaltung;lohn,Steuerberatung,ja\n" +
+		"max@kanzlei.at,Max,,Buchhaltung,,\n" +
+		"chef@kanzlei.at,Chef,owner,,,\n" +
+		"keine-mail,Niemand,member,,,\n" +
+		"anna.huber@kanzlei.at,Anna,viewer,,,x\n" +
+		",,,,,\n"
+
+	result, err := team.ParseUsers(strings.NewReader(csv), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalRows != 5 || result.ValidCount != 2 || result.ErrorCount != 3 {
+		t.Fatalf("unexpected counts %+v", result)
+	}
+
+	anna := result.Rows[0]
+	if anna.Email != "anna.huber@kanzlei.at" || anna.Role != "admin" || !anna.Lead || anna.Department != "Steuerberatung" {
+		t.Errorf("unexpected row %+v", anna)
+	}
+	if strings.Join(anna.Teams, "|") != "Lohn|Buchhaltung" {
+		t.Errorf("teams = %q", anna.Teams)
+	}
+	if max := result.Rows[1]; max.Role != "member" || !max.Valid {
+		t.Errorf("unexpected row %+v", max)
+	}
+
+	wantErrors := map[int]string{4: "owner", 5: "invalid email", 6: "email already in row 2"}
+	for _, row := range result.Rows[2:] {
+		if row.Valid || len(row.Errors) == 0 || !strings.Contains(strings.Join(row.Errors, "; "), wantErrors[row.RowNumber]) {
+			t.Errorf("row %d: errors %q, want %q", row.RowNumber, row.Errors, wantErrors[row.RowNumber])
+		}
+	}
+	if errs := result.Rows[4].Errors; len(errs) != 2 || errs[0] != "lead requires a team or department" {
+		t.Errorf("unexpected errors %q", errs)
+	}
+}
+
+func TestParseUsersImportRejectsFiles(t *testing.T) {
+	cases := map[string]error{
+		"":                                   team.ErrEmptyFile,
+		"email,name\n":                       team.ErrEmptyFile,
+		"name,role\nAnna,member\n":           team.ErrMissingHeaders,
+		"email;name\nanna@kanzlei.at;Anna\n": team.ErrMissingHeaders,
+		"email\na@x.at\nb@x.at\nc@x.at\n":    team.ErrTooManyRows,
+	}
+	for csv, want := range cases {
+		if _, err := team.ParseUsers(strings.NewReader(csv), 2); !errors.Is(err, want) {
+			t.Errorf("%q: got %v, want %v", csv, err, want)
+		}
+	}
+}
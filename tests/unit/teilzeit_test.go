@@ -0,0 +1,155 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/teilzeit"
+)
+
+func TestAltersteilzeitCalculation(t *testing.T) {
+	e := &teilzeit.Eingabe{
+		Modell:        teilzeit.ModellAltersteilzeit,
+		Variante:      teilzeit.VarianteKontinuierlich,
+		Von:           time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		Bis:           time.Date(2028, 6, 30, 0, 0, 0, 0, time.UTC),
+		BisherStunden: 40,
+		NeueStunden:   20,
+		BisherBrutto:  400000,
+	}
+
+	r, errs := teilzeit.Calculate(e)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected validation errors: %v", errs)
+	}
+
+	if r.Reduktion != 50 || r.Monate != 36 {
+		t.Errorf("unexpected Reduktion %.2f / Monate %d", r.Reduktion, r.Monate)
+	}
+	if r.Lohnausgleich != 100000 {
+		t.Errorf("expected Lohnausgleich 100000, got %d", r.Lohnausgleich)
+	}
+	if r.Teilzeit.Brutto != 300000 || r.Teilzeit.Beitragsgrundlage != 400000 {
+		t.Errorf("unexpected Teilzeit values: %+v", r.Teilzeit)
+	}
+	// SV (18.07% + 20.98%) on the 1.000,00 EUR difference to the previous basis
+	if r.Mehrbeitraege != 39050 {
+		t.Errorf("expected Mehrbeiträge 39050, got %d", r.Mehrbeitraege)
+	}
+	if r.Altersteilzeitgeld != 125145 { // 90% of 1.390,50 EUR
+		t.Errorf("expected Altersteilzeitgeld 125145, got %d", r.Altersteilzeitgeld)
+	}
+	if r.NettoDifferenz >= 0 || r.NettoDienstnehmer <= r.Bisher.Netto/2 {
+		t.Errorf("unexpected net effect: %d (bisher %d)", r.NettoDienstnehmer, r.Bisher.Netto)
+	}
+}
+
+func TestWiedereingliederungCalculation(t *testing.T) {
+	e := &teilzeit.Eingabe{
+		Modell:        teilzeit.ModellWiedereingliederung,
+		Von:           time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		Bis:           time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC),
+		BisherStunden: 40,
+		NeueStunden:   20,
+		BisherBrutto:  300000,
+	}
+
+	r, errs := teilzeit.Calculate(e)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected validation errors: %v", errs)
+	}
+
+	if r.Teilzeit.Brutto != 150000 || r.Teilzeit.Beitragsgrundlage != 150000 {
+		t.Errorf("unexpected Teilzeit values: %+v", r.Teilzeit)
+	}
+	// 60% of 3.000,00 EUR incl. Sonderzahlungen (14/12), half of it for 50% reduction
+	if r.Wiedereingliederungsgeld != 105000 {
+		t.Errorf("expected Wiedereingliederungsgeld 105000, got %d", r.Wiedereingliederungsgeld)
+	}
+	if r.NettoDienstnehmer != r.Teilzeit.Netto+r.Wiedereingliederungsgeld {
+		t.Errorf("net pay should include Wiedereingliederungsgeld")
+	}
+	if r.KostenDifferenz >= 0 {
+		t.Errorf("expected lower employer costs, got %d", r.KostenDifferenz)
+	}
+}
+
+func TestTeilzeitValidation(t *testing.T) {
+	von := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		e     teilzeit.Eingabe
+		match string
+	}{
+		{"ATZ reduction too small", teilzeit.Eingabe{Modell: teilzeit.ModellAltersteilzeit, Variante: teilzeit.VarianteKontinuierlich,
+			Von: von, Bis: von.AddDate(2, 0, -1), BisherStunden: 40, NeueStunden: 30, BisherBrutto: 400000}, "Reduktion"},
+		{"ATZ too long", teilzeit.Eingabe{Modell: teilzeit.ModellAltersteilzeit, Variante: teilzeit.VarianteBlock,
+			Von: von, Bis: von.AddDate(6, 0, 0), BisherStunden: 40, NeueStunden: 20, BisherBrutto: 400000}, "5 Jahre"},
+		{"WIETZ too long", teilzeit.Eingabe{Modell: teilzeit.ModellWiedereingliederung,
+			Von: von, Bis: von.AddDate(0, 7, -1), BisherStunden: 40, NeueStunden: 20, BisherBrutto: 300000}, "Monate"},
+		{"WIETZ too few hours", teilzeit.Eingabe{Modell: teilzeit.ModellWiedereingliederung,
+			Von: von, Bis: von.AddDate(0, 3, -1), BisherStunden: 20, NeueStunden: 10, BisherBrutto: 300000}, "Wochenstunden"},
+		{"WIETZ geringfügig", teilzeit.Eingabe{Modell: teilzeit.ModellWiedereingliederung,
+			Von: von, Bis: von.AddDate(0, 3, -1), BisherStunden: 40, NeueStunden: 20, BisherBrutto: 90000}, "Geringfügigkeitsgrenze"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := teilzeit.Validate(&tt.e)
+			if len(errs) != 1 || !strings.Contains(errs[0], tt.match) {
+				t.Errorf("expected single error containing %q, got %v", tt.match, errs)
+			}
+		})
+	}
+
+	if got := teilzeit.AMSErsatzsatz(teilzeit.VarianteBlock, 2025, true); got != 35 {
+		t.Errorf("expected 35%% for block variant 2025, got %.1f", got)
+	}
+	if got := teilzeit.AMSErsatzsatz(teilzeit.VarianteBlock, 2025, false); got != 0 {
+		t.Errorf("expected no refund without Ersatzkraft, got %.1f", got)
+	}
+}
+
+func TestTeilzeitBuildAenderungen(t *testing.T) {
+	stammdaten := &elda.ELDAMeldung{ID: uuid.New(), Geschlecht: "W"}
+	sz := &teilzeit.Szenario{
+		ELDAAccountID: uuid.New(),
+		SVNummer:      "1234150189",
+		Vorname:       "Anna",
+		Nachname:      "Huber",
+		Eingabe: teilzeit.Eingabe{
+			Modell:        teilzeit.ModellWiedereingliederung,
+			Von:           time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			Bis:           time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC),
+			BisherStunden: 40,
+			NeueStunden:   20,
+			BisherBrutto:  300000,
+		},
+		Ergebnis: teilzeit.Ergebnis{Teilzeit: teilzeit.Monatswerte{Brutto: 150000}},
+	}
+
+	meldungen := teilzeit.BuildAenderungen(sz, stammdaten, nil)
+	if len(meldungen) != 2 {
+		t.Fatalf("expected start and return Änderungsmeldung, got %d", len(meldungen))
+	}
+
+	start, ende := meldungen[0], meldungen[1]
+	if start.Type != elda.MeldungTypeAenderung || start.Status != elda.MeldungStatusDraft {
+		t.Errorf("unexpected draft: %s/%s", start.Type, start.Status)
+	}
+	if start.Arbeitszeit.WochenStunden != 20 || start.Entgelt.BruttoMonatlich != 150000 {
+		t.Errorf("unexpected start values: %+v %+v", start.Arbeitszeit, start.Entgelt)
+	}
+	if !ende.AenderungDatum.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)) ||
+		ende.Arbeitszeit.WochenStunden != 40 || ende.Entgelt.BruttoMonatlich != 300000 {
+		t.Errorf("unexpected return Änderungsmeldung: %v %+v %+v", ende.AenderungDatum, ende.Arbeitszeit, ende.Entgelt)
+	}
+	if start.OriginalMeldungID == nil || *start.OriginalMeldungID != stammdaten.ID {
+		t.Errorf("expected link to the last accepted Meldung")
+	}
+}
@@ -0,0 +1,57 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/tenantstats"
+)
+
+type superAdminSet map[uuid.UUID]bool
+
+func (s superAdminSet) IsSuperAdmin(userID uuid.UUID) bool {
+	return s[userID]
+}
+
+func TestTenantStatsAccess(t *testing.T) {
+	ownTenant, otherTenant := uuid.New(), uuid.New()
+	superAdmin := uuid.New()
+
+	h := tenantstats.NewHandler(nil)
+	// Without an audit logger super-admin reads cannot be recorded
+	h.SetSuperAdmins(superAdminSet{superAdmin: true}, nil)
+
+	tests := []struct {
+		name     string
+		target   string
+		userID   uuid.UUID
+		role     string
+		wantCode int
+	}{
+		{"invalid id", "not-a-uuid", uuid.New(), "admin", http.StatusBadRequest},
+		{"member of tenant", ownTenant.String(), uuid.New(), "member", http.StatusForbidden},
+		{"admin of other tenant", otherTenant.String(), uuid.New(), "admin", http.StatusForbidden},
+		{"super-admin without audit", otherTenant.String(), superAdmin, "member", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := api.NewRouter(nil)
+			h.RegisterRoutes(router, func(next http.Handler) http.Handler { return next }, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/tenants/"+tt.target+"/stats", nil)
+			ctx := context.WithValue(req.Context(), api.TenantIDKey, ownTenant.String())
+			ctx = context.WithValue(ctx, api.UserIDKey, tt.userID.String())
+			ctx = context.WithValue(ctx, api.UserRoleKey, tt.role)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req.WithContext(ctx))
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"austrian-business-infrastructure/internal/ocr"
+)
+
+// buildZip returns a zip archive with the given files
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const docxBody = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Finanzamt Österreich</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Bescheid über die </w:t></w:r><w:r><w:t>Einkommensteuer 2025</w:t></w:r></w:p>
+<w:p></w:p>
+<w:p><w:r><w:t>Zahlbar bis</w:t><w:tab/><w:t>15.04.2026</w:t></w:r></w:p>
+<w:tbl><w:tr>
+<w:tc><w:p><w:r><w:t>Nachzahlung</w:t></w:r></w:p></w:tc>
+<w:tc><w:p><w:r><w:t>1.234,56</w:t></w:r></w:p></w:tc>
+</w:tr></w:tbl>
+</w:body></w:document>`
+
+func newDOCX(t *testing.T) []byte {
+	return buildZip(t, map[string]string{
+		"[Content_Types].xml": `<Types/>`,
+		"word/document.xml":   docxBody,
+	})
+}
+
+func newXLSX(t *testing.T) []byte {
+	return buildZip(t, map[string]string{
+		"[Content_Types].xml": `<Types/>`,
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Übersicht" sheetId="1" r:id="rId2"/><sheet name="Details" sheetId="2" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>Umsatz</t></si><si><r><t>Vor</t></r><r><t>steuer</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="inlineStr"><is><t>Detailzeile</t></is></c><c r="B1" t="b"><v>1</v></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1"><v>12000.5</v></c></row>
+<row r="2"><c r="A2" t="s"><v>1</v></c><c r="B2"><f>B1*0.2</f><v>2400.1</v></c></row>
+<row r="3"><c r="A3"/></row>
+</sheetData></worksheet>`,
+	})
+}
+
+func TestDetectMIME(t *testing.T) {
+	var pngBuf bytes.Buffer
+	png.Encode(&pngBuf, image.NewGray(image.Rect(0, 0, 2, 2)))
+	heic := append([]byte{0, 0, 0, 24}, []byte("ftypheic\x00\x00\x00\x00mif1heic")...)
+
+	cases := []struct {
+		name     string
+		data     []byte
+		declared string
+		want     string
+	}{
+		{"pdf", []byte("%PDF-1.7\n..."), "application/octet-stream", ocr.MIMEPDF},
+		{"docx", newDOCX(t), "application/octet-stream", ocr.MIMEDOCX},
+		{"xlsx", newXLSX(t), "application/zip", ocr.MIMEXLSX},
+		{"png declared as pdf", pngBuf.Bytes(), "application/pdf", ocr.MIMEPNG},
+		{"jpeg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF"), "", ocr.MIMEJPEG},
+		{"heic", heic, "application/octet-stream", ocr.MIMEHEIC},
+		{"plain zip", buildZip(t, map[string]string{"a.txt": "x"}), "", "application/zip"},
+		{"unknown binary keeps declared type", []byte{0x00, 0x01, 0x02}, "application/msword; charset=binary", "application/msword"},
+	}
+	for _, c := range cases {
+		if got := ocr.DetectMIME(c.data, c.declared); got != c.want {
+			t.Errorf("%s: DetectMIME = %q, want %q", c.name, got, c.want)
+		}
+	}
+
+	for _, mime := range []string{ocr.MIMEPDF, ocr.MIMEDOCX, ocr.MIMEXLSX, ocr.MIMEJPEG, ocr.MIMEPNG, ocr.MIMEHEIC} {
+		if !ocr.IsExtractable(mime) {
+			t.Errorf("IsExtractable(%q) = false", mime)
+		}
+	}
+	if ocr.IsExtractable("application/zip") || ocr.IsExtractable("image/gif") {
+		t.Error("zip and gif are not extractable")
+	}
+}
+
+func TestExtractDOCXText(t *testing.T) {
+	text, err := ocr.ExtractDOCXText(newDOCX(t))
+	if err != nil {
+		t.Fatalf("ExtractDOCXText failed: %v", err)
+	}
+	want := "Finanzamt Österreich\nBescheid über die Einkommensteuer 2025\nZahlbar bis\t15.04.2026\nNachzahlung\n1.234,56"
+	if text != want {
+		t.Errorf("text =\n%q\nwant\n%q", text, want)
+	}
+
+	if _, err := ocr.ExtractDOCXText(buildZip(t, map[string]string{"xl/workbook.xml": "<workbook/>"})); err == nil {
+		t.Error("expected error for archive without document.xml")
+	}
+}
+
+func TestExtractXLSXText(t *testing.T) {
+	text, err := ocr.ExtractXLSXText(newXLSX(t))
+	if err != nil {
+		t.Fatalf("ExtractXLSXText failed: %v", err)
+	}
+	// Workbook order, not file order; formulas give their cached value
+	want := "# Übersicht\nUmsatz\t12000.5\nVorsteuer\t2400.1\n# Details\nDetailzeile\tTRUE"
+	if text != want {
+		t.Errorf("text =\n%q\nwant\n%q", text, want)
+	}
+}
+
+func TestExtractOfficeLimits(t *testing.T) {
+	// Highly compressible content that expands beyond the per-part limit
+	huge := `<w:document xmlns:w="w"><w:body><w:p><w:r><w:t>` +
+		strings.Repeat("a", ocr.MaxArchiveEntrySize) + `</w:t></w:r></w:p></w:body></w:document>`
+	bomb := buildZip(t, map[string]string{"word/document.xml": huge})
+	if len(bomb) > 1<<20 {
+		t.Fatalf("test archive unexpectedly large: %d bytes", len(bomb))
+	}
+	if _, err := ocr.ExtractDOCXText(bomb); !errors.Is(err, ocr.ErrArchiveTooLarge) {
+		t.Errorf("expected ErrArchiveTooLarge, got %v", err)
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	in := "  Zeile 1\r\n\r\n\r\nZeile\u00a02 mit Trenn\u00adstrich\x07\n\n\n\nEnde \xff"
+	want := "Zeile 1\nZeile 2 mit Trennstrich\nEnde"
+	if got := ocr.NormalizeText(in); got != want {
+		t.Errorf("NormalizeText = %q, want %q", got, want)
+	}
+
+	long := strings.Repeat("ä", ocr.MaxExtractedText)
+	got := ocr.NormalizeText(long)
+	if len(got) > ocr.MaxExtractedText || !strings.HasSuffix(got, "ä") {
+		t.Errorf("truncated text has %d bytes or a broken last rune", len(got))
+	}
+}
@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/tenant"
+	"austrian-business-infrastructure/internal/timezone"
+)
+
+func TestTimezoneToday(t *testing.T) {
+	// 23:30 UTC on 15 October is already the 16th in Vienna (CEST)
+	now := time.Date(2026, 10, 15, 23, 30, 0, 0, time.UTC)
+	if got := timezone.Today(now, timezone.Vienna); !got.Equal(time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Today in Vienna = %v", got)
+	}
+	if got := timezone.Today(now, time.UTC); got.Day() != 15 {
+		t.Errorf("Today in UTC = %v", got)
+	}
+	newYork, _ := timezone.Load("America/New_York")
+	if got := timezone.Today(now, newYork); got.Day() != 15 || got.Location() != time.UTC {
+		t.Errorf("Today in New York = %v", got)
+	}
+}
+
+func TestTimezoneDayStart(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		day       time.Time
+		startUTC  time.Time
+		hoursLong float64
+	}{
+		{day(1, 15), time.Date(2026, 1, 14, 23, 0, 0, 0, time.UTC), 24},
+		{day(7, 15), time.Date(2026, 7, 14, 22, 0, 0, 0, time.UTC), 24},
+		// Clocks go forward on 29 March and back on 25 October 2026
+		{day(3, 29), time.Date(2026, 3, 28, 23, 0, 0, 0, time.UTC), 23},
+		{day(10, 25), time.Date(2026, 10, 24, 22, 0, 0, 0, time.UTC), 25},
+	}
+	for _, tt := range tests {
+		start := timezone.DayStart(tt.day, timezone.Vienna)
+		if !start.Equal(tt.startUTC) {
+			t.Errorf("DayStart(%s) = %v, want %v", tt.day.Format("2006-01-02"), start.UTC(), tt.startUTC)
+		}
+		end := timezone.DayStart(tt.day.AddDate(0, 0, 1), timezone.Vienna)
+		if got := end.Sub(start).Hours(); got != tt.hoursLong {
+			t.Errorf("%s is %v hours long, want %v", tt.day.Format("2006-01-02"), got, tt.hoursLong)
+		}
+	}
+}
+
+func TestTimezoneLoad(t *testing.T) {
+	if loc, err := timezone.Load(""); err != nil || loc != timezone.Vienna {
+		t.Errorf(`Load("") = %v, %v`, loc, err)
+	}
+	if loc, err := timezone.Load("Europe/Berlin"); err != nil || loc.String() != "Europe/Berlin" {
+		t.Errorf("Load(Europe/Berlin) = %v, %v", loc, err)
+	}
+	for _, name := range []string{"Local", "Europe/Wien", "CEST", "../etc/passwd"} {
+		if _, err := timezone.Load(name); !errors.Is(err, timezone.ErrInvalid) {
+			t.Errorf("Load(%q) = %v, want ErrInvalid", name, err)
+		}
+	}
+}
+
+func TestTenantLocation(t *testing.T) {
+	tests := []struct {
+		settings map[string]interface{}
+		want     string
+	}{
+		{nil, "Europe/Vienna"},
+		{map[string]interface{}{tenant.SettingTimezone: "Europe/Berlin"}, "Europe/Berlin"},
+		{map[string]interface{}{tenant.SettingTimezone: "Mars/Olympus"}, "Europe/Vienna"},
+		{map[string]interface{}{tenant.SettingTimezone: 1}, "Europe/Vienna"},
+	}
+	for _, tt := range tests {
+		if got := (&tenant.Tenant{Settings: tt.settings}).Location().String(); got != tt.want {
+			t.Errorf("Location(%v) = %s, want %s", tt.settings, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/mail"
+	"austrian-business-infrastructure/internal/uid"
+)
+
+func TestUIDParseBatchCSV(t *testing.T) {
+	uids, err := uid.ParseBatchCSV([]byte("name,uid\nMuster GmbH,atu12345678\nBeispiel AG, DE 123 456 789\nMuster GmbH,ATU12345678\n,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(uids, ",") != "ATU12345678,DE123456789" {
+		t.Errorf("uids = %v", uids)
+	}
+
+	if _, err := uid.ParseBatchCSV([]byte("name\nMuster GmbH\n")); !errors.Is(err, uid.ErrInvalidCSV) {
+		t.Errorf("missing uid column: %v, want ErrInvalidCSV", err)
+	}
+	if _, err := uid.ParseBatchCSV([]byte("uid\n")); !errors.Is(err, uid.ErrEmptyBatch) {
+		t.Errorf("no rows: %v, want ErrEmptyBatch", err)
+	}
+
+	var csv strings.Builder
+	csv.WriteString("uid\n")
+	for i := 0; i <= uid.MaxBatchSize; i++ {
+		fmt.Fprintf(&csv, "ATU%08d\n", i)
+	}
+	if _, err := uid.ParseBatchCSV([]byte(csv.String())); !errors.Is(err, uid.ErrBatchTooLarge) {
+		t.Errorf("%d UIDs: %v, want ErrBatchTooLarge", uid.MaxBatchSize+1, err)
+	}
+}
+
+func TestUIDWriteBatchCSV(t *testing.T) {
+	name, city, queryErr, consultation := "Muster GmbH", "Wien", "UID validation failed: timeout", "U2026101600042"
+	validationID := uuid.MustParse("6f1c2a40-7a53-4b1e-9a1e-3c0d2f6b8e01")
+	items := []*uid.BatchItem{
+		{Position: 1, UID: "ATU12345678", Validation: &uid.Validation{
+			ID:          validationID,
+			Valid:       true,
+			Level:       uid.Level2,
+			CompanyName: &name,
+			City:        &city,
+			ValidatedAt: time.Date(2026, 10, 16, 10, 1, 12, 0, time.FixedZone("CEST", 2*3600)),
+
+			ConsultationNumber: &consultation,
+		}},
+		{Position: 2, UID: "DE123456789", Error: &queryErr},
+	}
+
+	data, err := uid.WriteBatchCSV(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "position,uid,level,valid,company_name,street,post_code,city,error,validated_at,validation_id,consultation_number\n" +
+		"1,ATU12345678,2,true,Muster GmbH,,,Wien,,2026-10-16T08:01:12Z," + validationID.String() + ",U2026101600042\n" +
+		"2,DE123456789,,,,,,,UID validation failed: timeout,,,\n"
+	if string(data) != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestUIDBatchCompletedMail(t *testing.T) {
+	renderer, err := mail.NewRenderer("Austrian Business Platform")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, err := renderer.Render(mail.TemplateUIDBatchCompleted, email.UIDBatchCompletedParams{
+		RecipientName: "Anna Huber",
+		FileName:      "lieferanten.csv",
+		Level:         2,
+		Total:         240,
+		Valid:         231,
+		Invalid:       8,
+		Failed:        1,
+		ResultsURL:    "https://app.example/uid/batches/1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered.Subject != "UID-Prüfung abgeschlossen: lieferanten.csv" {
+		t.Errorf("unexpected subject %q", rendered.Subject)
+	}
+	for _, want := range []string{"Guten Tag Anna Huber,", "(Stufe 2)", "Gültig: 231", "Ungültig: 8", "Nicht abgefragt: 1", "https://app.example/uid/batches/1"} {
+		if !strings.Contains(rendered.Text, want) {
+			t.Errorf("expected %q in text:\n%s", want, rendered.Text)
+		}
+	}
+
+	rendered, err = renderer.Render(mail.TemplateUIDBatchCompleted, email.UIDBatchCompletedParams{
+		Level: 1,
+		Total: 10,
+		Valid: 4,
+		Error: "failed to login to FinanzOnline",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered.Subject != "UID-Prüfung abgebrochen" {
+		t.Errorf("unexpected subject %q", rendered.Subject)
+	}
+	if !strings.Contains(rendered.Text, "failed to login to FinanzOnline") || strings.Contains(rendered.Text, "Nicht abgefragt") {
+		t.Errorf("unexpected text:\n%s", rendered.Text)
+	}
+}
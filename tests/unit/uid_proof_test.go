@@ -0,0 +1,106 @@
+package unit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/uid"
+)
+
+func TestUIDConsultationNumber(t *testing.T) {
+	result := fonws.ConvertUIDResponse(&fonws.UIDAbfrageResponse{
+		UIDTN:     "DE123456789",
+		Gueltig:   "true",
+		Name:      "Beispiel AG",
+		AbfrageNr: "WAPIAAAAY1V2Bm3p",
+	})
+	if !result.Valid || result.ConsultationNumber != "WAPIAAAAY1V2Bm3p" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	invalid := fonws.ConvertUIDResponse(&fonws.UIDAbfrageResponse{RC: 1514, UIDTN: "DE123456789", AbfrageNr: "WAPIAAAAY1V2Bm3q"})
+	if invalid.ConsultationNumber != "" {
+		t.Errorf("rejected query should not carry a consultation number, got %q", invalid.ConsultationNumber)
+	}
+}
+
+func uidTestProof() *uid.Proof {
+	name, street, postCode, city := "Muster GmbH", "Hauptstraße 1", "1010", "Wien"
+	consultation := "U2026101600042"
+	return &uid.Proof{
+		TenantName: "Kanzlei Berger",
+		UID:        "ATU12345678",
+		DateFrom:   time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		DateTo:     time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		Checks: []*uid.ProofCheck{
+			{
+				Validation: uid.Validation{
+					ID:                 uuid.MustParse("6f1c2a40-7a53-4b1e-9a1e-3c0d2f6b8e01"),
+					UID:                "ATU12345678",
+					Valid:              true,
+					Level:              uid.Level2,
+					CompanyName:        &name,
+					Street:             &street,
+					PostCode:           &postCode,
+					City:               &city,
+					Source:             "finanzonline",
+					ConsultationNumber: &consultation,
+					ValidatedAt:        time.Date(2026, 8, 3, 7, 15, 0, 0, time.UTC),
+				},
+				CheckedBy:   "Eva Berger",
+				AccountName: "Kanzlei FON",
+			},
+		},
+		GeneratedAt: time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestUIDWriteProofCSV(t *testing.T) {
+	data, err := uid.WriteProofCSV(uidTestProof())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "uid,validated_at,level,valid,consultation_number,company_name,street,post_code,city,country,source,checked_by,account,error,validation_id\n" +
+		"ATU12345678,2026-08-03T07:15:00Z,2,true,U2026101600042,Muster GmbH,Hauptstraße 1,1010,Wien,,finanzonline,Eva Berger,Kanzlei FON,,6f1c2a40-7a53-4b1e-9a1e-3c0d2f6b8e01\n"
+	if string(data) != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestUIDGenerateProofPDF(t *testing.T) {
+	data, err := uid.GenerateProofPDF(uidTestProof())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("not a PDF")
+	}
+	pdf := string(data)
+	for _, want := range []string{
+		"(UID des Gesch\\344ftspartners: ATU12345678)",
+		"(Zeitraum: 01.07.2026 \\226 30.09.2026)",
+		"(Abfragen: 1, davon Stufe 2 mit Abfragenummer: 1)",
+		"(03.08.2026 09:15:00   Stufe 2   g\\374ltig   Abfragenummer: U2026101600042)",
+		"(Muster GmbH, Hauptstra\\337e 1, 1010 Wien)",
+		"(Abgefragt \\374ber finanzonline, Konto Kanzlei FON, von Eva Berger)",
+	} {
+		if !strings.Contains(pdf, want) {
+			t.Errorf("PDF is missing %s", want)
+		}
+	}
+
+	empty := uidTestProof()
+	empty.Checks = nil
+	data, err = uid.GenerateProofPDF(empty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "(Keine Abfragen im Zeitraum.)") {
+		t.Error("empty proof should state that there were no checks")
+	}
+}
@@ -0,0 +1,128 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/api"
+	"austrian-business-infrastructure/internal/usage"
+
+	"github.com/google/uuid"
+)
+
+func TestUsageParseRange(t *testing.T) {
+	today := time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)
+
+	rng, err := usage.ParseRange(usage.GranularityDay, "", "", today)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := rng.From.Format(usage.DayLayout); got != "2025-02-16" {
+		t.Errorf("expected default day range to start 2025-02-16, got %s", got)
+	}
+
+	rng, err = usage.ParseRange(usage.GranularityMonth, "", "", today)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rng.From.Format(usage.MonthLayout) != "2024-04" || rng.To.Format(usage.MonthLayout) != "2025-03" {
+		t.Errorf("expected 2024-04..2025-03, got %v..%v", rng.From, rng.To)
+	}
+	if got := rng.LastDay(usage.GranularityMonth).Format(usage.DayLayout); got != "2025-03-31" {
+		t.Errorf("expected last day 2025-03-31, got %s", got)
+	}
+
+	tests := []struct {
+		name     string
+		g        usage.Granularity
+		from, to string
+		want     error
+	}{
+		{"reversed", usage.GranularityDay, "2025-03-02", "2025-03-01", usage.ErrInvalidRange},
+		{"month layout for days", usage.GranularityDay, "2025-03", "", usage.ErrInvalidRange},
+		{"day layout for months", usage.GranularityMonth, "2025-03-01", "", usage.ErrInvalidRange},
+		{"too many days", usage.GranularityDay, "2024-01-01", "2025-01-02", usage.ErrRangeTooLarge},
+		{"too many months", usage.GranularityMonth, "2015-01", "2025-01", usage.ErrRangeTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := usage.ParseRange(tt.g, tt.from, tt.to, today); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// A leap year fits into the day limit
+	if _, err := usage.ParseRange(usage.GranularityDay, "2024-01-01", "2024-12-31", today); err != nil {
+		t.Errorf("expected 366 days to be accepted, got %v", err)
+	}
+}
+
+func TestUsageFillSeries(t *testing.T) {
+	metric, err := usage.LookupMetric(usage.MetricAICostCents)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rng := usage.Range{
+		From: time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	s := usage.FillSeries(metric, usage.GranularityMonth, rng, map[string]int64{
+		"2024-11": 1840,
+		"2025-02": 912,
+		"2025-05": 999, // outside the range
+	})
+
+	want := []usage.Point{
+		{Period: "2024-11", Value: 1840},
+		{Period: "2024-12", Value: 0},
+		{Period: "2025-01", Value: 0},
+		{Period: "2025-02", Value: 912},
+	}
+	if len(s.Points) != len(want) {
+		t.Fatalf("expected %d points, got %v", len(want), s.Points)
+	}
+	for i := range want {
+		if s.Points[i] != want[i] {
+			t.Errorf("point %d: expected %v, got %v", i, want[i], s.Points[i])
+		}
+	}
+	if s.Total != 2752 || s.Unit != "cents" || s.From != "2024-11" || s.To != "2025-02" {
+		t.Errorf("unexpected series: %+v", s)
+	}
+
+	if _, err := usage.LookupMetric("storage_bytes"); !errors.Is(err, usage.ErrUnknownMetric) {
+		t.Errorf("expected ErrUnknownMetric, got %v", err)
+	}
+}
+
+func TestUsageHandlerValidation(t *testing.T) {
+	router := api.NewRouter(nil)
+	passthrough := func(next http.Handler) http.Handler { return next }
+	usage.NewHandler(usage.NewService(nil)).RegisterRoutes(router, passthrough, passthrough)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/api/v1/usage", http.StatusOK},
+		{"/api/v1/usage/storage_bytes", http.StatusNotFound},
+		{"/api/v1/usage/signatures?granularity=week", http.StatusBadRequest},
+		{"/api/v1/usage/signatures?from=17.03.2025", http.StatusBadRequest},
+		{"/api/v1/usage/signatures?granularity=month&from=2000-01", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		ctx := context.WithValue(req.Context(), api.TenantIDKey, uuid.New().String())
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req.WithContext(ctx))
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d: %s", tt.path, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
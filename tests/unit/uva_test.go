@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/uva"
+	"github.com/google/uuid"
 )
 
 // T012: Test UVA XML generation
@@ -382,3 +384,138 @@ func TestUVAPeriodTypes(t *testing.T) {
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
+
+// Test Kennzahlen derivation under Soll- and Istbesteuerung including switch-over
+func TestUVADeriveKennzahlenSchemeSwitch(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	// Soll until end of March, Ist from April
+	history := uva.NewSchemeHistory([]*uva.VATScheme{
+		{Scheme: uva.SchemeIst, EffectiveFrom: date(2025, 4, 1)},
+		{Scheme: uva.SchemeSoll, EffectiveFrom: date(2025, 1, 1)},
+	})
+
+	invoices := []*uva.TaxableInvoice{
+		{
+			// Issued under Soll, paid after the switch: already taxed in March
+			ID: uuid.New(), InvoiceNumber: "RE-1", IssueDate: date(2025, 3, 20), Gross: 120000,
+			Lines:    []uva.TaxLine{{TaxCategory: "S", TaxPercent: 20, Net: 100000}},
+			Receipts: []uva.Receipt{{Date: date(2025, 4, 10), Amount: 120000}},
+		},
+		{
+			// Issued under Ist, half paid in April
+			ID: uuid.New(), InvoiceNumber: "RE-2", IssueDate: date(2025, 4, 5), Gross: 110000,
+			Lines:    []uva.TaxLine{{TaxCategory: "S", TaxPercent: 10, Net: 100000}},
+			Receipts: []uva.Receipt{{Date: date(2025, 4, 15), Amount: 55000}},
+		},
+		{
+			// Issued under Ist, not yet paid
+			ID: uuid.New(), InvoiceNumber: "RE-3", IssueDate: date(2025, 4, 25), Gross: 24000,
+			Lines: []uva.TaxLine{{TaxCategory: "S", TaxPercent: 20, Net: 20000}},
+		},
+	}
+
+	march, _ := uva.DeriveKennzahlen(date(2025, 3, 1), date(2025, 3, 31), invoices, history)
+	if march.KZ017 != 100000 || march.KZ000 != 100000 {
+		t.Errorf("March: expected KZ017=100000 KZ000=100000, got KZ017=%d KZ000=%d", march.KZ017, march.KZ000)
+	}
+
+	april, contributions := uva.DeriveKennzahlen(date(2025, 4, 1), date(2025, 4, 30), invoices, history)
+	if april.KZ017 != 0 {
+		t.Errorf("April: Soll invoice must not be taxed again on receipt, got KZ017=%d", april.KZ017)
+	}
+	if april.KZ018 != 50000 {
+		t.Errorf("April: expected pro-rata KZ018=50000, got %d", april.KZ018)
+	}
+	if len(contributions) != 1 || contributions[0].Basis != "payment" {
+		t.Errorf("April: expected one payment contribution, got %+v", contributions)
+	}
+
+	if history.At(date(2025, 3, 31)) != uva.SchemeSoll || history.At(date(2025, 4, 1)) != uva.SchemeIst {
+		t.Error("scheme history lookup around the switch date is wrong")
+	}
+	if history.ChangedWithin(date(2025, 4, 1), date(2025, 6, 30)) {
+		t.Error("switch at the start of a period must not count as a change within the period")
+	}
+	if !history.ChangedWithin(date(2025, 3, 1), date(2025, 5, 31)) {
+		t.Error("switch inside a period must be reported")
+	}
+}
+
+// Test that credit notes reduce the Kennzahlen and unconfigured tenants default to Soll
+func TestUVADeriveKennzahlenCreditNote(t *testing.T) {
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC)
+
+	invoices := []*uva.TaxableInvoice{
+		{ID: uuid.New(), IssueDate: from.AddDate(0, 0, 2), Lines: []uva.TaxLine{{TaxCategory: "S", TaxPercent: 20, Net: 50000}}},
+		{ID: uuid.New(), IssueDate: from.AddDate(0, 0, 9), CreditNote: true, Lines: []uva.TaxLine{{TaxCategory: "S", TaxPercent: 20, Net: 10000}}},
+		{ID: uuid.New(), IssueDate: from.AddDate(0, 0, 12), Lines: []uva.TaxLine{{TaxCategory: "K", TaxPercent: 0, Net: 30000}}},
+	}
+
+	data, _ := uva.DeriveKennzahlen(from, to, invoices, nil)
+	if data.KZ017 != 40000 {
+		t.Errorf("expected KZ017=40000, got %d", data.KZ017)
+	}
+	if data.KZ001 != 30000 || data.KZ000 != 70000 {
+		t.Errorf("expected KZ001=30000 KZ000=70000, got KZ001=%d KZ000=%d", data.KZ001, data.KZ000)
+	}
+}
+
+// Test input tax derivation: domestic input tax on payment under Ist, imports and acquisitions on the invoice date
+func TestUVADeriveInputTax(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	from, to := date(2025, 3, 1), date(2025, 3, 31)
+	paidMarch, paidApril := date(2025, 3, 28), date(2025, 4, 3)
+
+	invoices := []*uva.InputInvoice{
+		{ID: uuid.New(), InvoiceNumber: "ER-1", InvoiceDate: date(2025, 2, 20), Kind: uva.InputDomestic, PaidDate: &paidMarch,
+			Lines: []uva.InputInvoiceLine{{TaxPercent: 20, Net: 100000, Tax: 20000}}},
+		{ID: uuid.New(), InvoiceNumber: "ER-2", InvoiceDate: date(2025, 3, 10), Kind: uva.InputDomestic, PaidDate: &paidApril,
+			Lines: []uva.InputInvoiceLine{{TaxPercent: 10, Net: 50000, Tax: 5000}}},
+		{ID: uuid.New(), InvoiceNumber: "EUSt-7", InvoiceDate: date(2025, 3, 12), Kind: uva.InputImportDeferred,
+			Lines: []uva.InputInvoiceLine{{TaxPercent: 20, Net: 30000, Tax: 6000}}},
+		{ID: uuid.New(), InvoiceNumber: "DE-99", InvoiceDate: date(2025, 3, 14), Kind: uva.InputIGAcquisition,
+			Lines: []uva.InputInvoiceLine{{TaxPercent: 20, Net: 40000}}},
+		{ID: uuid.New(), InvoiceNumber: "GS-3", InvoiceDate: date(2025, 3, 20), Kind: uva.InputDomestic, CreditNote: true, PaidDate: &paidMarch,
+			Lines: []uva.InputInvoiceLine{{TaxPercent: 20, Net: 10000, Tax: 2000}}},
+	}
+
+	soll := &uva.UVAData{}
+	uva.DeriveInputTax(soll, from, to, invoices, nil)
+	if soll.KZ060 != 3000 {
+		t.Errorf("Soll: expected KZ060=3000 from the March invoice and credit note, got %d", soll.KZ060)
+	}
+	if soll.KZ022 != 6000 || soll.KZ065 != 6000 {
+		t.Errorf("expected deferred import VAT in KZ022 and KZ065, got KZ022=%d KZ065=%d", soll.KZ022, soll.KZ065)
+	}
+	if soll.KZ029 != 40000 || soll.KZ066 != 8000 {
+		t.Errorf("expected acquisition KZ029=40000 KZ066=8000, got KZ029=%d KZ066=%d", soll.KZ029, soll.KZ066)
+	}
+
+	ist := &uva.UVAData{}
+	history := uva.NewSchemeHistory([]*uva.VATScheme{{Scheme: uva.SchemeIst, EffectiveFrom: date(2025, 1, 1)}})
+	contributions := uva.DeriveInputTax(ist, from, to, invoices, history)
+	if ist.KZ060 != 18000 {
+		t.Errorf("Ist: expected KZ060=18000 from the invoices paid in March, got %d", ist.KZ060)
+	}
+	if ist.KZ066 != 8000 || len(contributions) != 4 || contributions[0].Basis != "payment" || contributions[0].InputTax != 20000 {
+		t.Errorf("Ist: unexpected contributions %+v", contributions)
+	}
+}
+
+func TestUVAParsePeriod(t *testing.T) {
+	monthly, err := uva.ParsePeriod("2025-03")
+	if err != nil || monthly.PeriodType != uva.PeriodTypeMonthly || monthly.PeriodYear != 2025 || *monthly.PeriodMonth != 3 {
+		t.Errorf("unexpected monthly period %+v, %v", monthly, err)
+	}
+	quarterly, err := uva.ParsePeriod("2025-q2")
+	if err != nil || quarterly.PeriodType != uva.PeriodTypeQuarterly || *quarterly.PeriodQuarter != 2 {
+		t.Errorf("unexpected quarterly period %+v, %v", quarterly, err)
+	}
+	for _, period := range []string{"", "2025", "2025-13", "2025-3", "2025-Q5", "25-03"} {
+		if _, err := uva.ParsePeriod(period); err != uva.ErrInvalidPeriod {
+			t.Errorf("%q: expected ErrInvalidPeriod, got %v", period, err)
+		}
+	}
+}
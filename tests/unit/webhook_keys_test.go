@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/webhook"
+	"github.com/google/uuid"
+)
+
+func TestWebhookSigningKeyValidity(t *testing.T) {
+	now := time.Now()
+	until := now.Add(24 * time.Hour)
+	previous := &webhook.SigningKey{ValidFrom: now.Add(-time.Hour), ValidUntil: &until}
+	current := &webhook.SigningKey{ValidFrom: now}
+
+	if !previous.ValidAt(now) || !previous.ValidAt(until.Add(-time.Second)) || previous.ValidAt(until) {
+		t.Error("the previous key should be valid until the overlap period ends")
+	}
+	if !current.ValidAt(now.Add(365*24*time.Hour)) || current.ValidAt(now.Add(-time.Second)) {
+		t.Error("the current key should be valid from its start without end")
+	}
+	if previous.Current() || !current.Current() {
+		t.Error("only the key without end of validity is current")
+	}
+}
+
+func TestWebhookSignRequestDuringOverlap(t *testing.T) {
+	payload := []byte(`{"event":"new_document"}`)
+	until := time.Now().Add(time.Hour)
+	previous := &webhook.SigningKey{ID: uuid.New(), Secret: "old-secret", ValidUntil: &until}
+	current := &webhook.SigningKey{ID: uuid.New(), Secret: "new-secret"}
+
+	req := httptest.NewRequest("POST", "https://example.at/hook", nil)
+	webhook.SignRequest(req, payload, []*webhook.SigningKey{previous, current})
+
+	mac := hmac.New(sha256.New, []byte("old-secret"))
+	mac.Write(payload)
+	if got, want := req.Header.Get(webhook.SignatureHeader), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("signature = %q, want the previous key's %q", got, want)
+	}
+	if !webhook.VerifySignature(payload, req.Header.Get(webhook.SignatureHeader), "old-secret") {
+		t.Error("consumers still on the old secret should verify the single signature")
+	}
+	if got := req.Header.Get(webhook.KeyIDHeader); got != previous.ID.String() {
+		t.Errorf("key ID = %q, want %s", got, previous.ID)
+	}
+
+	signatures := req.Header.Get(webhook.SignaturesHeader)
+	if !strings.HasPrefix(signatures, "key="+previous.ID.String()+";sha256=") ||
+		!strings.Contains(signatures, ", key="+current.ID.String()+";sha256=") {
+		t.Errorf("signatures = %q", signatures)
+	}
+	for _, secret := range []string{"old-secret", "new-secret"} {
+		if !webhook.VerifySignatures(payload, signatures, secret) {
+			t.Errorf("%s should verify during the overlap", secret)
+		}
+	}
+	if webhook.VerifySignatures(payload, signatures, "other-secret") {
+		t.Error("an unrelated secret should not verify")
+	}
+	if webhook.VerifySignatures([]byte(`{"event":"tampered"}`), signatures, "new-secret") {
+		t.Error("a changed payload should not verify")
+	}
+}
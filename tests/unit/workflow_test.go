@@ -0,0 +1,146 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/antrag"
+	"austrian-business-infrastructure/internal/email"
+	"austrian-business-infrastructure/internal/mail"
+	"austrian-business-infrastructure/internal/workflow"
+)
+
+func freigabeWorkflow() *workflow.Definition {
+	return &workflow.Definition{
+		Key:     "freigabe",
+		Name:    "Freigabe",
+		Initial: "pruefung",
+		States: []workflow.State{
+			{Name: "pruefung", SLA: &workflow.SLA{Hours: 48}},
+			{Name: "freigabe", Timer: &workflow.Timer{Hours: 120, Transition: "zurueck"}},
+			{Name: "erledigt", Final: true},
+		},
+		Transitions: []workflow.Transition{
+			{Name: "pruefen", From: []string{"pruefung"}, To: "freigabe"},
+			{Name: "zurueck", From: []string{"freigabe"}, To: "pruefung"},
+			{Name: "freigeben", From: []string{"freigabe"}, To: "erledigt", MinRole: "admin"},
+		},
+	}
+}
+
+func TestWorkflowDefinitionValidate(t *testing.T) {
+	if err := freigabeWorkflow().Validate(); err != nil {
+		t.Fatalf("expected a valid definition, got %v", err)
+	}
+	if err := antrag.Lifecycle.Validate(); err != nil {
+		t.Fatalf("expected the application lifecycle to be valid, got %v", err)
+	}
+
+	broken := map[string]func(d *workflow.Definition){
+		"unknown initial state":   func(d *workflow.Definition) { d.Initial = "neu" },
+		"unknown target state":    func(d *workflow.Definition) { d.Transitions[0].To = "neu" },
+		"leaving a final state":   func(d *workflow.Definition) { d.Transitions[1].From = []string{"erledigt"} },
+		"timer not leaving state": func(d *workflow.Definition) { d.States[1].Timer.Transition = "pruefen" },
+		"unknown role":            func(d *workflow.Definition) { d.Transitions[2].MinRole = "chef" },
+		"invalid key":             func(d *workflow.Definition) { d.Key = "Freigabe 1" },
+	}
+	for name, breakIt := range broken {
+		d := freigabeWorkflow()
+		breakIt(d)
+		if err := d.Validate(); !errors.Is(err, workflow.ErrInvalidDefinition) {
+			t.Errorf("%s: expected ErrInvalidDefinition, got %v", name, err)
+		}
+	}
+}
+
+func TestWorkflowTransitions(t *testing.T) {
+	d := freigabeWorkflow()
+
+	if _, err := d.Transition("pruefung", "freigeben"); !errors.Is(err, workflow.ErrInvalidTransition) {
+		t.Errorf("expected freigeben not to leave pruefung, got %v", err)
+	}
+	if _, err := d.Transition("neu", "pruefen"); !errors.Is(err, workflow.ErrUnknownState) {
+		t.Errorf("expected an unknown state, got %v", err)
+	}
+	tr, err := d.Transition("freigabe", "freigeben")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.Permits("member") || !tr.Permits("admin") || !tr.Permits("owner") {
+		t.Error("expected freigeben to need at least an admin")
+	}
+	if got := d.Available("freigabe"); strings.Join(got, ",") != "zurueck,freigeben" {
+		t.Errorf("unexpected transitions %v", got)
+	}
+
+	if _, err := antrag.Lifecycle.Transition("in_review", "approved"); err != nil {
+		t.Errorf("expected an application in review to be approvable, got %v", err)
+	}
+	if _, err := antrag.Lifecycle.Transition("planned", "submitted"); err == nil {
+		t.Error("expected a planned application not to be submittable")
+	}
+}
+
+func TestWorkflowTimersAndSLA(t *testing.T) {
+	d := freigabeWorkflow()
+	start := time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)
+	inst := &workflow.Instance{Definition: d}
+
+	pruefung, _ := d.State("pruefung")
+	workflow.Enter(inst, pruefung, start)
+	if inst.DueAt == nil || !inst.DueAt.Equal(start.Add(48*time.Hour)) || inst.TimerAt != nil {
+		t.Fatalf("expected an SLA of 48 hours and no timer, got due %v timer %v", inst.DueAt, inst.TimerAt)
+	}
+	if workflow.Overdue(inst, start.Add(47*time.Hour)) || !workflow.Overdue(inst, start.Add(48*time.Hour)) {
+		t.Error("expected the workflow to be overdue after 48 hours")
+	}
+	escalated := start.Add(49 * time.Hour)
+	inst.EscalatedAt = &escalated
+	if workflow.Overdue(inst, start.Add(50*time.Hour)) {
+		t.Error("expected an escalated workflow not to be overdue again")
+	}
+
+	freigabe, _ := d.State("freigabe")
+	workflow.Enter(inst, freigabe, start)
+	if inst.EscalatedAt != nil || inst.DueAt != nil {
+		t.Error("expected entering a state to clear the SLA and escalation of the previous one")
+	}
+	if workflow.TimerDue(inst, start.Add(119*time.Hour)) || !workflow.TimerDue(inst, start.Add(120*time.Hour)) {
+		t.Error("expected the timer to expire after 120 hours")
+	}
+
+	erledigt, _ := d.State("erledigt")
+	workflow.Enter(inst, erledigt, start)
+	if inst.CompletedAt == nil || inst.TimerAt != nil {
+		t.Error("expected a final state to complete the workflow without timer")
+	}
+}
+
+func TestWorkflowEscalationMail(t *testing.T) {
+	renderer, err := mail.NewRenderer("Austrian Business Platform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := renderer.Render(mail.TemplateWorkflowEscalation, email.WorkflowEscalationParams{
+		RecipientName: "Anna Huber",
+		Workflow:      "Freigabe",
+		Title:         "RE 2025-117",
+		State:         "Sachliche Prüfung",
+		Since:         "02.06.2025 10:00",
+		Due:           "04.06.2025 10:00",
+		Assignee:      "Max Muster",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered.Subject != "Überfällig: RE 2025-117 (Sachliche Prüfung)" {
+		t.Errorf("unexpected subject %q", rendered.Subject)
+	}
+	for _, want := range []string{"bis 04.06.2025 10:00", "Zuständig: Max Muster"} {
+		if !strings.Contains(rendered.Text, want) {
+			t.Errorf("expected %q in text:\n%s", want, rendered.Text)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/foerderung"
+	"austrian-business-infrastructure/internal/xlsx"
+	"github.com/google/uuid"
+)
+
+// readXLSX returns the parts of a workbook and checks that each is well-formed XML
+func readXLSX(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip archive: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		dec := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well-formed: %v", f.Name, err)
+			}
+		}
+		parts[f.Name] = string(content)
+	}
+	return parts
+}
+
+func TestXLSXWorkbook(t *testing.T) {
+	wb := &xlsx.Workbook{
+		Title:       "Test & Co",
+		AccentColor: "#AA0000",
+		Sheets: []*xlsx.Sheet{
+			{
+				Name: "Vergleich: 2026/Q1",
+				Rows: [][]xlsx.Cell{
+					{xlsx.Text("Name <A> & B", xlsx.StyleHeader), {Value: 1500, Style: xlsx.StyleCurrency}},
+					{{Value: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Style: xlsx.StyleDate}, {Value: 0.5, Style: xlsx.StylePercent}},
+				},
+				FreezeRows:    1,
+				FreezeColumns: 1,
+				Merges:        []string{"A1:B1"},
+				Footer:        "Müller & Partner",
+				Landscape:     true,
+			},
+			{Name: "Vergleich: 2026/Q1"},
+		},
+	}
+	data, err := wb.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	parts := readXLSX(t, data)
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/styles.xml",
+		"xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml", "docProps/core.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`Name &lt;A&gt; &amp; B`,
+		`<c r="B1" s="5"><v>1500</v></c>`,
+		`<c r="A2" s="7"><v>46082</v></c>`,
+		`<c r="B2" s="6"><v>0.5</v></c>`,
+		`topLeftCell="B2"`,
+		`<mergeCell ref="A1:B1"/>`,
+		`Müller &amp;&amp; Partner`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet lacks %s", want)
+		}
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Vergleich- 2026-Q1"`) ||
+		!strings.Contains(parts["xl/workbook.xml"], `name="Vergleich- 2026-Q1 (2)"`) {
+		t.Errorf("sheet names not sanitized and unique: %s", parts["xl/workbook.xml"])
+	}
+	if !strings.Contains(parts["xl/styles.xml"], `rgb="FFAA0000"`) {
+		t.Error("accent color not applied")
+	}
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	for col, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := xlsx.ColumnName(col); got != want {
+			t.Errorf("ColumnName(%d) = %s, want %s", col, got, want)
+		}
+	}
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	for tag, want := range map[string]string{
+		"de": "de-AT", "de-at": "de-AT", "en_US": "en-US", "en": "en-GB", "en-IE": "en-GB", "fr-FR": "", "": "",
+	} {
+		if got := foerderung.NormalizeLocale(tag); got != want {
+			t.Errorf("NormalizeLocale(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestBuildComparison(t *testing.T) {
+	rate := 0.4
+	maxAmount := 200000
+	deadline := time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC)
+	rolling := foerderung.DeadlineRolling
+	a := &foerderung.Foerderung{ID: uuid.New(), Name: "aws Digitalisierung", Provider: "AWS", Type: foerderung.TypeZuschuss,
+		FundingRateMax: &rate, MaxAmount: &maxAmount, ApplicationDeadline: &deadline, Topics: []string{"digitalisierung"}}
+	b := &foerderung.Foerderung{ID: uuid.New(), Name: "FFG Basisprogramm", Provider: "FFG", Type: foerderung.TypeKredit,
+		DeadlineType: &rolling}
+
+	comparison := foerderung.BuildComparison(&foerderung.ComparisonInput{
+		Programs: []*foerderung.Foerderung{a, b},
+		Search:   &foerderung.FoerderungsSuche{CreatedAt: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)},
+		Matches: map[uuid.UUID]*foerderung.FoerderungsMatch{
+			a.ID: {FoerderungID: a.ID, TotalScore: 0.82, LLMResult: &foerderung.LLMEligibilityResult{
+				Eligible: true, Confidence: "high", Concerns: []string{"Eigenmittel nachweisen"}}},
+		},
+		Brand:  &foerderung.SheetBrand{CompanyName: "Kanzlei Huber", Color: "004488"},
+		Locale: "en-GB",
+		Now:    time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC),
+	})
+
+	if comparison.Filename != "funding-comparison-2026-10-16.xlsx" {
+		t.Errorf("Filename = %s", comparison.Filename)
+	}
+	data, err := comparison.Workbook.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	parts := readXLSX(t, data)
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		"Funding comparison – Kanzlei Huber",
+		"aws Digitalisierung", "FFG Basisprogramm",
+		"Grant", "Loan", "rolling",
+		"<v>0.4</v>", "<v>200000</v>", "<v>0.82</v>",
+		"yes (high confidence)", "• Eigenmittel nachweisen",
+		"not in the search",
+		`<mergeCell ref="A1:C1"/>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet lacks %q", want)
+		}
+	}
+	styles := parts["xl/styles.xml"]
+	if !strings.Contains(styles, `formatCode="DD/MM/YYYY"`) || !strings.Contains(styles, `rgb="FF004488"`) {
+		t.Error("locale date format or brand color missing from styles")
+	}
+}
@@ -0,0 +1,154 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"austrian-business-infrastructure/internal/elda"
+	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/lohnzettel"
+	"austrian-business-infrastructure/internal/xmlschema"
+)
+
+func TestXMLSchemaGeneratedDocumentsConform(t *testing.T) {
+	registry := xmlschema.Default()
+	period := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	uvaXML, err := fonws.GenerateUVAXML(&fonws.UVA{
+		Year:   2025,
+		Period: fonws.UVAPeriod{Type: fonws.PeriodTypeMonthly, Value: 3},
+		KZ000:  100000,
+		KZ022:  20000,
+		KZ060:  5000,
+		KZ095:  -1500,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Validate(xmlschema.FormatU30, period, uvaXML); err != nil {
+		t.Errorf("U30: %v", err)
+	}
+
+	zm := fonws.NewZM(2025, 1)
+	zm.Entries = append(zm.Entries, fonws.ZMEntry{
+		PartnerUID:   "DE123456789",
+		CountryCode:  "DE",
+		DeliveryType: fonws.ZMDeliveryTypeGoods,
+		Amount:       1250000,
+	})
+	zmXML, err := fonws.GenerateZMXML(zm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Validate(xmlschema.FormatZM, period, zmXML); err != nil {
+		t.Errorf("ZM: %v", err)
+	}
+
+	l16XML, err := lohnzettel.NewBuilder().BuildXML(&elda.Lohnzettel{
+		Year:         2025,
+		SVNummer:     "1237010180",
+		Familienname: "Muster",
+		Vorname:      "Max",
+		L16Data:      elda.L16Data{KZ210: 42000.5, KZ226: 1200, ArbeitsTage: 220},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := registry.Validate(xmlschema.FormatL16, period, l16XML)
+	if err != nil {
+		t.Errorf("L16: %v", err)
+	}
+	if entry == nil || entry.File != "l16-2024.xsd" {
+		t.Errorf("L16 entry = %+v", entry)
+	}
+}
+
+func TestXMLSchemaReportsViolations(t *testing.T) {
+	doc := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ZM>
+  <Jahr>2025</Jahr>
+  <Quartal>5</Quartal>
+  <Position>
+    <PartnerUID>DE123456789</PartnerUID>
+    <LandCode>de</LandCode>
+    <Lieferart>X</Lieferart>
+  </Position>
+  <Bemerkung>unexpected</Bemerkung>
+</ZM>`)
+
+	_, err := xmlschema.Default().Validate(xmlschema.FormatZM, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), doc)
+	var schemaErr *xmlschema.ValidationError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	want := map[string]string{
+		"/ZM/Quartal":            "maximum",
+		"/ZM/Position/LandCode":  "pattern",
+		"/ZM/Position/Lieferart": "not one of",
+		"/ZM/Position":           "Bemessungsgrundlage",
+		"/ZM/Bemerkung":          "unexpected",
+	}
+	for _, v := range schemaErr.Violations {
+		if fragment, ok := want[v.Path]; ok && strings.Contains(v.Message, fragment) {
+			delete(want, v.Path)
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing violations %v, got %v", want, schemaErr.Messages())
+	}
+
+	// Wrong namespace on an ELDA document
+	_, err = xmlschema.Default().Validate(xmlschema.FormatL16, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		[]byte(`<Lohnzettel><Jahr>2025</Jahr></Lohnzettel>`))
+	if !errors.As(err, &schemaErr) || !strings.Contains(schemaErr.Violations[0].Message, "namespace") {
+		t.Errorf("expected namespace violation, got %v", err)
+	}
+}
+
+func TestXMLSchemaRegistryVersions(t *testing.T) {
+	xsd := func(element string) []byte {
+		return []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="` + element + `" type="xs:string"/></xs:schema>`)
+	}
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	r := xmlschema.NewRegistry()
+	if _, err := r.Add(xmlschema.FormatU30, "2025", day("2025-01-01"), day("2025-12-31"), xsd("Alt")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Add(xmlschema.FormatU30, "2026", day("2026-01-01"), day("2026-12-31"), xsd("Neu")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Add(xmlschema.FormatU30, "overlap", day("2026-06-01"), day("2027-12-31"), xsd("X")); err == nil {
+		t.Error("expected overlapping validity to be rejected")
+	}
+
+	// The reporting date picks the version
+	if _, err := r.Validate(xmlschema.FormatU30, day("2025-11-01"), []byte(`<Alt>x</Alt>`)); err != nil {
+		t.Errorf("2025 document: %v", err)
+	}
+	if _, err := r.Validate(xmlschema.FormatU30, day("2026-02-01"), []byte(`<Alt>x</Alt>`)); err == nil {
+		t.Error("2025 root accepted by 2026 schema")
+	}
+	if _, err := r.Lookup(xmlschema.FormatU30, day("2027-01-01")); !errors.Is(err, xmlschema.ErrNoSchema) {
+		t.Errorf("2027: got %v", err)
+	}
+	if _, err := r.Lookup(xmlschema.FormatZM, day("2026-01-01")); !errors.Is(err, xmlschema.ErrUnknownFormat) {
+		t.Errorf("unknown format: got %v", err)
+	}
+
+	// Only the latest version of a format is reported as expiring
+	within := 30 * 24 * time.Hour
+	if expiring := r.Expiring(day("2026-10-01"), within); len(expiring) != 0 {
+		t.Errorf("expected nothing expiring, got %d", len(expiring))
+	}
+	expiring := r.Expiring(day("2026-12-15"), within)
+	if len(expiring) != 1 || expiring[0].Version != "2026" {
+		t.Errorf("expected 2026 version expiring, got %+v", expiring)
+	}
+}
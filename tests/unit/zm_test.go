@@ -1,10 +1,12 @@
 package unit
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"austrian-business-infrastructure/internal/fonws"
+	"austrian-business-infrastructure/internal/xmlschema"
 )
 
 // T139: Test ZM XML generation
@@ -325,3 +327,63 @@ func TestNewZM(t *testing.T) {
 		t.Error("CreatedAt should be set")
 	}
 }
+
+func TestMonthlyZMXML(t *testing.T) {
+	zm := fonws.NewMonthlyZM(2025, 2)
+	zm.Entries = []fonws.ZMEntry{
+		{PartnerUID: "de 123456789", CountryCode: "de", DeliveryType: fonws.ZMDeliveryTypeGoods, Amount: 1500000},
+		{PartnerUID: "EL123456789", CountryCode: "GR", DeliveryType: fonws.ZMDeliveryTypeTriangular, Amount: 250000},
+	}
+	if zm.Quarter != 1 || zm.PeriodString() != "02/2025" {
+		t.Errorf("NewMonthlyZM() quarter %d, period %s", zm.Quarter, zm.PeriodString())
+	}
+
+	xmlContent, err := fonws.GenerateZMXML(zm)
+	if err != nil {
+		t.Fatalf("GenerateZMXML() error = %v", err)
+	}
+	doc := string(xmlContent)
+	for _, want := range []string{"<Monat>02</Monat>", "<PartnerUID>DE123456789</PartnerUID>", "<LandCode>EL</LandCode>"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("XML should contain %s:\n%s", want, doc)
+		}
+	}
+	if strings.Contains(doc, "<Quartal>") {
+		t.Errorf("monthly XML should not contain a quarter:\n%s", doc)
+	}
+	if _, err := xmlschema.Default().Validate(xmlschema.FormatZM, time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC), xmlContent); err != nil {
+		t.Errorf("monthly XML does not conform to the schema: %v", err)
+	}
+
+	zm.Month = 4
+	if err := zm.Validate(); err == nil {
+		t.Error("expected an error for a month outside the quarter")
+	}
+}
+
+func TestZMValidateLines(t *testing.T) {
+	zm := fonws.NewZM(2025, 1)
+	zm.Entries = []fonws.ZMEntry{
+		{PartnerUID: "DE123456789", CountryCode: "DE", DeliveryType: fonws.ZMDeliveryTypeGoods, Amount: 1000000},
+		{PartnerUID: "DE123456789", CountryCode: "FR", DeliveryType: fonws.ZMDeliveryTypeGoods, Amount: 1000000},
+		{PartnerUID: "CHE123456789", CountryCode: "CH", DeliveryType: fonws.ZMDeliveryTypeServices, Amount: 1000000},
+		{PartnerUID: "ATU12345678", CountryCode: "AT", DeliveryType: fonws.ZMDeliveryTypeGoods, Amount: 1000000},
+		{PartnerUID: "XI123456789", CountryCode: "XI", DeliveryType: fonws.ZMDeliveryTypeServices, Amount: 1000000},
+		{PartnerUID: "XI123456789", CountryCode: "XI", DeliveryType: fonws.ZMDeliveryTypeGoods, Amount: 1000000},
+	}
+
+	errs := zm.ValidateLines()
+	lines := make([]int, 0, len(errs))
+	for _, e := range errs {
+		lines = append(lines, e.Line)
+	}
+	if len(lines) != 4 || lines[0] != 2 || lines[1] != 3 || lines[2] != 4 || lines[3] != 5 {
+		t.Fatalf("ValidateLines() = %+v", errs)
+	}
+	if errs[1].PartnerUID != "CHE123456789" {
+		t.Errorf("line error should carry the partner UID, got %+v", errs[1])
+	}
+	if err := zm.Validate(); err == nil || !strings.Contains(err.Error(), "entry 2") {
+		t.Errorf("Validate() should stop at the first invalid line, got %v", err)
+	}
+}